@@ -0,0 +1,696 @@
+// Package apierror holds the catalog of machine-readable API error codes and
+// renders them into the standard error envelope
+package apierror
+
+import (
+	"sort"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/i18n"
+	"github.com/gofiber/fiber/v3"
+)
+
+// Entry describes a registered error code: the HTTP status it is always
+// returned with and its client-facing message in every supported language
+type Entry struct {
+	Status  int
+	English string
+	Persian string
+}
+
+// Message returns the entry's message in the given locale, falling back to English
+func (e Entry) Message(locale i18n.Locale) string {
+	if locale == i18n.LocalePersian && e.Persian != "" {
+		return e.Persian
+	}
+	return e.English
+}
+
+// Lookup returns the catalog entry registered for code
+func Lookup(code string) (Entry, bool) {
+	entry, ok := catalog[code]
+	return entry, ok
+}
+
+// Codes returns every registered code in alphabetical order
+func Codes() []string {
+	codes := make([]string, 0, len(catalog))
+	for code := range catalog {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// catalog is the single source of truth for error codes emitted by handlers,
+// middleware and the router. Keep it in sync with docs/api-errors.md.
+var catalog = map[string]Entry{
+	// Generic and request validation
+	"BAD_REQUEST":               {fiber.StatusBadRequest, "Bad request", "درخواست نامعتبر است"},
+	"FORBIDDEN":                 {fiber.StatusForbidden, "Access denied", "دسترسی مجاز نیست"},
+	"IDEMPOTENCY_KEY_REQUIRED":  {fiber.StatusBadRequest, "Idempotency key is required", "کلید یکتایی درخواست الزامی است"},
+	"INTERNAL_ERROR":            {fiber.StatusInternalServerError, "An internal server error occurred", "خطای داخلی سرور رخ داد"},
+	"INVALID_BODY":              {fiber.StatusBadRequest, "Invalid request body", "بدنه درخواست نامعتبر است"},
+	"INVALID_DATE":              {fiber.StatusBadRequest, "Invalid date format", "قالب تاریخ نامعتبر است"},
+	"INVALID_DATE_RANGE":        {fiber.StatusBadRequest, "End date must be after start date", "تاریخ پایان باید بعد از تاریخ شروع باشد"},
+	"INVALID_END_DATE":          {fiber.StatusBadRequest, "end_date must be in RFC3339 format", "end_date باید در قالب RFC3339 باشد"},
+	"INVALID_LANGUAGE":          {fiber.StatusBadRequest, "Invalid language", "زبان نامعتبر است"},
+	"INVALID_LIMIT":             {fiber.StatusBadRequest, "Invalid limit", "مقدار limit نامعتبر است"},
+	"INVALID_OFFSET":            {fiber.StatusBadRequest, "offset must be a non-negative integer", "offset باید عدد صحیح نامنفی باشد"},
+	"INVALID_PAGE":              {fiber.StatusBadRequest, "Invalid page", "شماره صفحه نامعتبر است"},
+	"INVALID_PAGE_SIZE":         {fiber.StatusBadRequest, "Invalid page size", "اندازه صفحه نامعتبر است"},
+	"INVALID_REQUEST":           {fiber.StatusBadRequest, "Invalid request body", "بدنه درخواست نامعتبر است"},
+	"INVALID_START_DATE":        {fiber.StatusBadRequest, "start_date must be in RFC3339 format", "start_date باید در قالب RFC3339 باشد"},
+	"INVALID_UUID":              {fiber.StatusBadRequest, "Invalid UUID", "شناسه UUID نامعتبر است"},
+	"METHOD_NOT_ALLOWED":        {fiber.StatusMethodNotAllowed, "Method not allowed", "این متد برای این مسیر مجاز نیست"},
+	"NOT_FOUND":                 {fiber.StatusNotFound, "The requested resource was not found", "منبع درخواستی یافت نشد"},
+	"REQUEST_ENTITY_TOO_LARGE":  {fiber.StatusRequestEntityTooLarge, "Request body is too large", "حجم درخواست بیش از حد مجاز است"},
+	"REQUEST_TIMEOUT":           {fiber.StatusRequestTimeout, "Request timed out", "مهلت درخواست به پایان رسید"},
+	"START_DATE_AFTER_END_DATE": {fiber.StatusBadRequest, "Start date must be before end date", "تاریخ شروع باید قبل از تاریخ پایان باشد"},
+	"STATUS_REQUIRED":           {fiber.StatusBadRequest, "Status is required", "وضعیت الزامی است"},
+	"UNAUTHORIZED":              {fiber.StatusUnauthorized, "Authentication required", "احراز هویت الزامی است"},
+	"UNSUPPORTED_MEDIA_TYPE":    {fiber.StatusUnsupportedMediaType, "Unsupported media type", "نوع محتوای درخواست پشتیبانی نمی‌شود"},
+	"VALIDATION_ERROR":          {fiber.StatusBadRequest, "Validation failed", "اعتبارسنجی داده‌ها ناموفق بود"},
+
+	// Infrastructure
+	"ACCESS_DENIED":         {fiber.StatusForbidden, "Access denied from this IP address", "دسترسی از این آدرس IP مجاز نیست"},
+	"CACHE_NOT_AVAILABLE":   {fiber.StatusServiceUnavailable, "Cache is not available", "حافظه نهان در دسترس نیست"},
+	"CONFIG_INVALID":        {fiber.StatusBadRequest, "Configuration is invalid; the settings in effect were kept", "پیکربندی نامعتبر است؛ تنظیمات فعلی حفظ شد"},
+	"CONFIG_RELOAD_FAILED":  {fiber.StatusInternalServerError, "Failed to reload configuration", "بارگذاری مجدد پیکربندی ناموفق بود"},
+	"INVALID_API_KEY":       {fiber.StatusUnauthorized, "Invalid API key", "کلید API نامعتبر است"},
+	"IP_NOT_ALLOWED":        {fiber.StatusForbidden, "Access denied from this network", "دسترسی از این شبکه مجاز نیست"},
+	"JOB_CANCEL_FAILED":     {fiber.StatusInternalServerError, "Failed to cancel job", "لغو کار پس‌زمینه ناموفق بود"},
+	"JOB_LIST_FAILED":       {fiber.StatusInternalServerError, "Failed to list jobs", "دریافت فهرست کارهای پس‌زمینه ناموفق بود"},
+	"JOB_LOOKUP_FAILED":     {fiber.StatusInternalServerError, "Failed to get job", "دریافت کار پس‌زمینه ناموفق بود"},
+	"JOB_NOT_CANCELLABLE":   {fiber.StatusConflict, "Only pending or dead jobs can be cancelled", "فقط کارهای در انتظار یا متوقف‌شده را می‌توان لغو کرد"},
+	"JOB_NOT_FOUND":         {fiber.StatusNotFound, "Job not found", "کار پس‌زمینه یافت نشد"},
+	"JOB_NOT_REQUEUEABLE":   {fiber.StatusConflict, "Only dead jobs can be requeued", "فقط کارهای متوقف‌شده را می‌توان دوباره در صف قرار داد"},
+	"JOB_REQUEUE_FAILED":    {fiber.StatusInternalServerError, "Failed to requeue job", "قرار دادن دوباره کار در صف ناموفق بود"},
+	"MISSING_API_KEY":       {fiber.StatusUnauthorized, "API key is required", "کلید API الزامی است"},
+	"RATE_LIMITED":          {fiber.StatusTooManyRequests, "Too many attempts", "تعداد تلاش‌ها بیش از حد مجاز است"},
+	"RATE_LIMIT_EXCEEDED":   {fiber.StatusTooManyRequests, "Too many requests. Please try again later.", "تعداد درخواست‌ها بیش از حد مجاز است. لطفاً بعداً دوباره تلاش کنید."},
+	"SERVICE_NOT_READY":     {fiber.StatusServiceUnavailable, "Service is not ready", "سرویس آماده نیست"},
+	"SERVICE_SHUTTING_DOWN": {fiber.StatusServiceUnavailable, "Service is shutting down", "سرویس در حال خاموش شدن است"},
+	"SERVICE_STARTING":      {fiber.StatusServiceUnavailable, "Service is starting", "سرویس در حال راه‌اندازی است"},
+	"SWAGGER_LOAD_ERROR":    {fiber.StatusInternalServerError, "Failed to load API documentation", "بارگذاری مستندات API ناموفق بود"},
+	"SWAGGER_UI_LOAD_ERROR": {fiber.StatusInternalServerError, "Failed to load API documentation UI", "بارگذاری رابط مستندات API ناموفق بود"},
+
+	// Authentication and sessions
+	"ACCOUNT_ALREADY_VERIFIED":          {fiber.StatusBadRequest, "Account is already verified", "حساب کاربری قبلاً تأیید شده است"},
+	"ACCOUNT_INACTIVE":                  {fiber.StatusForbidden, "Account is inactive", "حساب کاربری غیرفعال است"},
+	"ACCOUNT_TYPE_NOT_FOUND":            {fiber.StatusBadRequest, "Account type not found", "نوع حساب کاربری یافت نشد"},
+	"ADMIN_AUTHENTICATION_REQUIRED":     {fiber.StatusUnauthorized, "Admin authentication required", "احراز هویت مدیر الزامی است"},
+	"ADMIN_CAPTCHA_INIT_FAILED":         {fiber.StatusInternalServerError, "Failed to initialize captcha", "ایجاد کپچا ناموفق بود"},
+	"ADMIN_BULK_ACTIVE_STATUS_FAILED":   {fiber.StatusInternalServerError, "Failed to set customers active status", "تغییر وضعیت فعال بودن مشتریان ناموفق بود"},
+	"ADMIN_BULK_COUNT_INVALID":          {fiber.StatusBadRequest, "Bulk operations take 1 to 100 items", "عملیات گروهی ۱ تا ۱۰۰ مورد می‌پذیرد"},
+	"ADMIN_BULK_CRYPTO_RESYNC_FAILED":   {fiber.StatusInternalServerError, "Failed to resync crypto payments", "همگام‌سازی پرداخت‌های رمزارزی ناموفق بود"},
+	"ADMIN_BULK_ITEM_FAILED":            {fiber.StatusInternalServerError, "Bulk operation item failed", "یکی از موارد عملیات گروهی ناموفق بود"},
+	"ADMIN_BULK_REASSIGN_AGENCY_FAILED": {fiber.StatusInternalServerError, "Failed to reassign agency", "تغییر آژانس ناموفق بود"},
+	"ADMIN_INACTIVE":                    {fiber.StatusForbidden, "Admin account is inactive", "حساب مدیر غیرفعال است"},
+	"AUTHENTICATION_FAILED":             {fiber.StatusUnauthorized, "Invalid credentials", "اطلاعات ورود نادرست است"},
+	"AUTHENTICATION_REQUIRED":           {fiber.StatusUnauthorized, "Authentication required", "احراز هویت الزامی است"},
+	"BOT_AUTHENTICATION_REQUIRED":       {fiber.StatusUnauthorized, "Bot authentication required", "احراز هویت ربات الزامی است"},
+	"BOT_LOGIN_FAILED":                  {fiber.StatusUnauthorized, "Bot login failed", "ورود ربات ناموفق بود"},
+	"CHANGE_PASSWORD_FAILED":            {fiber.StatusInternalServerError, "Failed to change password", "تغییر رمز عبور ناموفق بود"},
+	"COMPANY_FIELDS_REQUIRED":           {fiber.StatusBadRequest, "Company fields are required for business accounts", "برای حساب‌های تجاری وارد کردن اطلاعات شرکت الزامی است"},
+	"EMAIL_EXISTS":                      {fiber.StatusConflict, "Email already exists", "این ایمیل قبلاً ثبت شده است"},
+	"IMPERSONATION_NOT_ALLOWED":         {fiber.StatusForbidden, "Not allowed while impersonating a customer", "این عملیات هنگام ورود به جای مشتری مجاز نیست"},
+	"INCORRECT_PASSWORD":                {fiber.StatusUnauthorized, "Incorrect password", "رمز عبور نادرست است"},
+	"INVALID_ADMIN_ID":                  {fiber.StatusUnauthorized, "Invalid admin ID", "شناسه مدیر نامعتبر است"},
+	"INVALID_AUTHORIZATION_FORMAT":      {fiber.StatusUnauthorized, "Invalid authorization header format. Expected 'Bearer <token>'", "قالب هدر Authorization نامعتبر است. قالب مورد انتظار: 'Bearer <token>'"},
+	"INVALID_BOT_ID":                    {fiber.StatusUnauthorized, "Invalid bot ID", "شناسه ربات نامعتبر است"},
+	"INVALID_CAPTCHA":                   {fiber.StatusBadRequest, "Invalid captcha", "کپچا نادرست است"},
+	"INVALID_MOBILE_NUMBER":             {fiber.StatusBadRequest, "Invalid mobile number", "شماره موبایل نامعتبر است"},
+	"INVALID_OTP":                       {fiber.StatusUnauthorized, "Invalid or expired OTP", "کد یکبارمصرف نادرست یا منقضی شده است"},
+	"INVALID_OTP_CODE":                  {fiber.StatusBadRequest, "Invalid OTP code", "کد یکبارمصرف نادرست است"},
+	"INVALID_OTP_PURPOSE":               {fiber.StatusBadRequest, "Invalid OTP purpose", "هدف کد یکبارمصرف نامعتبر است"},
+	"INVALID_OTP_TYPE":                  {fiber.StatusBadRequest, "Invalid OTP type", "نوع کد یکبارمصرف نامعتبر است"},
+	"INVALID_SESSION_ID":                {fiber.StatusBadRequest, "Invalid session ID", "شناسه نشست نامعتبر است"},
+	"LIST_SESSIONS_FAILED":              {fiber.StatusInternalServerError, "Failed to list sessions", "دریافت فهرست نشست‌ها ناموفق بود"},
+	"LOGIN_ALERT_NOT_FOUND":             {fiber.StatusNotFound, "Alert link not found or expired", "لینک هشدار یافت نشد یا منقضی شده است"},
+	"LOGIN_ALERT_REPORT_FAILED":         {fiber.StatusInternalServerError, "Failed to report login", "گزارش ورود ناموفق بود"},
+	"LOGIN_FAILED":                      {fiber.StatusUnauthorized, "Login failed", "ورود ناموفق بود"},
+	"LOGIN_OTP_REQUEST_FAILED":          {fiber.StatusInternalServerError, "Login OTP request failed", "درخواست کد ورود ناموفق بود"},
+	"LOGOUT_FAILED":                     {fiber.StatusInternalServerError, "Logout failed", "خروج از حساب ناموفق بود"},
+	"MISSING_ACCESS_TOKEN":              {fiber.StatusUnauthorized, "Access token is required", "توکن دسترسی الزامی است"},
+	"MISSING_ADMIN_ID":                  {fiber.StatusUnauthorized, "Admin ID not found in context", "شناسه مدیر در درخواست یافت نشد"},
+	"MISSING_AUTHORIZATION_HEADER":      {fiber.StatusUnauthorized, "Authorization header is required", "هدر Authorization الزامی است"},
+	"MISSING_CUSTOMER_ID":               {fiber.StatusUnauthorized, "Customer ID not found in context", "شناسه مشتری در درخواست یافت نشد"},
+	"MOBILE_CHANGE_FAILED":              {fiber.StatusInternalServerError, "Failed to change mobile number", "تغییر شماره موبایل ناموفق بود"},
+	"MOBILE_EXISTS":                     {fiber.StatusConflict, "Mobile number already exists", "این شماره موبایل قبلاً ثبت شده است"},
+	"MOBILE_UNCHANGED":                  {fiber.StatusBadRequest, "New mobile number is the current one", "شماره موبایل جدید با شماره فعلی یکسان است"},
+	"NATIONAL_ID_EXISTS":                {fiber.StatusConflict, "National ID already exists", "این کد ملی قبلاً ثبت شده است"},
+	"NATIONAL_ID_REQUIRED":              {fiber.StatusBadRequest, "National ID is required", "کد ملی الزامی است"},
+	"NO_VALID_OTP":                      {fiber.StatusBadRequest, "No valid OTP found", "کد یکبارمصرف معتبری یافت نشد"},
+	"OTP_EXPIRED":                       {fiber.StatusBadRequest, "OTP expired", "کد یکبارمصرف منقضی شده است"},
+	"OTP_VERIFICATION_FAILED":           {fiber.StatusBadRequest, "OTP verification failed", "تأیید کد یکبارمصرف ناموفق بود"},
+	"PASSWORDLESS_LOGIN_DISABLED":       {fiber.StatusForbidden, "Login with an SMS code is disabled", "ورود با کد پیامکی غیرفعال است"},
+	"PASSWORDLESS_LOGIN_REQUEST_FAILED": {fiber.StatusInternalServerError, "Login code request failed", "درخواست کد ورود ناموفق بود"},
+	"PASSWORD_REJECTED":                 {fiber.StatusBadRequest, "Password does not meet the password policy", "رمز عبور با سیاست رمز عبور مطابقت ندارد"},
+	"PASSWORD_RESET_FAILED":             {fiber.StatusInternalServerError, "Password reset failed", "بازنشانی رمز عبور ناموفق بود"},
+	"PASSWORD_RESET_REQUIRED":           {fiber.StatusForbidden, "Password reset required", "بازنشانی رمز عبور الزامی است"},
+	"REFERRER_AGENCY_ID_REQUIRED":       {fiber.StatusBadRequest, "Referrer agency ID is required", "شناسه آژانس معرف الزامی است"},
+	"REFERRER_AGENCY_INACTIVE":          {fiber.StatusBadRequest, "Referrer agency is inactive", "آژانس معرف غیرفعال است"},
+	"REFERRER_AGENCY_NOT_FOUND":         {fiber.StatusBadRequest, "Referrer agency not found", "آژانس معرف یافت نشد"},
+	"REFERRER_MUST_BE_AGENCY":           {fiber.StatusBadRequest, "Referrer must be a marketing agency", "معرف باید آژانس بازاریابی باشد"},
+	"RESEND_OTP_FAILED":                 {fiber.StatusInternalServerError, "Failed to resend OTP", "ارسال مجدد کد یکبارمصرف ناموفق بود"},
+	"REVOKE_SESSION_FAILED":             {fiber.StatusInternalServerError, "Failed to revoke session", "لغو نشست ناموفق بود"},
+	"SESSION_CHECK_FAILED":              {fiber.StatusInternalServerError, "Session check failed", "بررسی نشست ناموفق بود"},
+	"SESSION_NOT_FOUND":                 {fiber.StatusNotFound, "Session not found", "نشست یافت نشد"},
+	"SIGNUP_FAILED":                     {fiber.StatusInternalServerError, "Signup failed", "ثبت‌نام ناموفق بود"},
+	"TOKEN_EXPIRED":                     {fiber.StatusUnauthorized, "Access token has expired", "توکن دسترسی منقضی شده است"},
+	"TOKEN_INVALID":                     {fiber.StatusUnauthorized, "Invalid access token", "توکن دسترسی نامعتبر است"},
+	"TOKEN_REVOKED":                     {fiber.StatusUnauthorized, "Access token has been revoked", "توکن دسترسی باطل شده است"},
+	"TOKEN_VALIDATION_FAILED":           {fiber.StatusUnauthorized, "Token validation failed", "اعتبارسنجی توکن ناموفق بود"},
+
+	// Admin access control
+	"ACL_REQUEST_APPROVE_FAILED": {fiber.StatusForbidden, "Approval failed", "تأیید درخواست ناموفق بود"},
+	"ACL_REQUEST_CREATE_FAILED":  {fiber.StatusInternalServerError, "Failed to create access change request", "ثبت درخواست تغییر دسترسی ناموفق بود"},
+	"ACL_REQUEST_FORBIDDEN":      {fiber.StatusForbidden, "You are not allowed to decide on this request", "شما مجاز به تصمیم‌گیری درباره این درخواست نیستید"},
+	"ACL_REQUEST_NOT_FOUND":      {fiber.StatusNotFound, "Access change request not found", "درخواست تغییر دسترسی یافت نشد"},
+	"ACL_REQUEST_NOT_PENDING":    {fiber.StatusConflict, "Access change request is not pending", "درخواست تغییر دسترسی در انتظار بررسی نیست"},
+	"FORBIDDEN_OPERATION":        {fiber.StatusForbidden, "System and tax users cannot be modified", "کاربران سیستمی و مالیاتی قابل تغییر نیستند"},
+	"PERMISSION_CHECK_FAILED":    {fiber.StatusInternalServerError, "Permission check failed", "بررسی مجوز ناموفق بود"},
+	"PERMISSION_DENIED":          {fiber.StatusForbidden, "Permission denied", "مجوز لازم را ندارید"},
+	"PERMISSION_NOT_MAPPED":      {fiber.StatusForbidden, "Permission mapping missing", "مجوزی برای این مسیر تعریف نشده است"},
+
+	// Customers, agencies and account data
+	"ACCOUNT_DELETION_BALANCE_REMAINING":          {fiber.StatusConflict, "Withdraw or spend the wallet balance before deleting the account", "پیش از حذف حساب، موجودی کیف پول را برداشت یا خرج کنید"},
+	"ACCOUNT_DELETION_CAMPAIGNS_ACTIVE":           {fiber.StatusConflict, "Cancel or finish active campaigns before deleting the account", "پیش از حذف حساب، کمپین‌های فعال را لغو کنید یا منتظر پایان آن‌ها بمانید"},
+	"ACCOUNT_DELETION_CANCEL_FAILED":              {fiber.StatusInternalServerError, "Failed to cancel account deletion", "لغو حذف حساب ناموفق بود"},
+	"ACCOUNT_DELETION_NOT_ALLOWED":                {fiber.StatusForbidden, "This account cannot be deleted", "این حساب قابل حذف نیست"},
+	"ACCOUNT_DELETION_NOT_FOUND":                  {fiber.StatusNotFound, "No account deletion is scheduled", "حذف حسابی برنامه‌ریزی نشده است"},
+	"ACCOUNT_DELETION_REQUEST_FAILED":             {fiber.StatusInternalServerError, "Failed to schedule account deletion", "برنامه‌ریزی حذف حساب ناموفق بود"},
+	"ACCOUNT_DELETION_SCHEDULED":                  {fiber.StatusConflict, "Account deletion is already scheduled", "حذف حساب قبلاً برنامه‌ریزی شده است"},
+	"AGENCY_CANNOT_CREATE_DISCOUNT_FOR_ITSELF":    {fiber.StatusBadRequest, "Agency cannot create a discount for itself", "آژانس نمی‌تواند برای خود تخفیف ایجاد کند"},
+	"AGENCY_CANNOT_LIST_DISCOUNTS_FOR_ITSELF":     {fiber.StatusBadRequest, "Agency cannot list discounts for itself", "آژانس نمی‌تواند تخفیف‌های خود را فهرست کند"},
+	"AGENCY_COMMISSION_DASHBOARD_FAILED":          {fiber.StatusInternalServerError, "Failed to retrieve agency commission dashboard", "دریافت داشبورد کمیسیون آژانس ناموفق بود"},
+	"AGENCY_COMMISSION_EXPORT_FAILED":             {fiber.StatusInternalServerError, "Failed to export agency commission report", "دریافت خروجی گزارش کمیسیون آژانس ناموفق بود"},
+	"AGENCY_CUSTOMER_REPORT_FAILED":               {fiber.StatusInternalServerError, "Failed to retrieve agency customer report", "دریافت گزارش مشتریان آژانس ناموفق بود"},
+	"AGENCY_DELEGATION_DENIED":                    {fiber.StatusForbidden, "Agency is not allowed to act for this customer", "آژانس مجاز به اقدام از طرف این مشتری نیست"},
+	"AGENCY_DELEGATION_GRANT_FAILED":              {fiber.StatusInternalServerError, "Failed to grant agency delegation", "اعطای دسترسی به آژانس ناموفق بود"},
+	"AGENCY_DELEGATION_LOOKUP_FAILED":             {fiber.StatusInternalServerError, "Failed to retrieve agency delegation", "دریافت دسترسی آژانس ناموفق بود"},
+	"AGENCY_DELEGATION_NOT_FOUND":                 {fiber.StatusNotFound, "No active agency delegation", "دسترسی فعالی برای آژانس وجود ندارد"},
+	"AGENCY_DELEGATION_PERMISSION_INVALID":        {fiber.StatusBadRequest, "Permissions must be create_campaigns or manage_campaigns", "دسترسی‌ها باید create_campaigns یا manage_campaigns باشند"},
+	"AGENCY_DELEGATION_REVOKE_FAILED":             {fiber.StatusInternalServerError, "Failed to revoke agency delegation", "لغو دسترسی آژانس ناموفق بود"},
+	"AGENCY_DISCOUNT_NOT_FOUND":                   {fiber.StatusNotFound, "Agency discount not found", "تخفیف آژانس یافت نشد"},
+	"AGENCY_REASSIGN_SELF":                        {fiber.StatusBadRequest, "Customer cannot be its own referrer agency", "مشتری نمی‌تواند آژانس معرف خود باشد"},
+	"AGENCY_INACTIVE":                             {fiber.StatusForbidden, "Agency is inactive", "آژانس غیرفعال است"},
+	"AGENCY_NOT_FOUND":                            {fiber.StatusNotFound, "Agency not found", "آژانس یافت نشد"},
+	"CREATE_DISCOUNT_FAILED":                      {fiber.StatusInternalServerError, "Failed to create discount", "ایجاد تخفیف ناموفق بود"},
+	"CUSTOMER_NOT_FOUND":                          {fiber.StatusNotFound, "Customer not found", "مشتری یافت نشد"},
+	"CUSTOMER_SUSPENDED":                          {fiber.StatusForbidden, "Your account is suspended", "حساب کاربری شما تعلیق شده است"},
+	"CUSTOMER_SUSPENSION_INVALID":                 {fiber.StatusBadRequest, "Invalid suspension level or reason", "سطح یا دلیل تعلیق نامعتبر است"},
+	"CUSTOMER_NOT_UNDER_AGENCY":                   {fiber.StatusBadRequest, "Customer is not under any agency", "مشتری زیرمجموعه هیچ آژانسی نیست"},
+	"CUSTOMER_USAGE_REPORT_FAILED":                {fiber.StatusInternalServerError, "Failed to retrieve usage report", "دریافت گزارش مصرف ناموفق بود"},
+	"DATA_EXPORT_DOWNLOAD_FAILED":                 {fiber.StatusInternalServerError, "Failed to download data export", "دریافت فایل خروجی اطلاعات ناموفق بود"},
+	"DATA_EXPORT_EXPIRED":                         {fiber.StatusGone, "Data export has expired", "مهلت دریافت خروجی اطلاعات به پایان رسیده است"},
+	"DATA_EXPORT_FORMAT_INVALID":                  {fiber.StatusBadRequest, "Export format must be json or csv", "قالب خروجی باید json یا csv باشد"},
+	"DATA_EXPORT_IN_PROGRESS":                     {fiber.StatusConflict, "A data export is already in progress", "یک خروجی اطلاعات در حال آماده‌سازی است"},
+	"DATA_EXPORT_NOT_READY":                       {fiber.StatusConflict, "Data export is not ready yet", "خروجی اطلاعات هنوز آماده نیست"},
+	"DATA_EXPORT_REQUEST_FAILED":                  {fiber.StatusInternalServerError, "Failed to queue data export", "ثبت درخواست خروجی اطلاعات ناموفق بود"},
+	"DATA_REQUEST_LOOKUP_FAILED":                  {fiber.StatusInternalServerError, "Failed to get data request", "دریافت درخواست اطلاعات ناموفق بود"},
+	"DATA_REQUEST_NOT_FOUND":                      {fiber.StatusNotFound, "Data request not found", "درخواست اطلاعات یافت نشد"},
+	"DISCOUNT_EFFECTIVE_FROM_INVALID":             {fiber.StatusBadRequest, "Effective date must be a future RFC3339 time", "تاریخ شروع تخفیف باید زمانی در آینده با قالب RFC3339 باشد"},
+	"DISCOUNT_RATE_OUT_OF_RANGE":                  {fiber.StatusBadRequest, "Rate must be between 0 and 0.5", "نرخ تخفیف باید بین ۰ و ۰٫۵ باشد"},
+	"DISCOUNT_TIERS_INVALID":                      {fiber.StatusBadRequest, "Tiers must have increasing thresholds and non-decreasing rates of at most 0.5", "آستانه‌های پله‌های تخفیف باید صعودی و نرخ آن‌ها نزولی نباشد و از ۰٫۵ بیشتر نشود"},
+	"FORCE_LOGOUT_CUSTOMER_FAILED":                {fiber.StatusInternalServerError, "Failed to end customer sessions", "پایان دادن به نشست‌های مشتری ناموفق بود"},
+	"GET_ADMIN_CUSTOMERS_LIST_FAILED":             {fiber.StatusInternalServerError, "Failed to retrieve customers list", "دریافت فهرست مشتریان ناموفق بود"},
+	"ADMIN_SEARCH_CUSTOMERS_FAILED":               {fiber.StatusInternalServerError, "Failed to search customers", "جستجوی مشتریان ناموفق بود"},
+	"GET_ADMIN_CUSTOMERS_SHARES_FAILED":           {fiber.StatusInternalServerError, "Failed to retrieve customers shares", "دریافت سهم مشتریان ناموفق بود"},
+	"GET_ADMIN_CUSTOMER_CAMPAIGNS_FAILED":         {fiber.StatusInternalServerError, "Failed to retrieve customer campaigns", "دریافت کمپین‌های مشتری ناموفق بود"},
+	"GET_ADMIN_CUSTOMER_DISCOUNTS_HISTORY_FAILED": {fiber.StatusInternalServerError, "Failed to retrieve customer discounts history", "دریافت سابقه تخفیف‌های مشتری ناموفق بود"},
+	"GET_ADMIN_CUSTOMER_FAILED":                   {fiber.StatusInternalServerError, "Failed to retrieve customer", "دریافت اطلاعات مشتری ناموفق بود"},
+	"GET_ADMIN_CUSTOMER_WITH_CAMPAIGNS_FAILED":    {fiber.StatusInternalServerError, "Failed to retrieve customer details", "دریافت جزئیات مشتری ناموفق بود"},
+	"GET_CUSTOMER_SENDING_QUOTA_FAILED":           {fiber.StatusInternalServerError, "Failed to get customer sending quota", "دریافت سهمیه ارسال مشتری ناموفق بود"},
+	"GET_PROFILE_FAILED":                          {fiber.StatusInternalServerError, "Failed to get profile", "دریافت پروفایل ناموفق بود"},
+	"INVALID_ACTING_AS_CUSTOMER":                  {fiber.StatusBadRequest, "X-Acting-As-Customer must be a positive customer ID", "X-Acting-As-Customer باید شناسه مثبت مشتری باشد"},
+	"LIST_AGENCY_DELEGATIONS_FAILED":              {fiber.StatusInternalServerError, "Failed to list agency delegations", "دریافت فهرست دسترسی‌های آژانس ناموفق بود"},
+	"LIST_DISCOUNT_SCHEDULE_FAILED":               {fiber.StatusInternalServerError, "Failed to list discount schedule", "دریافت برنامه تخفیف‌ها ناموفق بود"},
+	"UPDATE_LOCALE_FAILED":                        {fiber.StatusInternalServerError, "Failed to update locale", "به‌روزرسانی زبان ناموفق بود"},
+	"IMPERSONATE_CUSTOMER_FAILED":                 {fiber.StatusInternalServerError, "Failed to impersonate customer", "ورود به جای مشتری ناموفق بود"},
+	"INVALID_CUSTOMER_ID":                         {fiber.StatusBadRequest, "customer_id must be a positive integer", "customer_id باید عدد صحیح مثبت باشد"},
+	"LIST_ACTIVE_DISCOUNTS_FAILED":                {fiber.StatusInternalServerError, "Failed to list active discounts", "دریافت فهرست تخفیف‌های فعال ناموفق بود"},
+	"LIST_AGENCY_CUSTOMERS_FAILED":                {fiber.StatusInternalServerError, "Failed to list agency customers", "دریافت فهرست مشتریان آژانس ناموفق بود"},
+	"LIST_CUSTOMER_DISCOUNTS_FAILED":              {fiber.StatusInternalServerError, "Failed to list customer discounts", "دریافت فهرست تخفیف‌های مشتری ناموفق بود"},
+	"LIST_CUSTOMER_DISCOUNTS_HISTORY_FAILED":      {fiber.StatusInternalServerError, "Failed to list customer discounts history", "دریافت سابقه تخفیف‌های مشتری ناموفق بود"},
+	"SANDBOX_CUSTOMER_HAS_HISTORY":                {fiber.StatusConflict, "Customer already has campaigns or wallet transactions", "مشتری قبلاً کمپین یا تراکنش کیف پول داشته است"},
+	"SENDING_QUOTA_INVALID":                       {fiber.StatusBadRequest, "Invalid sending quota", "سهمیه ارسال نامعتبر است"},
+	"SET_CUSTOMER_ACTIVE_STATUS_FAILED":           {fiber.StatusInternalServerError, "Failed to set customer active status", "تغییر وضعیت فعال بودن مشتری ناموفق بود"},
+	"SET_CUSTOMER_SANDBOX_FAILED":                 {fiber.StatusInternalServerError, "Failed to update customer sandbox mode", "تغییر حالت آزمایشی مشتری ناموفق بود"},
+	"SET_CUSTOMER_SENDING_QUOTA_FAILED":           {fiber.StatusInternalServerError, "Failed to set customer sending quota", "تنظیم سهمیه ارسال مشتری ناموفق بود"},
+	"SET_CUSTOMER_SUSPENSION_FAILED":              {fiber.StatusInternalServerError, "Failed to set customer suspension", "تنظیم تعلیق مشتری ناموفق بود"},
+	"SHEBA_NUMBER_INVALID":                        {fiber.StatusBadRequest, "Sheba number is invalid", "شماره شبا نامعتبر است"},
+	"SHEBA_NUMBER_REQUIRED":                       {fiber.StatusBadRequest, "Sheba number is required", "شماره شبا الزامی است"},
+	"SYSTEM_USER_NOT_FOUND":                       {fiber.StatusNotFound, "System user not found", "کاربر سیستمی یافت نشد"},
+	"SYSTEM_USER_SHEBA_NUMBER_NOT_FOUND":          {fiber.StatusNotFound, "System user sheba number not found", "شماره شبای کاربر سیستمی یافت نشد"},
+
+	// Campaigns
+	"ADD_CAMPAIGN_REVIEW_COMMENT_FAILED":       {fiber.StatusInternalServerError, "Failed to add review comment", "ثبت نظر بررسی ناموفق بود"},
+	"ADMIN_ADD_CAMPAIGN_REVIEW_COMMENT_FAILED": {fiber.StatusInternalServerError, "Failed to add review comment", "ثبت نظر بررسی ناموفق بود"},
+	"ADMIN_APPROVE_CAMPAIGN_FAILED":            {fiber.StatusInternalServerError, "Failed to approve campaign", "تأیید کمپین ناموفق بود"},
+	"ADMIN_CANCEL_CAMPAIGN_FAILED":             {fiber.StatusInternalServerError, "Failed to cancel campaign", "لغو کمپین ناموفق بود"},
+	"ADMIN_GET_CAMPAIGN_FAILED":                {fiber.StatusInternalServerError, "Failed to get campaign", "دریافت کمپین ناموفق بود"},
+	"ADMIN_LIST_CAMPAIGNS_FAILED":              {fiber.StatusInternalServerError, "Failed to list campaigns", "دریافت فهرست کمپین‌ها ناموفق بود"},
+	"ADMIN_LIST_CAMPAIGN_REVIEWS_FAILED":       {fiber.StatusInternalServerError, "Failed to list campaign reviews", "دریافت فهرست بررسی‌های کمپین ناموفق بود"},
+	"ADMIN_CAMPAIGN_TIMELINE_FAILED":           {fiber.StatusInternalServerError, "Failed to get campaign timeline", "دریافت خط زمانی کمپین ناموفق بود"},
+	"ADMIN_REJECT_CAMPAIGN_FAILED":             {fiber.StatusInternalServerError, "Failed to reject campaign", "رد کمپین ناموفق بود"},
+	"ADMIN_REMOVE_AUDIENCE_SPEC_FAILED":        {fiber.StatusInternalServerError, "Failed to remove audience spec", "حذف مشخصات مخاطبان ناموفق بود"},
+	"ADMIN_REQUEST_CAMPAIGN_CHANGES_FAILED":    {fiber.StatusInternalServerError, "Failed to request campaign changes", "درخواست اصلاح کمپین ناموفق بود"},
+	"ADMIN_RESCHEDULE_CAMPAIGN_FAILED":         {fiber.StatusInternalServerError, "Failed to reschedule campaign", "زمان‌بندی مجدد کمپین ناموفق بود"},
+	"AUDIENCE_REPORT_NOT_AVAILABLE":            {fiber.StatusNotFound, "Audience report is not available", "گزارش مخاطبان در دسترس نیست"},
+	"AUDIENCE_SPEC_LOCK_BUSY":                  {fiber.StatusConflict, "Another worker is updating audience spec", "مشخصات مخاطبان در حال به‌روزرسانی توسط فرایند دیگری است"},
+	"BULK_CAMPAIGN_COUNT_INVALID":              {fiber.StatusBadRequest, "Bulk creation takes 1 to 100 campaigns", "ایجاد گروهی بین ۱ تا ۱۰۰ کمپین را می‌پذیرد"},
+	"BULK_CAMPAIGNS_REJECTED":                  {fiber.StatusBadRequest, "No campaign was created; see the per-campaign results", "هیچ کمپینی ایجاد نشد؛ نتیجه هر کمپین را ببینید"},
+	"BULK_OPERATION_REJECTED":                  {fiber.StatusBadRequest, "No item succeeded; see the per-item results", "هیچ موردی موفق نبود؛ نتیجه هر مورد را ببینید"},
+	"CAMPAIGN_ACCESS_DENIED":                   {fiber.StatusForbidden, "Access to this campaign is denied", "دسترسی به این کمپین مجاز نیست"},
+	"CAMPAIGN_CANCEL_NOT_ALLOWED":              {fiber.StatusForbidden, "Campaign cannot be cancelled in its current status", "کمپین در وضعیت فعلی قابل لغو نیست"},
+	"CAMPAIGN_CLICK_REPORT_EXPORT_FAILED":      {fiber.StatusInternalServerError, "Failed to export campaign click report", "تهیه خروجی گزارش کلیک کمپین ناموفق بود"},
+	"CAMPAIGN_CLONE_FAILED":                    {fiber.StatusInternalServerError, "Failed to clone campaign", "کپی کمپین ناموفق بود"},
+	"CAMPAIGN_CONTENT_UNKNOWN_VARIABLES":       {fiber.StatusBadRequest, "Campaign content uses unknown personalization variables", "متن کمپین شامل متغیرهای شخصی‌سازی ناشناخته است"},
+	"CAMPAIGN_CONTENT_VALIDATION_FAILED":       {fiber.StatusInternalServerError, "Campaign content validation failed", "بررسی متن کمپین ناموفق بود"},
+	"CAMPAIGN_CREATION_FAILED":                 {fiber.StatusInternalServerError, "Campaign creation failed", "ایجاد کمپین ناموفق بود"},
+	"CAMPAIGN_DAILY_STATS_FAILED":              {fiber.StatusInternalServerError, "Failed to retrieve campaign daily statistics", "دریافت آمار روزانه کمپین ناموفق بود"},
+	"CAMPAIGN_ESTIMATE_FAILED":                 {fiber.StatusInternalServerError, "Campaign estimate failed", "برآورد کمپین ناموفق بود"},
+	"CAMPAIGN_FETCH_FAILED":                    {fiber.StatusInternalServerError, "Failed to fetch campaign", "دریافت کمپین ناموفق بود"},
+	"CAMPAIGN_HAS_NO_VARIANTS":                 {fiber.StatusNotFound, "Campaign has no content variants", "کمپین هیچ نسخه محتوایی ندارد"},
+	"CAMPAIGN_NOT_APPROVED":                    {fiber.StatusConflict, "Campaign is not approved", "کمپین تأیید نشده است"},
+	"CAMPAIGN_NOT_FOUND":                       {fiber.StatusNotFound, "Campaign not found", "کمپین یافت نشد"},
+	"CAMPAIGN_NOT_PAUSED":                      {fiber.StatusConflict, "Campaign is not paused", "کمپین متوقف نشده است"},
+	"CAMPAIGN_NOT_RUNNING":                     {fiber.StatusConflict, "Campaign is not running", "کمپین در حال اجرا نیست"},
+	"CAMPAIGN_NOT_UNDER_REVIEW":                {fiber.StatusConflict, "Campaign is not under review", "کمپین در حال بررسی نیست"},
+	"CAMPAIGN_REPORT_EXPORT_FAILED":            {fiber.StatusInternalServerError, "Failed to export campaign report", "تهیه خروجی گزارش کمپین ناموفق بود"},
+	"CAMPAIGN_RESCHEDULE_NOT_ALLOWED":          {fiber.StatusConflict, "Campaign cannot be rescheduled in its current status", "کمپین در وضعیت فعلی قابل زمان‌بندی مجدد نیست"},
+	"CAMPAIGN_STATISTICS_UPDATE_FAILED":        {fiber.StatusInternalServerError, "Failed to update campaign statistics", "به‌روزرسانی آمار کمپین ناموفق بود"},
+	"CAMPAIGN_TEST_SEND_FAILED":                {fiber.StatusInternalServerError, "Campaign test send failed", "ارسال آزمایشی کمپین ناموفق بود"},
+	"CAMPAIGN_UPDATE_FAILED":                   {fiber.StatusInternalServerError, "Campaign update failed", "ویرایش کمپین ناموفق بود"},
+	"CAMPAIGN_UPDATE_NOT_ALLOWED":              {fiber.StatusForbidden, "Campaign cannot be updated in its current status", "کمپین در وضعیت فعلی قابل ویرایش نیست"},
+	"CAMPAIGN_UUID_INVALID":                    {fiber.StatusBadRequest, "Campaign UUID is invalid", "شناسه کمپین نامعتبر است"},
+	"CAMPAIGN_UUID_REQUIRED":                   {fiber.StatusBadRequest, "Campaign UUID is required", "شناسه کمپین الزامی است"},
+	"CAMPAIGN_VALIDATION_FAILED":               {fiber.StatusBadRequest, "Campaign validation failed", "اطلاعات کمپین معتبر نیست"},
+	"CAMPAIGN_VARIANT_STATS_FAILED":            {fiber.StatusInternalServerError, "Failed to get campaign variant statistics", "دریافت آمار نسخه‌های کمپین ناموفق بود"},
+	"CAMPAIGN_RETRY_STATS_FAILED":              {fiber.StatusInternalServerError, "Failed to get campaign retry statistics", "دریافت آمار ارسال مجدد کمپین ناموفق بود"},
+	"CAMPAIGN_OPERATOR_STATS_FAILED":           {fiber.StatusInternalServerError, "Failed to get campaign operator statistics", "دریافت آمار اپراتورهای کمپین ناموفق بود"},
+	"CAMPAIGN_VARIANT_TOO_LONG":                {fiber.StatusBadRequest, "A content variant needs more SMS parts than the first variant", "یکی از نسخه‌های محتوا به پیامک‌های بیشتری از نسخه اول نیاز دارد"},
+	"CANCEL_CAMPAIGN_FAILED":                   {fiber.StatusInternalServerError, "Cancel campaign failed", "لغو کمپین ناموفق بود"},
+	"CAPACITY_CALCULATION_FAILED":              {fiber.StatusInternalServerError, "Campaign capacity calculation failed", "محاسبه ظرفیت کمپین ناموفق بود"},
+	"CLONE_NOT_ALLOWED":                        {fiber.StatusConflict, "Clone not allowed", "کپی این کمپین مجاز نیست"},
+	"COST_CALCULATION_FAILED":                  {fiber.StatusInternalServerError, "Campaign cost calculation failed", "محاسبه هزینه کمپین ناموفق بود"},
+	"GET_CAMPAIGNS_SUMMARY_FAILED":             {fiber.StatusInternalServerError, "Failed to get campaigns summary", "دریافت خلاصه کمپین‌ها ناموفق بود"},
+	"GET_LAST_INITIATED_CAMPAIGN_FAILED":       {fiber.StatusInternalServerError, "Failed to get last initiated campaign", "دریافت آخرین کمپین ایجادشده ناموفق بود"},
+	"GET_SENDING_QUOTA_FAILED":                 {fiber.StatusInternalServerError, "Failed to get sending quota", "دریافت سهمیه ارسال ناموفق بود"},
+	"HIDE_CAMPAIGNS_FAILED":                    {fiber.StatusInternalServerError, "Failed to hide campaigns", "پنهان کردن کمپین‌ها ناموفق بود"},
+	"INSUFFICIENT_CAPACITY":                    {fiber.StatusConflict, "Insufficient campaign capacity", "ظرفیت کمپین کافی نیست"},
+	"INVALID_CAMPAIGN_ID":                      {fiber.StatusBadRequest, "Invalid campaign ID", "شناسه کمپین نامعتبر است"},
+	"INVALID_CAMPAIGN_UUID":                    {fiber.StatusBadRequest, "Campaign UUID is invalid", "شناسه کمپین نامعتبر است"},
+	"INVALID_CONTENT":                          {fiber.StatusBadRequest, "Invalid content", "محتوا نامعتبر است"},
+	"INVALID_STATE":                            {fiber.StatusConflict, "Invalid campaign state for this action", "وضعیت کمپین برای این عملیات مناسب نیست"},
+	"INVALID_TITLE":                            {fiber.StatusBadRequest, "Invalid title", "عنوان نامعتبر است"},
+	"LEVEL3_REQUIRED":                          {fiber.StatusBadRequest, "At least one level3 option is required", "انتخاب حداقل یک گزینه سطح سوم الزامی است"},
+	"LIST_AUDIENCE_SPEC_FAILED":                {fiber.StatusInternalServerError, "Failed to list audience spec", "دریافت مشخصات مخاطبان ناموفق بود"},
+	"LIST_CAMPAIGNS_FAILED":                    {fiber.StatusInternalServerError, "Failed to list campaigns", "دریافت فهرست کمپین‌ها ناموفق بود"},
+	"LIST_CAMPAIGN_REVIEWS_FAILED":             {fiber.StatusInternalServerError, "Failed to list campaign reviews", "دریافت فهرست بررسی‌های کمپین ناموفق بود"},
+	"MISSING_CAMPAIGN_ID":                      {fiber.StatusBadRequest, "Campaign ID is required", "شناسه کمپین الزامی است"},
+	"MISSING_CAMPAIGN_UUID":                    {fiber.StatusBadRequest, "Campaign UUID is required", "شناسه کمپین الزامی است"},
+	"PAUSE_CAMPAIGN_FAILED":                    {fiber.StatusInternalServerError, "Pause campaign failed", "توقف کمپین ناموفق بود"},
+	"RESET_AUDIENCE_SPEC_FAILED":               {fiber.StatusInternalServerError, "Failed to reset audience spec", "بازنشانی مشخصات مخاطبان ناموفق بود"},
+	"RESUME_CAMPAIGN_FAILED":                   {fiber.StatusInternalServerError, "Resume campaign failed", "ادامه کمپین ناموفق بود"},
+	"SCHEDULE_TIME_MUST_BE_UTC":                {fiber.StatusBadRequest, "Schedule time must be in UTC (offset +00:00)", "زمان ارسال باید به وقت UTC (با اختلاف +00:00) باشد"},
+	"SCHEDULE_TIME_OUTSIDE_WINDOW":             {fiber.StatusBadRequest, "Schedule time must be between 08:00 and 21:00 Asia/Tehran", "زمان ارسال باید بین ساعت ۰۸:۰۰ تا ۲۱:۰۰ به وقت تهران باشد"},
+	"SCHEDULE_TIME_REQUIRED":                   {fiber.StatusBadRequest, "Schedule time is required", "زمان ارسال الزامی است"},
+	"SCHEDULE_TIME_TOO_CLOSE_TO_CANCEL":        {fiber.StatusConflict, "Cancellation must happen at least 2 minutes before the scheduled time", "لغو باید حداقل ۲ دقیقه پیش از زمان ارسال انجام شود"},
+	"SCHEDULE_TIME_TOO_CLOSE_TO_CURRENT":       {fiber.StatusConflict, "Rescheduling must happen at least 5 minutes before the scheduled time", "زمان‌بندی مجدد باید حداقل ۵ دقیقه پیش از زمان ارسال انجام شود"},
+	"SCHEDULE_TIME_TOO_SOON":                   {fiber.StatusBadRequest, "Schedule time is too soon", "زمان ارسال بیش از حد نزدیک است"},
+	"SENDING_QUOTA_EXCEEDED":                   {fiber.StatusConflict, "Campaign audience exceeds the remaining sending quota", "تعداد مخاطبان کمپین از سهمیه ارسال باقی‌مانده بیشتر است"},
+	"STATISTICS_MARSHAL_FAILED":                {fiber.StatusInternalServerError, "Failed to update campaign statistics", "به‌روزرسانی آمار کمپین ناموفق بود"},
+	"TEST_RECIPIENT_MISSING":                   {fiber.StatusBadRequest, "Target phone number is missing", "شماره تلفن گیرنده وارد نشده است"},
+	"TEST_SEND_COOLDOWN_UNAVAILABLE":           {fiber.StatusServiceUnavailable, "Test send cooldown is temporarily unavailable", "ارسال آزمایشی موقتاً در دسترس نیست"},
+	"TEST_SEND_RATE_LIMITED":                   {fiber.StatusTooManyRequests, "Please wait before sending another test message", "لطفاً پیش از ارسال آزمایشی بعدی کمی صبر کنید"},
+	"TEST_SEND_STATE_NOT_ALLOWED":              {fiber.StatusConflict, "Campaign state does not allow test send", "وضعیت کمپین اجازه ارسال آزمایشی نمی‌دهد"},
+	"UNHIDE_CAMPAIGNS_FAILED":                  {fiber.StatusInternalServerError, "Failed to unhide campaigns", "نمایش مجدد کمپین‌ها ناموفق بود"},
+	"UPDATE_AUDIENCE_SPEC_FAILED":              {fiber.StatusInternalServerError, "Failed to update audience spec", "به‌روزرسانی مشخصات مخاطبان ناموفق بود"},
+	"UPDATE_CAMPAIGN_STATISTICS_FAILED":        {fiber.StatusInternalServerError, "Failed to update campaign statistics", "به‌روزرسانی آمار کمپین ناموفق بود"},
+
+	// Campaign templates, bundles and audiences
+	"AUDIENCE_IMPORT_CREATE_FAILED":           {fiber.StatusInternalServerError, "Failed to queue audience import", "ثبت درخواست ورود مخاطبان ناموفق بود"},
+	"AUDIENCE_IMPORT_FILE_INVALID":            {fiber.StatusBadRequest, "Invalid audience import file", "فایل ورود مخاطبان نامعتبر است"},
+	"AUDIENCE_IMPORT_LOOKUP_FAILED":           {fiber.StatusInternalServerError, "Failed to get audience import", "دریافت وضعیت ورود مخاطبان ناموفق بود"},
+	"AUDIENCE_IMPORT_NOT_FOUND":               {fiber.StatusNotFound, "Audience import not found", "درخواست ورود مخاطبان یافت نشد"},
+	"BLACKLISTED_NUMBER_NOT_FOUND":            {fiber.StatusNotFound, "Number is not blacklisted", "این شماره در فهرست سیاه نیست"},
+	"BLACKLIST_DELETE_FAILED":                 {fiber.StatusInternalServerError, "Failed to remove blacklisted number", "حذف شماره از فهرست سیاه ناموفق بود"},
+	"BLACKLIST_FILE_INVALID":                  {fiber.StatusBadRequest, "Invalid blacklist file", "فایل فهرست سیاه نامعتبر است"},
+	"BLACKLIST_LIST_FAILED":                   {fiber.StatusInternalServerError, "Failed to list blacklisted numbers", "دریافت فهرست سیاه ناموفق بود"},
+	"BLACKLIST_PHONE_INVALID":                 {fiber.StatusBadRequest, "Invalid phone number", "شماره تلفن نامعتبر است"},
+	"BLACKLIST_REQUEST_INVALID":               {fiber.StatusBadRequest, "Invalid blacklist request", "درخواست فهرست سیاه نامعتبر است"},
+	"BLACKLIST_UPLOAD_FAILED":                 {fiber.StatusInternalServerError, "Failed to upload blacklist", "بارگذاری فهرست سیاه ناموفق بود"},
+	"BUNDLE_ACCESS_DENIED":                    {fiber.StatusForbidden, "Bundle access denied", "دسترسی به این مجموعه مجاز نیست"},
+	"BUNDLE_NOT_FOUND":                        {fiber.StatusNotFound, "Bundle not found", "مجموعه یافت نشد"},
+	"BUNDLE_TAG_EVALUATION_ACTIVE":            {fiber.StatusConflict, "Bundle tag evaluation is already active", "ارزیابی برچسب‌های این مجموعه در حال انجام است"},
+	"CAMPAIGN_TEMPLATE_ALREADY_EXISTS":        {fiber.StatusConflict, "Campaign template with this name already exists", "قالب کمپین با این نام قبلاً ثبت شده است"},
+	"CAMPAIGN_TEMPLATE_CREATE_FAILED":         {fiber.StatusInternalServerError, "Create campaign template failed", "ایجاد قالب کمپین ناموفق بود"},
+	"CAMPAIGN_TEMPLATE_DELETE_FAILED":         {fiber.StatusInternalServerError, "Delete campaign template failed", "حذف قالب کمپین ناموفق بود"},
+	"CAMPAIGN_TEMPLATE_LIST_FAILED":           {fiber.StatusInternalServerError, "List campaign templates failed", "دریافت فهرست قالب‌های کمپین ناموفق بود"},
+	"CAMPAIGN_TEMPLATE_NAME_REQUIRED":         {fiber.StatusBadRequest, "Campaign template name is required", "نام قالب کمپین الزامی است"},
+	"CAMPAIGN_TEMPLATE_NOT_FOUND":             {fiber.StatusNotFound, "Campaign template not found", "قالب کمپین یافت نشد"},
+	"CAMPAIGN_TEMPLATE_PUBLISH_FAILED":        {fiber.StatusInternalServerError, "Publish campaign template failed", "انتشار قالب کمپین ناموفق بود"},
+	"CAMPAIGN_TEMPLATE_VALIDATION_FAILED":     {fiber.StatusBadRequest, "Campaign template validation failed", "اطلاعات قالب کمپین معتبر نیست"},
+	"CREATE_BUNDLE_FAILED":                    {fiber.StatusInternalServerError, "Failed to create bundle", "ایجاد مجموعه ناموفق بود"},
+	"CREATE_BUNDLE_VALIDATION_FAILED":         {fiber.StatusBadRequest, "Bundle validation failed", "اطلاعات مجموعه معتبر نیست"},
+	"GET_BUNDLE_FAILED":                       {fiber.StatusInternalServerError, "Failed to get bundle", "دریافت مجموعه ناموفق بود"},
+	"GET_BUNDLE_TAG_EVALUATION_STATUS_FAILED": {fiber.StatusInternalServerError, "Failed to get bundle tag evaluation status", "دریافت وضعیت ارزیابی برچسب‌های مجموعه ناموفق بود"},
+	"INVALID_BUNDLE_ID":                       {fiber.StatusBadRequest, "Invalid bundle ID", "شناسه مجموعه نامعتبر است"},
+	"LIST_BUNDLES_FAILED":                     {fiber.StatusInternalServerError, "Failed to list bundles", "دریافت فهرست مجموعه‌ها ناموفق بود"},
+	"LIST_BUNDLE_TAG_SCORES_FAILED":           {fiber.StatusInternalServerError, "Failed to list bundle tag scores", "دریافت امتیاز برچسب‌های مجموعه ناموفق بود"},
+	"REQUEST_BUNDLE_TAG_EVALUATION_FAILED":    {fiber.StatusInternalServerError, "Failed to request bundle tag evaluation", "درخواست ارزیابی برچسب‌های مجموعه ناموفق بود"},
+	"SMART_TAG_EVALUATION_DISABLED":           {fiber.StatusConflict, "Smart tag evaluation is disabled", "ارزیابی هوشمند برچسب‌ها غیرفعال است"},
+	"UPDATE_BUNDLE_FAILED":                    {fiber.StatusInternalServerError, "Failed to update bundle", "ویرایش مجموعه ناموفق بود"},
+	"UPDATE_BUNDLE_VALIDATION_FAILED":         {fiber.StatusBadRequest, "Bundle validation failed", "اطلاعات مجموعه معتبر نیست"},
+
+	// Bots
+	"ALLOCATE_SHORT_LINKS_FAILED":                {fiber.StatusInternalServerError, "Failed to allocate short links", "تخصیص لینک‌های کوتاه ناموفق بود"},
+	"AUDIENCE_UIDS_STORE_FAILED":                 {fiber.StatusInternalServerError, "Failed to store audience UIDs", "ذخیره شناسه‌های مخاطبان ناموفق بود"},
+	"BOT_AUDIENCE_SPEC_CACHE_FAILED":             {fiber.StatusInternalServerError, "Failed to update audience spec", "به‌روزرسانی مشخصات مخاطبان ناموفق بود"},
+	"BOT_AUDIENCE_SPEC_LOCK_BUSY":                {fiber.StatusConflict, "Another worker is updating audience spec", "مشخصات مخاطبان در حال به‌روزرسانی توسط فرایند دیگری است"},
+	"BOT_AUDIENCE_SPEC_LOCK_FAILED":              {fiber.StatusInternalServerError, "Failed to update audience spec", "به‌روزرسانی مشخصات مخاطبان ناموفق بود"},
+	"BOT_AUDIENCE_SPEC_MARSHAL_FAILED":           {fiber.StatusInternalServerError, "Failed to update audience spec", "به‌روزرسانی مشخصات مخاطبان ناموفق بود"},
+	"BOT_AUDIENCE_SPEC_READ_FAILED":              {fiber.StatusInternalServerError, "Failed to update audience spec", "به‌روزرسانی مشخصات مخاطبان ناموفق بود"},
+	"BOT_AUDIENCE_SPEC_WRITE_FAILED":             {fiber.StatusInternalServerError, "Failed to update audience spec", "به‌روزرسانی مشخصات مخاطبان ناموفق بود"},
+	"BOT_MOVE_CAMPAIGN_TO_EXECUTED_FAILED":       {fiber.StatusInternalServerError, "Failed to move campaign to executed", "انتقال کمپین به وضعیت اجراشده ناموفق بود"},
+	"BOT_MOVE_CAMPAIGN_TO_RUNNING_FAILED":        {fiber.StatusInternalServerError, "Failed to move campaign to running", "انتقال کمپین به وضعیت در حال اجرا ناموفق بود"},
+	"CREATE_SHORT_LINKS_FAILED":                  {fiber.StatusInternalServerError, "Failed to create short links", "ایجاد لینک‌های کوتاه ناموفق بود"},
+	"CREATE_SHORT_LINK_FAILED":                   {fiber.StatusInternalServerError, "Failed to create short link", "ایجاد لینک کوتاه ناموفق بود"},
+	"DOWNLOAD_TARGET_AUDIENCE_EXCEL_FILE_FAILED": {fiber.StatusInternalServerError, "Failed to download target audience excel file", "دریافت فایل اکسل مخاطبان هدف ناموفق بود"},
+	"LIST_READY_CAMPAIGNS_FAILED":                {fiber.StatusInternalServerError, "Failed to list ready campaigns", "دریافت فهرست کمپین‌های آماده ناموفق بود"},
+	"MOVE_TO_EXECUTED_FAILED":                    {fiber.StatusInternalServerError, "Failed to move campaign to executed", "انتقال کمپین به وضعیت اجراشده ناموفق بود"},
+	"MOVE_TO_RUNNING_FAILED":                     {fiber.StatusInternalServerError, "Failed to move campaign to running", "انتقال کمپین به وضعیت در حال اجرا ناموفق بود"},
+	"PUSH_AUDIENCE_UIDS_FAILED":                  {fiber.StatusInternalServerError, "Failed to push audience UIDs", "ارسال شناسه‌های مخاطبان ناموفق بود"},
+	"TARGET_AUDIENCE_EXCEL_FILE_NOT_FOUND":       {fiber.StatusNotFound, "Target audience excel file not found", "فایل اکسل مخاطبان هدف یافت نشد"},
+
+	// Files and multimedia
+	"ATTACHMENT_NOT_FOUND":       {fiber.StatusNotFound, "Attachment not found", "پیوست یافت نشد"},
+	"BUSINESS_LICENSE_NOT_FOUND": {fiber.StatusBadRequest, "Business license multimedia not found", "فایل مجوز کسب‌وکار یافت نشد"},
+	"DOWNLOAD_ATTACHMENT_FAILED": {fiber.StatusInternalServerError, "Failed to download attachment", "دریافت پیوست ناموفق بود"},
+	"DOWNLOAD_FAILED":            {fiber.StatusInternalServerError, "Failed to download multimedia", "دریافت فایل چندرسانه‌ای ناموفق بود"},
+	"EXCEL_FILE_INVALID":         {fiber.StatusBadRequest, "Excel file is invalid", "فایل اکسل نامعتبر است"},
+	"EXCEL_MEDIA_NOT_FOUND":      {fiber.StatusBadRequest, "Excel media not found", "فایل اکسل یافت نشد"},
+	"FILE_DOWNLOAD_FAILED":       {fiber.StatusBadRequest, "File download failed", "دریافت فایل ناموفق بود"},
+	"FILE_EMPTY":                 {fiber.StatusBadRequest, "File is empty", "فایل خالی است"},
+	"FILE_LINK_EXPIRED":          {fiber.StatusForbidden, "Download link expired", "لینک دریافت فایل منقضی شده است"},
+	"FILE_LINK_INVALID":          {fiber.StatusForbidden, "Invalid download link", "لینک دریافت فایل نامعتبر است"},
+	"FILE_NOT_FOUND":             {fiber.StatusNotFound, "File not found", "فایل یافت نشد"},
+	"FILE_READ_FAILED":           {fiber.StatusInternalServerError, "Failed to read file", "خواندن فایل ناموفق بود"},
+	"FILE_TOO_LARGE":             {fiber.StatusBadRequest, "File too large", "حجم فایل بیش از حد مجاز است"},
+	"FILE_UPLOAD_FAILED":         {fiber.StatusBadRequest, "File upload failed", "بارگذاری فایل ناموفق بود"},
+	"INVALID_ATTACHMENT_PATH":    {fiber.StatusBadRequest, "Invalid attachment path", "مسیر پیوست نامعتبر است"},
+	"INVALID_FILE":               {fiber.StatusBadRequest, "Invalid file", "فایل نامعتبر است"},
+	"INVALID_FILE_INDEX":         {fiber.StatusBadRequest, "Invalid file index", "شماره فایل نامعتبر است"},
+	"INVALID_FILE_TYPE":          {fiber.StatusBadRequest, "Invalid file type", "نوع فایل نامعتبر است"},
+	"INVALID_PATH":               {fiber.StatusBadRequest, "Invalid file path", "مسیر فایل نامعتبر است"},
+	"MEDIA_NOT_FOUND":            {fiber.StatusBadRequest, "Media not found", "فایل رسانه یافت نشد"},
+	"MULTIMEDIA_NOT_FOUND":       {fiber.StatusNotFound, "Multimedia not found", "فایل چندرسانه‌ای یافت نشد"},
+	"PREVIEW_FAILED":             {fiber.StatusInternalServerError, "Failed to generate preview", "ایجاد پیش‌نمایش ناموفق بود"},
+	"PREVIEW_NOT_SUPPORTED":      {fiber.StatusBadRequest, "Preview is not supported for this file type", "پیش‌نمایش برای این نوع فایل پشتیبانی نمی‌شود"},
+	"UPLOAD_FAILED":              {fiber.StatusInternalServerError, "Failed to upload multimedia", "بارگذاری فایل چندرسانه‌ای ناموفق بود"},
+	"VIDEO_PREVIEW_FAILED":       {fiber.StatusBadRequest, "Video preview failed", "ایجاد پیش‌نمایش ویدیو ناموفق بود"},
+	"VIDEO_PREVIEW_UNAVAILABLE":  {fiber.StatusNotImplemented, "Video preview unavailable", "پیش‌نمایش ویدیو در دسترس نیست"},
+
+	// Line numbers
+	"INVALID_TIER_ID":                        {fiber.StatusBadRequest, "Invalid tier ID", "شناسه سطح نامعتبر است"},
+	"LINE_NUMBER_ALREADY_EXISTS":             {fiber.StatusBadRequest, "Line number already exists", "این شماره خط قبلاً ثبت شده است"},
+	"LINE_NUMBER_ALREADY_RESERVED":           {fiber.StatusConflict, "Line number is already reserved by you", "این شماره خط قبلاً توسط شما رزرو شده است"},
+	"LINE_NUMBER_BATCH_UPDATE_FAILED":        {fiber.StatusInternalServerError, "Batch update failed", "به‌روزرسانی گروهی ناموفق بود"},
+	"LINE_NUMBER_CREATE_FAILED":              {fiber.StatusInternalServerError, "Create or update line number failed", "ایجاد یا ویرایش شماره خط ناموفق بود"},
+	"LINE_NUMBER_DELETED":                    {fiber.StatusConflict, "Line number is deleted; restore it instead", "این شماره خط حذف شده است؛ آن را بازیابی کنید"},
+	"LINE_NUMBER_LIST_FAILED":                {fiber.StatusInternalServerError, "List line numbers failed", "دریافت فهرست شماره خط‌ها ناموفق بود"},
+	"LINE_NUMBER_NOT_ACTIVE":                 {fiber.StatusBadRequest, "Line number is not active", "شماره خط فعال نیست"},
+	"LINE_NUMBER_NOT_APPLICABLE":             {fiber.StatusBadRequest, "Line number is only applicable for sms campaigns", "شماره خط فقط برای کمپین‌های پیامکی کاربرد دارد"},
+	"LINE_NUMBER_NOT_FOUND":                  {fiber.StatusNotFound, "Line number not found", "شماره خط یافت نشد"},
+	"LINE_NUMBER_NOT_RESERVABLE":             {fiber.StatusBadRequest, "Line number cannot be reserved", "این شماره خط قابل رزرو نیست"},
+	"LINE_NUMBER_REPORT_FAILED":              {fiber.StatusInternalServerError, "Report generation failed", "تهیه گزارش ناموفق بود"},
+	"LINE_NUMBER_REQUIRED":                   {fiber.StatusBadRequest, "Line number is required for sms campaigns", "برای کمپین‌های پیامکی انتخاب شماره خط الزامی است"},
+	"LINE_NUMBER_RESERVATION_DAYS_INVALID":   {fiber.StatusBadRequest, "Invalid reservation days", "تعداد روزهای رزرو نامعتبر است"},
+	"LINE_NUMBER_RESERVATION_NOT_ACTIVE":     {fiber.StatusConflict, "Line number reservation is not active", "رزرو شماره خط فعال نیست"},
+	"LINE_NUMBER_RESERVATION_NOT_FOUND":      {fiber.StatusNotFound, "Line number reservation not found", "رزرو شماره خط یافت نشد"},
+	"LINE_NUMBER_RESERVED":                   {fiber.StatusConflict, "Line number is reserved by another customer", "این شماره خط توسط مشتری دیگری رزرو شده است"},
+	"LINE_NUMBER_TIER_ALREADY_EXISTS":        {fiber.StatusConflict, "Line number tier already exists", "این سطح شماره خط قبلاً ثبت شده است"},
+	"LINE_NUMBER_TIER_CREATE_FAILED":         {fiber.StatusInternalServerError, "Create line number tier failed", "ایجاد سطح شماره خط ناموفق بود"},
+	"LINE_NUMBER_TIER_LIST_FAILED":           {fiber.StatusInternalServerError, "List line number tiers failed", "دریافت فهرست سطوح شماره خط ناموفق بود"},
+	"LINE_NUMBER_TIER_MAX_DAYS_INVALID":      {fiber.StatusBadRequest, "Max reservation days must be greater than zero", "حداکثر روزهای رزرو باید بیشتر از صفر باشد"},
+	"LINE_NUMBER_TIER_NOT_FOUND":             {fiber.StatusNotFound, "Line number tier not found", "سطح شماره خط یافت نشد"},
+	"LINE_NUMBER_TIER_UPDATE_FAILED":         {fiber.StatusInternalServerError, "Update line number tier failed", "ویرایش سطح شماره خط ناموفق بود"},
+	"LINE_NUMBER_VALUE_REQUIRED":             {fiber.StatusBadRequest, "Line number is required", "شماره خط الزامی است"},
+	"LIST_ACTIVE_LINE_NUMBERS_FAILED":        {fiber.StatusInternalServerError, "Failed to list active line numbers", "دریافت فهرست شماره خط‌های فعال ناموفق بود"},
+	"LIST_AVAILABLE_LINE_NUMBERS_FAILED":     {fiber.StatusInternalServerError, "Failed to list available line numbers", "دریافت فهرست شماره خط‌های قابل رزرو ناموفق بود"},
+	"LIST_LINE_NUMBER_RESERVATIONS_FAILED":   {fiber.StatusInternalServerError, "Failed to list line number reservations", "دریافت فهرست رزروهای شماره خط ناموفق بود"},
+	"RELEASE_LINE_NUMBER_RESERVATION_FAILED": {fiber.StatusInternalServerError, "Failed to release line number reservation", "آزادسازی رزرو شماره خط ناموفق بود"},
+	"RESERVE_LINE_NUMBER_FAILED":             {fiber.StatusInternalServerError, "Failed to reserve line number", "رزرو شماره خط ناموفق بود"},
+
+	// Pricing and platform settings
+	"INVALID_PLATFORM":                            {fiber.StatusBadRequest, "Invalid platform", "پلتفرم نامعتبر است"},
+	"INVALID_PLATFORM_SETTINGS_STATUS":            {fiber.StatusBadRequest, "Invalid platform settings status", "وضعیت تنظیمات پلتفرم نامعتبر است"},
+	"PAGE_PRICE_INSERT_FAILED":                    {fiber.StatusInternalServerError, "Failed to update page price", "به‌روزرسانی قیمت صفحه ناموفق بود"},
+	"PAGE_PRICE_INVALID":                          {fiber.StatusBadRequest, "Invalid page price", "قیمت صفحه نامعتبر است"},
+	"PAGE_PRICE_LIST_FAILED":                      {fiber.StatusInternalServerError, "Failed to get page prices", "دریافت قیمت صفحات ناموفق بود"},
+	"PAGE_PRICE_NOT_FOUND":                        {fiber.StatusBadRequest, "Page price not found", "قیمت صفحه یافت نشد"},
+	"PAGE_PRICE_PLATFORM_INVALID":                 {fiber.StatusBadRequest, "Invalid page price platform", "پلتفرم قیمت صفحه نامعتبر است"},
+	"PAGE_PRICE_PLATFORM_REQUIRED":                {fiber.StatusBadRequest, "Page price platform is required", "پلتفرم قیمت صفحه الزامی است"},
+	"PLATFORM_BASE_PRICE_INVALID":                 {fiber.StatusBadRequest, "Invalid platform base price", "قیمت پایه پلتفرم نامعتبر است"},
+	"PLATFORM_BASE_PRICE_LIST_FAILED":             {fiber.StatusInternalServerError, "Failed to list platform base prices", "دریافت فهرست قیمت‌های پایه پلتفرم ناموفق بود"},
+	"PLATFORM_BASE_PRICE_NOT_FOUND":               {fiber.StatusNotFound, "Platform base price not found", "قیمت پایه پلتفرم یافت نشد"},
+	"PLATFORM_BASE_PRICE_PLATFORM_INVALID":        {fiber.StatusBadRequest, "Invalid platform base price platform", "پلتفرم قیمت پایه نامعتبر است"},
+	"PLATFORM_BASE_PRICE_PLATFORM_REQUIRED":       {fiber.StatusBadRequest, "Platform base price platform is required", "پلتفرم قیمت پایه الزامی است"},
+	"PLATFORM_BASE_PRICE_UPDATE_FAILED":           {fiber.StatusInternalServerError, "Failed to update platform base price", "به‌روزرسانی قیمت پایه پلتفرم ناموفق بود"},
+	"PLATFORM_SETTINGS_CREATE_FAILED":             {fiber.StatusInternalServerError, "Failed to create platform settings", "ایجاد تنظیمات پلتفرم ناموفق بود"},
+	"PLATFORM_SETTINGS_DUPLICATE_CHECK_FAILED":    {fiber.StatusInternalServerError, "Failed to check duplicate platform settings name", "بررسی تکراری بودن نام تنظیمات پلتفرم ناموفق بود"},
+	"PLATFORM_SETTINGS_ID_REQUIRED":               {fiber.StatusBadRequest, "Platform settings ID is required", "شناسه تنظیمات پلتفرم الزامی است"},
+	"PLATFORM_SETTINGS_INVALID":                   {fiber.StatusBadRequest, "Platform settings are invalid for test send", "تنظیمات پلتفرم برای ارسال آزمایشی معتبر نیست"},
+	"PLATFORM_SETTINGS_LIST_FAILED":               {fiber.StatusInternalServerError, "Failed to list platform settings", "دریافت فهرست تنظیمات پلتفرم ناموفق بود"},
+	"PLATFORM_SETTINGS_LOOKUP_FAILED":             {fiber.StatusInternalServerError, "Failed to look up platform settings", "دریافت تنظیمات پلتفرم ناموفق بود"},
+	"PLATFORM_SETTINGS_METADATA_KEY_REQUIRED":     {fiber.StatusBadRequest, "Platform settings metadata key is required", "کلید فراداده تنظیمات پلتفرم الزامی است"},
+	"PLATFORM_SETTINGS_METADATA_UPDATE_FAILED":    {fiber.StatusInternalServerError, "Failed to update platform settings metadata", "به‌روزرسانی فراداده تنظیمات پلتفرم ناموفق بود"},
+	"PLATFORM_SETTINGS_NAME_ALREADY_EXISTS":       {fiber.StatusConflict, "Platform settings name already exists", "تنظیمات پلتفرم با این نام قبلاً ثبت شده است"},
+	"PLATFORM_SETTINGS_NOT_APPLICABLE":            {fiber.StatusBadRequest, "Platform settings is only applicable for non-sms campaigns", "تنظیمات پلتفرم فقط برای کمپین‌های غیرپیامکی کاربرد دارد"},
+	"PLATFORM_SETTINGS_NOT_FOUND":                 {fiber.StatusNotFound, "Platform settings not found", "تنظیمات پلتفرم یافت نشد"},
+	"PLATFORM_SETTINGS_REQUIRED":                  {fiber.StatusBadRequest, "Platform settings is required for non-sms campaigns", "برای کمپین‌های غیرپیامکی انتخاب تنظیمات پلتفرم الزامی است"},
+	"PLATFORM_SETTINGS_STATUS_CHANGE_NOT_ALLOWED": {fiber.StatusBadRequest, "Platform settings status change is not allowed", "تغییر وضعیت تنظیمات پلتفرم مجاز نیست"},
+	"PLATFORM_SETTINGS_STATUS_UPDATE_FAILED":      {fiber.StatusInternalServerError, "Failed to change platform settings status", "تغییر وضعیت تنظیمات پلتفرم ناموفق بود"},
+	"PRICE_FACTOR_INVALID":                        {fiber.StatusBadRequest, "Price factor must be greater than zero", "ضریب قیمت باید بیشتر از صفر باشد"},
+	"SEGMENT_PRICE_FACTOR_CREATE_FAILED":          {fiber.StatusInternalServerError, "Create segment price factor failed", "ایجاد ضریب قیمت بخش ناموفق بود"},
+	"SEGMENT_PRICE_FACTOR_LEVEL3_LIST_FAILED":     {fiber.StatusInternalServerError, "List level3 options failed", "دریافت فهرست گزینه‌های سطح سوم ناموفق بود"},
+	"SEGMENT_PRICE_FACTOR_LIST_FAILED":            {fiber.StatusInternalServerError, "List segment price factors failed", "دریافت فهرست ضرایب قیمت بخش ناموفق بود"},
+	"SEGMENT_PRICE_FACTOR_NOT_FOUND":              {fiber.StatusBadRequest, "Segment price factor not found", "ضریب قیمت بخش یافت نشد"},
+	"SMS_TARIFF_ALREADY_EXISTS":                   {fiber.StatusConflict, "An SMS tariff with the same dimensions already exists", "تعرفه پیامک با همین مشخصات قبلاً ثبت شده است"},
+	"SMS_TARIFF_CREATE_FAILED":                    {fiber.StatusInternalServerError, "Create SMS tariff failed", "ایجاد تعرفه پیامک ناموفق بود"},
+	"SMS_TARIFF_DELETE_FAILED":                    {fiber.StatusInternalServerError, "Delete SMS tariff failed", "حذف تعرفه پیامک ناموفق بود"},
+	"SMS_TARIFF_LIST_FAILED":                      {fiber.StatusInternalServerError, "List SMS tariffs failed", "دریافت فهرست تعرفه‌های پیامک ناموفق بود"},
+	"SMS_TARIFF_NOT_FOUND":                        {fiber.StatusNotFound, "SMS tariff not found", "تعرفه پیامک یافت نشد"},
+	"SMS_TARIFF_OPERATOR_INVALID":                 {fiber.StatusBadRequest, "Invalid SMS operator", "اپراتور پیامک نامعتبر است"},
+	"SMS_TARIFF_PRICE_INVALID":                    {fiber.StatusBadRequest, "Price per part must be greater than zero", "قیمت هر بخش پیامک باید بیشتر از صفر باشد"},
+	"SMS_TARIFF_UPDATE_FAILED":                    {fiber.StatusInternalServerError, "Update SMS tariff failed", "ویرایش تعرفه پیامک ناموفق بود"},
+
+	// Payments and wallets
+	"ADMIN_ADD_INVOICE_FAILED":                   {fiber.StatusInternalServerError, "Failed to add invoice to transaction", "افزودن فاکتور به تراکنش ناموفق بود"},
+	"ADMIN_DOWNLOAD_RECEIPT_FAILED":              {fiber.StatusInternalServerError, "Failed to download receipt file", "دریافت فایل رسید ناموفق بود"},
+	"ADMIN_FINANCIAL_REPORT_FAILED":              {fiber.StatusInternalServerError, "Failed to retrieve financial report", "دریافت گزارش مالی ناموفق بود"},
+	"ADMIN_LIST_AGENCY_PAYABLES_FAILED":          {fiber.StatusInternalServerError, "Failed to list agency payables", "دریافت فهرست بدهی‌های نمایندگی ناموفق بود"},
+	"ADMIN_LIST_DEPOSIT_RECEIPTS_FAILED":         {fiber.StatusInternalServerError, "Failed to list deposit receipts", "دریافت فهرست رسیدهای واریز ناموفق بود"},
+	"ADMIN_LIST_TRANSACTIONS_FAILED":             {fiber.StatusInternalServerError, "Failed to list transactions", "دریافت فهرست تراکنش‌ها ناموفق بود"},
+	"ADMIN_MARK_AGENCY_PAYABLE_PAID_FAILED":      {fiber.StatusInternalServerError, "Failed to mark agency payable paid", "ثبت پرداخت بدهی نمایندگی ناموفق بود"},
+	"ADMIN_TOP_CUSTOMERS_REPORT_FAILED":          {fiber.StatusInternalServerError, "Failed to retrieve top customers report", "دریافت گزارش مشتریان برتر ناموفق بود"},
+	"ADMIN_UPDATE_RECEIPT_FAILED":                {fiber.StatusInternalServerError, "Failed to update receipt status", "به‌روزرسانی وضعیت رسید ناموفق بود"},
+	"ADMIN_WALLET_LIABILITY_REPORT_FAILED":       {fiber.StatusInternalServerError, "Failed to retrieve wallet liability", "دریافت گزارش بدهی کیف پول‌ها ناموفق بود"},
+	"AGENCY_PAYABLE_ALREADY_PAID":                {fiber.StatusConflict, "Agency payable was already paid", "بدهی نمایندگی قبلاً پرداخت شده است"},
+	"AGENCY_PAYABLE_NOT_FOUND":                   {fiber.StatusNotFound, "Agency payable not found", "بدهی نمایندگی یافت نشد"},
+	"AMOUNT_NOT_MULTIPLE":                        {fiber.StatusBadRequest, "Amount must be a multiple of the required increment", "مبلغ باید مضربی از واحد تعیین‌شده باشد"},
+	"AMOUNT_TOO_LOW":                             {fiber.StatusBadRequest, "Amount is too low", "مبلغ کمتر از حد مجاز است"},
+	"ATIPAY_RECONCILIATION_FAILED":               {fiber.StatusInternalServerError, "Failed to reconcile settlement report", "تطبیق گزارش تسویه ناموفق بود"},
+	"ATIPAY_RECONCILIATION_REPORT_FAILED":        {fiber.StatusInternalServerError, "Failed to get reconciliation report", "دریافت گزارش مغایرت ناموفق بود"},
+	"ATIPAY_REPORT_DATE_INVALID":                 {fiber.StatusBadRequest, "Report date must be a past day formatted as YYYY-MM-DD", "تاریخ گزارش باید روزی گذشته با قالب YYYY-MM-DD باشد"},
+	"ATIPAY_REPORT_FILE_INVALID":                 {fiber.StatusBadRequest, "Invalid settlement report file", "فایل گزارش تسویه نامعتبر است"},
+	"ATIPAY_TOKEN_ERROR":                         {fiber.StatusInternalServerError, "Failed to get payment token", "دریافت توکن پرداخت ناموفق بود"},
+	"BALANCE_SNAPSHOT_NOT_FOUND":                 {fiber.StatusNotFound, "Balance snapshot not found", "وضعیت موجودی یافت نشد"},
+	"CALLBACK_REQUEST_NIL":                       {fiber.StatusBadRequest, "Callback request is required", "اطلاعات بازگشت از درگاه الزامی است"},
+	"CAMPAIGN_DEBIT_TRANSACTION_NOT_FOUND":       {fiber.StatusConflict, "Campaign debit transaction not found", "تراکنش برداشت کمپین یافت نشد"},
+	"CRYPTO_OPERATION_FAILED":                    {fiber.StatusInternalServerError, "Crypto payment operation failed", "عملیات پرداخت رمزارزی ناموفق بود"},
+	"CRYPTO_RATES_DIVERGED":                      {fiber.StatusServiceUnavailable, "Exchange rates are unstable, try again later", "نرخ‌های تبدیل ناپایدار است، بعداً دوباره تلاش کنید"},
+	"CRYPTO_RATE_UNAVAILABLE":                    {fiber.StatusServiceUnavailable, "Exchange rate unavailable, try again later", "نرخ تبدیل در دسترس نیست، بعداً دوباره تلاش کنید"},
+	"DEPOSIT_RECEIPT_BANK_REFERENCE_REQUIRED":    {fiber.StatusBadRequest, "bank_reference is required when approving a receipt", "bank_reference برای تأیید رسید الزامی است"},
+	"DEPOSIT_RECEIPT_BANK_REFERENCE_USED":        {fiber.StatusConflict, "Bank statement entry is already matched with another receipt", "این ردیف صورت‌حساب بانکی قبلاً با رسید دیگری تطبیق داده شده است"},
+	"DEPOSIT_RECEIPT_MATCHED_AMOUNT_TOO_HIGH":    {fiber.StatusBadRequest, "matched_amount cannot be above the receipt's amount", "مبلغ تطبیق‌داده‌شده نمی‌تواند بیشتر از مبلغ رسید باشد"},
+	"DEPOSIT_RECEIPT_TRACKING_NUMBER_INVALID":    {fiber.StatusBadRequest, "Tracking number is too short", "شماره پیگیری بیش از حد کوتاه است"},
+	"DEPOSIT_RECEIPT_TRACKING_NUMBER_USED":       {fiber.StatusConflict, "A receipt with this tracking number was already submitted", "رسیدی با این شماره پیگیری قبلاً ثبت شده است"},
+	"DEPOSIT_RECEIPT_TRANSFER_DATE_INVALID":      {fiber.StatusBadRequest, "Transfer date must be formatted as YYYY-MM-DD and not be in the future", "تاریخ واریز باید به صورت YYYY-MM-DD و حداکثر تاریخ امروز باشد"},
+	"FREEZE_TRANSACTION_NOT_FOUND":               {fiber.StatusConflict, "Freeze transaction not found", "تراکنش مسدودسازی یافت نشد"},
+	"FROZEN_BUDGET_AUDIT_FAILED":                 {fiber.StatusInternalServerError, "Failed to audit frozen budgets", "بررسی بودجه‌های مسدودشده ناموفق بود"},
+	"GET_CUSTOMER_CREDIT_LINE_FAILED":            {fiber.StatusInternalServerError, "Failed to get customer credit line", "دریافت خط اعتباری مشتری ناموفق بود"},
+	"GET_SANDBOX_STATUS_FAILED":                  {fiber.StatusInternalServerError, "Failed to get sandbox status", "دریافت وضعیت حالت آزمایشی ناموفق بود"},
+	"GET_POSTPAID_SUMMARY_FAILED":                {fiber.StatusInternalServerError, "Failed to get postpaid summary", "دریافت خلاصه پرداخت اعتباری ناموفق بود"},
+	"HTML_GENERATION_FAILED":                     {fiber.StatusInternalServerError, "Failed to generate payment result page", "ایجاد صفحه نتیجه پرداخت ناموفق بود"},
+	"INSUFFICIENT_FUNDS":                         {fiber.StatusConflict, "Insufficient funds", "موجودی کافی نیست"},
+	"INVALID_AMOUNT":                             {fiber.StatusBadRequest, "Invalid amount", "مبلغ نامعتبر است"},
+	"INVALID_RECEIPT":                            {fiber.StatusBadRequest, "Invalid receipt UUID", "شناسه رسید نامعتبر است"},
+	"INVALID_RECEIPT_ACTION":                     {fiber.StatusBadRequest, "action must be either approve or reject", "action باید approve یا reject باشد"},
+	"INVALID_TRANSACTION_UUID":                   {fiber.StatusBadRequest, "Invalid transaction UUID", "شناسه تراکنش نامعتبر است"},
+	"INVOICE_ALREADY_ASSIGNED":                   {fiber.StatusConflict, "Invoice is already linked to this transaction", "فاکتور قبلاً به این تراکنش متصل شده است"},
+	"INVOICE_ISSUE_REQUEST_FAILED":               {fiber.StatusInternalServerError, "Failed to submit invoice issue request", "ثبت درخواست صدور فاکتور ناموفق بود"},
+	"INVOICE_ISSUE_REQUEST_RATE_LIMITED":         {fiber.StatusConflict, "You have already submitted an invoice issue request in the last 24 hours. Please try again later.", "در ۲۴ ساعت گذشته درخواست صدور فاکتور ثبت کرده‌اید. لطفاً بعداً دوباره تلاش کنید."},
+	"INVOICE_UUID_ALREADY_USED":                  {fiber.StatusConflict, "customer_invoice_uuid is already linked to another payment", "customer_invoice_uuid قبلاً به پرداخت دیگری متصل شده است"},
+	"INVOICE_UUID_INVALID":                       {fiber.StatusBadRequest, "customer_invoice_uuid is invalid", "customer_invoice_uuid نامعتبر است"},
+	"INVOICE_UUID_MISMATCH":                      {fiber.StatusConflict, "customer_invoice_uuid conflicts with existing transaction metadata", "customer_invoice_uuid با اطلاعات تراکنش موجود مغایرت دارد"},
+	"INVOICE_UUID_REQUIRED":                      {fiber.StatusBadRequest, "customer_invoice_uuid is required when approving a receipt", "برای تأیید رسید، customer_invoice_uuid الزامی است"},
+	"LIST_DEPOSIT_RECEIPTS_FAILED":               {fiber.StatusInternalServerError, "Failed to list deposit receipts", "دریافت فهرست رسیدهای واریز ناموفق بود"},
+	"MULTIPLE_CAMPAIGN_DEBIT_TRANSACTIONS_FOUND": {fiber.StatusConflict, "Multiple campaign debit transactions found", "چند تراکنش برداشت برای کمپین یافت شد"},
+	"MULTIPLE_FREEZE_TRANSACTIONS_FOUND":         {fiber.StatusConflict, "Multiple freeze transactions found", "چند تراکنش مسدودسازی یافت شد"},
+	"PAYMENT_ALREADY_PROCESSED":                  {fiber.StatusConflict, "Payment already processed", "این پرداخت قبلاً پردازش شده است"},
+	"PAYMENT_CALLBACK_FAILED":                    {fiber.StatusInternalServerError, "Payment callback processing failed", "پردازش بازگشت از درگاه پرداخت ناموفق بود"},
+	"PAYMENT_CALLBACK_VALIDATION_FAILED":         {fiber.StatusBadRequest, "Payment callback validation failed", "اطلاعات بازگشت از درگاه پرداخت معتبر نیست"},
+	"PAYMENT_GATEWAY_UNAVAILABLE":                {fiber.StatusServiceUnavailable, "Payment gateway is unavailable, try again later", "درگاه پرداخت در دسترس نیست، بعداً دوباره تلاش کنید"},
+	"PAYMENT_REQUEST_EXPIRED":                    {fiber.StatusConflict, "Payment request expired", "درخواست پرداخت منقضی شده است"},
+	"PAYMENT_REQUEST_NOT_FOUND":                  {fiber.StatusNotFound, "Payment request not found", "درخواست پرداخت یافت نشد"},
+	"POSTPAID_INVOICE_ALREADY_PAID":              {fiber.StatusConflict, "Postpaid invoice was already paid", "صورتحساب اعتباری قبلاً پرداخت شده است"},
+	"POSTPAID_INVOICE_LIST_FAILED":               {fiber.StatusInternalServerError, "Failed to list postpaid invoices", "دریافت فهرست صورتحساب‌های اعتباری ناموفق بود"},
+	"POSTPAID_INVOICE_NOT_FOUND":                 {fiber.StatusNotFound, "Postpaid invoice not found", "صورتحساب اعتباری یافت نشد"},
+	"POSTPAID_INVOICE_OVERDUE":                   {fiber.StatusForbidden, "Pay the overdue postpaid invoice before creating new campaigns", "پیش از ایجاد کمپین جدید، صورتحساب اعتباری سررسیدگذشته را پرداخت کنید"},
+	"POSTPAID_INVOICE_PAY_FAILED":                {fiber.StatusInternalServerError, "Failed to mark postpaid invoice paid", "ثبت پرداخت صورتحساب اعتباری ناموفق بود"},
+	"POSTPAID_STANDING_CHECK_FAILED":             {fiber.StatusInternalServerError, "Failed to check postpaid invoices", "بررسی صورتحساب‌های اعتباری ناموفق بود"},
+	"PROFORMA_PREVIEW_FAILED":                    {fiber.StatusInternalServerError, "Failed to preview proforma invoice", "پیش‌نمایش پیش‌فاکتور ناموفق بود"},
+	"RECEIPT_ALREADY_APPROVED":                   {fiber.StatusConflict, "Receipt already approved", "این رسید قبلاً تأیید شده است"},
+	"RECEIPT_ALREADY_REJECTED":                   {fiber.StatusConflict, "Receipt already rejected", "این رسید قبلاً رد شده است"},
+	"RECEIPT_DOWNLOAD_FAILED":                    {fiber.StatusInternalServerError, "Failed to download receipt", "دریافت رسید ناموفق بود"},
+	"RECEIPT_FILE_DELETE_FAILED":                 {fiber.StatusInternalServerError, "Failed to delete receipt file", "حذف فایل رسید ناموفق بود"},
+	"RECEIPT_FILE_EMPTY":                         {fiber.StatusNotFound, "Receipt file not available", "فایل رسید در دسترس نیست"},
+	"RECEIPT_FILE_UPDATE_FAILED":                 {fiber.StatusInternalServerError, "Failed to update receipt file", "به‌روزرسانی فایل رسید ناموفق بود"},
+	"RECEIPT_FINALIZED":                          {fiber.StatusConflict, "Receipt already finalized", "وضعیت این رسید قبلاً نهایی شده است"},
+	"RECEIPT_NOT_FOUND":                          {fiber.StatusNotFound, "Receipt not found", "رسید یافت نشد"},
+	"REFERENCE_NUMBER_REQUIRED":                  {fiber.StatusBadRequest, "Reference number is required", "شماره مرجع الزامی است"},
+	"REPORT_ROLLUP_REFRESH_FAILED":               {fiber.StatusInternalServerError, "Failed to refresh reporting rollups", "به‌روزرسانی جداول خلاصه گزارش‌ها ناموفق بود"},
+	"RESERVATION_NUMBER_REQUIRED":                {fiber.StatusBadRequest, "Reservation number is required", "شماره رزرو الزامی است"},
+	"SANDBOX_NOT_ENABLED":                        {fiber.StatusForbidden, "Customer is not a sandbox account", "حساب مشتری در حالت آزمایشی نیست"},
+	"SANDBOX_PURGE_FAILED":                       {fiber.StatusInternalServerError, "Failed to purge sandbox data", "پاک‌سازی داده‌های آزمایشی ناموفق بود"},
+	"SANDBOX_REAL_PAYMENT":                       {fiber.StatusForbidden, "Sandbox accounts cannot make real payments", "حساب‌های آزمایشی امکان پرداخت واقعی ندارند"},
+	"SANDBOX_TOP_UP_FAILED":                      {fiber.StatusInternalServerError, "Failed to top up sandbox wallet", "شارژ کیف پول آزمایشی ناموفق بود"},
+	"SET_CUSTOMER_CREDIT_LIMIT_FAILED":           {fiber.StatusInternalServerError, "Failed to set customer credit limit", "تنظیم سقف اعتبار مشتری ناموفق بود"},
+	"STATE_REQUIRED":                             {fiber.StatusBadRequest, "State is required", "وضعیت الزامی است"},
+	"SUBMIT_DEPOSIT_RECEIPT_FAILED":              {fiber.StatusInternalServerError, "Submit deposit receipt failed", "ثبت رسید واریز ناموفق بود"},
+	"SYSTEM_WALLET_BALANCE_SNAPSHOT_NOT_FOUND":   {fiber.StatusNotFound, "System wallet balance snapshot not found", "وضعیت موجودی کیف پول سیستم یافت نشد"},
+	"SYSTEM_WALLET_NOT_FOUND":                    {fiber.StatusNotFound, "System wallet not found", "کیف پول سیستم یافت نشد"},
+	"TAX_INVOICE_DOWNLOAD_FAILED":                {fiber.StatusInternalServerError, "Failed to download invoice", "دریافت فایل صورتحساب ناموفق بود"},
+	"TAX_INVOICE_LIST_FAILED":                    {fiber.StatusInternalServerError, "Failed to list invoices", "دریافت فهرست صورتحساب‌ها ناموفق بود"},
+	"TAX_INVOICE_NOT_FOUND":                      {fiber.StatusNotFound, "Invoice not found", "صورتحساب یافت نشد"},
+	"TAX_INVOICE_PDF_UNAVAILABLE":                {fiber.StatusServiceUnavailable, "Invoice PDFs are not available", "فایل PDF صورتحساب در حال حاضر در دسترس نیست"},
+	"TAX_WALLET_BALANCE_SNAPSHOT_NOT_FOUND":      {fiber.StatusNotFound, "Tax wallet balance snapshot not found", "وضعیت موجودی کیف پول مالیات یافت نشد"},
+	"TAX_WALLET_NOT_FOUND":                       {fiber.StatusNotFound, "Tax wallet not found", "کیف پول مالیات یافت نشد"},
+	"TRANSACTION_HISTORY_RETRIEVAL_FAILED":       {fiber.StatusInternalServerError, "Failed to retrieve transaction history", "دریافت تاریخچه تراکنش‌ها ناموفق بود"},
+	"TRANSACTION_NOT_FOUND":                      {fiber.StatusNotFound, "Transaction not found", "تراکنش یافت نشد"},
+	"TRANSACTION_UUID_INVALID":                   {fiber.StatusBadRequest, "transaction_uuid is invalid", "transaction_uuid نامعتبر است"},
+	"UNSUPPORTED_PLATFORM":                       {fiber.StatusBadRequest, "Unsupported platform", "پلتفرم پشتیبانی نمی‌شود"},
+	"WALLET_ADJUSTMENT_CREATE_FAILED":            {fiber.StatusInternalServerError, "Failed to request wallet adjustment", "ثبت درخواست اصلاح کیف پول ناموفق بود"},
+	"WALLET_ADJUSTMENT_LIST_FAILED":              {fiber.StatusInternalServerError, "Failed to list wallet adjustments", "دریافت فهرست اصلاحات کیف پول ناموفق بود"},
+	"WALLET_ADJUSTMENT_NOT_FOUND":                {fiber.StatusNotFound, "Wallet adjustment not found", "درخواست اصلاح کیف پول یافت نشد"},
+	"WALLET_ADJUSTMENT_NOT_PENDING":              {fiber.StatusConflict, "Wallet adjustment was already reviewed", "درخواست اصلاح کیف پول قبلاً بررسی شده است"},
+	"WALLET_ADJUSTMENT_REASON_INVALID":           {fiber.StatusBadRequest, "Reason does not apply to this direction", "دلیل انتخاب‌شده برای این نوع اصلاح مجاز نیست"},
+	"WALLET_ADJUSTMENT_REVIEW_FAILED":            {fiber.StatusInternalServerError, "Failed to review wallet adjustment", "بررسی درخواست اصلاح کیف پول ناموفق بود"},
+	"WALLET_ADJUSTMENT_SELF_REVIEW":              {fiber.StatusForbidden, "Wallet adjustment must be reviewed by another admin", "درخواست اصلاح کیف پول باید توسط مدیر دیگری بررسی شود"},
+	"WALLET_TRANSFER_AMOUNT_OUT_OF_RANGE":        {fiber.StatusBadRequest, "Transfer amount is outside the allowed range", "مبلغ انتقال خارج از محدوده مجاز است"},
+	"WALLET_TRANSFER_CONFIRM_FAILED":             {fiber.StatusInternalServerError, "Failed to confirm wallet transfer", "تأیید انتقال کیف پول ناموفق بود"},
+	"WALLET_TRANSFER_DAILY_LIMIT_EXCEEDED":       {fiber.StatusConflict, "Transfer exceeds the daily transfer limit", "مبلغ انتقال از سقف روزانه انتقال بیشتر است"},
+	"WALLET_TRANSFER_DISABLED":                   {fiber.StatusConflict, "Wallet transfers are disabled", "انتقال بین کیف پول‌ها غیرفعال است"},
+	"WALLET_TRANSFER_INITIATE_FAILED":            {fiber.StatusInternalServerError, "Failed to start wallet transfer", "شروع انتقال کیف پول ناموفق بود"},
+	"WALLET_TRANSFER_LIST_FAILED":                {fiber.StatusInternalServerError, "Failed to list wallet transfers", "دریافت فهرست انتقال‌های کیف پول ناموفق بود"},
+	"WALLET_TRANSFER_LOCKED":                     {fiber.StatusTooManyRequests, "Too many wrong codes; start a new transfer", "تعداد کدهای نادرست بیش از حد مجاز است؛ انتقال جدیدی شروع کنید"},
+	"WALLET_TRANSFER_NOT_FOUND":                  {fiber.StatusNotFound, "Wallet transfer not found", "انتقال کیف پول یافت نشد"},
+	"WALLET_TRANSFER_NOT_PENDING":                {fiber.StatusConflict, "Wallet transfer is no longer pending", "انتقال کیف پول دیگر در انتظار تأیید نیست"},
+	"WALLET_TRANSFER_OTP_EXPIRED":                {fiber.StatusBadRequest, "Wallet transfer code expired; start a new transfer", "کد انتقال کیف پول منقضی شده است؛ انتقال جدیدی شروع کنید"},
+	"WALLET_TRANSFER_OTP_INVALID":                {fiber.StatusBadRequest, "Invalid wallet transfer code", "کد انتقال کیف پول نادرست است"},
+	"WALLET_TRANSFER_RECEIVER_NOT_ELIGIBLE":      {fiber.StatusBadRequest, "Receiver is not an account of the same company", "حساب گیرنده متعلق به همان شرکت نیست"},
+	"WALLET_TRANSFER_SANDBOX":                    {fiber.StatusBadRequest, "Sandbox accounts cannot transfer balance", "حساب‌های آزمایشی امکان انتقال موجودی ندارند"},
+	"WALLET_TRANSFER_SELF":                       {fiber.StatusBadRequest, "Cannot transfer to the same account", "انتقال به همان حساب امکان‌پذیر نیست"},
+	"WALLET_BALANCE_RETRIEVAL_FAILED":            {fiber.StatusInternalServerError, "Wallet balance retrieval failed", "دریافت موجودی کیف پول ناموفق بود"},
+	"WALLET_CHARGE_DETAILS_REQUIRED":             {fiber.StatusBadRequest, "Details of the payment method are required", "جزئیات روش پرداخت الزامی است"},
+	"WALLET_CHARGE_IMPACT_PREVIEW_FAILED":        {fiber.StatusInternalServerError, "Wallet charge impact preview failed", "پیش‌نمایش اثر شارژ کیف پول ناموفق بود"},
+	"WALLET_CHARGE_LIST_FAILED":                  {fiber.StatusInternalServerError, "Failed to list wallet charges", "دریافت فهرست شارژهای کیف پول ناموفق بود"},
+	"WALLET_CHARGE_METHOD_INVALID":               {fiber.StatusBadRequest, "Unknown payment method (allowed: card, crypto, bank_transfer)", "روش پرداخت نامعتبر است (مجاز: card، crypto، bank_transfer)"},
+	"WALLET_CHARGING_BY_ADMIN_FAILED":            {fiber.StatusInternalServerError, "Wallet charging by admin failed", "شارژ کیف پول توسط مدیر ناموفق بود"},
+	"WALLET_CHARGING_FAILED":                     {fiber.StatusInternalServerError, "Wallet charging failed", "شارژ کیف پول ناموفق بود"},
+	"WALLET_NOT_FOUND":                           {fiber.StatusNotFound, "Wallet not found", "کیف پول یافت نشد"},
+
+	// Soft-deleted records
+	"DELETE_RECORD_FAILED":   {fiber.StatusInternalServerError, "Failed to delete record", "حذف رکورد ناموفق بود"},
+	"RECORD_ALREADY_DELETED": {fiber.StatusConflict, "Record is already deleted", "این رکورد قبلاً حذف شده است"},
+	"RECORD_KIND_INVALID":    {fiber.StatusBadRequest, "Unknown record kind", "نوع رکورد نامعتبر است"},
+	"RECORD_NOT_DELETABLE":   {fiber.StatusConflict, "Record cannot be deleted in its current state", "این رکورد در وضعیت فعلی قابل حذف نیست"},
+	"RECORD_NOT_DELETED":     {fiber.StatusConflict, "Record is not deleted", "این رکورد حذف نشده است"},
+	"RECORD_NOT_FOUND":       {fiber.StatusNotFound, "Record not found", "رکورد یافت نشد"},
+	"RESTORE_RECORD_FAILED":  {fiber.StatusInternalServerError, "Failed to restore record", "بازیابی رکورد ناموفق بود"},
+
+	// Customer activity timeline
+	"ADMIN_CUSTOMER_TIMELINE_FAILED": {fiber.StatusInternalServerError, "Failed to get customer timeline", "دریافت خط زمانی مشتری ناموفق بود"},
+	"TIMELINE_CATEGORY_INVALID":      {fiber.StatusBadRequest, "Category must be one of audit, session, payment and campaign", "دسته باید یکی از audit، session، payment و campaign باشد"},
+	"TIMELINE_CURSOR_INVALID":        {fiber.StatusBadRequest, "Invalid timeline cursor", "نشانگر صفحه خط زمانی نامعتبر است"},
+
+	// Admin list queries
+	"LIST_QUERY_INVALID": {fiber.StatusBadRequest, "Invalid filter, sort or fields", "فیلتر، مرتب‌سازی یا فیلدها نامعتبر است"},
+
+	// Notifications
+	"GET_UNREAD_NOTIFICATIONS_COUNT_FAILED": {fiber.StatusInternalServerError, "Failed to count unread notifications", "شمارش اعلان‌های خوانده‌نشده ناموفق بود"},
+	"LIST_NOTIFICATIONS_FAILED":             {fiber.StatusInternalServerError, "Failed to list notifications", "دریافت فهرست اعلان‌ها ناموفق بود"},
+	"MARK_NOTIFICATIONS_READ_FAILED":        {fiber.StatusInternalServerError, "Failed to mark notifications as read", "علامت‌گذاری اعلان‌ها به‌عنوان خوانده‌شده ناموفق بود"},
+
+	// Push notifications
+	"BROADCAST_PUSH_FAILED":         {fiber.StatusInternalServerError, "Failed to send push broadcast", "ارسال اعلان همگانی ناموفق بود"},
+	"LIST_PUSH_DEVICES_FAILED":      {fiber.StatusInternalServerError, "Failed to list push devices", "دریافت فهرست دستگاه‌های اعلان ناموفق بود"},
+	"PUSH_DEVICE_NOT_FOUND":         {fiber.StatusNotFound, "Push device not found", "دستگاه اعلان یافت نشد"},
+	"PUSH_NOT_CONFIGURED":           {fiber.StatusServiceUnavailable, "Push notifications are not configured", "اعلان‌های پوش پیکربندی نشده است"},
+	"REGISTER_PUSH_DEVICE_FAILED":   {fiber.StatusInternalServerError, "Failed to register push device", "ثبت دستگاه اعلان ناموفق بود"},
+	"UNREGISTER_PUSH_DEVICE_FAILED": {fiber.StatusInternalServerError, "Failed to remove push device", "حذف دستگاه اعلان ناموفق بود"},
+
+	// Telegram
+	"GET_TELEGRAM_LINK_FAILED":    {fiber.StatusInternalServerError, "Failed to get Telegram link status", "دریافت وضعیت اتصال تلگرام ناموفق بود"},
+	"START_TELEGRAM_LINK_FAILED":  {fiber.StatusInternalServerError, "Failed to create Telegram link", "ایجاد لینک اتصال تلگرام ناموفق بود"},
+	"TELEGRAM_CODE_INVALID":       {fiber.StatusBadRequest, "Invalid Telegram verification code", "کد تأیید تلگرام نامعتبر است"},
+	"TELEGRAM_LINK_LOCKED":        {fiber.StatusTooManyRequests, "Too many wrong codes; create a new Telegram link", "تعداد کدهای اشتباه بیش از حد مجاز است؛ لینک اتصال تلگرام جدیدی بسازید"},
+	"TELEGRAM_LINK_NOT_FOUND":     {fiber.StatusNotFound, "No Telegram chat is waiting for a verification code", "هیچ گفتگوی تلگرامی در انتظار کد تأیید نیست"},
+	"TELEGRAM_NOT_CONFIGURED":     {fiber.StatusServiceUnavailable, "Telegram notifications are not configured", "اطلاع‌رسانی تلگرام پیکربندی نشده است"},
+	"UNLINK_TELEGRAM_FAILED":      {fiber.StatusInternalServerError, "Failed to unlink Telegram", "قطع اتصال تلگرام ناموفق بود"},
+	"VERIFY_TELEGRAM_LINK_FAILED": {fiber.StatusInternalServerError, "Failed to verify Telegram link", "تأیید اتصال تلگرام ناموفق بود"},
+
+	// Tickets
+	"ADMIN_LIST_TICKETS_FAILED":    {fiber.StatusInternalServerError, "Failed to list tickets", "دریافت فهرست تیکت‌ها ناموفق بود"},
+	"CREATE_ADMIN_RESPONSE_FAILED": {fiber.StatusInternalServerError, "Failed to create admin response", "ثبت پاسخ مدیر ناموفق بود"},
+	"CREATE_RESPONSE_FAILED":       {fiber.StatusInternalServerError, "Failed to create response ticket", "ثبت پاسخ تیکت ناموفق بود"},
+	"CREATE_TICKET_FAILED":         {fiber.StatusInternalServerError, "Failed to create ticket", "ایجاد تیکت ناموفق بود"},
+	"INVALID_TICKET_ID":            {fiber.StatusBadRequest, "Invalid ticket ID", "شناسه تیکت نامعتبر است"},
+	"LIST_TICKETS_FAILED":          {fiber.StatusInternalServerError, "Failed to list tickets", "دریافت فهرست تیکت‌ها ناموفق بود"},
+	"TICKET_NOT_FOUND":             {fiber.StatusNotFound, "Ticket not found", "تیکت یافت نشد"},
+
+	// KYC document verification
+	"ADMIN_GET_KYC_FAILED":          {fiber.StatusInternalServerError, "Failed to get customer KYC", "دریافت احراز هویت مشتری ناموفق بود"},
+	"ADMIN_LIST_KYC_FAILED":         {fiber.StatusInternalServerError, "Failed to list KYC reviews", "دریافت فهرست بررسی‌های احراز هویت ناموفق بود"},
+	"ADMIN_REVIEW_KYC_FAILED":       {fiber.StatusInternalServerError, "Failed to review KYC", "بررسی مدارک احراز هویت ناموفق بود"},
+	"GET_KYC_STATUS_FAILED":         {fiber.StatusInternalServerError, "Failed to get KYC status", "دریافت وضعیت احراز هویت ناموفق بود"},
+	"KYC_DOCUMENTS_MISSING":         {fiber.StatusBadRequest, "Required documents are missing", "مدارک الزامی بارگذاری نشده‌اند"},
+	"KYC_DOCUMENT_INFECTED":         {fiber.StatusUnprocessableEntity, "Document failed the virus scan", "فایل مدرک در بررسی ویروس رد شد"},
+	"KYC_DOCUMENT_INVALID":          {fiber.StatusBadRequest, "Document must be a JPEG, PNG or PDF of at most 5MB", "مدرک باید فایل JPEG، PNG یا PDF با حجم حداکثر ۵ مگابایت باشد"},
+	"KYC_NOT_APPLICABLE":            {fiber.StatusBadRequest, "Only company accounts verify documents", "احراز مدارک فقط برای حساب‌های شرکتی است"},
+	"KYC_REJECTION_REASON_REQUIRED": {fiber.StatusBadRequest, "A reason is required to reject documents", "برای رد مدارک، ذکر دلیل الزامی است"},
+	"KYC_STATUS_CONFLICT":           {fiber.StatusConflict, "Documents cannot change in the current KYC status", "مدارک در وضعیت فعلی احراز هویت قابل تغییر نیستند"},
+	"KYC_UPLOAD_FAILED":             {fiber.StatusInternalServerError, "Failed to upload document", "بارگذاری مدرک ناموفق بود"},
+	"KYC_VERIFICATION_REQUIRED":     {fiber.StatusConflict, "Sending quota exceeds the cap for companies whose documents are not verified", "سهمیه ارسال از سقف مجاز شرکت‌های احراز نشده بیشتر است"},
+	"SUBMIT_KYC_FAILED":             {fiber.StatusInternalServerError, "Failed to submit documents", "ارسال مدارک برای بررسی ناموفق بود"},
+	"UPLOADS_NOT_CONFIGURED":        {fiber.StatusServiceUnavailable, "Document uploads are not configured", "بارگذاری مدارک پیکربندی نشده است"},
+
+	// Profile changes
+	"ADMIN_LIST_PROFILE_CHANGES_FAILED":        {fiber.StatusInternalServerError, "Failed to list profile changes", "دریافت فهرست تغییرات پروفایل ناموفق بود"},
+	"ADMIN_REVIEW_PROFILE_CHANGE_FAILED":       {fiber.StatusInternalServerError, "Failed to review profile change", "بررسی تغییر پروفایل ناموفق بود"},
+	"COMPANY_PROFILE_NOT_APPLICABLE":           {fiber.StatusBadRequest, "Only company accounts have company information", "اطلاعات شرکت فقط برای حساب‌های شرکتی است"},
+	"LIST_PROFILE_CHANGES_FAILED":              {fiber.StatusInternalServerError, "Failed to list profile changes", "دریافت سابقه تغییرات پروفایل ناموفق بود"},
+	"PROFILE_CHANGE_NOT_FOUND":                 {fiber.StatusNotFound, "Profile change not found", "تغییر پروفایل یافت نشد"},
+	"PROFILE_CHANGE_NOT_PENDING":               {fiber.StatusConflict, "Profile change is not waiting for review", "تغییر پروفایل در انتظار بررسی نیست"},
+	"PROFILE_CHANGE_REJECTION_REASON_REQUIRED": {fiber.StatusBadRequest, "A reason is required to reject a profile change", "برای رد تغییر پروفایل، ذکر دلیل الزامی است"},
+	"UPDATE_PROFILE_FAILED":                    {fiber.StatusInternalServerError, "Failed to update profile", "به‌روزرسانی پروفایل ناموفق بود"},
+
+	// Sheba verification
+	"AGENCY_SHEBA_NOT_VERIFIED":    {fiber.StatusConflict, "The agency's Sheba number is not verified", "شماره شبای نمایندگی هنوز تأیید نشده است"},
+	"SHEBA_INQUIRY_NOT_CONFIGURED": {fiber.StatusServiceUnavailable, "Sheba verification is not configured", "استعلام شماره شبا پیکربندی نشده است"},
+	"SHEBA_INQUIRY_UNAVAILABLE":    {fiber.StatusServiceUnavailable, "Bank inquiry is unavailable, try again later", "استعلام بانکی در دسترس نیست، بعداً دوباره تلاش کنید"},
+	"VERIFY_SHEBA_FAILED":          {fiber.StatusInternalServerError, "Failed to verify Sheba number", "استعلام شماره شبا ناموفق بود"},
+}
@@ -0,0 +1,239 @@
+package apierror_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/apierror"
+	"github.com/gofiber/fiber/v3"
+)
+
+// emitterDirs are the packages whose error codes reach API clients
+var emitterDirs = []string{"../handlers", "../middleware", "../router"}
+
+var (
+	codeLiteral = regexp.MustCompile(`"([A-Z][A-Z0-9]*(?:_[A-Z0-9]+)+)"`)
+	// case labels and == comparisons match business flow codes rather than emit them
+	comparisonLine = regexp.MustCompile(`^\s*(case\s|"[A-Z0-9_]+"[,:]\s*$)|\.Code [!=]= "`)
+	// the code of a business error passed straight through to the response
+	passthroughCode = regexp.MustCompile(`\.Code, `)
+	statusCall      = regexp.MustCompile(`(?:ErrorResponse|Respond)\(c, fiber\.Status(\w+), .*?, "([A-Z0-9_]+)"`)
+	passthroughCall = regexp.MustCompile(`(?:ErrorResponse|Respond)\(c, fiber\.Status(\w+), `)
+)
+
+// nonCodeLiterals look like error codes but are not
+var nonCodeLiterals = map[string]bool{
+	"APP_ENV":       true,
+	"NOT_SUPPORTED": true, // plain-text reply to the crypto gateway webhook
+}
+
+var statusByName = map[string]int{
+	"BadRequest":          fiber.StatusBadRequest,
+	"Unauthorized":        fiber.StatusUnauthorized,
+	"Forbidden":           fiber.StatusForbidden,
+	"NotFound":            fiber.StatusNotFound,
+	"Conflict":            fiber.StatusConflict,
+	"Gone":                fiber.StatusGone,
+	"TooManyRequests":     fiber.StatusTooManyRequests,
+	"InternalServerError": fiber.StatusInternalServerError,
+	"NotImplemented":      fiber.StatusNotImplemented,
+	"ServiceUnavailable":  fiber.StatusServiceUnavailable,
+}
+
+func TestCatalogEntriesAreComplete(t *testing.T) {
+	t.Parallel()
+
+	for _, code := range apierror.Codes() {
+		entry, _ := apierror.Lookup(code)
+		if entry.Status < fiber.StatusBadRequest || entry.Status > 599 {
+			t.Errorf("%s: status %d is not an error status", code, entry.Status)
+		}
+		if entry.English == "" || entry.Persian == "" {
+			t.Errorf("%s: missing English or Persian message", code)
+		}
+	}
+}
+
+func TestEmittedCodesAreRegistered(t *testing.T) {
+	t.Parallel()
+
+	for _, dir := range emitterDirs {
+		files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, path := range files {
+			if strings.HasSuffix(path, "_test.go") {
+				continue
+			}
+			checkEmittedCodes(t, path)
+		}
+	}
+}
+
+func checkEmittedCodes(t *testing.T, path string) {
+	t.Helper()
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	requireRegistered := func(lineNo int, code string) {
+		if nonCodeLiterals[code] {
+			return
+		}
+		if _, ok := apierror.Lookup(code); !ok {
+			t.Errorf("%s:%d: error code %s is not registered in the catalog", path, lineNo, code)
+		}
+	}
+
+	checkStatus := func(lineNo int, statusName, code string) {
+		status, ok := statusByName[statusName]
+		if !ok {
+			t.Errorf("%s:%d: unknown status fiber.Status%s", path, lineNo, statusName)
+			return
+		}
+		if entry, ok := apierror.Lookup(code); ok && entry.Status != status {
+			t.Errorf("%s:%d: %s is sent with %d but registered with %d", path, lineNo, code, status, entry.Status)
+		}
+	}
+
+	var pending []string
+	scanner := bufio.NewScanner(f)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := scanner.Text()
+		literals := codeLiteral.FindAllStringSubmatch(line, -1)
+
+		if comparisonLine.MatchString(line) {
+			for _, m := range literals {
+				pending = append(pending, m[1])
+			}
+			continue
+		}
+		if passthroughCode.MatchString(line) {
+			passthroughStatus := passthroughCall.FindStringSubmatch(line)
+			for _, code := range pending {
+				requireRegistered(lineNo, code)
+				if passthroughStatus != nil {
+					checkStatus(lineNo, passthroughStatus[1], code)
+				}
+			}
+		}
+		pending = nil
+
+		for _, m := range literals {
+			requireRegistered(lineNo, m[1])
+		}
+		for _, m := range statusCall.FindAllStringSubmatch(line, -1) {
+			checkStatus(lineNo, m[1], m[2])
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCodesAreDocumented(t *testing.T) {
+	t.Parallel()
+
+	doc, err := os.ReadFile("../../docs/api-errors.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, code := range apierror.Codes() {
+		if !strings.Contains(string(doc), "| `"+code+"` |") {
+			t.Errorf("%s is missing from docs/api-errors.md", code)
+		}
+	}
+}
+
+func respond(t *testing.T, acceptLanguage string, status int, message, code string) (int, string, map[string]any) {
+	t.Helper()
+
+	app := fiber.New()
+	app.Get("/", func(c fiber.Ctx) error {
+		return apierror.Respond(c, status, message, code, map[string]any{"field": "x"})
+	})
+	req := httptest.NewRequest("GET", "/", nil)
+	if acceptLanguage != "" {
+		req.Header.Set(fiber.HeaderAcceptLanguage, acceptLanguage)
+	}
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var body map[string]any
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode, resp.Header.Get(fiber.HeaderContentLanguage), body
+}
+
+func TestRespondLocalizesRegisteredCodes(t *testing.T) {
+	t.Parallel()
+
+	entry, _ := apierror.Lookup("CAMPAIGN_NOT_FOUND")
+	status, lang, body := respond(t, "fa-IR,fa;q=0.9", fiber.StatusBadRequest, "ad-hoc message", "CAMPAIGN_NOT_FOUND")
+	if status != entry.Status || lang != "fa" {
+		t.Fatalf("expected %d/fa, got %d/%s", entry.Status, status, lang)
+	}
+	if body["success"] != false || body["message"] != entry.Persian {
+		t.Fatalf("unexpected envelope %v", body)
+	}
+	errField, _ := body["error"].(map[string]any)
+	details, _ := errField["details"].(map[string]any)
+	if errField["code"] != "CAMPAIGN_NOT_FOUND" || details["field"] != "x" {
+		t.Fatalf("unexpected error field %v", errField)
+	}
+
+	_, lang, body = respond(t, "", fiber.StatusNotFound, "ad-hoc message", "CAMPAIGN_NOT_FOUND")
+	if lang != "en" || body["message"] != entry.English {
+		t.Fatalf("expected English message, got %s %v", lang, body["message"])
+	}
+}
+
+func TestRespondKeepsUnregisteredCodes(t *testing.T) {
+	t.Parallel()
+
+	status, lang, body := respond(t, "fa", fiber.StatusTeapot, "Flow specific failure", "SOME_FLOW_SPECIFIC_FAILURE")
+	if status != fiber.StatusTeapot || lang != "en" || body["message"] != "Flow specific failure" {
+		t.Fatalf("unexpected response %d %s %v", status, lang, body)
+	}
+}
+
+func TestRespondIncludesRequestID(t *testing.T) {
+	t.Parallel()
+
+	app := fiber.New()
+	app.Get("/", func(c fiber.Ctx) error {
+		c.Locals("requestid", "req-1")
+		return apierror.Respond(c, fiber.StatusNotFound, "", "CAMPAIGN_NOT_FOUND", nil)
+	})
+	resp, err := app.Test(httptest.NewRequest("GET", "/", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		Error struct {
+			RequestID string `json:"request_id"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Error.RequestID != "req-1" {
+		t.Fatalf("error.request_id = %q, want req-1", body.Error.RequestID)
+	}
+}
@@ -0,0 +1,32 @@
+package apierror
+
+import (
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/app/i18n"
+	"github.com/gofiber/fiber/v3"
+)
+
+// Respond writes the standard error envelope. Registered codes always use the
+// catalog status and the message localized for the request; unregistered codes
+// (e.g. ones passed through from business flows) keep the given status and message.
+// The request ID is returned in error.request_id.
+func Respond(c fiber.Ctx, status int, message, code string, details any) error {
+	locale := i18n.LocaleEnglish
+	if entry, ok := Lookup(code); ok {
+		locale = i18n.RequestLocale(c)
+		status = entry.Status
+		message = entry.Message(locale)
+	}
+
+	requestID, _ := c.Locals("requestid").(string)
+	c.Set(fiber.HeaderContentLanguage, string(locale))
+	return c.Status(status).JSON(dto.APIResponse{
+		Success: false,
+		Message: message,
+		Error: dto.ErrorDetail{
+			Code:      code,
+			Details:   details,
+			RequestID: requestID,
+		},
+	})
+}
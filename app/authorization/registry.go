@@ -22,10 +22,17 @@ var RoutePermissionRegistry = []RoutePermission{
 	{"GET", "/api/v1/admin/campaigns", PermissionCampaignRead, "List/get campaigns"},
 	{"POST", "/api/v1/admin/campaigns/approve", PermissionCampaignApprove, "Approve campaigns"},
 	{"POST", "/api/v1/admin/campaigns/reject", PermissionCampaignApprove, "Reject campaigns"},
+	{"POST", "/api/v1/admin/campaigns/request-changes", PermissionCampaignApprove, "Request campaign changes"},
 	{"POST", "/api/v1/admin/campaigns/reschedule", PermissionCampaignApprove, "Reschedule campaigns"},
 	{"POST", "/api/v1/admin/campaigns/cancel", PermissionCampaignApprove, "Cancel campaigns"},
+	{"POST", "/api/v1/admin/campaigns/", PermissionCampaignApprove, "Comment on campaign reviews"},
 	{"DELETE", "/api/v1/admin/campaigns/audience-spec", PermissionCampaignWrite, "Remove audience spec"},
 
+	// Global campaign templates
+	{"GET", "/api/v1/admin/campaign-templates", PermissionCampaignRead, "List global campaign templates"},
+	{"POST", "/api/v1/admin/campaign-templates", PermissionCampaignWrite, "Publish global campaign template"},
+	{"DELETE", "/api/v1/admin/campaign-templates/", PermissionCampaignWrite, "Delete global campaign template"},
+
 	// Payments admin
 	{"POST", "/api/v1/admin/payments/charge-wallet", PermissionPaymentChargeWallet, "Charge wallet (admin)"},
 	{"POST", "/api/v1/admin/payments/charge-wallet/preview", PermissionPaymentChargeWallet, "Preview wallet charge impact (admin)"},
@@ -34,10 +41,49 @@ var RoutePermissionRegistry = []RoutePermission{
 	{"GET", "/api/v1/admin/payments/deposit-receipts/", PermissionPaymentReceiptReview, "Get deposit receipt file"}, // path prefix covers /deposit-receipts/:uuid/file
 	{"POST", "/api/v1/admin/payments/deposit-receipts/status", PermissionPaymentReceiptReview, "Review deposit receipt"},
 	{"POST", "/api/v1/admin/payments/transactions/invoice", PermissionPaymentInvoiceAttach, "Attach invoice to transaction"},
+	{"POST", "/api/v1/admin/payments/atipay-reconciliation", PermissionPaymentReconcile, "Reconcile Atipay settlement report"},
+	{"GET", "/api/v1/admin/payments/atipay-reconciliation", PermissionPaymentRead, "Atipay reconciliation report"},
+	{"POST", "/api/v1/admin/payments/wallet-adjustments/", PermissionPaymentAdjustApprove, "Review wallet adjustment"}, // path prefix covers /wallet-adjustments/:uuid/decision
+	{"POST", "/api/v1/admin/payments/wallet-adjustments", PermissionPaymentAdjustRequest, "Request wallet adjustment"},
+	{"GET", "/api/v1/admin/payments/wallet-adjustments", PermissionPaymentRead, "List wallet adjustments"},
+	{"GET", "/api/v1/admin/payments/wallet-transfers", PermissionPaymentRead, "List wallet transfers between customer accounts"},
+	{"GET", "/api/v1/admin/payments/frozen-budget-audit", PermissionPaymentRead, "Audit frozen campaign budgets"},
+	{"GET", "/api/v1/admin/payments/credit-lines/", PermissionPaymentRead, "Get customer credit line"},
+	{"PUT", "/api/v1/admin/payments/credit-lines/", PermissionPaymentCreditManage, "Set customer credit limit"},
+	{"GET", "/api/v1/admin/payments/postpaid-invoices", PermissionPaymentRead, "List postpaid invoices"},
+	{"POST", "/api/v1/admin/payments/postpaid-invoices/", PermissionPaymentCreditManage, "Mark postpaid invoice paid"}, // path prefix covers /postpaid-invoices/:uuid/paid
+	{"GET", "/api/v1/admin/payments/invoices", PermissionPaymentRead, "List and download invoices"},                    // path prefix covers /invoices/:uuid/pdf
+	{"GET", "/api/v1/admin/payments/agency-payables", PermissionPaymentRead, "List agency payables"},
+	{"POST", "/api/v1/admin/payments/agency-payables/", PermissionPaymentCreditManage, "Mark agency payable paid"}, // path prefix covers /agency-payables/:uuid/paid
+
+	// Financial reports
+	{"GET", "/api/v1/admin/reports", PermissionReportRead, "Financial dashboard reports"},
+	{"POST", "/api/v1/admin/reports/rollups/refresh", PermissionReportRefresh, "Refresh reporting rollups"},
 
 	// Customer management
 	{"GET", "/api/v1/admin/customer-management", PermissionUserList, "List customers & shares/discounts"},
 	{"POST", "/api/v1/admin/customer-management/active-status", PermissionUserWrite, "Change customer active status"},
+	{"POST", "/api/v1/admin/bulk/customers/active-status", PermissionUserWrite, "Change the active status of customers in bulk"},
+	{"POST", "/api/v1/admin/bulk/customers/agency", PermissionUserWrite, "Move customers under another agency in bulk"},
+	{"POST", "/api/v1/admin/bulk/crypto-payments/resync", PermissionPaymentReconcile, "Resync crypto payment requests with the provider in bulk"},
+	{"PUT", "/api/v1/admin/customer-management/", PermissionUserWrite, "Set customer sending quota, sandbox & suspension"}, // path prefix covers /:customer_id/sending-quota, /sandbox and /suspension
+
+	// Soft delete and restore; path prefixes cover /:id and /:id/restore
+	{"DELETE", "/api/v1/admin/records/campaigns/", PermissionCampaignWrite, "Delete campaign"},
+	{"POST", "/api/v1/admin/records/campaigns/", PermissionCampaignWrite, "Restore campaign"},
+	{"DELETE", "/api/v1/admin/records/audience-profiles/", PermissionCampaignWrite, "Delete audience profile"},
+	{"POST", "/api/v1/admin/records/audience-profiles/", PermissionCampaignWrite, "Restore audience profile"},
+	{"DELETE", "/api/v1/admin/records/tags/", PermissionCampaignWrite, "Delete tag"},
+	{"POST", "/api/v1/admin/records/tags/", PermissionCampaignWrite, "Restore tag"},
+	{"DELETE", "/api/v1/admin/records/line-numbers/", PermissionLineNumberWrite, "Delete line number"},
+	{"POST", "/api/v1/admin/records/line-numbers/", PermissionLineNumberWrite, "Restore line number"},
+	{"POST", "/api/v1/admin/customer-management/", PermissionUserWrite, "Force customer logout"}, // path prefix covers /:customer_id/force-logout
+	{"GET", "/api/v1/admin/kyc", PermissionUserList, "List and view customer KYC documents"},
+	{"POST", "/api/v1/admin/kyc/", PermissionUserWrite, "Approve or reject customer KYC documents"}, // path prefix covers /:customer_id/review
+	{"POST", "/api/v1/admin/customers/", PermissionUserImpersonate, "Impersonate customer"},         // path prefix covers /:id/impersonate
+	{"GET", "/api/v1/admin/customers/", PermissionUserList, "Customer activity timeline"},           // path prefix covers /:id/timeline
+	{"GET", "/api/v1/admin/profile-changes", PermissionUserList, "List customer profile changes"},
+	{"POST", "/api/v1/admin/profile-changes/", PermissionUserWrite, "Review profile changes"}, // path prefix covers /:uuid/review
 
 	// Short-links
 	{"POST", "/api/v1/admin/short-links", PermissionShortLinkManage, "Upload/download short-links"},
@@ -46,9 +92,17 @@ var RoutePermissionRegistry = []RoutePermission{
 	{"POST", "/api/v1/admin/short-links/download-with-clicks", PermissionShortLinkManage, "Export short-links with clicks"},
 	{"POST", "/api/v1/admin/short-links/download-with-clicks-range", PermissionShortLinkManage, "Export short-links with clicks range"},
 	{"POST", "/api/v1/admin/short-links/download-with-clicks-by-scenario-name", PermissionShortLinkManage, "Export short-links by scenario"},
+	{"POST", "/api/v1/admin/audience-imports", PermissionCampaignWrite, "Upload audience CSV import"},
+	{"GET", "/api/v1/admin/audience-imports/", PermissionCampaignRead, "Get audience import progress"},
+	{"POST", "/api/v1/admin/blacklist", PermissionCampaignWrite, "Upload blacklisted numbers"},
+	{"GET", "/api/v1/admin/blacklist", PermissionCampaignRead, "List blacklisted numbers"},
+	{"DELETE", "/api/v1/admin/blacklist/", PermissionCampaignWrite, "Remove blacklisted number"},
 
 	// Line numbers
 	{"GET", "/api/v1/admin/line-numbers/report", PermissionLineNumberReport, "Line number report/export"},
+	{"GET", "/api/v1/admin/line-numbers/tiers", PermissionLineNumberRead, "List line number tiers"},
+	{"POST", "/api/v1/admin/line-numbers/tiers", PermissionLineNumberWrite, "Create line number tier"},
+	{"PUT", "/api/v1/admin/line-numbers/tiers/", PermissionLineNumberWrite, "Update line number tier pricing"},
 	{"GET", "/api/v1/admin/line-numbers", PermissionLineNumberRead, "List line numbers"},
 	{"POST", "/api/v1/admin/line-numbers", PermissionLineNumberWrite, "Create line number"},
 	{"PUT", "/api/v1/admin/line-numbers", PermissionLineNumberWrite, "Batch update line numbers"},
@@ -70,10 +124,28 @@ var RoutePermissionRegistry = []RoutePermission{
 	{"GET", "/api/v1/admin/segment-price-factors/level3-options", PermissionPlatformBasePriceRead, "List level3 options"},
 	{"GET", "/api/v1/admin/platform-base-prices", PermissionPlatformBasePriceRead, "List platform base prices"},
 	{"PUT", "/api/v1/admin/platform-base-prices", PermissionPlatformBasePriceEdit, "Update platform base price"},
+	{"POST", "/api/v1/admin/sms-tariffs", PermissionPlatformBasePriceEdit, "Create SMS tariff"},
+	{"GET", "/api/v1/admin/sms-tariffs", PermissionPlatformBasePriceRead, "List SMS tariffs"},
+	{"PUT", "/api/v1/admin/sms-tariffs/", PermissionPlatformBasePriceEdit, "Update SMS tariff"},
+	{"DELETE", "/api/v1/admin/sms-tariffs/", PermissionPlatformBasePriceEdit, "Delete SMS tariff"},
 
 	// Access control (maker-checker)
 	{"POST", "/api/v1/admin/access-control/requests", PermissionACLManage, "Create ACL change request"},
 	{"POST", "/api/v1/admin/access-control/requests/", PermissionACLApprove, "Approve/reject ACL change request"}, // path prefix covers /requests/:uuid/decision
+
+	// Runtime configuration
+	{"GET", "/api/v1/admin/config", PermissionConfigRead, "View runtime configuration"},
+	{"POST", "/api/v1/admin/config/reload", PermissionConfigReload, "Reload runtime configuration"},
+
+	// Background jobs
+	{"GET", "/api/v1/admin/jobs", PermissionJobRead, "List/get background jobs"},
+	{"POST", "/api/v1/admin/jobs/", PermissionJobRequeue, "Requeue/cancel dead background job"}, // path prefix covers /:uuid/requeue and /:uuid/cancel
+
+	// Notifications
+	{"POST", "/api/v1/admin/notifications/push/broadcast", PermissionNotificationBroadcast, "Broadcast push notification"},
+
+	// API docs
+	{"GET", "/api/v1/admin/docs", PermissionDocsRead, "OpenAPI document"},
 }
 
 // PermissionForRoute returns the permission bucket for the given method/path if any.
@@ -23,8 +23,13 @@ const (
 	PermissionPaymentInvoiceAttach  PermissionKey = "payment:invoice_attach"
 	PermissionPaymentChargeWallet   PermissionKey = "payment:charge_wallet"
 	PermissionPaymentRead           PermissionKey = "payment:read"
+	PermissionPaymentReconcile      PermissionKey = "payment:reconcile"
+	PermissionPaymentAdjustRequest  PermissionKey = "payment:adjust_request"
+	PermissionPaymentAdjustApprove  PermissionKey = "payment:adjust_approve"
+	PermissionPaymentCreditManage   PermissionKey = "payment:credit_manage"
 	PermissionUserList              PermissionKey = "user:list"
 	PermissionUserWrite             PermissionKey = "user:write"
+	PermissionUserImpersonate       PermissionKey = "user:impersonate"
 	PermissionPlatformBasePriceRead PermissionKey = "platform-base-price:read"
 	PermissionPlatformBasePriceEdit PermissionKey = "platform-base-price:create"
 	PermissionShortLinkManage       PermissionKey = "shortlink:manage"
@@ -39,6 +44,14 @@ const (
 	PermissionPlatformSettingsWrite PermissionKey = "platform-settings:write"
 	PermissionACLManage             PermissionKey = "acl:manage"
 	PermissionACLApprove            PermissionKey = "acl:approve"
+	PermissionConfigRead            PermissionKey = "config:read"
+	PermissionConfigReload          PermissionKey = "config:reload"
+	PermissionReportRead            PermissionKey = "report:read"
+	PermissionReportRefresh         PermissionKey = "report:refresh"
+	PermissionJobRead               PermissionKey = "job:read"
+	PermissionJobRequeue            PermissionKey = "job:requeue"
+	PermissionDocsRead              PermissionKey = "docs:read"
+	PermissionNotificationBroadcast PermissionKey = "notification:broadcast"
 )
 
 // PermissionCatalog documents available permissions with a short description.
@@ -50,8 +63,13 @@ var PermissionCatalog = map[PermissionKey]string{
 	PermissionPaymentInvoiceAttach:  "Attach or update transaction invoices",
 	PermissionPaymentChargeWallet:   "Charge wallets on behalf of customers",
 	PermissionPaymentRead:           "View payment and wallet information",
+	PermissionPaymentReconcile:      "Upload gateway settlement reports for reconciliation",
+	PermissionPaymentAdjustRequest:  "Request manual wallet adjustments (maker)",
+	PermissionPaymentAdjustApprove:  "Approve or reject manual wallet adjustments (checker)",
+	PermissionPaymentCreditManage:   "Set customer credit limits, settle postpaid invoices and pay out agency payables",
 	PermissionUserList:              "List or view customers and related reports",
 	PermissionUserWrite:             "Change customer status or attributes",
+	PermissionUserImpersonate:       "Act as a customer with a short-lived impersonation token",
 	PermissionPlatformBasePriceRead: "Read platform base/page/segment price factors",
 	PermissionPlatformBasePriceEdit: "Create or update platform base/page/segment price factors",
 	PermissionShortLinkManage:       "Upload/export short-links and click data",
@@ -66,6 +84,14 @@ var PermissionCatalog = map[PermissionKey]string{
 	PermissionPlatformSettingsWrite: "Update platform settings or metadata",
 	PermissionACLManage:             "Create ACL change requests (maker)",
 	PermissionACLApprove:            "Approve or reject ACL change requests (checker)",
+	PermissionConfigRead:            "View the runtime configuration",
+	PermissionConfigReload:          "Reload the runtime configuration",
+	PermissionReportRead:            "View platform-wide financial reports",
+	PermissionReportRefresh:         "Refresh the reporting rollups on demand",
+	PermissionJobRead:               "Inspect the background job queue",
+	PermissionJobRequeue:            "Requeue or cancel dead background jobs",
+	PermissionDocsRead:              "Browse the OpenAPI document of the API",
+	PermissionNotificationBroadcast: "Send push notifications to every subscribed customer device",
 }
 
 // RolePermissions maps roles to the permissions they grant by default.
@@ -78,8 +104,13 @@ var RolePermissions = map[Role][]PermissionKey{
 		PermissionPaymentInvoiceAttach,
 		PermissionPaymentChargeWallet,
 		PermissionPaymentRead,
+		PermissionPaymentReconcile,
+		PermissionPaymentAdjustRequest,
+		PermissionPaymentAdjustApprove,
+		PermissionPaymentCreditManage,
 		PermissionUserList,
 		PermissionUserWrite,
+		PermissionUserImpersonate,
 		PermissionPlatformBasePriceRead,
 		PermissionPlatformBasePriceEdit,
 		PermissionShortLinkManage,
@@ -94,18 +125,33 @@ var RolePermissions = map[Role][]PermissionKey{
 		PermissionPlatformSettingsWrite,
 		PermissionACLManage,
 		PermissionACLApprove,
+		PermissionConfigRead,
+		PermissionConfigReload,
+		PermissionReportRead,
+		PermissionReportRefresh,
+		PermissionJobRead,
+		PermissionJobRequeue,
+		PermissionDocsRead,
+		PermissionNotificationBroadcast,
 	},
 	RoleFinance: {
 		PermissionPaymentReceiptReview,
 		PermissionPaymentInvoiceAttach,
 		PermissionPaymentChargeWallet,
 		PermissionPaymentRead,
+		PermissionPaymentReconcile,
+		PermissionPaymentAdjustRequest,
+		PermissionPaymentAdjustApprove,
+		PermissionPaymentCreditManage,
 		PermissionUserList,
+		PermissionReportRead,
+		PermissionReportRefresh,
 	},
 	RoleSupport: {
 		PermissionTicketRead,
 		PermissionTicketReply,
 		PermissionUserList,
+		PermissionUserImpersonate,
 		PermissionCampaignRead,
 	},
 	RoleContent: {
@@ -114,6 +160,7 @@ var RolePermissions = map[Role][]PermissionKey{
 		PermissionMediaWrite,
 		PermissionCampaignRead,
 		PermissionPlatformSettingsRead,
+		PermissionNotificationBroadcast,
 	},
 	RoleMediaUpload: {
 		PermissionMediaWrite,
@@ -126,6 +173,10 @@ var RolePermissions = map[Role][]PermissionKey{
 		PermissionLineNumberRead,
 		PermissionPlatformSettingsRead,
 		PermissionTicketRead,
+		PermissionConfigRead,
+		PermissionReportRead,
+		PermissionJobRead,
+		PermissionDocsRead,
 	},
 }
 
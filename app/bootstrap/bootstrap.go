@@ -0,0 +1,1832 @@
+// Package bootstrap builds the application from its configuration: the
+// database and cache connections, repositories, business flows, HTTP router
+// and background workers. The API server (main.go) and the worker binary
+// (cmd/worker) share it, so both run against the same wiring and config.
+package bootstrap
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/dbmigrate"
+	"github.com/amirphl/Yamata-no-Orochi/app/grpcapi"
+	"github.com/amirphl/Yamata-no-Orochi/app/handlers"
+	"github.com/amirphl/Yamata-no-Orochi/app/health"
+	"github.com/amirphl/Yamata-no-Orochi/app/i18n"
+	"github.com/amirphl/Yamata-no-Orochi/app/middleware"
+	"github.com/amirphl/Yamata-no-Orochi/app/observability"
+	"github.com/amirphl/Yamata-no-Orochi/app/router"
+	"github.com/amirphl/Yamata-no-Orochi/app/services"
+	"github.com/amirphl/Yamata-no-Orochi/app/storage"
+	businessflow "github.com/amirphl/Yamata-no-Orochi/business_flow"
+	"github.com/amirphl/Yamata-no-Orochi/config"
+	"github.com/amirphl/Yamata-no-Orochi/migrations"
+	"github.com/amirphl/Yamata-no-Orochi/repository"
+	"github.com/gofiber/fiber/v3"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/google/uuid"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/scheduler"
+)
+
+// Application represents the main application structure
+type Application struct {
+	Router    *router.FiberRouter
+	Config    *config.ProductionConfig
+	Server    *fiber.App
+	Health    *health.Checker
+	Runtime   businessflow.RuntimeConfigFlow
+	StopFuncs []func()
+}
+
+// dbStatsCollector exposes sql.DB Stats to Prometheus
+type dbStatsCollector struct {
+	db    *sql.DB
+	name  string
+	open  *prometheus.Desc
+	inUse *prometheus.Desc
+	idle  *prometheus.Desc
+	waitC *prometheus.Desc
+	waitD *prometheus.Desc
+	maxOC *prometheus.Desc
+	maxIC *prometheus.Desc
+	maxLC *prometheus.Desc
+}
+
+var metricsRegisterOnce sync.Once
+
+func newDBStatsCollector(db *sql.DB, name string) *dbStatsCollector {
+	const ns = "db"
+	labels := []string{"name"}
+	return &dbStatsCollector{
+		db:    db,
+		name:  name,
+		open:  prometheus.NewDesc(ns+"_open_connections", "The number of established connections both in use and idle.", labels, nil),
+		inUse: prometheus.NewDesc(ns+"_in_use_connections", "The number of connections currently in use.", labels, nil),
+		idle:  prometheus.NewDesc(ns+"_idle_connections", "The number of idle connections.", labels, nil),
+		waitC: prometheus.NewDesc(ns+"_wait_count_total", "The total number of connections waited for.", labels, nil),
+		waitD: prometheus.NewDesc(ns+"_wait_duration_seconds_total", "The total time blocked waiting for a new connection.", labels, nil),
+		maxOC: prometheus.NewDesc(ns+"_max_open_connections", "Maximum number of open connections to the database.", labels, nil),
+		maxIC: prometheus.NewDesc(ns+"_max_idle_closed_total", "The total number of connections closed due to SetMaxIdleConns.", labels, nil),
+		maxLC: prometheus.NewDesc(ns+"_max_lifetime_closed_total", "The total number of connections closed due to SetConnMaxLifetime.", labels, nil),
+	}
+}
+
+func (c *dbStatsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.open
+	ch <- c.inUse
+	ch <- c.idle
+	ch <- c.waitC
+	ch <- c.waitD
+	ch <- c.maxOC
+	ch <- c.maxIC
+	ch <- c.maxLC
+}
+
+func (c *dbStatsCollector) Collect(ch chan<- prometheus.Metric) {
+	s := c.db.Stats()
+	labels := []string{c.name}
+	ch <- prometheus.MustNewConstMetric(c.open, prometheus.GaugeValue, float64(s.OpenConnections), labels...)
+	ch <- prometheus.MustNewConstMetric(c.inUse, prometheus.GaugeValue, float64(s.InUse), labels...)
+	ch <- prometheus.MustNewConstMetric(c.idle, prometheus.GaugeValue, float64(s.Idle), labels...)
+	ch <- prometheus.MustNewConstMetric(c.waitC, prometheus.CounterValue, float64(s.WaitCount), labels...)
+	ch <- prometheus.MustNewConstMetric(c.waitD, prometheus.CounterValue, s.WaitDuration.Seconds(), labels...)
+	ch <- prometheus.MustNewConstMetric(c.maxOC, prometheus.GaugeValue, float64(s.MaxOpenConnections), labels...)
+	ch <- prometheus.MustNewConstMetric(c.maxIC, prometheus.CounterValue, float64(s.MaxIdleClosed), labels...)
+	ch <- prometheus.MustNewConstMetric(c.maxLC, prometheus.CounterValue, float64(s.MaxLifetimeClosed), labels...)
+}
+
+func startMetricsServer(cfg config.MetricsConfig, db, replicaDB *gorm.DB) (func(), error) {
+	if !cfg.Enabled || !cfg.EnablePrometheus {
+		return func() {}, nil
+	}
+
+	metricsRegisterOnce.Do(func() {
+		register := func(col prometheus.Collector) {
+			if err := prometheus.DefaultRegisterer.Register(col); err != nil {
+				if _, ok := err.(prometheus.AlreadyRegisteredError); ok {
+					return
+				}
+				log.Printf("metrics register error: %v", err)
+			}
+		}
+		register(collectors.NewGoCollector())
+		register(collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+
+		if db != nil {
+			if sqlDB, err := db.DB(); err == nil && sqlDB != nil {
+				register(newDBStatsCollector(sqlDB, "primary"))
+			}
+		}
+		if replicaDB != nil {
+			if sqlDB, err := replicaDB.DB(); err == nil && sqlDB != nil {
+				register(newDBStatsCollector(sqlDB, "replica"))
+			}
+		}
+	})
+
+	mux := http.NewServeMux()
+	path := cfg.Path
+	if path == "" {
+		path = "/metrics"
+	}
+	mux.Handle(path, promhttp.Handler())
+
+	addr := fmt.Sprintf(":%d", cfg.Port)
+	srv := &http.Server{Addr: addr, Handler: mux}
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Printf("metrics server error: %v", err)
+		}
+	}()
+	log.Printf("Metrics server started on %s%s", addr, path)
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(ctx)
+	}, nil
+}
+
+// RunRollupBackfill refreshes the reporting rollups for the Tehran days of a
+// FROM:TO range without starting the server
+func RunRollupBackfill(cfg *config.ProductionConfig, days string) error {
+	fromStr, toStr, ok := strings.Cut(days, ":")
+	if !ok {
+		return fmt.Errorf("expected FROM:TO, got %q", days)
+	}
+	from, err := time.ParseInLocation("2006-01-02", fromStr, utils.TehranLocation())
+	if err != nil {
+		return fmt.Errorf("invalid FROM date: %w", err)
+	}
+	to, err := time.ParseInLocation("2006-01-02", toStr, utils.TehranLocation())
+	if err != nil {
+		return fmt.Errorf("invalid TO date: %w", err)
+	}
+
+	db, err := initializeDatabase(cfg.Database)
+	if err != nil {
+		return err
+	}
+	flow := businessflow.NewReportRollupFlow(repository.NewReportRollupRepository(db), cfg.Scheduler.ReportRollupLookbackDays)
+	summary, err := flow.Backfill(context.Background(), from, to)
+	if err != nil {
+		return err
+	}
+	if summary.Skipped {
+		return errors.New("another instance is refreshing the rollups; try again later")
+	}
+	log.Printf("Rollups refreshed for %d days (%s to %s) and %d months", summary.Days, summary.From, summary.To, summary.Months)
+	return nil
+}
+
+// initializeDatabase initializes the database connection with connection pooling
+func initializeDatabase(cfg config.DatabaseConfig) (*gorm.DB, error) {
+	db, err := gorm.Open(postgres.Open(cfg.DSN()), &gorm.Config{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	// Get underlying sql.DB for connection pooling configuration
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get underlying sql.DB: %w", err)
+	}
+
+	// Configure connection pooling
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+
+	// Test the connection
+	if err := sqlDB.Ping(); err != nil {
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	log.Printf("Database connection established with %d max open connections, %d max idle connections",
+		cfg.MaxOpenConns, cfg.MaxIdleConns)
+
+	return db, nil
+}
+
+// checkMigrations compares the schema version with the embedded migrations.
+// Depending on mode a drift is logged or stops the startup.
+func checkMigrations(db *gorm.DB, mode string) error {
+	if mode == "off" {
+		return nil
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return fmt.Errorf("failed to get underlying sql.DB: %w", err)
+	}
+	known, err := dbmigrate.Load(migrations.FS)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	status, err := dbmigrate.Check(ctx, sqlDB, known)
+	if err == nil {
+		err = status.Drift()
+	}
+	if err == nil {
+		log.Printf("Database schema is at migration %s", status.Current.Name)
+		return nil
+	}
+	if mode == "fail" {
+		return fmt.Errorf("database schema drift: %w", err)
+	}
+	log.Printf("WARNING: database schema drift: %v", err)
+	return nil
+}
+
+// initializeReadReplica connects to the optional read-only replica. It returns
+// nil when no replica DSN is configured. The replica shares the primary's pool settings.
+func initializeReadReplica(cfg config.DatabaseConfig) (*gorm.DB, error) {
+	if cfg.ReplicaDSN == "" {
+		return nil, nil
+	}
+
+	db, err := gorm.Open(postgres.Open(cfg.ReplicaDSN), &gorm.Config{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to read replica: %w", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get underlying sql.DB for read replica: %w", err)
+	}
+
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+
+	if err := sqlDB.Ping(); err != nil {
+		return nil, fmt.Errorf("failed to ping read replica: %w", err)
+	}
+
+	log.Printf("Read replica connection established with %d max open connections", cfg.MaxOpenConns)
+
+	return db, nil
+}
+
+// initializeCache initializes the Cache client and verifies connectivity
+func initializeCache(cfg config.CacheConfig) (*redis.Client, error) {
+	if !cfg.Enabled || cfg.Provider != "redis" {
+		return nil, nil
+	}
+
+	opt, err := redis.ParseURL(cfg.RedisURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redis url: %w", err)
+	}
+	// Override DB if provided in config
+	opt.DB = cfg.RedisDB
+
+	rc := redis.NewClient(opt)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := rc.Ping(ctx).Err(); err != nil {
+		_ = rc.Close()
+		return nil, fmt.Errorf("failed to connect to redis: %w", err)
+	}
+
+	log.Printf("Redis connection established to %s (db=%d)", cfg.RedisURL, cfg.RedisDB)
+	return rc, nil
+}
+
+// startCacheHealthMonitor starts a background goroutine that periodically pings Redis
+// to detect connectivity issues. The returned cancel function stops the monitor.
+func startCacheHealthMonitor(parent context.Context, client *redis.Client, interval time.Duration) func() {
+	monitorCtx, cancel := context.WithCancel(parent)
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-monitorCtx.Done():
+				return
+			case <-ticker.C:
+				ctx, c := context.WithTimeout(context.Background(), 3*time.Second)
+				if err := client.Ping(ctx).Err(); err != nil {
+					log.Printf("Redis healthcheck failed: %v", err)
+				}
+				c()
+			}
+		}
+	}()
+	return cancel
+}
+
+// initializeNotificationService initializes the notification service
+func initializeNotificationService(cfg *config.ProductionConfig) services.NotificationService {
+	// Create SMS service based on configuration
+	var smsService services.SMSService
+	var emailProvider services.EmailProvider
+
+	switch cfg.SMS.ProviderDomain {
+	case "mock":
+		smsService = services.NewMockSMSService()
+	case "payamsms":
+		smsService = services.NewPayamSMSService(&cfg.SMS, &cfg.PayamSMS)
+	default:
+		smsService = services.NewSMSService(&cfg.SMS)
+	}
+
+	// Create email provider (mock for now)
+	emailProvider = services.NewMockEmailProvider()
+
+	var telegram services.TelegramSender
+	if cfg.Telegram.Enabled() {
+		telegram = services.NewTelegramClient(cfg.Telegram.BaseURL, cfg.Telegram.BotToken, cfg.Telegram.Timeout)
+	}
+
+	var push services.PushSender
+	if cfg.FCM.Enabled() {
+		client, err := services.LoadFCMClient(cfg.FCM.CredentialsFile, cfg.FCM.BaseURL, cfg.FCM.IIDBaseURL, cfg.FCM.Timeout)
+		if err != nil {
+			log.Printf("Push notifications disabled: %v", err)
+		} else {
+			push = client
+		}
+	}
+
+	return services.NewNotificationService(smsService, emailProvider, telegram, push)
+}
+
+// initializeStorage returns the store for private files, or nil when storage
+// is not configured
+func initializeStorage(cfg *config.ProductionConfig) storage.Store {
+	store, err := storage.New(cfg.Storage)
+	if err != nil {
+		log.Printf("File storage disabled: %v", err)
+		return nil
+	}
+	return store
+}
+
+// initializeFileScanner returns the virus scanner uploads pass through
+func initializeFileScanner(cfg *config.ProductionConfig) services.FileScanner {
+	if cfg.Uploads.ClamAVAddress == "" {
+		return services.NoopFileScanner{}
+	}
+	return services.NewClamAVScanner(cfg.Uploads.ClamAVAddress, cfg.Uploads.ClamAVTimeout)
+}
+
+// initializeShebaInquirer returns the bank inquiry Sheba numbers are verified
+// with, or nil while none is configured
+func initializeShebaInquirer(cfg *config.ProductionConfig) services.ShebaInquirer {
+	if !cfg.ShebaInquiry.Enabled() {
+		return nil
+	}
+	return services.NewJibitShebaClient(cfg.ShebaInquiry.BaseURL, cfg.ShebaInquiry.APIKey, cfg.ShebaInquiry.SecretKey, cfg.ShebaInquiry.Timeout)
+}
+
+// initializePasswordPolicy returns the policy new passwords are checked
+// against, looking up breached passwords when PASSWORD_BREACH_CHECK is on
+func initializePasswordPolicy(cfg *config.ProductionConfig, rc *redis.Client) services.PasswordPolicy {
+	var breaches services.BreachChecker
+	if cfg.Security.PasswordBreachCheck {
+		breaches = services.NewPwnedPasswordsClient(cfg.Security.PasswordBreachAPIURL, cfg.Security.PasswordBreachTimeout, rc, cfg.Security.PasswordBreachCacheTTL)
+	}
+	return services.NewPasswordPolicy(services.PasswordRules{
+		MinLength:     cfg.Security.PasswordMinLength,
+		MaxLength:     cfg.Security.PasswordMaxLength,
+		RequireUpper:  cfg.Security.PasswordRequireUpper,
+		RequireLower:  cfg.Security.PasswordRequireLower,
+		RequireNumber: cfg.Security.PasswordRequireNum,
+		RequireSymbol: cfg.Security.PasswordRequireSymbol,
+	}, breaches)
+}
+
+func initializeOTPSMSService(cfg *config.ProductionConfig) services.SMSService {
+	if cfg.SMS.ProviderDomain == "mock" {
+		return services.NewMockSMSService()
+	}
+
+	svc, err := services.NewPayamSMSServiceWithHTTPSProxy(&cfg.SMS, &cfg.PayamSMS, cfg.IRHTTPSProxy)
+	if err != nil {
+		log.Printf("Failed to initialize proxy-enabled OTP SMS service, falling back to direct connection: %v", err)
+		return services.NewPayamSMSService(&cfg.SMS, &cfg.PayamSMS)
+	}
+
+	return svc
+}
+
+// initializeHealthChecker builds the readiness checks: the databases and Redis
+// are critical, the SMS and payment providers are only reported
+func initializeHealthChecker(cfg *config.ProductionConfig, db, replicaDB *gorm.DB, rc *redis.Client) *health.Checker {
+	checks := []health.Check{health.DatabaseCheck("database", db)}
+	if replicaDB != nil {
+		checks = append(checks, health.DatabaseCheck("database_replica", replicaDB))
+	}
+	if rc != nil {
+		checks = append(checks, health.RedisCheck(rc))
+	}
+
+	// Provider checks are always registered so HEALTH_PROVIDER_CHECKS can be
+	// reloaded; the checker skips them while it is off
+	client := &http.Client{}
+	ttl := cfg.Health.ProviderCacheTTL
+	switch cfg.SMS.ProviderDomain {
+	case "mock":
+	case "payamsms":
+		if !cfg.Mocks.PayamSMS.Enabled {
+			checks = append(checks, health.ProviderCheck("sms_provider", cfg.PayamSMS.TokenURL, client, ttl))
+		}
+	default:
+		checks = append(checks, health.ProviderCheck("sms_provider", "https://"+cfg.SMS.ProviderDomain, client, ttl))
+	}
+	if !cfg.Mocks.Atipay.Enabled {
+		checks = append(checks, health.ProviderCheck("atipay", "https://mipg.atipay.net", client, ttl))
+	}
+	if cfg.Crypto.Oxapay.BaseURL != "" && !cfg.Mocks.Oxapay.Enabled {
+		checks = append(checks, health.ProviderCheck("oxapay", cfg.Crypto.Oxapay.BaseURL, client, ttl))
+	}
+	if cfg.Crypto.NowPayments.APIKey != "" {
+		checks = append(checks, health.ProviderCheck("nowpayments", cfg.Crypto.NowPayments.BaseURL+"/status", client, ttl))
+	}
+
+	checker := health.NewChecker(cfg.Health.CheckTimeout, checks...)
+	checker.SetOptionalChecks(cfg.Health.ProviderChecks)
+	return checker
+}
+
+// paymentGateways names the outbound clients of the configured payment
+// gateways, for the public status endpoint
+func paymentGateways(cfg *config.ProductionConfig) []string {
+	gateways := []string{"atipay"}
+	if cfg.Crypto.Oxapay.BaseURL != "" && cfg.Crypto.Oxapay.APIKey != "" {
+		gateways = append(gateways, "oxapay")
+	}
+	if cfg.Crypto.NowPayments.BaseURL != "" && cfg.Crypto.NowPayments.APIKey != "" {
+		gateways = append(gateways, "nowpayments")
+	}
+	return gateways
+}
+
+// Initialize builds the application for the given role. Every role gets the
+// same repositories and flows; the role decides whether the gRPC API and the
+// background workers are started.
+func Initialize(cfg *config.ProductionConfig, role Role) (*Application, error) {
+	var stopFuncs []func()
+
+	// Keep the secret provider login alive and watch for rotated credentials
+	stopFuncs = append(stopFuncs, config.StartSecretRenewal(cfg.Secrets))
+
+	// Initialize database
+	db, err := initializeDatabase(cfg.Database)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkMigrations(db, cfg.Database.MigrationCheck); err != nil {
+		return nil, err
+	}
+	replicaDB, err := initializeReadReplica(cfg.Database)
+	if err != nil {
+		return nil, err
+	}
+
+	rc, err := initializeCache(cfg.Cache)
+	if err != nil {
+		return nil, err
+	}
+
+	cancel := startCacheHealthMonitor(context.Background(), rc, cfg.Cache.CleanupInterval)
+	stopFuncs = append(stopFuncs, cancel)
+
+	// Init system/tax entities dynamically using config
+	if err := ensureSystemAndTaxEntities(db, cfg); err != nil {
+		return nil, err
+	}
+
+	// Initialize repositories
+	accountTypeRepo := repository.NewAccountTypeRepository(db)
+	customerRepo := repository.NewCustomerRepository(db)
+	sessionRepo := repository.NewCustomerSessionRepository(db)
+	knownDeviceRepo := repository.NewCustomerKnownDeviceRepository(db)
+	auditRepo := repository.NewAuditLogRepository(db)
+	if cfg.Logging.AuditBufferEnabled {
+		bufferedAuditRepo := repository.NewBufferedAuditLogRepository(
+			auditRepo,
+			log.Default(),
+			cfg.Logging.AuditBufferSize,
+			cfg.Logging.AuditBatchSize,
+			cfg.Logging.AuditFlushInterval,
+		)
+		auditRepo = bufferedAuditRepo
+		stopFuncs = append(stopFuncs, func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+			if err := bufferedAuditRepo.Close(ctx); err != nil {
+				log.Printf("audit log writer: drain incomplete: %v", err)
+			}
+		})
+	}
+	campaignRepo := repository.NewCampaignRepository(db)
+	walletRepo := repository.NewWalletRepository(db)
+	paymentRequestRepo := repository.NewPaymentRequestRepository(db)
+	balanceSnapshotRepo := repository.NewBalanceSnapshotRepository(db)
+	transactionRepo := repository.NewTransactionRepository(db)
+	agencyDiscountRepo := repository.NewAgencyDiscountRepository(db)
+	agencyDelegationRepo := repository.NewAgencyDelegationRepository(db)
+	depositReceiptRepo := repository.NewDepositReceiptRepository(db)
+	adminRepo := repository.NewAdminRepository(db)
+	lineNumberRepo := repository.NewLineNumberRepository(db)
+	lineNumberTierRepo := repository.NewLineNumberTierRepository(db)
+	lineNumberReservationRepo := repository.NewLineNumberReservationRepository(db)
+	botRepo := repository.NewBotRepository(db)
+	audienceProfileRepo := repository.NewAudienceProfileRepository(db)
+	tagRepo := repository.NewTagRepository(db)
+	srcLayerAllStatsRepo := repository.NewSrcLayerAllStatsRepository(db)
+	sentSMSRepo := repository.NewSentSMSRepository(db)
+	sentBaleMessageRepo := repository.NewSentBaleMessageRepository(db)
+	sentRubikaMessageRepo := repository.NewSentRubikaMessageRepository(db)
+	sentSplusMessageRepo := repository.NewSentSplusMessageRepository(db)
+	processedCampaignRepo := repository.NewProcessedCampaignRepository(db)
+	campaignStatusJobRepo := repository.NewCampaignStatusJobRepository(db)
+	smsStatusResultRepo := repository.NewSMSStatusResultRepository(db)
+	baleStatusResultRepo := repository.NewBaleStatusResultRepository(db)
+	rubikaStatusResultRepo := repository.NewRubikaStatusResultRepository(db)
+	splusStatusResultRepo := repository.NewSplusStatusResultRepository(db)
+	ticketRepo := repository.NewTicketRepository(db)
+	multimediaRepo := repository.NewMultimediaAssetRepository(db)
+	platformSettingsRepo := repository.NewPlatformSettingsRepository(db)
+	bundleRepo := repository.NewBundleRepository(db)
+	shortLinkRepo := repository.NewShortLinkRepository(db)
+	shortLinkClickRepo := repository.NewShortLinkClickRepository(db)
+	segmentPriceFactorRepo := repository.NewSegmentPriceFactorRepository(db)
+	platformBasePriceRepo := repository.NewPlatformBasePriceRepository(db)
+	pagePriceRepo := repository.NewPagePriceRepository(db)
+	smsTariffRepo := repository.NewSMSTariffRepository(db)
+	campaignTemplateRepo := repository.NewCampaignTemplateRepository(db)
+	campaignReviewRepo := repository.NewCampaignReviewRepository(db)
+	sendingQuotaRepo := repository.NewCustomerSendingQuotaRepository(db)
+	creditLineRepo := repository.NewCustomerCreditLineRepository(db)
+	postpaidDrawRepo := repository.NewPostpaidDrawRepository(db)
+	postpaidInvoiceRepo := repository.NewPostpaidInvoiceRepository(db)
+	agencyPayableRepo := repository.NewAgencySharePayableRepository(db)
+	taxInvoiceRepo := repository.NewTaxInvoiceRepository(db)
+	notificationRepo := repository.NewNotificationRepository(db)
+	telegramLinkRepo := repository.NewTelegramLinkRequestRepository(db)
+	pushDeviceRepo := repository.NewPushDeviceRepository(db)
+	bundleTagEvaluationRunRepo := repository.NewBundleTagEvaluationRunRepository(db)
+
+	// Route report, history and audience-count reads to the replica when configured
+	if n := repository.UseReadReplica(replicaDB, transactionRepo, balanceSnapshotRepo, campaignRepo, smsStatusResultRepo, audienceProfileRepo); n > 0 {
+		log.Printf("Read replica enabled for %d repositories", n)
+	}
+	bundleTagEvaluationEventRepo := repository.NewBundleTagEvaluationEventRepository(db)
+	bundleTagPersonaAttemptRepo := repository.NewBundleTagPersonaAnalysisAttemptRepository(db)
+	bundleTagEvaluationBatchRepo := repository.NewBundleTagEvaluationBatchRepository(db)
+	bundleTagEvaluationBatchAttemptRepo := repository.NewBundleTagEvaluationBatchAttemptRepository(db)
+	bundleTagScoreRepo := repository.NewBundleTagScoreRepository(db)
+	bundleTagEvaluationReadRepo := repository.NewBundleTagEvaluationReadRepository(db)
+	// Crypto payment repositories
+	cryptoPaymentRequestRepo := repository.NewCryptoPaymentRequestRepository(db)
+	cryptoDepositRepo := repository.NewCryptoDepositRepository(db)
+	cryptoWebhookEventRepo := repository.NewCryptoWebhookEventRepository(db)
+
+	// Initialize services
+	notificationService := initializeNotificationService(cfg)
+
+	// Background job queue; handlers are registered on every role so that
+	// whichever process holds worker leadership can run them
+	jobQueueFlow := businessflow.NewJobQueueFlow(repository.NewJobRepository(db), auditRepo, cfg.JobQueue)
+	jobQueueFlow.Register(models.JobTypeSendSMS, businessflow.NewSendSMSJobHandler(notificationService))
+	jobQueueFlow.Register(models.JobTypeSendEmail, businessflow.NewSendEmailJobHandler(notificationService))
+	jobQueueFlow.Register(models.JobTypePushAudienceUIDs, scheduler.NewPushAudienceUIDsJobHandler(cfg.Bot))
+
+	// Captcha service for admin
+	captchaSvc, err := services.NewCaptchaServiceRotate(2*time.Minute, 15, 300)
+	if err != nil {
+		return nil, err
+	}
+
+	// Initialize token service
+	tokenService, err := services.NewTokenService(
+		cfg.JWT.AccessTokenTTL,
+		cfg.JWT.RefreshTokenTTL,
+		cfg.JWT.Issuer,
+		cfg.JWT.Audience,
+		cfg.JWT.UseRSAKeys,
+		cfg.JWT.PrivateKey,
+		cfg.JWT.PublicKey,
+		cfg.JWT.SecretKey,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize token service: %w", err)
+	}
+
+	passwordHasher := services.NewArgon2idPasswordHasher(services.Argon2idParams{
+		Memory:      uint32(cfg.Security.Argon2Memory),
+		Iterations:  uint32(cfg.Security.Argon2Iterations),
+		Parallelism: uint8(cfg.Security.Argon2Parallelism),
+	})
+	passwordPolicy := initializePasswordPolicy(cfg, rc)
+
+	// Log that services are initialized
+	log.Printf("Token service initialized with issuer: %s, audience: %s", cfg.JWT.Issuer, cfg.JWT.Audience)
+
+	// Initialize flows
+	otpSMSService := initializeOTPSMSService(cfg)
+	localizer := i18n.NewLocalizer(cfg.I18n.DefaultLocale, cfg.I18n.AdminLocale)
+
+	otpThrottle := businessflow.NewOTPThrottle(rc, cfg.Security.OTPCooldown, cfg.Security.OTPDailyLimit)
+	sessionStore := services.NewRedisSessionStore(rc)
+	loginDetector := businessflow.NewSuspiciousLoginDetector(
+		knownDeviceRepo,
+		jobQueueFlow,
+		localizer,
+		cfg.Security.LoginAlertURL,
+		rc,
+	)
+
+	signupFlow := businessflow.NewSignupFlow(
+		customerRepo,
+		accountTypeRepo,
+		sessionRepo,
+		sessionStore,
+		auditRepo,
+		agencyDiscountRepo,
+		walletRepo,
+		tokenService,
+		passwordHasher,
+		passwordPolicy,
+		otpSMSService,
+		notificationService,
+		cfg.Admin,
+		localizer,
+		db,
+		rc,
+		otpThrottle,
+		loginDetector,
+	)
+
+	loginFlow := businessflow.NewLoginFlow(
+		customerRepo,
+		sessionRepo,
+		sessionStore,
+		auditRepo,
+		accountTypeRepo,
+		tokenService,
+		passwordHasher,
+		passwordPolicy,
+		otpSMSService,
+		notificationService,
+		localizer,
+		cfg.Admin,
+		db,
+		rc,
+		otpThrottle,
+		loginDetector,
+		cfg.Security.PasswordlessLoginEnabled,
+	)
+
+	smsPricingService := businessflow.NewSMSPricingService(smsTariffRepo)
+
+	campaignFlow := businessflow.NewCampaignFlow(
+		campaignRepo,
+		bundleRepo,
+		shortLinkRepo,
+		customerRepo,
+		multimediaRepo,
+		platformSettingsRepo,
+		walletRepo,
+		balanceSnapshotRepo,
+		transactionRepo,
+		auditRepo,
+		lineNumberRepo,
+		segmentPriceFactorRepo,
+		platformBasePriceRepo,
+		pagePriceRepo,
+		processedCampaignRepo,
+		smsStatusResultRepo,
+		repository.NewSMSRecipientRetryRepository(db),
+		shortLinkClickRepo,
+		audienceProfileRepo,
+		tagRepo,
+		campaignReviewRepo,
+		sendingQuotaRepo,
+		lineNumberReservationRepo,
+		creditLineRepo,
+		postpaidDrawRepo,
+		postpaidInvoiceRepo,
+		agencyDelegationRepo,
+		notificationRepo,
+		smsPricingService,
+		db,
+		rc,
+		jobQueueFlow,
+		cfg.Admin,
+		localizer,
+		cfg.Cache,
+		cfg.Bot,
+		cfg.PayamSMS,
+		cfg.Bale,
+		cfg.Rubika,
+		cfg.Splus,
+		cfg.KYC,
+		cfg.IRHTTPSProxy,
+	)
+
+	shebaVerificationFlow := businessflow.NewShebaVerificationFlow(
+		customerRepo,
+		auditRepo,
+		initializeShebaInquirer(cfg),
+		cfg.Cache,
+		rc,
+		cfg.ShebaInquiry.CacheTTL,
+	)
+
+	// Initialize PaymentFlow
+	paymentFlow := businessflow.NewPaymentFlow(
+		paymentRequestRepo,
+		walletRepo,
+		customerRepo,
+		campaignRepo,
+		auditRepo,
+		balanceSnapshotRepo,
+		transactionRepo,
+		agencyDiscountRepo,
+		depositReceiptRepo,
+		multimediaRepo,
+		taxInvoiceRepo,
+		notificationRepo,
+		agencyPayableRepo,
+		otpSMSService,
+		jobQueueFlow,
+		shebaVerificationFlow,
+		cfg.Admin,
+		localizer,
+		cfg.Cache,
+		rc,
+		db,
+		cfg.Atipay,
+		cfg.System,
+		cfg.Deployment,
+		cfg.Invoice,
+	)
+	paymentAdminFlow := businessflow.NewPaymentAdminFlow(
+		paymentRequestRepo,
+		walletRepo,
+		customerRepo,
+		auditRepo,
+		balanceSnapshotRepo,
+		transactionRepo,
+		agencyDiscountRepo,
+		depositReceiptRepo,
+		multimediaRepo,
+		taxInvoiceRepo,
+		notificationRepo,
+		agencyPayableRepo,
+		db,
+		cfg.Atipay,
+		cfg.System,
+		cfg.Deployment,
+		cfg.Invoice,
+	)
+
+	// Initialize CryptoPaymentFlow (providers registry)
+	providers := map[string]services.CryptoPaymentProvider{}
+	if cfg.Crypto.Oxapay.BaseURL != "" && cfg.Crypto.Oxapay.APIKey != "" {
+		providers["oxapay"] = services.NewOxapayClient(
+			cfg.Crypto.Oxapay.BaseURL,
+			cfg.Crypto.Oxapay.APIKey,
+			cfg.Crypto.Oxapay.Timeout,
+		)
+	}
+	if cfg.Crypto.NowPayments.BaseURL != "" && cfg.Crypto.NowPayments.APIKey != "" {
+		providers["nowpayments"] = services.NewNowPaymentsClient(
+			cfg.Crypto.NowPayments.BaseURL,
+			cfg.Crypto.NowPayments.APIKey,
+			cfg.Crypto.NowPayments.Timeout,
+		)
+	}
+	rateSources, err := services.NewExchangeRateSources(cfg.Crypto.Rates.Sources, cfg.Crypto.Rates.Timeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize exchange rate sources: %w", err)
+	}
+	exchangeRates := services.NewExchangeRateService(rc, rateSources, cfg.Crypto.Rates)
+	cryptoPaymentFlow := businessflow.NewCryptoPaymentFlow(
+		cryptoPaymentRequestRepo,
+		cryptoDepositRepo,
+		cryptoWebhookEventRepo,
+		walletRepo,
+		customerRepo,
+		balanceSnapshotRepo,
+		transactionRepo,
+		auditRepo,
+		agencyDiscountRepo,
+		taxInvoiceRepo,
+		notificationRepo,
+		pushDeviceRepo,
+		providers,
+		exchangeRates,
+		db,
+		cfg.System,
+		cfg.Deployment,
+		cfg.Crypto,
+		cfg.Invoice,
+		notificationService,
+		localizer,
+	)
+
+	// Initialize AgencyFlow
+	agencyFlow := businessflow.NewAgencyFlow(
+		customerRepo,
+		campaignRepo,
+		agencyDiscountRepo,
+		agencyDelegationRepo,
+		transactionRepo,
+		auditRepo,
+		db,
+	)
+
+	adminAuthFlow := businessflow.NewAdminAuthFlow(
+		adminRepo,
+		auditRepo,
+		tokenService,
+		passwordHasher,
+		captchaSvc,
+		services.NewTOTPService(cfg.Admin.TOTPIssuer),
+		otpSMSService,
+		cfg.Admin,
+		localizer,
+		rc,
+	)
+
+	botAuthFlow := businessflow.NewBotAuthFlow(
+		botRepo,
+		tokenService,
+		passwordHasher,
+	)
+
+	adminCampaignFlow := businessflow.NewAdminCampaignFlow(
+		campaignRepo,
+		customerRepo,
+		walletRepo,
+		balanceSnapshotRepo,
+		transactionRepo,
+		auditRepo,
+		platformSettingsRepo,
+		platformBasePriceRepo,
+		lineNumberRepo,
+		segmentPriceFactorRepo,
+		pagePriceRepo,
+		processedCampaignRepo,
+		campaignReviewRepo,
+		notificationRepo,
+		pushDeviceRepo,
+		db,
+		rc,
+		notificationService,
+		cfg.Admin,
+		localizer,
+		cfg.Cache,
+	)
+
+	lineNumberFlow := businessflow.NewLineNumberFlow(
+		lineNumberRepo,
+		lineNumberTierRepo,
+		lineNumberReservationRepo,
+		customerRepo,
+		walletRepo,
+		balanceSnapshotRepo,
+		transactionRepo,
+		auditRepo,
+		db,
+	)
+
+	adminLineNumberFlow := businessflow.NewAdminLineNumberFlow(lineNumberRepo, lineNumberTierRepo, db, auditRepo)
+
+	adminCustomerManagementFlow := businessflow.NewAdminCustomerManagementFlow(
+		customerRepo,
+		campaignRepo,
+		transactionRepo,
+		auditRepo,
+		lineNumberRepo,
+		segmentPriceFactorRepo,
+		sendingQuotaRepo,
+		sessionRepo,
+		sessionStore,
+		tokenService,
+		cfg.Admin.ImpersonationTTL,
+		cfg.KYC,
+	)
+
+	botCampaignFlow := businessflow.NewBotCampaignFlow(
+		campaignRepo,
+		multimediaRepo,
+		platformSettingsRepo,
+		transactionRepo,
+		platformBasePriceRepo,
+		cfg.Cache,
+		db,
+		rc,
+	)
+	botShortLinkFlow := businessflow.NewBotShortLinkFlow(shortLinkRepo, db)
+
+	ticketFlow := businessflow.NewTicketFlow(customerRepo, ticketRepo, jobQueueFlow, cfg.Admin, localizer)
+	multimediaFlow := businessflow.NewMultimediaFlow(customerRepo, multimediaRepo)
+	multimediaAdminFlow := businessflow.NewMultimediaAdminFlow(customerRepo, multimediaRepo)
+	multimediaBotFlow := businessflow.NewMultimediaBotFlow(multimediaRepo)
+	platformSettingsFlow := businessflow.NewPlatformSettingsFlow(platformSettingsRepo, multimediaRepo, jobQueueFlow, cfg.Admin, localizer)
+	bundleFlow := businessflow.NewBundleFlow(bundleRepo, campaignRepo, customerRepo, auditRepo, bundleTagEvaluationReadRepo, db)
+	bundleTagEvaluationFlow := businessflow.NewBundleTagEvaluationFlow(
+		bundleRepo,
+		customerRepo,
+		tagRepo,
+		bundleTagEvaluationRunRepo,
+		bundleTagEvaluationEventRepo,
+		bundleTagPersonaAttemptRepo,
+		bundleTagEvaluationBatchRepo,
+		bundleTagEvaluationBatchAttemptRepo,
+		bundleTagScoreRepo,
+		bundleTagEvaluationReadRepo,
+		db,
+		cfg.SmartTagEvaluation,
+	)
+	platformSettingsAdminFlow := businessflow.NewPlatformSettingsAdminFlow(platformSettingsRepo, multimediaRepo)
+	platformBasePriceFlow := businessflow.NewPlatformBasePriceFlow(platformBasePriceRepo)
+	platformBasePriceAdminFlow := businessflow.NewPlatformBasePriceAdminFlow(platformBasePriceRepo, auditRepo)
+
+	shortLinkVisitFlow := businessflow.NewShortLinkVisitFlow(shortLinkRepo, shortLinkClickRepo)
+
+	// Admin short-links flows and handler
+	adminShortLinkFlow := businessflow.NewAdminShortLinkFlow(shortLinkRepo, shortLinkClickRepo, auditRepo)
+	adminShortLinkDownloadFlow := businessflow.NewAdminShortLinkFlow(shortLinkRepo, shortLinkClickRepo, auditRepo)
+	adminShortLinkClicksDownloadFlow := businessflow.NewAdminShortLinkFlow(shortLinkRepo, shortLinkClickRepo, auditRepo)
+
+	// Profile flow
+	profileChangeRepo := repository.NewCustomerProfileChangeRepository(db)
+	profileFlow := businessflow.NewProfileFlow(
+		customerRepo,
+		profileChangeRepo,
+		auditRepo,
+		db,
+	)
+	customerDataFlow := businessflow.NewCustomerDataFlow(
+		repository.NewCustomerDataRequestRepository(db),
+		customerRepo,
+		campaignRepo,
+		transactionRepo,
+		walletRepo,
+		sessionRepo,
+		knownDeviceRepo,
+		profileChangeRepo,
+		auditRepo,
+		sessionStore,
+		passwordHasher,
+		db,
+		cfg.Scheduler.AccountDeletionGracePeriod,
+		cfg.Scheduler.DataExportRetention,
+	)
+
+	segmentPriceFactorFlow := businessflow.NewSegmentPriceFactorFlow(segmentPriceFactorRepo)
+	smsTariffAdminFlow := businessflow.NewSMSTariffAdminFlow(smsTariffRepo, auditRepo)
+	campaignTemplateFlow := businessflow.NewCampaignTemplateFlow(campaignTemplateRepo, customerRepo, auditRepo, campaignFlow)
+	campaignRecurrenceFlow := businessflow.NewCampaignRecurrenceFlow(campaignRepo, walletRepo, balanceSnapshotRepo, transactionRepo, auditRepo, db)
+	audienceImportFlow := businessflow.NewAudienceImportFlow(repository.NewAudienceImportJobRepository(db), audienceProfileRepo, tagRepo, auditRepo, db)
+	partitionMaintenanceFlow := businessflow.NewPartitionMaintenanceFlow(
+		repository.NewPartitionRepository(db),
+		repository.NewPartitionArchiveRepository(db),
+		cfg.Scheduler.PartitionArchiveDir,
+	)
+	reportRollupRepo := repository.NewReportRollupRepository(db)
+	reportRollupFlow := businessflow.NewReportRollupFlow(reportRollupRepo, cfg.Scheduler.ReportRollupLookbackDays)
+	audienceColorFlow := businessflow.NewAudienceColorFlow(repository.NewAudienceColorTransitionRepository(db), businessflow.AudienceColorRules{
+		Lookback:              cfg.Scheduler.AudienceColorLookback,
+		DemoteFailures:        cfg.Scheduler.AudienceColorDemoteFailures,
+		DemoteOptOuts:         cfg.Scheduler.AudienceColorDemoteOptOuts,
+		DemotePermanentErrors: cfg.Scheduler.AudienceColorDemotePermanentErrors,
+		PromoteClicks:         cfg.Scheduler.AudienceColorPromoteClicks,
+		BatchSize:             cfg.Scheduler.AudienceColorBatchSize,
+	})
+	blacklistFlow := businessflow.NewBlacklistFlow(repository.NewBlacklistedNumberRepository(db), auditRepo)
+
+	// Settlement reports are per merchant API key; terminals sharing a key
+	// share its report
+	var atipaySettlementFetchers []services.AtipaySettlementReportFetcher
+	if cfg.Atipay.SettlementReportURL != "" {
+		terminals, err := cfg.Atipay.AllTerminals()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read Atipay terminals: %w", err)
+		}
+		seen := make(map[string]bool)
+		for _, t := range terminals {
+			if seen[t.APIKey] {
+				continue
+			}
+			seen[t.APIKey] = true
+			atipaySettlementFetchers = append(atipaySettlementFetchers, services.NewAtipaySettlementClient(cfg.Atipay.SettlementReportURL, t.APIKey, time.Minute))
+		}
+	}
+	atipayReconciliationFlow := businessflow.NewAtipayReconciliationFlow(
+		db,
+		repository.NewAtipayReconciliationRepository(db),
+		paymentRequestRepo,
+		auditRepo,
+		atipaySettlementFetchers,
+	)
+	walletAdjustmentFlow := businessflow.NewWalletAdjustmentFlow(
+		db,
+		repository.NewWalletAdjustmentRequestRepository(db),
+		customerRepo,
+		walletRepo,
+		balanceSnapshotRepo,
+		transactionRepo,
+		auditRepo,
+	)
+	walletTransferFlow := businessflow.NewWalletTransferFlow(
+		db,
+		repository.NewWalletTransferRepository(db),
+		customerRepo,
+		walletRepo,
+		balanceSnapshotRepo,
+		transactionRepo,
+		auditRepo,
+		otpSMSService,
+		otpThrottle,
+		localizer,
+		cfg.WalletTransfer,
+	)
+	fileStore := initializeStorage(cfg)
+	kycFlow := businessflow.NewKYCFlow(
+		customerRepo,
+		repository.NewKYCDocumentRepository(db),
+		auditRepo,
+		fileStore,
+		initializeFileScanner(cfg),
+		cfg.Storage,
+		cfg.KYC,
+		db,
+	)
+	frozenBudgetAuditFlow := businessflow.NewFrozenBudgetAuditFlow(transactionRepo)
+	adminBulkOperationFlow := businessflow.NewAdminBulkOperationFlow(
+		db,
+		adminCustomerManagementFlow,
+		cryptoPaymentFlow,
+		customerRepo,
+		agencyDiscountRepo,
+		agencyDelegationRepo,
+		cryptoPaymentRequestRepo,
+		auditRepo,
+	)
+	postpaidBillingFlow := businessflow.NewPostpaidBillingFlow(
+		db,
+		customerRepo,
+		creditLineRepo,
+		postpaidDrawRepo,
+		postpaidInvoiceRepo,
+		auditRepo,
+		cfg.Scheduler.PostpaidInvoiceDueDays,
+	)
+
+	// Invoices are issued without the PDF fonts; only downloads need them
+	var taxInvoiceRenderer services.TaxInvoiceRenderer
+	if renderer, err := services.LoadTaxInvoicePDFRenderer(cfg.Invoice.FontPath, cfg.Invoice.BoldFontPath); err != nil {
+		log.Printf("Invoice PDF downloads disabled: %v", err)
+	} else {
+		taxInvoiceRenderer = renderer
+	}
+	taxInvoiceFlow := businessflow.NewTaxInvoiceFlow(taxInvoiceRepo, taxInvoiceRenderer, cfg.Invoice.StorageDir)
+
+	// Invoices issued while Moadian is disabled are queued and submitted
+	// once it is enabled
+	var moadianFlow businessflow.MoadianFlow
+	if cfg.Moadian.Enabled {
+		client, err := services.LoadMoadianHTTPClient(
+			cfg.Moadian.BaseURL,
+			cfg.Moadian.MemoryID,
+			cfg.Moadian.PrivateKeyPath,
+			cfg.Moadian.CertificatePath,
+			cfg.Moadian.Timeout,
+			cfg.IRHTTPSProxy,
+		)
+		if err != nil {
+			log.Printf("Moadian submission disabled: %v", err)
+		} else {
+			moadianFlow = businessflow.NewMoadianFlow(
+				db,
+				repository.NewMoadianSubmissionRepository(db),
+				taxInvoiceRepo,
+				paymentRequestRepo,
+				client,
+				cfg.Moadian,
+			)
+		}
+	}
+
+	accessControlFlow := businessflow.NewAccessControlFlow(adminRepo, repository.NewACLChangeRequestRepository(db), auditRepo)
+
+	// Initialize handlers
+	authHandler := handlers.NewAuthHandler(signupFlow, loginFlow, cfg.Server.CountryHeader)
+	bundleHandler := handlers.NewBundleHandler(bundleFlow, bundleTagEvaluationFlow)
+	campaignHandler := handlers.NewCampaignHandler(campaignFlow)
+	paymentHandler := handlers.NewPaymentHandler(paymentFlow)
+	paymentAdminHandler := handlers.NewPaymentAdminHandler(paymentAdminFlow)
+	cryptoPaymentHandler := handlers.NewCryptoPaymentHandler(cryptoPaymentFlow, cfg)
+	agencyHandler := handlers.NewAgencyHandler(agencyFlow)
+	authAdminHandler := handlers.NewAuthAdminHandler(adminAuthFlow)
+	authBotHandler := handlers.NewAuthBotHandler(botAuthFlow)
+	campaignAdminHandler := handlers.NewCampaignAdminHandler(adminCampaignFlow)
+	lineNumberHandler := handlers.NewLineNumberHandler(lineNumberFlow)
+	lineNumberAdminHandler := handlers.NewLineNumberAdminHandler(adminLineNumberFlow)
+	adminCustomerManagementHandler := handlers.NewAdminCustomerManagementHandler(adminCustomerManagementFlow)
+	campaignBotHandler := handlers.NewCampaignBotHandler(botCampaignFlow)
+	shortLinkBotHandler := handlers.NewShortLinkBotHandler(botShortLinkFlow)
+	shortLinkHandler := handlers.NewShortLinkHandler(shortLinkVisitFlow)
+	shortLinkAdminHandler := handlers.NewShortLinkAdminHandler(adminShortLinkFlow, adminShortLinkDownloadFlow, adminShortLinkClicksDownloadFlow)
+	audienceImportAdminHandler := handlers.NewAudienceImportAdminHandler(audienceImportFlow)
+	jobAdminHandler := handlers.NewJobAdminHandler(jobQueueFlow)
+	apiDocsAdminHandler := handlers.NewAPIDocsAdminHandler()
+	blacklistAdminHandler := handlers.NewBlacklistAdminHandler(blacklistFlow)
+	atipayReconciliationAdminHandler := handlers.NewAtipayReconciliationAdminHandler(atipayReconciliationFlow)
+	walletAdjustmentAdminHandler := handlers.NewWalletAdjustmentAdminHandler(walletAdjustmentFlow)
+	walletTransferHandler := handlers.NewWalletTransferHandler(walletTransferFlow)
+	walletTransferAdminHandler := handlers.NewWalletTransferAdminHandler(walletTransferFlow)
+	walletChargeHandler := handlers.NewWalletChargeHandler(businessflow.NewWalletChargeFlow(
+		paymentFlow,
+		cryptoPaymentFlow,
+		paymentRequestRepo,
+		cryptoPaymentRequestRepo,
+		depositReceiptRepo,
+	))
+	frozenBudgetAuditAdminHandler := handlers.NewFrozenBudgetAuditAdminHandler(frozenBudgetAuditFlow)
+	adminBulkOperationHandler := handlers.NewAdminBulkOperationHandler(adminBulkOperationFlow)
+	postpaidBillingHandler := handlers.NewPostpaidBillingHandler(postpaidBillingFlow)
+	postpaidBillingAdminHandler := handlers.NewPostpaidBillingAdminHandler(postpaidBillingFlow)
+	taxInvoiceHandler := handlers.NewTaxInvoiceHandler(taxInvoiceFlow)
+	taxInvoiceAdminHandler := handlers.NewTaxInvoiceAdminHandler(taxInvoiceFlow)
+	adminReportHandler := handlers.NewAdminReportHandler(
+		businessflow.NewAdminReportFlow(transactionRepo, balanceSnapshotRepo, reportRollupRepo, cfg.System),
+		reportRollupFlow,
+	)
+	customerAnalyticsHandler := handlers.NewCustomerAnalyticsHandler(businessflow.NewCustomerAnalyticsFlow(reportRollupRepo, campaignRepo))
+	kycHandler := handlers.NewKYCHandler(kycFlow)
+	kycAdminHandler := handlers.NewKYCAdminHandler(kycFlow)
+	fileHandler := handlers.NewFileHandler(fileStore)
+
+	ticketHandler := handlers.NewTicketHandler(ticketFlow)
+	multimediaHandler := handlers.NewMultimediaHandler(multimediaFlow)
+	multimediaAdminHandler := handlers.NewMultimediaAdminHandler(multimediaAdminFlow)
+	multimediaBotHandler := handlers.NewMultimediaBotHandler(multimediaBotFlow)
+	platformSettingsHandler := handlers.NewPlatformSettingsHandler(platformSettingsFlow)
+	platformSettingsAdminHandler := handlers.NewPlatformSettingsAdminHandler(platformSettingsAdminFlow)
+	platformBasePriceHandler := handlers.NewPlatformBasePriceHandler(platformBasePriceFlow)
+	accessControlHandler := handlers.NewAccessControlHandler(accessControlFlow)
+
+	profileHandler := handlers.NewProfileHandler(profileFlow, shebaVerificationFlow)
+	profileChangeAdminHandler := handlers.NewProfileChangeAdminHandler(profileFlow)
+	customerDataHandler := handlers.NewCustomerDataHandler(customerDataFlow)
+
+	segmentPriceFactorAdminHandler := handlers.NewSegmentPriceFactorAdminHandler(segmentPriceFactorFlow)
+	segmentPriceFactorHandler := handlers.NewSegmentPriceFactorHandler(segmentPriceFactorFlow)
+	smsTariffAdminHandler := handlers.NewSMSTariffAdminHandler(smsTariffAdminFlow)
+	campaignTemplateHandler := handlers.NewCampaignTemplateHandler(campaignTemplateFlow)
+	platformBasePriceAdminHandler := handlers.NewPlatformBasePriceAdminHandler(platformBasePriceAdminFlow)
+
+	healthChecker := initializeHealthChecker(cfg, db, replicaDB, rc)
+	healthHandler := handlers.NewHealthHandler(healthChecker, paymentGateways(cfg))
+
+	// Settings that can be reloaded without a restart (SIGHUP or the admin endpoint)
+	runtimeCfg := config.NewRuntime(cfg)
+	runtimeConfigFlow := businessflow.NewRuntimeConfigFlow(runtimeCfg, auditRepo)
+	runtimeConfigAdminHandler := handlers.NewRuntimeConfigAdminHandler(runtimeConfigFlow)
+
+	sandboxFlow := businessflow.NewSandboxFlow(
+		db,
+		customerRepo,
+		walletRepo,
+		balanceSnapshotRepo,
+		transactionRepo,
+		campaignRepo,
+		auditRepo,
+	)
+	sandboxHandler := handlers.NewSandboxHandler(sandboxFlow)
+	sandboxAdminHandler := handlers.NewSandboxAdminHandler(sandboxFlow)
+	recordAdminFlow := businessflow.NewRecordAdminFlow(campaignRepo, audienceProfileRepo, tagRepo, lineNumberRepo, auditRepo)
+	recordAdminHandler := handlers.NewRecordAdminHandler(recordAdminFlow)
+	campaignTimelineFlow := businessflow.NewCampaignTimelineFlow(
+		campaignRepo,
+		auditRepo,
+		campaignReviewRepo,
+		processedCampaignRepo,
+		campaignStatusJobRepo,
+		smsStatusResultRepo,
+		baleStatusResultRepo,
+		rubikaStatusResultRepo,
+		splusStatusResultRepo,
+	)
+	campaignTimelineAdminHandler := handlers.NewCampaignTimelineAdminHandler(campaignTimelineFlow)
+	customerTimelineFlow := businessflow.NewCustomerTimelineFlow(customerRepo, repository.NewCustomerActivityRepository(db))
+	customerTimelineAdminHandler := handlers.NewCustomerTimelineAdminHandler(customerTimelineFlow)
+	notificationFlow := businessflow.NewNotificationFlow(notificationRepo, customerRepo, localizer)
+	notificationHandler := handlers.NewNotificationHandler(notificationFlow)
+	telegramLinkFlow := businessflow.NewTelegramLinkFlow(telegramLinkRepo, customerRepo, auditRepo, db, cfg.Telegram, notificationService, localizer)
+	telegramHandler := handlers.NewTelegramHandler(telegramLinkFlow)
+	pushDeviceFlow := businessflow.NewPushDeviceFlow(pushDeviceRepo, customerRepo, auditRepo, cfg.FCM, notificationService)
+	pushHandler := handlers.NewPushHandler(pushDeviceFlow)
+	pushAdminHandler := handlers.NewPushAdminHandler(pushDeviceFlow)
+
+	// Initialize auth middleware
+	authMiddleware := middleware.NewAuthMiddleware(tokenService, sessionStore, sessionRepo)
+	authzMiddleware := middleware.NewAuthorizationMiddleware(adminRepo)
+	var rateLimitWindow middleware.SlidingWindow
+	if rc != nil {
+		rateLimitWindow = middleware.NewRedisSlidingWindow(rc)
+	}
+	rateLimitMiddleware := middleware.NewRateLimitMiddleware(rateLimitWindow, cfg.Security)
+	runtimeCfg.Subscribe(func(s config.RuntimeSettings) {
+		rateLimitMiddleware.SetLimits(s.Security(cfg.Security))
+		otpThrottle.SetLimits(s.OTPCooldown, s.OTPDailyLimit)
+		healthChecker.SetOptionalChecks(s.HealthProviderChecks)
+		observability.SetSentryCapture(s.SentryCapture4xx, s.SentryCapture5xx)
+	})
+	adminIPAllowlist, err := middleware.NewIPAllowlistMiddleware(cfg.Security.AdminIPAllowlist, auditRepo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize admin IP allowlist: %w", err)
+	}
+
+	// Initialize router
+	appRouter := router.NewFiberRouter(
+		authHandler,
+		bundleHandler,
+		campaignHandler,
+		paymentHandler,
+		paymentAdminHandler,
+		agencyHandler,
+		authMiddleware,
+		authzMiddleware,
+		rateLimitMiddleware,
+		adminIPAllowlist,
+		authAdminHandler,
+		authBotHandler,
+		campaignAdminHandler,
+		lineNumberHandler,
+		lineNumberAdminHandler,
+		segmentPriceFactorAdminHandler,
+		platformBasePriceAdminHandler,
+		platformBasePriceHandler,
+		segmentPriceFactorHandler,
+		smsTariffAdminHandler,
+		campaignTemplateHandler,
+		adminCustomerManagementHandler,
+		campaignBotHandler,
+		ticketHandler,
+		shortLinkBotHandler,
+		shortLinkHandler,
+		shortLinkAdminHandler,
+		audienceImportAdminHandler,
+		jobAdminHandler,
+		apiDocsAdminHandler,
+		blacklistAdminHandler,
+		atipayReconciliationAdminHandler,
+		walletAdjustmentAdminHandler,
+		walletTransferHandler,
+		walletTransferAdminHandler,
+		walletChargeHandler,
+		frozenBudgetAuditAdminHandler,
+		adminBulkOperationHandler,
+		postpaidBillingHandler,
+		postpaidBillingAdminHandler,
+		taxInvoiceHandler,
+		taxInvoiceAdminHandler,
+		adminReportHandler,
+		customerAnalyticsHandler,
+		kycHandler,
+		kycAdminHandler,
+		fileHandler,
+		cryptoPaymentHandler,
+		profileHandler,
+		profileChangeAdminHandler,
+		customerDataHandler,
+		multimediaHandler,
+		multimediaAdminHandler,
+		multimediaBotHandler,
+		platformSettingsHandler,
+		platformSettingsAdminHandler,
+		accessControlHandler,
+		healthHandler,
+		runtimeConfigAdminHandler,
+		sandboxHandler,
+		sandboxAdminHandler,
+		recordAdminHandler,
+		campaignTimelineAdminHandler,
+		customerTimelineAdminHandler,
+		notificationHandler,
+		telegramHandler,
+		pushHandler,
+		pushAdminHandler,
+		cfg.Server,
+	)
+
+	// The background workers are built afresh for every leadership term, as
+	// their goroutines end with the context they were started with. Reloaded
+	// campaign timing goes to the schedulers of the current term.
+	var (
+		timingMu    sync.Mutex
+		applyTiming func(config.RuntimeSettings)
+	)
+	runtimeCfg.Subscribe(func(s config.RuntimeSettings) {
+		timingMu.Lock()
+		defer timingMu.Unlock()
+		if applyTiming != nil {
+			applyTiming(s)
+		}
+	})
+	startWorkers := func(ctx context.Context) func() {
+		var workerStops []func()
+
+		if cfg.Scheduler.CampaignExecutionEnabled {
+			// Start SMS campaign scheduler.
+			smsSched := scheduler.NewCampaignScheduler(
+				audienceProfileRepo,
+				tagRepo,
+				sentSMSRepo,
+				processedCampaignRepo,
+				campaignStatusJobRepo,
+				smsStatusResultRepo,
+				srcLayerAllStatsRepo,
+				jobQueueFlow,
+				db,
+				log.Default(),
+				cfg.Scheduler.CampaignExecutionInterval,
+				cfg.PayamSMS,
+				cfg.Bot,
+				cfg.Admin,
+			)
+			if cfg.Scheduler.SMSRetryEnabled {
+				smsSched.SetRetryPolicy(scheduler.SMSRetryPolicy{
+					MaxAttempts:    cfg.Scheduler.SMSRetryMaxAttempts,
+					Delay:          cfg.Scheduler.SMSRetryDelay,
+					TransientCodes: cfg.Scheduler.SMSRetryTransientCodes,
+					PermanentCodes: cfg.Scheduler.SMSRetryPermanentCodes,
+				})
+			}
+			jobQueueFlow.Register(models.JobTypeSendSMSBatch, smsSched.ResendBatch)
+			stopSMSScheduler := smsSched.Start(ctx)
+			workerStops = append(workerStops, stopSMSScheduler)
+
+			// Start Bale campaign scheduler.
+			baleSched := scheduler.NewBaleCampaignScheduler(
+				audienceProfileRepo,
+				tagRepo,
+				sentBaleMessageRepo,
+				processedCampaignRepo,
+				campaignStatusJobRepo,
+				baleStatusResultRepo,
+				srcLayerAllStatsRepo,
+				jobQueueFlow,
+				db,
+				log.Default(),
+				cfg.Scheduler.CampaignExecutionInterval,
+				cfg.Scheduler.MessageSendDelay,
+				cfg.Bale,
+				cfg.Bot,
+				cfg.Admin,
+			)
+			stopBaleScheduler := baleSched.Start(ctx)
+			workerStops = append(workerStops, stopBaleScheduler)
+
+			// Start Rubika campaign scheduler.
+			rubikaSched := scheduler.NewRubikaCampaignScheduler(
+				audienceProfileRepo,
+				tagRepo,
+				sentRubikaMessageRepo,
+				processedCampaignRepo,
+				campaignStatusJobRepo,
+				rubikaStatusResultRepo,
+				srcLayerAllStatsRepo,
+				jobQueueFlow,
+				db,
+				log.Default(),
+				cfg.Scheduler.CampaignExecutionInterval,
+				cfg.Scheduler.MessageSendDelay,
+				cfg.Rubika,
+				cfg.Bot,
+				cfg.Admin,
+			)
+			stopRubikaScheduler := rubikaSched.Start(ctx)
+			workerStops = append(workerStops, stopRubikaScheduler)
+
+			// Start Splus campaign scheduler.
+			splusSched := scheduler.NewSplusCampaignScheduler(
+				audienceProfileRepo,
+				tagRepo,
+				sentSplusMessageRepo,
+				processedCampaignRepo,
+				campaignStatusJobRepo,
+				splusStatusResultRepo,
+				srcLayerAllStatsRepo,
+				jobQueueFlow,
+				db,
+				log.Default(),
+				cfg.Scheduler.CampaignExecutionInterval,
+				cfg.Scheduler.MessageSendDelay,
+				cfg.Splus,
+				cfg.Bot,
+				cfg.Admin,
+			)
+			stopSplusScheduler := splusSched.Start(ctx)
+			workerStops = append(workerStops, stopSplusScheduler)
+
+			setTiming := func(s config.RuntimeSettings) {
+				smsSched.SetTiming(s.CampaignExecutionInterval, 0)
+				baleSched.SetTiming(s.CampaignExecutionInterval, s.MessageSendDelay)
+				rubikaSched.SetTiming(s.CampaignExecutionInterval, s.MessageSendDelay)
+				splusSched.SetTiming(s.CampaignExecutionInterval, s.MessageSendDelay)
+			}
+			setTiming(runtimeCfg.Settings())
+			timingMu.Lock()
+			applyTiming = setTiming
+			timingMu.Unlock()
+			workerStops = append(workerStops, func() {
+				timingMu.Lock()
+				applyTiming = nil
+				timingMu.Unlock()
+			})
+		}
+
+		if cfg.Scheduler.RecurrenceEnabled {
+			recurrenceSched := scheduler.NewCampaignRecurrenceScheduler(
+				campaignRecurrenceFlow,
+				log.Default(),
+				cfg.Scheduler.RecurrenceInterval,
+				cfg.Scheduler.RecurrenceLookahead,
+			)
+			stopRecurrenceScheduler := recurrenceSched.Start(ctx)
+			workerStops = append(workerStops, stopRecurrenceScheduler)
+		}
+
+		if cfg.Scheduler.AudienceImportEnabled {
+			audienceImportSched := scheduler.NewAudienceImportScheduler(
+				audienceImportFlow,
+				log.Default(),
+				cfg.Scheduler.AudienceImportInterval,
+			)
+			stopAudienceImportScheduler := audienceImportSched.Start(ctx)
+			workerStops = append(workerStops, stopAudienceImportScheduler)
+		}
+
+		if cfg.Scheduler.CustomerDataEnabled {
+			customerDataSched := scheduler.NewCustomerDataScheduler(
+				customerDataFlow,
+				log.Default(),
+				cfg.Scheduler.CustomerDataInterval,
+			)
+			stopCustomerDataScheduler := customerDataSched.Start(ctx)
+			workerStops = append(workerStops, stopCustomerDataScheduler)
+		}
+
+		if cfg.Scheduler.PartitionMaintenanceEnabled {
+			partitionSched := scheduler.NewPartitionMaintenanceScheduler(
+				partitionMaintenanceFlow,
+				log.Default(),
+				cfg.Scheduler.PartitionMaintenanceInterval,
+				cfg.Scheduler.PartitionMonthsAhead,
+				cfg.Scheduler.PartitionRetentionMonths,
+			)
+			stopPartitionScheduler := partitionSched.Start(ctx)
+			workerStops = append(workerStops, stopPartitionScheduler)
+		}
+
+		if rules := storage.Rules(cfg.Storage.Lifecycle); cfg.Scheduler.StorageLifecycleEnabled && fileStore != nil && len(rules) > 0 {
+			storageLifecycleSched := scheduler.NewStorageLifecycleScheduler(
+				fileStore,
+				rules,
+				log.Default(),
+				cfg.Scheduler.StorageLifecycleInterval,
+			)
+			stopStorageLifecycleScheduler := storageLifecycleSched.Start(ctx)
+			workerStops = append(workerStops, stopStorageLifecycleScheduler)
+		}
+
+		if cfg.Scheduler.ReportRollupEnabled {
+			reportRollupSched := scheduler.NewReportRollupScheduler(
+				reportRollupFlow,
+				log.Default(),
+				cfg.Scheduler.ReportRollupInterval,
+			)
+			stopReportRollupScheduler := reportRollupSched.Start(ctx)
+			workerStops = append(workerStops, stopReportRollupScheduler)
+		}
+
+		if cfg.Scheduler.AudienceColorEnabled {
+			audienceColorSched := scheduler.NewAudienceColorScheduler(
+				audienceColorFlow,
+				log.Default(),
+				cfg.Scheduler.AudienceColorInterval,
+			)
+			stopAudienceColorScheduler := audienceColorSched.Start(ctx)
+			workerStops = append(workerStops, stopAudienceColorScheduler)
+		}
+
+		if cfg.Scheduler.CryptoExpiryEnabled {
+			cryptoExpirySched := scheduler.NewCryptoExpiryScheduler(
+				cryptoPaymentFlow,
+				log.Default(),
+				cfg.Scheduler.CryptoExpiryInterval,
+			)
+			stopCryptoExpiryScheduler := cryptoExpirySched.Start(ctx)
+			workerStops = append(workerStops, stopCryptoExpiryScheduler)
+		}
+
+		if cfg.Scheduler.PaymentExpiryEnabled {
+			paymentExpirySched := scheduler.NewPaymentExpiryScheduler(
+				paymentFlow,
+				log.Default(),
+				cfg.Scheduler.PaymentExpiryInterval,
+			)
+			stopPaymentExpiryScheduler := paymentExpirySched.Start(ctx)
+			workerStops = append(workerStops, stopPaymentExpiryScheduler)
+		}
+
+		if cfg.Scheduler.CampaignExpiryEnabled {
+			campaignExpirySched := scheduler.NewCampaignExpiryScheduler(
+				campaignFlow,
+				log.Default(),
+				cfg.Scheduler.CampaignExpiryInterval,
+			)
+			stopCampaignExpiryScheduler := campaignExpirySched.Start(ctx)
+			workerStops = append(workerStops, stopCampaignExpiryScheduler)
+		}
+
+		if cfg.Scheduler.AtipayReconciliationEnabled {
+			atipayReconciliationSched := scheduler.NewAtipayReconciliationScheduler(
+				atipayReconciliationFlow,
+				log.Default(),
+				cfg.Scheduler.AtipayReconciliationInterval,
+			)
+			stopAtipayReconciliationScheduler := atipayReconciliationSched.Start(ctx)
+			workerStops = append(workerStops, stopAtipayReconciliationScheduler)
+		}
+
+		if cfg.Scheduler.PostpaidInvoicingEnabled {
+			postpaidInvoiceSched := scheduler.NewPostpaidInvoiceScheduler(
+				postpaidBillingFlow,
+				log.Default(),
+				cfg.Scheduler.PostpaidInvoicingInterval,
+			)
+			stopPostpaidInvoiceScheduler := postpaidInvoiceSched.Start(ctx)
+			workerStops = append(workerStops, stopPostpaidInvoiceScheduler)
+		}
+
+		if moadianFlow != nil {
+			moadianSched := scheduler.NewMoadianSubmissionScheduler(
+				moadianFlow,
+				log.Default(),
+				cfg.Moadian.Interval,
+			)
+			stopMoadianScheduler := moadianSched.Start(ctx)
+			workerStops = append(workerStops, stopMoadianScheduler)
+		}
+
+		if cfg.SmartTagEvaluation.Enabled && cfg.SmartTagEvaluation.Scheduler.Enabled {
+			smartTagScheduler := scheduler.NewBundleTagEvaluationScheduler(
+				bundleTagEvaluationFlow,
+				bundleTagEvaluationReadRepo,
+				log.Default(),
+				cfg.SmartTagEvaluation.Scheduler.PollInterval,
+				cfg.SmartTagEvaluation.Scheduler.MaxParallelRuns,
+			)
+			stopSmartTagScheduler := smartTagScheduler.Start(ctx)
+			workerStops = append(workerStops, stopSmartTagScheduler)
+		}
+
+		// Started last so the handlers the schedulers register are in place
+		if cfg.JobQueue.Enabled {
+			jobQueueSched := scheduler.NewJobQueueScheduler(
+				jobQueueFlow,
+				log.Default(),
+				cfg.JobQueue.PollInterval,
+				cfg.JobQueue.Workers,
+			)
+			stopJobQueueScheduler := jobQueueSched.Start(ctx)
+			workerStops = append(workerStops, stopJobQueueScheduler)
+		}
+
+		return func() {
+			for i := len(workerStops) - 1; i >= 0; i-- {
+				workerStops[i]()
+			}
+		}
+	}
+	if role.runsWorkers() {
+		stopFuncs = append(stopFuncs, runWhileLeader(db, cfg.Scheduler.LeaderRetryInterval, startWorkers))
+	}
+
+	// Create application struct from FiberRouter
+	fiberRouter := appRouter.(*router.FiberRouter)
+	// Start metrics server (Prometheus) if enabled
+	if stop, err := startMetricsServer(cfg.Metrics, db, replicaDB); err == nil && stop != nil {
+		stopFuncs = append(stopFuncs, stop)
+	} else if err != nil {
+		log.Printf("failed to start metrics server: %v", err)
+	}
+
+	// Start the internal gRPC API the schedulers and the bot call
+	if cfg.GRPC.Enabled && role.servesAPI() {
+		grpcServer := grpcapi.NewServer(tokenService, grpcapi.Flows{
+			BotAuth:      botAuthFlow,
+			BotCampaign:  botCampaignFlow,
+			BotShortLink: botShortLinkFlow,
+			Payment:      paymentFlow,
+		})
+		stop, err := grpcapi.Start(grpcServer, fmt.Sprintf("%s:%d", cfg.GRPC.Host, cfg.GRPC.Port), cfg.Server.ShutdownTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("failed to start gRPC server: %w", err)
+		}
+		stopFuncs = append(stopFuncs, stop)
+	}
+
+	application := &Application{
+		Router:    fiberRouter,
+		Config:    cfg,
+		Server:    fiberRouter.GetApp(),
+		Health:    healthChecker,
+		Runtime:   runtimeConfigFlow,
+		StopFuncs: stopFuncs,
+	}
+
+	return application, nil
+}
+
+func ensureSystemAndTaxEntities(db *gorm.DB, cfg *config.ProductionConfig) error {
+	customerRepo := repository.NewCustomerRepository(db)
+	accountTypeRepo := repository.NewAccountTypeRepository(db)
+	walletRepo := repository.NewWalletRepository(db)
+
+	// Ensure system user
+	if cfg.System.SystemUserUUID != "" {
+		if err := ensureCustomerByUUID(
+			customerRepo,
+			accountTypeRepo,
+			cfg.System.SystemUserUUID,
+			models.AccountTypeMarketingAgency,
+			"System",
+			"Account",
+			cfg.System.SystemUserEmail,
+			cfg.System.SystemUserMobile,
+			"jazebeh.ir",
+			cfg.System.SystemShebaNumber,
+		); err != nil {
+			return err
+		}
+	}
+	// Ensure tax user
+	if cfg.System.TaxUserUUID != "" {
+		if err := ensureCustomerByUUID(
+			customerRepo,
+			accountTypeRepo,
+			cfg.System.TaxUserUUID,
+			models.AccountTypeIndividual,
+			"Tax",
+			"Collector",
+			cfg.System.TaxUserEmail,
+			cfg.System.TaxUserMobile,
+			"tax.jazebeh.ir",
+			"",
+		); err != nil {
+			return err
+		}
+	}
+
+	// Ensure system wallet
+	if cfg.System.SystemWalletUUID != "" && cfg.System.SystemUserUUID != "" {
+		if err := ensureWalletByUUID(
+			customerRepo,
+			walletRepo,
+			cfg.System.SystemWalletUUID,
+			cfg.System.SystemUserUUID,
+			map[string]any{"type": "system_wallet", "owner": "system", "source": "ensure_system_wallet"},
+		); err != nil {
+			return err
+		}
+	}
+
+	// Ensure tax wallet
+	if cfg.System.TaxWalletUUID != "" && cfg.System.TaxUserUUID != "" {
+		if err := ensureWalletByUUID(
+			customerRepo,
+			walletRepo,
+			cfg.System.TaxWalletUUID,
+			cfg.System.TaxUserUUID,
+			map[string]any{"type": "tax_wallet", "owner": "system", "source": "ensure_tax_wallet"},
+		); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func ensureCustomerByUUID(
+	customerRepo repository.CustomerRepository,
+	accountTypeRepo repository.AccountTypeRepository,
+	uuidStr, accountTypeName, firstName, lastName, email, mobile, agencyRefererCode, shebaNumber string,
+) error {
+	parsed, err := uuid.Parse(uuidStr)
+	if err != nil {
+		return err
+	}
+	customers, err := customerRepo.ByFilter(context.Background(), models.CustomerFilter{UUID: &parsed}, "", 1, 0)
+	if err != nil {
+		return err
+	}
+	if len(customers) > 0 {
+		return nil
+	}
+
+	accountType, err := accountTypeRepo.ByTypeName(context.Background(), accountTypeName)
+	if err != nil {
+		return err
+	}
+
+	// Create minimal customer
+	a := models.Customer{
+		UUID:                    parsed,
+		AgencyRefererCode:       agencyRefererCode,
+		ShebaNumber:             utils.ToPtr(shebaNumber),
+		AccountTypeID:           accountType.ID,
+		RepresentativeFirstName: firstName,
+		RepresentativeLastName:  lastName,
+		RepresentativeMobile:    mobile,
+		Email:                   email,
+		PasswordHash:            "", // not used
+		IsActive:                utils.ToPtr(true),
+		IsEmailVerified:         utils.ToPtr(false),
+		IsMobileVerified:        utils.ToPtr(false),
+		CreatedAt:               utils.UTCNow(),
+		UpdatedAt:               utils.UTCNow(),
+	}
+	err = customerRepo.Save(context.Background(), &a)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func ensureWalletByUUID(
+	customerRepo repository.CustomerRepository,
+	walletRepo repository.WalletRepository,
+	walletUUID, ownerUUID string,
+	metadata map[string]any) error {
+	// Lookup owner by UUID
+	ownerParsed, err := uuid.Parse(ownerUUID)
+	if err != nil {
+		return err
+	}
+	customers, err := customerRepo.ByFilter(context.Background(), models.CustomerFilter{UUID: &ownerParsed}, "", 1, 0)
+	if err != nil {
+		return err
+	}
+	if len(customers) == 0 {
+		return fmt.Errorf("owner not found")
+	}
+	owner := customers[0]
+	// Check wallet exists
+	w, err := walletRepo.ByUUID(context.Background(), walletUUID)
+	if err != nil {
+		return err
+	}
+	if w != nil {
+		return nil
+	}
+
+	metadataJSON, err := json.Marshal(metadata)
+	if err != nil {
+		return err
+	}
+	// Create wallet with initial snapshot
+	err = walletRepo.SaveWithInitialSnapshot(context.Background(), &models.Wallet{
+		UUID:       uuid.MustParse(walletUUID),
+		CustomerID: owner.ID,
+		Metadata:   metadataJSON,
+	})
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package bootstrap
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"gorm.io/gorm"
+)
+
+// workerLockName is hashed into the Postgres advisory lock the replica running
+// the background workers holds
+const workerLockName = "yamata_workers"
+
+var workerLeader = promauto.NewGauge(
+	prometheus.GaugeOpts{
+		Name: "worker_leader",
+		Help: "1 while this process holds the worker lock and runs the background workers",
+	},
+)
+
+// runWhileLeader runs the background workers on one replica at a time. It
+// holds a session-level advisory lock on a dedicated connection; replicas
+// that fail to take it retry every retry interval. The leader pings the same
+// connection at that interval and stops its workers when the ping fails, as
+// the lock went with the session. The returned func stops the workers and
+// releases the lock.
+func runWhileLeader(db *gorm.DB, retry time.Duration, start func(ctx context.Context) func()) func() {
+	parent, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(retry)
+		defer ticker.Stop()
+		for {
+			leadWhileLocked(parent, db, ticker.C, start)
+			select {
+			case <-parent.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// leadWhileLocked tries the lock once and, if it is taken, runs the workers
+// until the context ends or the lock is lost
+func leadWhileLocked(parent context.Context, db *gorm.DB, tick <-chan time.Time, start func(ctx context.Context) func()) {
+	sqlDB, err := db.DB()
+	if err != nil {
+		log.Printf("worker leader election: %v", err)
+		return
+	}
+	conn, err := sqlDB.Conn(parent)
+	if err != nil {
+		if parent.Err() == nil {
+			log.Printf("worker leader election: %v", err)
+		}
+		return
+	}
+	defer conn.Close()
+
+	var locked bool
+	if err := conn.QueryRowContext(parent, "SELECT pg_try_advisory_lock(hashtext($1))", workerLockName).Scan(&locked); err != nil {
+		if parent.Err() == nil {
+			log.Printf("worker leader election: %v", err)
+		}
+		return
+	}
+	if !locked {
+		return
+	}
+
+	log.Println("Acquired the worker lock, starting background workers")
+	workerLeader.Set(1)
+	ctx, cancel := context.WithCancel(parent)
+	stop := start(ctx)
+	defer func() {
+		cancel()
+		stop()
+		workerLeader.Set(0)
+		unlockCtx, unlockCancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer unlockCancel()
+		if _, err := conn.ExecContext(unlockCtx, "SELECT pg_advisory_unlock(hashtext($1))", workerLockName); err != nil {
+			log.Printf("worker leader election: release lock: %v", err)
+		}
+	}()
+
+	for {
+		select {
+		case <-parent.Done():
+			log.Println("Stopping background workers")
+			return
+		case <-tick:
+			pingCtx, pingCancel := context.WithTimeout(parent, 5*time.Second)
+			err := conn.PingContext(pingCtx)
+			pingCancel()
+			if err != nil && parent.Err() == nil {
+				log.Printf("Lost the worker lock, stopping background workers: %v", err)
+				return
+			}
+		}
+	}
+}
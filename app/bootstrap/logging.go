@@ -1,4 +1,4 @@
-package main
+package bootstrap
 
 import (
 	"io"
@@ -12,9 +12,9 @@ import (
 	"gopkg.in/natefinch/lumberjack.v2"
 )
 
-// setupLogging configures the global logger to write to a rotating file (and optionally stdout)
+// SetupLogging configures the global logger to write to a rotating file (and optionally stdout)
 // so logs survive container restarts when the path is backed by a volume.
-func setupLogging(cfg config.LoggingConfig) (io.Closer, error) {
+func SetupLogging(cfg config.LoggingConfig) (io.Closer, error) {
 	output := strings.ToLower(strings.TrimSpace(cfg.Output))
 	if output == "" {
 		output = "file"
@@ -0,0 +1,23 @@
+package bootstrap
+
+// Role selects which parts of the application a process runs
+type Role string
+
+const (
+	// RoleAll serves the API and runs the background workers, for single
+	// instance deployments
+	RoleAll Role = "all"
+	// RoleAPI serves the HTTP and gRPC APIs only
+	RoleAPI Role = "api"
+	// RoleWorker runs the campaign schedulers, their status checks and the
+	// other background workers only
+	RoleWorker Role = "worker"
+)
+
+func (r Role) servesAPI() bool {
+	return r == RoleAll || r == RoleAPI
+}
+
+func (r Role) runsWorkers() bool {
+	return r == RoleAll || r == RoleWorker
+}
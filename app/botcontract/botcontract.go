@@ -0,0 +1,144 @@
+// Package botcontract holds the recorded contract of the bot API endpoints
+// the campaign schedulers depend on: login, ready campaigns and short links.
+// Each fixture is one recorded interaction. The scheduler's bot client is
+// tested against Server, which answers with the recorded responses and
+// rejects requests that drift from the recorded ones; the bot handlers are
+// tested by checking their responses with Conforms. A change on either side
+// that renames, drops or retypes a field fails the other side's tests.
+package botcontract
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+//go:embed fixtures/*.json
+var fixtures embed.FS
+
+// Interaction is one recorded request to the bot API and its response
+type Interaction struct {
+	Name     string          `json:"name"`
+	Method   string          `json:"method"`
+	Path     string          `json:"path"`
+	Request  json.RawMessage `json:"request,omitempty"`
+	Status   int             `json:"status"`
+	Response json.RawMessage `json:"response"`
+}
+
+// Data returns the data field of the recorded response envelope
+func (i Interaction) Data() json.RawMessage {
+	var envelope struct {
+		Data json.RawMessage `json:"data"`
+	}
+	_ = json.Unmarshal(i.Response, &envelope)
+	return envelope.Data
+}
+
+// Interactions returns every recorded interaction, ordered by name
+func Interactions() []Interaction {
+	entries, err := fixtures.ReadDir("fixtures")
+	if err != nil {
+		panic(fmt.Sprintf("read bot contract fixtures: %v", err))
+	}
+	out := make([]Interaction, 0, len(entries))
+	for _, entry := range entries {
+		raw, err := fixtures.ReadFile(path.Join("fixtures", entry.Name()))
+		if err != nil {
+			panic(fmt.Sprintf("read bot contract fixture %s: %v", entry.Name(), err))
+		}
+		var i Interaction
+		if err := json.Unmarshal(raw, &i); err != nil {
+			panic(fmt.Sprintf("decode bot contract fixture %s: %v", entry.Name(), err))
+		}
+		out = append(out, i)
+	}
+	sort.Slice(out, func(a, b int) bool { return out[a].Name < out[b].Name })
+	return out
+}
+
+// Fixture returns the recorded interaction called name
+func Fixture(name string) Interaction {
+	for _, i := range Interactions() {
+		if i.Name == name {
+			return i
+		}
+	}
+	panic(fmt.Sprintf("no bot contract fixture %q", name))
+}
+
+// Conforms reports whether got has the shape of the recorded payload want:
+// every field of want is present in got with the same JSON type, at any
+// depth. Element n of an array in got must have the shape of recorded
+// element n, or of the last recorded element past the recorded ones. Fields
+// only got has are allowed, so either side can add fields without breaking
+// the contract; a recorded null matches any value.
+func Conforms(want, got []byte) error {
+	var w, g any
+	if err := json.Unmarshal(want, &w); err != nil {
+		return fmt.Errorf("decode recorded payload: %w", err)
+	}
+	if err := json.Unmarshal(got, &g); err != nil {
+		return fmt.Errorf("decode payload: %w", err)
+	}
+	var problems []string
+	conforms("$", w, g, &problems)
+	if len(problems) > 0 {
+		return fmt.Errorf("payload breaks the bot contract: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+func conforms(at string, want, got any, problems *[]string) {
+	if want == nil {
+		return
+	}
+	if kindOf(want) != kindOf(got) {
+		*problems = append(*problems, fmt.Sprintf("%s is %s, recorded %s", at, kindOf(got), kindOf(want)))
+		return
+	}
+	switch w := want.(type) {
+	case map[string]any:
+		g := got.(map[string]any)
+		keys := make([]string, 0, len(w))
+		for key := range w {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			value, ok := g[key]
+			if !ok {
+				*problems = append(*problems, fmt.Sprintf("%s.%s is missing", at, key))
+				continue
+			}
+			conforms(at+"."+key, w[key], value, problems)
+		}
+	case []any:
+		if len(w) == 0 {
+			return
+		}
+		for n, item := range got.([]any) {
+			conforms(fmt.Sprintf("%s[%d]", at, n), w[min(n, len(w)-1)], item, problems)
+		}
+	}
+}
+
+func kindOf(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	default:
+		return "object"
+	}
+}
@@ -0,0 +1,63 @@
+package botcontract
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Server is a bot API that answers with the recorded interactions. Requests
+// must carry the recorded login's access token, except the login itself, and
+// a request body must conform to the recorded one; a request that does not
+// is answered with 422 and the contract violation, so the client under test
+// fails with it.
+type Server struct {
+	interactions []Interaction
+	token        string
+}
+
+func NewServer() *Server {
+	var session struct {
+		Session struct {
+			AccessToken string `json:"access_token"`
+		} `json:"session"`
+	}
+	login := Fixture("login")
+	_ = json.Unmarshal(login.Data(), &session)
+	return &Server{interactions: Interactions(), token: session.Session.AccessToken}
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p := strings.TrimRight(r.URL.Path, "/")
+	for _, i := range s.interactions {
+		if i.Method != r.Method || i.Path != p {
+			continue
+		}
+		if i.Name != "login" && r.Header.Get("Authorization") != "Bearer "+s.token {
+			writeJSON(w, http.StatusUnauthorized, map[string]any{"success": false, "message": "invalid token"})
+			return
+		}
+		if len(i.Request) > 0 {
+			body, err := io.ReadAll(r.Body)
+			if err == nil {
+				err = Conforms(i.Request, body)
+			}
+			if err != nil {
+				writeJSON(w, http.StatusUnprocessableEntity, map[string]any{"success": false, "message": err.Error()})
+				return
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(i.Status)
+		_, _ = w.Write(i.Response)
+		return
+	}
+	writeJSON(w, http.StatusNotFound, map[string]any{"success": false, "message": "no recorded interaction"})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
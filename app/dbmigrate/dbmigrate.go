@@ -0,0 +1,205 @@
+// Package dbmigrate applies the versioned SQL migrations of the migrations
+// directory with golang-migrate and reports how far a database is from the
+// latest one. The applied version is kept in the schema_migrations table.
+// Databases created before the table existed are adopted with Baseline,
+// which records a version without running anything.
+package dbmigrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/postgres"
+	_ "github.com/lib/pq"
+)
+
+// Table holds the applied version and its dirty flag
+const Table = "schema_migrations"
+
+// ErrHistoryExists is returned by Baseline on a database that already has
+// a recorded version
+var ErrHistoryExists = errors.New("database already has a migration history")
+
+// Migrator runs migrations against one database
+type Migrator struct {
+	db         *sql.DB
+	migrate    *migrate.Migrate
+	migrations []Migration
+}
+
+// Open connects to the database at dsn and loads the migrations of fsys.
+// The connection is the migrator's own, since closing the migrator closes it.
+func Open(dsn string, fsys fs.FS) (*Migrator, error) {
+	migrations, err := Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("open database: %w", err)
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("ping database: %w", err)
+	}
+	driver, err := postgres.WithInstance(db, &postgres.Config{MigrationsTable: Table})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("prepare migration table: %w", err)
+	}
+	m, err := migrate.NewWithInstance("migrations", newFSSource(fsys, migrations), "postgres", driver)
+	if err != nil {
+		driver.Close()
+		return nil, fmt.Errorf("initialize migrations: %w", err)
+	}
+	return &Migrator{db: db, migrate: m, migrations: migrations}, nil
+}
+
+// Migrations returns the known migrations in order
+func (m *Migrator) Migrations() []Migration {
+	return m.migrations
+}
+
+// Up applies every pending migration
+func (m *Migrator) Up() error {
+	if err := m.migrate.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return err
+	}
+	return nil
+}
+
+// Down rolls back the last n applied migrations. A migration without a
+// down file is stepped over without changing the schema.
+func (m *Migrator) Down(n int) error {
+	if n <= 0 {
+		return fmt.Errorf("steps must be positive")
+	}
+	return m.migrate.Steps(-n)
+}
+
+// Status reports the applied version against the known migrations
+func (m *Migrator) Status(ctx context.Context) (Status, error) {
+	return Check(ctx, m.db, m.migrations)
+}
+
+// Baseline records migration name, or the latest one when name is empty,
+// as applied without running anything, for databases whose schema was
+// built before versions were tracked
+func (m *Migrator) Baseline(ctx context.Context, name string) (Migration, error) {
+	status, err := m.Status(ctx)
+	if err != nil {
+		return Migration{}, err
+	}
+	if status.Current != nil {
+		return Migration{}, ErrHistoryExists
+	}
+	target := m.migrations[len(m.migrations)-1]
+	if name != "" {
+		if target, err = m.find(name); err != nil {
+			return Migration{}, err
+		}
+	}
+	return target, m.migrate.Force(int(target.Version))
+}
+
+// Force records migration name as applied and clears the dirty flag, after
+// a failed migration has been repaired by hand
+func (m *Migrator) Force(name string) (Migration, error) {
+	target, err := m.find(name)
+	if err != nil {
+		return Migration{}, err
+	}
+	return target, m.migrate.Force(int(target.Version))
+}
+
+// find resolves a full migration name or an ordinal prefix such as 0172
+// that names exactly one migration
+func (m *Migrator) find(name string) (Migration, error) {
+	var found []Migration
+	for _, migration := range m.migrations {
+		if migration.Name == name {
+			return migration, nil
+		}
+		if strings.HasPrefix(migration.Name, name+"_") {
+			found = append(found, migration)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return Migration{}, fmt.Errorf("no migration %q", name)
+	case 1:
+		return found[0], nil
+	default:
+		return Migration{}, fmt.Errorf("%q names %d migrations; use the full name", name, len(found))
+	}
+}
+
+// Close releases the migrator and its database connection
+func (m *Migrator) Close() error {
+	sourceErr, dbErr := m.migrate.Close()
+	return errors.Join(sourceErr, dbErr)
+}
+
+// Status is the applied version of a database against the known migrations
+type Status struct {
+	// Current is the last applied migration, nil without a history
+	Current *Migration
+	Dirty   bool
+	// Unknown is set when the recorded version is not a known migration,
+	// which means the database is ahead of this build
+	Unknown uint
+	Pending []Migration
+}
+
+// Drift returns nil when the database is exactly at the latest migration
+// and a description of the difference otherwise
+func (s Status) Drift() error {
+	switch {
+	case s.Unknown != 0:
+		return fmt.Errorf("database is at version %d, which this build does not know; the build is older than the schema", s.Unknown)
+	case s.Current == nil:
+		return fmt.Errorf("database has no migration history; run migrate baseline on an existing schema or migrate up on an empty one")
+	case s.Dirty:
+		return fmt.Errorf("migration %s failed part way; repair it and run migrate force %s", s.Current.Name, s.Current.Name)
+	case len(s.Pending) > 0:
+		return fmt.Errorf("%d migrations pending, first %s", len(s.Pending), s.Pending[0].Name)
+	}
+	return nil
+}
+
+// Check reads the applied version from db without changing anything. It
+// does not take ownership of db, so it is safe on the application's pool.
+func Check(ctx context.Context, db *sql.DB, migrations []Migration) (Status, error) {
+	var (
+		status  Status
+		version int64
+		dirty   bool
+	)
+	err := db.QueryRowContext(ctx, `SELECT version, dirty FROM `+Table+` LIMIT 1`).Scan(&version, &dirty)
+	// lib/pq and pgx errors both carry the SQLSTATE
+	var stateErr interface{ SQLState() string }
+	switch {
+	case errors.As(err, &stateErr) && stateErr.SQLState() == "42P01", // undefined_table
+		errors.Is(err, sql.ErrNoRows):
+		status.Pending = migrations
+		return status, nil
+	case err != nil:
+		return status, fmt.Errorf("read migration version: %w", err)
+	}
+
+	status.Dirty = dirty
+	for i, migration := range migrations {
+		if int64(migration.Version) == version {
+			status.Current = &migrations[i]
+			status.Pending = migrations[i+1:]
+			return status, nil
+		}
+	}
+	status.Unknown = uint(version)
+	return status, nil
+}
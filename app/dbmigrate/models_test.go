@@ -0,0 +1,148 @@
+package dbmigrate_test
+
+import (
+	"context"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/dbmigrate"
+	"github.com/amirphl/Yamata-no-Orochi/migrations"
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	testingutil "github.com/amirphl/Yamata-no-Orochi/testing"
+	"gorm.io/gorm/schema"
+)
+
+func TestMain(m *testing.M) { os.Exit(testingutil.Main(m)) }
+
+// schemaModels are the models checked against the migrated schema; every
+// type in models with a TableName method must be listed
+var schemaModels = []any{
+	models.ACLChangeRequest{}, models.AccountType{}, models.Admin{}, models.AgencyDelegation{},
+	models.AgencyDiscount{}, models.AgencySharePayable{}, models.AtipayReconciliationEntry{}, models.AudienceColorTransition{}, models.SMSRecipientRetry{}, models.AudienceImportJob{},
+	models.AudienceProfile{}, models.AudienceSelection{}, models.AuditLog{}, models.BalanceSnapshot{},
+	models.BaleStatusResult{}, models.BlacklistedNumber{}, models.Bot{}, models.Bundle{},
+	models.BundleAudienceSelection{}, models.BundleTagEvaluationBatch{}, models.BundleTagEvaluationBatchAttempt{},
+	models.BundleTagEvaluationEvent{}, models.BundleTagEvaluationRun{}, models.BundleTagEvaluationRunStatus{},
+	models.BundleTagPersonaAnalysisAttempt{}, models.BundleTagScore{}, models.Campaign{},
+	models.CampaignDailyStat{}, models.CampaignReview{}, models.CampaignStatusJob{}, models.CampaignTemplate{},
+	models.CryptoWebhookEvent{}, models.CurrentBundleTagEvaluationStatus{}, models.CurrentBundleTagScore{},
+	models.Customer{}, models.CustomerCreditLine{}, models.CustomerDataRequest{}, models.CustomerKnownDevice{},
+	models.CustomerMonthlyUsage{}, models.CustomerProfileChange{}, models.CustomerSendingQuota{}, models.CustomerSession{}, models.Job{}, models.KYCDocument{},
+	models.LineNumber{}, models.LineNumberReservation{}, models.LineNumberTier{}, models.MoadianSubmission{},
+	models.MultimediaAsset{}, models.Notification{}, models.PagePrice{}, models.PartitionArchive{},
+	models.PlatformBasePrice{}, models.PlatformSettings{}, models.PostpaidDraw{}, models.PostpaidInvoice{},
+	models.ProcessedCampaign{}, models.PushDevice{}, models.ReportRollupState{}, models.RevenueDaily{},
+	models.RubikaStatusResult{}, models.SMSStatusResult{}, models.SMSTariff{}, models.SegmentPriceFactor{},
+	models.SentBaleMessage{}, models.SentRubikaMessage{}, models.SentSMS{}, models.SentSplusMessage{},
+	models.SequenceCounter{}, models.ShortLink{}, models.ShortLinkClick{}, models.SplusStatusResult{},
+	models.SrcLayerAllStats{}, models.Tag{}, models.TaxInvoice{}, models.TelegramLinkRequest{}, models.Ticket{},
+	models.WalletAdjustmentRequest{}, models.WalletTransfer{},
+}
+
+// TestSchemaModelsListsEveryTable keeps schemaModels in step with models
+func TestSchemaModelsListsEveryTable(t *testing.T) {
+	listed := make(map[string]bool, len(schemaModels))
+	for _, model := range schemaModels {
+		listed[reflect.TypeOf(model).Name()] = true
+	}
+
+	pkgs, err := parser.ParseDir(token.NewFileSet(), filepath.Join("..", "..", "models"), nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var missing []string
+	for _, pkg := range pkgs {
+		for _, f := range pkg.Files {
+			for _, decl := range f.Decls {
+				fn, ok := decl.(*ast.FuncDecl)
+				if !ok || fn.Name.Name != "TableName" || fn.Recv == nil {
+					continue
+				}
+				recv := fn.Recv.List[0].Type
+				if star, ok := recv.(*ast.StarExpr); ok {
+					recv = star.X
+				}
+				if ident, ok := recv.(*ast.Ident); ok && !listed[ident.Name] {
+					missing = append(missing, ident.Name)
+				}
+			}
+		}
+	}
+	sort.Strings(missing)
+	if len(missing) > 0 {
+		t.Fatalf("add these models to schemaModels: %v", missing)
+	}
+}
+
+// TestModelsMatchMigrations applies every migration to an empty database and
+// checks that each model's table and columns exist
+func TestModelsMatchMigrations(t *testing.T) {
+	if testingutil.TestDBMode() != testingutil.TestDBModeTestcontainers && os.Getenv("TEST_DB_HOST") == "" {
+		t.Skip("set TEST_DB_HOST or TEST_DB_MODE=testcontainers to check the models against a database")
+	}
+	testingutil.SkipWithoutDocker(t)
+
+	err := testingutil.TestWithDB(func(tdb *testingutil.TestDB) error {
+		sqlDB, err := tdb.DB.DB()
+		if err != nil {
+			return err
+		}
+		known, err := dbmigrate.Load(migrations.FS)
+		if err != nil {
+			return err
+		}
+		status, err := dbmigrate.Check(context.Background(), sqlDB, known)
+		if err != nil {
+			return err
+		}
+		if err := status.Drift(); err != nil {
+			t.Errorf("freshly migrated database drifts: %v", err)
+		}
+
+		cache := &sync.Map{}
+		for _, model := range schemaModels {
+			s, err := schema.Parse(model, cache, tdb.DB.NamingStrategy)
+			if err != nil {
+				t.Errorf("parse %T: %v", model, err)
+				continue
+			}
+			// pg_attribute covers tables, partitioned tables and views alike
+			var columns []string
+			err = tdb.DB.Raw(`SELECT a.attname FROM pg_attribute a
+				JOIN pg_class c ON c.oid = a.attrelid
+				JOIN pg_namespace n ON n.oid = c.relnamespace
+				WHERE n.nspname = current_schema() AND c.relname = ? AND a.attnum > 0 AND NOT a.attisdropped`,
+				s.Table).Scan(&columns).Error
+			if err != nil {
+				return err
+			}
+			if len(columns) == 0 {
+				t.Errorf("%s: table %s is not created by any migration", s.Name, s.Table)
+				continue
+			}
+			have := make(map[string]bool, len(columns))
+			for _, column := range columns {
+				have[column] = true
+			}
+			for _, name := range s.DBNames {
+				if field := s.FieldsByDBName[name]; field.IgnoreMigration {
+					continue
+				}
+				if !have[name] {
+					t.Errorf("%s: column %s.%s is not created by any migration", s.Name, s.Table, name)
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
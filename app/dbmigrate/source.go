@@ -0,0 +1,163 @@
+package dbmigrate
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/golang-migrate/migrate/v4/source"
+)
+
+// Migration is one numbered change of the schema history. The history has
+// two files for some ordinals, so the file name, not the ordinal, is the
+// identity of a migration; Version is ordinal*10 plus the position of the
+// file among those sharing its ordinal, which keeps versions unique and in
+// file order.
+type Migration struct {
+	Version uint
+	// Name is the up file name without .sql, e.g. 0172_add_sandbox_mode
+	Name string
+
+	up   string
+	down string // empty when the migration has no down file
+}
+
+// HasDown reports whether the migration can be rolled back
+func (m Migration) HasDown() bool {
+	return m.down != ""
+}
+
+// Load reads the migrations of fsys: NNNN_name.sql up files with optional
+// NNNN_name_down.sql down files. The run_all_ aggregate manifests are not
+// migrations and are skipped.
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("read migrations: %w", err)
+	}
+	files := make(map[string]bool, len(entries))
+	var ups []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".sql") || strings.HasPrefix(name, "run_all_") {
+			continue
+		}
+		files[name] = true
+		if !strings.HasSuffix(name, "_down.sql") {
+			ups = append(ups, name)
+		}
+	}
+	sort.Strings(ups)
+
+	migrations := make([]Migration, 0, len(ups))
+	var prevOrdinal uint64
+	position := 0
+	for _, up := range ups {
+		name := strings.TrimSuffix(up, ".sql")
+		prefix, _, ok := strings.Cut(name, "_")
+		ordinal, err := strconv.ParseUint(prefix, 10, 32)
+		if !ok || err != nil || ordinal == 0 {
+			return nil, fmt.Errorf("migration %s does not start with an ordinal", up)
+		}
+		if ordinal == prevOrdinal {
+			position++
+			if position > 9 {
+				return nil, fmt.Errorf("more than ten migrations share ordinal %04d", ordinal)
+			}
+		} else {
+			position = 0
+		}
+		prevOrdinal = ordinal
+
+		m := Migration{Version: uint(ordinal)*10 + uint(position), Name: name, up: up}
+		if down := name + "_down.sql"; files[down] {
+			m.down = down
+		}
+		migrations = append(migrations, m)
+	}
+	for name := range files {
+		if strings.HasSuffix(name, "_down.sql") && !files[strings.TrimSuffix(name, "_down.sql")+".sql"] {
+			return nil, fmt.Errorf("down migration %s has no up migration", name)
+		}
+	}
+	return migrations, nil
+}
+
+// fsSource serves loaded migrations to golang-migrate
+type fsSource struct {
+	fsys       fs.FS
+	migrations []Migration
+	index      map[uint]int // version -> position in migrations
+}
+
+var _ source.Driver = (*fsSource)(nil)
+
+func newFSSource(fsys fs.FS, migrations []Migration) *fsSource {
+	index := make(map[uint]int, len(migrations))
+	for i, m := range migrations {
+		index[m.Version] = i
+	}
+	return &fsSource{fsys: fsys, migrations: migrations, index: index}
+}
+
+func notExist(op string, version uint) error {
+	return &fs.PathError{Op: op, Path: strconv.FormatUint(uint64(version), 10), Err: fs.ErrNotExist}
+}
+
+func (s *fsSource) Open(url string) (source.Driver, error) {
+	return nil, fmt.Errorf("dbmigrate source is not opened by URL")
+}
+
+func (s *fsSource) Close() error {
+	return nil
+}
+
+func (s *fsSource) First() (uint, error) {
+	if len(s.migrations) == 0 {
+		return 0, notExist("first", 0)
+	}
+	return s.migrations[0].Version, nil
+}
+
+func (s *fsSource) Prev(version uint) (uint, error) {
+	i, ok := s.index[version]
+	if !ok || i == 0 {
+		return 0, notExist("prev", version)
+	}
+	return s.migrations[i-1].Version, nil
+}
+
+func (s *fsSource) Next(version uint) (uint, error) {
+	i, ok := s.index[version]
+	if !ok || i == len(s.migrations)-1 {
+		return 0, notExist("next", version)
+	}
+	return s.migrations[i+1].Version, nil
+}
+
+func (s *fsSource) ReadUp(version uint) (io.ReadCloser, string, error) {
+	i, ok := s.index[version]
+	if !ok {
+		return nil, "", notExist("read up", version)
+	}
+	f, err := s.fsys.Open(s.migrations[i].up)
+	if err != nil {
+		return nil, "", err
+	}
+	return f, s.migrations[i].Name, nil
+}
+
+func (s *fsSource) ReadDown(version uint) (io.ReadCloser, string, error) {
+	i, ok := s.index[version]
+	if !ok || !s.migrations[i].HasDown() {
+		return nil, "", notExist("read down", version)
+	}
+	f, err := s.fsys.Open(s.migrations[i].down)
+	if err != nil {
+		return nil, "", err
+	}
+	return f, s.migrations[i].Name + "_down", nil
+}
@@ -0,0 +1,137 @@
+package dbmigrate
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/amirphl/Yamata-no-Orochi/migrations"
+)
+
+func file(body string) *fstest.MapFile {
+	return &fstest.MapFile{Data: []byte(body)}
+}
+
+func TestLoadOrdersAndVersionsMigrations(t *testing.T) {
+	fsys := fstest.MapFS{
+		"0002_add_wallets.sql":           file("CREATE TABLE wallets ();"),
+		"0002_add_wallets_down.sql":      file("DROP TABLE wallets;"),
+		"0001_create_customers.sql":      file("CREATE TABLE customers ();"),
+		"0001_create_customers_down.sql": file("DROP TABLE customers;"),
+		"0002_add_company.sql":           file("INSERT INTO customers DEFAULT VALUES;"),
+		"0003_drop_index.sql":            file("DROP INDEX idx;"),
+		"run_all_up.sql":                 file(`\i 0001_create_customers.sql`),
+		"README.md":                      file("# migrations"),
+	}
+	got, err := Load(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		version uint
+		name    string
+		down    bool
+	}{
+		{10, "0001_create_customers", true},
+		{20, "0002_add_company", false},
+		{21, "0002_add_wallets", true},
+		{30, "0003_drop_index", false},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("loaded %d migrations, want %d: %+v", len(got), len(want), got)
+	}
+	for i, w := range want {
+		if got[i].Version != w.version || got[i].Name != w.name || got[i].HasDown() != w.down {
+			t.Errorf("migration %d = %d %s down=%t, want %d %s down=%t",
+				i, got[i].Version, got[i].Name, got[i].HasDown(), w.version, w.name, w.down)
+		}
+	}
+}
+
+func TestLoadRejectsOrphanDownAndUnnumberedFiles(t *testing.T) {
+	for name, fsys := range map[string]fstest.MapFS{
+		"orphan down": {"0001_a.sql": file(""), "0002_b_down.sql": file("")},
+		"no ordinal":  {"create_customers.sql": file("")},
+	} {
+		if _, err := Load(fsys); err == nil {
+			t.Errorf("%s: Load accepted the files", name)
+		}
+	}
+}
+
+func TestSourceWalksMigrations(t *testing.T) {
+	fsys := fstest.MapFS{
+		"0001_a.sql":      file("up a"),
+		"0001_a_down.sql": file("down a"),
+		"0002_b.sql":      file("up b"),
+	}
+	loaded, err := Load(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := newFSSource(fsys, loaded)
+
+	first, err := src.First()
+	if err != nil || first != 10 {
+		t.Fatalf("First = %d, %v", first, err)
+	}
+	next, err := src.Next(first)
+	if err != nil || next != 20 {
+		t.Fatalf("Next(10) = %d, %v", next, err)
+	}
+	if _, err := src.Next(next); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("Next past the last migration = %v", err)
+	}
+	if _, err := src.Prev(first); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("Prev before the first migration = %v", err)
+	}
+
+	r, ident, err := src.ReadDown(first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(r)
+	r.Close()
+	if string(body) != "down a" || ident != "0001_a_down" {
+		t.Fatalf("ReadDown(10) = %q %q", ident, body)
+	}
+	if _, _, err := src.ReadDown(next); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("ReadDown without a down file = %v", err)
+	}
+}
+
+func TestEmbeddedMigrationsLoad(t *testing.T) {
+	loaded, err := Load(migrations.FS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded) == 0 || loaded[0].Name != "0001_create_account_types" {
+		t.Fatalf("embedded migrations start with %+v", loaded[:min(1, len(loaded))])
+	}
+	for i := 1; i < len(loaded); i++ {
+		if loaded[i].Version <= loaded[i-1].Version {
+			t.Fatalf("%s does not sort after %s", loaded[i].Name, loaded[i-1].Name)
+		}
+	}
+}
+
+func TestStatusDrift(t *testing.T) {
+	known := []Migration{{Version: 10, Name: "0001_a"}, {Version: 20, Name: "0002_b"}}
+	cases := map[string]struct {
+		status Status
+		drift  bool
+	}{
+		"latest":     {Status{Current: &known[1]}, false},
+		"pending":    {Status{Current: &known[0], Pending: known[1:]}, true},
+		"no history": {Status{Pending: known}, true},
+		"dirty":      {Status{Current: &known[1], Dirty: true}, true},
+		"unknown":    {Status{Unknown: 30}, true},
+	}
+	for name, c := range cases {
+		if err := c.status.Drift(); (err != nil) != c.drift {
+			t.Errorf("%s: Drift() = %v", name, err)
+		}
+	}
+}
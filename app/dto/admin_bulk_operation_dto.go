@@ -0,0 +1,42 @@
+package dto
+
+// AdminBulkSetCustomersActiveStatusRequest activates or deactivates up to 100
+// customers; each is handled like a single active-status change
+type AdminBulkSetCustomersActiveStatusRequest struct {
+	CustomerIDs []uint `json:"customer_ids" validate:"required,min=1,max=100,dive,min=1"`
+	IsActive    bool   `json:"is_active"`
+}
+
+// AdminBulkReassignAgencyRequest moves up to 100 customers under another
+// referrer agency
+type AdminBulkReassignAgencyRequest struct {
+	CustomerIDs []uint  `json:"customer_ids" validate:"required,min=1,max=100,dive,min=1"`
+	AgencyID    uint    `json:"agency_id" validate:"required,min=1"`
+	Reason      *string `json:"reason,omitempty" validate:"omitempty,max=500"`
+}
+
+// AdminBulkResyncCryptoPaymentsRequest re-runs the provider callback handling
+// of up to 100 crypto payment requests
+type AdminBulkResyncCryptoPaymentsRequest struct {
+	UUIDs []string `json:"uuids" validate:"required,min=1,max=100,dive,uuid"`
+}
+
+// AdminBulkItemResult is the outcome of the item at Index of the request
+type AdminBulkItemResult struct {
+	Index     int    `json:"index"`
+	ID        string `json:"id"` // customer ID or crypto payment request UUID
+	Success   bool   `json:"success"`
+	Changed   bool   `json:"changed"`          // false when the item already was in the requested state
+	Status    string `json:"status,omitempty"` // resulting crypto payment status
+	ErrorCode string `json:"error_code,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// AdminBulkOperationResponse reports every item of a bulk request in request
+// order
+type AdminBulkOperationResponse struct {
+	Message   string                `json:"message"`
+	Succeeded int                   `json:"succeeded"`
+	Failed    int                   `json:"failed"`
+	Results   []AdminBulkItemResult `json:"results"`
+}
@@ -41,30 +41,37 @@ type AdminCustomersSharesResponse struct {
 
 // AdminCustomerDetailDTO contains full customer info for admin
 type AdminCustomerDetailDTO struct {
-	ID                      uint       `json:"id"`
-	UUID                    string     `json:"uuid"`
-	AgencyRefererCode       string     `json:"agency_referer_code"`
-	AccountTypeID           uint       `json:"account_type_id"`
-	AccountTypeName         string     `json:"account_type_name"`
-	CompanyName             *string    `json:"company_name,omitempty"`
-	NationalID              *string    `json:"national_id,omitempty"`
-	CompanyPhone            *string    `json:"company_phone,omitempty"`
-	CompanyAddress          *string    `json:"company_address,omitempty"`
-	PostalCode              *string    `json:"postal_code,omitempty"`
-	RepresentativeFirstName string     `json:"representative_first_name"`
-	RepresentativeLastName  string     `json:"representative_last_name"`
-	RepresentativeMobile    string     `json:"representative_mobile"`
-	Email                   string     `json:"email"`
-	ShebaNumber             *string    `json:"sheba_number,omitempty"`
-	ReferrerAgencyID        *uint      `json:"referrer_agency_id,omitempty"`
-	IsEmailVerified         *bool      `json:"is_email_verified,omitempty"`
-	IsMobileVerified        *bool      `json:"is_mobile_verified,omitempty"`
-	IsActive                *bool      `json:"is_active,omitempty"`
-	CreatedAt               time.Time  `json:"created_at"`
-	UpdatedAt               time.Time  `json:"updated_at,omitempty"`
-	EmailVerifiedAt         *time.Time `json:"email_verified_at,omitempty"`
-	MobileVerifiedAt        *time.Time `json:"mobile_verified_at,omitempty"`
-	LastLoginAt             *time.Time `json:"last_login_at,omitempty"`
+	ID                      uint    `json:"id"`
+	UUID                    string  `json:"uuid"`
+	AgencyRefererCode       string  `json:"agency_referer_code"`
+	AccountTypeID           uint    `json:"account_type_id"`
+	AccountTypeName         string  `json:"account_type_name"`
+	CompanyName             *string `json:"company_name,omitempty"`
+	NationalID              *string `json:"national_id,omitempty"`
+	CompanyPhone            *string `json:"company_phone,omitempty"`
+	CompanyAddress          *string `json:"company_address,omitempty"`
+	PostalCode              *string `json:"postal_code,omitempty"`
+	RepresentativeFirstName string  `json:"representative_first_name"`
+	RepresentativeLastName  string  `json:"representative_last_name"`
+	RepresentativeMobile    string  `json:"representative_mobile"`
+	Email                   string  `json:"email"`
+	ShebaNumber             *string `json:"sheba_number,omitempty"`
+	ReferrerAgencyID        *uint   `json:"referrer_agency_id,omitempty"`
+	IsEmailVerified         *bool   `json:"is_email_verified,omitempty"`
+	IsMobileVerified        *bool   `json:"is_mobile_verified,omitempty"`
+	IsActive                *bool   `json:"is_active,omitempty"`
+	IsSandbox               bool    `json:"is_sandbox"`
+	// Suspension is set while the customer is suspended; SuspensionNote is
+	// the admin's internal note
+	Suspension       *CustomerSuspensionDTO `json:"suspension,omitempty"`
+	SuspensionNote   *string                `json:"suspension_note,omitempty"`
+	CreatedAt        time.Time              `json:"created_at"`
+	UpdatedAt        time.Time              `json:"updated_at,omitempty"`
+	EmailVerifiedAt  *time.Time             `json:"email_verified_at,omitempty"`
+	MobileVerifiedAt *time.Time             `json:"mobile_verified_at,omitempty"`
+	LastLoginAt      *time.Time             `json:"last_login_at,omitempty"`
+	// PasswordHashAlgorithm is argon2id, bcrypt (not upgraded since), or unknown
+	PasswordHashAlgorithm string `json:"password_hash_algorithm"`
 }
 
 // AdminCustomerCampaignItem summarizes a campaign for admin list
@@ -141,9 +148,153 @@ type AdminSetCustomerActiveStatusResponse struct {
 	IsActive bool   `json:"is_active"`
 }
 
+// AdminSetCustomerSuspensionRequest suspends a customer at Level for the
+// Reason code, or lifts the suspension when Level is empty
+type AdminSetCustomerSuspensionRequest struct {
+	CustomerID uint    `json:"-"`
+	Level      string  `json:"level" validate:"omitempty,oneof=warning restricted suspended"`
+	Reason     string  `json:"reason" validate:"required_with=Level,omitempty,oneof=policy_violation spam_complaints payment_dispute fraud_review verification_required"`
+	Note       *string `json:"note,omitempty" validate:"omitempty,max=500"` // internal, never shown to the customer
+}
+
+// AdminSetCustomerSuspensionResponse reports the resulting suspension; it is
+// nil once lifted
+type AdminSetCustomerSuspensionResponse struct {
+	Message    string                 `json:"message"`
+	CustomerID uint                   `json:"customer_id"`
+	Suspension *CustomerSuspensionDTO `json:"suspension,omitempty"`
+}
+
+// AdminForceLogoutCustomerResponse is the response for ending all sessions of a customer.
+type AdminForceLogoutCustomerResponse struct {
+	Message       string `json:"message"`
+	EndedSessions int    `json:"ended_sessions"`
+}
+
+// AdminImpersonateCustomerResponse carries a short-lived customer access
+// token for an admin acting as the customer.
+type AdminImpersonateCustomerResponse struct {
+	Message        string `json:"message"`
+	CustomerID     uint   `json:"customer_id"`
+	ImpersonatedBy uint   `json:"impersonated_by"`
+	AccessToken    string `json:"access_token"`
+	TokenType      string `json:"token_type" example:"Bearer"`
+	ExpiresIn      int    `json:"expires_in" example:"1800"`
+	ExpiresAt      string `json:"expires_at"`
+}
+
 // AdminListCustomersResponse is the response for listing customers by admin.
+// AdminListCustomersRequest filters and sorts the customer list
+type AdminListCustomersRequest struct {
+	// Filter and Sort are list query expressions over the customer list fields
+	Filter string `json:"filter,omitempty" validate:"omitempty,max=2000"`
+	Sort   string `json:"sort,omitempty" validate:"omitempty,max=200"`
+}
+
 type AdminListCustomersResponse struct {
 	Message string                   `json:"message"`
 	Items   []AdminCustomerDetailDTO `json:"items"`
 	Total   uint64                   `json:"total"`
 }
+
+// AdminSearchCustomersRequest searches customers by name, company, email,
+// mobile, national ID or UUID
+type AdminSearchCustomersRequest struct {
+	Query string `json:"q" validate:"required,min=3,max=100"`
+	Page  int    `json:"page" validate:"min=1"`
+	Limit int    `json:"limit" validate:"min=1,max=100"`
+}
+
+// AdminCustomerSearchItem is a customer matching a search. Rank is higher for
+// better matches; exact email, mobile, national ID and UUID matches are above 2.
+type AdminCustomerSearchItem struct {
+	AdminCustomerDetailDTO
+	Rank float64 `json:"rank"`
+}
+
+// AdminSearchCustomersResponse is a page of search results, best match first
+type AdminSearchCustomersResponse struct {
+	Message    string                    `json:"message"`
+	Items      []AdminCustomerSearchItem `json:"items"`
+	Pagination PaginationInfo            `json:"pagination"`
+}
+
+// SendingQuotaUsage reports a customer's sending quota and its consumption in
+// the current Tehran day and month. Nil limits and remainders mean unlimited.
+// Reserved counts the audience of campaigns awaiting approval or sending.
+// FrequencyCapHours is the SMS frequency cap window; nil means no cap.
+type SendingQuotaUsage struct {
+	DailyLimit        *uint64 `json:"daily_limit"`
+	MonthlyLimit      *uint64 `json:"monthly_limit"`
+	FrequencyCapHours *uint   `json:"frequency_cap_hours"`
+	DailySent         uint64  `json:"daily_sent"`
+	MonthlySent       uint64  `json:"monthly_sent"`
+	DailyReserved     uint64  `json:"daily_reserved"`
+	MonthlyReserved   uint64  `json:"monthly_reserved"`
+	DailyRemaining    *uint64 `json:"daily_remaining"`
+	MonthlyRemaining  *uint64 `json:"monthly_remaining"`
+	DayStart          string  `json:"day_start"`
+	MonthStart        string  `json:"month_start"`
+}
+
+// AdminSetCustomerSendingQuotaRequest sets a customer's sending limits. Omit
+// or null a limit to make it unlimited. FrequencyCapHours, between 1 and 720,
+// keeps the customer's SMS campaigns from messaging a number another of its SMS
+// campaigns messaged within that many hours; omit or null it to disable.
+type AdminSetCustomerSendingQuotaRequest struct {
+	CustomerID        uint    `json:"-"`
+	DailyLimit        *uint64 `json:"daily_limit,omitempty"`
+	MonthlyLimit      *uint64 `json:"monthly_limit,omitempty"`
+	FrequencyCapHours *uint   `json:"frequency_cap_hours,omitempty"`
+}
+
+// AdminCustomerSendingQuotaResponse is a customer's sending quota as seen by admins
+type AdminCustomerSendingQuotaResponse struct {
+	Message    string            `json:"message"`
+	CustomerID uint              `json:"customer_id"`
+	Usage      SendingQuotaUsage `json:"usage"`
+	UpdatedAt  *string           `json:"updated_at,omitempty"`
+}
+
+// GetSendingQuotaUsageResponse is the customer's own sending quota usage
+type GetSendingQuotaUsageResponse struct {
+	Message string            `json:"message"`
+	Usage   SendingQuotaUsage `json:"usage"`
+}
+
+// AdminCustomerTimelineRequest selects a page of a customer's activity timeline
+type AdminCustomerTimelineRequest struct {
+	CustomerID uint `json:"-"`
+	// Categories limits the timeline to audit, session, payment and campaign
+	// activities; empty means all of them
+	Categories []string `json:"categories,omitempty"`
+	// Cursor is the next_cursor of the previous page
+	Cursor string `json:"cursor,omitempty"`
+	Limit  int    `json:"limit,omitempty"`
+}
+
+// CustomerTimelineEvent is one entry of a customer's activity timeline
+type CustomerTimelineEvent struct {
+	// Category is one of audit, session, payment and campaign
+	Category string `json:"category"`
+	// Type is the audit action, session_started or session_ended, or the
+	// payment or campaign status prefixed with payment_ or campaign_
+	Type string `json:"type"`
+	// SourceID is the ID of the audit log, session, payment request or
+	// campaign the event was read from
+	SourceID   uint           `json:"source_id"`
+	OccurredAt string         `json:"occurred_at"`
+	Summary    string         `json:"summary"`
+	Success    *bool          `json:"success,omitempty"`
+	Details    map[string]any `json:"details,omitempty"`
+}
+
+// AdminCustomerTimelineResponse is a page of a customer's activity, newest first
+type AdminCustomerTimelineResponse struct {
+	Message    string                  `json:"message"`
+	CustomerID uint                    `json:"customer_id"`
+	Events     []CustomerTimelineEvent `json:"events"`
+	// NextCursor continues the timeline; empty on the last page
+	NextCursor string `json:"next_cursor,omitempty"`
+	HasMore    bool   `json:"has_more"`
+}
@@ -42,6 +42,19 @@ type AdminLoginInitResponse struct {
 	RequiresTwoFactor bool             `json:"requires_two_factor"`
 	Admin             *AdminDTO        `json:"admin,omitempty"`
 	Session           *AdminSessionDTO `json:"session,omitempty"`
+	// TwoFactorMethod is what the code of the challenge comes from: sms,
+	// totp, or totp_setup for an authenticator app being enrolled
+	TwoFactorMethod string             `json:"two_factor_method,omitempty" example:"totp"`
+	TOTPSetup       *AdminTOTPSetupDTO `json:"totp_setup,omitempty"`
+}
+
+// AdminTOTPSetupDTO is a new authenticator app secret; the admin confirms it
+// by verifying a code from the app with the setup challenge
+type AdminTOTPSetupDTO struct {
+	ChallengeID     string    `json:"challenge_id"`
+	Secret          string    `json:"secret" example:"JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"`
+	ProvisioningURI string    `json:"provisioning_uri" example:"otpauth://totp/Yamata%20no%20Orochi:admin?secret=JBSWY3DPEHPK3PXP&issuer=Yamata+no+Orochi"`
+	ExpiresAt       time.Time `json:"expires_at"`
 }
 
 type AdminLoginVerifyOTPRequest struct {
@@ -52,6 +65,9 @@ type AdminLoginVerifyOTPRequest struct {
 type AdminLoginResponse struct {
 	Admin   AdminDTO        `json:"admin"`
 	Session AdminSessionDTO `json:"session"`
+	// TOTPSetup is set instead of a session when the verified code was an
+	// SMS OTP of an admin without an authenticator app
+	TOTPSetup *AdminTOTPSetupDTO `json:"totp_setup,omitempty"`
 }
 
 // Admin short link CSV creation response
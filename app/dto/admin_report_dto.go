@@ -0,0 +1,98 @@
+package dto
+
+// AdminFinancialReportRequest selects the periods of the financial report
+type AdminFinancialReportRequest struct {
+	Granularity string  `json:"granularity" validate:"omitempty,oneof=daily weekly"`
+	StartDate   *string `json:"start_date,omitempty"`
+	EndDate     *string `json:"end_date,omitempty"`
+}
+
+// AdminFinancialReportTotals are the platform's money flows over a range
+type AdminFinancialReportTotals struct {
+	Charges            int64  `json:"charges"`
+	RevenueWithTax     uint64 `json:"revenue_with_tax"`
+	TaxCollected       uint64 `json:"tax_collected"`
+	SystemShare        uint64 `json:"system_share"`
+	AgencyShareWithTax uint64 `json:"agency_share_with_tax"`
+	CampaignSpend      uint64 `json:"campaign_spend"`
+	CampaignRefunds    uint64 `json:"campaign_refunds"`
+	NetCampaignSpend   uint64 `json:"net_campaign_spend"`
+}
+
+// AdminFinancialReportPeriod is one Tehran day or week of the financial report
+type AdminFinancialReportPeriod struct {
+	Period string `json:"period"` // YYYY-MM-DD the period starts on
+	AdminFinancialReportTotals
+}
+
+// AdminFinancialReportResponse is the API response for the financial report
+type AdminFinancialReportResponse struct {
+	Message     string                       `json:"message"`
+	Granularity string                       `json:"granularity"`
+	StartDate   string                       `json:"start_date"`
+	EndDate     string                       `json:"end_date"`
+	Totals      AdminFinancialReportTotals   `json:"totals"`
+	Periods     []AdminFinancialReportPeriod `json:"periods"`
+	// RefreshedAt is when the revenue_daily rollup the report was read from
+	// was last refreshed; it is omitted when the report was computed live
+	RefreshedAt *string `json:"refreshed_at,omitempty"`
+}
+
+// AdminTopCustomersReportRequest selects the range and size of the top customers report
+type AdminTopCustomersReportRequest struct {
+	StartDate *string `json:"start_date,omitempty"`
+	EndDate   *string `json:"end_date,omitempty"`
+	Limit     int     `json:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+// AdminTopCustomerItem is a customer of the top customers report
+type AdminTopCustomerItem struct {
+	CustomerID              uint   `json:"customer_id"`
+	RepresentativeFirstName string `json:"representative_first_name"`
+	RepresentativeLastName  string `json:"representative_last_name"`
+	CompanyName             string `json:"company_name"`
+	Charges                 int64  `json:"charges"`
+	RevenueWithTax          uint64 `json:"revenue_with_tax"`
+	CampaignSpend           uint64 `json:"campaign_spend"`
+	CampaignRefunds         uint64 `json:"campaign_refunds"`
+	NetCampaignSpend        uint64 `json:"net_campaign_spend"`
+}
+
+// AdminTopCustomersReportResponse is the API response for the top customers report
+type AdminTopCustomersReportResponse struct {
+	Message   string                 `json:"message"`
+	StartDate string                 `json:"start_date"`
+	EndDate   string                 `json:"end_date"`
+	Items     []AdminTopCustomerItem `json:"items"`
+}
+
+// AdminWalletLiabilityResponse sums the current balances of customer and
+// agency wallets, i.e. what the platform owes them
+type AdminWalletLiabilityResponse struct {
+	Message            string `json:"message"`
+	Wallets            int64  `json:"wallets"`
+	FreeBalance        uint64 `json:"free_balance"`
+	FrozenBalance      uint64 `json:"frozen_balance"`
+	LockedBalance      uint64 `json:"locked_balance"`
+	CreditBalance      uint64 `json:"credit_balance"`
+	AgencyShareWithTax uint64 `json:"agency_share_with_tax"`
+	TotalLiability     uint64 `json:"total_liability"`
+	SpentOnCampaign    uint64 `json:"spent_on_campaign"`
+}
+
+// AdminReportRollupRefreshRequest re-rolls the reporting rollups for the
+// Tehran days from..to, both inclusive
+type AdminReportRollupRefreshRequest struct {
+	From string `json:"from" validate:"required,datetime=2006-01-02"`
+	To   string `json:"to" validate:"required,datetime=2006-01-02"`
+}
+
+// ReportRollupRefreshSummary reports a refresh of the reporting rollups
+type ReportRollupRefreshSummary struct {
+	From             string `json:"from"`
+	To               string `json:"to"`
+	Days             int    `json:"days"`
+	Months           int    `json:"months"`
+	RefreshedThrough string `json:"refreshed_through,omitempty"`
+	Skipped          bool   `json:"skipped,omitempty"` // another instance was refreshing
+}
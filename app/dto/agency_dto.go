@@ -71,16 +71,52 @@ type ListAgencyCustomerDiscountsResponse struct {
 	Items   []AgencyCustomerDiscountItem `json:"items"`
 }
 
+// AgencyDiscountTier raises a discount to DiscountRate for the charges of a
+// month once the customer has charged MinMonthlyAmountWithTax in it
+type AgencyDiscountTier struct {
+	MinMonthlyAmountWithTax uint64  `json:"min_monthly_amount_with_tax" validate:"required,min=1"`
+	DiscountRate            float64 `json:"discount_rate" validate:"gte=0,lte=0.5"`
+}
+
 type CreateAgencyDiscountRequest struct {
 	AgencyID     uint    `json:"-"`
 	CustomerID   uint    `json:"customer_id" validate:"required,min=1"`
 	Name         string  `json:"name" validate:"required,min=1,max=255"`
 	DiscountRate float64 `json:"discount_rate" validate:"gte=0,lte=0.5"`
+	// Tiers are sorted by threshold and never lower the rate
+	Tiers []AgencyDiscountTier `json:"tiers,omitempty" validate:"omitempty,max=10,dive"`
+	// EffectiveFrom schedules the discount to replace the current one later
+	// (RFC3339); it applies right away when omitted
+	EffectiveFrom *string `json:"effective_from,omitempty" validate:"omitempty"`
 }
 
 type CreateAgencyDiscountResponse struct {
-	Message      string  `json:"message"`
-	DiscountRate float64 `json:"discount_rate"`
+	Message       string               `json:"message"`
+	DiscountRate  float64              `json:"discount_rate"`
+	Tiers         []AgencyDiscountTier `json:"tiers"`
+	EffectiveFrom time.Time            `json:"effective_from"`
+}
+
+type ListAgencyDiscountScheduleRequest struct {
+	AgencyID   uint `json:"-"`
+	CustomerID uint `json:"-"`
+}
+
+// AgencyDiscountScheduleItem is a discount of a customer, past, current or
+// scheduled. Status is scheduled, active or expired.
+type AgencyDiscountScheduleItem struct {
+	UUID          string               `json:"uuid"`
+	DiscountRate  float64              `json:"discount_rate"`
+	Tiers         []AgencyDiscountTier `json:"tiers"`
+	Status        string               `json:"status"`
+	EffectiveFrom time.Time            `json:"effective_from"`
+	ExpiresAt     *time.Time           `json:"expires_at,omitempty"`
+	CreatedAt     time.Time            `json:"created_at"`
+}
+
+type ListAgencyDiscountScheduleResponse struct {
+	Message string                       `json:"message"`
+	Items   []AgencyDiscountScheduleItem `json:"items"`
 }
 
 type ListAgencyCustomersRequest struct {
@@ -100,3 +136,114 @@ type ListAgencyCustomersResponse struct {
 	Message string               `json:"message"`
 	Items   []AgencyCustomerItem `json:"items"`
 }
+
+// AgencyCommissionReportRequest selects the date range of the commission
+// dashboard and its CSV exports
+type AgencyCommissionReportRequest struct {
+	AgencyID  uint    `json:"-"`
+	StartDate *string `json:"start_date,omitempty"` // RFC3339
+	EndDate   *string `json:"end_date,omitempty"`   // RFC3339
+	// Report selects the CSV export: customers, monthly or campaigns
+	Report string `json:"report,omitempty" validate:"omitempty,oneof=customers monthly campaigns"`
+}
+
+// AgencyCommissionSummary totals an agency's commission. Revenue, accrued
+// and settled cover the requested range; pending is everything accrued and
+// not yet paid out.
+type AgencyCommissionSummary struct {
+	RevenueWithTax uint64 `json:"revenue_with_tax"`
+	Accrued        uint64 `json:"accrued"`
+	Settled        uint64 `json:"settled"`
+	Pending        uint64 `json:"pending"`
+}
+
+type AgencyCommissionCustomerItem struct {
+	CustomerID              uint   `json:"customer_id"`
+	RepresentativeFirstName string `json:"representative_first_name"`
+	RepresentativeLastName  string `json:"representative_last_name"`
+	CompanyName             string `json:"company_name"`
+	Charges                 int64  `json:"charges"`
+	RevenueWithTax          uint64 `json:"revenue_with_tax"`
+	CommissionWithTax       uint64 `json:"commission_with_tax"`
+}
+
+type AgencyCommissionMonthItem struct {
+	Month   string `json:"month"` // YYYY-MM, Tehran time
+	Accrued uint64 `json:"accrued"`
+	Settled uint64 `json:"settled"`
+}
+
+type AgencyTopCampaignItem struct {
+	UUID                    string    `json:"uuid"`
+	Title                   string    `json:"title"`
+	Status                  string    `json:"status"`
+	CustomerID              uint      `json:"customer_id"`
+	RepresentativeFirstName string    `json:"representative_first_name"`
+	RepresentativeLastName  string    `json:"representative_last_name"`
+	CompanyName             string    `json:"company_name"`
+	TotalSent               uint64    `json:"total_sent"`
+	CreatedAt               time.Time `json:"created_at"`
+}
+
+type AgencyCommissionDashboardResponse struct {
+	Message      string                         `json:"message"`
+	Summary      AgencyCommissionSummary        `json:"summary"`
+	Customers    []AgencyCommissionCustomerItem `json:"customers"`
+	Months       []AgencyCommissionMonthItem    `json:"months"`
+	TopCampaigns []AgencyTopCampaignItem        `json:"top_campaigns"`
+}
+
+// AgencyCommissionExport is a CSV export of one commission report
+type AgencyCommissionExport struct {
+	Filename string
+	Content  []byte
+}
+
+// GrantAgencyDelegationRequest lets the customer's referrer agency act for
+// them with the given permissions, replacing the permissions of an active
+// grant
+type GrantAgencyDelegationRequest struct {
+	CustomerID  uint     `json:"-"`
+	Permissions []string `json:"permissions" validate:"required,min=1,dive,oneof=create_campaigns manage_campaigns"`
+}
+
+type RevokeAgencyDelegationRequest struct {
+	CustomerID uint `json:"-"`
+}
+
+type GetAgencyDelegationRequest struct {
+	CustomerID uint `json:"-"`
+}
+
+type AgencyDelegationItem struct {
+	UUID        string    `json:"uuid"`
+	AgencyID    uint      `json:"agency_id"`
+	Permissions []string  `json:"permissions"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type AgencyDelegationResponse struct {
+	Message    string                `json:"message"`
+	Delegation *AgencyDelegationItem `json:"delegation"`
+}
+
+type ListAgencyDelegationsRequest struct {
+	AgencyID uint `json:"-"`
+}
+
+type AgencyDelegatingCustomerItem struct {
+	UUID                    string    `json:"uuid"`
+	CustomerID              uint      `json:"customer_id"`
+	CustomerUUID            string    `json:"customer_uuid"`
+	RepresentativeFirstName string    `json:"representative_first_name"`
+	RepresentativeLastName  string    `json:"representative_last_name"`
+	CompanyName             *string   `json:"company_name,omitempty"`
+	Permissions             []string  `json:"permissions"`
+	CreatedAt               time.Time `json:"created_at"`
+}
+
+type ListAgencyDelegationsResponse struct {
+	Message string                         `json:"message"`
+	Items   []AgencyDelegatingCustomerItem `json:"items"`
+}
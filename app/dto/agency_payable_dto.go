@@ -0,0 +1,45 @@
+package dto
+
+// AgencyPayableItem is an agency share settled to the system Sheba number
+// that the platform owes the agency
+type AgencyPayableItem struct {
+	UUID             string  `json:"uuid"`
+	AgencyID         uint    `json:"agency_id"`
+	CustomerID       uint    `json:"customer_id"`
+	PaymentRequestID uint    `json:"payment_request_id"`
+	AmountWithTax    uint64  `json:"amount_with_tax"` // toman
+	Reason           string  `json:"reason"`
+	Status           string  `json:"status"`
+	PaidAt           *string `json:"paid_at,omitempty"`
+	PaymentReference string  `json:"payment_reference,omitempty"`
+	CreatedAt        string  `json:"created_at"`
+}
+
+// AdminListAgencyPayablesFilter represents query params of the payable list
+type AdminListAgencyPayablesFilter struct {
+	AgencyID *uint   `json:"agency_id,omitempty"`
+	Status   *string `json:"status,omitempty" validate:"omitempty,oneof=pending paid"`
+	Page     int     `json:"page" validate:"min=1"`
+	Limit    int     `json:"limit" validate:"min=1,max=100"`
+}
+
+// AdminListAgencyPayablesResponse lists agency payables. PendingAmount is
+// what is still owed over every page of the filter.
+type AdminListAgencyPayablesResponse struct {
+	Message       string              `json:"message"`
+	Items         []AgencyPayableItem `json:"items"`
+	PendingAmount uint64              `json:"pending_amount_with_tax"`
+	Pagination    PaginationInfo      `json:"pagination"`
+}
+
+// AdminMarkAgencyPayablePaidRequest records the payout of an agency share,
+// e.g. a bank transfer
+type AdminMarkAgencyPayablePaidRequest struct {
+	PaymentReference string `json:"payment_reference" validate:"required,min=3,max=255"`
+}
+
+// AdminAgencyPayableResponse returns a payable after an admin action
+type AdminAgencyPayableResponse struct {
+	Message string            `json:"message"`
+	Payable AgencyPayableItem `json:"payable"`
+}
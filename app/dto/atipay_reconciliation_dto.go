@@ -0,0 +1,63 @@
+package dto
+
+// AtipayReconciliationSummary counts the outcome of reconciling one day's
+// Atipay settlement report
+type AtipayReconciliationSummary struct {
+	ReportDate      string `json:"report_date"` // YYYY-MM-DD, Tehran calendar day
+	Source          string `json:"source"`      // upload|pull
+	ReportRows      int    `json:"report_rows"`
+	Matched         int    `json:"matched"`
+	AmountMismatch  int    `json:"amount_mismatch"`
+	StatusMismatch  int    `json:"status_mismatch"`
+	MissingInSystem int    `json:"missing_in_system"`
+	MissingInReport int    `json:"missing_in_report"`
+	Duplicate       int    `json:"duplicate"`
+	Discrepancies   int    `json:"discrepancies"`
+}
+
+// AtipayReconciliationRowError describes a report row that could not be read.
+// Row is the 1-based data row number, not counting the header.
+type AtipayReconciliationRowError struct {
+	Row   int    `json:"row"`
+	Error string `json:"error"`
+}
+
+// AdminAtipayReconciliationUploadResponse is the result of reconciling an
+// uploaded settlement report
+type AdminAtipayReconciliationUploadResponse struct {
+	Message   string                         `json:"message"`
+	Summary   AtipayReconciliationSummary    `json:"summary"`
+	RowErrors []AtipayReconciliationRowError `json:"row_errors"`
+}
+
+// AdminAtipayReconciliationFilter represents query params of the discrepancy report
+type AdminAtipayReconciliationFilter struct {
+	ReportDate string  `json:"report_date" validate:"required,datetime=2006-01-02"`
+	Status     *string `json:"status,omitempty" validate:"omitempty,oneof=matched amount_mismatch status_mismatch missing_in_system missing_in_report duplicate"`
+	// IncludeMatched lists matched entries too; by default only discrepancies are listed
+	IncludeMatched bool `json:"include_matched"`
+	Page           int  `json:"page" validate:"min=1"`
+	Limit          int  `json:"limit" validate:"min=1,max=100"`
+}
+
+// AtipayReconciliationEntryItem is one reconciled settlement or payment request
+type AtipayReconciliationEntryItem struct {
+	UUID              string  `json:"uuid"`
+	Status            string  `json:"status"`
+	ReferenceNumber   string  `json:"reference_number,omitempty"`
+	RRN               string  `json:"rrn,omitempty"`
+	ReservationNumber string  `json:"reservation_number,omitempty"`
+	PaymentRequestID  *uint   `json:"payment_request_id,omitempty"`
+	ReportAmount      *uint64 `json:"report_amount,omitempty"` // toman
+	SystemAmount      *uint64 `json:"system_amount,omitempty"` // toman
+	ReportStatus      string  `json:"report_status,omitempty"`
+	Detail            string  `json:"detail,omitempty"`
+}
+
+// AdminAtipayReconciliationReportResponse lists the reconciliation of a day
+type AdminAtipayReconciliationReportResponse struct {
+	Message    string                          `json:"message"`
+	Summary    AtipayReconciliationSummary     `json:"summary"`
+	Items      []AtipayReconciliationEntryItem `json:"items"`
+	Pagination PaginationInfo                  `json:"pagination"`
+}
@@ -0,0 +1,34 @@
+package dto
+
+// AudienceImportRowError describes a rejected CSV row. Row is the 1-based data
+// row number, not counting the header.
+type AudienceImportRowError struct {
+	Row         int64  `json:"row"`
+	PhoneNumber string `json:"phone_number,omitempty"`
+	Error       string `json:"error"`
+}
+
+// AudienceImportJobItem represents the progress of a bulk audience import
+type AudienceImportJobItem struct {
+	UUID          string                   `json:"uuid"`
+	FileName      string                   `json:"file_name"`
+	Status        string                   `json:"status"`
+	TotalRows     int64                    `json:"total_rows"`
+	ProcessedRows int64                    `json:"processed_rows"`
+	InsertedRows  int64                    `json:"inserted_rows"`
+	ExistingRows  int64                    `json:"existing_rows"`
+	DuplicateRows int64                    `json:"duplicate_rows"`
+	FailedRows    int64                    `json:"failed_rows"`
+	Progress      float64                  `json:"progress"`
+	RowErrors     []AudienceImportRowError `json:"row_errors"`
+	ErrorMessage  *string                  `json:"error_message,omitempty"`
+	StartedAt     *string                  `json:"started_at,omitempty"`
+	CompletedAt   *string                  `json:"completed_at,omitempty"`
+	CreatedAt     string                   `json:"created_at"`
+}
+
+// AudienceImportJobResponse represents the response to an import upload or progress poll
+type AudienceImportJobResponse struct {
+	Message string                `json:"message"`
+	Job     AudienceImportJobItem `json:"job"`
+}
@@ -0,0 +1,52 @@
+package dto
+
+// BlacklistRowError describes a rejected CSV row. Row is the 1-based data row
+// number, not counting the header.
+type BlacklistRowError struct {
+	Row         int64  `json:"row"`
+	PhoneNumber string `json:"phone_number,omitempty"`
+	Error       string `json:"error"`
+}
+
+// BlacklistUploadResponse summarizes a bulk blacklist upload
+type BlacklistUploadResponse struct {
+	Message       string              `json:"message"`
+	Source        string              `json:"source"`
+	TotalRows     int64               `json:"total_rows"`
+	InsertedRows  int64               `json:"inserted_rows"`
+	ExistingRows  int64               `json:"existing_rows"`
+	DuplicateRows int64               `json:"duplicate_rows"`
+	FailedRows    int64               `json:"failed_rows"`
+	RowErrors     []BlacklistRowError `json:"row_errors"`
+}
+
+// AdminListBlacklistFilter represents query params for listing blacklisted numbers
+type AdminListBlacklistFilter struct {
+	PhoneNumber *string `json:"phone_number,omitempty"`
+	Source      *string `json:"source,omitempty" validate:"omitempty,oneof=regulator complaint admin"`
+	Page        int     `json:"page" validate:"min=1"`
+	Limit       int     `json:"limit" validate:"min=1,max=100"`
+}
+
+// BlacklistedNumberItem represents a blacklisted number
+type BlacklistedNumberItem struct {
+	ID          uint    `json:"id"`
+	PhoneNumber string  `json:"phone_number"`
+	Source      string  `json:"source"`
+	Reason      *string `json:"reason,omitempty"`
+	AdminID     *uint   `json:"admin_id,omitempty"`
+	CreatedAt   string  `json:"created_at"`
+}
+
+// AdminListBlacklistResponse represents a paginated list of blacklisted numbers
+type AdminListBlacklistResponse struct {
+	Message    string                  `json:"message"`
+	Items      []BlacklistedNumberItem `json:"items"`
+	Pagination PaginationInfo          `json:"pagination"`
+}
+
+// AdminDeleteBlacklistedNumberResponse represents the response of removing a number from the blacklist
+type AdminDeleteBlacklistedNumberResponse struct {
+	Message     string `json:"message"`
+	PhoneNumber string `json:"phone_number"`
+}
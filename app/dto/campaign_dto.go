@@ -34,6 +34,30 @@ type CreateCampaignRequest struct {
 	AudienceGrades []string `json:"audience_grades,omitempty" validate:"omitempty,dive,oneof=A B C"`
 
 	TargetAudienceExcelFileUUID *string `json:"target_audience_excel_file_uuid,omitempty" validate:"omitempty,uuid4"`
+
+	Recurrence *CampaignRecurrenceSpec `json:"recurrence,omitempty"`
+
+	Variants []CampaignContentVariantSpec `json:"variants,omitempty" validate:"omitempty,min=2,max=3,dive"`
+}
+
+// CampaignRecurrenceSpec repeats a campaign after its first run. Frequency is
+// daily (every Interval days), weekly (every Interval weeks on Weekdays, 0 is
+// Sunday) or cron (five-field expression evaluated in UTC).
+type CampaignRecurrenceSpec struct {
+	Frequency      string     `json:"frequency" validate:"required,oneof=daily weekly cron"`
+	Interval       uint       `json:"interval,omitempty" validate:"omitempty,max=365"`
+	Weekdays       []int      `json:"weekdays,omitempty" validate:"omitempty,max=7,dive,min=0,max=6"`
+	Cron           *string    `json:"cron,omitempty" validate:"omitempty,max=255"`
+	Until          *time.Time `json:"until,omitempty"`
+	MaxOccurrences *uint      `json:"max_occurrences,omitempty" validate:"omitempty,min=2,max=1000"`
+}
+
+// CampaignContentVariantSpec is one content alternative of an A/B tested SMS
+// campaign. Weights are percentages of recipients and must sum to 100.
+type CampaignContentVariantSpec struct {
+	Label   string `json:"label" validate:"required,max=16"`
+	Content string `json:"content" validate:"required,max=4096"`
+	Weight  uint   `json:"weight" validate:"required,min=1,max=99"`
 }
 
 // CreateCampaignResponse represents the response to create a new campaign
@@ -43,6 +67,40 @@ type CreateCampaignResponse struct {
 	UUID      string `json:"uuid"`
 	Status    string `json:"status"`
 	CreatedAt string `json:"created_at"`
+	// Encoding (gsm7 or ucs2) and Parts size the SMS body, opt-out suffix
+	// included; they are omitted for other platforms and empty content
+	Encoding string `json:"encoding,omitempty"`
+	Parts    uint64 `json:"parts,omitempty"`
+}
+
+// BulkCreateCampaignsRequest creates up to 100 campaigns in one request. With
+// Atomic set one invalid campaign rejects them all; otherwise the valid ones
+// are created and the others reported in the results.
+type BulkCreateCampaignsRequest struct {
+	CustomerID uint                    `json:"-"`
+	Atomic     bool                    `json:"atomic"`
+	Campaigns  []CreateCampaignRequest `json:"campaigns" validate:"required,min=1,max=100,dive"`
+}
+
+// BulkCreateCampaignResult is the outcome of the campaign at Index of the
+// request
+type BulkCreateCampaignResult struct {
+	Index     int    `json:"index"`
+	Success   bool   `json:"success"`
+	ID        uint   `json:"id,omitempty"`
+	UUID      string `json:"uuid,omitempty"`
+	Status    string `json:"status,omitempty"`
+	ErrorCode string `json:"error_code,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// BulkCreateCampaignsResponse reports every campaign of a bulk request in
+// request order
+type BulkCreateCampaignsResponse struct {
+	Created     int                        `json:"created"`
+	Failed      int                        `json:"failed"`
+	TotalBudget uint64                     `json:"total_budget"`
+	Results     []BulkCreateCampaignResult `json:"results"`
 }
 
 // UpdateCampaignRequest represents the request to update an existing campaign
@@ -75,6 +133,14 @@ type UpdateCampaignRequest struct {
 	AudienceGrades []string `json:"audience_grades,omitempty" validate:"omitempty,dive,oneof=A B C"`
 
 	TargetAudienceExcelFileUUID *string `json:"target_audience_excel_file_uuid,omitempty" validate:"omitempty,uuid4"`
+
+	Recurrence *CampaignRecurrenceSpec `json:"recurrence,omitempty"`
+
+	Variants []CampaignContentVariantSpec `json:"variants,omitempty" validate:"omitempty,min=2,max=3,dive"`
+
+	// ReviewComment is added to the review history when a campaign sent back
+	// for changes is finalized again
+	ReviewComment *string `json:"review_comment,omitempty" validate:"omitempty,max=1000"`
 }
 
 // UpdateCampaignResponse represents the response to update an existing campaign
@@ -82,10 +148,12 @@ type UpdateCampaignResponse struct {
 	Message string `json:"message"`
 }
 
-// CloneCampaignRequest represents the request to clone an existing campaign
+// CloneCampaignRequest represents the request to clone an existing campaign.
+// Title optionally renames the clone; otherwise the source title is kept.
 type CloneCampaignRequest struct {
-	UUID       string `json:"-" validate:"required,uuid4"`
-	CustomerID uint   `json:"-"`
+	UUID       string  `json:"-" validate:"required,uuid4"`
+	CustomerID uint    `json:"-"`
+	Title      *string `json:"title,omitempty" validate:"omitempty,max=255"`
 }
 
 // CloneCampaignResponse represents the response after cloning a campaign
@@ -105,6 +173,31 @@ type CancelCampaignRequest struct {
 
 type CancelCampaignResponse struct {
 	Message string `json:"message"`
+	// Set when a running or paused campaign is stopped before all messages were sent
+	SentBeforeStop *uint64 `json:"sent_before_stop,omitempty"`
+	RefundAmount   *uint64 `json:"refund_amount,omitempty"`
+}
+
+// PauseCampaignRequest represents a request to pause a running campaign between send batches
+type PauseCampaignRequest struct {
+	CampaignID uint `json:"campaign_id" validate:"required"`
+	CustomerID uint `json:"-"`
+}
+
+type PauseCampaignResponse struct {
+	Message string `json:"message"`
+	Status  string `json:"status"`
+}
+
+// ResumeCampaignRequest represents a request to resume a paused campaign
+type ResumeCampaignRequest struct {
+	CampaignID uint `json:"campaign_id" validate:"required"`
+	CustomerID uint `json:"-"`
+}
+
+type ResumeCampaignResponse struct {
+	Message string `json:"message"`
+	Status  string `json:"status"`
 }
 
 // SendCampaignTestMessageRequest represents a request to send a test message for a campaign.
@@ -134,6 +227,7 @@ type GetCampaignResponse struct {
 	ID                   uint           `json:"id"`
 	UUID                 string         `json:"uuid"`
 	Hidden               bool           `json:"hidden"`
+	IsSandbox            bool           `json:"is_sandbox"`
 	Status               string         `json:"status"`
 	CreatedAt            time.Time      `json:"created_at"`
 	UpdatedAt            *time.Time     `json:"updated_at,omitempty"`
@@ -172,6 +266,15 @@ type GetCampaignResponse struct {
 	AudienceGrades []string `json:"audience_grades"`
 
 	TargetAudienceExcelFileUUID *string `json:"target_audience_excel_file_uuid,omitempty"`
+
+	Recurrence         *CampaignRecurrenceSpec `json:"recurrence,omitempty"`
+	ParentCampaignID   *uint                   `json:"parent_campaign_id,omitempty"`
+	OccurrenceNumber   *uint                   `json:"occurrence_number,omitempty"`
+	NextOccurrenceAt   *time.Time              `json:"next_occurrence_at,omitempty"`
+	ExecutionStoppedAt *time.Time              `json:"execution_stopped_at,omitempty"`
+	SentBeforeStop     *uint64                 `json:"sent_before_stop,omitempty"`
+
+	Variants []CampaignContentVariantSpec `json:"variants,omitempty"`
 }
 
 // CalculateCampaignCapacityRequest represents the request to calculate the capacity of an campaign
@@ -202,12 +305,43 @@ type CalculateCampaignCostV2Request struct {
 	CustomerID  uint   `json:"-"`
 }
 
+// EstimateCampaignRequest describes a prospective SMS campaign to price without
+// creating it. Either Content or TextLength must be provided; TextLength is the
+// message length in characters including any link.
+type EstimateCampaignRequest struct {
+	CustomerID      uint     `json:"-"`
+	Tags            []string `json:"tags" validate:"required,min=1,max=255,dive,max=255"`
+	Content         *string  `json:"content,omitempty" validate:"omitempty,max=4096,min=1"`
+	TextLength      *uint64  `json:"text_length,omitempty" validate:"omitempty,min=1,max=4096"`
+	AdLink          *string  `json:"adlink,omitempty" validate:"omitempty,max=10000"`
+	ShortLinkDomain *string  `json:"short_link_domain,omitempty" validate:"omitempty,max=255"`
+	LineNumber      string   `json:"line_number" validate:"required,max=255"`
+	Level3s         []string `json:"level3s,omitempty" validate:"omitempty,max=255,dive,max=255"`
+}
+
+// EstimateCampaignResponse represents the estimated cost and reach of a campaign.
+// MinCost covers only the preferred (white) audience, MaxCost every reachable
+// recipient including the pink fallback pool.
+type EstimateCampaignResponse struct {
+	Message           string            `json:"message"`
+	Encoding          string            `json:"encoding"`
+	Parts             uint64            `json:"parts"`
+	PricePerMessage   uint64            `json:"price_per_message"`
+	AudienceByColor   map[string]uint64 `json:"audience_by_color"`
+	ReachableAudience uint64            `json:"reachable_audience"`
+	MinCost           uint64            `json:"min_cost"`
+	MaxCost           uint64            `json:"max_cost"`
+}
+
 // CalculateCampaignCostResponse represents the response to calculate the cost of an campaign
 type CalculateCampaignCostResponse struct {
 	Message           string `json:"message"`
 	TotalCost         uint64 `json:"total_cost"`
 	NumTargetAudience uint64 `json:"msg_target"`
 	MaxTargetAudience uint64 `json:"max_msg_target"`
+	// Encoding and Parts size the SMS body the cost is charged for
+	Encoding string `json:"encoding,omitempty"`
+	Parts    uint64 `json:"parts,omitempty"`
 }
 
 // ListCampaignsFilter represents filter criteria for listing campaigns in request layer
@@ -215,7 +349,7 @@ type ListCampaignsFilter struct {
 	CampaignTitle *string    `json:"campaign_title,omitempty" validate:"omitempty,max=255"`
 	BundleTitle   *string    `json:"bundle_title,omitempty" validate:"omitempty,max=255"`
 	CustomerName  *string    `json:"customer_name,omitempty" validate:"omitempty,max=255"`
-	Status        *string    `json:"status,omitempty" validate:"omitempty,max=255,oneof=initiated in-progress waiting-for-approval approved rejected cancelled running executed expired cancelled-by-admin"`
+	Status        *string    `json:"status,omitempty" validate:"omitempty,max=255,oneof=initiated in-progress waiting-for-approval changes-requested approved rejected cancelled running paused executed expired cancelled-by-admin"`
 	BundleID      *uint      `json:"bundle_id,omitempty" validate:"omitempty,min=1"`
 	Platform      *string    `json:"platform,omitempty" validate:"omitempty,oneof=sms rubika bale splus"`
 	StartDate     *time.Time `json:"start_date,omitempty" validate:"omitempty"`
@@ -240,6 +374,93 @@ type PaginationInfo struct {
 	TotalPages int   `json:"total_pages"`
 }
 
+// ValidateCampaignContentRequest checks the personalization variables of SMS
+// content, e.g. {first_name} or {city|Tehran}, before it is submitted
+type ValidateCampaignContentRequest struct {
+	CustomerID uint   `json:"-"`
+	Content    string `json:"content" validate:"required,max=4096"`
+}
+
+// ValidateCampaignContentResponse lists the variables found in the content.
+// Unknown variables would be sent literally and block finalization.
+type ValidateCampaignContentResponse struct {
+	Valid              bool     `json:"valid"`
+	Variables          []string `json:"variables"`
+	UnknownVariables   []string `json:"unknown_variables"`
+	SupportedVariables []string `json:"supported_variables"`
+}
+
+// GetCampaignVariantStatsRequest represents the request for the A/B variant
+// statistics of a campaign
+type GetCampaignVariantStatsRequest struct {
+	UUID       string `json:"-"`
+	CustomerID uint   `json:"-"`
+}
+
+// CampaignVariantStats reports delivery and clicks of one content variant.
+// Rates are nil until the variant has sent or delivered messages.
+type CampaignVariantStats struct {
+	Label        string   `json:"label"`
+	Weight       uint     `json:"weight"`
+	Sent         int64    `json:"sent"`
+	Delivered    int64    `json:"delivered"`
+	DeliveryRate *float64 `json:"delivery_rate,omitempty"`
+	Clicks       int64    `json:"clicks"`
+	ClickRate    *float64 `json:"click_rate,omitempty"`
+}
+
+// GetCampaignVariantStatsResponse represents the per-variant statistics of a campaign
+type GetCampaignVariantStatsResponse struct {
+	Message  string                 `json:"message"`
+	Variants []CampaignVariantStats `json:"variants"`
+}
+
+// GetCampaignOperatorStatsRequest represents the request for the per-operator
+// delivery statistics of a campaign
+type GetCampaignOperatorStatsRequest struct {
+	UUID       string `json:"-"`
+	CustomerID uint   `json:"-"`
+}
+
+// CampaignOperatorStats reports delivery and clicks of the recipients on one
+// mobile operator. Operator is "unknown" for unrecognized prefixes.
+// Rates are nil until the operator has sent or delivered messages.
+type CampaignOperatorStats struct {
+	Operator     string   `json:"operator"`
+	Sent         int64    `json:"sent"`
+	Delivered    int64    `json:"delivered"`
+	DeliveryRate *float64 `json:"delivery_rate,omitempty"`
+	Clicks       int64    `json:"clicks"`
+	ClickRate    *float64 `json:"click_rate,omitempty"`
+}
+
+// GetCampaignOperatorStatsResponse represents the per-operator statistics of a campaign
+type GetCampaignOperatorStatsResponse struct {
+	Message   string                  `json:"message"`
+	Operators []CampaignOperatorStats `json:"operators"`
+}
+
+// GetCampaignRetryStatsRequest represents the request for the recipient
+// retry outcomes of a campaign
+type GetCampaignRetryStatsRequest struct {
+	UUID       string `json:"-"`
+	CustomerID uint   `json:"-"`
+}
+
+// GetCampaignRetryStatsResponse reports the recipients of a campaign resent
+// after a transient provider error, by outcome. PermanentErrors counts its
+// messages rejected with a permanent error code, which are not resent.
+type GetCampaignRetryStatsResponse struct {
+	Message         string `json:"message"`
+	Retried         int64  `json:"retried"`
+	Pending         int64  `json:"pending"`
+	Recovered       int64  `json:"recovered"`
+	Exhausted       int64  `json:"exhausted"`
+	Permanent       int64  `json:"permanent"`
+	Attempts        int64  `json:"attempts"`
+	PermanentErrors int64  `json:"permanent_errors"`
+}
+
 // ListCampaignsResponse represents a paginated list of campaigns
 type ListCampaignsResponse struct {
 	Message    string                `json:"message"`
@@ -277,11 +498,16 @@ type AdminListCampaignsFilter struct {
 	CampaignTitle *string    `json:"campaign_title,omitempty" validate:"omitempty,max=255"`
 	BundleTitle   *string    `json:"bundle_title,omitempty" validate:"omitempty,max=255"`
 	CustomerName  *string    `json:"customer_name,omitempty" validate:"omitempty,max=255"`
-	Status        *string    `json:"status,omitempty" validate:"omitempty,oneof=initiated in-progress waiting-for-approval approved rejected expired cancelled running executed cancelled-by-admin"`
+	Status        *string    `json:"status,omitempty" validate:"omitempty,oneof=initiated in-progress waiting-for-approval changes-requested approved rejected expired cancelled running paused executed cancelled-by-admin"`
 	StartDate     *time.Time `json:"start_date,omitempty" validate:"omitempty"`
 	EndDate       *time.Time `json:"end_date,omitempty" validate:"omitempty"`
-	Page          int        `json:"page" validate:"omitempty,min=1,max=1000000"`
-	Limit         int        `json:"limit" validate:"omitempty,min=1,max=100"`
+	// IncludeDeleted lists soft-deleted campaigns too
+	IncludeDeleted bool `json:"include_deleted,omitempty"`
+	// Filter and Sort are list query expressions over the campaign list fields
+	Filter string `json:"filter,omitempty" validate:"omitempty,max=2000"`
+	Sort   string `json:"sort,omitempty" validate:"omitempty,max=200"`
+	Page   int    `json:"page" validate:"omitempty,min=1,max=1000000"`
+	Limit  int    `json:"limit" validate:"omitempty,min=1,max=100"`
 }
 
 // AdminGetCampaignResponse represents the campaign specification in responses
@@ -289,6 +515,7 @@ type AdminGetCampaignResponse struct {
 	ID                    uint           `json:"id"`
 	UUID                  string         `json:"uuid"`
 	Hidden                bool           `json:"hidden"`
+	IsSandbox             bool           `json:"is_sandbox"`
 	Status                string         `json:"status"`
 	CreatedAt             time.Time      `json:"created_at"`
 	UpdatedAt             *time.Time     `json:"updated_at,omitempty"`
@@ -328,6 +555,12 @@ type AdminGetCampaignResponse struct {
 	AudienceGrades []string `json:"audience_grades"`
 
 	TargetAudienceExcelFileUUID *string `json:"target_audience_excel_file_uuid,omitempty"`
+
+	// Audience candidates skipped because they are blacklisted; set once the campaign is processed
+	BlacklistedExcluded *int64 `json:"blacklisted_excluded,omitempty"`
+
+	// Set on soft-deleted campaigns, which are listed with include_deleted
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
 }
 
 // AdminListCampaignsResponse represents a paginated list of campaigns
@@ -359,6 +592,17 @@ type AdminRejectCampaignResponse struct {
 	Message string `json:"message"`
 }
 
+// AdminRequestCampaignChangesRequest represents an admin revision request
+type AdminRequestCampaignChangesRequest struct {
+	CampaignID uint   `json:"campaign_id" validate:"required"`
+	Comment    string `json:"comment" validate:"required,max=1000"`
+}
+
+// AdminRequestCampaignChangesResponse represents admin revision request result
+type AdminRequestCampaignChangesResponse struct {
+	Message string `json:"message"`
+}
+
 // AdminCancelCampaignRequest represents admin cancellation input
 type AdminCancelCampaignRequest struct {
 	CampaignID uint   `json:"campaign_id" validate:"required"`
@@ -368,6 +612,9 @@ type AdminCancelCampaignRequest struct {
 // AdminCancelCampaignResponse represents admin cancellation result
 type AdminCancelCampaignResponse struct {
 	Message string `json:"message"`
+	// Set when a running or paused campaign is stopped before all messages were sent
+	SentBeforeStop *uint64 `json:"sent_before_stop,omitempty"`
+	RefundAmount   *uint64 `json:"refund_amount,omitempty"`
 }
 
 // AdminRescheduleCampaignRequest represents admin reschedule input (schedule_at must be UTC)
@@ -425,6 +672,7 @@ type BotGetCampaignResponse struct {
 	ID                 uint                             `json:"id"`
 	CustomerID         uint                             `json:"customer_id"`
 	Hidden             bool                             `json:"hidden"`
+	Sandbox            bool                             `json:"sandbox,omitempty"` // executed against mock providers
 	Status             string                           `json:"status"`
 	CreatedAt          time.Time                        `json:"created_at"`
 	UpdatedAt          *time.Time                       `json:"updated_at,omitempty"`
@@ -457,6 +705,8 @@ type BotGetCampaignResponse struct {
 	AudienceGrades []string `json:"audience_grades"`
 
 	TargetAudienceExcelFileUUID *string `json:"target_audience_excel_file_uuid,omitempty"`
+
+	Variants []CampaignContentVariantSpec `json:"variants,omitempty"`
 }
 
 type BotCampaignPlatformSettingsSpec struct {
@@ -507,3 +757,83 @@ type BotUpdateCampaignStatisticsRequest struct {
 type BotUpdateCampaignStatisticsResponse struct {
 	Message string `json:"message"`
 }
+
+// CampaignReviewItem is an entry in the review history of a campaign
+type CampaignReviewItem struct {
+	ID         uint    `json:"id"`
+	Action     string  `json:"action"`
+	AuthorType string  `json:"author_type"`
+	AdminID    *uint   `json:"admin_id,omitempty"`
+	Comment    *string `json:"comment,omitempty"`
+	CreatedAt  string  `json:"created_at"`
+}
+
+// ListCampaignReviewsRequest represents a customer request for the review history of a campaign
+type ListCampaignReviewsRequest struct {
+	UUID       string `json:"-"`
+	CustomerID uint   `json:"-"`
+}
+
+// ListCampaignReviewsResponse represents the review history of a campaign, oldest first
+type ListCampaignReviewsResponse struct {
+	Message    string               `json:"message"`
+	CampaignID uint                 `json:"campaign_id"`
+	Status     string               `json:"status"`
+	Items      []CampaignReviewItem `json:"items"`
+}
+
+// AddCampaignReviewCommentRequest represents a customer comment on the review of a campaign
+type AddCampaignReviewCommentRequest struct {
+	UUID       string `json:"-"`
+	CustomerID uint   `json:"-"`
+	Comment    string `json:"comment" validate:"required,max=1000"`
+}
+
+// AdminAddCampaignReviewCommentRequest represents an admin comment on the review of a campaign
+type AdminAddCampaignReviewCommentRequest struct {
+	CampaignID uint   `json:"-"`
+	Comment    string `json:"comment" validate:"required,max=1000"`
+}
+
+// AddCampaignReviewCommentResponse represents the stored review comment
+type AddCampaignReviewCommentResponse struct {
+	Message string             `json:"message"`
+	Review  CampaignReviewItem `json:"review"`
+}
+
+// CampaignTimelineEvent is one entry in the execution timeline of a campaign
+type CampaignTimelineEvent struct {
+	At string `json:"at"`
+	// Kind is one of created, audit, review, prepared, batch_sent,
+	// provider_response, execution_stopped and deleted
+	Kind string `json:"kind"`
+	// Source is the table the event was read from
+	Source  string         `json:"source"`
+	Title   string         `json:"title"`
+	Success *bool          `json:"success,omitempty"`
+	Details map[string]any `json:"details,omitempty"`
+}
+
+// CampaignDeliverySummary aggregates the delivery status reports of a campaign
+type CampaignDeliverySummary struct {
+	Platform         string `json:"platform"`
+	SentAudience     uint64 `json:"sent_audience"`
+	Reported         int64  `json:"reported"`
+	FullyDelivered   int64  `json:"fully_delivered"`
+	TotalParts       int64  `json:"total_parts"`
+	DeliveredParts   int64  `json:"delivered_parts"`
+	UndeliveredParts int64  `json:"undelivered_parts"`
+	UnknownParts     int64  `json:"unknown_parts"`
+}
+
+// AdminCampaignTimelineResponse is what happened to a campaign, oldest first
+type AdminCampaignTimelineResponse struct {
+	Message    string                   `json:"message"`
+	CampaignID uint                     `json:"campaign_id"`
+	Status     string                   `json:"status"`
+	Events     []CampaignTimelineEvent  `json:"events"`
+	Delivery   *CampaignDeliverySummary `json:"delivery,omitempty"`
+	// Truncated is set when the campaign has more audit entries or batches
+	// than the timeline lists
+	Truncated bool `json:"truncated"`
+}
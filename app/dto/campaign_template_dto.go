@@ -0,0 +1,80 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CampaignTemplateSpec is the reusable part of a campaign: message content and targeting
+type CampaignTemplateSpec struct {
+	Title              *string    `json:"title,omitempty" validate:"omitempty,max=255"`
+	Level1             *string    `json:"level1,omitempty" validate:"omitempty,max=255"`
+	Level2s            []string   `json:"level2s,omitempty" validate:"omitempty,max=255,dive,max=255"`
+	Level3s            []string   `json:"level3s,omitempty" validate:"omitempty,max=255,dive,max=255"`
+	Tags               []string   `json:"tags,omitempty" validate:"omitempty,max=255,dive,max=255"`
+	Sex                *string    `json:"sex,omitempty" validate:"omitempty,max=255"`
+	City               []string   `json:"city,omitempty" validate:"omitempty,max=255,dive,max=255"`
+	AdLink             *string    `json:"adlink,omitempty" validate:"omitempty,max=10000"`
+	Content            *string    `json:"content,omitempty" validate:"omitempty,max=4096,min=1"`
+	ShortLinkDomain    *string    `json:"short_link_domain,omitempty" validate:"omitempty,max=255"`
+	Category           *string    `json:"job_category,omitempty" validate:"omitempty,max=255"`
+	Job                *string    `json:"job,omitempty" validate:"omitempty,max=255"`
+	LineNumber         *string    `json:"line_number,omitempty" validate:"omitempty,max=255"`
+	MediaUUID          *uuid.UUID `json:"media_uuid,omitempty"`
+	PlatformSettingsID *uint      `json:"platform_settings_id,omitempty" validate:"omitempty,min=1"`
+	Platform           *string    `json:"platform,omitempty" validate:"omitempty,oneof=sms rubika bale splus"`
+	AudienceGrades     []string   `json:"audience_grades,omitempty" validate:"omitempty,dive,oneof=A B C"`
+}
+
+// CreateCampaignTemplateRequest saves content and targeting as a named template
+type CreateCampaignTemplateRequest struct {
+	CustomerID uint                 `json:"-"`
+	Name       string               `json:"name" validate:"required,max=255"`
+	Spec       CampaignTemplateSpec `json:"spec"`
+}
+
+// AdminCreateCampaignTemplateRequest publishes a global template visible to all customers
+type AdminCreateCampaignTemplateRequest struct {
+	Name string               `json:"name" validate:"required,max=255"`
+	Spec CampaignTemplateSpec `json:"spec"`
+}
+
+// CampaignTemplateItem represents a template in API responses
+type CampaignTemplateItem struct {
+	UUID      string               `json:"uuid"`
+	Name      string               `json:"name"`
+	Global    bool                 `json:"global"`
+	Spec      CampaignTemplateSpec `json:"spec"`
+	CreatedAt string               `json:"created_at"`
+	UpdatedAt string               `json:"updated_at"`
+}
+
+// CampaignTemplateResponse represents the response to a template create request
+type CampaignTemplateResponse struct {
+	Message  string               `json:"message"`
+	Template CampaignTemplateItem `json:"template"`
+}
+
+// ListCampaignTemplatesResponse represents a list of templates
+type ListCampaignTemplatesResponse struct {
+	Message string                 `json:"message"`
+	Items   []CampaignTemplateItem `json:"items"`
+}
+
+// DeleteCampaignTemplateResponse represents the response to a template delete request
+type DeleteCampaignTemplateResponse struct {
+	Message string `json:"message"`
+}
+
+// CreateCampaignFromTemplateRequest creates a new campaign from a template.
+// Title overrides the template's title when provided.
+type CreateCampaignFromTemplateRequest struct {
+	UUID       string     `json:"-"`
+	CustomerID uint       `json:"-"`
+	Title      *string    `json:"title,omitempty" validate:"omitempty,max=255"`
+	ScheduleAt *time.Time `json:"scheduleat,omitempty"`
+	Budget     *uint64    `json:"budget,omitempty" validate:"omitempty"`
+	BundleID   *uint      `json:"bundle_id" validate:"required,min=1"`
+	Phase      *string    `json:"phase" validate:"required,max=255"`
+}
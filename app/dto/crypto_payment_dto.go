@@ -19,6 +19,7 @@ type CreateCryptoPaymentResponse struct {
 	ExpectedCoinAmount string  `json:"expected_coin_amount"`
 	ExchangeRate       string  `json:"exchange_rate"`
 	RateSource         string  `json:"rate_source"`
+	RateFetchedAt      *string `json:"rate_fetched_at,omitempty"`
 	ExpiresAt          *string `json:"expires_at,omitempty"`
 	PaymentURL         *string `json:"payment_url,omitempty"`
 }
@@ -54,6 +55,15 @@ type GetCryptoPaymentStatusResponse struct {
 	DepositMemo  *string          `json:"deposit_memo,omitempty"`
 	Deposits     []DepositInfoDTO `json:"deposits"`
 	ExpiresAt    *string          `json:"expires_at,omitempty"`
+	// Settlement is set once the request is credited
+	Settlement *CryptoSettlementDTO `json:"settlement,omitempty"`
+}
+
+// CryptoSettlementDTO tells how much a credited request was credited with
+type CryptoSettlementDTO struct {
+	Kind           string `json:"kind"` // exact|underpaid|overpaid
+	ReceivedCoin   string `json:"received_coin_amount"`
+	CreditedAmount uint64 `json:"credited_amount_toman"`
 }
 
 // GetSupportedAssetsResponse lists available platforms/coins/networks
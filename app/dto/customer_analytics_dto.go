@@ -0,0 +1,60 @@
+package dto
+
+// CustomerUsageReportRequest selects the months of a customer's usage report
+type CustomerUsageReportRequest struct {
+	CustomerID uint `json:"-"`
+	Months     int  `json:"months" validate:"omitempty,min=1,max=24"`
+}
+
+// CustomerMonthlyUsageItem is one month of a customer's usage
+type CustomerMonthlyUsageItem struct {
+	Month              string `json:"month"` // YYYY-MM-DD the month starts on
+	Campaigns          int64  `json:"campaigns"`
+	SentMessages       int64  `json:"sent_messages"`
+	SuccessfulMessages int64  `json:"successful_messages"`
+	DeliveredParts     int64  `json:"delivered_parts"`
+	Clicks             int64  `json:"clicks"`
+	CampaignSpend      uint64 `json:"campaign_spend"`
+	CampaignRefunds    uint64 `json:"campaign_refunds"`
+	NetCampaignSpend   uint64 `json:"net_campaign_spend"`
+	Charges            int64  `json:"charges"`
+	PaidWithTax        uint64 `json:"paid_with_tax"`
+}
+
+// CustomerUsageReportResponse is the API response for a customer's usage report
+type CustomerUsageReportResponse struct {
+	Message     string                     `json:"message"`
+	Months      []CustomerMonthlyUsageItem `json:"months"`
+	RefreshedAt *string                    `json:"refreshed_at,omitempty"`
+}
+
+// CampaignDailyStatsRequest selects the Tehran days of a campaign's daily
+// statistics; both bounds are inclusive
+type CampaignDailyStatsRequest struct {
+	CustomerID uint    `json:"-"`
+	UUID       string  `json:"uuid"`
+	StartDate  *string `json:"start_date,omitempty"`
+	EndDate    *string `json:"end_date,omitempty"`
+}
+
+// CampaignDailyStatItem is one day of a campaign's statistics; clicks are
+// unique clickers of that day
+type CampaignDailyStatItem struct {
+	Date               string `json:"date"`
+	SentMessages       int64  `json:"sent_messages"`
+	SuccessfulMessages int64  `json:"successful_messages"`
+	DeliveredParts     int64  `json:"delivered_parts"`
+	Clicks             int64  `json:"clicks"`
+	Spend              uint64 `json:"spend"`
+	Refunds            uint64 `json:"refunds"`
+}
+
+// CampaignDailyStatsResponse is the API response for a campaign's daily statistics
+type CampaignDailyStatsResponse struct {
+	Message     string                  `json:"message"`
+	UUID        string                  `json:"uuid"`
+	StartDate   string                  `json:"start_date"`
+	EndDate     string                  `json:"end_date"`
+	Days        []CampaignDailyStatItem `json:"days"`
+	RefreshedAt *string                 `json:"refreshed_at,omitempty"`
+}
@@ -0,0 +1,35 @@
+package dto
+
+// DataExportRequest represents a customer's request to export their data
+type DataExportRequest struct {
+	// Format of the records in the export bundle; defaults to json
+	Format string `json:"format" validate:"omitempty,oneof=json csv" example:"json"`
+}
+
+// AccountDeletionRequest represents a customer's request to delete their account
+type AccountDeletionRequest struct {
+	Password string `json:"password" validate:"required,max=100" example:"SecurePass123!"`
+}
+
+// CustomerDataRequestItem represents the progress of a data export or account deletion
+type CustomerDataRequestItem struct {
+	UUID         string  `json:"uuid"`
+	Type         string  `json:"type"`
+	Status       string  `json:"status"`
+	Format       *string `json:"format,omitempty"`
+	ScheduledFor *string `json:"scheduled_for,omitempty"`
+	ErrorMessage *string `json:"error_message,omitempty"`
+	StartedAt    *string `json:"started_at,omitempty"`
+	CompletedAt  *string `json:"completed_at,omitempty"`
+	CancelledAt  *string `json:"cancelled_at,omitempty"`
+	ExpiresAt    *string `json:"expires_at,omitempty"`
+	// DownloadURL is set once an export file is ready
+	DownloadURL *string `json:"download_url,omitempty"`
+	CreatedAt   string  `json:"created_at"`
+}
+
+// CustomerDataRequestResponse represents the response to a data request or status poll
+type CustomerDataRequestResponse struct {
+	Message string                  `json:"message"`
+	Request CustomerDataRequestItem `json:"request"`
+}
@@ -12,4 +12,7 @@ type APIResponse struct {
 type ErrorDetail struct {
 	Code    string `json:"code"`
 	Details any    `json:"details,omitempty" validate:"omitempty"`
+	// RequestID is the X-Request-ID of the failed request, for support to
+	// find it in the logs
+	RequestID string `json:"request_id,omitempty"`
 }
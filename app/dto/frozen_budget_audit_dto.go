@@ -0,0 +1,51 @@
+package dto
+
+import "time"
+
+// Kinds of frozen budget inconsistencies
+const (
+	FrozenBudgetIssueMissingReservation = "missing_reservation"      // campaign waits for approval without a budget reservation
+	FrozenBudgetIssueCampaignMismatch   = "campaign_frozen_mismatch" // campaign transactions hold a different frozen amount than its status calls for
+	FrozenBudgetIssueWalletMismatch     = "wallet_frozen_mismatch"   // wallet frozen balance differs from its campaign transactions
+)
+
+// FrozenBudgetIssue is one inconsistency between a campaign's frozen budget,
+// its transactions and its wallet's frozen balance. Amounts are toman.
+type FrozenBudgetIssue struct {
+	Kind       string `json:"kind"`
+	CustomerID uint   `json:"customer_id"`
+	WalletID   *uint  `json:"wallet_id,omitempty"`
+	WalletUUID string `json:"wallet_uuid,omitempty"`
+
+	// Campaign issues
+	CampaignID               *uint      `json:"campaign_id,omitempty"`
+	CampaignUUID             string     `json:"campaign_uuid,omitempty"`
+	CampaignStatus           string     `json:"campaign_status,omitempty"`
+	ExpectedFrozen           *int64     `json:"expected_frozen,omitempty"` // reservation while waiting for approval, otherwise 0
+	ReservationUUID          *string    `json:"reservation_uuid,omitempty"`
+	ReservationCorrelationID *string    `json:"reservation_correlation_id,omitempty"`
+	Transactions             int64      `json:"transactions,omitempty"`
+	LastTransactionAt        *time.Time `json:"last_transaction_at,omitempty"`
+
+	// LedgerFrozen is what the campaign's transactions, or all campaign
+	// transactions of the wallet, moved into frozen minus what they moved out
+	LedgerFrozen int64 `json:"ledger_frozen"`
+
+	// Wallet issues
+	BalanceFrozen *uint64    `json:"balance_frozen,omitempty"` // frozen balance of the latest snapshot
+	SnapshotID    *uint      `json:"snapshot_id,omitempty"`
+	SnapshotAt    *time.Time `json:"snapshot_at,omitempty"`
+	CampaignIDs   []uint     `json:"campaign_ids,omitempty"` // campaigns of the wallet whose transactions hold frozen budget
+
+	Difference int64  `json:"difference"` // ledger minus expected, or balance minus ledger
+	Detail     string `json:"detail"`
+}
+
+// AdminFrozenBudgetAuditResponse lists the frozen budget inconsistencies found
+type AdminFrozenBudgetAuditResponse struct {
+	Message          string              `json:"message"`
+	CheckedAt        time.Time           `json:"checked_at"`
+	CampaignsChecked int                 `json:"campaigns_checked"` // waiting for approval or holding frozen budget
+	WalletsChecked   int                 `json:"wallets_checked"`   // holding frozen budget
+	Issues           []FrozenBudgetIssue `json:"issues"`
+}
@@ -0,0 +1,39 @@
+package dto
+
+// AdminListJobsFilter represents query params of the background job list
+type AdminListJobsFilter struct {
+	Status *string `json:"status,omitempty" validate:"omitempty,oneof=pending running succeeded dead cancelled"`
+	Type   *string `json:"type,omitempty" validate:"omitempty,max=64"`
+	Page   int     `json:"page" validate:"min=1"`
+	Limit  int     `json:"limit" validate:"min=1,max=100"`
+}
+
+// JobItem is one background job
+type JobItem struct {
+	UUID        string         `json:"uuid"`
+	Type        string         `json:"type"`
+	Payload     map[string]any `json:"payload"`
+	Status      string         `json:"status"`
+	Attempts    int            `json:"attempts"`
+	MaxAttempts int            `json:"max_attempts"`
+	RunAt       string         `json:"run_at"`
+	LastError   *string        `json:"last_error,omitempty"`
+	CompletedAt *string        `json:"completed_at,omitempty"`
+	DeadAt      *string        `json:"dead_at,omitempty"`
+	CancelledAt *string        `json:"cancelled_at,omitempty"`
+	CreatedAt   string         `json:"created_at"`
+	UpdatedAt   string         `json:"updated_at"`
+}
+
+// AdminJobResponse returns a single job
+type AdminJobResponse struct {
+	Message string  `json:"message"`
+	Job     JobItem `json:"job"`
+}
+
+// AdminListJobsResponse lists background jobs
+type AdminListJobsResponse struct {
+	Message    string         `json:"message"`
+	Items      []JobItem      `json:"items"`
+	Pagination PaginationInfo `json:"pagination"`
+}
@@ -0,0 +1,114 @@
+package dto
+
+import "time"
+
+// UploadKYCDocumentRequest uploads one verification document; the file is
+// sent as multipart form data next to these fields
+type UploadKYCDocumentRequest struct {
+	CustomerID uint   `json:"-"`
+	Kind       string `json:"kind" form:"kind" validate:"required,oneof=registration_certificate official_gazette representative_id"`
+	FileName   string `json:"-"`
+}
+
+// KYCDocumentItem is an uploaded document. DownloadURL is a signed link that
+// stops working at DownloadURLExpiresAt.
+type KYCDocumentItem struct {
+	UUID                 string     `json:"uuid"`
+	Kind                 string     `json:"kind"`
+	FileName             string     `json:"file_name"`
+	ContentType          string     `json:"content_type"`
+	FileSize             int64      `json:"file_size"`
+	SHA256               string     `json:"sha256"`
+	DownloadURL          string     `json:"download_url,omitempty"`
+	DownloadURLExpiresAt *time.Time `json:"download_url_expires_at,omitempty"`
+	CreatedAt            time.Time  `json:"created_at"`
+}
+
+// UploadKYCDocumentResponse returns the stored document; it replaces the
+// previous document of the same kind
+type UploadKYCDocumentResponse struct {
+	Message  string          `json:"message"`
+	Document KYCDocumentItem `json:"document"`
+}
+
+// KYCStatusResponse is the customer's verification state, their current
+// documents and the required kinds still missing
+type KYCStatusResponse struct {
+	Message         string            `json:"message"`
+	Status          string            `json:"status"` // not_submitted, pending_review, verified or rejected
+	SubmittedAt     *time.Time        `json:"submitted_at,omitempty"`
+	ReviewedAt      *time.Time        `json:"reviewed_at,omitempty"`
+	RejectionReason *string           `json:"rejection_reason,omitempty"`
+	Documents       []KYCDocumentItem `json:"documents"`
+	MissingKinds    []string          `json:"missing_kinds"`
+	// QuotaCap is the sending quota cap applied until the documents are
+	// verified; nil when the customer is not capped
+	QuotaCap *KYCQuotaCap `json:"quota_cap,omitempty"`
+}
+
+// KYCQuotaCap caps the recipients an unverified company may message; a nil
+// limit leaves that period uncapped
+type KYCQuotaCap struct {
+	DailyLimit   *uint64 `json:"daily_limit,omitempty"`
+	MonthlyLimit *uint64 `json:"monthly_limit,omitempty"`
+}
+
+// SubmitKYCResponse returns the state after submitting documents for review
+type SubmitKYCResponse struct {
+	Message     string    `json:"message"`
+	Status      string    `json:"status"`
+	SubmittedAt time.Time `json:"submitted_at"`
+}
+
+// AdminListKYCReviewsFilter represents query params of the admin KYC queue
+type AdminListKYCReviewsFilter struct {
+	Status *string `json:"status,omitempty" validate:"omitempty,oneof=pending_review verified rejected"` // default pending_review
+	Page   int     `json:"page" validate:"min=1"`
+	Limit  int     `json:"limit" validate:"min=1,max=100"`
+}
+
+// AdminKYCReviewItem is a company customer in the KYC queue
+type AdminKYCReviewItem struct {
+	CustomerID   uint       `json:"customer_id"`
+	CustomerUUID string     `json:"customer_uuid"`
+	CompanyName  *string    `json:"company_name,omitempty"`
+	NationalID   *string    `json:"national_id,omitempty"`
+	Email        string     `json:"email"`
+	Status       string     `json:"status"`
+	SubmittedAt  *time.Time `json:"submitted_at,omitempty"`
+	ReviewedAt   *time.Time `json:"reviewed_at,omitempty"`
+}
+
+// AdminListKYCReviewsResponse lists customers by KYC status, longest waiting first
+type AdminListKYCReviewsResponse struct {
+	Message    string               `json:"message"`
+	Items      []AdminKYCReviewItem `json:"items"`
+	Pagination PaginationInfo       `json:"pagination"`
+}
+
+// AdminGetCustomerKYCResponse is a customer's KYC state with signed links
+// to every current document
+type AdminGetCustomerKYCResponse struct {
+	Message         string             `json:"message"`
+	Customer        AdminKYCReviewItem `json:"customer"`
+	ReviewerID      *uint              `json:"reviewer_id,omitempty"`
+	RejectionReason *string            `json:"rejection_reason,omitempty"`
+	Documents       []KYCDocumentItem  `json:"documents"`
+	MissingKinds    []string           `json:"missing_kinds"`
+}
+
+// AdminReviewKYCRequest approves or rejects a customer's submitted
+// documents; rejecting needs a reason, which is shown to the customer
+type AdminReviewKYCRequest struct {
+	CustomerID uint    `json:"-"`
+	Decision   string  `json:"decision" validate:"required,oneof=approve reject"`
+	Reason     *string `json:"reason,omitempty" validate:"omitempty,max=500"`
+}
+
+// AdminReviewKYCResponse returns the customer's KYC state after the review
+type AdminReviewKYCResponse struct {
+	Message    string    `json:"message"`
+	CustomerID uint      `json:"customer_id"`
+	Status     string    `json:"status"`
+	ReviewedAt time.Time `json:"reviewed_at"`
+}
@@ -9,6 +9,15 @@ type AdminCreateLineNumberRequest struct {
 	PriceFactor float64 `json:"price_factor" validate:"required,gt=0"`
 	Priority    *int    `json:"priority,omitempty" validate:"omitempty"`
 	IsActive    *bool   `json:"is_active,omitempty" validate:"omitempty"`
+	Operator    *string `json:"operator,omitempty" validate:"omitempty,oneof=mci mtn rightel"`
+	Tier        *string `json:"tier,omitempty" validate:"omitempty,max=50"`
+	// RatePerSecond is the provider throughput limit; 0 or omitted means unlimited
+	RatePerSecond *int    `json:"rate_per_second,omitempty" validate:"omitempty,min=0"`
+	Burst         *int    `json:"burst,omitempty" validate:"omitempty,min=0"`
+	Pool          *string `json:"pool,omitempty" validate:"omitempty,max=50"`
+	// CertifiedOperators limits the recipients routed through the line to
+	// these operators; empty means all of them
+	CertifiedOperators []string `json:"certified_operators,omitempty" validate:"omitempty,dive,oneof=mci mtn rightel"`
 }
 
 // AdminLineNumberDTO represents a line number for responses
@@ -19,17 +28,35 @@ type AdminLineNumberDTO struct {
 	LineNumber  string  `json:"line_number"`
 	PriceFactor float64 `json:"price_factor"`
 	Priority    *int    `json:"priority,omitempty"`
+	Operator    *string `json:"operator,omitempty"`
+	Tier        *string `json:"tier,omitempty"`
 	IsActive    *bool   `json:"is_active"`
-	CreatedAt   string  `json:"created_at"`
-	UpdatedAt   string  `json:"updated_at"`
+
+	RatePerSecond *int    `json:"rate_per_second,omitempty"`
+	Burst         *int    `json:"burst,omitempty"`
+	Pool          *string `json:"pool,omitempty"`
+
+	CertifiedOperators []string `json:"certified_operators,omitempty"`
+
+	CreatedAt string  `json:"created_at"`
+	UpdatedAt string  `json:"updated_at"`
+	DeletedAt *string `json:"deleted_at,omitempty"`
 }
 
 // AdminUpdateLineNumberItem represents one update operation for a line number
 // All IDs must exist; price_factor must be > 0; other fields optional
 type AdminUpdateLineNumberItem struct {
-	ID       uint  `json:"id" validate:"required"`
-	Priority *int  `json:"priority,omitempty" validate:"omitempty"`
-	IsActive *bool `json:"is_active,omitempty" validate:"omitempty"`
+	ID       uint    `json:"id" validate:"required"`
+	Priority *int    `json:"priority,omitempty" validate:"omitempty"`
+	IsActive *bool   `json:"is_active,omitempty" validate:"omitempty"`
+	Operator *string `json:"operator,omitempty" validate:"omitempty,oneof=mci mtn rightel"`
+	Tier     *string `json:"tier,omitempty" validate:"omitempty,max=50"`
+	// Set rate_per_second to 0 to remove the limit and pool to "" to leave the pool
+	RatePerSecond *int    `json:"rate_per_second,omitempty" validate:"omitempty,min=0"`
+	Burst         *int    `json:"burst,omitempty" validate:"omitempty,min=0"`
+	Pool          *string `json:"pool,omitempty" validate:"omitempty,max=50"`
+	// Set certified_operators to [] to certify the line for every operator
+	CertifiedOperators *[]string `json:"certified_operators,omitempty" validate:"omitempty,dive,oneof=mci mtn rightel"`
 }
 
 type AdminUpdateLineNumbersRequest struct {
@@ -61,3 +88,93 @@ type ListActiveLineNumbersResponse struct {
 	Message string                 `json:"message"`
 	Items   []ActiveLineNumberItem `json:"items"`
 }
+
+// AvailableLineNumberItem is a line number a customer can send from, with the
+// reservation pricing of its tier. Lines reserved by other customers are omitted.
+type AvailableLineNumberItem struct {
+	UUID        string  `json:"uuid"`
+	LineNumber  string  `json:"line_number"`
+	PriceFactor float64 `json:"price_factor"`
+	Operator    *string `json:"operator,omitempty"`
+	Tier        *string `json:"tier,omitempty"`
+
+	TierDisplayName        *string `json:"tier_display_name,omitempty"`
+	ReservationPricePerDay *uint64 `json:"reservation_price_per_day,omitempty"`
+	MaxReservationDays     *int    `json:"max_reservation_days,omitempty"`
+	Reservable             bool    `json:"reservable"`
+	ReservedByMe           bool    `json:"reserved_by_me"`
+	ReservationExpiresAt   *string `json:"reservation_expires_at,omitempty"`
+}
+
+// ListAvailableLineNumbersResponse wraps the available line numbers list for customers
+type ListAvailableLineNumbersResponse struct {
+	Message string                    `json:"message"`
+	Items   []AvailableLineNumberItem `json:"items"`
+}
+
+// ReserveLineNumberRequest reserves a line number for exclusive use
+type ReserveLineNumberRequest struct {
+	CustomerID     uint   `json:"-"`
+	LineNumberUUID string `json:"line_number_uuid" validate:"required,uuid"`
+	Days           int    `json:"days" validate:"required,min=1,max=365"`
+}
+
+// LineNumberReservationItem represents a reservation for responses
+type LineNumberReservationItem struct {
+	UUID       string  `json:"uuid"`
+	LineNumber string  `json:"line_number"`
+	Tier       *string `json:"tier,omitempty"`
+	Days       int     `json:"days"`
+	Price      uint64  `json:"price"`
+	StartsAt   string  `json:"starts_at"`
+	ExpiresAt  string  `json:"expires_at"`
+	ReleasedAt *string `json:"released_at,omitempty"`
+	IsActive   bool    `json:"is_active"`
+	CreatedAt  string  `json:"created_at"`
+}
+
+// LineNumberReservationResponse wraps a single reservation
+type LineNumberReservationResponse struct {
+	Message     string                    `json:"message"`
+	Reservation LineNumberReservationItem `json:"reservation"`
+}
+
+// ListLineNumberReservationsResponse wraps the customer's reservations
+type ListLineNumberReservationsResponse struct {
+	Message string                      `json:"message"`
+	Items   []LineNumberReservationItem `json:"items"`
+}
+
+// AdminCreateLineNumberTierRequest creates the reservation pricing of a tier
+// Name must match the tier of the line numbers it prices
+type AdminCreateLineNumberTierRequest struct {
+	Name                   string  `json:"name" validate:"required,max=50"`
+	DisplayName            *string `json:"display_name,omitempty" validate:"omitempty,max=255"`
+	Description            *string `json:"description,omitempty" validate:"omitempty"`
+	ReservationPricePerDay uint64  `json:"reservation_price_per_day" validate:"omitempty"`
+	MaxReservationDays     int     `json:"max_reservation_days" validate:"required,min=1,max=365"`
+	IsActive               *bool   `json:"is_active,omitempty" validate:"omitempty"`
+}
+
+// AdminUpdateLineNumberTierRequest updates the pricing of a tier; omitted fields are kept
+type AdminUpdateLineNumberTierRequest struct {
+	ID                     uint    `json:"-"`
+	DisplayName            *string `json:"display_name,omitempty" validate:"omitempty,max=255"`
+	Description            *string `json:"description,omitempty" validate:"omitempty"`
+	ReservationPricePerDay *uint64 `json:"reservation_price_per_day,omitempty" validate:"omitempty"`
+	MaxReservationDays     *int    `json:"max_reservation_days,omitempty" validate:"omitempty,min=1,max=365"`
+	IsActive               *bool   `json:"is_active,omitempty" validate:"omitempty"`
+}
+
+// AdminLineNumberTierDTO represents a tier for responses
+type AdminLineNumberTierDTO struct {
+	ID                     uint    `json:"id"`
+	Name                   string  `json:"name"`
+	DisplayName            *string `json:"display_name,omitempty"`
+	Description            *string `json:"description,omitempty"`
+	ReservationPricePerDay uint64  `json:"reservation_price_per_day"`
+	MaxReservationDays     int     `json:"max_reservation_days"`
+	IsActive               *bool   `json:"is_active"`
+	CreatedAt              string  `json:"created_at"`
+	UpdatedAt              string  `json:"updated_at"`
+}
@@ -8,7 +8,7 @@ import (
 // LoginRequest represents the request payload for user login
 type LoginRequest struct {
 	Identifier string `json:"identifier" validate:"required,mobile_format" example:"+989123456789"`
-	Password   string `json:"password" validate:"required,min=8,max=100" example:"SecurePass123!"`
+	Password   string `json:"password" validate:"required,max=1024" example:"SecurePass123!"`
 	OTPCode    string `json:"otp_code" validate:"required,len=6,numeric" example:"123456"`
 }
 
@@ -30,6 +30,27 @@ type LoginOTPResponse struct {
 	OTPSent     bool      `json:"otp_sent"`
 	AlreadySent bool      `json:"already_sent"`
 	OTPExpiry   time.Time `json:"otp_expiry"`
+	// Seconds until the OTP can be resent through /auth/otp/resend
+	ResendAvailableIn int `json:"resend_available_in"`
+}
+
+// LoginOTPResendRequest asks for a new login, passwordless login or password
+// reset OTP while one is still outstanding
+type LoginOTPResendRequest struct {
+	CustomerID uint   `json:"customer_id" validate:"required" example:"1"`
+	Purpose    string `json:"purpose" validate:"required,oneof=login passwordless_login password_reset" example:"login"`
+}
+
+// PasswordlessLoginOTPRequest asks for a code that logs in without a password
+type PasswordlessLoginOTPRequest struct {
+	Identifier string `json:"identifier" validate:"required,mobile_format" example:"+989123456789"`
+}
+
+// PasswordlessLoginRequest logs in with the mobile number and the code sent
+// to it
+type PasswordlessLoginRequest struct {
+	Identifier string `json:"identifier" validate:"required,mobile_format" example:"+989123456789"`
+	OTPCode    string `json:"otp_code" validate:"required,len=6,numeric" example:"123456"`
 }
 
 // ForgotPasswordRequest represents the request to initiate password reset
@@ -39,16 +60,17 @@ type ForgotPasswordRequest struct {
 
 // ForgetPasswordResponse represents the result of a password reset request
 type ForgetPasswordResponse struct {
-	CustomerID  uint
-	MaskedPhone string
-	OTPExpiry   time.Time
+	CustomerID        uint
+	MaskedPhone       string
+	OTPExpiry         time.Time
+	ResendAvailableIn int
 }
 
 // ResetPasswordRequest represents the request to reset password with OTP
 type ResetPasswordRequest struct {
 	CustomerID      uint   `json:"customer_id" validate:"required" example:"1"`
 	OTPCode         string `json:"otp_code" validate:"required,len=6,numeric" example:"123456"`
-	NewPassword     string `json:"new_password" validate:"required,min=8,max=100,password_strength" example:"NewSecurePass123!"`
+	NewPassword     string `json:"new_password" validate:"required,max=1024" example:"NewSecurePass123!"`
 	ConfirmPassword string `json:"confirm_password" validate:"required,eqfield=NewPassword" example:"NewSecurePass123!"`
 }
 
@@ -58,6 +80,55 @@ type ResetPasswordResponse struct {
 	Session  CustomerSessionDTO
 }
 
+// ChangePasswordRequest replaces the password of the logged in customer
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required,max=1024" example:"SecurePass123!"`
+	NewPassword     string `json:"new_password" validate:"required,max=1024" example:"NewSecurePass123!"`
+	ConfirmPassword string `json:"confirm_password" validate:"required,eqfield=NewPassword" example:"NewSecurePass123!"`
+}
+
+// ChangePasswordResponse reports how many other sessions were ended
+type ChangePasswordResponse struct {
+	Message       string `json:"message" example:"Password changed successfully"`
+	SessionsEnded int    `json:"sessions_ended" example:"2"`
+}
+
+// MobileChangeRequest starts replacing the representative mobile of the
+// logged in customer
+type MobileChangeRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required,max=1024" example:"SecurePass123!"`
+	NewMobile       string `json:"new_mobile" validate:"required,mobile_format" example:"+989123456789"`
+}
+
+// MobileChangeOTPResponse reports the code sent to the new mobile
+type MobileChangeOTPResponse struct {
+	Message           string    `json:"message" example:"OTP sent to the new mobile number"`
+	MaskedPhone       string    `json:"masked_phone" example:"+98912***6789"`
+	OTPExpiry         time.Time `json:"otp_expiry"`
+	ResendAvailableIn int       `json:"resend_available_in" example:"60"`
+}
+
+// MobileChangeConfirmRequest completes a mobile change with the code sent to
+// the new mobile
+type MobileChangeConfirmRequest struct {
+	OTPCode string `json:"otp_code" validate:"required,len=6,numeric" example:"123456"`
+}
+
+// MobileChangeResponse reports the new mobile and how many other sessions
+// were ended
+type MobileChangeResponse struct {
+	Message       string `json:"message" example:"Mobile number changed successfully"`
+	MaskedPhone   string `json:"masked_phone" example:"+98912***6789"`
+	SessionsEnded int    `json:"sessions_ended" example:"2"`
+}
+
+// PasswordRejectionDTO is a reason the password policy refused a new
+// password, with a message in the language of the request
+type PasswordRejectionDTO struct {
+	Reason  string `json:"reason" example:"missing_symbol"`
+	Message string `json:"message" example:"Password must contain a symbol"`
+}
+
 // MaskPhoneNumber masks the middle digits of a phone number for security
 func MaskPhoneNumber(phone string) string {
 	if len(phone) < 8 {
@@ -100,3 +171,52 @@ type CustomerSessionDTO struct {
 	TokenType    string  `json:"token_type" example:"Bearer"`
 	CreatedAt    string  `json:"created_at" example:"2024-01-15T10:30:00Z"`
 }
+
+// SessionDeviceDTO describes the client a session was created from
+type SessionDeviceDTO struct {
+	Platform   string `json:"platform,omitempty" example:"web"`
+	Browser    string `json:"browser,omitempty" example:"Chrome"`
+	Version    string `json:"version,omitempty" example:"120"`
+	OS         string `json:"os,omitempty" example:"Android"`
+	DeviceType string `json:"device_type,omitempty" example:"mobile"`
+	IsMobile   bool   `json:"is_mobile" example:"true"`
+}
+
+// SessionLocationDTO estimates where a session was created from
+type SessionLocationDTO struct {
+	Country string `json:"country,omitempty" example:"IR"`
+	IPRange string `json:"ip_range,omitempty" example:"5.160.12.0/24"`
+}
+
+// ActiveSessionDTO is an active session of the authenticated customer
+type ActiveSessionDTO struct {
+	ID             uint               `json:"id" example:"17"`
+	Device         SessionDeviceDTO   `json:"device"`
+	IPAddress      string             `json:"ip_address,omitempty" example:"5.160.12.34"`
+	UserAgent      string             `json:"user_agent,omitempty"`
+	Location       SessionLocationDTO `json:"location"`
+	Current        bool               `json:"current" example:"true"`
+	Impersonated   bool               `json:"impersonated" example:"false"` // opened by support staff acting as the customer
+	CreatedAt      string             `json:"created_at" example:"2024-01-15T10:30:00Z"`
+	LastAccessedAt string             `json:"last_accessed_at" example:"2024-01-15T11:05:00Z"`
+	ExpiresAt      string             `json:"expires_at" example:"2024-01-16T10:30:00Z"`
+}
+
+// LoginAlertReportRequest reports the login of a new-device alert as not the
+// customer's, using the token of the alert link
+type LoginAlertReportRequest struct {
+	Token string `json:"token" validate:"required,max=128" example:"3f2a9c..."`
+}
+
+// LoginAlertReportResponse confirms a reported login; the customer resets
+// their password through the forgot password flow
+type LoginAlertReportResponse struct {
+	Message     string `json:"message" example:"All sessions were ended. Reset your password to log in again."`
+	MaskedPhone string `json:"masked_phone" example:"+98912***6789"`
+}
+
+// ListSessionsResponse lists the active sessions of the authenticated customer
+type ListSessionsResponse struct {
+	Message  string             `json:"message" example:"Sessions retrieved successfully"`
+	Sessions []ActiveSessionDTO `json:"sessions"`
+}
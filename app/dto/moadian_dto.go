@@ -0,0 +1,11 @@
+package dto
+
+// MoadianSubmissionSummary reports a run of the Moadian submission queue
+type MoadianSubmissionSummary struct {
+	Enqueued  int `json:"enqueued"`
+	Submitted int `json:"submitted"`
+	Accepted  int `json:"accepted"`
+	Rejected  int `json:"rejected"`
+	Retried   int `json:"retried"`
+	Failed    int `json:"failed"`
+}
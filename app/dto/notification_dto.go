@@ -0,0 +1,53 @@
+package dto
+
+import "time"
+
+// ListNotificationsRequest pages a customer's inbox newest first
+type ListNotificationsRequest struct {
+	CustomerID uint `json:"-"`
+	UnreadOnly bool `json:"unread_only"`
+	// BeforeID is the next_before_id of the previous page
+	BeforeID uint `json:"before_id"`
+	Limit    int  `json:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+// NotificationItem is a notification rendered in the customer's locale
+type NotificationItem struct {
+	ID        uint           `json:"id"`
+	Kind      string         `json:"kind"`
+	Title     string         `json:"title"`
+	Body      string         `json:"body"`
+	Args      map[string]any `json:"args"`
+	Read      bool           `json:"read"`
+	ReadAt    *time.Time     `json:"read_at,omitempty"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+// ListNotificationsResponse is a page of the inbox. NextBeforeID is set when
+// older notifications remain.
+type ListNotificationsResponse struct {
+	Message      string             `json:"message"`
+	Items        []NotificationItem `json:"items"`
+	UnreadCount  int64              `json:"unread_count"`
+	NextBeforeID *uint              `json:"next_before_id,omitempty"`
+}
+
+// MarkNotificationsReadRequest marks notifications as read: the listed ids,
+// or every unread notification when All is set
+type MarkNotificationsReadRequest struct {
+	CustomerID uint   `json:"-"`
+	IDs        []uint `json:"ids" validate:"required_without=All,max=100,dive,min=1"`
+	All        bool   `json:"all"`
+}
+
+// MarkNotificationsReadResponse reports how many notifications were marked
+type MarkNotificationsReadResponse struct {
+	Message     string `json:"message"`
+	Marked      int64  `json:"marked"`
+	UnreadCount int64  `json:"unread_count"`
+}
+
+// UnreadNotificationsCountResponse is the badge count of the inbox
+type UnreadNotificationsCountResponse struct {
+	UnreadCount int64 `json:"unread_count"`
+}
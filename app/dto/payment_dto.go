@@ -99,6 +99,7 @@ type TransactionHistoryItem struct {
 	Operation           string            `json:"operation"`                                                 // Operation name for display
 	Source              string            `json:"source"`                                                    // Metadata source key
 	DepositMethod       string            `json:"deposit_method,omitempty"`                                  // payment_gateway, deposit_receipt, or admin_charge
+	IsSandbox           bool              `json:"is_sandbox"`                                                // Test money of a sandbox account
 	DateTime            time.Time         `json:"datetime"`                                                  // When the transaction occurred
 	ExternalRef         *string           `json:"external_ref,omitempty" validate:"omitempty"`               // External reference (e.g., Atipay reference)
 	CustomerInvoiceUUID *string           `json:"customer_invoice_uuid,omitempty" validate:"omitempty,uuid"` //
@@ -120,6 +121,9 @@ type AdminListTransactionsRequest struct {
 	EndDate      *time.Time `json:"end_date,omitempty" validate:"omitempty"`
 	CustomerID   *uint      `json:"customer_id,omitempty" validate:"omitempty,min=1"`
 	CustomerName *string    `json:"customer_name,omitempty" validate:"omitempty"`
+	// Filter and Sort are list query expressions over the transaction list fields
+	Filter string `json:"filter,omitempty" validate:"omitempty,max=2000"`
+	Sort   string `json:"sort,omitempty" validate:"omitempty,max=200"`
 }
 
 type AdminTransactionItem struct {
@@ -135,6 +139,7 @@ type AdminTransactionItem struct {
 	Operation           string                       `json:"operation"`
 	Source              string                       `json:"source"`
 	DepositMethod       string                       `json:"deposit_method,omitempty"`
+	IsSandbox           bool                         `json:"is_sandbox"`
 	DateTime            time.Time                    `json:"datetime"`
 	ExternalRef         *string                      `json:"external_ref,omitempty" validate:"omitempty"`
 	CustomerInvoiceUUID *string                      `json:"customer_invoice_uuid,omitempty" validate:"omitempty,uuid"`
@@ -187,6 +192,8 @@ var TransactionTypeDisplay = map[models.TransactionType]string{
 	models.TransactionTypeDebit:                       "Wallet Debit",
 	models.TransactionTypeChargeAgencyShareWithTax:    "Charge Agency Share with Tax",
 	models.TransactionTypeDischargeAgencyShareWithTax: "Discharge Agency Share with Tax",
+	models.TransactionTypeTransferOut:                 "Wallet Transfer Sent",
+	models.TransactionTypeTransferIn:                  "Wallet Transfer Received",
 }
 
 // TransactionStatusDisplay maps transaction statuses to human-readable status names
@@ -232,6 +239,10 @@ type SubmitDepositReceiptRequest struct {
 	ContentType string `json:"content_type" validate:"required,min=3,max=120"`
 	FileSize    int64  `json:"file_size" validate:"required,min=1,max=5242880"` // cap 5MB
 	FileBase64  string `json:"file_base64" validate:"required"`                 // frontend will send; backend decodes to []byte
+
+	// The transfer as printed on the receipt
+	TransferDate   string `json:"transfer_date" validate:"required,datetime=2006-01-02"` // YYYY-MM-DD, not in the future
+	TrackingNumber string `json:"tracking_number" validate:"required,min=4,max=64"`
 }
 
 type SubmitDepositReceiptResponse struct {
@@ -256,6 +267,14 @@ type DepositReceiptItem struct {
 	PreviewBase64    string    `json:"preview_base64,omitempty"`
 	PreviewType      string    `json:"preview_type,omitempty"`
 	CreatedAt        time.Time `json:"created_at"`
+
+	// The reported transfer and, once approved, the statement entry it was
+	// matched with and the amount credited
+	TransferDate   *string    `json:"transfer_date,omitempty"`
+	TrackingNumber *string    `json:"tracking_number,omitempty"`
+	BankReference  *string    `json:"bank_reference,omitempty"`
+	MatchedAmount  *uint64    `json:"matched_amount,omitempty"`
+	ReviewedAt     *time.Time `json:"reviewed_at,omitempty"`
 }
 
 type ListDepositReceiptsResponse struct {
@@ -279,6 +298,12 @@ type AdminUpdateDepositReceiptStatusRequest struct {
 	Action      string `json:"action" validate:"required,oneof=approve reject"`
 	// CustomerInvoiceUUID string `json:"customer_invoice_uuid,omitempty" validate:"omitempty,uuid"`
 	Reason string `json:"reason,omitempty" validate:"omitempty,min=3,max=500"`
+
+	// On approval, the bank statement entry finance matched the receipt with
+	// and the amount it shows; the wallet is credited with that amount, which
+	// cannot exceed the receipt's, or with the receipt's amount when none is given
+	BankReference string `json:"bank_reference,omitempty" validate:"required_if=Action approve,omitempty,min=3,max=64"`
+	MatchedAmount uint64 `json:"matched_amount,omitempty" validate:"omitempty,min=1000,max=1000000000"`
 }
 
 type AdminAddInvoiceToTransactionRequest struct {
@@ -0,0 +1,87 @@
+package dto
+
+// AdminSetCustomerCreditLimitRequest sets how far a customer may fund
+// campaigns on credit. A limit of 0 disables postpaid funding; what is
+// already outstanding stays billable.
+type AdminSetCustomerCreditLimitRequest struct {
+	CustomerID  uint   `json:"-"`
+	CreditLimit uint64 `json:"credit_limit" validate:"max=10000000000"` // toman
+}
+
+// CreditLineSummary is a customer's credit limit and what is owed on it.
+// Available is the limit minus outstanding, floored at zero.
+type CreditLineSummary struct {
+	CreditLimit  uint64  `json:"credit_limit"` // toman
+	Outstanding  uint64  `json:"outstanding"`
+	Available    uint64  `json:"available"`
+	Overdue      bool    `json:"overdue"`
+	NextDueAt    *string `json:"next_due_at,omitempty"`
+	OpenInvoices int64   `json:"open_invoices"`
+	UpdatedAt    *string `json:"updated_at,omitempty"`
+}
+
+// AdminCustomerCreditLineResponse is a customer's credit line as seen by admins
+type AdminCustomerCreditLineResponse struct {
+	Message    string            `json:"message"`
+	CustomerID uint              `json:"customer_id"`
+	CreditLine CreditLineSummary `json:"credit_line"`
+}
+
+// PostpaidInvoiceItem is one monthly postpaid invoice
+type PostpaidInvoiceItem struct {
+	UUID             string  `json:"uuid"`
+	CustomerID       uint    `json:"customer_id"`
+	PeriodStart      string  `json:"period_start"`
+	PeriodEnd        string  `json:"period_end"`
+	Amount           uint64  `json:"amount"` // toman
+	NumDraws         int64   `json:"num_draws"`
+	Status           string  `json:"status"`
+	Overdue          bool    `json:"overdue"`
+	DueAt            string  `json:"due_at"`
+	PaidAt           *string `json:"paid_at,omitempty"`
+	PaymentReference string  `json:"payment_reference,omitempty"`
+	CreatedAt        string  `json:"created_at"`
+}
+
+// AdminListPostpaidInvoicesFilter represents query params of the invoice list
+type AdminListPostpaidInvoicesFilter struct {
+	CustomerID  *uint   `json:"customer_id,omitempty"`
+	Status      *string `json:"status,omitempty" validate:"omitempty,oneof=open paid"`
+	OverdueOnly bool    `json:"overdue_only"`
+	Page        int     `json:"page" validate:"min=1"`
+	Limit       int     `json:"limit" validate:"min=1,max=100"`
+}
+
+// AdminListPostpaidInvoicesResponse lists postpaid invoices
+type AdminListPostpaidInvoicesResponse struct {
+	Message    string                `json:"message"`
+	Items      []PostpaidInvoiceItem `json:"items"`
+	Pagination PaginationInfo        `json:"pagination"`
+}
+
+// AdminMarkPostpaidInvoicePaidRequest records the settlement of an invoice,
+// e.g. a bank transfer
+type AdminMarkPostpaidInvoicePaidRequest struct {
+	PaymentReference string `json:"payment_reference" validate:"required,min=3,max=255"`
+}
+
+// AdminPostpaidInvoiceResponse returns an invoice after an admin action
+type AdminPostpaidInvoiceResponse struct {
+	Message string              `json:"message"`
+	Invoice PostpaidInvoiceItem `json:"invoice"`
+}
+
+// GetPostpaidSummaryResponse is the customer's own credit line and invoices
+type GetPostpaidSummaryResponse struct {
+	Message    string                `json:"message"`
+	CreditLine CreditLineSummary     `json:"credit_line"`
+	Invoices   []PostpaidInvoiceItem `json:"invoices"`
+}
+
+// PostpaidInvoicingSummary reports a monthly invoicing run
+type PostpaidInvoicingSummary struct {
+	PeriodStart string `json:"period_start"`
+	Issued      int    `json:"issued"`
+	Amount      uint64 `json:"amount"`
+	Failed      int    `json:"failed"`
+}
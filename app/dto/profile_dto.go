@@ -27,6 +27,7 @@ type ProfileDTO struct {
 	CompanyAddress          *string    `json:"company_address,omitempty"`
 	PostalCode              *string    `json:"postal_code,omitempty"`
 	ShebaNumber             *string    `json:"sheba_number,omitempty"`
+	ShebaVerification       string     `json:"sheba_verification" example:"verified"`
 	Category                *string    `json:"category,omitempty"`
 	Job                     *string    `json:"job,omitempty"`
 	AgencyRefererCode       string     `json:"agency_referer_code"`
@@ -34,12 +35,24 @@ type ProfileDTO struct {
 	IsActive                *bool      `json:"is_active"`
 	IsEmailVerified         *bool      `json:"is_email_verified"`
 	IsMobileVerified        *bool      `json:"is_mobile_verified"`
+	PreferredLocale         *string    `json:"preferred_locale,omitempty"`
 	LastLoginAt             *time.Time `json:"last_login_at,omitempty"`
 	CreatedAt               time.Time  `json:"created_at"`
 	UpdatedAt               time.Time  `json:"updated_at"`
 	// Agency-specific/helpful fields
 	AgencyID         *uint   `json:"agency_id,omitempty"`
 	ParentAgencyName *string `json:"parent_agency_name,omitempty"`
+	// Suspension is set while the customer is suspended
+	Suspension *CustomerSuspensionDTO `json:"suspension,omitempty"`
+}
+
+// CustomerSuspensionDTO is a customer's suspension as shown to the customer.
+// A warning blocks nothing, restricted blocks new campaigns and suspended
+// also blocks wallet recharges.
+type CustomerSuspensionDTO struct {
+	Level       string     `json:"level"`
+	Reason      string     `json:"reason"`
+	SuspendedAt *time.Time `json:"suspended_at,omitempty"`
 }
 
 type GetProfileResponse struct {
@@ -47,3 +60,120 @@ type GetProfileResponse struct {
 	Customer     ProfileDTO        `json:"customer"`
 	ParentAgency *AgencyProfileDTO `json:"parent_agency,omitempty"`
 }
+
+// UpdateLocaleRequest sets the language SMS, emails and pages are sent to the customer in
+type UpdateLocaleRequest struct {
+	Locale string `json:"locale" validate:"required,oneof=en fa"`
+}
+
+type UpdateLocaleResponse struct {
+	Message string `json:"message"`
+	Locale  string `json:"locale"`
+}
+
+// UpdateCompanyInfoRequest changes the company fields of a company account;
+// omitted fields are kept. A national ID change waits for an admin to
+// verify it.
+type UpdateCompanyInfoRequest struct {
+	CompanyName  *string `json:"company_name,omitempty" validate:"omitempty,min=1,max=60"`
+	NationalID   *string `json:"national_id,omitempty" validate:"omitempty,min=10,max=20,numeric"`
+	CompanyPhone *string `json:"company_phone,omitempty" validate:"omitempty,min=10,max=20"`
+}
+
+// UpdateAddressRequest changes the address fields; omitted fields are kept
+type UpdateAddressRequest struct {
+	CompanyAddress *string `json:"company_address,omitempty" validate:"omitempty,min=1,max=255"`
+	PostalCode     *string `json:"postal_code,omitempty" validate:"omitempty,min=10,max=20,numeric"`
+}
+
+// UpdateShebaRequest changes the Sheba number payouts go to; the change
+// waits for an admin to verify it
+type UpdateShebaRequest struct {
+	ShebaNumber string `json:"sheba_number" validate:"required,len=26" example:"IR820540102680020817909002"`
+}
+
+// ShebaVerificationResponse is the bank inquiry result of the customer's
+// Sheba number
+type ShebaVerificationResponse struct {
+	Message string `json:"message"`
+	// Status is verified, owner_mismatch or inactive
+	Status string `json:"status" example:"verified"`
+	// OwnerName is the account owner the bank reported
+	OwnerName  *string    `json:"owner_name,omitempty"`
+	VerifiedAt *time.Time `json:"verified_at,omitempty"`
+}
+
+// ProfileChangeDTO is one change in the history of a profile field
+type ProfileChangeDTO struct {
+	UUID     string  `json:"uuid"`
+	Field    string  `json:"field" example:"sheba_number"`
+	OldValue *string `json:"old_value,omitempty"`
+	NewValue *string `json:"new_value,omitempty"`
+	// Status is applied, pending_review, rejected or superseded
+	Status           string     `json:"status" example:"pending_review"`
+	ChangedByAdminID *uint      `json:"changed_by_admin_id,omitempty"`
+	ReviewedAt       *time.Time `json:"reviewed_at,omitempty"`
+	RejectionReason  *string    `json:"rejection_reason,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+}
+
+// UpdateProfileResponse lists the changes applied at once and those waiting
+// for an admin
+type UpdateProfileResponse struct {
+	Message       string             `json:"message"`
+	Applied       []ProfileChangeDTO `json:"applied"`
+	PendingReview []ProfileChangeDTO `json:"pending_review"`
+}
+
+// ListProfileChangesFilter represents query params of the profile change history
+type ListProfileChangesFilter struct {
+	Status *string `json:"status,omitempty" validate:"omitempty,oneof=applied pending_review rejected superseded"`
+	Page   int     `json:"page" validate:"min=1"`
+	Limit  int     `json:"limit" validate:"min=1,max=100"`
+}
+
+// ListProfileChangesResponse is a page of the profile change history, newest first
+type ListProfileChangesResponse struct {
+	Message    string             `json:"message"`
+	Items      []ProfileChangeDTO `json:"items"`
+	Pagination PaginationInfo     `json:"pagination"`
+}
+
+// AdminListProfileChangesFilter represents query params of the admin profile
+// change queue
+type AdminListProfileChangesFilter struct {
+	Status     *string `json:"status,omitempty" validate:"omitempty,oneof=applied pending_review rejected superseded"` // default pending_review
+	CustomerID *uint   `json:"customer_id,omitempty"`
+	Page       int     `json:"page" validate:"min=1"`
+	Limit      int     `json:"limit" validate:"min=1,max=100"`
+}
+
+// AdminProfileChangeItem is a profile change with the customer it belongs to
+type AdminProfileChangeItem struct {
+	ProfileChangeDTO
+	CustomerID  uint    `json:"customer_id"`
+	Email       string  `json:"email"`
+	CompanyName *string `json:"company_name,omitempty"`
+}
+
+// AdminListProfileChangesResponse is a page of profile changes; pending
+// reviews are listed longest waiting first
+type AdminListProfileChangesResponse struct {
+	Message    string                   `json:"message"`
+	Items      []AdminProfileChangeItem `json:"items"`
+	Pagination PaginationInfo           `json:"pagination"`
+}
+
+// AdminReviewProfileChangeRequest approves or rejects a change of a sensitive
+// field; rejecting needs a reason, which is shown to the customer
+type AdminReviewProfileChangeRequest struct {
+	UUID     string  `json:"-"`
+	Decision string  `json:"decision" validate:"required,oneof=approve reject"`
+	Reason   *string `json:"reason,omitempty" validate:"omitempty,max=500"`
+}
+
+// AdminReviewProfileChangeResponse returns the change after the review
+type AdminReviewProfileChangeResponse struct {
+	Message string                 `json:"message"`
+	Change  AdminProfileChangeItem `json:"change"`
+}
@@ -0,0 +1,57 @@
+package dto
+
+import "time"
+
+// RegisterPushDeviceRequest registers the FCM token of the browser or app
+// the customer is signed in on
+type RegisterPushDeviceRequest struct {
+	CustomerID uint   `json:"-"`
+	UserAgent  string `json:"-"`
+	Token      string `json:"token" validate:"required,max=4096"`
+	Platform   string `json:"platform" validate:"required,oneof=web android ios"`
+}
+
+// UnregisterPushDeviceRequest removes a token, e.g. on sign-out
+type UnregisterPushDeviceRequest struct {
+	CustomerID uint   `json:"-"`
+	Token      string `json:"token" validate:"required,max=4096"`
+}
+
+// PushDeviceItem is a registered device. The token itself is not returned.
+type PushDeviceItem struct {
+	ID         uint      `json:"id"`
+	Platform   string    `json:"platform"`
+	UserAgent  *string   `json:"user_agent,omitempty"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// RegisterPushDeviceResponse confirms the registration
+type RegisterPushDeviceResponse struct {
+	Message string         `json:"message"`
+	Device  PushDeviceItem `json:"device"`
+}
+
+// UnregisterPushDeviceResponse confirms the token was removed
+type UnregisterPushDeviceResponse struct {
+	Message string `json:"message"`
+}
+
+// ListPushDevicesResponse lists the customer's registered devices
+type ListPushDevicesResponse struct {
+	Enabled bool             `json:"enabled"`
+	Items   []PushDeviceItem `json:"items"`
+}
+
+// AdminBroadcastPushRequest sends a notification to every subscribed device
+type AdminBroadcastPushRequest struct {
+	Title string `json:"title" validate:"required,max=100"`
+	Body  string `json:"body" validate:"required,max=500"`
+	Link  string `json:"link,omitempty" validate:"omitempty,url,max=500"`
+}
+
+// AdminBroadcastPushResponse confirms the broadcast was accepted by FCM
+type AdminBroadcastPushResponse struct {
+	Message string `json:"message"`
+	Topic   string `json:"topic"`
+}
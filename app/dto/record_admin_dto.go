@@ -0,0 +1,18 @@
+package dto
+
+import "time"
+
+// AdminRecordRequest names a soft-deletable record: its kind (campaigns,
+// audience-profiles, tags or line-numbers) and ID
+type AdminRecordRequest struct {
+	Kind string `json:"-"`
+	ID   uint   `json:"-"`
+}
+
+// AdminRecordResponse returns a record after it was deleted or restored
+type AdminRecordResponse struct {
+	Message   string     `json:"message"`
+	Kind      string     `json:"kind"`
+	ID        uint       `json:"id"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
@@ -0,0 +1,27 @@
+package dto
+
+// AdminRuntimeSetting is a reloadable setting, named by its environment
+// variable, and the value in effect
+type AdminRuntimeSetting struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// AdminRuntimeSettingChange is a setting changed by a reload
+type AdminRuntimeSettingChange struct {
+	Key string `json:"key"`
+	Old string `json:"old"`
+	New string `json:"new"`
+}
+
+// AdminRuntimeConfigResponse lists the reloadable settings in effect
+type AdminRuntimeConfigResponse struct {
+	Settings []AdminRuntimeSetting `json:"settings"`
+}
+
+// AdminReloadRuntimeConfigResponse reports what a reload changed and the
+// settings in effect afterwards
+type AdminReloadRuntimeConfigResponse struct {
+	Changes  []AdminRuntimeSettingChange `json:"changes"`
+	Settings []AdminRuntimeSetting       `json:"settings"`
+}
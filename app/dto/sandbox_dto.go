@@ -0,0 +1,44 @@
+package dto
+
+// SandboxStatusResponse reports whether the authenticated customer is a
+// sandbox account and what sandbox data it holds
+type SandboxStatusResponse struct {
+	Message     string `json:"message"`
+	Enabled     bool   `json:"enabled"`
+	FreeBalance uint64 `json:"free_balance"` // toman
+}
+
+// SandboxTopUpRequest adds test money to a sandbox wallet
+type SandboxTopUpRequest struct {
+	CustomerID uint   `json:"-"`
+	Amount     uint64 `json:"amount" validate:"required,min=1000,max=1000000000"` // toman
+}
+
+// SandboxTopUpResponse returns the sandbox wallet after a top-up
+type SandboxTopUpResponse struct {
+	Message         string `json:"message"`
+	TransactionUUID string `json:"transaction_uuid"`
+	FreeBalance     uint64 `json:"free_balance"` // toman
+}
+
+// SandboxPurgeResponse reports what a sandbox purge deleted
+type SandboxPurgeResponse struct {
+	Message      string `json:"message"`
+	Campaigns    int64  `json:"campaigns"`
+	Transactions int64  `json:"transactions"`
+}
+
+// AdminSetCustomerSandboxRequest turns sandbox mode of a customer on or off
+type AdminSetCustomerSandboxRequest struct {
+	CustomerID uint `json:"-"`
+	Enabled    bool `json:"enabled"`
+}
+
+// AdminSetCustomerSandboxResponse returns the customer's sandbox mode and,
+// when it was turned off, what was purged
+type AdminSetCustomerSandboxResponse struct {
+	Message    string                `json:"message"`
+	CustomerID uint                  `json:"customer_id"`
+	Enabled    bool                  `json:"enabled"`
+	Purged     *SandboxPurgeResponse `json:"purged,omitempty"`
+}
@@ -25,11 +25,14 @@ type SignupRequest struct {
 
 	// Common fields (required for all types)
 	Email           string `json:"email" validate:"required,email,max=255"`
-	Password        string `json:"password" validate:"required,min=8,password_strength"`
+	Password        string `json:"password" validate:"required,max=1024"`
 	ConfirmPassword string `json:"confirm_password" validate:"required,eqfield=Password"`
 
 	// Optional agency referral
 	ReferrerAgencyCode *string `json:"referrer_agency_code,omitempty" validate:"omitempty,max=255"`
+
+	// Optional language of SMS and emails ("en" or "fa"); defaults to the Accept-Language header
+	Locale *string `json:"locale,omitempty" validate:"omitempty,oneof=en fa"`
 }
 
 // SignupResponse represents the response after successful signup initiation
@@ -64,4 +67,6 @@ type OTPResendResponse struct {
 	Message         string `json:"message"`
 	OTPSent         bool   `json:"otp_sent"`
 	MaskedOTPTarget string `json:"masked_otp_target"`
+	// Seconds until another OTP can be requested for the same target
+	ResendAvailableIn int `json:"resend_available_in"`
 }
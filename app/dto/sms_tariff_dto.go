@@ -0,0 +1,47 @@
+package dto
+
+// AdminCreateSMSTariffRequest represents the payload to add an SMS tariff row.
+// Omitted dimensions match any value; the most specific active tariff wins.
+type AdminCreateSMSTariffRequest struct {
+	Operator       *string `json:"operator,omitempty" validate:"omitempty,oneof=mci mtn rightel"`
+	LineNumberTier *string `json:"line_number_tier,omitempty" validate:"omitempty,max=50"`
+	AccountType    *string `json:"account_type,omitempty" validate:"omitempty,oneof=individual independent_company marketing_agency"`
+	PartCount      *uint64 `json:"part_count,omitempty" validate:"omitempty,gt=0"`
+	PricePerPart   uint64  `json:"price_per_part" validate:"required,gt=0"`
+	Description    *string `json:"description,omitempty" validate:"omitempty,max=1000"`
+	IsActive       *bool   `json:"is_active,omitempty"`
+}
+
+// AdminUpdateSMSTariffRequest replaces all mutable fields of an SMS tariff
+type AdminUpdateSMSTariffRequest struct {
+	UUID string `json:"-" validate:"required,uuid4"`
+	AdminCreateSMSTariffRequest
+}
+
+// AdminSMSTariffItem represents an SMS tariff in admin responses
+type AdminSMSTariffItem struct {
+	UUID           string  `json:"uuid"`
+	Operator       *string `json:"operator,omitempty"`
+	LineNumberTier *string `json:"line_number_tier,omitempty"`
+	AccountType    *string `json:"account_type,omitempty"`
+	PartCount      *uint64 `json:"part_count,omitempty"`
+	PricePerPart   uint64  `json:"price_per_part"`
+	Description    *string `json:"description,omitempty"`
+	IsActive       bool    `json:"is_active"`
+	CreatedAt      string  `json:"created_at"`
+	UpdatedAt      string  `json:"updated_at"`
+}
+
+type AdminSMSTariffResponse struct {
+	Message string             `json:"message"`
+	Tariff  AdminSMSTariffItem `json:"tariff"`
+}
+
+type AdminListSMSTariffsResponse struct {
+	Message string               `json:"message"`
+	Items   []AdminSMSTariffItem `json:"items"`
+}
+
+type AdminDeleteSMSTariffResponse struct {
+	Message string `json:"message"`
+}
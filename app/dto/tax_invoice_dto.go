@@ -0,0 +1,45 @@
+package dto
+
+// TaxInvoiceItem is one official invoice of a wallet charge
+type TaxInvoiceItem struct {
+	UUID             string `json:"uuid"`
+	InvoiceNumber    string `json:"invoice_number"`
+	CustomerID       uint   `json:"customer_id"`
+	PaymentChannel   string `json:"payment_channel"`
+	PaymentReference string `json:"payment_reference,omitempty"`
+	AmountWithTax    uint64 `json:"amount_with_tax"` // toman
+	Amount           uint64 `json:"amount"`
+	Tax              uint64 `json:"tax"`
+	TaxBasisPoints   uint64 `json:"tax_basis_points"`
+	Description      string `json:"description"`
+	IssuedAt         string `json:"issued_at"`
+}
+
+// ListTaxInvoicesResponse lists the customer's own invoices
+type ListTaxInvoicesResponse struct {
+	Message    string           `json:"message"`
+	Items      []TaxInvoiceItem `json:"items"`
+	Pagination PaginationInfo   `json:"pagination"`
+}
+
+// AdminListTaxInvoicesFilter represents query params of the admin invoice list
+type AdminListTaxInvoicesFilter struct {
+	CustomerID   *uint   `json:"customer_id,omitempty"`
+	IssuedAfter  *string `json:"issued_after,omitempty"`
+	IssuedBefore *string `json:"issued_before,omitempty"`
+	Page         int     `json:"page" validate:"min=1"`
+	Limit        int     `json:"limit" validate:"min=1,max=100"`
+}
+
+// AdminListTaxInvoicesResponse lists invoices of all customers
+type AdminListTaxInvoicesResponse struct {
+	Message    string           `json:"message"`
+	Items      []TaxInvoiceItem `json:"items"`
+	Pagination PaginationInfo   `json:"pagination"`
+}
+
+// TaxInvoicePDF is a rendered invoice ready to be downloaded
+type TaxInvoicePDF struct {
+	Filename string
+	Content  []byte
+}
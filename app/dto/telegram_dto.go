@@ -0,0 +1,61 @@
+package dto
+
+import "time"
+
+// StartTelegramLinkResponse carries the deep link that opens the bot with
+// the customer's link token
+type StartTelegramLinkResponse struct {
+	Message     string    `json:"message"`
+	DeepLink    string    `json:"deep_link"`
+	BotUsername string    `json:"bot_username"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// VerifyTelegramLinkRequest completes a link with the code the bot sent to
+// the chat
+type VerifyTelegramLinkRequest struct {
+	CustomerID uint   `json:"-"`
+	Code       string `json:"code" validate:"required,len=6,numeric"`
+}
+
+// VerifyTelegramLinkResponse confirms the linked chat
+type VerifyTelegramLinkResponse struct {
+	Message  string    `json:"message"`
+	LinkedAt time.Time `json:"linked_at"`
+}
+
+// TelegramLinkStatusResponse tells whether notices go to Telegram. Pending
+// is set while a chat opened the link and its code has not been entered.
+type TelegramLinkStatusResponse struct {
+	Enabled          bool       `json:"enabled"`
+	BotUsername      string     `json:"bot_username,omitempty"`
+	Linked           bool       `json:"linked"`
+	LinkedAt         *time.Time `json:"linked_at,omitempty"`
+	Pending          bool       `json:"pending"`
+	PendingExpiresAt *time.Time `json:"pending_expires_at,omitempty"`
+}
+
+// UnlinkTelegramResponse confirms notices are back on SMS
+type UnlinkTelegramResponse struct {
+	Message string `json:"message"`
+}
+
+// TelegramUpdate is the part of a Bot API update the webhook reads.
+// Docs: https://core.telegram.org/bots/api#update
+type TelegramUpdate struct {
+	UpdateID int64            `json:"update_id"`
+	Message  *TelegramMessage `json:"message,omitempty"`
+}
+
+// TelegramMessage is an incoming chat message
+type TelegramMessage struct {
+	MessageID int64        `json:"message_id"`
+	Chat      TelegramChat `json:"chat"`
+	Text      string       `json:"text"`
+}
+
+// TelegramChat identifies the chat a message came from
+type TelegramChat struct {
+	ID   int64  `json:"id"`
+	Type string `json:"type"`
+}
@@ -0,0 +1,55 @@
+package dto
+
+// AdminCreateWalletAdjustmentRequest asks for a manual credit or debit of a
+// customer's wallet. It takes effect once a second admin approves it.
+type AdminCreateWalletAdjustmentRequest struct {
+	CustomerID uint   `json:"customer_id" validate:"required,min=1"`
+	Direction  string `json:"direction" validate:"required,oneof=credit debit"`
+	Amount     uint64 `json:"amount" validate:"required,min=1,max=1000000000"` // toman
+	ReasonCode string `json:"reason_code" validate:"required,oneof=chargeback goodwill_credit refund correction other"`
+	Note       string `json:"note" validate:"required,min=3,max=1000"`
+}
+
+// AdminReviewWalletAdjustmentRequest approves or rejects a pending adjustment
+type AdminReviewWalletAdjustmentRequest struct {
+	Action string `json:"action" validate:"required,oneof=approve reject"`
+	Note   string `json:"note,omitempty" validate:"omitempty,max=1000"`
+}
+
+// AdminListWalletAdjustmentsFilter represents query params of the adjustment list
+type AdminListWalletAdjustmentsFilter struct {
+	CustomerID *uint   `json:"customer_id,omitempty"`
+	Status     *string `json:"status,omitempty" validate:"omitempty,oneof=pending approved rejected"`
+	Page       int     `json:"page" validate:"min=1"`
+	Limit      int     `json:"limit" validate:"min=1,max=100"`
+}
+
+// WalletAdjustmentItem is one adjustment request
+type WalletAdjustmentItem struct {
+	UUID               string  `json:"uuid"`
+	CustomerID         uint    `json:"customer_id"`
+	Direction          string  `json:"direction"`
+	Amount             uint64  `json:"amount"` // toman
+	ReasonCode         string  `json:"reason_code"`
+	Note               string  `json:"note"`
+	Status             string  `json:"status"`
+	RequestedByAdminID uint    `json:"requested_by_admin_id"`
+	ReviewedByAdminID  *uint   `json:"reviewed_by_admin_id,omitempty"`
+	ReviewNote         string  `json:"review_note,omitempty"`
+	ReviewedAt         *string `json:"reviewed_at,omitempty"`
+	TransactionID      *uint   `json:"transaction_id,omitempty"`
+	CreatedAt          string  `json:"created_at"`
+}
+
+// AdminWalletAdjustmentResponse returns a created or reviewed adjustment
+type AdminWalletAdjustmentResponse struct {
+	Message    string               `json:"message"`
+	Adjustment WalletAdjustmentItem `json:"adjustment"`
+}
+
+// AdminListWalletAdjustmentsResponse lists adjustment requests
+type AdminListWalletAdjustmentsResponse struct {
+	Message    string                 `json:"message"`
+	Items      []WalletAdjustmentItem `json:"items"`
+	Pagination PaginationInfo         `json:"pagination"`
+}
@@ -0,0 +1,86 @@
+package dto
+
+import "time"
+
+// Wallet charge methods of POST /api/v1/wallet/charge
+const (
+	WalletChargeMethodCard         = "card"
+	WalletChargeMethodCrypto       = "crypto"
+	WalletChargeMethodBankTransfer = "bank_transfer"
+)
+
+// WalletChargeRequest charges the wallet with the payment method named by
+// Method. The fields every method shares are at the top level; Crypto or
+// BankTransfer carry what only that method needs.
+type WalletChargeRequest struct {
+	CustomerID    uint   `json:"-"`
+	Method        string `json:"method" validate:"required,oneof=card crypto bank_transfer"`
+	AmountWithTax uint64 `json:"amount_with_tax" validate:"required,min=1000,max=1000000000"` // Tomans
+	Lang          string `json:"lang,omitempty" validate:"omitempty,oneof=FA EN fa en"`
+
+	Crypto       *WalletChargeCryptoDetails       `json:"crypto,omitempty" validate:"required_if=Method crypto,omitempty"`
+	BankTransfer *WalletChargeBankTransferDetails `json:"bank_transfer,omitempty" validate:"required_if=Method bank_transfer,omitempty"`
+}
+
+// WalletChargeCryptoDetails picks the coin, network and provider of a crypto
+// charge
+type WalletChargeCryptoDetails struct {
+	Coin     string `json:"coin" validate:"required,oneof=ETH DOGE XRP BNB"`
+	Network  string `json:"network" validate:"required"`
+	Platform string `json:"platform" validate:"required"`
+}
+
+// WalletChargeBankTransferDetails is the receipt of a bank transfer, sent as
+// base64 and reviewed by finance before the wallet is credited
+type WalletChargeBankTransferDetails struct {
+	FileName    string `json:"file_name" validate:"required,min=3,max=255"`
+	ContentType string `json:"content_type" validate:"required,min=3,max=120"`
+	FileSize    int64  `json:"file_size" validate:"required,min=1,max=5242880"`
+	FileBase64  string `json:"file_base64" validate:"required"`
+
+	TransferDate   string `json:"transfer_date" validate:"required,datetime=2006-01-02"`
+	TrackingNumber string `json:"tracking_number" validate:"required,min=4,max=64"`
+}
+
+// WalletChargeResponse is the started charge. Status is pending for every
+// method: the card payment waits for the gateway, the crypto payment for the
+// deposit and the bank transfer for review. Only the object of the method is
+// set.
+type WalletChargeResponse struct {
+	Message       string `json:"message"`
+	Method        string `json:"method"`
+	AmountWithTax uint64 `json:"amount_with_tax"`
+	Status        string `json:"status"`
+
+	Card         *ChargeWalletResponse         `json:"card,omitempty"`
+	Crypto       *CreateCryptoPaymentResponse  `json:"crypto,omitempty"`
+	BankTransfer *SubmitDepositReceiptResponse `json:"bank_transfer,omitempty"`
+}
+
+// ListWalletChargesRequest represents query params of a customer's recent
+// charges
+type ListWalletChargesRequest struct {
+	CustomerID uint   `json:"-"`
+	Method     string `json:"method,omitempty" validate:"omitempty,oneof=card crypto bank_transfer"`
+	Limit      int    `json:"limit" validate:"min=1,max=50"`
+}
+
+// WalletChargeItem is one charge of any method. Status is common to all
+// methods: pending, completed, failed, cancelled, expired, rejected or
+// refunded; MethodStatus is the status of the method's own request.
+type WalletChargeItem struct {
+	UUID          string    `json:"uuid"`
+	Method        string    `json:"method"`
+	AmountWithTax uint64    `json:"amount_with_tax"`
+	Status        string    `json:"status"`
+	MethodStatus  string    `json:"method_status"`
+	StatusReason  string    `json:"status_reason,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// ListWalletChargesResponse lists a customer's recent charges, newest first
+type ListWalletChargesResponse struct {
+	Message string             `json:"message"`
+	Items   []WalletChargeItem `json:"items"`
+}
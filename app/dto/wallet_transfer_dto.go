@@ -0,0 +1,95 @@
+package dto
+
+import "time"
+
+// InitiateWalletTransferRequest starts a transfer of free balance to another
+// account of the same company. It takes effect once the sender confirms the
+// OTP sent to their mobile.
+type InitiateWalletTransferRequest struct {
+	CustomerID uint   `json:"-"`
+	Receiver   string `json:"receiver" validate:"required,max=255"` // mobile or email of the receiving account
+	Amount     uint64 `json:"amount" validate:"required,min=1"`     // toman
+	Note       string `json:"note,omitempty" validate:"omitempty,max=500"`
+}
+
+// InitiateWalletTransferResponse returns the pending transfer and where its
+// OTP was sent
+type InitiateWalletTransferResponse struct {
+	Message     string             `json:"message"`
+	Transfer    WalletTransferItem `json:"transfer"`
+	MaskedPhone string             `json:"masked_phone"`
+	OTPExpiry   time.Time          `json:"otp_expiry"`
+}
+
+// ConfirmWalletTransferRequest confirms a pending transfer with its OTP
+type ConfirmWalletTransferRequest struct {
+	CustomerID uint   `json:"-"`
+	OTPCode    string `json:"otp_code" validate:"required,len=6,numeric"`
+}
+
+// ConfirmWalletTransferResponse returns the completed transfer
+type ConfirmWalletTransferResponse struct {
+	Message  string             `json:"message"`
+	Transfer WalletTransferItem `json:"transfer"`
+}
+
+// ListWalletTransfersRequest represents query params of a customer's transfer list
+type ListWalletTransfersRequest struct {
+	CustomerID uint `json:"-"`
+	Page       int  `json:"page" validate:"min=1"`
+	Limit      int  `json:"limit" validate:"min=1,max=100"`
+}
+
+// WalletTransferItem is one transfer as seen by the customer that sent or
+// received it
+type WalletTransferItem struct {
+	UUID             string     `json:"uuid"`
+	Direction        string     `json:"direction"` // sent or received
+	CounterpartyUUID string     `json:"counterparty_uuid"`
+	CounterpartyName string     `json:"counterparty_name"`
+	Amount           uint64     `json:"amount"` // toman
+	Note             *string    `json:"note,omitempty"`
+	Status           string     `json:"status"`
+	OTPExpiresAt     *time.Time `json:"otp_expires_at,omitempty"` // pending transfers only
+	CompletedAt      *time.Time `json:"completed_at,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+}
+
+// ListWalletTransfersResponse lists the transfers a customer sent or received
+type ListWalletTransfersResponse struct {
+	Message    string               `json:"message"`
+	Items      []WalletTransferItem `json:"items"`
+	Pagination PaginationInfo       `json:"pagination"`
+}
+
+// AdminListWalletTransfersFilter represents query params of the admin transfer list
+type AdminListWalletTransfersFilter struct {
+	CustomerID *uint   `json:"customer_id,omitempty"` // sender or receiver
+	Status     *string `json:"status,omitempty" validate:"omitempty,oneof=pending completed expired"`
+	Page       int     `json:"page" validate:"min=1"`
+	Limit      int     `json:"limit" validate:"min=1,max=100"`
+}
+
+// AdminWalletTransferItem is one transfer with both parties and its transactions
+type AdminWalletTransferItem struct {
+	UUID                  string     `json:"uuid"`
+	CorrelationID         string     `json:"correlation_id"`
+	SenderCustomerID      uint       `json:"sender_customer_id"`
+	ReceiverCustomerID    uint       `json:"receiver_customer_id"`
+	Amount                uint64     `json:"amount"` // toman
+	Note                  *string    `json:"note,omitempty"`
+	Status                string     `json:"status"`
+	OTPAttempts           int        `json:"otp_attempts"`
+	OTPExpiresAt          time.Time  `json:"otp_expires_at"`
+	SenderTransactionID   *uint      `json:"sender_transaction_id,omitempty"`
+	ReceiverTransactionID *uint      `json:"receiver_transaction_id,omitempty"`
+	CompletedAt           *time.Time `json:"completed_at,omitempty"`
+	CreatedAt             time.Time  `json:"created_at"`
+}
+
+// AdminListWalletTransfersResponse lists wallet transfers
+type AdminListWalletTransfersResponse struct {
+	Message    string                    `json:"message"`
+	Items      []AdminWalletTransferItem `json:"items"`
+	Pagination PaginationInfo            `json:"pagination"`
+}
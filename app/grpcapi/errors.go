@@ -0,0 +1,63 @@
+package grpcapi
+
+import (
+	"errors"
+	"log"
+
+	businessflow "github.com/amirphl/Yamata-no-Orochi/business_flow"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// errorDomain is the ErrorInfo domain of every error the services return
+const errorDomain = "yamata-no-orochi"
+
+// statusError returns a status carrying the API error code, the same one the
+// REST API would respond with, as ErrorInfo reason
+func statusError(code codes.Code, message, errorCode string) error {
+	st := status.New(code, message)
+	if withDetails, err := st.WithDetails(&errdetails.ErrorInfo{Reason: errorCode, Domain: errorDomain}); err == nil {
+		st = withDetails
+	}
+	return st.Err()
+}
+
+// ErrorCode returns the API error code of an error the services returned, or
+// "" if it carries none
+func ErrorCode(err error) string {
+	st, ok := status.FromError(err)
+	if !ok {
+		return ""
+	}
+	for _, d := range st.Details() {
+		if info, ok := d.(*errdetails.ErrorInfo); ok && info.Domain == errorDomain {
+			return info.Reason
+		}
+	}
+	return ""
+}
+
+// flowError maps a business flow error to a status, falling back to Internal
+// with the given message and code
+func flowError(method string, err error, message, errorCode string) error {
+	var be *businessflow.BusinessError
+	switch {
+	case businessflow.IsCampaignNotFound(err):
+		return statusError(codes.NotFound, "Campaign not found", "CAMPAIGN_NOT_FOUND")
+	case businessflow.IsCampaignPlatformInvalid(err):
+		return statusError(codes.InvalidArgument, "Invalid platform", "INVALID_PLATFORM")
+	case businessflow.IsCustomerNotFound(err):
+		return statusError(codes.NotFound, "Customer not found", "CUSTOMER_NOT_FOUND")
+	case businessflow.IsAccountInactive(err):
+		return statusError(codes.PermissionDenied, "Customer account is inactive", "ACCOUNT_INACTIVE")
+	case businessflow.IsWalletNotFound(err):
+		return statusError(codes.NotFound, "Wallet not found", "WALLET_NOT_FOUND")
+	case businessflow.IsBalanceSnapshotNotFound(err):
+		return statusError(codes.NotFound, "Balance snapshot not found", "BALANCE_SNAPSHOT_NOT_FOUND")
+	case errors.As(err, &be) && be.Code == "VALIDATION_ERROR":
+		return statusError(codes.InvalidArgument, be.Message, be.Code)
+	}
+	log.Printf("gRPC %s failed: %v", method, err)
+	return statusError(codes.Internal, message, errorCode)
+}
@@ -0,0 +1,129 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             (unknown)
+// source: internal/v1/auth.proto
+
+package internalv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	BotAuthService_Login_FullMethodName = "/yamata.internal.v1.BotAuthService/Login"
+)
+
+// BotAuthServiceClient is the client API for BotAuthService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// BotAuthService issues the bot access tokens the other services require in
+// the authorization metadata as "Bearer <token>".
+type BotAuthServiceClient interface {
+	// Login exchanges bot credentials for an access token.
+	Login(ctx context.Context, in *LoginRequest, opts ...grpc.CallOption) (*LoginResponse, error)
+}
+
+type botAuthServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewBotAuthServiceClient(cc grpc.ClientConnInterface) BotAuthServiceClient {
+	return &botAuthServiceClient{cc}
+}
+
+func (c *botAuthServiceClient) Login(ctx context.Context, in *LoginRequest, opts ...grpc.CallOption) (*LoginResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LoginResponse)
+	err := c.cc.Invoke(ctx, BotAuthService_Login_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// BotAuthServiceServer is the server API for BotAuthService service.
+// All implementations must embed UnimplementedBotAuthServiceServer
+// for forward compatibility.
+//
+// BotAuthService issues the bot access tokens the other services require in
+// the authorization metadata as "Bearer <token>".
+type BotAuthServiceServer interface {
+	// Login exchanges bot credentials for an access token.
+	Login(context.Context, *LoginRequest) (*LoginResponse, error)
+	mustEmbedUnimplementedBotAuthServiceServer()
+}
+
+// UnimplementedBotAuthServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedBotAuthServiceServer struct{}
+
+func (UnimplementedBotAuthServiceServer) Login(context.Context, *LoginRequest) (*LoginResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Login not implemented")
+}
+func (UnimplementedBotAuthServiceServer) mustEmbedUnimplementedBotAuthServiceServer() {}
+func (UnimplementedBotAuthServiceServer) testEmbeddedByValue()                        {}
+
+// UnsafeBotAuthServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to BotAuthServiceServer will
+// result in compilation errors.
+type UnsafeBotAuthServiceServer interface {
+	mustEmbedUnimplementedBotAuthServiceServer()
+}
+
+func RegisterBotAuthServiceServer(s grpc.ServiceRegistrar, srv BotAuthServiceServer) {
+	// If the following call panics, it indicates UnimplementedBotAuthServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&BotAuthService_ServiceDesc, srv)
+}
+
+func _BotAuthService_Login_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LoginRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BotAuthServiceServer).Login(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BotAuthService_Login_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BotAuthServiceServer).Login(ctx, req.(*LoginRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// BotAuthService_ServiceDesc is the grpc.ServiceDesc for BotAuthService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var BotAuthService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "yamata.internal.v1.BotAuthService",
+	HandlerType: (*BotAuthServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Login",
+			Handler:    _BotAuthService_Login_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "internal/v1/auth.proto",
+}
@@ -0,0 +1,1080 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: internal/v1/campaign.proto
+
+package internalv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type ListReadyCampaignsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// sms, rubika, bale or splus; every platform if empty
+	Platform      string `protobuf:"bytes,1,opt,name=platform,proto3" json:"platform,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListReadyCampaignsRequest) Reset() {
+	*x = ListReadyCampaignsRequest{}
+	mi := &file_internal_v1_campaign_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListReadyCampaignsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListReadyCampaignsRequest) ProtoMessage() {}
+
+func (x *ListReadyCampaignsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_v1_campaign_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListReadyCampaignsRequest.ProtoReflect.Descriptor instead.
+func (*ListReadyCampaignsRequest) Descriptor() ([]byte, []int) {
+	return file_internal_v1_campaign_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ListReadyCampaignsRequest) GetPlatform() string {
+	if x != nil {
+		return x.Platform
+	}
+	return ""
+}
+
+type ListReadyCampaignsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Campaigns     []*Campaign            `protobuf:"bytes,1,rep,name=campaigns,proto3" json:"campaigns,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListReadyCampaignsResponse) Reset() {
+	*x = ListReadyCampaignsResponse{}
+	mi := &file_internal_v1_campaign_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListReadyCampaignsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListReadyCampaignsResponse) ProtoMessage() {}
+
+func (x *ListReadyCampaignsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_v1_campaign_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListReadyCampaignsResponse.ProtoReflect.Descriptor instead.
+func (*ListReadyCampaignsResponse) Descriptor() ([]byte, []int) {
+	return file_internal_v1_campaign_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ListReadyCampaignsResponse) GetCampaigns() []*Campaign {
+	if x != nil {
+		return x.Campaigns
+	}
+	return nil
+}
+
+type Campaign struct {
+	state                       protoimpl.MessageState `protogen:"open.v1"`
+	Id                          uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	CustomerId                  uint64                 `protobuf:"varint,2,opt,name=customer_id,json=customerId,proto3" json:"customer_id,omitempty"`
+	Hidden                      bool                   `protobuf:"varint,3,opt,name=hidden,proto3" json:"hidden,omitempty"`
+	Status                      string                 `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`
+	CreatedAt                   *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt                   *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	Title                       *string                `protobuf:"bytes,7,opt,name=title,proto3,oneof" json:"title,omitempty"`
+	Level1                      *string                `protobuf:"bytes,8,opt,name=level1,proto3,oneof" json:"level1,omitempty"`
+	Level2S                     []string               `protobuf:"bytes,9,rep,name=level2s,proto3" json:"level2s,omitempty"`
+	Level3S                     []string               `protobuf:"bytes,10,rep,name=level3s,proto3" json:"level3s,omitempty"`
+	Tags                        []string               `protobuf:"bytes,11,rep,name=tags,proto3" json:"tags,omitempty"`
+	Sex                         *string                `protobuf:"bytes,12,opt,name=sex,proto3,oneof" json:"sex,omitempty"`
+	City                        []string               `protobuf:"bytes,13,rep,name=city,proto3" json:"city,omitempty"`
+	AdLink                      *string                `protobuf:"bytes,14,opt,name=ad_link,json=adLink,proto3,oneof" json:"ad_link,omitempty"`
+	Content                     *string                `protobuf:"bytes,15,opt,name=content,proto3,oneof" json:"content,omitempty"`
+	ShortLinkDomain             *string                `protobuf:"bytes,16,opt,name=short_link_domain,json=shortLinkDomain,proto3,oneof" json:"short_link_domain,omitempty"`
+	JobCategory                 *string                `protobuf:"bytes,17,opt,name=job_category,json=jobCategory,proto3,oneof" json:"job_category,omitempty"`
+	Job                         *string                `protobuf:"bytes,18,opt,name=job,proto3,oneof" json:"job,omitempty"`
+	ScheduleAt                  *timestamppb.Timestamp `protobuf:"bytes,19,opt,name=schedule_at,json=scheduleAt,proto3" json:"schedule_at,omitempty"`
+	LineNumber                  *string                `protobuf:"bytes,20,opt,name=line_number,json=lineNumber,proto3,oneof" json:"line_number,omitempty"`
+	MediaUuid                   *string                `protobuf:"bytes,21,opt,name=media_uuid,json=mediaUuid,proto3,oneof" json:"media_uuid,omitempty"`
+	PlatformSettingsId          *uint64                `protobuf:"varint,22,opt,name=platform_settings_id,json=platformSettingsId,proto3,oneof" json:"platform_settings_id,omitempty"`
+	PlatformSettings            *PlatformSettings      `protobuf:"bytes,23,opt,name=platform_settings,json=platformSettings,proto3" json:"platform_settings,omitempty"`
+	Platform                    string                 `protobuf:"bytes,24,opt,name=platform,proto3" json:"platform,omitempty"`
+	PlatformBasePrice           *uint64                `protobuf:"varint,25,opt,name=platform_base_price,json=platformBasePrice,proto3,oneof" json:"platform_base_price,omitempty"`
+	Budget                      *uint64                `protobuf:"varint,26,opt,name=budget,proto3,oneof" json:"budget,omitempty"`
+	Comment                     *string                `protobuf:"bytes,27,opt,name=comment,proto3,oneof" json:"comment,omitempty"`
+	NumAudiences                *uint64                `protobuf:"varint,28,opt,name=num_audiences,json=numAudiences,proto3,oneof" json:"num_audiences,omitempty"`
+	BundleId                    *uint64                `protobuf:"varint,29,opt,name=bundle_id,json=bundleId,proto3,oneof" json:"bundle_id,omitempty"`
+	Phase                       *string                `protobuf:"bytes,30,opt,name=phase,proto3,oneof" json:"phase,omitempty"`
+	AudienceGrades              []string               `protobuf:"bytes,31,rep,name=audience_grades,json=audienceGrades,proto3" json:"audience_grades,omitempty"`
+	TargetAudienceExcelFileUuid *string                `protobuf:"bytes,32,opt,name=target_audience_excel_file_uuid,json=targetAudienceExcelFileUuid,proto3,oneof" json:"target_audience_excel_file_uuid,omitempty"`
+	Variants                    []*ContentVariant      `protobuf:"bytes,33,rep,name=variants,proto3" json:"variants,omitempty"`
+	// executed against mock providers; nothing is sent for real
+	Sandbox       bool `protobuf:"varint,34,opt,name=sandbox,proto3" json:"sandbox,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Campaign) Reset() {
+	*x = Campaign{}
+	mi := &file_internal_v1_campaign_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Campaign) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Campaign) ProtoMessage() {}
+
+func (x *Campaign) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_v1_campaign_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Campaign.ProtoReflect.Descriptor instead.
+func (*Campaign) Descriptor() ([]byte, []int) {
+	return file_internal_v1_campaign_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Campaign) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Campaign) GetCustomerId() uint64 {
+	if x != nil {
+		return x.CustomerId
+	}
+	return 0
+}
+
+func (x *Campaign) GetHidden() bool {
+	if x != nil {
+		return x.Hidden
+	}
+	return false
+}
+
+func (x *Campaign) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Campaign) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Campaign) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+func (x *Campaign) GetTitle() string {
+	if x != nil && x.Title != nil {
+		return *x.Title
+	}
+	return ""
+}
+
+func (x *Campaign) GetLevel1() string {
+	if x != nil && x.Level1 != nil {
+		return *x.Level1
+	}
+	return ""
+}
+
+func (x *Campaign) GetLevel2S() []string {
+	if x != nil {
+		return x.Level2S
+	}
+	return nil
+}
+
+func (x *Campaign) GetLevel3S() []string {
+	if x != nil {
+		return x.Level3S
+	}
+	return nil
+}
+
+func (x *Campaign) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *Campaign) GetSex() string {
+	if x != nil && x.Sex != nil {
+		return *x.Sex
+	}
+	return ""
+}
+
+func (x *Campaign) GetCity() []string {
+	if x != nil {
+		return x.City
+	}
+	return nil
+}
+
+func (x *Campaign) GetAdLink() string {
+	if x != nil && x.AdLink != nil {
+		return *x.AdLink
+	}
+	return ""
+}
+
+func (x *Campaign) GetContent() string {
+	if x != nil && x.Content != nil {
+		return *x.Content
+	}
+	return ""
+}
+
+func (x *Campaign) GetShortLinkDomain() string {
+	if x != nil && x.ShortLinkDomain != nil {
+		return *x.ShortLinkDomain
+	}
+	return ""
+}
+
+func (x *Campaign) GetJobCategory() string {
+	if x != nil && x.JobCategory != nil {
+		return *x.JobCategory
+	}
+	return ""
+}
+
+func (x *Campaign) GetJob() string {
+	if x != nil && x.Job != nil {
+		return *x.Job
+	}
+	return ""
+}
+
+func (x *Campaign) GetScheduleAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ScheduleAt
+	}
+	return nil
+}
+
+func (x *Campaign) GetLineNumber() string {
+	if x != nil && x.LineNumber != nil {
+		return *x.LineNumber
+	}
+	return ""
+}
+
+func (x *Campaign) GetMediaUuid() string {
+	if x != nil && x.MediaUuid != nil {
+		return *x.MediaUuid
+	}
+	return ""
+}
+
+func (x *Campaign) GetPlatformSettingsId() uint64 {
+	if x != nil && x.PlatformSettingsId != nil {
+		return *x.PlatformSettingsId
+	}
+	return 0
+}
+
+func (x *Campaign) GetPlatformSettings() *PlatformSettings {
+	if x != nil {
+		return x.PlatformSettings
+	}
+	return nil
+}
+
+func (x *Campaign) GetPlatform() string {
+	if x != nil {
+		return x.Platform
+	}
+	return ""
+}
+
+func (x *Campaign) GetPlatformBasePrice() uint64 {
+	if x != nil && x.PlatformBasePrice != nil {
+		return *x.PlatformBasePrice
+	}
+	return 0
+}
+
+func (x *Campaign) GetBudget() uint64 {
+	if x != nil && x.Budget != nil {
+		return *x.Budget
+	}
+	return 0
+}
+
+func (x *Campaign) GetComment() string {
+	if x != nil && x.Comment != nil {
+		return *x.Comment
+	}
+	return ""
+}
+
+func (x *Campaign) GetNumAudiences() uint64 {
+	if x != nil && x.NumAudiences != nil {
+		return *x.NumAudiences
+	}
+	return 0
+}
+
+func (x *Campaign) GetBundleId() uint64 {
+	if x != nil && x.BundleId != nil {
+		return *x.BundleId
+	}
+	return 0
+}
+
+func (x *Campaign) GetPhase() string {
+	if x != nil && x.Phase != nil {
+		return *x.Phase
+	}
+	return ""
+}
+
+func (x *Campaign) GetAudienceGrades() []string {
+	if x != nil {
+		return x.AudienceGrades
+	}
+	return nil
+}
+
+func (x *Campaign) GetTargetAudienceExcelFileUuid() string {
+	if x != nil && x.TargetAudienceExcelFileUuid != nil {
+		return *x.TargetAudienceExcelFileUuid
+	}
+	return ""
+}
+
+func (x *Campaign) GetVariants() []*ContentVariant {
+	if x != nil {
+		return x.Variants
+	}
+	return nil
+}
+
+func (x *Campaign) GetSandbox() bool {
+	if x != nil {
+		return x.Sandbox
+	}
+	return false
+}
+
+type PlatformSettings struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Platform      string                 `protobuf:"bytes,2,opt,name=platform,proto3" json:"platform,omitempty"`
+	Name          *string                `protobuf:"bytes,3,opt,name=name,proto3,oneof" json:"name,omitempty"`
+	Description   *string                `protobuf:"bytes,4,opt,name=description,proto3,oneof" json:"description,omitempty"`
+	MultimediaId  *uint64                `protobuf:"varint,5,opt,name=multimedia_id,json=multimediaId,proto3,oneof" json:"multimedia_id,omitempty"`
+	Metadata      *structpb.Struct       `protobuf:"bytes,6,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	Status        string                 `protobuf:"bytes,7,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PlatformSettings) Reset() {
+	*x = PlatformSettings{}
+	mi := &file_internal_v1_campaign_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PlatformSettings) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlatformSettings) ProtoMessage() {}
+
+func (x *PlatformSettings) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_v1_campaign_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlatformSettings.ProtoReflect.Descriptor instead.
+func (*PlatformSettings) Descriptor() ([]byte, []int) {
+	return file_internal_v1_campaign_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *PlatformSettings) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *PlatformSettings) GetPlatform() string {
+	if x != nil {
+		return x.Platform
+	}
+	return ""
+}
+
+func (x *PlatformSettings) GetName() string {
+	if x != nil && x.Name != nil {
+		return *x.Name
+	}
+	return ""
+}
+
+func (x *PlatformSettings) GetDescription() string {
+	if x != nil && x.Description != nil {
+		return *x.Description
+	}
+	return ""
+}
+
+func (x *PlatformSettings) GetMultimediaId() uint64 {
+	if x != nil && x.MultimediaId != nil {
+		return *x.MultimediaId
+	}
+	return 0
+}
+
+func (x *PlatformSettings) GetMetadata() *structpb.Struct {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *PlatformSettings) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+type ContentVariant struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Label         string                 `protobuf:"bytes,1,opt,name=label,proto3" json:"label,omitempty"`
+	Content       string                 `protobuf:"bytes,2,opt,name=content,proto3" json:"content,omitempty"`
+	Weight        uint32                 `protobuf:"varint,3,opt,name=weight,proto3" json:"weight,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ContentVariant) Reset() {
+	*x = ContentVariant{}
+	mi := &file_internal_v1_campaign_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ContentVariant) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ContentVariant) ProtoMessage() {}
+
+func (x *ContentVariant) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_v1_campaign_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ContentVariant.ProtoReflect.Descriptor instead.
+func (*ContentVariant) Descriptor() ([]byte, []int) {
+	return file_internal_v1_campaign_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ContentVariant) GetLabel() string {
+	if x != nil {
+		return x.Label
+	}
+	return ""
+}
+
+func (x *ContentVariant) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+func (x *ContentVariant) GetWeight() uint32 {
+	if x != nil {
+		return x.Weight
+	}
+	return 0
+}
+
+type MoveCampaignRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CampaignId    uint64                 `protobuf:"varint,1,opt,name=campaign_id,json=campaignId,proto3" json:"campaign_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MoveCampaignRequest) Reset() {
+	*x = MoveCampaignRequest{}
+	mi := &file_internal_v1_campaign_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MoveCampaignRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MoveCampaignRequest) ProtoMessage() {}
+
+func (x *MoveCampaignRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_v1_campaign_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MoveCampaignRequest.ProtoReflect.Descriptor instead.
+func (*MoveCampaignRequest) Descriptor() ([]byte, []int) {
+	return file_internal_v1_campaign_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *MoveCampaignRequest) GetCampaignId() uint64 {
+	if x != nil {
+		return x.CampaignId
+	}
+	return 0
+}
+
+type MoveCampaignResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MoveCampaignResponse) Reset() {
+	*x = MoveCampaignResponse{}
+	mi := &file_internal_v1_campaign_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MoveCampaignResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MoveCampaignResponse) ProtoMessage() {}
+
+func (x *MoveCampaignResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_v1_campaign_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MoveCampaignResponse.ProtoReflect.Descriptor instead.
+func (*MoveCampaignResponse) Descriptor() ([]byte, []int) {
+	return file_internal_v1_campaign_proto_rawDescGZIP(), []int{6}
+}
+
+type UpdateStatisticsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CampaignId    uint64                 `protobuf:"varint,1,opt,name=campaign_id,json=campaignId,proto3" json:"campaign_id,omitempty"`
+	Statistics    *structpb.Struct       `protobuf:"bytes,2,opt,name=statistics,proto3" json:"statistics,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateStatisticsRequest) Reset() {
+	*x = UpdateStatisticsRequest{}
+	mi := &file_internal_v1_campaign_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateStatisticsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateStatisticsRequest) ProtoMessage() {}
+
+func (x *UpdateStatisticsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_v1_campaign_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateStatisticsRequest.ProtoReflect.Descriptor instead.
+func (*UpdateStatisticsRequest) Descriptor() ([]byte, []int) {
+	return file_internal_v1_campaign_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *UpdateStatisticsRequest) GetCampaignId() uint64 {
+	if x != nil {
+		return x.CampaignId
+	}
+	return 0
+}
+
+func (x *UpdateStatisticsRequest) GetStatistics() *structpb.Struct {
+	if x != nil {
+		return x.Statistics
+	}
+	return nil
+}
+
+type UpdateStatisticsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateStatisticsResponse) Reset() {
+	*x = UpdateStatisticsResponse{}
+	mi := &file_internal_v1_campaign_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateStatisticsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateStatisticsResponse) ProtoMessage() {}
+
+func (x *UpdateStatisticsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_v1_campaign_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateStatisticsResponse.ProtoReflect.Descriptor instead.
+func (*UpdateStatisticsResponse) Descriptor() ([]byte, []int) {
+	return file_internal_v1_campaign_proto_rawDescGZIP(), []int{8}
+}
+
+type PushAudienceUIDsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CampaignId    uint64                 `protobuf:"varint,1,opt,name=campaign_id,json=campaignId,proto3" json:"campaign_id,omitempty"`
+	Items         []*AudienceUID         `protobuf:"bytes,2,rep,name=items,proto3" json:"items,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PushAudienceUIDsRequest) Reset() {
+	*x = PushAudienceUIDsRequest{}
+	mi := &file_internal_v1_campaign_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PushAudienceUIDsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PushAudienceUIDsRequest) ProtoMessage() {}
+
+func (x *PushAudienceUIDsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_v1_campaign_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PushAudienceUIDsRequest.ProtoReflect.Descriptor instead.
+func (*PushAudienceUIDsRequest) Descriptor() ([]byte, []int) {
+	return file_internal_v1_campaign_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *PushAudienceUIDsRequest) GetCampaignId() uint64 {
+	if x != nil {
+		return x.CampaignId
+	}
+	return 0
+}
+
+func (x *PushAudienceUIDsRequest) GetItems() []*AudienceUID {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+type AudienceUID struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Uid   string                 `protobuf:"bytes,1,opt,name=uid,proto3" json:"uid,omitempty"`
+	// empty when the campaign has no short link
+	Code          string `protobuf:"bytes,2,opt,name=code,proto3" json:"code,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AudienceUID) Reset() {
+	*x = AudienceUID{}
+	mi := &file_internal_v1_campaign_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AudienceUID) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AudienceUID) ProtoMessage() {}
+
+func (x *AudienceUID) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_v1_campaign_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AudienceUID.ProtoReflect.Descriptor instead.
+func (*AudienceUID) Descriptor() ([]byte, []int) {
+	return file_internal_v1_campaign_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *AudienceUID) GetUid() string {
+	if x != nil {
+		return x.Uid
+	}
+	return ""
+}
+
+func (x *AudienceUID) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+type PushAudienceUIDsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PushAudienceUIDsResponse) Reset() {
+	*x = PushAudienceUIDsResponse{}
+	mi := &file_internal_v1_campaign_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PushAudienceUIDsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PushAudienceUIDsResponse) ProtoMessage() {}
+
+func (x *PushAudienceUIDsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_v1_campaign_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PushAudienceUIDsResponse.ProtoReflect.Descriptor instead.
+func (*PushAudienceUIDsResponse) Descriptor() ([]byte, []int) {
+	return file_internal_v1_campaign_proto_rawDescGZIP(), []int{11}
+}
+
+var File_internal_v1_campaign_proto protoreflect.FileDescriptor
+
+const file_internal_v1_campaign_proto_rawDesc = "" +
+	"\n" +
+	"\x1ainternal/v1/campaign.proto\x12\x12yamata.internal.v1\x1a\x1cgoogle/protobuf/struct.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"7\n" +
+	"\x19ListReadyCampaignsRequest\x12\x1a\n" +
+	"\bplatform\x18\x01 \x01(\tR\bplatform\"X\n" +
+	"\x1aListReadyCampaignsResponse\x12:\n" +
+	"\tcampaigns\x18\x01 \x03(\v2\x1c.yamata.internal.v1.CampaignR\tcampaigns\"\xa5\f\n" +
+	"\bCampaign\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x1f\n" +
+	"\vcustomer_id\x18\x02 \x01(\x04R\n" +
+	"customerId\x12\x16\n" +
+	"\x06hidden\x18\x03 \x01(\bR\x06hidden\x12\x16\n" +
+	"\x06status\x18\x04 \x01(\tR\x06status\x129\n" +
+	"\n" +
+	"created_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x12\x19\n" +
+	"\x05title\x18\a \x01(\tH\x00R\x05title\x88\x01\x01\x12\x1b\n" +
+	"\x06level1\x18\b \x01(\tH\x01R\x06level1\x88\x01\x01\x12\x18\n" +
+	"\alevel2s\x18\t \x03(\tR\alevel2s\x12\x18\n" +
+	"\alevel3s\x18\n" +
+	" \x03(\tR\alevel3s\x12\x12\n" +
+	"\x04tags\x18\v \x03(\tR\x04tags\x12\x15\n" +
+	"\x03sex\x18\f \x01(\tH\x02R\x03sex\x88\x01\x01\x12\x12\n" +
+	"\x04city\x18\r \x03(\tR\x04city\x12\x1c\n" +
+	"\aad_link\x18\x0e \x01(\tH\x03R\x06adLink\x88\x01\x01\x12\x1d\n" +
+	"\acontent\x18\x0f \x01(\tH\x04R\acontent\x88\x01\x01\x12/\n" +
+	"\x11short_link_domain\x18\x10 \x01(\tH\x05R\x0fshortLinkDomain\x88\x01\x01\x12&\n" +
+	"\fjob_category\x18\x11 \x01(\tH\x06R\vjobCategory\x88\x01\x01\x12\x15\n" +
+	"\x03job\x18\x12 \x01(\tH\aR\x03job\x88\x01\x01\x12;\n" +
+	"\vschedule_at\x18\x13 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"scheduleAt\x12$\n" +
+	"\vline_number\x18\x14 \x01(\tH\bR\n" +
+	"lineNumber\x88\x01\x01\x12\"\n" +
+	"\n" +
+	"media_uuid\x18\x15 \x01(\tH\tR\tmediaUuid\x88\x01\x01\x125\n" +
+	"\x14platform_settings_id\x18\x16 \x01(\x04H\n" +
+	"R\x12platformSettingsId\x88\x01\x01\x12Q\n" +
+	"\x11platform_settings\x18\x17 \x01(\v2$.yamata.internal.v1.PlatformSettingsR\x10platformSettings\x12\x1a\n" +
+	"\bplatform\x18\x18 \x01(\tR\bplatform\x123\n" +
+	"\x13platform_base_price\x18\x19 \x01(\x04H\vR\x11platformBasePrice\x88\x01\x01\x12\x1b\n" +
+	"\x06budget\x18\x1a \x01(\x04H\fR\x06budget\x88\x01\x01\x12\x1d\n" +
+	"\acomment\x18\x1b \x01(\tH\rR\acomment\x88\x01\x01\x12(\n" +
+	"\rnum_audiences\x18\x1c \x01(\x04H\x0eR\fnumAudiences\x88\x01\x01\x12 \n" +
+	"\tbundle_id\x18\x1d \x01(\x04H\x0fR\bbundleId\x88\x01\x01\x12\x19\n" +
+	"\x05phase\x18\x1e \x01(\tH\x10R\x05phase\x88\x01\x01\x12'\n" +
+	"\x0faudience_grades\x18\x1f \x03(\tR\x0eaudienceGrades\x12I\n" +
+	"\x1ftarget_audience_excel_file_uuid\x18  \x01(\tH\x11R\x1btargetAudienceExcelFileUuid\x88\x01\x01\x12>\n" +
+	"\bvariants\x18! \x03(\v2\".yamata.internal.v1.ContentVariantR\bvariants\x12\x18\n" +
+	"\asandbox\x18\" \x01(\bR\asandboxB\b\n" +
+	"\x06_titleB\t\n" +
+	"\a_level1B\x06\n" +
+	"\x04_sexB\n" +
+	"\n" +
+	"\b_ad_linkB\n" +
+	"\n" +
+	"\b_contentB\x14\n" +
+	"\x12_short_link_domainB\x0f\n" +
+	"\r_job_categoryB\x06\n" +
+	"\x04_jobB\x0e\n" +
+	"\f_line_numberB\r\n" +
+	"\v_media_uuidB\x17\n" +
+	"\x15_platform_settings_idB\x16\n" +
+	"\x14_platform_base_priceB\t\n" +
+	"\a_budgetB\n" +
+	"\n" +
+	"\b_commentB\x10\n" +
+	"\x0e_num_audiencesB\f\n" +
+	"\n" +
+	"_bundle_idB\b\n" +
+	"\x06_phaseB\"\n" +
+	" _target_audience_excel_file_uuid\"\xa0\x02\n" +
+	"\x10PlatformSettings\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x1a\n" +
+	"\bplatform\x18\x02 \x01(\tR\bplatform\x12\x17\n" +
+	"\x04name\x18\x03 \x01(\tH\x00R\x04name\x88\x01\x01\x12%\n" +
+	"\vdescription\x18\x04 \x01(\tH\x01R\vdescription\x88\x01\x01\x12(\n" +
+	"\rmultimedia_id\x18\x05 \x01(\x04H\x02R\fmultimediaId\x88\x01\x01\x123\n" +
+	"\bmetadata\x18\x06 \x01(\v2\x17.google.protobuf.StructR\bmetadata\x12\x16\n" +
+	"\x06status\x18\a \x01(\tR\x06statusB\a\n" +
+	"\x05_nameB\x0e\n" +
+	"\f_descriptionB\x10\n" +
+	"\x0e_multimedia_id\"X\n" +
+	"\x0eContentVariant\x12\x14\n" +
+	"\x05label\x18\x01 \x01(\tR\x05label\x12\x18\n" +
+	"\acontent\x18\x02 \x01(\tR\acontent\x12\x16\n" +
+	"\x06weight\x18\x03 \x01(\rR\x06weight\"6\n" +
+	"\x13MoveCampaignRequest\x12\x1f\n" +
+	"\vcampaign_id\x18\x01 \x01(\x04R\n" +
+	"campaignId\"\x16\n" +
+	"\x14MoveCampaignResponse\"s\n" +
+	"\x17UpdateStatisticsRequest\x12\x1f\n" +
+	"\vcampaign_id\x18\x01 \x01(\x04R\n" +
+	"campaignId\x127\n" +
+	"\n" +
+	"statistics\x18\x02 \x01(\v2\x17.google.protobuf.StructR\n" +
+	"statistics\"\x1a\n" +
+	"\x18UpdateStatisticsResponse\"q\n" +
+	"\x17PushAudienceUIDsRequest\x12\x1f\n" +
+	"\vcampaign_id\x18\x01 \x01(\x04R\n" +
+	"campaignId\x125\n" +
+	"\x05items\x18\x02 \x03(\v2\x1f.yamata.internal.v1.AudienceUIDR\x05items\"3\n" +
+	"\vAudienceUID\x12\x10\n" +
+	"\x03uid\x18\x01 \x01(\tR\x03uid\x12\x12\n" +
+	"\x04code\x18\x02 \x01(\tR\x04code\"\x1a\n" +
+	"\x18PushAudienceUIDsResponse2\xb6\x04\n" +
+	"\x18CampaignLifecycleService\x12s\n" +
+	"\x12ListReadyCampaigns\x12-.yamata.internal.v1.ListReadyCampaignsRequest\x1a..yamata.internal.v1.ListReadyCampaignsResponse\x12b\n" +
+	"\rMoveToRunning\x12'.yamata.internal.v1.MoveCampaignRequest\x1a(.yamata.internal.v1.MoveCampaignResponse\x12c\n" +
+	"\x0eMoveToExecuted\x12'.yamata.internal.v1.MoveCampaignRequest\x1a(.yamata.internal.v1.MoveCampaignResponse\x12m\n" +
+	"\x10UpdateStatistics\x12+.yamata.internal.v1.UpdateStatisticsRequest\x1a,.yamata.internal.v1.UpdateStatisticsResponse\x12m\n" +
+	"\x10PushAudienceUIDs\x12+.yamata.internal.v1.PushAudienceUIDsRequest\x1a,.yamata.internal.v1.PushAudienceUIDsResponseBGZEgithub.com/amirphl/Yamata-no-Orochi/app/grpcapi/internalv1;internalv1b\x06proto3"
+
+var (
+	file_internal_v1_campaign_proto_rawDescOnce sync.Once
+	file_internal_v1_campaign_proto_rawDescData []byte
+)
+
+func file_internal_v1_campaign_proto_rawDescGZIP() []byte {
+	file_internal_v1_campaign_proto_rawDescOnce.Do(func() {
+		file_internal_v1_campaign_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_internal_v1_campaign_proto_rawDesc), len(file_internal_v1_campaign_proto_rawDesc)))
+	})
+	return file_internal_v1_campaign_proto_rawDescData
+}
+
+var file_internal_v1_campaign_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_internal_v1_campaign_proto_goTypes = []any{
+	(*ListReadyCampaignsRequest)(nil),  // 0: yamata.internal.v1.ListReadyCampaignsRequest
+	(*ListReadyCampaignsResponse)(nil), // 1: yamata.internal.v1.ListReadyCampaignsResponse
+	(*Campaign)(nil),                   // 2: yamata.internal.v1.Campaign
+	(*PlatformSettings)(nil),           // 3: yamata.internal.v1.PlatformSettings
+	(*ContentVariant)(nil),             // 4: yamata.internal.v1.ContentVariant
+	(*MoveCampaignRequest)(nil),        // 5: yamata.internal.v1.MoveCampaignRequest
+	(*MoveCampaignResponse)(nil),       // 6: yamata.internal.v1.MoveCampaignResponse
+	(*UpdateStatisticsRequest)(nil),    // 7: yamata.internal.v1.UpdateStatisticsRequest
+	(*UpdateStatisticsResponse)(nil),   // 8: yamata.internal.v1.UpdateStatisticsResponse
+	(*PushAudienceUIDsRequest)(nil),    // 9: yamata.internal.v1.PushAudienceUIDsRequest
+	(*AudienceUID)(nil),                // 10: yamata.internal.v1.AudienceUID
+	(*PushAudienceUIDsResponse)(nil),   // 11: yamata.internal.v1.PushAudienceUIDsResponse
+	(*timestamppb.Timestamp)(nil),      // 12: google.protobuf.Timestamp
+	(*structpb.Struct)(nil),            // 13: google.protobuf.Struct
+}
+var file_internal_v1_campaign_proto_depIdxs = []int32{
+	2,  // 0: yamata.internal.v1.ListReadyCampaignsResponse.campaigns:type_name -> yamata.internal.v1.Campaign
+	12, // 1: yamata.internal.v1.Campaign.created_at:type_name -> google.protobuf.Timestamp
+	12, // 2: yamata.internal.v1.Campaign.updated_at:type_name -> google.protobuf.Timestamp
+	12, // 3: yamata.internal.v1.Campaign.schedule_at:type_name -> google.protobuf.Timestamp
+	3,  // 4: yamata.internal.v1.Campaign.platform_settings:type_name -> yamata.internal.v1.PlatformSettings
+	4,  // 5: yamata.internal.v1.Campaign.variants:type_name -> yamata.internal.v1.ContentVariant
+	13, // 6: yamata.internal.v1.PlatformSettings.metadata:type_name -> google.protobuf.Struct
+	13, // 7: yamata.internal.v1.UpdateStatisticsRequest.statistics:type_name -> google.protobuf.Struct
+	10, // 8: yamata.internal.v1.PushAudienceUIDsRequest.items:type_name -> yamata.internal.v1.AudienceUID
+	0,  // 9: yamata.internal.v1.CampaignLifecycleService.ListReadyCampaigns:input_type -> yamata.internal.v1.ListReadyCampaignsRequest
+	5,  // 10: yamata.internal.v1.CampaignLifecycleService.MoveToRunning:input_type -> yamata.internal.v1.MoveCampaignRequest
+	5,  // 11: yamata.internal.v1.CampaignLifecycleService.MoveToExecuted:input_type -> yamata.internal.v1.MoveCampaignRequest
+	7,  // 12: yamata.internal.v1.CampaignLifecycleService.UpdateStatistics:input_type -> yamata.internal.v1.UpdateStatisticsRequest
+	9,  // 13: yamata.internal.v1.CampaignLifecycleService.PushAudienceUIDs:input_type -> yamata.internal.v1.PushAudienceUIDsRequest
+	1,  // 14: yamata.internal.v1.CampaignLifecycleService.ListReadyCampaigns:output_type -> yamata.internal.v1.ListReadyCampaignsResponse
+	6,  // 15: yamata.internal.v1.CampaignLifecycleService.MoveToRunning:output_type -> yamata.internal.v1.MoveCampaignResponse
+	6,  // 16: yamata.internal.v1.CampaignLifecycleService.MoveToExecuted:output_type -> yamata.internal.v1.MoveCampaignResponse
+	8,  // 17: yamata.internal.v1.CampaignLifecycleService.UpdateStatistics:output_type -> yamata.internal.v1.UpdateStatisticsResponse
+	11, // 18: yamata.internal.v1.CampaignLifecycleService.PushAudienceUIDs:output_type -> yamata.internal.v1.PushAudienceUIDsResponse
+	14, // [14:19] is the sub-list for method output_type
+	9,  // [9:14] is the sub-list for method input_type
+	9,  // [9:9] is the sub-list for extension type_name
+	9,  // [9:9] is the sub-list for extension extendee
+	0,  // [0:9] is the sub-list for field type_name
+}
+
+func init() { file_internal_v1_campaign_proto_init() }
+func file_internal_v1_campaign_proto_init() {
+	if File_internal_v1_campaign_proto != nil {
+		return
+	}
+	file_internal_v1_campaign_proto_msgTypes[2].OneofWrappers = []any{}
+	file_internal_v1_campaign_proto_msgTypes[3].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_internal_v1_campaign_proto_rawDesc), len(file_internal_v1_campaign_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   12,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_internal_v1_campaign_proto_goTypes,
+		DependencyIndexes: file_internal_v1_campaign_proto_depIdxs,
+		MessageInfos:      file_internal_v1_campaign_proto_msgTypes,
+	}.Build()
+	File_internal_v1_campaign_proto = out.File
+	file_internal_v1_campaign_proto_goTypes = nil
+	file_internal_v1_campaign_proto_depIdxs = nil
+}
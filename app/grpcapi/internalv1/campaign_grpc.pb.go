@@ -0,0 +1,292 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             (unknown)
+// source: internal/v1/campaign.proto
+
+package internalv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	CampaignLifecycleService_ListReadyCampaigns_FullMethodName = "/yamata.internal.v1.CampaignLifecycleService/ListReadyCampaigns"
+	CampaignLifecycleService_MoveToRunning_FullMethodName      = "/yamata.internal.v1.CampaignLifecycleService/MoveToRunning"
+	CampaignLifecycleService_MoveToExecuted_FullMethodName     = "/yamata.internal.v1.CampaignLifecycleService/MoveToExecuted"
+	CampaignLifecycleService_UpdateStatistics_FullMethodName   = "/yamata.internal.v1.CampaignLifecycleService/UpdateStatistics"
+	CampaignLifecycleService_PushAudienceUIDs_FullMethodName   = "/yamata.internal.v1.CampaignLifecycleService/PushAudienceUIDs"
+)
+
+// CampaignLifecycleServiceClient is the client API for CampaignLifecycleService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// CampaignLifecycleService moves campaigns through execution on behalf of
+// the schedulers and the bot.
+type CampaignLifecycleServiceClient interface {
+	// ListReadyCampaigns lists the campaigns ready to be sent, optionally of
+	// one platform only.
+	ListReadyCampaigns(ctx context.Context, in *ListReadyCampaignsRequest, opts ...grpc.CallOption) (*ListReadyCampaignsResponse, error)
+	// MoveToRunning marks a campaign as running.
+	MoveToRunning(ctx context.Context, in *MoveCampaignRequest, opts ...grpc.CallOption) (*MoveCampaignResponse, error)
+	// MoveToExecuted marks a campaign as executed.
+	MoveToExecuted(ctx context.Context, in *MoveCampaignRequest, opts ...grpc.CallOption) (*MoveCampaignResponse, error)
+	// UpdateStatistics replaces the aggregated statistics of a campaign.
+	UpdateStatistics(ctx context.Context, in *UpdateStatisticsRequest, opts ...grpc.CallOption) (*UpdateStatisticsResponse, error)
+	// PushAudienceUIDs stores a batch of audience uid/code pairs of a campaign.
+	PushAudienceUIDs(ctx context.Context, in *PushAudienceUIDsRequest, opts ...grpc.CallOption) (*PushAudienceUIDsResponse, error)
+}
+
+type campaignLifecycleServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewCampaignLifecycleServiceClient(cc grpc.ClientConnInterface) CampaignLifecycleServiceClient {
+	return &campaignLifecycleServiceClient{cc}
+}
+
+func (c *campaignLifecycleServiceClient) ListReadyCampaigns(ctx context.Context, in *ListReadyCampaignsRequest, opts ...grpc.CallOption) (*ListReadyCampaignsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListReadyCampaignsResponse)
+	err := c.cc.Invoke(ctx, CampaignLifecycleService_ListReadyCampaigns_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *campaignLifecycleServiceClient) MoveToRunning(ctx context.Context, in *MoveCampaignRequest, opts ...grpc.CallOption) (*MoveCampaignResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(MoveCampaignResponse)
+	err := c.cc.Invoke(ctx, CampaignLifecycleService_MoveToRunning_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *campaignLifecycleServiceClient) MoveToExecuted(ctx context.Context, in *MoveCampaignRequest, opts ...grpc.CallOption) (*MoveCampaignResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(MoveCampaignResponse)
+	err := c.cc.Invoke(ctx, CampaignLifecycleService_MoveToExecuted_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *campaignLifecycleServiceClient) UpdateStatistics(ctx context.Context, in *UpdateStatisticsRequest, opts ...grpc.CallOption) (*UpdateStatisticsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateStatisticsResponse)
+	err := c.cc.Invoke(ctx, CampaignLifecycleService_UpdateStatistics_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *campaignLifecycleServiceClient) PushAudienceUIDs(ctx context.Context, in *PushAudienceUIDsRequest, opts ...grpc.CallOption) (*PushAudienceUIDsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PushAudienceUIDsResponse)
+	err := c.cc.Invoke(ctx, CampaignLifecycleService_PushAudienceUIDs_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CampaignLifecycleServiceServer is the server API for CampaignLifecycleService service.
+// All implementations must embed UnimplementedCampaignLifecycleServiceServer
+// for forward compatibility.
+//
+// CampaignLifecycleService moves campaigns through execution on behalf of
+// the schedulers and the bot.
+type CampaignLifecycleServiceServer interface {
+	// ListReadyCampaigns lists the campaigns ready to be sent, optionally of
+	// one platform only.
+	ListReadyCampaigns(context.Context, *ListReadyCampaignsRequest) (*ListReadyCampaignsResponse, error)
+	// MoveToRunning marks a campaign as running.
+	MoveToRunning(context.Context, *MoveCampaignRequest) (*MoveCampaignResponse, error)
+	// MoveToExecuted marks a campaign as executed.
+	MoveToExecuted(context.Context, *MoveCampaignRequest) (*MoveCampaignResponse, error)
+	// UpdateStatistics replaces the aggregated statistics of a campaign.
+	UpdateStatistics(context.Context, *UpdateStatisticsRequest) (*UpdateStatisticsResponse, error)
+	// PushAudienceUIDs stores a batch of audience uid/code pairs of a campaign.
+	PushAudienceUIDs(context.Context, *PushAudienceUIDsRequest) (*PushAudienceUIDsResponse, error)
+	mustEmbedUnimplementedCampaignLifecycleServiceServer()
+}
+
+// UnimplementedCampaignLifecycleServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedCampaignLifecycleServiceServer struct{}
+
+func (UnimplementedCampaignLifecycleServiceServer) ListReadyCampaigns(context.Context, *ListReadyCampaignsRequest) (*ListReadyCampaignsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListReadyCampaigns not implemented")
+}
+func (UnimplementedCampaignLifecycleServiceServer) MoveToRunning(context.Context, *MoveCampaignRequest) (*MoveCampaignResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method MoveToRunning not implemented")
+}
+func (UnimplementedCampaignLifecycleServiceServer) MoveToExecuted(context.Context, *MoveCampaignRequest) (*MoveCampaignResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method MoveToExecuted not implemented")
+}
+func (UnimplementedCampaignLifecycleServiceServer) UpdateStatistics(context.Context, *UpdateStatisticsRequest) (*UpdateStatisticsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method UpdateStatistics not implemented")
+}
+func (UnimplementedCampaignLifecycleServiceServer) PushAudienceUIDs(context.Context, *PushAudienceUIDsRequest) (*PushAudienceUIDsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method PushAudienceUIDs not implemented")
+}
+func (UnimplementedCampaignLifecycleServiceServer) mustEmbedUnimplementedCampaignLifecycleServiceServer() {
+}
+func (UnimplementedCampaignLifecycleServiceServer) testEmbeddedByValue() {}
+
+// UnsafeCampaignLifecycleServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to CampaignLifecycleServiceServer will
+// result in compilation errors.
+type UnsafeCampaignLifecycleServiceServer interface {
+	mustEmbedUnimplementedCampaignLifecycleServiceServer()
+}
+
+func RegisterCampaignLifecycleServiceServer(s grpc.ServiceRegistrar, srv CampaignLifecycleServiceServer) {
+	// If the following call panics, it indicates UnimplementedCampaignLifecycleServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&CampaignLifecycleService_ServiceDesc, srv)
+}
+
+func _CampaignLifecycleService_ListReadyCampaigns_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListReadyCampaignsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CampaignLifecycleServiceServer).ListReadyCampaigns(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CampaignLifecycleService_ListReadyCampaigns_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CampaignLifecycleServiceServer).ListReadyCampaigns(ctx, req.(*ListReadyCampaignsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CampaignLifecycleService_MoveToRunning_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MoveCampaignRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CampaignLifecycleServiceServer).MoveToRunning(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CampaignLifecycleService_MoveToRunning_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CampaignLifecycleServiceServer).MoveToRunning(ctx, req.(*MoveCampaignRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CampaignLifecycleService_MoveToExecuted_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MoveCampaignRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CampaignLifecycleServiceServer).MoveToExecuted(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CampaignLifecycleService_MoveToExecuted_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CampaignLifecycleServiceServer).MoveToExecuted(ctx, req.(*MoveCampaignRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CampaignLifecycleService_UpdateStatistics_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateStatisticsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CampaignLifecycleServiceServer).UpdateStatistics(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CampaignLifecycleService_UpdateStatistics_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CampaignLifecycleServiceServer).UpdateStatistics(ctx, req.(*UpdateStatisticsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CampaignLifecycleService_PushAudienceUIDs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PushAudienceUIDsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CampaignLifecycleServiceServer).PushAudienceUIDs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CampaignLifecycleService_PushAudienceUIDs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CampaignLifecycleServiceServer).PushAudienceUIDs(ctx, req.(*PushAudienceUIDsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// CampaignLifecycleService_ServiceDesc is the grpc.ServiceDesc for CampaignLifecycleService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var CampaignLifecycleService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "yamata.internal.v1.CampaignLifecycleService",
+	HandlerType: (*CampaignLifecycleServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListReadyCampaigns",
+			Handler:    _CampaignLifecycleService_ListReadyCampaigns_Handler,
+		},
+		{
+			MethodName: "MoveToRunning",
+			Handler:    _CampaignLifecycleService_MoveToRunning_Handler,
+		},
+		{
+			MethodName: "MoveToExecuted",
+			Handler:    _CampaignLifecycleService_MoveToExecuted_Handler,
+		},
+		{
+			MethodName: "UpdateStatistics",
+			Handler:    _CampaignLifecycleService_UpdateStatistics_Handler,
+		},
+		{
+			MethodName: "PushAudienceUIDs",
+			Handler:    _CampaignLifecycleService_PushAudienceUIDs_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "internal/v1/campaign.proto",
+}
@@ -0,0 +1,558 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: internal/v1/short_link.proto
+
+package internalv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type AllocateShortLinksRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	CampaignId      uint64                 `protobuf:"varint,1,opt,name=campaign_id,json=campaignId,proto3" json:"campaign_id,omitempty"`
+	Items           []*PhoneAdLink         `protobuf:"bytes,2,rep,name=items,proto3" json:"items,omitempty"`
+	ShortLinkDomain string                 `protobuf:"bytes,3,opt,name=short_link_domain,json=shortLinkDomain,proto3" json:"short_link_domain,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *AllocateShortLinksRequest) Reset() {
+	*x = AllocateShortLinksRequest{}
+	mi := &file_internal_v1_short_link_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AllocateShortLinksRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AllocateShortLinksRequest) ProtoMessage() {}
+
+func (x *AllocateShortLinksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_v1_short_link_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AllocateShortLinksRequest.ProtoReflect.Descriptor instead.
+func (*AllocateShortLinksRequest) Descriptor() ([]byte, []int) {
+	return file_internal_v1_short_link_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *AllocateShortLinksRequest) GetCampaignId() uint64 {
+	if x != nil {
+		return x.CampaignId
+	}
+	return 0
+}
+
+func (x *AllocateShortLinksRequest) GetItems() []*PhoneAdLink {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+func (x *AllocateShortLinksRequest) GetShortLinkDomain() string {
+	if x != nil {
+		return x.ShortLinkDomain
+	}
+	return ""
+}
+
+type PhoneAdLink struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Phone         string                 `protobuf:"bytes,1,opt,name=phone,proto3" json:"phone,omitempty"`
+	AdLink        *string                `protobuf:"bytes,2,opt,name=ad_link,json=adLink,proto3,oneof" json:"ad_link,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PhoneAdLink) Reset() {
+	*x = PhoneAdLink{}
+	mi := &file_internal_v1_short_link_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PhoneAdLink) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PhoneAdLink) ProtoMessage() {}
+
+func (x *PhoneAdLink) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_v1_short_link_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PhoneAdLink.ProtoReflect.Descriptor instead.
+func (*PhoneAdLink) Descriptor() ([]byte, []int) {
+	return file_internal_v1_short_link_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *PhoneAdLink) GetPhone() string {
+	if x != nil {
+		return x.Phone
+	}
+	return ""
+}
+
+func (x *PhoneAdLink) GetAdLink() string {
+	if x != nil && x.AdLink != nil {
+		return *x.AdLink
+	}
+	return ""
+}
+
+type AllocateShortLinksResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Codes         []string               `protobuf:"bytes,1,rep,name=codes,proto3" json:"codes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AllocateShortLinksResponse) Reset() {
+	*x = AllocateShortLinksResponse{}
+	mi := &file_internal_v1_short_link_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AllocateShortLinksResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AllocateShortLinksResponse) ProtoMessage() {}
+
+func (x *AllocateShortLinksResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_v1_short_link_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AllocateShortLinksResponse.ProtoReflect.Descriptor instead.
+func (*AllocateShortLinksResponse) Descriptor() ([]byte, []int) {
+	return file_internal_v1_short_link_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *AllocateShortLinksResponse) GetCodes() []string {
+	if x != nil {
+		return x.Codes
+	}
+	return nil
+}
+
+type CreateShortLinksRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Items         []*NewShortLink        `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateShortLinksRequest) Reset() {
+	*x = CreateShortLinksRequest{}
+	mi := &file_internal_v1_short_link_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateShortLinksRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateShortLinksRequest) ProtoMessage() {}
+
+func (x *CreateShortLinksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_v1_short_link_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateShortLinksRequest.ProtoReflect.Descriptor instead.
+func (*CreateShortLinksRequest) Descriptor() ([]byte, []int) {
+	return file_internal_v1_short_link_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *CreateShortLinksRequest) GetItems() []*NewShortLink {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+type NewShortLink struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Uid           string                 `protobuf:"bytes,1,opt,name=uid,proto3" json:"uid,omitempty"`
+	CampaignId    *uint64                `protobuf:"varint,2,opt,name=campaign_id,json=campaignId,proto3,oneof" json:"campaign_id,omitempty"`
+	ClientId      *uint64                `protobuf:"varint,3,opt,name=client_id,json=clientId,proto3,oneof" json:"client_id,omitempty"`
+	PhoneNumber   *string                `protobuf:"bytes,4,opt,name=phone_number,json=phoneNumber,proto3,oneof" json:"phone_number,omitempty"`
+	LongLink      string                 `protobuf:"bytes,5,opt,name=long_link,json=longLink,proto3" json:"long_link,omitempty"`
+	ShortLink     string                 `protobuf:"bytes,6,opt,name=short_link,json=shortLink,proto3" json:"short_link,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NewShortLink) Reset() {
+	*x = NewShortLink{}
+	mi := &file_internal_v1_short_link_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NewShortLink) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NewShortLink) ProtoMessage() {}
+
+func (x *NewShortLink) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_v1_short_link_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NewShortLink.ProtoReflect.Descriptor instead.
+func (*NewShortLink) Descriptor() ([]byte, []int) {
+	return file_internal_v1_short_link_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *NewShortLink) GetUid() string {
+	if x != nil {
+		return x.Uid
+	}
+	return ""
+}
+
+func (x *NewShortLink) GetCampaignId() uint64 {
+	if x != nil && x.CampaignId != nil {
+		return *x.CampaignId
+	}
+	return 0
+}
+
+func (x *NewShortLink) GetClientId() uint64 {
+	if x != nil && x.ClientId != nil {
+		return *x.ClientId
+	}
+	return 0
+}
+
+func (x *NewShortLink) GetPhoneNumber() string {
+	if x != nil && x.PhoneNumber != nil {
+		return *x.PhoneNumber
+	}
+	return ""
+}
+
+func (x *NewShortLink) GetLongLink() string {
+	if x != nil {
+		return x.LongLink
+	}
+	return ""
+}
+
+func (x *NewShortLink) GetShortLink() string {
+	if x != nil {
+		return x.ShortLink
+	}
+	return ""
+}
+
+type CreateShortLinksResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Items         []*ShortLink           `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateShortLinksResponse) Reset() {
+	*x = CreateShortLinksResponse{}
+	mi := &file_internal_v1_short_link_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateShortLinksResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateShortLinksResponse) ProtoMessage() {}
+
+func (x *CreateShortLinksResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_v1_short_link_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateShortLinksResponse.ProtoReflect.Descriptor instead.
+func (*CreateShortLinksResponse) Descriptor() ([]byte, []int) {
+	return file_internal_v1_short_link_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *CreateShortLinksResponse) GetItems() []*ShortLink {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+type ShortLink struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Uid           string                 `protobuf:"bytes,2,opt,name=uid,proto3" json:"uid,omitempty"`
+	CampaignId    *uint64                `protobuf:"varint,3,opt,name=campaign_id,json=campaignId,proto3,oneof" json:"campaign_id,omitempty"`
+	ClientId      *uint64                `protobuf:"varint,4,opt,name=client_id,json=clientId,proto3,oneof" json:"client_id,omitempty"`
+	PhoneNumber   *string                `protobuf:"bytes,5,opt,name=phone_number,json=phoneNumber,proto3,oneof" json:"phone_number,omitempty"`
+	LongLink      string                 `protobuf:"bytes,6,opt,name=long_link,json=longLink,proto3" json:"long_link,omitempty"`
+	ShortLink     string                 `protobuf:"bytes,7,opt,name=short_link,json=shortLink,proto3" json:"short_link,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ShortLink) Reset() {
+	*x = ShortLink{}
+	mi := &file_internal_v1_short_link_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ShortLink) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ShortLink) ProtoMessage() {}
+
+func (x *ShortLink) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_v1_short_link_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ShortLink.ProtoReflect.Descriptor instead.
+func (*ShortLink) Descriptor() ([]byte, []int) {
+	return file_internal_v1_short_link_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ShortLink) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *ShortLink) GetUid() string {
+	if x != nil {
+		return x.Uid
+	}
+	return ""
+}
+
+func (x *ShortLink) GetCampaignId() uint64 {
+	if x != nil && x.CampaignId != nil {
+		return *x.CampaignId
+	}
+	return 0
+}
+
+func (x *ShortLink) GetClientId() uint64 {
+	if x != nil && x.ClientId != nil {
+		return *x.ClientId
+	}
+	return 0
+}
+
+func (x *ShortLink) GetPhoneNumber() string {
+	if x != nil && x.PhoneNumber != nil {
+		return *x.PhoneNumber
+	}
+	return ""
+}
+
+func (x *ShortLink) GetLongLink() string {
+	if x != nil {
+		return x.LongLink
+	}
+	return ""
+}
+
+func (x *ShortLink) GetShortLink() string {
+	if x != nil {
+		return x.ShortLink
+	}
+	return ""
+}
+
+var File_internal_v1_short_link_proto protoreflect.FileDescriptor
+
+const file_internal_v1_short_link_proto_rawDesc = "" +
+	"\n" +
+	"\x1cinternal/v1/short_link.proto\x12\x12yamata.internal.v1\"\x9f\x01\n" +
+	"\x19AllocateShortLinksRequest\x12\x1f\n" +
+	"\vcampaign_id\x18\x01 \x01(\x04R\n" +
+	"campaignId\x125\n" +
+	"\x05items\x18\x02 \x03(\v2\x1f.yamata.internal.v1.PhoneAdLinkR\x05items\x12*\n" +
+	"\x11short_link_domain\x18\x03 \x01(\tR\x0fshortLinkDomain\"M\n" +
+	"\vPhoneAdLink\x12\x14\n" +
+	"\x05phone\x18\x01 \x01(\tR\x05phone\x12\x1c\n" +
+	"\aad_link\x18\x02 \x01(\tH\x00R\x06adLink\x88\x01\x01B\n" +
+	"\n" +
+	"\b_ad_link\"2\n" +
+	"\x1aAllocateShortLinksResponse\x12\x14\n" +
+	"\x05codes\x18\x01 \x03(\tR\x05codes\"Q\n" +
+	"\x17CreateShortLinksRequest\x126\n" +
+	"\x05items\x18\x01 \x03(\v2 .yamata.internal.v1.NewShortLinkR\x05items\"\xfb\x01\n" +
+	"\fNewShortLink\x12\x10\n" +
+	"\x03uid\x18\x01 \x01(\tR\x03uid\x12$\n" +
+	"\vcampaign_id\x18\x02 \x01(\x04H\x00R\n" +
+	"campaignId\x88\x01\x01\x12 \n" +
+	"\tclient_id\x18\x03 \x01(\x04H\x01R\bclientId\x88\x01\x01\x12&\n" +
+	"\fphone_number\x18\x04 \x01(\tH\x02R\vphoneNumber\x88\x01\x01\x12\x1b\n" +
+	"\tlong_link\x18\x05 \x01(\tR\blongLink\x12\x1d\n" +
+	"\n" +
+	"short_link\x18\x06 \x01(\tR\tshortLinkB\x0e\n" +
+	"\f_campaign_idB\f\n" +
+	"\n" +
+	"_client_idB\x0f\n" +
+	"\r_phone_number\"O\n" +
+	"\x18CreateShortLinksResponse\x123\n" +
+	"\x05items\x18\x01 \x03(\v2\x1d.yamata.internal.v1.ShortLinkR\x05items\"\x88\x02\n" +
+	"\tShortLink\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x10\n" +
+	"\x03uid\x18\x02 \x01(\tR\x03uid\x12$\n" +
+	"\vcampaign_id\x18\x03 \x01(\x04H\x00R\n" +
+	"campaignId\x88\x01\x01\x12 \n" +
+	"\tclient_id\x18\x04 \x01(\x04H\x01R\bclientId\x88\x01\x01\x12&\n" +
+	"\fphone_number\x18\x05 \x01(\tH\x02R\vphoneNumber\x88\x01\x01\x12\x1b\n" +
+	"\tlong_link\x18\x06 \x01(\tR\blongLink\x12\x1d\n" +
+	"\n" +
+	"short_link\x18\a \x01(\tR\tshortLinkB\x0e\n" +
+	"\f_campaign_idB\f\n" +
+	"\n" +
+	"_client_idB\x0f\n" +
+	"\r_phone_number2\xf6\x01\n" +
+	"\x10ShortLinkService\x12s\n" +
+	"\x12AllocateShortLinks\x12-.yamata.internal.v1.AllocateShortLinksRequest\x1a..yamata.internal.v1.AllocateShortLinksResponse\x12m\n" +
+	"\x10CreateShortLinks\x12+.yamata.internal.v1.CreateShortLinksRequest\x1a,.yamata.internal.v1.CreateShortLinksResponseBGZEgithub.com/amirphl/Yamata-no-Orochi/app/grpcapi/internalv1;internalv1b\x06proto3"
+
+var (
+	file_internal_v1_short_link_proto_rawDescOnce sync.Once
+	file_internal_v1_short_link_proto_rawDescData []byte
+)
+
+func file_internal_v1_short_link_proto_rawDescGZIP() []byte {
+	file_internal_v1_short_link_proto_rawDescOnce.Do(func() {
+		file_internal_v1_short_link_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_internal_v1_short_link_proto_rawDesc), len(file_internal_v1_short_link_proto_rawDesc)))
+	})
+	return file_internal_v1_short_link_proto_rawDescData
+}
+
+var file_internal_v1_short_link_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_internal_v1_short_link_proto_goTypes = []any{
+	(*AllocateShortLinksRequest)(nil),  // 0: yamata.internal.v1.AllocateShortLinksRequest
+	(*PhoneAdLink)(nil),                // 1: yamata.internal.v1.PhoneAdLink
+	(*AllocateShortLinksResponse)(nil), // 2: yamata.internal.v1.AllocateShortLinksResponse
+	(*CreateShortLinksRequest)(nil),    // 3: yamata.internal.v1.CreateShortLinksRequest
+	(*NewShortLink)(nil),               // 4: yamata.internal.v1.NewShortLink
+	(*CreateShortLinksResponse)(nil),   // 5: yamata.internal.v1.CreateShortLinksResponse
+	(*ShortLink)(nil),                  // 6: yamata.internal.v1.ShortLink
+}
+var file_internal_v1_short_link_proto_depIdxs = []int32{
+	1, // 0: yamata.internal.v1.AllocateShortLinksRequest.items:type_name -> yamata.internal.v1.PhoneAdLink
+	4, // 1: yamata.internal.v1.CreateShortLinksRequest.items:type_name -> yamata.internal.v1.NewShortLink
+	6, // 2: yamata.internal.v1.CreateShortLinksResponse.items:type_name -> yamata.internal.v1.ShortLink
+	0, // 3: yamata.internal.v1.ShortLinkService.AllocateShortLinks:input_type -> yamata.internal.v1.AllocateShortLinksRequest
+	3, // 4: yamata.internal.v1.ShortLinkService.CreateShortLinks:input_type -> yamata.internal.v1.CreateShortLinksRequest
+	2, // 5: yamata.internal.v1.ShortLinkService.AllocateShortLinks:output_type -> yamata.internal.v1.AllocateShortLinksResponse
+	5, // 6: yamata.internal.v1.ShortLinkService.CreateShortLinks:output_type -> yamata.internal.v1.CreateShortLinksResponse
+	5, // [5:7] is the sub-list for method output_type
+	3, // [3:5] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_internal_v1_short_link_proto_init() }
+func file_internal_v1_short_link_proto_init() {
+	if File_internal_v1_short_link_proto != nil {
+		return
+	}
+	file_internal_v1_short_link_proto_msgTypes[1].OneofWrappers = []any{}
+	file_internal_v1_short_link_proto_msgTypes[4].OneofWrappers = []any{}
+	file_internal_v1_short_link_proto_msgTypes[6].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_internal_v1_short_link_proto_rawDesc), len(file_internal_v1_short_link_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   7,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_internal_v1_short_link_proto_goTypes,
+		DependencyIndexes: file_internal_v1_short_link_proto_depIdxs,
+		MessageInfos:      file_internal_v1_short_link_proto_msgTypes,
+	}.Build()
+	File_internal_v1_short_link_proto = out.File
+	file_internal_v1_short_link_proto_goTypes = nil
+	file_internal_v1_short_link_proto_depIdxs = nil
+}
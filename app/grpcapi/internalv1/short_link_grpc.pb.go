@@ -0,0 +1,169 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             (unknown)
+// source: internal/v1/short_link.proto
+
+package internalv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	ShortLinkService_AllocateShortLinks_FullMethodName = "/yamata.internal.v1.ShortLinkService/AllocateShortLinks"
+	ShortLinkService_CreateShortLinks_FullMethodName   = "/yamata.internal.v1.ShortLinkService/CreateShortLinks"
+)
+
+// ShortLinkServiceClient is the client API for ShortLinkService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// ShortLinkService creates the short links of campaign messages.
+type ShortLinkServiceClient interface {
+	// AllocateShortLinks creates one short link per phone with sequential
+	// codes and returns the codes in input order.
+	AllocateShortLinks(ctx context.Context, in *AllocateShortLinksRequest, opts ...grpc.CallOption) (*AllocateShortLinksResponse, error)
+	// CreateShortLinks creates short links whose codes the caller chose.
+	CreateShortLinks(ctx context.Context, in *CreateShortLinksRequest, opts ...grpc.CallOption) (*CreateShortLinksResponse, error)
+}
+
+type shortLinkServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewShortLinkServiceClient(cc grpc.ClientConnInterface) ShortLinkServiceClient {
+	return &shortLinkServiceClient{cc}
+}
+
+func (c *shortLinkServiceClient) AllocateShortLinks(ctx context.Context, in *AllocateShortLinksRequest, opts ...grpc.CallOption) (*AllocateShortLinksResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AllocateShortLinksResponse)
+	err := c.cc.Invoke(ctx, ShortLinkService_AllocateShortLinks_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *shortLinkServiceClient) CreateShortLinks(ctx context.Context, in *CreateShortLinksRequest, opts ...grpc.CallOption) (*CreateShortLinksResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateShortLinksResponse)
+	err := c.cc.Invoke(ctx, ShortLinkService_CreateShortLinks_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ShortLinkServiceServer is the server API for ShortLinkService service.
+// All implementations must embed UnimplementedShortLinkServiceServer
+// for forward compatibility.
+//
+// ShortLinkService creates the short links of campaign messages.
+type ShortLinkServiceServer interface {
+	// AllocateShortLinks creates one short link per phone with sequential
+	// codes and returns the codes in input order.
+	AllocateShortLinks(context.Context, *AllocateShortLinksRequest) (*AllocateShortLinksResponse, error)
+	// CreateShortLinks creates short links whose codes the caller chose.
+	CreateShortLinks(context.Context, *CreateShortLinksRequest) (*CreateShortLinksResponse, error)
+	mustEmbedUnimplementedShortLinkServiceServer()
+}
+
+// UnimplementedShortLinkServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedShortLinkServiceServer struct{}
+
+func (UnimplementedShortLinkServiceServer) AllocateShortLinks(context.Context, *AllocateShortLinksRequest) (*AllocateShortLinksResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method AllocateShortLinks not implemented")
+}
+func (UnimplementedShortLinkServiceServer) CreateShortLinks(context.Context, *CreateShortLinksRequest) (*CreateShortLinksResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method CreateShortLinks not implemented")
+}
+func (UnimplementedShortLinkServiceServer) mustEmbedUnimplementedShortLinkServiceServer() {}
+func (UnimplementedShortLinkServiceServer) testEmbeddedByValue()                          {}
+
+// UnsafeShortLinkServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ShortLinkServiceServer will
+// result in compilation errors.
+type UnsafeShortLinkServiceServer interface {
+	mustEmbedUnimplementedShortLinkServiceServer()
+}
+
+func RegisterShortLinkServiceServer(s grpc.ServiceRegistrar, srv ShortLinkServiceServer) {
+	// If the following call panics, it indicates UnimplementedShortLinkServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&ShortLinkService_ServiceDesc, srv)
+}
+
+func _ShortLinkService_AllocateShortLinks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AllocateShortLinksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShortLinkServiceServer).AllocateShortLinks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ShortLinkService_AllocateShortLinks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShortLinkServiceServer).AllocateShortLinks(ctx, req.(*AllocateShortLinksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ShortLinkService_CreateShortLinks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateShortLinksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShortLinkServiceServer).CreateShortLinks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ShortLinkService_CreateShortLinks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShortLinkServiceServer).CreateShortLinks(ctx, req.(*CreateShortLinksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ShortLinkService_ServiceDesc is the grpc.ServiceDesc for ShortLinkService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ShortLinkService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "yamata.internal.v1.ShortLinkService",
+	HandlerType: (*ShortLinkServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "AllocateShortLinks",
+			Handler:    _ShortLinkService_AllocateShortLinks_Handler,
+		},
+		{
+			MethodName: "CreateShortLinks",
+			Handler:    _ShortLinkService_CreateShortLinks_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "internal/v1/short_link.proto",
+}
@@ -0,0 +1,248 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: internal/v1/wallet.proto
+
+package internalv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type GetBalanceRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CustomerId    uint64                 `protobuf:"varint,1,opt,name=customer_id,json=customerId,proto3" json:"customer_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetBalanceRequest) Reset() {
+	*x = GetBalanceRequest{}
+	mi := &file_internal_v1_wallet_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBalanceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBalanceRequest) ProtoMessage() {}
+
+func (x *GetBalanceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_v1_wallet_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBalanceRequest.ProtoReflect.Descriptor instead.
+func (*GetBalanceRequest) Descriptor() ([]byte, []int) {
+	return file_internal_v1_wallet_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *GetBalanceRequest) GetCustomerId() uint64 {
+	if x != nil {
+		return x.CustomerId
+	}
+	return 0
+}
+
+// Amounts are in Toman.
+type GetBalanceResponse struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	Free               uint64                 `protobuf:"varint,1,opt,name=free,proto3" json:"free,omitempty"`
+	Locked             uint64                 `protobuf:"varint,2,opt,name=locked,proto3" json:"locked,omitempty"`
+	Frozen             uint64                 `protobuf:"varint,3,opt,name=frozen,proto3" json:"frozen,omitempty"`
+	Credit             uint64                 `protobuf:"varint,4,opt,name=credit,proto3" json:"credit,omitempty"`
+	SpentOnCampaigns   uint64                 `protobuf:"varint,5,opt,name=spent_on_campaigns,json=spentOnCampaigns,proto3" json:"spent_on_campaigns,omitempty"`
+	AgencyShareWithTax uint64                 `protobuf:"varint,6,opt,name=agency_share_with_tax,json=agencyShareWithTax,proto3" json:"agency_share_with_tax,omitempty"`
+	Total              uint64                 `protobuf:"varint,7,opt,name=total,proto3" json:"total,omitempty"`
+	Currency           string                 `protobuf:"bytes,8,opt,name=currency,proto3" json:"currency,omitempty"`
+	LastUpdated        string                 `protobuf:"bytes,9,opt,name=last_updated,json=lastUpdated,proto3" json:"last_updated,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *GetBalanceResponse) Reset() {
+	*x = GetBalanceResponse{}
+	mi := &file_internal_v1_wallet_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBalanceResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBalanceResponse) ProtoMessage() {}
+
+func (x *GetBalanceResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_v1_wallet_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBalanceResponse.ProtoReflect.Descriptor instead.
+func (*GetBalanceResponse) Descriptor() ([]byte, []int) {
+	return file_internal_v1_wallet_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GetBalanceResponse) GetFree() uint64 {
+	if x != nil {
+		return x.Free
+	}
+	return 0
+}
+
+func (x *GetBalanceResponse) GetLocked() uint64 {
+	if x != nil {
+		return x.Locked
+	}
+	return 0
+}
+
+func (x *GetBalanceResponse) GetFrozen() uint64 {
+	if x != nil {
+		return x.Frozen
+	}
+	return 0
+}
+
+func (x *GetBalanceResponse) GetCredit() uint64 {
+	if x != nil {
+		return x.Credit
+	}
+	return 0
+}
+
+func (x *GetBalanceResponse) GetSpentOnCampaigns() uint64 {
+	if x != nil {
+		return x.SpentOnCampaigns
+	}
+	return 0
+}
+
+func (x *GetBalanceResponse) GetAgencyShareWithTax() uint64 {
+	if x != nil {
+		return x.AgencyShareWithTax
+	}
+	return 0
+}
+
+func (x *GetBalanceResponse) GetTotal() uint64 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *GetBalanceResponse) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *GetBalanceResponse) GetLastUpdated() string {
+	if x != nil {
+		return x.LastUpdated
+	}
+	return ""
+}
+
+var File_internal_v1_wallet_proto protoreflect.FileDescriptor
+
+const file_internal_v1_wallet_proto_rawDesc = "" +
+	"\n" +
+	"\x18internal/v1/wallet.proto\x12\x12yamata.internal.v1\"4\n" +
+	"\x11GetBalanceRequest\x12\x1f\n" +
+	"\vcustomer_id\x18\x01 \x01(\x04R\n" +
+	"customerId\"\xa6\x02\n" +
+	"\x12GetBalanceResponse\x12\x12\n" +
+	"\x04free\x18\x01 \x01(\x04R\x04free\x12\x16\n" +
+	"\x06locked\x18\x02 \x01(\x04R\x06locked\x12\x16\n" +
+	"\x06frozen\x18\x03 \x01(\x04R\x06frozen\x12\x16\n" +
+	"\x06credit\x18\x04 \x01(\x04R\x06credit\x12,\n" +
+	"\x12spent_on_campaigns\x18\x05 \x01(\x04R\x10spentOnCampaigns\x121\n" +
+	"\x15agency_share_with_tax\x18\x06 \x01(\x04R\x12agencyShareWithTax\x12\x14\n" +
+	"\x05total\x18\a \x01(\x04R\x05total\x12\x1a\n" +
+	"\bcurrency\x18\b \x01(\tR\bcurrency\x12!\n" +
+	"\flast_updated\x18\t \x01(\tR\vlastUpdated2l\n" +
+	"\rWalletService\x12[\n" +
+	"\n" +
+	"GetBalance\x12%.yamata.internal.v1.GetBalanceRequest\x1a&.yamata.internal.v1.GetBalanceResponseBGZEgithub.com/amirphl/Yamata-no-Orochi/app/grpcapi/internalv1;internalv1b\x06proto3"
+
+var (
+	file_internal_v1_wallet_proto_rawDescOnce sync.Once
+	file_internal_v1_wallet_proto_rawDescData []byte
+)
+
+func file_internal_v1_wallet_proto_rawDescGZIP() []byte {
+	file_internal_v1_wallet_proto_rawDescOnce.Do(func() {
+		file_internal_v1_wallet_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_internal_v1_wallet_proto_rawDesc), len(file_internal_v1_wallet_proto_rawDesc)))
+	})
+	return file_internal_v1_wallet_proto_rawDescData
+}
+
+var file_internal_v1_wallet_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_internal_v1_wallet_proto_goTypes = []any{
+	(*GetBalanceRequest)(nil),  // 0: yamata.internal.v1.GetBalanceRequest
+	(*GetBalanceResponse)(nil), // 1: yamata.internal.v1.GetBalanceResponse
+}
+var file_internal_v1_wallet_proto_depIdxs = []int32{
+	0, // 0: yamata.internal.v1.WalletService.GetBalance:input_type -> yamata.internal.v1.GetBalanceRequest
+	1, // 1: yamata.internal.v1.WalletService.GetBalance:output_type -> yamata.internal.v1.GetBalanceResponse
+	1, // [1:2] is the sub-list for method output_type
+	0, // [0:1] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_internal_v1_wallet_proto_init() }
+func file_internal_v1_wallet_proto_init() {
+	if File_internal_v1_wallet_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_internal_v1_wallet_proto_rawDesc), len(file_internal_v1_wallet_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_internal_v1_wallet_proto_goTypes,
+		DependencyIndexes: file_internal_v1_wallet_proto_depIdxs,
+		MessageInfos:      file_internal_v1_wallet_proto_msgTypes,
+	}.Build()
+	File_internal_v1_wallet_proto = out.File
+	file_internal_v1_wallet_proto_goTypes = nil
+	file_internal_v1_wallet_proto_depIdxs = nil
+}
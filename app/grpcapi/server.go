@@ -0,0 +1,179 @@
+// Package grpcapi serves the internal gRPC API the schedulers and the bot use
+// instead of the public REST API. The services are defined in
+// proto/internal/v1 and generated into internalv1 by `make proto`.
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/grpcapi/internalv1"
+	"github.com/amirphl/Yamata-no-Orochi/app/services"
+	businessflow "github.com/amirphl/Yamata-no-Orochi/business_flow"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// defaultCallTimeout bounds calls whose client set no deadline
+const defaultCallTimeout = 60 * time.Second
+
+// publicMethods may be called without a bot access token
+var publicMethods = map[string]bool{
+	internalv1.BotAuthService_Login_FullMethodName: true,
+	healthpb.Health_Check_FullMethodName:           true,
+	healthpb.Health_List_FullMethodName:            true,
+	healthpb.Health_Watch_FullMethodName:           true,
+}
+
+// Flows are the business flows behind the internal services
+type Flows struct {
+	BotAuth      businessflow.BotAuthFlow
+	BotCampaign  businessflow.BotCampaignFlow
+	BotShortLink businessflow.BotShortLinkFlow
+	Payment      businessflow.PaymentFlow
+}
+
+type botClaimsKey struct{}
+
+// NewServer returns a gRPC server with every internal service registered.
+// Calls other than Login and the health checks need a bot access token in
+// the authorization metadata.
+func NewServer(tokenService services.TokenService, flows Flows) *grpc.Server {
+	srv := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(
+			recoverUnary,
+			authUnary(tokenService),
+		),
+	)
+	internalv1.RegisterBotAuthServiceServer(srv, &botAuthServer{flow: flows.BotAuth})
+	internalv1.RegisterCampaignLifecycleServiceServer(srv, &campaignLifecycleServer{flow: flows.BotCampaign})
+	internalv1.RegisterShortLinkServiceServer(srv, &shortLinkServer{flow: flows.BotShortLink})
+	internalv1.RegisterWalletServiceServer(srv, &walletServer{flow: flows.Payment})
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+	return srv
+}
+
+// Start serves srv on address and returns a func that stops it, waiting up
+// to shutdownTimeout for in-flight calls
+func Start(srv *grpc.Server, address string, shutdownTimeout time.Duration) (func(), error) {
+	lis, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		if err := srv.Serve(lis); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+			log.Printf("gRPC server error: %v", err)
+		}
+	}()
+	log.Printf("gRPC server started on %s", address)
+	return func() {
+		done := make(chan struct{})
+		go func() {
+			srv.GracefulStop()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(shutdownTimeout):
+			srv.Stop()
+		}
+	}, nil
+}
+
+func recoverUnary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("gRPC panic in %s: %v\n%s", info.FullMethod, r, debug.Stack())
+			err = statusError(codes.Internal, "Internal server error", "INTERNAL_ERROR")
+		}
+	}()
+	return handler(ctx, req)
+}
+
+// authUnary validates the bot access token and adds the request metadata the
+// business flows log to the context
+func authUnary(tokenService services.TokenService) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx = requestContext(ctx, info.FullMethod)
+		if _, ok := ctx.Deadline(); !ok {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, defaultCallTimeout)
+			defer cancel()
+		}
+		if publicMethods[info.FullMethod] {
+			return handler(ctx, req)
+		}
+
+		authHeader := firstMetadata(ctx, "authorization")
+		if authHeader == "" {
+			return nil, statusError(codes.Unauthenticated, "Authorization metadata is required", "MISSING_AUTHORIZATION_HEADER")
+		}
+		token, ok := strings.CutPrefix(authHeader, "Bearer ")
+		if !ok || token == "" {
+			return nil, statusError(codes.Unauthenticated, "Invalid authorization metadata format. Expected 'Bearer <token>'", "INVALID_AUTHORIZATION_FORMAT")
+		}
+		claims, err := tokenService.ValidateBotToken(token)
+		if err != nil {
+			switch {
+			case errors.Is(err, services.ErrTokenExpired):
+				return nil, statusError(codes.Unauthenticated, "Access token has expired", "TOKEN_EXPIRED")
+			case errors.Is(err, services.ErrTokenInvalid):
+				return nil, statusError(codes.Unauthenticated, "Invalid access token", "TOKEN_INVALID")
+			case errors.Is(err, services.ErrTokenRevoked):
+				return nil, statusError(codes.Unauthenticated, "Access token has been revoked", "TOKEN_REVOKED")
+			}
+			return nil, statusError(codes.Unauthenticated, "Token validation failed", "TOKEN_VALIDATION_FAILED")
+		}
+		if claims.BotID == 0 {
+			return nil, statusError(codes.Unauthenticated, "Invalid bot ID", "INVALID_BOT_ID")
+		}
+		return handler(context.WithValue(ctx, botClaimsKey{}, claims), req)
+	}
+}
+
+func requestContext(ctx context.Context, method string) context.Context {
+	ctx = context.WithValue(ctx, utils.RequestIDKey, firstMetadata(ctx, "x-request-id"))
+	ctx = context.WithValue(ctx, utils.UserAgentKey, firstMetadata(ctx, "user-agent"))
+	ctx = context.WithValue(ctx, utils.IPAddressKey, peerIP(ctx))
+	ctx = context.WithValue(ctx, utils.EndpointKey, method)
+	return ctx
+}
+
+func firstMetadata(ctx context.Context, key string) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	if v := md.Get(key); len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+func peerIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
+
+func clientMetadata(ctx context.Context) *businessflow.ClientMetadata {
+	ip, _ := ctx.Value(utils.IPAddressKey).(string)
+	ua, _ := ctx.Value(utils.UserAgentKey).(string)
+	return businessflow.NewClientMetadata(ip, ua)
+}
@@ -0,0 +1,211 @@
+package grpcapi
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/app/grpcapi/internalv1"
+	"github.com/amirphl/Yamata-no-Orochi/app/services"
+	businessflow "github.com/amirphl/Yamata-no-Orochi/business_flow"
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type fakeTokenService struct {
+	services.TokenService
+}
+
+func (fakeTokenService) ValidateBotToken(token string) (*services.BotTokenClaims, error) {
+	switch token {
+	case "valid":
+		return &services.BotTokenClaims{BotID: 7}, nil
+	case "expired":
+		return nil, services.ErrTokenExpired
+	}
+	return nil, services.ErrTokenInvalid
+}
+
+type fakeBotAuthFlow struct{}
+
+func (fakeBotAuthFlow) Verify(ctx context.Context, req *dto.BotLoginRequest, metadata *businessflow.ClientMetadata) (*dto.BotLoginResponse, error) {
+	if req.Password != "secret-password" {
+		return nil, businessflow.NewBusinessError("BOT_INCORRECT_PASSWORD", "Incorrect password", businessflow.ErrIncorrectPassword)
+	}
+	return &dto.BotLoginResponse{Bot: dto.BotDTO{ID: 7}, Session: dto.BotSessionDTO{AccessToken: "valid", TokenType: "Bearer"}}, nil
+}
+
+type fakeBotCampaignFlow struct {
+	businessflow.BotCampaignFlow
+	ready   []dto.BotGetCampaignResponse
+	running []uint
+}
+
+func (f *fakeBotCampaignFlow) ListReadyCampaigns(ctx context.Context, platform *string) (*dto.BotListCampaignsResponse, error) {
+	if platform != nil && *platform == "fax" {
+		return nil, businessflow.NewBusinessError("INVALID_PLATFORM", "Invalid platform", businessflow.ErrCampaignPlatformInvalid)
+	}
+	return &dto.BotListCampaignsResponse{Items: f.ready}, nil
+}
+
+func (f *fakeBotCampaignFlow) MoveCampaignToRunning(ctx context.Context, campaignID uint) error {
+	if campaignID == 404 {
+		return businessflow.NewBusinessError("CAMPAIGN_NOT_FOUND", "Campaign not found", businessflow.ErrCampaignNotFound)
+	}
+	f.running = append(f.running, campaignID)
+	return nil
+}
+
+func startTestServer(t *testing.T, flows Flows) *grpc.ClientConn {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	srv := NewServer(fakeTokenService{}, flows)
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func withToken(token string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+}
+
+func TestServerRequiresBotToken(t *testing.T) {
+	t.Parallel()
+
+	conn := startTestServer(t, Flows{BotCampaign: &fakeBotCampaignFlow{}})
+	client := internalv1.NewCampaignLifecycleServiceClient(conn)
+
+	cases := []struct {
+		ctx  context.Context
+		code string
+	}{
+		{context.Background(), "MISSING_AUTHORIZATION_HEADER"},
+		{metadata.AppendToOutgoingContext(context.Background(), "authorization", "valid"), "INVALID_AUTHORIZATION_FORMAT"},
+		{withToken("expired"), "TOKEN_EXPIRED"},
+		{withToken("forged"), "TOKEN_INVALID"},
+	}
+	for _, tc := range cases {
+		_, err := client.ListReadyCampaigns(tc.ctx, &internalv1.ListReadyCampaignsRequest{})
+		if status.Code(err) != codes.Unauthenticated || ErrorCode(err) != tc.code {
+			t.Fatalf("expected Unauthenticated/%s, got %v (%s)", tc.code, err, ErrorCode(err))
+		}
+	}
+}
+
+func TestLoginIsPublic(t *testing.T) {
+	t.Parallel()
+
+	conn := startTestServer(t, Flows{BotAuth: fakeBotAuthFlow{}})
+	client := internalv1.NewBotAuthServiceClient(conn)
+
+	resp, err := client.Login(context.Background(), &internalv1.LoginRequest{Username: "scheduler", Password: "secret-password"})
+	if err != nil {
+		t.Fatalf("login failed: %v", err)
+	}
+	if resp.GetAccessToken() != "valid" || resp.GetBotId() != 7 {
+		t.Fatalf("unexpected login response %v", resp)
+	}
+
+	_, err = client.Login(context.Background(), &internalv1.LoginRequest{Username: "scheduler", Password: "wrong-password"})
+	if status.Code(err) != codes.Unauthenticated || ErrorCode(err) != "BOT_LOGIN_FAILED" {
+		t.Fatalf("expected BOT_LOGIN_FAILED, got %v", err)
+	}
+}
+
+func TestListReadyCampaignsConvertsCampaigns(t *testing.T) {
+	t.Parallel()
+
+	title := "Nowruz sale"
+	budget := uint64(5_000_000)
+	mediaUUID := uuid.New()
+	settingsID := uint(3)
+	scheduleAt := time.Date(2026, 3, 20, 8, 0, 0, 0, time.UTC)
+	flow := &fakeBotCampaignFlow{ready: []dto.BotGetCampaignResponse{{
+		ID:                 12,
+		CustomerID:         4,
+		Status:             "approved",
+		CreatedAt:          scheduleAt.Add(-time.Hour),
+		Title:              &title,
+		Tags:               []string{"tehran"},
+		ScheduleAt:         &scheduleAt,
+		MediaUUID:          &mediaUUID,
+		PlatformSettingsID: &settingsID,
+		PlatformSettings: &dto.BotCampaignPlatformSettingsSpec{
+			ID:       settingsID,
+			Platform: "bale",
+			Metadata: map[string]any{"bot_token_ref": "main", "retries": 3},
+			Status:   "active",
+		},
+		Platform: "bale",
+		Budget:   &budget,
+		Variants: []dto.CampaignContentVariantSpec{{Label: "A", Content: "hi", Weight: 60}},
+	}}}
+	conn := startTestServer(t, Flows{BotCampaign: flow})
+	client := internalv1.NewCampaignLifecycleServiceClient(conn)
+
+	resp, err := client.ListReadyCampaigns(withToken("valid"), &internalv1.ListReadyCampaignsRequest{Platform: "bale"})
+	if err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	if len(resp.GetCampaigns()) != 1 {
+		t.Fatalf("expected 1 campaign, got %d", len(resp.GetCampaigns()))
+	}
+	c := resp.GetCampaigns()[0]
+	if c.GetId() != 12 || c.GetTitle() != title || c.GetBudget() != budget || c.GetMediaUuid() != mediaUUID.String() {
+		t.Fatalf("unexpected campaign %v", c)
+	}
+	if !c.GetScheduleAt().AsTime().Equal(scheduleAt) || c.UpdatedAt != nil {
+		t.Fatalf("unexpected timestamps %v %v", c.GetScheduleAt(), c.UpdatedAt)
+	}
+	if c.GetPlatformSettings().GetMetadata().AsMap()["retries"] != float64(3) {
+		t.Fatalf("unexpected platform settings metadata %v", c.GetPlatformSettings().GetMetadata())
+	}
+	if len(c.GetVariants()) != 1 || c.GetVariants()[0].GetWeight() != 60 {
+		t.Fatalf("unexpected variants %v", c.GetVariants())
+	}
+
+	_, err = client.ListReadyCampaigns(withToken("valid"), &internalv1.ListReadyCampaignsRequest{Platform: "fax"})
+	if status.Code(err) != codes.InvalidArgument || ErrorCode(err) != "INVALID_PLATFORM" {
+		t.Fatalf("expected INVALID_PLATFORM, got %v", err)
+	}
+}
+
+func TestMoveToRunningMapsErrors(t *testing.T) {
+	t.Parallel()
+
+	flow := &fakeBotCampaignFlow{}
+	conn := startTestServer(t, Flows{BotCampaign: flow})
+	client := internalv1.NewCampaignLifecycleServiceClient(conn)
+
+	if _, err := client.MoveToRunning(withToken("valid"), &internalv1.MoveCampaignRequest{CampaignId: 12}); err != nil {
+		t.Fatalf("move failed: %v", err)
+	}
+	if len(flow.running) != 1 || flow.running[0] != 12 {
+		t.Fatalf("expected campaign 12 moved, got %v", flow.running)
+	}
+
+	_, err := client.MoveToRunning(withToken("valid"), &internalv1.MoveCampaignRequest{CampaignId: 404})
+	if status.Code(err) != codes.NotFound || ErrorCode(err) != "CAMPAIGN_NOT_FOUND" {
+		t.Fatalf("expected CAMPAIGN_NOT_FOUND, got %v", err)
+	}
+	_, err = client.MoveToRunning(withToken("valid"), &internalv1.MoveCampaignRequest{})
+	if status.Code(err) != codes.InvalidArgument || ErrorCode(err) != "INVALID_CAMPAIGN_ID" {
+		t.Fatalf("expected INVALID_CAMPAIGN_ID, got %v", err)
+	}
+}
@@ -0,0 +1,299 @@
+package grpcapi
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/app/grpcapi/internalv1"
+	businessflow "github.com/amirphl/Yamata-no-Orochi/business_flow"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type botAuthServer struct {
+	internalv1.UnimplementedBotAuthServiceServer
+	flow businessflow.BotAuthFlow
+}
+
+func (s *botAuthServer) Login(ctx context.Context, req *internalv1.LoginRequest) (*internalv1.LoginResponse, error) {
+	if req.GetUsername() == "" || req.GetPassword() == "" {
+		return nil, statusError(codes.InvalidArgument, "Validation failed", "VALIDATION_ERROR")
+	}
+	res, err := s.flow.Verify(ctx, &dto.BotLoginRequest{Username: req.GetUsername(), Password: req.GetPassword()}, clientMetadata(ctx))
+	if err != nil {
+		return nil, statusError(codes.Unauthenticated, "Login failed", "BOT_LOGIN_FAILED")
+	}
+	return &internalv1.LoginResponse{
+		BotId:        uint64(res.Bot.ID),
+		AccessToken:  res.Session.AccessToken,
+		RefreshToken: res.Session.RefreshToken,
+		ExpiresIn:    res.Session.ExpiresIn,
+		TokenType:    res.Session.TokenType,
+	}, nil
+}
+
+type campaignLifecycleServer struct {
+	internalv1.UnimplementedCampaignLifecycleServiceServer
+	flow businessflow.BotCampaignFlow
+}
+
+func (s *campaignLifecycleServer) ListReadyCampaigns(ctx context.Context, req *internalv1.ListReadyCampaignsRequest) (*internalv1.ListReadyCampaignsResponse, error) {
+	var platform *string
+	if p := strings.TrimSpace(req.GetPlatform()); p != "" {
+		platform = &p
+	}
+	res, err := s.flow.ListReadyCampaigns(ctx, platform)
+	if err != nil {
+		return nil, flowError("ListReadyCampaigns", err, "Failed to list ready campaigns", "LIST_READY_CAMPAIGNS_FAILED")
+	}
+	out := &internalv1.ListReadyCampaignsResponse{Campaigns: make([]*internalv1.Campaign, 0, len(res.Items))}
+	for i := range res.Items {
+		c, err := campaignToProto(&res.Items[i])
+		if err != nil {
+			return nil, flowError("ListReadyCampaigns", err, "Failed to list ready campaigns", "LIST_READY_CAMPAIGNS_FAILED")
+		}
+		out.Campaigns = append(out.Campaigns, c)
+	}
+	return out, nil
+}
+
+func (s *campaignLifecycleServer) MoveToRunning(ctx context.Context, req *internalv1.MoveCampaignRequest) (*internalv1.MoveCampaignResponse, error) {
+	if req.GetCampaignId() == 0 {
+		return nil, statusError(codes.InvalidArgument, "Invalid campaign id", "INVALID_CAMPAIGN_ID")
+	}
+	if err := s.flow.MoveCampaignToRunning(ctx, uint(req.GetCampaignId())); err != nil {
+		return nil, flowError("MoveToRunning", err, "Failed to move campaign to running", "MOVE_TO_RUNNING_FAILED")
+	}
+	return &internalv1.MoveCampaignResponse{}, nil
+}
+
+func (s *campaignLifecycleServer) MoveToExecuted(ctx context.Context, req *internalv1.MoveCampaignRequest) (*internalv1.MoveCampaignResponse, error) {
+	if req.GetCampaignId() == 0 {
+		return nil, statusError(codes.InvalidArgument, "Invalid campaign id", "INVALID_CAMPAIGN_ID")
+	}
+	if err := s.flow.MoveCampaignToExecuted(ctx, uint(req.GetCampaignId())); err != nil {
+		return nil, flowError("MoveToExecuted", err, "Failed to move campaign to executed", "MOVE_TO_EXECUTED_FAILED")
+	}
+	return &internalv1.MoveCampaignResponse{}, nil
+}
+
+func (s *campaignLifecycleServer) UpdateStatistics(ctx context.Context, req *internalv1.UpdateStatisticsRequest) (*internalv1.UpdateStatisticsResponse, error) {
+	if req.GetCampaignId() == 0 {
+		return nil, statusError(codes.InvalidArgument, "Invalid campaign id", "INVALID_CAMPAIGN_ID")
+	}
+	if req.GetStatistics() == nil {
+		return nil, statusError(codes.InvalidArgument, "statistics is required", "VALIDATION_ERROR")
+	}
+	if _, err := s.flow.UpdateCampaignStatistics(ctx, uint(req.GetCampaignId()), req.GetStatistics().AsMap()); err != nil {
+		return nil, flowError("UpdateStatistics", err, "Failed to update campaign statistics", "UPDATE_CAMPAIGN_STATISTICS_FAILED")
+	}
+	return &internalv1.UpdateStatisticsResponse{}, nil
+}
+
+func (s *campaignLifecycleServer) PushAudienceUIDs(ctx context.Context, req *internalv1.PushAudienceUIDsRequest) (*internalv1.PushAudienceUIDsResponse, error) {
+	if req.GetCampaignId() == 0 {
+		return nil, statusError(codes.InvalidArgument, "Invalid campaign id", "INVALID_CAMPAIGN_ID")
+	}
+	if len(req.GetItems()) == 0 {
+		return nil, statusError(codes.InvalidArgument, "items must not be empty", "VALIDATION_ERROR")
+	}
+	items := make([]dto.BotAudienceUIDItem, len(req.GetItems()))
+	for i, it := range req.GetItems() {
+		items[i] = dto.BotAudienceUIDItem{UID: it.GetUid(), Code: it.GetCode()}
+	}
+	if err := s.flow.PushCampaignAudienceUIDs(ctx, uint(req.GetCampaignId()), items); err != nil {
+		return nil, flowError("PushAudienceUIDs", err, "Failed to push audience UIDs", "PUSH_AUDIENCE_UIDS_FAILED")
+	}
+	return &internalv1.PushAudienceUIDsResponse{}, nil
+}
+
+type shortLinkServer struct {
+	internalv1.UnimplementedShortLinkServiceServer
+	flow businessflow.BotShortLinkFlow
+}
+
+func (s *shortLinkServer) AllocateShortLinks(ctx context.Context, req *internalv1.AllocateShortLinksRequest) (*internalv1.AllocateShortLinksResponse, error) {
+	if req.GetCampaignId() == 0 || len(req.GetItems()) == 0 || strings.TrimSpace(req.GetShortLinkDomain()) == "" {
+		return nil, statusError(codes.InvalidArgument, "campaign_id, items and short_link_domain are required", "VALIDATION_ERROR")
+	}
+	items := make([]dto.PhoneWithAdLink, len(req.GetItems()))
+	for i, it := range req.GetItems() {
+		items[i] = dto.PhoneWithAdLink{Phone: it.GetPhone(), AdLink: it.AdLink}
+	}
+	allocated, err := s.flow.GenerateAndCreateShortLinks(ctx, &dto.BotAllocateShortLinksRequest{
+		CampaignID:      uint(req.GetCampaignId()),
+		Items:           items,
+		ShortLinkDomain: req.GetShortLinkDomain(),
+	})
+	if err != nil {
+		return nil, flowError("AllocateShortLinks", err, "Failed to allocate short links", "ALLOCATE_SHORT_LINKS_FAILED")
+	}
+	return &internalv1.AllocateShortLinksResponse{Codes: allocated}, nil
+}
+
+func (s *shortLinkServer) CreateShortLinks(ctx context.Context, req *internalv1.CreateShortLinksRequest) (*internalv1.CreateShortLinksResponse, error) {
+	if len(req.GetItems()) == 0 {
+		return nil, statusError(codes.InvalidArgument, "items must not be empty", "VALIDATION_ERROR")
+	}
+	in := &dto.BotCreateShortLinksRequest{Items: make([]dto.BotCreateShortLinkRequest, len(req.GetItems()))}
+	for i, it := range req.GetItems() {
+		if it.GetUid() == "" || it.GetLongLink() == "" || it.GetShortLink() == "" {
+			return nil, statusError(codes.InvalidArgument, "uid, long_link and short_link are required", "VALIDATION_ERROR")
+		}
+		in.Items[i] = dto.BotCreateShortLinkRequest{
+			UID:         it.GetUid(),
+			CampaignID:  optionalUint(it.CampaignId),
+			ClientID:    optionalUint(it.ClientId),
+			PhoneNumber: it.PhoneNumber,
+			LongLink:    it.GetLongLink(),
+			ShortLink:   it.GetShortLink(),
+		}
+	}
+	res, err := s.flow.CreateShortLinks(ctx, in)
+	if err != nil {
+		return nil, flowError("CreateShortLinks", err, "Failed to create short links", "CREATE_SHORT_LINKS_FAILED")
+	}
+	out := &internalv1.CreateShortLinksResponse{Items: make([]*internalv1.ShortLink, 0, len(res.Items))}
+	for _, it := range res.Items {
+		out.Items = append(out.Items, &internalv1.ShortLink{
+			Id:          uint64(it.ID),
+			Uid:         it.UID,
+			CampaignId:  optionalUint64(it.CampaignID),
+			ClientId:    optionalUint64(it.ClientID),
+			PhoneNumber: it.PhoneNumber,
+			LongLink:    it.LongLink,
+			ShortLink:   it.ShortLink,
+		})
+	}
+	return out, nil
+}
+
+type walletServer struct {
+	internalv1.UnimplementedWalletServiceServer
+	flow businessflow.PaymentFlow
+}
+
+func (s *walletServer) GetBalance(ctx context.Context, req *internalv1.GetBalanceRequest) (*internalv1.GetBalanceResponse, error) {
+	if req.GetCustomerId() == 0 {
+		return nil, statusError(codes.InvalidArgument, "customer_id is required", "VALIDATION_ERROR")
+	}
+	res, err := s.flow.GetWalletBalance(ctx, &dto.GetWalletBalanceRequest{CustomerID: uint(req.GetCustomerId())}, clientMetadata(ctx))
+	if err != nil {
+		return nil, flowError("GetBalance", err, "Wallet balance retrieval failed", "WALLET_BALANCE_RETRIEVAL_FAILED")
+	}
+	return &internalv1.GetBalanceResponse{
+		Free:               res.Free,
+		Locked:             res.Locked,
+		Frozen:             res.Frozen,
+		Credit:             res.Credit,
+		SpentOnCampaigns:   res.SpentOnCamapigns,
+		AgencyShareWithTax: res.AgencyShareWithTax,
+		Total:              res.Total,
+		Currency:           res.Currency,
+		LastUpdated:        res.LastUpdated,
+	}, nil
+}
+
+// campaignToProto converts a ready campaign as the REST API lists it
+func campaignToProto(c *dto.BotGetCampaignResponse) (*internalv1.Campaign, error) {
+	out := &internalv1.Campaign{
+		Id:                          uint64(c.ID),
+		CustomerId:                  uint64(c.CustomerID),
+		Hidden:                      c.Hidden,
+		Sandbox:                     c.Sandbox,
+		Status:                      c.Status,
+		CreatedAt:                   timestamppb.New(c.CreatedAt),
+		UpdatedAt:                   optionalTimestamp(c.UpdatedAt),
+		Title:                       c.Title,
+		Level1:                      c.Level1,
+		Level2S:                     c.Level2s,
+		Level3S:                     c.Level3s,
+		Tags:                        c.Tags,
+		Sex:                         c.Sex,
+		City:                        c.City,
+		AdLink:                      c.AdLink,
+		Content:                     c.Content,
+		ShortLinkDomain:             c.ShortLinkDomain,
+		JobCategory:                 c.Category,
+		Job:                         c.Job,
+		ScheduleAt:                  optionalTimestamp(c.ScheduleAt),
+		LineNumber:                  c.LineNumber,
+		PlatformSettingsId:          optionalUint64(c.PlatformSettingsID),
+		Platform:                    c.Platform,
+		PlatformBasePrice:           c.PlatformBasePrice,
+		Budget:                      c.Budget,
+		Comment:                     c.Comment,
+		NumAudiences:                c.NumAudiences,
+		BundleId:                    optionalUint64(c.BundleID),
+		Phase:                       c.Phase,
+		AudienceGrades:              c.AudienceGrades,
+		TargetAudienceExcelFileUuid: c.TargetAudienceExcelFileUUID,
+	}
+	if c.MediaUUID != nil {
+		mediaUUID := c.MediaUUID.String()
+		out.MediaUuid = &mediaUUID
+	}
+	if ps := c.PlatformSettings; ps != nil {
+		out.PlatformSettings = &internalv1.PlatformSettings{
+			Id:           uint64(ps.ID),
+			Platform:     ps.Platform,
+			Name:         ps.Name,
+			Description:  ps.Description,
+			MultimediaId: optionalUint64(ps.MultimediaID),
+			Status:       ps.Status,
+		}
+		if ps.Metadata != nil {
+			md, err := structFromMap(ps.Metadata)
+			if err != nil {
+				return nil, err
+			}
+			out.PlatformSettings.Metadata = md
+		}
+	}
+	for _, v := range c.Variants {
+		out.Variants = append(out.Variants, &internalv1.ContentVariant{Label: v.Label, Content: v.Content, Weight: uint32(v.Weight)})
+	}
+	return out, nil
+}
+
+// structFromMap converts a map through JSON, so any value encoding/json can
+// marshal is accepted, as it is by the REST API
+func structFromMap(m map[string]any) (*structpb.Struct, error) {
+	b, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	out := &structpb.Struct{}
+	if err := protojson.Unmarshal(b, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func optionalTimestamp(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
+
+func optionalUint64(v *uint) *uint64 {
+	if v == nil {
+		return nil
+	}
+	out := uint64(*v)
+	return &out
+}
+
+func optionalUint(v *uint64) *uint {
+	if v == nil {
+		return nil
+	}
+	out := uint(*v)
+	return &out
+}
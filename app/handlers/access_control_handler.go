@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"github.com/amirphl/Yamata-no-Orochi/app/apierror"
 	"github.com/amirphl/Yamata-no-Orochi/app/dto"
 	"github.com/amirphl/Yamata-no-Orochi/app/middleware"
 	businessflow "github.com/amirphl/Yamata-no-Orochi/business_flow"
@@ -34,15 +35,16 @@ func NewAccessControlHandler(flow businessflow.AccessControlFlow) AccessControlH
 // @Failure 400 {object} dto.APIResponse "Invalid body"
 // @Failure 401 {object} dto.APIResponse "Admin authentication required"
 // @Failure 500 {object} dto.APIResponse "Failed to create request"
+// @Security AdminBearer
 // @Router /api/v1/admin/access-control/requests [post]
 func (h *AccessControlHandler) CreateRequest(c fiber.Ctx) error {
 	var req dto.AdminACLChangeRequestCreate
 	if err := c.Bind().JSON(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(dto.APIResponse{Success: false, Message: "Invalid body", Error: dto.ErrorDetail{Code: "INVALID_BODY"}})
+		return apierror.Respond(c, fiber.StatusBadRequest, "Invalid body", "INVALID_BODY", nil)
 	}
 	requesterID, ok := middleware.GetAdminIDFromContext(c)
 	if !ok {
-		return c.Status(fiber.StatusUnauthorized).JSON(dto.APIResponse{Success: false, Message: "Admin required", Error: dto.ErrorDetail{Code: "ADMIN_AUTHENTICATION_REQUIRED"}})
+		return apierror.Respond(c, fiber.StatusUnauthorized, "Admin required", "ADMIN_AUTHENTICATION_REQUIRED", nil)
 	}
 
 	modelReq := &models.ACLChangeRequest{
@@ -58,7 +60,7 @@ func (h *AccessControlHandler) CreateRequest(c fiber.Ctx) error {
 
 	created, err := h.flow.CreateRequest(c.Context(), requesterID, modelReq)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(dto.APIResponse{Success: false, Message: "Failed to create request", Error: dto.ErrorDetail{Code: "ACL_REQUEST_CREATE_FAILED", Details: map[string]any{"reason": err.Error()}}})
+		return apierror.Respond(c, fiber.StatusInternalServerError, "Failed to create request", "ACL_REQUEST_CREATE_FAILED", map[string]any{"reason": err.Error()})
 	}
 
 	return c.JSON(dto.APIResponse{Success: true, Data: map[string]any{"uuid": created.UUID.String(), "status": created.Status}})
@@ -80,12 +82,13 @@ func (h *AccessControlHandler) CreateRequest(c fiber.Ctx) error {
 // @Failure 404 {object} dto.APIResponse "Request not found"
 // @Failure 409 {object} dto.APIResponse "Request not in pending state"
 // @Failure 500 {object} dto.APIResponse "Approval failed"
+// @Security AdminBearer
 // @Router /api/v1/admin/access-control/requests/{uuid}/decision [post]
 func (h *AccessControlHandler) ApproveRequest(c fiber.Ctx) error {
 	idStr := c.Params("uuid")
 	u, err := uuid.Parse(idStr)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(dto.APIResponse{Success: false, Message: "Invalid uuid", Error: dto.ErrorDetail{Code: "INVALID_UUID"}})
+		return apierror.Respond(c, fiber.StatusBadRequest, "Invalid uuid", "INVALID_UUID", nil)
 	}
 	action := c.Query("action", "approve")
 	approve := action != "reject"
@@ -95,7 +98,7 @@ func (h *AccessControlHandler) ApproveRequest(c fiber.Ctx) error {
 
 	adminID, ok := middleware.GetAdminIDFromContext(c)
 	if !ok {
-		return c.Status(fiber.StatusUnauthorized).JSON(dto.APIResponse{Success: false, Message: "Admin required", Error: dto.ErrorDetail{Code: "ADMIN_AUTHENTICATION_REQUIRED"}})
+		return apierror.Respond(c, fiber.StatusUnauthorized, "Admin required", "ADMIN_AUTHENTICATION_REQUIRED", nil)
 	}
 
 	updated, err := h.flow.Approve(c.Context(), adminID, u, approve, body.Reason)
@@ -113,7 +116,7 @@ func (h *AccessControlHandler) ApproveRequest(c fiber.Ctx) error {
 			status = fiber.StatusNotFound
 			code = "ACL_REQUEST_NOT_FOUND"
 		}
-		return c.Status(status).JSON(dto.APIResponse{Success: false, Message: "Approval failed", Error: dto.ErrorDetail{Code: code, Details: map[string]any{"reason": err.Error()}}})
+		return apierror.Respond(c, status, "Approval failed", code, map[string]any{"reason": err.Error()})
 	}
 
 	return c.JSON(dto.APIResponse{Success: true, Data: map[string]any{"uuid": updated.UUID.String(), "status": updated.Status}})
@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/apierror"
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/app/middleware"
+	businessflow "github.com/amirphl/Yamata-no-Orochi/business_flow"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v3"
+)
+
+// AdminBulkOperationHandlerInterface defines the admin bulk operation endpoints
+type AdminBulkOperationHandlerInterface interface {
+	SetCustomersActiveStatus(c fiber.Ctx) error
+	ReassignAgency(c fiber.Ctx) error
+	ResyncCryptoPayments(c fiber.Ctx) error
+}
+
+// AdminBulkOperationHandler implements the admin bulk operation endpoints
+type AdminBulkOperationHandler struct {
+	flow      businessflow.AdminBulkOperationFlow
+	validator *validator.Validate
+}
+
+func NewAdminBulkOperationHandler(flow businessflow.AdminBulkOperationFlow) AdminBulkOperationHandlerInterface {
+	return &AdminBulkOperationHandler{flow: flow, validator: validator.New()}
+}
+
+func (h *AdminBulkOperationHandler) ErrorResponse(c fiber.Ctx, status int, message, code string, details any) error {
+	return apierror.Respond(c, status, message, code, details)
+}
+
+func (h *AdminBulkOperationHandler) SuccessResponse(c fiber.Ctx, status int, message string, data any) error {
+	return c.Status(status).JSON(dto.APIResponse{Success: true, Message: message, Data: data})
+}
+
+// SetCustomersActiveStatus activates or deactivates customers in bulk
+// @Summary Admin Bulk Set Customers Active Status
+// @Description Activate or deactivate up to 100 customers. Each customer is handled on its own exactly like the single active-status endpoint; deactivated customers are logged out. Customers already in the requested state succeed with changed=false.
+// @Tags Admin Bulk Operations
+// @Accept json
+// @Produce json
+// @Param request body dto.AdminBulkSetCustomersActiveStatusRequest true "Customers and target status"
+// @Success 200 {object} dto.APIResponse{data=dto.AdminBulkOperationResponse} "All customers handled"
+// @Success 207 {object} dto.APIResponse{data=dto.AdminBulkOperationResponse} "Some customers handled"
+// @Failure 400 {object} dto.APIResponse "Validation error, or no customer handled"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security AdminBearer
+// @Router /api/v1/admin/bulk/customers/active-status [post]
+func (h *AdminBulkOperationHandler) SetCustomersActiveStatus(c fiber.Ctx) error {
+	var req dto.AdminBulkSetCustomersActiveStatusRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body", "VALIDATION_ERROR", nil)
+	}
+	if err := h.validator.Struct(req); err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation error", "VALIDATION_ERROR", err.Error())
+	}
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/admin/bulk/customers/active-status", 2*time.Minute)
+	defer cancel()
+	res, err := h.flow.SetCustomersActiveStatus(ctx, &req)
+	if err != nil {
+		log.Println("Admin bulk set customers active status failed", err)
+		return h.respondBulkError(c, err, "Failed to set customers active status", "ADMIN_BULK_ACTIVE_STATUS_FAILED")
+	}
+	return h.respondBulkResult(c, res)
+}
+
+// ReassignAgency moves customers under another referrer agency in bulk
+// @Summary Admin Bulk Reassign Agency
+// @Description Move up to 100 customers under an active marketing agency. For each customer the previous agency's discount is expired, the new agency starts at the default signup discount and a delegation granted to the previous agency is revoked. Customers already under the agency succeed with changed=false.
+// @Tags Admin Bulk Operations
+// @Accept json
+// @Produce json
+// @Param request body dto.AdminBulkReassignAgencyRequest true "Customers and target agency"
+// @Success 200 {object} dto.APIResponse{data=dto.AdminBulkOperationResponse} "All customers handled"
+// @Success 207 {object} dto.APIResponse{data=dto.AdminBulkOperationResponse} "Some customers handled"
+// @Failure 400 {object} dto.APIResponse "Validation error, or no customer handled"
+// @Failure 403 {object} dto.APIResponse "Agency is inactive"
+// @Failure 404 {object} dto.APIResponse "Agency not found"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security AdminBearer
+// @Router /api/v1/admin/bulk/customers/agency [post]
+func (h *AdminBulkOperationHandler) ReassignAgency(c fiber.Ctx) error {
+	var req dto.AdminBulkReassignAgencyRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body", "VALIDATION_ERROR", nil)
+	}
+	if err := h.validator.Struct(req); err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation error", "VALIDATION_ERROR", err.Error())
+	}
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/admin/bulk/customers/agency", 2*time.Minute)
+	defer cancel()
+	res, err := h.flow.ReassignAgency(ctx, &req)
+	if err != nil {
+		log.Println("Admin bulk agency reassignment failed", err)
+		return h.respondBulkError(c, err, "Failed to reassign agency", "ADMIN_BULK_REASSIGN_AGENCY_FAILED")
+	}
+	return h.respondBulkResult(c, res)
+}
+
+// ResyncCryptoPayments re-runs crypto payment callbacks in bulk
+// @Summary Admin Bulk Resync Crypto Payments
+// @Description Re-run the provider callback handling of up to 100 crypto payment requests: each request's state and deposits are fetched from the provider and its owner's wallet is credited once they settle it, exactly like a webhook. Requests already credited are not credited again.
+// @Tags Admin Bulk Operations
+// @Accept json
+// @Produce json
+// @Param request body dto.AdminBulkResyncCryptoPaymentsRequest true "Crypto payment request UUIDs"
+// @Success 200 {object} dto.APIResponse{data=dto.AdminBulkOperationResponse} "All requests resynced"
+// @Success 207 {object} dto.APIResponse{data=dto.AdminBulkOperationResponse} "Some requests resynced"
+// @Failure 400 {object} dto.APIResponse "Validation error, or no request resynced"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security AdminBearer
+// @Router /api/v1/admin/bulk/crypto-payments/resync [post]
+func (h *AdminBulkOperationHandler) ResyncCryptoPayments(c fiber.Ctx) error {
+	var req dto.AdminBulkResyncCryptoPaymentsRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body", "VALIDATION_ERROR", nil)
+	}
+	if err := h.validator.Struct(req); err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation error", "VALIDATION_ERROR", err.Error())
+	}
+	metadata := businessflow.NewClientMetadata(c.IP(), c.Get("User-Agent"))
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/admin/bulk/crypto-payments/resync", 5*time.Minute)
+	defer cancel()
+	res, err := h.flow.ResyncCryptoPayments(ctx, &req, metadata)
+	if err != nil {
+		log.Println("Admin bulk crypto payment resync failed", err)
+		return h.respondBulkError(c, err, "Failed to resync crypto payments", "ADMIN_BULK_CRYPTO_RESYNC_FAILED")
+	}
+	return h.respondBulkResult(c, res)
+}
+
+func (h *AdminBulkOperationHandler) respondBulkResult(c fiber.Ctx, res *dto.AdminBulkOperationResponse) error {
+	switch {
+	case res.Succeeded == 0:
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "No item succeeded", "BULK_OPERATION_REJECTED", res)
+	case res.Failed > 0:
+		return h.SuccessResponse(c, fiber.StatusMultiStatus, res.Message, res)
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, res.Message, res)
+}
+
+func (h *AdminBulkOperationHandler) respondBulkError(c fiber.Ctx, err error, defaultMessage, defaultCode string) error {
+	switch {
+	case businessflow.IsAdminBulkCountInvalid(err):
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Bulk operations take 1 to 100 items", "ADMIN_BULK_COUNT_INVALID", nil)
+	case businessflow.IsAgencyNotFound(err):
+		return h.ErrorResponse(c, fiber.StatusNotFound, "Agency not found", "AGENCY_NOT_FOUND", nil)
+	case businessflow.IsAgencyInactive(err):
+		return h.ErrorResponse(c, fiber.StatusForbidden, "Agency is inactive", "AGENCY_INACTIVE", nil)
+	}
+	return h.ErrorResponse(c, fiber.StatusInternalServerError, defaultMessage, defaultCode, nil)
+}
+
+func (h *AdminBulkOperationHandler) createRequestContextWithTimeout(c fiber.Ctx, endpoint string, timeout time.Duration) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	ctx = context.WithValue(ctx, utils.RequestIDKey, c.Get("X-Request-ID"))
+	ctx = context.WithValue(ctx, utils.UserAgentKey, c.Get("User-Agent"))
+	ctx = context.WithValue(ctx, utils.IPAddressKey, c.IP())
+	ctx = context.WithValue(ctx, utils.EndpointKey, endpoint)
+	ctx = context.WithValue(ctx, utils.TimeoutKey, timeout)
+	ctx = context.WithValue(ctx, utils.CancelFuncKey, cancel)
+	if adminID, ok := middleware.GetAdminIDFromContext(c); ok {
+		ctx = context.WithValue(ctx, utils.AdminIDKey, adminID)
+	}
+	return ctx, cancel
+}
@@ -5,11 +5,14 @@ import (
 	"errors"
 	"log"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/amirphl/Yamata-no-Orochi/app/apierror"
 	"github.com/amirphl/Yamata-no-Orochi/app/dto"
 	"github.com/amirphl/Yamata-no-Orochi/app/middleware"
 	businessflow "github.com/amirphl/Yamata-no-Orochi/business_flow"
+	"github.com/amirphl/Yamata-no-Orochi/listquery"
 	"github.com/amirphl/Yamata-no-Orochi/utils"
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v3"
@@ -17,10 +20,16 @@ import (
 
 type AdminCustomerManagementHandlerInterface interface {
 	ListCustomers(c fiber.Ctx) error
+	SearchCustomers(c fiber.Ctx) error
 	GetCustomersShares(c fiber.Ctx) error
 	GetCustomerWithCampaigns(c fiber.Ctx) error
 	SetCustomerActiveStatus(c fiber.Ctx) error
+	SetCustomerSuspension(c fiber.Ctx) error
 	GetCustomerDiscountsHistory(c fiber.Ctx) error
+	GetCustomerSendingQuota(c fiber.Ctx) error
+	SetCustomerSendingQuota(c fiber.Ctx) error
+	ForceLogoutCustomer(c fiber.Ctx) error
+	ImpersonateCustomer(c fiber.Ctx) error
 }
 
 type AdminCustomerManagementHandler struct {
@@ -33,7 +42,7 @@ func NewAdminCustomerManagementHandler(flow businessflow.AdminCustomerManagement
 }
 
 func (h *AdminCustomerManagementHandler) ErrorResponse(c fiber.Ctx, statusCode int, message, errorCode string, details any) error {
-	return c.Status(statusCode).JSON(dto.APIResponse{Success: false, Message: message, Error: dto.ErrorDetail{Code: errorCode, Details: details}})
+	return apierror.Respond(c, statusCode, message, errorCode, details)
 }
 
 func (h *AdminCustomerManagementHandler) SuccessResponse(c fiber.Ctx, statusCode int, message string, data any) error {
@@ -42,20 +51,80 @@ func (h *AdminCustomerManagementHandler) SuccessResponse(c fiber.Ctx, statusCode
 
 // ListCustomers returns all customers except tax and system users
 // @Summary Admin List Customers
+// @Description Filter with field:operator:value conditions separated by semicolons over id, email, mobile, first_name, last_name, company_name, national_id, account_type_id, referrer_agency_id, is_active, is_sandbox, is_email_verified, is_mobile_verified, created_at and last_login_at. Operators are eq, ne, gt, gte, lt, lte, in, nin (comma separated values), contains and null (true or false).
 // @Tags Admin Customer Management
 // @Produce json
+// @Param filter query string false "List filter, e.g. is_active:eq:true;created_at:gte:2026-01-01T00:00:00Z"
+// @Param sort query string false "Sort fields, minus for descending, e.g. -last_login_at,id"
+// @Param fields query string false "Item fields to return, e.g. id,email,is_active"
 // @Success 200 {object} dto.APIResponse{data=dto.AdminListCustomersResponse}
+// @Failure 400 {object} dto.APIResponse "Invalid filter, sort or fields"
 // @Failure 500 {object} dto.APIResponse
+// @Security AdminBearer
 // @Router /api/v1/admin/customer-management [get]
 func (h *AdminCustomerManagementHandler) ListCustomers(c fiber.Ctx) error {
+	req := dto.AdminListCustomersRequest{Filter: c.Query("filter"), Sort: c.Query("sort")}
+	if err := h.validator.Struct(req); err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", nil)
+	}
+	fields, err := listquery.ParseFieldSet(c.Query("fields"), dto.AdminCustomerDetailDTO{})
+	if err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid filter, sort or fields", "LIST_QUERY_INVALID", err.Error())
+	}
+
 	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/admin/customer-management", 30*time.Second)
 	defer cancel()
-	res, err := h.flow.ListCustomers(ctx)
+	res, err := h.flow.ListCustomers(ctx, &req)
 	if err != nil {
+		if businessflow.IsListQueryInvalid(err) {
+			return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid filter, sort or fields", "LIST_QUERY_INVALID", err.Error())
+		}
 		log.Println("Admin list customers failed", err)
 		return h.respondAdminCustomerManagementError(c, err, "Failed to retrieve customers list", "GET_ADMIN_CUSTOMERS_LIST_FAILED")
 	}
-	return h.SuccessResponse(c, fiber.StatusOK, "Customers retrieved successfully", res)
+	data, err := fields.Project(res)
+	if err != nil {
+		log.Println("Admin list customers failed", err)
+		return h.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to retrieve customers list", "GET_ADMIN_CUSTOMERS_LIST_FAILED", nil)
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, "Customers retrieved successfully", data)
+}
+
+// SearchCustomers returns customers matching a search term, best match first
+// @Summary Admin Search Customers
+// @Description Search customers by name, company, email, mobile, national ID or UUID. Substrings and word prefixes match; exact email, mobile, national ID and UUID matches rank first. System, tax and anonymized accounts are not returned.
+// @Tags Admin Customer Management
+// @Produce json
+// @Param q query string true "Search term, 3 to 100 characters"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Page size" default(10) maximum(100)
+// @Success 200 {object} dto.APIResponse{data=dto.AdminSearchCustomersResponse}
+// @Failure 400 {object} dto.APIResponse "Validation error"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security AdminBearer
+// @Router /api/v1/admin/customer-management/search [get]
+func (h *AdminCustomerManagementHandler) SearchCustomers(c fiber.Ctx) error {
+	page, err := strconv.Atoi(c.Query("page", "1"))
+	if err != nil || page <= 0 {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid page format", "INVALID_PAGE", nil)
+	}
+	limit, err := strconv.Atoi(c.Query("limit", "10"))
+	if err != nil || limit <= 0 {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid limit format", "INVALID_LIMIT", nil)
+	}
+	req := dto.AdminSearchCustomersRequest{Query: strings.TrimSpace(c.Query("q")), Page: page, Limit: limit}
+	if err := h.validator.Struct(req); err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "q must be 3 to 100 characters and limit at most 100", "VALIDATION_ERROR", nil)
+	}
+
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/admin/customer-management/search", 30*time.Second)
+	defer cancel()
+	res, err := h.flow.SearchCustomers(ctx, &req)
+	if err != nil {
+		log.Println("Admin search customers failed", err)
+		return h.respondAdminCustomerManagementError(c, err, "Failed to search customers", "ADMIN_SEARCH_CUSTOMERS_FAILED")
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, res.Message, res)
 }
 
 // GetCustomersShares returns aggregated shares per customer
@@ -68,6 +137,7 @@ func (h *AdminCustomerManagementHandler) ListCustomers(c fiber.Ctx) error {
 // @Failure 400 {object} dto.APIResponse
 // @Failure 401 {object} dto.APIResponse
 // @Failure 500 {object} dto.APIResponse
+// @Security AdminBearer
 // @Router /api/v1/admin/customer-management/shares [get]
 func (h *AdminCustomerManagementHandler) GetCustomersShares(c fiber.Ctx) error {
 	var req dto.AdminCustomersSharesRequest
@@ -103,6 +173,7 @@ func (h *AdminCustomerManagementHandler) GetCustomersShares(c fiber.Ctx) error {
 // @Failure 400 {object} dto.APIResponse
 // @Failure 404 {object} dto.APIResponse
 // @Failure 500 {object} dto.APIResponse
+// @Security AdminBearer
 // @Router /api/v1/admin/customer-management/{customer_id} [get]
 func (h *AdminCustomerManagementHandler) GetCustomerWithCampaigns(c fiber.Ctx) error {
 	cidStr := c.Params("customer_id")
@@ -131,6 +202,7 @@ func (h *AdminCustomerManagementHandler) GetCustomerWithCampaigns(c fiber.Ctx) e
 // @Failure 404 {object} dto.APIResponse
 // @Failure 403 {object} dto.APIResponse
 // @Failure 500 {object} dto.APIResponse
+// @Security AdminBearer
 // @Router /api/v1/admin/customer-management/active-status [post]
 func (h *AdminCustomerManagementHandler) SetCustomerActiveStatus(c fiber.Ctx) error {
 	var req dto.AdminSetCustomerActiveStatusRequest
@@ -159,6 +231,7 @@ func (h *AdminCustomerManagementHandler) SetCustomerActiveStatus(c fiber.Ctx) er
 // @Failure 400 {object} dto.APIResponse
 // @Failure 404 {object} dto.APIResponse
 // @Failure 500 {object} dto.APIResponse
+// @Security AdminBearer
 // @Router /api/v1/admin/customer-management/{customer_id}/discounts [get]
 func (h *AdminCustomerManagementHandler) GetCustomerDiscountsHistory(c fiber.Ctx) error {
 	cidStr := c.Params("customer_id")
@@ -176,6 +249,166 @@ func (h *AdminCustomerManagementHandler) GetCustomerDiscountsHistory(c fiber.Ctx
 	return h.SuccessResponse(c, fiber.StatusOK, "Customer discounts history retrieved successfully", res)
 }
 
+// GetCustomerSendingQuota returns a customer's sending quota and its consumption
+// @Summary Admin Get Customer Sending Quota
+// @Tags Admin Customer Management
+// @Produce json
+// @Param customer_id path int true "Customer ID"
+// @Success 200 {object} dto.APIResponse{data=dto.AdminCustomerSendingQuotaResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Failure 404 {object} dto.APIResponse
+// @Failure 500 {object} dto.APIResponse
+// @Security AdminBearer
+// @Router /api/v1/admin/customer-management/{customer_id}/sending-quota [get]
+func (h *AdminCustomerManagementHandler) GetCustomerSendingQuota(c fiber.Ctx) error {
+	cidStr := c.Params("customer_id")
+	cid, err := strconv.ParseUint(cidStr, 10, 64)
+	if err != nil || cid == 0 {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid customer_id", "VALIDATION_ERROR", nil)
+	}
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/admin/customer-management/"+cidStr+"/sending-quota", 30*time.Second)
+	defer cancel()
+	res, err := h.flow.GetCustomerSendingQuota(ctx, uint(cid))
+	if err != nil {
+		log.Println("Admin get customer sending quota failed", err)
+		return h.respondAdminCustomerManagementError(c, err, "Failed to get customer sending quota", "GET_CUSTOMER_SENDING_QUOTA_FAILED")
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, "Customer sending quota retrieved successfully", res)
+}
+
+// SetCustomerSendingQuota sets a customer's daily and monthly sending limits
+// and SMS frequency cap
+// @Summary Admin Set Customer Sending Quota
+// @Description Set the maximum number of recipients the customer may message per Tehran calendar day and month. Omitted or null limits are unlimited. An optional frequency_cap_hours (1 to 720) skips SMS recipients another campaign of the customer messaged within that many hours. Finalizing a campaign that does not fit in the remaining quota is rejected, and campaigns sent after the quota is exhausted are truncated. Limits above the unverified KYC cap are rejected with KYC_VERIFICATION_REQUIRED until the company's documents are verified.
+// @Tags Admin Customer Management
+// @Accept json
+// @Produce json
+// @Param customer_id path int true "Customer ID"
+// @Param body body dto.AdminSetCustomerSendingQuotaRequest true "Sending limits"
+// @Success 200 {object} dto.APIResponse{data=dto.AdminCustomerSendingQuotaResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Failure 404 {object} dto.APIResponse
+// @Failure 409 {object} dto.APIResponse "Limits above the cap of an unverified company"
+// @Failure 500 {object} dto.APIResponse
+// @Security AdminBearer
+// @Router /api/v1/admin/customer-management/{customer_id}/sending-quota [put]
+func (h *AdminCustomerManagementHandler) SetCustomerSendingQuota(c fiber.Ctx) error {
+	cidStr := c.Params("customer_id")
+	cid, err := strconv.ParseUint(cidStr, 10, 64)
+	if err != nil || cid == 0 {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid customer_id", "VALIDATION_ERROR", nil)
+	}
+	var req dto.AdminSetCustomerSendingQuotaRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body", "VALIDATION_ERROR", nil)
+	}
+	req.CustomerID = uint(cid)
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/admin/customer-management/"+cidStr+"/sending-quota", 30*time.Second)
+	defer cancel()
+	res, err := h.flow.SetCustomerSendingQuota(ctx, &req)
+	if err != nil {
+		log.Println("Admin set customer sending quota failed", err)
+		return h.respondAdminCustomerManagementError(c, err, "Failed to set customer sending quota", "SET_CUSTOMER_SENDING_QUOTA_FAILED")
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, "Customer sending quota updated successfully", res)
+}
+
+// SetCustomerSuspension suspends a customer or lifts their suspension
+// @Summary Admin Set Customer Suspension
+// @Description Suspend a customer without deactivating them: they can still log in and view their data. Levels are graduated: warning restricts nothing and only shows the reason to the customer, restricted blocks creating, cloning and submitting campaigns, and suspended also blocks wallet recharges. Blocked requests fail with CUSTOMER_SUSPENDED and the level and reason code. Reasons are policy_violation, spam_complaints, payment_dispute, fraud_review and verification_required. An empty level lifts the suspension.
+// @Tags Admin Customer Management
+// @Accept json
+// @Produce json
+// @Param customer_id path int true "Customer ID"
+// @Param body body dto.AdminSetCustomerSuspensionRequest true "Suspension level and reason"
+// @Success 200 {object} dto.APIResponse{data=dto.AdminSetCustomerSuspensionResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Failure 403 {object} dto.APIResponse "System and tax users cannot be suspended"
+// @Failure 404 {object} dto.APIResponse
+// @Failure 500 {object} dto.APIResponse
+// @Security AdminBearer
+// @Router /api/v1/admin/customer-management/{customer_id}/suspension [put]
+func (h *AdminCustomerManagementHandler) SetCustomerSuspension(c fiber.Ctx) error {
+	cidStr := c.Params("customer_id")
+	cid, err := strconv.ParseUint(cidStr, 10, 64)
+	if err != nil || cid == 0 {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid customer_id", "VALIDATION_ERROR", nil)
+	}
+	var req dto.AdminSetCustomerSuspensionRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body", "VALIDATION_ERROR", nil)
+	}
+	if err := h.validator.Struct(req); err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation error", "VALIDATION_ERROR", err.Error())
+	}
+	req.CustomerID = uint(cid)
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/admin/customer-management/"+cidStr+"/suspension", 30*time.Second)
+	defer cancel()
+	res, err := h.flow.SetCustomerSuspension(ctx, &req)
+	if err != nil {
+		log.Println("Admin set customer suspension failed", err)
+		return h.respondAdminCustomerManagementError(c, err, "Failed to set customer suspension", "SET_CUSTOMER_SUSPENSION_FAILED")
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, res.Message, res)
+}
+
+// ForceLogoutCustomer ends every active session of a customer
+// @Summary Admin Force Logout Customer
+// @Description End all sessions of the customer. Their access tokens are rejected from the next request on.
+// @Tags Admin Customer Management
+// @Produce json
+// @Param customer_id path int true "Customer ID"
+// @Success 200 {object} dto.APIResponse{data=dto.AdminForceLogoutCustomerResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Failure 404 {object} dto.APIResponse
+// @Failure 500 {object} dto.APIResponse
+// @Security AdminBearer
+// @Router /api/v1/admin/customer-management/{customer_id}/force-logout [post]
+func (h *AdminCustomerManagementHandler) ForceLogoutCustomer(c fiber.Ctx) error {
+	cidStr := c.Params("customer_id")
+	cid, err := strconv.ParseUint(cidStr, 10, 64)
+	if err != nil || cid == 0 {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid customer_id", "VALIDATION_ERROR", nil)
+	}
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/admin/customer-management/"+cidStr+"/force-logout", 30*time.Second)
+	defer cancel()
+	res, err := h.flow.ForceLogoutCustomer(ctx, uint(cid))
+	if err != nil {
+		log.Println("Admin force logout customer failed", err)
+		return h.respondAdminCustomerManagementError(c, err, "Failed to end customer sessions", "FORCE_LOGOUT_CUSTOMER_FAILED")
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, "Customer sessions ended successfully", res)
+}
+
+// ImpersonateCustomer issues a short-lived access token of the customer to the admin
+// @Summary Admin Impersonate Customer
+// @Description Issue an access token of the customer so support staff see what the customer sees. The token has no refresh token, expires after ADMIN_IMPERSONATION_TTL and carries the admin in its impersonated_by claim. Responses to it have an X-Impersonated-By header, the customer's session list shows it as impersonated, and audit logs written under it record both the admin and the customer. System, tax and inactive customers cannot be impersonated.
+// @Tags Admin Customer Management
+// @Produce json
+// @Param id path int true "Customer ID"
+// @Success 200 {object} dto.APIResponse{data=dto.AdminImpersonateCustomerResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Failure 403 {object} dto.APIResponse
+// @Failure 404 {object} dto.APIResponse
+// @Failure 500 {object} dto.APIResponse
+// @Security AdminBearer
+// @Router /api/v1/admin/customers/{id}/impersonate [post]
+func (h *AdminCustomerManagementHandler) ImpersonateCustomer(c fiber.Ctx) error {
+	cidStr := c.Params("id")
+	cid, err := strconv.ParseUint(cidStr, 10, 64)
+	if err != nil || cid == 0 {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid customer id", "VALIDATION_ERROR", nil)
+	}
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/admin/customers/"+cidStr+"/impersonate", 30*time.Second)
+	defer cancel()
+	res, err := h.flow.ImpersonateCustomer(ctx, uint(cid))
+	if err != nil {
+		log.Println("Admin impersonate customer failed", err)
+		return h.respondAdminCustomerManagementError(c, err, "Failed to impersonate customer", "IMPERSONATE_CUSTOMER_FAILED")
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, "Impersonation token issued successfully", res)
+}
+
 func (h *AdminCustomerManagementHandler) respondAdminCustomerManagementError(
 	c fiber.Ctx,
 	err error,
@@ -198,12 +431,24 @@ func (h *AdminCustomerManagementHandler) respondAdminCustomerManagementError(
 			return h.ErrorResponse(c, fiber.StatusNotFound, "Customer not found", be.Code, nil)
 		case "FORBIDDEN_OPERATION":
 			return h.ErrorResponse(c, fiber.StatusForbidden, be.Message, be.Code, nil)
+		case "CUSTOMER_SUSPENSION_INVALID":
+			return h.ErrorResponse(c, fiber.StatusBadRequest, be.Message, be.Code, nil)
+		case "SENDING_QUOTA_INVALID":
+			return h.ErrorResponse(c, fiber.StatusBadRequest, be.Message, be.Code, nil)
+		case "KYC_VERIFICATION_REQUIRED":
+			return h.ErrorResponse(c, fiber.StatusConflict, be.Message, be.Code, nil)
 		case "GET_ADMIN_CUSTOMERS_SHARES_FAILED",
 			"GET_ADMIN_CUSTOMERS_LIST_FAILED",
+			"ADMIN_SEARCH_CUSTOMERS_FAILED",
 			"GET_ADMIN_CUSTOMER_FAILED",
 			"GET_ADMIN_CUSTOMER_CAMPAIGNS_FAILED",
 			"GET_ADMIN_CUSTOMER_DISCOUNTS_HISTORY_FAILED",
-			"SET_CUSTOMER_ACTIVE_STATUS_FAILED":
+			"SET_CUSTOMER_ACTIVE_STATUS_FAILED",
+			"GET_CUSTOMER_SENDING_QUOTA_FAILED",
+			"SET_CUSTOMER_SENDING_QUOTA_FAILED",
+			"SET_CUSTOMER_SUSPENSION_FAILED",
+			"FORCE_LOGOUT_CUSTOMER_FAILED",
+			"IMPERSONATE_CUSTOMER_FAILED":
 			return h.ErrorResponse(c, fiber.StatusInternalServerError, be.Message, be.Code, nil)
 		}
 	}
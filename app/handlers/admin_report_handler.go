@@ -0,0 +1,198 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/apierror"
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/app/middleware"
+	businessflow "github.com/amirphl/Yamata-no-Orochi/business_flow"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v3"
+)
+
+// AdminReportHandlerInterface defines the admin financial dashboard endpoints
+type AdminReportHandlerInterface interface {
+	GetFinancialReport(c fiber.Ctx) error
+	GetTopCustomers(c fiber.Ctx) error
+	GetWalletLiability(c fiber.Ctx) error
+	RefreshRollups(c fiber.Ctx) error
+}
+
+// AdminReportHandler implements the admin financial dashboard endpoints
+type AdminReportHandler struct {
+	flow       businessflow.AdminReportFlow
+	rollupFlow businessflow.ReportRollupFlow
+	validator  *validator.Validate
+}
+
+func NewAdminReportHandler(flow businessflow.AdminReportFlow, rollupFlow businessflow.ReportRollupFlow) AdminReportHandlerInterface {
+	return &AdminReportHandler{
+		flow:       flow,
+		rollupFlow: rollupFlow,
+		validator:  validator.New(),
+	}
+}
+
+func (h *AdminReportHandler) ErrorResponse(c fiber.Ctx, status int, message, code string, details any) error {
+	return apierror.Respond(c, status, message, code, details)
+}
+
+func (h *AdminReportHandler) SuccessResponse(c fiber.Ctx, status int, message string, data any) error {
+	return c.Status(status).JSON(dto.APIResponse{Success: true, Message: message, Data: data})
+}
+
+// GetFinancialReport returns the platform's money flows per day or week
+// @Summary Admin Financial Report
+// @Description Revenue with tax, tax collected, system and agency shares and campaign spend per Tehran day or week (weeks start on Saturday), with totals. Without dates the report covers the last 30 days, or the last 12 weeks when weekly. The range is capped at one year. Ranges of whole Tehran days are read from the revenue_daily rollup when it has been refreshed through them, and refreshed_at tells when.
+// @Tags Admin Reports
+// @Produce json
+// @Param granularity query string false "daily|weekly" default(daily)
+// @Param start_date query string false "Filter created_at >= start_date (RFC3339)"
+// @Param end_date query string false "Filter created_at < end_date (RFC3339)"
+// @Success 200 {object} dto.APIResponse{data=dto.AdminFinancialReportResponse}
+// @Failure 400 {object} dto.APIResponse "Validation error"
+// @Failure 401 {object} dto.APIResponse "Unauthorized"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security AdminBearer
+// @Router /api/v1/admin/reports/financial [get]
+func (h *AdminReportHandler) GetFinancialReport(c fiber.Ctx) error {
+	req := dto.AdminFinancialReportRequest{Granularity: strings.TrimSpace(c.Query("granularity"))}
+	req.StartDate, req.EndDate = adminReportRange(c)
+	if err := h.validator.Struct(req); err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "granularity must be daily or weekly", "VALIDATION_ERROR", nil)
+	}
+
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/admin/reports/financial", 30*time.Second)
+	defer cancel()
+	res, err := h.flow.GetFinancialReport(ctx, &req)
+	if err != nil {
+		return h.handleFlowError(c, "Failed to retrieve financial report", "ADMIN_FINANCIAL_REPORT_FAILED", err)
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, "Financial report retrieved successfully", res)
+}
+
+// GetTopCustomers returns the customers who paid the most
+// @Summary Admin Top Customers Report
+// @Description Customers ranked by what they paid for wallet charges in the range, with their campaign spend. Without dates the report covers the last 30 days.
+// @Tags Admin Reports
+// @Produce json
+// @Param start_date query string false "Filter created_at >= start_date (RFC3339)"
+// @Param end_date query string false "Filter created_at < end_date (RFC3339)"
+// @Param limit query int false "Number of customers" default(10) maximum(100)
+// @Success 200 {object} dto.APIResponse{data=dto.AdminTopCustomersReportResponse}
+// @Failure 400 {object} dto.APIResponse "Validation error"
+// @Failure 401 {object} dto.APIResponse "Unauthorized"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security AdminBearer
+// @Router /api/v1/admin/reports/top-customers [get]
+func (h *AdminReportHandler) GetTopCustomers(c fiber.Ctx) error {
+	limit, err := strconv.Atoi(c.Query("limit", "10"))
+	if err != nil || limit <= 0 {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid limit format", "INVALID_LIMIT", nil)
+	}
+	req := dto.AdminTopCustomersReportRequest{Limit: limit}
+	req.StartDate, req.EndDate = adminReportRange(c)
+	if err := h.validator.Struct(req); err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "limit must be between 1 and 100", "VALIDATION_ERROR", nil)
+	}
+
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/admin/reports/top-customers", 30*time.Second)
+	defer cancel()
+	res, err := h.flow.GetTopCustomers(ctx, &req)
+	if err != nil {
+		return h.handleFlowError(c, "Failed to retrieve top customers report", "ADMIN_TOP_CUSTOMERS_REPORT_FAILED", err)
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, "Top customers report retrieved successfully", res)
+}
+
+// GetWalletLiability returns what the platform owes customers and agencies
+// @Summary Admin Wallet Liability
+// @Description Current free, frozen, locked, credit and agency share balances summed over every wallet but the system and tax wallets. Credit is discount credit and is not counted in total_liability.
+// @Tags Admin Reports
+// @Produce json
+// @Success 200 {object} dto.APIResponse{data=dto.AdminWalletLiabilityResponse}
+// @Failure 401 {object} dto.APIResponse "Unauthorized"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security AdminBearer
+// @Router /api/v1/admin/reports/wallet-liability [get]
+func (h *AdminReportHandler) GetWalletLiability(c fiber.Ctx) error {
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/admin/reports/wallet-liability", 30*time.Second)
+	defer cancel()
+	res, err := h.flow.GetWalletLiability(ctx)
+	if err != nil {
+		return h.handleFlowError(c, "Failed to retrieve wallet liability", "ADMIN_WALLET_LIABILITY_REPORT_FAILED", err)
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, "Wallet liability retrieved successfully", res)
+}
+
+// RefreshRollups re-rolls the reporting rollups for a range of days
+// @Summary Refresh Reporting Rollups
+// @Description Rebuild the campaign_daily_stats, revenue_daily and customer_monthly_usage rollups for the Tehran days from..to (both inclusive, at most 31 days) and the months they fall in. skipped is true when another instance was refreshing.
+// @Tags Admin Reports
+// @Accept json
+// @Produce json
+// @Param request body dto.AdminReportRollupRefreshRequest true "Days to refresh"
+// @Success 200 {object} dto.APIResponse{data=dto.ReportRollupRefreshSummary}
+// @Failure 400 {object} dto.APIResponse "Validation error"
+// @Failure 401 {object} dto.APIResponse "Unauthorized"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security AdminBearer
+// @Router /api/v1/admin/reports/rollups/refresh [post]
+func (h *AdminReportHandler) RefreshRollups(c fiber.Ctx) error {
+	var req dto.AdminReportRollupRefreshRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body", "INVALID_REQUEST", nil)
+	}
+	if err := h.validator.Struct(req); err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "from and to must be YYYY-MM-DD dates", "VALIDATION_ERROR", nil)
+	}
+
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/admin/reports/rollups/refresh", 10*time.Minute)
+	defer cancel()
+	res, err := h.rollupFlow.RefreshOnDemand(ctx, &req)
+	if err != nil {
+		return h.handleFlowError(c, "Failed to refresh reporting rollups", "REPORT_ROLLUP_REFRESH_FAILED", err)
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, "Reporting rollups refreshed successfully", res)
+}
+
+// adminReportRange reads the optional start_date and end_date query parameters
+func adminReportRange(c fiber.Ctx) (start, end *string) {
+	if v := strings.TrimSpace(c.Query("start_date")); v != "" {
+		start = &v
+	}
+	if v := strings.TrimSpace(c.Query("end_date")); v != "" {
+		end = &v
+	}
+	return start, end
+}
+
+func (h *AdminReportHandler) handleFlowError(c fiber.Ctx, message, code string, err error) error {
+	var be *businessflow.BusinessError
+	if errors.As(err, &be) && be.Code == "VALIDATION_ERROR" {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, be.Message, be.Code, nil)
+	}
+	log.Println(message+":", err)
+	return h.ErrorResponse(c, fiber.StatusInternalServerError, message, code, nil)
+}
+
+func (h *AdminReportHandler) createRequestContextWithTimeout(c fiber.Ctx, endpoint string, timeout time.Duration) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	ctx = context.WithValue(ctx, utils.RequestIDKey, c.Get("X-Request-ID"))
+	ctx = context.WithValue(ctx, utils.UserAgentKey, c.Get("User-Agent"))
+	ctx = context.WithValue(ctx, utils.IPAddressKey, c.IP())
+	ctx = context.WithValue(ctx, utils.EndpointKey, endpoint)
+	ctx = context.WithValue(ctx, utils.TimeoutKey, timeout)
+	ctx = context.WithValue(ctx, utils.CancelFuncKey, cancel)
+	if adminID, ok := middleware.GetAdminIDFromContext(c); ok {
+		ctx = context.WithValue(ctx, utils.AdminIDKey, adminID)
+	}
+	return ctx, cancel
+}
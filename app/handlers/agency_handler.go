@@ -2,11 +2,14 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"log"
 	"strconv"
 	"time"
 
+	"github.com/amirphl/Yamata-no-Orochi/app/apierror"
 	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/app/middleware"
 	businessflow "github.com/amirphl/Yamata-no-Orochi/business_flow"
 	"github.com/amirphl/Yamata-no-Orochi/utils"
 	"github.com/go-playground/validator/v10"
@@ -18,7 +21,14 @@ type AgencyHandlerInterface interface {
 	GetAgencyCustomerReport(c fiber.Ctx) error
 	ListAgencyActiveDiscounts(c fiber.Ctx) error
 	ListAgencyCustomerDiscounts(c fiber.Ctx) error
+	ListAgencyDiscountSchedule(c fiber.Ctx) error
 	ListAgencyCustomers(c fiber.Ctx) error
+	GetAgencyCommissionDashboard(c fiber.Ctx) error
+	ExportAgencyCommissionReport(c fiber.Ctx) error
+	GrantAgencyDelegation(c fiber.Ctx) error
+	RevokeAgencyDelegation(c fiber.Ctx) error
+	GetAgencyDelegation(c fiber.Ctx) error
+	ListAgencyDelegations(c fiber.Ctx) error
 }
 
 type AgencyHandler struct {
@@ -31,11 +41,7 @@ func NewAgencyHandler(flow businessflow.AgencyFlow) *AgencyHandler {
 }
 
 func (h *AgencyHandler) ErrorResponse(c fiber.Ctx, statusCode int, message, errorCode string, details any) error {
-	return c.Status(statusCode).JSON(dto.APIResponse{
-		Success: false,
-		Message: message,
-		Error:   dto.ErrorDetail{Code: errorCode, Details: details},
-	})
+	return apierror.Respond(c, statusCode, message, errorCode, details)
 }
 
 func (h *AgencyHandler) SuccessResponse(c fiber.Ctx, statusCode int, message string, data any) error {
@@ -44,6 +50,7 @@ func (h *AgencyHandler) SuccessResponse(c fiber.Ctx, statusCode int, message str
 
 // CreateAgencyDiscount creates a new discount for a customer under the agency
 // @Summary Create Agency Discount
+// @Description Replaces the customer's discount now, or from effective_from when it is set. Optional tiers raise the rate for charges made after the customer's charges of the Tehran month pass their thresholds.
 // @Tags Reports
 // @Accept json
 // @Produce json
@@ -52,6 +59,7 @@ func (h *AgencyHandler) SuccessResponse(c fiber.Ctx, statusCode int, message str
 // @Failure 400 {object} dto.APIResponse
 // @Failure 401 {object} dto.APIResponse
 // @Failure 500 {object} dto.APIResponse
+// @Security CustomerBearer
 // @Router /api/v1/reports/agency/discounts [post]
 func (h *AgencyHandler) CreateAgencyDiscount(c fiber.Ctx) error {
 	agencyID, ok := c.Locals("customer_id").(uint)
@@ -67,7 +75,7 @@ func (h *AgencyHandler) CreateAgencyDiscount(c fiber.Ctx) error {
 	if err := h.validator.Struct(&req); err != nil {
 		var validationErrors []string
 		for _, err := range err.(validator.ValidationErrors) {
-			validationErrors = append(validationErrors, getValidationErrorMessage(err))
+			validationErrors = append(validationErrors, getValidationErrorMessage(c, err))
 		}
 		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", validationErrors)
 	}
@@ -80,17 +88,23 @@ func (h *AgencyHandler) CreateAgencyDiscount(c fiber.Ctx) error {
 			return h.ErrorResponse(c, fiber.StatusNotFound, "Agency not found", "AGENCY_NOT_FOUND", nil)
 		}
 		if businessflow.IsAgencyInactive(err) {
-			return h.ErrorResponse(c, fiber.StatusBadRequest, "Agency is inactive", "AGENCY_INACTIVE", nil)
+			return h.ErrorResponse(c, fiber.StatusForbidden, "Agency is inactive", "AGENCY_INACTIVE", nil)
 		}
 		if businessflow.IsCustomerNotFound(err) {
 			return h.ErrorResponse(c, fiber.StatusNotFound, "Customer not found", "CUSTOMER_NOT_FOUND", nil)
 		}
 		if businessflow.IsAccountInactive(err) {
-			return h.ErrorResponse(c, fiber.StatusBadRequest, "Account is inactive", "ACCOUNT_INACTIVE", nil)
+			return h.ErrorResponse(c, fiber.StatusForbidden, "Account is inactive", "ACCOUNT_INACTIVE", nil)
 		}
 		if businessflow.IsDiscountRateOutOfRange(err) {
 			return h.ErrorResponse(c, fiber.StatusBadRequest, "Rate must be between 0 and 0.5", "DISCOUNT_RATE_OUT_OF_RANGE", nil)
 		}
+		if businessflow.IsDiscountTiersInvalid(err) {
+			return h.ErrorResponse(c, fiber.StatusBadRequest, "Tiers must have increasing thresholds and non-decreasing rates of at most 0.5", "DISCOUNT_TIERS_INVALID", nil)
+		}
+		if businessflow.IsDiscountEffectiveFromInvalid(err) {
+			return h.ErrorResponse(c, fiber.StatusBadRequest, "Effective date must be a future RFC3339 time", "DISCOUNT_EFFECTIVE_FROM_INVALID", nil)
+		}
 		if businessflow.IsAgencyCannotCreateDiscountForItself(err) {
 			return h.ErrorResponse(c, fiber.StatusBadRequest, "Agency cannot create discount for itself", "AGENCY_CANNOT_CREATE_DISCOUNT_FOR_ITSELF", nil)
 		}
@@ -119,6 +133,7 @@ func (h *AgencyHandler) CreateAgencyDiscount(c fiber.Ctx) error {
 // @Failure 400 {object} dto.APIResponse "Validation error"
 // @Failure 401 {object} dto.APIResponse "Unauthorized"
 // @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
 // @Router /api/v1/reports/agency/customers [get]
 func (h *AgencyHandler) GetAgencyCustomerReport(c fiber.Ctx) error {
 	// Authorization: require authenticated customer id and that customer is agency
@@ -162,7 +177,7 @@ func (h *AgencyHandler) GetAgencyCustomerReport(c fiber.Ctx) error {
 			return h.ErrorResponse(c, fiber.StatusNotFound, "Agency not found", "AGENCY_NOT_FOUND", nil)
 		}
 		if businessflow.IsAgencyInactive(err) {
-			return h.ErrorResponse(c, fiber.StatusBadRequest, "Agency is inactive", "AGENCY_INACTIVE", nil)
+			return h.ErrorResponse(c, fiber.StatusForbidden, "Agency is inactive", "AGENCY_INACTIVE", nil)
 		}
 
 		log.Println("Agency customer report retrieval failed", err)
@@ -179,6 +194,7 @@ func (h *AgencyHandler) GetAgencyCustomerReport(c fiber.Ctx) error {
 // @Success 200 {object} dto.APIResponse{data=dto.ListAgencyActiveDiscountsResponse}
 // @Failure 401 {object} dto.APIResponse
 // @Failure 500 {object} dto.APIResponse
+// @Security CustomerBearer
 // @Router /api/v1/reports/agency/discounts/active [get]
 func (h *AgencyHandler) ListAgencyActiveDiscounts(c fiber.Ctx) error {
 	agencyID, ok := c.Locals("customer_id").(uint)
@@ -204,7 +220,7 @@ func (h *AgencyHandler) ListAgencyActiveDiscounts(c fiber.Ctx) error {
 			return h.ErrorResponse(c, fiber.StatusNotFound, "Agency not found", "AGENCY_NOT_FOUND", nil)
 		}
 		if businessflow.IsAgencyInactive(err) {
-			return h.ErrorResponse(c, fiber.StatusBadRequest, "Agency is inactive", "AGENCY_INACTIVE", nil)
+			return h.ErrorResponse(c, fiber.StatusForbidden, "Agency is inactive", "AGENCY_INACTIVE", nil)
 		}
 
 		log.Println("List active discounts failed", err)
@@ -222,6 +238,7 @@ func (h *AgencyHandler) ListAgencyActiveDiscounts(c fiber.Ctx) error {
 // @Failure 400 {object} dto.APIResponse
 // @Failure 401 {object} dto.APIResponse
 // @Failure 500 {object} dto.APIResponse
+// @Security CustomerBearer
 // @Router /api/v1/reports/agency/customers/{customer_id}/discounts [get]
 func (h *AgencyHandler) ListAgencyCustomerDiscounts(c fiber.Ctx) error {
 	agencyID, ok := c.Locals("customer_id").(uint)
@@ -245,13 +262,13 @@ func (h *AgencyHandler) ListAgencyCustomerDiscounts(c fiber.Ctx) error {
 			return h.ErrorResponse(c, fiber.StatusNotFound, "Agency not found", "AGENCY_NOT_FOUND", nil)
 		}
 		if businessflow.IsAgencyInactive(err) {
-			return h.ErrorResponse(c, fiber.StatusBadRequest, "Agency is inactive", "AGENCY_INACTIVE", nil)
+			return h.ErrorResponse(c, fiber.StatusForbidden, "Agency is inactive", "AGENCY_INACTIVE", nil)
 		}
 		if businessflow.IsCustomerNotFound(err) {
 			return h.ErrorResponse(c, fiber.StatusNotFound, "Customer not found", "CUSTOMER_NOT_FOUND", nil)
 		}
 		if businessflow.IsAccountInactive(err) {
-			return h.ErrorResponse(c, fiber.StatusBadRequest, "Account is inactive", "ACCOUNT_INACTIVE", nil)
+			return h.ErrorResponse(c, fiber.StatusForbidden, "Account is inactive", "ACCOUNT_INACTIVE", nil)
 		}
 		if businessflow.IsAgencyCannotListDiscountsForItself(err) {
 			return h.ErrorResponse(c, fiber.StatusBadRequest, "Agency cannot list discounts for itself", "AGENCY_CANNOT_LIST_DISCOUNTS_FOR_ITSELF", nil)
@@ -267,6 +284,62 @@ func (h *AgencyHandler) ListAgencyCustomerDiscounts(c fiber.Ctx) error {
 	return h.SuccessResponse(c, fiber.StatusOK, "Customer discounts retrieved successfully", res)
 }
 
+// ListAgencyDiscountSchedule lists the past, current and scheduled discounts
+// of a customer
+// @Summary List Customer Discount Schedule
+// @Tags Reports
+// @Produce json
+// @Param customer_id path int true "Customer ID"
+// @Success 200 {object} dto.APIResponse{data=dto.ListAgencyDiscountScheduleResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Failure 401 {object} dto.APIResponse
+// @Failure 500 {object} dto.APIResponse
+// @Security CustomerBearer
+// @Router /api/v1/reports/agency/customers/{customer_id}/discounts/schedule [get]
+func (h *AgencyHandler) ListAgencyDiscountSchedule(c fiber.Ctx) error {
+	agencyID, ok := c.Locals("customer_id").(uint)
+	if !ok || agencyID == 0 {
+		return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer ID not found in context", "MISSING_CUSTOMER_ID", nil)
+	}
+	cidStr := c.Params("customer_id")
+	cid, err := strconv.ParseUint(cidStr, 10, 64)
+	if err != nil || cid == 0 {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid customer_id", "VALIDATION_ERROR", nil)
+	}
+	req := &dto.ListAgencyDiscountScheduleRequest{AgencyID: agencyID, CustomerID: uint(cid)}
+
+	metadata := businessflow.NewClientMetadata(c.IP(), c.Get("User-Agent"))
+
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/reports/agency/customers/"+cidStr+"/discounts/schedule", 30*time.Second)
+	defer cancel()
+	res, err := h.flow.ListAgencyDiscountSchedule(ctx, req, metadata)
+	if err != nil {
+		if businessflow.IsAgencyNotFound(err) {
+			return h.ErrorResponse(c, fiber.StatusNotFound, "Agency not found", "AGENCY_NOT_FOUND", nil)
+		}
+		if businessflow.IsAgencyInactive(err) {
+			return h.ErrorResponse(c, fiber.StatusForbidden, "Agency is inactive", "AGENCY_INACTIVE", nil)
+		}
+		if businessflow.IsCustomerNotFound(err) {
+			return h.ErrorResponse(c, fiber.StatusNotFound, "Customer not found", "CUSTOMER_NOT_FOUND", nil)
+		}
+		if businessflow.IsAccountInactive(err) {
+			return h.ErrorResponse(c, fiber.StatusForbidden, "Account is inactive", "ACCOUNT_INACTIVE", nil)
+		}
+		if businessflow.IsAgencyCannotListDiscountsForItself(err) {
+			return h.ErrorResponse(c, fiber.StatusBadRequest, "Agency cannot list discounts for itself", "AGENCY_CANNOT_LIST_DISCOUNTS_FOR_ITSELF", nil)
+		}
+		if businessflow.IsCustomerNotUnderAgency(err) {
+			return h.ErrorResponse(c, fiber.StatusBadRequest, "Customer is not under any agency", "CUSTOMER_NOT_UNDER_AGENCY", nil)
+		}
+
+		log.Println("List discount schedule failed", err)
+		return h.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to list discount schedule", "LIST_DISCOUNT_SCHEDULE_FAILED", nil)
+	}
+
+	return h.SuccessResponse(c, fiber.StatusOK, "Discount schedule retrieved successfully", res)
+}
+
 // ListAgencyCustomers returns active customers under the authenticated agency
 // @Summary List Agency Customers
 // @Tags Reports
@@ -274,6 +347,7 @@ func (h *AgencyHandler) ListAgencyCustomerDiscounts(c fiber.Ctx) error {
 // @Success 200 {object} dto.APIResponse{data=dto.ListAgencyCustomersResponse}
 // @Failure 401 {object} dto.APIResponse
 // @Failure 500 {object} dto.APIResponse
+// @Security CustomerBearer
 // @Router /api/v1/reports/agency/customers/list [get]
 func (h *AgencyHandler) ListAgencyCustomers(c fiber.Ctx) error {
 	agencyID, ok := c.Locals("customer_id").(uint)
@@ -292,7 +366,7 @@ func (h *AgencyHandler) ListAgencyCustomers(c fiber.Ctx) error {
 			return h.ErrorResponse(c, fiber.StatusNotFound, "Agency not found", "AGENCY_NOT_FOUND", nil)
 		}
 		if businessflow.IsAgencyInactive(err) {
-			return h.ErrorResponse(c, fiber.StatusBadRequest, "Agency is inactive", "AGENCY_INACTIVE", nil)
+			return h.ErrorResponse(c, fiber.StatusForbidden, "Agency is inactive", "AGENCY_INACTIVE", nil)
 		}
 
 		log.Println("List agency customers failed", err)
@@ -302,6 +376,274 @@ func (h *AgencyHandler) ListAgencyCustomers(c fiber.Ctx) error {
 	return h.SuccessResponse(c, fiber.StatusOK, "Agency customers retrieved successfully", res)
 }
 
+// GetAgencyCommissionDashboard reports the agency's commission
+// @Summary Get Agency Commission Dashboard
+// @Description Revenue and accrued commission per referred customer, accrued and settled commission by Tehran month, pending commission and the referred customers' top campaigns
+// @Tags Reports
+// @Produce json
+// @Param start_date query string false "Start date (RFC3339)"
+// @Param end_date query string false "End date (RFC3339)"
+// @Success 200 {object} dto.APIResponse{data=dto.AgencyCommissionDashboardResponse}
+// @Failure 400 {object} dto.APIResponse "Validation error"
+// @Failure 401 {object} dto.APIResponse "Unauthorized"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
+// @Router /api/v1/reports/agency/commissions [get]
+func (h *AgencyHandler) GetAgencyCommissionDashboard(c fiber.Ctx) error {
+	agencyID, ok := c.Locals("customer_id").(uint)
+	if !ok || agencyID == 0 {
+		return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer ID not found in context", "MISSING_CUSTOMER_ID", nil)
+	}
+	req := h.agencyCommissionReportRequest(c, agencyID)
+
+	metadata := businessflow.NewClientMetadata(c.IP(), c.Get("User-Agent"))
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/reports/agency/commissions", 30*time.Second)
+	defer cancel()
+	res, err := h.flow.GetAgencyCommissionDashboard(ctx, req, metadata)
+	if err != nil {
+		if handled := h.handleAgencyCommissionError(c, err); handled != nil {
+			return handled
+		}
+		log.Println("Agency commission dashboard retrieval failed", err)
+		return h.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to retrieve agency commission dashboard", "AGENCY_COMMISSION_DASHBOARD_FAILED", nil)
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, "Agency commission dashboard retrieved successfully", res)
+}
+
+// ExportAgencyCommissionReport exports a section of the commission dashboard
+// @Summary Export Agency Commission Report
+// @Description Export commission per referred customer, commission by month, or the referred customers' campaigns as CSV
+// @Tags Reports
+// @Produce text/csv
+// @Param report query string true "Report (customers|monthly|campaigns)"
+// @Param start_date query string false "Start date (RFC3339)"
+// @Param end_date query string false "End date (RFC3339)"
+// @Success 200 {string} string "CSV file"
+// @Failure 400 {object} dto.APIResponse "Validation error"
+// @Failure 401 {object} dto.APIResponse "Unauthorized"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
+// @Router /api/v1/reports/agency/commissions/export [get]
+func (h *AgencyHandler) ExportAgencyCommissionReport(c fiber.Ctx) error {
+	agencyID, ok := c.Locals("customer_id").(uint)
+	if !ok || agencyID == 0 {
+		return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer ID not found in context", "MISSING_CUSTOMER_ID", nil)
+	}
+	req := h.agencyCommissionReportRequest(c, agencyID)
+	req.Report = c.Query("report")
+	if err := h.validator.Struct(req); err != nil || req.Report == "" {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "report must be one of customers, monthly, campaigns", "VALIDATION_ERROR", nil)
+	}
+
+	metadata := businessflow.NewClientMetadata(c.IP(), c.Get("User-Agent"))
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/reports/agency/commissions/export", 60*time.Second)
+	defer cancel()
+	res, err := h.flow.ExportAgencyCommissionReport(ctx, req, metadata)
+	if err != nil {
+		if handled := h.handleAgencyCommissionError(c, err); handled != nil {
+			return handled
+		}
+		log.Println("Agency commission export failed", err)
+		return h.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to export agency commission report", "AGENCY_COMMISSION_EXPORT_FAILED", nil)
+	}
+
+	c.Set("Content-Type", "text/csv; charset=utf-8")
+	c.Set("Content-Disposition", "attachment; filename=\""+res.Filename+"\"")
+	return c.Send(res.Content)
+}
+
+func (h *AgencyHandler) agencyCommissionReportRequest(c fiber.Ctx, agencyID uint) *dto.AgencyCommissionReportRequest {
+	req := &dto.AgencyCommissionReportRequest{AgencyID: agencyID}
+	if v := c.Query("start_date"); v != "" {
+		req.StartDate = &v
+	}
+	if v := c.Query("end_date"); v != "" {
+		req.EndDate = &v
+	}
+	return req
+}
+
+// handleAgencyCommissionError responds to the errors both commission
+// endpoints share, and returns nil for the others
+func (h *AgencyHandler) handleAgencyCommissionError(c fiber.Ctx, err error) error {
+	if businessflow.IsAgencyNotFound(err) {
+		return h.ErrorResponse(c, fiber.StatusNotFound, "Agency not found", "AGENCY_NOT_FOUND", nil)
+	}
+	if businessflow.IsAgencyInactive(err) {
+		return h.ErrorResponse(c, fiber.StatusForbidden, "Agency is inactive", "AGENCY_INACTIVE", nil)
+	}
+	var be *businessflow.BusinessError
+	if errors.As(err, &be) && be.Code == "VALIDATION_ERROR" {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, be.Message, be.Code, nil)
+	}
+	return nil
+}
+
+// GrantAgencyDelegation lets the customer's referrer agency act for them
+// @Summary Grant Agency Delegation
+// @Description Let the customer's referrer agency create (create_campaigns) or cancel, pause and resume (manage_campaigns) their campaigns by sending the X-Acting-As-Customer header. Replaces the permissions of an active grant.
+// @Tags Account
+// @Accept json
+// @Produce json
+// @Param request body dto.GrantAgencyDelegationRequest true "Delegated permissions"
+// @Success 200 {object} dto.APIResponse{data=dto.AgencyDelegationResponse}
+// @Failure 400 {object} dto.APIResponse "Validation error or customer without an agency"
+// @Failure 401 {object} dto.APIResponse "Unauthorized"
+// @Failure 403 {object} dto.APIResponse "Agency is inactive, or impersonating the customer"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
+// @Router /api/v1/account/agency-delegation [put]
+func (h *AgencyHandler) GrantAgencyDelegation(c fiber.Ctx) error {
+	customerID, ok := c.Locals("customer_id").(uint)
+	if !ok || customerID == 0 {
+		return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer ID not found in context", "MISSING_CUSTOMER_ID", nil)
+	}
+	var req dto.GrantAgencyDelegationRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body", "INVALID_REQUEST", err.Error())
+	}
+	req.CustomerID = customerID
+	if err := h.validator.Struct(&req); err != nil {
+		var validationErrors []string
+		for _, err := range err.(validator.ValidationErrors) {
+			validationErrors = append(validationErrors, getValidationErrorMessage(c, err))
+		}
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", validationErrors)
+	}
+
+	metadata := businessflow.NewClientMetadata(c.IP(), c.Get("User-Agent"))
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/account/agency-delegation", 30*time.Second)
+	defer cancel()
+	res, err := h.flow.GrantAgencyDelegation(ctx, &req, metadata)
+	if err != nil {
+		if handled := h.handleAgencyDelegationError(c, err); handled != nil {
+			return handled
+		}
+		if businessflow.IsAgencyDelegationPermissionInvalid(err) {
+			return h.ErrorResponse(c, fiber.StatusBadRequest, "Permissions must be create_campaigns or manage_campaigns", "AGENCY_DELEGATION_PERMISSION_INVALID", nil)
+		}
+		if businessflow.IsCustomerNotUnderAgency(err) {
+			return h.ErrorResponse(c, fiber.StatusBadRequest, "Customer is not under any agency", "CUSTOMER_NOT_UNDER_AGENCY", nil)
+		}
+		log.Println("Grant agency delegation failed", err)
+		return h.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to grant agency delegation", "AGENCY_DELEGATION_GRANT_FAILED", nil)
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, res.Message, res)
+}
+
+// RevokeAgencyDelegation withdraws the customer's grant to their agency
+// @Summary Revoke Agency Delegation
+// @Tags Account
+// @Produce json
+// @Success 200 {object} dto.APIResponse{data=dto.AgencyDelegationResponse}
+// @Failure 401 {object} dto.APIResponse "Unauthorized"
+// @Failure 404 {object} dto.APIResponse "No active delegation"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
+// @Router /api/v1/account/agency-delegation [delete]
+func (h *AgencyHandler) RevokeAgencyDelegation(c fiber.Ctx) error {
+	customerID, ok := c.Locals("customer_id").(uint)
+	if !ok || customerID == 0 {
+		return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer ID not found in context", "MISSING_CUSTOMER_ID", nil)
+	}
+
+	metadata := businessflow.NewClientMetadata(c.IP(), c.Get("User-Agent"))
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/account/agency-delegation", 30*time.Second)
+	defer cancel()
+	res, err := h.flow.RevokeAgencyDelegation(ctx, &dto.RevokeAgencyDelegationRequest{CustomerID: customerID}, metadata)
+	if err != nil {
+		if handled := h.handleAgencyDelegationError(c, err); handled != nil {
+			return handled
+		}
+		if businessflow.IsAgencyDelegationNotFound(err) {
+			return h.ErrorResponse(c, fiber.StatusNotFound, "No active agency delegation", "AGENCY_DELEGATION_NOT_FOUND", nil)
+		}
+		log.Println("Revoke agency delegation failed", err)
+		return h.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to revoke agency delegation", "AGENCY_DELEGATION_REVOKE_FAILED", nil)
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, res.Message, res)
+}
+
+// GetAgencyDelegation returns the customer's active grant to their agency
+// @Summary Get Agency Delegation
+// @Description The delegation is null when the customer has no active grant
+// @Tags Account
+// @Produce json
+// @Success 200 {object} dto.APIResponse{data=dto.AgencyDelegationResponse}
+// @Failure 401 {object} dto.APIResponse "Unauthorized"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
+// @Router /api/v1/account/agency-delegation [get]
+func (h *AgencyHandler) GetAgencyDelegation(c fiber.Ctx) error {
+	customerID, ok := c.Locals("customer_id").(uint)
+	if !ok || customerID == 0 {
+		return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer ID not found in context", "MISSING_CUSTOMER_ID", nil)
+	}
+
+	metadata := businessflow.NewClientMetadata(c.IP(), c.Get("User-Agent"))
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/account/agency-delegation", 30*time.Second)
+	defer cancel()
+	res, err := h.flow.GetAgencyDelegation(ctx, &dto.GetAgencyDelegationRequest{CustomerID: customerID}, metadata)
+	if err != nil {
+		if handled := h.handleAgencyDelegationError(c, err); handled != nil {
+			return handled
+		}
+		log.Println("Get agency delegation failed", err)
+		return h.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to retrieve agency delegation", "AGENCY_DELEGATION_LOOKUP_FAILED", nil)
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, res.Message, res)
+}
+
+// ListAgencyDelegations lists the referred customers who let the agency act
+// for them
+// @Summary List Agency Delegations
+// @Tags Reports
+// @Produce json
+// @Success 200 {object} dto.APIResponse{data=dto.ListAgencyDelegationsResponse}
+// @Failure 401 {object} dto.APIResponse "Unauthorized"
+// @Failure 403 {object} dto.APIResponse "Agency is inactive"
+// @Failure 404 {object} dto.APIResponse "Agency not found"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
+// @Router /api/v1/reports/agency/delegations [get]
+func (h *AgencyHandler) ListAgencyDelegations(c fiber.Ctx) error {
+	agencyID, ok := c.Locals("customer_id").(uint)
+	if !ok || agencyID == 0 {
+		return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer ID not found in context", "MISSING_CUSTOMER_ID", nil)
+	}
+
+	metadata := businessflow.NewClientMetadata(c.IP(), c.Get("User-Agent"))
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/reports/agency/delegations", 30*time.Second)
+	defer cancel()
+	res, err := h.flow.ListAgencyDelegations(ctx, &dto.ListAgencyDelegationsRequest{AgencyID: agencyID}, metadata)
+	if err != nil {
+		if handled := h.handleAgencyDelegationError(c, err); handled != nil {
+			return handled
+		}
+		log.Println("List agency delegations failed", err)
+		return h.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to list agency delegations", "LIST_AGENCY_DELEGATIONS_FAILED", nil)
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, res.Message, res)
+}
+
+// handleAgencyDelegationError responds to the account and agency errors the
+// delegation endpoints share, and returns nil for the others
+func (h *AgencyHandler) handleAgencyDelegationError(c fiber.Ctx, err error) error {
+	if businessflow.IsAgencyNotFound(err) {
+		return h.ErrorResponse(c, fiber.StatusNotFound, "Agency not found", "AGENCY_NOT_FOUND", nil)
+	}
+	if businessflow.IsAgencyInactive(err) {
+		return h.ErrorResponse(c, fiber.StatusForbidden, "Agency is inactive", "AGENCY_INACTIVE", nil)
+	}
+	if businessflow.IsCustomerNotFound(err) {
+		return h.ErrorResponse(c, fiber.StatusNotFound, "Customer not found", "CUSTOMER_NOT_FOUND", nil)
+	}
+	if businessflow.IsAccountInactive(err) {
+		return h.ErrorResponse(c, fiber.StatusForbidden, "Account is inactive", "ACCOUNT_INACTIVE", nil)
+	}
+	return nil
+}
+
 func (h *AgencyHandler) createRequestContextWithTimeout(c fiber.Ctx, endpoint string, timeout time.Duration) (context.Context, context.CancelFunc) {
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	ctx = context.WithValue(ctx, utils.RequestIDKey, c.Get("X-Request-ID"))
@@ -310,5 +652,6 @@ func (h *AgencyHandler) createRequestContextWithTimeout(c fiber.Ctx, endpoint st
 	ctx = context.WithValue(ctx, utils.EndpointKey, endpoint)
 	ctx = context.WithValue(ctx, utils.TimeoutKey, timeout)
 	ctx = context.WithValue(ctx, utils.CancelFuncKey, cancel)
+	ctx = middleware.WithImpersonation(ctx, c)
 	return ctx, cancel
 }
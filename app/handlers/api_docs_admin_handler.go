@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"log"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/apierror"
+	"github.com/amirphl/Yamata-no-Orochi/app/openapi"
+	"github.com/gofiber/fiber/v3"
+)
+
+// APIDocsAdminHandlerInterface serves the OpenAPI 3 document and a Swagger UI
+// for it to admins
+type APIDocsAdminHandlerInterface interface {
+	UI(c fiber.Ctx) error
+	OpenAPI(c fiber.Ctx) error
+}
+
+// APIDocsAdminHandler implements the admin API docs endpoints
+type APIDocsAdminHandler struct{}
+
+func NewAPIDocsAdminHandler() APIDocsAdminHandlerInterface {
+	return &APIDocsAdminHandler{}
+}
+
+func (h *APIDocsAdminHandler) ErrorResponse(c fiber.Ctx, status int, message, code string, details any) error {
+	return apierror.Respond(c, status, message, code, details)
+}
+
+// OpenAPI returns the OpenAPI 3 document
+// @Summary OpenAPI Document (Admin)
+// @Description The OpenAPI 3 document of this API, generated from the handler and DTO annotations, with the bearer security schemes, error envelope and pagination parameters
+// @Tags API Docs Admin
+// @Produce json
+// @Success 200 {object} map[string]interface{} "OpenAPI 3 document"
+// @Failure 500 {object} dto.APIResponse "Document could not be built"
+// @Security AdminBearer
+// @Router /api/v1/admin/docs/openapi.json [get]
+func (h *APIDocsAdminHandler) OpenAPI(c fiber.Ctx) error {
+	_, body, err := openapi.Document()
+	if err != nil {
+		log.Println("Failed to build OpenAPI document:", err)
+		return h.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to load API documentation", "SWAGGER_LOAD_ERROR", nil)
+	}
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	return c.Send(body)
+}
+
+// UI serves Swagger UI for the OpenAPI document. The page carries no data;
+// it asks for an admin access token and sends it when loading the document.
+// @Summary API Docs UI (Admin)
+// @Description Swagger UI for the OpenAPI document; paste an admin access token when prompted
+// @Tags API Docs Admin
+// @Produce html
+// @Success 200 {string} string "Swagger UI page"
+// @Router /api/v1/admin/docs [get]
+func (h *APIDocsAdminHandler) UI(c fiber.Ctx) error {
+	c.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+	return c.SendString(apiDocsUIPage)
+}
+
+const apiDocsUIPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>Yamata no Orochi API - Admin Docs</title>
+    <link rel="stylesheet" type="text/css" href="https://unpkg.com/swagger-ui-dist@5.9.0/swagger-ui.css" />
+    <style>
+        body { margin: 0; background: #fafafa; }
+    </style>
+</head>
+<body>
+    <div id="swagger-ui"></div>
+    <script src="https://unpkg.com/swagger-ui-dist@5.9.0/swagger-ui-bundle.js"></script>
+    <script>
+        window.onload = function() {
+            const specURL = '/api/v1/admin/docs/openapi.json';
+            let token = sessionStorage.getItem('admin_docs_token');
+            if (!token) {
+                token = (window.prompt('Admin access token') || '').replace(/^Bearer\s+/i, '').trim();
+                sessionStorage.setItem('admin_docs_token', token);
+            }
+            window.ui = SwaggerUIBundle({
+                url: specURL,
+                dom_id: '#swagger-ui',
+                deepLinking: true,
+                persistAuthorization: true,
+                validatorUrl: null,
+                requestInterceptor: function(req) {
+                    if (req.url.endsWith(specURL) && token) {
+                        req.headers['Authorization'] = 'Bearer ' + token;
+                    }
+                    return req;
+                },
+                responseInterceptor: function(res) {
+                    if (res.url.endsWith(specURL) && res.status === 401) {
+                        sessionStorage.removeItem('admin_docs_token');
+                    }
+                    return res;
+                }
+            });
+        };
+    </script>
+</body>
+</html>`
@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/apierror"
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/app/middleware"
+	businessflow "github.com/amirphl/Yamata-no-Orochi/business_flow"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v3"
+)
+
+const atipayReconciliationUploadTimeout = 5 * time.Minute
+
+// AtipayReconciliationAdminHandlerInterface defines admin endpoints for
+// reconciling Atipay settlement reports
+type AtipayReconciliationAdminHandlerInterface interface {
+	Upload(c fiber.Ctx) error
+	Report(c fiber.Ctx) error
+}
+
+// AtipayReconciliationAdminHandler implements the admin reconciliation endpoints
+type AtipayReconciliationAdminHandler struct {
+	flow      businessflow.AtipayReconciliationFlow
+	validator *validator.Validate
+}
+
+func NewAtipayReconciliationAdminHandler(flow businessflow.AtipayReconciliationFlow) AtipayReconciliationAdminHandlerInterface {
+	return &AtipayReconciliationAdminHandler{
+		flow:      flow,
+		validator: validator.New(),
+	}
+}
+
+func (h *AtipayReconciliationAdminHandler) ErrorResponse(c fiber.Ctx, status int, message, code string, details any) error {
+	return apierror.Respond(c, status, message, code, details)
+}
+
+func (h *AtipayReconciliationAdminHandler) SuccessResponse(c fiber.Ctx, status int, message string, data any) error {
+	return c.Status(status).JSON(dto.APIResponse{Success: true, Message: message, Data: data})
+}
+
+// Upload reconciles an Atipay settlement report
+// @Summary Upload Atipay Settlement Report (Admin)
+// @Description Reconcile Atipay's settlement report of a past day against the payment requests completed that day. The CSV needs an amount column in rials and at least one of reference_number, rrn or reservation_number. Reconciling a day again replaces its previous result. Rows that cannot be read are reported and skipped.
+// @Tags Admin Payments
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "Settlement report CSV"
+// @Param report_date formData string true "Tehran calendar day of the report (YYYY-MM-DD)"
+// @Success 200 {object} dto.APIResponse{data=dto.AdminAtipayReconciliationUploadResponse}
+// @Failure 400 {object} dto.APIResponse "Invalid file or report date"
+// @Failure 500 {object} dto.APIResponse "Reconciliation failed"
+// @Security AdminBearer
+// @Router /api/v1/admin/payments/atipay-reconciliation [post]
+func (h *AtipayReconciliationAdminHandler) Upload(c fiber.Ctx) error {
+	fileHeader, err := c.FormFile("file")
+	if err != nil || fileHeader == nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "file is required", "INVALID_REQUEST", nil)
+	}
+	fh, err := openFormFile(fileHeader)
+	if err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "invalid file", "INVALID_FILE", err.Error())
+	}
+	defer fh.Close()
+
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/admin/payments/atipay-reconciliation", atipayReconciliationUploadTimeout)
+	defer cancel()
+	res, err := h.flow.Upload(ctx, c.FormValue("report_date"), fh)
+	if err != nil {
+		return h.handleFlowError(c, "Failed to reconcile settlement report", "ATIPAY_RECONCILIATION_FAILED", err)
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, "Settlement report reconciled successfully", res)
+}
+
+// Report returns the reconciliation of a day
+// @Summary Atipay Reconciliation Report (Admin)
+// @Description Summary and entries of a day's reconciliation. Only discrepancies are listed unless a status is given or include_matched is true.
+// @Tags Admin Payments
+// @Produce json
+// @Param report_date query string true "Tehran calendar day of the report (YYYY-MM-DD)"
+// @Param status query string false "Filter by status (matched|amount_mismatch|status_mismatch|missing_in_system|missing_in_report|duplicate)"
+// @Param include_matched query bool false "List matched entries too" default(false)
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Page size" default(10) maximum(100)
+// @Success 200 {object} dto.APIResponse{data=dto.AdminAtipayReconciliationReportResponse}
+// @Failure 400 {object} dto.APIResponse "Validation error"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security AdminBearer
+// @Router /api/v1/admin/payments/atipay-reconciliation [get]
+func (h *AtipayReconciliationAdminHandler) Report(c fiber.Ctx) error {
+	page, err := strconv.Atoi(c.Query("page", "1"))
+	if err != nil || page <= 0 {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid page format", "INVALID_PAGE", nil)
+	}
+	limit, err := strconv.Atoi(c.Query("limit", "10"))
+	if err != nil || limit <= 0 {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid limit format", "INVALID_LIMIT", nil)
+	}
+	includeMatched, err := strconv.ParseBool(c.Query("include_matched", "false"))
+	if err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", nil)
+	}
+
+	filter := dto.AdminAtipayReconciliationFilter{
+		ReportDate:     strings.TrimSpace(c.Query("report_date")),
+		IncludeMatched: includeMatched,
+		Page:           page,
+		Limit:          limit,
+	}
+	if status := strings.TrimSpace(c.Query("status")); status != "" {
+		filter.Status = &status
+	}
+	if err := h.validator.Struct(filter); err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", nil)
+	}
+
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/admin/payments/atipay-reconciliation", 30*time.Second)
+	defer cancel()
+	res, err := h.flow.Report(ctx, filter)
+	if err != nil {
+		return h.handleFlowError(c, "Failed to get reconciliation report", "ATIPAY_RECONCILIATION_REPORT_FAILED", err)
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, "Reconciliation report retrieved successfully", res)
+}
+
+func (h *AtipayReconciliationAdminHandler) handleFlowError(c fiber.Ctx, message, code string, err error) error {
+	switch {
+	case businessflow.IsAtipayReportDateInvalid(err):
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid report date", "ATIPAY_REPORT_DATE_INVALID", businessErrorMessage(err))
+	case businessflow.IsAtipayReportFileInvalid(err):
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid settlement report file", "ATIPAY_REPORT_FILE_INVALID", businessErrorMessage(err))
+	}
+	log.Println(message+":", err)
+	return h.ErrorResponse(c, fiber.StatusInternalServerError, message, code, nil)
+}
+
+func (h *AtipayReconciliationAdminHandler) createRequestContextWithTimeout(c fiber.Ctx, endpoint string, timeout time.Duration) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	ctx = context.WithValue(ctx, utils.RequestIDKey, c.Get("X-Request-ID"))
+	ctx = context.WithValue(ctx, utils.UserAgentKey, c.Get("User-Agent"))
+	ctx = context.WithValue(ctx, utils.IPAddressKey, c.IP())
+	ctx = context.WithValue(ctx, utils.EndpointKey, endpoint)
+	ctx = context.WithValue(ctx, utils.TimeoutKey, timeout)
+	ctx = context.WithValue(ctx, utils.CancelFuncKey, cancel)
+	if adminID, ok := middleware.GetAdminIDFromContext(c); ok {
+		ctx = context.WithValue(ctx, utils.AdminIDKey, adminID)
+	}
+	return ctx, cancel
+}
@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/apierror"
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/app/middleware"
+	businessflow "github.com/amirphl/Yamata-no-Orochi/business_flow"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/gofiber/fiber/v3"
+)
+
+const audienceImportUploadTimeout = 5 * time.Minute
+
+// AudienceImportAdminHandlerInterface defines admin endpoints for bulk audience CSV imports
+type AudienceImportAdminHandlerInterface interface {
+	UploadCSV(c fiber.Ctx) error
+	GetImport(c fiber.Ctx) error
+}
+
+// AudienceImportAdminHandler implements the admin audience import endpoints
+type AudienceImportAdminHandler struct {
+	flow businessflow.AudienceImportFlow
+}
+
+func NewAudienceImportAdminHandler(flow businessflow.AudienceImportFlow) AudienceImportAdminHandlerInterface {
+	return &AudienceImportAdminHandler{flow: flow}
+}
+
+func (h *AudienceImportAdminHandler) ErrorResponse(c fiber.Ctx, status int, message, code string, details any) error {
+	return apierror.Respond(c, status, message, code, details)
+}
+
+func (h *AudienceImportAdminHandler) SuccessResponse(c fiber.Ctx, status int, message string, data any) error {
+	return c.Status(status).JSON(dto.APIResponse{Success: true, Message: message, Data: data})
+}
+
+// UploadCSV queues a CSV of phone numbers and tags for background import
+// @Summary Upload Audience CSV (Admin)
+// @Description Queue a CSV with a phone_number column and an optional tags column (tag names separated by ';') for import into audience profiles. Poll the returned job for progress.
+// @Tags Admin Audience Imports
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "CSV file with phone_number and optional tags columns"
+// @Success 202 {object} dto.APIResponse{data=dto.AudienceImportJobResponse}
+// @Failure 400 {object} dto.APIResponse "Invalid file"
+// @Failure 500 {object} dto.APIResponse "Upload failed"
+// @Security AdminBearer
+// @Router /api/v1/admin/audience-imports [post]
+func (h *AudienceImportAdminHandler) UploadCSV(c fiber.Ctx) error {
+	fileHeader, err := c.FormFile("file")
+	if err != nil || fileHeader == nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "file is required", "INVALID_REQUEST", nil)
+	}
+	fh, err := openFormFile(fileHeader)
+	if err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "invalid file", "INVALID_FILE", err.Error())
+	}
+	defer fh.Close()
+
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/admin/audience-imports", audienceImportUploadTimeout)
+	defer cancel()
+	res, err := h.flow.CreateImport(ctx, fh, fileHeader.Filename)
+	if err != nil {
+		return h.handleFlowError(c, "Failed to queue audience import", "AUDIENCE_IMPORT_CREATE_FAILED", err)
+	}
+	return h.SuccessResponse(c, fiber.StatusAccepted, "Audience import queued", res)
+}
+
+// GetImport returns the progress and row errors of an audience import
+// @Summary Get Audience Import (Admin)
+// @Description Poll the progress, counters and per-row errors of a bulk audience import
+// @Tags Admin Audience Imports
+// @Produce json
+// @Param uuid path string true "Import job UUID"
+// @Success 200 {object} dto.APIResponse{data=dto.AudienceImportJobResponse}
+// @Failure 404 {object} dto.APIResponse "Import not found"
+// @Failure 500 {object} dto.APIResponse "Lookup failed"
+// @Security AdminBearer
+// @Router /api/v1/admin/audience-imports/{uuid} [get]
+func (h *AudienceImportAdminHandler) GetImport(c fiber.Ctx) error {
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/admin/audience-imports/:uuid", 30*time.Second)
+	defer cancel()
+	res, err := h.flow.GetImport(ctx, c.Params("uuid"))
+	if err != nil {
+		return h.handleFlowError(c, "Failed to get audience import", "AUDIENCE_IMPORT_LOOKUP_FAILED", err)
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, "Audience import retrieved", res)
+}
+
+func (h *AudienceImportAdminHandler) handleFlowError(c fiber.Ctx, message, code string, err error) error {
+	switch {
+	case businessflow.IsAudienceImportNotFound(err):
+		return h.ErrorResponse(c, fiber.StatusNotFound, "Audience import not found", "AUDIENCE_IMPORT_NOT_FOUND", nil)
+	case businessflow.IsAudienceImportFileInvalid(err):
+		var details any
+		var be *businessflow.BusinessError
+		if errors.As(err, &be) {
+			details = be.Message
+		}
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid audience import file", "AUDIENCE_IMPORT_FILE_INVALID", details)
+	}
+	log.Println(message+":", err)
+	return h.ErrorResponse(c, fiber.StatusInternalServerError, message, code, nil)
+}
+
+func (h *AudienceImportAdminHandler) createRequestContextWithTimeout(c fiber.Ctx, endpoint string, timeout time.Duration) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	ctx = context.WithValue(ctx, utils.RequestIDKey, c.Get("X-Request-ID"))
+	ctx = context.WithValue(ctx, utils.UserAgentKey, c.Get("User-Agent"))
+	ctx = context.WithValue(ctx, utils.IPAddressKey, c.IP())
+	ctx = context.WithValue(ctx, utils.EndpointKey, endpoint)
+	ctx = context.WithValue(ctx, utils.TimeoutKey, timeout)
+	ctx = context.WithValue(ctx, utils.CancelFuncKey, cancel)
+	if adminID, ok := middleware.GetAdminIDFromContext(c); ok {
+		ctx = context.WithValue(ctx, utils.AdminIDKey, adminID)
+	}
+	return ctx, cancel
+}
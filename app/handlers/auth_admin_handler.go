@@ -6,6 +6,7 @@ import (
 	"log"
 	"time"
 
+	"github.com/amirphl/Yamata-no-Orochi/app/apierror"
 	"github.com/amirphl/Yamata-no-Orochi/app/dto"
 	businessflow "github.com/amirphl/Yamata-no-Orochi/business_flow"
 	"github.com/amirphl/Yamata-no-Orochi/utils"
@@ -28,14 +29,7 @@ type AuthAdminHandler struct {
 
 // ErrorResponse standard JSON error
 func (h *AuthAdminHandler) ErrorResponse(c fiber.Ctx, statusCode int, message, errorCode string, details any) error {
-	return c.Status(statusCode).JSON(dto.APIResponse{
-		Success: false,
-		Message: message,
-		Error: dto.ErrorDetail{
-			Code:    errorCode,
-			Details: details,
-		},
-	})
+	return apierror.Respond(c, statusCode, message, errorCode, details)
 }
 
 // SuccessResponse standard JSON success
@@ -74,14 +68,14 @@ func (h *AuthAdminHandler) InitCaptcha(c fiber.Ctx) error {
 	return h.SuccessResponse(c, fiber.StatusOK, "Captcha initialized", resp)
 }
 
-// VerifyLogin validates captcha and credentials, then starts the OTP step.
+// VerifyLogin validates captcha and credentials, then starts the second factor step.
 // @Summary Admin login
-// @Description Verify captcha and admin credentials, then send/reuse an OTP for two-factor login
+// @Description Verify captcha and admin credentials, then start the second factor. Admins with an authenticator app get a totp challenge; others get an SMS OTP (sms), or a new authenticator secret to confirm (totp_setup) when their mobile bypasses the SMS OTP.
 // @Tags Admin Authentication
 // @Accept json
 // @Produce json
 // @Param request body dto.AdminCaptchaVerifyRequest true "Admin login data"
-// @Success 200 {object} dto.APIResponse{data=dto.AdminLoginInitResponse} "OTP challenge created"
+// @Success 200 {object} dto.APIResponse{data=dto.AdminLoginInitResponse} "Second factor challenge created"
 // @Failure 400 {object} dto.APIResponse "Invalid request or captcha"
 // @Failure 401 {object} dto.APIResponse "Incorrect credentials or admin not found"
 // @Failure 403 {object} dto.APIResponse "Admin inactive"
@@ -96,7 +90,7 @@ func (h *AuthAdminHandler) VerifyLogin(c fiber.Ctx) error {
 	if err := h.validator.Struct(&req); err != nil {
 		var validationErrors []string
 		for _, err := range err.(validator.ValidationErrors) {
-			validationErrors = append(validationErrors, getValidationErrorMessage(err))
+			validationErrors = append(validationErrors, getValidationErrorMessage(c, err))
 		}
 		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", validationErrors)
 	}
@@ -119,27 +113,17 @@ func (h *AuthAdminHandler) VerifyLogin(c fiber.Ctx) error {
 		return h.ErrorResponse(c, fiber.StatusInternalServerError, "Login failed", "INTERNAL_ERROR", nil)
 	}
 
-	if !result.RequiresTwoFactor && result.Session != nil && result.Admin != nil {
-		return h.SuccessResponse(c, fiber.StatusOK, "Login successful", fiber.Map{
-			"access_token":  result.Session.AccessToken,
-			"refresh_token": result.Session.RefreshToken,
-			"token_type":    result.Session.TokenType,
-			"expires_in":    result.Session.ExpiresIn,
-			"admin":         result.Admin,
-		})
-	}
-
-	return h.SuccessResponse(c, fiber.StatusOK, "OTP sent", result)
+	return h.SuccessResponse(c, fiber.StatusOK, result.Message, result)
 }
 
 // VerifyLoginOTP completes the second factor step for admin login.
 // @Summary Admin login OTP verification
-// @Description Verify admin OTP and issue access/refresh tokens
+// @Description Verify the code of a login challenge: an SMS OTP, a code from the admin's authenticator app, or a code confirming a new authenticator app. A verified SMS OTP returns an authenticator setup (totp_setup) to confirm with another call instead of tokens.
 // @Tags Admin Authentication
 // @Accept json
 // @Produce json
 // @Param request body dto.AdminLoginVerifyOTPRequest true "Admin login OTP verification data"
-// @Success 200 {object} dto.APIResponse{data=object{access_token=string,refresh_token=string,token_type=string,expires_in=int,admin=dto.AdminDTO}} "Login successful"
+// @Success 200 {object} dto.APIResponse{data=object{access_token=string,refresh_token=string,token_type=string,expires_in=int,admin=dto.AdminDTO,totp_setup=dto.AdminTOTPSetupDTO}} "Login successful, or authenticator setup required"
 // @Failure 400 {object} dto.APIResponse "Invalid request body"
 // @Failure 401 {object} dto.APIResponse "Invalid or expired OTP"
 // @Failure 403 {object} dto.APIResponse "Admin inactive"
@@ -154,7 +138,7 @@ func (h *AuthAdminHandler) VerifyLoginOTP(c fiber.Ctx) error {
 	if err := h.validator.Struct(&req); err != nil {
 		var validationErrors []string
 		for _, err := range err.(validator.ValidationErrors) {
-			validationErrors = append(validationErrors, getValidationErrorMessage(err))
+			validationErrors = append(validationErrors, getValidationErrorMessage(c, err))
 		}
 		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", validationErrors)
 	}
@@ -177,6 +161,14 @@ func (h *AuthAdminHandler) VerifyLoginOTP(c fiber.Ctx) error {
 		return h.ErrorResponse(c, fiber.StatusInternalServerError, "OTP verification failed", "INTERNAL_ERROR", nil)
 	}
 
+	if result.TOTPSetup != nil {
+		return h.SuccessResponse(c, fiber.StatusOK, "Set up an authenticator app to continue", fiber.Map{
+			"requires_two_factor": true,
+			"two_factor_method":   businessflow.AdminTwoFactorTOTPSetup,
+			"totp_setup":          result.TOTPSetup,
+		})
+	}
+
 	return h.SuccessResponse(c, fiber.StatusOK, "Login successful", fiber.Map{
 		"access_token":  result.Session.AccessToken,
 		"refresh_token": result.Session.RefreshToken,
@@ -5,6 +5,7 @@ import (
 	"log"
 	"time"
 
+	"github.com/amirphl/Yamata-no-Orochi/app/apierror"
 	"github.com/amirphl/Yamata-no-Orochi/app/dto"
 	businessflow "github.com/amirphl/Yamata-no-Orochi/business_flow"
 	"github.com/amirphl/Yamata-no-Orochi/utils"
@@ -22,7 +23,7 @@ type AuthBotHandler struct {
 }
 
 func (h *AuthBotHandler) ErrorResponse(c fiber.Ctx, statusCode int, message, errorCode string, details any) error {
-	return c.Status(statusCode).JSON(dto.APIResponse{Success: false, Message: message, Error: dto.ErrorDetail{Code: errorCode, Details: details}})
+	return apierror.Respond(c, statusCode, message, errorCode, details)
 }
 
 func (h *AuthBotHandler) SuccessResponse(c fiber.Ctx, statusCode int, message string, data any) error {
@@ -4,11 +4,17 @@ package handlers
 import (
 	"context"
 	"log"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/amirphl/Yamata-no-Orochi/app/apierror"
 	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/app/i18n"
+	"github.com/amirphl/Yamata-no-Orochi/app/middleware"
 	businessflow "github.com/amirphl/Yamata-no-Orochi/business_flow"
 	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/amirphl/Yamata-no-Orochi/utils/phonenumber"
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v3"
 )
@@ -20,8 +26,18 @@ type AuthHandlerInterface interface {
 	ResendOTP(c fiber.Ctx) error
 	Login(c fiber.Ctx) error
 	RequestLoginOTP(c fiber.Ctx) error
+	RequestPasswordlessLoginOTP(c fiber.Ctx) error
+	PasswordlessLogin(c fiber.Ctx) error
 	ForgotPassword(c fiber.Ctx) error
 	ResetPassword(c fiber.Ctx) error
+	ChangePassword(c fiber.Ctx) error
+	RequestMobileChange(c fiber.Ctx) error
+	ConfirmMobileChange(c fiber.Ctx) error
+	ResendLoginOTP(c fiber.Ctx) error
+	Logout(c fiber.Ctx) error
+	ListSessions(c fiber.Ctx) error
+	RevokeSession(c fiber.Ctx) error
+	ReportLoginNotMe(c fiber.Ctx) error
 }
 
 // AuthHandler handles authentication-related HTTP requests
@@ -29,17 +45,14 @@ type AuthHandler struct {
 	signupFlow businessflow.SignupFlow
 	loginFlow  businessflow.LoginFlow
 	validator  *validator.Validate
+
+	// countryHeader names the request header in which the reverse proxy
+	// passes the client's country code; empty when it does not
+	countryHeader string
 }
 
 func (h *AuthHandler) ErrorResponse(c fiber.Ctx, statusCode int, message, errorCode string, details any) error {
-	return c.Status(statusCode).JSON(dto.APIResponse{
-		Success: false,
-		Message: message,
-		Error: dto.ErrorDetail{
-			Code:    errorCode,
-			Details: details,
-		},
-	})
+	return apierror.Respond(c, statusCode, message, errorCode, details)
 }
 
 func (h *AuthHandler) SuccessResponse(c fiber.Ctx, statusCode int, message string, data any) error {
@@ -51,11 +64,12 @@ func (h *AuthHandler) SuccessResponse(c fiber.Ctx, statusCode int, message strin
 }
 
 // NewAuthHandler creates a new authentication handler
-func NewAuthHandler(signupFlow businessflow.SignupFlow, loginFlow businessflow.LoginFlow) *AuthHandler {
+func NewAuthHandler(signupFlow businessflow.SignupFlow, loginFlow businessflow.LoginFlow, countryHeader string) *AuthHandler {
 	handler := &AuthHandler{
-		signupFlow: signupFlow,
-		loginFlow:  loginFlow,
-		validator:  validator.New(),
+		signupFlow:    signupFlow,
+		loginFlow:     loginFlow,
+		validator:     validator.New(),
+		countryHeader: countryHeader,
 	}
 
 	// Setup custom validations
@@ -81,24 +95,35 @@ func (h *AuthHandler) Signup(c fiber.Ctx) error {
 	if err := c.Bind().JSON(&req); err != nil {
 		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body", "INVALID_REQUEST", err.Error())
 	}
+	if req.Locale == nil {
+		if locale, ok := i18n.ParseAcceptLanguage(c.Get(fiber.HeaderAcceptLanguage)); ok {
+			req.Locale = utils.ToPtr(string(locale))
+		}
+	}
 
 	// Validate request
 	if err := h.validator.Struct(&req); err != nil {
 		var validationErrors []string
 		for _, err := range err.(validator.ValidationErrors) {
-			validationErrors = append(validationErrors, getValidationErrorMessage(err))
+			validationErrors = append(validationErrors, getValidationErrorMessage(c, err))
 		}
 		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", validationErrors)
 	}
 
 	// Get client information
-	metadata := businessflow.NewClientMetadata(c.IP(), c.Get("User-Agent"))
+	metadata := h.clientMetadata(c)
 	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/auth/signup", 30*time.Second)
 	defer cancel()
 
 	// Call business logic with proper context
 	result, err := h.signupFlow.Signup(ctx, &req, metadata)
 	if err != nil {
+		if businessflow.IsRateLimitExceeded(err) {
+			return h.otpRateLimited(c, err)
+		}
+		if businessflow.IsPasswordRejected(err) {
+			return passwordRejected(c, err)
+		}
 		// Handle specific business errors
 		if businessflow.IsEmailAlreadyExists(err) {
 			return h.ErrorResponse(c, fiber.StatusConflict, "Email already exists", "EMAIL_EXISTS", nil)
@@ -169,13 +194,13 @@ func (h *AuthHandler) VerifyOTP(c fiber.Ctx) error {
 	if err := h.validator.Struct(&req); err != nil {
 		var validationErrors []string
 		for _, err := range err.(validator.ValidationErrors) {
-			validationErrors = append(validationErrors, getValidationErrorMessage(err))
+			validationErrors = append(validationErrors, getValidationErrorMessage(c, err))
 		}
 		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", validationErrors)
 	}
 
 	// Get client information
-	metadata := businessflow.NewClientMetadata(c.IP(), c.Get("User-Agent"))
+	metadata := h.clientMetadata(c)
 	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/auth/verify", 30*time.Second)
 	defer cancel()
 
@@ -190,7 +215,7 @@ func (h *AuthHandler) VerifyOTP(c fiber.Ctx) error {
 			return h.ErrorResponse(c, fiber.StatusNotFound, "Customer not found", "CUSTOMER_NOT_FOUND", nil)
 		}
 		if businessflow.IsAccountInactive(err) {
-			return h.ErrorResponse(c, fiber.StatusBadRequest, "Account is inactive", "ACCOUNT_INACTIVE", nil)
+			return h.ErrorResponse(c, fiber.StatusForbidden, "Account is inactive", "ACCOUNT_INACTIVE", nil)
 		}
 		if businessflow.IsAccountTypeNotFound(err) {
 			return h.ErrorResponse(c, fiber.StatusBadRequest, "Account type not found", "ACCOUNT_TYPE_NOT_FOUND", nil)
@@ -247,7 +272,7 @@ func (h *AuthHandler) ResendOTP(c fiber.Ctx) error {
 	}
 
 	// Get client information
-	metadata := businessflow.NewClientMetadata(c.IP(), c.Get("User-Agent"))
+	metadata := h.clientMetadata(c)
 	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/auth/resend-otp", 30*time.Second)
 	defer cancel()
 
@@ -255,14 +280,14 @@ func (h *AuthHandler) ResendOTP(c fiber.Ctx) error {
 	result, err := h.signupFlow.ResendOTP(ctx, &req, metadata)
 	if err != nil {
 		if businessflow.IsRateLimitExceeded(err) {
-			return h.ErrorResponse(c, fiber.StatusTooManyRequests, "Please wait before requesting another OTP", "RATE_LIMITED", nil)
+			return h.otpRateLimited(c, err)
 		}
 		// Handle specific business errors
 		if businessflow.IsCustomerNotFound(err) {
 			return h.ErrorResponse(c, fiber.StatusNotFound, "Customer not found", "CUSTOMER_NOT_FOUND", nil)
 		}
 		if businessflow.IsAccountInactive(err) {
-			return h.ErrorResponse(c, fiber.StatusBadRequest, "Account is inactive", "ACCOUNT_INACTIVE", nil)
+			return h.ErrorResponse(c, fiber.StatusForbidden, "Account is inactive", "ACCOUNT_INACTIVE", nil)
 		}
 		if businessflow.IsAccountTypeNotFound(err) {
 			return h.ErrorResponse(c, fiber.StatusBadRequest, "Account type not found", "ACCOUNT_TYPE_NOT_FOUND", nil)
@@ -280,8 +305,9 @@ func (h *AuthHandler) ResendOTP(c fiber.Ctx) error {
 	}
 
 	return h.SuccessResponse(c, fiber.StatusOK, result.Message, fiber.Map{
-		"otp_sent":   result.OTPSent,
-		"otp_target": result.MaskedOTPTarget,
+		"otp_sent":            result.OTPSent,
+		"otp_target":          result.MaskedOTPTarget,
+		"resend_available_in": result.ResendAvailableIn,
 	})
 }
 
@@ -295,6 +321,7 @@ func (h *AuthHandler) ResendOTP(c fiber.Ctx) error {
 // @Success 200 {object} dto.APIResponse{data=object{access_token=string,refresh_token=string,token_type=string,expires_in=int,customer=dto.AuthCustomerDTO}} "Login successful with tokens"
 // @Failure 400 {object} dto.APIResponse "Invalid credentials"
 // @Failure 401 {object} dto.APIResponse "Authentication failed"
+// @Failure 403 {object} dto.APIResponse "Password reset required after a login was reported as not the customer's"
 // @Failure 500 {object} dto.APIResponse "Internal server error"
 // @Router /api/v1/auth/login [post]
 func (h *AuthHandler) Login(c fiber.Ctx) error {
@@ -307,13 +334,13 @@ func (h *AuthHandler) Login(c fiber.Ctx) error {
 	if err := h.validator.Struct(&req); err != nil {
 		var validationErrors []string
 		for _, err := range err.(validator.ValidationErrors) {
-			validationErrors = append(validationErrors, getValidationErrorMessage(err))
+			validationErrors = append(validationErrors, getValidationErrorMessage(c, err))
 		}
 		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", validationErrors)
 	}
 
 	// Get client information
-	metadata := businessflow.NewClientMetadata(c.IP(), c.Get("User-Agent"))
+	metadata := h.clientMetadata(c)
 	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/auth/login", 30*time.Second)
 	defer cancel()
 
@@ -329,6 +356,9 @@ func (h *AuthHandler) Login(c fiber.Ctx) error {
 		if businessflow.IsNoValidOTPFound(err) || businessflow.IsInvalidOTPCode(err) {
 			return h.ErrorResponse(c, fiber.StatusUnauthorized, "Invalid credentials", "AUTHENTICATION_FAILED", nil)
 		}
+		if businessflow.IsPasswordResetRequired(err) {
+			return h.ErrorResponse(c, fiber.StatusForbidden, "Password reset required", "PASSWORD_RESET_REQUIRED", nil)
+		}
 		if businessflow.IsCacheNotAvailable(err) {
 			return h.ErrorResponse(c, fiber.StatusServiceUnavailable, "Cache not available", "CACHE_NOT_AVAILABLE", nil)
 		}
@@ -373,19 +403,19 @@ func (h *AuthHandler) RequestLoginOTP(c fiber.Ctx) error {
 	if err := h.validator.Struct(&req); err != nil {
 		var validationErrors []string
 		for _, err := range err.(validator.ValidationErrors) {
-			validationErrors = append(validationErrors, getValidationErrorMessage(err))
+			validationErrors = append(validationErrors, getValidationErrorMessage(c, err))
 		}
 		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", validationErrors)
 	}
 
-	metadata := businessflow.NewClientMetadata(c.IP(), c.Get("User-Agent"))
+	metadata := h.clientMetadata(c)
 	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/auth/login/otp", 30*time.Second)
 	defer cancel()
 
 	res, err := h.loginFlow.RequestLoginOTP(ctx, &req, metadata)
 	if err != nil {
 		if businessflow.IsRateLimitExceeded(err) {
-			return h.ErrorResponse(c, fiber.StatusTooManyRequests, "Please wait before requesting another OTP", "RATE_LIMITED", nil)
+			return h.otpRateLimited(c, err)
 		}
 		if businessflow.IsCustomerNotFound(err) {
 			return h.ErrorResponse(c, fiber.StatusNotFound, "User not found", "CUSTOMER_NOT_FOUND", nil)
@@ -401,6 +431,131 @@ func (h *AuthHandler) RequestLoginOTP(c fiber.Ctx) error {
 	return h.SuccessResponse(c, fiber.StatusOK, res.Message, res)
 }
 
+// RequestPasswordlessLoginOTP handles sending a passwordless login code
+// @Summary Request Passwordless Login Code
+// @Description Send a one-time code to the customer's mobile number that logs them in without their password. Only available when passwordless login is enabled. Sends to a mobile number are limited by a cooldown and a daily limit; while a code is outstanding the same expiry is reported instead of sending another.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param request body dto.PasswordlessLoginOTPRequest true "Passwordless login code request"
+// @Success 200 {object} dto.APIResponse{data=dto.LoginOTPResponse} "Code sent"
+// @Failure 400 {object} dto.APIResponse "Validation error"
+// @Failure 403 {object} dto.APIResponse "Passwordless login disabled, or password reset required after a login was reported as not the customer's"
+// @Failure 404 {object} dto.APIResponse "User not found"
+// @Failure 429 {object} dto.APIResponse "Code requested too soon; details.retry_after_seconds says how long to wait"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Failure 503 {object} dto.APIResponse "Cache not available"
+// @Router /api/v1/auth/passwordless/otp [post]
+func (h *AuthHandler) RequestPasswordlessLoginOTP(c fiber.Ctx) error {
+	var req dto.PasswordlessLoginOTPRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body", "INVALID_REQUEST", err.Error())
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		var validationErrors []string
+		for _, err := range err.(validator.ValidationErrors) {
+			validationErrors = append(validationErrors, getValidationErrorMessage(c, err))
+		}
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", validationErrors)
+	}
+
+	metadata := h.clientMetadata(c)
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/auth/passwordless/otp", 30*time.Second)
+	defer cancel()
+
+	res, err := h.loginFlow.RequestPasswordlessLoginOTP(ctx, &req, metadata)
+	if err != nil {
+		if businessflow.IsPasswordlessLoginDisabled(err) {
+			return h.ErrorResponse(c, fiber.StatusForbidden, "Login with an SMS code is disabled", "PASSWORDLESS_LOGIN_DISABLED", nil)
+		}
+		if businessflow.IsRateLimitExceeded(err) {
+			return h.otpRateLimited(c, err)
+		}
+		if businessflow.IsCustomerNotFound(err) {
+			return h.ErrorResponse(c, fiber.StatusNotFound, "User not found", "CUSTOMER_NOT_FOUND", nil)
+		}
+		if businessflow.IsPasswordResetRequired(err) {
+			return h.ErrorResponse(c, fiber.StatusForbidden, "Password reset required", "PASSWORD_RESET_REQUIRED", nil)
+		}
+		if businessflow.IsCacheNotAvailable(err) {
+			return h.ErrorResponse(c, fiber.StatusServiceUnavailable, "Cache not available", "CACHE_NOT_AVAILABLE", nil)
+		}
+
+		log.Println("Passwordless login code request failed", err)
+		return h.ErrorResponse(c, fiber.StatusInternalServerError, "Login code request failed", "PASSWORDLESS_LOGIN_REQUEST_FAILED", nil)
+	}
+
+	return h.SuccessResponse(c, fiber.StatusOK, res.Message, res)
+}
+
+// PasswordlessLogin handles login with a one-time SMS code
+// @Summary Passwordless Login
+// @Description Log in with the mobile number and the code sent by /api/v1/auth/passwordless/otp, without a password. Failed attempts count toward the same lockout as password logins.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param request body dto.PasswordlessLoginRequest true "Mobile number and code"
+// @Success 200 {object} dto.APIResponse{data=object{access_token=string,refresh_token=string,token_type=string,expires_in=int,customer=dto.AuthCustomerDTO}} "Login successful with tokens"
+// @Failure 400 {object} dto.APIResponse "Validation error"
+// @Failure 401 {object} dto.APIResponse "Authentication failed"
+// @Failure 403 {object} dto.APIResponse "Passwordless login disabled, or password reset required after a login was reported as not the customer's"
+// @Failure 429 {object} dto.APIResponse "Too many login attempts"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Failure 503 {object} dto.APIResponse "Cache not available"
+// @Router /api/v1/auth/passwordless/login [post]
+func (h *AuthHandler) PasswordlessLogin(c fiber.Ctx) error {
+	var req dto.PasswordlessLoginRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body", "INVALID_REQUEST", err.Error())
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		var validationErrors []string
+		for _, err := range err.(validator.ValidationErrors) {
+			validationErrors = append(validationErrors, getValidationErrorMessage(c, err))
+		}
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", validationErrors)
+	}
+
+	metadata := h.clientMetadata(c)
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/auth/passwordless/login", 30*time.Second)
+	defer cancel()
+
+	result, err := h.loginFlow.PasswordlessLogin(ctx, &req, metadata)
+	if err != nil {
+		if businessflow.IsPasswordlessLoginDisabled(err) {
+			return h.ErrorResponse(c, fiber.StatusForbidden, "Login with an SMS code is disabled", "PASSWORDLESS_LOGIN_DISABLED", nil)
+		}
+		if businessflow.IsRateLimitExceeded(err) {
+			return h.ErrorResponse(c, fiber.StatusTooManyRequests, "Too many login attempts", "RATE_LIMITED", nil)
+		}
+		if businessflow.IsAuthenticationFailed(err) || businessflow.IsNoValidOTPFound(err) || businessflow.IsInvalidOTPCode(err) {
+			return h.ErrorResponse(c, fiber.StatusUnauthorized, "Invalid credentials", "AUTHENTICATION_FAILED", nil)
+		}
+		if businessflow.IsPasswordResetRequired(err) {
+			return h.ErrorResponse(c, fiber.StatusForbidden, "Password reset required", "PASSWORD_RESET_REQUIRED", nil)
+		}
+		if businessflow.IsCacheNotAvailable(err) {
+			return h.ErrorResponse(c, fiber.StatusServiceUnavailable, "Cache not available", "CACHE_NOT_AVAILABLE", nil)
+		}
+		if businessflow.IsAccountTypeNotFound(err) {
+			return h.ErrorResponse(c, fiber.StatusBadRequest, "Account type not found", "ACCOUNT_TYPE_NOT_FOUND", nil)
+		}
+
+		log.Println("Passwordless login failed", err)
+		return h.ErrorResponse(c, fiber.StatusUnauthorized, "Login failed", "LOGIN_FAILED", nil)
+	}
+
+	return h.SuccessResponse(c, fiber.StatusOK, "Login successful", fiber.Map{
+		"access_token":  result.Session.SessionToken,
+		"refresh_token": result.Session.RefreshToken,
+		"token_type":    "Bearer",
+		"expires_in":    utils.AccessTokenTTLSeconds,
+		"customer":      result.Customer,
+	})
+}
+
 // ForgotPassword handles password reset initiation
 // @Summary Forgot Password
 // @Description Initiate password reset by sending OTP to registered mobile
@@ -423,13 +578,13 @@ func (h *AuthHandler) ForgotPassword(c fiber.Ctx) error {
 	if err := h.validator.Struct(&req); err != nil {
 		var validationErrors []string
 		for _, err := range err.(validator.ValidationErrors) {
-			validationErrors = append(validationErrors, getValidationErrorMessage(err))
+			validationErrors = append(validationErrors, getValidationErrorMessage(c, err))
 		}
 		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", validationErrors)
 	}
 
 	// Get client information
-	metadata := businessflow.NewClientMetadata(c.IP(), c.Get("User-Agent"))
+	metadata := h.clientMetadata(c)
 	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/auth/forgot-password", 30*time.Second)
 	defer cancel()
 
@@ -437,14 +592,14 @@ func (h *AuthHandler) ForgotPassword(c fiber.Ctx) error {
 	result, err := h.loginFlow.ForgotPassword(ctx, &req, metadata)
 	if err != nil {
 		if businessflow.IsRateLimitExceeded(err) {
-			return h.ErrorResponse(c, fiber.StatusTooManyRequests, "Please wait before requesting another OTP", "RATE_LIMITED", nil)
+			return h.otpRateLimited(c, err)
 		}
 		// Handle specific business errors
 		if businessflow.IsCustomerNotFound(err) {
 			return h.ErrorResponse(c, fiber.StatusNotFound, "Customer not found", "CUSTOMER_NOT_FOUND", nil)
 		}
 		if businessflow.IsAccountInactive(err) {
-			return h.ErrorResponse(c, fiber.StatusBadRequest, "Account is inactive", "ACCOUNT_INACTIVE", nil)
+			return h.ErrorResponse(c, fiber.StatusForbidden, "Account is inactive", "ACCOUNT_INACTIVE", nil)
 		}
 		if businessflow.IsAccountTypeNotFound(err) {
 			return h.ErrorResponse(c, fiber.StatusBadRequest, "Account type not found", "ACCOUNT_TYPE_NOT_FOUND", nil)
@@ -457,9 +612,10 @@ func (h *AuthHandler) ForgotPassword(c fiber.Ctx) error {
 
 	// Successful response
 	return h.SuccessResponse(c, fiber.StatusOK, "Password reset OTP sent to your mobile number", fiber.Map{
-		"customer_id":  result.CustomerID,
-		"masked_phone": result.MaskedPhone,
-		"expires_in":   utils.OTPExpiry.Seconds(),
+		"customer_id":         result.CustomerID,
+		"masked_phone":        result.MaskedPhone,
+		"expires_in":          utils.OTPExpiry.Seconds(),
+		"resend_available_in": result.ResendAvailableIn,
 	})
 }
 
@@ -485,13 +641,13 @@ func (h *AuthHandler) ResetPassword(c fiber.Ctx) error {
 	if err := h.validator.Struct(&req); err != nil {
 		var validationErrors []string
 		for _, err := range err.(validator.ValidationErrors) {
-			validationErrors = append(validationErrors, getValidationErrorMessage(err))
+			validationErrors = append(validationErrors, getValidationErrorMessage(c, err))
 		}
 		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", validationErrors)
 	}
 
 	// Get client information
-	metadata := businessflow.NewClientMetadata(c.IP(), c.Get("User-Agent"))
+	metadata := h.clientMetadata(c)
 	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/auth/reset", 30*time.Second)
 	defer cancel()
 
@@ -501,12 +657,15 @@ func (h *AuthHandler) ResetPassword(c fiber.Ctx) error {
 		if businessflow.IsRateLimitExceeded(err) {
 			return h.ErrorResponse(c, fiber.StatusTooManyRequests, "Too many attempts", "RATE_LIMITED", nil)
 		}
+		if businessflow.IsPasswordRejected(err) {
+			return passwordRejected(c, err)
+		}
 		// Handle specific business errors
 		if businessflow.IsCustomerNotFound(err) {
 			return h.ErrorResponse(c, fiber.StatusNotFound, "Customer not found", "CUSTOMER_NOT_FOUND", nil)
 		}
 		if businessflow.IsAccountInactive(err) {
-			return h.ErrorResponse(c, fiber.StatusBadRequest, "Account is inactive", "ACCOUNT_INACTIVE", nil)
+			return h.ErrorResponse(c, fiber.StatusForbidden, "Account is inactive", "ACCOUNT_INACTIVE", nil)
 		}
 		if businessflow.IsAccountTypeNotFound(err) {
 			return h.ErrorResponse(c, fiber.StatusBadRequest, "Account type not found", "ACCOUNT_TYPE_NOT_FOUND", nil)
@@ -540,6 +699,455 @@ func (h *AuthHandler) ResetPassword(c fiber.Ctx) error {
 	})
 }
 
+// ChangePassword handles changing the password of the logged in customer
+// @Summary Change Password
+// @Description Replace the password after confirming the current one. The new password must meet the password policy; refused passwords list every reason in details. Every other session is ended and the current one stays logged in.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param request body dto.ChangePasswordRequest true "Current and new password"
+// @Success 200 {object} dto.APIResponse{data=dto.ChangePasswordResponse} "Password changed"
+// @Failure 400 {object} dto.APIResponse "Validation error, or PASSWORD_REJECTED with each dto.PasswordRejectionDTO reason in error.details"
+// @Failure 401 {object} dto.APIResponse "Unauthorized or incorrect current password"
+// @Failure 403 {object} dto.APIResponse "Not allowed while impersonating the customer"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
+// @Router /api/v1/auth/password [put]
+func (h *AuthHandler) ChangePassword(c fiber.Ctx) error {
+	var req dto.ChangePasswordRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body", "INVALID_REQUEST", err.Error())
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		var validationErrors []string
+		for _, err := range err.(validator.ValidationErrors) {
+			validationErrors = append(validationErrors, getValidationErrorMessage(c, err))
+		}
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", validationErrors)
+	}
+
+	customerID, ok := middleware.GetCustomerIDFromContext(c)
+	if !ok || customerID == 0 {
+		return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer ID not found in context", "MISSING_CUSTOMER_ID", nil)
+	}
+	token, ok := middleware.GetAccessTokenFromContext(c)
+	if !ok || token == "" {
+		return h.ErrorResponse(c, fiber.StatusUnauthorized, "Access token is required", "MISSING_ACCESS_TOKEN", nil)
+	}
+
+	metadata := h.clientMetadata(c)
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/auth/password", 30*time.Second)
+	defer cancel()
+
+	result, err := h.loginFlow.ChangePassword(ctx, customerID, token, &req, metadata)
+	if err != nil {
+		if businessflow.IsPasswordRejected(err) {
+			return passwordRejected(c, err)
+		}
+		if businessflow.IsIncorrectPassword(err) {
+			return h.ErrorResponse(c, fiber.StatusUnauthorized, "Incorrect password", "INCORRECT_PASSWORD", nil)
+		}
+		if businessflow.IsCustomerNotFound(err) {
+			return h.ErrorResponse(c, fiber.StatusNotFound, "Customer not found", "CUSTOMER_NOT_FOUND", nil)
+		}
+		if businessflow.IsAccountInactive(err) {
+			return h.ErrorResponse(c, fiber.StatusForbidden, "Account is inactive", "ACCOUNT_INACTIVE", nil)
+		}
+
+		log.Println("Change password failed", err)
+		return h.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to change password", "CHANGE_PASSWORD_FAILED", nil)
+	}
+
+	return h.SuccessResponse(c, fiber.StatusOK, result.Message, result)
+}
+
+// RequestMobileChange handles starting a change of the representative mobile
+// @Summary Request Mobile Change
+// @Description Start replacing the representative mobile after confirming the current password. A code is sent to the new mobile; confirm it with PUT /api/v1/auth/mobile. Requesting again replaces the pending mobile and code.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param request body dto.MobileChangeRequest true "Current password and new mobile"
+// @Success 200 {object} dto.APIResponse{data=dto.MobileChangeOTPResponse} "Code sent to the new mobile"
+// @Failure 400 {object} dto.APIResponse "Validation error, invalid mobile or the current mobile"
+// @Failure 401 {object} dto.APIResponse "Unauthorized or incorrect current password"
+// @Failure 403 {object} dto.APIResponse "Not allowed while impersonating the customer"
+// @Failure 409 {object} dto.APIResponse "Mobile number belongs to another account"
+// @Failure 429 {object} dto.APIResponse "Please wait before requesting another OTP"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
+// @Router /api/v1/auth/mobile/otp [post]
+func (h *AuthHandler) RequestMobileChange(c fiber.Ctx) error {
+	var req dto.MobileChangeRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body", "INVALID_REQUEST", err.Error())
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		var validationErrors []string
+		for _, err := range err.(validator.ValidationErrors) {
+			validationErrors = append(validationErrors, getValidationErrorMessage(c, err))
+		}
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", validationErrors)
+	}
+
+	customerID, ok := middleware.GetCustomerIDFromContext(c)
+	if !ok || customerID == 0 {
+		return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer ID not found in context", "MISSING_CUSTOMER_ID", nil)
+	}
+
+	metadata := h.clientMetadata(c)
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/auth/mobile/otp", 30*time.Second)
+	defer cancel()
+
+	result, err := h.loginFlow.RequestMobileChange(ctx, customerID, &req, metadata)
+	if err != nil {
+		if businessflow.IsIncorrectPassword(err) {
+			return h.ErrorResponse(c, fiber.StatusUnauthorized, "Incorrect password", "INCORRECT_PASSWORD", nil)
+		}
+		if businessflow.IsInvalidMobileNumber(err) {
+			return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid mobile number", "INVALID_MOBILE_NUMBER", nil)
+		}
+		if businessflow.IsMobileUnchanged(err) {
+			return h.ErrorResponse(c, fiber.StatusBadRequest, "New mobile number is the current one", "MOBILE_UNCHANGED", nil)
+		}
+		if businessflow.IsMobileAlreadyExists(err) {
+			return h.ErrorResponse(c, fiber.StatusConflict, "Mobile number already exists", "MOBILE_EXISTS", nil)
+		}
+		if businessflow.IsRateLimitExceeded(err) {
+			return h.otpRateLimited(c, err)
+		}
+		if businessflow.IsCustomerNotFound(err) {
+			return h.ErrorResponse(c, fiber.StatusNotFound, "Customer not found", "CUSTOMER_NOT_FOUND", nil)
+		}
+		if businessflow.IsAccountInactive(err) {
+			return h.ErrorResponse(c, fiber.StatusForbidden, "Account is inactive", "ACCOUNT_INACTIVE", nil)
+		}
+		if businessflow.IsCacheNotAvailable(err) {
+			return h.ErrorResponse(c, fiber.StatusServiceUnavailable, "Cache not available", "CACHE_NOT_AVAILABLE", nil)
+		}
+
+		log.Println("Request mobile change failed", err)
+		return h.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to change mobile number", "MOBILE_CHANGE_FAILED", nil)
+	}
+
+	return h.SuccessResponse(c, fiber.StatusOK, result.Message, result)
+}
+
+// ConfirmMobileChange handles completing a change of the representative mobile
+// @Summary Confirm Mobile Change
+// @Description Replace the representative mobile with the pending one after verifying the code sent to it. Every other session is ended and the current one stays logged in.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param request body dto.MobileChangeConfirmRequest true "Code sent to the new mobile"
+// @Success 200 {object} dto.APIResponse{data=dto.MobileChangeResponse} "Mobile changed"
+// @Failure 400 {object} dto.APIResponse "Validation error, no pending change or invalid code"
+// @Failure 401 {object} dto.APIResponse "Unauthorized"
+// @Failure 403 {object} dto.APIResponse "Not allowed while impersonating the customer"
+// @Failure 409 {object} dto.APIResponse "Mobile number was taken by another account"
+// @Failure 429 {object} dto.APIResponse "Too many wrong codes; request a new one"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
+// @Router /api/v1/auth/mobile [put]
+func (h *AuthHandler) ConfirmMobileChange(c fiber.Ctx) error {
+	var req dto.MobileChangeConfirmRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body", "INVALID_REQUEST", err.Error())
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		var validationErrors []string
+		for _, err := range err.(validator.ValidationErrors) {
+			validationErrors = append(validationErrors, getValidationErrorMessage(c, err))
+		}
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", validationErrors)
+	}
+
+	customerID, ok := middleware.GetCustomerIDFromContext(c)
+	if !ok || customerID == 0 {
+		return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer ID not found in context", "MISSING_CUSTOMER_ID", nil)
+	}
+	token, ok := middleware.GetAccessTokenFromContext(c)
+	if !ok || token == "" {
+		return h.ErrorResponse(c, fiber.StatusUnauthorized, "Access token is required", "MISSING_ACCESS_TOKEN", nil)
+	}
+
+	metadata := h.clientMetadata(c)
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/auth/mobile", 30*time.Second)
+	defer cancel()
+
+	result, err := h.loginFlow.ConfirmMobileChange(ctx, customerID, token, &req, metadata)
+	if err != nil {
+		if businessflow.IsNoValidOTPFound(err) {
+			return h.ErrorResponse(c, fiber.StatusBadRequest, "No valid OTP found", "NO_VALID_OTP", nil)
+		}
+		if businessflow.IsInvalidOTPCode(err) {
+			return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid OTP code", "INVALID_OTP_CODE", nil)
+		}
+		if businessflow.IsRateLimitExceeded(err) {
+			return h.ErrorResponse(c, fiber.StatusTooManyRequests, "Too many attempts", "RATE_LIMITED", nil)
+		}
+		if businessflow.IsMobileUnchanged(err) {
+			return h.ErrorResponse(c, fiber.StatusBadRequest, "New mobile number is the current one", "MOBILE_UNCHANGED", nil)
+		}
+		if businessflow.IsMobileAlreadyExists(err) {
+			return h.ErrorResponse(c, fiber.StatusConflict, "Mobile number already exists", "MOBILE_EXISTS", nil)
+		}
+		if businessflow.IsCustomerNotFound(err) {
+			return h.ErrorResponse(c, fiber.StatusNotFound, "Customer not found", "CUSTOMER_NOT_FOUND", nil)
+		}
+		if businessflow.IsAccountInactive(err) {
+			return h.ErrorResponse(c, fiber.StatusForbidden, "Account is inactive", "ACCOUNT_INACTIVE", nil)
+		}
+		if businessflow.IsCacheNotAvailable(err) {
+			return h.ErrorResponse(c, fiber.StatusServiceUnavailable, "Cache not available", "CACHE_NOT_AVAILABLE", nil)
+		}
+
+		log.Println("Confirm mobile change failed", err)
+		return h.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to change mobile number", "MOBILE_CHANGE_FAILED", nil)
+	}
+
+	return h.SuccessResponse(c, fiber.StatusOK, result.Message, result)
+}
+
+// ResendLoginOTP handles resending an outstanding login, passwordless login or
+// password reset OTP
+// @Summary Resend Login OTP
+// @Description Send a new code for an outstanding login, passwordless login or password reset OTP. Sends to a mobile number are limited by a cooldown and a daily limit.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param request body dto.LoginOTPResendRequest true "OTP resend request"
+// @Success 200 {object} dto.APIResponse{data=object{otp_sent=bool,otp_target=string,resend_available_in=int}} "OTP resent successfully"
+// @Failure 400 {object} dto.APIResponse "Invalid request or no outstanding OTP"
+// @Failure 404 {object} dto.APIResponse "User not found"
+// @Failure 429 {object} dto.APIResponse "OTP requested too soon; details.retry_after_seconds says how long to wait"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Router /api/v1/auth/otp/resend [post]
+func (h *AuthHandler) ResendLoginOTP(c fiber.Ctx) error {
+	var req dto.LoginOTPResendRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body", "INVALID_REQUEST", err.Error())
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		var validationErrors []string
+		for _, err := range err.(validator.ValidationErrors) {
+			validationErrors = append(validationErrors, getValidationErrorMessage(c, err))
+		}
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", validationErrors)
+	}
+
+	metadata := h.clientMetadata(c)
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/auth/otp/resend", 30*time.Second)
+	defer cancel()
+
+	result, err := h.loginFlow.ResendOTP(ctx, &req, metadata)
+	if err != nil {
+		if businessflow.IsRateLimitExceeded(err) {
+			return h.otpRateLimited(c, err)
+		}
+		if businessflow.IsCustomerNotFound(err) {
+			return h.ErrorResponse(c, fiber.StatusNotFound, "Customer not found", "CUSTOMER_NOT_FOUND", nil)
+		}
+		if businessflow.IsAccountInactive(err) {
+			return h.ErrorResponse(c, fiber.StatusForbidden, "Account is inactive", "ACCOUNT_INACTIVE", nil)
+		}
+		if businessflow.IsNoValidOTPFound(err) {
+			return h.ErrorResponse(c, fiber.StatusBadRequest, "No OTP to resend; request a new one", "NO_VALID_OTP", nil)
+		}
+		if businessflow.IsInvalidOTPType(err) {
+			return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid OTP purpose", "INVALID_OTP_PURPOSE", nil)
+		}
+		if businessflow.IsPasswordlessLoginDisabled(err) {
+			return h.ErrorResponse(c, fiber.StatusForbidden, "Login with an SMS code is disabled", "PASSWORDLESS_LOGIN_DISABLED", nil)
+		}
+		if businessflow.IsCacheNotAvailable(err) {
+			return h.ErrorResponse(c, fiber.StatusServiceUnavailable, "Cache not available", "CACHE_NOT_AVAILABLE", nil)
+		}
+
+		log.Println("Resend login OTP failed", err)
+		return h.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to resend OTP", "RESEND_OTP_FAILED", nil)
+	}
+
+	return h.SuccessResponse(c, fiber.StatusOK, result.Message, fiber.Map{
+		"otp_sent":            result.OTPSent,
+		"otp_target":          result.MaskedOTPTarget,
+		"resend_available_in": result.ResendAvailableIn,
+	})
+}
+
+// Logout handles ending the current session
+// @Summary Logout
+// @Description End the session of the access token in the Authorization header. The token is rejected from the next request on.
+// @Tags Authentication
+// @Produce json
+// @Success 200 {object} dto.APIResponse "Logged out successfully"
+// @Failure 401 {object} dto.APIResponse "Unauthorized"
+// @Failure 404 {object} dto.APIResponse "Session not found"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
+// @Router /api/v1/auth/logout [post]
+func (h *AuthHandler) Logout(c fiber.Ctx) error {
+	customerID, ok := middleware.GetCustomerIDFromContext(c)
+	if !ok || customerID == 0 {
+		return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer ID not found in context", "MISSING_CUSTOMER_ID", nil)
+	}
+	token, ok := middleware.GetAccessTokenFromContext(c)
+	if !ok || token == "" {
+		return h.ErrorResponse(c, fiber.StatusUnauthorized, "Access token is required", "MISSING_ACCESS_TOKEN", nil)
+	}
+
+	metadata := h.clientMetadata(c)
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/auth/logout", 30*time.Second)
+	defer cancel()
+
+	if err := h.loginFlow.Logout(ctx, customerID, token, metadata); err != nil {
+		if businessflow.IsSessionNotFound(err) {
+			return h.ErrorResponse(c, fiber.StatusNotFound, "Session not found", "SESSION_NOT_FOUND", nil)
+		}
+
+		log.Println("Logout failed", err)
+		return h.ErrorResponse(c, fiber.StatusInternalServerError, "Logout failed", "LOGOUT_FAILED", nil)
+	}
+
+	return h.SuccessResponse(c, fiber.StatusOK, "Logged out successfully", nil)
+}
+
+// ListSessions handles listing the devices signed in to the account
+// @Summary List Sessions
+// @Description List the active sessions of the authenticated customer with their device, IP address, last access, and estimated location. The session of the calling token has current=true.
+// @Tags Authentication
+// @Produce json
+// @Success 200 {object} dto.APIResponse{data=dto.ListSessionsResponse} "Sessions retrieved successfully"
+// @Failure 401 {object} dto.APIResponse "Unauthorized"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
+// @Router /api/v1/auth/sessions [get]
+func (h *AuthHandler) ListSessions(c fiber.Ctx) error {
+	customerID, ok := middleware.GetCustomerIDFromContext(c)
+	if !ok || customerID == 0 {
+		return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer ID not found in context", "MISSING_CUSTOMER_ID", nil)
+	}
+	token, _ := middleware.GetAccessTokenFromContext(c)
+
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/auth/sessions", 30*time.Second)
+	defer cancel()
+
+	result, err := h.loginFlow.ListSessions(ctx, customerID, token)
+	if err != nil {
+		log.Println("List sessions failed", err)
+		return h.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to list sessions", "LIST_SESSIONS_FAILED", nil)
+	}
+
+	return h.SuccessResponse(c, fiber.StatusOK, result.Message, result)
+}
+
+// RevokeSession handles signing a device out of the account
+// @Summary Revoke Session
+// @Description End one active session of the authenticated customer. Its access token is rejected from the next request on.
+// @Tags Authentication
+// @Produce json
+// @Param id path int true "Session ID"
+// @Success 200 {object} dto.APIResponse "Session revoked successfully"
+// @Failure 400 {object} dto.APIResponse "Invalid session ID"
+// @Failure 401 {object} dto.APIResponse "Unauthorized"
+// @Failure 404 {object} dto.APIResponse "Session not found"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
+// @Router /api/v1/auth/sessions/{id} [delete]
+func (h *AuthHandler) RevokeSession(c fiber.Ctx) error {
+	customerID, ok := middleware.GetCustomerIDFromContext(c)
+	if !ok || customerID == 0 {
+		return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer ID not found in context", "MISSING_CUSTOMER_ID", nil)
+	}
+	sessionID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil || sessionID == 0 {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid session ID", "INVALID_SESSION_ID", nil)
+	}
+
+	metadata := h.clientMetadata(c)
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/auth/sessions/"+c.Params("id"), 30*time.Second)
+	defer cancel()
+
+	if err := h.loginFlow.RevokeSession(ctx, customerID, uint(sessionID), metadata); err != nil {
+		if businessflow.IsSessionNotFound(err) {
+			return h.ErrorResponse(c, fiber.StatusNotFound, "Session not found", "SESSION_NOT_FOUND", nil)
+		}
+
+		log.Println("Revoke session failed", err)
+		return h.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to revoke session", "REVOKE_SESSION_FAILED", nil)
+	}
+
+	return h.SuccessResponse(c, fiber.StatusOK, "Session revoked successfully", nil)
+}
+
+// ReportLoginNotMe handles the "this wasn't me" link of a new-device login alert
+// @Summary Report Login Not Me
+// @Description Report the login of a new-device alert as not the customer's. Every session of the customer is ended and login is refused until the password is reset through forgot password.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param request body dto.LoginAlertReportRequest true "Alert link token"
+// @Success 200 {object} dto.APIResponse{data=dto.LoginAlertReportResponse} "Login reported"
+// @Failure 400 {object} dto.APIResponse "Validation error"
+// @Failure 404 {object} dto.APIResponse "Alert link not found or expired"
+// @Failure 503 {object} dto.APIResponse "Cache not available"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Router /api/v1/auth/login-alerts/not-me [post]
+func (h *AuthHandler) ReportLoginNotMe(c fiber.Ctx) error {
+	var req dto.LoginAlertReportRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body", "INVALID_REQUEST", err.Error())
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		var validationErrors []string
+		for _, err := range err.(validator.ValidationErrors) {
+			validationErrors = append(validationErrors, getValidationErrorMessage(c, err))
+		}
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", validationErrors)
+	}
+
+	metadata := h.clientMetadata(c)
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/auth/login-alerts/not-me", 30*time.Second)
+	defer cancel()
+
+	result, err := h.loginFlow.ReportLoginNotMe(ctx, &req, metadata)
+	if err != nil {
+		if businessflow.IsLoginAlertNotFound(err) {
+			return h.ErrorResponse(c, fiber.StatusNotFound, "Alert link not found or expired", "LOGIN_ALERT_NOT_FOUND", nil)
+		}
+		if businessflow.IsCacheNotAvailable(err) {
+			return h.ErrorResponse(c, fiber.StatusServiceUnavailable, "Cache not available", "CACHE_NOT_AVAILABLE", nil)
+		}
+
+		log.Println("Report login not me failed", err)
+		return h.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to report login", "LOGIN_ALERT_REPORT_FAILED", nil)
+	}
+
+	return h.SuccessResponse(c, fiber.StatusOK, result.Message, result)
+}
+
+// otpRateLimited responds to a throttled OTP request with how long to wait
+// when it is known
+func (h *AuthHandler) otpRateLimited(c fiber.Ctx, err error) error {
+	retryAfter, ok := businessflow.OTPRetryAfter(err)
+	if !ok {
+		return h.ErrorResponse(c, fiber.StatusTooManyRequests, "Please wait before requesting another OTP", "RATE_LIMITED", nil)
+	}
+	seconds := int((retryAfter + time.Second - 1) / time.Second)
+	if seconds < 1 {
+		seconds = 1
+	}
+	c.Set(fiber.HeaderRetryAfter, strconv.Itoa(seconds))
+	return h.ErrorResponse(c, fiber.StatusTooManyRequests, "Please wait before requesting another OTP", "RATE_LIMITED", fiber.Map{
+		"retry_after_seconds": seconds,
+	})
+}
+
 // Health handles health check requests
 // @Summary Health Check
 // @Description Check the health status of the API
@@ -548,6 +1156,7 @@ func (h *AuthHandler) ResetPassword(c fiber.Ctx) error {
 // @Produce json
 // @Success 200 {object} dto.APIResponse "Service is healthy"
 // @Router /api/v1/health [get]
+// @Router /api/v2/health [get]
 func (h *AuthHandler) Health(c fiber.Ctx) error {
 	return h.SuccessResponse(c, fiber.StatusOK, "Auth service is healthy", fiber.Map{
 		"status":    "healthy",
@@ -557,6 +1166,19 @@ func (h *AuthHandler) Health(c fiber.Ctx) error {
 }
 
 // createRequestContextWithTimeout creates a context with custom timeout and request-scoped values
+// clientMetadata describes the client of a request for audit logs and new
+// sessions, with the country the reverse proxy resolved for its IP
+func (h *AuthHandler) clientMetadata(c fiber.Ctx) *businessflow.ClientMetadata {
+	metadata := businessflow.NewClientMetadata(c.IP(), c.Get("User-Agent"))
+	if h.countryHeader == "" {
+		return metadata
+	}
+	if country := strings.ToUpper(strings.TrimSpace(c.Get(h.countryHeader))); len(country) == 2 && country != "XX" {
+		metadata.SetLocation(&businessflow.LocationInfo{Country: country})
+	}
+	return metadata
+}
+
 func (h *AuthHandler) createRequestContextWithTimeout(c fiber.Ctx, endpoint string, timeout time.Duration) (context.Context, context.CancelFunc) {
 	// Create context with custom timeout
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
@@ -568,6 +1190,7 @@ func (h *AuthHandler) createRequestContextWithTimeout(c fiber.Ctx, endpoint stri
 	ctx = context.WithValue(ctx, utils.EndpointKey, endpoint)
 	ctx = context.WithValue(ctx, utils.TimeoutKey, timeout)
 	ctx = context.WithValue(ctx, utils.CancelFuncKey, cancel) // Store cancel function for cleanup
+	ctx = middleware.WithImpersonation(ctx, c)
 
 	return ctx, cancel
 }
@@ -587,40 +1210,8 @@ func (h *AuthHandler) setupCustomValidations() {
 
 	// Register custom validation for mobile format
 	h.validator.RegisterValidation("mobile_format", func(fl validator.FieldLevel) bool {
-		value := fl.Field().String()
-		// Iranian mobile format: +989xxxxxxxxx
-		if len(value) != 13 {
-			return false
-		}
-		if value[:4] != "+989" {
-			return false
-		}
-		// Check if remaining characters are digits
-		for _, char := range value[4:] {
-			if char < '0' || char > '9' {
-				return false
-			}
-		}
-		return true
-	})
-
-	// Register custom validation for password strength
-	h.validator.RegisterValidation("password_strength", func(fl validator.FieldLevel) bool {
-		value := fl.Field().String()
-
-		hasUpper := false
-		hasNumber := false
-
-		for _, char := range value {
-			if char >= 'A' && char <= 'Z' {
-				hasUpper = true
-			}
-			if char >= '0' && char <= '9' {
-				hasNumber = true
-			}
-		}
-
-		return hasUpper && hasNumber
+		// Any Iranian mobile form is accepted; the flow stores it as +989xxxxxxxxx
+		return phonenumber.IsValid(fl.Field().String())
 	})
 
 	h.validator.RegisterValidation("numeric", func(fl validator.FieldLevel) bool {
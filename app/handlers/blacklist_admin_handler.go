@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/apierror"
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/app/middleware"
+	businessflow "github.com/amirphl/Yamata-no-Orochi/business_flow"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v3"
+)
+
+const blacklistUploadTimeout = 5 * time.Minute
+
+// BlacklistAdminHandlerInterface defines admin endpoints for the global recipient blacklist
+type BlacklistAdminHandlerInterface interface {
+	UploadCSV(c fiber.Ctx) error
+	List(c fiber.Ctx) error
+	Delete(c fiber.Ctx) error
+}
+
+// BlacklistAdminHandler implements the admin blacklist endpoints
+type BlacklistAdminHandler struct {
+	flow      businessflow.BlacklistFlow
+	validator *validator.Validate
+}
+
+func NewBlacklistAdminHandler(flow businessflow.BlacklistFlow) BlacklistAdminHandlerInterface {
+	return &BlacklistAdminHandler{
+		flow:      flow,
+		validator: validator.New(),
+	}
+}
+
+func (h *BlacklistAdminHandler) ErrorResponse(c fiber.Ctx, status int, message, code string, details any) error {
+	return apierror.Respond(c, status, message, code, details)
+}
+
+func (h *BlacklistAdminHandler) SuccessResponse(c fiber.Ctx, status int, message string, data any) error {
+	return c.Status(status).JSON(dto.APIResponse{Success: true, Message: message, Data: data})
+}
+
+// UploadCSV blacklists the phone numbers of a CSV file
+// @Summary Upload Blacklist CSV (Admin)
+// @Description Blacklist the numbers of a CSV with a phone_number column and an optional reason column. Blacklisted numbers are never included in campaign batches. Rows with invalid numbers are reported and skipped.
+// @Tags Admin Blacklist
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "CSV file with phone_number and optional reason columns"
+// @Param source formData string false "Source of the numbers (regulator|complaint|admin)" default(admin)
+// @Param reason formData string false "Reason applied to rows without their own reason"
+// @Success 200 {object} dto.APIResponse{data=dto.BlacklistUploadResponse}
+// @Failure 400 {object} dto.APIResponse "Invalid file or source"
+// @Failure 500 {object} dto.APIResponse "Upload failed"
+// @Security AdminBearer
+// @Router /api/v1/admin/blacklist [post]
+func (h *BlacklistAdminHandler) UploadCSV(c fiber.Ctx) error {
+	fileHeader, err := c.FormFile("file")
+	if err != nil || fileHeader == nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "file is required", "INVALID_REQUEST", nil)
+	}
+	fh, err := openFormFile(fileHeader)
+	if err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "invalid file", "INVALID_FILE", err.Error())
+	}
+	defer fh.Close()
+
+	var reason *string
+	if r := c.FormValue("reason"); r != "" {
+		reason = &r
+	}
+
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/admin/blacklist", blacklistUploadTimeout)
+	defer cancel()
+	res, err := h.flow.Upload(ctx, fh, c.FormValue("source"), reason)
+	if err != nil {
+		return h.handleFlowError(c, "Failed to upload blacklist", "BLACKLIST_UPLOAD_FAILED", err)
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, "Blacklist uploaded successfully", res)
+}
+
+// List returns blacklisted numbers
+// @Summary List Blacklisted Numbers (Admin)
+// @Tags Admin Blacklist
+// @Produce json
+// @Param phone_number query string false "Filter by phone number"
+// @Param source query string false "Filter by source (regulator|complaint|admin)"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Page size" default(10) maximum(100)
+// @Success 200 {object} dto.APIResponse{data=dto.AdminListBlacklistResponse}
+// @Failure 400 {object} dto.APIResponse "Validation error"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security AdminBearer
+// @Router /api/v1/admin/blacklist [get]
+func (h *BlacklistAdminHandler) List(c fiber.Ctx) error {
+	page, err := strconv.Atoi(c.Query("page", "1"))
+	if err != nil || page <= 0 {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid page format", "INVALID_PAGE", nil)
+	}
+	limit, err := strconv.Atoi(c.Query("limit", "10"))
+	if err != nil || limit <= 0 {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid limit format", "INVALID_LIMIT", nil)
+	}
+
+	filter := dto.AdminListBlacklistFilter{
+		Page:  page,
+		Limit: limit,
+	}
+	if phone := strings.TrimSpace(c.Query("phone_number")); phone != "" {
+		filter.PhoneNumber = &phone
+	}
+	if source := strings.TrimSpace(c.Query("source")); source != "" {
+		filter.Source = &source
+	}
+	if err := h.validator.Struct(filter); err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", nil)
+	}
+
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/admin/blacklist", 30*time.Second)
+	defer cancel()
+	res, err := h.flow.List(ctx, filter)
+	if err != nil {
+		return h.handleFlowError(c, "Failed to list blacklisted numbers", "BLACKLIST_LIST_FAILED", err)
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, "Blacklisted numbers retrieved successfully", res)
+}
+
+// Delete removes a number from the blacklist
+// @Summary Delete Blacklisted Number (Admin)
+// @Tags Admin Blacklist
+// @Produce json
+// @Param phone_number path string true "Phone number, e.g. 09123456789"
+// @Success 200 {object} dto.APIResponse{data=dto.AdminDeleteBlacklistedNumberResponse}
+// @Failure 400 {object} dto.APIResponse "Invalid phone number"
+// @Failure 404 {object} dto.APIResponse "Number is not blacklisted"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security AdminBearer
+// @Router /api/v1/admin/blacklist/{phone_number} [delete]
+func (h *BlacklistAdminHandler) Delete(c fiber.Ctx) error {
+	phone, err := url.PathUnescape(c.Params("phone_number"))
+	if err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid phone number", "BLACKLIST_PHONE_INVALID", nil)
+	}
+
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/admin/blacklist/:phone_number", 30*time.Second)
+	defer cancel()
+	res, err := h.flow.Delete(ctx, phone)
+	if err != nil {
+		return h.handleFlowError(c, "Failed to remove blacklisted number", "BLACKLIST_DELETE_FAILED", err)
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, "Number removed from blacklist", res)
+}
+
+func (h *BlacklistAdminHandler) handleFlowError(c fiber.Ctx, message, code string, err error) error {
+	switch {
+	case businessflow.IsBlacklistedNumberNotFound(err):
+		return h.ErrorResponse(c, fiber.StatusNotFound, "Number is not blacklisted", "BLACKLISTED_NUMBER_NOT_FOUND", nil)
+	case businessflow.IsBlacklistRequestInvalid(err):
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid blacklist request", "BLACKLIST_REQUEST_INVALID", businessErrorMessage(err))
+	case businessflow.IsBlacklistFileInvalid(err):
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid blacklist file", "BLACKLIST_FILE_INVALID", businessErrorMessage(err))
+	}
+	log.Println(message+":", err)
+	return h.ErrorResponse(c, fiber.StatusInternalServerError, message, code, nil)
+}
+
+func (h *BlacklistAdminHandler) createRequestContextWithTimeout(c fiber.Ctx, endpoint string, timeout time.Duration) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	ctx = context.WithValue(ctx, utils.RequestIDKey, c.Get("X-Request-ID"))
+	ctx = context.WithValue(ctx, utils.UserAgentKey, c.Get("User-Agent"))
+	ctx = context.WithValue(ctx, utils.IPAddressKey, c.IP())
+	ctx = context.WithValue(ctx, utils.EndpointKey, endpoint)
+	ctx = context.WithValue(ctx, utils.TimeoutKey, timeout)
+	ctx = context.WithValue(ctx, utils.CancelFuncKey, cancel)
+	if adminID, ok := middleware.GetAdminIDFromContext(c); ok {
+		ctx = context.WithValue(ctx, utils.AdminIDKey, adminID)
+	}
+	return ctx, cancel
+}
+
+// businessErrorMessage returns the user-facing message of a business error
+func businessErrorMessage(err error) any {
+	var be *businessflow.BusinessError
+	if errors.As(err, &be) {
+		return be.Message
+	}
+	return nil
+}
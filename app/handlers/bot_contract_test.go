@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/botcontract"
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	businessflow "github.com/amirphl/Yamata-no-Orochi/business_flow"
+	"github.com/gofiber/fiber/v3"
+)
+
+// The flows below answer with the recorded response data, so the tests check
+// that the handlers accept the recorded requests and that the DTOs they
+// encode keep every recorded field.
+
+type contractBotAuthFlow struct {
+	businessflow.BotAuthFlow
+}
+
+func (contractBotAuthFlow) Verify(ctx context.Context, req *dto.BotLoginRequest, metadata *businessflow.ClientMetadata) (*dto.BotLoginResponse, error) {
+	var res dto.BotLoginResponse
+	return &res, json.Unmarshal(botcontract.Fixture("login").Data(), &res)
+}
+
+type contractBotCampaignFlow struct {
+	businessflow.BotCampaignFlow
+}
+
+func (contractBotCampaignFlow) ListReadyCampaigns(ctx context.Context, platform *string) (*dto.BotListCampaignsResponse, error) {
+	var res dto.BotListCampaignsResponse
+	return &res, json.Unmarshal(botcontract.Fixture("ready_campaigns").Data(), &res)
+}
+
+type contractBotShortLinkFlow struct {
+	businessflow.BotShortLinkFlow
+}
+
+func (contractBotShortLinkFlow) CreateShortLinks(ctx context.Context, req *dto.BotCreateShortLinksRequest) (*dto.BotCreateShortLinksResponse, error) {
+	var res dto.BotCreateShortLinksResponse
+	return &res, json.Unmarshal(botcontract.Fixture("create_short_links").Data(), &res)
+}
+
+func (contractBotShortLinkFlow) GenerateAndCreateShortLinks(ctx context.Context, req *dto.BotAllocateShortLinksRequest) ([]string, error) {
+	var res dto.BotAllocateShortLinksResponse
+	return res.Codes, json.Unmarshal(botcontract.Fixture("allocate_short_links").Data(), &res)
+}
+
+func newBotContractApp() *fiber.App {
+	app := fiber.New()
+	auth := NewAuthBotHandler(contractBotAuthFlow{})
+	campaigns := NewCampaignBotHandler(contractBotCampaignFlow{})
+	shortLinks := NewShortLinkBotHandler(contractBotShortLinkFlow{})
+	app.Post("/api/v1/bot/auth/login", auth.Login)
+	app.Get("/api/v1/bot/campaigns/ready", campaigns.ListReadyCampaigns)
+	app.Post("/api/v1/bot/short-links", shortLinks.CreateShortLinks)
+	app.Post("/api/v1/bot/short-links/allocate", shortLinks.AllocateShortLinks)
+	return app
+}
+
+func TestBotHandlersHonourRecordedContract(t *testing.T) {
+	app := newBotContractApp()
+	for _, i := range botcontract.Interactions() {
+		t.Run(i.Name, func(t *testing.T) {
+			var body io.Reader = http.NoBody
+			if len(i.Request) > 0 {
+				body = bytes.NewReader(i.Request)
+			}
+			req := httptest.NewRequest(i.Method, i.Path, body)
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("request: %v", err)
+			}
+			defer resp.Body.Close()
+			got, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatalf("read response: %v", err)
+			}
+			if resp.StatusCode != i.Status {
+				t.Fatalf("status = %d, recorded %d: %s", resp.StatusCode, i.Status, got)
+			}
+			if err := botcontract.Conforms(i.Response, got); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
@@ -8,7 +8,9 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/amirphl/Yamata-no-Orochi/app/apierror"
 	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/app/middleware"
 	businessflow "github.com/amirphl/Yamata-no-Orochi/business_flow"
 	"github.com/amirphl/Yamata-no-Orochi/utils"
 	"github.com/go-playground/validator/v10"
@@ -40,14 +42,7 @@ func NewBundleHandler(flow businessflow.BundleFlow, evaluationFlow businessflow.
 }
 
 func (h *BundleHandler) ErrorResponse(c fiber.Ctx, statusCode int, message, errorCode string, details any) error {
-	return c.Status(statusCode).JSON(dto.APIResponse{
-		Success: false,
-		Message: message,
-		Error: dto.ErrorDetail{
-			Code:    errorCode,
-			Details: details,
-		},
-	})
+	return apierror.Respond(c, statusCode, message, errorCode, details)
 }
 
 func (h *BundleHandler) SuccessResponse(c fiber.Ctx, statusCode int, message string, data any) error {
@@ -69,6 +64,7 @@ func (h *BundleHandler) SuccessResponse(c fiber.Ctx, statusCode int, message str
 // @Failure 400 {object} dto.APIResponse "Validation error"
 // @Failure 401 {object} dto.APIResponse "Unauthorized"
 // @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
 // @Router /api/v1/bundles [post]
 func (h *BundleHandler) Create(c fiber.Ctx) error {
 	var req dto.CreateBundleRequest
@@ -85,7 +81,7 @@ func (h *BundleHandler) Create(c fiber.Ctx) error {
 	if err := h.validator.Struct(&req); err != nil {
 		var validationErrors []string
 		for _, e := range err.(validator.ValidationErrors) {
-			validationErrors = append(validationErrors, getValidationErrorMessage(e))
+			validationErrors = append(validationErrors, getValidationErrorMessage(c, e))
 		}
 		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", validationErrors)
 	}
@@ -117,6 +113,7 @@ func (h *BundleHandler) Create(c fiber.Ctx) error {
 // @Failure 403 {object} dto.APIResponse "Forbidden"
 // @Failure 404 {object} dto.APIResponse "Not found"
 // @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
 // @Router /api/v1/bundles/{id} [put]
 func (h *BundleHandler) Update(c fiber.Ctx) error {
 	idStr := c.Params("id")
@@ -140,7 +137,7 @@ func (h *BundleHandler) Update(c fiber.Ctx) error {
 	if err := h.validator.Struct(&req); err != nil {
 		var validationErrors []string
 		for _, e := range err.(validator.ValidationErrors) {
-			validationErrors = append(validationErrors, getValidationErrorMessage(e))
+			validationErrors = append(validationErrors, getValidationErrorMessage(c, e))
 		}
 		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", validationErrors)
 	}
@@ -169,6 +166,7 @@ func (h *BundleHandler) Update(c fiber.Ctx) error {
 // @Failure 403 {object} dto.APIResponse "Forbidden"
 // @Failure 404 {object} dto.APIResponse "Not found"
 // @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
 // @Router /api/v1/bundles/{id} [get]
 func (h *BundleHandler) Get(c fiber.Ctx) error {
 	idStr := c.Params("id")
@@ -212,6 +210,7 @@ func (h *BundleHandler) Get(c fiber.Ctx) error {
 // @Success 200 {object} dto.APIResponse{data=dto.ListBundlesResponse} "Retrieved"
 // @Failure 401 {object} dto.APIResponse "Unauthorized"
 // @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
 // @Router /api/v1/bundles [get]
 func (h *BundleHandler) List(c fiber.Ctx) error {
 	customerID, ok := c.Locals("customer_id").(uint)
@@ -287,6 +286,7 @@ func (h *BundleHandler) List(c fiber.Ctx) error {
 // @Failure 404 {object} dto.APIResponse "Not found"
 // @Failure 409 {object} dto.APIResponse "Evaluation already active or feature disabled"
 // @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
 // @Router /api/v1/bundles/{id}/tag-evaluations [post]
 func (h *BundleHandler) RequestTagEvaluation(c fiber.Ctx) error {
 	id, err := parsePositiveUintParam(c.Params("id"))
@@ -309,14 +309,7 @@ func (h *BundleHandler) RequestTagEvaluation(c fiber.Ctx) error {
 	if err != nil {
 		var conflictErr *businessflow.BundleTagEvaluationConflictError
 		if errors.As(err, &conflictErr) {
-			return c.Status(fiber.StatusConflict).JSON(dto.APIResponse{
-				Success: false,
-				Message: "Bundle tag evaluation is already active",
-				Error: dto.ErrorDetail{
-					Code:    "BUNDLE_TAG_EVALUATION_ACTIVE",
-					Details: conflictErr.Response,
-				},
-			})
+			return apierror.Respond(c, fiber.StatusConflict, "Bundle tag evaluation is already active", "BUNDLE_TAG_EVALUATION_ACTIVE", conflictErr.Response)
 		}
 		return h.handleBundleFlowError(c, err, fiber.StatusInternalServerError, "Failed to request bundle tag evaluation", "REQUEST_BUNDLE_TAG_EVALUATION_FAILED")
 	}
@@ -336,6 +329,7 @@ func (h *BundleHandler) RequestTagEvaluation(c fiber.Ctx) error {
 // @Failure 403 {object} dto.APIResponse "Forbidden"
 // @Failure 404 {object} dto.APIResponse "Not found"
 // @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
 // @Router /api/v1/bundles/{id}/tag-evaluation [get]
 func (h *BundleHandler) GetTagEvaluationStatus(c fiber.Ctx) error {
 	id, err := parsePositiveUintParam(c.Params("id"))
@@ -375,6 +369,7 @@ func (h *BundleHandler) GetTagEvaluationStatus(c fiber.Ctx) error {
 // @Failure 403 {object} dto.APIResponse "Forbidden"
 // @Failure 404 {object} dto.APIResponse "Not found"
 // @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
 // @Router /api/v1/bundles/{id}/tag-scores [get]
 func (h *BundleHandler) ListTagScores(c fiber.Ctx) error {
 	id, err := parsePositiveUintParam(c.Params("id"))
@@ -435,7 +430,7 @@ func (h *BundleHandler) handleBundleFlowError(c fiber.Ctx, err error, defaultSta
 		case "MISSING_CUSTOMER_ID":
 			return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer ID not found", be.Code, nil)
 		case "CUSTOMER_NOT_FOUND":
-			return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer not found", be.Code, nil)
+			return h.ErrorResponse(c, fiber.StatusNotFound, "Customer not found", be.Code, nil)
 		}
 	}
 
@@ -450,6 +445,7 @@ func (h *BundleHandler) createRequestContextWithTimeout(c fiber.Ctx, endpoint st
 	ctx = context.WithValue(ctx, utils.EndpointKey, endpoint)
 	ctx = context.WithValue(ctx, utils.TimeoutKey, timeout)
 	ctx = context.WithValue(ctx, utils.CancelFuncKey, cancel)
+	ctx = middleware.WithImpersonation(ctx, c)
 	return ctx, cancel
 }
 
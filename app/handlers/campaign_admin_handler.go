@@ -9,9 +9,11 @@ import (
 	"strings"
 	"time"
 
+	"github.com/amirphl/Yamata-no-Orochi/app/apierror"
 	"github.com/amirphl/Yamata-no-Orochi/app/dto"
 	"github.com/amirphl/Yamata-no-Orochi/app/middleware"
 	businessflow "github.com/amirphl/Yamata-no-Orochi/business_flow"
+	"github.com/amirphl/Yamata-no-Orochi/listquery"
 	"github.com/amirphl/Yamata-no-Orochi/utils"
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v3"
@@ -23,6 +25,9 @@ type CampaignAdminHandlerInterface interface {
 	GetCampaign(c fiber.Ctx) error
 	ApproveCampaign(c fiber.Ctx) error
 	RejectCampaign(c fiber.Ctx) error
+	RequestCampaignChanges(c fiber.Ctx) error
+	ListCampaignReviews(c fiber.Ctx) error
+	AddCampaignReviewComment(c fiber.Ctx) error
 	CancelCampaign(c fiber.Ctx) error
 	RemoveAudienceSpec(c fiber.Ctx) error
 	RescheduleCampaign(c fiber.Ctx) error
@@ -46,14 +51,7 @@ func NewCampaignAdminHandler(flow businessflow.AdminCampaignFlow) CampaignAdminH
 }
 
 func (h *CampaignAdminHandler) ErrorResponse(c fiber.Ctx, statusCode int, message, errorCode string, details any) error {
-	return c.Status(statusCode).JSON(dto.APIResponse{
-		Success: false,
-		Message: message,
-		Error: dto.ErrorDetail{
-			Code:    errorCode,
-			Details: details,
-		},
-	})
+	return apierror.Respond(c, statusCode, message, errorCode, details)
 }
 
 func (h *CampaignAdminHandler) SuccessResponse(c fiber.Ctx, statusCode int, message string, data any) error {
@@ -72,14 +70,19 @@ func (h *CampaignAdminHandler) SuccessResponse(c fiber.Ctx, statusCode int, mess
 // @Param campaign_title query string false "Filter by campaign title (contains)"
 // @Param bundle_title query string false "Filter by bundle title (contains)"
 // @Param customer_name query string false "Filter by customer name (contains, matches first/last name or company)"
-// @Param status query string false "Filter by status (initiated|in-progress|waiting-for-approval|approved|rejected|expired)"
+// @Param status query string false "Filter by status (initiated|in-progress|waiting-for-approval|changes-requested|approved|rejected|expired)"
 // @Param start_date query string false "Filter created_at >= start_date (RFC3339)"
 // @Param end_date query string false "Filter created_at <= end_date (RFC3339)"
+// @Param include_deleted query bool false "Include soft-deleted campaigns"
+// @Param filter query string false "List filter over id, customer_id, bundle_id, status, phase, title, platform, level1, line_number, budget, num_audience, hidden, is_sandbox, created_at and updated_at, e.g. status:in:approved,running;budget:gte:1000000"
+// @Param sort query string false "Sort fields, minus for descending, ahead of the default schedule order, e.g. -created_at"
+// @Param fields query string false "Item fields to return, e.g. id,title,status"
 // @Param page query int false "Page number" default(1)
 // @Param limit query int false "Page size" default(10) maximum(100)
 // @Success 200 {object} dto.APIResponse{data=dto.AdminListCampaignsResponse}
-// @Failure 400 {object} dto.APIResponse "Validation error"
+// @Failure 400 {object} dto.APIResponse "Validation error or invalid filter, sort or fields"
 // @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security AdminBearer
 // @Router /api/v1/admin/campaigns [get]
 func (h *CampaignAdminHandler) ListCampaigns(c fiber.Ctx) error {
 	pageStr := c.Query("page", "1")
@@ -102,8 +105,11 @@ func (h *CampaignAdminHandler) ListCampaigns(c fiber.Ctx) error {
 	}
 
 	filter := dto.AdminListCampaignsFilter{
-		Page:  page,
-		Limit: limit,
+		IncludeDeleted: c.Query("include_deleted") == "true",
+		Filter:         c.Query("filter"),
+		Sort:           c.Query("sort"),
+		Page:           page,
+		Limit:          limit,
 	}
 	if campaignTitle != "" {
 		filter.CampaignTitle = &campaignTitle
@@ -135,6 +141,10 @@ func (h *CampaignAdminHandler) ListCampaigns(c fiber.Ctx) error {
 	if err := h.validator.Struct(filter); err != nil {
 		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", nil)
 	}
+	fields, err := listquery.ParseFieldSet(c.Query("fields"), dto.AdminGetCampaignResponse{})
+	if err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid filter, sort or fields", "LIST_QUERY_INVALID", err.Error())
+	}
 
 	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/admin/campaigns", 30*time.Second)
 	defer cancel()
@@ -143,15 +153,23 @@ func (h *CampaignAdminHandler) ListCampaigns(c fiber.Ctx) error {
 		if businessflow.IsStartDateAfterEndDate(err) {
 			return h.ErrorResponse(c, fiber.StatusBadRequest, "End date must be after start date", "INVALID_DATE_RANGE", nil)
 		}
+		if businessflow.IsListQueryInvalid(err) {
+			return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid filter, sort or fields", "LIST_QUERY_INVALID", err.Error())
+		}
 		log.Println("Admin list campaigns failed", err)
 		return h.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to list campaigns", "ADMIN_LIST_CAMPAIGNS_FAILED", nil)
 	}
 
-	return h.SuccessResponse(c, fiber.StatusOK, "Campaigns retrieved successfully", fiber.Map{
+	data, err := fields.Project(fiber.Map{
 		"message":    resp.Message,
 		"items":      resp.Items,
 		"pagination": resp.Pagination,
 	})
+	if err != nil {
+		log.Println("Admin list campaigns failed", err)
+		return h.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to list campaigns", "ADMIN_LIST_CAMPAIGNS_FAILED", nil)
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, "Campaigns retrieved successfully", data)
 }
 
 // GetCampaign returns a single campaign by ID
@@ -162,6 +180,7 @@ func (h *CampaignAdminHandler) ListCampaigns(c fiber.Ctx) error {
 // @Success 200 {object} dto.APIResponse{data=dto.AdminGetCampaignResponse}
 // @Failure 404 {object} dto.APIResponse
 // @Failure 500 {object} dto.APIResponse
+// @Security AdminBearer
 // @Router /api/v1/admin/campaigns/{id} [get]
 func (h *CampaignAdminHandler) GetCampaign(c fiber.Ctx) error {
 	id := c.Params("id")
@@ -194,6 +213,7 @@ func (h *CampaignAdminHandler) GetCampaign(c fiber.Ctx) error {
 // @Failure 404 {object} dto.APIResponse "Campaign not found"
 // @Failure 409 {object} dto.APIResponse "Invalid state"
 // @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security AdminBearer
 // @Router /api/v1/admin/campaigns/approve [post]
 func (h *CampaignAdminHandler) ApproveCampaign(c fiber.Ctx) error {
 	var req dto.AdminApproveCampaignRequest
@@ -215,7 +235,7 @@ func (h *CampaignAdminHandler) ApproveCampaign(c fiber.Ctx) error {
 			return h.ErrorResponse(c, fiber.StatusConflict, "Invalid campaign state for approval", "INVALID_STATE", nil)
 		}
 		if businessflow.IsScheduleTimeTooSoon(err) {
-			return h.ErrorResponse(c, fiber.StatusConflict, "Schedule time is too soon", "SCHEDULE_TIME_TOO_SOON", nil)
+			return h.ErrorResponse(c, fiber.StatusBadRequest, "Schedule time is too soon", "SCHEDULE_TIME_TOO_SOON", nil)
 		}
 		if businessflow.IsCustomerNotFound(err) {
 			return h.ErrorResponse(c, fiber.StatusNotFound, "Customer not found", "CUSTOMER_NOT_FOUND", nil)
@@ -259,6 +279,7 @@ func (h *CampaignAdminHandler) ApproveCampaign(c fiber.Ctx) error {
 // @Failure 404 {object} dto.APIResponse "Campaign not found"
 // @Failure 409 {object} dto.APIResponse "Invalid state"
 // @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security AdminBearer
 // @Router /api/v1/admin/campaigns/reject [post]
 func (h *CampaignAdminHandler) RejectCampaign(c fiber.Ctx) error {
 	var req dto.AdminRejectCampaignRequest
@@ -309,6 +330,142 @@ func (h *CampaignAdminHandler) RejectCampaign(c fiber.Ctx) error {
 	return h.SuccessResponse(c, fiber.StatusOK, "Campaign rejected successfully", res)
 }
 
+// RequestCampaignChanges sends a campaign back to its owner for revision
+// @Summary Request Campaign Changes
+// @Description Send a campaign waiting for approval back to the customer with a comment; refunds reserved funds until the customer resubmits
+// @Tags Admin Campaigns
+// @Accept json
+// @Produce json
+// @Param request body dto.AdminRequestCampaignChangesRequest true "Revision request payload"
+// @Success 200 {object} dto.APIResponse{data=dto.AdminRequestCampaignChangesResponse}
+// @Failure 400 {object} dto.APIResponse "Validation error"
+// @Failure 404 {object} dto.APIResponse "Campaign not found"
+// @Failure 409 {object} dto.APIResponse "Invalid state"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security AdminBearer
+// @Router /api/v1/admin/campaigns/request-changes [post]
+func (h *CampaignAdminHandler) RequestCampaignChanges(c fiber.Ctx) error {
+	var req dto.AdminRequestCampaignChangesRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body", "INVALID_REQUEST", err.Error())
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", nil)
+	}
+
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/admin/campaigns/request-changes", 30*time.Second)
+	defer cancel()
+	res, err := h.campaignFlow.RequestCampaignChanges(ctx, &req)
+	if err != nil {
+		if businessflow.IsCampaignNotFound(err) {
+			return h.ErrorResponse(c, fiber.StatusNotFound, "Campaign not found", "CAMPAIGN_NOT_FOUND", nil)
+		}
+		if businessflow.IsCampaignNotWaitingForApproval(err) {
+			return h.ErrorResponse(c, fiber.StatusConflict, "Invalid campaign state for review", "INVALID_STATE", nil)
+		}
+		if businessflow.IsCustomerNotFound(err) {
+			return h.ErrorResponse(c, fiber.StatusNotFound, "Customer not found", "CUSTOMER_NOT_FOUND", nil)
+		}
+		if businessflow.IsWalletNotFound(err) {
+			return h.ErrorResponse(c, fiber.StatusNotFound, "Wallet not found", "WALLET_NOT_FOUND", nil)
+		}
+		if businessflow.IsBalanceSnapshotNotFound(err) {
+			return h.ErrorResponse(c, fiber.StatusNotFound, "Balance snapshot not found", "BALANCE_SNAPSHOT_NOT_FOUND", nil)
+		}
+		if businessflow.IsFreezeTransactionNotFound(err) {
+			return h.ErrorResponse(c, fiber.StatusConflict, "Freeze transaction not found", "FREEZE_TRANSACTION_NOT_FOUND", nil)
+		}
+		if businessflow.IsMultipleFreezeTransactionsFound(err) {
+			return h.ErrorResponse(c, fiber.StatusConflict, "Multiple freeze transactions found", "MULTIPLE_FREEZE_TRANSACTIONS_FOUND", nil)
+		}
+		if businessflow.IsInsufficientFunds(err) {
+			return h.ErrorResponse(c, fiber.StatusConflict, "Insufficient funds", "INSUFFICIENT_FUNDS", nil)
+		}
+		log.Println("Admin request campaign changes failed", err)
+		return h.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to request campaign changes", "ADMIN_REQUEST_CAMPAIGN_CHANGES_FAILED", nil)
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, "Campaign changes requested successfully", res)
+}
+
+// ListCampaignReviews returns the review history of a campaign
+// @Summary Admin List Campaign Reviews
+// @Description Return the review history of a campaign, oldest first: reviewer decisions, requested changes, resubmissions and comments
+// @Tags Admin Campaigns
+// @Produce json
+// @Param id path string true "Campaign ID"
+// @Success 200 {object} dto.APIResponse{data=dto.ListCampaignReviewsResponse}
+// @Failure 400 {object} dto.APIResponse "Invalid campaign ID"
+// @Failure 404 {object} dto.APIResponse "Campaign not found"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security AdminBearer
+// @Router /api/v1/admin/campaigns/{id}/reviews [get]
+func (h *CampaignAdminHandler) ListCampaignReviews(c fiber.Ctx) error {
+	id := c.Params("id")
+	idUint, err := strconv.ParseUint(id, 10, 64)
+	if err != nil || idUint == 0 {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid campaign ID", "INVALID_CAMPAIGN_ID", nil)
+	}
+
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/admin/campaigns/"+id+"/reviews", 30*time.Second)
+	defer cancel()
+	res, err := h.campaignFlow.ListCampaignReviews(ctx, uint(idUint))
+	if err != nil {
+		if businessflow.IsCampaignNotFound(err) {
+			return h.ErrorResponse(c, fiber.StatusNotFound, "Campaign not found", "CAMPAIGN_NOT_FOUND", nil)
+		}
+		log.Println("Admin list campaign reviews failed", err)
+		return h.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to list campaign reviews", "ADMIN_LIST_CAMPAIGN_REVIEWS_FAILED", nil)
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, "Campaign reviews retrieved successfully", res)
+}
+
+// AddCampaignReviewComment adds a reviewer comment to a campaign under review
+// @Summary Admin Add Campaign Review Comment
+// @Description Comment on a campaign that is waiting for approval or has changes requested
+// @Tags Admin Campaigns
+// @Accept json
+// @Produce json
+// @Param id path string true "Campaign ID"
+// @Param request body dto.AdminAddCampaignReviewCommentRequest true "Comment payload"
+// @Success 201 {object} dto.APIResponse{data=dto.AddCampaignReviewCommentResponse}
+// @Failure 400 {object} dto.APIResponse "Validation error"
+// @Failure 404 {object} dto.APIResponse "Campaign not found"
+// @Failure 409 {object} dto.APIResponse "Campaign is not under review"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security AdminBearer
+// @Router /api/v1/admin/campaigns/{id}/reviews [post]
+func (h *CampaignAdminHandler) AddCampaignReviewComment(c fiber.Ctx) error {
+	id := c.Params("id")
+	idUint, err := strconv.ParseUint(id, 10, 64)
+	if err != nil || idUint == 0 {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid campaign ID", "INVALID_CAMPAIGN_ID", nil)
+	}
+
+	var req dto.AdminAddCampaignReviewCommentRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body", "INVALID_REQUEST", err.Error())
+	}
+	req.CampaignID = uint(idUint)
+	if err := h.validator.Struct(&req); err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", nil)
+	}
+
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/admin/campaigns/"+id+"/reviews", 30*time.Second)
+	defer cancel()
+	res, err := h.campaignFlow.AddCampaignReviewComment(ctx, &req)
+	if err != nil {
+		if businessflow.IsCampaignNotFound(err) {
+			return h.ErrorResponse(c, fiber.StatusNotFound, "Campaign not found", "CAMPAIGN_NOT_FOUND", nil)
+		}
+		if businessflow.IsCampaignNotUnderReview(err) {
+			return h.ErrorResponse(c, fiber.StatusConflict, "Campaign is not under review", "CAMPAIGN_NOT_UNDER_REVIEW", nil)
+		}
+		log.Println("Admin add campaign review comment failed", err)
+		return h.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to add review comment", "ADMIN_ADD_CAMPAIGN_REVIEW_COMMENT_FAILED", nil)
+	}
+	return h.SuccessResponse(c, fiber.StatusCreated, "Review comment added successfully", res)
+}
+
 // RescheduleCampaign updates the scheduled time for a campaign (admin-only).
 // @Summary Reschedule Campaign
 // @Description Admin reschedules an eligible campaign; schedule_at must be UTC and its Tehran-local time must be between 08:00 and 21:00. A waiting-for-approval campaign whose deadline was missed may still be rescheduled.
@@ -321,6 +478,7 @@ func (h *CampaignAdminHandler) RejectCampaign(c fiber.Ctx) error {
 // @Failure 404 {object} dto.APIResponse "Campaign not found"
 // @Failure 409 {object} dto.APIResponse "Invalid state"
 // @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security AdminBearer
 // @Router /api/v1/admin/campaigns/reschedule [post]
 func (h *CampaignAdminHandler) RescheduleCampaign(c fiber.Ctx) error {
 	var req dto.AdminRescheduleCampaignRequest
@@ -369,7 +527,7 @@ func (h *CampaignAdminHandler) RescheduleCampaign(c fiber.Ctx) error {
 
 // CancelCampaign cancels an approved campaign or a waiting-for-approval campaign that missed its deadline.
 // @Summary Cancel Campaign
-// @Description Cancel an approved campaign by admin and refund consumed budget to customer. Approved campaigns still require at least 2 minutes before schedule_at; a waiting-for-approval campaign that already missed schedule_at may also be cancelled.
+// @Description Cancel an approved campaign by admin and refund consumed budget to customer. Approved campaigns still require at least 2 minutes before schedule_at; a waiting-for-approval campaign that already missed schedule_at may also be cancelled. A running or paused campaign stops before its next send batch and only the unsent share of the budget is refunded.
 // @Tags Admin Campaigns
 // @Accept json
 // @Produce json
@@ -379,6 +537,7 @@ func (h *CampaignAdminHandler) RescheduleCampaign(c fiber.Ctx) error {
 // @Failure 404 {object} dto.APIResponse "Campaign not found"
 // @Failure 409 {object} dto.APIResponse "Invalid state"
 // @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security AdminBearer
 // @Router /api/v1/admin/campaigns/cancel [post]
 func (h *CampaignAdminHandler) CancelCampaign(c fiber.Ctx) error {
 	var req dto.AdminCancelCampaignRequest
@@ -438,6 +597,7 @@ func (h *CampaignAdminHandler) CancelCampaign(c fiber.Ctx) error {
 // @Success 200 {object} dto.APIResponse{data=dto.AdminRemoveAudienceSpecResponse}
 // @Failure 400 {object} dto.APIResponse "Validation error"
 // @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security AdminBearer
 // @Router /api/v1/admin/campaigns/audience-spec [delete]
 func (h *CampaignAdminHandler) RemoveAudienceSpec(c fiber.Ctx) error {
 	var platform *string
@@ -479,6 +639,7 @@ func (h *CampaignAdminHandler) RemoveAudienceSpec(c fiber.Ctx) error {
 // @Success 200 {object} dto.APIResponse{data=dto.AdminUpdatePagePriceResponse}
 // @Failure 400 {object} dto.APIResponse "Validation error"
 // @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security AdminBearer
 // @Router /api/v1/admin/campaigns/page-prices [put]
 func (h *CampaignAdminHandler) UpdatePagePrice(c fiber.Ctx) error {
 	var req dto.AdminUpdatePagePriceRequest
@@ -513,6 +674,7 @@ func (h *CampaignAdminHandler) UpdatePagePrice(c fiber.Ctx) error {
 // @Produce json
 // @Success 200 {object} dto.APIResponse{data=dto.AdminGetPagePricesResponse}
 // @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security AdminBearer
 // @Router /api/v1/admin/campaigns/page-prices [get]
 func (h *CampaignAdminHandler) GetPagePrices(c fiber.Ctx) error {
 	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/admin/campaigns/page-prices", 30*time.Second)
@@ -7,6 +7,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/amirphl/Yamata-no-Orochi/app/apierror"
 	"github.com/amirphl/Yamata-no-Orochi/app/dto"
 	businessflow "github.com/amirphl/Yamata-no-Orochi/business_flow"
 	"github.com/amirphl/Yamata-no-Orochi/utils"
@@ -41,14 +42,7 @@ func NewCampaignBotHandler(flow businessflow.BotCampaignFlow) CampaignBotHandler
 }
 
 func (h *CampaignBotHandler) ErrorResponse(c fiber.Ctx, statusCode int, message, errorCode string, details any) error {
-	return c.Status(statusCode).JSON(dto.APIResponse{
-		Success: false,
-		Message: message,
-		Error: dto.ErrorDetail{
-			Code:    errorCode,
-			Details: details,
-		},
-	})
+	return apierror.Respond(c, statusCode, message, errorCode, details)
 }
 
 func (h *CampaignBotHandler) SuccessResponse(c fiber.Ctx, statusCode int, message string, data any) error {
@@ -67,6 +61,7 @@ func (h *CampaignBotHandler) SuccessResponse(c fiber.Ctx, statusCode int, messag
 // @Param request body dto.BotUpdateAudienceSpecRequest true "Audience spec update"
 // @Success 201 {object} dto.APIResponse{data=dto.BotUpdateAudienceSpecResponse}
 // @Failure 400 {object} dto.APIResponse
+// @Security BotBearer
 // @Router /api/v1/bot/campaigns/audience-spec [post]
 func (h *CampaignBotHandler) UpdateAudienceSpec(c fiber.Ctx) error {
 	var req dto.BotUpdateAudienceSpecRequest
@@ -76,7 +71,7 @@ func (h *CampaignBotHandler) UpdateAudienceSpec(c fiber.Ctx) error {
 	if err := h.validator.Struct(&req); err != nil {
 		var validationErrors []string
 		for _, err := range err.(validator.ValidationErrors) {
-			validationErrors = append(validationErrors, getValidationErrorMessage(err))
+			validationErrors = append(validationErrors, getValidationErrorMessage(c, err))
 		}
 		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", validationErrors)
 	}
@@ -111,6 +106,7 @@ func (h *CampaignBotHandler) UpdateAudienceSpec(c fiber.Ctx) error {
 // @Param request body dto.BotResetAudienceSpecRequest true "Audience spec reset"
 // @Success 200 {object} dto.APIResponse{data=dto.BotResetAudienceSpecResponse}
 // @Failure 400 {object} dto.APIResponse
+// @Security BotBearer
 // @Router /api/v1/bot/campaigns/audience-spec/reset [post]
 func (h *CampaignBotHandler) ResetAudienceSpec(c fiber.Ctx) error {
 	var req dto.BotResetAudienceSpecRequest
@@ -120,7 +116,7 @@ func (h *CampaignBotHandler) ResetAudienceSpec(c fiber.Ctx) error {
 	if err := h.validator.Struct(&req); err != nil {
 		var validationErrors []string
 		for _, err := range err.(validator.ValidationErrors) {
-			validationErrors = append(validationErrors, getValidationErrorMessage(err))
+			validationErrors = append(validationErrors, getValidationErrorMessage(c, err))
 		}
 		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", validationErrors)
 	}
@@ -153,6 +149,7 @@ func (h *CampaignBotHandler) ResetAudienceSpec(c fiber.Ctx) error {
 // @Produce json
 // @Param platform query string false "Filter by platform (sms|rubika|bale|splus)"
 // @Success 200 {object} dto.APIResponse{data=dto.BotListCampaignsResponse}
+// @Security BotBearer
 // @Router /api/v1/bot/campaigns/ready [get]
 func (h *CampaignBotHandler) ListReadyCampaigns(c fiber.Ctx) error {
 	var platformPtr *string
@@ -178,6 +175,7 @@ func (h *CampaignBotHandler) ListReadyCampaigns(c fiber.Ctx) error {
 // @Produce json
 // @Param id path int true "Campaign ID"
 // @Success 200 {object} dto.APIResponse
+// @Security BotBearer
 // @Router /api/v1/bot/campaigns/{id}/executed [post]
 func (h *CampaignBotHandler) MoveCampaignToExecuted(c fiber.Ctx) error {
 	idStr := c.Params("id")
@@ -208,6 +206,7 @@ func (h *CampaignBotHandler) MoveCampaignToExecuted(c fiber.Ctx) error {
 // @Produce json
 // @Param id path int true "Campaign ID"
 // @Success 200 {object} dto.APIResponse
+// @Security BotBearer
 // @Router /api/v1/bot/campaigns/{id}/running [post]
 func (h *CampaignBotHandler) MoveCampaignToRunning(c fiber.Ctx) error {
 	idStr := c.Params("id")
@@ -235,7 +234,6 @@ func (h *CampaignBotHandler) MoveCampaignToRunning(c fiber.Ctx) error {
 // DownloadTargetAudienceExcelFile downloads campaign target-audience Excel file for bots.
 // @Summary Bot Download Campaign Target Audience Excel File
 // @Tags Bot Campaigns
-// @Security BearerAuth
 // @Produce application/octet-stream
 // @Param id path int true "Campaign ID"
 // @Success 200 {string} string "Binary file"
@@ -243,6 +241,7 @@ func (h *CampaignBotHandler) MoveCampaignToRunning(c fiber.Ctx) error {
 // @Failure 401 {object} dto.APIResponse "Unauthorized"
 // @Failure 404 {object} dto.APIResponse "Not found"
 // @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security BotBearer
 // @Router /api/v1/bot/campaigns/{id}/target-audience-excel-file [get]
 func (h *CampaignBotHandler) DownloadTargetAudienceExcelFile(c fiber.Ctx) error {
 	idStr := c.Params("id")
@@ -289,6 +288,7 @@ func (h *CampaignBotHandler) DownloadTargetAudienceExcelFile(c fiber.Ctx) error
 // @Param request body dto.BotUpdateCampaignStatisticsRequest true "Statistics payload"
 // @Success 200 {object} dto.APIResponse{data=dto.BotUpdateCampaignStatisticsResponse}
 // @Failure 400 {object} dto.APIResponse
+// @Security BotBearer
 // @Router /api/v1/bot/campaigns/{id}/statistics [post]
 func (h *CampaignBotHandler) UpdateCampaignStatistics(c fiber.Ctx) error {
 	idStr := c.Params("id")
@@ -303,7 +303,7 @@ func (h *CampaignBotHandler) UpdateCampaignStatistics(c fiber.Ctx) error {
 	if err := h.validator.Struct(&req); err != nil {
 		var validationErrors []string
 		for _, err := range err.(validator.ValidationErrors) {
-			validationErrors = append(validationErrors, getValidationErrorMessage(err))
+			validationErrors = append(validationErrors, getValidationErrorMessage(c, err))
 		}
 		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", validationErrors)
 	}
@@ -337,6 +337,7 @@ func (h *CampaignBotHandler) UpdateCampaignStatistics(c fiber.Ctx) error {
 // @Param request body dto.BotPushAudienceUIDsRequest true "Audience UIDs batch"
 // @Success 200 {object} dto.APIResponse{data=dto.BotPushAudienceUIDsResponse}
 // @Failure 400 {object} dto.APIResponse
+// @Security BotBearer
 // @Router /api/v1/bot/campaigns/{id}/audience-uids [post]
 func (h *CampaignBotHandler) PushAudienceUIDs(c fiber.Ctx) error {
 	idStr := c.Params("id")
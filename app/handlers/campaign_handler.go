@@ -9,9 +9,12 @@ import (
 	"strings"
 	"time"
 
+	"github.com/amirphl/Yamata-no-Orochi/app/apierror"
 	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/app/middleware"
 	businessflow "github.com/amirphl/Yamata-no-Orochi/business_flow"
 	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/amirphl/Yamata-no-Orochi/utils/phonenumber"
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v3"
 	"github.com/google/uuid"
@@ -20,19 +23,30 @@ import (
 // CampaignHandlerInterface defines the contract for campaign handlers
 type CampaignHandlerInterface interface {
 	CreateCampaign(c fiber.Ctx) error
+	BulkCreateCampaigns(c fiber.Ctx) error
 	UpdateCampaign(c fiber.Ctx) error
 	CalculateCampaignCapacity(c fiber.Ctx) error
 	CalculateCampaignCost(c fiber.Ctx) error
 	CalculateCampaignCostV2(c fiber.Ctx) error
+	EstimateCampaign(c fiber.Ctx) error
 	ListCampaigns(c fiber.Ctx) error
 	GetLastInitiatedCampaign(c fiber.Ctx) error
 	GetPagePrices(c fiber.Ctx) error
 	ListAudienceSpec(c fiber.Ctx) error
 	GetApprovedRunningSummary(c fiber.Ctx) error
+	GetSendingQuotaUsage(c fiber.Ctx) error
 	CancelCampaign(c fiber.Ctx) error
+	PauseCampaign(c fiber.Ctx) error
+	ResumeCampaign(c fiber.Ctx) error
 	CloneCampaign(c fiber.Ctx) error
 	ExportCampaignReport(c fiber.Ctx) error
 	ExportCampaignClickReport(c fiber.Ctx) error
+	GetCampaignVariantStats(c fiber.Ctx) error
+	GetCampaignOperatorStats(c fiber.Ctx) error
+	GetCampaignRetryStats(c fiber.Ctx) error
+	ListCampaignReviews(c fiber.Ctx) error
+	AddCampaignReviewComment(c fiber.Ctx) error
+	ValidateCampaignContent(c fiber.Ctx) error
 	SendCampaignTestMessage(c fiber.Ctx) error
 	HideCampaigns(c fiber.Ctx) error
 	UnhideCampaigns(c fiber.Ctx) error
@@ -45,14 +59,7 @@ type CampaignHandler struct {
 }
 
 func (h *CampaignHandler) ErrorResponse(c fiber.Ctx, statusCode int, message, errorCode string, details any) error {
-	return c.Status(statusCode).JSON(dto.APIResponse{
-		Success: false,
-		Message: message,
-		Error: dto.ErrorDetail{
-			Code:    errorCode,
-			Details: details,
-		},
-	})
+	return apierror.Respond(c, statusCode, message, errorCode, details)
 }
 
 func (h *CampaignHandler) SuccessResponse(c fiber.Ctx, statusCode int, message string, data any) error {
@@ -83,10 +90,12 @@ func NewCampaignHandler(campaignFlow businessflow.CampaignFlow) *CampaignHandler
 // @Accept json
 // @Produce json
 // @Param request body dto.CreateCampaignRequest true "Campaign creation data"
+// @Param X-Acting-As-Customer header int false "Referred customer an agency acts for under their delegation grant"
 // @Success 201 {object} dto.APIResponse{data=dto.CreateCampaignResponse} "Campaign created successfully"
 // @Failure 400 {object} dto.APIResponse "Validation error or invalid request"
 // @Failure 401 {object} dto.APIResponse "Unauthorized - customer not found or inactive"
 // @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
 // @Router /api/v1/campaigns [post]
 func (h *CampaignHandler) CreateCampaign(c fiber.Ctx) error {
 	var req dto.CreateCampaignRequest
@@ -98,7 +107,7 @@ func (h *CampaignHandler) CreateCampaign(c fiber.Ctx) error {
 	if err := h.validator.Struct(&req); err != nil {
 		var validationErrors []string
 		for _, err := range err.(validator.ValidationErrors) {
-			validationErrors = append(validationErrors, getValidationErrorMessage(err))
+			validationErrors = append(validationErrors, getValidationErrorMessage(c, err))
 		}
 		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", validationErrors)
 	}
@@ -134,6 +143,64 @@ func (h *CampaignHandler) CreateCampaign(c fiber.Ctx) error {
 	})
 }
 
+// BulkCreateCampaigns creates up to 100 campaigns in one request
+// @Summary Bulk Create Campaigns
+// @Description Creates up to 100 campaigns, each validated like a single create. With atomic set, one invalid campaign rejects the batch; otherwise valid campaigns are created and invalid ones reported with error_code and error. The budgets of the created campaigns together may not exceed the free and credit balance plus the available credit line: an atomic batch fails with INSUFFICIENT_FUNDS, otherwise campaigns past that point fail individually. Responds 201 when every campaign was created, 207 when some were, and 400 BULK_CAMPAIGNS_REJECTED with the results as details when none was.
+// @Tags Campaigns
+// @Accept json
+// @Produce json
+// @Param request body dto.BulkCreateCampaignsRequest true "Campaigns to create"
+// @Param X-Acting-As-Customer header int false "Referred customer an agency acts for under their delegation grant"
+// @Success 201 {object} dto.APIResponse{data=dto.BulkCreateCampaignsResponse} "All campaigns created"
+// @Success 207 {object} dto.APIResponse{data=dto.BulkCreateCampaignsResponse} "Some campaigns created"
+// @Failure 400 {object} dto.APIResponse "Validation error, or no campaign created"
+// @Failure 401 {object} dto.APIResponse "Unauthorized - customer not found or inactive"
+// @Failure 409 {object} dto.APIResponse "Budgets of an atomic batch exceed the available balance"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
+// @Router /api/v1/campaigns/bulk [post]
+func (h *CampaignHandler) BulkCreateCampaigns(c fiber.Ctx) error {
+	var req dto.BulkCreateCampaignsRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body", "INVALID_REQUEST", err.Error())
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		var validationErrors []string
+		for _, err := range err.(validator.ValidationErrors) {
+			validationErrors = append(validationErrors, getValidationErrorMessage(c, err))
+		}
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", validationErrors)
+	}
+
+	metadata := businessflow.NewClientMetadata(c.IP(), c.Get("User-Agent"))
+
+	customerID, ok := c.Locals("customer_id").(uint)
+	if !ok {
+		return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer ID not found in context", "MISSING_CUSTOMER_ID", nil)
+	}
+	req.CustomerID = customerID
+
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/campaigns/bulk", 60*time.Second)
+	defer cancel()
+	result, err := h.campaignFlow.BulkCreateCampaigns(ctx, &req, metadata)
+	if err != nil {
+		log.Println("Bulk campaign creation failed", err)
+		if businessflow.IsBulkCampaignCountInvalid(err) {
+			return h.ErrorResponse(c, fiber.StatusBadRequest, "Bulk creation takes 1 to 100 campaigns", "BULK_CAMPAIGN_COUNT_INVALID", nil)
+		}
+		return h.handleCampaignFlowError(c, err, fiber.StatusInternalServerError, "Campaign creation failed", "CAMPAIGN_CREATION_FAILED")
+	}
+
+	switch {
+	case result.Created == 0:
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "No campaign was created", "BULK_CAMPAIGNS_REJECTED", result)
+	case result.Failed > 0:
+		return h.SuccessResponse(c, fiber.StatusMultiStatus, "Some campaigns were created", result)
+	}
+	return h.SuccessResponse(c, fiber.StatusCreated, "Campaigns created successfully", result)
+}
+
 // UpdateCampaign handles the campaign update process
 // @Summary Update Campaign
 // @Description Update an existing campaign with the specified parameters
@@ -142,12 +209,14 @@ func (h *CampaignHandler) CreateCampaign(c fiber.Ctx) error {
 // @Produce json
 // @Param uuid path string true "Campaign UUID"
 // @Param request body dto.UpdateCampaignRequest true "Campaign update data"
+// @Param X-Acting-As-Customer header int false "Referred customer an agency acts for under their delegation grant"
 // @Success 200 {object} dto.APIResponse{data=dto.UpdateCampaignResponse} "Campaign updated successfully"
 // @Failure 400 {object} dto.APIResponse "Validation error or invalid request"
 // @Failure 401 {object} dto.APIResponse "Unauthorized - customer not found or inactive"
 // @Failure 403 {object} dto.APIResponse "Forbidden - campaign access denied or update not allowed"
 // @Failure 404 {object} dto.APIResponse "Campaign not found"
 // @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
 // @Router /api/v1/campaigns/{uuid} [put]
 func (h *CampaignHandler) UpdateCampaign(c fiber.Ctx) error {
 	// Get campaign UUID from path parameter
@@ -175,7 +244,7 @@ func (h *CampaignHandler) UpdateCampaign(c fiber.Ctx) error {
 	if err := h.validator.Struct(&req); err != nil {
 		var validationErrors []string
 		for _, err := range err.(validator.ValidationErrors) {
-			validationErrors = append(validationErrors, getValidationErrorMessage(err))
+			validationErrors = append(validationErrors, getValidationErrorMessage(c, err))
 		}
 		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", validationErrors)
 	}
@@ -200,17 +269,19 @@ func (h *CampaignHandler) UpdateCampaign(c fiber.Ctx) error {
 
 // CancelCampaign handles customer-initiated campaign cancellation
 // @Summary Cancel Campaign
-// @Description Cancel a campaign that is waiting for approval, or approved but not yet scheduled to start
+// @Description Cancel a campaign that is waiting for approval, or approved but not yet scheduled to start. A running or paused campaign stops before its next send batch and only the unsent share of the budget is refunded.
 // @Tags Campaigns
 // @Produce json
 // @Param id path int true "Campaign ID"
 // @Param request body dto.CancelCampaignRequest false "Optional comment"
+// @Param X-Acting-As-Customer header int false "Referred customer an agency acts for under their delegation grant"
 // @Success 200 {object} dto.APIResponse{data=dto.CancelCampaignResponse} "Campaign cancelled successfully"
 // @Failure 400 {object} dto.APIResponse "Validation error or invalid request"
 // @Failure 401 {object} dto.APIResponse "Unauthorized"
 // @Failure 403 {object} dto.APIResponse "Forbidden - campaign access denied or status not cancellable"
 // @Failure 404 {object} dto.APIResponse "Campaign not found"
 // @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
 // @Router /api/v1/campaigns/{id}/cancel [post]
 func (h *CampaignHandler) CancelCampaign(c fiber.Ctx) error {
 	idStr := c.Params("id")
@@ -234,7 +305,7 @@ func (h *CampaignHandler) CancelCampaign(c fiber.Ctx) error {
 	if err := h.validator.Struct(&req); err != nil {
 		var validationErrors []string
 		for _, err := range err.(validator.ValidationErrors) {
-			validationErrors = append(validationErrors, getValidationErrorMessage(err))
+			validationErrors = append(validationErrors, getValidationErrorMessage(c, err))
 		}
 		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", validationErrors)
 	}
@@ -251,18 +322,118 @@ func (h *CampaignHandler) CancelCampaign(c fiber.Ctx) error {
 	return h.SuccessResponse(c, fiber.StatusOK, "Campaign cancelled successfully", result)
 }
 
+// PauseCampaign handles pausing a running campaign
+// @Summary Pause Campaign
+// @Description Pause a running campaign. Sending stops before the next batch until the campaign is resumed or cancelled.
+// @Tags Campaigns
+// @Produce json
+// @Param id path int true "Campaign ID"
+// @Param X-Acting-As-Customer header int false "Referred customer an agency acts for under their delegation grant"
+// @Success 200 {object} dto.APIResponse{data=dto.PauseCampaignResponse} "Campaign paused successfully"
+// @Failure 400 {object} dto.APIResponse "Validation error or invalid request"
+// @Failure 401 {object} dto.APIResponse "Unauthorized"
+// @Failure 403 {object} dto.APIResponse "Forbidden - campaign access denied"
+// @Failure 404 {object} dto.APIResponse "Campaign not found"
+// @Failure 409 {object} dto.APIResponse "Campaign is not running"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
+// @Router /api/v1/campaigns/{id}/pause [post]
+func (h *CampaignHandler) PauseCampaign(c fiber.Ctx) error {
+	idStr := c.Params("id")
+	if idStr == "" {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Campaign ID is required", "MISSING_CAMPAIGN_ID", nil)
+	}
+	id64, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid campaign ID", "INVALID_CAMPAIGN_ID", nil)
+	}
+
+	customerID, ok := c.Locals("customer_id").(uint)
+	if !ok {
+		return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer ID not found in context", "MISSING_CUSTOMER_ID", nil)
+	}
+
+	req := dto.PauseCampaignRequest{
+		CampaignID: uint(id64),
+		CustomerID: customerID,
+	}
+
+	metadata := businessflow.NewClientMetadata(c.IP(), c.Get("User-Agent"))
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/campaigns/"+idStr+"/pause", 30*time.Second)
+	defer cancel()
+	result, err := h.campaignFlow.PauseCampaign(ctx, &req, metadata)
+	if err != nil {
+		log.Println("Pause campaign failed", err)
+		return h.handleCampaignFlowError(c, err, fiber.StatusInternalServerError, "Pause campaign failed", "PAUSE_CAMPAIGN_FAILED")
+	}
+
+	return h.SuccessResponse(c, fiber.StatusOK, "Campaign paused successfully", result)
+}
+
+// ResumeCampaign handles resuming a paused campaign
+// @Summary Resume Campaign
+// @Description Resume a paused campaign from the next unsent batch
+// @Tags Campaigns
+// @Produce json
+// @Param id path int true "Campaign ID"
+// @Param X-Acting-As-Customer header int false "Referred customer an agency acts for under their delegation grant"
+// @Success 200 {object} dto.APIResponse{data=dto.ResumeCampaignResponse} "Campaign resumed successfully"
+// @Failure 400 {object} dto.APIResponse "Validation error or invalid request"
+// @Failure 401 {object} dto.APIResponse "Unauthorized"
+// @Failure 403 {object} dto.APIResponse "Forbidden - campaign access denied"
+// @Failure 404 {object} dto.APIResponse "Campaign not found"
+// @Failure 409 {object} dto.APIResponse "Campaign is not paused"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
+// @Router /api/v1/campaigns/{id}/resume [post]
+func (h *CampaignHandler) ResumeCampaign(c fiber.Ctx) error {
+	idStr := c.Params("id")
+	if idStr == "" {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Campaign ID is required", "MISSING_CAMPAIGN_ID", nil)
+	}
+	id64, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid campaign ID", "INVALID_CAMPAIGN_ID", nil)
+	}
+
+	customerID, ok := c.Locals("customer_id").(uint)
+	if !ok {
+		return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer ID not found in context", "MISSING_CUSTOMER_ID", nil)
+	}
+
+	req := dto.ResumeCampaignRequest{
+		CampaignID: uint(id64),
+		CustomerID: customerID,
+	}
+
+	metadata := businessflow.NewClientMetadata(c.IP(), c.Get("User-Agent"))
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/campaigns/"+idStr+"/resume", 30*time.Second)
+	defer cancel()
+	result, err := h.campaignFlow.ResumeCampaign(ctx, &req, metadata)
+	if err != nil {
+		log.Println("Resume campaign failed", err)
+		return h.handleCampaignFlowError(c, err, fiber.StatusInternalServerError, "Resume campaign failed", "RESUME_CAMPAIGN_FAILED")
+	}
+
+	return h.SuccessResponse(c, fiber.StatusOK, "Campaign resumed successfully", result)
+}
+
 // CloneCampaign clones an existing campaign for the authenticated customer.
 // @Summary Clone Campaign
-// @Description Clone an existing campaign belonging to the current customer.
+// @Description Clone an existing campaign belonging to the current customer into a new initiated draft. Content, targeting, line number and budget are copied; the schedule is reset. The request body is optional.
 // @Tags Campaigns
+// @Accept json
 // @Produce json
 // @Param uuid path string true "Campaign UUID to clone"
+// @Param request body dto.CloneCampaignRequest false "Optional overrides for the clone"
+// @Param X-Acting-As-Customer header int false "Referred customer an agency acts for under their delegation grant"
 // @Success 201 {object} dto.APIResponse{data=dto.CloneCampaignResponse} "Campaign cloned successfully"
 // @Failure 401 {object} dto.APIResponse "Unauthorized"
 // @Failure 403 {object} dto.APIResponse "Forbidden - access denied"
 // @Failure 404 {object} dto.APIResponse "Campaign not found"
 // @Failure 409 {object} dto.APIResponse "Clone not allowed"
 // @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
 // @Router /api/v1/campaigns/{uuid}/clone [post]
 func (h *CampaignHandler) CloneCampaign(c fiber.Ctx) error {
 	campaignUUID := c.Params("uuid")
@@ -275,9 +446,20 @@ func (h *CampaignHandler) CloneCampaign(c fiber.Ctx) error {
 		return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer ID not found in context", "MISSING_CUSTOMER_ID", nil)
 	}
 
-	req := dto.CloneCampaignRequest{
-		UUID:       campaignUUID,
-		CustomerID: customerID,
+	var req dto.CloneCampaignRequest
+	if len(c.Body()) > 0 {
+		if err := c.Bind().JSON(&req); err != nil {
+			return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body", "INVALID_REQUEST", err.Error())
+		}
+	}
+	req.UUID = campaignUUID
+	req.CustomerID = customerID
+	if err := h.validator.Struct(&req); err != nil {
+		var validationErrors []string
+		for _, err := range err.(validator.ValidationErrors) {
+			validationErrors = append(validationErrors, getValidationErrorMessage(c, err))
+		}
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", validationErrors)
 	}
 
 	metadata := businessflow.NewClientMetadata(c.IP(), c.Get("User-Agent"))
@@ -294,6 +476,12 @@ func (h *CampaignHandler) CloneCampaign(c fiber.Ctx) error {
 		if businessflow.IsCampaignUpdateNotAllowed(err) {
 			return h.ErrorResponse(c, fiber.StatusConflict, "Clone not allowed", "CLONE_NOT_ALLOWED", nil)
 		}
+		if businessflow.IsAgencyDelegationDenied(err) {
+			return h.ErrorResponse(c, fiber.StatusForbidden, "Agency is not allowed to act for this customer", "AGENCY_DELEGATION_DENIED", nil)
+		}
+		if businessflow.IsCustomerSuspended(err) {
+			return customerSuspended(c, err)
+		}
 		log.Println("Clone campaign failed", err)
 		return h.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to clone campaign", "CAMPAIGN_CLONE_FAILED", nil)
 	}
@@ -313,6 +501,7 @@ func (h *CampaignHandler) CloneCampaign(c fiber.Ctx) error {
 // @Failure 403 {object} dto.APIResponse "Forbidden"
 // @Failure 404 {object} dto.APIResponse "Campaign not found"
 // @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
 // @Router /api/v1/campaigns/{uuid}/export [get]
 func (h *CampaignHandler) ExportCampaignReport(c fiber.Ctx) error {
 	campaignUUID := strings.TrimSpace(c.Params("uuid"))
@@ -350,6 +539,7 @@ func (h *CampaignHandler) ExportCampaignReport(c fiber.Ctx) error {
 // @Failure 401 {object} dto.APIResponse "Unauthorized"
 // @Failure 404 {object} dto.APIResponse "Campaign not found"
 // @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
 // @Router /api/v1/campaigns/{uuid}/click-report [get]
 func (h *CampaignHandler) ExportCampaignClickReport(c fiber.Ctx) error {
 	campaignUUID := strings.TrimSpace(c.Params("uuid"))
@@ -389,6 +579,249 @@ func (h *CampaignHandler) ExportCampaignClickReport(c fiber.Ctx) error {
 	return c.Send(data)
 }
 
+// GetCampaignVariantStats returns per-variant delivery and click statistics
+// @Summary Get Campaign Variant Statistics
+// @Description Report sent, delivered and clicked recipients with delivery and click rates for each A/B content variant of an SMS campaign
+// @Tags Campaigns
+// @Produce json
+// @Param uuid path string true "Campaign UUID"
+// @Success 200 {object} dto.APIResponse{data=dto.GetCampaignVariantStatsResponse} "Campaign variant statistics retrieved successfully"
+// @Failure 400 {object} dto.APIResponse "Validation error"
+// @Failure 401 {object} dto.APIResponse "Unauthorized"
+// @Failure 403 {object} dto.APIResponse "Forbidden - campaign access denied"
+// @Failure 404 {object} dto.APIResponse "Campaign not found or has no variants"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
+// @Router /api/v1/campaigns/{uuid}/variant-stats [get]
+func (h *CampaignHandler) GetCampaignVariantStats(c fiber.Ctx) error {
+	campaignUUID := strings.TrimSpace(c.Params("uuid"))
+	if campaignUUID == "" {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Campaign UUID is required", "MISSING_CAMPAIGN_UUID", nil)
+	}
+	parsed, err := uuid.Parse(campaignUUID)
+	if err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Campaign UUID is invalid", "INVALID_CAMPAIGN_UUID", nil)
+	}
+	campaignUUID = parsed.String()
+
+	customerID, ok := c.Locals("customer_id").(uint)
+	if !ok {
+		return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer ID not found in context", "MISSING_CUSTOMER_ID", nil)
+	}
+
+	req := dto.GetCampaignVariantStatsRequest{
+		UUID:       campaignUUID,
+		CustomerID: customerID,
+	}
+
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/campaigns/"+campaignUUID+"/variant-stats", 30*time.Second)
+	defer cancel()
+	result, err := h.campaignFlow.GetCampaignVariantStats(ctx, &req)
+	if err != nil {
+		log.Println("Get campaign variant stats failed", err)
+		return h.handleCampaignFlowError(c, err, fiber.StatusInternalServerError, "Failed to get campaign variant statistics", "CAMPAIGN_VARIANT_STATS_FAILED")
+	}
+
+	return h.SuccessResponse(c, fiber.StatusOK, "Campaign variant statistics retrieved successfully", result)
+}
+
+// GetCampaignOperatorStats returns per-operator delivery and click statistics
+// @Summary Get Campaign Operator Statistics
+// @Description Report sent, delivered and clicked recipients with delivery and click rates for each recipient mobile operator (mci, mtn, rightel, unknown) of an SMS campaign
+// @Tags Campaigns
+// @Produce json
+// @Param uuid path string true "Campaign UUID"
+// @Success 200 {object} dto.APIResponse{data=dto.GetCampaignOperatorStatsResponse} "Campaign operator statistics retrieved successfully"
+// @Failure 400 {object} dto.APIResponse "Validation error"
+// @Failure 401 {object} dto.APIResponse "Unauthorized"
+// @Failure 403 {object} dto.APIResponse "Forbidden - campaign access denied"
+// @Failure 404 {object} dto.APIResponse "Campaign not found"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
+// @Router /api/v1/campaigns/{uuid}/operator-stats [get]
+func (h *CampaignHandler) GetCampaignOperatorStats(c fiber.Ctx) error {
+	campaignUUID := strings.TrimSpace(c.Params("uuid"))
+	if campaignUUID == "" {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Campaign UUID is required", "MISSING_CAMPAIGN_UUID", nil)
+	}
+	parsed, err := uuid.Parse(campaignUUID)
+	if err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Campaign UUID is invalid", "INVALID_CAMPAIGN_UUID", nil)
+	}
+	campaignUUID = parsed.String()
+
+	customerID, ok := c.Locals("customer_id").(uint)
+	if !ok {
+		return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer ID not found in context", "MISSING_CUSTOMER_ID", nil)
+	}
+
+	req := dto.GetCampaignOperatorStatsRequest{
+		UUID:       campaignUUID,
+		CustomerID: customerID,
+	}
+
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/campaigns/"+campaignUUID+"/operator-stats", 30*time.Second)
+	defer cancel()
+	result, err := h.campaignFlow.GetCampaignOperatorStats(ctx, &req)
+	if err != nil {
+		log.Println("Get campaign operator stats failed", err)
+		return h.handleCampaignFlowError(c, err, fiber.StatusInternalServerError, "Failed to get campaign operator statistics", "CAMPAIGN_OPERATOR_STATS_FAILED")
+	}
+
+	return h.SuccessResponse(c, fiber.StatusOK, "Campaign operator statistics retrieved successfully", result)
+}
+
+// GetCampaignRetryStats returns the recipient retry outcomes of a campaign
+// @Summary Get Campaign Retry Statistics
+// @Description Report the SMS recipients resent after a transient provider error by outcome (pending, recovered, exhausted, permanent), the resend attempts made, and the messages rejected with a permanent error code
+// @Tags Campaigns
+// @Produce json
+// @Param uuid path string true "Campaign UUID"
+// @Success 200 {object} dto.APIResponse{data=dto.GetCampaignRetryStatsResponse} "Campaign retry statistics retrieved successfully"
+// @Failure 400 {object} dto.APIResponse "Validation error"
+// @Failure 401 {object} dto.APIResponse "Unauthorized"
+// @Failure 403 {object} dto.APIResponse "Forbidden - campaign access denied"
+// @Failure 404 {object} dto.APIResponse "Campaign not found"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
+// @Router /api/v1/campaigns/{uuid}/retry-stats [get]
+func (h *CampaignHandler) GetCampaignRetryStats(c fiber.Ctx) error {
+	campaignUUID := strings.TrimSpace(c.Params("uuid"))
+	if campaignUUID == "" {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Campaign UUID is required", "MISSING_CAMPAIGN_UUID", nil)
+	}
+	parsed, err := uuid.Parse(campaignUUID)
+	if err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Campaign UUID is invalid", "INVALID_CAMPAIGN_UUID", nil)
+	}
+	campaignUUID = parsed.String()
+
+	customerID, ok := c.Locals("customer_id").(uint)
+	if !ok {
+		return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer ID not found in context", "MISSING_CUSTOMER_ID", nil)
+	}
+
+	req := dto.GetCampaignRetryStatsRequest{
+		UUID:       campaignUUID,
+		CustomerID: customerID,
+	}
+
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/campaigns/"+campaignUUID+"/retry-stats", 30*time.Second)
+	defer cancel()
+	result, err := h.campaignFlow.GetCampaignRetryStats(ctx, &req)
+	if err != nil {
+		log.Println("Get campaign retry stats failed", err)
+		return h.handleCampaignFlowError(c, err, fiber.StatusInternalServerError, "Failed to get campaign retry statistics", "CAMPAIGN_RETRY_STATS_FAILED")
+	}
+
+	return h.SuccessResponse(c, fiber.StatusOK, "Campaign retry statistics retrieved successfully", result)
+}
+
+// ListCampaignReviews returns the review history of a campaign
+// @Summary List Campaign Reviews
+// @Description Return the review history of a campaign, oldest first: reviewer decisions, requested changes, resubmissions and comments
+// @Tags Campaigns
+// @Produce json
+// @Param uuid path string true "Campaign UUID"
+// @Success 200 {object} dto.APIResponse{data=dto.ListCampaignReviewsResponse} "Campaign reviews retrieved successfully"
+// @Failure 400 {object} dto.APIResponse "Validation error"
+// @Failure 401 {object} dto.APIResponse "Unauthorized"
+// @Failure 403 {object} dto.APIResponse "Forbidden - campaign access denied"
+// @Failure 404 {object} dto.APIResponse "Campaign not found"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
+// @Router /api/v1/campaigns/{uuid}/reviews [get]
+func (h *CampaignHandler) ListCampaignReviews(c fiber.Ctx) error {
+	campaignUUID := strings.TrimSpace(c.Params("uuid"))
+	if campaignUUID == "" {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Campaign UUID is required", "MISSING_CAMPAIGN_UUID", nil)
+	}
+	parsed, err := uuid.Parse(campaignUUID)
+	if err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Campaign UUID is invalid", "INVALID_CAMPAIGN_UUID", nil)
+	}
+	campaignUUID = parsed.String()
+
+	customerID, ok := c.Locals("customer_id").(uint)
+	if !ok {
+		return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer ID not found in context", "MISSING_CUSTOMER_ID", nil)
+	}
+
+	req := dto.ListCampaignReviewsRequest{
+		UUID:       campaignUUID,
+		CustomerID: customerID,
+	}
+
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/campaigns/"+campaignUUID+"/reviews", 30*time.Second)
+	defer cancel()
+	result, err := h.campaignFlow.ListCampaignReviews(ctx, &req)
+	if err != nil {
+		log.Println("List campaign reviews failed", err)
+		return h.handleCampaignFlowError(c, err, fiber.StatusInternalServerError, "Failed to list campaign reviews", "LIST_CAMPAIGN_REVIEWS_FAILED")
+	}
+
+	return h.SuccessResponse(c, fiber.StatusOK, "Campaign reviews retrieved successfully", result)
+}
+
+// AddCampaignReviewComment adds a customer comment to the review of a campaign
+// @Summary Add Campaign Review Comment
+// @Description Reply to reviewers while the campaign is waiting for approval or has changes requested
+// @Tags Campaigns
+// @Accept json
+// @Produce json
+// @Param uuid path string true "Campaign UUID"
+// @Param request body dto.AddCampaignReviewCommentRequest true "Comment payload"
+// @Success 201 {object} dto.APIResponse{data=dto.AddCampaignReviewCommentResponse} "Review comment added successfully"
+// @Failure 400 {object} dto.APIResponse "Validation error"
+// @Failure 401 {object} dto.APIResponse "Unauthorized"
+// @Failure 403 {object} dto.APIResponse "Forbidden - campaign access denied"
+// @Failure 404 {object} dto.APIResponse "Campaign not found"
+// @Failure 409 {object} dto.APIResponse "Campaign is not under review"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
+// @Router /api/v1/campaigns/{uuid}/reviews [post]
+func (h *CampaignHandler) AddCampaignReviewComment(c fiber.Ctx) error {
+	campaignUUID := strings.TrimSpace(c.Params("uuid"))
+	if campaignUUID == "" {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Campaign UUID is required", "MISSING_CAMPAIGN_UUID", nil)
+	}
+	parsed, err := uuid.Parse(campaignUUID)
+	if err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Campaign UUID is invalid", "INVALID_CAMPAIGN_UUID", nil)
+	}
+	campaignUUID = parsed.String()
+
+	customerID, ok := c.Locals("customer_id").(uint)
+	if !ok {
+		return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer ID not found in context", "MISSING_CUSTOMER_ID", nil)
+	}
+
+	var req dto.AddCampaignReviewCommentRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body", "INVALID_REQUEST", err.Error())
+	}
+	req.UUID = campaignUUID
+	req.CustomerID = customerID
+	if err := h.validator.Struct(&req); err != nil {
+		var validationErrors []string
+		for _, err := range err.(validator.ValidationErrors) {
+			validationErrors = append(validationErrors, getValidationErrorMessage(c, err))
+		}
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", validationErrors)
+	}
+
+	metadata := businessflow.NewClientMetadata(c.IP(), c.Get("User-Agent"))
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/campaigns/"+campaignUUID+"/reviews", 30*time.Second)
+	defer cancel()
+	result, err := h.campaignFlow.AddCampaignReviewComment(ctx, &req, metadata)
+	if err != nil {
+		log.Println("Add campaign review comment failed", err)
+		return h.handleCampaignFlowError(c, err, fiber.StatusInternalServerError, "Failed to add review comment", "ADD_CAMPAIGN_REVIEW_COMMENT_FAILED")
+	}
+
+	return h.SuccessResponse(c, fiber.StatusCreated, "Review comment added successfully", result)
+}
+
 // CalculateCampaignCapacity handles the campaign capacity calculation process
 // @Summary Calculate Campaign Capacity
 // @Description Calculate the potential reach and capacity of an campaign based on parameters
@@ -399,6 +832,7 @@ func (h *CampaignHandler) ExportCampaignClickReport(c fiber.Ctx) error {
 // @Success 200 {object} dto.APIResponse{data=dto.CalculateCampaignCapacityResponse} "Capacity calculated successfully"
 // @Failure 400 {object} dto.APIResponse "Validation error or invalid request"
 // @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
 // @Router /api/v1/campaigns/calculate-capacity [post]
 func (h *CampaignHandler) CalculateCampaignCapacity(c fiber.Ctx) error {
 	var req dto.CalculateCampaignCapacityRequest
@@ -410,7 +844,7 @@ func (h *CampaignHandler) CalculateCampaignCapacity(c fiber.Ctx) error {
 	if err := h.validator.Struct(&req); err != nil {
 		var validationErrors []string
 		for _, err := range err.(validator.ValidationErrors) {
-			validationErrors = append(validationErrors, getValidationErrorMessage(err))
+			validationErrors = append(validationErrors, getValidationErrorMessage(c, err))
 		}
 		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", validationErrors)
 	}
@@ -451,6 +885,7 @@ func (h *CampaignHandler) CalculateCampaignCapacity(c fiber.Ctx) error {
 // @Failure 400 {object} dto.APIResponse "Validation error or invalid request"
 // @Failure 401 {object} dto.APIResponse "Unauthorized - customer not found or inactive"
 // @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
 // @Router /api/v1/campaigns/calculate-cost [post]
 func (h *CampaignHandler) CalculateCampaignCost(c fiber.Ctx) error {
 	var req dto.CalculateCampaignCostRequest
@@ -462,7 +897,7 @@ func (h *CampaignHandler) CalculateCampaignCost(c fiber.Ctx) error {
 	if err := h.validator.Struct(&req); err != nil {
 		var validationErrors []string
 		for _, err := range err.(validator.ValidationErrors) {
-			validationErrors = append(validationErrors, getValidationErrorMessage(err))
+			validationErrors = append(validationErrors, getValidationErrorMessage(c, err))
 		}
 		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", validationErrors)
 	}
@@ -506,6 +941,7 @@ func (h *CampaignHandler) CalculateCampaignCost(c fiber.Ctx) error {
 // @Failure 400 {object} dto.APIResponse "Validation error or invalid request"
 // @Failure 401 {object} dto.APIResponse "Unauthorized - customer not found or inactive"
 // @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
 // @Router /api/v1/campaigns/calculate-cost-v2 [post]
 func (h *CampaignHandler) CalculateCampaignCostV2(c fiber.Ctx) error {
 	var req dto.CalculateCampaignCostV2Request
@@ -516,7 +952,7 @@ func (h *CampaignHandler) CalculateCampaignCostV2(c fiber.Ctx) error {
 	if err := h.validator.Struct(&req); err != nil {
 		var validationErrors []string
 		for _, err := range err.(validator.ValidationErrors) {
-			validationErrors = append(validationErrors, getValidationErrorMessage(err))
+			validationErrors = append(validationErrors, getValidationErrorMessage(c, err))
 		}
 		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", validationErrors)
 	}
@@ -545,6 +981,96 @@ func (h *CampaignHandler) CalculateCampaignCostV2(c fiber.Ctx) error {
 	})
 }
 
+// EstimateCampaign prices a prospective campaign without creating it
+// @Summary Estimate Campaign
+// @Description Estimate SMS parts, price per message, audience counts by color and min/max cost for the given tags, text and line number without creating a campaign
+// @Tags Campaigns
+// @Accept json
+// @Produce json
+// @Param request body dto.EstimateCampaignRequest true "Prospective campaign parameters"
+// @Success 200 {object} dto.APIResponse{data=dto.EstimateCampaignResponse} "Campaign estimated successfully"
+// @Failure 400 {object} dto.APIResponse "Validation error or invalid request"
+// @Failure 401 {object} dto.APIResponse "Unauthorized - customer not found or inactive"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
+// @Router /api/v1/campaigns/estimate [post]
+func (h *CampaignHandler) EstimateCampaign(c fiber.Ctx) error {
+	var req dto.EstimateCampaignRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body", "INVALID_REQUEST", err.Error())
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		var validationErrors []string
+		for _, err := range err.(validator.ValidationErrors) {
+			validationErrors = append(validationErrors, getValidationErrorMessage(c, err))
+		}
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", validationErrors)
+	}
+
+	metadata := businessflow.NewClientMetadata(c.IP(), c.Get("User-Agent"))
+
+	customerID, ok := c.Locals("customer_id").(uint)
+	if !ok {
+		return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer ID not found in context", "MISSING_CUSTOMER_ID", nil)
+	}
+	req.CustomerID = customerID
+
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/campaigns/estimate", 30*time.Second)
+	defer cancel()
+	result, err := h.campaignFlow.EstimateCampaign(ctx, &req, metadata)
+	if err != nil {
+		log.Println("Campaign estimate failed", err)
+		return h.handleCampaignFlowError(c, err, fiber.StatusInternalServerError, "Campaign estimate failed", "CAMPAIGN_ESTIMATE_FAILED")
+	}
+
+	return h.SuccessResponse(c, fiber.StatusOK, "Campaign estimated successfully", result)
+}
+
+// ValidateCampaignContent reports personalization variables in campaign content
+// @Summary Validate Campaign Content
+// @Description Report the personalization variables (e.g. {first_name}, {city|fallback}) used in SMS content and any unknown variables that would block finalization
+// @Tags Campaigns
+// @Accept json
+// @Produce json
+// @Param request body dto.ValidateCampaignContentRequest true "Campaign content"
+// @Success 200 {object} dto.APIResponse{data=dto.ValidateCampaignContentResponse} "Campaign content validated"
+// @Failure 400 {object} dto.APIResponse "Validation error or invalid request"
+// @Failure 401 {object} dto.APIResponse "Unauthorized - customer not found or inactive"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
+// @Router /api/v1/campaigns/validate-content [post]
+func (h *CampaignHandler) ValidateCampaignContent(c fiber.Ctx) error {
+	var req dto.ValidateCampaignContentRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body", "INVALID_REQUEST", err.Error())
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		var validationErrors []string
+		for _, err := range err.(validator.ValidationErrors) {
+			validationErrors = append(validationErrors, getValidationErrorMessage(c, err))
+		}
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", validationErrors)
+	}
+
+	customerID, ok := c.Locals("customer_id").(uint)
+	if !ok {
+		return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer ID not found in context", "MISSING_CUSTOMER_ID", nil)
+	}
+	req.CustomerID = customerID
+
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/campaigns/validate-content", 10*time.Second)
+	defer cancel()
+	result, err := h.campaignFlow.ValidateCampaignContent(ctx, &req)
+	if err != nil {
+		log.Println("Campaign content validation failed", err)
+		return h.handleCampaignFlowError(c, err, fiber.StatusInternalServerError, "Campaign content validation failed", "CAMPAIGN_CONTENT_VALIDATION_FAILED")
+	}
+
+	return h.SuccessResponse(c, fiber.StatusOK, "Campaign content validated", result)
+}
+
 // ListCampaigns returns user's campaigns with filters and pagination
 // @Summary List Campaigns
 // @Description Retrieve the authenticated user's campaigns with pagination, ordering, and filters
@@ -555,11 +1081,13 @@ func (h *CampaignHandler) CalculateCampaignCostV2(c fiber.Ctx) error {
 // @Param limit query int true "Items per page (max 100)"
 // @Param orderby query string false "Order by (newest|oldest)" default(newest)
 // @Param title query string false "Filter by title (contains)"
-// @Param status query string false "Filter by status (initiated|in-progress|waiting-for-approval|approved|rejected|running|executed|expired|cancelled|cancelled-by-admin)"
+// @Param status query string false "Filter by status (initiated|in-progress|waiting-for-approval|changes-requested|approved|rejected|running|paused|executed|expired|cancelled|cancelled-by-admin)"
+// @Param X-Acting-As-Customer header int false "Referred customer an agency acts for under their delegation grant"
 // @Success 200 {object} dto.APIResponse{data=dto.ListCampaignsResponse}
 // @Failure 400 {object} dto.APIResponse "Validation error"
 // @Failure 401 {object} dto.APIResponse "Unauthorized"
 // @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
 // @Router /api/v1/campaigns [get]
 func (h *CampaignHandler) ListCampaigns(c fiber.Ctx) error {
 	// Parse query params
@@ -678,6 +1206,7 @@ func (h *CampaignHandler) ListCampaigns(c fiber.Ctx) error {
 // @Success 200 {object} dto.APIResponse{data=dto.GetLastInitiatedCampaignResponse}
 // @Failure 401 {object} dto.APIResponse "Unauthorized"
 // @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
 // @Router /api/v1/campaigns/initiated/last [get]
 func (h *CampaignHandler) GetLastInitiatedCampaign(c fiber.Ctx) error {
 	customerID, ok := c.Locals("customer_id").(uint)
@@ -704,6 +1233,7 @@ func (h *CampaignHandler) GetLastInitiatedCampaign(c fiber.Ctx) error {
 // @Produce json
 // @Success 200 {object} dto.APIResponse{data=dto.GetPagePricesResponse}
 // @Failure 500 {object} dto.APIResponse
+// @Security CustomerBearer
 // @Router /api/v1/campaigns/page-prices [get]
 func (h *CampaignHandler) GetPagePrices(c fiber.Ctx) error {
 	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/campaigns/page-prices", 30*time.Second)
@@ -723,6 +1253,7 @@ func (h *CampaignHandler) GetPagePrices(c fiber.Ctx) error {
 // @Produce json
 // @Param platform query string false "Platform (default: sms)"
 // @Success 200 {object} dto.APIResponse{data=map[string]map[string]map[string]any}
+// @Security CustomerBearer
 // @Router /api/v1/campaigns/audience-spec [get]
 func (h *CampaignHandler) ListAudienceSpec(c fiber.Ctx) error {
 	metadata := businessflow.NewClientMetadata(c.IP(), c.Get("User-Agent"))
@@ -755,6 +1286,7 @@ func (h *CampaignHandler) ListAudienceSpec(c fiber.Ctx) error {
 // @Success 200 {object} dto.APIResponse{data=map[string]any}
 // @Failure 401 {object} dto.APIResponse "Unauthorized"
 // @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
 // @Router /api/v1/campaigns/summary [get]
 func (h *CampaignHandler) GetApprovedRunningSummary(c fiber.Ctx) error {
 	customerID, ok := c.Locals("customer_id").(uint)
@@ -777,6 +1309,33 @@ func (h *CampaignHandler) GetApprovedRunningSummary(c fiber.Ctx) error {
 	})
 }
 
+// GetSendingQuotaUsage returns the customer's sending quota and its consumption
+// @Summary Get Sending Quota Usage
+// @Description Get the daily and monthly recipient limits of the authenticated customer with the recipients already sent and reserved by campaigns awaiting approval or sending. Days and months follow the Tehran calendar; null limits mean unlimited.
+// @Tags Campaigns
+// @Produce json
+// @Success 200 {object} dto.APIResponse{data=dto.GetSendingQuotaUsageResponse}
+// @Failure 401 {object} dto.APIResponse "Unauthorized"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
+// @Router /api/v1/campaigns/sending-quota [get]
+func (h *CampaignHandler) GetSendingQuotaUsage(c fiber.Ctx) error {
+	customerID, ok := c.Locals("customer_id").(uint)
+	if !ok {
+		return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer ID not found in context", "MISSING_CUSTOMER_ID", nil)
+	}
+
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/campaigns/sending-quota", 30*time.Second)
+	defer cancel()
+	res, err := h.campaignFlow.GetSendingQuotaUsage(ctx, customerID)
+	if err != nil {
+		log.Println("Get sending quota usage failed", err)
+		return h.handleCampaignFlowError(c, err, fiber.StatusInternalServerError, "Failed to get sending quota", "GET_SENDING_QUOTA_FAILED")
+	}
+
+	return h.SuccessResponse(c, fiber.StatusOK, res.Message, res)
+}
+
 // SendCampaignTestMessage attempts best-effort delivery of a single test message to the requested target phone.
 // @Summary Send Campaign Test Message
 // @Description Send a best-effort test message for a campaign to a target phone number
@@ -793,6 +1352,7 @@ func (h *CampaignHandler) GetApprovedRunningSummary(c fiber.Ctx) error {
 // @Failure 409 {object} dto.APIResponse
 // @Failure 429 {object} dto.APIResponse
 // @Failure 500 {object} dto.APIResponse
+// @Security CustomerBearer
 // @Router /api/v1/campaigns/{uuid}/test-send [post]
 func (h *CampaignHandler) SendCampaignTestMessage(c fiber.Ctx) error {
 	var req dto.SendCampaignTestMessageRequest
@@ -802,7 +1362,7 @@ func (h *CampaignHandler) SendCampaignTestMessage(c fiber.Ctx) error {
 	if err := h.validator.Struct(&req); err != nil {
 		var validationErrors []string
 		for _, err := range err.(validator.ValidationErrors) {
-			validationErrors = append(validationErrors, getValidationErrorMessage(err))
+			validationErrors = append(validationErrors, getValidationErrorMessage(c, err))
 		}
 		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", validationErrors)
 	}
@@ -849,6 +1409,7 @@ func (h *CampaignHandler) SendCampaignTestMessage(c fiber.Ctx) error {
 // @Failure 401 {object} dto.APIResponse "Unauthorized"
 // @Failure 404 {object} dto.APIResponse "One or more campaigns not found"
 // @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
 // @Router /api/v1/campaigns/hide [post]
 func (h *CampaignHandler) HideCampaigns(c fiber.Ctx) error {
 	var req dto.HideCampaignsRequest
@@ -859,7 +1420,7 @@ func (h *CampaignHandler) HideCampaigns(c fiber.Ctx) error {
 	if err := h.validator.Struct(&req); err != nil {
 		var validationErrors []string
 		for _, err := range err.(validator.ValidationErrors) {
-			validationErrors = append(validationErrors, getValidationErrorMessage(err))
+			validationErrors = append(validationErrors, getValidationErrorMessage(c, err))
 		}
 		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", validationErrors)
 	}
@@ -898,6 +1459,7 @@ func (h *CampaignHandler) HideCampaigns(c fiber.Ctx) error {
 // @Failure 401 {object} dto.APIResponse "Unauthorized"
 // @Failure 404 {object} dto.APIResponse "One or more campaigns not found"
 // @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
 // @Router /api/v1/campaigns/unhide [post]
 func (h *CampaignHandler) UnhideCampaigns(c fiber.Ctx) error {
 	var req dto.UnhideCampaignsRequest
@@ -908,7 +1470,7 @@ func (h *CampaignHandler) UnhideCampaigns(c fiber.Ctx) error {
 	if err := h.validator.Struct(&req); err != nil {
 		var validationErrors []string
 		for _, err := range err.(validator.ValidationErrors) {
-			validationErrors = append(validationErrors, getValidationErrorMessage(err))
+			validationErrors = append(validationErrors, getValidationErrorMessage(c, err))
 		}
 		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", validationErrors)
 	}
@@ -950,6 +1512,8 @@ func (h *CampaignHandler) createRequestContextWithTimeout(c fiber.Ctx, endpoint
 	if customerID, ok := c.Locals("customer_id").(uint); ok && customerID != 0 {
 		ctx = context.WithValue(ctx, utils.CustomerIDKey, customerID)
 	}
+	ctx = middleware.WithImpersonation(ctx, c)
+	ctx = middleware.WithDelegation(ctx, c)
 
 	return ctx, cancel
 }
@@ -957,16 +1521,7 @@ func (h *CampaignHandler) createRequestContextWithTimeout(c fiber.Ctx, endpoint
 // setupCustomValidations sets up custom validation rules
 func (h *CampaignHandler) setupCustomValidations() {
 	h.validator.RegisterValidation("mobile_format", func(fl validator.FieldLevel) bool {
-		value := fl.Field().String()
-		if len(value) != 13 || value[:4] != "+989" {
-			return false
-		}
-		for _, char := range value[4:] {
-			if char < '0' || char > '9' {
-				return false
-			}
-		}
-		return true
+		return phonenumber.IsValid(fl.Field().String())
 	})
 }
 
@@ -989,18 +1544,52 @@ func (h *CampaignHandler) handleCampaignFlowError(c fiber.Ctx, err error, defaul
 	if businessflow.IsCampaignNotApproved(err) {
 		return h.ErrorResponse(c, fiber.StatusConflict, "Campaign is not approved", "CAMPAIGN_NOT_APPROVED", nil)
 	}
+	if businessflow.IsCampaignNotRunning(err) {
+		return h.ErrorResponse(c, fiber.StatusConflict, "Campaign is not running", "CAMPAIGN_NOT_RUNNING", nil)
+	}
+	if businessflow.IsCampaignNotPaused(err) {
+		return h.ErrorResponse(c, fiber.StatusConflict, "Campaign is not paused", "CAMPAIGN_NOT_PAUSED", nil)
+	}
+	if businessflow.IsCampaignNotUnderReview(err) {
+		return h.ErrorResponse(c, fiber.StatusConflict, "Campaign is not under review", "CAMPAIGN_NOT_UNDER_REVIEW", nil)
+	}
+	if businessflow.IsCampaignHasNoVariants(err) {
+		return h.ErrorResponse(c, fiber.StatusNotFound, "Campaign has no content variants", "CAMPAIGN_HAS_NO_VARIANTS", nil)
+	}
+	if businessflow.IsCampaignContentUnknownVariables(err) {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Campaign content uses unknown personalization variables", "CAMPAIGN_CONTENT_UNKNOWN_VARIABLES", nil)
+	}
+	if businessflow.IsCampaignVariantTooLong(err) {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "A content variant needs more SMS parts than the first variant", "CAMPAIGN_VARIANT_TOO_LONG", nil)
+	}
 	if businessflow.IsInsufficientCampaignCapacity(err) {
 		return h.ErrorResponse(c, fiber.StatusConflict, "Insufficient campaign capacity", "INSUFFICIENT_CAPACITY", nil)
 	}
 	if businessflow.IsInsufficientFunds(err) {
 		return h.ErrorResponse(c, fiber.StatusConflict, "Insufficient funds", "INSUFFICIENT_FUNDS", nil)
 	}
+	if businessflow.IsSendingQuotaExceeded(err) {
+		return h.ErrorResponse(c, fiber.StatusConflict, "Campaign audience exceeds the remaining sending quota", "SENDING_QUOTA_EXCEEDED", businessErrorMessage(err))
+	}
+	if businessflow.IsCustomerSuspended(err) {
+		return customerSuspended(c, err)
+	}
+	if businessflow.IsPostpaidInvoiceOverdue(err) {
+		return h.ErrorResponse(c, fiber.StatusForbidden, "Pay the overdue postpaid invoice before creating new campaigns", "POSTPAID_INVOICE_OVERDUE", nil)
+	}
+
+	if businessflow.IsAgencyDelegationDenied(err) {
+		return h.ErrorResponse(c, fiber.StatusForbidden, "Agency is not allowed to act for this customer", "AGENCY_DELEGATION_DENIED", nil)
+	}
+	if businessflow.IsAgencyInactive(err) {
+		return h.ErrorResponse(c, fiber.StatusForbidden, "Agency is inactive", "AGENCY_INACTIVE", nil)
+	}
 
 	if businessflow.IsCustomerNotFound(err) {
 		return h.ErrorResponse(c, fiber.StatusNotFound, "Customer not found", "CUSTOMER_NOT_FOUND", nil)
 	}
 	if businessflow.IsAccountInactive(err) {
-		return h.ErrorResponse(c, fiber.StatusUnauthorized, "Account is inactive", "ACCOUNT_INACTIVE", nil)
+		return h.ErrorResponse(c, fiber.StatusForbidden, "Account is inactive", "ACCOUNT_INACTIVE", nil)
 	}
 	if businessflow.IsAccountTypeNotFound(err) {
 		return h.ErrorResponse(c, fiber.StatusBadRequest, "Account type not found", "ACCOUNT_TYPE_NOT_FOUND", nil)
@@ -1034,7 +1623,7 @@ func (h *CampaignHandler) handleCampaignFlowError(c fiber.Ctx, err error, defaul
 		return h.ErrorResponse(c, fiber.StatusBadRequest, "Platform settings is only applicable for non-sms campaigns", "PLATFORM_SETTINGS_NOT_APPLICABLE", nil)
 	}
 	if businessflow.IsCampaignPlatformSettingNotFound(err) {
-		return h.ErrorResponse(c, fiber.StatusBadRequest, "Platform settings not found", "PLATFORM_SETTINGS_NOT_FOUND", nil)
+		return h.ErrorResponse(c, fiber.StatusNotFound, "Platform settings not found", "PLATFORM_SETTINGS_NOT_FOUND", nil)
 	}
 	if businessflow.IsCampaignTestPlatformSettingsInvalid(err) {
 		return h.ErrorResponse(c, fiber.StatusBadRequest, "Platform settings are invalid for test send", "PLATFORM_SETTINGS_INVALID", nil)
@@ -1058,11 +1647,14 @@ func (h *CampaignHandler) handleCampaignFlowError(c fiber.Ctx, err error, defaul
 		return h.ErrorResponse(c, fiber.StatusBadRequest, "Line number is required for sms campaigns", "LINE_NUMBER_REQUIRED", nil)
 	}
 	if businessflow.IsLineNumberNotFound(err) {
-		return h.ErrorResponse(c, fiber.StatusBadRequest, "Line number not found", "LINE_NUMBER_NOT_FOUND", nil)
+		return h.ErrorResponse(c, fiber.StatusNotFound, "Line number not found", "LINE_NUMBER_NOT_FOUND", nil)
 	}
 	if businessflow.IsLineNumberNotActive(err) || businessflow.IsCampaignLineNumberNotActive(err) {
 		return h.ErrorResponse(c, fiber.StatusBadRequest, "Line number is not active", "LINE_NUMBER_NOT_ACTIVE", nil)
 	}
+	if businessflow.IsLineNumberReserved(err) {
+		return h.ErrorResponse(c, fiber.StatusConflict, "Line number is reserved by another customer", "LINE_NUMBER_RESERVED", nil)
+	}
 
 	if businessflow.IsCampaignMediaNotFound(err) {
 		return h.ErrorResponse(c, fiber.StatusBadRequest, "Media not found", "MEDIA_NOT_FOUND", nil)
@@ -1091,6 +1683,13 @@ func (h *CampaignHandler) handleCampaignFlowError(c fiber.Ctx, err error, defaul
 		businessflow.IsCampaignAdLinkRequired(err) ||
 		businessflow.IsCampaignCityRequired(err) ||
 		businessflow.IsCampaignTagsRequired(err) ||
+		businessflow.IsCampaignTagInvalid(err) ||
+		businessflow.IsCampaignRecurrenceInvalid(err) ||
+		businessflow.IsCampaignRecurrenceTooFrequent(err) ||
+		businessflow.IsCampaignRecurrenceUntilInvalid(err) ||
+		businessflow.IsCampaignVariantsInvalid(err) ||
+		businessflow.IsCampaignVariantsPlatformUnsupported(err) ||
+		businessflow.IsCampaignPersonalizationUnsupported(err) ||
 		businessflow.IsCampaignUUIDRequired(err) ||
 		businessflow.IsCampaignUpdateRequired(err) ||
 		businessflow.IsInvalidShortLinkDomain(err) ||
@@ -1110,7 +1709,7 @@ func (h *CampaignHandler) handleCampaignFlowError(c fiber.Ctx, err error, defaul
 		return h.ErrorResponse(c, fiber.StatusConflict, "Campaign cannot be rescheduled in current status", "CAMPAIGN_RESCHEDULE_NOT_ALLOWED", nil)
 	}
 	if businessflow.IsPlatformBasePriceNotFound(err) {
-		return h.ErrorResponse(c, fiber.StatusBadRequest, "Platform base price not found", "PLATFORM_BASE_PRICE_NOT_FOUND", nil)
+		return h.ErrorResponse(c, fiber.StatusNotFound, "Platform base price not found", "PLATFORM_BASE_PRICE_NOT_FOUND", nil)
 	}
 	if businessflow.IsPagePriceNotFound(err) {
 		return h.ErrorResponse(c, fiber.StatusBadRequest, "Page price not found", "PAGE_PRICE_NOT_FOUND", nil)
@@ -0,0 +1,308 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/apierror"
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/app/middleware"
+	businessflow "github.com/amirphl/Yamata-no-Orochi/business_flow"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v3"
+)
+
+// CampaignTemplateHandlerInterface defines customer and admin endpoints for campaign templates
+type CampaignTemplateHandlerInterface interface {
+	CreateTemplate(c fiber.Ctx) error
+	ListTemplates(c fiber.Ctx) error
+	DeleteTemplate(c fiber.Ctx) error
+	CreateCampaignFromTemplate(c fiber.Ctx) error
+	AdminPublishTemplate(c fiber.Ctx) error
+	AdminListTemplates(c fiber.Ctx) error
+	AdminDeleteTemplate(c fiber.Ctx) error
+}
+
+// CampaignTemplateHandler handles campaign template HTTP requests
+type CampaignTemplateHandler struct {
+	flow      businessflow.CampaignTemplateFlow
+	validator *validator.Validate
+	// campaignErrors maps campaign creation errors the same way the campaign endpoints do
+	campaignErrors *CampaignHandler
+}
+
+func NewCampaignTemplateHandler(flow businessflow.CampaignTemplateFlow) CampaignTemplateHandlerInterface {
+	return &CampaignTemplateHandler{
+		flow:           flow,
+		validator:      validator.New(),
+		campaignErrors: &CampaignHandler{},
+	}
+}
+
+func (h *CampaignTemplateHandler) ErrorResponse(c fiber.Ctx, status int, message, code string, details any) error {
+	return apierror.Respond(c, status, message, code, details)
+}
+
+func (h *CampaignTemplateHandler) SuccessResponse(c fiber.Ctx, status int, message string, data any) error {
+	return c.Status(status).JSON(dto.APIResponse{Success: true, Message: message, Data: data})
+}
+
+// CreateTemplate saves message content and targeting as a named template
+// @Summary Create Campaign Template
+// @Description Save campaign content and targeting as a named template for later reuse
+// @Tags Campaign Templates
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateCampaignTemplateRequest true "Template payload"
+// @Success 201 {object} dto.APIResponse{data=dto.CampaignTemplateResponse}
+// @Failure 400 {object} dto.APIResponse "Validation error"
+// @Failure 401 {object} dto.APIResponse "Unauthorized"
+// @Failure 409 {object} dto.APIResponse "Template name already used"
+// @Failure 500 {object} dto.APIResponse "Creation failed"
+// @Security CustomerBearer
+// @Router /api/v1/campaign-templates [post]
+func (h *CampaignTemplateHandler) CreateTemplate(c fiber.Ctx) error {
+	var req dto.CreateCampaignTemplateRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body", "INVALID_REQUEST", err.Error())
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		var validationErrors []string
+		for _, e := range err.(validator.ValidationErrors) {
+			validationErrors = append(validationErrors, getValidationErrorMessage(c, e))
+		}
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", validationErrors)
+	}
+
+	customerID, ok := c.Locals("customer_id").(uint)
+	if !ok {
+		return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer ID not found in context", "MISSING_CUSTOMER_ID", nil)
+	}
+	req.CustomerID = customerID
+
+	metadata := businessflow.NewClientMetadata(c.IP(), c.Get("User-Agent"))
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/campaign-templates", 30*time.Second)
+	defer cancel()
+	res, err := h.flow.CreateTemplate(ctx, &req, metadata)
+	if err != nil {
+		return h.handleFlowError(c, "Create campaign template failed", "CAMPAIGN_TEMPLATE_CREATE_FAILED", err)
+	}
+	return h.SuccessResponse(c, fiber.StatusCreated, "Campaign template created", res)
+}
+
+// ListTemplates returns the customer's templates followed by global templates
+// @Summary List Campaign Templates
+// @Description List the customer's own campaign templates and admin-published global templates
+// @Tags Campaign Templates
+// @Produce json
+// @Success 200 {object} dto.APIResponse{data=dto.ListCampaignTemplatesResponse}
+// @Failure 401 {object} dto.APIResponse "Unauthorized"
+// @Failure 500 {object} dto.APIResponse "List failed"
+// @Security CustomerBearer
+// @Router /api/v1/campaign-templates [get]
+func (h *CampaignTemplateHandler) ListTemplates(c fiber.Ctx) error {
+	customerID, ok := c.Locals("customer_id").(uint)
+	if !ok {
+		return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer ID not found in context", "MISSING_CUSTOMER_ID", nil)
+	}
+
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/campaign-templates", 30*time.Second)
+	defer cancel()
+	res, err := h.flow.ListTemplates(ctx, customerID)
+	if err != nil {
+		log.Println("List campaign templates failed:", err)
+		return h.ErrorResponse(c, fiber.StatusInternalServerError, "List campaign templates failed", "CAMPAIGN_TEMPLATE_LIST_FAILED", nil)
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, "Campaign templates retrieved", res)
+}
+
+// DeleteTemplate removes one of the customer's own templates
+// @Summary Delete Campaign Template
+// @Description Delete a campaign template owned by the customer
+// @Tags Campaign Templates
+// @Produce json
+// @Param uuid path string true "Template UUID"
+// @Success 200 {object} dto.APIResponse{data=dto.DeleteCampaignTemplateResponse}
+// @Failure 401 {object} dto.APIResponse "Unauthorized"
+// @Failure 404 {object} dto.APIResponse "Template not found"
+// @Failure 500 {object} dto.APIResponse "Delete failed"
+// @Security CustomerBearer
+// @Router /api/v1/campaign-templates/{uuid} [delete]
+func (h *CampaignTemplateHandler) DeleteTemplate(c fiber.Ctx) error {
+	customerID, ok := c.Locals("customer_id").(uint)
+	if !ok {
+		return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer ID not found in context", "MISSING_CUSTOMER_ID", nil)
+	}
+
+	metadata := businessflow.NewClientMetadata(c.IP(), c.Get("User-Agent"))
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/campaign-templates/:uuid", 30*time.Second)
+	defer cancel()
+	res, err := h.flow.DeleteTemplate(ctx, customerID, c.Params("uuid"), metadata)
+	if err != nil {
+		return h.handleFlowError(c, "Delete campaign template failed", "CAMPAIGN_TEMPLATE_DELETE_FAILED", err)
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, "Campaign template deleted", res)
+}
+
+// CreateCampaignFromTemplate creates a new campaign from a template
+// @Summary Create Campaign From Template
+// @Description Create a new campaign using the content and targeting stored in a template
+// @Tags Campaign Templates
+// @Accept json
+// @Produce json
+// @Param uuid path string true "Template UUID"
+// @Param request body dto.CreateCampaignFromTemplateRequest true "Campaign specific fields"
+// @Success 201 {object} dto.APIResponse{data=dto.CreateCampaignResponse}
+// @Failure 400 {object} dto.APIResponse "Validation error"
+// @Failure 401 {object} dto.APIResponse "Unauthorized"
+// @Failure 404 {object} dto.APIResponse "Template not found"
+// @Failure 500 {object} dto.APIResponse "Creation failed"
+// @Security CustomerBearer
+// @Router /api/v1/campaign-templates/{uuid}/campaigns [post]
+func (h *CampaignTemplateHandler) CreateCampaignFromTemplate(c fiber.Ctx) error {
+	var req dto.CreateCampaignFromTemplateRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body", "INVALID_REQUEST", err.Error())
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		var validationErrors []string
+		for _, e := range err.(validator.ValidationErrors) {
+			validationErrors = append(validationErrors, getValidationErrorMessage(c, e))
+		}
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", validationErrors)
+	}
+
+	customerID, ok := c.Locals("customer_id").(uint)
+	if !ok {
+		return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer ID not found in context", "MISSING_CUSTOMER_ID", nil)
+	}
+	req.CustomerID = customerID
+	req.UUID = c.Params("uuid")
+
+	metadata := businessflow.NewClientMetadata(c.IP(), c.Get("User-Agent"))
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/campaign-templates/:uuid/campaigns", 30*time.Second)
+	defer cancel()
+	res, err := h.flow.CreateCampaignFromTemplate(ctx, &req, metadata)
+	if err != nil {
+		if businessflow.IsCampaignTemplateNotFound(err) {
+			return h.ErrorResponse(c, fiber.StatusNotFound, "Campaign template not found", "CAMPAIGN_TEMPLATE_NOT_FOUND", nil)
+		}
+		log.Println("Create campaign from template failed:", err)
+		return h.campaignErrors.handleCampaignFlowError(c, err, fiber.StatusInternalServerError, "Campaign creation failed", "CAMPAIGN_CREATION_FAILED")
+	}
+	return h.SuccessResponse(c, fiber.StatusCreated, "Campaign created successfully", res)
+}
+
+// AdminPublishTemplate publishes a global template visible to all customers
+// @Summary Publish Campaign Template (Admin)
+// @Description Publish a global campaign template that every customer can use
+// @Tags Admin Campaign Templates
+// @Accept json
+// @Produce json
+// @Param request body dto.AdminCreateCampaignTemplateRequest true "Template payload"
+// @Success 201 {object} dto.APIResponse{data=dto.CampaignTemplateResponse}
+// @Failure 400 {object} dto.APIResponse "Validation error"
+// @Failure 409 {object} dto.APIResponse "Template name already used"
+// @Failure 500 {object} dto.APIResponse "Creation failed"
+// @Security AdminBearer
+// @Router /api/v1/admin/campaign-templates [post]
+func (h *CampaignTemplateHandler) AdminPublishTemplate(c fiber.Ctx) error {
+	var req dto.AdminCreateCampaignTemplateRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body", "INVALID_REQUEST", err.Error())
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		var validationErrors []string
+		for _, e := range err.(validator.ValidationErrors) {
+			validationErrors = append(validationErrors, getValidationErrorMessage(c, e))
+		}
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", validationErrors)
+	}
+
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/admin/campaign-templates", 30*time.Second)
+	defer cancel()
+	res, err := h.flow.AdminPublishTemplate(ctx, &req)
+	if err != nil {
+		return h.handleFlowError(c, "Publish campaign template failed", "CAMPAIGN_TEMPLATE_PUBLISH_FAILED", err)
+	}
+	return h.SuccessResponse(c, fiber.StatusCreated, "Campaign template published", res)
+}
+
+// AdminListTemplates lists global templates
+// @Summary List Global Campaign Templates (Admin)
+// @Description List admin-published global campaign templates
+// @Tags Admin Campaign Templates
+// @Produce json
+// @Success 200 {object} dto.APIResponse{data=dto.ListCampaignTemplatesResponse}
+// @Failure 500 {object} dto.APIResponse "List failed"
+// @Security AdminBearer
+// @Router /api/v1/admin/campaign-templates [get]
+func (h *CampaignTemplateHandler) AdminListTemplates(c fiber.Ctx) error {
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/admin/campaign-templates", 30*time.Second)
+	defer cancel()
+	res, err := h.flow.AdminListTemplates(ctx)
+	if err != nil {
+		log.Println("Admin list campaign templates failed:", err)
+		return h.ErrorResponse(c, fiber.StatusInternalServerError, "List campaign templates failed", "CAMPAIGN_TEMPLATE_LIST_FAILED", nil)
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, "Campaign templates retrieved", res)
+}
+
+// AdminDeleteTemplate removes a global template
+// @Summary Delete Global Campaign Template (Admin)
+// @Description Delete an admin-published global campaign template
+// @Tags Admin Campaign Templates
+// @Produce json
+// @Param uuid path string true "Template UUID"
+// @Success 200 {object} dto.APIResponse{data=dto.DeleteCampaignTemplateResponse}
+// @Failure 404 {object} dto.APIResponse "Template not found"
+// @Failure 500 {object} dto.APIResponse "Delete failed"
+// @Security AdminBearer
+// @Router /api/v1/admin/campaign-templates/{uuid} [delete]
+func (h *CampaignTemplateHandler) AdminDeleteTemplate(c fiber.Ctx) error {
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/admin/campaign-templates/:uuid", 30*time.Second)
+	defer cancel()
+	res, err := h.flow.AdminDeleteTemplate(ctx, c.Params("uuid"))
+	if err != nil {
+		return h.handleFlowError(c, "Delete campaign template failed", "CAMPAIGN_TEMPLATE_DELETE_FAILED", err)
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, "Campaign template deleted", res)
+}
+
+func (h *CampaignTemplateHandler) handleFlowError(c fiber.Ctx, message, code string, err error) error {
+	switch {
+	case businessflow.IsCampaignTemplateNotFound(err):
+		return h.ErrorResponse(c, fiber.StatusNotFound, "Campaign template not found", "CAMPAIGN_TEMPLATE_NOT_FOUND", nil)
+	case businessflow.IsCampaignTemplateAlreadyExists(err):
+		return h.ErrorResponse(c, fiber.StatusConflict, "Campaign template with this name already exists", "CAMPAIGN_TEMPLATE_ALREADY_EXISTS", nil)
+	case businessflow.IsCampaignTemplateNameRequired(err):
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Campaign template name is required", "CAMPAIGN_TEMPLATE_NAME_REQUIRED", nil)
+	case businessflow.IsCampaignPlatformRequired(err) || businessflow.IsCampaignPlatformInvalid(err):
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid platform", "INVALID_PLATFORM", nil)
+	case businessflow.IsInvalidShortLinkDomain(err) || businessflow.IsCampaignAudienceGradesInvalid(err):
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Campaign template validation failed", "CAMPAIGN_TEMPLATE_VALIDATION_FAILED", nil)
+	case businessflow.IsCustomerNotFound(err):
+		return h.ErrorResponse(c, fiber.StatusNotFound, "Customer not found", "CUSTOMER_NOT_FOUND", nil)
+	case businessflow.IsAccountInactive(err):
+		return h.ErrorResponse(c, fiber.StatusForbidden, "Account is inactive", "ACCOUNT_INACTIVE", nil)
+	}
+	log.Println(message+":", err)
+	return h.ErrorResponse(c, fiber.StatusInternalServerError, message, code, nil)
+}
+
+func (h *CampaignTemplateHandler) createRequestContextWithTimeout(c fiber.Ctx, endpoint string, timeout time.Duration) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	ctx = context.WithValue(ctx, utils.RequestIDKey, c.Get("X-Request-ID"))
+	ctx = context.WithValue(ctx, utils.UserAgentKey, c.Get("User-Agent"))
+	ctx = context.WithValue(ctx, utils.IPAddressKey, c.IP())
+	ctx = context.WithValue(ctx, utils.EndpointKey, endpoint)
+	ctx = context.WithValue(ctx, utils.TimeoutKey, timeout)
+	ctx = context.WithValue(ctx, utils.CancelFuncKey, cancel)
+	if adminID, ok := middleware.GetAdminIDFromContext(c); ok {
+		ctx = context.WithValue(ctx, utils.AdminIDKey, adminID)
+	}
+	ctx = middleware.WithImpersonation(ctx, c)
+	return ctx, cancel
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/apierror"
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/app/middleware"
+	businessflow "github.com/amirphl/Yamata-no-Orochi/business_flow"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/gofiber/fiber/v3"
+)
+
+// CampaignTimelineAdminHandlerInterface defines the admin campaign timeline endpoint
+type CampaignTimelineAdminHandlerInterface interface {
+	GetCampaignTimeline(c fiber.Ctx) error
+}
+
+// CampaignTimelineAdminHandler implements the admin campaign timeline endpoint
+type CampaignTimelineAdminHandler struct {
+	flow businessflow.CampaignTimelineFlow
+}
+
+func NewCampaignTimelineAdminHandler(flow businessflow.CampaignTimelineFlow) CampaignTimelineAdminHandlerInterface {
+	return &CampaignTimelineAdminHandler{flow: flow}
+}
+
+func (h *CampaignTimelineAdminHandler) ErrorResponse(c fiber.Ctx, status int, message, code string, details any) error {
+	return apierror.Respond(c, status, message, code, details)
+}
+
+func (h *CampaignTimelineAdminHandler) SuccessResponse(c fiber.Ctx, status int, message string, data any) error {
+	return c.Status(status).JSON(dto.APIResponse{Success: true, Message: message, Data: data})
+}
+
+// GetCampaignTimeline returns what happened to a campaign
+// @Summary Admin Get Campaign Timeline
+// @Description Return the execution timeline of a campaign, oldest first: creation, audited actions such as approvals, cancellations and pauses, review entries, audience preparation, every batch handed to the provider and the provider's delivery status responses, plus the aggregated delivery reports. Deleted campaigns are included. At most 500 audit entries and 1000 batches are listed; truncated is set when there are more.
+// @Tags Admin Campaigns
+// @Produce json
+// @Param id path string true "Campaign ID"
+// @Success 200 {object} dto.APIResponse{data=dto.AdminCampaignTimelineResponse}
+// @Failure 400 {object} dto.APIResponse "Invalid campaign ID"
+// @Failure 404 {object} dto.APIResponse "Campaign not found"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security AdminBearer
+// @Router /api/v1/admin/campaigns/{id}/timeline [get]
+func (h *CampaignTimelineAdminHandler) GetCampaignTimeline(c fiber.Ctx) error {
+	id := c.Params("id")
+	idUint, err := strconv.ParseUint(id, 10, 64)
+	if err != nil || idUint == 0 {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid campaign ID", "INVALID_CAMPAIGN_ID", nil)
+	}
+
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/admin/campaigns/"+id+"/timeline", 30*time.Second)
+	defer cancel()
+	res, err := h.flow.GetTimeline(ctx, uint(idUint))
+	if err != nil {
+		if businessflow.IsCampaignNotFound(err) {
+			return h.ErrorResponse(c, fiber.StatusNotFound, "Campaign not found", "CAMPAIGN_NOT_FOUND", nil)
+		}
+		log.Println("Admin get campaign timeline failed", err)
+		return h.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to get campaign timeline", "ADMIN_CAMPAIGN_TIMELINE_FAILED", nil)
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, res.Message, res)
+}
+
+func (h *CampaignTimelineAdminHandler) createRequestContextWithTimeout(c fiber.Ctx, endpoint string, timeout time.Duration) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	ctx = context.WithValue(ctx, utils.RequestIDKey, c.Get("X-Request-ID"))
+	ctx = context.WithValue(ctx, utils.UserAgentKey, c.Get("User-Agent"))
+	ctx = context.WithValue(ctx, utils.IPAddressKey, c.IP())
+	ctx = context.WithValue(ctx, utils.EndpointKey, endpoint)
+	ctx = context.WithValue(ctx, utils.TimeoutKey, timeout)
+	ctx = context.WithValue(ctx, utils.CancelFuncKey, cancel)
+	if adminID, ok := middleware.GetAdminIDFromContext(c); ok {
+		ctx = context.WithValue(ctx, utils.AdminIDKey, adminID)
+	}
+	return ctx, cancel
+}
@@ -3,7 +3,9 @@ package handlers
 import (
 	"context"
 
+	"github.com/amirphl/Yamata-no-Orochi/app/apierror"
 	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/app/middleware"
 	businessflow "github.com/amirphl/Yamata-no-Orochi/business_flow"
 	"github.com/amirphl/Yamata-no-Orochi/config"
 	"github.com/amirphl/Yamata-no-Orochi/utils"
@@ -38,20 +40,22 @@ func NewCryptoPaymentHandler(flow businessflow.CryptoPaymentFlow, cfg *config.Pr
 // @Success 200 {object} dto.APIResponse{data=dto.CreateCryptoPaymentResponse}
 // @Failure 400 {object} dto.APIResponse
 // @Failure 401 {object} dto.APIResponse
+// @Failure 403 {object} dto.APIResponse
 // @Failure 500 {object} dto.APIResponse
+// @Security CustomerBearer
 // @Router /api/v1/crypto/payments/request [post]
 func (h *CryptoPaymentHandler) CreateRequest(c fiber.Ctx) error {
 	var req dto.CreateCryptoPaymentRequest
 	if err := c.Bind().JSON(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(dto.APIResponse{Success: false, Message: "Invalid request body", Error: dto.ErrorDetail{Code: "INVALID_REQUEST"}})
+		return apierror.Respond(c, fiber.StatusBadRequest, "Invalid request body", "INVALID_REQUEST", nil)
 	}
 	customerID, ok := c.Locals("customer_id").(uint)
 	if !ok {
-		return c.Status(fiber.StatusUnauthorized).JSON(dto.APIResponse{Success: false, Message: "Unauthorized", Error: dto.ErrorDetail{Code: "MISSING_CUSTOMER_ID"}})
+		return apierror.Respond(c, fiber.StatusUnauthorized, "Unauthorized", "MISSING_CUSTOMER_ID", nil)
 	}
 	req.CustomerID = customerID
 	if err := h.validator.Struct(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(dto.APIResponse{Success: false, Message: "Validation failed", Error: dto.ErrorDetail{Code: "VALIDATION_ERROR", Details: err.Error()}})
+		return apierror.Respond(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", err.Error())
 	}
 	meta := businessflow.NewClientMetadata(c.IP(), c.Get("User-Agent"))
 	resp, err := h.flow.CreateRequest(h.requestCtx(c, "/api/v1/crypto/payments/request"), &req, meta)
@@ -72,12 +76,13 @@ func (h *CryptoPaymentHandler) CreateRequest(c fiber.Ctx) error {
 // @Failure 401 {object} dto.APIResponse
 // @Failure 404 {object} dto.APIResponse
 // @Failure 500 {object} dto.APIResponse
+// @Security CustomerBearer
 // @Router /api/v1/crypto/payments/{uuid}/status [get]
 func (h *CryptoPaymentHandler) GetStatus(c fiber.Ctx) error {
 	uuid := c.Params("uuid")
 	customerID, ok := c.Locals("customer_id").(uint)
 	if !ok {
-		return c.Status(fiber.StatusUnauthorized).JSON(dto.APIResponse{Success: false, Message: "Unauthorized", Error: dto.ErrorDetail{Code: "MISSING_CUSTOMER_ID"}})
+		return apierror.Respond(c, fiber.StatusUnauthorized, "Unauthorized", "MISSING_CUSTOMER_ID", nil)
 	}
 	req := dto.GetCryptoPaymentStatusRequest{UUID: uuid, CustomerID: customerID}
 	meta := businessflow.NewClientMetadata(c.IP(), c.Get("User-Agent"))
@@ -100,19 +105,20 @@ func (h *CryptoPaymentHandler) GetStatus(c fiber.Ctx) error {
 // @Failure 401 {object} dto.APIResponse
 // @Failure 404 {object} dto.APIResponse
 // @Failure 500 {object} dto.APIResponse
+// @Security CustomerBearer
 // @Router /api/v1/crypto/payments/verify [post]
 func (h *CryptoPaymentHandler) ManualVerify(c fiber.Ctx) error {
 	var req dto.ManualVerifyCryptoDepositRequest
 	if err := c.Bind().JSON(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(dto.APIResponse{Success: false, Message: "Invalid request body", Error: dto.ErrorDetail{Code: "INVALID_REQUEST"}})
+		return apierror.Respond(c, fiber.StatusBadRequest, "Invalid request body", "INVALID_REQUEST", nil)
 	}
 	customerID, ok := c.Locals("customer_id").(uint)
 	if !ok {
-		return c.Status(fiber.StatusUnauthorized).JSON(dto.APIResponse{Success: false, Message: "Unauthorized", Error: dto.ErrorDetail{Code: "MISSING_CUSTOMER_ID"}})
+		return apierror.Respond(c, fiber.StatusUnauthorized, "Unauthorized", "MISSING_CUSTOMER_ID", nil)
 	}
 	req.CustomerID = customerID
 	if err := h.validator.Struct(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(dto.APIResponse{Success: false, Message: "Validation failed", Error: dto.ErrorDetail{Code: "VALIDATION_ERROR", Details: err.Error()}})
+		return apierror.Respond(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", err.Error())
 	}
 	meta := businessflow.NewClientMetadata(c.IP(), c.Get("User-Agent"))
 	resp, err := h.flow.ManualVerify(h.requestCtx(c, "/api/v1/crypto/payments/verify"), &req, meta)
@@ -122,13 +128,13 @@ func (h *CryptoPaymentHandler) ManualVerify(c fiber.Ctx) error {
 	return c.Status(fiber.StatusOK).JSON(dto.APIResponse{Success: true, Message: "Crypto deposit verified", Data: resp})
 }
 
-// Webhook receives provider callbacks (oxapay)
+// Webhook receives provider callbacks (oxapay, nowpayments)
 // @Summary Crypto Provider Webhook
 // @Description Receives provider callbacks and updates deposit and wallet balances
 // @Tags Payments
 // @Accept json
 // @Produce text/plain
-// @Param platform path string true "Provider platform (oxapay)"
+// @Param platform path string true "Provider platform (oxapay, nowpayments)"
 // @Success 200 {string} string "OK"
 // @Router /api/v1/crypto/providers/{platform}/callback [post]
 func (h *CryptoPaymentHandler) Webhook(c fiber.Ctx) error {
@@ -143,28 +149,44 @@ func (h *CryptoPaymentHandler) Webhook(c fiber.Ctx) error {
 		}
 		// return c.SendString("OK")
 		return c.SendString("ok") // TODO: TEST
+	case "nowpayments":
+		raw := c.Body()
+		signature := c.Get("x-nowpayments-sig")
+		if err := h.flow.HandleNowPaymentsWebhook(h.requestCtx(c, "/api/v1/crypto/providers/nowpayments/callback"), raw, signature, h.cfg.Crypto.NowPayments.IPNSecret, meta); err != nil {
+			return c.Status(fiber.StatusBadRequest).SendString("ERR")
+		}
+		return c.SendString("ok")
 	default:
 		return c.Status(fiber.StatusNotFound).SendString("NOT_SUPPORTED")
 	}
 }
 
 func (h *CryptoPaymentHandler) requestCtx(c fiber.Ctx, endpoint string) context.Context {
-	return context.WithValue(context.Background(), utils.EndpointKey, endpoint)
+	ctx := context.WithValue(context.Background(), utils.EndpointKey, endpoint)
+	return middleware.WithImpersonation(ctx, c)
 }
 
 func mapCryptoErr(c fiber.Ctx, err error) error {
 	switch {
 	case businessflow.IsCustomerNotFound(err):
-		return c.Status(fiber.StatusUnauthorized).JSON(dto.APIResponse{Success: false, Message: "Customer not found", Error: dto.ErrorDetail{Code: "CUSTOMER_NOT_FOUND"}})
+		return apierror.Respond(c, fiber.StatusNotFound, "Customer not found", "CUSTOMER_NOT_FOUND", nil)
 	case businessflow.IsAccountInactive(err):
-		return c.Status(fiber.StatusForbidden).JSON(dto.APIResponse{Success: false, Message: "Account inactive", Error: dto.ErrorDetail{Code: "ACCOUNT_INACTIVE"}})
+		return apierror.Respond(c, fiber.StatusForbidden, "Account inactive", "ACCOUNT_INACTIVE", nil)
+	case businessflow.IsSandboxRealPayment(err):
+		return apierror.Respond(c, fiber.StatusForbidden, "Sandbox accounts cannot make real payments; top up the sandbox wallet instead", "SANDBOX_REAL_PAYMENT", nil)
+	case businessflow.IsCustomerSuspended(err):
+		return customerSuspended(c, err)
 	case businessflow.IsAgencyDiscountNotFound(err):
-		return c.Status(fiber.StatusNotFound).JSON(dto.APIResponse{Success: false, Message: "Agency discount not found", Error: dto.ErrorDetail{Code: "AGENCY_DISCOUNT_NOT_FOUND"}})
+		return apierror.Respond(c, fiber.StatusNotFound, "Agency discount not found", "AGENCY_DISCOUNT_NOT_FOUND", nil)
 	case businessflow.IsCryptoUnsupportedPlatform(err):
-		return c.Status(fiber.StatusBadRequest).JSON(dto.APIResponse{Success: false, Message: "Unsupported platform", Error: dto.ErrorDetail{Code: "UNSUPPORTED_PLATFORM"}})
+		return apierror.Respond(c, fiber.StatusBadRequest, "Unsupported platform", "UNSUPPORTED_PLATFORM", nil)
 	case businessflow.IsAmountTooLow(err):
-		return c.Status(fiber.StatusBadRequest).JSON(dto.APIResponse{Success: false, Message: "Amount too low", Error: dto.ErrorDetail{Code: "AMOUNT_TOO_LOW"}})
+		return apierror.Respond(c, fiber.StatusBadRequest, "Amount too low", "AMOUNT_TOO_LOW", nil)
+	case businessflow.IsCryptoRateUnavailable(err):
+		return apierror.Respond(c, fiber.StatusServiceUnavailable, "Exchange rate unavailable, try again later", "CRYPTO_RATE_UNAVAILABLE", nil)
+	case businessflow.IsCryptoRatesDiverged(err):
+		return apierror.Respond(c, fiber.StatusServiceUnavailable, "Exchange rates are unstable, try again later", "CRYPTO_RATES_DIVERGED", nil)
 	default:
-		return c.Status(fiber.StatusInternalServerError).JSON(dto.APIResponse{Success: false, Message: "Crypto payment operation failed", Error: dto.ErrorDetail{Code: "CRYPTO_OPERATION_FAILED", Details: err.Error()}})
+		return apierror.Respond(c, fiber.StatusInternalServerError, "Crypto payment operation failed", "CRYPTO_OPERATION_FAILED", err.Error())
 	}
 }
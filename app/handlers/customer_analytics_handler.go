@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/apierror"
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	businessflow "github.com/amirphl/Yamata-no-Orochi/business_flow"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+)
+
+// CustomerAnalyticsHandlerInterface defines the customer analytics endpoints
+type CustomerAnalyticsHandlerInterface interface {
+	GetUsage(c fiber.Ctx) error
+	GetCampaignDailyStats(c fiber.Ctx) error
+}
+
+// CustomerAnalyticsHandler serves customers' usage and campaign statistics
+type CustomerAnalyticsHandler struct {
+	flow businessflow.CustomerAnalyticsFlow
+}
+
+func NewCustomerAnalyticsHandler(flow businessflow.CustomerAnalyticsFlow) CustomerAnalyticsHandlerInterface {
+	return &CustomerAnalyticsHandler{flow: flow}
+}
+
+func (h *CustomerAnalyticsHandler) ErrorResponse(c fiber.Ctx, status int, message, code string, details any) error {
+	return apierror.Respond(c, status, message, code, details)
+}
+
+func (h *CustomerAnalyticsHandler) SuccessResponse(c fiber.Ctx, status int, message string, data any) error {
+	return c.Status(status).JSON(dto.APIResponse{Success: true, Message: message, Data: data})
+}
+
+// GetUsage returns the customer's monthly usage
+// @Summary Customer Usage Report
+// @Description Campaigns, messages sent and delivered, unique clickers, campaign spend and wallet charges per month, the current Tehran month included. Served from the customer_monthly_usage rollup; refreshed_at tells when it was last refreshed.
+// @Tags Reports
+// @Produce json
+// @Param months query int false "Number of months" default(12) maximum(24)
+// @Success 200 {object} dto.APIResponse{data=dto.CustomerUsageReportResponse}
+// @Failure 400 {object} dto.APIResponse "Validation error"
+// @Failure 401 {object} dto.APIResponse "Unauthorized"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
+// @Router /api/v1/reports/usage [get]
+func (h *CustomerAnalyticsHandler) GetUsage(c fiber.Ctx) error {
+	customerID, ok := c.Locals("customer_id").(uint)
+	if !ok {
+		return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer ID not found in context", "MISSING_CUSTOMER_ID", nil)
+	}
+	months, err := strconv.Atoi(c.Query("months", "12"))
+	if err != nil || months < 1 || months > 24 {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "months must be between 1 and 24", "VALIDATION_ERROR", nil)
+	}
+
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/reports/usage", 30*time.Second)
+	defer cancel()
+	res, err := h.flow.GetUsage(ctx, &dto.CustomerUsageReportRequest{CustomerID: customerID, Months: months})
+	if err != nil {
+		log.Println("Get usage report failed:", err)
+		return h.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to retrieve usage report", "CUSTOMER_USAGE_REPORT_FAILED", nil)
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, "Usage report retrieved successfully", res)
+}
+
+// GetCampaignDailyStats returns a campaign's statistics per day
+// @Summary Campaign Daily Statistics
+// @Description Messages sent and delivered, unique clickers of the day and budget spent and refunded per Tehran day for one of the customer's campaigns. Without dates the last 30 days are returned; the range is capped at 366 days. Served from the campaign_daily_stats rollup.
+// @Tags Reports
+// @Produce json
+// @Param uuid path string true "Campaign UUID"
+// @Param start_date query string false "First day (YYYY-MM-DD)"
+// @Param end_date query string false "Last day (YYYY-MM-DD)"
+// @Success 200 {object} dto.APIResponse{data=dto.CampaignDailyStatsResponse}
+// @Failure 400 {object} dto.APIResponse "Validation error"
+// @Failure 401 {object} dto.APIResponse "Unauthorized"
+// @Failure 403 {object} dto.APIResponse "Campaign access denied"
+// @Failure 404 {object} dto.APIResponse "Campaign not found"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
+// @Router /api/v1/reports/campaigns/{uuid}/daily [get]
+func (h *CustomerAnalyticsHandler) GetCampaignDailyStats(c fiber.Ctx) error {
+	parsed, err := uuid.Parse(strings.TrimSpace(c.Params("uuid")))
+	if err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Campaign UUID is invalid", "INVALID_CAMPAIGN_UUID", nil)
+	}
+	customerID, ok := c.Locals("customer_id").(uint)
+	if !ok {
+		return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer ID not found in context", "MISSING_CUSTOMER_ID", nil)
+	}
+	req := dto.CampaignDailyStatsRequest{CustomerID: customerID, UUID: parsed.String()}
+	req.StartDate, req.EndDate = adminReportRange(c)
+
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/reports/campaigns/"+req.UUID+"/daily", 30*time.Second)
+	defer cancel()
+	res, err := h.flow.GetCampaignDailyStats(ctx, &req)
+	if err != nil {
+		var be *businessflow.BusinessError
+		switch {
+		case errors.As(err, &be) && be.Code == "VALIDATION_ERROR":
+			return h.ErrorResponse(c, fiber.StatusBadRequest, be.Message, be.Code, nil)
+		case businessflow.IsCampaignNotFound(err):
+			return h.ErrorResponse(c, fiber.StatusNotFound, "Campaign not found", "CAMPAIGN_NOT_FOUND", nil)
+		case businessflow.IsCampaignAccessDenied(err):
+			return h.ErrorResponse(c, fiber.StatusForbidden, "Campaign access denied", "CAMPAIGN_ACCESS_DENIED", nil)
+		}
+		log.Println("Get campaign daily stats failed:", err)
+		return h.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to retrieve campaign daily statistics", "CAMPAIGN_DAILY_STATS_FAILED", nil)
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, "Campaign daily statistics retrieved successfully", res)
+}
+
+func (h *CustomerAnalyticsHandler) createRequestContextWithTimeout(c fiber.Ctx, endpoint string, timeout time.Duration) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	ctx = context.WithValue(ctx, utils.RequestIDKey, c.Get("X-Request-ID"))
+	ctx = context.WithValue(ctx, utils.UserAgentKey, c.Get("User-Agent"))
+	ctx = context.WithValue(ctx, utils.IPAddressKey, c.IP())
+	ctx = context.WithValue(ctx, utils.EndpointKey, endpoint)
+	ctx = context.WithValue(ctx, utils.TimeoutKey, timeout)
+	ctx = context.WithValue(ctx, utils.CancelFuncKey, cancel)
+	return ctx, cancel
+}
@@ -0,0 +1,254 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/apierror"
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/app/middleware"
+	businessflow "github.com/amirphl/Yamata-no-Orochi/business_flow"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v3"
+)
+
+// CustomerDataHandlerInterface defines customer endpoints for data exports and account deletion
+type CustomerDataHandlerInterface interface {
+	RequestExport(c fiber.Ctx) error
+	DownloadExport(c fiber.Ctx) error
+	GetRequest(c fiber.Ctx) error
+	RequestDeletion(c fiber.Ctx) error
+	CancelDeletion(c fiber.Ctx) error
+}
+
+// CustomerDataHandler implements the customer data export and account deletion endpoints
+type CustomerDataHandler struct {
+	flow      businessflow.CustomerDataFlow
+	validator *validator.Validate
+}
+
+func NewCustomerDataHandler(flow businessflow.CustomerDataFlow) CustomerDataHandlerInterface {
+	return &CustomerDataHandler{
+		flow:      flow,
+		validator: validator.New(),
+	}
+}
+
+func (h *CustomerDataHandler) ErrorResponse(c fiber.Ctx, status int, message, code string, details any) error {
+	return apierror.Respond(c, status, message, code, details)
+}
+
+func (h *CustomerDataHandler) SuccessResponse(c fiber.Ctx, status int, message string, data any) error {
+	return c.Status(status).JSON(dto.APIResponse{Success: true, Message: message, Data: data})
+}
+
+// RequestExport queues an export of the customer's data
+// @Summary Request data export
+// @Description Queue a zip of the authenticated customer's profile, campaigns and transactions as JSON or CSV files. Poll the returned request and download the file once it is completed.
+// @Tags Account Data
+// @Accept json
+// @Produce json
+// @Param request body dto.DataExportRequest false "Export format"
+// @Success 202 {object} dto.APIResponse{data=dto.CustomerDataRequestResponse}
+// @Failure 400 {object} dto.APIResponse "Invalid format"
+// @Failure 401 {object} dto.APIResponse "Unauthorized"
+// @Failure 403 {object} dto.APIResponse "Not allowed while impersonating the customer"
+// @Failure 409 {object} dto.APIResponse "An export is already in progress"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
+// @Router /api/v1/account/data-exports [post]
+func (h *CustomerDataHandler) RequestExport(c fiber.Ctx) error {
+	customerID, ok := c.Locals("customer_id").(uint)
+	if !ok {
+		return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer ID not found in context", "MISSING_CUSTOMER_ID", nil)
+	}
+
+	var req dto.DataExportRequest
+	if len(c.Body()) > 0 {
+		if err := c.Bind().JSON(&req); err != nil {
+			return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body", "INVALID_REQUEST", err.Error())
+		}
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", err.Error())
+	}
+
+	metadata := businessflow.NewClientMetadata(c.IP(), c.Get("User-Agent"))
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/account/data-exports", 30*time.Second)
+	defer cancel()
+	res, err := h.flow.RequestExport(ctx, customerID, &req, metadata)
+	if err != nil {
+		return h.handleFlowError(c, "Failed to queue data export", "DATA_EXPORT_REQUEST_FAILED", err)
+	}
+	return h.SuccessResponse(c, fiber.StatusAccepted, res.Message, res)
+}
+
+// DownloadExport returns the zip file of a completed data export
+// @Summary Download data export
+// @Description Download the zip file of a completed data export before it expires
+// @Tags Account Data
+// @Produce application/zip
+// @Param uuid path string true "Data request UUID"
+// @Success 200 {file} file "Export bundle"
+// @Failure 401 {object} dto.APIResponse "Unauthorized"
+// @Failure 404 {object} dto.APIResponse "Export not found"
+// @Failure 409 {object} dto.APIResponse "Export not ready yet"
+// @Failure 410 {object} dto.APIResponse "Export expired"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
+// @Router /api/v1/account/data-exports/{uuid}/download [get]
+func (h *CustomerDataHandler) DownloadExport(c fiber.Ctx) error {
+	customerID, ok := c.Locals("customer_id").(uint)
+	if !ok {
+		return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer ID not found in context", "MISSING_CUSTOMER_ID", nil)
+	}
+
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/account/data-exports/:uuid/download", 60*time.Second)
+	defer cancel()
+	filename, data, err := h.flow.DownloadExport(ctx, customerID, c.Params("uuid"))
+	if err != nil {
+		return h.handleFlowError(c, "Failed to download data export", "DATA_EXPORT_DOWNLOAD_FAILED", err)
+	}
+
+	c.Set("Content-Type", "application/zip")
+	c.Set("Content-Disposition", "attachment; filename="+filename)
+	return c.Send(data)
+}
+
+// GetRequest returns the status of a data export or account deletion
+// @Summary Get data request
+// @Description Poll the status of a data export or account deletion of the authenticated customer
+// @Tags Account Data
+// @Produce json
+// @Param uuid path string true "Data request UUID"
+// @Success 200 {object} dto.APIResponse{data=dto.CustomerDataRequestResponse}
+// @Failure 401 {object} dto.APIResponse "Unauthorized"
+// @Failure 404 {object} dto.APIResponse "Request not found"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
+// @Router /api/v1/account/data-requests/{uuid} [get]
+func (h *CustomerDataHandler) GetRequest(c fiber.Ctx) error {
+	customerID, ok := c.Locals("customer_id").(uint)
+	if !ok {
+		return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer ID not found in context", "MISSING_CUSTOMER_ID", nil)
+	}
+
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/account/data-requests/:uuid", 30*time.Second)
+	defer cancel()
+	res, err := h.flow.GetRequest(ctx, customerID, c.Params("uuid"))
+	if err != nil {
+		return h.handleFlowError(c, "Failed to get data request", "DATA_REQUEST_LOOKUP_FAILED", err)
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, res.Message, res)
+}
+
+// RequestDeletion schedules the deletion of the customer's account
+// @Summary Request account deletion
+// @Description Schedule the deletion of the authenticated customer's account after a grace period. The wallet must be empty and no campaign may be active. The account is then deactivated and its personal data anonymized; wallets, transactions and campaigns are kept as financial records.
+// @Tags Account Data
+// @Accept json
+// @Produce json
+// @Param request body dto.AccountDeletionRequest true "Password confirmation"
+// @Success 202 {object} dto.APIResponse{data=dto.CustomerDataRequestResponse}
+// @Failure 400 {object} dto.APIResponse "Validation error"
+// @Failure 401 {object} dto.APIResponse "Unauthorized or incorrect password"
+// @Failure 403 {object} dto.APIResponse "Account cannot be deleted, or impersonating the customer"
+// @Failure 409 {object} dto.APIResponse "Deletion already scheduled, or the wallet still holds balance or campaigns are active"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
+// @Router /api/v1/account/deletion [post]
+func (h *CustomerDataHandler) RequestDeletion(c fiber.Ctx) error {
+	customerID, ok := c.Locals("customer_id").(uint)
+	if !ok {
+		return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer ID not found in context", "MISSING_CUSTOMER_ID", nil)
+	}
+
+	var req dto.AccountDeletionRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body", "INVALID_REQUEST", err.Error())
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", err.Error())
+	}
+
+	metadata := businessflow.NewClientMetadata(c.IP(), c.Get("User-Agent"))
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/account/deletion", 30*time.Second)
+	defer cancel()
+	res, err := h.flow.RequestDeletion(ctx, customerID, &req, metadata)
+	if err != nil {
+		return h.handleFlowError(c, "Failed to schedule account deletion", "ACCOUNT_DELETION_REQUEST_FAILED", err)
+	}
+	return h.SuccessResponse(c, fiber.StatusAccepted, res.Message, res)
+}
+
+// CancelDeletion cancels a scheduled account deletion
+// @Summary Cancel account deletion
+// @Description Cancel the authenticated customer's account deletion while it is still in its grace period
+// @Tags Account Data
+// @Produce json
+// @Success 200 {object} dto.APIResponse{data=dto.CustomerDataRequestResponse}
+// @Failure 401 {object} dto.APIResponse "Unauthorized"
+// @Failure 404 {object} dto.APIResponse "No deletion scheduled"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
+// @Router /api/v1/account/deletion [delete]
+func (h *CustomerDataHandler) CancelDeletion(c fiber.Ctx) error {
+	customerID, ok := c.Locals("customer_id").(uint)
+	if !ok {
+		return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer ID not found in context", "MISSING_CUSTOMER_ID", nil)
+	}
+
+	metadata := businessflow.NewClientMetadata(c.IP(), c.Get("User-Agent"))
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/account/deletion", 30*time.Second)
+	defer cancel()
+	res, err := h.flow.CancelDeletion(ctx, customerID, metadata)
+	if err != nil {
+		return h.handleFlowError(c, "Failed to cancel account deletion", "ACCOUNT_DELETION_CANCEL_FAILED", err)
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, res.Message, res)
+}
+
+func (h *CustomerDataHandler) handleFlowError(c fiber.Ctx, message, code string, err error) error {
+	switch {
+	case businessflow.IsCustomerNotFound(err):
+		return h.ErrorResponse(c, fiber.StatusNotFound, "Customer not found", "CUSTOMER_NOT_FOUND", nil)
+	case businessflow.IsDataRequestNotFound(err):
+		return h.ErrorResponse(c, fiber.StatusNotFound, "Data request not found", "DATA_REQUEST_NOT_FOUND", nil)
+	case businessflow.IsDataExportFormatInvalid(err):
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Export format must be json or csv", "DATA_EXPORT_FORMAT_INVALID", nil)
+	case businessflow.IsDataExportInProgress(err):
+		return h.ErrorResponse(c, fiber.StatusConflict, "A data export is already in progress", "DATA_EXPORT_IN_PROGRESS", nil)
+	case businessflow.IsDataExportNotReady(err):
+		return h.ErrorResponse(c, fiber.StatusConflict, "Data export is not ready yet", "DATA_EXPORT_NOT_READY", nil)
+	case businessflow.IsDataExportExpired(err):
+		return h.ErrorResponse(c, fiber.StatusGone, "Data export has expired", "DATA_EXPORT_EXPIRED", nil)
+	case businessflow.IsIncorrectPassword(err):
+		return h.ErrorResponse(c, fiber.StatusUnauthorized, "Incorrect password", "INCORRECT_PASSWORD", nil)
+	case businessflow.IsAccountDeletionNotAllowed(err):
+		return h.ErrorResponse(c, fiber.StatusForbidden, "This account cannot be deleted", "ACCOUNT_DELETION_NOT_ALLOWED", nil)
+	case businessflow.IsAccountDeletionScheduled(err):
+		return h.ErrorResponse(c, fiber.StatusConflict, "Account deletion is already scheduled", "ACCOUNT_DELETION_SCHEDULED", nil)
+	case businessflow.IsAccountDeletionBalance(err):
+		return h.ErrorResponse(c, fiber.StatusConflict, "Withdraw or spend the wallet balance before deleting the account", "ACCOUNT_DELETION_BALANCE_REMAINING", nil)
+	case businessflow.IsAccountDeletionCampaigns(err):
+		return h.ErrorResponse(c, fiber.StatusConflict, "Cancel or finish active campaigns before deleting the account", "ACCOUNT_DELETION_CAMPAIGNS_ACTIVE", nil)
+	case businessflow.IsAccountDeletionNotFound(err):
+		return h.ErrorResponse(c, fiber.StatusNotFound, "No account deletion is scheduled", "ACCOUNT_DELETION_NOT_FOUND", nil)
+	}
+	log.Println(message+":", err)
+	return h.ErrorResponse(c, fiber.StatusInternalServerError, message, code, nil)
+}
+
+func (h *CustomerDataHandler) createRequestContextWithTimeout(c fiber.Ctx, endpoint string, timeout time.Duration) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	ctx = context.WithValue(ctx, utils.RequestIDKey, c.Get("X-Request-ID"))
+	ctx = context.WithValue(ctx, utils.UserAgentKey, c.Get("User-Agent"))
+	ctx = context.WithValue(ctx, utils.IPAddressKey, c.IP())
+	ctx = context.WithValue(ctx, utils.EndpointKey, endpoint)
+	ctx = context.WithValue(ctx, utils.TimeoutKey, timeout)
+	ctx = context.WithValue(ctx, utils.CancelFuncKey, cancel)
+	ctx = middleware.WithImpersonation(ctx, c)
+	return ctx, cancel
+}
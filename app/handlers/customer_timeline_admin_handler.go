@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/apierror"
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/app/middleware"
+	businessflow "github.com/amirphl/Yamata-no-Orochi/business_flow"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/gofiber/fiber/v3"
+)
+
+// CustomerTimelineAdminHandlerInterface defines the admin customer timeline endpoint
+type CustomerTimelineAdminHandlerInterface interface {
+	GetCustomerTimeline(c fiber.Ctx) error
+}
+
+// CustomerTimelineAdminHandler implements the admin customer timeline endpoint
+type CustomerTimelineAdminHandler struct {
+	flow businessflow.CustomerTimelineFlow
+}
+
+func NewCustomerTimelineAdminHandler(flow businessflow.CustomerTimelineFlow) CustomerTimelineAdminHandlerInterface {
+	return &CustomerTimelineAdminHandler{flow: flow}
+}
+
+func (h *CustomerTimelineAdminHandler) ErrorResponse(c fiber.Ctx, status int, message, code string, details any) error {
+	return apierror.Respond(c, status, message, code, details)
+}
+
+func (h *CustomerTimelineAdminHandler) SuccessResponse(c fiber.Ctx, status int, message string, data any) error {
+	return c.Status(status).JSON(dto.APIResponse{Success: true, Message: message, Data: data})
+}
+
+// GetCustomerTimeline returns a page of a customer's activity
+// @Summary Admin Get Customer Timeline
+// @Description Return a customer's activity newest first: audit log entries, login sessions, payment requests and campaigns, each as a typed event. Filter with category, repeated or comma separated. Continue with the next_cursor of the previous page and the same categories.
+// @Tags Admin Customer Management
+// @Produce json
+// @Param id path string true "Customer ID"
+// @Param category query string false "Categories to include: audit, session, payment, campaign"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param limit query int false "Page size, 1 to 100" default(20)
+// @Success 200 {object} dto.APIResponse{data=dto.AdminCustomerTimelineResponse}
+// @Failure 400 {object} dto.APIResponse "Invalid customer ID, category, cursor or limit"
+// @Failure 404 {object} dto.APIResponse "Customer not found"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security AdminBearer
+// @Router /api/v1/admin/customers/{id}/timeline [get]
+func (h *CustomerTimelineAdminHandler) GetCustomerTimeline(c fiber.Ctx) error {
+	id := c.Params("id")
+	idUint, err := strconv.ParseUint(id, 10, 64)
+	if err != nil || idUint == 0 {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid customer ID", "VALIDATION_ERROR", nil)
+	}
+	req := dto.AdminCustomerTimelineRequest{CustomerID: uint(idUint), Cursor: c.Query("cursor")}
+	if v := c.Query("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 || limit > 100 {
+			return h.ErrorResponse(c, fiber.StatusBadRequest, "limit must be between 1 and 100", "VALIDATION_ERROR", nil)
+		}
+		req.Limit = limit
+	}
+	for _, v := range c.RequestCtx().QueryArgs().PeekMulti("category") {
+		req.Categories = append(req.Categories, string(v))
+	}
+
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/admin/customers/"+id+"/timeline", 30*time.Second)
+	defer cancel()
+	res, err := h.flow.GetTimeline(ctx, &req)
+	if err != nil {
+		switch {
+		case businessflow.IsTimelineCategoryInvalid(err):
+			return h.ErrorResponse(c, fiber.StatusBadRequest, "Category must be one of audit, session, payment and campaign", "TIMELINE_CATEGORY_INVALID", nil)
+		case businessflow.IsTimelineCursorInvalid(err):
+			return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid timeline cursor", "TIMELINE_CURSOR_INVALID", nil)
+		case businessflow.IsCustomerNotFound(err):
+			return h.ErrorResponse(c, fiber.StatusNotFound, "Customer not found", "CUSTOMER_NOT_FOUND", nil)
+		}
+		log.Println("Admin get customer timeline failed", err)
+		return h.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to get customer timeline", "ADMIN_CUSTOMER_TIMELINE_FAILED", nil)
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, res.Message, res)
+}
+
+func (h *CustomerTimelineAdminHandler) createRequestContextWithTimeout(c fiber.Ctx, endpoint string, timeout time.Duration) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	ctx = context.WithValue(ctx, utils.RequestIDKey, c.Get("X-Request-ID"))
+	ctx = context.WithValue(ctx, utils.UserAgentKey, c.Get("User-Agent"))
+	ctx = context.WithValue(ctx, utils.IPAddressKey, c.IP())
+	ctx = context.WithValue(ctx, utils.EndpointKey, endpoint)
+	ctx = context.WithValue(ctx, utils.TimeoutKey, timeout)
+	ctx = context.WithValue(ctx, utils.CancelFuncKey, cancel)
+	if adminID, ok := middleware.GetAdminIDFromContext(c); ok {
+		ctx = context.WithValue(ctx, utils.AdminIDKey, adminID)
+	}
+	return ctx, cancel
+}
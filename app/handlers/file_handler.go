@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/apierror"
+	"github.com/amirphl/Yamata-no-Orochi/app/storage"
+	"github.com/gofiber/fiber/v3"
+)
+
+// FileHandlerInterface serves the signed download links of the local
+// storage driver
+type FileHandlerInterface interface {
+	Download(c fiber.Ctx) error
+}
+
+// signedFileStore reads files behind links it signed itself
+type signedFileStore interface {
+	VerifySignedURL(key, expires, signature string) error
+	Get(ctx context.Context, key string) ([]byte, string, error)
+}
+
+// FileHandler implements the signed file download endpoint
+type FileHandler struct {
+	// store is nil unless the local storage driver is used; S3 links point
+	// at the bucket directly
+	store signedFileStore
+}
+
+func NewFileHandler(store storage.Store) FileHandlerInterface {
+	h := &FileHandler{}
+	if local, ok := store.(*storage.LocalStorage); ok {
+		h.store = local
+	}
+	return h
+}
+
+func (h *FileHandler) ErrorResponse(c fiber.Ctx, status int, message, code string, details any) error {
+	return apierror.Respond(c, status, message, code, details)
+}
+
+// Download serves a file through a signed link
+// @Summary Download Stored File
+// @Description Download a privately stored file through a short-lived link handed out by another endpoint, such as a KYC document link. Only used with the local storage driver; links expire and cannot be altered.
+// @Tags Files
+// @Produce octet-stream
+// @Param key path string true "Object key"
+// @Param expires query int true "Unix time the link expires at"
+// @Param signature query string true "Link signature"
+// @Success 200 {file} file "File content"
+// @Failure 403 {object} dto.APIResponse "Invalid or expired link"
+// @Failure 404 {object} dto.APIResponse "File not found"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Router /api/v1/files/{key} [get]
+func (h *FileHandler) Download(c fiber.Ctx) error {
+	if h.store == nil {
+		return h.ErrorResponse(c, fiber.StatusNotFound, "File not found", "FILE_NOT_FOUND", nil)
+	}
+	key, err := url.PathUnescape(c.Params("*"))
+	if err != nil {
+		return h.ErrorResponse(c, fiber.StatusNotFound, "File not found", "FILE_NOT_FOUND", nil)
+	}
+	if err := h.store.VerifySignedURL(key, c.Query("expires"), c.Query("signature")); err != nil {
+		if errors.Is(err, storage.ErrSignatureExpired) {
+			return h.ErrorResponse(c, fiber.StatusForbidden, "Download link expired", "FILE_LINK_EXPIRED", nil)
+		}
+		return h.ErrorResponse(c, fiber.StatusForbidden, "Invalid download link", "FILE_LINK_INVALID", nil)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	data, contentType, err := h.store.Get(ctx, key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return h.ErrorResponse(c, fiber.StatusNotFound, "File not found", "FILE_NOT_FOUND", nil)
+		}
+		log.Println("Download stored file failed:", err)
+		return h.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to read file", "FILE_READ_FAILED", nil)
+	}
+
+	c.Set("Content-Disposition", `attachment; filename="`+strings.ReplaceAll(path.Base(key), `"`, "")+`"`)
+	c.Set("Content-Type", contentType)
+	c.Set("Cache-Control", "private, no-store")
+	c.Set("X-Content-Type-Options", "nosniff")
+	return c.Send(data)
+}
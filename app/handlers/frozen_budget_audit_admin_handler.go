@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/apierror"
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/app/middleware"
+	businessflow "github.com/amirphl/Yamata-no-Orochi/business_flow"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/gofiber/fiber/v3"
+)
+
+// FrozenBudgetAuditAdminHandlerInterface defines the admin frozen budget audit endpoint
+type FrozenBudgetAuditAdminHandlerInterface interface {
+	Audit(c fiber.Ctx) error
+}
+
+// FrozenBudgetAuditAdminHandler implements the admin frozen budget audit endpoint
+type FrozenBudgetAuditAdminHandler struct {
+	flow businessflow.FrozenBudgetAuditFlow
+}
+
+func NewFrozenBudgetAuditAdminHandler(flow businessflow.FrozenBudgetAuditFlow) FrozenBudgetAuditAdminHandlerInterface {
+	return &FrozenBudgetAuditAdminHandler{flow: flow}
+}
+
+func (h *FrozenBudgetAuditAdminHandler) ErrorResponse(c fiber.Ctx, status int, message, code string, details any) error {
+	return apierror.Respond(c, status, message, code, details)
+}
+
+func (h *FrozenBudgetAuditAdminHandler) SuccessResponse(c fiber.Ctx, status int, message string, data any) error {
+	return c.Status(status).JSON(dto.APIResponse{Success: true, Message: message, Data: data})
+}
+
+// Audit cross-checks frozen campaign budgets
+// @Summary Frozen Budget Audit (Admin)
+// @Description Cross-check every campaign's frozen budget against its transactions and its wallet's current frozen balance. A campaign waiting for approval must hold its latest reservation and every other campaign nothing; each wallet's frozen balance must equal what its campaign transactions hold. Lists every inconsistency with the campaign, reservation transaction and balance snapshot involved. Sandbox customers are skipped.
+// @Tags Payments Admin
+// @Produce json
+// @Success 200 {object} dto.APIResponse{data=dto.AdminFrozenBudgetAuditResponse}
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security AdminBearer
+// @Router /api/v1/admin/payments/frozen-budget-audit [get]
+func (h *FrozenBudgetAuditAdminHandler) Audit(c fiber.Ctx) error {
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/admin/payments/frozen-budget-audit", 2*time.Minute)
+	defer cancel()
+	res, err := h.flow.Audit(ctx)
+	if err != nil {
+		log.Println("Frozen budget audit failed:", err)
+		return h.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to audit frozen budgets", "FROZEN_BUDGET_AUDIT_FAILED", nil)
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, res.Message, res)
+}
+
+func (h *FrozenBudgetAuditAdminHandler) createRequestContextWithTimeout(c fiber.Ctx, endpoint string, timeout time.Duration) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	ctx = context.WithValue(ctx, utils.RequestIDKey, c.Get("X-Request-ID"))
+	ctx = context.WithValue(ctx, utils.UserAgentKey, c.Get("User-Agent"))
+	ctx = context.WithValue(ctx, utils.IPAddressKey, c.IP())
+	ctx = context.WithValue(ctx, utils.EndpointKey, endpoint)
+	ctx = context.WithValue(ctx, utils.TimeoutKey, timeout)
+	ctx = context.WithValue(ctx, utils.CancelFuncKey, cancel)
+	if adminID, ok := middleware.GetAdminIDFromContext(c); ok {
+		ctx = context.WithValue(ctx, utils.AdminIDKey, adminID)
+	}
+	return ctx, cancel
+}
@@ -2,40 +2,62 @@
 package handlers
 
 import (
-	"fmt"
-
+	"github.com/amirphl/Yamata-no-Orochi/app/apierror"
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/app/i18n"
+	businessflow "github.com/amirphl/Yamata-no-Orochi/business_flow"
 	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v3"
 )
 
-func getValidationErrorMessage(err validator.FieldError) string {
-	switch err.Tag() {
-	case "required":
-		return err.Field() + " is required"
-	case "email":
-		return "Invalid email format"
-	case "min":
-		return err.Field() + " must be at least " + err.Param() + " characters"
-	case "max":
-		return err.Field() + " must be at most " + err.Param() + " characters"
-	case "len":
-		return err.Field() + " must be exactly " + err.Param() + " characters"
-	case "oneof":
-		return err.Field() + " must be one of: " + err.Param()
-	case "eqfield":
-		return err.Field() + " must match " + err.Param()
-	case "alpha_space":
-		return err.Field() + " must contain only letters and spaces"
-	case "mobile_format":
-		return "Mobile number must be in format +989xxxxxxxxx"
-	case "password_strength":
-		return "Password must contain at least 1 uppercase letter and 1 number"
-	case "numeric":
-		return err.Field() + " must contain only numbers"
-	case "gte":
-		return fmt.Sprintf("%s must be greater than or equal to %s", err.Field(), err.Param())
-	case "lte":
-		return fmt.Sprintf("%s must be less than or equal to %s", err.Field(), err.Param())
-	default:
-		return err.Field() + " is invalid"
+// validationMessageTags are the validator tags with a message of their own in
+// the i18n catalogs (validation.<tag>); other tags use validation.invalid
+var validationMessageTags = map[string]bool{
+	"required":      true,
+	"email":         true,
+	"min":           true,
+	"max":           true,
+	"len":           true,
+	"oneof":         true,
+	"eqfield":       true,
+	"alpha_space":   true,
+	"mobile_format": true,
+	"numeric":       true,
+	"gte":           true,
+	"lte":           true,
+}
+
+// getValidationErrorMessage describes a failed validation in the language of the request
+func getValidationErrorMessage(c fiber.Ctx, err validator.FieldError) string {
+	key := "validation.invalid"
+	if validationMessageTags[err.Tag()] {
+		key = "validation." + err.Tag()
+	}
+	return i18n.Message(i18n.RequestLocale(c), key, i18n.Args{"Field": err.Field(), "Param": err.Param()})
+}
+
+// customerSuspended responds to a request blocked by the customer's
+// suspension with its level and reason code
+func customerSuspended(c fiber.Ctx, err error) error {
+	var details *dto.CustomerSuspensionDTO
+	if suspended, ok := businessflow.CustomerSuspension(err); ok {
+		details = &dto.CustomerSuspensionDTO{Level: string(suspended.Level), Reason: string(suspended.Reason)}
+	}
+	return apierror.Respond(c, fiber.StatusForbidden, "Your account is suspended", "CUSTOMER_SUSPENDED", details)
+}
+
+// passwordRejected responds to a new password refused by the password policy
+// with every reason and its message in the language of the request
+func passwordRejected(c fiber.Ctx, err error) error {
+	var details []dto.PasswordRejectionDTO
+	if rejected, ok := businessflow.PasswordRejection(err); ok {
+		locale := i18n.RequestLocale(c)
+		for _, reason := range rejected.Reasons {
+			details = append(details, dto.PasswordRejectionDTO{
+				Reason:  reason,
+				Message: i18n.Message(locale, "password."+reason, i18n.Args{"Min": rejected.MinLength, "Max": rejected.MaxLength}),
+			})
+		}
 	}
+	return apierror.Respond(c, fiber.StatusBadRequest, "Password does not meet the password policy", "PASSWORD_REJECTED", details)
 }
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"github.com/amirphl/Yamata-no-Orochi/app/apierror"
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/app/health"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/gofiber/fiber/v3"
+)
+
+// HealthHandlerInterface defines the probe endpoints used by Kubernetes and
+// the load balancer
+type HealthHandlerInterface interface {
+	Liveness(c fiber.Ctx) error
+	Readiness(c fiber.Ctx) error
+	Startup(c fiber.Ctx) error
+	Status(c fiber.Ctx) error
+}
+
+// HealthHandler serves the liveness, readiness and startup probes
+type HealthHandler struct {
+	checker *health.Checker
+	// gateways are the payment gateways reported by Status
+	gateways []string
+}
+
+func NewHealthHandler(checker *health.Checker, gateways []string) HealthHandlerInterface {
+	return &HealthHandler{checker: checker, gateways: gateways}
+}
+
+func (h *HealthHandler) ErrorResponse(c fiber.Ctx, status int, message, code string, details any) error {
+	return apierror.Respond(c, status, message, code, details)
+}
+
+func (h *HealthHandler) SuccessResponse(c fiber.Ctx, status int, message string, data any) error {
+	return c.Status(status).JSON(dto.APIResponse{Success: true, Message: message, Data: data})
+}
+
+// Liveness reports that the process is running; it checks no dependencies,
+// so an outage elsewhere never gets the instance restarted
+// @Summary Liveness probe
+// @Tags Health
+// @Produce json
+// @Success 200 {object} dto.APIResponse "Process is alive"
+// @Router /healthz [get]
+func (h *HealthHandler) Liveness(c fiber.Ctx) error {
+	return h.SuccessResponse(c, fiber.StatusOK, "Service is alive", fiber.Map{
+		"status":    health.StatusOK,
+		"timestamp": utils.UTCNowUnix(),
+	})
+}
+
+// Readiness reports whether the instance should receive traffic: the
+// database and Redis answer and the server is not shutting down. External
+// providers are reported but only degrade the status.
+// @Summary Readiness probe
+// @Description Pings the database and Redis on every call and reports the reachability of external providers, cached for a short time. Returns 503 while a critical dependency fails or the server is shutting down.
+// @Tags Health
+// @Produce json
+// @Success 200 {object} dto.APIResponse{data=health.Report} "Ready, possibly degraded"
+// @Failure 503 {object} dto.APIResponse "Not ready; error.details holds the report"
+// @Router /readyz [get]
+func (h *HealthHandler) Readiness(c fiber.Ctx) error {
+	if h.checker.Draining() {
+		return h.ErrorResponse(c, fiber.StatusServiceUnavailable, "Service is shutting down", "SERVICE_SHUTTING_DOWN", nil)
+	}
+
+	report := h.checker.Readiness(c.Context())
+	if !report.Ready() {
+		return h.ErrorResponse(c, fiber.StatusServiceUnavailable, "Service is not ready", "SERVICE_NOT_READY", report)
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, "Service is ready", report)
+}
+
+// Startup reports whether initialization finished; Kubernetes holds the
+// other probes until it succeeds
+// @Summary Startup probe
+// @Tags Health
+// @Produce json
+// @Success 200 {object} dto.APIResponse "Started"
+// @Failure 503 {object} dto.APIResponse "Still starting"
+// @Router /startupz [get]
+func (h *HealthHandler) Startup(c fiber.Ctx) error {
+	if !h.checker.Started() {
+		return h.ErrorResponse(c, fiber.StatusServiceUnavailable, "Service is starting", "SERVICE_STARTING", nil)
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, "Service has started", fiber.Map{
+		"status": health.StatusOK,
+	})
+}
+
+// Status reports whether the payment gateways are accepting payments, so
+// clients can hide or disable payment options during an outage
+// @Summary Payment gateway status
+// @Description Reports each payment gateway as ok or unavailable from the circuit breaker of its outbound client. A gateway is unavailable after repeated failures until a trial request succeeds; charges through it fail with PAYMENT_GATEWAY_UNAVAILABLE meanwhile.
+// @Tags Health
+// @Produce json
+// @Success 200 {object} dto.APIResponse{data=health.GatewayReport} "Gateway status"
+// @Router /api/v1/status [get]
+// @Router /api/v2/status [get]
+func (h *HealthHandler) Status(c fiber.Ctx) error {
+	report := health.Gateways(h.gateways...)
+	message := "All payment gateways are available"
+	if report.Status != health.StatusOK {
+		message = "Some payment gateways are unavailable"
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, message, report)
+}
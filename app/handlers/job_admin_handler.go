@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/apierror"
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/app/middleware"
+	businessflow "github.com/amirphl/Yamata-no-Orochi/business_flow"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+)
+
+// JobAdminHandlerInterface defines admin endpoints for inspecting the
+// background job queue and requeueing or cancelling dead jobs
+type JobAdminHandlerInterface interface {
+	List(c fiber.Ctx) error
+	Get(c fiber.Ctx) error
+	Requeue(c fiber.Ctx) error
+	Cancel(c fiber.Ctx) error
+}
+
+// JobAdminHandler implements the background job endpoints
+type JobAdminHandler struct {
+	flow      businessflow.JobQueueFlow
+	validator *validator.Validate
+}
+
+func NewJobAdminHandler(flow businessflow.JobQueueFlow) JobAdminHandlerInterface {
+	return &JobAdminHandler{
+		flow:      flow,
+		validator: validator.New(),
+	}
+}
+
+func (h *JobAdminHandler) ErrorResponse(c fiber.Ctx, status int, message, code string, details any) error {
+	return apierror.Respond(c, status, message, code, details)
+}
+
+func (h *JobAdminHandler) SuccessResponse(c fiber.Ctx, status int, message string, data any) error {
+	return c.Status(status).JSON(dto.APIResponse{Success: true, Message: message, Data: data})
+}
+
+// List returns background jobs
+// @Summary List Background Jobs (Admin)
+// @Description List queued background jobs such as SMS and email notifications, newest first. Filter by status=dead to find jobs that ran out of attempts.
+// @Tags Jobs Admin
+// @Produce json
+// @Param status query string false "Filter by status (pending|running|succeeded|dead|cancelled)"
+// @Param type query string false "Filter by job type, e.g. send_sms or send_sms_batch"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Page size" default(10) maximum(100)
+// @Success 200 {object} dto.APIResponse{data=dto.AdminListJobsResponse}
+// @Failure 400 {object} dto.APIResponse "Validation error"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security AdminBearer
+// @Router /api/v1/admin/jobs [get]
+func (h *JobAdminHandler) List(c fiber.Ctx) error {
+	page, err := strconv.Atoi(c.Query("page", "1"))
+	if err != nil || page <= 0 {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid page format", "INVALID_PAGE", nil)
+	}
+	limit, err := strconv.Atoi(c.Query("limit", "10"))
+	if err != nil || limit <= 0 {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid limit format", "INVALID_LIMIT", nil)
+	}
+
+	filter := dto.AdminListJobsFilter{Page: page, Limit: limit}
+	if status := strings.TrimSpace(c.Query("status")); status != "" {
+		filter.Status = &status
+	}
+	if jobType := strings.TrimSpace(c.Query("type")); jobType != "" {
+		filter.Type = &jobType
+	}
+	if err := h.validator.Struct(filter); err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", nil)
+	}
+
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/admin/jobs", 30*time.Second)
+	defer cancel()
+	res, err := h.flow.ListJobs(ctx, filter)
+	if err != nil {
+		return h.handleFlowError(c, "Failed to list jobs", "JOB_LIST_FAILED", err)
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, res.Message, res)
+}
+
+// Get returns a background job
+// @Summary Get Background Job (Admin)
+// @Description Get a background job with its payload and last error
+// @Tags Jobs Admin
+// @Produce json
+// @Param uuid path string true "Job UUID"
+// @Success 200 {object} dto.APIResponse{data=dto.AdminJobResponse}
+// @Failure 400 {object} dto.APIResponse "Invalid uuid"
+// @Failure 404 {object} dto.APIResponse "Job not found"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security AdminBearer
+// @Router /api/v1/admin/jobs/{uuid} [get]
+func (h *JobAdminHandler) Get(c fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("uuid"))
+	if err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid uuid", "INVALID_UUID", nil)
+	}
+
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/admin/jobs/:uuid", 30*time.Second)
+	defer cancel()
+	res, err := h.flow.GetJob(ctx, id)
+	if err != nil {
+		return h.handleFlowError(c, "Failed to get job", "JOB_LOOKUP_FAILED", err)
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, res.Message, res)
+}
+
+// Requeue gives a dead job a fresh set of attempts
+// @Summary Requeue Background Job (Admin)
+// @Description Move a dead job back to pending with its attempts reset, so it runs again as soon as a worker is free
+// @Tags Jobs Admin
+// @Produce json
+// @Param uuid path string true "Job UUID"
+// @Success 200 {object} dto.APIResponse{data=dto.AdminJobResponse}
+// @Failure 400 {object} dto.APIResponse "Invalid uuid"
+// @Failure 401 {object} dto.APIResponse "Unauthorized admin"
+// @Failure 404 {object} dto.APIResponse "Job not found"
+// @Failure 409 {object} dto.APIResponse "Job is not dead"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security AdminBearer
+// @Router /api/v1/admin/jobs/{uuid}/requeue [post]
+func (h *JobAdminHandler) Requeue(c fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("uuid"))
+	if err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid uuid", "INVALID_UUID", nil)
+	}
+
+	adminID, ok := middleware.GetAdminIDFromContext(c)
+	if !ok || adminID == 0 {
+		return h.ErrorResponse(c, fiber.StatusUnauthorized, "Admin ID not found in context", "MISSING_ADMIN_ID", nil)
+	}
+
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/admin/jobs/:uuid/requeue", 30*time.Second)
+	defer cancel()
+	res, err := h.flow.RequeueJob(ctx, id)
+	if err != nil {
+		return h.handleFlowError(c, "Failed to requeue job", "JOB_REQUEUE_FAILED", err)
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, res.Message, res)
+}
+
+// Cancel stops a pending or dead job from running again
+// @Summary Cancel Background Job (Admin)
+// @Description Cancel a pending or dead job, e.g. a dead-lettered SMS batch that must not be resent. Cancelled jobs are kept for inspection.
+// @Tags Jobs Admin
+// @Produce json
+// @Param uuid path string true "Job UUID"
+// @Success 200 {object} dto.APIResponse{data=dto.AdminJobResponse}
+// @Failure 400 {object} dto.APIResponse "Invalid uuid"
+// @Failure 401 {object} dto.APIResponse "Unauthorized admin"
+// @Failure 404 {object} dto.APIResponse "Job not found"
+// @Failure 409 {object} dto.APIResponse "Job is running, succeeded or already cancelled"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security AdminBearer
+// @Router /api/v1/admin/jobs/{uuid}/cancel [post]
+func (h *JobAdminHandler) Cancel(c fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("uuid"))
+	if err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid uuid", "INVALID_UUID", nil)
+	}
+
+	adminID, ok := middleware.GetAdminIDFromContext(c)
+	if !ok || adminID == 0 {
+		return h.ErrorResponse(c, fiber.StatusUnauthorized, "Admin ID not found in context", "MISSING_ADMIN_ID", nil)
+	}
+
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/admin/jobs/:uuid/cancel", 30*time.Second)
+	defer cancel()
+	res, err := h.flow.CancelJob(ctx, id)
+	if err != nil {
+		return h.handleFlowError(c, "Failed to cancel job", "JOB_CANCEL_FAILED", err)
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, res.Message, res)
+}
+
+func (h *JobAdminHandler) handleFlowError(c fiber.Ctx, message, code string, err error) error {
+	switch {
+	case businessflow.IsJobNotFound(err):
+		return h.ErrorResponse(c, fiber.StatusNotFound, "Job not found", "JOB_NOT_FOUND", nil)
+	case businessflow.IsJobNotRequeuable(err):
+		return h.ErrorResponse(c, fiber.StatusConflict, "Only dead jobs can be requeued", "JOB_NOT_REQUEUEABLE", nil)
+	case businessflow.IsJobNotCancellable(err):
+		return h.ErrorResponse(c, fiber.StatusConflict, "Only pending or dead jobs can be cancelled", "JOB_NOT_CANCELLABLE", nil)
+	}
+	log.Println(message+":", err)
+	return h.ErrorResponse(c, fiber.StatusInternalServerError, message, code, nil)
+}
+
+func (h *JobAdminHandler) createRequestContextWithTimeout(c fiber.Ctx, endpoint string, timeout time.Duration) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	ctx = context.WithValue(ctx, utils.RequestIDKey, c.Get("X-Request-ID"))
+	ctx = context.WithValue(ctx, utils.UserAgentKey, c.Get("User-Agent"))
+	ctx = context.WithValue(ctx, utils.IPAddressKey, c.IP())
+	ctx = context.WithValue(ctx, utils.EndpointKey, endpoint)
+	ctx = context.WithValue(ctx, utils.TimeoutKey, timeout)
+	ctx = context.WithValue(ctx, utils.CancelFuncKey, cancel)
+	if adminID, ok := middleware.GetAdminIDFromContext(c); ok {
+		ctx = context.WithValue(ctx, utils.AdminIDKey, adminID)
+	}
+	return ctx, cancel
+}
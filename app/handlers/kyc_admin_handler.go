@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/apierror"
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/app/middleware"
+	businessflow "github.com/amirphl/Yamata-no-Orochi/business_flow"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v3"
+)
+
+// KYCAdminHandlerInterface defines admin endpoints for reviewing company
+// verification documents
+type KYCAdminHandlerInterface interface {
+	List(c fiber.Ctx) error
+	GetCustomer(c fiber.Ctx) error
+	Review(c fiber.Ctx) error
+}
+
+// KYCAdminHandler implements the admin KYC endpoints
+type KYCAdminHandler struct {
+	flow      businessflow.KYCFlow
+	validator *validator.Validate
+}
+
+func NewKYCAdminHandler(flow businessflow.KYCFlow) KYCAdminHandlerInterface {
+	return &KYCAdminHandler{
+		flow:      flow,
+		validator: validator.New(),
+	}
+}
+
+func (h *KYCAdminHandler) ErrorResponse(c fiber.Ctx, status int, message, code string, details any) error {
+	return apierror.Respond(c, status, message, code, details)
+}
+
+func (h *KYCAdminHandler) SuccessResponse(c fiber.Ctx, status int, message string, data any) error {
+	return c.Status(status).JSON(dto.APIResponse{Success: true, Message: message, Data: data})
+}
+
+// List returns company customers by KYC status
+// @Summary List KYC Reviews (Admin)
+// @Description List company customers by KYC status, longest waiting first. Defaults to the customers waiting for review.
+// @Tags Admin KYC
+// @Produce json
+// @Param status query string false "Filter by status (pending_review|verified|rejected)" default(pending_review)
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Page size" default(10) maximum(100)
+// @Success 200 {object} dto.APIResponse{data=dto.AdminListKYCReviewsResponse}
+// @Failure 400 {object} dto.APIResponse "Validation error"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security AdminBearer
+// @Router /api/v1/admin/kyc [get]
+func (h *KYCAdminHandler) List(c fiber.Ctx) error {
+	page, err := strconv.Atoi(c.Query("page", "1"))
+	if err != nil || page <= 0 {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid page format", "INVALID_PAGE", nil)
+	}
+	limit, err := strconv.Atoi(c.Query("limit", "10"))
+	if err != nil || limit <= 0 {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid limit format", "INVALID_LIMIT", nil)
+	}
+
+	filter := dto.AdminListKYCReviewsFilter{Page: page, Limit: limit}
+	if status := strings.TrimSpace(c.Query("status")); status != "" {
+		filter.Status = &status
+	}
+	if err := h.validator.Struct(filter); err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", nil)
+	}
+
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/admin/kyc", 30*time.Second)
+	defer cancel()
+	res, err := h.flow.AdminList(ctx, filter)
+	if err != nil {
+		log.Println("Admin list KYC reviews failed:", err)
+		return h.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to list KYC reviews", "ADMIN_LIST_KYC_FAILED", nil)
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, res.Message, res)
+}
+
+// GetCustomer returns a customer's KYC state and documents
+// @Summary Get Customer KYC (Admin)
+// @Description Get a company customer's KYC state with short-lived download links to their current documents
+// @Tags Admin KYC
+// @Produce json
+// @Param customer_id path int true "Customer ID"
+// @Success 200 {object} dto.APIResponse{data=dto.AdminGetCustomerKYCResponse}
+// @Failure 400 {object} dto.APIResponse "Invalid customer ID or not a company account"
+// @Failure 404 {object} dto.APIResponse "Customer not found"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security AdminBearer
+// @Router /api/v1/admin/kyc/{customer_id} [get]
+func (h *KYCAdminHandler) GetCustomer(c fiber.Ctx) error {
+	cidStr := c.Params("customer_id")
+	cid, err := strconv.ParseUint(cidStr, 10, 64)
+	if err != nil || cid == 0 {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid customer_id", "VALIDATION_ERROR", nil)
+	}
+
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/admin/kyc/"+cidStr, 30*time.Second)
+	defer cancel()
+	res, err := h.flow.AdminGetCustomer(ctx, uint(cid))
+	if err != nil {
+		return h.handleError(c, "Admin get customer KYC failed:", err, "Failed to get customer KYC", "ADMIN_GET_KYC_FAILED")
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, res.Message, res)
+}
+
+// Review approves or rejects a customer's documents
+// @Summary Review Customer KYC (Admin)
+// @Description Approve or reject the documents a company customer submitted. Rejecting needs a reason, which is shown to the customer, and lets them replace their documents and submit again. A verified customer may also be rejected later. Approving lifts the sending quota cap applied to unverified companies.
+// @Tags Admin KYC
+// @Accept json
+// @Produce json
+// @Param customer_id path int true "Customer ID"
+// @Param request body dto.AdminReviewKYCRequest true "Review decision"
+// @Success 200 {object} dto.APIResponse{data=dto.AdminReviewKYCResponse}
+// @Failure 400 {object} dto.APIResponse "Validation error, missing rejection reason or not a company account"
+// @Failure 404 {object} dto.APIResponse "Customer not found"
+// @Failure 409 {object} dto.APIResponse "Documents are not waiting for this decision"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security AdminBearer
+// @Router /api/v1/admin/kyc/{customer_id}/review [post]
+func (h *KYCAdminHandler) Review(c fiber.Ctx) error {
+	cidStr := c.Params("customer_id")
+	cid, err := strconv.ParseUint(cidStr, 10, 64)
+	if err != nil || cid == 0 {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid customer_id", "VALIDATION_ERROR", nil)
+	}
+	var req dto.AdminReviewKYCRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body", "INVALID_REQUEST", err.Error())
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		var validationErrors []string
+		for _, err := range err.(validator.ValidationErrors) {
+			validationErrors = append(validationErrors, getValidationErrorMessage(c, err))
+		}
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", validationErrors)
+	}
+	req.CustomerID = uint(cid)
+
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/admin/kyc/"+cidStr+"/review", 30*time.Second)
+	defer cancel()
+	res, err := h.flow.AdminReview(ctx, &req)
+	if err != nil {
+		return h.handleError(c, "Admin review KYC failed:", err, "Failed to review KYC", "ADMIN_REVIEW_KYC_FAILED")
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, res.Message, res)
+}
+
+func (h *KYCAdminHandler) handleError(c fiber.Ctx, logPrefix string, err error, message, code string) error {
+	if resp, ok := kycErrorResponse(err); ok {
+		return h.ErrorResponse(c, resp.status, resp.message, resp.code, nil)
+	}
+	if businessflow.IsCustomerNotFound(err) {
+		return h.ErrorResponse(c, fiber.StatusNotFound, "Customer not found", "CUSTOMER_NOT_FOUND", nil)
+	}
+	log.Println(logPrefix, err)
+	return h.ErrorResponse(c, fiber.StatusInternalServerError, message, code, nil)
+}
+
+func (h *KYCAdminHandler) createRequestContextWithTimeout(c fiber.Ctx, endpoint string, timeout time.Duration) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	ctx = context.WithValue(ctx, utils.RequestIDKey, c.Get("X-Request-ID"))
+	ctx = context.WithValue(ctx, utils.UserAgentKey, c.Get("User-Agent"))
+	ctx = context.WithValue(ctx, utils.IPAddressKey, c.IP())
+	ctx = context.WithValue(ctx, utils.EndpointKey, endpoint)
+	ctx = context.WithValue(ctx, utils.TimeoutKey, timeout)
+	ctx = context.WithValue(ctx, utils.CancelFuncKey, cancel)
+	if adminID, ok := middleware.GetAdminIDFromContext(c); ok {
+		ctx = context.WithValue(ctx, utils.AdminIDKey, adminID)
+	}
+	return ctx, cancel
+}
@@ -0,0 +1,219 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/apierror"
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/app/middleware"
+	businessflow "github.com/amirphl/Yamata-no-Orochi/business_flow"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v3"
+)
+
+// KYCHandlerInterface defines the endpoints company customers verify their
+// company documents with
+type KYCHandlerInterface interface {
+	UploadDocument(c fiber.Ctx) error
+	GetStatus(c fiber.Ctx) error
+	Submit(c fiber.Ctx) error
+}
+
+// KYCHandler implements the customer KYC endpoints
+type KYCHandler struct {
+	flow      businessflow.KYCFlow
+	validator *validator.Validate
+}
+
+func NewKYCHandler(flow businessflow.KYCFlow) KYCHandlerInterface {
+	return &KYCHandler{
+		flow:      flow,
+		validator: validator.New(),
+	}
+}
+
+func (h *KYCHandler) ErrorResponse(c fiber.Ctx, status int, message, code string, details any) error {
+	return apierror.Respond(c, status, message, code, details)
+}
+
+func (h *KYCHandler) SuccessResponse(c fiber.Ctx, status int, message string, data any) error {
+	return c.Status(status).JSON(dto.APIResponse{Success: true, Message: message, Data: data})
+}
+
+// UploadDocument uploads a KYC document
+// @Summary Upload KYC Document
+// @Description Upload a company verification document as a JPEG, PNG or PDF of at most 5MB. The file is virus scanned before it is stored, and replaces the previous document of the same kind. Only company and agency accounts verify documents, and documents cannot change while they are under review or once they are verified.
+// @Tags KYC
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "Document file (JPEG, PNG or PDF)"
+// @Param kind formData string true "Document kind (registration_certificate|official_gazette|representative_id)"
+// @Success 201 {object} dto.APIResponse{data=dto.UploadKYCDocumentResponse}
+// @Failure 400 {object} dto.APIResponse "Validation error, not a company account, or file too large or of the wrong type"
+// @Failure 401 {object} dto.APIResponse "Unauthorized"
+// @Failure 409 {object} dto.APIResponse "Documents are under review or verified"
+// @Failure 422 {object} dto.APIResponse "File failed the virus scan"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Failure 503 {object} dto.APIResponse "Document uploads are not configured"
+// @Security CustomerBearer
+// @Router /api/v1/kyc/documents [post]
+func (h *KYCHandler) UploadDocument(c fiber.Ctx) error {
+	fileHeader, err := c.FormFile("file")
+	if err != nil || fileHeader == nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "file is required", "INVALID_REQUEST", nil)
+	}
+	req := dto.UploadKYCDocumentRequest{
+		Kind:     strings.TrimSpace(c.FormValue("kind")),
+		FileName: fileHeader.Filename,
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		var validationErrors []string
+		for _, err := range err.(validator.ValidationErrors) {
+			validationErrors = append(validationErrors, getValidationErrorMessage(c, err))
+		}
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", validationErrors)
+	}
+
+	customerID, ok := c.Locals("customer_id").(uint)
+	if !ok {
+		return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer ID not found in context", "MISSING_CUSTOMER_ID", nil)
+	}
+	req.CustomerID = customerID
+
+	fh, err := openFormFile(fileHeader)
+	if err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "invalid file", "INVALID_FILE", err.Error())
+	}
+	defer fh.Close()
+
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/kyc/documents", 60*time.Second)
+	defer cancel()
+	res, err := h.flow.UploadDocument(ctx, &req, fh, businessflow.NewClientMetadata(c.IP(), c.Get("User-Agent")))
+	if err != nil {
+		return h.handleError(c, "Upload KYC document failed:", err, "Failed to upload document", "KYC_UPLOAD_FAILED")
+	}
+	return h.SuccessResponse(c, fiber.StatusCreated, res.Message, res)
+}
+
+// GetStatus returns the customer's KYC state
+// @Summary Get KYC Status
+// @Description Get the customer's verification status, their current documents with short-lived download links, the required document kinds still missing, and the sending quota cap applied until the documents are verified
+// @Tags KYC
+// @Produce json
+// @Success 200 {object} dto.APIResponse{data=dto.KYCStatusResponse}
+// @Failure 400 {object} dto.APIResponse "Not a company account"
+// @Failure 401 {object} dto.APIResponse "Unauthorized"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
+// @Router /api/v1/kyc [get]
+func (h *KYCHandler) GetStatus(c fiber.Ctx) error {
+	customerID, ok := c.Locals("customer_id").(uint)
+	if !ok {
+		return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer ID not found in context", "MISSING_CUSTOMER_ID", nil)
+	}
+
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/kyc", 10*time.Second)
+	defer cancel()
+	res, err := h.flow.GetStatus(ctx, customerID)
+	if err != nil {
+		return h.handleError(c, "Get KYC status failed:", err, "Failed to get KYC status", "GET_KYC_STATUS_FAILED")
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, res.Message, res)
+}
+
+// Submit submits the customer's documents for review
+// @Summary Submit KYC Documents
+// @Description Submit the uploaded documents for review. The registration certificate and the representative's national ID are required. Documents are frozen until an admin approves or rejects them; after a rejection they can be replaced and submitted again.
+// @Tags KYC
+// @Produce json
+// @Success 200 {object} dto.APIResponse{data=dto.SubmitKYCResponse}
+// @Failure 400 {object} dto.APIResponse "Not a company account or required documents missing"
+// @Failure 401 {object} dto.APIResponse "Unauthorized"
+// @Failure 409 {object} dto.APIResponse "Already under review or verified"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
+// @Router /api/v1/kyc/submit [post]
+func (h *KYCHandler) Submit(c fiber.Ctx) error {
+	customerID, ok := c.Locals("customer_id").(uint)
+	if !ok {
+		return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer ID not found in context", "MISSING_CUSTOMER_ID", nil)
+	}
+
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/kyc/submit", 15*time.Second)
+	defer cancel()
+	res, err := h.flow.Submit(ctx, customerID, businessflow.NewClientMetadata(c.IP(), c.Get("User-Agent")))
+	if err != nil {
+		return h.handleError(c, "Submit KYC failed:", err, "Failed to submit documents", "SUBMIT_KYC_FAILED")
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, res.Message, res)
+}
+
+func (h *KYCHandler) handleError(c fiber.Ctx, logPrefix string, err error, message, code string) error {
+	if resp, ok := kycErrorResponse(err); ok {
+		return h.ErrorResponse(c, resp.status, resp.message, resp.code, nil)
+	}
+	switch {
+	case businessflow.IsCustomerNotFound(err):
+		return h.ErrorResponse(c, fiber.StatusNotFound, "Customer not found", "CUSTOMER_NOT_FOUND", nil)
+	case businessflow.IsAccountInactive(err):
+		return h.ErrorResponse(c, fiber.StatusForbidden, "Customer account is inactive", "ACCOUNT_INACTIVE", nil)
+	}
+	log.Println(logPrefix, err)
+	return h.ErrorResponse(c, fiber.StatusInternalServerError, message, code, nil)
+}
+
+type kycErrorResp struct {
+	status  int
+	message string
+	code    string
+}
+
+// kycErrorResponse maps the KYC flow errors shared by the customer and admin
+// endpoints
+func kycErrorResponse(err error) (kycErrorResp, bool) {
+	var be *businessflow.BusinessError
+	switch {
+	case businessflow.IsUploadsNotConfigured(err):
+		return kycErrorResp{fiber.StatusServiceUnavailable, "Document uploads are not configured", "UPLOADS_NOT_CONFIGURED"}, true
+	case businessflow.IsKYCNotApplicable(err):
+		return kycErrorResp{fiber.StatusBadRequest, "Only company accounts verify documents", "KYC_NOT_APPLICABLE"}, true
+	case businessflow.IsKYCDocumentInvalid(err):
+		return kycErrorResp{fiber.StatusBadRequest, kycErrorMessage(err), "KYC_DOCUMENT_INVALID"}, true
+	case businessflow.IsKYCDocumentInfected(err):
+		return kycErrorResp{fiber.StatusUnprocessableEntity, "Document failed the virus scan", "KYC_DOCUMENT_INFECTED"}, true
+	case businessflow.IsKYCDocumentsMissing(err):
+		return kycErrorResp{fiber.StatusBadRequest, kycErrorMessage(err), "KYC_DOCUMENTS_MISSING"}, true
+	case businessflow.IsKYCStatusConflict(err):
+		return kycErrorResp{fiber.StatusConflict, kycErrorMessage(err), "KYC_STATUS_CONFLICT"}, true
+	case businessflow.IsKYCRejectionReasonNeeded(err):
+		return kycErrorResp{fiber.StatusBadRequest, "A reason is required to reject documents", "KYC_REJECTION_REASON_REQUIRED"}, true
+	case errors.As(err, &be) && be.Code == "VALIDATION_ERROR":
+		return kycErrorResp{fiber.StatusBadRequest, be.Message, be.Code}, true
+	}
+	return kycErrorResp{}, false
+}
+
+func kycErrorMessage(err error) string {
+	var be *businessflow.BusinessError
+	if errors.As(err, &be) {
+		return be.Message
+	}
+	return err.Error()
+}
+
+func (h *KYCHandler) createRequestContextWithTimeout(c fiber.Ctx, endpoint string, timeout time.Duration) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	ctx = context.WithValue(ctx, utils.RequestIDKey, c.Get("X-Request-ID"))
+	ctx = context.WithValue(ctx, utils.UserAgentKey, c.Get("User-Agent"))
+	ctx = context.WithValue(ctx, utils.IPAddressKey, c.IP())
+	ctx = context.WithValue(ctx, utils.EndpointKey, endpoint)
+	ctx = context.WithValue(ctx, utils.TimeoutKey, timeout)
+	ctx = context.WithValue(ctx, utils.CancelFuncKey, cancel)
+	ctx = middleware.WithImpersonation(ctx, c)
+	return ctx, cancel
+}
@@ -4,8 +4,10 @@ package handlers
 import (
 	"context"
 	"log"
+	"strconv"
 	"time"
 
+	"github.com/amirphl/Yamata-no-Orochi/app/apierror"
 	"github.com/amirphl/Yamata-no-Orochi/app/dto"
 	"github.com/amirphl/Yamata-no-Orochi/app/middleware"
 	businessflow "github.com/amirphl/Yamata-no-Orochi/business_flow"
@@ -20,6 +22,9 @@ type LineNumberAdminHandlerInterface interface {
 	ListLineNumbers(c fiber.Ctx) error
 	UpdateLineNumbersBatch(c fiber.Ctx) error
 	GetLineNumbersReport(c fiber.Ctx) error
+	ListTiers(c fiber.Ctx) error
+	CreateTier(c fiber.Ctx) error
+	UpdateTier(c fiber.Ctx) error
 }
 
 // LineNumberAdminHandler implements admin line number endpoints
@@ -36,7 +41,7 @@ func NewLineNumberAdminHandler(flow businessflow.AdminLineNumberFlow) LineNumber
 }
 
 func (h *LineNumberAdminHandler) ErrorResponse(c fiber.Ctx, status int, message, code string, details any) error {
-	return c.Status(status).JSON(dto.APIResponse{Success: false, Message: message, Error: dto.ErrorDetail{Code: code, Details: details}})
+	return apierror.Respond(c, status, message, code, details)
 }
 
 func (h *LineNumberAdminHandler) SuccessResponse(c fiber.Ctx, status int, message string, data any) error {
@@ -45,14 +50,16 @@ func (h *LineNumberAdminHandler) SuccessResponse(c fiber.Ctx, status int, messag
 
 // CreateLineNumber creates a new line number or updates an existing one (admin only)
 // @Summary Create Line Number (Admin)
-// @Description Create a line number with name (optional), unique value, price factor, priority (optional), and is_active (optional). If the line number already exists, its mutable fields are updated.
+// @Description Create a line number with name (optional), unique value, price factor, priority (optional), is_active (optional), and certified_operators (optional; mci, mtn, rightel; empty serves every operator). If the line number already exists, its mutable fields are updated.
 // @Tags Admin Line Numbers
 // @Accept json
 // @Produce json
 // @Param request body dto.AdminCreateLineNumberRequest true "Create line number payload"
 // @Success 200 {object} dto.APIResponse{data=dto.AdminLineNumberDTO}
 // @Failure 400 {object} dto.APIResponse "Validation error"
+// @Failure 409 {object} dto.APIResponse "Line number is soft-deleted"
 // @Failure 500 {object} dto.APIResponse "Create or update failed"
+// @Security AdminBearer
 // @Router /api/v1/admin/line-numbers/ [post]
 func (h *LineNumberAdminHandler) CreateLineNumber(c fiber.Ctx) error {
 	var req dto.AdminCreateLineNumberRequest
@@ -77,6 +84,9 @@ func (h *LineNumberAdminHandler) CreateLineNumber(c fiber.Ctx) error {
 		if businessflow.IsPriceFactorInvalid(err) {
 			return h.ErrorResponse(c, fiber.StatusBadRequest, "Price factor must be greater than zero", "PRICE_FACTOR_INVALID", nil)
 		}
+		if businessflow.IsLineNumberDeleted(err) {
+			return h.ErrorResponse(c, fiber.StatusConflict, "Line number is deleted; restore it instead", "LINE_NUMBER_DELETED", nil)
+		}
 		log.Println("Create line number failed:", err)
 		return h.ErrorResponse(c, fiber.StatusInternalServerError, "Create or update line number failed", "LINE_NUMBER_CREATE_FAILED", nil)
 	}
@@ -85,17 +95,20 @@ func (h *LineNumberAdminHandler) CreateLineNumber(c fiber.Ctx) error {
 
 // ListLineNumbers returns all line numbers (admin)
 // @Summary List Line Numbers (Admin)
-// @Description Retrieve all line numbers
+// @Description Retrieve all line numbers; soft-deleted ones are included with include_deleted=true
 // @Tags Admin Line Numbers
 // @Produce json
+// @Param include_deleted query bool false "Include soft-deleted line numbers"
 // @Success 200 {object} dto.APIResponse{data=[]dto.AdminLineNumberDTO}
 // @Failure 500 {object} dto.APIResponse "List failed"
+// @Security AdminBearer
 // @Router /api/v1/admin/line-numbers/ [get]
 func (h *LineNumberAdminHandler) ListLineNumbers(c fiber.Ctx) error {
 	metadata := businessflow.NewClientMetadata(c.IP(), c.Get("User-Agent"))
 	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/admin/line-numbers/", 30*time.Second)
 	defer cancel()
-	res, err := h.flow.ListAll(ctx, metadata)
+	includeDeleted := c.Query("include_deleted") == "true"
+	res, err := h.flow.ListAll(ctx, includeDeleted, metadata)
 	if err != nil {
 		log.Println("List line numbers failed:", err)
 		return h.ErrorResponse(c, fiber.StatusInternalServerError, "List line numbers failed", "LINE_NUMBER_LIST_FAILED", nil)
@@ -113,6 +126,7 @@ func (h *LineNumberAdminHandler) ListLineNumbers(c fiber.Ctx) error {
 // @Success 200 {object} dto.APIResponse{data=object{updated=bool}}
 // @Failure 400 {object} dto.APIResponse "Validation error"
 // @Failure 500 {object} dto.APIResponse "Update failed"
+// @Security AdminBearer
 // @Router /api/v1/admin/line-numbers/ [put]
 func (h *LineNumberAdminHandler) UpdateLineNumbersBatch(c fiber.Ctx) error {
 	var req dto.AdminUpdateLineNumbersRequest
@@ -152,6 +166,7 @@ func (h *LineNumberAdminHandler) UpdateLineNumbersBatch(c fiber.Ctx) error {
 // @Produce json
 // @Success 200 {object} dto.APIResponse{data=[]dto.AdminLineNumberReportItem}
 // @Failure 500 {object} dto.APIResponse "Report generation failed"
+// @Security AdminBearer
 // @Router /api/v1/admin/line-numbers/report [get]
 func (h *LineNumberAdminHandler) GetLineNumbersReport(c fiber.Ctx) error {
 	metadata := businessflow.NewClientMetadata(c.IP(), c.Get("User-Agent"))
@@ -165,6 +180,115 @@ func (h *LineNumberAdminHandler) GetLineNumbersReport(c fiber.Ctx) error {
 	return h.SuccessResponse(c, fiber.StatusOK, "Line numbers report", items)
 }
 
+// ListTiers returns the reservation pricing of line number tiers (admin)
+// @Summary List Line Number Tiers (Admin)
+// @Tags Admin Line Numbers
+// @Produce json
+// @Success 200 {object} dto.APIResponse{data=[]dto.AdminLineNumberTierDTO}
+// @Failure 500 {object} dto.APIResponse "List failed"
+// @Security AdminBearer
+// @Router /api/v1/admin/line-numbers/tiers [get]
+func (h *LineNumberAdminHandler) ListTiers(c fiber.Ctx) error {
+	metadata := businessflow.NewClientMetadata(c.IP(), c.Get("User-Agent"))
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/admin/line-numbers/tiers", 30*time.Second)
+	defer cancel()
+	res, err := h.flow.ListTiers(ctx, metadata)
+	if err != nil {
+		log.Println("List line number tiers failed:", err)
+		return h.ErrorResponse(c, fiber.StatusInternalServerError, "List line number tiers failed", "LINE_NUMBER_TIER_LIST_FAILED", nil)
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, "Line number tiers retrieved", res)
+}
+
+// CreateTier creates the reservation pricing of a line number tier (admin)
+// @Summary Create Line Number Tier (Admin)
+// @Description The tier name must match the tier of the line numbers it prices
+// @Tags Admin Line Numbers
+// @Accept json
+// @Produce json
+// @Param request body dto.AdminCreateLineNumberTierRequest true "Create tier payload"
+// @Success 201 {object} dto.APIResponse{data=dto.AdminLineNumberTierDTO}
+// @Failure 400 {object} dto.APIResponse "Validation error"
+// @Failure 409 {object} dto.APIResponse "Tier already exists"
+// @Failure 500 {object} dto.APIResponse "Create failed"
+// @Security AdminBearer
+// @Router /api/v1/admin/line-numbers/tiers [post]
+func (h *LineNumberAdminHandler) CreateTier(c fiber.Ctx) error {
+	var req dto.AdminCreateLineNumberTierRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body", "INVALID_REQUEST", err.Error())
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		var validationErrors []string
+		for _, e := range err.(validator.ValidationErrors) {
+			validationErrors = append(validationErrors, e.Error())
+		}
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", validationErrors)
+	}
+	metadata := businessflow.NewClientMetadata(c.IP(), c.Get("User-Agent"))
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/admin/line-numbers/tiers", 30*time.Second)
+	defer cancel()
+	res, err := h.flow.CreateTier(ctx, &req, metadata)
+	if err != nil {
+		return h.handleTierError(c, err, "Create line number tier failed", "LINE_NUMBER_TIER_CREATE_FAILED")
+	}
+	return h.SuccessResponse(c, fiber.StatusCreated, "Line number tier created", res)
+}
+
+// UpdateTier updates the reservation pricing of a line number tier (admin)
+// @Summary Update Line Number Tier (Admin)
+// @Description Omitted fields are kept; existing reservations keep the price they were charged
+// @Tags Admin Line Numbers
+// @Accept json
+// @Produce json
+// @Param id path int true "Tier ID"
+// @Param request body dto.AdminUpdateLineNumberTierRequest true "Update tier payload"
+// @Success 200 {object} dto.APIResponse{data=dto.AdminLineNumberTierDTO}
+// @Failure 400 {object} dto.APIResponse "Validation error"
+// @Failure 404 {object} dto.APIResponse "Tier not found"
+// @Failure 500 {object} dto.APIResponse "Update failed"
+// @Security AdminBearer
+// @Router /api/v1/admin/line-numbers/tiers/{id} [put]
+func (h *LineNumberAdminHandler) UpdateTier(c fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil || id == 0 {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid tier id", "INVALID_TIER_ID", nil)
+	}
+	var req dto.AdminUpdateLineNumberTierRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body", "INVALID_REQUEST", err.Error())
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		var validationErrors []string
+		for _, e := range err.(validator.ValidationErrors) {
+			validationErrors = append(validationErrors, e.Error())
+		}
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", validationErrors)
+	}
+	req.ID = uint(id)
+	metadata := businessflow.NewClientMetadata(c.IP(), c.Get("User-Agent"))
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/admin/line-numbers/tiers/:id", 30*time.Second)
+	defer cancel()
+	res, err := h.flow.UpdateTier(ctx, &req, metadata)
+	if err != nil {
+		return h.handleTierError(c, err, "Update line number tier failed", "LINE_NUMBER_TIER_UPDATE_FAILED")
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, "Line number tier updated", res)
+}
+
+func (h *LineNumberAdminHandler) handleTierError(c fiber.Ctx, err error, defaultMessage, defaultCode string) error {
+	switch {
+	case businessflow.IsLineNumberTierNotFound(err):
+		return h.ErrorResponse(c, fiber.StatusNotFound, "Line number tier not found", "LINE_NUMBER_TIER_NOT_FOUND", nil)
+	case businessflow.IsLineNumberTierAlreadyExists(err):
+		return h.ErrorResponse(c, fiber.StatusConflict, "Line number tier already exists", "LINE_NUMBER_TIER_ALREADY_EXISTS", nil)
+	case businessflow.IsLineNumberTierMaxDaysInvalid(err):
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Max reservation days must be greater than zero", "LINE_NUMBER_TIER_MAX_DAYS_INVALID", nil)
+	}
+	log.Println(defaultMessage+":", err)
+	return h.ErrorResponse(c, fiber.StatusInternalServerError, defaultMessage, defaultCode, nil)
+}
+
 func (h *LineNumberAdminHandler) createRequestContextWithTimeout(c fiber.Ctx, endpoint string, timeout time.Duration) (context.Context, context.CancelFunc) {
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	ctx = context.WithValue(ctx, utils.RequestIDKey, c.Get("X-Request-ID"))
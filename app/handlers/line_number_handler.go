@@ -5,30 +5,37 @@ import (
 	"log"
 	"time"
 
+	"github.com/amirphl/Yamata-no-Orochi/app/apierror"
 	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/app/middleware"
 	businessflow "github.com/amirphl/Yamata-no-Orochi/business_flow"
 	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v3"
 )
 
 type LineNumberHandlerInterface interface {
 	ListActive(c fiber.Ctx) error
+	ListAvailable(c fiber.Ctx) error
+	Reserve(c fiber.Ctx) error
+	ListReservations(c fiber.Ctx) error
+	ReleaseReservation(c fiber.Ctx) error
 }
 
 type LineNumberHandler struct {
-	flow businessflow.LineNumberFlow
+	flow      businessflow.LineNumberFlow
+	validator *validator.Validate
 }
 
 func NewLineNumberHandler(flow businessflow.LineNumberFlow) LineNumberHandlerInterface {
-	return &LineNumberHandler{flow: flow}
+	return &LineNumberHandler{
+		flow:      flow,
+		validator: validator.New(),
+	}
 }
 
 func (h *LineNumberHandler) ErrorResponse(c fiber.Ctx, statusCode int, message, errorCode string, details any) error {
-	return c.Status(statusCode).JSON(dto.APIResponse{
-		Success: false,
-		Message: message,
-		Error:   dto.ErrorDetail{Code: errorCode, Details: details},
-	})
+	return apierror.Respond(c, statusCode, message, errorCode, details)
 }
 
 func (h *LineNumberHandler) SuccessResponse(c fiber.Ctx, statusCode int, message string, data any) error {
@@ -40,6 +47,7 @@ func (h *LineNumberHandler) SuccessResponse(c fiber.Ctx, statusCode int, message
 // @Tags Line Numbers
 // @Produce json
 // @Success 200 {object} dto.APIResponse{data=dto.ListActiveLineNumbersResponse}
+// @Security CustomerBearer
 // @Router /api/v1/line-numbers/active [get]
 func (h *LineNumberHandler) ListActive(c fiber.Ctx) error {
 	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/line-numbers/active", 30*time.Second)
@@ -53,6 +61,155 @@ func (h *LineNumberHandler) ListActive(c fiber.Ctx) error {
 	return h.SuccessResponse(c, fiber.StatusOK, "Active line numbers retrieved", res)
 }
 
+// ListAvailable returns line numbers the customer can send from with tier pricing
+// @Summary List Available Line Numbers
+// @Description Active line numbers not reserved by other customers, with the reservation price of their tier
+// @Tags Line Numbers
+// @Produce json
+// @Success 200 {object} dto.APIResponse{data=dto.ListAvailableLineNumbersResponse}
+// @Failure 401 {object} dto.APIResponse "Unauthorized"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
+// @Router /api/v1/line-numbers/available [get]
+func (h *LineNumberHandler) ListAvailable(c fiber.Ctx) error {
+	customerID, ok := c.Locals("customer_id").(uint)
+	if !ok {
+		return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer ID not found in context", "MISSING_CUSTOMER_ID", nil)
+	}
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/line-numbers/available", 30*time.Second)
+	defer cancel()
+	metadata := businessflow.NewClientMetadata(c.IP(), c.Get("User-Agent"))
+	res, err := h.flow.ListAvailableLineNumbers(ctx, customerID, metadata)
+	if err != nil {
+		log.Println("List available line numbers failed", err)
+		return h.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to list available line numbers", "LIST_AVAILABLE_LINE_NUMBERS_FAILED", nil)
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, res.Message, res)
+}
+
+// Reserve reserves a line number for the customer's exclusive use
+// @Summary Reserve Line Number
+// @Description Reserve a line number for a number of days; the tier price is debited from the wallet
+// @Tags Line Numbers
+// @Accept json
+// @Produce json
+// @Param request body dto.ReserveLineNumberRequest true "Reservation payload"
+// @Success 201 {object} dto.APIResponse{data=dto.LineNumberReservationResponse}
+// @Failure 400 {object} dto.APIResponse "Validation error"
+// @Failure 403 {object} dto.APIResponse "Sandbox account, or impersonating the customer"
+// @Failure 404 {object} dto.APIResponse "Line number not found"
+// @Failure 409 {object} dto.APIResponse "Line number already reserved or insufficient funds"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
+// @Router /api/v1/line-numbers/reservations [post]
+func (h *LineNumberHandler) Reserve(c fiber.Ctx) error {
+	customerID, ok := c.Locals("customer_id").(uint)
+	if !ok {
+		return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer ID not found in context", "MISSING_CUSTOMER_ID", nil)
+	}
+	var req dto.ReserveLineNumberRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body", "INVALID_REQUEST", err.Error())
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		var validationErrors []string
+		for _, e := range err.(validator.ValidationErrors) {
+			validationErrors = append(validationErrors, e.Error())
+		}
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", validationErrors)
+	}
+	req.CustomerID = customerID
+
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/line-numbers/reservations", 30*time.Second)
+	defer cancel()
+	metadata := businessflow.NewClientMetadata(c.IP(), c.Get("User-Agent"))
+	res, err := h.flow.ReserveLineNumber(ctx, &req, metadata)
+	if err != nil {
+		return h.handleReservationError(c, err, "Failed to reserve line number", "RESERVE_LINE_NUMBER_FAILED")
+	}
+	return h.SuccessResponse(c, fiber.StatusCreated, res.Message, res)
+}
+
+// ListReservations returns the customer's line number reservations
+// @Summary List Line Number Reservations
+// @Tags Line Numbers
+// @Produce json
+// @Success 200 {object} dto.APIResponse{data=dto.ListLineNumberReservationsResponse}
+// @Failure 401 {object} dto.APIResponse "Unauthorized"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
+// @Router /api/v1/line-numbers/reservations [get]
+func (h *LineNumberHandler) ListReservations(c fiber.Ctx) error {
+	customerID, ok := c.Locals("customer_id").(uint)
+	if !ok {
+		return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer ID not found in context", "MISSING_CUSTOMER_ID", nil)
+	}
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/line-numbers/reservations", 30*time.Second)
+	defer cancel()
+	metadata := businessflow.NewClientMetadata(c.IP(), c.Get("User-Agent"))
+	res, err := h.flow.ListReservations(ctx, customerID, metadata)
+	if err != nil {
+		log.Println("List line number reservations failed", err)
+		return h.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to list line number reservations", "LIST_LINE_NUMBER_RESERVATIONS_FAILED", nil)
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, res.Message, res)
+}
+
+// ReleaseReservation ends an active reservation early; the fee is not refunded
+// @Summary Release Line Number Reservation
+// @Tags Line Numbers
+// @Produce json
+// @Param uuid path string true "Reservation UUID"
+// @Success 200 {object} dto.APIResponse{data=dto.LineNumberReservationResponse}
+// @Failure 404 {object} dto.APIResponse "Reservation not found"
+// @Failure 409 {object} dto.APIResponse "Reservation not active"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
+// @Router /api/v1/line-numbers/reservations/{uuid} [delete]
+func (h *LineNumberHandler) ReleaseReservation(c fiber.Ctx) error {
+	customerID, ok := c.Locals("customer_id").(uint)
+	if !ok {
+		return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer ID not found in context", "MISSING_CUSTOMER_ID", nil)
+	}
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/line-numbers/reservations/:uuid", 30*time.Second)
+	defer cancel()
+	metadata := businessflow.NewClientMetadata(c.IP(), c.Get("User-Agent"))
+	res, err := h.flow.ReleaseReservation(ctx, customerID, c.Params("uuid"), metadata)
+	if err != nil {
+		return h.handleReservationError(c, err, "Failed to release line number reservation", "RELEASE_LINE_NUMBER_RESERVATION_FAILED")
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, res.Message, res)
+}
+
+func (h *LineNumberHandler) handleReservationError(c fiber.Ctx, err error, defaultMessage, defaultCode string) error {
+	switch {
+	case businessflow.IsLineNumberNotFound(err):
+		return h.ErrorResponse(c, fiber.StatusNotFound, "Line number not found", "LINE_NUMBER_NOT_FOUND", nil)
+	case businessflow.IsLineNumberNotActive(err):
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Line number is not active", "LINE_NUMBER_NOT_ACTIVE", nil)
+	case businessflow.IsLineNumberNotReservable(err):
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Line number cannot be reserved", "LINE_NUMBER_NOT_RESERVABLE", nil)
+	case businessflow.IsLineNumberReservationDaysInvalid(err):
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid reservation days", "LINE_NUMBER_RESERVATION_DAYS_INVALID", businessErrorMessage(err))
+	case businessflow.IsLineNumberReserved(err):
+		return h.ErrorResponse(c, fiber.StatusConflict, "Line number is reserved by another customer", "LINE_NUMBER_RESERVED", nil)
+	case businessflow.IsLineNumberAlreadyReserved(err):
+		return h.ErrorResponse(c, fiber.StatusConflict, "Line number is already reserved by you", "LINE_NUMBER_ALREADY_RESERVED", nil)
+	case businessflow.IsInsufficientFunds(err):
+		return h.ErrorResponse(c, fiber.StatusConflict, "Insufficient funds", "INSUFFICIENT_FUNDS", nil)
+	case businessflow.IsSandboxRealPayment(err):
+		return h.ErrorResponse(c, fiber.StatusForbidden, "Sandbox accounts cannot make real payments", "SANDBOX_REAL_PAYMENT", nil)
+	case businessflow.IsLineNumberReservationNotFound(err):
+		return h.ErrorResponse(c, fiber.StatusNotFound, "Line number reservation not found", "LINE_NUMBER_RESERVATION_NOT_FOUND", nil)
+	case businessflow.IsLineNumberReservationNotActive(err):
+		return h.ErrorResponse(c, fiber.StatusConflict, "Line number reservation is not active", "LINE_NUMBER_RESERVATION_NOT_ACTIVE", nil)
+	case businessflow.IsWalletNotFound(err):
+		return h.ErrorResponse(c, fiber.StatusNotFound, "Wallet not found", "WALLET_NOT_FOUND", nil)
+	}
+	log.Println(defaultMessage, err)
+	return h.ErrorResponse(c, fiber.StatusInternalServerError, defaultMessage, defaultCode, nil)
+}
+
 func (h *LineNumberHandler) createRequestContextWithTimeout(c fiber.Ctx, endpoint string, timeout time.Duration) (context.Context, context.CancelFunc) {
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	ctx = context.WithValue(ctx, utils.RequestIDKey, c.Get("X-Request-ID"))
@@ -61,5 +218,6 @@ func (h *LineNumberHandler) createRequestContextWithTimeout(c fiber.Ctx, endpoin
 	ctx = context.WithValue(ctx, utils.EndpointKey, endpoint)
 	ctx = context.WithValue(ctx, utils.TimeoutKey, timeout)
 	ctx = context.WithValue(ctx, utils.CancelFuncKey, cancel)
+	ctx = middleware.WithImpersonation(ctx, c)
 	return ctx, cancel
 }
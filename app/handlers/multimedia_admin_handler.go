@@ -5,6 +5,7 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/amirphl/Yamata-no-Orochi/app/apierror"
 	"github.com/amirphl/Yamata-no-Orochi/app/dto"
 	"github.com/amirphl/Yamata-no-Orochi/app/middleware"
 	businessflow "github.com/amirphl/Yamata-no-Orochi/business_flow"
@@ -30,14 +31,7 @@ func NewMultimediaAdminHandler(flow businessflow.MultimediaAdminFlow) Multimedia
 }
 
 func (h *MultimediaAdminHandler) ErrorResponse(c fiber.Ctx, statusCode int, message, errorCode string, details any) error {
-	return c.Status(statusCode).JSON(dto.APIResponse{
-		Success: false,
-		Message: message,
-		Error: dto.ErrorDetail{
-			Code:    errorCode,
-			Details: details,
-		},
-	})
+	return apierror.Respond(c, statusCode, message, errorCode, details)
 }
 
 func (h *MultimediaAdminHandler) SuccessResponse(c fiber.Ctx, statusCode int, message string, data any) error {
@@ -52,7 +46,6 @@ func (h *MultimediaAdminHandler) SuccessResponse(c fiber.Ctx, statusCode int, me
 // @Summary Admin upload multimedia
 // @Description Upload an image, video, excel, or pdf file for a customer (jpg/jpeg/png/gif/webp/mp4/mov/webm/mkv/xlsx/xls/xlsm/pdf, <=100MB)
 // @Tags Admin Multimedia
-// @Security BearerAuth
 // @Accept mpfd
 // @Produce json
 // @Param customer_id formData int true "Customer ID"
@@ -62,6 +55,7 @@ func (h *MultimediaAdminHandler) SuccessResponse(c fiber.Ctx, statusCode int, me
 // @Failure 401 {object} dto.APIResponse "Unauthorized"
 // @Failure 404 {object} dto.APIResponse "Customer not found"
 // @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security AdminBearer
 // @Router /api/v1/admin/media/upload [post]
 func (h *MultimediaAdminHandler) Upload(c fiber.Ctx) error {
 	customerIDStr := c.FormValue("customer_id")
@@ -101,7 +95,7 @@ func (h *MultimediaAdminHandler) Upload(c fiber.Ctx) error {
 			return h.ErrorResponse(c, fiber.StatusNotFound, "Customer not found", "CUSTOMER_NOT_FOUND", nil)
 		}
 		if businessflow.IsAccountInactive(err) {
-			return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer account is inactive", "ACCOUNT_INACTIVE", nil)
+			return h.ErrorResponse(c, fiber.StatusForbidden, "Customer account is inactive", "ACCOUNT_INACTIVE", nil)
 		}
 		if be, ok := err.(*businessflow.BusinessError); ok {
 			switch be.Code {
@@ -125,7 +119,6 @@ func (h *MultimediaAdminHandler) Upload(c fiber.Ctx) error {
 // @Summary Admin download multimedia
 // @Description Download a multimedia file (image/video/excel/pdf) by uuid (admin access)
 // @Tags Admin Multimedia
-// @Security BearerAuth
 // @Produce application/octet-stream
 // @Param uuid path string true "Multimedia UUID"
 // @Success 200 {string} string "Binary file"
@@ -134,6 +127,7 @@ func (h *MultimediaAdminHandler) Upload(c fiber.Ctx) error {
 // @Failure 403 {object} dto.APIResponse "Forbidden"
 // @Failure 404 {object} dto.APIResponse "Not found"
 // @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security AdminBearer
 // @Router /api/v1/admin/media/{uuid} [get]
 func (h *MultimediaAdminHandler) Download(c fiber.Ctx) error {
 	mediaUUID := c.Params("uuid")
@@ -169,7 +163,6 @@ func (h *MultimediaAdminHandler) Download(c fiber.Ctx) error {
 // @Summary Admin preview multimedia
 // @Description Return a thumbnail image for multimedia (video -> frame image, image -> resized)
 // @Tags Admin Multimedia
-// @Security BearerAuth
 // @Produce image/jpeg
 // @Param uuid path string true "Multimedia UUID"
 // @Success 200 {string} string "Thumbnail image"
@@ -178,6 +171,7 @@ func (h *MultimediaAdminHandler) Download(c fiber.Ctx) error {
 // @Failure 403 {object} dto.APIResponse "Forbidden"
 // @Failure 404 {object} dto.APIResponse "Not found"
 // @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security AdminBearer
 // @Router /api/v1/admin/media/{uuid}/preview [get]
 func (h *MultimediaAdminHandler) Preview(c fiber.Ctx) error {
 	mediaUUID := c.Params("uuid")
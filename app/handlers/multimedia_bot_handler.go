@@ -4,7 +4,7 @@ import (
 	"context"
 	"time"
 
-	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/app/apierror"
 	businessflow "github.com/amirphl/Yamata-no-Orochi/business_flow"
 	"github.com/amirphl/Yamata-no-Orochi/utils"
 	"github.com/gofiber/fiber/v3"
@@ -26,21 +26,13 @@ func NewMultimediaBotHandler(flow businessflow.MultimediaBotFlow) MultimediaBotH
 }
 
 func (h *MultimediaBotHandler) ErrorResponse(c fiber.Ctx, statusCode int, message, errorCode string, details any) error {
-	return c.Status(statusCode).JSON(dto.APIResponse{
-		Success: false,
-		Message: message,
-		Error: dto.ErrorDetail{
-			Code:    errorCode,
-			Details: details,
-		},
-	})
+	return apierror.Respond(c, statusCode, message, errorCode, details)
 }
 
 // Download handles multimedia download for bots.
 // @Summary Bot download multimedia
 // @Description Download an image or video by uuid (bot access)
 // @Tags Bot Multimedia
-// @Security BearerAuth
 // @Produce application/octet-stream
 // @Param uuid path string true "Multimedia UUID"
 // @Success 200 {string} string "Binary file"
@@ -48,6 +40,7 @@ func (h *MultimediaBotHandler) ErrorResponse(c fiber.Ctx, statusCode int, messag
 // @Failure 401 {object} dto.APIResponse "Unauthorized"
 // @Failure 404 {object} dto.APIResponse "Not found"
 // @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security BotBearer
 // @Router /api/v1/bot/media/{uuid} [get]
 func (h *MultimediaBotHandler) Download(c fiber.Ctx) error {
 	mediaUUID := c.Params("uuid")
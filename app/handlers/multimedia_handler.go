@@ -5,7 +5,9 @@ import (
 	"context"
 	"time"
 
+	"github.com/amirphl/Yamata-no-Orochi/app/apierror"
 	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/app/middleware"
 	businessflow "github.com/amirphl/Yamata-no-Orochi/business_flow"
 	"github.com/amirphl/Yamata-no-Orochi/utils"
 	"github.com/gofiber/fiber/v3"
@@ -29,14 +31,7 @@ func NewMultimediaHandler(flow businessflow.MultimediaFlow) *MultimediaHandler {
 }
 
 func (h *MultimediaHandler) ErrorResponse(c fiber.Ctx, statusCode int, message, errorCode string, details any) error {
-	return c.Status(statusCode).JSON(dto.APIResponse{
-		Success: false,
-		Message: message,
-		Error: dto.ErrorDetail{
-			Code:    errorCode,
-			Details: details,
-		},
-	})
+	return apierror.Respond(c, statusCode, message, errorCode, details)
 }
 
 func (h *MultimediaHandler) SuccessResponse(c fiber.Ctx, statusCode int, message string, data any) error {
@@ -58,6 +53,7 @@ func (h *MultimediaHandler) SuccessResponse(c fiber.Ctx, statusCode int, message
 // @Failure 400 {object} dto.APIResponse "Invalid request or file"
 // @Failure 401 {object} dto.APIResponse "Unauthorized - customer not found or inactive"
 // @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
 // @Router /api/v1/media/upload [post]
 func (h *MultimediaHandler) Upload(c fiber.Ctx) error {
 	fileHeader, err := c.FormFile("file")
@@ -90,10 +86,10 @@ func (h *MultimediaHandler) Upload(c fiber.Ctx) error {
 	result, err := h.flow.UploadMultimedia(ctx, &req, metadata)
 	if err != nil {
 		if businessflow.IsCustomerNotFound(err) {
-			return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer not found", "CUSTOMER_NOT_FOUND", nil)
+			return h.ErrorResponse(c, fiber.StatusNotFound, "Customer not found", "CUSTOMER_NOT_FOUND", nil)
 		}
 		if businessflow.IsAccountInactive(err) {
-			return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer account is inactive", "ACCOUNT_INACTIVE", nil)
+			return h.ErrorResponse(c, fiber.StatusForbidden, "Customer account is inactive", "ACCOUNT_INACTIVE", nil)
 		}
 		if be, ok := err.(*businessflow.BusinessError); ok {
 			switch be.Code {
@@ -125,6 +121,7 @@ func (h *MultimediaHandler) Upload(c fiber.Ctx) error {
 // @Failure 403 {object} dto.APIResponse "Forbidden"
 // @Failure 404 {object} dto.APIResponse "Not found"
 // @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
 // @Router /api/v1/media/{uuid} [get]
 func (h *MultimediaHandler) Download(c fiber.Ctx) error {
 	mediaUUID := c.Params("uuid")
@@ -175,6 +172,7 @@ func (h *MultimediaHandler) Download(c fiber.Ctx) error {
 // @Failure 403 {object} dto.APIResponse "Forbidden"
 // @Failure 404 {object} dto.APIResponse "Not found"
 // @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
 // @Router /api/v1/media/{uuid}/preview [get]
 func (h *MultimediaHandler) Preview(c fiber.Ctx) error {
 	mediaUUID := c.Params("uuid")
@@ -230,5 +228,6 @@ func (h *MultimediaHandler) createRequestContextWithTimeout(c fiber.Ctx, endpoin
 	if customerID, ok := c.Locals("customer_id").(uint); ok && customerID != 0 {
 		ctx = context.WithValue(ctx, utils.CustomerIDKey, customerID)
 	}
+	ctx = middleware.WithImpersonation(ctx, c)
 	return ctx, cancel
 }
@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/apierror"
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/app/middleware"
+	businessflow "github.com/amirphl/Yamata-no-Orochi/business_flow"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v3"
+)
+
+// NotificationHandlerInterface defines the customer notification inbox endpoints
+type NotificationHandlerInterface interface {
+	List(c fiber.Ctx) error
+	UnreadCount(c fiber.Ctx) error
+	MarkRead(c fiber.Ctx) error
+}
+
+// NotificationHandler implements the customer notification inbox endpoints
+type NotificationHandler struct {
+	flow      businessflow.NotificationFlow
+	validator *validator.Validate
+}
+
+func NewNotificationHandler(flow businessflow.NotificationFlow) NotificationHandlerInterface {
+	return &NotificationHandler{
+		flow:      flow,
+		validator: validator.New(),
+	}
+}
+
+func (h *NotificationHandler) ErrorResponse(c fiber.Ctx, status int, message, code string, details any) error {
+	return apierror.Respond(c, status, message, code, details)
+}
+
+func (h *NotificationHandler) SuccessResponse(c fiber.Ctx, status int, message string, data any) error {
+	return c.Status(status).JSON(dto.APIResponse{Success: true, Message: message, Data: data})
+}
+
+// List returns a page of the customer's notification inbox
+// @Summary List Notifications
+// @Description Return the authenticated customer's notifications newest first, rendered in the customer's locale, with the unread count. Notifications are added when a campaign is approved or rejected, a payment is credited to the wallet or the free balance drops below the minimum campaign budget. Continue with the next_before_id of the previous page.
+// @Tags Notifications
+// @Produce json
+// @Param unread_only query bool false "Only unread notifications"
+// @Param before_id query int false "next_before_id of the previous page"
+// @Param limit query int false "Page size, 1 to 100" default(20)
+// @Success 200 {object} dto.APIResponse{data=dto.ListNotificationsResponse}
+// @Failure 400 {object} dto.APIResponse "Validation error"
+// @Failure 401 {object} dto.APIResponse "Unauthorized"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
+// @Router /api/v1/notifications [get]
+func (h *NotificationHandler) List(c fiber.Ctx) error {
+	customerID, ok := c.Locals("customer_id").(uint)
+	if !ok {
+		return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer ID not found in context", "MISSING_CUSTOMER_ID", nil)
+	}
+	req := dto.ListNotificationsRequest{CustomerID: customerID, UnreadOnly: c.Query("unread_only") == "true"}
+	if v := c.Query("before_id"); v != "" {
+		beforeID, err := strconv.ParseUint(v, 10, 64)
+		if err != nil || beforeID == 0 {
+			return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid before_id", "VALIDATION_ERROR", nil)
+		}
+		req.BeforeID = uint(beforeID)
+	}
+	if v := c.Query("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil {
+			return h.ErrorResponse(c, fiber.StatusBadRequest, "limit must be between 1 and 100", "VALIDATION_ERROR", nil)
+		}
+		req.Limit = limit
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "limit must be between 1 and 100", "VALIDATION_ERROR", nil)
+	}
+
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/notifications", 10*time.Second)
+	defer cancel()
+	res, err := h.flow.List(ctx, &req)
+	if err != nil {
+		if businessflow.IsCustomerNotFound(err) {
+			return h.ErrorResponse(c, fiber.StatusNotFound, "Customer not found", "CUSTOMER_NOT_FOUND", nil)
+		}
+		log.Println("List notifications failed:", err)
+		return h.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to list notifications", "LIST_NOTIFICATIONS_FAILED", nil)
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, res.Message, res)
+}
+
+// UnreadCount returns the number of unread notifications
+// @Summary Get Unread Notifications Count
+// @Description Return how many of the authenticated customer's notifications are unread, for the inbox badge.
+// @Tags Notifications
+// @Produce json
+// @Success 200 {object} dto.APIResponse{data=dto.UnreadNotificationsCountResponse}
+// @Failure 401 {object} dto.APIResponse "Unauthorized"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
+// @Router /api/v1/notifications/unread-count [get]
+func (h *NotificationHandler) UnreadCount(c fiber.Ctx) error {
+	customerID, ok := c.Locals("customer_id").(uint)
+	if !ok {
+		return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer ID not found in context", "MISSING_CUSTOMER_ID", nil)
+	}
+
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/notifications/unread-count", 10*time.Second)
+	defer cancel()
+	res, err := h.flow.UnreadCount(ctx, customerID)
+	if err != nil {
+		log.Println("Get unread notifications count failed:", err)
+		return h.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to count unread notifications", "GET_UNREAD_NOTIFICATIONS_COUNT_FAILED", nil)
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, "Unread notifications counted", res)
+}
+
+// MarkRead marks notifications as read
+// @Summary Mark Notifications Read
+// @Description Mark the listed notifications of the authenticated customer as read, or all of them with all set to true. Unknown and already read ids are ignored.
+// @Tags Notifications
+// @Accept json
+// @Produce json
+// @Param request body dto.MarkNotificationsReadRequest true "Notifications to mark"
+// @Success 200 {object} dto.APIResponse{data=dto.MarkNotificationsReadResponse}
+// @Failure 400 {object} dto.APIResponse "Validation error"
+// @Failure 401 {object} dto.APIResponse "Unauthorized"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
+// @Router /api/v1/notifications/read [post]
+func (h *NotificationHandler) MarkRead(c fiber.Ctx) error {
+	var req dto.MarkNotificationsReadRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body", "INVALID_REQUEST", err.Error())
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		var validationErrors []string
+		for _, err := range err.(validator.ValidationErrors) {
+			validationErrors = append(validationErrors, getValidationErrorMessage(c, err))
+		}
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", validationErrors)
+	}
+
+	customerID, ok := c.Locals("customer_id").(uint)
+	if !ok {
+		return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer ID not found in context", "MISSING_CUSTOMER_ID", nil)
+	}
+	req.CustomerID = customerID
+
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/notifications/read", 10*time.Second)
+	defer cancel()
+	res, err := h.flow.MarkRead(ctx, &req)
+	if err != nil {
+		log.Println("Mark notifications read failed:", err)
+		return h.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to mark notifications as read", "MARK_NOTIFICATIONS_READ_FAILED", nil)
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, res.Message, res)
+}
+
+func (h *NotificationHandler) createRequestContextWithTimeout(c fiber.Ctx, endpoint string, timeout time.Duration) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	ctx = context.WithValue(ctx, utils.RequestIDKey, c.Get("X-Request-ID"))
+	ctx = context.WithValue(ctx, utils.UserAgentKey, c.Get("User-Agent"))
+	ctx = context.WithValue(ctx, utils.IPAddressKey, c.IP())
+	ctx = context.WithValue(ctx, utils.EndpointKey, endpoint)
+	ctx = context.WithValue(ctx, utils.TimeoutKey, timeout)
+	ctx = context.WithValue(ctx, utils.CancelFuncKey, cancel)
+	ctx = middleware.WithImpersonation(ctx, c)
+	return ctx, cancel
+}
@@ -8,13 +8,16 @@ import (
 	"strings"
 	"time"
 
+	"github.com/amirphl/Yamata-no-Orochi/app/apierror"
 	"github.com/amirphl/Yamata-no-Orochi/app/dto"
 	"github.com/amirphl/Yamata-no-Orochi/app/middleware"
 	businessflow "github.com/amirphl/Yamata-no-Orochi/business_flow"
+	"github.com/amirphl/Yamata-no-Orochi/listquery"
 	"github.com/amirphl/Yamata-no-Orochi/models"
 	"github.com/amirphl/Yamata-no-Orochi/utils"
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
 )
 
 // PaymentAdminHandlerInterface defines the contract for admin payment handlers.
@@ -26,6 +29,8 @@ type PaymentAdminHandlerInterface interface {
 	GetDepositReceiptFile(c fiber.Ctx) error
 	UpdateDepositReceiptStatus(c fiber.Ctx) error
 	AddInvoiceToTransaction(c fiber.Ctx) error
+	ListAgencyPayables(c fiber.Ctx) error
+	MarkAgencyPayablePaid(c fiber.Ctx) error
 }
 
 // PaymentAdminHandler handles admin payment HTTP requests.
@@ -43,14 +48,7 @@ func NewPaymentAdminHandler(paymentAdminFlow businessflow.PaymentAdminFlow) Paym
 }
 
 func (h *PaymentAdminHandler) ErrorResponse(c fiber.Ctx, statusCode int, message, errorCode string, details any) error {
-	return c.Status(statusCode).JSON(dto.APIResponse{
-		Success: false,
-		Message: message,
-		Error: dto.ErrorDetail{
-			Code:    errorCode,
-			Details: details,
-		},
-	})
+	return apierror.Respond(c, statusCode, message, errorCode, details)
 }
 
 func (h *PaymentAdminHandler) SuccessResponse(c fiber.Ctx, statusCode int, message string, data any) error {
@@ -73,6 +71,7 @@ func (h *PaymentAdminHandler) SuccessResponse(c fiber.Ctx, statusCode int, messa
 // @Failure 401 {object} dto.APIResponse "Unauthorized admin"
 // @Failure 404 {object} dto.APIResponse "Customer or wallet not found"
 // @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security AdminBearer
 // @Router /api/v1/admin/payments/charge-wallet [post]
 func (h *PaymentAdminHandler) ChargeWallet(c fiber.Ctx) error {
 	var req dto.AdminChargeWalletRequest
@@ -89,7 +88,7 @@ func (h *PaymentAdminHandler) ChargeWallet(c fiber.Ctx) error {
 	if err := h.validator.Struct(&req); err != nil {
 		var validationErrors []string
 		for _, err := range err.(validator.ValidationErrors) {
-			validationErrors = append(validationErrors, getValidationErrorMessage(err))
+			validationErrors = append(validationErrors, getValidationErrorMessage(c, err))
 		}
 		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", validationErrors)
 	}
@@ -171,6 +170,7 @@ func (h *PaymentAdminHandler) ChargeWallet(c fiber.Ctx) error {
 // @Failure 401 {object} dto.APIResponse "Unauthorized admin"
 // @Failure 404 {object} dto.APIResponse "Customer or discount not found"
 // @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security AdminBearer
 // @Router /api/v1/admin/payments/charge-wallet/preview [post]
 func (h *PaymentAdminHandler) PreviewWalletChargeImpact(c fiber.Ctx) error {
 	var req dto.AdminPreviewWalletChargeImpactRequest
@@ -180,7 +180,7 @@ func (h *PaymentAdminHandler) PreviewWalletChargeImpact(c fiber.Ctx) error {
 	if err := h.validator.Struct(&req); err != nil {
 		var validationErrors []string
 		for _, err := range err.(validator.ValidationErrors) {
-			validationErrors = append(validationErrors, getValidationErrorMessage(err))
+			validationErrors = append(validationErrors, getValidationErrorMessage(c, err))
 		}
 		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", validationErrors)
 	}
@@ -250,6 +250,8 @@ func (h *PaymentAdminHandler) PreviewWalletChargeImpact(c fiber.Ctx) error {
 // @Param lang query string false "Language filter (FA or EN)"
 // @Param customer_id query int false "Filter by customer ID"
 // @Param customer_name query string false "Filter by customer representative/company name"
+// @Param tracking_number query string false "Filter by the transfer's tracking number"
+// @Param bank_reference query string false "Filter by the matched bank statement reference"
 // @Param limit query int false "Limit (default 50)"
 // @Param offset query int false "Offset"
 // @Param order query string false "Order by clause (default id DESC)"
@@ -257,6 +259,7 @@ func (h *PaymentAdminHandler) PreviewWalletChargeImpact(c fiber.Ctx) error {
 // @Failure 400 {object} dto.APIResponse "Invalid language"
 // @Failure 401 {object} dto.APIResponse "Unauthorized"
 // @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security AdminBearer
 // @Router /api/v1/admin/payments/deposit-receipts [get]
 func (h *PaymentAdminHandler) ListDepositReceipts(c fiber.Ctx) error {
 	status := c.Query("status")
@@ -290,6 +293,12 @@ func (h *PaymentAdminHandler) ListDepositReceipts(c fiber.Ctx) error {
 	if customerName := strings.TrimSpace(c.Query("customer_name")); customerName != "" {
 		f.CustomerName = &customerName
 	}
+	if trackingNumber := strings.TrimSpace(c.Query("tracking_number")); trackingNumber != "" {
+		f.TrackingNumber = &trackingNumber
+	}
+	if bankReference := strings.TrimSpace(c.Query("bank_reference")); bankReference != "" {
+		f.BankReference = &bankReference
+	}
 	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/admin/payments/deposit-receipts", 30*time.Second)
 	defer cancel()
 	resp, err := h.paymentAdminFlow.AdminListDepositReceipts(ctx, f, limit, offset, order)
@@ -314,10 +323,14 @@ func (h *PaymentAdminHandler) ListDepositReceipts(c fiber.Ctx) error {
 // @Param end_date query string false "End date (RFC3339)"
 // @Param customer_id query int false "Optional customer filter"
 // @Param customer_name query string false "Optional customer name/company filter"
+// @Param filter query string false "List filter over id, type, status, amount, currency, wallet_id, customer_id, external_reference, is_sandbox, created_at and updated_at, e.g. amount:gte:500000;status:eq:completed"
+// @Param sort query string false "Sort fields, minus for descending, e.g. -amount"
+// @Param fields query string false "Item fields to return, e.g. uuid,amount,created_at"
 // @Success 200 {object} dto.APIResponse{data=dto.AdminListTransactionsResponse} "Transactions retrieved"
-// @Failure 400 {object} dto.APIResponse "Validation error"
+// @Failure 400 {object} dto.APIResponse "Validation error or invalid filter, sort or fields"
 // @Failure 401 {object} dto.APIResponse "Unauthorized"
 // @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security AdminBearer
 // @Router /api/v1/admin/payments/transactions [get]
 func (h *PaymentAdminHandler) ListTransactions(c fiber.Ctx) error {
 	page := uint(1)
@@ -375,6 +388,12 @@ func (h *PaymentAdminHandler) ListTransactions(c fiber.Ctx) error {
 		EndDate:      endDate,
 		CustomerID:   customerID,
 		CustomerName: customerName,
+		Filter:       c.Query("filter"),
+		Sort:         c.Query("sort"),
+	}
+	fields, err := listquery.ParseFieldSet(c.Query("fields"), dto.AdminTransactionItem{})
+	if err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid filter, sort or fields", "LIST_QUERY_INVALID", err.Error())
 	}
 
 	metadata := businessflow.NewClientMetadata(c.IP(), c.Get("User-Agent"))
@@ -394,12 +413,19 @@ func (h *PaymentAdminHandler) ListTransactions(c fiber.Ctx) error {
 			return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid page size", "INVALID_PAGE_SIZE", nil)
 		case businessflow.IsStartDateAfterEndDate(err):
 			return h.ErrorResponse(c, fiber.StatusBadRequest, "Start date must be before end date", "START_DATE_AFTER_END_DATE", nil)
+		case businessflow.IsListQueryInvalid(err):
+			return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid filter, sort or fields", "LIST_QUERY_INVALID", err.Error())
 		default:
 			log.Println("Admin list transactions failed", err)
 			return h.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to list transactions", "ADMIN_LIST_TRANSACTIONS_FAILED", nil)
 		}
 	}
-	return h.SuccessResponse(c, fiber.StatusOK, "Transactions retrieved", res)
+	data, err := fields.Project(res)
+	if err != nil {
+		log.Println("Admin list transactions failed", err)
+		return h.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to list transactions", "ADMIN_LIST_TRANSACTIONS_FAILED", nil)
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, "Transactions retrieved", data)
 }
 
 // GetDepositReceiptFile downloads the uploaded file.
@@ -412,6 +438,7 @@ func (h *PaymentAdminHandler) ListTransactions(c fiber.Ctx) error {
 // @Failure 404 {object} dto.APIResponse "Receipt not found"
 // @Failure 401 {object} dto.APIResponse "Unauthorized"
 // @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security AdminBearer
 // @Router /api/v1/admin/payments/deposit-receipts/{uuid}/file [get]
 func (h *PaymentAdminHandler) GetDepositReceiptFile(c fiber.Ctx) error {
 	uuid := c.Params("uuid")
@@ -448,7 +475,7 @@ func (h *PaymentAdminHandler) GetDepositReceiptFile(c fiber.Ctx) error {
 
 // UpdateDepositReceiptStatus approves/rejects a receipt.
 // @Summary Admin update deposit receipt status
-// @Description Approve or reject a deposit receipt; on approval customer_invoice_uuid is required and is linked in payment metadata.
+// @Description Approve or reject a deposit receipt. Approval matches the receipt with a bank statement entry: bank_reference is required and can be matched once, and the wallet is credited with matched_amount, which cannot exceed the receipt's amount (the receipt's amount when omitted), with the usual tax and agency split. The customer is notified of either outcome.
 // @Tags Payments Admin
 // @Accept json
 // @Produce json
@@ -459,6 +486,7 @@ func (h *PaymentAdminHandler) GetDepositReceiptFile(c fiber.Ctx) error {
 // @Failure 404 {object} dto.APIResponse "Receipt not found"
 // @Failure 409 {object} dto.APIResponse "Receipt already finalized"
 // @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security AdminBearer
 // @Router /api/v1/admin/payments/deposit-receipts/status [post]
 func (h *PaymentAdminHandler) UpdateDepositReceiptStatus(c fiber.Ctx) error {
 	var req dto.AdminUpdateDepositReceiptStatusRequest
@@ -468,7 +496,7 @@ func (h *PaymentAdminHandler) UpdateDepositReceiptStatus(c fiber.Ctx) error {
 	if err := h.validator.Struct(&req); err != nil {
 		var validationErrors []string
 		for _, err := range err.(validator.ValidationErrors) {
-			validationErrors = append(validationErrors, getValidationErrorMessage(err))
+			validationErrors = append(validationErrors, getValidationErrorMessage(c, err))
 		}
 		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", validationErrors)
 	}
@@ -497,6 +525,16 @@ func (h *PaymentAdminHandler) UpdateDepositReceiptStatus(c fiber.Ctx) error {
 			return h.ErrorResponse(c, fiber.StatusConflict, "Receipt already rejected", "RECEIPT_ALREADY_REJECTED", nil)
 		case businessflow.IsDepositReceiptInvalidStatus(err):
 			return h.ErrorResponse(c, fiber.StatusBadRequest, "action must be either approve or reject", "INVALID_RECEIPT_ACTION", nil)
+		case businessflow.IsDepositReceiptBankReferenceRequired(err):
+			return h.ErrorResponse(c, fiber.StatusBadRequest, "bank_reference is required when approving a receipt", "DEPOSIT_RECEIPT_BANK_REFERENCE_REQUIRED", nil)
+		case businessflow.IsDepositReceiptBankReferenceUsed(err):
+			return h.ErrorResponse(c, fiber.StatusConflict, "Bank statement entry is already matched with another receipt", "DEPOSIT_RECEIPT_BANK_REFERENCE_USED", nil)
+		case businessflow.IsDepositReceiptMatchedAmountTooHigh(err):
+			return h.ErrorResponse(c, fiber.StatusBadRequest, "matched_amount cannot be above the receipt's amount", "DEPOSIT_RECEIPT_MATCHED_AMOUNT_TOO_HIGH", nil)
+		case businessflow.IsAmountTooLow(err):
+			return h.ErrorResponse(c, fiber.StatusBadRequest, "Amount is too low", "AMOUNT_TOO_LOW", nil)
+		case businessflow.IsAmountNotMultiple(err):
+			return h.ErrorResponse(c, fiber.StatusBadRequest, "Amount must be a multiple of the required increment", "AMOUNT_NOT_MULTIPLE", nil)
 		case businessflow.IsDepositReceiptInvoiceRequired(err):
 			return h.ErrorResponse(c, fiber.StatusBadRequest, "customer_invoice_uuid is required when approving a receipt", "INVOICE_UUID_REQUIRED", nil)
 		case businessflow.IsDepositReceiptInvoiceInvalid(err):
@@ -526,6 +564,7 @@ func (h *PaymentAdminHandler) UpdateDepositReceiptStatus(c fiber.Ctx) error {
 // @Failure 404 {object} dto.APIResponse "Transaction not found"
 // @Failure 409 {object} dto.APIResponse "Invoice mismatch with existing metadata"
 // @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security AdminBearer
 // @Router /api/v1/admin/payments/transactions/invoice [post]
 func (h *PaymentAdminHandler) AddInvoiceToTransaction(c fiber.Ctx) error {
 	var req dto.AdminAddInvoiceToTransactionRequest
@@ -535,7 +574,7 @@ func (h *PaymentAdminHandler) AddInvoiceToTransaction(c fiber.Ctx) error {
 	if err := h.validator.Struct(&req); err != nil {
 		var validationErrors []string
 		for _, err := range err.(validator.ValidationErrors) {
-			validationErrors = append(validationErrors, getValidationErrorMessage(err))
+			validationErrors = append(validationErrors, getValidationErrorMessage(c, err))
 		}
 		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", validationErrors)
 	}
@@ -576,6 +615,110 @@ func (h *PaymentAdminHandler) AddInvoiceToTransaction(c fiber.Ctx) error {
 	return h.SuccessResponse(c, fiber.StatusOK, "Invoice linked to transaction", res)
 }
 
+// ListAgencyPayables returns agency shares settled to the system Sheba number
+// @Summary List Agency Payables (Admin)
+// @Description List agency shares of recharges that were settled to the system Sheba number because the agency's was invalid or unverified, newest first. pending_amount_with_tax is what is still owed over all pages.
+// @Tags Payments Admin
+// @Produce json
+// @Param agency_id query int false "Filter by agency ID"
+// @Param status query string false "Filter by status (pending|paid)"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Page size" default(10) maximum(100)
+// @Success 200 {object} dto.APIResponse{data=dto.AdminListAgencyPayablesResponse}
+// @Failure 400 {object} dto.APIResponse "Validation error"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security AdminBearer
+// @Router /api/v1/admin/payments/agency-payables [get]
+func (h *PaymentAdminHandler) ListAgencyPayables(c fiber.Ctx) error {
+	page, err := strconv.Atoi(c.Query("page", "1"))
+	if err != nil || page <= 0 {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid page format", "INVALID_PAGE", nil)
+	}
+	limit, err := strconv.Atoi(c.Query("limit", "10"))
+	if err != nil || limit <= 0 {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid limit format", "INVALID_LIMIT", nil)
+	}
+
+	filter := dto.AdminListAgencyPayablesFilter{Page: page, Limit: limit}
+	if v := strings.TrimSpace(c.Query("agency_id")); v != "" {
+		id, err := strconv.ParseUint(v, 10, 64)
+		if err != nil || id == 0 {
+			return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", nil)
+		}
+		filter.AgencyID = utils.ToPtr(uint(id))
+	}
+	if status := strings.TrimSpace(c.Query("status")); status != "" {
+		filter.Status = &status
+	}
+	if err := h.validator.Struct(filter); err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", nil)
+	}
+
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/admin/payments/agency-payables", 30*time.Second)
+	defer cancel()
+	res, err := h.paymentAdminFlow.AdminListAgencyPayables(ctx, filter)
+	if err != nil {
+		log.Println("Admin list agency payables failed", err)
+		return h.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to list agency payables", "ADMIN_LIST_AGENCY_PAYABLES_FAILED", nil)
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, res.Message, res)
+}
+
+// MarkAgencyPayablePaid records the payout of an agency payable
+// @Summary Mark Agency Payable Paid (Admin)
+// @Description Record that an agency share settled to the system Sheba number was paid out to the agency, e.g. by bank transfer.
+// @Tags Payments Admin
+// @Accept json
+// @Produce json
+// @Param uuid path string true "Payable UUID"
+// @Param body body dto.AdminMarkAgencyPayablePaidRequest true "Payout details"
+// @Success 200 {object} dto.APIResponse{data=dto.AdminAgencyPayableResponse}
+// @Failure 400 {object} dto.APIResponse "Validation error"
+// @Failure 401 {object} dto.APIResponse "Unauthorized admin"
+// @Failure 404 {object} dto.APIResponse "Payable not found"
+// @Failure 409 {object} dto.APIResponse "Payable already paid"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security AdminBearer
+// @Router /api/v1/admin/payments/agency-payables/{uuid}/paid [post]
+func (h *PaymentAdminHandler) MarkAgencyPayablePaid(c fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("uuid"))
+	if err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid uuid", "INVALID_UUID", nil)
+	}
+	var req dto.AdminMarkAgencyPayablePaidRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body", "INVALID_REQUEST", err.Error())
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		var validationErrors []string
+		for _, err := range err.(validator.ValidationErrors) {
+			validationErrors = append(validationErrors, getValidationErrorMessage(c, err))
+		}
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", validationErrors)
+	}
+
+	adminID, ok := middleware.GetAdminIDFromContext(c)
+	if !ok || adminID == 0 {
+		return h.ErrorResponse(c, fiber.StatusUnauthorized, "Admin ID not found in context", "MISSING_ADMIN_ID", nil)
+	}
+
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/admin/payments/agency-payables/paid", 30*time.Second)
+	defer cancel()
+	res, err := h.paymentAdminFlow.AdminMarkAgencyPayablePaid(ctx, id, &req, adminID)
+	if err != nil {
+		switch {
+		case businessflow.IsAgencyPayableNotFound(err):
+			return h.ErrorResponse(c, fiber.StatusNotFound, "Agency payable not found", "AGENCY_PAYABLE_NOT_FOUND", nil)
+		case businessflow.IsAgencyPayableAlreadyPaid(err):
+			return h.ErrorResponse(c, fiber.StatusConflict, "Agency payable was already paid", "AGENCY_PAYABLE_ALREADY_PAID", nil)
+		default:
+			log.Println("Admin mark agency payable paid failed", err)
+			return h.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to mark agency payable paid", "ADMIN_MARK_AGENCY_PAYABLE_PAID_FAILED", nil)
+		}
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, res.Message, res)
+}
+
 func (h *PaymentAdminHandler) createRequestContextWithTimeout(c fiber.Ctx, endpoint string, timeout time.Duration) (context.Context, context.CancelFunc) {
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	ctx = context.WithValue(ctx, utils.RequestIDKey, c.Get("X-Request-ID"))
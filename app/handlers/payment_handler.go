@@ -9,7 +9,9 @@ import (
 	"strings"
 	"time"
 
+	"github.com/amirphl/Yamata-no-Orochi/app/apierror"
 	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/app/middleware"
 	businessflow "github.com/amirphl/Yamata-no-Orochi/business_flow"
 	"github.com/amirphl/Yamata-no-Orochi/utils"
 	"github.com/go-playground/validator/v10"
@@ -39,14 +41,7 @@ type PaymentHandler struct {
 }
 
 func (h *PaymentHandler) ErrorResponse(c fiber.Ctx, statusCode int, message, errorCode string, details any) error {
-	return c.Status(statusCode).JSON(dto.APIResponse{
-		Success: false,
-		Message: message,
-		Error: dto.ErrorDetail{
-			Code:    errorCode,
-			Details: details,
-		},
-	})
+	return apierror.Respond(c, statusCode, message, errorCode, details)
 }
 
 func (h *PaymentHandler) SuccessResponse(c fiber.Ctx, statusCode int, message string, data any) error {
@@ -80,7 +75,11 @@ func NewPaymentHandler(paymentFlow businessflow.PaymentFlow) *PaymentHandler {
 // @Success 200 {object} dto.APIResponse{data=dto.ChargeWalletResponse} "Wallet charged successfully"
 // @Failure 400 {object} dto.APIResponse "Validation error or invalid request"
 // @Failure 401 {object} dto.APIResponse "Unauthorized - customer not found or inactive"
+// @Failure 403 {object} dto.APIResponse "Sandbox accounts cannot make real payments, or impersonating the customer"
+// @Failure 409 {object} dto.APIResponse "The customer's agency has no verified Sheba number"
 // @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Failure 503 {object} dto.APIResponse "Payment gateway or bank inquiry is unavailable, try again later"
+// @Security CustomerBearer
 // @Router /api/v1/payments/charge-wallet [post]
 func (h *PaymentHandler) ChargeWallet(c fiber.Ctx) error {
 	var req dto.ChargeWalletRequest
@@ -92,7 +91,7 @@ func (h *PaymentHandler) ChargeWallet(c fiber.Ctx) error {
 	if err := h.validator.Struct(&req); err != nil {
 		var validationErrors []string
 		for _, err := range err.(validator.ValidationErrors) {
-			validationErrors = append(validationErrors, getValidationErrorMessage(err))
+			validationErrors = append(validationErrors, getValidationErrorMessage(c, err))
 		}
 		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", validationErrors)
 	}
@@ -116,38 +115,7 @@ func (h *PaymentHandler) ChargeWallet(c fiber.Ctx) error {
 	defer cancel()
 	result, err := h.paymentFlow.ChargeWallet(ctx, &req, metadata)
 	if err != nil {
-		// Handle specific business errors
-		if businessflow.IsCustomerNotFound(err) {
-			return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer not found", "CUSTOMER_NOT_FOUND", nil)
-		}
-		if businessflow.IsAccountInactive(err) {
-			return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer account is inactive", "ACCOUNT_INACTIVE", nil)
-		}
-		if businessflow.IsWalletNotFound(err) {
-			return h.ErrorResponse(c, fiber.StatusNotFound, "Wallet not found", "WALLET_NOT_FOUND", nil)
-		}
-		if businessflow.IsReferrerAgencyIDRequired(err) {
-			return h.ErrorResponse(c, fiber.StatusBadRequest, "Referrer agency ID is required", "REFERRER_AGENCY_ID_REQUIRED", nil)
-		}
-		if businessflow.IsAgencyDiscountNotFound(err) {
-			return h.ErrorResponse(c, fiber.StatusNotFound, "Agency discount not found", "AGENCY_DISCOUNT_NOT_FOUND", nil)
-		}
-		if businessflow.IsAmountTooLow(err) {
-			return h.ErrorResponse(c, fiber.StatusBadRequest, "Amount is too low", "AMOUNT_TOO_LOW", nil)
-		}
-		if businessflow.IsAmountNotMultiple(err) {
-			return h.ErrorResponse(c, fiber.StatusBadRequest, "Amount must be a multiple of the required increment", "AMOUNT_NOT_MULTIPLE", nil)
-		}
-		if businessflow.IsInvalidLanguage(err) {
-			return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid language (allowed: FA, EN)", "INVALID_LANGUAGE", nil)
-		}
-		if businessflow.IsAtipayTokenEmpty(err) {
-			return h.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to get payment token", "ATIPAY_TOKEN_ERROR", nil)
-		}
-
-		log.Println("Wallet charging failed", err)
-		// Handle generic business errors
-		return h.ErrorResponse(c, fiber.StatusInternalServerError, "Wallet charging failed", "WALLET_CHARGING_FAILED", nil)
+		return mapChargeWalletErr(c, err)
 	}
 
 	// Successful wallet charging
@@ -158,6 +126,57 @@ func (h *PaymentHandler) ChargeWallet(c fiber.Ctx) error {
 	})
 }
 
+// mapChargeWalletErr maps the errors of a card payment through Atipay
+func mapChargeWalletErr(c fiber.Ctx, err error) error {
+	// Handle specific business errors
+	if businessflow.IsCustomerNotFound(err) {
+		return apierror.Respond(c, fiber.StatusNotFound, "Customer not found", "CUSTOMER_NOT_FOUND", nil)
+	}
+	if businessflow.IsAccountInactive(err) {
+		return apierror.Respond(c, fiber.StatusForbidden, "Customer account is inactive", "ACCOUNT_INACTIVE", nil)
+	}
+	if businessflow.IsWalletNotFound(err) {
+		return apierror.Respond(c, fiber.StatusNotFound, "Wallet not found", "WALLET_NOT_FOUND", nil)
+	}
+	if businessflow.IsReferrerAgencyIDRequired(err) {
+		return apierror.Respond(c, fiber.StatusBadRequest, "Referrer agency ID is required", "REFERRER_AGENCY_ID_REQUIRED", nil)
+	}
+	if businessflow.IsAgencyDiscountNotFound(err) {
+		return apierror.Respond(c, fiber.StatusNotFound, "Agency discount not found", "AGENCY_DISCOUNT_NOT_FOUND", nil)
+	}
+	if businessflow.IsAmountTooLow(err) {
+		return apierror.Respond(c, fiber.StatusBadRequest, "Amount is too low", "AMOUNT_TOO_LOW", nil)
+	}
+	if businessflow.IsAmountNotMultiple(err) {
+		return apierror.Respond(c, fiber.StatusBadRequest, "Amount must be a multiple of the required increment", "AMOUNT_NOT_MULTIPLE", nil)
+	}
+	if businessflow.IsInvalidLanguage(err) {
+		return apierror.Respond(c, fiber.StatusBadRequest, "Invalid language (allowed: FA, EN)", "INVALID_LANGUAGE", nil)
+	}
+	if businessflow.IsAtipayTokenEmpty(err) {
+		return apierror.Respond(c, fiber.StatusInternalServerError, "Failed to get payment token", "ATIPAY_TOKEN_ERROR", nil)
+	}
+	if businessflow.IsPaymentGatewayUnavailable(err) {
+		return apierror.Respond(c, fiber.StatusServiceUnavailable, "Payment gateway is unavailable, try again later", "PAYMENT_GATEWAY_UNAVAILABLE", nil)
+	}
+	if businessflow.IsAgencyShebaNotVerified(err) {
+		return apierror.Respond(c, fiber.StatusConflict, "Your agency's Sheba number is not verified yet; ask your agency to verify it", "AGENCY_SHEBA_NOT_VERIFIED", nil)
+	}
+	if businessflow.IsShebaInquiryUnavailable(err) {
+		return apierror.Respond(c, fiber.StatusServiceUnavailable, "Bank inquiry is unavailable, try again later", "SHEBA_INQUIRY_UNAVAILABLE", nil)
+	}
+	if businessflow.IsSandboxRealPayment(err) {
+		return apierror.Respond(c, fiber.StatusForbidden, "Sandbox accounts cannot make real payments; top up the sandbox wallet instead", "SANDBOX_REAL_PAYMENT", nil)
+	}
+	if businessflow.IsCustomerSuspended(err) {
+		return customerSuspended(c, err)
+	}
+
+	log.Println("Wallet charging failed", err)
+	// Handle generic business errors
+	return apierror.Respond(c, fiber.StatusInternalServerError, "Wallet charging failed", "WALLET_CHARGING_FAILED", nil)
+}
+
 // PaymentCallback handles the callback from the payment gateway
 // @Summary Payment Callback
 // @Description Handles the callback from the payment gateway (Atipay)
@@ -239,7 +258,7 @@ func (h *PaymentHandler) PaymentCallback(c fiber.Ctx) error {
 	if err := h.validator.Struct(&callbackReq); err != nil {
 		var validationErrors []string
 		for _, err := range err.(validator.ValidationErrors) {
-			validationErrors = append(validationErrors, getValidationErrorMessage(err))
+			validationErrors = append(validationErrors, getValidationErrorMessage(c, err))
 		}
 		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", validationErrors)
 	}
@@ -345,6 +364,7 @@ func (h *PaymentHandler) PaymentCallback(c fiber.Ctx) error {
 // @Failure 400 {object} dto.APIResponse "Validation error or invalid request"
 // @Failure 401 {object} dto.APIResponse "Unauthorized - customer not found or inactive"
 // @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
 // @Router /api/v1/payments/history [get]
 func (h *PaymentHandler) GetTransactionHistory(c fiber.Ctx) error {
 	// Get authenticated customer ID from context
@@ -422,10 +442,10 @@ func (h *PaymentHandler) GetTransactionHistory(c fiber.Ctx) error {
 		}
 		// Handle specific business errors
 		if businessflow.IsCustomerNotFound(err) {
-			return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer not found", "CUSTOMER_NOT_FOUND", nil)
+			return h.ErrorResponse(c, fiber.StatusNotFound, "Customer not found", "CUSTOMER_NOT_FOUND", nil)
 		}
 		if businessflow.IsAccountInactive(err) {
-			return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer account is inactive", "ACCOUNT_INACTIVE", nil)
+			return h.ErrorResponse(c, fiber.StatusForbidden, "Customer account is inactive", "ACCOUNT_INACTIVE", nil)
 		}
 		if businessflow.IsWalletNotFound(err) {
 			return h.ErrorResponse(c, fiber.StatusNotFound, "Wallet not found", "WALLET_NOT_FOUND", nil)
@@ -449,6 +469,7 @@ func (h *PaymentHandler) GetTransactionHistory(c fiber.Ctx) error {
 // @Failure 401 {object} dto.APIResponse "Unauthorized - customer not found or inactive"
 // @Failure 404 {object} dto.APIResponse "Wallet or snapshot not found"
 // @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
 // @Router /api/v1/wallet/balance [get]
 func (h *PaymentHandler) GetWalletBalance(c fiber.Ctx) error {
 	// Get authenticated customer ID from context
@@ -469,10 +490,10 @@ func (h *PaymentHandler) GetWalletBalance(c fiber.Ctx) error {
 	result, err := h.paymentFlow.GetWalletBalance(ctx, req, metadata)
 	if err != nil {
 		if businessflow.IsCustomerNotFound(err) {
-			return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer not found", "CUSTOMER_NOT_FOUND", nil)
+			return h.ErrorResponse(c, fiber.StatusNotFound, "Customer not found", "CUSTOMER_NOT_FOUND", nil)
 		}
 		if businessflow.IsAccountInactive(err) {
-			return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer account is inactive", "ACCOUNT_INACTIVE", nil)
+			return h.ErrorResponse(c, fiber.StatusForbidden, "Customer account is inactive", "ACCOUNT_INACTIVE", nil)
 		}
 		if businessflow.IsWalletNotFound(err) {
 			return h.ErrorResponse(c, fiber.StatusNotFound, "Wallet not found", "WALLET_NOT_FOUND", nil)
@@ -500,6 +521,7 @@ func (h *PaymentHandler) createRequestContextWithTimeout(c fiber.Ctx, endpoint s
 	ctx = context.WithValue(ctx, utils.EndpointKey, endpoint)
 	ctx = context.WithValue(ctx, utils.TimeoutKey, timeout)
 	ctx = context.WithValue(ctx, utils.CancelFuncKey, cancel) // Store cancel function for cleanup
+	ctx = middleware.WithImpersonation(ctx, c)
 
 	return ctx, cancel
 }
@@ -512,7 +534,7 @@ func (h *PaymentHandler) setupCustomValidations() {
 
 // SubmitDepositReceipt uploads a deposit receipt for manual review (no immediate credit).
 // @Summary Submit deposit receipt
-// @Description Upload a bank deposit receipt file (base64) for finance review; balance is credited only after approval.
+// @Description Upload a bank deposit receipt file (base64) with the transfer's date and tracking number for finance review; balance is credited only after finance matches it with the bank statement and approves it. A tracking number can be on one receipt under review or approved at a time.
 // @Tags Payments
 // @Accept json
 // @Produce json
@@ -520,7 +542,10 @@ func (h *PaymentHandler) setupCustomValidations() {
 // @Success 201 {object} dto.APIResponse{data=dto.SubmitDepositReceiptResponse} "Receipt submitted"
 // @Failure 400 {object} dto.APIResponse "Validation error or invalid request"
 // @Failure 401 {object} dto.APIResponse "Unauthorized - customer not found or inactive"
+// @Failure 403 {object} dto.APIResponse "Sandbox accounts cannot make real payments, or impersonating the customer"
+// @Failure 409 {object} dto.APIResponse "Tracking number already submitted"
 // @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
 // @Router /api/v1/payments/deposit-receipts [post]
 func (h *PaymentHandler) SubmitDepositReceipt(c fiber.Ctx) error {
 	var req dto.SubmitDepositReceiptRequest
@@ -534,7 +559,7 @@ func (h *PaymentHandler) SubmitDepositReceipt(c fiber.Ctx) error {
 	if err := h.validator.Struct(&req); err != nil {
 		var validationErrors []string
 		for _, err := range err.(validator.ValidationErrors) {
-			validationErrors = append(validationErrors, getValidationErrorMessage(err))
+			validationErrors = append(validationErrors, getValidationErrorMessage(c, err))
 		}
 		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", validationErrors)
 	}
@@ -543,23 +568,38 @@ func (h *PaymentHandler) SubmitDepositReceipt(c fiber.Ctx) error {
 	defer cancel()
 	res, err := h.paymentFlow.SubmitDepositReceipt(ctx, &req, metadata)
 	if err != nil {
-		switch {
-		case businessflow.IsInvalidLanguage(err):
-			return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid language", "INVALID_LANGUAGE", nil)
-		case businessflow.IsDepositReceiptFileEmpty(err):
-			return h.ErrorResponse(c, fiber.StatusBadRequest, "File is empty", "FILE_EMPTY", nil)
-		case businessflow.IsDepositReceiptFileTooLarge(err):
-			return h.ErrorResponse(c, fiber.StatusBadRequest, "File too large (max 5MB)", "FILE_TOO_LARGE", nil)
-		case businessflow.IsDepositReceiptFileInvalidType(err):
-			return h.ErrorResponse(c, fiber.StatusBadRequest, "Unsupported file type", "INVALID_FILE_TYPE", nil)
-		default:
-			log.Println("Submit deposit receipt failed", err)
-			return h.ErrorResponse(c, fiber.StatusInternalServerError, "Submit deposit receipt failed", "SUBMIT_DEPOSIT_RECEIPT_FAILED", nil)
-		}
+		return mapSubmitDepositReceiptErr(c, err)
 	}
 	return h.SuccessResponse(c, fiber.StatusCreated, "Deposit receipt submitted", res)
 }
 
+// mapSubmitDepositReceiptErr maps the errors of a bank transfer receipt
+func mapSubmitDepositReceiptErr(c fiber.Ctx, err error) error {
+	switch {
+	case businessflow.IsInvalidLanguage(err):
+		return apierror.Respond(c, fiber.StatusBadRequest, "Invalid language", "INVALID_LANGUAGE", nil)
+	case businessflow.IsDepositReceiptFileEmpty(err):
+		return apierror.Respond(c, fiber.StatusBadRequest, "File is empty", "FILE_EMPTY", nil)
+	case businessflow.IsDepositReceiptFileTooLarge(err):
+		return apierror.Respond(c, fiber.StatusBadRequest, "File too large (max 5MB)", "FILE_TOO_LARGE", nil)
+	case businessflow.IsDepositReceiptFileInvalidType(err):
+		return apierror.Respond(c, fiber.StatusBadRequest, "Unsupported file type", "INVALID_FILE_TYPE", nil)
+	case businessflow.IsDepositReceiptTransferDateInvalid(err):
+		return apierror.Respond(c, fiber.StatusBadRequest, "Transfer date must be formatted as YYYY-MM-DD and not be in the future", "DEPOSIT_RECEIPT_TRANSFER_DATE_INVALID", nil)
+	case businessflow.IsDepositReceiptTrackingNumberInvalid(err):
+		return apierror.Respond(c, fiber.StatusBadRequest, "Tracking number is too short", "DEPOSIT_RECEIPT_TRACKING_NUMBER_INVALID", nil)
+	case businessflow.IsDepositReceiptTrackingNumberUsed(err):
+		return apierror.Respond(c, fiber.StatusConflict, "A receipt with this tracking number was already submitted", "DEPOSIT_RECEIPT_TRACKING_NUMBER_USED", nil)
+	case businessflow.IsSandboxRealPayment(err):
+		return apierror.Respond(c, fiber.StatusForbidden, "Sandbox accounts cannot make real payments; top up the sandbox wallet instead", "SANDBOX_REAL_PAYMENT", nil)
+	case businessflow.IsCustomerSuspended(err):
+		return customerSuspended(c, err)
+	default:
+		log.Println("Submit deposit receipt failed", err)
+		return apierror.Respond(c, fiber.StatusInternalServerError, "Submit deposit receipt failed", "SUBMIT_DEPOSIT_RECEIPT_FAILED", nil)
+	}
+}
+
 // ListDepositReceipts returns current user's receipts.
 // @Summary List deposit receipts
 // @Description Returns the user's submitted deposit receipts with status and metadata.
@@ -570,6 +610,7 @@ func (h *PaymentHandler) SubmitDepositReceipt(c fiber.Ctx) error {
 // @Failure 400 {object} dto.APIResponse "Invalid language"
 // @Failure 401 {object} dto.APIResponse "Unauthorized"
 // @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
 // @Router /api/v1/payments/deposit-receipts [get]
 func (h *PaymentHandler) ListDepositReceipts(c fiber.Ctx) error {
 	customerID, ok := c.Locals("customer_id").(uint)
@@ -601,6 +642,7 @@ func (h *PaymentHandler) ListDepositReceipts(c fiber.Ctx) error {
 // @Failure 400 {object} dto.APIResponse "Invalid receipt or language"
 // @Failure 401 {object} dto.APIResponse "Unauthorized"
 // @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
 // @Router /api/v1/payments/proforma/preview [get]
 func (h *PaymentHandler) PreviewProformaInvoice(c fiber.Ctx) error {
 	customerID, ok := c.Locals("customer_id").(uint)
@@ -642,6 +684,7 @@ func (h *PaymentHandler) PreviewProformaInvoice(c fiber.Ctx) error {
 // @Failure 400 {object} dto.APIResponse "Invalid amount or language"
 // @Failure 401 {object} dto.APIResponse "Unauthorized"
 // @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
 // @Router /api/v1/payments/proforma/preview-by-amount [get]
 func (h *PaymentHandler) PreviewProformaInvoiceByAmount(c fiber.Ctx) error {
 	customerID, _ := c.Locals("customer_id").(uint)
@@ -683,6 +726,7 @@ func (h *PaymentHandler) PreviewProformaInvoiceByAmount(c fiber.Ctx) error {
 // @Failure 400 {object} dto.APIResponse "Invalid receipt"
 // @Failure 401 {object} dto.APIResponse "Unauthorized"
 // @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
 // @Router /api/v1/payments/deposit-receipts/{receipt_uuid}/file [get]
 func (h *PaymentHandler) DownloadDepositReceiptFile(c fiber.Ctx) error {
 	customerID, _ := c.Locals("customer_id").(uint)
@@ -722,8 +766,10 @@ func (h *PaymentHandler) DownloadDepositReceiptFile(c fiber.Ctx) error {
 // @Success 200 {object} dto.APIResponse "File updated"
 // @Failure 400 {object} dto.APIResponse "Invalid request or receipt"
 // @Failure 401 {object} dto.APIResponse "Unauthorized"
+// @Failure 403 {object} dto.APIResponse "Not allowed while impersonating the customer"
 // @Failure 409 {object} dto.APIResponse "Receipt already finalized"
 // @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
 // @Router /api/v1/payments/deposit-receipts/{receipt_uuid}/file [put]
 func (h *PaymentHandler) UpdateDepositReceiptFile(c fiber.Ctx) error {
 	customerID, _ := c.Locals("customer_id").(uint)
@@ -741,7 +787,7 @@ func (h *PaymentHandler) UpdateDepositReceiptFile(c fiber.Ctx) error {
 	if err := h.validator.Struct(&req); err != nil {
 		var validationErrors []string
 		for _, err := range err.(validator.ValidationErrors) {
-			validationErrors = append(validationErrors, getValidationErrorMessage(err))
+			validationErrors = append(validationErrors, getValidationErrorMessage(c, err))
 		}
 		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", validationErrors)
 	}
@@ -776,8 +822,10 @@ func (h *PaymentHandler) UpdateDepositReceiptFile(c fiber.Ctx) error {
 // @Success 200 {object} dto.APIResponse "File removed"
 // @Failure 400 {object} dto.APIResponse "Invalid receipt"
 // @Failure 401 {object} dto.APIResponse "Unauthorized"
+// @Failure 403 {object} dto.APIResponse "Not allowed while impersonating the customer"
 // @Failure 409 {object} dto.APIResponse "Receipt already finalized"
 // @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
 // @Router /api/v1/payments/deposit-receipts/{receipt_uuid}/file [delete]
 func (h *PaymentHandler) DeleteDepositReceiptFile(c fiber.Ctx) error {
 	customerID, _ := c.Locals("customer_id").(uint)
@@ -819,6 +867,7 @@ func (h *PaymentHandler) DeleteDepositReceiptFile(c fiber.Ctx) error {
 // @Failure 404 {object} dto.APIResponse
 // @Failure 409 {object} dto.APIResponse
 // @Failure 500 {object} dto.APIResponse
+// @Security CustomerBearer
 // @Router /api/v1/payments/transactions/invoice-issue-request [post]
 func (h *PaymentHandler) NotifyInvoiceIssueRequest(c fiber.Ctx) error {
 	var req dto.NotifyInvoiceIssueRequest
@@ -828,7 +877,7 @@ func (h *PaymentHandler) NotifyInvoiceIssueRequest(c fiber.Ctx) error {
 	if err := h.validator.Struct(&req); err != nil {
 		var validationErrors []string
 		for _, err := range err.(validator.ValidationErrors) {
-			validationErrors = append(validationErrors, getValidationErrorMessage(err))
+			validationErrors = append(validationErrors, getValidationErrorMessage(c, err))
 		}
 		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", validationErrors)
 	}
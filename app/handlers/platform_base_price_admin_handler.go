@@ -4,6 +4,7 @@ import (
 	"context"
 	"time"
 
+	"github.com/amirphl/Yamata-no-Orochi/app/apierror"
 	"github.com/amirphl/Yamata-no-Orochi/app/dto"
 	"github.com/amirphl/Yamata-no-Orochi/app/middleware"
 	businessflow "github.com/amirphl/Yamata-no-Orochi/business_flow"
@@ -30,14 +31,7 @@ func NewPlatformBasePriceAdminHandler(flow businessflow.PlatformBasePriceAdminFl
 }
 
 func (h *PlatformBasePriceAdminHandler) ErrorResponse(c fiber.Ctx, statusCode int, message, errorCode string, details any) error {
-	return c.Status(statusCode).JSON(dto.APIResponse{
-		Success: false,
-		Message: message,
-		Error: dto.ErrorDetail{
-			Code:    errorCode,
-			Details: details,
-		},
-	})
+	return apierror.Respond(c, statusCode, message, errorCode, details)
 }
 
 func (h *PlatformBasePriceAdminHandler) SuccessResponse(c fiber.Ctx, statusCode int, message string, data any) error {
@@ -55,6 +49,7 @@ func (h *PlatformBasePriceAdminHandler) SuccessResponse(c fiber.Ctx, statusCode
 // @Produce json
 // @Success 200 {object} dto.APIResponse{data=dto.AdminListPlatformBasePricesResponse} "Retrieved"
 // @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security AdminBearer
 // @Router /api/v1/admin/platform-base-prices [get]
 func (h *PlatformBasePriceAdminHandler) List(c fiber.Ctx) error {
 	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/admin/platform-base-prices", 30*time.Second)
@@ -82,6 +77,7 @@ func (h *PlatformBasePriceAdminHandler) List(c fiber.Ctx) error {
 // @Failure 400 {object} dto.APIResponse "Validation error"
 // @Failure 404 {object} dto.APIResponse "Platform base price not found"
 // @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security AdminBearer
 // @Router /api/v1/admin/platform-base-prices [put]
 func (h *PlatformBasePriceAdminHandler) Update(c fiber.Ctx) error {
 	var req dto.AdminUpdatePlatformBasePriceRequest
@@ -4,7 +4,9 @@ import (
 	"context"
 	"time"
 
+	"github.com/amirphl/Yamata-no-Orochi/app/apierror"
 	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/app/middleware"
 	businessflow "github.com/amirphl/Yamata-no-Orochi/business_flow"
 	"github.com/amirphl/Yamata-no-Orochi/utils"
 	"github.com/gofiber/fiber/v3"
@@ -26,14 +28,7 @@ func NewPlatformBasePriceHandler(flow businessflow.PlatformBasePriceFlow) Platfo
 }
 
 func (h *PlatformBasePriceHandler) ErrorResponse(c fiber.Ctx, statusCode int, message, errorCode string, details any) error {
-	return c.Status(statusCode).JSON(dto.APIResponse{
-		Success: false,
-		Message: message,
-		Error: dto.ErrorDetail{
-			Code:    errorCode,
-			Details: details,
-		},
-	})
+	return apierror.Respond(c, statusCode, message, errorCode, details)
 }
 
 func (h *PlatformBasePriceHandler) SuccessResponse(c fiber.Ctx, statusCode int, message string, data any) error {
@@ -51,6 +46,7 @@ func (h *PlatformBasePriceHandler) SuccessResponse(c fiber.Ctx, statusCode int,
 // @Produce json
 // @Success 200 {object} dto.APIResponse{data=dto.ListPlatformBasePricesResponse} "Retrieved"
 // @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
 // @Router /api/v1/platform-base-prices [get]
 func (h *PlatformBasePriceHandler) List(c fiber.Ctx) error {
 	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/platform-base-prices", 30*time.Second)
@@ -78,5 +74,6 @@ func (h *PlatformBasePriceHandler) createRequestContextWithTimeout(c fiber.Ctx,
 	if customerID, ok := c.Locals("customer_id").(uint); ok && customerID != 0 {
 		ctx = context.WithValue(ctx, utils.CustomerIDKey, customerID)
 	}
+	ctx = middleware.WithImpersonation(ctx, c)
 	return ctx, cancel
 }
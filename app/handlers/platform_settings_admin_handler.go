@@ -4,6 +4,7 @@ import (
 	"context"
 	"time"
 
+	"github.com/amirphl/Yamata-no-Orochi/app/apierror"
 	"github.com/amirphl/Yamata-no-Orochi/app/dto"
 	"github.com/amirphl/Yamata-no-Orochi/app/middleware"
 	businessflow "github.com/amirphl/Yamata-no-Orochi/business_flow"
@@ -31,14 +32,7 @@ func NewPlatformSettingsAdminHandler(flow businessflow.PlatformSettingsAdminFlow
 }
 
 func (h *PlatformSettingsAdminHandler) ErrorResponse(c fiber.Ctx, statusCode int, message, errorCode string, details any) error {
-	return c.Status(statusCode).JSON(dto.APIResponse{
-		Success: false,
-		Message: message,
-		Error: dto.ErrorDetail{
-			Code:    errorCode,
-			Details: details,
-		},
-	})
+	return apierror.Respond(c, statusCode, message, errorCode, details)
 }
 
 func (h *PlatformSettingsAdminHandler) SuccessResponse(c fiber.Ctx, statusCode int, message string, data any) error {
@@ -56,6 +50,7 @@ func (h *PlatformSettingsAdminHandler) SuccessResponse(c fiber.Ctx, statusCode i
 // @Produce json
 // @Success 200 {object} dto.APIResponse{data=dto.AdminListPlatformSettingsResponse} "Retrieved"
 // @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security AdminBearer
 // @Router /api/v1/admin/platform-settings [get]
 func (h *PlatformSettingsAdminHandler) List(c fiber.Ctx) error {
 	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/admin/platform-settings", 30*time.Second)
@@ -84,6 +79,7 @@ func (h *PlatformSettingsAdminHandler) List(c fiber.Ctx) error {
 // @Failure 400 {object} dto.APIResponse "Validation error"
 // @Failure 404 {object} dto.APIResponse "Platform settings not found"
 // @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security AdminBearer
 // @Router /api/v1/admin/platform-settings/status [put]
 func (h *PlatformSettingsAdminHandler) ChangeStatus(c fiber.Ctx) error {
 	var req dto.AdminChangePlatformSettingsStatusRequest
@@ -130,6 +126,7 @@ func (h *PlatformSettingsAdminHandler) ChangeStatus(c fiber.Ctx) error {
 // @Failure 400 {object} dto.APIResponse "Validation error"
 // @Failure 404 {object} dto.APIResponse "Platform settings not found"
 // @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security AdminBearer
 // @Router /api/v1/admin/platform-settings/metadata [put]
 func (h *PlatformSettingsAdminHandler) AddMetadata(c fiber.Ctx) error {
 	var req dto.AdminAddPlatformSettingsMetadataRequest
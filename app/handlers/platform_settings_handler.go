@@ -5,7 +5,9 @@ import (
 	"context"
 	"time"
 
+	"github.com/amirphl/Yamata-no-Orochi/app/apierror"
 	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/app/middleware"
 	businessflow "github.com/amirphl/Yamata-no-Orochi/business_flow"
 	"github.com/amirphl/Yamata-no-Orochi/utils"
 	"github.com/go-playground/validator/v10"
@@ -33,14 +35,7 @@ func NewPlatformSettingsHandler(flow businessflow.PlatformSettingsFlow) *Platfor
 }
 
 func (h *PlatformSettingsHandler) ErrorResponse(c fiber.Ctx, statusCode int, message, errorCode string, details any) error {
-	return c.Status(statusCode).JSON(dto.APIResponse{
-		Success: false,
-		Message: message,
-		Error: dto.ErrorDetail{
-			Code:    errorCode,
-			Details: details,
-		},
-	})
+	return apierror.Respond(c, statusCode, message, errorCode, details)
 }
 
 func (h *PlatformSettingsHandler) SuccessResponse(c fiber.Ctx, statusCode int, message string, data any) error {
@@ -63,6 +58,7 @@ func (h *PlatformSettingsHandler) SuccessResponse(c fiber.Ctx, statusCode int, m
 // @Failure 409 {object} dto.APIResponse "Duplicate name"
 // @Failure 401 {object} dto.APIResponse "Unauthorized"
 // @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
 // @Router /api/v1/platform-settings [post]
 func (h *PlatformSettingsHandler) Create(c fiber.Ctx) error {
 	var req dto.CreatePlatformSettingsRequest
@@ -87,7 +83,7 @@ func (h *PlatformSettingsHandler) Create(c fiber.Ctx) error {
 			case "INVALID_REQUEST":
 				return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request", be.Code, be.Error())
 			case "MULTIMEDIA_NOT_FOUND":
-				return h.ErrorResponse(c, fiber.StatusBadRequest, "Multimedia not found", be.Code, be.Error())
+				return h.ErrorResponse(c, fiber.StatusNotFound, "Multimedia not found", be.Code, be.Error())
 			case "BUSINESS_LICENSE_NOT_FOUND":
 				return h.ErrorResponse(c, fiber.StatusBadRequest, "Business license multimedia not found", be.Code, be.Error())
 			case "PLATFORM_SETTINGS_DUPLICATE_CHECK_FAILED":
@@ -112,6 +108,7 @@ func (h *PlatformSettingsHandler) Create(c fiber.Ctx) error {
 // @Success 200 {object} dto.APIResponse{data=dto.ListPlatformSettingsResponse} "Retrieved"
 // @Failure 401 {object} dto.APIResponse "Unauthorized"
 // @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
 // @Router /api/v1/platform-settings [get]
 func (h *PlatformSettingsHandler) List(c fiber.Ctx) error {
 	customerID, ok := c.Locals("customer_id").(uint)
@@ -144,5 +141,6 @@ func (h *PlatformSettingsHandler) createRequestContextWithTimeout(c fiber.Ctx, e
 	if customerID, ok := c.Locals("customer_id").(uint); ok && customerID != 0 {
 		ctx = context.WithValue(ctx, utils.CustomerIDKey, customerID)
 	}
+	ctx = middleware.WithImpersonation(ctx, c)
 	return ctx, cancel
 }
@@ -0,0 +1,244 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/apierror"
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/app/middleware"
+	businessflow "github.com/amirphl/Yamata-no-Orochi/business_flow"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+)
+
+// PostpaidBillingAdminHandlerInterface defines admin endpoints for customer
+// credit lines and postpaid invoices
+type PostpaidBillingAdminHandlerInterface interface {
+	GetCreditLine(c fiber.Ctx) error
+	SetCreditLimit(c fiber.Ctx) error
+	ListInvoices(c fiber.Ctx) error
+	MarkInvoicePaid(c fiber.Ctx) error
+}
+
+// PostpaidBillingAdminHandler implements the admin postpaid billing endpoints
+type PostpaidBillingAdminHandler struct {
+	flow      businessflow.PostpaidBillingFlow
+	validator *validator.Validate
+}
+
+func NewPostpaidBillingAdminHandler(flow businessflow.PostpaidBillingFlow) PostpaidBillingAdminHandlerInterface {
+	return &PostpaidBillingAdminHandler{
+		flow:      flow,
+		validator: validator.New(),
+	}
+}
+
+func (h *PostpaidBillingAdminHandler) ErrorResponse(c fiber.Ctx, status int, message, code string, details any) error {
+	return apierror.Respond(c, status, message, code, details)
+}
+
+func (h *PostpaidBillingAdminHandler) SuccessResponse(c fiber.Ctx, status int, message string, data any) error {
+	return c.Status(status).JSON(dto.APIResponse{Success: true, Message: message, Data: data})
+}
+
+// GetCreditLine returns a customer's credit line
+// @Summary Get Customer Credit Line (Admin)
+// @Description Credit limit of the customer, what is outstanding on it and whether an invoice is overdue
+// @Tags Payments Admin
+// @Produce json
+// @Param customer_id path int true "Customer ID"
+// @Success 200 {object} dto.APIResponse{data=dto.AdminCustomerCreditLineResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Failure 404 {object} dto.APIResponse
+// @Failure 500 {object} dto.APIResponse
+// @Security AdminBearer
+// @Router /api/v1/admin/payments/credit-lines/{customer_id} [get]
+func (h *PostpaidBillingAdminHandler) GetCreditLine(c fiber.Ctx) error {
+	cidStr := c.Params("customer_id")
+	cid, err := strconv.ParseUint(cidStr, 10, 64)
+	if err != nil || cid == 0 {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid customer_id", "VALIDATION_ERROR", nil)
+	}
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/admin/payments/credit-lines/"+cidStr, 30*time.Second)
+	defer cancel()
+	res, err := h.flow.GetCreditLine(ctx, uint(cid))
+	if err != nil {
+		return h.handleFlowError(c, "Failed to get customer credit line", "GET_CUSTOMER_CREDIT_LINE_FAILED", err)
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, res.Message, res)
+}
+
+// SetCreditLimit sets a customer's credit limit
+// @Summary Set Customer Credit Limit (Admin)
+// @Description Let the customer finalize campaigns whose budget exceeds the wallet balance, up to the given credit limit in toman. The shortfall is drawn from the credit line and billed on a monthly postpaid invoice. A limit of 0 stops further draws; what is outstanding stays billable.
+// @Tags Payments Admin
+// @Accept json
+// @Produce json
+// @Param customer_id path int true "Customer ID"
+// @Param body body dto.AdminSetCustomerCreditLimitRequest true "Credit limit"
+// @Success 200 {object} dto.APIResponse{data=dto.AdminCustomerCreditLineResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Failure 404 {object} dto.APIResponse
+// @Failure 500 {object} dto.APIResponse
+// @Security AdminBearer
+// @Router /api/v1/admin/payments/credit-lines/{customer_id} [put]
+func (h *PostpaidBillingAdminHandler) SetCreditLimit(c fiber.Ctx) error {
+	cidStr := c.Params("customer_id")
+	cid, err := strconv.ParseUint(cidStr, 10, 64)
+	if err != nil || cid == 0 {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid customer_id", "VALIDATION_ERROR", nil)
+	}
+	var req dto.AdminSetCustomerCreditLimitRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body", "INVALID_REQUEST", err.Error())
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		var validationErrors []string
+		for _, err := range err.(validator.ValidationErrors) {
+			validationErrors = append(validationErrors, getValidationErrorMessage(c, err))
+		}
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", validationErrors)
+	}
+	req.CustomerID = uint(cid)
+
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/admin/payments/credit-lines/"+cidStr, 30*time.Second)
+	defer cancel()
+	res, err := h.flow.SetCreditLimit(ctx, &req)
+	if err != nil {
+		return h.handleFlowError(c, "Failed to set customer credit limit", "SET_CUSTOMER_CREDIT_LIMIT_FAILED", err)
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, res.Message, res)
+}
+
+// ListInvoices returns postpaid invoices
+// @Summary List Postpaid Invoices (Admin)
+// @Description List monthly postpaid invoices, newest first
+// @Tags Payments Admin
+// @Produce json
+// @Param customer_id query int false "Filter by customer ID"
+// @Param status query string false "Filter by status (open|paid)"
+// @Param overdue_only query bool false "Only open invoices past their due date" default(false)
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Page size" default(10) maximum(100)
+// @Success 200 {object} dto.APIResponse{data=dto.AdminListPostpaidInvoicesResponse}
+// @Failure 400 {object} dto.APIResponse "Validation error"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security AdminBearer
+// @Router /api/v1/admin/payments/postpaid-invoices [get]
+func (h *PostpaidBillingAdminHandler) ListInvoices(c fiber.Ctx) error {
+	page, err := strconv.Atoi(c.Query("page", "1"))
+	if err != nil || page <= 0 {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid page format", "INVALID_PAGE", nil)
+	}
+	limit, err := strconv.Atoi(c.Query("limit", "10"))
+	if err != nil || limit <= 0 {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid limit format", "INVALID_LIMIT", nil)
+	}
+	overdueOnly, err := strconv.ParseBool(c.Query("overdue_only", "false"))
+	if err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", nil)
+	}
+
+	filter := dto.AdminListPostpaidInvoicesFilter{OverdueOnly: overdueOnly, Page: page, Limit: limit}
+	if v := strings.TrimSpace(c.Query("customer_id")); v != "" {
+		id, err := strconv.ParseUint(v, 10, 64)
+		if err != nil || id == 0 {
+			return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", nil)
+		}
+		filter.CustomerID = utils.ToPtr(uint(id))
+	}
+	if status := strings.TrimSpace(c.Query("status")); status != "" {
+		filter.Status = &status
+	}
+	if err := h.validator.Struct(filter); err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", nil)
+	}
+
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/admin/payments/postpaid-invoices", 30*time.Second)
+	defer cancel()
+	res, err := h.flow.ListInvoices(ctx, filter)
+	if err != nil {
+		return h.handleFlowError(c, "Failed to list postpaid invoices", "POSTPAID_INVOICE_LIST_FAILED", err)
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, res.Message, res)
+}
+
+// MarkInvoicePaid records the settlement of a postpaid invoice
+// @Summary Mark Postpaid Invoice Paid (Admin)
+// @Description Record that an open postpaid invoice was paid, e.g. by bank transfer. Its amount is deducted from what the customer owes, and new campaigns are no longer blocked once no invoice is overdue.
+// @Tags Payments Admin
+// @Accept json
+// @Produce json
+// @Param uuid path string true "Invoice UUID"
+// @Param body body dto.AdminMarkPostpaidInvoicePaidRequest true "Payment details"
+// @Success 200 {object} dto.APIResponse{data=dto.AdminPostpaidInvoiceResponse}
+// @Failure 400 {object} dto.APIResponse "Validation error"
+// @Failure 401 {object} dto.APIResponse "Unauthorized admin"
+// @Failure 404 {object} dto.APIResponse "Invoice not found"
+// @Failure 409 {object} dto.APIResponse "Invoice already paid"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security AdminBearer
+// @Router /api/v1/admin/payments/postpaid-invoices/{uuid}/paid [post]
+func (h *PostpaidBillingAdminHandler) MarkInvoicePaid(c fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("uuid"))
+	if err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid uuid", "INVALID_UUID", nil)
+	}
+	var req dto.AdminMarkPostpaidInvoicePaidRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body", "INVALID_REQUEST", err.Error())
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		var validationErrors []string
+		for _, err := range err.(validator.ValidationErrors) {
+			validationErrors = append(validationErrors, getValidationErrorMessage(c, err))
+		}
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", validationErrors)
+	}
+
+	adminID, ok := middleware.GetAdminIDFromContext(c)
+	if !ok || adminID == 0 {
+		return h.ErrorResponse(c, fiber.StatusUnauthorized, "Admin ID not found in context", "MISSING_ADMIN_ID", nil)
+	}
+
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/admin/payments/postpaid-invoices/paid", 30*time.Second)
+	defer cancel()
+	res, err := h.flow.MarkInvoicePaid(ctx, id, &req, adminID)
+	if err != nil {
+		return h.handleFlowError(c, "Failed to mark postpaid invoice paid", "POSTPAID_INVOICE_PAY_FAILED", err)
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, res.Message, res)
+}
+
+func (h *PostpaidBillingAdminHandler) handleFlowError(c fiber.Ctx, message, code string, err error) error {
+	switch {
+	case businessflow.IsCustomerNotFound(err):
+		return h.ErrorResponse(c, fiber.StatusNotFound, "Customer not found", "CUSTOMER_NOT_FOUND", nil)
+	case businessflow.IsPostpaidInvoiceNotFound(err):
+		return h.ErrorResponse(c, fiber.StatusNotFound, "Postpaid invoice not found", "POSTPAID_INVOICE_NOT_FOUND", nil)
+	case businessflow.IsPostpaidInvoiceAlreadyPaid(err):
+		return h.ErrorResponse(c, fiber.StatusConflict, "Postpaid invoice was already paid", "POSTPAID_INVOICE_ALREADY_PAID", nil)
+	}
+	log.Println(message+":", err)
+	return h.ErrorResponse(c, fiber.StatusInternalServerError, message, code, nil)
+}
+
+func (h *PostpaidBillingAdminHandler) createRequestContextWithTimeout(c fiber.Ctx, endpoint string, timeout time.Duration) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	ctx = context.WithValue(ctx, utils.RequestIDKey, c.Get("X-Request-ID"))
+	ctx = context.WithValue(ctx, utils.UserAgentKey, c.Get("User-Agent"))
+	ctx = context.WithValue(ctx, utils.IPAddressKey, c.IP())
+	ctx = context.WithValue(ctx, utils.EndpointKey, endpoint)
+	ctx = context.WithValue(ctx, utils.TimeoutKey, timeout)
+	ctx = context.WithValue(ctx, utils.CancelFuncKey, cancel)
+	if adminID, ok := middleware.GetAdminIDFromContext(c); ok {
+		ctx = context.WithValue(ctx, utils.AdminIDKey, adminID)
+	}
+	return ctx, cancel
+}
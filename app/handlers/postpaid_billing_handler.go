@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/apierror"
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/app/middleware"
+	businessflow "github.com/amirphl/Yamata-no-Orochi/business_flow"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/gofiber/fiber/v3"
+)
+
+// PostpaidBillingHandlerInterface defines customer endpoints for postpaid billing
+type PostpaidBillingHandlerInterface interface {
+	GetSummary(c fiber.Ctx) error
+}
+
+// PostpaidBillingHandler implements the customer postpaid billing endpoints
+type PostpaidBillingHandler struct {
+	flow businessflow.PostpaidBillingFlow
+}
+
+func NewPostpaidBillingHandler(flow businessflow.PostpaidBillingFlow) PostpaidBillingHandlerInterface {
+	return &PostpaidBillingHandler{flow: flow}
+}
+
+func (h *PostpaidBillingHandler) ErrorResponse(c fiber.Ctx, status int, message, code string, details any) error {
+	return apierror.Respond(c, status, message, code, details)
+}
+
+func (h *PostpaidBillingHandler) SuccessResponse(c fiber.Ctx, status int, message string, data any) error {
+	return c.Status(status).JSON(dto.APIResponse{Success: true, Message: message, Data: data})
+}
+
+// GetSummary returns the customer's credit line and postpaid invoices
+// @Summary Get Postpaid Summary
+// @Description Get the credit limit of the authenticated customer, what is outstanding on it and the last 12 monthly postpaid invoices. Campaign budgets beyond the wallet balance are drawn from the credit line; new campaigns are blocked while an invoice is overdue.
+// @Tags Payments
+// @Produce json
+// @Success 200 {object} dto.APIResponse{data=dto.GetPostpaidSummaryResponse}
+// @Failure 401 {object} dto.APIResponse "Unauthorized"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
+// @Router /api/v1/payments/postpaid [get]
+func (h *PostpaidBillingHandler) GetSummary(c fiber.Ctx) error {
+	customerID, ok := c.Locals("customer_id").(uint)
+	if !ok {
+		return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer ID not found in context", "MISSING_CUSTOMER_ID", nil)
+	}
+
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/payments/postpaid", 30*time.Second)
+	defer cancel()
+	res, err := h.flow.GetSummary(ctx, customerID)
+	if err != nil {
+		log.Println("Get postpaid summary failed", err)
+		return h.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to get postpaid summary", "GET_POSTPAID_SUMMARY_FAILED", nil)
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, res.Message, res)
+}
+
+func (h *PostpaidBillingHandler) createRequestContextWithTimeout(c fiber.Ctx, endpoint string, timeout time.Duration) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	ctx = context.WithValue(ctx, utils.RequestIDKey, c.Get("X-Request-ID"))
+	ctx = context.WithValue(ctx, utils.UserAgentKey, c.Get("User-Agent"))
+	ctx = context.WithValue(ctx, utils.IPAddressKey, c.IP())
+	ctx = context.WithValue(ctx, utils.EndpointKey, endpoint)
+	ctx = context.WithValue(ctx, utils.TimeoutKey, timeout)
+	ctx = context.WithValue(ctx, utils.CancelFuncKey, cancel)
+	ctx = middleware.WithImpersonation(ctx, c)
+	return ctx, cancel
+}
@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/apierror"
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/app/middleware"
+	businessflow "github.com/amirphl/Yamata-no-Orochi/business_flow"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v3"
+)
+
+// ProfileChangeAdminHandlerInterface defines admin endpoints for reviewing
+// changes of sensitive customer profile fields
+type ProfileChangeAdminHandlerInterface interface {
+	List(c fiber.Ctx) error
+	Review(c fiber.Ctx) error
+}
+
+// ProfileChangeAdminHandler implements the admin profile change endpoints
+type ProfileChangeAdminHandler struct {
+	flow      businessflow.ProfileFlow
+	validator *validator.Validate
+}
+
+func NewProfileChangeAdminHandler(flow businessflow.ProfileFlow) ProfileChangeAdminHandlerInterface {
+	return &ProfileChangeAdminHandler{
+		flow:      flow,
+		validator: validator.New(),
+	}
+}
+
+func (h *ProfileChangeAdminHandler) ErrorResponse(c fiber.Ctx, status int, message, code string, details any) error {
+	return apierror.Respond(c, status, message, code, details)
+}
+
+func (h *ProfileChangeAdminHandler) SuccessResponse(c fiber.Ctx, status int, message string, data any) error {
+	return c.Status(status).JSON(dto.APIResponse{Success: true, Message: message, Data: data})
+}
+
+// List returns customer profile changes by status
+// @Summary List Profile Changes (Admin)
+// @Description List customer profile field changes by status, longest waiting first. Defaults to the changes of sensitive fields (Sheba number, national ID) waiting for review.
+// @Tags Admin Profile Changes
+// @Produce json
+// @Param status query string false "Filter by status (applied|pending_review|rejected|superseded)" default(pending_review)
+// @Param customer_id query int false "Filter by customer ID"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Page size" default(10) maximum(100)
+// @Success 200 {object} dto.APIResponse{data=dto.AdminListProfileChangesResponse}
+// @Failure 400 {object} dto.APIResponse "Validation error"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security AdminBearer
+// @Router /api/v1/admin/profile-changes [get]
+func (h *ProfileChangeAdminHandler) List(c fiber.Ctx) error {
+	page, err := strconv.Atoi(c.Query("page", "1"))
+	if err != nil || page <= 0 {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid page format", "INVALID_PAGE", nil)
+	}
+	limit, err := strconv.Atoi(c.Query("limit", "10"))
+	if err != nil || limit <= 0 {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid limit format", "INVALID_LIMIT", nil)
+	}
+
+	filter := dto.AdminListProfileChangesFilter{Page: page, Limit: limit}
+	if status := strings.TrimSpace(c.Query("status")); status != "" {
+		filter.Status = &status
+	}
+	if cidStr := strings.TrimSpace(c.Query("customer_id")); cidStr != "" {
+		cid, err := strconv.ParseUint(cidStr, 10, 64)
+		if err != nil || cid == 0 {
+			return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid customer_id", "VALIDATION_ERROR", nil)
+		}
+		customerID := uint(cid)
+		filter.CustomerID = &customerID
+	}
+	if err := h.validator.Struct(filter); err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", nil)
+	}
+
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/admin/profile-changes", 30*time.Second)
+	defer cancel()
+	res, err := h.flow.AdminListProfileChanges(ctx, filter)
+	if err != nil {
+		log.Println("Admin list profile changes failed:", err)
+		return h.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to list profile changes", "ADMIN_LIST_PROFILE_CHANGES_FAILED", nil)
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, res.Message, res)
+}
+
+// Review approves or rejects a change of a sensitive profile field
+// @Summary Review Profile Change (Admin)
+// @Description Approve or reject a change of a sensitive profile field waiting for review. Approving writes the new value to the customer; rejecting needs a reason, which is shown to the customer, and keeps the old value.
+// @Tags Admin Profile Changes
+// @Accept json
+// @Produce json
+// @Param uuid path string true "Profile change UUID"
+// @Param request body dto.AdminReviewProfileChangeRequest true "Review decision"
+// @Success 200 {object} dto.APIResponse{data=dto.AdminReviewProfileChangeResponse}
+// @Failure 400 {object} dto.APIResponse "Validation error or missing rejection reason"
+// @Failure 404 {object} dto.APIResponse "Profile change not found"
+// @Failure 409 {object} dto.APIResponse "Change is not waiting for review or national ID belongs to another account"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security AdminBearer
+// @Router /api/v1/admin/profile-changes/{uuid}/review [post]
+func (h *ProfileChangeAdminHandler) Review(c fiber.Ctx) error {
+	var req dto.AdminReviewProfileChangeRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body", "INVALID_REQUEST", err.Error())
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		var validationErrors []string
+		for _, err := range err.(validator.ValidationErrors) {
+			validationErrors = append(validationErrors, getValidationErrorMessage(c, err))
+		}
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", validationErrors)
+	}
+	req.UUID = c.Params("uuid")
+
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/admin/profile-changes/"+req.UUID+"/review", 30*time.Second)
+	defer cancel()
+	res, err := h.flow.AdminReviewProfileChange(ctx, &req)
+	if err != nil {
+		if businessflow.IsProfileChangeNotFound(err) {
+			return h.ErrorResponse(c, fiber.StatusNotFound, "Profile change not found", "PROFILE_CHANGE_NOT_FOUND", nil)
+		}
+		if businessflow.IsProfileChangeNotPending(err) {
+			return h.ErrorResponse(c, fiber.StatusConflict, "Profile change is not waiting for review", "PROFILE_CHANGE_NOT_PENDING", nil)
+		}
+		if businessflow.IsProfileChangeRejectionReasonNeeded(err) {
+			return h.ErrorResponse(c, fiber.StatusBadRequest, "Rejection reason is required", "PROFILE_CHANGE_REJECTION_REASON_REQUIRED", nil)
+		}
+		if businessflow.IsNationalIDAlreadyExists(err) {
+			return h.ErrorResponse(c, fiber.StatusConflict, "National ID already exists", "NATIONAL_ID_EXISTS", nil)
+		}
+		if businessflow.IsCustomerNotFound(err) {
+			return h.ErrorResponse(c, fiber.StatusNotFound, "Customer not found", "CUSTOMER_NOT_FOUND", nil)
+		}
+		log.Println("Admin review profile change failed:", err)
+		return h.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to review profile change", "ADMIN_REVIEW_PROFILE_CHANGE_FAILED", nil)
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, res.Message, res)
+}
+
+func (h *ProfileChangeAdminHandler) createRequestContextWithTimeout(c fiber.Ctx, endpoint string, timeout time.Duration) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	ctx = context.WithValue(ctx, utils.RequestIDKey, c.Get("X-Request-ID"))
+	ctx = context.WithValue(ctx, utils.UserAgentKey, c.Get("User-Agent"))
+	ctx = context.WithValue(ctx, utils.IPAddressKey, c.IP())
+	ctx = context.WithValue(ctx, utils.EndpointKey, endpoint)
+	ctx = context.WithValue(ctx, utils.TimeoutKey, timeout)
+	ctx = context.WithValue(ctx, utils.CancelFuncKey, cancel)
+	if adminID, ok := middleware.GetAdminIDFromContext(c); ok {
+		ctx = context.WithValue(ctx, utils.AdminIDKey, adminID)
+	}
+	return ctx, cancel
+}
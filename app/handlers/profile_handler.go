@@ -2,35 +2,42 @@ package handlers
 
 import (
 	"context"
+	"log"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/amirphl/Yamata-no-Orochi/app/apierror"
 	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/app/middleware"
 	businessflow "github.com/amirphl/Yamata-no-Orochi/business_flow"
 	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v3"
 )
 
 type ProfileHandlerInterface interface {
 	GetProfile(c fiber.Ctx) error
+	UpdateLocale(c fiber.Ctx) error
+	UpdateCompanyInfo(c fiber.Ctx) error
+	UpdateAddress(c fiber.Ctx) error
+	UpdateSheba(c fiber.Ctx) error
+	ListChanges(c fiber.Ctx) error
+	VerifySheba(c fiber.Ctx) error
 }
 
 type ProfileHandler struct {
-	flow businessflow.ProfileFlow
+	flow      businessflow.ProfileFlow
+	shebaFlow businessflow.ShebaVerificationFlow
+	validator *validator.Validate
 }
 
-func NewProfileHandler(flow businessflow.ProfileFlow) *ProfileHandler {
-	return &ProfileHandler{flow: flow}
+func NewProfileHandler(flow businessflow.ProfileFlow, shebaFlow businessflow.ShebaVerificationFlow) *ProfileHandler {
+	return &ProfileHandler{flow: flow, shebaFlow: shebaFlow, validator: validator.New()}
 }
 
 func (h *ProfileHandler) ErrorResponse(c fiber.Ctx, statusCode int, message, errorCode string, details any) error {
-	return c.Status(statusCode).JSON(dto.APIResponse{
-		Success: false,
-		Message: message,
-		Error: dto.ErrorDetail{
-			Code:    errorCode,
-			Details: details,
-		},
-	})
+	return apierror.Respond(c, statusCode, message, errorCode, details)
 }
 
 func (h *ProfileHandler) SuccessResponse(c fiber.Ctx, statusCode int, message string, data any) error {
@@ -49,6 +56,7 @@ func (h *ProfileHandler) SuccessResponse(c fiber.Ctx, statusCode int, message st
 // @Success 200 {object} dto.APIResponse{data=dto.GetProfileResponse} "Profile retrieved successfully"
 // @Failure 401 {object} dto.APIResponse "Unauthorized"
 // @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
 // @Router /api/v1/profile [get]
 func (h *ProfileHandler) GetProfile(c fiber.Ctx) error {
 	customerID, ok := c.Locals("customer_id").(uint)
@@ -69,6 +77,244 @@ func (h *ProfileHandler) GetProfile(c fiber.Ctx) error {
 	})
 }
 
+// UpdateLocale sets the language SMS, emails and pages are sent to the customer in
+// @Summary Update preferred locale
+// @Description Set the language ("en" or "fa") of SMS, emails and pages sent to the authenticated customer
+// @Tags Profile
+// @Accept json
+// @Produce json
+// @Param request body dto.UpdateLocaleRequest true "Preferred locale"
+// @Success 200 {object} dto.APIResponse{data=dto.UpdateLocaleResponse} "Locale updated"
+// @Failure 400 {object} dto.APIResponse "Unsupported locale"
+// @Failure 401 {object} dto.APIResponse "Unauthorized"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
+// @Router /api/v1/profile/locale [put]
+func (h *ProfileHandler) UpdateLocale(c fiber.Ctx) error {
+	customerID, ok := c.Locals("customer_id").(uint)
+	if !ok {
+		return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer ID not found in context", "MISSING_CUSTOMER_ID", nil)
+	}
+
+	var req dto.UpdateLocaleRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body", "INVALID_REQUEST", err.Error())
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		var validationErrors []string
+		for _, err := range err.(validator.ValidationErrors) {
+			validationErrors = append(validationErrors, getValidationErrorMessage(c, err))
+		}
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", validationErrors)
+	}
+
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/profile/locale", 30*time.Second)
+	defer cancel()
+	res, err := h.flow.UpdatePreferredLocale(ctx, customerID, &req)
+	if err != nil {
+		if businessflow.IsCustomerNotFound(err) {
+			return h.ErrorResponse(c, fiber.StatusNotFound, "Customer not found", "CUSTOMER_NOT_FOUND", nil)
+		}
+		return h.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to update locale", "UPDATE_LOCALE_FAILED", nil)
+	}
+
+	return h.SuccessResponse(c, fiber.StatusOK, res.Message, res)
+}
+
+// UpdateCompanyInfo changes the company fields of a company account
+// @Summary Update company information
+// @Description Change the company name, national ID and phone of a company or agency account; omitted fields are kept. Name and phone change at once; a national ID change waits for an admin to verify it. Every change is recorded in the profile change history.
+// @Tags Profile
+// @Accept json
+// @Produce json
+// @Param request body dto.UpdateCompanyInfoRequest true "Company fields to change"
+// @Success 200 {object} dto.APIResponse{data=dto.UpdateProfileResponse} "Changes applied or waiting for review"
+// @Failure 400 {object} dto.APIResponse "Validation error or not a company account"
+// @Failure 401 {object} dto.APIResponse "Unauthorized"
+// @Failure 409 {object} dto.APIResponse "National ID belongs to another account"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
+// @Router /api/v1/profile/company [put]
+func (h *ProfileHandler) UpdateCompanyInfo(c fiber.Ctx) error {
+	var req dto.UpdateCompanyInfoRequest
+	return h.updateProfile(c, &req, "/api/v1/profile/company", func(ctx context.Context, customerID uint, metadata *businessflow.ClientMetadata) (*dto.UpdateProfileResponse, error) {
+		return h.flow.UpdateCompanyInfo(ctx, customerID, &req, metadata)
+	})
+}
+
+// UpdateAddress changes the address fields
+// @Summary Update address
+// @Description Change the address and postal code; omitted fields are kept. Every change is recorded in the profile change history.
+// @Tags Profile
+// @Accept json
+// @Produce json
+// @Param request body dto.UpdateAddressRequest true "Address fields to change"
+// @Success 200 {object} dto.APIResponse{data=dto.UpdateProfileResponse} "Changes applied"
+// @Failure 400 {object} dto.APIResponse "Validation error"
+// @Failure 401 {object} dto.APIResponse "Unauthorized"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
+// @Router /api/v1/profile/address [put]
+func (h *ProfileHandler) UpdateAddress(c fiber.Ctx) error {
+	var req dto.UpdateAddressRequest
+	return h.updateProfile(c, &req, "/api/v1/profile/address", func(ctx context.Context, customerID uint, metadata *businessflow.ClientMetadata) (*dto.UpdateProfileResponse, error) {
+		return h.flow.UpdateAddress(ctx, customerID, &req, metadata)
+	})
+}
+
+// UpdateSheba changes the Sheba number payouts go to
+// @Summary Update Sheba number
+// @Description Change the Sheba number payouts go to. The change waits for an admin to verify it; the current number stays in use until then. A newer change replaces one still waiting.
+// @Tags Profile
+// @Accept json
+// @Produce json
+// @Param request body dto.UpdateShebaRequest true "New Sheba number"
+// @Success 200 {object} dto.APIResponse{data=dto.UpdateProfileResponse} "Change waiting for review"
+// @Failure 400 {object} dto.APIResponse "Validation error or invalid Sheba number"
+// @Failure 401 {object} dto.APIResponse "Unauthorized"
+// @Failure 403 {object} dto.APIResponse "Not allowed while impersonating the customer"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
+// @Router /api/v1/profile/sheba [put]
+func (h *ProfileHandler) UpdateSheba(c fiber.Ctx) error {
+	var req dto.UpdateShebaRequest
+	return h.updateProfile(c, &req, "/api/v1/profile/sheba", func(ctx context.Context, customerID uint, metadata *businessflow.ClientMetadata) (*dto.UpdateProfileResponse, error) {
+		return h.flow.UpdateSheba(ctx, customerID, &req, metadata)
+	})
+}
+
+// ListChanges returns the customer's profile change history
+// @Summary List profile changes
+// @Description List the history of the customer's profile field changes, newest first, with the old and new value and whether the change was applied, waits for review, was rejected or superseded
+// @Tags Profile
+// @Produce json
+// @Param status query string false "Filter by status (applied|pending_review|rejected|superseded)"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Page size" default(10) maximum(100)
+// @Success 200 {object} dto.APIResponse{data=dto.ListProfileChangesResponse}
+// @Failure 400 {object} dto.APIResponse "Validation error"
+// @Failure 401 {object} dto.APIResponse "Unauthorized"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
+// @Router /api/v1/profile/changes [get]
+func (h *ProfileHandler) ListChanges(c fiber.Ctx) error {
+	customerID, ok := c.Locals("customer_id").(uint)
+	if !ok {
+		return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer ID not found in context", "MISSING_CUSTOMER_ID", nil)
+	}
+	page, err := strconv.Atoi(c.Query("page", "1"))
+	if err != nil || page <= 0 {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid page format", "INVALID_PAGE", nil)
+	}
+	limit, err := strconv.Atoi(c.Query("limit", "10"))
+	if err != nil || limit <= 0 {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid limit format", "INVALID_LIMIT", nil)
+	}
+
+	filter := dto.ListProfileChangesFilter{Page: page, Limit: limit}
+	if status := strings.TrimSpace(c.Query("status")); status != "" {
+		filter.Status = &status
+	}
+	if err := h.validator.Struct(filter); err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", nil)
+	}
+
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/profile/changes", 30*time.Second)
+	defer cancel()
+	res, err := h.flow.ListProfileChanges(ctx, customerID, filter)
+	if err != nil {
+		log.Println("List profile changes failed:", err)
+		return h.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to list profile changes", "LIST_PROFILE_CHANGES_FAILED", nil)
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, res.Message, res)
+}
+
+// VerifySheba checks with the bank who owns the customer's Sheba number
+// @Summary Verify Sheba number
+// @Description Look up the owner of the customer's current Sheba number with the bank and check it is the company, or the representative for individual accounts. Agencies need a verified Sheba number before wallet recharges of their customers are split with them. Inquiry results are reused for a while, so retrying right away gives the same answer.
+// @Tags Profile
+// @Produce json
+// @Success 200 {object} dto.APIResponse{data=dto.ShebaVerificationResponse} "Inquiry result: verified, owner_mismatch or inactive"
+// @Failure 400 {object} dto.APIResponse "No valid Sheba number on the profile"
+// @Failure 401 {object} dto.APIResponse "Unauthorized"
+// @Failure 403 {object} dto.APIResponse "Not allowed while impersonating the customer"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Failure 503 {object} dto.APIResponse "Bank inquiry not configured or unavailable"
+// @Security CustomerBearer
+// @Router /api/v1/profile/sheba/verify [post]
+func (h *ProfileHandler) VerifySheba(c fiber.Ctx) error {
+	customerID, ok := c.Locals("customer_id").(uint)
+	if !ok {
+		return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer ID not found in context", "MISSING_CUSTOMER_ID", nil)
+	}
+
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/profile/sheba/verify", 30*time.Second)
+	defer cancel()
+	res, err := h.shebaFlow.VerifySheba(ctx, customerID, businessflow.NewClientMetadata(c.IP(), c.Get("User-Agent")))
+	if err != nil {
+		if businessflow.IsShebaInquiryNotConfigured(err) {
+			return h.ErrorResponse(c, fiber.StatusServiceUnavailable, "Sheba verification is not configured", "SHEBA_INQUIRY_NOT_CONFIGURED", nil)
+		}
+		if businessflow.IsShebaInquiryUnavailable(err) {
+			return h.ErrorResponse(c, fiber.StatusServiceUnavailable, "Bank inquiry is unavailable, try again later", "SHEBA_INQUIRY_UNAVAILABLE", nil)
+		}
+		if businessflow.IsShebaNumberInvalid(err) || businessflow.IsShebaNumberRequired(err) {
+			return h.ErrorResponse(c, fiber.StatusBadRequest, "Sheba number is invalid", "SHEBA_NUMBER_INVALID", nil)
+		}
+		if businessflow.IsCustomerNotFound(err) {
+			return h.ErrorResponse(c, fiber.StatusNotFound, "Customer not found", "CUSTOMER_NOT_FOUND", nil)
+		}
+		if businessflow.IsAccountInactive(err) {
+			return h.ErrorResponse(c, fiber.StatusForbidden, "Account is inactive", "ACCOUNT_INACTIVE", nil)
+		}
+		log.Println("Verify Sheba failed:", err)
+		return h.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to verify Sheba number", "VERIFY_SHEBA_FAILED", nil)
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, res.Message, res)
+}
+
+// updateProfile binds and validates req and runs update for the customer
+func (h *ProfileHandler) updateProfile(c fiber.Ctx, req any, endpoint string, update func(context.Context, uint, *businessflow.ClientMetadata) (*dto.UpdateProfileResponse, error)) error {
+	customerID, ok := c.Locals("customer_id").(uint)
+	if !ok {
+		return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer ID not found in context", "MISSING_CUSTOMER_ID", nil)
+	}
+	if err := c.Bind().JSON(req); err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body", "INVALID_REQUEST", err.Error())
+	}
+	if err := h.validator.Struct(req); err != nil {
+		var validationErrors []string
+		for _, err := range err.(validator.ValidationErrors) {
+			validationErrors = append(validationErrors, getValidationErrorMessage(c, err))
+		}
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", validationErrors)
+	}
+
+	ctx, cancel := h.createRequestContextWithTimeout(c, endpoint, 30*time.Second)
+	defer cancel()
+	res, err := update(ctx, customerID, businessflow.NewClientMetadata(c.IP(), c.Get("User-Agent")))
+	if err != nil {
+		if businessflow.IsCompanyProfileNotApplicable(err) {
+			return h.ErrorResponse(c, fiber.StatusBadRequest, "Only company accounts have company information", "COMPANY_PROFILE_NOT_APPLICABLE", nil)
+		}
+		if businessflow.IsShebaNumberInvalid(err) || businessflow.IsShebaNumberRequired(err) {
+			return h.ErrorResponse(c, fiber.StatusBadRequest, "Sheba number is invalid", "SHEBA_NUMBER_INVALID", nil)
+		}
+		if businessflow.IsNationalIDAlreadyExists(err) {
+			return h.ErrorResponse(c, fiber.StatusConflict, "National ID already exists", "NATIONAL_ID_EXISTS", nil)
+		}
+		if businessflow.IsCustomerNotFound(err) {
+			return h.ErrorResponse(c, fiber.StatusNotFound, "Customer not found", "CUSTOMER_NOT_FOUND", nil)
+		}
+		if businessflow.IsAccountInactive(err) {
+			return h.ErrorResponse(c, fiber.StatusForbidden, "Account is inactive", "ACCOUNT_INACTIVE", nil)
+		}
+		log.Println("Update profile failed:", err)
+		return h.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to update profile", "UPDATE_PROFILE_FAILED", nil)
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, res.Message, res)
+}
+
 func (h *ProfileHandler) createRequestContextWithTimeout(c fiber.Ctx, endpoint string, timeout time.Duration) (context.Context, context.CancelFunc) {
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	ctx = context.WithValue(ctx, utils.RequestIDKey, c.Get("X-Request-ID"))
@@ -77,5 +323,6 @@ func (h *ProfileHandler) createRequestContextWithTimeout(c fiber.Ctx, endpoint s
 	ctx = context.WithValue(ctx, utils.EndpointKey, endpoint)
 	ctx = context.WithValue(ctx, utils.TimeoutKey, timeout)
 	ctx = context.WithValue(ctx, utils.CancelFuncKey, cancel)
+	ctx = middleware.WithImpersonation(ctx, c)
 	return ctx, cancel
 }
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/apierror"
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/app/middleware"
+	businessflow "github.com/amirphl/Yamata-no-Orochi/business_flow"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v3"
+)
+
+// PushAdminHandlerInterface defines the admin push broadcast endpoint
+type PushAdminHandlerInterface interface {
+	Broadcast(c fiber.Ctx) error
+}
+
+// PushAdminHandler implements the admin push broadcast endpoint
+type PushAdminHandler struct {
+	flow      businessflow.PushDeviceFlow
+	validator *validator.Validate
+}
+
+func NewPushAdminHandler(flow businessflow.PushDeviceFlow) PushAdminHandlerInterface {
+	return &PushAdminHandler{
+		flow:      flow,
+		validator: validator.New(),
+	}
+}
+
+func (h *PushAdminHandler) ErrorResponse(c fiber.Ctx, status int, message, code string, details any) error {
+	return apierror.Respond(c, status, message, code, details)
+}
+
+func (h *PushAdminHandler) SuccessResponse(c fiber.Ctx, status int, message string, data any) error {
+	return c.Status(status).JSON(dto.APIResponse{Success: true, Message: message, Data: data})
+}
+
+// Broadcast sends a push notification to every subscribed device
+// @Summary Admin Broadcast Push Notification
+// @Description Send a push notification to the broadcast topic every registered customer device is subscribed to, e.g. for maintenance windows or announcements. link is opened when a web notification is clicked. Every broadcast is audited.
+// @Tags Admin Notifications
+// @Accept json
+// @Produce json
+// @Param request body dto.AdminBroadcastPushRequest true "Notification"
+// @Success 200 {object} dto.APIResponse{data=dto.AdminBroadcastPushResponse}
+// @Failure 400 {object} dto.APIResponse "Validation error"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Failure 503 {object} dto.APIResponse "Push notifications are not configured"
+// @Security AdminBearer
+// @Router /api/v1/admin/notifications/push/broadcast [post]
+func (h *PushAdminHandler) Broadcast(c fiber.Ctx) error {
+	var req dto.AdminBroadcastPushRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body", "INVALID_REQUEST", err.Error())
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		var validationErrors []string
+		for _, err := range err.(validator.ValidationErrors) {
+			validationErrors = append(validationErrors, getValidationErrorMessage(c, err))
+		}
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", validationErrors)
+	}
+
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/admin/notifications/push/broadcast", 15*time.Second)
+	defer cancel()
+	res, err := h.flow.Broadcast(ctx, &req)
+	if err != nil {
+		if businessflow.IsPushNotConfigured(err) {
+			return h.ErrorResponse(c, fiber.StatusServiceUnavailable, "Push notifications are not configured", "PUSH_NOT_CONFIGURED", nil)
+		}
+		log.Println("Admin push broadcast failed:", err)
+		return h.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to send push broadcast", "BROADCAST_PUSH_FAILED", nil)
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, res.Message, res)
+}
+
+func (h *PushAdminHandler) createRequestContextWithTimeout(c fiber.Ctx, endpoint string, timeout time.Duration) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	ctx = context.WithValue(ctx, utils.RequestIDKey, c.Get("X-Request-ID"))
+	ctx = context.WithValue(ctx, utils.UserAgentKey, c.Get("User-Agent"))
+	ctx = context.WithValue(ctx, utils.IPAddressKey, c.IP())
+	ctx = context.WithValue(ctx, utils.EndpointKey, endpoint)
+	ctx = context.WithValue(ctx, utils.TimeoutKey, timeout)
+	ctx = context.WithValue(ctx, utils.CancelFuncKey, cancel)
+	if adminID, ok := middleware.GetAdminIDFromContext(c); ok {
+		ctx = context.WithValue(ctx, utils.AdminIDKey, adminID)
+	}
+	return ctx, cancel
+}
@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/apierror"
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/app/middleware"
+	businessflow "github.com/amirphl/Yamata-no-Orochi/business_flow"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v3"
+)
+
+// PushHandlerInterface defines the push device endpoints of the dashboard PWA
+type PushHandlerInterface interface {
+	RegisterDevice(c fiber.Ctx) error
+	UnregisterDevice(c fiber.Ctx) error
+	ListDevices(c fiber.Ctx) error
+}
+
+// PushHandler implements the push device endpoints
+type PushHandler struct {
+	flow      businessflow.PushDeviceFlow
+	validator *validator.Validate
+}
+
+func NewPushHandler(flow businessflow.PushDeviceFlow) PushHandlerInterface {
+	return &PushHandler{
+		flow:      flow,
+		validator: validator.New(),
+	}
+}
+
+func (h *PushHandler) ErrorResponse(c fiber.Ctx, status int, message, code string, details any) error {
+	return apierror.Respond(c, status, message, code, details)
+}
+
+func (h *PushHandler) SuccessResponse(c fiber.Ctx, status int, message string, data any) error {
+	return c.Status(status).JSON(dto.APIResponse{Success: true, Message: message, Data: data})
+}
+
+// RegisterDevice registers an FCM token
+// @Summary Register Push Device
+// @Description Register the FCM registration token of the browser or app. Call it on every start and whenever the token changes; a known token is refreshed, or moved to this customer when another one registered it. Campaign status and payment notices are pushed to every registered device, and the device is subscribed to admin announcements. Only the 10 most recently seen devices are kept.
+// @Tags Notifications
+// @Accept json
+// @Produce json
+// @Param request body dto.RegisterPushDeviceRequest true "Device token"
+// @Success 200 {object} dto.APIResponse{data=dto.RegisterPushDeviceResponse}
+// @Failure 400 {object} dto.APIResponse "Validation error"
+// @Failure 401 {object} dto.APIResponse "Unauthorized"
+// @Failure 403 {object} dto.APIResponse "Not allowed while impersonating the customer"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Failure 503 {object} dto.APIResponse "Push notifications are not configured"
+// @Security CustomerBearer
+// @Router /api/v1/notifications/push/devices [post]
+func (h *PushHandler) RegisterDevice(c fiber.Ctx) error {
+	var req dto.RegisterPushDeviceRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body", "INVALID_REQUEST", err.Error())
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		var validationErrors []string
+		for _, err := range err.(validator.ValidationErrors) {
+			validationErrors = append(validationErrors, getValidationErrorMessage(c, err))
+		}
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", validationErrors)
+	}
+
+	customerID, ok := c.Locals("customer_id").(uint)
+	if !ok {
+		return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer ID not found in context", "MISSING_CUSTOMER_ID", nil)
+	}
+	req.CustomerID = customerID
+	req.UserAgent = c.Get("User-Agent")
+
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/notifications/push/devices", 15*time.Second)
+	defer cancel()
+	res, err := h.flow.RegisterDevice(ctx, &req)
+	if err != nil {
+		return h.handleError(c, "Register push device failed:", err, "Failed to register push device", "REGISTER_PUSH_DEVICE_FAILED")
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, res.Message, res)
+}
+
+// UnregisterDevice removes an FCM token
+// @Summary Unregister Push Device
+// @Description Remove a registered FCM token, e.g. when the customer signs out or turns notifications off in the browser.
+// @Tags Notifications
+// @Accept json
+// @Produce json
+// @Param request body dto.UnregisterPushDeviceRequest true "Device token"
+// @Success 200 {object} dto.APIResponse{data=dto.UnregisterPushDeviceResponse}
+// @Failure 400 {object} dto.APIResponse "Validation error"
+// @Failure 401 {object} dto.APIResponse "Unauthorized"
+// @Failure 404 {object} dto.APIResponse "Token is not registered"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
+// @Router /api/v1/notifications/push/devices [delete]
+func (h *PushHandler) UnregisterDevice(c fiber.Ctx) error {
+	var req dto.UnregisterPushDeviceRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body", "INVALID_REQUEST", err.Error())
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		var validationErrors []string
+		for _, err := range err.(validator.ValidationErrors) {
+			validationErrors = append(validationErrors, getValidationErrorMessage(c, err))
+		}
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", validationErrors)
+	}
+
+	customerID, ok := c.Locals("customer_id").(uint)
+	if !ok {
+		return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer ID not found in context", "MISSING_CUSTOMER_ID", nil)
+	}
+	req.CustomerID = customerID
+
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/notifications/push/devices", 10*time.Second)
+	defer cancel()
+	res, err := h.flow.UnregisterDevice(ctx, &req)
+	if err != nil {
+		return h.handleError(c, "Unregister push device failed:", err, "Failed to remove push device", "UNREGISTER_PUSH_DEVICE_FAILED")
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, res.Message, res)
+}
+
+// ListDevices returns the registered devices
+// @Summary List Push Devices
+// @Description Return whether push notifications are available and the customer's registered devices, most recently seen first. Tokens are not returned.
+// @Tags Notifications
+// @Produce json
+// @Success 200 {object} dto.APIResponse{data=dto.ListPushDevicesResponse}
+// @Failure 401 {object} dto.APIResponse "Unauthorized"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
+// @Router /api/v1/notifications/push/devices [get]
+func (h *PushHandler) ListDevices(c fiber.Ctx) error {
+	customerID, ok := c.Locals("customer_id").(uint)
+	if !ok {
+		return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer ID not found in context", "MISSING_CUSTOMER_ID", nil)
+	}
+
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/notifications/push/devices", 10*time.Second)
+	defer cancel()
+	res, err := h.flow.ListDevices(ctx, customerID)
+	if err != nil {
+		return h.handleError(c, "List push devices failed:", err, "Failed to list push devices", "LIST_PUSH_DEVICES_FAILED")
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, "Push devices retrieved", res)
+}
+
+func (h *PushHandler) handleError(c fiber.Ctx, logPrefix string, err error, message, code string) error {
+	switch {
+	case businessflow.IsCustomerNotFound(err):
+		return h.ErrorResponse(c, fiber.StatusNotFound, "Customer not found", "CUSTOMER_NOT_FOUND", nil)
+	case businessflow.IsPushNotConfigured(err):
+		return h.ErrorResponse(c, fiber.StatusServiceUnavailable, "Push notifications are not configured", "PUSH_NOT_CONFIGURED", nil)
+	case businessflow.IsPushDeviceNotFound(err):
+		return h.ErrorResponse(c, fiber.StatusNotFound, "Push device not found", "PUSH_DEVICE_NOT_FOUND", nil)
+	}
+	log.Println(logPrefix, err)
+	return h.ErrorResponse(c, fiber.StatusInternalServerError, message, code, nil)
+}
+
+func (h *PushHandler) createRequestContextWithTimeout(c fiber.Ctx, endpoint string, timeout time.Duration) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	ctx = context.WithValue(ctx, utils.RequestIDKey, c.Get("X-Request-ID"))
+	ctx = context.WithValue(ctx, utils.UserAgentKey, c.Get("User-Agent"))
+	ctx = context.WithValue(ctx, utils.IPAddressKey, c.IP())
+	ctx = context.WithValue(ctx, utils.EndpointKey, endpoint)
+	ctx = context.WithValue(ctx, utils.TimeoutKey, timeout)
+	ctx = context.WithValue(ctx, utils.CancelFuncKey, cancel)
+	ctx = middleware.WithImpersonation(ctx, c)
+	return ctx, cancel
+}
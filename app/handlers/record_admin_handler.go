@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/apierror"
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/app/middleware"
+	businessflow "github.com/amirphl/Yamata-no-Orochi/business_flow"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/gofiber/fiber/v3"
+)
+
+// RecordAdminHandlerInterface defines admin endpoints that soft-delete and
+// restore records
+type RecordAdminHandlerInterface interface {
+	DeleteRecord(c fiber.Ctx) error
+	RestoreRecord(c fiber.Ctx) error
+}
+
+// RecordAdminHandler implements the admin record endpoints
+type RecordAdminHandler struct {
+	flow businessflow.RecordAdminFlow
+}
+
+func NewRecordAdminHandler(flow businessflow.RecordAdminFlow) RecordAdminHandlerInterface {
+	return &RecordAdminHandler{flow: flow}
+}
+
+func (h *RecordAdminHandler) ErrorResponse(c fiber.Ctx, status int, message, code string, details any) error {
+	return apierror.Respond(c, status, message, code, details)
+}
+
+func (h *RecordAdminHandler) SuccessResponse(c fiber.Ctx, status int, message string, data any) error {
+	return c.Status(status).JSON(dto.APIResponse{Success: true, Message: message, Data: data})
+}
+
+// DeleteRecord soft-deletes a record
+// @Summary Admin Delete Record
+// @Description Soft-delete a campaign, audience profile, tag or line number. Deleted records are hidden from listings and lookups but kept for reports and audits, and can be restored. Campaigns must be drafts or finished, and line numbers must be deactivated first.
+// @Tags Admin Records
+// @Produce json
+// @Param kind path string true "Record kind" Enums(campaigns, audience-profiles, tags, line-numbers)
+// @Param id path int true "Record ID"
+// @Success 200 {object} dto.APIResponse{data=dto.AdminRecordResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Failure 404 {object} dto.APIResponse
+// @Failure 409 {object} dto.APIResponse "Record is already deleted or cannot be deleted in its current state"
+// @Failure 500 {object} dto.APIResponse
+// @Security AdminBearer
+// @Router /api/v1/admin/records/{kind}/{id} [delete]
+func (h *RecordAdminHandler) DeleteRecord(c fiber.Ctx) error {
+	req, err := h.parseRequest(c)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/admin/records/"+req.Kind, 30*time.Second)
+	defer cancel()
+	res, err := h.flow.Delete(ctx, req)
+	if err != nil {
+		return h.handleError(c, err, "Failed to delete record", "DELETE_RECORD_FAILED")
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, res.Message, res)
+}
+
+// RestoreRecord restores a soft-deleted record
+// @Summary Admin Restore Record
+// @Description Restore a soft-deleted campaign, audience profile, tag or line number as it was when deleted. Restored line numbers stay inactive until reactivated.
+// @Tags Admin Records
+// @Produce json
+// @Param kind path string true "Record kind" Enums(campaigns, audience-profiles, tags, line-numbers)
+// @Param id path int true "Record ID"
+// @Success 200 {object} dto.APIResponse{data=dto.AdminRecordResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Failure 404 {object} dto.APIResponse
+// @Failure 409 {object} dto.APIResponse "Record is not deleted"
+// @Failure 500 {object} dto.APIResponse
+// @Security AdminBearer
+// @Router /api/v1/admin/records/{kind}/{id}/restore [post]
+func (h *RecordAdminHandler) RestoreRecord(c fiber.Ctx) error {
+	req, err := h.parseRequest(c)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/admin/records/"+req.Kind, 30*time.Second)
+	defer cancel()
+	res, err := h.flow.Restore(ctx, req)
+	if err != nil {
+		return h.handleError(c, err, "Failed to restore record", "RESTORE_RECORD_FAILED")
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, res.Message, res)
+}
+
+// parseRequest reads the record kind and ID; on a bad ID it writes the
+// error response and returns its result as the error
+func (h *RecordAdminHandler) parseRequest(c fiber.Ctx) (*dto.AdminRecordRequest, error) {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil || id == 0 {
+		return nil, h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid id", "VALIDATION_ERROR", nil)
+	}
+	return &dto.AdminRecordRequest{Kind: c.Params("kind"), ID: uint(id)}, nil
+}
+
+func (h *RecordAdminHandler) handleError(c fiber.Ctx, err error, defaultMessage, defaultCode string) error {
+	switch {
+	case businessflow.IsRecordKindInvalid(err):
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Unknown record kind", "RECORD_KIND_INVALID", nil)
+	case businessflow.IsRecordNotFound(err):
+		return h.ErrorResponse(c, fiber.StatusNotFound, "Record not found", "RECORD_NOT_FOUND", nil)
+	case businessflow.IsRecordNotDeletable(err):
+		var details any
+		var be *businessflow.BusinessError
+		if errors.As(err, &be) {
+			details = be.Message
+		}
+		return h.ErrorResponse(c, fiber.StatusConflict, "Record cannot be deleted in its current state", "RECORD_NOT_DELETABLE", details)
+	case businessflow.IsRecordAlreadyDeleted(err):
+		return h.ErrorResponse(c, fiber.StatusConflict, "Record is already deleted", "RECORD_ALREADY_DELETED", nil)
+	case businessflow.IsRecordNotDeleted(err):
+		return h.ErrorResponse(c, fiber.StatusConflict, "Record is not deleted", "RECORD_NOT_DELETED", nil)
+	}
+	log.Println(defaultMessage, err)
+	return h.ErrorResponse(c, fiber.StatusInternalServerError, defaultMessage, defaultCode, nil)
+}
+
+func (h *RecordAdminHandler) createRequestContextWithTimeout(c fiber.Ctx, endpoint string, timeout time.Duration) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	ctx = context.WithValue(ctx, utils.RequestIDKey, c.Get("X-Request-ID"))
+	ctx = context.WithValue(ctx, utils.UserAgentKey, c.Get("User-Agent"))
+	ctx = context.WithValue(ctx, utils.IPAddressKey, c.IP())
+	ctx = context.WithValue(ctx, utils.EndpointKey, endpoint)
+	ctx = context.WithValue(ctx, utils.TimeoutKey, timeout)
+	ctx = context.WithValue(ctx, utils.CancelFuncKey, cancel)
+	if adminID, ok := middleware.GetAdminIDFromContext(c); ok {
+		ctx = context.WithValue(ctx, utils.AdminIDKey, adminID)
+	}
+	return ctx, cancel
+}
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/apierror"
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/app/middleware"
+	businessflow "github.com/amirphl/Yamata-no-Orochi/business_flow"
+	"github.com/amirphl/Yamata-no-Orochi/config"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/gofiber/fiber/v3"
+)
+
+type RuntimeConfigAdminHandlerInterface interface {
+	Get(c fiber.Ctx) error
+	Reload(c fiber.Ctx) error
+}
+
+type RuntimeConfigAdminHandler struct {
+	flow businessflow.RuntimeConfigFlow
+}
+
+func NewRuntimeConfigAdminHandler(flow businessflow.RuntimeConfigFlow) RuntimeConfigAdminHandlerInterface {
+	return &RuntimeConfigAdminHandler{flow: flow}
+}
+
+func (h *RuntimeConfigAdminHandler) ErrorResponse(c fiber.Ctx, statusCode int, message, errorCode string, details any) error {
+	return apierror.Respond(c, statusCode, message, errorCode, details)
+}
+
+func (h *RuntimeConfigAdminHandler) SuccessResponse(c fiber.Ctx, statusCode int, message string, data any) error {
+	return c.Status(statusCode).JSON(dto.APIResponse{
+		Success: true,
+		Message: message,
+		Data:    data,
+	})
+}
+
+// Get lists the reloadable settings in effect on this instance.
+// @Summary Admin get runtime configuration
+// @Description List the settings that can be reloaded without a restart and their values on the instance serving the request
+// @Tags Admin Configuration
+// @Produce json
+// @Success 200 {object} dto.APIResponse{data=dto.AdminRuntimeConfigResponse} "Retrieved"
+// @Security AdminBearer
+// @Router /api/v1/admin/config [get]
+func (h *RuntimeConfigAdminHandler) Get(c fiber.Ctx) error {
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/admin/config", 10*time.Second)
+	defer cancel()
+	res, err := h.flow.GetRuntimeConfig(ctx)
+	if err != nil {
+		return h.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to get configuration", "INTERNAL_ERROR", nil)
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, "Configuration retrieved", res)
+}
+
+// Reload re-reads the configuration and applies its reloadable settings.
+// @Summary Admin reload runtime configuration
+// @Description Re-read the environment, .env and RUNTIME_CONFIG_FILE on the instance serving the request and apply rate limits, campaign scheduler timing, provider checks and Sentry capture flags. The whole configuration is validated first; structural settings need a restart. Every reload is audited with the old and new values.
+// @Tags Admin Configuration
+// @Produce json
+// @Success 200 {object} dto.APIResponse{data=dto.AdminReloadRuntimeConfigResponse} "Reloaded"
+// @Failure 400 {object} dto.APIResponse "Configuration is invalid; error.details lists the problems"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security AdminBearer
+// @Router /api/v1/admin/config/reload [post]
+func (h *RuntimeConfigAdminHandler) Reload(c fiber.Ctx) error {
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/admin/config/reload", 30*time.Second)
+	defer cancel()
+	res, err := h.flow.ReloadRuntimeConfig(ctx, businessflow.ConfigReloadSourceAdmin)
+	if err != nil {
+		log.Println("Admin configuration reload failed", err)
+		var verr *config.ValidationError
+		if errors.As(err, &verr) {
+			return h.ErrorResponse(c, fiber.StatusBadRequest, "Configuration is invalid; the settings in effect were kept", "CONFIG_INVALID", verr.Problems)
+		}
+		return h.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to reload configuration", "CONFIG_RELOAD_FAILED", nil)
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, "Configuration reloaded", res)
+}
+
+func (h *RuntimeConfigAdminHandler) createRequestContextWithTimeout(c fiber.Ctx, endpoint string, timeout time.Duration) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	ctx = context.WithValue(ctx, utils.RequestIDKey, c.Get("X-Request-ID"))
+	ctx = context.WithValue(ctx, utils.UserAgentKey, c.Get("User-Agent"))
+	ctx = context.WithValue(ctx, utils.IPAddressKey, c.IP())
+	ctx = context.WithValue(ctx, utils.EndpointKey, endpoint)
+	ctx = context.WithValue(ctx, utils.TimeoutKey, timeout)
+	ctx = context.WithValue(ctx, utils.CancelFuncKey, cancel)
+	if adminID, ok := middleware.GetAdminIDFromContext(c); ok {
+		ctx = context.WithValue(ctx, utils.AdminIDKey, adminID)
+	}
+	return ctx, cancel
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/apierror"
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/app/middleware"
+	businessflow "github.com/amirphl/Yamata-no-Orochi/business_flow"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/gofiber/fiber/v3"
+)
+
+// SandboxAdminHandlerInterface defines admin endpoints of sandbox accounts
+type SandboxAdminHandlerInterface interface {
+	SetCustomerSandbox(c fiber.Ctx) error
+}
+
+// SandboxAdminHandler implements the admin sandbox endpoints
+type SandboxAdminHandler struct {
+	flow businessflow.SandboxFlow
+}
+
+func NewSandboxAdminHandler(flow businessflow.SandboxFlow) SandboxAdminHandlerInterface {
+	return &SandboxAdminHandler{flow: flow}
+}
+
+func (h *SandboxAdminHandler) ErrorResponse(c fiber.Ctx, status int, message, code string, details any) error {
+	return apierror.Respond(c, status, message, code, details)
+}
+
+func (h *SandboxAdminHandler) SuccessResponse(c fiber.Ctx, status int, message string, data any) error {
+	return c.Status(status).JSON(dto.APIResponse{Success: true, Message: message, Data: data})
+}
+
+// SetCustomerSandbox turns sandbox mode of a customer on or off
+// @Summary Admin Set Customer Sandbox Mode
+// @Description Turn sandbox mode of a customer on or off. Only customers without campaigns or wallet transactions can be turned into sandbox accounts. Turning sandbox mode off deletes the customer's sandbox campaigns and transactions and empties the wallet first.
+// @Tags Admin Customer Management
+// @Accept json
+// @Produce json
+// @Param customer_id path int true "Customer ID"
+// @Param body body dto.AdminSetCustomerSandboxRequest true "Sandbox mode"
+// @Success 200 {object} dto.APIResponse{data=dto.AdminSetCustomerSandboxResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Failure 404 {object} dto.APIResponse
+// @Failure 409 {object} dto.APIResponse "Customer already has campaigns or wallet transactions"
+// @Failure 500 {object} dto.APIResponse
+// @Security AdminBearer
+// @Router /api/v1/admin/customer-management/{customer_id}/sandbox [put]
+func (h *SandboxAdminHandler) SetCustomerSandbox(c fiber.Ctx) error {
+	cidStr := c.Params("customer_id")
+	cid, err := strconv.ParseUint(cidStr, 10, 64)
+	if err != nil || cid == 0 {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid customer_id", "VALIDATION_ERROR", nil)
+	}
+	var req dto.AdminSetCustomerSandboxRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body", "VALIDATION_ERROR", nil)
+	}
+	req.CustomerID = uint(cid)
+
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/admin/customer-management/"+cidStr+"/sandbox", 60*time.Second)
+	defer cancel()
+	res, err := h.flow.SetCustomerSandbox(ctx, &req)
+	if err != nil {
+		switch {
+		case businessflow.IsSandboxCustomerHasHistory(err):
+			return h.ErrorResponse(c, fiber.StatusConflict, "Customer already has campaigns or wallet transactions", "SANDBOX_CUSTOMER_HAS_HISTORY", nil)
+		case businessflow.IsCustomerNotFound(err):
+			return h.ErrorResponse(c, fiber.StatusNotFound, "Customer not found", "CUSTOMER_NOT_FOUND", nil)
+		case businessflow.IsAccountInactive(err):
+			return h.ErrorResponse(c, fiber.StatusForbidden, "Customer account is inactive", "ACCOUNT_INACTIVE", nil)
+		}
+		log.Println("Admin set customer sandbox failed", err)
+		return h.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to update customer sandbox mode", "SET_CUSTOMER_SANDBOX_FAILED", nil)
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, res.Message, res)
+}
+
+func (h *SandboxAdminHandler) createRequestContextWithTimeout(c fiber.Ctx, endpoint string, timeout time.Duration) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	ctx = context.WithValue(ctx, utils.RequestIDKey, c.Get("X-Request-ID"))
+	ctx = context.WithValue(ctx, utils.UserAgentKey, c.Get("User-Agent"))
+	ctx = context.WithValue(ctx, utils.IPAddressKey, c.IP())
+	ctx = context.WithValue(ctx, utils.EndpointKey, endpoint)
+	ctx = context.WithValue(ctx, utils.TimeoutKey, timeout)
+	ctx = context.WithValue(ctx, utils.CancelFuncKey, cancel)
+	if adminID, ok := middleware.GetAdminIDFromContext(c); ok {
+		ctx = context.WithValue(ctx, utils.AdminIDKey, adminID)
+	}
+	return ctx, cancel
+}
@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/apierror"
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/app/middleware"
+	businessflow "github.com/amirphl/Yamata-no-Orochi/business_flow"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v3"
+)
+
+// SandboxHandlerInterface defines customer endpoints of sandbox accounts
+type SandboxHandlerInterface interface {
+	GetStatus(c fiber.Ctx) error
+	TopUp(c fiber.Ctx) error
+	Purge(c fiber.Ctx) error
+}
+
+// SandboxHandler implements the customer sandbox endpoints
+type SandboxHandler struct {
+	flow      businessflow.SandboxFlow
+	validator *validator.Validate
+}
+
+func NewSandboxHandler(flow businessflow.SandboxFlow) SandboxHandlerInterface {
+	return &SandboxHandler{
+		flow:      flow,
+		validator: validator.New(),
+	}
+}
+
+func (h *SandboxHandler) ErrorResponse(c fiber.Ctx, status int, message, code string, details any) error {
+	return apierror.Respond(c, status, message, code, details)
+}
+
+func (h *SandboxHandler) SuccessResponse(c fiber.Ctx, status int, message string, data any) error {
+	return c.Status(status).JSON(dto.APIResponse{Success: true, Message: message, Data: data})
+}
+
+// GetStatus reports whether the customer is a sandbox account
+// @Summary Get Sandbox Status
+// @Description Report whether the authenticated customer is a sandbox account and, if so, its test balance. Sandbox accounts are turned on by an admin. Their campaigns are approved as usual but executed against a mock provider, so no message is sent, and their wallet holds test money only.
+// @Tags Sandbox
+// @Produce json
+// @Success 200 {object} dto.APIResponse{data=dto.SandboxStatusResponse}
+// @Failure 401 {object} dto.APIResponse "Unauthorized"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
+// @Router /api/v1/sandbox [get]
+func (h *SandboxHandler) GetStatus(c fiber.Ctx) error {
+	customerID, ok := c.Locals("customer_id").(uint)
+	if !ok {
+		return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer ID not found in context", "MISSING_CUSTOMER_ID", nil)
+	}
+
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/sandbox", 30*time.Second)
+	defer cancel()
+	res, err := h.flow.GetStatus(ctx, customerID)
+	if err != nil {
+		return h.handleFlowError(c, "Failed to get sandbox status", "GET_SANDBOX_STATUS_FAILED", err)
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, res.Message, res)
+}
+
+// TopUp adds test money to a sandbox wallet
+// @Summary Top Up Sandbox Wallet
+// @Description Credit test money to the free balance of the authenticated sandbox account. The transaction is flagged as sandbox and left out of every financial report. Real payments are refused for sandbox accounts.
+// @Tags Sandbox
+// @Accept json
+// @Produce json
+// @Param request body dto.SandboxTopUpRequest true "Top-up payload"
+// @Success 200 {object} dto.APIResponse{data=dto.SandboxTopUpResponse}
+// @Failure 400 {object} dto.APIResponse "Validation error"
+// @Failure 401 {object} dto.APIResponse "Unauthorized"
+// @Failure 403 {object} dto.APIResponse "Customer is not a sandbox account"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
+// @Router /api/v1/sandbox/wallet/top-up [post]
+func (h *SandboxHandler) TopUp(c fiber.Ctx) error {
+	var req dto.SandboxTopUpRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body", "INVALID_REQUEST", err.Error())
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		var validationErrors []string
+		for _, err := range err.(validator.ValidationErrors) {
+			validationErrors = append(validationErrors, getValidationErrorMessage(c, err))
+		}
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", validationErrors)
+	}
+
+	customerID, ok := c.Locals("customer_id").(uint)
+	if !ok {
+		return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer ID not found in context", "MISSING_CUSTOMER_ID", nil)
+	}
+	req.CustomerID = customerID
+
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/sandbox/wallet/top-up", 30*time.Second)
+	defer cancel()
+	res, err := h.flow.TopUp(ctx, &req, businessflow.NewClientMetadata(c.IP(), c.Get("User-Agent")))
+	if err != nil {
+		return h.handleFlowError(c, "Failed to top up sandbox wallet", "SANDBOX_TOP_UP_FAILED", err)
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, res.Message, res)
+}
+
+// Purge deletes the test data of a sandbox account
+// @Summary Purge Sandbox Data
+// @Description Delete the sandbox campaigns and transactions of the authenticated sandbox account and empty its wallet. The account stays in sandbox mode.
+// @Tags Sandbox
+// @Produce json
+// @Success 200 {object} dto.APIResponse{data=dto.SandboxPurgeResponse}
+// @Failure 401 {object} dto.APIResponse "Unauthorized"
+// @Failure 403 {object} dto.APIResponse "Customer is not a sandbox account"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
+// @Router /api/v1/sandbox [delete]
+func (h *SandboxHandler) Purge(c fiber.Ctx) error {
+	customerID, ok := c.Locals("customer_id").(uint)
+	if !ok {
+		return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer ID not found in context", "MISSING_CUSTOMER_ID", nil)
+	}
+
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/sandbox", 60*time.Second)
+	defer cancel()
+	res, err := h.flow.Purge(ctx, customerID, businessflow.NewClientMetadata(c.IP(), c.Get("User-Agent")))
+	if err != nil {
+		return h.handleFlowError(c, "Failed to purge sandbox data", "SANDBOX_PURGE_FAILED", err)
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, res.Message, res)
+}
+
+func (h *SandboxHandler) handleFlowError(c fiber.Ctx, message, code string, err error) error {
+	switch {
+	case businessflow.IsSandboxNotEnabled(err):
+		return h.ErrorResponse(c, fiber.StatusForbidden, "Customer is not a sandbox account", "SANDBOX_NOT_ENABLED", nil)
+	case businessflow.IsCustomerNotFound(err):
+		return h.ErrorResponse(c, fiber.StatusNotFound, "Customer not found", "CUSTOMER_NOT_FOUND", nil)
+	case businessflow.IsAccountInactive(err):
+		return h.ErrorResponse(c, fiber.StatusForbidden, "Customer account is inactive", "ACCOUNT_INACTIVE", nil)
+	}
+	log.Println(message+":", err)
+	return h.ErrorResponse(c, fiber.StatusInternalServerError, message, code, nil)
+}
+
+func (h *SandboxHandler) createRequestContextWithTimeout(c fiber.Ctx, endpoint string, timeout time.Duration) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	ctx = context.WithValue(ctx, utils.RequestIDKey, c.Get("X-Request-ID"))
+	ctx = context.WithValue(ctx, utils.UserAgentKey, c.Get("User-Agent"))
+	ctx = context.WithValue(ctx, utils.IPAddressKey, c.IP())
+	ctx = context.WithValue(ctx, utils.EndpointKey, endpoint)
+	ctx = context.WithValue(ctx, utils.TimeoutKey, timeout)
+	ctx = context.WithValue(ctx, utils.CancelFuncKey, cancel)
+	ctx = middleware.WithImpersonation(ctx, c)
+	return ctx, cancel
+}
@@ -6,6 +6,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/amirphl/Yamata-no-Orochi/app/apierror"
 	"github.com/amirphl/Yamata-no-Orochi/app/dto"
 	"github.com/amirphl/Yamata-no-Orochi/app/middleware"
 	businessflow "github.com/amirphl/Yamata-no-Orochi/business_flow"
@@ -35,7 +36,7 @@ func NewSegmentPriceFactorAdminHandler(flow businessflow.SegmentPriceFactorAdmin
 }
 
 func (h *SegmentPriceFactorAdminHandler) ErrorResponse(c fiber.Ctx, status int, message, code string, details any) error {
-	return c.Status(status).JSON(dto.APIResponse{Success: false, Message: message, Error: dto.ErrorDetail{Code: code, Details: details}})
+	return apierror.Respond(c, status, message, code, details)
 }
 
 func (h *SegmentPriceFactorAdminHandler) SuccessResponse(c fiber.Ctx, status int, message string, data any) error {
@@ -52,6 +53,7 @@ func (h *SegmentPriceFactorAdminHandler) SuccessResponse(c fiber.Ctx, status int
 // @Success 200 {object} dto.APIResponse{data=dto.AdminCreateSegmentPriceFactorResponse}
 // @Failure 400 {object} dto.APIResponse "Validation error"
 // @Failure 500 {object} dto.APIResponse "Creation failed"
+// @Security AdminBearer
 // @Router /api/v1/admin/segment-price-factors [post]
 func (h *SegmentPriceFactorAdminHandler) CreateSegmentPriceFactor(c fiber.Ctx) error {
 	var req dto.AdminCreateSegmentPriceFactorRequest
@@ -95,6 +97,7 @@ func (h *SegmentPriceFactorAdminHandler) CreateSegmentPriceFactor(c fiber.Ctx) e
 // @Param platform query string false "Platform (sms|rubika|bale|splus), default sms"
 // @Success 200 {object} dto.APIResponse{data=dto.AdminListSegmentPriceFactorsResponse}
 // @Failure 500 {object} dto.APIResponse "List failed"
+// @Security AdminBearer
 // @Router /api/v1/admin/segment-price-factors [get]
 func (h *SegmentPriceFactorAdminHandler) ListSegmentPriceFactors(c fiber.Ctx) error {
 	var platform *string
@@ -123,6 +126,7 @@ func (h *SegmentPriceFactorAdminHandler) ListSegmentPriceFactors(c fiber.Ctx) er
 // @Produce json
 // @Success 200 {object} dto.APIResponse{data=dto.AdminListLevel3OptionsResponse}
 // @Failure 500 {object} dto.APIResponse "List failed"
+// @Security AdminBearer
 // @Router /api/v1/admin/segment-price-factors/level3-options [get]
 func (h *SegmentPriceFactorAdminHandler) ListLevel3Options(c fiber.Ctx) error {
 	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/admin/segment-price-factors/level3-options", 30*time.Second)
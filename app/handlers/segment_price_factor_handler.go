@@ -5,7 +5,9 @@ import (
 	"log"
 	"time"
 
+	"github.com/amirphl/Yamata-no-Orochi/app/apierror"
 	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/app/middleware"
 	businessflow "github.com/amirphl/Yamata-no-Orochi/business_flow"
 	"github.com/amirphl/Yamata-no-Orochi/utils"
 	"github.com/gofiber/fiber/v3"
@@ -24,7 +26,7 @@ func NewSegmentPriceFactorHandler(flow businessflow.SegmentPriceFactorFlow) Segm
 }
 
 func (h *SegmentPriceFactorHandler) ErrorResponse(c fiber.Ctx, status int, message, code string, details any) error {
-	return c.Status(status).JSON(dto.APIResponse{Success: false, Message: message, Error: dto.ErrorDetail{Code: code, Details: details}})
+	return apierror.Respond(c, status, message, code, details)
 }
 
 func (h *SegmentPriceFactorHandler) SuccessResponse(c fiber.Ctx, status int, message string, data any) error {
@@ -39,6 +41,7 @@ func (h *SegmentPriceFactorHandler) SuccessResponse(c fiber.Ctx, status int, mes
 // @Param platform query string false "Platform (sms|rubika|bale|splus), default sms"
 // @Success 200 {object} dto.APIResponse{data=dto.ListLatestSegmentPriceFactorsResponse}
 // @Failure 500 {object} dto.APIResponse "List failed"
+// @Security CustomerBearer
 // @Router /api/v1/segment-price-factors [get]
 func (h *SegmentPriceFactorHandler) ListLatest(c fiber.Ctx) error {
 	var platform *string
@@ -68,5 +71,6 @@ func (h *SegmentPriceFactorHandler) createRequestContextWithTimeout(c fiber.Ctx,
 	ctx = context.WithValue(ctx, utils.EndpointKey, endpoint)
 	ctx = context.WithValue(ctx, utils.TimeoutKey, timeout)
 	ctx = context.WithValue(ctx, utils.CancelFuncKey, cancel)
+	ctx = middleware.WithImpersonation(ctx, c)
 	return ctx, cancel
 }
@@ -7,6 +7,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/amirphl/Yamata-no-Orochi/app/apierror"
 	"github.com/amirphl/Yamata-no-Orochi/app/dto"
 	"github.com/amirphl/Yamata-no-Orochi/app/middleware"
 	businessflow "github.com/amirphl/Yamata-no-Orochi/business_flow"
@@ -47,7 +48,7 @@ func NewShortLinkAdminHandler(uploadFlow businessflow.AdminShortLinkFlow, downlo
 }
 
 func (h *ShortLinkAdminHandler) ErrorResponse(c fiber.Ctx, statusCode int, message, code string, details any) error {
-	return c.Status(statusCode).JSON(dto.APIResponse{Success: false, Message: message, Error: dto.ErrorDetail{Code: code, Details: details}})
+	return apierror.Respond(c, statusCode, message, code, details)
 }
 
 // UploadCSV accepts a multipart/form-data with file (CSV), short_link_domain, and scenario_name fields
@@ -61,6 +62,7 @@ func (h *ShortLinkAdminHandler) ErrorResponse(c fiber.Ctx, statusCode int, messa
 // @Success 201 {object} dto.APIResponse{data=dto.AdminCreateShortLinksResponse}
 // @Failure 400 {object} dto.APIResponse
 // @Failure 500 {object} dto.APIResponse
+// @Security AdminBearer
 // @Router /api/v1/admin/short-links/upload-csv [post]
 func (h *ShortLinkAdminHandler) UploadCSV(c fiber.Ctx) error {
 	fileHeader, err := c.FormFile("file")
@@ -98,6 +100,7 @@ func (h *ShortLinkAdminHandler) UploadCSV(c fiber.Ctx) error {
 // @Success 200 {string} string "CSV file"
 // @Failure 400 {object} dto.APIResponse
 // @Failure 500 {object} dto.APIResponse
+// @Security AdminBearer
 // @Router /api/v1/admin/short-links/download [post]
 func (h *ShortLinkAdminHandler) DownloadByScenario(c fiber.Ctx) error {
 	var req dto.AdminDownloadShortLinksRequest
@@ -128,6 +131,7 @@ func (h *ShortLinkAdminHandler) DownloadByScenario(c fiber.Ctx) error {
 // @Success 200 {string} string "CSV file"
 // @Failure 400 {object} dto.APIResponse
 // @Failure 500 {object} dto.APIResponse
+// @Security AdminBearer
 // @Router /api/v1/admin/short-links/download-with-clicks [post]
 func (h *ShortLinkAdminHandler) DownloadWithClicksByScenario(c fiber.Ctx) error {
 	var req dto.AdminDownloadShortLinksRequest
@@ -158,6 +162,7 @@ func (h *ShortLinkAdminHandler) DownloadWithClicksByScenario(c fiber.Ctx) error
 // @Success 200 {string} string "CSV file"
 // @Failure 400 {object} dto.APIResponse
 // @Failure 500 {object} dto.APIResponse
+// @Security AdminBearer
 // @Router /api/v1/admin/short-links/download-with-clicks-range [post]
 func (h *ShortLinkAdminHandler) DownloadWithClicksByScenarioRange(c fiber.Ctx) error {
 	var req dto.AdminDownloadShortLinksRangeRequest
@@ -188,6 +193,7 @@ func (h *ShortLinkAdminHandler) DownloadWithClicksByScenarioRange(c fiber.Ctx) e
 // @Success 200 {string} string "Excel file"
 // @Failure 400 {object} dto.APIResponse
 // @Failure 500 {object} dto.APIResponse
+// @Security AdminBearer
 // @Router /api/v1/admin/short-links/download-with-clicks-by-scenario-name [post]
 func (h *ShortLinkAdminHandler) DownloadWithClicksByScenarioNameExcel(c fiber.Ctx) error {
 	var req dto.AdminDownloadShortLinksByScenarioNameRegexRequest
@@ -5,6 +5,7 @@ import (
 	"log"
 	"time"
 
+	"github.com/amirphl/Yamata-no-Orochi/app/apierror"
 	"github.com/amirphl/Yamata-no-Orochi/app/dto"
 	businessflow "github.com/amirphl/Yamata-no-Orochi/business_flow"
 	"github.com/amirphl/Yamata-no-Orochi/utils"
@@ -35,7 +36,7 @@ func NewShortLinkBotHandler(flow businessflow.BotShortLinkFlow) ShortLinkBotHand
 }
 
 func (h *ShortLinkBotHandler) ErrorResponse(c fiber.Ctx, statusCode int, message, errorCode string, details any) error {
-	return c.Status(statusCode).JSON(dto.APIResponse{Success: false, Message: message, Error: dto.ErrorDetail{Code: errorCode, Details: details}})
+	return apierror.Respond(c, statusCode, message, errorCode, details)
 }
 
 func (h *ShortLinkBotHandler) SuccessResponse(c fiber.Ctx, statusCode int, message string, data any) error {
@@ -51,6 +52,7 @@ func (h *ShortLinkBotHandler) SuccessResponse(c fiber.Ctx, statusCode int, messa
 // @Success 201 {object} dto.APIResponse{data=dto.BotCreateShortLinkResponse}
 // @Failure 400 {object} dto.APIResponse
 // @Failure 500 {object} dto.APIResponse
+// @Security BotBearer
 // @Router /api/v1/bot/short-links/one [post]
 func (h *ShortLinkBotHandler) CreateShortLink(c fiber.Ctx) error {
 	var req dto.BotCreateShortLinkRequest
@@ -79,6 +81,7 @@ func (h *ShortLinkBotHandler) CreateShortLink(c fiber.Ctx) error {
 // @Success 201 {object} dto.APIResponse{data=dto.BotCreateShortLinksResponse}
 // @Failure 400 {object} dto.APIResponse
 // @Failure 500 {object} dto.APIResponse
+// @Security BotBearer
 // @Router /api/v1/bot/short-links [post]
 func (h *ShortLinkBotHandler) CreateShortLinks(c fiber.Ctx) error {
 	var req dto.BotCreateShortLinksRequest
@@ -107,6 +110,7 @@ func (h *ShortLinkBotHandler) CreateShortLinks(c fiber.Ctx) error {
 // @Success 200 {object} dto.APIResponse{data=dto.BotAllocateShortLinksResponse}
 // @Failure 400 {object} dto.APIResponse
 // @Failure 500 {object} dto.APIResponse
+// @Security BotBearer
 // @Router /api/v1/bot/short-links/allocate [post]
 func (h *ShortLinkBotHandler) AllocateShortLinks(c fiber.Ctx) error {
 	var req dto.BotAllocateShortLinksRequest
@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/apierror"
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/app/middleware"
+	businessflow "github.com/amirphl/Yamata-no-Orochi/business_flow"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v3"
+)
+
+// SMSTariffAdminHandlerInterface defines admin endpoints for SMS tariffs.
+type SMSTariffAdminHandlerInterface interface {
+	CreateSMSTariff(c fiber.Ctx) error
+	ListSMSTariffs(c fiber.Ctx) error
+	UpdateSMSTariff(c fiber.Ctx) error
+	DeleteSMSTariff(c fiber.Ctx) error
+}
+
+// SMSTariffAdminHandler implements admin endpoints for SMS tariffs.
+type SMSTariffAdminHandler struct {
+	flow      businessflow.SMSTariffAdminFlow
+	validator *validator.Validate
+}
+
+func NewSMSTariffAdminHandler(flow businessflow.SMSTariffAdminFlow) SMSTariffAdminHandlerInterface {
+	return &SMSTariffAdminHandler{
+		flow:      flow,
+		validator: validator.New(),
+	}
+}
+
+func (h *SMSTariffAdminHandler) ErrorResponse(c fiber.Ctx, status int, message, code string, details any) error {
+	return apierror.Respond(c, status, message, code, details)
+}
+
+func (h *SMSTariffAdminHandler) SuccessResponse(c fiber.Ctx, status int, message string, data any) error {
+	return c.Status(status).JSON(dto.APIResponse{Success: true, Message: message, Data: data})
+}
+
+// CreateSMSTariff adds a row to the SMS tariff table.
+// @Summary Create SMS Tariff (Admin)
+// @Description Create a per-part SMS price for an operator / line number tier / account type / part count combination; omitted dimensions match any value
+// @Tags Admin SMS Tariffs
+// @Accept json
+// @Produce json
+// @Param request body dto.AdminCreateSMSTariffRequest true "SMS tariff payload"
+// @Success 201 {object} dto.APIResponse{data=dto.AdminSMSTariffResponse}
+// @Failure 400 {object} dto.APIResponse "Validation error"
+// @Failure 409 {object} dto.APIResponse "Duplicate tariff"
+// @Failure 500 {object} dto.APIResponse "Creation failed"
+// @Security AdminBearer
+// @Router /api/v1/admin/sms-tariffs [post]
+func (h *SMSTariffAdminHandler) CreateSMSTariff(c fiber.Ctx) error {
+	var req dto.AdminCreateSMSTariffRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body", "INVALID_REQUEST", err.Error())
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		var validationErrors []string
+		for _, e := range err.(validator.ValidationErrors) {
+			validationErrors = append(validationErrors, e.Error())
+		}
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", validationErrors)
+	}
+
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/admin/sms-tariffs", 30*time.Second)
+	defer cancel()
+	res, err := h.flow.AdminCreateSMSTariff(ctx, &req)
+	if err != nil {
+		return h.handleFlowError(c, "Create SMS tariff failed", "SMS_TARIFF_CREATE_FAILED", err)
+	}
+	return h.SuccessResponse(c, fiber.StatusCreated, "SMS tariff created", res)
+}
+
+// ListSMSTariffs returns every SMS tariff row.
+// @Summary List SMS Tariffs (Admin)
+// @Description List all SMS tariff rows including inactive ones
+// @Tags Admin SMS Tariffs
+// @Produce json
+// @Success 200 {object} dto.APIResponse{data=dto.AdminListSMSTariffsResponse}
+// @Failure 500 {object} dto.APIResponse "List failed"
+// @Security AdminBearer
+// @Router /api/v1/admin/sms-tariffs [get]
+func (h *SMSTariffAdminHandler) ListSMSTariffs(c fiber.Ctx) error {
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/admin/sms-tariffs", 30*time.Second)
+	defer cancel()
+	res, err := h.flow.AdminListSMSTariffs(ctx)
+	if err != nil {
+		log.Println("List SMS tariffs failed:", err)
+		return h.ErrorResponse(c, fiber.StatusInternalServerError, "List SMS tariffs failed", "SMS_TARIFF_LIST_FAILED", nil)
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, "SMS tariffs retrieved", res)
+}
+
+// UpdateSMSTariff replaces an SMS tariff row.
+// @Summary Update SMS Tariff (Admin)
+// @Description Replace the dimensions and price of an SMS tariff
+// @Tags Admin SMS Tariffs
+// @Accept json
+// @Produce json
+// @Param uuid path string true "Tariff UUID"
+// @Param request body dto.AdminCreateSMSTariffRequest true "SMS tariff payload"
+// @Success 200 {object} dto.APIResponse{data=dto.AdminSMSTariffResponse}
+// @Failure 400 {object} dto.APIResponse "Validation error"
+// @Failure 404 {object} dto.APIResponse "Tariff not found"
+// @Failure 409 {object} dto.APIResponse "Duplicate tariff"
+// @Failure 500 {object} dto.APIResponse "Update failed"
+// @Security AdminBearer
+// @Router /api/v1/admin/sms-tariffs/{uuid} [put]
+func (h *SMSTariffAdminHandler) UpdateSMSTariff(c fiber.Ctx) error {
+	var req dto.AdminUpdateSMSTariffRequest
+	if err := c.Bind().JSON(&req.AdminCreateSMSTariffRequest); err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body", "INVALID_REQUEST", err.Error())
+	}
+	req.UUID = c.Params("uuid")
+	if err := h.validator.Struct(&req); err != nil {
+		var validationErrors []string
+		for _, e := range err.(validator.ValidationErrors) {
+			validationErrors = append(validationErrors, e.Error())
+		}
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", validationErrors)
+	}
+
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/admin/sms-tariffs/:uuid", 30*time.Second)
+	defer cancel()
+	res, err := h.flow.AdminUpdateSMSTariff(ctx, &req)
+	if err != nil {
+		return h.handleFlowError(c, "Update SMS tariff failed", "SMS_TARIFF_UPDATE_FAILED", err)
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, "SMS tariff updated", res)
+}
+
+// DeleteSMSTariff removes an SMS tariff row.
+// @Summary Delete SMS Tariff (Admin)
+// @Description Delete an SMS tariff; campaigns already finalized keep the price recorded at reservation time
+// @Tags Admin SMS Tariffs
+// @Produce json
+// @Param uuid path string true "Tariff UUID"
+// @Success 200 {object} dto.APIResponse{data=dto.AdminDeleteSMSTariffResponse}
+// @Failure 404 {object} dto.APIResponse "Tariff not found"
+// @Failure 500 {object} dto.APIResponse "Delete failed"
+// @Security AdminBearer
+// @Router /api/v1/admin/sms-tariffs/{uuid} [delete]
+func (h *SMSTariffAdminHandler) DeleteSMSTariff(c fiber.Ctx) error {
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/admin/sms-tariffs/:uuid", 30*time.Second)
+	defer cancel()
+	res, err := h.flow.AdminDeleteSMSTariff(ctx, c.Params("uuid"))
+	if err != nil {
+		return h.handleFlowError(c, "Delete SMS tariff failed", "SMS_TARIFF_DELETE_FAILED", err)
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, "SMS tariff deleted", res)
+}
+
+func (h *SMSTariffAdminHandler) handleFlowError(c fiber.Ctx, message, code string, err error) error {
+	switch {
+	case businessflow.IsSMSTariffNotFound(err):
+		return h.ErrorResponse(c, fiber.StatusNotFound, "SMS tariff not found", "SMS_TARIFF_NOT_FOUND", nil)
+	case businessflow.IsSMSTariffAlreadyExists(err):
+		return h.ErrorResponse(c, fiber.StatusConflict, "An SMS tariff with the same dimensions already exists", "SMS_TARIFF_ALREADY_EXISTS", nil)
+	case businessflow.IsSMSTariffOperatorInvalid(err):
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid SMS operator", "SMS_TARIFF_OPERATOR_INVALID", nil)
+	case businessflow.IsPriceFactorInvalid(err):
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Price per part must be greater than zero", "SMS_TARIFF_PRICE_INVALID", nil)
+	}
+	log.Println(message+":", err)
+	return h.ErrorResponse(c, fiber.StatusInternalServerError, message, code, nil)
+}
+
+func (h *SMSTariffAdminHandler) createRequestContextWithTimeout(c fiber.Ctx, endpoint string, timeout time.Duration) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	ctx = context.WithValue(ctx, utils.RequestIDKey, c.Get("X-Request-ID"))
+	ctx = context.WithValue(ctx, utils.UserAgentKey, c.Get("User-Agent"))
+	ctx = context.WithValue(ctx, utils.IPAddressKey, c.IP())
+	ctx = context.WithValue(ctx, utils.EndpointKey, endpoint)
+	ctx = context.WithValue(ctx, utils.TimeoutKey, timeout)
+	ctx = context.WithValue(ctx, utils.CancelFuncKey, cancel)
+	if adminID, ok := middleware.GetAdminIDFromContext(c); ok {
+		ctx = context.WithValue(ctx, utils.AdminIDKey, adminID)
+	}
+	return ctx, cancel
+}
@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/apierror"
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/app/middleware"
+	businessflow "github.com/amirphl/Yamata-no-Orochi/business_flow"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+)
+
+// TaxInvoiceAdminHandlerInterface defines admin endpoints for the official
+// invoices of wallet charges
+type TaxInvoiceAdminHandlerInterface interface {
+	ListInvoices(c fiber.Ctx) error
+	DownloadInvoice(c fiber.Ctx) error
+}
+
+// TaxInvoiceAdminHandler implements the admin invoice endpoints
+type TaxInvoiceAdminHandler struct {
+	flow      businessflow.TaxInvoiceFlow
+	validator *validator.Validate
+}
+
+func NewTaxInvoiceAdminHandler(flow businessflow.TaxInvoiceFlow) TaxInvoiceAdminHandlerInterface {
+	return &TaxInvoiceAdminHandler{
+		flow:      flow,
+		validator: validator.New(),
+	}
+}
+
+func (h *TaxInvoiceAdminHandler) ErrorResponse(c fiber.Ctx, status int, message, code string, details any) error {
+	return apierror.Respond(c, status, message, code, details)
+}
+
+func (h *TaxInvoiceAdminHandler) SuccessResponse(c fiber.Ctx, status int, message string, data any) error {
+	return c.Status(status).JSON(dto.APIResponse{Success: true, Message: message, Data: data})
+}
+
+// ListInvoices returns invoices of all customers
+// @Summary List Invoices (Admin)
+// @Description List the official invoices of wallet charges, newest first
+// @Tags Payments Admin
+// @Produce json
+// @Param customer_id query int false "Filter by customer ID"
+// @Param issued_after query string false "Issued at or after (RFC3339)"
+// @Param issued_before query string false "Issued before (RFC3339)"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Page size" default(10) maximum(100)
+// @Success 200 {object} dto.APIResponse{data=dto.AdminListTaxInvoicesResponse}
+// @Failure 400 {object} dto.APIResponse "Validation error"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security AdminBearer
+// @Router /api/v1/admin/payments/invoices [get]
+func (h *TaxInvoiceAdminHandler) ListInvoices(c fiber.Ctx) error {
+	page, err := strconv.Atoi(c.Query("page", "1"))
+	if err != nil || page <= 0 {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid page format", "INVALID_PAGE", nil)
+	}
+	limit, err := strconv.Atoi(c.Query("limit", "10"))
+	if err != nil || limit <= 0 {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid limit format", "INVALID_LIMIT", nil)
+	}
+
+	filter := dto.AdminListTaxInvoicesFilter{Page: page, Limit: limit}
+	if v := strings.TrimSpace(c.Query("customer_id")); v != "" {
+		id, err := strconv.ParseUint(v, 10, 64)
+		if err != nil || id == 0 {
+			return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", nil)
+		}
+		filter.CustomerID = utils.ToPtr(uint(id))
+	}
+	if v := strings.TrimSpace(c.Query("issued_after")); v != "" {
+		filter.IssuedAfter = &v
+	}
+	if v := strings.TrimSpace(c.Query("issued_before")); v != "" {
+		filter.IssuedBefore = &v
+	}
+	if err := h.validator.Struct(filter); err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", nil)
+	}
+
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/admin/payments/invoices", 30*time.Second)
+	defer cancel()
+	res, err := h.flow.AdminListInvoices(ctx, filter)
+	if err != nil {
+		var be *businessflow.BusinessError
+		if errors.As(err, &be) && be.Code == "VALIDATION_ERROR" {
+			return h.ErrorResponse(c, fiber.StatusBadRequest, be.Message, be.Code, nil)
+		}
+		log.Println("Failed to list tax invoices:", err)
+		return h.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to list invoices", "TAX_INVOICE_LIST_FAILED", nil)
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, res.Message, res)
+}
+
+// DownloadInvoice returns the PDF of any invoice
+// @Summary Download Invoice PDF (Admin)
+// @Description Download an invoice as a PDF in the Persian layout of the standard sales invoice
+// @Tags Payments Admin
+// @Produce application/pdf
+// @Param uuid path string true "Invoice UUID"
+// @Success 200 {file} file "Invoice PDF"
+// @Failure 400 {object} dto.APIResponse "Invalid UUID"
+// @Failure 404 {object} dto.APIResponse "Invoice not found"
+// @Failure 503 {object} dto.APIResponse "PDF rendering not configured"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security AdminBearer
+// @Router /api/v1/admin/payments/invoices/{uuid}/pdf [get]
+func (h *TaxInvoiceAdminHandler) DownloadInvoice(c fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("uuid"))
+	if err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid uuid", "INVALID_UUID", nil)
+	}
+
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/admin/payments/invoices/"+id.String()+"/pdf", 30*time.Second)
+	defer cancel()
+	pdf, err := h.flow.DownloadInvoice(ctx, id)
+	if err != nil {
+		return handleTaxInvoiceDownloadError(c, err)
+	}
+	return sendTaxInvoicePDF(c, pdf)
+}
+
+func (h *TaxInvoiceAdminHandler) createRequestContextWithTimeout(c fiber.Ctx, endpoint string, timeout time.Duration) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	ctx = context.WithValue(ctx, utils.RequestIDKey, c.Get("X-Request-ID"))
+	ctx = context.WithValue(ctx, utils.UserAgentKey, c.Get("User-Agent"))
+	ctx = context.WithValue(ctx, utils.IPAddressKey, c.IP())
+	ctx = context.WithValue(ctx, utils.EndpointKey, endpoint)
+	ctx = context.WithValue(ctx, utils.TimeoutKey, timeout)
+	ctx = context.WithValue(ctx, utils.CancelFuncKey, cancel)
+	if adminID, ok := middleware.GetAdminIDFromContext(c); ok {
+		ctx = context.WithValue(ctx, utils.AdminIDKey, adminID)
+	}
+	return ctx, cancel
+}
@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/apierror"
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/app/middleware"
+	businessflow "github.com/amirphl/Yamata-no-Orochi/business_flow"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+)
+
+// TaxInvoiceHandlerInterface defines customer endpoints for the official
+// invoices of wallet charges
+type TaxInvoiceHandlerInterface interface {
+	ListInvoices(c fiber.Ctx) error
+	DownloadInvoice(c fiber.Ctx) error
+}
+
+// TaxInvoiceHandler implements the customer invoice endpoints
+type TaxInvoiceHandler struct {
+	flow businessflow.TaxInvoiceFlow
+}
+
+func NewTaxInvoiceHandler(flow businessflow.TaxInvoiceFlow) TaxInvoiceHandlerInterface {
+	return &TaxInvoiceHandler{flow: flow}
+}
+
+func (h *TaxInvoiceHandler) ErrorResponse(c fiber.Ctx, status int, message, code string, details any) error {
+	return apierror.Respond(c, status, message, code, details)
+}
+
+func (h *TaxInvoiceHandler) SuccessResponse(c fiber.Ctx, status int, message string, data any) error {
+	return c.Status(status).JSON(dto.APIResponse{Success: true, Message: message, Data: data})
+}
+
+// ListInvoices returns the customer's invoices
+// @Summary List Invoices
+// @Description List the official invoices issued for the authenticated customer's wallet charges, newest first. Each successful charge gets one invoice with a gap-free number and its VAT breakdown.
+// @Tags Payments
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Page size" default(10) maximum(100)
+// @Success 200 {object} dto.APIResponse{data=dto.ListTaxInvoicesResponse}
+// @Failure 400 {object} dto.APIResponse "Validation error"
+// @Failure 401 {object} dto.APIResponse "Unauthorized"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
+// @Router /api/v1/payments/invoices [get]
+func (h *TaxInvoiceHandler) ListInvoices(c fiber.Ctx) error {
+	customerID, ok := c.Locals("customer_id").(uint)
+	if !ok {
+		return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer ID not found in context", "MISSING_CUSTOMER_ID", nil)
+	}
+	page, err := strconv.Atoi(c.Query("page", "1"))
+	if err != nil || page <= 0 {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid page format", "INVALID_PAGE", nil)
+	}
+	limit, err := strconv.Atoi(c.Query("limit", "10"))
+	if err != nil || limit <= 0 || limit > 100 {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid limit format", "INVALID_LIMIT", nil)
+	}
+
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/payments/invoices", 30*time.Second)
+	defer cancel()
+	res, err := h.flow.ListCustomerInvoices(ctx, customerID, page, limit)
+	if err != nil {
+		log.Println("List tax invoices failed", err)
+		return h.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to list invoices", "TAX_INVOICE_LIST_FAILED", nil)
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, res.Message, res)
+}
+
+// DownloadInvoice returns the PDF of one of the customer's invoices
+// @Summary Download Invoice PDF
+// @Description Download an invoice of the authenticated customer as a PDF in the Persian layout of the standard sales invoice
+// @Tags Payments
+// @Produce application/pdf
+// @Param uuid path string true "Invoice UUID"
+// @Success 200 {file} file "Invoice PDF"
+// @Failure 400 {object} dto.APIResponse "Invalid UUID"
+// @Failure 401 {object} dto.APIResponse "Unauthorized"
+// @Failure 404 {object} dto.APIResponse "Invoice not found"
+// @Failure 503 {object} dto.APIResponse "PDF rendering not configured"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
+// @Router /api/v1/payments/invoices/{uuid}/pdf [get]
+func (h *TaxInvoiceHandler) DownloadInvoice(c fiber.Ctx) error {
+	customerID, ok := c.Locals("customer_id").(uint)
+	if !ok {
+		return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer ID not found in context", "MISSING_CUSTOMER_ID", nil)
+	}
+	id, err := uuid.Parse(c.Params("uuid"))
+	if err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid uuid", "INVALID_UUID", nil)
+	}
+
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/payments/invoices/"+id.String()+"/pdf", 30*time.Second)
+	defer cancel()
+	pdf, err := h.flow.DownloadCustomerInvoice(ctx, customerID, id)
+	if err != nil {
+		return handleTaxInvoiceDownloadError(c, err)
+	}
+	return sendTaxInvoicePDF(c, pdf)
+}
+
+func (h *TaxInvoiceHandler) createRequestContextWithTimeout(c fiber.Ctx, endpoint string, timeout time.Duration) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	ctx = context.WithValue(ctx, utils.RequestIDKey, c.Get("X-Request-ID"))
+	ctx = context.WithValue(ctx, utils.UserAgentKey, c.Get("User-Agent"))
+	ctx = context.WithValue(ctx, utils.IPAddressKey, c.IP())
+	ctx = context.WithValue(ctx, utils.EndpointKey, endpoint)
+	ctx = context.WithValue(ctx, utils.TimeoutKey, timeout)
+	ctx = context.WithValue(ctx, utils.CancelFuncKey, cancel)
+	ctx = middleware.WithImpersonation(ctx, c)
+	return ctx, cancel
+}
+
+// handleTaxInvoiceDownloadError maps download errors shared by the customer
+// and admin endpoints
+func handleTaxInvoiceDownloadError(c fiber.Ctx, err error) error {
+	switch {
+	case businessflow.IsTaxInvoiceNotFound(err):
+		return apierror.Respond(c, fiber.StatusNotFound, "Invoice not found", "TAX_INVOICE_NOT_FOUND", nil)
+	case businessflow.IsTaxInvoicePDFUnavailable(err):
+		return apierror.Respond(c, fiber.StatusServiceUnavailable, "Invoice PDFs are not available", "TAX_INVOICE_PDF_UNAVAILABLE", nil)
+	}
+	log.Println("Download tax invoice failed:", err)
+	return apierror.Respond(c, fiber.StatusInternalServerError, "Failed to download invoice", "TAX_INVOICE_DOWNLOAD_FAILED", nil)
+}
+
+func sendTaxInvoicePDF(c fiber.Ctx, pdf *dto.TaxInvoicePDF) error {
+	c.Set("Content-Type", "application/pdf")
+	c.Set("Content-Disposition", "attachment; filename=\""+pdf.Filename+"\"")
+	return c.Send(pdf.Content)
+}
@@ -0,0 +1,218 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/apierror"
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/app/middleware"
+	businessflow "github.com/amirphl/Yamata-no-Orochi/business_flow"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v3"
+)
+
+// TelegramHandlerInterface defines the Telegram notification channel endpoints
+type TelegramHandlerInterface interface {
+	StartLink(c fiber.Ctx) error
+	VerifyLink(c fiber.Ctx) error
+	Status(c fiber.Ctx) error
+	Unlink(c fiber.Ctx) error
+	Webhook(c fiber.Ctx) error
+}
+
+// TelegramHandler implements the Telegram notification channel endpoints
+type TelegramHandler struct {
+	flow      businessflow.TelegramLinkFlow
+	validator *validator.Validate
+}
+
+func NewTelegramHandler(flow businessflow.TelegramLinkFlow) TelegramHandlerInterface {
+	return &TelegramHandler{
+		flow:      flow,
+		validator: validator.New(),
+	}
+}
+
+func (h *TelegramHandler) ErrorResponse(c fiber.Ctx, status int, message, code string, details any) error {
+	return apierror.Respond(c, status, message, code, details)
+}
+
+func (h *TelegramHandler) SuccessResponse(c fiber.Ctx, status int, message string, data any) error {
+	return c.Status(status).JSON(dto.APIResponse{Success: true, Message: message, Data: data})
+}
+
+// StartLink creates a deep link to the notification bot
+// @Summary Start Telegram Link
+// @Description Create a t.me deep link to the notification bot. Opening it and pressing Start makes the bot send a verification code to that chat; submit the code to the verify endpoint before the link expires.
+// @Tags Notifications
+// @Produce json
+// @Success 200 {object} dto.APIResponse{data=dto.StartTelegramLinkResponse}
+// @Failure 401 {object} dto.APIResponse "Unauthorized"
+// @Failure 403 {object} dto.APIResponse "Not allowed while impersonating the customer"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Failure 503 {object} dto.APIResponse "Telegram is not configured"
+// @Security CustomerBearer
+// @Router /api/v1/notifications/telegram/link [post]
+func (h *TelegramHandler) StartLink(c fiber.Ctx) error {
+	customerID, ok := c.Locals("customer_id").(uint)
+	if !ok {
+		return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer ID not found in context", "MISSING_CUSTOMER_ID", nil)
+	}
+
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/notifications/telegram/link", 10*time.Second)
+	defer cancel()
+	res, err := h.flow.StartLink(ctx, customerID)
+	if err != nil {
+		return h.handleError(c, "Start Telegram link failed:", err, "Failed to create Telegram link", "START_TELEGRAM_LINK_FAILED")
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, res.Message, res)
+}
+
+// VerifyLink completes the Telegram link
+// @Summary Verify Telegram Link
+// @Description Link the chat that opened the deep link by submitting the code the bot sent to it. Campaign status and payment notices then go to that chat instead of SMS. Five wrong codes lock the link; create a new one.
+// @Tags Notifications
+// @Accept json
+// @Produce json
+// @Param request body dto.VerifyTelegramLinkRequest true "Verification code"
+// @Success 200 {object} dto.APIResponse{data=dto.VerifyTelegramLinkResponse}
+// @Failure 400 {object} dto.APIResponse "Validation error or wrong code"
+// @Failure 401 {object} dto.APIResponse "Unauthorized"
+// @Failure 403 {object} dto.APIResponse "Not allowed while impersonating the customer"
+// @Failure 404 {object} dto.APIResponse "No chat is waiting for a code"
+// @Failure 429 {object} dto.APIResponse "Too many wrong codes"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
+// @Router /api/v1/notifications/telegram/verify [post]
+func (h *TelegramHandler) VerifyLink(c fiber.Ctx) error {
+	var req dto.VerifyTelegramLinkRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body", "INVALID_REQUEST", err.Error())
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		var validationErrors []string
+		for _, err := range err.(validator.ValidationErrors) {
+			validationErrors = append(validationErrors, getValidationErrorMessage(c, err))
+		}
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", validationErrors)
+	}
+
+	customerID, ok := c.Locals("customer_id").(uint)
+	if !ok {
+		return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer ID not found in context", "MISSING_CUSTOMER_ID", nil)
+	}
+	req.CustomerID = customerID
+
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/notifications/telegram/verify", 10*time.Second)
+	defer cancel()
+	res, err := h.flow.VerifyLink(ctx, &req, businessflow.NewClientMetadata(c.IP(), c.Get("User-Agent")))
+	if err != nil {
+		return h.handleError(c, "Verify Telegram link failed:", err, "Failed to verify Telegram link", "VERIFY_TELEGRAM_LINK_FAILED")
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, res.Message, res)
+}
+
+// Status returns whether notices go to Telegram
+// @Summary Get Telegram Link Status
+// @Description Return whether the Telegram channel is available, whether a chat is linked and whether a chat is waiting for its verification code.
+// @Tags Notifications
+// @Produce json
+// @Success 200 {object} dto.APIResponse{data=dto.TelegramLinkStatusResponse}
+// @Failure 401 {object} dto.APIResponse "Unauthorized"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
+// @Router /api/v1/notifications/telegram [get]
+func (h *TelegramHandler) Status(c fiber.Ctx) error {
+	customerID, ok := c.Locals("customer_id").(uint)
+	if !ok {
+		return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer ID not found in context", "MISSING_CUSTOMER_ID", nil)
+	}
+
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/notifications/telegram", 10*time.Second)
+	defer cancel()
+	res, err := h.flow.Status(ctx, customerID)
+	if err != nil {
+		return h.handleError(c, "Get Telegram link failed:", err, "Failed to get Telegram link status", "GET_TELEGRAM_LINK_FAILED")
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, "Telegram link status retrieved", res)
+}
+
+// Unlink moves notices back to SMS
+// @Summary Unlink Telegram
+// @Description Unlink the Telegram chat; campaign status and payment notices are sent by SMS again.
+// @Tags Notifications
+// @Produce json
+// @Success 200 {object} dto.APIResponse{data=dto.UnlinkTelegramResponse}
+// @Failure 401 {object} dto.APIResponse "Unauthorized"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
+// @Router /api/v1/notifications/telegram [delete]
+func (h *TelegramHandler) Unlink(c fiber.Ctx) error {
+	customerID, ok := c.Locals("customer_id").(uint)
+	if !ok {
+		return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer ID not found in context", "MISSING_CUSTOMER_ID", nil)
+	}
+
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/notifications/telegram", 10*time.Second)
+	defer cancel()
+	res, err := h.flow.Unlink(ctx, customerID, businessflow.NewClientMetadata(c.IP(), c.Get("User-Agent")))
+	if err != nil {
+		return h.handleError(c, "Unlink Telegram failed:", err, "Failed to unlink Telegram", "UNLINK_TELEGRAM_FAILED")
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, res.Message, res)
+}
+
+// Webhook receives bot updates from Telegram. It answers 200 to every update
+// it processed, including ones it ignored, so Telegram does not redeliver them.
+func (h *TelegramHandler) Webhook(c fiber.Ctx) error {
+	var update dto.TelegramUpdate
+	if err := c.Bind().JSON(&update); err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString("ERR")
+	}
+
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/telegram/webhook", 15*time.Second)
+	defer cancel()
+	if err := h.flow.HandleUpdate(ctx, &update, c.Get("X-Telegram-Bot-Api-Secret-Token")); err != nil {
+		switch {
+		case businessflow.IsTelegramWebhookSecretInvalid(err):
+			return c.Status(fiber.StatusUnauthorized).SendString("ERR")
+		case businessflow.IsTelegramNotConfigured(err):
+			return c.Status(fiber.StatusNotFound).SendString("NOT_SUPPORTED")
+		}
+		log.Println("Telegram update failed:", err)
+		return c.Status(fiber.StatusInternalServerError).SendString("ERR")
+	}
+	return c.SendString("ok")
+}
+
+func (h *TelegramHandler) handleError(c fiber.Ctx, logPrefix string, err error, message, code string) error {
+	switch {
+	case businessflow.IsCustomerNotFound(err):
+		return h.ErrorResponse(c, fiber.StatusNotFound, "Customer not found", "CUSTOMER_NOT_FOUND", nil)
+	case businessflow.IsTelegramNotConfigured(err):
+		return h.ErrorResponse(c, fiber.StatusServiceUnavailable, "Telegram notifications are not configured", "TELEGRAM_NOT_CONFIGURED", nil)
+	case businessflow.IsTelegramLinkNotFound(err):
+		return h.ErrorResponse(c, fiber.StatusNotFound, "No Telegram chat is waiting for a verification code", "TELEGRAM_LINK_NOT_FOUND", nil)
+	case businessflow.IsTelegramCodeInvalid(err):
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid Telegram verification code", "TELEGRAM_CODE_INVALID", nil)
+	case businessflow.IsTelegramLinkLocked(err):
+		return h.ErrorResponse(c, fiber.StatusTooManyRequests, "Too many wrong codes; create a new Telegram link", "TELEGRAM_LINK_LOCKED", nil)
+	}
+	log.Println(logPrefix, err)
+	return h.ErrorResponse(c, fiber.StatusInternalServerError, message, code, nil)
+}
+
+func (h *TelegramHandler) createRequestContextWithTimeout(c fiber.Ctx, endpoint string, timeout time.Duration) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	ctx = context.WithValue(ctx, utils.RequestIDKey, c.Get("X-Request-ID"))
+	ctx = context.WithValue(ctx, utils.UserAgentKey, c.Get("User-Agent"))
+	ctx = context.WithValue(ctx, utils.IPAddressKey, c.IP())
+	ctx = context.WithValue(ctx, utils.EndpointKey, endpoint)
+	ctx = context.WithValue(ctx, utils.TimeoutKey, timeout)
+	ctx = context.WithValue(ctx, utils.CancelFuncKey, cancel)
+	ctx = middleware.WithImpersonation(ctx, c)
+	return ctx, cancel
+}
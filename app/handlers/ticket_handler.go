@@ -12,7 +12,9 @@ import (
 	"strings"
 	"time"
 
+	"github.com/amirphl/Yamata-no-Orochi/app/apierror"
 	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/app/middleware"
 	businessflow "github.com/amirphl/Yamata-no-Orochi/business_flow"
 	"github.com/amirphl/Yamata-no-Orochi/utils"
 	"github.com/go-playground/validator/v10"
@@ -37,14 +39,7 @@ type TicketHandler struct {
 }
 
 func (h *TicketHandler) ErrorResponse(c fiber.Ctx, statusCode int, message, errorCode string, details any) error {
-	return c.Status(statusCode).JSON(dto.APIResponse{
-		Success: false,
-		Message: message,
-		Error: dto.ErrorDetail{
-			Code:    errorCode,
-			Details: details,
-		},
-	})
+	return apierror.Respond(c, statusCode, message, errorCode, details)
 }
 
 func (h *TicketHandler) SuccessResponse(c fiber.Ctx, statusCode int, message string, data any) error {
@@ -72,19 +67,18 @@ func getFirstFile(files []*multipart.FileHeader) *multipart.FileHeader {
 }
 
 // Create Ticket
-// @Description Create a new support ticket for the authenticated customer. Supports multipart form upload or JSON.
+// @Description Create a new support ticket for the authenticated customer. Supports multipart form upload or a JSON body with the same fields (dto.CreateTicketRequest).
 // @Tags Tickets
 // @Accept mpfd
-// @Accept json
 // @Produce json
 // @Param title formData string false "Ticket title (<=80 chars)"
 // @Param content formData string false "Ticket content (<=1000 chars)"
 // @Param file formData file false "Attachment (jpg/png/pdf/docx/xlsx/zip, <=10MB)"
-// @Param request body dto.CreateTicketRequest false "JSON alternative for creating ticket"
 // @Success 201 {object} dto.APIResponse{data=dto.CreateTicketResponse} "Ticket created successfully"
 // @Failure 400 {object} dto.APIResponse "Validation error or invalid request"
 // @Failure 401 {object} dto.APIResponse "Unauthorized - customer not found or inactive"
 // @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
 // @Router /api/v1/tickets [post]
 func (h *TicketHandler) Create(c fiber.Ctx) error {
 	contentType := c.Get("Content-Type")
@@ -126,10 +120,10 @@ func (h *TicketHandler) Create(c fiber.Ctx) error {
 	result, err := h.flow.CreateTicket(ctx, &req, metadata)
 	if err != nil {
 		if businessflow.IsCustomerNotFound(err) {
-			return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer not found", "CUSTOMER_NOT_FOUND", nil)
+			return h.ErrorResponse(c, fiber.StatusNotFound, "Customer not found", "CUSTOMER_NOT_FOUND", nil)
 		}
 		if businessflow.IsAccountInactive(err) {
-			return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer account is inactive", "ACCOUNT_INACTIVE", nil)
+			return h.ErrorResponse(c, fiber.StatusForbidden, "Customer account is inactive", "ACCOUNT_INACTIVE", nil)
 		}
 		if be, ok := err.(*businessflow.BusinessError); ok {
 			switch be.Code {
@@ -154,21 +148,20 @@ func (h *TicketHandler) Create(c fiber.Ctx) error {
 }
 
 // CreateResponse Ticket
-// @Description Create a response to an existing ticket. Customer can reply to their own tickets. Supports multipart form upload or JSON.
+// @Description Create a response to an existing ticket. Customer can reply to their own tickets. Supports multipart form upload or a JSON body with the same fields (dto.CreateResponseTicketRequest).
 // @Tags Tickets
 // @Accept mpfd
-// @Accept json
 // @Produce json
 // @Param ticket_id formData integer false "Original ticket ID to respond to"
 // @Param content formData string false "Response content (<=1000 chars)"
 // @Param file formData file false "Attachment (jpg/png/pdf/docx/xlsx/zip, <=10MB)"
-// @Param request body dto.CreateResponseTicketRequest false "JSON alternative for creating response"
 // @Success 201 {object} dto.APIResponse{data=dto.CreateResponseTicketResponse} "Response ticket created successfully"
 // @Failure 400 {object} dto.APIResponse "Validation error or invalid request"
 // @Failure 401 {object} dto.APIResponse "Unauthorized - customer not found or inactive"
 // @Failure 403 {object} dto.APIResponse "Forbidden - ticket does not belong to customer"
 // @Failure 404 {object} dto.APIResponse "Ticket not found"
 // @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
 // @Router /api/v1/tickets/reply [post]
 func (h *TicketHandler) CreateResponse(c fiber.Ctx) error {
 	contentType := c.Get("Content-Type")
@@ -215,10 +208,10 @@ func (h *TicketHandler) CreateResponse(c fiber.Ctx) error {
 	result, err := h.flow.CreateResponseTicket(ctx, &req, metadata)
 	if err != nil {
 		if businessflow.IsCustomerNotFound(err) {
-			return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer not found", "CUSTOMER_NOT_FOUND", nil)
+			return h.ErrorResponse(c, fiber.StatusNotFound, "Customer not found", "CUSTOMER_NOT_FOUND", nil)
 		}
 		if businessflow.IsAccountInactive(err) {
-			return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer account is inactive", "ACCOUNT_INACTIVE", nil)
+			return h.ErrorResponse(c, fiber.StatusForbidden, "Customer account is inactive", "ACCOUNT_INACTIVE", nil)
 		}
 		if businessflow.IsTicketNotFound(err) {
 			return h.ErrorResponse(c, fiber.StatusNotFound, "Ticket not found", "TICKET_NOT_FOUND", nil)
@@ -257,6 +250,7 @@ func (h *TicketHandler) CreateResponse(c fiber.Ctx) error {
 // @Failure 400 {object} dto.APIResponse "Validation error or invalid request"
 // @Failure 401 {object} dto.APIResponse "Unauthorized - customer not found or inactive"
 // @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
 // @Router /api/v1/tickets [get]
 func (h *TicketHandler) List(c fiber.Ctx) error {
 	// Auth
@@ -315,10 +309,10 @@ func (h *TicketHandler) List(c fiber.Ctx) error {
 			return h.ErrorResponse(c, fiber.StatusBadRequest, "Start date must be before end date", "START_DATE_AFTER_END_DATE", nil)
 		}
 		if businessflow.IsCustomerNotFound(err) {
-			return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer not found", "CUSTOMER_NOT_FOUND", nil)
+			return h.ErrorResponse(c, fiber.StatusNotFound, "Customer not found", "CUSTOMER_NOT_FOUND", nil)
 		}
 		if businessflow.IsAccountInactive(err) {
-			return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer account is inactive", "ACCOUNT_INACTIVE", nil)
+			return h.ErrorResponse(c, fiber.StatusForbidden, "Customer account is inactive", "ACCOUNT_INACTIVE", nil)
 		}
 		if be, ok := err.(*businessflow.BusinessError); ok {
 			switch be.Code {
@@ -344,6 +338,7 @@ func (h *TicketHandler) List(c fiber.Ctx) error {
 // @Failure 403 {object} dto.APIResponse "Forbidden - ticket does not belong to customer"
 // @Failure 404 {object} dto.APIResponse "Ticket or attachment not found"
 // @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
 // @Router /api/v1/tickets/{ticket_id}/attachments/{file_index} [get]
 func (h *TicketHandler) DownloadAttachment(c fiber.Ctx) error {
 	ticketID, err := strconv.ParseUint(c.Params("ticket_id"), 10, 64)
@@ -370,10 +365,10 @@ func (h *TicketHandler) DownloadAttachment(c fiber.Ctx) error {
 	)
 	if err != nil {
 		if businessflow.IsCustomerNotFound(err) {
-			return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer not found", "CUSTOMER_NOT_FOUND", nil)
+			return h.ErrorResponse(c, fiber.StatusNotFound, "Customer not found", "CUSTOMER_NOT_FOUND", nil)
 		}
 		if businessflow.IsAccountInactive(err) {
-			return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer account is inactive", "ACCOUNT_INACTIVE", nil)
+			return h.ErrorResponse(c, fiber.StatusForbidden, "Customer account is inactive", "ACCOUNT_INACTIVE", nil)
 		}
 		if businessflow.IsTicketNotFound(err) {
 			return h.ErrorResponse(c, fiber.StatusNotFound, "Ticket not found", "TICKET_NOT_FOUND", nil)
@@ -397,20 +392,19 @@ func (h *TicketHandler) DownloadAttachment(c fiber.Ctx) error {
 }
 
 // AdminCreateResponse Create Admin Response Ticket
-// @Description Admin creates a response to an existing ticket; new ticket shares correlation ID with the original. Supports multipart or JSON.
+// @Description Admin creates a response to an existing ticket; new ticket shares correlation ID with the original. Supports multipart or a JSON body with the same fields (dto.AdminCreateResponseTicketRequest).
 // @Tags Tickets
 // @Accept mpfd
-// @Accept json
 // @Produce json
 // @Param ticket_id formData integer false "Original ticket ID"
 // @Param content formData string false "Response content (<=1000 chars)"
 // @Param file formData file false "Attachment (jpg/png/pdf/docx/xlsx/zip, <=10MB)"
-// @Param request body dto.AdminCreateResponseTicketRequest false "JSON alternative payload"
 // @Success 201 {object} dto.APIResponse{data=dto.AdminCreateResponseTicketResponse} "Admin response created successfully"
 // @Failure 400 {object} dto.APIResponse "Validation error or invalid request"
 // @Failure 401 {object} dto.APIResponse "Unauthorized"
 // @Failure 404 {object} dto.APIResponse "Ticket not found"
 // @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security AdminBearer
 // @Router /api/v1/admin/tickets/reply [post]
 func (h *TicketHandler) AdminCreateResponse(c fiber.Ctx) error {
 	contentType := c.Get("Content-Type")
@@ -483,6 +477,7 @@ func (h *TicketHandler) AdminCreateResponse(c fiber.Ctx) error {
 // @Failure 400 {object} dto.APIResponse "Validation error or invalid request"
 // @Failure 401 {object} dto.APIResponse "Unauthorized"
 // @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security AdminBearer
 // @Router /api/v1/admin/tickets [get]
 func (h *TicketHandler) AdminList(c fiber.Ctx) error {
 	var (
@@ -566,6 +561,7 @@ func (h *TicketHandler) createRequestContextWithTimeout(c fiber.Ctx, endpoint st
 	ctx = context.WithValue(ctx, utils.EndpointKey, endpoint)
 	ctx = context.WithValue(ctx, utils.TimeoutKey, timeout)
 	ctx = context.WithValue(ctx, utils.CancelFuncKey, cancel)
+	ctx = middleware.WithImpersonation(ctx, c)
 	return ctx, cancel
 }
 
@@ -0,0 +1,221 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/apierror"
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/app/middleware"
+	businessflow "github.com/amirphl/Yamata-no-Orochi/business_flow"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+)
+
+// WalletAdjustmentAdminHandlerInterface defines admin endpoints for manual
+// wallet adjustments under dual approval
+type WalletAdjustmentAdminHandlerInterface interface {
+	Create(c fiber.Ctx) error
+	Review(c fiber.Ctx) error
+	List(c fiber.Ctx) error
+}
+
+// WalletAdjustmentAdminHandler implements the wallet adjustment endpoints
+type WalletAdjustmentAdminHandler struct {
+	flow      businessflow.WalletAdjustmentFlow
+	validator *validator.Validate
+}
+
+func NewWalletAdjustmentAdminHandler(flow businessflow.WalletAdjustmentFlow) WalletAdjustmentAdminHandlerInterface {
+	return &WalletAdjustmentAdminHandler{
+		flow:      flow,
+		validator: validator.New(),
+	}
+}
+
+func (h *WalletAdjustmentAdminHandler) ErrorResponse(c fiber.Ctx, status int, message, code string, details any) error {
+	return apierror.Respond(c, status, message, code, details)
+}
+
+func (h *WalletAdjustmentAdminHandler) SuccessResponse(c fiber.Ctx, status int, message string, data any) error {
+	return c.Status(status).JSON(dto.APIResponse{Success: true, Message: message, Data: data})
+}
+
+// Create requests a manual wallet adjustment
+// @Summary Request Wallet Adjustment (Admin)
+// @Description Request a manual credit or debit of a customer's wallet with a reason code. The wallet is only changed once a different admin approves the request. chargeback is debit only; goodwill_credit and refund are credit only.
+// @Tags Payments Admin
+// @Accept json
+// @Produce json
+// @Param request body dto.AdminCreateWalletAdjustmentRequest true "Adjustment payload"
+// @Success 201 {object} dto.APIResponse{data=dto.AdminWalletAdjustmentResponse}
+// @Failure 400 {object} dto.APIResponse "Validation error or reason does not apply to the direction"
+// @Failure 401 {object} dto.APIResponse "Unauthorized admin"
+// @Failure 404 {object} dto.APIResponse "Customer not found"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security AdminBearer
+// @Router /api/v1/admin/payments/wallet-adjustments [post]
+func (h *WalletAdjustmentAdminHandler) Create(c fiber.Ctx) error {
+	var req dto.AdminCreateWalletAdjustmentRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body", "INVALID_REQUEST", err.Error())
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		var validationErrors []string
+		for _, err := range err.(validator.ValidationErrors) {
+			validationErrors = append(validationErrors, getValidationErrorMessage(c, err))
+		}
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", validationErrors)
+	}
+
+	adminID, ok := middleware.GetAdminIDFromContext(c)
+	if !ok || adminID == 0 {
+		return h.ErrorResponse(c, fiber.StatusUnauthorized, "Admin ID not found in context", "MISSING_ADMIN_ID", nil)
+	}
+
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/admin/payments/wallet-adjustments", 30*time.Second)
+	defer cancel()
+	res, err := h.flow.Create(ctx, &req, adminID)
+	if err != nil {
+		return h.handleFlowError(c, "Failed to request wallet adjustment", "WALLET_ADJUSTMENT_CREATE_FAILED", err)
+	}
+	return h.SuccessResponse(c, fiber.StatusCreated, "Wallet adjustment requested successfully", res)
+}
+
+// Review approves or rejects a pending wallet adjustment
+// @Summary Review Wallet Adjustment (Admin)
+// @Description Approve or reject a pending wallet adjustment. The reviewer must be a different admin from the requester. Approving applies the adjustment to the wallet; a debit fails if the free and credit balances do not cover it.
+// @Tags Payments Admin
+// @Accept json
+// @Produce json
+// @Param uuid path string true "Adjustment UUID"
+// @Param request body dto.AdminReviewWalletAdjustmentRequest true "Decision payload"
+// @Success 200 {object} dto.APIResponse{data=dto.AdminWalletAdjustmentResponse}
+// @Failure 400 {object} dto.APIResponse "Validation error"
+// @Failure 401 {object} dto.APIResponse "Unauthorized admin"
+// @Failure 403 {object} dto.APIResponse "Reviewer is the requester"
+// @Failure 404 {object} dto.APIResponse "Adjustment not found"
+// @Failure 409 {object} dto.APIResponse "Adjustment already reviewed or insufficient funds"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security AdminBearer
+// @Router /api/v1/admin/payments/wallet-adjustments/{uuid}/decision [post]
+func (h *WalletAdjustmentAdminHandler) Review(c fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("uuid"))
+	if err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid uuid", "INVALID_UUID", nil)
+	}
+	var req dto.AdminReviewWalletAdjustmentRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body", "INVALID_REQUEST", err.Error())
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		var validationErrors []string
+		for _, err := range err.(validator.ValidationErrors) {
+			validationErrors = append(validationErrors, getValidationErrorMessage(c, err))
+		}
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", validationErrors)
+	}
+
+	adminID, ok := middleware.GetAdminIDFromContext(c)
+	if !ok || adminID == 0 {
+		return h.ErrorResponse(c, fiber.StatusUnauthorized, "Admin ID not found in context", "MISSING_ADMIN_ID", nil)
+	}
+
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/admin/payments/wallet-adjustments/decision", 30*time.Second)
+	defer cancel()
+	res, err := h.flow.Review(ctx, id, &req, adminID)
+	if err != nil {
+		return h.handleFlowError(c, "Failed to review wallet adjustment", "WALLET_ADJUSTMENT_REVIEW_FAILED", err)
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, res.Message, res)
+}
+
+// List returns wallet adjustment requests
+// @Summary List Wallet Adjustments (Admin)
+// @Description List wallet adjustment requests, newest first
+// @Tags Payments Admin
+// @Produce json
+// @Param customer_id query int false "Filter by customer ID"
+// @Param status query string false "Filter by status (pending|approved|rejected)"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Page size" default(10) maximum(100)
+// @Success 200 {object} dto.APIResponse{data=dto.AdminListWalletAdjustmentsResponse}
+// @Failure 400 {object} dto.APIResponse "Validation error"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security AdminBearer
+// @Router /api/v1/admin/payments/wallet-adjustments [get]
+func (h *WalletAdjustmentAdminHandler) List(c fiber.Ctx) error {
+	page, err := strconv.Atoi(c.Query("page", "1"))
+	if err != nil || page <= 0 {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid page format", "INVALID_PAGE", nil)
+	}
+	limit, err := strconv.Atoi(c.Query("limit", "10"))
+	if err != nil || limit <= 0 {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid limit format", "INVALID_LIMIT", nil)
+	}
+
+	filter := dto.AdminListWalletAdjustmentsFilter{Page: page, Limit: limit}
+	if v := strings.TrimSpace(c.Query("customer_id")); v != "" {
+		id, err := strconv.ParseUint(v, 10, 64)
+		if err != nil || id == 0 {
+			return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", nil)
+		}
+		filter.CustomerID = utils.ToPtr(uint(id))
+	}
+	if status := strings.TrimSpace(c.Query("status")); status != "" {
+		filter.Status = &status
+	}
+	if err := h.validator.Struct(filter); err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", nil)
+	}
+
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/admin/payments/wallet-adjustments", 30*time.Second)
+	defer cancel()
+	res, err := h.flow.List(ctx, filter)
+	if err != nil {
+		return h.handleFlowError(c, "Failed to list wallet adjustments", "WALLET_ADJUSTMENT_LIST_FAILED", err)
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, "Wallet adjustments retrieved successfully", res)
+}
+
+func (h *WalletAdjustmentAdminHandler) handleFlowError(c fiber.Ctx, message, code string, err error) error {
+	switch {
+	case businessflow.IsWalletAdjustmentReasonInvalid(err):
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Reason does not apply to this direction", "WALLET_ADJUSTMENT_REASON_INVALID", businessErrorMessage(err))
+	case businessflow.IsCustomerNotFound(err):
+		return h.ErrorResponse(c, fiber.StatusNotFound, "Customer not found", "CUSTOMER_NOT_FOUND", nil)
+	case businessflow.IsAccountInactive(err):
+		return h.ErrorResponse(c, fiber.StatusForbidden, "Customer account is inactive", "ACCOUNT_INACTIVE", nil)
+	case businessflow.IsWalletNotFound(err):
+		return h.ErrorResponse(c, fiber.StatusNotFound, "Wallet not found", "WALLET_NOT_FOUND", nil)
+	case businessflow.IsWalletAdjustmentNotFound(err):
+		return h.ErrorResponse(c, fiber.StatusNotFound, "Wallet adjustment not found", "WALLET_ADJUSTMENT_NOT_FOUND", nil)
+	case businessflow.IsWalletAdjustmentNotPending(err):
+		return h.ErrorResponse(c, fiber.StatusConflict, "Wallet adjustment was already reviewed", "WALLET_ADJUSTMENT_NOT_PENDING", nil)
+	case businessflow.IsWalletAdjustmentSelfReview(err):
+		return h.ErrorResponse(c, fiber.StatusForbidden, "Wallet adjustment must be reviewed by another admin", "WALLET_ADJUSTMENT_SELF_REVIEW", nil)
+	case businessflow.IsInsufficientFunds(err):
+		return h.ErrorResponse(c, fiber.StatusConflict, "Insufficient funds", "INSUFFICIENT_FUNDS", nil)
+	}
+	log.Println(message+":", err)
+	return h.ErrorResponse(c, fiber.StatusInternalServerError, message, code, nil)
+}
+
+func (h *WalletAdjustmentAdminHandler) createRequestContextWithTimeout(c fiber.Ctx, endpoint string, timeout time.Duration) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	ctx = context.WithValue(ctx, utils.RequestIDKey, c.Get("X-Request-ID"))
+	ctx = context.WithValue(ctx, utils.UserAgentKey, c.Get("User-Agent"))
+	ctx = context.WithValue(ctx, utils.IPAddressKey, c.IP())
+	ctx = context.WithValue(ctx, utils.EndpointKey, endpoint)
+	ctx = context.WithValue(ctx, utils.TimeoutKey, timeout)
+	ctx = context.WithValue(ctx, utils.CancelFuncKey, cancel)
+	if adminID, ok := middleware.GetAdminIDFromContext(c); ok {
+		ctx = context.WithValue(ctx, utils.AdminIDKey, adminID)
+	}
+	return ctx, cancel
+}
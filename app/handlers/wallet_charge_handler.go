@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/apierror"
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/app/middleware"
+	businessflow "github.com/amirphl/Yamata-no-Orochi/business_flow"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v3"
+)
+
+// WalletChargeHandlerInterface defines the endpoints customers charge their
+// wallet with by any payment method
+type WalletChargeHandlerInterface interface {
+	Charge(c fiber.Ctx) error
+	ListRecent(c fiber.Ctx) error
+}
+
+// WalletChargeHandler implements the wallet charge endpoints
+type WalletChargeHandler struct {
+	flow      businessflow.WalletChargeFlow
+	validator *validator.Validate
+}
+
+func NewWalletChargeHandler(flow businessflow.WalletChargeFlow) WalletChargeHandlerInterface {
+	return &WalletChargeHandler{
+		flow:      flow,
+		validator: validator.New(),
+	}
+}
+
+func (h *WalletChargeHandler) ErrorResponse(c fiber.Ctx, status int, message, code string, details any) error {
+	return apierror.Respond(c, status, message, code, details)
+}
+
+func (h *WalletChargeHandler) SuccessResponse(c fiber.Ctx, status int, message string, data any) error {
+	return c.Status(status).JSON(dto.APIResponse{Success: true, Message: message, Data: data})
+}
+
+// Charge starts a wallet charge with any payment method
+// @Summary Charge Wallet
+// @Description Charge the wallet by card through Atipay, by crypto or by bank transfer. The method picks which of the crypto or bank_transfer objects is required; amount_with_tax is shared by all methods. The response carries the object of the method: the gateway token of a card payment, the deposit address of a crypto payment or the submitted receipt of a bank transfer. Errors are those of the method's own endpoint.
+// @Tags Wallet
+// @Accept json
+// @Produce json
+// @Param request body dto.WalletChargeRequest true "Charge payload"
+// @Success 201 {object} dto.APIResponse{data=dto.WalletChargeResponse}
+// @Failure 400 {object} dto.APIResponse "Validation error, unknown method or missing method details"
+// @Failure 401 {object} dto.APIResponse "Unauthorized"
+// @Failure 403 {object} dto.APIResponse "Account inactive, suspended or sandbox, or impersonating the customer"
+// @Failure 404 {object} dto.APIResponse "Customer or wallet not found"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Failure 503 {object} dto.APIResponse "Payment gateway or exchange rate unavailable"
+// @Security CustomerBearer
+// @Router /api/v1/wallet/charge [post]
+func (h *WalletChargeHandler) Charge(c fiber.Ctx) error {
+	var req dto.WalletChargeRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body", "INVALID_REQUEST", err.Error())
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		var validationErrors []string
+		for _, err := range err.(validator.ValidationErrors) {
+			validationErrors = append(validationErrors, getValidationErrorMessage(c, err))
+		}
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", validationErrors)
+	}
+
+	customerID, ok := c.Locals("customer_id").(uint)
+	if !ok {
+		return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer ID not found in context", "MISSING_CUSTOMER_ID", nil)
+	}
+	req.CustomerID = customerID
+
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/wallet/charge", 30*time.Second)
+	defer cancel()
+	res, err := h.flow.Charge(ctx, &req, businessflow.NewClientMetadata(c.IP(), c.Get("User-Agent")))
+	if err != nil {
+		switch {
+		case businessflow.IsWalletChargeMethodInvalid(err):
+			return h.ErrorResponse(c, fiber.StatusBadRequest, "Unknown payment method (allowed: card, crypto, bank_transfer)", "WALLET_CHARGE_METHOD_INVALID", nil)
+		case businessflow.IsWalletChargeDetailsRequired(err):
+			return h.ErrorResponse(c, fiber.StatusBadRequest, "Details of the payment method are required", "WALLET_CHARGE_DETAILS_REQUIRED", nil)
+		}
+		switch req.Method {
+		case dto.WalletChargeMethodCrypto:
+			return mapCryptoErr(c, err)
+		case dto.WalletChargeMethodBankTransfer:
+			return mapSubmitDepositReceiptErr(c, err)
+		default:
+			return mapChargeWalletErr(c, err)
+		}
+	}
+	return h.SuccessResponse(c, fiber.StatusCreated, res.Message, res)
+}
+
+// ListRecent returns the customer's recent charges of every method
+// @Summary List Wallet Charges
+// @Description List the customer's latest card, crypto and bank transfer charges together, newest first. Each item has a status common to all methods (pending, completed, failed, cancelled, expired, rejected or refunded) next to the status of the method's own request.
+// @Tags Wallet
+// @Produce json
+// @Param method query string false "Only charges of this method" Enums(card, crypto, bank_transfer)
+// @Param limit query int false "Number of charges" default(10) maximum(50)
+// @Success 200 {object} dto.APIResponse{data=dto.ListWalletChargesResponse}
+// @Failure 400 {object} dto.APIResponse "Validation error"
+// @Failure 401 {object} dto.APIResponse "Unauthorized"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
+// @Router /api/v1/wallet/charges [get]
+func (h *WalletChargeHandler) ListRecent(c fiber.Ctx) error {
+	limit, err := strconv.Atoi(c.Query("limit", "10"))
+	if err != nil || limit <= 0 {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid limit format", "INVALID_LIMIT", nil)
+	}
+	customerID, ok := c.Locals("customer_id").(uint)
+	if !ok {
+		return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer ID not found in context", "MISSING_CUSTOMER_ID", nil)
+	}
+	req := dto.ListWalletChargesRequest{CustomerID: customerID, Method: c.Query("method"), Limit: limit}
+	if err := h.validator.Struct(req); err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", nil)
+	}
+
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/wallet/charges", 10*time.Second)
+	defer cancel()
+	res, err := h.flow.ListRecentCharges(ctx, &req)
+	if err != nil {
+		if businessflow.IsWalletChargeMethodInvalid(err) {
+			return h.ErrorResponse(c, fiber.StatusBadRequest, "Unknown payment method (allowed: card, crypto, bank_transfer)", "WALLET_CHARGE_METHOD_INVALID", nil)
+		}
+		log.Println("List wallet charges failed:", err)
+		return h.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to list wallet charges", "WALLET_CHARGE_LIST_FAILED", nil)
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, res.Message, res)
+}
+
+func (h *WalletChargeHandler) createRequestContextWithTimeout(c fiber.Ctx, endpoint string, timeout time.Duration) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	ctx = context.WithValue(ctx, utils.RequestIDKey, c.Get("X-Request-ID"))
+	ctx = context.WithValue(ctx, utils.UserAgentKey, c.Get("User-Agent"))
+	ctx = context.WithValue(ctx, utils.IPAddressKey, c.IP())
+	ctx = context.WithValue(ctx, utils.EndpointKey, endpoint)
+	ctx = context.WithValue(ctx, utils.TimeoutKey, timeout)
+	ctx = context.WithValue(ctx, utils.CancelFuncKey, cancel)
+	ctx = middleware.WithImpersonation(ctx, c)
+	return ctx, cancel
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/apierror"
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/app/middleware"
+	businessflow "github.com/amirphl/Yamata-no-Orochi/business_flow"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v3"
+)
+
+// WalletTransferAdminHandlerInterface defines admin endpoints for wallet
+// transfers between customer accounts
+type WalletTransferAdminHandlerInterface interface {
+	List(c fiber.Ctx) error
+}
+
+// WalletTransferAdminHandler implements the admin wallet transfer endpoints
+type WalletTransferAdminHandler struct {
+	flow      businessflow.WalletTransferFlow
+	validator *validator.Validate
+}
+
+func NewWalletTransferAdminHandler(flow businessflow.WalletTransferFlow) WalletTransferAdminHandlerInterface {
+	return &WalletTransferAdminHandler{
+		flow:      flow,
+		validator: validator.New(),
+	}
+}
+
+func (h *WalletTransferAdminHandler) ErrorResponse(c fiber.Ctx, status int, message, code string, details any) error {
+	return apierror.Respond(c, status, message, code, details)
+}
+
+func (h *WalletTransferAdminHandler) SuccessResponse(c fiber.Ctx, status int, message string, data any) error {
+	return c.Status(status).JSON(dto.APIResponse{Success: true, Message: message, Data: data})
+}
+
+// List returns wallet transfers between customer accounts
+// @Summary List Wallet Transfers (Admin)
+// @Description List wallet transfers between customer accounts, newest first, with their OTP attempts and the transactions of both sides
+// @Tags Payments Admin
+// @Produce json
+// @Param customer_id query int false "Filter by sender or receiver customer ID"
+// @Param status query string false "Filter by status (pending|completed|expired)"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Page size" default(10) maximum(100)
+// @Success 200 {object} dto.APIResponse{data=dto.AdminListWalletTransfersResponse}
+// @Failure 400 {object} dto.APIResponse "Validation error"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security AdminBearer
+// @Router /api/v1/admin/payments/wallet-transfers [get]
+func (h *WalletTransferAdminHandler) List(c fiber.Ctx) error {
+	page, err := strconv.Atoi(c.Query("page", "1"))
+	if err != nil || page <= 0 {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid page format", "INVALID_PAGE", nil)
+	}
+	limit, err := strconv.Atoi(c.Query("limit", "10"))
+	if err != nil || limit <= 0 {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid limit format", "INVALID_LIMIT", nil)
+	}
+
+	filter := dto.AdminListWalletTransfersFilter{Page: page, Limit: limit}
+	if v := strings.TrimSpace(c.Query("customer_id")); v != "" {
+		id, err := strconv.ParseUint(v, 10, 64)
+		if err != nil || id == 0 {
+			return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", nil)
+		}
+		filter.CustomerID = utils.ToPtr(uint(id))
+	}
+	if status := strings.TrimSpace(c.Query("status")); status != "" {
+		filter.Status = &status
+	}
+	if err := h.validator.Struct(filter); err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", nil)
+	}
+
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/admin/payments/wallet-transfers", 30*time.Second)
+	defer cancel()
+	res, err := h.flow.AdminList(ctx, filter)
+	if err != nil {
+		log.Println("List wallet transfers failed:", err)
+		return h.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to list wallet transfers", "WALLET_TRANSFER_LIST_FAILED", nil)
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, res.Message, res)
+}
+
+func (h *WalletTransferAdminHandler) createRequestContextWithTimeout(c fiber.Ctx, endpoint string, timeout time.Duration) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	ctx = context.WithValue(ctx, utils.RequestIDKey, c.Get("X-Request-ID"))
+	ctx = context.WithValue(ctx, utils.UserAgentKey, c.Get("User-Agent"))
+	ctx = context.WithValue(ctx, utils.IPAddressKey, c.IP())
+	ctx = context.WithValue(ctx, utils.EndpointKey, endpoint)
+	ctx = context.WithValue(ctx, utils.TimeoutKey, timeout)
+	ctx = context.WithValue(ctx, utils.CancelFuncKey, cancel)
+	if adminID, ok := middleware.GetAdminIDFromContext(c); ok {
+		ctx = context.WithValue(ctx, utils.AdminIDKey, adminID)
+	}
+	return ctx, cancel
+}
@@ -0,0 +1,236 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/apierror"
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/app/middleware"
+	businessflow "github.com/amirphl/Yamata-no-Orochi/business_flow"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+)
+
+// WalletTransferHandlerInterface defines the endpoints customers move balance
+// between accounts of their company with
+type WalletTransferHandlerInterface interface {
+	Initiate(c fiber.Ctx) error
+	Confirm(c fiber.Ctx) error
+	List(c fiber.Ctx) error
+}
+
+// WalletTransferHandler implements the wallet transfer endpoints
+type WalletTransferHandler struct {
+	flow      businessflow.WalletTransferFlow
+	validator *validator.Validate
+}
+
+func NewWalletTransferHandler(flow businessflow.WalletTransferFlow) WalletTransferHandlerInterface {
+	return &WalletTransferHandler{
+		flow:      flow,
+		validator: validator.New(),
+	}
+}
+
+func (h *WalletTransferHandler) ErrorResponse(c fiber.Ctx, status int, message, code string, details any) error {
+	return apierror.Respond(c, status, message, code, details)
+}
+
+func (h *WalletTransferHandler) SuccessResponse(c fiber.Ctx, status int, message string, data any) error {
+	return c.Status(status).JSON(dto.APIResponse{Success: true, Message: message, Data: data})
+}
+
+// Initiate starts a wallet transfer
+// @Summary Start Wallet Transfer
+// @Description Start moving free balance to another account of the same company, named by its mobile or email. Both accounts must share a company national ID and neither may be a sandbox account. The amount must be within the configured range and daily limit. An OTP is sent to the sender's mobile; the wallets change only once it is confirmed.
+// @Tags Wallet
+// @Accept json
+// @Produce json
+// @Param request body dto.InitiateWalletTransferRequest true "Transfer payload"
+// @Success 201 {object} dto.APIResponse{data=dto.InitiateWalletTransferResponse}
+// @Failure 400 {object} dto.APIResponse "Validation error, receiver not eligible or amount out of range"
+// @Failure 401 {object} dto.APIResponse "Unauthorized"
+// @Failure 403 {object} dto.APIResponse "Not allowed while impersonating the customer"
+// @Failure 409 {object} dto.APIResponse "Transfers disabled, insufficient funds or daily limit reached"
+// @Failure 429 {object} dto.APIResponse "OTP requested too often"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
+// @Router /api/v1/wallet/transfers [post]
+func (h *WalletTransferHandler) Initiate(c fiber.Ctx) error {
+	var req dto.InitiateWalletTransferRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body", "INVALID_REQUEST", err.Error())
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		var validationErrors []string
+		for _, err := range err.(validator.ValidationErrors) {
+			validationErrors = append(validationErrors, getValidationErrorMessage(c, err))
+		}
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", validationErrors)
+	}
+
+	customerID, ok := c.Locals("customer_id").(uint)
+	if !ok {
+		return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer ID not found in context", "MISSING_CUSTOMER_ID", nil)
+	}
+	req.CustomerID = customerID
+
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/wallet/transfers", 15*time.Second)
+	defer cancel()
+	res, err := h.flow.Initiate(ctx, &req, businessflow.NewClientMetadata(c.IP(), c.Get("User-Agent")))
+	if err != nil {
+		return h.handleError(c, "Start wallet transfer failed:", err, "Failed to start wallet transfer", "WALLET_TRANSFER_INITIATE_FAILED")
+	}
+	return h.SuccessResponse(c, fiber.StatusCreated, res.Message, res)
+}
+
+// Confirm completes a wallet transfer
+// @Summary Confirm Wallet Transfer
+// @Description Confirm a pending wallet transfer with the OTP sent to the sender's mobile. The amount moves from the sender's free balance to the receiver's in one step, recorded as a transfer_out and a transfer_in transaction sharing a correlation ID. Five wrong codes, or an expired code, end the transfer; start a new one.
+// @Tags Wallet
+// @Accept json
+// @Produce json
+// @Param uuid path string true "Transfer UUID"
+// @Param request body dto.ConfirmWalletTransferRequest true "OTP code"
+// @Success 200 {object} dto.APIResponse{data=dto.ConfirmWalletTransferResponse}
+// @Failure 400 {object} dto.APIResponse "Validation error, wrong or expired code"
+// @Failure 401 {object} dto.APIResponse "Unauthorized"
+// @Failure 403 {object} dto.APIResponse "Not allowed while impersonating the customer"
+// @Failure 404 {object} dto.APIResponse "Transfer not found"
+// @Failure 409 {object} dto.APIResponse "Transfer no longer pending, insufficient funds or daily limit reached"
+// @Failure 429 {object} dto.APIResponse "Too many wrong codes"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
+// @Router /api/v1/wallet/transfers/{uuid}/confirm [post]
+func (h *WalletTransferHandler) Confirm(c fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("uuid"))
+	if err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid uuid", "INVALID_UUID", nil)
+	}
+	var req dto.ConfirmWalletTransferRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body", "INVALID_REQUEST", err.Error())
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		var validationErrors []string
+		for _, err := range err.(validator.ValidationErrors) {
+			validationErrors = append(validationErrors, getValidationErrorMessage(c, err))
+		}
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", validationErrors)
+	}
+
+	customerID, ok := c.Locals("customer_id").(uint)
+	if !ok {
+		return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer ID not found in context", "MISSING_CUSTOMER_ID", nil)
+	}
+	req.CustomerID = customerID
+
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/wallet/transfers/confirm", 30*time.Second)
+	defer cancel()
+	res, err := h.flow.Confirm(ctx, id, &req, businessflow.NewClientMetadata(c.IP(), c.Get("User-Agent")))
+	if err != nil {
+		return h.handleError(c, "Confirm wallet transfer failed:", err, "Failed to confirm wallet transfer", "WALLET_TRANSFER_CONFIRM_FAILED")
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, res.Message, res)
+}
+
+// List returns the customer's wallet transfers
+// @Summary List Wallet Transfers
+// @Description List the wallet transfers the customer sent or received, newest first
+// @Tags Wallet
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Page size" default(10) maximum(100)
+// @Success 200 {object} dto.APIResponse{data=dto.ListWalletTransfersResponse}
+// @Failure 400 {object} dto.APIResponse "Validation error"
+// @Failure 401 {object} dto.APIResponse "Unauthorized"
+// @Failure 500 {object} dto.APIResponse "Internal server error"
+// @Security CustomerBearer
+// @Router /api/v1/wallet/transfers [get]
+func (h *WalletTransferHandler) List(c fiber.Ctx) error {
+	page, err := strconv.Atoi(c.Query("page", "1"))
+	if err != nil || page <= 0 {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid page format", "INVALID_PAGE", nil)
+	}
+	limit, err := strconv.Atoi(c.Query("limit", "10"))
+	if err != nil || limit <= 0 {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid limit format", "INVALID_LIMIT", nil)
+	}
+	customerID, ok := c.Locals("customer_id").(uint)
+	if !ok {
+		return h.ErrorResponse(c, fiber.StatusUnauthorized, "Customer ID not found in context", "MISSING_CUSTOMER_ID", nil)
+	}
+	req := dto.ListWalletTransfersRequest{CustomerID: customerID, Page: page, Limit: limit}
+	if err := h.validator.Struct(req); err != nil {
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", "VALIDATION_ERROR", nil)
+	}
+
+	ctx, cancel := h.createRequestContextWithTimeout(c, "/api/v1/wallet/transfers", 10*time.Second)
+	defer cancel()
+	res, err := h.flow.List(ctx, &req)
+	if err != nil {
+		return h.handleError(c, "List wallet transfers failed:", err, "Failed to list wallet transfers", "WALLET_TRANSFER_LIST_FAILED")
+	}
+	return h.SuccessResponse(c, fiber.StatusOK, res.Message, res)
+}
+
+func (h *WalletTransferHandler) handleError(c fiber.Ctx, logPrefix string, err error, message, code string) error {
+	if retryAfter, ok := businessflow.OTPRetryAfter(err); ok {
+		seconds := max(1, int((retryAfter+time.Second-1)/time.Second))
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(seconds))
+		return h.ErrorResponse(c, fiber.StatusTooManyRequests, "Please wait before requesting another OTP", "RATE_LIMITED", fiber.Map{
+			"retry_after_seconds": seconds,
+		})
+	}
+	switch {
+	case businessflow.IsWalletTransferDisabled(err):
+		return h.ErrorResponse(c, fiber.StatusConflict, "Wallet transfers are disabled", "WALLET_TRANSFER_DISABLED", nil)
+	case businessflow.IsCustomerNotFound(err):
+		return h.ErrorResponse(c, fiber.StatusNotFound, "Customer not found", "CUSTOMER_NOT_FOUND", nil)
+	case businessflow.IsAccountInactive(err):
+		return h.ErrorResponse(c, fiber.StatusForbidden, "Customer account is inactive", "ACCOUNT_INACTIVE", nil)
+	case businessflow.IsWalletNotFound(err):
+		return h.ErrorResponse(c, fiber.StatusNotFound, "Wallet not found", "WALLET_NOT_FOUND", nil)
+	case businessflow.IsWalletTransferSelf(err):
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Cannot transfer to the same account", "WALLET_TRANSFER_SELF", nil)
+	case businessflow.IsWalletTransferReceiverNotEligible(err):
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Receiver is not an account of the same company", "WALLET_TRANSFER_RECEIVER_NOT_ELIGIBLE", nil)
+	case businessflow.IsWalletTransferSandbox(err):
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Sandbox accounts cannot transfer balance", "WALLET_TRANSFER_SANDBOX", nil)
+	case businessflow.IsWalletTransferAmountOutOfRange(err):
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Transfer amount is outside the allowed range", "WALLET_TRANSFER_AMOUNT_OUT_OF_RANGE", nil)
+	case businessflow.IsWalletTransferDailyLimitExceeded(err):
+		return h.ErrorResponse(c, fiber.StatusConflict, "Transfer exceeds the daily transfer limit", "WALLET_TRANSFER_DAILY_LIMIT_EXCEEDED", nil)
+	case businessflow.IsInsufficientFunds(err):
+		return h.ErrorResponse(c, fiber.StatusConflict, "Insufficient funds", "INSUFFICIENT_FUNDS", nil)
+	case businessflow.IsWalletTransferNotFound(err):
+		return h.ErrorResponse(c, fiber.StatusNotFound, "Wallet transfer not found", "WALLET_TRANSFER_NOT_FOUND", nil)
+	case businessflow.IsWalletTransferNotPending(err):
+		return h.ErrorResponse(c, fiber.StatusConflict, "Wallet transfer is no longer pending", "WALLET_TRANSFER_NOT_PENDING", nil)
+	case businessflow.IsWalletTransferOTPExpired(err):
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Wallet transfer code expired; start a new transfer", "WALLET_TRANSFER_OTP_EXPIRED", nil)
+	case businessflow.IsWalletTransferOTPInvalid(err):
+		return h.ErrorResponse(c, fiber.StatusBadRequest, "Invalid wallet transfer code", "WALLET_TRANSFER_OTP_INVALID", nil)
+	case businessflow.IsRateLimitExceeded(err):
+		return h.ErrorResponse(c, fiber.StatusTooManyRequests, "Too many wrong codes; start a new transfer", "WALLET_TRANSFER_LOCKED", nil)
+	}
+	log.Println(logPrefix, err)
+	return h.ErrorResponse(c, fiber.StatusInternalServerError, message, code, nil)
+}
+
+func (h *WalletTransferHandler) createRequestContextWithTimeout(c fiber.Ctx, endpoint string, timeout time.Duration) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	ctx = context.WithValue(ctx, utils.RequestIDKey, c.Get("X-Request-ID"))
+	ctx = context.WithValue(ctx, utils.UserAgentKey, c.Get("User-Agent"))
+	ctx = context.WithValue(ctx, utils.IPAddressKey, c.IP())
+	ctx = context.WithValue(ctx, utils.EndpointKey, endpoint)
+	ctx = context.WithValue(ctx, utils.TimeoutKey, timeout)
+	ctx = context.WithValue(ctx, utils.CancelFuncKey, cancel)
+	ctx = middleware.WithImpersonation(ctx, c)
+	return ctx, cancel
+}
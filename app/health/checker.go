@@ -0,0 +1,182 @@
+// Package health runs the dependency checks behind the liveness, readiness
+// and startup probes
+package health
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+)
+
+// Check statuses; a readiness report is StatusDegraded when only
+// non-critical checks fail
+const (
+	StatusOK          = "ok"
+	StatusDegraded    = "degraded"
+	StatusUnavailable = "unavailable"
+)
+
+// Check is a single dependency check
+type Check struct {
+	Name string
+	// Critical checks take the instance out of rotation when they fail;
+	// others are only reported
+	Critical bool
+	// CacheTTL reuses the last result for this long, so frequent probes do not
+	// hammer remote providers. Zero runs the check on every probe.
+	CacheTTL time.Duration
+	Run      func(ctx context.Context) error
+}
+
+// CheckResult is the outcome of a check
+type CheckResult struct {
+	Name      string    `json:"name"`
+	Status    string    `json:"status"`
+	Critical  bool      `json:"critical"`
+	Error     string    `json:"error,omitempty"`
+	LatencyMS int64     `json:"latency_ms"`
+	CheckedAt time.Time `json:"checked_at"`
+	Cached    bool      `json:"cached"`
+}
+
+// Report is the outcome of a readiness probe
+type Report struct {
+	Status string        `json:"status"`
+	Checks []CheckResult `json:"checks"`
+}
+
+// Ready reports whether every critical check passed
+func (r Report) Ready() bool {
+	return r.Status != StatusUnavailable
+}
+
+// Checker runs the readiness checks and tracks the startup and shutdown
+// state of the process
+type Checker struct {
+	checks  []Check
+	timeout time.Duration
+
+	mu     sync.Mutex
+	cached map[string]CheckResult
+
+	started  atomic.Bool
+	draining atomic.Bool
+	// skipOptional leaves the non-critical checks out of readiness reports
+	skipOptional atomic.Bool
+}
+
+// NewChecker creates a Checker; each check gets at most timeout to finish
+func NewChecker(timeout time.Duration, checks ...Check) *Checker {
+	if timeout <= 0 {
+		timeout = 2 * time.Second
+	}
+	return &Checker{
+		checks:  checks,
+		timeout: timeout,
+		cached:  make(map[string]CheckResult),
+	}
+}
+
+// MarkStarted records that initialization finished and the server listens
+func (c *Checker) MarkStarted() {
+	c.started.Store(true)
+}
+
+// Started reports whether MarkStarted was called
+func (c *Checker) Started() bool {
+	return c.started.Load()
+}
+
+// MarkDraining records that shutdown began; readiness fails from then on so
+// the load balancer stops sending new requests
+func (c *Checker) MarkDraining() {
+	c.draining.Store(true)
+}
+
+// Draining reports whether MarkDraining was called
+func (c *Checker) Draining() bool {
+	return c.draining.Load()
+}
+
+// SetOptionalChecks turns the non-critical checks, the external providers,
+// on or off while serving
+func (c *Checker) SetOptionalChecks(enabled bool) {
+	c.skipOptional.Store(!enabled)
+}
+
+// Readiness runs all checks concurrently and summarizes them
+func (c *Checker) Readiness(ctx context.Context) Report {
+	checks := c.checks
+	if c.skipOptional.Load() {
+		checks = make([]Check, 0, len(c.checks))
+		for _, check := range c.checks {
+			if check.Critical {
+				checks = append(checks, check)
+			}
+		}
+	}
+
+	results := make([]CheckResult, len(checks))
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = c.run(ctx, check)
+		}()
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusOK, Checks: results}
+	for _, result := range results {
+		if result.Status == StatusOK {
+			continue
+		}
+		if result.Critical {
+			report.Status = StatusUnavailable
+			break
+		}
+		report.Status = StatusDegraded
+	}
+	return report
+}
+
+func (c *Checker) run(ctx context.Context, check Check) CheckResult {
+	now := utils.UTCNow()
+	if check.CacheTTL > 0 {
+		c.mu.Lock()
+		result, ok := c.cached[check.Name]
+		c.mu.Unlock()
+		if ok && now.Sub(result.CheckedAt) < check.CacheTTL {
+			result.Cached = true
+			return result
+		}
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check.Run(checkCtx)
+	result := CheckResult{
+		Name:      check.Name,
+		Status:    StatusOK,
+		Critical:  check.Critical,
+		LatencyMS: time.Since(start).Milliseconds(),
+		CheckedAt: now,
+	}
+	if err != nil {
+		result.Status = StatusUnavailable
+		result.Error = err.Error()
+	}
+
+	if check.CacheTTL > 0 {
+		c.mu.Lock()
+		c.cached[check.Name] = result
+		c.mu.Unlock()
+	}
+	return result
+}
@@ -0,0 +1,134 @@
+package health_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/health"
+)
+
+func staticCheck(name string, critical bool, err error) health.Check {
+	return health.Check{Name: name, Critical: critical, Run: func(context.Context) error { return err }}
+}
+
+func TestReadinessStatus(t *testing.T) {
+	t.Parallel()
+
+	down := errors.New("down")
+	cases := []struct {
+		name   string
+		checks []health.Check
+		want   string
+	}{
+		{"all pass", []health.Check{staticCheck("db", true, nil), staticCheck("sms", false, nil)}, health.StatusOK},
+		{"provider fails", []health.Check{staticCheck("db", true, nil), staticCheck("sms", false, down)}, health.StatusDegraded},
+		{"critical fails", []health.Check{staticCheck("sms", false, down), staticCheck("db", true, down)}, health.StatusUnavailable},
+		{"no checks", nil, health.StatusOK},
+	}
+	for _, tc := range cases {
+		report := health.NewChecker(time.Second, tc.checks...).Readiness(context.Background())
+		if report.Status != tc.want {
+			t.Errorf("%s: status %s, want %s", tc.name, report.Status, tc.want)
+		}
+		if report.Ready() != (tc.want != health.StatusUnavailable) {
+			t.Errorf("%s: Ready() = %v", tc.name, report.Ready())
+		}
+		if len(report.Checks) != len(tc.checks) {
+			t.Errorf("%s: %d results for %d checks", tc.name, len(report.Checks), len(tc.checks))
+		}
+	}
+}
+
+func TestReadinessCachesResults(t *testing.T) {
+	t.Parallel()
+
+	var runs atomic.Int32
+	cached := health.Check{Name: "provider", CacheTTL: time.Minute, Run: func(context.Context) error {
+		runs.Add(1)
+		return nil
+	}}
+	checker := health.NewChecker(time.Second, cached)
+
+	first := checker.Readiness(context.Background())
+	second := checker.Readiness(context.Background())
+	if runs.Load() != 1 {
+		t.Fatalf("cached check ran %d times", runs.Load())
+	}
+	if first.Checks[0].Cached || !second.Checks[0].Cached {
+		t.Errorf("cached flags %v, %v", first.Checks[0].Cached, second.Checks[0].Cached)
+	}
+}
+
+func TestReadinessTimesOutChecks(t *testing.T) {
+	t.Parallel()
+
+	slow := health.Check{Name: "db", Critical: true, Run: func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}}
+	report := health.NewChecker(20*time.Millisecond, slow).Readiness(context.Background())
+	if report.Status != health.StatusUnavailable || report.Checks[0].Error == "" {
+		t.Errorf("slow check reported %+v", report)
+	}
+}
+
+func TestProviderCheck(t *testing.T) {
+	t.Parallel()
+
+	status := http.StatusNotFound
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	check := health.ProviderCheck("sms", srv.URL, srv.Client(), 0)
+	if check.Critical {
+		t.Error("provider checks must not be critical")
+	}
+	if err := check.Run(context.Background()); err != nil {
+		t.Errorf("a 404 means reachable, got %v", err)
+	}
+
+	status = http.StatusBadGateway
+	if err := check.Run(context.Background()); err == nil {
+		t.Error("a 502 must fail the check")
+	}
+
+	srv.Close()
+	if err := check.Run(context.Background()); err == nil {
+		t.Error("an unreachable provider must fail the check")
+	}
+}
+
+func TestStartedAndDraining(t *testing.T) {
+	t.Parallel()
+
+	checker := health.NewChecker(0)
+	if checker.Started() || checker.Draining() {
+		t.Fatal("a new checker is neither started nor draining")
+	}
+	checker.MarkStarted()
+	checker.MarkDraining()
+	if !checker.Started() || !checker.Draining() {
+		t.Error("marks were not recorded")
+	}
+}
+
+func TestSetOptionalChecks(t *testing.T) {
+	t.Parallel()
+
+	checker := health.NewChecker(time.Second, staticCheck("db", true, nil), staticCheck("sms", false, errors.New("down")))
+	checker.SetOptionalChecks(false)
+	if report := checker.Readiness(context.Background()); report.Status != health.StatusOK || len(report.Checks) != 1 {
+		t.Errorf("with optional checks off: %+v", report)
+	}
+	checker.SetOptionalChecks(true)
+	if report := checker.Readiness(context.Background()); report.Status != health.StatusDegraded || len(report.Checks) != 2 {
+		t.Errorf("with optional checks on: %+v", report)
+	}
+}
@@ -0,0 +1,71 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// DatabaseCheck pings a GORM database; it is critical
+func DatabaseCheck(name string, db *gorm.DB) Check {
+	return Check{
+		Name:     name,
+		Critical: true,
+		Run: func(ctx context.Context) error {
+			sqlDB, err := db.DB()
+			if err != nil {
+				return err
+			}
+			return sqlDB.PingContext(ctx)
+		},
+	}
+}
+
+// RedisCheck pings Redis; it is critical
+func RedisCheck(client *redis.Client) Check {
+	return Check{
+		Name:     "redis",
+		Critical: true,
+		Run: func(ctx context.Context) error {
+			if client == nil {
+				return errors.New("redis client is not configured")
+			}
+			return client.Ping(ctx).Err()
+		},
+	}
+}
+
+// ProviderCheck tells whether an external provider is reachable. Any HTTP
+// response counts, since probes send no credentials; only network errors and
+// 5xx responses fail. It is not critical, so a provider outage degrades the
+// report without taking instances out of rotation, and results are cached
+// for cacheTTL.
+func ProviderCheck(name, url string, client *http.Client, cacheTTL time.Duration) Check {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return Check{
+		Name:     name,
+		CacheTTL: cacheTTL,
+		Run: func(ctx context.Context) error {
+			req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+			if err != nil {
+				return err
+			}
+			resp, err := client.Do(req)
+			if err != nil {
+				return err
+			}
+			resp.Body.Close()
+			if resp.StatusCode >= http.StatusInternalServerError {
+				return fmt.Errorf("%s responded with %d", url, resp.StatusCode)
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,43 @@
+package health
+
+import (
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/httpclient"
+)
+
+// Gateway is the state of a payment gateway as seen by the circuit breaker
+// of its outbound client
+type Gateway struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	// Since is when the breaker opened, while the gateway is unavailable
+	Since *time.Time `json:"since,omitempty"`
+}
+
+// GatewayReport is StatusDegraded while any gateway is unavailable
+type GatewayReport struct {
+	Status   string    `json:"status"`
+	Gateways []Gateway `json:"gateways"`
+}
+
+// Gateways reports the named payment gateways. It reads breaker state only,
+// so it never calls the gateways themselves.
+func Gateways(names ...string) GatewayReport {
+	report := GatewayReport{Status: StatusOK, Gateways: make([]Gateway, 0, len(names))}
+	for _, name := range names {
+		gateway := Gateway{Name: name, Status: StatusOK}
+		if !httpclient.Available(name) {
+			gateway.Status = StatusUnavailable
+			report.Status = StatusDegraded
+			for _, b := range httpclient.Breakers(name) {
+				if b.Open && (gateway.Since == nil || b.OpenedAt.Before(*gateway.Since)) {
+					openedAt := b.OpenedAt
+					gateway.Since = &openedAt
+				}
+			}
+		}
+		report.Gateways = append(report.Gateways, gateway)
+	}
+	return report
+}
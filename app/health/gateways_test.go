@@ -0,0 +1,36 @@
+package health_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/health"
+	"github.com/amirphl/Yamata-no-Orochi/app/httpclient"
+)
+
+func TestGatewaysReportsOpenBreaker(t *testing.T) {
+	httpclient.Configure(httpclient.Config{BreakerThreshold: 1, BreakerCooldown: time.Hour})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	resp, err := httpclient.New("gateway_down", time.Second).Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	report := health.Gateways("gateway_up", "gateway_down")
+	if report.Status != health.StatusDegraded {
+		t.Fatalf("report status = %s, want %s", report.Status, health.StatusDegraded)
+	}
+	if up := report.Gateways[0]; up.Status != health.StatusOK || up.Since != nil {
+		t.Fatalf("unused gateway reported %+v", up)
+	}
+	if down := report.Gateways[1]; down.Status != health.StatusUnavailable || down.Since == nil {
+		t.Fatalf("failing gateway reported %+v", down)
+	}
+}
@@ -0,0 +1,103 @@
+package httpclient
+
+import (
+	"sync"
+	"time"
+)
+
+// breaker is a consecutive-failure circuit breaker for one client and host.
+// While open it rejects requests; once the cooldown passes it half-opens and
+// lets a single trial request decide whether to close again.
+type breaker struct {
+	client string
+	host   string
+
+	mu       sync.Mutex
+	failures int
+	openedAt time.Time
+	open     bool
+	trial    bool
+}
+
+func (b *breaker) allow(cooldown time.Duration) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.open {
+		return true
+	}
+	if b.trial || time.Since(b.openedAt) < cooldown {
+		return false
+	}
+	b.trial = true
+	return true
+}
+
+// record settles an attempt let through by allow
+func (b *breaker) record(failed bool, threshold int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !failed {
+		b.failures = 0
+		if b.open {
+			b.open, b.trial = false, false
+			circuitOpen.WithLabelValues(b.client, b.host).Set(0)
+		}
+		return
+	}
+	b.failures++
+	if b.trial || (threshold > 0 && b.failures >= threshold) {
+		b.open, b.trial = true, false
+		b.openedAt = time.Now()
+		circuitOpen.WithLabelValues(b.client, b.host).Set(1)
+	}
+}
+
+// release returns an attempt without judging the host, so a half-open
+// breaker can try again
+func (b *breaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.trial = false
+}
+
+// BreakerStatus is the state of the breaker for one client and host
+type BreakerStatus struct {
+	Client   string
+	Host     string
+	Open     bool
+	OpenedAt time.Time
+	Failures int
+}
+
+// Breakers returns the breakers of the named client that have seen traffic
+func Breakers(client string) []BreakerStatus {
+	s := current()
+	s.mu.Lock()
+	list := make([]*breaker, 0, len(s.breakers))
+	for _, b := range s.breakers {
+		if b.client == client {
+			list = append(list, b)
+		}
+	}
+	s.mu.Unlock()
+
+	statuses := make([]BreakerStatus, 0, len(list))
+	for _, b := range list {
+		b.mu.Lock()
+		statuses = append(statuses, BreakerStatus{Client: b.client, Host: b.host, Open: b.open, OpenedAt: b.openedAt, Failures: b.failures})
+		b.mu.Unlock()
+	}
+	return statuses
+}
+
+// Available reports whether the named client can reach its hosts: no breaker
+// is open, or the cooldown has passed and a trial request may go through
+func Available(client string) bool {
+	cooldown := current().cfg.BreakerCooldown
+	for _, b := range Breakers(client) {
+		if b.Open && time.Since(b.OpenedAt) < cooldown {
+			return false
+		}
+	}
+	return true
+}
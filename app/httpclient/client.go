@@ -0,0 +1,319 @@
+// Package httpclient builds the http.Clients used for outbound calls to
+// payment gateways, SMS providers, bots and other third parties. Every client
+// shares one pooled transport, applies per-host timeouts, retries idempotent
+// requests on transient failures, trips a circuit breaker per host and
+// records metrics labelled by client name.
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+)
+
+// ErrCircuitOpen is returned without contacting the host while its circuit
+// breaker is open
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+// requestIDHeader passes the ID of the request being served to providers, so
+// a call can be traced in their logs too
+const requestIDHeader = "X-Request-ID"
+
+type Config struct {
+	MaxIdleConns        int
+	MaxIdleConnsPerHost int
+	IdleConnTimeout     time.Duration
+	// HostTimeouts overrides the client timeout for requests to a host
+	HostTimeouts map[string]time.Duration
+	// Idempotent requests are retried up to RetryMax times, waiting
+	// RetryBackoff and doubling per attempt
+	RetryMax     int
+	RetryBackoff time.Duration
+	// A host's breaker opens after BreakerThreshold consecutive failures
+	// and lets a trial request through after BreakerCooldown
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+	// Mocks answers the requests of the named clients in-process
+	Mocks map[string]Mock
+}
+
+func defaultConfig() Config {
+	return Config{
+		MaxIdleConns:        100,
+		MaxIdleConnsPerHost: 10,
+		IdleConnTimeout:     90 * time.Second,
+		RetryMax:            2,
+		RetryBackoff:        200 * time.Millisecond,
+		BreakerThreshold:    5,
+		BreakerCooldown:     30 * time.Second,
+	}
+}
+
+type state struct {
+	cfg      Config
+	base     *http.Transport
+	mu       sync.Mutex
+	proxied  map[string]*http.Transport
+	breakers map[string]*breaker
+	mocks    map[string]*mockTransport
+}
+
+var (
+	stateMu sync.RWMutex
+	active  = newState(defaultConfig())
+)
+
+func newState(cfg Config) *state {
+	s := &state{
+		cfg:      cfg,
+		base:     newTransport(cfg),
+		proxied:  make(map[string]*http.Transport),
+		breakers: make(map[string]*breaker),
+		mocks:    make(map[string]*mockTransport),
+	}
+	for name, mock := range cfg.Mocks {
+		if mock.Handler != nil {
+			s.mocks[name] = &mockTransport{mock: mock}
+		}
+	}
+	return s
+}
+
+func newTransport(cfg Config) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConns = cfg.MaxIdleConns
+	transport.MaxIdleConnsPerHost = cfg.MaxIdleConnsPerHost
+	transport.IdleConnTimeout = cfg.IdleConnTimeout
+	return transport
+}
+
+// Configure replaces the pool, timeouts, retry and breaker settings used by
+// every client, including the ones already built
+func Configure(cfg Config) {
+	next := newState(cfg)
+	stateMu.Lock()
+	prev := active
+	active = next
+	stateMu.Unlock()
+	prev.base.CloseIdleConnections()
+	prev.mu.Lock()
+	defer prev.mu.Unlock()
+	for _, t := range prev.proxied {
+		t.CloseIdleConnections()
+	}
+}
+
+func current() *state {
+	stateMu.RLock()
+	defer stateMu.RUnlock()
+	return active
+}
+
+// transport returns the named client's mock, the shared transport, or the
+// one for proxyURL
+func (s *state) transport(name string, proxy *url.URL) http.RoundTripper {
+	if mock, ok := s.mocks[name]; ok {
+		return mock
+	}
+	if proxy == nil {
+		return s.base
+	}
+	key := proxy.String()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if t, ok := s.proxied[key]; ok {
+		return t
+	}
+	t := newTransport(s.cfg)
+	t.Proxy = http.ProxyURL(proxy)
+	s.proxied[key] = t
+	return t
+}
+
+func (s *state) breaker(client, host string) *breaker {
+	key := client + "|" + host
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, ok := s.breakers[key]
+	if !ok {
+		b = &breaker{client: client, host: host}
+		s.breakers[key] = b
+	}
+	return b
+}
+
+// New returns a client for the named integration. timeout bounds each
+// attempt unless the host has its own timeout configured.
+func New(name string, timeout time.Duration) *http.Client {
+	return &http.Client{Transport: &roundTripper{name: name, timeout: timeout}}
+}
+
+// NewWithProxy is New routed through proxyURL; an empty proxyURL means no proxy
+func NewWithProxy(name string, timeout time.Duration, proxyURL string) (*http.Client, error) {
+	proxyURL = strings.TrimSpace(proxyURL)
+	if proxyURL == "" {
+		return New(name, timeout), nil
+	}
+	parsed, err := url.Parse(proxyURL)
+	if err != nil {
+		return nil, fmt.Errorf("parse proxy url: %w", err)
+	}
+	return &http.Client{Transport: &roundTripper{name: name, timeout: timeout, proxy: parsed}}, nil
+}
+
+type roundTripper struct {
+	name    string
+	timeout time.Duration
+	proxy   *url.URL
+}
+
+func (rt *roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if id, ok := req.Context().Value(utils.RequestIDKey).(string); ok && id != "" && req.Header.Get(requestIDHeader) == "" {
+		req = req.Clone(req.Context())
+		req.Header.Set(requestIDHeader, id)
+	}
+
+	s := current()
+	host := req.URL.Host
+	timeout := rt.timeout
+	if t, ok := s.cfg.HostTimeouts[req.URL.Hostname()]; ok && t > 0 {
+		timeout = t
+	}
+	b := s.breaker(rt.name, host)
+	transport := s.transport(rt.name, rt.proxy)
+
+	retries := 0
+	if retryable(req) {
+		retries = s.cfg.RetryMax
+	}
+	backoff := s.cfg.RetryBackoff
+
+	for attempt := 0; ; attempt++ {
+		if !b.allow(s.cfg.BreakerCooldown) {
+			observe(rt.name, host, req.Method, "circuit_open", 0)
+			return nil, fmt.Errorf("%s %s: %w", rt.name, host, ErrCircuitOpen)
+		}
+
+		attemptReq := req
+		if attempt > 0 {
+			var err error
+			if attemptReq, err = rewind(req); err != nil {
+				return nil, err
+			}
+		}
+		resp, err := rt.do(transport, attemptReq, timeout)
+
+		failed := err != nil || resp.StatusCode >= http.StatusInternalServerError
+		// The caller giving up says nothing about the host's health
+		if err != nil && req.Context().Err() != nil {
+			b.release()
+		} else {
+			b.record(failed, s.cfg.BreakerThreshold)
+		}
+
+		if attempt >= retries || !shouldRetry(req, resp, err) {
+			return resp, err
+		}
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// do sends one attempt bounded by timeout; the timeout keeps running while
+// the caller reads the body and is released when the body is closed
+func (rt *roundTripper) do(transport http.RoundTripper, req *http.Request, timeout time.Duration) (*http.Response, error) {
+	start := time.Now()
+	ctx, cancel := context.Background(), context.CancelFunc(func() {})
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(req.Context(), timeout)
+		req = req.WithContext(ctx)
+	}
+	resp, err := transport.RoundTrip(req)
+	elapsed := time.Since(start)
+	if err != nil {
+		cancel()
+		outcome := "error"
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			outcome = "timeout"
+		}
+		observe(rt.name, req.URL.Host, req.Method, outcome, elapsed)
+		return nil, err
+	}
+	observe(rt.name, req.URL.Host, req.Method, statusOutcome(resp.StatusCode), elapsed)
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
+
+// retryable reports whether sending req twice is safe: idempotent methods,
+// or any request carrying an Idempotency-Key, with a body that can be replayed
+func retryable(req *http.Request) bool {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return req.Header.Get("Idempotency-Key") != ""
+}
+
+func shouldRetry(req *http.Request, resp *http.Response, err error) bool {
+	if err != nil {
+		return req.Context().Err() == nil
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+func rewind(req *http.Request) (*http.Request, error) {
+	clone := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, fmt.Errorf("rewind request body: %w", err)
+		}
+		clone.Body = body
+	}
+	return clone, nil
+}
+
+func statusOutcome(code int) string {
+	switch {
+	case code >= 500:
+		return "5xx"
+	case code >= 400:
+		return "4xx"
+	default:
+		return "ok"
+	}
+}
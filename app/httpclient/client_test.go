@@ -0,0 +1,251 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+)
+
+func testConfig() Config {
+	cfg := defaultConfig()
+	cfg.RetryBackoff = time.Millisecond
+	return cfg
+}
+
+func countingServer(t *testing.T, handler func(n int32, w http.ResponseWriter)) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler(calls.Add(1), w)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &calls
+}
+
+func TestClientRetriesIdempotentRequests(t *testing.T) {
+	Configure(testConfig())
+	srv, calls := countingServer(t, func(n int32, w http.ResponseWriter) {
+		if n < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+
+	resp, err := New("test", time.Second).Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || calls.Load() != 3 {
+		t.Fatalf("status %d after %d calls, want 200 after 3", resp.StatusCode, calls.Load())
+	}
+}
+
+func TestClientDoesNotRetryPost(t *testing.T) {
+	Configure(testConfig())
+	srv, calls := countingServer(t, func(_ int32, w http.ResponseWriter) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	client := New("test", time.Second)
+	resp, err := client.Post(srv.URL, "application/json", strings.NewReader(`{}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if calls.Load() != 1 {
+		t.Fatalf("POST sent %d times, want 1", calls.Load())
+	}
+
+	req, _ := http.NewRequest(http.MethodPost, srv.URL, strings.NewReader(`{}`))
+	req.Header.Set("Idempotency-Key", "k-1")
+	resp, err = client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if calls.Load() != 4 {
+		t.Fatalf("POST with Idempotency-Key sent %d times, want 3", calls.Load()-1)
+	}
+}
+
+func TestClientOpensCircuitAfterConsecutiveFailures(t *testing.T) {
+	cfg := testConfig()
+	cfg.RetryMax = 0
+	cfg.BreakerThreshold = 2
+	cfg.BreakerCooldown = 50 * time.Millisecond
+	Configure(cfg)
+	healthy := atomic.Bool{}
+	srv, calls := countingServer(t, func(_ int32, w http.ResponseWriter) {
+		if healthy.Load() {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	client := New("test", time.Second)
+	for range 2 {
+		resp, err := client.Get(srv.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+	if _, err := client.Get(srv.URL); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen, got %v", err)
+	}
+	if calls.Load() != 2 {
+		t.Fatalf("open breaker let a request through: %d calls", calls.Load())
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	healthy.Store(true)
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("trial request after cooldown: %v", err)
+	}
+	resp.Body.Close()
+	if resp, err = client.Get(srv.URL); err != nil {
+		t.Fatalf("expected breaker closed after successful trial, got %v", err)
+	}
+	resp.Body.Close()
+}
+
+func TestClientAppliesHostTimeout(t *testing.T) {
+	srv, _ := countingServer(t, func(_ int32, w http.ResponseWriter) {
+		time.Sleep(200 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	})
+	u, _ := url.Parse(srv.URL)
+	cfg := testConfig()
+	cfg.RetryMax = 0
+	cfg.HostTimeouts = map[string]time.Duration{u.Hostname(): 20 * time.Millisecond}
+	Configure(cfg)
+
+	start := time.Now()
+	_, err := New("test", time.Minute).Get(srv.URL)
+	if err == nil {
+		t.Fatal("expected the host timeout to cut the request short")
+	}
+	if elapsed := time.Since(start); elapsed > 150*time.Millisecond {
+		t.Fatalf("request took %v despite a 20ms host timeout", elapsed)
+	}
+}
+
+func TestAvailableFollowsBreaker(t *testing.T) {
+	cfg := testConfig()
+	cfg.RetryMax = 0
+	cfg.BreakerThreshold = 1
+	cfg.BreakerCooldown = time.Hour
+	Configure(cfg)
+	srv, _ := countingServer(t, func(_ int32, w http.ResponseWriter) {
+		w.WriteHeader(http.StatusBadGateway)
+	})
+
+	if !Available("gateway") {
+		t.Fatal("expected an unused client to be available")
+	}
+	resp, err := New("gateway", time.Second).Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if Available("gateway") {
+		t.Fatal("expected the client to be unavailable once its breaker opened")
+	}
+	if !Available("other") {
+		t.Fatal("a breaker must only affect its own client")
+	}
+}
+
+func TestClientForwardsRequestID(t *testing.T) {
+	Configure(testConfig())
+	var got atomic.Value
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got.Store(r.Header.Get("X-Request-ID"))
+	}))
+	t.Cleanup(srv.Close)
+
+	ctx := context.WithValue(context.Background(), utils.RequestIDKey, "req-1")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := New("test", time.Second).Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if got.Load() != "req-1" {
+		t.Fatalf("X-Request-ID = %v, want req-1", got.Load())
+	}
+	if req.Header.Get("X-Request-ID") != "" {
+		t.Fatal("caller's request was modified")
+	}
+}
+
+func TestMockedClientAnswersInProcess(t *testing.T) {
+	cfg := testConfig()
+	cfg.RetryMax = 0
+	cfg.BreakerThreshold = 100
+	var calls atomic.Int32
+	cfg.Mocks = map[string]Mock{
+		"mocked": {
+			Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls.Add(1)
+				w.Write([]byte(r.URL.Path))
+			}),
+			FailureRate: 0.5,
+		},
+	}
+	Configure(cfg)
+	t.Cleanup(func() { Configure(testConfig()) })
+
+	client := New("mocked", time.Second)
+	var statuses []int
+	for range 4 {
+		resp, err := client.Post("https://provider.invalid/pay", "application/json", strings.NewReader(`{}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		statuses = append(statuses, resp.StatusCode)
+	}
+	want := []int{http.StatusOK, http.StatusServiceUnavailable, http.StatusOK, http.StatusServiceUnavailable}
+	for i := range want {
+		if statuses[i] != want[i] {
+			t.Fatalf("statuses = %v, want %v", statuses, want)
+		}
+	}
+	if calls.Load() != 2 {
+		t.Fatalf("handler called %d times, want 2", calls.Load())
+	}
+	if !Mocked("mocked") || Mocked("test") {
+		t.Fatal("only the configured client must be mocked")
+	}
+}
+
+func TestMockLatencyRespectsTimeout(t *testing.T) {
+	cfg := testConfig()
+	cfg.RetryMax = 0
+	cfg.Mocks = map[string]Mock{
+		"slow": {Handler: http.NotFoundHandler(), Latency: time.Second},
+	}
+	Configure(cfg)
+	t.Cleanup(func() { Configure(testConfig()) })
+
+	_, err := New("slow", 20*time.Millisecond).Get("https://provider.invalid/")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want deadline exceeded", err)
+	}
+}
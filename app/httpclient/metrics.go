@@ -0,0 +1,40 @@
+package httpclient
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	requestsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "outbound_http_requests_total",
+			Help: "Outbound HTTP attempts by client, host, method and outcome (ok, 4xx, 5xx, error, timeout, circuit_open)",
+		},
+		[]string{"client", "host", "method", "outcome"},
+	)
+	requestDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "outbound_http_request_duration_seconds",
+			Help:    "Duration of outbound HTTP attempts until response headers",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"client", "host"},
+	)
+	circuitOpen = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "outbound_http_circuit_open",
+			Help: "1 while the circuit breaker for a client and host is open",
+		},
+		[]string{"client", "host"},
+	)
+)
+
+func observe(client, host, method, outcome string, elapsed time.Duration) {
+	requestsTotal.WithLabelValues(client, host, method, outcome).Inc()
+	if outcome != "circuit_open" {
+		requestDuration.WithLabelValues(client, host).Observe(elapsed.Seconds())
+	}
+}
@@ -0,0 +1,75 @@
+package httpclient
+
+import (
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+)
+
+// Mock answers the requests of a client in-process instead of contacting the
+// provider, so local development runs without external dependencies. Mocked
+// requests still pass through retries, breakers and metrics.
+type Mock struct {
+	Handler http.Handler
+	// Latency delays every response
+	Latency time.Duration
+	// FailureRate is the share of requests, between 0 and 1, answered with
+	// 503. Failures are spread evenly, so a rate of 0.25 fails every fourth
+	// request and runs are repeatable.
+	FailureRate float64
+}
+
+// mockTransport serves one client's Mock
+type mockTransport struct {
+	mock  Mock
+	mu    sync.Mutex
+	calls int
+}
+
+// fail reports whether the next request is one of the failing share
+func (t *mockTransport) fail() bool {
+	rate := min(max(t.mock.FailureRate, 0), 1)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	n := float64(t.calls)
+	t.calls++
+	return math.Floor((n+1)*rate) > math.Floor(n*rate)
+}
+
+func (t *mockTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.mock.Latency > 0 {
+		timer := time.NewTimer(t.mock.Latency)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+	if err := req.Context().Err(); err != nil {
+		return nil, err
+	}
+
+	rec := httptest.NewRecorder()
+	if t.fail() {
+		http.Error(rec, "mock failure", http.StatusServiceUnavailable)
+	} else {
+		served := req.Clone(req.Context())
+		if served.Body == nil {
+			served.Body = http.NoBody
+		}
+		served.RequestURI = served.URL.RequestURI()
+		t.mock.Handler.ServeHTTP(rec, served)
+	}
+	resp := rec.Result()
+	resp.Request = req
+	return resp, nil
+}
+
+// Mocked reports whether requests of the named client are answered in-process
+func Mocked(name string) bool {
+	_, ok := current().mocks[name]
+	return ok
+}
@@ -0,0 +1,85 @@
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// Args are the named values a message template refers to, e.g. {{.Code}}
+type Args map[string]any
+
+//go:embed locales/*.json
+var localeFiles embed.FS
+
+// catalogs holds the parsed message templates of each locale by key. Keep
+// the locale files in sync: every key must exist in all of them.
+var catalogs = mustLoadCatalogs()
+
+func mustLoadCatalogs() map[Locale]map[string]*template.Template {
+	loaded := make(map[Locale]map[string]*template.Template)
+	for _, locale := range Locales() {
+		catalog, err := loadCatalog(locale)
+		if err != nil {
+			panic(err)
+		}
+		loaded[locale] = catalog
+	}
+	return loaded
+}
+
+func loadCatalog(locale Locale) (map[string]*template.Template, error) {
+	raw, err := localeFiles.ReadFile("locales/" + string(locale) + ".json")
+	if err != nil {
+		return nil, fmt.Errorf("read %s catalog: %w", locale, err)
+	}
+	var messages map[string]string
+	if err := json.Unmarshal(raw, &messages); err != nil {
+		return nil, fmt.Errorf("parse %s catalog: %w", locale, err)
+	}
+
+	catalog := make(map[string]*template.Template, len(messages))
+	for key, text := range messages {
+		tmpl, err := template.New(key).Option("missingkey=error").Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("%s catalog: %w", locale, err)
+		}
+		catalog[key] = tmpl
+	}
+	return catalog, nil
+}
+
+// Keys returns the message keys of a locale, sorted
+func Keys(locale Locale) []string {
+	keys := make([]string, 0, len(catalogs[locale]))
+	for key := range catalogs[locale] {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Message renders the message of a key in the locale, falling back to
+// DefaultLocale when the locale lacks the key and to the key itself when no
+// catalog has it
+func Message(locale Locale, key string, args Args) string {
+	tmpl, ok := catalogs[locale][key]
+	if !ok {
+		tmpl, ok = catalogs[DefaultLocale][key]
+	}
+	if !ok {
+		log.Printf("i18n: no message for key %s", key)
+		return key
+	}
+
+	var b strings.Builder
+	if err := tmpl.Execute(&b, args); err != nil {
+		log.Printf("i18n: render %s (%s): %v", key, locale, err)
+		return key
+	}
+	return b.String()
+}
@@ -0,0 +1,54 @@
+package i18n
+
+import (
+	"html/template"
+	"strings"
+)
+
+// Page is a parsed html/template page rendered per locale. The page resolves
+// strings with {{t "key"}} and can use {{.Lang}} and {{.Dir}} for the html
+// element. Values are escaped by html/template for their context.
+type Page struct {
+	tmpl *template.Template
+}
+
+// ParsePage parses a page; it is parsed once and rendered concurrently
+func ParsePage(name, text string) (*Page, error) {
+	tmpl, err := template.New(name).Funcs(pageFuncs(DefaultLocale)).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	return &Page{tmpl: tmpl}, nil
+}
+
+// Render renders the page in the locale; data is not modified
+func (p *Page) Render(locale Locale, data map[string]any) (string, error) {
+	// The parsed page is never executed itself, so it can be cloned to bind
+	// the locale's messages
+	tmpl, err := p.tmpl.Clone()
+	if err != nil {
+		return "", err
+	}
+	tmpl.Funcs(pageFuncs(locale))
+
+	pageData := make(map[string]any, len(data)+2)
+	for k, v := range data {
+		pageData[k] = v
+	}
+	pageData["Lang"] = string(locale)
+	pageData["Dir"] = locale.Dir()
+
+	var b strings.Builder
+	if err := tmpl.Execute(&b, pageData); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+func pageFuncs(locale Locale) template.FuncMap {
+	return template.FuncMap{
+		"t": func(key string) string {
+			return Message(locale, key, nil)
+		},
+	}
+}
@@ -0,0 +1,152 @@
+package i18n_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/i18n"
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+)
+
+// sampleArgs names every value message templates refer to
+var sampleArgs = i18n.Args{
+	"Code": "123456", "Minutes": 2, "Device": "Chrome on Windows", "Location": "IR", "Link": "https://example.com",
+	"Browser": "Chrome", "OS": "Windows", "Title": "Spring sale", "FirstName": "Sara", "LastName": "Ahmadi",
+	"Level3s": "a,b", "Platform": "sms", "Name": "default", "Customer": "Sara Ahmadi", "Company": "-",
+	"CustomerID": 7, "TicketID": 12, "Content": "Hello", "Status": "9", "State": "Unknown",
+	"Field": "Email", "Param": "8", "Min": 8, "Max": 100,
+	"Received": "9.5", "Expected": "10", "Coin": "USDT", "Credited": 950000, "Requested": 1000000,
+	"Comment": "Missing link", "Amount": 500000, "Balance": 40000, "Threshold": 100000, "Receiver": "09121234567",
+	"AgencyID": 3, "Reason": "sheba_invalid",
+}
+
+func TestCatalogsHaveSameKeys(t *testing.T) {
+	t.Parallel()
+
+	want := strings.Join(i18n.Keys(i18n.DefaultLocale), ",")
+	for _, locale := range i18n.Locales() {
+		if got := strings.Join(i18n.Keys(locale), ","); got != want {
+			t.Errorf("%s catalog keys differ from %s", locale, i18n.DefaultLocale)
+		}
+	}
+}
+
+func TestMessagesRender(t *testing.T) {
+	t.Parallel()
+
+	for _, locale := range i18n.Locales() {
+		for _, key := range i18n.Keys(locale) {
+			msg := i18n.Message(locale, key, sampleArgs)
+			if msg == key || msg == "" || strings.Contains(msg, "<no value>") {
+				t.Errorf("%s %s rendered as %q", locale, key, msg)
+			}
+		}
+	}
+	if got := i18n.Message(i18n.LocalePersian, "otp.signup_code", i18n.Args{"Code": "4711"}); got != "کد تأیید شما: 4711" {
+		t.Errorf("unexpected Persian OTP message %q", got)
+	}
+	if got := i18n.Message(i18n.LocaleEnglish, "no.such.key", nil); got != "no.such.key" {
+		t.Errorf("unknown keys must render as the key, got %q", got)
+	}
+}
+
+func TestParseLocale(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]i18n.Locale{"fa": i18n.LocalePersian, "FA": i18n.LocalePersian, "fa-IR": i18n.LocalePersian, "en_US": i18n.LocaleEnglish, " EN ": i18n.LocaleEnglish}
+	for input, want := range cases {
+		if got, ok := i18n.ParseLocale(input); !ok || got != want {
+			t.Errorf("ParseLocale(%q) = %q, %v", input, got, ok)
+		}
+	}
+	for _, input := range []string{"", "de", "farsi"} {
+		if _, ok := i18n.ParseLocale(input); ok {
+			t.Errorf("ParseLocale(%q) must fail", input)
+		}
+	}
+}
+
+func TestParseAcceptLanguage(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]i18n.Locale{
+		"fa":                      i18n.LocalePersian,
+		"fa-IR,fa;q=0.9,en;q=0.8": i18n.LocalePersian,
+		"en-US,en;q=0.9,fa;q=0.5": i18n.LocaleEnglish,
+		"de,fa;q=0.3":             i18n.LocalePersian,
+		"FA-ir":                   i18n.LocalePersian,
+		"fa;q=abc,en;q=0.1":       i18n.LocaleEnglish,
+	}
+	for header, want := range cases {
+		if got, ok := i18n.ParseAcceptLanguage(header); !ok || got != want {
+			t.Errorf("ParseAcceptLanguage(%q) = %q, %v, want %q", header, got, ok, want)
+		}
+	}
+	for _, header := range []string{"", "de-DE", "fa;q=0"} {
+		if got, ok := i18n.ParseAcceptLanguage(header); ok {
+			t.Errorf("ParseAcceptLanguage(%q) = %q, want no locale", header, got)
+		}
+	}
+}
+
+func TestLocalizer(t *testing.T) {
+	t.Parallel()
+
+	l := i18n.NewLocalizer("fa", "en")
+	if got := l.CustomerLocale(&models.Customer{}); got != i18n.LocalePersian {
+		t.Errorf("customer without preference got %q, want the default", got)
+	}
+	if got := l.CustomerLocale(&models.Customer{PreferredLocale: utils.ToPtr("en")}); got != i18n.LocaleEnglish {
+		t.Errorf("customer preferring en got %q", got)
+	}
+	if got := l.CustomerLocale(&models.Customer{PreferredLocale: utils.ToPtr("de")}); got != i18n.LocalePersian {
+		t.Errorf("unsupported preference got %q, want the default", got)
+	}
+	if got := l.AdminLocale(); got != i18n.LocaleEnglish {
+		t.Errorf("admin locale %q", got)
+	}
+
+	if got := i18n.NewLocalizer("xx", "").AdminLocale(); got != i18n.DefaultLocale {
+		t.Errorf("invalid locales must fall back to %q, got %q", i18n.DefaultLocale, got)
+	}
+	var none *i18n.Localizer
+	if got := none.Customer(nil, "campaign.approved", i18n.Args{"Title": "x"}); got != "Your campaign 'x' has been approved." {
+		t.Errorf("nil localizer rendered %q", got)
+	}
+}
+
+func TestPageRender(t *testing.T) {
+	t.Parallel()
+
+	page, err := i18n.ParsePage("page", `<html lang="{{.Lang}}" dir="{{.Dir}}"><a href="{{.Link}}">{{t "payment.return_to_wallet"}}</a> {{.Name}}</html>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := map[string]any{"Name": "<script>alert(1)</script>", "Link": "javascript:alert(1)"}
+
+	html, err := page.Render(i18n.LocalePersian, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`lang="fa" dir="rtl"`, i18n.Message(i18n.LocalePersian, "payment.return_to_wallet", nil), "&lt;script&gt;alert(1)&lt;/script&gt;", `href="#ZgotmplZ"`} {
+		if !strings.Contains(html, want) {
+			t.Errorf("page %q lacks %q", html, want)
+		}
+	}
+	if _, ok := data["Lang"]; ok {
+		t.Error("Render must not modify data")
+	}
+
+	html, err = page.Render(i18n.LocaleEnglish, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(html, `lang="en" dir="ltr"`) || !strings.Contains(html, "Return to Wallet") {
+		t.Errorf("English page rendered as %q", html)
+	}
+
+	if _, err := i18n.ParsePage("broken", "{{if}}"); err == nil {
+		t.Error("ParsePage must reject invalid templates")
+	}
+}
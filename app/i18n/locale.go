@@ -0,0 +1,86 @@
+// Package i18n resolves user-facing strings (SMS bodies, emails, HTML pages and
+// validation messages) by key from per-locale message catalogs
+package i18n
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// Locale is a language messages are available in
+type Locale string
+
+const (
+	LocaleEnglish Locale = "en"
+	LocalePersian Locale = "fa"
+)
+
+// DefaultLocale is used when nothing asks for a supported locale
+const DefaultLocale = LocaleEnglish
+
+// Locales lists the supported locales
+func Locales() []Locale {
+	return []Locale{LocaleEnglish, LocalePersian}
+}
+
+// ParseLocale accepts a locale in any case, with or without a region
+// (e.g. "FA", "fa-IR", "en_US")
+func ParseLocale(s string) (Locale, bool) {
+	primary := strings.ToLower(strings.TrimSpace(s))
+	if i := strings.IndexAny(primary, "-_"); i >= 0 {
+		primary = primary[:i]
+	}
+	for _, locale := range Locales() {
+		if Locale(primary) == locale {
+			return locale, true
+		}
+	}
+	return "", false
+}
+
+// Dir is the text direction of the locale, "rtl" or "ltr"
+func (l Locale) Dir() string {
+	if l == LocalePersian {
+		return "rtl"
+	}
+	return "ltr"
+}
+
+// ParseAcceptLanguage picks the supported locale the client prefers most,
+// honouring quality values (e.g. "fa-IR,fa;q=0.9,en;q=0.8"). It reports false
+// if the header names no supported locale.
+func ParseAcceptLanguage(header string) (Locale, bool) {
+	var best Locale
+	bestQuality := 0.0
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		quality := 1.0
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(q, 64)
+			if err != nil {
+				continue
+			}
+			quality = parsed
+		}
+
+		locale, ok := ParseLocale(tag)
+		if !ok {
+			continue
+		}
+		if quality > bestQuality {
+			best, bestQuality = locale, quality
+		}
+	}
+	return best, best != ""
+}
+
+// RequestLocale returns the locale negotiated from the request's
+// Accept-Language header, or DefaultLocale
+func RequestLocale(c fiber.Ctx) Locale {
+	if locale, ok := ParseAcceptLanguage(c.Get(fiber.HeaderAcceptLanguage)); ok {
+		return locale
+	}
+	return DefaultLocale
+}
@@ -0,0 +1,62 @@
+package i18n
+
+import "github.com/amirphl/Yamata-no-Orochi/models"
+
+// Localizer picks the locale of outgoing messages: a customer's preference
+// when set, the deployment default otherwise, and a separate locale for
+// notifications to admins. A nil Localizer uses DefaultLocale for both.
+type Localizer struct {
+	defaultLocale Locale
+	adminLocale   Locale
+}
+
+// NewLocalizer creates a Localizer; unsupported locales fall back to DefaultLocale
+func NewLocalizer(defaultLocale, adminLocale string) *Localizer {
+	l := &Localizer{defaultLocale: DefaultLocale, adminLocale: DefaultLocale}
+	if locale, ok := ParseLocale(defaultLocale); ok {
+		l.defaultLocale = locale
+	}
+	if locale, ok := ParseLocale(adminLocale); ok {
+		l.adminLocale = locale
+	}
+	return l
+}
+
+// Resolve returns the preferred locale if it is supported, else the default
+func (l *Localizer) Resolve(preferred *string) Locale {
+	if preferred != nil {
+		if locale, ok := ParseLocale(*preferred); ok {
+			return locale
+		}
+	}
+	if l == nil {
+		return DefaultLocale
+	}
+	return l.defaultLocale
+}
+
+// CustomerLocale returns the locale messages to the customer are sent in
+func (l *Localizer) CustomerLocale(customer *models.Customer) Locale {
+	if customer == nil {
+		return l.Resolve(nil)
+	}
+	return l.Resolve(customer.PreferredLocale)
+}
+
+// Customer renders a message for the customer
+func (l *Localizer) Customer(customer *models.Customer, key string, args Args) string {
+	return Message(l.CustomerLocale(customer), key, args)
+}
+
+// AdminLocale returns the locale notifications to admins are sent in
+func (l *Localizer) AdminLocale() Locale {
+	if l == nil {
+		return DefaultLocale
+	}
+	return l.adminLocale
+}
+
+// Admin renders a notification for admins
+func (l *Localizer) Admin(key string, args Args) string {
+	return Message(l.AdminLocale(), key, args)
+}
@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const apiVersionLocal = "api_version"
+
+// Requests served by a deprecated API version, to tell when it can be retired
+var deprecatedRequestsTotal = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "http_deprecated_api_requests_total",
+		Help: "Requests served by a deprecated API version",
+	},
+	[]string{"version"},
+)
+
+// APIVersion marks requests with the version of the group they came in
+// through, so handlers shared between versions can tell them apart
+func APIVersion(version string) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		c.Locals(apiVersionLocal, version)
+		return c.Next()
+	}
+}
+
+// RequestAPIVersion returns the version set by APIVersion, or "" outside a
+// versioned group
+func RequestAPIVersion(c fiber.Ctx) string {
+	version, _ := c.Locals(apiVersionLocal).(string)
+	return version
+}
+
+// Deprecation announces that version is deprecated from deprecatedAt: the
+// Deprecation header (RFC 9745), the Sunset header (RFC 8594) when sunsetAt is
+// set, and a successor-version link. It passes requests through untouched
+// while deprecatedAt is zero.
+func Deprecation(version string, deprecatedAt, sunsetAt time.Time, successor string) fiber.Handler {
+	if deprecatedAt.IsZero() {
+		return func(c fiber.Ctx) error { return c.Next() }
+	}
+	deprecation := fmt.Sprintf("@%d", deprecatedAt.Unix())
+	sunset := ""
+	if !sunsetAt.IsZero() {
+		sunset = sunsetAt.UTC().Format(http.TimeFormat)
+	}
+	link := fmt.Sprintf(`<%s>; rel="successor-version"`, successor)
+
+	return func(c fiber.Ctx) error {
+		c.Set("Deprecation", deprecation)
+		if sunset != "" {
+			c.Set("Sunset", sunset)
+		}
+		c.Set(fiber.HeaderLink, link)
+		deprecatedRequestsTotal.WithLabelValues(version).Inc()
+		return c.Next()
+	}
+}
@@ -0,0 +1,83 @@
+package middleware_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/middleware"
+	"github.com/gofiber/fiber/v3"
+)
+
+func newVersionTestApp(deprecatedAt, sunsetAt time.Time) *fiber.App {
+	app := fiber.New()
+	v1 := app.Group("/api/v1", middleware.APIVersion("v1"), middleware.Deprecation("v1", deprecatedAt, sunsetAt, "/api/v2"))
+	v2 := app.Group("/api/v2", middleware.APIVersion("v2"))
+	version := func(c fiber.Ctx) error { return c.SendString(middleware.RequestAPIVersion(c)) }
+	v1.Get("/ping", version)
+	v2.Get("/ping", version)
+	return app
+}
+
+func TestDeprecationHeaders(t *testing.T) {
+	deprecatedAt := time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)
+	sunsetAt := time.Date(2027, 7, 1, 0, 0, 0, 0, time.UTC)
+	app := newVersionTestApp(deprecatedAt, sunsetAt)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/api/v1/ping", nil))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := map[string]string{
+		"Deprecation": "@1798761600",
+		"Sunset":      "Thu, 01 Jul 2027 00:00:00 GMT",
+		"Link":        `</api/v2>; rel="successor-version"`,
+	}
+	for header, value := range want {
+		if got := resp.Header.Get(header); got != value {
+			t.Errorf("%s = %q, want %q", header, got, value)
+		}
+	}
+
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/api/v2/ping", nil))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := resp.Header.Get("Deprecation"); got != "" {
+		t.Errorf("v2 Deprecation = %q, want none", got)
+	}
+}
+
+func TestDeprecationDisabledWithoutDate(t *testing.T) {
+	app := newVersionTestApp(time.Time{}, time.Time{})
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/api/v1/ping", nil))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, header := range []string{"Deprecation", "Sunset", "Link"} {
+		if got := resp.Header.Get(header); got != "" {
+			t.Errorf("%s = %q, want none", header, got)
+		}
+	}
+}
+
+func TestRequestAPIVersion(t *testing.T) {
+	app := newVersionTestApp(time.Time{}, time.Time{})
+
+	for path, want := range map[string]string{"/api/v1/ping": "v1", "/api/v2/ping": "v2"} {
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, path, nil))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatalf("read body: %v", err)
+		}
+		if got := string(body); got != want {
+			t.Errorf("%s version = %q, want %q", path, got, want)
+		}
+	}
+}
@@ -2,23 +2,44 @@
 package middleware
 
 import (
+	"context"
 	"errors"
+	"log"
+	"strconv"
 	"strings"
+	"time"
 
-	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/app/apierror"
 	"github.com/amirphl/Yamata-no-Orochi/app/services"
+	"github.com/amirphl/Yamata-no-Orochi/repository"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
 	"github.com/gofiber/fiber/v3"
 )
 
-// AuthMiddleware handles JWT token validation for protected endpoints
+// sessionAccessInterval is how stale the last access time of a customer
+// session may get before a request records it again
+const sessionAccessInterval = 5 * time.Minute
+
+// AuthMiddleware handles JWT token validation for protected endpoints. Customer
+// tokens are also checked against their session so a logout or revocation
+// takes effect immediately; without a session repository only the JWT is
+// checked.
 type AuthMiddleware struct {
 	tokenService services.TokenService
+	sessionStore services.SessionStore
+	sessionRepo  repository.CustomerSessionRepository
 }
 
 // NewAuthMiddleware creates a new authentication middleware
-func NewAuthMiddleware(tokenService services.TokenService) *AuthMiddleware {
+func NewAuthMiddleware(
+	tokenService services.TokenService,
+	sessionStore services.SessionStore,
+	sessionRepo repository.CustomerSessionRepository,
+) *AuthMiddleware {
 	return &AuthMiddleware{
 		tokenService: tokenService,
+		sessionStore: sessionStore,
+		sessionRepo:  sessionRepo,
 	}
 }
 
@@ -28,36 +49,18 @@ func (m *AuthMiddleware) Authenticate() fiber.Handler {
 		// Get the Authorization header
 		authHeader := c.Get("Authorization")
 		if authHeader == "" {
-			return c.Status(fiber.StatusUnauthorized).JSON(dto.APIResponse{
-				Success: false,
-				Message: "Authorization header is required",
-				Error: dto.ErrorDetail{
-					Code: "MISSING_AUTHORIZATION_HEADER",
-				},
-			})
+			return apierror.Respond(c, fiber.StatusUnauthorized, "Authorization header is required", "MISSING_AUTHORIZATION_HEADER", nil)
 		}
 
 		// Check if the header starts with "Bearer "
 		if !strings.HasPrefix(authHeader, "Bearer ") {
-			return c.Status(fiber.StatusUnauthorized).JSON(dto.APIResponse{
-				Success: false,
-				Message: "Invalid authorization header format. Expected 'Bearer <token>'",
-				Error: dto.ErrorDetail{
-					Code: "INVALID_AUTHORIZATION_FORMAT",
-				},
-			})
+			return apierror.Respond(c, fiber.StatusUnauthorized, "Invalid authorization header format. Expected 'Bearer <token>'", "INVALID_AUTHORIZATION_FORMAT", nil)
 		}
 
 		// Extract the token (remove "Bearer " prefix)
 		token := strings.TrimPrefix(authHeader, "Bearer ")
 		if token == "" {
-			return c.Status(fiber.StatusUnauthorized).JSON(dto.APIResponse{
-				Success: false,
-				Message: "Access token is required",
-				Error: dto.ErrorDetail{
-					Code: "MISSING_ACCESS_TOKEN",
-				},
-			})
+			return apierror.Respond(c, fiber.StatusUnauthorized, "Access token is required", "MISSING_ACCESS_TOKEN", nil)
 		}
 
 		// Validate the token (this already checks for revocation)
@@ -81,19 +84,25 @@ func (m *AuthMiddleware) Authenticate() fiber.Handler {
 				message = "Token validation failed"
 			}
 
-			return c.Status(fiber.StatusUnauthorized).JSON(dto.APIResponse{
-				Success: false,
-				Message: message,
-				Error: dto.ErrorDetail{
-					Code: errorCode,
-				},
-			})
+			return apierror.Respond(c, fiber.StatusUnauthorized, message, errorCode, nil)
+		}
+
+		active, err := m.sessionActive(c.Context(), claims.CustomerID, token)
+		if err != nil {
+			log.Printf("session check failed for customer %d: %v", claims.CustomerID, err)
+			return apierror.Respond(c, fiber.StatusInternalServerError, "Session check failed", "SESSION_CHECK_FAILED", nil)
+		}
+		if !active {
+			return apierror.Respond(c, fiber.StatusUnauthorized, "Session has been revoked", "TOKEN_REVOKED", nil)
 		}
+		m.recordSessionAccess(c.Context(), token)
 
 		// Store user information in context for downstream handlers
 		c.Locals("customer_id", claims.CustomerID)
 		c.Locals("token_id", claims.TokenID)
 		c.Locals("token_claims", claims)
+		c.Locals("access_token", token)
+		markImpersonation(c, claims)
 
 		// Store RequestID for audit logging
 		if requestID := c.Get("X-Request-ID"); requestID != "" {
@@ -111,30 +120,18 @@ func (m *AuthMiddleware) AdminAuthenticate() fiber.Handler {
 		// Get the Authorization header
 		authHeader := c.Get("Authorization")
 		if authHeader == "" {
-			return c.Status(fiber.StatusUnauthorized).JSON(dto.APIResponse{
-				Success: false,
-				Message: "Authorization header is required",
-				Error:   dto.ErrorDetail{Code: "MISSING_AUTHORIZATION_HEADER"},
-			})
+			return apierror.Respond(c, fiber.StatusUnauthorized, "Authorization header is required", "MISSING_AUTHORIZATION_HEADER", nil)
 		}
 
 		// Check Bearer format
 		if !strings.HasPrefix(authHeader, "Bearer ") {
-			return c.Status(fiber.StatusUnauthorized).JSON(dto.APIResponse{
-				Success: false,
-				Message: "Invalid authorization header format. Expected 'Bearer <token>'",
-				Error:   dto.ErrorDetail{Code: "INVALID_AUTHORIZATION_FORMAT"},
-			})
+			return apierror.Respond(c, fiber.StatusUnauthorized, "Invalid authorization header format. Expected 'Bearer <token>'", "INVALID_AUTHORIZATION_FORMAT", nil)
 		}
 
 		// Extract token
 		token := strings.TrimPrefix(authHeader, "Bearer ")
 		if token == "" {
-			return c.Status(fiber.StatusUnauthorized).JSON(dto.APIResponse{
-				Success: false,
-				Message: "Access token is required",
-				Error:   dto.ErrorDetail{Code: "MISSING_ACCESS_TOKEN"},
-			})
+			return apierror.Respond(c, fiber.StatusUnauthorized, "Access token is required", "MISSING_ACCESS_TOKEN", nil)
 		}
 
 		// Validate token (admin)
@@ -154,7 +151,7 @@ func (m *AuthMiddleware) AdminAuthenticate() fiber.Handler {
 				code = "TOKEN_VALIDATION_FAILED"
 				msg = "Token validation failed"
 			}
-			return c.Status(fiber.StatusUnauthorized).JSON(dto.APIResponse{Success: false, Message: msg, Error: dto.ErrorDetail{Code: code}})
+			return apierror.Respond(c, fiber.StatusUnauthorized, msg, code, nil)
 		}
 
 		// For admin tokens, use admin-specific claims
@@ -175,14 +172,14 @@ func (m *AuthMiddleware) BotAuthenticate() fiber.Handler {
 	return func(c fiber.Ctx) error {
 		authHeader := c.Get("Authorization")
 		if authHeader == "" {
-			return c.Status(fiber.StatusUnauthorized).JSON(dto.APIResponse{Success: false, Message: "Authorization header is required", Error: dto.ErrorDetail{Code: "MISSING_AUTHORIZATION_HEADER"}})
+			return apierror.Respond(c, fiber.StatusUnauthorized, "Authorization header is required", "MISSING_AUTHORIZATION_HEADER", nil)
 		}
 		if !strings.HasPrefix(authHeader, "Bearer ") {
-			return c.Status(fiber.StatusUnauthorized).JSON(dto.APIResponse{Success: false, Message: "Invalid authorization header format. Expected 'Bearer <token>'", Error: dto.ErrorDetail{Code: "INVALID_AUTHORIZATION_FORMAT"}})
+			return apierror.Respond(c, fiber.StatusUnauthorized, "Invalid authorization header format. Expected 'Bearer <token>'", "INVALID_AUTHORIZATION_FORMAT", nil)
 		}
 		token := strings.TrimPrefix(authHeader, "Bearer ")
 		if token == "" {
-			return c.Status(fiber.StatusUnauthorized).JSON(dto.APIResponse{Success: false, Message: "Access token is required", Error: dto.ErrorDetail{Code: "MISSING_ACCESS_TOKEN"}})
+			return apierror.Respond(c, fiber.StatusUnauthorized, "Access token is required", "MISSING_ACCESS_TOKEN", nil)
 		}
 
 		botClaims, err := m.tokenService.ValidateBotToken(token)
@@ -201,7 +198,7 @@ func (m *AuthMiddleware) BotAuthenticate() fiber.Handler {
 				code = "TOKEN_VALIDATION_FAILED"
 				msg = "Token validation failed"
 			}
-			return c.Status(fiber.StatusUnauthorized).JSON(dto.APIResponse{Success: false, Message: msg, Error: dto.ErrorDetail{Code: code}})
+			return apierror.Respond(c, fiber.StatusUnauthorized, msg, code, nil)
 		}
 
 		c.Locals("bot_id", botClaims.BotID)
@@ -243,11 +240,17 @@ func (m *AuthMiddleware) OptionalAuth() fiber.Handler {
 			// Token is invalid, but this is optional auth, so continue
 			return c.Next()
 		}
+		if active, err := m.sessionActive(c.Context(), claims.CustomerID, token); err != nil || !active {
+			return c.Next()
+		}
+		m.recordSessionAccess(c.Context(), token)
 
 		// Store user information in context for downstream handlers
 		c.Locals("customer_id", claims.CustomerID)
 		c.Locals("token_id", claims.TokenID)
 		c.Locals("token_claims", claims)
+		c.Locals("access_token", token)
+		markImpersonation(c, claims)
 
 		// Store RequestID for audit logging
 		if requestID := c.Get("X-Request-ID"); requestID != "" {
@@ -259,12 +262,115 @@ func (m *AuthMiddleware) OptionalAuth() fiber.Handler {
 	}
 }
 
+// sessionActive reports whether the session of a customer access token is
+// still active. Tokens the session store does not know, or checked while it
+// is unavailable, are looked up in customer_sessions and mirrored again.
+func (m *AuthMiddleware) sessionActive(ctx context.Context, customerID uint, token string) (bool, error) {
+	if m.sessionRepo == nil {
+		return true, nil
+	}
+
+	storeAvailable := m.sessionStore != nil
+	if storeAvailable {
+		state, err := m.sessionStore.State(ctx, customerID, token)
+		switch {
+		case err != nil:
+			storeAvailable = false
+			log.Printf("session store unavailable, checking database: %v", err)
+		case state == services.SessionActive:
+			return true, nil
+		case state == services.SessionRevoked:
+			return false, nil
+		}
+	}
+
+	session, err := m.sessionRepo.BySessionToken(ctx, token)
+	if err != nil {
+		return false, err
+	}
+	if session == nil || session.CustomerID != customerID {
+		return false, nil
+	}
+	if storeAvailable {
+		if err := m.sessionStore.Activate(ctx, customerID, token, session.ExpiresAt); err != nil {
+			log.Printf("mirror session of customer %d: %v", customerID, err)
+		}
+	}
+	return true, nil
+}
+
+// recordSessionAccess updates the last access time of a session shown in the
+// customer's device list. Failures are only logged.
+func (m *AuthMiddleware) recordSessionAccess(ctx context.Context, token string) {
+	if m.sessionRepo == nil {
+		return
+	}
+	if m.sessionStore != nil {
+		due, err := m.sessionStore.MarkAccessed(ctx, token, sessionAccessInterval)
+		if err != nil || !due {
+			return
+		}
+	}
+	if err := m.sessionRepo.TouchLastAccessed(ctx, token, sessionAccessInterval); err != nil {
+		log.Printf("record session access: %v", err)
+	}
+}
+
+// markImpersonation records the admin of an impersonation token and marks
+// the response so clients can show that an admin is acting as the customer
+func markImpersonation(c fiber.Ctx, claims *services.TokenClaims) {
+	if claims.ImpersonatedBy == 0 {
+		return
+	}
+	c.Locals("impersonated_by", claims.ImpersonatedBy)
+	c.Set("X-Impersonated-By", strconv.FormatUint(uint64(claims.ImpersonatedBy), 10))
+}
+
+// GetImpersonationFromContext returns the admin and customer of a request
+// made with an impersonation token
+func GetImpersonationFromContext(c fiber.Ctx) (utils.Impersonation, bool) {
+	adminID, ok := c.Locals("impersonated_by").(uint)
+	if !ok || adminID == 0 {
+		return utils.Impersonation{}, false
+	}
+	customerID, _ := GetCustomerIDFromContext(c)
+	return utils.Impersonation{AdminID: adminID, CustomerID: customerID}, true
+}
+
+// DenyImpersonation refuses requests made with an impersonation token. Mount
+// it on routes that move money or change the customer's credentials, which
+// support staff must not do on the customer's behalf. Must run after
+// Authenticate.
+func DenyImpersonation() fiber.Handler {
+	return func(c fiber.Ctx) error {
+		if _, ok := GetImpersonationFromContext(c); ok {
+			return apierror.Respond(c, fiber.StatusForbidden, "Not allowed while impersonating a customer", "IMPERSONATION_NOT_ALLOWED", nil)
+		}
+		return c.Next()
+	}
+}
+
+// WithImpersonation adds the impersonation of the request, if any, to ctx so
+// audit logs written under it name both the admin and the customer
+func WithImpersonation(ctx context.Context, c fiber.Ctx) context.Context {
+	if impersonation, ok := GetImpersonationFromContext(c); ok {
+		return context.WithValue(ctx, utils.ImpersonationKey, impersonation)
+	}
+	return ctx
+}
+
 // GetCustomerIDFromContext extracts customer ID from the request context
 func GetCustomerIDFromContext(c fiber.Ctx) (uint, bool) {
 	customerID, ok := c.Locals("customer_id").(uint)
 	return customerID, ok
 }
 
+// GetAccessTokenFromContext extracts the customer access token from the request context
+func GetAccessTokenFromContext(c fiber.Ctx) (string, bool) {
+	token, ok := c.Locals("access_token").(string)
+	return token, ok
+}
+
 // GetAdminIDFromContext extracts admin ID from the request context
 func GetAdminIDFromContext(c fiber.Ctx) (uint, bool) {
 	adminID, ok := c.Locals("admin_id").(uint)
@@ -287,24 +393,12 @@ func GetTokenClaimsFromContext(c fiber.Ctx) (*services.TokenClaims, bool) {
 func RequireAuth(c fiber.Ctx) error {
 	customerID, exists := GetCustomerIDFromContext(c)
 	if !exists {
-		return c.Status(fiber.StatusUnauthorized).JSON(dto.APIResponse{
-			Success: false,
-			Message: "Authentication required",
-			Error: dto.ErrorDetail{
-				Code: "AUTHENTICATION_REQUIRED",
-			},
-		})
+		return apierror.Respond(c, fiber.StatusUnauthorized, "Authentication required", "AUTHENTICATION_REQUIRED", nil)
 	}
 
 	// Check if customer ID is valid
 	if customerID == 0 {
-		return c.Status(fiber.StatusUnauthorized).JSON(dto.APIResponse{
-			Success: false,
-			Message: "Invalid customer ID",
-			Error: dto.ErrorDetail{
-				Code: "INVALID_CUSTOMER_ID",
-			},
-		})
+		return apierror.Respond(c, fiber.StatusBadRequest, "Invalid customer ID", "INVALID_CUSTOMER_ID", nil)
 	}
 
 	return nil
@@ -314,18 +408,10 @@ func RequireAuth(c fiber.Ctx) error {
 func RequireAdminAuth(c fiber.Ctx) error {
 	adminID, exists := GetAdminIDFromContext(c)
 	if !exists {
-		return c.Status(fiber.StatusUnauthorized).JSON(dto.APIResponse{
-			Success: false,
-			Message: "Admin authentication required",
-			Error:   dto.ErrorDetail{Code: "ADMIN_AUTHENTICATION_REQUIRED"},
-		})
+		return apierror.Respond(c, fiber.StatusUnauthorized, "Admin authentication required", "ADMIN_AUTHENTICATION_REQUIRED", nil)
 	}
 	if adminID == 0 {
-		return c.Status(fiber.StatusUnauthorized).JSON(dto.APIResponse{
-			Success: false,
-			Message: "Invalid admin ID",
-			Error:   dto.ErrorDetail{Code: "INVALID_ADMIN_ID"},
-		})
+		return apierror.Respond(c, fiber.StatusUnauthorized, "Invalid admin ID", "INVALID_ADMIN_ID", nil)
 	}
 	return c.Next()
 }
@@ -334,18 +420,10 @@ func RequireAdminAuth(c fiber.Ctx) error {
 func RequireBotAuth(c fiber.Ctx) error {
 	botID, exists := GetBotIDFromContext(c)
 	if !exists {
-		return c.Status(fiber.StatusUnauthorized).JSON(dto.APIResponse{
-			Success: false,
-			Message: "Bot authentication required",
-			Error:   dto.ErrorDetail{Code: "BOT_AUTHENTICATION_REQUIRED"},
-		})
+		return apierror.Respond(c, fiber.StatusUnauthorized, "Bot authentication required", "BOT_AUTHENTICATION_REQUIRED", nil)
 	}
 	if botID == 0 {
-		return c.Status(fiber.StatusUnauthorized).JSON(dto.APIResponse{
-			Success: false,
-			Message: "Invalid bot ID",
-			Error:   dto.ErrorDetail{Code: "INVALID_BOT_ID"},
-		})
+		return apierror.Respond(c, fiber.StatusUnauthorized, "Invalid bot ID", "INVALID_BOT_ID", nil)
 	}
 	return c.Next()
 }
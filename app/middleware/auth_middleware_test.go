@@ -1,15 +1,22 @@
 package middleware_test
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/amirphl/Yamata-no-Orochi/app/middleware"
 	"github.com/amirphl/Yamata-no-Orochi/app/services"
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/repository"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
 	"github.com/gofiber/fiber/v3"
 )
 
@@ -38,6 +45,9 @@ func (s *stubTokenService) GetTokenClaims(token string) (*services.TokenClaims,
 	return nil, nil
 }
 func (s *stubTokenService) IsTokenRevoked(token string) bool { return false }
+func (s *stubTokenService) GenerateImpersonationToken(customerID, adminID uint, ttl time.Duration) (string, error) {
+	return "", nil
+}
 func (s *stubTokenService) GenerateAdminTokens(adminID uint) (string, string, error) {
 	return "", "", nil
 }
@@ -89,7 +99,7 @@ func decodeBody(t *testing.T, r io.Reader) map[string]any {
 
 func TestAuthenticateMissingHeader(t *testing.T) {
 	t.Parallel()
-	mw := middleware.NewAuthMiddleware(&stubTokenService{})
+	mw := middleware.NewAuthMiddleware(&stubTokenService{}, nil, nil)
 	app := newTestApp(mw.Authenticate())
 
 	resp, err := doRequest(app, "")
@@ -111,7 +121,7 @@ func TestAuthenticateMissingHeader(t *testing.T) {
 
 func TestAuthenticateInvalidBearerFormat(t *testing.T) {
 	t.Parallel()
-	mw := middleware.NewAuthMiddleware(&stubTokenService{})
+	mw := middleware.NewAuthMiddleware(&stubTokenService{}, nil, nil)
 	app := newTestApp(mw.Authenticate())
 
 	resp, err := doRequest(app, "Token abc")
@@ -135,7 +145,7 @@ func TestAuthenticateInvalidToken(t *testing.T) {
 			return nil, services.ErrTokenInvalid
 		},
 	}
-	mw := middleware.NewAuthMiddleware(stub)
+	mw := middleware.NewAuthMiddleware(stub, nil, nil)
 	app := newTestApp(mw.Authenticate())
 
 	resp, err := doRequest(app, "Bearer invalid.token.value")
@@ -159,7 +169,7 @@ func TestAuthenticateExpiredToken(t *testing.T) {
 			return nil, services.ErrTokenExpired
 		},
 	}
-	mw := middleware.NewAuthMiddleware(stub)
+	mw := middleware.NewAuthMiddleware(stub, nil, nil)
 	app := newTestApp(mw.Authenticate())
 
 	resp, err := doRequest(app, "Bearer expired.token.here")
@@ -183,7 +193,7 @@ func TestAuthenticateRevokedToken(t *testing.T) {
 			return nil, services.ErrTokenRevoked
 		},
 	}
-	mw := middleware.NewAuthMiddleware(stub)
+	mw := middleware.NewAuthMiddleware(stub, nil, nil)
 	app := newTestApp(mw.Authenticate())
 
 	resp, err := doRequest(app, "Bearer revoked.token.here")
@@ -208,7 +218,7 @@ func TestAuthenticateValidToken(t *testing.T) {
 			return &services.TokenClaims{CustomerID: wantCustomerID, TokenType: "access"}, nil
 		},
 	}
-	mw := middleware.NewAuthMiddleware(stub)
+	mw := middleware.NewAuthMiddleware(stub, nil, nil)
 
 	var capturedID any
 	app := fiber.New(fiber.Config{})
@@ -229,6 +239,38 @@ func TestAuthenticateValidToken(t *testing.T) {
 	}
 }
 
+func TestAuthenticateImpersonationToken(t *testing.T) {
+	t.Parallel()
+	stub := &stubTokenService{
+		validateFn: func(_ string) (*services.TokenClaims, error) {
+			return &services.TokenClaims{CustomerID: 42, TokenType: services.TokenTypeImpersonation, ImpersonatedBy: 7}, nil
+		},
+	}
+	mw := middleware.NewAuthMiddleware(stub, nil, nil)
+
+	var impersonation any
+	app := fiber.New(fiber.Config{})
+	app.Get("/test", mw.Authenticate(), func(c fiber.Ctx) error {
+		ctx := middleware.WithImpersonation(context.Background(), c)
+		impersonation = ctx.Value(utils.ImpersonationKey)
+		return c.SendString("ok")
+	})
+
+	resp, err := doRequest(app, "Bearer impersonation.token.here")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	if got := resp.Header.Get("X-Impersonated-By"); got != "7" {
+		t.Fatalf("expected X-Impersonated-By=7, got %q", got)
+	}
+	if impersonation != (utils.Impersonation{AdminID: 7, CustomerID: 42}) {
+		t.Fatalf("expected impersonation of customer 42 by admin 7, got %v", impersonation)
+	}
+}
+
 func TestAuthenticateGenericTokenError(t *testing.T) {
 	t.Parallel()
 	stub := &stubTokenService{
@@ -236,7 +278,7 @@ func TestAuthenticateGenericTokenError(t *testing.T) {
 			return nil, errors.New("unexpected validation error")
 		},
 	}
-	mw := middleware.NewAuthMiddleware(stub)
+	mw := middleware.NewAuthMiddleware(stub, nil, nil)
 	app := newTestApp(mw.Authenticate())
 
 	resp, err := doRequest(app, "Bearer bad.token")
@@ -259,7 +301,7 @@ func TestAuthenticateGenericTokenError(t *testing.T) {
 
 func TestAdminAuthenticateMissingHeader(t *testing.T) {
 	t.Parallel()
-	mw := middleware.NewAuthMiddleware(&stubTokenService{})
+	mw := middleware.NewAuthMiddleware(&stubTokenService{}, nil, nil)
 	app := newTestApp(mw.AdminAuthenticate())
 
 	resp, err := doRequest(app, "")
@@ -279,7 +321,7 @@ func TestAdminAuthenticateValidToken(t *testing.T) {
 			return &services.AdminTokenClaims{AdminID: wantAdminID, TokenType: "access"}, nil
 		},
 	}
-	mw := middleware.NewAuthMiddleware(stub)
+	mw := middleware.NewAuthMiddleware(stub, nil, nil)
 
 	var capturedID any
 	app := fiber.New(fiber.Config{})
@@ -307,7 +349,7 @@ func TestAdminAuthenticateExpiredToken(t *testing.T) {
 			return nil, services.ErrTokenExpired
 		},
 	}
-	mw := middleware.NewAuthMiddleware(stub)
+	mw := middleware.NewAuthMiddleware(stub, nil, nil)
 	app := newTestApp(mw.AdminAuthenticate())
 
 	resp, err := doRequest(app, "Bearer expired")
@@ -330,7 +372,7 @@ func TestAdminAuthenticateExpiredToken(t *testing.T) {
 
 func TestBotAuthenticateMissingHeader(t *testing.T) {
 	t.Parallel()
-	mw := middleware.NewAuthMiddleware(&stubTokenService{})
+	mw := middleware.NewAuthMiddleware(&stubTokenService{}, nil, nil)
 	app := newTestApp(mw.BotAuthenticate())
 
 	resp, err := doRequest(app, "")
@@ -350,7 +392,7 @@ func TestBotAuthenticateValidToken(t *testing.T) {
 			return &services.BotTokenClaims{BotID: wantBotID, TokenType: "access"}, nil
 		},
 	}
-	mw := middleware.NewAuthMiddleware(stub)
+	mw := middleware.NewAuthMiddleware(stub, nil, nil)
 
 	var capturedID any
 	app := fiber.New(fiber.Config{})
@@ -377,7 +419,7 @@ func TestBotAuthenticateValidToken(t *testing.T) {
 
 func TestOptionalAuthNoHeader(t *testing.T) {
 	t.Parallel()
-	mw := middleware.NewAuthMiddleware(&stubTokenService{})
+	mw := middleware.NewAuthMiddleware(&stubTokenService{}, nil, nil)
 
 	var capturedID any
 	app := fiber.New(fiber.Config{})
@@ -405,7 +447,7 @@ func TestOptionalAuthInvalidTokenContinues(t *testing.T) {
 			return nil, services.ErrTokenInvalid
 		},
 	}
-	mw := middleware.NewAuthMiddleware(stub)
+	mw := middleware.NewAuthMiddleware(stub, nil, nil)
 	app := newTestApp(mw.OptionalAuth())
 
 	resp, err := doRequest(app, "Bearer bad.token")
@@ -425,7 +467,7 @@ func TestOptionalAuthValidTokenSetsContext(t *testing.T) {
 			return &services.TokenClaims{CustomerID: wantCustomerID, TokenType: "access"}, nil
 		},
 	}
-	mw := middleware.NewAuthMiddleware(stub)
+	mw := middleware.NewAuthMiddleware(stub, nil, nil)
 
 	var capturedID any
 	app := fiber.New(fiber.Config{})
@@ -448,7 +490,7 @@ func TestOptionalAuthValidTokenSetsContext(t *testing.T) {
 
 func TestOptionalAuthInvalidBearerFormatContinues(t *testing.T) {
 	t.Parallel()
-	mw := middleware.NewAuthMiddleware(&stubTokenService{})
+	mw := middleware.NewAuthMiddleware(&stubTokenService{}, nil, nil)
 	app := newTestApp(mw.OptionalAuth())
 
 	resp, err := doRequest(app, "Token not-bearer")
@@ -459,3 +501,235 @@ func TestOptionalAuthInvalidBearerFormatContinues(t *testing.T) {
 		t.Fatalf("optional auth should continue on non-Bearer scheme, got %d", resp.StatusCode)
 	}
 }
+
+// stubSessionStore reports a fixed state for every token and records mirrors.
+type stubSessionStore struct {
+	state    services.SessionState
+	stateErr error
+	// accessDue is what MarkAccessed reports
+	accessDue bool
+
+	mu        sync.Mutex
+	activated []string
+}
+
+func (s *stubSessionStore) Activate(_ context.Context, _ uint, accessToken string, _ time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.activated = append(s.activated, accessToken)
+	return nil
+}
+func (s *stubSessionStore) Revoke(context.Context, string, time.Time) error { return nil }
+func (s *stubSessionStore) RevokeAll(context.Context, uint) error           { return nil }
+func (s *stubSessionStore) State(context.Context, uint, string) (services.SessionState, error) {
+	return s.state, s.stateErr
+}
+func (s *stubSessionStore) MarkAccessed(context.Context, string, time.Duration) (bool, error) {
+	return s.accessDue, s.stateErr
+}
+
+// stubSessionRepo serves the active sessions of the customer_sessions table by token.
+type stubSessionRepo struct {
+	repository.CustomerSessionRepository
+	sessions map[string]*models.CustomerSession
+	lookups  int
+	touched  []string
+}
+
+func (r *stubSessionRepo) BySessionToken(_ context.Context, token string) (*models.CustomerSession, error) {
+	r.lookups++
+	return r.sessions[token], nil
+}
+
+func (r *stubSessionRepo) TouchLastAccessed(_ context.Context, token string, _ time.Duration) error {
+	r.touched = append(r.touched, token)
+	return nil
+}
+
+func newSessionTestApp(store services.SessionStore, repo repository.CustomerSessionRepository) *fiber.App {
+	stub := &stubTokenService{
+		validateFn: func(_ string) (*services.TokenClaims, error) {
+			return &services.TokenClaims{CustomerID: 42, TokenType: "access"}, nil
+		},
+	}
+	return newTestApp(middleware.NewAuthMiddleware(stub, store, repo).Authenticate())
+}
+
+func TestAuthenticateRevokedSession(t *testing.T) {
+	t.Parallel()
+	repo := &stubSessionRepo{sessions: map[string]*models.CustomerSession{
+		"session.token": {CustomerID: 42, ExpiresAt: time.Now().Add(time.Hour)},
+	}}
+	app := newSessionTestApp(&stubSessionStore{state: services.SessionRevoked}, repo)
+
+	resp, err := doRequest(app, "Bearer session.token")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", resp.StatusCode)
+	}
+	body := decodeBody(t, resp.Body)
+	errField := body["error"].(map[string]any)
+	if errField["code"] != "TOKEN_REVOKED" {
+		t.Fatalf("unexpected error code: %v", errField["code"])
+	}
+	if repo.lookups != 0 {
+		t.Fatalf("a revoked session must not be looked up in the database")
+	}
+}
+
+func TestAuthenticateActiveSessionSkipsDatabase(t *testing.T) {
+	t.Parallel()
+	repo := &stubSessionRepo{}
+	app := newSessionTestApp(&stubSessionStore{state: services.SessionActive}, repo)
+
+	resp, err := doRequest(app, "Bearer session.token")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	if repo.lookups != 0 {
+		t.Fatalf("an active session must not be looked up in the database")
+	}
+}
+
+func TestAuthenticateUnknownSessionFallsBackToDatabase(t *testing.T) {
+	t.Parallel()
+	store := &stubSessionStore{state: services.SessionUnknown}
+	repo := &stubSessionRepo{sessions: map[string]*models.CustomerSession{
+		"session.token": {CustomerID: 42, ExpiresAt: time.Now().Add(time.Hour)},
+	}}
+	app := newSessionTestApp(store, repo)
+
+	resp, err := doRequest(app, "Bearer session.token")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	if len(store.activated) != 1 || store.activated[0] != "session.token" {
+		t.Fatalf("an active session found in the database must be mirrored again, got %v", store.activated)
+	}
+
+	resp, err = doRequest(app, "Bearer other.token")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusUnauthorized {
+		t.Fatalf("a token without an active session must be rejected, got %d", resp.StatusCode)
+	}
+}
+
+func TestAuthenticateSessionStoreDownFallsBackToDatabase(t *testing.T) {
+	t.Parallel()
+	store := &stubSessionStore{stateErr: errors.New("connection refused")}
+	repo := &stubSessionRepo{sessions: map[string]*models.CustomerSession{
+		"session.token": {CustomerID: 42, ExpiresAt: time.Now().Add(time.Hour)},
+	}}
+	app := newSessionTestApp(store, repo)
+
+	resp, err := doRequest(app, "Bearer session.token")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	if repo.lookups != 1 {
+		t.Fatalf("expected one database lookup, got %d", repo.lookups)
+	}
+	if len(store.activated) != 0 {
+		t.Fatalf("nothing must be mirrored while the store is down")
+	}
+
+	resp, err = doRequest(app, "Bearer other.token")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusUnauthorized {
+		t.Fatalf("a token without an active session must be rejected, got %d", resp.StatusCode)
+	}
+}
+
+func TestAuthenticateRecordsSessionAccessWhenDue(t *testing.T) {
+	t.Parallel()
+	store := &stubSessionStore{state: services.SessionActive}
+	repo := &stubSessionRepo{}
+	app := newSessionTestApp(store, repo)
+
+	if _, err := doRequest(app, "Bearer session.token"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(repo.touched) != 0 {
+		t.Fatalf("access recorded before it was due: %v", repo.touched)
+	}
+
+	store.accessDue = true
+	if _, err := doRequest(app, "Bearer session.token"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(repo.touched) != 1 || repo.touched[0] != "session.token" {
+		t.Fatalf("expected the access to be recorded once, got %v", repo.touched)
+	}
+}
+
+// TestDenyImpersonation covers the routes the router guards with
+// DenyImpersonation
+func TestDenyImpersonation(t *testing.T) {
+	t.Parallel()
+	stub := &stubTokenService{
+		validateFn: func(token string) (*services.TokenClaims, error) {
+			if token == "impersonation.token.here" {
+				return &services.TokenClaims{CustomerID: 42, TokenType: services.TokenTypeImpersonation, ImpersonatedBy: 7}, nil
+			}
+			return &services.TokenClaims{CustomerID: 42, TokenType: "access"}, nil
+		},
+	}
+	mw := middleware.NewAuthMiddleware(stub, nil, nil)
+
+	routes := []struct{ method, path string }{
+		{http.MethodPost, "/api/v1/wallet/charge"},
+		{http.MethodPost, "/api/v1/payments/charge-wallet"},
+		{http.MethodPost, "/api/v1/wallet/transfers"},
+		{http.MethodPost, "/api/v1/wallet/transfers/:uuid/confirm"},
+		{http.MethodPut, "/api/v1/auth/password"},
+		{http.MethodPost, "/api/v1/auth/mobile/otp"},
+		{http.MethodPut, "/api/v1/auth/mobile"},
+		{http.MethodPost, "/api/v1/account/deletion"},
+	}
+	app := fiber.New()
+	for _, route := range routes {
+		app.Add([]string{route.method}, route.path, mw.Authenticate(), middleware.DenyImpersonation(), func(c fiber.Ctx) error {
+			return c.SendString("ok")
+		})
+	}
+
+	for _, route := range routes {
+		path := strings.Replace(route.path, ":uuid", "4b0c6a4e-3f0e-4c43-9a35-1d1b2b6f6a10", 1)
+		for token, want := range map[string]int{
+			"impersonation.token.here": fiber.StatusForbidden,
+			"valid.token.here":         fiber.StatusOK,
+		} {
+			req := httptest.NewRequest(route.method, path, nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("%s %s: %v", route.method, path, err)
+			}
+			if resp.StatusCode != want {
+				t.Errorf("%s %s with %s: expected %d, got %d", route.method, path, token, want, resp.StatusCode)
+				continue
+			}
+			if want == fiber.StatusForbidden {
+				body := decodeBody(t, resp.Body)
+				if errObj, _ := body["error"].(map[string]any); errObj["code"] != "IMPERSONATION_NOT_ALLOWED" {
+					t.Errorf("%s %s: expected IMPERSONATION_NOT_ALLOWED, got %v", route.method, path, body["error"])
+				}
+			}
+		}
+	}
+}
@@ -4,8 +4,8 @@ import (
 	"log"
 	"strings"
 
+	"github.com/amirphl/Yamata-no-Orochi/app/apierror"
 	"github.com/amirphl/Yamata-no-Orochi/app/authorization"
-	"github.com/amirphl/Yamata-no-Orochi/app/dto"
 	"github.com/amirphl/Yamata-no-Orochi/repository"
 	"github.com/gofiber/fiber/v3"
 )
@@ -27,45 +27,25 @@ func (m *AuthorizationMiddleware) AdminAuthorize() fiber.Handler {
 		perm, ok := authorization.PermissionForRoute(string(c.Method()), c.Path())
 		if !ok {
 			log.Printf("permission_unmapped method=%s path=%s", c.Method(), c.Path())
-			return c.Status(fiber.StatusForbidden).JSON(dto.APIResponse{
-				Success: false,
-				Message: "Permission mapping missing",
-				Error:   dto.ErrorDetail{Code: "PERMISSION_NOT_MAPPED"},
-			})
+			return apierror.Respond(c, fiber.StatusForbidden, "Permission mapping missing", "PERMISSION_NOT_MAPPED", nil)
 		}
 
 		adminID, exists := GetAdminIDFromContext(c)
 		if !exists || adminID == 0 {
-			return c.Status(fiber.StatusUnauthorized).JSON(dto.APIResponse{
-				Success: false,
-				Message: "Admin authentication required",
-				Error:   dto.ErrorDetail{Code: "ADMIN_AUTHENTICATION_REQUIRED"},
-			})
+			return apierror.Respond(c, fiber.StatusUnauthorized, "Admin authentication required", "ADMIN_AUTHENTICATION_REQUIRED", nil)
 		}
 
 		admin, err := m.adminRepo.ByID(c.Context(), adminID)
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(dto.APIResponse{
-				Success: false,
-				Message: "Permission check failed",
-				Error:   dto.ErrorDetail{Code: "PERMISSION_CHECK_FAILED"},
-			})
+			return apierror.Respond(c, fiber.StatusInternalServerError, "Permission check failed", "PERMISSION_CHECK_FAILED", nil)
 		}
 		if admin == nil || (admin.IsActive != nil && !*admin.IsActive) {
-			return c.Status(fiber.StatusForbidden).JSON(dto.APIResponse{
-				Success: false,
-				Message: "Admin inactive",
-				Error:   dto.ErrorDetail{Code: "ADMIN_INACTIVE"},
-			})
+			return apierror.Respond(c, fiber.StatusForbidden, "Admin inactive", "ADMIN_INACTIVE", nil)
 		}
 
 		if !authorization.HasPermission(admin, perm) {
 			log.Printf("permission_denied admin_id=%d method=%s path=%s permission=%s", adminID, c.Method(), c.Path(), perm)
-			return c.Status(fiber.StatusForbidden).JSON(dto.APIResponse{
-				Success: false,
-				Message: "Permission denied",
-				Error:   dto.ErrorDetail{Code: "PERMISSION_DENIED", Details: map[string]any{"permission": perm}},
-			})
+			return apierror.Respond(c, fiber.StatusForbidden, "Permission denied", "PERMISSION_DENIED", map[string]any{"permission": perm})
 		}
 
 		return c.Next()
@@ -78,11 +58,7 @@ func (m *AuthorizationMiddleware) ServiceAccountAuthorize(required authorization
 	return func(c fiber.Ctx) error {
 		header := strings.TrimSpace(c.Get("X-Service-Permissions"))
 		if header == "" {
-			return c.Status(fiber.StatusForbidden).JSON(dto.APIResponse{
-				Success: false,
-				Message: "Permission denied",
-				Error:   dto.ErrorDetail{Code: "PERMISSION_DENIED", Details: map[string]any{"permission": required}},
-			})
+			return apierror.Respond(c, fiber.StatusForbidden, "Permission denied", "PERMISSION_DENIED", map[string]any{"permission": required})
 		}
 		has := false
 		for _, part := range strings.Split(header, ",") {
@@ -92,11 +68,7 @@ func (m *AuthorizationMiddleware) ServiceAccountAuthorize(required authorization
 			}
 		}
 		if !has {
-			return c.Status(fiber.StatusForbidden).JSON(dto.APIResponse{
-				Success: false,
-				Message: "Permission denied",
-				Error:   dto.ErrorDetail{Code: "PERMISSION_DENIED", Details: map[string]any{"permission": required}},
-			})
+			return apierror.Respond(c, fiber.StatusForbidden, "Permission denied", "PERMISSION_DENIED", map[string]any{"permission": required})
 		}
 		return c.Next()
 	}
@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/apierror"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/gofiber/fiber/v3"
+)
+
+// ActingAsCustomerHeader names the referred customer an agency makes a
+// request for
+const ActingAsCustomerHeader = "X-Acting-As-Customer"
+
+// ActAsCustomer lets an agency make a request for the referred customer
+// named by the X-Acting-As-Customer header. The customer becomes the
+// request's customer and the agency is kept as its delegate; the flow
+// serving the route checks the customer's delegation grant, so mount it only
+// on routes whose flow does. Requests without the header pass unchanged.
+// Must run after Authenticate.
+func ActAsCustomer() fiber.Handler {
+	return func(c fiber.Ctx) error {
+		raw := strings.TrimSpace(c.Get(ActingAsCustomerHeader))
+		if raw == "" {
+			return c.Next()
+		}
+		agencyID, ok := GetCustomerIDFromContext(c)
+		if !ok || agencyID == 0 {
+			return apierror.Respond(c, fiber.StatusUnauthorized, "Customer ID not found in context", "MISSING_CUSTOMER_ID", nil)
+		}
+		customerID, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil || customerID == 0 {
+			return apierror.Respond(c, fiber.StatusBadRequest, "X-Acting-As-Customer must be a positive customer ID", "INVALID_ACTING_AS_CUSTOMER", nil)
+		}
+		if uint(customerID) == agencyID {
+			return c.Next()
+		}
+
+		c.Locals("delegated_by", agencyID)
+		c.Locals("customer_id", uint(customerID))
+		return c.Next()
+	}
+}
+
+// GetDelegationFromContext returns the agency and customer of a request an
+// agency makes for a referred customer
+func GetDelegationFromContext(c fiber.Ctx) (utils.Delegation, bool) {
+	agencyID, ok := c.Locals("delegated_by").(uint)
+	if !ok || agencyID == 0 {
+		return utils.Delegation{}, false
+	}
+	customerID, _ := GetCustomerIDFromContext(c)
+	return utils.Delegation{AgencyID: agencyID, CustomerID: customerID}, true
+}
+
+// WithDelegation adds the delegation of the request, if any, to ctx so flows
+// check the agency's grant and audit logs name both the customer and the
+// agency
+func WithDelegation(ctx context.Context, c fiber.Ctx) context.Context {
+	if delegation, ok := GetDelegationFromContext(c); ok {
+		return context.WithValue(ctx, utils.DelegationKey, delegation)
+	}
+	return ctx
+}
@@ -0,0 +1,104 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/middleware"
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/repository"
+	"github.com/gofiber/fiber/v3"
+)
+
+// recordingAuditRepo keeps saved audit logs in memory
+type recordingAuditRepo struct {
+	repository.AuditLogRepository
+	saved []*models.AuditLog
+}
+
+func (r *recordingAuditRepo) Save(_ context.Context, audit *models.AuditLog) error {
+	r.saved = append(r.saved, audit)
+	return nil
+}
+
+func requestFrom(t *testing.T, app *fiber.App, ip string) int {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/admin", nil)
+	req.Header.Set("X-Real-IP", ip)
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return resp.StatusCode
+}
+
+func newAllowlistTestApp(t *testing.T, entries []string, auditRepo repository.AuditLogRepository) *fiber.App {
+	t.Helper()
+	mw, err := middleware.NewIPAllowlistMiddleware(entries, auditRepo)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	app := fiber.New(fiber.Config{
+		ProxyHeader: "X-Real-IP",
+		TrustProxy:  true,
+		TrustProxyConfig: fiber.TrustProxyConfig{
+			Loopback: true,
+			Private:  true,
+			Proxies:  []string{"0.0.0.0"},
+		},
+	})
+	app.Get("/admin", mw.Allow(), func(c fiber.Ctx) error {
+		return c.SendString("ok")
+	})
+	return app
+}
+
+func TestIPAllowlistAllowsConfiguredNetworks(t *testing.T) {
+	auditRepo := &recordingAuditRepo{}
+	app := newAllowlistTestApp(t, []string{"10.20.0.0/16", "203.0.113.7", "2001:db8::/32"}, auditRepo)
+
+	for _, ip := range []string{"10.20.1.2", "203.0.113.7", "2001:db8::1"} {
+		if status := requestFrom(t, app, ip); status != fiber.StatusOK {
+			t.Errorf("%s: expected 200, got %d", ip, status)
+		}
+	}
+	if len(auditRepo.saved) != 0 {
+		t.Errorf("expected no audit logs, got %d", len(auditRepo.saved))
+	}
+}
+
+func TestIPAllowlistDeniesAndAudits(t *testing.T) {
+	auditRepo := &recordingAuditRepo{}
+	app := newAllowlistTestApp(t, []string{"10.20.0.0/16", "203.0.113.7"}, auditRepo)
+
+	for _, ip := range []string{"10.21.0.1", "203.0.113.8"} {
+		if status := requestFrom(t, app, ip); status != fiber.StatusForbidden {
+			t.Errorf("%s: expected 403, got %d", ip, status)
+		}
+	}
+	if len(auditRepo.saved) != 2 {
+		t.Fatalf("expected 2 audit logs, got %d", len(auditRepo.saved))
+	}
+	audit := auditRepo.saved[0]
+	if audit.Action != models.AuditActionAdminIPDenied || audit.IPAddress == nil || *audit.IPAddress != "10.21.0.1" {
+		t.Errorf("unexpected audit log %+v", audit)
+	}
+}
+
+func TestIPAllowlistEmptyAllowsEverything(t *testing.T) {
+	app := newAllowlistTestApp(t, nil, nil)
+	if status := requestFrom(t, app, "198.51.100.1"); status != fiber.StatusOK {
+		t.Errorf("expected 200, got %d", status)
+	}
+}
+
+func TestIPAllowlistRejectsInvalidEntries(t *testing.T) {
+	if _, err := middleware.NewIPAllowlistMiddleware([]string{"10.0.0.0/33"}, nil); err == nil {
+		t.Error("expected an error for an invalid CIDR range")
+	}
+	if _, err := middleware.NewIPAllowlistMiddleware([]string{"office"}, nil); err == nil {
+		t.Error("expected an error for a hostname")
+	}
+}
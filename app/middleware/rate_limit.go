@@ -0,0 +1,204 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/apierror"
+	"github.com/amirphl/Yamata-no-Orochi/config"
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/middleware/limiter"
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/redis/go-redis/v9"
+)
+
+// Rate limit decisions partitioned by policy, key scope, and result
+var rateLimitDecisionsTotal = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "http_rate_limit_decisions_total",
+		Help: "Rate limit decisions by policy, scope (ip, customer), and result (allowed, limited, error)",
+	},
+	[]string{"policy", "scope", "result"},
+)
+
+// SlidingWindow counts requests per key over a sliding time window
+type SlidingWindow interface {
+	// Allow records a request for key unless limit requests were already
+	// recorded in the last window; when refused it returns how long until the
+	// oldest of them leaves the window
+	Allow(ctx context.Context, key string, limit int, window time.Duration) (bool, time.Duration, error)
+}
+
+// slidingWindowScript keeps one sorted set member per request scored by its
+// time in milliseconds
+var slidingWindowScript = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now - window)
+local count = redis.call('ZCARD', KEYS[1])
+if count >= limit then
+	local oldest = redis.call('ZRANGE', KEYS[1], 0, 0, 'WITHSCORES')
+	local retry = window
+	if oldest[2] then
+		retry = tonumber(oldest[2]) + window - now
+	end
+	return {0, retry}
+end
+redis.call('ZADD', KEYS[1], now, ARGV[4])
+redis.call('PEXPIRE', KEYS[1], window)
+return {1, 0}
+`)
+
+type redisSlidingWindow struct {
+	rc *redis.Client
+}
+
+// NewRedisSlidingWindow creates a sliding window shared by every instance
+// through Redis
+func NewRedisSlidingWindow(rc *redis.Client) SlidingWindow {
+	return &redisSlidingWindow{rc: rc}
+}
+
+func (w *redisSlidingWindow) Allow(ctx context.Context, key string, limit int, window time.Duration) (bool, time.Duration, error) {
+	res, err := slidingWindowScript.Run(ctx, w.rc, []string{key},
+		time.Now().UnixMilli(), window.Milliseconds(), limit, uuid.NewString()).Int64Slice()
+	if err != nil {
+		return false, 0, err
+	}
+	if len(res) != 2 {
+		return false, 0, fmt.Errorf("unexpected sliding window reply %v", res)
+	}
+	return res[0] == 1, time.Duration(res[1]) * time.Millisecond, nil
+}
+
+// RateLimitMiddleware applies the configured per-IP and per-customer request
+// limits. Limits are shared across instances through Redis; without Redis each
+// instance limits per IP in memory. A limit of 0 disables it. Limits can be
+// changed while serving with SetLimits.
+type RateLimitMiddleware struct {
+	window SlidingWindow
+	cfg    atomic.Pointer[config.SecurityConfig]
+
+	auth    fiber.Handler
+	otp     fiber.Handler
+	payment fiber.Handler
+}
+
+// NewRateLimitMiddleware creates the rate limit middleware; window may be nil
+// when Redis is not configured
+func NewRateLimitMiddleware(window SlidingWindow, cfg config.SecurityConfig) *RateLimitMiddleware {
+	m := &RateLimitMiddleware{window: window}
+	m.SetLimits(cfg)
+	// Handlers are built once so routes sharing a policy share its counters
+	// in the in-memory fallback too
+	m.auth = m.limit("auth", func(c *config.SecurityConfig) (int, int) { return c.AuthRateLimit, 0 })
+	m.otp = m.limit("otp", func(c *config.SecurityConfig) (int, int) { return c.OTPRateLimit, 0 })
+	m.payment = m.limit("payment", func(c *config.SecurityConfig) (int, int) { return c.PaymentIPRateLimit, c.PaymentRateLimit })
+	return m
+}
+
+// SetLimits replaces the rate limits and window; requests already counted
+// are kept
+func (m *RateLimitMiddleware) SetLimits(cfg config.SecurityConfig) {
+	if cfg.RateLimitWindow <= 0 {
+		cfg.RateLimitWindow = time.Minute
+	}
+	m.cfg.Store(&cfg)
+}
+
+// Auth limits authentication endpoints per IP
+func (m *RateLimitMiddleware) Auth() fiber.Handler {
+	return m.auth
+}
+
+// OTP limits endpoints that send a one-time password per IP
+func (m *RateLimitMiddleware) OTP() fiber.Handler {
+	return m.otp
+}
+
+// Payment limits payment endpoints per IP and per authenticated customer; it
+// must run after authentication
+func (m *RateLimitMiddleware) Payment() fiber.Handler {
+	return m.payment
+}
+
+// limit builds the handler of a policy; limits returns its per-IP and
+// per-customer limits from the current configuration
+func (m *RateLimitMiddleware) limit(policy string, limits func(*config.SecurityConfig) (int, int)) fiber.Handler {
+	if m.window == nil {
+		inMemory := limiter.New(limiter.Config{
+			MaxFunc: func(c fiber.Ctx) int {
+				ipLimit, _ := limits(m.cfg.Load())
+				return ipLimit
+			},
+			ExpirationFunc: func(c fiber.Ctx) time.Duration { return m.cfg.Load().RateLimitWindow },
+			KeyGenerator:   func(c fiber.Ctx) string { return c.IP() },
+			LimitReached: func(c fiber.Ctx) error {
+				rateLimitDecisionsTotal.WithLabelValues(policy, "ip", "limited").Inc()
+				return rateLimitExceeded(c, m.cfg.Load().RateLimitWindow)
+			},
+		})
+		return func(c fiber.Ctx) error {
+			if ipLimit, _ := limits(m.cfg.Load()); ipLimit <= 0 {
+				return c.Next()
+			}
+			return inMemory(c)
+		}
+	}
+
+	return func(c fiber.Ctx) error {
+		cfg := m.cfg.Load()
+		ipLimit, customerLimit := limits(cfg)
+		window := cfg.RateLimitWindow
+		if ipLimit > 0 {
+			key := "rate_limit:" + policy + ":ip:" + c.IP()
+			if ok, retryAfter := m.allow(c, policy, "ip", key, ipLimit, window); !ok {
+				return rateLimitExceeded(c, retryAfter)
+			}
+		}
+		if customerID, ok := c.Locals("customer_id").(uint); ok && customerID != 0 && customerLimit > 0 {
+			key := "rate_limit:" + policy + ":customer:" + strconv.FormatUint(uint64(customerID), 10)
+			if ok, retryAfter := m.allow(c, policy, "customer", key, customerLimit, window); !ok {
+				return rateLimitExceeded(c, retryAfter)
+			}
+		}
+		return c.Next()
+	}
+}
+
+// allow fails open when Redis is unavailable so an outage does not lock
+// customers out
+func (m *RateLimitMiddleware) allow(c fiber.Ctx, policy, scope, key string, limit int, window time.Duration) (bool, time.Duration) {
+	ctx, cancel := context.WithTimeout(c.Context(), 200*time.Millisecond)
+	defer cancel()
+
+	ok, retryAfter, err := m.window.Allow(ctx, key, limit, window)
+	if err != nil {
+		rateLimitDecisionsTotal.WithLabelValues(policy, scope, "error").Inc()
+		log.Printf("rate limit %s/%s: %v", policy, scope, err)
+		return true, 0
+	}
+	if !ok {
+		rateLimitDecisionsTotal.WithLabelValues(policy, scope, "limited").Inc()
+		return false, retryAfter
+	}
+	rateLimitDecisionsTotal.WithLabelValues(policy, scope, "allowed").Inc()
+	return true, 0
+}
+
+func rateLimitExceeded(c fiber.Ctx, retryAfter time.Duration) error {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	c.Set(fiber.HeaderRetryAfter, strconv.Itoa(seconds))
+	return apierror.Respond(c, fiber.StatusTooManyRequests, "Too many requests. Please try again later.", "RATE_LIMIT_EXCEEDED", map[string]any{"retry_after_seconds": seconds})
+}
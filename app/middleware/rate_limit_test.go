@@ -0,0 +1,153 @@
+package middleware_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/middleware"
+	"github.com/amirphl/Yamata-no-Orochi/config"
+	"github.com/gofiber/fiber/v3"
+)
+
+// countingWindow is an in-memory SlidingWindow that never expires entries
+type countingWindow struct {
+	mu     sync.Mutex
+	counts map[string]int
+	err    error
+}
+
+func (w *countingWindow) Allow(_ context.Context, key string, limit int, window time.Duration) (bool, time.Duration, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.err != nil {
+		return false, 0, w.err
+	}
+	if w.counts == nil {
+		w.counts = map[string]int{}
+	}
+	if w.counts[key] >= limit {
+		return false, 1500 * time.Millisecond, nil
+	}
+	w.counts[key]++
+	return true, 0, nil
+}
+
+func newPaymentTestApp(mw *middleware.RateLimitMiddleware, customerID uint) *fiber.App {
+	app := fiber.New()
+	app.Post("/pay", func(c fiber.Ctx) error {
+		c.Locals("customer_id", customerID)
+		return c.Next()
+	}, mw.Payment(), func(c fiber.Ctx) error {
+		return c.SendString("ok")
+	})
+	return app
+}
+
+func postPay(t *testing.T, app *fiber.App) *http.Response {
+	t.Helper()
+	resp, err := app.Test(httptest.NewRequest(http.MethodPost, "/pay", nil))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return resp
+}
+
+func TestRateLimitPaymentPerCustomer(t *testing.T) {
+	t.Parallel()
+
+	window := &countingWindow{}
+	mw := middleware.NewRateLimitMiddleware(window, config.SecurityConfig{PaymentIPRateLimit: 10, PaymentRateLimit: 2})
+	app := newPaymentTestApp(mw, 7)
+
+	for i := 0; i < 2; i++ {
+		if resp := postPay(t, app); resp.StatusCode != fiber.StatusOK {
+			t.Fatalf("request %d: expected 200, got %d", i+1, resp.StatusCode)
+		}
+	}
+	resp := postPay(t, app)
+	if resp.StatusCode != fiber.StatusTooManyRequests {
+		t.Fatalf("expected 429 after the customer limit, got %d", resp.StatusCode)
+	}
+	if got := resp.Header.Get("Retry-After"); got != "2" {
+		t.Fatalf("expected Retry-After rounded up to 2 seconds, got %q", got)
+	}
+	errField, _ := decodeBody(t, resp.Body)["error"].(map[string]any)
+	if errField["code"] != "RATE_LIMIT_EXCEEDED" {
+		t.Fatalf("unexpected error code: %v", errField["code"])
+	}
+
+	// Another customer behind the same IP keeps its own budget
+	if resp := postPay(t, newPaymentTestApp(mw, 8)); resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("expected other customer to be allowed, got %d", resp.StatusCode)
+	}
+}
+
+func TestRateLimitFailsOpenOnWindowError(t *testing.T) {
+	t.Parallel()
+
+	window := &countingWindow{err: errors.New("redis unavailable")}
+	mw := middleware.NewRateLimitMiddleware(window, config.SecurityConfig{PaymentIPRateLimit: 1, PaymentRateLimit: 1})
+	app := newPaymentTestApp(mw, 7)
+
+	for i := 0; i < 3; i++ {
+		if resp := postPay(t, app); resp.StatusCode != fiber.StatusOK {
+			t.Fatalf("request %d: expected 200 while the window is unavailable, got %d", i+1, resp.StatusCode)
+		}
+	}
+}
+
+func TestRateLimitInMemoryFallback(t *testing.T) {
+	t.Parallel()
+
+	mw := middleware.NewRateLimitMiddleware(nil, config.SecurityConfig{OTPRateLimit: 1})
+	app := fiber.New()
+	// Both routes share the otp policy counters
+	app.Post("/a", mw.OTP(), func(c fiber.Ctx) error { return c.SendString("ok") })
+	app.Post("/b", mw.OTP(), func(c fiber.Ctx) error { return c.SendString("ok") })
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodPost, "/a", nil))
+	if err != nil || resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("expected first OTP request to pass, got %v %v", resp.StatusCode, err)
+	}
+	resp, err = app.Test(httptest.NewRequest(http.MethodPost, "/b", nil))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusTooManyRequests {
+		t.Fatalf("expected 429 on the second OTP request, got %d", resp.StatusCode)
+	}
+	if resp.Header.Get("Retry-After") == "" {
+		t.Fatalf("expected Retry-After header")
+	}
+}
+
+func TestRateLimitSetLimits(t *testing.T) {
+	t.Parallel()
+
+	window := &countingWindow{}
+	mw := middleware.NewRateLimitMiddleware(window, config.SecurityConfig{PaymentRateLimit: 1})
+	app := newPaymentTestApp(mw, 7)
+
+	postPay(t, app)
+	if resp := postPay(t, app); resp.StatusCode != fiber.StatusTooManyRequests {
+		t.Fatalf("expected 429 at the initial limit, got %d", resp.StatusCode)
+	}
+
+	// Handlers already built pick up the new limits
+	mw.SetLimits(config.SecurityConfig{PaymentRateLimit: 2})
+	if resp := postPay(t, app); resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("expected 200 after raising the limit, got %d", resp.StatusCode)
+	}
+
+	mw.SetLimits(config.SecurityConfig{})
+	for i := 0; i < 3; i++ {
+		if resp := postPay(t, app); resp.StatusCode != fiber.StatusOK {
+			t.Fatalf("request %d: expected 200 with the limit disabled, got %d", i+1, resp.StatusCode)
+		}
+	}
+}
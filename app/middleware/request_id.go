@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"regexp"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/gofiber/fiber/v3"
+)
+
+const (
+	// RequestIDHeader carries the request ID in requests, responses and
+	// outbound provider calls
+	RequestIDHeader = "X-Request-ID"
+	// CorrelationIDHeader is accepted as the request ID from clients that
+	// already trace their calls under that name
+	CorrelationIDHeader = "X-Correlation-ID"
+
+	// requestIDLocal is the key the access log and error responses read
+	requestIDLocal = "requestid"
+)
+
+// Client-supplied IDs are echoed in headers and stored in audit logs, so
+// anything but short printable tokens is replaced
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// RequestID gives every request an ID: the client's X-Request-ID or
+// X-Correlation-ID when valid, a generated one otherwise. The ID is stored in
+// the locals and the request context, written back to the request header for
+// handlers that read it from there, and returned in the response header.
+func RequestID() fiber.Handler {
+	return func(c fiber.Ctx) error {
+		id := c.Get(RequestIDHeader)
+		if !validRequestID.MatchString(id) {
+			id = c.Get(CorrelationIDHeader)
+		}
+		if !validRequestID.MatchString(id) {
+			id = generateRequestID()
+		}
+
+		c.Locals(requestIDLocal, id)
+		c.SetContext(context.WithValue(c.Context(), utils.RequestIDKey, id))
+		c.Request().Header.Set(RequestIDHeader, id)
+		c.Set(RequestIDHeader, id)
+		return c.Next()
+	}
+}
+
+// GetRequestID returns the ID assigned by RequestID, or "" before it ran
+func GetRequestID(c fiber.Ctx) string {
+	id, _ := c.Locals(requestIDLocal).(string)
+	return id
+}
+
+// generateRequestID creates a unique request ID
+func generateRequestID() string {
+	bytes := make([]byte, 8)
+	if _, err := rand.Read(bytes); err != nil {
+		return hex.EncodeToString([]byte(time.Now().Format("150405.000000")))
+	}
+	return hex.EncodeToString(bytes)
+}
@@ -0,0 +1,71 @@
+package middleware_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/middleware"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/gofiber/fiber/v3"
+)
+
+// requestIDs returns the response header and the IDs the handler sees in
+// the locals, the request header and the request context
+func requestIDs(t *testing.T, headers map[string]string) (string, string) {
+	t.Helper()
+	app := fiber.New()
+	app.Use(middleware.RequestID())
+	app.Get("/", func(c fiber.Ctx) error {
+		ctxID, _ := c.Context().Value(utils.RequestIDKey).(string)
+		return c.SendString(strings.Join([]string{middleware.GetRequestID(c), c.Get("X-Request-ID"), ctxID}, ","))
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("read body: %v", err)
+	}
+	seen := strings.Split(string(body), ",")
+	for _, id := range seen[1:] {
+		if id != seen[0] {
+			t.Fatalf("handler saw different IDs %v", seen)
+		}
+	}
+	return resp.Header.Get("X-Request-ID"), seen[0]
+}
+
+func TestRequestIDKeepsClientID(t *testing.T) {
+	header, seen := requestIDs(t, map[string]string{"X-Request-ID": "support-42"})
+	if header != "support-42" || seen != "support-42" {
+		t.Fatalf("got header %q and handler %q, want support-42", header, seen)
+	}
+}
+
+func TestRequestIDAcceptsCorrelationID(t *testing.T) {
+	header, seen := requestIDs(t, map[string]string{"X-Correlation-ID": "corr-7"})
+	if header != "corr-7" || seen != "corr-7" {
+		t.Fatalf("got header %q and handler %q, want corr-7", header, seen)
+	}
+}
+
+func TestRequestIDReplacesInvalidID(t *testing.T) {
+	header, seen := requestIDs(t, map[string]string{"X-Request-ID": "<script>" + strings.Repeat("x", 200)})
+	if header == "" || header != seen || strings.Contains(header, "<") {
+		t.Fatalf("got header %q and handler %q, want a generated ID", header, seen)
+	}
+
+	generated, _ := requestIDs(t, nil)
+	if len(generated) != 16 {
+		t.Fatalf("generated ID %q, want 16 hex characters", generated)
+	}
+}
@@ -85,6 +85,20 @@ func InitSentry(cfg SentryConfig) error {
 	return nil
 }
 
+// SetSentryCapture changes which HTTP error responses are reported to Sentry
+func SetSentryCapture(capture4xx, capture5xx bool) {
+	clientMu.Lock()
+	defer clientMu.Unlock()
+	if activeClient == nil {
+		return
+	}
+	// Swap in a copy so readers holding the previous client never race
+	next := *activeClient
+	next.capture4xx = capture4xx
+	next.capture5xx = capture5xx
+	activeClient = &next
+}
+
 func ShutdownSentry(ctx context.Context) {
 	clientMu.Lock()
 	sc := activeClient
@@ -0,0 +1,150 @@
+// Package openapi serves the API as an OpenAPI 3 document. swag generates a
+// Swagger 2 document from the handler and DTO annotations (make swag); this
+// package converts it and fills in what Swagger 2 cannot express: bearer
+// security schemes, the shared error envelope and the pagination parameters.
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/amirphl/Yamata-no-Orochi/docs"
+	"github.com/getkin/kin-openapi/openapi2"
+	"github.com/getkin/kin-openapi/openapi2conv"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// Component names added to the converted document
+const (
+	ErrorResponseSchema = "ErrorResponse"
+	PageParameter       = "Page"
+	LimitParameter      = "Limit"
+)
+
+var (
+	buildOnce sync.Once
+	built     *openapi3.T
+	builtJSON []byte
+	buildErr  error
+)
+
+// Document returns the OpenAPI 3 document, built once per process
+func Document() (*openapi3.T, []byte, error) {
+	buildOnce.Do(func() {
+		built, buildErr = Build([]byte(docs.SwaggerInfo.ReadDoc()))
+		if buildErr == nil {
+			builtJSON, buildErr = json.Marshal(built)
+		}
+	})
+	return built, builtJSON, buildErr
+}
+
+// Build converts a swag-generated Swagger 2 document to OpenAPI 3
+func Build(swagger []byte) (*openapi3.T, error) {
+	var doc2 openapi2.T
+	if err := json.Unmarshal(swagger, &doc2); err != nil {
+		return nil, fmt.Errorf("decode swagger document: %w", err)
+	}
+	doc, err := openapi2conv.ToV3(&doc2)
+	if err != nil {
+		return nil, fmt.Errorf("convert swagger document: %w", err)
+	}
+	if doc.Components == nil {
+		doc.Components = &openapi3.Components{}
+	}
+	doc.Servers = openapi3.Servers{{URL: "/"}}
+
+	bearerSchemes(doc)
+	errorEnvelope(doc)
+	paginationParameters(doc)
+	return doc, nil
+}
+
+// bearerSchemes turns the Authorization header api keys, the closest Swagger 2
+// has to bearer tokens, into HTTP bearer schemes
+func bearerSchemes(doc *openapi3.T) {
+	for _, ref := range doc.Components.SecuritySchemes {
+		scheme := ref.Value
+		if scheme == nil || scheme.Type != "apiKey" || scheme.In != "header" || scheme.Name != "Authorization" {
+			continue
+		}
+		scheme.Type, scheme.Scheme, scheme.BearerFormat = "http", "bearer", "JWT"
+		scheme.In, scheme.Name = "", ""
+	}
+}
+
+// errorEnvelope documents every 4xx and 5xx response as the envelope
+// apierror.Respond writes
+func errorEnvelope(doc *openapi3.T) {
+	if doc.Components.Schemas == nil {
+		doc.Components.Schemas = openapi3.Schemas{}
+	}
+	detail := openapi3.NewObjectSchema().
+		WithProperty("code", openapi3.NewStringSchema()).
+		WithProperty("details", &openapi3.Schema{Description: "Code-specific details, e.g. validation messages"}).
+		WithProperty("request_id", openapi3.NewStringSchema())
+	detail.Required = []string{"code"}
+	detail.Properties["code"].Value.Description = "Stable error code listed in docs/api-errors.md"
+	detail.Properties["request_id"].Value.Description = "X-Request-ID of the request, to quote to support"
+
+	envelope := openapi3.NewObjectSchema().
+		WithProperty("success", openapi3.NewBoolSchema()).
+		WithProperty("message", openapi3.NewStringSchema()).
+		WithProperty("error", detail)
+	envelope.Required = []string{"success", "message", "error"}
+	envelope.Description = "Error envelope; message follows Accept-Language"
+	envelope.Properties["message"].Value.Description = "Localized message"
+	doc.Components.Schemas[ErrorResponseSchema] = envelope.NewRef()
+
+	ref := openapi3.NewSchemaRef("#/components/schemas/"+ErrorResponseSchema, envelope)
+	for _, item := range doc.Paths.Map() {
+		for _, op := range item.Operations() {
+			if op.Responses == nil {
+				continue
+			}
+			for status, resp := range op.Responses.Map() {
+				code, err := strconv.Atoi(status)
+				if err != nil || code < 400 || resp.Value == nil {
+					continue
+				}
+				resp.Value.Content = openapi3.NewContentWithJSONSchemaRef(ref)
+			}
+		}
+	}
+}
+
+// paginationParameters replaces the page and limit query parameters of list
+// endpoints with shared components
+func paginationParameters(doc *openapi3.T) {
+	if doc.Components.Parameters == nil {
+		doc.Components.Parameters = openapi3.ParametersMap{}
+	}
+	page := openapi3.NewQueryParameter("page").
+		WithSchema(openapi3.NewIntegerSchema().WithMin(1))
+	page.Description = "Page number, starting at 1"
+	page.Schema.Value.Default = 1
+	limit := openapi3.NewQueryParameter("limit").
+		WithSchema(openapi3.NewIntegerSchema().WithMin(1).WithMax(100))
+	limit.Description = "Page size; each endpoint applies its own default"
+	doc.Components.Parameters[PageParameter] = &openapi3.ParameterRef{Value: page}
+	doc.Components.Parameters[LimitParameter] = &openapi3.ParameterRef{Value: limit}
+
+	shared := map[string]*openapi3.ParameterRef{
+		"page":  {Ref: "#/components/parameters/" + PageParameter, Value: page},
+		"limit": {Ref: "#/components/parameters/" + LimitParameter, Value: limit},
+	}
+	for _, item := range doc.Paths.Map() {
+		for _, op := range item.Operations() {
+			for i, param := range op.Parameters {
+				if param.Value == nil || param.Value.In != openapi3.ParameterInQuery {
+					continue
+				}
+				if ref, ok := shared[param.Value.Name]; ok {
+					op.Parameters[i] = ref
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package openapi_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/openapi"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+func document(t *testing.T) *openapi3.T {
+	t.Helper()
+	doc, body, err := openapi.Document()
+	if err != nil {
+		t.Fatalf("build OpenAPI document: %v", err)
+	}
+	if len(body) == 0 {
+		t.Fatal("empty OpenAPI document")
+	}
+	return doc
+}
+
+func TestDocumentIsValid(t *testing.T) {
+	t.Parallel()
+
+	doc := document(t)
+	if err := doc.Validate(context.Background()); err != nil {
+		t.Fatalf("OpenAPI document is invalid: %v", err)
+	}
+	for _, name := range []string{"CustomerBearer", "AdminBearer", "BotBearer"} {
+		ref := doc.Components.SecuritySchemes[name]
+		if ref == nil || ref.Value.Type != "http" || ref.Value.Scheme != "bearer" {
+			t.Errorf("security scheme %s = %+v, want an HTTP bearer scheme", name, ref)
+		}
+	}
+}
+
+// TestOperationsDeclareTheirAuth catches handlers annotated without the
+// @Security of the route group they are registered in
+func TestOperationsDeclareTheirAuth(t *testing.T) {
+	t.Parallel()
+
+	// Every API version follows the same rules below its prefix
+	unversioned := func(path string) (string, bool) {
+		for _, prefix := range []string{"/api/v1", "/api/v2"} {
+			if rest, ok := strings.CutPrefix(path, prefix); ok && strings.HasPrefix(rest, "/") {
+				return rest, true
+			}
+		}
+		return path, false
+	}
+	public := func(path string) bool {
+		path, versioned := unversioned(path)
+		if !versioned {
+			return true
+		}
+		for _, prefix := range []string{"/auth/", "/admin/auth/", "/bot/auth/", "/payments/callback/", "/crypto/providers/", "/files/"} {
+			if strings.HasPrefix(path, prefix) && !strings.HasPrefix(path, "/auth/logout") && !strings.HasPrefix(path, "/auth/sessions") && path != "/auth/password" && !strings.HasPrefix(path, "/auth/mobile") {
+				return true
+			}
+		}
+		switch path {
+		case "/health", "/status", "/admin/docs":
+			return true
+		}
+		return false
+	}
+	want := func(path string) string {
+		path, _ = unversioned(path)
+		switch {
+		case strings.HasPrefix(path, "/admin/"):
+			return "AdminBearer"
+		case strings.HasPrefix(path, "/bot/"):
+			return "BotBearer"
+		}
+		return "CustomerBearer"
+	}
+
+	for path, item := range document(t).Paths.Map() {
+		for method, op := range item.Operations() {
+			var schemes []string
+			if op.Security != nil {
+				for _, requirement := range *op.Security {
+					for name := range requirement {
+						schemes = append(schemes, name)
+					}
+				}
+			}
+			if public(path) {
+				if len(schemes) > 0 {
+					t.Errorf("%s %s is public but declares %v", method, path, schemes)
+				}
+				continue
+			}
+			if len(schemes) != 1 || schemes[0] != want(path) {
+				t.Errorf("%s %s declares %v, want @Security %s", method, path, schemes, want(path))
+			}
+		}
+	}
+}
+
+func TestErrorsUseTheEnvelope(t *testing.T) {
+	t.Parallel()
+
+	ref := "#/components/schemas/" + openapi.ErrorResponseSchema
+	for path, item := range document(t).Paths.Map() {
+		for method, op := range item.Operations() {
+			for status, resp := range op.Responses.Map() {
+				if status[0] != '4' && status[0] != '5' {
+					continue
+				}
+				media := resp.Value.Content.Get("application/json")
+				if media == nil || media.Schema.Ref != ref {
+					t.Errorf("%s %s %s response does not use the error envelope", method, path, status)
+				}
+			}
+		}
+	}
+}
+
+func TestPaginationParametersAreShared(t *testing.T) {
+	t.Parallel()
+
+	doc := document(t)
+	op := doc.Paths.Find("/api/v1/admin/jobs").Get
+	refs := map[string]bool{}
+	for _, param := range op.Parameters {
+		refs[param.Ref] = true
+	}
+	for _, name := range []string{openapi.PageParameter, openapi.LimitParameter} {
+		if !refs["#/components/parameters/"+name] {
+			t.Errorf("GET /api/v1/admin/jobs does not reference the %s parameter", name)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package providermock
+
+import (
+	"encoding/csv"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// AtipayReferencePrefix starts the reference numbers the Atipay fake
+// verifies: AtipayReferencePrefix followed by the invoice number, so a
+// successful callback can be posted for any payment request it issued a
+// token for
+const AtipayReferencePrefix = "mock-ref-"
+
+// Atipay fakes Atipay's get-token and verify-payment APIs and serves the
+// settlement report of every payment it issued a token for
+type Atipay struct {
+	mu      sync.Mutex
+	amounts map[string]float64 // invoice number -> amount in rials
+}
+
+func NewAtipay() *Atipay {
+	return &Atipay{amounts: make(map[string]float64)}
+}
+
+func (a *Atipay) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/get-token"):
+		a.getToken(w, r)
+	case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/verify-payment"):
+		a.verifyPayment(w, r)
+	case r.Method == http.MethodGet:
+		a.settlementReport(w)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (a *Atipay) getToken(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Amount        float64 `json:"amount"`
+		InvoiceNumber string  `json:"invoiceNumber"`
+	}
+	if !decodeJSON(r, &req) || req.InvoiceNumber == "" || req.Amount <= 0 {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "0", "errorCode": "400", "message": "invalid request"})
+		return
+	}
+	a.mu.Lock()
+	a.amounts[req.InvoiceNumber] = req.Amount
+	a.mu.Unlock()
+	writeJSON(w, http.StatusOK, map[string]string{"status": "1", "token": "mock-token-" + req.InvoiceNumber})
+}
+
+// verifyPayment answers with the amount of the reference's invoice; unknown
+// references verify as 0 rials, which the payment flow rejects as a mismatch
+func (a *Atipay) verifyPayment(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ReferenceNumber string `json:"referenceNumber"`
+	}
+	if !decodeJSON(r, &req) {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+	a.mu.Lock()
+	amount := a.amounts[strings.TrimPrefix(req.ReferenceNumber, AtipayReferencePrefix)]
+	a.mu.Unlock()
+	writeJSON(w, http.StatusOK, map[string]float64{"amount": amount})
+}
+
+func (a *Atipay) settlementReport(w http.ResponseWriter) {
+	a.mu.Lock()
+	invoices := make([]string, 0, len(a.amounts))
+	for invoice := range a.amounts {
+		invoices = append(invoices, invoice)
+	}
+	slices.Sort(invoices)
+	rows := [][]string{{"reference_number", "reservation_number", "amount", "status"}}
+	for _, invoice := range invoices {
+		amount := strconv.FormatFloat(a.amounts[invoice], 'f', 0, 64)
+		rows = append(rows, []string{AtipayReferencePrefix + invoice, invoice, amount, "settled"})
+	}
+	a.mu.Unlock()
+
+	w.Header().Set("Content-Type", "text/csv")
+	_ = csv.NewWriter(w).WriteAll(rows)
+}
@@ -0,0 +1,99 @@
+package providermock
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+)
+
+// botToken is the access token the bot API fake issues and accepts
+const botToken = "mock-bot-token"
+
+// Bot fakes the bot API the campaign schedulers call. It logs in any
+// credentials, has no campaigns ready, accepts every status and statistics
+// update, echoes created short links and allocates short-link codes
+// sequentially per campaign. It is checked against the recorded bot contract
+// in app/botcontract.
+type Bot struct {
+	mu        sync.Mutex
+	allocated map[uint]int // campaign ID -> short-link codes allocated
+}
+
+func NewBot() *Bot {
+	return &Bot{allocated: make(map[uint]int)}
+}
+
+func (b *Bot) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimRight(r.URL.Path, "/")
+	if path == "/api/v1/bot/auth/login" && r.Method == http.MethodPost {
+		active := true
+		writeJSON(w, http.StatusOK, dto.APIResponse{Success: true, Message: "logged in", Data: dto.BotLoginResponse{
+			Bot:     dto.BotDTO{ID: 1, Username: "mock", IsActive: &active},
+			Session: dto.BotSessionDTO{AccessToken: botToken, ExpiresIn: 3600, TokenType: "Bearer"},
+		}})
+		return
+	}
+	if r.Header.Get("Authorization") != "Bearer "+botToken {
+		writeJSON(w, http.StatusUnauthorized, dto.APIResponse{Success: false, Message: "invalid token"})
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodGet && path == "/api/v1/bot/campaigns/ready":
+		writeJSON(w, http.StatusOK, dto.APIResponse{Success: true, Data: dto.BotListCampaignsResponse{Items: []dto.BotGetCampaignResponse{}}})
+	case r.Method == http.MethodPost && path == "/api/v1/bot/short-links/allocate":
+		b.allocateShortLinks(w, r)
+	case r.Method == http.MethodPost && path == "/api/v1/bot/short-links":
+		b.createShortLinks(w, r)
+	case r.Method == http.MethodPost && strings.HasPrefix(path, "/api/v1/bot/campaigns/") &&
+		(strings.HasSuffix(path, "/running") ||
+			strings.HasSuffix(path, "/executed") || strings.HasSuffix(path, "/statistics") ||
+			strings.HasSuffix(path, "/audience-uids")):
+		writeJSON(w, http.StatusOK, dto.APIResponse{Success: true, Message: "ok"})
+	default:
+		writeJSON(w, http.StatusNotFound, dto.APIResponse{Success: false, Message: "not found"})
+	}
+}
+
+func (b *Bot) allocateShortLinks(w http.ResponseWriter, r *http.Request) {
+	var req dto.BotAllocateShortLinksRequest
+	if !decodeJSON(r, &req) || len(req.Items) == 0 {
+		writeJSON(w, http.StatusBadRequest, dto.APIResponse{Success: false, Message: "invalid request"})
+		return
+	}
+	b.mu.Lock()
+	start := b.allocated[req.CampaignID]
+	b.allocated[req.CampaignID] = start + len(req.Items)
+	b.mu.Unlock()
+
+	codes := make([]string, len(req.Items))
+	for i := range codes {
+		codes[i] = fmt.Sprintf("m%dx%d", req.CampaignID, start+i+1)
+	}
+	writeJSON(w, http.StatusOK, dto.APIResponse{Success: true, Data: dto.BotAllocateShortLinksResponse{Message: "allocated", Codes: codes}})
+}
+
+// createShortLinks echoes the short links back as created
+func (b *Bot) createShortLinks(w http.ResponseWriter, r *http.Request) {
+	var req dto.BotCreateShortLinksRequest
+	if !decodeJSON(r, &req) || len(req.Items) == 0 {
+		writeJSON(w, http.StatusBadRequest, dto.APIResponse{Success: false, Message: "invalid request"})
+		return
+	}
+	items := make([]dto.ShortLinkDTO, len(req.Items))
+	for i, item := range req.Items {
+		items[i] = dto.ShortLinkDTO{
+			ID:          uint(i + 1),
+			UID:         item.UID,
+			CampaignID:  item.CampaignID,
+			ClientID:    item.ClientID,
+			PhoneNumber: item.PhoneNumber,
+			LongLink:    item.LongLink,
+			ShortLink:   item.ShortLink,
+		}
+	}
+	writeJSON(w, http.StatusCreated, dto.APIResponse{Success: true, Message: "Short links created", Data: dto.BotCreateShortLinksResponse{Message: "created", Items: items}})
+}
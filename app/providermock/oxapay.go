@@ -0,0 +1,144 @@
+package providermock
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// oxapayPrices are the USDT prices the OxaPay fake quotes
+var oxapayPrices = map[string]string{
+	"BTCUSDT":  "60000",
+	"ETHUSDT":  "3000",
+	"BNBUSDT":  "600",
+	"XRPUSDT":  "0.5",
+	"DOGEUSDT": "0.15",
+	"TRXUSDT":  "0.12",
+	"USDTUSDT": "1",
+}
+
+// oxapayUSDTToman is the Wallex USDT price in tomans, which the OxaPay client
+// fetches through its own outbound client
+const oxapayUSDTToman = "60000"
+
+// Oxapay fakes OxaPay's prices, static address, invoice and payment info
+// APIs. Addresses and track IDs derive from the order ID; every invoice
+// reports as paid in full.
+type Oxapay struct {
+	mu       sync.Mutex
+	invoices map[string]float64 // track ID -> amount in USDT
+}
+
+func NewOxapay() *Oxapay {
+	return &Oxapay{invoices: make(map[string]float64)}
+}
+
+func (o *Oxapay) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimRight(r.URL.Path, "/")
+	switch {
+	case r.Method == http.MethodGet && path == "/v1/trades":
+		o.wallexTrades(w)
+	case r.Method == http.MethodGet && strings.HasSuffix(path, "/common/prices"):
+		writeJSON(w, http.StatusOK, map[string]any{"success": true, "data": oxapayPrices})
+	case r.Method == http.MethodPost && strings.HasSuffix(path, "/payment/static-address/revoke"):
+		writeJSON(w, http.StatusOK, map[string]any{"status": http.StatusOK, "message": "revoked"})
+	case r.Method == http.MethodPost && strings.HasSuffix(path, "/payment/static-address"):
+		o.staticAddress(w, r)
+	case r.Method == http.MethodPost && strings.HasSuffix(path, "/payment/invoice"):
+		o.invoice(w, r)
+	case r.Method == http.MethodGet && strings.Contains(path, "/payment/"):
+		o.paymentInfo(w, path[strings.LastIndex(path, "/")+1:])
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (o *Oxapay) wallexTrades(w http.ResponseWriter) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"success": true,
+		"result": map[string]any{
+			"latestTrades": []map[string]string{{"symbol": "USDTTMN", "price": oxapayUSDTToman}},
+		},
+	})
+}
+
+func (o *Oxapay) staticAddress(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Network    string `json:"network"`
+		ToCurrency string `json:"to_currency"`
+		OrderID    string `json:"order_id"`
+	}
+	if !decodeJSON(r, &req) {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+	address := "mock-" + strings.ToLower(req.ToCurrency) + "-" + req.OrderID
+	trackID := "mock-" + req.OrderID
+	writeJSON(w, http.StatusOK, map[string]any{
+		"status":  http.StatusOK,
+		"message": "ok",
+		"data": map[string]any{
+			"address":  address,
+			"track_id": trackID,
+			"network":  req.Network,
+			"order_id": req.OrderID,
+		},
+	})
+}
+
+func (o *Oxapay) invoice(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Amount   float64 `json:"amount"`
+		LifeTime int     `json:"lifetime"`
+		OrderID  string  `json:"order_id"`
+	}
+	if !decodeJSON(r, &req) || req.OrderID == "" {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+	trackID := "mock-" + req.OrderID
+	o.mu.Lock()
+	o.invoices[trackID] = req.Amount
+	o.mu.Unlock()
+	now := time.Now().UTC()
+	writeJSON(w, http.StatusOK, map[string]any{
+		"status":  http.StatusOK,
+		"message": "ok",
+		"data": map[string]any{
+			"track_id":    trackID,
+			"payment_url": "https://oxapay.invalid/mock/" + trackID,
+			"expired_at":  now.Add(time.Duration(max(req.LifeTime, 1)) * time.Minute).Unix(),
+			"date":        now.Unix(),
+		},
+	})
+}
+
+func (o *Oxapay) paymentInfo(w http.ResponseWriter, trackID string) {
+	o.mu.Lock()
+	amount, ok := o.invoices[trackID]
+	o.mu.Unlock()
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]any{"status": http.StatusNotFound, "message": "payment not found"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"status":  http.StatusOK,
+		"message": "ok",
+		"data": map[string]any{
+			"track_id": trackID,
+			"type":     "invoice",
+			"amount":   amount,
+			"currency": "USDT",
+			"status":   "paid",
+			"txs": []map[string]any{{
+				"tx_hash":       "mock-tx-" + trackID,
+				"amount":        amount,
+				"currency":      "USDT",
+				"network":       "TRC20",
+				"status":        "confirmed",
+				"confirmations": 20,
+			}},
+		},
+	})
+}
@@ -0,0 +1,71 @@
+package providermock
+
+import (
+	"net/http"
+	"strings"
+)
+
+// payamSMSToken is the access token the PayamSMS fake issues and accepts
+const payamSMSToken = "mock-payamsms-token"
+
+// PayamSMS fakes PayamSMS's OAuth token, bulk send and delivery status APIs.
+// Every message is accepted and reported delivered in one part.
+type PayamSMS struct{}
+
+func NewPayamSMS() *PayamSMS {
+	return &PayamSMS{}
+}
+
+func (p *PayamSMS) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.Method == http.MethodPost && strings.Contains(r.URL.Path, "/oauth/token"):
+		writeJSON(w, http.StatusOK, map[string]string{"access_token": payamSMSToken})
+	case r.Header.Get("Authorization") != "Bearer "+payamSMSToken:
+		http.Error(w, "invalid token", http.StatusUnauthorized)
+	case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/sendMultipleWithSrc"):
+		p.send(w, r)
+	case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/status"):
+		p.status(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (p *PayamSMS) send(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		SMSItems []struct {
+			Recipient  string `json:"recipient"`
+			CustomerID string `json:"customerId"`
+		} `json:"smsItems"`
+	}
+	if !decodeJSON(r, &req) {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+	results := make([]map[string]any, 0, len(req.SMSItems))
+	for _, item := range req.SMSItems {
+		results = append(results, map[string]any{
+			"customerId": item.CustomerID,
+			"mobile":     item.Recipient,
+			"serverId":   "mock-" + item.CustomerID,
+		})
+	}
+	writeJSON(w, http.StatusOK, results)
+}
+
+func (p *PayamSMS) status(w http.ResponseWriter, r *http.Request) {
+	ids := r.URL.Query()["ids"]
+	results := make([]map[string]any, 0, len(ids))
+	for _, id := range ids {
+		results = append(results, map[string]any{
+			"customerId":            id,
+			"serverId":              "mock-" + id,
+			"totalParts":            1,
+			"totalDeliveredParts":   1,
+			"totalUnDeliveredParts": 0,
+			"totalUnKnownParts":     0,
+			"status":                "DELIVERED",
+		})
+	}
+	writeJSON(w, http.StatusOK, results)
+}
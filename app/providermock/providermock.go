@@ -0,0 +1,42 @@
+// Package providermock fakes Atipay, OxaPay, PayamSMS and the bot API
+// in-process for local development. Every fake answers with fixtures derived
+// from the request, so running a flow twice yields the same tokens, IDs and
+// amounts.
+package providermock
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/httpclient"
+	"github.com/amirphl/Yamata-no-Orochi/config"
+)
+
+// Clients maps the outbound clients of the mocked providers in cfg to their
+// fakes, for httpclient.Config.Mocks
+func Clients(cfg config.MocksConfig) map[string]httpclient.Mock {
+	mocks := make(map[string]httpclient.Mock)
+	add := func(p config.ProviderMockConfig, handler http.Handler, clients ...string) {
+		if !p.Enabled {
+			return
+		}
+		for _, name := range clients {
+			mocks[name] = httpclient.Mock{Handler: handler, Latency: p.Latency, FailureRate: p.FailureRate}
+		}
+	}
+	add(cfg.Atipay, NewAtipay(), "atipay")
+	add(cfg.Oxapay, NewOxapay(), "oxapay")
+	add(cfg.PayamSMS, NewPayamSMS(), "payamsms")
+	add(cfg.Bot, NewBot(), "bot")
+	return mocks
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func decodeJSON(r *http.Request, out any) bool {
+	return json.NewDecoder(r.Body).Decode(out) == nil
+}
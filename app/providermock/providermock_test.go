@@ -0,0 +1,138 @@
+package providermock_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/botcontract"
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/app/httpclient"
+	"github.com/amirphl/Yamata-no-Orochi/app/providermock"
+	"github.com/amirphl/Yamata-no-Orochi/app/scheduler"
+	"github.com/amirphl/Yamata-no-Orochi/app/services"
+	"github.com/amirphl/Yamata-no-Orochi/config"
+)
+
+func configureMocks(t *testing.T) {
+	t.Helper()
+	enabled := config.ProviderMockConfig{Enabled: true}
+	httpclient.Configure(httpclient.Config{
+		MaxIdleConns: 1, MaxIdleConnsPerHost: 1, BreakerThreshold: 5, BreakerCooldown: time.Second,
+		Mocks: providermock.Clients(config.MocksConfig{Atipay: enabled, Oxapay: enabled, PayamSMS: enabled, Bot: enabled}),
+	})
+	t.Cleanup(func() { httpclient.Configure(httpclient.Config{BreakerThreshold: 5, BreakerCooldown: time.Second}) })
+}
+
+func postJSON(t *testing.T, client *http.Client, url string, body any, out any) {
+	t.Helper()
+	b, _ := json.Marshal(body)
+	resp, err := client.Post(url, "application/json", bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAtipayVerifiesTokenizedAmount(t *testing.T) {
+	configureMocks(t)
+	client := httpclient.New("atipay", time.Second)
+
+	var token struct{ Status, Token string }
+	postJSON(t, client, "https://mipg.atipay.net/v1/get-token", map[string]any{"amount": 1_000_000, "invoiceNumber": "INV-1"}, &token)
+	if token.Status != "1" || token.Token != "mock-token-INV-1" {
+		t.Fatalf("get-token = %+v", token)
+	}
+
+	var verified struct{ Amount float64 }
+	postJSON(t, client, "https://mipg.atipay.net/v1/verify-payment", map[string]any{"referenceNumber": providermock.AtipayReferencePrefix + "INV-1"}, &verified)
+	if verified.Amount != 1_000_000 {
+		t.Fatalf("verified amount = %v, want 1000000", verified.Amount)
+	}
+}
+
+func TestOxapayInvoiceReportsPaid(t *testing.T) {
+	configureMocks(t)
+	client := services.NewOxapayClient("https://api.oxapay.com", "mock", time.Second)
+	ctx := context.Background()
+
+	invoice, err := client.CreateInvoice(ctx, services.OxapayInvoiceInput{FiatAmountToman: 600_000, Label: "order-7"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if invoice.TrackID != "mock-order-7" || invoice.AmountUSDT != 10 {
+		t.Fatalf("invoice = %+v", invoice)
+	}
+	info, err := client.GetPaymentInfo(ctx, invoice.TrackID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Status != "paid" || info.Amount != 10 || len(info.Txs) != 1 {
+		t.Fatalf("payment info = %+v", info)
+	}
+}
+
+func TestPayamSMSAcceptsMessages(t *testing.T) {
+	configureMocks(t)
+	svc := services.NewPayamSMSService(&config.SMSConfig{SourceNumber: "3000", Timeout: time.Second}, &config.PayamSMSConfig{})
+	if err := svc.SendBulk(context.Background(), []string{"989121234567", "989121234568"}, "hello", nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBotAllocatesSequentialCodes(t *testing.T) {
+	configureMocks(t)
+	client := scheduler.NewBotClient(config.BotConfig{Username: "mock", Password: "mock", APIDomain: "https://jazebeh.ir"})
+	ctx := context.Background()
+
+	token, err := client.Login(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := &dto.BotAllocateShortLinksRequest{CampaignID: 3, Items: []dto.PhoneWithAdLink{{Phone: "1"}, {Phone: "2"}}, ShortLinkDomain: "j.ir"}
+	first, err := client.AllocateShortLinks(ctx, token, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := client.AllocateShortLinks(ctx, token, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(first, []string{"m3x1", "m3x2"}) || !slices.Equal(second, []string{"m3x3", "m3x4"}) {
+		t.Fatalf("codes = %v then %v", first, second)
+	}
+	ready, err := client.ListReadyCampaigns(ctx, token, "sms")
+	if err != nil || len(ready) != 0 {
+		t.Fatalf("ready campaigns = %v, %v", ready, err)
+	}
+}
+
+func TestBotHonoursRecordedContract(t *testing.T) {
+	bot := providermock.NewBot()
+	for _, i := range botcontract.Interactions() {
+		var body io.Reader = http.NoBody
+		if len(i.Request) > 0 {
+			body = bytes.NewReader(i.Request)
+		}
+		req := httptest.NewRequest(i.Method, "https://jazebeh.ir"+i.Path, body)
+		req.Header.Set("Authorization", "Bearer mock-bot-token")
+		rec := httptest.NewRecorder()
+		bot.ServeHTTP(rec, req)
+		if rec.Code/100 != 2 {
+			t.Errorf("%s: status %d: %s", i.Name, rec.Code, rec.Body)
+			continue
+		}
+		if err := botcontract.Conforms(i.Response, rec.Body.Bytes()); err != nil {
+			t.Errorf("%s: %v", i.Name, err)
+		}
+	}
+}
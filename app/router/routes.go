@@ -2,14 +2,14 @@
 package router
 
 import (
-	"crypto/rand"
-	"encoding/hex"
 	"encoding/json"
 	"log"
+	"net/http"
 	"os"
 	"strings"
 	"time"
 
+	"github.com/amirphl/Yamata-no-Orochi/app/apierror"
 	"github.com/amirphl/Yamata-no-Orochi/app/dto"
 	"github.com/amirphl/Yamata-no-Orochi/app/handlers"
 	"github.com/amirphl/Yamata-no-Orochi/app/middleware"
@@ -25,7 +25,6 @@ import (
 	"github.com/gofiber/fiber/v3/middleware/limiter"
 	"github.com/gofiber/fiber/v3/middleware/logger"
 	"github.com/gofiber/fiber/v3/middleware/recover"
-	"github.com/gofiber/fiber/v3/middleware/requestid"
 )
 
 // Router interface for HTTP routing
@@ -37,38 +36,76 @@ type Router interface {
 
 // FiberRouter implements Router using Fiber v3
 type FiberRouter struct {
-	app                            *fiber.App
-	authHandler                    handlers.AuthHandlerInterface
-	bundleHandler                  handlers.BundleHandlerInterface
-	campaignHandler                handlers.CampaignHandlerInterface
-	paymentHandler                 handlers.PaymentHandlerInterface
-	paymentAdminHandler            handlers.PaymentAdminHandlerInterface
-	agencyHandler                  handlers.AgencyHandlerInterface
-	authMiddleware                 *middleware.AuthMiddleware
-	authzMiddleware                *middleware.AuthorizationMiddleware
-	authAdminHandler               handlers.AuthAdminHandlerInterface
-	authBotHandler                 handlers.AuthBotHandlerInterface
-	campaignAdminHandler           handlers.CampaignAdminHandlerInterface
-	lineNumberHandler              handlers.LineNumberHandlerInterface
-	lineNumberAdminHandler         handlers.LineNumberAdminHandlerInterface
-	segmentPriceFactorAdminHandler handlers.SegmentPriceFactorAdminHandlerInterface
-	platformBasePriceAdminHandler  handlers.PlatformBasePriceAdminHandlerInterface
-	platformBasePriceHandler       handlers.PlatformBasePriceHandlerInterface
-	segmentPriceFactorHandler      handlers.SegmentPriceFactorHandlerInterface
-	adminCustomerManagementHandler handlers.AdminCustomerManagementHandlerInterface
-	campaignBotHandler             handlers.CampaignBotHandlerInterface
-	ticketHandler                  handlers.TicketHandlerInterface
-	shortLinkBotHandler            handlers.ShortLinkBotHandlerInterface
-	shortLinkHandler               handlers.ShortLinkHandlerInterface
-	shortLinkAdminHandler          handlers.ShortLinkAdminHandlerInterface
-	cryptoPaymentHandler           handlers.CryptoPaymentHandlerInterface
-	profileHandler                 handlers.ProfileHandlerInterface
-	multimediaHandler              handlers.MultimediaHandlerInterface
-	multimediaAdminHandler         handlers.MultimediaAdminHandlerInterface
-	multimediaBotHandler           handlers.MultimediaBotHandlerInterface
-	platformSettingsHandler        handlers.PlatformSettingsHandlerInterface
-	platformSettingsAdminHandler   handlers.PlatformSettingsAdminHandlerInterface
-	accessControlHandler           handlers.AccessControlHandlerInterface
+	app                              *fiber.App
+	authHandler                      handlers.AuthHandlerInterface
+	bundleHandler                    handlers.BundleHandlerInterface
+	campaignHandler                  handlers.CampaignHandlerInterface
+	paymentHandler                   handlers.PaymentHandlerInterface
+	paymentAdminHandler              handlers.PaymentAdminHandlerInterface
+	agencyHandler                    handlers.AgencyHandlerInterface
+	authMiddleware                   *middleware.AuthMiddleware
+	authzMiddleware                  *middleware.AuthorizationMiddleware
+	rateLimitMiddleware              *middleware.RateLimitMiddleware
+	adminIPAllowlist                 *middleware.IPAllowlistMiddleware
+	authAdminHandler                 handlers.AuthAdminHandlerInterface
+	authBotHandler                   handlers.AuthBotHandlerInterface
+	campaignAdminHandler             handlers.CampaignAdminHandlerInterface
+	lineNumberHandler                handlers.LineNumberHandlerInterface
+	lineNumberAdminHandler           handlers.LineNumberAdminHandlerInterface
+	segmentPriceFactorAdminHandler   handlers.SegmentPriceFactorAdminHandlerInterface
+	platformBasePriceAdminHandler    handlers.PlatformBasePriceAdminHandlerInterface
+	platformBasePriceHandler         handlers.PlatformBasePriceHandlerInterface
+	segmentPriceFactorHandler        handlers.SegmentPriceFactorHandlerInterface
+	smsTariffAdminHandler            handlers.SMSTariffAdminHandlerInterface
+	campaignTemplateHandler          handlers.CampaignTemplateHandlerInterface
+	adminCustomerManagementHandler   handlers.AdminCustomerManagementHandlerInterface
+	campaignBotHandler               handlers.CampaignBotHandlerInterface
+	ticketHandler                    handlers.TicketHandlerInterface
+	shortLinkBotHandler              handlers.ShortLinkBotHandlerInterface
+	shortLinkHandler                 handlers.ShortLinkHandlerInterface
+	shortLinkAdminHandler            handlers.ShortLinkAdminHandlerInterface
+	audienceImportAdminHandler       handlers.AudienceImportAdminHandlerInterface
+	jobAdminHandler                  handlers.JobAdminHandlerInterface
+	apiDocsAdminHandler              handlers.APIDocsAdminHandlerInterface
+	blacklistAdminHandler            handlers.BlacklistAdminHandlerInterface
+	atipayReconciliationAdminHandler handlers.AtipayReconciliationAdminHandlerInterface
+	walletAdjustmentAdminHandler     handlers.WalletAdjustmentAdminHandlerInterface
+	walletTransferHandler            handlers.WalletTransferHandlerInterface
+	walletTransferAdminHandler       handlers.WalletTransferAdminHandlerInterface
+	walletChargeHandler              handlers.WalletChargeHandlerInterface
+	frozenBudgetAuditAdminHandler    handlers.FrozenBudgetAuditAdminHandlerInterface
+	adminBulkOperationHandler        handlers.AdminBulkOperationHandlerInterface
+	postpaidBillingHandler           handlers.PostpaidBillingHandlerInterface
+	postpaidBillingAdminHandler      handlers.PostpaidBillingAdminHandlerInterface
+	taxInvoiceHandler                handlers.TaxInvoiceHandlerInterface
+	taxInvoiceAdminHandler           handlers.TaxInvoiceAdminHandlerInterface
+	adminReportHandler               handlers.AdminReportHandlerInterface
+	customerAnalyticsHandler         handlers.CustomerAnalyticsHandlerInterface
+	kycHandler                       handlers.KYCHandlerInterface
+	kycAdminHandler                  handlers.KYCAdminHandlerInterface
+	fileHandler                      handlers.FileHandlerInterface
+	cryptoPaymentHandler             handlers.CryptoPaymentHandlerInterface
+	profileHandler                   handlers.ProfileHandlerInterface
+	profileChangeAdminHandler        handlers.ProfileChangeAdminHandlerInterface
+	customerDataHandler              handlers.CustomerDataHandlerInterface
+	multimediaHandler                handlers.MultimediaHandlerInterface
+	multimediaAdminHandler           handlers.MultimediaAdminHandlerInterface
+	multimediaBotHandler             handlers.MultimediaBotHandlerInterface
+	platformSettingsHandler          handlers.PlatformSettingsHandlerInterface
+	platformSettingsAdminHandler     handlers.PlatformSettingsAdminHandlerInterface
+	accessControlHandler             handlers.AccessControlHandlerInterface
+	healthHandler                    handlers.HealthHandlerInterface
+	runtimeConfigAdminHandler        handlers.RuntimeConfigAdminHandlerInterface
+	sandboxHandler                   handlers.SandboxHandlerInterface
+	sandboxAdminHandler              handlers.SandboxAdminHandlerInterface
+	recordAdminHandler               handlers.RecordAdminHandlerInterface
+	campaignTimelineAdminHandler     handlers.CampaignTimelineAdminHandlerInterface
+	customerTimelineAdminHandler     handlers.CustomerTimelineAdminHandlerInterface
+	notificationHandler              handlers.NotificationHandlerInterface
+	telegramHandler                  handlers.TelegramHandlerInterface
+	pushHandler                      handlers.PushHandlerInterface
+	pushAdminHandler                 handlers.PushAdminHandlerInterface
+	serverCfg                        config.ServerConfig
 }
 
 // NewFiberRouter creates a new Fiber router
@@ -81,6 +118,8 @@ func NewFiberRouter(
 	agencyHandler handlers.AgencyHandlerInterface,
 	authMiddleware *middleware.AuthMiddleware,
 	authzMiddleware *middleware.AuthorizationMiddleware,
+	rateLimitMiddleware *middleware.RateLimitMiddleware,
+	adminIPAllowlist *middleware.IPAllowlistMiddleware,
 	authAdminHandler handlers.AuthAdminHandlerInterface,
 	authBotHandler handlers.AuthBotHandlerInterface,
 	campaignAdminHandler handlers.CampaignAdminHandlerInterface,
@@ -90,20 +129,55 @@ func NewFiberRouter(
 	platformBasePriceAdminHandler handlers.PlatformBasePriceAdminHandlerInterface,
 	platformBasePriceHandler handlers.PlatformBasePriceHandlerInterface,
 	segmentPriceFactorHandler handlers.SegmentPriceFactorHandlerInterface,
+	smsTariffAdminHandler handlers.SMSTariffAdminHandlerInterface,
+	campaignTemplateHandler handlers.CampaignTemplateHandlerInterface,
 	adminCustomerManagemetHandler handlers.AdminCustomerManagementHandlerInterface,
 	campaignBotHandler handlers.CampaignBotHandlerInterface,
 	ticketHandler handlers.TicketHandlerInterface,
 	shortLinkBotHandler handlers.ShortLinkBotHandlerInterface,
 	shortLinkHandler handlers.ShortLinkHandlerInterface,
 	shortLinkAdminHandler handlers.ShortLinkAdminHandlerInterface,
+	audienceImportAdminHandler handlers.AudienceImportAdminHandlerInterface,
+	jobAdminHandler handlers.JobAdminHandlerInterface,
+	apiDocsAdminHandler handlers.APIDocsAdminHandlerInterface,
+	blacklistAdminHandler handlers.BlacklistAdminHandlerInterface,
+	atipayReconciliationAdminHandler handlers.AtipayReconciliationAdminHandlerInterface,
+	walletAdjustmentAdminHandler handlers.WalletAdjustmentAdminHandlerInterface,
+	walletTransferHandler handlers.WalletTransferHandlerInterface,
+	walletTransferAdminHandler handlers.WalletTransferAdminHandlerInterface,
+	walletChargeHandler handlers.WalletChargeHandlerInterface,
+	frozenBudgetAuditAdminHandler handlers.FrozenBudgetAuditAdminHandlerInterface,
+	adminBulkOperationHandler handlers.AdminBulkOperationHandlerInterface,
+	postpaidBillingHandler handlers.PostpaidBillingHandlerInterface,
+	postpaidBillingAdminHandler handlers.PostpaidBillingAdminHandlerInterface,
+	taxInvoiceHandler handlers.TaxInvoiceHandlerInterface,
+	taxInvoiceAdminHandler handlers.TaxInvoiceAdminHandlerInterface,
+	adminReportHandler handlers.AdminReportHandlerInterface,
+	customerAnalyticsHandler handlers.CustomerAnalyticsHandlerInterface,
+	kycHandler handlers.KYCHandlerInterface,
+	kycAdminHandler handlers.KYCAdminHandlerInterface,
+	fileHandler handlers.FileHandlerInterface,
 	cryptoPaymentHandler handlers.CryptoPaymentHandlerInterface,
 	profileHandler handlers.ProfileHandlerInterface,
+	profileChangeAdminHandler handlers.ProfileChangeAdminHandlerInterface,
+	customerDataHandler handlers.CustomerDataHandlerInterface,
 	multimediaHandler handlers.MultimediaHandlerInterface,
 	multimediaAdminHandler handlers.MultimediaAdminHandlerInterface,
 	multimediaBotHandler handlers.MultimediaBotHandlerInterface,
 	platformSettingsHandler handlers.PlatformSettingsHandlerInterface,
 	platformSettingsAdminHandler handlers.PlatformSettingsAdminHandlerInterface,
 	accessControlHandler handlers.AccessControlHandlerInterface,
+	healthHandler handlers.HealthHandlerInterface,
+	runtimeConfigAdminHandler handlers.RuntimeConfigAdminHandlerInterface,
+	sandboxHandler handlers.SandboxHandlerInterface,
+	sandboxAdminHandler handlers.SandboxAdminHandlerInterface,
+	recordAdminHandler handlers.RecordAdminHandlerInterface,
+	campaignTimelineAdminHandler handlers.CampaignTimelineAdminHandlerInterface,
+	customerTimelineAdminHandler handlers.CustomerTimelineAdminHandlerInterface,
+	notificationHandler handlers.NotificationHandlerInterface,
+	telegramHandler handlers.TelegramHandlerInterface,
+	pushHandler handlers.PushHandlerInterface,
+	pushAdminHandler handlers.PushAdminHandlerInterface,
 	serverCfg config.ServerConfig,
 ) Router {
 	// Configure Fiber app
@@ -122,38 +196,76 @@ func NewFiberRouter(
 	})
 
 	return &FiberRouter{
-		app:                            app,
-		authHandler:                    authHandler,
-		bundleHandler:                  bundleHandler,
-		campaignHandler:                campaignHandler,
-		paymentHandler:                 paymentHandler,
-		paymentAdminHandler:            paymentAdminHandler,
-		agencyHandler:                  agencyHandler,
-		authMiddleware:                 authMiddleware,
-		authzMiddleware:                authzMiddleware,
-		authAdminHandler:               authAdminHandler,
-		authBotHandler:                 authBotHandler,
-		campaignAdminHandler:           campaignAdminHandler,
-		lineNumberHandler:              lineNumberHandler,
-		lineNumberAdminHandler:         lineNumberAdminHandler,
-		segmentPriceFactorAdminHandler: segmentPriceFactorAdminHandler,
-		platformBasePriceAdminHandler:  platformBasePriceAdminHandler,
-		platformBasePriceHandler:       platformBasePriceHandler,
-		segmentPriceFactorHandler:      segmentPriceFactorHandler,
-		adminCustomerManagementHandler: adminCustomerManagemetHandler,
-		campaignBotHandler:             campaignBotHandler,
-		ticketHandler:                  ticketHandler,
-		shortLinkBotHandler:            shortLinkBotHandler,
-		shortLinkHandler:               shortLinkHandler,
-		shortLinkAdminHandler:          shortLinkAdminHandler,
-		cryptoPaymentHandler:           cryptoPaymentHandler,
-		profileHandler:                 profileHandler,
-		multimediaHandler:              multimediaHandler,
-		multimediaAdminHandler:         multimediaAdminHandler,
-		multimediaBotHandler:           multimediaBotHandler,
-		platformSettingsHandler:        platformSettingsHandler,
-		platformSettingsAdminHandler:   platformSettingsAdminHandler,
-		accessControlHandler:           accessControlHandler,
+		app:                              app,
+		authHandler:                      authHandler,
+		bundleHandler:                    bundleHandler,
+		campaignHandler:                  campaignHandler,
+		paymentHandler:                   paymentHandler,
+		paymentAdminHandler:              paymentAdminHandler,
+		agencyHandler:                    agencyHandler,
+		authMiddleware:                   authMiddleware,
+		authzMiddleware:                  authzMiddleware,
+		rateLimitMiddleware:              rateLimitMiddleware,
+		adminIPAllowlist:                 adminIPAllowlist,
+		authAdminHandler:                 authAdminHandler,
+		authBotHandler:                   authBotHandler,
+		campaignAdminHandler:             campaignAdminHandler,
+		lineNumberHandler:                lineNumberHandler,
+		lineNumberAdminHandler:           lineNumberAdminHandler,
+		segmentPriceFactorAdminHandler:   segmentPriceFactorAdminHandler,
+		platformBasePriceAdminHandler:    platformBasePriceAdminHandler,
+		platformBasePriceHandler:         platformBasePriceHandler,
+		segmentPriceFactorHandler:        segmentPriceFactorHandler,
+		smsTariffAdminHandler:            smsTariffAdminHandler,
+		campaignTemplateHandler:          campaignTemplateHandler,
+		adminCustomerManagementHandler:   adminCustomerManagemetHandler,
+		campaignBotHandler:               campaignBotHandler,
+		ticketHandler:                    ticketHandler,
+		shortLinkBotHandler:              shortLinkBotHandler,
+		shortLinkHandler:                 shortLinkHandler,
+		shortLinkAdminHandler:            shortLinkAdminHandler,
+		audienceImportAdminHandler:       audienceImportAdminHandler,
+		jobAdminHandler:                  jobAdminHandler,
+		apiDocsAdminHandler:              apiDocsAdminHandler,
+		blacklistAdminHandler:            blacklistAdminHandler,
+		atipayReconciliationAdminHandler: atipayReconciliationAdminHandler,
+		walletAdjustmentAdminHandler:     walletAdjustmentAdminHandler,
+		walletTransferHandler:            walletTransferHandler,
+		walletTransferAdminHandler:       walletTransferAdminHandler,
+		walletChargeHandler:              walletChargeHandler,
+		frozenBudgetAuditAdminHandler:    frozenBudgetAuditAdminHandler,
+		adminBulkOperationHandler:        adminBulkOperationHandler,
+		postpaidBillingHandler:           postpaidBillingHandler,
+		postpaidBillingAdminHandler:      postpaidBillingAdminHandler,
+		taxInvoiceHandler:                taxInvoiceHandler,
+		taxInvoiceAdminHandler:           taxInvoiceAdminHandler,
+		adminReportHandler:               adminReportHandler,
+		customerAnalyticsHandler:         customerAnalyticsHandler,
+		kycHandler:                       kycHandler,
+		kycAdminHandler:                  kycAdminHandler,
+		fileHandler:                      fileHandler,
+		cryptoPaymentHandler:             cryptoPaymentHandler,
+		profileHandler:                   profileHandler,
+		profileChangeAdminHandler:        profileChangeAdminHandler,
+		customerDataHandler:              customerDataHandler,
+		multimediaHandler:                multimediaHandler,
+		multimediaAdminHandler:           multimediaAdminHandler,
+		multimediaBotHandler:             multimediaBotHandler,
+		platformSettingsHandler:          platformSettingsHandler,
+		platformSettingsAdminHandler:     platformSettingsAdminHandler,
+		accessControlHandler:             accessControlHandler,
+		healthHandler:                    healthHandler,
+		runtimeConfigAdminHandler:        runtimeConfigAdminHandler,
+		sandboxHandler:                   sandboxHandler,
+		sandboxAdminHandler:              sandboxAdminHandler,
+		recordAdminHandler:               recordAdminHandler,
+		campaignTimelineAdminHandler:     campaignTimelineAdminHandler,
+		customerTimelineAdminHandler:     customerTimelineAdminHandler,
+		notificationHandler:              notificationHandler,
+		telegramHandler:                  telegramHandler,
+		pushHandler:                      pushHandler,
+		pushAdminHandler:                 pushAdminHandler,
+		serverCfg:                        serverCfg,
 	}
 }
 
@@ -161,14 +273,27 @@ func NewFiberRouter(
 func (r *FiberRouter) SetupRoutes() {
 	log.Println("Setting up routes...")
 
+	// Probes are registered ahead of the global middleware so they are never
+	// cached, logged, rate limited or reported to Sentry
+	r.app.Get("/healthz", r.healthHandler.Liveness)
+	r.app.Get("/readyz", r.healthHandler.Readiness)
+	r.app.Get("/startupz", r.healthHandler.Startup)
+
 	// Global middleware
 	r.setupMiddleware()
 
-	// API routes
-	api := r.app.Group("/api/v1")
+	// API routes. Each version is its own group; the handlers behind them
+	// share the business flows, and v1 announces its deprecation once
+	// API_V1_DEPRECATED_AT is set.
+	api := r.app.Group("/api/v1",
+		middleware.APIVersion("v1"),
+		middleware.Deprecation("v1", r.serverCfg.V1DeprecatedAt, r.serverCfg.V1SunsetAt, "/api/v2"),
+	)
+	v2 := r.app.Group("/api/v2", middleware.APIVersion("v2"))
 
 	// Health check route (no rate limiting)
 	api.Get("/health", r.healthCheck)
+	api.Get("/status", r.healthHandler.Status)
 
 	// API documentation route (development only)
 	if os.Getenv("APP_ENV") == "development" || os.Getenv("APP_ENV") == "local" {
@@ -186,74 +311,64 @@ func (r *FiberRouter) SetupRoutes() {
 		log.Println("API documentation enabled for development")
 	}
 
-	// Apply general rate limiting to all API routes (aligned with nginx)
-	api.Use(limiter.New(limiter.Config{
+	// Apply general rate limiting to all API routes (aligned with nginx).
+	// Both versions share one limiter so moving to v2 does not double the budget.
+	apiLimiter := limiter.New(limiter.Config{
 		Max:        2000,            // Maximum 2000 requests (matches nginx api zone)
 		Expiration: 1 * time.Minute, // Per minute
 		KeyGenerator: func(c fiber.Ctx) string {
 			return c.IP() // Rate limit by IP
 		},
 		LimitReached: func(c fiber.Ctx) error {
-			return c.Status(fiber.StatusTooManyRequests).JSON(dto.APIResponse{
-				Success: false,
-				Message: "Too many requests. Please try again later.",
-				Error: dto.ErrorDetail{
-					Code: "RATE_LIMIT_EXCEEDED",
-				},
-			})
+			return apierror.Respond(c, fiber.StatusTooManyRequests, "Too many requests. Please try again later.", "RATE_LIMIT_EXCEEDED", nil)
 		},
 		Next: func(c fiber.Ctx) bool {
 			// Skip rate limiting for health checks
-			return c.Path() == "/api/v1/health"
+			return isHealthPath(c.Path())
 		},
-	}))
+	})
+	api.Use(apiLimiter)
+
+	r.setupV2Routes(v2, apiLimiter)
 
 	// Auth routes with stricter rate limiting
 	auth := api.Group("/auth")
 
-	// Apply stricter rate limiting to auth endpoints (aligned with nginx)
-	auth.Use(limiter.New(limiter.Config{
-		Max:        20,              // Maximum 20 requests (matches nginx auth zone)
-		Expiration: 1 * time.Minute, // Per minute
-		KeyGenerator: func(c fiber.Ctx) string {
-			return c.IP() // Rate limit by IP
-		},
-		LimitReached: func(c fiber.Ctx) error {
-			return c.Status(fiber.StatusTooManyRequests).JSON(dto.APIResponse{
-				Success: false,
-				Message: "Too many requests. Please try again later.",
-				Error: dto.ErrorDetail{
-					Code: "RATE_LIMIT_EXCEEDED",
-				},
-			})
-		},
-	}))
+	// Apply stricter per-IP rate limiting to auth endpoints
+	auth.Use(r.rateLimitMiddleware.Auth())
 
 	// Auth endpoints
-	auth.Post("/signup", r.authHandler.Signup)
+	auth.Post("/signup", r.rateLimitMiddleware.OTP(), r.authHandler.Signup)
 	auth.Post("/verify", r.authHandler.VerifyOTP)
-	auth.Post("/resend-otp", r.authHandler.ResendOTP)
+	auth.Post("/resend-otp", r.rateLimitMiddleware.OTP(), r.authHandler.ResendOTP)
 	auth.Post("/login", r.authHandler.Login)
-	auth.Post("/login/otp", r.authHandler.RequestLoginOTP)
-	auth.Post("/forgot-password", r.authHandler.ForgotPassword)
+	auth.Post("/login/otp", r.rateLimitMiddleware.OTP(), r.authHandler.RequestLoginOTP)
+	auth.Post("/passwordless/otp", r.rateLimitMiddleware.OTP(), r.authHandler.RequestPasswordlessLoginOTP)
+	auth.Post("/passwordless/login", r.authHandler.PasswordlessLogin)
+	auth.Post("/forgot-password", r.rateLimitMiddleware.OTP(), r.authHandler.ForgotPassword)
+	auth.Post("/otp/resend", r.rateLimitMiddleware.OTP(), r.authHandler.ResendLoginOTP)
 	auth.Post("/reset", r.authHandler.ResetPassword)
+	// Support staff impersonating a customer cannot, on the routes marked
+	// with noImpersonation, change its credentials or payout Sheba, move its
+	// money, hand its account to an agency, redirect its notifications, or
+	// export or delete its data
+	noImpersonation := middleware.DenyImpersonation()
+	auth.Put("/password", r.authMiddleware.Authenticate(), noImpersonation, r.authHandler.ChangePassword)
+	auth.Post("/mobile/otp", r.authMiddleware.Authenticate(), noImpersonation, r.rateLimitMiddleware.OTP(), r.authHandler.RequestMobileChange)
+	auth.Put("/mobile", r.authMiddleware.Authenticate(), noImpersonation, r.authHandler.ConfirmMobileChange)
+	auth.Post("/logout", r.authMiddleware.Authenticate(), r.authHandler.Logout)
+	auth.Get("/sessions", r.authMiddleware.Authenticate(), r.authHandler.ListSessions)
+	auth.Delete("/sessions/:id", r.authMiddleware.Authenticate(), r.authHandler.RevokeSession)
+	auth.Post("/login-alerts/not-me", r.authHandler.ReportLoginNotMe)
+
+	// Every admin route, login included, is limited to the admin IP allowlist
+	api.Use("/admin", r.adminIPAllowlist.Allow())
 
 	// Admin auth routes (separate group; can have separate rate limit if needed)
 	adminAuth := api.Group("/admin/auth")
-	adminAuth.Use(limiter.New(limiter.Config{
-		Max:          20,
-		Expiration:   1 * time.Minute,
-		KeyGenerator: func(c fiber.Ctx) string { return c.IP() },
-		LimitReached: func(c fiber.Ctx) error {
-			return c.Status(fiber.StatusTooManyRequests).JSON(dto.APIResponse{
-				Success: false,
-				Message: "Too many requests. Please try again later.",
-				Error:   dto.ErrorDetail{Code: "RATE_LIMIT_EXCEEDED"},
-			})
-		},
-	}))
+	adminAuth.Use(r.rateLimitMiddleware.Auth())
 	adminAuth.Get("/captcha/init", r.authAdminHandler.InitCaptcha)
-	adminAuth.Post("/login", r.authAdminHandler.VerifyLogin)
+	adminAuth.Post("/login", r.rateLimitMiddleware.OTP(), r.authAdminHandler.VerifyLogin)
 	adminAuth.Post("/login/verify-otp", r.authAdminHandler.VerifyLoginOTP)
 
 	// Bot auth
@@ -263,24 +378,46 @@ func (r *FiberRouter) SetupRoutes() {
 	// Campaign routes (protected with authentication)
 	campaigns := api.Group("/campaigns")
 	campaigns.Use(r.authMiddleware.Authenticate()) // Require authentication
-	campaigns.Post("/", r.campaignHandler.CreateCampaign)
-	campaigns.Put("/:uuid", r.campaignHandler.UpdateCampaign)
-	campaigns.Get("/", r.campaignHandler.ListCampaigns)
-	campaigns.Post("/:uuid/clone", r.campaignHandler.CloneCampaign)
+	// Agencies may act for referred customers who delegated to them on
+	// the routes marked with actAs
+	actAs := middleware.ActAsCustomer()
+	campaigns.Post("/", actAs, r.campaignHandler.CreateCampaign)
+	campaigns.Post("/bulk", actAs, r.campaignHandler.BulkCreateCampaigns)
+	campaigns.Put("/:uuid", actAs, r.campaignHandler.UpdateCampaign)
+	campaigns.Get("/", actAs, r.campaignHandler.ListCampaigns)
+	campaigns.Post("/:uuid/clone", actAs, r.campaignHandler.CloneCampaign)
 	campaigns.Post("/:uuid/test-send", r.campaignHandler.SendCampaignTestMessage)
 	campaigns.Post("/calculate-capacity", r.campaignHandler.CalculateCampaignCapacity)
 	campaigns.Post("/calculate-cost", r.campaignHandler.CalculateCampaignCost)
 	campaigns.Post("/calculate-cost-v2", r.campaignHandler.CalculateCampaignCostV2)
+	campaigns.Post("/estimate", r.campaignHandler.EstimateCampaign)
+	campaigns.Post("/validate-content", r.campaignHandler.ValidateCampaignContent)
 	campaigns.Get("/page-prices", r.campaignHandler.GetPagePrices)
 	campaigns.Get("/audience-spec", r.campaignHandler.ListAudienceSpec)
 	campaigns.Get("/summary", r.campaignHandler.GetApprovedRunningSummary)
+	campaigns.Get("/sending-quota", r.campaignHandler.GetSendingQuotaUsage)
 	campaigns.Get("/initiated/last", r.campaignHandler.GetLastInitiatedCampaign)
 	campaigns.Get("/:id/export", r.campaignHandler.ExportCampaignReport)
 	campaigns.Get("/:uuid/click-report", r.campaignHandler.ExportCampaignClickReport)
-	campaigns.Post("/:id/cancel", r.campaignHandler.CancelCampaign)
+	campaigns.Get("/:uuid/variant-stats", r.campaignHandler.GetCampaignVariantStats)
+	campaigns.Get("/:uuid/operator-stats", r.campaignHandler.GetCampaignOperatorStats)
+	campaigns.Get("/:uuid/retry-stats", r.campaignHandler.GetCampaignRetryStats)
+	campaigns.Get("/:uuid/reviews", r.campaignHandler.ListCampaignReviews)
+	campaigns.Post("/:uuid/reviews", r.campaignHandler.AddCampaignReviewComment)
+	campaigns.Post("/:id/cancel", actAs, r.campaignHandler.CancelCampaign)
+	campaigns.Post("/:id/pause", actAs, r.campaignHandler.PauseCampaign)
+	campaigns.Post("/:id/resume", actAs, r.campaignHandler.ResumeCampaign)
 	campaigns.Post("/hide", r.campaignHandler.HideCampaigns)
 	campaigns.Post("/unhide", r.campaignHandler.UnhideCampaigns)
 
+	// Campaign template routes (protected with authentication)
+	campaignTemplates := api.Group("/campaign-templates")
+	campaignTemplates.Use(r.authMiddleware.Authenticate())
+	campaignTemplates.Post("/", r.campaignTemplateHandler.CreateTemplate)
+	campaignTemplates.Get("/", r.campaignTemplateHandler.ListTemplates)
+	campaignTemplates.Delete("/:uuid", r.campaignTemplateHandler.DeleteTemplate)
+	campaignTemplates.Post("/:uuid/campaigns", r.campaignTemplateHandler.CreateCampaignFromTemplate)
+
 	// Bundle routes (protected with authentication)
 	bundles := api.Group("/bundles")
 	bundles.Use(r.authMiddleware.Authenticate())
@@ -302,11 +439,24 @@ func (r *FiberRouter) SetupRoutes() {
 	adminCampaigns.Get("/:id", r.campaignAdminHandler.GetCampaign)
 	adminCampaigns.Post("/approve", r.campaignAdminHandler.ApproveCampaign)
 	adminCampaigns.Post("/reject", r.campaignAdminHandler.RejectCampaign)
+	adminCampaigns.Post("/request-changes", r.campaignAdminHandler.RequestCampaignChanges)
+	adminCampaigns.Get("/:id/reviews", r.campaignAdminHandler.ListCampaignReviews)
+	adminCampaigns.Post("/:id/reviews", r.campaignAdminHandler.AddCampaignReviewComment)
+	adminCampaigns.Get("/:id/timeline", r.campaignTimelineAdminHandler.GetCampaignTimeline)
 	adminCampaigns.Post("/reschedule", r.campaignAdminHandler.RescheduleCampaign)
 	adminCampaigns.Post("/cancel", r.campaignAdminHandler.CancelCampaign)
 	adminCampaigns.Delete("/audience-spec", r.campaignAdminHandler.RemoveAudienceSpec)
 	adminCampaigns.Put("/page-prices", r.campaignAdminHandler.UpdatePagePrice)
 
+	// Admin global campaign templates
+	adminCampaignTemplates := api.Group("/admin/campaign-templates")
+	adminCampaignTemplates.Use(r.authMiddleware.AdminAuthenticate())
+	adminCampaignTemplates.Use(func(c fiber.Ctx) error { return middleware.RequireAdminAuth(c) })
+	adminCampaignTemplates.Use(r.authzMiddleware.AdminAuthorize())
+	adminCampaignTemplates.Post("/", r.campaignTemplateHandler.AdminPublishTemplate)
+	adminCampaignTemplates.Get("/", r.campaignTemplateHandler.AdminListTemplates)
+	adminCampaignTemplates.Delete("/:uuid", r.campaignTemplateHandler.AdminDeleteTemplate)
+
 	// Admin segment price factors
 	adminSegmentPF := api.Group("/admin/segment-price-factors")
 	adminSegmentPF.Use(r.authMiddleware.AdminAuthenticate())
@@ -316,6 +466,16 @@ func (r *FiberRouter) SetupRoutes() {
 	adminSegmentPF.Get("/", r.segmentPriceFactorAdminHandler.ListSegmentPriceFactors)
 	adminSegmentPF.Get("/level3-options", r.segmentPriceFactorAdminHandler.ListLevel3Options)
 
+	// Admin SMS tariffs
+	adminSMSTariffs := api.Group("/admin/sms-tariffs")
+	adminSMSTariffs.Use(r.authMiddleware.AdminAuthenticate())
+	adminSMSTariffs.Use(func(c fiber.Ctx) error { return middleware.RequireAdminAuth(c) })
+	adminSMSTariffs.Use(r.authzMiddleware.AdminAuthorize())
+	adminSMSTariffs.Post("/", r.smsTariffAdminHandler.CreateSMSTariff)
+	adminSMSTariffs.Get("/", r.smsTariffAdminHandler.ListSMSTariffs)
+	adminSMSTariffs.Put("/:uuid", r.smsTariffAdminHandler.UpdateSMSTariff)
+	adminSMSTariffs.Delete("/:uuid", r.smsTariffAdminHandler.DeleteSMSTariff)
+
 	// Admin platform base prices
 	adminPlatformBasePrice := api.Group("/admin/platform-base-prices")
 	adminPlatformBasePrice.Use(r.authMiddleware.AdminAuthenticate())
@@ -367,6 +527,42 @@ func (r *FiberRouter) SetupRoutes() {
 	adminShortLinks.Post("/download-with-clicks-range", r.shortLinkAdminHandler.DownloadWithClicksByScenarioRange)
 	adminShortLinks.Post("/download-with-clicks-by-scenario-name", r.shortLinkAdminHandler.DownloadWithClicksByScenarioNameExcel)
 
+	// Admin bulk audience imports
+	adminAudienceImports := api.Group("/admin/audience-imports")
+	adminAudienceImports.Use(r.authMiddleware.AdminAuthenticate())
+	adminAudienceImports.Use(func(c fiber.Ctx) error { return middleware.RequireAdminAuth(c) })
+	adminAudienceImports.Use(r.authzMiddleware.AdminAuthorize())
+	adminAudienceImports.Post("/", r.audienceImportAdminHandler.UploadCSV)
+	adminAudienceImports.Get("/:uuid", r.audienceImportAdminHandler.GetImport)
+
+	// Admin background jobs
+	adminJobs := api.Group("/admin/jobs")
+	adminJobs.Use(r.authMiddleware.AdminAuthenticate())
+	adminJobs.Use(func(c fiber.Ctx) error { return middleware.RequireAdminAuth(c) })
+	adminJobs.Use(r.authzMiddleware.AdminAuthorize())
+	adminJobs.Get("/", r.jobAdminHandler.List)
+	adminJobs.Get("/:uuid", r.jobAdminHandler.Get)
+	adminJobs.Post("/:uuid/requeue", r.jobAdminHandler.Requeue)
+	adminJobs.Post("/:uuid/cancel", r.jobAdminHandler.Cancel)
+
+	// Admin API docs. The Swagger UI page holds no data, so it is only behind
+	// the admin IP allowlist; the document it loads needs docs:read.
+	api.Get("/admin/docs", r.apiDocsAdminHandler.UI)
+	adminDocs := api.Group("/admin/docs")
+	adminDocs.Use(r.authMiddleware.AdminAuthenticate())
+	adminDocs.Use(func(c fiber.Ctx) error { return middleware.RequireAdminAuth(c) })
+	adminDocs.Use(r.authzMiddleware.AdminAuthorize())
+	adminDocs.Get("/openapi.json", r.apiDocsAdminHandler.OpenAPI)
+
+	// Admin recipient blacklist
+	adminBlacklist := api.Group("/admin/blacklist")
+	adminBlacklist.Use(r.authMiddleware.AdminAuthenticate())
+	adminBlacklist.Use(func(c fiber.Ctx) error { return middleware.RequireAdminAuth(c) })
+	adminBlacklist.Use(r.authzMiddleware.AdminAuthorize())
+	adminBlacklist.Post("/", r.blacklistAdminHandler.UploadCSV)
+	adminBlacklist.Get("/", r.blacklistAdminHandler.List)
+	adminBlacklist.Delete("/:phone_number", r.blacklistAdminHandler.Delete)
+
 	// Admin customer reports
 	adminCustomers := api.Group("/admin/customer-management")
 	adminCustomers.Use(r.authMiddleware.AdminAuthenticate())
@@ -374,14 +570,48 @@ func (r *FiberRouter) SetupRoutes() {
 	adminCustomers.Use(r.authzMiddleware.AdminAuthorize())
 	adminCustomers.Get("/", r.adminCustomerManagementHandler.ListCustomers)
 	adminCustomers.Get("/shares", r.adminCustomerManagementHandler.GetCustomersShares)
+	adminCustomers.Get("/search", r.adminCustomerManagementHandler.SearchCustomers)
 	adminCustomers.Get("/:customer_id", r.adminCustomerManagementHandler.GetCustomerWithCampaigns)
 	adminCustomers.Post("/active-status", r.adminCustomerManagementHandler.SetCustomerActiveStatus)
 	adminCustomers.Get("/:customer_id/discounts", r.adminCustomerManagementHandler.GetCustomerDiscountsHistory)
+	adminCustomers.Get("/:customer_id/sending-quota", r.adminCustomerManagementHandler.GetCustomerSendingQuota)
+	adminCustomers.Put("/:customer_id/sending-quota", r.adminCustomerManagementHandler.SetCustomerSendingQuota)
+	adminCustomers.Post("/:customer_id/force-logout", r.adminCustomerManagementHandler.ForceLogoutCustomer)
+	adminCustomers.Put("/:customer_id/sandbox", r.sandboxAdminHandler.SetCustomerSandbox)
+	adminCustomers.Put("/:customer_id/suspension", r.adminCustomerManagementHandler.SetCustomerSuspension)
+
+	// Admin soft delete and restore
+	adminRecords := api.Group("/admin/records")
+	adminRecords.Use(r.authMiddleware.AdminAuthenticate())
+	adminRecords.Use(func(c fiber.Ctx) error { return middleware.RequireAdminAuth(c) })
+	adminRecords.Use(r.authzMiddleware.AdminAuthorize())
+	adminRecords.Delete("/:kind/:id", r.recordAdminHandler.DeleteRecord)
+	adminRecords.Post("/:kind/:id/restore", r.recordAdminHandler.RestoreRecord)
+
+	// Admin impersonation and activity timeline of customers
+	adminImpersonation := api.Group("/admin/customers")
+	adminImpersonation.Use(r.authMiddleware.AdminAuthenticate())
+	adminImpersonation.Use(func(c fiber.Ctx) error { return middleware.RequireAdminAuth(c) })
+	adminImpersonation.Use(r.authzMiddleware.AdminAuthorize())
+	adminImpersonation.Post("/:id/impersonate", r.adminCustomerManagementHandler.ImpersonateCustomer)
+	adminImpersonation.Get("/:id/timeline", r.customerTimelineAdminHandler.GetCustomerTimeline)
+
+	// Admin runtime configuration
+	adminConfig := api.Group("/admin/config")
+	adminConfig.Use(r.authMiddleware.AdminAuthenticate())
+	adminConfig.Use(func(c fiber.Ctx) error { return middleware.RequireAdminAuth(c) })
+	adminConfig.Use(r.authzMiddleware.AdminAuthorize())
+	adminConfig.Get("/", r.runtimeConfigAdminHandler.Get)
+	adminConfig.Post("/reload", r.runtimeConfigAdminHandler.Reload)
 
 	// Line numbers
 	lineNumbers := api.Group("/line-numbers")
 	lineNumbers.Use(r.authMiddleware.Authenticate()) // Require authentication
 	lineNumbers.Get("/active", r.lineNumberHandler.ListActive)
+	lineNumbers.Get("/available", r.lineNumberHandler.ListAvailable)
+	lineNumbers.Get("/reservations", r.lineNumberHandler.ListReservations)
+	lineNumbers.Post("/reservations", noImpersonation, r.lineNumberHandler.Reserve)
+	lineNumbers.Delete("/reservations/:uuid", r.lineNumberHandler.ReleaseReservation)
 
 	// Segment price factors (authenticated)
 	segmentPriceFactors := api.Group("/segment-price-factors")
@@ -397,6 +627,9 @@ func (r *FiberRouter) SetupRoutes() {
 	adminLineNumbers.Post("/", r.lineNumberAdminHandler.CreateLineNumber)
 	adminLineNumbers.Put("/", r.lineNumberAdminHandler.UpdateLineNumbersBatch)
 	adminLineNumbers.Get("/report", r.lineNumberAdminHandler.GetLineNumbersReport)
+	adminLineNumbers.Get("/tiers", r.lineNumberAdminHandler.ListTiers)
+	adminLineNumbers.Post("/tiers", r.lineNumberAdminHandler.CreateTier)
+	adminLineNumbers.Put("/tiers/:id", r.lineNumberAdminHandler.UpdateTier)
 
 	// Tickets
 	tickets := api.Group("/tickets")
@@ -418,18 +651,23 @@ func (r *FiberRouter) SetupRoutes() {
 	wallet := api.Group("/wallet")
 	wallet.Use(r.authMiddleware.Authenticate()) // Require authentication
 	wallet.Get("/balance", r.paymentHandler.GetWalletBalance)
+	wallet.Post("/transfers", noImpersonation, r.rateLimitMiddleware.OTP(), r.walletTransferHandler.Initiate)
+	wallet.Get("/transfers", r.walletTransferHandler.List)
+	wallet.Post("/transfers/:uuid/confirm", noImpersonation, r.walletTransferHandler.Confirm)
+	wallet.Post("/charge", noImpersonation, r.rateLimitMiddleware.Payment(), r.walletChargeHandler.Charge)
+	wallet.Get("/charges", r.walletChargeHandler.ListRecent)
 
 	// Payment routes
 	payments := api.Group("/payments")
 	// Charge wallet endpoint (protected with authentication)
-	payments.Post("/charge-wallet", r.authMiddleware.Authenticate(), r.paymentHandler.ChargeWallet)
+	payments.Post("/charge-wallet", r.authMiddleware.Authenticate(), noImpersonation, r.rateLimitMiddleware.Payment(), r.paymentHandler.ChargeWallet)
 	// Payment callback endpoint (unprotected - called by Atipay)
 	payments.Post("/callback/:invoice_number", r.paymentHandler.PaymentCallback)
 	// Transaction history endpoint (protected with authentication)
 	payments.Get("/history", r.authMiddleware.Authenticate(), r.paymentHandler.GetTransactionHistory)
 	// Deposit receipt submission & listing
-	payments.Post("/deposit-receipts", r.authMiddleware.Authenticate(), r.paymentHandler.SubmitDepositReceipt)
-	payments.Post("/transactions/invoice-issue-request", r.authMiddleware.Authenticate(), r.paymentHandler.NotifyInvoiceIssueRequest)
+	payments.Post("/deposit-receipts", r.authMiddleware.Authenticate(), noImpersonation, r.rateLimitMiddleware.Payment(), r.paymentHandler.SubmitDepositReceipt)
+	payments.Post("/transactions/invoice-issue-request", r.authMiddleware.Authenticate(), r.rateLimitMiddleware.Payment(), r.paymentHandler.NotifyInvoiceIssueRequest)
 	payments.Get("/deposit-receipts", r.authMiddleware.Authenticate(), r.paymentHandler.ListDepositReceipts)
 	// Proforma invoice preview
 	payments.Get("/proforma/preview", r.authMiddleware.Authenticate(), r.paymentHandler.PreviewProformaInvoice)
@@ -437,8 +675,43 @@ func (r *FiberRouter) SetupRoutes() {
 	// Receipt file download
 	payments.Get("/deposit-receipts/:receipt_uuid/file", r.authMiddleware.Authenticate(), r.paymentHandler.DownloadDepositReceiptFile)
 	// Receipt file update/delete
-	payments.Put("/deposit-receipts/:receipt_uuid/file", r.authMiddleware.Authenticate(), r.paymentHandler.UpdateDepositReceiptFile)
-	payments.Delete("/deposit-receipts/:receipt_uuid/file", r.authMiddleware.Authenticate(), r.paymentHandler.DeleteDepositReceiptFile)
+	payments.Put("/deposit-receipts/:receipt_uuid/file", r.authMiddleware.Authenticate(), noImpersonation, r.rateLimitMiddleware.Payment(), r.paymentHandler.UpdateDepositReceiptFile)
+	payments.Delete("/deposit-receipts/:receipt_uuid/file", r.authMiddleware.Authenticate(), noImpersonation, r.rateLimitMiddleware.Payment(), r.paymentHandler.DeleteDepositReceiptFile)
+	payments.Get("/postpaid", r.authMiddleware.Authenticate(), r.postpaidBillingHandler.GetSummary)
+	payments.Get("/invoices", r.authMiddleware.Authenticate(), r.taxInvoiceHandler.ListInvoices)
+	payments.Get("/invoices/:uuid/pdf", r.authMiddleware.Authenticate(), r.taxInvoiceHandler.DownloadInvoice)
+
+	// Sandbox account routes (protected with authentication)
+	sandbox := api.Group("/sandbox")
+	sandbox.Use(r.authMiddleware.Authenticate())
+	sandbox.Get("/", r.sandboxHandler.GetStatus)
+	sandbox.Post("/wallet/top-up", r.sandboxHandler.TopUp)
+	sandbox.Delete("/", r.sandboxHandler.Purge)
+
+	// Notification inbox routes (protected with authentication)
+	notifications := api.Group("/notifications")
+	notifications.Use(r.authMiddleware.Authenticate())
+	notifications.Get("/", r.notificationHandler.List)
+	notifications.Get("/unread-count", r.notificationHandler.UnreadCount)
+	notifications.Post("/read", r.notificationHandler.MarkRead)
+	// Telegram channel for campaign and payment notices
+	notifications.Get("/telegram", r.telegramHandler.Status)
+	notifications.Post("/telegram/link", noImpersonation, r.rateLimitMiddleware.OTP(), r.telegramHandler.StartLink)
+	notifications.Post("/telegram/verify", noImpersonation, r.telegramHandler.VerifyLink)
+	notifications.Delete("/telegram", r.telegramHandler.Unlink)
+	// public bot webhook, authenticated by its secret token header
+	api.Post("/telegram/webhook", r.telegramHandler.Webhook)
+	// FCM device tokens of the dashboard PWA
+	notifications.Get("/push/devices", r.pushHandler.ListDevices)
+	notifications.Post("/push/devices", noImpersonation, r.pushHandler.RegisterDevice)
+	notifications.Delete("/push/devices", r.pushHandler.UnregisterDevice)
+
+	// Admin notification routes (protected)
+	adminNotifications := api.Group("/admin/notifications")
+	adminNotifications.Use(r.authMiddleware.AdminAuthenticate())
+	adminNotifications.Use(func(c fiber.Ctx) error { return middleware.RequireAdminAuth(c) })
+	adminNotifications.Use(r.authzMiddleware.AdminAuthorize())
+	adminNotifications.Post("/push/broadcast", r.pushAdminHandler.Broadcast)
 
 	// Admin payment routes (protected)
 	adminPayments := api.Group("/admin/payments")
@@ -452,6 +725,58 @@ func (r *FiberRouter) SetupRoutes() {
 	adminPayments.Get("/deposit-receipts/:uuid/file", r.paymentAdminHandler.GetDepositReceiptFile)
 	adminPayments.Post("/deposit-receipts/status", r.paymentAdminHandler.UpdateDepositReceiptStatus)
 	adminPayments.Post("/transactions/invoice", r.paymentAdminHandler.AddInvoiceToTransaction)
+	adminPayments.Post("/atipay-reconciliation", r.atipayReconciliationAdminHandler.Upload)
+	adminPayments.Get("/atipay-reconciliation", r.atipayReconciliationAdminHandler.Report)
+	adminPayments.Post("/wallet-adjustments", r.walletAdjustmentAdminHandler.Create)
+	adminPayments.Get("/wallet-adjustments", r.walletAdjustmentAdminHandler.List)
+	adminPayments.Post("/wallet-adjustments/:uuid/decision", r.walletAdjustmentAdminHandler.Review)
+	adminPayments.Get("/wallet-transfers", r.walletTransferAdminHandler.List)
+	adminPayments.Get("/frozen-budget-audit", r.frozenBudgetAuditAdminHandler.Audit)
+	adminPayments.Get("/credit-lines/:customer_id", r.postpaidBillingAdminHandler.GetCreditLine)
+	adminPayments.Put("/credit-lines/:customer_id", r.postpaidBillingAdminHandler.SetCreditLimit)
+	adminPayments.Get("/postpaid-invoices", r.postpaidBillingAdminHandler.ListInvoices)
+	adminPayments.Post("/postpaid-invoices/:uuid/paid", r.postpaidBillingAdminHandler.MarkInvoicePaid)
+	adminPayments.Get("/agency-payables", r.paymentAdminHandler.ListAgencyPayables)
+	adminPayments.Post("/agency-payables/:uuid/paid", r.paymentAdminHandler.MarkAgencyPayablePaid)
+	adminPayments.Get("/invoices", r.taxInvoiceAdminHandler.ListInvoices)
+	adminPayments.Get("/invoices/:uuid/pdf", r.taxInvoiceAdminHandler.DownloadInvoice)
+
+	// Signed download links of the local storage driver (public, the
+	// signature authorizes the download)
+	api.Get("/files/*", r.fileHandler.Download)
+
+	// KYC document verification (protected)
+	kyc := api.Group("/kyc")
+	kyc.Use(r.authMiddleware.Authenticate())
+	kyc.Get("/", r.kycHandler.GetStatus)
+	kyc.Post("/documents", r.kycHandler.UploadDocument)
+	kyc.Post("/submit", r.kycHandler.Submit)
+	adminKYC := api.Group("/admin/kyc")
+	adminKYC.Use(r.authMiddleware.AdminAuthenticate())
+	adminKYC.Use(func(c fiber.Ctx) error { return middleware.RequireAdminAuth(c) })
+	adminKYC.Use(r.authzMiddleware.AdminAuthorize())
+	adminKYC.Get("/", r.kycAdminHandler.List)
+	adminKYC.Get("/:customer_id", r.kycAdminHandler.GetCustomer)
+	adminKYC.Post("/:customer_id/review", r.kycAdminHandler.Review)
+
+	// Admin bulk operations (protected)
+	adminBulk := api.Group("/admin/bulk")
+	adminBulk.Use(r.authMiddleware.AdminAuthenticate())
+	adminBulk.Use(func(c fiber.Ctx) error { return middleware.RequireAdminAuth(c) })
+	adminBulk.Use(r.authzMiddleware.AdminAuthorize())
+	adminBulk.Post("/customers/active-status", r.adminBulkOperationHandler.SetCustomersActiveStatus)
+	adminBulk.Post("/customers/agency", r.adminBulkOperationHandler.ReassignAgency)
+	adminBulk.Post("/crypto-payments/resync", r.adminBulkOperationHandler.ResyncCryptoPayments)
+
+	// Admin financial dashboard
+	adminReports := api.Group("/admin/reports")
+	adminReports.Use(r.authMiddleware.AdminAuthenticate())
+	adminReports.Use(func(c fiber.Ctx) error { return middleware.RequireAdminAuth(c) })
+	adminReports.Use(r.authzMiddleware.AdminAuthorize())
+	adminReports.Get("/financial", r.adminReportHandler.GetFinancialReport)
+	adminReports.Get("/top-customers", r.adminReportHandler.GetTopCustomers)
+	adminReports.Get("/wallet-liability", r.adminReportHandler.GetWalletLiability)
+	adminReports.Post("/rollups/refresh", r.adminReportHandler.RefreshRollups)
 
 	// Crypto payment routes
 	crypto := api.Group("/crypto")
@@ -459,7 +784,7 @@ func (r *FiberRouter) SetupRoutes() {
 	api.Post("/crypto/providers/:platform/callback", r.cryptoPaymentHandler.Webhook)
 	// protected crypto APIs
 	crypto.Use(r.authMiddleware.Authenticate())
-	crypto.Post("/payments/request", r.cryptoPaymentHandler.CreateRequest)
+	crypto.Post("/payments/request", noImpersonation, r.rateLimitMiddleware.Payment(), r.cryptoPaymentHandler.CreateRequest)
 	crypto.Get("/payments/:uuid/status", r.cryptoPaymentHandler.GetStatus)
 	crypto.Post("/payments/verify", r.cryptoPaymentHandler.ManualVerify)
 
@@ -470,10 +795,41 @@ func (r *FiberRouter) SetupRoutes() {
 	agency.Get("/agency/customers/list", r.agencyHandler.ListAgencyCustomers)
 	agency.Get("/agency/discounts/active", r.agencyHandler.ListAgencyActiveDiscounts)
 	agency.Get("/agency/customers/:customer_id/discounts", r.agencyHandler.ListAgencyCustomerDiscounts)
+	agency.Get("/agency/customers/:customer_id/discounts/schedule", r.agencyHandler.ListAgencyDiscountSchedule)
 	agency.Post("/agency/discounts", r.agencyHandler.CreateAgencyDiscount)
+	agency.Get("/agency/commissions", r.agencyHandler.GetAgencyCommissionDashboard)
+	agency.Get("/agency/commissions/export", r.agencyHandler.ExportAgencyCommissionReport)
+	agency.Get("/agency/delegations", r.agencyHandler.ListAgencyDelegations)
+	// Customer analytics, served from the reporting rollups
+	agency.Get("/usage", r.customerAnalyticsHandler.GetUsage)
+	agency.Get("/campaigns/:uuid/daily", r.customerAnalyticsHandler.GetCampaignDailyStats)
 
 	// Profile route (protected)
 	api.Get("/profile", r.authMiddleware.Authenticate(), r.profileHandler.GetProfile)
+	api.Put("/profile/locale", r.authMiddleware.Authenticate(), r.profileHandler.UpdateLocale)
+	api.Put("/profile/company", r.authMiddleware.Authenticate(), r.profileHandler.UpdateCompanyInfo)
+	api.Put("/profile/address", r.authMiddleware.Authenticate(), r.profileHandler.UpdateAddress)
+	api.Put("/profile/sheba", r.authMiddleware.Authenticate(), noImpersonation, r.profileHandler.UpdateSheba)
+	api.Post("/profile/sheba/verify", r.authMiddleware.Authenticate(), noImpersonation, r.profileHandler.VerifySheba)
+	api.Get("/profile/changes", r.authMiddleware.Authenticate(), r.profileHandler.ListChanges)
+	adminProfileChanges := api.Group("/admin/profile-changes")
+	adminProfileChanges.Use(r.authMiddleware.AdminAuthenticate())
+	adminProfileChanges.Use(func(c fiber.Ctx) error { return middleware.RequireAdminAuth(c) })
+	adminProfileChanges.Use(r.authzMiddleware.AdminAuthorize())
+	adminProfileChanges.Get("/", r.profileChangeAdminHandler.List)
+	adminProfileChanges.Post("/:uuid/review", r.profileChangeAdminHandler.Review)
+
+	// Account data export and deletion routes (protected)
+	account := api.Group("/account")
+	account.Use(r.authMiddleware.Authenticate())
+	account.Post("/data-exports", noImpersonation, r.customerDataHandler.RequestExport)
+	account.Get("/data-exports/:uuid/download", r.customerDataHandler.DownloadExport)
+	account.Get("/data-requests/:uuid", r.customerDataHandler.GetRequest)
+	account.Post("/deletion", noImpersonation, r.customerDataHandler.RequestDeletion)
+	account.Delete("/deletion", r.customerDataHandler.CancelDeletion)
+	account.Get("/agency-delegation", r.agencyHandler.GetAgencyDelegation)
+	account.Put("/agency-delegation", noImpersonation, r.agencyHandler.GrantAgencyDelegation)
+	account.Delete("/agency-delegation", r.agencyHandler.RevokeAgencyDelegation)
 
 	// Multimedia upload route (protected)
 	media := api.Group("/media")
@@ -529,15 +885,21 @@ func (r *FiberRouter) SetupRoutes() {
 	log.Println("Routes configured successfully")
 }
 
+// setupV2Routes registers /api/v2. An endpoint gets a v2 route when its
+// request or response has to change incompatibly; its v1 route keeps the old
+// shape and both call the same business flow. Handlers that serve both can
+// branch on middleware.RequestAPIVersion.
+func (r *FiberRouter) setupV2Routes(api fiber.Router, apiLimiter fiber.Handler) {
+	api.Get("/health", r.healthCheck)
+	api.Get("/status", r.healthHandler.Status)
+
+	api.Use(apiLimiter)
+}
+
 // SetupMiddleware configures global middleware
 func (r *FiberRouter) setupMiddleware() {
 	// Request ID middleware - must be first
-	r.app.Use(requestid.New(requestid.Config{
-		Header: "X-Request-ID",
-		Generator: func() string {
-			return generateRequestID()
-		},
-	}))
+	r.app.Use(middleware.RequestID())
 
 	// Capture every completed 4xx/5xx response, even when the handler does not return an error.
 	r.app.Use(observability.HTTPStatusCaptureMiddleware())
@@ -582,12 +944,14 @@ func (r *FiberRouter) setupMiddleware() {
 			"Accept",
 			"Authorization",
 			"X-Requested-With",
-			"X-Request-ID",
+			middleware.RequestIDHeader,
+			middleware.CorrelationIDHeader,
 			"X-API-Key",
 			"Cache-Control",
+			middleware.ActingAsCustomerHeader,
 		},
 		ExposeHeaders: []string{
-			"X-Request-ID",
+			middleware.RequestIDHeader,
 			"X-Response-Time",
 		},
 		AllowCredentials: true,
@@ -624,7 +988,7 @@ func (r *FiberRouter) setupMiddleware() {
 		TimeZone:   "UTC",
 		Next: func(c fiber.Ctx) bool {
 			// Skip logging for health checks in production
-			return c.Path() == "/api/v1/health"
+			return isHealthPath(c.Path())
 		},
 	}))
 
@@ -668,13 +1032,7 @@ func (r *FiberRouter) securityMiddleware(c fiber.Ctx) error {
 
 	for _, blockedIP := range blockedIPs {
 		if clientIP == blockedIP {
-			return c.Status(fiber.StatusForbidden).JSON(dto.APIResponse{
-				Success: false,
-				Message: "Access denied from this IP address",
-				Error: dto.ErrorDetail{
-					Code: "ACCESS_DENIED",
-				},
-			})
+			return apierror.Respond(c, fiber.StatusForbidden, "Access denied from this IP address", "ACCESS_DENIED", nil)
 		}
 	}
 
@@ -685,7 +1043,7 @@ func (r *FiberRouter) securityMiddleware(c fiber.Ctx) error {
 // API key validation middleware
 func (r *FiberRouter) apiKeyMiddleware(c fiber.Ctx) error {
 	// Skip API key validation for certain endpoints
-	if c.Path() == "/api/v1/health" || c.Path() == "/api/v1/docs" {
+	if isHealthPath(c.Path()) || c.Path() == "/api/v1/docs" {
 		return c.Next()
 	}
 
@@ -695,13 +1053,7 @@ func (r *FiberRouter) apiKeyMiddleware(c fiber.Ctx) error {
 	if requireAPIKey {
 		apiKey := c.Get("X-API-Key")
 		if apiKey == "" {
-			return c.Status(fiber.StatusUnauthorized).JSON(dto.APIResponse{
-				Success: false,
-				Message: "API key is required",
-				Error: dto.ErrorDetail{
-					Code: "MISSING_API_KEY",
-				},
-			})
+			return apierror.Respond(c, fiber.StatusUnauthorized, "API key is required", "MISSING_API_KEY", nil)
 		}
 
 		// Validate API key (this would check against database/config)
@@ -718,13 +1070,7 @@ func (r *FiberRouter) apiKeyMiddleware(c fiber.Ctx) error {
 		}
 
 		if !isValid {
-			return c.Status(fiber.StatusUnauthorized).JSON(dto.APIResponse{
-				Success: false,
-				Message: "Invalid API key",
-				Error: dto.ErrorDetail{
-					Code: "INVALID_API_KEY",
-				},
-			})
+			return apierror.Respond(c, fiber.StatusUnauthorized, "Invalid API key", "INVALID_API_KEY", nil)
 		}
 	}
 
@@ -832,13 +1178,7 @@ func (r *FiberRouter) serveSwaggerJSON(c fiber.Ctx) error {
 	// Read the generated swagger.json file
 	swaggerData, err := os.ReadFile("docs/swagger.json")
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(dto.APIResponse{
-			Success: false,
-			Message: "Failed to load Swagger documentation",
-			Error: dto.ErrorDetail{
-				Code: "SWAGGER_LOAD_ERROR",
-			},
-		})
+		return apierror.Respond(c, fiber.StatusInternalServerError, "Failed to load Swagger documentation", "SWAGGER_LOAD_ERROR", nil)
 	}
 
 	c.Set("Content-Type", "application/json")
@@ -850,13 +1190,7 @@ func (r *FiberRouter) serveStandaloneSwaggerUI(c fiber.Ctx) error {
 	// Read the standalone HTML file
 	htmlData, err := os.ReadFile("docs/swagger-ui-standalone.html")
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(dto.APIResponse{
-			Success: false,
-			Message: "Failed to load standalone Swagger UI",
-			Error: dto.ErrorDetail{
-				Code: "SWAGGER_UI_LOAD_ERROR",
-			},
-		})
+		return apierror.Respond(c, fiber.StatusInternalServerError, "Failed to load standalone Swagger UI", "SWAGGER_UI_LOAD_ERROR", nil)
 	}
 
 	c.Set("Content-Type", "text/html")
@@ -867,17 +1201,10 @@ func (r *FiberRouter) serveStandaloneSwaggerUI(c fiber.Ctx) error {
 func (r *FiberRouter) notFoundHandler(c fiber.Ctx) error {
 	requestID := c.Locals("requestid")
 
-	return c.Status(fiber.StatusNotFound).JSON(dto.APIResponse{
-		Success: false,
-		Message: "The requested resource was not found",
-		Error: dto.ErrorDetail{
-			Code: "NOT_FOUND",
-			Details: fiber.Map{
-				"path":       c.Path(),
-				"method":     c.Method(),
-				"request_id": requestID,
-			},
-		},
+	return apierror.Respond(c, fiber.StatusNotFound, "The requested resource was not found", "NOT_FOUND", fiber.Map{
+		"path":       c.Path(),
+		"method":     c.Method(),
+		"request_id": requestID,
 	})
 }
 
@@ -889,11 +1216,17 @@ func errorHandler(c fiber.Ctx, err error) error {
 	errorCode := "INTERNAL_ERROR"
 
 	// Retrieve the custom status code if it's a fiber.*Error
+	// and name the error after it, so catalog entries such as NOT_FOUND or
+	// METHOD_NOT_ALLOWED apply regardless of the message fiber generated
 	if e, ok := err.(*fiber.Error); ok {
 		code = e.Code
+		if code != fiber.StatusInternalServerError {
+			if text := http.StatusText(code); text != "" {
+				errorCode = strings.ToUpper(strings.ReplaceAll(text, " ", "_"))
+			}
+		}
 		if code >= fiber.StatusBadRequest && code < fiber.StatusInternalServerError {
 			message = e.Message
-			errorCode = strings.ToUpper(strings.ReplaceAll(e.Message, " ", "_"))
 		}
 	}
 
@@ -905,28 +1238,17 @@ func errorHandler(c fiber.Ctx, err error) error {
 	requestID := c.Locals("requestid")
 
 	// Return JSON error response
-	return c.Status(code).JSON(dto.APIResponse{
-		Success: false,
-		Message: message,
-		Error: dto.ErrorDetail{
-			Code: errorCode,
-			Details: fiber.Map{
-				"timestamp":  utils.UTCNow().Unix(),
-				"request_id": requestID,
-			},
-		},
+	return apierror.Respond(c, code, message, errorCode, fiber.Map{
+		"timestamp":  utils.UTCNow().Unix(),
+		"request_id": requestID,
 	})
 }
 
 // Helper functions
 
-// generateRequestID creates a unique request ID
-func generateRequestID() string {
-	bytes := make([]byte, 8)
-	if _, err := rand.Read(bytes); err != nil {
-		return hex.EncodeToString([]byte(time.Now().Format("150405.000000")))
-	}
-	return hex.EncodeToString(bytes)
+// isHealthPath reports whether path is the health check of any API version
+func isHealthPath(path string) bool {
+	return path == "/api/v1/health" || path == "/api/v2/health"
 }
 
 // contains checks if a string contains a substring
@@ -0,0 +1,117 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	// Discrepancies found by the last reconciliation, by kind
+	atipayReconciliationDiscrepancies = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "atipay_reconciliation_discrepancies",
+			Help: "Discrepancies found by the last Atipay settlement reconciliation, by status (amount_mismatch, status_mismatch, missing_in_system, missing_in_report, duplicate)",
+		},
+		[]string{"status"},
+	)
+
+	// When the reconciliation last completed without error
+	atipayReconciliationLastSuccess = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "atipay_reconciliation_last_success_timestamp_seconds",
+			Help: "Unix time the Atipay settlement reconciliation last completed without error",
+		},
+	)
+)
+
+// AtipaySettlementReconciler downloads and reconciles Atipay's settlement
+// report of the Tehran calendar day containing reportDate
+type AtipaySettlementReconciler interface {
+	Pull(ctx context.Context, reportDate time.Time) (*dto.AtipayReconciliationSummary, error)
+}
+
+// AtipayReconciliationScheduler periodically reconciles the previous day's
+// Atipay settlement report. The report may be published late or corrected,
+// so each run reconciles the day again and replaces the previous result.
+type AtipayReconciliationScheduler struct {
+	reconciler   AtipaySettlementReconciler
+	logger       *log.Logger
+	pollInterval time.Duration
+}
+
+func NewAtipayReconciliationScheduler(
+	reconciler AtipaySettlementReconciler,
+	logger *log.Logger,
+	pollInterval time.Duration,
+) *AtipayReconciliationScheduler {
+	if pollInterval <= 0 {
+		pollInterval = 6 * time.Hour
+	}
+	if logger == nil {
+		logger = log.Default()
+	}
+	return &AtipayReconciliationScheduler{
+		reconciler:   reconciler,
+		logger:       logger,
+		pollInterval: pollInterval,
+	}
+}
+
+func (s *AtipayReconciliationScheduler) Start(parent context.Context) func() {
+	workerCtx, cancel := context.WithCancel(parent)
+	var wg sync.WaitGroup
+	var stopOnce sync.Once
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(s.pollInterval)
+		defer ticker.Stop()
+
+		s.runOnce(workerCtx)
+		for {
+			select {
+			case <-workerCtx.Done():
+				return
+			case <-ticker.C:
+				s.runOnce(workerCtx)
+			}
+		}
+	}()
+	return func() {
+		stopOnce.Do(func() {
+			cancel()
+			wg.Wait()
+		})
+	}
+}
+
+func (s *AtipayReconciliationScheduler) runOnce(parent context.Context) {
+	ctx, cancel := context.WithTimeout(parent, 5*time.Minute)
+	defer cancel()
+
+	today, _ := utils.TehranDayBounds(time.Now())
+	yesterday := today.AddDate(0, 0, -1)
+	summary, err := s.reconciler.Pull(ctx, yesterday)
+	if err != nil {
+		s.logger.Printf("atipay reconciliation scheduler: %s: %v", yesterday.Format("2006-01-02"), err)
+		return
+	}
+	atipayReconciliationDiscrepancies.WithLabelValues("amount_mismatch").Set(float64(summary.AmountMismatch))
+	atipayReconciliationDiscrepancies.WithLabelValues("status_mismatch").Set(float64(summary.StatusMismatch))
+	atipayReconciliationDiscrepancies.WithLabelValues("missing_in_system").Set(float64(summary.MissingInSystem))
+	atipayReconciliationDiscrepancies.WithLabelValues("missing_in_report").Set(float64(summary.MissingInReport))
+	atipayReconciliationDiscrepancies.WithLabelValues("duplicate").Set(float64(summary.Duplicate))
+	atipayReconciliationLastSuccess.SetToCurrentTime()
+	if summary.Discrepancies > 0 {
+		s.logger.Printf("atipay reconciliation scheduler: %s: %d discrepancies in %d report rows (%+v)",
+			summary.ReportDate, summary.Discrepancies, summary.ReportRows, *summary)
+	}
+}
@@ -0,0 +1,38 @@
+package scheduler
+
+import (
+	"context"
+	"io"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+type fakeAtipaySettlementReconciler struct {
+	pulled []time.Time
+}
+
+func (r *fakeAtipaySettlementReconciler) Pull(_ context.Context, reportDate time.Time) (*dto.AtipayReconciliationSummary, error) {
+	r.pulled = append(r.pulled, reportDate)
+	return &dto.AtipayReconciliationSummary{ReportRows: 10, Matched: 7, AmountMismatch: 2, MissingInReport: 1, Discrepancies: 3}, nil
+}
+
+func TestAtipayReconciliationSchedulerReconcilesYesterday(t *testing.T) {
+	reconciler := &fakeAtipaySettlementReconciler{}
+	NewAtipayReconciliationScheduler(reconciler, log.New(io.Discard, "", 0), 0).runOnce(context.Background())
+
+	today, _ := utils.TehranDayBounds(time.Now())
+	if len(reconciler.pulled) != 1 || !reconciler.pulled[0].Equal(today.AddDate(0, 0, -1)) {
+		t.Fatalf("pulled = %v, want the day before %s", reconciler.pulled, today)
+	}
+	if got := testutil.ToFloat64(atipayReconciliationDiscrepancies.WithLabelValues("amount_mismatch")); got != 2 {
+		t.Fatalf("amount mismatches = %v, want 2", got)
+	}
+	if got := testutil.ToFloat64(atipayReconciliationDiscrepancies.WithLabelValues("missing_in_report")); got != 1 {
+		t.Fatalf("missing in report = %v, want 1", got)
+	}
+}
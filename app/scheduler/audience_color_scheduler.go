@@ -0,0 +1,111 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	// Audience profiles moved between colors, by the rule that moved them
+	audienceColorTransitionsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "audience_color_transitions_total",
+			Help: "Audience profiles moved between white and pink, by reason (delivery_failures, opt_out, permanent_error, click_engagement)",
+		},
+		[]string{"reason"},
+	)
+
+	// When the color lifecycle run last completed without error
+	audienceColorLastSuccess = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "audience_color_last_success_timestamp_seconds",
+			Help: "Unix time the audience color lifecycle run last completed without error",
+		},
+	)
+)
+
+// AudienceColorTransitioner applies the audience color lifecycle rules
+type AudienceColorTransitioner interface {
+	ApplyTransitions(ctx context.Context) (map[models.AudienceColorTransitionReason]int, error)
+}
+
+// AudienceColorScheduler periodically promotes and demotes audience profiles
+// between white and pink.
+type AudienceColorScheduler struct {
+	transitioner AudienceColorTransitioner
+	logger       *log.Logger
+	pollInterval time.Duration
+}
+
+func NewAudienceColorScheduler(
+	transitioner AudienceColorTransitioner,
+	logger *log.Logger,
+	pollInterval time.Duration,
+) *AudienceColorScheduler {
+	if pollInterval <= 0 {
+		pollInterval = time.Hour
+	}
+	if logger == nil {
+		logger = log.Default()
+	}
+	return &AudienceColorScheduler{
+		transitioner: transitioner,
+		logger:       logger,
+		pollInterval: pollInterval,
+	}
+}
+
+func (s *AudienceColorScheduler) Start(parent context.Context) func() {
+	workerCtx, cancel := context.WithCancel(parent)
+	var wg sync.WaitGroup
+	var stopOnce sync.Once
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(s.pollInterval)
+		defer ticker.Stop()
+
+		s.runOnce(workerCtx)
+		for {
+			select {
+			case <-workerCtx.Done():
+				return
+			case <-ticker.C:
+				s.runOnce(workerCtx)
+			}
+		}
+	}()
+	return func() {
+		stopOnce.Do(func() {
+			cancel()
+			wg.Wait()
+		})
+	}
+}
+
+func (s *AudienceColorScheduler) runOnce(parent context.Context) {
+	ctx, cancel := context.WithTimeout(parent, 15*time.Minute)
+	defer cancel()
+
+	moved, err := s.transitioner.ApplyTransitions(ctx)
+	total := 0
+	for reason, n := range moved {
+		audienceColorTransitionsTotal.WithLabelValues(string(reason)).Add(float64(n))
+		total += n
+	}
+	if err != nil {
+		s.logger.Printf("audience color scheduler: %v", err)
+	} else {
+		audienceColorLastSuccess.SetToCurrentTime()
+	}
+	if total > 0 {
+		s.logger.Printf("audience color scheduler: moved %d profiles (%v)", total, moved)
+	}
+}
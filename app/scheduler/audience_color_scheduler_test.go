@@ -0,0 +1,46 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"testing"
+
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+type fakeAudienceColorTransitioner struct {
+	moved map[models.AudienceColorTransitionReason]int
+	err   error
+}
+
+func (t fakeAudienceColorTransitioner) ApplyTransitions(context.Context) (map[models.AudienceColorTransitionReason]int, error) {
+	return t.moved, t.err
+}
+
+func TestAudienceColorSchedulerCountsTransitions(t *testing.T) {
+	optOut := audienceColorTransitionsTotal.WithLabelValues(string(models.AudienceColorReasonOptOut))
+	clicks := audienceColorTransitionsTotal.WithLabelValues(string(models.AudienceColorReasonClickEngagement))
+	beforeOptOut, beforeClicks := testutil.ToFloat64(optOut), testutil.ToFloat64(clicks)
+
+	logger := log.New(io.Discard, "", 0)
+	NewAudienceColorScheduler(fakeAudienceColorTransitioner{moved: map[models.AudienceColorTransitionReason]int{
+		models.AudienceColorReasonOptOut:          2,
+		models.AudienceColorReasonClickEngagement: 5,
+	}}, logger, 0).runOnce(context.Background())
+
+	// A run failing on a later rule still counts what the earlier ones moved
+	NewAudienceColorScheduler(fakeAudienceColorTransitioner{
+		moved: map[models.AudienceColorTransitionReason]int{models.AudienceColorReasonOptOut: 1},
+		err:   errors.New("db down"),
+	}, logger, 0).runOnce(context.Background())
+
+	if got := testutil.ToFloat64(optOut) - beforeOptOut; got != 3 {
+		t.Fatalf("opt-out transitions = %v, want 3", got)
+	}
+	if got := testutil.ToFloat64(clicks) - beforeClicks; got != 5 {
+		t.Fatalf("click transitions = %v, want 5", got)
+	}
+}
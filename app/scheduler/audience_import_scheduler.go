@@ -0,0 +1,82 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// AudienceImportProcessor imports the rows of queued bulk audience imports
+type AudienceImportProcessor interface {
+	ProcessNextImport(ctx context.Context) (bool, error)
+}
+
+// AudienceImportScheduler periodically drains the queue of uploaded audience
+// CSV files, one job at a time.
+type AudienceImportScheduler struct {
+	processor    AudienceImportProcessor
+	logger       *log.Logger
+	pollInterval time.Duration
+}
+
+func NewAudienceImportScheduler(
+	processor AudienceImportProcessor,
+	logger *log.Logger,
+	pollInterval time.Duration,
+) *AudienceImportScheduler {
+	if pollInterval <= 0 {
+		pollInterval = 30 * time.Second
+	}
+	if logger == nil {
+		logger = log.Default()
+	}
+	return &AudienceImportScheduler{
+		processor:    processor,
+		logger:       logger,
+		pollInterval: pollInterval,
+	}
+}
+
+func (s *AudienceImportScheduler) Start(parent context.Context) func() {
+	workerCtx, cancel := context.WithCancel(parent)
+	var wg sync.WaitGroup
+	var stopOnce sync.Once
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(s.pollInterval)
+		defer ticker.Stop()
+
+		s.runOnce(workerCtx)
+		for {
+			select {
+			case <-workerCtx.Done():
+				return
+			case <-ticker.C:
+				s.runOnce(workerCtx)
+			}
+		}
+	}()
+	return func() {
+		stopOnce.Do(func() {
+			cancel()
+			wg.Wait()
+		})
+	}
+}
+
+func (s *AudienceImportScheduler) runOnce(parent context.Context) {
+	for parent.Err() == nil {
+		ctx, cancel := context.WithTimeout(parent, 30*time.Minute)
+		claimed, err := s.processor.ProcessNextImport(ctx)
+		cancel()
+		if err != nil {
+			s.logger.Printf("audience import scheduler: %v", err)
+		}
+		if !claimed {
+			return
+		}
+	}
+}
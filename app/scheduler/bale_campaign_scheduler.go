@@ -4,6 +4,7 @@ package scheduler
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -37,11 +38,9 @@ type BaleCampaignScheduler struct {
 	jobRepo   repository.CampaignStatusJobRepository
 	resRepo   repository.BaleStatusResultRepository
 	statsRepo repository.SrcLayerAllStatsRepository
-	notifier  NotificationSender
+	jobs      JobEnqueuer
 	logger    *log.Logger
-	interval  time.Duration
-
-	messageDelay time.Duration
+	*campaignTiming
 
 	db       *gorm.DB
 	adminCfg config.AdminConfig
@@ -57,6 +56,8 @@ type BaleCampaignScheduler struct {
 
 	audienceCache       *AudienceCache
 	bundleAudienceCache *BundleAudienceCache
+	blacklistRepo       repository.BlacklistedNumberRepository
+	quotaRepo           repository.CustomerSendingQuotaRepository
 }
 
 func NewBaleCampaignScheduler(
@@ -67,7 +68,7 @@ func NewBaleCampaignScheduler(
 	jobRepo repository.CampaignStatusJobRepository,
 	resRepo repository.BaleStatusResultRepository,
 	statsRepo repository.SrcLayerAllStatsRepository,
-	notifier NotificationSender,
+	jobs JobEnqueuer,
 	db *gorm.DB,
 	logger *log.Logger,
 	interval time.Duration,
@@ -76,9 +77,6 @@ func NewBaleCampaignScheduler(
 	botCfg config.BotConfig,
 	adminCfg config.AdminConfig,
 ) *BaleCampaignScheduler {
-	if interval <= 0 {
-		interval = time.Minute
-	}
 	if botCfg.APIDomain == "" {
 		botCfg.APIDomain = defaultBotAPIDomain
 	}
@@ -91,18 +89,19 @@ func NewBaleCampaignScheduler(
 		jobRepo:             jobRepo,
 		resRepo:             resRepo,
 		statsRepo:           statsRepo,
-		notifier:            notifier,
+		jobs:                jobs,
 		logger:              logger,
 		db:                  db,
-		interval:            interval,
-		messageDelay:        messageDelay,
+		campaignTiming:      newCampaignTiming(interval, messageDelay),
 		adminCfg:            adminCfg,
 		baleCfg:             baleCfg,
 		botCfg:              botCfg,
-		botClient:           newHTTPBotClient(botCfg),
+		botClient:           newBotClient(botCfg),
 		baleClient:          newHTTPBaleClient(baleCfg),
 		audienceCache:       NewAudienceCache(repository.NewAudienceSelectionRepository(db)),
 		bundleAudienceCache: NewBundleAudienceCache(repository.NewBundleAudienceSelectionRepository(db)),
+		blacklistRepo:       repository.NewBlacklistedNumberRepository(db),
+		quotaRepo:           repository.NewCustomerSendingQuotaRepository(db),
 		schedulerName:       "bale",
 	}
 
@@ -125,13 +124,17 @@ func (s *BaleCampaignScheduler) initSchedulerLogger() error {
 
 func (s *BaleCampaignScheduler) Start(parent context.Context) func() {
 	go func() {
-		ticker := time.NewTicker(s.interval)
+		interval, changed := s.tick()
+		ticker := time.NewTicker(interval)
 		defer ticker.Stop()
 
 		for {
 			select {
 			case <-parent.Done():
 				return
+			case <-changed:
+				interval, changed = s.tick()
+				ticker.Reset(interval)
 			case <-ticker.C:
 				func() {
 					ctx, cancel := context.WithTimeout(parent, 20*time.Minute) // TODO:
@@ -213,6 +216,10 @@ func (s *BaleCampaignScheduler) runOnce(ctx context.Context, parent context.Cont
 }
 
 func (s *BaleCampaignScheduler) processBaleCampaign(ctx context.Context, jazzAccessToken string, c dto.BotGetCampaignResponse) error {
+	if c.Sandbox {
+		return executeSandboxCampaign(ctx, s.botClient, s.logger, jazzAccessToken, c)
+	}
+
 	botID, err := extractBaleBotID(c)
 	if err != nil {
 		return fmt.Errorf("resolve Bale bot id for campaign id=%d: %w", c.ID, err)
@@ -238,6 +245,7 @@ func (s *BaleCampaignScheduler) processBaleCampaign(ctx context.Context, jazzAcc
 		codes        []string
 		unmatchedUID []string
 		selectionID  *uint
+		blacklisted  int64
 	)
 	if hasTargetAudienceExcelFileUUID(c.TargetAudienceExcelFileUUID) {
 		if err := ctx.Err(); err != nil {
@@ -253,7 +261,7 @@ func (s *BaleCampaignScheduler) processBaleCampaign(ctx context.Context, jazzAcc
 		if c.ShortLinkDomain != nil {
 			excelShortLinkDomain = *c.ShortLinkDomain
 		}
-		audienceResult, err := fetchAudiencePhonesByUIDs(ctx, s.logger, s.audRepo, s.botClient, c, jazzAccessToken, fileUIDs, excelShortLinkDomain)
+		audienceResult, err := fetchAudiencePhonesByUIDs(ctx, s.logger, s.audRepo, s.blacklistRepo, s.botClient, c, jazzAccessToken, fileUIDs, excelShortLinkDomain)
 		if err != nil {
 			return fmt.Errorf("fetch audience phones by UIDs for campaign id=%d: %w", c.ID, err)
 		}
@@ -262,6 +270,7 @@ func (s *BaleCampaignScheduler) processBaleCampaign(ctx context.Context, jazzAcc
 		uids = audienceResult.UIDs
 		codes = audienceResult.Codes
 		unmatchedUID = audienceResult.UnmatchedUIDs
+		blacklisted = audienceResult.BlacklistedExcluded
 		selectionID = nil
 		s.logger.Printf("Bale scheduler: campaign id=%d fetched %d phones via excel (unmatched=%d)", c.ID, len(phones), len(unmatchedUID))
 	} else {
@@ -287,6 +296,7 @@ func (s *BaleCampaignScheduler) processBaleCampaign(ctx context.Context, jazzAcc
 		uids = audienceResult.UIDs
 		codes = audienceResult.Codes
 		selectionID = utils.ToPtr(audienceResult.SelectionID)
+		blacklisted = audienceResult.BlacklistedExcluded
 		s.logger.Printf("Bale scheduler: campaign id=%d fetched %d phones (selection_id=%d)", c.ID, len(phones), audienceResult.SelectionID)
 	}
 
@@ -296,7 +306,17 @@ func (s *BaleCampaignScheduler) processBaleCampaign(ctx context.Context, jazzAcc
 	if len(codes) != len(phones) {
 		return fmt.Errorf("audience codes mismatch for campaign id=%d: phones=%d codes=%d", c.ID, len(phones), len(codes))
 	}
-	s.logger.Printf("Bale scheduler: campaign id=%d audience ready: phones=%d unmatched=%d", c.ID, len(phones), len(unmatchedUID))
+	keep, err := sendingQuotaKeep(ctx, s.quotaRepo, c.CustomerID, len(phones))
+	if err != nil {
+		return fmt.Errorf("apply sending quota for campaign id=%d: %w", c.ID, err)
+	}
+	quotaExcluded := int64(len(phones) - keep)
+	if quotaExcluded > 0 {
+		phones, ids, codes = phones[:keep], ids[:keep], codes[:keep]
+		uids = uids[:min(keep, len(uids))]
+		s.logger.Printf("Bale scheduler: campaign id=%d truncated to sending quota: kept=%d dropped=%d", c.ID, keep, quotaExcluded)
+	}
+	s.logger.Printf("Bale scheduler: campaign id=%d audience ready: phones=%d unmatched=%d blacklisted=%d quota_excluded=%d", c.ID, len(phones), len(unmatchedUID), blacklisted, quotaExcluded)
 
 	campaignJSON, err := json.Marshal(c)
 	if err != nil {
@@ -314,6 +334,8 @@ func (s *BaleCampaignScheduler) processBaleCampaign(ctx context.Context, jazzAcc
 			AudienceCodes:       []string{},
 			LastAudienceID:      nil,
 			AudienceSelectionID: selectionID,
+			BlacklistedExcluded: blacklisted,
+			QuotaExcluded:       quotaExcluded,
 			Statistics:          nil,
 		}
 		if err := s.pcRepo.Save(txCtx, pc); err != nil {
@@ -359,6 +381,7 @@ func (s *BaleCampaignScheduler) processBaleCampaign(ctx context.Context, jazzAcc
 		s.logger.Printf("Bale scheduler: campaign id=%d media uploaded file_id=%v", c.ID, fileID)
 	}
 
+	stopped := false
 	for start := 0; start < len(phones); start += baleSendBatchSize {
 		if err := ctx.Err(); err != nil {
 			return fmt.Errorf("context expired at batch start=%d for campaign id=%d: %w", start, c.ID, err)
@@ -403,7 +426,7 @@ func (s *BaleCampaignScheduler) processBaleCampaign(ctx context.Context, jazzAcc
 		}
 
 		lastBatchID := batchIDs[len(batchIDs)-1]
-		if err := repository.WithTransaction(ctx, s.db, func(txCtx context.Context) error {
+		if err := saveCampaignBatch(ctx, s.db, s.logger, c.ID, func(txCtx context.Context) error {
 			if len(rows) > 0 {
 				if err := s.sentRepo.SaveBatch(txCtx, rows); err != nil {
 					return fmt.Errorf("save batch rows: %w", err)
@@ -416,6 +439,12 @@ func (s *BaleCampaignScheduler) processBaleCampaign(ctx context.Context, jazzAcc
 			}
 			return nil
 		}); err != nil {
+			if errors.Is(err, errCampaignExecutionStopped) {
+				s.logger.Printf("Bale scheduler: campaign id=%d stopped after %d recipients", c.ID, start)
+				uids, codes = uids[:start], codes[:start]
+				stopped = true
+				break
+			}
 			return fmt.Errorf("save batch [%d,%d) for campaign id=%d: %w", start, end, c.ID, err)
 		}
 		s.logger.Printf("Bale scheduler: campaign id=%d batch [%d,%d) saved, sending to Bale", c.ID, start, end)
@@ -467,7 +496,7 @@ func (s *BaleCampaignScheduler) processBaleCampaign(ctx context.Context, jazzAcc
 			// NOTE: Error silent here; not returning to avoid blocking further processing
 		}
 		s.logger.Printf("Bale scheduler: campaign id=%d batch [%d,%d) done, sleeping message_delay", c.ID, start, end)
-		if err := sleepWithContext(ctx, s.messageDelay); err != nil {
+		if err := sleepWithContext(ctx, s.messageDelay()); err != nil {
 			return fmt.Errorf("interrupted during batch delay at [%d,%d) for campaign id=%d: %w", start, end, c.ID, err)
 		}
 	}
@@ -483,21 +512,23 @@ func (s *BaleCampaignScheduler) processBaleCampaign(ctx context.Context, jazzAcc
 		}
 	}
 
-	s.logger.Printf("Bale scheduler: campaign id=%d all batches sent", c.ID)
+	if stopped {
+		s.logger.Printf("Bale scheduler: campaign id=%d stopped before all batches were sent", c.ID)
+	} else {
+		s.logger.Printf("Bale scheduler: campaign id=%d all batches sent", c.ID)
 
-	if err := s.botClient.MoveCampaignToExecuted(ctx, jazzAccessToken, c.ID); err != nil {
-		return fmt.Errorf("move campaign id=%d to executed: %w", c.ID, err)
+		if err := s.botClient.MoveCampaignToExecuted(ctx, jazzAccessToken, c.ID); err != nil {
+			return fmt.Errorf("move campaign id=%d to executed: %w", c.ID, err)
+		}
+		s.logger.Printf("Bale scheduler: campaign id=%d moved to executed", c.ID)
 	}
-	s.logger.Printf("Bale scheduler: campaign id=%d moved to executed", c.ID)
 
-	go func(campaignID uint, uids, codes []string) {
-		pushCtx, cancel := context.WithTimeout(context.Background(), 15*time.Minute)
-		defer cancel()
-		if err := s.botClient.PushCampaignAudienceUIDs(pushCtx, campaignID, uids, codes); err != nil {
-			s.logger.Printf("Bale scheduler: push audience UIDs failed for campaign id=%d: %v", campaignID, err)
-			s.notifyAdmin(fmt.Sprintf("Bale Scheduler: push audience UIDs failed for campaign id=%d: %v", campaignID, err))
+	if s.jobs != nil {
+		push := models.PushAudienceUIDsJob{CampaignID: c.ID, UIDs: uids, Codes: codes}
+		if err := s.jobs.Enqueue(ctx, models.JobTypePushAudienceUIDs, push); err != nil {
+			s.logger.Printf("Bale scheduler: queue audience UIDs push failed for campaign id=%d: %v", c.ID, err)
 		}
-	}(c.ID, uids, codes)
+	}
 
 	return nil
 }
@@ -607,7 +638,14 @@ func (s *BaleCampaignScheduler) fetchBaleAudiencePhones(
 		s.logger.Printf("fetchBaleAudiencePhones selection miss: campaign_id=%d", c.ID)
 	}
 
+	blacklist, err := loadBlacklistFilter(ctx, s.blacklistRepo)
+	if err != nil {
+		s.logger.Printf("fetchBaleAudiencePhones load blacklist failed: campaign_id=%d err=%v", c.ID, err)
+		return nil, err
+	}
+
 	selectAudiences := func(exclude map[int64]struct{}) ([]string, []int64, []string, error) {
+		blacklist.reset()
 		phones := make([]string, 0, numAudiences)
 		ids := make([]int64, 0, numAudiences)
 		uids := make([]string, 0, numAudiences)
@@ -631,6 +669,9 @@ func (s *BaleCampaignScheduler) fetchBaleAudiencePhones(
 					return
 				}
 			}
+			if blacklist.skip(*ap.PhoneNumber) {
+				return
+			}
 			phones = append(phones, *ap.PhoneNumber)
 			ids = append(ids, int64(ap.ID))
 			uids = append(uids, ap.UID)
@@ -685,11 +726,12 @@ func (s *BaleCampaignScheduler) fetchBaleAudiencePhones(
 		s.logger.Printf("fetchBaleAudiencePhones skipped short links generation: campaign_id=%d ad_link=empty", c.ID)
 		s.logger.Printf("fetchBaleAudiencePhones success: campaign_id=%d selected=%d codes_length=%d selection_id=%d ad_link=empty", c.ID, len(phones), len(phones), sel.ID)
 		return &AudiencePhonesResult{
-			Phones:      phones,
-			IDs:         ids,
-			UIDs:        uids,
-			Codes:       make([]string, len(phones)),
-			SelectionID: sel.ID,
+			Phones:              phones,
+			IDs:                 ids,
+			UIDs:                uids,
+			Codes:               make([]string, len(phones)),
+			SelectionID:         sel.ID,
+			BlacklistedExcluded: blacklist.Excluded(),
 		}, nil
 	}
 
@@ -699,11 +741,12 @@ func (s *BaleCampaignScheduler) fetchBaleAudiencePhones(
 		s.logger.Printf("fetchBaleAudiencePhones skipped short links generation: campaign_id=%d short_link_domain=empty", c.ID)
 		s.logger.Printf("fetchBaleAudiencePhones success: campaign_id=%d selected=%d codes_length=%d selection_id=%d short_link_domain=empty", c.ID, len(phones), len(phones), sel.ID)
 		return &AudiencePhonesResult{
-			Phones:      phones,
-			IDs:         ids,
-			UIDs:        uids,
-			Codes:       make([]string, len(phones)),
-			SelectionID: sel.ID,
+			Phones:              phones,
+			IDs:                 ids,
+			UIDs:                uids,
+			Codes:               make([]string, len(phones)),
+			SelectionID:         sel.ID,
+			BlacklistedExcluded: blacklist.Excluded(),
 		}, nil
 	}
 
@@ -731,11 +774,12 @@ func (s *BaleCampaignScheduler) fetchBaleAudiencePhones(
 	}
 	s.logger.Printf("fetchBaleAudiencePhones success: campaign_id=%d selected=%d codes_length=%d selection_id=%d", c.ID, len(phones), len(codes), sel.ID)
 	return &AudiencePhonesResult{
-		Phones:      phones,
-		IDs:         ids,
-		UIDs:        uids,
-		Codes:       codes,
-		SelectionID: sel.ID,
+		Phones:              phones,
+		IDs:                 ids,
+		UIDs:                uids,
+		Codes:               codes,
+		SelectionID:         sel.ID,
+		BlacklistedExcluded: blacklist.Excluded(),
 	}, nil
 }
 
@@ -747,6 +791,7 @@ func (s *BaleCampaignScheduler) selectBaleTagAudiences(
 	tagIDs pq.Int32Array,
 	numAudiences int64,
 	exclude map[int64]struct{},
+	blacklist *blacklistFilter,
 	scoreConstraint *models.NormalizedScoreConstraint,
 ) (phones []string, ids []int64, uids []string, err error) {
 	const limit = 10000000
@@ -774,6 +819,9 @@ func (s *BaleCampaignScheduler) selectBaleTagAudiences(
 				continue
 			}
 		}
+		if blacklist.skip(*ap.PhoneNumber) {
+			continue
+		}
 		phones = append(phones, *ap.PhoneNumber)
 		ids = append(ids, int64(ap.ID))
 		uids = append(uids, ap.UID)
@@ -844,7 +892,13 @@ func (s *BaleCampaignScheduler) fetchBaleAudiencePhonesByBundle(
 		s.logger.Printf("fetchBaleAudiencePhonesByBundle bundle selection miss: campaign_id=%d bundle_id=%d", c.ID, bundleID)
 	}
 
-	phones, ids, uids, err := s.selectBaleTagAudiences(ctx, c.ID, tagIDs, numAudiences, exclude, scoreConstraint)
+	blacklist, err := loadBlacklistFilter(ctx, s.blacklistRepo)
+	if err != nil {
+		s.logger.Printf("fetchBaleAudiencePhonesByBundle load blacklist failed: campaign_id=%d err=%v", c.ID, err)
+		return nil, err
+	}
+
+	phones, ids, uids, err := s.selectBaleTagAudiences(ctx, c.ID, tagIDs, numAudiences, exclude, blacklist, scoreConstraint)
 	if err != nil {
 		return nil, err
 	}
@@ -863,22 +917,24 @@ func (s *BaleCampaignScheduler) fetchBaleAudiencePhonesByBundle(
 	if !hasCampaignAdLink(c.AdLink) {
 		s.logger.Printf("fetchBaleAudiencePhonesByBundle skipped short links: campaign_id=%d ad_link=empty", c.ID)
 		return &AudiencePhonesResult{
-			Phones:      phones,
-			IDs:         ids,
-			UIDs:        uids,
-			Codes:       make([]string, len(phones)),
-			SelectionID: sel.ID,
+			Phones:              phones,
+			IDs:                 ids,
+			UIDs:                uids,
+			Codes:               make([]string, len(phones)),
+			SelectionID:         sel.ID,
+			BlacklistedExcluded: blacklist.Excluded(),
 		}, nil
 	}
 
 	if c.ShortLinkDomain == nil || strings.TrimSpace(*c.ShortLinkDomain) == "" {
 		s.logger.Printf("fetchBaleAudiencePhonesByBundle skipped short links: campaign_id=%d short_link_domain=empty", c.ID)
 		return &AudiencePhonesResult{
-			Phones:      phones,
-			IDs:         ids,
-			UIDs:        uids,
-			Codes:       make([]string, len(phones)),
-			SelectionID: sel.ID,
+			Phones:              phones,
+			IDs:                 ids,
+			UIDs:                uids,
+			Codes:               make([]string, len(phones)),
+			SelectionID:         sel.ID,
+			BlacklistedExcluded: blacklist.Excluded(),
 		}, nil
 	}
 
@@ -906,11 +962,12 @@ func (s *BaleCampaignScheduler) fetchBaleAudiencePhonesByBundle(
 	s.logger.Printf("fetchBaleAudiencePhonesByBundle success: campaign_id=%d bundle_id=%d selected=%d codes=%d selection_id=%d",
 		c.ID, bundleID, len(phones), len(codes), sel.ID)
 	return &AudiencePhonesResult{
-		Phones:      phones,
-		IDs:         ids,
-		UIDs:        uids,
-		Codes:       codes,
-		SelectionID: sel.ID,
+		Phones:              phones,
+		IDs:                 ids,
+		UIDs:                uids,
+		Codes:               codes,
+		SelectionID:         sel.ID,
+		BlacklistedExcluded: blacklist.Excluded(),
 	}, nil
 }
 
@@ -963,32 +1020,10 @@ func (s *BaleCampaignScheduler) scheduleStatusCheckJobs(ctx context.Context, pro
 	if len(trackingIDs) == 0 || !s.baleClient.SupportsStatusTracking() || s.jobRepo == nil {
 		return nil
 	}
-	filteredTrackingIDs := make([]string, 0, len(trackingIDs))
-	for _, id := range trackingIDs {
-		if strings.TrimSpace(id) != "" {
-			filteredTrackingIDs = append(filteredTrackingIDs, strings.TrimSpace(id))
-		}
-	}
-	if len(filteredTrackingIDs) == 0 {
+	jobs := newStatusCheckJobs(models.CampaignPlatformBale, processedCampaignID, trackingIDs, utils.UTCNow())
+	if len(jobs) == 0 {
 		return nil
 	}
-
-	corrID := uuid.NewString()
-	now := utils.UTCNow()
-	offsets := []time.Duration{1 * time.Minute, 5 * time.Minute, 15 * time.Minute, 24 * time.Hour, 48 * time.Hour}
-	jobs := make([]*models.CampaignStatusJob, 0, len(offsets))
-	for _, off := range offsets {
-		jobs = append(jobs, &models.CampaignStatusJob{
-			ProcessedCampaignID: processedCampaignID,
-			CorrelationID:       corrID,
-			Platform:            models.CampaignPlatformBale,
-			TrackingIDs:         pq.StringArray(filteredTrackingIDs),
-			RetryCount:          0,
-			ScheduledAt:         now.Add(off),
-			CreatedAt:           now,
-			UpdatedAt:           now,
-		})
-	}
 	return s.jobRepo.SaveBatch(ctx, jobs)
 }
 
@@ -1021,13 +1056,14 @@ func (s *BaleCampaignScheduler) startStatusJobWorker(parent context.Context) {
 					return
 				}
 
+				observeStatusJobLag(job, utils.UTCNow())
 				jobCtx, jobCancel := context.WithTimeout(parent, 2*time.Minute)
 				err := s.handleStatusJob(jobCtx, job)
 				jobCancel()
 
 				if err != nil {
 					s.logger.Printf("Bale scheduler: handle status job id=%d failed: %v", job.ID, err)
-					if job.RetryCount >= statusJobMaxRetry {
+					if job.Status == models.CampaignStatusJobFailed {
 						s.notifyAdmin(fmt.Sprintf("Bale scheduler: status job id=%d has failed %d times with error: %v", job.ID, job.RetryCount, err))
 					}
 				} else {
@@ -1076,20 +1112,11 @@ func (s *BaleCampaignScheduler) handleStatusJob(ctx context.Context, job *models
 	statusResult, fetchErr := s.baleClient.FetchStatus(ctx, serverIDs)
 	job.RawProviderResponse = statusResult.RawResponse
 	if fetchErr != nil {
-		now := utils.UTCNow()
-		job.RetryCount++
-		msg := fetchErr.Error()
-		job.Error = &msg
-		job.UpdatedAt = now
-		// Keep job open for retries until max retry threshold is reached.
-		if job.RetryCount >= statusJobMaxRetry {
-			job.ExecutedAt = &now
-		} else {
-			job.ExecutedAt = nil
-		}
+		recordStatusJobFailure(job, fetchErr, utils.UTCNow())
 		if err := s.jobRepo.Update(ctx, job); err != nil {
 			return err
 		}
+		observeStatusJobOutcome(job)
 		return fetchErr
 	}
 	statusItems := statusResult.Items
@@ -1172,14 +1199,13 @@ func (s *BaleCampaignScheduler) handleStatusJob(ctx context.Context, job *models
 			return err
 		}
 
-		job.ExecutedAt = &now
-		job.Error = nil
-		job.UpdatedAt = now
+		recordStatusJobSuccess(job, now)
 		return s.jobRepo.Update(txCtx, job)
 	})
 	if txErr != nil {
 		return txErr
 	}
+	observeStatusJobOutcome(job)
 
 	stats, err := s.updateProcessedCampaignStats(ctx, job.ProcessedCampaignID)
 	if err != nil {
@@ -1204,11 +1230,13 @@ func (s *BaleCampaignScheduler) handleStatusJob(ctx context.Context, job *models
 }
 
 func (s *BaleCampaignScheduler) markStatusJobExecuted(ctx context.Context, job *models.CampaignStatusJob, errText *string) error {
-	now := utils.UTCNow()
-	job.ExecutedAt = &now
-	job.UpdatedAt = now
+	recordStatusJobSuccess(job, utils.UTCNow())
 	job.Error = errText
-	return s.jobRepo.Update(ctx, job)
+	if err := s.jobRepo.Update(ctx, job); err != nil {
+		return err
+	}
+	observeStatusJobOutcome(job)
+	return nil
 }
 
 func mapBaleProviderStatus(statusCode int) (totalParts int64, deliveredParts int64, undeliveredParts int64, unknownParts int64, status models.BaleSendStatus) {
@@ -1512,12 +1540,12 @@ func extractBaleBotID(c dto.BotGetCampaignResponse) (int64, error) {
 }
 
 func (s *BaleCampaignScheduler) notifyAdmin(message string) {
-	if s.notifier == nil {
+	if s.jobs == nil {
 		return
 	}
-	go func(msg string) {
-		for _, mobile := range s.adminCfg.ActiveMobiles() {
-			_ = s.notifier.SendSMS(context.Background(), mobile, msg, nil)
+	for _, mobile := range s.adminCfg.ActiveMobiles() {
+		if err := s.jobs.Enqueue(context.Background(), models.JobTypeSendSMS, models.SendSMSJob{Mobile: mobile, Message: message}); err != nil {
+			s.logger.Printf("queue admin notification failed: %v", err)
 		}
-	}(message)
+	}
 }
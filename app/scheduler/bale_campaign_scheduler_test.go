@@ -115,6 +115,14 @@ func (s *stubCampaignStatusJobRepo) ListDue(ctx context.Context, platform string
 	return nil, nil
 }
 
+func (s *stubCampaignStatusJobRepo) ListByProcessedCampaign(ctx context.Context, processedCampaignID uint, limit int) ([]*models.CampaignStatusJob, error) {
+	return nil, nil
+}
+
+func (s *stubCampaignStatusJobRepo) SkipPending(ctx context.Context, correlationID string, now time.Time) (int64, error) {
+	return 0, nil
+}
+
 func (s *stubCampaignStatusJobRepo) Update(ctx context.Context, job *models.CampaignStatusJob) error {
 	clone := *job
 	s.updated = append(s.updated, &clone)
@@ -180,12 +180,12 @@ type httpBaleClient struct {
 }
 
 func newHTTPBaleClient(cfg config.BaleConfig) *httpBaleClient {
-	return newHTTPBaleClientWithClient(cfg, newHTTPClient(60*time.Second))
+	return newHTTPBaleClientWithClient(cfg, newHTTPClient("bale", 60*time.Second))
 }
 
 func newHTTPBaleClientWithClient(cfg config.BaleConfig, client *http.Client) *httpBaleClient {
 	if client == nil {
-		client = newHTTPClient(60 * time.Second)
+		client = newHTTPClient("bale", 60*time.Second)
 	}
 	provider := normalizeBaleProvider(cfg.Provider)
 	legacyURL := strings.TrimSpace(cfg.LegacyDomain)
@@ -16,6 +16,7 @@ import (
 	"time"
 
 	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/app/httpclient"
 	"github.com/amirphl/Yamata-no-Orochi/config"
 )
 
@@ -46,10 +47,8 @@ func newHTTPBotClient(cfg config.BotConfig) *httpBotClient {
 	}
 
 	return &httpBotClient{
-		cfg: cfg,
-		client: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		cfg:    cfg,
+		client: httpclient.New("bot", 30*time.Second),
 	}
 }
 
@@ -0,0 +1,111 @@
+package scheduler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/botcontract"
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/config"
+)
+
+func newContractBotClient(t *testing.T) *httpBotClient {
+	t.Helper()
+	srv := httptest.NewServer(botcontract.NewServer())
+	t.Cleanup(srv.Close)
+	return newHTTPBotClient(config.BotConfig{APIDomain: srv.URL, Username: "scheduler", Password: "scheduler-password"})
+}
+
+// TestBotContractResponsesDecodeStrictly fails when a recorded response has
+// a field the DTO the client decodes it into does not know, which the client
+// would otherwise drop silently
+func TestBotContractResponsesDecodeStrictly(t *testing.T) {
+	targets := map[string]func() any{
+		"login":                func() any { return &dto.BotLoginResponse{} },
+		"ready_campaigns":      func() any { return &dto.BotListCampaignsResponse{} },
+		"allocate_short_links": func() any { return &dto.BotAllocateShortLinksResponse{} },
+		"create_short_links":   func() any { return &dto.BotCreateShortLinksResponse{} },
+	}
+	for _, i := range botcontract.Interactions() {
+		newTarget, ok := targets[i.Name]
+		if !ok {
+			t.Errorf("no client DTO for recorded interaction %s", i.Name)
+			continue
+		}
+		dec := json.NewDecoder(bytes.NewReader(i.Data()))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(newTarget()); err != nil {
+			t.Errorf("%s: %v", i.Name, err)
+		}
+	}
+}
+
+func TestBotContractLoginAndReadyCampaigns(t *testing.T) {
+	client := newContractBotClient(t)
+	ctx := context.Background()
+
+	token, err := client.Login(ctx)
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	if token != "contract-bot-access-token" {
+		t.Fatalf("token = %q", token)
+	}
+
+	campaigns, err := client.ListReadyCampaigns(ctx, token, "sms")
+	if err != nil {
+		t.Fatalf("list ready campaigns: %v", err)
+	}
+	if len(campaigns) != 1 {
+		t.Fatalf("campaigns = %d, want 1", len(campaigns))
+	}
+	c := campaigns[0]
+	if c.ID != 42 || c.CustomerID != 7 || c.Platform != "sms" || c.Status != "approved" {
+		t.Fatalf("campaign = %+v", c)
+	}
+	if c.Content == nil || c.ShortLinkDomain == nil || c.LineNumber == nil || c.ScheduleAt == nil || c.AdLink == nil {
+		t.Fatalf("campaign lost fields the schedulers send with: %+v", c)
+	}
+	if c.NumAudiences == nil || *c.NumAudiences != 25000 || !slices.Equal(c.AudienceGrades, []string{"A", "B"}) {
+		t.Fatalf("campaign audience = %v %v", c.NumAudiences, c.AudienceGrades)
+	}
+}
+
+func TestBotContractShortLinks(t *testing.T) {
+	client := newContractBotClient(t)
+	ctx := context.Background()
+	token, err := client.Login(ctx)
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+
+	adLink := "https://shop.example.com/nowruz?u=1"
+	codes, err := client.AllocateShortLinks(ctx, token, &dto.BotAllocateShortLinksRequest{
+		CampaignID:      42,
+		Items:           []dto.PhoneWithAdLink{{Phone: "+989121234567", AdLink: &adLink}, {Phone: "+989121234568"}},
+		ShortLinkDomain: "https://jo1n.ir",
+	})
+	if err != nil {
+		t.Fatalf("allocate short links: %v", err)
+	}
+	if !slices.Equal(codes, []string{"aB3x9", "aB3y0"}) {
+		t.Fatalf("codes = %v", codes)
+	}
+
+	campaignID, clientID, phone := uint(42), uint(7), "+989121234567"
+	err = client.CreateShortLinks(ctx, token, &dto.BotCreateShortLinksRequest{Items: []dto.BotCreateShortLinkRequest{{
+		UID:         "aB3x9",
+		CampaignID:  &campaignID,
+		ClientID:    &clientID,
+		PhoneNumber: &phone,
+		LongLink:    adLink,
+		ShortLink:   "https://jo1n.ir/s/aB3x9",
+	}}})
+	if err != nil {
+		t.Fatalf("create short links: %v", err)
+	}
+}
@@ -0,0 +1,106 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	// Campaigns expired unsent by the sweeper, their budget refunded
+	campaignsExpiredTotal = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "campaigns_expired_total",
+			Help: "Campaigns expired while still waiting for approval past their schedule time, with their reserved budget refunded",
+		},
+	)
+
+	// When the sweeper last completed without error
+	campaignExpiryLastSuccess = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "campaign_expiry_last_success_timestamp_seconds",
+			Help: "Unix time the campaign expiry sweep last completed without error",
+		},
+	)
+)
+
+// CampaignExpirer expires campaigns left unsent past their schedule time
+type CampaignExpirer interface {
+	ExpireUnsentCampaigns(ctx context.Context) (int, error)
+}
+
+// CampaignExpiryScheduler periodically expires campaigns nobody approved
+// before their schedule time, so their reserved budget goes back to the
+// customer without an admin stepping in.
+type CampaignExpiryScheduler struct {
+	expirer      CampaignExpirer
+	logger       *log.Logger
+	pollInterval time.Duration
+}
+
+func NewCampaignExpiryScheduler(
+	expirer CampaignExpirer,
+	logger *log.Logger,
+	pollInterval time.Duration,
+) *CampaignExpiryScheduler {
+	if pollInterval <= 0 {
+		pollInterval = 10 * time.Minute
+	}
+	if logger == nil {
+		logger = log.Default()
+	}
+	return &CampaignExpiryScheduler{
+		expirer:      expirer,
+		logger:       logger,
+		pollInterval: pollInterval,
+	}
+}
+
+func (s *CampaignExpiryScheduler) Start(parent context.Context) func() {
+	workerCtx, cancel := context.WithCancel(parent)
+	var wg sync.WaitGroup
+	var stopOnce sync.Once
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(s.pollInterval)
+		defer ticker.Stop()
+
+		s.runOnce(workerCtx)
+		for {
+			select {
+			case <-workerCtx.Done():
+				return
+			case <-ticker.C:
+				s.runOnce(workerCtx)
+			}
+		}
+	}()
+	return func() {
+		stopOnce.Do(func() {
+			cancel()
+			wg.Wait()
+		})
+	}
+}
+
+func (s *CampaignExpiryScheduler) runOnce(parent context.Context) {
+	ctx, cancel := context.WithTimeout(parent, 5*time.Minute)
+	defer cancel()
+
+	expired, err := s.expirer.ExpireUnsentCampaigns(ctx)
+	campaignsExpiredTotal.Add(float64(expired))
+	if err != nil {
+		s.logger.Printf("campaign expiry scheduler: %v", err)
+	} else {
+		campaignExpiryLastSuccess.SetToCurrentTime()
+	}
+	if expired > 0 {
+		s.logger.Printf("campaign expiry scheduler: expired %d campaigns", expired)
+	}
+}
@@ -0,0 +1,33 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+type fakeCampaignExpirer struct {
+	expired int
+	err     error
+}
+
+func (e fakeCampaignExpirer) ExpireUnsentCampaigns(context.Context) (int, error) {
+	return e.expired, e.err
+}
+
+func TestCampaignExpirySchedulerCountsExpiredCampaigns(t *testing.T) {
+	before := testutil.ToFloat64(campaignsExpiredTotal)
+
+	logger := log.New(io.Discard, "", 0)
+	NewCampaignExpiryScheduler(fakeCampaignExpirer{expired: 2}, logger, 0).runOnce(context.Background())
+	// A sweep failing part way still counts what it expired
+	NewCampaignExpiryScheduler(fakeCampaignExpirer{expired: 1, err: errors.New("db down")}, logger, 0).runOnce(context.Background())
+
+	if got := testutil.ToFloat64(campaignsExpiredTotal) - before; got != 3 {
+		t.Fatalf("expired = %v, want 3", got)
+	}
+}
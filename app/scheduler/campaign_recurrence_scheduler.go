@@ -0,0 +1,90 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+)
+
+// CampaignRecurrenceExpander materializes due occurrences of recurring campaigns
+type CampaignRecurrenceExpander interface {
+	ExpandDueRecurrences(ctx context.Context, dueBefore time.Time) (int, error)
+}
+
+// CampaignRecurrenceScheduler periodically expands recurring campaigns into
+// child campaigns. Occurrences are created lookahead before they are due so
+// the platform schedulers pick them up on time.
+type CampaignRecurrenceScheduler struct {
+	expander     CampaignRecurrenceExpander
+	logger       *log.Logger
+	pollInterval time.Duration
+	lookahead    time.Duration
+}
+
+func NewCampaignRecurrenceScheduler(
+	expander CampaignRecurrenceExpander,
+	logger *log.Logger,
+	pollInterval time.Duration,
+	lookahead time.Duration,
+) *CampaignRecurrenceScheduler {
+	if pollInterval <= 0 {
+		pollInterval = time.Minute
+	}
+	if lookahead < 0 {
+		lookahead = 0
+	}
+	if logger == nil {
+		logger = log.Default()
+	}
+	return &CampaignRecurrenceScheduler{
+		expander:     expander,
+		logger:       logger,
+		pollInterval: pollInterval,
+		lookahead:    lookahead,
+	}
+}
+
+func (s *CampaignRecurrenceScheduler) Start(parent context.Context) func() {
+	workerCtx, cancel := context.WithCancel(parent)
+	var wg sync.WaitGroup
+	var stopOnce sync.Once
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(s.pollInterval)
+		defer ticker.Stop()
+
+		s.runOnce(workerCtx)
+		for {
+			select {
+			case <-workerCtx.Done():
+				return
+			case <-ticker.C:
+				s.runOnce(workerCtx)
+			}
+		}
+	}()
+	return func() {
+		stopOnce.Do(func() {
+			cancel()
+			wg.Wait()
+		})
+	}
+}
+
+func (s *CampaignRecurrenceScheduler) runOnce(parent context.Context) {
+	ctx, cancel := context.WithTimeout(parent, 5*time.Minute)
+	defer cancel()
+
+	created, err := s.expander.ExpandDueRecurrences(ctx, utils.UTCNow().Add(s.lookahead))
+	if err != nil {
+		s.logger.Printf("campaign recurrence scheduler: expand failed: %v", err)
+	}
+	if created > 0 {
+		s.logger.Printf("campaign recurrence scheduler: created %d occurrences", created)
+	}
+}
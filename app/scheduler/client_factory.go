@@ -6,9 +6,10 @@ import (
 	"github.com/amirphl/Yamata-no-Orochi/config"
 )
 
-// NewBotClient creates a new bot API client instance.
+// NewBotClient creates a new bot API client instance. It calls the internal
+// gRPC API when cfg.GRPCAddress is set and the REST API otherwise.
 func NewBotClient(cfg config.BotConfig) BotClient {
-	return newHTTPBotClient(cfg)
+	return newBotClient(cfg)
 }
 
 // NewPayamSMSClient creates a new PayamSMS client instance.
@@ -19,7 +20,7 @@ func NewPayamSMSClient(cfg config.PayamSMSConfig) PayamSMSClient {
 // NewPayamSMSClientWithHTTPSProxy creates a new PayamSMS client that routes
 // requests through the provided HTTPS proxy URL.
 func NewPayamSMSClientWithHTTPSProxy(cfg config.PayamSMSConfig, proxyURL string) (PayamSMSClient, error) {
-	client, err := newHTTPClientWithHTTPSProxy(60*time.Second, proxyURL)
+	client, err := newHTTPClientWithHTTPSProxy("payamsms", 60*time.Second, proxyURL)
 	if err != nil {
 		return nil, err
 	}
@@ -34,7 +35,7 @@ func NewBaleClient(cfg config.BaleConfig) BaleClient {
 // NewBaleClientWithHTTPSProxy creates a new Bale client that routes requests
 // through the provided HTTPS proxy URL.
 func NewBaleClientWithHTTPSProxy(cfg config.BaleConfig, proxyURL string) (BaleClient, error) {
-	client, err := newHTTPClientWithHTTPSProxy(60*time.Second, proxyURL)
+	client, err := newHTTPClientWithHTTPSProxy("bale", 60*time.Second, proxyURL)
 	if err != nil {
 		return nil, err
 	}
@@ -49,7 +50,7 @@ func NewRubikaClient(cfg config.RubikaConfig) RubikaClient {
 // NewRubikaClientWithHTTPSProxy creates a new Rubika client that routes
 // requests through the provided HTTPS proxy URL.
 func NewRubikaClientWithHTTPSProxy(cfg config.RubikaConfig, proxyURL string) (RubikaClient, error) {
-	client, err := newHTTPClientWithHTTPSProxy(60*time.Second, proxyURL)
+	client, err := newHTTPClientWithHTTPSProxy("rubika", 60*time.Second, proxyURL)
 	if err != nil {
 		return nil, err
 	}
@@ -64,7 +65,7 @@ func NewSplusClient(cfg config.SplusConfig) SplusClient {
 // NewSplusClientWithHTTPSProxy creates a new Splus client that routes requests
 // through the provided HTTPS proxy URL.
 func NewSplusClientWithHTTPSProxy(cfg config.SplusConfig, proxyURL string) (SplusClient, error) {
-	client, err := newHTTPClientWithHTTPSProxy(60*time.Second, proxyURL)
+	client, err := newHTTPClientWithHTTPSProxy("splus", 60*time.Second, proxyURL)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,82 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// CryptoPaymentExpirer expires pending crypto payment requests past their
+// payment window
+type CryptoPaymentExpirer interface {
+	ExpireStaleRequests(ctx context.Context) (int, error)
+}
+
+// CryptoExpiryScheduler periodically expires stale crypto payment requests,
+// so they do not stay pending until the customer polls them.
+type CryptoExpiryScheduler struct {
+	expirer      CryptoPaymentExpirer
+	logger       *log.Logger
+	pollInterval time.Duration
+}
+
+func NewCryptoExpiryScheduler(
+	expirer CryptoPaymentExpirer,
+	logger *log.Logger,
+	pollInterval time.Duration,
+) *CryptoExpiryScheduler {
+	if pollInterval <= 0 {
+		pollInterval = time.Minute
+	}
+	if logger == nil {
+		logger = log.Default()
+	}
+	return &CryptoExpiryScheduler{
+		expirer:      expirer,
+		logger:       logger,
+		pollInterval: pollInterval,
+	}
+}
+
+func (s *CryptoExpiryScheduler) Start(parent context.Context) func() {
+	workerCtx, cancel := context.WithCancel(parent)
+	var wg sync.WaitGroup
+	var stopOnce sync.Once
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(s.pollInterval)
+		defer ticker.Stop()
+
+		s.runOnce(workerCtx)
+		for {
+			select {
+			case <-workerCtx.Done():
+				return
+			case <-ticker.C:
+				s.runOnce(workerCtx)
+			}
+		}
+	}()
+	return func() {
+		stopOnce.Do(func() {
+			cancel()
+			wg.Wait()
+		})
+	}
+}
+
+func (s *CryptoExpiryScheduler) runOnce(parent context.Context) {
+	ctx, cancel := context.WithTimeout(parent, 5*time.Minute)
+	defer cancel()
+
+	expired, err := s.expirer.ExpireStaleRequests(ctx)
+	if err != nil {
+		s.logger.Printf("crypto expiry scheduler: %v", err)
+	}
+	if expired > 0 {
+		s.logger.Printf("crypto expiry scheduler: expired %d requests", expired)
+	}
+}
@@ -0,0 +1,92 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// CustomerDataProcessor carries out queued customer data exports and account
+// deletions and removes expired export files
+type CustomerDataProcessor interface {
+	ProcessNextRequest(ctx context.Context) (bool, error)
+	PurgeExpiredExports(ctx context.Context) (int, error)
+}
+
+// CustomerDataScheduler periodically drains the queue of customer data
+// requests, one request at a time.
+type CustomerDataScheduler struct {
+	processor    CustomerDataProcessor
+	logger       *log.Logger
+	pollInterval time.Duration
+}
+
+func NewCustomerDataScheduler(
+	processor CustomerDataProcessor,
+	logger *log.Logger,
+	pollInterval time.Duration,
+) *CustomerDataScheduler {
+	if pollInterval <= 0 {
+		pollInterval = time.Minute
+	}
+	if logger == nil {
+		logger = log.Default()
+	}
+	return &CustomerDataScheduler{
+		processor:    processor,
+		logger:       logger,
+		pollInterval: pollInterval,
+	}
+}
+
+func (s *CustomerDataScheduler) Start(parent context.Context) func() {
+	workerCtx, cancel := context.WithCancel(parent)
+	var wg sync.WaitGroup
+	var stopOnce sync.Once
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(s.pollInterval)
+		defer ticker.Stop()
+
+		s.runOnce(workerCtx)
+		for {
+			select {
+			case <-workerCtx.Done():
+				return
+			case <-ticker.C:
+				s.runOnce(workerCtx)
+			}
+		}
+	}()
+	return func() {
+		stopOnce.Do(func() {
+			cancel()
+			wg.Wait()
+		})
+	}
+}
+
+func (s *CustomerDataScheduler) runOnce(parent context.Context) {
+	purgeCtx, cancelPurge := context.WithTimeout(parent, time.Minute)
+	if purged, err := s.processor.PurgeExpiredExports(purgeCtx); err != nil {
+		s.logger.Printf("customer data scheduler: purge expired exports: %v", err)
+	} else if purged > 0 {
+		s.logger.Printf("customer data scheduler: removed %d expired exports", purged)
+	}
+	cancelPurge()
+
+	for parent.Err() == nil {
+		ctx, cancel := context.WithTimeout(parent, 10*time.Minute)
+		claimed, err := s.processor.ProcessNextRequest(ctx)
+		cancel()
+		if err != nil {
+			s.logger.Printf("customer data scheduler: %v", err)
+		}
+		if !claimed {
+			return
+		}
+	}
+}
@@ -0,0 +1,296 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/app/grpcapi/internalv1"
+	"github.com/amirphl/Yamata-no-Orochi/config"
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// grpcBotClient calls the internal gRPC API. Target-audience Excel files and
+// campaign media are still downloaded over REST with the same access token.
+type grpcBotClient struct {
+	*httpBotClient
+	conn       *grpc.ClientConn
+	auth       internalv1.BotAuthServiceClient
+	campaigns  internalv1.CampaignLifecycleServiceClient
+	shortLinks internalv1.ShortLinkServiceClient
+}
+
+func newGRPCBotClient(cfg config.BotConfig) (*grpcBotClient, error) {
+	conn, err := grpc.NewClient(cfg.GRPCAddress, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("dial bot grpc api: %w", err)
+	}
+	return &grpcBotClient{
+		httpBotClient: newHTTPBotClient(cfg),
+		conn:          conn,
+		auth:          internalv1.NewBotAuthServiceClient(conn),
+		campaigns:     internalv1.NewCampaignLifecycleServiceClient(conn),
+		shortLinks:    internalv1.NewShortLinkServiceClient(conn),
+	}, nil
+}
+
+// newBotClient returns a gRPC client when the bot config names a gRPC
+// address and a REST client otherwise
+func newBotClient(cfg config.BotConfig) BotClient {
+	if strings.TrimSpace(cfg.GRPCAddress) == "" {
+		return newHTTPBotClient(cfg)
+	}
+	client, err := newGRPCBotClient(cfg)
+	if err != nil {
+		log.Printf("bot client: %v; falling back to the REST API", err)
+		return newHTTPBotClient(cfg)
+	}
+	return client
+}
+
+func withBotToken(ctx context.Context, token string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+}
+
+func (c *grpcBotClient) Login(ctx context.Context) (string, error) {
+	if c.cfg.Username == "" || c.cfg.Password == "" {
+		return "", fmt.Errorf("bot credentials not configured")
+	}
+	resp, err := c.auth.Login(ctx, &internalv1.LoginRequest{Username: c.cfg.Username, Password: c.cfg.Password})
+	if err != nil {
+		return "", fmt.Errorf("bot login: %w", err)
+	}
+	if resp.GetAccessToken() == "" {
+		return "", fmt.Errorf("empty bot access token")
+	}
+	return resp.GetAccessToken(), nil
+}
+
+func (c *grpcBotClient) ListReadyCampaigns(ctx context.Context, token string, platform string) ([]dto.BotGetCampaignResponse, error) {
+	resp, err := c.campaigns.ListReadyCampaigns(withBotToken(ctx, token), &internalv1.ListReadyCampaignsRequest{Platform: platform})
+	if err != nil {
+		return nil, fmt.Errorf("list ready campaigns: %w", err)
+	}
+	items := make([]dto.BotGetCampaignResponse, 0, len(resp.GetCampaigns()))
+	for _, pc := range resp.GetCampaigns() {
+		item, err := campaignFromProto(pc)
+		if err != nil {
+			return nil, fmt.Errorf("list ready campaigns: campaign %d: %w", pc.GetId(), err)
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func (c *grpcBotClient) MoveCampaignToRunning(ctx context.Context, token string, id uint) error {
+	if _, err := c.campaigns.MoveToRunning(withBotToken(ctx, token), &internalv1.MoveCampaignRequest{CampaignId: uint64(id)}); err != nil {
+		return fmt.Errorf("move to running: %w", err)
+	}
+	return nil
+}
+
+func (c *grpcBotClient) MoveCampaignToExecuted(ctx context.Context, token string, id uint) error {
+	if _, err := c.campaigns.MoveToExecuted(withBotToken(ctx, token), &internalv1.MoveCampaignRequest{CampaignId: uint64(id)}); err != nil {
+		return fmt.Errorf("move to executed: %w", err)
+	}
+	return nil
+}
+
+func (c *grpcBotClient) AllocateShortLinks(ctx context.Context, token string, req *dto.BotAllocateShortLinksRequest) ([]string, error) {
+	in := &internalv1.AllocateShortLinksRequest{
+		CampaignId:      uint64(req.CampaignID),
+		Items:           make([]*internalv1.PhoneAdLink, len(req.Items)),
+		ShortLinkDomain: req.ShortLinkDomain,
+	}
+	for i, it := range req.Items {
+		in.Items[i] = &internalv1.PhoneAdLink{Phone: it.Phone, AdLink: it.AdLink}
+	}
+	resp, err := c.shortLinks.AllocateShortLinks(withBotToken(ctx, token), in)
+	if err != nil {
+		return nil, fmt.Errorf("allocate short-links: %w", err)
+	}
+	return resp.GetCodes(), nil
+}
+
+func (c *grpcBotClient) PushCampaignStatistics(ctx context.Context, campaignID uint, stats map[string]any) error {
+	token, err := c.Login(ctx)
+	if err != nil {
+		return err
+	}
+	statistics, err := structFromMap(stats)
+	if err != nil {
+		return fmt.Errorf("push statistics: %w", err)
+	}
+	if _, err := c.campaigns.UpdateStatistics(withBotToken(ctx, token), &internalv1.UpdateStatisticsRequest{
+		CampaignId: uint64(campaignID),
+		Statistics: statistics,
+	}); err != nil {
+		return fmt.Errorf("push statistics: %w", err)
+	}
+	return nil
+}
+
+// PushCampaignAudienceUIDs sends the UIDs in chunks of audienceUIDChunkSize
+// with one login, like the REST client
+func (c *grpcBotClient) PushCampaignAudienceUIDs(ctx context.Context, campaignID uint, uids, codes []string) error {
+	if len(uids) == 0 {
+		return nil
+	}
+	token, err := c.Login(ctx)
+	if err != nil {
+		return fmt.Errorf("push audience UIDs login: %w", err)
+	}
+	authCtx := withBotToken(ctx, token)
+	for start := 0; start < len(uids); start += audienceUIDChunkSize {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("push audience UIDs context cancelled at offset %d: %w", start, err)
+		}
+		end := min(start+audienceUIDChunkSize, len(uids))
+		items := make([]*internalv1.AudienceUID, end-start)
+		for i, uid := range uids[start:end] {
+			code := ""
+			if start+i < len(codes) {
+				code = codes[start+i]
+			}
+			items[i] = &internalv1.AudienceUID{Uid: uid, Code: code}
+		}
+		if _, err := c.campaigns.PushAudienceUIDs(authCtx, &internalv1.PushAudienceUIDsRequest{
+			CampaignId: uint64(campaignID),
+			Items:      items,
+		}); err != nil {
+			return fmt.Errorf("push audience UIDs chunk [%d,%d): %w", start, end, err)
+		}
+	}
+	return nil
+}
+
+func (c *grpcBotClient) CreateShortLinks(ctx context.Context, token string, reqBody *dto.BotCreateShortLinksRequest) error {
+	if reqBody == nil {
+		return fmt.Errorf("create short-links request body is nil")
+	}
+	in := &internalv1.CreateShortLinksRequest{Items: make([]*internalv1.NewShortLink, len(reqBody.Items))}
+	for i, it := range reqBody.Items {
+		in.Items[i] = &internalv1.NewShortLink{
+			Uid:         it.UID,
+			CampaignId:  uint64Ptr(it.CampaignID),
+			ClientId:    uint64Ptr(it.ClientID),
+			PhoneNumber: it.PhoneNumber,
+			LongLink:    it.LongLink,
+			ShortLink:   it.ShortLink,
+		}
+	}
+	if _, err := c.shortLinks.CreateShortLinks(withBotToken(ctx, token), in); err != nil {
+		return fmt.Errorf("create short-links: %w", err)
+	}
+	return nil
+}
+
+// campaignFromProto converts a ready campaign back to the shape the REST API
+// lists, which the schedulers work with
+func campaignFromProto(pc *internalv1.Campaign) (dto.BotGetCampaignResponse, error) {
+	out := dto.BotGetCampaignResponse{
+		ID:                          uint(pc.GetId()),
+		CustomerID:                  uint(pc.GetCustomerId()),
+		Hidden:                      pc.GetHidden(),
+		Sandbox:                     pc.GetSandbox(),
+		Status:                      pc.GetStatus(),
+		CreatedAt:                   pc.GetCreatedAt().AsTime(),
+		UpdatedAt:                   timePtr(pc.GetUpdatedAt()),
+		Title:                       pc.Title,
+		Level1:                      pc.Level1,
+		Level2s:                     pc.GetLevel2S(),
+		Level3s:                     pc.GetLevel3S(),
+		Tags:                        pc.GetTags(),
+		Sex:                         pc.Sex,
+		City:                        pc.GetCity(),
+		AdLink:                      pc.AdLink,
+		Content:                     pc.Content,
+		ShortLinkDomain:             pc.ShortLinkDomain,
+		Category:                    pc.JobCategory,
+		Job:                         pc.Job,
+		ScheduleAt:                  timePtr(pc.GetScheduleAt()),
+		LineNumber:                  pc.LineNumber,
+		PlatformSettingsID:          uintPtr(pc.PlatformSettingsId),
+		Platform:                    pc.GetPlatform(),
+		PlatformBasePrice:           pc.PlatformBasePrice,
+		Budget:                      pc.Budget,
+		Comment:                     pc.Comment,
+		NumAudiences:                pc.NumAudiences,
+		BundleID:                    uintPtr(pc.BundleId),
+		Phase:                       pc.Phase,
+		AudienceGrades:              pc.GetAudienceGrades(),
+		TargetAudienceExcelFileUUID: pc.TargetAudienceExcelFileUuid,
+	}
+	if pc.MediaUuid != nil {
+		mediaUUID, err := uuid.Parse(pc.GetMediaUuid())
+		if err != nil {
+			return out, fmt.Errorf("invalid media uuid: %w", err)
+		}
+		out.MediaUUID = &mediaUUID
+	}
+	if ps := pc.GetPlatformSettings(); ps != nil {
+		out.PlatformSettings = &dto.BotCampaignPlatformSettingsSpec{
+			ID:           uint(ps.GetId()),
+			Platform:     ps.GetPlatform(),
+			Name:         ps.Name,
+			Description:  ps.Description,
+			MultimediaID: uintPtr(ps.MultimediaId),
+			Status:       ps.GetStatus(),
+		}
+		if ps.GetMetadata() != nil {
+			out.PlatformSettings.Metadata = ps.GetMetadata().AsMap()
+		}
+	}
+	for _, v := range pc.GetVariants() {
+		out.Variants = append(out.Variants, dto.CampaignContentVariantSpec{Label: v.GetLabel(), Content: v.GetContent(), Weight: uint(v.GetWeight())})
+	}
+	return out, nil
+}
+
+// structFromMap converts a map through JSON, so the statistics reach the API
+// as they would over REST
+func structFromMap(m map[string]any) (*structpb.Struct, error) {
+	b, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	out := &structpb.Struct{}
+	if err := protojson.Unmarshal(b, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func timePtr(ts *timestamppb.Timestamp) *time.Time {
+	if ts == nil {
+		return nil
+	}
+	t := ts.AsTime()
+	return &t
+}
+
+func uintPtr(v *uint64) *uint {
+	if v == nil {
+		return nil
+	}
+	out := uint(*v)
+	return &out
+}
+
+func uint64Ptr(v *uint) *uint64 {
+	if v == nil {
+		return nil
+	}
+	out := uint64(*v)
+	return &out
+}
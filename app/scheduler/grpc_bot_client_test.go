@@ -0,0 +1,177 @@
+package scheduler
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/grpcapi/internalv1"
+	"github.com/amirphl/Yamata-no-Orochi/config"
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type fakeInternalAPI struct {
+	internalv1.UnimplementedBotAuthServiceServer
+	internalv1.UnimplementedCampaignLifecycleServiceServer
+
+	mu         sync.Mutex
+	campaigns  []*internalv1.Campaign
+	chunks     []int
+	statistics map[string]any
+}
+
+func (f *fakeInternalAPI) Login(ctx context.Context, req *internalv1.LoginRequest) (*internalv1.LoginResponse, error) {
+	return &internalv1.LoginResponse{AccessToken: "token-of-" + req.GetUsername()}, nil
+}
+
+func (f *fakeInternalAPI) authorize(ctx context.Context) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if v := md.Get("authorization"); len(v) == 0 || v[0] != "Bearer token-of-scheduler" {
+		return status.Error(codes.Unauthenticated, "missing token")
+	}
+	return nil
+}
+
+func (f *fakeInternalAPI) ListReadyCampaigns(ctx context.Context, req *internalv1.ListReadyCampaignsRequest) (*internalv1.ListReadyCampaignsResponse, error) {
+	if err := f.authorize(ctx); err != nil {
+		return nil, err
+	}
+	return &internalv1.ListReadyCampaignsResponse{Campaigns: f.campaigns}, nil
+}
+
+func (f *fakeInternalAPI) UpdateStatistics(ctx context.Context, req *internalv1.UpdateStatisticsRequest) (*internalv1.UpdateStatisticsResponse, error) {
+	if err := f.authorize(ctx); err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.statistics = req.GetStatistics().AsMap()
+	return &internalv1.UpdateStatisticsResponse{}, nil
+}
+
+func (f *fakeInternalAPI) PushAudienceUIDs(ctx context.Context, req *internalv1.PushAudienceUIDsRequest) (*internalv1.PushAudienceUIDsResponse, error) {
+	if err := f.authorize(ctx); err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.chunks = append(f.chunks, len(req.GetItems()))
+	return &internalv1.PushAudienceUIDsResponse{}, nil
+}
+
+func startFakeInternalAPI(t *testing.T, api *fakeInternalAPI) *grpcBotClient {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := grpc.NewServer()
+	internalv1.RegisterBotAuthServiceServer(srv, api)
+	internalv1.RegisterCampaignLifecycleServiceServer(srv, api)
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	client, err := newGRPCBotClient(config.BotConfig{Username: "scheduler", Password: "secret-password", GRPCAddress: lis.Addr().String()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.conn.Close() })
+	return client
+}
+
+func TestNewBotClientPicksTransport(t *testing.T) {
+	t.Parallel()
+
+	if _, ok := newBotClient(config.BotConfig{}).(*httpBotClient); !ok {
+		t.Fatal("expected the REST client without a gRPC address")
+	}
+	if _, ok := newBotClient(config.BotConfig{GRPCAddress: "127.0.0.1:9091"}).(*grpcBotClient); !ok {
+		t.Fatal("expected the gRPC client with a gRPC address")
+	}
+}
+
+func TestGRPCBotClientListsReadyCampaigns(t *testing.T) {
+	t.Parallel()
+
+	mediaUUID := uuid.New()
+	media := mediaUUID.String()
+	title := "Nowruz sale"
+	bundleID := uint64(9)
+	api := &fakeInternalAPI{campaigns: []*internalv1.Campaign{{
+		Id:        12,
+		Platform:  "sms",
+		Title:     &title,
+		MediaUuid: &media,
+		BundleId:  &bundleID,
+		Variants:  []*internalv1.ContentVariant{{Label: "A", Content: "hi", Weight: 60}},
+	}}}
+	client := startFakeInternalAPI(t, api)
+
+	token, err := client.Login(context.Background())
+	if err != nil {
+		t.Fatalf("login failed: %v", err)
+	}
+	items, err := client.ListReadyCampaigns(context.Background(), token, "sms")
+	if err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	if len(items) != 1 {
+		t.Fatalf("expected 1 campaign, got %d", len(items))
+	}
+	c := items[0]
+	if c.ID != 12 || c.Title == nil || *c.Title != title || c.MediaUUID == nil || *c.MediaUUID != mediaUUID {
+		t.Fatalf("unexpected campaign %+v", c)
+	}
+	if c.BundleID == nil || *c.BundleID != 9 || c.ScheduleAt != nil || c.PlatformSettings != nil {
+		t.Fatalf("unexpected optional fields %+v", c)
+	}
+	if len(c.Variants) != 1 || c.Variants[0].Weight != 60 {
+		t.Fatalf("unexpected variants %+v", c.Variants)
+	}
+
+	if _, err := client.ListReadyCampaigns(context.Background(), "stale", "sms"); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("expected Unauthenticated with a bad token, got %v", err)
+	}
+}
+
+func TestGRPCBotClientPushesAudienceUIDsInChunks(t *testing.T) {
+	t.Parallel()
+
+	api := &fakeInternalAPI{}
+	client := startFakeInternalAPI(t, api)
+
+	uids := make([]string, audienceUIDChunkSize+1)
+	for i := range uids {
+		uids[i] = strconv.Itoa(i)
+	}
+	if err := client.PushCampaignAudienceUIDs(context.Background(), 12, uids, nil); err != nil {
+		t.Fatalf("push failed: %v", err)
+	}
+	if len(api.chunks) != 2 || api.chunks[0] != audienceUIDChunkSize || api.chunks[1] != 1 {
+		t.Fatalf("unexpected chunks %v", api.chunks)
+	}
+}
+
+func TestGRPCBotClientPushesStatistics(t *testing.T) {
+	t.Parallel()
+
+	api := &fakeInternalAPI{}
+	client := startFakeInternalAPI(t, api)
+
+	stats := map[string]any{"sent": uint64(120), "failed_phones": []string{"0912"}}
+	if err := client.PushCampaignStatistics(context.Background(), 12, stats); err != nil {
+		t.Fatalf("push failed: %v", err)
+	}
+	if api.statistics["sent"] != float64(120) {
+		t.Fatalf("unexpected statistics %v", api.statistics)
+	}
+	if phones, _ := api.statistics["failed_phones"].([]any); len(phones) != 1 || phones[0] != "0912" {
+		t.Fatalf("unexpected statistics %v", api.statistics)
+	}
+}
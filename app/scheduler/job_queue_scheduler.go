@@ -0,0 +1,163 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/config"
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// jobPurgeInterval is how often succeeded jobs past retention are deleted
+const jobPurgeInterval = time.Hour
+
+// Attempts run by the job queue workers, by outcome
+var jobQueueJobsProcessed = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "job_queue_jobs_processed_total",
+		Help: "Job attempts run by the background job queue, by outcome (succeeded, failed)",
+	},
+	[]string{"outcome"},
+)
+
+// JobProcessor runs queued background jobs
+type JobProcessor interface {
+	ProcessNextJob(ctx context.Context) (bool, error)
+	PurgeSucceededJobs(ctx context.Context) (int64, error)
+}
+
+// JobQueueScheduler runs a pool of workers that drain the background job
+// queue, and periodically deletes old succeeded jobs.
+type JobQueueScheduler struct {
+	processor    JobProcessor
+	logger       *log.Logger
+	pollInterval time.Duration
+	workers      int
+}
+
+func NewJobQueueScheduler(
+	processor JobProcessor,
+	logger *log.Logger,
+	pollInterval time.Duration,
+	workers int,
+) *JobQueueScheduler {
+	if pollInterval <= 0 {
+		pollInterval = 5 * time.Second
+	}
+	if workers <= 0 {
+		workers = 1
+	}
+	if logger == nil {
+		logger = log.Default()
+	}
+	return &JobQueueScheduler{
+		processor:    processor,
+		logger:       logger,
+		pollInterval: pollInterval,
+		workers:      workers,
+	}
+}
+
+func (s *JobQueueScheduler) Start(parent context.Context) func() {
+	workerCtx, cancel := context.WithCancel(parent)
+	var wg sync.WaitGroup
+	var stopOnce sync.Once
+
+	for range s.workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ticker := time.NewTicker(s.pollInterval)
+			defer ticker.Stop()
+
+			s.runOnce(workerCtx)
+			for {
+				select {
+				case <-workerCtx.Done():
+					return
+				case <-ticker.C:
+					s.runOnce(workerCtx)
+				}
+			}
+		}()
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(jobPurgeInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-workerCtx.Done():
+				return
+			case <-ticker.C:
+				s.purge(workerCtx)
+			}
+		}
+	}()
+	return func() {
+		stopOnce.Do(func() {
+			cancel()
+			wg.Wait()
+		})
+	}
+}
+
+// runOnce runs due jobs until the queue has none left
+func (s *JobQueueScheduler) runOnce(parent context.Context) {
+	for parent.Err() == nil {
+		ctx, cancel := context.WithTimeout(parent, 30*time.Minute)
+		claimed, err := s.processor.ProcessNextJob(ctx)
+		cancel()
+		if claimed {
+			outcome := "succeeded"
+			if err != nil {
+				outcome = "failed"
+			}
+			jobQueueJobsProcessed.WithLabelValues(outcome).Inc()
+		}
+		if err != nil {
+			s.logger.Printf("job queue scheduler: %v", err)
+		}
+		if !claimed {
+			return
+		}
+	}
+}
+
+func (s *JobQueueScheduler) purge(parent context.Context) {
+	ctx, cancel := context.WithTimeout(parent, 5*time.Minute)
+	defer cancel()
+	deleted, err := s.processor.PurgeSucceededJobs(ctx)
+	if err != nil {
+		s.logger.Printf("job queue scheduler: purge succeeded jobs: %v", err)
+		return
+	}
+	if deleted > 0 {
+		s.logger.Printf("job queue scheduler: purged %d succeeded jobs", deleted)
+	}
+}
+
+// NewPushAudienceUIDsJobHandler pushes the audience of a sent campaign to the
+// bot, for push_audience_uids jobs
+func NewPushAudienceUIDsJobHandler(botCfg config.BotConfig) func(ctx context.Context, payload json.RawMessage) error {
+	if botCfg.APIDomain == "" {
+		botCfg.APIDomain = defaultBotAPIDomain
+	}
+	client := newBotClient(botCfg)
+	return func(ctx context.Context, payload json.RawMessage) error {
+		var job models.PushAudienceUIDsJob
+		if err := json.Unmarshal(payload, &job); err != nil {
+			return fmt.Errorf("decode push_audience_uids payload: %w", err)
+		}
+		return client.PushCampaignAudienceUIDs(ctx, job.CampaignID, job.UIDs, job.Codes)
+	}
+}
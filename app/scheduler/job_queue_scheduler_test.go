@@ -0,0 +1,51 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// fakeJobProcessor plays back a fixed sequence of ProcessNextJob results
+type fakeJobProcessor struct {
+	results []error
+	calls   int
+}
+
+func (p *fakeJobProcessor) ProcessNextJob(context.Context) (bool, error) {
+	if p.calls >= len(p.results) {
+		p.calls++
+		return false, nil
+	}
+	err := p.results[p.calls]
+	p.calls++
+	return true, err
+}
+
+func (p *fakeJobProcessor) PurgeSucceededJobs(context.Context) (int64, error) {
+	return 0, nil
+}
+
+func TestJobQueueSchedulerDrainsDueJobs(t *testing.T) {
+	succeeded := jobQueueJobsProcessed.WithLabelValues("succeeded")
+	failed := jobQueueJobsProcessed.WithLabelValues("failed")
+	beforeSucceeded, beforeFailed := testutil.ToFloat64(succeeded), testutil.ToFloat64(failed)
+
+	processor := &fakeJobProcessor{results: []error{nil, errors.New("provider down"), nil}}
+	NewJobQueueScheduler(processor, log.New(io.Discard, "", 0), 0, 0).runOnce(context.Background())
+
+	// A failed job does not stop the drain; the empty claim does
+	if processor.calls != 4 {
+		t.Fatalf("ProcessNextJob calls = %d, want 4", processor.calls)
+	}
+	if got := testutil.ToFloat64(succeeded) - beforeSucceeded; got != 2 {
+		t.Fatalf("succeeded = %v, want 2", got)
+	}
+	if got := testutil.ToFloat64(failed) - beforeFailed; got != 1 {
+		t.Fatalf("failed = %v, want 1", got)
+	}
+}
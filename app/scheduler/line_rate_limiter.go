@@ -0,0 +1,118 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"time"
+)
+
+// lineRateLimiter throttles sends with a token bucket per line number. One
+// limiter is shared by every campaign of a scheduler, so campaigns sending
+// concurrently from the same line share its provider throughput. Lines
+// without a configured rate are unlimited.
+type lineRateLimiter struct {
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+	now     func() time.Time
+}
+
+type tokenBucket struct {
+	rate   float64 // tokens added per second
+	burst  float64 // bucket capacity
+	tokens float64
+	last   time.Time
+}
+
+func newLineRateLimiter() *lineRateLimiter {
+	return &lineRateLimiter{
+		buckets: make(map[string]*tokenBucket),
+		now:     time.Now,
+	}
+}
+
+// configure sets the limit of a line in messages per second. A zero rate
+// removes the limit. Tokens already in the bucket are kept, capped at the new
+// burst, so reconfiguring between campaigns does not reset throttling.
+func (l *lineRateLimiter) configure(line string, rate, burst int) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if rate <= 0 {
+		delete(l.buckets, line)
+		return
+	}
+	if burst < 1 {
+		burst = rate
+	}
+	b, ok := l.buckets[line]
+	if !ok {
+		l.buckets[line] = &tokenBucket{rate: float64(rate), burst: float64(burst), tokens: float64(burst), last: l.now()}
+		return
+	}
+	b.refill(l.now())
+	b.rate = float64(rate)
+	b.burst = float64(burst)
+	b.tokens = math.Min(b.tokens, b.burst)
+}
+
+// take grants up to n messages on the first of lines that can send right
+// away. When every line is saturated it grants nothing and returns how long
+// to wait until one of them can send again.
+func (l *lineRateLimiter) take(lines []string, n int) (string, int, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	wait := time.Duration(math.MaxInt64)
+	for _, line := range lines {
+		b, ok := l.buckets[line]
+		if !ok {
+			return line, n, 0
+		}
+		b.refill(now)
+		if b.tokens >= 1 {
+			granted := min(n, int(b.tokens))
+			b.tokens -= float64(granted)
+			return line, granted, 0
+		}
+		wait = min(wait, time.Duration((1-b.tokens)/b.rate*float64(time.Second)))
+	}
+	return "", 0, wait
+}
+
+// acquire blocks until one of lines can send and grants up to n messages on
+// it. Lines are tried in order, so later lines only receive traffic while the
+// earlier ones are saturated. A nil limiter grants everything on the first line.
+func (l *lineRateLimiter) acquire(ctx context.Context, lines []string, n int) (string, int, error) {
+	if len(lines) == 0 {
+		return "", 0, errors.New("no sender lines")
+	}
+	if l == nil {
+		return lines[0], n, nil
+	}
+	for {
+		line, granted, wait := l.take(lines, n)
+		if granted > 0 {
+			return line, granted, nil
+		}
+		timer := time.NewTimer(max(wait, time.Millisecond))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return "", 0, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (b *tokenBucket) refill(now time.Time) {
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(b.burst, b.tokens+elapsed*b.rate)
+	}
+	b.last = now
+}
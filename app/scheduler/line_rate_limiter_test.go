@@ -0,0 +1,71 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLineRateLimiterSpillsOverWhenSaturated(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	l := newLineRateLimiter()
+	l.now = func() time.Time { return now }
+	l.configure("1000", 10, 20)
+	l.configure("2000", 5, 0)
+
+	lines := []string{"1000", "2000"}
+	line, n, _ := l.take(lines, 50)
+	if line != "1000" || n != 20 {
+		t.Fatalf("expected burst of 20 on the primary line, got %d on %s", n, line)
+	}
+	line, n, _ = l.take(lines, 30)
+	if line != "2000" || n != 5 {
+		t.Fatalf("expected spillover of 5 to the pool line, got %d on %s", n, line)
+	}
+	_, n, wait := l.take(lines, 25)
+	if n != 0 || wait != 100*time.Millisecond {
+		t.Fatalf("expected saturation with 100ms wait, got n=%d wait=%s", n, wait)
+	}
+
+	now = now.Add(500 * time.Millisecond)
+	line, n, _ = l.take(lines, 25)
+	if line != "1000" || n != 5 {
+		t.Fatalf("expected 5 refilled tokens on the primary line, got %d on %s", n, line)
+	}
+}
+
+func TestLineRateLimiterUnlimitedLines(t *testing.T) {
+	t.Parallel()
+
+	l := newLineRateLimiter()
+	l.configure("1000", 1, 1)
+	l.configure("1000", 0, 0)
+	line, n, err := l.acquire(context.Background(), []string{"1000"}, 200)
+	if err != nil || line != "1000" || n != 200 {
+		t.Fatalf("expected unlimited line to grant everything, got %d on %s (err=%v)", n, line, err)
+	}
+
+	var nilLimiter *lineRateLimiter
+	line, n, err = nilLimiter.acquire(context.Background(), []string{"3000", "4000"}, 7)
+	if err != nil || line != "3000" || n != 7 {
+		t.Fatalf("expected nil limiter to grant everything on the first line, got %d on %s (err=%v)", n, line, err)
+	}
+}
+
+func TestLineRateLimiterAcquireHonorsContext(t *testing.T) {
+	t.Parallel()
+
+	l := newLineRateLimiter()
+	l.configure("1000", 1, 1)
+	if _, n, err := l.acquire(context.Background(), []string{"1000"}, 5); err != nil || n != 1 {
+		t.Fatalf("expected one token, got n=%d err=%v", n, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, _, err := l.acquire(ctx, []string{"1000"}, 5); err == nil {
+		t.Fatalf("expected context error while saturated")
+	}
+}
@@ -0,0 +1,115 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	// Outcomes of the last Moadian submission run
+	moadianSubmissionLastRun = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "moadian_submission_last_run",
+			Help: "Tax invoices handled by the last Moadian submission run, by result (enqueued, submitted, accepted, rejected, retried, failed)",
+		},
+		[]string{"result"},
+	)
+
+	// When the submission queue was last processed without error
+	moadianSubmissionLastSuccess = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "moadian_submission_last_success_timestamp_seconds",
+			Help: "Unix time the Moadian submission queue was last processed without error",
+		},
+	)
+)
+
+// MoadianSubmitter submits due tax invoices to Moadian and looks up the
+// results of earlier submissions
+type MoadianSubmitter interface {
+	ProcessSubmissions(ctx context.Context, now time.Time) (*dto.MoadianSubmissionSummary, error)
+}
+
+// MoadianSubmissionScheduler periodically works through the Moadian
+// submission queue. Retries are scheduled on the queue itself, so a run
+// only picks up what is due.
+type MoadianSubmissionScheduler struct {
+	submitter    MoadianSubmitter
+	logger       *log.Logger
+	pollInterval time.Duration
+}
+
+func NewMoadianSubmissionScheduler(
+	submitter MoadianSubmitter,
+	logger *log.Logger,
+	pollInterval time.Duration,
+) *MoadianSubmissionScheduler {
+	if pollInterval <= 0 {
+		pollInterval = 5 * time.Minute
+	}
+	if logger == nil {
+		logger = log.Default()
+	}
+	return &MoadianSubmissionScheduler{
+		submitter:    submitter,
+		logger:       logger,
+		pollInterval: pollInterval,
+	}
+}
+
+func (s *MoadianSubmissionScheduler) Start(parent context.Context) func() {
+	workerCtx, cancel := context.WithCancel(parent)
+	var wg sync.WaitGroup
+	var stopOnce sync.Once
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(s.pollInterval)
+		defer ticker.Stop()
+
+		s.runOnce(workerCtx)
+		for {
+			select {
+			case <-workerCtx.Done():
+				return
+			case <-ticker.C:
+				s.runOnce(workerCtx)
+			}
+		}
+	}()
+	return func() {
+		stopOnce.Do(func() {
+			cancel()
+			wg.Wait()
+		})
+	}
+}
+
+func (s *MoadianSubmissionScheduler) runOnce(parent context.Context) {
+	ctx, cancel := context.WithTimeout(parent, 5*time.Minute)
+	defer cancel()
+
+	summary, err := s.submitter.ProcessSubmissions(ctx, time.Now())
+	if err != nil {
+		s.logger.Printf("moadian submission scheduler: %v", err)
+		return
+	}
+	moadianSubmissionLastRun.WithLabelValues("enqueued").Set(float64(summary.Enqueued))
+	moadianSubmissionLastRun.WithLabelValues("submitted").Set(float64(summary.Submitted))
+	moadianSubmissionLastRun.WithLabelValues("accepted").Set(float64(summary.Accepted))
+	moadianSubmissionLastRun.WithLabelValues("rejected").Set(float64(summary.Rejected))
+	moadianSubmissionLastRun.WithLabelValues("retried").Set(float64(summary.Retried))
+	moadianSubmissionLastRun.WithLabelValues("failed").Set(float64(summary.Failed))
+	moadianSubmissionLastSuccess.SetToCurrentTime()
+	if summary.Rejected > 0 || summary.Failed > 0 {
+		s.logger.Printf("moadian submission scheduler: %d invoices rejected and %d failed (%+v)",
+			summary.Rejected, summary.Failed, *summary)
+	}
+}
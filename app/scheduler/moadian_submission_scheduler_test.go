@@ -0,0 +1,36 @@
+package scheduler
+
+import (
+	"context"
+	"io"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+type fakeMoadianSubmitter struct {
+	runs int
+}
+
+func (s *fakeMoadianSubmitter) ProcessSubmissions(_ context.Context, _ time.Time) (*dto.MoadianSubmissionSummary, error) {
+	s.runs++
+	return &dto.MoadianSubmissionSummary{Enqueued: 4, Submitted: 3, Accepted: 2, Retried: 1}, nil
+}
+
+func TestMoadianSubmissionSchedulerRecordsRun(t *testing.T) {
+	submitter := &fakeMoadianSubmitter{}
+	NewMoadianSubmissionScheduler(submitter, log.New(io.Discard, "", 0), 0).runOnce(context.Background())
+
+	if submitter.runs != 1 {
+		t.Fatalf("runs = %d, want 1", submitter.runs)
+	}
+	if got := testutil.ToFloat64(moadianSubmissionLastRun.WithLabelValues("submitted")); got != 3 {
+		t.Fatalf("submitted = %v, want 3", got)
+	}
+	if got := testutil.ToFloat64(moadianSubmissionLastRun.WithLabelValues("retried")); got != 1 {
+		t.Fatalf("retried = %v, want 1", got)
+	}
+}
@@ -0,0 +1,111 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+)
+
+// PartitionMaintainer creates upcoming monthly partitions and archives the
+// ones past retention
+type PartitionMaintainer interface {
+	EnsureMonthlyPartitions(ctx context.Context, through time.Time) (int, error)
+	ArchivePartitionsBefore(ctx context.Context, cutoff time.Time) (int, error)
+}
+
+// PartitionMaintenanceScheduler periodically keeps monthsAhead months of
+// partitions created and archives partitions older than retentionMonths full
+// months.
+type PartitionMaintenanceScheduler struct {
+	maintainer      PartitionMaintainer
+	logger          *log.Logger
+	pollInterval    time.Duration
+	monthsAhead     int
+	retentionMonths int
+}
+
+func NewPartitionMaintenanceScheduler(
+	maintainer PartitionMaintainer,
+	logger *log.Logger,
+	pollInterval time.Duration,
+	monthsAhead int,
+	retentionMonths int,
+) *PartitionMaintenanceScheduler {
+	if pollInterval <= 0 {
+		pollInterval = 6 * time.Hour
+	}
+	if monthsAhead <= 0 {
+		monthsAhead = 3
+	}
+	if logger == nil {
+		logger = log.Default()
+	}
+	return &PartitionMaintenanceScheduler{
+		maintainer:      maintainer,
+		logger:          logger,
+		pollInterval:    pollInterval,
+		monthsAhead:     monthsAhead,
+		retentionMonths: retentionMonths,
+	}
+}
+
+func (s *PartitionMaintenanceScheduler) Start(parent context.Context) func() {
+	workerCtx, cancel := context.WithCancel(parent)
+	var wg sync.WaitGroup
+	var stopOnce sync.Once
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(s.pollInterval)
+		defer ticker.Stop()
+
+		s.runOnce(workerCtx)
+		for {
+			select {
+			case <-workerCtx.Done():
+				return
+			case <-ticker.C:
+				s.runOnce(workerCtx)
+			}
+		}
+	}()
+	return func() {
+		stopOnce.Do(func() {
+			cancel()
+			wg.Wait()
+		})
+	}
+}
+
+func (s *PartitionMaintenanceScheduler) runOnce(parent context.Context) {
+	ctx, cancel := context.WithTimeout(parent, 2*time.Hour)
+	defer cancel()
+
+	now := utils.UTCNow()
+	if _, err := s.maintainer.EnsureMonthlyPartitions(ctx, now.AddDate(0, s.monthsAhead, 0)); err != nil {
+		s.logger.Printf("partition maintenance scheduler: %v", err)
+	}
+
+	// A retention of zero keeps every partition in the database
+	if s.retentionMonths <= 0 {
+		return
+	}
+	archived, err := s.maintainer.ArchivePartitionsBefore(ctx, ArchiveCutoff(now, s.retentionMonths))
+	if err != nil {
+		s.logger.Printf("partition maintenance scheduler: %v", err)
+	}
+	if archived > 0 {
+		s.logger.Printf("partition maintenance scheduler: archived %d partitions", archived)
+	}
+}
+
+// ArchiveCutoff returns the start of the UTC month retentionMonths before the
+// month of now; partitions ending at or before it are archived
+func ArchiveCutoff(now time.Time, retentionMonths int) time.Time {
+	now = now.UTC()
+	return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, -retentionMonths, 0)
+}
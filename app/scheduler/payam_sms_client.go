@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/amirphl/Yamata-no-Orochi/config"
+	"github.com/amirphl/Yamata-no-Orochi/utils/phonenumber"
 )
 
 const (
@@ -45,9 +46,14 @@ func isPayamRetryableError(err error) bool {
 }
 
 type PayamSMSItem struct {
+	// Recipient may be in any Iranian mobile form; it is sent as 989XXXXXXXXX
 	Recipient  string
 	Body       string
 	TrackingID string
+	// Parts is the number of SMS parts Body is sent in, passed to the gateway
+	// so it charges the count the campaign was priced with; zero when unknown,
+	// leaving the count to the gateway
+	Parts uint64
 }
 
 type PayamSMSResponseItem struct {
@@ -88,12 +94,12 @@ type httpPayamSMSClient struct {
 }
 
 func newHTTPPayamSMSClient(cfg config.PayamSMSConfig) *httpPayamSMSClient {
-	return newHTTPPayamSMSClientWithClient(cfg, newHTTPClient(60*time.Second))
+	return newHTTPPayamSMSClientWithClient(cfg, newHTTPClient("payamsms", 60*time.Second))
 }
 
 func newHTTPPayamSMSClientWithClient(cfg config.PayamSMSConfig, client *http.Client) *httpPayamSMSClient {
 	if client == nil {
-		client = newHTTPClient(60 * time.Second)
+		client = newHTTPClient("payamsms", 60*time.Second)
 	}
 	return &httpPayamSMSClient{
 		cfg:    cfg,
@@ -128,6 +134,15 @@ func (c *httpPayamSMSClient) SendBatch(ctx context.Context, sender string, items
 	return out, err
 }
 
+// payamRecipient converts a stored mobile number to the gateway's
+// international form without the plus sign
+func payamRecipient(phone string) string {
+	if n, err := phonenumber.Parse(phone); err == nil {
+		return n.International()
+	}
+	return phone
+}
+
 func (c *httpPayamSMSClient) sendBatchOnce(ctx context.Context, sender string, items []PayamSMSItem, token string) ([]PayamSMSResponseItem, error) {
 	payload := struct {
 		Sender   string `json:"sender"`
@@ -143,12 +158,16 @@ func (c *httpPayamSMSClient) sendBatchOnce(ctx context.Context, sender string, i
 	// sendDate = sendDate.Add(time.Minute)
 
 	for _, it := range items {
-		payload.SMSItems = append(payload.SMSItems, map[string]any{
-			"recipient":  it.Recipient,
+		smsItem := map[string]any{
+			"recipient":  payamRecipient(it.Recipient),
 			"body":       it.Body,
 			"customerId": it.TrackingID,
 			// "sendDate":   sendDate.Format("2006-01-02 15:04:05"),
-		})
+		}
+		if it.Parts > 0 {
+			smsItem["parts"] = it.Parts
+		}
+		payload.SMSItems = append(payload.SMSItems, smsItem)
 	}
 
 	b, err := json.Marshal(payload)
@@ -2,6 +2,7 @@ package scheduler
 
 import (
 	"context"
+	"encoding/json"
 	"io"
 	"net/http"
 	"strings"
@@ -42,3 +43,43 @@ func TestPayamFetchStatusRetainsRawEmptyResponse(t *testing.T) {
 		t.Fatalf("raw response mismatch: got=%v want=%q", result.RawResponse, rawResponse)
 	}
 }
+
+func TestPayamSendBatchSendsParts(t *testing.T) {
+	t.Parallel()
+
+	var payload struct {
+		Sender   string           `json:"sender"`
+		SMSItems []map[string]any `json:"smsItems"`
+	}
+	client := newHTTPPayamSMSClientWithClient(config.PayamSMSConfig{}, &http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if err := json.NewDecoder(req.Body).Decode(&payload); err != nil {
+				t.Errorf("decode payload: %v", err)
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     make(http.Header),
+				Body:       io.NopCloser(strings.NewReader("[]")),
+				Request:    req,
+			}, nil
+		}),
+	})
+
+	items := []PayamSMSItem{
+		{Recipient: "09120000001", Body: "long body", TrackingID: "trk-1", Parts: 3},
+		{Recipient: "09120000002", Body: "hi", TrackingID: "trk-2"},
+	}
+	if _, err := client.sendBatchOnce(context.Background(), "3000", items, "token"); err != nil {
+		t.Fatalf("sendBatchOnce returned an error: %v", err)
+	}
+	if len(payload.SMSItems) != 2 {
+		t.Fatalf("sent %d items, want 2", len(payload.SMSItems))
+	}
+	// JSON numbers decode as float64
+	if got := payload.SMSItems[0]["parts"]; got != float64(3) {
+		t.Errorf("parts of the first item = %v, want 3", got)
+	}
+	if got, ok := payload.SMSItems[1]["parts"]; ok {
+		t.Errorf("item without a part count sent parts = %v", got)
+	}
+}
@@ -0,0 +1,112 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	// Payment requests expired by the sweeper, by the status they were in
+	paymentRequestsExpiredTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "payment_requests_expired_total",
+			Help: "Payment requests expired past their expiry, by the status they were in (created, tokenized, pending)",
+		},
+		[]string{"from_status"},
+	)
+
+	// When the sweeper last completed without error
+	paymentExpiryLastSuccess = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "payment_request_expiry_last_success_timestamp_seconds",
+			Help: "Unix time the payment request expiry sweep last completed without error",
+		},
+	)
+)
+
+// PaymentRequestExpirer expires payment requests still open past their
+// expiry
+type PaymentRequestExpirer interface {
+	ExpireStaleRequests(ctx context.Context) (map[models.PaymentRequestStatus]int, error)
+}
+
+// PaymentExpiryScheduler periodically expires Atipay payment requests whose
+// callback never arrived, so they do not stay open forever.
+type PaymentExpiryScheduler struct {
+	expirer      PaymentRequestExpirer
+	logger       *log.Logger
+	pollInterval time.Duration
+}
+
+func NewPaymentExpiryScheduler(
+	expirer PaymentRequestExpirer,
+	logger *log.Logger,
+	pollInterval time.Duration,
+) *PaymentExpiryScheduler {
+	if pollInterval <= 0 {
+		pollInterval = time.Minute
+	}
+	if logger == nil {
+		logger = log.Default()
+	}
+	return &PaymentExpiryScheduler{
+		expirer:      expirer,
+		logger:       logger,
+		pollInterval: pollInterval,
+	}
+}
+
+func (s *PaymentExpiryScheduler) Start(parent context.Context) func() {
+	workerCtx, cancel := context.WithCancel(parent)
+	var wg sync.WaitGroup
+	var stopOnce sync.Once
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(s.pollInterval)
+		defer ticker.Stop()
+
+		s.runOnce(workerCtx)
+		for {
+			select {
+			case <-workerCtx.Done():
+				return
+			case <-ticker.C:
+				s.runOnce(workerCtx)
+			}
+		}
+	}()
+	return func() {
+		stopOnce.Do(func() {
+			cancel()
+			wg.Wait()
+		})
+	}
+}
+
+func (s *PaymentExpiryScheduler) runOnce(parent context.Context) {
+	ctx, cancel := context.WithTimeout(parent, 5*time.Minute)
+	defer cancel()
+
+	expired, err := s.expirer.ExpireStaleRequests(ctx)
+	total := 0
+	for status, n := range expired {
+		paymentRequestsExpiredTotal.WithLabelValues(string(status)).Add(float64(n))
+		total += n
+	}
+	if err != nil {
+		s.logger.Printf("payment expiry scheduler: %v", err)
+	} else {
+		paymentExpiryLastSuccess.SetToCurrentTime()
+	}
+	if total > 0 {
+		s.logger.Printf("payment expiry scheduler: expired %d requests (%v)", total, expired)
+	}
+}
@@ -0,0 +1,46 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"testing"
+
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+type fakePaymentRequestExpirer struct {
+	expired map[models.PaymentRequestStatus]int
+	err     error
+}
+
+func (e fakePaymentRequestExpirer) ExpireStaleRequests(context.Context) (map[models.PaymentRequestStatus]int, error) {
+	return e.expired, e.err
+}
+
+func TestPaymentExpirySchedulerCountsExpiredRequests(t *testing.T) {
+	pending := paymentRequestsExpiredTotal.WithLabelValues(string(models.PaymentRequestStatusPending))
+	created := paymentRequestsExpiredTotal.WithLabelValues(string(models.PaymentRequestStatusCreated))
+	beforePending, beforeCreated := testutil.ToFloat64(pending), testutil.ToFloat64(created)
+
+	logger := log.New(io.Discard, "", 0)
+	NewPaymentExpiryScheduler(fakePaymentRequestExpirer{expired: map[models.PaymentRequestStatus]int{
+		models.PaymentRequestStatusPending: 3,
+		models.PaymentRequestStatusCreated: 1,
+	}}, logger, 0).runOnce(context.Background())
+
+	// A sweep failing part way still counts what it expired
+	NewPaymentExpiryScheduler(fakePaymentRequestExpirer{
+		expired: map[models.PaymentRequestStatus]int{models.PaymentRequestStatusPending: 2},
+		err:     errors.New("db down"),
+	}, logger, 0).runOnce(context.Background())
+
+	if got := testutil.ToFloat64(pending) - beforePending; got != 5 {
+		t.Fatalf("pending expired = %v, want 5", got)
+	}
+	if got := testutil.ToFloat64(created) - beforeCreated; got != 1 {
+		t.Fatalf("created expired = %v, want 1", got)
+	}
+}
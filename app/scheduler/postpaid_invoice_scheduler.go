@@ -0,0 +1,110 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	// Invoices issued and customers that failed in the last invoicing run
+	postpaidInvoicingLastRun = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "postpaid_invoicing_last_run",
+			Help: "Postpaid invoices issued and customers whose invoice failed in the last invoicing run, by result (issued, failed)",
+		},
+		[]string{"result"},
+	)
+
+	// When invoicing last completed without error
+	postpaidInvoicingLastSuccess = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "postpaid_invoicing_last_success_timestamp_seconds",
+			Help: "Unix time postpaid invoicing last completed without error",
+		},
+	)
+)
+
+// PostpaidInvoicer bills the credit drawn in the Tehran month before now
+type PostpaidInvoicer interface {
+	IssueMonthlyInvoices(ctx context.Context, now time.Time) (*dto.PostpaidInvoicingSummary, error)
+}
+
+// PostpaidInvoiceScheduler periodically invoices the previous month's credit
+// draws. Draws are marked once invoiced, so every run after the first of the
+// month only picks up customers whose invoice failed before.
+type PostpaidInvoiceScheduler struct {
+	invoicer     PostpaidInvoicer
+	logger       *log.Logger
+	pollInterval time.Duration
+}
+
+func NewPostpaidInvoiceScheduler(
+	invoicer PostpaidInvoicer,
+	logger *log.Logger,
+	pollInterval time.Duration,
+) *PostpaidInvoiceScheduler {
+	if pollInterval <= 0 {
+		pollInterval = time.Hour
+	}
+	if logger == nil {
+		logger = log.Default()
+	}
+	return &PostpaidInvoiceScheduler{
+		invoicer:     invoicer,
+		logger:       logger,
+		pollInterval: pollInterval,
+	}
+}
+
+func (s *PostpaidInvoiceScheduler) Start(parent context.Context) func() {
+	workerCtx, cancel := context.WithCancel(parent)
+	var wg sync.WaitGroup
+	var stopOnce sync.Once
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(s.pollInterval)
+		defer ticker.Stop()
+
+		s.runOnce(workerCtx)
+		for {
+			select {
+			case <-workerCtx.Done():
+				return
+			case <-ticker.C:
+				s.runOnce(workerCtx)
+			}
+		}
+	}()
+	return func() {
+		stopOnce.Do(func() {
+			cancel()
+			wg.Wait()
+		})
+	}
+}
+
+func (s *PostpaidInvoiceScheduler) runOnce(parent context.Context) {
+	ctx, cancel := context.WithTimeout(parent, 5*time.Minute)
+	defer cancel()
+
+	summary, err := s.invoicer.IssueMonthlyInvoices(ctx, time.Now())
+	if err != nil {
+		s.logger.Printf("postpaid invoice scheduler: %v", err)
+		return
+	}
+	postpaidInvoicingLastRun.WithLabelValues("issued").Set(float64(summary.Issued))
+	postpaidInvoicingLastRun.WithLabelValues("failed").Set(float64(summary.Failed))
+	postpaidInvoicingLastSuccess.SetToCurrentTime()
+	if summary.Issued > 0 || summary.Failed > 0 {
+		s.logger.Printf("postpaid invoice scheduler: period %s: issued %d invoices for %d toman, %d failed",
+			summary.PeriodStart, summary.Issued, summary.Amount, summary.Failed)
+	}
+}
@@ -0,0 +1,36 @@
+package scheduler
+
+import (
+	"context"
+	"io"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+type fakePostpaidInvoicer struct {
+	runs int
+}
+
+func (i *fakePostpaidInvoicer) IssueMonthlyInvoices(_ context.Context, _ time.Time) (*dto.PostpaidInvoicingSummary, error) {
+	i.runs++
+	return &dto.PostpaidInvoicingSummary{PeriodStart: "2026-09-22T20:30:00Z", Issued: 3, Amount: 4500000, Failed: 1}, nil
+}
+
+func TestPostpaidInvoiceSchedulerRecordsRun(t *testing.T) {
+	invoicer := &fakePostpaidInvoicer{}
+	NewPostpaidInvoiceScheduler(invoicer, log.New(io.Discard, "", 0), 0).runOnce(context.Background())
+
+	if invoicer.runs != 1 {
+		t.Fatalf("runs = %d, want 1", invoicer.runs)
+	}
+	if got := testutil.ToFloat64(postpaidInvoicingLastRun.WithLabelValues("issued")); got != 3 {
+		t.Fatalf("issued = %v, want 3", got)
+	}
+	if got := testutil.ToFloat64(postpaidInvoicingLastRun.WithLabelValues("failed")); got != 1 {
+		t.Fatalf("failed = %v, want 1", got)
+	}
+}
@@ -0,0 +1,111 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	// Tehran days re-rolled by the last refresh
+	reportRollupLastRunDays = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "report_rollup_last_run_days",
+			Help: "Days re-rolled by the last reporting rollup refresh",
+		},
+	)
+
+	// When the rollups were last refreshed without error
+	reportRollupLastSuccess = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "report_rollup_last_success_timestamp_seconds",
+			Help: "Unix time the reporting rollups were last refreshed without error",
+		},
+	)
+)
+
+// ReportRollupRefresher re-rolls the reporting rollups since the last refresh
+type ReportRollupRefresher interface {
+	RefreshIncremental(ctx context.Context) (*dto.ReportRollupRefreshSummary, error)
+}
+
+// ReportRollupScheduler periodically refreshes the campaign_daily_stats,
+// revenue_daily and customer_monthly_usage rollups. Runs within a day only
+// re-roll that day; the first run after Tehran midnight also re-rolls the
+// lookback days.
+type ReportRollupScheduler struct {
+	refresher    ReportRollupRefresher
+	logger       *log.Logger
+	pollInterval time.Duration
+}
+
+func NewReportRollupScheduler(
+	refresher ReportRollupRefresher,
+	logger *log.Logger,
+	pollInterval time.Duration,
+) *ReportRollupScheduler {
+	if pollInterval <= 0 {
+		pollInterval = time.Hour
+	}
+	if logger == nil {
+		logger = log.Default()
+	}
+	return &ReportRollupScheduler{
+		refresher:    refresher,
+		logger:       logger,
+		pollInterval: pollInterval,
+	}
+}
+
+func (s *ReportRollupScheduler) Start(parent context.Context) func() {
+	workerCtx, cancel := context.WithCancel(parent)
+	var wg sync.WaitGroup
+	var stopOnce sync.Once
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(s.pollInterval)
+		defer ticker.Stop()
+
+		s.runOnce(workerCtx)
+		for {
+			select {
+			case <-workerCtx.Done():
+				return
+			case <-ticker.C:
+				s.runOnce(workerCtx)
+			}
+		}
+	}()
+	return func() {
+		stopOnce.Do(func() {
+			cancel()
+			wg.Wait()
+		})
+	}
+}
+
+func (s *ReportRollupScheduler) runOnce(parent context.Context) {
+	ctx, cancel := context.WithTimeout(parent, time.Hour)
+	defer cancel()
+
+	summary, err := s.refresher.RefreshIncremental(ctx)
+	if err != nil {
+		s.logger.Printf("report rollup scheduler: %v", err)
+		return
+	}
+	if summary.Skipped {
+		return
+	}
+	reportRollupLastRunDays.Set(float64(summary.Days))
+	reportRollupLastSuccess.SetToCurrentTime()
+	if summary.Days > 1 {
+		s.logger.Printf("report rollup scheduler: re-rolled %d days from %s to %s", summary.Days, summary.From, summary.To)
+	}
+}
@@ -0,0 +1,35 @@
+package scheduler
+
+import (
+	"context"
+	"io"
+	"log"
+	"testing"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+type fakeReportRollupRefresher struct {
+	summary *dto.ReportRollupRefreshSummary
+}
+
+func (r *fakeReportRollupRefresher) RefreshIncremental(_ context.Context) (*dto.ReportRollupRefreshSummary, error) {
+	return r.summary, nil
+}
+
+func TestReportRollupSchedulerRecordsRun(t *testing.T) {
+	logger := log.New(io.Discard, "", 0)
+	refresher := &fakeReportRollupRefresher{summary: &dto.ReportRollupRefreshSummary{From: "2026-10-14", To: "2026-10-17", Days: 4, Months: 1}}
+	NewReportRollupScheduler(refresher, logger, 0).runOnce(context.Background())
+	if got := testutil.ToFloat64(reportRollupLastRunDays); got != 4 {
+		t.Fatalf("days = %v, want 4", got)
+	}
+
+	// A run skipped because another instance holds the lock records nothing
+	refresher.summary = &dto.ReportRollupRefreshSummary{Skipped: true}
+	NewReportRollupScheduler(refresher, logger, 0).runOnce(context.Background())
+	if got := testutil.ToFloat64(reportRollupLastRunDays); got != 4 {
+		t.Fatalf("days = %v after a skipped run, want 4", got)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -18,6 +19,7 @@ import (
 	"gorm.io/gorm"
 
 	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/app/httpclient"
 	"github.com/amirphl/Yamata-no-Orochi/config"
 	"github.com/amirphl/Yamata-no-Orochi/models"
 	"github.com/amirphl/Yamata-no-Orochi/repository"
@@ -27,24 +29,22 @@ import (
 )
 
 const (
-	defaultRubikaBaseURL    = "https://messaging.rubika.ir"
-	rubikaSendMaxRetries    = 5
-	rubikaSendBatchSize     = 200
-	rubikaStatusJobMaxRetry = 3
+	defaultRubikaBaseURL = "https://messaging.rubika.ir"
+	rubikaSendMaxRetries = 5
+	rubikaSendBatchSize  = 200
 )
 
 type RubikaCampaignScheduler struct {
-	audRepo      repository.AudienceProfileRepository
-	tagRepo      repository.TagRepository
-	sentRepo     repository.SentRubikaMessageRepository
-	pcRepo       repository.ProcessedCampaignRepository
-	jobRepo      repository.CampaignStatusJobRepository
-	resRepo      repository.RubikaStatusResultRepository
-	statsRepo    repository.SrcLayerAllStatsRepository
-	notifier     NotificationSender
-	logger       *log.Logger
-	interval     time.Duration
-	messageDelay time.Duration
+	audRepo   repository.AudienceProfileRepository
+	tagRepo   repository.TagRepository
+	sentRepo  repository.SentRubikaMessageRepository
+	pcRepo    repository.ProcessedCampaignRepository
+	jobRepo   repository.CampaignStatusJobRepository
+	resRepo   repository.RubikaStatusResultRepository
+	statsRepo repository.SrcLayerAllStatsRepository
+	jobs      JobEnqueuer
+	logger    *log.Logger
+	*campaignTiming
 
 	db        *gorm.DB
 	adminCfg  config.AdminConfig
@@ -60,6 +60,8 @@ type RubikaCampaignScheduler struct {
 
 	audienceCache       *AudienceCache
 	bundleAudienceCache *BundleAudienceCache
+	blacklistRepo       repository.BlacklistedNumberRepository
+	quotaRepo           repository.CustomerSendingQuotaRepository
 }
 
 type RubikaClient interface {
@@ -124,7 +126,7 @@ type httpRubikaClient struct {
 }
 
 func newHTTPRubikaClient(cfg config.RubikaConfig) *httpRubikaClient {
-	return newHTTPRubikaClientWithClient(cfg, newHTTPClient(60*time.Second))
+	return newHTTPRubikaClientWithClient(cfg, newHTTPClient("rubika", 60*time.Second))
 }
 
 func newHTTPRubikaClientWithClient(cfg config.RubikaConfig, client *http.Client) *httpRubikaClient {
@@ -134,7 +136,7 @@ func newHTTPRubikaClientWithClient(cfg config.RubikaConfig, client *http.Client)
 	}
 	cfg.BaseURL = baseURL
 	if client == nil {
-		client = newHTTPClient(60 * time.Second)
+		client = newHTTPClient("rubika", 60*time.Second)
 	}
 	return &httpRubikaClient{
 		cfg:    cfg,
@@ -279,7 +281,7 @@ func NewRubikaCampaignScheduler(
 	jobRepo repository.CampaignStatusJobRepository,
 	resRepo repository.RubikaStatusResultRepository,
 	statsRepo repository.SrcLayerAllStatsRepository,
-	notifier NotificationSender,
+	jobs JobEnqueuer,
 	db *gorm.DB,
 	logger *log.Logger,
 	interval time.Duration,
@@ -288,9 +290,6 @@ func NewRubikaCampaignScheduler(
 	botCfg config.BotConfig,
 	adminCfg config.AdminConfig,
 ) *RubikaCampaignScheduler {
-	if interval <= 0 {
-		interval = time.Minute
-	}
 	if botCfg.APIDomain == "" {
 		botCfg.APIDomain = defaultBotAPIDomain
 	}
@@ -303,18 +302,19 @@ func NewRubikaCampaignScheduler(
 		jobRepo:             jobRepo,
 		resRepo:             resRepo,
 		statsRepo:           statsRepo,
-		notifier:            notifier,
+		jobs:                jobs,
 		logger:              logger,
 		db:                  db,
-		interval:            interval,
-		messageDelay:        messageDelay,
+		campaignTiming:      newCampaignTiming(interval, messageDelay),
 		adminCfg:            adminCfg,
 		rubikaCfg:           rubikaCfg,
 		botCfg:              botCfg,
-		botClient:           newHTTPBotClient(botCfg),
+		botClient:           newBotClient(botCfg),
 		rubikaClient:        newHTTPRubikaClient(rubikaCfg),
 		audienceCache:       NewAudienceCache(repository.NewAudienceSelectionRepository(db)),
 		bundleAudienceCache: NewBundleAudienceCache(repository.NewBundleAudienceSelectionRepository(db)),
+		blacklistRepo:       repository.NewBlacklistedNumberRepository(db),
+		quotaRepo:           repository.NewCustomerSendingQuotaRepository(db),
 		schedulerName:       "rubika",
 	}
 
@@ -337,13 +337,17 @@ func (s *RubikaCampaignScheduler) initSchedulerLogger() error {
 
 func (s *RubikaCampaignScheduler) Start(parent context.Context) func() {
 	go func() {
-		ticker := time.NewTicker(s.interval)
+		interval, changed := s.tick()
+		ticker := time.NewTicker(interval)
 		defer ticker.Stop()
 
 		for {
 			select {
 			case <-parent.Done():
 				return
+			case <-changed:
+				interval, changed = s.tick()
+				ticker.Reset(interval)
 			case <-ticker.C:
 				func() {
 					ctx, cancel := context.WithTimeout(parent, 20*time.Minute) // TODO:
@@ -425,6 +429,10 @@ func (s *RubikaCampaignScheduler) runOnce(ctx context.Context, parent context.Co
 }
 
 func (s *RubikaCampaignScheduler) processRubikaCampaign(ctx context.Context, token string, c dto.BotGetCampaignResponse) error {
+	if c.Sandbox {
+		return executeSandboxCampaign(ctx, s.botClient, s.logger, token, c)
+	}
+
 	serviceID, err := s.extractRubikaServiceID(c)
 	if err != nil {
 		return fmt.Errorf("resolve Rubika service id for campaign id=%d: %w", c.ID, err)
@@ -450,6 +458,7 @@ func (s *RubikaCampaignScheduler) processRubikaCampaign(ctx context.Context, tok
 		codes        []string
 		unmatchedUID []string
 		selectionID  *uint
+		blacklisted  int64
 	)
 	if hasTargetAudienceExcelFileUUID(c.TargetAudienceExcelFileUUID) {
 		if err := ctx.Err(); err != nil {
@@ -465,7 +474,7 @@ func (s *RubikaCampaignScheduler) processRubikaCampaign(ctx context.Context, tok
 		if c.ShortLinkDomain != nil {
 			excelShortLinkDomain = *c.ShortLinkDomain
 		}
-		audienceResult, err := fetchAudiencePhonesByUIDs(ctx, s.logger, s.audRepo, s.botClient, c, token, fileUIDs, excelShortLinkDomain)
+		audienceResult, err := fetchAudiencePhonesByUIDs(ctx, s.logger, s.audRepo, s.blacklistRepo, s.botClient, c, token, fileUIDs, excelShortLinkDomain)
 		if err != nil {
 			return fmt.Errorf("fetch audience phones by UIDs for campaign id=%d: %w", c.ID, err)
 		}
@@ -474,6 +483,7 @@ func (s *RubikaCampaignScheduler) processRubikaCampaign(ctx context.Context, tok
 		uids = audienceResult.UIDs
 		codes = audienceResult.Codes
 		unmatchedUID = audienceResult.UnmatchedUIDs
+		blacklisted = audienceResult.BlacklistedExcluded
 		selectionID = nil
 		s.logger.Printf("Rubika scheduler: campaign id=%d fetched %d phones via excel (unmatched=%d)", c.ID, len(phones), len(unmatchedUID))
 	} else {
@@ -499,6 +509,7 @@ func (s *RubikaCampaignScheduler) processRubikaCampaign(ctx context.Context, tok
 		uids = audienceResult.UIDs
 		codes = audienceResult.Codes
 		selectionID = utils.ToPtr(audienceResult.SelectionID)
+		blacklisted = audienceResult.BlacklistedExcluded
 		s.logger.Printf("Rubika scheduler: campaign id=%d fetched %d phones (selection_id=%d)", c.ID, len(phones), audienceResult.SelectionID)
 	}
 
@@ -508,7 +519,17 @@ func (s *RubikaCampaignScheduler) processRubikaCampaign(ctx context.Context, tok
 	if len(codes) != len(phones) {
 		return fmt.Errorf("audience codes mismatch for campaign id=%d: phones=%d codes=%d", c.ID, len(phones), len(codes))
 	}
-	s.logger.Printf("Rubika scheduler: campaign id=%d audience ready: phones=%d unmatched=%d", c.ID, len(phones), len(unmatchedUID))
+	keep, err := sendingQuotaKeep(ctx, s.quotaRepo, c.CustomerID, len(phones))
+	if err != nil {
+		return fmt.Errorf("apply sending quota for campaign id=%d: %w", c.ID, err)
+	}
+	quotaExcluded := int64(len(phones) - keep)
+	if quotaExcluded > 0 {
+		phones, ids, codes = phones[:keep], ids[:keep], codes[:keep]
+		uids = uids[:min(keep, len(uids))]
+		s.logger.Printf("Rubika scheduler: campaign id=%d truncated to sending quota: kept=%d dropped=%d", c.ID, keep, quotaExcluded)
+	}
+	s.logger.Printf("Rubika scheduler: campaign id=%d audience ready: phones=%d unmatched=%d blacklisted=%d quota_excluded=%d", c.ID, len(phones), len(unmatchedUID), blacklisted, quotaExcluded)
 
 	campaignJSON, err := json.Marshal(c)
 	if err != nil {
@@ -526,6 +547,8 @@ func (s *RubikaCampaignScheduler) processRubikaCampaign(ctx context.Context, tok
 			AudienceCodes:       []string{},
 			LastAudienceID:      nil,
 			AudienceSelectionID: selectionID,
+			BlacklistedExcluded: blacklisted,
+			QuotaExcluded:       quotaExcluded,
 			Statistics:          nil,
 		}
 		if err := s.pcRepo.Save(txCtx, pc); err != nil {
@@ -571,6 +594,7 @@ func (s *RubikaCampaignScheduler) processRubikaCampaign(ctx context.Context, tok
 		s.logger.Printf("Rubika scheduler: campaign id=%d media uploaded file_id=%v", c.ID, fileID)
 	}
 
+	stopped := false
 	for start := 0; start < len(phones); start += rubikaSendBatchSize {
 		if err := ctx.Err(); err != nil {
 			return fmt.Errorf("context expired at batch start=%d for campaign id=%d: %w", start, c.ID, err)
@@ -608,7 +632,7 @@ func (s *RubikaCampaignScheduler) processRubikaCampaign(ctx context.Context, tok
 		}
 
 		lastBatchID := batchIDs[len(batchIDs)-1]
-		if err := repository.WithTransaction(ctx, s.db, func(txCtx context.Context) error {
+		if err := saveCampaignBatch(ctx, s.db, s.logger, c.ID, func(txCtx context.Context) error {
 			if len(rows) > 0 {
 				if err := s.sentRepo.SaveBatch(txCtx, rows); err != nil {
 					return fmt.Errorf("save batch rows: %w", err)
@@ -621,6 +645,12 @@ func (s *RubikaCampaignScheduler) processRubikaCampaign(ctx context.Context, tok
 			}
 			return nil
 		}); err != nil {
+			if errors.Is(err, errCampaignExecutionStopped) {
+				s.logger.Printf("Rubika scheduler: campaign id=%d stopped after %d recipients", c.ID, start)
+				uids, codes = uids[:start], codes[:start]
+				stopped = true
+				break
+			}
 			return fmt.Errorf("save batch [%d,%d) for campaign id=%d: %w", start, end, c.ID, err)
 		}
 		s.logger.Printf("Rubika scheduler: campaign id=%d batch [%d,%d) saved, sending to Rubika", c.ID, start, end)
@@ -647,7 +677,7 @@ func (s *RubikaCampaignScheduler) processRubikaCampaign(ctx context.Context, tok
 			// NOTE: Error silent here; not returning to avoid blocking further processing
 		}
 		s.logger.Printf("Rubika scheduler: campaign id=%d batch [%d,%d) done, sleeping message_delay", c.ID, start, end)
-		if err := sleepWithContext(ctx, s.messageDelay); err != nil {
+		if err := sleepWithContext(ctx, s.messageDelay()); err != nil {
 			return fmt.Errorf("interrupted during batch delay at [%d,%d) for campaign id=%d: %w", start, end, c.ID, err)
 		}
 	}
@@ -662,21 +692,23 @@ func (s *RubikaCampaignScheduler) processRubikaCampaign(ctx context.Context, tok
 		}
 	}
 
-	s.logger.Printf("Rubika scheduler: campaign id=%d all batches sent", c.ID)
+	if stopped {
+		s.logger.Printf("Rubika scheduler: campaign id=%d stopped before all batches were sent", c.ID)
+	} else {
+		s.logger.Printf("Rubika scheduler: campaign id=%d all batches sent", c.ID)
 
-	if err := s.botClient.MoveCampaignToExecuted(ctx, token, c.ID); err != nil {
-		return fmt.Errorf("move campaign id=%d to executed: %w", c.ID, err)
+		if err := s.botClient.MoveCampaignToExecuted(ctx, token, c.ID); err != nil {
+			return fmt.Errorf("move campaign id=%d to executed: %w", c.ID, err)
+		}
+		s.logger.Printf("Rubika scheduler: campaign id=%d moved to executed", c.ID)
 	}
-	s.logger.Printf("Rubika scheduler: campaign id=%d moved to executed", c.ID)
 
-	go func(campaignID uint, uids, codes []string) {
-		pushCtx, cancel := context.WithTimeout(context.Background(), 15*time.Minute)
-		defer cancel()
-		if err := s.botClient.PushCampaignAudienceUIDs(pushCtx, campaignID, uids, codes); err != nil {
-			s.logger.Printf("Rubika scheduler: push audience UIDs failed for campaign id=%d: %v", campaignID, err)
-			s.notifyAdmin(fmt.Sprintf("Rubika Scheduler: push audience UIDs failed for campaign id=%d: %v", campaignID, err))
+	if s.jobs != nil {
+		push := models.PushAudienceUIDsJob{CampaignID: c.ID, UIDs: uids, Codes: codes}
+		if err := s.jobs.Enqueue(ctx, models.JobTypePushAudienceUIDs, push); err != nil {
+			s.logger.Printf("Rubika scheduler: queue audience UIDs push failed for campaign id=%d: %v", c.ID, err)
 		}
-	}(c.ID, uids, codes)
+	}
 
 	return nil
 }
@@ -712,7 +744,7 @@ func (s *RubikaCampaignScheduler) downloadCampaignMediaViaBotAPI(ctx context.Con
 	req.Header.Set("Authorization", "Bearer "+token)
 	req.Header.Set("Accept", "*/*")
 
-	client := &http.Client{Timeout: 60 * time.Second}
+	client := httpclient.New("rubika", 60*time.Second)
 	resp, err := client.Do(req)
 	if err != nil {
 		return "", err
@@ -963,7 +995,14 @@ func (s *RubikaCampaignScheduler) fetchRubikaAudiencePhones(
 		s.logger.Printf("fetchRubikaAudiencePhones selection miss: campaign_id=%d", c.ID)
 	}
 
+	blacklist, err := loadBlacklistFilter(ctx, s.blacklistRepo)
+	if err != nil {
+		s.logger.Printf("fetchRubikaAudiencePhones load blacklist failed: campaign_id=%d err=%v", c.ID, err)
+		return nil, err
+	}
+
 	selectAudiences := func(exclude map[int64]struct{}) ([]string, []int64, []string, error) {
+		blacklist.reset()
 		phones := make([]string, 0, numAudiences)
 		ids := make([]int64, 0, numAudiences)
 		uids := make([]string, 0, numAudiences)
@@ -987,6 +1026,9 @@ func (s *RubikaCampaignScheduler) fetchRubikaAudiencePhones(
 					return
 				}
 			}
+			if blacklist.skip(*ap.PhoneNumber) {
+				return
+			}
 			phones = append(phones, *ap.PhoneNumber)
 			ids = append(ids, int64(ap.ID))
 			uids = append(uids, ap.UID)
@@ -1038,11 +1080,12 @@ func (s *RubikaCampaignScheduler) fetchRubikaAudiencePhones(
 		s.logger.Printf("fetchRubikaAudiencePhones skipped short links generation: campaign_id=%d ad_link=empty", c.ID)
 		s.logger.Printf("fetchRubikaAudiencePhones success: campaign_id=%d selected=%d codes_length=%d selection_id=%d ad_link=empty", c.ID, len(phones), len(phones), sel.ID)
 		return &AudiencePhonesResult{
-			Phones:      phones,
-			IDs:         ids,
-			UIDs:        uids,
-			Codes:       make([]string, len(phones)),
-			SelectionID: sel.ID,
+			Phones:              phones,
+			IDs:                 ids,
+			UIDs:                uids,
+			Codes:               make([]string, len(phones)),
+			SelectionID:         sel.ID,
+			BlacklistedExcluded: blacklist.Excluded(),
 		}, nil
 	}
 
@@ -1050,11 +1093,12 @@ func (s *RubikaCampaignScheduler) fetchRubikaAudiencePhones(
 		s.logger.Printf("fetchRubikaAudiencePhones skipped short links generation: campaign_id=%d short_link_domain=empty", c.ID)
 		s.logger.Printf("fetchRubikaAudiencePhones success: campaign_id=%d selected=%d codes_length=%d selection_id=%d short_link_domain=empty", c.ID, len(phones), len(phones), sel.ID)
 		return &AudiencePhonesResult{
-			Phones:      phones,
-			IDs:         ids,
-			UIDs:        uids,
-			Codes:       make([]string, len(phones)),
-			SelectionID: sel.ID,
+			Phones:              phones,
+			IDs:                 ids,
+			UIDs:                uids,
+			Codes:               make([]string, len(phones)),
+			SelectionID:         sel.ID,
+			BlacklistedExcluded: blacklist.Excluded(),
 		}, nil
 	}
 
@@ -1081,11 +1125,12 @@ func (s *RubikaCampaignScheduler) fetchRubikaAudiencePhones(
 	}
 	s.logger.Printf("fetchRubikaAudiencePhones success: campaign_id=%d selected=%d codes_length=%d selection_id=%d", c.ID, len(phones), len(codes), sel.ID)
 	return &AudiencePhonesResult{
-		Phones:      phones,
-		IDs:         ids,
-		UIDs:        uids,
-		Codes:       codes,
-		SelectionID: sel.ID,
+		Phones:              phones,
+		IDs:                 ids,
+		UIDs:                uids,
+		Codes:               codes,
+		SelectionID:         sel.ID,
+		BlacklistedExcluded: blacklist.Excluded(),
 	}, nil
 }
 
@@ -1097,6 +1142,7 @@ func (s *RubikaCampaignScheduler) selectRubikaTagAudiences(
 	tagIDs pq.Int32Array,
 	numAudiences int64,
 	exclude map[int64]struct{},
+	blacklist *blacklistFilter,
 	scoreConstraint *models.NormalizedScoreConstraint,
 ) (phones []string, ids []int64, uids []string, err error) {
 	const limit = 10000000
@@ -1124,6 +1170,9 @@ func (s *RubikaCampaignScheduler) selectRubikaTagAudiences(
 				continue
 			}
 		}
+		if blacklist.skip(*ap.PhoneNumber) {
+			continue
+		}
 		phones = append(phones, *ap.PhoneNumber)
 		ids = append(ids, int64(ap.ID))
 		uids = append(uids, ap.UID)
@@ -1194,7 +1243,13 @@ func (s *RubikaCampaignScheduler) fetchRubikaAudiencePhonesByBundle(
 		s.logger.Printf("fetchRubikaAudiencePhonesByBundle bundle selection miss: campaign_id=%d bundle_id=%d", c.ID, bundleID)
 	}
 
-	phones, ids, uids, err := s.selectRubikaTagAudiences(ctx, c.ID, tagIDs, numAudiences, exclude, scoreConstraint)
+	blacklist, err := loadBlacklistFilter(ctx, s.blacklistRepo)
+	if err != nil {
+		s.logger.Printf("fetchRubikaAudiencePhonesByBundle load blacklist failed: campaign_id=%d err=%v", c.ID, err)
+		return nil, err
+	}
+
+	phones, ids, uids, err := s.selectRubikaTagAudiences(ctx, c.ID, tagIDs, numAudiences, exclude, blacklist, scoreConstraint)
 	if err != nil {
 		return nil, err
 	}
@@ -1213,22 +1268,24 @@ func (s *RubikaCampaignScheduler) fetchRubikaAudiencePhonesByBundle(
 	if !hasCampaignAdLink(c.AdLink) {
 		s.logger.Printf("fetchRubikaAudiencePhonesByBundle skipped short links: campaign_id=%d ad_link=empty", c.ID)
 		return &AudiencePhonesResult{
-			Phones:      phones,
-			IDs:         ids,
-			UIDs:        uids,
-			Codes:       make([]string, len(phones)),
-			SelectionID: sel.ID,
+			Phones:              phones,
+			IDs:                 ids,
+			UIDs:                uids,
+			Codes:               make([]string, len(phones)),
+			SelectionID:         sel.ID,
+			BlacklistedExcluded: blacklist.Excluded(),
 		}, nil
 	}
 
 	if c.ShortLinkDomain == nil || strings.TrimSpace(*c.ShortLinkDomain) == "" {
 		s.logger.Printf("fetchRubikaAudiencePhonesByBundle skipped short links: campaign_id=%d short_link_domain=empty", c.ID)
 		return &AudiencePhonesResult{
-			Phones:      phones,
-			IDs:         ids,
-			UIDs:        uids,
-			Codes:       make([]string, len(phones)),
-			SelectionID: sel.ID,
+			Phones:              phones,
+			IDs:                 ids,
+			UIDs:                uids,
+			Codes:               make([]string, len(phones)),
+			SelectionID:         sel.ID,
+			BlacklistedExcluded: blacklist.Excluded(),
 		}, nil
 	}
 
@@ -1256,11 +1313,12 @@ func (s *RubikaCampaignScheduler) fetchRubikaAudiencePhonesByBundle(
 	s.logger.Printf("fetchRubikaAudiencePhonesByBundle success: campaign_id=%d bundle_id=%d selected=%d codes=%d selection_id=%d",
 		c.ID, bundleID, len(phones), len(codes), sel.ID)
 	return &AudiencePhonesResult{
-		Phones:      phones,
-		IDs:         ids,
-		UIDs:        uids,
-		Codes:       codes,
-		SelectionID: sel.ID,
+		Phones:              phones,
+		IDs:                 ids,
+		UIDs:                uids,
+		Codes:               codes,
+		SelectionID:         sel.ID,
+		BlacklistedExcluded: blacklist.Excluded(),
 	}, nil
 }
 
@@ -1313,32 +1371,10 @@ func (s *RubikaCampaignScheduler) scheduleStatusCheckJobs(ctx context.Context, p
 	if len(trackingIDs) == 0 || !s.rubikaClient.SupportsStatusTracking() || s.jobRepo == nil {
 		return nil
 	}
-	filteredTrackingIDs := make([]string, 0, len(trackingIDs))
-	for _, id := range trackingIDs {
-		if strings.TrimSpace(id) != "" {
-			filteredTrackingIDs = append(filteredTrackingIDs, strings.TrimSpace(id))
-		}
-	}
-	if len(filteredTrackingIDs) == 0 {
+	jobs := newStatusCheckJobs(models.CampaignPlatformRubika, processedCampaignID, trackingIDs, utils.UTCNow())
+	if len(jobs) == 0 {
 		return nil
 	}
-
-	corrID := uuid.NewString()
-	now := utils.UTCNow()
-	offsets := []time.Duration{1 * time.Minute, 5 * time.Minute, 15 * time.Minute, 24 * time.Hour, 48 * time.Hour}
-	jobs := make([]*models.CampaignStatusJob, 0, len(offsets))
-	for _, off := range offsets {
-		jobs = append(jobs, &models.CampaignStatusJob{
-			ProcessedCampaignID: processedCampaignID,
-			CorrelationID:       corrID,
-			Platform:            models.CampaignPlatformRubika,
-			TrackingIDs:         pq.StringArray(filteredTrackingIDs),
-			RetryCount:          0,
-			ScheduledAt:         now.Add(off),
-			CreatedAt:           now,
-			UpdatedAt:           now,
-		})
-	}
 	return s.jobRepo.SaveBatch(ctx, jobs)
 }
 
@@ -1371,13 +1407,14 @@ func (s *RubikaCampaignScheduler) startStatusJobWorker(parent context.Context) {
 					return
 				}
 
+				observeStatusJobLag(job, utils.UTCNow())
 				jobCtx, jobCancel := context.WithTimeout(parent, 2*time.Minute)
 				err := s.handleStatusJob(jobCtx, job)
 				jobCancel()
 
 				if err != nil {
 					s.logger.Printf("Rubika scheduler: handle status job id=%d failed: %v", job.ID, err)
-					if job.RetryCount >= rubikaStatusJobMaxRetry {
+					if job.Status == models.CampaignStatusJobFailed {
 						s.notifyAdmin(fmt.Sprintf("Rubika scheduler: status job id=%d has failed %d times with error: %v", job.ID, job.RetryCount, err))
 					}
 				} else {
@@ -1425,19 +1462,11 @@ func (s *RubikaCampaignScheduler) handleStatusJob(ctx context.Context, job *mode
 
 	statusItems, fetchErr := s.rubikaClient.FetchStatus(ctx, serverIDs)
 	if fetchErr != nil {
-		now := utils.UTCNow()
-		job.RetryCount++
-		msg := fetchErr.Error()
-		job.Error = &msg
-		job.UpdatedAt = now
-		if job.RetryCount >= rubikaStatusJobMaxRetry {
-			job.ExecutedAt = &now
-		} else {
-			job.ExecutedAt = nil
-		}
+		recordStatusJobFailure(job, fetchErr, utils.UTCNow())
 		if err := s.jobRepo.Update(ctx, job); err != nil {
 			return err
 		}
+		observeStatusJobOutcome(job)
 		return fetchErr
 	}
 
@@ -1520,14 +1549,13 @@ func (s *RubikaCampaignScheduler) handleStatusJob(ctx context.Context, job *mode
 			return err
 		}
 
-		job.ExecutedAt = &now
-		job.Error = nil
-		job.UpdatedAt = now
+		recordStatusJobSuccess(job, now)
 		return s.jobRepo.Update(txCtx, job)
 	})
 	if txErr != nil {
 		return txErr
 	}
+	observeStatusJobOutcome(job)
 
 	stats, err := s.updateProcessedCampaignStats(ctx, job.ProcessedCampaignID)
 	if err != nil {
@@ -1552,11 +1580,13 @@ func (s *RubikaCampaignScheduler) handleStatusJob(ctx context.Context, job *mode
 }
 
 func (s *RubikaCampaignScheduler) markStatusJobExecuted(ctx context.Context, job *models.CampaignStatusJob, errText *string) error {
-	now := utils.UTCNow()
-	job.ExecutedAt = &now
-	job.UpdatedAt = now
+	recordStatusJobSuccess(job, utils.UTCNow())
 	job.Error = errText
-	return s.jobRepo.Update(ctx, job)
+	if err := s.jobRepo.Update(ctx, job); err != nil {
+		return err
+	}
+	observeStatusJobOutcome(job)
+	return nil
 }
 
 func mapRubikaProviderStatus(item RubikaStatusResponse) (totalParts int64, deliveredParts int64, undeliveredParts int64, unknownParts int64, status models.RubikaSendStatus) {
@@ -1807,12 +1837,12 @@ func buildRubikaSendMetadataDescription(resp *RubikaMessageStatus, fallback stri
 }
 
 func (s *RubikaCampaignScheduler) notifyAdmin(message string) {
-	if s.notifier == nil {
+	if s.jobs == nil {
 		return
 	}
-	go func(msg string) {
-		for _, mobile := range s.adminCfg.ActiveMobiles() {
-			_ = s.notifier.SendSMS(context.Background(), mobile, msg, nil)
+	for _, mobile := range s.adminCfg.ActiveMobiles() {
+		if err := s.jobs.Enqueue(context.Background(), models.JobTypeSendSMS, models.SendSMSJob{Mobile: mobile, Message: message}); err != nil {
+			s.logger.Printf("queue admin notification failed: %v", err)
 		}
-	}(message)
+	}
 }
@@ -20,6 +20,7 @@ import (
 	"github.com/amirphl/Yamata-no-Orochi/models"
 	"github.com/amirphl/Yamata-no-Orochi/repository"
 	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/amirphl/Yamata-no-Orochi/utils/phonenumber"
 	"github.com/xuri/excelize/v2"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
@@ -33,8 +34,8 @@ const (
 	numJobsPerTick          = 250
 	statusJobWorkerInterval = 1 * time.Minute
 
-	// statusJobMaxRetry is the maximum number of times a status-check job is
-	// retried before it is permanently marked as executed. Used by all platform
+	// statusJobMaxRetry is the attempt limit new status-check jobs get; a job
+	// fails once it has failed that many times. Used by all platform
 	// schedulers (Bale, Splus, SMS, …).
 	statusJobMaxRetry = 3
 
@@ -42,8 +43,16 @@ const (
 	// database per AppendAudienceData call inside the persistence transaction.
 	// Used by all platform schedulers.
 	audienceAppendBatchSize = 1000
+
+	// campaignPausePollInterval is how often a paused campaign is re-checked
+	// for resume or cancel between send batches.
+	campaignPausePollInterval = 15 * time.Second
 )
 
+// errCampaignExecutionStopped is returned by saveCampaignBatch when the
+// campaign was cancelled while it was being sent.
+var errCampaignExecutionStopped = errors.New("campaign execution stopped")
+
 type AudiencePhonesResult struct {
 	Phones        []string
 	IDs           []int64
@@ -52,6 +61,130 @@ type AudiencePhonesResult struct {
 	SelectionID   uint
 	MatchedUIDs   []string
 	UnmatchedUIDs []string
+	// BlacklistedExcluded counts candidates skipped because they are blacklisted
+	BlacklistedExcluded int64
+	// FrequencyCapped counts candidates skipped because another campaign of the
+	// customer messaged them within its frequency cap
+	FrequencyCapped int64
+}
+
+// blacklistFilter skips blacklisted recipients, and recipients recently
+// messaged by another campaign of the same customer, while a campaign's
+// audience is selected and counts how many candidates it skipped
+type blacklistFilter struct {
+	phones   map[string]struct{}
+	recent   map[string]struct{}
+	excluded int64
+	capped   int64
+}
+
+// loadBlacklistFilter loads the current blacklist. A nil repository yields a
+// filter that skips nothing.
+func loadBlacklistFilter(ctx context.Context, repo repository.BlacklistedNumberRepository) (*blacklistFilter, error) {
+	if repo == nil {
+		return &blacklistFilter{}, nil
+	}
+	phones, err := repo.PhoneNumberSet(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("load blacklisted numbers: %w", err)
+	}
+	return &blacklistFilter{phones: phones}, nil
+}
+
+// loadFrequencyCap makes the filter also skip numbers the customer's other
+// campaigns messaged within its frequency cap. It does nothing when the
+// customer has no cap.
+func (b *blacklistFilter) loadFrequencyCap(
+	ctx context.Context,
+	quotaRepo repository.CustomerSendingQuotaRepository,
+	sentRepo repository.SentSMSRepository,
+	customerID, campaignID uint,
+) error {
+	if b == nil || quotaRepo == nil || sentRepo == nil {
+		return nil
+	}
+	quota, err := quotaRepo.ByCustomerID(ctx, customerID)
+	if err != nil {
+		return fmt.Errorf("load sending quota: %w", err)
+	}
+	window, ok := quota.FrequencyCap()
+	if !ok {
+		return nil
+	}
+	recent, err := sentRepo.RecentPhonesByCustomer(ctx, customerID, campaignID, utils.UTCNow().Add(-window))
+	if err != nil {
+		return fmt.Errorf("load recently messaged numbers: %w", err)
+	}
+	b.recent = recent
+	return nil
+}
+
+// skip reports whether phone is blacklisted or frequency capped and counts it
+// if so
+func (b *blacklistFilter) skip(phone string) bool {
+	if b == nil || (len(b.phones) == 0 && len(b.recent) == 0) {
+		return false
+	}
+	canonical := phonenumber.CanonicalOrRaw(strings.TrimSpace(phone))
+	if _, ok := b.phones[canonical]; ok {
+		b.excluded++
+		return true
+	}
+	if _, ok := b.recent[canonical]; ok {
+		b.capped++
+		return true
+	}
+	return false
+}
+
+// reset clears the counters before a selection is retried from scratch
+func (b *blacklistFilter) reset() {
+	if b != nil {
+		b.excluded = 0
+		b.capped = 0
+	}
+}
+
+// Excluded returns how many blacklisted candidates were skipped
+func (b *blacklistFilter) Excluded() int64 {
+	if b == nil {
+		return 0
+	}
+	return b.excluded
+}
+
+// Capped returns how many frequency capped candidates were skipped
+func (b *blacklistFilter) Capped() int64 {
+	if b == nil {
+		return 0
+	}
+	return b.capped
+}
+
+// sendingQuotaKeep returns how many of the n selected recipients fit in the
+// customer's remaining daily and monthly sending quota. Only recipients of
+// already processed campaigns count as used. Recipients dropped here are never
+// sent, so the undelivered-messages reconciliation refunds them.
+func sendingQuotaKeep(ctx context.Context, repo repository.CustomerSendingQuotaRepository, customerID uint, n int) (int, error) {
+	if repo == nil || n == 0 {
+		return n, nil
+	}
+	quota, err := repo.ByCustomerID(ctx, customerID)
+	if err != nil {
+		return 0, fmt.Errorf("load sending quota: %w", err)
+	}
+	if quota.IsUnlimited() {
+		return n, nil
+	}
+	usage, err := repo.Usage(ctx, customerID, utils.UTCNow(), 0)
+	if err != nil {
+		return 0, fmt.Errorf("load sending quota usage: %w", err)
+	}
+	remaining, _ := quota.Remaining(usage.DailySent, usage.MonthlySent)
+	if remaining >= uint64(n) {
+		return n, nil
+	}
+	return int(remaining), nil
 }
 
 func initSchedulerLogger(name string) (*log.Logger, *os.File, error) {
@@ -151,6 +284,7 @@ func fetchAudiencePhonesByUIDs(
 	ctx context.Context,
 	logger *log.Logger,
 	audRepo repository.AudienceProfileRepository,
+	blacklistRepo repository.BlacklistedNumberRepository,
 	botClient BotClient,
 	c dto.BotGetCampaignResponse,
 	token string,
@@ -182,6 +316,10 @@ func fetchAudiencePhonesByUIDs(
 	if err != nil {
 		return nil, err
 	}
+	blacklist, err := loadBlacklistFilter(ctx, blacklistRepo)
+	if err != nil {
+		return nil, err
+	}
 
 	byUID := make(map[string]*models.AudienceProfile, len(profiles))
 	for _, p := range profiles {
@@ -209,6 +347,9 @@ func fetchAudiencePhonesByUIDs(
 			unmatchedUIDs = append(unmatchedUIDs, uid)
 			continue
 		}
+		if blacklist.skip(*profile.PhoneNumber) {
+			continue
+		}
 		matched = append(matched, matchedAudience{
 			id:    profile.ID,
 			phone: strings.TrimSpace(*profile.PhoneNumber),
@@ -234,23 +375,25 @@ func fetchAudiencePhonesByUIDs(
 	if !hasCampaignAdLink(c.AdLink) {
 		logger.Printf("fetchAudiencePhonesByUIDs skipped short links generation: campaign_id=%d ad_link=empty", c.ID)
 		return &AudiencePhonesResult{
-			Phones:        phones,
-			IDs:           ids,
-			UIDs:          uids,
-			Codes:         make([]string, len(phones)),
-			MatchedUIDs:   matchedUIDs,
-			UnmatchedUIDs: unmatchedUIDs,
+			Phones:              phones,
+			IDs:                 ids,
+			UIDs:                uids,
+			Codes:               make([]string, len(phones)),
+			MatchedUIDs:         matchedUIDs,
+			UnmatchedUIDs:       unmatchedUIDs,
+			BlacklistedExcluded: blacklist.Excluded(),
 		}, nil
 	}
 	if strings.TrimSpace(shortLinkDomain) == "" {
 		logger.Printf("fetchAudiencePhonesByUIDs skipped short links generation: campaign_id=%d short_link_domain=empty", c.ID)
 		return &AudiencePhonesResult{
-			Phones:        phones,
-			IDs:           ids,
-			UIDs:          uids,
-			Codes:         make([]string, len(phones)),
-			MatchedUIDs:   matchedUIDs,
-			UnmatchedUIDs: unmatchedUIDs,
+			Phones:              phones,
+			IDs:                 ids,
+			UIDs:                uids,
+			Codes:               make([]string, len(phones)),
+			MatchedUIDs:         matchedUIDs,
+			UnmatchedUIDs:       unmatchedUIDs,
+			BlacklistedExcluded: blacklist.Excluded(),
 		}, nil
 	}
 
@@ -274,12 +417,13 @@ func fetchAudiencePhonesByUIDs(
 	}
 
 	return &AudiencePhonesResult{
-		Phones:        phones,
-		IDs:           ids,
-		UIDs:          uids,
-		Codes:         codes,
-		MatchedUIDs:   matchedUIDs,
-		UnmatchedUIDs: unmatchedUIDs,
+		Phones:              phones,
+		IDs:                 ids,
+		UIDs:                uids,
+		Codes:               codes,
+		MatchedUIDs:         matchedUIDs,
+		UnmatchedUIDs:       unmatchedUIDs,
+		BlacklistedExcluded: blacklist.Excluded(),
 	}, nil
 }
 
@@ -351,6 +495,88 @@ func allocateTrackingIDs(ctx context.Context, db *gorm.DB, count int) ([]string,
 	return ids, nil
 }
 
+// saveCampaignBatch runs save in a transaction that holds the campaign's
+// advisory lock and only while the campaign is still running. Pause and cancel
+// requests take the same lock, so a batch is either recorded before the
+// campaign stops or not sent at all. While the campaign is paused it waits
+// for a resume; once the campaign is no longer running or paused it returns
+// errCampaignExecutionStopped.
+func saveCampaignBatch(ctx context.Context, db *gorm.DB, logger *log.Logger, campaignID uint, save func(txCtx context.Context) error) error {
+	for {
+		var status models.CampaignStatus
+		err := repository.WithTransaction(ctx, db, func(txCtx context.Context) error {
+			tx := db.WithContext(txCtx)
+			if t, ok := txCtx.Value(repository.TxContextKey).(*gorm.DB); ok && t != nil {
+				tx = t.WithContext(txCtx)
+			}
+			// Same key as lockCampaignExecution in business_flow
+			if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext('campaign_execution'), ?)", int64(campaignID)).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&models.Campaign{}).
+				Select("status").
+				Where("id = ?", campaignID).
+				Scan(&status).Error; err != nil {
+				return err
+			}
+			if status != models.CampaignStatusRunning {
+				return nil
+			}
+			return save(txCtx)
+		})
+		if err != nil {
+			return err
+		}
+
+		switch status {
+		case models.CampaignStatusRunning:
+			return nil
+		case models.CampaignStatusPaused:
+			logger.Printf("campaign id=%d is paused, waiting for resume", campaignID)
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("context expired while campaign id=%d paused: %w", campaignID, ctx.Err())
+			case <-time.After(campaignPausePollInterval):
+			}
+		default:
+			return errCampaignExecutionStopped
+		}
+	}
+}
+
+// executeSandboxCampaign runs a sandbox campaign against a mock provider:
+// nothing is sent, every audience counts as delivered in one part and the
+// campaign moves through running to executed as a real one would.
+func executeSandboxCampaign(ctx context.Context, botClient BotClient, logger *log.Logger, token string, c dto.BotGetCampaignResponse) error {
+	if err := botClient.MoveCampaignToRunning(ctx, token, c.ID); err != nil {
+		return fmt.Errorf("move sandbox campaign id=%d to running: %w", c.ID, err)
+	}
+
+	var n int64
+	if c.NumAudiences != nil {
+		n = int64(*c.NumAudiences)
+	}
+	stats := map[string]any{
+		"aggregatedTotalRecords":          n,
+		"aggregatedTotalSent":             n,
+		"aggregatedTotalParts":            n,
+		"aggregatedTotalDeliveredParts":   n,
+		"aggregatedTotalUnDeliveredParts": int64(0),
+		"aggregatedTotalUnKnownParts":     int64(0),
+		"sandbox":                         true,
+		"updatedAt":                       utils.UTCNow().Format(time.RFC3339),
+	}
+	if err := botClient.PushCampaignStatistics(ctx, c.ID, stats); err != nil {
+		return fmt.Errorf("push statistics for sandbox campaign id=%d: %w", c.ID, err)
+	}
+
+	if err := botClient.MoveCampaignToExecuted(ctx, token, c.ID); err != nil {
+		return fmt.Errorf("move sandbox campaign id=%d to executed: %w", c.ID, err)
+	}
+	logger.Printf("sandbox campaign id=%d executed against the mock provider (audiences=%d)", c.ID, n)
+	return nil
+}
+
 // retryBackoffDelay returns an exponential back-off duration for the given
 // attempt index (0-based), starting at base and capped at max.
 func retryBackoffDelay(attempt int, base, max time.Duration) time.Duration {
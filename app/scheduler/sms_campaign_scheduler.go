@@ -4,7 +4,9 @@ package scheduler
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"hash/fnv"
 	"io"
 	"log"
 	"os"
@@ -17,8 +19,11 @@ import (
 	"github.com/amirphl/Yamata-no-Orochi/app/dto"
 	"github.com/amirphl/Yamata-no-Orochi/config"
 	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/personalization"
+	"github.com/amirphl/Yamata-no-Orochi/pricing"
 	"github.com/amirphl/Yamata-no-Orochi/repository"
 	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/amirphl/Yamata-no-Orochi/utils/phonenumber"
 	"github.com/google/uuid"
 	"github.com/lib/pq"
 )
@@ -26,8 +31,10 @@ import (
 // TODO: Tx management in queries, especially around processed_campaign creation and audience fetching to ensure consistency
 
 const (
-	smsSendBatchSize     = 200 // NOTE: MUST BE LESS THAN 250
-	smsStatusJobMaxRetry = 3
+	smsSendBatchSize = 200 // NOTE: MUST BE LESS THAN 250
+	// smsStatusQueryMaxIDs bounds the tracking IDs of one status query; they
+	// are sent in the query string
+	smsStatusQueryMaxIDs = 200
 )
 
 type SMSCampaignScheduler struct {
@@ -38,9 +45,9 @@ type SMSCampaignScheduler struct {
 	jobRepo   repository.CampaignStatusJobRepository
 	resRepo   repository.SMSStatusResultRepository
 	statsRepo repository.SrcLayerAllStatsRepository
-	notifier  NotificationSender
+	jobs      JobEnqueuer
 	logger    *log.Logger
-	interval  time.Duration
+	*campaignTiming
 
 	db       *gorm.DB
 	adminCfg config.AdminConfig
@@ -55,13 +62,19 @@ type SMSCampaignScheduler struct {
 
 	audienceCache       *AudienceCache
 	bundleAudienceCache *BundleAudienceCache
+	blacklistRepo       repository.BlacklistedNumberRepository
+	quotaRepo           repository.CustomerSendingQuotaRepository
+	lineRepo            repository.LineNumberRepository
+	lineLimiter         *lineRateLimiter
+	retryRepo           repository.SMSRecipientRetryRepository
+	// retryPolicy is nil unless recipient retries are enabled
+	retryPolicy *SMSRetryPolicy
 }
 
-// NotificationSender is a minimal interface extracted from NotificationService for SMS
-// This keeps the scheduler independent and easy to test
-type NotificationSender interface {
-	SendSMS(ctx context.Context, to string, message string, trackingID *int64) error
-	SendSMSBulk(ctx context.Context, mobiles []string, message string, trackingID *int64) error
+// JobEnqueuer is the part of the job queue the schedulers hand follow-up
+// work to, such as admin notifications and audience pushes
+type JobEnqueuer interface {
+	Enqueue(ctx context.Context, jobType string, payload any) error
 }
 
 func NewCampaignScheduler(
@@ -72,7 +85,7 @@ func NewCampaignScheduler(
 	jobRepo repository.CampaignStatusJobRepository,
 	resRepo repository.SMSStatusResultRepository,
 	statsRepo repository.SrcLayerAllStatsRepository,
-	notifier NotificationSender,
+	jobs JobEnqueuer,
 	db *gorm.DB,
 	logger *log.Logger,
 	interval time.Duration,
@@ -80,10 +93,6 @@ func NewCampaignScheduler(
 	botCfg config.BotConfig,
 	adminCfg config.AdminConfig,
 ) *SMSCampaignScheduler {
-	if interval <= 0 {
-		interval = time.Minute
-	}
-
 	if botCfg.APIDomain == "" {
 		botCfg.APIDomain = defaultBotAPIDomain
 	}
@@ -96,16 +105,21 @@ func NewCampaignScheduler(
 		jobRepo:             jobRepo,
 		resRepo:             resRepo,
 		statsRepo:           statsRepo,
-		notifier:            notifier,
+		jobs:                jobs,
 		logger:              logger,
 		db:                  db,
-		interval:            interval,
+		campaignTiming:      newCampaignTiming(interval, 0),
 		adminCfg:            adminCfg,
 		botCfg:              botCfg,
-		botClient:           newHTTPBotClient(botCfg),
+		botClient:           newBotClient(botCfg),
 		smsClient:           newHTTPPayamSMSClient(payamSMSCfg),
 		audienceCache:       NewAudienceCache(repository.NewAudienceSelectionRepository(db)),
 		bundleAudienceCache: NewBundleAudienceCache(repository.NewBundleAudienceSelectionRepository(db)),
+		blacklistRepo:       repository.NewBlacklistedNumberRepository(db),
+		quotaRepo:           repository.NewCustomerSendingQuotaRepository(db),
+		lineRepo:            repository.NewLineNumberRepository(db),
+		lineLimiter:         newLineRateLimiter(),
+		retryRepo:           repository.NewSMSRecipientRetryRepository(db),
 		schedulerName:       "sms",
 	}
 
@@ -129,13 +143,17 @@ func (s *SMSCampaignScheduler) initSchedulerLogger() error {
 
 func (s *SMSCampaignScheduler) Start(parent context.Context) func() {
 	go func() {
-		ticker := time.NewTicker(s.interval)
+		interval, changed := s.tick()
+		ticker := time.NewTicker(interval)
 		defer ticker.Stop()
 
 		for {
 			select {
 			case <-parent.Done():
 				return
+			case <-changed:
+				interval, changed = s.tick()
+				ticker.Reset(interval)
 			case <-ticker.C:
 				func() {
 					ctx, cancel := context.WithTimeout(parent, 20*time.Minute) // TODO:
@@ -147,6 +165,9 @@ func (s *SMSCampaignScheduler) Start(parent context.Context) func() {
 	}()
 
 	go s.startStatusJobWorker(parent)
+	if s.retryPolicy != nil {
+		go s.startRetryWorker(parent)
+	}
 
 	return func() {
 		if s.logFile != nil {
@@ -217,6 +238,10 @@ func (s *SMSCampaignScheduler) runOnce(ctx context.Context, parent context.Conte
 }
 
 func (s *SMSCampaignScheduler) processSMSCampaign(ctx context.Context, jazzAccessToken string, c dto.BotGetCampaignResponse) error {
+	if c.Sandbox {
+		return executeSandboxCampaign(ctx, s.botClient, s.logger, jazzAccessToken, c)
+	}
+
 	// Sender from campaign line number
 	if c.LineNumber == nil {
 		return fmt.Errorf("resolve SMS sender for campaign id=%d: sender is nil", c.ID)
@@ -243,6 +268,10 @@ func (s *SMSCampaignScheduler) processSMSCampaign(ctx context.Context, jazzAcces
 		codes        []string
 		unmatchedUID []string
 		selectionID  *uint
+		blacklisted  int64
+		// frequencyCapped is only counted for tag based audiences; excel
+		// audiences are sent as uploaded
+		frequencyCapped int64
 	)
 	if hasTargetAudienceExcelFileUUID(c.TargetAudienceExcelFileUUID) {
 		if err := ctx.Err(); err != nil {
@@ -258,7 +287,7 @@ func (s *SMSCampaignScheduler) processSMSCampaign(ctx context.Context, jazzAcces
 		if c.ShortLinkDomain != nil {
 			excelShortLinkDomain = *c.ShortLinkDomain
 		}
-		audienceResult, err := fetchAudiencePhonesByUIDs(ctx, s.logger, s.audRepo, s.botClient, c, jazzAccessToken, fileUIDs, excelShortLinkDomain)
+		audienceResult, err := fetchAudiencePhonesByUIDs(ctx, s.logger, s.audRepo, s.blacklistRepo, s.botClient, c, jazzAccessToken, fileUIDs, excelShortLinkDomain)
 		if err != nil {
 			return fmt.Errorf("fetch audience phones by UIDs for campaign id=%d: %w", c.ID, err)
 		}
@@ -267,6 +296,7 @@ func (s *SMSCampaignScheduler) processSMSCampaign(ctx context.Context, jazzAcces
 		uids = audienceResult.UIDs
 		codes = audienceResult.Codes
 		unmatchedUID = audienceResult.UnmatchedUIDs
+		blacklisted = audienceResult.BlacklistedExcluded
 		selectionID = nil
 		s.logger.Printf("SMS scheduler: campaign id=%d fetched %d phones via excel (unmatched=%d)", c.ID, len(phones), len(unmatchedUID))
 	} else {
@@ -293,6 +323,8 @@ func (s *SMSCampaignScheduler) processSMSCampaign(ctx context.Context, jazzAcces
 		uids = audienceResult.UIDs
 		codes = audienceResult.Codes
 		selectionID = utils.ToPtr(audienceResult.SelectionID)
+		blacklisted = audienceResult.BlacklistedExcluded
+		frequencyCapped = audienceResult.FrequencyCapped
 		s.logger.Printf("SMS scheduler: campaign id=%d fetched %d phones (selection_id=%d)", c.ID, len(phones), audienceResult.SelectionID)
 	}
 
@@ -302,7 +334,17 @@ func (s *SMSCampaignScheduler) processSMSCampaign(ctx context.Context, jazzAcces
 	if len(codes) != len(phones) {
 		return fmt.Errorf("audience codes mismatch for campaign id=%d: phones=%d codes=%d", c.ID, len(phones), len(codes))
 	}
-	s.logger.Printf("SMS scheduler: campaign id=%d audience ready: phones=%d unmatched=%d", c.ID, len(phones), len(unmatchedUID))
+	keep, err := sendingQuotaKeep(ctx, s.quotaRepo, c.CustomerID, len(phones))
+	if err != nil {
+		return fmt.Errorf("apply sending quota for campaign id=%d: %w", c.ID, err)
+	}
+	quotaExcluded := int64(len(phones) - keep)
+	if quotaExcluded > 0 {
+		phones, ids, codes = phones[:keep], ids[:keep], codes[:keep]
+		uids = uids[:min(keep, len(uids))]
+		s.logger.Printf("SMS scheduler: campaign id=%d truncated to sending quota: kept=%d dropped=%d", c.ID, keep, quotaExcluded)
+	}
+	s.logger.Printf("SMS scheduler: campaign id=%d audience ready: phones=%d unmatched=%d blacklisted=%d frequency_capped=%d quota_excluded=%d", c.ID, len(phones), len(unmatchedUID), blacklisted, frequencyCapped, quotaExcluded)
 
 	campaignJSON, err := json.Marshal(c)
 	if err != nil {
@@ -320,6 +362,9 @@ func (s *SMSCampaignScheduler) processSMSCampaign(ctx context.Context, jazzAcces
 			AudienceCodes:       []string{},
 			LastAudienceID:      nil,
 			AudienceSelectionID: selectionID,
+			BlacklistedExcluded: blacklisted,
+			QuotaExcluded:       quotaExcluded,
+			FrequencyCapped:     frequencyCapped,
 			Statistics:          nil,
 		}
 		if err := s.pcRepo.Save(txCtx, pc); err != nil {
@@ -351,6 +396,9 @@ func (s *SMSCampaignScheduler) processSMSCampaign(ctx context.Context, jazzAcces
 		}
 	}
 
+	personalized := campaignUsesPersonalization(c)
+	senders := s.senderLines(ctx, sender)
+	stopped := false
 	for start := 0; start < len(phones); start += smsSendBatchSize {
 		if err := ctx.Err(); err != nil {
 			return fmt.Errorf("context expired at batch start=%d for campaign id=%d: %w", start, c.ID, err)
@@ -363,6 +411,7 @@ func (s *SMSCampaignScheduler) processSMSCampaign(ctx context.Context, jazzAcces
 		batchCodes := codes[start:end]
 
 		items := make([]PayamSMSItem, 0, len(batchPhones))
+		operators := make([]string, 0, len(batchPhones))
 		rows := make([]*models.SentSMS, 0, len(batchPhones))
 
 		s.logger.Printf("SMS scheduler: campaign id=%d allocating tracking ids for batch [%d,%d)", c.ID, start, end)
@@ -371,13 +420,39 @@ func (s *SMSCampaignScheduler) processSMSCampaign(ctx context.Context, jazzAcces
 			return fmt.Errorf("allocate tracking ids for batch [%d,%d) campaign id=%d: %w", start, end, c.ID, err)
 		}
 
+		var attrs map[int64]map[string]string
+		if personalized {
+			attrs, err = s.audRepo.AttributesByIDs(ctx, batchIDs)
+			if err != nil {
+				return fmt.Errorf("load personalization attributes for batch [%d,%d) campaign id=%d: %w", start, end, c.ID, err)
+			}
+		}
+
 		for i, p := range batchPhones {
-			body := s.buildSMSBody(c, batchCodes[i], batchUIDs[i])
+			msg := c
+			var variant *string
+			if len(c.Variants) > 0 {
+				v := c.Variants[pickContentVariant(c.Variants, p)]
+				msg.Content = &v.Content
+				variant = &v.Label
+			}
+			if personalized && msg.Content != nil {
+				rendered := personalization.Render(*msg.Content, attrs[batchIDs[i]])
+				msg.Content = &rendered
+			}
+			body := s.buildSMSBody(msg, batchCodes[i], batchUIDs[i])
 			trackingID := trackingIDs[i]
+			operator := recipientOperator(p)
+			operators = append(operators, operator)
+			var rowOperator *string
+			if operator != "" {
+				rowOperator = &operator
+			}
 			items = append(items, PayamSMSItem{
 				Recipient:  p,
 				Body:       body,
 				TrackingID: trackingID,
+				Parts:      pricing.CountSMSSegments(body).Parts,
 			})
 			rows = append(rows, &models.SentSMS{
 				ProcessedCampaignID: pc.ID,
@@ -385,11 +460,13 @@ func (s *SMSCampaignScheduler) processSMSCampaign(ctx context.Context, jazzAcces
 				PartsDelivered:      0,
 				Status:              models.SMSSendStatusPending,
 				TrackingID:          trackingID,
+				Variant:             variant,
+				Operator:            rowOperator,
 			})
 		}
 
 		lastBatchID := batchIDs[len(batchIDs)-1]
-		if err := repository.WithTransaction(ctx, s.db, func(txCtx context.Context) error {
+		if err := saveCampaignBatch(ctx, s.db, s.logger, c.ID, func(txCtx context.Context) error {
 			if len(rows) > 0 {
 				if err := s.sentRepo.SaveBatch(txCtx, rows); err != nil {
 					return fmt.Errorf("save batch rows: %w", err)
@@ -402,42 +479,28 @@ func (s *SMSCampaignScheduler) processSMSCampaign(ctx context.Context, jazzAcces
 			}
 			return nil
 		}); err != nil {
+			if errors.Is(err, errCampaignExecutionStopped) {
+				s.logger.Printf("SMS scheduler: campaign id=%d stopped after %d recipients", c.ID, start)
+				uids, codes = uids[:start], codes[:start]
+				stopped = true
+				break
+			}
 			return fmt.Errorf("save batch [%d,%d) for campaign id=%d: %w", start, end, c.ID, err)
 		}
 		s.logger.Printf("SMS scheduler: campaign id=%d batch [%d,%d) saved, sending to SMS provider", c.ID, start, end)
 
-		batchResponses, batchErr := s.smsClient.SendBatch(ctx, sender, items)
-		if batchErr != nil {
-			s.logger.Printf("SMS scheduler: send batch [%d,%d) failed for campaign id=%d: %v", start, end, c.ID, batchErr)
-			// TODO: How to handle this error? Retry sending? Skip to next batch?
-		}
-
-		responseByTrackingID := make(map[string]*PayamSMSResponseItem, len(batchResponses))
-		for i := range batchResponses {
-			resp := batchResponses[i]
-			trackingID := strings.TrimSpace(resp.TrackingID)
-			if trackingID == "" {
-				continue
-			}
-			respCopy := resp
-			responseByTrackingID[trackingID] = &respCopy
-		}
-		s.logger.Printf("SMS scheduler: campaign id=%d batch [%d,%d) SMS provider responded: sent=%d responses=%d", c.ID, start, end, len(items), len(batchResponses))
-
-		sendUpdates := make([]repository.SentSMSProviderUpdate, 0, len(items))
-		for _, item := range items {
-			trackingID := strings.TrimSpace(item.TrackingID)
-			if trackingID == "" {
-				continue
-			}
-			sendUpdates = append(sendUpdates, buildSMSProviderUpdate(trackingID, responseByTrackingID[trackingID], batchErr))
-		}
+		sendUpdates, throttleErr := s.sendByOperator(ctx, c.ID, pc.ID, senders, items, operators)
+		s.logger.Printf("SMS scheduler: campaign id=%d batch [%d,%d) SMS provider responded: sent=%d parts=%d updates=%d", c.ID, start, end, len(items), smsItemsParts(items), len(sendUpdates))
+		s.handleSendFailures(ctx, c.ID, pc.ID, items, sendUpdates)
 		if len(sendUpdates) > 0 {
 			if updateErr := s.sentRepo.UpdateProviderFieldsByTrackingIDs(ctx, sendUpdates); updateErr != nil {
 				s.logger.Printf("SMS scheduler: failed to batch update sent_sms provider fields for campaign id=%d: %v", c.ID, updateErr)
 				// NOTE: Error silent here; not returning to avoid blocking further processing
 			}
 		}
+		if throttleErr != nil {
+			return fmt.Errorf("wait for sender line capacity at batch [%d,%d) campaign id=%d: %w", start, end, c.ID, throttleErr)
+		}
 
 		if err := s.scheduleStatusCheckJobs(ctx, pc.ID, trackingIDs); err != nil {
 			s.logger.Printf("SMS scheduler: failed to schedule status jobs for campaign id=%d: %v", c.ID, err)
@@ -456,25 +519,263 @@ func (s *SMSCampaignScheduler) processSMSCampaign(ctx context.Context, jazzAcces
 		}
 	}
 
-	s.logger.Printf("SMS scheduler: campaign id=%d all batches sent", c.ID)
+	if stopped {
+		s.logger.Printf("SMS scheduler: campaign id=%d stopped before all batches were sent", c.ID)
+	} else {
+		s.logger.Printf("SMS scheduler: campaign id=%d all batches sent", c.ID)
 
-	if err := s.botClient.MoveCampaignToExecuted(ctx, jazzAccessToken, c.ID); err != nil {
-		return fmt.Errorf("move campaign id=%d to executed: %w", c.ID, err)
+		if err := s.botClient.MoveCampaignToExecuted(ctx, jazzAccessToken, c.ID); err != nil {
+			return fmt.Errorf("move campaign id=%d to executed: %w", c.ID, err)
+		}
+		s.logger.Printf("SMS scheduler: campaign id=%d moved to executed", c.ID)
 	}
-	s.logger.Printf("SMS scheduler: campaign id=%d moved to executed", c.ID)
 
-	go func(campaignID uint, uids, codes []string) {
-		pushCtx, cancel := context.WithTimeout(context.Background(), 15*time.Minute)
-		defer cancel()
-		if err := s.botClient.PushCampaignAudienceUIDs(pushCtx, campaignID, uids, codes); err != nil {
-			s.logger.Printf("SMS scheduler: push audience UIDs failed for campaign id=%d: %v", campaignID, err)
-			s.notifyAdmin(fmt.Sprintf("SMS Scheduler: push audience UIDs failed for campaign id=%d: %v", campaignID, err))
+	if s.jobs != nil {
+		push := models.PushAudienceUIDsJob{CampaignID: c.ID, UIDs: uids, Codes: codes}
+		if err := s.jobs.Enqueue(ctx, models.JobTypePushAudienceUIDs, push); err != nil {
+			s.logger.Printf("SMS scheduler: queue audience UIDs push failed for campaign id=%d: %v", c.ID, err)
 		}
-	}(c.ID, uids, codes)
+	}
 
 	return nil
 }
 
+// senderLines returns the lines a campaign may send from: its own line first,
+// then the other active lines of its pool, highest priority first. The rate
+// limits of those lines are refreshed on the way. When the line cannot be
+// looked up the campaign sends from its own line only, to every operator.
+func (s *SMSCampaignScheduler) senderLines(ctx context.Context, sender string) []models.LineNumber {
+	lines := []models.LineNumber{{LineNumber: sender}}
+	if s.lineRepo == nil {
+		return lines
+	}
+	primary, err := s.lineRepo.ByValue(ctx, sender)
+	if err != nil {
+		s.logger.Printf("SMS scheduler: lookup sender line %s failed: %v", sender, err)
+		return lines
+	}
+	if primary == nil {
+		return lines
+	}
+	lines[0] = *primary
+	rate, burst := primary.SendRate()
+	s.lineLimiter.configure(primary.LineNumber, rate, burst)
+	if primary.Pool == nil || *primary.Pool == "" {
+		return lines
+	}
+
+	members, err := s.lineRepo.ByFilter(ctx, models.LineNumberFilter{
+		Pool:     primary.Pool,
+		IsActive: utils.ToPtr(true),
+	}, "priority DESC NULLS LAST, id ASC", 0, 0)
+	if err != nil {
+		s.logger.Printf("SMS scheduler: list pool %s of sender line %s failed: %v", *primary.Pool, sender, err)
+		return lines
+	}
+	for _, m := range members {
+		if m.LineNumber == sender {
+			continue
+		}
+		rate, burst := m.SendRate()
+		s.lineLimiter.configure(m.LineNumber, rate, burst)
+		lines = append(lines, *m)
+	}
+	return lines
+}
+
+// recipientOperator returns the mobile operator of phone, or "" when its
+// prefix is unknown
+func recipientOperator(phone string) string {
+	n, err := phonenumber.Parse(phone)
+	if err != nil {
+		return ""
+	}
+	return string(n.Operator())
+}
+
+// routeSenderLines returns, in order, the lines certified for operator. When
+// none is, all lines are returned and ok is false.
+func routeSenderLines(lines []models.LineNumber, operator string) (routed []string, ok bool) {
+	for _, l := range lines {
+		if l.CertifiedFor(operator) {
+			routed = append(routed, l.LineNumber)
+		}
+	}
+	if len(routed) > 0 {
+		return routed, true
+	}
+	for _, l := range lines {
+		routed = append(routed, l.LineNumber)
+	}
+	return routed, false
+}
+
+// sendByOperator splits items by the operator of their recipient, operators
+// being parallel to items, and sends each group through the lines certified
+// for that operator. Groups go out in the order their operator first appears.
+func (s *SMSCampaignScheduler) sendByOperator(ctx context.Context, campaignID, processedCampaignID uint, lines []models.LineNumber, items []PayamSMSItem, operators []string) ([]repository.SentSMSProviderUpdate, error) {
+	groups := make(map[string][]PayamSMSItem)
+	var order []string
+	for i, item := range items {
+		op := operators[i]
+		if _, seen := groups[op]; !seen {
+			order = append(order, op)
+		}
+		groups[op] = append(groups[op], item)
+	}
+
+	updates := make([]repository.SentSMSProviderUpdate, 0, len(items))
+	for _, op := range order {
+		routed, ok := routeSenderLines(lines, op)
+		if !ok {
+			s.logger.Printf("SMS scheduler: campaign id=%d has no line certified for operator %s, sending %d messages from its own lines", campaignID, op, len(groups[op]))
+		}
+		sent, err := s.sendThrottled(ctx, campaignID, processedCampaignID, routed, groups[op])
+		updates = append(updates, sent...)
+		if err != nil {
+			return updates, err
+		}
+	}
+	return updates, nil
+}
+
+// sendThrottled sends items within the rate limits of lines, spilling over to
+// the next line while the earlier ones are saturated, and returns the provider
+// update of every item sent. Chunks the provider rejects are queued to be
+// resent with backoff. It only fails when ctx ends while waiting for
+// capacity; the updates of the items sent so far are still returned.
+func (s *SMSCampaignScheduler) sendThrottled(ctx context.Context, campaignID, processedCampaignID uint, lines []string, items []PayamSMSItem) ([]repository.SentSMSProviderUpdate, error) {
+	updates := make([]repository.SentSMSProviderUpdate, 0, len(items))
+	for len(items) > 0 {
+		line, n, err := s.lineLimiter.acquire(ctx, lines, len(items))
+		if err != nil {
+			return updates, err
+		}
+		chunk := items[:n]
+		items = items[n:]
+		if line != lines[0] {
+			s.logger.Printf("SMS scheduler: campaign id=%d line %s saturated, sending %d messages from %s", campaignID, lines[0], n, line)
+		}
+
+		responses, sendErr := s.smsClient.SendBatch(ctx, line, chunk)
+		if sendErr != nil {
+			s.logger.Printf("SMS scheduler: send %d messages from %s failed for campaign id=%d: %v", n, line, campaignID, sendErr)
+			s.queueFailedBatch(ctx, campaignID, processedCampaignID, line, chunk, sendErr)
+		}
+
+		responseByTrackingID := make(map[string]*PayamSMSResponseItem, len(responses))
+		for i := range responses {
+			resp := responses[i]
+			trackingID := strings.TrimSpace(resp.TrackingID)
+			if trackingID == "" {
+				continue
+			}
+			respCopy := resp
+			responseByTrackingID[trackingID] = &respCopy
+		}
+		for _, item := range chunk {
+			trackingID := strings.TrimSpace(item.TrackingID)
+			if trackingID == "" {
+				continue
+			}
+			update := buildSMSProviderUpdate(trackingID, responseByTrackingID[trackingID], sendErr)
+			update.Sender = utils.ToPtr(line)
+			updates = append(updates, update)
+		}
+	}
+	return updates, nil
+}
+
+// smsItemsParts sums the SMS parts of the items
+func smsItemsParts(items []PayamSMSItem) uint64 {
+	var parts uint64
+	for _, item := range items {
+		parts += item.Parts
+	}
+	return parts
+}
+
+// queueFailedBatch persists a chunk the provider rejected as a send_sms_batch
+// job, so it is resent with backoff and dead-lettered for admins if it keeps
+// failing
+func (s *SMSCampaignScheduler) queueFailedBatch(ctx context.Context, campaignID, processedCampaignID uint, sender string, items []PayamSMSItem, sendErr error) {
+	if s.jobs == nil {
+		return
+	}
+	batch := models.SendSMSBatchJob{
+		CampaignID:          campaignID,
+		ProcessedCampaignID: processedCampaignID,
+		Sender:              sender,
+		Items:               make([]models.SMSBatchJobItem, 0, len(items)),
+		Error:               sendErr.Error(),
+	}
+	for _, item := range items {
+		batch.Items = append(batch.Items, models.SMSBatchJobItem{Recipient: item.Recipient, Body: item.Body, TrackingID: item.TrackingID, Parts: item.Parts})
+	}
+	if err := s.jobs.Enqueue(context.WithoutCancel(ctx), models.JobTypeSendSMSBatch, batch); err != nil {
+		s.logger.Printf("SMS scheduler: queue failed batch of %d messages for campaign id=%d: %v", len(items), campaignID, err)
+		s.notifyAdmin(fmt.Sprintf("SMS Scheduler: %d messages of campaign id=%d failed and could not be queued for resend: %v", len(items), campaignID, err))
+	}
+}
+
+// ResendBatch handles send_sms_batch jobs. The whole batch goes to the
+// provider in one request once the sender line has capacity for it, so a
+// failed attempt never leaves part of the batch sent.
+func (s *SMSCampaignScheduler) ResendBatch(ctx context.Context, payload json.RawMessage) error {
+	var batch models.SendSMSBatchJob
+	if err := json.Unmarshal(payload, &batch); err != nil {
+		return fmt.Errorf("decode send_sms_batch payload: %w", err)
+	}
+	if len(batch.Items) == 0 {
+		return nil
+	}
+
+	lines := []string{batch.Sender}
+	for granted := 0; granted < len(batch.Items); {
+		_, n, err := s.lineLimiter.acquire(ctx, lines, len(batch.Items)-granted)
+		if err != nil {
+			return fmt.Errorf("wait for sender line %s capacity: %w", batch.Sender, err)
+		}
+		granted += n
+	}
+
+	items := make([]PayamSMSItem, 0, len(batch.Items))
+	trackingIDs := make([]string, 0, len(batch.Items))
+	for _, item := range batch.Items {
+		items = append(items, PayamSMSItem{Recipient: item.Recipient, Body: item.Body, TrackingID: item.TrackingID, Parts: item.Parts})
+		trackingIDs = append(trackingIDs, item.TrackingID)
+	}
+	responses, err := s.smsClient.SendBatch(ctx, batch.Sender, items)
+	if err != nil {
+		return fmt.Errorf("resend %d messages from %s for campaign id=%d: %w", len(items), batch.Sender, batch.CampaignID, err)
+	}
+	s.logger.Printf("SMS scheduler: resent %d messages from %s for campaign id=%d", len(items), batch.Sender, batch.CampaignID)
+
+	responseByTrackingID := make(map[string]*PayamSMSResponseItem, len(responses))
+	for i := range responses {
+		responseByTrackingID[strings.TrimSpace(responses[i].TrackingID)] = &responses[i]
+	}
+	updates := make([]repository.SentSMSProviderUpdate, 0, len(items))
+	for _, item := range items {
+		trackingID := strings.TrimSpace(item.TrackingID)
+		if trackingID == "" {
+			continue
+		}
+		update := buildSMSProviderUpdate(trackingID, responseByTrackingID[trackingID], nil)
+		update.Sender = utils.ToPtr(batch.Sender)
+		updates = append(updates, update)
+	}
+	s.handleSendFailures(ctx, batch.CampaignID, batch.ProcessedCampaignID, items, updates)
+	// The messages went out; failing the job now would send them again
+	if err := s.sentRepo.UpdateProviderFieldsByTrackingIDs(ctx, updates); err != nil {
+		s.logger.Printf("SMS scheduler: failed to update sent_sms provider fields of resent batch for campaign id=%d: %v", batch.CampaignID, err)
+	}
+	if err := s.scheduleStatusCheckJobs(ctx, batch.ProcessedCampaignID, trackingIDs); err != nil {
+		s.logger.Printf("SMS scheduler: failed to schedule status jobs of resent batch for campaign id=%d: %v", batch.CampaignID, err)
+	}
+	return nil
+}
+
 func (s *SMSCampaignScheduler) validateSMSCampaign(c dto.BotGetCampaignResponse) error {
 	if c.Status != string(models.CampaignStatusApproved) {
 		return fmt.Errorf("campaign status is not approved")
@@ -581,7 +882,18 @@ func (s *SMSCampaignScheduler) fetchSMSAudiencePhones(
 		s.logger.Printf("fetchSMSAudiencePhones selection miss: campaign_id=%d", c.ID)
 	}
 
+	blacklist, err := loadBlacklistFilter(ctx, s.blacklistRepo)
+	if err != nil {
+		s.logger.Printf("fetchSMSAudiencePhones load blacklist failed: campaign_id=%d err=%v", c.ID, err)
+		return nil, err
+	}
+	if err := blacklist.loadFrequencyCap(ctx, s.quotaRepo, s.sentRepo, c.CustomerID, c.ID); err != nil {
+		s.logger.Printf("fetchSMSAudiencePhones load frequency cap failed: campaign_id=%d err=%v", c.ID, err)
+		return nil, err
+	}
+
 	selectAudiences := func(exclude map[int64]struct{}) ([]string, []int64, []string, error) {
+		blacklist.reset()
 		phones := make([]string, 0, numAudiences)
 		ids := make([]int64, 0, numAudiences)
 		uids := make([]string, 0, numAudiences)
@@ -607,6 +919,9 @@ func (s *SMSCampaignScheduler) fetchSMSAudiencePhones(
 					return
 				}
 			}
+			if blacklist.skip(*ap.PhoneNumber) {
+				return
+			}
 			phones = append(phones, *ap.PhoneNumber)
 			ids = append(ids, int64(ap.ID))
 			uids = append(uids, ap.UID)
@@ -681,11 +996,13 @@ func (s *SMSCampaignScheduler) fetchSMSAudiencePhones(
 		s.logger.Printf("fetchSMSAudiencePhones skipped short links generation: campaign_id=%d ad_link=empty", c.ID)
 		s.logger.Printf("fetchSMSAudiencePhones success: campaign_id=%d selected=%d codes_length=%d selection_id=%d ad_link=empty", c.ID, len(phones), len(phones), sel.ID)
 		return &AudiencePhonesResult{
-			Phones:      phones,
-			IDs:         ids,
-			UIDs:        uids,
-			Codes:       make([]string, len(phones)),
-			SelectionID: sel.ID,
+			Phones:              phones,
+			IDs:                 ids,
+			UIDs:                uids,
+			Codes:               make([]string, len(phones)),
+			SelectionID:         sel.ID,
+			BlacklistedExcluded: blacklist.Excluded(),
+			FrequencyCapped:     blacklist.Capped(),
 		}, nil
 	}
 
@@ -695,11 +1012,13 @@ func (s *SMSCampaignScheduler) fetchSMSAudiencePhones(
 		s.logger.Printf("fetchSMSAudiencePhones skipped short links generation: campaign_id=%d short_link_domain=empty", c.ID)
 		s.logger.Printf("fetchSMSAudiencePhones success: campaign_id=%d selected=%d codes_length=%d selection_id=%d short_link_domain=empty", c.ID, len(phones), len(phones), sel.ID)
 		return &AudiencePhonesResult{
-			Phones:      phones,
-			IDs:         ids,
-			UIDs:        uids,
-			Codes:       make([]string, len(phones)),
-			SelectionID: sel.ID,
+			Phones:              phones,
+			IDs:                 ids,
+			UIDs:                uids,
+			Codes:               make([]string, len(phones)),
+			SelectionID:         sel.ID,
+			BlacklistedExcluded: blacklist.Excluded(),
+			FrequencyCapped:     blacklist.Capped(),
 		}, nil
 	}
 
@@ -727,11 +1046,13 @@ func (s *SMSCampaignScheduler) fetchSMSAudiencePhones(
 	}
 	s.logger.Printf("fetchSMSAudiencePhones success: campaign_id=%d selected=%d codes_length=%d selection_id=%d", c.ID, len(phones), len(codes), sel.ID)
 	return &AudiencePhonesResult{
-		Phones:      phones,
-		IDs:         ids,
-		UIDs:        uids,
-		Codes:       codes,
-		SelectionID: sel.ID,
+		Phones:              phones,
+		IDs:                 ids,
+		UIDs:                uids,
+		Codes:               codes,
+		SelectionID:         sel.ID,
+		BlacklistedExcluded: blacklist.Excluded(),
+		FrequencyCapped:     blacklist.Capped(),
 	}, nil
 }
 
@@ -744,6 +1065,7 @@ func (s *SMSCampaignScheduler) selectTagAudiences(
 	tagIDs pq.Int32Array,
 	numAudiences int64,
 	exclude map[int64]struct{},
+	blacklist *blacklistFilter,
 	scoreConstraint *models.NormalizedScoreConstraint,
 ) (phones []string, ids []int64, uids []string, err error) {
 	const limit = 10000000
@@ -761,6 +1083,9 @@ func (s *SMSCampaignScheduler) selectTagAudiences(
 				return
 			}
 		}
+		if blacklist.skip(*ap.PhoneNumber) {
+			return
+		}
 		phones = append(phones, *ap.PhoneNumber)
 		ids = append(ids, int64(ap.ID))
 		uids = append(uids, ap.UID)
@@ -869,7 +1194,17 @@ func (s *SMSCampaignScheduler) fetchSMSAudiencePhonesByBundle(
 		s.logger.Printf("fetchSMSAudiencePhonesByBundle bundle selection miss: campaign_id=%d bundle_id=%d", c.ID, bundleID)
 	}
 
-	phones, ids, uids, err := s.selectTagAudiences(ctx, c.ID, tagIDs, numAudiences, exclude, scoreConstraint)
+	blacklist, err := loadBlacklistFilter(ctx, s.blacklistRepo)
+	if err != nil {
+		s.logger.Printf("fetchSMSAudiencePhonesByBundle load blacklist failed: campaign_id=%d err=%v", c.ID, err)
+		return nil, err
+	}
+	if err := blacklist.loadFrequencyCap(ctx, s.quotaRepo, s.sentRepo, c.CustomerID, c.ID); err != nil {
+		s.logger.Printf("fetchSMSAudiencePhonesByBundle load frequency cap failed: campaign_id=%d err=%v", c.ID, err)
+		return nil, err
+	}
+
+	phones, ids, uids, err := s.selectTagAudiences(ctx, c.ID, tagIDs, numAudiences, exclude, blacklist, scoreConstraint)
 	if err != nil {
 		return nil, err
 	}
@@ -888,22 +1223,26 @@ func (s *SMSCampaignScheduler) fetchSMSAudiencePhonesByBundle(
 	if !hasCampaignAdLink(c.AdLink) {
 		s.logger.Printf("fetchSMSAudiencePhonesByBundle skipped short links: campaign_id=%d ad_link=empty", c.ID)
 		return &AudiencePhonesResult{
-			Phones:      phones,
-			IDs:         ids,
-			UIDs:        uids,
-			Codes:       make([]string, len(phones)),
-			SelectionID: sel.ID,
+			Phones:              phones,
+			IDs:                 ids,
+			UIDs:                uids,
+			Codes:               make([]string, len(phones)),
+			SelectionID:         sel.ID,
+			BlacklistedExcluded: blacklist.Excluded(),
+			FrequencyCapped:     blacklist.Capped(),
 		}, nil
 	}
 
 	if c.ShortLinkDomain == nil || strings.TrimSpace(*c.ShortLinkDomain) == "" {
 		s.logger.Printf("fetchSMSAudiencePhonesByBundle skipped short links: campaign_id=%d short_link_domain=empty", c.ID)
 		return &AudiencePhonesResult{
-			Phones:      phones,
-			IDs:         ids,
-			UIDs:        uids,
-			Codes:       make([]string, len(phones)),
-			SelectionID: sel.ID,
+			Phones:              phones,
+			IDs:                 ids,
+			UIDs:                uids,
+			Codes:               make([]string, len(phones)),
+			SelectionID:         sel.ID,
+			BlacklistedExcluded: blacklist.Excluded(),
+			FrequencyCapped:     blacklist.Capped(),
 		}, nil
 	}
 
@@ -931,11 +1270,13 @@ func (s *SMSCampaignScheduler) fetchSMSAudiencePhonesByBundle(
 	s.logger.Printf("fetchSMSAudiencePhonesByBundle success: campaign_id=%d bundle_id=%d selected=%d codes=%d selection_id=%d",
 		c.ID, bundleID, len(phones), len(codes), sel.ID)
 	return &AudiencePhonesResult{
-		Phones:      phones,
-		IDs:         ids,
-		UIDs:        uids,
-		Codes:       codes,
-		SelectionID: sel.ID,
+		Phones:              phones,
+		IDs:                 ids,
+		UIDs:                uids,
+		Codes:               codes,
+		SelectionID:         sel.ID,
+		BlacklistedExcluded: blacklist.Excluded(),
+		FrequencyCapped:     blacklist.Capped(),
 	}, nil
 }
 
@@ -951,12 +1292,44 @@ func (s *SMSCampaignScheduler) buildSMSBody(c dto.BotGetCampaignResponse, code s
 				domain += "/"
 			}
 			shortened := domain + code
-			return strings.ReplaceAll(content, "{YOUR_LINK}", shortened) + "\n" + "لغو۱۱"
+			return strings.ReplaceAll(content, "{YOUR_LINK}", shortened) + pricing.SMSOptOutSuffix
 		}
 		injected := strings.ReplaceAll(*c.AdLink, "{uid}", uid)
-		return strings.ReplaceAll(content, "{YOUR_LINK}", injected) + "\n" + "لغو۱۱"
+		return strings.ReplaceAll(content, "{YOUR_LINK}", injected) + pricing.SMSOptOutSuffix
 	}
-	return strings.ReplaceAll(content, "{YOUR_LINK}", "") + "\n" + "لغو۱۱"
+	return strings.ReplaceAll(content, "{YOUR_LINK}", "") + pricing.SMSOptOutSuffix
+}
+
+// campaignUsesPersonalization reports whether the content or any A/B variant
+// uses a per-recipient variable, so attributes only load when needed.
+func campaignUsesPersonalization(c dto.BotGetCampaignResponse) bool {
+	if c.Content != nil && personalization.HasVariables(*c.Content) {
+		return true
+	}
+	for _, v := range c.Variants {
+		if personalization.HasVariables(v.Content) {
+			return true
+		}
+	}
+	return false
+}
+
+// pickContentVariant assigns a recipient to an A/B content variant by hashing
+// the phone number onto the cumulative variant weights, so the same recipient
+// always gets the same variant across runs and resumes.
+func pickContentVariant(variants []dto.CampaignContentVariantSpec, recipient string) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(recipient))
+	bucket := uint(h.Sum32() % 100)
+
+	var cumulative uint
+	for i, v := range variants {
+		cumulative += v.Weight
+		if bucket < cumulative {
+			return i
+		}
+	}
+	return len(variants) - 1
 }
 
 func (s *SMSCampaignScheduler) createUnmatchedSentSMSRows(ctx context.Context, processedCampaignID uint, unmatchedUIDs []string) error {
@@ -1007,8 +1380,10 @@ func (s *SMSCampaignScheduler) createUnmatchedSentSMSRows(ctx context.Context, p
 			CorrelationID:       uuid.NewString(),
 			Platform:            models.CampaignPlatformSMS,
 			TrackingIDs:         pq.StringArray(trackingIDs),
-			RetryCount:          0,
+			Status:              models.CampaignStatusJobSucceeded,
+			MaxAttempts:         statusJobMaxRetry,
 			ScheduledAt:         now,
+			NextRunAt:           now,
 			ExecutedAt:          &executedAt,
 			CreatedAt:           now,
 			UpdatedAt:           now.Add(time.Second),
@@ -1060,32 +1435,10 @@ func (s *SMSCampaignScheduler) scheduleStatusCheckJobs(ctx context.Context, proc
 	if len(trackingIDs) == 0 || s.jobRepo == nil {
 		return nil
 	}
-	filteredTrackingIDs := make([]string, 0, len(trackingIDs))
-	for _, id := range trackingIDs {
-		if strings.TrimSpace(id) != "" {
-			filteredTrackingIDs = append(filteredTrackingIDs, strings.TrimSpace(id))
-		}
-	}
-	if len(filteredTrackingIDs) == 0 {
+	jobs := newStatusCheckJobs(models.CampaignPlatformSMS, processedCampaignID, trackingIDs, utils.UTCNow())
+	if len(jobs) == 0 {
 		return nil
 	}
-
-	corrID := uuid.NewString()
-	now := utils.UTCNow()
-	offsets := []time.Duration{1 * time.Minute, 5 * time.Minute, 15 * time.Minute, 24 * time.Hour, 48 * time.Hour}
-	jobs := make([]*models.CampaignStatusJob, 0, len(offsets))
-	for _, off := range offsets {
-		jobs = append(jobs, &models.CampaignStatusJob{
-			ProcessedCampaignID: processedCampaignID,
-			CorrelationID:       corrID,
-			Platform:            models.CampaignPlatformSMS,
-			TrackingIDs:         pq.StringArray(filteredTrackingIDs),
-			RetryCount:          0,
-			ScheduledAt:         now.Add(off),
-			CreatedAt:           now,
-			UpdatedAt:           now,
-		})
-	}
 	return s.jobRepo.SaveBatch(ctx, jobs)
 }
 
@@ -1121,25 +1474,32 @@ func (s *SMSCampaignScheduler) startStatusJobWorker(parent context.Context) {
 				continue
 			}
 
-			for i, job := range jobs {
+			groups := groupSMSStatusJobs(jobs, smsStatusQueryMaxIDs)
+			for i, group := range groups {
 				if parent.Err() != nil {
 					return
 				}
 
+				now := utils.UTCNow()
+				for _, job := range group {
+					observeStatusJobLag(job, now)
+				}
 				jobCtx, jobCancel := context.WithTimeout(parent, 2*time.Minute)
-				err := s.handleStatusJob(jobCtx, job, atiehAccessToken)
+				errs := s.handleStatusJobs(jobCtx, group, atiehAccessToken)
 				jobCancel()
 
-				if err != nil {
-					s.logger.Printf("SMS scheduler: handle status job id=%d failed: %v", job.ID, err)
-					if job.RetryCount >= smsStatusJobMaxRetry {
-						s.notifyAdmin(fmt.Sprintf("SMS scheduler: status job id=%d has failed %d times with error: %v", job.ID, job.RetryCount, err))
+				for j, job := range group {
+					if err := errs[j]; err != nil {
+						s.logger.Printf("SMS scheduler: handle status job id=%d failed: %v", job.ID, err)
+						if job.Status == models.CampaignStatusJobFailed {
+							s.notifyAdmin(fmt.Sprintf("SMS scheduler: status job id=%d has failed %d times with error: %v", job.ID, job.RetryCount, err))
+						}
+					} else {
+						s.logger.Printf("SMS scheduler: handle status job id=%d succeeded", job.ID)
 					}
-				} else {
-					s.logger.Printf("SMS scheduler: handle status job id=%d succeeded", job.ID)
 				}
 
-				if i < len(jobs)-1 {
+				if i < len(groups)-1 {
 					if err := sleepWithContext(parent, time.Second); err != nil {
 						return
 					}
@@ -1149,27 +1509,137 @@ func (s *SMSCampaignScheduler) startStatusJobWorker(parent context.Context) {
 	}
 }
 
+// groupSMSStatusJobs packs due jobs, in order, into groups whose distinct
+// tracking IDs fit one status query. A job larger than maxIDs gets a group of
+// its own.
+func groupSMSStatusJobs(jobs []*models.CampaignStatusJob, maxIDs int) [][]*models.CampaignStatusJob {
+	var (
+		groups [][]*models.CampaignStatusJob
+		group  []*models.CampaignStatusJob
+		seen   map[string]struct{}
+	)
+	for _, job := range jobs {
+		added := 0
+		for _, id := range job.TrackingIDs {
+			if _, ok := seen[id]; !ok {
+				added++
+			}
+		}
+		if len(group) > 0 && len(seen)+added > maxIDs {
+			groups = append(groups, group)
+			group = nil
+		}
+		if len(group) == 0 {
+			seen = make(map[string]struct{}, maxIDs)
+		}
+		group = append(group, job)
+		for _, id := range job.TrackingIDs {
+			seen[id] = struct{}{}
+		}
+	}
+	if len(group) > 0 {
+		groups = append(groups, group)
+	}
+	return groups
+}
+
 func (s *SMSCampaignScheduler) handleStatusJob(ctx context.Context, job *models.CampaignStatusJob, jazzAccessToken string) error {
-	statusResult, fetchErr := s.smsClient.FetchStatus(ctx, jazzAccessToken, []string(job.TrackingIDs))
-	job.RawProviderResponse = statusResult.RawResponse
+	return s.handleStatusJobs(ctx, []*models.CampaignStatusJob{job}, jazzAccessToken)[0]
+}
+
+// handleStatusJobs fetches the statuses of a group of jobs with one PayamSMS
+// query and stores each job's share of the answer. A failed query counts as a
+// failed attempt of every job in the group. Once every message of a batch has
+// a final status, the batch's later checks are skipped. It returns each job's
+// error, in order.
+func (s *SMSCampaignScheduler) handleStatusJobs(ctx context.Context, jobs []*models.CampaignStatusJob, jazzAccessToken string) []error {
+	errs := make([]error, len(jobs))
+
+	ids := make([]string, 0, len(jobs))
+	seen := make(map[string]struct{})
+	for _, job := range jobs {
+		for _, id := range job.TrackingIDs {
+			if _, ok := seen[id]; !ok {
+				seen[id] = struct{}{}
+				ids = append(ids, id)
+			}
+		}
+	}
+
+	statusResult, fetchErr := s.smsClient.FetchStatus(ctx, jazzAccessToken, ids)
 	if fetchErr != nil {
-		now := utils.UTCNow()
-		job.RetryCount++
-		msg := fetchErr.Error()
-		job.Error = &msg
-		job.UpdatedAt = now
-		if job.RetryCount >= smsStatusJobMaxRetry {
-			job.ExecutedAt = &now
-		} else {
-			job.ExecutedAt = nil
+		for i, job := range jobs {
+			job.RawProviderResponse = statusResult.RawResponse
+			recordStatusJobFailure(job, fetchErr, utils.UTCNow())
+			if err := s.jobRepo.Update(ctx, job); err != nil {
+				errs[i] = err
+				continue
+			}
+			observeStatusJobOutcome(job)
+			errs[i] = fetchErr
 		}
-		if err := s.jobRepo.Update(ctx, job); err != nil {
-			return err
+		return errs
+	}
+
+	itemsByID := make(map[string][]PayamStatusResponse, len(statusResult.Items))
+	for _, item := range statusResult.Items {
+		trackingID := strings.TrimSpace(item.TrackingID)
+		itemsByID[trackingID] = append(itemsByID[trackingID], item)
+	}
+
+	finished := make(map[string]bool)
+	for i, job := range jobs {
+		if finished[job.CorrelationID] {
+			continue
+		}
+		var items []PayamStatusResponse
+		for _, id := range job.TrackingIDs {
+			items = append(items, itemsByID[id]...)
+		}
+		if len(jobs) == 1 {
+			job.RawProviderResponse = statusResult.RawResponse
+		} else if raw, err := json.Marshal(items); err == nil {
+			rawText := string(raw)
+			job.RawProviderResponse = &rawText
+		}
+		if err := s.saveStatusJobResult(ctx, job, items); err != nil {
+			errs[i] = err
+			continue
+		}
+		if job.CorrelationID == "" || !smsStatusesFinal(job.TrackingIDs, itemsByID) {
+			continue
+		}
+		finished[job.CorrelationID] = true
+		skipped, err := s.jobRepo.SkipPending(ctx, job.CorrelationID, utils.UTCNow())
+		if err != nil {
+			s.logger.Printf("SMS scheduler: skip later status jobs of correlation_id=%s failed: %v", job.CorrelationID, err)
+			continue
+		}
+		recordStatusJobsSkipped(models.CampaignPlatformSMS, skipped)
+	}
+	return errs
+}
+
+// smsStatusesFinal reports whether every tracking ID has a status whose parts
+// are all delivered or undelivered
+func smsStatusesFinal(trackingIDs []string, itemsByID map[string][]PayamStatusResponse) bool {
+	for _, id := range trackingIDs {
+		items := itemsByID[id]
+		if len(items) == 0 {
+			return false
+		}
+		for _, item := range items {
+			if item.TotalParts <= 0 || item.TotalUnknownParts > 0 {
+				return false
+			}
 		}
-		return fetchErr
 	}
-	statusItems := statusResult.Items
+	return len(trackingIDs) > 0
+}
 
+// saveStatusJobResult stores the job's status items, marks it succeeded and
+// pushes the refreshed campaign statistics
+func (s *SMSCampaignScheduler) saveStatusJobResult(ctx context.Context, job *models.CampaignStatusJob, statusItems []PayamStatusResponse) error {
 	txErr := repository.WithTransaction(ctx, s.db, func(txCtx context.Context) error {
 		now := utils.UTCNow()
 
@@ -1198,14 +1668,13 @@ func (s *SMSCampaignScheduler) handleStatusJob(ctx context.Context, job *models.
 		if err := s.resRepo.SaveBatch(txCtx, statusRows); err != nil {
 			return err
 		}
-		job.ExecutedAt = &now
-		job.Error = nil
-		job.UpdatedAt = now
+		recordStatusJobSuccess(job, now)
 		return s.jobRepo.Update(txCtx, job)
 	})
 	if txErr != nil {
 		return txErr
 	}
+	observeStatusJobOutcome(job)
 
 	stats, err := s.updateProcessedCampaignStats(ctx, job.ProcessedCampaignID)
 	if err != nil {
@@ -1322,14 +1791,14 @@ func (s *SMSCampaignScheduler) updateProcessedCampaignStatsFromSentRows(ctx cont
 }
 
 func (s *SMSCampaignScheduler) notifyAdmin(message string) {
-	if s.notifier == nil {
+	if s.jobs == nil {
 		return
 	}
-	go func(msg string) {
-		for _, mobile := range s.adminCfg.ActiveMobiles() {
-			_ = s.notifier.SendSMS(context.Background(), mobile, msg, nil)
+	for _, mobile := range s.adminCfg.ActiveMobiles() {
+		if err := s.jobs.Enqueue(context.Background(), models.JobTypeSendSMS, models.SendSMSJob{Mobile: mobile, Message: message}); err != nil {
+			s.logger.Printf("queue admin notification failed: %v", err)
 		}
-	}(message)
+	}
 }
 
 func buildSMSProviderUpdate(trackingID string, resp *PayamSMSResponseItem, sendErr error) repository.SentSMSProviderUpdate {
@@ -1337,7 +1806,7 @@ func buildSMSProviderUpdate(trackingID string, resp *PayamSMSResponseItem, sendE
 		TrackingID: trackingID,
 	}
 	if sendErr != nil {
-		code := "SEND_BATCH_FAILED"
+		code := sendBatchFailedCode
 		desc := sendErr.Error()
 		update.ErrorCode = &code
 		update.Description = &desc
@@ -3,19 +3,37 @@ package scheduler
 import (
 	"context"
 	"errors"
+	"fmt"
+	"io"
+	"log"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
 	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/lib/pq"
 )
 
 type stubSMSClient struct {
-	fetchStatusFn func(ctx context.Context, token string, ids []string) (PayamStatusFetchResult, error)
+	fetchStatusFn      func(ctx context.Context, token string, ids []string) (PayamStatusFetchResult, error)
+	sendBatchErr       error
+	sendBatchResponses []PayamSMSResponseItem
 }
 
 func (s *stubSMSClient) SendBatch(ctx context.Context, sender string, items []PayamSMSItem) ([]PayamSMSResponseItem, error) {
-	return nil, nil
+	return s.sendBatchResponses, s.sendBatchErr
+}
+
+type stubJobEnqueuer struct {
+	jobTypes []string
+	payloads []any
+}
+
+func (s *stubJobEnqueuer) Enqueue(ctx context.Context, jobType string, payload any) error {
+	s.jobTypes = append(s.jobTypes, jobType)
+	s.payloads = append(s.payloads, payload)
+	return nil
 }
 
 func (s *stubSMSClient) GetToken(ctx context.Context) (string, error) {
@@ -31,6 +49,7 @@ func (s *stubSMSClient) FetchStatus(ctx context.Context, token string, ids []str
 
 type stubSMSCampaignStatusJobRepo struct {
 	updated []*models.CampaignStatusJob
+	skipped []string
 }
 
 func (s *stubSMSCampaignStatusJobRepo) ByFilter(ctx context.Context, filter any, orderBy string, limit, offset int) ([]*models.CampaignStatusJob, error) {
@@ -61,6 +80,15 @@ func (s *stubSMSCampaignStatusJobRepo) ListDue(ctx context.Context, platform str
 	return nil, nil
 }
 
+func (s *stubSMSCampaignStatusJobRepo) ListByProcessedCampaign(ctx context.Context, processedCampaignID uint, limit int) ([]*models.CampaignStatusJob, error) {
+	return nil, nil
+}
+
+func (s *stubSMSCampaignStatusJobRepo) SkipPending(ctx context.Context, correlationID string, now time.Time) (int64, error) {
+	s.skipped = append(s.skipped, correlationID)
+	return 2, nil
+}
+
 func (s *stubSMSCampaignStatusJobRepo) Update(ctx context.Context, job *models.CampaignStatusJob) error {
 	clone := *job
 	s.updated = append(s.updated, &clone)
@@ -133,15 +161,15 @@ func TestSMSHandleStatusJobFetchFailureMarksExecutedAtOnMaxRetry(t *testing.T) {
 		ID:                  11,
 		ProcessedCampaignID: 88,
 		TrackingIDs:         []string{"trk-2"},
-		RetryCount:          smsStatusJobMaxRetry - 1,
+		RetryCount:          statusJobMaxRetry - 1,
 	}
 
 	err := s.handleStatusJob(context.Background(), job, "token-2")
 	if err == nil {
 		t.Fatalf("expected error")
 	}
-	if job.RetryCount != smsStatusJobMaxRetry {
-		t.Fatalf("expected retry_count=%d, got=%d", smsStatusJobMaxRetry, job.RetryCount)
+	if job.RetryCount != statusJobMaxRetry {
+		t.Fatalf("expected retry_count=%d, got=%d", statusJobMaxRetry, job.RetryCount)
 	}
 	if job.ExecutedAt == nil {
 		t.Fatalf("expected executed_at to be set at retry limit")
@@ -151,6 +179,182 @@ func TestSMSHandleStatusJobFetchFailureMarksExecutedAtOnMaxRetry(t *testing.T) {
 	}
 }
 
+func TestSMSHandleStatusJobsQueriesGroupOnce(t *testing.T) {
+	t.Parallel()
+
+	jobRepo := &stubSMSCampaignStatusJobRepo{}
+	calls := 0
+	s := &SMSCampaignScheduler{
+		jobRepo: jobRepo,
+		smsClient: &stubSMSClient{
+			fetchStatusFn: func(ctx context.Context, token string, ids []string) (PayamStatusFetchResult, error) {
+				calls++
+				if strings.Join(ids, ",") != "trk-1,trk-2,trk-3" {
+					t.Fatalf("expected deduplicated ids of the group, got %v", ids)
+				}
+				return PayamStatusFetchResult{}, errors.New("provider down")
+			},
+		},
+	}
+	jobs := []*models.CampaignStatusJob{
+		{ID: 1, TrackingIDs: []string{"trk-1", "trk-2"}, MaxAttempts: 3},
+		{ID: 2, TrackingIDs: []string{"trk-2", "trk-3"}, MaxAttempts: 3},
+	}
+
+	errs := s.handleStatusJobs(context.Background(), jobs, "token")
+	if calls != 1 {
+		t.Fatalf("expected one status query, got %d", calls)
+	}
+	for i, job := range jobs {
+		if errs[i] == nil || job.RetryCount != 1 || job.Status != models.CampaignStatusJobPending {
+			t.Fatalf("expected retryable failure for job %d, got err=%v retry_count=%d status=%s", job.ID, errs[i], job.RetryCount, job.Status)
+		}
+	}
+	if len(jobRepo.updated) != 2 {
+		t.Fatalf("expected both jobs updated, got=%d", len(jobRepo.updated))
+	}
+}
+
+func TestGroupSMSStatusJobs(t *testing.T) {
+	t.Parallel()
+
+	jobs := []*models.CampaignStatusJob{
+		{ID: 1, TrackingIDs: []string{"a", "b"}},
+		{ID: 2, TrackingIDs: []string{"b", "c"}},
+		{ID: 3, TrackingIDs: []string{"d", "e"}},
+		{ID: 4, TrackingIDs: []string{"f", "g", "h", "i"}},
+	}
+	groups := groupSMSStatusJobs(jobs, 3)
+	var got []string
+	for _, g := range groups {
+		var ids []string
+		for _, job := range g {
+			ids = append(ids, fmt.Sprint(job.ID))
+		}
+		got = append(got, strings.Join(ids, "+"))
+	}
+	if strings.Join(got, " ") != "1+2 3 4" {
+		t.Fatalf("unexpected groups %v", got)
+	}
+}
+
+func TestSMSStatusesFinal(t *testing.T) {
+	t.Parallel()
+
+	items := map[string][]PayamStatusResponse{
+		"delivered":   {{TrackingID: "delivered", TotalParts: 2, TotalDeliveredParts: 2}},
+		"undelivered": {{TrackingID: "undelivered", TotalParts: 1, TotalUndeliveredParts: 1}},
+		"unknown":     {{TrackingID: "unknown", TotalParts: 1, TotalUnknownParts: 1}},
+		"empty":       {{TrackingID: "empty"}},
+	}
+	if !smsStatusesFinal([]string{"delivered", "undelivered"}, items) {
+		t.Fatalf("expected delivered and undelivered messages to be final")
+	}
+	for _, id := range []string{"unknown", "empty", "missing"} {
+		if smsStatusesFinal([]string{"delivered", id}, items) {
+			t.Fatalf("expected %s to keep the batch open", id)
+		}
+	}
+}
+
+func TestSMSSendThrottledQueuesRejectedBatch(t *testing.T) {
+	t.Parallel()
+
+	jobs := &stubJobEnqueuer{}
+	s := &SMSCampaignScheduler{
+		jobs:      jobs,
+		logger:    log.New(io.Discard, "", 0),
+		smsClient: &stubSMSClient{sendBatchErr: errors.New("provider rejected batch")},
+	}
+	items := []PayamSMSItem{
+		{Recipient: "09120000001", Body: "hi", TrackingID: "trk-1"},
+		{Recipient: "09120000002", Body: "hi", TrackingID: "trk-2"},
+	}
+
+	updates, err := s.sendThrottled(context.Background(), 5, 9, []string{"3000"}, items)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(updates) != 2 || updates[0].ErrorCode == nil || *updates[0].ErrorCode != "SEND_BATCH_FAILED" {
+		t.Fatalf("expected the failure recorded on both messages, got %#v", updates)
+	}
+	if len(jobs.jobTypes) != 1 || jobs.jobTypes[0] != models.JobTypeSendSMSBatch {
+		t.Fatalf("expected one send_sms_batch job, got %v", jobs.jobTypes)
+	}
+	batch := jobs.payloads[0].(models.SendSMSBatchJob)
+	if batch.CampaignID != 5 || batch.ProcessedCampaignID != 9 || batch.Sender != "3000" {
+		t.Fatalf("unexpected batch %+v", batch)
+	}
+	if len(batch.Items) != 2 || batch.Items[1].TrackingID != "trk-2" || batch.Error != "provider rejected batch" {
+		t.Fatalf("unexpected batch items %+v", batch)
+	}
+}
+
+func TestSMSSendByOperatorRoutesCertifiedLines(t *testing.T) {
+	t.Parallel()
+
+	jobs := &stubJobEnqueuer{}
+	s := &SMSCampaignScheduler{
+		jobs:      jobs,
+		logger:    log.New(io.Discard, "", 0),
+		smsClient: &stubSMSClient{sendBatchErr: errors.New("provider rejected batch")},
+	}
+	lines := []models.LineNumber{
+		{LineNumber: "3000", CertifiedOperators: pq.StringArray{"mci"}},
+		{LineNumber: "3001", CertifiedOperators: pq.StringArray{"mtn"}},
+	}
+	items := []PayamSMSItem{
+		{Recipient: "09350000001", Body: "hi", TrackingID: "trk-1"},
+		{Recipient: "09120000002", Body: "hi", TrackingID: "trk-2"},
+		{Recipient: "09350000003", Body: "hi", TrackingID: "trk-3"},
+		{Recipient: "09210000004", Body: "hi", TrackingID: "trk-4"},
+	}
+	operators := make([]string, len(items))
+	for i, item := range items {
+		operators[i] = recipientOperator(item.Recipient)
+	}
+
+	updates, err := s.sendByOperator(context.Background(), 5, 9, lines, items, operators)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(updates) != 4 {
+		t.Fatalf("expected 4 updates, got %d", len(updates))
+	}
+	// mtn recipients go first, from the mtn line; rightel has no certified
+	// line and falls back to the campaign's own line
+	want := []struct {
+		sender string
+		ids    string
+	}{{"3001", "trk-1+trk-3"}, {"3000", "trk-2"}, {"3000", "trk-4"}}
+	if len(jobs.payloads) != len(want) {
+		t.Fatalf("expected %d batches, got %d", len(want), len(jobs.payloads))
+	}
+	for i, w := range want {
+		batch := jobs.payloads[i].(models.SendSMSBatchJob)
+		var ids []string
+		for _, item := range batch.Items {
+			ids = append(ids, item.TrackingID)
+		}
+		if batch.Sender != w.sender || strings.Join(ids, "+") != w.ids {
+			t.Fatalf("batch %d: expected %s from %s, got %v from %s", i, w.ids, w.sender, ids, batch.Sender)
+		}
+	}
+}
+
+func TestLineNumberCertifiedFor(t *testing.T) {
+	t.Parallel()
+
+	unrestricted := models.LineNumber{}
+	mci := models.LineNumber{CertifiedOperators: pq.StringArray{"mci"}}
+	if !unrestricted.CertifiedFor("mtn") || !mci.CertifiedFor("mci") || !mci.CertifiedFor("") {
+		t.Fatalf("expected uncertified lines and unknown operators to match")
+	}
+	if mci.CertifiedFor("rightel") {
+		t.Fatalf("expected mci-only line to reject rightel")
+	}
+}
+
 func TestBuildSMSProviderUpdateMissingResponse(t *testing.T) {
 	t.Parallel()
 
@@ -165,3 +369,96 @@ func TestBuildSMSProviderUpdateMissingResponse(t *testing.T) {
 		t.Fatalf("expected missing response description to include tracking id, got=%v", update.Description)
 	}
 }
+
+func TestPickContentVariantFollowsWeights(t *testing.T) {
+	t.Parallel()
+
+	variants := []dto.CampaignContentVariantSpec{
+		{Label: "A", Content: "a", Weight: 50},
+		{Label: "B", Content: "b", Weight: 30},
+		{Label: "C", Content: "c", Weight: 20},
+	}
+
+	const recipients = 20000
+	counts := make([]int, len(variants))
+	for i := range recipients {
+		phone := fmt.Sprintf("0912%07d", i)
+		idx := pickContentVariant(variants, phone)
+		if again := pickContentVariant(variants, phone); again != idx {
+			t.Fatalf("expected stable variant for %s, got %d then %d", phone, idx, again)
+		}
+		counts[idx]++
+	}
+
+	for i, v := range variants {
+		share := float64(counts[i]) * 100 / recipients
+		if diff := share - float64(v.Weight); diff < -2 || diff > 2 {
+			t.Fatalf("variant %s: expected about %d%%, got %.2f%%", v.Label, v.Weight, share)
+		}
+	}
+}
+
+type stubSandboxBotClient struct {
+	BotClient
+	calls []string
+	stats map[string]any
+}
+
+func (b *stubSandboxBotClient) MoveCampaignToRunning(ctx context.Context, token string, id uint) error {
+	b.calls = append(b.calls, "running")
+	return nil
+}
+
+func (b *stubSandboxBotClient) PushCampaignStatistics(ctx context.Context, id uint, stats map[string]any) error {
+	b.calls = append(b.calls, "statistics")
+	b.stats = stats
+	return nil
+}
+
+func (b *stubSandboxBotClient) MoveCampaignToExecuted(ctx context.Context, token string, id uint) error {
+	b.calls = append(b.calls, "executed")
+	return nil
+}
+
+func TestSMSProcessSandboxCampaignSendsNothing(t *testing.T) {
+	t.Parallel()
+
+	bot := &stubSandboxBotClient{}
+	s := &SMSCampaignScheduler{
+		botClient: bot,
+		logger:    log.New(io.Discard, "", 0),
+		smsClient: &stubSMSClient{sendBatchErr: errors.New("sandbox campaigns must not reach the provider")},
+	}
+	numAudiences := uint64(40)
+	c := dto.BotGetCampaignResponse{ID: 7, Sandbox: true, NumAudiences: &numAudiences}
+
+	if err := s.processSMSCampaign(context.Background(), "token", c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := strings.Join(bot.calls, ","); got != "running,statistics,executed" {
+		t.Fatalf("unexpected bot calls %s", got)
+	}
+	if bot.stats["aggregatedTotalSent"] != int64(40) || bot.stats["aggregatedTotalUnDeliveredParts"] != int64(0) || bot.stats["sandbox"] != true {
+		t.Fatalf("unexpected statistics %v", bot.stats)
+	}
+}
+
+func TestBlacklistFilterCountsFrequencyCappedSeparately(t *testing.T) {
+	t.Parallel()
+
+	f := &blacklistFilter{
+		phones: map[string]struct{}{"+989121111111": {}},
+		recent: map[string]struct{}{"+989121111111": {}, "+989122222222": {}},
+	}
+	for _, phone := range []string{"09121111111", " 09122222222", "09123333333"} {
+		f.skip(phone)
+	}
+	if f.Excluded() != 1 || f.Capped() != 1 {
+		t.Fatalf("expected 1 blacklisted and 1 capped, got %d and %d", f.Excluded(), f.Capped())
+	}
+
+	f.reset()
+	if f.Excluded() != 0 || f.Capped() != 0 {
+		t.Fatalf("expected counters reset, got %d and %d", f.Excluded(), f.Capped())
+	}
+}
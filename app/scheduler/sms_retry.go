@@ -0,0 +1,263 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/repository"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const (
+	smsRetryWorkerInterval = 30 * time.Second
+	smsRetriesPerTick      = 200
+	// sendBatchFailedCode marks messages of a batch the provider rejected as
+	// a whole; those are resent by send_sms_batch jobs, not per recipient
+	sendBatchFailedCode = "SEND_BATCH_FAILED"
+)
+
+// SMS recipient retries, by outcome
+var smsRecipientRetriesTotal = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "sms_recipient_retries_total",
+		Help: "SMS recipient retries, by outcome (scheduled, rescheduled, recovered, exhausted, permanent)",
+	},
+	[]string{"outcome"},
+)
+
+// SMSRetryPolicy decides which provider error codes of an SMS are resent and
+// which are permanent. Codes in neither list are left alone.
+type SMSRetryPolicy struct {
+	MaxAttempts    int
+	Delay          time.Duration
+	TransientCodes []string
+	PermanentCodes []string
+}
+
+type smsFailureClass int
+
+const (
+	smsFailureNone smsFailureClass = iota
+	smsFailureOther
+	smsFailureTransient
+	smsFailurePermanent
+)
+
+// classify tells what kind of failure a provider error code is; a nil or
+// blank code means the message was accepted
+func (p *SMSRetryPolicy) classify(code *string) smsFailureClass {
+	if code == nil || strings.TrimSpace(*code) == "" {
+		return smsFailureNone
+	}
+	c := strings.TrimSpace(*code)
+	for _, t := range p.TransientCodes {
+		if c == t {
+			return smsFailureTransient
+		}
+	}
+	for _, t := range p.PermanentCodes {
+		if c == t {
+			return smsFailurePermanent
+		}
+	}
+	return smsFailureOther
+}
+
+// SetRetryPolicy turns on recipient retries; call it before Start
+func (s *SMSCampaignScheduler) SetRetryPolicy(policy SMSRetryPolicy) {
+	s.retryPolicy = &policy
+}
+
+// handleSendFailures flags the updates of messages rejected with a permanent
+// error code and schedules the ones rejected with a transient code for a
+// resend from the line they were sent from
+func (s *SMSCampaignScheduler) handleSendFailures(ctx context.Context, campaignID, processedCampaignID uint, items []PayamSMSItem, updates []repository.SentSMSProviderUpdate) {
+	if s.retryPolicy == nil || s.retryRepo == nil {
+		return
+	}
+	itemByTrackingID := make(map[string]PayamSMSItem, len(items))
+	for _, item := range items {
+		itemByTrackingID[strings.TrimSpace(item.TrackingID)] = item
+	}
+
+	now := utils.UTCNow()
+	var retries []*models.SMSRecipientRetry
+	for i := range updates {
+		u := &updates[i]
+		if u.ErrorCode != nil && *u.ErrorCode == sendBatchFailedCode {
+			continue
+		}
+		switch s.retryPolicy.classify(u.ErrorCode) {
+		case smsFailurePermanent:
+			u.PermanentError = true
+		case smsFailureTransient:
+			item, ok := itemByTrackingID[u.TrackingID]
+			if !ok || u.Sender == nil {
+				continue
+			}
+			retries = append(retries, &models.SMSRecipientRetry{
+				CampaignID:          campaignID,
+				ProcessedCampaignID: processedCampaignID,
+				TrackingID:          u.TrackingID,
+				Recipient:           item.Recipient,
+				Sender:              *u.Sender,
+				Body:                item.Body,
+				Parts:               item.Parts,
+				Status:              models.SMSRetryStatusPending,
+				MaxAttempts:         s.retryPolicy.MaxAttempts,
+				NextAttemptAt:       now.Add(s.retryPolicy.Delay),
+				LastErrorCode:       u.ErrorCode,
+				CreatedAt:           now,
+				UpdatedAt:           now,
+			})
+		}
+	}
+	if len(retries) == 0 {
+		return
+	}
+	if err := s.retryRepo.Schedule(ctx, retries); err != nil {
+		s.logger.Printf("SMS scheduler: schedule %d recipient retries for campaign id=%d failed: %v", len(retries), campaignID, err)
+		return
+	}
+	smsRecipientRetriesTotal.WithLabelValues("scheduled").Add(float64(len(retries)))
+	s.logger.Printf("SMS scheduler: campaign id=%d scheduled %d recipients for retry", campaignID, len(retries))
+}
+
+func (s *SMSCampaignScheduler) startRetryWorker(parent context.Context) {
+	ticker := time.NewTicker(smsRetryWorkerInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-parent.Done():
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(parent, 5*time.Minute)
+			if err := s.runRetries(ctx); err != nil {
+				s.logger.Printf("SMS scheduler: recipient retries failed: %v", err)
+			}
+			cancel()
+		}
+	}
+}
+
+// runRetries ends the retries of cancelled campaigns and resends the due
+// ones, one provider request per sender line
+func (s *SMSCampaignScheduler) runRetries(ctx context.Context) error {
+	now := utils.UTCNow()
+	cancelled, err := s.retryRepo.ExhaustCancelled(ctx, now)
+	if err != nil {
+		return fmt.Errorf("end retries of cancelled campaigns: %w", err)
+	}
+	if cancelled > 0 {
+		smsRecipientRetriesTotal.WithLabelValues("exhausted").Add(float64(cancelled))
+	}
+
+	due, err := s.retryRepo.ListDue(ctx, now, smsRetriesPerTick)
+	if err != nil {
+		return fmt.Errorf("list due retries: %w", err)
+	}
+
+	bySender := make(map[string][]*models.SMSRecipientRetry)
+	var senders []string
+	for _, r := range due {
+		if _, seen := bySender[r.Sender]; !seen {
+			senders = append(senders, r.Sender)
+		}
+		bySender[r.Sender] = append(bySender[r.Sender], r)
+	}
+	for _, sender := range senders {
+		if err := s.resendRetries(ctx, sender, bySender[sender]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resendRetries resends retries sharing a sender line under their original
+// tracking IDs and records the outcome of the attempt on each
+func (s *SMSCampaignScheduler) resendRetries(ctx context.Context, sender string, retries []*models.SMSRecipientRetry) error {
+	lines := []string{sender}
+	for granted := 0; granted < len(retries); {
+		_, n, err := s.lineLimiter.acquire(ctx, lines, len(retries)-granted)
+		if err != nil {
+			return fmt.Errorf("wait for sender line %s capacity: %w", sender, err)
+		}
+		granted += n
+	}
+
+	items := make([]PayamSMSItem, 0, len(retries))
+	for _, r := range retries {
+		items = append(items, PayamSMSItem{Recipient: r.Recipient, Body: r.Body, TrackingID: r.TrackingID, Parts: r.Parts})
+	}
+	responses, sendErr := s.smsClient.SendBatch(ctx, sender, items)
+	if sendErr != nil {
+		s.logger.Printf("SMS scheduler: resend %d retried messages from %s failed: %v", len(items), sender, sendErr)
+	}
+	responseByTrackingID := make(map[string]*PayamSMSResponseItem, len(responses))
+	for i := range responses {
+		responseByTrackingID[strings.TrimSpace(responses[i].TrackingID)] = &responses[i]
+	}
+
+	now := utils.UTCNow()
+	updates := make([]repository.SentSMSProviderUpdate, 0, len(retries))
+	recovered := make(map[uint][]string)
+	for _, r := range retries {
+		update := buildSMSProviderUpdate(r.TrackingID, responseByTrackingID[r.TrackingID], sendErr)
+		update.Sender = utils.ToPtr(sender)
+		outcome := s.settleRetry(r, &update, sendErr != nil, now)
+		updates = append(updates, update)
+		smsRecipientRetriesTotal.WithLabelValues(outcome).Inc()
+		if r.Status == models.SMSRetryStatusRecovered {
+			recovered[r.ProcessedCampaignID] = append(recovered[r.ProcessedCampaignID], r.TrackingID)
+		}
+		if err := s.retryRepo.Update(ctx, r); err != nil {
+			s.logger.Printf("SMS scheduler: save retry of tracking_id=%s failed: %v", r.TrackingID, err)
+		}
+	}
+	// The attempt was made; a failed bookkeeping update must not resend it
+	if err := s.sentRepo.UpdateProviderFieldsByTrackingIDs(ctx, updates); err != nil {
+		s.logger.Printf("SMS scheduler: failed to update sent_sms provider fields of %d retried messages: %v", len(updates), err)
+	}
+	for pcID, trackingIDs := range recovered {
+		if err := s.scheduleStatusCheckJobs(ctx, pcID, trackingIDs); err != nil {
+			s.logger.Printf("SMS scheduler: failed to schedule status jobs of retried messages for processed campaign id=%d: %v", pcID, err)
+		}
+	}
+	return nil
+}
+
+// settleRetry records one attempt on a retry and returns its outcome. A
+// failed request counts as a transient failure, and a code in neither list
+// ends the retry as exhausted.
+func (s *SMSCampaignScheduler) settleRetry(r *models.SMSRecipientRetry, update *repository.SentSMSProviderUpdate, requestFailed bool, now time.Time) string {
+	r.Attempts++
+	r.LastErrorCode = update.ErrorCode
+	r.UpdatedAt = now
+
+	class := s.retryPolicy.classify(update.ErrorCode)
+	if requestFailed {
+		class = smsFailureTransient
+	}
+	switch class {
+	case smsFailureNone:
+		r.Status = models.SMSRetryStatusRecovered
+		return "recovered"
+	case smsFailurePermanent:
+		r.Status = models.SMSRetryStatusPermanent
+		update.PermanentError = true
+		return "permanent"
+	case smsFailureTransient:
+		if r.Attempts < r.MaxAttempts {
+			r.NextAttemptAt = now.Add(s.retryPolicy.Delay)
+			return "rescheduled"
+		}
+	}
+	r.Status = models.SMSRetryStatusExhausted
+	return "exhausted"
+}
@@ -0,0 +1,142 @@
+package scheduler
+
+import (
+	"context"
+	"io"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/repository"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+)
+
+type stubSMSRetryRepo struct {
+	repository.SMSRecipientRetryRepository
+	scheduled []*models.SMSRecipientRetry
+	updated   []*models.SMSRecipientRetry
+}
+
+func (r *stubSMSRetryRepo) Schedule(_ context.Context, retries []*models.SMSRecipientRetry) error {
+	r.scheduled = append(r.scheduled, retries...)
+	return nil
+}
+
+func (r *stubSMSRetryRepo) Update(_ context.Context, retry *models.SMSRecipientRetry) error {
+	r.updated = append(r.updated, retry)
+	return nil
+}
+
+type stubSMSProviderUpdater struct {
+	repository.SentSMSRepository
+	updates []repository.SentSMSProviderUpdate
+}
+
+func (r *stubSMSProviderUpdater) UpdateProviderFieldsByTrackingIDs(_ context.Context, updates []repository.SentSMSProviderUpdate) error {
+	r.updates = append(r.updates, updates...)
+	return nil
+}
+
+func testSMSRetryPolicy() *SMSRetryPolicy {
+	return &SMSRetryPolicy{MaxAttempts: 2, Delay: time.Minute, TransientCodes: []string{"T1"}, PermanentCodes: []string{"P1"}}
+}
+
+func TestSMSHandleSendFailuresSchedulesTransientAndFlagsPermanent(t *testing.T) {
+	t.Parallel()
+
+	retries := &stubSMSRetryRepo{}
+	s := &SMSCampaignScheduler{logger: log.New(io.Discard, "", 0), retryRepo: retries, retryPolicy: testSMSRetryPolicy()}
+	items := []PayamSMSItem{
+		{Recipient: "09120000001", Body: "hi 1", TrackingID: "trk-1", Parts: 1},
+		{Recipient: "09120000002", Body: "hi 2", TrackingID: "trk-2", Parts: 1},
+		{Recipient: "09120000003", Body: "hi 3", TrackingID: "trk-3", Parts: 1},
+		{Recipient: "09120000004", Body: "hi 4", TrackingID: "trk-4", Parts: 1},
+	}
+	updates := []repository.SentSMSProviderUpdate{
+		{TrackingID: "trk-1", ErrorCode: utils.ToPtr("T1"), Sender: utils.ToPtr("3000")},
+		{TrackingID: "trk-2", ErrorCode: utils.ToPtr("P1"), Sender: utils.ToPtr("3000")},
+		{TrackingID: "trk-3", ErrorCode: utils.ToPtr("X9"), Sender: utils.ToPtr("3000")},
+		{TrackingID: "trk-4", Sender: utils.ToPtr("3000")},
+	}
+
+	s.handleSendFailures(context.Background(), 5, 9, items, updates)
+
+	if len(retries.scheduled) != 1 {
+		t.Fatalf("expected one retry, got %d", len(retries.scheduled))
+	}
+	r := retries.scheduled[0]
+	if r.TrackingID != "trk-1" || r.Body != "hi 1" || r.Sender != "3000" || r.CampaignID != 5 || r.ProcessedCampaignID != 9 || r.MaxAttempts != 2 {
+		t.Fatalf("unexpected retry %+v", r)
+	}
+	if !updates[1].PermanentError || updates[0].PermanentError || updates[2].PermanentError || updates[3].PermanentError {
+		t.Fatalf("expected only the permanent error flagged, got %+v", updates)
+	}
+}
+
+func TestSMSHandleSendFailuresLeavesRejectedBatchesToBatchJobs(t *testing.T) {
+	t.Parallel()
+
+	retries := &stubSMSRetryRepo{}
+	policy := testSMSRetryPolicy()
+	policy.TransientCodes = append(policy.TransientCodes, sendBatchFailedCode)
+	s := &SMSCampaignScheduler{logger: log.New(io.Discard, "", 0), retryRepo: retries, retryPolicy: policy}
+	items := []PayamSMSItem{{Recipient: "09120000001", Body: "hi", TrackingID: "trk-1"}}
+	updates := []repository.SentSMSProviderUpdate{{TrackingID: "trk-1", ErrorCode: utils.ToPtr(sendBatchFailedCode), Sender: utils.ToPtr("3000")}}
+
+	s.handleSendFailures(context.Background(), 5, 9, items, updates)
+
+	if len(retries.scheduled) != 0 {
+		t.Fatalf("expected no recipient retry for a rejected batch, got %d", len(retries.scheduled))
+	}
+}
+
+func TestSMSResendRetriesSettlesOutcomes(t *testing.T) {
+	t.Parallel()
+
+	retries := &stubSMSRetryRepo{}
+	sent := &stubSMSProviderUpdater{}
+	s := &SMSCampaignScheduler{
+		logger:      log.New(io.Discard, "", 0),
+		sentRepo:    sent,
+		retryRepo:   retries,
+		retryPolicy: testSMSRetryPolicy(),
+		smsClient: &stubSMSClient{sendBatchResponses: []PayamSMSResponseItem{
+			{TrackingID: "ok", ServerID: utils.ToPtr("srv-1")},
+			{TrackingID: "again", ErrorCode: utils.ToPtr("T1")},
+			{TrackingID: "last", ErrorCode: utils.ToPtr("T1")},
+			{TrackingID: "perm", ErrorCode: utils.ToPtr("P1")},
+		}},
+	}
+	due := []*models.SMSRecipientRetry{
+		{TrackingID: "ok", Status: models.SMSRetryStatusPending, MaxAttempts: 2},
+		{TrackingID: "again", Status: models.SMSRetryStatusPending, MaxAttempts: 2},
+		{TrackingID: "last", Status: models.SMSRetryStatusPending, Attempts: 1, MaxAttempts: 2},
+		{TrackingID: "perm", Status: models.SMSRetryStatusPending, MaxAttempts: 2},
+	}
+
+	if err := s.resendRetries(context.Background(), "3000", due); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]models.SMSRetryStatus{
+		"ok":    models.SMSRetryStatusRecovered,
+		"again": models.SMSRetryStatusPending,
+		"last":  models.SMSRetryStatusExhausted,
+		"perm":  models.SMSRetryStatusPermanent,
+	}
+	for _, r := range due {
+		if r.Status != want[r.TrackingID] {
+			t.Fatalf("%s: expected %s, got %s", r.TrackingID, want[r.TrackingID], r.Status)
+		}
+	}
+	if due[1].Attempts != 1 || !due[1].NextAttemptAt.After(time.Now()) {
+		t.Fatalf("expected the transient failure rescheduled, got %+v", due[1])
+	}
+	if len(retries.updated) != 4 || len(sent.updates) != 4 {
+		t.Fatalf("expected every retry and sent row updated, got %d and %d", len(retries.updated), len(sent.updates))
+	}
+	if !sent.updates[3].PermanentError || sent.updates[0].PermanentError {
+		t.Fatalf("expected only the permanent error flagged, got %+v", sent.updates)
+	}
+}
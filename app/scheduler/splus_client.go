@@ -97,7 +97,7 @@ type httpSplusClient struct {
 }
 
 func newHTTPSplusClient(cfg config.SplusConfig) *httpSplusClient {
-	return newHTTPSplusClientWithClient(cfg, newHTTPClient(60*time.Second))
+	return newHTTPSplusClientWithClient(cfg, newHTTPClient("splus", 60*time.Second))
 }
 
 func newHTTPSplusClientWithClient(cfg config.SplusConfig, client *http.Client) *httpSplusClient {
@@ -107,7 +107,7 @@ func newHTTPSplusClientWithClient(cfg config.SplusConfig, client *http.Client) *
 	}
 	cfg.BaseURL = strings.TrimRight(baseURL, "/")
 	if client == nil {
-		client = newHTTPClient(60 * time.Second)
+		client = newHTTPClient("splus", 60*time.Second)
 	}
 
 	return &httpSplusClient{
@@ -0,0 +1,156 @@
+package scheduler
+
+import (
+	"strings"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const (
+	// statusJobBackoffBase is the delay before a failed status job is tried
+	// again; it doubles with every further failure up to statusJobBackoffMax
+	statusJobBackoffBase = 2 * time.Minute
+	statusJobBackoffMax  = time.Hour
+)
+
+// statusCheckOffsets are the delays after a batch is sent at which its
+// delivery status is checked
+var statusCheckOffsets = []time.Duration{1 * time.Minute, 5 * time.Minute, 15 * time.Minute, 24 * time.Hour, 48 * time.Hour}
+
+var (
+	campaignStatusJobLag = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "campaign_status_job_lag_seconds",
+			Help:    "Delay between a campaign status job becoming due and a worker running it, by platform",
+			Buckets: []float64{1, 5, 15, 30, 60, 120, 300, 600, 1800, 3600},
+		},
+		[]string{"platform"},
+	)
+	campaignStatusJobCompletion = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "campaign_status_job_completion_seconds",
+			Help:    "Time from a campaign status job's creation until it succeeded or failed, by platform and status",
+			Buckets: []float64{60, 300, 900, 1800, 3600, 6 * 3600, 24 * 3600, 48 * 3600, 72 * 3600},
+		},
+		[]string{"platform", "status"},
+	)
+	campaignStatusJobOutcomes = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "campaign_status_job_outcomes_total",
+			Help: "Campaign status job outcomes, by platform and outcome (succeeded, retried, failed, skipped)",
+		},
+		[]string{"platform", "outcome"},
+	)
+)
+
+// newStatusCheckJobs builds the status checks of one sent batch, one per
+// offset, sharing a correlation ID. It returns nil when no tracking ID is left
+// after trimming.
+func newStatusCheckJobs(platform string, processedCampaignID uint, trackingIDs []string, now time.Time) []*models.CampaignStatusJob {
+	filtered := make([]string, 0, len(trackingIDs))
+	for _, id := range trackingIDs {
+		if id = strings.TrimSpace(id); id != "" {
+			filtered = append(filtered, id)
+		}
+	}
+	if len(filtered) == 0 {
+		return nil
+	}
+
+	corrID := uuid.NewString()
+	jobs := make([]*models.CampaignStatusJob, 0, len(statusCheckOffsets))
+	for _, off := range statusCheckOffsets {
+		jobs = append(jobs, &models.CampaignStatusJob{
+			ProcessedCampaignID: processedCampaignID,
+			CorrelationID:       corrID,
+			Platform:            platform,
+			TrackingIDs:         pq.StringArray(filtered),
+			Status:              models.CampaignStatusJobPending,
+			MaxAttempts:         statusJobMaxRetry,
+			ScheduledAt:         now.Add(off),
+			NextRunAt:           now.Add(off),
+			CreatedAt:           now,
+			UpdatedAt:           now,
+		})
+	}
+	return jobs
+}
+
+// statusJobBackoff returns the delay before the next attempt of a job that
+// has failed retryCount times
+func statusJobBackoff(retryCount int) time.Duration {
+	d := statusJobBackoffBase
+	for i := 1; i < retryCount && d < statusJobBackoffMax; i++ {
+		d *= 2
+	}
+	return min(d, statusJobBackoffMax)
+}
+
+// statusJobMaxAttempts returns the job's attempt limit, statusJobMaxRetry for
+// jobs created without one
+func statusJobMaxAttempts(job *models.CampaignStatusJob) int {
+	if job.MaxAttempts > 0 {
+		return job.MaxAttempts
+	}
+	return statusJobMaxRetry
+}
+
+// observeStatusJobLag records how long a job waited past its next run time
+func observeStatusJobLag(job *models.CampaignStatusJob, now time.Time) {
+	due := job.NextRunAt
+	if due.IsZero() {
+		due = job.ScheduledAt
+	}
+	campaignStatusJobLag.WithLabelValues(job.Platform).Observe(max(now.Sub(due), 0).Seconds())
+}
+
+// recordStatusJobFailure records a failed attempt. The job stays pending with
+// its next run pushed back, or fails once it used up its attempts.
+func recordStatusJobFailure(job *models.CampaignStatusJob, attemptErr error, now time.Time) {
+	job.RetryCount++
+	msg := attemptErr.Error()
+	job.Error = &msg
+	job.UpdatedAt = now
+	if job.RetryCount >= statusJobMaxAttempts(job) {
+		job.Status = models.CampaignStatusJobFailed
+		job.ExecutedAt = &now
+		return
+	}
+	job.Status = models.CampaignStatusJobPending
+	job.ExecutedAt = nil
+	job.NextRunAt = now.Add(statusJobBackoff(job.RetryCount))
+}
+
+// recordStatusJobSuccess marks the job succeeded
+func recordStatusJobSuccess(job *models.CampaignStatusJob, now time.Time) {
+	job.Status = models.CampaignStatusJobSucceeded
+	job.ExecutedAt = &now
+	job.Error = nil
+	job.UpdatedAt = now
+}
+
+// observeStatusJobOutcome counts an attempt whose job state was saved, and
+// the completion time of jobs it finished
+func observeStatusJobOutcome(job *models.CampaignStatusJob) {
+	if job.Status == models.CampaignStatusJobPending {
+		campaignStatusJobOutcomes.WithLabelValues(job.Platform, "retried").Inc()
+		return
+	}
+	campaignStatusJobOutcomes.WithLabelValues(job.Platform, job.Status).Inc()
+	if job.ExecutedAt != nil {
+		campaignStatusJobCompletion.WithLabelValues(job.Platform, job.Status).Observe(job.ExecutedAt.Sub(job.CreatedAt).Seconds())
+	}
+}
+
+// recordStatusJobsSkipped counts later checks dropped because their batch
+// reached final delivery statuses
+func recordStatusJobsSkipped(platform string, n int64) {
+	if n > 0 {
+		campaignStatusJobOutcomes.WithLabelValues(platform, "skipped").Add(float64(n))
+	}
+}
@@ -0,0 +1,80 @@
+package scheduler
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/models"
+)
+
+func TestStatusJobBackoff(t *testing.T) {
+	t.Parallel()
+
+	cases := map[int]time.Duration{
+		1:  2 * time.Minute,
+		2:  4 * time.Minute,
+		3:  8 * time.Minute,
+		6:  time.Hour,
+		50: time.Hour,
+	}
+	for retryCount, want := range cases {
+		if got := statusJobBackoff(retryCount); got != want {
+			t.Fatalf("statusJobBackoff(%d) = %v, want %v", retryCount, got, want)
+		}
+	}
+}
+
+func TestRecordStatusJobFailure(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	job := &models.CampaignStatusJob{Status: models.CampaignStatusJobPending, MaxAttempts: 2}
+
+	recordStatusJobFailure(job, errors.New("provider down"), now)
+	if job.Status != models.CampaignStatusJobPending || job.ExecutedAt != nil {
+		t.Fatalf("expected job to stay pending after first failure, got status=%s executed_at=%v", job.Status, job.ExecutedAt)
+	}
+	if !job.NextRunAt.Equal(now.Add(statusJobBackoffBase)) {
+		t.Fatalf("expected next run after backoff, got %v", job.NextRunAt)
+	}
+	if job.Error == nil || *job.Error != "provider down" {
+		t.Fatalf("expected error to be recorded, got %v", job.Error)
+	}
+
+	recordStatusJobFailure(job, errors.New("provider down"), now)
+	if job.Status != models.CampaignStatusJobFailed || job.ExecutedAt == nil {
+		t.Fatalf("expected job to fail at max attempts, got status=%s executed_at=%v", job.Status, job.ExecutedAt)
+	}
+	if job.RetryCount != 2 {
+		t.Fatalf("expected retry_count=2, got=%d", job.RetryCount)
+	}
+}
+
+func TestNewStatusCheckJobs(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	if jobs := newStatusCheckJobs(models.CampaignPlatformSMS, 7, []string{" ", ""}, now); jobs != nil {
+		t.Fatalf("expected no jobs without tracking ids, got %d", len(jobs))
+	}
+
+	jobs := newStatusCheckJobs(models.CampaignPlatformSMS, 7, []string{" trk-1 ", "", "trk-2"}, now)
+	if len(jobs) != len(statusCheckOffsets) {
+		t.Fatalf("expected %d jobs, got %d", len(statusCheckOffsets), len(jobs))
+	}
+	for i, job := range jobs {
+		if job.CorrelationID == "" || job.CorrelationID != jobs[0].CorrelationID {
+			t.Fatalf("expected shared correlation id, got %q", job.CorrelationID)
+		}
+		if len(job.TrackingIDs) != 2 || job.TrackingIDs[0] != "trk-1" {
+			t.Fatalf("expected trimmed tracking ids, got %v", job.TrackingIDs)
+		}
+		if job.Status != models.CampaignStatusJobPending || job.MaxAttempts != statusJobMaxRetry {
+			t.Fatalf("unexpected job state status=%s max_attempts=%d", job.Status, job.MaxAttempts)
+		}
+		if !job.NextRunAt.Equal(now.Add(statusCheckOffsets[i])) || !job.ScheduledAt.Equal(job.NextRunAt) {
+			t.Fatalf("unexpected schedule scheduled_at=%v next_run_at=%v", job.ScheduledAt, job.NextRunAt)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/storage"
+)
+
+// LifecycleApplier expires stored files by key prefix
+type LifecycleApplier interface {
+	ApplyLifecycle(ctx context.Context, rules []storage.Rule) error
+}
+
+// StorageLifecycleScheduler periodically applies the storage lifecycle
+// rules. On S3 this re-submits the bucket lifecycle configuration, which S3
+// then enforces itself; on local disk it deletes the expired files.
+type StorageLifecycleScheduler struct {
+	store        LifecycleApplier
+	rules        []storage.Rule
+	logger       *log.Logger
+	pollInterval time.Duration
+}
+
+func NewStorageLifecycleScheduler(
+	store LifecycleApplier,
+	rules []storage.Rule,
+	logger *log.Logger,
+	pollInterval time.Duration,
+) *StorageLifecycleScheduler {
+	if pollInterval <= 0 {
+		pollInterval = 24 * time.Hour
+	}
+	if logger == nil {
+		logger = log.Default()
+	}
+	return &StorageLifecycleScheduler{
+		store:        store,
+		rules:        rules,
+		logger:       logger,
+		pollInterval: pollInterval,
+	}
+}
+
+func (s *StorageLifecycleScheduler) Start(parent context.Context) func() {
+	workerCtx, cancel := context.WithCancel(parent)
+	var wg sync.WaitGroup
+	var stopOnce sync.Once
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(s.pollInterval)
+		defer ticker.Stop()
+
+		s.runOnce(workerCtx)
+		for {
+			select {
+			case <-workerCtx.Done():
+				return
+			case <-ticker.C:
+				s.runOnce(workerCtx)
+			}
+		}
+	}()
+	return func() {
+		stopOnce.Do(func() {
+			cancel()
+			wg.Wait()
+		})
+	}
+}
+
+func (s *StorageLifecycleScheduler) runOnce(parent context.Context) {
+	ctx, cancel := context.WithTimeout(parent, 30*time.Minute)
+	defer cancel()
+	if err := s.store.ApplyLifecycle(ctx, s.rules); err != nil {
+		s.logger.Printf("storage lifecycle scheduler: %v", err)
+	}
+}
@@ -0,0 +1,48 @@
+package scheduler
+
+import (
+	"sync"
+	"time"
+)
+
+// campaignTiming holds how often a campaign scheduler ticks and how long it
+// waits between send batches; both can be changed while it runs
+type campaignTiming struct {
+	mu       sync.Mutex
+	interval time.Duration
+	delay    time.Duration
+	changed  chan struct{}
+}
+
+func newCampaignTiming(interval, delay time.Duration) *campaignTiming {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	return &campaignTiming{interval: interval, delay: delay, changed: make(chan struct{})}
+}
+
+// SetTiming changes the tick interval and the delay between send batches; the
+// next tick is rescheduled from now. A non-positive interval is ignored.
+func (t *campaignTiming) SetTiming(interval, delay time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if interval > 0 {
+		t.interval = interval
+	}
+	t.delay = delay
+	close(t.changed)
+	t.changed = make(chan struct{})
+}
+
+// tick returns the current interval and a channel closed when it changes
+func (t *campaignTiming) tick() (time.Duration, <-chan struct{}) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.interval, t.changed
+}
+
+func (t *campaignTiming) messageDelay() time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.delay
+}
@@ -0,0 +1,36 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCampaignTimingSetTiming(t *testing.T) {
+	timing := newCampaignTiming(time.Minute, time.Second)
+	interval, changed := timing.tick()
+	if interval != time.Minute {
+		t.Fatalf("interval = %v, want 1m", interval)
+	}
+
+	timing.SetTiming(5*time.Minute, 2*time.Second)
+	select {
+	case <-changed:
+	default:
+		t.Fatal("changed channel was not closed")
+	}
+	if interval, _ := timing.tick(); interval != 5*time.Minute {
+		t.Errorf("interval = %v, want 5m", interval)
+	}
+	if delay := timing.messageDelay(); delay != 2*time.Second {
+		t.Errorf("delay = %v, want 2s", delay)
+	}
+
+	// A non-positive interval keeps the current one
+	timing.SetTiming(0, 0)
+	if interval, _ := timing.tick(); interval != 5*time.Minute {
+		t.Errorf("interval = %v, want 5m to be kept", interval)
+	}
+	if delay := timing.messageDelay(); delay != 0 {
+		t.Errorf("delay = %v, want 0", delay)
+	}
+}
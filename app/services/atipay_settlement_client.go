@@ -0,0 +1,65 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/httpclient"
+)
+
+// maxAtipaySettlementReportBytes bounds the size of a downloaded report
+const maxAtipaySettlementReportBytes = 32 << 20
+
+// AtipaySettlementReportFetcher downloads Atipay's settlement report of a day
+type AtipaySettlementReportFetcher interface {
+	FetchSettlementReport(ctx context.Context, reportDate time.Time) ([]byte, error)
+}
+
+// AtipaySettlementClient downloads daily settlement reports as CSV. The
+// {date} placeholder of URLTemplate is replaced by the report date as
+// YYYY-MM-DD; the API key is sent in the apiKey header.
+type AtipaySettlementClient struct {
+	URLTemplate string
+	APIKey      string
+	HTTPClient  *http.Client
+}
+
+func NewAtipaySettlementClient(urlTemplate, apiKey string, timeout time.Duration) *AtipaySettlementClient {
+	return &AtipaySettlementClient{
+		URLTemplate: urlTemplate,
+		APIKey:      apiKey,
+		HTTPClient:  httpclient.New("atipay", timeout),
+	}
+}
+
+func (c *AtipaySettlementClient) FetchSettlementReport(ctx context.Context, reportDate time.Time) ([]byte, error) {
+	url := strings.ReplaceAll(c.URLTemplate, "{date}", reportDate.Format("2006-01-02"))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/csv")
+	if c.APIKey != "" {
+		req.Header.Set("apiKey", c.APIKey)
+	}
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("atipay settlement report: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("atipay settlement report: http %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxAtipaySettlementReportBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("atipay settlement report: %w", err)
+	}
+	if len(body) > maxAtipaySettlementReportBytes {
+		return nil, fmt.Errorf("atipay settlement report: larger than %d bytes", maxAtipaySettlementReportBytes)
+	}
+	return body, nil
+}
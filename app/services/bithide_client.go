@@ -9,6 +9,8 @@ import (
 	"net/http"
 	"strings"
 	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/httpclient"
 )
 
 // BithideClient implements CryptoPaymentProvider
@@ -29,7 +31,7 @@ func NewBithideClient(baseURL, apiKey string, timeout time.Duration, defaultConf
 	return &BithideClient{
 		BaseURL:              strings.TrimRight(baseURL, "/"),
 		APIKey:               apiKey,
-		HTTPClient:           &http.Client{Timeout: timeout},
+		HTTPClient:           httpclient.New("bithide", timeout),
 		DefaultConfirmations: defaultConfirmations,
 	}
 }
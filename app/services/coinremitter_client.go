@@ -10,6 +10,8 @@ import (
 	"net/http"
 	"strings"
 	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/httpclient"
 )
 
 type CoinremitterWalletConfig struct {
@@ -30,7 +32,7 @@ func NewCoinremitterClient(baseURL string, timeout time.Duration, wallets map[st
 	}
 	return &CoinremitterClient{
 		BaseURL:    strings.TrimRight(baseURL, "/"),
-		HTTPClient: &http.Client{Timeout: timeout},
+		HTTPClient: httpclient.New("coinremitter", timeout),
 		Timeout:    timeout,
 		Wallets:    wallets,
 	}
@@ -29,10 +29,14 @@ type ProvisionResult struct {
 	DepositMemo       string
 	ProviderRequestID string
 	ExpiresAt         *time.Time
+	// ExpectedCoinAmount is set when the provider fixes the amount to pay,
+	// which then replaces the quoted amount
+	ExpectedCoinAmount string
 }
 
 type DepositInfo struct {
 	TxHash                string
+	Coin                  string // empty when paid in the requested coin
 	AmountCoin            string
 	Confirmations         int
 	RequiredConfirmations int
@@ -51,3 +55,9 @@ type CryptoPaymentProvider interface {
 	GetDeposits(ctx context.Context, providerRequestID string) ([]DepositInfo, error)
 	VerifyTx(ctx context.Context, txHash string) (*DepositInfo, error)
 }
+
+// DepositReleaser is implemented by providers whose deposit addresses keep
+// accepting payments until they are released
+type DepositReleaser interface {
+	ReleaseDeposit(ctx context.Context, address, providerRequestID string) error
+}
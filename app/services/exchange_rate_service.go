@@ -0,0 +1,292 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/httpclient"
+	"github.com/amirphl/Yamata-no-Orochi/config"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	// ErrExchangeRateUnavailable is returned when fewer sources than
+	// required could quote a coin
+	ErrExchangeRateUnavailable = errors.New("exchange rate unavailable")
+	// ErrExchangeRatesDiverged is returned when the sources disagree by more
+	// than the configured threshold
+	ErrExchangeRatesDiverged = errors.New("exchange rate sources diverged")
+)
+
+// ExchangeRate is the toman price of a coin agreed by several sources
+type ExchangeRate struct {
+	Coin         string             `json:"coin"`
+	TomanPerCoin float64            `json:"toman_per_coin"`
+	Source       string             `json:"source"` // names of the sources, comma separated
+	Sources      map[string]float64 `json:"sources"`
+	FetchedAt    time.Time          `json:"fetched_at"`
+}
+
+// ExchangeRateSource quotes the toman price of a coin
+type ExchangeRateSource interface {
+	Name() string
+	TomanPerCoin(ctx context.Context, coin string) (float64, error)
+}
+
+// ExchangeRateService gives the toman price of a coin from every configured
+// source, cached in Redis for the configured TTL. A rate is only given when
+// enough sources answer and they agree within the divergence threshold.
+type ExchangeRateService interface {
+	Rate(ctx context.Context, coin string) (*ExchangeRate, error)
+}
+
+type exchangeRateService struct {
+	rc      *redis.Client
+	sources []ExchangeRateSource
+	cfg     config.ExchangeRateConfig
+}
+
+// NewExchangeRateService creates an exchange rate service; with a nil Redis
+// client every call asks the sources
+func NewExchangeRateService(rc *redis.Client, sources []ExchangeRateSource, cfg config.ExchangeRateConfig) ExchangeRateService {
+	return &exchangeRateService{rc: rc, sources: sources, cfg: cfg}
+}
+
+// NewExchangeRateSources creates the sources named in CRYPTO_RATE_SOURCES
+func NewExchangeRateSources(names []string, timeout time.Duration) ([]ExchangeRateSource, error) {
+	sources := make([]ExchangeRateSource, 0, len(names))
+	for _, name := range names {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "wallex":
+			sources = append(sources, NewWallexRateSource(timeout))
+		case "nobitex":
+			sources = append(sources, NewNobitexRateSource(timeout))
+		default:
+			return nil, fmt.Errorf("unknown exchange rate source %q", name)
+		}
+	}
+	return sources, nil
+}
+
+func (s *exchangeRateService) Rate(ctx context.Context, coin string) (*ExchangeRate, error) {
+	coin = strings.ToUpper(strings.TrimSpace(coin))
+	if rate := s.cached(ctx, coin); rate != nil {
+		return rate, nil
+	}
+
+	quotes := make(map[string]float64, len(s.sources))
+	var errs []error
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, src := range s.sources {
+		wg.Add(1)
+		go func(src ExchangeRateSource) {
+			defer wg.Done()
+			qctx, cancel := context.WithTimeout(ctx, s.cfg.Timeout)
+			defer cancel()
+			price, err := src.TomanPerCoin(qctx, coin)
+			if err == nil && price <= 0 {
+				err = fmt.Errorf("non-positive price %v", price)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", src.Name(), err))
+				return
+			}
+			quotes[src.Name()] = price
+		}(src)
+	}
+	wg.Wait()
+
+	rate, err := aggregateExchangeRate(coin, quotes, s.cfg.MinSources, s.cfg.MaxDivergenceBPS)
+	if err != nil {
+		if len(errs) > 0 {
+			err = fmt.Errorf("%w (%w)", err, errors.Join(errs...))
+		}
+		return nil, err
+	}
+	s.store(ctx, rate)
+	return rate, nil
+}
+
+func (s *exchangeRateService) cached(ctx context.Context, coin string) *ExchangeRate {
+	if s.rc == nil || s.cfg.CacheTTL <= 0 {
+		return nil
+	}
+	raw, err := s.rc.Get(ctx, exchangeRateKey(coin)).Bytes()
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			log.Printf("exchange rate cache read for %s failed: %v", coin, err)
+		}
+		return nil
+	}
+	var rate ExchangeRate
+	if err := json.Unmarshal(raw, &rate); err != nil {
+		return nil
+	}
+	return &rate
+}
+
+func (s *exchangeRateService) store(ctx context.Context, rate *ExchangeRate) {
+	if s.rc == nil || s.cfg.CacheTTL <= 0 {
+		return
+	}
+	b, _ := json.Marshal(rate)
+	if err := s.rc.Set(ctx, exchangeRateKey(rate.Coin), b, s.cfg.CacheTTL).Err(); err != nil {
+		log.Printf("exchange rate cache write for %s failed: %v", rate.Coin, err)
+	}
+}
+
+func exchangeRateKey(coin string) string {
+	return "crypto:rate:" + coin
+}
+
+// aggregateExchangeRate takes the median of the quotes once at least
+// minSources agree within maxDivergenceBPS
+func aggregateExchangeRate(coin string, quotes map[string]float64, minSources, maxDivergenceBPS int) (*ExchangeRate, error) {
+	if len(quotes) == 0 || len(quotes) < minSources {
+		return nil, fmt.Errorf("%w: %s quoted by %d of %d required sources", ErrExchangeRateUnavailable, coin, len(quotes), minSources)
+	}
+	names := make([]string, 0, len(quotes))
+	prices := make([]float64, 0, len(quotes))
+	for name, price := range quotes {
+		names = append(names, name)
+		prices = append(prices, price)
+	}
+	slices.Sort(names)
+	slices.Sort(prices)
+
+	if bps := RateDivergenceBPS(prices[0], prices[len(prices)-1]); bps > maxDivergenceBPS {
+		return nil, fmt.Errorf("%w: %s quotes differ by %d bps (%v)", ErrExchangeRatesDiverged, coin, bps, quotes)
+	}
+	median := prices[len(prices)/2]
+	if len(prices)%2 == 0 {
+		median = (prices[len(prices)/2-1] + prices[len(prices)/2]) / 2
+	}
+	return &ExchangeRate{
+		Coin:         coin,
+		TomanPerCoin: median,
+		Source:       strings.Join(names, ","),
+		Sources:      quotes,
+		FetchedAt:    utils.UTCNow(),
+	}, nil
+}
+
+// RateDivergenceBPS is how far apart two prices are, in basis points of the
+// lower one
+func RateDivergenceBPS(a, b float64) int {
+	lo, hi := a, b
+	if lo > hi {
+		lo, hi = hi, lo
+	}
+	if lo <= 0 {
+		return 10000
+	}
+	return int((hi - lo) / lo * 10000)
+}
+
+// WallexRateSource prices coins from the latest Wallex trades, through USDT
+// for coins other than USDT
+type WallexRateSource struct {
+	BaseURL    string
+	HTTPClient *http.Client
+}
+
+func NewWallexRateSource(timeout time.Duration) *WallexRateSource {
+	return &WallexRateSource{BaseURL: "https://api.wallex.ir", HTTPClient: httpclient.New("wallex", timeout)}
+}
+
+func (s *WallexRateSource) Name() string { return "wallex" }
+
+func (s *WallexRateSource) TomanPerCoin(ctx context.Context, coin string) (float64, error) {
+	usdt, err := s.latestPrice(ctx, "USDTTMN")
+	if err != nil || strings.EqualFold(coin, "USDT") {
+		return usdt, err
+	}
+	price, err := s.latestPrice(ctx, strings.ToUpper(coin)+"USDT")
+	if err != nil {
+		return 0, err
+	}
+	return price * usdt, nil
+}
+
+func (s *WallexRateSource) latestPrice(ctx context.Context, pair string) (float64, error) {
+	var out struct {
+		Success bool `json:"success"`
+		Result  struct {
+			LatestTrades []struct {
+				Price string `json:"price"`
+			} `json:"latestTrades"`
+		} `json:"result"`
+	}
+	if err := getRateJSON(ctx, s.HTTPClient, s.BaseURL+"/v1/trades?symbol="+strings.ToLower(pair), &out); err != nil {
+		return 0, fmt.Errorf("wallex %s: %w", pair, err)
+	}
+	if !out.Success || len(out.Result.LatestTrades) == 0 {
+		return 0, fmt.Errorf("wallex %s: empty trades", pair)
+	}
+	return strconv.ParseFloat(out.Result.LatestTrades[0].Price, 64)
+}
+
+// NobitexRateSource prices coins from the latest Nobitex trade against the
+// rial
+type NobitexRateSource struct {
+	BaseURL    string
+	HTTPClient *http.Client
+}
+
+func NewNobitexRateSource(timeout time.Duration) *NobitexRateSource {
+	return &NobitexRateSource{BaseURL: "https://api.nobitex.ir", HTTPClient: httpclient.New("wallex", timeout)}
+}
+
+func (s *NobitexRateSource) Name() string { return "nobitex" }
+
+func (s *NobitexRateSource) TomanPerCoin(ctx context.Context, coin string) (float64, error) {
+	src := strings.ToLower(coin)
+	var out struct {
+		Status string `json:"status"`
+		Stats  map[string]struct {
+			Latest string `json:"latest"`
+		} `json:"stats"`
+	}
+	if err := getRateJSON(ctx, s.HTTPClient, s.BaseURL+"/market/stats?srcCurrency="+src+"&dstCurrency=rls", &out); err != nil {
+		return 0, fmt.Errorf("nobitex %s: %w", coin, err)
+	}
+	stats, ok := out.Stats[src+"-rls"]
+	if out.Status != "ok" || !ok || stats.Latest == "" {
+		return 0, fmt.Errorf("nobitex %s: no market stats", coin)
+	}
+	rials, err := strconv.ParseFloat(stats.Latest, 64)
+	if err != nil {
+		return 0, fmt.Errorf("nobitex %s: %w", coin, err)
+	}
+	return rials / 10, nil
+}
+
+func getRateJSON(ctx context.Context, client *http.Client, url string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("http %d", resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
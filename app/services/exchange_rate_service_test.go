@@ -0,0 +1,100 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeRateSource struct {
+	name  string
+	price float64
+	err   error
+}
+
+func (s fakeRateSource) Name() string { return s.name }
+
+func (s fakeRateSource) TomanPerCoin(context.Context, string) (float64, error) {
+	return s.price, s.err
+}
+
+func TestExchangeRateServiceRate(t *testing.T) {
+	cfg := config.ExchangeRateConfig{MinSources: 2, MaxDivergenceBPS: 300, Timeout: time.Second}
+	down := errors.New("down")
+	tests := []struct {
+		name    string
+		sources []ExchangeRateSource
+		want    float64
+		wantErr error
+	}{
+		{"two sources agree", []ExchangeRateSource{
+			fakeRateSource{name: "wallex", price: 100_000}, fakeRateSource{name: "nobitex", price: 102_000},
+		}, 101_000, nil},
+		{"median of three", []ExchangeRateSource{
+			fakeRateSource{name: "a", price: 100_000}, fakeRateSource{name: "b", price: 101_000}, fakeRateSource{name: "c", price: 102_500},
+		}, 101_000, nil},
+		{"one source down", []ExchangeRateSource{
+			fakeRateSource{name: "wallex", price: 100_000}, fakeRateSource{name: "nobitex", err: down},
+		}, 0, ErrExchangeRateUnavailable},
+		{"sources diverge", []ExchangeRateSource{
+			fakeRateSource{name: "wallex", price: 100_000}, fakeRateSource{name: "nobitex", price: 110_000},
+		}, 0, ErrExchangeRatesDiverged},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rate, err := NewExchangeRateService(nil, tt.sources, cfg).Rate(context.Background(), "usdt")
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "USDT", rate.Coin)
+			assert.InDelta(t, tt.want, rate.TomanPerCoin, 0.001)
+			assert.Len(t, rate.Sources, len(tt.sources))
+			assert.False(t, rate.FetchedAt.IsZero())
+		})
+	}
+}
+
+func TestExchangeRateSources(t *testing.T) {
+	client := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		var body string
+		switch req.URL.Host + req.URL.Path + "?" + req.URL.RawQuery {
+		case "api.wallex.ir/v1/trades?symbol=usdttmn":
+			body = `{"success":true,"result":{"latestTrades":[{"price":"100000"}]}}`
+		case "api.wallex.ir/v1/trades?symbol=ethusdt":
+			body = `{"success":true,"result":{"latestTrades":[{"price":"2500.5"}]}}`
+		case "api.nobitex.ir/market/stats?srcCurrency=eth&dstCurrency=rls":
+			body = `{"status":"ok","stats":{"eth-rls":{"latest":"2501000000"}}}`
+		default:
+			return &http.Response{StatusCode: http.StatusNotFound, Body: io.NopCloser(strings.NewReader(`{}`))}, nil
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(body))}, nil
+	})}
+
+	wallex := NewWallexRateSource(time.Second)
+	wallex.HTTPClient = client
+	price, err := wallex.TomanPerCoin(context.Background(), "ETH")
+	require.NoError(t, err)
+	assert.InDelta(t, 250_050_000, price, 0.001)
+
+	nobitex := NewNobitexRateSource(time.Second)
+	nobitex.HTTPClient = client
+	price, err = nobitex.TomanPerCoin(context.Background(), "ETH")
+	require.NoError(t, err)
+	assert.InDelta(t, 250_100_000, price, 0.001)
+
+	_, err = nobitex.TomanPerCoin(context.Background(), "DOGE")
+	assert.Error(t, err)
+
+	_, err = NewExchangeRateSources([]string{"wallex", "binance"}, time.Second)
+	assert.Error(t, err)
+}
@@ -0,0 +1,296 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/httpclient"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ErrPushTokenInvalid is returned for a device token FCM no longer accepts,
+// such as one of an uninstalled app or a revoked browser subscription. The
+// token should be removed.
+var ErrPushTokenInvalid = errors.New("push token is invalid or unregistered")
+
+// PushMessage is a notification shown on a device. Data is passed to the
+// app as is; Link is opened when a web notification is clicked.
+type PushMessage struct {
+	Title string
+	Body  string
+	Link  string
+	Data  map[string]string
+}
+
+// PushSender delivers push notifications to devices and topics
+type PushSender interface {
+	SendToToken(ctx context.Context, token string, msg PushMessage) error
+	SendToTopic(ctx context.Context, topic string, msg PushMessage) error
+	SubscribeToTopic(ctx context.Context, topic string, tokens []string) error
+}
+
+// FCMClient sends push notifications with the Firebase Cloud Messaging HTTP
+// v1 API, authenticated as a service account.
+// Docs: https://firebase.google.com/docs/reference/fcm/rest/v1/projects.messages
+type FCMClient struct {
+	BaseURL    string
+	IIDBaseURL string
+	ProjectID  string
+	HTTPClient *http.Client
+
+	clientEmail string
+	key         *rsa.PrivateKey
+	tokenURI    string
+
+	mu          sync.Mutex
+	accessToken string
+	tokenExpiry time.Time
+}
+
+// fcmServiceAccount is the part of a service account JSON key the client reads
+type fcmServiceAccount struct {
+	ProjectID   string `json:"project_id"`
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+}
+
+// LoadFCMClient reads the service account JSON key from disk
+func LoadFCMClient(credentialsFile, baseURL, iidBaseURL string, timeout time.Duration) (*FCMClient, error) {
+	data, err := os.ReadFile(credentialsFile)
+	if err != nil {
+		return nil, fmt.Errorf("fcm credentials: %w", err)
+	}
+	return NewFCMClient(data, baseURL, iidBaseURL, timeout)
+}
+
+func NewFCMClient(credentialsJSON []byte, baseURL, iidBaseURL string, timeout time.Duration) (*FCMClient, error) {
+	var sa fcmServiceAccount
+	if err := json.Unmarshal(credentialsJSON, &sa); err != nil {
+		return nil, fmt.Errorf("fcm credentials: %w", err)
+	}
+	if sa.ProjectID == "" || sa.ClientEmail == "" || sa.PrivateKey == "" {
+		return nil, errors.New("fcm credentials: project_id, client_email and private_key are required")
+	}
+	key, err := parseRSAPrivateKeyPEM([]byte(sa.PrivateKey))
+	if err != nil {
+		return nil, fmt.Errorf("fcm credentials: private key: %w", err)
+	}
+	if sa.TokenURI == "" {
+		sa.TokenURI = "https://oauth2.googleapis.com/token"
+	}
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	return &FCMClient{
+		BaseURL:     strings.TrimRight(baseURL, "/"),
+		IIDBaseURL:  strings.TrimRight(iidBaseURL, "/"),
+		ProjectID:   sa.ProjectID,
+		HTTPClient:  httpclient.New("fcm", timeout),
+		clientEmail: sa.ClientEmail,
+		key:         key,
+		tokenURI:    sa.TokenURI,
+	}, nil
+}
+
+type fcmMessage struct {
+	Token        string            `json:"token,omitempty"`
+	Topic        string            `json:"topic,omitempty"`
+	Notification fcmNotification   `json:"notification"`
+	Data         map[string]string `json:"data,omitempty"`
+	Webpush      *fcmWebpush       `json:"webpush,omitempty"`
+}
+
+type fcmNotification struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+}
+
+type fcmWebpush struct {
+	FCMOptions struct {
+		Link string `json:"link"`
+	} `json:"fcm_options"`
+}
+
+type fcmErrorResponse struct {
+	Error struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+		Status  string `json:"status"`
+		Details []struct {
+			ErrorCode string `json:"errorCode"`
+		} `json:"details"`
+	} `json:"error"`
+}
+
+// SendToToken sends a notification to one device. It returns
+// ErrPushTokenInvalid when FCM rejects the token.
+func (c *FCMClient) SendToToken(ctx context.Context, token string, msg PushMessage) error {
+	err := c.send(ctx, c.message(msg, token, ""))
+	var fe *fcmError
+	if errors.As(err, &fe) && fe.invalidToken() {
+		return fmt.Errorf("%w: %s", ErrPushTokenInvalid, fe.Error())
+	}
+	return err
+}
+
+// SendToTopic sends a notification to every device subscribed to a topic
+func (c *FCMClient) SendToTopic(ctx context.Context, topic string, msg PushMessage) error {
+	return c.send(ctx, c.message(msg, "", topic))
+}
+
+// SubscribeToTopic subscribes devices to a topic through the Instance ID
+// API; web clients can not subscribe themselves
+func (c *FCMClient) SubscribeToTopic(ctx context.Context, topic string, tokens []string) error {
+	body, _ := json.Marshal(map[string]any{"to": "/topics/" + topic, "registration_tokens": tokens})
+	req, err := c.newRequest(ctx, c.IIDBaseURL+"/iid/v1:batchAdd", body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("access_token_auth", "true")
+	return c.do(req, "subscribe to topic "+topic)
+}
+
+func (c *FCMClient) message(msg PushMessage, token, topic string) fcmMessage {
+	m := fcmMessage{
+		Token:        token,
+		Topic:        topic,
+		Notification: fcmNotification{Title: msg.Title, Body: msg.Body},
+		Data:         msg.Data,
+	}
+	if msg.Link != "" {
+		m.Webpush = &fcmWebpush{}
+		m.Webpush.FCMOptions.Link = msg.Link
+	}
+	return m
+}
+
+func (c *FCMClient) send(ctx context.Context, m fcmMessage) error {
+	body, err := json.Marshal(map[string]fcmMessage{"message": m})
+	if err != nil {
+		return fmt.Errorf("failed to marshal push message: %w", err)
+	}
+	req, err := c.newRequest(ctx, fmt.Sprintf("%s/v1/projects/%s/messages:send", c.BaseURL, c.ProjectID), body)
+	if err != nil {
+		return err
+	}
+	return c.do(req, "send push")
+}
+
+func (c *FCMClient) newRequest(ctx context.Context, endpoint string, body []byte) (*http.Request, error) {
+	token, err := c.authToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create FCM request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	return req, nil
+}
+
+func (c *FCMClient) do(req *http.Request, op string) error {
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("fcm %s: %w", op, err)
+	}
+	defer resp.Body.Close()
+	raw, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode/100 == 2 {
+		return nil
+	}
+	fe := &fcmError{op: op, statusCode: resp.StatusCode}
+	var er fcmErrorResponse
+	if json.Unmarshal(raw, &er) == nil {
+		fe.status, fe.message = er.Error.Status, er.Error.Message
+		for _, d := range er.Error.Details {
+			if d.ErrorCode != "" {
+				fe.errorCode = d.ErrorCode
+			}
+		}
+	}
+	return fe
+}
+
+// authToken returns an OAuth access token for the service account, reused
+// until shortly before it expires
+func (c *FCMClient) authToken(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.accessToken != "" && time.Now().Before(c.tokenExpiry) {
+		return c.accessToken, nil
+	}
+
+	now := time.Now()
+	assertion, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":   c.clientEmail,
+		"scope": "https://www.googleapis.com/auth/firebase.messaging",
+		"aud":   c.tokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	}).SignedString(c.key)
+	if err != nil {
+		return "", fmt.Errorf("fcm auth: %w", err)
+	}
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.tokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("fcm auth: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("fcm auth: %w", err)
+	}
+	defer resp.Body.Close()
+	var tok struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+		Error       string `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tok); err != nil {
+		return "", fmt.Errorf("fcm auth: decode token response (status %d): %w", resp.StatusCode, err)
+	}
+	if resp.StatusCode != http.StatusOK || tok.AccessToken == "" {
+		return "", fmt.Errorf("fcm auth: http %d: %s", resp.StatusCode, tok.Error)
+	}
+	c.accessToken = tok.AccessToken
+	c.tokenExpiry = now.Add(time.Duration(tok.ExpiresIn)*time.Second - time.Minute)
+	return c.accessToken, nil
+}
+
+// fcmError is an error response of FCM
+type fcmError struct {
+	op         string
+	statusCode int
+	status     string
+	errorCode  string
+	message    string
+}
+
+func (e *fcmError) Error() string {
+	return fmt.Sprintf("fcm %s: http %d %s %s: %s", e.op, e.statusCode, e.status, e.errorCode, e.message)
+}
+
+// invalidToken reports whether the error rejects the device token. Payloads
+// are built here, so an invalid argument on a token send is the token.
+func (e *fcmError) invalidToken() bool {
+	return e.errorCode == "UNREGISTERED" || e.errorCode == "INVALID_ARGUMENT" ||
+		(e.errorCode == "" && (e.status == "NOT_FOUND" || e.status == "INVALID_ARGUMENT"))
+}
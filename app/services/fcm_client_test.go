@@ -0,0 +1,101 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fcmTestClient answers the OAuth token and FCM requests with canned bodies
+// keyed by path, and records the FCM requests it received
+func fcmTestClient(t *testing.T, responses map[string]string, status int) (*FCMClient, *[]*http.Request, *int) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: mustPKCS8(t, key)})
+	creds, _ := json.Marshal(map[string]string{
+		"project_id":   "jaazebeh-web",
+		"client_email": "push@jaazebeh-web.iam.gserviceaccount.com",
+		"private_key":  string(keyPEM),
+		"token_uri":    "https://oauth2.googleapis.com/token",
+	})
+	client, err := NewFCMClient(creds, "https://fcm.googleapis.com/", "https://iid.googleapis.com", 0)
+	require.NoError(t, err)
+
+	var requests []*http.Request
+	tokenRequests := 0
+	client.HTTPClient = &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Host == "oauth2.googleapis.com" {
+			tokenRequests++
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(`{"access_token":"ya29.token","expires_in":3600}`))}, nil
+		}
+		body, _ := io.ReadAll(req.Body)
+		req.Body = io.NopCloser(strings.NewReader(string(body)))
+		requests = append(requests, req)
+		return &http.Response{StatusCode: status, Body: io.NopCloser(strings.NewReader(responses[req.URL.Path]))}, nil
+	})}
+	return client, &requests, &tokenRequests
+}
+
+func mustPKCS8(t *testing.T, key *rsa.PrivateKey) []byte {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	return der
+}
+
+func TestFCMClientSendToToken(t *testing.T) {
+	client, requests, tokenRequests := fcmTestClient(t, map[string]string{
+		"/v1/projects/jaazebeh-web/messages:send": `{"name":"projects/jaazebeh-web/messages/1"}`,
+	}, http.StatusOK)
+
+	msg := PushMessage{Title: "Campaign approved", Body: "Spring sale was approved", Link: "https://jaazebeh.ir/campaigns", Data: map[string]string{"kind": "campaign"}}
+	require.NoError(t, client.SendToToken(context.Background(), "device-1", msg))
+	require.NoError(t, client.SendToTopic(context.Background(), "customers", msg))
+	require.Len(t, *requests, 2)
+	assert.Equal(t, 1, *tokenRequests, "the access token is reused")
+
+	req := (*requests)[0]
+	assert.Equal(t, "Bearer ya29.token", req.Header.Get("Authorization"))
+	body, _ := io.ReadAll(req.Body)
+	assert.JSONEq(t, `{"message":{"token":"device-1","notification":{"title":"Campaign approved","body":"Spring sale was approved"},
+		"data":{"kind":"campaign"},"webpush":{"fcm_options":{"link":"https://jaazebeh.ir/campaigns"}}}}`, string(body))
+	body, _ = io.ReadAll((*requests)[1].Body)
+	assert.Contains(t, string(body), `"topic":"customers"`)
+}
+
+func TestFCMClientInvalidToken(t *testing.T) {
+	client, _, _ := fcmTestClient(t, map[string]string{
+		"/v1/projects/jaazebeh-web/messages:send": `{"error":{"code":404,"message":"Requested entity was not found.","status":"NOT_FOUND",
+			"details":[{"@type":"type.googleapis.com/google.firebase.fcm.v1.FcmError","errorCode":"UNREGISTERED"}]}}`,
+	}, http.StatusNotFound)
+
+	err := client.SendToToken(context.Background(), "gone", PushMessage{Title: "t", Body: "b"})
+	assert.True(t, errors.Is(err, ErrPushTokenInvalid), "got %v", err)
+
+	// Topic sends never report a device token as invalid
+	err = client.SendToTopic(context.Background(), "customers", PushMessage{Title: "t", Body: "b"})
+	require.Error(t, err)
+	assert.False(t, errors.Is(err, ErrPushTokenInvalid))
+}
+
+func TestFCMClientSubscribeToTopic(t *testing.T) {
+	client, requests, _ := fcmTestClient(t, map[string]string{"/iid/v1:batchAdd": `{"results":[{}]}`}, http.StatusOK)
+
+	require.NoError(t, client.SubscribeToTopic(context.Background(), "customers", []string{"device-1"}))
+	req := (*requests)[0]
+	assert.Equal(t, "true", req.Header.Get("access_token_auth"))
+	body, _ := io.ReadAll(req.Body)
+	assert.JSONEq(t, `{"to":"/topics/customers","registration_tokens":["device-1"]}`, string(body))
+}
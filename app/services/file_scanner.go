@@ -0,0 +1,97 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// ErrFileInfected is returned for an upload the scanner flagged
+var ErrFileInfected = errors.New("file is infected")
+
+// FileScanner checks uploaded files for malware before they are stored
+type FileScanner interface {
+	Scan(ctx context.Context, data []byte) error
+}
+
+// NoopFileScanner accepts every file; it is used while no scanner is configured
+type NoopFileScanner struct{}
+
+func (NoopFileScanner) Scan(context.Context, []byte) error { return nil }
+
+// ClamAVScanner streams files to a clamd daemon with the INSTREAM command.
+// Docs: https://docs.clamav.net/manual/Usage/Scanning.html#clamd
+type ClamAVScanner struct {
+	Address string
+	Timeout time.Duration
+}
+
+// clamAVChunkSize stays well below clamd's default StreamMaxLength
+const clamAVChunkSize = 64 * 1024
+
+func NewClamAVScanner(address string, timeout time.Duration) *ClamAVScanner {
+	return &ClamAVScanner{Address: address, Timeout: timeout}
+}
+
+// Scan returns ErrFileInfected wrapped with the signature name when clamd
+// finds something, and a plain error when the scan itself failed
+func (s *ClamAVScanner) Scan(ctx context.Context, data []byte) error {
+	dialer := net.Dialer{Timeout: s.Timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", s.Address)
+	if err != nil {
+		return fmt.Errorf("clamav dial: %w", err)
+	}
+	defer conn.Close()
+	var deadline time.Time
+	if s.Timeout > 0 {
+		deadline = time.Now().Add(s.Timeout)
+	}
+	if d, ok := ctx.Deadline(); ok && (deadline.IsZero() || d.Before(deadline)) {
+		deadline = d
+	}
+	_ = conn.SetDeadline(deadline)
+
+	if _, err := conn.Write([]byte("zINSTREAM\x00")); err != nil {
+		return fmt.Errorf("clamav write: %w", err)
+	}
+	size := make([]byte, 4)
+	for start := 0; start < len(data); start += clamAVChunkSize {
+		chunk := data[start:min(start+clamAVChunkSize, len(data))]
+		binary.BigEndian.PutUint32(size, uint32(len(chunk)))
+		if _, err := conn.Write(size); err != nil {
+			return fmt.Errorf("clamav write: %w", err)
+		}
+		if _, err := conn.Write(chunk); err != nil {
+			return fmt.Errorf("clamav write: %w", err)
+		}
+	}
+	binary.BigEndian.PutUint32(size, 0)
+	if _, err := conn.Write(size); err != nil {
+		return fmt.Errorf("clamav write: %w", err)
+	}
+
+	reply, err := bufio.NewReader(conn).ReadString(0)
+	if err != nil && reply == "" {
+		return fmt.Errorf("clamav read: %w", err)
+	}
+	return parseClamAVReply(reply)
+}
+
+// parseClamAVReply reads replies such as "stream: OK" and
+// "stream: Eicar-Signature FOUND"
+func parseClamAVReply(reply string) error {
+	reply = strings.TrimSpace(strings.TrimRight(reply, "\x00"))
+	result := strings.TrimSpace(strings.TrimPrefix(reply, "stream:"))
+	switch {
+	case result == "OK":
+		return nil
+	case strings.HasSuffix(result, " FOUND"):
+		return fmt.Errorf("%w: %s", ErrFileInfected, strings.TrimSuffix(result, " FOUND"))
+	}
+	return fmt.Errorf("clamav: %s", reply)
+}
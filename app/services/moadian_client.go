@@ -0,0 +1,415 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/httpclient"
+	"github.com/google/uuid"
+)
+
+// MoadianClient submits e-invoices to Moadian and looks up their results
+type MoadianClient interface {
+	// SubmitInvoice sends a signed and encrypted invoice and returns the
+	// reference number to look its result up by
+	SubmitInvoice(ctx context.Context, inv MoadianInvoice) (string, error)
+	InquireByReference(ctx context.Context, referenceNumber string) (*MoadianInquiryResult, error)
+}
+
+// Statuses of a submitted invoice
+const (
+	MoadianResultSuccess    = "SUCCESS"
+	MoadianResultFailed     = "FAILED"
+	MoadianResultPending    = "PENDING"
+	MoadianResultInProgress = "IN_PROGRESS"
+)
+
+// MoadianInquiryResult is the processing result of a submitted invoice
+type MoadianInquiryResult struct {
+	ReferenceNumber string
+	Status          string
+	Errors          []string
+}
+
+// MoadianError is an error response of Moadian. Transient errors, such as
+// timeouts or an unavailable server, may succeed when retried; the others
+// reject the invoice itself.
+type MoadianError struct {
+	StatusCode int
+	Message    string
+	Transient  bool
+}
+
+func (e *MoadianError) Error() string {
+	if e.StatusCode == 0 {
+		return "moadian: " + e.Message
+	}
+	return fmt.Sprintf("moadian: http %d: %s", e.StatusCode, e.Message)
+}
+
+// IsMoadianTransient reports whether err may succeed when retried. Network
+// errors, which are not a MoadianError, are transient.
+func IsMoadianTransient(err error) bool {
+	var me *MoadianError
+	if errors.As(err, &me) {
+		return me.Transient
+	}
+	return err != nil
+}
+
+// MoadianHTTPClient talks to the Moadian API. Requests are authenticated by
+// a token signed with the taxpayer's key; invoices are signed with the same
+// key (JWS) and then encrypted for the tax authority (JWE).
+type MoadianHTTPClient struct {
+	BaseURL    string
+	MemoryID   string
+	Key        *rsa.PrivateKey
+	CertDER    []byte
+	HTTPClient *http.Client
+
+	mu          sync.Mutex
+	token       string
+	tokenExpiry time.Time
+	serverKey   *rsa.PublicKey
+	serverKeyID string
+}
+
+func NewMoadianHTTPClient(baseURL, memoryID string, key *rsa.PrivateKey, certDER []byte, timeout time.Duration, proxyURL string) (*MoadianHTTPClient, error) {
+	// Moadian is reachable from Iranian networks only
+	httpClient, err := httpclient.NewWithProxy("moadian", timeout, proxyURL)
+	if err != nil {
+		return nil, fmt.Errorf("moadian proxy: %w", err)
+	}
+	return &MoadianHTTPClient{
+		BaseURL:    strings.TrimRight(baseURL, "/"),
+		MemoryID:   memoryID,
+		Key:        key,
+		CertDER:    certDER,
+		HTTPClient: httpClient,
+	}, nil
+}
+
+// LoadMoadianHTTPClient reads the PEM private key and certificate from disk
+func LoadMoadianHTTPClient(baseURL, memoryID, keyPath, certPath string, timeout time.Duration, proxyURL string) (*MoadianHTTPClient, error) {
+	keyPEM, err := os.ReadFile(keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("moadian private key: %w", err)
+	}
+	key, err := parseRSAPrivateKeyPEM(keyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("moadian private key: %w", err)
+	}
+	certPEM, err := os.ReadFile(certPath)
+	if err != nil {
+		return nil, fmt.Errorf("moadian certificate: %w", err)
+	}
+	block, _ := pem.Decode(certPEM)
+	if block == nil {
+		return nil, errors.New("moadian certificate: no PEM block")
+	}
+	if _, err := x509.ParseCertificate(block.Bytes); err != nil {
+		return nil, fmt.Errorf("moadian certificate: %w", err)
+	}
+	return NewMoadianHTTPClient(baseURL, memoryID, key, block.Bytes, timeout, proxyURL)
+}
+
+func parseRSAPrivateKeyPEM(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("not an RSA key")
+	}
+	return key, nil
+}
+
+type moadianPacket struct {
+	Payload string              `json:"payload"`
+	Header  moadianPacketHeader `json:"header"`
+}
+
+type moadianPacketHeader struct {
+	RequestTraceID string `json:"requestTraceId"`
+	FiscalID       string `json:"fiscalId"`
+}
+
+type moadianErrorItem struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (c *MoadianHTTPClient) SubmitInvoice(ctx context.Context, inv MoadianInvoice) (string, error) {
+	token, err := c.authToken(ctx)
+	if err != nil {
+		return "", err
+	}
+	serverKey, serverKeyID, err := c.encryptionKey(ctx, token)
+	if err != nil {
+		return "", err
+	}
+	body, err := json.Marshal(inv)
+	if err != nil {
+		return "", err
+	}
+	signed, err := c.sign(body)
+	if err != nil {
+		return "", err
+	}
+	payload, err := encryptJWE([]byte(signed), serverKey, serverKeyID)
+	if err != nil {
+		return "", err
+	}
+
+	traceID := uuid.NewString()
+	packets, _ := json.Marshal([]moadianPacket{{
+		Payload: payload,
+		Header:  moadianPacketHeader{RequestTraceID: traceID, FiscalID: c.MemoryID},
+	}})
+	var resp struct {
+		Result []struct {
+			UID             string `json:"uid"`
+			ReferenceNumber string `json:"referenceNumber"`
+		} `json:"result"`
+		Errors []moadianErrorItem `json:"errors"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/invoice", token, packets, &resp); err != nil {
+		return "", err
+	}
+	if len(resp.Errors) > 0 {
+		return "", &MoadianError{Message: joinMoadianErrors(resp.Errors)}
+	}
+	if len(resp.Result) == 0 || resp.Result[0].ReferenceNumber == "" {
+		return "", &MoadianError{Message: "no reference number in response", Transient: true}
+	}
+	return resp.Result[0].ReferenceNumber, nil
+}
+
+func (c *MoadianHTTPClient) InquireByReference(ctx context.Context, referenceNumber string) (*MoadianInquiryResult, error) {
+	token, err := c.authToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var resp []struct {
+		ReferenceNumber string `json:"referenceNumber"`
+		Status          string `json:"status"`
+		Data            struct {
+			Error []moadianErrorItem `json:"error"`
+		} `json:"data"`
+	}
+	path := "/inquiry-by-reference-number?referenceNumbers=" + url.QueryEscape(referenceNumber)
+	if err := c.do(ctx, http.MethodGet, path, token, nil, &resp); err != nil {
+		return nil, err
+	}
+	if len(resp) == 0 {
+		return &MoadianInquiryResult{ReferenceNumber: referenceNumber, Status: MoadianResultPending}, nil
+	}
+	result := &MoadianInquiryResult{ReferenceNumber: resp[0].ReferenceNumber, Status: resp[0].Status}
+	for _, e := range resp[0].Data.Error {
+		result.Errors = append(result.Errors, strings.TrimSpace(e.Code+" "+e.Message))
+	}
+	return result, nil
+}
+
+// authToken returns a token signed over a fresh nonce, reused until shortly
+// before the nonce expires
+func (c *MoadianHTTPClient) authToken(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.token != "" && time.Now().Before(c.tokenExpiry) {
+		return c.token, nil
+	}
+
+	var nonce struct {
+		Nonce string `json:"nonce"`
+	}
+	const ttl = 20 * time.Second
+	if err := c.do(ctx, http.MethodGet, fmt.Sprintf("/nonce?timeToLive=%d", int(ttl.Seconds())), "", nil, &nonce); err != nil {
+		return "", err
+	}
+	claims, _ := json.Marshal(map[string]string{"nonce": nonce.Nonce, "clientId": c.MemoryID})
+	token, err := c.sign(claims)
+	if err != nil {
+		return "", err
+	}
+	c.token, c.tokenExpiry = token, time.Now().Add(ttl-5*time.Second)
+	return token, nil
+}
+
+// encryptionKey returns the tax authority's public key invoices are
+// encrypted for
+func (c *MoadianHTTPClient) encryptionKey(ctx context.Context, token string) (*rsa.PublicKey, string, error) {
+	c.mu.Lock()
+	key, id := c.serverKey, c.serverKeyID
+	c.mu.Unlock()
+	if key != nil {
+		return key, id, nil
+	}
+
+	var info struct {
+		PublicKeys []struct {
+			Key string `json:"key"`
+			ID  string `json:"id"`
+		} `json:"publicKeys"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/server-information", token, nil, &info); err != nil {
+		return nil, "", err
+	}
+	if len(info.PublicKeys) == 0 {
+		return nil, "", &MoadianError{Message: "no public key in server information", Transient: true}
+	}
+	der, err := base64.StdEncoding.DecodeString(info.PublicKeys[0].Key)
+	if err != nil {
+		return nil, "", fmt.Errorf("moadian public key: %w", err)
+	}
+	parsed, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, "", fmt.Errorf("moadian public key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PublicKey)
+	if !ok {
+		return nil, "", errors.New("moadian public key: not an RSA key")
+	}
+	c.mu.Lock()
+	c.serverKey, c.serverKeyID = key, info.PublicKeys[0].ID
+	c.mu.Unlock()
+	return key, info.PublicKeys[0].ID, nil
+}
+
+func (c *MoadianHTTPClient) do(ctx context.Context, method, path, token string, body []byte, out any) error {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	req.Header.Set("requestTraceId", uuid.NewString())
+	req.Header.Set("timestamp", fmt.Sprintf("%d", time.Now().UnixMilli()))
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("moadian %s: %w", path, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("moadian %s: %w", path, err)
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		// The token expired early; fetch a new one on the next try
+		c.mu.Lock()
+		c.token = ""
+		c.mu.Unlock()
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &MoadianError{
+			StatusCode: resp.StatusCode,
+			Message:    strings.TrimSpace(string(data)),
+			Transient: resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests ||
+				resp.StatusCode == http.StatusRequestTimeout || resp.StatusCode == http.StatusUnauthorized,
+		}
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return &MoadianError{StatusCode: resp.StatusCode, Message: "invalid response: " + err.Error(), Transient: true}
+	}
+	return nil
+}
+
+// sign returns payload as a compact JWS signed with RS256. The certificate
+// identifies the taxpayer and sigT the signing time, as Moadian requires.
+func (c *MoadianHTTPClient) sign(payload []byte) (string, error) {
+	header, _ := json.Marshal(map[string]any{
+		"alg":  "RS256",
+		"typ":  "jose",
+		"x5c":  []string{base64.StdEncoding.EncodeToString(c.CertDER)},
+		"sigT": time.Now().UTC().Format("2006-01-02T15:04:05Z"),
+		"crit": []string{"sigT"},
+		"cty":  "text/plain",
+	})
+	input := b64url(header) + "." + b64url(payload)
+	digest := sha256.Sum256([]byte(input))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, c.Key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("moadian sign: %w", err)
+	}
+	return input + "." + b64url(sig), nil
+}
+
+// encryptJWE encrypts plaintext for key as a compact JWE with RSA-OAEP-256
+// key wrapping and A256GCM content encryption
+func encryptJWE(plaintext []byte, key *rsa.PublicKey, keyID string) (string, error) {
+	header, _ := json.Marshal(map[string]string{"alg": "RSA-OAEP-256", "enc": "A256GCM", "kid": keyID})
+	protected := b64url(header)
+
+	cek := make([]byte, 32)
+	if _, err := rand.Read(cek); err != nil {
+		return "", err
+	}
+	wrapped, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, key, cek, nil)
+	if err != nil {
+		return "", fmt.Errorf("moadian encrypt: %w", err)
+	}
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return "", err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", err
+	}
+	iv := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(iv); err != nil {
+		return "", err
+	}
+	sealed := gcm.Seal(nil, iv, plaintext, []byte(protected))
+	ciphertext, tag := sealed[:len(sealed)-gcm.Overhead()], sealed[len(sealed)-gcm.Overhead():]
+	return strings.Join([]string{protected, b64url(wrapped), b64url(iv), b64url(ciphertext), b64url(tag)}, "."), nil
+}
+
+func b64url(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func joinMoadianErrors(items []moadianErrorItem) string {
+	msgs := make([]string, 0, len(items))
+	for _, e := range items {
+		msgs = append(msgs, strings.TrimSpace(e.Code+" "+e.Message))
+	}
+	return strings.Join(msgs, "; ")
+}
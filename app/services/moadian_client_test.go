@@ -0,0 +1,121 @@
+package services
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMoadianTaxID(t *testing.T) {
+	assert.Equal(t, 3, verhoeffCheckDigit("236"))
+
+	issuedAt := time.Date(2026, 10, 17, 9, 30, 0, 0, time.UTC)
+	id := MoadianTaxID("A1B2C3", issuedAt, 42)
+	require.Len(t, id, 22)
+	assert.True(t, strings.HasPrefix(id, "A1B2C3"))
+	assert.Equal(t, "000000002A", id[11:21])
+	assert.Equal(t, "000000002A", MoadianSerial(42))
+	assert.Equal(t, id, MoadianTaxID("A1B2C3", issuedAt.Add(time.Hour), 42), "same day, same ID")
+	assert.NotEqual(t, id, MoadianTaxID("A1B2C3", issuedAt, 43))
+}
+
+func TestEncryptJWE(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	out, err := encryptJWE([]byte("signed invoice"), &key.PublicKey, "key-1")
+	require.NoError(t, err)
+	parts := strings.Split(out, ".")
+	require.Len(t, parts, 5)
+
+	decode := func(s string) []byte {
+		b, err := base64.RawURLEncoding.DecodeString(s)
+		require.NoError(t, err)
+		return b
+	}
+	var header map[string]string
+	require.NoError(t, json.Unmarshal(decode(parts[0]), &header))
+	assert.Equal(t, "RSA-OAEP-256", header["alg"])
+	assert.Equal(t, "key-1", header["kid"])
+
+	cek, err := rsa.DecryptOAEP(sha256.New(), rand.Reader, key, decode(parts[1]), nil)
+	require.NoError(t, err)
+	block, err := aes.NewCipher(cek)
+	require.NoError(t, err)
+	gcm, err := cipher.NewGCM(block)
+	require.NoError(t, err)
+	plain, err := gcm.Open(nil, decode(parts[2]), append(decode(parts[3]), decode(parts[4])...), []byte(parts[0]))
+	require.NoError(t, err)
+	assert.Equal(t, "signed invoice", string(plain))
+}
+
+func TestMoadianHTTPClientSubmitAndInquire(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	serverKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	serverDER, err := x509.MarshalPKIXPublicKey(&serverKey.PublicKey)
+	require.NoError(t, err)
+
+	var nonces int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/nonce" && !strings.HasPrefix(r.Header.Get("Authorization"), "Bearer ") {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/nonce":
+			nonces++
+			_, _ = w.Write([]byte(`{"nonce":"n-1","expDate":""}`))
+		case "/server-information":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"publicKeys": []map[string]string{{"key": base64.StdEncoding.EncodeToString(serverDER), "id": "key-1"}},
+			})
+		case "/invoice":
+			var packets []moadianPacket
+			if err := json.NewDecoder(r.Body).Decode(&packets); err != nil || len(packets) != 1 || packets[0].Header.FiscalID != "A1B2C3" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			_, _ = w.Write([]byte(`{"result":[{"uid":"u-1","referenceNumber":"ref-1"}]}`))
+		case "/inquiry-by-reference-number":
+			if r.URL.Query().Get("referenceNumbers") == "ref-2" {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			_, _ = w.Write([]byte(`[{"referenceNumber":"ref-1","status":"FAILED","data":{"error":[{"code":"0100","message":"invalid tins"}]}}]`))
+		}
+	}))
+	defer srv.Close()
+
+	client, err := NewMoadianHTTPClient(srv.URL, "A1B2C3", key, []byte("cert"), 5*time.Second, "")
+	require.NoError(t, err)
+
+	ref, err := client.SubmitInvoice(context.Background(), MoadianInvoice{Header: MoadianInvoiceHeader{TaxID: "A1B2C3"}})
+	require.NoError(t, err)
+	assert.Equal(t, "ref-1", ref)
+
+	result, err := client.InquireByReference(context.Background(), ref)
+	require.NoError(t, err)
+	assert.Equal(t, MoadianResultFailed, result.Status)
+	assert.Equal(t, []string{"0100 invalid tins"}, result.Errors)
+	assert.Equal(t, 1, nonces, "the token is reused while fresh")
+
+	_, err = client.InquireByReference(context.Background(), "ref-2")
+	assert.True(t, IsMoadianTransient(err))
+	assert.False(t, IsMoadianTransient(&MoadianError{StatusCode: http.StatusBadRequest}))
+}
@@ -0,0 +1,128 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MoadianInvoice is an e-invoice in the format of Moadian, the tax
+// authority's e-invoicing system. Field names follow the official schema;
+// amounts are in rial.
+type MoadianInvoice struct {
+	Header   MoadianInvoiceHeader    `json:"header"`
+	Body     []MoadianInvoiceItem    `json:"body"`
+	Payments []MoadianInvoicePayment `json:"payments"`
+}
+
+// MoadianInvoiceHeader is the header of an e-invoice
+type MoadianInvoiceHeader struct {
+	TaxID        string `json:"taxid"`             // Tax ID, see MoadianTaxID
+	IssuedAt     int64  `json:"indatim"`           // Issue time, Unix milliseconds
+	CreatedAt    int64  `json:"indati2m"`          // Creation time, Unix milliseconds
+	Type         int    `json:"inty"`              // 1 with the buyer's identity, 2 without
+	Serial       string `json:"inno"`              // Seller's internal serial
+	Pattern      int    `json:"inp"`               // 1 for sale
+	Subject      int    `json:"ins"`               // 1 for an original invoice
+	SellerTaxID  string `json:"tins"`              // Seller's economic code
+	BuyerType    int    `json:"tob,omitempty"`     // 1 natural person, 2 legal entity
+	BuyerID      string `json:"bid,omitempty"`     // Buyer's national ID
+	BuyerTaxID   string `json:"tinb,omitempty"`    // Buyer's economic code
+	BuyerPostal  string `json:"bpc,omitempty"`     // Buyer's postal code
+	TotalPrice   uint64 `json:"tprdis"`            // Total before discount
+	TotalDisc    uint64 `json:"tdis"`              // Total discount
+	TotalNet     uint64 `json:"tadis"`             // Total after discount
+	TotalVAT     uint64 `json:"tvam"`              // Total VAT
+	TotalOther   uint64 `json:"todam"`             // Total other duties
+	TotalBill    uint64 `json:"tbill"`             // Amount payable
+	Settlement   int    `json:"setm"`              // 1 cash, 2 credit, 3 both
+	CashPaid     uint64 `json:"cap"`               // Paid in cash
+	CreditPaid   uint64 `json:"insp"`              // Paid on credit
+	TotalVATPaid uint64 `json:"tvop"`              // VAT share of the amount paid
+	Tax17        uint64 `json:"tax17,omitempty"`   // Article 17 tax
+	IrTaxID      string `json:"irtaxid,omitempty"` // Tax ID of the invoice this one amends
+}
+
+// MoadianInvoiceItem is a line of an e-invoice
+type MoadianInvoiceItem struct {
+	ServiceID   string  `json:"sstid"`  // Goods or services ID
+	Description string  `json:"sstt"`   // Description
+	Quantity    uint64  `json:"am"`     // Quantity
+	Unit        string  `json:"mu"`     // Unit of measurement code
+	Fee         uint64  `json:"fee"`    // Unit price
+	Price       uint64  `json:"prdis"`  // Price before discount
+	Discount    uint64  `json:"dis"`    // Discount
+	Net         uint64  `json:"adis"`   // Price after discount
+	VATRate     float64 `json:"vra"`    // VAT rate in percent
+	VAT         uint64  `json:"vam"`    // VAT
+	Total       uint64  `json:"tsstam"` // Line total
+}
+
+// MoadianInvoicePayment is a payment of an e-invoice
+type MoadianInvoicePayment struct {
+	Reference string `json:"trn,omitempty"` // Payment trace number
+	PaidAt    int64  `json:"pdt"`           // Payment time, Unix milliseconds
+}
+
+// MoadianUnitEach is the measurement unit code of "each"
+const MoadianUnitEach = "1627"
+
+// MoadianTaxID builds the 22-character tax ID of an invoice from the
+// taxpayer's memory ID, the issue day and the seller's serial number: the
+// memory ID, the days since the Unix epoch as 5 hex digits, the serial as 10
+// hex digits and a Verhoeff check digit over their decimal form.
+func MoadianTaxID(memoryID string, issuedAt time.Time, serial uint64) string {
+	days := issuedAt.Unix() / 86400
+	var decimal strings.Builder
+	for _, r := range memoryID {
+		if r >= '0' && r <= '9' {
+			decimal.WriteRune(r)
+		} else {
+			decimal.WriteString(strconv.Itoa(int(r)))
+		}
+	}
+	decimal.WriteString(fmt.Sprintf("%06d%012d", days, serial))
+	return strings.ToUpper(fmt.Sprintf("%s%05x%010x%d", memoryID, days, serial, verhoeffCheckDigit(decimal.String())))
+}
+
+// MoadianSerial is the seller's internal serial of an invoice as sent in inno
+func MoadianSerial(serial uint64) string {
+	return strings.ToUpper(fmt.Sprintf("%010x", serial))
+}
+
+var (
+	verhoeffD = [10][10]int{
+		{0, 1, 2, 3, 4, 5, 6, 7, 8, 9},
+		{1, 2, 3, 4, 0, 6, 7, 8, 9, 5},
+		{2, 3, 4, 0, 1, 7, 8, 9, 5, 6},
+		{3, 4, 0, 1, 2, 8, 9, 5, 6, 7},
+		{4, 0, 1, 2, 3, 9, 5, 6, 7, 8},
+		{5, 9, 8, 7, 6, 0, 4, 3, 2, 1},
+		{6, 5, 9, 8, 7, 1, 0, 4, 3, 2},
+		{7, 6, 5, 9, 8, 2, 1, 0, 4, 3},
+		{8, 7, 6, 5, 9, 3, 2, 1, 0, 4},
+		{9, 8, 7, 6, 5, 4, 3, 2, 1, 0},
+	}
+	verhoeffP = [8][10]int{
+		{0, 1, 2, 3, 4, 5, 6, 7, 8, 9},
+		{1, 5, 7, 6, 2, 8, 3, 0, 9, 4},
+		{5, 8, 0, 3, 7, 9, 6, 1, 4, 2},
+		{8, 9, 1, 6, 0, 4, 3, 5, 2, 7},
+		{9, 4, 5, 3, 1, 2, 6, 8, 7, 0},
+		{4, 2, 8, 6, 5, 7, 3, 9, 0, 1},
+		{2, 7, 9, 3, 8, 0, 6, 4, 1, 5},
+		{7, 0, 4, 6, 9, 1, 3, 2, 5, 8},
+	}
+	verhoeffInv = [10]int{0, 4, 3, 2, 1, 5, 6, 7, 8, 9}
+)
+
+// verhoeffCheckDigit computes the Verhoeff check digit of a decimal string
+func verhoeffCheckDigit(digits string) int {
+	c := 0
+	for i := 0; i < len(digits); i++ {
+		d := int(digits[len(digits)-1-i] - '0')
+		c = verhoeffD[c][verhoeffP[(i+1)%8][d]]
+	}
+	return verhoeffInv[c]
+}
@@ -8,17 +8,24 @@ import (
 	"strings"
 )
 
-// NotificationService handles sending notifications via SMS and email
+// NotificationService handles sending notifications via SMS, email, Telegram
+// and push
 type NotificationService interface {
 	SendSMS(ctx context.Context, mobile, message string, customerID *int64) error
 	SendSMSBulk(ctx context.Context, mobiles []string, message string, customerID *int64) error
 	SendEmail(email, subject, message string) error
+	SendTelegram(ctx context.Context, chatID int64, message string) error
+	SendPush(ctx context.Context, token string, msg PushMessage) error
+	SendPushTopic(ctx context.Context, topic string, msg PushMessage) error
+	SubscribePushTopic(ctx context.Context, topic, token string) error
 }
 
 // NotificationServiceImpl implements NotificationService
 type NotificationServiceImpl struct {
 	smsService    SMSService
 	emailProvider EmailProvider
+	telegram      TelegramSender
+	push          PushSender
 }
 
 // EmailProvider interface for email sending
@@ -26,11 +33,14 @@ type EmailProvider interface {
 	SendEmail(email, subject, message string) error
 }
 
-// NewNotificationService creates a new notification service
-func NewNotificationService(smsService SMSService, emailProvider EmailProvider) NotificationService {
+// NewNotificationService creates a new notification service. telegram and
+// push may be nil when no bot or Firebase project is configured.
+func NewNotificationService(smsService SMSService, emailProvider EmailProvider, telegram TelegramSender, push PushSender) NotificationService {
 	return &NotificationServiceImpl{
 		smsService:    smsService,
 		emailProvider: emailProvider,
+		telegram:      telegram,
+		push:          push,
 	}
 }
 
@@ -91,6 +101,42 @@ func (s *NotificationServiceImpl) SendEmail(email, subject, message string) erro
 	return s.emailProvider.SendEmail(email, subject, message)
 }
 
+// SendTelegram sends a message to a linked Telegram chat
+func (s *NotificationServiceImpl) SendTelegram(ctx context.Context, chatID int64, message string) error {
+	if s.telegram == nil {
+		return fmt.Errorf("telegram bot not configured")
+	}
+	if chatID == 0 {
+		return fmt.Errorf("invalid telegram chat id")
+	}
+	return s.telegram.SendMessage(ctx, chatID, message)
+}
+
+// SendPush sends a push notification to a registered device. It returns
+// ErrPushTokenInvalid when the token should be removed.
+func (s *NotificationServiceImpl) SendPush(ctx context.Context, token string, msg PushMessage) error {
+	if s.push == nil {
+		return fmt.Errorf("push notifications not configured")
+	}
+	return s.push.SendToToken(ctx, token, msg)
+}
+
+// SendPushTopic sends a push notification to every device of a topic
+func (s *NotificationServiceImpl) SendPushTopic(ctx context.Context, topic string, msg PushMessage) error {
+	if s.push == nil {
+		return fmt.Errorf("push notifications not configured")
+	}
+	return s.push.SendToTopic(ctx, topic, msg)
+}
+
+// SubscribePushTopic subscribes a registered device to a topic
+func (s *NotificationServiceImpl) SubscribePushTopic(ctx context.Context, topic, token string) error {
+	if s.push == nil {
+		return fmt.Errorf("push notifications not configured")
+	}
+	return s.push.SubscribeToTopic(ctx, topic, []string{token})
+}
+
 type MockEmailProvider struct{}
 
 func NewMockEmailProvider() EmailProvider {
@@ -0,0 +1,455 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/httpclient"
+)
+
+// NowPaymentsClient is a secondary crypto provider used when OxaPay is
+// unavailable. Amounts are priced in USD, converted from toman with the
+// Wallex USDTTMN rate.
+// Docs: https://documenter.getpostman.com/view/7907941/2s93JusNJt
+type NowPaymentsClient struct {
+	BaseURL    string
+	APIKey     string
+	HTTPClient *http.Client
+	Timeout    time.Duration
+}
+
+func NewNowPaymentsClient(baseURL, apiKey string, timeout time.Duration) *NowPaymentsClient {
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	return &NowPaymentsClient{
+		BaseURL:    strings.TrimRight(baseURL, "/"),
+		APIKey:     apiKey,
+		HTTPClient: httpclient.New("nowpayments", timeout),
+		Timeout:    timeout,
+	}
+}
+
+func (c *NowPaymentsClient) Name() string { return "nowpayments" }
+
+// nowPaymentsNumber accepts amounts and IDs sent either as JSON numbers or
+// as strings, which NOWPayments mixes between endpoints
+type nowPaymentsNumber string
+
+func (n *nowPaymentsNumber) UnmarshalJSON(b []byte) error {
+	s := strings.Trim(string(b), `"`)
+	if s == "null" {
+		s = ""
+	}
+	*n = nowPaymentsNumber(s)
+	return nil
+}
+
+func (n nowPaymentsNumber) String() string { return string(n) }
+
+// NowPaymentsCurrency returns the NOWPayments ticker for a coin on a network,
+// e.g. BNB on BSC is "bnbbsc"
+func NowPaymentsCurrency(coin, network string) string {
+	coin = strings.ToLower(strings.TrimSpace(coin))
+	switch strings.ToUpper(strings.TrimSpace(network)) {
+	case "BSC", "BEP20", "BEP-20":
+		if coin != "bnb" {
+			return coin + "bsc"
+		}
+		return "bnbbsc"
+	case "BASE":
+		return coin + "base"
+	case "ARBITRUM":
+		return coin + "arb"
+	default:
+		return coin
+	}
+}
+
+// Quote via the Wallex USDT rate and GET /estimate
+
+type nowPaymentsEstimateResp struct {
+	CurrencyFrom    string            `json:"currency_from"`
+	AmountFrom      nowPaymentsNumber `json:"amount_from"`
+	CurrencyTo      string            `json:"currency_to"`
+	EstimatedAmount nowPaymentsNumber `json:"estimated_amount"`
+}
+
+func (c *NowPaymentsClient) GetQuote(ctx context.Context, in QuoteInput) (*QuoteResult, error) {
+	amountUSD, err := c.tomanToUSD(ctx, in.FiatAmountToman)
+	if err != nil {
+		return &QuoteResult{RateSource: "wallex:error"}, nil
+	}
+	q := url.Values{}
+	q.Set("amount", formatUSD(amountUSD))
+	q.Set("currency_from", "usd")
+	q.Set("currency_to", NowPaymentsCurrency(in.Coin, in.Network))
+	var out nowPaymentsEstimateResp
+	if err := c.doJSON(ctx, http.MethodGet, "/estimate?"+q.Encode(), nil, &out); err != nil {
+		return &QuoteResult{RateSource: "nowpayments:error"}, nil
+	}
+	rate := ""
+	if coins, err := strconv.ParseFloat(out.EstimatedAmount.String(), 64); err == nil && coins > 0 {
+		// USD per 1 coin, like the USDT prices of the other providers
+		rate = strconv.FormatFloat(amountUSD/coins, 'f', -1, 64)
+	}
+	exp := time.Now().Add(1 * time.Minute)
+	return &QuoteResult{
+		ExpectedCoinAmount: out.EstimatedAmount.String(),
+		ExchangeRate:       rate,
+		RateSource:         "nowpayments:estimate",
+		ExpiresAt:          &exp,
+	}, nil
+}
+
+// Provision via POST /payment, which returns the address the customer pays to
+
+type nowPaymentsPaymentReq struct {
+	PriceAmount      float64 `json:"price_amount"`
+	PriceCurrency    string  `json:"price_currency"`
+	PayCurrency      string  `json:"pay_currency"`
+	IPNCallbackURL   string  `json:"ipn_callback_url,omitempty"`
+	OrderID          string  `json:"order_id"`
+	OrderDescription string  `json:"order_description,omitempty"`
+	IsFeePaidByUser  bool    `json:"is_fee_paid_by_user"`
+}
+
+// NowPaymentsPayment is a payment as returned by GET /payment/{id} and sent
+// in IPN callbacks (subset based on docs)
+type NowPaymentsPayment struct {
+	PaymentID              nowPaymentsNumber `json:"payment_id"`
+	InvoiceID              nowPaymentsNumber `json:"invoice_id"`
+	PaymentStatus          string            `json:"payment_status"`
+	PayAddress             string            `json:"pay_address"`
+	PayinExtraID           string            `json:"payin_extra_id"`
+	PriceAmount            nowPaymentsNumber `json:"price_amount"`
+	PriceCurrency          string            `json:"price_currency"`
+	PayAmount              nowPaymentsNumber `json:"pay_amount"`
+	ActuallyPaid           nowPaymentsNumber `json:"actually_paid"`
+	PayCurrency            string            `json:"pay_currency"`
+	OrderID                string            `json:"order_id"`
+	OrderDescription       string            `json:"order_description"`
+	PayinHash              string            `json:"payin_hash"`
+	UpdatedAt              nowPaymentsNumber `json:"updated_at"` // RFC 3339 when polled, unix ms in IPNs
+	ExpirationEstimateDate string            `json:"expiration_estimate_date"`
+}
+
+func (c *NowPaymentsClient) ProvisionDeposit(ctx context.Context, in ProvisionInput) (*ProvisionResult, error) {
+	amountUSD, err := c.tomanToUSD(ctx, in.FiatAmountToman)
+	if err != nil {
+		return nil, err
+	}
+	body := nowPaymentsPaymentReq{
+		PriceAmount:      amountUSD,
+		PriceCurrency:    "usd",
+		PayCurrency:      NowPaymentsCurrency(in.Coin, in.Network),
+		IPNCallbackURL:   in.CallbackURL,
+		OrderID:          in.Label,
+		OrderDescription: "deposit " + in.Label,
+		IsFeePaidByUser:  true,
+	}
+	var out NowPaymentsPayment
+	if err := c.doJSON(ctx, http.MethodPost, "/payment", body, &out); err != nil {
+		return nil, err
+	}
+	if out.PayAddress == "" || out.PaymentID == "" {
+		return nil, errors.New("nowpayments: empty payment response")
+	}
+	return &ProvisionResult{
+		DepositAddress:     out.PayAddress,
+		DepositMemo:        out.PayinExtraID,
+		ProviderRequestID:  out.PaymentID.String(),
+		ExpiresAt:          parseNowPaymentsTime(out.ExpirationEstimateDate),
+		ExpectedCoinAmount: out.PayAmount.String(),
+	}, nil
+}
+
+// GetPayment fetches a payment's status with GET /payment/{id}
+func (c *NowPaymentsClient) GetPayment(ctx context.Context, paymentID string) (*NowPaymentsPayment, error) {
+	if strings.TrimSpace(paymentID) == "" {
+		return nil, errors.New("nowpayments: empty payment_id")
+	}
+	var out NowPaymentsPayment
+	if err := c.doJSON(ctx, http.MethodGet, "/payment/"+url.PathEscape(paymentID), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetDeposits polls the payment. A payment has at most one deposit, reported
+// once the customer has sent funds.
+func (c *NowPaymentsClient) GetDeposits(ctx context.Context, providerRequestID string) ([]DepositInfo, error) {
+	payment, err := c.GetPayment(ctx, providerRequestID)
+	if err != nil {
+		return nil, err
+	}
+	dep := payment.Deposit()
+	if dep == nil {
+		return nil, nil
+	}
+	return []DepositInfo{*dep}, nil
+}
+
+// VerifyTx is not supported: NOWPayments looks payments up by payment_id only
+func (c *NowPaymentsClient) VerifyTx(ctx context.Context, txHash string) (*DepositInfo, error) {
+	return nil, errors.New("nowpayments: VerifyTx not supported; use IPN callbacks or payment status polling")
+}
+
+// Deposit maps the payment to a deposit, or nil while nothing has been paid.
+// The payin hash is not known until the transaction is seen on chain, so the
+// payment ID stands in for it until then.
+func (p NowPaymentsPayment) Deposit() *DepositInfo {
+	status := MapNowPaymentsStatus(p.PaymentStatus)
+	paid, _ := strconv.ParseFloat(p.ActuallyPaid.String(), 64)
+	if status == "" || paid <= 0 {
+		return nil
+	}
+	txHash := p.PayinHash
+	if txHash == "" {
+		txHash = "nowpayments:" + p.PaymentID.String()
+	}
+	dep := &DepositInfo{
+		TxHash:         txHash,
+		AmountCoin:     p.ActuallyPaid.String(),
+		ToAddress:      p.PayAddress,
+		DestinationTag: p.PayinExtraID,
+		Status:         status,
+		DetectedAt:     p.UpdatedTime(),
+	}
+	if status == "confirmed" {
+		now := time.Now().UTC()
+		dep.ConfirmedAt = &now
+	}
+	return dep
+}
+
+// UpdatedTime is when NOWPayments last updated the payment, nil when unknown
+func (p NowPaymentsPayment) UpdatedTime() *time.Time {
+	if ms, err := strconv.ParseInt(p.UpdatedAt.String(), 10, 64); err == nil && ms > 0 {
+		t := time.UnixMilli(ms).UTC()
+		return &t
+	}
+	return parseNowPaymentsTime(p.UpdatedAt.String())
+}
+
+// MapNowPaymentsStatus maps a payment_status to a deposit status. Funds are
+// only treated as confirmed once NOWPayments has finished the payment, or
+// closed it as partially paid; an empty result means nothing has been
+// received.
+func MapNowPaymentsStatus(s string) string {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "confirming", "confirmed", "sending":
+		return "detected"
+	case "finished", "partially_paid":
+		return "confirmed"
+	case "failed", "refunded":
+		return "failed"
+	default: // waiting, expired
+		return ""
+	}
+}
+
+// Invoice via POST /invoice: a hosted page where the customer may pick the coin
+
+type NowPaymentsInvoiceInput struct {
+	FiatAmountToman uint64
+	Coin            string
+	Network         string
+	CallbackURL     string
+	SuccessURL      string
+	CancelURL       string
+	Label           string // order_id
+	Description     string
+}
+
+type NowPaymentsInvoiceResult struct {
+	InvoiceID  string
+	PaymentURL string
+}
+
+type nowPaymentsInvoiceReq struct {
+	PriceAmount      float64 `json:"price_amount"`
+	PriceCurrency    string  `json:"price_currency"`
+	PayCurrency      string  `json:"pay_currency,omitempty"`
+	IPNCallbackURL   string  `json:"ipn_callback_url,omitempty"`
+	OrderID          string  `json:"order_id"`
+	OrderDescription string  `json:"order_description,omitempty"`
+	SuccessURL       string  `json:"success_url,omitempty"`
+	CancelURL        string  `json:"cancel_url,omitempty"`
+	IsFeePaidByUser  bool    `json:"is_fee_paid_by_user"`
+}
+
+type nowPaymentsInvoiceResp struct {
+	ID         nowPaymentsNumber `json:"id"`
+	OrderID    string            `json:"order_id"`
+	InvoiceURL string            `json:"invoice_url"`
+}
+
+func (c *NowPaymentsClient) CreateInvoice(ctx context.Context, in NowPaymentsInvoiceInput) (*NowPaymentsInvoiceResult, error) {
+	amountUSD, err := c.tomanToUSD(ctx, in.FiatAmountToman)
+	if err != nil {
+		return nil, err
+	}
+	body := nowPaymentsInvoiceReq{
+		PriceAmount:      amountUSD,
+		PriceCurrency:    "usd",
+		IPNCallbackURL:   in.CallbackURL,
+		OrderID:          in.Label,
+		OrderDescription: in.Description,
+		SuccessURL:       in.SuccessURL,
+		CancelURL:        in.CancelURL,
+		IsFeePaidByUser:  true,
+	}
+	if in.Coin != "" {
+		body.PayCurrency = NowPaymentsCurrency(in.Coin, in.Network)
+	}
+	var out nowPaymentsInvoiceResp
+	if err := c.doJSON(ctx, http.MethodPost, "/invoice", body, &out); err != nil {
+		return nil, err
+	}
+	if out.InvoiceURL == "" || out.ID == "" {
+		return nil, errors.New("nowpayments: empty invoice response")
+	}
+	return &NowPaymentsInvoiceResult{InvoiceID: out.ID.String(), PaymentURL: out.InvoiceURL}, nil
+}
+
+// VerifyNowPaymentsIPN checks the x-nowpayments-sig header: an HMAC-SHA512,
+// keyed with the IPN secret, of the body re-serialized with its keys sorted
+func VerifyNowPaymentsIPN(raw []byte, signature, secret string) bool {
+	if secret == "" || signature == "" {
+		return false
+	}
+	sorted, err := sortedJSON(raw)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha512.New, []byte(secret))
+	mac.Write(sorted)
+	expected := hex.EncodeToString(mac.Sum(nil))
+	return hmac.Equal([]byte(expected), []byte(strings.ToLower(signature)))
+}
+
+// sortedJSON re-encodes a JSON document compactly with object keys sorted at
+// every level, keeping numbers as sent, like JSON.stringify on sorted keys
+func sortedJSON(raw []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	// encoding/json writes map keys in sorted order
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// HTTP helpers
+func (c *NowPaymentsClient) doJSON(ctx context.Context, method, path string, payload any, out any) error {
+	var body *bytes.Reader
+	if payload != nil {
+		b, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(b)
+	} else {
+		body = bytes.NewReader(nil)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+path, body)
+	if err != nil {
+		return err
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("x-api-key", c.APIKey)
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		var e struct {
+			Message string `json:"message"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&e)
+		if e.Message != "" {
+			return fmt.Errorf("nowpayments: status %d for %s: %s", resp.StatusCode, strings.SplitN(path, "?", 2)[0], e.Message)
+		}
+		return fmt.Errorf("nowpayments: status %d for %s", resp.StatusCode, strings.SplitN(path, "?", 2)[0])
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func (c *NowPaymentsClient) tomanToUSD(ctx context.Context, toman uint64) (float64, error) {
+	priceTMNStr, err := c.fetchWallexPrice(ctx, "USDTTMN")
+	if err != nil {
+		return 0, fmt.Errorf("wallex usdttmn: %w", err)
+	}
+	priceTMN, err := strconv.ParseFloat(priceTMNStr, 64)
+	if err != nil || priceTMN <= 0 {
+		return 0, fmt.Errorf("invalid usdt/tmn price")
+	}
+	return math.Round(float64(toman)/priceTMN*100) / 100, nil
+}
+
+func (c *NowPaymentsClient) fetchWallexPrice(ctx context.Context, pair string) (string, error) {
+	url := "https://api.wallex.ir/v1/trades?symbol=" + strings.ToLower(pair)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("wallex: http %d", resp.StatusCode)
+	}
+	var wr wallexTradesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&wr); err != nil {
+		return "", err
+	}
+	if !wr.Success || len(wr.Result.LatestTrades) == 0 {
+		return "", errors.New("wallex: empty trades")
+	}
+	return wr.Result.LatestTrades[0].Price, nil
+}
+
+func formatUSD(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
+
+func parseNowPaymentsTime(s string) *time.Time {
+	if s == "" {
+		return nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return nil
+	}
+	t = t.UTC()
+	return &t
+}
@@ -0,0 +1,143 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// nowPaymentsTestClient answers Wallex and NOWPayments requests with canned
+// bodies keyed by method and path, and records the requests it received
+func nowPaymentsTestClient(t *testing.T, responses map[string]string) (*NowPaymentsClient, *[]*http.Request) {
+	t.Helper()
+	var requests []*http.Request
+	client := NewNowPaymentsClient("https://api.nowpayments.io/v1/", "np-key", 0)
+	client.HTTPClient = &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if req.Body != nil {
+			body, _ := io.ReadAll(req.Body)
+			req.Body = io.NopCloser(strings.NewReader(string(body)))
+		}
+		requests = append(requests, req)
+		key := req.Method + " " + req.URL.Path
+		if req.URL.Host == "api.wallex.ir" {
+			key = "wallex"
+		}
+		body, ok := responses[key]
+		if !ok {
+			return &http.Response{StatusCode: http.StatusNotFound, Body: io.NopCloser(strings.NewReader(`{"message":"not found"}`))}, nil
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(body))}, nil
+	})}
+	return client, &requests
+}
+
+const wallexUSDTTMN = `{"success":true,"result":{"latestTrades":[{"symbol":"USDTTMN","price":"100000"}]}}`
+
+func TestNowPaymentsProvisionDeposit(t *testing.T) {
+	client, requests := nowPaymentsTestClient(t, map[string]string{
+		"wallex": wallexUSDTTMN,
+		"POST /v1/payment": `{"payment_id":5745459419,"payment_status":"waiting","pay_address":"bnb1addr",` +
+			`"payin_extra_id":"8812","pay_amount":0.0415,"pay_currency":"bnbbsc","order_id":"req-1",` +
+			`"expiration_estimate_date":"2026-10-17T12:30:00.000Z"}`,
+	})
+
+	res, err := client.ProvisionDeposit(context.Background(), ProvisionInput{
+		QuoteInput:  QuoteInput{FiatAmountToman: 2_500_000, Coin: "BNB", Network: "BSC"},
+		Label:       "req-1",
+		CallbackURL: "https://api.example.com/api/v1/crypto/providers/nowpayments/callback",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "bnb1addr", res.DepositAddress)
+	assert.Equal(t, "8812", res.DepositMemo)
+	assert.Equal(t, "5745459419", res.ProviderRequestID)
+	require.NotNil(t, res.ExpiresAt)
+
+	req := (*requests)[len(*requests)-1]
+	assert.Equal(t, "np-key", req.Header.Get("x-api-key"))
+	var body map[string]any
+	require.NoError(t, json.NewDecoder(req.Body).Decode(&body))
+	assert.Equal(t, 25.0, body["price_amount"])
+	assert.Equal(t, "usd", body["price_currency"])
+	assert.Equal(t, "bnbbsc", body["pay_currency"])
+	assert.Equal(t, "req-1", body["order_id"])
+	assert.Equal(t, "https://api.example.com/api/v1/crypto/providers/nowpayments/callback", body["ipn_callback_url"])
+}
+
+func TestNowPaymentsGetDeposits(t *testing.T) {
+	tests := []struct {
+		name       string
+		payment    string
+		wantTxHash string
+		wantStatus string
+		confirmed  bool
+	}{
+		{"waiting", `{"payment_id":1,"payment_status":"waiting","actually_paid":0}`, "", "", false},
+		{"confirming without a hash", `{"payment_id":1,"payment_status":"confirming","actually_paid":0.0415,"pay_address":"addr"}`,
+			"nowpayments:1", "detected", false},
+		{"finished", `{"payment_id":1,"payment_status":"finished","actually_paid":"0.0415","payin_hash":"0xabc","updated_at":"2026-10-17T12:10:00.000Z"}`,
+			"0xabc", "confirmed", true},
+		{"partially paid", `{"payment_id":1,"payment_status":"partially_paid","actually_paid":0.0415,"payin_hash":"0xdef"}`,
+			"0xdef", "confirmed", true},
+		{"expired", `{"payment_id":1,"payment_status":"expired","actually_paid":0}`, "", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, _ := nowPaymentsTestClient(t, map[string]string{"GET /v1/payment/1": tt.payment})
+			deps, err := client.GetDeposits(context.Background(), "1")
+			require.NoError(t, err)
+			if tt.wantTxHash == "" {
+				assert.Empty(t, deps)
+				return
+			}
+			require.Len(t, deps, 1)
+			assert.Equal(t, tt.wantTxHash, deps[0].TxHash)
+			assert.Equal(t, "0.0415", deps[0].AmountCoin)
+			assert.Equal(t, tt.wantStatus, deps[0].Status)
+			assert.Equal(t, tt.confirmed, deps[0].ConfirmedAt != nil)
+		})
+	}
+}
+
+func TestNowPaymentsGetQuote(t *testing.T) {
+	client, requests := nowPaymentsTestClient(t, map[string]string{
+		"wallex":           wallexUSDTTMN,
+		"GET /v1/estimate": `{"currency_from":"usd","amount_from":25,"currency_to":"eth","estimated_amount":"0.01"}`,
+	})
+
+	quote, err := client.GetQuote(context.Background(), QuoteInput{FiatAmountToman: 2_500_000, Coin: "ETH", Network: "ERC20"})
+	require.NoError(t, err)
+	assert.Equal(t, "0.01", quote.ExpectedCoinAmount)
+	assert.Equal(t, "2500", quote.ExchangeRate)
+	assert.Equal(t, "nowpayments:estimate", quote.RateSource)
+	q := (*requests)[len(*requests)-1].URL.Query()
+	assert.Equal(t, "25.00", q.Get("amount"))
+	assert.Equal(t, "eth", q.Get("currency_to"))
+}
+
+func TestVerifyNowPaymentsIPN(t *testing.T) {
+	secret := "ipn-secret"
+	raw := []byte(`{"payment_status":"finished","payment_id":5077125051,"order_id":"a&b",` +
+		`"fee":{"currency":"btc","depositFee":0.0000123,"withdrawalFee":0},"actually_paid":0.00021}`)
+	// The body with keys sorted at every level, as NOWPayments signs it
+	sorted := `{"actually_paid":0.00021,"fee":{"currency":"btc","depositFee":0.0000123,"withdrawalFee":0},` +
+		`"order_id":"a&b","payment_id":5077125051,"payment_status":"finished"}`
+	mac := hmac.New(sha512.New, []byte(secret))
+	mac.Write([]byte(sorted))
+	sig := hex.EncodeToString(mac.Sum(nil))
+
+	assert.True(t, VerifyNowPaymentsIPN(raw, sig, secret))
+	assert.True(t, VerifyNowPaymentsIPN(raw, strings.ToUpper(sig), secret))
+	assert.False(t, VerifyNowPaymentsIPN(raw, sig, "other-secret"))
+	assert.False(t, VerifyNowPaymentsIPN([]byte(strings.Replace(string(raw), "finished", "failed", 1)), sig, secret))
+	assert.False(t, VerifyNowPaymentsIPN(raw, "", secret))
+	assert.False(t, VerifyNowPaymentsIPN([]byte("not json"), sig, secret))
+}
@@ -11,6 +11,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/amirphl/Yamata-no-Orochi/app/httpclient"
 	"github.com/amirphl/Yamata-no-Orochi/utils"
 )
 
@@ -28,7 +29,7 @@ func NewOxapayClient(baseURL, apiKey string, timeout time.Duration) *OxapayClien
 	return &OxapayClient{
 		BaseURL:    strings.TrimRight(baseURL, "/"),
 		APIKey:     apiKey,
-		HTTPClient: &http.Client{Timeout: timeout},
+		HTTPClient: httpclient.New("oxapay", timeout),
 		Timeout:    timeout,
 	}
 }
@@ -157,6 +158,22 @@ func (c *OxapayClient) GetDeposits(ctx context.Context, providerRequestID string
 	return nil, nil
 }
 
+// ReleaseDeposit revokes a static address so it stops accepting payments
+// (POST /payment/static-address/revoke)
+func (c *OxapayClient) ReleaseDeposit(ctx context.Context, address, providerRequestID string) error {
+	if address == "" {
+		return nil
+	}
+	var env oxapayEnvelope
+	if err := c.postMerchantJSON(ctx, "/payment/static-address/revoke", map[string]string{"address": address}, &env); err != nil {
+		return err
+	}
+	if env.Status != 0 && env.Status != http.StatusOK {
+		return fmt.Errorf("oxapay: revoke static address: %s", env.Message)
+	}
+	return nil
+}
+
 func (c *OxapayClient) VerifyTx(ctx context.Context, txHash string) (*DepositInfo, error) {
 	return nil, errors.New("oxapay: VerifyTx not implemented; use webhook or history endpoint mapping")
 }
@@ -231,6 +248,7 @@ type OxapayInvoiceResult struct {
 	TrackID    string
 	PaymentURL string
 	ExpiredAt  *time.Time
+	AmountUSDT float64
 }
 
 // request/response for invoice endpoint
@@ -313,7 +331,7 @@ func (c *OxapayClient) CreateInvoice(ctx context.Context, in OxapayInvoiceInput)
 		t := time.Unix(env.Data.ExpiredAt, 0).UTC()
 		exp = &t
 	}
-	return &OxapayInvoiceResult{TrackID: env.Data.TrackID, PaymentURL: env.Data.PaymentURL, ExpiredAt: exp}, nil
+	return &OxapayInvoiceResult{TrackID: env.Data.TrackID, PaymentURL: env.Data.PaymentURL, ExpiredAt: exp, AmountUSDT: amountUSDT}, nil
 }
 
 // wallex trades for USDTIRR (shared helper based on bithide client)
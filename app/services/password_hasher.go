@@ -0,0 +1,175 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Password hash algorithms as reported by PasswordHashAlgorithm
+const (
+	PasswordHashArgon2id = "argon2id"
+	PasswordHashBcrypt   = "bcrypt"
+	PasswordHashUnknown  = "unknown"
+)
+
+// ErrInvalidPasswordHash is returned when a stored hash cannot be decoded
+var ErrInvalidPasswordHash = errors.New("invalid password hash")
+
+// PasswordHasher hashes passwords for storage and verifies them. New hashes
+// are Argon2id; bcrypt hashes stored before it are still verified.
+type PasswordHasher interface {
+	Hash(password string) (string, error)
+	// Verify reports whether password matches encodedHash
+	Verify(password, encodedHash string) (bool, error)
+	// NeedsRehash reports whether encodedHash uses another algorithm or other
+	// parameters than new hashes, so it should be replaced after a successful
+	// verification
+	NeedsRehash(encodedHash string) bool
+}
+
+// Argon2idParams are the cost parameters of new Argon2id hashes
+type Argon2idParams struct {
+	Memory      uint32 // KiB
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+// DefaultArgon2idParams returns 64 MiB, 3 passes, and 2 lanes with a 16 byte
+// salt and a 32 byte key
+func DefaultArgon2idParams() Argon2idParams {
+	return Argon2idParams{
+		Memory:      64 * 1024,
+		Iterations:  3,
+		Parallelism: 2,
+		SaltLength:  16,
+		KeyLength:   32,
+	}
+}
+
+type argon2idPasswordHasher struct {
+	params Argon2idParams
+}
+
+// NewArgon2idPasswordHasher creates a password hasher; zero parameters take
+// their defaults
+func NewArgon2idPasswordHasher(params Argon2idParams) PasswordHasher {
+	defaults := DefaultArgon2idParams()
+	if params.Memory == 0 {
+		params.Memory = defaults.Memory
+	}
+	if params.Iterations == 0 {
+		params.Iterations = defaults.Iterations
+	}
+	if params.Parallelism == 0 {
+		params.Parallelism = defaults.Parallelism
+	}
+	if params.SaltLength == 0 {
+		params.SaltLength = defaults.SaltLength
+	}
+	if params.KeyLength == 0 {
+		params.KeyLength = defaults.KeyLength
+	}
+	return &argon2idPasswordHasher{params: params}
+}
+
+// Hash returns the password in the PHC string format
+// $argon2id$v=19$m=<memory>,t=<iterations>,p=<parallelism>$<salt>$<key>
+func (h *argon2idPasswordHasher) Hash(password string) (string, error) {
+	salt := make([]byte, h.params.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("generate salt: %w", err)
+	}
+	key := argon2.IDKey([]byte(password), salt, h.params.Iterations, h.params.Memory, h.params.Parallelism, h.params.KeyLength)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, h.params.Memory, h.params.Iterations, h.params.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+func (h *argon2idPasswordHasher) Verify(password, encodedHash string) (bool, error) {
+	switch PasswordHashAlgorithm(encodedHash) {
+	case PasswordHashArgon2id:
+		params, salt, key, err := decodeArgon2idHash(encodedHash)
+		if err != nil {
+			return false, err
+		}
+		actual := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, params.KeyLength)
+		return subtle.ConstantTimeCompare(actual, key) == 1, nil
+	case PasswordHashBcrypt:
+		err := bcrypt.CompareHashAndPassword([]byte(encodedHash), []byte(password))
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		return true, nil
+	default:
+		return false, ErrInvalidPasswordHash
+	}
+}
+
+func (h *argon2idPasswordHasher) NeedsRehash(encodedHash string) bool {
+	if PasswordHashAlgorithm(encodedHash) != PasswordHashArgon2id {
+		return true
+	}
+	params, _, _, err := decodeArgon2idHash(encodedHash)
+	if err != nil {
+		return true
+	}
+	return params != h.params
+}
+
+// PasswordHashAlgorithm names the algorithm of a stored password hash
+func PasswordHashAlgorithm(encodedHash string) string {
+	switch {
+	case strings.HasPrefix(encodedHash, "$argon2id$"):
+		return PasswordHashArgon2id
+	case strings.HasPrefix(encodedHash, "$2a$"),
+		strings.HasPrefix(encodedHash, "$2b$"),
+		strings.HasPrefix(encodedHash, "$2y$"):
+		return PasswordHashBcrypt
+	default:
+		return PasswordHashUnknown
+	}
+}
+
+func decodeArgon2idHash(encodedHash string) (Argon2idParams, []byte, []byte, error) {
+	parts := strings.Split(encodedHash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return Argon2idParams{}, nil, nil, ErrInvalidPasswordHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return Argon2idParams{}, nil, nil, ErrInvalidPasswordHash
+	}
+
+	var params Argon2idParams
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil ||
+		params.Iterations == 0 || params.Parallelism == 0 {
+		return Argon2idParams{}, nil, nil, ErrInvalidPasswordHash
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return Argon2idParams{}, nil, nil, ErrInvalidPasswordHash
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return Argon2idParams{}, nil, nil, ErrInvalidPasswordHash
+	}
+	params.SaltLength = uint32(len(salt))
+	params.KeyLength = uint32(len(key))
+	return params, salt, key, nil
+}
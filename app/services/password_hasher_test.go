@@ -0,0 +1,89 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func testArgon2idParams() Argon2idParams {
+	return Argon2idParams{Memory: 1024, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+}
+
+func TestArgon2idPasswordHasherHashAndVerify(t *testing.T) {
+	hasher := NewArgon2idPasswordHasher(testArgon2idParams())
+
+	hash, err := hasher.Hash("SecurePass123!")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(hash, "$argon2id$v=19$m=1024,t=1,p=1$"))
+	assert.Equal(t, PasswordHashArgon2id, PasswordHashAlgorithm(hash))
+
+	ok, err := hasher.Verify("SecurePass123!", hash)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = hasher.Verify("WrongPass123!", hash)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	other, err := hasher.Hash("SecurePass123!")
+	require.NoError(t, err)
+	assert.NotEqual(t, hash, other, "hashes must be salted")
+
+	assert.False(t, hasher.NeedsRehash(hash))
+}
+
+func TestArgon2idPasswordHasherVerifiesBcrypt(t *testing.T) {
+	hasher := NewArgon2idPasswordHasher(testArgon2idParams())
+
+	legacy, err := bcrypt.GenerateFromPassword([]byte("SecurePass123!"), bcrypt.MinCost)
+	require.NoError(t, err)
+	assert.Equal(t, PasswordHashBcrypt, PasswordHashAlgorithm(string(legacy)))
+
+	ok, err := hasher.Verify("SecurePass123!", string(legacy))
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = hasher.Verify("WrongPass123!", string(legacy))
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	assert.True(t, hasher.NeedsRehash(string(legacy)))
+}
+
+func TestArgon2idPasswordHasherNeedsRehashOnParamChange(t *testing.T) {
+	oldParams := testArgon2idParams()
+	hash, err := NewArgon2idPasswordHasher(oldParams).Hash("SecurePass123!")
+	require.NoError(t, err)
+
+	newParams := oldParams
+	newParams.Iterations = 2
+	hasher := NewArgon2idPasswordHasher(newParams)
+	assert.True(t, hasher.NeedsRehash(hash))
+
+	// The old hash still verifies with its own parameters
+	ok, err := hasher.Verify("SecurePass123!", hash)
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestArgon2idPasswordHasherRejectsMalformedHashes(t *testing.T) {
+	hasher := NewArgon2idPasswordHasher(testArgon2idParams())
+
+	for _, hash := range []string{
+		"",
+		"plaintext",
+		"$argon2id$v=19$m=1024,t=1,p=1$not-base64!$abc",
+		"$argon2id$v=18$m=1024,t=1,p=1$c2FsdA$a2V5",
+		"$argon2id$v=19$m=1024$c2FsdA$a2V5",
+		"$argon2id$v=19$m=1024,t=1,p=0$c2FsdA$a2V5",
+	} {
+		ok, err := hasher.Verify("SecurePass123!", hash)
+		assert.False(t, ok, hash)
+		assert.ErrorIs(t, err, ErrInvalidPasswordHash, hash)
+		assert.True(t, hasher.NeedsRehash(hash), hash)
+	}
+}
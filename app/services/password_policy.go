@@ -0,0 +1,214 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/httpclient"
+	"github.com/redis/go-redis/v9"
+)
+
+// Reasons PasswordPolicy refuses a password for
+const (
+	PasswordTooShort      = "too_short"
+	PasswordTooLong       = "too_long"
+	PasswordMissingUpper  = "missing_upper"
+	PasswordMissingLower  = "missing_lower"
+	PasswordMissingNumber = "missing_number"
+	PasswordMissingSymbol = "missing_symbol"
+	PasswordBreached      = "breached"
+)
+
+// PasswordRules are the composition rules of new passwords. Lengths count
+// characters, not bytes.
+type PasswordRules struct {
+	MinLength     int
+	MaxLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireNumber bool
+	RequireSymbol bool
+}
+
+// PasswordPolicy decides whether a new password may be used
+type PasswordPolicy interface {
+	// Check returns the reasons password is refused, in a stable order, or
+	// none when it is acceptable
+	Check(ctx context.Context, password string) []string
+	Rules() PasswordRules
+}
+
+// BreachChecker reports whether a password appeared in a known data breach
+type BreachChecker interface {
+	Breached(ctx context.Context, password string) (bool, error)
+}
+
+type passwordPolicy struct {
+	rules    PasswordRules
+	breaches BreachChecker
+}
+
+// NewPasswordPolicy creates a policy enforcing rules. With a nil breaches
+// checker breached passwords are not looked up; when the lookup fails the
+// password is accepted so an unreachable service never blocks signups.
+func NewPasswordPolicy(rules PasswordRules, breaches BreachChecker) PasswordPolicy {
+	return &passwordPolicy{rules: rules, breaches: breaches}
+}
+
+func (p *passwordPolicy) Rules() PasswordRules {
+	return p.rules
+}
+
+func (p *passwordPolicy) Check(ctx context.Context, password string) []string {
+	var reasons []string
+	length := utf8.RuneCountInString(password)
+	if length < p.rules.MinLength {
+		reasons = append(reasons, PasswordTooShort)
+	}
+	if p.rules.MaxLength > 0 && length > p.rules.MaxLength {
+		reasons = append(reasons, PasswordTooLong)
+	}
+
+	var hasUpper, hasLower, hasNumber, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasNumber = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			hasSymbol = true
+		}
+	}
+	if p.rules.RequireUpper && !hasUpper {
+		reasons = append(reasons, PasswordMissingUpper)
+	}
+	if p.rules.RequireLower && !hasLower {
+		reasons = append(reasons, PasswordMissingLower)
+	}
+	if p.rules.RequireNumber && !hasNumber {
+		reasons = append(reasons, PasswordMissingNumber)
+	}
+	if p.rules.RequireSymbol && !hasSymbol {
+		reasons = append(reasons, PasswordMissingSymbol)
+	}
+
+	// Only look up passwords that pass the other rules; a refused password
+	// is not worth a request
+	if len(reasons) == 0 && p.breaches != nil {
+		breached, err := p.breaches.Breached(ctx, password)
+		if err != nil {
+			log.Printf("password breach check failed: %v", err)
+		} else if breached {
+			reasons = append(reasons, PasswordBreached)
+		}
+	}
+	return reasons
+}
+
+// PwnedPasswordsClient checks passwords against the Have I Been Pwned range
+// API. Only the first 5 hex characters of the password's SHA-1 leave the
+// process (k-anonymity), and range responses are cached in Redis so repeated
+// prefixes skip the request.
+type PwnedPasswordsClient struct {
+	BaseURL    string
+	HTTPClient *http.Client
+	rc         *redis.Client
+	cacheTTL   time.Duration
+}
+
+// NewPwnedPasswordsClient creates a client for the range API at baseURL; with
+// a nil Redis client or a zero TTL nothing is cached
+func NewPwnedPasswordsClient(baseURL string, timeout time.Duration, rc *redis.Client, cacheTTL time.Duration) *PwnedPasswordsClient {
+	return &PwnedPasswordsClient{
+		BaseURL:    strings.TrimRight(baseURL, "/"),
+		HTTPClient: httpclient.New("pwnedpasswords", timeout),
+		rc:         rc,
+		cacheTTL:   cacheTTL,
+	}
+}
+
+func (c *PwnedPasswordsClient) Breached(ctx context.Context, password string) (bool, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:5], hash[5:]
+
+	body, err := c.rangeBody(ctx, prefix)
+	if err != nil {
+		return false, err
+	}
+	return pwnedRangeContains(body, suffix), nil
+}
+
+func (c *PwnedPasswordsClient) rangeBody(ctx context.Context, prefix string) (string, error) {
+	if c.rc != nil && c.cacheTTL > 0 {
+		body, err := c.rc.Get(ctx, pwnedRangeKey(prefix)).Result()
+		if err == nil {
+			return body, nil
+		}
+		if !errors.Is(err, redis.Nil) {
+			log.Printf("pwned passwords cache read for %s failed: %v", prefix, err)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.BaseURL+"/range/"+prefix, nil)
+	if err != nil {
+		return "", err
+	}
+	// Padding hides the real number of suffixes sharing the prefix
+	req.Header.Set("Add-Padding", "true")
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("pwned passwords range %s: status %d", prefix, resp.StatusCode)
+	}
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+	if err != nil {
+		return "", err
+	}
+	body := string(raw)
+
+	if c.rc != nil && c.cacheTTL > 0 {
+		if err := c.rc.Set(ctx, pwnedRangeKey(prefix), body, c.cacheTTL).Err(); err != nil {
+			log.Printf("pwned passwords cache write for %s failed: %v", prefix, err)
+		}
+	}
+	return body, nil
+}
+
+// pwnedRangeContains reports whether a range response lists suffix with a
+// non-zero count; padding entries have a count of 0
+func pwnedRangeContains(body, suffix string) bool {
+	scanner := bufio.NewScanner(strings.NewReader(body))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		hashSuffix, count, ok := strings.Cut(line, ":")
+		if !ok || !strings.EqualFold(hashSuffix, suffix) {
+			continue
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(count))
+		return err == nil && n > 0
+	}
+	return false
+}
+
+func pwnedRangeKey(prefix string) string {
+	return "password:pwned:" + prefix
+}
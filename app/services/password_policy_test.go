@@ -0,0 +1,98 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeBreachChecker struct {
+	breached bool
+	err      error
+	calls    int
+}
+
+func (f *fakeBreachChecker) Breached(context.Context, string) (bool, error) {
+	f.calls++
+	return f.breached, f.err
+}
+
+func TestPasswordPolicyCheck(t *testing.T) {
+	rules := PasswordRules{MinLength: 8, MaxLength: 20, RequireUpper: true, RequireLower: true, RequireNumber: true, RequireSymbol: true}
+	tests := []struct {
+		name     string
+		password string
+		want     []string
+	}{
+		{"acceptable", "Secure#Pass1", nil},
+		{"too short", "Se#1a", []string{PasswordTooShort}},
+		{"too long", "Secure#Pass1Secure#Pass1", []string{PasswordTooLong}},
+		{"missing classes", "securepassword", []string{PasswordMissingUpper, PasswordMissingNumber, PasswordMissingSymbol}},
+		{"lengths count characters", "رمزعبورامنA#1x", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := NewPasswordPolicy(rules, nil)
+			assert.Equal(t, tt.want, policy.Check(context.Background(), tt.password))
+		})
+	}
+}
+
+func TestPasswordPolicyBreachCheck(t *testing.T) {
+	rules := PasswordRules{MinLength: 8, RequireNumber: true}
+
+	breached := &fakeBreachChecker{breached: true}
+	policy := NewPasswordPolicy(rules, breached)
+	assert.Equal(t, []string{PasswordBreached}, policy.Check(context.Background(), "password1"))
+
+	// A password refused by the rules is not looked up
+	assert.Equal(t, []string{PasswordTooShort}, policy.Check(context.Background(), "pass1"))
+	assert.Equal(t, 1, breached.calls)
+
+	// An unreachable breach service does not block the password
+	down := NewPasswordPolicy(rules, &fakeBreachChecker{err: errors.New("down")})
+	assert.Empty(t, down.Check(context.Background(), "password1"))
+}
+
+func TestPwnedPasswordsClientBreached(t *testing.T) {
+	// SHA-1 of "password" is 5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8
+	var requests []string
+	client := NewPwnedPasswordsClient("https://pwned.test/", time.Second, nil, 0)
+	client.HTTPClient = &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		requests = append(requests, req.URL.String())
+		assert.Equal(t, "true", req.Header.Get("Add-Padding"))
+		body := "0018A45C4D1DEF81644B54AB7F969B88D65:1\r\n1E4C9B93F3F0682250B6CF8331B7EE68FD8:3861493\r\nFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFF:0\r\n"
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(body)), Header: make(http.Header)}, nil
+	})}
+
+	breached, err := client.Breached(context.Background(), "password")
+	require.NoError(t, err)
+	assert.True(t, breached)
+	assert.Equal(t, []string{"https://pwned.test/range/5BAA6"}, requests)
+
+	breached, err = client.Breached(context.Background(), "a-password-nobody-used")
+	require.NoError(t, err)
+	assert.False(t, breached)
+}
+
+func TestPwnedRangeContainsIgnoresPadding(t *testing.T) {
+	body := "1E4C9B93F3F0682250B6CF8331B7EE68FD8:0\n"
+	assert.False(t, pwnedRangeContains(body, "1E4C9B93F3F0682250B6CF8331B7EE68FD8"))
+	assert.True(t, pwnedRangeContains("1e4c9b93f3f0682250b6cf8331b7ee68fd8:2", "1E4C9B93F3F0682250B6CF8331B7EE68FD8"))
+}
+
+func TestPwnedPasswordsClientStatusError(t *testing.T) {
+	client := NewPwnedPasswordsClient("https://pwned.test", time.Second, nil, 0)
+	client.HTTPClient = &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusServiceUnavailable, Body: io.NopCloser(strings.NewReader("")), Header: make(http.Header)}, nil
+	})}
+	_, err := client.Breached(context.Background(), "password")
+	assert.Error(t, err)
+}
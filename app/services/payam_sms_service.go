@@ -14,6 +14,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/amirphl/Yamata-no-Orochi/app/httpclient"
 	"github.com/amirphl/Yamata-no-Orochi/config"
 	"github.com/amirphl/Yamata-no-Orochi/utils"
 )
@@ -56,33 +57,20 @@ func NewPayamSMSService(smsCfg *config.SMSConfig, payamCfg *config.PayamSMSConfi
 	return &PayamSMSSMSService{
 		smsConfig:   smsCfg,
 		payamConfig: payamCfg,
-		client: &http.Client{
-			Timeout: smsCfg.Timeout,
-		},
+		client:      httpclient.New("payamsms", smsCfg.Timeout),
 	}
 }
 
 func NewPayamSMSServiceWithHTTPSProxy(smsCfg *config.SMSConfig, payamCfg *config.PayamSMSConfig, proxyURL string) (SMSService, error) {
-	proxyURL = strings.TrimSpace(proxyURL)
-	if proxyURL == "" {
-		return NewPayamSMSService(smsCfg, payamCfg), nil
-	}
-
-	parsed, err := url.Parse(proxyURL)
+	client, err := httpclient.NewWithProxy("payamsms", smsCfg.Timeout, proxyURL)
 	if err != nil {
 		return nil, err
 	}
 
-	transport := http.DefaultTransport.(*http.Transport).Clone()
-	transport.Proxy = http.ProxyURL(parsed)
-
 	return &PayamSMSSMSService{
 		smsConfig:   smsCfg,
 		payamConfig: payamCfg,
-		client: &http.Client{
-			Timeout:   smsCfg.Timeout,
-			Transport: transport,
-		},
+		client:      client,
 	}, nil
 }
 
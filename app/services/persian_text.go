@@ -0,0 +1,249 @@
+package services
+
+import (
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// PDF fonts draw runes one after the other from left to right. Persian text
+// is therefore shaped here into the contextual presentation forms of its
+// letters and reordered visually before it is drawn.
+
+// persianForms holds the isolated, final, initial and medial presentation
+// forms of a letter; letters that only join to the previous one have no
+// initial and medial forms
+var persianForms = map[rune][4]rune{
+	'ا': {0xFE8D, 0xFE8E, 0, 0},
+	'آ': {0xFE81, 0xFE82, 0, 0},
+	'أ': {0xFE83, 0xFE84, 0, 0},
+	'إ': {0xFE87, 0xFE88, 0, 0},
+	'ؤ': {0xFE85, 0xFE86, 0, 0},
+	'ئ': {0xFE89, 0xFE8A, 0xFE8B, 0xFE8C},
+	'ب': {0xFE8F, 0xFE90, 0xFE91, 0xFE92},
+	'پ': {0xFB56, 0xFB57, 0xFB58, 0xFB59},
+	'ة': {0xFE93, 0xFE94, 0, 0},
+	'ت': {0xFE95, 0xFE96, 0xFE97, 0xFE98},
+	'ث': {0xFE99, 0xFE9A, 0xFE9B, 0xFE9C},
+	'ج': {0xFE9D, 0xFE9E, 0xFE9F, 0xFEA0},
+	'چ': {0xFB7A, 0xFB7B, 0xFB7C, 0xFB7D},
+	'ح': {0xFEA1, 0xFEA2, 0xFEA3, 0xFEA4},
+	'خ': {0xFEA5, 0xFEA6, 0xFEA7, 0xFEA8},
+	'د': {0xFEA9, 0xFEAA, 0, 0},
+	'ذ': {0xFEAB, 0xFEAC, 0, 0},
+	'ر': {0xFEAD, 0xFEAE, 0, 0},
+	'ز': {0xFEAF, 0xFEB0, 0, 0},
+	'ژ': {0xFB8A, 0xFB8B, 0, 0},
+	'س': {0xFEB1, 0xFEB2, 0xFEB3, 0xFEB4},
+	'ش': {0xFEB5, 0xFEB6, 0xFEB7, 0xFEB8},
+	'ص': {0xFEB9, 0xFEBA, 0xFEBB, 0xFEBC},
+	'ض': {0xFEBD, 0xFEBE, 0xFEBF, 0xFEC0},
+	'ط': {0xFEC1, 0xFEC2, 0xFEC3, 0xFEC4},
+	'ظ': {0xFEC5, 0xFEC6, 0xFEC7, 0xFEC8},
+	'ع': {0xFEC9, 0xFECA, 0xFECB, 0xFECC},
+	'غ': {0xFECD, 0xFECE, 0xFECF, 0xFED0},
+	'ف': {0xFED1, 0xFED2, 0xFED3, 0xFED4},
+	'ق': {0xFED5, 0xFED6, 0xFED7, 0xFED8},
+	'ك': {0xFED9, 0xFEDA, 0xFEDB, 0xFEDC},
+	'ک': {0xFB8E, 0xFB8F, 0xFB90, 0xFB91},
+	'گ': {0xFB92, 0xFB93, 0xFB94, 0xFB95},
+	'ل': {0xFEDD, 0xFEDE, 0xFEDF, 0xFEE0},
+	'م': {0xFEE1, 0xFEE2, 0xFEE3, 0xFEE4},
+	'ن': {0xFEE5, 0xFEE6, 0xFEE7, 0xFEE8},
+	'ه': {0xFEE9, 0xFEEA, 0xFEEB, 0xFEEC},
+	'و': {0xFEED, 0xFEEE, 0, 0},
+	'ي': {0xFEF1, 0xFEF2, 0xFEF3, 0xFEF4},
+	'ی': {0xFBFC, 0xFBFD, 0xFBFE, 0xFBFF},
+}
+
+// lamAlef holds the isolated and final forms of lam followed by an alef
+var lamAlef = map[rune][2]rune{
+	'ا': {0xFEFB, 0xFEFC},
+	'آ': {0xFEF5, 0xFEF6},
+	'أ': {0xFEF7, 0xFEF8},
+	'إ': {0xFEF9, 0xFEFA},
+}
+
+const (
+	formIsolated = iota
+	formFinal
+	formInitial
+	formMedial
+)
+
+// joinsForward reports whether a letter connects to the letter after it
+func joinsForward(r rune) bool {
+	forms, ok := persianForms[r]
+	return ok && forms[formInitial] != 0
+}
+
+// shapePersian replaces Persian letters with their contextual presentation
+// forms. A zero-width non-joiner breaks the join and is dropped.
+func shapePersian(s string) string {
+	in := []rune(s)
+	out := make([]rune, 0, len(in))
+	for i := 0; i < len(in); i++ {
+		r := in[i]
+		forms, ok := persianForms[r]
+		if !ok {
+			if r != '\u200c' {
+				out = append(out, r)
+			}
+			continue
+		}
+		prevJoins := i > 0 && joinsForward(in[i-1])
+		if r == 'ل' && i+1 < len(in) {
+			if lig, ok := lamAlef[in[i+1]]; ok {
+				if prevJoins {
+					out = append(out, lig[formFinal])
+				} else {
+					out = append(out, lig[formIsolated])
+				}
+				i++
+				continue
+			}
+		}
+		nextIsLetter := false
+		if i+1 < len(in) {
+			_, nextIsLetter = persianForms[in[i+1]]
+		}
+		nextJoins := forms[formInitial] != 0 && nextIsLetter
+		switch {
+		case prevJoins && nextJoins:
+			out = append(out, forms[formMedial])
+		case prevJoins:
+			out = append(out, forms[formFinal])
+		case nextJoins:
+			out = append(out, forms[formInitial])
+		default:
+			out = append(out, forms[formIsolated])
+		}
+	}
+	return string(out)
+}
+
+// isRTLRune reports whether r is written right to left. Persian digits and
+// separators are Arabic script too, but are laid out like numbers.
+func isRTLRune(r rune) bool {
+	return unicode.IsLetter(r) && unicode.In(r, unicode.Arabic)
+}
+
+// isLTRRune reports whether r is written left to right; digits, Persian ones
+// included, keep their order inside right-to-left text
+func isLTRRune(r rune) bool {
+	return unicode.IsLetter(r) && !isRTLRune(r) || unicode.IsDigit(r)
+}
+
+var mirroredRunes = map[rune]rune{'(': ')', ')': '(', '[': ']', ']': '[', '<': '>', '>': '<', '«': '»', '»': '«'}
+
+// visualOrder reorders a right-to-left line for left-to-right drawing:
+// runs of left-to-right text keep their order and everything else is
+// reversed. Neutral characters between two left-to-right runes, such as the
+// separators of a number or a date, stay with them.
+func visualOrder(s string) string {
+	in := []rune(s)
+	ltr := make([]bool, len(in))
+	for i, r := range in {
+		if isLTRRune(r) {
+			ltr[i] = true
+			continue
+		}
+		if isRTLRune(r) {
+			continue
+		}
+		prev, next := false, false
+		for j := i - 1; j >= 0; j-- {
+			if isLTRRune(in[j]) || isRTLRune(in[j]) {
+				prev = isLTRRune(in[j])
+				break
+			}
+		}
+		for j := i + 1; j < len(in); j++ {
+			if isLTRRune(in[j]) || isRTLRune(in[j]) {
+				next = isLTRRune(in[j])
+				break
+			}
+		}
+		ltr[i] = prev && next
+	}
+
+	var runs [][]rune
+	for i := 0; i < len(in); {
+		j := i
+		for j < len(in) && ltr[j] == ltr[i] {
+			j++
+		}
+		run := append([]rune(nil), in[i:j]...)
+		if !ltr[i] {
+			for a, b := 0, len(run)-1; a < b; a, b = a+1, b-1 {
+				run[a], run[b] = run[b], run[a]
+			}
+			for k, r := range run {
+				if m, ok := mirroredRunes[r]; ok {
+					run[k] = m
+				}
+			}
+		}
+		runs = append(runs, run)
+		i = j
+	}
+
+	var b strings.Builder
+	for i := len(runs) - 1; i >= 0; i-- {
+		b.WriteString(string(runs[i]))
+	}
+	return b.String()
+}
+
+// persianVisual shapes and reorders a right-to-left line for drawing
+func persianVisual(s string) string {
+	return visualOrder(shapePersian(s))
+}
+
+// persianDigits writes the ASCII digits of s as Persian digits
+func persianDigits(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return '۰' + (r - '0')
+		}
+		return r
+	}, s)
+}
+
+// persianAmount formats an amount with thousands separators in Persian digits
+func persianAmount(v uint64) string {
+	digits := []rune(persianDigits(strconv.FormatUint(v, 10)))
+	var b strings.Builder
+	for i, r := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteRune('٬')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// jalaliDate converts the calendar day of t, in t's location, to the Solar
+// Hijri calendar used on Iranian invoices
+func jalaliDate(t time.Time) (year, month, day int) {
+	gy, gm, gd := t.Date()
+	daysBeforeMonth := [12]int{0, 31, 59, 90, 120, 151, 181, 212, 243, 273, 304, 334}
+	gy2 := gy
+	if gm > 2 {
+		gy2 = gy + 1
+	}
+	days := 355666 + 365*gy + (gy2+3)/4 - (gy2+99)/100 + (gy2+399)/400 + gd + daysBeforeMonth[gm-1]
+	year = -1595 + 33*(days/12053)
+	days %= 12053
+	year += 4 * (days / 1461)
+	days %= 1461
+	if days > 365 {
+		year += (days - 1) / 365
+		days = (days - 1) % 365
+	}
+	if days < 186 {
+		return year, 1 + days/31, 1 + days%31
+	}
+	return year, 7 + (days-186)/30, 1 + (days-186)%30
+}
@@ -0,0 +1,51 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShapePersian(t *testing.T) {
+	// سلام: initial seen, medial lam, final alef joined to it as lam-alef, final meem
+	assert.Equal(t, "ﺳﻼﻡ", shapePersian("سلام"))
+	// دو: dal and waw never join forward, so both are isolated
+	assert.Equal(t, "ﺩﻭ", shapePersian("دو"))
+	// کی: Persian kaf and yeh come from presentation forms A
+	assert.Equal(t, "ﮐﯽ", shapePersian("کی"))
+	// A zero-width non-joiner breaks the join and is dropped
+	assert.Equal(t, "\uFEE3\uFBFD", shapePersian("می"))
+	assert.Equal(t, "\uFEE1\uFBFC", shapePersian("م\u200cی"))
+	assert.Equal(t, "abc 123", shapePersian("abc 123"))
+}
+
+func TestVisualOrder(t *testing.T) {
+	assert.Equal(t, "JZB-00000042 :ابم", visualOrder("مبا: JZB-00000042"))
+	// Digits and the separators between them keep their order
+	assert.Equal(t, "۱۴۰۵/۰۷/۲۵ :بت", visualOrder("تب: ۱۴۰۵/۰۷/۲۵"))
+	assert.Equal(t, "(٪۱۰) با", visualOrder("اب (۱۰٪)"))
+	assert.Equal(t, "hello world", visualOrder("hello world"))
+}
+
+func TestPersianAmount(t *testing.T) {
+	assert.Equal(t, "۰", persianAmount(0))
+	assert.Equal(t, "۹۹۹", persianAmount(999))
+	assert.Equal(t, "۱٬۰۰۰", persianAmount(1000))
+	assert.Equal(t, "۱۲٬۳۴۵٬۶۷۸", persianAmount(12345678))
+}
+
+func TestJalaliDate(t *testing.T) {
+	for _, tc := range []struct {
+		date    time.Time
+		y, m, d int
+	}{
+		{time.Date(2026, 3, 21, 0, 0, 0, 0, time.UTC), 1405, 1, 1},
+		{time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC), 1405, 7, 25},
+		{time.Date(2025, 3, 20, 0, 0, 0, 0, time.UTC), 1403, 12, 30},
+		{time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC), 1402, 12, 10},
+	} {
+		y, m, d := jalaliDate(tc.date)
+		assert.Equal(t, [3]int{tc.y, tc.m, tc.d}, [3]int{y, m, d}, tc.date.Format("2006-01-02"))
+	}
+}
@@ -0,0 +1,160 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/redis/go-redis/v9"
+)
+
+// SessionState is what the session store knows about an access token
+type SessionState int
+
+const (
+	// SessionUnknown means the token is not mirrored; the caller checks the
+	// customer_sessions table instead
+	SessionUnknown SessionState = iota
+	SessionActive
+	SessionRevoked
+)
+
+// SessionStore mirrors active customer sessions in Redis so they can be
+// checked on every request without a database lookup and revoked instantly.
+//
+// Each customer has a session version; a session is active while the version
+// it was mirrored with is still current, so RevokeAll invalidates every
+// session of a customer with a single increment. Revoke leaves a tombstone for
+// one session. The customer_sessions table stays the source of truth: callers
+// deactivate sessions there too, and fall back to it when a token is not
+// mirrored or Redis is unavailable.
+type SessionStore interface {
+	Activate(ctx context.Context, customerID uint, accessToken string, expiresAt time.Time) error
+	Revoke(ctx context.Context, accessToken string, expiresAt time.Time) error
+	RevokeAll(ctx context.Context, customerID uint) error
+	State(ctx context.Context, customerID uint, accessToken string) (SessionState, error)
+	// MarkAccessed reports whether the use of a token should be recorded,
+	// which is at most once per interval
+	MarkAccessed(ctx context.Context, accessToken string, interval time.Duration) (bool, error)
+}
+
+const sessionRevokedValue = "revoked"
+
+// sessionActivateScript mirrors a session with the current version of its
+// customer; reading the version and writing the session atomically keeps a
+// concurrent RevokeAll from being missed
+var sessionActivateScript = redis.NewScript(`
+local version = redis.call('GET', KEYS[2]) or '0'
+redis.call('SET', KEYS[1], ARGV[1] .. ':' .. version, 'PX', ARGV[2])
+return version
+`)
+
+type redisSessionStore struct {
+	rc *redis.Client
+}
+
+// NewRedisSessionStore creates a session store; with a nil Redis client every
+// token is reported unknown, nothing is mirrored, and every access is recorded
+func NewRedisSessionStore(rc *redis.Client) SessionStore {
+	return &redisSessionStore{rc: rc}
+}
+
+func (s *redisSessionStore) Activate(ctx context.Context, customerID uint, accessToken string, expiresAt time.Time) error {
+	ttl := sessionTTL(expiresAt)
+	if s.rc == nil || ttl <= 0 {
+		return nil
+	}
+	return sessionActivateScript.Run(ctx, s.rc,
+		[]string{sessionKey(accessToken), sessionVersionKey(customerID)},
+		customerID, ttl.Milliseconds()).Err()
+}
+
+func (s *redisSessionStore) Revoke(ctx context.Context, accessToken string, expiresAt time.Time) error {
+	if s.rc == nil {
+		return nil
+	}
+	ttl := sessionTTL(expiresAt)
+	if ttl < time.Minute {
+		ttl = time.Minute
+	}
+	return s.rc.Set(ctx, sessionKey(accessToken), sessionRevokedValue, ttl).Err()
+}
+
+func (s *redisSessionStore) RevokeAll(ctx context.Context, customerID uint) error {
+	if s.rc == nil {
+		return nil
+	}
+	return s.rc.Incr(ctx, sessionVersionKey(customerID)).Err()
+}
+
+func (s *redisSessionStore) State(ctx context.Context, customerID uint, accessToken string) (SessionState, error) {
+	if s.rc == nil {
+		return SessionUnknown, nil
+	}
+	values, err := s.rc.MGet(ctx, sessionKey(accessToken), sessionVersionKey(customerID)).Result()
+	if err != nil {
+		return SessionUnknown, err
+	}
+	session, ok := values[0].(string)
+	if !ok {
+		return SessionUnknown, nil
+	}
+	current := "0"
+	if version, ok := values[1].(string); ok {
+		current = version
+	}
+	return parseSessionState(session, customerID, current)
+}
+
+func (s *redisSessionStore) MarkAccessed(ctx context.Context, accessToken string, interval time.Duration) (bool, error) {
+	if s.rc == nil {
+		return true, nil
+	}
+	return s.rc.SetNX(ctx, sessionAccessKey(accessToken), 1, interval).Result()
+}
+
+// parseSessionState decodes a mirrored session value of the form
+// <customer_id>:<version>
+func parseSessionState(value string, customerID uint, currentVersion string) (SessionState, error) {
+	if value == sessionRevokedValue {
+		return SessionRevoked, nil
+	}
+	owner, version, ok := strings.Cut(value, ":")
+	if !ok {
+		return SessionUnknown, errors.New("malformed session entry")
+	}
+	ownerID, err := strconv.ParseUint(owner, 10, 64)
+	if err != nil {
+		return SessionUnknown, fmt.Errorf("malformed session entry: %w", err)
+	}
+	if uint(ownerID) != customerID || version != currentVersion {
+		return SessionRevoked, nil
+	}
+	return SessionActive, nil
+}
+
+func sessionTTL(expiresAt time.Time) time.Duration {
+	return expiresAt.Sub(utils.UTCNow())
+}
+
+// sessionKey keys a session by the hash of its access token so tokens are
+// not stored in Redis
+func sessionKey(accessToken string) string {
+	sum := sha256.Sum256([]byte(accessToken))
+	return "auth:session:" + hex.EncodeToString(sum[:])
+}
+
+func sessionAccessKey(accessToken string) string {
+	sum := sha256.Sum256([]byte(accessToken))
+	return "auth:session_access:" + hex.EncodeToString(sum[:])
+}
+
+func sessionVersionKey(customerID uint) string {
+	return fmt.Sprintf("auth:session_version:%d", customerID)
+}
@@ -0,0 +1,36 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSessionState(t *testing.T) {
+	state, err := parseSessionState("42:3", 42, "3")
+	assert.NoError(t, err)
+	assert.Equal(t, SessionActive, state)
+
+	// RevokeAll bumped the version after the session was mirrored
+	state, err = parseSessionState("42:3", 42, "4")
+	assert.NoError(t, err)
+	assert.Equal(t, SessionRevoked, state)
+
+	state, err = parseSessionState("42:3", 7, "3")
+	assert.NoError(t, err)
+	assert.Equal(t, SessionRevoked, state, "a token of another customer is not active")
+
+	state, err = parseSessionState(sessionRevokedValue, 42, "3")
+	assert.NoError(t, err)
+	assert.Equal(t, SessionRevoked, state)
+
+	_, err = parseSessionState("garbage", 42, "0")
+	assert.Error(t, err)
+}
+
+func TestSessionKeyHidesToken(t *testing.T) {
+	key := sessionKey("header.payload.signature")
+	assert.NotContains(t, key, "payload")
+	assert.Equal(t, key, sessionKey("header.payload.signature"))
+	assert.NotEqual(t, key, sessionKey("header.payload.other"))
+}
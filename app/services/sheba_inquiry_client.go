@@ -0,0 +1,182 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/httpclient"
+)
+
+// ErrShebaUnknown is returned for a Sheba number the bank has no account for
+var ErrShebaUnknown = errors.New("sheba number has no bank account")
+
+// ShebaInquiry is the bank account a Sheba number belongs to
+type ShebaInquiry struct {
+	Sheba         string   `json:"sheba"`
+	Bank          string   `json:"bank"`
+	DepositNumber string   `json:"deposit_number"`
+	Active        bool     `json:"active"`
+	Owners        []string `json:"owners"`
+}
+
+// ShebaInquirer looks up the owners of Sheba numbers with a bank inquiry
+// provider
+type ShebaInquirer interface {
+	InquireSheba(ctx context.Context, sheba string) (*ShebaInquiry, error)
+}
+
+// jibitTokenTTL is how long an access token is reused; Jibit issues them for
+// 24 hours
+const jibitTokenTTL = 23 * time.Hour
+
+// JibitShebaClient looks up Sheba numbers with the Jibit IBAN inquiry.
+// Docs: https://napi.jibit.ir/ide/swagger-ui/index.html
+type JibitShebaClient struct {
+	BaseURL    string
+	APIKey     string
+	SecretKey  string
+	HTTPClient *http.Client
+
+	mu          sync.Mutex
+	accessToken string
+	tokenExpiry time.Time
+}
+
+func NewJibitShebaClient(baseURL, apiKey, secretKey string, timeout time.Duration) *JibitShebaClient {
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	return &JibitShebaClient{
+		BaseURL:    strings.TrimRight(baseURL, "/"),
+		APIKey:     apiKey,
+		SecretKey:  secretKey,
+		HTTPClient: httpclient.New("jibit", timeout),
+	}
+}
+
+type jibitIBANResponse struct {
+	Value    string `json:"value"`
+	IBANInfo struct {
+		Bank          string `json:"bank"`
+		DepositNumber string `json:"depositNumber"`
+		IBAN          string `json:"iban"`
+		Status        string `json:"status"`
+		Owners        []struct {
+			FirstName string `json:"firstName"`
+			LastName  string `json:"lastName"`
+		} `json:"owners"`
+	} `json:"ibanInfo"`
+}
+
+type jibitError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// InquireSheba returns the account sheba belongs to, or ErrShebaUnknown when
+// the bank has none
+func (c *JibitShebaClient) InquireSheba(ctx context.Context, sheba string) (*ShebaInquiry, error) {
+	resp, err := c.getIBAN(ctx, sheba)
+	if err == nil && resp.StatusCode == http.StatusUnauthorized {
+		// The token was revoked before it expired; get a new one once
+		resp.Body.Close()
+		c.mu.Lock()
+		c.accessToken = ""
+		c.mu.Unlock()
+		resp, err = c.getIBAN(ctx, sheba)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var e jibitError
+		_ = json.NewDecoder(resp.Body).Decode(&e)
+		if resp.StatusCode == http.StatusBadRequest || resp.StatusCode == http.StatusNotFound {
+			return nil, fmt.Errorf("%w: %s", ErrShebaUnknown, e.Code)
+		}
+		return nil, fmt.Errorf("jibit iban inquiry: http %d: %s %s", resp.StatusCode, e.Code, e.Message)
+	}
+	var res jibitIBANResponse
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return nil, fmt.Errorf("jibit iban inquiry: decode response: %w", err)
+	}
+	inquiry := &ShebaInquiry{
+		Sheba:         res.IBANInfo.IBAN,
+		Bank:          res.IBANInfo.Bank,
+		DepositNumber: res.IBANInfo.DepositNumber,
+		Active:        res.IBANInfo.Status == "ACTIVE",
+		Owners:        make([]string, 0, len(res.IBANInfo.Owners)),
+	}
+	if inquiry.Sheba == "" {
+		inquiry.Sheba = sheba
+	}
+	for _, o := range res.IBANInfo.Owners {
+		inquiry.Owners = append(inquiry.Owners, strings.TrimSpace(o.FirstName+" "+o.LastName))
+	}
+	return inquiry, nil
+}
+
+func (c *JibitShebaClient) getIBAN(ctx context.Context, sheba string) (*http.Response, error) {
+	token, err := c.authToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.BaseURL+"/v1/ibans?value="+url.QueryEscape(sheba), nil)
+	if err != nil {
+		return nil, fmt.Errorf("jibit iban inquiry: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("jibit iban inquiry: %w", err)
+	}
+	return resp, nil
+}
+
+// authToken returns an access token for the API key, reused until shortly
+// before it expires
+func (c *JibitShebaClient) authToken(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.accessToken != "" && time.Now().Before(c.tokenExpiry) {
+		return c.accessToken, nil
+	}
+
+	body, err := json.Marshal(map[string]string{"apiKey": c.APIKey, "secretKey": c.SecretKey})
+	if err != nil {
+		return "", fmt.Errorf("jibit auth: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.BaseURL+"/v1/tokens/generate", bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("jibit auth: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("jibit auth: %w", err)
+	}
+	defer resp.Body.Close()
+	var tok struct {
+		AccessToken string `json:"accessToken"`
+		jibitError
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tok); err != nil {
+		return "", fmt.Errorf("jibit auth: decode token response (status %d): %w", resp.StatusCode, err)
+	}
+	if resp.StatusCode != http.StatusOK || tok.AccessToken == "" {
+		return "", fmt.Errorf("jibit auth: http %d: %s", resp.StatusCode, tok.Code)
+	}
+	c.accessToken = tok.AccessToken
+	c.tokenExpiry = time.Now().Add(jibitTokenTTL)
+	return c.accessToken, nil
+}
@@ -0,0 +1,74 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func jibitResponse(status int, body string) *http.Response {
+	return &http.Response{StatusCode: status, Body: io.NopCloser(strings.NewReader(body))}
+}
+
+func TestJibitShebaClientInquireSheba(t *testing.T) {
+	tokens, inquiries := 0, 0
+	client := NewJibitShebaClient("https://napi.jibit.ir/ide/", "key", "secret", 0)
+	client.HTTPClient = &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		switch req.URL.Path {
+		case "/ide/v1/tokens/generate":
+			tokens++
+			b, _ := io.ReadAll(req.Body)
+			assert.JSONEq(t, `{"apiKey":"key","secretKey":"secret"}`, string(b))
+			return jibitResponse(http.StatusOK, `{"accessToken":"tok","refreshToken":"ref"}`), nil
+		case "/ide/v1/ibans":
+			inquiries++
+			assert.Equal(t, "Bearer tok", req.Header.Get("Authorization"))
+			assert.Equal(t, "IR820540102680020817909002", req.URL.Query().Get("value"))
+			return jibitResponse(http.StatusOK, `{"value":"IR820540102680020817909002","ibanInfo":{"bank":"MELLI","depositNumber":"0102680020817909002","iban":"IR820540102680020817909002","status":"ACTIVE","owners":[{"firstName":"علی","lastName":"رضایی"}]}}`), nil
+		}
+		return nil, errors.New("unexpected request " + req.URL.Path)
+	})}
+
+	for range 2 {
+		inquiry, err := client.InquireSheba(context.Background(), "IR820540102680020817909002")
+		require.NoError(t, err)
+		assert.True(t, inquiry.Active)
+		assert.Equal(t, "MELLI", inquiry.Bank)
+		assert.Equal(t, []string{"علی رضایی"}, inquiry.Owners)
+	}
+	assert.Equal(t, 1, tokens, "access token is reused")
+	assert.Equal(t, 2, inquiries)
+}
+
+func TestJibitShebaClientInquireShebaErrors(t *testing.T) {
+	client := NewJibitShebaClient("https://napi.jibit.ir/ide", "key", "secret", 0)
+	ibanStatus, tokens := http.StatusUnauthorized, 0
+	client.HTTPClient = &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if strings.HasSuffix(req.URL.Path, "/tokens/generate") {
+			tokens++
+			return jibitResponse(http.StatusOK, `{"accessToken":"tok"}`), nil
+		}
+		status := ibanStatus
+		ibanStatus = http.StatusNotFound
+		return jibitResponse(status, `{"code":"iban.not_found","message":"not found"}`), nil
+	})}
+
+	// A revoked token is replaced once before the inquiry is retried
+	_, err := client.InquireSheba(context.Background(), "IR820540102680020817909002")
+	assert.ErrorIs(t, err, ErrShebaUnknown)
+	assert.Equal(t, 2, tokens)
+
+	ibanStatus = http.StatusInternalServerError
+	client.HTTPClient = &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return jibitResponse(ibanStatus, `{"code":"server.error"}`), nil
+	})}
+	_, err = client.InquireSheba(context.Background(), "IR820540102680020817909002")
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, ErrShebaUnknown)
+}
@@ -7,11 +7,11 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"net/url"
 	"os"
 	"strings"
 	"time"
 
+	"github.com/amirphl/Yamata-no-Orochi/app/httpclient"
 	"github.com/amirphl/Yamata-no-Orochi/config"
 )
 
@@ -54,16 +54,13 @@ type smartTagOpenAIClient struct {
 }
 
 func NewSmartTagOpenAIClient(cfg config.SmartTagOpenAIConfig) (SmartTagOpenAIClient, error) {
-	var parsedProxy *url.URL
+	proxyURL := ""
 	if cfg.HTTPProxy != nil {
-		proxyURL := strings.TrimSpace(*cfg.HTTPProxy)
-		if proxyURL != "" {
-			var err error
-			parsedProxy, err = url.Parse(proxyURL)
-			if err != nil {
-				return nil, fmt.Errorf("OPENAI_PROXY_INVALID: %w", err)
-			}
-		}
+		proxyURL = *cfg.HTTPProxy
+	}
+	httpClient, err := httpclient.NewWithProxy("openai", cfg.Timeout, proxyURL)
+	if err != nil {
+		return nil, fmt.Errorf("OPENAI_PROXY_INVALID: %w", err)
 	}
 
 	apiKeyEnv := strings.TrimSpace(cfg.APIKeyEnv)
@@ -80,19 +77,11 @@ func NewSmartTagOpenAIClient(cfg config.SmartTagOpenAIConfig) (SmartTagOpenAICli
 		baseURL = "https://api.openai.com/v1"
 	}
 
-	transport := http.DefaultTransport.(*http.Transport).Clone()
-	if parsedProxy != nil {
-		transport.Proxy = http.ProxyURL(parsedProxy)
-	}
-
 	return &smartTagOpenAIClient{
-		baseURL: baseURL,
-		model:   cfg.Model,
-		apiKey:  apiKey,
-		httpClient: &http.Client{
-			Timeout:   cfg.Timeout,
-			Transport: transport,
-		},
+		baseURL:    baseURL,
+		model:      cfg.Model,
+		apiKey:     apiKey,
+		httpClient: httpClient,
 	}, nil
 }
 
@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/amirphl/Yamata-no-Orochi/app/httpclient"
 	"github.com/amirphl/Yamata-no-Orochi/config"
 	"github.com/amirphl/Yamata-no-Orochi/utils"
 )
@@ -51,9 +52,7 @@ type SMSResponse struct {
 func NewSMSService(cfg *config.SMSConfig) SMSService {
 	return &SMSServiceImpl{
 		config: cfg,
-		client: &http.Client{
-			Timeout: cfg.Timeout,
-		},
+		client: httpclient.New("sms", cfg.Timeout),
 	}
 }
 
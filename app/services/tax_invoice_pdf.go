@@ -0,0 +1,221 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/go-pdf/fpdf"
+)
+
+// TaxInvoiceDocument is what is printed on an official invoice. Amounts are
+// in toman.
+type TaxInvoiceDocument struct {
+	InvoiceNumber  string
+	IssuedAt       time.Time
+	Seller         models.TaxInvoiceParty
+	Buyer          models.TaxInvoiceParty
+	Description    string
+	PaymentChannel string
+	Reference      string
+	Amount         uint64
+	Tax            uint64
+	AmountWithTax  uint64
+	TaxBasisPoints uint64
+}
+
+// TaxInvoiceRenderer renders official invoices
+type TaxInvoiceRenderer interface {
+	Render(doc TaxInvoiceDocument) ([]byte, error)
+}
+
+// TaxInvoicePDFRenderer renders invoices as A4 PDFs in the Persian layout of
+// the standard sales invoice: seller, buyer, the line item with its VAT and
+// the amount payable. The fonts must contain the Arabic presentation forms.
+type TaxInvoicePDFRenderer struct {
+	regular []byte
+	bold    []byte
+}
+
+// NewTaxInvoicePDFRenderer renders with the given TrueType fonts; bold may be
+// nil to use the regular font for headings too
+func NewTaxInvoicePDFRenderer(regular, bold []byte) *TaxInvoicePDFRenderer {
+	if bold == nil {
+		bold = regular
+	}
+	return &TaxInvoicePDFRenderer{regular: regular, bold: bold}
+}
+
+// LoadTaxInvoicePDFRenderer reads the fonts from disk; boldPath may be empty
+func LoadTaxInvoicePDFRenderer(regularPath, boldPath string) (*TaxInvoicePDFRenderer, error) {
+	regular, err := os.ReadFile(regularPath)
+	if err != nil {
+		return nil, fmt.Errorf("invoice font: %w", err)
+	}
+	var bold []byte
+	if boldPath != "" {
+		if bold, err = os.ReadFile(boldPath); err != nil {
+			return nil, fmt.Errorf("invoice bold font: %w", err)
+		}
+	}
+	return NewTaxInvoicePDFRenderer(regular, bold), nil
+}
+
+const (
+	invoiceFont       = "invoice"
+	invoiceMargin     = 12.0
+	invoiceWidth      = 210 - 2*invoiceMargin
+	invoiceRowH       = 7.0
+	invoiceEmptyField = "-"
+)
+
+func (r *TaxInvoicePDFRenderer) Render(doc TaxInvoiceDocument) ([]byte, error) {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(invoiceMargin, invoiceMargin, invoiceMargin)
+	pdf.SetAutoPageBreak(true, invoiceMargin)
+	pdf.SetTitle(doc.InvoiceNumber, true)
+	pdf.AddUTF8FontFromBytes(invoiceFont, "", r.regular)
+	pdf.AddUTF8FontFromBytes(invoiceFont, "B", r.bold)
+	pdf.SetFillColor(235, 235, 235)
+	pdf.AddPage()
+
+	pdf.SetFont(invoiceFont, "B", 15)
+	pdf.CellFormat(0, 12, persianVisual("صورتحساب فروش کالا و خدمات"), "", 1, "C", false, 0, "")
+
+	y, m, d := jalaliDate(doc.IssuedAt.In(utils.TehranLocation()))
+	pdf.SetFont(invoiceFont, "", 10)
+	pdf.CellFormat(invoiceWidth/2, invoiceRowH, persianVisual("تاریخ: "+persianDigits(fmt.Sprintf("%04d/%02d/%02d", y, m, d))), "", 0, "L", false, 0, "")
+	pdf.CellFormat(invoiceWidth/2, invoiceRowH, persianVisual("شماره صورتحساب: "+doc.InvoiceNumber), "", 1, "R", false, 0, "")
+	pdf.Ln(2)
+
+	drawInvoiceParty(pdf, "مشخصات فروشنده", doc.Seller)
+	pdf.Ln(3)
+	drawInvoiceParty(pdf, "مشخصات خریدار", doc.Buyer)
+	pdf.Ln(3)
+	drawInvoiceItems(pdf, doc)
+	pdf.Ln(4)
+
+	pdf.SetFont(invoiceFont, "", 9)
+	payment := "روش پرداخت: " + doc.PaymentChannel
+	if doc.Reference != "" {
+		payment += "     شناسه پرداخت: " + doc.Reference
+	}
+	pdf.CellFormat(0, invoiceRowH, persianVisual(payment), "", 1, "R", false, 0, "")
+	pdf.CellFormat(0, invoiceRowH, persianVisual("این صورتحساب پس از تأیید پرداخت به‌صورت سامانه‌ای صادر شده است."), "", 1, "R", false, 0, "")
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, fmt.Errorf("render invoice %s: %w", doc.InvoiceNumber, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// drawInvoiceParty prints a seller or buyer box, two fields per line
+func drawInvoiceParty(pdf *fpdf.Fpdf, title string, p models.TaxInvoiceParty) {
+	pdf.SetFont(invoiceFont, "B", 10)
+	pdf.CellFormat(0, invoiceRowH, persianVisual(title), "1", 1, "C", true, 0, "")
+
+	phone := p.Phone
+	if phone == "" {
+		phone = p.Mobile
+	}
+	fields := [][2]string{
+		{"نام شخص حقیقی / حقوقی", p.Name},
+		{"شماره اقتصادی", p.EconomicCode},
+		{"شناسه ملی / کد ملی", p.NationalID},
+		{"شماره ثبت", p.RegistrationNumber},
+		{"کد پستی", p.PostalCode},
+		{"تلفن", phone},
+	}
+	pdf.SetFont(invoiceFont, "", 9)
+	for i := 0; i < len(fields); i += 2 {
+		// The first field of a line is the right one
+		pdf.CellFormat(invoiceWidth/2, invoiceRowH, persianVisual(invoiceField(fields[i+1])), "LB", 0, "R", false, 0, "")
+		pdf.CellFormat(invoiceWidth/2, invoiceRowH, persianVisual(invoiceField(fields[i])), "RB", 1, "R", false, 0, "")
+	}
+	lines := wrapRTL(pdf, invoiceField([2]string{"نشانی", p.Address}), invoiceWidth-4)
+	for i, line := range lines {
+		border := "LR"
+		if i == len(lines)-1 {
+			border = "LRB"
+		}
+		pdf.CellFormat(invoiceWidth, invoiceRowH, persianVisual(line), border, 1, "R", false, 0, "")
+	}
+}
+
+func invoiceField(f [2]string) string {
+	value := strings.TrimSpace(f[1])
+	if value == "" {
+		value = invoiceEmptyField
+	}
+	return f[0] + ": " + value
+}
+
+// drawInvoiceItems prints the line item and the totals. Columns run from
+// right to left, so they are drawn in reverse.
+func drawInvoiceItems(pdf *fpdf.Fpdf, doc TaxInvoiceDocument) {
+	pdf.SetFont(invoiceFont, "", 8)
+	pdf.CellFormat(0, 5, persianVisual("مبالغ به تومان"), "", 1, "L", false, 0, "")
+
+	widths := []float64{10, 58, 28, 18, 24, 22, 26}
+	headers := []string{"ردیف", "شرح کالا یا خدمات", "مبلغ", "تخفیف", "مبلغ پس از تخفیف", "مالیات و عوارض", "جمع کل"}
+	values := []string{
+		persianDigits("1"),
+		doc.Description,
+		persianAmount(doc.Amount),
+		persianAmount(0),
+		persianAmount(doc.Amount),
+		persianAmount(doc.Tax),
+		persianAmount(doc.AmountWithTax),
+	}
+
+	pdf.SetFont(invoiceFont, "B", 8)
+	for i := len(headers) - 1; i >= 0; i-- {
+		pdf.CellFormat(widths[i], 9, persianVisual(headers[i]), "1", 0, "C", true, 0, "")
+	}
+	pdf.Ln(-1)
+	pdf.SetFont(invoiceFont, "", 9)
+	for i := len(values) - 1; i >= 0; i-- {
+		pdf.CellFormat(widths[i], 9, persianVisual(values[i]), "1", 0, "C", false, 0, "")
+	}
+	pdf.Ln(-1)
+
+	rate := strings.ReplaceAll(strconv.FormatFloat(float64(doc.TaxBasisPoints)/100, 'f', -1, 64), ".", "٫")
+	totals := [][2]string{
+		{"جمع مبلغ پیش از مالیات", persianAmount(doc.Amount)},
+		{"مالیات بر ارزش افزوده (" + persianDigits(rate) + "٪)", persianAmount(doc.Tax)},
+		{"مبلغ قابل پرداخت", persianAmount(doc.AmountWithTax)},
+	}
+	last := widths[len(widths)-1]
+	for i, t := range totals {
+		if i == len(totals)-1 {
+			pdf.SetFont(invoiceFont, "B", 10)
+		}
+		pdf.CellFormat(last, invoiceRowH, persianVisual(t[1]), "1", 0, "C", false, 0, "")
+		pdf.CellFormat(invoiceWidth-last, invoiceRowH, persianVisual(t[0]), "1", 1, "R", true, 0, "")
+	}
+}
+
+// wrapRTL splits text into lines that fit width, measured after shaping
+func wrapRTL(pdf *fpdf.Fpdf, text string, width float64) []string {
+	words := strings.Fields(text)
+	if len(words) == 0 {
+		return []string{""}
+	}
+	var lines []string
+	line := words[0]
+	for _, w := range words[1:] {
+		if pdf.GetStringWidth(shapePersian(line+" "+w)) > width {
+			lines = append(lines, line)
+			line = w
+			continue
+		}
+		line += " " + w
+	}
+	return append(lines, line)
+}
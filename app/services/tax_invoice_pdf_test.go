@@ -0,0 +1,33 @@
+package services
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+func TestTaxInvoicePDFRendererRender(t *testing.T) {
+	// The Go fonts lack Persian glyphs, which only leaves those blank
+	renderer := NewTaxInvoicePDFRenderer(goregular.TTF, gobold.TTF)
+	out, err := renderer.Render(TaxInvoiceDocument{
+		InvoiceNumber:  "JZB-00000042",
+		IssuedAt:       time.Date(2026, 10, 17, 9, 30, 0, 0, time.UTC),
+		Seller:         models.TaxInvoiceParty{Name: "پلتفرم جاذبه", EconomicCode: "411111111111"},
+		Buyer:          models.TaxInvoiceParty{Name: "شرکت نمونه", Address: "تهران، خیابان آزادی، پلاک ۱۲۳، طبقه دوم، واحد ۴ — نشانی طولانی برای شکستن سطر در کادر خریدار"},
+		Description:    "شارژ کیف پول جاذبه",
+		PaymentChannel: "درگاه پرداخت",
+		Reference:      "123456789",
+		Amount:         1000000,
+		Tax:            100000,
+		AmountWithTax:  1100000,
+		TaxBasisPoints: 1000,
+	})
+	require.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(out, []byte("%PDF-")))
+}
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/httpclient"
+)
+
+// TelegramSender delivers text messages to Telegram chats
+type TelegramSender interface {
+	SendMessage(ctx context.Context, chatID int64, text string) error
+}
+
+// TelegramClient sends messages through the Telegram Bot API.
+// Docs: https://core.telegram.org/bots/api#sendmessage
+type TelegramClient struct {
+	BaseURL    string
+	BotToken   string
+	HTTPClient *http.Client
+}
+
+func NewTelegramClient(baseURL, botToken string, timeout time.Duration) *TelegramClient {
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	return &TelegramClient{
+		BaseURL:    strings.TrimRight(baseURL, "/"),
+		BotToken:   botToken,
+		HTTPClient: httpclient.New("telegram", timeout),
+	}
+}
+
+type telegramSendMessageRequest struct {
+	ChatID int64  `json:"chat_id"`
+	Text   string `json:"text"`
+}
+
+type telegramResponse struct {
+	OK          bool   `json:"ok"`
+	ErrorCode   int    `json:"error_code"`
+	Description string `json:"description"`
+}
+
+// SendMessage sends a plain text message to a chat
+func (c *TelegramClient) SendMessage(ctx context.Context, chatID int64, text string) error {
+	body, err := json.Marshal(telegramSendMessageRequest{ChatID: chatID, Text: text})
+	if err != nil {
+		return fmt.Errorf("failed to marshal Telegram message: %w", err)
+	}
+	// The token is part of the path, so errors never include the URL
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.BaseURL+"/bot"+c.BotToken+"/sendMessage", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create Telegram request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send Telegram message to chat %d", chatID)
+	}
+	defer resp.Body.Close()
+
+	var res telegramResponse
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return fmt.Errorf("failed to decode Telegram response (status %d): %w", resp.StatusCode, err)
+	}
+	if !res.OK {
+		return fmt.Errorf("telegram delivery to chat %d failed: %s (%d)", chatID, res.Description, res.ErrorCode)
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTelegramClientSendMessage(t *testing.T) {
+	var got *http.Request
+	var body string
+	client := NewTelegramClient("https://api.telegram.org/", "123:secret", 0)
+	client.HTTPClient = &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		got = req
+		b, _ := io.ReadAll(req.Body)
+		body = string(b)
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(`{"ok":true,"result":{}}`))}, nil
+	})}
+
+	require.NoError(t, client.SendMessage(context.Background(), 42, "Campaign approved"))
+	assert.Equal(t, "/bot123:secret/sendMessage", got.URL.Path)
+	assert.JSONEq(t, `{"chat_id":42,"text":"Campaign approved"}`, body)
+}
+
+func TestTelegramClientSendMessageErrors(t *testing.T) {
+	client := NewTelegramClient("https://api.telegram.org", "123:secret", 0)
+	client.HTTPClient = &http.Client{Transport: roundTripFunc(func(*http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusForbidden, Body: io.NopCloser(strings.NewReader(
+			`{"ok":false,"error_code":403,"description":"Forbidden: bot was blocked by the user"}`))}, nil
+	})}
+	err := client.SendMessage(context.Background(), 42, "hi")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "bot was blocked by the user")
+
+	// Transport errors carry the URL, which holds the token
+	client.HTTPClient = &http.Client{Transport: roundTripFunc(func(*http.Request) (*http.Response, error) {
+		return nil, errors.New("connection refused")
+	})}
+	err = client.SendMessage(context.Background(), 42, "hi")
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "secret")
+}
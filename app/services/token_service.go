@@ -31,6 +31,9 @@ type TokenService interface {
 	RevokeToken(token string) error
 	GetTokenClaims(token string) (*TokenClaims, error)
 	IsTokenRevoked(token string) bool
+	// GenerateImpersonationToken issues a customer access token for an admin
+	// acting as the customer. It has no refresh token and expires after ttl.
+	GenerateImpersonationToken(customerID, adminID uint, ttl time.Duration) (string, error)
 	GenerateAdminTokens(adminID uint) (accessToken, refreshToken string, err error)
 	ValidateAdminToken(token string) (*AdminTokenClaims, error)
 	// Bot tokens
@@ -43,10 +46,16 @@ type TokenClaims struct {
 	CustomerID uint      `json:"customer_id"`
 	IssuedAt   time.Time `json:"issued_at"`
 	ExpiresAt  time.Time `json:"expires_at"`
-	TokenType  string    `json:"token_type"` // "access" or "refresh"
+	TokenType  string    `json:"token_type"` // "access", "refresh" or "impersonation"
 	TokenID    string    `json:"jti"`        // JWT ID for token revocation
+	// ImpersonatedBy is the admin acting as the customer; zero for tokens the
+	// customer logged in for
+	ImpersonatedBy uint `json:"impersonated_by,omitempty"`
 }
 
+// TokenTypeImpersonation marks customer access tokens issued to an admin
+const TokenTypeImpersonation = "impersonation"
+
 // AdminTokenClaims represents claims for admin JWTs
 type AdminTokenClaims struct {
 	AdminID   uint      `json:"admin_id"`
@@ -202,6 +211,31 @@ func (s *TokenServiceImpl) GenerateTokens(customerID uint) (accessToken, refresh
 	return accessToken, refreshToken, nil
 }
 
+// GenerateImpersonationToken generates a customer access token carrying the
+// impersonating admin in its impersonated_by claim
+func (s *TokenServiceImpl) GenerateImpersonationToken(customerID, adminID uint, ttl time.Duration) (string, error) {
+	if adminID == 0 {
+		return "", fmt.Errorf("impersonating admin is required")
+	}
+	now := utils.UTCNow()
+
+	tokenID, err := generateTokenID()
+	if err != nil {
+		return "", err
+	}
+
+	return s.generateToken(jwt.MapClaims{
+		"customer_id":     customerID,
+		"impersonated_by": adminID,
+		"token_type":      TokenTypeImpersonation,
+		"jti":             tokenID,
+		"iat":             now.Unix(),
+		"exp":             now.Add(ttl).Unix(),
+		"iss":             s.issuer,
+		"aud":             s.audience,
+	})
+}
+
 // GenerateAdminTokens generates access and refresh tokens for an admin (same TTLs, different claim key)
 func (s *TokenServiceImpl) GenerateAdminTokens(adminID uint) (accessToken, refreshToken string, err error) {
 	now := utils.UTCNow()
@@ -375,13 +409,21 @@ func (s *TokenServiceImpl) ValidateToken(token string) (*TokenClaims, error) {
 		return nil, ErrTokenRevoked
 	}
 
-	return &TokenClaims{
+	result := &TokenClaims{
 		CustomerID: uint(customerID),
 		TokenType:  tokenType,
 		TokenID:    tokenID,
 		IssuedAt:   time.Unix(int64(issuedAt), 0),
 		ExpiresAt:  time.Unix(int64(expiresAt), 0),
-	}, nil
+	}
+	if tokenType == TokenTypeImpersonation {
+		adminID, ok := claims["impersonated_by"].(float64)
+		if !ok || adminID <= 0 {
+			return nil, ErrTokenInvalid
+		}
+		result.ImpersonatedBy = uint(adminID)
+	}
+	return result, nil
 }
 
 // ValidateAdminToken validates an admin JWT and returns admin-specific claims
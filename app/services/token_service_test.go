@@ -291,6 +291,35 @@ func TestRefreshToken(t *testing.T) {
 	}
 }
 
+func TestGenerateImpersonationToken(t *testing.T) {
+	service, err := createTestTokenService()
+	require.NoError(t, err)
+
+	token, err := service.GenerateImpersonationToken(123, 7, 30*time.Minute)
+	require.NoError(t, err)
+
+	claims, err := service.ValidateToken(token)
+	require.NoError(t, err)
+	assert.Equal(t, uint(123), claims.CustomerID)
+	assert.Equal(t, uint(7), claims.ImpersonatedBy)
+	assert.Equal(t, TokenTypeImpersonation, claims.TokenType)
+	assert.WithinDuration(t, time.Now().Add(30*time.Minute), claims.ExpiresAt, 2*time.Second)
+
+	// Impersonation tokens cannot be refreshed into regular sessions
+	_, _, err = service.RefreshToken(token)
+	assert.Error(t, err)
+
+	_, err = service.GenerateImpersonationToken(123, 0, 30*time.Minute)
+	assert.Error(t, err)
+
+	// Regular customer tokens are not marked
+	accessToken, _, err := service.GenerateTokens(123)
+	require.NoError(t, err)
+	claims, err = service.ValidateToken(accessToken)
+	require.NoError(t, err)
+	assert.Zero(t, claims.ImpersonatedBy)
+}
+
 func TestRevokeToken(t *testing.T) {
 	service, err := createTestTokenService()
 	require.NoError(t, err)
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rfc6238Secret is the SHA-1 test key of RFC 6238 appendix B
+var rfc6238Secret = totpEncoding.EncodeToString([]byte("12345678901234567890"))
+
+func TestTOTPValidateRFC6238Vectors(t *testing.T) {
+	svc := NewTOTPService("Yamata")
+
+	// The RFC lists 8 digit codes; 6 digit codes are their last 6 digits
+	for _, tc := range []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	} {
+		at := time.Unix(tc.unix, 0)
+		step, ok := svc.Validate(rfc6238Secret, tc.code, at)
+		assert.True(t, ok, tc.code)
+		assert.Equal(t, tc.unix/30, step, tc.code)
+	}
+}
+
+func TestTOTPValidateAllowsOneStepOfDrift(t *testing.T) {
+	svc := NewTOTPService("Yamata")
+	at := time.Unix(59, 0)
+
+	_, ok := svc.Validate(rfc6238Secret, "287082", at.Add(30*time.Second))
+	assert.True(t, ok, "previous step")
+	_, ok = svc.Validate(rfc6238Secret, "287082", at.Add(-30*time.Second))
+	assert.True(t, ok, "next step")
+	_, ok = svc.Validate(rfc6238Secret, "287082", at.Add(90*time.Second))
+	assert.False(t, ok, "three steps later")
+
+	_, ok = svc.Validate(rfc6238Secret, "000000", at)
+	assert.False(t, ok)
+	_, ok = svc.Validate("not base32!", "287082", at)
+	assert.False(t, ok)
+}
+
+func TestTOTPGenerateSecretAndProvisioningURI(t *testing.T) {
+	svc := NewTOTPService("Yamata no Orochi")
+
+	secret, err := svc.GenerateSecret()
+	require.NoError(t, err)
+	key, err := decodeTOTPSecret(secret)
+	require.NoError(t, err)
+	assert.Len(t, key, totpSecretLength)
+
+	now := time.Now()
+	_, ok := svc.Validate(secret, totpCode(key, now.Unix()/30), now)
+	assert.True(t, ok)
+
+	uri, err := url.Parse(svc.ProvisioningURI("admin", secret))
+	require.NoError(t, err)
+	assert.Equal(t, "otpauth", uri.Scheme)
+	assert.Equal(t, "totp", uri.Host)
+	assert.Equal(t, "/Yamata no Orochi:admin", uri.Path)
+	assert.Equal(t, secret, uri.Query().Get("secret"))
+	assert.Equal(t, "Yamata no Orochi", uri.Query().Get("issuer"))
+}
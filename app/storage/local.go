@@ -0,0 +1,229 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrSignatureInvalid is returned for a local download link that was not
+	// signed with the store's key
+	ErrSignatureInvalid = errors.New("invalid signature")
+	// ErrSignatureExpired is returned for a local download link past its expiry
+	ErrSignatureExpired = errors.New("signature expired")
+)
+
+// LocalStorage keeps files under a directory, for development and single
+// node deployments. Signed URLs point at BaseURL, the public address of the
+// route serving the directory, and carry an HMAC of the key and expiry.
+type LocalStorage struct {
+	Dir        string
+	BaseURL    *url.URL
+	SigningKey []byte
+
+	now func() time.Time
+}
+
+const localTempPrefix = ".upload-"
+
+// NewLocalStorage returns a store keeping files under dir, creating it when
+// missing
+func NewLocalStorage(dir, baseURL, signingKey string) (*LocalStorage, error) {
+	if dir == "" {
+		return nil, errors.New("local storage directory is required")
+	}
+	u, err := url.Parse(strings.TrimRight(baseURL, "/"))
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("local storage base URL must be an absolute URL: %q", baseURL)
+	}
+	if signingKey == "" {
+		return nil, errors.New("local storage signing key is required")
+	}
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("create local storage directory: %w", err)
+	}
+	return &LocalStorage{
+		Dir:        dir,
+		BaseURL:    u,
+		SigningKey: []byte(signingKey),
+		now:        time.Now,
+	}, nil
+}
+
+func (s *LocalStorage) Put(ctx context.Context, key, contentType string, data []byte) error {
+	full, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(full), 0o750); err != nil {
+		return err
+	}
+	// Write to a temporary file and rename it so readers never see a
+	// partially written file
+	tmp, err := os.CreateTemp(filepath.Dir(full), localTempPrefix+"*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), full)
+}
+
+// Get returns the file and a content type guessed from its extension, or
+// sniffed from its content when the extension is unknown
+func (s *LocalStorage) Get(ctx context.Context, key string) ([]byte, string, error) {
+	full, err := s.path(key)
+	if err != nil {
+		return nil, "", err
+	}
+	data, err := os.ReadFile(full)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, "", ErrNotFound
+		}
+		return nil, "", err
+	}
+	contentType := mime.TypeByExtension(path.Ext(key))
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+	}
+	return data, contentType, nil
+}
+
+func (s *LocalStorage) Delete(ctx context.Context, key string) error {
+	full, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(full); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// SignedURL returns BaseURL/<key>?expires=<unix>&signature=<hmac>
+func (s *LocalStorage) SignedURL(key string, ttl time.Duration) (string, error) {
+	if ttl <= 0 {
+		return "", errors.New("signed URL lifetime must be positive")
+	}
+	key, err := cleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	expires := s.now().Add(ttl).Unix()
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(expires, 10))
+	query.Set("signature", s.sign(key, expires))
+	return s.BaseURL.String() + "/" + s3EscapePath(key) + "?" + query.Encode(), nil
+}
+
+// VerifySignedURL checks the expires and signature query parameters of a
+// link SignedURL returned for key
+func (s *LocalStorage) VerifySignedURL(key, expires, signature string) error {
+	key, err := cleanKey(key)
+	if err != nil {
+		return err
+	}
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return ErrSignatureInvalid
+	}
+	if !hmac.Equal([]byte(signature), []byte(s.sign(key, unix))) {
+		return ErrSignatureInvalid
+	}
+	if s.now().Unix() > unix {
+		return ErrSignatureExpired
+	}
+	return nil
+}
+
+// ApplyLifecycle deletes the files under each rule's prefix that were
+// written more than ExpireAfter ago
+func (s *LocalStorage) ApplyLifecycle(ctx context.Context, rules []Rule) error {
+	now := s.now()
+	for _, rule := range rules {
+		// Walk the directory holding the prefix; a prefix such as
+		// exports/2026- also matches part of a file name
+		prefix := strings.TrimLeft(rule.Prefix, "/")
+		dir := prefix
+		if !strings.HasSuffix(prefix, "/") {
+			dir = path.Dir(prefix)
+		}
+		root := s.Dir
+		if dir = strings.Trim(dir, "/"); dir != "" && dir != "." {
+			full, err := s.path(dir)
+			if err != nil {
+				return err
+			}
+			root = full
+		}
+		cutoff := now.Add(-rule.ExpireAfter)
+		err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if errors.Is(err, fs.ErrNotExist) {
+					return nil
+				}
+				return err
+			}
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return ctxErr
+			}
+			if d.IsDir() {
+				return nil
+			}
+			rel, err := filepath.Rel(s.Dir, p)
+			if err != nil || !strings.HasPrefix(filepath.ToSlash(rel), prefix) {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return nil
+			}
+			if info.ModTime().Before(cutoff) {
+				if err := os.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("expire %s: %w", rule.Prefix, err)
+		}
+	}
+	return nil
+}
+
+// path maps key to a file under Dir
+func (s *LocalStorage) path(key string) (string, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(s.Dir, filepath.FromSlash(key)), nil
+}
+
+func (s *LocalStorage) sign(key string, expires int64) string {
+	mac := hmac.New(sha256.New, s.SigningKey)
+	mac.Write([]byte(key + "\n" + strconv.FormatInt(expires, 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,91 @@
+package storage
+
+import (
+	"context"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLocalStorage(t *testing.T) *LocalStorage {
+	t.Helper()
+	s, err := NewLocalStorage(t.TempDir(), "https://api.example.com/api/v1/files/", "secret")
+	require.NoError(t, err)
+	s.now = func() time.Time { return time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC) }
+	return s
+}
+
+func TestLocalStorageRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	s := newTestLocalStorage(t)
+
+	require.NoError(t, s.Put(ctx, "/kyc/1/id.pdf", "application/pdf", []byte("%PDF-1.7")))
+	data, contentType, err := s.Get(ctx, "kyc/1/id.pdf")
+	require.NoError(t, err)
+	assert.Equal(t, "%PDF-1.7", string(data))
+	assert.Equal(t, "application/pdf", contentType)
+
+	require.NoError(t, s.Delete(ctx, "kyc/1/id.pdf"))
+	require.NoError(t, s.Delete(ctx, "kyc/1/id.pdf"))
+	_, _, err = s.Get(ctx, "kyc/1/id.pdf")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	for _, key := range []string{"", "/", "../etc/passwd", "kyc/../../x", `kyc\x`} {
+		assert.ErrorIs(t, s.Put(ctx, key, "", nil), ErrInvalidKey, key)
+	}
+}
+
+func TestLocalStorageSignedURL(t *testing.T) {
+	s := newTestLocalStorage(t)
+	link, err := s.SignedURL("kyc/1/a b.pdf", time.Minute)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(link, "https://api.example.com/api/v1/files/kyc/1/a%20b.pdf?"), link)
+
+	u, err := url.Parse(link)
+	require.NoError(t, err)
+	expires, signature := u.Query().Get("expires"), u.Query().Get("signature")
+	assert.NoError(t, s.VerifySignedURL("kyc/1/a b.pdf", expires, signature))
+	assert.ErrorIs(t, s.VerifySignedURL("kyc/1/other.pdf", expires, signature), ErrSignatureInvalid)
+	assert.ErrorIs(t, s.VerifySignedURL("kyc/1/a b.pdf", "9999999999", signature), ErrSignatureInvalid)
+
+	s.now = func() time.Time { return time.Date(2026, 5, 1, 12, 2, 0, 0, time.UTC) }
+	assert.ErrorIs(t, s.VerifySignedURL("kyc/1/a b.pdf", expires, signature), ErrSignatureExpired)
+}
+
+func TestLocalStorageApplyLifecycle(t *testing.T) {
+	ctx := context.Background()
+	s := newTestLocalStorage(t)
+	for _, key := range []string{"exports/2026-old.zip", "exports/2026-new.zip", "exports/2025-old.zip", "kyc/1/old.pdf"} {
+		require.NoError(t, s.Put(ctx, key, "", []byte("x")))
+	}
+	old := s.now().Add(-48 * time.Hour)
+	for _, key := range []string{"exports/2026-old.zip", "exports/2025-old.zip", "kyc/1/old.pdf"} {
+		require.NoError(t, os.Chtimes(filepath.Join(s.Dir, key), old, old))
+	}
+
+	require.NoError(t, s.ApplyLifecycle(ctx, []Rule{
+		{Prefix: "exports/2026-", ExpireAfter: 24 * time.Hour},
+		{Prefix: "missing/", ExpireAfter: time.Hour},
+	}))
+
+	for key, kept := range map[string]bool{
+		"exports/2026-old.zip": false,
+		"exports/2026-new.zip": true,
+		"exports/2025-old.zip": true,
+		"kyc/1/old.pdf":        true,
+	} {
+		_, err := os.Stat(filepath.Join(s.Dir, key))
+		assert.Equal(t, kept, err == nil, key)
+	}
+}
+
+func TestRules(t *testing.T) {
+	assert.Equal(t, []Rule{{"exports/", time.Hour}, {"tmp/", time.Minute}},
+		Rules(map[string]time.Duration{"tmp/": time.Minute, "exports/": time.Hour}))
+}
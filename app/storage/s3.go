@@ -0,0 +1,357 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/httpclient"
+)
+
+// S3Storage talks to S3 or an S3 compatible store such as MinIO, signing
+// requests with AWS Signature Version 4.
+// Docs: https://docs.aws.amazon.com/AmazonS3/latest/API/sig-v4-authenticating-requests.html
+type S3Storage struct {
+	Endpoint  *url.URL
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	// PathStyle addresses the bucket as endpoint/bucket/key, as MinIO
+	// expects, instead of bucket.endpoint/key
+	PathStyle  bool
+	HTTPClient *http.Client
+
+	now func() time.Time
+}
+
+const (
+	s3ClientName       = "s3"
+	s3Service          = "s3"
+	s3Algorithm        = "AWS4-HMAC-SHA256"
+	s3UnsignedPayload  = "UNSIGNED-PAYLOAD"
+	s3MaxSignedURLTTL  = 7 * 24 * time.Hour
+	s3TimestampFormat  = "20060102T150405Z"
+	s3DateStampFormat  = "20060102"
+	s3ErrorBodyMaxSize = 1024
+)
+
+// NewS3Storage returns a store for bucket at endpoint, e.g.
+// https://s3.amazonaws.com or http://minio:9000
+func NewS3Storage(endpoint, region, bucket, accessKey, secretKey string, pathStyle bool, timeout time.Duration) (*S3Storage, error) {
+	u, err := url.Parse(strings.TrimRight(endpoint, "/"))
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("s3 endpoint must be an absolute URL: %q", endpoint)
+	}
+	if bucket == "" || accessKey == "" || secretKey == "" {
+		return nil, errors.New("s3 bucket, access key and secret key are required")
+	}
+	if region == "" {
+		region = "us-east-1"
+	}
+	return &S3Storage{
+		Endpoint:   u,
+		Region:     region,
+		Bucket:     bucket,
+		AccessKey:  accessKey,
+		SecretKey:  secretKey,
+		PathStyle:  pathStyle,
+		HTTPClient: httpclient.New(s3ClientName, timeout),
+		now:        time.Now,
+	}, nil
+}
+
+func (s *S3Storage) Put(ctx context.Context, key, contentType string, data []byte) error {
+	key, err := cleanKey(key)
+	if err != nil {
+		return err
+	}
+	headers := map[string]string{}
+	if contentType != "" {
+		headers["content-type"] = contentType
+	}
+	resp, err := s.do(ctx, http.MethodPut, key, nil, headers, data)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return s3ResponseError(resp, key)
+}
+
+func (s *S3Storage) Get(ctx context.Context, key string) ([]byte, string, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return nil, "", err
+	}
+	resp, err := s.do(ctx, http.MethodGet, key, nil, nil, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	if err := s3ResponseError(resp, key); err != nil {
+		return nil, "", err
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", fmt.Errorf("s3 get %s: %w", key, err)
+	}
+	return data, resp.Header.Get("Content-Type"), nil
+}
+
+func (s *S3Storage) Delete(ctx context.Context, key string) error {
+	key, err := cleanKey(key)
+	if err != nil {
+		return err
+	}
+	resp, err := s.do(ctx, http.MethodDelete, key, nil, nil, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	return s3ResponseError(resp, key)
+}
+
+// SignedURL returns a URL that downloads key without credentials until ttl
+// passes; S3 refuses signatures valid for more than 7 days
+func (s *S3Storage) SignedURL(key string, ttl time.Duration) (string, error) {
+	if ttl <= 0 || ttl > s3MaxSignedURLTTL {
+		return "", fmt.Errorf("signed URL lifetime must be between 1s and %s", s3MaxSignedURLTTL)
+	}
+	key, err := cleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	now := s.now().UTC()
+	host, path := s.location(key)
+	scope := s.scope(now)
+
+	query := url.Values{}
+	query.Set("X-Amz-Algorithm", s3Algorithm)
+	query.Set("X-Amz-Credential", s.AccessKey+"/"+scope)
+	query.Set("X-Amz-Date", now.Format(s3TimestampFormat))
+	query.Set("X-Amz-Expires", strconv.Itoa(int(ttl/time.Second)))
+	query.Set("X-Amz-SignedHeaders", "host")
+
+	canonical := strings.Join([]string{
+		http.MethodGet,
+		path,
+		s3CanonicalQuery(query),
+		"host:" + host + "\n",
+		"host",
+		s3UnsignedPayload,
+	}, "\n")
+	query.Set("X-Amz-Signature", s.signature(now, scope, canonical))
+
+	return s.Endpoint.Scheme + "://" + host + path + "?" + s3CanonicalQuery(query), nil
+}
+
+// ApplyLifecycle replaces the bucket lifecycle configuration with one
+// expiration rule per prefix. S3 expires objects in whole days, so each
+// retention is rounded up to a day.
+func (s *S3Storage) ApplyLifecycle(ctx context.Context, rules []Rule) error {
+	if len(rules) == 0 {
+		return nil
+	}
+	doc := s3LifecycleConfiguration{}
+	for _, rule := range rules {
+		days := int((rule.ExpireAfter + 24*time.Hour - 1) / (24 * time.Hour))
+		doc.Rules = append(doc.Rules, s3LifecycleRule{
+			ID:         "expire-" + strings.Trim(strings.ReplaceAll(rule.Prefix, "/", "-"), "-"),
+			Prefix:     rule.Prefix,
+			Status:     "Enabled",
+			Expiration: s3LifecycleExpiration{Days: max(days, 1)},
+		})
+	}
+	body, err := xml.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	// S3 refuses lifecycle configurations without a Content-MD5 header
+	sum := md5.Sum(body)
+	headers := map[string]string{
+		"content-md5":  base64.StdEncoding.EncodeToString(sum[:]),
+		"content-type": "application/xml",
+	}
+	resp, err := s.do(ctx, http.MethodPut, "", url.Values{"lifecycle": {""}}, headers, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return s3ResponseError(resp, "?lifecycle")
+}
+
+type s3LifecycleConfiguration struct {
+	XMLName xml.Name          `xml:"LifecycleConfiguration"`
+	Rules   []s3LifecycleRule `xml:"Rule"`
+}
+
+type s3LifecycleRule struct {
+	ID         string                `xml:"ID"`
+	Prefix     string                `xml:"Filter>Prefix"`
+	Status     string                `xml:"Status"`
+	Expiration s3LifecycleExpiration `xml:"Expiration"`
+}
+
+type s3LifecycleExpiration struct {
+	Days int `xml:"Days"`
+}
+
+// do sends a request signed in the Authorization header. An empty key
+// addresses the bucket itself.
+func (s *S3Storage) do(ctx context.Context, method, key string, query url.Values, extra map[string]string, body []byte) (*http.Response, error) {
+	now := s.now().UTC()
+	host, path := s.location(key)
+	payloadHash := s3Hash(body)
+	canonicalQuery := s3CanonicalQuery(query)
+
+	target := s.Endpoint.Scheme + "://" + host + path
+	if canonicalQuery != "" {
+		target += "?" + canonicalQuery
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = int64(len(body))
+	headers := map[string]string{
+		"host":                 host,
+		"x-amz-content-sha256": payloadHash,
+		"x-amz-date":           now.Format(s3TimestampFormat),
+	}
+	for name, value := range extra {
+		headers[name] = value
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+		if name != "host" {
+			req.Header.Set(name, headers[name])
+		}
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonical := strings.Join([]string{method, path, canonicalQuery, canonicalHeaders.String(), signedHeaders, payloadHash}, "\n")
+	scope := s.scope(now)
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s3Algorithm, s.AccessKey, scope, signedHeaders, s.signature(now, scope, canonical)))
+
+	resp, err := s.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("s3 %s %s: %w", strings.ToLower(method), key, err)
+	}
+	return resp, nil
+}
+
+// location returns the host and escaped path of key
+func (s *S3Storage) location(key string) (string, string) {
+	escaped := s3EscapePath(strings.TrimLeft(key, "/"))
+	base := strings.TrimRight(s.Endpoint.EscapedPath(), "/")
+	if s.PathStyle {
+		if escaped == "" {
+			return s.Endpoint.Host, base + "/" + s3EscapePath(s.Bucket)
+		}
+		return s.Endpoint.Host, base + "/" + s3EscapePath(s.Bucket) + "/" + escaped
+	}
+	return s.Bucket + "." + s.Endpoint.Host, base + "/" + escaped
+}
+
+func (s *S3Storage) scope(now time.Time) string {
+	return now.Format(s3DateStampFormat) + "/" + s.Region + "/" + s3Service + "/aws4_request"
+}
+
+func (s *S3Storage) signature(now time.Time, scope, canonicalRequest string) string {
+	stringToSign := strings.Join([]string{
+		s3Algorithm,
+		now.Format(s3TimestampFormat),
+		scope,
+		s3Hash([]byte(canonicalRequest)),
+	}, "\n")
+	key := s3HMAC([]byte("AWS4"+s.SecretKey), now.Format(s3DateStampFormat))
+	key = s3HMAC(key, s.Region)
+	key = s3HMAC(key, s3Service)
+	key = s3HMAC(key, "aws4_request")
+	return hex.EncodeToString(s3HMAC(key, stringToSign))
+}
+
+func s3ResponseError(resp *http.Response, key string) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrNotFound
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, s3ErrorBodyMaxSize))
+	return fmt.Errorf("s3 %s: status %d: %s", key, resp.StatusCode, strings.TrimSpace(string(body)))
+}
+
+func s3Hash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func s3HMAC(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// s3EscapePath URI-encodes every path segment the way SigV4 expects
+func s3EscapePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = s3Escape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// s3CanonicalQuery sorts and encodes query parameters the way SigV4 expects
+func s3CanonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		for _, v := range query[k] {
+			parts = append(parts, s3Escape(k)+"="+s3Escape(v))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// s3Escape percent-encodes everything but the unreserved characters
+func s3Escape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if ('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z') || ('0' <= c && c <= '9') || c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
@@ -0,0 +1,95 @@
+// Package storage keeps private files such as KYC documents, exports and
+// templates in S3 compatible object storage or on local disk. Files are never
+// public; clients download them through short-lived signed URLs.
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/config"
+)
+
+var (
+	// ErrNotFound is returned for a key the store does not hold
+	ErrNotFound = errors.New("object not found")
+	// ErrInvalidKey is returned for an empty key or one escaping the store
+	ErrInvalidKey = errors.New("invalid object key")
+)
+
+// Store keeps files under slash separated keys such as kyc/<uuid>/<file>
+type Store interface {
+	Put(ctx context.Context, key, contentType string, data []byte) error
+	// Get returns the file and the content type it was stored with
+	Get(ctx context.Context, key string) ([]byte, string, error)
+	// Delete removes key; deleting a missing key is not an error
+	Delete(ctx context.Context, key string) error
+	// SignedURL returns a URL that downloads key without credentials until
+	// ttl passes
+	SignedURL(key string, ttl time.Duration) (string, error)
+	// ApplyLifecycle makes the files under each rule's prefix expire. It is
+	// idempotent and is run periodically; an empty rule set changes nothing.
+	ApplyLifecycle(ctx context.Context, rules []Rule) error
+}
+
+// Rule expires the files under Prefix ExpireAfter after they were stored
+type Rule struct {
+	Prefix      string
+	ExpireAfter time.Duration
+}
+
+// Rules converts the configured prefix to retention map into rules ordered
+// by prefix
+func Rules(lifecycle map[string]time.Duration) []Rule {
+	rules := make([]Rule, 0, len(lifecycle))
+	for prefix, expireAfter := range lifecycle {
+		rules = append(rules, Rule{Prefix: prefix, ExpireAfter: expireAfter})
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].Prefix < rules[j].Prefix })
+	return rules
+}
+
+// New returns the store cfg selects, or nil when storage is not configured
+func New(cfg config.StorageConfig) (Store, error) {
+	switch cfg.Driver {
+	case "":
+		return nil, nil
+	case config.StorageDriverS3:
+		s, err := NewS3Storage(cfg.Endpoint, cfg.Region, cfg.Bucket, cfg.AccessKey, cfg.SecretKey, cfg.PathStyle, cfg.Timeout)
+		if err != nil {
+			return nil, err
+		}
+		return s, nil
+	case config.StorageDriverLocal:
+		s, err := NewLocalStorage(cfg.LocalDir, cfg.LocalBaseURL, cfg.LocalSigningKey)
+		if err != nil {
+			return nil, err
+		}
+		return s, nil
+	}
+	return nil, fmt.Errorf("unknown storage driver %q", cfg.Driver)
+}
+
+// cleanKey normalizes key and rejects keys that are empty or climb out of
+// the store with ..
+func cleanKey(key string) (string, error) {
+	key = strings.TrimLeft(key, "/")
+	if key == "" || strings.Contains(key, `\`) {
+		return "", fmt.Errorf("%w: %q", ErrInvalidKey, key)
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == ".." {
+			return "", fmt.Errorf("%w: %q", ErrInvalidKey, key)
+		}
+	}
+	cleaned := path.Clean(key)
+	if cleaned == "." {
+		return "", fmt.Errorf("%w: %q", ErrInvalidKey, key)
+	}
+	return cleaned, nil
+}
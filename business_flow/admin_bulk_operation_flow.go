@@ -0,0 +1,259 @@
+package businessflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/repository"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const maxAdminBulkItems = 100
+
+// AdminBulkOperationFlow runs routine admin operations on many items at once.
+// Every item is handled on its own, like its single-item counterpart, so one
+// failing item neither stops nor rolls back the others; each gets a result.
+type AdminBulkOperationFlow interface {
+	SetCustomersActiveStatus(ctx context.Context, req *dto.AdminBulkSetCustomersActiveStatusRequest) (*dto.AdminBulkOperationResponse, error)
+	ReassignAgency(ctx context.Context, req *dto.AdminBulkReassignAgencyRequest) (*dto.AdminBulkOperationResponse, error)
+	ResyncCryptoPayments(ctx context.Context, req *dto.AdminBulkResyncCryptoPaymentsRequest, metadata *ClientMetadata) (*dto.AdminBulkOperationResponse, error)
+}
+
+type AdminBulkOperationFlowImpl struct {
+	db                 *gorm.DB
+	customerFlow       AdminCustomerManagementFlow
+	cryptoFlow         CryptoPaymentFlow
+	customerRepo       repository.CustomerRepository
+	agencyDiscountRepo repository.AgencyDiscountRepository
+	delegationRepo     repository.AgencyDelegationRepository
+	cprRepo            repository.CryptoPaymentRequestRepository
+	auditRepo          repository.AuditLogRepository
+}
+
+func NewAdminBulkOperationFlow(
+	db *gorm.DB,
+	customerFlow AdminCustomerManagementFlow,
+	cryptoFlow CryptoPaymentFlow,
+	customerRepo repository.CustomerRepository,
+	agencyDiscountRepo repository.AgencyDiscountRepository,
+	delegationRepo repository.AgencyDelegationRepository,
+	cprRepo repository.CryptoPaymentRequestRepository,
+	auditRepo repository.AuditLogRepository,
+) AdminBulkOperationFlow {
+	return &AdminBulkOperationFlowImpl{
+		db:                 db,
+		customerFlow:       customerFlow,
+		cryptoFlow:         cryptoFlow,
+		customerRepo:       customerRepo,
+		agencyDiscountRepo: agencyDiscountRepo,
+		delegationRepo:     delegationRepo,
+		cprRepo:            cprRepo,
+		auditRepo:          auditRepo,
+	}
+}
+
+// SetCustomersActiveStatus activates or deactivates each customer like the
+// single active-status endpoint, ending the sessions of deactivated ones
+func (f *AdminBulkOperationFlowImpl) SetCustomersActiveStatus(ctx context.Context, req *dto.AdminBulkSetCustomersActiveStatusRequest) (*dto.AdminBulkOperationResponse, error) {
+	if req == nil || !validAdminBulkCount(len(req.CustomerIDs)) {
+		return nil, NewBusinessError("ADMIN_BULK_COUNT_INVALID", "Bulk operations take 1 to 100 items", ErrAdminBulkCountInvalid)
+	}
+	return runAdminBulk(customerIDStrings(req.CustomerIDs), func(i int) (dto.AdminBulkItemResult, error) {
+		res, err := f.customerFlow.SetCustomerActiveStatus(ctx, &dto.AdminSetCustomerActiveStatusRequest{
+			CustomerID: req.CustomerIDs[i],
+			IsActive:   req.IsActive,
+		})
+		if err != nil {
+			return dto.AdminBulkItemResult{}, err
+		}
+		return dto.AdminBulkItemResult{Changed: res.Message != "No change required"}, nil
+	}), nil
+}
+
+// ReassignAgency moves each customer under the given agency. The discount
+// of the previous agency is expired and the new agency starts at the default
+// signup discount; a delegation granted to the previous agency is revoked.
+func (f *AdminBulkOperationFlowImpl) ReassignAgency(ctx context.Context, req *dto.AdminBulkReassignAgencyRequest) (*dto.AdminBulkOperationResponse, error) {
+	if req == nil || !validAdminBulkCount(len(req.CustomerIDs)) {
+		return nil, NewBusinessError("ADMIN_BULK_COUNT_INVALID", "Bulk operations take 1 to 100 items", ErrAdminBulkCountInvalid)
+	}
+	agency, err := getAgency(ctx, f.customerRepo, req.AgencyID)
+	if err != nil {
+		if IsAgencyNotFound(err) {
+			return nil, NewBusinessError("AGENCY_NOT_FOUND", "Agency not found", err)
+		}
+		if IsAgencyInactive(err) {
+			return nil, NewBusinessError("AGENCY_INACTIVE", "Agency is inactive", err)
+		}
+		return nil, NewBusinessError("ADMIN_BULK_REASSIGN_AGENCY_FAILED", "Failed to get agency", err)
+	}
+
+	return runAdminBulk(customerIDStrings(req.CustomerIDs), func(i int) (dto.AdminBulkItemResult, error) {
+		changed, err := f.reassignAgency(ctx, req.CustomerIDs[i], agency, req.Reason)
+		return dto.AdminBulkItemResult{Changed: changed}, err
+	}), nil
+}
+
+// reassignAgency moves one customer under agency in its own transaction and
+// reports whether anything changed
+func (f *AdminBulkOperationFlowImpl) reassignAgency(ctx context.Context, customerID uint, agency models.Customer, reason *string) (bool, error) {
+	customer, err := f.customerRepo.ByID(ctx, customerID)
+	if err != nil {
+		return false, err
+	}
+	if customer == nil {
+		return false, NewBusinessError("CUSTOMER_NOT_FOUND", "Customer not found", ErrCustomerNotFound)
+	}
+	if isSystemOrTaxCustomer(customer) {
+		return false, NewBusinessError("FORBIDDEN_OPERATION", "System and tax users cannot be modified", ErrAccountInactive)
+	}
+	if customer.ID == agency.ID {
+		return false, NewBusinessError("AGENCY_REASSIGN_SELF", "Customer cannot be its own referrer agency", ErrAgencyReassignSelf)
+	}
+	previous := customer.ReferrerAgencyID
+	if previous != nil && *previous == agency.ID {
+		return false, nil
+	}
+
+	meta := map[string]any{
+		"previous_agency_id": previous,
+		"agency_id":          agency.ID,
+	}
+	if reason != nil {
+		meta["reason"] = *reason
+	}
+	err = repository.WithTransaction(ctx, f.db, func(txCtx context.Context) error {
+		if err := f.customerRepo.UpdateReferrerAgency(txCtx, customer.ID, agency.ID); err != nil {
+			return err
+		}
+		now := utils.UTCNow()
+		if previous != nil {
+			if err := f.agencyDiscountRepo.ExpireActiveByAgencyAndCustomer(txCtx, *previous, customer.ID, now); err != nil {
+				return err
+			}
+		}
+
+		// Same default as signup: agencies under an agency get half, others none
+		rate := 0.0
+		if customer.IsAgency() {
+			rate = 0.5
+		}
+		discountReason := "Created via agency reassignment"
+		if reason != nil && *reason != "" {
+			discountReason = *reason
+		}
+		if err := f.agencyDiscountRepo.Save(txCtx, &models.AgencyDiscount{
+			UUID:          uuid.New(),
+			AgencyID:      agency.ID,
+			CustomerID:    customer.ID,
+			DiscountRate:  rate,
+			EffectiveFrom: now,
+			Reason:        &discountReason,
+		}); err != nil {
+			return err
+		}
+
+		grant, err := f.delegationRepo.ActiveByCustomer(txCtx, customer.ID)
+		if err != nil {
+			return err
+		}
+		if grant != nil && grant.AgencyID != agency.ID {
+			grant.RevokedAt = &now
+			grant.UpdatedAt = now
+			if err := f.delegationRepo.Update(txCtx, grant); err != nil {
+				return err
+			}
+			meta["revoked_delegation_id"] = grant.ID
+		}
+		return nil
+	})
+	logAdminAction(ctx, f.auditRepo, models.AuditActionAdminCustomerAgencyReassigned,
+		fmt.Sprintf("Admin moved customer %d under agency %d", customer.ID, agency.ID), err == nil, &customer.ID, meta, err)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// ResyncCryptoPayments re-runs the provider callback handling of each crypto
+// payment request: its state and deposits are pulled from the provider and
+// the wallet is credited once they settle it, as a webhook would do
+func (f *AdminBulkOperationFlowImpl) ResyncCryptoPayments(ctx context.Context, req *dto.AdminBulkResyncCryptoPaymentsRequest, metadata *ClientMetadata) (*dto.AdminBulkOperationResponse, error) {
+	if req == nil || !validAdminBulkCount(len(req.UUIDs)) {
+		return nil, NewBusinessError("ADMIN_BULK_COUNT_INVALID", "Bulk operations take 1 to 100 items", ErrAdminBulkCountInvalid)
+	}
+	return runAdminBulk(req.UUIDs, func(i int) (dto.AdminBulkItemResult, error) {
+		cpr, err := f.cprRepo.ByUUID(ctx, req.UUIDs[i])
+		if err != nil {
+			return dto.AdminBulkItemResult{}, err
+		}
+		if cpr == nil {
+			return dto.AdminBulkItemResult{}, NewBusinessError("CRYPTO_REQUEST_NOT_FOUND", "Crypto payment request not found", ErrCryptoRequestNotFound)
+		}
+		before := cpr.Status
+		res, err := f.cryptoFlow.GetStatus(ctx, &dto.GetCryptoPaymentStatusRequest{CustomerID: cpr.CustomerID, UUID: req.UUIDs[i]}, metadata)
+		meta := map[string]any{"crypto_payment_request_uuid": req.UUIDs[i], "previous_status": before}
+		if res != nil {
+			meta["status"] = res.Status
+		}
+		logAdminAction(ctx, f.auditRepo, models.AuditActionAdminCryptoPaymentResynced,
+			fmt.Sprintf("Admin resynced crypto payment request %s", req.UUIDs[i]), err == nil, &cpr.CustomerID, meta, err)
+		if err != nil {
+			return dto.AdminBulkItemResult{}, err
+		}
+		return dto.AdminBulkItemResult{Changed: res.Status != string(before), Status: res.Status}, nil
+	}), nil
+}
+
+func validAdminBulkCount(n int) bool {
+	return n > 0 && n <= maxAdminBulkItems
+}
+
+func customerIDStrings(ids []uint) []string {
+	out := make([]string, len(ids))
+	for i, id := range ids {
+		out[i] = strconv.FormatUint(uint64(id), 10)
+	}
+	return out
+}
+
+// runAdminBulk runs item for every ID in order and collects the results
+func runAdminBulk(ids []string, item func(i int) (dto.AdminBulkItemResult, error)) *dto.AdminBulkOperationResponse {
+	resp := &dto.AdminBulkOperationResponse{Results: make([]dto.AdminBulkItemResult, len(ids))}
+	for i, id := range ids {
+		result, err := item(i)
+		if err != nil {
+			result = failedAdminBulkItem(err)
+		} else {
+			result.Success = true
+			resp.Succeeded++
+		}
+		result.Index = i
+		result.ID = id
+		resp.Results[i] = result
+	}
+	resp.Failed = len(ids) - resp.Succeeded
+	resp.Message = fmt.Sprintf("%d of %d items succeeded", resp.Succeeded, len(ids))
+	return resp
+}
+
+// failedAdminBulkItem reports the error code and cause of a failed item
+func failedAdminBulkItem(err error) dto.AdminBulkItemResult {
+	result := dto.AdminBulkItemResult{ErrorCode: "ADMIN_BULK_ITEM_FAILED", Error: err.Error()}
+	var be *BusinessError
+	if errors.As(err, &be) {
+		result.ErrorCode = be.Code
+		if be.Err != nil {
+			result.Error = be.Err.Error()
+		} else {
+			result.Error = be.Message
+		}
+	}
+	return result
+}
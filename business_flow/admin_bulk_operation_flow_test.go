@@ -0,0 +1,53 @@
+package businessflow
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+)
+
+func TestAdminBulkOperationsRejectCount(t *testing.T) {
+	t.Parallel()
+
+	flow := &AdminBulkOperationFlowImpl{}
+	ctx := context.Background()
+	for _, n := range []int{0, maxAdminBulkItems + 1} {
+		if _, err := flow.SetCustomersActiveStatus(ctx, &dto.AdminBulkSetCustomersActiveStatusRequest{CustomerIDs: make([]uint, n)}); !IsAdminBulkCountInvalid(err) {
+			t.Fatalf("active status with %d items: got %v, want ErrAdminBulkCountInvalid", n, err)
+		}
+		if _, err := flow.ReassignAgency(ctx, &dto.AdminBulkReassignAgencyRequest{CustomerIDs: make([]uint, n), AgencyID: 1}); !IsAdminBulkCountInvalid(err) {
+			t.Fatalf("reassign agency with %d items: got %v, want ErrAdminBulkCountInvalid", n, err)
+		}
+		if _, err := flow.ResyncCryptoPayments(ctx, &dto.AdminBulkResyncCryptoPaymentsRequest{UUIDs: make([]string, n)}, nil); !IsAdminBulkCountInvalid(err) {
+			t.Fatalf("crypto resync with %d items: got %v, want ErrAdminBulkCountInvalid", n, err)
+		}
+	}
+}
+
+func TestRunAdminBulkReportsEveryItem(t *testing.T) {
+	t.Parallel()
+
+	resp := runAdminBulk([]string{"7", "8", "9"}, func(i int) (dto.AdminBulkItemResult, error) {
+		switch i {
+		case 1:
+			return dto.AdminBulkItemResult{}, NewBusinessError("AGENCY_REASSIGN_SELF", "Customer cannot be its own referrer agency", ErrAgencyReassignSelf)
+		case 2:
+			return dto.AdminBulkItemResult{}, errors.New("boom")
+		}
+		return dto.AdminBulkItemResult{Changed: true}, nil
+	})
+	if resp.Succeeded != 1 || resp.Failed != 2 || len(resp.Results) != 3 {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+	if r := resp.Results[0]; !r.Success || !r.Changed || r.ID != "7" {
+		t.Fatalf("unexpected first result %+v", r)
+	}
+	if r := resp.Results[1]; r.Success || r.Index != 1 || r.ID != "8" || r.ErrorCode != "AGENCY_REASSIGN_SELF" || r.Error != ErrAgencyReassignSelf.Error() {
+		t.Fatalf("unexpected second result %+v", r)
+	}
+	if r := resp.Results[2]; r.ErrorCode != "ADMIN_BULK_ITEM_FAILED" || r.Error != "boom" {
+		t.Fatalf("unexpected third result %+v", r)
+	}
+}
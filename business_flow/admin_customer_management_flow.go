@@ -8,17 +8,27 @@ import (
 	"time"
 
 	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/app/services"
+	"github.com/amirphl/Yamata-no-Orochi/config"
 	"github.com/amirphl/Yamata-no-Orochi/models"
 	"github.com/amirphl/Yamata-no-Orochi/repository"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/google/uuid"
 )
 
 // AdminCustomerManagementFlow exposes admin customer management use cases
 type AdminCustomerManagementFlow interface {
-	ListCustomers(ctx context.Context) (*dto.AdminListCustomersResponse, error)
+	ListCustomers(ctx context.Context, req *dto.AdminListCustomersRequest) (*dto.AdminListCustomersResponse, error)
+	SearchCustomers(ctx context.Context, req *dto.AdminSearchCustomersRequest) (*dto.AdminSearchCustomersResponse, error)
 	GetCustomersShares(ctx context.Context, req *dto.AdminCustomersSharesRequest) (*dto.AdminCustomersSharesResponse, error)
 	GetCustomerWithCampaigns(ctx context.Context, customerID uint) (*dto.AdminCustomerWithCampaignsResponse, error)
 	GetCustomerDiscountsHistory(ctx context.Context, customerID uint) (*dto.AdminCustomerDiscountHistoryResponse, error)
 	SetCustomerActiveStatus(ctx context.Context, req *dto.AdminSetCustomerActiveStatusRequest) (*dto.AdminSetCustomerActiveStatusResponse, error)
+	SetCustomerSuspension(ctx context.Context, req *dto.AdminSetCustomerSuspensionRequest) (*dto.AdminSetCustomerSuspensionResponse, error)
+	GetCustomerSendingQuota(ctx context.Context, customerID uint) (*dto.AdminCustomerSendingQuotaResponse, error)
+	SetCustomerSendingQuota(ctx context.Context, req *dto.AdminSetCustomerSendingQuotaRequest) (*dto.AdminCustomerSendingQuotaResponse, error)
+	ForceLogoutCustomer(ctx context.Context, customerID uint) (*dto.AdminForceLogoutCustomerResponse, error)
+	ImpersonateCustomer(ctx context.Context, customerID uint) (*dto.AdminImpersonateCustomerResponse, error)
 }
 
 // AdminCustomerManagementFlowImpl implements AdminCustomerManagementFlow
@@ -29,6 +39,12 @@ type AdminCustomerManagementFlowImpl struct {
 	auditRepo        repository.AuditLogRepository
 	lineNumberRepo   repository.LineNumberRepository
 	segmentPriceRepo repository.SegmentPriceFactorRepository
+	sendingQuotaRepo repository.CustomerSendingQuotaRepository
+	sessionRepo      repository.CustomerSessionRepository
+	sessionStore     services.SessionStore
+	tokenService     services.TokenService
+	impersonationTTL time.Duration
+	kycConfig        config.KYCConfig
 }
 
 const (
@@ -43,6 +59,12 @@ func NewAdminCustomerManagementFlow(
 	auditRepo repository.AuditLogRepository,
 	lineNumberRepo repository.LineNumberRepository,
 	segmentPriceRepo repository.SegmentPriceFactorRepository,
+	sendingQuotaRepo repository.CustomerSendingQuotaRepository,
+	sessionRepo repository.CustomerSessionRepository,
+	sessionStore services.SessionStore,
+	tokenService services.TokenService,
+	impersonationTTL time.Duration,
+	kycConfig config.KYCConfig,
 ) AdminCustomerManagementFlow {
 	return &AdminCustomerManagementFlowImpl{
 		transactionRepo:  transactionRepo,
@@ -51,12 +73,23 @@ func NewAdminCustomerManagementFlow(
 		auditRepo:        auditRepo,
 		lineNumberRepo:   lineNumberRepo,
 		segmentPriceRepo: segmentPriceRepo,
+		sendingQuotaRepo: sendingQuotaRepo,
+		sessionRepo:      sessionRepo,
+		sessionStore:     sessionStore,
+		tokenService:     tokenService,
+		impersonationTTL: impersonationTTL,
+		kycConfig:        kycConfig,
 	}
 }
 
-// ListCustomers returns all customers except system and tax users.
-func (f *AdminCustomerManagementFlowImpl) ListCustomers(ctx context.Context) (*dto.AdminListCustomersResponse, error) {
-	customers, err := f.customerRepo.ByFilter(ctx, models.CustomerFilter{}, "id DESC", 0, 0)
+// ListCustomers returns all customers except system and tax users, filtered
+// and sorted by the request's list query.
+func (f *AdminCustomerManagementFlowImpl) ListCustomers(ctx context.Context, req *dto.AdminListCustomersRequest) (*dto.AdminListCustomersResponse, error) {
+	list, err := models.CustomerListFields.Parse(req.Filter, req.Sort)
+	if err != nil {
+		return nil, NewBusinessError("LIST_QUERY_INVALID", "Invalid filter or sort", err)
+	}
+	customers, err := f.customerRepo.ByFilter(ctx, models.CustomerFilter{List: list}, "id DESC", 0, 0)
 	if err != nil {
 		return nil, NewBusinessError("GET_ADMIN_CUSTOMERS_LIST_FAILED", "Failed to list customers", err)
 	}
@@ -80,6 +113,71 @@ func (f *AdminCustomerManagementFlowImpl) ListCustomers(ctx context.Context) (*d
 	return resp, nil
 }
 
+// SearchCustomers returns a page of customers matching a search term, best
+// match first. System and tax accounts are never returned.
+func (f *AdminCustomerManagementFlowImpl) SearchCustomers(ctx context.Context, req *dto.AdminSearchCustomersRequest) (*dto.AdminSearchCustomersResponse, error) {
+	page, limit := req.Page, req.Limit
+	if page <= 0 {
+		page = 1
+	}
+	if limit <= 0 {
+		limit = 10
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	hits, total, err := f.customerRepo.Search(ctx, repository.CustomerSearchQuery{
+		Term:          req.Query,
+		ExcludeEmails: []string{systemCustomerEmail, taxCustomerEmail},
+		Limit:         limit,
+		Offset:        (page - 1) * limit,
+	})
+	if err != nil {
+		return nil, NewBusinessError("ADMIN_SEARCH_CUSTOMERS_FAILED", "Failed to search customers", err)
+	}
+
+	ids := make([]uint, 0, len(hits))
+	for _, hit := range hits {
+		ids = append(ids, hit.CustomerID)
+	}
+	customers, err := f.customerRepo.FindByIDs(ctx, ids)
+	if err != nil {
+		return nil, NewBusinessError("ADMIN_SEARCH_CUSTOMERS_FAILED", "Failed to load matching customers", err)
+	}
+	byID := make(map[uint]*models.Customer, len(customers))
+	for _, cust := range customers {
+		byID[cust.ID] = cust
+	}
+
+	// Keep the order of the ranked hits
+	items := make([]dto.AdminCustomerSearchItem, 0, len(hits))
+	for _, hit := range hits {
+		cust, ok := byID[hit.CustomerID]
+		if !ok {
+			continue
+		}
+		items = append(items, dto.AdminCustomerSearchItem{AdminCustomerDetailDTO: toAdminCustomerDetailDTO(*cust), Rank: hit.Rank})
+	}
+
+	logAdminAction(ctx, f.auditRepo, models.AuditActionAdminSearchCustomers, "Admin searched customers", true, nil, map[string]any{
+		"query":          req.Query,
+		"page":           page,
+		"total_matches":  total,
+		"total_returned": len(items),
+	}, nil)
+	return &dto.AdminSearchCustomersResponse{
+		Message: "Customers retrieved successfully",
+		Items:   items,
+		Pagination: dto.PaginationInfo{
+			Total:      total,
+			Page:       page,
+			Limit:      limit,
+			TotalPages: int((total + int64(limit) - 1) / int64(limit)),
+		},
+	}, nil
+}
+
 // GetCustomersShares returns aggregated shares per customer with optional date range
 func (f *AdminCustomerManagementFlowImpl) GetCustomersShares(ctx context.Context, req *dto.AdminCustomersSharesRequest) (*dto.AdminCustomersSharesResponse, error) {
 	var err error
@@ -360,11 +458,15 @@ func toAdminCustomerDetailDTO(c models.Customer) dto.AdminCustomerDetailDTO {
 		IsEmailVerified:         c.IsEmailVerified,
 		IsMobileVerified:        c.IsMobileVerified,
 		IsActive:                c.IsActive,
+		IsSandbox:               c.InSandbox(),
+		Suspension:              customerSuspensionDTO(&c),
+		SuspensionNote:          c.SuspensionNote,
 		CreatedAt:               c.CreatedAt,
 		UpdatedAt:               c.UpdatedAt,
 		EmailVerifiedAt:         c.EmailVerifiedAt,
 		MobileVerifiedAt:        c.MobileVerifiedAt,
 		LastLoginAt:             c.LastLoginAt,
+		PasswordHashAlgorithm:   services.PasswordHashAlgorithm(c.PasswordHash),
 	}
 }
 
@@ -447,6 +549,17 @@ func (f *AdminCustomerManagementFlowImpl) SetCustomerActiveStatus(ctx context.Co
 		}, err)
 		return nil, NewBusinessError("SET_CUSTOMER_ACTIVE_STATUS_FAILED", "Failed to update active status", err)
 	}
+	// A deactivated customer must not keep using the sessions it already has
+	if !req.IsActive {
+		if _, err := f.endCustomerSessions(ctx, req.CustomerID); err != nil {
+			logAdminAction(ctx, f.auditRepo, models.AuditActionAdminSetCustomerStatus, "Admin toggled customer active status", false, &req.CustomerID, map[string]any{
+				"desired_active": req.IsActive,
+				"previous":       prevActive,
+				"changed":        true,
+			}, err)
+			return nil, NewBusinessError("SET_CUSTOMER_ACTIVE_STATUS_FAILED", "Failed to end customer sessions", err)
+		}
+	}
 	resp := &dto.AdminSetCustomerActiveStatusResponse{
 		Message:  "Customer status updated successfully",
 		IsActive: req.IsActive,
@@ -459,6 +572,194 @@ func (f *AdminCustomerManagementFlowImpl) SetCustomerActiveStatus(ctx context.Co
 	return resp, nil
 }
 
+// SetCustomerSuspension suspends a customer at a level, escalating or easing
+// an existing suspension, or lifts it. Unlike deactivation the customer keeps
+// their sessions; checkCustomerPolicy blocks what the level restricts.
+func (f *AdminCustomerManagementFlowImpl) SetCustomerSuspension(ctx context.Context, req *dto.AdminSetCustomerSuspensionRequest) (*dto.AdminSetCustomerSuspensionResponse, error) {
+	if req == nil || req.CustomerID == 0 {
+		return nil, NewBusinessError("VALIDATION_ERROR", "Invalid request", nil)
+	}
+	var level *models.CustomerSuspensionLevel
+	var reason *models.CustomerSuspensionReason
+	if req.Level != "" {
+		l, r := models.CustomerSuspensionLevel(req.Level), models.CustomerSuspensionReason(req.Reason)
+		if !l.Valid() {
+			return nil, NewBusinessError("CUSTOMER_SUSPENSION_INVALID", "Invalid suspension level", ErrCustomerSuspensionLevelInvalid)
+		}
+		if !r.Valid() {
+			return nil, NewBusinessError("CUSTOMER_SUSPENSION_INVALID", "Invalid suspension reason", ErrCustomerSuspensionReasonInvalid)
+		}
+		level, reason = &l, &r
+	}
+
+	customer, err := f.customerRepo.ByID(ctx, req.CustomerID)
+	if err != nil {
+		return nil, NewBusinessError("SET_CUSTOMER_SUSPENSION_FAILED", "Failed to get customer", err)
+	}
+	if customer == nil {
+		return nil, NewBusinessError("CUSTOMER_NOT_FOUND", "Customer not found", ErrCustomerNotFound)
+	}
+	if level != nil && isSystemOrTaxCustomer(customer) {
+		return nil, NewBusinessError("FORBIDDEN_OPERATION", "System and Tax users cannot be suspended", ErrForbidden)
+	}
+
+	prevLevel, prevReason := customer.Suspension()
+	meta := map[string]any{
+		"level":           req.Level,
+		"reason":          req.Reason,
+		"previous_level":  string(prevLevel),
+		"previous_reason": string(prevReason),
+	}
+	if level == nil && prevLevel == "" {
+		return &dto.AdminSetCustomerSuspensionResponse{Message: "No change required", CustomerID: customer.ID}, nil
+	}
+	if err := f.customerRepo.UpdateSuspension(ctx, customer.ID, level, reason, req.Note); err != nil {
+		logAdminAction(ctx, f.auditRepo, models.AuditActionAdminCustomerSuspensionUpdate, "Admin customer suspension update failed", false, &customer.ID, meta, err)
+		return nil, NewBusinessError("SET_CUSTOMER_SUSPENSION_FAILED", "Failed to update customer suspension", err)
+	}
+
+	resp := &dto.AdminSetCustomerSuspensionResponse{Message: "Customer suspension lifted", CustomerID: customer.ID}
+	if level != nil {
+		resp.Message = "Customer suspended"
+		resp.Suspension = &dto.CustomerSuspensionDTO{Level: string(*level), Reason: string(*reason), SuspendedAt: utils.ToPtr(utils.UTCNow())}
+	}
+	logAdminAction(ctx, f.auditRepo, models.AuditActionAdminCustomerSuspensionUpdate, resp.Message, true, &customer.ID, meta, nil)
+	return resp, nil
+}
+
+// ForceLogoutCustomer ends every active session of a customer; their tokens
+// stop working on the next request
+func (f *AdminCustomerManagementFlowImpl) ForceLogoutCustomer(ctx context.Context, customerID uint) (*dto.AdminForceLogoutCustomerResponse, error) {
+	if customerID == 0 {
+		return nil, NewBusinessError("VALIDATION_ERROR", "Invalid customer id", nil)
+	}
+	customer, err := f.customerRepo.ByID(ctx, customerID)
+	if err != nil {
+		return nil, NewBusinessError("FORCE_LOGOUT_CUSTOMER_FAILED", "Failed to get customer", err)
+	}
+	if customer == nil {
+		return nil, NewBusinessError("CUSTOMER_NOT_FOUND", "Customer not found", ErrCustomerNotFound)
+	}
+
+	ended, err := f.endCustomerSessions(ctx, customerID)
+	if err != nil {
+		logAdminAction(ctx, f.auditRepo, models.AuditActionAdminForceLogoutCustomer, "Admin forced customer logout", false, &customerID, nil, err)
+		return nil, NewBusinessError("FORCE_LOGOUT_CUSTOMER_FAILED", "Failed to end customer sessions", err)
+	}
+
+	logAdminAction(ctx, f.auditRepo, models.AuditActionAdminForceLogoutCustomer, "Admin forced customer logout", true, &customerID, map[string]any{
+		"ended_sessions": ended,
+	}, nil)
+	return &dto.AdminForceLogoutCustomerResponse{
+		Message:       "Customer sessions ended successfully",
+		EndedSessions: ended,
+	}, nil
+}
+
+// ImpersonateCustomer issues the admin of ctx a short-lived access token of
+// the customer, without a refresh token. It gets its own session, listed to
+// the customer as a support session, and requests made with it are audited
+// with both the admin and the customer.
+func (f *AdminCustomerManagementFlowImpl) ImpersonateCustomer(ctx context.Context, customerID uint) (*dto.AdminImpersonateCustomerResponse, error) {
+	if customerID == 0 {
+		return nil, NewBusinessError("VALIDATION_ERROR", "Invalid customer id", nil)
+	}
+	adminID, ok := adminIDFromContext(ctx)
+	if !ok {
+		return nil, NewBusinessError("IMPERSONATE_CUSTOMER_FAILED", "Failed to impersonate customer", ErrAdminNotFound)
+	}
+	customer, err := f.customerRepo.ByID(ctx, customerID)
+	if err != nil {
+		return nil, NewBusinessError("IMPERSONATE_CUSTOMER_FAILED", "Failed to get customer", err)
+	}
+	if customer == nil {
+		return nil, NewBusinessError("CUSTOMER_NOT_FOUND", "Customer not found", ErrCustomerNotFound)
+	}
+	if isSystemOrTaxCustomer(customer) {
+		return nil, NewBusinessError("FORBIDDEN_OPERATION", "System and Tax users cannot be impersonated", nil)
+	}
+	if !utils.IsTrue(customer.IsActive) {
+		return nil, NewBusinessError("FORBIDDEN_OPERATION", "Inactive customers cannot be impersonated", nil)
+	}
+
+	session, err := f.createImpersonationSession(ctx, customerID, adminID)
+	if err != nil {
+		logAdminAction(ctx, f.auditRepo, models.AuditActionAdminImpersonateCustomer, "Admin impersonation of customer failed", false, &customerID, nil, err)
+		return nil, NewBusinessError("IMPERSONATE_CUSTOMER_FAILED", "Failed to impersonate customer", err)
+	}
+	activateSession(ctx, f.sessionStore, session)
+
+	logAdminAction(ctx, f.auditRepo, models.AuditActionAdminImpersonateCustomer, "Admin started impersonating customer", true, &customerID, map[string]any{
+		"session_id": session.ID,
+		"expires_at": session.ExpiresAt.Format(time.RFC3339),
+	}, nil)
+	return &dto.AdminImpersonateCustomerResponse{
+		Message:        "Impersonation token issued successfully",
+		CustomerID:     customerID,
+		ImpersonatedBy: adminID,
+		AccessToken:    session.SessionToken,
+		TokenType:      "Bearer",
+		ExpiresIn:      int(f.impersonationTTL / time.Second),
+		ExpiresAt:      session.ExpiresAt.Format(time.RFC3339),
+	}, nil
+}
+
+func (f *AdminCustomerManagementFlowImpl) createImpersonationSession(ctx context.Context, customerID, adminID uint) (*models.CustomerSession, error) {
+	token, err := f.tokenService.GenerateImpersonationToken(customerID, adminID, f.impersonationTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	// The session describes the admin's device
+	userAgent := stringFromCtx(ctx, utils.UserAgentKey)
+	device := parseUserAgent(userAgent)
+	device.ImpersonatedBy = adminID
+	deviceInfo, err := json.Marshal(device)
+	if err != nil {
+		return nil, err
+	}
+	session := &models.CustomerSession{
+		CorrelationID:  uuid.New(),
+		CustomerID:     customerID,
+		SessionToken:   token,
+		DeviceInfo:     deviceInfo,
+		IsActive:       utils.ToPtr(true),
+		ExpiresAt:      utils.UTCNowAdd(f.impersonationTTL),
+		LastAccessedAt: utils.UTCNow(),
+	}
+	if ip := stringFromCtx(ctx, utils.IPAddressKey); ip != "" {
+		session.IPAddress = &ip
+	}
+	if userAgent != "" {
+		session.UserAgent = &userAgent
+	}
+	if err := f.sessionRepo.Save(ctx, session); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// endCustomerSessions deactivates the active sessions of a customer and
+// revokes them in the session store
+func (f *AdminCustomerManagementFlowImpl) endCustomerSessions(ctx context.Context, customerID uint) (int, error) {
+	sessions, err := f.sessionRepo.ByFilter(ctx, models.CustomerSessionFilter{
+		CustomerID: &customerID,
+		IsActive:   utils.ToPtr(true),
+	}, "", 0, 0)
+	if err != nil {
+		return 0, err
+	}
+	for _, session := range sessions {
+		session.IsActive = utils.ToPtr(false)
+		session.ExpiresAt = utils.UTCNow()
+		if err := f.sessionRepo.Update(ctx, session); err != nil {
+			return 0, err
+		}
+	}
+	revokeCustomerSessions(ctx, f.sessionStore, customerID)
+	return len(sessions), nil
+}
+
 func isSystemOrTaxCustomer(cust *models.Customer) bool {
 	if cust == nil {
 		return false
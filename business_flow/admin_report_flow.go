@@ -0,0 +1,261 @@
+package businessflow
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/config"
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/repository"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+)
+
+const (
+	adminReportDefaultDailyDays  = 30
+	adminReportDefaultWeeklyDays = 12 * 7
+	adminReportMaxRange          = 366 * 24 * time.Hour
+	adminTopCustomersLimit       = 10
+)
+
+// AdminReportFlow serves the admin financial dashboard
+type AdminReportFlow interface {
+	GetFinancialReport(ctx context.Context, req *dto.AdminFinancialReportRequest) (*dto.AdminFinancialReportResponse, error)
+	GetTopCustomers(ctx context.Context, req *dto.AdminTopCustomersReportRequest) (*dto.AdminTopCustomersReportResponse, error)
+	GetWalletLiability(ctx context.Context) (*dto.AdminWalletLiabilityResponse, error)
+}
+
+// AdminReportFlowImpl implements AdminReportFlow
+type AdminReportFlowImpl struct {
+	transactionRepo     repository.TransactionRepository
+	balanceSnapshotRepo repository.BalanceSnapshotRepository
+	rollupRepo          repository.ReportRollupRepository
+	sysCfg              config.SystemConfig
+}
+
+func NewAdminReportFlow(
+	transactionRepo repository.TransactionRepository,
+	balanceSnapshotRepo repository.BalanceSnapshotRepository,
+	rollupRepo repository.ReportRollupRepository,
+	sysCfg config.SystemConfig,
+) AdminReportFlow {
+	return &AdminReportFlowImpl{
+		transactionRepo:     transactionRepo,
+		balanceSnapshotRepo: balanceSnapshotRepo,
+		rollupRepo:          rollupRepo,
+		sysCfg:              sysCfg,
+	}
+}
+
+// GetFinancialReport reports revenue, tax, system and agency shares and
+// campaign spend per Tehran day or week. Without dates it covers the last
+// 30 days, or the last 12 weeks when weekly. Ranges of whole Tehran days the
+// revenue_daily rollup has been refreshed through are read from it.
+func (f *AdminReportFlowImpl) GetFinancialReport(ctx context.Context, req *dto.AdminFinancialReportRequest) (*dto.AdminFinancialReportResponse, error) {
+	granularity := strings.TrimSpace(req.Granularity)
+	if granularity == "" {
+		granularity = repository.ReportGranularityDaily
+	}
+	if granularity != repository.ReportGranularityDaily && granularity != repository.ReportGranularityWeekly {
+		return nil, NewBusinessError("VALIDATION_ERROR", "granularity must be daily or weekly", nil)
+	}
+	defaultDays := adminReportDefaultDailyDays
+	if granularity == repository.ReportGranularityWeekly {
+		defaultDays = adminReportDefaultWeeklyDays
+	}
+	startDate, endDate, err := parseAdminReportRange(req.StartDate, req.EndDate, defaultDays, utils.UTCNow())
+	if err != nil {
+		return nil, err
+	}
+
+	rows, refreshedAt, err := f.rollupFinancialPeriods(ctx, granularity, startDate, endDate)
+	if err != nil {
+		return nil, NewBusinessError("ADMIN_FINANCIAL_REPORT_FAILED", "Failed to get financial report", err)
+	}
+	if rows == nil {
+		rows, err = f.transactionRepo.AggregateFinancialPeriods(ctx, granularity, startDate, endDate)
+		if err != nil {
+			return nil, NewBusinessError("ADMIN_FINANCIAL_REPORT_FAILED", "Failed to get financial report", err)
+		}
+	}
+
+	periods := make([]dto.AdminFinancialReportPeriod, 0, len(rows))
+	var totals dto.AdminFinancialReportTotals
+	for _, r := range rows {
+		p := dto.AdminFinancialReportPeriod{
+			Period: r.Period,
+			AdminFinancialReportTotals: dto.AdminFinancialReportTotals{
+				Charges:            r.Charges,
+				RevenueWithTax:     r.RevenueWithTax,
+				TaxCollected:       r.TaxCollected,
+				SystemShare:        r.SystemShare,
+				AgencyShareWithTax: r.AgencyShareWithTax,
+				CampaignSpend:      r.CampaignSpend,
+				CampaignRefunds:    r.CampaignRefunds,
+				NetCampaignSpend:   netCampaignSpend(r.CampaignSpend, r.CampaignRefunds),
+			},
+		}
+		periods = append(periods, p)
+
+		totals.Charges += r.Charges
+		totals.RevenueWithTax += r.RevenueWithTax
+		totals.TaxCollected += r.TaxCollected
+		totals.SystemShare += r.SystemShare
+		totals.AgencyShareWithTax += r.AgencyShareWithTax
+		totals.CampaignSpend += r.CampaignSpend
+		totals.CampaignRefunds += r.CampaignRefunds
+	}
+	totals.NetCampaignSpend = netCampaignSpend(totals.CampaignSpend, totals.CampaignRefunds)
+
+	return &dto.AdminFinancialReportResponse{
+		Message:     "Financial report retrieved successfully",
+		Granularity: granularity,
+		StartDate:   startDate.Format(time.RFC3339),
+		EndDate:     endDate.Format(time.RFC3339),
+		Totals:      totals,
+		Periods:     periods,
+		RefreshedAt: refreshedAt,
+	}, nil
+}
+
+// rollupFinancialPeriods reads the report from revenue_daily when the range
+// is whole Tehran days through the last refreshed day. It returns nil rows
+// when the report has to be computed from the transactions instead.
+func (f *AdminReportFlowImpl) rollupFinancialPeriods(ctx context.Context, granularity string, startDate, endDate time.Time) ([]*repository.FinancialPeriodAggregate, *string, error) {
+	if f.rollupRepo == nil || !isTehranMidnight(startDate) || !isTehranMidnight(endDate) {
+		return nil, nil, nil
+	}
+	state, err := f.rollupRepo.State(ctx, models.ReportRollupDaily)
+	if err != nil || state == nil {
+		return nil, nil, err
+	}
+	fromDay, _ := utils.TehranDayBounds(startDate)
+	toDay, _ := utils.TehranDayBounds(endDate)
+	if toDay.After(rollupDay(state.RefreshedThrough).AddDate(0, 0, 1)) {
+		return nil, nil, nil
+	}
+	rows, err := f.rollupRepo.RevenuePeriods(ctx, granularity, fromDay, toDay)
+	if err != nil {
+		return nil, nil, err
+	}
+	refreshedAt := state.RefreshedAt.UTC().Format(time.RFC3339)
+	return rows, &refreshedAt, nil
+}
+
+// isTehranMidnight reports whether t starts a Tehran day
+func isTehranMidnight(t time.Time) bool {
+	start, _ := utils.TehranDayBounds(t)
+	return start.Equal(t)
+}
+
+// GetTopCustomers ranks customers by what they paid in the range, 30 days
+// by default
+func (f *AdminReportFlowImpl) GetTopCustomers(ctx context.Context, req *dto.AdminTopCustomersReportRequest) (*dto.AdminTopCustomersReportResponse, error) {
+	limit := req.Limit
+	if limit <= 0 {
+		limit = adminTopCustomersLimit
+	}
+	startDate, endDate, err := parseAdminReportRange(req.StartDate, req.EndDate, adminReportDefaultDailyDays, utils.UTCNow())
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := f.transactionRepo.AggregateTopCustomers(ctx, startDate, endDate, limit)
+	if err != nil {
+		return nil, NewBusinessError("ADMIN_TOP_CUSTOMERS_REPORT_FAILED", "Failed to get top customers report", err)
+	}
+
+	items := make([]dto.AdminTopCustomerItem, 0, len(rows))
+	for _, r := range rows {
+		items = append(items, dto.AdminTopCustomerItem{
+			CustomerID:              r.CustomerID,
+			RepresentativeFirstName: r.RepresentativeFirstName,
+			RepresentativeLastName:  r.RepresentativeLastName,
+			CompanyName:             r.CompanyName,
+			Charges:                 r.Charges,
+			RevenueWithTax:          r.RevenueWithTax,
+			CampaignSpend:           r.CampaignSpend,
+			CampaignRefunds:         r.CampaignRefunds,
+			NetCampaignSpend:        netCampaignSpend(r.CampaignSpend, r.CampaignRefunds),
+		})
+	}
+
+	return &dto.AdminTopCustomersReportResponse{
+		Message:   "Top customers report retrieved successfully",
+		StartDate: startDate.Format(time.RFC3339),
+		EndDate:   endDate.Format(time.RFC3339),
+		Items:     items,
+	}, nil
+}
+
+// GetWalletLiability sums the current balances of every wallet but the
+// system and tax wallets. Credit is discount credit granted on top of what
+// customers paid, not money the platform holds, so it is reported but left
+// out of the total liability.
+func (f *AdminReportFlowImpl) GetWalletLiability(ctx context.Context) (*dto.AdminWalletLiabilityResponse, error) {
+	exclude := make([]string, 0, 2)
+	for _, u := range []string{f.sysCfg.SystemWalletUUID, f.sysCfg.TaxWalletUUID} {
+		if u != "" {
+			exclude = append(exclude, u)
+		}
+	}
+	totals, err := f.balanceSnapshotRepo.SumLatestBalances(ctx, exclude)
+	if err != nil {
+		return nil, NewBusinessError("ADMIN_WALLET_LIABILITY_REPORT_FAILED", "Failed to get wallet liability report", err)
+	}
+
+	return &dto.AdminWalletLiabilityResponse{
+		Message:            "Wallet liability retrieved successfully",
+		Wallets:            totals.Wallets,
+		FreeBalance:        totals.FreeBalance,
+		FrozenBalance:      totals.FrozenBalance,
+		LockedBalance:      totals.LockedBalance,
+		CreditBalance:      totals.CreditBalance,
+		AgencyShareWithTax: totals.AgencyShareWithTax,
+		TotalLiability:     totals.FreeBalance + totals.FrozenBalance + totals.LockedBalance + totals.AgencyShareWithTax,
+		SpentOnCampaign:    totals.SpentOnCampaign,
+	}, nil
+}
+
+// parseAdminReportRange parses the optional RFC3339 bounds of a report. A
+// missing end is the start of the next Tehran day and a missing start is
+// defaultDays before the end; ranges are capped at a year.
+func parseAdminReportRange(start, end *string, defaultDays int, now time.Time) (time.Time, time.Time, error) {
+	var startDate, endDate time.Time
+	if end != nil && strings.TrimSpace(*end) != "" {
+		t, err := time.Parse(time.RFC3339, strings.TrimSpace(*end))
+		if err != nil {
+			return time.Time{}, time.Time{}, NewBusinessError("VALIDATION_ERROR", "Invalid end_date format", err)
+		}
+		endDate = t.UTC()
+	} else {
+		tehranNow := now.In(utils.TehranLocation())
+		endDate = time.Date(tehranNow.Year(), tehranNow.Month(), tehranNow.Day()+1, 0, 0, 0, 0, tehranNow.Location()).UTC()
+	}
+	if start != nil && strings.TrimSpace(*start) != "" {
+		t, err := time.Parse(time.RFC3339, strings.TrimSpace(*start))
+		if err != nil {
+			return time.Time{}, time.Time{}, NewBusinessError("VALIDATION_ERROR", "Invalid start_date format", err)
+		}
+		startDate = t.UTC()
+	} else {
+		startDate = endDate.AddDate(0, 0, -defaultDays)
+	}
+
+	if !startDate.Before(endDate) {
+		return time.Time{}, time.Time{}, NewBusinessError("VALIDATION_ERROR", "start_date must be before end_date", ErrStartDateAfterEndDate)
+	}
+	if endDate.Sub(startDate) > adminReportMaxRange {
+		return time.Time{}, time.Time{}, NewBusinessError("VALIDATION_ERROR", "Report range cannot exceed one year", nil)
+	}
+	return startDate, endDate, nil
+}
+
+// netCampaignSpend is the campaign budget spent less what was refunded of it
+func netCampaignSpend(spend, refunds uint64) uint64 {
+	if refunds > spend {
+		return 0
+	}
+	return spend - refunds
+}
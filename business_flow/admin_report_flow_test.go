@@ -0,0 +1,53 @@
+package businessflow
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+)
+
+func TestParseAdminReportRange(t *testing.T) {
+	t.Parallel()
+
+	// 23:00 UTC is already the next day in Tehran
+	now := time.Date(2026, 10, 16, 23, 0, 0, 0, time.UTC)
+	start, end, err := parseAdminReportRange(nil, nil, 30, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantEnd := time.Date(2026, 10, 18, 0, 0, 0, 0, utils.TehranLocation())
+	if !end.Equal(wantEnd) || !start.Equal(wantEnd.AddDate(0, 0, -30)) {
+		t.Fatalf("range = %v - %v, want 30 days ending %v", start, end, wantEnd)
+	}
+
+	start, end, err = parseAdminReportRange(utils.ToPtr("2026-09-01T00:00:00+03:30"), utils.ToPtr("2026-10-01T00:00:00+03:30"), 30, now)
+	if err != nil || end.Sub(start) != 30*24*time.Hour {
+		t.Fatalf("range = %v - %v, %v", start, end, err)
+	}
+
+	for name, bounds := range map[string][2]*string{
+		"invalid":  {utils.ToPtr("2026-09-01"), nil},
+		"reversed": {utils.ToPtr("2026-10-01T00:00:00Z"), utils.ToPtr("2026-09-01T00:00:00Z")},
+		"empty":    {utils.ToPtr("2026-10-01T00:00:00Z"), utils.ToPtr("2026-10-01T00:00:00Z")},
+		"too long": {utils.ToPtr("2025-01-01T00:00:00Z"), utils.ToPtr("2026-10-01T00:00:00Z")},
+	} {
+		var be *BusinessError
+		if _, _, err := parseAdminReportRange(bounds[0], bounds[1], 30, now); !errors.As(err, &be) || be.Code != "VALIDATION_ERROR" {
+			t.Errorf("%s: err = %v, want a validation error", name, err)
+		}
+	}
+}
+
+func TestNetCampaignSpend(t *testing.T) {
+	t.Parallel()
+
+	if got := netCampaignSpend(1_000, 250); got != 750 {
+		t.Fatalf("net = %d, want 750", got)
+	}
+	// Refunds of budget spent before the range can exceed the range's spend
+	if got := netCampaignSpend(100, 250); got != 0 {
+		t.Fatalf("net = %d, want 0", got)
+	}
+}
@@ -0,0 +1,234 @@
+package businessflow
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"strconv"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/repository"
+)
+
+const (
+	agencyTopCampaignsLimit = 10
+	// Campaign exports list every campaign with messages sent, up to this many
+	agencyCampaignExportLimit = 10_000
+)
+
+// GetAgencyCommissionDashboard reports what an agency's referred customers
+// paid, the commission it accrued from them by customer and by month, how
+// much of it was paid out, and the referred customers' largest campaigns
+func (a *AgencyFlowImpl) GetAgencyCommissionDashboard(ctx context.Context, req *dto.AgencyCommissionReportRequest, metadata *ClientMetadata) (*dto.AgencyCommissionDashboardResponse, error) {
+	if _, err := getAgency(ctx, a.customerRepo, req.AgencyID); err != nil {
+		return nil, err
+	}
+	startDate, endDate, err := parseAgencyReportRange(req)
+	if err != nil {
+		return nil, err
+	}
+
+	customers, err := a.transactionRepo.AggregateAgencyCommissionByCustomers(ctx, req.AgencyID, startDate, endDate)
+	if err != nil {
+		return nil, NewBusinessError("GET_AGENCY_COMMISSION_DASHBOARD_FAILED", "Get agency commission dashboard failed", err)
+	}
+	months, err := a.transactionRepo.AggregateAgencyCommissionByMonth(ctx, req.AgencyID, startDate, endDate)
+	if err != nil {
+		return nil, NewBusinessError("GET_AGENCY_COMMISSION_DASHBOARD_FAILED", "Get agency commission dashboard failed", err)
+	}
+	allMonths := months
+	if startDate != nil || endDate != nil {
+		if allMonths, err = a.transactionRepo.AggregateAgencyCommissionByMonth(ctx, req.AgencyID, nil, nil); err != nil {
+			return nil, NewBusinessError("GET_AGENCY_COMMISSION_DASHBOARD_FAILED", "Get agency commission dashboard failed", err)
+		}
+	}
+	campaigns, err := a.campaignRepo.TopByReferrerAgency(ctx, req.AgencyID, startDate, endDate, agencyTopCampaignsLimit)
+	if err != nil {
+		return nil, NewBusinessError("GET_AGENCY_COMMISSION_DASHBOARD_FAILED", "Get agency commission dashboard failed", err)
+	}
+
+	return &dto.AgencyCommissionDashboardResponse{
+		Message:      "Agency commission dashboard retrieved successfully",
+		Summary:      agencyCommissionSummary(customers, months, allMonths),
+		Customers:    agencyCommissionCustomerItems(customers),
+		Months:       agencyCommissionMonthItems(months),
+		TopCampaigns: agencyTopCampaignItems(campaigns),
+	}, nil
+}
+
+// ExportAgencyCommissionReport exports one section of the commission
+// dashboard as CSV. The campaigns report lists all campaigns with messages
+// sent, not only the top ones.
+func (a *AgencyFlowImpl) ExportAgencyCommissionReport(ctx context.Context, req *dto.AgencyCommissionReportRequest, metadata *ClientMetadata) (*dto.AgencyCommissionExport, error) {
+	if _, err := getAgency(ctx, a.customerRepo, req.AgencyID); err != nil {
+		return nil, err
+	}
+	startDate, endDate, err := parseAgencyReportRange(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var records [][]string
+	switch req.Report {
+	case "customers":
+		rows, err := a.transactionRepo.AggregateAgencyCommissionByCustomers(ctx, req.AgencyID, startDate, endDate)
+		if err != nil {
+			return nil, NewBusinessError("EXPORT_AGENCY_COMMISSION_REPORT_FAILED", "Export agency commission report failed", err)
+		}
+		records = agencyCommissionCustomerRecords(agencyCommissionCustomerItems(rows))
+	case "monthly":
+		rows, err := a.transactionRepo.AggregateAgencyCommissionByMonth(ctx, req.AgencyID, startDate, endDate)
+		if err != nil {
+			return nil, NewBusinessError("EXPORT_AGENCY_COMMISSION_REPORT_FAILED", "Export agency commission report failed", err)
+		}
+		records = agencyCommissionMonthRecords(agencyCommissionMonthItems(rows))
+	case "campaigns":
+		rows, err := a.campaignRepo.TopByReferrerAgency(ctx, req.AgencyID, startDate, endDate, agencyCampaignExportLimit)
+		if err != nil {
+			return nil, NewBusinessError("EXPORT_AGENCY_COMMISSION_REPORT_FAILED", "Export agency commission report failed", err)
+		}
+		records = agencyTopCampaignRecords(agencyTopCampaignItems(rows))
+	default:
+		return nil, NewBusinessError("VALIDATION_ERROR", "report must be one of customers, monthly, campaigns", nil)
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.WriteAll(records); err != nil {
+		return nil, NewBusinessError("CSV_WRITE_ERROR", "Failed to write CSV", err)
+	}
+	return &dto.AgencyCommissionExport{
+		Filename: "agency_commission_" + req.Report + ".csv",
+		Content:  buf.Bytes(),
+	}, nil
+}
+
+// parseAgencyReportRange parses the optional RFC3339 bounds of a report
+func parseAgencyReportRange(req *dto.AgencyCommissionReportRequest) (*time.Time, *time.Time, error) {
+	var startDate, endDate *time.Time
+	if req.StartDate != nil && *req.StartDate != "" {
+		t, err := time.Parse(time.RFC3339, *req.StartDate)
+		if err != nil {
+			return nil, nil, NewBusinessError("VALIDATION_ERROR", "Invalid start_date format", err)
+		}
+		startDate = &t
+	}
+	if req.EndDate != nil && *req.EndDate != "" {
+		t, err := time.Parse(time.RFC3339, *req.EndDate)
+		if err != nil {
+			return nil, nil, NewBusinessError("VALIDATION_ERROR", "Invalid end_date format", err)
+		}
+		endDate = &t
+	}
+	if startDate != nil && endDate != nil && endDate.Before(*startDate) {
+		return nil, nil, NewBusinessError("VALIDATION_ERROR", "end_date must not be before start_date", nil)
+	}
+	return startDate, endDate, nil
+}
+
+// agencyCommissionSummary totals the range's rows; pending comes from the
+// all-time months, as payouts need not fall in the range of what they pay
+func agencyCommissionSummary(customers []*repository.AgencyCommissionCustomerAggregate, months, allMonths []*repository.AgencyCommissionMonthAggregate) dto.AgencyCommissionSummary {
+	var summary dto.AgencyCommissionSummary
+	for _, c := range customers {
+		summary.RevenueWithTax += c.RevenueWithTax
+	}
+	for _, m := range months {
+		summary.Accrued += m.Accrued
+		summary.Settled += m.Settled
+	}
+	var accrued, settled uint64
+	for _, m := range allMonths {
+		accrued += m.Accrued
+		settled += m.Settled
+	}
+	if accrued > settled {
+		summary.Pending = accrued - settled
+	}
+	return summary
+}
+
+func agencyCommissionCustomerItems(rows []*repository.AgencyCommissionCustomerAggregate) []dto.AgencyCommissionCustomerItem {
+	items := make([]dto.AgencyCommissionCustomerItem, 0, len(rows))
+	for _, r := range rows {
+		items = append(items, dto.AgencyCommissionCustomerItem{
+			CustomerID:              r.CustomerID,
+			RepresentativeFirstName: r.RepresentativeFirstName,
+			RepresentativeLastName:  r.RepresentativeLastName,
+			CompanyName:             r.CompanyName,
+			Charges:                 r.Charges,
+			RevenueWithTax:          r.RevenueWithTax,
+			CommissionWithTax:       r.CommissionWithTax,
+		})
+	}
+	return items
+}
+
+func agencyCommissionMonthItems(rows []*repository.AgencyCommissionMonthAggregate) []dto.AgencyCommissionMonthItem {
+	items := make([]dto.AgencyCommissionMonthItem, 0, len(rows))
+	for _, r := range rows {
+		items = append(items, dto.AgencyCommissionMonthItem{Month: r.Month, Accrued: r.Accrued, Settled: r.Settled})
+	}
+	return items
+}
+
+func agencyTopCampaignItems(rows []*repository.AgencyCampaignAggregate) []dto.AgencyTopCampaignItem {
+	items := make([]dto.AgencyTopCampaignItem, 0, len(rows))
+	for _, r := range rows {
+		items = append(items, dto.AgencyTopCampaignItem{
+			UUID:                    r.UUID,
+			Title:                   r.Title,
+			Status:                  r.Status,
+			CustomerID:              r.CustomerID,
+			RepresentativeFirstName: r.RepresentativeFirstName,
+			RepresentativeLastName:  r.RepresentativeLastName,
+			CompanyName:             r.CompanyName,
+			TotalSent:               r.TotalSent,
+			CreatedAt:               r.CreatedAt,
+		})
+	}
+	return items
+}
+
+func agencyCommissionCustomerRecords(items []dto.AgencyCommissionCustomerItem) [][]string {
+	records := [][]string{{"customer_id", "first_name", "last_name", "company_name", "charges", "revenue_with_tax", "commission_with_tax"}}
+	for _, it := range items {
+		records = append(records, []string{
+			strconv.FormatUint(uint64(it.CustomerID), 10),
+			it.RepresentativeFirstName,
+			it.RepresentativeLastName,
+			it.CompanyName,
+			strconv.FormatInt(it.Charges, 10),
+			strconv.FormatUint(it.RevenueWithTax, 10),
+			strconv.FormatUint(it.CommissionWithTax, 10),
+		})
+	}
+	return records
+}
+
+func agencyCommissionMonthRecords(items []dto.AgencyCommissionMonthItem) [][]string {
+	records := [][]string{{"month", "accrued", "settled"}}
+	for _, it := range items {
+		records = append(records, []string{it.Month, strconv.FormatUint(it.Accrued, 10), strconv.FormatUint(it.Settled, 10)})
+	}
+	return records
+}
+
+func agencyTopCampaignRecords(items []dto.AgencyTopCampaignItem) [][]string {
+	records := [][]string{{"campaign_uuid", "title", "status", "customer_id", "first_name", "last_name", "company_name", "total_sent", "created_at"}}
+	for _, it := range items {
+		records = append(records, []string{
+			it.UUID,
+			it.Title,
+			it.Status,
+			strconv.FormatUint(uint64(it.CustomerID), 10),
+			it.RepresentativeFirstName,
+			it.RepresentativeLastName,
+			it.CompanyName,
+			strconv.FormatUint(it.TotalSent, 10),
+			it.CreatedAt.UTC().Format(time.RFC3339),
+		})
+	}
+	return records
+}
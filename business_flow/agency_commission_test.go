@@ -0,0 +1,54 @@
+package businessflow
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/repository"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+)
+
+func TestAgencyCommissionSummary(t *testing.T) {
+	t.Parallel()
+
+	customers := []*repository.AgencyCommissionCustomerAggregate{{RevenueWithTax: 1_000}, {RevenueWithTax: 500}}
+	months := []*repository.AgencyCommissionMonthAggregate{{Month: "2026-10", Accrued: 150, Settled: 100}}
+	allMonths := []*repository.AgencyCommissionMonthAggregate{
+		{Month: "2026-09", Accrued: 200, Settled: 0},
+		{Month: "2026-10", Accrued: 150, Settled: 100},
+	}
+
+	got := agencyCommissionSummary(customers, months, allMonths)
+	want := dto.AgencyCommissionSummary{RevenueWithTax: 1_500, Accrued: 150, Settled: 100, Pending: 250}
+	if got != want {
+		t.Fatalf("summary = %+v, want %+v", got, want)
+	}
+
+	overpaid := []*repository.AgencyCommissionMonthAggregate{{Accrued: 100, Settled: 120}}
+	if got := agencyCommissionSummary(nil, overpaid, overpaid); got.Pending != 0 {
+		t.Fatalf("pending = %d, want 0 when payouts exceed accruals", got.Pending)
+	}
+}
+
+func TestParseAgencyReportRange(t *testing.T) {
+	t.Parallel()
+
+	start, end, err := parseAgencyReportRange(&dto.AgencyCommissionReportRequest{
+		StartDate: utils.ToPtr("2026-09-01T00:00:00+03:30"),
+		EndDate:   utils.ToPtr("2026-10-01T00:00:00+03:30"),
+	})
+	if err != nil || start == nil || end == nil {
+		t.Fatalf("range = %v, %v, %v", start, end, err)
+	}
+
+	for name, req := range map[string]*dto.AgencyCommissionReportRequest{
+		"invalid":  {StartDate: utils.ToPtr("2026-09-01")},
+		"reversed": {StartDate: utils.ToPtr("2026-10-01T00:00:00Z"), EndDate: utils.ToPtr("2026-09-01T00:00:00Z")},
+	} {
+		var be *BusinessError
+		if _, _, err := parseAgencyReportRange(req); !errors.As(err, &be) || be.Code != "VALIDATION_ERROR" {
+			t.Errorf("%s: err = %v, want a validation error", name, err)
+		}
+	}
+}
@@ -0,0 +1,226 @@
+package businessflow
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/repository"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/lib/pq"
+)
+
+// GrantAgencyDelegation lets the customer's referrer agency act for them. An
+// active grant to the same agency gets the new permissions; one left from a
+// previous referrer agency is revoked.
+func (a *AgencyFlowImpl) GrantAgencyDelegation(ctx context.Context, req *dto.GrantAgencyDelegationRequest, metadata *ClientMetadata) (*dto.AgencyDelegationResponse, error) {
+	customer, err := getCustomer(ctx, a.customerRepo, req.CustomerID)
+	if err != nil {
+		return nil, err
+	}
+	if customer.ReferrerAgencyID == nil {
+		return nil, NewBusinessError("GRANT_AGENCY_DELEGATION_VALIDATION_FAILED", "Customer is not under any agency", ErrCustomerNotUnderAgency)
+	}
+	if _, err := getAgency(ctx, a.customerRepo, *customer.ReferrerAgencyID); err != nil {
+		return nil, err
+	}
+	permissions, err := normalizeDelegationPermissions(req.Permissions)
+	if err != nil {
+		return nil, NewBusinessError("GRANT_AGENCY_DELEGATION_VALIDATION_FAILED", "Unknown delegation permission", err)
+	}
+
+	var grant *models.AgencyDelegation
+	err = repository.WithTransaction(ctx, a.db, func(txCtx context.Context) error {
+		active, err := a.delegationRepo.ActiveByCustomer(txCtx, customer.ID)
+		if err != nil {
+			return err
+		}
+		if active != nil && active.AgencyID == *customer.ReferrerAgencyID {
+			active.Permissions = permissions
+			grant = active
+			return a.delegationRepo.Update(txCtx, active)
+		}
+		if active != nil {
+			active.RevokedAt = utils.ToPtr(utils.UTCNow())
+			if err := a.delegationRepo.Update(txCtx, active); err != nil {
+				return err
+			}
+		}
+		grant = &models.AgencyDelegation{
+			AgencyID:    *customer.ReferrerAgencyID,
+			CustomerID:  customer.ID,
+			Permissions: permissions,
+			CreatedAt:   utils.UTCNow(),
+			UpdatedAt:   utils.UTCNow(),
+		}
+		return a.delegationRepo.Save(txCtx, grant)
+	})
+	if err != nil {
+		return nil, NewBusinessError("GRANT_AGENCY_DELEGATION_FAILED", "Failed to grant agency delegation", err)
+	}
+
+	msg := fmt.Sprintf("Agency %d may act for customer %d with permissions %v", grant.AgencyID, customer.ID, []string(grant.Permissions))
+	_ = createAuditLog(ctx, a.auditRepo, &customer, models.AuditActionAgencyDelegationGranted, msg, true, nil, metadata)
+
+	return &dto.AgencyDelegationResponse{
+		Message:    "Agency delegation granted successfully",
+		Delegation: agencyDelegationItem(grant),
+	}, nil
+}
+
+// RevokeAgencyDelegation withdraws the customer's active grant
+func (a *AgencyFlowImpl) RevokeAgencyDelegation(ctx context.Context, req *dto.RevokeAgencyDelegationRequest, metadata *ClientMetadata) (*dto.AgencyDelegationResponse, error) {
+	customer, err := getCustomer(ctx, a.customerRepo, req.CustomerID)
+	if err != nil {
+		return nil, err
+	}
+	grant, err := a.delegationRepo.ActiveByCustomer(ctx, customer.ID)
+	if err != nil {
+		return nil, NewBusinessError("REVOKE_AGENCY_DELEGATION_FAILED", "Failed to revoke agency delegation", err)
+	}
+	if grant == nil {
+		return nil, ErrAgencyDelegationNotFound
+	}
+	grant.RevokedAt = utils.ToPtr(utils.UTCNow())
+	if err := a.delegationRepo.Update(ctx, grant); err != nil {
+		return nil, NewBusinessError("REVOKE_AGENCY_DELEGATION_FAILED", "Failed to revoke agency delegation", err)
+	}
+
+	msg := fmt.Sprintf("Agency %d may no longer act for customer %d", grant.AgencyID, customer.ID)
+	_ = createAuditLog(ctx, a.auditRepo, &customer, models.AuditActionAgencyDelegationRevoked, msg, true, nil, metadata)
+
+	return &dto.AgencyDelegationResponse{
+		Message:    "Agency delegation revoked successfully",
+		Delegation: agencyDelegationItem(grant),
+	}, nil
+}
+
+// GetAgencyDelegation returns the customer's active grant; Delegation is nil
+// when they have none
+func (a *AgencyFlowImpl) GetAgencyDelegation(ctx context.Context, req *dto.GetAgencyDelegationRequest, metadata *ClientMetadata) (*dto.AgencyDelegationResponse, error) {
+	if _, err := getCustomer(ctx, a.customerRepo, req.CustomerID); err != nil {
+		return nil, err
+	}
+	grant, err := a.delegationRepo.ActiveByCustomer(ctx, req.CustomerID)
+	if err != nil {
+		return nil, NewBusinessError("GET_AGENCY_DELEGATION_FAILED", "Failed to retrieve agency delegation", err)
+	}
+	resp := &dto.AgencyDelegationResponse{Message: "Agency delegation retrieved successfully"}
+	if grant != nil {
+		resp.Delegation = agencyDelegationItem(grant)
+	}
+	return resp, nil
+}
+
+// ListAgencyDelegations lists the customers who let the agency act for them
+func (a *AgencyFlowImpl) ListAgencyDelegations(ctx context.Context, req *dto.ListAgencyDelegationsRequest, metadata *ClientMetadata) (*dto.ListAgencyDelegationsResponse, error) {
+	if _, err := getAgency(ctx, a.customerRepo, req.AgencyID); err != nil {
+		return nil, err
+	}
+	rows, err := a.delegationRepo.ListActiveWithCustomer(ctx, req.AgencyID)
+	if err != nil {
+		return nil, NewBusinessError("LIST_AGENCY_DELEGATIONS_FAILED", "Failed to list agency delegations", err)
+	}
+
+	items := make([]dto.AgencyDelegatingCustomerItem, 0, len(rows))
+	for _, r := range rows {
+		items = append(items, dto.AgencyDelegatingCustomerItem{
+			UUID:                    r.DelegationUUID,
+			CustomerID:              r.CustomerID,
+			CustomerUUID:            r.CustomerUUID,
+			RepresentativeFirstName: r.RepresentativeFirstName,
+			RepresentativeLastName:  r.RepresentativeLastName,
+			CompanyName:             r.CompanyName,
+			Permissions:             r.Permissions,
+			CreatedAt:               r.CreatedAt,
+		})
+	}
+	return &dto.ListAgencyDelegationsResponse{
+		Message: "Agency delegations retrieved successfully",
+		Items:   items,
+	}, nil
+}
+
+// authorizeDelegation checks the delegation of ctx, if any: the agency must
+// act for customerID, still be the customer's referrer agency and hold their
+// active grant with permission. An empty permission accepts any active
+// grant. Requests without a delegation are not checked.
+func authorizeDelegation(
+	ctx context.Context,
+	customerRepo repository.CustomerRepository,
+	delegationRepo repository.AgencyDelegationRepository,
+	customerID uint,
+	permission string,
+) error {
+	delegation, ok := ctx.Value(utils.DelegationKey).(utils.Delegation)
+	if !ok || delegation.AgencyID == 0 {
+		return nil
+	}
+	if delegation.CustomerID != customerID {
+		return ErrAgencyDelegationDenied
+	}
+	if _, err := getAgency(ctx, customerRepo, delegation.AgencyID); err != nil {
+		return err
+	}
+	customer, err := getCustomer(ctx, customerRepo, customerID)
+	if err != nil {
+		return err
+	}
+	if customer.ReferrerAgencyID == nil || *customer.ReferrerAgencyID != delegation.AgencyID {
+		return ErrAgencyDelegationDenied
+	}
+
+	grant, err := delegationRepo.ActiveByCustomer(ctx, customerID)
+	if err != nil {
+		return err
+	}
+	if grant == nil || grant.AgencyID != delegation.AgencyID {
+		return ErrAgencyDelegationDenied
+	}
+	if permission != "" && !grant.Allows(permission) {
+		return ErrAgencyDelegationDenied
+	}
+	return nil
+}
+
+// authorizeDelegation checks the agency acting for customerID, if any, and
+// audits denied attempts
+func (s *CampaignFlowImpl) authorizeDelegation(ctx context.Context, customerID uint, permission string, metadata *ClientMetadata) error {
+	err := authorizeDelegation(ctx, s.customerRepo, s.delegationRepo, customerID, permission)
+	if IsAgencyDelegationDenied(err) {
+		msg := fmt.Sprintf("Agency denied acting for customer %d (permission %q)", customerID, permission)
+		_ = s.createAuditLog(ctx, &models.Customer{ID: customerID}, models.AuditActionAgencyDelegationDenied, msg, false, &msg, metadata)
+	}
+	return err
+}
+
+// normalizeDelegationPermissions deduplicates and sorts permissions,
+// rejecting unknown ones
+func normalizeDelegationPermissions(permissions []string) (pq.StringArray, error) {
+	out := make(pq.StringArray, 0, len(permissions))
+	for _, p := range permissions {
+		if !slices.Contains(models.AgencyDelegationPermissions, p) {
+			return nil, ErrAgencyDelegationPermissionInvalid
+		}
+		if !slices.Contains(out, p) {
+			out = append(out, p)
+		}
+	}
+	if len(out) == 0 {
+		return nil, ErrAgencyDelegationPermissionInvalid
+	}
+	slices.Sort(out)
+	return out, nil
+}
+
+func agencyDelegationItem(d *models.AgencyDelegation) *dto.AgencyDelegationItem {
+	return &dto.AgencyDelegationItem{
+		UUID:        d.UUID.String(),
+		AgencyID:    d.AgencyID,
+		Permissions: d.Permissions,
+		CreatedAt:   d.CreatedAt,
+		UpdatedAt:   d.UpdatedAt,
+	}
+}
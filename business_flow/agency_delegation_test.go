@@ -0,0 +1,40 @@
+package businessflow
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+)
+
+func TestNormalizeDelegationPermissions(t *testing.T) {
+	t.Parallel()
+
+	got, err := normalizeDelegationPermissions([]string{"manage_campaigns", "create_campaigns", "manage_campaigns"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{"create_campaigns", "manage_campaigns"}; !slices.Equal(got, want) {
+		t.Fatalf("permissions = %v, want %v", got, want)
+	}
+
+	for _, permissions := range [][]string{nil, {"delete_account"}} {
+		if _, err := normalizeDelegationPermissions(permissions); !IsAgencyDelegationPermissionInvalid(err) {
+			t.Errorf("%v: err = %v, want ErrAgencyDelegationPermissionInvalid", permissions, err)
+		}
+	}
+}
+
+func TestAuthorizeDelegationWithoutAgency(t *testing.T) {
+	t.Parallel()
+
+	if err := authorizeDelegation(context.Background(), nil, nil, 42, "create_campaigns"); err != nil {
+		t.Fatalf("requests without a delegation must pass, got %v", err)
+	}
+
+	ctx := context.WithValue(context.Background(), utils.DelegationKey, utils.Delegation{AgencyID: 7, CustomerID: 41})
+	if err := authorizeDelegation(ctx, nil, nil, 42, "create_campaigns"); !IsAgencyDelegationDenied(err) {
+		t.Fatalf("acting for another customer: err = %v, want ErrAgencyDelegationDenied", err)
+	}
+}
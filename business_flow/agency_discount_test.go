@@ -0,0 +1,42 @@
+package businessflow
+
+import (
+	"testing"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+)
+
+func TestAgencyDiscountStatus(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	tests := map[string]struct {
+		discount models.AgencyDiscount
+		want     string
+	}{
+		"active":            {models.AgencyDiscount{EffectiveFrom: now.Add(-time.Hour)}, "active"},
+		"ending later":      {models.AgencyDiscount{EffectiveFrom: now.Add(-time.Hour), ExpiresAt: utils.ToPtr(now.Add(time.Hour))}, "active"},
+		"scheduled":         {models.AgencyDiscount{EffectiveFrom: now.Add(time.Hour)}, "scheduled"},
+		"expired":           {models.AgencyDiscount{EffectiveFrom: now.Add(-2 * time.Hour), ExpiresAt: utils.ToPtr(now.Add(-time.Hour))}, "expired"},
+		"cancelled pending": {models.AgencyDiscount{EffectiveFrom: now.Add(time.Hour), ExpiresAt: utils.ToPtr(now.Add(-time.Minute))}, "expired"},
+	}
+	for name, tt := range tests {
+		if got := agencyDiscountStatus(&tt.discount, now); got != tt.want {
+			t.Errorf("%s: status = %q, want %q", name, got, tt.want)
+		}
+	}
+}
+
+func TestChargeDiscountRate(t *testing.T) {
+	t.Parallel()
+
+	discount := &models.AgencyDiscount{DiscountRate: 0.1}
+	if got := chargeDiscountRate(map[string]any{"agency_discount_rate": 0.25}, discount); got != 0.25 {
+		t.Fatalf("rate = %g, want the rate fixed on the request", got)
+	}
+	if got := chargeDiscountRate(map[string]any{}, discount); got != 0.1 {
+		t.Fatalf("rate = %g, want the base rate for requests made before tiers", got)
+	}
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/amirphl/Yamata-no-Orochi/app/dto"
 	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/pricing"
 	"github.com/amirphl/Yamata-no-Orochi/repository"
 	"github.com/amirphl/Yamata-no-Orochi/utils"
 	"github.com/google/uuid"
@@ -21,7 +22,14 @@ type AgencyFlow interface {
 	GetAgencyCustomerReport(ctx context.Context, req *dto.AgencyCustomerReportRequest, metadata *ClientMetadata) (*dto.AgencyCustomerReportResponse, error)
 	ListAgencyActiveDiscounts(ctx context.Context, req *dto.ListAgencyActiveDiscountsRequest, metadata *ClientMetadata) (*dto.ListAgencyActiveDiscountsResponse, error)
 	ListAgencyCustomerDiscounts(ctx context.Context, req *dto.ListAgencyCustomerDiscountsRequest, metadata *ClientMetadata) (*dto.ListAgencyCustomerDiscountsResponse, error)
+	ListAgencyDiscountSchedule(ctx context.Context, req *dto.ListAgencyDiscountScheduleRequest, metadata *ClientMetadata) (*dto.ListAgencyDiscountScheduleResponse, error)
 	ListAgencyCustomers(ctx context.Context, req *dto.ListAgencyCustomersRequest, metadata *ClientMetadata) (*dto.ListAgencyCustomersResponse, error)
+	GetAgencyCommissionDashboard(ctx context.Context, req *dto.AgencyCommissionReportRequest, metadata *ClientMetadata) (*dto.AgencyCommissionDashboardResponse, error)
+	ExportAgencyCommissionReport(ctx context.Context, req *dto.AgencyCommissionReportRequest, metadata *ClientMetadata) (*dto.AgencyCommissionExport, error)
+	GrantAgencyDelegation(ctx context.Context, req *dto.GrantAgencyDelegationRequest, metadata *ClientMetadata) (*dto.AgencyDelegationResponse, error)
+	RevokeAgencyDelegation(ctx context.Context, req *dto.RevokeAgencyDelegationRequest, metadata *ClientMetadata) (*dto.AgencyDelegationResponse, error)
+	GetAgencyDelegation(ctx context.Context, req *dto.GetAgencyDelegationRequest, metadata *ClientMetadata) (*dto.AgencyDelegationResponse, error)
+	ListAgencyDelegations(ctx context.Context, req *dto.ListAgencyDelegationsRequest, metadata *ClientMetadata) (*dto.ListAgencyDelegationsResponse, error)
 }
 
 // AgencyFlowImpl implements AgencyFlow
@@ -29,6 +37,7 @@ type AgencyFlowImpl struct {
 	customerRepo       repository.CustomerRepository
 	campaignRepo       repository.CampaignRepository
 	agencyDiscountRepo repository.AgencyDiscountRepository
+	delegationRepo     repository.AgencyDelegationRepository
 	transactionRepo    repository.TransactionRepository
 	auditRepo          repository.AuditLogRepository
 	db                 *gorm.DB
@@ -39,6 +48,7 @@ func NewAgencyFlow(
 	customerRepo repository.CustomerRepository,
 	campaignRepo repository.CampaignRepository,
 	agencyDiscountRepo repository.AgencyDiscountRepository,
+	delegationRepo repository.AgencyDelegationRepository,
 	transactionRepo repository.TransactionRepository,
 	auditRepo repository.AuditLogRepository,
 	db *gorm.DB,
@@ -47,6 +57,7 @@ func NewAgencyFlow(
 		customerRepo:       customerRepo,
 		campaignRepo:       campaignRepo,
 		agencyDiscountRepo: agencyDiscountRepo,
+		delegationRepo:     delegationRepo,
 		transactionRepo:    transactionRepo,
 		auditRepo:          auditRepo,
 		db:                 db,
@@ -81,10 +92,28 @@ func (a *AgencyFlowImpl) CreateAgencyDiscount(ctx context.Context, req *dto.Crea
 		return nil, NewBusinessError("CREATE_AGENCY_DISCOUNT_VALIDATION_FAILED", "Customer is not under this agency", ErrCustomerNotUnderAgency)
 	}
 
+	tiers := make(pricing.DiscountTiers, 0, len(req.Tiers))
+	for _, t := range req.Tiers {
+		tiers = append(tiers, pricing.DiscountTier{MinMonthlyAmountWithTax: t.MinMonthlyAmountWithTax, DiscountRate: t.DiscountRate})
+	}
+	if err := tiers.Validate(req.DiscountRate, 0.5); err != nil {
+		return nil, NewBusinessError("CREATE_AGENCY_DISCOUNT_VALIDATION_FAILED", err.Error(), ErrDiscountTiersInvalid)
+	}
+
+	effectiveFrom := utils.UTCNow()
+	if req.EffectiveFrom != nil && *req.EffectiveFrom != "" {
+		t, err := time.Parse(time.RFC3339, *req.EffectiveFrom)
+		if err != nil || !t.After(effectiveFrom) {
+			return nil, NewBusinessError("CREATE_AGENCY_DISCOUNT_VALIDATION_FAILED", "Effective date must be a future RFC3339 time", ErrDiscountEffectiveFromInvalid)
+		}
+		effectiveFrom = t.UTC()
+	}
+
 	var resp *dto.CreateAgencyDiscountResponse
 	err = repository.WithTransaction(ctx, a.db, func(txCtx context.Context) error {
-		// expire old active discounts
-		if err := a.agencyDiscountRepo.ExpireActiveByAgencyAndCustomer(txCtx, req.AgencyID, customer.ID, utils.UTCNow()); err != nil {
+		// end the discount in effect at effectiveFrom and cancel those
+		// scheduled after it
+		if err := a.agencyDiscountRepo.ExpireActiveByAgencyAndCustomer(txCtx, req.AgencyID, customer.ID, effectiveFrom); err != nil {
 			return err
 		}
 
@@ -95,23 +124,27 @@ func (a *AgencyFlowImpl) CreateAgencyDiscount(ctx context.Context, req *dto.Crea
 		}
 		metaJSON, _ := json.Marshal(meta)
 		row := &models.AgencyDiscount{
-			UUID:         uuid.New(),
-			AgencyID:     req.AgencyID,
-			CustomerID:   customer.ID,
-			DiscountRate: req.DiscountRate,
-			ExpiresAt:    nil,
-			Reason:       utils.ToPtr("Created by agency"),
-			Metadata:     metaJSON,
-			CreatedAt:    utils.UTCNow(),
-			UpdatedAt:    utils.UTCNow(),
+			UUID:          uuid.New(),
+			AgencyID:      req.AgencyID,
+			CustomerID:    customer.ID,
+			DiscountRate:  req.DiscountRate,
+			Tiers:         tiers,
+			EffectiveFrom: effectiveFrom,
+			ExpiresAt:     nil,
+			Reason:        utils.ToPtr("Created by agency"),
+			Metadata:      metaJSON,
+			CreatedAt:     utils.UTCNow(),
+			UpdatedAt:     utils.UTCNow(),
 		}
 		if err := a.agencyDiscountRepo.Save(txCtx, row); err != nil {
 			return err
 		}
 
 		resp = &dto.CreateAgencyDiscountResponse{
-			Message:      "Discount created successfully",
-			DiscountRate: row.DiscountRate,
+			Message:       "Discount created successfully",
+			DiscountRate:  row.DiscountRate,
+			Tiers:         agencyDiscountTiers(row.Tiers),
+			EffectiveFrom: row.EffectiveFrom,
 		}
 		return nil
 	})
@@ -124,7 +157,7 @@ func (a *AgencyFlowImpl) CreateAgencyDiscount(ctx context.Context, req *dto.Crea
 	}
 
 	// Create success audit log
-	msg := fmt.Sprintf("Agency discount created for customer %d", customer.ID)
+	msg := fmt.Sprintf("Agency discount created for customer %d: rate %g with %d tiers from %s", customer.ID, req.DiscountRate, len(tiers), effectiveFrom.Format(time.RFC3339))
 	_ = createAuditLog(ctx, a.auditRepo, &customer, models.AuditActionCreateDiscountByAgencyCompleted, msg, true, nil, metadata)
 
 	return resp, nil
@@ -320,6 +353,60 @@ func (a *AgencyFlowImpl) ListAgencyCustomerDiscounts(ctx context.Context, req *d
 	}, nil
 }
 
+// ListAgencyDiscountSchedule returns every discount the agency gave a
+// customer, scheduled ones first, so past rates stay auditable
+func (a *AgencyFlowImpl) ListAgencyDiscountSchedule(ctx context.Context, req *dto.ListAgencyDiscountScheduleRequest, metadata *ClientMetadata) (*dto.ListAgencyDiscountScheduleResponse, error) {
+	var err error
+	defer func() {
+		if err != nil {
+			err = NewBusinessError("LIST_AGENCY_DISCOUNT_SCHEDULE_FAILED", "List agency discount schedule failed", err)
+		}
+	}()
+
+	customer, err := getCustomer(ctx, a.customerRepo, req.CustomerID)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = getAgency(ctx, a.customerRepo, req.AgencyID)
+	if err != nil {
+		return nil, err
+	}
+
+	if customer.ID == req.AgencyID {
+		return nil, ErrAgencyCannotListDiscountsForItself
+	}
+
+	if customer.ReferrerAgencyID == nil || *customer.ReferrerAgencyID != req.AgencyID {
+		return nil, ErrCustomerNotUnderAgency
+	}
+
+	filter := models.AgencyDiscountFilter{AgencyID: &req.AgencyID, CustomerID: &req.CustomerID}
+	rows, err := a.agencyDiscountRepo.ByFilter(ctx, filter, "effective_from DESC, id DESC", 0, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	now := utils.UTCNow()
+	items := make([]dto.AgencyDiscountScheduleItem, 0, len(rows))
+	for _, v := range rows {
+		items = append(items, dto.AgencyDiscountScheduleItem{
+			UUID:          v.UUID.String(),
+			DiscountRate:  v.DiscountRate,
+			Tiers:         agencyDiscountTiers(v.Tiers),
+			Status:        agencyDiscountStatus(v, now),
+			EffectiveFrom: v.EffectiveFrom,
+			ExpiresAt:     v.ExpiresAt,
+			CreatedAt:     v.CreatedAt,
+		})
+	}
+
+	return &dto.ListAgencyDiscountScheduleResponse{
+		Message: "Discount schedule retrieved successfully",
+		Items:   items,
+	}, nil
+}
+
 // ListAgencyCustomers returns all active customers referred by the agency
 func (a *AgencyFlowImpl) ListAgencyCustomers(ctx context.Context, req *dto.ListAgencyCustomersRequest, metadata *ClientMetadata) (*dto.ListAgencyCustomersResponse, error) {
 	var err error
@@ -353,3 +440,23 @@ func (a *AgencyFlowImpl) ListAgencyCustomers(ctx context.Context, req *dto.ListA
 
 	return &dto.ListAgencyCustomersResponse{Message: "Agency customers retrieved successfully", Items: items}, nil
 }
+
+func agencyDiscountTiers(tiers pricing.DiscountTiers) []dto.AgencyDiscountTier {
+	out := make([]dto.AgencyDiscountTier, 0, len(tiers))
+	for _, t := range tiers {
+		out = append(out, dto.AgencyDiscountTier{MinMonthlyAmountWithTax: t.MinMonthlyAmountWithTax, DiscountRate: t.DiscountRate})
+	}
+	return out
+}
+
+// agencyDiscountStatus reports whether ad is scheduled, active or expired at now
+func agencyDiscountStatus(ad *models.AgencyDiscount, now time.Time) string {
+	switch {
+	case ad.ExpiresAt != nil && !ad.ExpiresAt.After(now):
+		return "expired"
+	case ad.EffectiveFrom.After(now):
+		return "scheduled"
+	default:
+		return "active"
+	}
+}
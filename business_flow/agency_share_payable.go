@@ -0,0 +1,168 @@
+package businessflow
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/app/i18n"
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/repository"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/google/uuid"
+)
+
+// recordAgencySharePayable records the agency's share of a completed recharge
+// that was settled to the system Sheba number, and asks finance to pay it out
+func (p *PaymentFlowImpl) recordAgencySharePayable(ctx context.Context, paymentRequest *models.PaymentRequest, agencyID uint, amountWithTax uint64, reason models.AgencySettlementFallbackReason) error {
+	if amountWithTax == 0 {
+		return nil
+	}
+	payable := &models.AgencySharePayable{
+		UUID:             uuid.New(),
+		AgencyID:         agencyID,
+		CustomerID:       paymentRequest.CustomerID,
+		PaymentRequestID: paymentRequest.ID,
+		AmountWithTax:    amountWithTax,
+		Reason:           reason,
+		Status:           models.AgencySharePayablePending,
+	}
+	if err := p.agencyPayableRepo.Save(ctx, payable); err != nil {
+		return err
+	}
+
+	msg := p.localizer.Admin("admin.agency_share_payable", i18n.Args{"AgencyID": agencyID, "Amount": amountWithTax, "Reason": string(reason)})
+	enqueueSMS(ctx, p.jobs, p.adminCfg.ActiveDepositReviewers(), msg)
+	return nil
+}
+
+// AdminListAgencyPayables returns agency payables, newest first
+func (p *PaymentFlowImpl) AdminListAgencyPayables(ctx context.Context, filter dto.AdminListAgencyPayablesFilter) (*dto.AdminListAgencyPayablesResponse, error) {
+	page := max(1, filter.Page)
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 10
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	offset := (page - 1) * limit
+
+	pf := models.AgencySharePayableFilter{AgencyID: filter.AgencyID}
+	if filter.Status != nil && *filter.Status != "" {
+		status := models.AgencySharePayableStatus(*filter.Status)
+		pf.Status = &status
+	}
+
+	total, err := p.agencyPayableRepo.Count(ctx, pf)
+	if err != nil {
+		return nil, NewBusinessError("ADMIN_LIST_AGENCY_PAYABLES_FAILED", "Failed to count agency payables", err)
+	}
+	rows, err := p.agencyPayableRepo.ByFilter(ctx, pf, "id DESC", limit, offset)
+	if err != nil {
+		return nil, NewBusinessError("ADMIN_LIST_AGENCY_PAYABLES_FAILED", "Failed to list agency payables", err)
+	}
+	pending := pf
+	pending.Status = utils.ToPtr(models.AgencySharePayablePending)
+	pendingAmount, err := p.agencyPayableRepo.SumAmount(ctx, pending)
+	if err != nil {
+		return nil, NewBusinessError("ADMIN_LIST_AGENCY_PAYABLES_FAILED", "Failed to sum agency payables", err)
+	}
+
+	items := make([]dto.AgencyPayableItem, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, agencyPayableItem(row))
+	}
+	return &dto.AdminListAgencyPayablesResponse{
+		Message:       "Agency payables retrieved successfully",
+		Items:         items,
+		PendingAmount: pendingAmount,
+		Pagination: dto.PaginationInfo{
+			Total:      total,
+			Page:       page,
+			Limit:      limit,
+			TotalPages: int((total + int64(limit) - 1) / int64(limit)),
+		},
+	}, nil
+}
+
+// AdminMarkAgencyPayablePaid records that finance paid an agency share out,
+// e.g. by bank transfer
+func (p *PaymentFlowImpl) AdminMarkAgencyPayablePaid(ctx context.Context, id uuid.UUID, req *dto.AdminMarkAgencyPayablePaidRequest, adminID uint) (*dto.AdminAgencyPayableResponse, error) {
+	if req == nil {
+		return nil, NewBusinessError("VALIDATION_ERROR", "Request is required", nil)
+	}
+	meta := map[string]any{"payable_uuid": id.String(), "payment_reference": strings.TrimSpace(req.PaymentReference)}
+
+	var payable *models.AgencySharePayable
+	err := repository.WithTransaction(ctx, p.db, func(txCtx context.Context) error {
+		var err error
+		payable, err = p.agencyPayableRepo.ByUUID(txCtx, id)
+		if err != nil {
+			return err
+		}
+		if payable == nil {
+			return ErrAgencyPayableNotFound
+		}
+		if payable.Status != models.AgencySharePayablePending {
+			return ErrAgencyPayableAlreadyPaid
+		}
+
+		now := utils.UTCNow()
+		payable.PaidAt = &now
+		payable.PaidByAdminID = &adminID
+		payable.PaymentReference = strings.TrimSpace(req.PaymentReference)
+		payable.UpdatedAt = now
+		paid, err := p.agencyPayableRepo.MarkPaid(txCtx, payable)
+		if err != nil {
+			return err
+		}
+		if !paid {
+			return ErrAgencyPayableAlreadyPaid
+		}
+		payable.Status = models.AgencySharePayablePaid
+		return nil
+	})
+
+	var agencyID *uint
+	if payable != nil {
+		agencyID = &payable.AgencyID
+		meta["amount_with_tax"] = payable.AmountWithTax
+		meta["payment_request_id"] = payable.PaymentRequestID
+	}
+	if err != nil {
+		logAdminAction(ctx, p.auditRepo, models.AuditActionAdminAgencyPayablePaid, "Marking agency payable paid failed", false, agencyID, meta, err)
+		switch {
+		case IsAgencyPayableNotFound(err):
+			return nil, NewBusinessError("AGENCY_PAYABLE_NOT_FOUND", "Agency payable not found", err)
+		case IsAgencyPayableAlreadyPaid(err):
+			return nil, NewBusinessError("AGENCY_PAYABLE_ALREADY_PAID", "Agency payable was already paid", err)
+		}
+		return nil, NewBusinessError("ADMIN_MARK_AGENCY_PAYABLE_PAID_FAILED", "Failed to mark agency payable paid", err)
+	}
+	logAdminAction(ctx, p.auditRepo, models.AuditActionAdminAgencyPayablePaid, "Admin marked agency payable paid", true, agencyID, meta, nil)
+
+	return &dto.AdminAgencyPayableResponse{
+		Message: "Agency payable marked paid",
+		Payable: agencyPayableItem(payable),
+	}, nil
+}
+
+func agencyPayableItem(p *models.AgencySharePayable) dto.AgencyPayableItem {
+	item := dto.AgencyPayableItem{
+		UUID:             p.UUID.String(),
+		AgencyID:         p.AgencyID,
+		CustomerID:       p.CustomerID,
+		PaymentRequestID: p.PaymentRequestID,
+		AmountWithTax:    p.AmountWithTax,
+		Reason:           string(p.Reason),
+		Status:           string(p.Status),
+		PaymentReference: p.PaymentReference,
+		CreatedAt:        p.CreatedAt.Format(time.RFC3339),
+	}
+	if p.PaidAt != nil {
+		item.PaidAt = utils.ToPtr(p.PaidAt.Format(time.RFC3339))
+	}
+	return item
+}
@@ -0,0 +1,83 @@
+package businessflow
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/services"
+	"github.com/amirphl/Yamata-no-Orochi/config"
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/google/uuid"
+)
+
+func TestAgencySettlementFallback(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	invalid := newTestAgency()
+	invalid.ShebaNumber = utils.ToPtr("IR00")
+	p := &PaymentFlowImpl{customerRepo: &shebaCustomerRepoStub{customer: invalid}}
+	if _, err := p.agencySettlementFallback(ctx, 9); err == nil {
+		t.Fatal("invalid Sheba without fallback returned no error")
+	}
+	p.atipayCfg = config.AtipayConfig{SettlementFallback: true}
+	if reason, err := p.agencySettlementFallback(ctx, 9); err != nil || reason != models.AgencySettlementShebaInvalid {
+		t.Fatalf("invalid Sheba returned %q, %v", reason, err)
+	}
+
+	repo := &shebaCustomerRepoStub{customer: newTestAgency()}
+	inquirer := &shebaInquirerStub{inquiry: &services.ShebaInquiry{Active: true, Owners: []string{"علی رضایی"}}}
+	p = &PaymentFlowImpl{
+		customerRepo:      repo,
+		shebaVerification: NewShebaVerificationFlow(repo, &kycAuditStub{}, inquirer, config.CacheConfig{}, nil, time.Hour),
+	}
+	if _, err := p.agencySettlementFallback(ctx, 9); !IsAgencyShebaNotVerified(err) {
+		t.Fatalf("unverified Sheba without fallback returned %v", err)
+	}
+	p.atipayCfg = config.AtipayConfig{SettlementFallback: true}
+	if reason, err := p.agencySettlementFallback(ctx, 9); err != nil || reason != models.AgencySettlementShebaNotVerified {
+		t.Fatalf("unverified Sheba returned %q, %v", reason, err)
+	}
+
+	inquirer.err = errors.New("timeout")
+	if reason, err := p.agencySettlementFallback(ctx, 9); err != nil || reason != models.AgencySettlementShebaInquiryUnavailable {
+		t.Fatalf("unavailable inquiry returned %q, %v", reason, err)
+	}
+
+	inquirer.err = nil
+	inquirer.inquiry.Owners = []string{"آلفا"}
+	if reason, err := p.agencySettlementFallback(ctx, 9); err != nil || reason != "" {
+		t.Fatalf("verified Sheba returned %q, %v", reason, err)
+	}
+}
+
+func TestAgencyPayableItem(t *testing.T) {
+	t.Parallel()
+	created := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	payable := &models.AgencySharePayable{
+		UUID:             uuid.New(),
+		AgencyID:         9,
+		CustomerID:       12,
+		PaymentRequestID: 40,
+		AmountWithTax:    150000,
+		Reason:           models.AgencySettlementShebaInvalid,
+		Status:           models.AgencySharePayablePending,
+		CreatedAt:        created,
+	}
+	item := agencyPayableItem(payable)
+	if item.UUID != payable.UUID.String() || item.Reason != "sheba_invalid" || item.Status != "pending" || item.PaidAt != nil {
+		t.Fatalf("pending item = %+v", item)
+	}
+
+	paid := created.Add(time.Hour)
+	payable.Status = models.AgencySharePayablePaid
+	payable.PaidAt = &paid
+	payable.PaymentReference = "TRX-1"
+	item = agencyPayableItem(payable)
+	if item.PaidAt == nil || *item.PaidAt != "2026-03-01T11:00:00Z" || item.PaymentReference != "TRX-1" {
+		t.Fatalf("paid item = %+v", item)
+	}
+}
@@ -0,0 +1,528 @@
+package businessflow
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/app/services"
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/repository"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"gorm.io/gorm"
+)
+
+const (
+	maxAtipayReportRows      = 200_000
+	maxAtipayReportRowErrors = 1000
+
+	atipayReconciliationSourceUpload = "upload"
+	atipayReconciliationSourcePull   = "pull"
+)
+
+// atipayReportColumns maps the accepted header names of a settlement report
+// to the field they hold
+var atipayReportColumns = map[string]string{
+	"reference_number":   "reference",
+	"referencenumber":    "reference",
+	"reference":          "reference",
+	"ref":                "reference",
+	"rrn":                "rrn",
+	"reservation_number": "reservation",
+	"reservationnumber":  "reservation",
+	"invoice_number":     "reservation",
+	"amount":             "amount",
+	"amount_rial":        "amount",
+	"status":             "status",
+}
+
+// AtipayReconciliationFlow reconciles Atipay's daily settlement reports
+// against the payment requests completed that day.
+//
+// Reports are CSV files with an amount column in rials and at least one of
+// reference_number, rrn or reservation_number. Each report row is matched to
+// a payment request by Atipay reference number, RRN, or our invoice number
+// (sent to Atipay as the reservation number). Reconciling a day again
+// replaces its previous result.
+type AtipayReconciliationFlow interface {
+	Upload(ctx context.Context, reportDate string, csvReader io.Reader) (*dto.AdminAtipayReconciliationUploadResponse, error)
+	Pull(ctx context.Context, reportDate time.Time) (*dto.AtipayReconciliationSummary, error)
+	Report(ctx context.Context, filter dto.AdminAtipayReconciliationFilter) (*dto.AdminAtipayReconciliationReportResponse, error)
+}
+
+type AtipayReconciliationFlowImpl struct {
+	db                 *gorm.DB
+	reconciliationRepo repository.AtipayReconciliationRepository
+	paymentRequestRepo repository.PaymentRequestRepository
+	auditRepo          repository.AuditLogRepository
+	fetchers           []services.AtipaySettlementReportFetcher
+}
+
+// NewAtipayReconciliationFlow creates the reconciliation flow with a report
+// fetcher per Atipay merchant API key; fetchers is empty when reports are
+// only uploaded by admins.
+func NewAtipayReconciliationFlow(
+	db *gorm.DB,
+	reconciliationRepo repository.AtipayReconciliationRepository,
+	paymentRequestRepo repository.PaymentRequestRepository,
+	auditRepo repository.AuditLogRepository,
+	fetchers []services.AtipaySettlementReportFetcher,
+) AtipayReconciliationFlow {
+	return &AtipayReconciliationFlowImpl{
+		db:                 db,
+		reconciliationRepo: reconciliationRepo,
+		paymentRequestRepo: paymentRequestRepo,
+		auditRepo:          auditRepo,
+		fetchers:           fetchers,
+	}
+}
+
+// Upload reconciles a settlement report uploaded by an admin. Rows that
+// cannot be read are reported and skipped.
+func (f *AtipayReconciliationFlowImpl) Upload(ctx context.Context, reportDate string, csvReader io.Reader) (*dto.AdminAtipayReconciliationUploadResponse, error) {
+	if csvReader == nil {
+		return nil, NewBusinessError("VALIDATION_ERROR", "CSV file is required", nil)
+	}
+	day, err := parseAtipayReportDate(reportDate, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, csvReader); err != nil {
+		return nil, NewBusinessError("CSV_READ_ERROR", "Failed to read CSV", err)
+	}
+	rows, rowErrors, err := parseAtipaySettlementReport(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		return nil, NewBusinessError("ATIPAY_REPORT_FILE_INVALID", err.Error(), err)
+	}
+
+	meta := map[string]any{"report_date": day.Format("2006-01-02"), "failed_rows": len(rowErrors)}
+	summary, err := f.reconcile(ctx, day, atipayReconciliationSourceUpload, rows)
+	if err != nil {
+		logAdminAction(ctx, f.auditRepo, models.AuditActionAdminAtipayReconciliationUpload, "Atipay reconciliation upload failed", false, nil, meta, err)
+		return nil, err
+	}
+	meta["summary"] = summary
+	logAdminAction(ctx, f.auditRepo, models.AuditActionAdminAtipayReconciliationUpload, "Atipay settlement report reconciled", true, nil, meta, nil)
+
+	if len(rowErrors) > maxAtipayReportRowErrors {
+		rowErrors = rowErrors[:maxAtipayReportRowErrors]
+	}
+	return &dto.AdminAtipayReconciliationUploadResponse{
+		Message:   "Settlement report reconciled successfully",
+		Summary:   *summary,
+		RowErrors: rowErrors,
+	}, nil
+}
+
+// Pull downloads and reconciles the settlement reports of the Tehran calendar
+// day containing reportDate, one per merchant API key
+func (f *AtipayReconciliationFlowImpl) Pull(ctx context.Context, reportDate time.Time) (*dto.AtipayReconciliationSummary, error) {
+	if len(f.fetchers) == 0 {
+		return nil, NewBusinessError("ATIPAY_REPORT_FETCHER_UNAVAILABLE", "Atipay settlement report download is not configured", ErrAtipayReportFetcherUnavailable)
+	}
+	day, _ := utils.TehranDayBounds(reportDate)
+	meta := map[string]any{"report_date": day.Format("2006-01-02")}
+
+	// Payments of every terminal are reconciled together; with a report
+	// missing, those of its terminals would all look unsettled
+	var rows []atipaySettlementRow
+	failedRows := 0
+	for _, fetcher := range f.fetchers {
+		body, err := fetcher.FetchSettlementReport(ctx, day)
+		if err != nil {
+			logAdminAction(ctx, f.auditRepo, models.AuditActionAtipayReconciliationPulled, "Atipay settlement report download failed", false, nil, meta, err)
+			return nil, NewBusinessError("ATIPAY_REPORT_FETCH_FAILED", "Failed to download Atipay settlement report", err)
+		}
+		reportRows, rowErrors, err := parseAtipaySettlementReport(bytes.NewReader(body))
+		if err != nil {
+			logAdminAction(ctx, f.auditRepo, models.AuditActionAtipayReconciliationPulled, "Atipay settlement report is invalid", false, nil, meta, err)
+			return nil, NewBusinessError("ATIPAY_REPORT_FILE_INVALID", err.Error(), err)
+		}
+		rows = append(rows, reportRows...)
+		failedRows += len(rowErrors)
+	}
+
+	meta["failed_rows"] = failedRows
+	summary, err := f.reconcile(ctx, day, atipayReconciliationSourcePull, rows)
+	if err != nil {
+		logAdminAction(ctx, f.auditRepo, models.AuditActionAtipayReconciliationPulled, "Atipay reconciliation failed", false, nil, meta, err)
+		return nil, err
+	}
+	meta["summary"] = summary
+	logAdminAction(ctx, f.auditRepo, models.AuditActionAtipayReconciliationPulled, "Atipay settlement report reconciled", true, nil, meta, nil)
+	return summary, nil
+}
+
+// Report returns the reconciliation summary of a day and its entries,
+// discrepancies only unless matched entries are asked for
+func (f *AtipayReconciliationFlowImpl) Report(ctx context.Context, filter dto.AdminAtipayReconciliationFilter) (*dto.AdminAtipayReconciliationReportResponse, error) {
+	day, err := parseAtipayReportDate(filter.ReportDate, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	page := max(1, filter.Page)
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 10
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	offset := (page - 1) * limit
+
+	ef := models.AtipayReconciliationEntryFilter{ReportDate: &day}
+	if filter.Status != nil && *filter.Status != "" {
+		status := models.AtipayReconciliationStatus(*filter.Status)
+		if !status.Valid() {
+			return nil, NewBusinessError("VALIDATION_ERROR", "Reconciliation status is invalid", nil)
+		}
+		ef.Status = &status
+	} else if !filter.IncludeMatched {
+		ef.Discrepancies = utils.ToPtr(true)
+	}
+
+	counts, err := f.reconciliationRepo.CountByStatus(ctx, day.Format("2006-01-02"))
+	if err != nil {
+		return nil, NewBusinessError("ATIPAY_RECONCILIATION_REPORT_FAILED", "Failed to summarize reconciliation", err)
+	}
+	total, err := f.reconciliationRepo.Count(ctx, ef)
+	if err != nil {
+		return nil, NewBusinessError("ATIPAY_RECONCILIATION_REPORT_FAILED", "Failed to count reconciliation entries", err)
+	}
+	entries, err := f.reconciliationRepo.ByFilter(ctx, ef, "id ASC", limit, offset)
+	if err != nil {
+		return nil, NewBusinessError("ATIPAY_RECONCILIATION_REPORT_FAILED", "Failed to list reconciliation entries", err)
+	}
+
+	items := make([]dto.AtipayReconciliationEntryItem, 0, len(entries))
+	for _, e := range entries {
+		items = append(items, dto.AtipayReconciliationEntryItem{
+			UUID:              e.UUID.String(),
+			Status:            string(e.Status),
+			ReferenceNumber:   e.ReferenceNumber,
+			RRN:               e.RRN,
+			ReservationNumber: e.ReservationNumber,
+			PaymentRequestID:  e.PaymentRequestID,
+			ReportAmount:      e.ReportAmount,
+			SystemAmount:      e.SystemAmount,
+			ReportStatus:      e.ReportStatus,
+			Detail:            e.Detail,
+		})
+	}
+
+	summary := dto.AtipayReconciliationSummary{ReportDate: day.Format("2006-01-02")}
+	for status, n := range counts {
+		addAtipayReconciliationCount(&summary, status, int(n))
+	}
+	return &dto.AdminAtipayReconciliationReportResponse{
+		Message: "Reconciliation report retrieved successfully",
+		Summary: summary,
+		Items:   items,
+		Pagination: dto.PaginationInfo{
+			Total:      total,
+			Page:       page,
+			Limit:      limit,
+			TotalPages: int((total + int64(limit) - 1) / int64(limit)),
+		},
+	}, nil
+}
+
+// reconcile matches the report rows of a day against payment requests and
+// replaces the day's stored entries with the result
+func (f *AtipayReconciliationFlowImpl) reconcile(ctx context.Context, day time.Time, source string, rows []atipaySettlementRow) (*dto.AtipayReconciliationSummary, error) {
+	from, to := utils.TehranDayBounds(day)
+	completed, err := f.paymentRequestRepo.ListCompletedAtipay(ctx, from, to)
+	if err != nil {
+		return nil, NewBusinessError("ATIPAY_RECONCILIATION_FAILED", "Failed to load completed payment requests", err)
+	}
+	entries, err := reconcileAtipaySettlement(rows, completed, func(row atipaySettlementRow) (*models.PaymentRequest, error) {
+		if row.ReferenceNumber != "" {
+			pr, err := f.paymentRequestRepo.ByPaymentReference(ctx, row.ReferenceNumber)
+			if err != nil || pr != nil {
+				return pr, err
+			}
+		}
+		if row.ReservationNumber != "" {
+			return f.paymentRequestRepo.ByInvoiceNumber(ctx, row.ReservationNumber)
+		}
+		return nil, nil
+	})
+	if err != nil {
+		return nil, NewBusinessError("ATIPAY_RECONCILIATION_FAILED", "Failed to match settlement report", err)
+	}
+
+	reportDate := atipayReportDateValue(day)
+	for _, e := range entries {
+		e.ReportDate = reportDate
+		e.Source = source
+	}
+	err = repository.WithTransaction(ctx, f.db, func(txCtx context.Context) error {
+		return f.reconciliationRepo.ReplaceForDate(txCtx, reportDate.Format("2006-01-02"), entries)
+	})
+	if err != nil {
+		return nil, NewBusinessError("ATIPAY_RECONCILIATION_FAILED", "Failed to store reconciliation", err)
+	}
+
+	summary := summarizeAtipayReconciliation(entries)
+	summary.ReportDate = reportDate.Format("2006-01-02")
+	summary.Source = source
+	return summary, nil
+}
+
+// atipaySettlementRow is a parsed settlement report data row
+type atipaySettlementRow struct {
+	ReferenceNumber   string
+	RRN               string
+	ReservationNumber string
+	AmountRial        uint64
+	Status            string
+}
+
+// parseAtipaySettlementReport reads a whole settlement report. Rows without
+// an identifier or with an invalid amount are returned as row errors; a
+// missing header or malformed CSV fails the report.
+func parseAtipaySettlementReport(r io.Reader) ([]atipaySettlementRow, []dto.AtipayReconciliationRowError, error) {
+	reader := csv.NewReader(bufio.NewReader(r))
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil, ErrAtipayReportFileEmpty
+	}
+	if err != nil {
+		return nil, nil, ErrAtipayReportInvalidCSV
+	}
+	cols := make(map[string]int)
+	for i, h := range header {
+		name := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))
+		name = strings.NewReplacer(" ", "_", "-", "_").Replace(name)
+		if field, ok := atipayReportColumns[name]; ok {
+			if _, dup := cols[field]; !dup {
+				cols[field] = i
+			}
+		}
+	}
+	_, hasRef := cols["reference"]
+	_, hasRRN := cols["rrn"]
+	_, hasReservation := cols["reservation"]
+	if _, ok := cols["amount"]; !ok || !(hasRef || hasRRN || hasReservation) {
+		return nil, nil, ErrAtipayReportColumnsMissing
+	}
+	field := func(rec []string, name string) string {
+		if i, ok := cols[name]; ok && i < len(rec) {
+			return strings.TrimSpace(rec[i])
+		}
+		return ""
+	}
+
+	var (
+		rows      []atipaySettlementRow
+		rowErrors []dto.AtipayReconciliationRowError
+		rowNum    int
+	)
+	for {
+		rec, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: %v", ErrAtipayReportInvalidCSV, err)
+		}
+		rowNum++
+		if rowNum > maxAtipayReportRows {
+			return nil, nil, ErrAtipayReportTooManyRows
+		}
+
+		row := atipaySettlementRow{
+			ReferenceNumber:   field(rec, "reference"),
+			RRN:               field(rec, "rrn"),
+			ReservationNumber: field(rec, "reservation"),
+			Status:            strings.ToLower(field(rec, "status")),
+		}
+		if row.ReferenceNumber == "" && row.RRN == "" && row.ReservationNumber == "" {
+			rowErrors = append(rowErrors, dto.AtipayReconciliationRowError{Row: rowNum, Error: "row has no reference number, rrn or reservation number"})
+			continue
+		}
+		rawAmount := strings.NewReplacer(",", "", " ", "").Replace(field(rec, "amount"))
+		amount, err := strconv.ParseUint(rawAmount, 10, 64)
+		if err != nil {
+			rowErrors = append(rowErrors, dto.AtipayReconciliationRowError{Row: rowNum, Error: fmt.Sprintf("invalid amount %q", field(rec, "amount"))})
+			continue
+		}
+		row.AmountRial = amount
+		rows = append(rows, row)
+	}
+	if rowNum == 0 {
+		return nil, nil, ErrAtipayReportFileEmpty
+	}
+	return rows, rowErrors, nil
+}
+
+// reconcileAtipaySettlement matches report rows against payment requests.
+// Rows are first looked up among the day's completed requests, then through
+// lookup, which finds requests completed on another day or never completed.
+// Completed requests no row matched are missing from the report.
+func reconcileAtipaySettlement(
+	rows []atipaySettlementRow,
+	completed []*models.PaymentRequest,
+	lookup func(row atipaySettlementRow) (*models.PaymentRequest, error),
+) ([]*models.AtipayReconciliationEntry, error) {
+	byRef := make(map[string]*models.PaymentRequest, len(completed))
+	byRRN := make(map[string]*models.PaymentRequest, len(completed))
+	byInvoice := make(map[string]*models.PaymentRequest, len(completed))
+	for _, pr := range completed {
+		if pr.PaymentReference != "" {
+			byRef[pr.PaymentReference] = pr
+		}
+		if pr.PaymentRRN != "" {
+			byRRN[pr.PaymentRRN] = pr
+		}
+		byInvoice[pr.InvoiceNumber] = pr
+	}
+
+	entries := make([]*models.AtipayReconciliationEntry, 0, len(rows)+len(completed))
+	seenRequests := make(map[uint]struct{}, len(rows))
+	seenRefs := make(map[string]struct{}, len(rows))
+	for _, row := range rows {
+		pr := byRef[row.ReferenceNumber]
+		if pr == nil && row.RRN != "" {
+			pr = byRRN[row.RRN]
+		}
+		if pr == nil && row.ReservationNumber != "" {
+			pr = byInvoice[row.ReservationNumber]
+		}
+		if pr == nil {
+			var err error
+			if pr, err = lookup(row); err != nil {
+				return nil, err
+			}
+		}
+
+		reportAmount := row.AmountRial / 10
+		entry := &models.AtipayReconciliationEntry{
+			ReferenceNumber:   row.ReferenceNumber,
+			RRN:               row.RRN,
+			ReservationNumber: row.ReservationNumber,
+			ReportAmount:      &reportAmount,
+			ReportStatus:      row.Status,
+		}
+		entries = append(entries, entry)
+
+		refKey := strings.Join([]string{row.ReferenceNumber, row.RRN, row.ReservationNumber}, "|")
+		_, dupRef := seenRefs[refKey]
+		seenRefs[refKey] = struct{}{}
+		if pr == nil {
+			entry.Status = models.AtipayReconciliationMissingInSystem
+			entry.Detail = "no payment request matches this settlement"
+			if dupRef {
+				entry.Status = models.AtipayReconciliationDuplicate
+				entry.Detail = "settlement appears more than once in the report"
+			}
+			continue
+		}
+
+		entry.PaymentRequestID = &pr.ID
+		entry.SystemAmount = &pr.Amount
+		if entry.ReferenceNumber == "" {
+			entry.ReferenceNumber = pr.PaymentReference
+		}
+		if entry.ReservationNumber == "" {
+			entry.ReservationNumber = pr.InvoiceNumber
+		}
+		if _, dup := seenRequests[pr.ID]; dup {
+			entry.Status = models.AtipayReconciliationDuplicate
+			entry.Detail = "payment request is settled more than once in the report"
+			continue
+		}
+		seenRequests[pr.ID] = struct{}{}
+
+		switch {
+		case pr.Status != models.PaymentRequestStatusCompleted:
+			entry.Status = models.AtipayReconciliationStatusMismatch
+			entry.Detail = fmt.Sprintf("payment request is %s", pr.Status)
+		case row.AmountRial != pr.Amount*10:
+			entry.Status = models.AtipayReconciliationAmountMismatch
+			entry.Detail = fmt.Sprintf("settled %d rials, charged %d rials", row.AmountRial, pr.Amount*10)
+		default:
+			entry.Status = models.AtipayReconciliationMatched
+		}
+	}
+
+	for _, pr := range completed {
+		if _, ok := seenRequests[pr.ID]; ok {
+			continue
+		}
+		entries = append(entries, &models.AtipayReconciliationEntry{
+			Status:            models.AtipayReconciliationMissingInReport,
+			ReferenceNumber:   pr.PaymentReference,
+			RRN:               pr.PaymentRRN,
+			ReservationNumber: pr.InvoiceNumber,
+			PaymentRequestID:  &pr.ID,
+			SystemAmount:      &pr.Amount,
+			Detail:            "payment request completed but not settled in the report",
+		})
+	}
+	return entries, nil
+}
+
+// summarizeAtipayReconciliation counts entries by status
+func summarizeAtipayReconciliation(entries []*models.AtipayReconciliationEntry) *dto.AtipayReconciliationSummary {
+	summary := &dto.AtipayReconciliationSummary{}
+	for _, e := range entries {
+		addAtipayReconciliationCount(summary, e.Status, 1)
+	}
+	return summary
+}
+
+func addAtipayReconciliationCount(summary *dto.AtipayReconciliationSummary, status models.AtipayReconciliationStatus, n int) {
+	switch status {
+	case models.AtipayReconciliationMatched:
+		summary.Matched += n
+	case models.AtipayReconciliationAmountMismatch:
+		summary.AmountMismatch += n
+	case models.AtipayReconciliationStatusMismatch:
+		summary.StatusMismatch += n
+	case models.AtipayReconciliationMissingInSystem:
+		summary.MissingInSystem += n
+	case models.AtipayReconciliationMissingInReport:
+		summary.MissingInReport += n
+	case models.AtipayReconciliationDuplicate:
+		summary.Duplicate += n
+	}
+	if status != models.AtipayReconciliationMissingInReport {
+		summary.ReportRows += n
+	}
+	if status != models.AtipayReconciliationMatched {
+		summary.Discrepancies += n
+	}
+}
+
+// parseAtipayReportDate parses a YYYY-MM-DD Tehran calendar day that has
+// already ended
+func parseAtipayReportDate(value string, now time.Time) (time.Time, error) {
+	day, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(value), utils.TehranLocation())
+	if err != nil {
+		return time.Time{}, NewBusinessError("ATIPAY_REPORT_DATE_INVALID", "Report date must be formatted as YYYY-MM-DD", ErrAtipayReportDateInvalid)
+	}
+	if today, _ := utils.TehranDayBounds(now); !day.Before(today) {
+		return time.Time{}, NewBusinessError("ATIPAY_REPORT_DATE_INVALID", "Report date must be a past day", ErrAtipayReportDateInvalid)
+	}
+	return day, nil
+}
+
+// atipayReportDateValue returns the calendar day of a Tehran time as a UTC
+// midnight, so it is stored as the same date column value
+func atipayReportDateValue(day time.Time) time.Time {
+	y, m, d := day.In(utils.TehranLocation()).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
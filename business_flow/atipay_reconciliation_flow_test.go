@@ -0,0 +1,128 @@
+package businessflow
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/models"
+)
+
+func TestParseAtipaySettlementReport(t *testing.T) {
+	t.Parallel()
+
+	csv := "\ufeffReference Number,RRN,Amount,Status\n" +
+		"ref-1,rrn-1,\"1,000,000\",Settled\n" +
+		",,50000,settled\n" +
+		"ref-3,,abc,settled\n" +
+		"ref-4,,20000,\n"
+	rows, rowErrors, err := parseAtipaySettlementReport(strings.NewReader(csv))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(rows) != 2 || rows[0].ReferenceNumber != "ref-1" || rows[0].RRN != "rrn-1" || rows[0].AmountRial != 1_000_000 || rows[0].Status != "settled" {
+		t.Fatalf("rows = %+v", rows)
+	}
+	if len(rowErrors) != 2 || rowErrors[0].Row != 2 || rowErrors[1].Row != 3 {
+		t.Fatalf("row errors = %+v", rowErrors)
+	}
+
+	for name, bad := range map[string]string{
+		"empty":          "",
+		"header only":    "reference_number,amount\n",
+		"no amount":      "reference_number,rrn\nref-1,rrn-1\n",
+		"no identifiers": "amount,status\n1000,settled\n",
+	} {
+		if _, _, err := parseAtipaySettlementReport(strings.NewReader(bad)); !IsAtipayReportFileInvalid(err) {
+			t.Fatalf("%s: err = %v", name, err)
+		}
+	}
+}
+
+func TestReconcileAtipaySettlement(t *testing.T) {
+	t.Parallel()
+
+	completed := func(id uint, ref, rrn, invoice string, amount uint64) *models.PaymentRequest {
+		return &models.PaymentRequest{ID: id, PaymentReference: ref, PaymentRRN: rrn, InvoiceNumber: invoice, Amount: amount, Status: models.PaymentRequestStatusCompleted}
+	}
+	day := []*models.PaymentRequest{
+		completed(1, "ref-1", "rrn-1", "inv-1", 100_000),
+		completed(2, "ref-2", "rrn-2", "inv-2", 50_000),
+		completed(3, "ref-3", "rrn-3", "inv-3", 70_000),
+		completed(4, "ref-4", "rrn-4", "inv-4", 10_000),
+	}
+	// Found outside the day: one completed the day before, one still pending
+	others := map[string]*models.PaymentRequest{
+		"ref-9":  completed(9, "ref-9", "", "inv-9", 30_000),
+		"inv-10": {ID: 10, InvoiceNumber: "inv-10", Amount: 40_000, Status: models.PaymentRequestStatusPending},
+	}
+	lookup := func(row atipaySettlementRow) (*models.PaymentRequest, error) {
+		if pr := others[row.ReferenceNumber]; pr != nil {
+			return pr, nil
+		}
+		return others[row.ReservationNumber], nil
+	}
+	rows := []atipaySettlementRow{
+		{ReferenceNumber: "ref-1", AmountRial: 1_000_000},  // matched
+		{RRN: "rrn-2", AmountRial: 400_000},                // amount mismatch, by RRN
+		{ReservationNumber: "inv-3", AmountRial: 700_000},  // matched, by invoice number
+		{ReferenceNumber: "ref-1", AmountRial: 1_000_000},  // duplicate
+		{ReferenceNumber: "ref-9", AmountRial: 300_000},    // matched, completed another day
+		{ReservationNumber: "inv-10", AmountRial: 400_000}, // status mismatch
+		{ReferenceNumber: "ref-x", AmountRial: 5_000},      // missing in system
+	}
+
+	entries, err := reconcileAtipaySettlement(rows, day, lookup)
+	if err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+	want := []models.AtipayReconciliationStatus{
+		models.AtipayReconciliationMatched,
+		models.AtipayReconciliationAmountMismatch,
+		models.AtipayReconciliationMatched,
+		models.AtipayReconciliationDuplicate,
+		models.AtipayReconciliationMatched,
+		models.AtipayReconciliationStatusMismatch,
+		models.AtipayReconciliationMissingInSystem,
+		models.AtipayReconciliationMissingInReport, // request 4
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("got %d entries, want %d", len(entries), len(want))
+	}
+	for i, e := range entries {
+		if e.Status != want[i] {
+			t.Fatalf("entry %d status = %s, want %s (%+v)", i, e.Status, want[i], e)
+		}
+	}
+	if e := entries[1]; *e.ReportAmount != 40_000 || *e.SystemAmount != 50_000 || *e.PaymentRequestID != 2 {
+		t.Fatalf("amount mismatch entry = %+v", e)
+	}
+	if e := entries[7]; *e.PaymentRequestID != 4 || e.ReportAmount != nil {
+		t.Fatalf("missing in report entry = %+v", e)
+	}
+
+	summary := summarizeAtipayReconciliation(entries)
+	if summary.ReportRows != 7 || summary.Matched != 3 || summary.Discrepancies != 5 || summary.MissingInReport != 1 {
+		t.Fatalf("summary = %+v", summary)
+	}
+}
+
+func TestParseAtipayReportDate(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 3, 10, 22, 0, 0, 0, time.UTC) // 2025-03-11 01:30 in Tehran
+	for value, wantErr := range map[string]bool{
+		"2025-03-10": false,
+		"2025-03-11": true, // today in Tehran
+		"2025-03-12": true,
+		"10/03/2025": true,
+	} {
+		day, err := parseAtipayReportDate(value, now)
+		if IsAtipayReportDateInvalid(err) != wantErr {
+			t.Fatalf("%s: err = %v", value, err)
+		}
+		if err == nil && day.Format("2006-01-02") != value {
+			t.Fatalf("%s: day = %s", value, day)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package businessflow
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/amirphl/Yamata-no-Orochi/config"
+)
+
+// atipayTerminalRouter picks the Atipay terminal of each recharge and finds
+// the terminal a payment request was sent to when its callback arrives
+type atipayTerminalRouter struct {
+	terminals []config.AtipayTerminal
+	byAmount  bool
+	next      atomic.Uint64
+}
+
+// newAtipayTerminalRouter routes over the configured terminals. A malformed
+// ATIPAY_TERMINALS is refused at startup, so only the primary terminal is
+// used if it gets here.
+func newAtipayTerminalRouter(cfg config.AtipayConfig) *atipayTerminalRouter {
+	terminals, err := cfg.AllTerminals()
+	if err != nil {
+		terminals = []config.AtipayTerminal{{Terminal: cfg.Terminal, APIKey: cfg.APIKey}}
+	}
+	return &atipayTerminalRouter{
+		terminals: terminals,
+		byAmount:  cfg.Routing == config.AtipayRoutingAmount,
+	}
+}
+
+// pick returns the terminal for a recharge of amountWithTax Tomans. By
+// amount, the terminals with the smallest maximum covering the amount take
+// turns; otherwise all terminals do.
+func (r *atipayTerminalRouter) pick(amountWithTax uint64) config.AtipayTerminal {
+	candidates := r.terminals
+	if r.byAmount {
+		candidates = nil
+		var best uint64
+		for _, t := range r.terminals {
+			if t.MaxAmount != 0 && t.MaxAmount < amountWithTax {
+				continue
+			}
+			switch {
+			case candidates == nil || lessAtipayLimit(t.MaxAmount, best):
+				candidates, best = []config.AtipayTerminal{t}, t.MaxAmount
+			case t.MaxAmount == best:
+				candidates = append(candidates, t)
+			}
+		}
+	}
+	n := r.next.Add(1) - 1
+	return candidates[n%uint64(len(candidates))]
+}
+
+// lessAtipayLimit orders maximum amounts with zero, no limit, last
+func lessAtipayLimit(a, b uint64) bool {
+	if a == 0 {
+		return false
+	}
+	return b == 0 || a < b
+}
+
+// terminal returns the terminal a payment request was sent to. Requests
+// created before terminals were recorded went to the primary terminal.
+func (r *atipayTerminalRouter) terminal(id string) (config.AtipayTerminal, error) {
+	if id == "" {
+		return r.terminals[0], nil
+	}
+	for _, t := range r.terminals {
+		if t.Terminal == id {
+			return t, nil
+		}
+	}
+	return config.AtipayTerminal{}, fmt.Errorf("%w: %s", ErrAtipayTerminalNotConfigured, id)
+}
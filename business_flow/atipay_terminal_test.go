@@ -0,0 +1,72 @@
+package businessflow
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/amirphl/Yamata-no-Orochi/config"
+)
+
+func TestAtipayTerminalRouterRoundRobin(t *testing.T) {
+	t.Parallel()
+	r := newAtipayTerminalRouter(config.AtipayConfig{Terminal: "T1", APIKey: "k1", Terminals: "T2:k2, T3:k3:5000000"})
+
+	var got []string
+	for range 4 {
+		got = append(got, r.pick(100000000).Terminal)
+	}
+	if want := []string{"T1", "T2", "T3", "T1"}; !slices.Equal(got, want) {
+		t.Fatalf("round robin picked %v, want %v", got, want)
+	}
+}
+
+func TestAtipayTerminalRouterByAmount(t *testing.T) {
+	t.Parallel()
+	r := newAtipayTerminalRouter(config.AtipayConfig{
+		Terminal:  "T1",
+		APIKey:    "k1",
+		Terminals: "T2:k2:5000000,T3:k3:1000000,T4:k4:1000000",
+		Routing:   config.AtipayRoutingAmount,
+	})
+
+	tests := []struct {
+		amount uint64
+		want   []string
+	}{
+		// The smallest limits take turns
+		{500000, []string{"T3", "T4", "T3"}},
+		{1000000, []string{"T4"}},
+		{3000000, []string{"T2", "T2"}},
+		// Above every limit only the primary terminal is left
+		{9000000, []string{"T1"}},
+	}
+	for _, tt := range tests {
+		for _, want := range tt.want {
+			if got := r.pick(tt.amount).Terminal; got != want {
+				t.Fatalf("pick(%d) = %s, want %s", tt.amount, got, want)
+			}
+		}
+	}
+}
+
+func TestAtipayTerminalLookup(t *testing.T) {
+	t.Parallel()
+	r := newAtipayTerminalRouter(config.AtipayConfig{Terminal: "T1", APIKey: "k1", Terminals: "T2:k2"})
+
+	if got, err := r.terminal("T2"); err != nil || got.APIKey != "k2" {
+		t.Fatalf("terminal(T2) = %+v, %v", got, err)
+	}
+	// Requests made before terminals were recorded went to the primary one
+	if got, err := r.terminal(""); err != nil || got.APIKey != "k1" {
+		t.Fatalf("terminal(\"\") = %+v, %v", got, err)
+	}
+	if _, err := r.terminal("T9"); !IsAtipayTerminalNotConfigured(err) {
+		t.Fatalf("terminal(T9) error = %v", err)
+	}
+
+	// A malformed list falls back to the primary terminal
+	r = newAtipayTerminalRouter(config.AtipayConfig{Terminal: "T1", APIKey: "k1", Terminals: "T2"})
+	if got := r.pick(10000).Terminal; got != "T1" || len(r.terminals) != 1 {
+		t.Fatalf("malformed terminals picked %s of %d", got, len(r.terminals))
+	}
+}
@@ -0,0 +1,105 @@
+package businessflow
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/repository"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+)
+
+// AudienceColorRules are the thresholds of the audience color lifecycle. A
+// threshold of 0 disables its rule.
+type AudienceColorRules struct {
+	// Lookback bounds the delivery reports and clicks taken into account
+	Lookback time.Duration
+	// DemoteFailures demotes white profiles with this many undelivered SMS
+	// and no delivered one
+	DemoteFailures int
+	// DemoteOptOuts demotes white profiles whose number is blacklisted
+	DemoteOptOuts bool
+	// DemotePermanentErrors demotes white profiles an SMS was rejected for
+	// with a permanent provider error
+	DemotePermanentErrors bool
+	// PromoteClicks promotes pink profiles with this many short link clicks
+	PromoteClicks int
+	// BatchSize bounds the profiles each rule moves per run
+	BatchSize int
+}
+
+// AudienceColorFlow moves audience profiles between white and pink based on
+// their delivery reports, provider errors, opt-outs and clicks, recording
+// every move
+type AudienceColorFlow interface {
+	// ApplyTransitions runs every enabled rule once and returns how many
+	// profiles each moved
+	ApplyTransitions(ctx context.Context) (map[models.AudienceColorTransitionReason]int, error)
+}
+
+type AudienceColorFlowImpl struct {
+	transitionRepo repository.AudienceColorTransitionRepository
+	rules          AudienceColorRules
+}
+
+func NewAudienceColorFlow(transitionRepo repository.AudienceColorTransitionRepository, rules AudienceColorRules) AudienceColorFlow {
+	if rules.BatchSize <= 0 {
+		rules.BatchSize = 1000
+	}
+	return &AudienceColorFlowImpl{
+		transitionRepo: transitionRepo,
+		rules:          rules,
+	}
+}
+
+func (f *AudienceColorFlowImpl) ApplyTransitions(ctx context.Context) (map[models.AudienceColorTransitionReason]int, error) {
+	now := utils.UTCNow()
+	since := now.Add(-f.rules.Lookback)
+	limit := f.rules.BatchSize
+
+	// Opt-outs go first so a blacklisted profile is never promoted on clicks
+	// it made before opting out
+	rules := []struct {
+		enabled  bool
+		from, to string
+		reason   models.AudienceColorTransitionReason
+		find     func() ([]models.AudienceColorCandidate, error)
+	}{
+		{f.rules.DemoteOptOuts, models.AudienceColorWhite, models.AudienceColorPink, models.AudienceColorReasonOptOut,
+			func() ([]models.AudienceColorCandidate, error) {
+				return f.transitionRepo.OptOutDemotionCandidates(ctx, limit)
+			}},
+		{f.rules.DemotePermanentErrors, models.AudienceColorWhite, models.AudienceColorPink, models.AudienceColorReasonPermanentError,
+			func() ([]models.AudienceColorCandidate, error) {
+				return f.transitionRepo.PermanentErrorDemotionCandidates(ctx, since, limit)
+			}},
+		{f.rules.DemoteFailures > 0, models.AudienceColorWhite, models.AudienceColorPink, models.AudienceColorReasonDeliveryFailures,
+			func() ([]models.AudienceColorCandidate, error) {
+				return f.transitionRepo.FailureDemotionCandidates(ctx, since, f.rules.DemoteFailures, limit)
+			}},
+		{f.rules.PromoteClicks > 0, models.AudienceColorPink, models.AudienceColorWhite, models.AudienceColorReasonClickEngagement,
+			func() ([]models.AudienceColorCandidate, error) {
+				return f.transitionRepo.ClickPromotionCandidates(ctx, since, f.rules.PromoteClicks, limit)
+			}},
+	}
+
+	moved := make(map[models.AudienceColorTransitionReason]int)
+	for _, rule := range rules {
+		if !rule.enabled {
+			continue
+		}
+		candidates, err := rule.find()
+		if err != nil {
+			return moved, fmt.Errorf("find %s candidates: %w", rule.reason, err)
+		}
+		n, err := f.transitionRepo.Apply(ctx, rule.from, rule.to, rule.reason, candidates, now)
+		if err != nil {
+			return moved, fmt.Errorf("apply %s transitions: %w", rule.reason, err)
+		}
+		if n > 0 {
+			moved[rule.reason] = n
+		}
+	}
+	return moved, nil
+}
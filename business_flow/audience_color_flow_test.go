@@ -0,0 +1,93 @@
+package businessflow
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/repository"
+)
+
+type audienceColorRepoStub struct {
+	repository.AudienceColorTransitionRepository
+	optOuts   []models.AudienceColorCandidate
+	failures  []models.AudienceColorCandidate
+	permanent []models.AudienceColorCandidate
+	clicks    []models.AudienceColorCandidate
+	clicksErr error
+	calls     []string
+	since     time.Time
+}
+
+func (r *audienceColorRepoStub) OptOutDemotionCandidates(_ context.Context, _ int) ([]models.AudienceColorCandidate, error) {
+	r.calls = append(r.calls, "opt_outs")
+	return r.optOuts, nil
+}
+
+func (r *audienceColorRepoStub) FailureDemotionCandidates(_ context.Context, since time.Time, _, _ int) ([]models.AudienceColorCandidate, error) {
+	r.calls = append(r.calls, "failures")
+	r.since = since
+	return r.failures, nil
+}
+
+func (r *audienceColorRepoStub) PermanentErrorDemotionCandidates(_ context.Context, _ time.Time, _ int) ([]models.AudienceColorCandidate, error) {
+	r.calls = append(r.calls, "permanent")
+	return r.permanent, nil
+}
+
+func (r *audienceColorRepoStub) ClickPromotionCandidates(_ context.Context, _ time.Time, _, _ int) ([]models.AudienceColorCandidate, error) {
+	r.calls = append(r.calls, "clicks")
+	return r.clicks, r.clicksErr
+}
+
+func (r *audienceColorRepoStub) Apply(_ context.Context, from, to string, reason models.AudienceColorTransitionReason, candidates []models.AudienceColorCandidate, _ time.Time) (int, error) {
+	r.calls = append(r.calls, "apply:"+from+">"+to)
+	return len(candidates), nil
+}
+
+func TestAudienceColorFlowAppliesEnabledRules(t *testing.T) {
+	t.Parallel()
+
+	repo := &audienceColorRepoStub{
+		optOuts:   []models.AudienceColorCandidate{{AudienceProfileID: 1}},
+		failures:  []models.AudienceColorCandidate{{AudienceProfileID: 2, Evidence: 3}, {AudienceProfileID: 3, Evidence: 4}},
+		permanent: []models.AudienceColorCandidate{{AudienceProfileID: 4, Evidence: 1}},
+	}
+	flow := NewAudienceColorFlow(repo, AudienceColorRules{Lookback: 24 * time.Hour, DemoteFailures: 3, DemoteOptOuts: true, DemotePermanentErrors: true})
+
+	moved, err := flow.ApplyTransitions(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(repo.calls, ","); got != "opt_outs,apply:white>pink,permanent,apply:white>pink,failures,apply:white>pink" {
+		t.Fatalf("unexpected calls %s", got)
+	}
+	if moved[models.AudienceColorReasonOptOut] != 1 || moved[models.AudienceColorReasonDeliveryFailures] != 2 ||
+		moved[models.AudienceColorReasonPermanentError] != 1 || len(moved) != 3 {
+		t.Fatalf("unexpected moves %v", moved)
+	}
+	if age := time.Since(repo.since); age < 24*time.Hour || age > 25*time.Hour {
+		t.Fatalf("expected a 24h lookback, got %v", age)
+	}
+}
+
+func TestAudienceColorFlowKeepsMovesOfEarlierRulesOnError(t *testing.T) {
+	t.Parallel()
+
+	repo := &audienceColorRepoStub{
+		optOuts:   []models.AudienceColorCandidate{{AudienceProfileID: 1}},
+		clicksErr: errors.New("db down"),
+	}
+	flow := NewAudienceColorFlow(repo, AudienceColorRules{Lookback: time.Hour, DemoteOptOuts: true, PromoteClicks: 1})
+
+	moved, err := flow.ApplyTransitions(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "click_engagement") {
+		t.Fatalf("expected the click rule to fail, got %v", err)
+	}
+	if moved[models.AudienceColorReasonOptOut] != 1 {
+		t.Fatalf("expected the opt-out move to be reported, got %v", moved)
+	}
+}
@@ -0,0 +1,503 @@
+package businessflow
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/repository"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/amirphl/Yamata-no-Orochi/utils/phonenumber"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	maxAudienceImportRows      = 1_000_000
+	maxAudienceImportRowErrors = 1000
+	audienceImportChunkSize    = 1000
+	// A processing job not updated for this long is assumed abandoned and resumed
+	audienceImportStaleAfter = 10 * time.Minute
+
+	audienceImportPhoneColumn = "phone_number"
+	audienceImportTagsColumn  = "tags"
+	audienceImportTagSep      = ";"
+)
+
+// AudienceImportFlow imports phone numbers with tags from admin-uploaded CSV
+// files into audience profiles. Uploads are stored and validated synchronously;
+// rows are imported in the background by the audience import scheduler and
+// the admin polls the job for progress and per-row errors.
+//
+// The CSV must have a phone_number column and may have a tags column holding
+// tag names separated by ';'. Numbers are normalized to E.164 and matched
+// against existing profiles in any stored form; existing profiles get the row
+// tags merged in, new numbers become white profiles.
+type AudienceImportFlow interface {
+	CreateImport(ctx context.Context, csvReader io.Reader, fileName string) (*dto.AudienceImportJobResponse, error)
+	GetImport(ctx context.Context, jobUUID string) (*dto.AudienceImportJobResponse, error)
+	ProcessNextImport(ctx context.Context) (bool, error)
+}
+
+type AudienceImportFlowImpl struct {
+	jobRepo     repository.AudienceImportJobRepository
+	profileRepo repository.AudienceProfileRepository
+	tagRepo     repository.TagRepository
+	auditRepo   repository.AuditLogRepository
+	db          *gorm.DB
+}
+
+func NewAudienceImportFlow(
+	jobRepo repository.AudienceImportJobRepository,
+	profileRepo repository.AudienceProfileRepository,
+	tagRepo repository.TagRepository,
+	auditRepo repository.AuditLogRepository,
+	db *gorm.DB,
+) AudienceImportFlow {
+	return &AudienceImportFlowImpl{
+		jobRepo:     jobRepo,
+		profileRepo: profileRepo,
+		tagRepo:     tagRepo,
+		auditRepo:   auditRepo,
+		db:          db,
+	}
+}
+
+func audienceImportDir() string {
+	return filepath.Join("data", "audience_imports")
+}
+
+// CreateImport validates the CSV header, counts its rows, stores the file and
+// queues an import job.
+func (f *AudienceImportFlowImpl) CreateImport(ctx context.Context, csvReader io.Reader, fileName string) (*dto.AudienceImportJobResponse, error) {
+	if csvReader == nil {
+		return nil, NewBusinessError("VALIDATION_ERROR", "CSV file is required", nil)
+	}
+
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, csvReader); err != nil {
+		return nil, NewBusinessError("CSV_READ_ERROR", "Failed to read CSV", err)
+	}
+	total, err := countAudienceImportRows(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		return nil, NewBusinessError("AUDIENCE_IMPORT_FILE_INVALID", err.Error(), err)
+	}
+
+	fileName = strings.TrimSpace(filepath.Base(fileName))
+	if fileName == "" || fileName == "." {
+		fileName = "audience.csv"
+	}
+	if len(fileName) > 255 {
+		fileName = fileName[:255]
+	}
+
+	jobUUID := uuid.New()
+	path := filepath.Join(audienceImportDir(), jobUUID.String()+".csv")
+	if err := atomicWrite(path, buf.Bytes(), 0o640); err != nil {
+		return nil, NewBusinessError("AUDIENCE_IMPORT_STORE_FAILED", "Failed to store CSV", err)
+	}
+
+	job := &models.AudienceImportJob{
+		UUID:      jobUUID,
+		FileName:  fileName,
+		FilePath:  path,
+		Status:    models.AudienceImportJobStatusPending,
+		TotalRows: total,
+		RowErrors: json.RawMessage(`[]`),
+	}
+	if adminID, ok := adminIDFromContext(ctx); ok {
+		job.AdminID = &adminID
+	}
+	meta := map[string]any{"job_uuid": jobUUID.String(), "file_name": fileName, "total_rows": total}
+	if err := f.jobRepo.Save(ctx, job); err != nil {
+		_ = os.Remove(path)
+		logAdminAction(ctx, f.auditRepo, models.AuditActionAdminAudienceImportCreate, "Queue audience import failed", false, nil, meta, err)
+		return nil, NewBusinessError("AUDIENCE_IMPORT_CREATE_FAILED", "Failed to queue audience import", err)
+	}
+	logAdminAction(ctx, f.auditRepo, models.AuditActionAdminAudienceImportCreate, "Audience import queued", true, nil, meta, nil)
+
+	return &dto.AudienceImportJobResponse{
+		Message: "Audience import queued",
+		Job:     toAudienceImportJobItem(job),
+	}, nil
+}
+
+// GetImport returns the progress and row errors of an import job
+func (f *AudienceImportFlowImpl) GetImport(ctx context.Context, jobUUID string) (*dto.AudienceImportJobResponse, error) {
+	id, err := uuid.Parse(strings.TrimSpace(jobUUID))
+	if err != nil {
+		return nil, NewBusinessError("AUDIENCE_IMPORT_NOT_FOUND", "Audience import not found", ErrAudienceImportNotFound)
+	}
+	job, err := f.jobRepo.ByUUID(ctx, id)
+	if err != nil {
+		return nil, NewBusinessError("AUDIENCE_IMPORT_LOOKUP_FAILED", "Failed to lookup audience import", err)
+	}
+	if job == nil {
+		return nil, NewBusinessError("AUDIENCE_IMPORT_NOT_FOUND", "Audience import not found", ErrAudienceImportNotFound)
+	}
+	return &dto.AudienceImportJobResponse{
+		Message: "Audience import retrieved successfully",
+		Job:     toAudienceImportJobItem(job),
+	}, nil
+}
+
+// ProcessNextImport claims one pending or abandoned job and imports its
+// remaining rows. It reports whether a job was claimed.
+func (f *AudienceImportFlowImpl) ProcessNextImport(ctx context.Context) (bool, error) {
+	job, err := f.jobRepo.ClaimNext(ctx, utils.UTCNow().Add(-audienceImportStaleAfter))
+	if err != nil {
+		return false, NewBusinessError("AUDIENCE_IMPORT_CLAIM_FAILED", "Failed to claim audience import", err)
+	}
+	if job == nil {
+		return false, nil
+	}
+
+	if err := f.processImport(ctx, job); err != nil {
+		if ctx.Err() != nil {
+			// Left in processing; another run resumes it once it goes stale
+			return true, err
+		}
+		msg := err.Error()
+		job.Status = models.AudienceImportJobStatusFailed
+		job.ErrorMessage = &msg
+		job.CompletedAt = utils.ToPtr(utils.UTCNow())
+		if uerr := f.jobRepo.Update(ctx, job); uerr != nil {
+			return true, fmt.Errorf("audience import %s failed: %w (status update: %v)", job.UUID, err, uerr)
+		}
+		return true, fmt.Errorf("audience import %s failed: %w", job.UUID, err)
+	}
+	return true, nil
+}
+
+// audienceImportColumns locates the phone_number and optional tags columns
+type audienceImportColumns struct {
+	phone int
+	tags  int
+}
+
+func parseAudienceImportHeader(header []string) (audienceImportColumns, error) {
+	cols := audienceImportColumns{phone: -1, tags: -1}
+	for i, h := range header {
+		name := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))
+		switch name {
+		case audienceImportPhoneColumn:
+			cols.phone = i
+		case audienceImportTagsColumn:
+			cols.tags = i
+		}
+	}
+	if cols.phone < 0 {
+		return cols, ErrAudienceImportPhoneColumnMissing
+	}
+	return cols, nil
+}
+
+func newAudienceImportCSVReader(r io.Reader) *csv.Reader {
+	reader := csv.NewReader(bufio.NewReader(r))
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+	return reader
+}
+
+// countAudienceImportRows validates the header and returns the number of data rows
+func countAudienceImportRows(r io.Reader) (int64, error) {
+	reader := newAudienceImportCSVReader(r)
+	header, err := reader.Read()
+	if err == io.EOF {
+		return 0, ErrAudienceImportFileEmpty
+	}
+	if err != nil {
+		return 0, ErrAudienceImportInvalidCSV
+	}
+	if _, err := parseAudienceImportHeader(header); err != nil {
+		return 0, err
+	}
+
+	var total int64
+	for {
+		_, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, fmt.Errorf("%w: %v", ErrAudienceImportInvalidCSV, err)
+		}
+		total++
+		if total > maxAudienceImportRows {
+			return 0, ErrAudienceImportTooManyRows
+		}
+	}
+	if total == 0 {
+		return 0, ErrAudienceImportFileEmpty
+	}
+	return total, nil
+}
+
+// audienceImportRow is a parsed CSV data row
+type audienceImportRow struct {
+	phone    string
+	aliases  []string
+	tagNames []string
+}
+
+func parseAudienceImportRecord(rec []string, cols audienceImportColumns) (audienceImportRow, error) {
+	var row audienceImportRow
+	if cols.phone >= len(rec) || strings.TrimSpace(rec[cols.phone]) == "" {
+		return row, errors.New("phone number is empty")
+	}
+	n, err := phonenumber.Parse(rec[cols.phone])
+	if err != nil {
+		return row, errors.New("phone number is not a valid Iranian mobile number")
+	}
+	forms := n.Forms()
+	row.phone = forms[0]
+	row.aliases = forms[1:]
+
+	if cols.tags >= 0 && cols.tags < len(rec) {
+		seen := make(map[string]struct{})
+		for _, name := range strings.Split(rec[cols.tags], audienceImportTagSep) {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+			if _, dup := seen[name]; dup {
+				continue
+			}
+			seen[name] = struct{}{}
+			row.tagNames = append(row.tagNames, name)
+		}
+	}
+	return row, nil
+}
+
+// processImport imports the rows of a claimed job after ProcessedRows. Each
+// chunk of rows is committed together with the job counters so a resumed job
+// neither skips nor repeats rows.
+func (f *AudienceImportFlowImpl) processImport(ctx context.Context, job *models.AudienceImportJob) error {
+	file, err := os.Open(job.FilePath)
+	if err != nil {
+		return fmt.Errorf("open import file: %w", err)
+	}
+	defer file.Close()
+
+	reader := newAudienceImportCSVReader(file)
+	header, err := reader.Read()
+	if err != nil {
+		return ErrAudienceImportInvalidCSV
+	}
+	cols, err := parseAudienceImportHeader(header)
+	if err != nil {
+		return err
+	}
+
+	var rowErrors []models.AudienceImportRowError
+	if len(job.RowErrors) > 0 {
+		if err := json.Unmarshal(job.RowErrors, &rowErrors); err != nil {
+			return fmt.Errorf("decode row errors: %w", err)
+		}
+	}
+
+	tagIDs := make(map[string]int32)
+	seen := make(map[string]struct{})
+	entries := make([]repository.AudienceProfileImportEntry, 0, audienceImportChunkSize)
+	var pendingRows, pendingFailed, pendingDuplicate int64
+	var pendingErrors []models.AudienceImportRowError
+	var rowNum int64
+
+	fail := func(row int64, phone string, msg string) {
+		pendingFailed++
+		if len(rowErrors)+len(pendingErrors) < maxAudienceImportRowErrors {
+			pendingErrors = append(pendingErrors, models.AudienceImportRowError{Row: row, PhoneNumber: phone, Error: msg})
+		}
+	}
+
+	flush := func() error {
+		if pendingRows == 0 {
+			return nil
+		}
+		next := *job
+		next.ProcessedRows = rowNum
+		next.FailedRows += pendingFailed
+		next.DuplicateRows += pendingDuplicate
+		allErrors := append(rowErrors, pendingErrors...)
+		errBytes, err := json.Marshal(allErrors)
+		if err != nil {
+			return err
+		}
+		next.RowErrors = errBytes
+
+		err = repository.WithTransaction(ctx, f.db, func(txCtx context.Context) error {
+			inserted, existing, err := f.profileRepo.ImportPhoneNumbers(txCtx, entries)
+			if err != nil {
+				return err
+			}
+			next.InsertedRows += inserted
+			next.ExistingRows += existing
+			return f.jobRepo.Update(txCtx, &next)
+		})
+		if err != nil {
+			return err
+		}
+
+		*job = next
+		rowErrors = allErrors
+		entries = entries[:0]
+		pendingErrors = nil
+		pendingRows, pendingFailed, pendingDuplicate = 0, 0, 0
+		return nil
+	}
+
+	for {
+		rec, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		rowNum++
+		resumed := rowNum <= job.ProcessedRows
+		if err != nil {
+			if !resumed {
+				pendingRows++
+				fail(rowNum, "", "malformed CSV row")
+			}
+			continue
+		}
+
+		row, rowErr := parseAudienceImportRecord(rec, cols)
+		if resumed {
+			// Rebuild in-file duplicate detection for rows imported before a restart
+			if rowErr == nil {
+				seen[row.phone] = struct{}{}
+			}
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		pendingRows++
+		if rowErr != nil {
+			raw := ""
+			if cols.phone < len(rec) {
+				raw = strings.TrimSpace(rec[cols.phone])
+			}
+			fail(rowNum, raw, rowErr.Error())
+		} else if _, dup := seen[row.phone]; dup {
+			pendingDuplicate++
+		} else if tags, err := f.resolveImportTags(ctx, tagIDs, row.tagNames); err != nil {
+			if IsAudienceImportUnknownTag(err) {
+				fail(rowNum, row.phone, err.Error())
+			} else {
+				return err
+			}
+		} else {
+			seen[row.phone] = struct{}{}
+			entries = append(entries, repository.AudienceProfileImportEntry{
+				PhoneNumber: row.phone,
+				Aliases:     row.aliases,
+				Tags:        tags,
+			})
+		}
+
+		if pendingRows >= audienceImportChunkSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+
+	job.Status = models.AudienceImportJobStatusCompleted
+	job.CompletedAt = utils.ToPtr(utils.UTCNow())
+	return f.jobRepo.Update(ctx, job)
+}
+
+// resolveImportTags maps tag names to IDs, caching lookups across rows. A
+// name that matches no tag fails the row.
+func (f *AudienceImportFlowImpl) resolveImportTags(ctx context.Context, cache map[string]int32, names []string) ([]int32, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	var missing []string
+	for _, name := range names {
+		if _, ok := cache[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		tags, err := f.tagRepo.ListByNames(ctx, missing)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range missing {
+			cache[name] = 0
+		}
+		for _, t := range tags {
+			cache[t.Name] = int32(t.ID)
+		}
+	}
+
+	ids := make([]int32, 0, len(names))
+	for _, name := range names {
+		id := cache[name]
+		if id == 0 {
+			return nil, fmt.Errorf("%w: %s", ErrAudienceImportUnknownTag, name)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func toAudienceImportJobItem(job *models.AudienceImportJob) dto.AudienceImportJobItem {
+	item := dto.AudienceImportJobItem{
+		UUID:          job.UUID.String(),
+		FileName:      job.FileName,
+		Status:        string(job.Status),
+		TotalRows:     job.TotalRows,
+		ProcessedRows: job.ProcessedRows,
+		InsertedRows:  job.InsertedRows,
+		ExistingRows:  job.ExistingRows,
+		DuplicateRows: job.DuplicateRows,
+		FailedRows:    job.FailedRows,
+		RowErrors:     []dto.AudienceImportRowError{},
+		ErrorMessage:  job.ErrorMessage,
+		CreatedAt:     job.CreatedAt.Format(time.RFC3339),
+	}
+	if job.TotalRows > 0 {
+		item.Progress = float64(job.ProcessedRows) * 100 / float64(job.TotalRows)
+	}
+	if job.Status == models.AudienceImportJobStatusCompleted {
+		item.Progress = 100
+	}
+	if job.StartedAt != nil {
+		item.StartedAt = utils.ToPtr(job.StartedAt.Format(time.RFC3339))
+	}
+	if job.CompletedAt != nil {
+		item.CompletedAt = utils.ToPtr(job.CompletedAt.Format(time.RFC3339))
+	}
+
+	var rowErrors []models.AudienceImportRowError
+	if len(job.RowErrors) > 0 && json.Unmarshal(job.RowErrors, &rowErrors) == nil {
+		for _, e := range rowErrors {
+			item.RowErrors = append(item.RowErrors, dto.AudienceImportRowError{
+				Row:         e.Row,
+				PhoneNumber: e.PhoneNumber,
+				Error:       e.Error,
+			})
+		}
+	}
+	return item
+}
@@ -0,0 +1,64 @@
+package businessflow
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCountAudienceImportRows(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		csv     string
+		want    int64
+		wantErr error
+	}{
+		{name: "rows", csv: "phone_number,tags\n09121234567,a;b\n09121234568,\n", want: 2},
+		{name: "bom and case", csv: "\ufeffPhone_Number\n09121234567\n", want: 1},
+		{name: "ragged rows", csv: "tags,phone_number\na\n,09121234567\n", want: 2},
+		{name: "empty file", csv: "", wantErr: ErrAudienceImportFileEmpty},
+		{name: "header only", csv: "phone_number\n", wantErr: ErrAudienceImportFileEmpty},
+		{name: "missing phone column", csv: "mobile\n09121234567\n", wantErr: ErrAudienceImportPhoneColumnMissing},
+		{name: "bad quoting", csv: "phone_number\n\"0912\"x\n", wantErr: ErrAudienceImportInvalidCSV},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := countAudienceImportRows(strings.NewReader(tt.csv))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if got != tt.want {
+				t.Fatalf("expected %d rows, got %d", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParseAudienceImportRecord(t *testing.T) {
+	t.Parallel()
+
+	cols := audienceImportColumns{phone: 0, tags: 1}
+	row, err := parseAudienceImportRecord([]string{"09121234567", " vip ; ; new;vip"}, cols)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if row.phone != "+989121234567" {
+		t.Fatalf("unexpected phone %q", row.phone)
+	}
+	if len(row.aliases) != 3 || row.aliases[0] != "989121234567" {
+		t.Fatalf("unexpected aliases %v", row.aliases)
+	}
+	if strings.Join(row.tagNames, ",") != "vip,new" {
+		t.Fatalf("unexpected tags %v", row.tagNames)
+	}
+
+	if _, err := parseAudienceImportRecord([]string{""}, cols); err == nil {
+		t.Fatal("expected empty phone to be rejected")
+	}
+	if _, err := parseAudienceImportRecord([]string{"02112345678"}, cols); err == nil {
+		t.Fatal("expected landline to be rejected")
+	}
+}
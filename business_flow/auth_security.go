@@ -10,11 +10,11 @@ import (
 	"unicode"
 
 	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/utils/phonenumber"
 )
 
 const (
 	authOTPMaxAttempts     = 5
-	authOTPResendCooldown  = 30 * time.Second
 	authLoginMaxFailures   = 5
 	authLoginFailureWindow = 15 * time.Minute
 )
@@ -45,7 +45,7 @@ func normalizeLoginIdentifier(identifier string) string {
 	if strings.Contains(identifier, "@") {
 		return normalizeEmailIdentifier(identifier)
 	}
-	return identifier
+	return phonenumber.CanonicalOrRaw(identifier)
 }
 
 func isSixDigitCode(code string) bool {
@@ -73,6 +73,8 @@ func TestNormalizeLoginIdentifier(t *testing.T) {
 		{"  user@example.com  ", "user@example.com"},
 		{"+989123456789", "+989123456789"},
 		{"  +989123456789  ", "+989123456789"},
+		{"09123456789", "+989123456789"},
+		{"989123456789", "+989123456789"},
 		{"plaintext", "plaintext"},
 	}
 
@@ -0,0 +1,307 @@
+package businessflow
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/repository"
+	"github.com/amirphl/Yamata-no-Orochi/utils/phonenumber"
+)
+
+const (
+	maxBlacklistRows      = 1_000_000
+	maxBlacklistRowErrors = 1000
+	blacklistChunkSize    = 1000
+
+	blacklistPhoneColumn  = "phone_number"
+	blacklistReasonColumn = "reason"
+)
+
+// BlacklistFlow manages the global blacklist of prohibited recipients, such
+// as regulator-provided numbers and numbers that complained. Blacklisted
+// numbers are never selected into campaign batches.
+//
+// Uploads are CSV files with a phone_number column and an optional reason
+// column. Numbers are stored in canonical E.164 form; numbers that are
+// already blacklisted keep their original source and reason.
+type BlacklistFlow interface {
+	Upload(ctx context.Context, csvReader io.Reader, source string, reason *string) (*dto.BlacklistUploadResponse, error)
+	List(ctx context.Context, filter dto.AdminListBlacklistFilter) (*dto.AdminListBlacklistResponse, error)
+	Delete(ctx context.Context, phoneNumber string) (*dto.AdminDeleteBlacklistedNumberResponse, error)
+}
+
+type BlacklistFlowImpl struct {
+	blacklistRepo repository.BlacklistedNumberRepository
+	auditRepo     repository.AuditLogRepository
+}
+
+func NewBlacklistFlow(blacklistRepo repository.BlacklistedNumberRepository, auditRepo repository.AuditLogRepository) BlacklistFlow {
+	return &BlacklistFlowImpl{
+		blacklistRepo: blacklistRepo,
+		auditRepo:     auditRepo,
+	}
+}
+
+// Upload validates the CSV and blacklists every valid number in it. Rows with
+// an invalid number are reported and skipped; the rest of the file is still
+// imported.
+func (f *BlacklistFlowImpl) Upload(ctx context.Context, csvReader io.Reader, source string, reason *string) (*dto.BlacklistUploadResponse, error) {
+	if csvReader == nil {
+		return nil, NewBusinessError("VALIDATION_ERROR", "CSV file is required", nil)
+	}
+	src := models.BlacklistSource(strings.TrimSpace(source))
+	if src == "" {
+		src = models.BlacklistSourceAdmin
+	}
+	if !src.Valid() {
+		return nil, NewBusinessError("BLACKLIST_SOURCE_INVALID", "Blacklist source is invalid", ErrBlacklistSourceInvalid)
+	}
+	if reason != nil {
+		trimmed := strings.TrimSpace(*reason)
+		reason = &trimmed
+		if trimmed == "" {
+			reason = nil
+		}
+	}
+
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, csvReader); err != nil {
+		return nil, NewBusinessError("CSV_READ_ERROR", "Failed to read CSV", err)
+	}
+	rows, rowErrors, err := parseBlacklistCSV(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		return nil, NewBusinessError("BLACKLIST_FILE_INVALID", err.Error(), err)
+	}
+
+	res := &dto.BlacklistUploadResponse{
+		Message:   "Blacklist uploaded successfully",
+		Source:    string(src),
+		RowErrors: make([]dto.BlacklistRowError, 0),
+	}
+	res.TotalRows = int64(len(rows)) + int64(len(rowErrors))
+	for _, re := range rowErrors {
+		res.FailedRows++
+		if len(res.RowErrors) < maxBlacklistRowErrors {
+			res.RowErrors = append(res.RowErrors, re)
+		}
+	}
+
+	var adminID *uint
+	if id, ok := adminIDFromContext(ctx); ok {
+		adminID = &id
+	}
+	seen := make(map[string]struct{}, len(rows))
+	entries := make([]*models.BlacklistedNumber, 0, len(rows))
+	for _, row := range rows {
+		if _, dup := seen[row.phone]; dup {
+			res.DuplicateRows++
+			continue
+		}
+		seen[row.phone] = struct{}{}
+		entryReason := reason
+		if row.reason != nil {
+			entryReason = row.reason
+		}
+		entries = append(entries, &models.BlacklistedNumber{
+			PhoneNumber: row.phone,
+			Source:      src,
+			Reason:      entryReason,
+			AdminID:     adminID,
+		})
+	}
+
+	meta := map[string]any{"source": string(src), "total_rows": res.TotalRows}
+	for start := 0; start < len(entries); start += blacklistChunkSize {
+		end := min(start+blacklistChunkSize, len(entries))
+		inserted, err := f.blacklistRepo.InsertMissing(ctx, entries[start:end])
+		if err != nil {
+			meta["inserted_rows"] = res.InsertedRows
+			logAdminAction(ctx, f.auditRepo, models.AuditActionAdminBlacklistUpload, "Blacklist upload failed", false, nil, meta, err)
+			return nil, NewBusinessError("BLACKLIST_UPLOAD_FAILED", "Failed to store blacklisted numbers", err)
+		}
+		res.InsertedRows += inserted
+		res.ExistingRows += int64(end-start) - inserted
+	}
+
+	meta["inserted_rows"] = res.InsertedRows
+	meta["existing_rows"] = res.ExistingRows
+	meta["failed_rows"] = res.FailedRows
+	logAdminAction(ctx, f.auditRepo, models.AuditActionAdminBlacklistUpload, "Blacklist uploaded", true, nil, meta, nil)
+	return res, nil
+}
+
+// List returns blacklisted numbers, newest first
+func (f *BlacklistFlowImpl) List(ctx context.Context, filter dto.AdminListBlacklistFilter) (*dto.AdminListBlacklistResponse, error) {
+	page := max(1, filter.Page)
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 10
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	offset := (page - 1) * limit
+
+	bf := models.BlacklistedNumberFilter{}
+	if filter.PhoneNumber != nil && strings.TrimSpace(*filter.PhoneNumber) != "" {
+		phone := strings.TrimSpace(*filter.PhoneNumber)
+		bf.PhoneNumber = &phone
+	}
+	if filter.Source != nil && *filter.Source != "" {
+		src := models.BlacklistSource(*filter.Source)
+		if !src.Valid() {
+			return nil, NewBusinessError("BLACKLIST_SOURCE_INVALID", "Blacklist source is invalid", ErrBlacklistSourceInvalid)
+		}
+		bf.Source = &src
+	}
+
+	total, err := f.blacklistRepo.Count(ctx, bf)
+	if err != nil {
+		return nil, NewBusinessError("BLACKLIST_LIST_FAILED", "Failed to count blacklisted numbers", err)
+	}
+	rows, err := f.blacklistRepo.ByFilter(ctx, bf, "id DESC", limit, offset)
+	if err != nil {
+		return nil, NewBusinessError("BLACKLIST_LIST_FAILED", "Failed to list blacklisted numbers", err)
+	}
+
+	items := make([]dto.BlacklistedNumberItem, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, dto.BlacklistedNumberItem{
+			ID:          row.ID,
+			PhoneNumber: row.PhoneNumber,
+			Source:      string(row.Source),
+			Reason:      row.Reason,
+			AdminID:     row.AdminID,
+			CreatedAt:   row.CreatedAt.Format(time.RFC3339),
+		})
+	}
+
+	return &dto.AdminListBlacklistResponse{
+		Message: "Blacklisted numbers retrieved successfully",
+		Items:   items,
+		Pagination: dto.PaginationInfo{
+			Total:      total,
+			Page:       page,
+			Limit:      limit,
+			TotalPages: int((total + int64(limit) - 1) / int64(limit)),
+		},
+	}, nil
+}
+
+// Delete removes a number from the blacklist so campaigns may reach it again
+func (f *BlacklistFlowImpl) Delete(ctx context.Context, phoneNumber string) (*dto.AdminDeleteBlacklistedNumberResponse, error) {
+	canonical, err := phonenumber.Canonical(phoneNumber)
+	if err != nil {
+		return nil, NewBusinessError("BLACKLIST_PHONE_INVALID", "Phone number is not a valid Iranian mobile number", ErrBlacklistPhoneInvalid)
+	}
+
+	meta := map[string]any{"phone_number": canonical}
+	deleted, err := f.blacklistRepo.DeleteByPhoneNumber(ctx, canonical)
+	if err != nil {
+		logAdminAction(ctx, f.auditRepo, models.AuditActionAdminBlacklistDelete, "Blacklist delete failed", false, nil, meta, err)
+		return nil, NewBusinessError("BLACKLIST_DELETE_FAILED", "Failed to remove blacklisted number", err)
+	}
+	if !deleted {
+		return nil, NewBusinessError("BLACKLISTED_NUMBER_NOT_FOUND", "Number is not blacklisted", ErrBlacklistedNumberNotFound)
+	}
+	logAdminAction(ctx, f.auditRepo, models.AuditActionAdminBlacklistDelete, "Number removed from blacklist", true, nil, meta, nil)
+
+	return &dto.AdminDeleteBlacklistedNumberResponse{
+		Message:     "Number removed from blacklist",
+		PhoneNumber: canonical,
+	}, nil
+}
+
+// blacklistRow is a parsed CSV data row
+type blacklistRow struct {
+	phone  string
+	reason *string
+}
+
+// parseBlacklistCSV reads the whole upload. Rows with an invalid number are
+// returned as row errors; a missing header or malformed CSV fails the upload.
+func parseBlacklistCSV(r io.Reader) ([]blacklistRow, []dto.BlacklistRowError, error) {
+	reader := csv.NewReader(bufio.NewReader(r))
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil, ErrBlacklistFileEmpty
+	}
+	if err != nil {
+		return nil, nil, ErrBlacklistInvalidCSV
+	}
+	phoneCol, reasonCol := -1, -1
+	for i, h := range header {
+		switch strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff"))) {
+		case blacklistPhoneColumn:
+			phoneCol = i
+		case blacklistReasonColumn:
+			reasonCol = i
+		}
+	}
+	if phoneCol < 0 {
+		return nil, nil, ErrBlacklistPhoneColumnMissing
+	}
+
+	var (
+		rows      []blacklistRow
+		rowErrors []dto.BlacklistRowError
+		rowNum    int64
+	)
+	for {
+		rec, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: %v", ErrBlacklistInvalidCSV, err)
+		}
+		rowNum++
+		if rowNum > maxBlacklistRows {
+			return nil, nil, ErrBlacklistTooManyRows
+		}
+
+		raw := ""
+		if phoneCol < len(rec) {
+			raw = strings.TrimSpace(rec[phoneCol])
+		}
+		canonical, err := parseBlacklistPhone(raw)
+		if err != nil {
+			rowErrors = append(rowErrors, dto.BlacklistRowError{Row: rowNum, PhoneNumber: raw, Error: err.Error()})
+			continue
+		}
+		row := blacklistRow{phone: canonical}
+		if reasonCol >= 0 && reasonCol < len(rec) {
+			if reason := strings.TrimSpace(rec[reasonCol]); reason != "" {
+				row.reason = &reason
+			}
+		}
+		rows = append(rows, row)
+	}
+	if rowNum == 0 {
+		return nil, nil, ErrBlacklistFileEmpty
+	}
+	return rows, rowErrors, nil
+}
+
+func parseBlacklistPhone(raw string) (string, error) {
+	if raw == "" {
+		return "", errors.New("phone number is empty")
+	}
+	canonical, err := phonenumber.Canonical(raw)
+	if err != nil {
+		return "", errors.New("phone number is not a valid Iranian mobile number")
+	}
+	return canonical, nil
+}
@@ -0,0 +1,60 @@
+package businessflow
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseBlacklistCSV(t *testing.T) {
+	t.Parallel()
+
+	csvData := "\ufeffReason,Phone_Number\n" +
+		"spam complaint,09121234567\n" +
+		",+98 912 765 4321\n" +
+		"landline,02112345678\n" +
+		"missing,\n"
+	rows, rowErrors, err := parseBlacklistCSV(strings.NewReader(csvData))
+	if err != nil {
+		t.Fatalf("parseBlacklistCSV: %v", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("expected 2 rows, got %d", len(rows))
+	}
+	if rows[0].phone != "+989121234567" || rows[0].reason == nil || *rows[0].reason != "spam complaint" {
+		t.Fatalf("unexpected first row: %+v", rows[0])
+	}
+	if rows[1].phone != "+989127654321" || rows[1].reason != nil {
+		t.Fatalf("unexpected second row: %+v", rows[1])
+	}
+	if len(rowErrors) != 2 || rowErrors[0].Row != 3 || rowErrors[1].Row != 4 {
+		t.Fatalf("unexpected row errors: %+v", rowErrors)
+	}
+}
+
+func TestParseBlacklistCSVRejectsInvalidFiles(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name string
+		data string
+		want error
+	}{
+		{name: "empty", data: "", want: ErrBlacklistFileEmpty},
+		{name: "header only", data: "phone_number\n", want: ErrBlacklistFileEmpty},
+		{name: "missing phone column", data: "mobile\n09121234567\n", want: ErrBlacklistPhoneColumnMissing},
+		{name: "malformed", data: "phone_number\n\"0912\n", want: ErrBlacklistInvalidCSV},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			_, _, err := parseBlacklistCSV(strings.NewReader(tc.data))
+			if !errors.Is(err, tc.want) {
+				t.Fatalf("expected %v, got %v", tc.want, err)
+			}
+			if !IsBlacklistFileInvalid(err) {
+				t.Fatalf("expected file invalid error, got %v", err)
+			}
+		})
+	}
+}
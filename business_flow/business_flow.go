@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 	"unicode"
@@ -111,6 +112,41 @@ func ToCustomerSessionDTO(session models.CustomerSession) dto.CustomerSessionDTO
 	}
 }
 
+// ToActiveSessionDTO describes a session in the device list of its customer
+func ToActiveSessionDTO(session *models.CustomerSession, current bool) dto.ActiveSessionDTO {
+	device := decodeSessionDeviceInfo(session)
+	ipAddress := ""
+	if session.IPAddress != nil {
+		ipAddress = *session.IPAddress
+	}
+	userAgent := ""
+	if session.UserAgent != nil {
+		userAgent = *session.UserAgent
+	}
+	return dto.ActiveSessionDTO{
+		ID: session.ID,
+		Device: dto.SessionDeviceDTO{
+			Platform:   device.Platform,
+			Browser:    device.Browser,
+			Version:    device.Version,
+			OS:         device.OS,
+			DeviceType: device.DeviceType,
+			IsMobile:   device.IsMobile,
+		},
+		IPAddress: ipAddress,
+		UserAgent: userAgent,
+		Location: dto.SessionLocationDTO{
+			Country: device.Country,
+			IPRange: ipRange(ipAddress),
+		},
+		Current:        current,
+		Impersonated:   device.ImpersonatedBy != 0,
+		CreatedAt:      session.CreatedAt.Format(time.RFC3339),
+		LastAccessedAt: session.LastAccessedAt.Format(time.RFC3339),
+		ExpiresAt:      session.ExpiresAt.Format(time.RFC3339),
+	}
+}
+
 func ToAdminDTOModel(a models.Admin) dto.AdminDTO {
 	return dto.AdminDTO{
 		ID:        a.ID,
@@ -152,16 +188,42 @@ func ToBotSessionDTO(accessToken, refreshToken string) dto.BotSessionDTO {
 }
 
 func ToLineNumberDTO(line models.LineNumber) dto.AdminLineNumberDTO {
+	var deletedAt *string
+	if line.DeletedAt.Valid {
+		s := line.DeletedAt.Time.Format(time.RFC3339)
+		deletedAt = &s
+	}
 	return dto.AdminLineNumberDTO{
-		ID:          line.ID,
-		UUID:        line.UUID.String(),
-		Name:        line.Name,
-		LineNumber:  line.LineNumber,
-		PriceFactor: line.PriceFactor,
-		Priority:    line.Priority,
-		IsActive:    line.IsActive,
-		CreatedAt:   line.CreatedAt.Format(time.RFC3339),
-		UpdatedAt:   line.UpdatedAt.Format(time.RFC3339),
+		ID:                 line.ID,
+		UUID:               line.UUID.String(),
+		Name:               line.Name,
+		LineNumber:         line.LineNumber,
+		PriceFactor:        line.PriceFactor,
+		Priority:           line.Priority,
+		Operator:           line.Operator,
+		Tier:               line.Tier,
+		IsActive:           line.IsActive,
+		RatePerSecond:      line.RatePerSecond,
+		Burst:              line.Burst,
+		Pool:               line.Pool,
+		CreatedAt:          line.CreatedAt.Format(time.RFC3339),
+		CertifiedOperators: line.CertifiedOperators,
+		UpdatedAt:          line.UpdatedAt.Format(time.RFC3339),
+		DeletedAt:          deletedAt,
+	}
+}
+
+func ToLineNumberTierDTO(tier models.LineNumberTier) dto.AdminLineNumberTierDTO {
+	return dto.AdminLineNumberTierDTO{
+		ID:                     tier.ID,
+		Name:                   tier.Name,
+		DisplayName:            tier.DisplayName,
+		Description:            tier.Description,
+		ReservationPricePerDay: tier.ReservationPricePerDay,
+		MaxReservationDays:     tier.MaxReservationDays,
+		IsActive:               tier.IsActive,
+		CreatedAt:              tier.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:              tier.UpdatedAt.Format(time.RFC3339),
 	}
 }
 
@@ -278,6 +340,31 @@ func getAgency(ctx context.Context, customerRepo repository.CustomerRepository,
 	return *agency, nil
 }
 
+// agencyDiscountRate returns the rate of ad for a charge the customer makes
+// now: the tier their earlier charges of the Tehran month reach, or its base
+// rate. It also returns those charges.
+func agencyDiscountRate(ctx context.Context, transactionRepo repository.TransactionRepository, ad *models.AgencyDiscount) (float64, uint64, error) {
+	if len(ad.Tiers) == 0 {
+		return ad.DiscountRate, 0, nil
+	}
+	start, end := utils.TehranMonthBounds(utils.UTCNow())
+	monthly, err := transactionRepo.SumCustomerDepositsWithTax(ctx, ad.CustomerID, start, end)
+	if err != nil {
+		return 0, 0, err
+	}
+	return ad.RateFor(monthly), monthly, nil
+}
+
+// chargeDiscountRate returns the discount rate fixed when a charge was
+// requested. Requests made before tiers existed carry none and use the base
+// rate of their discount.
+func chargeDiscountRate(m map[string]any, ad *models.AgencyDiscount) float64 {
+	if rate, ok := m["agency_discount_rate"].(float64); ok {
+		return rate
+	}
+	return ad.DiscountRate
+}
+
 func getCampaign(ctx context.Context, campaignRepo repository.CampaignRepository, campaignUUID string, customerID uint) (models.Campaign, error) {
 	// Get existing campaign
 	campaign, err := campaignRepo.ByUUID(ctx, campaignUUID)
@@ -313,14 +400,21 @@ func getCampaignByID(ctx context.Context, campaignRepo repository.CampaignReposi
 
 // canUpdateCampaign checks if a campaign can be updated based on its current status
 func canUpdateCampaign(status models.CampaignStatus) bool {
-	// Only campaigns with 'initiated' or 'in-progress' status can be updated
+	// Only campaigns with 'initiated', 'in-progress' or 'changes-requested' status can be updated
 	// Campaigns with 'waiting-for-approval', 'approved', 'rejected', 'running', 'executed', 'canceled', or 'cancelled-by-admin' status cannot be updated
-	return status == models.CampaignStatusInitiated || status == models.CampaignStatusInProgress
+	return status == models.CampaignStatusInitiated || status == models.CampaignStatusInProgress ||
+		status == models.CampaignStatusChangesRequested
 }
 
 // canCancelCampaign checks if a campaign can be cancelled based on its current status.
 func canCancelCampaign(status models.CampaignStatus) bool {
-	return status == models.CampaignStatusWaitingForApproval || status == models.CampaignStatusApproved
+	switch status {
+	case models.CampaignStatusWaitingForApproval, models.CampaignStatusChangesRequested,
+		models.CampaignStatusApproved, models.CampaignStatusRunning, models.CampaignStatusPaused:
+		return true
+	default:
+		return false
+	}
 }
 
 func getWallet(ctx context.Context, walletRepo repository.WalletRepository, customerID uint) (models.Wallet, error) {
@@ -359,6 +453,26 @@ func getTaxWallet(ctx context.Context, walletRepo repository.WalletRepository, s
 	return *wallet, nil
 }
 
+// lockWalletBalances takes the balance lock of every wallet a transaction
+// writes snapshots for. Locks are taken in ascending wallet ID order, so two
+// transactions sharing wallets, e.g. the tax and system wallets of concurrent
+// charges, cannot deadlock.
+func lockWalletBalances(ctx context.Context, walletRepo repository.WalletRepository, walletIDs ...uint) error {
+	for _, id := range balanceLockOrder(walletIDs) {
+		if err := walletRepo.LockBalance(ctx, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// balanceLockOrder returns the distinct wallet IDs in ascending order
+func balanceLockOrder(walletIDs []uint) []uint {
+	ids := slices.Clone(walletIDs)
+	slices.Sort(ids)
+	return slices.Compact(ids)
+}
+
 func getLatestBalanceSnapshot(ctx context.Context, walletRepo repository.WalletRepository, walletID uint) (models.BalanceSnapshot, error) {
 	latestBalance, err := walletRepo.GetCurrentBalance(ctx, walletID)
 	if err != nil {
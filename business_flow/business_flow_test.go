@@ -0,0 +1,29 @@
+package businessflow
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestBalanceLockOrder(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name string
+		ids  []uint
+		want []uint
+	}{
+		{name: "customer agency tax system", ids: []uint{42, 7, 2, 1}, want: []uint{1, 2, 7, 42}},
+		{name: "agency is the customer's own wallet", ids: []uint{9, 9, 2, 1}, want: []uint{1, 2, 9}},
+		{name: "single wallet", ids: []uint{5}, want: []uint{5}},
+	}
+	for _, tc := range cases {
+		input := slices.Clone(tc.ids)
+		if got := balanceLockOrder(tc.ids); !slices.Equal(got, tc.want) {
+			t.Fatalf("%s: got %v, want %v", tc.name, got, tc.want)
+		}
+		if !slices.Equal(tc.ids, input) {
+			t.Fatalf("%s: input reordered to %v", tc.name, tc.ids)
+		}
+	}
+}
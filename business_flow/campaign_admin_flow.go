@@ -12,11 +12,13 @@ import (
 	"time"
 
 	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/app/i18n"
 	"github.com/amirphl/Yamata-no-Orochi/app/services"
 	"github.com/amirphl/Yamata-no-Orochi/config"
 	"github.com/amirphl/Yamata-no-Orochi/models"
 	"github.com/amirphl/Yamata-no-Orochi/repository"
 	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/amirphl/Yamata-no-Orochi/utils/phonenumber"
 	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
@@ -28,6 +30,9 @@ type AdminCampaignFlow interface {
 	GetCampaign(ctx context.Context, id uint) (*dto.AdminGetCampaignResponse, error)
 	ApproveCampaign(ctx context.Context, req *dto.AdminApproveCampaignRequest) (*dto.AdminApproveCampaignResponse, error)
 	RejectCampaign(ctx context.Context, req *dto.AdminRejectCampaignRequest) (*dto.AdminRejectCampaignResponse, error)
+	RequestCampaignChanges(ctx context.Context, req *dto.AdminRequestCampaignChangesRequest) (*dto.AdminRequestCampaignChangesResponse, error)
+	ListCampaignReviews(ctx context.Context, campaignID uint) (*dto.ListCampaignReviewsResponse, error)
+	AddCampaignReviewComment(ctx context.Context, req *dto.AdminAddCampaignReviewCommentRequest) (*dto.AddCampaignReviewCommentResponse, error)
 	CancelCampaign(ctx context.Context, req *dto.AdminCancelCampaignRequest) (*dto.AdminCancelCampaignResponse, error)
 	RemoveAudienceSpec(ctx context.Context, platform *string) (*dto.AdminRemoveAudienceSpecResponse, error)
 	RescheduleCampaign(ctx context.Context, req *dto.AdminRescheduleCampaignRequest) (*dto.AdminRescheduleCampaignResponse, error)
@@ -37,23 +42,27 @@ type AdminCampaignFlow interface {
 
 // AdminCampaignFlowImpl implements the campaign business flow
 type AdminCampaignFlowImpl struct {
-	campaignRepo         repository.CampaignRepository
-	customerRepo         repository.CustomerRepository
-	walletRepo           repository.WalletRepository
-	balanceSnapshotRepo  repository.BalanceSnapshotRepository
-	transactionRepo      repository.TransactionRepository
-	auditRepo            repository.AuditLogRepository
-	platformSettingsRepo repository.PlatformSettingsRepository
-	platformBaseRepo     repository.PlatformBasePriceRepository
-	lineNumberRepo       repository.LineNumberRepository
-	segmentPriceRepo     repository.SegmentPriceFactorRepository
-	pagePriceRepo        repository.PagePriceRepository
-	notifier             services.NotificationService
-	adminConfig          config.AdminConfig
-	messageConfig        config.MessageConfig
-	cacheConfig          config.CacheConfig
-	rc                   *redis.Client
-	db                   *gorm.DB
+	campaignRepo          repository.CampaignRepository
+	customerRepo          repository.CustomerRepository
+	walletRepo            repository.WalletRepository
+	balanceSnapshotRepo   repository.BalanceSnapshotRepository
+	transactionRepo       repository.TransactionRepository
+	auditRepo             repository.AuditLogRepository
+	platformSettingsRepo  repository.PlatformSettingsRepository
+	platformBaseRepo      repository.PlatformBasePriceRepository
+	lineNumberRepo        repository.LineNumberRepository
+	segmentPriceRepo      repository.SegmentPriceFactorRepository
+	pagePriceRepo         repository.PagePriceRepository
+	processedCampaignRepo repository.ProcessedCampaignRepository
+	campaignReviewRepo    repository.CampaignReviewRepository
+	notificationRepo      repository.NotificationRepository
+	pushDeviceRepo        repository.PushDeviceRepository
+	notifier              services.NotificationService
+	adminConfig           config.AdminConfig
+	localizer             *i18n.Localizer
+	cacheConfig           config.CacheConfig
+	rc                    *redis.Client
+	db                    *gorm.DB
 }
 
 const (
@@ -76,31 +85,39 @@ func NewAdminCampaignFlow(
 	lineNumberRepo repository.LineNumberRepository,
 	segmentPriceRepo repository.SegmentPriceFactorRepository,
 	pagePriceRepo repository.PagePriceRepository,
+	processedCampaignRepo repository.ProcessedCampaignRepository,
+	campaignReviewRepo repository.CampaignReviewRepository,
+	notificationRepo repository.NotificationRepository,
+	pushDeviceRepo repository.PushDeviceRepository,
 	db *gorm.DB,
 	rc *redis.Client,
 	notifier services.NotificationService,
 	adminConfig config.AdminConfig,
-	messageConfig config.MessageConfig,
+	localizer *i18n.Localizer,
 	cacheConfig config.CacheConfig,
 ) AdminCampaignFlow {
 	return &AdminCampaignFlowImpl{
-		campaignRepo:         campaignRepo,
-		customerRepo:         customerRepo,
-		walletRepo:           walletRepo,
-		balanceSnapshotRepo:  balanceSnapshotRepo,
-		transactionRepo:      transactionRepo,
-		auditRepo:            auditRepo,
-		platformSettingsRepo: platformSettingsRepo,
-		platformBaseRepo:     platformBaseRepo,
-		lineNumberRepo:       lineNumberRepo,
-		segmentPriceRepo:     segmentPriceRepo,
-		pagePriceRepo:        pagePriceRepo,
-		notifier:             notifier,
-		adminConfig:          adminConfig,
-		messageConfig:        messageConfig,
-		cacheConfig:          cacheConfig,
-		rc:                   rc,
-		db:                   db,
+		campaignRepo:          campaignRepo,
+		customerRepo:          customerRepo,
+		walletRepo:            walletRepo,
+		balanceSnapshotRepo:   balanceSnapshotRepo,
+		transactionRepo:       transactionRepo,
+		auditRepo:             auditRepo,
+		platformSettingsRepo:  platformSettingsRepo,
+		platformBaseRepo:      platformBaseRepo,
+		lineNumberRepo:        lineNumberRepo,
+		segmentPriceRepo:      segmentPriceRepo,
+		pagePriceRepo:         pagePriceRepo,
+		processedCampaignRepo: processedCampaignRepo,
+		campaignReviewRepo:    campaignReviewRepo,
+		notificationRepo:      notificationRepo,
+		pushDeviceRepo:        pushDeviceRepo,
+		notifier:              notifier,
+		adminConfig:           adminConfig,
+		localizer:             localizer,
+		cacheConfig:           cacheConfig,
+		rc:                    rc,
+		db:                    db,
 	}
 }
 
@@ -116,7 +133,11 @@ func (s *AdminCampaignFlowImpl) ListCampaigns(ctx context.Context, filter dto.Ad
 	}
 	offset := (page - 1) * limit
 
-	cf := models.CampaignFilter{}
+	list, err := models.CampaignListFields.Parse(filter.Filter, filter.Sort)
+	if err != nil {
+		return nil, NewBusinessError("LIST_QUERY_INVALID", "Invalid filter or sort", err)
+	}
+	cf := models.CampaignFilter{IncludeDeleted: filter.IncludeDeleted, List: list}
 	if filter.CampaignTitle != nil && *filter.CampaignTitle != "" {
 		cf.CampaignTitle = filter.CampaignTitle
 	}
@@ -209,10 +230,15 @@ func (s *AdminCampaignFlowImpl) ListCampaigns(ctx context.Context, filter dto.Ad
 			return nil, NewBusinessError("ADMIN_LIST_CAMPAIGNS_FAILED", "Failed to fetch platform base price", err)
 		}
 
+		var deletedAt *time.Time
+		if c.DeletedAt.Valid {
+			deletedAt = &c.DeletedAt.Time
+		}
 		items = append(items, dto.AdminGetCampaignResponse{
 			ID:                 c.ID,
 			UUID:               c.UUID.String(),
 			Hidden:             c.Hidden,
+			IsSandbox:          c.IsSandbox,
 			Status:             c.Status.String(),
 			CreatedAt:          c.CreatedAt,
 			UpdatedAt:          c.UpdatedAt,
@@ -250,6 +276,8 @@ func (s *AdminCampaignFlowImpl) ListCampaigns(ctx context.Context, filter dto.Ad
 			AudienceGrades: campaignAudienceGradesOrDefault(c.Spec.AudienceGrades),
 
 			TargetAudienceExcelFileUUID: c.Spec.TargetAudienceExcelFileUUID,
+
+			DeletedAt: deletedAt,
 		})
 	}
 	totalPages := int((total64 + int64(limit) - 1) / int64(limit))
@@ -340,11 +368,20 @@ func (s *AdminCampaignFlowImpl) GetCampaign(ctx context.Context, id uint) (*dto.
 	if c.Bundle != nil {
 		bundleTitle = &c.Bundle.Title
 	}
+	var blacklistedExcluded *int64
+	pc, err := s.processedCampaignRepo.ByCampaignID(ctx, c.ID)
+	if err != nil {
+		return nil, NewBusinessError("ADMIN_GET_CAMPAIGN_FAILED", "Failed to get processed campaign", err)
+	}
+	if pc != nil {
+		blacklistedExcluded = &pc.BlacklistedExcluded
+	}
 
 	resp := &dto.AdminGetCampaignResponse{
 		ID:                    c.ID,
 		UUID:                  c.UUID.String(),
 		Hidden:                c.Hidden,
+		IsSandbox:             c.IsSandbox,
 		Status:                c.Status.String(),
 		CreatedAt:             c.CreatedAt,
 		UpdatedAt:             c.UpdatedAt,
@@ -384,6 +421,7 @@ func (s *AdminCampaignFlowImpl) GetCampaign(ctx context.Context, id uint) (*dto.
 		AudienceGrades: campaignAudienceGradesOrDefault(c.Spec.AudienceGrades),
 
 		TargetAudienceExcelFileUUID: c.Spec.TargetAudienceExcelFileUUID,
+		BlacklistedExcluded:         blacklistedExcluded,
 	}
 	logAdminAction(ctx, s.auditRepo, models.AuditActionAdminCampaignGet, "Admin fetched campaign", true, &c.CustomerID, map[string]any{
 		"campaign_id": c.ID,
@@ -493,7 +531,7 @@ func (s *AdminCampaignFlowImpl) ApproveCampaign(ctx context.Context, req *dto.Ad
 	var campaign *models.Campaign
 	var customer models.Customer
 
-	err := repository.WithTransaction(ctx, s.db, func(txCtx context.Context) error {
+	err := withVersionRetry(ctx, s.db, func(txCtx context.Context) error {
 		var err error
 		campaign, err = s.campaignRepo.ByID(txCtx, req.CampaignID)
 		if err != nil {
@@ -581,6 +619,9 @@ func (s *AdminCampaignFlowImpl) ApproveCampaign(ctx context.Context, req *dto.Ad
 			Description:        fmt.Sprintf("Budget spent on approved campaign %d", campaign.ID),
 			Metadata:           metaBytes,
 		}
+		if err := s.walletRepo.AdvanceVersion(txCtx, &wallet); err != nil {
+			return err
+		}
 		if err := s.balanceSnapshotRepo.Save(txCtx, newSnap); err != nil {
 			return err
 		}
@@ -612,12 +653,27 @@ func (s *AdminCampaignFlowImpl) ApproveCampaign(ctx context.Context, req *dto.Ad
 			return err
 		}
 
+		// Approving a recurring campaign starts its series; the parent is
+		// the first occurrence.
+		if campaign.Spec.Recurrence != nil && campaign.ParentCampaignID == nil {
+			campaign.OccurrenceNumber = utils.ToPtr(uint(1))
+			campaign.NextOccurrenceAt, err = nextCampaignOccurrence(campaign, *campaign.Spec.ScheduleAt, 1)
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := s.saveCampaignReview(txCtx, campaign.ID, models.CampaignReviewActionApproved, req.Comment); err != nil {
+			return err
+		}
+
 		campaign.Status = models.CampaignStatusApproved
 		campaign.Comment = req.Comment
 		campaign.UpdatedAt = utils.ToPtr(utils.UTCNow())
 		if err := s.campaignRepo.Update(txCtx, *campaign); err != nil {
 			return err
 		}
+		notifyInbox(txCtx, s.notificationRepo, customer.ID, models.NotificationKindCampaignApproved, i18n.Args{"Title": campaignDisplayTitle(campaign)})
 		return nil
 	})
 	if err != nil {
@@ -633,12 +689,11 @@ func (s *AdminCampaignFlowImpl) ApproveCampaign(ctx context.Context, req *dto.Ad
 		if campaign.Spec.Title != nil && *campaign.Spec.Title != "" {
 			title = *campaign.Spec.Title
 		}
-		customerMobile := normalizeIranMobile(customer.RepresentativeMobile)
-		msgCustomer := fmt.Sprintf("Your campaign '%s' has been approved.", title)
-		id64 := int64(customer.ID)
+		msgCustomer := s.localizer.Customer(&customer, "campaign.approved", i18n.Args{"Title": title})
+		pushTitle := s.localizer.Customer(&customer, "notification.campaign_approved.title", nil)
 		smsCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
-		_ = s.notifier.SendSMS(smsCtx, customerMobile, msgCustomer, &id64)
+		_ = sendCustomerNotice(smsCtx, s.notifier, s.pushDeviceRepo, &customer, pushTitle, msgCustomer)
 	}
 
 	logAdminAction(ctx, s.auditRepo, models.AuditActionAdminCampaignApproved, "Admin approved campaign", true, &customer.ID, map[string]any{
@@ -792,8 +847,9 @@ func (s *AdminCampaignFlowImpl) RejectCampaign(ctx context.Context, req *dto.Adm
 
 	var campaign *models.Campaign
 	var customer models.Customer
+	var refunded uint64
 
-	err := repository.WithTransaction(ctx, s.db, func(txCtx context.Context) error {
+	err := withVersionRetry(ctx, s.db, func(txCtx context.Context) error {
 		var err error
 		campaign, err = s.campaignRepo.ByID(txCtx, req.CampaignID)
 		if err != nil {
@@ -811,105 +867,26 @@ func (s *AdminCampaignFlowImpl) RejectCampaign(ctx context.Context, req *dto.Adm
 			return err
 		}
 
-		// Find the frozen reservation transaction created during finalize
-		freezeTxs, err := s.transactionRepo.ByFilter(txCtx, models.TransactionFilter{
-			CustomerID: &campaign.CustomerID,
-			CampaignID: &campaign.ID,
-			Source:     utils.ToPtr("campaign_update"),
-			Operation:  utils.ToPtr("reserve_budget"),
-			Type:       utils.ToPtr(models.TransactionTypeFreeze),
-			Status:     utils.ToPtr(models.TransactionStatusCompleted),
-		}, "id DESC", 0, 0)
-		if err != nil {
-			return err
-		}
-		if len(freezeTxs) == 0 {
-			return ErrFreezeTransactionNotFound
-		}
-		if len(freezeTxs) > 1 {
-			return ErrMultipleFreezeTransactionsFound
-		}
-		freezeTx := freezeTxs[0]
-
-		wallet, err := getWallet(txCtx, s.walletRepo, campaign.CustomerID)
-		if err != nil {
-			return err
-		}
-		latestBalance, err := getLatestBalanceSnapshot(txCtx, s.walletRepo, wallet.ID)
-		if err != nil {
-			return err
-		}
-
-		amount := freezeTx.Amount
-		if latestBalance.FrozenBalance < amount {
-			return ErrInsufficientFunds
-		}
-
 		meta := map[string]any{
 			"source":      "admin_campaign_reject",
 			"operation":   "reject_campaign_refund_frozen",
 			"campaign_id": campaign.ID,
 			"comment":     req.Comment,
 		}
-		metaBytes, _ := json.Marshal(meta)
-
-		// TODO:
-		// // Restore free/credit split exactly as it was taken during the freeze.
-		// newFrozen := latestBalance.FrozenBalance - amount
-		// freeRefund, creditRefund := computeFreezeRefundSplit(freezeTx, amount)
-		// newFree := latestBalance.FreeBalance + freeRefund
-		// newCredit := latestBalance.CreditBalance + creditRefund
-
-		// Move from frozen back to free
-		newFrozen := latestBalance.FrozenBalance - amount
-		newCredit := latestBalance.CreditBalance + amount
-
-		newSnap := &models.BalanceSnapshot{
-			UUID:          uuid.New(),
-			CorrelationID: freezeTx.CorrelationID,
-			WalletID:      wallet.ID,
-			CustomerID:    customer.ID,
-			// FreeBalance:        newFree,
-			FreeBalance:        latestBalance.FreeBalance,
-			FrozenBalance:      newFrozen,
-			LockedBalance:      latestBalance.LockedBalance,
-			CreditBalance:      newCredit,
-			SpentOnCampaign:    latestBalance.SpentOnCampaign,
-			AgencyShareWithTax: latestBalance.AgencyShareWithTax,
-			// TotalBalance:       newFree + newFrozen + latestBalance.LockedBalance + newCredit + latestBalance.SpentOnCampaign + latestBalance.AgencyShareWithTax,
-			TotalBalance: latestBalance.FreeBalance + newFrozen + latestBalance.LockedBalance + newCredit + latestBalance.SpentOnCampaign + latestBalance.AgencyShareWithTax,
-			Reason:       "campaign_rejected_budget_refund",
-			Description:  fmt.Sprintf("Refund reserved budget for rejected campaign %d", campaign.ID),
-			Metadata:     metaBytes,
-		}
-		if err := s.balanceSnapshotRepo.Save(txCtx, newSnap); err != nil {
-			return err
-		}
-
-		beforeMap, err := latestBalance.GetBalanceMap()
-		if err != nil {
-			return err
-		}
-		afterMap, err := newSnap.GetBalanceMap()
+		freezeTx, err := s.refundCampaignReservation(
+			txCtx,
+			campaign,
+			customer,
+			meta,
+			"campaign_rejected_budget_refund",
+			fmt.Sprintf("Refund reserved budget for rejected campaign %d", campaign.ID),
+		)
 		if err != nil {
 			return err
 		}
+		refunded = freezeTx.Amount
 
-		refundTx := &models.Transaction{
-			UUID:          uuid.New(),
-			CorrelationID: freezeTx.CorrelationID,
-			Type:          models.TransactionTypeRefund,
-			Status:        models.TransactionStatusCompleted,
-			Amount:        amount,
-			Currency:      utils.TomanCurrency,
-			WalletID:      wallet.ID,
-			CustomerID:    customer.ID,
-			BalanceBefore: beforeMap,
-			BalanceAfter:  afterMap,
-			Description:   fmt.Sprintf("Refund reserved budget for rejected campaign %d", campaign.ID),
-			Metadata:      metaBytes,
-		}
-		if err := s.transactionRepo.Save(txCtx, refundTx); err != nil {
+		if err := s.saveCampaignReview(txCtx, campaign.ID, models.CampaignReviewActionRejected, &req.Comment); err != nil {
 			return err
 		}
 
@@ -919,6 +896,10 @@ func (s *AdminCampaignFlowImpl) RejectCampaign(ctx context.Context, req *dto.Adm
 		if err := s.campaignRepo.Update(txCtx, *campaign); err != nil {
 			return err
 		}
+		notifyInbox(txCtx, s.notificationRepo, customer.ID, models.NotificationKindCampaignRejected, i18n.Args{
+			"Title":   campaignDisplayTitle(campaign),
+			"Comment": req.Comment,
+		})
 		return nil
 	})
 	if err != nil {
@@ -935,19 +916,12 @@ func (s *AdminCampaignFlowImpl) RejectCampaign(ctx context.Context, req *dto.Adm
 		if campaign.Spec.Title != nil && *campaign.Spec.Title != "" {
 			title = *campaign.Spec.Title
 		}
-		customerMobile := normalizeIranMobile(customer.RepresentativeMobile)
-		msgCustomer := strings.TrimSpace(s.messageConfig.CampaignRejectedTemplate)
-		if msgCustomer == "" {
-			msgCustomer = "Your campaign '%s' has been rejected."
-		}
-		if strings.Contains(msgCustomer, "%") {
-			msgCustomer = fmt.Sprintf(msgCustomer, title)
-		}
-		id64 := int64(customer.ID)
+		msgCustomer := s.localizer.Customer(&customer, "campaign.rejected", i18n.Args{"Title": title, "Amount": refunded})
+		pushTitle := s.localizer.Customer(&customer, "notification.campaign_rejected.title", nil)
 		smsCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
-		_ = s.notifier.SendSMS(smsCtx, customerMobile, msgCustomer, &id64)
-		adminMsg := fmt.Sprintf("Campaign rejected:\n%s", title)
+		_ = sendCustomerNotice(smsCtx, s.notifier, s.pushDeviceRepo, &customer, pushTitle, msgCustomer)
+		adminMsg := s.localizer.Admin("admin.campaign_rejected", i18n.Args{"Title": title})
 		for _, mobile := range s.adminConfig.ActiveMobiles() {
 			_ = s.notifier.SendSMS(smsCtx, mobile, adminMsg, nil)
 		}
@@ -962,6 +936,112 @@ func (s *AdminCampaignFlowImpl) RejectCampaign(ctx context.Context, req *dto.Adm
 	}, nil
 }
 
+// refundCampaignReservation moves the budget reserved when the campaign was
+// finalized from frozen back to the free and credit balances it was taken
+// from and returns the freeze transaction.
+func (s *AdminCampaignFlowImpl) refundCampaignReservation(
+	ctx context.Context,
+	campaign *models.Campaign,
+	customer models.Customer,
+	meta map[string]any,
+	reason, description string,
+) (*models.Transaction, error) {
+	// Find the frozen reservation transaction created during finalize
+	freezeTxs, err := s.transactionRepo.ByFilter(ctx, models.TransactionFilter{
+		CustomerID: &campaign.CustomerID,
+		CampaignID: &campaign.ID,
+		Source:     utils.ToPtr("campaign_update"),
+		Operation:  utils.ToPtr("reserve_budget"),
+		Type:       utils.ToPtr(models.TransactionTypeFreeze),
+		Status:     utils.ToPtr(models.TransactionStatusCompleted),
+	}, "id DESC", 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(freezeTxs) == 0 {
+		return nil, ErrFreezeTransactionNotFound
+	}
+	if len(freezeTxs) > 1 {
+		return nil, ErrMultipleFreezeTransactionsFound
+	}
+	freezeTx := freezeTxs[0]
+
+	wallet, err := getWallet(ctx, s.walletRepo, campaign.CustomerID)
+	if err != nil {
+		return nil, err
+	}
+	latestBalance, err := getLatestBalanceSnapshot(ctx, s.walletRepo, wallet.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	amount := freezeTx.Amount
+	if latestBalance.FrozenBalance < amount {
+		return nil, ErrInsufficientFunds
+	}
+
+	metaBytes, _ := json.Marshal(meta)
+
+	// Restore the free/credit split the freeze took, so real money returns
+	// to the free balance
+	newFrozen := latestBalance.FrozenBalance - amount
+	freeRefund, creditRefund := computeFreezeRefundSplit(freezeTx, amount)
+	newFree := latestBalance.FreeBalance + freeRefund
+	newCredit := latestBalance.CreditBalance + creditRefund
+
+	newSnap := &models.BalanceSnapshot{
+		UUID:               uuid.New(),
+		CorrelationID:      freezeTx.CorrelationID,
+		WalletID:           wallet.ID,
+		CustomerID:         customer.ID,
+		FreeBalance:        newFree,
+		FrozenBalance:      newFrozen,
+		LockedBalance:      latestBalance.LockedBalance,
+		CreditBalance:      newCredit,
+		SpentOnCampaign:    latestBalance.SpentOnCampaign,
+		AgencyShareWithTax: latestBalance.AgencyShareWithTax,
+		TotalBalance:       newFree + newFrozen + latestBalance.LockedBalance + newCredit + latestBalance.SpentOnCampaign + latestBalance.AgencyShareWithTax,
+		Reason:             reason,
+		Description:        description,
+		Metadata:           metaBytes,
+	}
+	if err := s.walletRepo.AdvanceVersion(ctx, &wallet); err != nil {
+		return nil, err
+	}
+	if err := s.balanceSnapshotRepo.Save(ctx, newSnap); err != nil {
+		return nil, err
+	}
+
+	beforeMap, err := latestBalance.GetBalanceMap()
+	if err != nil {
+		return nil, err
+	}
+	afterMap, err := newSnap.GetBalanceMap()
+	if err != nil {
+		return nil, err
+	}
+
+	refundTx := &models.Transaction{
+		UUID:          uuid.New(),
+		CorrelationID: freezeTx.CorrelationID,
+		Type:          models.TransactionTypeRefund,
+		Status:        models.TransactionStatusCompleted,
+		Amount:        amount,
+		Currency:      utils.TomanCurrency,
+		WalletID:      wallet.ID,
+		CustomerID:    customer.ID,
+		BalanceBefore: beforeMap,
+		BalanceAfter:  afterMap,
+		Description:   description,
+		Metadata:      metaBytes,
+	}
+	if err := s.transactionRepo.Save(ctx, refundTx); err != nil {
+		return nil, err
+	}
+
+	return freezeTx, nil
+}
+
 // RescheduleCampaign updates the scheduled time for eligible campaigns (admin action).
 func (s *AdminCampaignFlowImpl) RescheduleCampaign(ctx context.Context, req *dto.AdminRescheduleCampaignRequest) (*dto.AdminRescheduleCampaignResponse, error) {
 	if req == nil || req.CampaignID == 0 {
@@ -1026,7 +1106,8 @@ func (s *AdminCampaignFlowImpl) RescheduleCampaign(ctx context.Context, req *dto
 	}, nil
 }
 
-// CancelCampaign cancels an approved campaign or a missed waiting-for-approval campaign.
+// CancelCampaign cancels an approved campaign or a missed waiting-for-approval campaign,
+// or stops a running or paused campaign and refunds its unsent share.
 func (s *AdminCampaignFlowImpl) CancelCampaign(ctx context.Context, req *dto.AdminCancelCampaignRequest) (*dto.AdminCancelCampaignResponse, error) {
 	if req == nil || req.CampaignID == 0 || strings.TrimSpace(req.Comment) == "" {
 		return nil, NewBusinessError("ADMIN_CANCEL_CAMPAIGN_FAILED", "campaign_id and comment are required", nil)
@@ -1034,8 +1115,14 @@ func (s *AdminCampaignFlowImpl) CancelCampaign(ctx context.Context, req *dto.Adm
 
 	var campaign *models.Campaign
 	var customer models.Customer
+	var stopped bool
+	var sentBeforeStop, refundAmount uint64
+
+	err := withVersionRetry(ctx, s.db, func(txCtx context.Context) error {
+		if err := lockCampaignExecution(txCtx, req.CampaignID); err != nil {
+			return err
+		}
 
-	err := repository.WithTransaction(ctx, s.db, func(txCtx context.Context) error {
 		var err error
 		campaign, err = s.campaignRepo.ByID(txCtx, req.CampaignID)
 		if err != nil {
@@ -1046,7 +1133,8 @@ func (s *AdminCampaignFlowImpl) CancelCampaign(ctx context.Context, req *dto.Adm
 		}
 		nowUTC := utils.UTCNow()
 		isMissedPendingApproval := isAdminMissedPendingApprovalCampaign(campaign, nowUTC)
-		if campaign.Status != models.CampaignStatusApproved && !isMissedPendingApproval {
+		executing := campaign.Status == models.CampaignStatusRunning || campaign.Status == models.CampaignStatusPaused
+		if campaign.Status != models.CampaignStatusApproved && !isMissedPendingApproval && !executing {
 			return ErrCampaignNotApproved
 		}
 		if campaign.Spec.ScheduleAt == nil || campaign.Spec.ScheduleAt.IsZero() {
@@ -1070,7 +1158,22 @@ func (s *AdminCampaignFlowImpl) CancelCampaign(ctx context.Context, req *dto.Adm
 			return err
 		}
 
-		if isMissedPendingApproval {
+		if executing {
+			sentBeforeStop, refundAmount, err = stopCampaignExecution(
+				txCtx,
+				s.processedCampaignRepo,
+				s.walletRepo,
+				s.balanceSnapshotRepo,
+				s.transactionRepo,
+				campaign,
+				"admin_campaign_cancel",
+				&req.Comment,
+			)
+			if err != nil {
+				return err
+			}
+			stopped = true
+		} else if isMissedPendingApproval {
 			freezeTxs, err := s.transactionRepo.ByFilter(txCtx, models.TransactionFilter{
 				CustomerID: &campaign.CustomerID,
 				CampaignID: &campaign.ID,
@@ -1123,6 +1226,9 @@ func (s *AdminCampaignFlowImpl) CancelCampaign(ctx context.Context, req *dto.Adm
 				Description:        fmt.Sprintf("Refund reserved budget for admin-cancelled campaign %d before approval", campaign.ID),
 				Metadata:           metaBytes,
 			}
+			if err := s.walletRepo.AdvanceVersion(txCtx, &wallet); err != nil {
+				return err
+			}
 			if err := s.balanceSnapshotRepo.Save(txCtx, newSnap); err != nil {
 				return err
 			}
@@ -1207,6 +1313,9 @@ func (s *AdminCampaignFlowImpl) CancelCampaign(ctx context.Context, req *dto.Adm
 				Description:        fmt.Sprintf("Refund spent budget for admin-cancelled campaign %d", campaign.ID),
 				Metadata:           metaBytes,
 			}
+			if err := s.walletRepo.AdvanceVersion(txCtx, &wallet); err != nil {
+				return err
+			}
 			if err := s.balanceSnapshotRepo.Save(txCtx, newSnap); err != nil {
 				return err
 			}
@@ -1261,18 +1370,31 @@ func (s *AdminCampaignFlowImpl) CancelCampaign(ctx context.Context, req *dto.Adm
 		if campaign.Spec.Title != nil && *campaign.Spec.Title != "" {
 			title = *campaign.Spec.Title
 		}
-		customerMobile := normalizeIranMobile(customer.RepresentativeMobile)
-		msgCustomer := fmt.Sprintf("Your campaign '%s' has been cancelled by admin.", title)
-		id64 := int64(customer.ID)
+		msgCustomer := s.localizer.Customer(&customer, "campaign.cancelled", i18n.Args{"Title": title})
+		pushTitle := s.localizer.Customer(&customer, "push.campaign_cancelled.title", nil)
 		smsCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
-		_ = s.notifier.SendSMS(smsCtx, customerMobile, msgCustomer, &id64)
-		adminMsg := fmt.Sprintf("Campaign cancelled by admin:\n%s", title)
+		_ = sendCustomerNotice(smsCtx, s.notifier, s.pushDeviceRepo, &customer, pushTitle, msgCustomer)
+		adminMsg := s.localizer.Admin("admin.campaign_cancelled", i18n.Args{"Title": title})
 		for _, mobile := range s.adminConfig.ActiveMobiles() {
 			_ = s.notifier.SendSMS(smsCtx, mobile, adminMsg, nil)
 		}
 	}
 
+	if stopped {
+		logAdminAction(ctx, s.auditRepo, models.AuditActionCampaignExecutionStopped, "Admin stopped running campaign", true, &customer.ID, map[string]any{
+			"campaign_id":      campaign.ID,
+			"comment":          req.Comment,
+			"sent_before_stop": sentBeforeStop,
+			"refund_amount":    refundAmount,
+		}, nil)
+		return &dto.AdminCancelCampaignResponse{
+			Message:        "Campaign stopped and unsent budget refunded successfully",
+			SentBeforeStop: &sentBeforeStop,
+			RefundAmount:   &refundAmount,
+		}, nil
+	}
+
 	logAdminAction(ctx, s.auditRepo, models.AuditActionAdminCampaignCancelled, "Admin cancelled campaign", true, &customer.ID, map[string]any{
 		"campaign_id": campaign.ID,
 		"comment":     req.Comment,
@@ -1454,15 +1576,11 @@ func isWithinRescheduleWindow(tehranTime time.Time) bool {
 	return true
 }
 
+// normalizeIranMobile returns the SMS gateway form of a mobile number, or m
+// unchanged when it is not an Iranian mobile number
 func normalizeIranMobile(m string) string {
-	if m == "" {
-		return m
-	}
-	if strings.HasPrefix(m, "+") {
-		return m[1:]
-	}
-	if strings.HasPrefix(m, "0") && len(m) == 11 {
-		return "98" + m[1:]
+	if n, err := phonenumber.Parse(m); err == nil {
+		return n.International()
 	}
 	return m
 }
@@ -111,6 +111,7 @@ func (s *BotCampaignFlowImpl) ListReadyCampaigns(ctx context.Context, platform *
 			ID:                 c.ID,
 			CustomerID:         c.CustomerID,
 			Hidden:             c.Hidden,
+			Sandbox:            c.IsSandbox,
 			Status:             c.Status.String(),
 			CreatedAt:          c.CreatedAt,
 			UpdatedAt:          c.UpdatedAt,
@@ -143,6 +144,8 @@ func (s *BotCampaignFlowImpl) ListReadyCampaigns(ctx context.Context, platform *
 			AudienceGrades: campaignAudienceGradesOrDefault(c.Spec.AudienceGrades),
 
 			TargetAudienceExcelFileUUID: c.Spec.TargetAudienceExcelFileUUID,
+
+			Variants: toCampaignContentVariantSpecs(c.Spec.Variants),
 		})
 	}
 
@@ -261,6 +264,9 @@ func (s *BotCampaignFlowImpl) MoveCampaignToRunning(ctx context.Context, campaig
 // MoveCampaignToExecuted moves campaign status to executed
 func (s *BotCampaignFlowImpl) MoveCampaignToExecuted(ctx context.Context, campaignID uint) error {
 	err := repository.WithTransaction(ctx, s.db, func(txCtx context.Context) error {
+		if err := lockCampaignExecution(txCtx, campaignID); err != nil {
+			return err
+		}
 		campaign, err := s.campaignRepo.ByID(txCtx, campaignID)
 		if err != nil {
 			return err
@@ -268,6 +274,11 @@ func (s *BotCampaignFlowImpl) MoveCampaignToExecuted(ctx context.Context, campai
 		if campaign == nil {
 			return ErrCampaignNotFound
 		}
+		// A campaign stopped by its owner or an admin after the last batch was
+		// handed to the provider keeps its cancelled status.
+		if campaign.Status == models.CampaignStatusCancelled || campaign.Status == models.CampaignStatusCancelledByAdmin {
+			return nil
+		}
 		campaign.Status = models.CampaignStatusExecuted
 		err = s.campaignRepo.Update(txCtx, *campaign)
 		if err != nil {
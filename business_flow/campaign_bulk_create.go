@@ -0,0 +1,161 @@
+package businessflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/repository"
+)
+
+const maxBulkCampaigns = 100
+
+// BulkCreateCampaigns creates several campaigns for one customer, each
+// validated like CreateCampaign. In atomic mode one invalid campaign rejects
+// the batch. Otherwise the invalid ones are reported and the rest created.
+// The budgets of the created campaigns together may not exceed what the wallet
+// and credit line can fund. In atomic mode an excess rejects the batch with
+// ErrInsufficientFunds; otherwise campaigns past the point where funds run out
+// fail with INSUFFICIENT_FUNDS. The campaigns are created in one transaction.
+func (s *CampaignFlowImpl) BulkCreateCampaigns(ctx context.Context, req *dto.BulkCreateCampaignsRequest, metadata *ClientMetadata) (*dto.BulkCreateCampaignsResponse, error) {
+	if req == nil || len(req.Campaigns) == 0 || len(req.Campaigns) > maxBulkCampaigns {
+		return nil, NewBusinessError("BULK_CAMPAIGN_COUNT_INVALID", "Bulk creation takes 1 to 100 campaigns", ErrBulkCampaignCountInvalid)
+	}
+	if err := s.authorizeDelegation(ctx, req.CustomerID, models.AgencyDelegationPermissionCreateCampaigns, metadata); err != nil {
+		return nil, err
+	}
+
+	customer, err := getCustomer(ctx, s.customerRepo, req.CustomerID)
+	if err != nil {
+		return nil, NewBusinessError("CUSTOMER_LOOKUP_FAILED", "Failed to lookup customer", err)
+	}
+	if err := checkCustomerPolicy(customer, CustomerActionCreateCampaign); err != nil {
+		return nil, err
+	}
+	if err := checkPostpaidStanding(ctx, s.postpaidInvoiceRepo, customer.ID); err != nil {
+		return nil, err
+	}
+
+	results := make([]dto.BulkCreateCampaignResult, len(req.Campaigns))
+	valid := make([]int, 0, len(req.Campaigns))
+	for i := range req.Campaigns {
+		item := &req.Campaigns[i]
+		item.CustomerID = req.CustomerID
+		results[i].Index = i
+
+		if err := s.validateCreateCampaignRequest(ctx, item); err != nil {
+			results[i] = failedBulkCampaign(i, NewBusinessError("CAMPAIGN_VALIDATION_FAILED", "Campaign validation failed", err))
+			continue
+		}
+		if err := s.prepareCreateCampaign(ctx, item, customer); err != nil {
+			results[i] = failedBulkCampaign(i, err)
+			continue
+		}
+		valid = append(valid, i)
+	}
+
+	resp := &dto.BulkCreateCampaignsResponse{Results: results}
+	if req.Atomic && len(valid) < len(req.Campaigns) {
+		resp.Failed = len(req.Campaigns) - len(valid)
+		return resp, nil
+	}
+
+	var created []*models.Campaign
+	err = repository.WithTransaction(ctx, s.db, func(txCtx context.Context) error {
+		remaining, err := s.fundableBudget(txCtx, customer)
+		if err != nil {
+			return err
+		}
+		for _, i := range valid {
+			item := &req.Campaigns[i]
+			var budget uint64
+			if item.Budget != nil {
+				budget = *item.Budget
+			}
+			if budget > remaining {
+				if req.Atomic {
+					return ErrInsufficientFunds
+				}
+				results[i] = failedBulkCampaign(i, NewBusinessError("INSUFFICIENT_FUNDS", "Insufficient funds", ErrInsufficientFunds))
+				continue
+			}
+
+			campaign, err := s.createCampaign(txCtx, item, &customer)
+			if err != nil {
+				return err
+			}
+			remaining -= budget
+			resp.TotalBudget += budget
+			created = append(created, campaign)
+			results[i] = dto.BulkCreateCampaignResult{
+				Index:   i,
+				Success: true,
+				ID:      campaign.ID,
+				UUID:    campaign.UUID.String(),
+				Status:  string(campaign.Status),
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		errMsg := fmt.Sprintf("Bulk campaign creation failed: %s", err.Error())
+		_ = s.createAuditLog(ctx, &customer, models.AuditActionCampaignCreationFailed, errMsg, false, &errMsg, metadata)
+
+		if errors.Is(err, ErrInsufficientFunds) {
+			return nil, NewBusinessError("INSUFFICIENT_FUNDS", "Campaign budgets exceed the available balance", err)
+		}
+		return nil, NewBusinessError("CAMPAIGN_CREATION_FAILED", "Campaign creation failed", err)
+	}
+
+	for _, campaign := range created {
+		msg := fmt.Sprintf("Campaign created successfully in bulk: %s", campaign.UUID.String())
+		_ = s.createAuditLog(ctx, &customer, models.AuditActionCampaignCreated, msg, true, nil, metadata)
+	}
+
+	resp.Created = len(created)
+	resp.Failed = len(req.Campaigns) - resp.Created
+	return resp, nil
+}
+
+// fundableBudget is what customer could spend on campaigns right now: the
+// free and credit balance of the wallet plus what the credit line can still
+// lend. Sandbox customers have no credit line.
+func (s *CampaignFlowImpl) fundableBudget(ctx context.Context, customer models.Customer) (uint64, error) {
+	wallet, err := getWallet(ctx, s.walletRepo, customer.ID)
+	if err != nil {
+		return 0, err
+	}
+	balance, err := getLatestBalanceSnapshot(ctx, s.walletRepo, wallet.ID)
+	if err != nil {
+		return 0, err
+	}
+	fundable := balance.FreeBalance + balance.CreditBalance
+	if customer.InSandbox() {
+		return fundable, nil
+	}
+
+	line, err := s.creditLineRepo.ByCustomerID(ctx, customer.ID)
+	if err != nil {
+		return 0, err
+	}
+	if line != nil {
+		fundable += line.Available()
+	}
+	return fundable, nil
+}
+
+func failedBulkCampaign(index int, err error) dto.BulkCreateCampaignResult {
+	result := dto.BulkCreateCampaignResult{Index: index, ErrorCode: "CAMPAIGN_VALIDATION_FAILED", Error: err.Error()}
+	var be *BusinessError
+	if errors.As(err, &be) {
+		result.ErrorCode = be.Code
+		if be.Err != nil {
+			result.Error = be.Err.Error()
+		} else {
+			result.Error = be.Message
+		}
+	}
+	return result
+}
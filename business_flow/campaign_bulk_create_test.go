@@ -0,0 +1,34 @@
+package businessflow
+
+import (
+	"context"
+	"testing"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+)
+
+func TestBulkCreateCampaignsRejectsCount(t *testing.T) {
+	t.Parallel()
+
+	flow := &CampaignFlowImpl{}
+	for _, n := range []int{0, maxBulkCampaigns + 1} {
+		req := &dto.BulkCreateCampaignsRequest{CustomerID: 1, Campaigns: make([]dto.CreateCampaignRequest, n)}
+		if _, err := flow.BulkCreateCampaigns(context.Background(), req, nil); !IsBulkCampaignCountInvalid(err) {
+			t.Fatalf("%d campaigns: got %v, want ErrBulkCampaignCountInvalid", n, err)
+		}
+	}
+}
+
+func TestFailedBulkCampaignReportsTheCause(t *testing.T) {
+	t.Parallel()
+
+	result := failedBulkCampaign(3, NewBusinessError("CAMPAIGN_VALIDATION_FAILED", "Campaign validation failed", ErrCampaignTitleRequired))
+	if result.Index != 3 || result.Success || result.ErrorCode != "CAMPAIGN_VALIDATION_FAILED" || result.Error != ErrCampaignTitleRequired.Error() {
+		t.Fatalf("unexpected result %+v", result)
+	}
+
+	result = failedBulkCampaign(0, NewBusinessError("INSUFFICIENT_FUNDS", "Insufficient funds", nil))
+	if result.ErrorCode != "INSUFFICIENT_FUNDS" || result.Error != "Insufficient funds" {
+		t.Fatalf("unexpected result %+v", result)
+	}
+}
@@ -0,0 +1,147 @@
+package businessflow
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/pricing"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/lib/pq"
+)
+
+// EstimateCampaign prices a prospective SMS campaign and counts its reachable
+// audience without creating anything.
+func (s *CampaignFlowImpl) EstimateCampaign(ctx context.Context, req *dto.EstimateCampaignRequest, metadata *ClientMetadata) (*dto.EstimateCampaignResponse, error) {
+	if req == nil {
+		return nil, NewBusinessError("CAMPAIGN_ESTIMATE_VALIDATION_FAILED", "request is required", ErrInvalidState)
+	}
+	if len(req.Tags) == 0 {
+		return nil, NewBusinessError("CAMPAIGN_ESTIMATE_VALIDATION_FAILED", "Campaign estimate validation failed", ErrCampaignTagsRequired)
+	}
+	hasContent := req.Content != nil && strings.TrimSpace(*req.Content) != ""
+	if !hasContent && (req.TextLength == nil || *req.TextLength == 0) {
+		return nil, NewBusinessError("CAMPAIGN_ESTIMATE_VALIDATION_FAILED", "Campaign estimate validation failed", ErrCampaignContentRequired)
+	}
+	if _, err := sanitizeShortLinkDomain(req.ShortLinkDomain); err != nil {
+		return nil, NewBusinessError("CAMPAIGN_ESTIMATE_VALIDATION_FAILED", "Campaign estimate validation failed", err)
+	}
+
+	segments := estimateSMSSegments(req)
+
+	platform := models.CampaignPlatformSMS
+	segmentPriceFactor := defaultSegmentPriceFactor
+	if len(req.Level3s) > 0 {
+		maxFactor, err := s.fetchSegmentPriceFactor(ctx, req.Level3s, platform)
+		if err != nil && !errors.Is(err, ErrSegmentPriceFactorNotFound) {
+			return nil, NewBusinessError("SEGMENT_PRICE_FACTOR_FETCH_FAILED", "Failed to fetch segment price factors", err)
+		}
+		if err != nil || maxFactor == 0 {
+			s.notifyMissingSegmentPriceFactor(ctx, req.Level3s)
+			return nil, NewBusinessError("SEGMENT_PRICE_FACTOR_NOT_FOUND", "Segment price factor not found for provided level3 options", ErrSegmentPriceFactorNotFound)
+		}
+		segmentPriceFactor = maxFactor
+	}
+
+	pbp, err := s.platformBaseRepo.LatestByPlatform(ctx, platform)
+	if err != nil {
+		return nil, NewBusinessError("PLATFORM_BASE_PRICE_FETCH_FAILED", "Failed to fetch platform base price", err)
+	}
+	if pbp == nil {
+		return nil, NewBusinessError("PLATFORM_BASE_PRICE_NOT_FOUND", "Platform base price not found for platform "+platform, ErrPlatformBasePriceNotFound)
+	}
+	pp, err := s.pagePriceRepo.LatestByPlatform(ctx, platform)
+	if err != nil {
+		return nil, NewBusinessError("PAGE_PRICE_FETCH_FAILED", "Failed to fetch page price", err)
+	}
+	if pp == nil {
+		return nil, NewBusinessError("PAGE_PRICE_NOT_FOUND", "Page price not found for platform "+platform, ErrPagePriceNotFound)
+	}
+
+	quote, err := s.quoteCustomerSMS(ctx, req.CustomerID, &req.LineNumber, segments.Parts, pbp.Price)
+	if err != nil {
+		return nil, err
+	}
+	pricePerMsg := pricing.MessagePrice(quote.PartsCost, segmentPriceFactor, pp.Price)
+
+	audienceByColor, err := s.countAudienceByColor(ctx, req.Tags)
+	if err != nil {
+		return nil, err
+	}
+	white := audienceByColor[models.AudienceColorWhite]
+	reachable := white + audienceByColor[models.AudienceColorPink]
+
+	return &dto.EstimateCampaignResponse{
+		Message:           "Campaign estimated successfully",
+		Encoding:          segments.Encoding,
+		Parts:             segments.Parts,
+		PricePerMessage:   pricePerMsg,
+		AudienceByColor:   audienceByColor,
+		ReachableAudience: reachable,
+		MinCost:           pricePerMsg * white,
+		MaxCost:           pricePerMsg * reachable,
+	}, nil
+}
+
+// estimateSMSSegments sizes the body the scheduler would send. When only a
+// length is known the body is assumed to be UCS-2, which the Persian opt-out
+// suffix forces anyway.
+func estimateSMSSegments(req *dto.EstimateCampaignRequest) pricing.SMSSegments {
+	if req.Content != nil && strings.TrimSpace(*req.Content) != "" {
+		return campaignSMSSegments(*req.Content, req.AdLink, req.ShortLinkDomain)
+	}
+
+	units := *req.TextLength + uint64(len(utf16.Encode([]rune(pricing.SMSOptOutSuffix))))
+	return pricing.SMSSegments{
+		Encoding: pricing.SMSEncodingUCS2,
+		Units:    units,
+		Parts:    pricing.SMSPartsForLength(pricing.SMSEncodingUCS2, units),
+	}
+}
+
+// countAudienceByColor counts audience profiles with a phone number that carry
+// any of the given active tags, grouped by color.
+func (s *CampaignFlowImpl) countAudienceByColor(ctx context.Context, rawTags []string) (map[string]uint64, error) {
+	counts := map[string]uint64{
+		models.AudienceColorWhite: 0,
+		models.AudienceColorPink:  0,
+	}
+
+	ids := make([]uint, 0, len(rawTags))
+	for _, raw := range rawTags {
+		id, err := strconv.ParseUint(strings.TrimSpace(raw), 10, 32)
+		if err != nil {
+			return nil, NewBusinessError("CAMPAIGN_ESTIMATE_VALIDATION_FAILED", "Campaign estimate validation failed", ErrCampaignTagInvalid)
+		}
+		ids = append(ids, uint(id))
+	}
+	tags, err := s.tagRepo.ListByIDs(ctx, ids)
+	if err != nil {
+		return nil, NewBusinessError("TAG_LOOKUP_FAILED", "Failed to lookup tags", err)
+	}
+	if len(tags) == 0 {
+		return counts, nil
+	}
+
+	tagIDs := make(pq.Int32Array, len(tags))
+	for i, tag := range tags {
+		tagIDs[i] = int32(tag.ID)
+	}
+
+	for color := range counts {
+		n, err := s.audienceProfileRepo.Count(ctx, models.AudienceProfileFilter{
+			Tags:           &tagIDs,
+			Color:          utils.ToPtr(color),
+			HasPhoneNumber: utils.ToPtr(true),
+		})
+		if err != nil {
+			return nil, NewBusinessError("AUDIENCE_COUNT_FAILED", "Failed to count audience", err)
+		}
+		counts[color] = uint64(n)
+	}
+	return counts, nil
+}
@@ -0,0 +1,252 @@
+package businessflow
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/repository"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PauseCampaign pauses a running campaign. The platform schedulers check the
+// status before every send batch and wait until the campaign is resumed or
+// cancelled.
+func (s *CampaignFlowImpl) PauseCampaign(ctx context.Context, req *dto.PauseCampaignRequest, metadata *ClientMetadata) (*dto.PauseCampaignResponse, error) {
+	if req == nil || req.CampaignID == 0 {
+		return nil, NewBusinessError("PAUSE_CAMPAIGN_VALIDATION_FAILED", "campaign_id is required", ErrCampaignNotFound)
+	}
+	if err := s.authorizeDelegation(ctx, req.CustomerID, models.AgencyDelegationPermissionManageCampaigns, metadata); err != nil {
+		return nil, err
+	}
+
+	campaign, err := s.setCampaignExecutionStatus(ctx, req.CustomerID, req.CampaignID, models.CampaignStatusRunning, models.CampaignStatusPaused, ErrCampaignNotRunning)
+	if err != nil {
+		return nil, NewBusinessError("PAUSE_CAMPAIGN_FAILED", "Failed to pause campaign", err)
+	}
+
+	if customer, err := getCustomer(ctx, s.customerRepo, req.CustomerID); err == nil {
+		msg := fmt.Sprintf("Campaign paused: %d", campaign.ID)
+		_ = s.createAuditLog(ctx, &customer, models.AuditActionCampaignPaused, msg, true, nil, metadata)
+	}
+
+	return &dto.PauseCampaignResponse{
+		Message: "Campaign paused successfully",
+		Status:  string(campaign.Status),
+	}, nil
+}
+
+// ResumeCampaign resumes a paused campaign from the next unsent batch.
+func (s *CampaignFlowImpl) ResumeCampaign(ctx context.Context, req *dto.ResumeCampaignRequest, metadata *ClientMetadata) (*dto.ResumeCampaignResponse, error) {
+	if req == nil || req.CampaignID == 0 {
+		return nil, NewBusinessError("RESUME_CAMPAIGN_VALIDATION_FAILED", "campaign_id is required", ErrCampaignNotFound)
+	}
+	if err := s.authorizeDelegation(ctx, req.CustomerID, models.AgencyDelegationPermissionManageCampaigns, metadata); err != nil {
+		return nil, err
+	}
+
+	campaign, err := s.setCampaignExecutionStatus(ctx, req.CustomerID, req.CampaignID, models.CampaignStatusPaused, models.CampaignStatusRunning, ErrCampaignNotPaused)
+	if err != nil {
+		return nil, NewBusinessError("RESUME_CAMPAIGN_FAILED", "Failed to resume campaign", err)
+	}
+
+	if customer, err := getCustomer(ctx, s.customerRepo, req.CustomerID); err == nil {
+		msg := fmt.Sprintf("Campaign resumed: %d", campaign.ID)
+		_ = s.createAuditLog(ctx, &customer, models.AuditActionCampaignResumed, msg, true, nil, metadata)
+	}
+
+	return &dto.ResumeCampaignResponse{
+		Message: "Campaign resumed successfully",
+		Status:  string(campaign.Status),
+	}, nil
+}
+
+// setCampaignExecutionStatus moves a customer's campaign between running and
+// paused while holding the campaign's execution lock.
+func (s *CampaignFlowImpl) setCampaignExecutionStatus(
+	ctx context.Context,
+	customerID, campaignID uint,
+	from, to models.CampaignStatus,
+	errWrongStatus error,
+) (*models.Campaign, error) {
+	var campaign *models.Campaign
+	err := withVersionRetry(ctx, s.db, func(txCtx context.Context) error {
+		if err := lockCampaignExecution(txCtx, campaignID); err != nil {
+			return err
+		}
+
+		var err error
+		campaign, err = s.campaignRepo.ByID(txCtx, campaignID)
+		if err != nil {
+			return err
+		}
+		if campaign == nil {
+			return ErrCampaignNotFound
+		}
+		if campaign.CustomerID != customerID {
+			return ErrCampaignAccessDenied
+		}
+		if campaign.Status != from {
+			return errWrongStatus
+		}
+
+		campaign.Status = to
+		campaign.UpdatedAt = utils.ToPtr(utils.UTCNow())
+		return s.campaignRepo.Update(txCtx, *campaign)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return campaign, nil
+}
+
+// lockCampaignExecution takes the per-campaign advisory lock that the platform
+// schedulers hold while saving a send batch, so a status change and a batch
+// never interleave. The lock is keyed in its own namespace so it does not
+// collide with single-key locks on other IDs, such as bundle evaluations.
+func lockCampaignExecution(txCtx context.Context, campaignID uint) error {
+	if tx, ok := txCtx.Value(repository.TxContextKey).(*gorm.DB); ok && tx != nil {
+		return tx.Exec("SELECT pg_advisory_xact_lock(hashtext('campaign_execution'), ?)", int64(campaignID)).Error
+	}
+	return nil
+}
+
+// stopCampaignExecution records how many recipients a running or paused
+// campaign already sent and refunds the share of the approval debit that
+// belongs to the unsent recipients from spent-on-campaign to credit.
+// The caller must hold the campaign's execution lock and save the campaign
+// with its cancelled status.
+func stopCampaignExecution(
+	txCtx context.Context,
+	processedCampaignRepo repository.ProcessedCampaignRepository,
+	walletRepo repository.WalletRepository,
+	balanceSnapshotRepo repository.BalanceSnapshotRepository,
+	transactionRepo repository.TransactionRepository,
+	campaign *models.Campaign,
+	source string,
+	comment *string,
+) (sent uint64, refund uint64, err error) {
+	sent, err = processedCampaignRepo.SentAudienceCount(txCtx, campaign.ID)
+	if err != nil {
+		return 0, 0, err
+	}
+	campaign.ExecutionStoppedAt = utils.ToPtr(utils.UTCNow())
+	campaign.SentBeforeStop = utils.ToPtr(sent)
+
+	if campaign.NumAudience == nil || *campaign.NumAudience == 0 || sent >= *campaign.NumAudience {
+		return sent, 0, nil
+	}
+	total := *campaign.NumAudience
+	unsent := total - sent
+
+	debitTxs, err := transactionRepo.ByFilter(txCtx, models.TransactionFilter{
+		CustomerID: &campaign.CustomerID,
+		CampaignID: &campaign.ID,
+		Source:     utils.ToPtr("admin_campaign_approve"),
+		Operation:  utils.ToPtr("approve_campaign_budget_consume"),
+		Type:       utils.ToPtr(models.TransactionTypeFee),
+		Status:     utils.ToPtr(models.TransactionStatusCompleted),
+	}, "id DESC", 0, 0)
+	if err != nil {
+		return 0, 0, err
+	}
+	if len(debitTxs) == 0 {
+		return 0, 0, ErrCampaignDebitTransactionNotFound
+	}
+	if len(debitTxs) > 1 {
+		return 0, 0, ErrMultipleCampaignDebitTransactionsFound
+	}
+	debitTx := debitTxs[0]
+
+	if debitTx.Amount > 0 && unsent > math.MaxUint64/debitTx.Amount {
+		return 0, 0, fmt.Errorf("refund amount overflow for campaign=%d", campaign.ID)
+	}
+	refund = debitTx.Amount * unsent / total
+	if refund == 0 {
+		return sent, 0, nil
+	}
+
+	wallet, err := getWallet(txCtx, walletRepo, campaign.CustomerID)
+	if err != nil {
+		return 0, 0, err
+	}
+	latestBalance, err := getLatestBalanceSnapshot(txCtx, walletRepo, wallet.ID)
+	if err != nil {
+		return 0, 0, err
+	}
+	if latestBalance.SpentOnCampaign < refund {
+		return 0, 0, ErrInsufficientFunds
+	}
+
+	meta := map[string]any{
+		"source":           source,
+		"operation":        "stop_campaign_refund_unsent",
+		"campaign_id":      campaign.ID,
+		"num_audience":     total,
+		"sent_before_stop": sent,
+		"unsent_messages":  unsent,
+		"refund_amount":    refund,
+		"comment":          comment,
+	}
+	metaBytes, _ := json.Marshal(meta)
+
+	newCredit := latestBalance.CreditBalance + refund
+	newSpentOnCampaign := latestBalance.SpentOnCampaign - refund
+
+	newSnap := &models.BalanceSnapshot{
+		UUID:               uuid.New(),
+		CorrelationID:      debitTx.CorrelationID,
+		WalletID:           wallet.ID,
+		CustomerID:         campaign.CustomerID,
+		FreeBalance:        latestBalance.FreeBalance,
+		FrozenBalance:      latestBalance.FrozenBalance,
+		LockedBalance:      latestBalance.LockedBalance,
+		CreditBalance:      newCredit,
+		SpentOnCampaign:    newSpentOnCampaign,
+		AgencyShareWithTax: latestBalance.AgencyShareWithTax,
+		TotalBalance:       latestBalance.FreeBalance + latestBalance.FrozenBalance + latestBalance.LockedBalance + newCredit + newSpentOnCampaign + latestBalance.AgencyShareWithTax,
+		Reason:             "campaign_stopped_unsent_refund",
+		Description:        fmt.Sprintf("Refund unsent messages for stopped campaign %d", campaign.ID),
+		Metadata:           metaBytes,
+	}
+	if err := walletRepo.AdvanceVersion(txCtx, &wallet); err != nil {
+		return 0, 0, err
+	}
+	if err := balanceSnapshotRepo.Save(txCtx, newSnap); err != nil {
+		return 0, 0, err
+	}
+
+	beforeMap, err := latestBalance.GetBalanceMap()
+	if err != nil {
+		return 0, 0, err
+	}
+	afterMap, err := newSnap.GetBalanceMap()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	refundTx := &models.Transaction{
+		UUID:          uuid.New(),
+		CorrelationID: debitTx.CorrelationID,
+		Type:          models.TransactionTypeRefund,
+		Status:        models.TransactionStatusCompleted,
+		Amount:        refund,
+		Currency:      utils.TomanCurrency,
+		WalletID:      wallet.ID,
+		CustomerID:    campaign.CustomerID,
+		BalanceBefore: beforeMap,
+		BalanceAfter:  afterMap,
+		Description:   fmt.Sprintf("Refund unsent messages for stopped campaign %d", campaign.ID),
+		Metadata:      metaBytes,
+	}
+	if err := transactionRepo.Save(txCtx, refundTx); err != nil {
+		return 0, 0, err
+	}
+
+	return sent, refund, nil
+}
@@ -18,9 +18,10 @@ import (
 	"time"
 
 	"github.com/amirphl/Yamata-no-Orochi/app/dto"
-	"github.com/amirphl/Yamata-no-Orochi/app/services"
+	"github.com/amirphl/Yamata-no-Orochi/app/i18n"
 	"github.com/amirphl/Yamata-no-Orochi/config"
 	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/pricing"
 	"github.com/amirphl/Yamata-no-Orochi/repository"
 	"github.com/amirphl/Yamata-no-Orochi/utils"
 	"github.com/google/uuid"
@@ -32,22 +33,34 @@ import (
 // CampaignFlow handles the campaign business logic
 type CampaignFlow interface {
 	CreateCampaign(ctx context.Context, req *dto.CreateCampaignRequest, metadata *ClientMetadata) (*dto.CreateCampaignResponse, error)
+	BulkCreateCampaigns(ctx context.Context, req *dto.BulkCreateCampaignsRequest, metadata *ClientMetadata) (*dto.BulkCreateCampaignsResponse, error)
 	UpdateCampaign(ctx context.Context, req *dto.UpdateCampaignRequest, metadata *ClientMetadata) (*dto.UpdateCampaignResponse, error)
 	CalculateCampaignCapacity(ctx context.Context, req *dto.CalculateCampaignCapacityRequest, metadata *ClientMetadata) (*dto.CalculateCampaignCapacityResponse, error)
 	CalculateCampaignCost(ctx context.Context, req *dto.CalculateCampaignCostRequest, metadata *ClientMetadata) (*dto.CalculateCampaignCostResponse, error)
 	CalculateCampaignCostV2(ctx context.Context, req *dto.CalculateCampaignCostV2Request, metadata *ClientMetadata) (*dto.CalculateCampaignCostResponse, error)
+	EstimateCampaign(ctx context.Context, req *dto.EstimateCampaignRequest, metadata *ClientMetadata) (*dto.EstimateCampaignResponse, error)
 	ListCampaigns(ctx context.Context, req *dto.ListCampaignsRequest, metadata *ClientMetadata) (*dto.ListCampaignsResponse, error)
 	GetLastInitiatedCampaign(ctx context.Context, customerID uint, metadata *ClientMetadata) (*dto.GetLastInitiatedCampaignResponse, error)
 	GetPagePrices(ctx context.Context) (*dto.GetPagePricesResponse, error)
 	ListAudienceSpec(ctx context.Context, platform *string) (*dto.ListAudienceSpecResponse, error)
 	GetApprovedRunningSummary(ctx context.Context, customerID uint) (*dto.CampaignsSummaryResponse, error)
 	CancelCampaign(ctx context.Context, req *dto.CancelCampaignRequest, metadata *ClientMetadata) (*dto.CancelCampaignResponse, error)
+	PauseCampaign(ctx context.Context, req *dto.PauseCampaignRequest, metadata *ClientMetadata) (*dto.PauseCampaignResponse, error)
+	ResumeCampaign(ctx context.Context, req *dto.ResumeCampaignRequest, metadata *ClientMetadata) (*dto.ResumeCampaignResponse, error)
 	HideCampaigns(ctx context.Context, req *dto.HideCampaignsRequest, metadata *ClientMetadata) (*dto.HideCampaignsResponse, error)
 	UnhideCampaigns(ctx context.Context, req *dto.UnhideCampaignsRequest, metadata *ClientMetadata) (*dto.UnhideCampaignsResponse, error)
 	CloneCampaign(ctx context.Context, req *dto.CloneCampaignRequest, metadata *ClientMetadata) (*dto.CloneCampaignResponse, error)
 	ExportCampaignReport(ctx context.Context, campaignID string) ([]byte, error)
 	ExportCampaignClickReport(ctx context.Context, campaignUUID string) ([]byte, error)
+	GetCampaignVariantStats(ctx context.Context, req *dto.GetCampaignVariantStatsRequest) (*dto.GetCampaignVariantStatsResponse, error)
+	GetCampaignOperatorStats(ctx context.Context, req *dto.GetCampaignOperatorStatsRequest) (*dto.GetCampaignOperatorStatsResponse, error)
+	GetCampaignRetryStats(ctx context.Context, req *dto.GetCampaignRetryStatsRequest) (*dto.GetCampaignRetryStatsResponse, error)
+	ValidateCampaignContent(ctx context.Context, req *dto.ValidateCampaignContentRequest) (*dto.ValidateCampaignContentResponse, error)
 	SendCampaignTestMessage(ctx context.Context, req *dto.SendCampaignTestMessageRequest, metadata *ClientMetadata) (*dto.SendCampaignTestMessageResponse, error)
+	ListCampaignReviews(ctx context.Context, req *dto.ListCampaignReviewsRequest) (*dto.ListCampaignReviewsResponse, error)
+	AddCampaignReviewComment(ctx context.Context, req *dto.AddCampaignReviewCommentRequest, metadata *ClientMetadata) (*dto.AddCampaignReviewCommentResponse, error)
+	GetSendingQuotaUsage(ctx context.Context, customerID uint) (*dto.GetSendingQuotaUsageResponse, error)
+	ExpireUnsentCampaigns(ctx context.Context) (int, error)
 }
 
 // CampaignFlowImpl implements the campaign business flow
@@ -68,15 +81,29 @@ type CampaignFlowImpl struct {
 	pagePriceRepo         repository.PagePriceRepository
 	processedCampaignRepo repository.ProcessedCampaignRepository
 	smsStatusResultRepo   repository.SMSStatusResultRepository
+	smsRetryRepo          repository.SMSRecipientRetryRepository
 	shortLinkClickRepo    repository.ShortLinkClickRepository
-	notifier              services.NotificationService
+	audienceProfileRepo   repository.AudienceProfileRepository
+	tagRepo               repository.TagRepository
+	campaignReviewRepo    repository.CampaignReviewRepository
+	sendingQuotaRepo      repository.CustomerSendingQuotaRepository
+	lineReservationRepo   repository.LineNumberReservationRepository
+	creditLineRepo        repository.CustomerCreditLineRepository
+	postpaidDrawRepo      repository.PostpaidDrawRepository
+	postpaidInvoiceRepo   repository.PostpaidInvoiceRepository
+	delegationRepo        repository.AgencyDelegationRepository
+	notificationRepo      repository.NotificationRepository
+	smsPricing            SMSPricingService
+	jobs                  JobQueue
 	adminConfig           config.AdminConfig
+	localizer             *i18n.Localizer
 	cacheConfig           config.CacheConfig
 	botConfig             config.BotConfig
 	payamSMSConfig        config.PayamSMSConfig
 	baleConfig            config.BaleConfig
 	rubikaConfig          config.RubikaConfig
 	splusConfig           config.SplusConfig
+	kycConfig             config.KYCConfig
 	irHTTPSProxy          string
 	rc                    *redis.Client
 	db                    *gorm.DB
@@ -88,6 +115,9 @@ const (
 	defaultSegmentPriceFactor    = 1.0
 	defaultLineNumberPriceFactor = 1.0
 	undeliveredRefundDelay       = 72 * time.Hour
+	// Campaigns still waiting for approval this long after their schedule
+	// time are expired and refunded
+	unsentCampaignExpiryDelay = 6 * time.Hour
 )
 
 var tehranLoc *time.Location
@@ -112,17 +142,31 @@ func NewCampaignFlow(
 	pagePriceRepo repository.PagePriceRepository,
 	processedCampaignRepo repository.ProcessedCampaignRepository,
 	smsStatusResultRepo repository.SMSStatusResultRepository,
+	smsRetryRepo repository.SMSRecipientRetryRepository,
 	shortLinkClickRepo repository.ShortLinkClickRepository,
+	audienceProfileRepo repository.AudienceProfileRepository,
+	tagRepo repository.TagRepository,
+	campaignReviewRepo repository.CampaignReviewRepository,
+	sendingQuotaRepo repository.CustomerSendingQuotaRepository,
+	lineReservationRepo repository.LineNumberReservationRepository,
+	creditLineRepo repository.CustomerCreditLineRepository,
+	postpaidDrawRepo repository.PostpaidDrawRepository,
+	postpaidInvoiceRepo repository.PostpaidInvoiceRepository,
+	delegationRepo repository.AgencyDelegationRepository,
+	notificationRepo repository.NotificationRepository,
+	smsPricing SMSPricingService,
 	db *gorm.DB,
 	rc *redis.Client,
-	notifier services.NotificationService,
+	jobs JobQueue,
 	adminConfig config.AdminConfig,
+	localizer *i18n.Localizer,
 	cacheConfig config.CacheConfig,
 	botConfig config.BotConfig,
 	payamSMSConfig config.PayamSMSConfig,
 	baleConfig config.BaleConfig,
 	rubikaConfig config.RubikaConfig,
 	splusConfig config.SplusConfig,
+	kycConfig config.KYCConfig,
 	irHTTPSProxy string,
 ) CampaignFlow {
 	return &CampaignFlowImpl{
@@ -142,15 +186,29 @@ func NewCampaignFlow(
 		pagePriceRepo:         pagePriceRepo,
 		processedCampaignRepo: processedCampaignRepo,
 		smsStatusResultRepo:   smsStatusResultRepo,
+		smsRetryRepo:          smsRetryRepo,
 		shortLinkClickRepo:    shortLinkClickRepo,
-		notifier:              notifier,
+		audienceProfileRepo:   audienceProfileRepo,
+		tagRepo:               tagRepo,
+		campaignReviewRepo:    campaignReviewRepo,
+		sendingQuotaRepo:      sendingQuotaRepo,
+		lineReservationRepo:   lineReservationRepo,
+		creditLineRepo:        creditLineRepo,
+		postpaidDrawRepo:      postpaidDrawRepo,
+		postpaidInvoiceRepo:   postpaidInvoiceRepo,
+		delegationRepo:        delegationRepo,
+		notificationRepo:      notificationRepo,
+		smsPricing:            smsPricing,
+		jobs:                  jobs,
 		adminConfig:           adminConfig,
+		localizer:             localizer,
 		cacheConfig:           cacheConfig,
 		botConfig:             botConfig,
 		payamSMSConfig:        payamSMSConfig,
 		baleConfig:            baleConfig,
 		rubikaConfig:          rubikaConfig,
 		splusConfig:           splusConfig,
+		kycConfig:             kycConfig,
 		irHTTPSProxy:          irHTTPSProxy,
 		rc:                    rc,
 		db:                    db,
@@ -159,6 +217,10 @@ func NewCampaignFlow(
 
 // CreateCampaign handles the complete campaign creation process
 func (s *CampaignFlowImpl) CreateCampaign(ctx context.Context, req *dto.CreateCampaignRequest, metadata *ClientMetadata) (*dto.CreateCampaignResponse, error) {
+	if err := s.authorizeDelegation(ctx, req.CustomerID, models.AgencyDelegationPermissionCreateCampaigns, metadata); err != nil {
+		return nil, err
+	}
+
 	// Validate business rules
 	if err := s.validateCreateCampaignRequest(ctx, req); err != nil {
 		return nil, NewBusinessError("CAMPAIGN_VALIDATION_FAILED", "Campaign validation failed", err)
@@ -168,39 +230,14 @@ func (s *CampaignFlowImpl) CreateCampaign(ctx context.Context, req *dto.CreateCa
 	if err != nil {
 		return nil, NewBusinessError("CUSTOMER_LOOKUP_FAILED", "Failed to lookup customer", err)
 	}
-
-	shortLinkDomain, err := sanitizeShortLinkDomain(req.ShortLinkDomain)
-	if err != nil {
-		return nil, NewBusinessError("SHORT_LINK_DOMAIN_INVALID", "Invalid short link domain", err)
-	}
-	req.ShortLinkDomain = shortLinkDomain
-
-	category, job, err := sanitizeCategoryAndJob(customer.AccountType.TypeName, req.Category, req.Job, true)
-	if err != nil {
-		return nil, NewBusinessError("CAMPAIGN_VALIDATION_FAILED", "Campaign validation failed", err)
+	if err := checkCustomerPolicy(customer, CustomerActionCreateCampaign); err != nil {
+		return nil, err
 	}
-	req.Category = category
-	req.Job = job
-
-	sanitizedPlatform, err := sanitizeCampaignPlatform(req.Platform)
-	if err != nil {
-		return nil, NewBusinessError("CAMPAIGN_VALIDATION_FAILED", "Campaign validation failed", err)
+	if err := checkPostpaidStanding(ctx, s.postpaidInvoiceRepo, customer.ID); err != nil {
+		return nil, err
 	}
-	req.Platform = &sanitizedPlatform
-
-	if err := s.ensureCreateCampaignRefs(
-		ctx,
-		req.CustomerID,
-		req.BundleID,
-		req.Phase,
-		req.LineNumber,
-		req.Level3s,
-		sanitizedPlatform,
-		req.MediaUUID,
-		req.TargetAudienceExcelFileUUID,
-		req.PlatformSettingsID,
-	); err != nil {
-		return nil, NewBusinessError("CAMPAIGN_VALIDATION_FAILED", "Campaign validation failed", err)
+	if err := s.prepareCreateCampaign(ctx, req, customer); err != nil {
+		return nil, err
 	}
 
 	// Use transaction for atomicity
@@ -235,12 +272,58 @@ func (s *CampaignFlowImpl) CreateCampaign(ctx context.Context, req *dto.CreateCa
 		Status:    string(campaign.Status),
 		CreatedAt: campaign.CreatedAt.Format(time.RFC3339),
 	}
+	resp.Encoding, resp.Parts = campaignSpecSMSSegments(campaign.Spec)
 
 	return resp, nil
 }
 
+// prepareCreateCampaign normalizes the fields of a validated request for
+// customer and checks the bundle, line number, segments, media and platform
+// settings it refers to
+func (s *CampaignFlowImpl) prepareCreateCampaign(ctx context.Context, req *dto.CreateCampaignRequest, customer models.Customer) error {
+	shortLinkDomain, err := sanitizeShortLinkDomain(req.ShortLinkDomain)
+	if err != nil {
+		return NewBusinessError("SHORT_LINK_DOMAIN_INVALID", "Invalid short link domain", err)
+	}
+	req.ShortLinkDomain = shortLinkDomain
+
+	category, job, err := sanitizeCategoryAndJob(customer.AccountType.TypeName, req.Category, req.Job, true)
+	if err != nil {
+		return NewBusinessError("CAMPAIGN_VALIDATION_FAILED", "Campaign validation failed", err)
+	}
+	req.Category = category
+	req.Job = job
+
+	sanitizedPlatform, err := sanitizeCampaignPlatform(req.Platform)
+	if err != nil {
+		return NewBusinessError("CAMPAIGN_VALIDATION_FAILED", "Campaign validation failed", err)
+	}
+	req.Platform = &sanitizedPlatform
+
+	if err := s.ensureCreateCampaignRefs(
+		ctx,
+		req.CustomerID,
+		req.BundleID,
+		req.Phase,
+		req.LineNumber,
+		req.Level3s,
+		sanitizedPlatform,
+		req.MediaUUID,
+		req.TargetAudienceExcelFileUUID,
+		req.PlatformSettingsID,
+	); err != nil {
+		return NewBusinessError("CAMPAIGN_VALIDATION_FAILED", "Campaign validation failed", err)
+	}
+
+	return nil
+}
+
 // UpdateCampaign handles the campaign update process
 func (s *CampaignFlowImpl) UpdateCampaign(ctx context.Context, req *dto.UpdateCampaignRequest, metadata *ClientMetadata) (*dto.UpdateCampaignResponse, error) {
+	if err := s.authorizeDelegation(ctx, req.CustomerID, models.AgencyDelegationPermissionCreateCampaigns, metadata); err != nil {
+		return nil, err
+	}
+
 	// Validate business rules
 	if err := s.validateUpdateCampaignRequest(req); err != nil {
 		return nil, NewBusinessError("CAMPAIGN_UPDATE_VALIDATION_FAILED", "Campaign update validation failed", err)
@@ -366,18 +449,13 @@ func (s *CampaignFlowImpl) UpdateCampaign(ctx context.Context, req *dto.UpdateCa
 	// --- Finalize path ---
 	// Pre-compute all pricing inputs outside the DB transaction so the
 	// transaction that touches money is as short as possible.
+	if err := checkCustomerPolicy(customer, CustomerActionCreateCampaign); err != nil {
+		return nil, err
+	}
 	if err := s.canFinalizeCampaign(ctx, campaign, customer); err != nil {
 		return nil, NewBusinessError("CAMPAIGN_FINALIZE_NOT_ALLOWED", "Campaign cannot be finalized", err)
 	}
 
-	lineNumberPriceFactor := defaultLineNumberPriceFactor
-	if campaign.Spec.Platform == models.CampaignPlatformSMS {
-		lineNumberPriceFactor, err = s.fetchLineNumberPriceFactor(ctx, campaign.Spec.LineNumber)
-		if err != nil {
-			return nil, NewBusinessError("LINE_NUMBER_PRICE_FACTOR_FETCH_FAILED", "Failed to fetch line number price factor", err)
-		}
-	}
-
 	segmentPriceFactor := defaultSegmentPriceFactor
 	if !usingTargetAudienceExcelFile {
 		segmentPriceFactor, err = s.fetchSegmentPriceFactor(ctx, campaign.Spec.Level3s, sanitizedPlatform)
@@ -401,6 +479,23 @@ func (s *CampaignFlowImpl) UpdateCampaign(ctx context.Context, req *dto.UpdateCa
 		return nil, NewBusinessError("PAGE_PRICE_NOT_FOUND", "Page price not found", ErrPagePriceNotFound)
 	}
 
+	numPages := s.calculateParts(
+		campaign.Spec.Content,
+		campaign.Spec.AdLink,
+		campaign.Spec.ShortLinkDomain,
+		sanitizedPlatform,
+	)
+
+	lineNumberPriceFactor := defaultLineNumberPriceFactor
+	var smsQuote *SMSQuote
+	if campaign.Spec.Platform == models.CampaignPlatformSMS {
+		smsQuote, err = s.quoteCustomerSMS(ctx, campaign.CustomerID, campaign.Spec.LineNumber, numPages, pbp.Price)
+		if err != nil {
+			return nil, err
+		}
+		lineNumberPriceFactor = smsQuote.LineNumberFactor
+	}
+
 	cost, err := s.CalculateCampaignCost(ctx, &dto.CalculateCampaignCostRequest{
 		CampaignID: campaign.ID,
 		CustomerID: campaign.CustomerID,
@@ -408,17 +503,26 @@ func (s *CampaignFlowImpl) UpdateCampaign(ctx context.Context, req *dto.UpdateCa
 	if err != nil {
 		return nil, NewBusinessError("CAMPAIGN_COST_CALCULATION_FAILED", "Failed to calculate campaign cost", err)
 	}
+	if err := s.checkSendingQuota(ctx, &customer, campaign, cost.NumTargetAudience); err != nil {
+		return nil, err
+	}
+	if err := checkPostpaidStanding(ctx, s.postpaidInvoiceRepo, campaign.CustomerID); err != nil {
+		return nil, err
+	}
 
-	numPages := s.calculateParts(
-		campaign.Spec.Content,
-		campaign.Spec.AdLink,
-		campaign.Spec.ShortLinkDomain,
-		sanitizedPlatform,
-	)
+	// A campaign sent back by a reviewer goes through the same finalize path;
+	// the resubmission is recorded in its review history.
+	resubmitted := campaign.Status == models.CampaignStatusChangesRequested
 
 	// Phase 2: atomic financial operations only — keep this transaction as
 	// short as possible (no network calls, no heavy computation).
-	err = repository.WithTransaction(ctx, s.db, func(txCtx context.Context) error {
+	err = withVersionRetry(ctx, s.db, func(txCtx context.Context) error {
+		if resubmitted {
+			if err := s.saveCustomerCampaignReview(txCtx, campaign.ID, customer.ID, models.CampaignReviewActionResubmitted, req.ReviewComment); err != nil {
+				return err
+			}
+		}
+
 		campaign.Status = models.CampaignStatusWaitingForApproval
 		campaign.NumAudience = utils.ToPtr(cost.NumTargetAudience)
 		campaign.UpdatedAt = utils.ToPtr(utils.UTCNow())
@@ -439,7 +543,16 @@ func (s *CampaignFlowImpl) UpdateCampaign(ctx context.Context, req *dto.UpdateCa
 
 		availableBalance := latestBalance.FreeBalance + latestBalance.CreditBalance
 		if availableBalance < cost.TotalCost {
-			return ErrInsufficientFunds
+			// Sandbox campaigns are funded with test money only
+			if customer.InSandbox() {
+				return ErrInsufficientFunds
+			}
+			// Customers with a credit line fund the shortfall from it
+			latestBalance, err = drawPostpaidCredit(txCtx, s.creditLineRepo, s.postpaidDrawRepo, s.walletRepo, s.balanceSnapshotRepo, s.transactionRepo,
+				&wallet, latestBalance, cost.TotalCost-availableBalance, campaign.ID)
+			if err != nil {
+				return err
+			}
 		}
 
 		newFreeBalance := latestBalance.FreeBalance
@@ -468,6 +581,10 @@ func (s *CampaignFlowImpl) UpdateCampaign(ctx context.Context, req *dto.UpdateCa
 			"line_number_price_factor": lineNumberPriceFactor,
 			"segment_price_factor":     segmentPriceFactor,
 		}
+		if smsQuote != nil && smsQuote.TariffID != nil {
+			meta["sms_tariff_id"] = *smsQuote.TariffID
+			meta["sms_price_per_part"] = *smsQuote.PricePerPart
+		}
 		metaBytes, _ := json.Marshal(meta)
 
 		corrID := uuid.New()
@@ -490,6 +607,9 @@ func (s *CampaignFlowImpl) UpdateCampaign(ctx context.Context, req *dto.UpdateCa
 			CreatedAt:          utils.UTCNow(),
 			UpdatedAt:          utils.UTCNow(),
 		}
+		if err := s.walletRepo.AdvanceVersion(txCtx, &wallet); err != nil {
+			return err
+		}
 		if err := s.balanceSnapshotRepo.Save(txCtx, newSnapshot); err != nil {
 			return err
 		}
@@ -519,7 +639,11 @@ func (s *CampaignFlowImpl) UpdateCampaign(ctx context.Context, req *dto.UpdateCa
 			CreatedAt:     utils.UTCNow(),
 			UpdatedAt:     utils.UTCNow(),
 		}
-		return s.transactionRepo.Save(txCtx, freezeTx)
+		if err := s.transactionRepo.Save(txCtx, freezeTx); err != nil {
+			return err
+		}
+		notifyLowBalance(txCtx, s.notificationRepo, customer.ID, latestBalance.FreeBalance, newFreeBalance)
+		return nil
 	})
 
 	if err != nil {
@@ -528,27 +652,24 @@ func (s *CampaignFlowImpl) UpdateCampaign(ctx context.Context, req *dto.UpdateCa
 		return nil, NewBusinessError("CAMPAIGN_UPDATE_FAILED", "Campaign update failed", err)
 	}
 
-	// Send admin notifications after the transaction commits so network
-	// latency does not extend the transaction lifetime.
-	if s.notifier != nil {
-		go func() {
-			notifyCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-			defer cancel()
-
-			subject := campaign.UUID.String()
-			if campaign.Spec.Title != nil {
-				subject = *campaign.Spec.Title
-			}
-			msg := fmt.Sprintf("New campaign pending approval:\n%s", subject)
-			for _, mobile := range s.adminConfig.ActiveMobiles() {
-				_ = s.notifier.SendSMS(notifyCtx, mobile, msg, nil)
-			}
-		}()
+	// Queue admin notifications after the transaction commits
+	subject := campaign.UUID.String()
+	if campaign.Spec.Title != nil {
+		subject = *campaign.Spec.Title
+	}
+	notice := s.localizer.Admin("admin.campaign_pending_approval", i18n.Args{"Title": subject})
+	if resubmitted {
+		notice = s.localizer.Admin("admin.campaign_resubmitted", i18n.Args{"Title": subject})
 	}
+	enqueueSMS(ctx, s.jobs, s.adminConfig.ActiveMobiles(), notice)
 
 	// Log successful update
 	msg := fmt.Sprintf("Campaign updated successfully: %d", campaign.ID)
 	_ = s.createAuditLog(ctx, &customer, models.AuditActionCampaignUpdated, msg, true, nil, metadata)
+	if resubmitted {
+		msg := fmt.Sprintf("Campaign resubmitted for approval: %d", campaign.ID)
+		_ = s.createAuditLog(ctx, &customer, models.AuditActionCampaignResubmitted, msg, true, nil, metadata)
+	}
 
 	// Build resp
 	resp := &dto.UpdateCampaignResponse{
@@ -563,11 +684,17 @@ func (s *CampaignFlowImpl) CloneCampaign(ctx context.Context, req *dto.CloneCamp
 	if req == nil || strings.TrimSpace(req.UUID) == "" {
 		return nil, NewBusinessError("CLONE_CAMPAIGN_VALIDATION_FAILED", "Campaign UUID is required", ErrCampaignUUIDRequired)
 	}
+	if err := s.authorizeDelegation(ctx, req.CustomerID, models.AgencyDelegationPermissionCreateCampaigns, metadata); err != nil {
+		return nil, err
+	}
 
 	customer, err := getCustomer(ctx, s.customerRepo, req.CustomerID)
 	if err != nil {
 		return nil, NewBusinessError("CUSTOMER_LOOKUP_FAILED", "Failed to lookup customer", err)
 	}
+	if err := checkCustomerPolicy(customer, CustomerActionCreateCampaign); err != nil {
+		return nil, err
+	}
 
 	src, err := getCampaign(ctx, s.campaignRepo, req.UUID, req.CustomerID)
 	if err != nil {
@@ -576,10 +703,15 @@ func (s *CampaignFlowImpl) CloneCampaign(ctx context.Context, req *dto.CloneCamp
 
 	ensureCampaignSpecDefaults(&src.Spec)
 	src.Spec.ScheduleAt = nil // Clear schedule to avoid cloning campaigns with past schedule times
+	if req.Title != nil && strings.TrimSpace(*req.Title) != "" {
+		title := strings.TrimSpace(*req.Title)
+		src.Spec.Title = &title
+	}
 
 	clone := models.Campaign{
 		UUID:        uuid.New(),
 		CustomerID:  src.CustomerID,
+		IsSandbox:   customer.InSandbox(),
 		Status:      models.CampaignStatusInitiated,
 		Spec:        src.Spec,
 		Comment:     nil,
@@ -608,19 +740,33 @@ func (s *CampaignFlowImpl) CloneCampaign(ctx context.Context, req *dto.CloneCamp
 }
 
 // CancelCampaign allows a customer to cancel their own campaign and refunds budget according to current campaign status.
+// A running or paused campaign stops before its next send batch and only the unsent share of the budget is refunded.
 func (s *CampaignFlowImpl) CancelCampaign(ctx context.Context, req *dto.CancelCampaignRequest, metadata *ClientMetadata) (*dto.CancelCampaignResponse, error) {
 	// NOTE: Idempotency
 	if req == nil || req.CampaignID == 0 {
 		return nil, NewBusinessError("CANCEL_CAMPAIGN_VALIDATION_FAILED", "campaign_id is required", ErrCampaignNotFound)
 	}
+	if err := s.authorizeDelegation(ctx, req.CustomerID, models.AgencyDelegationPermissionManageCampaigns, metadata); err != nil {
+		return nil, err
+	}
 
 	if !s.tryAcquireFlowLock(ctx, fmt.Sprintf("cancel_campaign:%d", req.CustomerID), 20*time.Second) {
 		return nil, NewBusinessError("CANCEL_CAMPAIGN_BUSY", "cancel campaign request is already in progress", ErrInvalidState)
 	}
 
-	var campaign *models.Campaign
+	var (
+		campaign       *models.Campaign
+		customer       models.Customer
+		stopped        bool
+		sentBeforeStop uint64
+		refundAmount   uint64
+	)
+
+	err := withVersionRetry(ctx, s.db, func(txCtx context.Context) error {
+		if err := lockCampaignExecution(txCtx, req.CampaignID); err != nil {
+			return err
+		}
 
-	err := repository.WithTransaction(ctx, s.db, func(txCtx context.Context) error {
 		var err error
 		campaign, err = s.campaignRepo.ByID(txCtx, req.CampaignID)
 		if err != nil {
@@ -636,7 +782,7 @@ func (s *CampaignFlowImpl) CancelCampaign(ctx context.Context, req *dto.CancelCa
 			return ErrCampaignNotWaitingForApproval
 		}
 
-		customer, err := getCustomer(txCtx, s.customerRepo, campaign.CustomerID)
+		customer, err = getCustomer(txCtx, s.customerRepo, campaign.CustomerID)
 		if err != nil {
 			return err
 		}
@@ -727,6 +873,9 @@ func (s *CampaignFlowImpl) CancelCampaign(ctx context.Context, req *dto.CancelCa
 				Description:        fmt.Sprintf("Refund reserved budget for cancelled campaign %d", campaign.ID),
 				Metadata:           metaBytes,
 			}
+			if err := s.walletRepo.AdvanceVersion(txCtx, &wallet); err != nil {
+				return err
+			}
 			if err := s.balanceSnapshotRepo.Save(txCtx, newSnap); err != nil {
 				return err
 			}
@@ -859,6 +1008,9 @@ func (s *CampaignFlowImpl) CancelCampaign(ctx context.Context, req *dto.CancelCa
 				Description:        fmt.Sprintf("Refund spent budget for cancelled campaign %d after approval", campaign.ID),
 				Metadata:           metaBytes,
 			}
+			if err := s.walletRepo.AdvanceVersion(txCtx, &wallet); err != nil {
+				return err
+			}
 			if err := s.balanceSnapshotRepo.Save(txCtx, newSnap); err != nil {
 				return err
 			}
@@ -889,6 +1041,24 @@ func (s *CampaignFlowImpl) CancelCampaign(ctx context.Context, req *dto.CancelCa
 			if err := s.transactionRepo.Save(txCtx, refundTx); err != nil {
 				return err
 			}
+		case models.CampaignStatusRunning, models.CampaignStatusPaused:
+			sentBeforeStop, refundAmount, err = stopCampaignExecution(
+				txCtx,
+				s.processedCampaignRepo,
+				s.walletRepo,
+				s.balanceSnapshotRepo,
+				s.transactionRepo,
+				campaign,
+				"campaign_cancel",
+				req.Comment,
+			)
+			if err != nil {
+				return err
+			}
+			stopped = true
+		case models.CampaignStatusChangesRequested:
+			// The reserved budget was already refunded when the reviewer
+			// requested changes.
 		default:
 			return ErrCampaignNotWaitingForApproval
 		}
@@ -909,8 +1079,19 @@ func (s *CampaignFlowImpl) CancelCampaign(ctx context.Context, req *dto.CancelCa
 		return nil, NewBusinessError("CANCEL_CAMPAIGN_FAILED", "Failed to cancel campaign", err)
 	}
 
+	if !stopped {
+		return &dto.CancelCampaignResponse{
+			Message: "Campaign cancelled successfully",
+		}, nil
+	}
+
+	msg := fmt.Sprintf("Campaign %d stopped after %d messages, refunded %d", campaign.ID, sentBeforeStop, refundAmount)
+	_ = s.createAuditLog(ctx, &customer, models.AuditActionCampaignExecutionStopped, msg, true, nil, metadata)
+
 	return &dto.CancelCampaignResponse{
-		Message: "Campaign cancelled successfully",
+		Message:        "Campaign stopped and unsent budget refunded successfully",
+		SentBeforeStop: &sentBeforeStop,
+		RefundAmount:   &refundAmount,
 	}, nil
 }
 
@@ -1255,12 +1436,14 @@ func (s *CampaignFlowImpl) CalculateCampaignCost(ctx context.Context, req *dto.C
 		}
 	}
 
-	return &dto.CalculateCampaignCostResponse{
+	resp := &dto.CalculateCampaignCostResponse{
 		Message:           "Campaign cost calculated successfully",
 		TotalCost:         totalCost,
 		NumTargetAudience: numTargetAudience,
 		MaxTargetAudience: availableCapacity,
-	}, nil
+	}
+	resp.Encoding, resp.Parts = campaignSpecSMSSegments(campaign.Spec)
+	return resp, nil
 }
 
 // CalculateCampaignCostV2 calculates required cost for desired num_messages
@@ -1290,12 +1473,14 @@ func (s *CampaignFlowImpl) CalculateCampaignCostV2(ctx context.Context, req *dto
 		}
 	}
 
-	return &dto.CalculateCampaignCostResponse{
+	resp := &dto.CalculateCampaignCostResponse{
 		Message:           "Campaign cost calculated successfully",
 		TotalCost:         totalCost,
 		NumTargetAudience: numTargetAudience,
 		MaxTargetAudience: availableCapacity,
-	}, nil
+	}
+	resp.Encoding, resp.Parts = campaignSpecSMSSegments(campaign.Spec)
+	return resp, nil
 }
 
 func (s *CampaignFlowImpl) computeCostInputs(
@@ -1325,27 +1510,18 @@ func (s *CampaignFlowImpl) computeCostInputs(
 		platform,
 	)
 
-	lineNumberFactor := defaultLineNumberPriceFactor
-	if platform == models.CampaignPlatformSMS && campaign.Spec.LineNumber != nil && strings.TrimSpace(*campaign.Spec.LineNumber) != "" {
-		var err error
-		lineNumberFactor, err = s.fetchLineNumberPriceFactor(ctx, campaign.Spec.LineNumber)
-		if err != nil {
-			return 0, 0, NewBusinessError("LINE_NUMBER_PRICE_FACTOR_FETCH_FAILED", "Failed to fetch line number price factor", err)
-		}
-	}
-
 	segmentPriceFactor := defaultSegmentPriceFactor
 	if len(campaign.Spec.Level3s) > 0 && !usingTargetAudienceExcelFile {
 		maxFactor, err := s.fetchSegmentPriceFactor(ctx, campaign.Spec.Level3s, platform)
 		if err != nil {
 			if errors.Is(err, ErrSegmentPriceFactorNotFound) {
-				s.notifyMissingSegmentPriceFactor(campaign.Spec.Level3s)
+				s.notifyMissingSegmentPriceFactor(ctx, campaign.Spec.Level3s)
 				return 0, 0, NewBusinessError("SEGMENT_PRICE_FACTOR_NOT_FOUND", "Segment price factor not found for provided level3 options", ErrSegmentPriceFactorNotFound)
 			}
 			return 0, 0, NewBusinessError("SEGMENT_PRICE_FACTOR_FETCH_FAILED", "Failed to fetch segment price factors", err)
 		}
 		if maxFactor == 0 {
-			s.notifyMissingSegmentPriceFactor(campaign.Spec.Level3s)
+			s.notifyMissingSegmentPriceFactor(ctx, campaign.Spec.Level3s)
 			return 0, 0, NewBusinessError("SEGMENT_PRICE_FACTOR_NOT_FOUND", "Segment price factor not found for provided level3 options", ErrSegmentPriceFactorNotFound)
 		}
 		segmentPriceFactor = maxFactor
@@ -1367,12 +1543,14 @@ func (s *CampaignFlowImpl) computeCostInputs(
 	if pp == nil {
 		return 0, 0, NewBusinessError("PAGE_PRICE_NOT_FOUND", "Page price not found for platform "+platform, ErrPagePriceNotFound)
 	}
-	pagePrice := float64(pp.Price)
-	numPages := float64(numParts)
 	if platform == models.CampaignPlatformSMS {
-		pricePerMsg = pbp.Price*uint64(lineNumberFactor*numPages) + uint64(segmentPriceFactor*pagePrice)
+		quote, err := s.quoteCustomerSMS(ctx, campaign.CustomerID, campaign.Spec.LineNumber, numParts, pbp.Price)
+		if err != nil {
+			return 0, 0, err
+		}
+		pricePerMsg = pricing.MessagePrice(quote.PartsCost, segmentPriceFactor, pp.Price)
 	} else {
-		pricePerMsg = pbp.Price*uint64(1*1) + uint64(segmentPriceFactor*pagePrice)
+		pricePerMsg = pricing.MessagePrice(pbp.Price, segmentPriceFactor, pp.Price)
 	}
 
 	// Calculate campaign capacity (target audience size)
@@ -1387,22 +1565,77 @@ func (s *CampaignFlowImpl) computeCostInputs(
 }
 
 func (s *CampaignFlowImpl) fetchLineNumberPriceFactor(ctx context.Context, lineNumber *string) (float64, error) {
+	ln, err := s.fetchActiveLineNumber(ctx, lineNumber)
+	if err != nil {
+		return 0, err
+	}
+	return ln.PriceFactor, nil
+}
+
+func (s *CampaignFlowImpl) fetchActiveLineNumber(ctx context.Context, lineNumber *string) (*models.LineNumber, error) {
 	if lineNumber == nil || strings.TrimSpace(*lineNumber) == "" {
-		return 0, ErrLineNumberNotFound
+		return nil, ErrLineNumberNotFound
 	}
 
 	ln, err := s.lineNumberRepo.ByValue(ctx, *lineNumber)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 	if ln == nil {
-		return 0, ErrLineNumberNotFound
+		return nil, ErrLineNumberNotFound
 	}
 	if !utils.IsTrue(ln.IsActive) {
-		return 0, ErrLineNumberNotActive
+		return nil, ErrLineNumberNotActive
 	}
 
-	return ln.PriceFactor, nil
+	return ln, nil
+}
+
+// ensureLineNumberNotReserved rejects a line number currently reserved by
+// another customer. Campaigns already finalized before the reservation was
+// made are not affected.
+func (s *CampaignFlowImpl) ensureLineNumberNotReserved(ctx context.Context, customerID uint, lineNumber *string) error {
+	ln, err := s.fetchActiveLineNumber(ctx, lineNumber)
+	if err != nil {
+		return err
+	}
+	reservation, err := s.lineReservationRepo.ActiveByLineNumberID(ctx, ln.ID, utils.UTCNow())
+	if err != nil {
+		return err
+	}
+	if reservation != nil && reservation.CustomerID != customerID {
+		return ErrLineNumberReserved
+	}
+	return nil
+}
+
+// quoteCustomerSMS prices all parts of one SMS sent from lineNumber on behalf
+// of the customer, whose account type selects the tariff row.
+func (s *CampaignFlowImpl) quoteCustomerSMS(ctx context.Context, customerID uint, lineNumber *string, parts uint64, basePrice uint64) (*SMSQuote, error) {
+	ln, err := s.fetchActiveLineNumber(ctx, lineNumber)
+	if err != nil {
+		return nil, NewBusinessError("LINE_NUMBER_PRICE_FACTOR_FETCH_FAILED", "Failed to fetch line number price factor", err)
+	}
+
+	accountType := ""
+	customer, err := s.customerRepo.ByID(ctx, customerID)
+	if err != nil {
+		return nil, NewBusinessError("CUSTOMER_LOOKUP_FAILED", "Failed to lookup customer", err)
+	}
+	if customer != nil {
+		accountType = customer.AccountType.TypeName
+	}
+
+	quote, err := s.smsPricing.QuoteSMS(ctx, SMSQuoteRequest{
+		LineNumber:  *ln,
+		AccountType: accountType,
+		Parts:       parts,
+		BasePrice:   basePrice,
+	})
+	if err != nil {
+		return nil, NewBusinessError("SMS_TARIFF_FETCH_FAILED", "Failed to resolve SMS tariff", err)
+	}
+	return quote, nil
 }
 
 func (s *CampaignFlowImpl) fetchSegmentPriceFactor(ctx context.Context, level3s []string, platform string) (float64, error) {
@@ -1423,16 +1656,9 @@ func (s *CampaignFlowImpl) fetchSegmentPriceFactor(ctx context.Context, level3s
 	return maxFactor, nil
 }
 
-func (s *CampaignFlowImpl) notifyMissingSegmentPriceFactor(level3s []string) {
-	if s.notifier == nil {
-		return
-	}
-	msg := fmt.Sprintf("Segment price factor missing for level3: %s", strings.Join(level3s, ","))
-	go func() {
-		for _, mobile := range s.adminConfig.ActiveMobiles() {
-			_ = s.notifier.SendSMS(context.Background(), mobile, msg, nil)
-		}
-	}()
+func (s *CampaignFlowImpl) notifyMissingSegmentPriceFactor(ctx context.Context, level3s []string) {
+	msg := s.localizer.Admin("admin.segment_price_factor_missing", i18n.Args{"Level3s": strings.Join(level3s, ",")})
+	enqueueSMS(context.WithoutCancel(ctx), s.jobs, s.adminConfig.ActiveMobiles(), msg)
 }
 
 // campaignDisplayEnrichments holds computed pricing and settings data for building a GetCampaignResponse.
@@ -1706,6 +1932,7 @@ func buildCampaignResponse(c *models.Campaign, e campaignDisplayEnrichments) dto
 		ID:                          c.ID,
 		UUID:                        c.UUID.String(),
 		Hidden:                      c.Hidden,
+		IsSandbox:                   c.IsSandbox,
 		Status:                      c.Status.String(),
 		CreatedAt:                   c.CreatedAt,
 		UpdatedAt:                   c.UpdatedAt,
@@ -1738,6 +1965,13 @@ func buildCampaignResponse(c *models.Campaign, e campaignDisplayEnrichments) dto
 		Phase:                       campaignPhasePtr(c.Phase),
 		AudienceGrades:              campaignAudienceGradesOrDefault(c.Spec.AudienceGrades),
 		TargetAudienceExcelFileUUID: c.Spec.TargetAudienceExcelFileUUID,
+		Recurrence:                  toCampaignRecurrenceSpec(c.Spec.Recurrence),
+		ParentCampaignID:            c.ParentCampaignID,
+		OccurrenceNumber:            c.OccurrenceNumber,
+		NextOccurrenceAt:            c.NextOccurrenceAt,
+		ExecutionStoppedAt:          c.ExecutionStoppedAt,
+		SentBeforeStop:              c.SentBeforeStop,
+		Variants:                    toCampaignContentVariantSpecs(c.Spec.Variants),
 	}
 }
 
@@ -1750,18 +1984,17 @@ func (s *CampaignFlowImpl) ListCampaigns(ctx context.Context, req *dto.ListCampa
 		}
 	}()
 
+	// Agencies acting for the customer see their campaigns under any grant
+	if err = s.authorizeDelegation(ctx, req.CustomerID, "", metadata); err != nil {
+		return nil, err
+	}
+
 	// Validate customer
 	_, err = getCustomer(ctx, s.customerRepo, req.CustomerID)
 	if err != nil {
 		return nil, err
 	}
 
-	// if s.tryAcquireFlowLock(ctx, fmt.Sprintf("list_campaigns_expire:%d", req.CustomerID), 20*time.Second) {
-	// 	if err := s.expireCustomerCampaigns(ctx, req.CustomerID); err != nil {
-	// 		return nil, err
-	// 	}
-	// }
-
 	if s.tryAcquireFlowLock(ctx, fmt.Sprintf("list_campaigns_reconcile_refund:%d", req.CustomerID), 20*time.Second) {
 		if err := s.reconcileUndeliveredCampaignRefunds(ctx, req.CustomerID); err != nil {
 			return nil, err
@@ -1992,6 +2225,12 @@ func (s *CampaignFlowImpl) validateCreateCampaignRequest(ctx context.Context, re
 	if err := validateCampaignPhaseInput(req.Phase, true); err != nil {
 		return err
 	}
+	if _, err := buildCampaignRecurrence(req.Recurrence, req.ScheduleAt); err != nil {
+		return err
+	}
+	if _, err := buildCampaignVariants(req.Variants); err != nil {
+		return err
+	}
 	if req.Title == nil || (req.Title != nil && *req.Title == "") {
 		return ErrCampaignTitleRequired
 	}
@@ -2189,6 +2428,17 @@ func (s *CampaignFlowImpl) createCampaign(ctx context.Context, req *dto.CreateCa
 		spec.Budget = req.Budget
 	}
 	spec.AudienceGrades = campaignAudienceGradesOrDefault(req.AudienceGrades)
+	spec.Recurrence, err = buildCampaignRecurrence(req.Recurrence, req.ScheduleAt)
+	if err != nil {
+		return nil, err
+	}
+	spec.Variants, err = buildCampaignVariants(req.Variants)
+	if err != nil {
+		return nil, err
+	}
+	if len(spec.Variants) > 0 {
+		spec.Content = &spec.Variants[0].Content
+	}
 
 	uid := uuid.New()
 
@@ -2196,6 +2446,7 @@ func (s *CampaignFlowImpl) createCampaign(ctx context.Context, req *dto.CreateCa
 	err = s.campaignRepo.Save(ctx, &models.Campaign{
 		UUID:       uid,
 		CustomerID: customer.ID,
+		IsSandbox:  customer.InSandbox(),
 		Status:     models.CampaignStatusInitiated,
 		Spec:       spec,
 		BundleID:   req.BundleID,
@@ -2272,6 +2523,9 @@ func (s *CampaignFlowImpl) ensureCreateCampaignRefs(
 		if err != nil {
 			return err
 		}
+		if err := s.ensureLineNumberNotReserved(ctx, customerID, lineNumber); err != nil {
+			return err
+		}
 	}
 
 	usingTargetAudienceFromExcelFile := targetAudienceExcelFileUUID != nil && strings.TrimSpace(*targetAudienceExcelFileUUID) != ""
@@ -2393,6 +2647,9 @@ func (s *CampaignFlowImpl) ensureUpdateCampaignRefs(
 		if err != nil {
 			return err
 		}
+		if err := s.ensureLineNumberNotReserved(ctx, customerID, lineNumber); err != nil {
+			return err
+		}
 	}
 
 	if len(level3s) > 0 && !usingTargetAudienceExcelFile {
@@ -2452,7 +2709,8 @@ func (s *CampaignFlowImpl) validateUpdateCampaignRequest(req *dto.UpdateCampaign
 		req.AdLink != nil || req.Content != nil ||
 		req.ScheduleAt != nil || req.LineNumber != nil || req.Budget != nil || req.ShortLinkDomain != nil ||
 		req.Category != nil || req.Job != nil ||
-		req.MediaUUID != nil || req.PlatformSettingsID != nil || req.Platform != nil
+		req.MediaUUID != nil || req.PlatformSettingsID != nil || req.Platform != nil ||
+		req.Recurrence != nil || req.Variants != nil
 
 	if !hasUpdateFields {
 		return ErrCampaignUpdateRequired
@@ -2477,6 +2735,12 @@ func (s *CampaignFlowImpl) validateUpdateCampaignRequest(req *dto.UpdateCampaign
 	if err := validateCampaignPhaseInput(req.Phase, false); err != nil {
 		return err
 	}
+	if _, err := buildCampaignRecurrence(req.Recurrence, req.ScheduleAt); err != nil {
+		return err
+	}
+	if _, err := buildCampaignVariants(req.Variants); err != nil {
+		return err
+	}
 
 	// if req.ScheduleAt != nil && !req.ScheduleAt.IsZero() {
 	// 	if !isScheduleWithinTehranWindow(*req.ScheduleAt) {
@@ -2541,9 +2805,20 @@ func (s *CampaignFlowImpl) canFinalizeCampaign(ctx context.Context, campaign mod
 	if !isScheduleWithinTehranWindow(*campaign.Spec.ScheduleAt) {
 		return ErrScheduleTimeOutsideWindow
 	}
+	if campaign.Spec.Recurrence != nil {
+		if err := validateCampaignRecurrence(campaign.Spec.Recurrence, campaign.Spec.ScheduleAt); err != nil {
+			return err
+		}
+	}
 	if campaign.Spec.Platform == models.CampaignPlatformSMS && (campaign.Spec.LineNumber == nil || *campaign.Spec.LineNumber == "") {
 		return ErrCampaignLineNumberRequired
 	}
+	if err := s.validateCampaignVariants(campaign.Spec); err != nil {
+		return err
+	}
+	if err := validateCampaignPersonalization(campaign.Spec); err != nil {
+		return err
+	}
 	if campaign.Spec.Budget == nil || *campaign.Spec.Budget <= 0 {
 		return ErrCampaignBudgetRequired
 	}
@@ -2657,6 +2932,19 @@ func (s *CampaignFlowImpl) updateCampaign(ctx context.Context, req *dto.UpdateCa
 	if req.AudienceGrades != nil {
 		spec.AudienceGrades = campaignAudienceGradesOrDefault(req.AudienceGrades)
 	}
+	rec, err := buildCampaignRecurrence(req.Recurrence, req.ScheduleAt)
+	if err != nil {
+		return err
+	}
+	spec.Recurrence = rec
+	variants, err := buildCampaignVariants(req.Variants)
+	if err != nil {
+		return err
+	}
+	spec.Variants = variants
+	if len(spec.Variants) > 0 {
+		spec.Content = &spec.Variants[0].Content
+	}
 	ensureCampaignSpecDefaults(&spec)
 
 	// Update the campaign spec
@@ -2668,11 +2956,15 @@ func (s *CampaignFlowImpl) updateCampaign(ctx context.Context, req *dto.UpdateCa
 	if req.Phase != nil {
 		existingCampaign.Phase = campaignPhaseOrDefault(req.Phase)
 	}
-	existingCampaign.Status = models.CampaignStatusInProgress
+	// A campaign sent back for revision keeps its status until it is
+	// resubmitted so the customer still sees the requested changes.
+	if existingCampaign.Status != models.CampaignStatusChangesRequested {
+		existingCampaign.Status = models.CampaignStatusInProgress
+	}
 	existingCampaign.UpdatedAt = utils.ToPtr(utils.UTCNow())
 
 	// Save to database
-	err := s.campaignRepo.Update(ctx, *existingCampaign)
+	err = s.campaignRepo.Update(ctx, *existingCampaign)
 	if err != nil {
 		return err
 	}
@@ -2680,175 +2972,191 @@ func (s *CampaignFlowImpl) updateCampaign(ctx context.Context, req *dto.UpdateCa
 	return nil
 }
 
-func (s *CampaignFlowImpl) expireCustomerCampaigns(ctx context.Context, customerID uint) error {
+// ExpireUnsentCampaigns expires campaigns still waiting for approval past
+// their schedule time and returns their reserved budget to the free and
+// credit balances it was taken from. It returns how many were expired.
+func (s *CampaignFlowImpl) ExpireUnsentCampaigns(ctx context.Context) (int, error) {
 	// NOTE: Idempotency
-	cutoff := utils.UTCNow().Add(-6 * time.Hour)
+	cutoff := utils.UTCNow().Add(-unsentCampaignExpiryDelay)
 
 	st := models.CampaignStatusWaitingForApproval
 	rows, err := s.campaignRepo.ByFilter(ctx, models.CampaignFilter{
-		CustomerID: &customerID,
-		Status:     &st,
-	}, "", 0, 0)
+		Status:         &st,
+		ScheduleBefore: &cutoff,
+	}, "id ASC", 0, 0)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
+	expired := 0
 	for _, c := range rows {
-		if c.Spec.ScheduleAt == nil || c.Spec.ScheduleAt.IsZero() {
-			continue
+		if err := ctx.Err(); err != nil {
+			return expired, err
 		}
-		if !c.Spec.ScheduleAt.Before(cutoff) {
+		ok, err := s.expireUnsentCampaign(ctx, c.ID, cutoff)
+		if err != nil {
+			log.Printf("expire campaign %d: %v", c.ID, err)
 			continue
 		}
+		if ok {
+			expired++
+		}
+	}
+	return expired, nil
+}
 
-		if err := repository.WithTransaction(ctx, s.db, func(txCtx context.Context) error {
-			campaign, err := s.campaignRepo.ByID(txCtx, c.ID)
-			if err != nil {
-				return err
-			}
-			if campaign == nil {
-				return ErrCampaignNotFound
-			}
-			if campaign.CustomerID != customerID {
-				return ErrCampaignAccessDenied
-			}
-			if campaign.Status != models.CampaignStatusWaitingForApproval {
-				return nil
-			}
-			if campaign.Spec.ScheduleAt == nil || campaign.Spec.ScheduleAt.IsZero() || !campaign.Spec.ScheduleAt.Before(cutoff) {
-				return nil
-			}
-
-			customer, err := getCustomer(txCtx, s.customerRepo, campaign.CustomerID)
-			if err != nil {
-				return err
-			}
+// expireUnsentCampaign expires one campaign and refunds its reservation.
+// It reports false when the campaign was approved, rejected or rescheduled
+// in the meantime.
+func (s *CampaignFlowImpl) expireUnsentCampaign(ctx context.Context, campaignID uint, cutoff time.Time) (bool, error) {
+	var campaign *models.Campaign
+	var customer models.Customer
+	var refunded uint64
+	expired := false
 
-			wallet, err := getWallet(txCtx, s.walletRepo, campaign.CustomerID)
-			if err != nil {
-				return err
-			}
-			latestBalance, err := getLatestBalanceSnapshot(txCtx, s.walletRepo, wallet.ID)
-			if err != nil {
-				return err
-			}
+	err := withVersionRetry(ctx, s.db, func(txCtx context.Context) error {
+		var err error
+		expired = false
+		campaign, err = s.campaignRepo.ByID(txCtx, campaignID)
+		if err != nil {
+			return err
+		}
+		if campaign == nil {
+			return ErrCampaignNotFound
+		}
+		if campaign.Status != models.CampaignStatusWaitingForApproval {
+			return nil
+		}
+		if campaign.Spec.ScheduleAt == nil || campaign.Spec.ScheduleAt.IsZero() || !campaign.Spec.ScheduleAt.Before(cutoff) {
+			return nil
+		}
 
-			freezeTxs, err := s.transactionRepo.ByFilter(txCtx, models.TransactionFilter{
-				CustomerID: &campaign.CustomerID,
-				CampaignID: &campaign.ID,
-				Source:     utils.ToPtr("campaign_update"),
-				Operation:  utils.ToPtr("reserve_budget"),
-				Type:       utils.ToPtr(models.TransactionTypeFreeze),
-				Status:     utils.ToPtr(models.TransactionStatusCompleted),
-			}, "id DESC", 0, 0)
-			if err != nil {
-				return err
-			}
-			if len(freezeTxs) == 0 {
-				return ErrFreezeTransactionNotFound
-			}
-			if len(freezeTxs) > 1 {
-				return ErrMultipleFreezeTransactionsFound
-			}
-			freezeTx := freezeTxs[0]
+		customer, err = getCustomer(txCtx, s.customerRepo, campaign.CustomerID)
+		if err != nil {
+			return err
+		}
 
-			amount := freezeTx.Amount
-			if latestBalance.FrozenBalance < amount {
-				return ErrInsufficientFunds
-			}
+		wallet, err := getWallet(txCtx, s.walletRepo, campaign.CustomerID)
+		if err != nil {
+			return err
+		}
+		latestBalance, err := getLatestBalanceSnapshot(txCtx, s.walletRepo, wallet.ID)
+		if err != nil {
+			return err
+		}
 
-			meta := map[string]any{
-				"source":      "campaign_expire",
-				"operation":   "expire_campaign_refund_frozen",
-				"campaign_id": campaign.ID,
-				"comment":     "campaign_auto_expired_due_to_schedule_time",
-			}
-			metaBytes, _ := json.Marshal(meta)
+		freezeTxs, err := s.transactionRepo.ByFilter(txCtx, models.TransactionFilter{
+			CustomerID: &campaign.CustomerID,
+			CampaignID: &campaign.ID,
+			Source:     utils.ToPtr("campaign_update"),
+			Operation:  utils.ToPtr("reserve_budget"),
+			Type:       utils.ToPtr(models.TransactionTypeFreeze),
+			Status:     utils.ToPtr(models.TransactionStatusCompleted),
+		}, "id DESC", 0, 0)
+		if err != nil {
+			return err
+		}
+		if len(freezeTxs) == 0 {
+			return ErrFreezeTransactionNotFound
+		}
+		if len(freezeTxs) > 1 {
+			return ErrMultipleFreezeTransactionsFound
+		}
+		freezeTx := freezeTxs[0]
 
-			// TODO:
-			// newFrozen := latestBalance.FrozenBalance - amount
-			// // Restore free/credit split exactly as it was taken during the freeze.
-			// freeRefund, creditRefund := computeFreezeRefundSplit(freezeTx, amount)
-			// newFree := latestBalance.FreeBalance + freeRefund
-			// newCredit := latestBalance.CreditBalance + creditRefund
+		amount := freezeTx.Amount
+		if latestBalance.FrozenBalance < amount {
+			return ErrInsufficientFunds
+		}
 
-			// newSnap := &models.BalanceSnapshot{
-			// 	UUID:               uuid.New(),
-			// 	CorrelationID:      freezeTx.CorrelationID,
-			// 	WalletID:           wallet.ID,
-			// 	CustomerID:         customer.ID,
-			// 	FreeBalance:        newFree,
-			// 	FrozenBalance:      newFrozen,
-			// 	LockedBalance:      latestBalance.LockedBalance,
-			// 	CreditBalance:      newCredit,
-			// 	SpentOnCampaign:    latestBalance.SpentOnCampaign,
-			// 	AgencyShareWithTax: latestBalance.AgencyShareWithTax,
-			// 	TotalBalance:       newFree + newFrozen + latestBalance.LockedBalance + newCredit + latestBalance.SpentOnCampaign + latestBalance.AgencyShareWithTax,
-			// 	Reason:             "campaign_expired_budget_refund",
-			// 	Description:        fmt.Sprintf("Refund reserved budget for expired campaign %d", campaign.ID),
-			// 	Metadata:           metaBytes,
-			// }
+		meta := map[string]any{
+			"source":      "campaign_expire",
+			"operation":   "expire_campaign_refund_frozen",
+			"campaign_id": campaign.ID,
+			"comment":     "campaign_auto_expired_due_to_schedule_time",
+		}
+		metaBytes, _ := json.Marshal(meta)
 
-			newFrozen := latestBalance.FrozenBalance - amount
-			newCredit := latestBalance.CreditBalance + amount
+		// Restore free/credit split exactly as it was taken during the freeze.
+		newFrozen := latestBalance.FrozenBalance - amount
+		freeRefund, creditRefund := computeFreezeRefundSplit(freezeTx, amount)
+		newFree := latestBalance.FreeBalance + freeRefund
+		newCredit := latestBalance.CreditBalance + creditRefund
 
-			newSnap := &models.BalanceSnapshot{
-				UUID:               uuid.New(),
-				CorrelationID:      freezeTx.CorrelationID,
-				WalletID:           wallet.ID,
-				CustomerID:         customer.ID,
-				FreeBalance:        latestBalance.FreeBalance,
-				FrozenBalance:      newFrozen,
-				LockedBalance:      latestBalance.LockedBalance,
-				CreditBalance:      newCredit,
-				SpentOnCampaign:    latestBalance.SpentOnCampaign,
-				AgencyShareWithTax: latestBalance.AgencyShareWithTax,
-				TotalBalance:       latestBalance.FreeBalance + newFrozen + latestBalance.LockedBalance + newCredit + latestBalance.SpentOnCampaign + latestBalance.AgencyShareWithTax,
-				Reason:             "campaign_expired_budget_refund",
-				Description:        fmt.Sprintf("Refund reserved budget for expired campaign %d", campaign.ID),
-				Metadata:           metaBytes,
-			}
-			if err := s.balanceSnapshotRepo.Save(txCtx, newSnap); err != nil {
-				return err
-			}
+		newSnap := &models.BalanceSnapshot{
+			UUID:               uuid.New(),
+			CorrelationID:      freezeTx.CorrelationID,
+			WalletID:           wallet.ID,
+			CustomerID:         customer.ID,
+			FreeBalance:        newFree,
+			FrozenBalance:      newFrozen,
+			LockedBalance:      latestBalance.LockedBalance,
+			CreditBalance:      newCredit,
+			SpentOnCampaign:    latestBalance.SpentOnCampaign,
+			AgencyShareWithTax: latestBalance.AgencyShareWithTax,
+			TotalBalance:       newFree + newFrozen + latestBalance.LockedBalance + newCredit + latestBalance.SpentOnCampaign + latestBalance.AgencyShareWithTax,
+			Reason:             "campaign_expired_budget_refund",
+			Description:        fmt.Sprintf("Refund reserved budget for expired campaign %d", campaign.ID),
+			Metadata:           metaBytes,
+		}
+		if err := s.walletRepo.AdvanceVersion(txCtx, &wallet); err != nil {
+			return err
+		}
+		if err := s.balanceSnapshotRepo.Save(txCtx, newSnap); err != nil {
+			return err
+		}
 
-			beforeMap, err := latestBalance.GetBalanceMap()
-			if err != nil {
-				return err
-			}
-			afterMap, err := newSnap.GetBalanceMap()
-			if err != nil {
-				return err
-			}
+		beforeMap, err := latestBalance.GetBalanceMap()
+		if err != nil {
+			return err
+		}
+		afterMap, err := newSnap.GetBalanceMap()
+		if err != nil {
+			return err
+		}
 
-			refundTx := &models.Transaction{
-				UUID:          uuid.New(),
-				CorrelationID: freezeTx.CorrelationID,
-				Type:          models.TransactionTypeRefund,
-				Status:        models.TransactionStatusCompleted,
-				Amount:        amount,
-				Currency:      utils.TomanCurrency,
-				WalletID:      wallet.ID,
-				CustomerID:    customer.ID,
-				BalanceBefore: beforeMap,
-				BalanceAfter:  afterMap,
-				Description:   fmt.Sprintf("Refund reserved budget for expired campaign %d", campaign.ID),
-				Metadata:      metaBytes,
-			}
-			if err := s.transactionRepo.Save(txCtx, refundTx); err != nil {
-				return err
-			}
+		refundTx := &models.Transaction{
+			UUID:          uuid.New(),
+			CorrelationID: freezeTx.CorrelationID,
+			Type:          models.TransactionTypeRefund,
+			Status:        models.TransactionStatusCompleted,
+			Amount:        amount,
+			Currency:      utils.TomanCurrency,
+			WalletID:      wallet.ID,
+			CustomerID:    customer.ID,
+			BalanceBefore: beforeMap,
+			BalanceAfter:  afterMap,
+			Description:   fmt.Sprintf("Refund reserved budget for expired campaign %d", campaign.ID),
+			Metadata:      metaBytes,
+		}
+		if err := s.transactionRepo.Save(txCtx, refundTx); err != nil {
+			return err
+		}
 
-			if err := s.campaignRepo.UpdateStatus(txCtx, campaign.ID, models.CampaignStatusExpired); err != nil {
-				return err
-			}
-			return nil
-		}); err != nil {
+		if err := s.campaignRepo.UpdateStatus(txCtx, campaign.ID, models.CampaignStatusExpired); err != nil {
 			return err
 		}
+		notifyInbox(txCtx, s.notificationRepo, customer.ID, models.NotificationKindCampaignExpired, i18n.Args{
+			"Title":  campaignDisplayTitle(campaign),
+			"Amount": amount,
+		})
+		refunded = amount
+		expired = true
+		return nil
+	})
+	if err != nil || !expired {
+		return false, err
+	}
+	if customer.RepresentativeMobile == "" {
+		return true, nil
 	}
 
-	return nil
+	msg := s.localizer.Customer(&customer, "campaign.expired", i18n.Args{
+		"Title":  campaignDisplayTitle(campaign),
+		"Amount": refunded,
+	})
+	enqueueSMS(context.WithoutCancel(ctx), s.jobs, []string{customer.RepresentativeMobile}, msg)
+	return true, nil
 }
 
 // reconcileUndeliveredCampaignRefunds runs a best-effort reconciliation pass to refund
@@ -2886,7 +3194,7 @@ func (s *CampaignFlowImpl) reconcileUndeliveredCampaignRefunds(ctx context.Conte
 			continue
 		}
 
-		err = repository.WithTransaction(ctx, s.db, func(txCtx context.Context) error {
+		err = withVersionRetry(ctx, s.db, func(txCtx context.Context) error {
 			campaign, err := s.campaignRepo.ByID(txCtx, c.ID)
 			if err != nil {
 				return err
@@ -2909,11 +3217,10 @@ func (s *CampaignFlowImpl) reconcileUndeliveredCampaignRefunds(ctx context.Conte
 			}
 
 			// Serialize refund reconciliation per campaign to prevent duplicate refunds
-			// under concurrent list/get requests.
-			if tx, ok := txCtx.Value(repository.TxContextKey).(*gorm.DB); ok && tx != nil {
-				if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", int64(campaign.ID)).Error; err != nil {
-					return err
-				}
+			// under concurrent list/get requests, and with execution stops that
+			// refund the unsent share.
+			if err := lockCampaignExecution(txCtx, campaign.ID); err != nil {
+				return err
 			}
 
 			if hasProcessedUndeliveredRefund(campaign.Statistics) {
@@ -3077,6 +3384,9 @@ func (s *CampaignFlowImpl) reconcileUndeliveredCampaignRefunds(ctx context.Conte
 				Description:        fmt.Sprintf("Refund undelivered messages for campaign %d", campaign.ID),
 				Metadata:           metaBytes,
 			}
+			if err := s.walletRepo.AdvanceVersion(txCtx, &wallet); err != nil {
+				return err
+			}
 			if err := s.balanceSnapshotRepo.Save(txCtx, newSnap); err != nil {
 				return err
 			}
@@ -3314,12 +3624,14 @@ func (s *CampaignFlowImpl) resolveCampaignCostPerMessageFromMetadata(ctx context
 		segmentFactor = *f
 	}
 
-	pagePriceFloat := float64(pagePrice)
-	numPagesFloat := float64(numPages)
 	if campaign.Spec.Platform == models.CampaignPlatformSMS {
-		return basePrice*uint64(lineFactor*numPagesFloat) + uint64(segmentFactor*pagePriceFloat), true
+		partsCost := pricing.LegacySMSPartsCost(basePrice, lineFactor, numPages)
+		if pricePerPart, ok := parseMetadataUint64(meta["sms_price_per_part"]); ok && pricePerPart > 0 {
+			partsCost = pricePerPart * numPages
+		}
+		return pricing.MessagePrice(partsCost, segmentFactor, pagePrice), true
 	}
-	return basePrice*uint64(1*1) + uint64(segmentFactor*pagePriceFloat), true
+	return pricing.MessagePrice(basePrice, segmentFactor, pagePrice), true
 }
 
 func parseMetadataUint64(value any) (uint64, bool) {
@@ -3555,47 +3867,39 @@ func (s *CampaignFlowImpl) GetApprovedRunningSummary(ctx context.Context, custom
 	return resp, nil
 }
 
-// calculateParts calculates the number of SMS parts based on the effective
-// character count after link substitution rules are applied.
-// Non-SMS platforms always use a single part.
+// calculateParts returns the number of SMS parts the campaign body is sent
+// in, after link substitution and with the opt-out suffix. Non-SMS platforms
+// always use a single part.
 func (s *CampaignFlowImpl) calculateParts(content *string, adLink *string, shortLinkDomain *string, platform string) uint64 {
 	if platform != models.CampaignPlatformSMS {
 		return 1
 	}
-
-	if content == nil || *content == "" {
-		return 1
-	}
-
-	// Count characters with proper weighting (English=1, others=2)
-	charCount := s.countCharacters(*content, adLink, shortLinkDomain, platform)
-
-	// Calculate SMS parts based on character count
-	if charCount <= 70 {
-		return 1
-	} else if charCount <= 132 {
-		return 2
-	} else if charCount <= 198 {
-		return 3
-	} else if charCount <= 264 {
-		return 4
-	} else if charCount <= 330 {
-		return 5
+	text := ""
+	if content != nil {
+		text = *content
 	}
-	return 6 // More than 330 characters
+	return campaignSMSSegments(text, adLink, shortLinkDomain).Parts
 }
 
-// countCharacters counts characters after applying campaign link expansion rules.
-func (s *CampaignFlowImpl) countCharacters(text string, adLink *string, shortLinkDomain *string, platform string) uint64 {
-	if text == "" {
-		if platform == models.CampaignPlatformSMS {
-			return 6
-		}
-		return 0
+// campaignSpecSMSSegments returns the encoding and part count of an SMS
+// campaign's body, or nothing for other platforms and empty content
+func campaignSpecSMSSegments(spec models.CampaignSpec) (string, uint64) {
+	if spec.Platform != models.CampaignPlatformSMS || spec.Content == nil || strings.TrimSpace(*spec.Content) == "" {
+		return "", 0
 	}
+	segments := campaignSMSSegments(*spec.Content, spec.AdLink, spec.ShortLinkDomain)
+	return segments.Encoding, segments.Parts
+}
 
-	textToCount := text
+// campaignSMSSegments sizes the SMS the scheduler builds from the content:
+// links expanded to a representative length and the opt-out suffix appended
+func campaignSMSSegments(content string, adLink *string, shortLinkDomain *string) pricing.SMSSegments {
+	return pricing.CountSMSSegments(expandCampaignLinks(content, adLink, shortLinkDomain) + pricing.SMSOptOutSuffix)
+}
 
+// expandCampaignLinks substitutes {YOUR_LINK} with a representative short
+// link or ad link so the text has the length it will have when sent.
+func expandCampaignLinks(text string, adLink *string, shortLinkDomain *string) string {
 	hasAdLink := adLink != nil && strings.TrimSpace(*adLink) != ""
 	hasShortLinkDomain := shortLinkDomain != nil && strings.TrimSpace(*shortLinkDomain) != ""
 
@@ -3603,33 +3907,15 @@ func (s *CampaignFlowImpl) countCharacters(text string, adLink *string, shortLin
 	case hasAdLink && hasShortLinkDomain:
 		shortLinkText := strings.TrimSpace(*shortLinkDomain)
 		shortLinkText = shortLinkText + "/123456"
-		textToCount = strings.ReplaceAll(textToCount, "{YOUR_LINK}", shortLinkText)
+		return strings.ReplaceAll(text, "{YOUR_LINK}", shortLinkText)
 	case hasAdLink:
 		resolvedAdLink := strings.TrimSpace(*adLink)
 		if strings.Contains(resolvedAdLink, "{uid}") {
 			resolvedAdLink = strings.ReplaceAll(resolvedAdLink, "{uid}", "123456")
 		}
-		textToCount = strings.ReplaceAll(textToCount, "{YOUR_LINK}", resolvedAdLink)
-	}
-
-	var count uint64
-	for _, char := range textToCount {
-		// Check if character is English (ASCII range 32-126)
-		if char >= 32 && char <= 126 {
-			count += 1 // English character
-		} else {
-			// Non-English character (Farsi, Arabic, etc.)
-			// count += 2
-
-			count += 1
-		}
+		return strings.ReplaceAll(text, "{YOUR_LINK}", resolvedAdLink)
 	}
-
-	if platform == models.CampaignPlatformSMS {
-		count += 6
-	}
-
-	return count
+	return text
 }
 
 func sanitizeShortLinkDomain(domain *string) (*string, error) {
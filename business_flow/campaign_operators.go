@@ -0,0 +1,54 @@
+package businessflow
+
+import (
+	"context"
+	"strings"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+)
+
+// unknownOperator labels recipients whose operator could not be told from
+// their prefix, including those sent before operators were recorded
+const unknownOperator = "unknown"
+
+// GetCampaignOperatorStats reports sent, delivered and clicked recipients per
+// mobile operator of a customer's campaign.
+func (s *CampaignFlowImpl) GetCampaignOperatorStats(ctx context.Context, req *dto.GetCampaignOperatorStatsRequest) (*dto.GetCampaignOperatorStatsResponse, error) {
+	if req == nil || strings.TrimSpace(req.UUID) == "" {
+		return nil, NewBusinessError("CAMPAIGN_OPERATOR_STATS_VALIDATION_FAILED", "campaign uuid is required", ErrCampaignUUIDRequired)
+	}
+
+	campaign, err := getCampaign(ctx, s.campaignRepo, req.UUID, req.CustomerID)
+	if err != nil {
+		return nil, NewBusinessError("CAMPAIGN_LOOKUP_FAILED", "Failed to lookup campaign", err)
+	}
+
+	aggs, err := s.smsStatusResultRepo.AggregateByOperator(ctx, campaign.ID)
+	if err != nil {
+		return nil, NewBusinessError("CAMPAIGN_OPERATOR_STATS_FAILED", "Failed to aggregate campaign operator statistics", err)
+	}
+
+	items := make([]dto.CampaignOperatorStats, 0, len(aggs))
+	for _, a := range aggs {
+		item := dto.CampaignOperatorStats{
+			Operator:  a.Operator,
+			Sent:      a.Sent,
+			Delivered: a.Delivered,
+			Clicks:    a.Clicks,
+		}
+		if item.Operator == "" {
+			item.Operator = unknownOperator
+		}
+		if item.Sent > 0 {
+			rate := float64(item.Delivered) / float64(item.Sent)
+			item.DeliveryRate = &rate
+		}
+		item.ClickRate = computeClickRate(item.Clicks, float64(item.Delivered))
+		items = append(items, item)
+	}
+
+	return &dto.GetCampaignOperatorStatsResponse{
+		Message:   "Campaign operator statistics retrieved successfully",
+		Operators: items,
+	}, nil
+}
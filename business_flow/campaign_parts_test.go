@@ -0,0 +1,59 @@
+package businessflow
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/pricing"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+)
+
+func TestCalculatePartsCountsOptOutSuffix(t *testing.T) {
+	t.Parallel()
+
+	s := &CampaignFlowImpl{}
+	sms := models.CampaignPlatformSMS
+
+	// The six-unit suffix makes every body UCS-2: 70 units fit one part, then 67 per part
+	tests := []struct {
+		name    string
+		content string
+		adLink  *string
+		domain  *string
+		want    uint64
+	}{
+		{name: "empty", content: "", want: 1},
+		{name: "fits one part", content: strings.Repeat("a", 64), want: 1},
+		{name: "spills into two parts", content: strings.Repeat("a", 65), want: 2},
+		{name: "persian three parts", content: strings.Repeat("س", 135), want: 3},
+		{name: "short link expanded", content: strings.Repeat("a", 53) + "{YOUR_LINK}", adLink: utils.ToPtr("https://example.com"), domain: utils.ToPtr("jo.ir"), want: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.calculateParts(&tt.content, tt.adLink, tt.domain, sms); got != tt.want {
+				t.Fatalf("expected %d parts, got %d", tt.want, got)
+			}
+		})
+	}
+
+	long := strings.Repeat("س", 500)
+	if got := s.calculateParts(&long, nil, nil, models.CampaignPlatformBale); got != 1 {
+		t.Fatalf("expected non-SMS platforms to use one part, got %d", got)
+	}
+}
+
+func TestCampaignSpecSMSSegments(t *testing.T) {
+	t.Parallel()
+
+	encoding, parts := campaignSpecSMSSegments(models.CampaignSpec{Platform: models.CampaignPlatformSMS, Content: utils.ToPtr("hello")})
+	if encoding != pricing.SMSEncodingUCS2 || parts != 1 {
+		t.Fatalf("expected ucs2/1, got %s/%d", encoding, parts)
+	}
+	if encoding, parts := campaignSpecSMSSegments(models.CampaignSpec{Platform: models.CampaignPlatformSMS}); encoding != "" || parts != 0 {
+		t.Fatalf("expected nothing without content, got %s/%d", encoding, parts)
+	}
+	if encoding, parts := campaignSpecSMSSegments(models.CampaignSpec{Platform: models.CampaignPlatformRubika, Content: utils.ToPtr("hello")}); encoding != "" || parts != 0 {
+		t.Fatalf("expected nothing for other platforms, got %s/%d", encoding, parts)
+	}
+}
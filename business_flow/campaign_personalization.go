@@ -0,0 +1,57 @@
+package businessflow
+
+import (
+	"context"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/personalization"
+)
+
+// ValidateCampaignContent reports the personalization variables used in SMS
+// content and flags unknown ones before the campaign is finalized.
+func (s *CampaignFlowImpl) ValidateCampaignContent(ctx context.Context, req *dto.ValidateCampaignContentRequest) (*dto.ValidateCampaignContentResponse, error) {
+	if req == nil || req.Content == "" {
+		return nil, NewBusinessError("CAMPAIGN_CONTENT_VALIDATION_FAILED", "content is required", ErrCampaignContentRequired)
+	}
+	if _, err := getCustomer(ctx, s.customerRepo, req.CustomerID); err != nil {
+		return nil, NewBusinessError("CUSTOMER_LOOKUP_FAILED", "Failed to lookup customer", err)
+	}
+
+	used, unknown := personalization.Parse(req.Content)
+	if used == nil {
+		used = []string{}
+	}
+	if unknown == nil {
+		unknown = []string{}
+	}
+	return &dto.ValidateCampaignContentResponse{
+		Valid:              len(unknown) == 0,
+		Variables:          used,
+		UnknownVariables:   unknown,
+		SupportedVariables: personalization.Variables,
+	}, nil
+}
+
+// validateCampaignPersonalization rejects content or variants that would send
+// a placeholder literally: unknown variables, or any variable outside SMS.
+func validateCampaignPersonalization(spec models.CampaignSpec) error {
+	contents := make([]string, 0, 1+len(spec.Variants))
+	if spec.Content != nil {
+		contents = append(contents, *spec.Content)
+	}
+	for _, v := range spec.Variants {
+		contents = append(contents, v.Content)
+	}
+
+	for _, content := range contents {
+		used, unknown := personalization.Parse(content)
+		if len(unknown) > 0 {
+			return ErrCampaignContentUnknownVariables
+		}
+		if len(used) > 0 && spec.Platform != models.CampaignPlatformSMS {
+			return ErrCampaignPersonalizationUnsupported
+		}
+	}
+	return nil
+}
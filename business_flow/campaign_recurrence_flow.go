@@ -0,0 +1,444 @@
+package businessflow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/recurrence"
+	"github.com/amirphl/Yamata-no-Orochi/repository"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	// minCampaignRecurrenceGap is the shortest allowed distance between two occurrences
+	minCampaignRecurrenceGap = time.Hour
+	// missedOccurrenceGrace is how late an occurrence may still be materialized;
+	// older ones are skipped because the bot only lists campaigns scheduled
+	// within the last hour.
+	missedOccurrenceGrace = 30 * time.Minute
+	// recurrenceExpandBatchSize caps the parents handled per expansion run
+	recurrenceExpandBatchSize = 100
+)
+
+// CampaignRecurrenceFlow materializes the occurrences of recurring campaigns
+type CampaignRecurrenceFlow interface {
+	// ExpandDueRecurrences creates a child campaign for every series whose
+	// next occurrence is due at or before dueBefore and returns how many
+	// occurrences were created.
+	ExpandDueRecurrences(ctx context.Context, dueBefore time.Time) (int, error)
+}
+
+type CampaignRecurrenceFlowImpl struct {
+	campaignRepo        repository.CampaignRepository
+	walletRepo          repository.WalletRepository
+	balanceSnapshotRepo repository.BalanceSnapshotRepository
+	transactionRepo     repository.TransactionRepository
+	auditRepo           repository.AuditLogRepository
+	db                  *gorm.DB
+}
+
+func NewCampaignRecurrenceFlow(
+	campaignRepo repository.CampaignRepository,
+	walletRepo repository.WalletRepository,
+	balanceSnapshotRepo repository.BalanceSnapshotRepository,
+	transactionRepo repository.TransactionRepository,
+	auditRepo repository.AuditLogRepository,
+	db *gorm.DB,
+) CampaignRecurrenceFlow {
+	return &CampaignRecurrenceFlowImpl{
+		campaignRepo:        campaignRepo,
+		walletRepo:          walletRepo,
+		balanceSnapshotRepo: balanceSnapshotRepo,
+		transactionRepo:     transactionRepo,
+		auditRepo:           auditRepo,
+		db:                  db,
+	}
+}
+
+func (f *CampaignRecurrenceFlowImpl) ExpandDueRecurrences(ctx context.Context, dueBefore time.Time) (int, error) {
+	parents, err := f.campaignRepo.ListDueRecurringParents(ctx, dueBefore, recurrenceExpandBatchSize)
+	if err != nil {
+		return 0, NewBusinessError("CAMPAIGN_RECURRENCE_LIST_FAILED", "Failed to list due recurring campaigns", err)
+	}
+
+	created := 0
+	var errs []error
+	for _, parent := range parents {
+		ok, err := f.expandParent(ctx, parent)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("campaign %d: %w", parent.ID, err))
+			continue
+		}
+		if ok {
+			created++
+		}
+	}
+	return created, errors.Join(errs...)
+}
+
+// expandParent materializes the due occurrence of one series and advances the
+// series to its next occurrence. It reports whether a child was created.
+func (f *CampaignRecurrenceFlowImpl) expandParent(ctx context.Context, parent *models.Campaign) (bool, error) {
+	if parent.Spec.Recurrence == nil || parent.Spec.ScheduleAt == nil || parent.NextOccurrenceAt == nil {
+		return false, f.endSeries(ctx, parent, "recurrence rule or schedule is missing")
+	}
+
+	children, err := f.campaignRepo.Count(ctx, models.CampaignFilter{ParentCampaignID: &parent.ID})
+	if err != nil {
+		return false, err
+	}
+	occurrence := uint(children) + 2
+	occurrenceAt := parent.NextOccurrenceAt.UTC()
+
+	now := utils.UTCNow()
+	if occurrenceAt.Before(now.Add(-missedOccurrenceGrace)) || !isScheduleWithinTehranWindow(occurrenceAt) {
+		log.Printf("campaign recurrence: skipping occurrence at %s of campaign %d", occurrenceAt.Format(time.RFC3339), parent.ID)
+		return false, f.advanceSeries(ctx, parent, occurrenceAt, occurrence-1)
+	}
+
+	var child *models.Campaign
+	err = withVersionRetry(ctx, f.db, func(txCtx context.Context) error {
+		var err error
+		child, err = f.materializeOccurrence(txCtx, parent, occurrence, occurrenceAt)
+		if err != nil {
+			return err
+		}
+		parent.NextOccurrenceAt, err = nextCampaignOccurrence(parent, occurrenceAt, occurrence)
+		if err != nil {
+			return err
+		}
+		return f.campaignRepo.Update(txCtx, *parent)
+	})
+	if err != nil {
+		if errors.Is(err, ErrInsufficientFunds) || errors.Is(err, ErrFreezeTransactionNotFound) {
+			f.logSeriesEvent(ctx, parent, models.AuditActionCampaignOccurrenceFailed,
+				fmt.Sprintf("Occurrence %d of campaign %d could not be funded", occurrence, parent.ID), err)
+			return false, f.endSeries(ctx, parent, err.Error())
+		}
+		return false, err
+	}
+
+	f.logSeriesEvent(ctx, parent, models.AuditActionCampaignOccurrenceCreated,
+		fmt.Sprintf("Occurrence %d of campaign %d created as campaign %d", occurrence, parent.ID, child.ID), nil)
+	if parent.NextOccurrenceAt == nil {
+		f.logSeriesEvent(ctx, parent, models.AuditActionCampaignRecurrenceEnded,
+			fmt.Sprintf("Recurring campaign %d completed after %d occurrences", parent.ID, occurrence), nil)
+	}
+	return true, nil
+}
+
+// materializeOccurrence creates an approved child campaign and charges it the
+// per-occurrence budget reserved when the parent was finalized. The charge
+// mirrors finalize (free/credit to frozen) followed by approval (frozen to
+// spent) so refunds and reports treat the child like any other campaign.
+func (f *CampaignRecurrenceFlowImpl) materializeOccurrence(ctx context.Context, parent *models.Campaign, occurrence uint, occurrenceAt time.Time) (*models.Campaign, error) {
+	freezeTxs, err := f.transactionRepo.ByFilter(ctx, models.TransactionFilter{
+		CustomerID: &parent.CustomerID,
+		CampaignID: &parent.ID,
+		Source:     utils.ToPtr("campaign_update"),
+		Operation:  utils.ToPtr("reserve_budget"),
+		Type:       utils.ToPtr(models.TransactionTypeFreeze),
+		Status:     utils.ToPtr(models.TransactionStatusCompleted),
+	}, "id DESC", 1, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(freezeTxs) == 0 {
+		return nil, ErrFreezeTransactionNotFound
+	}
+	amount := freezeTxs[0].Amount
+
+	spec := parent.Spec
+	spec.ScheduleAt = &occurrenceAt
+	spec.Recurrence = nil
+	child := &models.Campaign{
+		UUID:             uuid.New(),
+		CustomerID:       parent.CustomerID,
+		IsSandbox:        parent.IsSandbox,
+		Status:           models.CampaignStatusApproved,
+		Spec:             spec,
+		Comment:          parent.Comment,
+		NumAudience:      parent.NumAudience,
+		BundleID:         parent.BundleID,
+		Phase:            parent.Phase,
+		ParentCampaignID: &parent.ID,
+		OccurrenceNumber: &occurrence,
+	}
+	if err := f.campaignRepo.Save(ctx, child); err != nil {
+		return nil, err
+	}
+
+	wallet, err := getWallet(ctx, f.walletRepo, parent.CustomerID)
+	if err != nil {
+		return nil, err
+	}
+	latestBalance, err := getLatestBalanceSnapshot(ctx, f.walletRepo, wallet.ID)
+	if err != nil {
+		return nil, err
+	}
+	if latestBalance.FreeBalance+latestBalance.CreditBalance < amount {
+		return nil, ErrInsufficientFunds
+	}
+
+	reserveMeta := map[string]any{}
+	if len(freezeTxs[0].Metadata) > 0 {
+		_ = json.Unmarshal(freezeTxs[0].Metadata, &reserveMeta)
+	}
+	reserveMeta["campaign_id"] = child.ID
+	reserveMeta["amount"] = amount
+	reserveMeta["campaign_spec"] = child.Spec
+	reserveMeta["parent_campaign_id"] = parent.ID
+	reserveMeta["occurrence_number"] = occurrence
+	reserveMetaBytes, _ := json.Marshal(reserveMeta)
+
+	reserved := latestBalance
+	remaining := amount
+	if remaining <= reserved.FreeBalance {
+		reserved.FreeBalance -= remaining
+	} else {
+		remaining -= reserved.FreeBalance
+		reserved.FreeBalance = 0
+		reserved.CreditBalance -= remaining
+	}
+	reserved.FrozenBalance += amount
+	corrID := uuid.New()
+	reserveSnap, err := f.saveOccurrenceTransaction(ctx, &wallet, latestBalance, reserved, corrID, models.TransactionTypeFreeze, amount,
+		"campaign_budget_reserved_for_occurrence",
+		fmt.Sprintf("Budget reserved for occurrence %d of campaign %d (campaign %d)", occurrence, parent.ID, child.ID),
+		reserveMetaBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	// Same source/operation as admin approval so cancel and undelivered
+	// refunds find the debit of the child.
+	consumeMeta, _ := json.Marshal(map[string]any{
+		"source":             "admin_campaign_approve",
+		"operation":          "approve_campaign_budget_consume",
+		"campaign_id":        child.ID,
+		"parent_campaign_id": parent.ID,
+		"occurrence_number":  occurrence,
+	})
+	spent := *reserveSnap
+	spent.FrozenBalance -= amount
+	spent.SpentOnCampaign += amount
+	if _, err := f.saveOccurrenceTransaction(ctx, &wallet, *reserveSnap, spent, corrID, models.TransactionTypeFee, amount,
+		"campaign_approved_budget_spent_on_campaign",
+		fmt.Sprintf("Budget spent on occurrence %d of campaign %d (campaign %d)", occurrence, parent.ID, child.ID),
+		consumeMeta); err != nil {
+		return nil, err
+	}
+
+	return child, nil
+}
+
+// saveOccurrenceTransaction persists the balance snapshot after and the
+// transaction that moved the wallet from before to after.
+func (f *CampaignRecurrenceFlowImpl) saveOccurrenceTransaction(
+	ctx context.Context,
+	wallet *models.Wallet,
+	before, after models.BalanceSnapshot,
+	corrID uuid.UUID,
+	txType models.TransactionType,
+	amount uint64,
+	reason, description string,
+	metadata []byte,
+) (*models.BalanceSnapshot, error) {
+	now := utils.UTCNow()
+	snap := &models.BalanceSnapshot{
+		UUID:               uuid.New(),
+		CorrelationID:      corrID,
+		WalletID:           wallet.ID,
+		CustomerID:         wallet.CustomerID,
+		FreeBalance:        after.FreeBalance,
+		FrozenBalance:      after.FrozenBalance,
+		CreditBalance:      after.CreditBalance,
+		LockedBalance:      after.LockedBalance,
+		SpentOnCampaign:    after.SpentOnCampaign,
+		AgencyShareWithTax: after.AgencyShareWithTax,
+		TotalBalance:       after.FreeBalance + after.FrozenBalance + after.CreditBalance + after.LockedBalance + after.SpentOnCampaign + after.AgencyShareWithTax,
+		Reason:             reason,
+		Description:        description,
+		Metadata:           metadata,
+		CreatedAt:          now,
+		UpdatedAt:          now,
+	}
+	if err := f.walletRepo.AdvanceVersion(ctx, wallet); err != nil {
+		return nil, err
+	}
+	if err := f.balanceSnapshotRepo.Save(ctx, snap); err != nil {
+		return nil, err
+	}
+
+	beforeMap, err := before.GetBalanceMap()
+	if err != nil {
+		return nil, err
+	}
+	afterMap, err := snap.GetBalanceMap()
+	if err != nil {
+		return nil, err
+	}
+	tx := &models.Transaction{
+		UUID:          uuid.New(),
+		CorrelationID: corrID,
+		Type:          txType,
+		Status:        models.TransactionStatusCompleted,
+		Amount:        amount,
+		Currency:      utils.TomanCurrency,
+		WalletID:      wallet.ID,
+		CustomerID:    wallet.CustomerID,
+		BalanceBefore: beforeMap,
+		BalanceAfter:  afterMap,
+		Description:   description,
+		Metadata:      metadata,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	if err := f.transactionRepo.Save(ctx, tx); err != nil {
+		return nil, err
+	}
+	return snap, nil
+}
+
+// advanceSeries moves the series past an occurrence that was not materialized
+func (f *CampaignRecurrenceFlowImpl) advanceSeries(ctx context.Context, parent *models.Campaign, occurrenceAt time.Time, occurrences uint) error {
+	next, err := nextCampaignOccurrence(parent, occurrenceAt, occurrences)
+	if err != nil {
+		return f.endSeries(ctx, parent, err.Error())
+	}
+	parent.NextOccurrenceAt = next
+	if err := f.campaignRepo.Update(ctx, *parent); err != nil {
+		return err
+	}
+	if next == nil {
+		f.logSeriesEvent(ctx, parent, models.AuditActionCampaignRecurrenceEnded,
+			fmt.Sprintf("Recurring campaign %d completed", parent.ID), nil)
+	}
+	return nil
+}
+
+// endSeries stops a series so no further occurrences are materialized
+func (f *CampaignRecurrenceFlowImpl) endSeries(ctx context.Context, parent *models.Campaign, reason string) error {
+	parent.NextOccurrenceAt = nil
+	if err := f.campaignRepo.Update(ctx, *parent); err != nil {
+		return err
+	}
+	f.logSeriesEvent(ctx, parent, models.AuditActionCampaignRecurrenceEnded,
+		fmt.Sprintf("Recurring campaign %d stopped: %s", parent.ID, reason), nil)
+	return nil
+}
+
+func (f *CampaignRecurrenceFlowImpl) logSeriesEvent(ctx context.Context, parent *models.Campaign, action, description string, cause error) {
+	var errMsg *string
+	if cause != nil {
+		errMsg = utils.ToPtr(cause.Error())
+	}
+	audit := &models.AuditLog{
+		CustomerID:   &parent.CustomerID,
+		Action:       action,
+		Description:  &description,
+		Success:      utils.ToPtr(cause == nil),
+		ErrorMessage: errMsg,
+	}
+	if err := f.auditRepo.Save(ctx, audit); err != nil {
+		log.Printf("campaign recurrence: failed to save audit log for campaign %d: %v", parent.ID, err)
+	}
+}
+
+// nextCampaignOccurrence returns the occurrence of a recurring campaign that
+// follows after, or nil when the series has ended. occurrences is the number
+// of occurrences up to and including after.
+func nextCampaignOccurrence(c *models.Campaign, after time.Time, occurrences uint) (*time.Time, error) {
+	rec := c.Spec.Recurrence
+	if rec == nil || c.Spec.ScheduleAt == nil {
+		return nil, nil
+	}
+	if rec.MaxOccurrences != nil && occurrences >= *rec.MaxOccurrences {
+		return nil, nil
+	}
+	next, err := rec.Rule().Next(*c.Spec.ScheduleAt, after)
+	if err != nil {
+		return nil, err
+	}
+	if rec.Until != nil && next.After(*rec.Until) {
+		return nil, nil
+	}
+	return &next, nil
+}
+
+// buildCampaignRecurrence validates a requested recurrence rule. scheduleAt is
+// the first occurrence when already known.
+func buildCampaignRecurrence(spec *dto.CampaignRecurrenceSpec, scheduleAt *time.Time) (*models.CampaignRecurrence, error) {
+	if spec == nil {
+		return nil, nil
+	}
+	rec := &models.CampaignRecurrence{
+		Frequency:      spec.Frequency,
+		Interval:       spec.Interval,
+		Weekdays:       spec.Weekdays,
+		Cron:           spec.Cron,
+		Until:          spec.Until,
+		MaxOccurrences: spec.MaxOccurrences,
+	}
+	if rec.Frequency != recurrence.FrequencyCron {
+		rec.Cron = nil
+	}
+	if rec.Frequency != recurrence.FrequencyWeekly {
+		rec.Weekdays = nil
+	}
+	if err := validateCampaignRecurrence(rec, scheduleAt); err != nil {
+		return nil, err
+	}
+	return rec, nil
+}
+
+func validateCampaignRecurrence(rec *models.CampaignRecurrence, scheduleAt *time.Time) error {
+	rule := rec.Rule()
+	if err := rule.Validate(); err != nil {
+		return ErrCampaignRecurrenceInvalid
+	}
+
+	anchor := utils.UTCNow()
+	if scheduleAt != nil && !scheduleAt.IsZero() {
+		anchor = scheduleAt.UTC()
+		if rec.Until != nil && !rec.Until.After(anchor) {
+			return ErrCampaignRecurrenceUntilInvalid
+		}
+	}
+
+	// Check the gap over a few occurrences; cron rules are not evenly spaced.
+	prev := anchor
+	for range 4 {
+		next, err := rule.Next(anchor, prev)
+		if err != nil {
+			return ErrCampaignRecurrenceInvalid
+		}
+		if next.Sub(prev) < minCampaignRecurrenceGap {
+			return ErrCampaignRecurrenceTooFrequent
+		}
+		prev = next
+	}
+	return nil
+}
+
+func toCampaignRecurrenceSpec(rec *models.CampaignRecurrence) *dto.CampaignRecurrenceSpec {
+	if rec == nil {
+		return nil
+	}
+	return &dto.CampaignRecurrenceSpec{
+		Frequency:      rec.Frequency,
+		Interval:       rec.Interval,
+		Weekdays:       rec.Weekdays,
+		Cron:           rec.Cron,
+		Until:          rec.Until,
+		MaxOccurrences: rec.MaxOccurrences,
+	}
+}
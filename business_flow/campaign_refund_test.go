@@ -0,0 +1,89 @@
+package businessflow
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/repository"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+)
+
+type expiryCampaignRepoStub struct {
+	repository.CampaignRepository
+	filter models.CampaignFilter
+}
+
+func (r *expiryCampaignRepoStub) ByFilter(ctx context.Context, filter models.CampaignFilter, orderBy string, limit, offset int) ([]*models.Campaign, error) {
+	r.filter = filter
+	return nil, nil
+}
+
+func freezeTxWithBalances(t *testing.T, before, after models.BalanceSnapshot) *models.Transaction {
+	t.Helper()
+	beforeMap, err := before.GetBalanceMap()
+	if err != nil {
+		t.Fatal(err)
+	}
+	afterMap, err := after.GetBalanceMap()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &models.Transaction{BalanceBefore: beforeMap, BalanceAfter: afterMap}
+}
+
+func TestComputeFreezeRefundSplit(t *testing.T) {
+	t.Parallel()
+	// 300k reserved: 200k of free balance, the rest from credit
+	mixed := freezeTxWithBalances(t,
+		models.BalanceSnapshot{FreeBalance: 200_000, CreditBalance: 500_000},
+		models.BalanceSnapshot{FreeBalance: 0, FrozenBalance: 300_000, CreditBalance: 400_000},
+	)
+	creditOnly := freezeTxWithBalances(t,
+		models.BalanceSnapshot{CreditBalance: 500_000},
+		models.BalanceSnapshot{FrozenBalance: 300_000, CreditBalance: 200_000},
+	)
+
+	tests := []struct {
+		name       string
+		tx         *models.Transaction
+		amount     uint64
+		free, cred uint64
+	}{
+		{"whole reservation", mixed, 300_000, 200_000, 100_000},
+		{"part of the reservation goes to free first", mixed, 150_000, 150_000, 0},
+		{"credit only", creditOnly, 300_000, 0, 300_000},
+		{"no balances recorded", &models.Transaction{}, 300_000, 300_000, 0},
+		{"unreadable balances", &models.Transaction{BalanceBefore: json.RawMessage(`{`), BalanceAfter: json.RawMessage(`{}`)}, 300_000, 300_000, 0},
+		{"nothing to refund", mixed, 0, 0, 0},
+	}
+	for _, tt := range tests {
+		free, cred := computeFreezeRefundSplit(tt.tx, tt.amount)
+		if free != tt.free || cred != tt.cred {
+			t.Errorf("%s: split = %d free, %d credit, want %d, %d", tt.name, free, cred, tt.free, tt.cred)
+		}
+	}
+}
+
+func TestExpireUnsentCampaignsSweepsOverdueWaitingCampaigns(t *testing.T) {
+	t.Parallel()
+	repo := &expiryCampaignRepoStub{}
+	s := &CampaignFlowImpl{campaignRepo: repo}
+
+	expired, err := s.ExpireUnsentCampaigns(context.Background())
+	if err != nil || expired != 0 {
+		t.Fatalf("ExpireUnsentCampaigns = %d, %v", expired, err)
+	}
+	if repo.filter.CustomerID != nil {
+		t.Fatalf("sweep limited to customer %d", *repo.filter.CustomerID)
+	}
+	if repo.filter.Status == nil || *repo.filter.Status != models.CampaignStatusWaitingForApproval {
+		t.Fatalf("campaigns filtered by status %v", repo.filter.Status)
+	}
+	wantCutoff := utils.UTCNow().Add(-unsentCampaignExpiryDelay)
+	if repo.filter.ScheduleBefore == nil || repo.filter.ScheduleBefore.Sub(wantCutoff).Abs() > time.Minute {
+		t.Fatalf("campaigns scheduled before %v, want about %v", repo.filter.ScheduleBefore, wantCutoff)
+	}
+}
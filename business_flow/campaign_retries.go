@@ -0,0 +1,36 @@
+package businessflow
+
+import (
+	"context"
+	"strings"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+)
+
+// GetCampaignRetryStats reports how the recipients of a customer's campaign
+// that were resent after a transient provider error fared.
+func (s *CampaignFlowImpl) GetCampaignRetryStats(ctx context.Context, req *dto.GetCampaignRetryStatsRequest) (*dto.GetCampaignRetryStatsResponse, error) {
+	if req == nil || strings.TrimSpace(req.UUID) == "" {
+		return nil, NewBusinessError("CAMPAIGN_RETRY_STATS_VALIDATION_FAILED", "campaign uuid is required", ErrCampaignUUIDRequired)
+	}
+
+	campaign, err := getCampaign(ctx, s.campaignRepo, req.UUID, req.CustomerID)
+	if err != nil {
+		return nil, NewBusinessError("CAMPAIGN_LOOKUP_FAILED", "Failed to lookup campaign", err)
+	}
+
+	outcomes, err := s.smsRetryRepo.OutcomesByCampaign(ctx, campaign.ID)
+	if err != nil {
+		return nil, NewBusinessError("CAMPAIGN_RETRY_STATS_FAILED", "Failed to aggregate campaign retry statistics", err)
+	}
+	return &dto.GetCampaignRetryStatsResponse{
+		Message:         "Campaign retry statistics retrieved successfully",
+		Retried:         outcomes.Retried,
+		Pending:         outcomes.Pending,
+		Recovered:       outcomes.Recovered,
+		Exhausted:       outcomes.Exhausted,
+		Permanent:       outcomes.Permanent,
+		Attempts:        outcomes.Attempts,
+		PermanentErrors: outcomes.PermanentErrors,
+	}, nil
+}
@@ -0,0 +1,277 @@
+package businessflow
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/app/i18n"
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+)
+
+// RequestCampaignChanges sends a campaign waiting for approval back to its
+// owner with a comment. The reserved budget is refunded; the customer edits
+// the campaign and finalizes it again, which reserves the budget anew.
+func (s *AdminCampaignFlowImpl) RequestCampaignChanges(ctx context.Context, req *dto.AdminRequestCampaignChangesRequest) (*dto.AdminRequestCampaignChangesResponse, error) {
+	if req == nil || req.CampaignID == 0 || strings.TrimSpace(req.Comment) == "" {
+		return nil, NewBusinessError("ADMIN_REQUEST_CAMPAIGN_CHANGES_FAILED", "campaign_id and comment are required", nil)
+	}
+	comment := strings.TrimSpace(req.Comment)
+
+	var campaign *models.Campaign
+	var customer models.Customer
+
+	err := withVersionRetry(ctx, s.db, func(txCtx context.Context) error {
+		var err error
+		campaign, err = s.campaignRepo.ByID(txCtx, req.CampaignID)
+		if err != nil {
+			return err
+		}
+		if campaign == nil {
+			return ErrCampaignNotFound
+		}
+		if campaign.Status != models.CampaignStatusWaitingForApproval {
+			return ErrCampaignNotWaitingForApproval
+		}
+
+		customer, err = getCustomer(txCtx, s.customerRepo, campaign.CustomerID)
+		if err != nil {
+			return err
+		}
+
+		meta := map[string]any{
+			"source":      "admin_campaign_request_changes",
+			"operation":   "request_changes_refund_frozen",
+			"campaign_id": campaign.ID,
+			"comment":     comment,
+		}
+		freezeTx, err := s.refundCampaignReservation(
+			txCtx,
+			campaign,
+			customer,
+			meta,
+			"campaign_changes_requested_budget_refund",
+			fmt.Sprintf("Refund reserved budget for campaign %d sent back for changes", campaign.ID),
+		)
+		if err != nil {
+			return err
+		}
+		// Resubmitting reserves the budget again; retire this reservation so
+		// the new one is the only completed freeze of the campaign.
+		if err := s.transactionRepo.UpdateStatus(txCtx, freezeTx.ID, models.TransactionStatusReversed, utils.UTCNow()); err != nil {
+			return err
+		}
+
+		if err := s.saveCampaignReview(txCtx, campaign.ID, models.CampaignReviewActionChangesRequested, &comment); err != nil {
+			return err
+		}
+
+		campaign.Status = models.CampaignStatusChangesRequested
+		campaign.Comment = &comment
+		campaign.UpdatedAt = utils.ToPtr(utils.UTCNow())
+		return s.campaignRepo.Update(txCtx, *campaign)
+	})
+	if err != nil {
+		logAdminAction(ctx, s.auditRepo, models.AuditActionAdminCampaignChangesRequested, "Admin requested campaign changes", false, nil, map[string]any{
+			"campaign_id": req.CampaignID,
+			"comment":     comment,
+		}, err)
+		return nil, NewBusinessError("ADMIN_REQUEST_CAMPAIGN_CHANGES_FAILED", "Failed to request campaign changes", err)
+	}
+
+	// Notify customer (best-effort, outside transaction)
+	if s.notifier != nil {
+		title := campaign.UUID.String()
+		if campaign.Spec.Title != nil && *campaign.Spec.Title != "" {
+			title = *campaign.Spec.Title
+		}
+		msgCustomer := s.localizer.Customer(&customer, "campaign.changes_requested", i18n.Args{"Title": title})
+		pushTitle := s.localizer.Customer(&customer, "push.campaign_changes_requested.title", nil)
+		smsCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		_ = sendCustomerNotice(smsCtx, s.notifier, s.pushDeviceRepo, &customer, pushTitle, msgCustomer)
+	}
+
+	logAdminAction(ctx, s.auditRepo, models.AuditActionAdminCampaignChangesRequested, "Admin requested campaign changes", true, &customer.ID, map[string]any{
+		"campaign_id": campaign.ID,
+		"comment":     comment,
+	}, nil)
+	return &dto.AdminRequestCampaignChangesResponse{
+		Message: "Changes requested and reserved budget refunded successfully",
+	}, nil
+}
+
+// ListCampaignReviews returns the review history of a campaign, oldest first
+func (s *AdminCampaignFlowImpl) ListCampaignReviews(ctx context.Context, campaignID uint) (*dto.ListCampaignReviewsResponse, error) {
+	campaign, err := s.campaignRepo.ByID(ctx, campaignID)
+	if err != nil {
+		return nil, NewBusinessError("ADMIN_LIST_CAMPAIGN_REVIEWS_FAILED", "Failed to fetch campaign", err)
+	}
+	if campaign == nil {
+		return nil, NewBusinessError("CAMPAIGN_NOT_FOUND", "Campaign not found", ErrCampaignNotFound)
+	}
+
+	reviews, err := s.campaignReviewRepo.ByCampaignID(ctx, campaign.ID)
+	if err != nil {
+		return nil, NewBusinessError("ADMIN_LIST_CAMPAIGN_REVIEWS_FAILED", "Failed to list campaign reviews", err)
+	}
+	return buildCampaignReviewsResponse(campaign, reviews), nil
+}
+
+// AddCampaignReviewComment adds a reviewer comment to a campaign under review
+func (s *AdminCampaignFlowImpl) AddCampaignReviewComment(ctx context.Context, req *dto.AdminAddCampaignReviewCommentRequest) (*dto.AddCampaignReviewCommentResponse, error) {
+	if req == nil || req.CampaignID == 0 || strings.TrimSpace(req.Comment) == "" {
+		return nil, NewBusinessError("ADMIN_ADD_CAMPAIGN_REVIEW_COMMENT_FAILED", "campaign_id and comment are required", nil)
+	}
+	comment := strings.TrimSpace(req.Comment)
+	meta := map[string]any{
+		"campaign_id": req.CampaignID,
+		"comment":     comment,
+	}
+
+	campaign, err := s.campaignRepo.ByID(ctx, req.CampaignID)
+	if err != nil {
+		return nil, NewBusinessError("ADMIN_ADD_CAMPAIGN_REVIEW_COMMENT_FAILED", "Failed to fetch campaign", err)
+	}
+	if campaign == nil {
+		return nil, NewBusinessError("CAMPAIGN_NOT_FOUND", "Campaign not found", ErrCampaignNotFound)
+	}
+	if !isCampaignUnderReview(campaign.Status) {
+		return nil, NewBusinessError("CAMPAIGN_NOT_UNDER_REVIEW", "Campaign is not under review", ErrCampaignNotUnderReview)
+	}
+
+	review := newCampaignReview(campaign.ID, models.CampaignReviewAuthorAdmin, models.CampaignReviewActionComment, &comment)
+	if adminID, ok := adminIDFromContext(ctx); ok {
+		review.AdminID = &adminID
+	}
+	if err := s.campaignReviewRepo.Save(ctx, review); err != nil {
+		logAdminAction(ctx, s.auditRepo, models.AuditActionAdminCampaignReviewComment, "Admin campaign review comment failed", false, &campaign.CustomerID, meta, err)
+		return nil, NewBusinessError("ADMIN_ADD_CAMPAIGN_REVIEW_COMMENT_FAILED", "Failed to save review comment", err)
+	}
+	logAdminAction(ctx, s.auditRepo, models.AuditActionAdminCampaignReviewComment, "Admin commented on campaign review", true, &campaign.CustomerID, meta, nil)
+
+	return &dto.AddCampaignReviewCommentResponse{
+		Message: "Review comment added successfully",
+		Review:  toCampaignReviewItem(review),
+	}, nil
+}
+
+// saveCampaignReview records a reviewer decision in the campaign's history
+func (s *AdminCampaignFlowImpl) saveCampaignReview(ctx context.Context, campaignID uint, action models.CampaignReviewAction, comment *string) error {
+	review := newCampaignReview(campaignID, models.CampaignReviewAuthorAdmin, action, comment)
+	if adminID, ok := adminIDFromContext(ctx); ok {
+		review.AdminID = &adminID
+	}
+	return s.campaignReviewRepo.Save(ctx, review)
+}
+
+// ListCampaignReviews returns the review history of a customer's campaign,
+// including the changes requested by reviewers
+func (s *CampaignFlowImpl) ListCampaignReviews(ctx context.Context, req *dto.ListCampaignReviewsRequest) (*dto.ListCampaignReviewsResponse, error) {
+	if req == nil || strings.TrimSpace(req.UUID) == "" {
+		return nil, NewBusinessError("LIST_CAMPAIGN_REVIEWS_FAILED", "campaign uuid is required", ErrCampaignNotFound)
+	}
+
+	campaign, err := getCampaign(ctx, s.campaignRepo, req.UUID, req.CustomerID)
+	if err != nil {
+		return nil, NewBusinessError("CAMPAIGN_LOOKUP_FAILED", "Failed to lookup campaign", err)
+	}
+
+	reviews, err := s.campaignReviewRepo.ByCampaignID(ctx, campaign.ID)
+	if err != nil {
+		return nil, NewBusinessError("LIST_CAMPAIGN_REVIEWS_FAILED", "Failed to list campaign reviews", err)
+	}
+	return buildCampaignReviewsResponse(&campaign, reviews), nil
+}
+
+// AddCampaignReviewComment lets the owner reply to reviewers while the
+// campaign is under review
+func (s *CampaignFlowImpl) AddCampaignReviewComment(ctx context.Context, req *dto.AddCampaignReviewCommentRequest, metadata *ClientMetadata) (*dto.AddCampaignReviewCommentResponse, error) {
+	if req == nil || strings.TrimSpace(req.UUID) == "" || strings.TrimSpace(req.Comment) == "" {
+		return nil, NewBusinessError("ADD_CAMPAIGN_REVIEW_COMMENT_FAILED", "campaign uuid and comment are required", nil)
+	}
+	comment := strings.TrimSpace(req.Comment)
+
+	customer, err := getCustomer(ctx, s.customerRepo, req.CustomerID)
+	if err != nil {
+		return nil, NewBusinessError("CUSTOMER_LOOKUP_FAILED", "Failed to lookup customer", err)
+	}
+	campaign, err := getCampaign(ctx, s.campaignRepo, req.UUID, req.CustomerID)
+	if err != nil {
+		return nil, NewBusinessError("CAMPAIGN_LOOKUP_FAILED", "Failed to lookup campaign", err)
+	}
+	if !isCampaignUnderReview(campaign.Status) {
+		return nil, NewBusinessError("CAMPAIGN_NOT_UNDER_REVIEW", "Campaign is not under review", ErrCampaignNotUnderReview)
+	}
+
+	review := newCampaignReview(campaign.ID, models.CampaignReviewAuthorCustomer, models.CampaignReviewActionComment, &comment)
+	review.CustomerID = &customer.ID
+	if err := s.campaignReviewRepo.Save(ctx, review); err != nil {
+		errMsg := fmt.Sprintf("Review comment failed for campaign %d: %s", campaign.ID, err.Error())
+		_ = s.createAuditLog(ctx, &customer, models.AuditActionCampaignReviewComment, errMsg, false, &errMsg, metadata)
+		return nil, NewBusinessError("ADD_CAMPAIGN_REVIEW_COMMENT_FAILED", "Failed to save review comment", err)
+	}
+
+	msg := fmt.Sprintf("Review comment added to campaign %d", campaign.ID)
+	_ = s.createAuditLog(ctx, &customer, models.AuditActionCampaignReviewComment, msg, true, nil, metadata)
+
+	return &dto.AddCampaignReviewCommentResponse{
+		Message: "Review comment added successfully",
+		Review:  toCampaignReviewItem(review),
+	}, nil
+}
+
+// saveCustomerCampaignReview records a customer action in the campaign's history
+func (s *CampaignFlowImpl) saveCustomerCampaignReview(ctx context.Context, campaignID, customerID uint, action models.CampaignReviewAction, comment *string) error {
+	review := newCampaignReview(campaignID, models.CampaignReviewAuthorCustomer, action, comment)
+	review.CustomerID = &customerID
+	return s.campaignReviewRepo.Save(ctx, review)
+}
+
+// isCampaignUnderReview reports whether reviewers and the owner may still
+// discuss the campaign
+func isCampaignUnderReview(status models.CampaignStatus) bool {
+	return status == models.CampaignStatusWaitingForApproval ||
+		status == models.CampaignStatusChangesRequested
+}
+
+func newCampaignReview(campaignID uint, author models.CampaignReviewAuthorType, action models.CampaignReviewAction, comment *string) *models.CampaignReview {
+	review := &models.CampaignReview{
+		CampaignID: campaignID,
+		AuthorType: author,
+		Action:     action,
+	}
+	if comment != nil {
+		if trimmed := strings.TrimSpace(*comment); trimmed != "" {
+			review.Comment = &trimmed
+		}
+	}
+	return review
+}
+
+func buildCampaignReviewsResponse(campaign *models.Campaign, reviews []*models.CampaignReview) *dto.ListCampaignReviewsResponse {
+	items := make([]dto.CampaignReviewItem, 0, len(reviews))
+	for _, review := range reviews {
+		items = append(items, toCampaignReviewItem(review))
+	}
+	return &dto.ListCampaignReviewsResponse{
+		Message:    "Campaign reviews retrieved successfully",
+		CampaignID: campaign.ID,
+		Status:     string(campaign.Status),
+		Items:      items,
+	}
+}
+
+func toCampaignReviewItem(review *models.CampaignReview) dto.CampaignReviewItem {
+	return dto.CampaignReviewItem{
+		ID:         review.ID,
+		Action:     string(review.Action),
+		AuthorType: string(review.AuthorType),
+		AdminID:    review.AdminID,
+		Comment:    review.Comment,
+		CreatedAt:  review.CreatedAt.UTC().Format(time.RFC3339),
+	}
+}
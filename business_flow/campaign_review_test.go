@@ -0,0 +1,65 @@
+package businessflow
+
+import (
+	"testing"
+
+	"github.com/amirphl/Yamata-no-Orochi/models"
+)
+
+func TestIsCampaignUnderReview(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		status models.CampaignStatus
+		want   bool
+	}{
+		{status: models.CampaignStatusWaitingForApproval, want: true},
+		{status: models.CampaignStatusChangesRequested, want: true},
+		{status: models.CampaignStatusInProgress, want: false},
+		{status: models.CampaignStatusApproved, want: false},
+		{status: models.CampaignStatusRejected, want: false},
+	}
+	for _, tc := range cases {
+		t.Run(string(tc.status), func(t *testing.T) {
+			t.Parallel()
+			if got := isCampaignUnderReview(tc.status); got != tc.want {
+				t.Fatalf("isCampaignUnderReview(%q) = %v, want %v", tc.status, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestChangesRequestedCampaignCanBeResubmitted(t *testing.T) {
+	t.Parallel()
+
+	if !canUpdateCampaign(models.CampaignStatusChangesRequested) {
+		t.Fatalf("expected a campaign with changes requested to be editable")
+	}
+	campaign := models.Campaign{Status: models.CampaignStatusWaitingForApproval}
+	if !campaign.CanTransitionTo(models.CampaignStatusChangesRequested) {
+		t.Fatalf("expected waiting-for-approval to transition to changes-requested")
+	}
+	campaign.Status = models.CampaignStatusChangesRequested
+	if !campaign.CanTransitionTo(models.CampaignStatusWaitingForApproval) {
+		t.Fatalf("expected changes-requested to transition back to waiting-for-approval")
+	}
+	if campaign.CanTransitionTo(models.CampaignStatusApproved) {
+		t.Fatalf("expected changes-requested campaign to need resubmission before approval")
+	}
+}
+
+func TestNewCampaignReviewTrimsComment(t *testing.T) {
+	t.Parallel()
+
+	comment := "  fix the link  "
+	review := newCampaignReview(7, models.CampaignReviewAuthorAdmin, models.CampaignReviewActionChangesRequested, &comment)
+	if review.Comment == nil || *review.Comment != "fix the link" {
+		t.Fatalf("unexpected comment: %v", review.Comment)
+	}
+
+	blank := "   "
+	review = newCampaignReview(7, models.CampaignReviewAuthorCustomer, models.CampaignReviewActionResubmitted, &blank)
+	if review.Comment != nil {
+		t.Fatalf("expected blank comment to be dropped, got %q", *review.Comment)
+	}
+}
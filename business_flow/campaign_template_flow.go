@@ -0,0 +1,359 @@
+package businessflow
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/repository"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/google/uuid"
+)
+
+// CampaignTemplateFlow defines operations on reusable campaign templates for
+// customers and admin-published global templates
+type CampaignTemplateFlow interface {
+	CreateTemplate(ctx context.Context, req *dto.CreateCampaignTemplateRequest, metadata *ClientMetadata) (*dto.CampaignTemplateResponse, error)
+	ListTemplates(ctx context.Context, customerID uint) (*dto.ListCampaignTemplatesResponse, error)
+	DeleteTemplate(ctx context.Context, customerID uint, templateUUID string, metadata *ClientMetadata) (*dto.DeleteCampaignTemplateResponse, error)
+	CreateCampaignFromTemplate(ctx context.Context, req *dto.CreateCampaignFromTemplateRequest, metadata *ClientMetadata) (*dto.CreateCampaignResponse, error)
+	AdminPublishTemplate(ctx context.Context, req *dto.AdminCreateCampaignTemplateRequest) (*dto.CampaignTemplateResponse, error)
+	AdminListTemplates(ctx context.Context) (*dto.ListCampaignTemplatesResponse, error)
+	AdminDeleteTemplate(ctx context.Context, templateUUID string) (*dto.DeleteCampaignTemplateResponse, error)
+}
+
+// CampaignTemplateFlowImpl implements CampaignTemplateFlow
+type CampaignTemplateFlowImpl struct {
+	templateRepo repository.CampaignTemplateRepository
+	customerRepo repository.CustomerRepository
+	auditRepo    repository.AuditLogRepository
+	campaignFlow CampaignFlow
+}
+
+func NewCampaignTemplateFlow(
+	templateRepo repository.CampaignTemplateRepository,
+	customerRepo repository.CustomerRepository,
+	auditRepo repository.AuditLogRepository,
+	campaignFlow CampaignFlow,
+) CampaignTemplateFlow {
+	return &CampaignTemplateFlowImpl{
+		templateRepo: templateRepo,
+		customerRepo: customerRepo,
+		auditRepo:    auditRepo,
+		campaignFlow: campaignFlow,
+	}
+}
+
+func (f *CampaignTemplateFlowImpl) CreateTemplate(ctx context.Context, req *dto.CreateCampaignTemplateRequest, metadata *ClientMetadata) (*dto.CampaignTemplateResponse, error) {
+	if req == nil {
+		return nil, NewBusinessError("INVALID_REQUEST", "request is required", nil)
+	}
+
+	customer, err := getCustomer(ctx, f.customerRepo, req.CustomerID)
+	if err != nil {
+		return nil, NewBusinessError("CUSTOMER_LOOKUP_FAILED", "Failed to lookup customer", err)
+	}
+
+	template, err := f.buildTemplate(ctx, &customer.ID, req.Name, req.Spec)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := f.templateRepo.Save(ctx, template); err != nil {
+		errMsg := fmt.Sprintf("Campaign template creation failed: %v", err)
+		f.createAuditLog(ctx, customer.ID, models.AuditActionCampaignTemplateCreated, errMsg, false, &errMsg, metadata)
+		return nil, NewBusinessError("CAMPAIGN_TEMPLATE_SAVE_FAILED", "Failed to save campaign template", err)
+	}
+
+	f.createAuditLog(ctx, customer.ID, models.AuditActionCampaignTemplateCreated, fmt.Sprintf("Campaign template created: %s", template.UUID), true, nil, metadata)
+	return &dto.CampaignTemplateResponse{
+		Message:  "Campaign template created successfully",
+		Template: toCampaignTemplateItem(template),
+	}, nil
+}
+
+func (f *CampaignTemplateFlowImpl) ListTemplates(ctx context.Context, customerID uint) (*dto.ListCampaignTemplatesResponse, error) {
+	rows, err := f.templateRepo.ListVisibleToCustomer(ctx, customerID)
+	if err != nil {
+		return nil, NewBusinessError("CAMPAIGN_TEMPLATE_LIST_FAILED", "Failed to list campaign templates", err)
+	}
+	return &dto.ListCampaignTemplatesResponse{
+		Message: "Campaign templates retrieved successfully",
+		Items:   toCampaignTemplateItems(rows),
+	}, nil
+}
+
+func (f *CampaignTemplateFlowImpl) DeleteTemplate(ctx context.Context, customerID uint, templateUUID string, metadata *ClientMetadata) (*dto.DeleteCampaignTemplateResponse, error) {
+	template, err := f.getTemplate(ctx, templateUUID)
+	if err != nil {
+		return nil, err
+	}
+	// Customers may only delete their own templates; global ones are managed by admins
+	if template.CustomerID == nil || *template.CustomerID != customerID {
+		return nil, NewBusinessError("CAMPAIGN_TEMPLATE_NOT_FOUND", "Campaign template not found", ErrCampaignTemplateNotFound)
+	}
+
+	if err := f.templateRepo.Delete(ctx, template.ID); err != nil {
+		return nil, NewBusinessError("CAMPAIGN_TEMPLATE_DELETE_FAILED", "Failed to delete campaign template", err)
+	}
+
+	f.createAuditLog(ctx, customerID, models.AuditActionCampaignTemplateDeleted, fmt.Sprintf("Campaign template deleted: %s", template.UUID), true, nil, metadata)
+	return &dto.DeleteCampaignTemplateResponse{
+		Message: "Campaign template deleted successfully",
+	}, nil
+}
+
+// CreateCampaignFromTemplate creates a campaign through the regular creation
+// flow so templates are subject to exactly the same validation as new campaigns.
+func (f *CampaignTemplateFlowImpl) CreateCampaignFromTemplate(ctx context.Context, req *dto.CreateCampaignFromTemplateRequest, metadata *ClientMetadata) (*dto.CreateCampaignResponse, error) {
+	if req == nil {
+		return nil, NewBusinessError("INVALID_REQUEST", "request is required", nil)
+	}
+
+	template, err := f.getTemplate(ctx, req.UUID)
+	if err != nil {
+		return nil, err
+	}
+	if template.CustomerID != nil && *template.CustomerID != req.CustomerID {
+		return nil, NewBusinessError("CAMPAIGN_TEMPLATE_NOT_FOUND", "Campaign template not found", ErrCampaignTemplateNotFound)
+	}
+
+	spec := template.Spec
+	title := spec.Title
+	if req.Title != nil && strings.TrimSpace(*req.Title) != "" {
+		title = req.Title
+	}
+
+	return f.campaignFlow.CreateCampaign(ctx, &dto.CreateCampaignRequest{
+		CustomerID:         req.CustomerID,
+		Title:              title,
+		Level1:             spec.Level1,
+		Level2s:            spec.Level2s,
+		Level3s:            spec.Level3s,
+		Tags:               spec.Tags,
+		Sex:                spec.Sex,
+		City:               spec.City,
+		AdLink:             spec.AdLink,
+		Content:            spec.Content,
+		ShortLinkDomain:    spec.ShortLinkDomain,
+		Category:           spec.Category,
+		Job:                spec.Job,
+		ScheduleAt:         req.ScheduleAt,
+		LineNumber:         spec.LineNumber,
+		MediaUUID:          spec.MediaUUID,
+		PlatformSettingsID: spec.PlatformSettingsID,
+		Platform:           &spec.Platform,
+		Budget:             req.Budget,
+		BundleID:           req.BundleID,
+		Phase:              req.Phase,
+		AudienceGrades:     spec.AudienceGrades,
+	}, metadata)
+}
+
+func (f *CampaignTemplateFlowImpl) AdminPublishTemplate(ctx context.Context, req *dto.AdminCreateCampaignTemplateRequest) (*dto.CampaignTemplateResponse, error) {
+	if req == nil {
+		return nil, NewBusinessError("INVALID_REQUEST", "request is required", nil)
+	}
+
+	template, err := f.buildTemplate(ctx, nil, req.Name, req.Spec)
+	if err != nil {
+		return nil, err
+	}
+	if adminID, ok := adminIDFromContext(ctx); ok {
+		template.PublishedByAdminID = &adminID
+	}
+
+	if err := f.templateRepo.Save(ctx, template); err != nil {
+		logAdminAction(ctx, f.auditRepo, models.AuditActionAdminCampaignTemplatePublish, "Admin publish campaign template", false, nil, campaignTemplateAuditMetadata(template), err)
+		return nil, NewBusinessError("CAMPAIGN_TEMPLATE_SAVE_FAILED", "Failed to save campaign template", err)
+	}
+
+	logAdminAction(ctx, f.auditRepo, models.AuditActionAdminCampaignTemplatePublish, "Admin publish campaign template", true, nil, campaignTemplateAuditMetadata(template), nil)
+	return &dto.CampaignTemplateResponse{
+		Message:  "Campaign template published successfully",
+		Template: toCampaignTemplateItem(template),
+	}, nil
+}
+
+func (f *CampaignTemplateFlowImpl) AdminListTemplates(ctx context.Context) (*dto.ListCampaignTemplatesResponse, error) {
+	rows, err := f.templateRepo.ByFilter(ctx, models.CampaignTemplateFilter{Global: utils.ToPtr(true)}, "id DESC", 0, 0)
+	if err != nil {
+		return nil, NewBusinessError("CAMPAIGN_TEMPLATE_LIST_FAILED", "Failed to list campaign templates", err)
+	}
+	return &dto.ListCampaignTemplatesResponse{
+		Message: "Campaign templates retrieved successfully",
+		Items:   toCampaignTemplateItems(rows),
+	}, nil
+}
+
+func (f *CampaignTemplateFlowImpl) AdminDeleteTemplate(ctx context.Context, templateUUID string) (*dto.DeleteCampaignTemplateResponse, error) {
+	template, err := f.getTemplate(ctx, templateUUID)
+	if err != nil {
+		return nil, err
+	}
+	if !template.IsGlobal() {
+		return nil, NewBusinessError("CAMPAIGN_TEMPLATE_NOT_FOUND", "Campaign template not found", ErrCampaignTemplateNotFound)
+	}
+
+	if err := f.templateRepo.Delete(ctx, template.ID); err != nil {
+		logAdminAction(ctx, f.auditRepo, models.AuditActionAdminCampaignTemplateDelete, "Admin delete campaign template", false, nil, campaignTemplateAuditMetadata(template), err)
+		return nil, NewBusinessError("CAMPAIGN_TEMPLATE_DELETE_FAILED", "Failed to delete campaign template", err)
+	}
+
+	logAdminAction(ctx, f.auditRepo, models.AuditActionAdminCampaignTemplateDelete, "Admin delete campaign template", true, nil, campaignTemplateAuditMetadata(template), nil)
+	return &dto.DeleteCampaignTemplateResponse{
+		Message: "Campaign template deleted successfully",
+	}, nil
+}
+
+// buildTemplate validates the name and spec and ensures the name is unique
+// within the owner's templates (or among global templates when customerID is nil).
+func (f *CampaignTemplateFlowImpl) buildTemplate(ctx context.Context, customerID *uint, name string, spec dto.CampaignTemplateSpec) (*models.CampaignTemplate, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, NewBusinessError("CAMPAIGN_TEMPLATE_VALIDATION_FAILED", "Campaign template name is required", ErrCampaignTemplateNameRequired)
+	}
+
+	platform, err := sanitizeCampaignPlatform(spec.Platform)
+	if err != nil {
+		return nil, NewBusinessError("CAMPAIGN_TEMPLATE_VALIDATION_FAILED", "Campaign template validation failed", err)
+	}
+	shortLinkDomain, err := sanitizeShortLinkDomain(spec.ShortLinkDomain)
+	if err != nil {
+		return nil, NewBusinessError("CAMPAIGN_TEMPLATE_VALIDATION_FAILED", "Campaign template validation failed", err)
+	}
+	grades, err := sanitizeAudienceGrades(spec.AudienceGrades)
+	if err != nil {
+		return nil, NewBusinessError("CAMPAIGN_TEMPLATE_VALIDATION_FAILED", "Campaign template validation failed", err)
+	}
+
+	filter := models.CampaignTemplateFilter{Name: &name}
+	if customerID != nil {
+		filter.CustomerID = customerID
+	} else {
+		filter.Global = utils.ToPtr(true)
+	}
+	exists, err := f.templateRepo.Exists(ctx, filter)
+	if err != nil {
+		return nil, NewBusinessError("CAMPAIGN_TEMPLATE_LOOKUP_FAILED", "Failed to lookup campaign templates", err)
+	}
+	if exists {
+		return nil, NewBusinessError("CAMPAIGN_TEMPLATE_ALREADY_EXISTS", "Campaign template with this name already exists", ErrCampaignTemplateAlreadyExists)
+	}
+
+	now := utils.UTCNow()
+	return &models.CampaignTemplate{
+		UUID:       uuid.New(),
+		CustomerID: customerID,
+		Name:       name,
+		Spec: models.CampaignSpec{
+			Title:              spec.Title,
+			Level1:             spec.Level1,
+			Level2s:            spec.Level2s,
+			Level3s:            spec.Level3s,
+			Tags:               spec.Tags,
+			AudienceGrades:     campaignAudienceGradesOrDefault(grades),
+			Sex:                spec.Sex,
+			City:               spec.City,
+			AdLink:             spec.AdLink,
+			Content:            spec.Content,
+			LineNumber:         spec.LineNumber,
+			MediaUUID:          spec.MediaUUID,
+			PlatformSettingsID: spec.PlatformSettingsID,
+			Platform:           platform,
+			ShortLinkDomain:    shortLinkDomain,
+			Category:           spec.Category,
+			Job:                spec.Job,
+		},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, nil
+}
+
+func (f *CampaignTemplateFlowImpl) getTemplate(ctx context.Context, templateUUID string) (*models.CampaignTemplate, error) {
+	id, err := uuid.Parse(strings.TrimSpace(templateUUID))
+	if err != nil {
+		return nil, NewBusinessError("CAMPAIGN_TEMPLATE_NOT_FOUND", "Campaign template not found", ErrCampaignTemplateNotFound)
+	}
+	template, err := f.templateRepo.ByUUID(ctx, id)
+	if err != nil {
+		return nil, NewBusinessError("CAMPAIGN_TEMPLATE_LOOKUP_FAILED", "Failed to lookup campaign template", err)
+	}
+	if template == nil {
+		return nil, NewBusinessError("CAMPAIGN_TEMPLATE_NOT_FOUND", "Campaign template not found", ErrCampaignTemplateNotFound)
+	}
+	return template, nil
+}
+
+// createAuditLog records a customer template action; failures are ignored
+func (f *CampaignTemplateFlowImpl) createAuditLog(ctx context.Context, customerID uint, action, description string, success bool, errorMsg *string, metadata *ClientMetadata) {
+	ipAddress := ""
+	userAgent := ""
+	if metadata != nil {
+		ipAddress = metadata.IPAddress
+		userAgent = metadata.UserAgent
+	}
+
+	audit := &models.AuditLog{
+		CustomerID:   &customerID,
+		Action:       action,
+		Description:  &description,
+		Success:      utils.ToPtr(success),
+		IPAddress:    &ipAddress,
+		UserAgent:    &userAgent,
+		ErrorMessage: errorMsg,
+	}
+	if requestID, ok := ctx.Value(utils.RequestIDKey).(string); ok {
+		audit.RequestID = &requestID
+	}
+	_ = f.auditRepo.Save(ctx, audit)
+}
+
+func toCampaignTemplateItems(rows []*models.CampaignTemplate) []dto.CampaignTemplateItem {
+	items := make([]dto.CampaignTemplateItem, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, toCampaignTemplateItem(row))
+	}
+	return items
+}
+
+func toCampaignTemplateItem(t *models.CampaignTemplate) dto.CampaignTemplateItem {
+	spec := t.Spec
+	return dto.CampaignTemplateItem{
+		UUID:   t.UUID.String(),
+		Name:   t.Name,
+		Global: t.IsGlobal(),
+		Spec: dto.CampaignTemplateSpec{
+			Title:              spec.Title,
+			Level1:             spec.Level1,
+			Level2s:            spec.Level2s,
+			Level3s:            spec.Level3s,
+			Tags:               spec.Tags,
+			Sex:                spec.Sex,
+			City:               spec.City,
+			AdLink:             spec.AdLink,
+			Content:            spec.Content,
+			ShortLinkDomain:    spec.ShortLinkDomain,
+			Category:           spec.Category,
+			Job:                spec.Job,
+			LineNumber:         spec.LineNumber,
+			MediaUUID:          spec.MediaUUID,
+			PlatformSettingsID: spec.PlatformSettingsID,
+			Platform:           &spec.Platform,
+			AudienceGrades:     spec.AudienceGrades,
+		},
+		CreatedAt: t.CreatedAt.Format(time.RFC3339),
+		UpdatedAt: t.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+func campaignTemplateAuditMetadata(t *models.CampaignTemplate) map[string]any {
+	return map[string]any{
+		"uuid":     t.UUID.String(),
+		"name":     t.Name,
+		"platform": t.Spec.Platform,
+	}
+}
@@ -16,7 +16,10 @@ import (
 	"github.com/amirphl/Yamata-no-Orochi/app/dto"
 	"github.com/amirphl/Yamata-no-Orochi/app/scheduler"
 	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/personalization"
+	"github.com/amirphl/Yamata-no-Orochi/pricing"
 	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/amirphl/Yamata-no-Orochi/utils/phonenumber"
 	"github.com/google/uuid"
 )
 
@@ -33,7 +36,7 @@ func (s *CampaignFlowImpl) SendCampaignTestMessage(ctx context.Context, req *dto
 	if req.CustomerID == 0 {
 		return nil, NewBusinessError("CAMPAIGN_TEST_SEND_INVALID_REQUEST", "customer id is required", ErrCustomerNotFound)
 	}
-	recipient := strings.TrimSpace(req.TargetPhoneNumber)
+	recipient := phonenumber.CanonicalOrRaw(strings.TrimSpace(req.TargetPhoneNumber))
 	if recipient == "" {
 		return nil, NewBusinessError("CAMPAIGN_TEST_SEND_RECIPIENT_MISSING", "target phone number is missing", ErrCampaignTestRecipientMissing)
 	}
@@ -176,7 +179,9 @@ func buildCampaignTestMessageBody(platform string, contentPtr *string, resolvedL
 	}
 	content = strings.ReplaceAll(content, "{YOUR_LINK}", replacement)
 	if platform == models.CampaignPlatformSMS {
-		return content + "\n" + "لغو۱۱"
+		// Test recipients have no profile; variables render their fallbacks.
+		content = personalization.Render(content, nil)
+		return content + pricing.SMSOptOutSuffix
 	}
 	return content
 }
@@ -0,0 +1,375 @@
+package businessflow
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+	"unicode/utf8"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/repository"
+)
+
+const (
+	// campaignTimelineAuditLimit and campaignTimelineJobLimit bound the
+	// audit entries and status jobs read into one timeline
+	campaignTimelineAuditLimit = 500
+	campaignTimelineJobLimit   = 1000
+	// campaignTimelineResponseLimit caps the raw provider response kept in a
+	// provider_response event, in runes
+	campaignTimelineResponseLimit = 2000
+)
+
+// Kinds of campaign timeline events
+const (
+	TimelineEventCreated          = "created"
+	TimelineEventAudit            = "audit"
+	TimelineEventReview           = "review"
+	TimelineEventPrepared         = "prepared"
+	TimelineEventBatchSent        = "batch_sent"
+	TimelineEventProviderResponse = "provider_response"
+	TimelineEventExecutionStopped = "execution_stopped"
+	TimelineEventDeleted          = "deleted"
+)
+
+// campaignTimelineSkippedActions are audit actions that mention a campaign
+// without changing it
+var campaignTimelineSkippedActions = map[string]bool{
+	models.AuditActionAdminCampaignGet:  true,
+	models.AuditActionAdminCampaignList: true,
+}
+
+// CampaignTimelineFlow assembles what happened to a campaign from the tables
+// its lifecycle writes to: the campaign itself, audit logs, reviews,
+// processed campaigns, status jobs and delivery status results
+type CampaignTimelineFlow interface {
+	GetTimeline(ctx context.Context, campaignID uint) (*dto.AdminCampaignTimelineResponse, error)
+}
+
+type CampaignTimelineFlowImpl struct {
+	campaignRepo          repository.CampaignRepository
+	auditRepo             repository.AuditLogRepository
+	campaignReviewRepo    repository.CampaignReviewRepository
+	processedCampaignRepo repository.ProcessedCampaignRepository
+	statusJobRepo         repository.CampaignStatusJobRepository
+	smsStatusRepo         repository.SMSStatusResultRepository
+	baleStatusRepo        repository.BaleStatusResultRepository
+	rubikaStatusRepo      repository.RubikaStatusResultRepository
+	splusStatusRepo       repository.SplusStatusResultRepository
+}
+
+func NewCampaignTimelineFlow(
+	campaignRepo repository.CampaignRepository,
+	auditRepo repository.AuditLogRepository,
+	campaignReviewRepo repository.CampaignReviewRepository,
+	processedCampaignRepo repository.ProcessedCampaignRepository,
+	statusJobRepo repository.CampaignStatusJobRepository,
+	smsStatusRepo repository.SMSStatusResultRepository,
+	baleStatusRepo repository.BaleStatusResultRepository,
+	rubikaStatusRepo repository.RubikaStatusResultRepository,
+	splusStatusRepo repository.SplusStatusResultRepository,
+) CampaignTimelineFlow {
+	return &CampaignTimelineFlowImpl{
+		campaignRepo:          campaignRepo,
+		auditRepo:             auditRepo,
+		campaignReviewRepo:    campaignReviewRepo,
+		processedCampaignRepo: processedCampaignRepo,
+		statusJobRepo:         statusJobRepo,
+		smsStatusRepo:         smsStatusRepo,
+		baleStatusRepo:        baleStatusRepo,
+		rubikaStatusRepo:      rubikaStatusRepo,
+		splusStatusRepo:       splusStatusRepo,
+	}
+}
+
+// timedEvent keeps the event time sortable until the response is built
+type timedEvent struct {
+	at    time.Time
+	event dto.CampaignTimelineEvent
+}
+
+// GetTimeline returns the events of a campaign, deleted or not, oldest first,
+// with the aggregated delivery reports of its sends
+func (f *CampaignTimelineFlowImpl) GetTimeline(ctx context.Context, campaignID uint) (*dto.AdminCampaignTimelineResponse, error) {
+	campaigns, err := f.campaignRepo.ByFilter(ctx, models.CampaignFilter{ID: &campaignID, IncludeDeleted: true}, "", 1, 0)
+	if err != nil {
+		return nil, NewBusinessError("ADMIN_CAMPAIGN_TIMELINE_FAILED", "Failed to fetch campaign", err)
+	}
+	if len(campaigns) == 0 {
+		return nil, NewBusinessError("CAMPAIGN_NOT_FOUND", "Campaign not found", ErrCampaignNotFound)
+	}
+	campaign := campaigns[0]
+
+	events := campaignLifecycleEvents(campaign)
+	truncated := false
+
+	audits, err := f.auditRepo.ListByCampaign(ctx, campaign.ID, campaignTimelineAuditLimit+1)
+	if err != nil {
+		return nil, NewBusinessError("ADMIN_CAMPAIGN_TIMELINE_FAILED", "Failed to list campaign audit logs", err)
+	}
+	if len(audits) > campaignTimelineAuditLimit {
+		audits, truncated = audits[:campaignTimelineAuditLimit], true
+	}
+	for _, a := range audits {
+		if campaignTimelineSkippedActions[a.Action] {
+			continue
+		}
+		events = append(events, auditTimelineEvent(a))
+	}
+
+	reviews, err := f.campaignReviewRepo.ByCampaignID(ctx, campaign.ID)
+	if err != nil {
+		return nil, NewBusinessError("ADMIN_CAMPAIGN_TIMELINE_FAILED", "Failed to list campaign reviews", err)
+	}
+	for _, r := range reviews {
+		events = append(events, reviewTimelineEvent(r))
+	}
+
+	processed, err := f.processedCampaignRepo.ByFilter(ctx, models.ProcessedCampaignFilter{CampaignID: &campaign.ID}, "id ASC", 0, 0)
+	if err != nil {
+		return nil, NewBusinessError("ADMIN_CAMPAIGN_TIMELINE_FAILED", "Failed to list processed campaigns", err)
+	}
+	jobBudget := campaignTimelineJobLimit
+	for _, pc := range processed {
+		events = append(events, preparedTimelineEvent(pc))
+		if jobBudget == 0 {
+			truncated = true
+			continue
+		}
+		jobs, err := f.statusJobRepo.ListByProcessedCampaign(ctx, pc.ID, jobBudget+1)
+		if err != nil {
+			return nil, NewBusinessError("ADMIN_CAMPAIGN_TIMELINE_FAILED", "Failed to list campaign status jobs", err)
+		}
+		if len(jobs) > jobBudget {
+			jobs, truncated = jobs[:jobBudget], true
+		}
+		jobBudget -= len(jobs)
+		for _, job := range jobs {
+			events = append(events, statusJobTimelineEvents(job)...)
+		}
+	}
+
+	delivery, err := f.deliverySummary(ctx, campaign, processed)
+	if err != nil {
+		return nil, NewBusinessError("ADMIN_CAMPAIGN_TIMELINE_FAILED", "Failed to aggregate campaign delivery", err)
+	}
+
+	sort.SliceStable(events, func(i, j int) bool { return events[i].at.Before(events[j].at) })
+	out := make([]dto.CampaignTimelineEvent, 0, len(events))
+	for _, e := range events {
+		e.event.At = e.at.UTC().Format(time.RFC3339)
+		out = append(out, e.event)
+	}
+
+	return &dto.AdminCampaignTimelineResponse{
+		Message:    "Campaign timeline retrieved successfully",
+		CampaignID: campaign.ID,
+		Status:     string(campaign.Status),
+		Events:     out,
+		Delivery:   delivery,
+		Truncated:  truncated,
+	}, nil
+}
+
+// deliverySummary sums the delivery status results of every processed run
+// of the campaign on its platform; nil when the campaign was never prepared
+func (f *CampaignTimelineFlowImpl) deliverySummary(ctx context.Context, campaign *models.Campaign, processed []*models.ProcessedCampaign) (*dto.CampaignDeliverySummary, error) {
+	if len(processed) == 0 {
+		return nil, nil
+	}
+	platform := campaign.Spec.Platform
+	sent, err := f.processedCampaignRepo.SentAudienceCount(ctx, campaign.ID)
+	if err != nil {
+		return nil, err
+	}
+	summary := &dto.CampaignDeliverySummary{Platform: platform, SentAudience: sent}
+	for _, pc := range processed {
+		var agg repository.SMSStatusAggregates
+		switch platform {
+		case models.CampaignPlatformSMS:
+			a, err := f.smsStatusRepo.AggregateByCampaign(ctx, pc.ID)
+			if err != nil {
+				return nil, err
+			}
+			agg = *a
+		case models.CampaignPlatformBale:
+			a, err := f.baleStatusRepo.AggregateByCampaign(ctx, pc.ID)
+			if err != nil {
+				return nil, err
+			}
+			agg = repository.SMSStatusAggregates(*a)
+		case models.CampaignPlatformRubika:
+			a, err := f.rubikaStatusRepo.AggregateByCampaign(ctx, pc.ID)
+			if err != nil {
+				return nil, err
+			}
+			agg = repository.SMSStatusAggregates(*a)
+		case models.CampaignPlatformSPlus:
+			a, err := f.splusStatusRepo.AggregateByCampaign(ctx, pc.ID)
+			if err != nil {
+				return nil, err
+			}
+			agg = repository.SMSStatusAggregates(*a)
+		default:
+			continue
+		}
+		summary.Reported += agg.AggregatedTotalRecords
+		summary.FullyDelivered += agg.AggregatedTotalSent
+		summary.TotalParts += agg.AggregatedTotalParts
+		summary.DeliveredParts += agg.AggregatedDeliveredParts
+		summary.UndeliveredParts += agg.AggregatedUndelivered
+		summary.UnknownParts += agg.AggregatedUnknown
+	}
+	return summary, nil
+}
+
+// campaignLifecycleEvents are the events recorded on the campaign row itself
+func campaignLifecycleEvents(c *models.Campaign) []timedEvent {
+	events := []timedEvent{{at: c.CreatedAt, event: dto.CampaignTimelineEvent{
+		Kind:   TimelineEventCreated,
+		Source: "campaign",
+		Title:  "Campaign created",
+	}}}
+	if c.ExecutionStoppedAt != nil {
+		details := map[string]any{}
+		if c.SentBeforeStop != nil {
+			details["sent_before_stop"] = *c.SentBeforeStop
+		}
+		events = append(events, timedEvent{at: *c.ExecutionStoppedAt, event: dto.CampaignTimelineEvent{
+			Kind:    TimelineEventExecutionStopped,
+			Source:  "campaign",
+			Title:   "Execution stopped before all batches were sent",
+			Details: details,
+		}})
+	}
+	if c.DeletedAt.Valid {
+		events = append(events, timedEvent{at: c.DeletedAt.Time, event: dto.CampaignTimelineEvent{
+			Kind:   TimelineEventDeleted,
+			Source: "campaign",
+			Title:  "Campaign deleted by an admin",
+		}})
+	}
+	return events
+}
+
+func auditTimelineEvent(a *models.AuditLog) timedEvent {
+	title := a.Action
+	if a.Description != nil && *a.Description != "" {
+		title = *a.Description
+	}
+	var details map[string]any
+	if len(a.Metadata) > 0 {
+		_ = json.Unmarshal(a.Metadata, &details)
+	}
+	if details == nil {
+		details = map[string]any{}
+	}
+	details["action"] = a.Action
+	if a.ErrorMessage != nil {
+		details["error"] = *a.ErrorMessage
+	}
+	return timedEvent{at: a.CreatedAt, event: dto.CampaignTimelineEvent{
+		Kind:    TimelineEventAudit,
+		Source:  "audit_log",
+		Title:   title,
+		Success: a.Success,
+		Details: details,
+	}}
+}
+
+func reviewTimelineEvent(r *models.CampaignReview) timedEvent {
+	details := map[string]any{
+		"action":      string(r.Action),
+		"author_type": string(r.AuthorType),
+	}
+	if r.AdminID != nil {
+		details["admin_id"] = *r.AdminID
+	}
+	if r.Comment != nil {
+		details["comment"] = *r.Comment
+	}
+	return timedEvent{at: r.CreatedAt, event: dto.CampaignTimelineEvent{
+		Kind:    TimelineEventReview,
+		Source:  "campaign_review",
+		Title:   "Review: " + string(r.Action),
+		Details: details,
+	}}
+}
+
+func preparedTimelineEvent(pc *models.ProcessedCampaign) timedEvent {
+	details := map[string]any{
+		"processed_campaign_id": pc.ID,
+		"audience":              len(pc.AudienceIDs),
+		"blacklisted_excluded":  pc.BlacklistedExcluded,
+		"quota_excluded":        pc.QuotaExcluded,
+		"frequency_capped":      pc.FrequencyCapped,
+	}
+	if len(pc.Statistics) > 0 {
+		var stats map[string]any
+		if json.Unmarshal(pc.Statistics, &stats) == nil && len(stats) > 0 {
+			details["statistics"] = stats
+		}
+	}
+	return timedEvent{at: pc.CreatedAt, event: dto.CampaignTimelineEvent{
+		Kind:    TimelineEventPrepared,
+		Source:  "processed_campaign",
+		Title:   "Audience resolved for sending",
+		Details: details,
+	}}
+}
+
+// statusJobTimelineEvents turns a status job into the batch it follows and,
+// once the job ran, the provider's delivery report for that batch
+func statusJobTimelineEvents(job *models.CampaignStatusJob) []timedEvent {
+	events := []timedEvent{{at: job.CreatedAt, event: dto.CampaignTimelineEvent{
+		Kind:   TimelineEventBatchSent,
+		Source: "campaign_status_job",
+		Title:  "Batch handed to " + job.Platform,
+		Details: map[string]any{
+			"status_job_id":  job.ID,
+			"correlation_id": job.CorrelationID,
+			"platform":       job.Platform,
+			"recipients":     len(job.TrackingIDs),
+		},
+	}}}
+	// Skipped checks never queried the provider
+	if (job.ExecutedAt == nil && job.Error == nil) || job.Status == models.CampaignStatusJobSkipped {
+		return events
+	}
+
+	at := job.UpdatedAt
+	if job.ExecutedAt != nil {
+		at = *job.ExecutedAt
+	}
+	success := job.ExecutedAt != nil && job.Error == nil
+	details := map[string]any{
+		"status_job_id": job.ID,
+		"status":        job.Status,
+		"retry_count":   job.RetryCount,
+	}
+	if job.Status == models.CampaignStatusJobPending {
+		details["next_run_at"] = job.NextRunAt
+	}
+	if job.Error != nil {
+		details["error"] = *job.Error
+	}
+	if job.RawProviderResponse != nil {
+		details["provider_response"] = truncateRunes(*job.RawProviderResponse, campaignTimelineResponseLimit)
+	}
+	return append(events, timedEvent{at: at, event: dto.CampaignTimelineEvent{
+		Kind:    TimelineEventProviderResponse,
+		Source:  "campaign_status_job",
+		Title:   "Delivery status fetched from " + job.Platform,
+		Success: &success,
+		Details: details,
+	}})
+}
+
+func truncateRunes(s string, limit int) string {
+	if utf8.RuneCountInString(s) <= limit {
+		return s
+	}
+	return string([]rune(s)[:limit]) + "…"
+}
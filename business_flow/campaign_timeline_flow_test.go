@@ -0,0 +1,73 @@
+package businessflow
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/lib/pq"
+)
+
+func TestStatusJobTimelineEvents(t *testing.T) {
+	t.Parallel()
+
+	sent := time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+	executed := sent.Add(10 * time.Minute)
+	base := models.CampaignStatusJob{
+		ID:          7,
+		Platform:    models.CampaignPlatformSMS,
+		TrackingIDs: pq.StringArray{"a", "b", "c"},
+		CreatedAt:   sent,
+		UpdatedAt:   sent,
+	}
+
+	pending := base
+	events := statusJobTimelineEvents(&pending)
+	if len(events) != 1 || events[0].event.Kind != TimelineEventBatchSent || !events[0].at.Equal(sent) {
+		t.Fatalf("pending job: got %+v, want only the batch event", events)
+	}
+	if got := events[0].event.Details["recipients"]; got != 3 {
+		t.Fatalf("pending job: got %v recipients, want 3", got)
+	}
+
+	done := base
+	done.ExecutedAt = &executed
+	done.RawProviderResponse = utils.ToPtr(strings.Repeat("x", campaignTimelineResponseLimit+10))
+	events = statusJobTimelineEvents(&done)
+	if len(events) != 2 || events[1].event.Kind != TimelineEventProviderResponse || !events[1].at.Equal(executed) {
+		t.Fatalf("executed job: got %+v, want a provider response at execution", events)
+	}
+	if events[1].event.Success == nil || !*events[1].event.Success {
+		t.Fatal("executed job: provider response should be successful")
+	}
+	if got := events[1].event.Details["provider_response"].(string); len([]rune(got)) != campaignTimelineResponseLimit+1 {
+		t.Fatalf("executed job: provider response of %d runes was not truncated", len([]rune(got)))
+	}
+
+	failed := base
+	failed.UpdatedAt = executed
+	failed.RetryCount = 3
+	failed.Error = utils.ToPtr("provider timeout")
+	events = statusJobTimelineEvents(&failed)
+	if len(events) != 2 || events[1].event.Success == nil || *events[1].event.Success || !events[1].at.Equal(executed) {
+		t.Fatalf("failed job: got %+v, want an unsuccessful provider response at last update", events)
+	}
+}
+
+func TestCampaignLifecycleEvents(t *testing.T) {
+	t.Parallel()
+
+	created := time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC)
+	stopped := created.Add(48 * time.Hour)
+	c := &models.Campaign{ID: 1, CreatedAt: created, ExecutionStoppedAt: &stopped, SentBeforeStop: utils.ToPtr(uint64(120))}
+
+	events := campaignLifecycleEvents(c)
+	if len(events) != 2 || events[0].event.Kind != TimelineEventCreated || events[1].event.Kind != TimelineEventExecutionStopped {
+		t.Fatalf("got %+v, want created and execution_stopped", events)
+	}
+	if got := events[1].event.Details["sent_before_stop"]; got != uint64(120) {
+		t.Fatalf("got sent_before_stop %v, want 120", got)
+	}
+}
@@ -0,0 +1,132 @@
+package businessflow
+
+import (
+	"context"
+	"strings"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/models"
+)
+
+const (
+	minCampaignVariants = 2
+	maxCampaignVariants = 3
+)
+
+// GetCampaignVariantStats reports sent, delivered and clicked recipients per
+// A/B content variant of a customer's campaign.
+func (s *CampaignFlowImpl) GetCampaignVariantStats(ctx context.Context, req *dto.GetCampaignVariantStatsRequest) (*dto.GetCampaignVariantStatsResponse, error) {
+	if req == nil || strings.TrimSpace(req.UUID) == "" {
+		return nil, NewBusinessError("CAMPAIGN_VARIANT_STATS_VALIDATION_FAILED", "campaign uuid is required", ErrCampaignUUIDRequired)
+	}
+
+	campaign, err := getCampaign(ctx, s.campaignRepo, req.UUID, req.CustomerID)
+	if err != nil {
+		return nil, NewBusinessError("CAMPAIGN_LOOKUP_FAILED", "Failed to lookup campaign", err)
+	}
+	if len(campaign.Spec.Variants) == 0 {
+		return nil, NewBusinessError("CAMPAIGN_HAS_NO_VARIANTS", "Campaign has no content variants", ErrCampaignHasNoVariants)
+	}
+
+	aggs, err := s.smsStatusResultRepo.AggregateByVariant(ctx, campaign.ID)
+	if err != nil {
+		return nil, NewBusinessError("CAMPAIGN_VARIANT_STATS_FAILED", "Failed to aggregate campaign variant statistics", err)
+	}
+	byLabel := make(map[string]dto.CampaignVariantStats, len(aggs))
+	for _, a := range aggs {
+		byLabel[a.Variant] = dto.CampaignVariantStats{
+			Sent:      a.Sent,
+			Delivered: a.Delivered,
+			Clicks:    a.Clicks,
+		}
+	}
+
+	items := make([]dto.CampaignVariantStats, 0, len(campaign.Spec.Variants))
+	for _, v := range campaign.Spec.Variants {
+		item := byLabel[v.Label]
+		item.Label = v.Label
+		item.Weight = v.Weight
+		if item.Sent > 0 {
+			rate := float64(item.Delivered) / float64(item.Sent)
+			item.DeliveryRate = &rate
+		}
+		// Click rate is relative to delivered messages, like the campaign list.
+		item.ClickRate = computeClickRate(item.Clicks, float64(item.Delivered))
+		items = append(items, item)
+	}
+
+	return &dto.GetCampaignVariantStatsResponse{
+		Message:  "Campaign variant statistics retrieved successfully",
+		Variants: items,
+	}, nil
+}
+
+// buildCampaignVariants converts requested content variants into the stored
+// form. Nil or empty specs mean the campaign is not A/B tested.
+func buildCampaignVariants(specs []dto.CampaignContentVariantSpec) ([]models.CampaignContentVariant, error) {
+	if len(specs) == 0 {
+		return nil, nil
+	}
+	if len(specs) < minCampaignVariants || len(specs) > maxCampaignVariants {
+		return nil, ErrCampaignVariantsInvalid
+	}
+
+	variants := make([]models.CampaignContentVariant, 0, len(specs))
+	labels := make(map[string]struct{}, len(specs))
+	var totalWeight uint
+	for _, spec := range specs {
+		label := strings.TrimSpace(spec.Label)
+		content := strings.TrimSpace(spec.Content)
+		if label == "" || content == "" || spec.Weight == 0 {
+			return nil, ErrCampaignVariantsInvalid
+		}
+		if _, dup := labels[label]; dup {
+			return nil, ErrCampaignVariantsInvalid
+		}
+		labels[label] = struct{}{}
+		totalWeight += spec.Weight
+		variants = append(variants, models.CampaignContentVariant{
+			Label:   label,
+			Content: spec.Content,
+			Weight:  spec.Weight,
+		})
+	}
+	if totalWeight != 100 {
+		return nil, ErrCampaignVariantsInvalid
+	}
+	return variants, nil
+}
+
+// validateCampaignVariants checks that variants are sent over SMS and that no
+// variant needs more parts than the first one, which the campaign is priced by.
+func (s *CampaignFlowImpl) validateCampaignVariants(spec models.CampaignSpec) error {
+	if len(spec.Variants) == 0 {
+		return nil
+	}
+	if spec.Platform != models.CampaignPlatformSMS {
+		return ErrCampaignVariantsPlatformUnsupported
+	}
+	priced := s.calculateParts(spec.Content, spec.AdLink, spec.ShortLinkDomain, spec.Platform)
+	for _, v := range spec.Variants {
+		content := v.Content
+		if s.calculateParts(&content, spec.AdLink, spec.ShortLinkDomain, spec.Platform) > priced {
+			return ErrCampaignVariantTooLong
+		}
+	}
+	return nil
+}
+
+func toCampaignContentVariantSpecs(variants []models.CampaignContentVariant) []dto.CampaignContentVariantSpec {
+	if len(variants) == 0 {
+		return nil
+	}
+	out := make([]dto.CampaignContentVariantSpec, 0, len(variants))
+	for _, v := range variants {
+		out = append(out, dto.CampaignContentVariantSpec{
+			Label:   v.Label,
+			Content: v.Content,
+			Weight:  v.Weight,
+		})
+	}
+	return out
+}
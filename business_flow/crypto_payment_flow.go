@@ -7,15 +7,19 @@ import (
 	"crypto/sha512"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/app/i18n"
 	"github.com/amirphl/Yamata-no-Orochi/app/services"
 	"github.com/amirphl/Yamata-no-Orochi/config"
 	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/pricing"
 	"github.com/amirphl/Yamata-no-Orochi/repository"
 	"github.com/amirphl/Yamata-no-Orochi/utils"
 	"github.com/google/uuid"
@@ -29,51 +33,82 @@ type CryptoPaymentFlow interface {
 	ManualVerify(ctx context.Context, req *dto.ManualVerifyCryptoDepositRequest, metadata *ClientMetadata) (*dto.ManualVerifyCryptoDepositResponse, error)
 	CancelRequest(ctx context.Context, req *dto.CancelCryptoPaymentRequest, metadata *ClientMetadata) error
 	HandleOxapayWebhook(ctx context.Context, raw []byte, hmacHeader string, secret string, metadata *ClientMetadata) error
+	HandleNowPaymentsWebhook(ctx context.Context, raw []byte, signature string, secret string, metadata *ClientMetadata) error
+	// ExpireStaleRequests expires pending requests past their payment window
+	// and returns how many were expired
+	ExpireStaleRequests(ctx context.Context) (int, error)
 }
 
 // CryptoPaymentFlowImpl implements CryptoPaymentFlow
 type CryptoPaymentFlowImpl struct {
 	cprRepo             repository.CryptoPaymentRequestRepository
 	cdRepo              repository.CryptoDepositRepository
+	webhookEventRepo    repository.CryptoWebhookEventRepository
 	walletRepo          repository.WalletRepository
 	customerRepo        repository.CustomerRepository
 	balanceSnapshotRepo repository.BalanceSnapshotRepository
 	transactionRepo     repository.TransactionRepository
 	auditRepo           repository.AuditLogRepository
 	agencyDiscountRepo  repository.AgencyDiscountRepository
+	taxInvoiceRepo      repository.TaxInvoiceRepository
+	notificationRepo    repository.NotificationRepository
+	pushDeviceRepo      repository.PushDeviceRepository
 	providers           map[string]services.CryptoPaymentProvider // platform -> provider
+	rates               services.ExchangeRateService
 	db                  *gorm.DB
 	sysCfg              config.SystemConfig
 	deploymentCfg       config.DeploymentConfig
+	cryptoCfg           config.CryptoConfig
+	invoiceCfg          config.InvoiceConfig
+	notifier            services.NotificationService
+	localizer           *i18n.Localizer
 }
 
 func NewCryptoPaymentFlow(
 	cprRepo repository.CryptoPaymentRequestRepository,
 	cdRepo repository.CryptoDepositRepository,
+	webhookEventRepo repository.CryptoWebhookEventRepository,
 	walletRepo repository.WalletRepository,
 	customerRepo repository.CustomerRepository,
 	balanceSnapshotRepo repository.BalanceSnapshotRepository,
 	transactionRepo repository.TransactionRepository,
 	auditRepo repository.AuditLogRepository,
 	agencyDiscountRepo repository.AgencyDiscountRepository,
+	taxInvoiceRepo repository.TaxInvoiceRepository,
+	notificationRepo repository.NotificationRepository,
+	pushDeviceRepo repository.PushDeviceRepository,
 	providers map[string]services.CryptoPaymentProvider,
+	rates services.ExchangeRateService,
 	db *gorm.DB,
 	sysCfg config.SystemConfig,
 	deploymentCfg config.DeploymentConfig,
+	cryptoCfg config.CryptoConfig,
+	invoiceCfg config.InvoiceConfig,
+	notifier services.NotificationService,
+	localizer *i18n.Localizer,
 ) CryptoPaymentFlow {
 	return &CryptoPaymentFlowImpl{
 		cprRepo:             cprRepo,
 		cdRepo:              cdRepo,
+		webhookEventRepo:    webhookEventRepo,
 		walletRepo:          walletRepo,
 		customerRepo:        customerRepo,
 		balanceSnapshotRepo: balanceSnapshotRepo,
 		transactionRepo:     transactionRepo,
 		auditRepo:           auditRepo,
 		agencyDiscountRepo:  agencyDiscountRepo,
+		taxInvoiceRepo:      taxInvoiceRepo,
+		notificationRepo:    notificationRepo,
+		pushDeviceRepo:      pushDeviceRepo,
 		providers:           providers,
+		rates:               rates,
 		db:                  db,
 		sysCfg:              sysCfg,
 		deploymentCfg:       deploymentCfg,
+		cryptoCfg:           cryptoCfg,
+		invoiceCfg:          invoiceCfg,
+		notifier:            notifier,
+		localizer:           localizer,
 	}
 }
 
@@ -90,18 +125,34 @@ func (f *CryptoPaymentFlowImpl) CreateRequest(ctx context.Context, req *dto.Crea
 		return nil, ErrCryptoUnsupportedPlatform
 	}
 
+	// Reference rate the provider quote is checked against
+	rate, err := f.rates.Rate(ctx, req.Coin)
+	if err != nil {
+		log.Printf("crypto exchange rate for %s: %v", req.Coin, err)
+		if errors.Is(err, services.ErrExchangeRatesDiverged) {
+			return nil, ErrCryptoRatesDiverged
+		}
+		return nil, ErrCryptoRateUnavailable
+	}
+
 	var customer models.Customer
 	var wallet models.Wallet
 	var agencyDiscount *models.AgencyDiscount
 	var cpr *models.CryptoPaymentRequest
 	var paymentURL *string
 
-	err := repository.WithTransaction(ctx, f.db, func(txCtx context.Context) error {
+	err = repository.WithTransaction(ctx, f.db, func(txCtx context.Context) error {
 		var err error
 		customer, err = getCustomer(txCtx, f.customerRepo, req.CustomerID)
 		if err != nil {
 			return err
 		}
+		if customer.InSandbox() {
+			return ErrSandboxRealPayment
+		}
+		if err := checkCustomerPolicy(customer, CustomerActionRechargeWallet); err != nil {
+			return err
+		}
 		wallet, err = getWallet(txCtx, f.walletRepo, customer.ID)
 		if err != nil {
 			return err
@@ -118,6 +169,10 @@ func (f *CryptoPaymentFlowImpl) CreateRequest(ctx context.Context, req *dto.Crea
 		if agencyDiscount == nil {
 			return ErrAgencyDiscountNotFound
 		}
+		discountRate, monthlyAmountWithTax, err := agencyDiscountRate(txCtx, f.transactionRepo, agencyDiscount)
+		if err != nil {
+			return err
+		}
 
 		// Compute shares metadata similar to fiat flow
 		// scattered, err := f.calculateShares(txCtx, customer, req.AmountWithTax)
@@ -130,11 +185,13 @@ func (f *CryptoPaymentFlowImpl) CreateRequest(ctx context.Context, req *dto.Crea
 			"amount_with_tax": req.AmountWithTax,
 			// "system_share_with_tax": scattered[0].Amount,
 			// "agency_share_with_tax": scattered[1].Amount,
-			"system_share_with_tax": req.AmountWithTax,
-			"agency_share_with_tax": 0,
-			"agency_discount_id":    agencyDiscount.ID,
-			"agency_id":             customer.ReferrerAgencyID,
-			"customer_id":           customer.ID,
+			"system_share_with_tax":   req.AmountWithTax,
+			"agency_share_with_tax":   0,
+			"agency_discount_id":      agencyDiscount.ID,
+			"agency_discount_rate":    discountRate,
+			"monthly_amount_with_tax": monthlyAmountWithTax,
+			"agency_id":               customer.ReferrerAgencyID,
+			"customer_id":             customer.ID,
 		}
 		metaJSON, _ := json.Marshal(metadataMap)
 
@@ -177,21 +234,27 @@ func (f *CryptoPaymentFlowImpl) CreateRequest(ctx context.Context, req *dto.Crea
 			return NewBusinessError("CRYPTO_PROVIDER_QUOTE_FAILED", "Failed to get quote from provider", fmt.Errorf("%w", err))
 		}
 		callbackURL := fmt.Sprintf("https://%s/api/v1/crypto/providers/%s/callback", f.deploymentCfg.APIDomain, strings.ToLower(string(cpr.Platform)))
+		provisionCallbackURL := "" // TODO: oxapay static address callbacks
+		if strings.EqualFold(req.Platform, string(models.CryptoPlatformNowPayments)) {
+			provisionCallbackURL = callbackURL
+		}
 		prov, err := provider.ProvisionDeposit(txCtx, services.ProvisionInput{
 			QuoteInput: services.QuoteInput{
 				FiatAmountToman: req.AmountWithTax,
 				Coin:            req.Coin,
 				Network:         req.Network,
 			},
-			Label: cpr.UUID.String(),
-			// CallbackURL: callbackURL,
-			CallbackURL: "", // TODO:
+			Label:       cpr.UUID.String(),
+			CallbackURL: provisionCallbackURL,
 		})
 		if err != nil {
 			return NewBusinessError("CRYPTO_ADDRESS_PROVISION_FAILED", "Failed to provision deposit address", fmt.Errorf("%w", err))
 		}
 
 		cpr.ExpectedCoinAmount = quote.ExpectedCoinAmount
+		if prov.ExpectedCoinAmount != "" {
+			cpr.ExpectedCoinAmount = prov.ExpectedCoinAmount
+		}
 		cpr.ExchangeRate = quote.ExchangeRate
 		cpr.DepositAddress = prov.DepositAddress
 		cpr.DepositMemo = prov.DepositMemo
@@ -225,6 +288,10 @@ func (f *CryptoPaymentFlowImpl) CreateRequest(ctx context.Context, req *dto.Crea
 					Sandbox:         false,
 				})
 				if invErr == nil && inv != nil {
+					// The invoice fixes the USDT amount; price it in the requested coin
+					if price, perr := strconv.ParseFloat(cpr.ExchangeRate, 64); perr == nil && price > 0 && inv.AmountUSDT > 0 && cpr.ExpectedCoinAmount == "" {
+						cpr.ExpectedCoinAmount = strconv.FormatFloat(inv.AmountUSDT/price, 'f', 8, 64)
+					}
 					if inv.PaymentURL != "" {
 						pu := inv.PaymentURL
 						paymentURL = &pu
@@ -252,6 +319,70 @@ func (f *CryptoPaymentFlowImpl) CreateRequest(ctx context.Context, req *dto.Crea
 			}
 		}
 
+		// NOWPayments also offers a hosted invoice page for the same order
+		if strings.EqualFold(req.Platform, string(models.CryptoPlatformNowPayments)) {
+			if invProv, ok := provider.(interface {
+				CreateInvoice(context.Context, services.NowPaymentsInvoiceInput) (*services.NowPaymentsInvoiceResult, error)
+			}); ok {
+				inv, invErr := invProv.CreateInvoice(txCtx, services.NowPaymentsInvoiceInput{
+					FiatAmountToman: req.AmountWithTax,
+					Coin:            req.Coin,
+					Network:         req.Network,
+					CallbackURL:     callbackURL,
+					SuccessURL:      fmt.Sprintf("https://%s/dashboard/wallet", f.deploymentCfg.Domain),
+					CancelURL:       fmt.Sprintf("https://%s/dashboard/wallet", f.deploymentCfg.Domain),
+					Label:           cpr.UUID.String(),
+					Description:     "Wallet recharge via crypto",
+				})
+				if invErr == nil && inv != nil {
+					pu := inv.PaymentURL
+					paymentURL = &pu
+					var m map[string]any
+					_ = json.Unmarshal(cpr.Metadata, &m)
+					if m == nil {
+						m = map[string]any{}
+					}
+					m["nowpayments_payment_id"] = cpr.ProviderRequestID
+					m["nowpayments_invoice_id"] = inv.InvoiceID
+					m["nowpayments_invoice_url"] = inv.PaymentURL
+					b, _ := json.Marshal(m)
+					cpr.Metadata = b
+					_ = f.cprRepo.Update(txCtx, cpr)
+				} else if invErr != nil {
+					log.Printf("nowpayments invoice for %s failed: %v", cpr.UUID, invErr)
+				}
+			}
+		}
+
+		// Without an amount from the provider, ask for the reference amount
+		if cpr.ExpectedCoinAmount == "" {
+			cpr.ExpectedCoinAmount = strconv.FormatFloat(float64(cpr.FiatAmountToman)/rate.TomanPerCoin, 'f', 8, 64)
+		}
+		// Lock the rate the received coins are valued at when settling,
+		// once it is close enough to the reference rate
+		lockedRate := lockedExchangeRate(cpr.FiatAmountToman, cpr.ExpectedCoinAmount)
+		if quoted, perr := strconv.ParseFloat(lockedRate, 64); perr == nil {
+			if bps := services.RateDivergenceBPS(quoted, rate.TomanPerCoin); bps > f.cryptoCfg.Rates.MaxDivergenceBPS {
+				return fmt.Errorf("%w: %s quote of %s toman per %s is %d bps from %s rate %.4f",
+					ErrCryptoRatesDiverged, cpr.Platform, lockedRate, cpr.Coin, bps, rate.Source, rate.TomanPerCoin)
+			}
+		}
+		var m map[string]any
+		_ = json.Unmarshal(cpr.Metadata, &m)
+		if m == nil {
+			m = map[string]any{}
+		}
+		if lockedRate != "" {
+			m["locked_exchange_rate"] = lockedRate
+		}
+		m["reference_exchange_rate"] = strconv.FormatFloat(rate.TomanPerCoin, 'f', 4, 64)
+		m["reference_exchange_rates"] = rate.Sources
+		b, _ := json.Marshal(m)
+		cpr.Metadata = b
+		cpr.RateSource = rate.Source
+		fetchedAt := rate.FetchedAt
+		cpr.RateFetchedAt = &fetchedAt
+
 		// Move to pending
 		cpr.Status = models.CryptoPaymentStatusPending
 		cpr.StatusReason = "awaiting user crypto payment"
@@ -287,7 +418,8 @@ func (f *CryptoPaymentFlowImpl) CreateRequest(ctx context.Context, req *dto.Crea
 		DepositMemo:        memo,
 		ExpectedCoinAmount: cpr.ExpectedCoinAmount,
 		ExchangeRate:       cpr.ExchangeRate,
-		RateSource:         "provider",
+		RateSource:         cpr.RateSource,
+		RateFetchedAt:      dto.FormatTime(cpr.RateFetchedAt),
 		ExpiresAt:          expiresStr,
 		PaymentURL:         paymentURL,
 	}
@@ -305,7 +437,8 @@ func (f *CryptoPaymentFlowImpl) GetStatus(ctx context.Context, req *dto.GetCrypt
 	var customer models.Customer
 	var provider services.CryptoPaymentProvider
 	var deposits []*models.CryptoDeposit
-	err := repository.WithTransaction(ctx, f.db, func(txCtx context.Context) error {
+	wasCredited := false
+	err := withVersionRetry(ctx, f.db, func(txCtx context.Context) error {
 		var err error
 		uid, err := uuid.Parse(req.UUID)
 		if err != nil {
@@ -318,6 +451,7 @@ func (f *CryptoPaymentFlowImpl) GetStatus(ctx context.Context, req *dto.GetCrypt
 		if cpr == nil {
 			return ErrCryptoRequestNotFound
 		}
+		wasCredited = cpr.CreditedAt != nil
 		customer, err = getCustomer(txCtx, f.customerRepo, cpr.CustomerID)
 		if err != nil {
 			return err
@@ -338,156 +472,15 @@ func (f *CryptoPaymentFlowImpl) GetStatus(ctx context.Context, req *dto.GetCrypt
 			_ = f.cprRepo.Update(txCtx, cpr)
 		}
 
-		// Special handling: Oxapay invoice status via track_id
-		if strings.EqualFold(string(cpr.Platform), "oxapay") {
-			var meta map[string]any
-			_ = json.Unmarshal(cpr.Metadata, &meta)
-			if meta != nil {
-				trackID, _ := meta["oxapay_track_id"].(string)
-				if strings.TrimSpace(trackID) != "" {
-					if infoProv, ok := provider.(interface {
-						GetPaymentInfo(context.Context, string) (*services.OxapayPaymentInfo, error)
-					}); ok {
-						info, ierr := infoProv.GetPaymentInfo(txCtx, trackID)
-						if ierr == nil && info != nil {
-							// update request status from invoice status table
-							st, reason := mapOxapayInvoiceStatus(info.Status)
-							cpr.Status = st
-							cpr.StatusReason = "oxapay:" + reason
-							_ = f.cprRepo.Update(txCtx, cpr)
-
-							b, _ := json.Marshal(info)
-
-							// upsert txs
-							for _, t := range info.Txs {
-								existing, _ := f.cdRepo.ByTxHash(txCtx, t.TxHash)
-								if existing == nil {
-									dep := &models.CryptoDeposit{
-										UUID:                   uuid.New(),
-										CorrelationID:          cpr.CorrelationID,
-										CryptoPaymentRequestID: &cpr.ID,
-										CustomerID:             cpr.CustomerID,
-										WalletID:               cpr.WalletID,
-										Coin:                   cpr.Coin,
-										Network:                cpr.Network,
-										Platform:               cpr.Platform,
-										TxHash:                 t.TxHash,
-										FromAddress:            "",
-										ToAddress:              t.Address,
-										AmountCoin:             fmt.Sprintf("%g", t.Amount),
-										Confirmations:          t.Confirmations,
-										RequiredConfirmations:  0,
-										// BlockHeight: ,
-										// DetectedAt: ,
-										// ConfirmedAt: ,
-										// CreditedAt: ,
-										Status:   mapOxapayTxStatus(t.Status),
-										Metadata: b,
-									}
-									if t.Date > 0 {
-										dt := time.Unix(t.Date, 0).UTC()
-										dep.DetectedAt = &dt
-									}
-									if strings.EqualFold(t.Status, "confirmed") {
-										now := utils.UTCNow()
-										dep.ConfirmedAt = &now
-									}
-									_ = f.cdRepo.Save(txCtx, dep)
-								} else {
-									existing.Confirmations = t.Confirmations
-									existing.Status = mapOxapayTxStatus(t.Status)
-									existing.Metadata = b
-									if strings.EqualFold(t.Status, "confirmed") && existing.ConfirmedAt == nil {
-										now := utils.UTCNow()
-										existing.ConfirmedAt = &now
-									}
-									_ = f.cdRepo.Update(txCtx, existing)
-								}
-							}
-							// credit on invoice paid
-							if strings.EqualFold(info.Status, "paid") || strings.EqualFold(info.Status, "manual_accept") {
-								// fetch current deposits for this request
-								ds, _ := f.cdRepo.ByFilter(txCtx, models.CryptoDepositFilter{CryptoPaymentRequestID: &cpr.ID}, "id ASC", 100, 0)
-								for _, d := range ds {
-									if d.CreditedAt == nil && d.ConfirmedAt != nil {
-										if err := f.creditOnConfirmed(txCtx, cpr, d, metadata); err != nil {
-											// proceed but update status reason
-											s := fmt.Sprintf("credit failed: %v", err)
-											cpr.Status = models.CryptoPaymentStatusFailed
-											cpr.StatusReason = s
-											_ = f.cprRepo.Update(txCtx, cpr)
-											log.Printf("credit on confirmed failed: %v", err)
-										}
-									}
-								}
-							}
-						}
-					}
-				}
-			}
-		}
-
-		// pull provider deposits if any (for providers with polling)
-		provDeposits, perr := provider.GetDeposits(txCtx, cpr.ProviderRequestID)
-		if perr == nil && len(provDeposits) > 0 {
-			for _, d := range provDeposits {
-				dep := &models.CryptoDeposit{
-					UUID:                   uuid.New(),
-					CorrelationID:          cpr.CorrelationID,
-					CryptoPaymentRequestID: &cpr.ID,
-					CustomerID:             cpr.CustomerID,
-					WalletID:               cpr.WalletID,
-					Coin:                   cpr.Coin,
-					Network:                cpr.Network,
-					Platform:               cpr.Platform,
-					TxHash:                 d.TxHash,
-					FromAddress:            "",
-					ToAddress:              d.ToAddress,
-					DestinationTag:         d.DestinationTag,
-					AmountCoin:             d.AmountCoin,
-					Confirmations:          d.Confirmations,
-					RequiredConfirmations:  d.RequiredConfirmations,
-					// BlockHeight: ,
-					// DetectedAt:             d.DetectedAt,
-					// ConfirmedAt:            d.ConfirmedAt,
-					// CreditedAt:             d.CreditedAt,
-					Status: d.Status,
-				}
-				dep.DetectedAt = d.DetectedAt
-				dep.ConfirmedAt = d.ConfirmedAt
-				dep.CreditedAt = d.CreditedAt
-				_ = f.cdRepo.Save(txCtx, dep)
-			}
-		}
-		// fetch current deposits
-		deposits, err = f.cdRepo.ByFilter(txCtx, models.CryptoDepositFilter{
-			CryptoPaymentRequestID: &cpr.ID,
-		}, "created_at ASC", 100, 0)
-		if err != nil {
-			return err
-		}
-
-		// finalize on confirmed not yet credited
-		for _, dep := range deposits {
-			// For OxaPay, only auto-credit after invoice is paid (mapped to Confirmed)
-			// if strings.EqualFold(string(cpr.Platform), "oxapay") && cpr.Status != models.CryptoPaymentStatusConfirmed {
-			// 	continue
-			// }
-			if dep.CreditedAt == nil && dep.ConfirmedAt != nil && cpr.CreditedAt == nil {
-				if err := f.creditOnConfirmed(txCtx, cpr, dep, metadata); err != nil {
-					// proceed but update status reason
-					s := fmt.Sprintf("credit failed: %v", err)
-					cpr.Status = models.CryptoPaymentStatusFailed
-					cpr.StatusReason = s
-					_ = f.cprRepo.Update(txCtx, cpr)
-				}
-			}
-		}
-		return nil
+		deposits, err = f.syncProvider(txCtx, cpr, provider, metadata)
+		return err
 	})
 	if err != nil {
 		return nil, NewBusinessError("CRYPTO_STATUS_FAILED", "Failed to get crypto payment status", err)
 	}
+	if !wasCredited && cpr.CreditedAt != nil {
+		f.notifySettlement(ctx, cpr)
+	}
 
 	depos := make([]dto.DepositInfoDTO, 0, len(deposits))
 	for _, d := range deposits {
@@ -521,9 +514,159 @@ func (f *CryptoPaymentFlowImpl) GetStatus(ctx context.Context, req *dto.GetCrypt
 		Deposits:     depos,
 		ExpiresAt:    expiresStr,
 	}
+	if st := cryptoRequestSettlement(cpr); st != nil {
+		resp.Settlement = &dto.CryptoSettlementDTO{
+			Kind:           st.Kind,
+			ReceivedCoin:   st.ReceivedCoin,
+			CreditedAmount: st.CreditedToman,
+		}
+	}
 	return resp, nil
 }
 
+// syncProvider brings the request and its deposits up to date with the
+// provider, credits the wallet once the deposits settle the request, and
+// returns the deposits of the request
+func (f *CryptoPaymentFlowImpl) syncProvider(ctx context.Context, cpr *models.CryptoPaymentRequest, provider services.CryptoPaymentProvider, metadata *ClientMetadata) ([]*models.CryptoDeposit, error) {
+	// Special handling: Oxapay invoice status via track_id
+	if strings.EqualFold(string(cpr.Platform), "oxapay") {
+		var meta map[string]any
+		_ = json.Unmarshal(cpr.Metadata, &meta)
+		if meta != nil {
+			trackID, _ := meta["oxapay_track_id"].(string)
+			if strings.TrimSpace(trackID) != "" {
+				if infoProv, ok := provider.(interface {
+					GetPaymentInfo(context.Context, string) (*services.OxapayPaymentInfo, error)
+				}); ok {
+					info, ierr := infoProv.GetPaymentInfo(ctx, trackID)
+					if ierr == nil && info != nil {
+						// update request status from invoice status table
+						st, reason := mapOxapayInvoiceStatus(info.Status)
+						cpr.Status = st
+						cpr.StatusReason = "oxapay:" + reason
+						_ = f.cprRepo.Update(ctx, cpr)
+
+						b, _ := json.Marshal(info)
+
+						// upsert txs
+						for _, t := range info.Txs {
+							existing, _ := f.cdRepo.ByTxHash(ctx, t.TxHash)
+							if existing == nil {
+								dep := &models.CryptoDeposit{
+									UUID:                   uuid.New(),
+									CorrelationID:          cpr.CorrelationID,
+									CryptoPaymentRequestID: &cpr.ID,
+									CustomerID:             cpr.CustomerID,
+									WalletID:               cpr.WalletID,
+									Coin:                   depositCoin(cpr, t.Currency),
+									Network:                cpr.Network,
+									Platform:               cpr.Platform,
+									TxHash:                 t.TxHash,
+									FromAddress:            "",
+									ToAddress:              t.Address,
+									AmountCoin:             fmt.Sprintf("%g", t.Amount),
+									Confirmations:          t.Confirmations,
+									RequiredConfirmations:  0,
+									// BlockHeight: ,
+									// DetectedAt: ,
+									// ConfirmedAt: ,
+									// CreditedAt: ,
+									Status:   mapOxapayTxStatus(t.Status),
+									Metadata: b,
+								}
+								if t.Date > 0 {
+									dt := time.Unix(t.Date, 0).UTC()
+									dep.DetectedAt = &dt
+								}
+								if strings.EqualFold(t.Status, "confirmed") {
+									now := utils.UTCNow()
+									dep.ConfirmedAt = &now
+								}
+								_ = f.cdRepo.Save(ctx, dep)
+							} else {
+								existing.Confirmations = t.Confirmations
+								existing.Status = mapOxapayTxStatus(t.Status)
+								existing.Metadata = b
+								if strings.EqualFold(t.Status, "confirmed") && existing.ConfirmedAt == nil {
+									now := utils.UTCNow()
+									existing.ConfirmedAt = &now
+								}
+								_ = f.cdRepo.Update(ctx, existing)
+							}
+						}
+						// credit on invoice paid, or what was received once it expired
+						if strings.EqualFold(info.Status, "paid") || strings.EqualFold(info.Status, "manual_accept") || strings.EqualFold(info.Status, "expired") {
+							// fetch current deposits for this request
+							ds, _ := f.cdRepo.ByFilter(ctx, models.CryptoDepositFilter{CryptoPaymentRequestID: &cpr.ID}, "id ASC", 100, 0)
+							for _, d := range ds {
+								if d.CreditedAt == nil && d.ConfirmedAt != nil {
+									if err := f.creditOnConfirmed(ctx, cpr, d, metadata); err != nil {
+										// proceed but update status reason
+										s := fmt.Sprintf("credit failed: %v", err)
+										cpr.Status = models.CryptoPaymentStatusFailed
+										cpr.StatusReason = s
+										_ = f.cprRepo.Update(ctx, cpr)
+										log.Printf("credit on confirmed failed: %v", err)
+									}
+								}
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+
+	if strings.EqualFold(string(cpr.Platform), string(models.CryptoPlatformNowPayments)) {
+		// NOWPayments payment status, which also reports the deposit
+		if payProv, ok := provider.(interface {
+			GetPayment(context.Context, string) (*services.NowPaymentsPayment, error)
+		}); ok && cpr.CreditedAt == nil {
+			payment, perr := payProv.GetPayment(ctx, cpr.ProviderRequestID)
+			if perr == nil {
+				if err := f.applyNowPaymentsPayment(ctx, cpr, payment, nil, metadata); err != nil {
+					log.Printf("nowpayments status for %s failed: %v", cpr.UUID, err)
+				}
+			}
+		}
+	} else {
+		// pull provider deposits if any (for providers with polling)
+		provDeposits, perr := provider.GetDeposits(ctx, cpr.ProviderRequestID)
+		if perr == nil && len(provDeposits) > 0 {
+			for _, d := range provDeposits {
+				if _, err := f.upsertProviderDeposit(ctx, cpr, d, nil, ""); err != nil {
+					log.Printf("save %s deposit %s failed: %v", cpr.Platform, d.TxHash, err)
+				}
+			}
+		}
+	}
+	// fetch current deposits
+	deposits, err := f.cdRepo.ByFilter(ctx, models.CryptoDepositFilter{
+		CryptoPaymentRequestID: &cpr.ID,
+	}, "created_at ASC", 100, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	// finalize on confirmed not yet credited
+	for _, dep := range deposits {
+		// For OxaPay, only auto-credit after invoice is paid (mapped to Confirmed)
+		// if strings.EqualFold(string(cpr.Platform), "oxapay") && cpr.Status != models.CryptoPaymentStatusConfirmed {
+		// 	continue
+		// }
+		if dep.CreditedAt == nil && dep.ConfirmedAt != nil && cpr.CreditedAt == nil {
+			if err := f.creditOnConfirmed(ctx, cpr, dep, metadata); err != nil {
+				// proceed but update status reason
+				s := fmt.Sprintf("credit failed: %v", err)
+				cpr.Status = models.CryptoPaymentStatusFailed
+				cpr.StatusReason = s
+				_ = f.cprRepo.Update(ctx, cpr)
+			}
+		}
+	}
+	return deposits, nil
+}
+
 // TODO: Test
 func (f *CryptoPaymentFlowImpl) ManualVerify(ctx context.Context, req *dto.ManualVerifyCryptoDepositRequest, metadata *ClientMetadata) (*dto.ManualVerifyCryptoDepositResponse, error) {
 	if req.RequestUUID == "" || req.TxHash == "" {
@@ -538,7 +681,8 @@ func (f *CryptoPaymentFlowImpl) ManualVerify(ctx context.Context, req *dto.Manua
 	var customer models.Customer
 	var dep *models.CryptoDeposit
 	var provider services.CryptoPaymentProvider
-	err = repository.WithTransaction(ctx, f.db, func(txCtx context.Context) error {
+	wasCredited := false
+	err = withVersionRetry(ctx, f.db, func(txCtx context.Context) error {
 		var err error
 		cpr, err = f.cprRepo.ByUUID(txCtx, uid.String())
 		if err != nil {
@@ -547,6 +691,7 @@ func (f *CryptoPaymentFlowImpl) ManualVerify(ctx context.Context, req *dto.Manua
 		if cpr == nil {
 			return ErrCryptoRequestNotFound
 		}
+		wasCredited = cpr.CreditedAt != nil
 		customer, err = getCustomer(txCtx, f.customerRepo, cpr.CustomerID)
 		if err != nil {
 			return err
@@ -616,6 +761,9 @@ func (f *CryptoPaymentFlowImpl) ManualVerify(ctx context.Context, req *dto.Manua
 	if err != nil {
 		return nil, NewBusinessError("CRYPTO_VERIFY_FAILED", "Manual deposit verification failed", err)
 	}
+	if !wasCredited && cpr.CreditedAt != nil {
+		f.notifySettlement(ctx, cpr)
+	}
 
 	resp := &dto.ManualVerifyCryptoDepositResponse{
 		Status:     string(models.CryptoPaymentStatusCredited),
@@ -625,6 +773,117 @@ func (f *CryptoPaymentFlowImpl) ManualVerify(ctx context.Context, req *dto.Manua
 	return resp, nil
 }
 
+// cryptoExpiryBatchSize bounds the requests expired per sweep
+const cryptoExpiryBatchSize = 100
+
+func (f *CryptoPaymentFlowImpl) ExpireStaleRequests(ctx context.Context) (int, error) {
+	now := utils.UTCNow()
+	pending := models.CryptoPaymentStatusPending
+	stale, err := f.cprRepo.ByFilter(ctx, models.CryptoPaymentRequestFilter{
+		Status:        &pending,
+		ExpiresBefore: &now,
+	}, "expires_at ASC", cryptoExpiryBatchSize, 0)
+	if err != nil {
+		return 0, err
+	}
+	expired := 0
+	for _, cpr := range stale {
+		ok, err := f.expireRequest(ctx, cpr.ID)
+		if err != nil {
+			log.Printf("expire crypto request %s: %v", cpr.UUID, err)
+			continue
+		}
+		if ok {
+			expired++
+		}
+	}
+	return expired, nil
+}
+
+// expireRequest settles what the provider received for a pending request
+// past its window, expires it when nothing was credited, releases its
+// deposit address and tells the customer
+func (f *CryptoPaymentFlowImpl) expireRequest(ctx context.Context, id uint) (bool, error) {
+	var cpr *models.CryptoPaymentRequest
+	expired := false
+	err := repository.WithTransaction(ctx, f.db, func(txCtx context.Context) error {
+		var err error
+		cpr, err = f.cprRepo.ByID(txCtx, id)
+		if err != nil {
+			return err
+		}
+		if cpr == nil || cpr.Status != models.CryptoPaymentStatusPending || cpr.CreditedAt != nil ||
+			cpr.ExpiresAt == nil || cpr.ExpiresAt.After(utils.UTCNow()) {
+			return nil
+		}
+		provider := f.providers[string(cpr.Platform)]
+		if provider == nil {
+			return ErrCryptoUnsupportedPlatform
+		}
+
+		// Late deposits still count, and an underpayment is credited now
+		// that the window has closed
+		if _, err := f.syncProvider(txCtx, cpr, provider, nil); err != nil {
+			return err
+		}
+		if cpr.CreditedAt != nil {
+			return nil
+		}
+		switch cpr.Status {
+		case models.CryptoPaymentStatusExpired:
+			// the provider expired it too
+			expired = true
+		case models.CryptoPaymentStatusPending:
+			cpr.Status = models.CryptoPaymentStatusExpired
+			cpr.StatusReason = "payment window expired"
+			expired = true
+			return f.cprRepo.Update(txCtx, cpr)
+		}
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	if cpr == nil {
+		return false, nil
+	}
+	if cpr.CreditedAt != nil {
+		f.notifySettlement(ctx, cpr)
+		return false, nil
+	}
+	if !expired {
+		return false, nil
+	}
+
+	f.releaseDepositAddress(ctx, cpr)
+	f.notifyExpired(ctx, cpr)
+	return true, nil
+}
+
+// releaseDepositAddress releases the deposit address of an expired request
+// at providers that keep addresses open, so it stops accepting payments
+func (f *CryptoPaymentFlowImpl) releaseDepositAddress(ctx context.Context, cpr *models.CryptoPaymentRequest) {
+	releaser, ok := f.providers[string(cpr.Platform)].(services.DepositReleaser)
+	if !ok || cpr.DepositAddress == "" {
+		return
+	}
+	if err := releaser.ReleaseDeposit(ctx, cpr.DepositAddress, cpr.ProviderRequestID); err != nil {
+		log.Printf("release deposit address of crypto request %s: %v", cpr.UUID, err)
+		return
+	}
+	var m map[string]any
+	_ = json.Unmarshal(cpr.Metadata, &m)
+	if m == nil {
+		m = map[string]any{}
+	}
+	m["deposit_address_released_at"] = utils.UTCNow().Format(time.RFC3339)
+	b, _ := json.Marshal(m)
+	cpr.Metadata = b
+	if err := f.cprRepo.Update(ctx, cpr); err != nil {
+		log.Printf("record deposit address release of crypto request %s: %v", cpr.UUID, err)
+	}
+}
+
 // TODO: Test
 func (f *CryptoPaymentFlowImpl) CancelRequest(ctx context.Context, req *dto.CancelCryptoPaymentRequest, metadata *ClientMetadata) error {
 	uid, err := uuid.Parse(req.UUID)
@@ -709,10 +968,21 @@ func (f *CryptoPaymentFlowImpl) HandleOxapayWebhook(ctx context.Context, raw []b
 	if len(payload.Txs) == 0 {
 		return nil
 	}
+	event := oxapayWebhookEvent(&payload)
+	if err := checkCryptoWebhookFresh(event.EventAt, utils.UTCNow(), f.cryptoCfg.WebhookMaxAge); err != nil {
+		log.Printf("oxapay webhook for track %s rejected: %v", payload.TrackID, err)
+		return err
+	}
 	// Upsert deposit per txs
-	return repository.WithTransaction(ctx, f.db, func(txCtx context.Context) error {
+	var cpr *models.CryptoPaymentRequest
+	wasCredited := false
+	err := withVersionRetry(ctx, f.db, func(txCtx context.Context) error {
+		cpr = nil
+		// A re-delivered callback was applied already
+		if fresh, err := f.recordWebhookEvent(txCtx, event); err != nil || !fresh {
+			return err
+		}
 		// Resolve by track_id first for invoice/static_address callbacks
-		var cpr *models.CryptoPaymentRequest
 		var err error
 		if payload.TrackID != "" {
 			cpr, err = f.cprRepo.ByProviderRequestID(txCtx, payload.TrackID)
@@ -732,6 +1002,7 @@ func (f *CryptoPaymentFlowImpl) HandleOxapayWebhook(ctx context.Context, raw []b
 			}
 			cpr = reqs[0]
 		}
+		wasCredited = cpr.CreditedAt != nil
 		// Update request status from invoice status mapping (paying/paid)
 		st, reason := mapOxapayInvoiceStatus(payload.Status)
 		cpr.Status = st
@@ -747,7 +1018,7 @@ func (f *CryptoPaymentFlowImpl) HandleOxapayWebhook(ctx context.Context, raw []b
 					CryptoPaymentRequestID: &cpr.ID,
 					CustomerID:             cpr.CustomerID,
 					WalletID:               cpr.WalletID,
-					Coin:                   cpr.Coin,
+					Coin:                   depositCoin(cpr, t.Currency),
 					Network:                cpr.Network,
 					Platform:               cpr.Platform,
 					TxHash:                 t.TxHash,
@@ -785,8 +1056,8 @@ func (f *CryptoPaymentFlowImpl) HandleOxapayWebhook(ctx context.Context, raw []b
 				if err := f.cdRepo.Update(txCtx, dep); err != nil {
 					return err
 				}
-				// Credit on Paid once per request
-				if (strings.EqualFold(payload.Status, "paid") || strings.EqualFold(payload.Status, "manual_accept")) && dep.ConfirmedAt != nil && dep.CreditedAt == nil && cpr.CreditedAt == nil {
+				// Credit on Paid once per request, or what was received once expired
+				if (strings.EqualFold(payload.Status, "paid") || strings.EqualFold(payload.Status, "manual_accept") || strings.EqualFold(payload.Status, "expired")) && dep.ConfirmedAt != nil && dep.CreditedAt == nil && cpr.CreditedAt == nil {
 					if err := f.creditOnConfirmed(txCtx, cpr, dep, metadata); err != nil {
 						return err
 					}
@@ -795,6 +1066,195 @@ func (f *CryptoPaymentFlowImpl) HandleOxapayWebhook(ctx context.Context, raw []b
 		}
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+	if !wasCredited && cpr != nil && cpr.CreditedAt != nil {
+		f.notifySettlement(ctx, cpr)
+	}
+	return nil
+}
+
+// HandleNowPaymentsWebhook applies a NOWPayments IPN callback, signed with
+// the IPN secret in the x-nowpayments-sig header
+func (f *CryptoPaymentFlowImpl) HandleNowPaymentsWebhook(ctx context.Context, raw []byte, signature string, secret string, metadata *ClientMetadata) error {
+	if len(raw) == 0 || signature == "" {
+		return NewBusinessError("CRYPTO_WEBHOOK_INVALID", "missing body or signature header", nil)
+	}
+	if !services.VerifyNowPaymentsIPN(raw, signature, secret) {
+		return NewBusinessError("CRYPTO_WEBHOOK_FORBIDDEN", "invalid IPN signature", nil)
+	}
+	var payment services.NowPaymentsPayment
+	if err := json.Unmarshal(raw, &payment); err != nil {
+		return NewBusinessError("CRYPTO_WEBHOOK_INVALID", "invalid json", err)
+	}
+	event := nowPaymentsWebhookEvent(&payment)
+	if err := checkCryptoWebhookFresh(event.EventAt, utils.UTCNow(), f.cryptoCfg.WebhookMaxAge); err != nil {
+		log.Printf("nowpayments webhook for payment %s rejected: %v", payment.PaymentID, err)
+		return err
+	}
+	var cpr *models.CryptoPaymentRequest
+	wasCredited := false
+	err := withVersionRetry(ctx, f.db, func(txCtx context.Context) error {
+		// A re-delivered callback was applied already
+		if fresh, err := f.recordWebhookEvent(txCtx, event); err != nil || !fresh {
+			return err
+		}
+		// order_id is the request UUID for both the payment and the invoice,
+		// whose payments get their own payment_id
+		var err error
+		if uid, perr := uuid.Parse(payment.OrderID); perr == nil {
+			cpr, err = f.cprRepo.ByUUID(txCtx, uid.String())
+			if err != nil {
+				return err
+			}
+		}
+		if cpr == nil && payment.PaymentID != "" {
+			cpr, err = f.cprRepo.ByProviderRequestID(txCtx, payment.PaymentID.String())
+			if err != nil {
+				return err
+			}
+		}
+		if cpr == nil || cpr.Platform != models.CryptoPlatformNowPayments {
+			return ErrCryptoRequestNotFound
+		}
+		wasCredited = cpr.CreditedAt != nil
+		if wasCredited {
+			return nil
+		}
+		return f.applyNowPaymentsPayment(txCtx, cpr, &payment, raw, metadata)
+	})
+	if err != nil {
+		return err
+	}
+	if !wasCredited && cpr != nil && cpr.CreditedAt != nil {
+		f.notifySettlement(ctx, cpr)
+	}
+	return nil
+}
+
+// applyNowPaymentsPayment updates the request and its deposit from a
+// NOWPayments payment, and credits the wallet once the payment is finished
+func (f *CryptoPaymentFlowImpl) applyNowPaymentsPayment(ctx context.Context, cpr *models.CryptoPaymentRequest, payment *services.NowPaymentsPayment, raw []byte, metadata *ClientMetadata) error {
+	st, reason := mapNowPaymentsStatus(payment.PaymentStatus)
+	cpr.Status = st
+	cpr.StatusReason = "nowpayments:" + reason
+	if err := f.cprRepo.Update(ctx, cpr); err != nil {
+		return err
+	}
+
+	d := payment.Deposit()
+	if d == nil {
+		return nil
+	}
+	if payment.PayCurrency != "" && !strings.EqualFold(payment.PayCurrency, services.NowPaymentsCurrency(string(cpr.Coin), cpr.Network)) {
+		d.Coin = strings.ToUpper(payment.PayCurrency)
+	}
+	if raw == nil {
+		raw, _ = json.Marshal(payment)
+	}
+	dep, err := f.upsertProviderDeposit(ctx, cpr, *d, raw, "nowpayments:"+payment.PaymentID.String())
+	if err != nil {
+		return err
+	}
+	if dep.ConfirmedAt != nil && dep.CreditedAt == nil && cpr.CreditedAt == nil {
+		return f.creditOnConfirmed(ctx, cpr, dep, metadata)
+	}
+	return nil
+}
+
+// upsertProviderDeposit saves a deposit reported by a provider, updating the
+// one already stored under its tx hash, or under previousTxHash when the
+// provider reported it before the hash was known
+func (f *CryptoPaymentFlowImpl) upsertProviderDeposit(ctx context.Context, cpr *models.CryptoPaymentRequest, d services.DepositInfo, raw []byte, previousTxHash string) (*models.CryptoDeposit, error) {
+	if raw == nil {
+		raw, _ = json.Marshal(d)
+	}
+	dep, err := f.cdRepo.ByTxHash(ctx, d.TxHash)
+	if err != nil {
+		return nil, err
+	}
+	if dep == nil && previousTxHash != "" && previousTxHash != d.TxHash {
+		if dep, err = f.cdRepo.ByTxHash(ctx, previousTxHash); err != nil {
+			return nil, err
+		}
+	}
+	if dep == nil {
+		dep = &models.CryptoDeposit{
+			UUID:                   uuid.New(),
+			CorrelationID:          cpr.CorrelationID,
+			CryptoPaymentRequestID: &cpr.ID,
+			CustomerID:             cpr.CustomerID,
+			WalletID:               cpr.WalletID,
+			Coin:                   depositCoin(cpr, d.Coin),
+			Network:                cpr.Network,
+			Platform:               cpr.Platform,
+			TxHash:                 d.TxHash,
+			ToAddress:              d.ToAddress,
+			DestinationTag:         d.DestinationTag,
+			AmountCoin:             d.AmountCoin,
+			Confirmations:          d.Confirmations,
+			RequiredConfirmations:  d.RequiredConfirmations,
+			DetectedAt:             d.DetectedAt,
+			ConfirmedAt:            d.ConfirmedAt,
+			CreditedAt:             d.CreditedAt,
+			Status:                 d.Status,
+			Metadata:               raw,
+		}
+		if err := f.cdRepo.Save(ctx, dep); err != nil {
+			return nil, err
+		}
+		return dep, nil
+	}
+	if dep.CryptoPaymentRequestID == nil || *dep.CryptoPaymentRequestID != cpr.ID {
+		return nil, fmt.Errorf("deposit %s belongs to another payment request", d.TxHash)
+	}
+	if dep.CreditedAt != nil {
+		return dep, nil
+	}
+	dep.TxHash = d.TxHash
+	dep.AmountCoin = d.AmountCoin
+	dep.Confirmations = d.Confirmations
+	dep.Status = d.Status
+	dep.Metadata = raw
+	if dep.DetectedAt == nil {
+		dep.DetectedAt = d.DetectedAt
+	}
+	if dep.ConfirmedAt == nil {
+		dep.ConfirmedAt = d.ConfirmedAt
+	}
+	if err := f.cdRepo.Update(ctx, dep); err != nil {
+		return nil, err
+	}
+	return dep, nil
+}
+
+func mapNowPaymentsStatus(s string) (models.CryptoPaymentStatus, string) {
+	sx := strings.ToLower(strings.TrimSpace(s))
+	switch sx {
+	case "waiting", "confirming", "confirmed", "sending":
+		return models.CryptoPaymentStatusPending, sx
+	case "partially_paid":
+		// NOWPayments closes an underpaid payment, so settle what was received
+		return models.CryptoPaymentStatusExpired, sx
+	case "finished":
+		return models.CryptoPaymentStatusConfirmed, sx
+	case "expired":
+		return models.CryptoPaymentStatusExpired, sx
+	case "failed", "refunded":
+		return models.CryptoPaymentStatusFailed, sx
+	default:
+		return models.CryptoPaymentStatusPending, sx
+	}
+}
+
+// depositCoin is the coin a deposit was paid in, which providers accepting
+// several coins per request report along with the deposit
+func depositCoin(cpr *models.CryptoPaymentRequest, currency string) models.CryptoCurrency {
+	if strings.TrimSpace(currency) == "" {
+		return cpr.Coin
+	}
+	return models.CryptoCurrency(strings.ToUpper(strings.TrimSpace(currency)))
 }
 
 func mapOxapayTxStatus(s string) string {
@@ -845,7 +1305,10 @@ func (f *CryptoPaymentFlowImpl) getAgencyDiscountRate(ctx context.Context, custo
 	}
 	var discountRate float64
 	if ad != nil {
-		discountRate = ad.DiscountRate
+		discountRate, _, err = agencyDiscountRate(ctx, f.transactionRepo, ad)
+		if err != nil {
+			return 0, "", err
+		}
 	}
 	sheba := ""
 	if agency.ShebaNumber != nil {
@@ -854,19 +1317,50 @@ func (f *CryptoPaymentFlowImpl) getAgencyDiscountRate(ctx context.Context, custo
 	return discountRate, sheba, nil
 }
 
+// creditOnConfirmed credits the wallet with the confirmed deposits of a
+// request, valued by settlementFor. It leaves the request pending while an
+// underpayment may still be topped up.
 func (f *CryptoPaymentFlowImpl) creditOnConfirmed(ctx context.Context, cpr *models.CryptoPaymentRequest, dep *models.CryptoDeposit, metadata *ClientMetadata) error {
+	if cpr.CreditedAt != nil {
+		return nil
+	}
 	// decode metadata
 	var m map[string]any
 	if err := json.Unmarshal(cpr.Metadata, &m); err != nil {
 		return err
 	}
-	realWithTax := uint64(m["amount_with_tax"].(float64))
-	systemShareWithTax := uint64(m["system_share_with_tax"].(float64))
+
+	// settle every confirmed deposit of the request at once
+	all, err := f.cdRepo.ByFilter(ctx, models.CryptoDepositFilter{CryptoPaymentRequestID: &cpr.ID}, "id ASC", 100, 0)
+	if err != nil {
+		return err
+	}
+	deposits := []*models.CryptoDeposit{dep}
+	for _, d := range all {
+		if d.ID != dep.ID && d.ConfirmedAt != nil && d.CreditedAt == nil {
+			deposits = append(deposits, d)
+		}
+	}
+	settlement, ready := f.settlementFor(cpr, deposits)
+	if !ready {
+		return nil
+	}
+
+	requestedWithTax := uint64(m["amount_with_tax"].(float64))
+	realWithTax := settlement.CreditedToman
 	agencyShareWithTax := uint64(m["agency_share_with_tax"].(float64))
+	if realWithTax != requestedWithTax && requestedWithTax > 0 {
+		agencyShareWithTax = uint64(float64(agencyShareWithTax) * float64(realWithTax) / float64(requestedWithTax))
+	}
+	systemShareWithTax := realWithTax - agencyShareWithTax
 	agencyDiscountID := uint(m["agency_discount_id"].(float64))
 	agencyID := uint(m["agency_id"].(float64))
 
 	// wallets & balances
+	customerWallet, err := getWallet(ctx, f.walletRepo, cpr.CustomerID)
+	if err != nil {
+		return err
+	}
 	agencyWallet, err := getWallet(ctx, f.walletRepo, agencyID)
 	if err != nil {
 		return err
@@ -879,6 +1373,10 @@ func (f *CryptoPaymentFlowImpl) creditOnConfirmed(ctx context.Context, cpr *mode
 	if err != nil {
 		return err
 	}
+	// serialize with concurrent balance updates of the same wallets
+	if err := lockWalletBalances(ctx, f.walletRepo, customerWallet.ID, agencyWallet.ID, taxWallet.ID, systemWallet.ID); err != nil {
+		return err
+	}
 	customerBalance, err := getLatestBalanceSnapshot(ctx, f.walletRepo, cpr.WalletID)
 	if err != nil {
 		return err
@@ -903,18 +1401,21 @@ func (f *CryptoPaymentFlowImpl) creditOnConfirmed(ctx context.Context, cpr *mode
 		return ErrAgencyDiscountNotFound
 	}
 
-	real := uint64(realWithTax * 10 / 11)
-	tax := realWithTax - real
-	realSystemShare := uint64(systemShareWithTax * 10 / 11)
-	taxSystemShare := systemShareWithTax - realSystemShare
-	realAgencyShare := uint64(agencyShareWithTax * 10 / 11)
-	taxAgencyShare := agencyShareWithTax - realAgencyShare
-	customerCredit := uint64(float64(real)/(1-agencyDiscount.DiscountRate)) - real
+	taxRate := f.sysCfg.TaxRateAt(cpr.CreatedAt)
+	total := pricing.SplitGross(realWithTax, taxRate)
+	systemShare := pricing.SplitGross(systemShareWithTax, taxRate)
+	agencyShare := pricing.SplitGross(agencyShareWithTax, taxRate)
+	real, tax := total.Net, total.Tax
+	realSystemShare, taxSystemShare := systemShare.Net, systemShare.Tax
+	realAgencyShare, taxAgencyShare := agencyShare.Net, agencyShare.Tax
+	discountRate := chargeDiscountRate(m, agencyDiscount)
+	customerCredit := pricing.CustomerCredit(real, discountRate)
 
 	metadataMap := map[string]any{
 		"customer_id":               cpr.CustomerID,
 		"agency_id":                 agencyID,
 		"agency_discount_id":        agencyDiscountID,
+		"agency_discount_rate":      discountRate,
 		"source":                    "crypto_payment_callback",
 		"operation":                 "increase_balance",
 		"crypto_payment_request_id": cpr.ID,
@@ -929,12 +1430,13 @@ func (f *CryptoPaymentFlowImpl) creditOnConfirmed(ctx context.Context, cpr *mode
 		"agency_share_tax":          taxAgencyShare,
 		"customer_credit":           customerCredit,
 		"tx_hash":                   dep.TxHash,
+		"settlement":                settlement.Kind,
 	}
 
 	// Update customer balance
 	newCustomerFree := customerBalance.FreeBalance + real
 	newCustomerCredit := customerBalance.CreditBalance + customerCredit
-	metadataMap["source"] = "crypto_increase_customer_free_plus_credit"
+	metadataMap["source"] = models.TransactionSourceCryptoIncreaseCustomerFreePlusCredit
 	metadataMap["operation"] = "increase_customer_free_plus_credit"
 	b, _ := json.Marshal(metadataMap)
 	newCustomerBS := &models.BalanceSnapshot{
@@ -953,6 +1455,9 @@ func (f *CryptoPaymentFlowImpl) creditOnConfirmed(ctx context.Context, cpr *mode
 		Description:        fmt.Sprintf("Wallet recharged via crypto (request %d)", cpr.ID),
 		Metadata:           b,
 	}
+	if err := f.walletRepo.AdvanceVersion(ctx, &customerWallet); err != nil {
+		return err
+	}
 	if err := f.balanceSnapshotRepo.Save(ctx, newCustomerBS); err != nil {
 		return err
 	}
@@ -979,10 +1484,31 @@ func (f *CryptoPaymentFlowImpl) creditOnConfirmed(ctx context.Context, cpr *mode
 	if err := f.transactionRepo.Save(ctx, customerDepositTx); err != nil {
 		return err
 	}
+	// Confirmed deposits are credited even to deactivated customers, so the
+	// customer is looked up without the active check of getCustomer
+	customer, err := f.customerRepo.ByID(ctx, cpr.CustomerID)
+	if err != nil {
+		return err
+	}
+	if customer == nil {
+		return ErrCustomerNotFound
+	}
+	if _, err := issueTaxInvoice(ctx, f.taxInvoiceRepo, f.invoiceCfg, taxInvoiceCharge{
+		Customer:      *customer,
+		Deposit:       customerDepositTx,
+		AmountWithTax: realWithTax,
+		Amount:        real,
+		Tax:           tax,
+		Rate:          taxRate,
+		Channel:       "crypto",
+		Reference:     dep.TxHash,
+	}); err != nil {
+		return err
+	}
 
 	// Update agency balance
 	newAgencyShareWithTax := agencyBalance.AgencyShareWithTax + agencyShareWithTax
-	metadataMap["source"] = "crypto_increase_agency_share_with_tax"
+	metadataMap["source"] = models.TransactionSourceCryptoIncreaseAgencyShareWithTax
 	metadataMap["operation"] = "increase_agency_share_with_tax"
 	b, _ = json.Marshal(metadataMap)
 	agencyBS := &models.BalanceSnapshot{
@@ -1001,6 +1527,9 @@ func (f *CryptoPaymentFlowImpl) creditOnConfirmed(ctx context.Context, cpr *mode
 		Description:        fmt.Sprintf("Agency share for crypto request %d", cpr.ID),
 		Metadata:           b,
 	}
+	if err := f.walletRepo.AdvanceVersion(ctx, &agencyWallet); err != nil {
+		return err
+	}
 	if err := f.balanceSnapshotRepo.Save(ctx, agencyBS); err != nil {
 		return err
 	}
@@ -1030,7 +1559,7 @@ func (f *CryptoPaymentFlowImpl) creditOnConfirmed(ctx context.Context, cpr *mode
 
 	// Tax locked
 	newTaxLocked := taxBalance.LockedBalance + taxSystemShare
-	metadataMap["source"] = "crypto_increase_tax_locked_(tax_system_share)"
+	metadataMap["source"] = models.TransactionSourceCryptoIncreaseTaxSystemShare
 	metadataMap["operation"] = "increase_tax_locked"
 	b, _ = json.Marshal(metadataMap)
 	taxBS := &models.BalanceSnapshot{
@@ -1049,6 +1578,9 @@ func (f *CryptoPaymentFlowImpl) creditOnConfirmed(ctx context.Context, cpr *mode
 		Description:        fmt.Sprintf("Tax collection for crypto request %d", cpr.ID),
 		Metadata:           b,
 	}
+	if err := f.walletRepo.AdvanceVersion(ctx, &taxWallet); err != nil {
+		return err
+	}
 	if err := f.balanceSnapshotRepo.Save(ctx, taxBS); err != nil {
 		return err
 	}
@@ -1076,7 +1608,7 @@ func (f *CryptoPaymentFlowImpl) creditOnConfirmed(ctx context.Context, cpr *mode
 
 	// System locked
 	newSystemLocked := systemBalance.LockedBalance + realSystemShare
-	metadataMap["source"] = "crypto_increase_system_locked_(real_system_share)"
+	metadataMap["source"] = models.TransactionSourceCryptoIncreaseRealSystemShare
 	metadataMap["operation"] = "increase_system_locked"
 	b, _ = json.Marshal(metadataMap)
 	sysBS := &models.BalanceSnapshot{
@@ -1095,6 +1627,9 @@ func (f *CryptoPaymentFlowImpl) creditOnConfirmed(ctx context.Context, cpr *mode
 		Description:        fmt.Sprintf("System share for crypto request %d", cpr.ID),
 		Metadata:           b,
 	}
+	if err := f.walletRepo.AdvanceVersion(ctx, &systemWallet); err != nil {
+		return err
+	}
 	if err := f.balanceSnapshotRepo.Save(ctx, sysBS); err != nil {
 		return err
 	}
@@ -1121,17 +1656,25 @@ func (f *CryptoPaymentFlowImpl) creditOnConfirmed(ctx context.Context, cpr *mode
 		return err
 	}
 
-	// finalize request and deposit
+	// finalize request and deposits
 	now := utils.UTCNow()
-	dep.CreditedAt = &now
-	if err := f.cdRepo.Update(ctx, dep); err != nil {
-		return err
+	for _, d := range deposits {
+		d.CreditedAt = &now
+		if err := f.cdRepo.Update(ctx, d); err != nil {
+			return err
+		}
 	}
+	m["settlement"] = settlement
+	b, _ = json.Marshal(m)
+	cpr.Metadata = b
 	cpr.CreditedAt = &now
 	cpr.ConfirmedAt = dep.ConfirmedAt
 	cpr.DetectedAt = dep.DetectedAt
 	cpr.Status = models.CryptoPaymentStatusCredited
 	cpr.StatusReason = "crypto payment credited"
+	if settlement.Kind != CryptoSettlementExact {
+		cpr.StatusReason = fmt.Sprintf("crypto payment %s: credited %d of %d toman", settlement.Kind, realWithTax, requestedWithTax)
+	}
 	if err := f.cprRepo.Update(ctx, cpr); err != nil {
 		return err
 	}
@@ -1144,5 +1687,6 @@ func (f *CryptoPaymentFlowImpl) creditOnConfirmed(ctx context.Context, cpr *mode
 		},
 	}
 	_ = createAuditLog(ctx, f.auditRepo, &cust, models.AuditActionWalletChargeCompleted, msg, true, nil, metadata)
+	notifyInbox(ctx, f.notificationRepo, cpr.CustomerID, models.NotificationKindPaymentCredited, i18n.Args{"Amount": real + customerCredit})
 	return nil
 }
@@ -0,0 +1,176 @@
+package businessflow
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/i18n"
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+)
+
+// Settlement kinds of a credited crypto payment request
+const (
+	CryptoSettlementExact     = "exact"
+	CryptoSettlementUnderpaid = "underpaid"
+	CryptoSettlementOverpaid  = "overpaid"
+)
+
+// cryptoSettlement is what a crypto payment request is credited with, given
+// the coins received and the exchange rate locked when it was created
+type cryptoSettlement struct {
+	Kind          string `json:"kind"`
+	ExpectedCoin  string `json:"expected_coin_amount"`
+	ReceivedCoin  string `json:"received_coin_amount"`
+	LockedRate    string `json:"locked_exchange_rate,omitempty"` // toman per coin
+	CreditedToman uint64 `json:"credited_amount_toman"`
+}
+
+// lockedExchangeRate returns the toman per coin rate implied by the amount
+// requested and the coin amount the customer was asked to pay
+func lockedExchangeRate(fiatToman uint64, expectedCoin string) string {
+	coins, err := strconv.ParseFloat(expectedCoin, 64)
+	if err != nil || coins <= 0 || fiatToman == 0 {
+		return ""
+	}
+	return strconv.FormatFloat(float64(fiatToman)/coins, 'f', 4, 64)
+}
+
+// settleCryptoPayment decides how much of a request to credit. A payment
+// within toleranceBPS of the requested amount, or one the provider reports
+// as paid in full, is credited as requested; an overpayment is credited at
+// the locked rate. An underpayment is credited at the locked rate once the
+// payment window has closed, since the customer may still send the rest.
+// It returns false when nothing should be credited yet.
+func settleCryptoPayment(requested uint64, expectedCoin, lockedRate string, received float64, paidInFull, windowClosed bool, toleranceBPS int) (cryptoSettlement, bool) {
+	s := cryptoSettlement{
+		Kind:          CryptoSettlementExact,
+		ExpectedCoin:  expectedCoin,
+		ReceivedCoin:  strconv.FormatFloat(received, 'f', -1, 64),
+		LockedRate:    lockedRate,
+		CreditedToman: requested,
+	}
+	rate, err := strconv.ParseFloat(lockedRate, 64)
+	if err != nil || rate <= 0 || received < 0 {
+		// Without a locked rate the received coins can not be valued
+		return s, paidInFull
+	}
+
+	receivedToman := uint64(math.Floor(received * rate))
+	tolerance := float64(requested) * float64(toleranceBPS) / 10000
+	switch {
+	case float64(receivedToman) > float64(requested)+tolerance:
+		s.Kind = CryptoSettlementOverpaid
+		s.CreditedToman = receivedToman
+		return s, true
+	case paidInFull || float64(receivedToman) >= float64(requested)-tolerance:
+		return s, true
+	case !windowClosed || receivedToman == 0:
+		return s, false
+	default:
+		s.Kind = CryptoSettlementUnderpaid
+		s.CreditedToman = receivedToman
+		return s, true
+	}
+}
+
+// settlementFor values the confirmed deposits of a request. Deposits in
+// another coin than the requested one can not be valued at the locked rate,
+// so they only settle a request the provider reports as paid in full.
+func (f *CryptoPaymentFlowImpl) settlementFor(cpr *models.CryptoPaymentRequest, deposits []*models.CryptoDeposit) (cryptoSettlement, bool) {
+	var received float64
+	lockedRate := cryptoRequestLockedRate(cpr)
+	for _, d := range deposits {
+		if d.ConfirmedAt == nil {
+			continue
+		}
+		if d.Coin != "" && !strings.EqualFold(string(d.Coin), string(cpr.Coin)) {
+			lockedRate = ""
+			continue
+		}
+		amount, err := strconv.ParseFloat(d.AmountCoin, 64)
+		if err != nil {
+			log.Printf("crypto request %d: invalid deposit amount %q", cpr.ID, d.AmountCoin)
+			continue
+		}
+		received += amount
+	}
+	paidInFull := cpr.Status == models.CryptoPaymentStatusConfirmed
+	windowClosed := cpr.Status == models.CryptoPaymentStatusExpired ||
+		(cpr.ExpiresAt != nil && cpr.ExpiresAt.Before(utils.UTCNow()))
+	return settleCryptoPayment(cpr.FiatAmountToman, cpr.ExpectedCoinAmount, lockedRate, received, paidInFull, windowClosed, f.cryptoCfg.PaymentToleranceBPS)
+}
+
+func cryptoRequestLockedRate(cpr *models.CryptoPaymentRequest) string {
+	var m map[string]any
+	_ = json.Unmarshal(cpr.Metadata, &m)
+	rate, _ := m["locked_exchange_rate"].(string)
+	return rate
+}
+
+func cryptoRequestSettlement(cpr *models.CryptoPaymentRequest) *cryptoSettlement {
+	var m struct {
+		Settlement *cryptoSettlement `json:"settlement"`
+	}
+	_ = json.Unmarshal(cpr.Metadata, &m)
+	return m.Settlement
+}
+
+// notifyExpired tells the customer a request expired without being paid
+func (f *CryptoPaymentFlowImpl) notifyExpired(ctx context.Context, cpr *models.CryptoPaymentRequest) {
+	if f.notifier == nil {
+		return
+	}
+	customer, err := getCustomer(ctx, f.customerRepo, cpr.CustomerID)
+	if err != nil {
+		log.Printf("crypto expiry notice for request %d: %v", cpr.ID, err)
+		return
+	}
+	msg := f.localizer.Customer(&customer, "crypto.request_expired", i18n.Args{
+		"Requested": cpr.FiatAmountToman,
+		"Expected":  cpr.ExpectedCoinAmount,
+		"Coin":      string(cpr.Coin),
+	})
+	title := f.localizer.Customer(&customer, "push.crypto_payment.title", nil)
+	smsCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := sendCustomerNotice(smsCtx, f.notifier, f.pushDeviceRepo, &customer, title, msg); err != nil {
+		log.Printf("crypto expiry notice for request %d: %v", cpr.ID, err)
+	}
+}
+
+// notifySettlement tells the customer when a request was credited with more
+// or less than requested. It is called after the crediting transaction.
+func (f *CryptoPaymentFlowImpl) notifySettlement(ctx context.Context, cpr *models.CryptoPaymentRequest) {
+	s := cryptoRequestSettlement(cpr)
+	if f.notifier == nil || s == nil || s.Kind == CryptoSettlementExact {
+		return
+	}
+	customer, err := getCustomer(ctx, f.customerRepo, cpr.CustomerID)
+	if err != nil {
+		log.Printf("crypto settlement notice for request %d: %v", cpr.ID, err)
+		return
+	}
+	key := "crypto.underpaid_credited"
+	if s.Kind == CryptoSettlementOverpaid {
+		key = "crypto.overpaid_credited"
+	}
+	msg := f.localizer.Customer(&customer, key, i18n.Args{
+		"Received":  s.ReceivedCoin,
+		"Expected":  s.ExpectedCoin,
+		"Coin":      string(cpr.Coin),
+		"Credited":  s.CreditedToman,
+		"Requested": cpr.FiatAmountToman,
+	})
+	title := f.localizer.Customer(&customer, "push.crypto_payment.title", nil)
+	smsCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := sendCustomerNotice(smsCtx, f.notifier, f.pushDeviceRepo, &customer, title, msg); err != nil {
+		log.Printf("crypto settlement notice for request %d: %v", cpr.ID, err)
+	}
+}
@@ -0,0 +1,57 @@
+package businessflow
+
+import "testing"
+
+func TestSettleCryptoPayment(t *testing.T) {
+	t.Parallel()
+
+	// 1,000,000 toman requested as 10 USDT, locked at 100,000 toman per USDT
+	tests := []struct {
+		name         string
+		lockedRate   string
+		received     float64
+		paidInFull   bool
+		windowClosed bool
+		wantReady    bool
+		wantKind     string
+		wantCredited uint64
+	}{
+		{name: "exact", lockedRate: "100000", received: 10, wantReady: true, wantKind: CryptoSettlementExact, wantCredited: 1_000_000},
+		{name: "within tolerance", lockedRate: "100000", received: 9.95, wantReady: true, wantKind: CryptoSettlementExact, wantCredited: 1_000_000},
+		{name: "underpaid while open", lockedRate: "100000", received: 6, wantReady: false},
+		{name: "underpaid after window", lockedRate: "100000", received: 6, windowClosed: true, wantReady: true, wantKind: CryptoSettlementUnderpaid, wantCredited: 600_000},
+		{name: "underpaid but paid in full", lockedRate: "100000", received: 9.5, paidInFull: true, wantReady: true, wantKind: CryptoSettlementExact, wantCredited: 1_000_000},
+		{name: "nothing received after window", lockedRate: "100000", received: 0, windowClosed: true, wantReady: false},
+		{name: "overpaid", lockedRate: "100000", received: 12.5, wantReady: true, wantKind: CryptoSettlementOverpaid, wantCredited: 1_250_000},
+		{name: "no locked rate", received: 6, paidInFull: true, wantReady: true, wantKind: CryptoSettlementExact, wantCredited: 1_000_000},
+		{name: "no locked rate while pending", received: 6, wantReady: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ready := settleCryptoPayment(1_000_000, "10", tt.lockedRate, tt.received, tt.paidInFull, tt.windowClosed, 100)
+			if ready != tt.wantReady {
+				t.Fatalf("ready = %v, want %v", ready, tt.wantReady)
+			}
+			if !ready {
+				return
+			}
+			if got.Kind != tt.wantKind || got.CreditedToman != tt.wantCredited {
+				t.Fatalf("settlement = %s %d, want %s %d", got.Kind, got.CreditedToman, tt.wantKind, tt.wantCredited)
+			}
+		})
+	}
+}
+
+func TestLockedExchangeRate(t *testing.T) {
+	t.Parallel()
+
+	if got := lockedExchangeRate(1_000_000, "8"); got != "125000.0000" {
+		t.Errorf("lockedExchangeRate = %q", got)
+	}
+	for _, coins := range []string{"", "0", "abc"} {
+		if got := lockedExchangeRate(1_000_000, coins); got != "" {
+			t.Errorf("lockedExchangeRate(%q) = %q, want empty", coins, got)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package businessflow
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/app/services"
+	"github.com/amirphl/Yamata-no-Orochi/models"
+)
+
+// cryptoWebhookMaxSkew is how far in the future a provider callback may be
+// dated, to allow for clock drift
+const cryptoWebhookMaxSkew = 5 * time.Minute
+
+// newCryptoWebhookEvent identifies a callback by its provider request ID,
+// tx hashes with their statuses, and status. A re-delivered callback gets the
+// same event key; one reporting progress gets a new one.
+func newCryptoWebhookEvent(platform models.CryptoPlatform, trackID, status string, txs []string, eventAt *time.Time) *models.CryptoWebhookEvent {
+	txs = slices.Clone(txs)
+	slices.Sort(txs)
+	sum := sha256.Sum256([]byte(strings.Join([]string{trackID, strings.ToLower(status), strings.Join(txs, ",")}, "|")))
+
+	hashes := make([]string, 0, len(txs))
+	for _, tx := range txs {
+		hash, _, _ := strings.Cut(tx, "=")
+		if hash != "" && !slices.Contains(hashes, hash) {
+			hashes = append(hashes, hash)
+		}
+	}
+	return &models.CryptoWebhookEvent{
+		Platform: platform,
+		EventKey: hex.EncodeToString(sum[:]),
+		TrackID:  trackID,
+		TxHash:   strings.Join(hashes, ","),
+		Status:   strings.ToLower(status),
+		EventAt:  eventAt,
+	}
+}
+
+// oxapayWebhookEvent identifies an OxaPay callback
+func oxapayWebhookEvent(payload *dto.OxapayWebhookPayload) *models.CryptoWebhookEvent {
+	txs := make([]string, 0, len(payload.Txs))
+	for _, t := range payload.Txs {
+		txs = append(txs, t.TxHash+"="+strings.ToLower(t.Status))
+	}
+	var eventAt *time.Time
+	if payload.Date > 0 {
+		t := time.Unix(payload.Date, 0).UTC()
+		eventAt = &t
+	}
+	return newCryptoWebhookEvent(models.CryptoPlatformOxapay, payload.TrackID, payload.Status, txs, eventAt)
+}
+
+// nowPaymentsWebhookEvent identifies a NOWPayments IPN. Partial payments
+// keep the status, so the amount paid so far is part of the key.
+func nowPaymentsWebhookEvent(payment *services.NowPaymentsPayment) *models.CryptoWebhookEvent {
+	var txs []string
+	if payment.PayinHash != "" || payment.ActuallyPaid != "" {
+		txs = append(txs, payment.PayinHash+"="+payment.ActuallyPaid.String())
+	}
+	return newCryptoWebhookEvent(models.CryptoPlatformNowPayments, payment.PaymentID.String(), payment.PaymentStatus, txs, payment.UpdatedTime())
+}
+
+// checkCryptoWebhookFresh rejects a callback dated more than maxAge ago or
+// too far in the future. Undated callbacks are accepted.
+func checkCryptoWebhookFresh(eventAt *time.Time, now time.Time, maxAge time.Duration) error {
+	if eventAt == nil {
+		return nil
+	}
+	if age := now.Sub(*eventAt); age > maxAge || age < -cryptoWebhookMaxSkew {
+		return NewBusinessError("CRYPTO_WEBHOOK_STALE", fmt.Sprintf("webhook dated %s", eventAt.Format(time.RFC3339)), ErrCryptoWebhookStale)
+	}
+	return nil
+}
+
+// recordWebhookEvent records a callback within the transaction applying it,
+// and reports false when the callback was already applied. A concurrent
+// delivery of the same callback waits on the unique key until the first one
+// commits or rolls back.
+func (f *CryptoPaymentFlowImpl) recordWebhookEvent(ctx context.Context, event *models.CryptoWebhookEvent) (bool, error) {
+	return f.webhookEventRepo.Record(ctx, event)
+}
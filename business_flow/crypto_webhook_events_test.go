@@ -0,0 +1,73 @@
+package businessflow
+
+import (
+	"testing"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+)
+
+func TestOxapayWebhookEventKey(t *testing.T) {
+	t.Parallel()
+
+	payload := dto.OxapayWebhookPayload{
+		TrackID: "151811887",
+		Status:  "Paying",
+		Date:    1736500000,
+		Txs: []dto.OxapayWebhookTx{
+			{TxHash: "0xaaa", Status: "confirming"},
+			{TxHash: "0xbbb", Status: "confirmed"},
+		},
+	}
+	event := oxapayWebhookEvent(&payload)
+	if event.TxHash != "0xaaa,0xbbb" || event.Status != "paying" || event.EventAt == nil || event.EventAt.Unix() != payload.Date {
+		t.Fatalf("event = %+v", event)
+	}
+
+	// The same callback with its txs reordered is a re-delivery
+	redelivered := payload
+	redelivered.Txs = []dto.OxapayWebhookTx{payload.Txs[1], payload.Txs[0]}
+	if got := oxapayWebhookEvent(&redelivered).EventKey; got != event.EventKey {
+		t.Fatalf("re-delivered key = %s, want %s", got, event.EventKey)
+	}
+
+	// A tx confirming or the invoice being paid is progress
+	confirmed := payload
+	confirmed.Txs = []dto.OxapayWebhookTx{{TxHash: "0xaaa", Status: "confirmed"}, payload.Txs[1]}
+	paid := payload
+	paid.Status = "Paid"
+	for name, p := range map[string]dto.OxapayWebhookPayload{"tx confirmed": confirmed, "paid": paid} {
+		if oxapayWebhookEvent(&p).EventKey == event.EventKey {
+			t.Fatalf("%s: key unchanged", name)
+		}
+	}
+}
+
+func TestCheckCryptoWebhookFresh(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) *time.Time {
+		t := now.Add(d)
+		return &t
+	}
+	tests := []struct {
+		name      string
+		eventAt   *time.Time
+		wantStale bool
+	}{
+		{"undated", nil, false},
+		{"recent", at(-time.Hour), false},
+		{"older than max age", at(-25 * time.Hour), true},
+		{"slightly ahead", at(time.Minute), false},
+		{"far ahead", at(time.Hour), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkCryptoWebhookFresh(tt.eventAt, now, 24*time.Hour)
+			if IsCryptoWebhookStale(err) != tt.wantStale {
+				t.Fatalf("err = %v, want stale %v", err, tt.wantStale)
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package businessflow
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/repository"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+)
+
+const (
+	customerUsageDefaultMonths    = 12
+	campaignDailyStatsDefaultDays = 30
+	campaignDailyStatsMaxDays     = 366
+)
+
+// CustomerAnalyticsFlow serves customers' usage and campaign statistics from
+// the reporting rollups
+type CustomerAnalyticsFlow interface {
+	GetUsage(ctx context.Context, req *dto.CustomerUsageReportRequest) (*dto.CustomerUsageReportResponse, error)
+	GetCampaignDailyStats(ctx context.Context, req *dto.CampaignDailyStatsRequest) (*dto.CampaignDailyStatsResponse, error)
+}
+
+type CustomerAnalyticsFlowImpl struct {
+	rollupRepo   repository.ReportRollupRepository
+	campaignRepo repository.CampaignRepository
+}
+
+func NewCustomerAnalyticsFlow(rollupRepo repository.ReportRollupRepository, campaignRepo repository.CampaignRepository) CustomerAnalyticsFlow {
+	return &CustomerAnalyticsFlowImpl{
+		rollupRepo:   rollupRepo,
+		campaignRepo: campaignRepo,
+	}
+}
+
+// GetUsage returns the customer's usage in the last months, the current
+// Tehran month included; 12 months by default
+func (f *CustomerAnalyticsFlowImpl) GetUsage(ctx context.Context, req *dto.CustomerUsageReportRequest) (*dto.CustomerUsageReportResponse, error) {
+	months := req.Months
+	if months <= 0 {
+		months = customerUsageDefaultMonths
+	}
+	_, toMonth := utils.TehranMonthBounds(utils.UTCNow())
+	fromMonth := toMonth.AddDate(0, -months, 0)
+
+	rows, err := f.rollupRepo.CustomerMonthlyUsage(ctx, req.CustomerID, fromMonth, toMonth)
+	if err != nil {
+		return nil, NewBusinessError("CUSTOMER_USAGE_REPORT_FAILED", "Failed to get usage report", err)
+	}
+	items := make([]dto.CustomerMonthlyUsageItem, 0, len(rows))
+	for _, r := range rows {
+		items = append(items, dto.CustomerMonthlyUsageItem{
+			Month:              r.Month.Format("2006-01-02"),
+			Campaigns:          r.Campaigns,
+			SentMessages:       r.SentMessages,
+			SuccessfulMessages: r.SuccessfulMessages,
+			DeliveredParts:     r.DeliveredParts,
+			Clicks:             r.Clicks,
+			CampaignSpend:      r.CampaignSpend,
+			CampaignRefunds:    r.CampaignRefunds,
+			NetCampaignSpend:   netCampaignSpend(r.CampaignSpend, r.CampaignRefunds),
+			Charges:            r.Charges,
+			PaidWithTax:        r.PaidWithTax,
+		})
+	}
+
+	return &dto.CustomerUsageReportResponse{
+		Message:     "Usage report retrieved successfully",
+		Months:      items,
+		RefreshedAt: f.rollupRefreshedAt(ctx),
+	}, nil
+}
+
+// GetCampaignDailyStats returns the daily statistics of one of the customer's
+// campaigns; the last 30 Tehran days by default
+func (f *CustomerAnalyticsFlowImpl) GetCampaignDailyStats(ctx context.Context, req *dto.CampaignDailyStatsRequest) (*dto.CampaignDailyStatsResponse, error) {
+	if strings.TrimSpace(req.UUID) == "" {
+		return nil, NewBusinessError("VALIDATION_ERROR", "campaign uuid is required", ErrCampaignUUIDRequired)
+	}
+	fromDay, toDay, err := parseRollupDayRange(req.StartDate, req.EndDate, utils.UTCNow())
+	if err != nil {
+		return nil, err
+	}
+
+	campaign, err := getCampaign(ctx, f.campaignRepo, req.UUID, req.CustomerID)
+	if err != nil {
+		return nil, NewBusinessError("CAMPAIGN_LOOKUP_FAILED", "Failed to lookup campaign", err)
+	}
+	rows, err := f.rollupRepo.CampaignDailyStats(ctx, campaign.ID, fromDay, toDay.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, NewBusinessError("CAMPAIGN_DAILY_STATS_FAILED", "Failed to get campaign daily statistics", err)
+	}
+	days := make([]dto.CampaignDailyStatItem, 0, len(rows))
+	for _, r := range rows {
+		days = append(days, dto.CampaignDailyStatItem{
+			Date:               r.StatDate.Format("2006-01-02"),
+			SentMessages:       r.SentMessages,
+			SuccessfulMessages: r.SuccessfulMessages,
+			DeliveredParts:     r.DeliveredParts,
+			Clicks:             r.Clicks,
+			Spend:              r.Spend,
+			Refunds:            r.Refunds,
+		})
+	}
+
+	return &dto.CampaignDailyStatsResponse{
+		Message:     "Campaign daily statistics retrieved successfully",
+		UUID:        campaign.UUID.String(),
+		StartDate:   fromDay.Format("2006-01-02"),
+		EndDate:     toDay.Format("2006-01-02"),
+		Days:        days,
+		RefreshedAt: f.rollupRefreshedAt(ctx),
+	}, nil
+}
+
+// rollupRefreshedAt returns when the rollups were last refreshed, or nil if
+// they never were or the watermark cannot be read
+func (f *CustomerAnalyticsFlowImpl) rollupRefreshedAt(ctx context.Context) *string {
+	state, err := f.rollupRepo.State(ctx, models.ReportRollupDaily)
+	if err != nil || state == nil {
+		return nil
+	}
+	refreshedAt := state.RefreshedAt.UTC().Format(time.RFC3339)
+	return &refreshedAt
+}
+
+// parseRollupDayRange parses optional inclusive YYYY-MM-DD bounds as Tehran
+// days. A missing end is today and a missing start is 30 days before the end.
+func parseRollupDayRange(start, end *string, now time.Time) (time.Time, time.Time, error) {
+	loc := utils.TehranLocation()
+	toDay, _ := utils.TehranDayBounds(now)
+	if end != nil && strings.TrimSpace(*end) != "" {
+		t, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(*end), loc)
+		if err != nil {
+			return time.Time{}, time.Time{}, NewBusinessError("VALIDATION_ERROR", "Invalid end_date format", err)
+		}
+		toDay = t
+	}
+	fromDay := toDay.AddDate(0, 0, -(campaignDailyStatsDefaultDays - 1))
+	if start != nil && strings.TrimSpace(*start) != "" {
+		t, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(*start), loc)
+		if err != nil {
+			return time.Time{}, time.Time{}, NewBusinessError("VALIDATION_ERROR", "Invalid start_date format", err)
+		}
+		fromDay = t
+	}
+
+	if toDay.Before(fromDay) {
+		return time.Time{}, time.Time{}, NewBusinessError("VALIDATION_ERROR", "start_date must not be after end_date", ErrStartDateAfterEndDate)
+	}
+	if toDay.Sub(fromDay) >= campaignDailyStatsMaxDays*24*time.Hour {
+		return time.Time{}, time.Time{}, NewBusinessError("VALIDATION_ERROR", "Range cannot exceed 366 days", nil)
+	}
+	return fromDay, toDay, nil
+}
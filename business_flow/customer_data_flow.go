@@ -0,0 +1,805 @@
+package businessflow
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/app/services"
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/repository"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/gofiber/fiber/v3/log"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	// A processing request not updated for this long is assumed abandoned and retried
+	customerDataStaleAfter = 15 * time.Minute
+	// Expired export files removed per scheduler run
+	customerDataPurgeBatch = 100
+)
+
+// CustomerDataFlow lets customers export their data and delete their account.
+//
+// Both are queued as customer data requests and carried out in the background
+// by the customer data scheduler; customers poll the request for its status.
+// An export is a zip of the customer's profile, campaigns and transactions as
+// JSON or CSV files, downloadable until it expires. A deletion waits for a
+// grace period during which the customer can cancel it; then the account is
+// deactivated and its personal data anonymized, while wallets, transactions
+// and campaigns are kept as financial records. An account that still holds
+// wallet balance or active campaigns cannot be deleted.
+type CustomerDataFlow interface {
+	RequestExport(ctx context.Context, customerID uint, req *dto.DataExportRequest, metadata *ClientMetadata) (*dto.CustomerDataRequestResponse, error)
+	RequestDeletion(ctx context.Context, customerID uint, req *dto.AccountDeletionRequest, metadata *ClientMetadata) (*dto.CustomerDataRequestResponse, error)
+	CancelDeletion(ctx context.Context, customerID uint, metadata *ClientMetadata) (*dto.CustomerDataRequestResponse, error)
+	GetRequest(ctx context.Context, customerID uint, requestUUID string) (*dto.CustomerDataRequestResponse, error)
+	DownloadExport(ctx context.Context, customerID uint, requestUUID string) (string, []byte, error)
+	ProcessNextRequest(ctx context.Context) (bool, error)
+	PurgeExpiredExports(ctx context.Context) (int, error)
+}
+
+type CustomerDataFlowImpl struct {
+	requestRepo     repository.CustomerDataRequestRepository
+	customerRepo    repository.CustomerRepository
+	campaignRepo    repository.CampaignRepository
+	transactionRepo repository.TransactionRepository
+	walletRepo      repository.WalletRepository
+	sessionRepo     repository.CustomerSessionRepository
+	knownDeviceRepo repository.CustomerKnownDeviceRepository
+	changeRepo      repository.CustomerProfileChangeRepository
+	auditRepo       repository.AuditLogRepository
+	sessionStore    services.SessionStore
+	passwordHasher  services.PasswordHasher
+	db              *gorm.DB
+	gracePeriod     time.Duration
+	exportRetention time.Duration
+}
+
+func NewCustomerDataFlow(
+	requestRepo repository.CustomerDataRequestRepository,
+	customerRepo repository.CustomerRepository,
+	campaignRepo repository.CampaignRepository,
+	transactionRepo repository.TransactionRepository,
+	walletRepo repository.WalletRepository,
+	sessionRepo repository.CustomerSessionRepository,
+	knownDeviceRepo repository.CustomerKnownDeviceRepository,
+	changeRepo repository.CustomerProfileChangeRepository,
+	auditRepo repository.AuditLogRepository,
+	sessionStore services.SessionStore,
+	passwordHasher services.PasswordHasher,
+	db *gorm.DB,
+	gracePeriod time.Duration,
+	exportRetention time.Duration,
+) CustomerDataFlow {
+	return &CustomerDataFlowImpl{
+		requestRepo:     requestRepo,
+		customerRepo:    customerRepo,
+		campaignRepo:    campaignRepo,
+		transactionRepo: transactionRepo,
+		walletRepo:      walletRepo,
+		sessionRepo:     sessionRepo,
+		knownDeviceRepo: knownDeviceRepo,
+		changeRepo:      changeRepo,
+		auditRepo:       auditRepo,
+		sessionStore:    sessionStore,
+		passwordHasher:  passwordHasher,
+		db:              db,
+		gracePeriod:     gracePeriod,
+		exportRetention: exportRetention,
+	}
+}
+
+func customerExportDir() string {
+	return filepath.Join("data", "customer_exports")
+}
+
+// RequestExport queues an export of the customer's data
+func (f *CustomerDataFlowImpl) RequestExport(ctx context.Context, customerID uint, req *dto.DataExportRequest, metadata *ClientMetadata) (*dto.CustomerDataRequestResponse, error) {
+	format := models.CustomerDataExportFormatJSON
+	if req != nil && strings.TrimSpace(req.Format) != "" {
+		format = models.CustomerDataExportFormat(strings.ToLower(strings.TrimSpace(req.Format)))
+	}
+	if format != models.CustomerDataExportFormatJSON && format != models.CustomerDataExportFormatCSV {
+		return nil, NewBusinessError("DATA_EXPORT_FORMAT_INVALID", "Export format must be json or csv", ErrDataExportFormatInvalid)
+	}
+
+	customer, err := f.activeCustomer(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	inProgress, err := f.requestRepo.Exists(ctx, models.CustomerDataRequestFilter{
+		CustomerID: &customerID,
+		Type:       utils.ToPtr(models.CustomerDataRequestTypeExport),
+		Statuses:   []models.CustomerDataRequestStatus{models.CustomerDataRequestStatusPending, models.CustomerDataRequestStatusProcessing},
+	})
+	if err != nil {
+		return nil, NewBusinessError("DATA_REQUEST_LOOKUP_FAILED", "Failed to lookup data requests", err)
+	}
+	if inProgress {
+		return nil, NewBusinessError("DATA_EXPORT_IN_PROGRESS", "A data export is already in progress", ErrDataExportInProgress)
+	}
+
+	request := &models.CustomerDataRequest{
+		UUID:       uuid.New(),
+		CustomerID: customerID,
+		Type:       models.CustomerDataRequestTypeExport,
+		Status:     models.CustomerDataRequestStatusPending,
+		Format:     &format,
+	}
+	if err := f.requestRepo.Save(ctx, request); err != nil {
+		errMsg := err.Error()
+		_ = createAuditLog(ctx, f.auditRepo, customer, models.AuditActionDataExportRequested, "Data export request failed", false, &errMsg, metadata)
+		return nil, NewBusinessError("DATA_EXPORT_REQUEST_FAILED", "Failed to queue data export", err)
+	}
+	_ = createAuditLog(ctx, f.auditRepo, customer, models.AuditActionDataExportRequested, fmt.Sprintf("Data export %s requested as %s", request.UUID, format), true, nil, metadata)
+
+	return &dto.CustomerDataRequestResponse{
+		Message: "Data export queued",
+		Request: toCustomerDataRequestItem(request),
+	}, nil
+}
+
+// RequestDeletion schedules the deletion of the customer's account after the
+// grace period. The customer confirms with their password.
+func (f *CustomerDataFlowImpl) RequestDeletion(ctx context.Context, customerID uint, req *dto.AccountDeletionRequest, metadata *ClientMetadata) (*dto.CustomerDataRequestResponse, error) {
+	if req == nil || req.Password == "" {
+		return nil, NewBusinessError("VALIDATION_ERROR", "Password is required", nil)
+	}
+
+	customer, err := f.activeCustomer(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+	if isSystemOrTaxCustomer(customer) {
+		return nil, NewBusinessError("ACCOUNT_DELETION_NOT_ALLOWED", "This account cannot be deleted", ErrAccountDeletionNotAllowed)
+	}
+	if ok, err := f.passwordHasher.Verify(req.Password, customer.PasswordHash); err != nil || !ok {
+		errMsg := ErrIncorrectPassword.Error()
+		_ = createAuditLog(ctx, f.auditRepo, customer, models.AuditActionDeletionRequested, "Account deletion request failed", false, &errMsg, metadata)
+		return nil, NewBusinessError("INCORRECT_PASSWORD", "Incorrect password", ErrIncorrectPassword)
+	}
+
+	scheduled, err := f.openDeletion(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+	if scheduled != nil {
+		return nil, NewBusinessError("ACCOUNT_DELETION_SCHEDULED", "Account deletion is already scheduled", ErrAccountDeletionScheduled)
+	}
+	if err := f.checkDeletable(ctx, customerID); err != nil {
+		return nil, err
+	}
+
+	request := &models.CustomerDataRequest{
+		UUID:         uuid.New(),
+		CustomerID:   customerID,
+		Type:         models.CustomerDataRequestTypeDeletion,
+		Status:       models.CustomerDataRequestStatusScheduled,
+		ScheduledFor: utils.ToPtr(utils.UTCNow().Add(f.gracePeriod)),
+	}
+	if err := f.requestRepo.Save(ctx, request); err != nil {
+		errMsg := err.Error()
+		_ = createAuditLog(ctx, f.auditRepo, customer, models.AuditActionDeletionRequested, "Account deletion request failed", false, &errMsg, metadata)
+		return nil, NewBusinessError("ACCOUNT_DELETION_REQUEST_FAILED", "Failed to schedule account deletion", err)
+	}
+	_ = createAuditLog(ctx, f.auditRepo, customer, models.AuditActionDeletionRequested,
+		fmt.Sprintf("Account deletion %s scheduled for %s", request.UUID, request.ScheduledFor.Format(time.RFC3339)), true, nil, metadata)
+
+	return &dto.CustomerDataRequestResponse{
+		Message: "Account deletion scheduled",
+		Request: toCustomerDataRequestItem(request),
+	}, nil
+}
+
+// CancelDeletion cancels a deletion that is still in its grace period
+func (f *CustomerDataFlowImpl) CancelDeletion(ctx context.Context, customerID uint, metadata *ClientMetadata) (*dto.CustomerDataRequestResponse, error) {
+	customer, err := f.activeCustomer(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	request, err := f.openDeletion(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+	if request == nil || request.Status != models.CustomerDataRequestStatusScheduled {
+		return nil, NewBusinessError("ACCOUNT_DELETION_NOT_FOUND", "No account deletion is scheduled", ErrAccountDeletionNotFound)
+	}
+
+	request.Status = models.CustomerDataRequestStatusCancelled
+	request.CancelledAt = utils.ToPtr(utils.UTCNow())
+	if err := f.requestRepo.Update(ctx, request); err != nil {
+		return nil, NewBusinessError("ACCOUNT_DELETION_CANCEL_FAILED", "Failed to cancel account deletion", err)
+	}
+	_ = createAuditLog(ctx, f.auditRepo, customer, models.AuditActionDeletionCancelled, fmt.Sprintf("Account deletion %s cancelled", request.UUID), true, nil, metadata)
+
+	return &dto.CustomerDataRequestResponse{
+		Message: "Account deletion cancelled",
+		Request: toCustomerDataRequestItem(request),
+	}, nil
+}
+
+// GetRequest returns the status of one of the customer's data requests
+func (f *CustomerDataFlowImpl) GetRequest(ctx context.Context, customerID uint, requestUUID string) (*dto.CustomerDataRequestResponse, error) {
+	request, err := f.customerRequest(ctx, customerID, requestUUID)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.CustomerDataRequestResponse{
+		Message: "Data request retrieved successfully",
+		Request: toCustomerDataRequestItem(request),
+	}, nil
+}
+
+// DownloadExport returns the file name and content of a finished export
+func (f *CustomerDataFlowImpl) DownloadExport(ctx context.Context, customerID uint, requestUUID string) (string, []byte, error) {
+	request, err := f.customerRequest(ctx, customerID, requestUUID)
+	if err != nil {
+		return "", nil, err
+	}
+	if request.Type != models.CustomerDataRequestTypeExport {
+		return "", nil, NewBusinessError("DATA_REQUEST_NOT_FOUND", "Data export not found", ErrDataRequestNotFound)
+	}
+	if request.Status != models.CustomerDataRequestStatusCompleted {
+		return "", nil, NewBusinessError("DATA_EXPORT_NOT_READY", "Data export is not ready yet", ErrDataExportNotReady)
+	}
+	if request.FilePath == nil || (request.ExpiresAt != nil && !utils.UTCNow().Before(*request.ExpiresAt)) {
+		return "", nil, NewBusinessError("DATA_EXPORT_EXPIRED", "Data export has expired", ErrDataExportExpired)
+	}
+
+	data, err := os.ReadFile(*request.FilePath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", nil, NewBusinessError("DATA_EXPORT_EXPIRED", "Data export has expired", ErrDataExportExpired)
+		}
+		return "", nil, NewBusinessError("DATA_EXPORT_READ_FAILED", "Failed to read data export", err)
+	}
+	return fmt.Sprintf("data-export-%s.zip", request.UUID), data, nil
+}
+
+// ProcessNextRequest claims one pending export, due deletion or abandoned
+// request and carries it out. It reports whether a request was claimed.
+func (f *CustomerDataFlowImpl) ProcessNextRequest(ctx context.Context) (bool, error) {
+	now := utils.UTCNow()
+	request, err := f.requestRepo.ClaimNext(ctx, now, now.Add(-customerDataStaleAfter))
+	if err != nil {
+		return false, NewBusinessError("DATA_REQUEST_CLAIM_FAILED", "Failed to claim data request", err)
+	}
+	if request == nil {
+		return false, nil
+	}
+
+	switch request.Type {
+	case models.CustomerDataRequestTypeExport:
+		err = f.processExport(ctx, request)
+	case models.CustomerDataRequestTypeDeletion:
+		err = f.processDeletion(ctx, request)
+	default:
+		err = fmt.Errorf("unknown data request type %q", request.Type)
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			// Left in processing; another run retries it once it goes stale
+			return true, err
+		}
+		msg := err.Error()
+		request.Status = models.CustomerDataRequestStatusFailed
+		request.ErrorMessage = &msg
+		request.CompletedAt = utils.ToPtr(utils.UTCNow())
+		if uerr := f.requestRepo.Update(ctx, request); uerr != nil {
+			return true, fmt.Errorf("data request %s failed: %w (status update: %v)", request.UUID, err, uerr)
+		}
+		return true, fmt.Errorf("data request %s failed: %w", request.UUID, err)
+	}
+	return true, nil
+}
+
+// PurgeExpiredExports removes export files whose download window closed and
+// returns how many were removed
+func (f *CustomerDataFlowImpl) PurgeExpiredExports(ctx context.Context) (int, error) {
+	requests, err := f.requestRepo.ExpiredExports(ctx, utils.UTCNow(), customerDataPurgeBatch)
+	if err != nil {
+		return 0, NewBusinessError("DATA_EXPORT_PURGE_FAILED", "Failed to list expired data exports", err)
+	}
+	for i, request := range requests {
+		if err := removeExportFile(request); err != nil {
+			return i, err
+		}
+		if err := f.requestRepo.Update(ctx, request); err != nil {
+			return i, err
+		}
+	}
+	return len(requests), nil
+}
+
+func (f *CustomerDataFlowImpl) processExport(ctx context.Context, request *models.CustomerDataRequest) error {
+	customer, err := f.customerRepo.ByID(ctx, request.CustomerID)
+	if err != nil {
+		return fmt.Errorf("lookup customer: %w", err)
+	}
+	if customer == nil || customer.DeletedAt != nil {
+		return ErrCustomerNotFound
+	}
+	campaigns, err := f.campaignRepo.ByCustomerID(ctx, customer.ID, 0, 0)
+	if err != nil {
+		return fmt.Errorf("list campaigns: %w", err)
+	}
+	transactions, err := f.transactionRepo.ByCustomerID(ctx, customer.ID, 0, 0)
+	if err != nil {
+		return fmt.Errorf("list transactions: %w", err)
+	}
+
+	format := models.CustomerDataExportFormatJSON
+	if request.Format != nil {
+		format = *request.Format
+	}
+	bundle, err := buildCustomerDataExport(format, mapCustomerToProfileDTO(customer), toCampaignExportRows(campaigns), toTransactionExportRows(transactions))
+	if err != nil {
+		return fmt.Errorf("build export: %w", err)
+	}
+
+	path := filepath.Join(customerExportDir(), request.UUID.String()+".zip")
+	if err := atomicWrite(path, bundle, 0o640); err != nil {
+		return fmt.Errorf("store export: %w", err)
+	}
+
+	now := utils.UTCNow()
+	request.Status = models.CustomerDataRequestStatusCompleted
+	request.FilePath = &path
+	request.CompletedAt = &now
+	request.ExpiresAt = utils.ToPtr(now.Add(f.exportRetention))
+	if err := f.requestRepo.Update(ctx, request); err != nil {
+		_ = os.Remove(path)
+		return fmt.Errorf("update request: %w", err)
+	}
+	_ = createAuditLog(ctx, f.auditRepo, customer, models.AuditActionDataExportCompleted,
+		fmt.Sprintf("Data export %s ready with %d campaigns and %d transactions", request.UUID, len(campaigns), len(transactions)), true, nil, nil)
+	return nil
+}
+
+// processDeletion anonymizes the customer, ends their sessions, forgets their
+// devices and removes their export files in one transaction
+func (f *CustomerDataFlowImpl) processDeletion(ctx context.Context, request *models.CustomerDataRequest) error {
+	customer, err := f.customerRepo.ByID(ctx, request.CustomerID)
+	if err != nil {
+		return fmt.Errorf("lookup customer: %w", err)
+	}
+	if customer == nil {
+		return ErrCustomerNotFound
+	}
+	if isSystemOrTaxCustomer(customer) {
+		return ErrAccountDeletionNotAllowed
+	}
+	// The customer may have topped up or launched a campaign during the grace
+	// period; the request then fails and has to be made again
+	if err := f.checkDeletable(ctx, customer.ID); err != nil {
+		return err
+	}
+
+	var exports []*models.CustomerDataRequest
+	err = repository.WithTransaction(ctx, f.db, func(txCtx context.Context) error {
+		now := utils.UTCNow()
+		if err := f.customerRepo.Anonymize(txCtx, customer.ID, now); err != nil {
+			return fmt.Errorf("anonymize customer: %w", err)
+		}
+		if err := f.sessionRepo.AnonymizeByCustomer(txCtx, customer.ID); err != nil {
+			return fmt.Errorf("end sessions: %w", err)
+		}
+		if err := f.knownDeviceRepo.ForgetAll(txCtx, customer.ID); err != nil {
+			return fmt.Errorf("forget devices: %w", err)
+		}
+		if err := f.changeRepo.AnonymizeByCustomer(txCtx, customer.ID); err != nil {
+			return fmt.Errorf("erase profile changes: %w", err)
+		}
+
+		exports, err = f.requestRepo.ByFilter(txCtx, models.CustomerDataRequestFilter{
+			CustomerID: &customer.ID,
+			Type:       utils.ToPtr(models.CustomerDataRequestTypeExport),
+		}, "", 0, 0)
+		if err != nil {
+			return fmt.Errorf("list exports: %w", err)
+		}
+		for _, export := range exports {
+			switch {
+			case export.Status == models.CustomerDataRequestStatusPending:
+				export.Status = models.CustomerDataRequestStatusCancelled
+				export.CancelledAt = &now
+			case export.FilePath != nil:
+				export.ExpiresAt = &now
+			default:
+				continue
+			}
+			if err := f.requestRepo.Update(txCtx, export); err != nil {
+				return fmt.Errorf("close export: %w", err)
+			}
+		}
+
+		request.Status = models.CustomerDataRequestStatusCompleted
+		request.CompletedAt = &now
+		return f.requestRepo.Update(txCtx, request)
+	})
+	if err != nil {
+		return err
+	}
+
+	revokeCustomerSessions(ctx, f.sessionStore, customer.ID)
+	// Files left behind are removed by PurgeExpiredExports
+	for _, export := range exports {
+		if export.FilePath == nil {
+			continue
+		}
+		if err := removeExportFile(export); err != nil {
+			log.Errorf("remove data export %s: %v", export.UUID, err)
+			continue
+		}
+		if err := f.requestRepo.Update(ctx, export); err != nil {
+			log.Errorf("update data export %s: %v", export.UUID, err)
+		}
+	}
+
+	_ = createAuditLog(ctx, f.auditRepo, customer, models.AuditActionAccountDeleted, fmt.Sprintf("Account deleted by request %s", request.UUID), true, nil, nil)
+	return nil
+}
+
+// checkDeletable refuses the deletion while the customer's wallet holds free,
+// frozen or locked balance, or a campaign still reserves budget or is due to
+// send; anonymizing the account would strand both
+func (f *CustomerDataFlowImpl) checkDeletable(ctx context.Context, customerID uint) error {
+	wallet, err := f.walletRepo.ByCustomerID(ctx, customerID)
+	if err != nil {
+		return NewBusinessError("ACCOUNT_DELETION_REQUEST_FAILED", "Failed to look up wallet", err)
+	}
+	if wallet != nil {
+		balance, err := f.walletRepo.GetCurrentBalance(ctx, wallet.ID)
+		if err != nil {
+			return NewBusinessError("ACCOUNT_DELETION_REQUEST_FAILED", "Failed to look up wallet balance", err)
+		}
+		if balance != nil && balance.FreeBalance+balance.FrozenBalance+balance.LockedBalance > 0 {
+			return NewBusinessError("ACCOUNT_DELETION_BALANCE_REMAINING", "Withdraw or spend the wallet balance before deleting the account", ErrAccountDeletionBalance)
+		}
+	}
+
+	campaigns, err := f.campaignRepo.ByCustomerID(ctx, customerID, 0, 0)
+	if err != nil {
+		return NewBusinessError("ACCOUNT_DELETION_REQUEST_FAILED", "Failed to list campaigns", err)
+	}
+	for _, campaign := range campaigns {
+		// Submitted and not yet finished, the ones the customer can cancel
+		if canCancelCampaign(campaign.Status) {
+			return NewBusinessError("ACCOUNT_DELETION_CAMPAIGNS_ACTIVE", "Cancel or finish active campaigns before deleting the account", ErrAccountDeletionCampaigns)
+		}
+	}
+	return nil
+}
+
+// activeCustomer returns the customer unless it does not exist or was deleted
+func (f *CustomerDataFlowImpl) activeCustomer(ctx context.Context, customerID uint) (*models.Customer, error) {
+	customer, err := f.customerRepo.ByID(ctx, customerID)
+	if err != nil {
+		return nil, NewBusinessError("CUSTOMER_FETCH_FAILED", "Failed to fetch customer", err)
+	}
+	if customer == nil || customer.DeletedAt != nil {
+		return nil, NewBusinessError("CUSTOMER_NOT_FOUND", "Customer not found", ErrCustomerNotFound)
+	}
+	return customer, nil
+}
+
+// openDeletion returns the customer's scheduled or processing deletion, if any
+func (f *CustomerDataFlowImpl) openDeletion(ctx context.Context, customerID uint) (*models.CustomerDataRequest, error) {
+	requests, err := f.requestRepo.ByFilter(ctx, models.CustomerDataRequestFilter{
+		CustomerID: &customerID,
+		Type:       utils.ToPtr(models.CustomerDataRequestTypeDeletion),
+		Statuses:   []models.CustomerDataRequestStatus{models.CustomerDataRequestStatusScheduled, models.CustomerDataRequestStatusProcessing},
+	}, "", 1, 0)
+	if err != nil {
+		return nil, NewBusinessError("DATA_REQUEST_LOOKUP_FAILED", "Failed to lookup data requests", err)
+	}
+	if len(requests) == 0 {
+		return nil, nil
+	}
+	return requests[0], nil
+}
+
+// customerRequest looks up a data request of the customer; requests of other
+// customers are reported as not found
+func (f *CustomerDataFlowImpl) customerRequest(ctx context.Context, customerID uint, requestUUID string) (*models.CustomerDataRequest, error) {
+	id, err := uuid.Parse(strings.TrimSpace(requestUUID))
+	if err != nil {
+		return nil, NewBusinessError("DATA_REQUEST_NOT_FOUND", "Data request not found", ErrDataRequestNotFound)
+	}
+	request, err := f.requestRepo.ByUUID(ctx, id)
+	if err != nil {
+		return nil, NewBusinessError("DATA_REQUEST_LOOKUP_FAILED", "Failed to lookup data request", err)
+	}
+	if request == nil || request.CustomerID != customerID {
+		return nil, NewBusinessError("DATA_REQUEST_NOT_FOUND", "Data request not found", ErrDataRequestNotFound)
+	}
+	return request, nil
+}
+
+// removeExportFile deletes the file of an export and clears its path
+func removeExportFile(request *models.CustomerDataRequest) error {
+	if request.FilePath == nil {
+		return nil
+	}
+	if err := os.Remove(*request.FilePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	request.FilePath = nil
+	return nil
+}
+
+func toCustomerDataRequestItem(request *models.CustomerDataRequest) dto.CustomerDataRequestItem {
+	item := dto.CustomerDataRequestItem{
+		UUID:         request.UUID.String(),
+		Type:         string(request.Type),
+		Status:       string(request.Status),
+		ErrorMessage: request.ErrorMessage,
+		CreatedAt:    request.CreatedAt.Format(time.RFC3339),
+	}
+	if request.Format != nil {
+		item.Format = utils.ToPtr(string(*request.Format))
+	}
+	formatTime := func(t *time.Time) *string {
+		if t == nil {
+			return nil
+		}
+		return utils.ToPtr(t.Format(time.RFC3339))
+	}
+	item.ScheduledFor = formatTime(request.ScheduledFor)
+	item.StartedAt = formatTime(request.StartedAt)
+	item.CompletedAt = formatTime(request.CompletedAt)
+	item.CancelledAt = formatTime(request.CancelledAt)
+	item.ExpiresAt = formatTime(request.ExpiresAt)
+	if request.Type == models.CustomerDataRequestTypeExport &&
+		request.Status == models.CustomerDataRequestStatusCompleted &&
+		request.FilePath != nil {
+		item.DownloadURL = utils.ToPtr(fmt.Sprintf("/api/v1/account/data-exports/%s/download", request.UUID))
+	}
+	return item
+}
+
+// campaignExportRow is a campaign as it appears in a data export
+type campaignExportRow struct {
+	UUID        string     `json:"uuid"`
+	Status      string     `json:"status"`
+	Phase       string     `json:"phase"`
+	Platform    string     `json:"platform"`
+	Title       *string    `json:"title,omitempty"`
+	Content     *string    `json:"content,omitempty"`
+	AdLink      *string    `json:"adlink,omitempty"`
+	LineNumber  *string    `json:"line_number,omitempty"`
+	ScheduleAt  *time.Time `json:"schedule_at,omitempty"`
+	NumAudience *uint64    `json:"num_audience,omitempty"`
+	Budget      *uint64    `json:"budget,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+var campaignExportHeader = []string{"uuid", "status", "phase", "platform", "title", "content", "adlink", "line_number", "schedule_at", "num_audience", "budget", "created_at"}
+
+func (r campaignExportRow) csvRecord() []string {
+	return []string{
+		r.UUID, r.Status, r.Phase, r.Platform,
+		exportString(r.Title), exportString(r.Content), exportString(r.AdLink), exportString(r.LineNumber),
+		exportTime(r.ScheduleAt), exportUint(r.NumAudience), exportUint(r.Budget), r.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+func toCampaignExportRows(campaigns []*models.Campaign) []campaignExportRow {
+	rows := make([]campaignExportRow, 0, len(campaigns))
+	for _, c := range campaigns {
+		rows = append(rows, campaignExportRow{
+			UUID:        c.UUID.String(),
+			Status:      string(c.Status),
+			Phase:       string(c.Phase),
+			Platform:    c.Spec.Platform,
+			Title:       c.Spec.Title,
+			Content:     c.Spec.Content,
+			AdLink:      c.Spec.AdLink,
+			LineNumber:  c.Spec.LineNumber,
+			ScheduleAt:  c.Spec.ScheduleAt,
+			NumAudience: c.NumAudience,
+			Budget:      c.Spec.Budget,
+			CreatedAt:   c.CreatedAt,
+		})
+	}
+	return rows
+}
+
+// transactionExportRow is a wallet transaction as it appears in a data export
+type transactionExportRow struct {
+	UUID              string    `json:"uuid"`
+	Type              string    `json:"type"`
+	Status            string    `json:"status"`
+	Amount            uint64    `json:"amount"`
+	Currency          string    `json:"currency"`
+	Description       string    `json:"description"`
+	ExternalReference string    `json:"external_reference,omitempty"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+var transactionExportHeader = []string{"uuid", "type", "status", "amount", "currency", "description", "external_reference", "created_at"}
+
+func (r transactionExportRow) csvRecord() []string {
+	return []string{
+		r.UUID, r.Type, r.Status, strconv.FormatUint(r.Amount, 10), r.Currency,
+		r.Description, r.ExternalReference, r.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+func toTransactionExportRows(transactions []*models.Transaction) []transactionExportRow {
+	rows := make([]transactionExportRow, 0, len(transactions))
+	for _, t := range transactions {
+		rows = append(rows, transactionExportRow{
+			UUID:              t.UUID.String(),
+			Type:              string(t.Type),
+			Status:            string(t.Status),
+			Amount:            t.Amount,
+			Currency:          t.Currency,
+			Description:       t.Description,
+			ExternalReference: t.ExternalReference,
+			CreatedAt:         t.CreatedAt,
+		})
+	}
+	return rows
+}
+
+// buildCustomerDataExport zips profile, campaigns and transactions files in
+// the given format. The CSV profile has one field,value row per attribute.
+func buildCustomerDataExport(format models.CustomerDataExportFormat, profile dto.ProfileDTO, campaigns []campaignExportRow, transactions []transactionExportRow) ([]byte, error) {
+	files := make(map[string][]byte, 3)
+	switch format {
+	case models.CustomerDataExportFormatJSON:
+		for name, v := range map[string]any{"profile.json": profile, "campaigns.json": campaigns, "transactions.json": transactions} {
+			data, err := json.MarshalIndent(v, "", "  ")
+			if err != nil {
+				return nil, err
+			}
+			files[name] = data
+		}
+	case models.CustomerDataExportFormatCSV:
+		profileRecords, err := profileCSVRecords(profile)
+		if err != nil {
+			return nil, err
+		}
+		campaignRecords := make([][]string, 0, len(campaigns))
+		for _, row := range campaigns {
+			campaignRecords = append(campaignRecords, row.csvRecord())
+		}
+		transactionRecords := make([][]string, 0, len(transactions))
+		for _, row := range transactions {
+			transactionRecords = append(transactionRecords, row.csvRecord())
+		}
+		if files["profile.csv"], err = encodeCSV([]string{"field", "value"}, profileRecords); err != nil {
+			return nil, err
+		}
+		if files["campaigns.csv"], err = encodeCSV(campaignExportHeader, campaignRecords); err != nil {
+			return nil, err
+		}
+		if files["transactions.csv"], err = encodeCSV(transactionExportHeader, transactionRecords); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, ErrDataExportFormatInvalid
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, name := range names {
+		w, err := zw.Create(name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write(files[name]); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// profileCSVRecords flattens the profile into field,value rows ordered by field
+func profileCSVRecords(profile dto.ProfileDTO) ([][]string, error) {
+	data, err := json.Marshal(profile)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	records := make([][]string, 0, len(keys))
+	for _, k := range keys {
+		value := ""
+		switch v := fields[k].(type) {
+		case nil:
+		case string:
+			value = v
+		default:
+			value = fmt.Sprint(v)
+		}
+		records = append(records, []string{k, value})
+	}
+	return records, nil
+}
+
+func encodeCSV(header []string, records [][]string) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write(header); err != nil {
+		return nil, err
+	}
+	for _, record := range records {
+		escaped := make([]string, len(record))
+		for i, cell := range record {
+			escaped[i] = escapeCSVFormula(cell)
+		}
+		if err := w.Write(escaped); err != nil {
+			return nil, err
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// escapeCSVFormula prefixes cells that spreadsheets would evaluate as a
+// formula with a quote, so customer-written text is shown as text
+func escapeCSVFormula(cell string) string {
+	if cell != "" && strings.ContainsRune("=+-@", rune(cell[0])) {
+		return "'" + cell
+	}
+	return cell
+}
+
+func exportString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func exportTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+func exportUint(n *uint64) string {
+	if n == nil {
+		return ""
+	}
+	return strconv.FormatUint(*n, 10)
+}
@@ -0,0 +1,256 @@
+package businessflow
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/repository"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/google/uuid"
+)
+
+func readExportBundle(t *testing.T, data []byte) map[string][]byte {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("invalid zip: %v", err)
+	}
+	files := make(map[string][]byte)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("open %s: %v", f.Name, err)
+		}
+		content, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatalf("read %s: %v", f.Name, err)
+		}
+		files[f.Name] = content
+	}
+	return files
+}
+
+func testExportRecords() (dto.ProfileDTO, []campaignExportRow, []transactionExportRow) {
+	created := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	profile := dto.ProfileDTO{
+		ID:                   7,
+		Email:                "owner@example.com",
+		RepresentativeMobile: "+989121234567",
+		CompanyName:          utils.ToPtr("Acme, Ltd"),
+		CreatedAt:            created,
+	}
+	campaigns := []campaignExportRow{{
+		UUID:        "c1",
+		Status:      "executed",
+		Phase:       "execution",
+		Platform:    "sms",
+		Title:       utils.ToPtr("Spring sale"),
+		Content:     utils.ToPtr("line one\nline two"),
+		NumAudience: utils.ToPtr(uint64(1200)),
+		CreatedAt:   created,
+	}}
+	transactions := []transactionExportRow{{
+		UUID:      "t1",
+		Type:      "deposit",
+		Status:    "completed",
+		Amount:    500000,
+		Currency:  "TMN",
+		CreatedAt: created,
+	}}
+	return profile, campaigns, transactions
+}
+
+func TestBuildCustomerDataExportJSON(t *testing.T) {
+	t.Parallel()
+
+	profile, campaigns, transactions := testExportRecords()
+	data, err := buildCustomerDataExport(models.CustomerDataExportFormatJSON, profile, campaigns, transactions)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	files := readExportBundle(t, data)
+	if len(files) != 3 {
+		t.Fatalf("expected 3 files, got %d", len(files))
+	}
+
+	var gotProfile dto.ProfileDTO
+	if err := json.Unmarshal(files["profile.json"], &gotProfile); err != nil || gotProfile.Email != "owner@example.com" {
+		t.Fatalf("unexpected profile %s (%v)", files["profile.json"], err)
+	}
+	var gotCampaigns []campaignExportRow
+	if err := json.Unmarshal(files["campaigns.json"], &gotCampaigns); err != nil || len(gotCampaigns) != 1 || *gotCampaigns[0].Title != "Spring sale" {
+		t.Fatalf("unexpected campaigns %s (%v)", files["campaigns.json"], err)
+	}
+	var gotTransactions []transactionExportRow
+	if err := json.Unmarshal(files["transactions.json"], &gotTransactions); err != nil || len(gotTransactions) != 1 || gotTransactions[0].Amount != 500000 {
+		t.Fatalf("unexpected transactions %s (%v)", files["transactions.json"], err)
+	}
+}
+
+func TestBuildCustomerDataExportCSV(t *testing.T) {
+	t.Parallel()
+
+	profile, campaigns, transactions := testExportRecords()
+	data, err := buildCustomerDataExport(models.CustomerDataExportFormatCSV, profile, campaigns, transactions)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	files := readExportBundle(t, data)
+
+	readCSV := func(name string) [][]string {
+		records, err := csv.NewReader(bytes.NewReader(files[name])).ReadAll()
+		if err != nil {
+			t.Fatalf("%s: invalid CSV: %v", name, err)
+		}
+		return records
+	}
+
+	profileFields := make(map[string]string)
+	for _, rec := range readCSV("profile.csv")[1:] {
+		profileFields[rec[0]] = rec[1]
+	}
+	if profileFields["company_name"] != "Acme, Ltd" || profileFields["id"] != "7" || profileFields["email"] != "owner@example.com" {
+		t.Fatalf("unexpected profile fields %v", profileFields)
+	}
+
+	campaignRecords := readCSV("campaigns.csv")
+	if len(campaignRecords) != 2 || len(campaignRecords[0]) != len(campaignExportHeader) {
+		t.Fatalf("unexpected campaign records %v", campaignRecords)
+	}
+	if campaignRecords[1][5] != "line one\nline two" || campaignRecords[1][9] != "1200" || campaignRecords[1][8] != "" {
+		t.Fatalf("unexpected campaign row %v", campaignRecords[1])
+	}
+
+	transactionRecords := readCSV("transactions.csv")
+	if len(transactionRecords) != 2 || transactionRecords[1][3] != "500000" {
+		t.Fatalf("unexpected transaction records %v", transactionRecords)
+	}
+}
+
+func TestBuildCustomerDataExportCSVEscapesFormulas(t *testing.T) {
+	t.Parallel()
+
+	profile, campaigns, transactions := testExportRecords()
+	campaigns[0].Title = utils.ToPtr("=HYPERLINK(\"http://evil\")")
+	campaigns[0].Content = utils.ToPtr("@SUM(A1)")
+	data, err := buildCustomerDataExport(models.CustomerDataExportFormatCSV, profile, campaigns, transactions)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	records, err := csv.NewReader(bytes.NewReader(readExportBundle(t, data)["campaigns.csv"])).ReadAll()
+	if err != nil {
+		t.Fatalf("invalid CSV: %v", err)
+	}
+	if records[1][4] != "'=HYPERLINK(\"http://evil\")" || records[1][5] != "'@SUM(A1)" {
+		t.Fatalf("formula cells not escaped: %v", records[1])
+	}
+	if records[0][0] != "uuid" || records[1][0] != "c1" {
+		t.Fatalf("plain cells changed: %v", records)
+	}
+
+	for cell, want := range map[string]string{"+1": "'+1", "-1": "'-1", "a=b": "a=b", "": ""} {
+		if got := escapeCSVFormula(cell); got != want {
+			t.Fatalf("escapeCSVFormula(%q) = %q, want %q", cell, got, want)
+		}
+	}
+}
+
+type deletionWalletRepoStub struct {
+	repository.WalletRepository
+	balance models.BalanceSnapshot
+}
+
+func (r *deletionWalletRepoStub) ByCustomerID(ctx context.Context, customerID uint) (*models.Wallet, error) {
+	return &models.Wallet{ID: 10, CustomerID: customerID}, nil
+}
+
+func (r *deletionWalletRepoStub) GetCurrentBalance(ctx context.Context, walletID uint) (*models.BalanceSnapshot, error) {
+	return &r.balance, nil
+}
+
+type deletionCampaignRepoStub struct {
+	repository.CampaignRepository
+	statuses []models.CampaignStatus
+}
+
+func (r *deletionCampaignRepoStub) ByCustomerID(ctx context.Context, customerID uint, limit, offset int) ([]*models.Campaign, error) {
+	campaigns := make([]*models.Campaign, 0, len(r.statuses))
+	for _, status := range r.statuses {
+		campaigns = append(campaigns, &models.Campaign{CustomerID: customerID, Status: status})
+	}
+	return campaigns, nil
+}
+
+func TestCheckDeletableRefusesBalanceAndActiveCampaigns(t *testing.T) {
+	t.Parallel()
+
+	finished := []models.CampaignStatus{models.CampaignStatusExecuted, models.CampaignStatusCancelled}
+	tests := []struct {
+		name      string
+		balance   models.BalanceSnapshot
+		statuses  []models.CampaignStatus
+		wantCheck func(error) bool
+	}{
+		{"empty wallet and finished campaigns", models.BalanceSnapshot{SpentOnCampaign: 500000}, finished, func(err error) bool { return err == nil }},
+		{"free balance", models.BalanceSnapshot{FreeBalance: 1}, nil, IsAccountDeletionBalance},
+		{"frozen balance", models.BalanceSnapshot{FrozenBalance: 1}, nil, IsAccountDeletionBalance},
+		{"locked balance", models.BalanceSnapshot{LockedBalance: 1}, nil, IsAccountDeletionBalance},
+		{"running campaign", models.BalanceSnapshot{}, append(finished, models.CampaignStatusRunning), IsAccountDeletionCampaigns},
+		{"campaign waiting for approval", models.BalanceSnapshot{}, []models.CampaignStatus{models.CampaignStatusWaitingForApproval}, IsAccountDeletionCampaigns},
+	}
+	for _, tt := range tests {
+		f := &CustomerDataFlowImpl{
+			walletRepo:   &deletionWalletRepoStub{balance: tt.balance},
+			campaignRepo: &deletionCampaignRepoStub{statuses: tt.statuses},
+		}
+		if err := f.checkDeletable(context.Background(), 1); !tt.wantCheck(err) {
+			t.Fatalf("%s: unexpected error %v", tt.name, err)
+		}
+	}
+}
+
+func TestBuildCustomerDataExportRejectsUnknownFormat(t *testing.T) {
+	t.Parallel()
+
+	profile, campaigns, transactions := testExportRecords()
+	if _, err := buildCustomerDataExport("xml", profile, campaigns, transactions); !errors.Is(err, ErrDataExportFormatInvalid) {
+		t.Fatalf("expected ErrDataExportFormatInvalid, got %v", err)
+	}
+}
+
+func TestToCustomerDataRequestItemDownloadURL(t *testing.T) {
+	t.Parallel()
+
+	id := uuid.New()
+	path := "data/customer_exports/x.zip"
+	request := &models.CustomerDataRequest{
+		UUID:     id,
+		Type:     models.CustomerDataRequestTypeExport,
+		Status:   models.CustomerDataRequestStatusProcessing,
+		FilePath: &path,
+	}
+	if item := toCustomerDataRequestItem(request); item.DownloadURL != nil {
+		t.Fatalf("expected no download URL while processing, got %q", *item.DownloadURL)
+	}
+
+	request.Status = models.CustomerDataRequestStatusCompleted
+	item := toCustomerDataRequestItem(request)
+	if item.DownloadURL == nil || *item.DownloadURL != "/api/v1/account/data-exports/"+id.String()+"/download" {
+		t.Fatalf("unexpected download URL %v", item.DownloadURL)
+	}
+
+	request.FilePath = nil
+	if item := toCustomerDataRequestItem(request); item.DownloadURL != nil {
+		t.Fatalf("expected no download URL once the file is purged, got %q", *item.DownloadURL)
+	}
+}
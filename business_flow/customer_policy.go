@@ -0,0 +1,71 @@
+package businessflow
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/models"
+)
+
+// CustomerAction is something a customer does that a suspension can block
+type CustomerAction string
+
+const (
+	CustomerActionCreateCampaign CustomerAction = "create_campaign"
+	CustomerActionRechargeWallet CustomerAction = "recharge_wallet"
+)
+
+// CustomerSuspendedError reports that the customer's suspension blocks the
+// action; Reason is the code shown to the customer
+type CustomerSuspendedError struct {
+	Action CustomerAction
+	Level  models.CustomerSuspensionLevel
+	Reason models.CustomerSuspensionReason
+}
+
+func (e *CustomerSuspendedError) Error() string {
+	return fmt.Sprintf("customer is %s (%s) and cannot %s", e.Level, e.Reason, e.Action)
+}
+
+func (e *CustomerSuspendedError) Unwrap() error {
+	return ErrCustomerSuspended
+}
+
+// CustomerSuspension returns the suspension that blocked the request when err
+// is a suspension error
+func CustomerSuspension(err error) (*CustomerSuspendedError, bool) {
+	var suspended *CustomerSuspendedError
+	if errors.As(err, &suspended) {
+		return suspended, true
+	}
+	return nil, false
+}
+
+// checkCustomerPolicy is the single place suspensions are enforced. A warning
+// blocks nothing, restricted blocks campaigns and suspended also blocks
+// wallet recharges. Logging in and reading data are never blocked here.
+func checkCustomerPolicy(customer models.Customer, action CustomerAction) error {
+	level, reason := customer.Suspension()
+	var blocked bool
+	switch level {
+	case models.CustomerSuspensionRestricted:
+		blocked = action == CustomerActionCreateCampaign
+	case models.CustomerSuspensionSuspended:
+		blocked = action == CustomerActionCreateCampaign || action == CustomerActionRechargeWallet
+	}
+	if !blocked {
+		return nil
+	}
+	return &CustomerSuspendedError{Action: action, Level: level, Reason: reason}
+}
+
+// customerSuspensionDTO returns the customer's suspension as shown to them,
+// or nil when they are not suspended
+func customerSuspensionDTO(c *models.Customer) *dto.CustomerSuspensionDTO {
+	level, reason := c.Suspension()
+	if level == "" {
+		return nil
+	}
+	return &dto.CustomerSuspensionDTO{Level: string(level), Reason: string(reason), SuspendedAt: c.SuspendedAt}
+}
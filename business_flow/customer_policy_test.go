@@ -0,0 +1,49 @@
+package businessflow
+
+import (
+	"testing"
+
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+)
+
+func TestCheckCustomerPolicy(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		level        models.CustomerSuspensionLevel
+		campaignsOK  bool
+		rechargingOK bool
+	}{
+		{level: "", campaignsOK: true, rechargingOK: true},
+		{level: models.CustomerSuspensionWarning, campaignsOK: true, rechargingOK: true},
+		{level: models.CustomerSuspensionRestricted, campaignsOK: false, rechargingOK: true},
+		{level: models.CustomerSuspensionSuspended, campaignsOK: false, rechargingOK: false},
+	}
+	for _, tt := range tests {
+		customer := models.Customer{}
+		if tt.level != "" {
+			customer.SuspensionLevel = utils.ToPtr(tt.level)
+			customer.SuspensionReason = utils.ToPtr(models.CustomerSuspensionReasonFraudReview)
+		}
+		for action, ok := range map[CustomerAction]bool{
+			CustomerActionCreateCampaign: tt.campaignsOK,
+			CustomerActionRechargeWallet: tt.rechargingOK,
+		} {
+			err := checkCustomerPolicy(customer, action)
+			if ok {
+				if err != nil {
+					t.Fatalf("level %q, %s: unexpected error %v", tt.level, action, err)
+				}
+				continue
+			}
+			if !IsCustomerSuspended(err) {
+				t.Fatalf("level %q, %s: got %v, want ErrCustomerSuspended", tt.level, action, err)
+			}
+			suspended, found := CustomerSuspension(NewBusinessError("CHARGE_WALLET_FAILED", "Failed to charge wallet", err))
+			if !found || suspended.Level != tt.level || suspended.Reason != models.CustomerSuspensionReasonFraudReview {
+				t.Fatalf("level %q, %s: suspension = %+v", tt.level, action, suspended)
+			}
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package businessflow
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/repository"
+)
+
+const (
+	customerTimelineDefaultLimit = 20
+	customerTimelineMaxLimit     = 100
+)
+
+// customerTimelineCategories are the categories a timeline can be filtered by
+var customerTimelineCategories = []string{
+	repository.CustomerActivityAudit,
+	repository.CustomerActivitySession,
+	repository.CustomerActivityPayment,
+	repository.CustomerActivityCampaign,
+}
+
+// CustomerTimelineFlow lists what a customer did and what happened to their
+// account: audit logs, sessions, payments and campaigns, newest first
+type CustomerTimelineFlow interface {
+	GetTimeline(ctx context.Context, req *dto.AdminCustomerTimelineRequest) (*dto.AdminCustomerTimelineResponse, error)
+}
+
+type CustomerTimelineFlowImpl struct {
+	customerRepo repository.CustomerRepository
+	activityRepo repository.CustomerActivityRepository
+}
+
+func NewCustomerTimelineFlow(customerRepo repository.CustomerRepository, activityRepo repository.CustomerActivityRepository) CustomerTimelineFlow {
+	return &CustomerTimelineFlowImpl{customerRepo: customerRepo, activityRepo: activityRepo}
+}
+
+// GetTimeline returns a page of a customer's activities. Pages are continued
+// with the next_cursor of the previous page and the same categories.
+func (f *CustomerTimelineFlowImpl) GetTimeline(ctx context.Context, req *dto.AdminCustomerTimelineRequest) (*dto.AdminCustomerTimelineResponse, error) {
+	categories, err := normalizeTimelineCategories(req.Categories)
+	if err != nil {
+		return nil, NewBusinessError("TIMELINE_CATEGORY_INVALID", "Category must be one of audit, session, payment and campaign", err)
+	}
+	var after *repository.CustomerActivityCursor
+	if req.Cursor != "" {
+		cursor, err := decodeCustomerActivityCursor(req.Cursor)
+		if err != nil {
+			return nil, NewBusinessError("TIMELINE_CURSOR_INVALID", "Invalid timeline cursor", err)
+		}
+		after = &cursor
+	}
+	limit := req.Limit
+	if limit <= 0 {
+		limit = customerTimelineDefaultLimit
+	}
+	limit = min(limit, customerTimelineMaxLimit)
+
+	customer, err := f.customerRepo.ByID(ctx, req.CustomerID)
+	if err != nil {
+		return nil, NewBusinessError("ADMIN_CUSTOMER_TIMELINE_FAILED", "Failed to fetch customer", err)
+	}
+	if customer == nil {
+		return nil, NewBusinessError("CUSTOMER_NOT_FOUND", "Customer not found", ErrCustomerNotFound)
+	}
+
+	// One row more than the page tells whether another page follows
+	activities, err := f.activityRepo.ListActivities(ctx, repository.CustomerActivityQuery{
+		CustomerID: customer.ID,
+		Categories: categories,
+		After:      after,
+		Limit:      limit + 1,
+	})
+	if err != nil {
+		return nil, NewBusinessError("ADMIN_CUSTOMER_TIMELINE_FAILED", "Failed to list customer activities", err)
+	}
+
+	res := &dto.AdminCustomerTimelineResponse{
+		Message:    "Customer timeline retrieved successfully",
+		CustomerID: customer.ID,
+		Events:     make([]dto.CustomerTimelineEvent, 0, min(len(activities), limit)),
+	}
+	if len(activities) > limit {
+		activities = activities[:limit]
+		last := activities[limit-1]
+		res.HasMore = true
+		res.NextCursor = encodeCustomerActivityCursor(repository.CustomerActivityCursor{
+			OccurredAt: last.OccurredAt,
+			Category:   last.Category,
+			SourceID:   last.SourceID,
+		})
+	}
+	for _, a := range activities {
+		res.Events = append(res.Events, customerTimelineEvent(a))
+	}
+	return res, nil
+}
+
+func customerTimelineEvent(a *repository.CustomerActivity) dto.CustomerTimelineEvent {
+	event := dto.CustomerTimelineEvent{
+		Category:   a.Category,
+		Type:       a.Type,
+		SourceID:   a.SourceID,
+		OccurredAt: a.OccurredAt.UTC().Format(time.RFC3339),
+		Summary:    a.Summary,
+		Success:    a.Success,
+	}
+	if len(a.Details) > 0 {
+		var details map[string]any
+		if json.Unmarshal(a.Details, &details) == nil {
+			// Drop the keys whose column is NULL
+			for k, v := range details {
+				if v == nil {
+					delete(details, k)
+				}
+			}
+			event.Details = details
+		}
+	}
+	return event
+}
+
+// normalizeTimelineCategories lowercases, validates and deduplicates the
+// requested categories. Comma separated values are split.
+func normalizeTimelineCategories(raw []string) ([]string, error) {
+	var categories []string
+	for _, value := range raw {
+		for c := range strings.SplitSeq(value, ",") {
+			c = strings.ToLower(strings.TrimSpace(c))
+			if c == "" {
+				continue
+			}
+			if !slices.Contains(customerTimelineCategories, c) {
+				return nil, ErrTimelineCategoryInvalid
+			}
+			if !slices.Contains(categories, c) {
+				categories = append(categories, c)
+			}
+		}
+	}
+	return categories, nil
+}
+
+// encodeCustomerActivityCursor makes an opaque page token of the last
+// activity of a page
+func encodeCustomerActivityCursor(c repository.CustomerActivityCursor) string {
+	raw := c.OccurredAt.UTC().Format(time.RFC3339Nano) + "|" + c.Category + "|" + strconv.FormatUint(uint64(c.SourceID), 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCustomerActivityCursor(token string) (repository.CustomerActivityCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return repository.CustomerActivityCursor{}, ErrTimelineCursorInvalid
+	}
+	parts := strings.Split(string(raw), "|")
+	if len(parts) != 3 || !slices.Contains(customerTimelineCategories, parts[1]) {
+		return repository.CustomerActivityCursor{}, ErrTimelineCursorInvalid
+	}
+	at, err := time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return repository.CustomerActivityCursor{}, ErrTimelineCursorInvalid
+	}
+	id, err := strconv.ParseUint(parts[2], 10, 64)
+	if err != nil || id == 0 {
+		return repository.CustomerActivityCursor{}, ErrTimelineCursorInvalid
+	}
+	return repository.CustomerActivityCursor{OccurredAt: at, Category: parts[1], SourceID: uint(id)}, nil
+}
@@ -0,0 +1,55 @@
+package businessflow
+
+import (
+	"encoding/base64"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/repository"
+)
+
+func TestCustomerActivityCursorRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	want := repository.CustomerActivityCursor{
+		OccurredAt: time.Date(2026, 10, 17, 9, 30, 0, 123456000, time.UTC),
+		Category:   repository.CustomerActivityPayment,
+		SourceID:   42,
+	}
+	got, err := decodeCustomerActivityCursor(encodeCustomerActivityCursor(want))
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if !got.OccurredAt.Equal(want.OccurredAt) || got.Category != want.Category || got.SourceID != want.SourceID {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+
+	for _, token := range []string{
+		"not base64!",
+		base64.RawURLEncoding.EncodeToString([]byte("2026-10-17T09:30:00Z|payment")),
+		base64.RawURLEncoding.EncodeToString([]byte("2026-10-17T09:30:00Z|wallet|1")),
+		base64.RawURLEncoding.EncodeToString([]byte("yesterday|payment|1")),
+		base64.RawURLEncoding.EncodeToString([]byte("2026-10-17T09:30:00Z|payment|0")),
+	} {
+		if _, err := decodeCustomerActivityCursor(token); !IsTimelineCursorInvalid(err) {
+			t.Errorf("decode(%q): got %v, want ErrTimelineCursorInvalid", token, err)
+		}
+	}
+}
+
+func TestNormalizeTimelineCategories(t *testing.T) {
+	t.Parallel()
+
+	got, err := normalizeTimelineCategories([]string{"Payment, audit", "payment", ""})
+	if err != nil {
+		t.Fatalf("normalize: %v", err)
+	}
+	if want := []string{"payment", "audit"}; !slices.Equal(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+
+	if _, err := normalizeTimelineCategories([]string{"audit,wallet"}); !IsTimelineCategoryInvalid(err) {
+		t.Fatalf("got %v, want ErrTimelineCategoryInvalid", err)
+	}
+}
@@ -0,0 +1,104 @@
+package businessflow
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/i18n"
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+)
+
+type smsJobQueueStub struct {
+	jobs []models.SendSMSJob
+}
+
+func (q *smsJobQueueStub) Enqueue(ctx context.Context, jobType string, payload any) error {
+	q.jobs = append(q.jobs, payload.(models.SendSMSJob))
+	return nil
+}
+
+func (q *smsJobQueueStub) Schedule(ctx context.Context, jobType string, payload any, runAt time.Time) error {
+	return q.Enqueue(ctx, jobType, payload)
+}
+
+func TestParseDepositTransferDate(t *testing.T) {
+	t.Parallel()
+	// 22:00 UTC is already the next day in Tehran
+	now := time.Date(2026, 4, 9, 22, 0, 0, 0, time.UTC)
+
+	if day, err := parseDepositTransferDate(" 2026-04-10 ", now); err != nil || !day.Equal(time.Date(2026, 4, 10, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("today in Tehran = %v, %v", day, err)
+	}
+	for _, value := range []string{"2026-04-11", "10/04/2026", ""} {
+		if _, err := parseDepositTransferDate(value, now); !IsDepositReceiptTransferDateInvalid(err) {
+			t.Errorf("%q returned %v", value, err)
+		}
+	}
+}
+
+func TestTrackingNumberReuse(t *testing.T) {
+	t.Parallel()
+	if got := normalizeTrackingNumber(" 1234-5678 90 "); got != "1234567890" {
+		t.Fatalf("normalized tracking number = %q", got)
+	}
+
+	repo := &chargeReceiptRepoStub{receipts: []*models.DepositReceipt{{Status: models.DepositReceiptStatusRejected}}}
+	p := &PaymentFlowImpl{depositReceiptRepo: repo}
+	if err := p.ensureTrackingNumberUnused(context.Background(), "1234567890"); err != nil {
+		t.Fatalf("rejected receipt blocked its tracking number: %v", err)
+	}
+	if repo.filter.TrackingNumber == nil || *repo.filter.TrackingNumber != "1234567890" || !repo.filter.OmitFileData {
+		t.Fatalf("receipts filtered by %+v", repo.filter)
+	}
+
+	repo.receipts = append(repo.receipts, &models.DepositReceipt{Status: models.DepositReceiptStatusPending})
+	if err := p.ensureTrackingNumberUnused(context.Background(), "1234567890"); !IsDepositReceiptTrackingNumberUsed(err) {
+		t.Fatalf("pending receipt returned %v", err)
+	}
+}
+
+func TestDepositReceiptCreditAmount(t *testing.T) {
+	t.Parallel()
+	receipt := &models.DepositReceipt{Amount: 500000}
+
+	if got, err := depositReceiptCreditAmount(receipt, 0); err != nil || got != 500000 {
+		t.Errorf("without matched amount = %d, %v", got, err)
+	}
+	for _, matched := range []uint64{450000, 500000} {
+		if got, err := depositReceiptCreditAmount(receipt, matched); err != nil || got != matched {
+			t.Errorf("matched %d = %d, %v", matched, got, err)
+		}
+	}
+	if _, err := depositReceiptCreditAmount(receipt, 500001); !IsDepositReceiptMatchedAmountTooHigh(err) {
+		t.Errorf("matched above the claim returned %v", err)
+	}
+}
+
+func TestNotifyDepositReceiptReviewed(t *testing.T) {
+	t.Parallel()
+	jobs := &smsJobQueueStub{}
+	p := &PaymentFlowImpl{jobs: jobs, localizer: i18n.NewLocalizer("en", "en")}
+	customer := &models.Customer{RepresentativeMobile: "+989121234567"}
+
+	p.notifyDepositReceiptReviewed(context.Background(), customer, &models.DepositReceipt{
+		Amount:        500000,
+		Status:        models.DepositReceiptStatusApproved,
+		MatchedAmount: utils.ToPtr(uint64(450000)),
+	}, "")
+	p.notifyDepositReceiptReviewed(context.Background(), customer, &models.DepositReceipt{
+		Amount: 500000,
+		Status: models.DepositReceiptStatusRejected,
+	}, "Amount not on the statement")
+
+	if len(jobs.jobs) != 2 {
+		t.Fatalf("queued %d SMS, want 2", len(jobs.jobs))
+	}
+	if want := i18n.Message(i18n.LocaleEnglish, "deposit_receipt.approved", i18n.Args{"Amount": 450000}); jobs.jobs[0].Message != want {
+		t.Errorf("approval SMS = %q, want %q", jobs.jobs[0].Message, want)
+	}
+	if want := i18n.Message(i18n.LocaleEnglish, "deposit_receipt.rejected", i18n.Args{"Amount": 500000, "Reason": "Amount not on the statement"}); jobs.jobs[1].Message != want {
+		t.Errorf("rejection SMS = %q, want %q", jobs.jobs[1].Message, want)
+	}
+}
@@ -0,0 +1,143 @@
+package businessflow
+
+import (
+	"encoding/json"
+	"net"
+	"strings"
+
+	"github.com/amirphl/Yamata-no-Orochi/models"
+)
+
+// Device types reported in models.DeviceInfo
+const (
+	DeviceTypeDesktop = "desktop"
+	DeviceTypeMobile  = "mobile"
+	DeviceTypeTablet  = "tablet"
+)
+
+// userAgentBrowsers is checked in order: Edge and Opera also announce Chrome,
+// and Chrome also announces Safari
+var userAgentBrowsers = []struct {
+	name  string
+	token string
+}{
+	{"Edge", "Edg/"},
+	{"Opera", "OPR/"},
+	{"Samsung Internet", "SamsungBrowser/"},
+	{"Firefox", "Firefox/"},
+	{"Firefox", "FxiOS/"},
+	{"Chrome", "CriOS/"},
+	{"Chrome", "Chrome/"},
+	{"Safari", "Version/"},
+}
+
+// parseUserAgent estimates the browser, OS, and device type of a client from
+// its User-Agent header. Unrecognized parts are left empty.
+func parseUserAgent(userAgent string) models.DeviceInfo {
+	var info models.DeviceInfo
+	if userAgent == "" {
+		return info
+	}
+
+	for _, b := range userAgentBrowsers {
+		if version, ok := userAgentVersion(userAgent, b.token); ok {
+			if b.name == "Safari" && !strings.Contains(userAgent, "Safari/") {
+				continue
+			}
+			info.Browser = b.name
+			info.Version = version
+			break
+		}
+	}
+
+	switch {
+	case strings.Contains(userAgent, "iPad"):
+		info.OS = "iPadOS"
+		info.DeviceType = DeviceTypeTablet
+	case strings.Contains(userAgent, "iPhone"), strings.Contains(userAgent, "iPod"):
+		info.OS = "iOS"
+		info.DeviceType = DeviceTypeMobile
+	case strings.Contains(userAgent, "Android"):
+		info.OS = "Android"
+		info.DeviceType = DeviceTypeTablet
+		if strings.Contains(userAgent, "Mobile") {
+			info.DeviceType = DeviceTypeMobile
+		}
+	case strings.Contains(userAgent, "Windows"):
+		info.OS = "Windows"
+		info.DeviceType = DeviceTypeDesktop
+	case strings.Contains(userAgent, "Mac OS X"):
+		info.OS = "macOS"
+		info.DeviceType = DeviceTypeDesktop
+	case strings.Contains(userAgent, "CrOS"):
+		info.OS = "ChromeOS"
+		info.DeviceType = DeviceTypeDesktop
+	case strings.Contains(userAgent, "Linux"):
+		info.OS = "Linux"
+		info.DeviceType = DeviceTypeDesktop
+	}
+	info.IsMobile = info.DeviceType == DeviceTypeMobile
+	if info.Browser != "" {
+		info.Platform = "web"
+	}
+	return info
+}
+
+// userAgentVersion returns the major version following token, e.g. "120" for
+// "Chrome/120.0.6099.109"
+func userAgentVersion(userAgent, token string) (string, bool) {
+	i := strings.Index(userAgent, token)
+	if i < 0 {
+		return "", false
+	}
+	version := userAgent[i+len(token):]
+	if end := strings.IndexAny(version, ". ;)"); end >= 0 {
+		version = version[:end]
+	}
+	return version, true
+}
+
+// ipRange returns the network an IP address most likely shares with other
+// addresses of the same client: its /24 for IPv4 and its /48 for IPv6
+func ipRange(ipAddress string) string {
+	// inet columns may carry a prefix length
+	ipAddress, _, _ = strings.Cut(strings.TrimSpace(ipAddress), "/")
+	ip := net.ParseIP(ipAddress)
+	if ip == nil {
+		return ""
+	}
+	if v4 := ip.To4(); v4 != nil {
+		return (&net.IPNet{IP: v4.Mask(net.CIDRMask(24, 32)), Mask: net.CIDRMask(24, 32)}).String()
+	}
+	return (&net.IPNet{IP: ip.Mask(net.CIDRMask(48, 128)), Mask: net.CIDRMask(48, 128)}).String()
+}
+
+// sessionDeviceInfo describes the client of a new session for the
+// customer_sessions device_info column
+func sessionDeviceInfo(metadata *ClientMetadata) json.RawMessage {
+	if metadata == nil {
+		return nil
+	}
+	info := parseUserAgent(metadata.UserAgent)
+	if metadata.Location != nil {
+		info.Country = metadata.Location.Country
+	}
+	raw, err := json.Marshal(info)
+	if err != nil {
+		return nil
+	}
+	return raw
+}
+
+// decodeSessionDeviceInfo reads the device_info column of a session; sessions
+// created before it was recorded are described from their user agent
+func decodeSessionDeviceInfo(session *models.CustomerSession) models.DeviceInfo {
+	var info models.DeviceInfo
+	if len(session.DeviceInfo) > 0 && json.Unmarshal(session.DeviceInfo, &info) == nil {
+		return info
+	}
+	if session.UserAgent != nil {
+		return parseUserAgent(*session.UserAgent)
+	}
+	return info
+}
@@ -0,0 +1,83 @@
+package businessflow
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/amirphl/Yamata-no-Orochi/models"
+)
+
+func TestParseUserAgent(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		userAgent string
+		want      models.DeviceInfo
+	}{
+		{
+			"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.6099.109 Safari/537.36",
+			models.DeviceInfo{Platform: "web", Browser: "Chrome", Version: "120", OS: "Windows", DeviceType: DeviceTypeDesktop},
+		},
+		{
+			"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36 Edg/120.0.2210.91",
+			models.DeviceInfo{Platform: "web", Browser: "Edge", Version: "120", OS: "Windows", DeviceType: DeviceTypeDesktop},
+		},
+		{
+			"Mozilla/5.0 (Linux; Android 13; SM-A536E) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/119.0.0.0 Mobile Safari/537.36",
+			models.DeviceInfo{Platform: "web", Browser: "Chrome", Version: "119", OS: "Android", DeviceType: DeviceTypeMobile, IsMobile: true},
+		},
+		{
+			"Mozilla/5.0 (iPhone; CPU iPhone OS 17_1 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.1 Mobile/15E148 Safari/604.1",
+			models.DeviceInfo{Platform: "web", Browser: "Safari", Version: "17", OS: "iOS", DeviceType: DeviceTypeMobile, IsMobile: true},
+		},
+		{
+			"Mozilla/5.0 (Macintosh; Intel Mac OS X 10.15; rv:121.0) Gecko/20100101 Firefox/121.0",
+			models.DeviceInfo{Platform: "web", Browser: "Firefox", Version: "121", OS: "macOS", DeviceType: DeviceTypeDesktop},
+		},
+		{
+			"okhttp/4.12.0",
+			models.DeviceInfo{},
+		},
+	}
+	for _, tc := range cases {
+		if got := parseUserAgent(tc.userAgent); got != tc.want {
+			t.Errorf("parseUserAgent(%q) = %+v, want %+v", tc.userAgent, got, tc.want)
+		}
+	}
+}
+
+func TestIPRange(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]string{
+		"5.160.12.34":        "5.160.12.0/24",
+		"5.160.12.34/32":     "5.160.12.0/24",
+		"2a01:5ec0:1:2::1":   "2a01:5ec0:1::/48",
+		"::ffff:5.160.12.34": "5.160.12.0/24",
+		"not an ip":          "",
+		"":                   "",
+	}
+	for in, want := range cases {
+		if got := ipRange(in); got != want {
+			t.Errorf("ipRange(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestSessionDeviceInfoRecordsCountry(t *testing.T) {
+	t.Parallel()
+
+	metadata := NewClientMetadata("5.160.12.34", "Mozilla/5.0 (Linux; Android 13) Chrome/119.0.0.0 Mobile Safari/537.36")
+	metadata.SetLocation(&LocationInfo{Country: "IR"})
+
+	session := &models.CustomerSession{DeviceInfo: sessionDeviceInfo(metadata)}
+	info := decodeSessionDeviceInfo(session)
+	if info.Country != "IR" || info.OS != "Android" || !info.IsMobile {
+		t.Fatalf("unexpected device info: %+v", info)
+	}
+
+	var raw map[string]any
+	if err := json.Unmarshal(session.DeviceInfo, &raw); err != nil {
+		t.Fatalf("device info is not JSON: %v", err)
+	}
+}
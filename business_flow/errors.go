@@ -4,6 +4,8 @@ package businessflow
 import (
 	"errors"
 	"fmt"
+
+	"github.com/amirphl/Yamata-no-Orochi/listquery"
 )
 
 // Business flow error constants
@@ -16,6 +18,8 @@ var (
 	ErrMobileNumberNotVerified = errors.New("mobile number not verified")
 	ErrEmailAlreadyExists      = errors.New("email already exists")
 	ErrMobileAlreadyExists     = errors.New("mobile number already exists")
+	ErrMobileUnchanged         = errors.New("new mobile number is the current one")
+	ErrInvalidMobileNumber     = errors.New("mobile number is not a valid Iranian mobile number")
 	ErrNationalIDAlreadyExists = errors.New("national ID already exists")
 	ErrNationalIDRequired      = errors.New("national ID is required")
 	ErrAgencyNotFound          = errors.New("agency not found")
@@ -44,6 +48,21 @@ var (
 
 	ErrAlreadyVerified = errors.New("already verified")
 
+	// Session-related errors
+	ErrSessionNotFound = errors.New("session not found")
+
+	// Login alert errors
+	ErrPasswordResetRequired = errors.New("password reset required")
+	ErrLoginAlertNotFound    = errors.New("login alert not found or expired")
+
+	// ErrPasswordRejected is wrapped by PasswordRejectedError when a new
+	// password breaks the password policy
+	ErrPasswordRejected = errors.New("password rejected by policy")
+
+	// ErrPasswordlessLoginDisabled is returned for an SMS code login while
+	// PASSWORDLESS_LOGIN_ENABLED is off
+	ErrPasswordlessLoginDisabled = errors.New("passwordless login is disabled")
+
 	// Campaign-related errors
 	ErrCampaignNotFound                         = errors.New("campaign not found")
 	ErrCampaignAccessDenied                     = errors.New("campaign access denied")
@@ -68,6 +87,7 @@ var (
 	ErrScheduleTimeMustBeUTC                    = errors.New("schedule time must be UTC")
 	ErrCampaignCityRequired                     = errors.New("campaign city is required")
 	ErrCampaignTagsRequired                     = errors.New("campaign tags is required")
+	ErrCampaignTagInvalid                       = errors.New("campaign tag is invalid")
 	ErrCampaignAudienceGradesInvalid            = errors.New("campaign audience grades are invalid")
 	ErrCampaignUpdateRequired                   = errors.New("at least one field must be provided for update")
 	ErrCampaignUUIDRequired                     = errors.New("campaign UUID is required")
@@ -88,23 +108,56 @@ var (
 	ErrCampaignTestRecipientMissing             = errors.New("campaign test recipient is missing")
 	ErrCampaignTestPlatformSettingsInvalid      = errors.New("campaign test platform settings are invalid")
 	ErrCampaignTestCooldownUnavailable          = errors.New("campaign test cooldown is unavailable")
+	ErrCampaignRecurrenceInvalid                = errors.New("campaign recurrence rule is invalid")
+	ErrCampaignRecurrenceTooFrequent            = errors.New("campaign occurrences must be at least one hour apart")
+	ErrCampaignRecurrenceUntilInvalid           = errors.New("campaign recurrence end must be after the schedule time")
+	ErrCampaignVariantsInvalid                  = errors.New("campaign variants must be 2 or 3 with unique labels and weights summing to 100")
+	ErrCampaignVariantsPlatformUnsupported      = errors.New("campaign variants are only supported for SMS campaigns")
+	ErrCampaignVariantTooLong                   = errors.New("campaign variant needs more SMS parts than the first variant")
+	ErrCampaignHasNoVariants                    = errors.New("campaign has no content variants")
+	ErrCampaignContentUnknownVariables          = errors.New("campaign content uses unknown personalization variables")
+	ErrCampaignPersonalizationUnsupported       = errors.New("personalization variables are only supported for SMS campaigns")
+	ErrBulkCampaignCountInvalid                 = errors.New("bulk campaign creation takes 1 to 100 campaigns")
 
 	ErrCampaignNotWaitingForApproval          = errors.New("campaign is not waiting for approval")
 	ErrCampaignNotApproved                    = errors.New("campaign is not approved")
+	ErrCampaignNotRunning                     = errors.New("campaign is not running")
+	ErrCampaignNotPaused                      = errors.New("campaign is not paused")
+	ErrCampaignNotUnderReview                 = errors.New("campaign is not under review")
 	ErrFreezeTransactionNotFound              = errors.New("freeze transaction not found for campaign")
 	ErrMultipleFreezeTransactionsFound        = errors.New("multiple freeze transactions found for campaign")
 	ErrCampaignDebitTransactionNotFound       = errors.New("campaign debit transaction not found")
 	ErrMultipleCampaignDebitTransactionsFound = errors.New("multiple campaign debit transactions found")
 
 	// Payment-related errors
-	ErrWalletNotFound           = errors.New("wallet not found")
-	ErrAmountTooLow             = errors.New("amount is too low")
-	ErrAmountNotMultiple        = errors.New("amount must be a multiple of 10000")
-	ErrAtipayTokenEmpty         = errors.New("atipay token is empty")
-	ErrInsufficientFunds        = errors.New("insufficient funds")
-	ErrInvalidLanguage          = errors.New("invalid language")
-	ErrReferrerAgencyIDRequired = errors.New("referrer agency ID is required")
-	ErrAgencyDiscountNotFound   = errors.New("agency discount not found")
+	ErrWalletNotFound               = errors.New("wallet not found")
+	ErrAmountTooLow                 = errors.New("amount is too low")
+	ErrAmountNotMultiple            = errors.New("amount must be a multiple of 10000")
+	ErrAtipayTokenEmpty             = errors.New("atipay token is empty")
+	ErrPaymentGatewayUnavailable    = errors.New("payment gateway unavailable")
+	ErrAtipayTerminalNotConfigured  = errors.New("atipay terminal is not configured")
+	ErrSandboxRealPayment           = errors.New("sandbox accounts cannot make real payments")
+	ErrSandboxNotEnabled            = errors.New("customer is not a sandbox account")
+	ErrSandboxCustomerHasHistory    = errors.New("only customers without wallet transactions can become sandbox accounts")
+	ErrRecordKindInvalid            = errors.New("record kind is not soft-deletable")
+	ErrRecordNotFound               = errors.New("record not found")
+	ErrRecordNotDeletable           = errors.New("record cannot be deleted in its current state")
+	ErrRecordAlreadyDeleted         = errors.New("record is already deleted")
+	ErrRecordNotDeleted             = errors.New("record is not deleted")
+	ErrTimelineCategoryInvalid      = errors.New("timeline category is invalid")
+	ErrTimelineCursorInvalid        = errors.New("timeline cursor is invalid")
+	ErrListQueryInvalid             = listquery.ErrInvalid
+	ErrTelegramNotConfigured        = errors.New("telegram notifications are not configured")
+	ErrTelegramLinkNotFound         = errors.New("no pending telegram link awaiting verification")
+	ErrTelegramCodeInvalid          = errors.New("telegram verification code is invalid")
+	ErrTelegramLinkLocked           = errors.New("too many wrong telegram verification codes")
+	ErrTelegramWebhookSecretInvalid = errors.New("telegram webhook secret token is invalid")
+	ErrPushNotConfigured            = errors.New("push notifications are not configured")
+	ErrPushDeviceNotFound           = errors.New("push device not found")
+	ErrInsufficientFunds            = errors.New("insufficient funds")
+	ErrInvalidLanguage              = errors.New("invalid language")
+	ErrReferrerAgencyIDRequired     = errors.New("referrer agency ID is required")
+	ErrAgencyDiscountNotFound       = errors.New("agency discount not found")
 
 	// Payment callback errors
 	ErrCallbackRequestNil             = errors.New("callback request is nil")
@@ -143,10 +196,17 @@ var (
 
 	// Agency discount errors
 	ErrDiscountRateOutOfRange              = errors.New("discount rate must be between 0 and 0.5")
+	ErrDiscountTiersInvalid                = errors.New("discount tiers must have increasing thresholds and non-decreasing rates of at most 0.5")
+	ErrDiscountEffectiveFromInvalid        = errors.New("discount effective date must be a future RFC3339 time")
 	ErrAgencyCannotCreateDiscountForItself = errors.New("agency cannot create discount for itself")
 	ErrCustomerNotUnderAgency              = errors.New("customer is not under this agency")
 	ErrAgencyCannotListDiscountsForItself  = errors.New("agency cannot list discounts for itself")
 
+	// Agency delegation errors
+	ErrAgencyDelegationNotFound          = errors.New("customer has no active delegation to their agency")
+	ErrAgencyDelegationDenied            = errors.New("agency is not allowed to act for this customer")
+	ErrAgencyDelegationPermissionInvalid = errors.New("unknown agency delegation permission")
+
 	// Admin and captcha related errors
 	ErrAdminNotFound               = errors.New("admin not found")
 	ErrAdminInactive               = errors.New("admin account is inactive")
@@ -163,6 +223,18 @@ var (
 	ErrLineNumberAlreadyExists = errors.New("line number already exists")
 	ErrLineNumberNotFound      = errors.New("line number not found")
 	ErrLineNumberNotActive     = errors.New("line number is not active")
+	ErrLineNumberDeleted       = errors.New("line number is deleted")
+
+	// Line number reservation and tier errors
+	ErrLineNumberReserved               = errors.New("line number is reserved by another customer")
+	ErrLineNumberAlreadyReserved        = errors.New("line number is already reserved by this customer")
+	ErrLineNumberNotReservable          = errors.New("line number has no active reservation tier")
+	ErrLineNumberReservationDaysInvalid = errors.New("reservation days must be between 1 and the tier maximum")
+	ErrLineNumberReservationNotFound    = errors.New("line number reservation not found")
+	ErrLineNumberReservationNotActive   = errors.New("line number reservation is not active")
+	ErrLineNumberTierNotFound           = errors.New("line number tier not found")
+	ErrLineNumberTierAlreadyExists      = errors.New("line number tier already exists")
+	ErrLineNumberTierMaxDaysInvalid     = errors.New("line number tier max reservation days must be greater than zero")
 
 	// Segment price factor errors
 	ErrLevel3Required                    = errors.New("level3 is required")
@@ -191,6 +263,9 @@ var (
 	ErrCryptoAddressProvisionFailed  = errors.New("failed to provision deposit address")
 	ErrCryptoProviderError           = errors.New("crypto provider error")
 	ErrCryptoDepositNotFound         = errors.New("crypto deposit not found")
+	ErrCryptoRateUnavailable         = errors.New("crypto exchange rate unavailable")
+	ErrCryptoRatesDiverged           = errors.New("crypto exchange rates diverged")
+	ErrCryptoWebhookStale            = errors.New("crypto webhook timestamp out of range")
 
 	// Deposit receipts
 	ErrDepositReceiptNotFound         = errors.New("deposit receipt not found")
@@ -205,6 +280,14 @@ var (
 	ErrDepositReceiptFileInvalidType  = errors.New("deposit receipt file type is not allowed")
 	ErrDepositReceiptFileEmpty        = errors.New("deposit receipt file is empty")
 
+	// Bank transfers behind deposit receipts
+	ErrDepositReceiptTransferDateInvalid   = errors.New("transfer date must be formatted as YYYY-MM-DD and not be in the future")
+	ErrDepositReceiptTrackingNumberInvalid = errors.New("tracking number must have at least 4 characters besides spaces and dashes")
+	ErrDepositReceiptTrackingNumberUsed    = errors.New("tracking number is already on another deposit receipt")
+	ErrDepositReceiptBankReferenceRequired = errors.New("bank statement reference is required to approve a deposit receipt")
+	ErrDepositReceiptBankReferenceUsed     = errors.New("bank statement entry is already matched with another deposit receipt")
+	ErrDepositReceiptMatchedAmountTooHigh  = errors.New("matched amount is above the amount claimed on the deposit receipt")
+
 	// Platform base prices
 	ErrPlatformBasePriceNotFound  = errors.New("platform base price not found")
 	ErrPlatformSettingsNameExists = errors.New("platform settings name already exists for this customer")
@@ -212,6 +295,129 @@ var (
 	// Page price
 	ErrPagePriceNotFound = errors.New("page base price not found")
 
+	// SMS tariffs
+	ErrSMSTariffNotFound        = errors.New("sms tariff not found")
+	ErrSMSTariffAlreadyExists   = errors.New("sms tariff already exists for these dimensions")
+	ErrSMSTariffOperatorInvalid = errors.New("sms tariff operator is invalid")
+
+	// Campaign templates
+	ErrCampaignTemplateNotFound      = errors.New("campaign template not found")
+	ErrCampaignTemplateAlreadyExists = errors.New("campaign template with this name already exists")
+	ErrCampaignTemplateNameRequired  = errors.New("campaign template name is required")
+
+	// Audience imports
+	ErrAudienceImportNotFound           = errors.New("audience import not found")
+	ErrAudienceImportFileEmpty          = errors.New("audience import file has no data rows")
+	ErrAudienceImportPhoneColumnMissing = errors.New("audience import file must have a phone_number column")
+	ErrAudienceImportTooManyRows        = errors.New("audience import file has too many rows")
+	ErrAudienceImportInvalidCSV         = errors.New("audience import file is not a valid CSV")
+	ErrAudienceImportUnknownTag         = errors.New("unknown tag")
+
+	// Background jobs
+	ErrJobNotFound       = errors.New("job not found")
+	ErrJobNotRequeuable  = errors.New("only dead jobs can be requeued")
+	ErrJobNotCancellable = errors.New("only pending or dead jobs can be cancelled")
+
+	// Customer data exports and account deletion
+	ErrDataRequestNotFound       = errors.New("data request not found")
+	ErrDataExportFormatInvalid   = errors.New("export format must be json or csv")
+	ErrDataExportInProgress      = errors.New("a data export is already in progress")
+	ErrDataExportNotReady        = errors.New("data export is not ready yet")
+	ErrDataExportExpired         = errors.New("data export has expired")
+	ErrAccountDeletionScheduled  = errors.New("account deletion is already scheduled")
+	ErrAccountDeletionNotFound   = errors.New("no account deletion is scheduled")
+	ErrAccountDeletionNotAllowed = errors.New("this account cannot be deleted")
+	ErrAccountDeletionBalance    = errors.New("withdraw or spend the wallet balance before deleting the account")
+	ErrAccountDeletionCampaigns  = errors.New("cancel or finish active campaigns before deleting the account")
+
+	// Blacklist
+	ErrBlacklistedNumberNotFound   = errors.New("number is not blacklisted")
+	ErrBlacklistSourceInvalid      = errors.New("blacklist source must be one of regulator, complaint or admin")
+	ErrBlacklistPhoneInvalid       = errors.New("phone number is not a valid Iranian mobile number")
+	ErrBlacklistFileEmpty          = errors.New("blacklist file has no data rows")
+	ErrBlacklistPhoneColumnMissing = errors.New("blacklist file must have a phone_number column")
+	ErrBlacklistTooManyRows        = errors.New("blacklist file has too many rows")
+	ErrBlacklistInvalidCSV         = errors.New("blacklist file is not a valid CSV")
+
+	// Wallet adjustments
+	ErrWalletAdjustmentNotFound      = errors.New("wallet adjustment request not found")
+	ErrWalletAdjustmentNotPending    = errors.New("wallet adjustment request was already reviewed")
+	ErrWalletAdjustmentSelfReview    = errors.New("wallet adjustment must be reviewed by another admin")
+	ErrWalletAdjustmentReasonInvalid = errors.New("reason code does not apply to this adjustment direction")
+
+	// Postpaid billing
+	ErrPostpaidInvoiceOverdue     = errors.New("customer has an overdue postpaid invoice")
+	ErrPostpaidInvoiceNotFound    = errors.New("postpaid invoice not found")
+	ErrPostpaidInvoiceAlreadyPaid = errors.New("postpaid invoice was already paid")
+
+	// Tax invoices
+	ErrTaxInvoiceNotFound       = errors.New("tax invoice not found")
+	ErrTaxInvoicePDFUnavailable = errors.New("tax invoice PDF rendering is not configured")
+
+	// Wallet transfers
+	ErrWalletTransferDisabled            = errors.New("wallet transfers are disabled")
+	ErrWalletTransferNotFound            = errors.New("wallet transfer not found")
+	ErrWalletTransferNotPending          = errors.New("wallet transfer is no longer pending")
+	ErrWalletTransferSelf                = errors.New("cannot transfer to the same account")
+	ErrWalletTransferReceiverNotEligible = errors.New("receiver is not an account of the same company")
+	ErrWalletTransferSandbox             = errors.New("sandbox accounts cannot transfer balance")
+	ErrWalletTransferAmountOutOfRange    = errors.New("transfer amount is outside the allowed range")
+	ErrWalletTransferDailyLimitExceeded  = errors.New("transfer exceeds the daily transfer limit")
+	ErrWalletTransferOTPExpired          = errors.New("wallet transfer code expired")
+	ErrWalletTransferOTPInvalid          = errors.New("invalid wallet transfer code")
+
+	// Admin bulk operations
+	ErrAdminBulkCountInvalid = errors.New("bulk operations take 1 to 100 items")
+	ErrAgencyReassignSelf    = errors.New("customer cannot be its own referrer agency")
+
+	// Customer suspension
+	ErrCustomerSuspended               = errors.New("customer is suspended")
+	ErrCustomerSuspensionLevelInvalid  = errors.New("suspension level must be warning, restricted or suspended")
+	ErrCustomerSuspensionReasonInvalid = errors.New("suspension reason is not a known reason code")
+
+	// KYC
+	ErrUploadsNotConfigured     = errors.New("document uploads are not configured")
+	ErrKYCNotApplicable         = errors.New("only company and agency accounts go through KYC")
+	ErrKYCDocumentTooLarge      = errors.New("document must be at most 5MB")
+	ErrKYCDocumentTypeInvalid   = errors.New("document must be a JPEG, PNG or PDF file")
+	ErrKYCDocumentInfected      = errors.New("document failed the virus scan")
+	ErrKYCDocumentsMissing      = errors.New("required documents are missing")
+	ErrKYCStatusConflict        = errors.New("KYC status does not allow this action")
+	ErrKYCRejectionReasonNeeded = errors.New("rejecting documents requires a reason")
+	ErrSendingQuotaAboveKYCCap  = errors.New("sending limits above the unverified cap require verified company documents")
+
+	// Profile changes
+	ErrCompanyProfileNotApplicable        = errors.New("only company and agency accounts have company information")
+	ErrProfileChangeNotFound              = errors.New("profile change not found")
+	ErrProfileChangeNotPending            = errors.New("profile change is not waiting for review")
+	ErrProfileChangeRejectionReasonNeeded = errors.New("rejecting a profile change requires a reason")
+
+	// Sheba verification
+	ErrShebaInquiryNotConfigured = errors.New("sheba verification is not configured")
+	ErrShebaInquiryUnavailable   = errors.New("bank inquiry is unavailable")
+	ErrAgencyShebaNotVerified    = errors.New("agency sheba number is not verified to belong to the agency")
+
+	// Agency share payables
+	ErrAgencyPayableNotFound    = errors.New("agency share payable not found")
+	ErrAgencyPayableAlreadyPaid = errors.New("agency share payable was already paid")
+
+	// Wallet charges
+	ErrWalletChargeMethodInvalid   = errors.New("wallet charge method is invalid")
+	ErrWalletChargeDetailsRequired = errors.New("wallet charge method details are required")
+
+	// Atipay settlement reconciliation
+	ErrAtipayReportDateInvalid        = errors.New("report date must be a past day formatted as YYYY-MM-DD")
+	ErrAtipayReportFileEmpty          = errors.New("settlement report has no data rows")
+	ErrAtipayReportColumnsMissing     = errors.New("settlement report must have an amount column and a reference_number, rrn or reservation_number column")
+	ErrAtipayReportTooManyRows        = errors.New("settlement report has too many rows")
+	ErrAtipayReportInvalidCSV         = errors.New("settlement report is not a valid CSV")
+	ErrAtipayReportFetcherUnavailable = errors.New("atipay settlement report download is not configured")
+
+	// Sending quota
+	ErrSendingQuotaExceeded = errors.New("campaign audience exceeds the remaining sending quota")
+	ErrSendingQuotaInvalid  = errors.New("daily sending limit cannot exceed the monthly limit")
+	ErrFrequencyCapInvalid  = errors.New("frequency cap must be between 1 and 720 hours")
+
 	ErrNotFound     = errors.New("not found")
 	ErrInvalidState = errors.New("invalid state")
 	ErrForbidden    = errors.New("forbidden")
@@ -278,6 +484,14 @@ func IsMobileAlreadyExists(err error) bool {
 	return errors.Is(err, ErrMobileAlreadyExists)
 }
 
+func IsMobileUnchanged(err error) bool {
+	return errors.Is(err, ErrMobileUnchanged)
+}
+
+func IsInvalidMobileNumber(err error) bool {
+	return errors.Is(err, ErrInvalidMobileNumber)
+}
+
 func IsNationalIDAlreadyExists(err error) bool {
 	return errors.Is(err, ErrNationalIDAlreadyExists)
 }
@@ -558,6 +772,26 @@ func IsAlreadyVerified(err error) bool {
 	return errors.Is(err, ErrAlreadyVerified)
 }
 
+func IsSessionNotFound(err error) bool {
+	return errors.Is(err, ErrSessionNotFound)
+}
+
+func IsPasswordResetRequired(err error) bool {
+	return errors.Is(err, ErrPasswordResetRequired)
+}
+
+func IsLoginAlertNotFound(err error) bool {
+	return errors.Is(err, ErrLoginAlertNotFound)
+}
+
+func IsPasswordRejected(err error) bool {
+	return errors.Is(err, ErrPasswordRejected)
+}
+
+func IsPasswordlessLoginDisabled(err error) bool {
+	return errors.Is(err, ErrPasswordlessLoginDisabled)
+}
+
 func IsWalletNotFound(err error) bool {
 	return errors.Is(err, ErrWalletNotFound)
 }
@@ -574,6 +808,86 @@ func IsAtipayTokenEmpty(err error) bool {
 	return errors.Is(err, ErrAtipayTokenEmpty)
 }
 
+func IsPaymentGatewayUnavailable(err error) bool {
+	return errors.Is(err, ErrPaymentGatewayUnavailable)
+}
+
+func IsAtipayTerminalNotConfigured(err error) bool {
+	return errors.Is(err, ErrAtipayTerminalNotConfigured)
+}
+
+func IsSandboxRealPayment(err error) bool {
+	return errors.Is(err, ErrSandboxRealPayment)
+}
+
+func IsSandboxNotEnabled(err error) bool {
+	return errors.Is(err, ErrSandboxNotEnabled)
+}
+
+func IsSandboxCustomerHasHistory(err error) bool {
+	return errors.Is(err, ErrSandboxCustomerHasHistory)
+}
+
+func IsRecordKindInvalid(err error) bool {
+	return errors.Is(err, ErrRecordKindInvalid)
+}
+
+func IsRecordNotFound(err error) bool {
+	return errors.Is(err, ErrRecordNotFound)
+}
+
+func IsRecordNotDeletable(err error) bool {
+	return errors.Is(err, ErrRecordNotDeletable)
+}
+
+func IsRecordAlreadyDeleted(err error) bool {
+	return errors.Is(err, ErrRecordAlreadyDeleted)
+}
+
+func IsRecordNotDeleted(err error) bool {
+	return errors.Is(err, ErrRecordNotDeleted)
+}
+
+func IsTimelineCategoryInvalid(err error) bool {
+	return errors.Is(err, ErrTimelineCategoryInvalid)
+}
+
+func IsTimelineCursorInvalid(err error) bool {
+	return errors.Is(err, ErrTimelineCursorInvalid)
+}
+
+func IsListQueryInvalid(err error) bool {
+	return errors.Is(err, ErrListQueryInvalid)
+}
+
+func IsTelegramNotConfigured(err error) bool {
+	return errors.Is(err, ErrTelegramNotConfigured)
+}
+
+func IsTelegramLinkNotFound(err error) bool {
+	return errors.Is(err, ErrTelegramLinkNotFound)
+}
+
+func IsTelegramCodeInvalid(err error) bool {
+	return errors.Is(err, ErrTelegramCodeInvalid)
+}
+
+func IsTelegramLinkLocked(err error) bool {
+	return errors.Is(err, ErrTelegramLinkLocked)
+}
+
+func IsTelegramWebhookSecretInvalid(err error) bool {
+	return errors.Is(err, ErrTelegramWebhookSecretInvalid)
+}
+
+func IsPushNotConfigured(err error) bool {
+	return errors.Is(err, ErrPushNotConfigured)
+}
+
+func IsPushDeviceNotFound(err error) bool {
+	return errors.Is(err, ErrPushDeviceNotFound)
+}
+
 func IsInvalidLanguage(err error) bool {
 	return errors.Is(err, ErrInvalidLanguage)
 }
@@ -693,6 +1007,14 @@ func IsDiscountRateOutOfRange(err error) bool {
 	return errors.Is(err, ErrDiscountRateOutOfRange)
 }
 
+func IsDiscountTiersInvalid(err error) bool {
+	return errors.Is(err, ErrDiscountTiersInvalid)
+}
+
+func IsDiscountEffectiveFromInvalid(err error) bool {
+	return errors.Is(err, ErrDiscountEffectiveFromInvalid)
+}
+
 func IsAgencyCannotCreateDiscountForItself(err error) bool {
 	return errors.Is(err, ErrAgencyCannotCreateDiscountForItself)
 }
@@ -705,6 +1027,18 @@ func IsAgencyCannotListDiscountsForItself(err error) bool {
 	return errors.Is(err, ErrAgencyCannotListDiscountsForItself)
 }
 
+func IsAgencyDelegationNotFound(err error) bool {
+	return errors.Is(err, ErrAgencyDelegationNotFound)
+}
+
+func IsAgencyDelegationDenied(err error) bool {
+	return errors.Is(err, ErrAgencyDelegationDenied)
+}
+
+func IsAgencyDelegationPermissionInvalid(err error) bool {
+	return errors.Is(err, ErrAgencyDelegationPermissionInvalid)
+}
+
 func IsAdminNotFound(err error) bool {
 	return errors.Is(err, ErrAdminNotFound)
 }
@@ -745,6 +1079,10 @@ func IsLineNumberNotActive(err error) bool {
 	return errors.Is(err, ErrLineNumberNotActive)
 }
 
+func IsLineNumberDeleted(err error) bool {
+	return errors.Is(err, ErrLineNumberDeleted)
+}
+
 func IsLevel3Required(err error) bool {
 	return errors.Is(err, ErrLevel3Required)
 }
@@ -784,6 +1122,9 @@ func IsCryptoAddressProvisionFailed(err error) bool {
 }
 func IsCryptoProviderError(err error) bool   { return errors.Is(err, ErrCryptoProviderError) }
 func IsCryptoDepositNotFound(err error) bool { return errors.Is(err, ErrCryptoDepositNotFound) }
+func IsCryptoRateUnavailable(err error) bool { return errors.Is(err, ErrCryptoRateUnavailable) }
+func IsCryptoRatesDiverged(err error) bool   { return errors.Is(err, ErrCryptoRatesDiverged) }
+func IsCryptoWebhookStale(err error) bool    { return errors.Is(err, ErrCryptoWebhookStale) }
 
 func IsDepositReceiptNotFound(err error) bool { return errors.Is(err, ErrDepositReceiptNotFound) }
 func IsDepositReceiptAlreadyApproved(err error) bool {
@@ -817,6 +1158,30 @@ func IsDepositReceiptFileEmpty(err error) bool {
 	return errors.Is(err, ErrDepositReceiptFileEmpty)
 }
 
+func IsDepositReceiptTransferDateInvalid(err error) bool {
+	return errors.Is(err, ErrDepositReceiptTransferDateInvalid)
+}
+
+func IsDepositReceiptTrackingNumberInvalid(err error) bool {
+	return errors.Is(err, ErrDepositReceiptTrackingNumberInvalid)
+}
+
+func IsDepositReceiptTrackingNumberUsed(err error) bool {
+	return errors.Is(err, ErrDepositReceiptTrackingNumberUsed)
+}
+
+func IsDepositReceiptBankReferenceRequired(err error) bool {
+	return errors.Is(err, ErrDepositReceiptBankReferenceRequired)
+}
+
+func IsDepositReceiptBankReferenceUsed(err error) bool {
+	return errors.Is(err, ErrDepositReceiptBankReferenceUsed)
+}
+
+func IsDepositReceiptMatchedAmountTooHigh(err error) bool {
+	return errors.Is(err, ErrDepositReceiptMatchedAmountTooHigh)
+}
+
 func IsPlatformBasePriceNotFound(err error) bool {
 	return errors.Is(err, ErrPlatformBasePriceNotFound)
 }
@@ -828,3 +1193,393 @@ func IsPlatformSettingsNameExists(err error) bool {
 func IsPagePriceNotFound(err error) bool {
 	return errors.Is(err, ErrPagePriceNotFound)
 }
+
+func IsSMSTariffNotFound(err error) bool {
+	return errors.Is(err, ErrSMSTariffNotFound)
+}
+
+func IsSMSTariffAlreadyExists(err error) bool {
+	return errors.Is(err, ErrSMSTariffAlreadyExists)
+}
+
+func IsSMSTariffOperatorInvalid(err error) bool {
+	return errors.Is(err, ErrSMSTariffOperatorInvalid)
+}
+
+func IsCampaignTagInvalid(err error) bool {
+	return errors.Is(err, ErrCampaignTagInvalid)
+}
+
+func IsCampaignTemplateNotFound(err error) bool {
+	return errors.Is(err, ErrCampaignTemplateNotFound)
+}
+
+func IsCampaignTemplateAlreadyExists(err error) bool {
+	return errors.Is(err, ErrCampaignTemplateAlreadyExists)
+}
+
+func IsCampaignTemplateNameRequired(err error) bool {
+	return errors.Is(err, ErrCampaignTemplateNameRequired)
+}
+
+func IsCampaignRecurrenceInvalid(err error) bool {
+	return errors.Is(err, ErrCampaignRecurrenceInvalid)
+}
+
+func IsCampaignRecurrenceTooFrequent(err error) bool {
+	return errors.Is(err, ErrCampaignRecurrenceTooFrequent)
+}
+
+func IsCampaignRecurrenceUntilInvalid(err error) bool {
+	return errors.Is(err, ErrCampaignRecurrenceUntilInvalid)
+}
+
+func IsCampaignNotRunning(err error) bool {
+	return errors.Is(err, ErrCampaignNotRunning)
+}
+
+func IsCampaignNotPaused(err error) bool {
+	return errors.Is(err, ErrCampaignNotPaused)
+}
+
+func IsCampaignVariantsInvalid(err error) bool {
+	return errors.Is(err, ErrCampaignVariantsInvalid)
+}
+
+func IsCampaignVariantsPlatformUnsupported(err error) bool {
+	return errors.Is(err, ErrCampaignVariantsPlatformUnsupported)
+}
+
+func IsCampaignVariantTooLong(err error) bool {
+	return errors.Is(err, ErrCampaignVariantTooLong)
+}
+
+func IsCampaignHasNoVariants(err error) bool {
+	return errors.Is(err, ErrCampaignHasNoVariants)
+}
+
+func IsCampaignContentUnknownVariables(err error) bool {
+	return errors.Is(err, ErrCampaignContentUnknownVariables)
+}
+
+func IsCampaignPersonalizationUnsupported(err error) bool {
+	return errors.Is(err, ErrCampaignPersonalizationUnsupported)
+}
+
+func IsBulkCampaignCountInvalid(err error) bool {
+	return errors.Is(err, ErrBulkCampaignCountInvalid)
+}
+
+func IsAudienceImportNotFound(err error) bool {
+	return errors.Is(err, ErrAudienceImportNotFound)
+}
+
+func IsJobNotFound(err error) bool {
+	return errors.Is(err, ErrJobNotFound)
+}
+
+func IsJobNotRequeuable(err error) bool {
+	return errors.Is(err, ErrJobNotRequeuable)
+}
+
+func IsJobNotCancellable(err error) bool {
+	return errors.Is(err, ErrJobNotCancellable)
+}
+
+func IsAudienceImportFileInvalid(err error) bool {
+	return errors.Is(err, ErrAudienceImportFileEmpty) ||
+		errors.Is(err, ErrAudienceImportPhoneColumnMissing) ||
+		errors.Is(err, ErrAudienceImportTooManyRows) ||
+		errors.Is(err, ErrAudienceImportInvalidCSV)
+}
+
+func IsAudienceImportUnknownTag(err error) bool {
+	return errors.Is(err, ErrAudienceImportUnknownTag)
+}
+
+func IsDataRequestNotFound(err error) bool {
+	return errors.Is(err, ErrDataRequestNotFound)
+}
+
+func IsDataExportFormatInvalid(err error) bool {
+	return errors.Is(err, ErrDataExportFormatInvalid)
+}
+
+func IsDataExportInProgress(err error) bool {
+	return errors.Is(err, ErrDataExportInProgress)
+}
+
+func IsDataExportNotReady(err error) bool {
+	return errors.Is(err, ErrDataExportNotReady)
+}
+
+func IsDataExportExpired(err error) bool {
+	return errors.Is(err, ErrDataExportExpired)
+}
+
+func IsAccountDeletionScheduled(err error) bool {
+	return errors.Is(err, ErrAccountDeletionScheduled)
+}
+
+func IsAccountDeletionNotFound(err error) bool {
+	return errors.Is(err, ErrAccountDeletionNotFound)
+}
+
+func IsAccountDeletionNotAllowed(err error) bool {
+	return errors.Is(err, ErrAccountDeletionNotAllowed)
+}
+
+func IsAccountDeletionBalance(err error) bool {
+	return errors.Is(err, ErrAccountDeletionBalance)
+}
+
+func IsAccountDeletionCampaigns(err error) bool {
+	return errors.Is(err, ErrAccountDeletionCampaigns)
+}
+
+func IsBlacklistedNumberNotFound(err error) bool {
+	return errors.Is(err, ErrBlacklistedNumberNotFound)
+}
+
+func IsBlacklistRequestInvalid(err error) bool {
+	return errors.Is(err, ErrBlacklistSourceInvalid) ||
+		errors.Is(err, ErrBlacklistPhoneInvalid)
+}
+
+func IsBlacklistFileInvalid(err error) bool {
+	return errors.Is(err, ErrBlacklistFileEmpty) ||
+		errors.Is(err, ErrBlacklistPhoneColumnMissing) ||
+		errors.Is(err, ErrBlacklistTooManyRows) ||
+		errors.Is(err, ErrBlacklistInvalidCSV)
+}
+
+func IsWalletAdjustmentNotFound(err error) bool {
+	return errors.Is(err, ErrWalletAdjustmentNotFound)
+}
+
+func IsWalletAdjustmentNotPending(err error) bool {
+	return errors.Is(err, ErrWalletAdjustmentNotPending)
+}
+
+func IsWalletAdjustmentSelfReview(err error) bool {
+	return errors.Is(err, ErrWalletAdjustmentSelfReview)
+}
+
+func IsWalletAdjustmentReasonInvalid(err error) bool {
+	return errors.Is(err, ErrWalletAdjustmentReasonInvalid)
+}
+
+func IsPostpaidInvoiceOverdue(err error) bool {
+	return errors.Is(err, ErrPostpaidInvoiceOverdue)
+}
+
+func IsPostpaidInvoiceNotFound(err error) bool {
+	return errors.Is(err, ErrPostpaidInvoiceNotFound)
+}
+
+func IsPostpaidInvoiceAlreadyPaid(err error) bool {
+	return errors.Is(err, ErrPostpaidInvoiceAlreadyPaid)
+}
+
+func IsTaxInvoiceNotFound(err error) bool {
+	return errors.Is(err, ErrTaxInvoiceNotFound)
+}
+
+func IsTaxInvoicePDFUnavailable(err error) bool {
+	return errors.Is(err, ErrTaxInvoicePDFUnavailable)
+}
+
+func IsWalletTransferDisabled(err error) bool {
+	return errors.Is(err, ErrWalletTransferDisabled)
+}
+
+func IsWalletTransferNotFound(err error) bool {
+	return errors.Is(err, ErrWalletTransferNotFound)
+}
+
+func IsWalletTransferNotPending(err error) bool {
+	return errors.Is(err, ErrWalletTransferNotPending)
+}
+
+func IsWalletTransferSelf(err error) bool {
+	return errors.Is(err, ErrWalletTransferSelf)
+}
+
+func IsAdminBulkCountInvalid(err error) bool {
+	return errors.Is(err, ErrAdminBulkCountInvalid)
+}
+
+func IsAgencyReassignSelf(err error) bool {
+	return errors.Is(err, ErrAgencyReassignSelf)
+}
+
+func IsCustomerSuspended(err error) bool {
+	return errors.Is(err, ErrCustomerSuspended)
+}
+
+func IsUploadsNotConfigured(err error) bool {
+	return errors.Is(err, ErrUploadsNotConfigured)
+}
+
+func IsKYCNotApplicable(err error) bool {
+	return errors.Is(err, ErrKYCNotApplicable)
+}
+
+func IsKYCDocumentInvalid(err error) bool {
+	return errors.Is(err, ErrKYCDocumentTooLarge) || errors.Is(err, ErrKYCDocumentTypeInvalid)
+}
+
+func IsKYCDocumentInfected(err error) bool {
+	return errors.Is(err, ErrKYCDocumentInfected)
+}
+
+func IsKYCDocumentsMissing(err error) bool {
+	return errors.Is(err, ErrKYCDocumentsMissing)
+}
+
+func IsKYCStatusConflict(err error) bool {
+	return errors.Is(err, ErrKYCStatusConflict)
+}
+
+func IsKYCRejectionReasonNeeded(err error) bool {
+	return errors.Is(err, ErrKYCRejectionReasonNeeded)
+}
+
+func IsSendingQuotaAboveKYCCap(err error) bool {
+	return errors.Is(err, ErrSendingQuotaAboveKYCCap)
+}
+
+func IsCompanyProfileNotApplicable(err error) bool {
+	return errors.Is(err, ErrCompanyProfileNotApplicable)
+}
+
+func IsProfileChangeNotFound(err error) bool {
+	return errors.Is(err, ErrProfileChangeNotFound)
+}
+
+func IsProfileChangeNotPending(err error) bool {
+	return errors.Is(err, ErrProfileChangeNotPending)
+}
+
+func IsProfileChangeRejectionReasonNeeded(err error) bool {
+	return errors.Is(err, ErrProfileChangeRejectionReasonNeeded)
+}
+
+func IsShebaInquiryNotConfigured(err error) bool {
+	return errors.Is(err, ErrShebaInquiryNotConfigured)
+}
+
+func IsShebaInquiryUnavailable(err error) bool {
+	return errors.Is(err, ErrShebaInquiryUnavailable)
+}
+
+func IsAgencyShebaNotVerified(err error) bool {
+	return errors.Is(err, ErrAgencyShebaNotVerified)
+}
+
+func IsAgencyPayableNotFound(err error) bool {
+	return errors.Is(err, ErrAgencyPayableNotFound)
+}
+
+func IsAgencyPayableAlreadyPaid(err error) bool {
+	return errors.Is(err, ErrAgencyPayableAlreadyPaid)
+}
+
+func IsWalletChargeMethodInvalid(err error) bool {
+	return errors.Is(err, ErrWalletChargeMethodInvalid)
+}
+
+func IsWalletChargeDetailsRequired(err error) bool {
+	return errors.Is(err, ErrWalletChargeDetailsRequired)
+}
+
+func IsCustomerSuspensionInvalid(err error) bool {
+	return errors.Is(err, ErrCustomerSuspensionLevelInvalid) || errors.Is(err, ErrCustomerSuspensionReasonInvalid)
+}
+
+func IsWalletTransferReceiverNotEligible(err error) bool {
+	return errors.Is(err, ErrWalletTransferReceiverNotEligible)
+}
+
+func IsWalletTransferSandbox(err error) bool {
+	return errors.Is(err, ErrWalletTransferSandbox)
+}
+
+func IsWalletTransferAmountOutOfRange(err error) bool {
+	return errors.Is(err, ErrWalletTransferAmountOutOfRange)
+}
+
+func IsWalletTransferDailyLimitExceeded(err error) bool {
+	return errors.Is(err, ErrWalletTransferDailyLimitExceeded)
+}
+
+func IsWalletTransferOTPExpired(err error) bool {
+	return errors.Is(err, ErrWalletTransferOTPExpired)
+}
+
+func IsWalletTransferOTPInvalid(err error) bool {
+	return errors.Is(err, ErrWalletTransferOTPInvalid)
+}
+
+func IsAtipayReportDateInvalid(err error) bool {
+	return errors.Is(err, ErrAtipayReportDateInvalid)
+}
+
+func IsAtipayReportFileInvalid(err error) bool {
+	return errors.Is(err, ErrAtipayReportFileEmpty) ||
+		errors.Is(err, ErrAtipayReportColumnsMissing) ||
+		errors.Is(err, ErrAtipayReportTooManyRows) ||
+		errors.Is(err, ErrAtipayReportInvalidCSV)
+}
+
+func IsAtipayReportFetcherUnavailable(err error) bool {
+	return errors.Is(err, ErrAtipayReportFetcherUnavailable)
+}
+
+func IsCampaignNotUnderReview(err error) bool {
+	return errors.Is(err, ErrCampaignNotUnderReview)
+}
+
+func IsSendingQuotaExceeded(err error) bool {
+	return errors.Is(err, ErrSendingQuotaExceeded)
+}
+
+func IsSendingQuotaInvalid(err error) bool {
+	return errors.Is(err, ErrSendingQuotaInvalid) || errors.Is(err, ErrFrequencyCapInvalid)
+}
+
+func IsLineNumberReserved(err error) bool {
+	return errors.Is(err, ErrLineNumberReserved)
+}
+
+func IsLineNumberAlreadyReserved(err error) bool {
+	return errors.Is(err, ErrLineNumberAlreadyReserved)
+}
+
+func IsLineNumberNotReservable(err error) bool {
+	return errors.Is(err, ErrLineNumberNotReservable)
+}
+
+func IsLineNumberReservationDaysInvalid(err error) bool {
+	return errors.Is(err, ErrLineNumberReservationDaysInvalid)
+}
+
+func IsLineNumberReservationNotFound(err error) bool {
+	return errors.Is(err, ErrLineNumberReservationNotFound)
+}
+
+func IsLineNumberReservationNotActive(err error) bool {
+	return errors.Is(err, ErrLineNumberReservationNotActive)
+}
+
+func IsLineNumberTierNotFound(err error) bool {
+	return errors.Is(err, ErrLineNumberTierNotFound)
+}
+
+func IsLineNumberTierAlreadyExists(err error) bool {
+	return errors.Is(err, ErrLineNumberTierAlreadyExists)
+}
+
+func IsLineNumberTierMaxDaysInvalid(err error) bool {
+	return errors.Is(err, ErrLineNumberTierMaxDaysInvalid)
+}
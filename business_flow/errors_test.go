@@ -80,10 +80,15 @@ func TestErrorSentinels(t *testing.T) {
 		{"MobileNumberNotVerified", ErrMobileNumberNotVerified, IsMobileNumberNotVerified},
 		{"EmailAlreadyExists", ErrEmailAlreadyExists, IsEmailAlreadyExists},
 		{"MobileAlreadyExists", ErrMobileAlreadyExists, IsMobileAlreadyExists},
+		{"MobileUnchanged", ErrMobileUnchanged, IsMobileUnchanged},
+		{"InvalidMobileNumber", ErrInvalidMobileNumber, IsInvalidMobileNumber},
 		{"NationalIDAlreadyExists", ErrNationalIDAlreadyExists, IsNationalIDAlreadyExists},
 		{"NationalIDRequired", ErrNationalIDRequired, IsNationalIDRequired},
 		{"AgencyNotFound", ErrAgencyNotFound, IsAgencyNotFound},
 		{"AgencyInactive", ErrAgencyInactive, IsAgencyInactive},
+		{"AgencyDelegationNotFound", ErrAgencyDelegationNotFound, IsAgencyDelegationNotFound},
+		{"AgencyDelegationDenied", ErrAgencyDelegationDenied, IsAgencyDelegationDenied},
+		{"AgencyDelegationPermissionInvalid", ErrAgencyDelegationPermissionInvalid, IsAgencyDelegationPermissionInvalid},
 		{"NoValidOTPFound", ErrNoValidOTPFound, IsNoValidOTPFound},
 		{"InvalidOTPCode", ErrInvalidOTPCode, IsInvalidOTPCode},
 		{"OTPExpired", ErrOTPExpired, IsOTPExpired},
@@ -102,6 +107,8 @@ func TestErrorSentinels(t *testing.T) {
 		{"InvalidPageSize", ErrInvalidPageSize, IsInvalidPageSize},
 		{"StartDateAfterEndDate", ErrStartDateAfterEndDate, IsStartDateAfterEndDate},
 		{"DiscountRateOutOfRange", ErrDiscountRateOutOfRange, IsDiscountRateOutOfRange},
+		{"DiscountTiersInvalid", ErrDiscountTiersInvalid, IsDiscountTiersInvalid},
+		{"DiscountEffectiveFromInvalid", ErrDiscountEffectiveFromInvalid, IsDiscountEffectiveFromInvalid},
 		{"AdminNotFound", ErrAdminNotFound, IsAdminNotFound},
 		{"AdminInactive", ErrAdminInactive, IsAdminInactive},
 		{"BotNotFound", ErrBotNotFound, IsBotNotFound},
@@ -110,7 +117,84 @@ func TestErrorSentinels(t *testing.T) {
 		{"SegmentPriceFactorNotFound", ErrSegmentPriceFactorNotFound, IsSegmentPriceFactorNotFound},
 		{"ShortLinkNotFound", ErrShortLinkNotFound, IsShortLinkNotFound},
 		{"CryptoRequestNotFound", ErrCryptoRequestNotFound, IsCryptoRequestNotFound},
+		{"CryptoRatesDiverged", ErrCryptoRatesDiverged, IsCryptoRatesDiverged},
+		{"CryptoWebhookStale", ErrCryptoWebhookStale, IsCryptoWebhookStale},
 		{"DepositReceiptNotFound", ErrDepositReceiptNotFound, IsDepositReceiptNotFound},
+		{"DepositReceiptTransferDateInvalid", ErrDepositReceiptTransferDateInvalid, IsDepositReceiptTransferDateInvalid},
+		{"DepositReceiptTrackingNumberInvalid", ErrDepositReceiptTrackingNumberInvalid, IsDepositReceiptTrackingNumberInvalid},
+		{"DepositReceiptTrackingNumberUsed", ErrDepositReceiptTrackingNumberUsed, IsDepositReceiptTrackingNumberUsed},
+		{"DepositReceiptBankReferenceRequired", ErrDepositReceiptBankReferenceRequired, IsDepositReceiptBankReferenceRequired},
+		{"DepositReceiptBankReferenceUsed", ErrDepositReceiptBankReferenceUsed, IsDepositReceiptBankReferenceUsed},
+		{"DepositReceiptMatchedAmountTooHigh", ErrDepositReceiptMatchedAmountTooHigh, IsDepositReceiptMatchedAmountTooHigh},
+		{"WalletAdjustmentNotFound", ErrWalletAdjustmentNotFound, IsWalletAdjustmentNotFound},
+		{"WalletAdjustmentNotPending", ErrWalletAdjustmentNotPending, IsWalletAdjustmentNotPending},
+		{"WalletAdjustmentSelfReview", ErrWalletAdjustmentSelfReview, IsWalletAdjustmentSelfReview},
+		{"WalletAdjustmentReasonInvalid", ErrWalletAdjustmentReasonInvalid, IsWalletAdjustmentReasonInvalid},
+		{"PostpaidInvoiceOverdue", ErrPostpaidInvoiceOverdue, IsPostpaidInvoiceOverdue},
+		{"PostpaidInvoiceNotFound", ErrPostpaidInvoiceNotFound, IsPostpaidInvoiceNotFound},
+		{"PostpaidInvoiceAlreadyPaid", ErrPostpaidInvoiceAlreadyPaid, IsPostpaidInvoiceAlreadyPaid},
+		{"TaxInvoiceNotFound", ErrTaxInvoiceNotFound, IsTaxInvoiceNotFound},
+		{"TaxInvoicePDFUnavailable", ErrTaxInvoicePDFUnavailable, IsTaxInvoicePDFUnavailable},
+		{"WalletTransferDisabled", ErrWalletTransferDisabled, IsWalletTransferDisabled},
+		{"WalletTransferNotFound", ErrWalletTransferNotFound, IsWalletTransferNotFound},
+		{"WalletTransferNotPending", ErrWalletTransferNotPending, IsWalletTransferNotPending},
+		{"WalletTransferSelf", ErrWalletTransferSelf, IsWalletTransferSelf},
+		{"WalletTransferReceiverNotEligible", ErrWalletTransferReceiverNotEligible, IsWalletTransferReceiverNotEligible},
+		{"WalletTransferSandbox", ErrWalletTransferSandbox, IsWalletTransferSandbox},
+		{"WalletTransferAmountOutOfRange", ErrWalletTransferAmountOutOfRange, IsWalletTransferAmountOutOfRange},
+		{"WalletTransferDailyLimitExceeded", ErrWalletTransferDailyLimitExceeded, IsWalletTransferDailyLimitExceeded},
+		{"WalletTransferOTPExpired", ErrWalletTransferOTPExpired, IsWalletTransferOTPExpired},
+		{"WalletTransferOTPInvalid", ErrWalletTransferOTPInvalid, IsWalletTransferOTPInvalid},
+		{"AdminBulkCountInvalid", ErrAdminBulkCountInvalid, IsAdminBulkCountInvalid},
+		{"AgencyReassignSelf", ErrAgencyReassignSelf, IsAgencyReassignSelf},
+		{"CustomerSuspended", ErrCustomerSuspended, IsCustomerSuspended},
+		{"CustomerSuspensionLevelInvalid", ErrCustomerSuspensionLevelInvalid, IsCustomerSuspensionInvalid},
+		{"CustomerSuspensionReasonInvalid", ErrCustomerSuspensionReasonInvalid, IsCustomerSuspensionInvalid},
+		{"UploadsNotConfigured", ErrUploadsNotConfigured, IsUploadsNotConfigured},
+		{"KYCNotApplicable", ErrKYCNotApplicable, IsKYCNotApplicable},
+		{"KYCDocumentTooLarge", ErrKYCDocumentTooLarge, IsKYCDocumentInvalid},
+		{"KYCDocumentTypeInvalid", ErrKYCDocumentTypeInvalid, IsKYCDocumentInvalid},
+		{"KYCDocumentInfected", ErrKYCDocumentInfected, IsKYCDocumentInfected},
+		{"KYCDocumentsMissing", ErrKYCDocumentsMissing, IsKYCDocumentsMissing},
+		{"KYCStatusConflict", ErrKYCStatusConflict, IsKYCStatusConflict},
+		{"KYCRejectionReasonNeeded", ErrKYCRejectionReasonNeeded, IsKYCRejectionReasonNeeded},
+		{"PasswordRejected", ErrPasswordRejected, IsPasswordRejected},
+		{"PasswordlessLoginDisabled", ErrPasswordlessLoginDisabled, IsPasswordlessLoginDisabled},
+		{"SendingQuotaAboveKYCCap", ErrSendingQuotaAboveKYCCap, IsSendingQuotaAboveKYCCap},
+		{"CompanyProfileNotApplicable", ErrCompanyProfileNotApplicable, IsCompanyProfileNotApplicable},
+		{"ProfileChangeNotFound", ErrProfileChangeNotFound, IsProfileChangeNotFound},
+		{"ProfileChangeNotPending", ErrProfileChangeNotPending, IsProfileChangeNotPending},
+		{"ProfileChangeRejectionReasonNeeded", ErrProfileChangeRejectionReasonNeeded, IsProfileChangeRejectionReasonNeeded},
+		{"ShebaInquiryNotConfigured", ErrShebaInquiryNotConfigured, IsShebaInquiryNotConfigured},
+		{"ShebaInquiryUnavailable", ErrShebaInquiryUnavailable, IsShebaInquiryUnavailable},
+		{"AgencyShebaNotVerified", ErrAgencyShebaNotVerified, IsAgencyShebaNotVerified},
+		{"AgencyPayableNotFound", ErrAgencyPayableNotFound, IsAgencyPayableNotFound},
+		{"AgencyPayableAlreadyPaid", ErrAgencyPayableAlreadyPaid, IsAgencyPayableAlreadyPaid},
+		{"AtipayTerminalNotConfigured", ErrAtipayTerminalNotConfigured, IsAtipayTerminalNotConfigured},
+		{"WalletChargeMethodInvalid", ErrWalletChargeMethodInvalid, IsWalletChargeMethodInvalid},
+		{"WalletChargeDetailsRequired", ErrWalletChargeDetailsRequired, IsWalletChargeDetailsRequired},
+		{"AtipayReportDateInvalid", ErrAtipayReportDateInvalid, IsAtipayReportDateInvalid},
+		{"AtipayReportFileInvalid", ErrAtipayReportColumnsMissing, IsAtipayReportFileInvalid},
+		{"AtipayReportFetcherUnavailable", ErrAtipayReportFetcherUnavailable, IsAtipayReportFetcherUnavailable},
+		{"SandboxRealPayment", ErrSandboxRealPayment, IsSandboxRealPayment},
+		{"SandboxNotEnabled", ErrSandboxNotEnabled, IsSandboxNotEnabled},
+		{"SandboxCustomerHasHistory", ErrSandboxCustomerHasHistory, IsSandboxCustomerHasHistory},
+		{"RecordKindInvalid", ErrRecordKindInvalid, IsRecordKindInvalid},
+		{"RecordNotFound", ErrRecordNotFound, IsRecordNotFound},
+		{"RecordNotDeletable", ErrRecordNotDeletable, IsRecordNotDeletable},
+		{"RecordAlreadyDeleted", ErrRecordAlreadyDeleted, IsRecordAlreadyDeleted},
+		{"RecordNotDeleted", ErrRecordNotDeleted, IsRecordNotDeleted},
+		{"TimelineCategoryInvalid", ErrTimelineCategoryInvalid, IsTimelineCategoryInvalid},
+		{"TimelineCursorInvalid", ErrTimelineCursorInvalid, IsTimelineCursorInvalid},
+		{"ListQueryInvalid", ErrListQueryInvalid, IsListQueryInvalid},
+		{"TelegramNotConfigured", ErrTelegramNotConfigured, IsTelegramNotConfigured},
+		{"TelegramLinkNotFound", ErrTelegramLinkNotFound, IsTelegramLinkNotFound},
+		{"TelegramCodeInvalid", ErrTelegramCodeInvalid, IsTelegramCodeInvalid},
+		{"TelegramLinkLocked", ErrTelegramLinkLocked, IsTelegramLinkLocked},
+		{"TelegramWebhookSecretInvalid", ErrTelegramWebhookSecretInvalid, IsTelegramWebhookSecretInvalid},
+		{"PushNotConfigured", ErrPushNotConfigured, IsPushNotConfigured},
+		{"PushDeviceNotFound", ErrPushDeviceNotFound, IsPushDeviceNotFound},
+		{"LineNumberDeleted", ErrLineNumberDeleted, IsLineNumberDeleted},
 	}
 
 	for _, tc := range cases {
@@ -0,0 +1,122 @@
+package businessflow
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/repository"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+)
+
+// FrozenBudgetAuditFlow cross-checks campaign budgets held in frozen balance.
+//
+// A campaign waiting for approval holds its latest budget reservation frozen;
+// every other campaign holds nothing, since approval moves the budget to
+// spent and rejection, cancellation and expiry move it back. What a campaign
+// holds is read from its transactions' balance before/after, and the sum of
+// a wallet's campaign holdings must match the frozen balance of its latest
+// snapshot. Sandbox customers are skipped.
+type FrozenBudgetAuditFlow interface {
+	Audit(ctx context.Context) (*dto.AdminFrozenBudgetAuditResponse, error)
+}
+
+type FrozenBudgetAuditFlowImpl struct {
+	transactionRepo repository.TransactionRepository
+}
+
+func NewFrozenBudgetAuditFlow(transactionRepo repository.TransactionRepository) FrozenBudgetAuditFlow {
+	return &FrozenBudgetAuditFlowImpl{transactionRepo: transactionRepo}
+}
+
+// Audit lists every frozen budget inconsistency
+func (f *FrozenBudgetAuditFlowImpl) Audit(ctx context.Context) (*dto.AdminFrozenBudgetAuditResponse, error) {
+	checkedAt := utils.UTCNow()
+	campaigns, err := f.transactionRepo.CampaignFrozenLedgers(ctx)
+	if err != nil {
+		return nil, NewBusinessError("FROZEN_BUDGET_AUDIT_FAILED", "Failed to read campaign frozen budgets", err)
+	}
+	wallets, err := f.transactionRepo.WalletFrozenLedgers(ctx)
+	if err != nil {
+		return nil, NewBusinessError("FROZEN_BUDGET_AUDIT_FAILED", "Failed to read wallet frozen balances", err)
+	}
+
+	issues := frozenBudgetIssues(campaigns, wallets)
+	return &dto.AdminFrozenBudgetAuditResponse{
+		Message:          fmt.Sprintf("Found %d frozen budget inconsistencies", len(issues)),
+		CheckedAt:        checkedAt,
+		CampaignsChecked: len(campaigns),
+		WalletsChecked:   len(wallets),
+		Issues:           issues,
+	}, nil
+}
+
+// frozenBudgetIssues compares the campaign and wallet ledgers
+func frozenBudgetIssues(campaigns []*repository.CampaignFrozenLedger, wallets []*repository.WalletFrozenLedger) []dto.FrozenBudgetIssue {
+	issues := make([]dto.FrozenBudgetIssue, 0)
+	holdingByWallet := make(map[uint][]uint)
+	for _, c := range campaigns {
+		if c.WalletID != nil && c.NetFrozen != 0 {
+			holdingByWallet[*c.WalletID] = append(holdingByWallet[*c.WalletID], c.CampaignID)
+		}
+
+		issue := dto.FrozenBudgetIssue{
+			CustomerID:               c.CustomerID,
+			WalletID:                 c.WalletID,
+			CampaignID:               utils.ToPtr(c.CampaignID),
+			CampaignUUID:             c.CampaignUUID,
+			CampaignStatus:           c.Status,
+			ReservationUUID:          c.ReservationUUID,
+			ReservationCorrelationID: c.ReservationCorrelationID,
+			Transactions:             c.Transactions,
+			LastTransactionAt:        c.LastTransactionAt,
+			LedgerFrozen:             c.NetFrozen,
+		}
+		waiting := c.Status == string(models.CampaignStatusWaitingForApproval)
+		if waiting && c.ReservedAmount == nil {
+			issue.Kind = dto.FrozenBudgetIssueMissingReservation
+			issue.Difference = c.NetFrozen
+			issue.Detail = fmt.Sprintf("Campaign is waiting for approval without a budget reservation; its transactions hold %d frozen", c.NetFrozen)
+			issues = append(issues, issue)
+			continue
+		}
+
+		var expected int64
+		if waiting {
+			expected = int64(*c.ReservedAmount)
+		}
+		if c.NetFrozen == expected {
+			continue
+		}
+		issue.Kind = dto.FrozenBudgetIssueCampaignMismatch
+		issue.ExpectedFrozen = utils.ToPtr(expected)
+		issue.Difference = c.NetFrozen - expected
+		if waiting {
+			issue.Detail = fmt.Sprintf("Campaign transactions hold %d frozen but its reservation is %d", c.NetFrozen, expected)
+		} else {
+			issue.Detail = fmt.Sprintf("Campaign is %s but its transactions still hold %d frozen", c.Status, c.NetFrozen)
+		}
+		issues = append(issues, issue)
+	}
+
+	for _, w := range wallets {
+		if int64(w.FrozenBalance) == w.CampaignFrozen {
+			continue
+		}
+		issues = append(issues, dto.FrozenBudgetIssue{
+			Kind:          dto.FrozenBudgetIssueWalletMismatch,
+			CustomerID:    w.CustomerID,
+			WalletID:      utils.ToPtr(w.WalletID),
+			WalletUUID:    w.WalletUUID,
+			LedgerFrozen:  w.CampaignFrozen,
+			BalanceFrozen: utils.ToPtr(w.FrozenBalance),
+			SnapshotID:    utils.ToPtr(w.SnapshotID),
+			SnapshotAt:    utils.ToPtr(w.SnapshotAt),
+			CampaignIDs:   holdingByWallet[w.WalletID],
+			Difference:    int64(w.FrozenBalance) - w.CampaignFrozen,
+			Detail:        fmt.Sprintf("Latest balance snapshot holds %d frozen but campaign transactions add up to %d", w.FrozenBalance, w.CampaignFrozen),
+		})
+	}
+	return issues
+}
@@ -0,0 +1,54 @@
+package businessflow
+
+import (
+	"testing"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/repository"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+)
+
+func TestFrozenBudgetIssues(t *testing.T) {
+	t.Parallel()
+
+	waiting := string(models.CampaignStatusWaitingForApproval)
+	campaigns := []*repository.CampaignFrozenLedger{
+		{CampaignID: 1, CustomerID: 10, WalletID: utils.ToPtr(uint(100)), Status: waiting, NetFrozen: 500, ReservedAmount: utils.ToPtr(uint64(500))},
+		{CampaignID: 2, CustomerID: 10, WalletID: utils.ToPtr(uint(100)), Status: waiting, NetFrozen: 300, ReservedAmount: utils.ToPtr(uint64(400))},
+		{CampaignID: 3, CustomerID: 10, WalletID: utils.ToPtr(uint(100)), Status: string(models.CampaignStatusRejected), NetFrozen: 200},
+		{CampaignID: 4, CustomerID: 11, WalletID: utils.ToPtr(uint(101)), Status: waiting},
+	}
+	wallets := []*repository.WalletFrozenLedger{
+		{WalletID: 100, CustomerID: 10, FrozenBalance: 1000, CampaignFrozen: 1000},
+		{WalletID: 101, CustomerID: 11, FrozenBalance: 250, CampaignFrozen: 0},
+	}
+
+	issues := frozenBudgetIssues(campaigns, wallets)
+	want := []struct {
+		kind       string
+		campaignID uint
+		walletID   uint
+		difference int64
+	}{
+		{kind: dto.FrozenBudgetIssueCampaignMismatch, campaignID: 2, walletID: 100, difference: -100},
+		{kind: dto.FrozenBudgetIssueCampaignMismatch, campaignID: 3, walletID: 100, difference: 200},
+		{kind: dto.FrozenBudgetIssueMissingReservation, campaignID: 4, walletID: 101, difference: 0},
+		{kind: dto.FrozenBudgetIssueWalletMismatch, walletID: 101, difference: 250},
+	}
+	if len(issues) != len(want) {
+		t.Fatalf("got %d issues, want %d: %+v", len(issues), len(want), issues)
+	}
+	for i, w := range want {
+		got := issues[i]
+		if got.Kind != w.kind || got.Difference != w.difference || got.WalletID == nil || *got.WalletID != w.walletID {
+			t.Fatalf("issue %d = %+v, want %+v", i, got, w)
+		}
+		if w.campaignID != 0 && (got.CampaignID == nil || *got.CampaignID != w.campaignID) {
+			t.Fatalf("issue %d campaign = %v, want %d", i, got.CampaignID, w.campaignID)
+		}
+	}
+	if got := issues[1].ExpectedFrozen; got == nil || *got != 0 {
+		t.Fatalf("rejected campaign expected frozen = %v, want 0", got)
+	}
+}
@@ -0,0 +1,374 @@
+package businessflow
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/app/services"
+	"github.com/amirphl/Yamata-no-Orochi/config"
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/repository"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/google/uuid"
+)
+
+// jobStaleAfter is how long a running job may go without finishing before
+// another worker assumes its worker died and runs it again
+const jobStaleAfter = 30 * time.Minute
+
+// JobHandler runs one job with its JSON payload. A returned error makes the
+// queue retry the job after a backoff until it runs out of attempts.
+type JobHandler func(ctx context.Context, payload json.RawMessage) error
+
+// JobQueue persists background work so it survives restarts and is retried
+// when it fails. Enqueued inside a transaction, a job is only created if the
+// transaction commits.
+type JobQueue interface {
+	Enqueue(ctx context.Context, jobType string, payload any) error
+	Schedule(ctx context.Context, jobType string, payload any, runAt time.Time) error
+}
+
+// JobQueueFlow runs queued jobs with the handlers registered for their type
+// and lets admins inspect failed jobs and requeue dead ones
+type JobQueueFlow interface {
+	JobQueue
+	Register(jobType string, handler JobHandler)
+	ProcessNextJob(ctx context.Context) (bool, error)
+	PurgeSucceededJobs(ctx context.Context) (int64, error)
+	ListJobs(ctx context.Context, filter dto.AdminListJobsFilter) (*dto.AdminListJobsResponse, error)
+	GetJob(ctx context.Context, id uuid.UUID) (*dto.AdminJobResponse, error)
+	RequeueJob(ctx context.Context, id uuid.UUID) (*dto.AdminJobResponse, error)
+	CancelJob(ctx context.Context, id uuid.UUID) (*dto.AdminJobResponse, error)
+}
+
+type JobQueueFlowImpl struct {
+	jobRepo   repository.JobRepository
+	auditRepo repository.AuditLogRepository
+	cfg       config.JobQueueConfig
+
+	mu       sync.RWMutex
+	handlers map[string]JobHandler
+}
+
+func NewJobQueueFlow(
+	jobRepo repository.JobRepository,
+	auditRepo repository.AuditLogRepository,
+	cfg config.JobQueueConfig,
+) JobQueueFlow {
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 5
+	}
+	if cfg.BackoffBase <= 0 {
+		cfg.BackoffBase = 30 * time.Second
+	}
+	if cfg.BackoffMax < cfg.BackoffBase {
+		cfg.BackoffMax = cfg.BackoffBase
+	}
+	return &JobQueueFlowImpl{
+		jobRepo:   jobRepo,
+		auditRepo: auditRepo,
+		cfg:       cfg,
+		handlers:  map[string]JobHandler{},
+	}
+}
+
+// Register sets the handler of a job type. Jobs of a type without a handler
+// fail and end up dead.
+func (f *JobQueueFlowImpl) Register(jobType string, handler JobHandler) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.handlers[jobType] = handler
+}
+
+// Enqueue queues a job to run as soon as a worker is free
+func (f *JobQueueFlowImpl) Enqueue(ctx context.Context, jobType string, payload any) error {
+	return f.Schedule(ctx, jobType, payload, utils.UTCNow())
+}
+
+// Schedule queues a job to run at runAt
+func (f *JobQueueFlowImpl) Schedule(ctx context.Context, jobType string, payload any, runAt time.Time) error {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("encode %s job payload: %w", jobType, err)
+	}
+	job := &models.Job{
+		UUID:        uuid.New(),
+		Type:        jobType,
+		Payload:     raw,
+		Status:      models.JobStatusPending,
+		MaxAttempts: f.cfg.MaxAttempts,
+		RunAt:       runAt.UTC(),
+	}
+	if err := f.jobRepo.Save(ctx, job); err != nil {
+		return fmt.Errorf("enqueue %s job: %w", jobType, err)
+	}
+	return nil
+}
+
+// ProcessNextJob claims the next due job and runs it. A failed job is
+// scheduled for another attempt or, out of attempts, marked dead. Reports
+// whether a job was claimed; the error is the job's failure, if any.
+func (f *JobQueueFlowImpl) ProcessNextJob(ctx context.Context) (bool, error) {
+	job, err := f.jobRepo.ClaimNext(ctx, utils.UTCNow().Add(-jobStaleAfter))
+	if err != nil {
+		return false, fmt.Errorf("claim job: %w", err)
+	}
+	if job == nil {
+		return false, nil
+	}
+
+	runErr := f.runJob(ctx, job)
+	settleJob(job, runErr, utils.UTCNow(), f.cfg.BackoffBase, f.cfg.BackoffMax)
+
+	// The job's own context may have expired; record the outcome regardless
+	saveCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+	defer cancel()
+	if err := f.jobRepo.Update(saveCtx, job); err != nil {
+		return true, fmt.Errorf("save %s job %s: %w", job.Type, job.UUID, err)
+	}
+	if runErr != nil {
+		return true, fmt.Errorf("%s job %s attempt %d/%d (%s): %w", job.Type, job.UUID, job.Attempts, job.MaxAttempts, job.Status, runErr)
+	}
+	return true, nil
+}
+
+func (f *JobQueueFlowImpl) runJob(ctx context.Context, job *models.Job) (err error) {
+	f.mu.RLock()
+	handler, ok := f.handlers[job.Type]
+	f.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("no handler registered for job type %q", job.Type)
+	}
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("job handler panicked: %v", recovered)
+		}
+	}()
+	return handler(ctx, job.Payload)
+}
+
+// settleJob records the outcome of an attempt on the job
+func settleJob(job *models.Job, runErr error, now time.Time, backoffBase, backoffMax time.Duration) {
+	job.LockedAt = nil
+	if runErr == nil {
+		job.Status = models.JobStatusSucceeded
+		job.CompletedAt = &now
+		job.LastError = nil
+		return
+	}
+	msg := runErr.Error()
+	job.LastError = &msg
+	if job.Attempts >= job.MaxAttempts {
+		job.Status = models.JobStatusDead
+		job.DeadAt = &now
+		return
+	}
+	job.Status = models.JobStatusPending
+	job.RunAt = now.Add(jobBackoff(job.Attempts, backoffBase, backoffMax))
+}
+
+// jobBackoff is the wait after the given failed attempt: base, doubling per
+// attempt, capped at max
+func jobBackoff(attempt int, base, max time.Duration) time.Duration {
+	wait := base
+	for i := 1; i < attempt; i++ {
+		wait *= 2
+		if wait >= max {
+			return max
+		}
+	}
+	return min(wait, max)
+}
+
+// PurgeSucceededJobs deletes the jobs that succeeded longer than the
+// retention period ago
+func (f *JobQueueFlowImpl) PurgeSucceededJobs(ctx context.Context) (int64, error) {
+	if f.cfg.Retention <= 0 {
+		return 0, nil
+	}
+	return f.jobRepo.DeleteSucceededBefore(ctx, utils.UTCNow().Add(-f.cfg.Retention))
+}
+
+// ListJobs lists jobs, newest first
+func (f *JobQueueFlowImpl) ListJobs(ctx context.Context, filter dto.AdminListJobsFilter) (*dto.AdminListJobsResponse, error) {
+	page := max(1, filter.Page)
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 10
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	offset := (page - 1) * limit
+
+	jf := models.JobFilter{}
+	if filter.Status != nil && *filter.Status != "" {
+		status := models.JobStatus(*filter.Status)
+		jf.Status = &status
+	}
+	if filter.Type != nil && *filter.Type != "" {
+		jf.Type = filter.Type
+	}
+
+	total, err := f.jobRepo.Count(ctx, jf)
+	if err != nil {
+		return nil, NewBusinessError("JOB_LIST_FAILED", "Failed to count jobs", err)
+	}
+	rows, err := f.jobRepo.ByFilter(ctx, jf, "id DESC", limit, offset)
+	if err != nil {
+		return nil, NewBusinessError("JOB_LIST_FAILED", "Failed to list jobs", err)
+	}
+
+	items := make([]dto.JobItem, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, jobItem(row))
+	}
+	return &dto.AdminListJobsResponse{
+		Message: "Jobs retrieved successfully",
+		Items:   items,
+		Pagination: dto.PaginationInfo{
+			Total:      total,
+			Page:       page,
+			Limit:      limit,
+			TotalPages: int((total + int64(limit) - 1) / int64(limit)),
+		},
+	}, nil
+}
+
+// GetJob returns a job with its payload and last error
+func (f *JobQueueFlowImpl) GetJob(ctx context.Context, id uuid.UUID) (*dto.AdminJobResponse, error) {
+	job, err := f.jobRepo.ByUUID(ctx, id)
+	if err != nil {
+		return nil, NewBusinessError("JOB_LOOKUP_FAILED", "Failed to get job", err)
+	}
+	if job == nil {
+		return nil, NewBusinessError("JOB_NOT_FOUND", "Job not found", ErrJobNotFound)
+	}
+	return &dto.AdminJobResponse{Message: "Job retrieved successfully", Job: jobItem(job)}, nil
+}
+
+// RequeueJob gives a dead job a fresh set of attempts, starting now
+func (f *JobQueueFlowImpl) RequeueJob(ctx context.Context, id uuid.UUID) (*dto.AdminJobResponse, error) {
+	meta := map[string]any{"job_uuid": id.String()}
+	job, err := f.jobRepo.ByUUID(ctx, id)
+	if err != nil {
+		return nil, NewBusinessError("JOB_REQUEUE_FAILED", "Failed to requeue job", err)
+	}
+	if job == nil {
+		return nil, NewBusinessError("JOB_NOT_FOUND", "Job not found", ErrJobNotFound)
+	}
+	meta["job_type"] = job.Type
+
+	requeued, err := f.jobRepo.Requeue(ctx, job.ID)
+	if err != nil {
+		logAdminAction(ctx, f.auditRepo, models.AuditActionAdminJobRequeued, "Job requeue failed", false, nil, meta, err)
+		return nil, NewBusinessError("JOB_REQUEUE_FAILED", "Failed to requeue job", err)
+	}
+	if !requeued {
+		return nil, NewBusinessError("JOB_NOT_REQUEUEABLE", "Only dead jobs can be requeued", ErrJobNotRequeuable)
+	}
+	logAdminAction(ctx, f.auditRepo, models.AuditActionAdminJobRequeued, "Job requeued", true, nil, meta, nil)
+
+	job, err = f.jobRepo.ByUUID(ctx, id)
+	if err != nil || job == nil {
+		return nil, NewBusinessError("JOB_REQUEUE_FAILED", "Failed to load requeued job", err)
+	}
+	return &dto.AdminJobResponse{Message: "Job requeued successfully", Job: jobItem(job)}, nil
+}
+
+// CancelJob stops a pending or dead job from ever running again
+func (f *JobQueueFlowImpl) CancelJob(ctx context.Context, id uuid.UUID) (*dto.AdminJobResponse, error) {
+	meta := map[string]any{"job_uuid": id.String()}
+	job, err := f.jobRepo.ByUUID(ctx, id)
+	if err != nil {
+		return nil, NewBusinessError("JOB_CANCEL_FAILED", "Failed to cancel job", err)
+	}
+	if job == nil {
+		return nil, NewBusinessError("JOB_NOT_FOUND", "Job not found", ErrJobNotFound)
+	}
+	meta["job_type"] = job.Type
+	meta["previous_status"] = job.Status
+
+	cancelled, err := f.jobRepo.Cancel(ctx, job.ID)
+	if err != nil {
+		logAdminAction(ctx, f.auditRepo, models.AuditActionAdminJobCancelled, "Job cancellation failed", false, nil, meta, err)
+		return nil, NewBusinessError("JOB_CANCEL_FAILED", "Failed to cancel job", err)
+	}
+	if !cancelled {
+		return nil, NewBusinessError("JOB_NOT_CANCELLABLE", "Only pending or dead jobs can be cancelled", ErrJobNotCancellable)
+	}
+	logAdminAction(ctx, f.auditRepo, models.AuditActionAdminJobCancelled, "Job cancelled", true, nil, meta, nil)
+
+	job, err = f.jobRepo.ByUUID(ctx, id)
+	if err != nil || job == nil {
+		return nil, NewBusinessError("JOB_CANCEL_FAILED", "Failed to load cancelled job", err)
+	}
+	return &dto.AdminJobResponse{Message: "Job cancelled successfully", Job: jobItem(job)}, nil
+}
+
+func jobItem(job *models.Job) dto.JobItem {
+	item := dto.JobItem{
+		UUID:        job.UUID.String(),
+		Type:        job.Type,
+		Status:      string(job.Status),
+		Attempts:    job.Attempts,
+		MaxAttempts: job.MaxAttempts,
+		RunAt:       job.RunAt.Format(time.RFC3339),
+		LastError:   job.LastError,
+		CreatedAt:   job.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:   job.UpdatedAt.Format(time.RFC3339),
+	}
+	if len(job.Payload) > 0 {
+		_ = json.Unmarshal(job.Payload, &item.Payload)
+	}
+	if job.CompletedAt != nil {
+		item.CompletedAt = utils.ToPtr(job.CompletedAt.Format(time.RFC3339))
+	}
+	if job.DeadAt != nil {
+		item.DeadAt = utils.ToPtr(job.DeadAt.Format(time.RFC3339))
+	}
+	if job.CancelledAt != nil {
+		item.CancelledAt = utils.ToPtr(job.CancelledAt.Format(time.RFC3339))
+	}
+	return item
+}
+
+// NewSendSMSJobHandler sends the SMS of send_sms jobs
+func NewSendSMSJobHandler(notifier services.NotificationService) JobHandler {
+	return func(ctx context.Context, payload json.RawMessage) error {
+		var job models.SendSMSJob
+		if err := json.Unmarshal(payload, &job); err != nil {
+			return fmt.Errorf("decode send_sms payload: %w", err)
+		}
+		return notifier.SendSMS(ctx, job.Mobile, job.Message, job.CustomerID)
+	}
+}
+
+// NewSendEmailJobHandler sends the email of send_email jobs
+func NewSendEmailJobHandler(notifier services.NotificationService) JobHandler {
+	return func(ctx context.Context, payload json.RawMessage) error {
+		var job models.SendEmailJob
+		if err := json.Unmarshal(payload, &job); err != nil {
+			return fmt.Errorf("decode send_email payload: %w", err)
+		}
+		return notifier.SendEmail(job.To, job.Subject, job.Body)
+	}
+}
+
+// enqueueSMS queues one SMS per mobile; queueing is best-effort, like the
+// notifications it carries
+func enqueueSMS(ctx context.Context, jobs JobQueue, mobiles []string, message string) {
+	if jobs == nil {
+		return
+	}
+	for _, mobile := range mobiles {
+		if err := jobs.Enqueue(ctx, models.JobTypeSendSMS, models.SendSMSJob{Mobile: mobile, Message: message}); err != nil {
+			log.Printf("queue SMS notification: %v", err)
+		}
+	}
+}
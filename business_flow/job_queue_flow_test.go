@@ -0,0 +1,72 @@
+package businessflow
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/models"
+)
+
+func TestJobBackoff(t *testing.T) {
+	t.Parallel()
+
+	base, maxWait := 30*time.Second, 5*time.Minute
+	cases := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{4, 4 * time.Minute},
+		{5, 5 * time.Minute},
+		{40, 5 * time.Minute},
+	}
+	for _, tc := range cases {
+		if got := jobBackoff(tc.attempt, base, maxWait); got != tc.want {
+			t.Errorf("jobBackoff(%d) = %v, want %v", tc.attempt, got, tc.want)
+		}
+	}
+}
+
+func TestSettleJob(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	locked := now.Add(-time.Minute)
+
+	t.Run("success", func(t *testing.T) {
+		failure := "earlier failure"
+		job := &models.Job{Status: models.JobStatusRunning, Attempts: 2, MaxAttempts: 5, LockedAt: &locked, LastError: &failure}
+		settleJob(job, nil, now, time.Minute, time.Hour)
+		if job.Status != models.JobStatusSucceeded || job.CompletedAt == nil || !job.CompletedAt.Equal(now) {
+			t.Fatalf("expected succeeded at %v, got %s at %v", now, job.Status, job.CompletedAt)
+		}
+		if job.LockedAt != nil || job.LastError != nil {
+			t.Fatal("expected lock and last error to be cleared")
+		}
+	})
+
+	t.Run("retry", func(t *testing.T) {
+		job := &models.Job{Status: models.JobStatusRunning, Attempts: 2, MaxAttempts: 5, LockedAt: &locked}
+		settleJob(job, errors.New("provider down"), now, time.Minute, time.Hour)
+		if job.Status != models.JobStatusPending {
+			t.Fatalf("expected pending, got %s", job.Status)
+		}
+		if want := now.Add(2 * time.Minute); !job.RunAt.Equal(want) {
+			t.Fatalf("expected retry at %v, got %v", want, job.RunAt)
+		}
+		if job.LastError == nil || *job.LastError != "provider down" || job.LockedAt != nil {
+			t.Fatalf("expected last error recorded and lock cleared, got %v %v", job.LastError, job.LockedAt)
+		}
+	})
+
+	t.Run("dead", func(t *testing.T) {
+		job := &models.Job{Status: models.JobStatusRunning, Attempts: 5, MaxAttempts: 5, LockedAt: &locked}
+		settleJob(job, errors.New("provider down"), now, time.Minute, time.Hour)
+		if job.Status != models.JobStatusDead || job.DeadAt == nil || !job.DeadAt.Equal(now) {
+			t.Fatalf("expected dead at %v, got %s at %v", now, job.Status, job.DeadAt)
+		}
+	})
+}
@@ -0,0 +1,478 @@
+package businessflow
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/app/services"
+	"github.com/amirphl/Yamata-no-Orochi/app/storage"
+	"github.com/amirphl/Yamata-no-Orochi/config"
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/repository"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// maxKYCDocumentSize bounds a single uploaded document
+const maxKYCDocumentSize = 5 << 20
+
+// kycDocumentExtensions are the accepted document types, detected from the
+// file content rather than the name, and the extension they are stored with
+var kycDocumentExtensions = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"application/pdf": ".pdf",
+}
+
+// KYCFlow handles company document verification: customers upload their
+// registration documents and representative ID and submit them, and admins
+// review them. Companies are held to the unverified sending quota cap until
+// an admin verifies them.
+type KYCFlow interface {
+	UploadDocument(ctx context.Context, req *dto.UploadKYCDocumentRequest, file io.Reader, metadata *ClientMetadata) (*dto.UploadKYCDocumentResponse, error)
+	GetStatus(ctx context.Context, customerID uint) (*dto.KYCStatusResponse, error)
+	Submit(ctx context.Context, customerID uint, metadata *ClientMetadata) (*dto.SubmitKYCResponse, error)
+	AdminList(ctx context.Context, filter dto.AdminListKYCReviewsFilter) (*dto.AdminListKYCReviewsResponse, error)
+	AdminGetCustomer(ctx context.Context, customerID uint) (*dto.AdminGetCustomerKYCResponse, error)
+	AdminReview(ctx context.Context, req *dto.AdminReviewKYCRequest) (*dto.AdminReviewKYCResponse, error)
+}
+
+type KYCFlowImpl struct {
+	customerRepo repository.CustomerRepository
+	documentRepo repository.KYCDocumentRepository
+	auditRepo    repository.AuditLogRepository
+	// storage is nil while uploads are not configured
+	storage    storage.Store
+	scanner    services.FileScanner
+	storageCfg config.StorageConfig
+	kycCfg     config.KYCConfig
+	db         *gorm.DB
+}
+
+func NewKYCFlow(
+	customerRepo repository.CustomerRepository,
+	documentRepo repository.KYCDocumentRepository,
+	auditRepo repository.AuditLogRepository,
+	store storage.Store,
+	scanner services.FileScanner,
+	storageCfg config.StorageConfig,
+	kycCfg config.KYCConfig,
+	db *gorm.DB,
+) KYCFlow {
+	if scanner == nil {
+		scanner = services.NoopFileScanner{}
+	}
+	return &KYCFlowImpl{
+		customerRepo: customerRepo,
+		documentRepo: documentRepo,
+		auditRepo:    auditRepo,
+		storage:      store,
+		scanner:      scanner,
+		storageCfg:   storageCfg,
+		kycCfg:       kycCfg,
+		db:           db,
+	}
+}
+
+// UploadDocument scans and stores a document, replacing the customer's
+// previous document of the same kind. Documents can only change before they
+// are submitted or after they were rejected.
+func (f *KYCFlowImpl) UploadDocument(ctx context.Context, req *dto.UploadKYCDocumentRequest, file io.Reader, metadata *ClientMetadata) (*dto.UploadKYCDocumentResponse, error) {
+	if f.storage == nil {
+		return nil, NewBusinessError("UPLOADS_NOT_CONFIGURED", "Document uploads are not configured", ErrUploadsNotConfigured)
+	}
+	kind := models.KYCDocumentKind(req.Kind)
+	if !kind.Valid() {
+		return nil, NewBusinessError("VALIDATION_ERROR", "Unknown document kind", nil)
+	}
+	customer, err := f.kycCustomer(ctx, req.CustomerID)
+	if err != nil {
+		return nil, err
+	}
+	if !customer.KYC().AcceptsUploads() {
+		return nil, NewBusinessError("KYC_STATUS_CONFLICT", fmt.Sprintf("Documents cannot change while KYC is %s", customer.KYC()), ErrKYCStatusConflict)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(file, maxKYCDocumentSize+1))
+	if err != nil {
+		return nil, NewBusinessError("KYC_UPLOAD_FAILED", "Failed to read document", err)
+	}
+	if len(data) > maxKYCDocumentSize {
+		return nil, NewBusinessError("KYC_DOCUMENT_INVALID", ErrKYCDocumentTooLarge.Error(), ErrKYCDocumentTooLarge)
+	}
+	contentType := http.DetectContentType(data)
+	ext, ok := kycDocumentExtensions[contentType]
+	if len(data) == 0 || !ok {
+		return nil, NewBusinessError("KYC_DOCUMENT_INVALID", ErrKYCDocumentTypeInvalid.Error(), ErrKYCDocumentTypeInvalid)
+	}
+
+	if err := f.scanner.Scan(ctx, data); err != nil {
+		if errors.Is(err, services.ErrFileInfected) {
+			f.audit(ctx, &customer, models.AuditActionKYCDocumentUpload, "KYC document rejected by the virus scan", false, err, metadata)
+			return nil, NewBusinessError("KYC_DOCUMENT_INFECTED", ErrKYCDocumentInfected.Error(), fmt.Errorf("%w: %v", ErrKYCDocumentInfected, err))
+		}
+		return nil, NewBusinessError("KYC_SCAN_FAILED", "Failed to scan document", err)
+	}
+
+	sum := sha256.Sum256(data)
+	doc := &models.KYCDocument{
+		UUID:        uuid.New(),
+		CustomerID:  customer.ID,
+		Kind:        kind,
+		FileName:    kycFileName(req.FileName, ext),
+		ContentType: contentType,
+		FileSize:    int64(len(data)),
+		SHA256:      hex.EncodeToString(sum[:]),
+		CreatedAt:   utils.UTCNow(),
+	}
+	doc.StorageKey = fmt.Sprintf("kyc/%s/%s%s", customer.UUID, doc.UUID, ext)
+	if err := f.storage.Put(ctx, doc.StorageKey, contentType, data); err != nil {
+		return nil, NewBusinessError("KYC_UPLOAD_FAILED", "Failed to store document", err)
+	}
+
+	err = repository.WithTransaction(ctx, f.db, func(txCtx context.Context) error {
+		if _, err := f.documentRepo.Supersede(txCtx, customer.ID, kind, doc.CreatedAt); err != nil {
+			return err
+		}
+		return f.documentRepo.Save(txCtx, doc)
+	})
+	if err != nil {
+		if delErr := f.storage.Delete(ctx, doc.StorageKey); delErr != nil {
+			log.Printf("kyc: delete orphaned document %s: %v", doc.StorageKey, delErr)
+		}
+		return nil, NewBusinessError("KYC_UPLOAD_FAILED", "Failed to save document", err)
+	}
+	f.audit(ctx, &customer, models.AuditActionKYCDocumentUpload, fmt.Sprintf("KYC document %s uploaded", kind), true, nil, metadata)
+
+	return &dto.UploadKYCDocumentResponse{
+		Message:  "Document uploaded",
+		Document: f.documentItem(doc),
+	}, nil
+}
+
+// GetStatus returns the customer's KYC state with signed links to their
+// current documents
+func (f *KYCFlowImpl) GetStatus(ctx context.Context, customerID uint) (*dto.KYCStatusResponse, error) {
+	customer, err := f.kycCustomer(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+	docs, missing, err := f.currentDocuments(ctx, customer.ID)
+	if err != nil {
+		return nil, NewBusinessError("GET_KYC_STATUS_FAILED", "Failed to list documents", err)
+	}
+	res := &dto.KYCStatusResponse{
+		Message:         "KYC status retrieved successfully",
+		Status:          string(customer.KYC()),
+		SubmittedAt:     customer.KYCSubmittedAt,
+		ReviewedAt:      customer.KYCReviewedAt,
+		RejectionReason: customer.KYCRejectionReason,
+		Documents:       docs,
+		MissingKinds:    missing,
+	}
+	if customer.IsUnverifiedCompany() && (f.kycCfg.UnverifiedDailyLimit > 0 || f.kycCfg.UnverifiedMonthlyLimit > 0) {
+		res.QuotaCap = &dto.KYCQuotaCap{
+			DailyLimit:   kycCapLimit(f.kycCfg.UnverifiedDailyLimit),
+			MonthlyLimit: kycCapLimit(f.kycCfg.UnverifiedMonthlyLimit),
+		}
+	}
+	return res, nil
+}
+
+// Submit sends the customer's documents for review once every required kind
+// is uploaded
+func (f *KYCFlowImpl) Submit(ctx context.Context, customerID uint, metadata *ClientMetadata) (*dto.SubmitKYCResponse, error) {
+	customer, err := f.kycCustomer(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+	from := customer.KYC()
+	if !from.CanTransitionTo(models.KYCStatusPendingReview) {
+		return nil, NewBusinessError("KYC_STATUS_CONFLICT", fmt.Sprintf("Documents cannot be submitted while KYC is %s", from), ErrKYCStatusConflict)
+	}
+	_, missing, err := f.currentDocuments(ctx, customer.ID)
+	if err != nil {
+		return nil, NewBusinessError("SUBMIT_KYC_FAILED", "Failed to list documents", err)
+	}
+	if len(missing) > 0 {
+		msg := fmt.Sprintf("Upload the missing documents first: %s", strings.Join(missing, ", "))
+		return nil, NewBusinessError("KYC_DOCUMENTS_MISSING", msg, ErrKYCDocumentsMissing)
+	}
+
+	ok, err := f.customerRepo.TransitionKYCStatus(ctx, customer.ID, from, models.KYCStatusPendingReview, nil, nil)
+	if err != nil {
+		return nil, NewBusinessError("SUBMIT_KYC_FAILED", "Failed to submit documents", err)
+	}
+	if !ok {
+		return nil, NewBusinessError("KYC_STATUS_CONFLICT", "KYC status changed meanwhile; reload and try again", ErrKYCStatusConflict)
+	}
+	f.audit(ctx, &customer, models.AuditActionKYCSubmit, "KYC documents submitted for review", true, nil, metadata)
+
+	return &dto.SubmitKYCResponse{
+		Message:     "Documents submitted for review",
+		Status:      string(models.KYCStatusPendingReview),
+		SubmittedAt: utils.UTCNow(),
+	}, nil
+}
+
+// AdminList returns customers by KYC status, pending reviews by default,
+// longest waiting first
+func (f *KYCFlowImpl) AdminList(ctx context.Context, filter dto.AdminListKYCReviewsFilter) (*dto.AdminListKYCReviewsResponse, error) {
+	page, limit := walletTransferPage(filter.Page, filter.Limit)
+	status := models.KYCStatusPendingReview
+	if filter.Status != nil && *filter.Status != "" {
+		status = models.KYCStatus(*filter.Status)
+	}
+	cf := models.CustomerFilter{KYCStatus: &status}
+
+	total, err := f.customerRepo.Count(ctx, cf)
+	if err != nil {
+		return nil, NewBusinessError("ADMIN_LIST_KYC_FAILED", "Failed to count customers", err)
+	}
+	customers, err := f.customerRepo.ByFilter(ctx, cf, "kyc_submitted_at ASC NULLS LAST, id ASC", limit, (page-1)*limit)
+	if err != nil {
+		return nil, NewBusinessError("ADMIN_LIST_KYC_FAILED", "Failed to list customers", err)
+	}
+	items := make([]dto.AdminKYCReviewItem, 0, len(customers))
+	for _, c := range customers {
+		items = append(items, kycReviewItem(c))
+	}
+	return &dto.AdminListKYCReviewsResponse{
+		Message:    "KYC reviews retrieved successfully",
+		Items:      items,
+		Pagination: walletTransferPagination(total, page, limit),
+	}, nil
+}
+
+// AdminGetCustomer returns a customer's KYC state with signed links to
+// their current documents
+func (f *KYCFlowImpl) AdminGetCustomer(ctx context.Context, customerID uint) (*dto.AdminGetCustomerKYCResponse, error) {
+	customer, err := f.adminKYCCustomer(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+	docs, missing, err := f.currentDocuments(ctx, customer.ID)
+	if err != nil {
+		return nil, NewBusinessError("ADMIN_GET_KYC_FAILED", "Failed to list documents", err)
+	}
+	return &dto.AdminGetCustomerKYCResponse{
+		Message:         "Customer KYC retrieved successfully",
+		Customer:        kycReviewItem(customer),
+		ReviewerID:      customer.KYCReviewerID,
+		RejectionReason: customer.KYCRejectionReason,
+		Documents:       docs,
+		MissingKinds:    missing,
+	}, nil
+}
+
+// AdminReview verifies or rejects a customer's submitted documents. A
+// verified customer can still be rejected when their documents turn out to
+// be invalid, which caps their quota again.
+func (f *KYCFlowImpl) AdminReview(ctx context.Context, req *dto.AdminReviewKYCRequest) (*dto.AdminReviewKYCResponse, error) {
+	to := models.KYCStatusVerified
+	var reason *string
+	if req.Decision == "reject" {
+		to = models.KYCStatusRejected
+		if req.Reason == nil || strings.TrimSpace(*req.Reason) == "" {
+			return nil, NewBusinessError("KYC_REJECTION_REASON_REQUIRED", ErrKYCRejectionReasonNeeded.Error(), ErrKYCRejectionReasonNeeded)
+		}
+		reason = utils.ToPtr(strings.TrimSpace(*req.Reason))
+	}
+	customer, err := f.adminKYCCustomer(ctx, req.CustomerID)
+	if err != nil {
+		return nil, err
+	}
+	from := customer.KYC()
+	meta := map[string]any{"decision": req.Decision, "from": from, "reason": reason}
+	if !from.CanTransitionTo(to) {
+		err := NewBusinessError("KYC_STATUS_CONFLICT", fmt.Sprintf("KYC cannot move from %s to %s", from, to), ErrKYCStatusConflict)
+		logAdminAction(ctx, f.auditRepo, models.AuditActionAdminKYCReview, "Admin KYC review rejected", false, &customer.ID, meta, err)
+		return nil, err
+	}
+
+	var reviewerID *uint
+	if adminID, ok := adminIDFromContext(ctx); ok {
+		reviewerID = &adminID
+	}
+	ok, err := f.customerRepo.TransitionKYCStatus(ctx, customer.ID, from, to, reviewerID, reason)
+	if err == nil && !ok {
+		err = ErrKYCStatusConflict
+	}
+	if err != nil {
+		logAdminAction(ctx, f.auditRepo, models.AuditActionAdminKYCReview, "Admin KYC review failed", false, &customer.ID, meta, err)
+		if errors.Is(err, ErrKYCStatusConflict) {
+			return nil, NewBusinessError("KYC_STATUS_CONFLICT", "KYC status changed meanwhile; reload and try again", err)
+		}
+		return nil, NewBusinessError("ADMIN_REVIEW_KYC_FAILED", "Failed to save review", err)
+	}
+	logAdminAction(ctx, f.auditRepo, models.AuditActionAdminKYCReview, fmt.Sprintf("Admin set customer KYC to %s", to), true, &customer.ID, meta, nil)
+
+	return &dto.AdminReviewKYCResponse{
+		Message:    "KYC review saved",
+		CustomerID: customer.ID,
+		Status:     string(to),
+		ReviewedAt: utils.UTCNow(),
+	}, nil
+}
+
+// kycCustomer returns the active company customer going through KYC
+func (f *KYCFlowImpl) kycCustomer(ctx context.Context, customerID uint) (models.Customer, error) {
+	customer, err := getCustomer(ctx, f.customerRepo, customerID)
+	if err != nil {
+		return models.Customer{}, NewBusinessError("KYC_CUSTOMER_FAILED", "Failed to find customer", err)
+	}
+	if !customer.RequiresCompanyFields() {
+		return models.Customer{}, NewBusinessError("KYC_NOT_APPLICABLE", ErrKYCNotApplicable.Error(), ErrKYCNotApplicable)
+	}
+	return customer, nil
+}
+
+// adminKYCCustomer returns a company customer whether or not it is active
+func (f *KYCFlowImpl) adminKYCCustomer(ctx context.Context, customerID uint) (*models.Customer, error) {
+	customer, err := f.customerRepo.ByID(ctx, customerID)
+	if err != nil {
+		return nil, NewBusinessError("KYC_CUSTOMER_FAILED", "Failed to find customer", err)
+	}
+	if customer == nil {
+		return nil, NewBusinessError("CUSTOMER_NOT_FOUND", "Customer not found", ErrCustomerNotFound)
+	}
+	if !customer.RequiresCompanyFields() {
+		return nil, NewBusinessError("KYC_NOT_APPLICABLE", ErrKYCNotApplicable.Error(), ErrKYCNotApplicable)
+	}
+	return customer, nil
+}
+
+// currentDocuments returns the customer's current documents and the
+// required kinds they have not uploaded
+func (f *KYCFlowImpl) currentDocuments(ctx context.Context, customerID uint) ([]dto.KYCDocumentItem, []string, error) {
+	docs, err := f.documentRepo.Current(ctx, customerID)
+	if err != nil {
+		return nil, nil, err
+	}
+	uploaded := make(map[models.KYCDocumentKind]bool, len(docs))
+	items := make([]dto.KYCDocumentItem, 0, len(docs))
+	for _, doc := range docs {
+		uploaded[doc.Kind] = true
+		items = append(items, f.documentItem(doc))
+	}
+	missing := []string{}
+	for _, kind := range models.RequiredKYCDocumentKinds {
+		if !uploaded[kind] {
+			missing = append(missing, string(kind))
+		}
+	}
+	return items, missing, nil
+}
+
+// documentItem describes a document with a signed download link when
+// storage is configured
+func (f *KYCFlowImpl) documentItem(doc *models.KYCDocument) dto.KYCDocumentItem {
+	item := dto.KYCDocumentItem{
+		UUID:        doc.UUID.String(),
+		Kind:        string(doc.Kind),
+		FileName:    doc.FileName,
+		ContentType: doc.ContentType,
+		FileSize:    doc.FileSize,
+		SHA256:      doc.SHA256,
+		CreatedAt:   doc.CreatedAt,
+	}
+	if f.storage == nil {
+		return item
+	}
+	url, err := f.storage.SignedURL(doc.StorageKey, f.storageCfg.SignedURLTTL)
+	if err != nil {
+		log.Printf("kyc: sign download URL of %s: %v", doc.StorageKey, err)
+		return item
+	}
+	item.DownloadURL = url
+	item.DownloadURLExpiresAt = utils.ToPtr(utils.UTCNow().Add(f.storageCfg.SignedURLTTL))
+	return item
+}
+
+func (f *KYCFlowImpl) audit(ctx context.Context, customer *models.Customer, action, description string, success bool, err error, metadata *ClientMetadata) {
+	var errMsg *string
+	if err != nil {
+		errMsg = utils.ToPtr(err.Error())
+	}
+	_ = createAuditLog(ctx, f.auditRepo, customer, action, description, success, errMsg, metadata)
+}
+
+func kycReviewItem(c *models.Customer) dto.AdminKYCReviewItem {
+	return dto.AdminKYCReviewItem{
+		CustomerID:   c.ID,
+		CustomerUUID: c.UUID.String(),
+		CompanyName:  c.CompanyName,
+		NationalID:   c.NationalID,
+		Email:        c.Email,
+		Status:       string(c.KYC()),
+		SubmittedAt:  c.KYCSubmittedAt,
+		ReviewedAt:   c.KYCReviewedAt,
+	}
+}
+
+// kycFileName keeps the uploaded file's base name for display, with the
+// extension of its detected type
+func kycFileName(name, ext string) string {
+	base := filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	base = strings.TrimSpace(strings.TrimSuffix(base, filepath.Ext(base)))
+	if base == "" || base == "." || base == "/" {
+		base = "document"
+	}
+	if runes := []rune(base); len(runes) > 200 {
+		base = string(runes[:200])
+	}
+	return base + ext
+}
+
+func kycCapLimit(limit uint64) *uint64 {
+	if limit == 0 {
+		return nil
+	}
+	return &limit
+}
+
+// unverifiedQuota returns the quota an unverified company is held to: each
+// configured limit lowered to the KYC cap. Other customers keep their quota.
+func unverifiedQuota(customer *models.Customer, quota *models.CustomerSendingQuota, cfg config.KYCConfig) (*models.CustomerSendingQuota, bool) {
+	if customer == nil || !customer.IsUnverifiedCompany() || (cfg.UnverifiedDailyLimit == 0 && cfg.UnverifiedMonthlyLimit == 0) {
+		return quota, false
+	}
+	capped := &models.CustomerSendingQuota{CustomerID: customer.ID}
+	if quota != nil {
+		*capped = *quota
+	}
+	capped.DailyLimit = lowerLimit(capped.DailyLimit, cfg.UnverifiedDailyLimit)
+	capped.MonthlyLimit = lowerLimit(capped.MonthlyLimit, cfg.UnverifiedMonthlyLimit)
+	return capped, true
+}
+
+// lowerLimit returns the tighter of limit and ceiling; a nil limit is
+// unlimited and a zero ceiling is no ceiling
+func lowerLimit(limit *uint64, ceiling uint64) *uint64 {
+	if ceiling == 0 || (limit != nil && *limit <= ceiling) {
+		return limit
+	}
+	return &ceiling
+}
+
+// exceedsKYCCap reports whether limits set for an unverified company go
+// above the unverified cap; a nil limit is unlimited and so always does
+func exceedsKYCCap(customer *models.Customer, daily, monthly *uint64, cfg config.KYCConfig) bool {
+	if !customer.IsUnverifiedCompany() {
+		return false
+	}
+	above := func(limit *uint64, ceiling uint64) bool {
+		return ceiling > 0 && (limit == nil || *limit > ceiling)
+	}
+	return above(daily, cfg.UnverifiedDailyLimit) || above(monthly, cfg.UnverifiedMonthlyLimit)
+}
@@ -0,0 +1,288 @@
+package businessflow
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/app/services"
+	"github.com/amirphl/Yamata-no-Orochi/app/storage"
+	"github.com/amirphl/Yamata-no-Orochi/config"
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/repository"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+)
+
+type kycCustomerRepoStub struct {
+	repository.CustomerRepository
+	customer    *models.Customer
+	transitions []models.KYCStatus
+}
+
+func (r *kycCustomerRepoStub) ByID(_ context.Context, id uint) (*models.Customer, error) {
+	if r.customer == nil || r.customer.ID != id {
+		return nil, nil
+	}
+	c := *r.customer
+	return &c, nil
+}
+
+func (r *kycCustomerRepoStub) TransitionKYCStatus(_ context.Context, customerID uint, from, to models.KYCStatus, _ *uint, reason *string) (bool, error) {
+	if r.customer.ID != customerID || r.customer.KYC() != from {
+		return false, nil
+	}
+	r.customer.KYCStatus = to
+	r.customer.KYCRejectionReason = reason
+	r.transitions = append(r.transitions, to)
+	return true, nil
+}
+
+type kycDocumentRepoStub struct {
+	repository.KYCDocumentRepository
+	current []*models.KYCDocument
+}
+
+func (r *kycDocumentRepoStub) Current(context.Context, uint) ([]*models.KYCDocument, error) {
+	return r.current, nil
+}
+
+type kycAuditStub struct {
+	repository.AuditLogRepository
+	actions []string
+}
+
+func (r *kycAuditStub) Save(_ context.Context, log *models.AuditLog) error {
+	r.actions = append(r.actions, log.Action)
+	return nil
+}
+
+type memoryStorage struct {
+	objects map[string][]byte
+}
+
+func (s *memoryStorage) Put(_ context.Context, key, _ string, data []byte) error {
+	s.objects[key] = data
+	return nil
+}
+
+func (s *memoryStorage) Get(_ context.Context, key string) ([]byte, string, error) {
+	data, ok := s.objects[key]
+	if !ok {
+		return nil, "", storage.ErrNotFound
+	}
+	return data, "", nil
+}
+
+func (s *memoryStorage) Delete(_ context.Context, key string) error {
+	delete(s.objects, key)
+	return nil
+}
+
+func (s *memoryStorage) SignedURL(key string, _ time.Duration) (string, error) {
+	return "https://storage.test/" + key + "?sig", nil
+}
+
+func (s *memoryStorage) ApplyLifecycle(context.Context, []storage.Rule) error { return nil }
+
+type scannerFunc func([]byte) error
+
+func (f scannerFunc) Scan(_ context.Context, data []byte) error { return f(data) }
+
+func kycCompany(status models.KYCStatus) *models.Customer {
+	return &models.Customer{
+		ID:          5,
+		IsActive:    utils.ToPtr(true),
+		AccountType: models.AccountType{TypeName: models.AccountTypeIndependentCompany},
+		KYCStatus:   status,
+	}
+}
+
+func newTestKYCFlow(customer *models.Customer, docs ...*models.KYCDocument) (*KYCFlowImpl, *kycCustomerRepoStub, *kycAuditStub, *memoryStorage) {
+	customers := &kycCustomerRepoStub{customer: customer}
+	audit := &kycAuditStub{}
+	store := &memoryStorage{objects: map[string][]byte{}}
+	flow := NewKYCFlow(customers, &kycDocumentRepoStub{current: docs}, audit, store, nil,
+		config.StorageConfig{SignedURLTTL: time.Minute}, config.KYCConfig{UnverifiedDailyLimit: 100}, nil).(*KYCFlowImpl)
+	return flow, customers, audit, store
+}
+
+func TestKYCStatusTransitions(t *testing.T) {
+	t.Parallel()
+
+	allowed := map[models.KYCStatus][]models.KYCStatus{
+		models.KYCStatusNotSubmitted:  {models.KYCStatusPendingReview},
+		models.KYCStatusPendingReview: {models.KYCStatusVerified, models.KYCStatusRejected},
+		models.KYCStatusVerified:      {models.KYCStatusRejected},
+		models.KYCStatusRejected:      {models.KYCStatusPendingReview},
+	}
+	all := []models.KYCStatus{models.KYCStatusNotSubmitted, models.KYCStatusPendingReview, models.KYCStatusVerified, models.KYCStatusRejected}
+	for _, from := range all {
+		for _, to := range all {
+			want := false
+			for _, next := range allowed[from] {
+				want = want || next == to
+			}
+			if got := from.CanTransitionTo(to); got != want {
+				t.Errorf("%s -> %s: got %v, want %v", from, to, got, want)
+			}
+		}
+	}
+}
+
+func TestKYCUploadDocumentRejections(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	pdf := []byte("%PDF-1.7\n1 0 obj\n")
+	req := func() *dto.UploadKYCDocumentRequest {
+		return &dto.UploadKYCDocumentRequest{CustomerID: 5, Kind: string(models.KYCDocumentRegistrationCertificate), FileName: "reg.pdf"}
+	}
+
+	disabled, _, _, _ := newTestKYCFlow(kycCompany(""))
+	disabled.storage = nil
+	if _, err := disabled.UploadDocument(ctx, req(), bytes.NewReader(pdf), nil); !IsUploadsNotConfigured(err) {
+		t.Fatalf("expected uploads not configured, got %v", err)
+	}
+
+	individual := kycCompany("")
+	individual.AccountType.TypeName = models.AccountTypeIndividual
+	flow, _, _, _ := newTestKYCFlow(individual)
+	if _, err := flow.UploadDocument(ctx, req(), bytes.NewReader(pdf), nil); !IsKYCNotApplicable(err) {
+		t.Fatalf("expected individuals to be rejected, got %v", err)
+	}
+
+	flow, _, _, _ = newTestKYCFlow(kycCompany(models.KYCStatusPendingReview))
+	if _, err := flow.UploadDocument(ctx, req(), bytes.NewReader(pdf), nil); !IsKYCStatusConflict(err) {
+		t.Fatalf("expected documents under review to be frozen, got %v", err)
+	}
+
+	flow, _, audit, store := newTestKYCFlow(kycCompany(models.KYCStatusRejected))
+	if _, err := flow.UploadDocument(ctx, req(), bytes.NewReader(bytes.Repeat([]byte("a"), maxKYCDocumentSize+1)), nil); !errors.Is(err, ErrKYCDocumentTooLarge) {
+		t.Fatalf("expected a size error, got %v", err)
+	}
+	if _, err := flow.UploadDocument(ctx, req(), strings.NewReader("just some text"), nil); !errors.Is(err, ErrKYCDocumentTypeInvalid) {
+		t.Fatalf("expected a type error, got %v", err)
+	}
+	flow.scanner = scannerFunc(func([]byte) error { return fmt.Errorf("%w: Eicar-Signature", services.ErrFileInfected) })
+	if _, err := flow.UploadDocument(ctx, req(), bytes.NewReader(pdf), nil); !IsKYCDocumentInfected(err) {
+		t.Fatalf("expected an infected file error, got %v", err)
+	}
+	if len(store.objects) != 0 {
+		t.Fatalf("rejected files must not be stored, got %v", store.objects)
+	}
+	if len(audit.actions) != 1 || audit.actions[0] != models.AuditActionKYCDocumentUpload {
+		t.Fatalf("expected the infected upload to be audited, got %v", audit.actions)
+	}
+}
+
+func TestKYCSubmit(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	registration := &models.KYCDocument{Kind: models.KYCDocumentRegistrationCertificate, StorageKey: "kyc/a/reg.pdf"}
+	representative := &models.KYCDocument{Kind: models.KYCDocumentRepresentativeID, StorageKey: "kyc/a/id.jpg"}
+
+	flow, customers, _, _ := newTestKYCFlow(kycCompany(""), registration)
+	_, err := flow.Submit(ctx, 5, nil)
+	if !IsKYCDocumentsMissing(err) || !strings.Contains(err.Error(), string(models.KYCDocumentRepresentativeID)) {
+		t.Fatalf("expected the missing representative ID to be reported, got %v", err)
+	}
+
+	flow, customers, audit, _ := newTestKYCFlow(kycCompany(models.KYCStatusRejected), registration, representative)
+	res, err := flow.Submit(ctx, 5, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Status != string(models.KYCStatusPendingReview) || customers.customer.KYCStatus != models.KYCStatusPendingReview {
+		t.Fatalf("expected pending review, got %s", res.Status)
+	}
+	if len(audit.actions) != 1 || audit.actions[0] != models.AuditActionKYCSubmit {
+		t.Fatalf("expected the submission to be audited, got %v", audit.actions)
+	}
+	if _, err := flow.Submit(ctx, 5, nil); !IsKYCStatusConflict(err) {
+		t.Fatalf("expected a second submission to conflict, got %v", err)
+	}
+
+	status, err := flow.GetStatus(ctx, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(status.Documents) != 2 || status.Documents[0].DownloadURL == "" || len(status.MissingKinds) != 0 {
+		t.Fatalf("expected both documents with signed links, got %+v", status)
+	}
+	if status.QuotaCap == nil || status.QuotaCap.DailyLimit == nil || *status.QuotaCap.DailyLimit != 100 || status.QuotaCap.MonthlyLimit != nil {
+		t.Fatalf("expected the unverified daily cap, got %+v", status.QuotaCap)
+	}
+}
+
+func TestKYCAdminReview(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	flow, customers, audit, _ := newTestKYCFlow(kycCompany(models.KYCStatusPendingReview))
+
+	if _, err := flow.AdminReview(ctx, &dto.AdminReviewKYCRequest{CustomerID: 5, Decision: "reject", Reason: utils.ToPtr("  ")}); !IsKYCRejectionReasonNeeded(err) {
+		t.Fatalf("expected a reason to be required, got %v", err)
+	}
+	res, err := flow.AdminReview(ctx, &dto.AdminReviewKYCRequest{CustomerID: 5, Decision: "reject", Reason: utils.ToPtr("Gazette is unreadable")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Status != string(models.KYCStatusRejected) || *customers.customer.KYCRejectionReason != "Gazette is unreadable" {
+		t.Fatalf("expected a rejection with its reason, got %+v", customers.customer)
+	}
+	if _, err := flow.AdminReview(ctx, &dto.AdminReviewKYCRequest{CustomerID: 5, Decision: "approve"}); !IsKYCStatusConflict(err) {
+		t.Fatalf("expected approving without a resubmission to conflict, got %v", err)
+	}
+	if len(audit.actions) != 2 || audit.actions[0] != models.AuditActionAdminKYCReview {
+		t.Fatalf("expected both reviews to be audited, got %v", audit.actions)
+	}
+
+	customers.customer.KYCStatus = models.KYCStatusPendingReview
+	if _, err := flow.AdminReview(ctx, &dto.AdminReviewKYCRequest{CustomerID: 5, Decision: "approve"}); err != nil {
+		t.Fatal(err)
+	}
+	if customers.customer.KYCStatus != models.KYCStatusVerified || customers.customer.IsUnverifiedCompany() {
+		t.Fatalf("expected the customer to be verified, got %s", customers.customer.KYCStatus)
+	}
+}
+
+func TestUnverifiedQuota(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.KYCConfig{UnverifiedDailyLimit: 100, UnverifiedMonthlyLimit: 1000}
+	company := kycCompany(models.KYCStatusPendingReview)
+
+	capped, ok := unverifiedQuota(company, nil, cfg)
+	if !ok || *capped.DailyLimit != 100 || *capped.MonthlyLimit != 1000 {
+		t.Fatalf("expected an unlimited company to get the cap, got %+v", capped)
+	}
+	quota := &models.CustomerSendingQuota{DailyLimit: utils.ToPtr(uint64(50)), MonthlyLimit: utils.ToPtr(uint64(5000))}
+	capped, _ = unverifiedQuota(company, quota, cfg)
+	if *capped.DailyLimit != 50 || *capped.MonthlyLimit != 1000 || *quota.MonthlyLimit != 5000 {
+		t.Fatalf("expected the tighter limit of each period without changing the stored quota, got %+v", capped)
+	}
+
+	verified := kycCompany(models.KYCStatusVerified)
+	if got, ok := unverifiedQuota(verified, nil, cfg); ok || got != nil {
+		t.Fatalf("verified companies must keep their quota, got %+v", got)
+	}
+	if got, ok := unverifiedQuota(company, nil, config.KYCConfig{}); ok || got != nil {
+		t.Fatalf("no cap is configured, got %+v", got)
+	}
+
+	if !exceedsKYCCap(company, nil, utils.ToPtr(uint64(1000)), cfg) {
+		t.Fatal("an unlimited daily quota must exceed the cap")
+	}
+	if exceedsKYCCap(company, utils.ToPtr(uint64(100)), utils.ToPtr(uint64(900)), cfg) {
+		t.Fatal("limits within the cap must be accepted")
+	}
+	if exceedsKYCCap(verified, nil, nil, cfg) {
+		t.Fatal("verified companies are not capped")
+	}
+}
@@ -10,26 +10,32 @@ import (
 	"github.com/amirphl/Yamata-no-Orochi/repository"
 	"github.com/amirphl/Yamata-no-Orochi/utils"
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"gorm.io/gorm"
 )
 
 // AdminLineNumberFlow handles admin operations on line numbers
 type AdminLineNumberFlow interface {
 	Create(ctx context.Context, req *dto.AdminCreateLineNumberRequest, metadata *ClientMetadata) (*dto.AdminLineNumberDTO, error)
-	ListAll(ctx context.Context, metadata *ClientMetadata) ([]*dto.AdminLineNumberDTO, error)
+	ListAll(ctx context.Context, includeDeleted bool, metadata *ClientMetadata) ([]*dto.AdminLineNumberDTO, error)
 	UpdateBatch(ctx context.Context, req *dto.AdminUpdateLineNumbersRequest, metadata *ClientMetadata) error
 	GetReport(ctx context.Context, metadata *ClientMetadata) ([]*dto.AdminLineNumberReportItem, error)
+	ListTiers(ctx context.Context, metadata *ClientMetadata) ([]*dto.AdminLineNumberTierDTO, error)
+	CreateTier(ctx context.Context, req *dto.AdminCreateLineNumberTierRequest, metadata *ClientMetadata) (*dto.AdminLineNumberTierDTO, error)
+	UpdateTier(ctx context.Context, req *dto.AdminUpdateLineNumberTierRequest, metadata *ClientMetadata) (*dto.AdminLineNumberTierDTO, error)
 }
 
 type AdminLineNumberFlowImpl struct {
 	lineRepo  repository.LineNumberRepository
+	tierRepo  repository.LineNumberTierRepository
 	db        *gorm.DB
 	auditRepo repository.AuditLogRepository
 }
 
-func NewAdminLineNumberFlow(lineRepo repository.LineNumberRepository, db *gorm.DB, auditRepo repository.AuditLogRepository) AdminLineNumberFlow {
+func NewAdminLineNumberFlow(lineRepo repository.LineNumberRepository, tierRepo repository.LineNumberTierRepository, db *gorm.DB, auditRepo repository.AuditLogRepository) AdminLineNumberFlow {
 	return &AdminLineNumberFlowImpl{
 		lineRepo:  lineRepo,
+		tierRepo:  tierRepo,
 		db:        db,
 		auditRepo: auditRepo,
 	}
@@ -61,6 +67,12 @@ func (f *AdminLineNumberFlowImpl) Create(ctx context.Context, req *dto.AdminCrea
 		existing.PriceFactor = req.PriceFactor
 		existing.Priority = req.Priority
 		existing.IsActive = req.IsActive
+		existing.Operator = req.Operator
+		existing.Tier = req.Tier
+		existing.RatePerSecond = req.RatePerSecond
+		existing.Burst = req.Burst
+		existing.Pool = trimLinePool(req.Pool)
+		existing.CertifiedOperators = lineCertifiedOperators(req.CertifiedOperators)
 		existing.UpdatedAt = utils.UTCNow()
 
 		if err := f.lineRepo.Update(ctx, existing); err != nil {
@@ -79,16 +91,34 @@ func (f *AdminLineNumberFlowImpl) Create(ctx context.Context, req *dto.AdminCrea
 		return &resp, nil
 	}
 
+	// A soft-deleted line still owns its number
+	deleted, err := f.lineRepo.Exists(ctx, models.LineNumberFilter{LineNumber: &value, IncludeDeleted: true})
+	if err != nil {
+		return nil, err
+	}
+	if deleted {
+		return nil, NewBusinessError("LINE_NUMBER_DELETED", "Line number is deleted; restore it instead", ErrLineNumberDeleted)
+	}
+
 	// Build entity
 	ln := models.LineNumber{
-		UUID:        uuid.New(),
-		Name:        req.Name,
-		LineNumber:  value,
-		PriceFactor: req.PriceFactor,
-		Priority:    req.Priority,
-		IsActive:    req.IsActive,
-		CreatedAt:   utils.UTCNow(),
-		UpdatedAt:   utils.UTCNow(),
+		UUID:               uuid.New(),
+		Name:               req.Name,
+		LineNumber:         value,
+		PriceFactor:        req.PriceFactor,
+		Priority:           req.Priority,
+		Operator:           req.Operator,
+		Tier:               req.Tier,
+		IsActive:           req.IsActive,
+		RatePerSecond:      req.RatePerSecond,
+		Burst:              req.Burst,
+		Pool:               trimLinePool(req.Pool),
+		CreatedAt:          utils.UTCNow(),
+		CertifiedOperators: lineCertifiedOperators(req.CertifiedOperators),
+		UpdatedAt:          utils.UTCNow(),
+	}
+	if ln.Pool != nil && *ln.Pool == "" {
+		ln.Pool = nil
 	}
 
 	// Save
@@ -106,8 +136,8 @@ func (f *AdminLineNumberFlowImpl) Create(ctx context.Context, req *dto.AdminCrea
 	return &resp, nil
 }
 
-func (f *AdminLineNumberFlowImpl) ListAll(ctx context.Context, metadata *ClientMetadata) ([]*dto.AdminLineNumberDTO, error) {
-	lines, err := f.lineRepo.ByFilter(ctx, models.LineNumberFilter{}, "id DESC", 0, 0)
+func (f *AdminLineNumberFlowImpl) ListAll(ctx context.Context, includeDeleted bool, metadata *ClientMetadata) ([]*dto.AdminLineNumberDTO, error) {
+	lines, err := f.lineRepo.ByFilter(ctx, models.LineNumberFilter{IncludeDeleted: includeDeleted}, "id DESC", 0, 0)
 	if err != nil {
 		return nil, NewBusinessError("LINE_NUMBER_LIST_FAILED", "Failed to list line numbers", err)
 	}
@@ -117,7 +147,8 @@ func (f *AdminLineNumberFlowImpl) ListAll(ctx context.Context, metadata *ClientM
 		result = append(result, &dtoItem)
 	}
 	logAdminAction(ctx, f.auditRepo, models.AuditActionAdminLineNumberList, "Admin list line numbers", true, nil, map[string]any{
-		"count": len(result),
+		"count":           len(result),
+		"include_deleted": includeDeleted,
 	}, nil)
 	return result, nil
 }
@@ -133,12 +164,24 @@ func (f *AdminLineNumberFlowImpl) UpdateBatch(ctx context.Context, req *dto.Admi
 			return NewBusinessError("LINE_NUMBER_UPDATE_VALIDATION_FAILED", "Line number ID is required", ErrLineNumberValueRequired)
 		}
 
-		updates = append(updates, &models.LineNumber{
-			ID:        item.ID,
-			Priority:  item.Priority,
-			IsActive:  item.IsActive,
-			UpdatedAt: utils.UTCNow(),
-		})
+		line := &models.LineNumber{
+			ID:            item.ID,
+			Priority:      item.Priority,
+			IsActive:      item.IsActive,
+			Operator:      item.Operator,
+			Tier:          item.Tier,
+			RatePerSecond: item.RatePerSecond,
+			Burst:         item.Burst,
+			Pool:          trimLinePool(item.Pool),
+			UpdatedAt:     utils.UTCNow(),
+		}
+		if item.CertifiedOperators != nil {
+			line.CertifiedOperators = lineCertifiedOperators(*item.CertifiedOperators)
+			if line.CertifiedOperators == nil {
+				line.CertifiedOperators = pq.StringArray{}
+			}
+		}
+		updates = append(updates, line)
 	}
 	// Persist
 	if err := f.lineRepo.UpdateBatch(ctx, updates); err != nil {
@@ -161,3 +204,142 @@ func (f *AdminLineNumberFlowImpl) GetReport(ctx context.Context, metadata *Clien
 	}, nil)
 	return items, nil
 }
+
+// ListTiers returns the reservation pricing of all line number tiers
+func (f *AdminLineNumberFlowImpl) ListTiers(ctx context.Context, metadata *ClientMetadata) ([]*dto.AdminLineNumberTierDTO, error) {
+	tiers, err := f.tierRepo.ByFilter(ctx, models.LineNumberTierFilter{}, "name ASC", 0, 0)
+	if err != nil {
+		return nil, NewBusinessError("LINE_NUMBER_TIER_LIST_FAILED", "Failed to list line number tiers", err)
+	}
+	result := make([]*dto.AdminLineNumberTierDTO, 0, len(tiers))
+	for _, t := range tiers {
+		item := ToLineNumberTierDTO(*t)
+		result = append(result, &item)
+	}
+	return result, nil
+}
+
+// CreateTier adds reservation pricing for the line numbers of a tier
+func (f *AdminLineNumberFlowImpl) CreateTier(ctx context.Context, req *dto.AdminCreateLineNumberTierRequest, metadata *ClientMetadata) (*dto.AdminLineNumberTierDTO, error) {
+	if req == nil {
+		return nil, NewBusinessError("VALIDATION_ERROR", "Invalid request", nil)
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, NewBusinessError("VALIDATION_ERROR", "Tier name is required", nil)
+	}
+	if req.MaxReservationDays < 1 {
+		return nil, NewBusinessError("LINE_NUMBER_TIER_MAX_DAYS_INVALID", "Max reservation days must be greater than zero", ErrLineNumberTierMaxDaysInvalid)
+	}
+	existing, err := f.tierRepo.ByName(ctx, name)
+	if err != nil {
+		return nil, NewBusinessError("LINE_NUMBER_TIER_CREATE_FAILED", "Failed to create line number tier", err)
+	}
+	if existing != nil {
+		return nil, NewBusinessError("LINE_NUMBER_TIER_ALREADY_EXISTS", "Line number tier already exists", ErrLineNumberTierAlreadyExists)
+	}
+
+	isActive := req.IsActive
+	if isActive == nil {
+		isActive = utils.ToPtr(true)
+	}
+	tier := models.LineNumberTier{
+		Name:                   name,
+		DisplayName:            req.DisplayName,
+		Description:            req.Description,
+		ReservationPricePerDay: req.ReservationPricePerDay,
+		MaxReservationDays:     req.MaxReservationDays,
+		IsActive:               isActive,
+		CreatedAt:              utils.UTCNow(),
+		UpdatedAt:              utils.UTCNow(),
+	}
+	meta := map[string]any{
+		"name":                      name,
+		"reservation_price_per_day": req.ReservationPricePerDay,
+		"max_reservation_days":      req.MaxReservationDays,
+	}
+	if err := f.tierRepo.Save(ctx, &tier); err != nil {
+		logAdminAction(ctx, f.auditRepo, models.AuditActionAdminLineNumberTierCreate, "Admin create line number tier", false, nil, meta, err)
+		return nil, NewBusinessError("LINE_NUMBER_TIER_CREATE_FAILED", "Failed to create line number tier", err)
+	}
+	logAdminAction(ctx, f.auditRepo, models.AuditActionAdminLineNumberTierCreate, "Admin create line number tier", true, nil, meta, nil)
+
+	resp := ToLineNumberTierDTO(tier)
+	return &resp, nil
+}
+
+// UpdateTier changes the pricing of a tier. Existing reservations keep the
+// price they were charged.
+func (f *AdminLineNumberFlowImpl) UpdateTier(ctx context.Context, req *dto.AdminUpdateLineNumberTierRequest, metadata *ClientMetadata) (*dto.AdminLineNumberTierDTO, error) {
+	if req == nil || req.ID == 0 {
+		return nil, NewBusinessError("VALIDATION_ERROR", "Invalid request", nil)
+	}
+	tier, err := f.tierRepo.ByID(ctx, req.ID)
+	if err != nil {
+		return nil, NewBusinessError("LINE_NUMBER_TIER_UPDATE_FAILED", "Failed to get line number tier", err)
+	}
+	if tier == nil {
+		return nil, NewBusinessError("LINE_NUMBER_TIER_NOT_FOUND", "Line number tier not found", ErrLineNumberTierNotFound)
+	}
+	if req.MaxReservationDays != nil && *req.MaxReservationDays < 1 {
+		return nil, NewBusinessError("LINE_NUMBER_TIER_MAX_DAYS_INVALID", "Max reservation days must be greater than zero", ErrLineNumberTierMaxDaysInvalid)
+	}
+
+	if req.DisplayName != nil {
+		tier.DisplayName = req.DisplayName
+	}
+	if req.Description != nil {
+		tier.Description = req.Description
+	}
+	if req.ReservationPricePerDay != nil {
+		tier.ReservationPricePerDay = *req.ReservationPricePerDay
+	}
+	if req.MaxReservationDays != nil {
+		tier.MaxReservationDays = *req.MaxReservationDays
+	}
+	if req.IsActive != nil {
+		tier.IsActive = req.IsActive
+	}
+	tier.UpdatedAt = utils.UTCNow()
+
+	meta := map[string]any{
+		"id":                        tier.ID,
+		"name":                      tier.Name,
+		"reservation_price_per_day": tier.ReservationPricePerDay,
+		"max_reservation_days":      tier.MaxReservationDays,
+		"is_active":                 tier.IsActive,
+	}
+	if err := f.tierRepo.Update(ctx, tier); err != nil {
+		logAdminAction(ctx, f.auditRepo, models.AuditActionAdminLineNumberTierUpdate, "Admin update line number tier", false, nil, meta, err)
+		return nil, NewBusinessError("LINE_NUMBER_TIER_UPDATE_FAILED", "Failed to update line number tier", err)
+	}
+	logAdminAction(ctx, f.auditRepo, models.AuditActionAdminLineNumberTierUpdate, "Admin update line number tier", true, nil, meta, nil)
+
+	resp := ToLineNumberTierDTO(*tier)
+	return &resp, nil
+}
+
+// lineCertifiedOperators normalizes and deduplicates operator names; nil means
+// the line is certified for every operator
+func lineCertifiedOperators(ops []string) pq.StringArray {
+	var out pq.StringArray
+	seen := make(map[string]struct{}, len(ops))
+	for _, op := range ops {
+		op = strings.ToLower(strings.TrimSpace(op))
+		if _, dup := seen[op]; dup || op == "" {
+			continue
+		}
+		seen[op] = struct{}{}
+		out = append(out, op)
+	}
+	return out
+}
+
+// trimLinePool trims a pool name; an empty name is kept so updates can clear the pool
+func trimLinePool(pool *string) *string {
+	if pool == nil {
+		return nil
+	}
+	trimmed := strings.TrimSpace(*pool)
+	return &trimmed
+}
@@ -3,23 +3,62 @@ package businessflow
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
 
 	"github.com/amirphl/Yamata-no-Orochi/app/dto"
 	"github.com/amirphl/Yamata-no-Orochi/models"
 	"github.com/amirphl/Yamata-no-Orochi/repository"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 // LineNumberFlow defines user-facing operations for line numbers for customers
 type LineNumberFlow interface {
 	ListActiveLineNumbers(ctx context.Context, metadata *ClientMetadata) (*dto.ListActiveLineNumbersResponse, error)
+	ListAvailableLineNumbers(ctx context.Context, customerID uint, metadata *ClientMetadata) (*dto.ListAvailableLineNumbersResponse, error)
+	ReserveLineNumber(ctx context.Context, req *dto.ReserveLineNumberRequest, metadata *ClientMetadata) (*dto.LineNumberReservationResponse, error)
+	ListReservations(ctx context.Context, customerID uint, metadata *ClientMetadata) (*dto.ListLineNumberReservationsResponse, error)
+	ReleaseReservation(ctx context.Context, customerID uint, reservationUUID string, metadata *ClientMetadata) (*dto.LineNumberReservationResponse, error)
 }
 
 type LineNumberFlowImpl struct {
-	lineRepo repository.LineNumberRepository
+	lineRepo            repository.LineNumberRepository
+	tierRepo            repository.LineNumberTierRepository
+	reservationRepo     repository.LineNumberReservationRepository
+	customerRepo        repository.CustomerRepository
+	walletRepo          repository.WalletRepository
+	balanceSnapshotRepo repository.BalanceSnapshotRepository
+	transactionRepo     repository.TransactionRepository
+	auditRepo           repository.AuditLogRepository
+	db                  *gorm.DB
 }
 
-func NewLineNumberFlow(lineRepo repository.LineNumberRepository) LineNumberFlow {
-	return &LineNumberFlowImpl{lineRepo: lineRepo}
+func NewLineNumberFlow(
+	lineRepo repository.LineNumberRepository,
+	tierRepo repository.LineNumberTierRepository,
+	reservationRepo repository.LineNumberReservationRepository,
+	customerRepo repository.CustomerRepository,
+	walletRepo repository.WalletRepository,
+	balanceSnapshotRepo repository.BalanceSnapshotRepository,
+	transactionRepo repository.TransactionRepository,
+	auditRepo repository.AuditLogRepository,
+	db *gorm.DB,
+) LineNumberFlow {
+	return &LineNumberFlowImpl{
+		lineRepo:            lineRepo,
+		tierRepo:            tierRepo,
+		reservationRepo:     reservationRepo,
+		customerRepo:        customerRepo,
+		walletRepo:          walletRepo,
+		balanceSnapshotRepo: balanceSnapshotRepo,
+		transactionRepo:     transactionRepo,
+		auditRepo:           auditRepo,
+		db:                  db,
+	}
 }
 
 // ListActiveLineNumbers returns active line numbers for customers
@@ -55,3 +94,346 @@ func (f *LineNumberFlowImpl) ListActiveLineNumbers(ctx context.Context, metadata
 		Items:   items,
 	}, nil
 }
+
+// ListAvailableLineNumbers returns the active line numbers the customer may
+// send from together with the reservation pricing of their tiers. Lines held
+// by another customer's reservation are left out.
+func (f *LineNumberFlowImpl) ListAvailableLineNumbers(ctx context.Context, customerID uint, metadata *ClientMetadata) (*dto.ListAvailableLineNumbersResponse, error) {
+	isActive := true
+	lines, err := f.lineRepo.ByFilter(ctx, models.LineNumberFilter{IsActive: &isActive}, "id DESC", 0, 0)
+	if err != nil {
+		return nil, NewBusinessError("LIST_AVAILABLE_LINE_NUMBERS_FAILED", "Failed to list line numbers", err)
+	}
+	tiers, err := f.tierRepo.ByFilter(ctx, models.LineNumberTierFilter{IsActive: &isActive}, "", 0, 0)
+	if err != nil {
+		return nil, NewBusinessError("LIST_AVAILABLE_LINE_NUMBERS_FAILED", "Failed to list line number tiers", err)
+	}
+	now := utils.UTCNow()
+	reservations, err := f.reservationRepo.ByFilter(ctx, models.LineNumberReservationFilter{ActiveAt: &now}, "", 0, 0)
+	if err != nil {
+		return nil, NewBusinessError("LIST_AVAILABLE_LINE_NUMBERS_FAILED", "Failed to list line number reservations", err)
+	}
+
+	return &dto.ListAvailableLineNumbersResponse{
+		Message: "Available line numbers retrieved successfully",
+		Items:   buildAvailableLineNumbers(customerID, lines, tiers, reservations),
+	}, nil
+}
+
+func buildAvailableLineNumbers(customerID uint, lines []*models.LineNumber, tiers []*models.LineNumberTier, reservations []*models.LineNumberReservation) []dto.AvailableLineNumberItem {
+	tierByName := make(map[string]*models.LineNumberTier, len(tiers))
+	for _, t := range tiers {
+		tierByName[t.Name] = t
+	}
+	reservationByLine := make(map[uint]*models.LineNumberReservation, len(reservations))
+	for _, r := range reservations {
+		reservationByLine[r.LineNumberID] = r
+	}
+
+	items := make([]dto.AvailableLineNumberItem, 0, len(lines))
+	for _, ln := range lines {
+		reservation := reservationByLine[ln.ID]
+		if reservation != nil && reservation.CustomerID != customerID {
+			continue
+		}
+		item := dto.AvailableLineNumberItem{
+			UUID:        ln.UUID.String(),
+			LineNumber:  ln.LineNumber,
+			PriceFactor: ln.PriceFactor,
+			Operator:    ln.Operator,
+			Tier:        ln.Tier,
+		}
+		if ln.Tier != nil {
+			if tier, ok := tierByName[*ln.Tier]; ok {
+				item.TierDisplayName = tier.DisplayName
+				item.ReservationPricePerDay = utils.ToPtr(tier.ReservationPricePerDay)
+				item.MaxReservationDays = utils.ToPtr(tier.MaxReservationDays)
+				item.Reservable = reservation == nil
+			}
+		}
+		if reservation != nil {
+			item.ReservedByMe = true
+			item.ReservationExpiresAt = utils.ToPtr(reservation.ExpiresAt.Format(time.RFC3339))
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// ReserveLineNumber gives the customer exclusive use of a line number for the
+// requested number of days. The tier price is debited from the wallet's free
+// balance first and then from its credit balance.
+func (f *LineNumberFlowImpl) ReserveLineNumber(ctx context.Context, req *dto.ReserveLineNumberRequest, metadata *ClientMetadata) (*dto.LineNumberReservationResponse, error) {
+	if req == nil || req.CustomerID == 0 {
+		return nil, NewBusinessError("VALIDATION_ERROR", "Invalid request", nil)
+	}
+	customer, err := f.customerRepo.ByID(ctx, req.CustomerID)
+	if err != nil {
+		return nil, NewBusinessError("RESERVE_LINE_NUMBER_FAILED", "Failed to get customer", err)
+	}
+	if customer == nil {
+		return nil, NewBusinessError("CUSTOMER_NOT_FOUND", "Customer not found", ErrCustomerNotFound)
+	}
+
+	reservation, ln, err := f.reserveLineNumber(ctx, *customer, req)
+	if err != nil {
+		errMsg := fmt.Sprintf("Line number reservation failed: %s", err.Error())
+		_ = createAuditLog(ctx, f.auditRepo, customer, models.AuditActionLineNumberReservationFailed, errMsg, false, &errMsg, metadata)
+		var be *BusinessError
+		if errors.As(err, &be) {
+			return nil, err
+		}
+		return nil, NewBusinessError("RESERVE_LINE_NUMBER_FAILED", "Failed to reserve line number", err)
+	}
+
+	msg := fmt.Sprintf("Line number %s reserved for %d days until %s", ln.LineNumber, reservation.Days, reservation.ExpiresAt.Format(time.RFC3339))
+	_ = createAuditLog(ctx, f.auditRepo, customer, models.AuditActionLineNumberReserved, msg, true, nil, metadata)
+
+	reservation.LineNumber = ln
+	return &dto.LineNumberReservationResponse{
+		Message:     "Line number reserved successfully",
+		Reservation: toLineNumberReservationItem(*reservation, utils.UTCNow()),
+	}, nil
+}
+
+func (f *LineNumberFlowImpl) reserveLineNumber(ctx context.Context, customer models.Customer, req *dto.ReserveLineNumberRequest) (*models.LineNumberReservation, *models.LineNumber, error) {
+	// Sandbox wallets hold test money, which must not take real numbers away
+	// from paying customers
+	if customer.InSandbox() {
+		return nil, nil, ErrSandboxRealPayment
+	}
+	if _, err := uuid.Parse(req.LineNumberUUID); err != nil {
+		return nil, nil, NewBusinessError("LINE_NUMBER_NOT_FOUND", "Line number not found", ErrLineNumberNotFound)
+	}
+	ln, err := f.lineRepo.ByUUID(ctx, req.LineNumberUUID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if ln == nil {
+		return nil, nil, NewBusinessError("LINE_NUMBER_NOT_FOUND", "Line number not found", ErrLineNumberNotFound)
+	}
+	if !utils.IsTrue(ln.IsActive) {
+		return nil, nil, NewBusinessError("LINE_NUMBER_NOT_ACTIVE", "Line number is not active", ErrLineNumberNotActive)
+	}
+	if ln.Tier == nil {
+		return nil, nil, NewBusinessError("LINE_NUMBER_NOT_RESERVABLE", "Line number cannot be reserved", ErrLineNumberNotReservable)
+	}
+	tier, err := f.tierRepo.ByName(ctx, *ln.Tier)
+	if err != nil {
+		return nil, nil, err
+	}
+	if tier == nil || !utils.IsTrue(tier.IsActive) {
+		return nil, nil, NewBusinessError("LINE_NUMBER_NOT_RESERVABLE", "Line number cannot be reserved", ErrLineNumberNotReservable)
+	}
+	if req.Days < 1 || req.Days > tier.MaxReservationDays {
+		msg := fmt.Sprintf("Reservation days must be between 1 and %d", tier.MaxReservationDays)
+		return nil, nil, NewBusinessError("LINE_NUMBER_RESERVATION_DAYS_INVALID", msg, ErrLineNumberReservationDaysInvalid)
+	}
+
+	now := utils.UTCNow()
+	reservation := &models.LineNumberReservation{
+		UUID:         uuid.New(),
+		LineNumberID: ln.ID,
+		CustomerID:   customer.ID,
+		TierID:       &tier.ID,
+		Days:         req.Days,
+		Price:        tier.ReservationPricePerDay * uint64(req.Days),
+		StartsAt:     now,
+		ExpiresAt:    now.AddDate(0, 0, req.Days),
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+
+	err = withVersionRetry(ctx, f.db, func(txCtx context.Context) error {
+		if err := f.reservationRepo.LockLineNumber(txCtx, ln.ID); err != nil {
+			return err
+		}
+		existing, err := f.reservationRepo.ActiveByLineNumberID(txCtx, ln.ID, now)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			if existing.CustomerID == customer.ID {
+				return NewBusinessError("LINE_NUMBER_ALREADY_RESERVED", "Line number is already reserved by you", ErrLineNumberAlreadyReserved)
+			}
+			return NewBusinessError("LINE_NUMBER_RESERVED", "Line number is reserved by another customer", ErrLineNumberReserved)
+		}
+		if err := f.reservationRepo.Save(txCtx, reservation); err != nil {
+			return err
+		}
+		if reservation.Price == 0 {
+			return nil
+		}
+		return f.debitReservationFee(txCtx, customer, *ln, reservation)
+	})
+	if err != nil {
+		if IsInsufficientFunds(err) {
+			return nil, nil, NewBusinessError("INSUFFICIENT_FUNDS", "Insufficient wallet balance for reservation", err)
+		}
+		return nil, nil, err
+	}
+	return reservation, ln, nil
+}
+
+// debitReservationFee records the reservation price as a fee taken from the
+// customer's free balance first and then from the credit balance
+func (f *LineNumberFlowImpl) debitReservationFee(ctx context.Context, customer models.Customer, ln models.LineNumber, reservation *models.LineNumberReservation) error {
+	wallet, err := getWallet(ctx, f.walletRepo, customer.ID)
+	if err != nil {
+		return err
+	}
+	latestBalance, err := getLatestBalanceSnapshot(ctx, f.walletRepo, wallet.ID)
+	if err != nil {
+		return err
+	}
+	if latestBalance.FreeBalance+latestBalance.CreditBalance < reservation.Price {
+		return ErrInsufficientFunds
+	}
+
+	newFreeBalance := latestBalance.FreeBalance
+	newCreditBalance := latestBalance.CreditBalance
+	remaining := reservation.Price
+	if remaining <= newFreeBalance {
+		newFreeBalance -= remaining
+	} else {
+		remaining -= newFreeBalance
+		newFreeBalance = 0
+		newCreditBalance -= remaining
+	}
+
+	meta := map[string]any{
+		"source":           "line_number_reservation",
+		"operation":        "reservation_fee",
+		"reservation_uuid": reservation.UUID.String(),
+		"line_number":      ln.LineNumber,
+		"tier":             ln.Tier,
+		"days":             reservation.Days,
+		"amount":           reservation.Price,
+		"currency":         utils.TomanCurrency,
+	}
+	metaBytes, _ := json.Marshal(meta)
+
+	corrID := uuid.New()
+	newSnapshot := &models.BalanceSnapshot{
+		UUID:               uuid.New(),
+		CorrelationID:      corrID,
+		WalletID:           wallet.ID,
+		CustomerID:         customer.ID,
+		FreeBalance:        newFreeBalance,
+		FrozenBalance:      latestBalance.FrozenBalance,
+		CreditBalance:      newCreditBalance,
+		LockedBalance:      latestBalance.LockedBalance,
+		SpentOnCampaign:    latestBalance.SpentOnCampaign,
+		AgencyShareWithTax: latestBalance.AgencyShareWithTax,
+		TotalBalance:       newFreeBalance + latestBalance.FrozenBalance + newCreditBalance + latestBalance.LockedBalance + latestBalance.SpentOnCampaign + latestBalance.AgencyShareWithTax,
+		Reason:             "line_number_reservation_fee",
+		Description:        fmt.Sprintf("Reservation fee for line number %s (%d days)", ln.LineNumber, reservation.Days),
+		Metadata:           metaBytes,
+		CreatedAt:          utils.UTCNow(),
+		UpdatedAt:          utils.UTCNow(),
+	}
+	if err := f.walletRepo.AdvanceVersion(ctx, &wallet); err != nil {
+		return err
+	}
+	if err := f.balanceSnapshotRepo.Save(ctx, newSnapshot); err != nil {
+		return err
+	}
+
+	beforeMap, err := latestBalance.GetBalanceMap()
+	if err != nil {
+		return err
+	}
+	afterMap, err := newSnapshot.GetBalanceMap()
+	if err != nil {
+		return err
+	}
+
+	feeTx := &models.Transaction{
+		UUID:          uuid.New(),
+		CorrelationID: corrID,
+		Type:          models.TransactionTypeFee,
+		Status:        models.TransactionStatusCompleted,
+		Amount:        reservation.Price,
+		Currency:      utils.TomanCurrency,
+		WalletID:      wallet.ID,
+		CustomerID:    customer.ID,
+		BalanceBefore: beforeMap,
+		BalanceAfter:  afterMap,
+		Description:   fmt.Sprintf("Line number reservation fee: %d Tomans for line number %s", reservation.Price, ln.LineNumber),
+		Metadata:      metaBytes,
+		CreatedAt:     utils.UTCNow(),
+		UpdatedAt:     utils.UTCNow(),
+	}
+	return f.transactionRepo.Save(ctx, feeTx)
+}
+
+// ListReservations returns the customer's line number reservations, newest first
+func (f *LineNumberFlowImpl) ListReservations(ctx context.Context, customerID uint, metadata *ClientMetadata) (*dto.ListLineNumberReservationsResponse, error) {
+	rows, err := f.reservationRepo.ByFilter(ctx, models.LineNumberReservationFilter{CustomerID: &customerID}, "id DESC", 0, 0)
+	if err != nil {
+		return nil, NewBusinessError("LIST_LINE_NUMBER_RESERVATIONS_FAILED", "Failed to list line number reservations", err)
+	}
+	now := utils.UTCNow()
+	items := make([]dto.LineNumberReservationItem, 0, len(rows))
+	for _, r := range rows {
+		items = append(items, toLineNumberReservationItem(*r, now))
+	}
+	return &dto.ListLineNumberReservationsResponse{
+		Message: "Line number reservations retrieved successfully",
+		Items:   items,
+	}, nil
+}
+
+// ReleaseReservation ends an active reservation early so other customers can
+// use the line again. The reservation fee is not refunded.
+func (f *LineNumberFlowImpl) ReleaseReservation(ctx context.Context, customerID uint, reservationUUID string, metadata *ClientMetadata) (*dto.LineNumberReservationResponse, error) {
+	id, err := uuid.Parse(reservationUUID)
+	if err != nil {
+		return nil, NewBusinessError("LINE_NUMBER_RESERVATION_NOT_FOUND", "Line number reservation not found", ErrLineNumberReservationNotFound)
+	}
+	rows, err := f.reservationRepo.ByFilter(ctx, models.LineNumberReservationFilter{UUID: &id, CustomerID: &customerID}, "", 1, 0)
+	if err != nil {
+		return nil, NewBusinessError("RELEASE_LINE_NUMBER_RESERVATION_FAILED", "Failed to get line number reservation", err)
+	}
+	if len(rows) == 0 {
+		return nil, NewBusinessError("LINE_NUMBER_RESERVATION_NOT_FOUND", "Line number reservation not found", ErrLineNumberReservationNotFound)
+	}
+	reservation := rows[0]
+	now := utils.UTCNow()
+	if !reservation.IsActiveAt(now) {
+		return nil, NewBusinessError("LINE_NUMBER_RESERVATION_NOT_ACTIVE", "Line number reservation is not active", ErrLineNumberReservationNotActive)
+	}
+	if err := f.reservationRepo.Release(ctx, reservation.ID, now); err != nil {
+		return nil, NewBusinessError("RELEASE_LINE_NUMBER_RESERVATION_FAILED", "Failed to release line number reservation", err)
+	}
+	reservation.ReleasedAt = &now
+
+	customer := &models.Customer{ID: customerID}
+	msg := fmt.Sprintf("Line number reservation released: %s", reservation.UUID.String())
+	_ = createAuditLog(ctx, f.auditRepo, customer, models.AuditActionLineNumberReservationReleased, msg, true, nil, metadata)
+
+	return &dto.LineNumberReservationResponse{
+		Message:     "Line number reservation released successfully",
+		Reservation: toLineNumberReservationItem(*reservation, now),
+	}, nil
+}
+
+func toLineNumberReservationItem(r models.LineNumberReservation, now time.Time) dto.LineNumberReservationItem {
+	item := dto.LineNumberReservationItem{
+		UUID:      r.UUID.String(),
+		Days:      r.Days,
+		Price:     r.Price,
+		StartsAt:  r.StartsAt.Format(time.RFC3339),
+		ExpiresAt: r.ExpiresAt.Format(time.RFC3339),
+		IsActive:  r.IsActiveAt(now),
+		CreatedAt: r.CreatedAt.Format(time.RFC3339),
+	}
+	if r.LineNumber != nil {
+		item.LineNumber = r.LineNumber.LineNumber
+		item.Tier = r.LineNumber.Tier
+	}
+	if r.ReleasedAt != nil {
+		item.ReleasedAt = utils.ToPtr(r.ReleasedAt.Format(time.RFC3339))
+	}
+	return item
+}
@@ -0,0 +1,93 @@
+package businessflow
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/google/uuid"
+)
+
+func TestBuildAvailableLineNumbers(t *testing.T) {
+	t.Parallel()
+
+	gold := "gold"
+	silver := "silver"
+	lines := []*models.LineNumber{
+		{ID: 1, UUID: uuid.New(), LineNumber: "1000", Tier: &gold},
+		{ID: 2, UUID: uuid.New(), LineNumber: "2000", Tier: &gold},
+		{ID: 3, UUID: uuid.New(), LineNumber: "3000", Tier: &gold},
+		{ID: 4, UUID: uuid.New(), LineNumber: "4000", Tier: &silver},
+		{ID: 5, UUID: uuid.New(), LineNumber: "5000"},
+	}
+	tiers := []*models.LineNumberTier{
+		{ID: 1, Name: gold, ReservationPricePerDay: 5000, MaxReservationDays: 30, IsActive: utils.ToPtr(true)},
+	}
+	expires := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+	reservations := []*models.LineNumberReservation{
+		{LineNumberID: 2, CustomerID: 7, ExpiresAt: expires},
+		{LineNumberID: 3, CustomerID: 8, ExpiresAt: expires},
+	}
+
+	items := buildAvailableLineNumbers(7, lines, tiers, reservations)
+	if len(items) != 4 {
+		t.Fatalf("expected line reserved by another customer to be hidden, got %d items", len(items))
+	}
+	byLine := make(map[string]int, len(items))
+	for i, item := range items {
+		byLine[item.LineNumber] = i
+	}
+	if _, ok := byLine["3000"]; ok {
+		t.Fatalf("line 3000 is reserved by another customer and must not be listed")
+	}
+
+	free := items[byLine["1000"]]
+	if !free.Reservable || free.ReservedByMe || free.ReservationPricePerDay == nil || *free.ReservationPricePerDay != 5000 {
+		t.Fatalf("unexpected free gold line %+v", free)
+	}
+	mine := items[byLine["2000"]]
+	if mine.Reservable || !mine.ReservedByMe || mine.ReservationExpiresAt == nil || *mine.ReservationExpiresAt != "2026-04-01T00:00:00Z" {
+		t.Fatalf("unexpected own reservation %+v", mine)
+	}
+	if items[byLine["4000"]].Reservable || items[byLine["5000"]].Reservable {
+		t.Fatalf("lines without an active tier must not be reservable")
+	}
+}
+
+func TestLineNumberReservationIsActiveAt(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	r := models.LineNumberReservation{StartsAt: start, ExpiresAt: start.AddDate(0, 0, 3)}
+
+	if !r.IsActiveAt(start) || !r.IsActiveAt(start.Add(48*time.Hour)) {
+		t.Fatalf("expected reservation to be active within its window")
+	}
+	if r.IsActiveAt(r.ExpiresAt) || r.IsActiveAt(start.Add(-time.Second)) {
+		t.Fatalf("expected reservation to be inactive outside its window")
+	}
+	released := start.Add(time.Hour)
+	r.ReleasedAt = &released
+	if r.IsActiveAt(start.Add(2 * time.Hour)) {
+		t.Fatalf("expected released reservation to be inactive")
+	}
+}
+
+func TestReserveLineNumberRefusesSandbox(t *testing.T) {
+	t.Parallel()
+
+	// Refused before the line number is looked up, so no repository is needed
+	f := &LineNumberFlowImpl{}
+	customer := models.Customer{ID: 7, IsSandbox: utils.ToPtr(true)}
+	_, _, err := f.reserveLineNumber(context.Background(), customer, &dto.ReserveLineNumberRequest{
+		CustomerID:     7,
+		LineNumberUUID: uuid.NewString(),
+		Days:           3,
+	})
+	if !IsSandboxRealPayment(err) {
+		t.Fatalf("sandbox reservation returned %v", err)
+	}
+}
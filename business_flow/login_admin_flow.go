@@ -12,13 +12,13 @@ import (
 	"time"
 
 	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/app/i18n"
 	"github.com/amirphl/Yamata-no-Orochi/app/services"
 	"github.com/amirphl/Yamata-no-Orochi/config"
 	"github.com/amirphl/Yamata-no-Orochi/models"
 	"github.com/amirphl/Yamata-no-Orochi/repository"
 	"github.com/amirphl/Yamata-no-Orochi/utils"
 	"github.com/redis/go-redis/v9"
-	"golang.org/x/crypto/bcrypt"
 )
 
 // AdminAuthFlow represents the admin authentication flow used by handlers
@@ -28,17 +28,39 @@ type AdminAuthFlow interface {
 	VerifyOTP(ctx context.Context, req *dto.AdminLoginVerifyOTPRequest, metadata *ClientMetadata) (*dto.AdminLoginResponse, error)
 }
 
-// AdminAuthFlowImpl provides captcha-init and admin credential verification
+// AdminAuthFlowImpl provides captcha-init and admin credential verification.
+//
+// Every admin login ends with a TOTP code from an authenticator app. An admin
+// without one enrolls it on their next login: after the SMS OTP (or directly,
+// for OTP bypass mobiles) a new secret is returned and only becomes theirs
+// once a code from the app is verified.
 type AdminAuthFlowImpl struct {
-	adminRepo    repository.AdminRepository
-	tokenService services.TokenService
-	captchaSvc   services.CaptchaService
-	otpSMSSvc    services.SMSService
-	adminConfig  config.AdminConfig
-	messageCfg   config.MessageConfig
-	rc           *redis.Client
+	adminRepo      repository.AdminRepository
+	auditRepo      repository.AuditLogRepository
+	tokenService   services.TokenService
+	passwordHasher services.PasswordHasher
+	captchaSvc     services.CaptchaService
+	totpSvc        services.TOTPService
+	otpSMSSvc      services.SMSService
+	adminConfig    config.AdminConfig
+	localizer      *i18n.Localizer
+	rc             *redis.Client
 }
 
+// Second factors of an admin login challenge
+const (
+	AdminTwoFactorSMS       = "sms"
+	AdminTwoFactorTOTP      = "totp"
+	AdminTwoFactorTOTPSetup = "totp_setup"
+)
+
+// adminTOTPSetupTTL leaves time to install and set up an authenticator app
+const adminTOTPSetupTTL = 10 * time.Minute
+
+// adminTOTPUsedTTL covers every step a TOTP code is accepted in, so a code
+// cannot be replayed while it is still valid
+const adminTOTPUsedTTL = 2 * time.Minute
+
 // adminLoginOTPMaxAttempts is intentionally separate from authOTPMaxAttempts so
 // the admin and user limits can be tuned independently without silent coupling.
 const adminLoginOTPMaxAttempts = 5
@@ -51,25 +73,36 @@ type adminLoginChallenge struct {
 	OTPHash     string    `json:"otp_hash"`
 	CreatedAt   time.Time `json:"created_at"`
 	LastSentAt  time.Time `json:"last_sent_at"`
+	// Method is one of the AdminTwoFactor constants; challenges stored before
+	// TOTP was required have none and are SMS challenges
+	Method string `json:"method,omitempty"`
+	// TOTPSecret is the secret being enrolled by a totp_setup challenge
+	TOTPSecret string `json:"totp_secret,omitempty"`
 }
 
 func NewAdminAuthFlow(
 	adminRepo repository.AdminRepository,
+	auditRepo repository.AuditLogRepository,
 	tokenService services.TokenService,
+	passwordHasher services.PasswordHasher,
 	captchaSvc services.CaptchaService,
+	totpSvc services.TOTPService,
 	otpSMSSvc services.SMSService,
 	adminConfig config.AdminConfig,
-	messageCfg config.MessageConfig,
+	localizer *i18n.Localizer,
 	rc *redis.Client,
 ) AdminAuthFlow {
 	return &AdminAuthFlowImpl{
-		adminRepo:    adminRepo,
-		tokenService: tokenService,
-		captchaSvc:   captchaSvc,
-		otpSMSSvc:    otpSMSSvc,
-		adminConfig:  adminConfig,
-		messageCfg:   messageCfg,
-		rc:           rc,
+		adminRepo:      adminRepo,
+		auditRepo:      auditRepo,
+		tokenService:   tokenService,
+		passwordHasher: passwordHasher,
+		captchaSvc:     captchaSvc,
+		totpSvc:        totpSvc,
+		otpSMSSvc:      otpSMSSvc,
+		adminConfig:    adminConfig,
+		localizer:      localizer,
+		rc:             rc,
 	}
 }
 
@@ -124,24 +157,34 @@ func (af *AdminAuthFlowImpl) Verify(ctx context.Context, req *dto.AdminCaptchaVe
 	}
 
 	// Verify password
-	if err := bcrypt.CompareHashAndPassword([]byte(admin.PasswordHash), []byte(req.Password)); err != nil {
+	if ok, err := af.passwordHasher.Verify(req.Password, admin.PasswordHash); err != nil || !ok {
 		_ = af.recordAdminLoginFailure(ctx, req.Username, metadata)
 		return nil, NewBusinessError("ADMIN_LOGIN_FAILED", "Admin login failed", ErrAuthenticationFailed)
 	}
 
+	if admin.TOTPSecret != nil {
+		resp, err := af.startTOTPChallenge(ctx, admin)
+		if err != nil {
+			return nil, NewBusinessError("ADMIN_LOGIN_TOTP_FAILED", "Failed to start admin login verification", err)
+		}
+		_ = af.clearAdminLoginFailures(ctx, req.Username, metadata)
+		return resp, nil
+	}
+
+	// OTP bypass mobiles skip the SMS OTP but still enroll an authenticator app
 	if af.adminConfig.AllowsOTPBypass(af.adminConfig.TwoFAMobile(admin.Username)) {
-		resp, err := af.issueAdminSession(admin)
+		setup, err := af.startTOTPSetup(ctx, admin)
 		if err != nil {
-			return nil, NewBusinessError("TOKEN_GENERATION_FAILED", "Failed to generate tokens", err)
+			return nil, NewBusinessError("ADMIN_LOGIN_TOTP_FAILED", "Failed to start authenticator setup", err)
 		}
 		_ = af.clearAdminLoginFailures(ctx, req.Username, metadata)
 		return &dto.AdminLoginInitResponse{
-			Message:           "Login successful",
-			OTPSent:           false,
-			AlreadySent:       false,
-			RequiresTwoFactor: false,
-			Admin:             &resp.Admin,
-			Session:           &resp.Session,
+			Message:           "Set up an authenticator app to continue",
+			ChallengeID:       setup.ChallengeID,
+			OTPExpiresAt:      setup.ExpiresAt,
+			RequiresTwoFactor: true,
+			TwoFactorMethod:   AdminTwoFactorTOTPSetup,
+			TOTPSetup:         setup,
 		}, nil
 	}
 
@@ -183,13 +226,6 @@ func (af *AdminAuthFlowImpl) VerifyOTP(ctx context.Context, req *dto.AdminLoginV
 		return nil, NewBusinessError("ADMIN_LOGIN_OTP_ATTEMPTS_EXCEEDED", "Admin OTP verification failed", ErrInvalidOTPCode)
 	}
 
-	if !verifyOTPCodeHash(req.OTPCode, challenge.OTPHash) {
-		if newAttempts >= adminLoginOTPMaxAttempts {
-			_ = af.deleteAdminLoginChallenge(ctx, req.ChallengeID, challenge.AdminID)
-		}
-		return nil, NewBusinessError("ADMIN_LOGIN_OTP_INVALID", "Admin OTP verification failed", ErrInvalidOTPCode)
-	}
-
 	admin, err := af.adminRepo.ByID(ctx, challenge.AdminID)
 	if err != nil {
 		return nil, NewBusinessError("ADMIN_LOOKUP_FAILED", "Failed to lookup admin", err)
@@ -203,6 +239,20 @@ func (af *AdminAuthFlowImpl) VerifyOTP(ctx context.Context, req *dto.AdminLoginV
 		return nil, NewBusinessError("ADMIN_INACTIVE", "Admin account is inactive", ErrAdminInactive)
 	}
 
+	valid, err := af.verifyChallengeCode(ctx, challenge, admin, req.OTPCode)
+	if err != nil {
+		return nil, NewBusinessError("ADMIN_LOGIN_OTP_VERIFY_FAILED", "Admin OTP verification failed", err)
+	}
+	if !valid {
+		if newAttempts >= adminLoginOTPMaxAttempts {
+			_ = af.deleteAdminLoginChallenge(ctx, req.ChallengeID, challenge.AdminID)
+		}
+		if challenge.Method == AdminTwoFactorTOTP || challenge.Method == AdminTwoFactorTOTPSetup {
+			af.logTOTPAction(ctx, models.AuditActionAdminTOTPFailed, fmt.Sprintf("Invalid TOTP code for admin %s", admin.Username), false, admin, challenge.Method, metadata, ErrInvalidOTPCode)
+		}
+		return nil, NewBusinessError("ADMIN_LOGIN_OTP_INVALID", "Admin OTP verification failed", ErrInvalidOTPCode)
+	}
+
 	// consumeAdminLoginChallenge atomically deletes the challenge and checks it
 	// was still present. A concurrent request that already deleted it returns
 	// false here, preventing a second token pair from being issued for a single OTP.
@@ -213,6 +263,24 @@ func (af *AdminAuthFlowImpl) VerifyOTP(ctx context.Context, req *dto.AdminLoginV
 	if !consumed {
 		return nil, NewBusinessError("ADMIN_LOGIN_OTP_INVALID", "Admin OTP verification failed", ErrInvalidOTPCode)
 	}
+
+	switch challenge.Method {
+	case AdminTwoFactorTOTP:
+	case AdminTwoFactorTOTPSetup:
+		if err := af.adminRepo.EnableTOTP(ctx, admin.ID, challenge.TOTPSecret); err != nil {
+			return nil, NewBusinessError("ADMIN_TOTP_ENROLL_FAILED", "Failed to enable authenticator app", err)
+		}
+		af.logTOTPAction(ctx, models.AuditActionAdminTOTPEnrolled, fmt.Sprintf("Authenticator app enrolled for admin %s", admin.Username), true, admin, challenge.Method, metadata, nil)
+	default:
+		// The SMS OTP proves the admin's mobile; the login continues with
+		// enrolling an authenticator app
+		setup, err := af.startTOTPSetup(ctx, admin)
+		if err != nil {
+			return nil, NewBusinessError("ADMIN_LOGIN_TOTP_FAILED", "Failed to start authenticator setup", err)
+		}
+		return &dto.AdminLoginResponse{TOTPSetup: setup}, nil
+	}
+
 	resp, err := af.issueAdminSession(admin)
 	if err != nil {
 		return nil, NewBusinessError("TOKEN_GENERATION_FAILED", "Failed to generate tokens", err)
@@ -237,7 +305,7 @@ func (af *AdminAuthFlowImpl) issueOrReuseOTP(ctx context.Context, adminID uint,
 		return nil, err
 	}
 
-	if existing, ttl, err := af.getExistingChallengeByAdminID(ctx, adminID); err == nil && existing != nil {
+	if existing, ttl, err := af.getExistingChallengeByAdminID(ctx, adminID); err == nil && existing != nil && existing.isSMS() {
 		return &dto.AdminLoginInitResponse{
 			Message:           "OTP already generated and sent",
 			ChallengeID:       existing.ChallengeID,
@@ -246,6 +314,7 @@ func (af *AdminAuthFlowImpl) issueOrReuseOTP(ctx context.Context, adminID uint,
 			AlreadySent:       true,
 			OTPExpiresAt:      utils.UTCNowAdd(ttl),
 			RequiresTwoFactor: true,
+			TwoFactorMethod:   AdminTwoFactorSMS,
 		}, nil
 	} else if err != nil && err != redis.Nil && err != ErrNoValidOTPFound {
 		return nil, err
@@ -269,9 +338,10 @@ func (af *AdminAuthFlowImpl) issueOrReuseOTP(ctx context.Context, adminID uint,
 		OTPHash:     hashOTPCode(otpCode),
 		CreatedAt:   now,
 		LastSentAt:  now,
+		Method:      AdminTwoFactorSMS,
 	}
 
-	message := fmt.Sprintf(af.messageCfg.SigninVerificationCodeTemplate, otpCode)
+	message := af.localizer.Admin("otp.signin_code", i18n.Args{"Code": otpCode})
 	adminID64 := int64(adminID)
 	if err := af.saveAdminLoginChallenge(ctx, challenge, utils.OTPExpiry); err != nil {
 		return nil, err
@@ -289,9 +359,112 @@ func (af *AdminAuthFlowImpl) issueOrReuseOTP(ctx context.Context, adminID uint,
 		AlreadySent:       false,
 		OTPExpiresAt:      now.Add(utils.OTPExpiry),
 		RequiresTwoFactor: true,
+		TwoFactorMethod:   AdminTwoFactorSMS,
+	}, nil
+}
+
+// startTOTPChallenge asks an enrolled admin for a code from their
+// authenticator app
+func (af *AdminAuthFlowImpl) startTOTPChallenge(ctx context.Context, admin *models.Admin) (*dto.AdminLoginInitResponse, error) {
+	challenge, err := af.newTOTPChallenge(ctx, admin, AdminTwoFactorTOTP, "", utils.OTPExpiry)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.AdminLoginInitResponse{
+		Message:           "Enter the code from your authenticator app",
+		ChallengeID:       challenge.ChallengeID,
+		OTPExpiresAt:      challenge.CreatedAt.Add(utils.OTPExpiry),
+		RequiresTwoFactor: true,
+		TwoFactorMethod:   AdminTwoFactorTOTP,
 	}, nil
 }
 
+// startTOTPSetup generates a secret for an admin to add to an authenticator
+// app; it is stored on the admin once a code from the app is verified
+func (af *AdminAuthFlowImpl) startTOTPSetup(ctx context.Context, admin *models.Admin) (*dto.AdminTOTPSetupDTO, error) {
+	if af.totpSvc == nil {
+		return nil, ErrAdminTwoFactorNotConfigured
+	}
+	secret, err := af.totpSvc.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	challenge, err := af.newTOTPChallenge(ctx, admin, AdminTwoFactorTOTPSetup, secret, adminTOTPSetupTTL)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.AdminTOTPSetupDTO{
+		ChallengeID:     challenge.ChallengeID,
+		Secret:          secret,
+		ProvisioningURI: af.totpSvc.ProvisioningURI(admin.Username, secret),
+		ExpiresAt:       challenge.CreatedAt.Add(adminTOTPSetupTTL),
+	}, nil
+}
+
+func (af *AdminAuthFlowImpl) newTOTPChallenge(ctx context.Context, admin *models.Admin, method, secret string, ttl time.Duration) (*adminLoginChallenge, error) {
+	if af.rc == nil {
+		return nil, ErrCacheNotAvailable
+	}
+	challengeID, err := generateAdminLoginChallengeID()
+	if err != nil {
+		return nil, err
+	}
+	challenge := &adminLoginChallenge{
+		ChallengeID: challengeID,
+		AdminID:     admin.ID,
+		Username:    admin.Username,
+		CreatedAt:   utils.UTCNow(),
+		Method:      method,
+		TOTPSecret:  secret,
+	}
+	if err := af.saveAdminLoginChallenge(ctx, challenge, ttl); err != nil {
+		return nil, err
+	}
+	return challenge, nil
+}
+
+// verifyChallengeCode checks code against the second factor of a challenge.
+// A TOTP code is accepted once: the time step it matched is recorded.
+func (af *AdminAuthFlowImpl) verifyChallengeCode(ctx context.Context, challenge *adminLoginChallenge, admin *models.Admin, code string) (bool, error) {
+	var secret string
+	switch challenge.Method {
+	case AdminTwoFactorTOTP:
+		if admin.TOTPSecret == nil {
+			return false, nil
+		}
+		secret = *admin.TOTPSecret
+	case AdminTwoFactorTOTPSetup:
+		secret = challenge.TOTPSecret
+	default:
+		return verifyOTPCodeHash(code, challenge.OTPHash), nil
+	}
+
+	if af.totpSvc == nil {
+		return false, ErrAdminTwoFactorNotConfigured
+	}
+	step, ok := af.totpSvc.Validate(secret, code, utils.UTCNow())
+	if !ok {
+		return false, nil
+	}
+	return af.rc.SetNX(ctx, af.adminTOTPUsedKey(admin.ID, step), 1, adminTOTPUsedTTL).Result()
+}
+
+func (af *AdminAuthFlowImpl) logTOTPAction(ctx context.Context, action, description string, success bool, admin *models.Admin, method string, metadata *ClientMetadata, err error) {
+	meta := map[string]any{
+		"admin_id":          admin.ID,
+		"username":          admin.Username,
+		"two_factor_method": method,
+	}
+	if metadata != nil {
+		meta["ip_address"] = metadata.IPAddress
+	}
+	logAdminAction(ctx, af.auditRepo, action, description, success, nil, meta, err)
+}
+
+func (c *adminLoginChallenge) isSMS() bool {
+	return c.Method == "" || c.Method == AdminTwoFactorSMS
+}
+
 func (af *AdminAuthFlowImpl) getExistingChallengeByAdminID(ctx context.Context, adminID uint) (*adminLoginChallenge, time.Duration, error) {
 	challengeID, err := af.rc.Get(ctx, af.adminLoginIndexKey(adminID)).Result()
 	if err != nil {
@@ -330,7 +503,10 @@ func (af *AdminAuthFlowImpl) saveAdminLoginChallenge(ctx context.Context, challe
 	}
 	pipe := af.rc.TxPipeline()
 	pipe.Set(ctx, af.adminLoginChallengeKey(challenge.ChallengeID), payload, ttl)
-	pipe.Set(ctx, af.adminLoginIndexKey(challenge.AdminID), challenge.ChallengeID, ttl)
+	// Only SMS challenges are reused while their OTP is outstanding
+	if challenge.isSMS() {
+		pipe.Set(ctx, af.adminLoginIndexKey(challenge.AdminID), challenge.ChallengeID, ttl)
+	}
 	_, err = pipe.Exec(ctx)
 	return err
 }
@@ -376,6 +552,10 @@ func (af *AdminAuthFlowImpl) adminLoginAttemptsKey(challengeID string) string {
 	return fmt.Sprintf("admin:login:otp:attempts:%s", challengeID)
 }
 
+func (af *AdminAuthFlowImpl) adminTOTPUsedKey(adminID uint, step int64) string {
+	return fmt.Sprintf("admin:login:totp:used:%d:%d", adminID, step)
+}
+
 func (af *AdminAuthFlowImpl) incrementAdminOTPAttempts(ctx context.Context, challengeID string, ttl time.Duration) (int, error) {
 	key := af.adminLoginAttemptsKey(challengeID)
 	pipe := af.rc.TxPipeline()
@@ -7,7 +7,6 @@ import (
 	"github.com/amirphl/Yamata-no-Orochi/app/services"
 	"github.com/amirphl/Yamata-no-Orochi/repository"
 	"github.com/amirphl/Yamata-no-Orochi/utils"
-	"golang.org/x/crypto/bcrypt"
 )
 
 // BotAuthFlow represents the bot authentication flow used by handlers
@@ -16,14 +15,16 @@ type BotAuthFlow interface {
 }
 
 type BotAuthFlowImpl struct {
-	botRepo      repository.BotRepository
-	tokenService services.TokenService
+	botRepo        repository.BotRepository
+	tokenService   services.TokenService
+	passwordHasher services.PasswordHasher
 }
 
-func NewBotAuthFlow(botRepo repository.BotRepository, tokenService services.TokenService) BotAuthFlow {
+func NewBotAuthFlow(botRepo repository.BotRepository, tokenService services.TokenService, passwordHasher services.PasswordHasher) BotAuthFlow {
 	return &BotAuthFlowImpl{
-		botRepo:      botRepo,
-		tokenService: tokenService,
+		botRepo:        botRepo,
+		tokenService:   tokenService,
+		passwordHasher: passwordHasher,
 	}
 }
 
@@ -43,7 +44,7 @@ func (bf *BotAuthFlowImpl) Verify(ctx context.Context, req *dto.BotLoginRequest,
 		return nil, NewBusinessError("BOT_INACTIVE", "Bot account is inactive", ErrBotInactive)
 	}
 
-	if err := bcrypt.CompareHashAndPassword([]byte(bot.PasswordHash), []byte(req.Password)); err != nil {
+	if ok, err := bf.passwordHasher.Verify(req.Password, bot.PasswordHash); err != nil || !ok {
 		return nil, NewBusinessError("BOT_INCORRECT_PASSWORD", "Incorrect password", ErrIncorrectPassword)
 	}
 
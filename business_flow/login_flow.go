@@ -7,18 +7,21 @@ import (
 	"encoding/json"
 	"fmt"
 	"math/big"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/app/i18n"
 	"github.com/amirphl/Yamata-no-Orochi/app/services"
 	"github.com/amirphl/Yamata-no-Orochi/config"
 	"github.com/amirphl/Yamata-no-Orochi/models"
 	"github.com/amirphl/Yamata-no-Orochi/repository"
 	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/amirphl/Yamata-no-Orochi/utils/phonenumber"
+	"github.com/gofiber/fiber/v3/log"
 	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
-	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
@@ -26,51 +29,87 @@ import (
 type LoginFlow interface {
 	Login(ctx context.Context, request *dto.LoginRequest, metadata *ClientMetadata) (*dto.LoginResponse, error)
 	RequestLoginOTP(ctx context.Context, request *dto.LoginOTPRequest, metadata *ClientMetadata) (*dto.LoginOTPResponse, error)
+	RequestPasswordlessLoginOTP(ctx context.Context, request *dto.PasswordlessLoginOTPRequest, metadata *ClientMetadata) (*dto.LoginOTPResponse, error)
+	PasswordlessLogin(ctx context.Context, request *dto.PasswordlessLoginRequest, metadata *ClientMetadata) (*dto.LoginResponse, error)
 	ForgotPassword(ctx context.Context, request *dto.ForgotPasswordRequest, metadata *ClientMetadata) (*dto.ForgetPasswordResponse, error)
 	ResetPassword(ctx context.Context, request *dto.ResetPasswordRequest, metadata *ClientMetadata) (*dto.ResetPasswordResponse, error)
+	ChangePassword(ctx context.Context, customerID uint, currentToken string, request *dto.ChangePasswordRequest, metadata *ClientMetadata) (*dto.ChangePasswordResponse, error)
+	RequestMobileChange(ctx context.Context, customerID uint, request *dto.MobileChangeRequest, metadata *ClientMetadata) (*dto.MobileChangeOTPResponse, error)
+	ConfirmMobileChange(ctx context.Context, customerID uint, currentToken string, request *dto.MobileChangeConfirmRequest, metadata *ClientMetadata) (*dto.MobileChangeResponse, error)
+	ResendOTP(ctx context.Context, request *dto.LoginOTPResendRequest, metadata *ClientMetadata) (*dto.OTPResendResponse, error)
+	Logout(ctx context.Context, customerID uint, accessToken string, metadata *ClientMetadata) error
+	ListSessions(ctx context.Context, customerID uint, currentToken string) (*dto.ListSessionsResponse, error)
+	RevokeSession(ctx context.Context, customerID, sessionID uint, metadata *ClientMetadata) error
+	ReportLoginNotMe(ctx context.Context, request *dto.LoginAlertReportRequest, metadata *ClientMetadata) (*dto.LoginAlertReportResponse, error)
 }
 
+// OTP purposes that can be resent through LoginFlow.ResendOTP
+const (
+	OTPPurposeLogin             = "login"
+	OTPPurposePasswordlessLogin = "passwordless_login"
+	OTPPurposePasswordReset     = "password_reset"
+)
+
 // LoginFlowImpl implements the login business flow
 type LoginFlowImpl struct {
 	customerRepo    repository.CustomerRepository
 	sessionRepo     repository.CustomerSessionRepository
+	sessionStore    services.SessionStore
 	auditRepo       repository.AuditLogRepository
 	accountTypeRepo repository.AccountTypeRepository
 	tokenService    services.TokenService
+	passwordHasher  services.PasswordHasher
+	passwordPolicy  services.PasswordPolicy
 	otpSMSSvc       services.SMSService
 	notificationSvc services.NotificationService
-	messageConfig   config.MessageConfig
+	localizer       *i18n.Localizer
 	adminConfig     config.AdminConfig
 	db              *gorm.DB
 	rc              *redis.Client
+	otpThrottle     *OTPThrottle
+	loginDetector   *SuspiciousLoginDetector
+	// passwordlessLogin enables logging in with only an SMS code
+	passwordlessLogin bool
 }
 
 // NewLoginFlow creates a new login flow instance
 func NewLoginFlow(
 	customerRepo repository.CustomerRepository,
 	sessionRepo repository.CustomerSessionRepository,
+	sessionStore services.SessionStore,
 	auditRepo repository.AuditLogRepository,
 	accountTypeRepo repository.AccountTypeRepository,
 	tokenService services.TokenService,
+	passwordHasher services.PasswordHasher,
+	passwordPolicy services.PasswordPolicy,
 	otpSMSSvc services.SMSService,
 	notificationSvc services.NotificationService,
-	messageConfig config.MessageConfig,
+	localizer *i18n.Localizer,
 	adminConfig config.AdminConfig,
 	db *gorm.DB,
 	rc *redis.Client,
+	otpThrottle *OTPThrottle,
+	loginDetector *SuspiciousLoginDetector,
+	passwordlessLogin bool,
 ) LoginFlow {
 	return &LoginFlowImpl{
-		customerRepo:    customerRepo,
-		sessionRepo:     sessionRepo,
-		auditRepo:       auditRepo,
-		accountTypeRepo: accountTypeRepo,
-		tokenService:    tokenService,
-		otpSMSSvc:       otpSMSSvc,
-		notificationSvc: notificationSvc,
-		messageConfig:   messageConfig,
-		adminConfig:     adminConfig,
-		db:              db,
-		rc:              rc,
+		customerRepo:      customerRepo,
+		sessionRepo:       sessionRepo,
+		sessionStore:      sessionStore,
+		auditRepo:         auditRepo,
+		accountTypeRepo:   accountTypeRepo,
+		tokenService:      tokenService,
+		passwordHasher:    passwordHasher,
+		passwordPolicy:    passwordPolicy,
+		otpSMSSvc:         otpSMSSvc,
+		notificationSvc:   notificationSvc,
+		localizer:         localizer,
+		adminConfig:       adminConfig,
+		db:                db,
+		rc:                rc,
+		otpThrottle:       otpThrottle,
+		loginDetector:     loginDetector,
+		passwordlessLogin: passwordlessLogin,
 	}
 }
 
@@ -87,6 +126,7 @@ func (lf *LoginFlowImpl) Login(ctx context.Context, req *dto.LoginRequest, metad
 	}
 
 	var customer *models.Customer
+	var session *models.CustomerSession
 	var resp *dto.LoginResponse
 	var newlyVerified bool
 
@@ -105,10 +145,15 @@ func (lf *LoginFlowImpl) Login(ctx context.Context, req *dto.LoginRequest, metad
 		}
 
 		// Verify password
-		if err := bcrypt.CompareHashAndPassword([]byte(customer.PasswordHash), []byte(req.Password)); err != nil {
+		if ok, err := lf.passwordHasher.Verify(req.Password, customer.PasswordHash); err != nil || !ok {
 			return ErrAuthenticationFailed
 		}
 
+		// A login reported as not the customer's locks the password until reset
+		if utils.IsTrue(customer.PasswordResetRequired) {
+			return ErrPasswordResetRequired
+		}
+
 		// Get account type information
 		accountType, err := lf.accountTypeRepo.ByID(txCtx, customer.AccountTypeID)
 		if err != nil {
@@ -139,7 +184,7 @@ func (lf *LoginFlowImpl) Login(ctx context.Context, req *dto.LoginRequest, metad
 		}
 
 		// Create new session
-		session, err := lf.createSession(txCtx, customer.ID, metadata)
+		session, err = lf.createSession(txCtx, customer.ID, metadata)
 		if err != nil {
 			return err
 		}
@@ -162,6 +207,8 @@ func (lf *LoginFlowImpl) Login(ctx context.Context, req *dto.LoginRequest, metad
 		return nil, NewBusinessError("LOGIN_FAILED", "Login failed", err)
 	}
 	_ = lf.clearFailedLoginAttempts(ctx, req.Identifier, metadata)
+	lf.upgradePasswordHash(ctx, customer, req.Password)
+	activateSession(ctx, lf.sessionStore, session)
 
 	if newlyVerified {
 		msg := fmt.Sprintf("Signup completed successfully for customer %d", customer.ID)
@@ -171,6 +218,8 @@ func (lf *LoginFlowImpl) Login(ctx context.Context, req *dto.LoginRequest, metad
 	msg := fmt.Sprintf("User logged in successfully for identifier %s", req.Identifier)
 	_ = lf.createAuditLog(ctx, customer, models.AuditActionLoginSuccess, msg, true, nil, metadata)
 
+	lf.checkNewDevice(ctx, customer, metadata)
+
 	return resp, nil
 }
 
@@ -192,68 +241,149 @@ func (lf *LoginFlowImpl) RequestLoginOTP(ctx context.Context, req *dto.LoginOTPR
 		return nil, ErrCustomerNotFound
 	}
 
-	key := lf.loginOTPKey(customer.ID)
-	if _, ttl, err := lf.getOTPState(ctx, key); err == nil {
-		return &dto.LoginOTPResponse{
-			Message:     "OTP already generated and sent",
-			CustomerID:  customer.ID,
-			MaskedPhone: dto.MaskPhoneNumber(customer.RepresentativeMobile),
-			OTPSent:     true,
-			AlreadySent: true,
-			OTPExpiry:   utils.UTCNowAdd(ttl),
-		}, nil
-	} else if err != nil && err != ErrNoValidOTPFound {
-		return nil, err
+	return lf.sendLoginOTP(ctx, customer, lf.loginOTPKey(customer.ID), req.LogOTPToConsole)
+}
+
+// RequestPasswordlessLoginOTP sends a code that logs the customer in without
+// their password. The code is kept apart from the password login OTP, so it
+// never stands in for the second factor of a password login.
+func (lf *LoginFlowImpl) RequestPasswordlessLoginOTP(ctx context.Context, req *dto.PasswordlessLoginOTPRequest, metadata *ClientMetadata) (*dto.LoginOTPResponse, error) {
+	if !lf.passwordlessLogin {
+		return nil, NewBusinessError("PASSWORDLESS_LOGIN_DISABLED", "Passwordless login is disabled", ErrPasswordlessLoginDisabled)
+	}
+	if strings.TrimSpace(req.Identifier) == "" || strings.Contains(req.Identifier, "@") {
+		return nil, NewBusinessError("PASSWORDLESS_LOGIN_VALIDATION_FAILED", "Passwordless login validation failed", ErrCustomerNotFound)
 	}
+	if lf.rc == nil {
+		return nil, NewBusinessError("PASSWORDLESS_LOGIN_CACHE_UNAVAILABLE", "Cache not available", ErrCacheNotAvailable)
+	}
+	req.Identifier = normalizeLoginIdentifier(req.Identifier)
 
-	otpCode, err := generateOTP()
+	customer, err := lf.customerRepo.ByMobile(ctx, req.Identifier)
 	if err != nil {
-		return nil, err
+		return nil, NewBusinessError("PASSWORDLESS_LOGIN_REQUEST_FAILED", "Passwordless login request failed", err)
 	}
-
-	expiresAt := utils.UTCNowAdd(utils.OTPExpiry)
-	if err := lf.saveOTPState(ctx, key, otpCode, utils.OTPExpiry); err != nil {
-		return nil, err
+	if customer == nil || !utils.IsTrue(customer.IsActive) {
+		return nil, NewBusinessError("PASSWORDLESS_LOGIN_REQUEST_FAILED", "Passwordless login request failed", ErrCustomerNotFound)
+	}
+	// A login reported as not the customer's locks every login until the
+	// password is reset
+	if utils.IsTrue(customer.PasswordResetRequired) {
+		return nil, NewBusinessError("PASSWORDLESS_LOGIN_REQUEST_FAILED", "Passwordless login request failed", ErrPasswordResetRequired)
 	}
 
-	message := fmt.Sprintf(lf.messageConfig.SigninVerificationCodeTemplate, otpCode)
-	customerID := int64(customer.ID)
-	recipient, err := normalizeOTPMobile(customer.RepresentativeMobile)
+	resp, err := lf.sendLoginOTP(ctx, customer, lf.passwordlessLoginOTPKey(customer.ID), false)
 	if err != nil {
-		_ = lf.deleteOTPState(ctx, key)
-		return nil, err
+		return nil, NewBusinessError("PASSWORDLESS_LOGIN_REQUEST_FAILED", "Passwordless login request failed", err)
 	}
-	if req.LogOTPToConsole {
-		adminRecipient, err := normalizeOTPMobile(lf.adminConfig.ActiveLoginOTPForwardMobile())
+	if !resp.AlreadySent {
+		msg := fmt.Sprintf("Passwordless login code sent to customer %d", customer.ID)
+		_ = lf.createAuditLog(ctx, customer, models.AuditActionOTPLoginRequested, msg, true, nil, metadata)
+	}
+
+	return resp, nil
+}
+
+// PasswordlessLogin logs the customer in with the code RequestPasswordlessLoginOTP
+// sent to their mobile number. Failures count toward the same lockout as
+// password logins.
+func (lf *LoginFlowImpl) PasswordlessLogin(ctx context.Context, req *dto.PasswordlessLoginRequest, metadata *ClientMetadata) (*dto.LoginResponse, error) {
+	if !lf.passwordlessLogin {
+		return nil, NewBusinessError("PASSWORDLESS_LOGIN_DISABLED", "Passwordless login is disabled", ErrPasswordlessLoginDisabled)
+	}
+	if err := lf.validatePasswordlessLoginRequest(req); err != nil {
+		return nil, NewBusinessError("PASSWORDLESS_LOGIN_VALIDATION_FAILED", "Passwordless login validation failed", err)
+	}
+	req.Identifier = normalizeLoginIdentifier(req.Identifier)
+
+	if err := lf.enforceLoginRateLimit(ctx, req.Identifier, metadata); err != nil {
+		return nil, NewBusinessError("LOGIN_RATE_LIMITED", "Login failed", err)
+	}
+	if lf.rc == nil {
+		return nil, NewBusinessError("PASSWORDLESS_LOGIN_CACHE_UNAVAILABLE", "Cache not available", ErrCacheNotAvailable)
+	}
+
+	var customer *models.Customer
+	var session *models.CustomerSession
+	var resp *dto.LoginResponse
+	var newlyVerified bool
+
+	err := repository.WithTransaction(ctx, lf.db, func(txCtx context.Context) error {
+		var err error
+		customer, err = lf.customerRepo.ByMobile(txCtx, req.Identifier)
 		if err != nil {
-			_ = lf.deleteOTPState(ctx, key)
-			return nil, err
+			return err
 		}
-		runAsyncOTPTask(ctx, "RequestLoginOTP send OTP to admin", func(asyncCtx context.Context) error {
-			if err := lf.otpSMSSvc.SendOTP(asyncCtx, adminRecipient, message, &customerID); err != nil {
-				_ = lf.deleteOTPState(asyncCtx, key)
+		if customer == nil || !utils.IsTrue(customer.IsActive) {
+			return ErrAuthenticationFailed
+		}
+		if utils.IsTrue(customer.PasswordResetRequired) {
+			return ErrPasswordResetRequired
+		}
+
+		accountType, err := lf.accountTypeRepo.ByID(txCtx, customer.AccountTypeID)
+		if err != nil {
+			return err
+		}
+		if accountType == nil {
+			return ErrAccountTypeNotFound
+		}
+
+		if err := lf.verifyOTPState(txCtx, lf.passwordlessLoginOTPKey(customer.ID), req.OTPCode, true); err != nil {
+			return err
+		}
+
+		// The code proves the customer holds the mobile number
+		if !utils.IsTrue(customer.IsMobileVerified) {
+			if err := lf.completeSignupAfterLogin(txCtx, customer); err != nil {
 				return err
 			}
-			return nil
-		})
-	} else {
-		runAsyncOTPTask(ctx, "RequestLoginOTP send OTP", func(asyncCtx context.Context) error {
-			if err := lf.otpSMSSvc.SendOTP(asyncCtx, recipient, message, &customerID); err != nil {
-				_ = lf.deleteOTPState(asyncCtx, key)
+			newlyVerified = true
+			updated, err := lf.customerRepo.ByID(txCtx, customer.ID)
+			if err != nil {
 				return err
 			}
-			return nil
-		})
+			if updated != nil {
+				customer = updated
+			}
+		}
+
+		session, err = lf.createSession(txCtx, customer.ID, metadata)
+		if err != nil {
+			return err
+		}
+
+		resp = &dto.LoginResponse{
+			Customer: ToAuthCustomerDTO(*customer),
+			Session:  ToCustomerSessionDTO(*session),
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		if IsAuthenticationFailed(err) || IsNoValidOTPFound(err) || IsInvalidOTPCode(err) {
+			_ = lf.recordFailedLoginAttempt(ctx, req.Identifier, metadata)
+		}
+		errMsg := fmt.Sprintf("Passwordless login failed for identifier %s: %s", req.Identifier, err.Error())
+		_ = lf.createAuditLog(ctx, customer, models.AuditActionOTPLoginFailed, errMsg, false, &errMsg, metadata)
+
+		return nil, NewBusinessError("PASSWORDLESS_LOGIN_FAILED", "Login failed", err)
 	}
+	_ = lf.clearFailedLoginAttempts(ctx, req.Identifier, metadata)
+	activateSession(ctx, lf.sessionStore, session)
 
-	return &dto.LoginOTPResponse{
-		Message:     "OTP sent successfully",
-		CustomerID:  customer.ID,
-		MaskedPhone: dto.MaskPhoneNumber(customer.RepresentativeMobile),
-		OTPSent:     true,
-		AlreadySent: false,
-		OTPExpiry:   expiresAt,
-	}, nil
+	if newlyVerified {
+		msg := fmt.Sprintf("Signup completed successfully for customer %d", customer.ID)
+		_ = lf.createAuditLog(ctx, customer, models.AuditActionSignupCompleted, msg, true, nil, metadata)
+	}
+
+	msg := fmt.Sprintf("User logged in with an SMS code for identifier %s", req.Identifier)
+	_ = lf.createAuditLog(ctx, customer, models.AuditActionOTPLoginSuccess, msg, true, nil, metadata)
+
+	lf.checkNewDevice(ctx, customer, metadata)
+
+	return resp, nil
 }
 
 // ForgotPassword initiates the password reset process
@@ -284,13 +414,18 @@ func (lf *LoginFlowImpl) ForgotPassword(ctx context.Context, req *dto.ForgotPass
 			return ErrAccountInactive
 		}
 
+		resendWait, err := lf.otpThrottle.Reserve(txCtx, customer.RepresentativeMobile)
+		if err != nil {
+			return err
+		}
+
 		// Generate new OTP (Redis)
 		otpCode, expiresAt, err := lf.generateAndSavePasswordResetOTP(txCtx, customer)
 		if err != nil {
 			return err
 		}
 
-		otpMessage = fmt.Sprintf(lf.messageConfig.PasswordResetVerificationCodeTemplate, otpCode)
+		otpMessage = lf.localizer.Customer(customer, "otp.password_reset_code", i18n.Args{"Code": otpCode, "Minutes": utils.OTPExpiry.Minutes()})
 		otpCustomerID = int64(customer.ID)
 		otpRecipient, err = normalizeOTPMobile(customer.RepresentativeMobile)
 		if err != nil {
@@ -300,9 +435,10 @@ func (lf *LoginFlowImpl) ForgotPassword(ctx context.Context, req *dto.ForgotPass
 		otpKey = lf.passwordResetOTPKey(customer.ID)
 
 		resp = &dto.ForgetPasswordResponse{
-			CustomerID:  customer.ID,
-			MaskedPhone: dto.MaskPhoneNumber(customer.RepresentativeMobile),
-			OTPExpiry:   expiresAt,
+			CustomerID:        customer.ID,
+			MaskedPhone:       dto.MaskPhoneNumber(customer.RepresentativeMobile),
+			OTPExpiry:         expiresAt,
+			ResendAvailableIn: ceilSeconds(resendWait),
 		}
 
 		return nil
@@ -339,8 +475,14 @@ func (lf *LoginFlowImpl) ResetPassword(ctx context.Context, req *dto.ResetPasswo
 	if err := lf.validateResetPasswordRequest(req); err != nil {
 		return nil, NewBusinessError("RESET_PASSWORD_VALIDATION_FAILED", "Reset password validation failed", err)
 	}
+	// Checked before the OTP is verified so a refused password does not use
+	// up the code
+	if err := checkPasswordPolicy(ctx, lf.passwordPolicy, req.NewPassword); err != nil {
+		return nil, NewBusinessError("RESET_PASSWORD_VALIDATION_FAILED", "Reset password validation failed", err)
+	}
 
 	var customer models.Customer
+	var session *models.CustomerSession
 	var resp *dto.ResetPasswordResponse
 
 	// Start transaction for password reset completion
@@ -358,16 +500,21 @@ func (lf *LoginFlowImpl) ResetPassword(ctx context.Context, req *dto.ResetPasswo
 		}
 
 		// Hash the new password
-		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+		hashedPassword, err := lf.passwordHasher.Hash(req.NewPassword)
 		if err != nil {
 			return err
 		}
 
 		// Update customer password (maintain referential integrity)
-		err = lf.customerRepo.UpdatePassword(txCtx, customer.ID, string(hashedPassword))
+		err = lf.customerRepo.UpdatePassword(txCtx, customer.ID, hashedPassword)
 		if err != nil {
 			return err
 		}
+		if utils.IsTrue(customer.PasswordResetRequired) {
+			if err := lf.customerRepo.SetPasswordResetRequired(txCtx, customer.ID, false); err != nil {
+				return err
+			}
+		}
 
 		// Invalidate all existing sessions for this customer
 		if err := lf.invalidateAllSessions(txCtx, customer.ID); err != nil {
@@ -375,7 +522,7 @@ func (lf *LoginFlowImpl) ResetPassword(ctx context.Context, req *dto.ResetPasswo
 		}
 
 		// Create new session for the user
-		session, err := lf.createSession(txCtx, customer.ID, metadata)
+		session, err = lf.createSession(txCtx, customer.ID, metadata)
 		if err != nil {
 			return err
 		}
@@ -395,14 +542,499 @@ func (lf *LoginFlowImpl) ResetPassword(ctx context.Context, req *dto.ResetPasswo
 		return nil, NewBusinessError("PASSWORD_RESET_FAILED", "Password reset failed", err)
 	}
 
+	// Revoke the old sessions before mirroring the new one so it carries the
+	// bumped session version
+	revokeCustomerSessions(ctx, lf.sessionStore, customer.ID)
+	activateSession(ctx, lf.sessionStore, session)
+	if err := lf.loginDetector.Remember(ctx, customer.ID, metadata); err != nil {
+		log.Errorf("remember device of customer %d: %v", customer.ID, err)
+	}
+
 	msg := fmt.Sprintf("Password reset completed successfully for customer %d", req.CustomerID)
 	_ = lf.createAuditLog(ctx, &customer, models.AuditActionPasswordResetCompleted, msg, true, nil, metadata)
 
 	return resp, nil
 }
 
+// ChangePassword replaces the password of a logged in customer after checking
+// their current one. Every other session of the customer is ended; the
+// session of currentToken stays logged in.
+func (lf *LoginFlowImpl) ChangePassword(ctx context.Context, customerID uint, currentToken string, req *dto.ChangePasswordRequest, metadata *ClientMetadata) (*dto.ChangePasswordResponse, error) {
+	if req.NewPassword != req.ConfirmPassword {
+		return nil, NewBusinessError("CHANGE_PASSWORD_VALIDATION_FAILED", "Password confirmation does not match", ErrIncorrectPassword)
+	}
+
+	customer, err := getCustomer(ctx, lf.customerRepo, customerID)
+	if err != nil {
+		return nil, NewBusinessError("CHANGE_PASSWORD_FAILED", "Change password failed", err)
+	}
+	if ok, err := lf.passwordHasher.Verify(req.CurrentPassword, customer.PasswordHash); err != nil || !ok {
+		errMsg := ErrIncorrectPassword.Error()
+		_ = lf.createAuditLog(ctx, &customer, models.AuditActionPasswordChangeFailed, "Password change failed: incorrect current password", false, &errMsg, metadata)
+		return nil, NewBusinessError("INCORRECT_PASSWORD", "Incorrect password", ErrIncorrectPassword)
+	}
+	if err := checkPasswordPolicy(ctx, lf.passwordPolicy, req.NewPassword); err != nil {
+		return nil, NewBusinessError("CHANGE_PASSWORD_VALIDATION_FAILED", "Change password validation failed", err)
+	}
+
+	hashedPassword, err := lf.passwordHasher.Hash(req.NewPassword)
+	if err != nil {
+		return nil, NewBusinessError("CHANGE_PASSWORD_FAILED", "Change password failed", err)
+	}
+
+	var ended int
+	err = repository.WithTransaction(ctx, lf.db, func(txCtx context.Context) error {
+		if err := lf.customerRepo.UpdatePassword(txCtx, customer.ID, hashedPassword); err != nil {
+			return err
+		}
+		sessions, err := lf.sessionRepo.ListActiveSessionsByCustomer(txCtx, customer.ID)
+		if err != nil {
+			return err
+		}
+		for _, session := range sessions {
+			if session.SessionToken == currentToken {
+				continue
+			}
+			if err := lf.endSession(txCtx, session); err != nil {
+				return err
+			}
+			ended++
+		}
+		return nil
+	})
+	if err != nil {
+		errMsg := fmt.Sprintf("Password change failed for customer %d: %s", customer.ID, err.Error())
+		_ = lf.createAuditLog(ctx, &customer, models.AuditActionPasswordChangeFailed, errMsg, false, &errMsg, metadata)
+		return nil, NewBusinessError("CHANGE_PASSWORD_FAILED", "Change password failed", err)
+	}
+
+	msg := fmt.Sprintf("Customer %d changed their password; %d other sessions ended", customer.ID, ended)
+	_ = lf.createAuditLog(ctx, &customer, models.AuditActionPasswordChanged, msg, true, nil, metadata)
+
+	return &dto.ChangePasswordResponse{
+		Message:       "Password changed successfully",
+		SessionsEnded: ended,
+	}, nil
+}
+
+// RequestMobileChange starts replacing the representative mobile of a logged
+// in customer. The current password is checked, and a code is sent to the new
+// mobile so the customer proves they own it; ConfirmMobileChange completes
+// the change. Requesting again replaces the pending mobile and code.
+func (lf *LoginFlowImpl) RequestMobileChange(ctx context.Context, customerID uint, req *dto.MobileChangeRequest, metadata *ClientMetadata) (*dto.MobileChangeOTPResponse, error) {
+	if lf.rc == nil {
+		return nil, NewBusinessError("MOBILE_CHANGE_FAILED", "Mobile change failed", ErrCacheNotAvailable)
+	}
+
+	customer, err := getCustomer(ctx, lf.customerRepo, customerID)
+	if err != nil {
+		return nil, NewBusinessError("MOBILE_CHANGE_FAILED", "Mobile change failed", err)
+	}
+	if ok, err := lf.passwordHasher.Verify(req.CurrentPassword, customer.PasswordHash); err != nil || !ok {
+		errMsg := ErrIncorrectPassword.Error()
+		_ = lf.createAuditLog(ctx, &customer, models.AuditActionMobileChangeFailed, "Mobile change failed: incorrect current password", false, &errMsg, metadata)
+		return nil, NewBusinessError("INCORRECT_PASSWORD", "Incorrect password", ErrIncorrectPassword)
+	}
+
+	newMobile, err := phonenumber.Canonical(req.NewMobile)
+	if err != nil {
+		return nil, NewBusinessError("MOBILE_CHANGE_VALIDATION_FAILED", "Mobile change validation failed", ErrInvalidMobileNumber)
+	}
+	if err := lf.checkMobileAvailable(ctx, &customer, newMobile); err != nil {
+		return nil, NewBusinessError("MOBILE_CHANGE_VALIDATION_FAILED", "Mobile change validation failed", err)
+	}
+	recipient, err := normalizeOTPMobile(newMobile)
+	if err != nil {
+		return nil, NewBusinessError("MOBILE_CHANGE_VALIDATION_FAILED", "Mobile change validation failed", ErrInvalidMobileNumber)
+	}
+
+	resendWait, err := lf.otpThrottle.Reserve(ctx, newMobile)
+	if err != nil {
+		return nil, NewBusinessError("MOBILE_CHANGE_FAILED", "Mobile change failed", err)
+	}
+	otpCode, err := generateOTP()
+	if err != nil {
+		return nil, NewBusinessError("MOBILE_CHANGE_FAILED", "Mobile change failed", err)
+	}
+
+	otpKey := lf.mobileChangeOTPKey(customer.ID)
+	expiresAt := utils.UTCNowAdd(utils.OTPExpiry)
+	if err := lf.rc.Set(ctx, lf.mobileChangeTargetKey(customer.ID), newMobile, utils.OTPExpiry).Err(); err != nil {
+		return nil, NewBusinessError("MOBILE_CHANGE_FAILED", "Mobile change failed", err)
+	}
+	if err := lf.saveOTPState(ctx, otpKey, otpCode, utils.OTPExpiry); err != nil {
+		return nil, NewBusinessError("MOBILE_CHANGE_FAILED", "Mobile change failed", err)
+	}
+
+	message := lf.localizer.Customer(&customer, "otp.mobile_change_code", i18n.Args{"Code": otpCode, "Minutes": utils.OTPExpiry.Minutes()})
+	otpCustomerID := int64(customer.ID)
+	runAsyncOTPTask(ctx, "send mobile change OTP", func(asyncCtx context.Context) error {
+		if err := lf.otpSMSSvc.SendOTP(asyncCtx, recipient, message, &otpCustomerID); err != nil {
+			_ = lf.deleteOTPState(asyncCtx, otpKey)
+			return err
+		}
+		return nil
+	})
+
+	msg := fmt.Sprintf("Customer %d requested to change their mobile to %s", customer.ID, dto.MaskPhoneNumber(newMobile))
+	_ = lf.createAuditLog(ctx, &customer, models.AuditActionMobileChangeRequested, msg, true, nil, metadata)
+
+	return &dto.MobileChangeOTPResponse{
+		Message:           "OTP sent to the new mobile number",
+		MaskedPhone:       dto.MaskPhoneNumber(newMobile),
+		OTPExpiry:         expiresAt,
+		ResendAvailableIn: ceilSeconds(resendWait),
+	}, nil
+}
+
+// ConfirmMobileChange replaces the representative mobile with the one pending
+// from RequestMobileChange once the code sent to it is verified. Every other
+// session of the customer is ended; the session of currentToken stays logged
+// in.
+func (lf *LoginFlowImpl) ConfirmMobileChange(ctx context.Context, customerID uint, currentToken string, req *dto.MobileChangeConfirmRequest, metadata *ClientMetadata) (*dto.MobileChangeResponse, error) {
+	if lf.rc == nil {
+		return nil, NewBusinessError("MOBILE_CHANGE_FAILED", "Mobile change failed", ErrCacheNotAvailable)
+	}
+	if !isSixDigitCode(req.OTPCode) {
+		return nil, NewBusinessError("MOBILE_CHANGE_VALIDATION_FAILED", "Mobile change validation failed", ErrInvalidOTPCode)
+	}
+
+	customer, err := getCustomer(ctx, lf.customerRepo, customerID)
+	if err != nil {
+		return nil, NewBusinessError("MOBILE_CHANGE_FAILED", "Mobile change failed", err)
+	}
+
+	if err := lf.verifyOTPState(ctx, lf.mobileChangeOTPKey(customer.ID), req.OTPCode, true); err != nil {
+		errMsg := err.Error()
+		_ = lf.createAuditLog(ctx, &customer, models.AuditActionMobileChangeFailed, "Mobile change failed: OTP verification failed", false, &errMsg, metadata)
+		return nil, NewBusinessError("MOBILE_CHANGE_FAILED", "Mobile change failed", err)
+	}
+	targetKey := lf.mobileChangeTargetKey(customer.ID)
+	newMobile, err := lf.rc.Get(ctx, targetKey).Result()
+	if err != nil {
+		if err == redis.Nil {
+			err = ErrNoValidOTPFound
+		}
+		return nil, NewBusinessError("MOBILE_CHANGE_FAILED", "Mobile change failed", err)
+	}
+	_ = lf.rc.Del(ctx, targetKey).Err()
+
+	// The mobile may have been taken while the code was outstanding
+	if err := lf.checkMobileAvailable(ctx, &customer, newMobile); err != nil {
+		errMsg := err.Error()
+		_ = lf.createAuditLog(ctx, &customer, models.AuditActionMobileChangeFailed, "Mobile change failed: mobile no longer available", false, &errMsg, metadata)
+		return nil, NewBusinessError("MOBILE_CHANGE_VALIDATION_FAILED", "Mobile change validation failed", err)
+	}
+
+	oldMobile := customer.RepresentativeMobile
+	var ended int
+	err = repository.WithTransaction(ctx, lf.db, func(txCtx context.Context) error {
+		if err := lf.customerRepo.UpdateRepresentativeMobile(txCtx, customer.ID, newMobile, utils.UTCNow()); err != nil {
+			return err
+		}
+		sessions, err := lf.sessionRepo.ListActiveSessionsByCustomer(txCtx, customer.ID)
+		if err != nil {
+			return err
+		}
+		for _, session := range sessions {
+			if session.SessionToken == currentToken {
+				continue
+			}
+			if err := lf.endSession(txCtx, session); err != nil {
+				return err
+			}
+			ended++
+		}
+		return nil
+	})
+	if err != nil {
+		errMsg := fmt.Sprintf("Mobile change failed for customer %d: %s", customer.ID, err.Error())
+		_ = lf.createAuditLog(ctx, &customer, models.AuditActionMobileChangeFailed, errMsg, false, &errMsg, metadata)
+		return nil, NewBusinessError("MOBILE_CHANGE_FAILED", "Mobile change failed", err)
+	}
+
+	msg := fmt.Sprintf("Customer %d changed their mobile from %s to %s; %d other sessions ended",
+		customer.ID, dto.MaskPhoneNumber(oldMobile), dto.MaskPhoneNumber(newMobile), ended)
+	_ = lf.createAuditLog(ctx, &customer, models.AuditActionMobileChanged, msg, true, nil, metadata)
+
+	return &dto.MobileChangeResponse{
+		Message:       "Mobile number changed successfully",
+		MaskedPhone:   dto.MaskPhoneNumber(newMobile),
+		SessionsEnded: ended,
+	}, nil
+}
+
+// ResendOTP sends a new code for an outstanding login, passwordless login or
+// password reset OTP, replacing the previous one. It cannot start a new login
+// or reset.
+func (lf *LoginFlowImpl) ResendOTP(ctx context.Context, req *dto.LoginOTPResendRequest, metadata *ClientMetadata) (*dto.OTPResendResponse, error) {
+	if lf.rc == nil {
+		return nil, NewBusinessError("RESEND_OTP_FAILED", "Resend OTP failed", ErrCacheNotAvailable)
+	}
+
+	var key, messageKey string
+	switch req.Purpose {
+	case OTPPurposeLogin:
+		key = lf.loginOTPKey(req.CustomerID)
+		messageKey = "otp.signin_code"
+	case OTPPurposePasswordlessLogin:
+		if !lf.passwordlessLogin {
+			return nil, NewBusinessError("PASSWORDLESS_LOGIN_DISABLED", "Passwordless login is disabled", ErrPasswordlessLoginDisabled)
+		}
+		key = lf.passwordlessLoginOTPKey(req.CustomerID)
+		messageKey = "otp.signin_code"
+	case OTPPurposePasswordReset:
+		key = lf.passwordResetOTPKey(req.CustomerID)
+		messageKey = "otp.password_reset_code"
+	default:
+		return nil, NewBusinessError("OTP_RESEND_VALIDATION_FAILED", "OTP resend validation failed", ErrInvalidOTPType)
+	}
+
+	customer, err := getCustomer(ctx, lf.customerRepo, req.CustomerID)
+	if err != nil {
+		return nil, NewBusinessError("RESEND_OTP_FAILED", "Resend OTP failed", err)
+	}
+	if _, _, err := lf.getOTPState(ctx, key); err != nil {
+		return nil, NewBusinessError("RESEND_OTP_FAILED", "Resend OTP failed", err)
+	}
+	recipient, err := normalizeOTPMobile(customer.RepresentativeMobile)
+	if err != nil {
+		return nil, NewBusinessError("RESEND_OTP_FAILED", "Resend OTP failed", err)
+	}
+
+	resendWait, err := lf.otpThrottle.Reserve(ctx, customer.RepresentativeMobile)
+	if err != nil {
+		return nil, NewBusinessError("RESEND_OTP_FAILED", "Resend OTP failed", err)
+	}
+
+	otpCode, err := generateOTP()
+	if err != nil {
+		return nil, NewBusinessError("RESEND_OTP_FAILED", "Resend OTP failed", err)
+	}
+	if err := lf.saveOTPState(ctx, key, otpCode, utils.OTPExpiry); err != nil {
+		return nil, NewBusinessError("RESEND_OTP_FAILED", "Resend OTP failed", err)
+	}
+
+	message := lf.localizer.Customer(&customer, messageKey, i18n.Args{"Code": otpCode, "Minutes": utils.OTPExpiry.Minutes()})
+	customerID := int64(customer.ID)
+	runAsyncOTPTask(ctx, "ResendOTP send "+req.Purpose+" OTP", func(asyncCtx context.Context) error {
+		if err := lf.otpSMSSvc.SendOTP(asyncCtx, recipient, message, &customerID); err != nil {
+			_ = lf.deleteOTPState(asyncCtx, key)
+			return err
+		}
+		return nil
+	})
+
+	return &dto.OTPResendResponse{
+		Message:           "OTP resent successfully",
+		OTPSent:           true,
+		MaskedOTPTarget:   maskOTPTarget(customer.RepresentativeMobile),
+		ResendAvailableIn: ceilSeconds(resendWait),
+	}, nil
+}
+
+// Logout ends the session of accessToken. The session is deactivated in the
+// database and revoked in the session store so the token stops working at
+// once.
+func (lf *LoginFlowImpl) Logout(ctx context.Context, customerID uint, accessToken string, metadata *ClientMetadata) error {
+	session, err := lf.sessionRepo.BySessionToken(ctx, accessToken)
+	if err != nil {
+		return NewBusinessError("LOGOUT_FAILED", "Logout failed", err)
+	}
+	if session == nil || session.CustomerID != customerID {
+		return NewBusinessError("LOGOUT_FAILED", "Logout failed", ErrSessionNotFound)
+	}
+
+	if err := lf.endSession(ctx, session); err != nil {
+		return NewBusinessError("LOGOUT_FAILED", "Logout failed", err)
+	}
+
+	msg := fmt.Sprintf("Customer %d logged out", customerID)
+	_ = lf.createAuditLog(ctx, &models.Customer{ID: customerID}, models.AuditActionLogout, msg, true, nil, metadata)
+
+	return nil
+}
+
+// ListSessions returns the active sessions of a customer, most recently used
+// first. The session of currentToken is marked as the current one.
+func (lf *LoginFlowImpl) ListSessions(ctx context.Context, customerID uint, currentToken string) (*dto.ListSessionsResponse, error) {
+	sessions, err := lf.sessionRepo.ListActiveSessionsByCustomer(ctx, customerID)
+	if err != nil {
+		return nil, NewBusinessError("LIST_SESSIONS_FAILED", "Failed to list sessions", err)
+	}
+	sort.SliceStable(sessions, func(i, j int) bool {
+		return sessions[i].LastAccessedAt.After(sessions[j].LastAccessedAt)
+	})
+
+	items := make([]dto.ActiveSessionDTO, 0, len(sessions))
+	for _, session := range sessions {
+		items = append(items, ToActiveSessionDTO(session, session.SessionToken == currentToken))
+	}
+	return &dto.ListSessionsResponse{
+		Message:  "Sessions retrieved successfully",
+		Sessions: items,
+	}, nil
+}
+
+// RevokeSession ends one active session of a customer, e.g. a lost device
+func (lf *LoginFlowImpl) RevokeSession(ctx context.Context, customerID, sessionID uint, metadata *ClientMetadata) error {
+	session, err := lf.sessionRepo.ByID(ctx, sessionID)
+	if err != nil {
+		return NewBusinessError("REVOKE_SESSION_FAILED", "Failed to revoke session", err)
+	}
+	if session == nil || session.CustomerID != customerID || !session.IsValid() {
+		return NewBusinessError("REVOKE_SESSION_FAILED", "Failed to revoke session", ErrSessionNotFound)
+	}
+
+	if err := lf.endSession(ctx, session); err != nil {
+		return NewBusinessError("REVOKE_SESSION_FAILED", "Failed to revoke session", err)
+	}
+
+	msg := fmt.Sprintf("Customer %d revoked session %d", customerID, sessionID)
+	_ = lf.createAuditLog(ctx, &models.Customer{ID: customerID}, models.AuditActionSessionRevoked, msg, true, nil, metadata)
+
+	return nil
+}
+
 // Private helper methods
 
+// ReportLoginNotMe handles the link of a new-device login alert: every
+// session of the customer is ended and logging in is refused until the
+// password is reset
+func (lf *LoginFlowImpl) ReportLoginNotMe(ctx context.Context, req *dto.LoginAlertReportRequest, metadata *ClientMetadata) (*dto.LoginAlertReportResponse, error) {
+	if strings.TrimSpace(req.Token) == "" {
+		return nil, NewBusinessError("LOGIN_ALERT_REPORT_VALIDATION_FAILED", "Login alert report validation failed", ErrLoginAlertNotFound)
+	}
+
+	alert, err := lf.loginDetector.Resolve(ctx, strings.TrimSpace(req.Token))
+	if err != nil {
+		return nil, NewBusinessError("LOGIN_ALERT_REPORT_FAILED", "Reporting the login failed", err)
+	}
+
+	var customer models.Customer
+	err = repository.WithTransaction(ctx, lf.db, func(txCtx context.Context) error {
+		var err error
+		customer, err = getCustomer(txCtx, lf.customerRepo, alert.CustomerID)
+		if err != nil {
+			return err
+		}
+		if err := lf.customerRepo.SetPasswordResetRequired(txCtx, customer.ID, true); err != nil {
+			return err
+		}
+		return lf.invalidateAllSessions(txCtx, customer.ID)
+	})
+	if err != nil {
+		errMsg := fmt.Sprintf("Reporting login of customer %d as not theirs failed: %s", alert.CustomerID, err.Error())
+		_ = lf.createAuditLog(ctx, &customer, models.AuditActionLoginReportedNotMe, errMsg, false, &errMsg, metadata)
+
+		return nil, NewBusinessError("LOGIN_ALERT_REPORT_FAILED", "Reporting the login failed", err)
+	}
+
+	revokeCustomerSessions(ctx, lf.sessionStore, customer.ID)
+	if err := lf.loginDetector.Forget(ctx, alert); err != nil {
+		log.Errorf("forget reported device of customer %d: %v", customer.ID, err)
+	}
+
+	msg := fmt.Sprintf("Login of customer %d from IP range %s reported as not theirs", customer.ID, alert.IPRange)
+	_ = lf.createAuditLog(ctx, &customer, models.AuditActionLoginReportedNotMe, msg, true, nil, metadata)
+
+	return &dto.LoginAlertReportResponse{
+		Message:     "All sessions were ended. Reset your password to log in again.",
+		MaskedPhone: dto.MaskPhoneNumber(customer.RepresentativeMobile),
+	}, nil
+}
+
+// sendLoginOTP sends a login code for customer stored under key, or reports
+// the outstanding one. With forwardToAdmin the code goes to the configured
+// admin mobile instead of the customer.
+func (lf *LoginFlowImpl) sendLoginOTP(ctx context.Context, customer *models.Customer, key string, forwardToAdmin bool) (*dto.LoginOTPResponse, error) {
+	if _, ttl, err := lf.getOTPState(ctx, key); err == nil {
+		wait, err := lf.otpThrottle.Wait(ctx, customer.RepresentativeMobile)
+		if err != nil {
+			return nil, err
+		}
+		return &dto.LoginOTPResponse{
+			Message:           "OTP already generated and sent",
+			CustomerID:        customer.ID,
+			MaskedPhone:       dto.MaskPhoneNumber(customer.RepresentativeMobile),
+			OTPSent:           true,
+			AlreadySent:       true,
+			OTPExpiry:         utils.UTCNowAdd(ttl),
+			ResendAvailableIn: ceilSeconds(wait),
+		}, nil
+	} else if err != nil && err != ErrNoValidOTPFound {
+		return nil, err
+	}
+
+	resendWait, err := lf.otpThrottle.Reserve(ctx, customer.RepresentativeMobile)
+	if err != nil {
+		return nil, err
+	}
+
+	otpCode, err := generateOTP()
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt := utils.UTCNowAdd(utils.OTPExpiry)
+	if err := lf.saveOTPState(ctx, key, otpCode, utils.OTPExpiry); err != nil {
+		return nil, err
+	}
+
+	message := lf.localizer.Customer(customer, "otp.signin_code", i18n.Args{"Code": otpCode})
+	customerID := int64(customer.ID)
+	recipient, err := normalizeOTPMobile(customer.RepresentativeMobile)
+	if err != nil {
+		_ = lf.deleteOTPState(ctx, key)
+		return nil, err
+	}
+	if forwardToAdmin {
+		adminRecipient, err := normalizeOTPMobile(lf.adminConfig.ActiveLoginOTPForwardMobile())
+		if err != nil {
+			_ = lf.deleteOTPState(ctx, key)
+			return nil, err
+		}
+		runAsyncOTPTask(ctx, "send login OTP to admin", func(asyncCtx context.Context) error {
+			if err := lf.otpSMSSvc.SendOTP(asyncCtx, adminRecipient, message, &customerID); err != nil {
+				_ = lf.deleteOTPState(asyncCtx, key)
+				return err
+			}
+			return nil
+		})
+	} else {
+		runAsyncOTPTask(ctx, "send login OTP", func(asyncCtx context.Context) error {
+			if err := lf.otpSMSSvc.SendOTP(asyncCtx, recipient, message, &customerID); err != nil {
+				_ = lf.deleteOTPState(asyncCtx, key)
+				return err
+			}
+			return nil
+		})
+	}
+
+	return &dto.LoginOTPResponse{
+		Message:           "OTP sent successfully",
+		CustomerID:        customer.ID,
+		MaskedPhone:       dto.MaskPhoneNumber(customer.RepresentativeMobile),
+		OTPSent:           true,
+		AlreadySent:       false,
+		OTPExpiry:         expiresAt,
+		ResendAvailableIn: ceilSeconds(resendWait),
+	}, nil
+}
+
+// checkNewDevice records a login from a device or IP range the customer never
+// used; the detector alerts the customer
+func (lf *LoginFlowImpl) checkNewDevice(ctx context.Context, customer *models.Customer, metadata *ClientMetadata) {
+	suspicious, err := lf.loginDetector.Check(ctx, customer, metadata)
+	if err != nil {
+		log.Errorf("check login of customer %d for a new device: %v", customer.ID, err)
+	}
+	if suspicious {
+		msg := fmt.Sprintf("Login from a new device or IP range for customer %d", customer.ID)
+		_ = lf.createAuditLog(ctx, customer, models.AuditActionSuspiciousLogin, msg, true, nil, metadata)
+	}
+}
+
 func (lf *LoginFlowImpl) findCustomerByIdentifier(ctx context.Context, identifier string) (*models.Customer, error) {
 	identifier = normalizeLoginIdentifier(identifier)
 
@@ -439,6 +1071,23 @@ func (lf *LoginFlowImpl) findCustomerByIdentifier(ctx context.Context, identifie
 	return nil, nil
 }
 
+// upgradePasswordHash replaces a bcrypt hash, or an Argon2id hash with outdated
+// parameters, after the password was verified. A failure only delays the
+// upgrade to the next login.
+func (lf *LoginFlowImpl) upgradePasswordHash(ctx context.Context, customer *models.Customer, password string) {
+	if customer == nil || !lf.passwordHasher.NeedsRehash(customer.PasswordHash) {
+		return
+	}
+	hashedPassword, err := lf.passwordHasher.Hash(password)
+	if err != nil {
+		log.Errorf("upgrade password hash for customer %d: %v", customer.ID, err)
+		return
+	}
+	if err := lf.customerRepo.UpdatePassword(ctx, customer.ID, hashedPassword); err != nil {
+		log.Errorf("upgrade password hash for customer %d: %v", customer.ID, err)
+	}
+}
+
 func (lf *LoginFlowImpl) createSession(ctx context.Context, customerID uint, metadata *ClientMetadata) (*models.CustomerSession, error) {
 	// Generate tokens
 	accessToken, refreshToken, err := lf.tokenService.GenerateTokens(customerID)
@@ -455,11 +1104,11 @@ func (lf *LoginFlowImpl) createSession(ctx context.Context, customerID uint, met
 
 	// Create session record
 	session := &models.CustomerSession{
-		CorrelationID: uuid.New(),
-		CustomerID:    customerID,
-		SessionToken:  accessToken,
-		RefreshToken:  &refreshToken,
-		// DeviceInfo:    json.RawMessage, // TODO: Add device info
+		CorrelationID:  uuid.New(),
+		CustomerID:     customerID,
+		SessionToken:   accessToken,
+		RefreshToken:   &refreshToken,
+		DeviceInfo:     sessionDeviceInfo(metadata),
 		IPAddress:      &ipAddress,
 		UserAgent:      &userAgent,
 		IsActive:       utils.ToPtr(true),
@@ -489,13 +1138,6 @@ func (lf *LoginFlowImpl) generateAndSavePasswordResetOTP(ctx context.Context, cu
 		return "", time.Time{}, ErrCacheNotAvailable
 	}
 	key := lf.passwordResetOTPKey(customer.ID)
-	if existing, ttl, err := lf.getOTPState(ctx, key); err == nil {
-		if ttl > 0 && utils.UTCNow().Sub(existing.LastSentAt) < authOTPResendCooldown {
-			return "", time.Time{}, ErrRateLimitExceeded
-		}
-	} else if err != ErrNoValidOTPFound {
-		return "", time.Time{}, err
-	}
 
 	otpCode, err := generateOTP()
 	if err != nil {
@@ -527,6 +1169,22 @@ func (lf *LoginFlowImpl) verifyPasswordResetOTP(ctx context.Context, customerID
 	return lf.verifyOTPState(ctx, key, otpCode, true)
 }
 
+// endSession deactivates a session and revokes its token in the session store
+func (lf *LoginFlowImpl) endSession(ctx context.Context, session *models.CustomerSession) error {
+	expiresAt := session.ExpiresAt
+	session.IsActive = utils.ToPtr(false)
+	session.ExpiresAt = utils.UTCNow()
+	if err := lf.sessionRepo.Update(ctx, session); err != nil {
+		return err
+	}
+	if lf.sessionStore != nil {
+		if err := lf.sessionStore.Revoke(ctx, session.SessionToken, expiresAt); err != nil {
+			log.Errorf("revoke session %d of customer %d: %v", session.ID, session.CustomerID, err)
+		}
+	}
+	return nil
+}
+
 func (lf *LoginFlowImpl) invalidateAllSessions(ctx context.Context, customerID uint) error {
 	// Find all active sessions for this customer
 	filter := models.CustomerSessionFilter{
@@ -615,6 +1273,16 @@ func (lf *LoginFlowImpl) validateLoginOTPRequest(request *dto.LoginOTPRequest) e
 	return nil
 }
 
+func (lf *LoginFlowImpl) validatePasswordlessLoginRequest(request *dto.PasswordlessLoginRequest) error {
+	if strings.TrimSpace(request.Identifier) == "" || strings.Contains(request.Identifier, "@") {
+		return ErrAuthenticationFailed
+	}
+	if !isSixDigitCode(request.OTPCode) {
+		return ErrInvalidOTPCode
+	}
+	return nil
+}
+
 func (lf *LoginFlowImpl) validateForgotPasswordRequest(request *dto.ForgotPasswordRequest) error {
 	// Validate identifier is not empty
 	if strings.TrimSpace(request.Identifier) == "" {
@@ -632,7 +1300,7 @@ func (lf *LoginFlowImpl) completeSignupAfterLogin(ctx context.Context, customer
 	}
 
 	if lf.notificationSvc != nil {
-		adminMsg := fmt.Sprintf("New user verified: %s %s", customer.RepresentativeFirstName, customer.RepresentativeLastName)
+		adminMsg := lf.localizer.Admin("admin.customer_verified", i18n.Args{"FirstName": customer.RepresentativeFirstName, "LastName": customer.RepresentativeLastName})
 		for _, mobile := range lf.adminConfig.ActiveMobiles() {
 			_ = lf.notificationSvc.SendSMS(ctx, mobile, adminMsg, utils.ToPtr(int64(customer.ID)))
 		}
@@ -664,10 +1332,40 @@ func (lf *LoginFlowImpl) loginOTPKey(customerID uint) string {
 	return fmt.Sprintf("login:otp:%d", customerID)
 }
 
+func (lf *LoginFlowImpl) passwordlessLoginOTPKey(customerID uint) string {
+	return fmt.Sprintf("passwordless_login:otp:%d", customerID)
+}
+
 func (lf *LoginFlowImpl) passwordResetOTPKey(customerID uint) string {
 	return fmt.Sprintf("password_reset:otp:%d", customerID)
 }
 
+func (lf *LoginFlowImpl) mobileChangeOTPKey(customerID uint) string {
+	return fmt.Sprintf("mobile_change:otp:%d", customerID)
+}
+
+// mobileChangeTargetKey holds the mobile a pending change replaces the
+// current one with
+func (lf *LoginFlowImpl) mobileChangeTargetKey(customerID uint) string {
+	return fmt.Sprintf("mobile_change:mobile:%d", customerID)
+}
+
+// checkMobileAvailable refuses mobile when it is the customer's current one or
+// belongs to another customer
+func (lf *LoginFlowImpl) checkMobileAvailable(ctx context.Context, customer *models.Customer, mobile string) error {
+	if mobile == customer.RepresentativeMobile {
+		return ErrMobileUnchanged
+	}
+	existing, err := lf.customerRepo.ByMobile(ctx, mobile)
+	if err != nil {
+		return err
+	}
+	if existing != nil && existing.ID != customer.ID {
+		return ErrMobileAlreadyExists
+	}
+	return nil
+}
+
 func (lf *LoginFlowImpl) saveOTPState(ctx context.Context, key, code string, ttl time.Duration) error {
 	state := otpChallengeState{
 		OTPHash:    hashOTPCode(code),
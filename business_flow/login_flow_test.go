@@ -0,0 +1,77 @@
+package businessflow
+
+import (
+	"context"
+	"testing"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+)
+
+func TestPasswordlessLoginDisabled(t *testing.T) {
+	lf := &LoginFlowImpl{}
+	ctx := context.Background()
+
+	if _, err := lf.RequestPasswordlessLoginOTP(ctx, &dto.PasswordlessLoginOTPRequest{Identifier: "+989123456789"}, nil); !IsPasswordlessLoginDisabled(err) {
+		t.Fatalf("RequestPasswordlessLoginOTP error = %v, want disabled", err)
+	}
+	if _, err := lf.PasswordlessLogin(ctx, &dto.PasswordlessLoginRequest{Identifier: "+989123456789", OTPCode: "123456"}, nil); !IsPasswordlessLoginDisabled(err) {
+		t.Fatalf("PasswordlessLogin error = %v, want disabled", err)
+	}
+}
+
+func TestPasswordlessLoginValidation(t *testing.T) {
+	lf := &LoginFlowImpl{passwordlessLogin: true}
+	ctx := context.Background()
+
+	if _, err := lf.RequestPasswordlessLoginOTP(ctx, &dto.PasswordlessLoginOTPRequest{Identifier: "user@example.com"}, nil); !IsCustomerNotFound(err) {
+		t.Fatalf("email identifier error = %v, want customer not found", err)
+	}
+	if _, err := lf.PasswordlessLogin(ctx, &dto.PasswordlessLoginRequest{Identifier: "+989123456789", OTPCode: "12ab56"}, nil); !IsInvalidOTPCode(err) {
+		t.Fatalf("malformed code error = %v, want invalid OTP code", err)
+	}
+	if _, err := lf.PasswordlessLogin(ctx, &dto.PasswordlessLoginRequest{Identifier: " ", OTPCode: "123456"}, nil); !IsAuthenticationFailed(err) {
+		t.Fatalf("empty identifier error = %v, want authentication failed", err)
+	}
+	if _, err := lf.PasswordlessLogin(ctx, &dto.PasswordlessLoginRequest{Identifier: "+989123456789", OTPCode: "123456"}, nil); !IsCacheNotAvailable(err) {
+		t.Fatalf("without redis error = %v, want cache unavailable", err)
+	}
+}
+
+func TestPasswordlessLoginOTPKeyIsSeparate(t *testing.T) {
+	lf := &LoginFlowImpl{}
+	// A passwordless code must never pass as the second factor of a
+	// password login
+	if lf.passwordlessLoginOTPKey(7) == lf.loginOTPKey(7) {
+		t.Fatalf("passwordless and password login OTPs share the key %q", lf.loginOTPKey(7))
+	}
+}
+
+func TestMobileChangeValidation(t *testing.T) {
+	lf := &LoginFlowImpl{}
+	ctx := context.Background()
+
+	if _, err := lf.RequestMobileChange(ctx, 7, &dto.MobileChangeRequest{CurrentPassword: "secret", NewMobile: "+989123456789"}, nil); !IsCacheNotAvailable(err) {
+		t.Fatalf("request without redis error = %v, want cache unavailable", err)
+	}
+	if _, err := lf.ConfirmMobileChange(ctx, 7, "token", &dto.MobileChangeConfirmRequest{OTPCode: "123456"}, nil); !IsCacheNotAvailable(err) {
+		t.Fatalf("confirm without redis error = %v, want cache unavailable", err)
+	}
+	// A code sent to a new mobile must never pass as a login code
+	if lf.mobileChangeOTPKey(7) == lf.loginOTPKey(7) || lf.mobileChangeOTPKey(7) == lf.passwordResetOTPKey(7) {
+		t.Fatalf("mobile change OTP shares the key %q", lf.mobileChangeOTPKey(7))
+	}
+}
+
+func TestChangePasswordConfirmationMismatch(t *testing.T) {
+	lf := &LoginFlowImpl{}
+
+	// Refused before the customer is loaded, so no password is checked
+	_, err := lf.ChangePassword(context.Background(), 7, "token", &dto.ChangePasswordRequest{
+		CurrentPassword: "OldSecurePass123!",
+		NewPassword:     "NewSecurePass123!",
+		ConfirmPassword: "NewSecurePass124!",
+	}, nil)
+	if !IsIncorrectPassword(err) {
+		t.Fatalf("mismatched confirmation error = %v, want incorrect password", err)
+	}
+}
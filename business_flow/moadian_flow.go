@@ -0,0 +1,289 @@
+package businessflow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/app/services"
+	"github.com/amirphl/Yamata-no-Orochi/config"
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/repository"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"gorm.io/gorm"
+)
+
+const (
+	// A claimed submission not updated for this long lost its worker
+	moadianStaleAfter = 10 * time.Minute
+	// Moadian processes invoices asynchronously; results are looked up
+	// this long after submission and then on every run
+	moadianResultDelay = time.Minute
+	moadianBaseBackoff = time.Minute
+	moadianMaxBackoff  = 6 * time.Hour
+	// Amounts are stored in tomans and reported in rials
+	tomanToRial = 10
+)
+
+// MoadianFlow submits issued tax invoices to Moadian, the tax authority's
+// e-invoicing system.
+//
+// Every issued invoice is queued once. A run submits the due invoices,
+// retrying transient failures with exponential backoff, and looks up the
+// result of invoices submitted earlier. The latest status, tax ID and
+// reference number are mirrored onto the invoice's payment request.
+type MoadianFlow interface {
+	ProcessSubmissions(ctx context.Context, now time.Time) (*dto.MoadianSubmissionSummary, error)
+}
+
+type MoadianFlowImpl struct {
+	db                 *gorm.DB
+	submissionRepo     repository.MoadianSubmissionRepository
+	taxInvoiceRepo     repository.TaxInvoiceRepository
+	paymentRequestRepo repository.PaymentRequestRepository
+	client             services.MoadianClient
+	cfg                config.MoadianConfig
+}
+
+func NewMoadianFlow(
+	db *gorm.DB,
+	submissionRepo repository.MoadianSubmissionRepository,
+	taxInvoiceRepo repository.TaxInvoiceRepository,
+	paymentRequestRepo repository.PaymentRequestRepository,
+	client services.MoadianClient,
+	cfg config.MoadianConfig,
+) MoadianFlow {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 50
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 10
+	}
+	return &MoadianFlowImpl{
+		db:                 db,
+		submissionRepo:     submissionRepo,
+		taxInvoiceRepo:     taxInvoiceRepo,
+		paymentRequestRepo: paymentRequestRepo,
+		client:             client,
+		cfg:                cfg,
+	}
+}
+
+// ProcessSubmissions queues newly issued invoices, submits up to a batch of
+// due ones and looks up the results of up to a batch of submitted ones
+func (f *MoadianFlowImpl) ProcessSubmissions(ctx context.Context, now time.Time) (*dto.MoadianSubmissionSummary, error) {
+	res := &dto.MoadianSubmissionSummary{}
+
+	enqueued, err := f.submissionRepo.EnqueueIssued(ctx, f.cfg.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	res.Enqueued = int(enqueued)
+
+	for i := 0; i < f.cfg.BatchSize; i++ {
+		sub, err := f.submissionRepo.ClaimNextDue(ctx, now, now.Add(-moadianStaleAfter))
+		if err != nil {
+			return res, err
+		}
+		if sub == nil {
+			break
+		}
+		if err := f.submit(ctx, sub, now, res); err != nil {
+			return res, err
+		}
+	}
+
+	awaiting, err := f.submissionRepo.ListAwaitingResult(ctx, now, f.cfg.BatchSize)
+	if err != nil {
+		return res, err
+	}
+	for _, sub := range awaiting {
+		if err := f.inquire(ctx, sub, now, res); err != nil {
+			return res, err
+		}
+	}
+	return res, nil
+}
+
+// submit sends a claimed submission's invoice and records the outcome
+func (f *MoadianFlowImpl) submit(ctx context.Context, sub *models.MoadianSubmission, now time.Time, res *dto.MoadianSubmissionSummary) error {
+	invoices, err := f.taxInvoiceRepo.ByFilter(ctx, models.TaxInvoiceFilter{ID: &sub.TaxInvoiceID}, "", 1, 0)
+	if err != nil {
+		return err
+	}
+	if len(invoices) == 0 {
+		return f.finish(ctx, sub, models.MoadianSubmissionStatusFailed, now, "tax invoice not found", res)
+	}
+	inv := invoices[0]
+	payload, err := moadianInvoice(inv, f.cfg)
+	if err != nil {
+		return f.finish(ctx, sub, models.MoadianSubmissionStatusFailed, now, err.Error(), res)
+	}
+	sub.TaxID = utils.ToPtr(payload.Header.TaxID)
+	sub.Attempts++
+
+	ref, err := f.client.SubmitInvoice(ctx, payload)
+	switch {
+	case err == nil:
+		sub.Status = models.MoadianSubmissionStatusSubmitted
+		sub.ReferenceNumber = utils.ToPtr(ref)
+		sub.SubmittedAt = utils.ToPtr(now)
+		sub.NextAttemptAt = now.Add(moadianResultDelay)
+		sub.LastError = nil
+		res.Submitted++
+		return f.save(ctx, sub)
+	case !services.IsMoadianTransient(err):
+		return f.finish(ctx, sub, models.MoadianSubmissionStatusRejected, now, err.Error(), res)
+	case sub.Attempts >= f.cfg.MaxAttempts:
+		return f.finish(ctx, sub, models.MoadianSubmissionStatusFailed, now, err.Error(), res)
+	}
+	log.Printf("moadian: invoice %d: attempt %d: %v", inv.ID, sub.Attempts, err)
+	sub.Status = models.MoadianSubmissionStatusPending
+	sub.NextAttemptAt = now.Add(moadianBackoff(sub.Attempts))
+	sub.LastError = utils.ToPtr(err.Error())
+	res.Retried++
+	return f.save(ctx, sub)
+}
+
+// inquire looks up the result of a submitted invoice. A failed lookup is
+// tried again on the next run.
+func (f *MoadianFlowImpl) inquire(ctx context.Context, sub *models.MoadianSubmission, now time.Time, res *dto.MoadianSubmissionSummary) error {
+	if sub.ReferenceNumber == nil {
+		return f.finish(ctx, sub, models.MoadianSubmissionStatusFailed, now, "submitted without a reference number", res)
+	}
+	result, err := f.client.InquireByReference(ctx, *sub.ReferenceNumber)
+	if err != nil {
+		log.Printf("moadian: inquiry %s: %v", *sub.ReferenceNumber, err)
+		return nil
+	}
+	switch result.Status {
+	case services.MoadianResultSuccess:
+		return f.finish(ctx, sub, models.MoadianSubmissionStatusAccepted, now, "", res)
+	case services.MoadianResultFailed:
+		msg := strings.Join(result.Errors, "; ")
+		if msg == "" {
+			msg = "rejected by Moadian"
+		}
+		return f.finish(ctx, sub, models.MoadianSubmissionStatusRejected, now, msg, res)
+	}
+	sub.NextAttemptAt = now.Add(moadianResultDelay)
+	return f.submissionRepo.Update(ctx, sub)
+}
+
+// finish moves a submission to a final status
+func (f *MoadianFlowImpl) finish(ctx context.Context, sub *models.MoadianSubmission, status models.MoadianSubmissionStatus, now time.Time, lastError string, res *dto.MoadianSubmissionSummary) error {
+	sub.Status = status
+	sub.CompletedAt = utils.ToPtr(now)
+	sub.LastError = nil
+	if lastError != "" {
+		sub.LastError = utils.ToPtr(lastError)
+	}
+	switch status {
+	case models.MoadianSubmissionStatusAccepted:
+		res.Accepted++
+	case models.MoadianSubmissionStatusRejected:
+		res.Rejected++
+		log.Printf("moadian: invoice %d rejected: %s", sub.TaxInvoiceID, lastError)
+	default:
+		res.Failed++
+		log.Printf("moadian: invoice %d failed: %s", sub.TaxInvoiceID, lastError)
+	}
+	return f.save(ctx, sub)
+}
+
+// save persists a submission and mirrors it onto its payment request
+func (f *MoadianFlowImpl) save(ctx context.Context, sub *models.MoadianSubmission) error {
+	return repository.WithTransaction(ctx, f.db, func(txCtx context.Context) error {
+		if err := f.submissionRepo.Update(txCtx, sub); err != nil {
+			return err
+		}
+		if sub.PaymentRequestID == nil {
+			return nil
+		}
+		return f.paymentRequestRepo.SetMoadianStatus(txCtx, *sub.PaymentRequestID, sub.Status, sub.TaxID, sub.ReferenceNumber)
+	})
+}
+
+// moadianBackoff is the delay before retry n: one minute doubling per
+// attempt, at most six hours
+func moadianBackoff(attempts int) time.Duration {
+	d := moadianBaseBackoff
+	for i := 1; i < attempts && d < moadianMaxBackoff; i++ {
+		d *= 2
+	}
+	return min(d, moadianMaxBackoff)
+}
+
+// moadianInvoice converts an issued invoice to the Moadian format: a sale
+// of one wallet top-up, paid in cash
+func moadianInvoice(inv *models.TaxInvoice, cfg config.MoadianConfig) (services.MoadianInvoice, error) {
+	var seller, buyer models.TaxInvoiceParty
+	if err := json.Unmarshal(inv.Seller, &seller); err != nil {
+		return services.MoadianInvoice{}, fmt.Errorf("seller snapshot: %w", err)
+	}
+	if err := json.Unmarshal(inv.Buyer, &buyer); err != nil {
+		return services.MoadianInvoice{}, fmt.Errorf("buyer snapshot: %w", err)
+	}
+	if seller.EconomicCode == "" {
+		return services.MoadianInvoice{}, errors.New("invoice was issued without the seller's economic code")
+	}
+
+	net := inv.Amount * tomanToRial
+	vat := inv.Tax * tomanToRial
+	total := net + vat
+	issuedAt := inv.IssuedAt.UnixMilli()
+	header := services.MoadianInvoiceHeader{
+		TaxID:        services.MoadianTaxID(cfg.MemoryID, inv.IssuedAt, inv.SequenceNumber),
+		IssuedAt:     issuedAt,
+		CreatedAt:    issuedAt,
+		Type:         2,
+		Serial:       services.MoadianSerial(inv.SequenceNumber),
+		Pattern:      1,
+		Subject:      1,
+		SellerTaxID:  seller.EconomicCode,
+		TotalPrice:   net,
+		TotalNet:     net,
+		TotalVAT:     vat,
+		TotalBill:    total,
+		Settlement:   1,
+		CashPaid:     total,
+		TotalVATPaid: vat,
+	}
+	// Invoices naming the buyer need their national ID: 10 digits for a
+	// person, 11 for a legal entity
+	if buyer.NationalID != "" {
+		header.Type = 1
+		header.BuyerType = 1
+		if len(buyer.NationalID) == 11 {
+			header.BuyerType = 2
+		}
+		header.BuyerID = buyer.NationalID
+		header.BuyerTaxID = buyer.EconomicCode
+		if header.BuyerTaxID == "" {
+			header.BuyerTaxID = buyer.NationalID
+		}
+		header.BuyerPostal = buyer.PostalCode
+	}
+
+	return services.MoadianInvoice{
+		Header: header,
+		Body: []services.MoadianInvoiceItem{{
+			ServiceID:   cfg.ServiceID,
+			Description: inv.Description,
+			Quantity:    1,
+			Unit:        services.MoadianUnitEach,
+			Fee:         net,
+			Price:       net,
+			Net:         net,
+			VATRate:     float64(inv.TaxBasisPoints) / 100,
+			VAT:         vat,
+			Total:       total,
+		}},
+		Payments: []services.MoadianInvoicePayment{{Reference: inv.Reference, PaidAt: issuedAt}},
+	}, nil
+}
@@ -0,0 +1,67 @@
+package businessflow
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/config"
+	"github.com/amirphl/Yamata-no-Orochi/models"
+)
+
+func TestMoadianInvoice(t *testing.T) {
+	t.Parallel()
+
+	seller, _ := json.Marshal(models.TaxInvoiceParty{Name: "Jazebeh", EconomicCode: "411111111111"})
+	buyer, _ := json.Marshal(models.TaxInvoiceParty{Name: "Acme Co", NationalID: "10101010101", PostalCode: "1234567890"})
+	inv := &models.TaxInvoice{
+		SequenceNumber: 42,
+		AmountWithTax:  1_100_000,
+		Amount:         1_000_000,
+		Tax:            100_000,
+		TaxBasisPoints: 1000,
+		Reference:      "REF-1",
+		Seller:         seller,
+		Buyer:          buyer,
+		IssuedAt:       time.Date(2026, 10, 17, 9, 30, 0, 0, time.UTC),
+	}
+	cfg := config.MoadianConfig{MemoryID: "A1B2C3", ServiceID: "2330001234567"}
+
+	out, err := moadianInvoice(inv, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := out.Header
+	if h.TotalBill != 11_000_000 || h.TotalVAT != 1_000_000 || h.CashPaid != h.TotalBill {
+		t.Fatalf("header totals = %+v, want the amounts in rials paid in cash", h)
+	}
+	if h.Type != 1 || h.BuyerType != 2 || h.BuyerID != "10101010101" || h.SellerTaxID != "411111111111" {
+		t.Fatalf("header parties = %+v, want a legal entity buyer", h)
+	}
+	if len(out.Body) != 1 || out.Body[0].VATRate != 10 || out.Body[0].Total != h.TotalBill || out.Body[0].ServiceID != cfg.ServiceID {
+		t.Fatalf("body = %+v, want one top-up line at 10%% VAT", out.Body)
+	}
+	if len(h.TaxID) != 22 || h.Serial != "000000002A" {
+		t.Fatalf("tax ID = %q, serial = %q", h.TaxID, h.Serial)
+	}
+
+	inv.Buyer, _ = json.Marshal(models.TaxInvoiceParty{Name: "Sara Ahmadi"})
+	if out, _ := moadianInvoice(inv, cfg); out.Header.Type != 2 || out.Header.BuyerID != "" {
+		t.Fatalf("header = %+v, want an invoice without buyer identity", out.Header)
+	}
+
+	inv.Seller, _ = json.Marshal(models.TaxInvoiceParty{Name: "Jazebeh"})
+	if _, err := moadianInvoice(inv, cfg); err == nil {
+		t.Fatal("expected an error without the seller's economic code")
+	}
+}
+
+func TestMoadianBackoff(t *testing.T) {
+	t.Parallel()
+
+	for attempts, want := range map[int]time.Duration{1: time.Minute, 2: 2 * time.Minute, 4: 8 * time.Minute, 20: 6 * time.Hour} {
+		if got := moadianBackoff(attempts); got != want {
+			t.Errorf("moadianBackoff(%d) = %v, want %v", attempts, got, want)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package businessflow
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/app/i18n"
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/repository"
+)
+
+// NotificationFlow serves the in-app inbox of customers. Flows add entries
+// with notifyInbox when a campaign is approved or rejected, a payment is
+// credited or the free balance drops below the minimum campaign budget.
+type NotificationFlow interface {
+	List(ctx context.Context, req *dto.ListNotificationsRequest) (*dto.ListNotificationsResponse, error)
+	MarkRead(ctx context.Context, req *dto.MarkNotificationsReadRequest) (*dto.MarkNotificationsReadResponse, error)
+	UnreadCount(ctx context.Context, customerID uint) (*dto.UnreadNotificationsCountResponse, error)
+}
+
+type NotificationFlowImpl struct {
+	notificationRepo repository.NotificationRepository
+	customerRepo     repository.CustomerRepository
+	localizer        *i18n.Localizer
+}
+
+const (
+	defaultNotificationsLimit = 20
+	maxNotificationsLimit     = 100
+)
+
+func NewNotificationFlow(
+	notificationRepo repository.NotificationRepository,
+	customerRepo repository.CustomerRepository,
+	localizer *i18n.Localizer,
+) NotificationFlow {
+	return &NotificationFlowImpl{
+		notificationRepo: notificationRepo,
+		customerRepo:     customerRepo,
+		localizer:        localizer,
+	}
+}
+
+// List returns a page of the inbox newest first, rendered in the customer's
+// locale, with the unread count for the badge
+func (f *NotificationFlowImpl) List(ctx context.Context, req *dto.ListNotificationsRequest) (*dto.ListNotificationsResponse, error) {
+	customer, err := getCustomer(ctx, f.customerRepo, req.CustomerID)
+	if err != nil {
+		return nil, NewBusinessError("LIST_NOTIFICATIONS_FAILED", "Failed to find customer", err)
+	}
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultNotificationsLimit
+	}
+	limit = min(limit, maxNotificationsLimit)
+
+	filter := models.NotificationFilter{CustomerID: &customer.ID, Unread: req.UnreadOnly}
+	if req.BeforeID > 0 {
+		filter.BeforeID = &req.BeforeID
+	}
+	// One extra row tells whether an older page exists
+	rows, err := f.notificationRepo.ByFilter(ctx, filter, "id DESC", limit+1, 0)
+	if err != nil {
+		return nil, NewBusinessError("LIST_NOTIFICATIONS_FAILED", "Failed to list notifications", err)
+	}
+	unread, err := f.notificationRepo.Count(ctx, models.NotificationFilter{CustomerID: &customer.ID, Unread: true})
+	if err != nil {
+		return nil, NewBusinessError("LIST_NOTIFICATIONS_FAILED", "Failed to count unread notifications", err)
+	}
+
+	res := &dto.ListNotificationsResponse{
+		Message:     "Notifications retrieved successfully",
+		Items:       make([]dto.NotificationItem, 0, min(len(rows), limit)),
+		UnreadCount: unread,
+	}
+	if len(rows) > limit {
+		rows = rows[:limit]
+		res.NextBeforeID = &rows[limit-1].ID
+	}
+	for _, n := range rows {
+		res.Items = append(res.Items, renderNotification(f.localizer, &customer, n))
+	}
+	return res, nil
+}
+
+// MarkRead marks notifications of the customer as read. Ids of other
+// customers' or already read notifications are ignored.
+func (f *NotificationFlowImpl) MarkRead(ctx context.Context, req *dto.MarkNotificationsReadRequest) (*dto.MarkNotificationsReadResponse, error) {
+	var ids []uint
+	if !req.All {
+		ids = req.IDs
+		if ids == nil {
+			ids = []uint{}
+		}
+	}
+	marked, err := f.notificationRepo.MarkRead(ctx, req.CustomerID, ids)
+	if err != nil {
+		return nil, NewBusinessError("MARK_NOTIFICATIONS_READ_FAILED", "Failed to mark notifications as read", err)
+	}
+	unread, err := f.notificationRepo.Count(ctx, models.NotificationFilter{CustomerID: &req.CustomerID, Unread: true})
+	if err != nil {
+		return nil, NewBusinessError("MARK_NOTIFICATIONS_READ_FAILED", "Failed to count unread notifications", err)
+	}
+	return &dto.MarkNotificationsReadResponse{
+		Message:     "Notifications marked as read",
+		Marked:      marked,
+		UnreadCount: unread,
+	}, nil
+}
+
+// UnreadCount returns the number of unread notifications of the customer
+func (f *NotificationFlowImpl) UnreadCount(ctx context.Context, customerID uint) (*dto.UnreadNotificationsCountResponse, error) {
+	unread, err := f.notificationRepo.Count(ctx, models.NotificationFilter{CustomerID: &customerID, Unread: true})
+	if err != nil {
+		return nil, NewBusinessError("GET_UNREAD_NOTIFICATIONS_COUNT_FAILED", "Failed to count unread notifications", err)
+	}
+	return &dto.UnreadNotificationsCountResponse{UnreadCount: unread}, nil
+}
+
+// renderNotification renders the title and body of a notification from its
+// kind and arguments. Numbers in the arguments are kept as stored so amounts
+// are not printed in exponent form.
+func renderNotification(localizer *i18n.Localizer, customer *models.Customer, n *models.Notification) dto.NotificationItem {
+	args := map[string]any{}
+	dec := json.NewDecoder(bytes.NewReader(n.Args))
+	dec.UseNumber()
+	if err := dec.Decode(&args); err != nil {
+		log.Printf("notification %d: invalid args: %v", n.ID, err)
+	}
+	key := "notification." + string(n.Kind)
+	return dto.NotificationItem{
+		ID:        n.ID,
+		Kind:      string(n.Kind),
+		Title:     localizer.Customer(customer, key+".title", args),
+		Body:      localizer.Customer(customer, key+".body", args),
+		Args:      args,
+		Read:      n.ReadAt != nil,
+		ReadAt:    n.ReadAt,
+		CreatedAt: n.CreatedAt,
+	}
+}
+
+// notifyInbox adds a notification to a customer's inbox. Call it with the
+// transaction context of the change it reports so the entry commits with
+// that change. Like the SMS notices it is best effort: failures are logged.
+func notifyInbox(ctx context.Context, repo repository.NotificationRepository, customerID uint, kind models.NotificationKind, args i18n.Args) {
+	if repo == nil {
+		return
+	}
+	raw, err := json.Marshal(args)
+	if err != nil {
+		log.Printf("notify customer %d of %s: %v", customerID, kind, err)
+		return
+	}
+	if err := repo.Record(ctx, &models.Notification{CustomerID: customerID, Kind: kind, Args: raw}); err != nil {
+		log.Printf("notify customer %d of %s: %v", customerID, kind, err)
+	}
+}
+
+// notifyLowBalance warns a customer whose free balance dropped below the
+// minimum campaign budget, once per crossing
+func notifyLowBalance(ctx context.Context, repo repository.NotificationRepository, customerID uint, before, after uint64) {
+	if before < minCampaignBudget || after >= minCampaignBudget {
+		return
+	}
+	notifyInbox(ctx, repo, customerID, models.NotificationKindLowBalance, i18n.Args{
+		"Balance":   after,
+		"Threshold": minCampaignBudget,
+	})
+}
+
+// campaignDisplayTitle is the title notifications show for a campaign
+func campaignDisplayTitle(campaign *models.Campaign) string {
+	if campaign.Spec.Title != nil && *campaign.Spec.Title != "" {
+		return *campaign.Spec.Title
+	}
+	return campaign.UUID.String()
+}
@@ -0,0 +1,66 @@
+package businessflow
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/i18n"
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/repository"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+)
+
+type recordingNotificationRepo struct {
+	repository.NotificationRepository
+	recorded []*models.Notification
+}
+
+func (r *recordingNotificationRepo) Record(_ context.Context, n *models.Notification) error {
+	r.recorded = append(r.recorded, n)
+	return nil
+}
+
+func TestRenderNotification(t *testing.T) {
+	t.Parallel()
+
+	n := &models.Notification{
+		ID:   3,
+		Kind: models.NotificationKindPaymentCredited,
+		Args: json.RawMessage(`{"Amount":15000000}`),
+	}
+	localizer := i18n.NewLocalizer("en", "en")
+	got := renderNotification(localizer, &models.Customer{}, n)
+	if got.Title != "Wallet charged" || got.Body != "15000000 toman was credited to your wallet." {
+		t.Fatalf("unexpected English rendering %q / %q", got.Title, got.Body)
+	}
+	if got.Read {
+		t.Fatal("expected unread notification")
+	}
+
+	fa := renderNotification(localizer, &models.Customer{PreferredLocale: utils.ToPtr("fa")}, n)
+	if fa.Body != i18n.Message(i18n.LocalePersian, "notification.payment_credited.body", i18n.Args{"Amount": 15000000}) {
+		t.Fatalf("unexpected Persian body %q", fa.Body)
+	}
+}
+
+func TestNotifyLowBalance(t *testing.T) {
+	t.Parallel()
+
+	repo := &recordingNotificationRepo{}
+	ctx := context.Background()
+	notifyLowBalance(ctx, repo, 7, 500_000, 200_000)          // still above
+	notifyLowBalance(ctx, repo, 7, 200_000, 40_000)           // crosses
+	notifyLowBalance(ctx, repo, 7, 40_000, 10_000)            // already below
+	notifyLowBalance(ctx, repo, 7, minCampaignBudget, 99_999) // crosses at the threshold
+
+	if len(repo.recorded) != 2 {
+		t.Fatalf("expected 2 notifications, got %d", len(repo.recorded))
+	}
+	n := repo.recorded[0]
+	if n.CustomerID != 7 || n.Kind != models.NotificationKindLowBalance || string(n.Args) != `{"Balance":40000,"Threshold":100000}` {
+		t.Fatalf("unexpected notification %+v (args %s)", n, n.Args)
+	}
+
+	notifyInbox(ctx, nil, 7, models.NotificationKindLowBalance, nil) // no repository is a no-op
+}
@@ -2,16 +2,15 @@ package businessflow
 
 import (
 	"fmt"
-	"strings"
+
+	"github.com/amirphl/Yamata-no-Orochi/utils/phonenumber"
 )
 
+// normalizeOTPMobile returns the SMS gateway form (989XXXXXXXXX) of a mobile number
 func normalizeOTPMobile(mobile string) (string, error) {
-	recipient := strings.TrimSpace(mobile)
-	if strings.HasPrefix(recipient, "+") {
-		recipient = recipient[1:]
-	}
-	if len(recipient) != 12 || !strings.HasPrefix(recipient, "989") {
+	n, err := phonenumber.Parse(mobile)
+	if err != nil {
 		return "", fmt.Errorf("invalid mobile number format: %s", mobile)
 	}
-	return recipient, nil
+	return n.International(), nil
 }
@@ -0,0 +1,164 @@
+package businessflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const otpDailyWindow = 24 * time.Hour
+
+// OTPThrottledError reports that an OTP may not be sent to a target yet
+type OTPThrottledError struct {
+	RetryAfter time.Duration
+	// DailyLimit is set when the target used up its sends for the day rather
+	// than still being in the cooldown after the previous send
+	DailyLimit bool
+}
+
+func (e *OTPThrottledError) Error() string {
+	if e.DailyLimit {
+		return fmt.Sprintf("daily OTP limit reached, retry after %s", e.RetryAfter)
+	}
+	return fmt.Sprintf("OTP resend cooldown, retry after %s", e.RetryAfter)
+}
+
+func (e *OTPThrottledError) Unwrap() error {
+	return ErrRateLimitExceeded
+}
+
+// OTPRetryAfter returns how long the caller must wait before another OTP can
+// be sent when err is an OTP throttling error
+func OTPRetryAfter(err error) (time.Duration, bool) {
+	var throttled *OTPThrottledError
+	if errors.As(err, &throttled) {
+		return throttled.RetryAfter, true
+	}
+	return 0, false
+}
+
+// otpThrottleScript records a send for a target unless it is in its cooldown or
+// used up its daily sends. The daily window starts with the first send.
+var otpThrottleScript = redis.NewScript(`
+local cooldown = redis.call('PTTL', KEYS[1])
+if cooldown > 0 then
+	return {0, cooldown, 0}
+end
+local limit = tonumber(ARGV[2])
+if limit > 0 then
+	local sent = tonumber(redis.call('GET', KEYS[2]) or '0')
+	if sent >= limit then
+		return {0, redis.call('PTTL', KEYS[2]), 1}
+	end
+end
+if tonumber(ARGV[1]) > 0 then
+	redis.call('SET', KEYS[1], '1', 'PX', ARGV[1])
+end
+if redis.call('INCR', KEYS[2]) == 1 then
+	redis.call('PEXPIRE', KEYS[2], ARGV[3])
+end
+return {1, tonumber(ARGV[1]), 0}
+`)
+
+// OTPThrottle limits how often an OTP is sent to the same mobile number or
+// email, whichever flow sends it: at most one per cooldown and dailyLimit per
+// day. A cooldown or daily limit of 0 disables it.
+type OTPThrottle struct {
+	rc *redis.Client
+
+	mu         sync.RWMutex
+	cooldown   time.Duration
+	dailyLimit int
+}
+
+// NewOTPThrottle creates an OTP throttle; a nil Redis client disables it
+func NewOTPThrottle(rc *redis.Client, cooldown time.Duration, dailyLimit int) *OTPThrottle {
+	return &OTPThrottle{rc: rc, cooldown: cooldown, dailyLimit: dailyLimit}
+}
+
+// SetLimits changes the cooldown and daily limit; targets already throttled
+// keep their current cooldown and count
+func (t *OTPThrottle) SetLimits(cooldown time.Duration, dailyLimit int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.cooldown = cooldown
+	t.dailyLimit = dailyLimit
+}
+
+func (t *OTPThrottle) limits() (time.Duration, int) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.cooldown, t.dailyLimit
+}
+
+// Reserve records an OTP send to target, or returns an *OTPThrottledError
+// when it is not allowed yet. On success it returns the cooldown before the
+// next send.
+func (t *OTPThrottle) Reserve(ctx context.Context, target string) (time.Duration, error) {
+	if t == nil || t.rc == nil {
+		return 0, nil
+	}
+	cooldown, dailyLimit := t.limits()
+	if cooldown <= 0 && dailyLimit <= 0 {
+		return 0, nil
+	}
+	cooldownKey, dailyKey := otpThrottleKeys(target)
+	res, err := otpThrottleScript.Run(ctx, t.rc, []string{cooldownKey, dailyKey},
+		cooldown.Milliseconds(), dailyLimit, otpDailyWindow.Milliseconds()).Int64Slice()
+	if err != nil {
+		return 0, err
+	}
+	if len(res) != 3 {
+		return 0, fmt.Errorf("unexpected OTP throttle reply %v", res)
+	}
+	wait := time.Duration(res[1]) * time.Millisecond
+	if res[0] != 1 {
+		return 0, &OTPThrottledError{RetryAfter: wait, DailyLimit: res[2] == 1}
+	}
+	return wait, nil
+}
+
+// Wait returns how long until the cooldown of target ends
+func (t *OTPThrottle) Wait(ctx context.Context, target string) (time.Duration, error) {
+	if t == nil || t.rc == nil {
+		return 0, nil
+	}
+	if cooldown, _ := t.limits(); cooldown <= 0 {
+		return 0, nil
+	}
+	cooldownKey, _ := otpThrottleKeys(target)
+	ttl, err := t.rc.PTTL(ctx, cooldownKey).Result()
+	if err != nil {
+		return 0, err
+	}
+	if ttl < 0 {
+		return 0, nil
+	}
+	return ttl, nil
+}
+
+// ceilSeconds rounds a wait up to whole seconds for API responses
+func ceilSeconds(d time.Duration) int {
+	if d <= 0 {
+		return 0
+	}
+	return int((d + time.Second - 1) / time.Second)
+}
+
+// otpThrottleKeys keys a target by the hash of its normalized form so the same
+// number written differently shares its counters
+func otpThrottleKeys(target string) (string, string) {
+	normalized := normalizeLoginIdentifier(target)
+	if !strings.Contains(normalized, "@") {
+		if mobile, err := normalizeOTPMobile(normalized); err == nil {
+			normalized = mobile
+		}
+	}
+	hash := hashOTPCode(normalized)
+	return "otp:throttle:cooldown:" + hash, "otp:throttle:daily:" + hash
+}
@@ -0,0 +1,81 @@
+package businessflow
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestOTPThrottleKeysNormalizeMobile(t *testing.T) {
+	t.Parallel()
+
+	cooldown1, daily1 := otpThrottleKeys("09123456789")
+	cooldown2, daily2 := otpThrottleKeys("+989123456789")
+	if cooldown1 != cooldown2 || daily1 != daily2 {
+		t.Fatal("the same mobile in different forms must share throttle keys")
+	}
+
+	emailCooldown1, _ := otpThrottleKeys(" User@Example.com ")
+	emailCooldown2, _ := otpThrottleKeys("user@example.com")
+	if emailCooldown1 != emailCooldown2 {
+		t.Fatal("emails must be keyed case-insensitively")
+	}
+	if emailCooldown1 == cooldown1 {
+		t.Fatal("different targets must not share throttle keys")
+	}
+}
+
+func TestOTPRetryAfterThroughBusinessError(t *testing.T) {
+	t.Parallel()
+
+	err := NewBusinessError("RESEND_OTP_FAILED", "Resend OTP failed", &OTPThrottledError{RetryAfter: 42 * time.Second})
+	if !IsRateLimitExceeded(err) {
+		t.Fatal("a throttled OTP must count as a rate limit error")
+	}
+	retryAfter, ok := OTPRetryAfter(err)
+	if !ok || retryAfter != 42*time.Second {
+		t.Fatalf("unexpected retry after: %v %v", retryAfter, ok)
+	}
+
+	if _, ok := OTPRetryAfter(NewBusinessError("X", "x", ErrRateLimitExceeded)); ok {
+		t.Fatal("a plain rate limit error has no retry after")
+	}
+	if _, ok := OTPRetryAfter(errors.New("other")); ok {
+		t.Fatal("unrelated errors have no retry after")
+	}
+}
+
+func TestOTPThrottleWithoutRedisAllows(t *testing.T) {
+	t.Parallel()
+
+	throttle := NewOTPThrottle(nil, 90*time.Second, 5)
+	for i := 0; i < 10; i++ {
+		if _, err := throttle.Reserve(context.Background(), "09123456789"); err != nil {
+			t.Fatalf("reserve %d: %v", i, err)
+		}
+	}
+
+	var nilThrottle *OTPThrottle
+	if _, err := nilThrottle.Reserve(context.Background(), "09123456789"); err != nil {
+		t.Fatalf("nil throttle: %v", err)
+	}
+}
+
+func TestCeilSeconds(t *testing.T) {
+	t.Parallel()
+
+	cases := map[time.Duration]int{
+		0:                      0,
+		-time.Second:           0,
+		time.Millisecond:       1,
+		90 * time.Second:       90,
+		90*time.Second + 1:     91,
+		89*time.Second + 999e6: 90,
+	}
+	for in, want := range cases {
+		if got := ceilSeconds(in); got != want {
+			t.Fatalf("ceilSeconds(%v) = %d, want %d", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,298 @@
+package businessflow
+
+import (
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/repository"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/google/uuid"
+)
+
+// partitionedTables are the tables range partitioned by month on created_at
+var partitionedTables = []string{"sent_sms", "audit_log"}
+
+// PartitionMaintenanceFlow keeps the monthly partitions of sent_sms and
+// audit_log ahead of time and moves partitions past retention to cold storage.
+//
+// An archived partition is written as a gzipped CSV with a header row under
+// archiveDir/<table>/<partition>.csv.gz, recorded in partition_archives with
+// its row count and SHA-256, and only then detached and dropped. archiveDir
+// may be a mounted object storage bucket.
+type PartitionMaintenanceFlow interface {
+	EnsureMonthlyPartitions(ctx context.Context, through time.Time) (int, error)
+	ArchivePartitionsBefore(ctx context.Context, cutoff time.Time) (int, error)
+	// ReadArchivedRows calls fn with every archived row of a table created in
+	// [from, to), keyed by column name
+	ReadArchivedRows(ctx context.Context, parentTable string, from, to time.Time, fn func(row map[string]string) error) error
+}
+
+type PartitionMaintenanceFlowImpl struct {
+	partitionRepo repository.PartitionRepository
+	archiveRepo   repository.PartitionArchiveRepository
+	archiveDir    string
+}
+
+func NewPartitionMaintenanceFlow(
+	partitionRepo repository.PartitionRepository,
+	archiveRepo repository.PartitionArchiveRepository,
+	archiveDir string,
+) PartitionMaintenanceFlow {
+	if archiveDir == "" {
+		archiveDir = filepath.Join("data", "archives")
+	}
+	return &PartitionMaintenanceFlowImpl{
+		partitionRepo: partitionRepo,
+		archiveRepo:   archiveRepo,
+		archiveDir:    archiveDir,
+	}
+}
+
+// monthStart returns the first instant of the UTC month containing t
+func monthStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// EnsureMonthlyPartitions creates the partitions of every partitioned table
+// from the current month up to and including the month of through. It returns
+// the number of partitions that exist for that window.
+func (f *PartitionMaintenanceFlowImpl) EnsureMonthlyPartitions(ctx context.Context, through time.Time) (int, error) {
+	release, ok, err := f.partitionRepo.TryLockMaintenance(ctx)
+	if err != nil {
+		return 0, NewBusinessError("PARTITION_LOCK_FAILED", "Failed to lock partition maintenance", err)
+	}
+	if !ok {
+		return 0, nil
+	}
+	defer release()
+
+	last := monthStart(through)
+	ensured := 0
+	for _, table := range partitionedTables {
+		for month := monthStart(utils.UTCNow()); !month.After(last); month = month.AddDate(0, 1, 0) {
+			if _, err := f.partitionRepo.EnsureMonthlyPartition(ctx, table, month); err != nil {
+				return ensured, NewBusinessError("PARTITION_CREATE_FAILED", fmt.Sprintf("Failed to create %s partition for %s", table, month.Format("2006-01")), err)
+			}
+			ensured++
+		}
+	}
+	return ensured, nil
+}
+
+// ArchivePartitionsBefore archives and drops every partition whose range ends
+// at or before cutoff. The DEFAULT partition is never archived.
+func (f *PartitionMaintenanceFlowImpl) ArchivePartitionsBefore(ctx context.Context, cutoff time.Time) (int, error) {
+	release, ok, err := f.partitionRepo.TryLockMaintenance(ctx)
+	if err != nil {
+		return 0, NewBusinessError("PARTITION_LOCK_FAILED", "Failed to lock partition maintenance", err)
+	}
+	if !ok {
+		return 0, nil
+	}
+	defer release()
+
+	archived := 0
+	var errs []error
+	for _, table := range partitionedTables {
+		partitions, err := f.partitionRepo.ListPartitions(ctx, table)
+		if err != nil {
+			return archived, NewBusinessError("PARTITION_LIST_FAILED", fmt.Sprintf("Failed to list %s partitions", table), err)
+		}
+		for _, p := range partitions {
+			if !partitionExpired(p, cutoff) {
+				continue
+			}
+			if err := f.archivePartition(ctx, table, p); err != nil {
+				errs = append(errs, fmt.Errorf("partition %s: %w", p.Name, err))
+				continue
+			}
+			archived++
+		}
+	}
+	if len(errs) > 0 {
+		return archived, NewBusinessError("PARTITION_ARCHIVE_FAILED", "Failed to archive partitions", errors.Join(errs...))
+	}
+	return archived, nil
+}
+
+func partitionExpired(p models.TablePartition, cutoff time.Time) bool {
+	return !p.IsDefault && p.RangeEnd != nil && !p.RangeEnd.After(cutoff)
+}
+
+// archivePartition exports one partition and drops it. A partition already
+// recorded as archived by an earlier run that failed before dropping it is
+// dropped without exporting it again.
+func (f *PartitionMaintenanceFlowImpl) archivePartition(ctx context.Context, table string, p models.TablePartition) error {
+	exists, err := f.archiveRepo.Exists(ctx, models.PartitionArchiveFilter{PartitionName: &p.Name})
+	if err != nil {
+		return err
+	}
+	if !exists {
+		key := filepath.Join(table, p.Name+".csv.gz")
+		rows, size, checksum, err := f.exportPartition(ctx, p.Name, key)
+		if err != nil {
+			return err
+		}
+		archive := &models.PartitionArchive{
+			UUID:          uuid.New(),
+			ParentTable:   table,
+			PartitionName: p.Name,
+			RangeStart:    p.RangeStart,
+			RangeEnd:      *p.RangeEnd,
+			RowCount:      rows,
+			StorageKey:    key,
+			SizeBytes:     size,
+			Checksum:      checksum,
+			ArchivedAt:    utils.UTCNow(),
+		}
+		if err := f.archiveRepo.Save(ctx, archive); err != nil {
+			return fmt.Errorf("record archive: %w", err)
+		}
+	}
+	if err := f.partitionRepo.DetachAndDropPartition(ctx, table, p.Name); err != nil {
+		return fmt.Errorf("drop partition: %w", err)
+	}
+	return nil
+}
+
+// exportPartition writes the partition to a temporary file and renames it into
+// place once it is fully flushed, so a key in the store is always complete
+func (f *PartitionMaintenanceFlowImpl) exportPartition(ctx context.Context, partitionName, key string) (rows, size int64, checksum string, err error) {
+	path := filepath.Join(f.archiveDir, key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return 0, 0, "", fmt.Errorf("create archive dir: %w", err)
+	}
+	tmpPath := path + ".partial"
+	file, err := os.Create(tmpPath)
+	if err != nil {
+		return 0, 0, "", fmt.Errorf("create archive file: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = file.Close()
+			_ = os.Remove(tmpPath)
+		}
+	}()
+
+	hash := sha256.New()
+	counter := &countingWriter{w: io.MultiWriter(file, hash)}
+	gz := gzip.NewWriter(counter)
+	if rows, err = f.partitionRepo.CopyPartitionCSV(ctx, partitionName, gz); err != nil {
+		return 0, 0, "", fmt.Errorf("export rows: %w", err)
+	}
+	if err = gz.Close(); err != nil {
+		return 0, 0, "", fmt.Errorf("compress archive: %w", err)
+	}
+	if err = file.Sync(); err != nil {
+		return 0, 0, "", fmt.Errorf("sync archive file: %w", err)
+	}
+	if err = file.Close(); err != nil {
+		return 0, 0, "", fmt.Errorf("close archive file: %w", err)
+	}
+	if err = os.Rename(tmpPath, path); err != nil {
+		return 0, 0, "", fmt.Errorf("store archive file: %w", err)
+	}
+	return rows, counter.n, hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// ReadArchivedRows streams the archived rows of a table created in [from, to)
+func (f *PartitionMaintenanceFlowImpl) ReadArchivedRows(ctx context.Context, parentTable string, from, to time.Time, fn func(row map[string]string) error) error {
+	archives, err := f.archiveRepo.Overlapping(ctx, parentTable, from, to)
+	if err != nil {
+		return NewBusinessError("PARTITION_ARCHIVE_LIST_FAILED", "Failed to list partition archives", err)
+	}
+	for _, archive := range archives {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := f.readArchive(archive, from, to, fn); err != nil {
+			return fmt.Errorf("archive %s: %w", archive.PartitionName, err)
+		}
+	}
+	return nil
+}
+
+func (f *PartitionMaintenanceFlowImpl) readArchive(archive *models.PartitionArchive, from, to time.Time, fn func(row map[string]string) error) error {
+	file, err := os.Open(filepath.Join(f.archiveDir, archive.StorageKey))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	return readArchivedCSV(gz, from, to, fn)
+}
+
+// readArchivedCSV decodes a COPY ... WITH (FORMAT csv, HEADER true) export and
+// passes on the rows whose created_at falls in [from, to)
+func readArchivedCSV(r io.Reader, from, to time.Time, fn func(row map[string]string) error) error {
+	reader := csv.NewReader(r)
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		return err
+	}
+	createdAtIdx := -1
+	for i, column := range header {
+		if column == "created_at" {
+			createdAtIdx = i
+			break
+		}
+	}
+	if createdAtIdx < 0 {
+		return fmt.Errorf("archive has no created_at column")
+	}
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		createdAt, err := repository.ParseTimestamptz(record[createdAtIdx])
+		if err != nil {
+			return err
+		}
+		if createdAt.Before(from) || !createdAt.Before(to) {
+			continue
+		}
+		row := make(map[string]string, len(header))
+		for i, column := range header {
+			row[column] = record[i]
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
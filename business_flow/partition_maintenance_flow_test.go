@@ -0,0 +1,67 @@
+package businessflow
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/repository"
+)
+
+func TestPartitionExpired(t *testing.T) {
+	t.Parallel()
+
+	cutoff := time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)
+	cases := []struct {
+		name  string
+		bound string
+		want  bool
+	}{
+		{"legacy before cutoff", "FOR VALUES FROM (MINVALUE) TO ('2025-10-01 00:00:00+00')", true},
+		{"month ending at cutoff", "FOR VALUES FROM ('2025-09-01 00:00:00+00') TO ('2025-10-01 00:00:00+00')", true},
+		{"month after cutoff", "FOR VALUES FROM ('2025-10-01 00:00:00+00') TO ('2025-11-01 00:00:00+00')", false},
+		{"local time zone", "FOR VALUES FROM ('2025-09-01 03:30:00+03:30') TO ('2025-10-01 03:30:00+03:30')", true},
+		{"default partition", "DEFAULT", false},
+	}
+	for _, c := range cases {
+		p, err := repository.ParsePartitionBound("p", c.bound)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", c.name, err)
+		}
+		if got := partitionExpired(p, cutoff); got != c.want {
+			t.Fatalf("%s: expected expired=%v, got %v", c.name, c.want, got)
+		}
+	}
+
+	if _, err := repository.ParsePartitionBound("p", "FOR VALUES IN (1)"); err == nil {
+		t.Fatalf("expected list partition bound to be rejected")
+	}
+}
+
+func TestReadArchivedCSV(t *testing.T) {
+	t.Parallel()
+
+	data := "id,phone_number,created_at\n" +
+		"1,+989120000001,2025-08-31 23:59:59.5+00\n" +
+		"2,+989120000002,2025-09-01 00:00:00+00\n" +
+		"3,\"+98912,0000003\",2025-09-15 12:00:00.123456+00\n" +
+		"4,+989120000004,2025-10-01 00:00:00+00\n"
+	from := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	var ids []string
+	err := readArchivedCSV(strings.NewReader(data), from, to, func(row map[string]string) error {
+		ids = append(ids, row["id"])
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Join(ids, ",") != "2,3" {
+		t.Fatalf("expected rows 2,3 in range, got %v", ids)
+	}
+
+	if err := readArchivedCSV(strings.NewReader("id\n1\n"), from, to, func(map[string]string) error { return nil }); err == nil {
+		t.Fatalf("expected archive without created_at to be rejected")
+	}
+}
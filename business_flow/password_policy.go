@@ -0,0 +1,50 @@
+package businessflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/services"
+)
+
+// PasswordRejectedError reports why the password policy refused a new
+// password; Reasons are the services.Password* reason codes and the lengths
+// are the policy's bounds for describing them
+type PasswordRejectedError struct {
+	Reasons   []string
+	MinLength int
+	MaxLength int
+}
+
+func (e *PasswordRejectedError) Error() string {
+	return fmt.Sprintf("password rejected: %s", strings.Join(e.Reasons, ", "))
+}
+
+func (e *PasswordRejectedError) Unwrap() error {
+	return ErrPasswordRejected
+}
+
+// PasswordRejection returns why a new password was refused when err is a
+// password policy error
+func PasswordRejection(err error) (*PasswordRejectedError, bool) {
+	var rejected *PasswordRejectedError
+	if errors.As(err, &rejected) {
+		return rejected, true
+	}
+	return nil, false
+}
+
+// checkPasswordPolicy is used by signup, password reset and password change
+// before a new password is hashed; a nil policy accepts any password
+func checkPasswordPolicy(ctx context.Context, policy services.PasswordPolicy, password string) error {
+	if policy == nil {
+		return nil
+	}
+	if reasons := policy.Check(ctx, password); len(reasons) > 0 {
+		rules := policy.Rules()
+		return &PasswordRejectedError{Reasons: reasons, MinLength: rules.MinLength, MaxLength: rules.MaxLength}
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package businessflow
+
+import (
+	"context"
+	"testing"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/app/services"
+)
+
+func TestCheckPasswordPolicy(t *testing.T) {
+	policy := services.NewPasswordPolicy(services.PasswordRules{MinLength: 8, MaxLength: 64, RequireNumber: true}, nil)
+
+	if err := checkPasswordPolicy(context.Background(), nil, "x"); err != nil {
+		t.Fatalf("nil policy error = %v, want nil", err)
+	}
+	if err := checkPasswordPolicy(context.Background(), policy, "long enough 1"); err != nil {
+		t.Fatalf("acceptable password error = %v, want nil", err)
+	}
+
+	err := checkPasswordPolicy(context.Background(), policy, "short")
+	wrapped := NewBusinessError("SIGNUP_VALIDATION_FAILED", "Signup validation failed", err)
+	if !IsPasswordRejected(wrapped) {
+		t.Fatalf("error = %v, want password rejected", wrapped)
+	}
+	rejected, ok := PasswordRejection(wrapped)
+	if !ok {
+		t.Fatal("PasswordRejection did not find the rejection through the business error")
+	}
+	want := []string{services.PasswordTooShort, services.PasswordMissingNumber}
+	if len(rejected.Reasons) != len(want) || rejected.Reasons[0] != want[0] || rejected.Reasons[1] != want[1] {
+		t.Fatalf("reasons = %v, want %v", rejected.Reasons, want)
+	}
+	if rejected.MinLength != 8 || rejected.MaxLength != 64 {
+		t.Fatalf("bounds = %d..%d, want 8..64", rejected.MinLength, rejected.MaxLength)
+	}
+}
+
+func TestResetPasswordChecksPolicyBeforeOTP(t *testing.T) {
+	// Without Redis or a database the reset can only fail on the policy,
+	// which must be checked before the OTP is used up
+	lf := &LoginFlowImpl{passwordPolicy: services.NewPasswordPolicy(services.PasswordRules{MinLength: 12}, nil)}
+	_, err := lf.ResetPassword(context.Background(), &dto.ResetPasswordRequest{
+		CustomerID:      1,
+		OTPCode:         "123456",
+		NewPassword:     "Short1!",
+		ConfirmPassword: "Short1!",
+	}, nil)
+	if !IsPasswordRejected(err) {
+		t.Fatalf("error = %v, want password rejected", err)
+	}
+}
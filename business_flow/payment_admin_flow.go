@@ -9,8 +9,10 @@ import (
 	"strings"
 
 	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/app/i18n"
 	"github.com/amirphl/Yamata-no-Orochi/config"
 	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/pricing"
 	"github.com/amirphl/Yamata-no-Orochi/repository"
 	"github.com/amirphl/Yamata-no-Orochi/utils"
 	"github.com/google/uuid"
@@ -26,6 +28,8 @@ type PaymentAdminFlow interface {
 	AdminGetDepositReceiptFile(ctx context.Context, receiptUUID string) ([]byte, string, string, error)
 	AdminUpdateDepositReceiptStatus(ctx context.Context, req *dto.AdminUpdateDepositReceiptStatusRequest, adminID uint, metadata *ClientMetadata) (*dto.SubmitDepositReceiptResponse, error)
 	AddInvoiceToTransaction(ctx context.Context, req *dto.AdminAddInvoiceToTransactionRequest, adminID uint, metadata *ClientMetadata) (*dto.AdminAddInvoiceToTransactionResponse, error)
+	AdminListAgencyPayables(ctx context.Context, filter dto.AdminListAgencyPayablesFilter) (*dto.AdminListAgencyPayablesResponse, error)
+	AdminMarkAgencyPayablePaid(ctx context.Context, id uuid.UUID, req *dto.AdminMarkAgencyPayablePaidRequest, adminID uint) (*dto.AdminAgencyPayableResponse, error)
 }
 
 // NewPaymentAdminFlow creates a new admin payment flow instance.
@@ -39,10 +43,14 @@ func NewPaymentAdminFlow(
 	agencyDiscountRepo repository.AgencyDiscountRepository,
 	depositReceiptRepo repository.DepositReceiptRepository,
 	multimediaRepo repository.MultimediaAssetRepository,
+	taxInvoiceRepo repository.TaxInvoiceRepository,
+	notificationRepo repository.NotificationRepository,
+	agencyPayableRepo repository.AgencySharePayableRepository,
 	db *gorm.DB,
 	atipayCfg config.AtipayConfig,
 	sysCfg config.SystemConfig,
 	deploymentCfg config.DeploymentConfig,
+	invoiceCfg config.InvoiceConfig,
 ) PaymentAdminFlow {
 	return &PaymentFlowImpl{
 		paymentRequestRepo:  paymentRequestRepo,
@@ -54,10 +62,14 @@ func NewPaymentAdminFlow(
 		agencyDiscountRepo:  agencyDiscountRepo,
 		depositReceiptRepo:  depositReceiptRepo,
 		multimediaRepo:      multimediaRepo,
+		taxInvoiceRepo:      taxInvoiceRepo,
+		notificationRepo:    notificationRepo,
+		agencyPayableRepo:   agencyPayableRepo,
 		db:                  db,
 		atipayCfg:           atipayCfg,
 		sysCfg:              sysCfg,
 		deploymentCfg:       deploymentCfg,
+		invoiceCfg:          invoiceCfg,
 	}
 }
 
@@ -90,7 +102,7 @@ func (p *PaymentFlowImpl) AdminChargeWallet(
 	var paymentRequest *models.PaymentRequest
 	isIdempotentReplay := false
 
-	err := repository.WithTransaction(ctx, p.db, func(txCtx context.Context) error {
+	err := withVersionRetry(ctx, p.db, func(txCtx context.Context) error {
 		var err error
 
 		lockName := "charge_wallet_by_admin:" + idempotencyKey
@@ -140,7 +152,7 @@ func (p *PaymentFlowImpl) AdminChargeWallet(
 		}
 		customer.Wallet = wallet
 
-		paymentRequest, err = p.createPaymentRequest(txCtx, customer, req.AmountWithTax, "EN")
+		paymentRequest, err = p.createPaymentRequest(txCtx, customer, req.AmountWithTax, "EN", "")
 		if err != nil {
 			return err
 		}
@@ -303,7 +315,17 @@ func (p *PaymentFlowImpl) AdminPreviewWalletChargeImpact(
 		return nil, NewBusinessError("PREVIEW_WALLET_CHARGE_IMPACT_FAILED", "Failed to preview wallet charge impact", err)
 	}
 
-	scatteredSettlementItems, err := p.calculateScatteredSettlementItems(ctx, customer, req.AmountWithTax)
+	discountRate, _, err := agencyDiscountRate(ctx, p.transactionRepo, agencyDiscount)
+	if err != nil {
+		logAdminAction(ctx, p.auditRepo, models.AuditActionAdminPreviewWalletChargeImpactFailed, "Admin preview wallet charge impact", false, &req.CustomerID, map[string]any{
+			"customer_id":     req.CustomerID,
+			"admin_id":        adminID,
+			"amount_with_tax": req.AmountWithTax,
+		}, err)
+		return nil, NewBusinessError("PREVIEW_WALLET_CHARGE_IMPACT_FAILED", "Failed to preview wallet charge impact", err)
+	}
+
+	scatteredSettlementItems, err := p.calculateScatteredSettlementItems(ctx, customer, req.AmountWithTax, false)
 	if err != nil {
 		logAdminAction(ctx, p.auditRepo, models.AuditActionAdminPreviewWalletChargeImpactFailed, "Admin preview wallet charge impact", false, &req.CustomerID, map[string]any{
 			"customer_id":     req.CustomerID,
@@ -313,9 +335,9 @@ func (p *PaymentFlowImpl) AdminPreviewWalletChargeImpact(
 		return nil, NewBusinessError("PREVIEW_WALLET_CHARGE_IMPACT_FAILED", "Failed to preview wallet charge impact", err)
 	}
 
-	amount := uint64(float64(req.AmountWithTax) * 10 / 11)
-	tax := req.AmountWithTax - amount
-	creditIncrease := uint64(float64(amount)/(1-agencyDiscount.DiscountRate)) - amount
+	split := pricing.SplitGross(req.AmountWithTax, p.sysCfg.TaxRateAt(utils.UTCNow()))
+	amount, tax := split.Net, split.Tax
+	creditIncrease := pricing.CustomerCredit(amount, discountRate)
 
 	resp := &dto.AdminPreviewWalletChargeImpactResponse{
 		Message:            "Wallet charge impact preview calculated successfully",
@@ -323,7 +345,7 @@ func (p *PaymentFlowImpl) AdminPreviewWalletChargeImpact(
 		CustomerID:         customer.ID,
 		AgencyID:           *customer.ReferrerAgencyID,
 		AgencyDiscountID:   agencyDiscount.ID,
-		DiscountRate:       agencyDiscount.DiscountRate,
+		DiscountRate:       discountRate,
 		AmountWithTax:      req.AmountWithTax,
 		Amount:             amount,
 		Tax:                tax,
@@ -338,7 +360,7 @@ func (p *PaymentFlowImpl) AdminPreviewWalletChargeImpact(
 		"admin_id":              adminID,
 		"amount_with_tax":       req.AmountWithTax,
 		"agency_discount_id":    agencyDiscount.ID,
-		"discount_rate":         agencyDiscount.DiscountRate,
+		"discount_rate":         discountRate,
 		"free_increase":         resp.FreeIncrease,
 		"credit_increase":       resp.CreditIncrease,
 		"system_share_with_tax": resp.SystemShareWithTax,
@@ -402,6 +424,7 @@ func (p *PaymentFlowImpl) AdminListDepositReceipts(ctx context.Context, f models
 			PreviewType:      previewType,
 			CreatedAt:        r.CreatedAt,
 		})
+		setDepositReceiptTransfer(&resp.Items[len(resp.Items)-1], r)
 	}
 	logAdminAction(ctx, p.auditRepo, models.AuditActionAdminDepositReceiptReviewed, "Admin listed deposit receipts", true, nil, map[string]any{
 		"limit":      limit,
@@ -427,12 +450,17 @@ func (p *PaymentFlowImpl) AdminListTransactions(ctx context.Context, req *dto.Ad
 	if err := p.validateAdminListTransactionsRequest(req); err != nil {
 		return nil, err
 	}
+	list, err := models.TransactionListFields.Parse(req.Filter, req.Sort)
+	if err != nil {
+		return nil, err
+	}
 
 	filter := models.TransactionFilter{
 		Source:       utils.ToPtr(models.TransactionSourceIncreaseCustomerFreePlusCredit),
 		Operation:    utils.ToPtr("increase_customer_free_plus_credit"),
 		CustomerID:   req.CustomerID,
 		CustomerName: req.CustomerName,
+		List:         list,
 	}
 	if req.StartDate != nil {
 		filter.CreatedAfter = req.StartDate
@@ -599,6 +627,19 @@ func (p *PaymentFlowImpl) AddInvoiceToTransaction(ctx context.Context, req *dto.
 	return resp, nil
 }
 
+// depositReceiptCreditAmount is what approving a receipt credits: the amount
+// on the matched statement entry, or the claimed amount when none is given.
+// A statement entry may show less than the customer claimed, never more.
+func depositReceiptCreditAmount(receipt *models.DepositReceipt, matchedAmount uint64) (uint64, error) {
+	if matchedAmount == 0 {
+		return receipt.Amount, nil
+	}
+	if matchedAmount > receipt.Amount {
+		return 0, ErrDepositReceiptMatchedAmountTooHigh
+	}
+	return matchedAmount, nil
+}
+
 // AdminUpdateDepositReceiptStatus approves or rejects a receipt and on approval credits wallet like normal flow.
 func (p *PaymentFlowImpl) AdminUpdateDepositReceiptStatus(ctx context.Context, req *dto.AdminUpdateDepositReceiptStatusRequest, adminID uint, metadata *ClientMetadata) (*dto.SubmitDepositReceiptResponse, error) {
 	if req == nil {
@@ -608,15 +649,19 @@ func (p *PaymentFlowImpl) AdminUpdateDepositReceiptStatus(ctx context.Context, r
 	if action != "approve" && action != "reject" {
 		return nil, ErrDepositReceiptInvalidStatus
 	}
+	bankReference := strings.TrimSpace(req.BankReference)
 	if action == "approve" {
 		// if err := p.validateCustomerInvoiceUUIDForApproval(ctx, req.CustomerInvoiceUUID); err != nil {
 		// 	return nil, NewBusinessError("ADMIN_RECEIPT_UPDATE_INVALID", "Invalid request", err)
 		// }
+		if bankReference == "" {
+			return nil, ErrDepositReceiptBankReferenceRequired
+		}
 	}
 
 	var customer models.Customer
 	var receipt *models.DepositReceipt
-	err := repository.WithTransaction(ctx, p.db, func(txCtx context.Context) error {
+	err := withVersionRetry(ctx, p.db, func(txCtx context.Context) error {
 		var err error
 		receipt, err = p.depositReceiptRepo.ByUUID(txCtx, req.ReceiptUUID)
 		if err != nil {
@@ -644,10 +689,16 @@ func (p *PaymentFlowImpl) AdminUpdateDepositReceiptStatus(ctx context.Context, r
 			// }
 		}
 
+		customer, err = getCustomer(txCtx, p.customerRepo, receipt.CustomerID)
+		if err != nil {
+			return err
+		}
+
 		if action == "reject" {
 			receipt.Status = models.DepositReceiptStatusRejected
 			receipt.StatusReason = "Rejected by admin"
 			receipt.ReviewerID = &adminID
+			receipt.ReviewedAt = utils.ToPtr(utils.UTCNow())
 			if req.Reason != "" {
 				receipt.RejectionNote = &req.Reason
 			}
@@ -656,11 +707,23 @@ func (p *PaymentFlowImpl) AdminUpdateDepositReceiptStatus(ctx context.Context, r
 			}
 			desc := fmt.Sprintf("Deposit receipt %s rejected by admin %d", req.ReceiptUUID, adminID)
 			_ = createAuditLog(txCtx, p.auditRepo, nil, models.AuditActionAdminUpdateDepositReceiptStatus, desc, true, nil, metadata)
+			notifyInbox(txCtx, p.notificationRepo, customer.ID, models.NotificationKindDepositReceiptRejected, i18n.Args{
+				"Amount": receipt.Amount,
+				"Reason": req.Reason,
+			})
 			return nil
 		}
 
-		// Approve path mirrors AdminChargeWallet + PaymentCallback success.
-		customer, err = getCustomer(txCtx, p.customerRepo, receipt.CustomerID)
+		// Approve path mirrors AdminChargeWallet + PaymentCallback success,
+		// crediting what the matched statement entry shows
+		matched, err := p.depositReceiptRepo.List(txCtx, models.DepositReceiptFilter{BankReference: &bankReference, OmitFileData: true}, 1, 0, "")
+		if err != nil {
+			return err
+		}
+		if len(matched) > 0 {
+			return ErrDepositReceiptBankReferenceUsed
+		}
+		creditAmount, err := depositReceiptCreditAmount(receipt, req.MatchedAmount)
 		if err != nil {
 			return err
 		}
@@ -670,7 +733,7 @@ func (p *PaymentFlowImpl) AdminUpdateDepositReceiptStatus(ctx context.Context, r
 		}
 		customer.Wallet = wallet
 
-		paymentRequest, err := p.createPaymentRequest(txCtx, customer, receipt.Amount, receipt.Lang)
+		paymentRequest, err := p.createPaymentRequest(txCtx, customer, creditAmount, receipt.Lang, "")
 		if err != nil {
 			return err
 		}
@@ -683,6 +746,11 @@ func (p *PaymentFlowImpl) AdminUpdateDepositReceiptStatus(ctx context.Context, r
 		m["source"] = "deposit_receipt"
 		m["deposit_receipt_uuid"] = receipt.UUID.String()
 		m["deposit_invoice_number"] = receipt.InvoiceNumber
+		m["bank_reference"] = bankReference
+		m["claimed_amount"] = receipt.Amount
+		if receipt.TrackingNumber != nil {
+			m["tracking_number"] = *receipt.TrackingNumber
+		}
 		// m["customer_invoice_uuid"] = req.CustomerInvoiceUUID
 		m["admin_id"] = adminID
 		m["payment_channel"] = "deposit_receipt_manual"
@@ -725,12 +793,15 @@ func (p *PaymentFlowImpl) AdminUpdateDepositReceiptStatus(ctx context.Context, r
 		receipt.Status = models.DepositReceiptStatusApproved
 		receipt.StatusReason = "Approved and credited"
 		receipt.ReviewerID = &adminID
+		receipt.ReviewedAt = utils.ToPtr(utils.UTCNow())
+		receipt.BankReference = &bankReference
+		receipt.MatchedAmount = &creditAmount
 		if err := p.depositReceiptRepo.Update(txCtx, receipt); err != nil {
 			return err
 		}
 
 		// desc := fmt.Sprintf("Deposit receipt %s approved by admin %d and credited (customer_invoice_uuid=%s)", req.ReceiptUUID, adminID, req.CustomerInvoiceUUID)
-		desc := fmt.Sprintf("Deposit receipt %s approved by admin %d and credited", req.ReceiptUUID, adminID)
+		desc := fmt.Sprintf("Deposit receipt %s approved by admin %d and credited %d matched with bank reference %s", req.ReceiptUUID, adminID, creditAmount, bankReference)
 		_ = createAuditLog(txCtx, p.auditRepo, &customer, models.AuditActionAdminUpdateDepositReceiptStatus, desc, true, nil, metadata)
 		return nil
 	})
@@ -745,6 +816,8 @@ func (p *PaymentFlowImpl) AdminUpdateDepositReceiptStatus(ctx context.Context, r
 		return nil, NewBusinessError("ADMIN_RECEIPT_UPDATE_FAILED", "Failed to update deposit receipt", err)
 	}
 
+	p.notifyDepositReceiptReviewed(ctx, &customer, receipt, req.Reason)
+
 	msg := fmt.Sprintf("Admin %d updated receipt %s to %s", adminID, req.ReceiptUUID, req.Action)
 	_ = createAuditLog(ctx, p.auditRepo, &customer, models.AuditActionAdminUpdateDepositReceiptStatus, msg, true, nil, metadata)
 	logAdminAction(ctx, p.auditRepo, models.AuditActionAdminUpdateDepositReceiptStatus, "Admin updated deposit receipt status", true, &receipt.CustomerID, map[string]any{
@@ -753,6 +826,8 @@ func (p *PaymentFlowImpl) AdminUpdateDepositReceiptStatus(ctx context.Context, r
 		// "customer_invoice_uuid": req.CustomerInvoiceUUID,
 		"customer_id":      receipt.CustomerID,
 		"resulting_status": receipt.Status,
+		"bank_reference":   receipt.BankReference,
+		"matched_amount":   receipt.MatchedAmount,
 	}, nil)
 
 	return &dto.SubmitDepositReceiptResponse{
@@ -763,6 +838,25 @@ func (p *PaymentFlowImpl) AdminUpdateDepositReceiptStatus(ctx context.Context, r
 	}, nil
 }
 
+// notifyDepositReceiptReviewed texts the customer the outcome of a receipt
+// review. The inbox already has it: a credited payment on approval, the
+// rejection otherwise.
+func (p *PaymentFlowImpl) notifyDepositReceiptReviewed(ctx context.Context, customer *models.Customer, receipt *models.DepositReceipt, reason string) {
+	if customer.RepresentativeMobile == "" {
+		return
+	}
+	var msg string
+	switch receipt.Status {
+	case models.DepositReceiptStatusApproved:
+		msg = p.localizer.Customer(customer, "deposit_receipt.approved", i18n.Args{"Amount": *receipt.MatchedAmount})
+	case models.DepositReceiptStatusRejected:
+		msg = p.localizer.Customer(customer, "deposit_receipt.rejected", i18n.Args{"Amount": receipt.Amount, "Reason": reason})
+	default:
+		return
+	}
+	enqueueSMS(ctx, p.jobs, []string{customer.RepresentativeMobile}, msg)
+}
+
 func paymentMetadataAdminID(raw json.RawMessage) uint {
 	if len(raw) == 0 {
 		return 0
@@ -884,6 +978,7 @@ func (p *PaymentFlowImpl) convertTransactionHistoryRecordToTransactionHistoryIte
 		Operation:           operation,
 		Source:              source,
 		DepositMethod:       depositMethod,
+		IsSandbox:           transaction.IsSandbox,
 		DateTime:            transaction.CreatedAt,
 		ExternalRef:         externalRef,
 		BalanceBefore:       balanceBefore,
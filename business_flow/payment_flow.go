@@ -6,20 +6,25 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"image"
 	"image/jpeg"
 	"image/png"
+	"log"
 	"net/http"
-	"os"
 	"strings"
 	"time"
 
 	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/app/httpclient"
+	"github.com/amirphl/Yamata-no-Orochi/app/i18n"
 	"github.com/amirphl/Yamata-no-Orochi/app/services"
 	"github.com/amirphl/Yamata-no-Orochi/config"
 	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/pricing"
 	"github.com/amirphl/Yamata-no-Orochi/repository"
+	"github.com/amirphl/Yamata-no-Orochi/templates"
 	"github.com/amirphl/Yamata-no-Orochi/utils"
 	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
@@ -27,6 +32,10 @@ import (
 	"gorm.io/gorm"
 )
 
+// atipayClientName names the outbound client for Atipay; its circuit breaker
+// decides whether wallet charges are accepted
+const atipayClientName = "atipay"
+
 // PaymentFlow handles the complete payment business logic
 type PaymentFlow interface {
 	ChargeWallet(ctx context.Context, req *dto.ChargeWalletRequest, metadata *ClientMetadata) (*dto.ChargeWalletResponse, error)
@@ -41,6 +50,7 @@ type PaymentFlow interface {
 	UpdateDepositReceiptFile(ctx context.Context, customerID uint, receiptUUID string, req *dto.UpdateDepositReceiptFileRequest) error
 	DeleteDepositReceiptFile(ctx context.Context, customerID uint, receiptUUID string) error
 	NotifyInvoiceIssueRequest(ctx context.Context, req *dto.NotifyInvoiceIssueRequest, customerID uint, metadata *ClientMetadata) (*dto.NotifyInvoiceIssueResponse, error)
+	ExpireStaleRequests(ctx context.Context) (map[models.PaymentRequestStatus]int, error)
 }
 
 // PaymentFlowImpl implements the payment business flow
@@ -55,9 +65,14 @@ type PaymentFlowImpl struct {
 	agencyDiscountRepo  repository.AgencyDiscountRepository
 	depositReceiptRepo  repository.DepositReceiptRepository
 	multimediaRepo      repository.MultimediaAssetRepository
+	taxInvoiceRepo      repository.TaxInvoiceRepository
+	notificationRepo    repository.NotificationRepository
+	agencyPayableRepo   repository.AgencySharePayableRepository
 	notifier            services.SMSService
+	jobs                JobQueue
+	shebaVerification   ShebaVerificationFlow
 	adminCfg            config.AdminConfig
-	messageCfg          config.MessageConfig
+	localizer           *i18n.Localizer
 	cacheCfg            config.CacheConfig
 	rc                  *redis.Client
 	db                  *gorm.DB
@@ -66,6 +81,9 @@ type PaymentFlowImpl struct {
 	atipayCfg     config.AtipayConfig
 	sysCfg        config.SystemConfig
 	deploymentCfg config.DeploymentConfig
+	invoiceCfg    config.InvoiceConfig
+	// atipayTerminals spreads recharges over the configured Atipay terminals
+	atipayTerminals *atipayTerminalRouter
 }
 
 // NewPaymentFlow creates a new payment flow instance
@@ -80,15 +98,21 @@ func NewPaymentFlow(
 	agencyDiscountRepo repository.AgencyDiscountRepository,
 	depositReceiptRepo repository.DepositReceiptRepository,
 	multimediaRepo repository.MultimediaAssetRepository,
+	taxInvoiceRepo repository.TaxInvoiceRepository,
+	notificationRepo repository.NotificationRepository,
+	agencyPayableRepo repository.AgencySharePayableRepository,
 	notifier services.SMSService,
+	jobs JobQueue,
+	shebaVerification ShebaVerificationFlow,
 	adminCfg config.AdminConfig,
-	messageCfg config.MessageConfig,
+	localizer *i18n.Localizer,
 	cacheCfg config.CacheConfig,
 	rc *redis.Client,
 	db *gorm.DB,
 	atipayCfg config.AtipayConfig,
 	sysCfg config.SystemConfig,
 	deploymentCfg config.DeploymentConfig,
+	invoiceCfg config.InvoiceConfig,
 ) PaymentFlow {
 	return &PaymentFlowImpl{
 		paymentRequestRepo:  paymentRequestRepo,
@@ -101,15 +125,22 @@ func NewPaymentFlow(
 		agencyDiscountRepo:  agencyDiscountRepo,
 		depositReceiptRepo:  depositReceiptRepo,
 		multimediaRepo:      multimediaRepo,
+		taxInvoiceRepo:      taxInvoiceRepo,
+		notificationRepo:    notificationRepo,
+		agencyPayableRepo:   agencyPayableRepo,
 		notifier:            notifier,
+		jobs:                jobs,
+		shebaVerification:   shebaVerification,
 		adminCfg:            adminCfg,
-		messageCfg:          messageCfg,
+		localizer:           localizer,
 		cacheCfg:            cacheCfg,
 		rc:                  rc,
 		db:                  db,
 		atipayCfg:           atipayCfg,
+		atipayTerminals:     newAtipayTerminalRouter(atipayCfg),
 		sysCfg:              sysCfg,
 		deploymentCfg:       deploymentCfg,
+		invoiceCfg:          invoiceCfg,
 	}
 }
 
@@ -118,6 +149,7 @@ func (p *PaymentFlowImpl) ChargeWallet(ctx context.Context, req *dto.ChargeWalle
 	var customer models.Customer
 	var paymentRequest *models.PaymentRequest
 	var atipayToken string
+	var fallback models.AgencySettlementFallbackReason
 
 	err := repository.WithTransaction(ctx, p.db, func(txCtx context.Context) error {
 		var err error
@@ -125,12 +157,33 @@ func (p *PaymentFlowImpl) ChargeWallet(ctx context.Context, req *dto.ChargeWalle
 		if err != nil {
 			return err
 		}
+		if customer.InSandbox() {
+			return ErrSandboxRealPayment
+		}
+		if err := checkCustomerPolicy(customer, CustomerActionRechargeWallet); err != nil {
+			return err
+		}
 
 		// Admin customer numbers can bypass the public wallet charge amount restrictions.
 		if err := p.validateChargeWalletRequest(req, customer.RepresentativeMobile); err != nil {
 			return err
 		}
 
+		// Fail fast while Atipay's breaker is open instead of waiting out
+		// the timeout on every request
+		if !httpclient.Available(atipayClientName) {
+			return ErrPaymentGatewayUnavailable
+		}
+
+		// The agency's share is settled to its Sheba number. The verification
+		// result is stored with ctx so it outlives a failed recharge.
+		if customer.ReferrerAgencyID != nil {
+			fallback, err = p.agencySettlementFallback(ctx, *customer.ReferrerAgencyID)
+			if err != nil {
+				return err
+			}
+		}
+
 		// Check if customer has a wallet, create one if it doesn't exist
 		wallet, err := p.walletRepo.ByCustomerID(txCtx, customer.ID)
 		if err != nil {
@@ -140,12 +193,12 @@ func (p *PaymentFlowImpl) ChargeWallet(ctx context.Context, req *dto.ChargeWalle
 		customer.Wallet = wallet
 
 		// Create payment request
-		paymentRequest, err = p.createPaymentRequest(txCtx, customer, req.AmountWithTax, req.Lang)
+		paymentRequest, err = p.createPaymentRequest(txCtx, customer, req.AmountWithTax, req.Lang, fallback)
 		if err != nil {
 			return err
 		}
 
-		atipayToken, err = p.callAtipayGetToken(txCtx, customer, *paymentRequest)
+		atipayToken, err = p.callAtipayGetToken(txCtx, customer, *paymentRequest, fallback != "")
 		if err != nil {
 			return err
 		}
@@ -181,6 +234,10 @@ func (p *PaymentFlowImpl) ChargeWallet(ctx context.Context, req *dto.ChargeWalle
 	// Create success audit log
 	msg := fmt.Sprintf("Generated payment token for payment request %d for customer %d", paymentRequest.ID, customer.ID)
 	_ = createAuditLog(ctx, p.auditRepo, &customer, models.AuditActionWalletChargeCompleted, msg, true, nil, metadata)
+	if fallback != "" {
+		msg := fmt.Sprintf("Agency %d share of payment request %d is settled to the system Sheba number: %s", *customer.ReferrerAgencyID, paymentRequest.ID, fallback)
+		_ = createAuditLog(ctx, p.auditRepo, &customer, models.AuditActionAgencySettlementFallback, msg, true, nil, metadata)
+	}
 
 	// Build resp
 	resp := &dto.ChargeWalletResponse{
@@ -217,8 +274,41 @@ func (p *PaymentFlowImpl) validateChargeWalletRequest(req *dto.ChargeWalletReque
 	return nil
 }
 
+// agencySettlementFallback returns why the agency's share of a recharge is
+// settled to the system Sheba number, or the empty reason when it goes to
+// the agency's. An unusable agency Sheba number fails the recharge unless
+// the settlement fallback is on.
+func (p *PaymentFlowImpl) agencySettlementFallback(ctx context.Context, agencyID uint) (models.AgencySettlementFallbackReason, error) {
+	agency, err := getAgency(ctx, p.customerRepo, agencyID)
+	if err != nil {
+		return "", err
+	}
+	if _, err := ValidateShebaNumber(agency.ShebaNumber); err != nil {
+		if !p.atipayCfg.SettlementFallback {
+			return "", err
+		}
+		return models.AgencySettlementShebaInvalid, nil
+	}
+	if p.shebaVerification == nil {
+		return "", nil
+	}
+
+	err = p.shebaVerification.RequireAgencySheba(ctx, agencyID)
+	switch {
+	case err == nil:
+		return "", nil
+	case !p.atipayCfg.SettlementFallback:
+		return "", err
+	case IsAgencyShebaNotVerified(err):
+		return models.AgencySettlementShebaNotVerified, nil
+	case IsShebaInquiryUnavailable(err):
+		return models.AgencySettlementShebaInquiryUnavailable, nil
+	}
+	return "", err
+}
+
 // createPaymentRequest creates a new payment request record
-func (p *PaymentFlowImpl) createPaymentRequest(ctx context.Context, customer models.Customer, amountWithTax uint64, lang string) (*models.PaymentRequest, error) {
+func (p *PaymentFlowImpl) createPaymentRequest(ctx context.Context, customer models.Customer, amountWithTax uint64, lang string, fallback models.AgencySettlementFallbackReason) (*models.PaymentRequest, error) {
 	if customer.ReferrerAgencyID == nil {
 		return nil, ErrReferrerAgencyIDRequired
 	}
@@ -236,22 +326,32 @@ func (p *PaymentFlowImpl) createPaymentRequest(ctx context.Context, customer mod
 	if agencyDiscount == nil {
 		return nil, ErrAgencyDiscountNotFound
 	}
+	discountRate, monthlyAmountWithTax, err := agencyDiscountRate(ctx, p.transactionRepo, agencyDiscount)
+	if err != nil {
+		return nil, err
+	}
 
-	scatteredSettlementItems, err := p.calculateScatteredSettlementItems(ctx, customer, amountWithTax)
+	scatteredSettlementItems, err := p.calculateScatteredSettlementItems(ctx, customer, amountWithTax, fallback != "")
 	if err != nil {
 		return nil, err
 	}
 
-	metadata, _ := json.Marshal(map[string]any{
-		"source":                "wallet_recharge",
-		"amount_with_tax":       amountWithTax,
-		"system_share_with_tax": scatteredSettlementItems[0].Amount,
-		"agency_share_with_tax": scatteredSettlementItems[1].Amount,
-		"agency_discount_id":    agencyDiscount.ID,
-		"agency_id":             customer.ReferrerAgencyID,
-		"customer_id":           customer.ID,
-		"payment_channel":       "atipay",
-	})
+	meta := map[string]any{
+		"source":                  "wallet_recharge",
+		"amount_with_tax":         amountWithTax,
+		"system_share_with_tax":   scatteredSettlementItems[0].Amount,
+		"agency_share_with_tax":   scatteredSettlementItems[1].Amount,
+		"agency_discount_id":      agencyDiscount.ID,
+		"agency_discount_rate":    discountRate,
+		"monthly_amount_with_tax": monthlyAmountWithTax,
+		"agency_id":               customer.ReferrerAgencyID,
+		"customer_id":             customer.ID,
+		"payment_channel":         "atipay",
+	}
+	if fallback != "" {
+		meta["settlement_fallback"] = fallback
+	}
+	metadata, _ := json.Marshal(meta)
 
 	// Create payment request
 	paymentRequest := &models.PaymentRequest{
@@ -274,6 +374,8 @@ func (p *PaymentFlowImpl) createPaymentRequest(ctx context.Context, customer mod
 		ExpiresAt:    &expiresAt,
 		Metadata:     json.RawMessage(metadata),
 	}
+	// Its callback is verified with the API key of the same terminal
+	paymentRequest.AtipayTerminal = p.atipayTerminals.pick(amountWithTax).Terminal
 	if err := p.paymentRequestRepo.Save(ctx, paymentRequest); err != nil {
 		return nil, err
 	}
@@ -281,24 +383,67 @@ func (p *PaymentFlowImpl) createPaymentRequest(ctx context.Context, customer mod
 	return paymentRequest, nil
 }
 
+// paymentExpiryBatchSize bounds the requests of each status expired per sweep
+const paymentExpiryBatchSize = 100
+
+// ExpireStaleRequests expires payment requests still waiting for an Atipay
+// token or callback past their expiry, and returns how many were expired by
+// the status they were in. Callbacks for them are already refused, so they
+// would otherwise stay open.
+func (p *PaymentFlowImpl) ExpireStaleRequests(ctx context.Context) (map[models.PaymentRequestStatus]int, error) {
+	now := utils.UTCNow()
+	expired := make(map[models.PaymentRequestStatus]int)
+	for _, status := range []models.PaymentRequestStatus{
+		models.PaymentRequestStatusCreated,
+		models.PaymentRequestStatusTokenized,
+		models.PaymentRequestStatusPending,
+	} {
+		stale, err := p.paymentRequestRepo.ByFilter(ctx, models.PaymentRequestFilter{
+			Status:        &status,
+			ExpiresBefore: &now,
+		}, "expires_at ASC", paymentExpiryBatchSize, 0)
+		if err != nil {
+			return expired, err
+		}
+		for _, pr := range stale {
+			reason := fmt.Sprintf("expired while %s", status)
+			ok, err := p.paymentRequestRepo.MarkExpired(ctx, pr.ID, status, reason)
+			if err != nil {
+				log.Printf("expire payment request %s: %v", pr.UUID, err)
+				continue
+			}
+			if !ok {
+				continue
+			}
+			expired[status]++
+
+			msg := fmt.Sprintf("Payment request %s of %d expired while %s", pr.InvoiceNumber, pr.Amount, status)
+			errMsg := "payment request expired before a callback arrived"
+			_ = createAuditLog(ctx, p.auditRepo, &models.Customer{ID: pr.CustomerID}, models.AuditActionPaymentExpired, msg, false, &errMsg, nil)
+		}
+	}
+	return expired, nil
+}
+
 type ScatteredSettlementItem struct {
 	Amount uint64 `json:"amount"`
 	IBAN   string `json:"iban"`
 }
 
-func (p *PaymentFlowImpl) calculateScatteredSettlementItems(ctx context.Context, customer models.Customer, amountWithTax uint64) ([]ScatteredSettlementItem, error) {
+// calculateScatteredSettlementItems splits a recharge into the system's and
+// the agency's share. With settleToSystem both are settled to the system
+// Sheba number.
+func (p *PaymentFlowImpl) calculateScatteredSettlementItems(ctx context.Context, customer models.Customer, amountWithTax uint64, settleToSystem bool) ([]ScatteredSettlementItem, error) {
 	// Default shares fallback (50/50) if no discount found
 	var systemShareWithTax uint64
 	var agencyShareWithTax uint64
 
-	discountRate, shebaNumber, err := p.getAgencyDiscountAndIBAN(ctx, customer)
+	discountRate, shebaNumber, err := p.getAgencyDiscountAndIBAN(ctx, customer, settleToSystem)
 	if err != nil {
 		return nil, err
 	}
 
-	x := float64(amountWithTax) / (1 - discountRate)
-	systemShareWithTax = uint64(x / 2)
-	agencyShareWithTax = uint64(amountWithTax - systemShareWithTax)
+	systemShareWithTax, agencyShareWithTax = pricing.SplitShares(amountWithTax, discountRate)
 
 	scatteredSettlementItems := make([]ScatteredSettlementItem, 0, 2)
 
@@ -313,6 +458,9 @@ func (p *PaymentFlowImpl) calculateScatteredSettlementItems(ctx context.Context,
 	if systemUser.ShebaNumber == nil {
 		return nil, ErrSystemUserShebaNumberNotFound
 	}
+	if settleToSystem {
+		shebaNumber = *systemUser.ShebaNumber
+	}
 
 	scatteredSettlementItems = append(scatteredSettlementItems, ScatteredSettlementItem{
 		Amount: systemShareWithTax,
@@ -326,7 +474,9 @@ func (p *PaymentFlowImpl) calculateScatteredSettlementItems(ctx context.Context,
 	return scatteredSettlementItems, nil
 }
 
-func (p *PaymentFlowImpl) getAgencyDiscountAndIBAN(ctx context.Context, customer models.Customer) (float64, string, error) {
+// getAgencyDiscountAndIBAN returns the agency's discount rate for the
+// customer and its Sheba number, which is not looked at with skipSheba
+func (p *PaymentFlowImpl) getAgencyDiscountAndIBAN(ctx context.Context, customer models.Customer, skipSheba bool) (float64, string, error) {
 	// Determine discount and agency IBAN if referrer exists
 	var discountRate float64
 	var shebaNumber string
@@ -336,9 +486,11 @@ func (p *PaymentFlowImpl) getAgencyDiscountAndIBAN(ctx context.Context, customer
 		return 0, "", err
 	}
 
-	shebaNumber, err = ValidateShebaNumber(agency.ShebaNumber)
-	if err != nil {
-		return 0, "", err
+	if !skipSheba {
+		shebaNumber, err = ValidateShebaNumber(agency.ShebaNumber)
+		if err != nil {
+			return 0, "", err
+		}
 	}
 
 	ad, err := p.agencyDiscountRepo.GetActiveDiscount(ctx, agency.ID, customer.ID)
@@ -347,15 +499,18 @@ func (p *PaymentFlowImpl) getAgencyDiscountAndIBAN(ctx context.Context, customer
 	}
 
 	if ad != nil {
-		discountRate = ad.DiscountRate
+		discountRate, _, err = agencyDiscountRate(ctx, p.transactionRepo, ad)
+		if err != nil {
+			return 0, "", err
+		}
 	}
 
 	return discountRate, shebaNumber, nil
 }
 
 // callAtipayGetToken calls Atipay's get-token API
-func (p *PaymentFlowImpl) callAtipayGetToken(ctx context.Context, customer models.Customer, paymentRequest models.PaymentRequest) (string, error) {
-	scatteredSettlementItems, err := p.calculateScatteredSettlementItems(ctx, customer, paymentRequest.Amount)
+func (p *PaymentFlowImpl) callAtipayGetToken(ctx context.Context, customer models.Customer, paymentRequest models.PaymentRequest, settleToSystem bool) (string, error) {
+	scatteredSettlementItems, err := p.calculateScatteredSettlementItems(ctx, customer, paymentRequest.Amount, settleToSystem)
 	if err != nil {
 		return "", err
 	}
@@ -380,6 +535,11 @@ func (p *PaymentFlowImpl) callAtipayGetToken(ctx context.Context, customer model
 		}
 	}
 
+	terminal, err := p.atipayTerminals.terminal(paymentRequest.AtipayTerminal)
+	if err != nil {
+		return "", err
+	}
+
 	// Prepare Atipay request payload
 	atipayPayload := map[string]any{
 		"amount":        amountWithTaxIRR,
@@ -387,8 +547,8 @@ func (p *PaymentFlowImpl) callAtipayGetToken(ctx context.Context, customer model
 		"description":   paymentRequest.Description,
 		"invoiceNumber": paymentRequest.InvoiceNumber,
 		"redirectUrl":   paymentRequest.RedirectURL,
-		"apiKey":        p.atipayCfg.APIKey,
-		"terminal":      p.atipayCfg.Terminal,
+		"apiKey":        terminal.APIKey,
+		"terminal":      terminal.Terminal,
 	}
 
 	systemUser, err := getSystemUser(ctx, p.customerRepo, p.walletRepo, p.sysCfg)
@@ -419,19 +579,22 @@ func (p *PaymentFlowImpl) callAtipayGetToken(ctx context.Context, customer model
 
 	httpReq.Header.Set("Content-Type", "application/json")
 
-	// Create HTTP client with timeout
-	client := &http.Client{
-		Timeout: 5 * time.Second,
-	}
+	client := httpclient.New(atipayClientName, 5*time.Second)
 
 	// Make HTTP request
 	resp, err := client.Do(httpReq)
 	if err != nil {
+		if errors.Is(err, httpclient.ErrCircuitOpen) || errors.Is(err, context.DeadlineExceeded) {
+			return "", fmt.Errorf("%w: %v", ErrPaymentGatewayUnavailable, err)
+		}
 		return "", err
 	}
 	defer resp.Body.Close()
 
 	// Check HTTP status code
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return "", fmt.Errorf("%w: atipay API returned status %d", ErrPaymentGatewayUnavailable, resp.StatusCode)
+	}
 	if resp.StatusCode != http.StatusOK {
 		return "", fmt.Errorf("atipay API returned non-OK status: %d", resp.StatusCode)
 	}
@@ -488,7 +651,7 @@ func (p *PaymentFlowImpl) PaymentCallback(ctx context.Context, atipayRequest *dt
 	var mapping PaymentStatusMapping
 
 	// Process callback within transaction
-	err := repository.WithTransaction(ctx, p.db, func(txCtx context.Context) error {
+	err := withVersionRetry(ctx, p.db, func(txCtx context.Context) error {
 		var err error
 
 		// Find the payment request by reservation number (our invoice number)
@@ -525,12 +688,14 @@ func (p *PaymentFlowImpl) PaymentCallback(ctx context.Context, atipayRequest *dt
 		// If payment was successful, increase customer balance
 		if mapping.Success {
 			// Verify payment with Atipay before finalizing
-			verificationResult, err := p.verifyPaymentWithAtipay(txCtx, atipayRequest.ReferenceNumber)
+			// Payments are verified with the API key of the terminal the
+			// request was sent to; the callback's terminalId is not trusted
+			verificationResult, err := p.verifyPaymentWithAtipay(txCtx, paymentRequest.AtipayTerminal, atipayRequest.ReferenceNumber)
 			if err != nil {
 				// Failed but don't return error to avoid rollback
 				mapping.Status = models.PaymentRequestStatusFailed
 				mapping.Success = false
-				mapping.Message = "Payment verification failed (step 1)"
+				mapping.MessageKey = "payment.message_verification_failed"
 				mapping.Description = "Payment verification failed (step 1): " + err.Error()
 
 				// Update payment request status
@@ -550,7 +715,7 @@ func (p *PaymentFlowImpl) PaymentCallback(ctx context.Context, atipayRequest *dt
 				// Amount mismatch - mark payment as failed and refund will occur
 				mapping.Status = models.PaymentRequestStatusFailed
 				mapping.Success = false
-				mapping.Message = "Payment verification failed (step 2): amount mismatch"
+				mapping.MessageKey = "payment.message_amount_mismatch"
 				mapping.Description = fmt.Sprintf("Verified amount (%f Rials) does not match original amount (%d Rials)",
 					verificationResult.AmountIRR, paymentRequest.Amount*10)
 
@@ -571,7 +736,7 @@ func (p *PaymentFlowImpl) PaymentCallback(ctx context.Context, atipayRequest *dt
 				// Failed but don't return error to avoid rollback
 				mapping.Status = models.PaymentRequestStatusFailed
 				mapping.Success = false
-				mapping.Message = "Increase customer balance failed (step 3)"
+				mapping.MessageKey = "payment.message_balance_update_failed"
 				mapping.Description = "Increase customer balance failed (step 3): " + err.Error()
 
 				// Update payment request status
@@ -609,7 +774,7 @@ func (p *PaymentFlowImpl) PaymentCallback(ctx context.Context, atipayRequest *dt
 	_ = createAuditLog(ctx, p.auditRepo, &customer, models.AuditActionPaymentCallbackProcessed, msg, true, nil, metadata)
 
 	// Generate HTML response based on payment status
-	htmlResponse, err := p.generatePaymentResultHTML(ctx, paymentRequest, atipayRequest, mapping)
+	htmlResponse, err := p.generatePaymentResultHTML(ctx, paymentRequest, &customer, atipayRequest, mapping)
 	if err != nil {
 		return "", NewBusinessError("PAYMENT_CALLBACK_HTML_GENERATION_FAILED", "Failed to generate HTML response", err)
 	}
@@ -639,9 +804,11 @@ func (p *PaymentFlowImpl) validateCallbackRequest(callback *dto.AtipayRequest) e
 
 // PaymentStatusMapping maps Atipay status codes to our payment statuses
 type PaymentStatusMapping struct {
-	Status      models.PaymentRequestStatus
-	Success     bool
-	Message     string
+	Status  models.PaymentRequestStatus
+	Success bool
+	// MessageKey is the i18n key of the message shown on the payment result page
+	MessageKey  string
+	MessageArgs i18n.Args
 	Description string
 }
 
@@ -650,55 +817,55 @@ var atipayStatusMappings = map[string]PaymentStatusMapping{
 	"2_OK": {
 		Status:      models.PaymentRequestStatusCompleted,
 		Success:     true,
-		Message:     "Payment completed successfully",
+		MessageKey:  "payment.message_completed",
 		Description: "Payment completed successfully via Atipay",
 	},
 	"1_CanceledByUser": {
 		Status:      models.PaymentRequestStatusCancelled,
 		Success:     false,
-		Message:     "Payment cancelled by customer",
+		MessageKey:  "payment.message_cancelled",
 		Description: "Payment cancelled by user via Atipay",
 	},
 	"3_Failed": {
 		Status:      models.PaymentRequestStatusFailed,
 		Success:     false,
-		Message:     "Payment failed",
+		MessageKey:  "payment.message_failed",
 		Description: "Payment failed via Atipay",
 	},
 	"4_SessionIsNull": {
 		Status:      models.PaymentRequestStatusExpired,
 		Success:     false,
-		Message:     "Payment session expired",
+		MessageKey:  "payment.message_session_expired",
 		Description: "Payment session expired via Atipay",
 	},
 	"5_InvalidParameters": {
 		Status:      models.PaymentRequestStatusFailed,
 		Success:     false,
-		Message:     "Invalid payment parameters",
+		MessageKey:  "payment.message_invalid_parameters",
 		Description: "Invalid payment parameters via Atipay",
 	},
 	"8_MerchantIpAddressIsInvalid": {
 		Status:      models.PaymentRequestStatusFailed,
 		Success:     false,
-		Message:     "Merchant IP address invalid",
+		MessageKey:  "payment.message_merchant_ip_invalid",
 		Description: "Merchant IP address invalid via Atipay",
 	},
 	"10_TokenNotFound": {
 		Status:      models.PaymentRequestStatusFailed,
 		Success:     false,
-		Message:     "Payment token not found",
+		MessageKey:  "payment.message_token_not_found",
 		Description: "Payment token not found via Atipay",
 	},
 	"11_TokenRequired": {
 		Status:      models.PaymentRequestStatusFailed,
 		Success:     false,
-		Message:     "Payment token required",
+		MessageKey:  "payment.message_token_required",
 		Description: "Payment token required via Atipay",
 	},
 	"12_TerminalNotFound": {
 		Status:      models.PaymentRequestStatusFailed,
 		Success:     false,
-		Message:     "Payment terminal not found",
+		MessageKey:  "payment.message_terminal_not_found",
 		Description: "Payment terminal not found via Atipay",
 	},
 }
@@ -720,7 +887,8 @@ func (p *PaymentFlowImpl) getPaymentStatusMapping(status, state string) PaymentS
 	return PaymentStatusMapping{
 		Status:      models.PaymentRequestStatusFailed,
 		Success:     false,
-		Message:     fmt.Sprintf("Unknown payment status: %s, state: %s", status, state),
+		MessageKey:  "payment.message_unknown_status",
+		MessageArgs: i18n.Args{"Status": status, "State": state},
 		Description: fmt.Sprintf("Unknown payment status: %s, state: %s via Atipay", status, state),
 	}
 }
@@ -752,7 +920,7 @@ func (p *PaymentFlowImpl) updatePaymentRequest(ctx context.Context, paymentReque
 }
 
 func (p *PaymentFlowImpl) updateBalances(ctx context.Context, paymentRequest *models.PaymentRequest, atipayRequest *dto.AtipayRequest) error {
-	_, err := getCustomer(ctx, p.customerRepo, paymentRequest.CustomerID)
+	customer, err := getCustomer(ctx, p.customerRepo, paymentRequest.CustomerID)
 	if err != nil {
 		return err
 	}
@@ -769,6 +937,11 @@ func (p *PaymentFlowImpl) updateBalances(ctx context.Context, paymentRequest *mo
 	agencyDiscountID := uint(m["agency_discount_id"].(float64))
 	agencyID := uint(m["agency_id"].(float64))
 
+	customerWallet, err := getWallet(ctx, p.walletRepo, paymentRequest.CustomerID)
+	if err != nil {
+		return err
+	}
+
 	agencyWallet, err := getWallet(ctx, p.walletRepo, agencyID)
 	if err != nil {
 		return err
@@ -785,6 +958,12 @@ func (p *PaymentFlowImpl) updateBalances(ctx context.Context, paymentRequest *mo
 		return err
 	}
 
+	// Serialize with other balance updates of the four wallets, so no
+	// concurrent callback credits from the same snapshots
+	if err := lockWalletBalances(ctx, p.walletRepo, customerWallet.ID, agencyWallet.ID, taxWallet.ID, systemWallet.ID); err != nil {
+		return err
+	}
+
 	// Get current balance snapshot for customer wallet
 	customerBalance, err := getLatestBalanceSnapshot(ctx, p.walletRepo, paymentRequest.WalletID)
 	if err != nil {
@@ -816,18 +995,21 @@ func (p *PaymentFlowImpl) updateBalances(ctx context.Context, paymentRequest *mo
 		return ErrAgencyDiscountNotFound
 	}
 
-	real := uint64(realWithTax * 10 / 11)
-	tax := realWithTax - real
-	realSystemShare := uint64(systemShareWithTax * 10 / 11)
-	taxSystemShare := systemShareWithTax - realSystemShare
-	realAgencyShare := uint64(agencyShareWithTax * 10 / 11)
-	taxAgencyShare := agencyShareWithTax - realAgencyShare
-	customerCredit := uint64(float64(real)/(1-agencyDiscount.DiscountRate)) - real
+	taxRate := p.sysCfg.TaxRateAt(paymentRequest.CreatedAt)
+	total := pricing.SplitGross(realWithTax, taxRate)
+	systemShare := pricing.SplitGross(systemShareWithTax, taxRate)
+	agencyShare := pricing.SplitGross(agencyShareWithTax, taxRate)
+	real, tax := total.Net, total.Tax
+	realSystemShare, taxSystemShare := systemShare.Net, systemShare.Tax
+	realAgencyShare, taxAgencyShare := agencyShare.Net, agencyShare.Tax
+	discountRate := chargeDiscountRate(m, agencyDiscount)
+	customerCredit := pricing.CustomerCredit(real, discountRate)
 
 	metadata := map[string]any{
 		"customer_id":           paymentRequest.CustomerID,
 		"agency_id":             agencyID,
 		"agency_discount_id":    agencyDiscountID,
+		"agency_discount_rate":  discountRate,
 		"source":                "payment_callback",
 		"operation":             "increase_balance",
 		"payment_request_id":    paymentRequest.ID,
@@ -854,6 +1036,10 @@ func (p *PaymentFlowImpl) updateBalances(ctx context.Context, paymentRequest *mo
 	if customerInvoiceUUID, ok := m["customer_invoice_uuid"]; ok {
 		metadata["customer_invoice_uuid"] = customerInvoiceUUID
 	}
+	fallback, _ := m["settlement_fallback"].(string)
+	if fallback != "" {
+		metadata["settlement_fallback"] = fallback
+	}
 
 	// Update customer wallet balance
 	newCustomerFreeBalance := customerBalance.FreeBalance + real
@@ -880,6 +1066,9 @@ func (p *PaymentFlowImpl) updateBalances(ctx context.Context, paymentRequest *mo
 		Description:        fmt.Sprintf("Wallet recharged via Atipay (payment request %d)", paymentRequest.ID),
 		Metadata:           metadataJSON,
 	}
+	if err := p.walletRepo.AdvanceVersion(ctx, &customerWallet); err != nil {
+		return err
+	}
 	if err := p.balanceSnapshotRepo.Save(ctx, newCustomerBS); err != nil {
 		return err
 	}
@@ -914,6 +1103,26 @@ func (p *PaymentFlowImpl) updateBalances(ctx context.Context, paymentRequest *mo
 	if err := p.transactionRepo.Save(ctx, customerDepositTx); err != nil {
 		return err
 	}
+	channel := deriveDepositMethod(metadata)
+	reference := ""
+	if channel == "payment_gateway" {
+		// Admin charges and deposit receipts carry synthetic references that
+		// mean nothing to the customer
+		reference = atipayRequest.ReferenceNumber
+	}
+	if _, err := issueTaxInvoice(ctx, p.taxInvoiceRepo, p.invoiceCfg, taxInvoiceCharge{
+		Customer:       customer,
+		Deposit:        customerDepositTx,
+		PaymentRequest: paymentRequest,
+		AmountWithTax:  realWithTax,
+		Amount:         real,
+		Tax:            tax,
+		Rate:           taxRate,
+		Channel:        channel,
+		Reference:      reference,
+	}); err != nil {
+		return err
+	}
 
 	// Update agency wallet balance
 	newAgencyShareWithTax := agencyBalance.AgencyShareWithTax + agencyShareWithTax
@@ -939,6 +1148,9 @@ func (p *PaymentFlowImpl) updateBalances(ctx context.Context, paymentRequest *mo
 		Description:        fmt.Sprintf("Agency share for payment request %d", paymentRequest.ID),
 		Metadata:           metadataJSON,
 	}
+	if err := p.walletRepo.AdvanceVersion(ctx, &agencyWallet); err != nil {
+		return err
+	}
 	if err := p.balanceSnapshotRepo.Save(ctx, newAgencyBS); err != nil {
 		return err
 	}
@@ -973,6 +1185,11 @@ func (p *PaymentFlowImpl) updateBalances(ctx context.Context, paymentRequest *mo
 	if err := p.transactionRepo.Save(ctx, agencyChargeTx); err != nil {
 		return err
 	}
+	if fallback != "" {
+		if err := p.recordAgencySharePayable(ctx, paymentRequest, agencyID, agencyShareWithTax, models.AgencySettlementFallbackReason(fallback)); err != nil {
+			return err
+		}
+	}
 
 	// Update tax wallet balance
 	newTaxLockedBalance := taxBalance.LockedBalance + taxSystemShare
@@ -998,6 +1215,9 @@ func (p *PaymentFlowImpl) updateBalances(ctx context.Context, paymentRequest *mo
 		Description:        fmt.Sprintf("Tax collection for payment request %d", paymentRequest.ID),
 		Metadata:           metadataJSON,
 	}
+	if err := p.walletRepo.AdvanceVersion(ctx, &taxWallet); err != nil {
+		return err
+	}
 	if err := p.balanceSnapshotRepo.Save(ctx, newTaxBS); err != nil {
 		return err
 	}
@@ -1057,6 +1277,9 @@ func (p *PaymentFlowImpl) updateBalances(ctx context.Context, paymentRequest *mo
 		Description:        fmt.Sprintf("System share for payment request %d", paymentRequest.ID),
 		Metadata:           metadataJSON,
 	}
+	if err := p.walletRepo.AdvanceVersion(ctx, &systemWallet); err != nil {
+		return err
+	}
 	if err := p.balanceSnapshotRepo.Save(ctx, newSystemBalanceSnapshot); err != nil {
 		return err
 	}
@@ -1092,40 +1315,22 @@ func (p *PaymentFlowImpl) updateBalances(ctx context.Context, paymentRequest *mo
 		return err
 	}
 
+	notifyInbox(ctx, p.notificationRepo, customer.ID, models.NotificationKindPaymentCredited, i18n.Args{"Amount": real + customerCredit})
 	return nil
 }
 
 // generatePaymentResultHTML generates HTML response based on payment status
+// in the language the payment was started in, or the customer's locale
 func (p *PaymentFlowImpl) generatePaymentResultHTML(
 	ctx context.Context,
 	paymentRequest *models.PaymentRequest,
+	customer *models.Customer,
 	atipayRequest *dto.AtipayRequest,
 	mapping PaymentStatusMapping,
 ) (string, error) {
-	// Read template files
-	var templateContent string
-	var err error
-	lang := strings.ToUpper(strings.TrimSpace(paymentRequest.Lang))
-	if lang == "" {
-		lang = "EN"
-	}
-
-	if mapping.Success {
-		filename := "templates/payment_success.html"
-		if lang == "FA" {
-			filename = "templates/payment_success_fa.html"
-		}
-		templateContent, err = p.readTemplate(filename)
-	} else {
-		filename := "templates/payment_failure.html"
-		if lang == "FA" {
-			filename = "templates/payment_failure_fa.html"
-		}
-		templateContent, err = p.readTemplate(filename)
-	}
-
-	if err != nil {
-		return "", err
+	locale, ok := i18n.ParseLocale(paymentRequest.Lang)
+	if !ok {
+		locale = p.localizer.CustomerLocale(customer)
 	}
 
 	var m map[string]any
@@ -1144,16 +1349,19 @@ func (p *PaymentFlowImpl) generatePaymentResultHTML(
 		return "", ErrAgencyDiscountNotFound
 	}
 
-	real := uint64(realWithTax * 10 / 11)
-	tax := realWithTax - real
-	customerCredit := uint64(float64(real)/(1-agencyDiscount.DiscountRate)) - real
+	taxRate := p.sysCfg.TaxRateAt(paymentRequest.CreatedAt)
+	split := pricing.SplitGross(realWithTax, taxRate)
+	real, tax := split.Net, split.Tax
+	customerCredit := pricing.CustomerCredit(real, chargeDiscountRate(m, agencyDiscount))
 
 	// Prepare template data
 	data := map[string]any{
-		"Status":          mapping.Status,
-		"Message":         mapping.Message,
+		"Success":         mapping.Success,
+		"Status":          i18n.Message(locale, "payment.status_"+string(mapping.Status), nil),
+		"Message":         i18n.Message(locale, mapping.MessageKey, mapping.MessageArgs),
 		"TotalAmount":     realWithTax,
 		"TaxAmount":       tax,
+		"TaxPercent":      taxRate.Percent(),
 		"NetAmount":       real,
 		"CreditAmount":    customerCredit,
 		"ReferenceNumber": atipayRequest.ReferenceNumber,
@@ -1163,25 +1371,7 @@ func (p *PaymentFlowImpl) generatePaymentResultHTML(
 		"ProcessedAt":     utils.UTCNow().Format("2006-01-02 15:04:05"),
 	}
 
-	// Simple template replacement (you could use a proper template engine like html/template)
-	html := templateContent
-	for key, value := range data {
-		if value != nil {
-			placeholder := "{{." + key + "}}"
-			html = strings.ReplaceAll(html, placeholder, fmt.Sprintf("%v", value))
-		}
-	}
-
-	return html, nil
-}
-
-// readTemplate reads a template file from the filesystem
-func (p *PaymentFlowImpl) readTemplate(filename string) (string, error) {
-	content, err := os.ReadFile(filename)
-	if err != nil {
-		return "", err
-	}
-	return string(content), nil
+	return templates.PaymentResult.Render(locale, data)
 }
 
 // AtipayVerificationResponse represents the response from Atipay's verify-payment API
@@ -1189,12 +1379,18 @@ type AtipayVerificationResponse struct {
 	AmountIRR float64 `json:"amount"`
 }
 
-// verifyPaymentWithAtipay calls Atipay's verify-payment API to finalize the transaction
-func (p *PaymentFlowImpl) verifyPaymentWithAtipay(ctx context.Context, referenceNumber string) (*AtipayVerificationResponse, error) {
+// verifyPaymentWithAtipay calls Atipay's verify-payment API of the terminal
+// the payment was made on to finalize the transaction
+func (p *PaymentFlowImpl) verifyPaymentWithAtipay(ctx context.Context, terminalID, referenceNumber string) (*AtipayVerificationResponse, error) {
+	terminal, err := p.atipayTerminals.terminal(terminalID)
+	if err != nil {
+		return nil, err
+	}
+
 	// Prepare Atipay verification request payload
 	verificationPayload := map[string]any{
 		"referenceNumber": referenceNumber,
-		"apiKey":          p.atipayCfg.APIKey,
+		"apiKey":          terminal.APIKey,
 	}
 
 	// Convert to JSON
@@ -1211,10 +1407,7 @@ func (p *PaymentFlowImpl) verifyPaymentWithAtipay(ctx context.Context, reference
 
 	httpReq.Header.Set("Content-Type", "application/json")
 
-	// Create HTTP client with timeout
-	client := &http.Client{
-		Timeout: 5 * time.Second,
-	}
+	client := httpclient.New(atipayClientName, 5*time.Second)
 
 	// Make HTTP request
 	resp, err := client.Do(httpReq)
@@ -1415,6 +1608,7 @@ func (p *PaymentFlowImpl) convertTransactionToTransactionHistoryItem(transaction
 		// Operation:          operation,
 		// Source:             source,
 		DepositMethod: depositMethod,
+		IsSandbox:     transaction.IsSandbox,
 		DateTime:      transaction.CreatedAt,
 		// ExternalRef:         externalRef,
 		// BalanceBefore:       balanceBefore,
@@ -1659,13 +1853,30 @@ func (p *PaymentFlowImpl) SubmitDepositReceipt(ctx context.Context, req *dto.Sub
 	if req.FileSize > 0 {
 		ct = strings.ToLower(strings.TrimSpace(req.ContentType))
 	}
+	transferDate, err := parseDepositTransferDate(req.TransferDate, utils.UTCNow())
+	if err != nil {
+		return nil, err
+	}
+	trackingNumber := normalizeTrackingNumber(req.TrackingNumber)
+	if len(trackingNumber) < 4 {
+		return nil, ErrDepositReceiptTrackingNumberInvalid
+	}
 
 	var receiptUUID string
-	err := repository.WithTransaction(ctx, p.db, func(txCtx context.Context) error {
+	err = repository.WithTransaction(ctx, p.db, func(txCtx context.Context) error {
 		customer, err := getCustomer(txCtx, p.customerRepo, req.CustomerID)
 		if err != nil {
 			return err
 		}
+		if customer.InSandbox() {
+			return ErrSandboxRealPayment
+		}
+		if err := checkCustomerPolicy(customer, CustomerActionRechargeWallet); err != nil {
+			return err
+		}
+		if err := p.ensureTrackingNumberUnused(txCtx, trackingNumber); err != nil {
+			return err
+		}
 		invoiceNumber := fmt.Sprintf("PRF-%s", uuid.New().String())
 		rec := &models.DepositReceipt{
 			CustomerID:    customer.ID,
@@ -1680,12 +1891,14 @@ func (p *PaymentFlowImpl) SubmitDepositReceipt(ctx context.Context, req *dto.Sub
 			InvoiceNumber: invoiceNumber,
 			StatusReason:  "Submitted by customer",
 		}
+		rec.TransferDate = &transferDate
+		rec.TrackingNumber = &trackingNumber
 		if err := p.depositReceiptRepo.Save(txCtx, rec); err != nil {
 			return err
 		}
 		receiptUUID = rec.UUID.String()
 
-		desc := fmt.Sprintf("Deposit receipt submitted amount %d by customer %d", req.Amount, req.CustomerID)
+		desc := fmt.Sprintf("Deposit receipt submitted amount %d by customer %d (tracking number %s)", req.Amount, req.CustomerID, trackingNumber)
 		_ = createAuditLog(txCtx, p.auditRepo, &customer, models.AuditActionDepositReceiptSubmitted, desc, true, nil, metadata)
 		return nil
 	})
@@ -1694,17 +1907,8 @@ func (p *PaymentFlowImpl) SubmitDepositReceipt(ctx context.Context, req *dto.Sub
 	}
 
 	// Notify deposit reviewers via SMS (best-effort).
-	if p.notifier != nil {
-		msg := strings.TrimSpace(p.messageCfg.DepositReceiptSubmittedTemplate)
-		if msg == "" {
-			msg = "A recharge has been completed in the Jazebeh platform. Please generate the related invoice through the admin panel and upload it."
-		}
-		go func() {
-			for _, mobile := range p.adminCfg.ActiveDepositReviewers() {
-				_ = p.notifier.SendSMS(context.Background(), mobile, msg, nil)
-			}
-		}()
-	}
+	msg := p.localizer.Admin("admin.deposit_receipt_submitted", nil)
+	enqueueSMS(ctx, p.jobs, p.adminCfg.ActiveDepositReviewers(), msg)
 
 	return &dto.SubmitDepositReceiptResponse{
 		Success:     true,
@@ -1714,6 +1918,46 @@ func (p *PaymentFlowImpl) SubmitDepositReceipt(ctx context.Context, req *dto.Sub
 	}, nil
 }
 
+// parseDepositTransferDate parses the day a bank transfer was made on, which
+// cannot be after today in Tehran
+func parseDepositTransferDate(value string, now time.Time) (time.Time, error) {
+	day, err := time.ParseInLocation(time.DateOnly, strings.TrimSpace(value), time.UTC)
+	if err != nil {
+		return time.Time{}, ErrDepositReceiptTransferDateInvalid
+	}
+	today := now.In(utils.TehranLocation())
+	if day.After(time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)) {
+		return time.Time{}, ErrDepositReceiptTransferDateInvalid
+	}
+	return day, nil
+}
+
+// normalizeTrackingNumber drops the spaces and dashes banks print tracking
+// numbers with, so one transfer is not submitted twice in two spellings
+func normalizeTrackingNumber(value string) string {
+	return strings.Map(func(r rune) rune {
+		if r == ' ' || r == '-' {
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(value))
+}
+
+// ensureTrackingNumberUnused refuses a tracking number that is already on a
+// receipt under review or approved; a rejected receipt frees it
+func (p *PaymentFlowImpl) ensureTrackingNumberUnused(ctx context.Context, trackingNumber string) error {
+	receipts, err := p.depositReceiptRepo.List(ctx, models.DepositReceiptFilter{TrackingNumber: &trackingNumber, OmitFileData: true}, 0, 0, "")
+	if err != nil {
+		return err
+	}
+	for _, r := range receipts {
+		if r.Status != models.DepositReceiptStatusRejected {
+			return ErrDepositReceiptTrackingNumberUsed
+		}
+	}
+	return nil
+}
+
 // ListDepositReceipts lists receipts for a customer.
 func (p *PaymentFlowImpl) ListDepositReceipts(ctx context.Context, customerID uint, lang string) (*dto.ListDepositReceiptsResponse, error) {
 	lang = strings.ToUpper(strings.TrimSpace(lang))
@@ -1747,10 +1991,23 @@ func (p *PaymentFlowImpl) ListDepositReceipts(ctx context.Context, customerID ui
 			PreviewType:   previewType,
 			CreatedAt:     r.CreatedAt,
 		})
+		setDepositReceiptTransfer(&resp.Items[len(resp.Items)-1], r)
 	}
 	return resp, nil
 }
 
+// setDepositReceiptTransfer fills in the reported transfer of a receipt and
+// the statement entry it was matched with
+func setDepositReceiptTransfer(item *dto.DepositReceiptItem, r *models.DepositReceipt) {
+	if r.TransferDate != nil {
+		item.TransferDate = utils.ToPtr(r.TransferDate.Format(time.DateOnly))
+	}
+	item.TrackingNumber = r.TrackingNumber
+	item.BankReference = r.BankReference
+	item.MatchedAmount = r.MatchedAmount
+	item.ReviewedAt = r.ReviewedAt
+}
+
 // PreviewProformaInvoice builds data for a proforma invoice JSON preview.
 func (p *PaymentFlowImpl) PreviewProformaInvoice(ctx context.Context, customerID uint, receiptUUID string, lang string) (*dto.ProformaPreviewResponse, error) {
 	lang = strings.ToUpper(strings.TrimSpace(lang))
@@ -1774,8 +2031,8 @@ func (p *PaymentFlowImpl) PreviewProformaInvoice(ctx context.Context, customerID
 	}
 	now := utils.UTCNow()
 	invoiceNumber := receipt.InvoiceNumber
-	real := uint64(float64(amountWithTax) * 10 / 11)
-	tax := amountWithTax - real
+	split := pricing.SplitGross(amountWithTax, p.sysCfg.TaxRateAt(receipt.CreatedAt))
+	real, tax := split.Net, split.Tax
 	serviceDesc := "Jazebeh wallet top-up"
 	notes := "This is a proforma invoice. Final invoice will be issued after payment confirmation."
 	sellerName := "Jazebeh Platform"
@@ -1845,8 +2102,8 @@ func (p *PaymentFlowImpl) PreviewProformaInvoiceByAmount(ctx context.Context, cu
 	}
 	now := utils.UTCNow()
 	invoiceNumber := "-"
-	real := uint64(float64(amountWithTax) * 10 / 11)
-	tax := amountWithTax - real
+	split := pricing.SplitGross(amountWithTax, p.sysCfg.TaxRateAt(now))
+	real, tax := split.Net, split.Tax
 	serviceDesc := "Jazebeh wallet top-up"
 	notes := "This is a proforma invoice. Final invoice will be issued after payment confirmation."
 	sellerName := "Jazebeh Platform"
@@ -2044,8 +2301,7 @@ func (p *PaymentFlowImpl) NotifyInvoiceIssueRequest(ctx context.Context, req *dt
 		companyName = strings.TrimSpace(*customer.CompanyName)
 	}
 
-	template := strings.TrimSpace(p.messageCfg.InvoiceIssueRequestTemplate)
-	msg := fmt.Sprintf(template, fullName, companyName)
+	msg := p.localizer.Admin("admin.invoice_issue_requested", i18n.Args{"Customer": fullName, "Company": companyName})
 	if smsErr := p.notifier.SendSMS(ctx, reviewerPhone, msg, utils.ToPtr(int64(customerID))); smsErr != nil {
 		_ = p.rc.Del(ctx, rlKey).Err()
 		return nil, smsErr
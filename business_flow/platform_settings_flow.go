@@ -2,12 +2,11 @@ package businessflow
 
 import (
 	"context"
-	"fmt"
 	"strings"
 	"time"
 
 	"github.com/amirphl/Yamata-no-Orochi/app/dto"
-	"github.com/amirphl/Yamata-no-Orochi/app/services"
+	"github.com/amirphl/Yamata-no-Orochi/app/i18n"
 	"github.com/amirphl/Yamata-no-Orochi/config"
 	"github.com/amirphl/Yamata-no-Orochi/models"
 	"github.com/amirphl/Yamata-no-Orochi/repository"
@@ -25,22 +24,25 @@ type PlatformSettingsFlow interface {
 type PlatformSettingsFlowImpl struct {
 	platformSettingsRepo repository.PlatformSettingsRepository
 	multimediaRepo       repository.MultimediaAssetRepository
-	notifier             services.NotificationService
+	jobs                 JobQueue
 	adminCfg             config.AdminConfig
+	localizer            *i18n.Localizer
 }
 
 // NewPlatformSettingsFlow creates a new platform settings flow.
 func NewPlatformSettingsFlow(
 	platformSettingsRepo repository.PlatformSettingsRepository,
 	multimediaRepo repository.MultimediaAssetRepository,
-	notifier services.NotificationService,
+	jobs JobQueue,
 	adminCfg config.AdminConfig,
+	localizer *i18n.Localizer,
 ) PlatformSettingsFlow {
 	return &PlatformSettingsFlowImpl{
 		platformSettingsRepo: platformSettingsRepo,
 		multimediaRepo:       multimediaRepo,
-		notifier:             notifier,
+		jobs:                 jobs,
 		adminCfg:             adminCfg,
+		localizer:            localizer,
 	}
 }
 
@@ -123,18 +125,12 @@ func (f *PlatformSettingsFlowImpl) CreatePlatformSettings(ctx context.Context, r
 	}
 
 	// Notify admins via SMS (best-effort).
-	if f.notifier != nil {
-		name := "-"
-		if row.Name != nil && strings.TrimSpace(*row.Name) != "" {
-			name = strings.TrimSpace(*row.Name)
-		}
-		msg := fmt.Sprintf("New platform settings created: platform=%s\n name=%s", row.Platform, name)
-		go func() {
-			for _, mobile := range f.adminCfg.ActiveMobiles() {
-				_ = f.notifier.SendSMS(context.Background(), mobile, msg, nil)
-			}
-		}()
+	name := "-"
+	if row.Name != nil && strings.TrimSpace(*row.Name) != "" {
+		name = strings.TrimSpace(*row.Name)
 	}
+	msg := f.localizer.Admin("admin.platform_settings_created", i18n.Args{"Platform": row.Platform, "Name": name})
+	enqueueSMS(ctx, f.jobs, f.adminCfg.ActiveMobiles(), msg)
 
 	return &dto.CreatePlatformSettingsResponse{
 		Message:             "Platform settings created successfully",
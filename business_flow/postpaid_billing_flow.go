@@ -0,0 +1,519 @@
+package businessflow
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/repository"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PostpaidBillingFlow manages credit lines of enterprise customers who fund
+// campaigns on credit, and the monthly invoices of what they drew
+type PostpaidBillingFlow interface {
+	GetCreditLine(ctx context.Context, customerID uint) (*dto.AdminCustomerCreditLineResponse, error)
+	SetCreditLimit(ctx context.Context, req *dto.AdminSetCustomerCreditLimitRequest) (*dto.AdminCustomerCreditLineResponse, error)
+	ListInvoices(ctx context.Context, filter dto.AdminListPostpaidInvoicesFilter) (*dto.AdminListPostpaidInvoicesResponse, error)
+	MarkInvoicePaid(ctx context.Context, id uuid.UUID, req *dto.AdminMarkPostpaidInvoicePaidRequest, adminID uint) (*dto.AdminPostpaidInvoiceResponse, error)
+	GetSummary(ctx context.Context, customerID uint) (*dto.GetPostpaidSummaryResponse, error)
+	IssueMonthlyInvoices(ctx context.Context, now time.Time) (*dto.PostpaidInvoicingSummary, error)
+}
+
+type PostpaidBillingFlowImpl struct {
+	db             *gorm.DB
+	customerRepo   repository.CustomerRepository
+	creditLineRepo repository.CustomerCreditLineRepository
+	drawRepo       repository.PostpaidDrawRepository
+	invoiceRepo    repository.PostpaidInvoiceRepository
+	auditRepo      repository.AuditLogRepository
+	invoiceDueDays int
+}
+
+func NewPostpaidBillingFlow(
+	db *gorm.DB,
+	customerRepo repository.CustomerRepository,
+	creditLineRepo repository.CustomerCreditLineRepository,
+	drawRepo repository.PostpaidDrawRepository,
+	invoiceRepo repository.PostpaidInvoiceRepository,
+	auditRepo repository.AuditLogRepository,
+	invoiceDueDays int,
+) PostpaidBillingFlow {
+	if invoiceDueDays <= 0 {
+		invoiceDueDays = 15
+	}
+	return &PostpaidBillingFlowImpl{
+		db:             db,
+		customerRepo:   customerRepo,
+		creditLineRepo: creditLineRepo,
+		drawRepo:       drawRepo,
+		invoiceRepo:    invoiceRepo,
+		auditRepo:      auditRepo,
+		invoiceDueDays: invoiceDueDays,
+	}
+}
+
+// GetCreditLine returns a customer's credit limit and what is owed on it
+func (f *PostpaidBillingFlowImpl) GetCreditLine(ctx context.Context, customerID uint) (*dto.AdminCustomerCreditLineResponse, error) {
+	if customerID == 0 {
+		return nil, NewBusinessError("VALIDATION_ERROR", "Invalid customer_id", nil)
+	}
+	customer, err := f.customerRepo.ByID(ctx, customerID)
+	if err != nil {
+		return nil, NewBusinessError("GET_CUSTOMER_CREDIT_LINE_FAILED", "Failed to get customer", err)
+	}
+	if customer == nil {
+		return nil, NewBusinessError("CUSTOMER_NOT_FOUND", "Customer not found", ErrCustomerNotFound)
+	}
+	summary, err := f.creditLineSummary(ctx, customerID)
+	if err != nil {
+		return nil, NewBusinessError("GET_CUSTOMER_CREDIT_LINE_FAILED", "Failed to get credit line", err)
+	}
+	return &dto.AdminCustomerCreditLineResponse{
+		Message:    "Customer credit line retrieved successfully",
+		CustomerID: customerID,
+		CreditLine: *summary,
+	}, nil
+}
+
+// SetCreditLimit replaces a customer's credit limit. Lowering it below what
+// is outstanding only stops further draws.
+func (f *PostpaidBillingFlowImpl) SetCreditLimit(ctx context.Context, req *dto.AdminSetCustomerCreditLimitRequest) (*dto.AdminCustomerCreditLineResponse, error) {
+	if req == nil || req.CustomerID == 0 {
+		return nil, NewBusinessError("VALIDATION_ERROR", "Invalid request", nil)
+	}
+	customer, err := f.customerRepo.ByID(ctx, req.CustomerID)
+	if err != nil {
+		return nil, NewBusinessError("SET_CUSTOMER_CREDIT_LIMIT_FAILED", "Failed to get customer", err)
+	}
+	if customer == nil {
+		return nil, NewBusinessError("CUSTOMER_NOT_FOUND", "Customer not found", ErrCustomerNotFound)
+	}
+
+	line := &models.CustomerCreditLine{
+		CustomerID:  req.CustomerID,
+		CreditLimit: req.CreditLimit,
+		UpdatedAt:   utils.UTCNow(),
+	}
+	if adminID, ok := adminIDFromContext(ctx); ok {
+		line.AdminID = &adminID
+	}
+	meta := map[string]any{"credit_limit": req.CreditLimit}
+	if prev, err := f.creditLineRepo.ByCustomerID(ctx, req.CustomerID); err == nil && prev != nil {
+		meta["previous_credit_limit"] = prev.CreditLimit
+		meta["outstanding"] = prev.Outstanding
+	}
+	if err := f.creditLineRepo.UpsertLimit(ctx, line); err != nil {
+		logAdminAction(ctx, f.auditRepo, models.AuditActionAdminCustomerCreditLimitUpdate, "Admin credit limit update failed", false, &req.CustomerID, meta, err)
+		return nil, NewBusinessError("SET_CUSTOMER_CREDIT_LIMIT_FAILED", "Failed to update credit limit", err)
+	}
+	logAdminAction(ctx, f.auditRepo, models.AuditActionAdminCustomerCreditLimitUpdate, "Admin updated customer credit limit", true, &req.CustomerID, meta, nil)
+
+	summary, err := f.creditLineSummary(ctx, req.CustomerID)
+	if err != nil {
+		return nil, NewBusinessError("GET_CUSTOMER_CREDIT_LINE_FAILED", "Failed to get credit line", err)
+	}
+	return &dto.AdminCustomerCreditLineResponse{
+		Message:    "Customer credit limit updated successfully",
+		CustomerID: req.CustomerID,
+		CreditLine: *summary,
+	}, nil
+}
+
+// ListInvoices returns postpaid invoices, newest first
+func (f *PostpaidBillingFlowImpl) ListInvoices(ctx context.Context, filter dto.AdminListPostpaidInvoicesFilter) (*dto.AdminListPostpaidInvoicesResponse, error) {
+	page := max(1, filter.Page)
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 10
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	offset := (page - 1) * limit
+
+	now := utils.UTCNow()
+	inf := models.PostpaidInvoiceFilter{CustomerID: filter.CustomerID}
+	if filter.Status != nil && *filter.Status != "" {
+		status := models.PostpaidInvoiceStatus(*filter.Status)
+		inf.Status = &status
+	}
+	if filter.OverdueOnly {
+		inf.Status = utils.ToPtr(models.PostpaidInvoiceStatusOpen)
+		inf.DueBefore = &now
+	}
+
+	total, err := f.invoiceRepo.Count(ctx, inf)
+	if err != nil {
+		return nil, NewBusinessError("POSTPAID_INVOICE_LIST_FAILED", "Failed to count postpaid invoices", err)
+	}
+	rows, err := f.invoiceRepo.ByFilter(ctx, inf, "id DESC", limit, offset)
+	if err != nil {
+		return nil, NewBusinessError("POSTPAID_INVOICE_LIST_FAILED", "Failed to list postpaid invoices", err)
+	}
+
+	items := make([]dto.PostpaidInvoiceItem, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, postpaidInvoiceItem(row, now))
+	}
+	return &dto.AdminListPostpaidInvoicesResponse{
+		Message: "Postpaid invoices retrieved successfully",
+		Items:   items,
+		Pagination: dto.PaginationInfo{
+			Total:      total,
+			Page:       page,
+			Limit:      limit,
+			TotalPages: int((total + int64(limit) - 1) / int64(limit)),
+		},
+	}, nil
+}
+
+// MarkInvoicePaid records that an invoice was settled outside the wallet,
+// e.g. by bank transfer, and deducts it from what the customer owes
+func (f *PostpaidBillingFlowImpl) MarkInvoicePaid(ctx context.Context, id uuid.UUID, req *dto.AdminMarkPostpaidInvoicePaidRequest, adminID uint) (*dto.AdminPostpaidInvoiceResponse, error) {
+	if req == nil {
+		return nil, NewBusinessError("VALIDATION_ERROR", "Request is required", nil)
+	}
+	meta := map[string]any{"invoice_uuid": id.String(), "payment_reference": strings.TrimSpace(req.PaymentReference)}
+
+	var inv *models.PostpaidInvoice
+	err := repository.WithTransaction(ctx, f.db, func(txCtx context.Context) error {
+		var err error
+		inv, err = f.invoiceRepo.ByUUID(txCtx, id)
+		if err != nil {
+			return err
+		}
+		if inv == nil {
+			return ErrPostpaidInvoiceNotFound
+		}
+		if inv.Status != models.PostpaidInvoiceStatusOpen {
+			return ErrPostpaidInvoiceAlreadyPaid
+		}
+
+		now := utils.UTCNow()
+		inv.PaidAt = &now
+		inv.PaidByAdminID = &adminID
+		inv.PaymentReference = strings.TrimSpace(req.PaymentReference)
+		inv.UpdatedAt = now
+		paid, err := f.invoiceRepo.MarkPaid(txCtx, inv)
+		if err != nil {
+			return err
+		}
+		if !paid {
+			return ErrPostpaidInvoiceAlreadyPaid
+		}
+		inv.Status = models.PostpaidInvoiceStatusPaid
+		return f.creditLineRepo.Settle(txCtx, inv.CustomerID, inv.Amount)
+	})
+
+	var customerID *uint
+	if inv != nil {
+		customerID = &inv.CustomerID
+		meta["amount"] = inv.Amount
+		meta["period_start"] = inv.PeriodStart
+	}
+	if err != nil {
+		logAdminAction(ctx, f.auditRepo, models.AuditActionAdminPostpaidInvoicePaid, "Marking postpaid invoice paid failed", false, customerID, meta, err)
+		switch {
+		case IsPostpaidInvoiceNotFound(err):
+			return nil, NewBusinessError("POSTPAID_INVOICE_NOT_FOUND", "Postpaid invoice not found", err)
+		case IsPostpaidInvoiceAlreadyPaid(err):
+			return nil, NewBusinessError("POSTPAID_INVOICE_ALREADY_PAID", "Postpaid invoice was already paid", err)
+		}
+		return nil, NewBusinessError("POSTPAID_INVOICE_PAY_FAILED", "Failed to mark postpaid invoice paid", err)
+	}
+	logAdminAction(ctx, f.auditRepo, models.AuditActionAdminPostpaidInvoicePaid, "Admin marked postpaid invoice paid", true, customerID, meta, nil)
+
+	return &dto.AdminPostpaidInvoiceResponse{
+		Message: "Postpaid invoice marked paid",
+		Invoice: postpaidInvoiceItem(inv, utils.UTCNow()),
+	}, nil
+}
+
+// GetSummary returns the customer's own credit line and recent invoices
+func (f *PostpaidBillingFlowImpl) GetSummary(ctx context.Context, customerID uint) (*dto.GetPostpaidSummaryResponse, error) {
+	summary, err := f.creditLineSummary(ctx, customerID)
+	if err != nil {
+		return nil, NewBusinessError("GET_POSTPAID_SUMMARY_FAILED", "Failed to get credit line", err)
+	}
+	rows, err := f.invoiceRepo.ByFilter(ctx, models.PostpaidInvoiceFilter{CustomerID: &customerID}, "id DESC", 12, 0)
+	if err != nil {
+		return nil, NewBusinessError("GET_POSTPAID_SUMMARY_FAILED", "Failed to get postpaid invoices", err)
+	}
+	now := utils.UTCNow()
+	invoices := make([]dto.PostpaidInvoiceItem, 0, len(rows))
+	for _, row := range rows {
+		invoices = append(invoices, postpaidInvoiceItem(row, now))
+	}
+	return &dto.GetPostpaidSummaryResponse{
+		Message:    "Postpaid summary retrieved successfully",
+		CreditLine: *summary,
+		Invoices:   invoices,
+	}, nil
+}
+
+// IssueMonthlyInvoices bills the draws made before the Tehran month
+// containing now, one invoice per customer for the previous month. Draws
+// already invoiced are skipped, so running it again in the same month only
+// picks up what a failed run left behind.
+func (f *PostpaidBillingFlowImpl) IssueMonthlyInvoices(ctx context.Context, now time.Time) (*dto.PostpaidInvoicingSummary, error) {
+	periodStart, periodEnd := previousTehranMonth(now)
+	res := &dto.PostpaidInvoicingSummary{PeriodStart: periodStart.Format(time.RFC3339)}
+
+	totals, err := f.drawRepo.UninvoicedTotals(ctx, periodEnd)
+	if err != nil {
+		return nil, err
+	}
+	for _, total := range totals {
+		inv, err := f.issueInvoice(ctx, total, periodStart, periodEnd)
+		if err != nil {
+			res.Failed++
+			log.Printf("postpaid invoicing: customer %d: %v", total.CustomerID, err)
+			continue
+		}
+		if inv == nil {
+			continue
+		}
+		res.Issued++
+		res.Amount += inv.Amount
+	}
+	return res, nil
+}
+
+func (f *PostpaidBillingFlowImpl) issueInvoice(ctx context.Context, total models.PostpaidDrawTotal, periodStart, periodEnd time.Time) (*models.PostpaidInvoice, error) {
+	var inv *models.PostpaidInvoice
+	err := repository.WithTransaction(ctx, f.db, func(txCtx context.Context) error {
+		// One invoice per customer and period. Draws of the period committed
+		// after it was issued are reported instead of silently billed on it.
+		existing, err := f.invoiceRepo.ByFilter(txCtx, models.PostpaidInvoiceFilter{
+			CustomerID:  &total.CustomerID,
+			PeriodStart: &periodStart,
+		}, "id DESC", 1, 0)
+		if err != nil {
+			return err
+		}
+		if len(existing) > 0 {
+			return fmt.Errorf("invoice %s of period %s already issued", existing[0].UUID, periodStart.Format("2006-01"))
+		}
+
+		now := utils.UTCNow()
+		inv = &models.PostpaidInvoice{
+			UUID:        uuid.New(),
+			CustomerID:  total.CustomerID,
+			PeriodStart: periodStart,
+			PeriodEnd:   periodEnd,
+			Amount:      total.Amount,
+			NumDraws:    total.Draws,
+			Status:      models.PostpaidInvoiceStatusOpen,
+			DueAt:       periodEnd.AddDate(0, 0, f.invoiceDueDays),
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		}
+		if err := f.invoiceRepo.Save(txCtx, inv); err != nil {
+			return err
+		}
+		assigned, err := f.drawRepo.AssignInvoice(txCtx, total.CustomerID, periodEnd, inv.ID)
+		if err != nil {
+			return err
+		}
+		if assigned != total.Draws {
+			return fmt.Errorf("expected %d draws to invoice, found %d", total.Draws, assigned)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	meta := map[string]any{
+		"invoice_uuid": inv.UUID.String(),
+		"period_start": inv.PeriodStart,
+		"amount":       inv.Amount,
+		"num_draws":    inv.NumDraws,
+		"due_at":       inv.DueAt,
+	}
+	logAdminAction(ctx, f.auditRepo, models.AuditActionPostpaidInvoiceIssued, "Postpaid invoice issued", true, &inv.CustomerID, meta, nil)
+	return inv, nil
+}
+
+func (f *PostpaidBillingFlowImpl) creditLineSummary(ctx context.Context, customerID uint) (*dto.CreditLineSummary, error) {
+	line, err := f.creditLineRepo.ByCustomerID(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+	now := utils.UTCNow()
+	open, err := f.invoiceRepo.ByFilter(ctx, models.PostpaidInvoiceFilter{
+		CustomerID: &customerID,
+		Status:     utils.ToPtr(models.PostpaidInvoiceStatusOpen),
+	}, "due_at ASC", 0, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &dto.CreditLineSummary{OpenInvoices: int64(len(open))}
+	if line != nil {
+		summary.CreditLimit = line.CreditLimit
+		summary.Outstanding = line.Outstanding
+		summary.Available = line.Available()
+		summary.UpdatedAt = utils.ToPtr(line.UpdatedAt.Format(time.RFC3339))
+	}
+	if len(open) > 0 {
+		summary.NextDueAt = utils.ToPtr(open[0].DueAt.Format(time.RFC3339))
+		summary.Overdue = open[0].IsOverdue(now)
+	}
+	return summary, nil
+}
+
+// checkPostpaidStanding rejects new campaigns of customers with a postpaid
+// invoice open past its due date
+func checkPostpaidStanding(ctx context.Context, invoiceRepo repository.PostpaidInvoiceRepository, customerID uint) error {
+	overdue, err := invoiceRepo.Exists(ctx, models.PostpaidInvoiceFilter{
+		CustomerID: &customerID,
+		Status:     utils.ToPtr(models.PostpaidInvoiceStatusOpen),
+		DueBefore:  utils.ToPtr(utils.UTCNow()),
+	})
+	if err != nil {
+		return NewBusinessError("POSTPAID_STANDING_CHECK_FAILED", "Failed to check postpaid invoices", err)
+	}
+	if overdue {
+		return NewBusinessError("POSTPAID_INVOICE_OVERDUE", "Pay the overdue postpaid invoice before creating new campaigns", ErrPostpaidInvoiceOverdue)
+	}
+	return nil
+}
+
+// drawPostpaidCredit funds amount from the customer's credit line into the
+// wallet's credit balance and returns the resulting snapshot. It fails with
+// ErrInsufficientFunds when the customer has no credit line or the draw would
+// exceed its limit. Budget later refunded to the wallet stays drawn; it is
+// billed with the rest of the month's draws.
+func drawPostpaidCredit(
+	ctx context.Context,
+	creditLineRepo repository.CustomerCreditLineRepository,
+	drawRepo repository.PostpaidDrawRepository,
+	walletRepo repository.WalletRepository,
+	balanceSnapshotRepo repository.BalanceSnapshotRepository,
+	transactionRepo repository.TransactionRepository,
+	wallet *models.Wallet,
+	latest models.BalanceSnapshot,
+	amount uint64,
+	campaignID uint,
+) (models.BalanceSnapshot, error) {
+	drawn, err := creditLineRepo.Draw(ctx, wallet.CustomerID, amount)
+	if err != nil {
+		return models.BalanceSnapshot{}, err
+	}
+	if !drawn {
+		return models.BalanceSnapshot{}, ErrInsufficientFunds
+	}
+
+	metaBytes, err := json.Marshal(map[string]any{
+		"source":      "postpaid_credit_line",
+		"operation":   "draw",
+		"campaign_id": campaignID,
+		"amount":      amount,
+		"currency":    utils.TomanCurrency,
+	})
+	if err != nil {
+		return models.BalanceSnapshot{}, err
+	}
+	corrID := uuid.New()
+	description := fmt.Sprintf("Credit line draw for campaign %d", campaignID)
+
+	newCredit := latest.CreditBalance + amount
+	newSnap := &models.BalanceSnapshot{
+		UUID:               uuid.New(),
+		CorrelationID:      corrID,
+		WalletID:           wallet.ID,
+		CustomerID:         wallet.CustomerID,
+		FreeBalance:        latest.FreeBalance,
+		FrozenBalance:      latest.FrozenBalance,
+		LockedBalance:      latest.LockedBalance,
+		CreditBalance:      newCredit,
+		SpentOnCampaign:    latest.SpentOnCampaign,
+		AgencyShareWithTax: latest.AgencyShareWithTax,
+		TotalBalance:       latest.FreeBalance + latest.FrozenBalance + latest.LockedBalance + newCredit + latest.SpentOnCampaign + latest.AgencyShareWithTax,
+		Reason:             "postpaid_credit_draw",
+		Description:        description,
+		Metadata:           metaBytes,
+		CreatedAt:          utils.UTCNow(),
+		UpdatedAt:          utils.UTCNow(),
+	}
+	if err := walletRepo.AdvanceVersion(ctx, wallet); err != nil {
+		return models.BalanceSnapshot{}, err
+	}
+	if err := balanceSnapshotRepo.Save(ctx, newSnap); err != nil {
+		return models.BalanceSnapshot{}, err
+	}
+
+	beforeMap, err := latest.GetBalanceMap()
+	if err != nil {
+		return models.BalanceSnapshot{}, err
+	}
+	afterMap, err := newSnap.GetBalanceMap()
+	if err != nil {
+		return models.BalanceSnapshot{}, err
+	}
+	tx := &models.Transaction{
+		UUID:          uuid.New(),
+		CorrelationID: corrID,
+		Type:          models.TransactionTypeCredit,
+		Status:        models.TransactionStatusCompleted,
+		Amount:        amount,
+		Currency:      utils.TomanCurrency,
+		WalletID:      wallet.ID,
+		CustomerID:    wallet.CustomerID,
+		BalanceBefore: beforeMap,
+		BalanceAfter:  afterMap,
+		Description:   description,
+		Metadata:      metaBytes,
+	}
+	if err := transactionRepo.Save(ctx, tx); err != nil {
+		return models.BalanceSnapshot{}, err
+	}
+
+	draw := &models.PostpaidDraw{
+		CustomerID:    wallet.CustomerID,
+		CampaignID:    &campaignID,
+		Amount:        amount,
+		TransactionID: tx.ID,
+		CreatedAt:     utils.UTCNow(),
+	}
+	if err := drawRepo.Save(ctx, draw); err != nil {
+		return models.BalanceSnapshot{}, err
+	}
+	return *newSnap, nil
+}
+
+// previousTehranMonth returns the [start, end) range of the Tehran calendar
+// month before the one containing now
+func previousTehranMonth(now time.Time) (time.Time, time.Time) {
+	currentStart, _ := utils.TehranMonthBounds(now)
+	return utils.TehranMonthBounds(currentStart.AddDate(0, 0, -1))
+}
+
+func postpaidInvoiceItem(inv *models.PostpaidInvoice, now time.Time) dto.PostpaidInvoiceItem {
+	item := dto.PostpaidInvoiceItem{
+		UUID:             inv.UUID.String(),
+		CustomerID:       inv.CustomerID,
+		PeriodStart:      inv.PeriodStart.Format(time.RFC3339),
+		PeriodEnd:        inv.PeriodEnd.Format(time.RFC3339),
+		Amount:           inv.Amount,
+		NumDraws:         inv.NumDraws,
+		Status:           string(inv.Status),
+		Overdue:          inv.IsOverdue(now),
+		DueAt:            inv.DueAt.Format(time.RFC3339),
+		PaymentReference: inv.PaymentReference,
+		CreatedAt:        inv.CreatedAt.Format(time.RFC3339),
+	}
+	if inv.PaidAt != nil {
+		item.PaidAt = utils.ToPtr(inv.PaidAt.Format(time.RFC3339))
+	}
+	return item
+}
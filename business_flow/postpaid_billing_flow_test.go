@@ -0,0 +1,69 @@
+package businessflow
+
+import (
+	"testing"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+)
+
+func TestPreviousTehranMonth(t *testing.T) {
+	t.Parallel()
+
+	loc := utils.TehranLocation()
+	cases := []struct {
+		name  string
+		now   time.Time
+		start time.Time
+	}{
+		{name: "mid month", now: time.Date(2026, 10, 17, 12, 0, 0, 0, loc), start: time.Date(2026, 9, 1, 0, 0, 0, 0, loc)},
+		{name: "first minute of month", now: time.Date(2026, 10, 1, 0, 1, 0, 0, loc), start: time.Date(2026, 9, 1, 0, 0, 0, 0, loc)},
+		{name: "january", now: time.Date(2026, 1, 5, 0, 0, 0, 0, loc), start: time.Date(2025, 12, 1, 0, 0, 0, 0, loc)},
+		// 2026-09-30 21:00 UTC is already October in Tehran
+		{name: "utc still in previous month", now: time.Date(2026, 9, 30, 21, 0, 0, 0, time.UTC), start: time.Date(2026, 9, 1, 0, 0, 0, 0, loc)},
+	}
+	for _, tc := range cases {
+		start, end := previousTehranMonth(tc.now)
+		if !start.Equal(tc.start) || !end.Equal(tc.start.AddDate(0, 1, 0)) {
+			t.Fatalf("%s: got [%s, %s), want month starting %s", tc.name, start, end, tc.start)
+		}
+	}
+}
+
+func TestCustomerCreditLineAvailable(t *testing.T) {
+	t.Parallel()
+
+	if got := (&models.CustomerCreditLine{CreditLimit: 1000, Outstanding: 300}).Available(); got != 700 {
+		t.Fatalf("available = %d, want 700", got)
+	}
+	// The limit may be lowered below what is already outstanding
+	if got := (&models.CustomerCreditLine{CreditLimit: 100, Outstanding: 300}).Available(); got != 0 {
+		t.Fatalf("available = %d, want 0", got)
+	}
+	var none *models.CustomerCreditLine
+	if got := none.Available(); got != 0 {
+		t.Fatalf("available without a credit line = %d, want 0", got)
+	}
+}
+
+func TestPostpaidInvoiceItemOverdue(t *testing.T) {
+	t.Parallel()
+
+	due := time.Date(2026, 10, 15, 20, 30, 0, 0, time.UTC)
+	inv := &models.PostpaidInvoice{Status: models.PostpaidInvoiceStatusOpen, Amount: 500, DueAt: due}
+	if postpaidInvoiceItem(inv, due).Overdue {
+		t.Fatal("invoice overdue at its due date")
+	}
+	if !postpaidInvoiceItem(inv, due.Add(time.Second)).Overdue {
+		t.Fatal("open invoice past its due date not overdue")
+	}
+
+	paidAt := due.Add(time.Hour)
+	inv.Status = models.PostpaidInvoiceStatusPaid
+	inv.PaidAt = &paidAt
+	item := postpaidInvoiceItem(inv, due.Add(48*time.Hour))
+	if item.Overdue || item.PaidAt == nil || *item.PaidAt != paidAt.Format(time.RFC3339) {
+		t.Fatalf("paid invoice item = %+v", item)
+	}
+}
@@ -0,0 +1,369 @@
+package businessflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/repository"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/google/uuid"
+)
+
+// profileFieldValue is a requested value of a profile field; nil keeps the
+// field as it is
+type profileFieldValue struct {
+	field models.ProfileField
+	value *string
+}
+
+// UpdateCompanyInfo changes the company name, national ID and phone of a
+// company account
+func (f *ProfileFlowImpl) UpdateCompanyInfo(ctx context.Context, customerID uint, req *dto.UpdateCompanyInfoRequest, metadata *ClientMetadata) (*dto.UpdateProfileResponse, error) {
+	return f.changeProfile(ctx, customerID, true, []profileFieldValue{
+		{models.ProfileFieldCompanyName, req.CompanyName},
+		{models.ProfileFieldNationalID, req.NationalID},
+		{models.ProfileFieldCompanyPhone, req.CompanyPhone},
+	}, metadata)
+}
+
+// UpdateAddress changes the address and postal code
+func (f *ProfileFlowImpl) UpdateAddress(ctx context.Context, customerID uint, req *dto.UpdateAddressRequest, metadata *ClientMetadata) (*dto.UpdateProfileResponse, error) {
+	return f.changeProfile(ctx, customerID, false, []profileFieldValue{
+		{models.ProfileFieldCompanyAddress, req.CompanyAddress},
+		{models.ProfileFieldPostalCode, req.PostalCode},
+	}, metadata)
+}
+
+// UpdateSheba changes the Sheba number payouts go to
+func (f *ProfileFlowImpl) UpdateSheba(ctx context.Context, customerID uint, req *dto.UpdateShebaRequest, metadata *ClientMetadata) (*dto.UpdateProfileResponse, error) {
+	sheba, err := ValidateShebaNumber(&req.ShebaNumber)
+	if err != nil {
+		return nil, NewBusinessError("UPDATE_PROFILE_VALIDATION_FAILED", "Sheba number is invalid", err)
+	}
+	return f.changeProfile(ctx, customerID, false, []profileFieldValue{
+		{models.ProfileFieldShebaNumber, &sheba},
+	}, metadata)
+}
+
+// ListProfileChanges returns the customer's profile change history, newest first
+func (f *ProfileFlowImpl) ListProfileChanges(ctx context.Context, customerID uint, filter dto.ListProfileChangesFilter) (*dto.ListProfileChangesResponse, error) {
+	page, limit := walletTransferPage(filter.Page, filter.Limit)
+	cf := models.CustomerProfileChangeFilter{CustomerID: &customerID}
+	if filter.Status != nil && *filter.Status != "" {
+		status := models.ProfileChangeStatus(*filter.Status)
+		cf.Status = &status
+	}
+
+	total, err := f.changeRepo.Count(ctx, cf)
+	if err != nil {
+		return nil, NewBusinessError("LIST_PROFILE_CHANGES_FAILED", "Failed to count profile changes", err)
+	}
+	changes, err := f.changeRepo.ByFilter(ctx, cf, "id DESC", limit, (page-1)*limit)
+	if err != nil {
+		return nil, NewBusinessError("LIST_PROFILE_CHANGES_FAILED", "Failed to list profile changes", err)
+	}
+	items := make([]dto.ProfileChangeDTO, 0, len(changes))
+	for _, c := range changes {
+		items = append(items, profileChangeDTO(c))
+	}
+	return &dto.ListProfileChangesResponse{
+		Message:    "Profile changes retrieved successfully",
+		Items:      items,
+		Pagination: walletTransferPagination(total, page, limit),
+	}, nil
+}
+
+// AdminListProfileChanges returns profile changes by status, changes waiting
+// for review by default, longest waiting first
+func (f *ProfileFlowImpl) AdminListProfileChanges(ctx context.Context, filter dto.AdminListProfileChangesFilter) (*dto.AdminListProfileChangesResponse, error) {
+	page, limit := walletTransferPage(filter.Page, filter.Limit)
+	status := models.ProfileChangePendingReview
+	if filter.Status != nil && *filter.Status != "" {
+		status = models.ProfileChangeStatus(*filter.Status)
+	}
+	cf := models.CustomerProfileChangeFilter{Status: &status, CustomerID: filter.CustomerID}
+	orderBy := "id DESC"
+	if status == models.ProfileChangePendingReview {
+		orderBy = "id ASC"
+	}
+
+	total, err := f.changeRepo.Count(ctx, cf)
+	if err != nil {
+		return nil, NewBusinessError("ADMIN_LIST_PROFILE_CHANGES_FAILED", "Failed to count profile changes", err)
+	}
+	changes, err := f.changeRepo.ByFilter(ctx, cf, orderBy, limit, (page-1)*limit)
+	if err != nil {
+		return nil, NewBusinessError("ADMIN_LIST_PROFILE_CHANGES_FAILED", "Failed to list profile changes", err)
+	}
+
+	ids := make([]uint, 0, len(changes))
+	for _, c := range changes {
+		ids = append(ids, c.CustomerID)
+	}
+	customers, err := f.customerRepo.FindByIDs(ctx, ids)
+	if err != nil {
+		return nil, NewBusinessError("ADMIN_LIST_PROFILE_CHANGES_FAILED", "Failed to load customers", err)
+	}
+	byID := make(map[uint]*models.Customer, len(customers))
+	for _, c := range customers {
+		byID[c.ID] = c
+	}
+
+	items := make([]dto.AdminProfileChangeItem, 0, len(changes))
+	for _, c := range changes {
+		items = append(items, adminProfileChangeItem(c, byID[c.CustomerID]))
+	}
+	return &dto.AdminListProfileChangesResponse{
+		Message:    "Profile changes retrieved successfully",
+		Items:      items,
+		Pagination: walletTransferPagination(total, page, limit),
+	}, nil
+}
+
+// AdminReviewProfileChange applies or rejects a change of a sensitive field.
+// An approved change is written to the customer in the same transaction.
+func (f *ProfileFlowImpl) AdminReviewProfileChange(ctx context.Context, req *dto.AdminReviewProfileChangeRequest) (*dto.AdminReviewProfileChangeResponse, error) {
+	id, err := uuid.Parse(req.UUID)
+	if err != nil {
+		return nil, NewBusinessError("PROFILE_CHANGE_NOT_FOUND", "Profile change not found", ErrProfileChangeNotFound)
+	}
+	to := models.ProfileChangeApplied
+	var reason *string
+	if req.Decision == "reject" {
+		to = models.ProfileChangeRejected
+		if req.Reason == nil || strings.TrimSpace(*req.Reason) == "" {
+			return nil, NewBusinessError("PROFILE_CHANGE_REJECTION_REASON_REQUIRED", ErrProfileChangeRejectionReasonNeeded.Error(), ErrProfileChangeRejectionReasonNeeded)
+		}
+		reason = utils.ToPtr(strings.TrimSpace(*req.Reason))
+	}
+
+	change, err := f.changeRepo.ByUUID(ctx, id)
+	if err != nil {
+		return nil, NewBusinessError("ADMIN_REVIEW_PROFILE_CHANGE_FAILED", "Failed to find profile change", err)
+	}
+	if change == nil {
+		return nil, NewBusinessError("PROFILE_CHANGE_NOT_FOUND", "Profile change not found", ErrProfileChangeNotFound)
+	}
+	customer, err := f.customerRepo.ByID(ctx, change.CustomerID)
+	if err != nil {
+		return nil, NewBusinessError("ADMIN_REVIEW_PROFILE_CHANGE_FAILED", "Failed to find customer", err)
+	}
+	if customer == nil {
+		return nil, NewBusinessError("CUSTOMER_NOT_FOUND", "Customer not found", ErrCustomerNotFound)
+	}
+
+	meta := map[string]any{"decision": req.Decision, "field": change.Field, "change_uuid": change.UUID.String(), "reason": reason}
+	if change.Status != models.ProfileChangePendingReview {
+		err := NewBusinessError("PROFILE_CHANGE_NOT_PENDING", fmt.Sprintf("Profile change is %s", change.Status), ErrProfileChangeNotPending)
+		logAdminAction(ctx, f.auditRepo, models.AuditActionAdminProfileChangeReview, "Admin profile change review rejected", false, &customer.ID, meta, err)
+		return nil, err
+	}
+	if to == models.ProfileChangeApplied && change.Field == models.ProfileFieldNationalID && change.NewValue != nil {
+		// Another account may have taken the national ID while the change waited
+		if err := f.checkNationalIDAvailable(ctx, customer.ID, *change.NewValue); err != nil {
+			logAdminAction(ctx, f.auditRepo, models.AuditActionAdminProfileChangeReview, "Admin profile change review failed", false, &customer.ID, meta, err)
+			return nil, NewBusinessError("UPDATE_PROFILE_VALIDATION_FAILED", "National ID already exists", err)
+		}
+	}
+
+	var reviewerID *uint
+	if adminID, ok := adminIDFromContext(ctx); ok {
+		reviewerID = &adminID
+	}
+	now := utils.UTCNow()
+	err = repository.WithTransaction(ctx, f.db, func(txCtx context.Context) error {
+		ok, err := f.changeRepo.Review(txCtx, change.ID, to, reviewerID, reason, now)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrProfileChangeNotPending
+		}
+		if to != models.ProfileChangeApplied {
+			return nil
+		}
+		return f.customerRepo.UpdateProfileFields(txCtx, customer.ID, map[models.ProfileField]*string{change.Field: change.NewValue})
+	})
+	if err != nil {
+		logAdminAction(ctx, f.auditRepo, models.AuditActionAdminProfileChangeReview, "Admin profile change review failed", false, &customer.ID, meta, err)
+		if errors.Is(err, ErrProfileChangeNotPending) {
+			return nil, NewBusinessError("PROFILE_CHANGE_NOT_PENDING", "Profile change was reviewed meanwhile; reload and try again", err)
+		}
+		return nil, NewBusinessError("ADMIN_REVIEW_PROFILE_CHANGE_FAILED", "Failed to save review", err)
+	}
+	logAdminAction(ctx, f.auditRepo, models.AuditActionAdminProfileChangeReview, fmt.Sprintf("Admin set %s change to %s", change.Field, to), true, &customer.ID, meta, nil)
+
+	change.Status = to
+	change.ReviewerID = reviewerID
+	change.ReviewedAt = &now
+	change.RejectionReason = reason
+	return &dto.AdminReviewProfileChangeResponse{
+		Message: "Profile change review saved",
+		Change:  adminProfileChangeItem(change, customer),
+	}, nil
+}
+
+// changeProfile records a history row for every field whose value changes.
+// Changes of sensitive fields replace any change of the same field waiting
+// for review and wait for an admin themselves; the others are applied at once.
+func (f *ProfileFlowImpl) changeProfile(ctx context.Context, customerID uint, companyOnly bool, values []profileFieldValue, metadata *ClientMetadata) (*dto.UpdateProfileResponse, error) {
+	customer, err := getCustomer(ctx, f.customerRepo, customerID)
+	if err != nil {
+		return nil, NewBusinessError("UPDATE_PROFILE_FAILED", "Failed to update profile", err)
+	}
+	if companyOnly && !customer.RequiresCompanyFields() {
+		return nil, NewBusinessError("COMPANY_PROFILE_NOT_APPLICABLE", ErrCompanyProfileNotApplicable.Error(), ErrCompanyProfileNotApplicable)
+	}
+
+	var adminID *uint
+	if impersonation, ok := ctx.Value(utils.ImpersonationKey).(utils.Impersonation); ok && impersonation.AdminID != 0 {
+		adminID = &impersonation.AdminID
+	}
+
+	var changes []*models.CustomerProfileChange
+	applied := map[models.ProfileField]*string{}
+	for _, v := range values {
+		if v.value == nil {
+			continue
+		}
+		value := strings.TrimSpace(*v.value)
+		old := profileFieldOf(&customer, v.field)
+		if old != nil && *old == value {
+			continue
+		}
+		if v.field == models.ProfileFieldNationalID {
+			if err := f.checkNationalIDAvailable(ctx, customer.ID, value); err != nil {
+				return nil, NewBusinessError("UPDATE_PROFILE_VALIDATION_FAILED", "National ID already exists", err)
+			}
+		}
+
+		change := &models.CustomerProfileChange{
+			CustomerID:       customer.ID,
+			Field:            v.field,
+			OldValue:         old,
+			NewValue:         &value,
+			Status:           models.ProfileChangeApplied,
+			ChangedByAdminID: adminID,
+		}
+		if v.field.Sensitive() {
+			change.Status = models.ProfileChangePendingReview
+		} else {
+			applied[v.field] = &value
+		}
+		changes = append(changes, change)
+	}
+
+	resp := &dto.UpdateProfileResponse{
+		Message:       "Profile unchanged",
+		Applied:       []dto.ProfileChangeDTO{},
+		PendingReview: []dto.ProfileChangeDTO{},
+	}
+	if len(changes) == 0 {
+		return resp, nil
+	}
+
+	err = repository.WithTransaction(ctx, f.db, func(txCtx context.Context) error {
+		if err := f.customerRepo.UpdateProfileFields(txCtx, customer.ID, applied); err != nil {
+			return err
+		}
+		for _, change := range changes {
+			if change.Status == models.ProfileChangePendingReview {
+				if _, err := f.changeRepo.SupersedePending(txCtx, customer.ID, change.Field); err != nil {
+					return err
+				}
+			}
+			if err := f.changeRepo.Save(txCtx, change); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		errMsg := err.Error()
+		_ = createAuditLog(ctx, f.auditRepo, &customer, models.AuditActionProfileUpdated, "Profile update failed", false, &errMsg, metadata)
+		return nil, NewBusinessError("UPDATE_PROFILE_FAILED", "Failed to update profile", err)
+	}
+
+	var appliedFields, pendingFields []string
+	for _, change := range changes {
+		if change.Status == models.ProfileChangePendingReview {
+			pendingFields = append(pendingFields, string(change.Field))
+			resp.PendingReview = append(resp.PendingReview, profileChangeDTO(change))
+		} else {
+			appliedFields = append(appliedFields, string(change.Field))
+			resp.Applied = append(resp.Applied, profileChangeDTO(change))
+		}
+	}
+	if len(appliedFields) > 0 {
+		msg := fmt.Sprintf("Customer %d updated %s", customer.ID, strings.Join(appliedFields, ", "))
+		_ = createAuditLog(ctx, f.auditRepo, &customer, models.AuditActionProfileUpdated, msg, true, nil, metadata)
+	}
+	if len(pendingFields) > 0 {
+		msg := fmt.Sprintf("Customer %d requested to change %s; waiting for admin review", customer.ID, strings.Join(pendingFields, ", "))
+		_ = createAuditLog(ctx, f.auditRepo, &customer, models.AuditActionProfileChangeRequested, msg, true, nil, metadata)
+	}
+
+	resp.Message = "Profile updated"
+	if len(pendingFields) > 0 {
+		resp.Message = "Profile updated; some changes wait for verification"
+	}
+	return resp, nil
+}
+
+// checkNationalIDAvailable refuses a national ID another customer has
+func (f *ProfileFlowImpl) checkNationalIDAvailable(ctx context.Context, customerID uint, nationalID string) error {
+	existing, err := f.customerRepo.ByNationalID(ctx, nationalID)
+	if err != nil {
+		return err
+	}
+	if existing != nil && existing.ID != customerID {
+		return ErrNationalIDAlreadyExists
+	}
+	return nil
+}
+
+// profileFieldOf returns the current value of a profile field
+func profileFieldOf(c *models.Customer, field models.ProfileField) *string {
+	switch field {
+	case models.ProfileFieldCompanyName:
+		return c.CompanyName
+	case models.ProfileFieldNationalID:
+		return c.NationalID
+	case models.ProfileFieldCompanyPhone:
+		return c.CompanyPhone
+	case models.ProfileFieldCompanyAddress:
+		return c.CompanyAddress
+	case models.ProfileFieldPostalCode:
+		return c.PostalCode
+	case models.ProfileFieldShebaNumber:
+		return c.ShebaNumber
+	}
+	return nil
+}
+
+func profileChangeDTO(c *models.CustomerProfileChange) dto.ProfileChangeDTO {
+	return dto.ProfileChangeDTO{
+		UUID:             c.UUID.String(),
+		Field:            string(c.Field),
+		OldValue:         c.OldValue,
+		NewValue:         c.NewValue,
+		Status:           string(c.Status),
+		ChangedByAdminID: c.ChangedByAdminID,
+		ReviewedAt:       c.ReviewedAt,
+		RejectionReason:  c.RejectionReason,
+		CreatedAt:        c.CreatedAt,
+	}
+}
+
+func adminProfileChangeItem(c *models.CustomerProfileChange, customer *models.Customer) dto.AdminProfileChangeItem {
+	item := dto.AdminProfileChangeItem{ProfileChangeDTO: profileChangeDTO(c), CustomerID: c.CustomerID}
+	if customer != nil {
+		item.Email = customer.Email
+		item.CompanyName = customer.CompanyName
+	}
+	return item
+}
@@ -0,0 +1,186 @@
+package businessflow
+
+import (
+	"context"
+	"testing"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/repository"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/google/uuid"
+)
+
+type profileCustomerRepoStub struct {
+	repository.CustomerRepository
+	customers map[uint]*models.Customer
+}
+
+func (r *profileCustomerRepoStub) ByID(_ context.Context, id uint) (*models.Customer, error) {
+	c, ok := r.customers[id]
+	if !ok {
+		return nil, nil
+	}
+	copy := *c
+	return &copy, nil
+}
+
+func (r *profileCustomerRepoStub) ByNationalID(_ context.Context, nationalID string) (*models.Customer, error) {
+	for _, c := range r.customers {
+		if c.NationalID != nil && *c.NationalID == nationalID {
+			copy := *c
+			return &copy, nil
+		}
+	}
+	return nil, nil
+}
+
+type profileChangeRepoStub struct {
+	repository.CustomerProfileChangeRepository
+	changes map[uuid.UUID]*models.CustomerProfileChange
+}
+
+func (r *profileChangeRepoStub) ByUUID(_ context.Context, id uuid.UUID) (*models.CustomerProfileChange, error) {
+	c, ok := r.changes[id]
+	if !ok {
+		return nil, nil
+	}
+	copy := *c
+	return &copy, nil
+}
+
+func newTestProfileFlow(changes ...*models.CustomerProfileChange) (*ProfileFlowImpl, *kycAuditStub) {
+	customers := &profileCustomerRepoStub{customers: map[uint]*models.Customer{
+		5: {
+			ID:          5,
+			IsActive:    utils.ToPtr(true),
+			AccountType: models.AccountType{TypeName: models.AccountTypeIndependentCompany},
+			CompanyName: utils.ToPtr("Acme"),
+			NationalID:  utils.ToPtr("10101010101"),
+			ShebaNumber: utils.ToPtr("IR820540102680020817909002"),
+		},
+		6: {
+			ID:          6,
+			IsActive:    utils.ToPtr(true),
+			AccountType: models.AccountType{TypeName: models.AccountTypeIndividual},
+		},
+		7: {
+			ID:          7,
+			IsActive:    utils.ToPtr(true),
+			AccountType: models.AccountType{TypeName: models.AccountTypeIndependentCompany},
+			NationalID:  utils.ToPtr("20202020202"),
+		},
+	}}
+	repo := &profileChangeRepoStub{changes: map[uuid.UUID]*models.CustomerProfileChange{}}
+	for _, c := range changes {
+		repo.changes[c.UUID] = c
+	}
+	audit := &kycAuditStub{}
+	return NewProfileFlow(customers, repo, audit, nil).(*ProfileFlowImpl), audit
+}
+
+func TestProfileUpdateRejections(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	flow, _ := newTestProfileFlow()
+
+	_, err := flow.UpdateCompanyInfo(ctx, 6, &dto.UpdateCompanyInfoRequest{CompanyName: utils.ToPtr("Solo")}, nil)
+	if !IsCompanyProfileNotApplicable(err) {
+		t.Errorf("individual company update: got %v, want ErrCompanyProfileNotApplicable", err)
+	}
+
+	_, err = flow.UpdateCompanyInfo(ctx, 5, &dto.UpdateCompanyInfoRequest{NationalID: utils.ToPtr("20202020202")}, nil)
+	if !IsNationalIDAlreadyExists(err) {
+		t.Errorf("taken national ID: got %v, want ErrNationalIDAlreadyExists", err)
+	}
+
+	_, err = flow.UpdateSheba(ctx, 5, &dto.UpdateShebaRequest{ShebaNumber: "IR82054010268002081790900X"}, nil)
+	if !IsShebaNumberInvalid(err) {
+		t.Errorf("invalid Sheba number: got %v, want ErrShebaNumberInvalid", err)
+	}
+
+	_, err = flow.UpdateAddress(ctx, 99, &dto.UpdateAddressRequest{PostalCode: utils.ToPtr("1234567890")}, nil)
+	if !IsCustomerNotFound(err) {
+		t.Errorf("unknown customer: got %v, want ErrCustomerNotFound", err)
+	}
+}
+
+func TestProfileUpdateSkipsUnchangedFields(t *testing.T) {
+	t.Parallel()
+
+	flow, audit := newTestProfileFlow()
+	res, err := flow.UpdateCompanyInfo(context.Background(), 5, &dto.UpdateCompanyInfoRequest{
+		CompanyName: utils.ToPtr(" Acme "),
+		NationalID:  utils.ToPtr("10101010101"),
+	}, nil)
+	if err != nil {
+		t.Fatalf("UpdateCompanyInfo: %v", err)
+	}
+	if len(res.Applied) != 0 || len(res.PendingReview) != 0 {
+		t.Errorf("unchanged values recorded changes: applied %d, pending %d", len(res.Applied), len(res.PendingReview))
+	}
+	if len(audit.actions) != 0 {
+		t.Errorf("unchanged values were audited: %v", audit.actions)
+	}
+}
+
+func TestAdminReviewProfileChangeRejections(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	applied := &models.CustomerProfileChange{
+		ID:         1,
+		UUID:       uuid.New(),
+		CustomerID: 5,
+		Field:      models.ProfileFieldShebaNumber,
+		Status:     models.ProfileChangeApplied,
+	}
+	taken := &models.CustomerProfileChange{
+		ID:         2,
+		UUID:       uuid.New(),
+		CustomerID: 5,
+		Field:      models.ProfileFieldNationalID,
+		NewValue:   utils.ToPtr("20202020202"),
+		Status:     models.ProfileChangePendingReview,
+	}
+	flow, audit := newTestProfileFlow(applied, taken)
+
+	_, err := flow.AdminReviewProfileChange(ctx, &dto.AdminReviewProfileChangeRequest{UUID: taken.UUID.String(), Decision: "reject", Reason: utils.ToPtr("  ")})
+	if !IsProfileChangeRejectionReasonNeeded(err) {
+		t.Errorf("reject without reason: got %v, want ErrProfileChangeRejectionReasonNeeded", err)
+	}
+
+	_, err = flow.AdminReviewProfileChange(ctx, &dto.AdminReviewProfileChangeRequest{UUID: uuid.NewString(), Decision: "approve"})
+	if !IsProfileChangeNotFound(err) {
+		t.Errorf("unknown change: got %v, want ErrProfileChangeNotFound", err)
+	}
+
+	_, err = flow.AdminReviewProfileChange(ctx, &dto.AdminReviewProfileChangeRequest{UUID: applied.UUID.String(), Decision: "approve"})
+	if !IsProfileChangeNotPending(err) {
+		t.Errorf("applied change: got %v, want ErrProfileChangeNotPending", err)
+	}
+
+	_, err = flow.AdminReviewProfileChange(ctx, &dto.AdminReviewProfileChangeRequest{UUID: taken.UUID.String(), Decision: "approve"})
+	if !IsNationalIDAlreadyExists(err) {
+		t.Errorf("national ID taken meanwhile: got %v, want ErrNationalIDAlreadyExists", err)
+	}
+
+	if len(audit.actions) != 2 {
+		t.Errorf("audited %d review failures, want 2", len(audit.actions))
+	}
+}
+
+func TestProfileFieldSensitivity(t *testing.T) {
+	t.Parallel()
+
+	for _, f := range []models.ProfileField{
+		models.ProfileFieldCompanyName, models.ProfileFieldNationalID, models.ProfileFieldCompanyPhone,
+		models.ProfileFieldCompanyAddress, models.ProfileFieldPostalCode, models.ProfileFieldShebaNumber,
+	} {
+		want := f == models.ProfileFieldNationalID || f == models.ProfileFieldShebaNumber
+		if got := f.Sensitive(); got != want {
+			t.Errorf("%s.Sensitive() = %v, want %v", f, got, want)
+		}
+	}
+}
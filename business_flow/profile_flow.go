@@ -4,20 +4,46 @@ import (
 	"context"
 
 	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/app/i18n"
 	"github.com/amirphl/Yamata-no-Orochi/models"
 	"github.com/amirphl/Yamata-no-Orochi/repository"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"gorm.io/gorm"
 )
 
+// ProfileFlow reads and changes the customer's profile. Changes of sensitive
+// fields such as the Sheba number and national ID wait for an admin to verify
+// them; every change is kept in the profile change history.
 type ProfileFlow interface {
 	GetProfile(ctx context.Context, customerID uint) (*dto.GetProfileResponse, error)
+	UpdatePreferredLocale(ctx context.Context, customerID uint, req *dto.UpdateLocaleRequest) (*dto.UpdateLocaleResponse, error)
+	UpdateCompanyInfo(ctx context.Context, customerID uint, req *dto.UpdateCompanyInfoRequest, metadata *ClientMetadata) (*dto.UpdateProfileResponse, error)
+	UpdateAddress(ctx context.Context, customerID uint, req *dto.UpdateAddressRequest, metadata *ClientMetadata) (*dto.UpdateProfileResponse, error)
+	UpdateSheba(ctx context.Context, customerID uint, req *dto.UpdateShebaRequest, metadata *ClientMetadata) (*dto.UpdateProfileResponse, error)
+	ListProfileChanges(ctx context.Context, customerID uint, filter dto.ListProfileChangesFilter) (*dto.ListProfileChangesResponse, error)
+	AdminListProfileChanges(ctx context.Context, filter dto.AdminListProfileChangesFilter) (*dto.AdminListProfileChangesResponse, error)
+	AdminReviewProfileChange(ctx context.Context, req *dto.AdminReviewProfileChangeRequest) (*dto.AdminReviewProfileChangeResponse, error)
 }
 
 type ProfileFlowImpl struct {
 	customerRepo repository.CustomerRepository
+	changeRepo   repository.CustomerProfileChangeRepository
+	auditRepo    repository.AuditLogRepository
+	db           *gorm.DB
 }
 
-func NewProfileFlow(customerRepo repository.CustomerRepository) ProfileFlow {
-	return &ProfileFlowImpl{customerRepo: customerRepo}
+func NewProfileFlow(
+	customerRepo repository.CustomerRepository,
+	changeRepo repository.CustomerProfileChangeRepository,
+	auditRepo repository.AuditLogRepository,
+	db *gorm.DB,
+) ProfileFlow {
+	return &ProfileFlowImpl{
+		customerRepo: customerRepo,
+		changeRepo:   changeRepo,
+		auditRepo:    auditRepo,
+		db:           db,
+	}
 }
 
 func (f *ProfileFlowImpl) GetProfile(ctx context.Context, customerID uint) (*dto.GetProfileResponse, error) {
@@ -46,6 +72,28 @@ func (f *ProfileFlowImpl) GetProfile(ctx context.Context, customerID uint) (*dto
 	return resp, nil
 }
 
+// UpdatePreferredLocale sets the language messages are sent to the customer in
+func (f *ProfileFlowImpl) UpdatePreferredLocale(ctx context.Context, customerID uint, req *dto.UpdateLocaleRequest) (*dto.UpdateLocaleResponse, error) {
+	locale, ok := i18n.ParseLocale(req.Locale)
+	if !ok {
+		return nil, NewBusinessError("INVALID_LOCALE", "Unsupported locale", ErrInvalidLanguage)
+	}
+
+	cust, err := f.customerRepo.ByID(ctx, customerID)
+	if err != nil {
+		return nil, NewBusinessError("CUSTOMER_FETCH_FAILED", "Failed to fetch customer", err)
+	}
+	if cust == nil {
+		return nil, NewBusinessError("CUSTOMER_NOT_FOUND", "Customer not found", ErrCustomerNotFound)
+	}
+
+	if err := f.customerRepo.UpdatePreferredLocale(ctx, customerID, utils.ToPtr(string(locale))); err != nil {
+		return nil, NewBusinessError("UPDATE_LOCALE_FAILED", "Failed to update locale", err)
+	}
+
+	return &dto.UpdateLocaleResponse{Message: "Locale updated", Locale: string(locale)}, nil
+}
+
 func mapCustomerToProfileDTO(c *models.Customer) dto.ProfileDTO {
 	dtoOut := dto.ProfileDTO{
 		ID:                      c.ID,
@@ -62,6 +110,7 @@ func mapCustomerToProfileDTO(c *models.Customer) dto.ProfileDTO {
 		CompanyAddress:          c.CompanyAddress,
 		PostalCode:              c.PostalCode,
 		ShebaNumber:             c.ShebaNumber,
+		ShebaVerification:       string(c.ShebaStatus()),
 		Category:                c.Category,
 		Job:                     c.Job,
 		IsActive:                c.IsActive,
@@ -69,10 +118,12 @@ func mapCustomerToProfileDTO(c *models.Customer) dto.ProfileDTO {
 		ReferrerAgencyID:        c.ReferrerAgencyID,
 		IsEmailVerified:         c.IsEmailVerified,
 		IsMobileVerified:        c.IsMobileVerified,
+		PreferredLocale:         c.PreferredLocale,
 		LastLoginAt:             c.LastLoginAt,
 		CreatedAt:               c.CreatedAt,
 		UpdatedAt:               c.UpdatedAt,
 	}
+	dtoOut.Suspension = customerSuspensionDTO(c)
 	return dtoOut
 }
 
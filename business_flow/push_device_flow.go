@@ -0,0 +1,192 @@
+package businessflow
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/app/services"
+	"github.com/amirphl/Yamata-no-Orochi/config"
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/repository"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+)
+
+// maxPushDevicesPerCustomer bounds the tokens kept per customer; registering
+// another one drops the least recently seen
+const maxPushDevicesPerCustomer = 10
+
+// PushDeviceFlow manages the FCM tokens of the dashboard PWA and the admin
+// broadcast to every subscribed device
+type PushDeviceFlow interface {
+	RegisterDevice(ctx context.Context, req *dto.RegisterPushDeviceRequest) (*dto.RegisterPushDeviceResponse, error)
+	UnregisterDevice(ctx context.Context, req *dto.UnregisterPushDeviceRequest) (*dto.UnregisterPushDeviceResponse, error)
+	ListDevices(ctx context.Context, customerID uint) (*dto.ListPushDevicesResponse, error)
+	Broadcast(ctx context.Context, req *dto.AdminBroadcastPushRequest) (*dto.AdminBroadcastPushResponse, error)
+}
+
+type PushDeviceFlowImpl struct {
+	deviceRepo   repository.PushDeviceRepository
+	customerRepo repository.CustomerRepository
+	auditRepo    repository.AuditLogRepository
+	cfg          config.FCMConfig
+	notifier     services.NotificationService
+}
+
+func NewPushDeviceFlow(
+	deviceRepo repository.PushDeviceRepository,
+	customerRepo repository.CustomerRepository,
+	auditRepo repository.AuditLogRepository,
+	cfg config.FCMConfig,
+	notifier services.NotificationService,
+) PushDeviceFlow {
+	return &PushDeviceFlowImpl{
+		deviceRepo:   deviceRepo,
+		customerRepo: customerRepo,
+		auditRepo:    auditRepo,
+		cfg:          cfg,
+		notifier:     notifier,
+	}
+}
+
+// RegisterDevice stores the token, or refreshes it when already known, and
+// subscribes it to the broadcast topic. Registering is idempotent, so the
+// PWA calls it on every start and whenever FCM rotates the token.
+func (f *PushDeviceFlowImpl) RegisterDevice(ctx context.Context, req *dto.RegisterPushDeviceRequest) (*dto.RegisterPushDeviceResponse, error) {
+	if !f.cfg.Enabled() {
+		return nil, NewBusinessError("PUSH_NOT_CONFIGURED", "Push notifications are not configured", ErrPushNotConfigured)
+	}
+	customer, err := getCustomer(ctx, f.customerRepo, req.CustomerID)
+	if err != nil {
+		return nil, NewBusinessError("REGISTER_PUSH_DEVICE_FAILED", "Failed to find customer", err)
+	}
+
+	now := utils.UTCNow()
+	device := &models.PushDevice{
+		CustomerID: customer.ID,
+		Token:      strings.TrimSpace(req.Token),
+		Platform:   req.Platform,
+		LastSeenAt: now,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if ua := strings.TrimSpace(req.UserAgent); ua != "" {
+		if len(ua) > 255 {
+			ua = ua[:255]
+		}
+		device.UserAgent = &ua
+	}
+	if err := f.deviceRepo.Register(ctx, device); err != nil {
+		return nil, NewBusinessError("REGISTER_PUSH_DEVICE_FAILED", "Failed to register push device", err)
+	}
+	if _, err := f.deviceRepo.PruneOldest(ctx, customer.ID, maxPushDevicesPerCustomer); err != nil {
+		log.Printf("prune push devices of customer %d: %v", customer.ID, err)
+	}
+
+	if f.cfg.BroadcastTopic != "" && f.notifier != nil {
+		if err := f.notifier.SubscribePushTopic(ctx, f.cfg.BroadcastTopic, device.Token); err != nil {
+			log.Printf("subscribe push device of customer %d to %s: %v", customer.ID, f.cfg.BroadcastTopic, err)
+		}
+	}
+
+	// The upsert does not return the stored row when the token was known
+	stored, err := f.deviceRepo.ByFilter(ctx, models.PushDeviceFilter{Token: &device.Token}, "", 1, 0)
+	if err != nil {
+		return nil, NewBusinessError("REGISTER_PUSH_DEVICE_FAILED", "Failed to load push device", err)
+	}
+	if len(stored) > 0 {
+		device = stored[0]
+	}
+	return &dto.RegisterPushDeviceResponse{
+		Message: "Push device registered",
+		Device:  toPushDeviceItem(device),
+	}, nil
+}
+
+// UnregisterDevice removes a token of the customer
+func (f *PushDeviceFlowImpl) UnregisterDevice(ctx context.Context, req *dto.UnregisterPushDeviceRequest) (*dto.UnregisterPushDeviceResponse, error) {
+	removed, err := f.deviceRepo.Remove(ctx, req.CustomerID, strings.TrimSpace(req.Token))
+	if err != nil {
+		return nil, NewBusinessError("UNREGISTER_PUSH_DEVICE_FAILED", "Failed to remove push device", err)
+	}
+	if removed == 0 {
+		return nil, NewBusinessError("PUSH_DEVICE_NOT_FOUND", "Push device not found", ErrPushDeviceNotFound)
+	}
+	return &dto.UnregisterPushDeviceResponse{Message: "Push device removed"}, nil
+}
+
+// ListDevices returns the customer's registered devices, most recently seen
+// first
+func (f *PushDeviceFlowImpl) ListDevices(ctx context.Context, customerID uint) (*dto.ListPushDevicesResponse, error) {
+	devices, err := f.deviceRepo.ByFilter(ctx, models.PushDeviceFilter{CustomerID: &customerID}, "", maxPushDevicesPerCustomer, 0)
+	if err != nil {
+		return nil, NewBusinessError("LIST_PUSH_DEVICES_FAILED", "Failed to list push devices", err)
+	}
+	items := make([]dto.PushDeviceItem, 0, len(devices))
+	for _, d := range devices {
+		items = append(items, toPushDeviceItem(d))
+	}
+	return &dto.ListPushDevicesResponse{Enabled: f.cfg.Enabled(), Items: items}, nil
+}
+
+// Broadcast sends a notification to the broadcast topic every registered
+// device is subscribed to
+func (f *PushDeviceFlowImpl) Broadcast(ctx context.Context, req *dto.AdminBroadcastPushRequest) (*dto.AdminBroadcastPushResponse, error) {
+	if !f.cfg.Enabled() || f.cfg.BroadcastTopic == "" || f.notifier == nil {
+		return nil, NewBusinessError("PUSH_NOT_CONFIGURED", "Push notifications are not configured", ErrPushNotConfigured)
+	}
+	metadata := map[string]any{
+		"topic": f.cfg.BroadcastTopic,
+		"title": req.Title,
+	}
+	err := f.notifier.SendPushTopic(ctx, f.cfg.BroadcastTopic, services.PushMessage{
+		Title: req.Title,
+		Body:  req.Body,
+		Link:  req.Link,
+	})
+	if err != nil {
+		logAdminAction(ctx, f.auditRepo, models.AuditActionAdminPushBroadcast, "Admin push broadcast failed", false, nil, metadata, err)
+		return nil, NewBusinessError("BROADCAST_PUSH_FAILED", "Failed to send push broadcast", err)
+	}
+	logAdminAction(ctx, f.auditRepo, models.AuditActionAdminPushBroadcast, "Admin sent push broadcast", true, nil, metadata, nil)
+	return &dto.AdminBroadcastPushResponse{Message: "Push broadcast sent", Topic: f.cfg.BroadcastTopic}, nil
+}
+
+func toPushDeviceItem(d *models.PushDevice) dto.PushDeviceItem {
+	return dto.PushDeviceItem{
+		ID:         d.ID,
+		Platform:   d.Platform,
+		UserAgent:  d.UserAgent,
+		LastSeenAt: d.LastSeenAt,
+		CreatedAt:  d.CreatedAt,
+	}
+}
+
+// sendCustomerPush sends a notice to every device of the customer and drops
+// the tokens FCM rejects. Delivery is best-effort.
+func sendCustomerPush(ctx context.Context, notifier services.NotificationService, deviceRepo repository.PushDeviceRepository, customerID uint, msg services.PushMessage) {
+	if deviceRepo == nil {
+		return
+	}
+	devices, err := deviceRepo.ByFilter(ctx, models.PushDeviceFilter{CustomerID: &customerID}, "", maxPushDevicesPerCustomer, 0)
+	if err != nil {
+		log.Printf("push notice to customer %d: %v", customerID, err)
+		return
+	}
+	var invalid []string
+	for _, d := range devices {
+		err := notifier.SendPush(ctx, d.Token, msg)
+		switch {
+		case err == nil:
+		case errors.Is(err, services.ErrPushTokenInvalid):
+			invalid = append(invalid, d.Token)
+		default:
+			log.Printf("push notice to device %d of customer %d: %v", d.ID, customerID, err)
+		}
+	}
+	if _, err := deviceRepo.RemoveTokens(ctx, invalid); err != nil {
+		log.Printf("remove invalid push tokens of customer %d: %v", customerID, err)
+	}
+}
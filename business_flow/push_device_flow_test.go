@@ -0,0 +1,67 @@
+package businessflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/services"
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/repository"
+)
+
+type pushDeviceRepoStub struct {
+	repository.PushDeviceRepository
+	devices []*models.PushDevice
+	removed []string
+}
+
+func (r *pushDeviceRepoStub) ByFilter(_ context.Context, filter models.PushDeviceFilter, _ string, _, _ int) ([]*models.PushDevice, error) {
+	var out []*models.PushDevice
+	for _, d := range r.devices {
+		if filter.CustomerID != nil && d.CustomerID != *filter.CustomerID {
+			continue
+		}
+		out = append(out, d)
+	}
+	return out, nil
+}
+
+func (r *pushDeviceRepoStub) RemoveTokens(_ context.Context, tokens []string) (int64, error) {
+	r.removed = append(r.removed, tokens...)
+	return int64(len(tokens)), nil
+}
+
+func TestSendCustomerNoticePush(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	customer := &models.Customer{ID: 7, RepresentativeMobile: "+989121234567"}
+	repo := &pushDeviceRepoStub{devices: []*models.PushDevice{
+		{ID: 1, CustomerID: 7, Token: "laptop"},
+		{ID: 2, CustomerID: 7, Token: "uninstalled"},
+		{ID: 3, CustomerID: 7, Token: "flaky"},
+		{ID: 4, CustomerID: 8, Token: "someone-else"},
+	}}
+	n := &recordingNotifier{pushErr: map[string]error{
+		"uninstalled": fmt.Errorf("%w: UNREGISTERED", services.ErrPushTokenInvalid),
+		"flaky":       errors.New("fcm send push: http 503"),
+	}}
+
+	if err := sendCustomerNotice(ctx, n, repo, customer, "Campaign approved", "approved"); err != nil {
+		t.Fatal(err)
+	}
+	if msgs := n.push["laptop"]; len(msgs) != 1 || msgs[0].Title != "Campaign approved" || msgs[0].Body != "approved" {
+		t.Fatalf("expected the notice on the customer's device, got %v", n.push)
+	}
+	if len(n.push["someone-else"]) != 0 {
+		t.Fatal("another customer's device must not be notified")
+	}
+	if len(repo.removed) != 1 || repo.removed[0] != "uninstalled" {
+		t.Fatalf("only the rejected token should be removed, got %v", repo.removed)
+	}
+	if len(n.sms) != 1 {
+		t.Fatalf("push is sent in addition to SMS, got sms=%v", n.sms)
+	}
+}
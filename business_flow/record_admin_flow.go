@@ -0,0 +1,209 @@
+package businessflow
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/repository"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+)
+
+// Kinds of records that admins can soft-delete and restore
+const (
+	RecordKindCampaigns        = "campaigns"
+	RecordKindAudienceProfiles = "audience-profiles"
+	RecordKindTags             = "tags"
+	RecordKindLineNumbers      = "line-numbers"
+)
+
+// RecordAdminFlow soft-deletes and restores campaigns, audience profiles,
+// tags and line numbers. Deleted records drop out of every listing and
+// lookup but stay in the database, so reports keep their history and an
+// admin can bring them back.
+type RecordAdminFlow interface {
+	Delete(ctx context.Context, req *dto.AdminRecordRequest) (*dto.AdminRecordResponse, error)
+	Restore(ctx context.Context, req *dto.AdminRecordRequest) (*dto.AdminRecordResponse, error)
+}
+
+type RecordAdminFlowImpl struct {
+	campaignRepo        repository.CampaignRepository
+	audienceProfileRepo repository.AudienceProfileRepository
+	tagRepo             repository.TagRepository
+	lineNumberRepo      repository.LineNumberRepository
+	auditRepo           repository.AuditLogRepository
+}
+
+func NewRecordAdminFlow(
+	campaignRepo repository.CampaignRepository,
+	audienceProfileRepo repository.AudienceProfileRepository,
+	tagRepo repository.TagRepository,
+	lineNumberRepo repository.LineNumberRepository,
+	auditRepo repository.AuditLogRepository,
+) RecordAdminFlow {
+	return &RecordAdminFlowImpl{
+		campaignRepo:        campaignRepo,
+		audienceProfileRepo: audienceProfileRepo,
+		tagRepo:             tagRepo,
+		lineNumberRepo:      lineNumberRepo,
+		auditRepo:           auditRepo,
+	}
+}
+
+// softDeletable is what the flow needs to know of a record before deleting
+// or restoring it
+type softDeletable struct {
+	repo       repository.SoftDeleteRepository
+	deleted    bool
+	customerID *uint
+	// blocker explains why the record must not be deleted now, if it must not
+	blocker string
+}
+
+// Delete soft-deletes a record. Campaigns must be drafts or finished and
+// line numbers must be deactivated first, so nothing in flight loses them.
+func (f *RecordAdminFlowImpl) Delete(ctx context.Context, req *dto.AdminRecordRequest) (*dto.AdminRecordResponse, error) {
+	if req == nil {
+		return nil, NewBusinessError("VALIDATION_ERROR", "Request is required", nil)
+	}
+	meta := map[string]any{"kind": req.Kind, "id": req.ID}
+	rec, err := f.find(ctx, req.Kind, req.ID)
+	if err == nil {
+		switch {
+		case rec.deleted:
+			err = ErrRecordAlreadyDeleted
+		case rec.blocker != "":
+			err = NewBusinessError("RECORD_NOT_DELETABLE", rec.blocker, ErrRecordNotDeletable)
+		}
+	}
+	if err == nil {
+		var found bool
+		if found, err = rec.repo.SoftDelete(ctx, req.ID); err == nil && !found {
+			err = ErrRecordAlreadyDeleted
+		}
+	}
+	if err != nil {
+		var customerID *uint
+		if rec != nil {
+			customerID = rec.customerID
+		}
+		logAdminAction(ctx, f.auditRepo, models.AuditActionAdminRecordDeleted, "Record delete failed", false, customerID, meta, err)
+		return nil, recordError("DELETE_RECORD_FAILED", "Failed to delete record", err)
+	}
+
+	now := utils.UTCNow()
+	logAdminAction(ctx, f.auditRepo, models.AuditActionAdminRecordDeleted, fmt.Sprintf("Deleted %s %d", req.Kind, req.ID), true, rec.customerID, meta, nil)
+	return &dto.AdminRecordResponse{Message: "Record deleted", Kind: req.Kind, ID: req.ID, DeletedAt: &now}, nil
+}
+
+// Restore brings back a soft-deleted record as it was when deleted
+func (f *RecordAdminFlowImpl) Restore(ctx context.Context, req *dto.AdminRecordRequest) (*dto.AdminRecordResponse, error) {
+	if req == nil {
+		return nil, NewBusinessError("VALIDATION_ERROR", "Request is required", nil)
+	}
+	meta := map[string]any{"kind": req.Kind, "id": req.ID}
+	rec, err := f.find(ctx, req.Kind, req.ID)
+	if err == nil && !rec.deleted {
+		err = ErrRecordNotDeleted
+	}
+	if err == nil {
+		var found bool
+		if found, err = rec.repo.Restore(ctx, req.ID); err == nil && !found {
+			err = ErrRecordNotDeleted
+		}
+	}
+	if err != nil {
+		var customerID *uint
+		if rec != nil {
+			customerID = rec.customerID
+		}
+		logAdminAction(ctx, f.auditRepo, models.AuditActionAdminRecordRestored, "Record restore failed", false, customerID, meta, err)
+		return nil, recordError("RESTORE_RECORD_FAILED", "Failed to restore record", err)
+	}
+
+	logAdminAction(ctx, f.auditRepo, models.AuditActionAdminRecordRestored, fmt.Sprintf("Restored %s %d", req.Kind, req.ID), true, rec.customerID, meta, nil)
+	return &dto.AdminRecordResponse{Message: "Record restored", Kind: req.Kind, ID: req.ID}, nil
+}
+
+// find loads a record of any kind, deleted or not
+func (f *RecordAdminFlowImpl) find(ctx context.Context, kind string, id uint) (*softDeletable, error) {
+	switch kind {
+	case RecordKindCampaigns:
+		rows, err := f.campaignRepo.ByFilter(ctx, models.CampaignFilter{ID: &id, IncludeDeleted: true}, "", 1, 0)
+		if err != nil {
+			return nil, err
+		}
+		if len(rows) == 0 {
+			return nil, ErrRecordNotFound
+		}
+		c := rows[0]
+		return &softDeletable{repo: f.campaignRepo, deleted: c.DeletedAt.Valid, customerID: &c.CustomerID, blocker: campaignDeleteBlocker(c)}, nil
+	case RecordKindAudienceProfiles:
+		rows, err := f.audienceProfileRepo.ByFilter(ctx, models.AudienceProfileFilter{ID: &id, IncludeDeleted: true}, "", 1, 0)
+		if err != nil {
+			return nil, err
+		}
+		if len(rows) == 0 {
+			return nil, ErrRecordNotFound
+		}
+		return &softDeletable{repo: f.audienceProfileRepo, deleted: rows[0].DeletedAt.Valid}, nil
+	case RecordKindTags:
+		rows, err := f.tagRepo.ByFilter(ctx, models.TagFilter{ID: &id, IncludeDeleted: true}, "", 1, 0)
+		if err != nil {
+			return nil, err
+		}
+		if len(rows) == 0 {
+			return nil, ErrRecordNotFound
+		}
+		return &softDeletable{repo: f.tagRepo, deleted: rows[0].DeletedAt.Valid}, nil
+	case RecordKindLineNumbers:
+		rows, err := f.lineNumberRepo.ByFilter(ctx, models.LineNumberFilter{ID: &id, IncludeDeleted: true}, "", 1, 0)
+		if err != nil {
+			return nil, err
+		}
+		if len(rows) == 0 {
+			return nil, ErrRecordNotFound
+		}
+		return &softDeletable{repo: f.lineNumberRepo, deleted: rows[0].DeletedAt.Valid, blocker: lineNumberDeleteBlocker(rows[0])}, nil
+	default:
+		return nil, ErrRecordKindInvalid
+	}
+}
+
+// campaignDeleteBlocker returns why a campaign must not be deleted, or ""
+// when it may be. Approved, running and paused campaigns hold budget and
+// are still worked on by the schedulers.
+func campaignDeleteBlocker(c *models.Campaign) string {
+	if c.IsDeletable() {
+		return ""
+	}
+	return fmt.Sprintf("campaign is %s; cancel it first", c.Status)
+}
+
+// lineNumberDeleteBlocker returns why a line number must not be deleted, or
+// "" when it may be. Active lines can be picked by campaigns at any moment.
+func lineNumberDeleteBlocker(ln *models.LineNumber) string {
+	if ln.IsActive != nil && *ln.IsActive {
+		return "line number is active; deactivate it first"
+	}
+	return ""
+}
+
+// recordError keeps the sentinel of err in the business error so handlers
+// can map it
+func recordError(code, message string, err error) error {
+	switch {
+	case IsRecordKindInvalid(err):
+		return NewBusinessError("RECORD_KIND_INVALID", "Unknown record kind", err)
+	case IsRecordNotFound(err):
+		return NewBusinessError("RECORD_NOT_FOUND", "Record not found", err)
+	case IsRecordNotDeletable(err):
+		return err
+	case IsRecordAlreadyDeleted(err):
+		return NewBusinessError("RECORD_ALREADY_DELETED", "Record is already deleted", err)
+	case IsRecordNotDeleted(err):
+		return NewBusinessError("RECORD_NOT_DELETED", "Record is not deleted", err)
+	}
+	return NewBusinessError(code, message, err)
+}
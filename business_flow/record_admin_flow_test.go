@@ -0,0 +1,47 @@
+package businessflow
+
+import (
+	"testing"
+
+	"github.com/amirphl/Yamata-no-Orochi/models"
+)
+
+func TestCampaignDeleteBlocker(t *testing.T) {
+	t.Parallel()
+
+	cases := map[models.CampaignStatus]bool{
+		models.CampaignStatusInitiated:          true,
+		models.CampaignStatusInProgress:         true,
+		models.CampaignStatusWaitingForApproval: false,
+		models.CampaignStatusChangesRequested:   false,
+		models.CampaignStatusApproved:           false,
+		models.CampaignStatusRunning:            false,
+		models.CampaignStatusPaused:             false,
+		models.CampaignStatusExecuted:           true,
+		models.CampaignStatusExpired:            true,
+		models.CampaignStatusRejected:           true,
+		models.CampaignStatusCancelled:          true,
+		models.CampaignStatusCancelledByAdmin:   true,
+	}
+	for status, deletable := range cases {
+		blocker := campaignDeleteBlocker(&models.Campaign{Status: status})
+		if (blocker == "") != deletable {
+			t.Errorf("%s: blocker %q, want deletable=%t", status, blocker, deletable)
+		}
+	}
+}
+
+func TestLineNumberDeleteBlocker(t *testing.T) {
+	t.Parallel()
+
+	active, inactive := true, false
+	if lineNumberDeleteBlocker(&models.LineNumber{IsActive: &active}) == "" {
+		t.Error("active line number should not be deletable")
+	}
+	if blocker := lineNumberDeleteBlocker(&models.LineNumber{IsActive: &inactive}); blocker != "" {
+		t.Errorf("inactive line number blocked: %q", blocker)
+	}
+	if blocker := lineNumberDeleteBlocker(&models.LineNumber{}); blocker != "" {
+		t.Errorf("line number without is_active blocked: %q", blocker)
+	}
+}
@@ -0,0 +1,158 @@
+package businessflow
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/repository"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+)
+
+// reportRollupOnDemandMaxDays caps the days an admin may re-roll in one request
+const reportRollupOnDemandMaxDays = 31
+
+// ReportRollupFlow refreshes the campaign_daily_stats, revenue_daily and
+// customer_monthly_usage rollups. Days are Tehran days; each refreshed day is
+// rebuilt from scratch, so refreshing a day again is always safe.
+type ReportRollupFlow interface {
+	// RefreshIncremental re-rolls today; the first run of a day also re-rolls
+	// the lookback days before it, or every day since the last refresh
+	RefreshIncremental(ctx context.Context) (*dto.ReportRollupRefreshSummary, error)
+	// Backfill re-rolls the Tehran days from and to fall on, both inclusive
+	Backfill(ctx context.Context, from, to time.Time) (*dto.ReportRollupRefreshSummary, error)
+	// RefreshOnDemand re-rolls up to a month of days on an admin's request
+	RefreshOnDemand(ctx context.Context, req *dto.AdminReportRollupRefreshRequest) (*dto.ReportRollupRefreshSummary, error)
+}
+
+type ReportRollupFlowImpl struct {
+	rollupRepo   repository.ReportRollupRepository
+	lookbackDays int
+}
+
+func NewReportRollupFlow(rollupRepo repository.ReportRollupRepository, lookbackDays int) ReportRollupFlow {
+	if lookbackDays < 0 {
+		lookbackDays = 0
+	}
+	return &ReportRollupFlowImpl{
+		rollupRepo:   rollupRepo,
+		lookbackDays: lookbackDays,
+	}
+}
+
+func (f *ReportRollupFlowImpl) RefreshIncremental(ctx context.Context) (*dto.ReportRollupRefreshSummary, error) {
+	state, err := f.rollupRepo.State(ctx, models.ReportRollupDaily)
+	if err != nil {
+		return nil, NewBusinessError("REPORT_ROLLUP_REFRESH_FAILED", "Failed to get the reporting rollup watermark", err)
+	}
+	var through *time.Time
+	if state != nil {
+		day := rollupDay(state.RefreshedThrough)
+		through = &day
+	}
+	from, to := incrementalRollupRange(through, utils.UTCNow(), f.lookbackDays)
+	return f.refresh(ctx, from, to)
+}
+
+func (f *ReportRollupFlowImpl) Backfill(ctx context.Context, from, to time.Time) (*dto.ReportRollupRefreshSummary, error) {
+	fromDay, _ := utils.TehranDayBounds(from)
+	toDay, _ := utils.TehranDayBounds(to)
+	if toDay.Before(fromDay) {
+		return nil, NewBusinessError("VALIDATION_ERROR", "from must not be after to", ErrStartDateAfterEndDate)
+	}
+	today, _ := utils.TehranDayBounds(utils.UTCNow())
+	if toDay.After(today) {
+		toDay = today
+	}
+	return f.refresh(ctx, fromDay, toDay)
+}
+
+func (f *ReportRollupFlowImpl) RefreshOnDemand(ctx context.Context, req *dto.AdminReportRollupRefreshRequest) (*dto.ReportRollupRefreshSummary, error) {
+	from, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(req.From), utils.TehranLocation())
+	if err != nil {
+		return nil, NewBusinessError("VALIDATION_ERROR", "Invalid from date format", err)
+	}
+	to, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(req.To), utils.TehranLocation())
+	if err != nil {
+		return nil, NewBusinessError("VALIDATION_ERROR", "Invalid to date format", err)
+	}
+	if to.Sub(from) >= reportRollupOnDemandMaxDays*24*time.Hour {
+		return nil, NewBusinessError("VALIDATION_ERROR", "At most 31 days can be refreshed at once", nil)
+	}
+	return f.Backfill(ctx, from, to)
+}
+
+// refresh re-rolls the Tehran days [fromDay, toDay] and then the months they
+// fall in, and advances the watermark to toDay
+func (f *ReportRollupFlowImpl) refresh(ctx context.Context, fromDay, toDay time.Time) (*dto.ReportRollupRefreshSummary, error) {
+	summary := &dto.ReportRollupRefreshSummary{
+		From: fromDay.Format("2006-01-02"),
+		To:   toDay.Format("2006-01-02"),
+	}
+	release, ok, err := f.rollupRepo.TryLockRefresh(ctx)
+	if err != nil {
+		return nil, NewBusinessError("REPORT_ROLLUP_REFRESH_FAILED", "Failed to lock the reporting rollups", err)
+	}
+	if !ok {
+		summary.Skipped = true
+		return summary, nil
+	}
+	defer release()
+
+	now := utils.UTCNow()
+	for day := fromDay; !day.After(toDay); day = day.AddDate(0, 0, 1) {
+		if err := f.rollupRepo.RefreshDay(ctx, day, now); err != nil {
+			return nil, NewBusinessError("REPORT_ROLLUP_REFRESH_FAILED", "Failed to refresh the daily reporting rollups of "+day.Format("2006-01-02"), err)
+		}
+		summary.Days++
+	}
+	for _, month := range rollupMonths(fromDay, toDay) {
+		if err := f.rollupRepo.RefreshMonth(ctx, month, now); err != nil {
+			return nil, NewBusinessError("REPORT_ROLLUP_REFRESH_FAILED", "Failed to refresh the monthly usage rollup of "+month.Format("2006-01"), err)
+		}
+		summary.Months++
+	}
+
+	if err := f.rollupRepo.AdvanceState(ctx, models.ReportRollupDaily, toDay, now); err != nil {
+		return nil, NewBusinessError("REPORT_ROLLUP_REFRESH_FAILED", "Failed to advance the reporting rollup watermark", err)
+	}
+	state, err := f.rollupRepo.State(ctx, models.ReportRollupDaily)
+	if err == nil && state != nil {
+		summary.RefreshedThrough = state.RefreshedThrough.Format("2006-01-02")
+	}
+	return summary, nil
+}
+
+// incrementalRollupRange returns the Tehran days an incremental refresh
+// re-rolls. A run on the day already refreshed through only re-rolls that
+// day; the first run of a later day goes back lookbackDays before the first
+// day not yet refreshed.
+func incrementalRollupRange(refreshedThrough *time.Time, now time.Time, lookbackDays int) (time.Time, time.Time) {
+	today, _ := utils.TehranDayBounds(now)
+	if refreshedThrough == nil {
+		return today.AddDate(0, 0, -lookbackDays), today
+	}
+	next := refreshedThrough.AddDate(0, 0, 1)
+	if next.After(today) {
+		return today, today
+	}
+	return next.AddDate(0, 0, -lookbackDays), today
+}
+
+// rollupMonths returns the first Tehran day of every month touching [fromDay, toDay]
+func rollupMonths(fromDay, toDay time.Time) []time.Time {
+	months := make([]time.Time, 0, 1)
+	month, _ := utils.TehranMonthBounds(fromDay)
+	for !month.After(toDay) {
+		months = append(months, month)
+		month = month.AddDate(0, 1, 0)
+	}
+	return months
+}
+
+// rollupDay returns the Tehran midnight of a DATE column value
+func rollupDay(d time.Time) time.Time {
+	return time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, utils.TehranLocation())
+}
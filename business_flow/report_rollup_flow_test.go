@@ -0,0 +1,72 @@
+package businessflow
+
+import (
+	"testing"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+)
+
+func TestIncrementalRollupRange(t *testing.T) {
+	t.Parallel()
+
+	loc := utils.TehranLocation()
+	day := func(d int) time.Time { return time.Date(2026, 10, d, 0, 0, 0, 0, loc) }
+	// 21:00 UTC is already the 17th in Tehran
+	now := time.Date(2026, 10, 16, 21, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name             string
+		refreshedThrough *time.Time
+		wantFrom         time.Time
+	}{
+		{"first refresh", nil, day(14)},
+		{"later run of the same day", utils.ToPtr(day(17)), day(17)},
+		{"first run of the day", utils.ToPtr(day(16)), day(14)},
+		{"after a gap", utils.ToPtr(day(10)), day(8)},
+	}
+	for _, tc := range cases {
+		from, to := incrementalRollupRange(tc.refreshedThrough, now, 3)
+		if !from.Equal(tc.wantFrom) || !to.Equal(day(17)) {
+			t.Errorf("%s: range = %v - %v, want %v - %v", tc.name, from, to, tc.wantFrom, day(17))
+		}
+	}
+}
+
+func TestRollupMonths(t *testing.T) {
+	t.Parallel()
+
+	loc := utils.TehranLocation()
+	months := rollupMonths(time.Date(2026, 9, 28, 0, 0, 0, 0, loc), time.Date(2026, 11, 1, 0, 0, 0, 0, loc))
+	if len(months) != 3 {
+		t.Fatalf("months = %v, want September to November", months)
+	}
+	for i, want := range []time.Month{time.September, time.October, time.November} {
+		if months[i].Month() != want || months[i].Day() != 1 {
+			t.Errorf("months[%d] = %v, want the first of %v", i, months[i], want)
+		}
+	}
+}
+
+func TestParseRollupDayRange(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 10, 16, 21, 0, 0, 0, time.UTC)
+	from, to, err := parseRollupDayRange(nil, nil, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if to.Format("2006-01-02") != "2026-10-17" || from.Format("2006-01-02") != "2026-09-18" {
+		t.Fatalf("range = %v - %v, want the 30 days through 2026-10-17", from, to)
+	}
+
+	for name, bounds := range map[string][2]*string{
+		"invalid":  {utils.ToPtr("2026/09/01"), nil},
+		"reversed": {utils.ToPtr("2026-10-02"), utils.ToPtr("2026-10-01")},
+		"too long": {utils.ToPtr("2025-01-01"), utils.ToPtr("2026-10-01")},
+	} {
+		if _, _, err := parseRollupDayRange(bounds[0], bounds[1], now); err == nil {
+			t.Errorf("%s: want an error", name)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package businessflow
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/config"
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/repository"
+)
+
+// Sources of a configuration reload, recorded in its audit entry
+const (
+	ConfigReloadSourceAdmin  = "admin_api"
+	ConfigReloadSourceSignal = "sighup"
+)
+
+// RuntimeConfigFlow shows and reloads the settings that can change without a
+// restart
+type RuntimeConfigFlow interface {
+	GetRuntimeConfig(ctx context.Context) (*dto.AdminRuntimeConfigResponse, error)
+	ReloadRuntimeConfig(ctx context.Context, source string) (*dto.AdminReloadRuntimeConfigResponse, error)
+}
+
+type RuntimeConfigFlowImpl struct {
+	runtime   *config.Runtime
+	auditRepo repository.AuditLogRepository
+}
+
+func NewRuntimeConfigFlow(runtime *config.Runtime, auditRepo repository.AuditLogRepository) RuntimeConfigFlow {
+	return &RuntimeConfigFlowImpl{
+		runtime:   runtime,
+		auditRepo: auditRepo,
+	}
+}
+
+func (f *RuntimeConfigFlowImpl) GetRuntimeConfig(ctx context.Context) (*dto.AdminRuntimeConfigResponse, error) {
+	return &dto.AdminRuntimeConfigResponse{Settings: runtimeSettingsDTO(f.runtime.Settings())}, nil
+}
+
+// ReloadRuntimeConfig reloads the configuration and records the reload with
+// its source, the admin who asked for it if any, and every changed setting's
+// old and new value. An invalid configuration is rejected as a whole with
+// CONFIG_INVALID and the settings in effect are kept.
+func (f *RuntimeConfigFlowImpl) ReloadRuntimeConfig(ctx context.Context, source string) (*dto.AdminReloadRuntimeConfigResponse, error) {
+	changes, err := f.runtime.Reload()
+	if err != nil {
+		logAdminAction(ctx, f.auditRepo, models.AuditActionConfigReloaded, "Configuration reload failed", false, nil, map[string]any{
+			"source": source,
+		}, err)
+		var verr *config.ValidationError
+		if errors.As(err, &verr) {
+			return nil, NewBusinessError("CONFIG_INVALID", "configuration is invalid", err)
+		}
+		return nil, NewBusinessError("CONFIG_RELOAD_FAILED", "failed to reload configuration", err)
+	}
+
+	for _, change := range changes {
+		log.Printf("config reload (%s): %s changed from %q to %q", source, change.Key, change.Old, change.New)
+	}
+	logAdminAction(ctx, f.auditRepo, models.AuditActionConfigReloaded, "Configuration reloaded", true, nil, map[string]any{
+		"source":  source,
+		"changes": changes,
+	}, nil)
+
+	items := make([]dto.AdminRuntimeSettingChange, 0, len(changes))
+	for _, change := range changes {
+		items = append(items, dto.AdminRuntimeSettingChange{Key: change.Key, Old: change.Old, New: change.New})
+	}
+	return &dto.AdminReloadRuntimeConfigResponse{
+		Changes:  items,
+		Settings: runtimeSettingsDTO(f.runtime.Settings()),
+	}, nil
+}
+
+func runtimeSettingsDTO(settings config.RuntimeSettings) []dto.AdminRuntimeSetting {
+	values := settings.Values()
+	items := make([]dto.AdminRuntimeSetting, 0, len(values))
+	for _, value := range values {
+		items = append(items, dto.AdminRuntimeSetting{Key: value.Key, Value: value.Value})
+	}
+	return items
+}
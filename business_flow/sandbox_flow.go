@@ -0,0 +1,323 @@
+package businessflow
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/repository"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// SandboxFlow manages sandbox accounts, which integrators use to try the API
+// end to end without sending real messages or moving real money. Campaigns
+// of a sandbox account are flagged at creation and executed by the
+// schedulers against a mock provider; its wallet is funded with test money
+// only and every transaction it writes is flagged so reports skip it.
+type SandboxFlow interface {
+	GetStatus(ctx context.Context, customerID uint) (*dto.SandboxStatusResponse, error)
+	TopUp(ctx context.Context, req *dto.SandboxTopUpRequest, metadata *ClientMetadata) (*dto.SandboxTopUpResponse, error)
+	Purge(ctx context.Context, customerID uint, metadata *ClientMetadata) (*dto.SandboxPurgeResponse, error)
+	SetCustomerSandbox(ctx context.Context, req *dto.AdminSetCustomerSandboxRequest) (*dto.AdminSetCustomerSandboxResponse, error)
+}
+
+type SandboxFlowImpl struct {
+	db                  *gorm.DB
+	customerRepo        repository.CustomerRepository
+	walletRepo          repository.WalletRepository
+	balanceSnapshotRepo repository.BalanceSnapshotRepository
+	transactionRepo     repository.TransactionRepository
+	campaignRepo        repository.CampaignRepository
+	auditRepo           repository.AuditLogRepository
+}
+
+func NewSandboxFlow(
+	db *gorm.DB,
+	customerRepo repository.CustomerRepository,
+	walletRepo repository.WalletRepository,
+	balanceSnapshotRepo repository.BalanceSnapshotRepository,
+	transactionRepo repository.TransactionRepository,
+	campaignRepo repository.CampaignRepository,
+	auditRepo repository.AuditLogRepository,
+) SandboxFlow {
+	return &SandboxFlowImpl{
+		db:                  db,
+		customerRepo:        customerRepo,
+		walletRepo:          walletRepo,
+		balanceSnapshotRepo: balanceSnapshotRepo,
+		transactionRepo:     transactionRepo,
+		campaignRepo:        campaignRepo,
+		auditRepo:           auditRepo,
+	}
+}
+
+// GetStatus reports whether the customer is a sandbox account and its test
+// balance
+func (f *SandboxFlowImpl) GetStatus(ctx context.Context, customerID uint) (*dto.SandboxStatusResponse, error) {
+	customer, err := getCustomer(ctx, f.customerRepo, customerID)
+	if err != nil {
+		return nil, NewBusinessError("GET_SANDBOX_STATUS_FAILED", "Failed to find customer", err)
+	}
+	res := &dto.SandboxStatusResponse{
+		Message: "Sandbox mode is off",
+		Enabled: customer.InSandbox(),
+	}
+	if !res.Enabled {
+		return res, nil
+	}
+
+	wallet, err := getWallet(ctx, f.walletRepo, customerID)
+	if err != nil {
+		return nil, NewBusinessError("GET_SANDBOX_STATUS_FAILED", "Failed to find wallet", err)
+	}
+	latest, err := getLatestBalanceSnapshot(ctx, f.walletRepo, wallet.ID)
+	if err != nil {
+		return nil, NewBusinessError("GET_SANDBOX_STATUS_FAILED", "Failed to get wallet balance", err)
+	}
+	res.Message = "Sandbox mode is on"
+	res.FreeBalance = latest.FreeBalance
+	return res, nil
+}
+
+// TopUp credits test money to the free balance of a sandbox wallet. It is
+// the only way a sandbox account is funded, as real payments are refused.
+func (f *SandboxFlowImpl) TopUp(ctx context.Context, req *dto.SandboxTopUpRequest, metadata *ClientMetadata) (*dto.SandboxTopUpResponse, error) {
+	if req == nil {
+		return nil, NewBusinessError("VALIDATION_ERROR", "Request is required", nil)
+	}
+	customer, err := getCustomer(ctx, f.customerRepo, req.CustomerID)
+	if err != nil {
+		return nil, NewBusinessError("SANDBOX_TOP_UP_FAILED", "Failed to find customer", err)
+	}
+	if !customer.InSandbox() {
+		return nil, NewBusinessError("SANDBOX_NOT_ENABLED", "Customer is not a sandbox account", ErrSandboxNotEnabled)
+	}
+
+	var (
+		tx      *models.Transaction
+		newSnap *models.BalanceSnapshot
+	)
+	err = withVersionRetry(ctx, f.db, func(txCtx context.Context) error {
+		wallet, err := getWallet(txCtx, f.walletRepo, customer.ID)
+		if err != nil {
+			return err
+		}
+		latest, err := getLatestBalanceSnapshot(txCtx, f.walletRepo, wallet.ID)
+		if err != nil {
+			return err
+		}
+
+		correlationID := uuid.New()
+		description := fmt.Sprintf("Sandbox wallet top-up of %d toman", req.Amount)
+		metaBytes, err := json.Marshal(map[string]any{
+			"source":    models.TransactionSourceSandboxTopUp,
+			"operation": "sandbox_top_up",
+		})
+		if err != nil {
+			return err
+		}
+
+		newFree := latest.FreeBalance + req.Amount
+		newSnap = &models.BalanceSnapshot{
+			UUID:               uuid.New(),
+			CorrelationID:      correlationID,
+			WalletID:           wallet.ID,
+			CustomerID:         customer.ID,
+			FreeBalance:        newFree,
+			FrozenBalance:      latest.FrozenBalance,
+			LockedBalance:      latest.LockedBalance,
+			CreditBalance:      latest.CreditBalance,
+			SpentOnCampaign:    latest.SpentOnCampaign,
+			AgencyShareWithTax: latest.AgencyShareWithTax,
+			TotalBalance:       newFree + latest.CreditBalance + latest.FrozenBalance + latest.LockedBalance + latest.SpentOnCampaign + latest.AgencyShareWithTax,
+			Reason:             "sandbox_top_up",
+			Description:        description,
+			Metadata:           metaBytes,
+		}
+		if err := f.walletRepo.AdvanceVersion(txCtx, &wallet); err != nil {
+			return err
+		}
+		if err := f.balanceSnapshotRepo.Save(txCtx, newSnap); err != nil {
+			return err
+		}
+
+		beforeMap, err := latest.GetBalanceMap()
+		if err != nil {
+			return err
+		}
+		afterMap, err := newSnap.GetBalanceMap()
+		if err != nil {
+			return err
+		}
+		tx = &models.Transaction{
+			UUID:          uuid.New(),
+			CorrelationID: correlationID,
+			Type:          models.TransactionTypeCredit,
+			Status:        models.TransactionStatusCompleted,
+			Amount:        req.Amount,
+			Currency:      utils.TomanCurrency,
+			WalletID:      wallet.ID,
+			CustomerID:    customer.ID,
+			BalanceBefore: beforeMap,
+			BalanceAfter:  afterMap,
+			Description:   description,
+			Metadata:      metaBytes,
+		}
+		return f.transactionRepo.Save(txCtx, tx)
+	})
+	if err != nil {
+		errMsg := err.Error()
+		_ = createAuditLog(ctx, f.auditRepo, &customer, models.AuditActionSandboxWalletToppedUp, "Sandbox wallet top-up failed", false, &errMsg, metadata)
+		return nil, NewBusinessError("SANDBOX_TOP_UP_FAILED", "Failed to top up sandbox wallet", err)
+	}
+	_ = createAuditLog(ctx, f.auditRepo, &customer, models.AuditActionSandboxWalletToppedUp, fmt.Sprintf("Sandbox wallet topped up with %d toman", req.Amount), true, nil, metadata)
+
+	return &dto.SandboxTopUpResponse{
+		Message:         "Sandbox wallet topped up",
+		TransactionUUID: tx.UUID.String(),
+		FreeBalance:     newSnap.FreeBalance,
+	}, nil
+}
+
+// Purge deletes the sandbox campaigns and transactions of a sandbox account
+// and empties its wallet. The account stays in sandbox mode.
+func (f *SandboxFlowImpl) Purge(ctx context.Context, customerID uint, metadata *ClientMetadata) (*dto.SandboxPurgeResponse, error) {
+	customer, err := getCustomer(ctx, f.customerRepo, customerID)
+	if err != nil {
+		return nil, NewBusinessError("SANDBOX_PURGE_FAILED", "Failed to find customer", err)
+	}
+	if !customer.InSandbox() {
+		return nil, NewBusinessError("SANDBOX_NOT_ENABLED", "Customer is not a sandbox account", ErrSandboxNotEnabled)
+	}
+
+	var res *dto.SandboxPurgeResponse
+	err = withVersionRetry(ctx, f.db, func(txCtx context.Context) error {
+		var err error
+		res, err = f.purge(txCtx, customer.ID)
+		return err
+	})
+	if err != nil {
+		errMsg := err.Error()
+		_ = createAuditLog(ctx, f.auditRepo, &customer, models.AuditActionSandboxPurged, "Sandbox purge failed", false, &errMsg, metadata)
+		return nil, NewBusinessError("SANDBOX_PURGE_FAILED", "Failed to purge sandbox data", err)
+	}
+	_ = createAuditLog(ctx, f.auditRepo, &customer, models.AuditActionSandboxPurged, fmt.Sprintf("Sandbox purged: %d campaigns, %d transactions", res.Campaigns, res.Transactions), true, nil, metadata)
+
+	res.Message = "Sandbox data purged"
+	return res, nil
+}
+
+// SetCustomerSandbox turns sandbox mode of a customer on or off. Only
+// customers without campaigns or wallet transactions can be switched on, so
+// real and test money never share a wallet; switching off purges the test
+// data first.
+func (f *SandboxFlowImpl) SetCustomerSandbox(ctx context.Context, req *dto.AdminSetCustomerSandboxRequest) (*dto.AdminSetCustomerSandboxResponse, error) {
+	if req == nil {
+		return nil, NewBusinessError("VALIDATION_ERROR", "Request is required", nil)
+	}
+	meta := map[string]any{"enabled": req.Enabled}
+	customer, err := getCustomer(ctx, f.customerRepo, req.CustomerID)
+	if err != nil {
+		logAdminAction(ctx, f.auditRepo, models.AuditActionAdminCustomerSandboxUpdate, "Customer sandbox update failed", false, &req.CustomerID, meta, err)
+		return nil, NewBusinessError("SET_CUSTOMER_SANDBOX_FAILED", "Failed to find customer", err)
+	}
+
+	res := &dto.AdminSetCustomerSandboxResponse{CustomerID: customer.ID, Enabled: req.Enabled}
+	err = withVersionRetry(ctx, f.db, func(txCtx context.Context) error {
+		if req.Enabled == customer.InSandbox() {
+			return nil
+		}
+		if req.Enabled {
+			hasTransactions, err := f.transactionRepo.Exists(txCtx, models.TransactionFilter{CustomerID: &customer.ID})
+			if err != nil {
+				return err
+			}
+			hasCampaigns, err := f.campaignRepo.Exists(txCtx, models.CampaignFilter{CustomerID: &customer.ID})
+			if err != nil {
+				return err
+			}
+			if hasTransactions || hasCampaigns {
+				return ErrSandboxCustomerHasHistory
+			}
+		} else {
+			purged, err := f.purge(txCtx, customer.ID)
+			if err != nil {
+				return err
+			}
+			res.Purged = purged
+		}
+		return f.customerRepo.UpdateSandbox(txCtx, customer.ID, req.Enabled)
+	})
+	if err != nil {
+		logAdminAction(ctx, f.auditRepo, models.AuditActionAdminCustomerSandboxUpdate, "Customer sandbox update failed", false, &customer.ID, meta, err)
+		if IsSandboxCustomerHasHistory(err) {
+			return nil, NewBusinessError("SANDBOX_CUSTOMER_HAS_HISTORY", "Customer already has campaigns or wallet transactions", err)
+		}
+		return nil, NewBusinessError("SET_CUSTOMER_SANDBOX_FAILED", "Failed to update customer sandbox mode", err)
+	}
+
+	res.Message = "Sandbox mode turned off"
+	if req.Enabled {
+		res.Message = "Sandbox mode turned on"
+	}
+	if res.Purged != nil {
+		meta["purged_campaigns"] = res.Purged.Campaigns
+		meta["purged_transactions"] = res.Purged.Transactions
+	}
+	logAdminAction(ctx, f.auditRepo, models.AuditActionAdminCustomerSandboxUpdate, res.Message, true, &customer.ID, meta, nil)
+	return res, nil
+}
+
+// purge deletes the customer's sandbox campaigns and transactions and writes
+// an empty balance snapshot. It must run inside a transaction.
+func (f *SandboxFlowImpl) purge(ctx context.Context, customerID uint) (*dto.SandboxPurgeResponse, error) {
+	campaigns, err := f.campaignRepo.DeleteSandboxByCustomerID(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+	transactions, err := f.transactionRepo.DeleteSandboxByCustomerID(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	wallet, err := getWallet(ctx, f.walletRepo, customerID)
+	if err != nil {
+		return nil, err
+	}
+	latest, err := getLatestBalanceSnapshot(ctx, f.walletRepo, wallet.ID)
+	if err != nil {
+		return nil, err
+	}
+	if latest.TotalBalance != 0 || latest.FreeBalance != 0 || latest.CreditBalance != 0 {
+		metaBytes, err := json.Marshal(map[string]any{
+			"source":                models.TransactionSourceSandboxTopUp,
+			"operation":             "sandbox_purge",
+			"purged_campaigns":      campaigns,
+			"purged_transactions":   transactions,
+			"previous_free_balance": latest.FreeBalance,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := f.walletRepo.AdvanceVersion(ctx, &wallet); err != nil {
+			return nil, err
+		}
+		if err := f.balanceSnapshotRepo.Save(ctx, &models.BalanceSnapshot{
+			UUID:          uuid.New(),
+			CorrelationID: uuid.New(),
+			WalletID:      wallet.ID,
+			CustomerID:    customerID,
+			Reason:        "sandbox_purge",
+			Description:   "Sandbox wallet emptied by purge",
+			Metadata:      metaBytes,
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	return &dto.SandboxPurgeResponse{Campaigns: campaigns, Transactions: transactions}, nil
+}
@@ -0,0 +1,186 @@
+package businessflow
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+)
+
+// GetCustomerSendingQuota returns a customer's sending limits and their
+// consumption in the current Tehran day and month
+func (f *AdminCustomerManagementFlowImpl) GetCustomerSendingQuota(ctx context.Context, customerID uint) (*dto.AdminCustomerSendingQuotaResponse, error) {
+	if customerID == 0 {
+		return nil, NewBusinessError("VALIDATION_ERROR", "Invalid customer_id", nil)
+	}
+	customer, err := f.customerRepo.ByID(ctx, customerID)
+	if err != nil {
+		return nil, NewBusinessError("GET_CUSTOMER_SENDING_QUOTA_FAILED", "Failed to get customer", err)
+	}
+	if customer == nil {
+		return nil, NewBusinessError("CUSTOMER_NOT_FOUND", "Customer not found", ErrCustomerNotFound)
+	}
+
+	quota, err := f.sendingQuotaRepo.ByCustomerID(ctx, customerID)
+	if err != nil {
+		return nil, NewBusinessError("GET_CUSTOMER_SENDING_QUOTA_FAILED", "Failed to get sending quota", err)
+	}
+	return f.customerSendingQuotaResponse(ctx, customerID, quota, "Customer sending quota retrieved successfully")
+}
+
+// SetCustomerSendingQuota replaces a customer's daily and monthly sending
+// limits and SMS frequency cap. New limits only affect campaigns finalized or
+// sent afterwards. Companies whose KYC is not verified cannot get limits
+// above the unverified cap.
+func (f *AdminCustomerManagementFlowImpl) SetCustomerSendingQuota(ctx context.Context, req *dto.AdminSetCustomerSendingQuotaRequest) (*dto.AdminCustomerSendingQuotaResponse, error) {
+	if req == nil || req.CustomerID == 0 {
+		return nil, NewBusinessError("VALIDATION_ERROR", "Invalid request", nil)
+	}
+	if req.DailyLimit != nil && req.MonthlyLimit != nil && *req.DailyLimit > *req.MonthlyLimit {
+		return nil, NewBusinessError("SENDING_QUOTA_INVALID", ErrSendingQuotaInvalid.Error(), ErrSendingQuotaInvalid)
+	}
+	if req.FrequencyCapHours != nil && (*req.FrequencyCapHours == 0 || *req.FrequencyCapHours > models.MaxFrequencyCapHours) {
+		return nil, NewBusinessError("SENDING_QUOTA_INVALID", ErrFrequencyCapInvalid.Error(), ErrFrequencyCapInvalid)
+	}
+	customer, err := f.customerRepo.ByID(ctx, req.CustomerID)
+	if err != nil {
+		return nil, NewBusinessError("SET_CUSTOMER_SENDING_QUOTA_FAILED", "Failed to get customer", err)
+	}
+	if customer == nil {
+		return nil, NewBusinessError("CUSTOMER_NOT_FOUND", "Customer not found", ErrCustomerNotFound)
+	}
+	if exceedsKYCCap(customer, req.DailyLimit, req.MonthlyLimit, f.kycConfig) {
+		return nil, NewBusinessError("KYC_VERIFICATION_REQUIRED", ErrSendingQuotaAboveKYCCap.Error(), ErrSendingQuotaAboveKYCCap)
+	}
+
+	quota := &models.CustomerSendingQuota{
+		CustomerID:        req.CustomerID,
+		DailyLimit:        req.DailyLimit,
+		MonthlyLimit:      req.MonthlyLimit,
+		FrequencyCapHours: req.FrequencyCapHours,
+		UpdatedAt:         utils.UTCNow(),
+	}
+	if adminID, ok := adminIDFromContext(ctx); ok {
+		quota.AdminID = &adminID
+	}
+	meta := map[string]any{
+		"daily_limit":         req.DailyLimit,
+		"monthly_limit":       req.MonthlyLimit,
+		"frequency_cap_hours": req.FrequencyCapHours,
+	}
+	if err := f.sendingQuotaRepo.Upsert(ctx, quota); err != nil {
+		logAdminAction(ctx, f.auditRepo, models.AuditActionAdminCustomerSendingQuotaUpdate, "Admin sending quota update failed", false, &req.CustomerID, meta, err)
+		return nil, NewBusinessError("SET_CUSTOMER_SENDING_QUOTA_FAILED", "Failed to update sending quota", err)
+	}
+	logAdminAction(ctx, f.auditRepo, models.AuditActionAdminCustomerSendingQuotaUpdate, "Admin updated customer sending quota", true, &req.CustomerID, meta, nil)
+
+	return f.customerSendingQuotaResponse(ctx, req.CustomerID, quota, "Customer sending quota updated successfully")
+}
+
+func (f *AdminCustomerManagementFlowImpl) customerSendingQuotaResponse(ctx context.Context, customerID uint, quota *models.CustomerSendingQuota, message string) (*dto.AdminCustomerSendingQuotaResponse, error) {
+	usage, err := f.sendingQuotaRepo.Usage(ctx, customerID, utils.UTCNow(), 0)
+	if err != nil {
+		return nil, NewBusinessError("GET_CUSTOMER_SENDING_QUOTA_FAILED", "Failed to get sending quota usage", err)
+	}
+	resp := &dto.AdminCustomerSendingQuotaResponse{
+		Message:    message,
+		CustomerID: customerID,
+		Usage:      buildSendingQuotaUsage(quota, usage),
+	}
+	if quota != nil && !quota.UpdatedAt.IsZero() {
+		resp.UpdatedAt = utils.ToPtr(quota.UpdatedAt.Format(time.RFC3339))
+	}
+	return resp, nil
+}
+
+// GetSendingQuotaUsage returns the customer's effective sending limits,
+// including the unverified KYC cap, and their consumption in the current
+// Tehran day and month
+func (s *CampaignFlowImpl) GetSendingQuotaUsage(ctx context.Context, customerID uint) (*dto.GetSendingQuotaUsageResponse, error) {
+	customer, err := s.customerRepo.ByID(ctx, customerID)
+	if err != nil {
+		return nil, NewBusinessError("GET_SENDING_QUOTA_FAILED", "Failed to get customer", err)
+	}
+	quota, err := s.sendingQuotaRepo.ByCustomerID(ctx, customerID)
+	if err != nil {
+		return nil, NewBusinessError("GET_SENDING_QUOTA_FAILED", "Failed to get sending quota", err)
+	}
+	quota, _ = unverifiedQuota(customer, quota, s.kycConfig)
+	usage, err := s.sendingQuotaRepo.Usage(ctx, customerID, utils.UTCNow(), 0)
+	if err != nil {
+		return nil, NewBusinessError("GET_SENDING_QUOTA_FAILED", "Failed to get sending quota usage", err)
+	}
+	return &dto.GetSendingQuotaUsageResponse{
+		Message: "Sending quota retrieved successfully",
+		Usage:   buildSendingQuotaUsage(quota, usage),
+	}, nil
+}
+
+// checkSendingQuota rejects finalizing a campaign whose audience does not fit
+// in the quota left for the day and month it is scheduled in. Campaigns
+// already sent and campaigns awaiting approval or sending both count as used.
+// Concurrent finalizes may still overbook; the schedulers truncate the
+// audience at send time in that case. Companies whose KYC is not verified are
+// held to the unverified cap as well.
+func (s *CampaignFlowImpl) checkSendingQuota(ctx context.Context, customer *models.Customer, campaign models.Campaign, numAudience uint64) error {
+	quota, err := s.sendingQuotaRepo.ByCustomerID(ctx, campaign.CustomerID)
+	if err != nil {
+		return NewBusinessError("SENDING_QUOTA_FETCH_FAILED", "Failed to get sending quota", err)
+	}
+	quota, capped := unverifiedQuota(customer, quota, s.kycConfig)
+	if quota.IsUnlimited() {
+		return nil
+	}
+
+	at := utils.UTCNow()
+	if campaign.Spec.ScheduleAt != nil && !campaign.Spec.ScheduleAt.IsZero() {
+		at = *campaign.Spec.ScheduleAt
+	}
+	usage, err := s.sendingQuotaRepo.Usage(ctx, campaign.CustomerID, at, campaign.ID)
+	if err != nil {
+		return NewBusinessError("SENDING_QUOTA_FETCH_FAILED", "Failed to get sending quota usage", err)
+	}
+	remaining, _ := quota.Remaining(usage.DailySent+usage.DailyReserved, usage.MonthlySent+usage.MonthlyReserved)
+	if numAudience > remaining {
+		msg := fmt.Sprintf("Campaign audience of %d exceeds the remaining sending quota of %d", numAudience, remaining)
+		if capped {
+			msg += "; verify your company documents to raise it"
+		}
+		return NewBusinessError("SENDING_QUOTA_EXCEEDED", msg, ErrSendingQuotaExceeded)
+	}
+	return nil
+}
+
+func buildSendingQuotaUsage(quota *models.CustomerSendingQuota, usage *models.SendingQuotaUsage) dto.SendingQuotaUsage {
+	out := dto.SendingQuotaUsage{
+		DailySent:       usage.DailySent,
+		MonthlySent:     usage.MonthlySent,
+		DailyReserved:   usage.DailyReserved,
+		MonthlyReserved: usage.MonthlyReserved,
+		DayStart:        usage.DayStart.Format(time.RFC3339),
+		MonthStart:      usage.MonthStart.Format(time.RFC3339),
+	}
+	if quota == nil {
+		return out
+	}
+	out.DailyLimit = quota.DailyLimit
+	out.MonthlyLimit = quota.MonthlyLimit
+	out.FrequencyCapHours = quota.FrequencyCapHours
+	out.DailyRemaining = remainingSendingQuota(quota.DailyLimit, usage.DailySent+usage.DailyReserved)
+	out.MonthlyRemaining = remainingSendingQuota(quota.MonthlyLimit, usage.MonthlySent+usage.MonthlyReserved)
+	return out
+}
+
+// remainingSendingQuota returns limit minus used, floored at zero; nil means unlimited
+func remainingSendingQuota(limit *uint64, used uint64) *uint64 {
+	if limit == nil {
+		return nil
+	}
+	if used >= *limit {
+		return utils.ToPtr(uint64(0))
+	}
+	return utils.ToPtr(*limit - used)
+}
@@ -0,0 +1,77 @@
+package businessflow
+
+import (
+	"testing"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+)
+
+func TestSendingQuotaRemaining(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name          string
+		quota         *models.CustomerSendingQuota
+		daily         uint64
+		monthly       uint64
+		wantRemaining uint64
+		wantLimited   bool
+	}{
+		{name: "no quota", quota: nil, wantLimited: false},
+		{name: "no limits", quota: &models.CustomerSendingQuota{}, wantLimited: false},
+		{name: "daily only", quota: &models.CustomerSendingQuota{DailyLimit: utils.ToPtr(uint64(100))}, daily: 30, monthly: 5000, wantRemaining: 70, wantLimited: true},
+		{name: "monthly is tighter", quota: &models.CustomerSendingQuota{DailyLimit: utils.ToPtr(uint64(100)), MonthlyLimit: utils.ToPtr(uint64(1000))}, daily: 10, monthly: 950, wantRemaining: 50, wantLimited: true},
+		{name: "exhausted", quota: &models.CustomerSendingQuota{DailyLimit: utils.ToPtr(uint64(100))}, daily: 130, wantRemaining: 0, wantLimited: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			remaining, limited := tc.quota.Remaining(tc.daily, tc.monthly)
+			if limited != tc.wantLimited {
+				t.Fatalf("expected limited=%v, got %v", tc.wantLimited, limited)
+			}
+			if limited && remaining != tc.wantRemaining {
+				t.Fatalf("expected remaining %d, got %d", tc.wantRemaining, remaining)
+			}
+		})
+	}
+}
+
+func TestBuildSendingQuotaUsage(t *testing.T) {
+	t.Parallel()
+
+	dayStart, _ := utils.TehranDayBounds(time.Date(2026, 3, 20, 22, 0, 0, 0, time.UTC))
+	usage := &models.SendingQuotaUsage{
+		DayStart:        dayStart,
+		DailySent:       40,
+		DailyReserved:   20,
+		MonthlySent:     900,
+		MonthlyReserved: 200,
+	}
+	quota := &models.CustomerSendingQuota{
+		DailyLimit:   utils.ToPtr(uint64(100)),
+		MonthlyLimit: utils.ToPtr(uint64(1000)),
+	}
+
+	got := buildSendingQuotaUsage(quota, usage)
+	if got.DailyRemaining == nil || *got.DailyRemaining != 40 {
+		t.Fatalf("expected daily remaining 40, got %v", got.DailyRemaining)
+	}
+	if got.MonthlyRemaining == nil || *got.MonthlyRemaining != 0 {
+		t.Fatalf("expected monthly remaining 0, got %v", got.MonthlyRemaining)
+	}
+	// 22:00 UTC is already the next day in Tehran
+	if got.DayStart != "2026-03-21T00:00:00+03:30" {
+		t.Fatalf("unexpected day start %s", got.DayStart)
+	}
+
+	unlimited := buildSendingQuotaUsage(nil, usage)
+	if unlimited.DailyLimit != nil || unlimited.DailyRemaining != nil || unlimited.MonthlyRemaining != nil {
+		t.Fatalf("expected unlimited usage, got %+v", unlimited)
+	}
+	if unlimited.DailySent != 40 || unlimited.MonthlyReserved != 200 {
+		t.Fatalf("unexpected counts %+v", unlimited)
+	}
+}
@@ -0,0 +1,32 @@
+package businessflow
+
+import (
+	"context"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/services"
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/gofiber/fiber/v3/log"
+)
+
+// activateSession mirrors a committed session into the session store. A
+// failure is only logged: the auth middleware falls back to the
+// customer_sessions table for sessions the store does not know.
+func activateSession(ctx context.Context, store services.SessionStore, session *models.CustomerSession) {
+	if store == nil || session == nil {
+		return
+	}
+	if err := store.Activate(ctx, session.CustomerID, session.SessionToken, session.ExpiresAt); err != nil {
+		log.Errorf("mirror session %d of customer %d: %v", session.ID, session.CustomerID, err)
+	}
+}
+
+// revokeCustomerSessions invalidates every mirrored session of a customer
+// after they were deactivated in the customer_sessions table
+func revokeCustomerSessions(ctx context.Context, store services.SessionStore, customerID uint) {
+	if store == nil {
+		return
+	}
+	if err := store.RevokeAll(ctx, customerID); err != nil {
+		log.Errorf("revoke sessions of customer %d: %v", customerID, err)
+	}
+}
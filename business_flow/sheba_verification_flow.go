@@ -0,0 +1,235 @@
+package businessflow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/app/services"
+	"github.com/amirphl/Yamata-no-Orochi/config"
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/repository"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/redis/go-redis/v9"
+)
+
+// ShebaVerificationFlow confirms with a bank inquiry that customers' Sheba
+// numbers belong to them
+type ShebaVerificationFlow interface {
+	VerifySheba(ctx context.Context, customerID uint, metadata *ClientMetadata) (*dto.ShebaVerificationResponse, error)
+	// RequireAgencySheba returns ErrAgencyShebaNotVerified unless the
+	// agency's Sheba number is verified; it always succeeds while no bank
+	// inquiry provider is configured
+	RequireAgencySheba(ctx context.Context, agencyID uint) error
+}
+
+type ShebaVerificationFlowImpl struct {
+	customerRepo repository.CustomerRepository
+	auditRepo    repository.AuditLogRepository
+	inquirer     services.ShebaInquirer
+	cacheCfg     config.CacheConfig
+	rc           *redis.Client
+	cacheTTL     time.Duration
+}
+
+// NewShebaVerificationFlow creates the flow; a nil inquirer turns
+// verification off
+func NewShebaVerificationFlow(
+	customerRepo repository.CustomerRepository,
+	auditRepo repository.AuditLogRepository,
+	inquirer services.ShebaInquirer,
+	cacheCfg config.CacheConfig,
+	rc *redis.Client,
+	cacheTTL time.Duration,
+) ShebaVerificationFlow {
+	return &ShebaVerificationFlowImpl{
+		customerRepo: customerRepo,
+		auditRepo:    auditRepo,
+		inquirer:     inquirer,
+		cacheCfg:     cacheCfg,
+		rc:           rc,
+		cacheTTL:     cacheTTL,
+	}
+}
+
+// VerifySheba looks up the owner of the customer's Sheba number and stores
+// whether it matches the customer
+func (f *ShebaVerificationFlowImpl) VerifySheba(ctx context.Context, customerID uint, metadata *ClientMetadata) (*dto.ShebaVerificationResponse, error) {
+	if f.inquirer == nil {
+		return nil, NewBusinessError("SHEBA_INQUIRY_NOT_CONFIGURED", ErrShebaInquiryNotConfigured.Error(), ErrShebaInquiryNotConfigured)
+	}
+	customer, err := getCustomer(ctx, f.customerRepo, customerID)
+	if err != nil {
+		return nil, NewBusinessError("VERIFY_SHEBA_FAILED", "Failed to verify Sheba number", err)
+	}
+	status, owner, at, err := f.verify(ctx, &customer, metadata)
+	if err != nil {
+		return nil, NewBusinessError("VERIFY_SHEBA_FAILED", "Failed to verify Sheba number", err)
+	}
+
+	message := "Sheba number verified"
+	switch status {
+	case models.ShebaOwnerMismatch:
+		message = "Sheba number belongs to someone else"
+	case models.ShebaInactive:
+		message = "Sheba number does not belong to an active bank account"
+	}
+	return &dto.ShebaVerificationResponse{
+		Message:    message,
+		Status:     string(status),
+		OwnerName:  owner,
+		VerifiedAt: &at,
+	}, nil
+}
+
+func (f *ShebaVerificationFlowImpl) RequireAgencySheba(ctx context.Context, agencyID uint) error {
+	if f.inquirer == nil {
+		return nil
+	}
+	agency, err := getAgency(ctx, f.customerRepo, agencyID)
+	if err != nil {
+		return err
+	}
+	if agency.ShebaStatus() == models.ShebaVerified {
+		return nil
+	}
+	// Earlier results are looked up again: the agency may have fixed its
+	// name since, and the inquiry itself is cached
+	status, _, _, err := f.verify(ctx, &agency, nil)
+	if err != nil {
+		return err
+	}
+	if status != models.ShebaVerified {
+		return fmt.Errorf("%w: %s", ErrAgencyShebaNotVerified, status)
+	}
+	return nil
+}
+
+// verify runs the bank inquiry of the customer's Sheba number and stores the
+// result. It is audited when it differs from the stored one.
+func (f *ShebaVerificationFlowImpl) verify(ctx context.Context, customer *models.Customer, metadata *ClientMetadata) (models.ShebaVerificationStatus, *string, time.Time, error) {
+	sheba, err := ValidateShebaNumber(customer.ShebaNumber)
+	if err != nil {
+		return "", nil, time.Time{}, err
+	}
+	inquiry, err := f.inquire(ctx, sheba)
+	if err != nil {
+		errMsg := err.Error()
+		_ = createAuditLog(ctx, f.auditRepo, customer, models.AuditActionShebaVerification, fmt.Sprintf("Bank inquiry of customer %d Sheba number failed", customer.ID), false, &errMsg, metadata)
+		return "", nil, time.Time{}, fmt.Errorf("%w: %v", ErrShebaInquiryUnavailable, err)
+	}
+
+	status := models.ShebaVerified
+	switch {
+	case !inquiry.Active:
+		status = models.ShebaInactive
+	case !shebaOwnerMatches(shebaAccountHolder(customer), inquiry.Owners):
+		status = models.ShebaOwnerMismatch
+	}
+	var owner *string
+	if len(inquiry.Owners) > 0 {
+		owner = utils.ToPtr(strings.Join(inquiry.Owners, ", "))
+	}
+
+	now := utils.UTCNow()
+	if err := f.customerRepo.SaveShebaVerification(ctx, customer.ID, sheba, status, owner, now); err != nil {
+		return "", nil, time.Time{}, err
+	}
+	if status != customer.ShebaStatus() {
+		desc := fmt.Sprintf("Customer %d Sheba number is %s", customer.ID, status)
+		var errMsg *string
+		if status != models.ShebaVerified && owner != nil {
+			errMsg = utils.ToPtr(fmt.Sprintf("bank reported owner %q", *owner))
+		}
+		_ = createAuditLog(ctx, f.auditRepo, customer, models.AuditActionShebaVerification, desc, status == models.ShebaVerified, errMsg, metadata)
+	}
+	return status, owner, now, nil
+}
+
+// inquire returns the bank account of sheba, reusing the result of an
+// earlier inquiry for the cache TTL since every inquiry is billed
+func (f *ShebaVerificationFlowImpl) inquire(ctx context.Context, sheba string) (*services.ShebaInquiry, error) {
+	key := redisKey(f.cacheCfg, "sheba_inquiry:"+sheba)
+	if f.rc != nil {
+		if bs, err := f.rc.Get(ctx, key).Bytes(); err == nil {
+			var cached services.ShebaInquiry
+			if json.Unmarshal(bs, &cached) == nil {
+				return &cached, nil
+			}
+		}
+	}
+
+	inquiry, err := f.inquirer.InquireSheba(ctx, sheba)
+	if errors.Is(err, services.ErrShebaUnknown) {
+		inquiry, err = &services.ShebaInquiry{Sheba: sheba}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if f.rc != nil {
+		if bs, err := json.Marshal(inquiry); err == nil {
+			_ = f.rc.Set(ctx, key, bs, f.cacheTTL).Err()
+		}
+	}
+	return inquiry, nil
+}
+
+// shebaAccountHolder is the name the bank account must be in: the company
+// for company and agency accounts, the representative otherwise
+func shebaAccountHolder(c *models.Customer) string {
+	if c.RequiresCompanyFields() && c.CompanyName != nil && strings.TrimSpace(*c.CompanyName) != "" {
+		return *c.CompanyName
+	}
+	return c.RepresentativeFirstName + " " + c.RepresentativeLastName
+}
+
+// shebaOwnerMatches reports whether one of the owners the bank reported is
+// the account holder
+func shebaOwnerMatches(holder string, owners []string) bool {
+	want := normalizeOwnerName(holder)
+	if want == "" {
+		return false
+	}
+	for _, owner := range owners {
+		if normalizeOwnerName(owner) == want {
+			return true
+		}
+	}
+	return false
+}
+
+// legalFormWords are dropped from company names; banks and customers write
+// them inconsistently, if at all
+var legalFormWords = []string{"شرکت", "سهامیخاص", "سهامیعام", "بامسئولیتمحدود", "مسئولیتمحدود"}
+
+// normalizeOwnerName folds the spellings of a name banks and customers use:
+// Arabic and Persian letter forms, spacing and zero-width non-joiners,
+// punctuation and the legal form of companies
+func normalizeOwnerName(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		switch r {
+		case 'ي', 'ى':
+			r = 'ی'
+		case 'ك':
+			r = 'ک'
+		case 'ة', 'ۀ':
+			r = 'ه'
+		case 'أ', 'إ', 'آ':
+			r = 'ا'
+		}
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	out := b.String()
+	for _, w := range legalFormWords {
+		out = strings.ReplaceAll(out, w, "")
+	}
+	return out
+}
@@ -0,0 +1,127 @@
+package businessflow
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/services"
+	"github.com/amirphl/Yamata-no-Orochi/config"
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/repository"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+)
+
+type shebaCustomerRepoStub struct {
+	repository.CustomerRepository
+	customer *models.Customer
+	saved    models.ShebaVerificationStatus
+}
+
+func (r *shebaCustomerRepoStub) ByID(_ context.Context, id uint) (*models.Customer, error) {
+	if r.customer == nil || r.customer.ID != id {
+		return nil, nil
+	}
+	copy := *r.customer
+	return &copy, nil
+}
+
+func (r *shebaCustomerRepoStub) SaveShebaVerification(_ context.Context, _ uint, _ string, status models.ShebaVerificationStatus, _ *string, _ time.Time) error {
+	r.saved = status
+	return nil
+}
+
+type shebaInquirerStub struct {
+	inquiry *services.ShebaInquiry
+	err     error
+	calls   int
+}
+
+func (s *shebaInquirerStub) InquireSheba(_ context.Context, sheba string) (*services.ShebaInquiry, error) {
+	s.calls++
+	return s.inquiry, s.err
+}
+
+func newTestAgency() *models.Customer {
+	return &models.Customer{
+		ID:          9,
+		IsActive:    utils.ToPtr(true),
+		AccountType: models.AccountType{TypeName: models.AccountTypeMarketingAgency},
+		CompanyName: utils.ToPtr("شرکت آلفا"),
+		ShebaNumber: utils.ToPtr("IR820540102680020817909002"),
+	}
+}
+
+func TestNormalizeOwnerName(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		holder string
+		owners []string
+		want   bool
+	}{
+		{"علی رضایی", []string{"علي  رضايي"}, true},
+		{"شرکت آلفا سهامی خاص", []string{"آلفا (سهامی‌خاص)"}, true},
+		{"Acme Ltd", []string{"acme ltd."}, true},
+		{"علی رضایی", []string{"مریم رضایی", "علی رضائی"}, false},
+		{"", []string{""}, false},
+	}
+	for _, tt := range tests {
+		if got := shebaOwnerMatches(tt.holder, tt.owners); got != tt.want {
+			t.Errorf("shebaOwnerMatches(%q, %q) = %v, want %v", tt.holder, tt.owners, got, tt.want)
+		}
+	}
+}
+
+func TestRequireAgencySheba(t *testing.T) {
+	t.Parallel()
+
+	// Without an inquiry provider payments are never held up
+	flow := NewShebaVerificationFlow(&shebaCustomerRepoStub{}, &kycAuditStub{}, nil, config.CacheConfig{}, nil, time.Hour)
+	if err := flow.RequireAgencySheba(context.Background(), 9); err != nil {
+		t.Fatalf("disabled verification returned %v", err)
+	}
+
+	repo := &shebaCustomerRepoStub{customer: newTestAgency()}
+	audit := &kycAuditStub{}
+	inquirer := &shebaInquirerStub{inquiry: &services.ShebaInquiry{Active: true, Owners: []string{"علی رضایی"}}}
+	flow = NewShebaVerificationFlow(repo, audit, inquirer, config.CacheConfig{}, nil, time.Hour)
+	if err := flow.RequireAgencySheba(context.Background(), 9); !IsAgencyShebaNotVerified(err) {
+		t.Fatalf("owner mismatch returned %v", err)
+	}
+	if repo.saved != models.ShebaOwnerMismatch || len(audit.actions) != 1 {
+		t.Fatalf("saved %q with audit %v", repo.saved, audit.actions)
+	}
+
+	inquirer.inquiry.Owners = []string{"آلفا"}
+	if err := flow.RequireAgencySheba(context.Background(), 9); err != nil {
+		t.Fatalf("matching owner returned %v", err)
+	}
+	if repo.saved != models.ShebaVerified {
+		t.Fatalf("saved %q", repo.saved)
+	}
+
+	// A verified number is not looked up again
+	agency := newTestAgency()
+	agency.ShebaVerification = utils.ToPtr(models.ShebaVerified)
+	agency.ShebaVerifiedNumber = agency.ShebaNumber
+	repo.customer = agency
+	calls := inquirer.calls
+	if err := flow.RequireAgencySheba(context.Background(), 9); err != nil || inquirer.calls != calls {
+		t.Fatalf("verified agency returned %v after %d inquiries", err, inquirer.calls-calls)
+	}
+
+	inquirer.err = errors.New("timeout")
+	repo.customer = newTestAgency()
+	if err := flow.RequireAgencySheba(context.Background(), 9); !IsShebaInquiryUnavailable(err) {
+		t.Fatalf("provider error returned %v", err)
+	}
+}
+
+func TestVerifyShebaNotConfigured(t *testing.T) {
+	t.Parallel()
+	flow := NewShebaVerificationFlow(&shebaCustomerRepoStub{}, &kycAuditStub{}, nil, config.CacheConfig{}, nil, time.Hour)
+	if _, err := flow.VerifySheba(context.Background(), 9, nil); !IsShebaInquiryNotConfigured(err) {
+		t.Fatalf("VerifySheba returned %v", err)
+	}
+}
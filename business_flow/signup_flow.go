@@ -12,14 +12,15 @@ import (
 	"strings"
 	"time"
 
-	"golang.org/x/crypto/bcrypt"
-
 	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/app/i18n"
 	"github.com/amirphl/Yamata-no-Orochi/app/services"
 	"github.com/amirphl/Yamata-no-Orochi/config"
 	"github.com/amirphl/Yamata-no-Orochi/models"
 	"github.com/amirphl/Yamata-no-Orochi/repository"
 	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/amirphl/Yamata-no-Orochi/utils/phonenumber"
+	"github.com/gofiber/fiber/v3/log"
 	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
@@ -42,16 +43,21 @@ type SignupFlowImpl struct {
 	customerRepo       repository.CustomerRepository
 	accountTypeRepo    repository.AccountTypeRepository
 	sessionRepo        repository.CustomerSessionRepository
+	sessionStore       services.SessionStore
 	auditRepo          repository.AuditLogRepository
 	agencyDiscountRepo repository.AgencyDiscountRepository
 	walletRepo         repository.WalletRepository
 	tokenService       services.TokenService
+	passwordHasher     services.PasswordHasher
+	passwordPolicy     services.PasswordPolicy
 	otpSMSSvc          services.SMSService
 	notificationSvc    services.NotificationService
 	adminConfig        config.AdminConfig
-	messageConfig      config.MessageConfig
+	localizer          *i18n.Localizer
 	db                 *gorm.DB
 	rc                 *redis.Client
+	otpThrottle        *OTPThrottle
+	loginDetector      *SuspiciousLoginDetector
 }
 
 type pendingSignupData struct {
@@ -65,31 +71,41 @@ func NewSignupFlow(
 	customerRepo repository.CustomerRepository,
 	accountTypeRepo repository.AccountTypeRepository,
 	sessionRepo repository.CustomerSessionRepository,
+	sessionStore services.SessionStore,
 	auditRepo repository.AuditLogRepository,
 	agencyDiscountRepo repository.AgencyDiscountRepository,
 	walletRepo repository.WalletRepository,
 	tokenService services.TokenService,
+	passwordHasher services.PasswordHasher,
+	passwordPolicy services.PasswordPolicy,
 	otpSMSSvc services.SMSService,
 	notificationSvc services.NotificationService,
 	adminConfig config.AdminConfig,
-	messageConfig config.MessageConfig,
+	localizer *i18n.Localizer,
 	db *gorm.DB,
 	rc *redis.Client,
+	otpThrottle *OTPThrottle,
+	loginDetector *SuspiciousLoginDetector,
 ) SignupFlow {
 	return &SignupFlowImpl{
 		customerRepo:       customerRepo,
 		accountTypeRepo:    accountTypeRepo,
 		sessionRepo:        sessionRepo,
+		sessionStore:       sessionStore,
 		auditRepo:          auditRepo,
 		agencyDiscountRepo: agencyDiscountRepo,
 		walletRepo:         walletRepo,
 		tokenService:       tokenService,
+		passwordHasher:     passwordHasher,
+		passwordPolicy:     passwordPolicy,
 		otpSMSSvc:          otpSMSSvc,
 		notificationSvc:    notificationSvc,
 		adminConfig:        adminConfig,
-		messageConfig:      messageConfig,
+		localizer:          localizer,
 		db:                 db,
 		rc:                 rc,
+		otpThrottle:        otpThrottle,
+		loginDetector:      loginDetector,
 	}
 }
 
@@ -101,9 +117,15 @@ func (s *SignupFlowImpl) Signup(ctx context.Context, req *dto.SignupRequest, met
 	if err := s.validateSignupRequest(ctx, req); err != nil {
 		return nil, NewBusinessError("SIGNUP_VALIDATION_FAILED", "Signup validation failed", err)
 	}
+	if err := checkPasswordPolicy(ctx, s.passwordPolicy, req.Password); err != nil {
+		return nil, NewBusinessError("SIGNUP_VALIDATION_FAILED", "Signup validation failed", err)
+	}
 	if s.rc == nil {
 		return nil, NewBusinessError("SIGNUP_FAILED", "Signup failed", ErrCacheNotAvailable)
 	}
+	if _, err := s.otpThrottle.Reserve(ctx, req.RepresentativeMobile); err != nil {
+		return nil, NewBusinessError("SIGNUP_FAILED", "Signup failed", err)
+	}
 
 	// Default referrer code if not provided
 	if req.ReferrerAgencyCode == nil || len(strings.TrimSpace(*req.ReferrerAgencyCode)) == 0 {
@@ -111,14 +133,14 @@ func (s *SignupFlowImpl) Signup(ctx context.Context, req *dto.SignupRequest, met
 		req.ReferrerAgencyCode = &defaultCode
 	}
 
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	hashedPassword, err := s.passwordHasher.Hash(req.Password)
 	if err != nil {
 		return nil, NewBusinessError("SIGNUP_FAILED", "Signup failed", err)
 	}
 	req.Password = ""
 	req.ConfirmPassword = ""
 
-	pendingID, err := s.createPendingSignup(ctx, req, string(hashedPassword))
+	pendingID, err := s.createPendingSignup(ctx, req, hashedPassword)
 	if err != nil {
 		return nil, NewBusinessError("SIGNUP_FAILED", "Signup failed", err)
 	}
@@ -129,7 +151,7 @@ func (s *SignupFlowImpl) Signup(ctx context.Context, req *dto.SignupRequest, met
 	}
 
 	customerID := int64(pendingID)
-	message := fmt.Sprintf(s.messageConfig.SignupVerificationCodeTemplate, otpCode)
+	message := i18n.Message(s.localizer.Resolve(req.Locale), "otp.signup_code", i18n.Args{"Code": otpCode})
 	recipient, err := normalizeOTPMobile(req.RepresentativeMobile)
 	if err != nil {
 		_ = s.deletePendingSignup(ctx, pendingID)
@@ -168,6 +190,7 @@ func (s *SignupFlowImpl) VerifyOTP(ctx context.Context, req *dto.OTPVerification
 	}
 
 	var customer models.Customer
+	var session *models.CustomerSession
 	var tokens struct {
 		access  string
 		refresh string
@@ -211,7 +234,8 @@ func (s *SignupFlowImpl) VerifyOTP(ctx context.Context, req *dto.OTPVerification
 		}
 
 		// Create session
-		if err := s.createSession(txCtx, customer.ID, tokens.access, tokens.refresh, metadata); err != nil {
+		session, err = s.createSession(txCtx, customer.ID, tokens.access, tokens.refresh, metadata)
+		if err != nil {
 			return err
 		}
 
@@ -228,13 +252,17 @@ func (s *SignupFlowImpl) VerifyOTP(ctx context.Context, req *dto.OTPVerification
 		return nil, NewBusinessError("OTP_VERIFICATION_FAILED", "OTP verification failed", err)
 	}
 	_ = s.deletePendingSignup(ctx, req.CustomerID)
+	activateSession(ctx, s.sessionStore, session)
+	if err := s.loginDetector.Remember(ctx, customer.ID, metadata); err != nil {
+		log.Errorf("remember device of customer %d: %v", customer.ID, err)
+	}
 
 	msg := fmt.Sprintf("Signup completed successfully for customer %d", customer.ID)
 	_ = s.createAuditLog(ctx, &customer, models.AuditActionSignupCompleted, msg, true, nil, metadata)
 
 	// Notify admins
 	if s.notificationSvc != nil {
-		adminMsg := fmt.Sprintf("New user verified: %s %s", customer.RepresentativeFirstName, customer.RepresentativeLastName)
+		adminMsg := s.localizer.Admin("admin.customer_verified", i18n.Args{"FirstName": customer.RepresentativeFirstName, "LastName": customer.RepresentativeLastName})
 		for _, mobile := range s.adminConfig.ActiveMobiles() {
 			_ = s.notificationSvc.SendSMS(ctx, mobile, adminMsg, utils.ToPtr(int64(customer.ID)))
 		}
@@ -265,12 +293,18 @@ func (s *SignupFlowImpl) ResendOTP(ctx context.Context, req *dto.OTPResendReques
 		target = pending.Request.Email
 	}
 
+	resendWait, err := s.otpThrottle.Reserve(ctx, target)
+	if err != nil {
+		return nil, NewBusinessError("RESEND_OTP_FAILED", "Resend OTP failed", err)
+	}
+
 	otpCode, err := s.generateAndSaveOTP(ctx, req.CustomerID, req.OTPType)
 	if err != nil {
 		return nil, NewBusinessError("RESEND_OTP_FAILED", "Resend OTP failed", err)
 	}
 
-	message := fmt.Sprintf(s.messageConfig.OTPResendVerificationCodeTemplate, otpCode, utils.OTPExpiry.Minutes())
+	locale := s.localizer.Resolve(pending.Request.Locale)
+	message := i18n.Message(locale, "otp.resend_code", i18n.Args{"Code": otpCode, "Minutes": utils.OTPExpiry.Minutes()})
 	if req.OTPType == OTPTypeMobile {
 		customerID := int64(req.CustomerID)
 		recipient, mobileErr := normalizeOTPMobile(target)
@@ -295,7 +329,7 @@ func (s *SignupFlowImpl) ResendOTP(ctx context.Context, req *dto.OTPResendReques
 		}
 		runAsyncOTPTask(ctx, "ResendOTP send email OTP", func(asyncCtx context.Context) error {
 			_ = asyncCtx
-			if err := s.notificationSvc.SendEmail(target, "Verification Code", message); err != nil {
+			if err := s.notificationSvc.SendEmail(target, i18n.Message(locale, "otp.email_subject", nil), message); err != nil {
 				_ = s.deleteSignupOTPState(asyncCtx, req.CustomerID, req.OTPType)
 				return err
 			}
@@ -304,9 +338,10 @@ func (s *SignupFlowImpl) ResendOTP(ctx context.Context, req *dto.OTPResendReques
 	}
 
 	return &dto.OTPResendResponse{
-		Message:         "OTP resent successfully",
-		OTPSent:         true,
-		MaskedOTPTarget: maskOTPTarget(target),
+		Message:           "OTP resent successfully",
+		OTPSent:           true,
+		MaskedOTPTarget:   maskOTPTarget(target),
+		ResendAvailableIn: ceilSeconds(resendWait),
 	}, nil
 }
 
@@ -449,6 +484,7 @@ func (s *SignupFlowImpl) createCustomer(ctx context.Context, req *dto.SignupRequ
 		IsEmailVerified:         utils.ToPtr(false),
 		IsMobileVerified:        utils.ToPtr(false),
 		IsActive:                utils.ToPtr(true),
+		PreferredLocale:         req.Locale,
 	}
 
 	err = s.customerRepo.Save(ctx, customer)
@@ -468,14 +504,6 @@ func (s *SignupFlowImpl) generateAndSaveOTP(ctx context.Context, customerID uint
 
 	if s.rc != nil {
 		key := s.signupOTPKey(customerID, otpType)
-		if existing, ttl, err := s.getSignupOTPState(ctx, customerID, otpType); err == nil {
-			if ttl > 0 && utils.UTCNow().Sub(existing.LastSentAt) < authOTPResendCooldown {
-				return "", ErrRateLimitExceeded
-			}
-		} else if err != ErrNoValidOTPFound {
-			return "", err
-		}
-
 		state := otpChallengeState{
 			OTPHash:    hashOTPCode(otpCode),
 			Attempts:   0,
@@ -546,7 +574,7 @@ func (s *SignupFlowImpl) completeSignup(ctx context.Context, customer *models.Cu
 	return s.customerRepo.UpdateVerificationStatus(ctx, customer.ID, isMobileVerified, isEmailVerified, mobileVerifiedAt, emailVerifiedAt)
 }
 
-func (s *SignupFlowImpl) createSession(ctx context.Context, customerID uint, accessToken, refreshToken string, metadata *ClientMetadata) error {
+func (s *SignupFlowImpl) createSession(ctx context.Context, customerID uint, accessToken, refreshToken string, metadata *ClientMetadata) (*models.CustomerSession, error) {
 	ipAddress := ""
 	userAgent := ""
 	if metadata != nil {
@@ -555,11 +583,11 @@ func (s *SignupFlowImpl) createSession(ctx context.Context, customerID uint, acc
 	}
 
 	session := &models.CustomerSession{
-		CorrelationID: uuid.New(),
-		CustomerID:    customerID,
-		SessionToken:  accessToken,
-		RefreshToken:  &refreshToken,
-		// DeviceInfo:    json.RawMessage, // TODO: Add device info
+		CorrelationID:  uuid.New(),
+		CustomerID:     customerID,
+		SessionToken:   accessToken,
+		RefreshToken:   &refreshToken,
+		DeviceInfo:     sessionDeviceInfo(metadata),
 		IPAddress:      &ipAddress,
 		UserAgent:      &userAgent,
 		IsActive:       utils.ToPtr(true),
@@ -568,10 +596,10 @@ func (s *SignupFlowImpl) createSession(ctx context.Context, customerID uint, acc
 	}
 
 	if err := s.sessionRepo.Save(ctx, session); err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	return session, nil
 }
 
 func (s *SignupFlowImpl) createDefaultDiscount(ctx context.Context, customer *models.Customer) error {
@@ -586,12 +614,13 @@ func (s *SignupFlowImpl) createDefaultDiscount(ctx context.Context, customer *mo
 
 	// create agency discount for customer
 	if err := s.agencyDiscountRepo.Save(ctx, &models.AgencyDiscount{
-		UUID:         uuid.New(),
-		AgencyID:     *customer.ReferrerAgencyID,
-		CustomerID:   customer.ID,
-		DiscountRate: rate,
-		ExpiresAt:    nil,
-		Reason:       utils.ToPtr("Created via Signup"),
+		UUID:          uuid.New(),
+		AgencyID:      *customer.ReferrerAgencyID,
+		CustomerID:    customer.ID,
+		DiscountRate:  rate,
+		EffectiveFrom: utils.UTCNow(),
+		ExpiresAt:     nil,
+		Reason:        utils.ToPtr("Created via Signup"),
 	}); err != nil {
 		return err
 	}
@@ -770,7 +799,7 @@ func (s *SignupFlowImpl) sanitizeSignupRequest(req *dto.SignupRequest) {
 	req.Email = normalizeEmailIdentifier(req.Email)
 	req.RepresentativeFirstName = strings.TrimSpace(req.RepresentativeFirstName)
 	req.RepresentativeLastName = strings.TrimSpace(req.RepresentativeLastName)
-	req.RepresentativeMobile = strings.TrimSpace(req.RepresentativeMobile)
+	req.RepresentativeMobile = phonenumber.CanonicalOrRaw(strings.TrimSpace(req.RepresentativeMobile))
 	req.AccountType = strings.TrimSpace(req.AccountType)
 	req.ReferrerAgencyCode = trimOptionalString(req.ReferrerAgencyCode)
 	req.CompanyName = trimOptionalString(req.CompanyName)
@@ -781,6 +810,7 @@ func (s *SignupFlowImpl) sanitizeSignupRequest(req *dto.SignupRequest) {
 	req.ShebaNumber = trimOptionalString(req.ShebaNumber)
 	req.Job = trimOptionalString(req.Job)
 	req.Category = trimOptionalString(req.Category)
+	req.Locale = trimOptionalString(req.Locale)
 }
 
 func (s *SignupFlowImpl) signupOTPKey(customerID uint, otpType string) string {
@@ -0,0 +1,101 @@
+package businessflow
+
+import (
+	"context"
+
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/pricing"
+	"github.com/amirphl/Yamata-no-Orochi/repository"
+)
+
+// SMSPricingService resolves the SMS sending cost of a single recipient. It is
+// shared by campaign cost estimation and budget reservation at finalize so
+// both sides always agree on the price.
+type SMSPricingService interface {
+	QuoteSMS(ctx context.Context, req SMSQuoteRequest) (*SMSQuote, error)
+}
+
+// SMSQuoteRequest holds the inputs used to select a tariff
+type SMSQuoteRequest struct {
+	LineNumber  models.LineNumber
+	AccountType string
+	Parts       uint64
+	// BasePrice is the platform base price used when no tariff matches
+	BasePrice uint64
+}
+
+// SMSQuote is the resolved cost of sending all parts of one message
+type SMSQuote struct {
+	Parts     uint64
+	PartsCost uint64
+	// TariffID and PricePerPart are set when a tariff row was applied;
+	// otherwise the base price and line number factor were used.
+	TariffID         *uint
+	PricePerPart     *uint64
+	LineNumberFactor float64
+}
+
+type SMSPricingServiceImpl struct {
+	tariffRepo repository.SMSTariffRepository
+}
+
+func NewSMSPricingService(tariffRepo repository.SMSTariffRepository) SMSPricingService {
+	return &SMSPricingServiceImpl{tariffRepo: tariffRepo}
+}
+
+func (s *SMSPricingServiceImpl) QuoteSMS(ctx context.Context, req SMSQuoteRequest) (*SMSQuote, error) {
+	quote := &SMSQuote{
+		Parts:            req.Parts,
+		LineNumberFactor: req.LineNumber.PriceFactor,
+	}
+
+	rows, err := s.tariffRepo.ListActive(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rules := make([]pricing.TariffRule, 0, len(rows))
+	for _, row := range rows {
+		rules = append(rules, smsTariffRule(row))
+	}
+
+	criteria := pricing.TariffCriteria{
+		AccountType: req.AccountType,
+		Parts:       req.Parts,
+	}
+	if req.LineNumber.Operator != nil {
+		criteria.Operator = *req.LineNumber.Operator
+	}
+	if req.LineNumber.Tier != nil {
+		criteria.LineNumberTier = *req.LineNumber.Tier
+	}
+
+	if rule, ok := pricing.SelectTariff(rules, criteria); ok {
+		quote.TariffID = &rule.ID
+		quote.PricePerPart = &rule.PricePerPart
+		quote.PartsCost = rule.PricePerPart * req.Parts
+		return quote, nil
+	}
+
+	quote.PartsCost = pricing.LegacySMSPartsCost(req.BasePrice, req.LineNumber.PriceFactor, req.Parts)
+	return quote, nil
+}
+
+func smsTariffRule(t *models.SMSTariff) pricing.TariffRule {
+	rule := pricing.TariffRule{
+		ID:           t.ID,
+		PricePerPart: t.PricePerPart,
+	}
+	if t.Operator != nil {
+		rule.Operator = *t.Operator
+	}
+	if t.LineNumberTier != nil {
+		rule.LineNumberTier = *t.LineNumberTier
+	}
+	if t.AccountType != nil {
+		rule.AccountType = *t.AccountType
+	}
+	if t.PartCount != nil {
+		rule.PartCount = *t.PartCount
+	}
+	return rule
+}
@@ -0,0 +1,233 @@
+package businessflow
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/repository"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/google/uuid"
+)
+
+// SMSTariffAdminFlow defines admin CRUD operations for the SMS tariff table.
+type SMSTariffAdminFlow interface {
+	AdminCreateSMSTariff(ctx context.Context, req *dto.AdminCreateSMSTariffRequest) (*dto.AdminSMSTariffResponse, error)
+	AdminListSMSTariffs(ctx context.Context) (*dto.AdminListSMSTariffsResponse, error)
+	AdminUpdateSMSTariff(ctx context.Context, req *dto.AdminUpdateSMSTariffRequest) (*dto.AdminSMSTariffResponse, error)
+	AdminDeleteSMSTariff(ctx context.Context, tariffUUID string) (*dto.AdminDeleteSMSTariffResponse, error)
+}
+
+type SMSTariffAdminFlowImpl struct {
+	tariffRepo repository.SMSTariffRepository
+	auditRepo  repository.AuditLogRepository
+}
+
+func NewSMSTariffAdminFlow(tariffRepo repository.SMSTariffRepository, auditRepo repository.AuditLogRepository) SMSTariffAdminFlow {
+	return &SMSTariffAdminFlowImpl{
+		tariffRepo: tariffRepo,
+		auditRepo:  auditRepo,
+	}
+}
+
+func (f *SMSTariffAdminFlowImpl) AdminCreateSMSTariff(ctx context.Context, req *dto.AdminCreateSMSTariffRequest) (*dto.AdminSMSTariffResponse, error) {
+	if req == nil {
+		return nil, NewBusinessError("INVALID_REQUEST", "request is required", nil)
+	}
+
+	tariff, err := buildSMSTariff(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := f.ensureUniqueDimensions(ctx, tariff, 0); err != nil {
+		return nil, err
+	}
+
+	now := utils.UTCNow()
+	tariff.UUID = uuid.New()
+	tariff.CreatedAt = now
+	tariff.UpdatedAt = now
+	if err := f.tariffRepo.Save(ctx, tariff); err != nil {
+		logAdminAction(ctx, f.auditRepo, models.AuditActionAdminSMSTariffCreate, "Admin create SMS tariff", false, nil, smsTariffAuditMetadata(tariff), err)
+		return nil, NewBusinessError("SMS_TARIFF_SAVE_FAILED", "Failed to save SMS tariff", err)
+	}
+
+	logAdminAction(ctx, f.auditRepo, models.AuditActionAdminSMSTariffCreate, "Admin create SMS tariff", true, nil, smsTariffAuditMetadata(tariff), nil)
+	return &dto.AdminSMSTariffResponse{
+		Message: "SMS tariff created successfully",
+		Tariff:  toAdminSMSTariffItem(tariff),
+	}, nil
+}
+
+func (f *SMSTariffAdminFlowImpl) AdminListSMSTariffs(ctx context.Context) (*dto.AdminListSMSTariffsResponse, error) {
+	rows, err := f.tariffRepo.ByFilter(ctx, models.SMSTariffFilter{}, "id ASC", 0, 0)
+	if err != nil {
+		return nil, NewBusinessError("SMS_TARIFF_LIST_FAILED", "Failed to list SMS tariffs", err)
+	}
+
+	items := make([]dto.AdminSMSTariffItem, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, toAdminSMSTariffItem(row))
+	}
+
+	logAdminAction(ctx, f.auditRepo, models.AuditActionAdminSMSTariffList, "Admin listed SMS tariffs", true, nil, map[string]any{
+		"items": len(items),
+	}, nil)
+	return &dto.AdminListSMSTariffsResponse{
+		Message: "SMS tariffs retrieved successfully",
+		Items:   items,
+	}, nil
+}
+
+func (f *SMSTariffAdminFlowImpl) AdminUpdateSMSTariff(ctx context.Context, req *dto.AdminUpdateSMSTariffRequest) (*dto.AdminSMSTariffResponse, error) {
+	if req == nil {
+		return nil, NewBusinessError("INVALID_REQUEST", "request is required", nil)
+	}
+
+	existing, err := f.getTariff(ctx, req.UUID)
+	if err != nil {
+		return nil, err
+	}
+
+	tariff, err := buildSMSTariff(&req.AdminCreateSMSTariffRequest)
+	if err != nil {
+		return nil, err
+	}
+	if err := f.ensureUniqueDimensions(ctx, tariff, existing.ID); err != nil {
+		return nil, err
+	}
+
+	tariff.ID = existing.ID
+	tariff.UUID = existing.UUID
+	tariff.CreatedAt = existing.CreatedAt
+	tariff.UpdatedAt = utils.UTCNow()
+	if tariff.IsActive == nil {
+		tariff.IsActive = existing.IsActive
+	}
+	if err := f.tariffRepo.Update(ctx, tariff); err != nil {
+		logAdminAction(ctx, f.auditRepo, models.AuditActionAdminSMSTariffUpdate, "Admin update SMS tariff", false, nil, smsTariffAuditMetadata(tariff), err)
+		return nil, NewBusinessError("SMS_TARIFF_UPDATE_FAILED", "Failed to update SMS tariff", err)
+	}
+
+	logAdminAction(ctx, f.auditRepo, models.AuditActionAdminSMSTariffUpdate, "Admin update SMS tariff", true, nil, smsTariffAuditMetadata(tariff), nil)
+	return &dto.AdminSMSTariffResponse{
+		Message: "SMS tariff updated successfully",
+		Tariff:  toAdminSMSTariffItem(tariff),
+	}, nil
+}
+
+func (f *SMSTariffAdminFlowImpl) AdminDeleteSMSTariff(ctx context.Context, tariffUUID string) (*dto.AdminDeleteSMSTariffResponse, error) {
+	existing, err := f.getTariff(ctx, tariffUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := f.tariffRepo.Delete(ctx, existing.ID); err != nil {
+		logAdminAction(ctx, f.auditRepo, models.AuditActionAdminSMSTariffDelete, "Admin delete SMS tariff", false, nil, smsTariffAuditMetadata(existing), err)
+		return nil, NewBusinessError("SMS_TARIFF_DELETE_FAILED", "Failed to delete SMS tariff", err)
+	}
+
+	logAdminAction(ctx, f.auditRepo, models.AuditActionAdminSMSTariffDelete, "Admin delete SMS tariff", true, nil, smsTariffAuditMetadata(existing), nil)
+	return &dto.AdminDeleteSMSTariffResponse{
+		Message: "SMS tariff deleted successfully",
+	}, nil
+}
+
+func (f *SMSTariffAdminFlowImpl) getTariff(ctx context.Context, tariffUUID string) (*models.SMSTariff, error) {
+	id, err := uuid.Parse(strings.TrimSpace(tariffUUID))
+	if err != nil {
+		return nil, NewBusinessError("SMS_TARIFF_NOT_FOUND", "SMS tariff not found", ErrSMSTariffNotFound)
+	}
+	tariff, err := f.tariffRepo.ByUUID(ctx, id)
+	if err != nil {
+		return nil, NewBusinessError("SMS_TARIFF_LOOKUP_FAILED", "Failed to lookup SMS tariff", err)
+	}
+	if tariff == nil {
+		return nil, NewBusinessError("SMS_TARIFF_NOT_FOUND", "SMS tariff not found", ErrSMSTariffNotFound)
+	}
+	return tariff, nil
+}
+
+// ensureUniqueDimensions rejects a tariff whose dimensions collide with another
+// row; the table is small so the comparison is done in memory.
+func (f *SMSTariffAdminFlowImpl) ensureUniqueDimensions(ctx context.Context, tariff *models.SMSTariff, excludeID uint) error {
+	rows, err := f.tariffRepo.ByFilter(ctx, models.SMSTariffFilter{}, "id ASC", 0, 0)
+	if err != nil {
+		return NewBusinessError("SMS_TARIFF_LOOKUP_FAILED", "Failed to lookup SMS tariffs", err)
+	}
+	key := smsTariffRule(tariff)
+	for _, row := range rows {
+		if row.ID == excludeID {
+			continue
+		}
+		other := smsTariffRule(row)
+		if other.Operator == key.Operator && other.LineNumberTier == key.LineNumberTier &&
+			other.AccountType == key.AccountType && other.PartCount == key.PartCount {
+			return NewBusinessError("SMS_TARIFF_ALREADY_EXISTS", "An SMS tariff with the same dimensions already exists", ErrSMSTariffAlreadyExists)
+		}
+	}
+	return nil
+}
+
+func buildSMSTariff(req *dto.AdminCreateSMSTariffRequest) (*models.SMSTariff, error) {
+	operator := normalizeOptionalLower(req.Operator)
+	if operator != nil && !models.IsValidSMSOperator(*operator) {
+		return nil, NewBusinessError("SMS_TARIFF_OPERATOR_INVALID", "Invalid SMS operator", ErrSMSTariffOperatorInvalid)
+	}
+	if req.PricePerPart == 0 {
+		return nil, NewBusinessError("SMS_TARIFF_PRICE_INVALID", "Price per part must be greater than zero", ErrPriceFactorInvalid)
+	}
+	partCount := req.PartCount
+	if partCount != nil && *partCount == 0 {
+		partCount = nil
+	}
+
+	return &models.SMSTariff{
+		Operator:       operator,
+		LineNumberTier: normalizeOptionalLower(req.LineNumberTier),
+		AccountType:    normalizeOptionalLower(req.AccountType),
+		PartCount:      partCount,
+		PricePerPart:   req.PricePerPart,
+		Description:    req.Description,
+		IsActive:       req.IsActive,
+	}, nil
+}
+
+func normalizeOptionalLower(value *string) *string {
+	if value == nil {
+		return nil
+	}
+	v := strings.ToLower(strings.TrimSpace(*value))
+	if v == "" {
+		return nil
+	}
+	return &v
+}
+
+func toAdminSMSTariffItem(t *models.SMSTariff) dto.AdminSMSTariffItem {
+	return dto.AdminSMSTariffItem{
+		UUID:           t.UUID.String(),
+		Operator:       t.Operator,
+		LineNumberTier: t.LineNumberTier,
+		AccountType:    t.AccountType,
+		PartCount:      t.PartCount,
+		PricePerPart:   t.PricePerPart,
+		Description:    t.Description,
+		IsActive:       t.IsActive == nil || *t.IsActive,
+		CreatedAt:      t.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:      t.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+func smsTariffAuditMetadata(t *models.SMSTariff) map[string]any {
+	return map[string]any{
+		"uuid":             t.UUID.String(),
+		"operator":         t.Operator,
+		"line_number_tier": t.LineNumberTier,
+		"account_type":     t.AccountType,
+		"part_count":       t.PartCount,
+		"price_per_part":   t.PricePerPart,
+	}
+}
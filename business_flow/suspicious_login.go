@@ -0,0 +1,268 @@
+package businessflow
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/i18n"
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/repository"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/redis/go-redis/v9"
+)
+
+// loginAlertTTL is how long the "this wasn't me" link of a login alert works
+const loginAlertTTL = 7 * 24 * time.Hour
+
+// LoginAlert identifies the login a customer was alerted of
+type LoginAlert struct {
+	CustomerID  uint   `json:"customer_id"`
+	Fingerprint string `json:"fingerprint"`
+	IPRange     string `json:"ip_range"`
+}
+
+// SuspiciousLoginDetector remembers the devices and IP ranges each customer
+// logs in from, and alerts the customer by SMS and email when a login comes
+// from a new one. The alert links to a page where the login can be reported
+// as not the customer's; the link token is kept in Redis, so without Redis
+// alerts are sent without a link.
+type SuspiciousLoginDetector struct {
+	knownDeviceRepo repository.CustomerKnownDeviceRepository
+	jobs            JobQueue
+	localizer       *i18n.Localizer
+	alertURL        string
+	rc              *redis.Client
+}
+
+// NewSuspiciousLoginDetector creates a detector; alertURL is the page the
+// alert token is passed to as ?token=
+func NewSuspiciousLoginDetector(
+	knownDeviceRepo repository.CustomerKnownDeviceRepository,
+	jobs JobQueue,
+	localizer *i18n.Localizer,
+	alertURL string,
+	rc *redis.Client,
+) *SuspiciousLoginDetector {
+	return &SuspiciousLoginDetector{
+		knownDeviceRepo: knownDeviceRepo,
+		jobs:            jobs,
+		localizer:       localizer,
+		alertURL:        alertURL,
+		rc:              rc,
+	}
+}
+
+// Remember records the client of a login the customer is known to have made,
+// such as at signup or after a password reset
+func (d *SuspiciousLoginDetector) Remember(ctx context.Context, customerID uint, metadata *ClientMetadata) error {
+	if d == nil {
+		return nil
+	}
+	return d.knownDeviceRepo.Upsert(ctx, knownDevice(customerID, metadata))
+}
+
+// Check records the client of a successful login and reports whether it came
+// from a device or IP range the customer never logged in from, in which case
+// the customer is alerted. Customers without known devices, who logged in
+// before devices were recorded, are not alerted on their first login.
+func (d *SuspiciousLoginDetector) Check(ctx context.Context, customer *models.Customer, metadata *ClientMetadata) (bool, error) {
+	if d == nil || customer == nil {
+		return false, nil
+	}
+
+	known, err := d.knownDeviceRepo.ByCustomerID(ctx, customer.ID)
+	if err != nil {
+		return false, err
+	}
+	device := knownDevice(customer.ID, metadata)
+	suspicious := isSuspiciousLogin(known, device.Fingerprint, device.IPRange)
+
+	if err := d.knownDeviceRepo.Upsert(ctx, device); err != nil {
+		return suspicious, err
+	}
+	if !suspicious {
+		return false, nil
+	}
+
+	link, err := d.alertLink(ctx, LoginAlert{CustomerID: customer.ID, Fingerprint: device.Fingerprint, IPRange: device.IPRange})
+	if err != nil {
+		return true, err
+	}
+	d.sendAlert(ctx, customer, metadata, link)
+	return true, nil
+}
+
+// Resolve consumes the token of a login alert link
+func (d *SuspiciousLoginDetector) Resolve(ctx context.Context, token string) (*LoginAlert, error) {
+	if d == nil || d.rc == nil {
+		return nil, ErrCacheNotAvailable
+	}
+	raw, err := d.rc.GetDel(ctx, loginAlertKey(token)).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrLoginAlertNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	var alert LoginAlert
+	if err := json.Unmarshal([]byte(raw), &alert); err != nil {
+		return nil, fmt.Errorf("decode login alert: %w", err)
+	}
+	return &alert, nil
+}
+
+// Forget removes the device of a login reported as not the customer's so
+// logging in from it is reported again
+func (d *SuspiciousLoginDetector) Forget(ctx context.Context, alert *LoginAlert) error {
+	if d == nil || alert == nil {
+		return nil
+	}
+	return d.knownDeviceRepo.Forget(ctx, alert.CustomerID, alert.Fingerprint, alert.IPRange)
+}
+
+// alertLink stores the alert under a random token and returns the link to
+// report it; it is empty when no link can be offered
+func (d *SuspiciousLoginDetector) alertLink(ctx context.Context, alert LoginAlert) (string, error) {
+	if d.rc == nil || d.alertURL == "" {
+		return "", nil
+	}
+
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(buf)
+
+	raw, err := json.Marshal(alert)
+	if err != nil {
+		return "", err
+	}
+	if err := d.rc.Set(ctx, loginAlertKey(token), raw, loginAlertTTL).Err(); err != nil {
+		return "", err
+	}
+
+	separator := "?"
+	if strings.Contains(d.alertURL, "?") {
+		separator = "&"
+	}
+	return d.alertURL + separator + "token=" + url.QueryEscape(token), nil
+}
+
+func (d *SuspiciousLoginDetector) sendAlert(ctx context.Context, customer *models.Customer, metadata *ClientMetadata, link string) {
+	if d.jobs == nil {
+		return
+	}
+	locale := d.localizer.CustomerLocale(customer)
+	if link == "" {
+		link = i18n.Message(locale, "login_alert.no_link", nil)
+	}
+
+	var info models.DeviceInfo
+	location := ""
+	if metadata != nil {
+		info = parseUserAgent(metadata.UserAgent)
+		location = ipRange(metadata.IPAddress)
+		if metadata.Location != nil && metadata.Location.Country != "" {
+			location = metadata.Location.Country
+		}
+	}
+	message := i18n.Message(locale, "login_alert.message", i18n.Args{
+		"Device":   describeDevice(locale, info),
+		"Location": location,
+		"Link":     link,
+	})
+	subject := i18n.Message(locale, "login_alert.email_subject", nil)
+
+	customerID := int64(customer.ID)
+	if mobile := customer.RepresentativeMobile; mobile != "" {
+		recipient, err := normalizeOTPMobile(mobile)
+		if err == nil {
+			err = d.jobs.Enqueue(ctx, models.JobTypeSendSMS, models.SendSMSJob{Mobile: recipient, Message: message, CustomerID: &customerID})
+		}
+		if err != nil {
+			log.Printf("queue login alert SMS: %v", err)
+		}
+	}
+	if email := customer.Email; email != "" {
+		if err := d.jobs.Enqueue(ctx, models.JobTypeSendEmail, models.SendEmailJob{To: email, Subject: subject, Body: message}); err != nil {
+			log.Printf("queue login alert email: %v", err)
+		}
+	}
+}
+
+// knownDevice describes the client of a login for customer_known_devices
+func knownDevice(customerID uint, metadata *ClientMetadata) *models.CustomerKnownDevice {
+	now := utils.UTCNow()
+	device := &models.CustomerKnownDevice{
+		CustomerID:  customerID,
+		Fingerprint: deviceFingerprint(""),
+		FirstSeenAt: now,
+		LastSeenAt:  now,
+	}
+	if metadata == nil {
+		return device
+	}
+	device.Fingerprint = deviceFingerprint(metadata.UserAgent)
+	device.IPRange = ipRange(metadata.IPAddress)
+	if metadata.UserAgent != "" {
+		device.UserAgent = utils.ToPtr(metadata.UserAgent)
+	}
+	if metadata.Location != nil && metadata.Location.Country != "" {
+		device.Country = utils.ToPtr(metadata.Location.Country)
+	}
+	return device
+}
+
+// deviceFingerprint identifies a device by its browser, OS, and device type;
+// the browser version is left out so browser updates are not new devices
+func deviceFingerprint(userAgent string) string {
+	info := parseUserAgent(userAgent)
+	sum := sha256.Sum256([]byte(info.Browser + "|" + info.OS + "|" + info.DeviceType))
+	return hex.EncodeToString(sum[:])
+}
+
+// isSuspiciousLogin reports whether a login comes from a device or an IP range
+// none of the known devices of the customer was seen with. An unknown IP range
+// is ignored.
+func isSuspiciousLogin(known []*models.CustomerKnownDevice, fingerprint, ipRange string) bool {
+	if len(known) == 0 {
+		return false
+	}
+	knownFingerprint := false
+	knownIPRange := ipRange == ""
+	for _, device := range known {
+		knownFingerprint = knownFingerprint || device.Fingerprint == fingerprint
+		knownIPRange = knownIPRange || device.IPRange == ipRange
+	}
+	return !knownFingerprint || !knownIPRange
+}
+
+// describeDevice names a device for alerts, e.g. "Chrome on Windows"
+func describeDevice(locale i18n.Locale, info models.DeviceInfo) string {
+	switch {
+	case info.Browser != "" && info.OS != "":
+		return i18n.Message(locale, "device.browser_on_os", i18n.Args{"Browser": info.Browser, "OS": info.OS})
+	case info.Browser != "":
+		return info.Browser
+	case info.OS != "":
+		return info.OS
+	default:
+		return i18n.Message(locale, "device.unknown", nil)
+	}
+}
+
+// loginAlertKey keys an alert by the hash of its token so tokens are not
+// stored in Redis
+func loginAlertKey(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return "auth:login_alert:" + hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,73 @@
+package businessflow
+
+import (
+	"testing"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/i18n"
+	"github.com/amirphl/Yamata-no-Orochi/models"
+)
+
+const (
+	chromeWindows120 = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.6099.109 Safari/537.36"
+	chromeWindows121 = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/121.0.6167.85 Safari/537.36"
+	safariIPhone     = "Mozilla/5.0 (iPhone; CPU iPhone OS 17_1 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.1 Mobile/15E148 Safari/604.1"
+)
+
+func TestDeviceFingerprint(t *testing.T) {
+	t.Parallel()
+
+	if deviceFingerprint(chromeWindows120) != deviceFingerprint(chromeWindows121) {
+		t.Error("a browser update must not change the fingerprint")
+	}
+	if deviceFingerprint(chromeWindows120) == deviceFingerprint(safariIPhone) {
+		t.Error("different devices must have different fingerprints")
+	}
+}
+
+func TestIsSuspiciousLogin(t *testing.T) {
+	t.Parallel()
+
+	known := []*models.CustomerKnownDevice{
+		{Fingerprint: deviceFingerprint(chromeWindows120), IPRange: "203.0.113.0/24"},
+		{Fingerprint: deviceFingerprint(safariIPhone), IPRange: "198.51.100.0/24"},
+	}
+
+	cases := []struct {
+		name        string
+		known       []*models.CustomerKnownDevice
+		fingerprint string
+		ipRange     string
+		want        bool
+	}{
+		{"first login", nil, deviceFingerprint(chromeWindows120), "203.0.113.0/24", false},
+		{"known device and range", known, deviceFingerprint(chromeWindows121), "203.0.113.0/24", false},
+		{"known device in range of another device", known, deviceFingerprint(safariIPhone), "203.0.113.0/24", false},
+		{"new device", known, deviceFingerprint("Mozilla/5.0 (X11; Linux x86_64; rv:121.0) Gecko/20100101 Firefox/121.0"), "203.0.113.0/24", true},
+		{"new range", known, deviceFingerprint(chromeWindows120), "192.0.2.0/24", true},
+		{"unknown range", known, deviceFingerprint(chromeWindows120), "", false},
+	}
+	for _, tc := range cases {
+		if got := isSuspiciousLogin(tc.known, tc.fingerprint, tc.ipRange); got != tc.want {
+			t.Errorf("%s: isSuspiciousLogin() = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestKnownDevice(t *testing.T) {
+	t.Parallel()
+
+	device := knownDevice(7, &ClientMetadata{
+		IPAddress: "203.0.113.42",
+		UserAgent: chromeWindows120,
+		Location:  &LocationInfo{Country: "IR"},
+	})
+	if device.CustomerID != 7 || device.IPRange != "203.0.113.0/24" || device.Fingerprint != deviceFingerprint(chromeWindows120) {
+		t.Errorf("knownDevice() = %+v", device)
+	}
+	if device.Country == nil || *device.Country != "IR" {
+		t.Errorf("knownDevice() country = %v, want IR", device.Country)
+	}
+	if describeDevice(i18n.LocaleEnglish, parseUserAgent(chromeWindows120)) != "Chrome on Windows" {
+		t.Errorf("describeDevice() = %q", describeDevice(i18n.LocaleEnglish, parseUserAgent(chromeWindows120)))
+	}
+}
@@ -0,0 +1,339 @@
+package businessflow
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/app/services"
+	"github.com/amirphl/Yamata-no-Orochi/config"
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/pricing"
+	"github.com/amirphl/Yamata-no-Orochi/repository"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/google/uuid"
+)
+
+// TaxInvoiceFlow lists the official invoices issued for wallet charges and
+// serves their PDFs
+type TaxInvoiceFlow interface {
+	ListCustomerInvoices(ctx context.Context, customerID uint, page, limit int) (*dto.ListTaxInvoicesResponse, error)
+	AdminListInvoices(ctx context.Context, filter dto.AdminListTaxInvoicesFilter) (*dto.AdminListTaxInvoicesResponse, error)
+	DownloadCustomerInvoice(ctx context.Context, customerID uint, id uuid.UUID) (*dto.TaxInvoicePDF, error)
+	DownloadInvoice(ctx context.Context, id uuid.UUID) (*dto.TaxInvoicePDF, error)
+}
+
+type TaxInvoiceFlowImpl struct {
+	invoiceRepo repository.TaxInvoiceRepository
+	renderer    services.TaxInvoiceRenderer
+	storageDir  string
+}
+
+// NewTaxInvoiceFlow creates the invoice flow. renderer may be nil when the
+// invoice fonts are missing; invoices are still issued and listed, but cannot
+// be downloaded until it is configured.
+func NewTaxInvoiceFlow(
+	invoiceRepo repository.TaxInvoiceRepository,
+	renderer services.TaxInvoiceRenderer,
+	storageDir string,
+) TaxInvoiceFlow {
+	return &TaxInvoiceFlowImpl{
+		invoiceRepo: invoiceRepo,
+		renderer:    renderer,
+		storageDir:  storageDir,
+	}
+}
+
+// ListCustomerInvoices returns the customer's own invoices, newest first
+func (f *TaxInvoiceFlowImpl) ListCustomerInvoices(ctx context.Context, customerID uint, page, limit int) (*dto.ListTaxInvoicesResponse, error) {
+	items, pagination, err := f.list(ctx, models.TaxInvoiceFilter{CustomerID: &customerID}, page, limit)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.ListTaxInvoicesResponse{
+		Message:    "Invoices retrieved successfully",
+		Items:      items,
+		Pagination: pagination,
+	}, nil
+}
+
+// AdminListInvoices returns invoices of all customers, newest first
+func (f *TaxInvoiceFlowImpl) AdminListInvoices(ctx context.Context, filter dto.AdminListTaxInvoicesFilter) (*dto.AdminListTaxInvoicesResponse, error) {
+	tf := models.TaxInvoiceFilter{CustomerID: filter.CustomerID}
+	if filter.IssuedAfter != nil && *filter.IssuedAfter != "" {
+		t, err := time.Parse(time.RFC3339, *filter.IssuedAfter)
+		if err != nil {
+			return nil, NewBusinessError("VALIDATION_ERROR", "Invalid issued_after format", err)
+		}
+		tf.IssuedAfter = &t
+	}
+	if filter.IssuedBefore != nil && *filter.IssuedBefore != "" {
+		t, err := time.Parse(time.RFC3339, *filter.IssuedBefore)
+		if err != nil {
+			return nil, NewBusinessError("VALIDATION_ERROR", "Invalid issued_before format", err)
+		}
+		tf.IssuedBefore = &t
+	}
+	if tf.IssuedAfter != nil && tf.IssuedBefore != nil && tf.IssuedAfter.After(*tf.IssuedBefore) {
+		return nil, NewBusinessError("VALIDATION_ERROR", "issued_after cannot be after issued_before", ErrStartDateAfterEndDate)
+	}
+
+	items, pagination, err := f.list(ctx, tf, filter.Page, filter.Limit)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.AdminListTaxInvoicesResponse{
+		Message:    "Invoices retrieved successfully",
+		Items:      items,
+		Pagination: pagination,
+	}, nil
+}
+
+func (f *TaxInvoiceFlowImpl) list(ctx context.Context, filter models.TaxInvoiceFilter, page, limit int) ([]dto.TaxInvoiceItem, dto.PaginationInfo, error) {
+	page = max(1, page)
+	if limit <= 0 {
+		limit = 10
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	offset := (page - 1) * limit
+
+	total, err := f.invoiceRepo.Count(ctx, filter)
+	if err != nil {
+		return nil, dto.PaginationInfo{}, NewBusinessError("TAX_INVOICE_LIST_FAILED", "Failed to count invoices", err)
+	}
+	rows, err := f.invoiceRepo.ByFilter(ctx, filter, "issued_at DESC, id DESC", limit, offset)
+	if err != nil {
+		return nil, dto.PaginationInfo{}, NewBusinessError("TAX_INVOICE_LIST_FAILED", "Failed to list invoices", err)
+	}
+
+	items := make([]dto.TaxInvoiceItem, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, taxInvoiceItem(row))
+	}
+	return items, dto.PaginationInfo{
+		Total:      total,
+		Page:       page,
+		Limit:      limit,
+		TotalPages: int((total + int64(limit) - 1) / int64(limit)),
+	}, nil
+}
+
+// DownloadCustomerInvoice returns the PDF of one of the customer's invoices
+func (f *TaxInvoiceFlowImpl) DownloadCustomerInvoice(ctx context.Context, customerID uint, id uuid.UUID) (*dto.TaxInvoicePDF, error) {
+	inv, err := f.invoiceRepo.ByUUID(ctx, id)
+	if err != nil {
+		return nil, NewBusinessError("TAX_INVOICE_DOWNLOAD_FAILED", "Failed to get invoice", err)
+	}
+	// Another customer's invoice is reported as missing
+	if inv == nil || inv.CustomerID != customerID {
+		return nil, ErrTaxInvoiceNotFound
+	}
+	return f.pdf(ctx, inv)
+}
+
+// DownloadInvoice returns the PDF of any invoice
+func (f *TaxInvoiceFlowImpl) DownloadInvoice(ctx context.Context, id uuid.UUID) (*dto.TaxInvoicePDF, error) {
+	inv, err := f.invoiceRepo.ByUUID(ctx, id)
+	if err != nil {
+		return nil, NewBusinessError("TAX_INVOICE_DOWNLOAD_FAILED", "Failed to get invoice", err)
+	}
+	if inv == nil {
+		return nil, ErrTaxInvoiceNotFound
+	}
+	return f.pdf(ctx, inv)
+}
+
+// pdf returns the stored PDF of an invoice, rendering and storing it on first
+// download. The row is immutable once issued, so the file never goes stale.
+func (f *TaxInvoiceFlowImpl) pdf(ctx context.Context, inv *models.TaxInvoice) (*dto.TaxInvoicePDF, error) {
+	filename := inv.InvoiceNumber + ".pdf"
+	if inv.PDFPath != "" {
+		content, err := os.ReadFile(inv.PDFPath)
+		if err == nil {
+			return &dto.TaxInvoicePDF{Filename: filename, Content: content}, nil
+		}
+		if !os.IsNotExist(err) {
+			return nil, NewBusinessError("TAX_INVOICE_DOWNLOAD_FAILED", "Failed to read invoice PDF", err)
+		}
+		// The file was lost, e.g. with a volume; render it again
+	}
+	if f.renderer == nil {
+		return nil, ErrTaxInvoicePDFUnavailable
+	}
+
+	doc, err := taxInvoiceDocument(inv)
+	if err != nil {
+		return nil, NewBusinessError("TAX_INVOICE_DOWNLOAD_FAILED", "Failed to read invoice parties", err)
+	}
+	content, err := f.renderer.Render(doc)
+	if err != nil {
+		return nil, NewBusinessError("TAX_INVOICE_DOWNLOAD_FAILED", "Failed to render invoice PDF", err)
+	}
+	issued := inv.IssuedAt.In(utils.TehranLocation())
+	path := filepath.Join(f.storageDir, issued.Format("2006"), issued.Format("01"), filename)
+	if err := atomicWrite(path, content, 0o644); err != nil {
+		return nil, NewBusinessError("TAX_INVOICE_DOWNLOAD_FAILED", "Failed to store invoice PDF", err)
+	}
+	if err := f.invoiceRepo.SetPDF(ctx, inv.ID, path, utils.UTCNow()); err != nil {
+		return nil, NewBusinessError("TAX_INVOICE_DOWNLOAD_FAILED", "Failed to record invoice PDF", err)
+	}
+	return &dto.TaxInvoicePDF{Filename: filename, Content: content}, nil
+}
+
+func taxInvoiceItem(inv *models.TaxInvoice) dto.TaxInvoiceItem {
+	return dto.TaxInvoiceItem{
+		UUID:             inv.UUID.String(),
+		InvoiceNumber:    inv.InvoiceNumber,
+		CustomerID:       inv.CustomerID,
+		PaymentChannel:   inv.PaymentChannel,
+		PaymentReference: inv.Reference,
+		AmountWithTax:    inv.AmountWithTax,
+		Amount:           inv.Amount,
+		Tax:              inv.Tax,
+		TaxBasisPoints:   inv.TaxBasisPoints,
+		Description:      inv.Description,
+		IssuedAt:         inv.IssuedAt.Format(time.RFC3339),
+	}
+}
+
+func taxInvoiceDocument(inv *models.TaxInvoice) (services.TaxInvoiceDocument, error) {
+	var seller, buyer models.TaxInvoiceParty
+	if err := json.Unmarshal(inv.Seller, &seller); err != nil {
+		return services.TaxInvoiceDocument{}, fmt.Errorf("seller: %w", err)
+	}
+	if err := json.Unmarshal(inv.Buyer, &buyer); err != nil {
+		return services.TaxInvoiceDocument{}, fmt.Errorf("buyer: %w", err)
+	}
+	return services.TaxInvoiceDocument{
+		InvoiceNumber:  inv.InvoiceNumber,
+		IssuedAt:       inv.IssuedAt,
+		Seller:         seller,
+		Buyer:          buyer,
+		Description:    inv.Description,
+		PaymentChannel: taxInvoiceChannelLabel(inv.PaymentChannel),
+		Reference:      inv.Reference,
+		Amount:         inv.Amount,
+		Tax:            inv.Tax,
+		AmountWithTax:  inv.AmountWithTax,
+		TaxBasisPoints: inv.TaxBasisPoints,
+	}, nil
+}
+
+// taxInvoiceChannelLabel is the Persian name of a payment channel as printed
+// on the invoice
+func taxInvoiceChannelLabel(channel string) string {
+	switch channel {
+	case "payment_gateway":
+		return "درگاه پرداخت اینترنتی"
+	case "deposit_receipt":
+		return "واریز بانکی"
+	case "admin_charge":
+		return "شارژ توسط پشتیبانی"
+	case "crypto":
+		return "رمزارز"
+	}
+	return "سایر"
+}
+
+// taxInvoiceCharge is a successful wallet charge to issue an invoice for.
+// Amounts are what the customer paid; credit granted on top of it by an
+// agency discount is not sold and is not invoiced.
+type taxInvoiceCharge struct {
+	Customer       models.Customer
+	Deposit        *models.Transaction
+	PaymentRequest *models.PaymentRequest // nil for crypto charges
+	AmountWithTax  uint64
+	Amount         uint64
+	Tax            uint64
+	Rate           pricing.TaxRate
+	Channel        string
+	Reference      string
+}
+
+// issueTaxInvoice numbers and saves the invoice of a charge. It must run in
+// the charge's database transaction so that a rolled back charge releases its
+// number and the sequence of the legal entity has no gaps.
+func issueTaxInvoice(ctx context.Context, repo repository.TaxInvoiceRepository, cfg config.InvoiceConfig, charge taxInvoiceCharge) (*models.TaxInvoice, error) {
+	seq, err := repo.NextSequenceNumber(ctx, cfg.LegalEntityCode)
+	if err != nil {
+		return nil, err
+	}
+	seller, err := json.Marshal(taxInvoiceSeller(cfg))
+	if err != nil {
+		return nil, err
+	}
+	buyer, err := json.Marshal(taxInvoiceBuyer(charge.Customer))
+	if err != nil {
+		return nil, err
+	}
+
+	inv := &models.TaxInvoice{
+		UUID:           uuid.New(),
+		LegalEntity:    cfg.LegalEntityCode,
+		SequenceNumber: seq,
+		InvoiceNumber:  models.TaxInvoiceNumber(cfg.LegalEntityCode, seq),
+		CustomerID:     charge.Customer.ID,
+		TransactionID:  charge.Deposit.ID,
+		PaymentChannel: charge.Channel,
+		Reference:      charge.Reference,
+		AmountWithTax:  charge.AmountWithTax,
+		Amount:         charge.Amount,
+		Tax:            charge.Tax,
+		TaxBasisPoints: charge.Rate.BasisPoints,
+		Description:    "شارژ کیف پول جاذبه",
+		Seller:         seller,
+		Buyer:          buyer,
+		IssuedAt:       utils.UTCNow(),
+	}
+	if charge.PaymentRequest != nil {
+		inv.PaymentRequestID = &charge.PaymentRequest.ID
+	}
+	if err := repo.Save(ctx, inv); err != nil {
+		return nil, err
+	}
+	return inv, nil
+}
+
+func taxInvoiceSeller(cfg config.InvoiceConfig) models.TaxInvoiceParty {
+	return models.TaxInvoiceParty{
+		Name:               cfg.SellerName,
+		EconomicCode:       cfg.SellerEconomicCode,
+		NationalID:         cfg.SellerNationalID,
+		RegistrationNumber: cfg.SellerRegistrationNumber,
+		Address:            cfg.SellerAddress,
+		PostalCode:         cfg.SellerPostalCode,
+		Phone:              cfg.SellerPhone,
+	}
+}
+
+// taxInvoiceBuyer is the customer as printed on an invoice. Companies and
+// agencies are invoiced under their registered name, individuals under the
+// representative's.
+func taxInvoiceBuyer(c models.Customer) models.TaxInvoiceParty {
+	deref := func(s *string) string {
+		if s == nil {
+			return ""
+		}
+		return strings.TrimSpace(*s)
+	}
+	name := strings.TrimSpace(c.RepresentativeFirstName + " " + c.RepresentativeLastName)
+	if company := deref(c.CompanyName); company != "" && (c.RequiresCompanyFields() || name == "") {
+		name = company
+	}
+	return models.TaxInvoiceParty{
+		Name:       name,
+		NationalID: deref(c.NationalID),
+		Address:    deref(c.CompanyAddress),
+		PostalCode: deref(c.PostalCode),
+		Phone:      deref(c.CompanyPhone),
+		Mobile:     c.RepresentativeMobile,
+		Email:      c.Email,
+	}
+}
@@ -0,0 +1,83 @@
+package businessflow
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+)
+
+func TestTaxInvoiceBuyer(t *testing.T) {
+	t.Parallel()
+
+	company := models.Customer{
+		AccountType:             models.AccountType{TypeName: models.AccountTypeIndependentCompany},
+		CompanyName:             utils.ToPtr(" Acme Co "),
+		NationalID:              utils.ToPtr("10101010101"),
+		RepresentativeFirstName: "Sara",
+		RepresentativeLastName:  "Ahmadi",
+		RepresentativeMobile:    "+989120000000",
+	}
+	buyer := taxInvoiceBuyer(company)
+	if buyer.Name != "Acme Co" || buyer.NationalID != "10101010101" || buyer.Mobile != "+989120000000" {
+		t.Fatalf("company buyer = %+v, want the registered name and national ID", buyer)
+	}
+
+	individual := company
+	individual.AccountType = models.AccountType{TypeName: models.AccountTypeIndividual}
+	if got := taxInvoiceBuyer(individual).Name; got != "Sara Ahmadi" {
+		t.Fatalf("individual buyer name = %q, want the representative's name", got)
+	}
+
+	unnamed := individual
+	unnamed.RepresentativeFirstName, unnamed.RepresentativeLastName = "", ""
+	if got := taxInvoiceBuyer(unnamed).Name; got != "Acme Co" {
+		t.Fatalf("unnamed buyer name = %q, want the company name", got)
+	}
+}
+
+func TestTaxInvoiceDocument(t *testing.T) {
+	t.Parallel()
+
+	seller, _ := json.Marshal(models.TaxInvoiceParty{Name: "Jazebeh", EconomicCode: "411111111111"})
+	buyer, _ := json.Marshal(models.TaxInvoiceParty{Name: "Acme Co"})
+	inv := &models.TaxInvoice{
+		InvoiceNumber:  models.TaxInvoiceNumber("JZB", 42),
+		PaymentChannel: "deposit_receipt",
+		AmountWithTax:  1_100_000,
+		Amount:         1_000_000,
+		Tax:            100_000,
+		TaxBasisPoints: 1000,
+		Seller:         seller,
+		Buyer:          buyer,
+	}
+	doc, err := taxInvoiceDocument(inv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.InvoiceNumber != "JZB-00000042" {
+		t.Fatalf("invoice number = %q, want JZB-00000042", doc.InvoiceNumber)
+	}
+	if doc.Seller.EconomicCode != "411111111111" || doc.Buyer.Name != "Acme Co" {
+		t.Fatalf("parties = %+v / %+v, want them as issued", doc.Seller, doc.Buyer)
+	}
+	if doc.PaymentChannel != taxInvoiceChannelLabel("deposit_receipt") || doc.PaymentChannel == taxInvoiceChannelLabel("unknown") {
+		t.Fatalf("payment channel = %q, want the deposit receipt label", doc.PaymentChannel)
+	}
+
+	inv.Buyer = []byte("{")
+	if _, err := taxInvoiceDocument(inv); err == nil {
+		t.Fatal("expected an error for a corrupt buyer snapshot")
+	}
+}
+
+func TestTaxInvoicePDFWithoutRenderer(t *testing.T) {
+	t.Parallel()
+
+	f := &TaxInvoiceFlowImpl{storageDir: t.TempDir()}
+	if _, err := f.pdf(context.Background(), &models.TaxInvoice{InvoiceNumber: "JZB-00000001"}); !IsTaxInvoicePDFUnavailable(err) {
+		t.Fatalf("err = %v, want ErrTaxInvoicePDFUnavailable", err)
+	}
+}
@@ -0,0 +1,292 @@
+package businessflow
+
+import (
+	"context"
+	crand "crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/app/i18n"
+	"github.com/amirphl/Yamata-no-Orochi/app/services"
+	"github.com/amirphl/Yamata-no-Orochi/config"
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/repository"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"gorm.io/gorm"
+)
+
+// TelegramLinkFlow links customers to a Telegram chat. The dashboard asks
+// for a deep link, the customer opens it and presses Start, the bot sends a
+// verification code to that chat, and entering the code in the dashboard
+// completes the link. Linked customers get campaign and payment notices in
+// Telegram instead of SMS.
+type TelegramLinkFlow interface {
+	StartLink(ctx context.Context, customerID uint) (*dto.StartTelegramLinkResponse, error)
+	HandleUpdate(ctx context.Context, update *dto.TelegramUpdate, secretToken string) error
+	VerifyLink(ctx context.Context, req *dto.VerifyTelegramLinkRequest, metadata *ClientMetadata) (*dto.VerifyTelegramLinkResponse, error)
+	Status(ctx context.Context, customerID uint) (*dto.TelegramLinkStatusResponse, error)
+	Unlink(ctx context.Context, customerID uint, metadata *ClientMetadata) (*dto.UnlinkTelegramResponse, error)
+}
+
+type TelegramLinkFlowImpl struct {
+	linkRepo     repository.TelegramLinkRequestRepository
+	customerRepo repository.CustomerRepository
+	auditRepo    repository.AuditLogRepository
+	db           *gorm.DB
+	cfg          config.TelegramConfig
+	notifier     services.NotificationService
+	localizer    *i18n.Localizer
+}
+
+func NewTelegramLinkFlow(
+	linkRepo repository.TelegramLinkRequestRepository,
+	customerRepo repository.CustomerRepository,
+	auditRepo repository.AuditLogRepository,
+	db *gorm.DB,
+	cfg config.TelegramConfig,
+	notifier services.NotificationService,
+	localizer *i18n.Localizer,
+) TelegramLinkFlow {
+	return &TelegramLinkFlowImpl{
+		linkRepo:     linkRepo,
+		customerRepo: customerRepo,
+		auditRepo:    auditRepo,
+		db:           db,
+		cfg:          cfg,
+		notifier:     notifier,
+		localizer:    localizer,
+	}
+}
+
+// StartLink creates a link request and returns the deep link opening the bot
+// with its token. Earlier pending requests stay valid until they expire.
+func (f *TelegramLinkFlowImpl) StartLink(ctx context.Context, customerID uint) (*dto.StartTelegramLinkResponse, error) {
+	if !f.cfg.Enabled() {
+		return nil, NewBusinessError("TELEGRAM_NOT_CONFIGURED", "Telegram notifications are not configured", ErrTelegramNotConfigured)
+	}
+	customer, err := getCustomer(ctx, f.customerRepo, customerID)
+	if err != nil {
+		return nil, NewBusinessError("START_TELEGRAM_LINK_FAILED", "Failed to find customer", err)
+	}
+
+	token, err := generateTelegramLinkToken()
+	if err != nil {
+		return nil, NewBusinessError("START_TELEGRAM_LINK_FAILED", "Failed to generate link token", err)
+	}
+	req := &models.TelegramLinkRequest{
+		CustomerID: customer.ID,
+		TokenHash:  hashOTPCode(token),
+		ExpiresAt:  utils.UTCNow().Add(f.cfg.LinkTTL),
+	}
+	if err := f.linkRepo.Save(ctx, req); err != nil {
+		return nil, NewBusinessError("START_TELEGRAM_LINK_FAILED", "Failed to save link request", err)
+	}
+
+	return &dto.StartTelegramLinkResponse{
+		Message:     "Open the link in Telegram and press Start to receive a verification code",
+		DeepLink:    fmt.Sprintf("https://t.me/%s?start=%s", f.cfg.BotUsername, token),
+		BotUsername: f.cfg.BotUsername,
+		ExpiresAt:   req.ExpiresAt,
+	}, nil
+}
+
+// HandleUpdate processes an update delivered to the bot webhook. A /start
+// command carrying a pending link token attaches the chat to the request and
+// sends the verification code to it; other updates are ignored.
+func (f *TelegramLinkFlowImpl) HandleUpdate(ctx context.Context, update *dto.TelegramUpdate, secretToken string) error {
+	if !f.cfg.Enabled() {
+		return ErrTelegramNotConfigured
+	}
+	if subtle.ConstantTimeCompare([]byte(secretToken), []byte(f.cfg.WebhookSecret)) != 1 {
+		return ErrTelegramWebhookSecretInvalid
+	}
+	if update == nil || update.Message == nil || update.Message.Chat.Type != "private" {
+		return nil
+	}
+	token, ok := telegramStartToken(update.Message.Text)
+	if !ok {
+		return nil
+	}
+	chatID := update.Message.Chat.ID
+
+	now := utils.UTCNow()
+	tokenHash := hashOTPCode(token)
+	reqs, err := f.linkRepo.ByFilter(ctx, models.TelegramLinkRequestFilter{TokenHash: &tokenHash, Pending: true, ActiveAt: &now}, "", 1, 0)
+	if err != nil {
+		return err
+	}
+	if len(reqs) == 0 {
+		f.sendTelegram(chatID, i18n.Message(f.localizer.Resolve(nil), "telegram.link_expired", nil))
+		return nil
+	}
+	req := reqs[0]
+	customer, err := getCustomer(ctx, f.customerRepo, req.CustomerID)
+	if err != nil {
+		return err
+	}
+
+	code, err := generateOTP()
+	if err != nil {
+		return err
+	}
+	if err := f.linkRepo.AttachChat(ctx, req.ID, chatID, hashOTPCode(code)); err != nil {
+		return err
+	}
+	f.sendTelegram(chatID, f.localizer.Customer(&customer, "telegram.link_code", i18n.Args{
+		"Code":    code,
+		"Minutes": int(time.Until(req.ExpiresAt).Minutes()) + 1,
+	}))
+	return nil
+}
+
+// VerifyLink completes the newest request whose chat received a code
+func (f *TelegramLinkFlowImpl) VerifyLink(ctx context.Context, req *dto.VerifyTelegramLinkRequest, metadata *ClientMetadata) (*dto.VerifyTelegramLinkResponse, error) {
+	customer, err := getCustomer(ctx, f.customerRepo, req.CustomerID)
+	if err != nil {
+		return nil, NewBusinessError("VERIFY_TELEGRAM_LINK_FAILED", "Failed to find customer", err)
+	}
+	link, err := f.awaitingCode(ctx, customer.ID)
+	if err != nil {
+		return nil, NewBusinessError("VERIFY_TELEGRAM_LINK_FAILED", "Failed to find link request", err)
+	}
+	if link == nil {
+		return nil, NewBusinessError("TELEGRAM_LINK_NOT_FOUND", "No Telegram chat is waiting for a verification code", ErrTelegramLinkNotFound)
+	}
+	if link.Attempts >= authOTPMaxAttempts {
+		return nil, NewBusinessError("TELEGRAM_LINK_LOCKED", "Too many wrong codes; create a new link", ErrTelegramLinkLocked)
+	}
+	if !verifyOTPCodeHash(req.Code, *link.CodeHash) {
+		if err := f.linkRepo.IncrementAttempts(ctx, link.ID); err != nil {
+			log.Printf("telegram link %d: count wrong code: %v", link.ID, err)
+		}
+		return nil, NewBusinessError("TELEGRAM_CODE_INVALID", "Invalid verification code", ErrTelegramCodeInvalid)
+	}
+
+	linkedAt := utils.UTCNow()
+	err = repository.WithTransaction(ctx, f.db, func(txCtx context.Context) error {
+		if err := f.customerRepo.UpdateTelegramChat(txCtx, customer.ID, link.ChatID, &linkedAt); err != nil {
+			return err
+		}
+		return f.linkRepo.MarkLinked(txCtx, link.ID, linkedAt)
+	})
+	if err != nil {
+		return nil, NewBusinessError("VERIFY_TELEGRAM_LINK_FAILED", "Failed to link Telegram chat", err)
+	}
+	_ = createAuditLog(ctx, f.auditRepo, &customer, models.AuditActionTelegramLinked, "Telegram chat linked for notices", true, nil, metadata)
+	f.sendTelegram(*link.ChatID, f.localizer.Customer(&customer, "telegram.linked", nil))
+
+	return &dto.VerifyTelegramLinkResponse{Message: "Telegram linked", LinkedAt: linkedAt}, nil
+}
+
+// Status reports whether the customer's notices go to Telegram
+func (f *TelegramLinkFlowImpl) Status(ctx context.Context, customerID uint) (*dto.TelegramLinkStatusResponse, error) {
+	customer, err := getCustomer(ctx, f.customerRepo, customerID)
+	if err != nil {
+		return nil, NewBusinessError("GET_TELEGRAM_LINK_FAILED", "Failed to find customer", err)
+	}
+	res := &dto.TelegramLinkStatusResponse{
+		Enabled:  f.cfg.Enabled(),
+		Linked:   customer.TelegramChatID != nil,
+		LinkedAt: customer.TelegramLinkedAt,
+	}
+	if !res.Enabled {
+		return res, nil
+	}
+	res.BotUsername = f.cfg.BotUsername
+	link, err := f.awaitingCode(ctx, customer.ID)
+	if err != nil {
+		return nil, NewBusinessError("GET_TELEGRAM_LINK_FAILED", "Failed to find link request", err)
+	}
+	if link != nil {
+		res.Pending = true
+		res.PendingExpiresAt = &link.ExpiresAt
+	}
+	return res, nil
+}
+
+// Unlink moves the customer's notices back to SMS
+func (f *TelegramLinkFlowImpl) Unlink(ctx context.Context, customerID uint, metadata *ClientMetadata) (*dto.UnlinkTelegramResponse, error) {
+	customer, err := getCustomer(ctx, f.customerRepo, customerID)
+	if err != nil {
+		return nil, NewBusinessError("UNLINK_TELEGRAM_FAILED", "Failed to find customer", err)
+	}
+	if customer.TelegramChatID == nil {
+		return &dto.UnlinkTelegramResponse{Message: "Telegram is not linked"}, nil
+	}
+	if err := f.customerRepo.UpdateTelegramChat(ctx, customer.ID, nil, nil); err != nil {
+		return nil, NewBusinessError("UNLINK_TELEGRAM_FAILED", "Failed to unlink Telegram chat", err)
+	}
+	_ = createAuditLog(ctx, f.auditRepo, &customer, models.AuditActionTelegramUnlinked, "Telegram chat unlinked", true, nil, metadata)
+	return &dto.UnlinkTelegramResponse{Message: "Telegram unlinked"}, nil
+}
+
+// awaitingCode returns the newest unexpired request of the customer whose
+// chat was sent a code, or nil
+func (f *TelegramLinkFlowImpl) awaitingCode(ctx context.Context, customerID uint) (*models.TelegramLinkRequest, error) {
+	now := utils.UTCNow()
+	reqs, err := f.linkRepo.ByFilter(ctx, models.TelegramLinkRequestFilter{CustomerID: &customerID, Pending: true, ActiveAt: &now}, "updated_at DESC, id DESC", 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range reqs {
+		if r.ChatID != nil && r.CodeHash != nil {
+			return r, nil
+		}
+	}
+	return nil, nil
+}
+
+// sendTelegram replies to a chat; like the SMS notices it is best effort
+func (f *TelegramLinkFlowImpl) sendTelegram(chatID int64, message string) {
+	if f.notifier == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := f.notifier.SendTelegram(ctx, chatID, message); err != nil {
+		log.Printf("telegram reply to chat %d: %v", chatID, err)
+	}
+}
+
+// telegramStartToken extracts the deep-link token from a "/start <token>"
+// message
+func telegramStartToken(text string) (string, bool) {
+	cmd, token, ok := strings.Cut(strings.TrimSpace(text), " ")
+	if !ok || (cmd != "/start" && !strings.HasPrefix(cmd, "/start@")) {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+// generateTelegramLinkToken returns a token that fits the 64 character
+// limit Telegram sets on start parameters
+func generateTelegramLinkToken() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := crand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// sendCustomerNotice delivers a campaign or payment notice to the customer's
+// push devices, and to the linked Telegram chat or by SMS when no chat is
+// linked or Telegram fails. Push is sent in addition, since a browser may be
+// closed; only the Telegram or SMS outcome is returned.
+func sendCustomerNotice(ctx context.Context, notifier services.NotificationService, pushDevices repository.PushDeviceRepository, customer *models.Customer, title, message string) error {
+	sendCustomerPush(ctx, notifier, pushDevices, customer.ID, services.PushMessage{Title: title, Body: message})
+	if customer.TelegramChatID != nil {
+		err := notifier.SendTelegram(ctx, *customer.TelegramChatID, message)
+		if err == nil {
+			return nil
+		}
+		log.Printf("telegram notice to customer %d failed, falling back to SMS: %v", customer.ID, err)
+	}
+	id64 := int64(customer.ID)
+	return notifier.SendSMS(ctx, normalizeIranMobile(customer.RepresentativeMobile), message, &id64)
+}
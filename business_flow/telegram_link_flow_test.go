@@ -0,0 +1,199 @@
+package businessflow
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/app/i18n"
+	"github.com/amirphl/Yamata-no-Orochi/app/services"
+	"github.com/amirphl/Yamata-no-Orochi/config"
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/repository"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+)
+
+type recordingNotifier struct {
+	services.NotificationService
+	telegramErr error
+	telegram    map[int64][]string
+	sms         map[string][]string
+	pushErr     map[string]error
+	push        map[string][]services.PushMessage
+}
+
+func (n *recordingNotifier) SendPush(_ context.Context, token string, msg services.PushMessage) error {
+	if err := n.pushErr[token]; err != nil {
+		return err
+	}
+	if n.push == nil {
+		n.push = map[string][]services.PushMessage{}
+	}
+	n.push[token] = append(n.push[token], msg)
+	return nil
+}
+
+func (n *recordingNotifier) SendTelegram(_ context.Context, chatID int64, message string) error {
+	if n.telegramErr != nil {
+		return n.telegramErr
+	}
+	if n.telegram == nil {
+		n.telegram = map[int64][]string{}
+	}
+	n.telegram[chatID] = append(n.telegram[chatID], message)
+	return nil
+}
+
+func (n *recordingNotifier) SendSMS(_ context.Context, mobile, message string, _ *int64) error {
+	if n.sms == nil {
+		n.sms = map[string][]string{}
+	}
+	n.sms[mobile] = append(n.sms[mobile], message)
+	return nil
+}
+
+type telegramLinkRepoStub struct {
+	repository.TelegramLinkRequestRepository
+	requests []*models.TelegramLinkRequest
+}
+
+func (r *telegramLinkRepoStub) ByFilter(_ context.Context, filter models.TelegramLinkRequestFilter, _ string, _, _ int) ([]*models.TelegramLinkRequest, error) {
+	var out []*models.TelegramLinkRequest
+	for _, req := range r.requests {
+		if filter.TokenHash != nil && req.TokenHash != *filter.TokenHash {
+			continue
+		}
+		if filter.Pending && req.LinkedAt != nil {
+			continue
+		}
+		if filter.ActiveAt != nil && !req.ExpiresAt.After(*filter.ActiveAt) {
+			continue
+		}
+		out = append(out, req)
+	}
+	return out, nil
+}
+
+func (r *telegramLinkRepoStub) AttachChat(_ context.Context, id uint, chatID int64, codeHash string) error {
+	for _, req := range r.requests {
+		if req.ID == id {
+			req.ChatID, req.CodeHash, req.Attempts = &chatID, &codeHash, 0
+		}
+	}
+	return nil
+}
+
+type activeCustomerRepoStub struct {
+	repository.CustomerRepository
+}
+
+func (activeCustomerRepoStub) ByID(_ context.Context, id uint) (*models.Customer, error) {
+	return &models.Customer{ID: id, IsActive: utils.ToPtr(true)}, nil
+}
+
+func TestTelegramStartToken(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		text  string
+		token string
+		ok    bool
+	}{
+		{"/start abc123", "abc123", true},
+		{"  /start   abc123 ", "abc123", true},
+		{"/start@JaazebehBot abc123", "abc123", true},
+		{"/start", "", false},
+		{"/start ", "", false},
+		{"/help abc123", "", false},
+		{"hello", "", false},
+	}
+	for _, tc := range cases {
+		token, ok := telegramStartToken(tc.text)
+		if token != tc.token || ok != tc.ok {
+			t.Errorf("telegramStartToken(%q) = %q, %v; want %q, %v", tc.text, token, ok, tc.token, tc.ok)
+		}
+	}
+}
+
+func TestSendCustomerNotice(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	customer := &models.Customer{ID: 7, RepresentativeMobile: "+989121234567"}
+
+	n := &recordingNotifier{}
+	if err := sendCustomerNotice(ctx, n, nil, customer, "Campaign approved", "approved"); err != nil {
+		t.Fatal(err)
+	}
+	if len(n.sms) != 1 || len(n.telegram) != 0 {
+		t.Fatalf("unlinked customer should get SMS, got sms=%v telegram=%v", n.sms, n.telegram)
+	}
+
+	customer.TelegramChatID = utils.ToPtr(int64(42))
+	n = &recordingNotifier{}
+	if err := sendCustomerNotice(ctx, n, nil, customer, "Campaign approved", "approved"); err != nil {
+		t.Fatal(err)
+	}
+	if len(n.sms) != 0 || len(n.telegram[42]) != 1 {
+		t.Fatalf("linked customer should get Telegram, got sms=%v telegram=%v", n.sms, n.telegram)
+	}
+
+	n = &recordingNotifier{telegramErr: errors.New("bot was blocked by the user")}
+	if err := sendCustomerNotice(ctx, n, nil, customer, "Campaign approved", "approved"); err != nil {
+		t.Fatal(err)
+	}
+	if len(n.sms) != 1 {
+		t.Fatalf("failed Telegram delivery should fall back to SMS, got sms=%v", n.sms)
+	}
+}
+
+func TestTelegramHandleUpdate(t *testing.T) {
+	t.Parallel()
+
+	repo := &telegramLinkRepoStub{requests: []*models.TelegramLinkRequest{
+		{ID: 1, CustomerID: 7, TokenHash: hashOTPCode("live"), ExpiresAt: utils.UTCNow().Add(10 * time.Minute)},
+		{ID: 2, CustomerID: 7, TokenHash: hashOTPCode("old"), ExpiresAt: utils.UTCNow().Add(-time.Minute)},
+	}}
+	notifier := &recordingNotifier{}
+	cfg := config.TelegramConfig{BotToken: "123:abc", BotUsername: "JaazebehBot", WebhookSecret: "s3cret", LinkTTL: 15 * time.Minute}
+	flow := NewTelegramLinkFlow(repo, activeCustomerRepoStub{}, nil, nil, cfg, notifier, i18n.NewLocalizer("en", "en"))
+	ctx := context.Background()
+	start := func(chatID int64, text string) *dto.TelegramUpdate {
+		return &dto.TelegramUpdate{Message: &dto.TelegramMessage{Chat: dto.TelegramChat{ID: chatID, Type: "private"}, Text: text}}
+	}
+
+	if err := flow.HandleUpdate(ctx, start(42, "/start live"), "wrong"); !IsTelegramWebhookSecretInvalid(err) {
+		t.Fatalf("expected secret error, got %v", err)
+	}
+
+	if err := flow.HandleUpdate(ctx, start(42, "/start live"), "s3cret"); err != nil {
+		t.Fatal(err)
+	}
+	req := repo.requests[0]
+	if req.ChatID == nil || *req.ChatID != 42 || req.CodeHash == nil {
+		t.Fatalf("chat not attached: %+v", req)
+	}
+	if msgs := notifier.telegram[42]; len(msgs) != 1 || !strings.Contains(msgs[0], "verification code") {
+		t.Fatalf("expected the code in chat 42, got %v", msgs)
+	}
+
+	if err := flow.HandleUpdate(ctx, start(43, "/start old"), "s3cret"); err != nil {
+		t.Fatal(err)
+	}
+	if repo.requests[1].ChatID != nil {
+		t.Fatal("expired request must not be attached")
+	}
+	if msgs := notifier.telegram[43]; len(msgs) != 1 || !strings.Contains(msgs[0], "expired") {
+		t.Fatalf("expected an expiry reply in chat 43, got %v", msgs)
+	}
+
+	// Group chats and other commands are ignored
+	group := start(44, "/start live")
+	group.Message.Chat.Type = "group"
+	if err := flow.HandleUpdate(ctx, group, "s3cret"); err != nil || len(notifier.telegram[44]) != 0 {
+		t.Fatalf("group update must be ignored, got %v %v", err, notifier.telegram[44])
+	}
+}
@@ -13,7 +13,8 @@ import (
 	"time"
 
 	"github.com/amirphl/Yamata-no-Orochi/app/dto"
-	"github.com/amirphl/Yamata-no-Orochi/app/services"
+	"github.com/amirphl/Yamata-no-Orochi/app/httpclient"
+	"github.com/amirphl/Yamata-no-Orochi/app/i18n"
 	"github.com/amirphl/Yamata-no-Orochi/config"
 	"github.com/amirphl/Yamata-no-Orochi/models"
 	"github.com/amirphl/Yamata-no-Orochi/repository"
@@ -35,12 +36,13 @@ type TicketFlow interface {
 type TicketFlowImpl struct {
 	customerRepo repository.CustomerRepository
 	ticketRepo   repository.TicketRepository
-	notifier     services.NotificationService
+	jobs         JobQueue
 	adminCfg     config.AdminConfig
+	localizer    *i18n.Localizer
 }
 
-func NewTicketFlow(customerRepo repository.CustomerRepository, ticketRepo repository.TicketRepository, notifier services.NotificationService, adminCfg config.AdminConfig) TicketFlow {
-	return &TicketFlowImpl{customerRepo: customerRepo, ticketRepo: ticketRepo, notifier: notifier, adminCfg: adminCfg}
+func NewTicketFlow(customerRepo repository.CustomerRepository, ticketRepo repository.TicketRepository, jobs JobQueue, adminCfg config.AdminConfig, localizer *i18n.Localizer) TicketFlow {
+	return &TicketFlowImpl{customerRepo: customerRepo, ticketRepo: ticketRepo, jobs: jobs, adminCfg: adminCfg, localizer: localizer}
 }
 
 const (
@@ -103,12 +105,8 @@ func (f *TicketFlowImpl) CreateTicket(ctx context.Context, req *dto.CreateTicket
 	}
 
 	// Notify admins via SMS (best-effort)
-	if f.notifier != nil {
-		msg := fmt.Sprintf("New ticket: %s (customer %d)", truncate(req.Title, 50), customer.ID)
-		for _, mobile := range f.adminCfg.ActiveMobiles() {
-			_ = f.notifier.SendSMS(ctx, mobile, msg, nil)
-		}
-	}
+	msg := f.localizer.Admin("admin.ticket_created", i18n.Args{"Title": truncate(req.Title, 50), "CustomerID": customer.ID})
+	enqueueSMS(ctx, f.jobs, f.adminCfg.ActiveMobiles(), msg)
 
 	return &dto.CreateTicketResponse{
 		Message:       "Ticket created successfully",
@@ -176,21 +174,14 @@ func (f *TicketFlowImpl) CreateResponseTicket(ctx context.Context, req *dto.Crea
 	}
 
 	// Send SMS notification to admins
-	if f.notifier != nil {
-		// translate to en
-		msg := fmt.Sprintf("New response to ticket %d from customer %s %s\nTitle: %s\nContent: %s",
-			orig.ID,
-			customer.RepresentativeFirstName,
-			customer.RepresentativeLastName,
-			truncate(orig.Title, 30),
-			truncate(req.Content, 50),
-		)
-		go func() {
-			for _, mobile := range f.adminCfg.ActiveMobiles() {
-				_ = f.notifier.SendSMS(context.Background(), mobile, msg, nil)
-			}
-		}()
-	}
+	msg := f.localizer.Admin("admin.ticket_replied", i18n.Args{
+		"TicketID":  orig.ID,
+		"FirstName": customer.RepresentativeFirstName,
+		"LastName":  customer.RepresentativeLastName,
+		"Title":     truncate(orig.Title, 30),
+		"Content":   truncate(req.Content, 50),
+	})
+	enqueueSMS(ctx, f.jobs, f.adminCfg.ActiveMobiles(), msg)
 
 	return &dto.CreateResponseTicketResponse{
 		Message:       "Response ticket created successfully",
@@ -539,7 +530,7 @@ func (f *TicketFlowImpl) saveFileToDisk(ctx context.Context, urlStr string, orig
 	if err != nil {
 		return "", err
 	}
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := httpclient.New("ticket_attachment", 60*time.Second).Do(req)
 	if err != nil {
 		return "", err
 	}
@@ -0,0 +1,58 @@
+package businessflow
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/repository"
+	"gorm.io/gorm"
+)
+
+const (
+	// versionRetryAttempts bounds how often a transaction that lost a
+	// compare-and-swap race is run again before the conflict is returned
+	versionRetryAttempts = 4
+	// versionRetryBackoff is the base delay between attempts; each attempt
+	// waits a random duration up to attempt * versionRetryBackoff
+	versionRetryBackoff = 25 * time.Millisecond
+)
+
+// withVersionRetry runs fn in a transaction like repository.WithTransaction,
+// and runs it again in a fresh transaction when it fails with
+// repository.ErrVersionConflict. fn must read every wallet and payment
+// request it writes inside the transaction, so a retry starts from the state
+// the concurrent writer committed, and must not keep side effects outside the
+// database before it returns.
+func withVersionRetry(ctx context.Context, db *gorm.DB, fn func(txCtx context.Context) error) error {
+	return retryOnVersionConflict(ctx, versionRetryAttempts, versionRetryBackoff, func() error {
+		return repository.WithTransaction(ctx, db, fn)
+	})
+}
+
+// retryOnVersionConflict calls run until it succeeds, fails with anything
+// other than a version conflict, attempts are used up or ctx is done
+func retryOnVersionConflict(ctx context.Context, attempts int, backoff time.Duration, run func() error) error {
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		err = run()
+		if !errors.Is(err, repository.ErrVersionConflict) || attempt == attempts {
+			return err
+		}
+
+		if ctx.Err() != nil {
+			return err
+		}
+		delay := backoff
+		if backoff > 0 {
+			delay = rand.N(time.Duration(attempt)*backoff) + 1
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+	}
+	return err
+}
@@ -0,0 +1,61 @@
+package businessflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/amirphl/Yamata-no-Orochi/repository"
+)
+
+func TestRetryOnVersionConflict(t *testing.T) {
+	t.Parallel()
+
+	conflict := fmt.Errorf("update wallet: %w", repository.ErrVersionConflict)
+	other := errors.New("insufficient funds")
+	cases := []struct {
+		name      string
+		results   []error
+		wantCalls int
+		wantErr   error
+	}{
+		{name: "first attempt succeeds", results: []error{nil}, wantCalls: 1},
+		{name: "succeeds after conflicts", results: []error{conflict, conflict, nil}, wantCalls: 3},
+		{name: "other errors are not retried", results: []error{other}, wantCalls: 1, wantErr: other},
+		{name: "conflict after retry fails with other error", results: []error{conflict, other}, wantCalls: 2, wantErr: other},
+		{name: "attempts used up", results: []error{conflict, conflict, conflict, conflict}, wantCalls: 3, wantErr: repository.ErrVersionConflict},
+	}
+	for _, tc := range cases {
+		calls := 0
+		err := retryOnVersionConflict(context.Background(), 3, 0, func() error {
+			err := tc.results[calls]
+			calls++
+			return err
+		})
+		if calls != tc.wantCalls {
+			t.Fatalf("%s: got %d calls, want %d", tc.name, calls, tc.wantCalls)
+		}
+		if tc.wantErr == nil && err != nil {
+			t.Fatalf("%s: unexpected error %v", tc.name, err)
+		}
+		if tc.wantErr != nil && !errors.Is(err, tc.wantErr) {
+			t.Fatalf("%s: got error %v, want %v", tc.name, err, tc.wantErr)
+		}
+	}
+}
+
+func TestRetryOnVersionConflictStopsWhenContextDone(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	calls := 0
+	err := retryOnVersionConflict(ctx, 3, 0, func() error {
+		calls++
+		return repository.ErrVersionConflict
+	})
+	if calls != 1 || !errors.Is(err, repository.ErrVersionConflict) {
+		t.Fatalf("got %d calls and error %v, want 1 call and a version conflict", calls, err)
+	}
+}
@@ -0,0 +1,368 @@
+package businessflow
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/repository"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// WalletAdjustmentFlow lets finance admins correct customer balances, e.g.
+// for chargebacks or goodwill credit, under dual approval: one admin requests
+// an adjustment with a reason code and a second admin approves or rejects it.
+// The balance snapshot and adjustment transaction are only created on
+// approval.
+type WalletAdjustmentFlow interface {
+	Create(ctx context.Context, req *dto.AdminCreateWalletAdjustmentRequest, adminID uint) (*dto.AdminWalletAdjustmentResponse, error)
+	Review(ctx context.Context, id uuid.UUID, req *dto.AdminReviewWalletAdjustmentRequest, adminID uint) (*dto.AdminWalletAdjustmentResponse, error)
+	List(ctx context.Context, filter dto.AdminListWalletAdjustmentsFilter) (*dto.AdminListWalletAdjustmentsResponse, error)
+}
+
+type WalletAdjustmentFlowImpl struct {
+	db                  *gorm.DB
+	adjustmentRepo      repository.WalletAdjustmentRequestRepository
+	customerRepo        repository.CustomerRepository
+	walletRepo          repository.WalletRepository
+	balanceSnapshotRepo repository.BalanceSnapshotRepository
+	transactionRepo     repository.TransactionRepository
+	auditRepo           repository.AuditLogRepository
+}
+
+func NewWalletAdjustmentFlow(
+	db *gorm.DB,
+	adjustmentRepo repository.WalletAdjustmentRequestRepository,
+	customerRepo repository.CustomerRepository,
+	walletRepo repository.WalletRepository,
+	balanceSnapshotRepo repository.BalanceSnapshotRepository,
+	transactionRepo repository.TransactionRepository,
+	auditRepo repository.AuditLogRepository,
+) WalletAdjustmentFlow {
+	return &WalletAdjustmentFlowImpl{
+		db:                  db,
+		adjustmentRepo:      adjustmentRepo,
+		customerRepo:        customerRepo,
+		walletRepo:          walletRepo,
+		balanceSnapshotRepo: balanceSnapshotRepo,
+		transactionRepo:     transactionRepo,
+		auditRepo:           auditRepo,
+	}
+}
+
+// Create records a pending adjustment. The customer's wallet is not touched
+// until another admin approves it.
+func (f *WalletAdjustmentFlowImpl) Create(ctx context.Context, req *dto.AdminCreateWalletAdjustmentRequest, adminID uint) (*dto.AdminWalletAdjustmentResponse, error) {
+	if req == nil {
+		return nil, NewBusinessError("VALIDATION_ERROR", "Request is required", nil)
+	}
+	direction := models.WalletAdjustmentDirection(req.Direction)
+	reason := models.WalletAdjustmentReason(req.ReasonCode)
+	if !reason.Allows(direction) {
+		return nil, NewBusinessError("WALLET_ADJUSTMENT_REASON_INVALID", fmt.Sprintf("Reason %s does not apply to a %s", reason, direction), ErrWalletAdjustmentReasonInvalid)
+	}
+
+	meta := map[string]any{
+		"customer_id": req.CustomerID,
+		"direction":   req.Direction,
+		"amount":      req.Amount,
+		"reason_code": req.ReasonCode,
+		"note":        strings.TrimSpace(req.Note),
+	}
+	customer, err := getCustomer(ctx, f.customerRepo, req.CustomerID)
+	if err != nil {
+		logAdminAction(ctx, f.auditRepo, models.AuditActionAdminWalletAdjustmentRequested, "Wallet adjustment request failed", false, &req.CustomerID, meta, err)
+		return nil, NewBusinessError("WALLET_ADJUSTMENT_CREATE_FAILED", "Failed to find customer", err)
+	}
+
+	now := utils.UTCNow()
+	adj := &models.WalletAdjustmentRequest{
+		UUID:               uuid.New(),
+		CorrelationID:      uuid.New(),
+		CustomerID:         customer.ID,
+		Direction:          direction,
+		Amount:             req.Amount,
+		ReasonCode:         reason,
+		Note:               strings.TrimSpace(req.Note),
+		Status:             models.WalletAdjustmentStatusPending,
+		RequestedByAdminID: adminID,
+		CreatedAt:          now,
+		UpdatedAt:          now,
+	}
+	if err := f.adjustmentRepo.Save(ctx, adj); err != nil {
+		logAdminAction(ctx, f.auditRepo, models.AuditActionAdminWalletAdjustmentRequested, "Wallet adjustment request failed", false, &req.CustomerID, meta, err)
+		return nil, NewBusinessError("WALLET_ADJUSTMENT_CREATE_FAILED", "Failed to create wallet adjustment", err)
+	}
+	meta["adjustment_uuid"] = adj.UUID.String()
+	logAdminAction(ctx, f.auditRepo, models.AuditActionAdminWalletAdjustmentRequested, "Wallet adjustment requested", true, &adj.CustomerID, meta, nil)
+
+	return &dto.AdminWalletAdjustmentResponse{
+		Message:    "Wallet adjustment requested; it takes effect once another admin approves it",
+		Adjustment: walletAdjustmentItem(adj),
+	}, nil
+}
+
+// Review approves or rejects a pending adjustment. Approving applies it to
+// the customer's wallet in the same transaction that marks it reviewed.
+func (f *WalletAdjustmentFlowImpl) Review(ctx context.Context, id uuid.UUID, req *dto.AdminReviewWalletAdjustmentRequest, adminID uint) (*dto.AdminWalletAdjustmentResponse, error) {
+	if req == nil {
+		return nil, NewBusinessError("VALIDATION_ERROR", "Request is required", nil)
+	}
+	approve := req.Action == "approve"
+	action := models.AuditActionAdminWalletAdjustmentRejected
+	if approve {
+		action = models.AuditActionAdminWalletAdjustmentApproved
+	}
+	meta := map[string]any{"adjustment_uuid": id.String(), "action": req.Action, "review_note": strings.TrimSpace(req.Note)}
+
+	var adj *models.WalletAdjustmentRequest
+	err := withVersionRetry(ctx, f.db, func(txCtx context.Context) error {
+		var err error
+		adj, err = f.adjustmentRepo.ByUUID(txCtx, id)
+		if err != nil {
+			return err
+		}
+		if adj == nil {
+			return ErrWalletAdjustmentNotFound
+		}
+		if adj.Status != models.WalletAdjustmentStatusPending {
+			return ErrWalletAdjustmentNotPending
+		}
+		if adj.RequestedByAdminID == adminID {
+			return ErrWalletAdjustmentSelfReview
+		}
+
+		now := utils.UTCNow()
+		adj.ReviewedByAdminID = &adminID
+		adj.ReviewNote = strings.TrimSpace(req.Note)
+		adj.ReviewedAt = &now
+		adj.UpdatedAt = now
+		adj.Status = models.WalletAdjustmentStatusRejected
+		if approve {
+			adj.Status = models.WalletAdjustmentStatusApproved
+			tx, err := f.applyAdjustment(txCtx, adj)
+			if err != nil {
+				return err
+			}
+			adj.TransactionID = &tx.ID
+		}
+
+		// Another reviewer may have acted since the request was read
+		reviewed, err := f.adjustmentRepo.MarkReviewed(txCtx, adj)
+		if err != nil {
+			return err
+		}
+		if !reviewed {
+			return ErrWalletAdjustmentNotPending
+		}
+		return nil
+	})
+
+	var customerID *uint
+	if adj != nil {
+		customerID = &adj.CustomerID
+		meta["direction"] = adj.Direction
+		meta["amount"] = adj.Amount
+		meta["reason_code"] = adj.ReasonCode
+		meta["requested_by_admin_id"] = adj.RequestedByAdminID
+	}
+	if err != nil {
+		logAdminAction(ctx, f.auditRepo, action, "Wallet adjustment review failed", false, customerID, meta, err)
+		switch {
+		case IsWalletAdjustmentNotFound(err):
+			return nil, NewBusinessError("WALLET_ADJUSTMENT_NOT_FOUND", "Wallet adjustment not found", err)
+		case IsWalletAdjustmentNotPending(err):
+			return nil, NewBusinessError("WALLET_ADJUSTMENT_NOT_PENDING", "Wallet adjustment was already reviewed", err)
+		case IsWalletAdjustmentSelfReview(err):
+			return nil, NewBusinessError("WALLET_ADJUSTMENT_SELF_REVIEW", "Wallet adjustment must be reviewed by another admin", err)
+		case IsInsufficientFunds(err):
+			return nil, NewBusinessError("INSUFFICIENT_FUNDS", "Wallet balance does not cover the debit", err)
+		}
+		return nil, NewBusinessError("WALLET_ADJUSTMENT_REVIEW_FAILED", "Failed to review wallet adjustment", err)
+	}
+
+	meta["transaction_id"] = adj.TransactionID
+	message := "Wallet adjustment rejected"
+	if approve {
+		message = "Wallet adjustment approved and applied"
+	}
+	logAdminAction(ctx, f.auditRepo, action, message, true, customerID, meta, nil)
+
+	return &dto.AdminWalletAdjustmentResponse{
+		Message:    message,
+		Adjustment: walletAdjustmentItem(adj),
+	}, nil
+}
+
+// List returns adjustment requests, newest first
+func (f *WalletAdjustmentFlowImpl) List(ctx context.Context, filter dto.AdminListWalletAdjustmentsFilter) (*dto.AdminListWalletAdjustmentsResponse, error) {
+	page := max(1, filter.Page)
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 10
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	offset := (page - 1) * limit
+
+	af := models.WalletAdjustmentRequestFilter{CustomerID: filter.CustomerID}
+	if filter.Status != nil && *filter.Status != "" {
+		status := models.WalletAdjustmentStatus(*filter.Status)
+		af.Status = &status
+	}
+
+	total, err := f.adjustmentRepo.Count(ctx, af)
+	if err != nil {
+		return nil, NewBusinessError("WALLET_ADJUSTMENT_LIST_FAILED", "Failed to count wallet adjustments", err)
+	}
+	rows, err := f.adjustmentRepo.ByFilter(ctx, af, "id DESC", limit, offset)
+	if err != nil {
+		return nil, NewBusinessError("WALLET_ADJUSTMENT_LIST_FAILED", "Failed to list wallet adjustments", err)
+	}
+
+	items := make([]dto.WalletAdjustmentItem, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, walletAdjustmentItem(row))
+	}
+	return &dto.AdminListWalletAdjustmentsResponse{
+		Message: "Wallet adjustments retrieved successfully",
+		Items:   items,
+		Pagination: dto.PaginationInfo{
+			Total:      total,
+			Page:       page,
+			Limit:      limit,
+			TotalPages: int((total + int64(limit) - 1) / int64(limit)),
+		},
+	}, nil
+}
+
+// applyAdjustment writes the balance snapshot and adjustment transaction of
+// an approved request
+func (f *WalletAdjustmentFlowImpl) applyAdjustment(ctx context.Context, adj *models.WalletAdjustmentRequest) (*models.Transaction, error) {
+	wallet, err := getWallet(ctx, f.walletRepo, adj.CustomerID)
+	if err != nil {
+		return nil, err
+	}
+	latest, err := getLatestBalanceSnapshot(ctx, f.walletRepo, wallet.ID)
+	if err != nil {
+		return nil, err
+	}
+	newFree, newCredit, err := adjustedBalances(latest, adj.Direction, adj.Amount)
+	if err != nil {
+		return nil, err
+	}
+
+	description := fmt.Sprintf("Wallet %s by admin adjustment (%s)", adj.Direction, adj.ReasonCode)
+	metaBytes, err := json.Marshal(map[string]any{
+		"source":                "admin_wallet_adjustment",
+		"operation":             "wallet_adjustment_" + string(adj.Direction),
+		"adjustment_uuid":       adj.UUID.String(),
+		"direction":             adj.Direction,
+		"reason_code":           adj.ReasonCode,
+		"note":                  adj.Note,
+		"requested_by_admin_id": adj.RequestedByAdminID,
+		"approved_by_admin_id":  adj.ReviewedByAdminID,
+		"review_note":           adj.ReviewNote,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	newSnap := &models.BalanceSnapshot{
+		UUID:               uuid.New(),
+		CorrelationID:      adj.CorrelationID,
+		WalletID:           wallet.ID,
+		CustomerID:         adj.CustomerID,
+		FreeBalance:        newFree,
+		FrozenBalance:      latest.FrozenBalance,
+		LockedBalance:      latest.LockedBalance,
+		CreditBalance:      newCredit,
+		SpentOnCampaign:    latest.SpentOnCampaign,
+		AgencyShareWithTax: latest.AgencyShareWithTax,
+		TotalBalance:       newFree + newCredit + latest.FrozenBalance + latest.LockedBalance + latest.SpentOnCampaign + latest.AgencyShareWithTax,
+		Reason:             "wallet_adjustment",
+		Description:        description,
+		Metadata:           metaBytes,
+	}
+	if err := f.walletRepo.AdvanceVersion(ctx, &wallet); err != nil {
+		return nil, err
+	}
+	if err := f.balanceSnapshotRepo.Save(ctx, newSnap); err != nil {
+		return nil, err
+	}
+
+	beforeMap, err := latest.GetBalanceMap()
+	if err != nil {
+		return nil, err
+	}
+	afterMap, err := newSnap.GetBalanceMap()
+	if err != nil {
+		return nil, err
+	}
+	tx := &models.Transaction{
+		UUID:          uuid.New(),
+		CorrelationID: adj.CorrelationID,
+		Type:          models.TransactionTypeAdjustment,
+		Status:        models.TransactionStatusCompleted,
+		Amount:        adj.Amount,
+		Currency:      utils.TomanCurrency,
+		WalletID:      wallet.ID,
+		CustomerID:    adj.CustomerID,
+		BalanceBefore: beforeMap,
+		BalanceAfter:  afterMap,
+		Description:   description,
+		Metadata:      metaBytes,
+	}
+	if err := f.transactionRepo.Save(ctx, tx); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// adjustedBalances returns the free and credit balances after an adjustment.
+// Credits are added to the free balance; debits are taken from the free
+// balance first and then from credit, as campaign spending is.
+func adjustedBalances(latest models.BalanceSnapshot, direction models.WalletAdjustmentDirection, amount uint64) (uint64, uint64, error) {
+	free, credit := latest.FreeBalance, latest.CreditBalance
+	switch direction {
+	case models.WalletAdjustmentCredit:
+		return free + amount, credit, nil
+	case models.WalletAdjustmentDebit:
+		if free+credit < amount {
+			return 0, 0, ErrInsufficientFunds
+		}
+		if amount <= free {
+			return free - amount, credit, nil
+		}
+		return 0, credit - (amount - free), nil
+	}
+	return 0, 0, fmt.Errorf("unknown adjustment direction %q", direction)
+}
+
+func walletAdjustmentItem(adj *models.WalletAdjustmentRequest) dto.WalletAdjustmentItem {
+	item := dto.WalletAdjustmentItem{
+		UUID:               adj.UUID.String(),
+		CustomerID:         adj.CustomerID,
+		Direction:          string(adj.Direction),
+		Amount:             adj.Amount,
+		ReasonCode:         string(adj.ReasonCode),
+		Note:               adj.Note,
+		Status:             string(adj.Status),
+		RequestedByAdminID: adj.RequestedByAdminID,
+		ReviewedByAdminID:  adj.ReviewedByAdminID,
+		ReviewNote:         adj.ReviewNote,
+		TransactionID:      adj.TransactionID,
+		CreatedAt:          adj.CreatedAt.Format(time.RFC3339),
+	}
+	if adj.ReviewedAt != nil {
+		item.ReviewedAt = utils.ToPtr(adj.ReviewedAt.Format(time.RFC3339))
+	}
+	return item
+}
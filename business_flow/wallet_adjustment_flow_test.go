@@ -0,0 +1,60 @@
+package businessflow
+
+import (
+	"testing"
+
+	"github.com/amirphl/Yamata-no-Orochi/models"
+)
+
+func TestAdjustedBalances(t *testing.T) {
+	t.Parallel()
+
+	latest := models.BalanceSnapshot{FreeBalance: 100, CreditBalance: 50}
+	cases := []struct {
+		name         string
+		direction    models.WalletAdjustmentDirection
+		amount       uint64
+		free         uint64
+		credit       uint64
+		insufficient bool
+	}{
+		{name: "credit", direction: models.WalletAdjustmentCredit, amount: 30, free: 130, credit: 50},
+		{name: "debit from free", direction: models.WalletAdjustmentDebit, amount: 100, free: 0, credit: 50},
+		{name: "debit into credit", direction: models.WalletAdjustmentDebit, amount: 120, free: 0, credit: 30},
+		{name: "debit everything", direction: models.WalletAdjustmentDebit, amount: 150, free: 0, credit: 0},
+		{name: "debit too much", direction: models.WalletAdjustmentDebit, amount: 151, insufficient: true},
+	}
+	for _, tc := range cases {
+		free, credit, err := adjustedBalances(latest, tc.direction, tc.amount)
+		if tc.insufficient {
+			if !IsInsufficientFunds(err) {
+				t.Fatalf("%s: err = %v, want insufficient funds", tc.name, err)
+			}
+			continue
+		}
+		if err != nil || free != tc.free || credit != tc.credit {
+			t.Fatalf("%s: got (%d, %d, %v), want (%d, %d)", tc.name, free, credit, err, tc.free, tc.credit)
+		}
+	}
+
+	if _, _, err := adjustedBalances(latest, "sideways", 1); err == nil {
+		t.Fatal("unknown direction accepted")
+	}
+}
+
+func TestWalletAdjustmentReasonAllows(t *testing.T) {
+	t.Parallel()
+
+	if models.WalletAdjustmentReasonChargeback.Allows(models.WalletAdjustmentCredit) {
+		t.Fatal("chargeback credit allowed")
+	}
+	if models.WalletAdjustmentReasonGoodwillCredit.Allows(models.WalletAdjustmentDebit) {
+		t.Fatal("goodwill debit allowed")
+	}
+	if !models.WalletAdjustmentReasonCorrection.Allows(models.WalletAdjustmentDebit) || !models.WalletAdjustmentReasonCorrection.Allows(models.WalletAdjustmentCredit) {
+		t.Fatal("correction must allow both directions")
+	}
+	if models.WalletAdjustmentReason("typo").Allows(models.WalletAdjustmentCredit) {
+		t.Fatal("unknown reason allowed")
+	}
+}
@@ -0,0 +1,269 @@
+package businessflow
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/repository"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+)
+
+// WalletChargeFlow charges wallets with any payment method behind one API:
+// card payments through Atipay, crypto payments and bank transfers. The
+// method's own flow does the work; this one validates what the methods
+// share and lists their charges together.
+type WalletChargeFlow interface {
+	Charge(ctx context.Context, req *dto.WalletChargeRequest, metadata *ClientMetadata) (*dto.WalletChargeResponse, error)
+	ListRecentCharges(ctx context.Context, req *dto.ListWalletChargesRequest) (*dto.ListWalletChargesResponse, error)
+}
+
+type WalletChargeFlowImpl struct {
+	paymentFlow        PaymentFlow
+	cryptoFlow         CryptoPaymentFlow
+	paymentRequestRepo repository.PaymentRequestRepository
+	cryptoRequestRepo  repository.CryptoPaymentRequestRepository
+	depositReceiptRepo repository.DepositReceiptRepository
+}
+
+func NewWalletChargeFlow(
+	paymentFlow PaymentFlow,
+	cryptoFlow CryptoPaymentFlow,
+	paymentRequestRepo repository.PaymentRequestRepository,
+	cryptoRequestRepo repository.CryptoPaymentRequestRepository,
+	depositReceiptRepo repository.DepositReceiptRepository,
+) WalletChargeFlow {
+	return &WalletChargeFlowImpl{
+		paymentFlow:        paymentFlow,
+		cryptoFlow:         cryptoFlow,
+		paymentRequestRepo: paymentRequestRepo,
+		cryptoRequestRepo:  cryptoRequestRepo,
+		depositReceiptRepo: depositReceiptRepo,
+	}
+}
+
+// Charge starts a charge with the requested method. Errors are those of the
+// method's flow, so callers map them the way the method's own endpoint does.
+func (f *WalletChargeFlowImpl) Charge(ctx context.Context, req *dto.WalletChargeRequest, metadata *ClientMetadata) (*dto.WalletChargeResponse, error) {
+	if err := validateWalletChargeRequest(req); err != nil {
+		return nil, NewBusinessError("WALLET_CHARGE_VALIDATION_FAILED", err.Error(), err)
+	}
+	lang := strings.ToUpper(strings.TrimSpace(req.Lang))
+	if lang == "" {
+		lang = "EN"
+	}
+
+	resp := &dto.WalletChargeResponse{
+		Method:        req.Method,
+		AmountWithTax: req.AmountWithTax,
+		Status:        walletChargePending,
+	}
+	switch req.Method {
+	case dto.WalletChargeMethodCard:
+		card, err := f.paymentFlow.ChargeWallet(ctx, &dto.ChargeWalletRequest{
+			AmountWithTax: req.AmountWithTax,
+			CustomerID:    req.CustomerID,
+			Lang:          lang,
+		}, metadata)
+		if err != nil {
+			return nil, err
+		}
+		resp.Message = "Card payment started; redirect the customer to the gateway with the token"
+		resp.Card = card
+	case dto.WalletChargeMethodCrypto:
+		crypto, err := f.cryptoFlow.CreateRequest(ctx, &dto.CreateCryptoPaymentRequest{
+			CustomerID:    req.CustomerID,
+			AmountWithTax: req.AmountWithTax,
+			Coin:          req.Crypto.Coin,
+			Network:       req.Crypto.Network,
+			Platform:      req.Crypto.Platform,
+		}, metadata)
+		if err != nil {
+			return nil, err
+		}
+		resp.Message = "Crypto payment started; the wallet is credited once the deposit is confirmed"
+		resp.Crypto = crypto
+	case dto.WalletChargeMethodBankTransfer:
+		receipt, err := f.paymentFlow.SubmitDepositReceipt(ctx, &dto.SubmitDepositReceiptRequest{
+			CustomerID:     req.CustomerID,
+			Amount:         req.AmountWithTax,
+			Lang:           lang,
+			FileName:       req.BankTransfer.FileName,
+			ContentType:    req.BankTransfer.ContentType,
+			FileSize:       req.BankTransfer.FileSize,
+			FileBase64:     req.BankTransfer.FileBase64,
+			TransferDate:   req.BankTransfer.TransferDate,
+			TrackingNumber: req.BankTransfer.TrackingNumber,
+		}, metadata)
+		if err != nil {
+			return nil, err
+		}
+		resp.Message = "Bank transfer receipt submitted; the wallet is credited once finance approves it"
+		resp.BankTransfer = receipt
+	}
+	return resp, nil
+}
+
+// validateWalletChargeRequest checks what every method shares: a known
+// method, an amount and the details the method needs
+func validateWalletChargeRequest(req *dto.WalletChargeRequest) error {
+	if req == nil || req.AmountWithTax == 0 {
+		return ErrAmountTooLow
+	}
+	switch req.Method {
+	case dto.WalletChargeMethodCard:
+	case dto.WalletChargeMethodCrypto:
+		if req.Crypto == nil {
+			return ErrWalletChargeDetailsRequired
+		}
+	case dto.WalletChargeMethodBankTransfer:
+		if req.BankTransfer == nil {
+			return ErrWalletChargeDetailsRequired
+		}
+	default:
+		return ErrWalletChargeMethodInvalid
+	}
+	if lang := strings.ToUpper(strings.TrimSpace(req.Lang)); lang != "" && lang != "EN" && lang != "FA" {
+		return ErrInvalidLanguage
+	}
+	return nil
+}
+
+// Common statuses of charges of every method
+const (
+	walletChargePending   = "pending"
+	walletChargeCompleted = "completed"
+	walletChargeFailed    = "failed"
+	walletChargeCancelled = "cancelled"
+	walletChargeExpired   = "expired"
+	walletChargeRejected  = "rejected"
+	walletChargeRefunded  = "refunded"
+)
+
+// ListRecentCharges returns the customer's latest charges of every method,
+// or of one method, newest first
+func (f *WalletChargeFlowImpl) ListRecentCharges(ctx context.Context, req *dto.ListWalletChargesRequest) (*dto.ListWalletChargesResponse, error) {
+	if req.Method != "" && req.Method != dto.WalletChargeMethodCard && req.Method != dto.WalletChargeMethodCrypto && req.Method != dto.WalletChargeMethodBankTransfer {
+		return nil, NewBusinessError("WALLET_CHARGE_VALIDATION_FAILED", ErrWalletChargeMethodInvalid.Error(), ErrWalletChargeMethodInvalid)
+	}
+	limit := req.Limit
+	if limit <= 0 {
+		limit = 10
+	}
+	limit = min(limit, 50)
+	wants := func(method string) bool { return req.Method == "" || req.Method == method }
+	customerID := req.CustomerID
+	items := make([]dto.WalletChargeItem, 0, limit)
+
+	// Each method's latest charges are enough: no older one can make the
+	// merged list
+	if wants(dto.WalletChargeMethodCard) {
+		requests, err := f.paymentRequestRepo.ByFilter(ctx, models.PaymentRequestFilter{
+			CustomerID:     &customerID,
+			PaymentChannel: utils.ToPtr("atipay"),
+		}, "created_at DESC", limit, 0)
+		if err != nil {
+			return nil, NewBusinessError("LIST_WALLET_CHARGES_FAILED", "Failed to list card payments", err)
+		}
+		for _, r := range requests {
+			items = append(items, dto.WalletChargeItem{
+				UUID:          r.UUID.String(),
+				Method:        dto.WalletChargeMethodCard,
+				AmountWithTax: r.Amount,
+				Status:        cardChargeStatus(r.Status),
+				MethodStatus:  string(r.Status),
+				StatusReason:  r.StatusReason,
+				CreatedAt:     r.CreatedAt,
+				UpdatedAt:     r.UpdatedAt,
+			})
+		}
+	}
+	if wants(dto.WalletChargeMethodCrypto) {
+		requests, err := f.cryptoRequestRepo.ByFilter(ctx, models.CryptoPaymentRequestFilter{CustomerID: &customerID}, "created_at DESC", limit, 0)
+		if err != nil {
+			return nil, NewBusinessError("LIST_WALLET_CHARGES_FAILED", "Failed to list crypto payments", err)
+		}
+		for _, r := range requests {
+			items = append(items, dto.WalletChargeItem{
+				UUID:          r.UUID.String(),
+				Method:        dto.WalletChargeMethodCrypto,
+				AmountWithTax: r.FiatAmountToman,
+				Status:        cryptoChargeStatus(r.Status),
+				MethodStatus:  string(r.Status),
+				StatusReason:  r.StatusReason,
+				CreatedAt:     r.CreatedAt,
+				UpdatedAt:     r.UpdatedAt,
+			})
+		}
+	}
+	if wants(dto.WalletChargeMethodBankTransfer) {
+		receipts, err := f.depositReceiptRepo.List(ctx, models.DepositReceiptFilter{CustomerID: &customerID, OmitFileData: true}, limit, 0, "created_at DESC")
+		if err != nil {
+			return nil, NewBusinessError("LIST_WALLET_CHARGES_FAILED", "Failed to list bank transfers", err)
+		}
+		for _, r := range receipts {
+			items = append(items, dto.WalletChargeItem{
+				UUID:          r.UUID.String(),
+				Method:        dto.WalletChargeMethodBankTransfer,
+				AmountWithTax: r.Amount,
+				Status:        bankTransferChargeStatus(r.Status),
+				MethodStatus:  string(r.Status),
+				StatusReason:  r.StatusReason,
+				CreatedAt:     r.CreatedAt,
+				UpdatedAt:     r.UpdatedAt,
+			})
+		}
+	}
+
+	sort.SliceStable(items, func(i, j int) bool { return items[i].CreatedAt.After(items[j].CreatedAt) })
+	if len(items) > limit {
+		items = items[:limit]
+	}
+	return &dto.ListWalletChargesResponse{
+		Message: "Wallet charges retrieved successfully",
+		Items:   items,
+	}, nil
+}
+
+func cardChargeStatus(s models.PaymentRequestStatus) string {
+	switch s {
+	case models.PaymentRequestStatusCompleted:
+		return walletChargeCompleted
+	case models.PaymentRequestStatusFailed:
+		return walletChargeFailed
+	case models.PaymentRequestStatusCancelled:
+		return walletChargeCancelled
+	case models.PaymentRequestStatusExpired:
+		return walletChargeExpired
+	case models.PaymentRequestStatusRefunded:
+		return walletChargeRefunded
+	}
+	return walletChargePending
+}
+
+func cryptoChargeStatus(s models.CryptoPaymentStatus) string {
+	switch s {
+	case models.CryptoPaymentStatusCredited:
+		return walletChargeCompleted
+	case models.CryptoPaymentStatusFailed:
+		return walletChargeFailed
+	case models.CryptoPaymentStatusCancelled:
+		return walletChargeCancelled
+	case models.CryptoPaymentStatusExpired:
+		return walletChargeExpired
+	}
+	// Confirmed deposits are not credited yet
+	return walletChargePending
+}
+
+func bankTransferChargeStatus(s models.DepositReceiptStatus) string {
+	switch s {
+	case models.DepositReceiptStatusApproved:
+		return walletChargeCompleted
+	case models.DepositReceiptStatusRejected:
+		return walletChargeRejected
+	}
+	return walletChargePending
+}
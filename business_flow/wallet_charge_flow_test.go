@@ -0,0 +1,112 @@
+package businessflow
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/repository"
+	"github.com/google/uuid"
+)
+
+type chargePaymentRepoStub struct {
+	repository.PaymentRequestRepository
+	requests []*models.PaymentRequest
+	filter   models.PaymentRequestFilter
+}
+
+func (r *chargePaymentRepoStub) ByFilter(ctx context.Context, filter models.PaymentRequestFilter, orderBy string, limit, offset int) ([]*models.PaymentRequest, error) {
+	r.filter = filter
+	return r.requests, nil
+}
+
+type chargeCryptoRepoStub struct {
+	repository.CryptoPaymentRequestRepository
+	requests []*models.CryptoPaymentRequest
+}
+
+func (r *chargeCryptoRepoStub) ByFilter(ctx context.Context, filter models.CryptoPaymentRequestFilter, orderBy string, limit, offset int) ([]*models.CryptoPaymentRequest, error) {
+	return r.requests, nil
+}
+
+type chargeReceiptRepoStub struct {
+	repository.DepositReceiptRepository
+	receipts []*models.DepositReceipt
+	filter   models.DepositReceiptFilter
+}
+
+func (r *chargeReceiptRepoStub) List(ctx context.Context, f models.DepositReceiptFilter, limit, offset int, order string) ([]*models.DepositReceipt, error) {
+	r.filter = f
+	return r.receipts, nil
+}
+
+func TestValidateWalletChargeRequest(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		req  dto.WalletChargeRequest
+		is   func(error) bool
+	}{
+		{"card", dto.WalletChargeRequest{Method: dto.WalletChargeMethodCard, AmountWithTax: 100000}, nil},
+		{"unknown method", dto.WalletChargeRequest{Method: "paypal", AmountWithTax: 100000}, IsWalletChargeMethodInvalid},
+		{"crypto without details", dto.WalletChargeRequest{Method: dto.WalletChargeMethodCrypto, AmountWithTax: 100000}, IsWalletChargeDetailsRequired},
+		{"bank transfer without receipt", dto.WalletChargeRequest{Method: dto.WalletChargeMethodBankTransfer, AmountWithTax: 100000}, IsWalletChargeDetailsRequired},
+		{"no amount", dto.WalletChargeRequest{Method: dto.WalletChargeMethodCard}, IsAmountTooLow},
+		{"bad language", dto.WalletChargeRequest{Method: dto.WalletChargeMethodCard, AmountWithTax: 100000, Lang: "de"}, IsInvalidLanguage},
+	}
+	for _, tt := range tests {
+		err := validateWalletChargeRequest(&tt.req)
+		if tt.is == nil && err != nil || tt.is != nil && !tt.is(err) {
+			t.Errorf("%s: got %v", tt.name, err)
+		}
+	}
+}
+
+func TestListRecentWalletCharges(t *testing.T) {
+	t.Parallel()
+	base := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	payments := &chargePaymentRepoStub{requests: []*models.PaymentRequest{
+		{UUID: uuid.New(), Amount: 200000, Status: models.PaymentRequestStatusCompleted, CreatedAt: base.Add(3 * time.Hour)},
+		{UUID: uuid.New(), Amount: 100000, Status: models.PaymentRequestStatusTokenized, CreatedAt: base},
+	}}
+	cryptos := &chargeCryptoRepoStub{requests: []*models.CryptoPaymentRequest{
+		{UUID: uuid.New(), FiatAmountToman: 500000, Status: models.CryptoPaymentStatusConfirmed, CreatedAt: base.Add(2 * time.Hour)},
+	}}
+	receipts := &chargeReceiptRepoStub{receipts: []*models.DepositReceipt{
+		{UUID: uuid.New(), Amount: 300000, Status: models.DepositReceiptStatusRejected, CreatedAt: base.Add(time.Hour)},
+	}}
+	f := NewWalletChargeFlow(nil, nil, payments, cryptos, receipts)
+
+	res, err := f.ListRecentCharges(context.Background(), &dto.ListWalletChargesRequest{CustomerID: 7, Limit: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, item := range res.Items {
+		got = append(got, item.Method+":"+item.Status)
+	}
+	want := []string{"card:completed", "crypto:pending", "bank_transfer:rejected"}
+	if len(got) != len(want) {
+		t.Fatalf("items = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("items = %v, want %v", got, want)
+		}
+	}
+	// Admin charges and approved receipts are payment requests too; only
+	// gateway payments are card charges
+	if payments.filter.PaymentChannel == nil || *payments.filter.PaymentChannel != "atipay" {
+		t.Fatalf("card charges filtered by %+v", payments.filter)
+	}
+	if !receipts.filter.OmitFileData {
+		t.Fatal("receipt files were loaded")
+	}
+
+	res, err = f.ListRecentCharges(context.Background(), &dto.ListWalletChargesRequest{CustomerID: 7, Method: dto.WalletChargeMethodCrypto})
+	if err != nil || len(res.Items) != 1 || res.Items[0].AmountWithTax != 500000 {
+		t.Fatalf("crypto charges = %+v, %v", res, err)
+	}
+}
@@ -0,0 +1,624 @@
+package businessflow
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/dto"
+	"github.com/amirphl/Yamata-no-Orochi/app/i18n"
+	"github.com/amirphl/Yamata-no-Orochi/app/services"
+	"github.com/amirphl/Yamata-no-Orochi/config"
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/repository"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// walletTransferLimitWindow is the window WalletTransferConfig.DailyLimit applies to
+const walletTransferLimitWindow = 24 * time.Hour
+
+// WalletTransferFlow moves free balance between wallets of accounts that
+// share a company national ID, for companies that run several accounts. The
+// sender starts a transfer and confirms it with the OTP sent to their mobile;
+// both wallets are changed in one database transaction on confirmation.
+type WalletTransferFlow interface {
+	Initiate(ctx context.Context, req *dto.InitiateWalletTransferRequest, metadata *ClientMetadata) (*dto.InitiateWalletTransferResponse, error)
+	Confirm(ctx context.Context, id uuid.UUID, req *dto.ConfirmWalletTransferRequest, metadata *ClientMetadata) (*dto.ConfirmWalletTransferResponse, error)
+	List(ctx context.Context, req *dto.ListWalletTransfersRequest) (*dto.ListWalletTransfersResponse, error)
+	AdminList(ctx context.Context, filter dto.AdminListWalletTransfersFilter) (*dto.AdminListWalletTransfersResponse, error)
+}
+
+type WalletTransferFlowImpl struct {
+	db                  *gorm.DB
+	transferRepo        repository.WalletTransferRepository
+	customerRepo        repository.CustomerRepository
+	walletRepo          repository.WalletRepository
+	balanceSnapshotRepo repository.BalanceSnapshotRepository
+	transactionRepo     repository.TransactionRepository
+	auditRepo           repository.AuditLogRepository
+	otpSMSSvc           services.SMSService
+	otpThrottle         *OTPThrottle
+	localizer           *i18n.Localizer
+	cfg                 config.WalletTransferConfig
+}
+
+func NewWalletTransferFlow(
+	db *gorm.DB,
+	transferRepo repository.WalletTransferRepository,
+	customerRepo repository.CustomerRepository,
+	walletRepo repository.WalletRepository,
+	balanceSnapshotRepo repository.BalanceSnapshotRepository,
+	transactionRepo repository.TransactionRepository,
+	auditRepo repository.AuditLogRepository,
+	otpSMSSvc services.SMSService,
+	otpThrottle *OTPThrottle,
+	localizer *i18n.Localizer,
+	cfg config.WalletTransferConfig,
+) WalletTransferFlow {
+	return &WalletTransferFlowImpl{
+		db:                  db,
+		transferRepo:        transferRepo,
+		customerRepo:        customerRepo,
+		walletRepo:          walletRepo,
+		balanceSnapshotRepo: balanceSnapshotRepo,
+		transactionRepo:     transactionRepo,
+		auditRepo:           auditRepo,
+		otpSMSSvc:           otpSMSSvc,
+		otpThrottle:         otpThrottle,
+		localizer:           localizer,
+		cfg:                 cfg,
+	}
+}
+
+// Initiate validates a transfer, records it as pending and sends its OTP to
+// the sender's mobile. Neither wallet changes until the OTP is confirmed.
+func (f *WalletTransferFlowImpl) Initiate(ctx context.Context, req *dto.InitiateWalletTransferRequest, metadata *ClientMetadata) (*dto.InitiateWalletTransferResponse, error) {
+	if !f.cfg.Enabled {
+		return nil, NewBusinessError("WALLET_TRANSFER_DISABLED", "Wallet transfers are disabled", ErrWalletTransferDisabled)
+	}
+	sender, err := getCustomer(ctx, f.customerRepo, req.CustomerID)
+	if err != nil {
+		return nil, NewBusinessError("WALLET_TRANSFER_INITIATE_FAILED", "Failed to find customer", err)
+	}
+
+	t, receiver, err := f.newTransfer(ctx, &sender, req)
+	if err != nil {
+		f.audit(ctx, &sender, models.AuditActionWalletTransferFailed, fmt.Sprintf("Wallet transfer of %d toman to %q rejected", req.Amount, strings.TrimSpace(req.Receiver)), false, err, metadata)
+		return nil, NewBusinessError("WALLET_TRANSFER_INITIATE_FAILED", "Failed to start wallet transfer", err)
+	}
+
+	if _, err := f.otpThrottle.Reserve(ctx, sender.RepresentativeMobile); err != nil {
+		return nil, NewBusinessError("WALLET_TRANSFER_INITIATE_FAILED", "Failed to send wallet transfer code", err)
+	}
+	recipient, err := normalizeOTPMobile(sender.RepresentativeMobile)
+	if err != nil {
+		return nil, NewBusinessError("WALLET_TRANSFER_INITIATE_FAILED", "Failed to send wallet transfer code", err)
+	}
+	code, err := generateOTP()
+	if err != nil {
+		return nil, NewBusinessError("WALLET_TRANSFER_INITIATE_FAILED", "Failed to generate wallet transfer code", err)
+	}
+	t.OTPHash = hashOTPCode(code)
+	if err := f.transferRepo.Save(ctx, t); err != nil {
+		return nil, NewBusinessError("WALLET_TRANSFER_INITIATE_FAILED", "Failed to create wallet transfer", err)
+	}
+
+	message := f.localizer.Customer(&sender, "otp.wallet_transfer_code", i18n.Args{
+		"Amount":   t.Amount,
+		"Receiver": walletTransferPartyName(receiver),
+		"Code":     code,
+		"Minutes":  int(utils.OTPExpiry.Minutes()),
+	})
+	customerID := int64(sender.ID)
+	transferID := t.ID
+	runAsyncOTPTask(ctx, "Wallet transfer send OTP", func(asyncCtx context.Context) error {
+		if err := f.otpSMSSvc.SendOTP(asyncCtx, recipient, message, &customerID); err != nil {
+			_ = f.transferRepo.Expire(asyncCtx, transferID)
+			return err
+		}
+		return nil
+	})
+
+	f.audit(ctx, &sender, models.AuditActionWalletTransferInitiated, fmt.Sprintf("Wallet transfer %s of %d toman to customer %d started", t.UUID, t.Amount, receiver.ID), true, nil, metadata)
+
+	return &dto.InitiateWalletTransferResponse{
+		Message:     "Wallet transfer code sent; confirm it to complete the transfer",
+		Transfer:    walletTransferItem(t, sender.ID, receiver),
+		MaskedPhone: dto.MaskPhoneNumber(sender.RepresentativeMobile),
+		OTPExpiry:   t.OTPExpiresAt,
+	}, nil
+}
+
+// newTransfer checks the receiver, amount and limits of a transfer request
+// and builds the pending transfer without its OTP
+func (f *WalletTransferFlowImpl) newTransfer(ctx context.Context, sender *models.Customer, req *dto.InitiateWalletTransferRequest) (*models.WalletTransfer, *models.Customer, error) {
+	receiver, err := f.findReceiver(ctx, req.Receiver)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := checkWalletTransferParties(sender, receiver); err != nil {
+		return nil, nil, err
+	}
+	if err := f.checkLimits(ctx, sender.ID, req.Amount); err != nil {
+		return nil, nil, err
+	}
+
+	// Checked again on confirmation; this only spares the customer an OTP
+	// for a transfer that cannot succeed
+	wallet, err := getWallet(ctx, f.walletRepo, sender.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	latest, err := getLatestBalanceSnapshot(ctx, f.walletRepo, wallet.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if latest.FreeBalance < req.Amount {
+		return nil, nil, ErrInsufficientFunds
+	}
+
+	now := utils.UTCNow()
+	var note *string
+	if n := strings.TrimSpace(req.Note); n != "" {
+		note = &n
+	}
+	return &models.WalletTransfer{
+		UUID:               uuid.New(),
+		CorrelationID:      uuid.New(),
+		SenderCustomerID:   sender.ID,
+		ReceiverCustomerID: receiver.ID,
+		Amount:             req.Amount,
+		Note:               note,
+		Status:             models.WalletTransferStatusPending,
+		OTPExpiresAt:       now.Add(utils.OTPExpiry),
+		CreatedAt:          now,
+		UpdatedAt:          now,
+	}, receiver, nil
+}
+
+// Confirm checks the OTP of a pending transfer and, when it matches, moves
+// the amount from the sender's free balance to the receiver's. Limits and
+// both parties are checked again, as they may have changed since Initiate.
+func (f *WalletTransferFlowImpl) Confirm(ctx context.Context, id uuid.UUID, req *dto.ConfirmWalletTransferRequest, metadata *ClientMetadata) (*dto.ConfirmWalletTransferResponse, error) {
+	if !f.cfg.Enabled {
+		return nil, NewBusinessError("WALLET_TRANSFER_DISABLED", "Wallet transfers are disabled", ErrWalletTransferDisabled)
+	}
+	sender, err := getCustomer(ctx, f.customerRepo, req.CustomerID)
+	if err != nil {
+		return nil, NewBusinessError("WALLET_TRANSFER_CONFIRM_FAILED", "Failed to find customer", err)
+	}
+	t, err := f.transferRepo.ByUUID(ctx, id)
+	if err != nil {
+		return nil, NewBusinessError("WALLET_TRANSFER_CONFIRM_FAILED", "Failed to find wallet transfer", err)
+	}
+	if t == nil || t.SenderCustomerID != sender.ID {
+		return nil, NewBusinessError("WALLET_TRANSFER_NOT_FOUND", "Wallet transfer not found", ErrWalletTransferNotFound)
+	}
+	if err := f.checkOTP(ctx, t, req.OTPCode); err != nil {
+		f.audit(ctx, &sender, models.AuditActionWalletTransferFailed, fmt.Sprintf("Wallet transfer %s confirmation failed", t.UUID), false, err, metadata)
+		return nil, NewBusinessError("WALLET_TRANSFER_CONFIRM_FAILED", "Failed to confirm wallet transfer", err)
+	}
+
+	var receiver *models.Customer
+	err = withVersionRetry(ctx, f.db, func(txCtx context.Context) error {
+		current, err := f.transferRepo.ByUUID(txCtx, id)
+		if err != nil {
+			return err
+		}
+		if current == nil {
+			return ErrWalletTransferNotFound
+		}
+		if current.Status != models.WalletTransferStatusPending {
+			return ErrWalletTransferNotPending
+		}
+		receiver, err = f.customerRepo.ByID(txCtx, current.ReceiverCustomerID)
+		if err != nil {
+			return err
+		}
+		if err := checkWalletTransferParties(&sender, receiver); err != nil {
+			return err
+		}
+		senderTx, receiverTx, err := f.applyTransfer(txCtx, current)
+		if err != nil {
+			return err
+		}
+		now := utils.UTCNow()
+		current.Status = models.WalletTransferStatusCompleted
+		current.SenderTransactionID = &senderTx.ID
+		current.ReceiverTransactionID = &receiverTx.ID
+		current.CompletedAt = &now
+		current.UpdatedAt = now
+
+		// A concurrent confirmation may have completed it since it was read
+		completed, err := f.transferRepo.MarkCompleted(txCtx, current)
+		if err != nil {
+			return err
+		}
+		if !completed {
+			return ErrWalletTransferNotPending
+		}
+		t = current
+		return nil
+	})
+	if err != nil {
+		f.audit(ctx, &sender, models.AuditActionWalletTransferFailed, fmt.Sprintf("Wallet transfer %s failed", t.UUID), false, err, metadata)
+		return nil, NewBusinessError("WALLET_TRANSFER_CONFIRM_FAILED", "Failed to complete wallet transfer", err)
+	}
+
+	f.audit(ctx, &sender, models.AuditActionWalletTransferCompleted, fmt.Sprintf("Wallet transfer %s of %d toman sent to customer %d", t.UUID, t.Amount, receiver.ID), true, nil, metadata)
+	f.audit(ctx, receiver, models.AuditActionWalletTransferCompleted, fmt.Sprintf("Wallet transfer %s of %d toman received from customer %d", t.UUID, t.Amount, sender.ID), true, nil, metadata)
+
+	return &dto.ConfirmWalletTransferResponse{
+		Message:  "Wallet transfer completed",
+		Transfer: walletTransferItem(t, sender.ID, receiver),
+	}, nil
+}
+
+// checkOTP verifies the code of a pending transfer. An expired transfer, or
+// one with too many wrong codes, is ended and must be started again.
+func (f *WalletTransferFlowImpl) checkOTP(ctx context.Context, t *models.WalletTransfer, code string) error {
+	if t.Status != models.WalletTransferStatusPending {
+		return ErrWalletTransferNotPending
+	}
+	if !utils.UTCNow().Before(t.OTPExpiresAt) {
+		if err := f.transferRepo.Expire(ctx, t.ID); err != nil {
+			return err
+		}
+		return ErrWalletTransferOTPExpired
+	}
+	if t.OTPAttempts >= authOTPMaxAttempts {
+		if err := f.transferRepo.Expire(ctx, t.ID); err != nil {
+			return err
+		}
+		return ErrRateLimitExceeded
+	}
+	if verifyOTPCodeHash(code, t.OTPHash) {
+		return nil
+	}
+	if err := f.transferRepo.IncrementOTPAttempts(ctx, t.ID); err != nil {
+		return err
+	}
+	if t.OTPAttempts+1 >= authOTPMaxAttempts {
+		if err := f.transferRepo.Expire(ctx, t.ID); err != nil {
+			return err
+		}
+		return ErrRateLimitExceeded
+	}
+	return ErrWalletTransferOTPInvalid
+}
+
+// checkLimits checks an amount against the per-transfer range and what the
+// sender transferred over the last 24 hours
+func (f *WalletTransferFlowImpl) checkLimits(ctx context.Context, senderID uint, amount uint64) error {
+	if amount < f.cfg.MinAmount || amount > f.cfg.MaxAmount {
+		return ErrWalletTransferAmountOutOfRange
+	}
+	sent, err := f.transferRepo.SumSentSince(ctx, senderID, utils.UTCNow().Add(-walletTransferLimitWindow))
+	if err != nil {
+		return err
+	}
+	if sent+amount > f.cfg.DailyLimit {
+		return ErrWalletTransferDailyLimitExceeded
+	}
+	return nil
+}
+
+// findReceiver looks the receiving account up by mobile or email. An unknown
+// account is reported like an ineligible one so the endpoint cannot be used
+// to probe for accounts.
+func (f *WalletTransferFlowImpl) findReceiver(ctx context.Context, identifier string) (*models.Customer, error) {
+	identifier = normalizeLoginIdentifier(identifier)
+	var (
+		receiver *models.Customer
+		err      error
+	)
+	if strings.Contains(identifier, "@") {
+		receiver, err = f.customerRepo.ByEmail(ctx, identifier)
+	} else {
+		receiver, err = f.customerRepo.ByMobile(ctx, identifier)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if receiver == nil {
+		return nil, ErrWalletTransferReceiverNotEligible
+	}
+	return receiver, nil
+}
+
+// checkWalletTransferParties reports whether balance may move from sender to
+// receiver: two different live accounts of the same company, neither of them
+// a sandbox account
+func checkWalletTransferParties(sender, receiver *models.Customer) error {
+	if receiver == nil {
+		return ErrWalletTransferReceiverNotEligible
+	}
+	if sender.ID == receiver.ID {
+		return ErrWalletTransferSelf
+	}
+	if sender.InSandbox() || receiver.InSandbox() {
+		return ErrWalletTransferSandbox
+	}
+	if !utils.IsTrue(receiver.IsActive) || receiver.DeletedAt != nil {
+		return ErrWalletTransferReceiverNotEligible
+	}
+	if sender.NationalID == nil || receiver.NationalID == nil {
+		return ErrWalletTransferReceiverNotEligible
+	}
+	nationalID := strings.TrimSpace(*sender.NationalID)
+	if nationalID == "" || nationalID != strings.TrimSpace(*receiver.NationalID) {
+		return ErrWalletTransferReceiverNotEligible
+	}
+	return nil
+}
+
+// applyTransfer writes the balance snapshots and transactions of both wallets
+// once the amount is within the sender's limits
+func (f *WalletTransferFlowImpl) applyTransfer(ctx context.Context, t *models.WalletTransfer) (*models.Transaction, *models.Transaction, error) {
+	senderWallet, err := getWallet(ctx, f.walletRepo, t.SenderCustomerID)
+	if err != nil {
+		return nil, nil, err
+	}
+	receiverWallet, err := getWallet(ctx, f.walletRepo, t.ReceiverCustomerID)
+	if err != nil {
+		return nil, nil, err
+	}
+	// Serialize with other balance updates of both wallets; the locks are
+	// taken in wallet ID order, so opposite transfers cannot deadlock
+	if err := lockWalletBalances(ctx, f.walletRepo, senderWallet.ID, receiverWallet.ID); err != nil {
+		return nil, nil, err
+	}
+	// Checked under the sender's lock so concurrent confirmations each see
+	// the transfers completed before them in the daily total
+	if err := f.checkLimits(ctx, t.SenderCustomerID, t.Amount); err != nil {
+		return nil, nil, err
+	}
+	senderLatest, err := getLatestBalanceSnapshot(ctx, f.walletRepo, senderWallet.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	receiverLatest, err := getLatestBalanceSnapshot(ctx, f.walletRepo, receiverWallet.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	// Only free balance moves; credit is provisioned to one account
+	if senderLatest.FreeBalance < t.Amount {
+		return nil, nil, ErrInsufficientFunds
+	}
+
+	if err := f.walletRepo.AdvanceVersion(ctx, &senderWallet); err != nil {
+		return nil, nil, err
+	}
+	if err := f.walletRepo.AdvanceVersion(ctx, &receiverWallet); err != nil {
+		return nil, nil, err
+	}
+
+	senderTx, err := f.recordTransferLeg(ctx, t, senderWallet, senderLatest, models.TransactionTypeTransferOut, senderLatest.FreeBalance-t.Amount, t.ReceiverCustomerID)
+	if err != nil {
+		return nil, nil, err
+	}
+	receiverTx, err := f.recordTransferLeg(ctx, t, receiverWallet, receiverLatest, models.TransactionTypeTransferIn, receiverLatest.FreeBalance+t.Amount, t.SenderCustomerID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return senderTx, receiverTx, nil
+}
+
+// recordTransferLeg writes the snapshot and transaction of one side of a
+// transfer, setting the wallet's free balance to newFree
+func (f *WalletTransferFlowImpl) recordTransferLeg(ctx context.Context, t *models.WalletTransfer, wallet models.Wallet, latest models.BalanceSnapshot, txType models.TransactionType, newFree uint64, counterpartyID uint) (*models.Transaction, error) {
+	description := fmt.Sprintf("Wallet transfer to customer %d", counterpartyID)
+	if txType == models.TransactionTypeTransferIn {
+		description = fmt.Sprintf("Wallet transfer from customer %d", counterpartyID)
+	}
+	metaBytes, err := json.Marshal(map[string]any{
+		"source":                   "wallet_transfer",
+		"operation":                string(txType),
+		"transfer_uuid":            t.UUID.String(),
+		"sender_customer_id":       t.SenderCustomerID,
+		"receiver_customer_id":     t.ReceiverCustomerID,
+		"counterparty_customer_id": counterpartyID,
+		"note":                     t.Note,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	newSnap := &models.BalanceSnapshot{
+		UUID:               uuid.New(),
+		CorrelationID:      t.CorrelationID,
+		WalletID:           wallet.ID,
+		CustomerID:         wallet.CustomerID,
+		FreeBalance:        newFree,
+		FrozenBalance:      latest.FrozenBalance,
+		LockedBalance:      latest.LockedBalance,
+		CreditBalance:      latest.CreditBalance,
+		SpentOnCampaign:    latest.SpentOnCampaign,
+		AgencyShareWithTax: latest.AgencyShareWithTax,
+		TotalBalance:       newFree + latest.CreditBalance + latest.FrozenBalance + latest.LockedBalance + latest.SpentOnCampaign + latest.AgencyShareWithTax,
+		Reason:             "wallet_transfer",
+		Description:        description,
+		Metadata:           metaBytes,
+	}
+	if err := f.balanceSnapshotRepo.Save(ctx, newSnap); err != nil {
+		return nil, err
+	}
+
+	beforeMap, err := latest.GetBalanceMap()
+	if err != nil {
+		return nil, err
+	}
+	afterMap, err := newSnap.GetBalanceMap()
+	if err != nil {
+		return nil, err
+	}
+	tx := &models.Transaction{
+		UUID:          uuid.New(),
+		CorrelationID: t.CorrelationID,
+		Type:          txType,
+		Status:        models.TransactionStatusCompleted,
+		Amount:        t.Amount,
+		Currency:      utils.TomanCurrency,
+		WalletID:      wallet.ID,
+		CustomerID:    wallet.CustomerID,
+		BalanceBefore: beforeMap,
+		BalanceAfter:  afterMap,
+		Description:   description,
+		Metadata:      metaBytes,
+	}
+	if err := f.transactionRepo.Save(ctx, tx); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// List returns the transfers a customer sent or received, newest first
+func (f *WalletTransferFlowImpl) List(ctx context.Context, req *dto.ListWalletTransfersRequest) (*dto.ListWalletTransfersResponse, error) {
+	page, limit := walletTransferPage(req.Page, req.Limit)
+	filter := models.WalletTransferFilter{CustomerID: &req.CustomerID}
+
+	total, err := f.transferRepo.Count(ctx, filter)
+	if err != nil {
+		return nil, NewBusinessError("WALLET_TRANSFER_LIST_FAILED", "Failed to count wallet transfers", err)
+	}
+	rows, err := f.transferRepo.ByFilter(ctx, filter, "id DESC", limit, (page-1)*limit)
+	if err != nil {
+		return nil, NewBusinessError("WALLET_TRANSFER_LIST_FAILED", "Failed to list wallet transfers", err)
+	}
+
+	ids := make([]uint, 0, len(rows))
+	for _, row := range rows {
+		if row.SenderCustomerID == req.CustomerID {
+			ids = append(ids, row.ReceiverCustomerID)
+		} else {
+			ids = append(ids, row.SenderCustomerID)
+		}
+	}
+	counterparties, err := f.customerRepo.FindByIDs(ctx, ids)
+	if err != nil {
+		return nil, NewBusinessError("WALLET_TRANSFER_LIST_FAILED", "Failed to load transfer counterparties", err)
+	}
+	byID := make(map[uint]*models.Customer, len(counterparties))
+	for _, c := range counterparties {
+		byID[c.ID] = c
+	}
+
+	items := make([]dto.WalletTransferItem, 0, len(rows))
+	for i, row := range rows {
+		items = append(items, walletTransferItem(row, req.CustomerID, byID[ids[i]]))
+	}
+	return &dto.ListWalletTransfersResponse{
+		Message:    "Wallet transfers retrieved successfully",
+		Items:      items,
+		Pagination: walletTransferPagination(total, page, limit),
+	}, nil
+}
+
+// AdminList returns transfers of all customers, newest first
+func (f *WalletTransferFlowImpl) AdminList(ctx context.Context, filter dto.AdminListWalletTransfersFilter) (*dto.AdminListWalletTransfersResponse, error) {
+	page, limit := walletTransferPage(filter.Page, filter.Limit)
+	tf := models.WalletTransferFilter{CustomerID: filter.CustomerID}
+	if filter.Status != nil && *filter.Status != "" {
+		status := models.WalletTransferStatus(*filter.Status)
+		tf.Status = &status
+	}
+
+	total, err := f.transferRepo.Count(ctx, tf)
+	if err != nil {
+		return nil, NewBusinessError("WALLET_TRANSFER_LIST_FAILED", "Failed to count wallet transfers", err)
+	}
+	rows, err := f.transferRepo.ByFilter(ctx, tf, "id DESC", limit, (page-1)*limit)
+	if err != nil {
+		return nil, NewBusinessError("WALLET_TRANSFER_LIST_FAILED", "Failed to list wallet transfers", err)
+	}
+
+	items := make([]dto.AdminWalletTransferItem, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, dto.AdminWalletTransferItem{
+			UUID:                  row.UUID.String(),
+			CorrelationID:         row.CorrelationID.String(),
+			SenderCustomerID:      row.SenderCustomerID,
+			ReceiverCustomerID:    row.ReceiverCustomerID,
+			Amount:                row.Amount,
+			Note:                  row.Note,
+			Status:                string(row.Status),
+			OTPAttempts:           row.OTPAttempts,
+			OTPExpiresAt:          row.OTPExpiresAt,
+			SenderTransactionID:   row.SenderTransactionID,
+			ReceiverTransactionID: row.ReceiverTransactionID,
+			CompletedAt:           row.CompletedAt,
+			CreatedAt:             row.CreatedAt,
+		})
+	}
+	return &dto.AdminListWalletTransfersResponse{
+		Message:    "Wallet transfers retrieved successfully",
+		Items:      items,
+		Pagination: walletTransferPagination(total, page, limit),
+	}, nil
+}
+
+func (f *WalletTransferFlowImpl) audit(ctx context.Context, customer *models.Customer, action, description string, success bool, err error, metadata *ClientMetadata) {
+	var errMsg *string
+	if err != nil {
+		errMsg = utils.ToPtr(err.Error())
+	}
+	_ = createAuditLog(ctx, f.auditRepo, customer, action, description, success, errMsg, metadata)
+}
+
+func walletTransferPage(page, limit int) (int, int) {
+	page = max(1, page)
+	if limit <= 0 {
+		limit = 10
+	}
+	return page, min(limit, 100)
+}
+
+func walletTransferPagination(total int64, page, limit int) dto.PaginationInfo {
+	return dto.PaginationInfo{
+		Total:      total,
+		Page:       page,
+		Limit:      limit,
+		TotalPages: int((total + int64(limit) - 1) / int64(limit)),
+	}
+}
+
+// walletTransferPartyName names an account by its company, or by its
+// representative when it has none
+func walletTransferPartyName(c *models.Customer) string {
+	if c == nil {
+		return ""
+	}
+	if c.CompanyName != nil && strings.TrimSpace(*c.CompanyName) != "" {
+		return strings.TrimSpace(*c.CompanyName)
+	}
+	return strings.TrimSpace(c.RepresentativeFirstName + " " + c.RepresentativeLastName)
+}
+
+// walletTransferItem describes a transfer to the customer viewerID, who sent
+// or received it
+func walletTransferItem(t *models.WalletTransfer, viewerID uint, counterparty *models.Customer) dto.WalletTransferItem {
+	item := dto.WalletTransferItem{
+		UUID:             t.UUID.String(),
+		Direction:        "received",
+		CounterpartyName: walletTransferPartyName(counterparty),
+		Amount:           t.Amount,
+		Note:             t.Note,
+		Status:           string(t.Status),
+		CompletedAt:      t.CompletedAt,
+		CreatedAt:        t.CreatedAt,
+	}
+	if t.SenderCustomerID == viewerID {
+		item.Direction = "sent"
+	}
+	if counterparty != nil {
+		item.CounterpartyUUID = counterparty.UUID.String()
+	}
+	if t.Status == models.WalletTransferStatusPending {
+		item.OTPExpiresAt = utils.ToPtr(t.OTPExpiresAt)
+	}
+	return item
+}
@@ -0,0 +1,104 @@
+package businessflow
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/config"
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/repository"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+)
+
+func TestCheckWalletTransferParties(t *testing.T) {
+	t.Parallel()
+
+	customer := func(id uint, nationalID string) *models.Customer {
+		return &models.Customer{ID: id, NationalID: utils.ToPtr(nationalID), IsActive: utils.ToPtr(true)}
+	}
+	sender := customer(1, "0012345678")
+
+	cases := []struct {
+		name     string
+		receiver *models.Customer
+		check    func(error) bool
+	}{
+		{name: "same company", receiver: customer(2, "0012345678"), check: func(err error) bool { return err == nil }},
+		{name: "unknown receiver", receiver: nil, check: IsWalletTransferReceiverNotEligible},
+		{name: "self", receiver: customer(1, "0012345678"), check: IsWalletTransferSelf},
+		{name: "other company", receiver: customer(2, "0087654321"), check: IsWalletTransferReceiverNotEligible},
+		{name: "no national id", receiver: &models.Customer{ID: 2, IsActive: utils.ToPtr(true)}, check: IsWalletTransferReceiverNotEligible},
+		{name: "inactive", receiver: func() *models.Customer {
+			c := customer(2, "0012345678")
+			c.IsActive = utils.ToPtr(false)
+			return c
+		}(), check: IsWalletTransferReceiverNotEligible},
+		{name: "deleted", receiver: func() *models.Customer {
+			c := customer(2, "0012345678")
+			c.DeletedAt = utils.ToPtr(time.Now())
+			return c
+		}(), check: IsWalletTransferReceiverNotEligible},
+		{name: "sandbox", receiver: func() *models.Customer {
+			c := customer(2, "0012345678")
+			c.IsSandbox = utils.ToPtr(true)
+			return c
+		}(), check: IsWalletTransferSandbox},
+	}
+	for _, tc := range cases {
+		if err := checkWalletTransferParties(sender, tc.receiver); !tc.check(err) {
+			t.Fatalf("%s: unexpected err %v", tc.name, err)
+		}
+	}
+
+	blank := customer(1, " ")
+	if err := checkWalletTransferParties(blank, customer(2, " ")); !IsWalletTransferReceiverNotEligible(err) {
+		t.Fatalf("blank national IDs: err = %v, want receiver not eligible", err)
+	}
+}
+
+type transferWalletRepoStub struct {
+	repository.WalletRepository
+	calls *[]string
+}
+
+func (r *transferWalletRepoStub) ByCustomerID(ctx context.Context, customerID uint) (*models.Wallet, error) {
+	return &models.Wallet{ID: customerID * 10, CustomerID: customerID}, nil
+}
+
+func (r *transferWalletRepoStub) LockBalance(ctx context.Context, walletID uint) error {
+	*r.calls = append(*r.calls, fmt.Sprintf("lock %d", walletID))
+	return nil
+}
+
+type transferRepoStub struct {
+	repository.WalletTransferRepository
+	calls *[]string
+	sent  uint64
+}
+
+func (r *transferRepoStub) SumSentSince(ctx context.Context, senderCustomerID uint, since time.Time) (uint64, error) {
+	*r.calls = append(*r.calls, fmt.Sprintf("sum %d", senderCustomerID))
+	return r.sent, nil
+}
+
+func TestApplyTransferChecksDailyLimitUnderLock(t *testing.T) {
+	t.Parallel()
+
+	var calls []string
+	f := &WalletTransferFlowImpl{
+		walletRepo:   &transferWalletRepoStub{calls: &calls},
+		transferRepo: &transferRepoStub{calls: &calls, sent: 900000},
+		cfg:          config.WalletTransferConfig{MinAmount: 10000, MaxAmount: 1000000, DailyLimit: 1000000},
+	}
+
+	_, _, err := f.applyTransfer(context.Background(), &models.WalletTransfer{SenderCustomerID: 2, ReceiverCustomerID: 1, Amount: 200000})
+	if !IsWalletTransferDailyLimitExceeded(err) {
+		t.Fatalf("transfer over the daily limit returned %v", err)
+	}
+	if want := []string{"lock 10", "lock 20", "sum 2"}; !slices.Equal(calls, want) {
+		t.Fatalf("calls = %v, want %v", calls, want)
+	}
+}
@@ -0,0 +1,201 @@
+// Package main is the load-test harness of Yamata no Orochi. It drives the
+// critical flows — login, wallet charging, campaign creation and the SMS
+// scheduler's batch sending — with concurrent workers for a fixed duration,
+// reports their latency percentiles and exits non-zero when a scenario
+// misses its p95 budget or error-rate limit, so it can gate CI.
+//
+// The HTTP scenarios run against a server started with make run-local, so
+// Atipay and the SMS gateway are mocked; see README.md for the rate limits to
+// raise first. The batch-sending scenario runs the scheduler's PayamSMS
+// client in-process against the PayamSMS mock.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// scenario is one flow under load
+type scenario struct {
+	name string
+	// slo is the p95 latency budget of one iteration
+	slo time.Duration
+	run func(ctx context.Context, worker int) error
+}
+
+// result holds the measured iterations of a scenario
+type result struct {
+	latencies []time.Duration
+	errors    int
+	firstErr  error
+	elapsed   time.Duration
+}
+
+func main() {
+	opts := parseOptions()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	scenarios, err := buildScenarios(ctx, opts)
+	if err != nil {
+		log.Fatalf("Failed to set up load test: %v", err)
+	}
+
+	failed := false
+	for _, s := range scenarios {
+		log.Printf("Running %s: %d workers for %s", s.name, opts.concurrency, opts.duration)
+		res := runScenario(ctx, s, opts.concurrency, opts.duration)
+		if !report(s, res, opts.maxErrorRate) {
+			failed = true
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// runScenario runs s on concurrency workers until duration has passed
+func runScenario(parent context.Context, s scenario, concurrency int, duration time.Duration) result {
+	ctx, cancel := context.WithTimeout(parent, duration)
+	defer cancel()
+
+	var (
+		mu  sync.Mutex
+		res result
+		wg  sync.WaitGroup
+	)
+	start := time.Now()
+	for w := range concurrency {
+		wg.Go(func() {
+			for ctx.Err() == nil {
+				began := time.Now()
+				err := s.run(ctx, w)
+				took := time.Since(began)
+				if err != nil && ctx.Err() != nil {
+					// Cut off by the end of the run rather than failed
+					return
+				}
+				mu.Lock()
+				res.latencies = append(res.latencies, took)
+				if err != nil {
+					res.errors++
+					if res.firstErr == nil {
+						res.firstErr = err
+					}
+				}
+				mu.Unlock()
+			}
+		})
+	}
+	wg.Wait()
+	res.elapsed = time.Since(start)
+	return res
+}
+
+// report prints the latency summary of a scenario and returns whether it met
+// its budget and the error-rate limit
+func report(s scenario, res result, maxErrorRate float64) bool {
+	n := len(res.latencies)
+	if n == 0 {
+		fmt.Printf("%-16s no iterations completed\n", s.name)
+		return false
+	}
+	slices.Sort(res.latencies)
+	p50, p95, p99 := percentile(res.latencies, 50), percentile(res.latencies, 95), percentile(res.latencies, 99)
+	errorRate := float64(res.errors) / float64(n)
+
+	ok := p95 <= s.slo && errorRate <= maxErrorRate
+	verdict := "PASS"
+	if !ok {
+		verdict = "FAIL"
+	}
+	fmt.Printf("%-16s n=%d rps=%.1f errors=%.2f%% p50=%s p95=%s p99=%s slo(p95)=%s %s\n",
+		s.name, n, float64(n)/res.elapsed.Seconds(), errorRate*100,
+		p50.Round(time.Microsecond), p95.Round(time.Microsecond), p99.Round(time.Microsecond), s.slo, verdict)
+	if res.firstErr != nil {
+		fmt.Printf("%-16s first error: %v\n", "", res.firstErr)
+	}
+	return ok
+}
+
+// percentile returns the nearest-rank percentile p of sorted
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	return sorted[max(rank, 1)-1]
+}
+
+// options are the command-line flags
+type options struct {
+	baseURL      string
+	scenarios    []string
+	concurrency  int
+	duration     time.Duration
+	maxErrorRate float64
+	slos         map[string]time.Duration
+
+	mobile       string
+	password     string
+	accountsFile string
+	redisURL     string
+
+	chargeAmount uint64
+	batchSize    int
+	smsLatency   time.Duration
+}
+
+func parseOptions() options {
+	var (
+		opts      options
+		scenarios string
+		slos      string
+	)
+	flag.StringVar(&opts.baseURL, "base-url", "http://localhost:8080", "API server to load")
+	flag.StringVar(&scenarios, "scenarios", strings.Join(scenarioNames, ","), "comma-separated scenarios to run: "+strings.Join(scenarioNames, ", "))
+	flag.IntVar(&opts.concurrency, "concurrency", 10, "concurrent workers per scenario")
+	flag.DurationVar(&opts.duration, "duration", 30*time.Second, "how long each scenario runs")
+	flag.Float64Var(&opts.maxErrorRate, "max-error-rate", 0.01, "highest share of failed iterations a scenario may have")
+	flag.StringVar(&slos, "slo", "", "p95 budgets overriding the defaults, e.g. login=200ms,charge-wallet=1s")
+	flag.StringVar(&opts.mobile, "mobile", os.Getenv("LOADTEST_MOBILE"), "mobile of the customer the HTTP scenarios log in as")
+	flag.StringVar(&opts.password, "password", os.Getenv("LOADTEST_PASSWORD"), "password of that customer")
+	flag.StringVar(&opts.accountsFile, "accounts", "", "file of mobile,password lines; workers spread over these customers instead of -mobile")
+	flag.StringVar(&opts.redisURL, "redis-url", "redis://localhost:6379", "Redis of the server, where login OTPs are seeded")
+	flag.Uint64Var(&opts.chargeAmount, "charge-amount", 100000, "wallet charge amount in Tomans")
+	flag.IntVar(&opts.batchSize, "batch-size", 200, "messages per batch in the sms-batch scenario")
+	flag.DurationVar(&opts.smsLatency, "sms-latency", 50*time.Millisecond, "latency of the PayamSMS mock in the sms-batch scenario")
+	flag.Parse()
+
+	for name := range strings.SplitSeq(scenarios, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			opts.scenarios = append(opts.scenarios, name)
+		}
+	}
+	opts.slos = make(map[string]time.Duration)
+	for pair := range strings.SplitSeq(slos, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		name, raw, ok := strings.Cut(pair, "=")
+		budget, err := time.ParseDuration(raw)
+		if !ok || err != nil || budget <= 0 {
+			log.Fatalf("Invalid -slo entry %q", pair)
+		}
+		opts.slos[name] = budget
+	}
+	if opts.concurrency <= 0 || opts.duration <= 0 || opts.batchSize <= 0 {
+		log.Fatal("-concurrency, -duration and -batch-size must be positive")
+	}
+	return opts
+}
@@ -0,0 +1,277 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/httpclient"
+	"github.com/amirphl/Yamata-no-Orochi/app/providermock"
+	"github.com/amirphl/Yamata-no-Orochi/app/scheduler"
+	"github.com/amirphl/Yamata-no-Orochi/config"
+	"github.com/redis/go-redis/v9"
+)
+
+var scenarioNames = []string{"login", "charge-wallet", "create-campaign", "sms-batch"}
+
+// defaultSLOs are the p95 budgets of the scenarios
+var defaultSLOs = map[string]time.Duration{
+	"login":           300 * time.Millisecond,
+	"charge-wallet":   500 * time.Millisecond,
+	"create-campaign": 300 * time.Millisecond,
+	"sms-batch":       time.Second,
+}
+
+// loadTestOTP is the login OTP seeded for every login of the harness
+const loadTestOTP = "246810"
+
+// account is a customer the HTTP scenarios act as
+type account struct {
+	mobile     string
+	password   string
+	customerID uint
+
+	mu    sync.Mutex // serializes the account's seeded logins
+	token string
+}
+
+func buildScenarios(ctx context.Context, opts options) ([]scenario, error) {
+	var (
+		out      []scenario
+		accounts []*account
+		api      *apiClient
+		rc       *redis.Client
+	)
+	for _, name := range opts.scenarios {
+		slo, ok := opts.slos[name]
+		if !ok {
+			slo, ok = defaultSLOs[name]
+		}
+		if !ok {
+			return nil, fmt.Errorf("unknown scenario %q", name)
+		}
+
+		if name != "sms-batch" && api == nil {
+			var err error
+			if accounts, err = loadAccounts(opts); err != nil {
+				return nil, err
+			}
+			redisOpts, err := redis.ParseURL(opts.redisURL)
+			if err != nil {
+				return nil, fmt.Errorf("parse redis url: %w", err)
+			}
+			rc = redis.NewClient(redisOpts)
+			api = &apiClient{baseURL: strings.TrimRight(opts.baseURL, "/"), client: &http.Client{Timeout: 30 * time.Second}}
+			for _, a := range accounts {
+				if err := api.prepare(ctx, rc, a); err != nil {
+					return nil, fmt.Errorf("log in %s: %w", a.mobile, err)
+				}
+			}
+		}
+		accountOf := func(worker int) *account { return accounts[worker%len(accounts)] }
+
+		s := scenario{name: name, slo: slo}
+		switch name {
+		case "login":
+			s.run = func(ctx context.Context, worker int) error {
+				a := accountOf(worker)
+				a.mu.Lock()
+				defer a.mu.Unlock()
+				return api.login(ctx, rc, a)
+			}
+		case "charge-wallet":
+			s.run = func(ctx context.Context, worker int) error {
+				return api.post(ctx, "/api/v1/payments/charge-wallet", accountOf(worker).token,
+					map[string]any{"amount": opts.chargeAmount}, nil)
+			}
+		case "create-campaign":
+			var seq atomic.Int64
+			s.run = func(ctx context.Context, worker int) error {
+				n := seq.Add(1)
+				return api.post(ctx, "/api/v1/campaigns", accountOf(worker).token, map[string]any{
+					"title":   fmt.Sprintf("loadtest %d", n),
+					"content": "Load test campaign",
+				}, nil)
+			}
+		case "sms-batch":
+			s.run = smsBatchScenario(opts)
+		}
+		out = append(out, s)
+	}
+	return out, nil
+}
+
+// smsBatchScenario sends batches through the SMS scheduler's PayamSMS client
+// against the in-process PayamSMS mock
+func smsBatchScenario(opts options) func(ctx context.Context, worker int) error {
+	httpclient.Configure(httpclient.Config{
+		MaxIdleConns:        opts.concurrency,
+		MaxIdleConnsPerHost: opts.concurrency,
+		BreakerThreshold:    5,
+		BreakerCooldown:     time.Second,
+		Mocks: providermock.Clients(config.MocksConfig{
+			PayamSMS: config.ProviderMockConfig{Enabled: true, Latency: opts.smsLatency},
+		}),
+	})
+	client := scheduler.NewPayamSMSClient(config.PayamSMSConfig{})
+
+	var seq atomic.Int64
+	return func(ctx context.Context, worker int) error {
+		batch := seq.Add(1)
+		items := make([]scheduler.PayamSMSItem, opts.batchSize)
+		for i := range items {
+			items[i] = scheduler.PayamSMSItem{
+				Recipient:  fmt.Sprintf("0912%07d", i),
+				Body:       "Load test message",
+				TrackingID: fmt.Sprintf("loadtest-%d-%d", batch, i),
+			}
+		}
+		responses, err := client.SendBatch(ctx, "3000", items)
+		if err != nil {
+			return err
+		}
+		if len(responses) != len(items) {
+			return fmt.Errorf("provider answered %d of %d messages", len(responses), len(items))
+		}
+		return nil
+	}
+}
+
+// loadAccounts reads the -accounts file, or the single -mobile account
+func loadAccounts(opts options) ([]*account, error) {
+	if opts.accountsFile == "" {
+		if opts.mobile == "" || opts.password == "" {
+			return nil, fmt.Errorf("set -mobile and -password, or -accounts, for the HTTP scenarios")
+		}
+		return []*account{{mobile: opts.mobile, password: opts.password}}, nil
+	}
+	f, err := os.Open(opts.accountsFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var accounts []*account
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		mobile, password, ok := strings.Cut(line, ",")
+		if !ok {
+			return nil, fmt.Errorf("accounts line %q is not mobile,password", line)
+		}
+		accounts = append(accounts, &account{mobile: strings.TrimSpace(mobile), password: strings.TrimSpace(password)})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(accounts) == 0 {
+		return nil, fmt.Errorf("no accounts in %s", opts.accountsFile)
+	}
+	return accounts, nil
+}
+
+// apiClient calls the API server under load
+type apiClient struct {
+	baseURL string
+	client  *http.Client
+}
+
+// prepare resolves the customer ID of a and logs it in once, for the
+// scenarios that need an access token
+func (c *apiClient) prepare(ctx context.Context, rc *redis.Client, a *account) error {
+	var otp struct {
+		CustomerID uint `json:"customer_id"`
+	}
+	if err := c.post(ctx, "/api/v1/auth/login/otp", "", map[string]any{"identifier": a.mobile}, &otp); err != nil {
+		return fmt.Errorf("request login otp: %w", err)
+	}
+	a.customerID = otp.CustomerID
+	return c.login(ctx, rc, a)
+}
+
+// login seeds a known login OTP for a in Redis, the way the login flow
+// stores one it sent, and logs in with it
+func (c *apiClient) login(ctx context.Context, rc *redis.Client, a *account) error {
+	now := time.Now().UTC()
+	sum := sha256.Sum256([]byte(loadTestOTP))
+	state, err := json.Marshal(map[string]any{
+		"otp_hash":     hex.EncodeToString(sum[:]),
+		"attempts":     0,
+		"created_at":   now,
+		"last_sent_at": now,
+	})
+	if err != nil {
+		return err
+	}
+	if err := rc.Set(ctx, fmt.Sprintf("login:otp:%d", a.customerID), state, 2*time.Minute).Err(); err != nil {
+		return fmt.Errorf("seed login otp: %w", err)
+	}
+
+	var session struct {
+		AccessToken string `json:"access_token"`
+	}
+	err = c.post(ctx, "/api/v1/auth/login", "", map[string]any{
+		"identifier": a.mobile,
+		"password":   a.password,
+		"otp_code":   loadTestOTP,
+	}, &session)
+	if err != nil {
+		return err
+	}
+	a.token = session.AccessToken
+	return nil
+}
+
+// post sends body to path and decodes the data of a successful response
+// into out
+func (c *apiClient) post(ctx context.Context, path, token string, body, out any) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s: HTTP status: %d: %s", path, resp.StatusCode, strings.TrimSpace(string(raw)))
+	}
+	if out == nil {
+		return nil
+	}
+	var envelope struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(raw, &envelope); err != nil {
+		return fmt.Errorf("%s: decode response: %w", path, err)
+	}
+	return json.Unmarshal(envelope.Data, out)
+}
@@ -0,0 +1,148 @@
+// Package main applies and inspects the database migrations embedded in the
+// build. It connects with the DB_* settings of the API, or with -dsn.
+//
+//	migrate up                   apply every pending migration
+//	migrate down [N]             roll back the last N migrations (default 1)
+//	migrate status               show the applied and pending migrations
+//	migrate baseline [MIGRATION] record an existing schema as migrated up to
+//	                             MIGRATION (default the latest) without running it
+//	migrate force MIGRATION      record MIGRATION as applied and clear the dirty
+//	                             flag after repairing a failed migration by hand
+//
+// MIGRATION is a file name without .sql, e.g. 0172_add_sandbox_mode, or its
+// ordinal when only one migration has it.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/dbmigrate"
+	"github.com/amirphl/Yamata-no-Orochi/config"
+	"github.com/amirphl/Yamata-no-Orochi/migrations"
+)
+
+func main() {
+	dsn := flag.String("dsn", "", "PostgreSQL connection string; defaults to the DB_* settings")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: migrate [-dsn DSN] up | down [N] | status | baseline [MIGRATION] | force MIGRATION\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if *dsn == "" {
+		cfg, err := config.LoadProductionConfig()
+		if err != nil {
+			log.Fatalf("Failed to load configuration: %v", err)
+		}
+		*dsn = cfg.Database.DSN()
+	}
+
+	m, err := dbmigrate.Open(*dsn, migrations.FS)
+	if err != nil {
+		log.Fatalf("Failed to open migrator: %v", err)
+	}
+	err = run(context.Background(), m, flag.Arg(0), flag.Args()[1:])
+	if closeErr := m.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		log.Fatalf("migrate %s: %v", flag.Arg(0), err)
+	}
+}
+
+func run(ctx context.Context, m *dbmigrate.Migrator, command string, args []string) error {
+	switch command {
+	case "up":
+		before, err := m.Status(ctx)
+		if err != nil {
+			return err
+		}
+		if err := m.Up(); err != nil {
+			return err
+		}
+		log.Printf("Applied %d migrations", len(before.Pending))
+		return printStatus(ctx, m)
+	case "down":
+		steps := 1
+		if len(args) > 0 {
+			n, err := strconv.Atoi(args[0])
+			if err != nil || n <= 0 {
+				return fmt.Errorf("steps must be a positive number, got %q", args[0])
+			}
+			steps = n
+		}
+		if err := m.Down(steps); err != nil {
+			return err
+		}
+		return printStatus(ctx, m)
+	case "status":
+		return printStatus(ctx, m)
+	case "baseline":
+		name := ""
+		if len(args) > 0 {
+			name = args[0]
+		}
+		target, err := m.Baseline(ctx, name)
+		if errors.Is(err, dbmigrate.ErrHistoryExists) {
+			return fmt.Errorf("%w; use force to move it", err)
+		}
+		if err != nil {
+			return err
+		}
+		log.Printf("Recorded %s as the applied migration", target.Name)
+		return printStatus(ctx, m)
+	case "force":
+		if len(args) == 0 {
+			return errors.New("force needs a migration")
+		}
+		target, err := m.Force(args[0])
+		if err != nil {
+			return err
+		}
+		log.Printf("Recorded %s as the applied migration", target.Name)
+		return printStatus(ctx, m)
+	default:
+		return fmt.Errorf("unknown command %q", command)
+	}
+}
+
+func printStatus(ctx context.Context, m *dbmigrate.Migrator) error {
+	status, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+	current := "none"
+	if status.Current != nil {
+		current = status.Current.Name
+		if status.Dirty {
+			current += " (dirty)"
+		}
+	}
+	if status.Unknown != 0 {
+		current = fmt.Sprintf("unknown version %d", status.Unknown)
+	}
+	fmt.Printf("current: %s\n", current)
+	fmt.Printf("pending: %d\n", len(status.Pending))
+	for _, migration := range status.Pending {
+		down := ""
+		if !migration.HasDown() {
+			down = " (no down migration)"
+		}
+		fmt.Printf("  %s%s\n", migration.Name, down)
+	}
+	// Pending migrations are routine; a dirty or unknown version needs a hand
+	if status.Dirty || status.Unknown != 0 {
+		return status.Drift()
+	}
+	return nil
+}
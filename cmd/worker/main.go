@@ -0,0 +1,126 @@
+// Package main runs the background workers of Yamata no Orochi without the
+// API: the campaign schedulers with their status checks and the other
+// periodic jobs. It loads the same configuration as the API server and works
+// against the same database; only the replica holding the worker lock runs
+// the workers, so it can be scaled like the API.
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/bootstrap"
+	"github.com/amirphl/Yamata-no-Orochi/app/handlers"
+	"github.com/amirphl/Yamata-no-Orochi/app/httpclient"
+	"github.com/amirphl/Yamata-no-Orochi/app/observability"
+	"github.com/amirphl/Yamata-no-Orochi/app/providermock"
+	businessflow "github.com/amirphl/Yamata-no-Orochi/business_flow"
+	"github.com/amirphl/Yamata-no-Orochi/config"
+	"github.com/gofiber/fiber/v3"
+)
+
+func main() {
+	cfg, err := config.LoadProductionConfig()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	if err := observability.InitSentry(observability.SentryConfig{
+		DSN:         cfg.Sentry.DSN,
+		Environment: cfg.Sentry.Environment,
+		Release:     cfg.Sentry.Release,
+		ServerName:  cfg.Sentry.ServerName,
+		Timeout:     cfg.Sentry.Timeout,
+		Capture4xx:  cfg.Sentry.Capture4xx,
+		Capture5xx:  cfg.Sentry.Capture5xx,
+	}); err != nil {
+		log.Fatalf("Failed to initialize sentry transport: %v", err)
+	}
+
+	httpclient.Configure(httpclient.Config{
+		MaxIdleConns:        cfg.OutboundHTTP.MaxIdleConns,
+		MaxIdleConnsPerHost: cfg.OutboundHTTP.MaxIdleConnsPerHost,
+		IdleConnTimeout:     cfg.OutboundHTTP.IdleConnTimeout,
+		HostTimeouts:        cfg.OutboundHTTP.HostTimeouts,
+		RetryMax:            cfg.OutboundHTTP.RetryMax,
+		RetryBackoff:        cfg.OutboundHTTP.RetryBackoff,
+		BreakerThreshold:    cfg.OutboundHTTP.BreakerThreshold,
+		BreakerCooldown:     cfg.OutboundHTTP.BreakerCooldown,
+		Mocks:               providermock.Clients(cfg.Mocks),
+	})
+	if cfg.Mocks.Any() {
+		log.Printf("Provider mocks enabled: atipay=%t oxapay=%t payamsms=%t bot=%t",
+			cfg.Mocks.Atipay.Enabled, cfg.Mocks.Oxapay.Enabled, cfg.Mocks.PayamSMS.Enabled, cfg.Mocks.Bot.Enabled)
+	}
+
+	logCloser, err := bootstrap.SetupLogging(cfg.Logging)
+	if err != nil {
+		log.Printf("Failed to initialize file logger, falling back to default: %v", err)
+	} else if logCloser != nil {
+		defer logCloser.Close()
+	}
+
+	log.Println("Starting Yamata no Orochi worker...")
+
+	app, err := bootstrap.Initialize(cfg, bootstrap.RoleWorker)
+	if err != nil {
+		log.Fatalf("Failed to initialize worker: %v", err)
+	}
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+
+	// Reload the reloadable settings on SIGHUP
+	hupChan := make(chan os.Signal, 1)
+	signal.Notify(hupChan, syscall.SIGHUP)
+	go func() {
+		for range hupChan {
+			log.Println("SIGHUP received, reloading configuration...")
+			res, err := app.Runtime.ReloadRuntimeConfig(context.Background(), businessflow.ConfigReloadSourceSignal)
+			if err != nil {
+				log.Printf("Configuration reload failed, keeping current settings: %v", err)
+				continue
+			}
+			log.Printf("Configuration reloaded, %d setting(s) changed", len(res.Changes))
+		}
+	}()
+
+	// Probes only; the worker serves no API
+	healthHandler := handlers.NewHealthHandler(app.Health, nil)
+	probes := fiber.New(fiber.Config{AppName: "yamata-worker"})
+	probes.Get("/healthz", healthHandler.Liveness)
+	probes.Get("/readyz", healthHandler.Readiness)
+	probes.Get("/startupz", healthHandler.Startup)
+	probes.Hooks().OnListen(func(fiber.ListenData) error {
+		app.Health.MarkStarted()
+		return nil
+	})
+	go func() {
+		address := fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.Server.Port)
+		log.Printf("Worker probes listening on %s", address)
+		if err := probes.Listen(address, fiber.ListenConfig{DisableStartupMessage: true}); err != nil {
+			log.Fatalf("Failed to start probe server: %v", err)
+		}
+	}()
+
+	<-sigChan
+	log.Println("Shutting down worker gracefully...")
+	app.Health.MarkDraining()
+
+	for _, fn := range app.StopFuncs {
+		fn()
+	}
+
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer shutdownCancel()
+	if err := probes.ShutdownWithContext(shutdownCtx); err != nil {
+		log.Printf("Error during shutdown: %v", err)
+	}
+	observability.ShutdownSentry(shutdownCtx)
+
+	log.Println("Worker stopped")
+}
@@ -9,14 +9,14 @@ import (
 	"strings"
 	"time"
 
-	"github.com/amirphl/Yamata-no-Orochi/utils"
-	"github.com/google/uuid"
+	"github.com/amirphl/Yamata-no-Orochi/pricing"
 )
 
 // ProductionConfig holds all configuration for production environment
 type ProductionConfig struct {
 	Database           DatabaseConfig           `json:"database"`
 	Server             ServerConfig             `json:"server"`
+	GRPC               GRPCConfig               `json:"grpc"`
 	Security           SecurityConfig           `json:"security"`
 	JWT                JWTConfig                `json:"jwt"`
 	Sentry             SentryConfig             `json:"sentry"`
@@ -29,15 +29,29 @@ type ProductionConfig struct {
 	Atipay             AtipayConfig             `json:"atipay"`
 	Admin              AdminConfig              `json:"admin"`
 	System             SystemConfig             `json:"system"`
+	Invoice            InvoiceConfig            `json:"invoice"`
+	Moadian            MoadianConfig            `json:"moadian"`
 	PayamSMS           PayamSMSConfig           `json:"payam_sms"`
 	Bale               BaleConfig               `json:"bale"`
 	Rubika             RubikaConfig             `json:"rubika"`
 	Splus              SplusConfig              `json:"splus"`
+	Telegram           TelegramConfig           `json:"telegram"`
+	FCM                FCMConfig                `json:"fcm"`
+	Storage            StorageConfig            `json:"storage"`
+	Uploads            UploadsConfig            `json:"uploads"`
+	KYC                KYCConfig                `json:"kyc"`
+	ShebaInquiry       ShebaInquiryConfig       `json:"sheba_inquiry"`
 	Bot                BotConfig                `json:"bot"`
 	Scheduler          SchedulerConfig          `json:"scheduler"`
+	JobQueue           JobQueueConfig           `json:"job_queue"`
+	OutboundHTTP       OutboundHTTPConfig       `json:"outbound_http"`
 	Crypto             CryptoConfig             `json:"crypto"`
-	Message            MessageConfig            `json:"message"`
+	I18n               I18nConfig               `json:"i18n"`
+	Health             HealthConfig             `json:"health"`
+	WalletTransfer     WalletTransferConfig     `json:"wallet_transfer"`
+	Secrets            SecretsConfig            `json:"secrets"`
 	SmartTagEvaluation SmartTagEvaluationConfig `json:"smart_tag_evaluation"`
+	Mocks              MocksConfig              `json:"mocks"`
 	IRHTTPSProxy       string                   `json:"ir_https_proxy"`
 }
 
@@ -54,6 +68,19 @@ type DatabaseConfig struct {
 	ConnMaxIdleTime time.Duration `json:"conn_max_idle_time"`
 	SlowQueryLog    bool          `json:"slow_query_log"`
 	SlowQueryTime   time.Duration `json:"slow_query_time"`
+	// ReplicaDSN optionally points lag-tolerant reads (reports, history,
+	// audience counts) at a read-only replica; empty keeps them on the primary
+	ReplicaDSN string `json:"replica_dsn"`
+	// MigrationCheck is what startup does when the schema is not at the
+	// latest embedded migration: "warn" logs it, "fail" refuses to start
+	// and "off" skips the check
+	MigrationCheck string `json:"migration_check"`
+}
+
+// DSN returns the libpq connection string of the primary database
+func (c DatabaseConfig) DSN() string {
+	return fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
+		c.Host, c.Port, c.User, c.Password, c.Name, c.SSLMode)
 }
 
 type ServerConfig struct {
@@ -70,6 +97,23 @@ type ServerConfig struct {
 	ProxyHeader       string        `json:"proxy_header"`
 	EnableCompression bool          `json:"enable_compression"`
 	CompressionLevel  int           `json:"compression_level"`
+
+	// CountryHeader names the header in which the reverse proxy passes the
+	// ISO country code of the client IP, e.g. CF-IPCountry; empty if none
+	CountryHeader string `json:"country_header"`
+
+	// V1DeprecatedAt, when set, makes /api/v1 responses carry Deprecation and
+	// Link headers pointing at /api/v2; V1SunsetAt adds a Sunset header
+	V1DeprecatedAt time.Time `json:"v1_deprecated_at"`
+	V1SunsetAt     time.Time `json:"v1_sunset_at"`
+}
+
+// GRPCConfig configures the internal gRPC API the schedulers and the bot call.
+// It is plaintext and must not be exposed outside the private network.
+type GRPCConfig struct {
+	Enabled bool   `json:"enabled"`
+	Host    string `json:"host"`
+	Port    int    `json:"port"`
 }
 
 type SecurityConfig struct {
@@ -94,6 +138,13 @@ type SecurityConfig struct {
 	GlobalRateLimit int           `json:"global_rate_limit"` // requests per minute
 	RateLimitWindow time.Duration `json:"rate_limit_window"`
 	RateLimitMemory int           `json:"rate_limit_memory"` // MB
+	// Sliding window limits per RateLimitWindow shared through Redis; 0 disables
+	OTPRateLimit       int `json:"otp_rate_limit"`        // OTP sends per IP
+	PaymentIPRateLimit int `json:"payment_ip_rate_limit"` // payment requests per IP
+	PaymentRateLimit   int `json:"payment_rate_limit"`    // payment requests per customer
+	// OTP sends per mobile number or email across signup, login, and password reset; 0 disables
+	OTPCooldown   time.Duration `json:"otp_cooldown"`    // minimum time between two sends
+	OTPDailyLimit int           `json:"otp_daily_limit"` // sends per 24 hours
 
 	// Content Security
 	CSPPolicy           string `json:"csp_policy"`
@@ -108,14 +159,29 @@ type SecurityConfig struct {
 	AllowedAPIKeys []string `json:"allowed_api_keys"`
 	IPWhitelist    []string `json:"ip_whitelist"`
 	IPBlacklist    []string `json:"ip_blacklist"`
+	// CIDR ranges or addresses admin endpoints accept requests from; empty
+	// accepts any
+	AdminIPAllowlist []string `json:"admin_ip_allowlist"`
 
 	// Password & Auth
 	PasswordMinLength     int  `json:"password_min_length"`
+	PasswordMaxLength     int  `json:"password_max_length"`
 	PasswordRequireUpper  bool `json:"password_require_upper"`
 	PasswordRequireLower  bool `json:"password_require_lower"`
 	PasswordRequireNum    bool `json:"password_require_number"`
 	PasswordRequireSymbol bool `json:"password_require_symbol"`
-	BcryptCost            int  `json:"bcrypt_cost"`
+	// New passwords found in the Have I Been Pwned range API are refused;
+	// range responses are cached in Redis for PasswordBreachCacheTTL
+	PasswordBreachCheck    bool          `json:"password_breach_check"`
+	PasswordBreachAPIURL   string        `json:"password_breach_api_url"`
+	PasswordBreachTimeout  time.Duration `json:"password_breach_timeout"`
+	PasswordBreachCacheTTL time.Duration `json:"password_breach_cache_ttl"`
+	BcryptCost             int           `json:"bcrypt_cost"`
+	// Argon2id parameters of new password hashes; hashes made with other
+	// parameters or with bcrypt are upgraded on the next login
+	Argon2Memory      int `json:"argon2_memory"` // KiB
+	Argon2Iterations  int `json:"argon2_iterations"`
+	Argon2Parallelism int `json:"argon2_parallelism"`
 
 	// Session Security
 	SessionCookieSecure    bool          `json:"session_cookie_secure"`
@@ -123,6 +189,14 @@ type SecurityConfig struct {
 	SessionCookieSameSite  string        `json:"session_cookie_samesite"`
 	SessionTimeout         time.Duration `json:"session_timeout"`
 	SessionCleanupInterval time.Duration `json:"session_cleanup_interval"`
+
+	// Page linked from new-device login alerts where the customer reports the
+	// login as not theirs; the alert token is appended as ?token=
+	LoginAlertURL string `json:"login_alert_url"`
+
+	// PasswordlessLoginEnabled lets customers log in with only a one-time SMS
+	// code sent to their mobile number
+	PasswordlessLoginEnabled bool `json:"passwordless_login_enabled"`
 }
 
 type JWTConfig struct {
@@ -190,6 +264,12 @@ type LoggingConfig struct {
 	// Audit Logs
 	EnableAuditLog bool   `json:"enable_audit_log"`
 	AuditLogPath   string `json:"audit_log_path"`
+	// Audit rows are queued and written to the database in batches of
+	// AuditBatchSize at least every AuditFlushInterval
+	AuditBufferEnabled bool          `json:"audit_buffer_enabled"`
+	AuditBufferSize    int           `json:"audit_buffer_size"`
+	AuditBatchSize     int           `json:"audit_batch_size"`
+	AuditFlushInterval time.Duration `json:"audit_flush_interval"`
 
 	// Security Logs
 	EnableSecurityLog bool   `json:"enable_security_log"`
@@ -254,9 +334,72 @@ type DeploymentConfig struct {
 	BuildTime   string `json:"build_time"`
 }
 
+// Atipay terminal routing policies selectable with ATIPAY_ROUTING
+const (
+	// AtipayRoutingRoundRobin spreads recharges evenly over the terminals
+	AtipayRoutingRoundRobin = "round_robin"
+	// AtipayRoutingAmount sends each recharge to the terminals with the
+	// smallest maximum amount that still covers it
+	AtipayRoutingAmount = "amount"
+)
+
 type AtipayConfig struct {
 	APIKey   string `json:"api_key"`
 	Terminal string `json:"terminal"`
+	// Terminals adds terminals to the one above as comma separated
+	// terminal:apiKey[:maxAmount] entries; maxAmount is the largest recharge
+	// with tax in Tomans the terminal takes when routing by amount
+	Terminals string `json:"terminals"`
+	Routing   string `json:"routing"`
+	// Daily settlement report download URL; {date} is replaced by YYYY-MM-DD
+	SettlementReportURL string `json:"settlement_report_url"`
+	// SettlementFallback settles recharges to the system Sheba number when
+	// the agency's cannot be used, instead of refusing them; the agency's
+	// share is then owed to it as a payable
+	SettlementFallback bool `json:"settlement_fallback"`
+}
+
+// AtipayTerminal is an Atipay terminal and the API key of its merchant
+type AtipayTerminal struct {
+	Terminal string `json:"terminal"`
+	APIKey   string `json:"api_key"`
+	// MaxAmount is the largest recharge with tax in Tomans routed to the
+	// terminal by amount; zero means no limit
+	MaxAmount uint64 `json:"max_amount"`
+}
+
+// AllTerminals returns the terminal of ATIPAY_TERMINAL, which takes
+// recharges of any amount, followed by those of ATIPAY_TERMINALS
+func (c AtipayConfig) AllTerminals() ([]AtipayTerminal, error) {
+	terminals := []AtipayTerminal{{Terminal: c.Terminal, APIKey: c.APIKey}}
+	for _, item := range strings.Split(c.Terminals, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		parts := strings.Split(item, ":")
+		if len(parts) < 2 || len(parts) > 3 {
+			return nil, fmt.Errorf("%q is not a terminal:apiKey[:maxAmount] entry", item)
+		}
+		t := AtipayTerminal{Terminal: strings.TrimSpace(parts[0]), APIKey: strings.TrimSpace(parts[1])}
+		if t.Terminal == "" || t.APIKey == "" {
+			return nil, fmt.Errorf("%q has an empty terminal or API key", item)
+		}
+		if len(parts) == 3 {
+			maxAmount, err := strconv.ParseUint(strings.TrimSpace(parts[2]), 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("%q has an invalid maximum amount", item)
+			}
+			t.MaxAmount = maxAmount
+		}
+		for _, other := range terminals {
+			if other.Terminal == t.Terminal {
+				return nil, fmt.Errorf("terminal %s is listed twice", t.Terminal)
+			}
+		}
+		terminals = append(terminals, t)
+	}
+	return terminals, nil
 }
 
 type AdminConfig struct {
@@ -265,6 +408,10 @@ type AdminConfig struct {
 	TwoFAMobiles          map[string]string `json:"admin_2fa_mobiles"`
 	OTPBypassMobiles      []string          `json:"admin_otp_bypass_mobiles"`
 	LoginOTPForwardMobile string            `json:"admin_login_otp_forward_mobile"`
+	// TOTPIssuer names the service in admins' authenticator apps
+	TOTPIssuer string `json:"admin_totp_issuer"`
+	// ImpersonationTTL is how long a customer impersonation token lasts
+	ImpersonationTTL time.Duration `json:"admin_impersonation_ttl"`
 }
 
 func (c AdminConfig) ActiveMobiles() []string {
@@ -341,12 +488,36 @@ type BotConfig struct {
 	Username  string `json:"username"`
 	Password  string `json:"password"`
 	APIDomain string `json:"api_domain"`
+	// GRPCAddress is the host:port of the internal gRPC API; when set the
+	// campaign schedulers call it instead of the REST API, which they still
+	// use for file downloads
+	GRPCAddress string `json:"grpc_address"`
 }
 
 type CryptoConfig struct {
 	DefaultPlatform string       `json:"default_platform"`
 	SupportedCoins  []string     `json:"supported_coins"`
 	Oxapay          OxapayConfig `json:"oxapay"`
+	// NowPayments is the secondary provider, enabled when its API key is set
+	NowPayments NowPaymentsConfig `json:"nowpayments"`
+	// PaymentToleranceBPS is how far, in basis points, the amount received may
+	// differ from the amount requested and still be credited as requested
+	PaymentToleranceBPS int `json:"payment_tolerance_bps"`
+	// WebhookMaxAge is how old a provider callback may be; older ones are
+	// rejected as replays
+	WebhookMaxAge time.Duration `json:"webhook_max_age"`
+	// Rates are the exchange rates provider quotes are checked against
+	Rates ExchangeRateConfig `json:"rates"`
+}
+
+type ExchangeRateConfig struct {
+	Sources    []string      `json:"sources"` // wallex, nobitex
+	MinSources int           `json:"min_sources"`
+	CacheTTL   time.Duration `json:"cache_ttl"`
+	// MaxDivergenceBPS is how far, in basis points, sources and provider
+	// quotes may differ before a quote is rejected
+	MaxDivergenceBPS int           `json:"max_divergence_bps"`
+	Timeout          time.Duration `json:"timeout"`
 }
 
 type OxapayConfig struct {
@@ -355,6 +526,13 @@ type OxapayConfig struct {
 	Timeout time.Duration `json:"timeout"`
 }
 
+type NowPaymentsConfig struct {
+	BaseURL   string        `json:"base_url"`
+	APIKey    string        `json:"api_key"`
+	IPNSecret string        `json:"ipn_secret"`
+	Timeout   time.Duration `json:"timeout"`
+}
+
 // SystemConfig holds system/tax actors and wallets UUIDs configured by admin
 type SystemConfig struct {
 	SystemUserUUID    string `json:"system_user_uuid"`
@@ -366,6 +544,53 @@ type SystemConfig struct {
 	SystemWalletUUID  string `json:"system_wallet_uuid"`
 	TaxWalletUUID     string `json:"tax_wallet_uuid"`
 	SystemShebaNumber string `json:"system_sheba_number"`
+	// TaxRateSchedule is the raw TAX_RATE_SCHEDULE value; TaxRates is its parsed form
+	TaxRateSchedule string              `json:"tax_rate_schedule"`
+	TaxRates        pricing.TaxSchedule `json:"tax_rates"`
+}
+
+// TaxRateAt returns the VAT rate in effect at t
+func (c SystemConfig) TaxRateAt(t time.Time) pricing.TaxRate {
+	return c.TaxRates.RateAt(t)
+}
+
+// InvoiceConfig identifies the legal entity that issues official invoices
+// for wallet charges and how their PDFs are rendered
+type InvoiceConfig struct {
+	// LegalEntityCode prefixes invoice numbers; each code has its own
+	// gap-free sequence, so changing it starts numbering from 1 again
+	LegalEntityCode          string `json:"legal_entity_code"`
+	SellerName               string `json:"seller_name"`
+	SellerEconomicCode       string `json:"seller_economic_code"`
+	SellerNationalID         string `json:"seller_national_id"`
+	SellerRegistrationNumber string `json:"seller_registration_number"`
+	SellerAddress            string `json:"seller_address"`
+	SellerPostalCode         string `json:"seller_postal_code"`
+	SellerPhone              string `json:"seller_phone"`
+	// TrueType fonts with Persian glyphs, e.g. Vazirmatn
+	FontPath     string `json:"font_path"`
+	BoldFontPath string `json:"bold_font_path"`
+	// StorageDir holds rendered invoice PDFs
+	StorageDir string `json:"storage_dir"`
+}
+
+// MoadianConfig connects to Moadian, the tax authority's e-invoicing system.
+// Issued invoices are queued and submitted in the background; transient
+// failures are retried with backoff up to MaxAttempts times.
+type MoadianConfig struct {
+	Enabled bool   `json:"enabled"`
+	BaseURL string `json:"base_url"`
+	// MemoryID is the taxpayer's fiscal memory ID (شناسه یکتای حافظه مالیاتی)
+	MemoryID string `json:"memory_id"`
+	// PEM RSA private key and X.509 certificate registered for MemoryID
+	PrivateKeyPath  string `json:"private_key_path"`
+	CertificatePath string `json:"certificate_path"`
+	// ServiceID is the 13-digit goods and services ID of wallet top-ups
+	ServiceID   string        `json:"service_id"`
+	Timeout     time.Duration `json:"timeout"`
+	Interval    time.Duration `json:"interval"`
+	BatchSize   int           `json:"batch_size"`
+	MaxAttempts int           `json:"max_attempts"`
 }
 
 // PayamSMSConfig holds credentials and endpoints for PayamSMS OAuth
@@ -399,20 +624,304 @@ type SplusConfig struct {
 	BaseURL string `json:"base_url"`
 }
 
+// TelegramConfig holds the bot customers link their Telegram chat to for
+// campaign and payment notices. The channel is off while BotToken is empty.
+type TelegramConfig struct {
+	BotToken    string `json:"-"`
+	BotUsername string `json:"bot_username"`
+	BaseURL     string `json:"base_url"`
+	// WebhookSecret is the secret_token the webhook was registered with;
+	// Telegram echoes it in the X-Telegram-Bot-Api-Secret-Token header
+	WebhookSecret string        `json:"-"`
+	LinkTTL       time.Duration `json:"link_ttl"`
+	Timeout       time.Duration `json:"timeout"`
+}
+
+// Enabled reports whether a bot is configured
+func (c TelegramConfig) Enabled() bool {
+	return c.BotToken != ""
+}
+
+// FCMConfig holds the Firebase project push notifications are sent through
+// to the dashboard PWA. Push is off while CredentialsFile is empty.
+type FCMConfig struct {
+	// CredentialsFile is the service account JSON key of the project
+	CredentialsFile string `json:"credentials_file"`
+	BaseURL         string `json:"base_url"`
+	IIDBaseURL      string `json:"iid_base_url"`
+	// BroadcastTopic is the topic every registered device is subscribed to,
+	// for announcements to all customers
+	BroadcastTopic string        `json:"broadcast_topic"`
+	Timeout        time.Duration `json:"timeout"`
+}
+
+// Enabled reports whether a Firebase project is configured
+func (c FCMConfig) Enabled() bool {
+	return c.CredentialsFile != ""
+}
+
+// Storage drivers
+const (
+	StorageDriverS3    = "s3"
+	StorageDriverLocal = "local"
+)
+
+// StorageConfig selects where private files such as KYC documents, exports
+// and templates are kept: an S3 compatible bucket (S3 or MinIO) or a local
+// directory. Storage is off while Driver is empty.
+type StorageConfig struct {
+	Driver    string `json:"driver"`
+	Endpoint  string `json:"endpoint"`
+	Region    string `json:"region"`
+	Bucket    string `json:"bucket"`
+	AccessKey string `json:"-"`
+	SecretKey string `json:"-"`
+	// PathStyle addresses the bucket in the path, as MinIO expects
+	PathStyle bool          `json:"path_style"`
+	Timeout   time.Duration `json:"timeout"`
+	LocalDir  string        `json:"local_dir"`
+	// LocalBaseURL is the public URL of the route serving LocalDir, which
+	// signed download links point at
+	LocalBaseURL    string `json:"local_base_url"`
+	LocalSigningKey string `json:"-"`
+	// SignedURLTTL is how long a download link handed to a client works
+	SignedURLTTL time.Duration `json:"signed_url_ttl"`
+	// Lifecycle expires the files under each key prefix after the duration
+	Lifecycle map[string]time.Duration `json:"lifecycle"`
+}
+
+// Enabled reports whether a storage driver is configured
+func (c StorageConfig) Enabled() bool {
+	return c.Driver != ""
+}
+
+// UploadsConfig holds the ClamAV daemon private customer uploads such as KYC
+// documents are scanned with before they are stored. Scanning is skipped
+// while ClamAVAddress is empty.
+type UploadsConfig struct {
+	ClamAVAddress string        `json:"clamav_address"`
+	ClamAVTimeout time.Duration `json:"clamav_timeout"`
+}
+
+// KYCConfig caps the sending quota of company customers whose documents are
+// not verified yet. A zero limit leaves that period uncapped.
+type KYCConfig struct {
+	UnverifiedDailyLimit   uint64 `json:"unverified_daily_limit"`
+	UnverifiedMonthlyLimit uint64 `json:"unverified_monthly_limit"`
+}
+
+// ShebaInquiryConfig holds the Jibit bank inquiry API the owners of Sheba
+// numbers are looked up with. While it is configured, wallet recharges split
+// with an agency wait until the agency's Sheba number is verified to belong
+// to the agency. Verification is off while APIKey is empty.
+type ShebaInquiryConfig struct {
+	BaseURL   string `json:"base_url"`
+	APIKey    string `json:"-"`
+	SecretKey string `json:"-"`
+	// CacheTTL is how long an inquiry result is reused for the same number
+	CacheTTL time.Duration `json:"cache_ttl"`
+	Timeout  time.Duration `json:"timeout"`
+}
+
+// Enabled reports whether a bank inquiry provider is configured
+func (c ShebaInquiryConfig) Enabled() bool {
+	return c.APIKey != ""
+}
+
+// MocksConfig replaces providers with in-process fakes answering with fixed
+// fixtures, so the full payment, SMS and campaign flows run locally. Mocks
+// are refused in production.
+type MocksConfig struct {
+	Atipay   ProviderMockConfig `json:"atipay"`
+	Oxapay   ProviderMockConfig `json:"oxapay"`
+	PayamSMS ProviderMockConfig `json:"payam_sms"`
+	Bot      ProviderMockConfig `json:"bot"`
+}
+
+// ProviderMockConfig mocks one provider. FailureRate is the share of
+// requests, between 0 and 1, answered with 503.
+type ProviderMockConfig struct {
+	Enabled     bool          `json:"enabled"`
+	Latency     time.Duration `json:"latency"`
+	FailureRate float64       `json:"failure_rate"`
+}
+
+// Any reports whether at least one provider is mocked
+func (c MocksConfig) Any() bool {
+	return c.Atipay.Enabled || c.Oxapay.Enabled || c.PayamSMS.Enabled || c.Bot.Enabled
+}
+
 type SchedulerConfig struct {
 	CampaignExecutionEnabled  bool          `json:"campaign_execution_enabled"`
 	CampaignExecutionInterval time.Duration `json:"campaign_execution_interval"`
 	MessageSendDelay          time.Duration `json:"message_send_delay"`
+
+	// Recurring campaigns: occurrences are materialized RecurrenceLookahead
+	// before they are due.
+	RecurrenceEnabled   bool          `json:"recurrence_enabled"`
+	RecurrenceInterval  time.Duration `json:"recurrence_interval"`
+	RecurrenceLookahead time.Duration `json:"recurrence_lookahead"`
+
+	// Bulk audience CSV imports are processed in the background
+	AudienceImportEnabled  bool          `json:"audience_import_enabled"`
+	AudienceImportInterval time.Duration `json:"audience_import_interval"`
+
+	// Customer data exports and account deletions are processed in the
+	// background. Deletions are carried out AccountDeletionGracePeriod after
+	// they were requested; export files can be downloaded for DataExportRetention.
+	CustomerDataEnabled        bool          `json:"customer_data_enabled"`
+	CustomerDataInterval       time.Duration `json:"customer_data_interval"`
+	AccountDeletionGracePeriod time.Duration `json:"account_deletion_grace_period"`
+	DataExportRetention        time.Duration `json:"data_export_retention"`
+
+	// sent_sms and audit_log are partitioned by month; partitions are created
+	// PartitionMonthsAhead months ahead and those older than
+	// PartitionRetentionMonths full months are archived to PartitionArchiveDir
+	// and dropped. A retention of 0 disables archival.
+	PartitionMaintenanceEnabled  bool          `json:"partition_maintenance_enabled"`
+	PartitionMaintenanceInterval time.Duration `json:"partition_maintenance_interval"`
+	PartitionMonthsAhead         int           `json:"partition_months_ahead"`
+	PartitionRetentionMonths     int           `json:"partition_retention_months"`
+	PartitionArchiveDir          string        `json:"partition_archive_dir"`
+
+	// The reporting rollups are re-rolled for the current Tehran day every
+	// ReportRollupInterval; the first run of each day also re-rolls the
+	// ReportRollupLookbackDays before it to pick up late delivery reports
+	// and refunds
+	ReportRollupEnabled      bool          `json:"report_rollup_enabled"`
+	ReportRollupInterval     time.Duration `json:"report_rollup_interval"`
+	ReportRollupLookbackDays int           `json:"report_rollup_lookback_days"`
+
+	// The storage lifecycle rules are applied every StorageLifecycleInterval
+	// while any are configured
+	StorageLifecycleEnabled  bool          `json:"storage_lifecycle_enabled"`
+	StorageLifecycleInterval time.Duration `json:"storage_lifecycle_interval"`
+
+	// Audience profiles are moved between colors every AudienceColorInterval:
+	// white ones are demoted to pink after AudienceColorDemoteFailures
+	// undelivered SMS without a delivered one, or once their number is
+	// blacklisted when AudienceColorDemoteOptOuts is set, or after an SMS was
+	// rejected with a permanent error code when
+	// AudienceColorDemotePermanentErrors is set, and pink ones are promoted to
+	// white after AudienceColorPromoteClicks short link clicks.
+	// Only activity within AudienceColorLookback and after a profile's last
+	// color change counts; a threshold of 0 disables its rule. Each rule moves
+	// at most AudienceColorBatchSize profiles per run.
+	AudienceColorEnabled               bool          `json:"audience_color_enabled"`
+	AudienceColorInterval              time.Duration `json:"audience_color_interval"`
+	AudienceColorLookback              time.Duration `json:"audience_color_lookback"`
+	AudienceColorDemoteFailures        int           `json:"audience_color_demote_failures"`
+	AudienceColorDemoteOptOuts         bool          `json:"audience_color_demote_opt_outs"`
+	AudienceColorDemotePermanentErrors bool          `json:"audience_color_demote_permanent_errors"`
+	AudienceColorPromoteClicks         int           `json:"audience_color_promote_clicks"`
+	AudienceColorBatchSize             int           `json:"audience_color_batch_size"`
+
+	// SMS recipients the provider rejected with one of SMSRetryTransientCodes
+	// are resent from the same line SMSRetryDelay after each failed attempt,
+	// at most SMSRetryMaxAttempts times. Recipients rejected with one of
+	// SMSRetryPermanentCodes are not resent; their sent_sms rows are flagged
+	// for the color worker.
+	SMSRetryEnabled        bool          `json:"sms_retry_enabled"`
+	SMSRetryMaxAttempts    int           `json:"sms_retry_max_attempts"`
+	SMSRetryDelay          time.Duration `json:"sms_retry_delay"`
+	SMSRetryTransientCodes []string      `json:"sms_retry_transient_codes"`
+	SMSRetryPermanentCodes []string      `json:"sms_retry_permanent_codes"`
+
+	// Pending crypto payment requests past their payment window are expired
+	// in the background
+	CryptoExpiryEnabled  bool          `json:"crypto_expiry_enabled"`
+	CryptoExpiryInterval time.Duration `json:"crypto_expiry_interval"`
+
+	// Atipay payment requests still open past their expiry are expired in
+	// the background
+	PaymentExpiryEnabled  bool          `json:"payment_expiry_enabled"`
+	PaymentExpiryInterval time.Duration `json:"payment_expiry_interval"`
+
+	// Atipay's settlement report of the previous day is downloaded and
+	// reconciled against payment requests
+	AtipayReconciliationEnabled  bool          `json:"atipay_reconciliation_enabled"`
+	AtipayReconciliationInterval time.Duration `json:"atipay_reconciliation_interval"`
+
+	// Credit drawn by postpaid customers in the previous Tehran month is
+	// billed on an invoice due PostpaidInvoiceDueDays after the month ends
+	PostpaidInvoicingEnabled  bool          `json:"postpaid_invoicing_enabled"`
+	PostpaidInvoicingInterval time.Duration `json:"postpaid_invoicing_interval"`
+	PostpaidInvoiceDueDays    int           `json:"postpaid_invoice_due_days"`
+
+	// Campaigns still waiting for approval hours past their schedule time
+	// are expired and their reserved budget refunded
+	CampaignExpiryEnabled  bool          `json:"campaign_expiry_enabled"`
+	CampaignExpiryInterval time.Duration `json:"campaign_expiry_interval"`
+
+	// The background workers run on one replica at a time, the one holding
+	// the worker advisory lock; the others retry every LeaderRetryInterval.
+	// RunInAPI also runs them in the API server; turn it off when they are
+	// deployed separately with cmd/worker.
+	RunInAPI            bool          `json:"run_in_api"`
+	LeaderRetryInterval time.Duration `json:"leader_retry_interval"`
+}
+
+// OutboundHTTPConfig tunes the shared client used for calls to payment
+// gateways, SMS providers, bots and other third parties
+type OutboundHTTPConfig struct {
+	MaxIdleConns        int           `json:"max_idle_conns"`
+	MaxIdleConnsPerHost int           `json:"max_idle_conns_per_host"`
+	IdleConnTimeout     time.Duration `json:"idle_conn_timeout"`
+	// HostTimeouts overrides a client's own timeout for one host
+	HostTimeouts map[string]time.Duration `json:"host_timeouts"`
+	// Idempotent requests failing with a transport error, 429, 502, 503 or
+	// 504 are retried up to RetryMax times, backing off from RetryBackoff
+	RetryMax     int           `json:"retry_max"`
+	RetryBackoff time.Duration `json:"retry_backoff"`
+	// A host is failed fast for BreakerCooldown after BreakerThreshold
+	// consecutive transport errors or 5xx responses
+	BreakerThreshold int           `json:"breaker_threshold"`
+	BreakerCooldown  time.Duration `json:"breaker_cooldown"`
+}
+
+// JobQueueConfig tunes the persistent background job queue. Jobs are always
+// enqueued; Enabled runs the workers that process them.
+type JobQueueConfig struct {
+	Enabled      bool          `json:"enabled"`
+	PollInterval time.Duration `json:"poll_interval"`
+	Workers      int           `json:"workers"`
+	// A failed job is retried after BackoffBase, doubling per attempt up to
+	// BackoffMax, and is dead-lettered after MaxAttempts attempts
+	MaxAttempts int           `json:"max_attempts"`
+	BackoffBase time.Duration `json:"backoff_base"`
+	BackoffMax  time.Duration `json:"backoff_max"`
+	// Succeeded jobs are deleted Retention after they completed; dead jobs
+	// are kept until requeued
+	Retention time.Duration `json:"retention"`
+}
+
+// I18nConfig selects the locales of outgoing messages; the messages themselves
+// live in the app/i18n catalogs
+type I18nConfig struct {
+	// DefaultLocale is used for customers without a preferred locale
+	DefaultLocale string `json:"default_locale"`
+	// AdminLocale is used for SMS notifications to admins and deposit reviewers
+	AdminLocale string `json:"admin_locale"`
+}
+
+// WalletTransferConfig limits transfers between wallets of accounts of the
+// same company. Amounts are in toman.
+type WalletTransferConfig struct {
+	Enabled   bool   `json:"enabled"`
+	MinAmount uint64 `json:"min_amount"`
+	MaxAmount uint64 `json:"max_amount"`
+	// DailyLimit caps what one account sends over any 24 hours
+	DailyLimit uint64 `json:"daily_limit"`
 }
 
-type MessageConfig struct {
-	SignupVerificationCodeTemplate        string `json:"signup_verification_code_template"`
-	SigninVerificationCodeTemplate        string `json:"signin_verification_code_template"`
-	OTPResendVerificationCodeTemplate     string `json:"otp_resend_verification_code_template"`
-	PasswordResetVerificationCodeTemplate string `json:"password_reset_verification_code_template"`
-	CampaignRejectedTemplate              string `json:"campaign_rejected_template"`
-	DepositReceiptSubmittedTemplate       string `json:"deposit_receipt_submitted_template"`
-	InvoiceIssueRequestTemplate           string `json:"invoice_issue_request_template"`
+// HealthConfig tunes the readiness probe
+type HealthConfig struct {
+	// CheckTimeout bounds each dependency check
+	CheckTimeout time.Duration `json:"check_timeout"`
+	// ProviderChecks reports the reachability of SMS and payment providers
+	ProviderChecks bool `json:"provider_checks"`
+	// ProviderCacheTTL is how long a provider check result is reused
+	ProviderCacheTTL time.Duration `json:"provider_cache_ttl"`
 }
 
 type SmartTagEvaluationConfig struct {
@@ -463,10 +972,15 @@ const (
 
 // LoadProductionConfig loads and validates configuration from environment variables
 func LoadProductionConfig() (*ProductionConfig, error) {
+	malformedEnv = nil
+
 	// Load environment variables from .env file
 	if err := loadEnvFile(); err != nil {
 		return nil, fmt.Errorf("failed to load .env file: %w", err)
 	}
+	if err := loadRuntimeFile(); err != nil {
+		return nil, err
+	}
 
 	smartTagEvaluationEnabled := getEnvBool("SMART_TAG_EVALUATION_ENABLED", false)
 	personaAnalysisSystemPrompt, err := readConfigTextFile(
@@ -484,6 +998,10 @@ func LoadProductionConfig() (*ProductionConfig, error) {
 		return nil, fmt.Errorf("failed to load smart tag scoring system prompt: %w", err)
 	}
 
+	// An invalid schedule leaves TaxRates empty; ValidateProductionConfig reports it
+	taxRateSchedule := getEnvString("TAX_RATE_SCHEDULE", "")
+	taxRates, _ := pricing.ParseTaxSchedule(taxRateSchedule)
+
 	cfg := &ProductionConfig{
 		Database: DatabaseConfig{
 			Host:            getEnvString("DB_HOST", "localhost"),
@@ -498,6 +1016,8 @@ func LoadProductionConfig() (*ProductionConfig, error) {
 			ConnMaxIdleTime: getEnvDuration("DB_CONN_MAX_IDLE_TIME", 15*time.Minute),
 			SlowQueryLog:    getEnvBool("DB_SLOW_QUERY_LOG", true),
 			SlowQueryTime:   getEnvDuration("DB_SLOW_QUERY_TIME", 1*time.Second),
+			ReplicaDSN:      getEnvString("DB_REPLICA_DSN", ""),
+			MigrationCheck:  getEnvString("DB_MIGRATION_CHECK", "warn"),
 		},
 		Server: ServerConfig{
 			Host:              getEnvString("SERVER_HOST", "0.0.0.0"),
@@ -513,45 +1033,69 @@ func LoadProductionConfig() (*ProductionConfig, error) {
 			ProxyHeader:       getEnvString("SERVER_PROXY_HEADER", "X-Forwarded-For"),
 			EnableCompression: getEnvBool("SERVER_ENABLE_COMPRESSION", true),
 			CompressionLevel:  getEnvInt("SERVER_COMPRESSION_LEVEL", 6),
+			CountryHeader:     getEnvString("SERVER_COUNTRY_HEADER", ""),
+			V1DeprecatedAt:    getEnvTime("API_V1_DEPRECATED_AT"),
+			V1SunsetAt:        getEnvTime("API_V1_SUNSET_AT"),
+		},
+		GRPC: GRPCConfig{
+			Enabled: getEnvBool("GRPC_ENABLED", false),
+			Host:    getEnvString("GRPC_HOST", "127.0.0.1"),
+			Port:    getEnvInt("GRPC_PORT", 9091),
 		},
 		Security: SecurityConfig{
-			TLSEnabled:             getEnvBool("TLS_ENABLED", true),
-			TLSCertFile:            getEnvString("TLS_CERT_FILE", "/etc/ssl/certs/yamata.crt"),
-			TLSKeyFile:             getEnvString("TLS_KEY_FILE", "/etc/ssl/private/yamata.key"),
-			TLSMinVersion:          getEnvString("TLS_MIN_VERSION", "1.3"),
-			HSTSMaxAge:             getEnvInt("HSTS_MAX_AGE", 31536000), // 1 year
-			HSTSIncludeSubDoms:     getEnvBool("HSTS_INCLUDE_SUBDOMAINS", true),
-			HSTSPreload:            getEnvBool("HSTS_PRELOAD", true),
-			AllowedOrigins:         getEnvStringSlice("CORS_ALLOWED_ORIGINS", []string{"https://yamata-no-orochi.com", "https://api.yamata-no-orochi.com", "https://wwww.yamata-no-orochi.com", "https://monitoring.yamata-no-orochi.com", "https://admin.yamata-no-orochi.com"}),
-			AllowedMethods:         getEnvStringSlice("CORS_ALLOWED_METHODS", []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}),
-			AllowedHeaders:         getEnvStringSlice("CORS_ALLOWED_HEADERS", []string{"Origin", "Content-Type", "Accept", "Authorization", "X-Requested-With", "X-API-Key"}),
-			AllowCredentials:       getEnvBool("CORS_ALLOW_CREDENTIALS", true),
-			CORSMaxAge:             getEnvInt("CORS_MAX_AGE", 86400),
-			AuthRateLimit:          getEnvInt("AUTH_RATE_LIMIT", 20),
-			GlobalRateLimit:        getEnvInt("GLOBAL_RATE_LIMIT", 2000),
-			RateLimitWindow:        getEnvDuration("RATE_LIMIT_WINDOW", 1*time.Minute),
-			RateLimitMemory:        getEnvInt("RATE_LIMIT_MEMORY", 64), // MB
-			CSPPolicy:              getEnvString("CSP_POLICY", "default-src 'self'; script-src 'self' 'unsafe-inline'; style-src 'self' 'unsafe-inline'; img-src 'self' data: https: blob:; font-src 'self' https:; connect-src 'self' https:; frame-ancestors 'none';"),
-			XFrameOptions:          getEnvString("X_FRAME_OPTIONS", "DENY"),
-			XContentTypeOptions:    getEnvString("X_CONTENT_TYPE_OPTIONS", "nosniff"),
-			XSSProtection:          getEnvString("XSS_PROTECTION", "1; mode=block"),
-			ReferrerPolicy:         getEnvString("REFERRER_POLICY", "strict-origin-when-cross-origin"),
-			RequireAPIKey:          getEnvBool("REQUIRE_API_KEY", false),
-			APIKeyHeader:           getEnvString("API_KEY_HEADER", "X-API-Key"),
-			AllowedAPIKeys:         getEnvStringSlice("ALLOWED_API_KEYS", []string{}),
-			IPWhitelist:            getEnvStringSlice("IP_WHITELIST", []string{}),
-			IPBlacklist:            getEnvStringSlice("IP_BLACKLIST", []string{}),
-			PasswordMinLength:      getEnvInt("PASSWORD_MIN_LENGTH", 8),
-			PasswordRequireUpper:   getEnvBool("PASSWORD_REQUIRE_UPPER", true),
-			PasswordRequireLower:   getEnvBool("PASSWORD_REQUIRE_LOWER", true),
-			PasswordRequireNum:     getEnvBool("PASSWORD_REQUIRE_NUMBER", true),
-			PasswordRequireSymbol:  getEnvBool("PASSWORD_REQUIRE_SYMBOL", true),
-			BcryptCost:             getEnvInt("BCRYPT_COST", 12),
-			SessionCookieSecure:    getEnvBool("SESSION_COOKIE_SECURE", true),
-			SessionCookieHTTPOnly:  getEnvBool("SESSION_COOKIE_HTTPONLY", true),
-			SessionCookieSameSite:  getEnvString("SESSION_COOKIE_SAMESITE", "Strict"),
-			SessionTimeout:         getEnvDuration("SESSION_TIMEOUT", 24*time.Hour),
-			SessionCleanupInterval: getEnvDuration("SESSION_CLEANUP_INTERVAL", 1*time.Hour),
+			TLSEnabled:               getEnvBool("TLS_ENABLED", true),
+			TLSCertFile:              getEnvString("TLS_CERT_FILE", "/etc/ssl/certs/yamata.crt"),
+			TLSKeyFile:               getEnvString("TLS_KEY_FILE", "/etc/ssl/private/yamata.key"),
+			TLSMinVersion:            getEnvString("TLS_MIN_VERSION", "1.3"),
+			HSTSMaxAge:               getEnvInt("HSTS_MAX_AGE", 31536000), // 1 year
+			HSTSIncludeSubDoms:       getEnvBool("HSTS_INCLUDE_SUBDOMAINS", true),
+			HSTSPreload:              getEnvBool("HSTS_PRELOAD", true),
+			AllowedOrigins:           getEnvStringSlice("CORS_ALLOWED_ORIGINS", []string{"https://yamata-no-orochi.com", "https://api.yamata-no-orochi.com", "https://wwww.yamata-no-orochi.com", "https://monitoring.yamata-no-orochi.com", "https://admin.yamata-no-orochi.com"}),
+			AllowedMethods:           getEnvStringSlice("CORS_ALLOWED_METHODS", []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}),
+			AllowedHeaders:           getEnvStringSlice("CORS_ALLOWED_HEADERS", []string{"Origin", "Content-Type", "Accept", "Authorization", "X-Requested-With", "X-API-Key"}),
+			AllowCredentials:         getEnvBool("CORS_ALLOW_CREDENTIALS", true),
+			CORSMaxAge:               getEnvInt("CORS_MAX_AGE", 86400),
+			AuthRateLimit:            getEnvInt("AUTH_RATE_LIMIT", 20),
+			GlobalRateLimit:          getEnvInt("GLOBAL_RATE_LIMIT", 2000),
+			RateLimitWindow:          getEnvDuration("RATE_LIMIT_WINDOW", 1*time.Minute),
+			RateLimitMemory:          getEnvInt("RATE_LIMIT_MEMORY", 64), // MB
+			OTPRateLimit:             getEnvInt("OTP_RATE_LIMIT", 5),
+			PaymentIPRateLimit:       getEnvInt("PAYMENT_IP_RATE_LIMIT", 60),
+			PaymentRateLimit:         getEnvInt("PAYMENT_RATE_LIMIT", 20),
+			OTPCooldown:              getEnvDuration("OTP_COOLDOWN", 90*time.Second),
+			OTPDailyLimit:            getEnvInt("OTP_DAILY_LIMIT", 5),
+			CSPPolicy:                getEnvString("CSP_POLICY", "default-src 'self'; script-src 'self' 'unsafe-inline'; style-src 'self' 'unsafe-inline'; img-src 'self' data: https: blob:; font-src 'self' https:; connect-src 'self' https:; frame-ancestors 'none';"),
+			XFrameOptions:            getEnvString("X_FRAME_OPTIONS", "DENY"),
+			XContentTypeOptions:      getEnvString("X_CONTENT_TYPE_OPTIONS", "nosniff"),
+			XSSProtection:            getEnvString("XSS_PROTECTION", "1; mode=block"),
+			ReferrerPolicy:           getEnvString("REFERRER_POLICY", "strict-origin-when-cross-origin"),
+			RequireAPIKey:            getEnvBool("REQUIRE_API_KEY", false),
+			APIKeyHeader:             getEnvString("API_KEY_HEADER", "X-API-Key"),
+			AllowedAPIKeys:           getEnvStringSlice("ALLOWED_API_KEYS", []string{}),
+			IPWhitelist:              getEnvStringSlice("IP_WHITELIST", []string{}),
+			IPBlacklist:              getEnvStringSlice("IP_BLACKLIST", []string{}),
+			AdminIPAllowlist:         getEnvStringSlice("ADMIN_IP_ALLOWLIST", []string{}),
+			PasswordMinLength:        getEnvInt("PASSWORD_MIN_LENGTH", 8),
+			PasswordMaxLength:        getEnvInt("PASSWORD_MAX_LENGTH", 100),
+			PasswordRequireUpper:     getEnvBool("PASSWORD_REQUIRE_UPPER", true),
+			PasswordRequireLower:     getEnvBool("PASSWORD_REQUIRE_LOWER", true),
+			PasswordRequireNum:       getEnvBool("PASSWORD_REQUIRE_NUMBER", true),
+			PasswordRequireSymbol:    getEnvBool("PASSWORD_REQUIRE_SYMBOL", true),
+			PasswordBreachCheck:      getEnvBool("PASSWORD_BREACH_CHECK", false),
+			PasswordBreachAPIURL:     getEnvString("PASSWORD_BREACH_API_URL", "https://api.pwnedpasswords.com"),
+			PasswordBreachTimeout:    getEnvDuration("PASSWORD_BREACH_TIMEOUT", 3*time.Second),
+			PasswordBreachCacheTTL:   getEnvDuration("PASSWORD_BREACH_CACHE_TTL", 24*time.Hour),
+			BcryptCost:               getEnvInt("BCRYPT_COST", 12),
+			Argon2Memory:             getEnvInt("ARGON2_MEMORY", 64*1024),
+			Argon2Iterations:         getEnvInt("ARGON2_ITERATIONS", 3),
+			Argon2Parallelism:        getEnvInt("ARGON2_PARALLELISM", 2),
+			SessionCookieSecure:      getEnvBool("SESSION_COOKIE_SECURE", true),
+			SessionCookieHTTPOnly:    getEnvBool("SESSION_COOKIE_HTTPONLY", true),
+			SessionCookieSameSite:    getEnvString("SESSION_COOKIE_SAMESITE", "Strict"),
+			SessionTimeout:           getEnvDuration("SESSION_TIMEOUT", 24*time.Hour),
+			SessionCleanupInterval:   getEnvDuration("SESSION_CLEANUP_INTERVAL", 1*time.Hour),
+			LoginAlertURL:            getEnvString("LOGIN_ALERT_URL", ""),
+			PasswordlessLoginEnabled: getEnvBool("PASSWORDLESS_LOGIN_ENABLED", false),
 		},
 		JWT: JWTConfig{
 			SecretKey:       getEnvString("JWT_SECRET_KEY", ""),
@@ -612,6 +1156,11 @@ func LoadProductionConfig() (*ProductionConfig, error) {
 			AuditLogPath:      getEnvString("LOG_AUDIT_PATH", "/var/log/yamata/audit.log"),
 			EnableSecurityLog: getEnvBool("LOG_ENABLE_SECURITY", true),
 			SecurityLogPath:   getEnvString("LOG_SECURITY_PATH", "/var/log/yamata/security.log"),
+
+			AuditBufferEnabled: getEnvBool("LOG_AUDIT_BUFFER_ENABLED", true),
+			AuditBufferSize:    getEnvInt("LOG_AUDIT_BUFFER_SIZE", 4096),
+			AuditBatchSize:     getEnvInt("LOG_AUDIT_BATCH_SIZE", 200),
+			AuditFlushInterval: getEnvDuration("LOG_AUDIT_FLUSH_INTERVAL", time.Second),
 		},
 		Metrics: MetricsConfig{
 			Enabled:             getEnvBool("METRICS_ENABLED", true),
@@ -654,8 +1203,12 @@ func LoadProductionConfig() (*ProductionConfig, error) {
 			BuildTime:            getEnvString("BUILD_TIME", "unknown"),
 		},
 		Atipay: AtipayConfig{
-			APIKey:   getEnvString("ATIPAY_API_KEY", ""),
-			Terminal: getEnvString("ATIPAY_TERMINAL", ""),
+			APIKey:              getEnvString("ATIPAY_API_KEY", ""),
+			Terminal:            getEnvString("ATIPAY_TERMINAL", ""),
+			Terminals:           getEnvString("ATIPAY_TERMINALS", ""),
+			Routing:             getEnvString("ATIPAY_ROUTING", AtipayRoutingRoundRobin),
+			SettlementReportURL: getEnvString("ATIPAY_SETTLEMENT_REPORT_URL", ""),
+			SettlementFallback:  getEnvBool("ATIPAY_SETTLEMENT_FALLBACK", false),
 		},
 		Admin: AdminConfig{
 			Mobiles:               getEnvStringSlice("ADMIN_MOBILE", []string{}),
@@ -663,6 +1216,8 @@ func LoadProductionConfig() (*ProductionConfig, error) {
 			TwoFAMobiles:          getEnvStringMap("ADMIN_2FA_MOBILES", map[string]string{}),
 			OTPBypassMobiles:      getEnvStringSlice("ADMIN_OTP_BYPASS_MOBILES", []string{}),
 			LoginOTPForwardMobile: getEnvString("ADMIN_LOGIN_OTP_FORWARD_MOBILE", ""),
+			TOTPIssuer:            getEnvString("ADMIN_TOTP_ISSUER", "Yamata no Orochi"),
+			ImpersonationTTL:      getEnvDuration("ADMIN_IMPERSONATION_TTL", 30*time.Minute),
 		},
 		System: SystemConfig{
 			SystemUserUUID:    getEnvString("SYSTEM_USER_UUID", ""),
@@ -674,6 +1229,33 @@ func LoadProductionConfig() (*ProductionConfig, error) {
 			SystemWalletUUID:  getEnvString("SYSTEM_WALLET_UUID", ""),
 			TaxWalletUUID:     getEnvString("TAX_WALLET_UUID", ""),
 			SystemShebaNumber: getEnvString("SYSTEM_SHEBA_NUMBER", ""),
+			TaxRateSchedule:   taxRateSchedule,
+			TaxRates:          taxRates,
+		},
+		Invoice: InvoiceConfig{
+			LegalEntityCode:          getEnvString("INVOICE_LEGAL_ENTITY_CODE", "JZB"),
+			SellerName:               getEnvString("INVOICE_SELLER_NAME", "پلتفرم جاذبه"),
+			SellerEconomicCode:       getEnvString("INVOICE_SELLER_ECONOMIC_CODE", ""),
+			SellerNationalID:         getEnvString("INVOICE_SELLER_NATIONAL_ID", ""),
+			SellerRegistrationNumber: getEnvString("INVOICE_SELLER_REGISTRATION_NUMBER", ""),
+			SellerAddress:            getEnvString("INVOICE_SELLER_ADDRESS", ""),
+			SellerPostalCode:         getEnvString("INVOICE_SELLER_POSTAL_CODE", ""),
+			SellerPhone:              getEnvString("INVOICE_SELLER_PHONE", ""),
+			FontPath:                 getEnvString("INVOICE_FONT_PATH", "/usr/share/fonts/vazirmatn/Vazirmatn-Regular.ttf"),
+			BoldFontPath:             getEnvString("INVOICE_BOLD_FONT_PATH", "/usr/share/fonts/vazirmatn/Vazirmatn-Bold.ttf"),
+			StorageDir:               getEnvString("INVOICE_STORAGE_DIR", "data/invoices"),
+		},
+		Moadian: MoadianConfig{
+			Enabled:         getEnvBool("MOADIAN_ENABLED", false),
+			BaseURL:         getEnvString("MOADIAN_BASE_URL", "https://tp.tax.gov.ir/requestsmanager/api/v1"),
+			MemoryID:        getEnvString("MOADIAN_MEMORY_ID", ""),
+			PrivateKeyPath:  getEnvString("MOADIAN_PRIVATE_KEY_PATH", ""),
+			CertificatePath: getEnvString("MOADIAN_CERTIFICATE_PATH", ""),
+			ServiceID:       getEnvString("MOADIAN_SERVICE_ID", ""),
+			Timeout:         getEnvDuration("MOADIAN_TIMEOUT", 30*time.Second),
+			Interval:        getEnvDuration("MOADIAN_SUBMISSION_INTERVAL", 5*time.Minute),
+			BatchSize:       getEnvInt("MOADIAN_BATCH_SIZE", 50),
+			MaxAttempts:     getEnvInt("MOADIAN_MAX_ATTEMPTS", 10),
 		},
 		PayamSMS: PayamSMSConfig{
 			TokenURL:        getEnvString("PAYAM_SMS_TOKEN_URL", "https://www.payamsms.com/auth/oauth/token/"),
@@ -698,33 +1280,186 @@ func LoadProductionConfig() (*ProductionConfig, error) {
 		Splus: SplusConfig{
 			BaseURL: getEnvString("SPLUS_BASE_URL", "https://bui.splus.ir"),
 		},
+		FCM: FCMConfig{
+			CredentialsFile: getEnvString("FCM_CREDENTIALS_FILE", ""),
+			BaseURL:         getEnvString("FCM_BASE_URL", "https://fcm.googleapis.com"),
+			IIDBaseURL:      getEnvString("FCM_IID_BASE_URL", "https://iid.googleapis.com"),
+			BroadcastTopic:  getEnvString("FCM_BROADCAST_TOPIC", "customers"),
+			Timeout:         getEnvDuration("FCM_TIMEOUT", 10*time.Second),
+		},
+		Storage: StorageConfig{
+			Driver:          strings.ToLower(getEnvString("STORAGE_DRIVER", "")),
+			Endpoint:        getEnvString("STORAGE_ENDPOINT", ""),
+			Region:          getEnvString("STORAGE_REGION", "us-east-1"),
+			Bucket:          getEnvString("STORAGE_BUCKET", ""),
+			AccessKey:       getEnvString("STORAGE_ACCESS_KEY", ""),
+			SecretKey:       getEnvString("STORAGE_SECRET_KEY", ""),
+			PathStyle:       getEnvBool("STORAGE_PATH_STYLE", true),
+			Timeout:         getEnvDuration("STORAGE_TIMEOUT", 30*time.Second),
+			LocalDir:        getEnvString("STORAGE_LOCAL_DIR", "data/storage"),
+			LocalBaseURL:    getEnvString("STORAGE_LOCAL_BASE_URL", ""),
+			LocalSigningKey: getEnvString("STORAGE_LOCAL_SIGNING_KEY", ""),
+			SignedURLTTL:    getEnvDuration("STORAGE_SIGNED_URL_TTL", 15*time.Minute),
+			Lifecycle:       getEnvDurationMap("STORAGE_LIFECYCLE"),
+		},
+		Uploads: UploadsConfig{
+			ClamAVAddress: getEnvString("UPLOADS_CLAMAV_ADDRESS", ""),
+			ClamAVTimeout: getEnvDuration("UPLOADS_CLAMAV_TIMEOUT", 30*time.Second),
+		},
+		KYC: KYCConfig{
+			UnverifiedDailyLimit:   getEnvUint64("KYC_UNVERIFIED_DAILY_LIMIT", 0),
+			UnverifiedMonthlyLimit: getEnvUint64("KYC_UNVERIFIED_MONTHLY_LIMIT", 0),
+		},
+		ShebaInquiry: ShebaInquiryConfig{
+			BaseURL:   getEnvString("SHEBA_INQUIRY_BASE_URL", "https://napi.jibit.ir/ide"),
+			APIKey:    getEnvString("SHEBA_INQUIRY_API_KEY", ""),
+			SecretKey: getEnvString("SHEBA_INQUIRY_SECRET_KEY", ""),
+			CacheTTL:  getEnvDuration("SHEBA_INQUIRY_CACHE_TTL", 24*time.Hour),
+			Timeout:   getEnvDuration("SHEBA_INQUIRY_TIMEOUT", 10*time.Second),
+		},
+		Telegram: TelegramConfig{
+			BotToken:      getEnvString("TELEGRAM_BOT_TOKEN", ""),
+			BotUsername:   getEnvString("TELEGRAM_BOT_USERNAME", ""),
+			BaseURL:       getEnvString("TELEGRAM_BASE_URL", "https://api.telegram.org"),
+			WebhookSecret: getEnvString("TELEGRAM_WEBHOOK_SECRET", ""),
+			LinkTTL:       getEnvDuration("TELEGRAM_LINK_TTL", 15*time.Minute),
+			Timeout:       getEnvDuration("TELEGRAM_TIMEOUT", 10*time.Second),
+		},
 		Bot: BotConfig{
-			Username:  getEnvString("BOT_USERNAME", ""),
-			Password:  getEnvString("BOT_PASSWORD", ""),
-			APIDomain: getEnvString("BOT_API_DOMAIN", ""),
+			Username:    getEnvString("BOT_USERNAME", ""),
+			Password:    getEnvString("BOT_PASSWORD", ""),
+			APIDomain:   getEnvString("BOT_API_DOMAIN", ""),
+			GRPCAddress: getEnvString("BOT_GRPC_ADDRESS", ""),
 		},
 		Scheduler: SchedulerConfig{
 			CampaignExecutionEnabled:  getEnvBool("CAMPAIGN_EXECUTION_ENABLED", true),
 			CampaignExecutionInterval: getEnvDuration("CAMPAIGN_EXECUTION_INTERVAL", 1*time.Minute),
 			MessageSendDelay:          getEnvDuration("CAMPAIGN_MESSAGE_SEND_DELAY", 23*time.Millisecond),
+			RecurrenceEnabled:         getEnvBool("CAMPAIGN_RECURRENCE_ENABLED", true),
+			RecurrenceInterval:        getEnvDuration("CAMPAIGN_RECURRENCE_INTERVAL", 1*time.Minute),
+			RecurrenceLookahead:       getEnvDuration("CAMPAIGN_RECURRENCE_LOOKAHEAD", 15*time.Minute),
+			AudienceImportEnabled:     getEnvBool("AUDIENCE_IMPORT_ENABLED", true),
+			AudienceImportInterval:    getEnvDuration("AUDIENCE_IMPORT_INTERVAL", 30*time.Second),
+
+			CustomerDataEnabled:        getEnvBool("CUSTOMER_DATA_ENABLED", true),
+			CustomerDataInterval:       getEnvDuration("CUSTOMER_DATA_INTERVAL", 1*time.Minute),
+			AccountDeletionGracePeriod: getEnvDuration("ACCOUNT_DELETION_GRACE_PERIOD", 30*24*time.Hour),
+			DataExportRetention:        getEnvDuration("DATA_EXPORT_RETENTION", 7*24*time.Hour),
+
+			PartitionMaintenanceEnabled:        getEnvBool("PARTITION_MAINTENANCE_ENABLED", true),
+			PartitionMaintenanceInterval:       getEnvDuration("PARTITION_MAINTENANCE_INTERVAL", 6*time.Hour),
+			PartitionMonthsAhead:               getEnvInt("PARTITION_MONTHS_AHEAD", 3),
+			PartitionRetentionMonths:           getEnvInt("PARTITION_RETENTION_MONTHS", 12),
+			PartitionArchiveDir:                getEnvString("PARTITION_ARCHIVE_DIR", "data/archives"),
+			ReportRollupEnabled:                getEnvBool("REPORT_ROLLUP_ENABLED", true),
+			ReportRollupInterval:               getEnvDuration("REPORT_ROLLUP_INTERVAL", time.Hour),
+			ReportRollupLookbackDays:           getEnvInt("REPORT_ROLLUP_LOOKBACK_DAYS", 3),
+			StorageLifecycleEnabled:            getEnvBool("STORAGE_LIFECYCLE_ENABLED", true),
+			StorageLifecycleInterval:           getEnvDuration("STORAGE_LIFECYCLE_INTERVAL", 24*time.Hour),
+			AudienceColorEnabled:               getEnvBool("AUDIENCE_COLOR_ENABLED", false),
+			AudienceColorInterval:              getEnvDuration("AUDIENCE_COLOR_INTERVAL", time.Hour),
+			AudienceColorLookback:              getEnvDuration("AUDIENCE_COLOR_LOOKBACK", 30*24*time.Hour),
+			AudienceColorDemoteFailures:        getEnvInt("AUDIENCE_COLOR_DEMOTE_FAILURES", 3),
+			AudienceColorDemoteOptOuts:         getEnvBool("AUDIENCE_COLOR_DEMOTE_OPT_OUTS", true),
+			AudienceColorDemotePermanentErrors: getEnvBool("AUDIENCE_COLOR_DEMOTE_PERMANENT_ERRORS", true),
+			AudienceColorPromoteClicks:         getEnvInt("AUDIENCE_COLOR_PROMOTE_CLICKS", 1),
+			AudienceColorBatchSize:             getEnvInt("AUDIENCE_COLOR_BATCH_SIZE", 1000),
+			SMSRetryEnabled:                    getEnvBool("SMS_RETRY_ENABLED", false),
+			SMSRetryMaxAttempts:                getEnvInt("SMS_RETRY_MAX_ATTEMPTS", 3),
+			SMSRetryDelay:                      getEnvDuration("SMS_RETRY_DELAY", 10*time.Minute),
+			SMSRetryTransientCodes:             getEnvStringSlice("SMS_RETRY_TRANSIENT_CODES", []string{}),
+			SMSRetryPermanentCodes:             getEnvStringSlice("SMS_RETRY_PERMANENT_CODES", []string{}),
+			CryptoExpiryEnabled:                getEnvBool("CRYPTO_EXPIRY_ENABLED", true),
+			CryptoExpiryInterval:               getEnvDuration("CRYPTO_EXPIRY_INTERVAL", time.Minute),
+			PaymentExpiryEnabled:               getEnvBool("PAYMENT_EXPIRY_ENABLED", true),
+			PaymentExpiryInterval:              getEnvDuration("PAYMENT_EXPIRY_INTERVAL", time.Minute),
+			AtipayReconciliationEnabled:        getEnvBool("ATIPAY_RECONCILIATION_ENABLED", false),
+			AtipayReconciliationInterval:       getEnvDuration("ATIPAY_RECONCILIATION_INTERVAL", 6*time.Hour),
+			PostpaidInvoicingEnabled:           getEnvBool("POSTPAID_INVOICING_ENABLED", true),
+			PostpaidInvoicingInterval:          getEnvDuration("POSTPAID_INVOICING_INTERVAL", time.Hour),
+			PostpaidInvoiceDueDays:             getEnvInt("POSTPAID_INVOICE_DUE_DAYS", 15),
+			CampaignExpiryEnabled:              getEnvBool("CAMPAIGN_EXPIRY_ENABLED", true),
+			CampaignExpiryInterval:             getEnvDuration("CAMPAIGN_EXPIRY_INTERVAL", 10*time.Minute),
+
+			RunInAPI:            getEnvBool("SCHEDULER_RUN_IN_API", true),
+			LeaderRetryInterval: getEnvDuration("SCHEDULER_LEADER_RETRY_INTERVAL", 15*time.Second),
+		},
+		JobQueue: JobQueueConfig{
+			Enabled:      getEnvBool("JOB_QUEUE_ENABLED", true),
+			PollInterval: getEnvDuration("JOB_QUEUE_POLL_INTERVAL", 5*time.Second),
+			Workers:      getEnvInt("JOB_QUEUE_WORKERS", 4),
+			MaxAttempts:  getEnvInt("JOB_QUEUE_MAX_ATTEMPTS", 5),
+			BackoffBase:  getEnvDuration("JOB_QUEUE_BACKOFF_BASE", 30*time.Second),
+			BackoffMax:   getEnvDuration("JOB_QUEUE_BACKOFF_MAX", time.Hour),
+			Retention:    getEnvDuration("JOB_QUEUE_RETENTION", 7*24*time.Hour),
+		},
+		OutboundHTTP: OutboundHTTPConfig{
+			MaxIdleConns:        getEnvInt("OUTBOUND_HTTP_MAX_IDLE_CONNS", 100),
+			MaxIdleConnsPerHost: getEnvInt("OUTBOUND_HTTP_MAX_IDLE_CONNS_PER_HOST", 10),
+			IdleConnTimeout:     getEnvDuration("OUTBOUND_HTTP_IDLE_CONN_TIMEOUT", 90*time.Second),
+			HostTimeouts:        getEnvDurationMap("OUTBOUND_HTTP_HOST_TIMEOUTS"),
+			RetryMax:            getEnvInt("OUTBOUND_HTTP_RETRY_MAX", 2),
+			RetryBackoff:        getEnvDuration("OUTBOUND_HTTP_RETRY_BACKOFF", 200*time.Millisecond),
+			BreakerThreshold:    getEnvInt("OUTBOUND_HTTP_BREAKER_THRESHOLD", 5),
+			BreakerCooldown:     getEnvDuration("OUTBOUND_HTTP_BREAKER_COOLDOWN", 30*time.Second),
 		},
 		Crypto: CryptoConfig{
-			DefaultPlatform: getEnvString("CRYPTO_DEFAULT_PLATFORM", "oxapay"),
-			SupportedCoins:  getEnvStringSlice("CRYPTO_SUPPORTED_COINS", []string{"ETH", "DOGE", "XRP", "BNB"}),
+			DefaultPlatform:     getEnvString("CRYPTO_DEFAULT_PLATFORM", "oxapay"),
+			SupportedCoins:      getEnvStringSlice("CRYPTO_SUPPORTED_COINS", []string{"ETH", "DOGE", "XRP", "BNB"}),
+			PaymentToleranceBPS: getEnvInt("CRYPTO_PAYMENT_TOLERANCE_BPS", 100),
+			WebhookMaxAge:       getEnvDuration("CRYPTO_WEBHOOK_MAX_AGE", 24*time.Hour),
 			Oxapay: OxapayConfig{
 				BaseURL: getEnvString("OXA_BASE_URL", "https://api.oxapay.com"),
 				APIKey:  getEnvString("OXA_API_KEY", ""),
 				Timeout: getEnvDuration("OXA_TIMEOUT", 10*time.Second),
 			},
+			NowPayments: NowPaymentsConfig{
+				BaseURL:   getEnvString("NOWPAYMENTS_BASE_URL", "https://api.nowpayments.io/v1"),
+				APIKey:    getEnvString("NOWPAYMENTS_API_KEY", ""),
+				IPNSecret: getEnvString("NOWPAYMENTS_IPN_SECRET", ""),
+				Timeout:   getEnvDuration("NOWPAYMENTS_TIMEOUT", 10*time.Second),
+			},
+			Rates: ExchangeRateConfig{
+				Sources:          getEnvStringSlice("CRYPTO_RATE_SOURCES", []string{"wallex", "nobitex"}),
+				MinSources:       getEnvInt("CRYPTO_RATE_MIN_SOURCES", 2),
+				CacheTTL:         getEnvDuration("CRYPTO_RATE_CACHE_TTL", time.Minute),
+				MaxDivergenceBPS: getEnvInt("CRYPTO_RATE_MAX_DIVERGENCE_BPS", 300),
+				Timeout:          getEnvDuration("CRYPTO_RATE_TIMEOUT", 5*time.Second),
+			},
+		},
+		I18n: I18nConfig{
+			DefaultLocale: getEnvString("I18N_DEFAULT_LOCALE", "en"),
+			AdminLocale:   getEnvString("I18N_ADMIN_LOCALE", "fa"),
+		},
+		Health: HealthConfig{
+			CheckTimeout:     getEnvDuration("HEALTH_CHECK_TIMEOUT", 2*time.Second),
+			ProviderChecks:   getEnvBool("HEALTH_PROVIDER_CHECKS", true),
+			ProviderCacheTTL: getEnvDuration("HEALTH_PROVIDER_CACHE_TTL", 30*time.Second),
 		},
-		Message: MessageConfig{
-			SignupVerificationCodeTemplate:        getEnvString("MESSAGE_SIGNUP_VERIFICATION_CODE_TEMPLATE", "Your verification code is %s"),
-			SigninVerificationCodeTemplate:        getEnvString("MESSAGE_SIGNIN_VERIFICATION_CODE_TEMPLATE", "Your verification code is %s"),
-			OTPResendVerificationCodeTemplate:     getEnvString("MESSAGE_OTP_RESEND_VERIFICATION_CODE_TEMPLATE", "Your new verification code is: %s. Valid for %v minutes."),
-			PasswordResetVerificationCodeTemplate: getEnvString("MESSAGE_PASSWORD_RESET_VERIFICATION_CODE_TEMPLATE", "Your password reset code is: %s. This code will expire in %v minutes."),
-			CampaignRejectedTemplate:              getEnvString("MESSAGE_CAMPAIGN_REJECTED_TEMPLATE", "Your campaign has been rejected."),
-			DepositReceiptSubmittedTemplate:       getEnvString("MESSAGE_DEPOSIT_RECEIPT_SUBMITTED_TEMPLATE", "سلام شارژی در سامانه جاذبه انجام شده است. لطفا از پنل ادمین فاکتور مربوطه را صادر و آپلود نمایید"),
-			InvoiceIssueRequestTemplate:           getEnvString("MESSAGE_INVOICE_ISSUE_REQUEST_TEMPLATE", "درخواست صدور فاکتور ثبت شد. مشتری: %s، شرکت: %s"),
+		WalletTransfer: WalletTransferConfig{
+			Enabled:    getEnvBool("WALLET_TRANSFER_ENABLED", false),
+			MinAmount:  getEnvUint64("WALLET_TRANSFER_MIN_AMOUNT", 10000),
+			MaxAmount:  getEnvUint64("WALLET_TRANSFER_MAX_AMOUNT", 50000000),
+			DailyLimit: getEnvUint64("WALLET_TRANSFER_DAILY_LIMIT", 100000000),
+		},
+		Secrets: SecretsConfig{
+			Provider: getEnvString("SECRETS_PROVIDER", SecretsProviderEnv),
+			CacheTTL: getEnvDuration("SECRETS_CACHE_TTL", 5*time.Minute),
+			Dir:      getEnvString("SECRETS_DIR", "/run/secrets"),
+			Vault: VaultConfig{
+				Address:             getEnvString("VAULT_ADDR", ""),
+				Namespace:           getEnvString("VAULT_NAMESPACE", ""),
+				AuthMethod:          getEnvString("VAULT_AUTH_METHOD", VaultAuthToken),
+				AuthMount:           getEnvString("VAULT_AUTH_MOUNT", ""),
+				Token:               getEnvString("VAULT_TOKEN", ""),
+				RoleID:              getEnvString("VAULT_ROLE_ID", ""),
+				SecretID:            getEnvString("VAULT_SECRET_ID", ""),
+				Role:                getEnvString("VAULT_ROLE", ""),
+				KubernetesTokenPath: getEnvString("VAULT_K8S_TOKEN_PATH", "/var/run/secrets/kubernetes.io/serviceaccount/token"),
+				KVMount:             getEnvString("VAULT_KV_MOUNT", "secret"),
+				SecretPath:          getEnvString("VAULT_SECRET_PATH", ""),
+				Timeout:             getEnvDuration("VAULT_TIMEOUT", 10*time.Second),
+			},
 		},
 		SmartTagEvaluation: SmartTagEvaluationConfig{
 			Enabled: smartTagEvaluationEnabled,
@@ -758,17 +1493,61 @@ func LoadProductionConfig() (*ProductionConfig, error) {
 				RequireExactTagIDs:   getEnvBool("SMART_TAG_EVALUATION_VALIDATION_REQUIRE_EXACT_TAG_IDS", true),
 			},
 		},
+		Mocks: MocksConfig{
+			Atipay:   getEnvProviderMock("MOCK_ATIPAY"),
+			Oxapay:   getEnvProviderMock("MOCK_OXAPAY"),
+			PayamSMS: getEnvProviderMock("MOCK_PAYAM_SMS"),
+			Bot:      getEnvProviderMock("MOCK_BOT"),
+		},
 		IRHTTPSProxy: getEnvString("IR_HTTPS_PROXY", ""),
 	}
 
+	// Replace credentials with those of the secret provider, if any
+	if err := loadSecrets(cfg); err != nil {
+		return nil, err
+	}
+	applyProviderMocks(cfg)
+
 	// Validate the loaded configuration
-	if err := ValidateProductionConfig(cfg); err != nil {
+	if err := validateLoadedConfig(cfg); err != nil {
 		return nil, err
 	}
 
 	return cfg, nil
 }
 
+// applyProviderMocks fills the credentials of mocked providers that are left
+// unset, since their fakes accept any, and sends bot calls to the REST API
+// where the mock answers them
+func applyProviderMocks(cfg *ProductionConfig) {
+	const placeholder = "mock"
+	fill := func(value *string) {
+		if strings.TrimSpace(*value) == "" {
+			*value = placeholder
+		}
+	}
+	if cfg.Mocks.Atipay.Enabled {
+		fill(&cfg.Atipay.APIKey)
+		fill(&cfg.Atipay.Terminal)
+	}
+	if cfg.Mocks.Oxapay.Enabled {
+		fill(&cfg.Crypto.Oxapay.APIKey)
+	}
+	if cfg.Mocks.PayamSMS.Enabled {
+		fill(&cfg.PayamSMS.Username)
+		fill(&cfg.PayamSMS.Password)
+	}
+	if cfg.Mocks.Bot.Enabled {
+		fill(&cfg.Bot.Username)
+		fill(&cfg.Bot.Password)
+		cfg.Bot.GRPCAddress = ""
+	}
+}
+
+// envFileKeys records the variables set from the .env file, so a reload
+// picks up their new values while the process environment still wins
+var envFileKeys = map[string]bool{}
+
 // loadEnvFile loads environment variables from .env file if it exists
 func loadEnvFile() error {
 	envFile := ".env"
@@ -789,31 +1568,15 @@ func loadEnvFile() error {
 	// Read file line by line
 	scanner := bufio.NewScanner(file)
 	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-
-		// Skip empty lines and comments
-		if line == "" || strings.HasPrefix(line, "#") {
+		key, value, ok := parseEnvLine(scanner.Text())
+		if !ok {
 			continue
 		}
 
-		// Parse key=value pairs
-		if strings.Contains(line, "=") {
-			parts := strings.SplitN(line, "=", 2)
-			if len(parts) == 2 {
-				key := strings.TrimSpace(parts[0])
-				value := strings.TrimSpace(parts[1])
-
-				// Remove quotes if present
-				if (strings.HasPrefix(value, `"`) && strings.HasSuffix(value, `"`)) ||
-					(strings.HasPrefix(value, `'`) && strings.HasSuffix(value, `'`)) {
-					value = value[1 : len(value)-1]
-				}
-
-				// Set environment variable if not already set
-				if os.Getenv(key) == "" {
-					os.Setenv(key, value)
-				}
-			}
+		// Set environment variable if not already set
+		if os.Getenv(key) == "" || envFileKeys[key] {
+			os.Setenv(key, value)
+			envFileKeys[key] = true
 		}
 	}
 
@@ -824,6 +1587,28 @@ func loadEnvFile() error {
 	return nil
 }
 
+// parseEnvLine parses a KEY=value line, skipping empty lines and comments
+func parseEnvLine(line string) (string, string, bool) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return "", "", false
+	}
+
+	parts := strings.SplitN(line, "=", 2)
+	if len(parts) != 2 {
+		return "", "", false
+	}
+	key := strings.TrimSpace(parts[0])
+	value := strings.TrimSpace(parts[1])
+
+	// Remove quotes if present
+	if len(value) >= 2 && ((strings.HasPrefix(value, `"`) && strings.HasSuffix(value, `"`)) ||
+		(strings.HasPrefix(value, `'`) && strings.HasSuffix(value, `'`))) {
+		value = value[1 : len(value)-1]
+	}
+	return key, value, true
+}
+
 // Helper functions for environment variable parsing
 func getEnvString(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
@@ -857,6 +1642,17 @@ func getEnvInt(key string, defaultValue int) int {
 		if parsed, err := strconv.Atoi(value); err == nil {
 			return parsed
 		}
+		reportMalformedEnv(key, value, "integer")
+	}
+	return defaultValue
+}
+
+func getEnvUint64(key string, defaultValue uint64) uint64 {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.ParseUint(value, 10, 64); err == nil {
+			return parsed
+		}
+		reportMalformedEnv(key, value, "unsigned integer")
 	}
 	return defaultValue
 }
@@ -866,6 +1662,7 @@ func getEnvBool(key string, defaultValue bool) bool {
 		if parsed, err := strconv.ParseBool(value); err == nil {
 			return parsed
 		}
+		reportMalformedEnv(key, value, "boolean")
 	}
 	return defaultValue
 }
@@ -875,19 +1672,53 @@ func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
 		if parsed, err := time.ParseDuration(value); err == nil {
 			return parsed
 		}
+		reportMalformedEnv(key, value, "duration")
 	}
 	return defaultValue
 }
 
+// getEnvTime parses an RFC 3339 timestamp or a YYYY-MM-DD date (midnight UTC);
+// unset yields the zero time
+func getEnvTime(key string) time.Time {
+	value := strings.TrimSpace(os.Getenv(key))
+	if value == "" {
+		return time.Time{}
+	}
+	for _, layout := range []string{time.RFC3339, time.DateOnly} {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return parsed.UTC()
+		}
+	}
+	reportMalformedEnv(key, value, "RFC 3339 timestamp or date")
+	return time.Time{}
+}
+
 func getOptionalEnvFloat64(key string) *float64 {
 	if value := os.Getenv(key); value != "" {
 		if parsed, err := strconv.ParseFloat(value, 64); err == nil {
 			return &parsed
 		}
+		reportMalformedEnv(key, value, "number")
 	}
 	return nil
 }
 
+func getEnvFloat64(key string, defaultValue float64) float64 {
+	if parsed := getOptionalEnvFloat64(key); parsed != nil {
+		return *parsed
+	}
+	return defaultValue
+}
+
+// getEnvProviderMock reads <prefix>, <prefix>_LATENCY and <prefix>_FAILURE_RATE
+func getEnvProviderMock(prefix string) ProviderMockConfig {
+	return ProviderMockConfig{
+		Enabled:     getEnvBool(prefix, false),
+		Latency:     getEnvDuration(prefix+"_LATENCY", 0),
+		FailureRate: getEnvFloat64(prefix+"_FAILURE_RATE", 0),
+	}
+}
+
 func getEnvStringSlice(key string, defaultValue []string) []string {
 	if value := os.Getenv(key); value != "" {
 		// Use standard library strings.Split and strings.TrimSpace
@@ -904,6 +1735,20 @@ func getEnvStringSlice(key string, defaultValue []string) []string {
 	return defaultValue
 }
 
+// getEnvDurationMap parses key:duration pairs, e.g. api.example.com:10s
+func getEnvDurationMap(key string) map[string]time.Duration {
+	result := make(map[string]time.Duration)
+	for mapKey, raw := range getEnvStringMap(key, nil) {
+		parsed, err := time.ParseDuration(raw)
+		if err != nil {
+			reportMalformedEnv(key, mapKey+":"+raw, "host:duration pair")
+			continue
+		}
+		result[mapKey] = parsed
+	}
+	return result
+}
+
 func getEnvStringMap(key string, defaultValue map[string]string) map[string]string {
 	value := strings.TrimSpace(os.Getenv(key))
 	if value == "" {
@@ -919,6 +1764,7 @@ func getEnvStringMap(key string, defaultValue map[string]string) map[string]stri
 
 		parts := strings.SplitN(item, ":", 2)
 		if len(parts) != 2 {
+			reportMalformedEnv(key, item, "key:value pair")
 			continue
 		}
 
@@ -935,222 +1781,3 @@ func getEnvStringMap(key string, defaultValue map[string]string) map[string]stri
 	}
 	return result
 }
-
-// ValidateProductionConfig validates the production configuration
-func ValidateProductionConfig(cfg *ProductionConfig) error {
-	var errors []string
-
-	// Validate database configuration
-	if cfg.Database.Host == "" {
-		errors = append(errors, "DB_HOST is required")
-	}
-	if cfg.Database.Port <= 0 || cfg.Database.Port > 65535 {
-		errors = append(errors, "DB_PORT must be between 1 and 65535")
-	}
-	if cfg.Database.Name == "" {
-		errors = append(errors, "DB_NAME is required")
-	}
-	if cfg.Database.User == "" {
-		errors = append(errors, "DB_USER is required")
-	}
-	if cfg.Database.Password == "" {
-		errors = append(errors, "DB_PASSWORD is required")
-	}
-
-	// Validate JWT configuration
-	if cfg.JWT.SecretKey == "" {
-		errors = append(errors, "JWT_SECRET_KEY is required")
-	}
-	if len(cfg.JWT.SecretKey) < 32 {
-		errors = append(errors, "JWT_SECRET_KEY must be at least 32 characters long")
-	}
-	if cfg.JWT.AccessTokenTTL <= 0 {
-		errors = append(errors, "JWT_ACCESS_TOKEN_TTL must be positive")
-	}
-	if cfg.JWT.RefreshTokenTTL <= 0 {
-		errors = append(errors, "JWT_REFRESH_TOKEN_TTL must be positive")
-	}
-	if cfg.JWT.Issuer == "" {
-		errors = append(errors, "JWT_ISSUER is required")
-	}
-	if cfg.JWT.Audience == "" {
-		errors = append(errors, "JWT_AUDIENCE is required")
-	}
-
-	// Validate server configuration
-	if cfg.Server.Port <= 0 || cfg.Server.Port > 65535 {
-		errors = append(errors, "SERVER_PORT must be between 1 and 65535")
-	}
-	if cfg.Server.ReadTimeout <= 0 {
-		errors = append(errors, "SERVER_READ_TIMEOUT must be positive")
-	}
-	if cfg.Server.WriteTimeout <= 0 {
-		errors = append(errors, "SERVER_WRITE_TIMEOUT must be positive")
-	}
-	if cfg.Server.IdleTimeout <= 0 {
-		errors = append(errors, "SERVER_IDLE_TIMEOUT must be positive")
-	}
-
-	// Validate security configuration
-	if cfg.Security.PasswordMinLength < 6 {
-		errors = append(errors, "PASSWORD_MIN_LENGTH must be at least 6")
-	}
-	if cfg.Security.BcryptCost < 10 || cfg.Security.BcryptCost > 14 {
-		errors = append(errors, "BCRYPT_COST must be between 10 and 14")
-	}
-
-	// Validate SMS configuration if enabled
-	if cfg.SMS.ProviderDomain == "payamsms" {
-		if cfg.SMS.SourceNumber == "" {
-			errors = append(errors, "SMS_SOURCE_NUMBER is required for PayamSMS provider")
-		}
-		if cfg.PayamSMS.Username == "" {
-			errors = append(errors, "PAYAM_SMS_USERNAME is required for PayamSMS provider")
-		}
-		if cfg.PayamSMS.Password == "" {
-			errors = append(errors, "PAYAM_SMS_PASSWORD is required for PayamSMS provider")
-		}
-	} else if cfg.SMS.ProviderDomain != "mock" {
-		if cfg.SMS.APIKey == "" {
-			errors = append(errors, "SMS_API_KEY is required for SMS provider")
-		}
-		if cfg.SMS.SourceNumber == "" {
-			errors = append(errors, "SMS_SOURCE_NUMBER is required for SMS provider")
-		}
-	}
-
-	// Validate email configuration if enabled
-	if cfg.Email.Host != "" {
-		if cfg.Email.Username == "" {
-			errors = append(errors, "EMAIL_USERNAME is required for email configuration")
-		}
-		if cfg.Email.Password == "" {
-			errors = append(errors, "EMAIL_PASSWORD is required for email configuration")
-		}
-		if cfg.Email.FromEmail == "" {
-			errors = append(errors, "EMAIL_FROM_EMAIL is required for email configuration")
-		}
-	}
-
-	// Validate TLS configuration if enabled
-	if cfg.Security.TLSEnabled {
-		if cfg.Security.TLSCertFile == "" {
-			errors = append(errors, "TLS_CERT_FILE is required when TLS is enabled")
-		}
-		if cfg.Security.TLSKeyFile == "" {
-			errors = append(errors, "TLS_KEY_FILE is required when TLS is enabled")
-		}
-	}
-
-	// Validate logging configuration
-	if cfg.Logging.Level != "" {
-		validLevels := []string{"debug", "info", "warn", "error"}
-		valid := false
-		for _, level := range validLevels {
-			if cfg.Logging.Level == level {
-				valid = true
-				break
-			}
-		}
-		if !valid {
-			errors = append(errors, fmt.Sprintf("LOG_LEVEL must be one of: %v", validLevels))
-		}
-	}
-
-	// Validate cache configuration if enabled
-	if cfg.Cache.Enabled {
-		if cfg.Cache.Provider == "redis" && cfg.Cache.RedisURL == "" {
-			errors = append(errors, "CACHE_REDIS_URL is required when cache is enabled with redis provider")
-		}
-	}
-
-	if cfg.SmartTagEvaluation.Enabled {
-		if cfg.SmartTagEvaluation.OpenAI.Model == "" {
-			errors = append(errors, "SMART_TAG_EVALUATION_OPENAI_MODEL is required when smart tag evaluation is enabled")
-		}
-		if cfg.SmartTagEvaluation.OpenAI.Timeout <= 0 {
-			errors = append(errors, "SMART_TAG_EVALUATION_OPENAI_TIMEOUT must be positive")
-		}
-		if cfg.SmartTagEvaluation.OpenAI.MaxRetries < 0 {
-			errors = append(errors, "SMART_TAG_EVALUATION_OPENAI_MAX_RETRIES must be zero or greater")
-		}
-		if cfg.SmartTagEvaluation.Batching.TagBatchSize <= 0 {
-			errors = append(errors, "SMART_TAG_EVALUATION_BATCHING_TAG_BATCH_SIZE must be positive")
-		}
-		if cfg.SmartTagEvaluation.Scheduler.PollInterval <= 0 {
-			errors = append(errors, "SMART_TAG_EVALUATION_SCHEDULER_POLL_INTERVAL must be positive")
-		}
-		if cfg.SmartTagEvaluation.Scheduler.MaxParallelRuns <= 0 {
-			errors = append(errors, "SMART_TAG_EVALUATION_SCHEDULER_MAX_PARALLEL_RUNS must be positive")
-		}
-	}
-
-	// System config is not optional and all must be parsed
-	if cfg.System.SystemUserUUID == "" {
-		errors = append(errors, "SYSTEM_USER_UUID is required")
-	}
-	if cfg.System.TaxUserUUID == "" {
-		errors = append(errors, "TAX_USER_UUID is required")
-	}
-	if cfg.System.SystemUserMobile == "" {
-		errors = append(errors, "SYSTEM_USER_MOBILE is required")
-	}
-	if cfg.System.TaxUserMobile == "" {
-		errors = append(errors, "TAX_USER_MOBILE is required")
-	}
-	if cfg.System.SystemUserEmail == "" {
-		errors = append(errors, "SYSTEM_USER_EMAIL is required")
-	}
-	if cfg.System.TaxUserEmail == "" {
-		errors = append(errors, "TAX_USER_EMAIL is required")
-	}
-	if cfg.System.SystemWalletUUID == "" {
-		errors = append(errors, "SYSTEM_WALLET_UUID is required")
-	}
-	if cfg.System.TaxWalletUUID == "" {
-		errors = append(errors, "TAX_WALLET_UUID is required")
-	}
-	_, err := uuid.Parse(cfg.System.SystemUserUUID)
-	if err != nil {
-		errors = append(errors, "SYSTEM_USER_UUID is invalid")
-	}
-	_, err = uuid.Parse(cfg.System.TaxUserUUID)
-	if err != nil {
-		errors = append(errors, "TAX_USER_UUID is invalid")
-	}
-	_, err = uuid.Parse(cfg.System.SystemWalletUUID)
-	if err != nil {
-		errors = append(errors, "SYSTEM_WALLET_UUID is invalid")
-	}
-	_, err = uuid.Parse(cfg.System.TaxWalletUUID)
-	if err != nil {
-		errors = append(errors, "TAX_WALLET_UUID is invalid")
-	}
-	if cfg.System.SystemShebaNumber == "" {
-		errors = append(errors, "SYSTEM_SHEBA_NUMBER is required")
-	}
-	_, err = utils.ValidateShebaNumber(&cfg.System.SystemShebaNumber)
-	if err != nil {
-		errors = append(errors, "SYSTEM_SHEBA_NUMBER is invalid")
-	}
-
-	// Crypto config: only oxapay is supported.
-	if cfg.Crypto.DefaultPlatform != "oxapay" {
-		errors = append(errors, "CRYPTO_DEFAULT_PLATFORM must be oxapay")
-	}
-	if cfg.Crypto.DefaultPlatform == "oxapay" {
-		if cfg.Crypto.Oxapay.BaseURL == "" {
-			errors = append(errors, "OXA_BASE_URL is required when oxapay is default platform")
-		}
-		if cfg.Crypto.Oxapay.APIKey == "" {
-			errors = append(errors, "OXA_API_KEY is required when oxapay is default platform")
-		}
-	}
-
-	// Return validation errors if any
-	if len(errors) > 0 {
-		return fmt.Errorf("configuration validation failed: %s", strings.Join(errors, "; "))
-	}
-
-	return nil
-}
@@ -0,0 +1,246 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RuntimeSettings are the settings that can be changed without a restart, by
+// sending the process SIGHUP or through the admin API. The rest of
+// ProductionConfig is structural and only takes effect on restart.
+type RuntimeSettings struct {
+	// Rate limits, see SecurityConfig
+	AuthRateLimit      int
+	OTPRateLimit       int
+	PaymentIPRateLimit int
+	PaymentRateLimit   int
+	RateLimitWindow    time.Duration
+	OTPCooldown        time.Duration
+	OTPDailyLimit      int
+
+	// Campaign schedulers
+	CampaignExecutionInterval time.Duration
+	MessageSendDelay          time.Duration
+
+	// Provider toggles
+	HealthProviderChecks bool
+
+	// Feature flags
+	SentryCapture4xx bool
+	SentryCapture5xx bool
+}
+
+// RuntimeSettings returns the reloadable part of the configuration
+func (c *ProductionConfig) RuntimeSettings() RuntimeSettings {
+	return RuntimeSettings{
+		AuthRateLimit:             c.Security.AuthRateLimit,
+		OTPRateLimit:              c.Security.OTPRateLimit,
+		PaymentIPRateLimit:        c.Security.PaymentIPRateLimit,
+		PaymentRateLimit:          c.Security.PaymentRateLimit,
+		RateLimitWindow:           c.Security.RateLimitWindow,
+		OTPCooldown:               c.Security.OTPCooldown,
+		OTPDailyLimit:             c.Security.OTPDailyLimit,
+		CampaignExecutionInterval: c.Scheduler.CampaignExecutionInterval,
+		MessageSendDelay:          c.Scheduler.MessageSendDelay,
+		HealthProviderChecks:      c.Health.ProviderChecks,
+		SentryCapture4xx:          c.Sentry.Capture4xx,
+		SentryCapture5xx:          c.Sentry.Capture5xx,
+	}
+}
+
+// Security returns sec with its rate limits replaced by those of s
+func (s RuntimeSettings) Security(sec SecurityConfig) SecurityConfig {
+	sec.AuthRateLimit = s.AuthRateLimit
+	sec.OTPRateLimit = s.OTPRateLimit
+	sec.PaymentIPRateLimit = s.PaymentIPRateLimit
+	sec.PaymentRateLimit = s.PaymentRateLimit
+	sec.RateLimitWindow = s.RateLimitWindow
+	sec.OTPCooldown = s.OTPCooldown
+	sec.OTPDailyLimit = s.OTPDailyLimit
+	return sec
+}
+
+// runtimeSettings maps each reloadable environment variable to its value
+var runtimeSettings = []struct {
+	key   string
+	value func(RuntimeSettings) string
+}{
+	{"AUTH_RATE_LIMIT", func(s RuntimeSettings) string { return strconv.Itoa(s.AuthRateLimit) }},
+	{"OTP_RATE_LIMIT", func(s RuntimeSettings) string { return strconv.Itoa(s.OTPRateLimit) }},
+	{"PAYMENT_IP_RATE_LIMIT", func(s RuntimeSettings) string { return strconv.Itoa(s.PaymentIPRateLimit) }},
+	{"PAYMENT_RATE_LIMIT", func(s RuntimeSettings) string { return strconv.Itoa(s.PaymentRateLimit) }},
+	{"RATE_LIMIT_WINDOW", func(s RuntimeSettings) string { return s.RateLimitWindow.String() }},
+	{"OTP_COOLDOWN", func(s RuntimeSettings) string { return s.OTPCooldown.String() }},
+	{"OTP_DAILY_LIMIT", func(s RuntimeSettings) string { return strconv.Itoa(s.OTPDailyLimit) }},
+	{"CAMPAIGN_EXECUTION_INTERVAL", func(s RuntimeSettings) string { return s.CampaignExecutionInterval.String() }},
+	{"CAMPAIGN_MESSAGE_SEND_DELAY", func(s RuntimeSettings) string { return s.MessageSendDelay.String() }},
+	{"HEALTH_PROVIDER_CHECKS", func(s RuntimeSettings) string { return strconv.FormatBool(s.HealthProviderChecks) }},
+	{"SENTRY_CAPTURE_4XX", func(s RuntimeSettings) string { return strconv.FormatBool(s.SentryCapture4xx) }},
+	{"SENTRY_CAPTURE_5XX", func(s RuntimeSettings) string { return strconv.FormatBool(s.SentryCapture5xx) }},
+}
+
+// SettingValue is the current value of a reloadable environment variable
+type SettingValue struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// SettingChange records a reloadable environment variable that a reload changed
+type SettingChange struct {
+	Key string `json:"key"`
+	Old string `json:"old"`
+	New string `json:"new"`
+}
+
+// RuntimeSettingKeys returns the environment variables that can be reloaded
+func RuntimeSettingKeys() []string {
+	keys := make([]string, 0, len(runtimeSettings))
+	for _, setting := range runtimeSettings {
+		keys = append(keys, setting.key)
+	}
+	return keys
+}
+
+// Values lists the settings by environment variable
+func (s RuntimeSettings) Values() []SettingValue {
+	values := make([]SettingValue, 0, len(runtimeSettings))
+	for _, setting := range runtimeSettings {
+		values = append(values, SettingValue{Key: setting.key, Value: setting.value(s)})
+	}
+	return values
+}
+
+// Changes lists the settings that differ between s and next
+func (s RuntimeSettings) Changes(next RuntimeSettings) []SettingChange {
+	var changes []SettingChange
+	for _, setting := range runtimeSettings {
+		if old, updated := setting.value(s), setting.value(next); old != updated {
+			changes = append(changes, SettingChange{Key: setting.key, Old: old, New: updated})
+		}
+	}
+	return changes
+}
+
+// Runtime holds the live RuntimeSettings and hands them to its subscribers
+// whenever a reload changes them. It is safe for concurrent use.
+type Runtime struct {
+	mu          sync.RWMutex
+	current     RuntimeSettings
+	load        func() (*ProductionConfig, error)
+	subscribers []func(RuntimeSettings)
+}
+
+// NewRuntime creates a Runtime starting from cfg that reloads with
+// LoadProductionConfig
+func NewRuntime(cfg *ProductionConfig) *Runtime {
+	return &Runtime{current: cfg.RuntimeSettings(), load: LoadProductionConfig}
+}
+
+// Settings returns the settings in effect
+func (r *Runtime) Settings() RuntimeSettings {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.current
+}
+
+// Subscribe registers apply to be called with the new settings after every
+// reload that changes them
+func (r *Runtime) Subscribe(apply func(RuntimeSettings)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.subscribers = append(r.subscribers, apply)
+}
+
+// Reload reads the configuration again and applies its reloadable settings.
+// The whole configuration is validated first, so an invalid value leaves the
+// settings in effect untouched. Changes to structural settings are ignored
+// until the next restart.
+func (r *Runtime) Reload() ([]SettingChange, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cfg, err := r.load()
+	if err != nil {
+		return nil, err
+	}
+	next := cfg.RuntimeSettings()
+	changes := r.current.Changes(next)
+	if len(changes) == 0 {
+		return nil, nil
+	}
+	r.current = next
+	for _, apply := range r.subscribers {
+		apply(next)
+	}
+	return changes, nil
+}
+
+// runtimeFileOriginals holds the environment values the runtime file
+// replaced, nil for unset variables, so removing a line restores them
+var runtimeFileOriginals = map[string]*string{}
+
+// loadRuntimeFile applies the file named by RUNTIME_CONFIG_FILE over the
+// environment. Unlike .env it overrides variables that are already set, so
+// the reloadable settings of a container can be changed in place; it may
+// only contain those settings. A missing file is not an error.
+func loadRuntimeFile() error {
+	values := map[string]string{}
+	var unknown []FieldError
+	if path := strings.TrimSpace(os.Getenv("RUNTIME_CONFIG_FILE")); path != "" {
+		file, err := os.Open(path)
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to open runtime config file: %w", err)
+		}
+		if err == nil {
+			defer file.Close()
+			allowed := RuntimeSettingKeys()
+			scanner := bufio.NewScanner(file)
+			for scanner.Scan() {
+				key, value, ok := parseEnvLine(scanner.Text())
+				if !ok {
+					continue
+				}
+				if !slices.Contains(allowed, key) {
+					unknown = append(unknown, FieldError{Section: "runtime", Key: key, Message: "is not a reloadable setting and can not be set in RUNTIME_CONFIG_FILE"})
+					continue
+				}
+				values[key] = value
+			}
+			if err := scanner.Err(); err != nil {
+				return fmt.Errorf("error reading runtime config file: %w", err)
+			}
+		}
+	}
+	if len(unknown) > 0 {
+		return &ValidationError{Problems: unknown}
+	}
+
+	for key, original := range runtimeFileOriginals {
+		if _, ok := values[key]; ok {
+			continue
+		}
+		if original == nil {
+			os.Unsetenv(key)
+		} else {
+			os.Setenv(key, *original)
+		}
+		delete(runtimeFileOriginals, key)
+	}
+	for key, value := range values {
+		if _, ok := runtimeFileOriginals[key]; !ok {
+			if original, set := os.LookupEnv(key); set {
+				runtimeFileOriginals[key] = &original
+			} else {
+				runtimeFileOriginals[key] = nil
+			}
+		}
+		os.Setenv(key, value)
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRuntimeSettingsChanges(t *testing.T) {
+	old := RuntimeSettings{AuthRateLimit: 10, RateLimitWindow: time.Minute, SentryCapture5xx: true}
+	next := old
+	next.AuthRateLimit = 20
+	next.SentryCapture5xx = false
+
+	changes := old.Changes(next)
+	want := []SettingChange{
+		{Key: "AUTH_RATE_LIMIT", Old: "10", New: "20"},
+		{Key: "SENTRY_CAPTURE_5XX", Old: "true", New: "false"},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("got %d changes, want %d: %+v", len(changes), len(want), changes)
+	}
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Errorf("change %d = %+v, want %+v", i, changes[i], want[i])
+		}
+	}
+	if changes := old.Changes(old); len(changes) != 0 {
+		t.Errorf("expected no changes, got %+v", changes)
+	}
+	if got := len(old.Values()); got != len(RuntimeSettingKeys()) {
+		t.Errorf("Values returned %d settings, want %d", got, len(RuntimeSettingKeys()))
+	}
+}
+
+func TestRuntimeReload(t *testing.T) {
+	cfg := validConfig()
+	cfg.Security.AuthRateLimit = 10
+	runtime := NewRuntime(cfg)
+
+	var applied []RuntimeSettings
+	runtime.Subscribe(func(s RuntimeSettings) { applied = append(applied, s) })
+
+	next := *cfg
+	next.Security.AuthRateLimit = 30
+	next.Scheduler.CampaignExecutionInterval = 5 * time.Minute
+	runtime.load = func() (*ProductionConfig, error) { return &next, nil }
+
+	changes, err := runtime.Reload()
+	if err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	if len(changes) != 2 {
+		t.Fatalf("got %d changes, want 2: %+v", len(changes), changes)
+	}
+	if len(applied) != 1 || applied[0].AuthRateLimit != 30 {
+		t.Fatalf("subscriber got %+v, want one call with the new settings", applied)
+	}
+	if runtime.Settings().CampaignExecutionInterval != 5*time.Minute {
+		t.Errorf("settings not updated: %+v", runtime.Settings())
+	}
+
+	// Nothing changed: subscribers are not called again
+	if changes, err := runtime.Reload(); err != nil || len(changes) != 0 {
+		t.Fatalf("second Reload = %+v, %v; want no changes", changes, err)
+	}
+	if len(applied) != 1 {
+		t.Errorf("subscriber called %d times, want 1", len(applied))
+	}
+}
+
+func TestRuntimeReloadKeepsSettingsOnError(t *testing.T) {
+	cfg := validConfig()
+	cfg.Security.AuthRateLimit = 10
+	runtime := NewRuntime(cfg)
+	called := false
+	runtime.Subscribe(func(RuntimeSettings) { called = true })
+
+	invalid := &ValidationError{Problems: []FieldError{{Section: "security", Key: "AUTH_RATE_LIMIT", Message: "must not be negative"}}}
+	runtime.load = func() (*ProductionConfig, error) { return nil, invalid }
+
+	if _, err := runtime.Reload(); !errors.Is(err, invalid) {
+		t.Fatalf("Reload error = %v, want the validation error", err)
+	}
+	if called {
+		t.Error("subscriber called after a failed reload")
+	}
+	if runtime.Settings().AuthRateLimit != 10 {
+		t.Errorf("settings changed after a failed reload: %+v", runtime.Settings())
+	}
+}
+
+func TestLoadRuntimeFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "runtime.env")
+	t.Setenv("RUNTIME_CONFIG_FILE", path)
+	t.Setenv("AUTH_RATE_LIMIT", "10")
+	t.Setenv("OTP_DAILY_LIMIT", "")
+	os.Unsetenv("OTP_DAILY_LIMIT")
+	t.Cleanup(func() { runtimeFileOriginals = map[string]*string{} })
+
+	// A missing file leaves the environment alone
+	if err := loadRuntimeFile(); err != nil {
+		t.Fatalf("missing file: %v", err)
+	}
+	if got := os.Getenv("AUTH_RATE_LIMIT"); got != "10" {
+		t.Fatalf("AUTH_RATE_LIMIT = %q, want 10", got)
+	}
+
+	// The file overrides variables that are already set
+	writeRuntimeFile(t, path, "# runtime settings\nAUTH_RATE_LIMIT=25\nOTP_DAILY_LIMIT=\"7\"\n")
+	if err := loadRuntimeFile(); err != nil {
+		t.Fatalf("loadRuntimeFile: %v", err)
+	}
+	if got := os.Getenv("AUTH_RATE_LIMIT"); got != "25" {
+		t.Errorf("AUTH_RATE_LIMIT = %q, want 25", got)
+	}
+	if got := os.Getenv("OTP_DAILY_LIMIT"); got != "7" {
+		t.Errorf("OTP_DAILY_LIMIT = %q, want 7", got)
+	}
+
+	// Removing a line restores the value from before the file
+	writeRuntimeFile(t, path, "OTP_DAILY_LIMIT=8\n")
+	if err := loadRuntimeFile(); err != nil {
+		t.Fatalf("loadRuntimeFile: %v", err)
+	}
+	if got := os.Getenv("AUTH_RATE_LIMIT"); got != "10" {
+		t.Errorf("AUTH_RATE_LIMIT = %q, want the original 10", got)
+	}
+	writeRuntimeFile(t, path, "")
+	if err := loadRuntimeFile(); err != nil {
+		t.Fatalf("loadRuntimeFile: %v", err)
+	}
+	if _, set := os.LookupEnv("OTP_DAILY_LIMIT"); set {
+		t.Error("OTP_DAILY_LIMIT should be unset again")
+	}
+}
+
+func TestLoadRuntimeFileRejectsStructuralSettings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "runtime.env")
+	t.Setenv("RUNTIME_CONFIG_FILE", path)
+	t.Setenv("AUTH_RATE_LIMIT", "10")
+	t.Cleanup(func() { runtimeFileOriginals = map[string]*string{} })
+	writeRuntimeFile(t, path, "AUTH_RATE_LIMIT=25\nDB_HOST=elsewhere\n")
+
+	err := loadRuntimeFile()
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected a ValidationError, got %v", err)
+	}
+	if len(verr.Problems) != 1 || verr.Problems[0].Key != "DB_HOST" {
+		t.Errorf("problems = %+v, want DB_HOST only", verr.Problems)
+	}
+	if got := os.Getenv("AUTH_RATE_LIMIT"); got != "10" {
+		t.Errorf("AUTH_RATE_LIMIT = %q, a rejected file must not be applied", got)
+	}
+}
+
+func writeRuntimeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,285 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Secret providers selectable with SECRETS_PROVIDER
+const (
+	// SecretsProviderEnv reads credentials from the environment (development)
+	SecretsProviderEnv = "env"
+	// SecretsProviderVault reads them from a HashiCorp Vault KV v2 secret
+	SecretsProviderVault = "vault"
+	// SecretsProviderFile reads one file per credential from a directory, as
+	// mounted by Docker secrets or the AWS/GCP Secrets Store CSI drivers
+	SecretsProviderFile = "file"
+)
+
+// SecretsConfig selects where credentials are loaded from. Whatever the
+// provider, a credential it has no value for keeps its environment value.
+type SecretsConfig struct {
+	Provider string        `json:"provider"`
+	CacheTTL time.Duration `json:"cache_ttl"`
+	Dir      string        `json:"dir"`
+	Vault    VaultConfig   `json:"vault"`
+}
+
+// VaultConfig holds the address, login and secret location for Vault
+type VaultConfig struct {
+	Address    string `json:"address"`
+	Namespace  string `json:"namespace"`
+	AuthMethod string `json:"auth_method"` // token, approle, kubernetes
+	AuthMount  string `json:"auth_mount"`
+	Token      string `json:"token"`
+	RoleID     string `json:"role_id"`
+	SecretID   string `json:"secret_id"`
+	// Role and KubernetesTokenPath are used by the kubernetes auth method
+	Role                string        `json:"role"`
+	KubernetesTokenPath string        `json:"kubernetes_token_path"`
+	KVMount             string        `json:"kv_mount"`
+	SecretPath          string        `json:"secret_path"`
+	Timeout             time.Duration `json:"timeout"`
+}
+
+// secretFields are the credentials a secret provider may supply, named by
+// the environment variable they replace
+var secretFields = []struct {
+	key   string
+	field func(*ProductionConfig) *string
+}{
+	{"JWT_SECRET_KEY", func(c *ProductionConfig) *string { return &c.JWT.SecretKey }},
+	{"JWT_PRIVATE_KEY", func(c *ProductionConfig) *string { return &c.JWT.PrivateKey }},
+	{"JWT_PUBLIC_KEY", func(c *ProductionConfig) *string { return &c.JWT.PublicKey }},
+	{"ATIPAY_API_KEY", func(c *ProductionConfig) *string { return &c.Atipay.APIKey }},
+	{"ATIPAY_TERMINAL", func(c *ProductionConfig) *string { return &c.Atipay.Terminal }},
+	{"ATIPAY_TERMINALS", func(c *ProductionConfig) *string { return &c.Atipay.Terminals }},
+	{"PAYAM_SMS_USERNAME", func(c *ProductionConfig) *string { return &c.PayamSMS.Username }},
+	{"PAYAM_SMS_PASSWORD", func(c *ProductionConfig) *string { return &c.PayamSMS.Password }},
+	{"PAYAM_SMS_ROOT_ACCESS_TOKEN", func(c *ProductionConfig) *string { return &c.PayamSMS.RootAccessToken }},
+	{"NOWPAYMENTS_API_KEY", func(c *ProductionConfig) *string { return &c.Crypto.NowPayments.APIKey }},
+	{"NOWPAYMENTS_IPN_SECRET", func(c *ProductionConfig) *string { return &c.Crypto.NowPayments.IPNSecret }},
+	{"TELEGRAM_BOT_TOKEN", func(c *ProductionConfig) *string { return &c.Telegram.BotToken }},
+	{"TELEGRAM_WEBHOOK_SECRET", func(c *ProductionConfig) *string { return &c.Telegram.WebhookSecret }},
+	{"SHEBA_INQUIRY_API_KEY", func(c *ProductionConfig) *string { return &c.ShebaInquiry.APIKey }},
+	{"SHEBA_INQUIRY_SECRET_KEY", func(c *ProductionConfig) *string { return &c.ShebaInquiry.SecretKey }},
+}
+
+// SecretKeys returns the credentials that can be loaded from a secret provider
+func SecretKeys() []string {
+	keys := make([]string, 0, len(secretFields))
+	for _, f := range secretFields {
+		keys = append(keys, f.key)
+	}
+	return keys
+}
+
+// secretProvider fetches credentials by key; keys it has no value for are
+// left out of the result
+type secretProvider interface {
+	fetch(ctx context.Context, keys []string) (map[string]string, error)
+}
+
+// secretRenewer is a provider whose login expires and has to be renewed
+type secretRenewer interface {
+	// renewIn returns how long until the login should be renewed, false if
+	// it does not expire
+	renewIn() (time.Duration, bool)
+	renew(ctx context.Context) error
+}
+
+func newSecretProvider(cfg SecretsConfig) (secretProvider, error) {
+	switch cfg.Provider {
+	case SecretsProviderVault:
+		return newVaultClient(cfg.Vault), nil
+	case SecretsProviderFile:
+		return fileSecretProvider{dir: cfg.Dir}, nil
+	default:
+		return nil, fmt.Errorf("unknown secrets provider %q", cfg.Provider)
+	}
+}
+
+// fileSecretProvider reads each credential from a file named after its key
+type fileSecretProvider struct {
+	dir string
+}
+
+func (p fileSecretProvider) fetch(_ context.Context, keys []string) (map[string]string, error) {
+	values := map[string]string{}
+	for _, key := range keys {
+		data, err := os.ReadFile(filepath.Join(p.dir, key))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read secret %s: %w", key, err)
+		}
+		values[key] = strings.TrimRight(string(data), "\r\n")
+	}
+	return values, nil
+}
+
+// secretCache keeps the provider, with its login, and the last credentials
+// it returned. Loads within the cache TTL, such as configuration reloads,
+// reuse them, and a provider outage falls back to them.
+var secretCache struct {
+	mu        sync.Mutex
+	source    string
+	provider  secretProvider
+	values    map[string]string
+	fetchedAt time.Time
+}
+
+// loadSecrets replaces the credentials in cfg with those of the configured
+// provider. An invalid secrets section is left for validation to report.
+func loadSecrets(cfg *ProductionConfig) error {
+	if cfg.Secrets.Provider == SecretsProviderEnv {
+		return nil
+	}
+	p := &problems{section: "secrets"}
+	validateSecrets(p, cfg)
+	if len(p.list) > 0 {
+		return nil
+	}
+
+	values, _, err := cachedSecrets(cfg.Secrets, false)
+	if err != nil {
+		return err
+	}
+	for _, f := range secretFields {
+		if value, ok := values[f.key]; ok {
+			*f.field(cfg) = value
+		}
+	}
+	return nil
+}
+
+// cachedSecrets returns the cached credentials, fetching them again when
+// they are older than the cache TTL or force is set. It also reports the
+// keys whose value changed since the previous fetch.
+func cachedSecrets(cfg SecretsConfig, force bool) (map[string]string, []string, error) {
+	secretCache.mu.Lock()
+	defer secretCache.mu.Unlock()
+
+	if source := fmt.Sprintf("%+v", cfg); secretCache.source != source {
+		provider, err := newSecretProvider(cfg)
+		if err != nil {
+			return nil, nil, err
+		}
+		secretCache.source = source
+		secretCache.provider = provider
+		secretCache.values = nil
+	}
+	if !force && secretCache.values != nil && time.Since(secretCache.fetchedAt) < cfg.CacheTTL {
+		return maps.Clone(secretCache.values), nil, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), secretFetchTimeout(cfg))
+	defer cancel()
+	values, err := secretCache.provider.fetch(ctx, SecretKeys())
+	if err != nil {
+		if secretCache.values != nil {
+			log.Printf("secrets: %s unavailable, keeping the credentials fetched %s ago: %v",
+				cfg.Provider, time.Since(secretCache.fetchedAt).Round(time.Second), err)
+			return maps.Clone(secretCache.values), nil, nil
+		}
+		return nil, nil, fmt.Errorf("failed to load secrets from %s: %w", cfg.Provider, err)
+	}
+
+	var changed []string
+	if secretCache.values != nil {
+		for _, key := range SecretKeys() {
+			if secretCache.values[key] != values[key] {
+				changed = append(changed, key)
+			}
+		}
+	}
+	secretCache.values = values
+	secretCache.fetchedAt = time.Now()
+	return maps.Clone(values), changed, nil
+}
+
+func secretFetchTimeout(cfg SecretsConfig) time.Duration {
+	if cfg.Provider == SecretsProviderVault && cfg.Vault.Timeout > 0 {
+		// Login, renewal and the read itself
+		return 3 * cfg.Vault.Timeout
+	}
+	return 10 * time.Second
+}
+
+// StartSecretRenewal keeps the secret provider's login alive and refreshes
+// the cached credentials every cache TTL, logging the keys that were
+// rotated. The services hold the credentials they were started with, so a
+// rotated credential takes effect on the next restart. It returns a function
+// that stops the renewal.
+func StartSecretRenewal(cfg SecretsConfig) func() {
+	if cfg.Provider == SecretsProviderEnv || cfg.CacheTTL <= 0 {
+		return func() {}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		nextRefresh := time.Now().Add(cfg.CacheTTL)
+		for {
+			wait := time.Until(nextRefresh)
+			renewer := currentSecretRenewer()
+			renewDue := false
+			if renewer != nil {
+				if in, ok := renewer.renewIn(); ok && in < wait {
+					wait, renewDue = in, true
+				}
+			}
+			timer := time.NewTimer(max(wait, time.Second))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+
+			if renewDue {
+				renewCtx, renewCancel := context.WithTimeout(ctx, secretFetchTimeout(cfg))
+				err := renewer.renew(renewCtx)
+				renewCancel()
+				if err != nil {
+					log.Printf("secrets: failed to renew %s login: %v", cfg.Provider, err)
+				}
+				continue
+			}
+
+			nextRefresh = time.Now().Add(cfg.CacheTTL)
+			_, changed, err := cachedSecrets(cfg, true)
+			if err != nil {
+				log.Printf("secrets: refresh failed: %v", err)
+				continue
+			}
+			if len(changed) > 0 {
+				slices.Sort(changed)
+				log.Printf("secrets: %s rotated in %s; restart to apply", strings.Join(changed, ", "), cfg.Provider)
+			}
+		}
+	}()
+
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+func currentSecretRenewer() secretRenewer {
+	secretCache.mu.Lock()
+	defer secretCache.mu.Unlock()
+	renewer, _ := secretCache.provider.(secretRenewer)
+	return renewer
+}
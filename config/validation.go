@@ -0,0 +1,842 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/i18n"
+	"github.com/amirphl/Yamata-no-Orochi/pricing"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/google/uuid"
+)
+
+// FieldError is a single configuration problem: the section it belongs to,
+// the environment variable to fix and what is wrong with it
+type FieldError struct {
+	Section string `json:"section"`
+	Key     string `json:"key"`
+	Message string `json:"message"`
+}
+
+func (e FieldError) Error() string {
+	return e.Key + " " + e.Message
+}
+
+// ValidationError lists every problem found in a configuration, so a broken
+// deployment can be fixed in one pass
+type ValidationError struct {
+	Problems []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "configuration validation failed with %d problem(s):", len(e.Problems))
+	for _, p := range e.Problems {
+		fmt.Fprintf(&b, "\n  [%s] %s", p.Section, p.Error())
+	}
+	return b.String()
+}
+
+// Keys returns the environment variables with a problem
+func (e *ValidationError) Keys() []string {
+	keys := make([]string, 0, len(e.Problems))
+	for _, p := range e.Problems {
+		keys = append(keys, p.Key)
+	}
+	return keys
+}
+
+// malformedEnv records environment variables whose value could not be parsed
+// while loading; the default is used in their place and validation reports them
+var malformedEnv []FieldError
+
+func reportMalformedEnv(key, value, kind string) {
+	malformedEnv = append(malformedEnv, FieldError{
+		Section: "environment",
+		Key:     key,
+		Message: fmt.Sprintf("%q is not a valid %s", value, kind),
+	})
+}
+
+// problems collects FieldErrors section by section
+type problems struct {
+	section string
+	list    []FieldError
+}
+
+func (p *problems) add(key, format string, args ...any) {
+	p.list = append(p.list, FieldError{Section: p.section, Key: key, Message: fmt.Sprintf(format, args...)})
+}
+
+func (p *problems) required(key, value string) {
+	if strings.TrimSpace(value) == "" {
+		p.add(key, "is required")
+	}
+}
+
+func (p *problems) positive(key string, d time.Duration) {
+	if d <= 0 {
+		p.add(key, "must be positive")
+	}
+}
+
+func (p *problems) port(key string, port int) {
+	if port <= 0 || port > 65535 {
+		p.add(key, "must be between 1 and 65535")
+	}
+}
+
+func (p *problems) absoluteURL(key, value string) {
+	if value == "" {
+		return
+	}
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		p.add(key, "must be an absolute http(s) URL")
+	}
+}
+
+// ValidateProductionConfig checks every section of the configuration and
+// returns a *ValidationError listing all problems, or nil
+func ValidateProductionConfig(cfg *ProductionConfig) error {
+	p := &problems{}
+	for _, section := range []struct {
+		name     string
+		validate func(*problems, *ProductionConfig)
+	}{
+		{"database", validateDatabase},
+		{"jwt", validateJWT},
+		{"server", validateServer},
+		{"security", validateSecurity},
+		{"admin", validateAdmin},
+		{"i18n", validateI18n},
+		{"scheduler", validateScheduler},
+		{"job_queue", validateJobQueue},
+		{"outbound_http", validateOutboundHTTP},
+		{"sms", validateSMS},
+		{"email", validateEmail},
+		{"logging", validateLogging},
+		{"cache", validateCache},
+		{"deployment", validateDeployment},
+		{"atipay", validateAtipay},
+		{"bot", validateBot},
+		{"health", validateHealth},
+		{"wallet_transfer", validateWalletTransfer},
+		{"secrets", validateSecrets},
+		{"smart_tag_evaluation", validateSmartTagEvaluation},
+		{"system", validateSystem},
+		{"invoice", validateInvoice},
+		{"moadian", validateMoadian},
+		{"crypto", validateCrypto},
+		{"telegram", validateTelegram},
+		{"fcm", validateFCM},
+		{"storage", validateStorage},
+		{"uploads", validateUploads},
+		{"kyc", validateKYC},
+		{"sheba_inquiry", validateShebaInquiry},
+		{"mocks", validateMocks},
+	} {
+		p.section = section.name
+		section.validate(p, cfg)
+	}
+
+	if len(p.list) == 0 {
+		return nil
+	}
+	return &ValidationError{Problems: p.list}
+}
+
+// validateLoadedConfig validates a configuration just loaded from the
+// environment, including the values that could not be parsed
+func validateLoadedConfig(cfg *ProductionConfig) error {
+	list := append([]FieldError(nil), malformedEnv...)
+	var verr *ValidationError
+	if err := ValidateProductionConfig(cfg); errors.As(err, &verr) {
+		list = append(list, verr.Problems...)
+	}
+	if len(list) == 0 {
+		return nil
+	}
+	return &ValidationError{Problems: list}
+}
+
+func validateDatabase(p *problems, cfg *ProductionConfig) {
+	db := cfg.Database
+	p.required("DB_HOST", db.Host)
+	p.port("DB_PORT", db.Port)
+	p.required("DB_NAME", db.Name)
+	p.required("DB_USER", db.User)
+	p.required("DB_PASSWORD", db.Password)
+	if db.MaxOpenConns <= 0 {
+		p.add("DB_MAX_OPEN_CONNS", "must be positive")
+	}
+	if db.MaxIdleConns < 0 || db.MaxIdleConns > db.MaxOpenConns {
+		p.add("DB_MAX_IDLE_CONNS", "must be between 0 and DB_MAX_OPEN_CONNS")
+	}
+	switch db.MigrationCheck {
+	case "warn", "fail", "off":
+	default:
+		p.add("DB_MIGRATION_CHECK", "must be warn, fail or off")
+	}
+}
+
+func validateJWT(p *problems, cfg *ProductionConfig) {
+	jwt := cfg.JWT
+	if jwt.SecretKey == "" {
+		p.add("JWT_SECRET_KEY", "is required")
+	} else if len(jwt.SecretKey) < 32 {
+		p.add("JWT_SECRET_KEY", "must be at least 32 characters long")
+	}
+	if jwt.UseRSAKeys {
+		p.required("JWT_PRIVATE_KEY", jwt.PrivateKey)
+		p.required("JWT_PUBLIC_KEY", jwt.PublicKey)
+	}
+	p.positive("JWT_ACCESS_TOKEN_TTL", jwt.AccessTokenTTL)
+	p.positive("JWT_REFRESH_TOKEN_TTL", jwt.RefreshTokenTTL)
+	p.required("JWT_ISSUER", jwt.Issuer)
+	p.required("JWT_AUDIENCE", jwt.Audience)
+}
+
+func validateServer(p *problems, cfg *ProductionConfig) {
+	server := cfg.Server
+	p.port("SERVER_PORT", server.Port)
+	p.positive("SERVER_READ_TIMEOUT", server.ReadTimeout)
+	p.positive("SERVER_WRITE_TIMEOUT", server.WriteTimeout)
+	p.positive("SERVER_IDLE_TIMEOUT", server.IdleTimeout)
+	p.positive("SERVER_SHUTDOWN_TIMEOUT", server.ShutdownTimeout)
+	if !server.V1SunsetAt.IsZero() {
+		if server.V1DeprecatedAt.IsZero() {
+			p.add("API_V1_SUNSET_AT", "requires API_V1_DEPRECATED_AT")
+		} else if !server.V1SunsetAt.After(server.V1DeprecatedAt) {
+			p.add("API_V1_SUNSET_AT", "must be after API_V1_DEPRECATED_AT")
+		}
+	}
+	if cfg.GRPC.Enabled {
+		p.port("GRPC_PORT", cfg.GRPC.Port)
+		if cfg.GRPC.Port == server.Port {
+			p.add("GRPC_PORT", "must differ from SERVER_PORT")
+		}
+	}
+}
+
+func validateSecurity(p *problems, cfg *ProductionConfig) {
+	sec := cfg.Security
+	if sec.PasswordMinLength < 6 {
+		p.add("PASSWORD_MIN_LENGTH", "must be at least 6")
+	}
+	if sec.PasswordMaxLength < sec.PasswordMinLength || sec.PasswordMaxLength > 1024 {
+		p.add("PASSWORD_MAX_LENGTH", "must be between PASSWORD_MIN_LENGTH and 1024")
+	}
+	if sec.PasswordBreachCheck {
+		p.required("PASSWORD_BREACH_API_URL", sec.PasswordBreachAPIURL)
+		p.absoluteURL("PASSWORD_BREACH_API_URL", sec.PasswordBreachAPIURL)
+		p.positive("PASSWORD_BREACH_TIMEOUT", sec.PasswordBreachTimeout)
+		if sec.PasswordBreachCacheTTL < 0 {
+			p.add("PASSWORD_BREACH_CACHE_TTL", "must not be negative")
+		}
+	}
+	if sec.BcryptCost < 10 || sec.BcryptCost > 14 {
+		p.add("BCRYPT_COST", "must be between 10 and 14")
+	}
+	if sec.Argon2Memory < 19*1024 || sec.Argon2Memory > 4*1024*1024 {
+		p.add("ARGON2_MEMORY", "must be between 19456 and 4194304 KiB")
+	}
+	if sec.Argon2Iterations < 1 || sec.Argon2Iterations > 10 {
+		p.add("ARGON2_ITERATIONS", "must be between 1 and 10")
+	}
+	if sec.Argon2Parallelism < 1 || sec.Argon2Parallelism > 16 {
+		p.add("ARGON2_PARALLELISM", "must be between 1 and 16")
+	}
+	for _, entry := range sec.AdminIPAllowlist {
+		if _, _, err := net.ParseCIDR(entry); err != nil && net.ParseIP(entry) == nil {
+			p.add("ADMIN_IP_ALLOWLIST", "entry %q is not an IP address or CIDR range", entry)
+		}
+	}
+	p.absoluteURL("LOGIN_ALERT_URL", sec.LoginAlertURL)
+	for _, limit := range []struct {
+		key   string
+		value int
+	}{
+		{"AUTH_RATE_LIMIT", sec.AuthRateLimit},
+		{"OTP_RATE_LIMIT", sec.OTPRateLimit},
+		{"PAYMENT_IP_RATE_LIMIT", sec.PaymentIPRateLimit},
+		{"PAYMENT_RATE_LIMIT", sec.PaymentRateLimit},
+		{"OTP_DAILY_LIMIT", sec.OTPDailyLimit},
+	} {
+		if limit.value < 0 {
+			p.add(limit.key, "must not be negative")
+		}
+	}
+	if sec.RateLimitWindow < 0 {
+		p.add("RATE_LIMIT_WINDOW", "must not be negative")
+	}
+	if sec.OTPCooldown < 0 {
+		p.add("OTP_COOLDOWN", "must not be negative")
+	}
+
+	if sec.TLSEnabled {
+		p.required("TLS_CERT_FILE", sec.TLSCertFile)
+		p.required("TLS_KEY_FILE", sec.TLSKeyFile)
+	}
+}
+
+func validateAdmin(p *problems, cfg *ProductionConfig) {
+	p.positive("ADMIN_IMPERSONATION_TTL", cfg.Admin.ImpersonationTTL)
+}
+
+func validateI18n(p *problems, cfg *ProductionConfig) {
+	if _, ok := i18n.ParseLocale(cfg.I18n.DefaultLocale); !ok {
+		p.add("I18N_DEFAULT_LOCALE", "%q is not a supported locale", cfg.I18n.DefaultLocale)
+	}
+	if _, ok := i18n.ParseLocale(cfg.I18n.AdminLocale); !ok {
+		p.add("I18N_ADMIN_LOCALE", "%q is not a supported locale", cfg.I18n.AdminLocale)
+	}
+}
+
+func validateScheduler(p *problems, cfg *ProductionConfig) {
+	s := cfg.Scheduler
+	if s.CampaignExecutionEnabled {
+		p.positive("CAMPAIGN_EXECUTION_INTERVAL", s.CampaignExecutionInterval)
+		if s.MessageSendDelay < 0 {
+			p.add("CAMPAIGN_MESSAGE_SEND_DELAY", "must not be negative")
+		}
+	}
+	if s.RecurrenceEnabled {
+		p.positive("CAMPAIGN_RECURRENCE_INTERVAL", s.RecurrenceInterval)
+	}
+	if s.AudienceImportEnabled {
+		p.positive("AUDIENCE_IMPORT_INTERVAL", s.AudienceImportInterval)
+	}
+	if s.CustomerDataEnabled {
+		p.positive("CUSTOMER_DATA_INTERVAL", s.CustomerDataInterval)
+	}
+	if s.PartitionMaintenanceEnabled {
+		p.positive("PARTITION_MAINTENANCE_INTERVAL", s.PartitionMaintenanceInterval)
+		if s.PartitionRetentionMonths > 0 {
+			p.required("PARTITION_ARCHIVE_DIR", s.PartitionArchiveDir)
+		}
+	}
+	if s.ReportRollupEnabled {
+		p.positive("REPORT_ROLLUP_INTERVAL", s.ReportRollupInterval)
+		if s.ReportRollupLookbackDays < 0 {
+			p.add("REPORT_ROLLUP_LOOKBACK_DAYS", "must not be negative")
+		}
+	}
+	if s.StorageLifecycleEnabled {
+		p.positive("STORAGE_LIFECYCLE_INTERVAL", s.StorageLifecycleInterval)
+	}
+	if s.AudienceColorEnabled {
+		p.positive("AUDIENCE_COLOR_INTERVAL", s.AudienceColorInterval)
+		p.positive("AUDIENCE_COLOR_LOOKBACK", s.AudienceColorLookback)
+		if s.AudienceColorDemoteFailures < 0 {
+			p.add("AUDIENCE_COLOR_DEMOTE_FAILURES", "must not be negative")
+		}
+		if s.AudienceColorPromoteClicks < 0 {
+			p.add("AUDIENCE_COLOR_PROMOTE_CLICKS", "must not be negative")
+		}
+		if s.AudienceColorBatchSize <= 0 {
+			p.add("AUDIENCE_COLOR_BATCH_SIZE", "must be positive")
+		}
+	}
+	if s.SMSRetryEnabled {
+		p.positive("SMS_RETRY_DELAY", s.SMSRetryDelay)
+		if s.SMSRetryMaxAttempts <= 0 {
+			p.add("SMS_RETRY_MAX_ATTEMPTS", "must be positive")
+		}
+		if len(s.SMSRetryTransientCodes) == 0 && len(s.SMSRetryPermanentCodes) == 0 {
+			p.add("SMS_RETRY_TRANSIENT_CODES", "set it or SMS_RETRY_PERMANENT_CODES when SMS_RETRY_ENABLED is true")
+		}
+		for _, code := range s.SMSRetryTransientCodes {
+			if slices.Contains(s.SMSRetryPermanentCodes, code) {
+				p.add("SMS_RETRY_PERMANENT_CODES", "%s is also a transient code", code)
+			}
+		}
+	}
+	if s.CryptoExpiryEnabled {
+		p.positive("CRYPTO_EXPIRY_INTERVAL", s.CryptoExpiryInterval)
+	}
+	if s.PaymentExpiryEnabled {
+		p.positive("PAYMENT_EXPIRY_INTERVAL", s.PaymentExpiryInterval)
+	}
+	if s.AtipayReconciliationEnabled {
+		p.positive("ATIPAY_RECONCILIATION_INTERVAL", s.AtipayReconciliationInterval)
+		p.required("ATIPAY_SETTLEMENT_REPORT_URL", cfg.Atipay.SettlementReportURL)
+	}
+	if s.PostpaidInvoicingEnabled {
+		p.positive("POSTPAID_INVOICING_INTERVAL", s.PostpaidInvoicingInterval)
+		if s.PostpaidInvoiceDueDays <= 0 {
+			p.add("POSTPAID_INVOICE_DUE_DAYS", "must be positive")
+		}
+	}
+	if s.CampaignExpiryEnabled {
+		p.positive("CAMPAIGN_EXPIRY_INTERVAL", s.CampaignExpiryInterval)
+	}
+	if s.AccountDeletionGracePeriod < 0 {
+		p.add("ACCOUNT_DELETION_GRACE_PERIOD", "must not be negative")
+	}
+	p.positive("DATA_EXPORT_RETENTION", s.DataExportRetention)
+	p.positive("SCHEDULER_LEADER_RETRY_INTERVAL", s.LeaderRetryInterval)
+}
+
+func validateJobQueue(p *problems, cfg *ProductionConfig) {
+	q := cfg.JobQueue
+	if !q.Enabled {
+		return
+	}
+	p.positive("JOB_QUEUE_POLL_INTERVAL", q.PollInterval)
+	if q.Workers <= 0 {
+		p.add("JOB_QUEUE_WORKERS", "must be positive")
+	}
+	if q.MaxAttempts <= 0 {
+		p.add("JOB_QUEUE_MAX_ATTEMPTS", "must be positive")
+	}
+	p.positive("JOB_QUEUE_BACKOFF_BASE", q.BackoffBase)
+	if q.BackoffMax < q.BackoffBase {
+		p.add("JOB_QUEUE_BACKOFF_MAX", "must not be less than JOB_QUEUE_BACKOFF_BASE")
+	}
+	p.positive("JOB_QUEUE_RETENTION", q.Retention)
+}
+
+func validateOutboundHTTP(p *problems, cfg *ProductionConfig) {
+	o := cfg.OutboundHTTP
+	if o.MaxIdleConns <= 0 {
+		p.add("OUTBOUND_HTTP_MAX_IDLE_CONNS", "must be positive")
+	}
+	if o.MaxIdleConnsPerHost <= 0 {
+		p.add("OUTBOUND_HTTP_MAX_IDLE_CONNS_PER_HOST", "must be positive")
+	}
+	p.positive("OUTBOUND_HTTP_IDLE_CONN_TIMEOUT", o.IdleConnTimeout)
+	for host, timeout := range o.HostTimeouts {
+		if timeout <= 0 {
+			p.add("OUTBOUND_HTTP_HOST_TIMEOUTS", "timeout for %s must be positive", host)
+		}
+	}
+	if o.RetryMax < 0 {
+		p.add("OUTBOUND_HTTP_RETRY_MAX", "must not be negative")
+	}
+	if o.RetryMax > 0 {
+		p.positive("OUTBOUND_HTTP_RETRY_BACKOFF", o.RetryBackoff)
+	}
+	if o.BreakerThreshold <= 0 {
+		p.add("OUTBOUND_HTTP_BREAKER_THRESHOLD", "must be positive")
+	}
+	p.positive("OUTBOUND_HTTP_BREAKER_COOLDOWN", o.BreakerCooldown)
+}
+
+func validateSMS(p *problems, cfg *ProductionConfig) {
+	switch cfg.SMS.ProviderDomain {
+	case "mock":
+	case "payamsms":
+		p.required("SMS_SOURCE_NUMBER", cfg.SMS.SourceNumber)
+		p.required("PAYAM_SMS_USERNAME", cfg.PayamSMS.Username)
+		p.required("PAYAM_SMS_PASSWORD", cfg.PayamSMS.Password)
+		p.absoluteURL("PAYAM_SMS_TOKEN_URL", cfg.PayamSMS.TokenURL)
+	default:
+		p.required("SMS_API_KEY", cfg.SMS.APIKey)
+		p.required("SMS_SOURCE_NUMBER", cfg.SMS.SourceNumber)
+	}
+}
+
+func validateEmail(p *problems, cfg *ProductionConfig) {
+	if cfg.Email.Host == "" {
+		return
+	}
+	p.required("EMAIL_USERNAME", cfg.Email.Username)
+	p.required("EMAIL_PASSWORD", cfg.Email.Password)
+	p.required("EMAIL_FROM_EMAIL", cfg.Email.FromEmail)
+}
+
+func validateLogging(p *problems, cfg *ProductionConfig) {
+	validLevels := []string{"debug", "info", "warn", "error"}
+	if level := cfg.Logging.Level; level != "" && !slices.Contains(validLevels, level) {
+		p.add("LOG_LEVEL", "must be one of: %v", validLevels)
+	}
+	if cfg.Logging.AuditBufferEnabled {
+		if cfg.Logging.AuditBufferSize <= 0 {
+			p.add("LOG_AUDIT_BUFFER_SIZE", "must be positive")
+		}
+		if cfg.Logging.AuditBatchSize <= 0 {
+			p.add("LOG_AUDIT_BATCH_SIZE", "must be positive")
+		}
+		p.positive("LOG_AUDIT_FLUSH_INTERVAL", cfg.Logging.AuditFlushInterval)
+	}
+}
+
+func validateCache(p *problems, cfg *ProductionConfig) {
+	if cfg.Cache.Enabled && cfg.Cache.Provider == "redis" && cfg.Cache.RedisURL == "" {
+		p.add("CACHE_REDIS_URL", "is required when cache is enabled with redis provider")
+	}
+}
+
+func validateDeployment(p *problems, cfg *ProductionConfig) {
+	// DOMAIN builds the payment gateway callback URL
+	domain := cfg.Deployment.Domain
+	switch {
+	case strings.TrimSpace(domain) == "":
+		p.add("DOMAIN", "is required")
+	case strings.Contains(domain, "your-domain.com"):
+		p.add("DOMAIN", "is still the template placeholder %q", domain)
+	case strings.Contains(domain, "://") || strings.Contains(domain, "/"):
+		p.add("DOMAIN", "must be a host name without scheme or path")
+	}
+}
+
+func validateAtipay(p *problems, cfg *ProductionConfig) {
+	p.required("ATIPAY_API_KEY", cfg.Atipay.APIKey)
+	p.required("ATIPAY_TERMINAL", cfg.Atipay.Terminal)
+	if _, err := cfg.Atipay.AllTerminals(); err != nil {
+		p.add("ATIPAY_TERMINALS", "%v", err)
+	}
+	switch cfg.Atipay.Routing {
+	case "", AtipayRoutingRoundRobin, AtipayRoutingAmount:
+	default:
+		p.add("ATIPAY_ROUTING", "must be %s or %s, got %q", AtipayRoutingRoundRobin, AtipayRoutingAmount, cfg.Atipay.Routing)
+	}
+	p.absoluteURL("ATIPAY_SETTLEMENT_REPORT_URL", cfg.Atipay.SettlementReportURL)
+}
+
+func validateBot(p *problems, cfg *ProductionConfig) {
+	// Campaign schedulers log in to the bot API to send campaigns
+	if !cfg.Scheduler.CampaignExecutionEnabled {
+		return
+	}
+	p.required("BOT_USERNAME", cfg.Bot.Username)
+	p.required("BOT_PASSWORD", cfg.Bot.Password)
+	p.absoluteURL("BOT_API_DOMAIN", cfg.Bot.APIDomain)
+}
+
+func validateHealth(p *problems, cfg *ProductionConfig) {
+	p.positive("HEALTH_CHECK_TIMEOUT", cfg.Health.CheckTimeout)
+	if cfg.Health.ProviderCacheTTL < 0 {
+		p.add("HEALTH_PROVIDER_CACHE_TTL", "must not be negative")
+	}
+}
+
+func validateWalletTransfer(p *problems, cfg *ProductionConfig) {
+	wt := cfg.WalletTransfer
+	if !wt.Enabled {
+		return
+	}
+	if wt.MinAmount == 0 {
+		p.add("WALLET_TRANSFER_MIN_AMOUNT", "must be positive")
+	}
+	if wt.MaxAmount < wt.MinAmount {
+		p.add("WALLET_TRANSFER_MAX_AMOUNT", "must not be less than WALLET_TRANSFER_MIN_AMOUNT")
+	}
+	if wt.DailyLimit < wt.MaxAmount {
+		p.add("WALLET_TRANSFER_DAILY_LIMIT", "must not be less than WALLET_TRANSFER_MAX_AMOUNT")
+	}
+}
+
+func validateSecrets(p *problems, cfg *ProductionConfig) {
+	sec := cfg.Secrets
+	switch sec.Provider {
+	case SecretsProviderEnv:
+		return
+	case SecretsProviderFile:
+		p.required("SECRETS_DIR", sec.Dir)
+	case SecretsProviderVault:
+		v := sec.Vault
+		p.required("VAULT_ADDR", v.Address)
+		p.absoluteURL("VAULT_ADDR", v.Address)
+		p.required("VAULT_KV_MOUNT", v.KVMount)
+		p.required("VAULT_SECRET_PATH", v.SecretPath)
+		p.positive("VAULT_TIMEOUT", v.Timeout)
+		switch v.AuthMethod {
+		case VaultAuthToken:
+			p.required("VAULT_TOKEN", v.Token)
+		case VaultAuthAppRole:
+			p.required("VAULT_ROLE_ID", v.RoleID)
+			p.required("VAULT_SECRET_ID", v.SecretID)
+		case VaultAuthKubernetes:
+			p.required("VAULT_ROLE", v.Role)
+			p.required("VAULT_K8S_TOKEN_PATH", v.KubernetesTokenPath)
+		default:
+			p.add("VAULT_AUTH_METHOD", "must be one of token, approle, kubernetes")
+		}
+	default:
+		p.add("SECRETS_PROVIDER", "must be one of env, vault, file")
+		return
+	}
+	p.positive("SECRETS_CACHE_TTL", sec.CacheTTL)
+}
+
+func validateSmartTagEvaluation(p *problems, cfg *ProductionConfig) {
+	ste := cfg.SmartTagEvaluation
+	if !ste.Enabled {
+		return
+	}
+	if ste.OpenAI.Model == "" {
+		p.add("SMART_TAG_EVALUATION_OPENAI_MODEL", "is required when smart tag evaluation is enabled")
+	}
+	p.positive("SMART_TAG_EVALUATION_OPENAI_TIMEOUT", ste.OpenAI.Timeout)
+	if ste.OpenAI.MaxRetries < 0 {
+		p.add("SMART_TAG_EVALUATION_OPENAI_MAX_RETRIES", "must be zero or greater")
+	}
+	if ste.Batching.TagBatchSize <= 0 {
+		p.add("SMART_TAG_EVALUATION_BATCHING_TAG_BATCH_SIZE", "must be positive")
+	}
+	p.positive("SMART_TAG_EVALUATION_SCHEDULER_POLL_INTERVAL", ste.Scheduler.PollInterval)
+	if ste.Scheduler.MaxParallelRuns <= 0 {
+		p.add("SMART_TAG_EVALUATION_SCHEDULER_MAX_PARALLEL_RUNS", "must be positive")
+	}
+}
+
+func validateSystem(p *problems, cfg *ProductionConfig) {
+	// System config is not optional and all must be parsed
+	sys := cfg.System
+	p.required("SYSTEM_USER_MOBILE", sys.SystemUserMobile)
+	p.required("TAX_USER_MOBILE", sys.TaxUserMobile)
+	p.required("SYSTEM_USER_EMAIL", sys.SystemUserEmail)
+	p.required("TAX_USER_EMAIL", sys.TaxUserEmail)
+
+	uuids := map[string]string{
+		"SYSTEM_USER_UUID":   sys.SystemUserUUID,
+		"TAX_USER_UUID":      sys.TaxUserUUID,
+		"SYSTEM_WALLET_UUID": sys.SystemWalletUUID,
+		"TAX_WALLET_UUID":    sys.TaxWalletUUID,
+	}
+	keys := make([]string, 0, len(uuids))
+	for key := range uuids {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if uuids[key] == "" {
+			p.add(key, "is required")
+		} else if _, err := uuid.Parse(uuids[key]); err != nil {
+			p.add(key, "is invalid")
+		}
+	}
+
+	if sys.SystemShebaNumber == "" {
+		p.add("SYSTEM_SHEBA_NUMBER", "is required")
+	} else if _, err := utils.ValidateShebaNumber(&sys.SystemShebaNumber); err != nil {
+		p.add("SYSTEM_SHEBA_NUMBER", "is invalid")
+	}
+	if _, err := pricing.ParseTaxSchedule(sys.TaxRateSchedule); err != nil {
+		p.add("TAX_RATE_SCHEDULE", "is invalid: %v", err)
+	}
+}
+
+func validateInvoice(p *problems, cfg *ProductionConfig) {
+	inv := cfg.Invoice
+	// The code is part of every invoice number, e.g. JZB-00000042
+	if inv.LegalEntityCode == "" {
+		p.add("INVOICE_LEGAL_ENTITY_CODE", "is required")
+	} else if len(inv.LegalEntityCode) > 16 || strings.Trim(inv.LegalEntityCode, "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789") != "" {
+		p.add("INVOICE_LEGAL_ENTITY_CODE", "must be up to 16 uppercase letters and digits")
+	}
+	p.required("INVOICE_SELLER_NAME", inv.SellerName)
+	p.required("INVOICE_FONT_PATH", inv.FontPath)
+	p.required("INVOICE_STORAGE_DIR", inv.StorageDir)
+}
+
+func validateMoadian(p *problems, cfg *ProductionConfig) {
+	m := cfg.Moadian
+	if !m.Enabled {
+		return
+	}
+	if m.BaseURL == "" {
+		p.add("MOADIAN_BASE_URL", "is required")
+	} else {
+		p.absoluteURL("MOADIAN_BASE_URL", m.BaseURL)
+	}
+	// The memory ID is the first part of every tax ID
+	if len(m.MemoryID) != 6 || strings.Trim(m.MemoryID, "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789") != "" {
+		p.add("MOADIAN_MEMORY_ID", "must be 6 uppercase letters and digits")
+	}
+	p.required("MOADIAN_PRIVATE_KEY_PATH", m.PrivateKeyPath)
+	p.required("MOADIAN_CERTIFICATE_PATH", m.CertificatePath)
+	if len(m.ServiceID) != 13 || strings.Trim(m.ServiceID, "0123456789") != "" {
+		p.add("MOADIAN_SERVICE_ID", "must be 13 digits")
+	}
+	// Moadian identifies the seller by economic code
+	p.required("INVOICE_SELLER_ECONOMIC_CODE", cfg.Invoice.SellerEconomicCode)
+	p.positive("MOADIAN_TIMEOUT", m.Timeout)
+	p.positive("MOADIAN_SUBMISSION_INTERVAL", m.Interval)
+	if m.BatchSize <= 0 {
+		p.add("MOADIAN_BATCH_SIZE", "must be positive")
+	}
+	if m.MaxAttempts <= 0 {
+		p.add("MOADIAN_MAX_ATTEMPTS", "must be positive")
+	}
+}
+
+func validateCrypto(p *problems, cfg *ProductionConfig) {
+	// OxaPay is always configured; NOWPayments is an optional second provider
+	switch cfg.Crypto.DefaultPlatform {
+	case "oxapay", "nowpayments":
+	default:
+		p.add("CRYPTO_DEFAULT_PLATFORM", "must be oxapay or nowpayments")
+		return
+	}
+	if cfg.Crypto.Oxapay.BaseURL == "" {
+		p.add("OXA_BASE_URL", "is required")
+	} else {
+		p.absoluteURL("OXA_BASE_URL", cfg.Crypto.Oxapay.BaseURL)
+	}
+	p.required("OXA_API_KEY", cfg.Crypto.Oxapay.APIKey)
+	if bps := cfg.Crypto.PaymentToleranceBPS; bps < 0 || bps >= 10000 {
+		p.add("CRYPTO_PAYMENT_TOLERANCE_BPS", "must be between 0 and 9999")
+	}
+	p.positive("CRYPTO_WEBHOOK_MAX_AGE", cfg.Crypto.WebhookMaxAge)
+	validateExchangeRates(p, cfg.Crypto.Rates)
+
+	np := cfg.Crypto.NowPayments
+	if np.APIKey == "" {
+		if cfg.Crypto.DefaultPlatform == "nowpayments" {
+			p.add("NOWPAYMENTS_API_KEY", "is required when nowpayments is default platform")
+		}
+		return
+	}
+	p.required("NOWPAYMENTS_BASE_URL", np.BaseURL)
+	p.absoluteURL("NOWPAYMENTS_BASE_URL", np.BaseURL)
+	// IPN callbacks can not be verified without it
+	p.required("NOWPAYMENTS_IPN_SECRET", np.IPNSecret)
+}
+
+func validateExchangeRates(p *problems, rates ExchangeRateConfig) {
+	for _, source := range rates.Sources {
+		if source != "wallex" && source != "nobitex" {
+			p.add("CRYPTO_RATE_SOURCES", "must list only wallex and nobitex")
+			break
+		}
+	}
+	if rates.MinSources < 1 || rates.MinSources > len(rates.Sources) {
+		p.add("CRYPTO_RATE_MIN_SOURCES", "must be between 1 and the number of CRYPTO_RATE_SOURCES")
+	}
+	if rates.CacheTTL < 0 {
+		p.add("CRYPTO_RATE_CACHE_TTL", "must not be negative")
+	}
+	if rates.MaxDivergenceBPS < 1 || rates.MaxDivergenceBPS >= 10000 {
+		p.add("CRYPTO_RATE_MAX_DIVERGENCE_BPS", "must be between 1 and 9999")
+	}
+	if rates.Timeout <= 0 {
+		p.add("CRYPTO_RATE_TIMEOUT", "must be positive")
+	}
+}
+
+func validateTelegram(p *problems, cfg *ProductionConfig) {
+	tg := cfg.Telegram
+	if !tg.Enabled() {
+		return
+	}
+	// Deep links name the bot and updates can not be trusted without the secret
+	p.required("TELEGRAM_BOT_USERNAME", tg.BotUsername)
+	p.required("TELEGRAM_WEBHOOK_SECRET", tg.WebhookSecret)
+	p.absoluteURL("TELEGRAM_BASE_URL", tg.BaseURL)
+	p.positive("TELEGRAM_LINK_TTL", tg.LinkTTL)
+	p.positive("TELEGRAM_TIMEOUT", tg.Timeout)
+}
+
+// fcmTopicPattern is the topic name format FCM accepts
+var fcmTopicPattern = regexp.MustCompile(`^[a-zA-Z0-9-_.~%]{1,900}$`)
+
+func validateFCM(p *problems, cfg *ProductionConfig) {
+	fcm := cfg.FCM
+	if !fcm.Enabled() {
+		return
+	}
+	p.absoluteURL("FCM_BASE_URL", fcm.BaseURL)
+	p.absoluteURL("FCM_IID_BASE_URL", fcm.IIDBaseURL)
+	if fcm.BroadcastTopic != "" && !fcmTopicPattern.MatchString(fcm.BroadcastTopic) {
+		p.add("FCM_BROADCAST_TOPIC", "must contain only letters, digits and -_.~%%")
+	}
+	p.positive("FCM_TIMEOUT", fcm.Timeout)
+}
+
+func validateShebaInquiry(p *problems, cfg *ProductionConfig) {
+	si := cfg.ShebaInquiry
+	if !si.Enabled() {
+		return
+	}
+	p.required("SHEBA_INQUIRY_SECRET_KEY", si.SecretKey)
+	p.absoluteURL("SHEBA_INQUIRY_BASE_URL", si.BaseURL)
+	p.positive("SHEBA_INQUIRY_CACHE_TTL", si.CacheTTL)
+	p.positive("SHEBA_INQUIRY_TIMEOUT", si.Timeout)
+}
+
+func validateStorage(p *problems, cfg *ProductionConfig) {
+	st := cfg.Storage
+	switch st.Driver {
+	case "":
+		return
+	case StorageDriverS3:
+		p.absoluteURL("STORAGE_ENDPOINT", st.Endpoint)
+		p.required("STORAGE_BUCKET", st.Bucket)
+		p.required("STORAGE_ACCESS_KEY", st.AccessKey)
+		p.required("STORAGE_SECRET_KEY", st.SecretKey)
+		p.positive("STORAGE_TIMEOUT", st.Timeout)
+		// S3 refuses signatures valid for more than 7 days
+		if st.SignedURLTTL > 7*24*time.Hour {
+			p.add("STORAGE_SIGNED_URL_TTL", "must not exceed 168h with the s3 driver")
+		}
+	case StorageDriverLocal:
+		p.required("STORAGE_LOCAL_DIR", st.LocalDir)
+		p.absoluteURL("STORAGE_LOCAL_BASE_URL", st.LocalBaseURL)
+		if len(st.LocalSigningKey) < 32 {
+			p.add("STORAGE_LOCAL_SIGNING_KEY", "must be at least 32 characters")
+		}
+	default:
+		p.add("STORAGE_DRIVER", "must be s3 or local")
+	}
+	if st.SignedURLTTL < time.Second {
+		p.add("STORAGE_SIGNED_URL_TTL", "must be at least 1s")
+	}
+	for prefix, expireAfter := range st.Lifecycle {
+		// An empty prefix would expire every file, KYC documents included
+		if strings.Trim(prefix, "/") == "" {
+			p.add("STORAGE_LIFECYCLE", "prefix must not be empty")
+		}
+		if expireAfter <= 0 {
+			p.add("STORAGE_LIFECYCLE", "retention of %s must be positive", prefix)
+		}
+	}
+}
+
+func validateUploads(p *problems, cfg *ProductionConfig) {
+	up := cfg.Uploads
+	if up.ClamAVAddress != "" {
+		if _, _, err := net.SplitHostPort(up.ClamAVAddress); err != nil {
+			p.add("UPLOADS_CLAMAV_ADDRESS", "must be host:port")
+		}
+		p.positive("UPLOADS_CLAMAV_TIMEOUT", up.ClamAVTimeout)
+	}
+}
+
+func validateKYC(p *problems, cfg *ProductionConfig) {
+	kyc := cfg.KYC
+	if kyc.UnverifiedDailyLimit > 0 && kyc.UnverifiedMonthlyLimit > 0 && kyc.UnverifiedDailyLimit > kyc.UnverifiedMonthlyLimit {
+		p.add("KYC_UNVERIFIED_DAILY_LIMIT", "must not exceed KYC_UNVERIFIED_MONTHLY_LIMIT")
+	}
+}
+
+func validateMocks(p *problems, cfg *ProductionConfig) {
+	if cfg.Mocks.Any() && strings.EqualFold(cfg.Deployment.Environment, "production") {
+		p.add("APP_ENV", "must not be production while a provider is mocked")
+	}
+	for _, m := range []struct {
+		prefix string
+		cfg    ProviderMockConfig
+	}{
+		{"MOCK_ATIPAY", cfg.Mocks.Atipay},
+		{"MOCK_OXAPAY", cfg.Mocks.Oxapay},
+		{"MOCK_PAYAM_SMS", cfg.Mocks.PayamSMS},
+		{"MOCK_BOT", cfg.Mocks.Bot},
+	} {
+		if m.cfg.Latency < 0 {
+			p.add(m.prefix+"_LATENCY", "must not be negative")
+		}
+		if m.cfg.FailureRate < 0 || m.cfg.FailureRate > 1 {
+			p.add(m.prefix+"_FAILURE_RATE", "must be between 0 and 1")
+		}
+	}
+}
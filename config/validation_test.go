@@ -0,0 +1,244 @@
+package config
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func validConfig() *ProductionConfig {
+	return &ProductionConfig{
+		Database: DatabaseConfig{Host: "db", Port: 5432, Name: "yamata", User: "yamata", Password: "secret", MaxOpenConns: 20, MaxIdleConns: 10, MigrationCheck: "warn"},
+		JWT: JWTConfig{
+			SecretKey:      strings.Repeat("k", 32),
+			AccessTokenTTL: time.Hour, RefreshTokenTTL: 24 * time.Hour,
+			Issuer: "yamata", Audience: "yamata-api",
+		},
+		Server: ServerConfig{Port: 8080, ReadTimeout: time.Minute, WriteTimeout: time.Minute, IdleTimeout: time.Minute, ShutdownTimeout: time.Minute},
+		Security: SecurityConfig{
+			PasswordMinLength: 8, PasswordMaxLength: 100, BcryptCost: 12,
+			Argon2Memory: 64 * 1024, Argon2Iterations: 3, Argon2Parallelism: 2,
+		},
+		Admin:      AdminConfig{ImpersonationTTL: 30 * time.Minute},
+		I18n:       I18nConfig{DefaultLocale: "en", AdminLocale: "fa"},
+		Scheduler:  SchedulerConfig{DataExportRetention: 7 * 24 * time.Hour, LeaderRetryInterval: 15 * time.Second},
+		SMS:        SMSConfig{ProviderDomain: "mock"},
+		Cache:      CacheConfig{Enabled: true, Provider: "redis", RedisURL: "redis://redis:6379"},
+		Deployment: DeploymentConfig{Domain: "jaazebeh.ir"},
+		Atipay:     AtipayConfig{APIKey: "key", Terminal: "terminal"},
+		Health:     HealthConfig{CheckTimeout: 2 * time.Second, ProviderCacheTTL: 30 * time.Second},
+		OutboundHTTP: OutboundHTTPConfig{
+			MaxIdleConns: 100, MaxIdleConnsPerHost: 10, IdleConnTimeout: 90 * time.Second,
+			RetryMax: 2, RetryBackoff: 200 * time.Millisecond, BreakerThreshold: 5, BreakerCooldown: 30 * time.Second,
+		},
+		Secrets: SecretsConfig{Provider: SecretsProviderEnv},
+		System: SystemConfig{
+			SystemUserUUID: "3f1d2c4e-8a7b-4c6d-9e0f-1a2b3c4d5e6f", TaxUserUUID: "4f1d2c4e-8a7b-4c6d-9e0f-1a2b3c4d5e6f",
+			SystemWalletUUID: "5f1d2c4e-8a7b-4c6d-9e0f-1a2b3c4d5e6f", TaxWalletUUID: "6f1d2c4e-8a7b-4c6d-9e0f-1a2b3c4d5e6f",
+			SystemUserMobile: "+989123456789", TaxUserMobile: "+989123456788",
+			SystemUserEmail: "system@jaazebeh.ir", TaxUserEmail: "tax@jaazebeh.ir",
+			SystemShebaNumber: "IR820540102680020817909002",
+		},
+		Invoice: InvoiceConfig{LegalEntityCode: "JZB", SellerName: "Jazebeh", FontPath: "fonts/Vazirmatn-Regular.ttf", StorageDir: "data/invoices"},
+		Crypto: CryptoConfig{
+			DefaultPlatform: "oxapay", Oxapay: OxapayConfig{BaseURL: "https://api.oxapay.com", APIKey: "key"},
+			WebhookMaxAge: 24 * time.Hour,
+			Rates:         ExchangeRateConfig{Sources: []string{"wallex", "nobitex"}, MinSources: 2, CacheTTL: time.Minute, MaxDivergenceBPS: 300, Timeout: 5 * time.Second},
+		},
+	}
+}
+
+func validationKeys(t *testing.T, cfg *ProductionConfig) []string {
+	t.Helper()
+
+	err := ValidateProductionConfig(cfg)
+	if err == nil {
+		return nil
+	}
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("ValidateProductionConfig() error = %T, want *ValidationError", err)
+	}
+	return verr.Keys()
+}
+
+func TestValidateProductionConfigAcceptsValidConfig(t *testing.T) {
+	if err := ValidateProductionConfig(validConfig()); err != nil {
+		t.Fatalf("ValidateProductionConfig() error = %v", err)
+	}
+}
+
+func TestValidateProductionConfigReportsEveryProblem(t *testing.T) {
+	cfg := validConfig()
+	cfg.Database.Password = ""
+	cfg.Atipay = AtipayConfig{}
+	cfg.Deployment.Domain = "your-domain.com"
+	cfg.Health.CheckTimeout = 0
+
+	err := ValidateProductionConfig(cfg)
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("ValidateProductionConfig() error = %v, want *ValidationError", err)
+	}
+	want := []string{"DB_PASSWORD", "DOMAIN", "ATIPAY_API_KEY", "ATIPAY_TERMINAL", "HEALTH_CHECK_TIMEOUT"}
+	if !slices.Equal(verr.Keys(), want) {
+		t.Fatalf("Keys() = %v, want %v", verr.Keys(), want)
+	}
+	if verr.Problems[2].Section != "atipay" {
+		t.Errorf("ATIPAY_API_KEY section = %q, want atipay", verr.Problems[2].Section)
+	}
+	if msg := err.Error(); !strings.Contains(msg, "5 problem(s)") || !strings.Contains(msg, "[database] DB_PASSWORD is required") {
+		t.Errorf("Error() = %q", msg)
+	}
+}
+
+func TestValidateProductionConfigConditionalSections(t *testing.T) {
+	cases := []struct {
+		name   string
+		mutate func(*ProductionConfig)
+		want   []string
+	}{
+		{"payamsms needs credentials", func(c *ProductionConfig) { c.SMS.ProviderDomain = "payamsms" },
+			[]string{"SMS_SOURCE_NUMBER", "PAYAM_SMS_USERNAME", "PAYAM_SMS_PASSWORD"}},
+		{"campaign execution needs bot credentials", func(c *ProductionConfig) {
+			c.Scheduler.CampaignExecutionEnabled = true
+			c.Scheduler.CampaignExecutionInterval = time.Minute
+		}, []string{"BOT_USERNAME", "BOT_PASSWORD"}},
+		{"RSA keys", func(c *ProductionConfig) { c.JWT.UseRSAKeys = true }, []string{"JWT_PRIVATE_KEY", "JWT_PUBLIC_KEY"}},
+		{"relative URLs", func(c *ProductionConfig) {
+			c.Security.LoginAlertURL = "/security/not-me"
+			c.Crypto.Oxapay.BaseURL = "api.oxapay.com"
+		}, []string{"LOGIN_ALERT_URL", "OXA_BASE_URL"}},
+		{"breach check needs a URL and timeout", func(c *ProductionConfig) {
+			c.Security.PasswordBreachCheck = true
+			c.Security.PasswordBreachAPIURL = "api.pwnedpasswords.com"
+		}, []string{"PASSWORD_BREACH_API_URL", "PASSWORD_BREACH_TIMEOUT"}},
+		{"max password length below min", func(c *ProductionConfig) { c.Security.PasswordMaxLength = 6 }, []string{"PASSWORD_MAX_LENGTH"}},
+		{"v1 sunset before deprecation", func(c *ProductionConfig) {
+			c.Server.V1DeprecatedAt = time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)
+			c.Server.V1SunsetAt = c.Server.V1DeprecatedAt.AddDate(0, 0, -1)
+		}, []string{"API_V1_SUNSET_AT"}},
+		{"domain with scheme", func(c *ProductionConfig) { c.Deployment.Domain = "https://jaazebeh.ir" }, []string{"DOMAIN"}},
+		{"idle above open connections", func(c *ProductionConfig) { c.Database.MaxIdleConns = 50 }, []string{"DB_MAX_IDLE_CONNS"}},
+		{"unknown migration check", func(c *ProductionConfig) { c.Database.MigrationCheck = "strict" }, []string{"DB_MIGRATION_CHECK"}},
+		{"vault needs an address, a secret path and a login", func(c *ProductionConfig) {
+			c.Secrets = SecretsConfig{Provider: SecretsProviderVault, CacheTTL: time.Minute, Vault: VaultConfig{AuthMethod: VaultAuthAppRole, KVMount: "secret", Timeout: time.Second}}
+		}, []string{"VAULT_ADDR", "VAULT_SECRET_PATH", "VAULT_ROLE_ID", "VAULT_SECRET_ID"}},
+		{"unknown secrets provider", func(c *ProductionConfig) { c.Secrets.Provider = "keychain" }, []string{"SECRETS_PROVIDER"}},
+		{"nowpayments as default needs an API key", func(c *ProductionConfig) { c.Crypto.DefaultPlatform = "nowpayments" },
+			[]string{"NOWPAYMENTS_API_KEY"}},
+		{"nowpayments needs an IPN secret", func(c *ProductionConfig) {
+			c.Crypto.NowPayments = NowPaymentsConfig{BaseURL: "https://api.nowpayments.io/v1", APIKey: "key"}
+		}, []string{"NOWPAYMENTS_IPN_SECRET"}},
+		{"telegram bot without a username or webhook secret", func(c *ProductionConfig) {
+			c.Telegram = TelegramConfig{BotToken: "123:abc", BaseURL: "https://api.telegram.org", LinkTTL: time.Minute, Timeout: time.Second}
+		}, []string{"TELEGRAM_BOT_USERNAME", "TELEGRAM_WEBHOOK_SECRET"}},
+		{"push with a bad topic and relative URLs", func(c *ProductionConfig) {
+			c.FCM = FCMConfig{CredentialsFile: "/run/secrets/fcm.json", BaseURL: "fcm.googleapis.com", IIDBaseURL: "https://iid.googleapis.com",
+				BroadcastTopic: "all customers", Timeout: time.Second}
+		}, []string{"FCM_BASE_URL", "FCM_BROADCAST_TOPIC"}},
+		{"s3 storage without credentials and a clamav address without a port", func(c *ProductionConfig) {
+			c.Storage = StorageConfig{Driver: StorageDriverS3, Endpoint: "http://minio:9000", Bucket: "uploads", Timeout: time.Second,
+				SignedURLTTL: 8 * 24 * time.Hour}
+			c.Uploads = UploadsConfig{ClamAVAddress: "clamav", ClamAVTimeout: time.Second}
+		}, []string{"STORAGE_ACCESS_KEY", "STORAGE_SECRET_KEY", "STORAGE_SIGNED_URL_TTL", "UPLOADS_CLAMAV_ADDRESS"}},
+		{"local storage with a short signing key and an empty lifecycle prefix", func(c *ProductionConfig) {
+			c.Storage = StorageConfig{Driver: StorageDriverLocal, LocalDir: "data/storage", LocalBaseURL: "/api/v1/files",
+				LocalSigningKey: "short", SignedURLTTL: time.Minute, Lifecycle: map[string]time.Duration{"/": time.Hour}}
+		}, []string{"STORAGE_LOCAL_BASE_URL", "STORAGE_LOCAL_SIGNING_KEY", "STORAGE_LIFECYCLE"}},
+		{"unknown storage driver", func(c *ProductionConfig) {
+			c.Storage = StorageConfig{Driver: "gcs", SignedURLTTL: time.Minute}
+		}, []string{"STORAGE_DRIVER"}},
+		{"unverified daily cap above the monthly cap", func(c *ProductionConfig) {
+			c.KYC = KYCConfig{UnverifiedDailyLimit: 5000, UnverifiedMonthlyLimit: 1000}
+		}, []string{"KYC_UNVERIFIED_DAILY_LIMIT"}},
+		{"sheba inquiry without a secret key", func(c *ProductionConfig) {
+			c.ShebaInquiry = ShebaInquiryConfig{BaseURL: "napi.jibit.ir/ide", APIKey: "key", CacheTTL: time.Hour, Timeout: time.Second}
+		}, []string{"SHEBA_INQUIRY_SECRET_KEY", "SHEBA_INQUIRY_BASE_URL"}},
+		{"payment tolerance of the whole amount", func(c *ProductionConfig) { c.Crypto.PaymentToleranceBPS = 10000 },
+			[]string{"CRYPTO_PAYMENT_TOLERANCE_BPS"}},
+		{"crypto webhooks without a max age", func(c *ProductionConfig) { c.Crypto.WebhookMaxAge = 0 },
+			[]string{"CRYPTO_WEBHOOK_MAX_AGE"}},
+		{"crypto expiry without an interval", func(c *ProductionConfig) { c.Scheduler.CryptoExpiryEnabled = true },
+			[]string{"CRYPTO_EXPIRY_INTERVAL"}},
+		{"payment expiry without an interval", func(c *ProductionConfig) { c.Scheduler.PaymentExpiryEnabled = true },
+			[]string{"PAYMENT_EXPIRY_INTERVAL"}},
+		{"atipay reconciliation without a report URL", func(c *ProductionConfig) { c.Scheduler.AtipayReconciliationEnabled = true },
+			[]string{"ATIPAY_RECONCILIATION_INTERVAL", "ATIPAY_SETTLEMENT_REPORT_URL"}},
+		{"postpaid invoicing without an interval", func(c *ProductionConfig) {
+			c.Scheduler.PostpaidInvoicingEnabled = true
+			c.Scheduler.PostpaidInvoiceDueDays = 0
+		}, []string{"POSTPAID_INVOICING_INTERVAL", "POSTPAID_INVOICE_DUE_DAYS"}},
+		{"campaign expiry without an interval", func(c *ProductionConfig) { c.Scheduler.CampaignExpiryEnabled = true },
+			[]string{"CAMPAIGN_EXPIRY_INTERVAL"}},
+		{"job queue without workers", func(c *ProductionConfig) {
+			c.JobQueue = JobQueueConfig{Enabled: true, PollInterval: time.Second, MaxAttempts: 5, BackoffBase: time.Minute, BackoffMax: time.Second, Retention: time.Hour}
+		}, []string{"JOB_QUEUE_WORKERS", "JOB_QUEUE_BACKOFF_MAX"}},
+		{"outbound http retries without a backoff and a zero host timeout", func(c *ProductionConfig) {
+			c.OutboundHTTP.RetryBackoff = 0
+			c.OutboundHTTP.HostTimeouts = map[string]time.Duration{"api.atipay.net": 0}
+		}, []string{"OUTBOUND_HTTP_HOST_TIMEOUTS", "OUTBOUND_HTTP_RETRY_BACKOFF"}},
+		{"worker leader election without a retry interval", func(c *ProductionConfig) { c.Scheduler.LeaderRetryInterval = 0 },
+			[]string{"SCHEDULER_LEADER_RETRY_INTERVAL"}},
+		{"invoice numbers with a lowercase prefix", func(c *ProductionConfig) {
+			c.Invoice.LegalEntityCode = "jzb-1"
+			c.Invoice.FontPath = ""
+		}, []string{"INVOICE_LEGAL_ENTITY_CODE", "INVOICE_FONT_PATH"}},
+		{"moadian enabled without its identity", func(c *ProductionConfig) {
+			c.Moadian = MoadianConfig{Enabled: true, BaseURL: "https://tp.tax.gov.ir/requestsmanager/api/v1", MemoryID: "a1b2c3",
+				ServiceID: "2330001234567", Timeout: time.Second, Interval: time.Minute, BatchSize: 10, MaxAttempts: 3}
+		}, []string{"MOADIAN_MEMORY_ID", "MOADIAN_PRIVATE_KEY_PATH", "MOADIAN_CERTIFICATE_PATH", "INVOICE_SELLER_ECONOMIC_CODE"}},
+		{"wallet transfers with a maximum below the minimum", func(c *ProductionConfig) {
+			c.WalletTransfer = WalletTransferConfig{Enabled: true, MinAmount: 100000, MaxAmount: 50000, DailyLimit: 10000}
+		}, []string{"WALLET_TRANSFER_MAX_AMOUNT", "WALLET_TRANSFER_DAILY_LIMIT"}},
+		{"relative atipay settlement report URL", func(c *ProductionConfig) { c.Atipay.SettlementReportURL = "/reports/{date}" },
+			[]string{"ATIPAY_SETTLEMENT_REPORT_URL"}},
+		{"atipay terminal without an API key and an unknown routing", func(c *ProductionConfig) {
+			c.Atipay.Terminals = "T2:key2:5000000,T3"
+			c.Atipay.Routing = "random"
+		}, []string{"ATIPAY_TERMINALS", "ATIPAY_ROUTING"}},
+		{"more rate sources required than configured", func(c *ProductionConfig) {
+			c.Crypto.Rates.Sources = []string{"wallex", "coingecko"}
+			c.Crypto.Rates.MinSources = 3
+		}, []string{"CRYPTO_RATE_SOURCES", "CRYPTO_RATE_MIN_SOURCES"}},
+		{"provider mocked in production", func(c *ProductionConfig) {
+			c.Deployment.Environment = "production"
+			c.Mocks.Atipay.Enabled = true
+		}, []string{"APP_ENV"}},
+		{"mock failure rate out of range", func(c *ProductionConfig) {
+			c.Deployment.Environment = "development"
+			c.Mocks.Bot = ProviderMockConfig{Enabled: true, Latency: -time.Second, FailureRate: 1.5}
+		}, []string{"MOCK_BOT_LATENCY", "MOCK_BOT_FAILURE_RATE"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := validConfig()
+			tc.mutate(cfg)
+			if got := validationKeys(t, cfg); !slices.Equal(got, tc.want) {
+				t.Fatalf("keys = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestLoadProductionConfigReportsMalformedValues(t *testing.T) {
+	t.Setenv("DB_PORT", "five-four-three-two")
+	t.Setenv("CACHE_ENABLED", "maybe")
+	t.Setenv("SERVER_READ_TIMEOUT", "10")
+
+	_, err := LoadProductionConfig()
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("LoadProductionConfig() error = %v, want *ValidationError", err)
+	}
+	for _, key := range []string{"DB_PORT", "CACHE_ENABLED", "SERVER_READ_TIMEOUT"} {
+		if !slices.Contains(verr.Keys(), key) {
+			t.Errorf("malformed %s not reported in %v", key, verr.Keys())
+		}
+	}
+	if verr.Problems[0].Section != "environment" {
+		t.Errorf("first problem section = %q, want environment", verr.Problems[0].Section)
+	}
+}
@@ -0,0 +1,287 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Vault auth methods selectable with VAULT_AUTH_METHOD
+const (
+	VaultAuthToken      = "token"
+	VaultAuthAppRole    = "approle"
+	VaultAuthKubernetes = "kubernetes"
+)
+
+// vaultRenewRetry is how long to wait before retrying a failed renewal
+const vaultRenewRetry = 30 * time.Second
+
+// vaultClient reads credentials from a Vault KV v2 secret over Vault's HTTP
+// API, logging in with a token, AppRole or a Kubernetes service account
+type vaultClient struct {
+	cfg  VaultConfig
+	http *http.Client
+
+	mu        sync.Mutex
+	token     string
+	renewable bool
+	renewAt   time.Time // zero when the token does not expire
+	expiresAt time.Time
+}
+
+func newVaultClient(cfg VaultConfig) *vaultClient {
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	return &vaultClient{cfg: cfg, http: &http.Client{Timeout: timeout}}
+}
+
+// vaultAuth is the auth block of login and renewal responses
+type vaultAuth struct {
+	ClientToken   string `json:"client_token"`
+	LeaseDuration int    `json:"lease_duration"`
+	Renewable     bool   `json:"renewable"`
+}
+
+// vaultError is a non-2xx response from Vault
+type vaultError struct {
+	status int
+	errors []string
+}
+
+func (e *vaultError) Error() string {
+	if len(e.errors) == 0 {
+		return fmt.Sprintf("vault returned status %d", e.status)
+	}
+	return fmt.Sprintf("vault returned status %d: %s", e.status, strings.Join(e.errors, "; "))
+}
+
+func (v *vaultClient) fetch(ctx context.Context, keys []string) (map[string]string, error) {
+	token, err := v.currentToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Data struct {
+			Data map[string]any `json:"data"`
+		} `json:"data"`
+	}
+	path := "/v1/" + strings.Trim(v.cfg.KVMount, "/") + "/data/" + strings.Trim(v.cfg.SecretPath, "/")
+	err = v.do(ctx, http.MethodGet, path, token, nil, &resp)
+	var verr *vaultError
+	if errors.As(err, &verr) && verr.status == http.StatusForbidden && v.cfg.AuthMethod != VaultAuthToken {
+		// The token was revoked or expired early; log in again once
+		if token, err = v.login(ctx); err != nil {
+			return nil, err
+		}
+		err = v.do(ctx, http.MethodGet, path, token, nil, &resp)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	values := map[string]string{}
+	for _, key := range keys {
+		if value, ok := resp.Data.Data[key]; ok && value != nil {
+			values[key] = fmt.Sprint(value)
+		}
+	}
+	return values, nil
+}
+
+// currentToken returns the token, logging in first if there is none or it
+// has expired
+func (v *vaultClient) currentToken(ctx context.Context) (string, error) {
+	v.mu.Lock()
+	token, expiresAt := v.token, v.expiresAt
+	v.mu.Unlock()
+	if token != "" && (expiresAt.IsZero() || time.Now().Before(expiresAt)) {
+		return token, nil
+	}
+	return v.login(ctx)
+}
+
+// login obtains a token with the configured auth method
+func (v *vaultClient) login(ctx context.Context) (string, error) {
+	var auth vaultAuth
+	switch v.cfg.AuthMethod {
+	case VaultAuthAppRole:
+		var resp struct {
+			Auth vaultAuth `json:"auth"`
+		}
+		body := map[string]string{"role_id": v.cfg.RoleID, "secret_id": v.cfg.SecretID}
+		if err := v.do(ctx, http.MethodPost, v.loginPath(), "", body, &resp); err != nil {
+			return "", fmt.Errorf("vault approle login failed: %w", err)
+		}
+		auth = resp.Auth
+	case VaultAuthKubernetes:
+		jwt, err := os.ReadFile(v.cfg.KubernetesTokenPath)
+		if err != nil {
+			return "", fmt.Errorf("failed to read service account token: %w", err)
+		}
+		var resp struct {
+			Auth vaultAuth `json:"auth"`
+		}
+		body := map[string]string{"role": v.cfg.Role, "jwt": strings.TrimSpace(string(jwt))}
+		if err := v.do(ctx, http.MethodPost, v.loginPath(), "", body, &resp); err != nil {
+			return "", fmt.Errorf("vault kubernetes login failed: %w", err)
+		}
+		auth = resp.Auth
+	default:
+		// A static token: look up its TTL so it can be renewed
+		var resp struct {
+			Data struct {
+				TTL       int  `json:"ttl"`
+				Renewable bool `json:"renewable"`
+			} `json:"data"`
+		}
+		if err := v.do(ctx, http.MethodGet, "/v1/auth/token/lookup-self", v.cfg.Token, nil, &resp); err != nil {
+			return "", fmt.Errorf("vault token lookup failed: %w", err)
+		}
+		auth = vaultAuth{ClientToken: v.cfg.Token, LeaseDuration: resp.Data.TTL, Renewable: resp.Data.Renewable}
+	}
+	if auth.ClientToken == "" {
+		return "", fmt.Errorf("vault login returned no token")
+	}
+	v.setToken(auth)
+	return auth.ClientToken, nil
+}
+
+func (v *vaultClient) loginPath() string {
+	mount := v.cfg.AuthMount
+	if mount == "" {
+		mount = v.cfg.AuthMethod
+	}
+	return "/v1/auth/" + strings.Trim(mount, "/") + "/login"
+}
+
+// setToken stores a token and schedules its renewal at two thirds of its
+// lease
+func (v *vaultClient) setToken(auth vaultAuth) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.token = auth.ClientToken
+	v.renewable = auth.Renewable
+	if auth.LeaseDuration <= 0 {
+		v.renewAt, v.expiresAt = time.Time{}, time.Time{}
+		return
+	}
+	lease := time.Duration(auth.LeaseDuration) * time.Second
+	now := time.Now()
+	v.renewAt = now.Add(lease * 2 / 3)
+	v.expiresAt = now.Add(lease)
+}
+
+func (v *vaultClient) renewIn() (time.Duration, bool) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.renewAt.IsZero() {
+		return 0, false
+	}
+	return time.Until(v.renewAt), true
+}
+
+// renew extends the token's lease, or logs in again when it can not be
+// extended, e.g. at its max TTL
+func (v *vaultClient) renew(ctx context.Context) error {
+	v.mu.Lock()
+	token, renewable := v.token, v.renewable
+	v.mu.Unlock()
+
+	if token != "" && renewable {
+		var resp struct {
+			Auth vaultAuth `json:"auth"`
+		}
+		err := v.do(ctx, http.MethodPost, "/v1/auth/token/renew-self", token, map[string]any{}, &resp)
+		// A lease shorter than a minute means the max TTL is close
+		if err == nil && resp.Auth.LeaseDuration >= 60 {
+			resp.Auth.ClientToken = token
+			v.setToken(resp.Auth)
+			return nil
+		}
+		if err != nil && v.cfg.AuthMethod == VaultAuthToken {
+			v.retryRenewalLater()
+			return fmt.Errorf("vault token renewal failed: %w", err)
+		}
+	}
+	if v.cfg.AuthMethod == VaultAuthToken {
+		// A static token that can not be renewed runs out; nothing to do
+		v.mu.Lock()
+		v.renewAt = time.Time{}
+		expiresAt := v.expiresAt
+		v.mu.Unlock()
+		return fmt.Errorf("vault token can not be renewed and expires at %s", expiresAt.Format(time.RFC3339))
+	}
+	if _, err := v.login(ctx); err != nil {
+		v.retryRenewalLater()
+		return err
+	}
+	return nil
+}
+
+func (v *vaultClient) retryRenewalLater() {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.renewAt = time.Now().Add(vaultRenewRetry)
+}
+
+// do sends a request to Vault and decodes the JSON response into out
+func (v *vaultClient) do(ctx context.Context, method, path, token string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	endpoint, err := url.JoinPath(v.cfg.Address, path)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("X-Vault-Token", token)
+	}
+	if v.cfg.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", v.cfg.Namespace)
+	}
+
+	resp, err := v.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var errResp struct {
+			Errors []string `json:"errors"`
+		}
+		_ = json.Unmarshal(data, &errResp)
+		return &vaultError{status: resp.StatusCode, errors: errResp.Errors}
+	}
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
@@ -0,0 +1,96 @@
+package listquery
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// FieldSet is a sparse fieldset: the JSON fields kept in every item of a
+// listing. A nil FieldSet keeps every field.
+type FieldSet []string
+
+// ParseFieldSet validates a comma separated list of fields against the JSON
+// fields of item, a value of the listing's item type. It returns nil when raw
+// is empty.
+func ParseFieldSet(raw string, item any) (FieldSet, error) {
+	known := jsonFields(reflect.TypeOf(item))
+	var set FieldSet
+	for name := range strings.SplitSeq(raw, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if !known[name] {
+			return nil, fmt.Errorf("%w: unknown field %q", ErrInvalid, name)
+		}
+		set = append(set, name)
+	}
+	return set, nil
+}
+
+// Project returns res with every element of its "items" array reduced to the
+// fields of s. res is returned unchanged when s is nil.
+func (s FieldSet) Project(res any) (any, error) {
+	if s == nil {
+		return res, nil
+	}
+	raw, err := json.Marshal(res)
+	if err != nil {
+		return nil, err
+	}
+	// Numbers are kept as written so large amounts do not lose precision
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var out map[string]any
+	if err := dec.Decode(&out); err != nil {
+		return nil, err
+	}
+	items, _ := out["items"].([]any)
+	for i, item := range items {
+		fields, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		kept := make(map[string]any, len(s))
+		for _, name := range s {
+			if v, ok := fields[name]; ok {
+				kept[name] = v
+			}
+		}
+		items[i] = kept
+	}
+	return out, nil
+}
+
+// jsonFields returns the JSON field names of a struct type, including those
+// of embedded structs
+func jsonFields(t reflect.Type) map[string]bool {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	fields := make(map[string]bool)
+	if t == nil || t.Kind() != reflect.Struct {
+		return fields
+	}
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		name, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+		if name == "-" || (!sf.IsExported() && !sf.Anonymous) {
+			continue
+		}
+		if sf.Anonymous && name == "" {
+			for embedded := range jsonFields(sf.Type) {
+				fields[embedded] = true
+			}
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		fields[name] = true
+	}
+	return fields
+}
@@ -0,0 +1,206 @@
+// Package listquery parses the filter and sort expressions and the sparse
+// fieldsets of admin list endpoints.
+//
+// A filter is a list of conditions separated by semicolons, each written as
+// field:operator:value, e.g.
+//
+//	status:in:approved,running;created_at:gte:2026-01-01T00:00:00Z;title:contains:nowruz
+//
+// The operators are eq, ne, gt, gte, lt, lte, in and nin (comma separated
+// values), contains (case-insensitive substring) and null (true or false). A
+// sort is a comma separated list of fields, each descending when prefixed
+// with a minus, e.g. -created_at,id. A fieldset is a comma separated list of
+// the JSON fields to keep in every listed item, e.g. id,status,created_at.
+package listquery
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalid = errors.New("invalid list query")
+
+// Operators of filter conditions
+const (
+	OpEq       = "eq"
+	OpNe       = "ne"
+	OpGt       = "gt"
+	OpGte      = "gte"
+	OpLt       = "lt"
+	OpLte      = "lte"
+	OpIn       = "in"
+	OpNin      = "nin"
+	OpContains = "contains"
+	OpNull     = "null"
+)
+
+const (
+	// maxConditions and maxSorts bound the work a single request can ask for
+	maxConditions = 10
+	maxSorts      = 3
+	maxValues     = 100
+)
+
+// Kind is how the values of a field are parsed
+type Kind int
+
+const (
+	KindString Kind = iota
+	KindInt
+	KindTime
+	KindBool
+)
+
+// Field is a field of a listing that can be filtered and sorted
+type Field struct {
+	// Column is the SQL expression the field reads. It is trusted and must
+	// be qualified when the listing query joins other tables.
+	Column string
+	Kind   Kind
+	// Values restricts a string field to an enumeration
+	Values []string
+	// Nullable allows the null operator
+	Nullable bool
+	// NoSort leaves out expressions that cannot use an index
+	NoSort bool
+}
+
+// Fields are the fields of a listing by their public name
+type Fields map[string]Field
+
+// Condition is a parsed filter condition on a column
+type Condition struct {
+	Column   string
+	Operator string
+	// Values are typed by the field kind: string, int64, time.Time or bool.
+	// eq to lte and contains have one value, null has one bool.
+	Values []any
+}
+
+// Sort is a parsed sort on a column
+type Sort struct {
+	Column string
+	Desc   bool
+}
+
+// Query is a parsed filter and sort of a listing
+type Query struct {
+	Conditions []Condition
+	Sorts      []Sort
+}
+
+// Empty reports whether q neither filters nor sorts
+func (q *Query) Empty() bool {
+	return q == nil || (len(q.Conditions) == 0 && len(q.Sorts) == 0)
+}
+
+// Parse validates a filter and a sort against the fields of a listing. It
+// returns nil when both are empty.
+func (f Fields) Parse(filter, sort string) (*Query, error) {
+	q := &Query{}
+	for raw := range strings.SplitSeq(filter, ";") {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+		if len(q.Conditions) == maxConditions {
+			return nil, fmt.Errorf("%w: at most %d filter conditions are allowed", ErrInvalid, maxConditions)
+		}
+		cond, err := f.parseCondition(raw)
+		if err != nil {
+			return nil, err
+		}
+		q.Conditions = append(q.Conditions, cond)
+	}
+	for raw := range strings.SplitSeq(sort, ",") {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+		if len(q.Sorts) == maxSorts {
+			return nil, fmt.Errorf("%w: at most %d sort fields are allowed", ErrInvalid, maxSorts)
+		}
+		name, desc := strings.CutPrefix(raw, "-")
+		field, ok := f[name]
+		if !ok || field.NoSort {
+			return nil, fmt.Errorf("%w: cannot sort by %q", ErrInvalid, name)
+		}
+		q.Sorts = append(q.Sorts, Sort{Column: field.Column, Desc: desc})
+	}
+	if q.Empty() {
+		return nil, nil
+	}
+	return q, nil
+}
+
+func (f Fields) parseCondition(raw string) (Condition, error) {
+	parts := strings.SplitN(raw, ":", 3)
+	if len(parts) != 3 {
+		return Condition{}, fmt.Errorf("%w: condition %q is not field:operator:value", ErrInvalid, raw)
+	}
+	name, op, value := parts[0], parts[1], parts[2]
+	field, ok := f[name]
+	if !ok {
+		return Condition{}, fmt.Errorf("%w: cannot filter by %q", ErrInvalid, name)
+	}
+
+	var raws []string
+	switch op {
+	case OpEq, OpNe, OpGt, OpGte, OpLt, OpLte:
+		if field.Kind == KindBool && op != OpEq && op != OpNe {
+			return Condition{}, fmt.Errorf("%w: %s supports eq and ne only", ErrInvalid, name)
+		}
+		raws = []string{value}
+	case OpIn, OpNin:
+		raws = strings.Split(value, ",")
+		if len(raws) > maxValues {
+			return Condition{}, fmt.Errorf("%w: at most %d values are allowed for %s", ErrInvalid, maxValues, op)
+		}
+	case OpContains:
+		if field.Kind != KindString || field.Values != nil {
+			return Condition{}, fmt.Errorf("%w: %s does not support contains", ErrInvalid, name)
+		}
+		raws = []string{value}
+	case OpNull:
+		if !field.Nullable {
+			return Condition{}, fmt.Errorf("%w: %s is never null", ErrInvalid, name)
+		}
+		isNull, err := strconv.ParseBool(value)
+		if err != nil {
+			return Condition{}, fmt.Errorf("%w: null takes true or false", ErrInvalid)
+		}
+		return Condition{Column: field.Column, Operator: op, Values: []any{isNull}}, nil
+	default:
+		return Condition{}, fmt.Errorf("%w: unknown operator %q", ErrInvalid, op)
+	}
+
+	values := make([]any, 0, len(raws))
+	for _, v := range raws {
+		parsed, err := field.parseValue(strings.TrimSpace(v))
+		if err != nil {
+			return Condition{}, fmt.Errorf("%w: %s: %v", ErrInvalid, name, err)
+		}
+		values = append(values, parsed)
+	}
+	return Condition{Column: field.Column, Operator: op, Values: values}, nil
+}
+
+func (field Field) parseValue(v string) (any, error) {
+	switch field.Kind {
+	case KindInt:
+		return strconv.ParseInt(v, 10, 64)
+	case KindTime:
+		return time.Parse(time.RFC3339, v)
+	case KindBool:
+		return strconv.ParseBool(v)
+	default:
+		if field.Values != nil && !slices.Contains(field.Values, v) {
+			return nil, fmt.Errorf("%q is not one of %s", v, strings.Join(field.Values, ", "))
+		}
+		return v, nil
+	}
+}
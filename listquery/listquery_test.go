@@ -0,0 +1,111 @@
+package listquery
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var testFields = Fields{
+	"id":         {Column: "t.id", Kind: KindInt},
+	"status":     {Column: "t.status", Values: []string{"active", "closed"}},
+	"title":      {Column: "t.title"},
+	"paid":       {Column: "t.paid", Kind: KindBool},
+	"created_at": {Column: "t.created_at", Kind: KindTime},
+	"closed_at":  {Column: "t.closed_at", Kind: KindTime, Nullable: true},
+	"score":      {Column: "t.a + t.b", Kind: KindInt, NoSort: true},
+}
+
+func TestFieldsParse(t *testing.T) {
+	t.Parallel()
+
+	q, err := testFields.Parse("status:in:active, closed;created_at:gte:2026-10-01T00:00:00Z;title:contains:nowruz;closed_at:null:true", "-created_at,id")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	want := &Query{
+		Conditions: []Condition{
+			{Column: "t.status", Operator: OpIn, Values: []any{"active", "closed"}},
+			{Column: "t.created_at", Operator: OpGte, Values: []any{time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)}},
+			{Column: "t.title", Operator: OpContains, Values: []any{"nowruz"}},
+			{Column: "t.closed_at", Operator: OpNull, Values: []any{true}},
+		},
+		Sorts: []Sort{{Column: "t.created_at", Desc: true}, {Column: "t.id"}},
+	}
+	if !reflect.DeepEqual(q, want) {
+		t.Fatalf("got %+v, want %+v", q, want)
+	}
+
+	if q, err := testFields.Parse(" ; ", ""); err != nil || q != nil {
+		t.Fatalf("empty query: got %+v, %v", q, err)
+	}
+}
+
+func TestFieldsParseRejects(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct{ filter, sort string }{
+		{filter: "status"},
+		{filter: "owner:eq:1"},
+		{filter: "id:like:1"},
+		{filter: "id:eq:one"},
+		{filter: "status:eq:deleted"},
+		{filter: "status:contains:act"},
+		{filter: "paid:gt:true"},
+		{filter: "title:null:true"},
+		{filter: "closed_at:null:maybe"},
+		{filter: "created_at:lt:yesterday"},
+		{sort: "-owner"},
+		{sort: "score"},
+		{sort: "id,title,status,created_at"},
+	}
+	for _, c := range cases {
+		if _, err := testFields.Parse(c.filter, c.sort); !errors.Is(err, ErrInvalid) {
+			t.Errorf("Parse(%q, %q): got %v, want ErrInvalid", c.filter, c.sort, err)
+		}
+	}
+}
+
+type testBase struct {
+	ID uint `json:"id"`
+}
+
+type testItem struct {
+	testBase
+	Status string  `json:"status"`
+	Note   *string `json:"note,omitempty"`
+	Secret string  `json:"-"`
+}
+
+func TestFieldSet(t *testing.T) {
+	t.Parallel()
+
+	set, err := ParseFieldSet("id, status", testItem{})
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if _, err := ParseFieldSet("id,secret", testItem{}); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("secret field: got %v, want ErrInvalid", err)
+	}
+	if set, err := ParseFieldSet("", testItem{}); err != nil || set != nil {
+		t.Fatalf("empty fieldset: got %v, %v", set, err)
+	}
+
+	res := struct {
+		Message string     `json:"message"`
+		Items   []testItem `json:"items"`
+	}{Message: "ok", Items: []testItem{{testBase: testBase{ID: 1}, Status: "active", Secret: "x"}}}
+	got, err := set.Project(res)
+	if err != nil {
+		t.Fatalf("project: %v", err)
+	}
+	want := map[string]any{
+		"message": "ok",
+		"items":   []any{map[string]any{"id": json.Number("1"), "status": "active"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %#v, want %#v", got, want)
+	}
+}
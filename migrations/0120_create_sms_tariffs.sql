@@ -0,0 +1,34 @@
+-- Migration: 0120_create_sms_tariffs.sql
+-- Description: Create sms_tariffs table and add operator/tier to line_numbers for tariff selection
+
+BEGIN;
+
+ALTER TABLE line_numbers ADD COLUMN IF NOT EXISTS operator VARCHAR(20);
+ALTER TABLE line_numbers ADD COLUMN IF NOT EXISTS tier VARCHAR(50);
+
+CREATE TABLE IF NOT EXISTS sms_tariffs (
+    id SERIAL PRIMARY KEY,
+    uuid UUID NOT NULL UNIQUE DEFAULT gen_random_uuid(),
+
+    -- NULL dimensions match any value; the most specific active row wins
+    operator VARCHAR(20),
+    line_number_tier VARCHAR(50),
+    account_type VARCHAR(50),
+    part_count INTEGER CHECK (part_count IS NULL OR part_count > 0),
+    price_per_part BIGINT NOT NULL CHECK (price_per_part > 0),
+    description TEXT,
+
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uk_sms_tariffs_dimensions ON sms_tariffs (
+    COALESCE(operator, ''),
+    COALESCE(line_number_tier, ''),
+    COALESCE(account_type, ''),
+    COALESCE(part_count, 0)
+);
+CREATE INDEX IF NOT EXISTS idx_sms_tariffs_is_active ON sms_tariffs(is_active);
+
+COMMIT;
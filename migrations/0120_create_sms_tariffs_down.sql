@@ -0,0 +1,8 @@
+-- Migration: 0120_create_sms_tariffs_down.sql
+-- Description: Drop sms_tariffs table and line_numbers operator/tier columns
+
+BEGIN;
+DROP TABLE IF EXISTS sms_tariffs;
+ALTER TABLE line_numbers DROP COLUMN IF EXISTS tier;
+ALTER TABLE line_numbers DROP COLUMN IF EXISTS operator;
+COMMIT;
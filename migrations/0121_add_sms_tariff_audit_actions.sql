@@ -0,0 +1,6 @@
+-- Description: Add audit_action_enum values for admin SMS tariff operations
+
+ALTER TYPE audit_action_enum ADD VALUE IF NOT EXISTS 'admin_sms_tariff_create';
+ALTER TYPE audit_action_enum ADD VALUE IF NOT EXISTS 'admin_sms_tariff_list';
+ALTER TYPE audit_action_enum ADD VALUE IF NOT EXISTS 'admin_sms_tariff_update';
+ALTER TYPE audit_action_enum ADD VALUE IF NOT EXISTS 'admin_sms_tariff_delete';
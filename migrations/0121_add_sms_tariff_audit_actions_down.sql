@@ -0,0 +1,3 @@
+-- Description: Down migration for SMS tariff audit actions
+
+-- PostgreSQL enum values cannot be removed safely in a reversible migration.
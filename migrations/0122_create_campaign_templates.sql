@@ -0,0 +1,27 @@
+-- Migration: 0122_create_campaign_templates.sql
+-- Description: Create campaign_templates table for customer and admin-published global templates
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS campaign_templates (
+    id SERIAL PRIMARY KEY,
+    uuid UUID NOT NULL UNIQUE DEFAULT gen_random_uuid(),
+
+    -- NULL customer_id marks a global template published by an admin
+    customer_id INTEGER REFERENCES customers(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    spec JSONB NOT NULL DEFAULT '{}'::jsonb,
+    published_by_admin_id INTEGER REFERENCES admins(id) ON DELETE SET NULL,
+
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT chk_campaign_templates_name_not_blank CHECK (btrim(name) <> '')
+);
+
+CREATE INDEX IF NOT EXISTS idx_campaign_templates_customer_id ON campaign_templates(customer_id);
+CREATE INDEX IF NOT EXISTS idx_campaign_templates_created_at ON campaign_templates(created_at);
+CREATE UNIQUE INDEX IF NOT EXISTS uk_campaign_templates_customer_name
+    ON campaign_templates (COALESCE(customer_id, 0), lower(name));
+
+COMMIT;
@@ -0,0 +1,6 @@
+-- Migration: 0122_create_campaign_templates_down.sql
+-- Description: Drop campaign_templates table
+
+BEGIN;
+DROP TABLE IF EXISTS campaign_templates;
+COMMIT;
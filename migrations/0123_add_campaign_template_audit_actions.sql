@@ -0,0 +1,6 @@
+-- Description: Add audit_action_enum values for campaign template operations
+
+ALTER TYPE audit_action_enum ADD VALUE IF NOT EXISTS 'campaign_template_created';
+ALTER TYPE audit_action_enum ADD VALUE IF NOT EXISTS 'campaign_template_deleted';
+ALTER TYPE audit_action_enum ADD VALUE IF NOT EXISTS 'admin_campaign_template_publish';
+ALTER TYPE audit_action_enum ADD VALUE IF NOT EXISTS 'admin_campaign_template_delete';
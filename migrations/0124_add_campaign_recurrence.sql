@@ -0,0 +1,21 @@
+-- Migration: 0124_add_campaign_recurrence.sql
+-- Description: Track recurring campaign series (parent link, occurrence number and next occurrence time)
+
+BEGIN;
+
+ALTER TABLE campaigns
+    ADD COLUMN IF NOT EXISTS parent_campaign_id INTEGER NULL REFERENCES campaigns(id),
+    ADD COLUMN IF NOT EXISTS occurrence_number INTEGER NULL,
+    ADD COLUMN IF NOT EXISTS next_occurrence_at TIMESTAMP WITH TIME ZONE NULL;
+
+CREATE INDEX IF NOT EXISTS idx_campaigns_parent_campaign_id ON campaigns(parent_campaign_id);
+CREATE INDEX IF NOT EXISTS idx_campaigns_next_occurrence_at
+    ON campaigns(next_occurrence_at)
+    WHERE next_occurrence_at IS NOT NULL;
+
+-- An occurrence is materialized at most once per series
+CREATE UNIQUE INDEX IF NOT EXISTS uk_campaigns_parent_occurrence
+    ON campaigns(parent_campaign_id, occurrence_number)
+    WHERE parent_campaign_id IS NOT NULL;
+
+COMMIT;
@@ -0,0 +1,15 @@
+-- Migration: 0124_add_campaign_recurrence_down.sql
+-- Description: Remove recurring campaign series columns
+
+BEGIN;
+
+DROP INDEX IF EXISTS uk_campaigns_parent_occurrence;
+DROP INDEX IF EXISTS idx_campaigns_next_occurrence_at;
+DROP INDEX IF EXISTS idx_campaigns_parent_campaign_id;
+
+ALTER TABLE campaigns
+    DROP COLUMN IF EXISTS next_occurrence_at,
+    DROP COLUMN IF EXISTS occurrence_number,
+    DROP COLUMN IF EXISTS parent_campaign_id;
+
+COMMIT;
@@ -0,0 +1,5 @@
+-- Description: Add audit_action_enum values for recurring campaign occurrences
+
+ALTER TYPE audit_action_enum ADD VALUE IF NOT EXISTS 'campaign_occurrence_created';
+ALTER TYPE audit_action_enum ADD VALUE IF NOT EXISTS 'campaign_occurrence_failed';
+ALTER TYPE audit_action_enum ADD VALUE IF NOT EXISTS 'campaign_recurrence_ended';
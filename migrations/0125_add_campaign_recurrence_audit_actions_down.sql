@@ -0,0 +1,3 @@
+-- Description: Down migration for recurring campaign audit actions
+
+-- PostgreSQL enum values cannot be removed safely in a reversible migration.
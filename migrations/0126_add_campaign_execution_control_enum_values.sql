@@ -0,0 +1,18 @@
+-- Migration: 0126_add_campaign_execution_control_enum_values.sql
+-- Description: Add 'paused' campaign status and audit actions for pausing, resuming and stopping a running campaign
+
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1
+        FROM pg_type t
+        JOIN pg_enum e ON t.oid = e.enumtypid
+        WHERE t.typname = 'sms_campaign_status' AND e.enumlabel = 'paused'
+    ) THEN
+        ALTER TYPE sms_campaign_status ADD VALUE 'paused';
+    END IF;
+END$$;
+
+ALTER TYPE audit_action_enum ADD VALUE IF NOT EXISTS 'campaign_paused';
+ALTER TYPE audit_action_enum ADD VALUE IF NOT EXISTS 'campaign_resumed';
+ALTER TYPE audit_action_enum ADD VALUE IF NOT EXISTS 'campaign_execution_stopped';
@@ -0,0 +1,4 @@
+-- Migration: 0126_add_campaign_execution_control_enum_values_down.sql
+-- Description: Down migration for campaign execution control enum values (no-op)
+
+-- PostgreSQL enum values cannot be removed safely in a reversible migration.
@@ -0,0 +1,10 @@
+-- Migration: 0127_add_campaign_execution_stop_columns.sql
+-- Description: Record when a running campaign was stopped and how many messages it had sent
+
+BEGIN;
+
+ALTER TABLE campaigns
+    ADD COLUMN IF NOT EXISTS execution_stopped_at TIMESTAMP WITH TIME ZONE NULL,
+    ADD COLUMN IF NOT EXISTS sent_before_stop BIGINT NULL;
+
+COMMIT;
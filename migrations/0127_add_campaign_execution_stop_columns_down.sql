@@ -0,0 +1,10 @@
+-- Migration: 0127_add_campaign_execution_stop_columns_down.sql
+-- Description: Drop campaign execution stop columns
+
+BEGIN;
+
+ALTER TABLE campaigns
+    DROP COLUMN IF EXISTS sent_before_stop,
+    DROP COLUMN IF EXISTS execution_stopped_at;
+
+COMMIT;
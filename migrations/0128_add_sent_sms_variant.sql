@@ -0,0 +1,13 @@
+-- Migration: 0128_add_sent_sms_variant.sql
+-- Description: Tag sent SMS rows with the A/B content variant they carried
+
+BEGIN;
+
+ALTER TABLE sent_sms
+    ADD COLUMN IF NOT EXISTS variant VARCHAR(16) NULL;
+
+CREATE INDEX IF NOT EXISTS idx_sent_sms_processed_campaign_id_variant
+    ON sent_sms (processed_campaign_id, variant)
+    WHERE variant IS NOT NULL;
+
+COMMIT;
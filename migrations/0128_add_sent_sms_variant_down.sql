@@ -0,0 +1,11 @@
+-- Migration: 0128_add_sent_sms_variant_down.sql
+-- Description: Drop the A/B content variant of sent SMS rows
+
+BEGIN;
+
+DROP INDEX IF EXISTS idx_sent_sms_processed_campaign_id_variant;
+
+ALTER TABLE sent_sms
+    DROP COLUMN IF EXISTS variant;
+
+COMMIT;
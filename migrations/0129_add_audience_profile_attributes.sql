@@ -0,0 +1,11 @@
+-- Migration: 0129_add_audience_profile_attributes.sql
+-- Description: Store audience profile attributes used to personalize campaign content
+
+BEGIN;
+
+ALTER TABLE audience_profiles
+    ADD COLUMN IF NOT EXISTS attributes JSONB NOT NULL DEFAULT '{}'::jsonb;
+
+COMMENT ON COLUMN audience_profiles.attributes IS 'Personalization attributes such as first_name, last_name and city';
+
+COMMIT;
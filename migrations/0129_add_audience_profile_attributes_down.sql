@@ -0,0 +1,9 @@
+-- Migration: 0129_add_audience_profile_attributes_down.sql
+-- Description: Drop audience profile personalization attributes
+
+BEGIN;
+
+ALTER TABLE audience_profiles
+    DROP COLUMN IF EXISTS attributes;
+
+COMMIT;
@@ -0,0 +1,36 @@
+-- Migration: 0130_create_audience_import_jobs.sql
+-- Description: Create audience_import_jobs table tracking asynchronous bulk audience CSV imports
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS audience_import_jobs (
+    id BIGSERIAL PRIMARY KEY,
+    uuid UUID NOT NULL UNIQUE DEFAULT gen_random_uuid(),
+    admin_id INTEGER REFERENCES admins(id) ON DELETE SET NULL,
+    file_name VARCHAR(255) NOT NULL,
+    file_path TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+
+    total_rows BIGINT NOT NULL DEFAULT 0,
+    processed_rows BIGINT NOT NULL DEFAULT 0,
+    inserted_rows BIGINT NOT NULL DEFAULT 0,
+    existing_rows BIGINT NOT NULL DEFAULT 0,
+    duplicate_rows BIGINT NOT NULL DEFAULT 0,
+    failed_rows BIGINT NOT NULL DEFAULT 0,
+
+    -- Capped list of {row, phone_number, error} objects for rejected rows
+    row_errors JSONB NOT NULL DEFAULT '[]'::jsonb,
+    error_message TEXT,
+
+    started_at TIMESTAMP WITH TIME ZONE,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT chk_audience_import_jobs_status CHECK (status IN ('pending', 'processing', 'completed', 'failed'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_audience_import_jobs_status ON audience_import_jobs(status);
+CREATE INDEX IF NOT EXISTS idx_audience_import_jobs_created_at ON audience_import_jobs(created_at);
+
+COMMIT;
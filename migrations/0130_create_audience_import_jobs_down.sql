@@ -0,0 +1,6 @@
+-- Migration: 0130_create_audience_import_jobs_down.sql
+-- Description: Drop audience_import_jobs table
+
+BEGIN;
+DROP TABLE IF EXISTS audience_import_jobs;
+COMMIT;
@@ -0,0 +1,4 @@
+-- Migration: 0131_add_audience_import_audit_actions.sql
+-- Description: Add audit_action_enum value for admin bulk audience imports
+
+ALTER TYPE audit_action_enum ADD VALUE IF NOT EXISTS 'admin_audience_import_create';
@@ -0,0 +1,4 @@
+-- Migration: 0131_add_audience_import_audit_actions_down.sql
+-- Description: Down migration for audience import audit actions
+
+-- PostgreSQL enum values cannot be removed safely in a reversible migration.
@@ -0,0 +1,89 @@
+-- Migration: 0132_normalize_phone_numbers.sql
+-- Description: Backfill Iranian mobile numbers into canonical E.164 form (+989XXXXXXXXX).
+-- Safe to re-run (make backfill-phone-numbers); values that are not Iranian mobiles are left untouched.
+
+BEGIN;
+
+-- Mirrors utils/phonenumber.Parse: Persian/Arabic digits, separators and the
+-- 0098 / 98 / 0 prefixes are accepted; anything else yields NULL.
+CREATE OR REPLACE FUNCTION canonical_ir_mobile(raw TEXT)
+RETURNS TEXT AS $$
+DECLARE
+    digits TEXT;
+BEGIN
+    IF raw IS NULL THEN
+        RETURN NULL;
+    END IF;
+
+    digits := translate(btrim(raw), '۰۱۲۳۴۵۶۷۸۹٠١٢٣٤٥٦٧٨٩', '01234567890123456789');
+    digits := regexp_replace(digits, '^\+', '');
+    digits := regexp_replace(digits, '[ ().-]', '', 'g');
+    IF digits !~ '^[0-9]+$' THEN
+        RETURN NULL;
+    END IF;
+
+    IF digits LIKE '0098%' THEN
+        digits := substr(digits, 5);
+    ELSIF digits LIKE '98%' AND length(digits) = 12 THEN
+        digits := substr(digits, 3);
+    ELSIF digits LIKE '0%' AND length(digits) = 11 THEN
+        digits := substr(digits, 2);
+    END IF;
+
+    IF digits !~ '^9[0-9]{9}$' THEN
+        RETURN NULL;
+    END IF;
+    RETURN '+98' || digits;
+END;
+$$ LANGUAGE plpgsql IMMUTABLE;
+
+-- Unique columns: skip rows whose canonical form already belongs to another row
+UPDATE customers c
+SET representative_mobile = canonical_ir_mobile(c.representative_mobile)
+WHERE canonical_ir_mobile(c.representative_mobile) IS NOT NULL
+  AND canonical_ir_mobile(c.representative_mobile) <> c.representative_mobile
+  AND NOT EXISTS (
+      SELECT 1 FROM customers o
+      WHERE o.representative_mobile = canonical_ir_mobile(c.representative_mobile)
+  );
+
+UPDATE audience_profiles p
+SET phone_number = canonical_ir_mobile(p.phone_number)
+WHERE canonical_ir_mobile(p.phone_number) IS NOT NULL
+  AND canonical_ir_mobile(p.phone_number) <> p.phone_number
+  AND NOT EXISTS (
+      SELECT 1 FROM audience_profiles o
+      WHERE o.phone_number = canonical_ir_mobile(p.phone_number)
+  );
+
+UPDATE sent_sms
+SET phone_number = canonical_ir_mobile(phone_number)
+WHERE canonical_ir_mobile(phone_number) IS NOT NULL
+  AND canonical_ir_mobile(phone_number) <> phone_number;
+
+UPDATE sent_bale_messages
+SET phone_number = canonical_ir_mobile(phone_number)
+WHERE canonical_ir_mobile(phone_number) IS NOT NULL
+  AND canonical_ir_mobile(phone_number) <> phone_number;
+
+UPDATE sent_rubika_messages
+SET phone_number = canonical_ir_mobile(phone_number)
+WHERE canonical_ir_mobile(phone_number) IS NOT NULL
+  AND canonical_ir_mobile(phone_number) <> phone_number;
+
+UPDATE sent_splus_messages
+SET phone_number = canonical_ir_mobile(phone_number)
+WHERE canonical_ir_mobile(phone_number) IS NOT NULL
+  AND canonical_ir_mobile(phone_number) <> phone_number;
+
+UPDATE short_links
+SET phone_number = canonical_ir_mobile(phone_number)
+WHERE canonical_ir_mobile(phone_number) IS NOT NULL
+  AND canonical_ir_mobile(phone_number) <> phone_number;
+
+UPDATE short_link_clicks
+SET phone_number = canonical_ir_mobile(phone_number)
+WHERE canonical_ir_mobile(phone_number) IS NOT NULL
+  AND canonical_ir_mobile(phone_number) <> phone_number;
+
+COMMIT;
@@ -0,0 +1,9 @@
+-- Migration: 0132_normalize_phone_numbers_down.sql
+-- Description: Drop the canonical_ir_mobile helper. Backfilled phone numbers stay in E.164 form,
+-- since the original spellings were not recorded.
+
+BEGIN;
+
+DROP FUNCTION IF EXISTS canonical_ir_mobile(TEXT);
+
+COMMIT;
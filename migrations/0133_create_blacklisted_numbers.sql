@@ -0,0 +1,28 @@
+-- Migration: 0133_create_blacklisted_numbers.sql
+-- Description: Create blacklisted_numbers table of prohibited recipients and track per-campaign exclusions
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS blacklisted_numbers (
+    id BIGSERIAL PRIMARY KEY,
+    -- Canonical E.164 form, e.g. +989123456789
+    phone_number VARCHAR(20) NOT NULL UNIQUE,
+    source VARCHAR(20) NOT NULL,
+    reason TEXT,
+    admin_id INTEGER REFERENCES admins(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT chk_blacklisted_numbers_source CHECK (source IN ('regulator', 'complaint', 'admin'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_blacklisted_numbers_source ON blacklisted_numbers(source);
+CREATE INDEX IF NOT EXISTS idx_blacklisted_numbers_created_at ON blacklisted_numbers(created_at);
+
+COMMENT ON TABLE blacklisted_numbers IS 'Recipients that must never be messaged by any campaign';
+COMMENT ON COLUMN blacklisted_numbers.source IS 'regulator: regulator-provided list, complaint: recipient complaint, admin: manual entry';
+
+-- Number of audience candidates skipped because they were blacklisted
+ALTER TABLE processed_campaigns
+    ADD COLUMN IF NOT EXISTS blacklisted_excluded BIGINT NOT NULL DEFAULT 0;
+
+COMMIT;
@@ -0,0 +1,7 @@
+-- Migration: 0133_create_blacklisted_numbers_down.sql
+-- Description: Drop blacklisted_numbers table and processed_campaigns.blacklisted_excluded
+
+BEGIN;
+ALTER TABLE processed_campaigns DROP COLUMN IF EXISTS blacklisted_excluded;
+DROP TABLE IF EXISTS blacklisted_numbers;
+COMMIT;
@@ -0,0 +1,5 @@
+-- Migration: 0134_add_blacklist_audit_actions.sql
+-- Description: Add audit_action_enum values for admin blacklist management
+
+ALTER TYPE audit_action_enum ADD VALUE IF NOT EXISTS 'admin_blacklist_upload';
+ALTER TYPE audit_action_enum ADD VALUE IF NOT EXISTS 'admin_blacklist_delete';
@@ -0,0 +1,4 @@
+-- Migration: 0134_add_blacklist_audit_actions_down.sql
+-- Description: Down migration for blacklist audit actions
+
+-- PostgreSQL enum values cannot be removed safely in a reversible migration.
@@ -0,0 +1,25 @@
+-- Migration: 0135_create_campaign_reviews.sql
+-- Description: Create campaign_reviews table holding the review history and comment thread of campaigns
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS campaign_reviews (
+    id BIGSERIAL PRIMARY KEY,
+    campaign_id INTEGER NOT NULL REFERENCES campaigns(id) ON DELETE CASCADE,
+    author_type VARCHAR(20) NOT NULL,
+    admin_id INTEGER REFERENCES admins(id) ON DELETE SET NULL,
+    customer_id INTEGER REFERENCES customers(id) ON DELETE SET NULL,
+    action VARCHAR(30) NOT NULL,
+    comment TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT chk_campaign_reviews_author_type CHECK (author_type IN ('admin', 'customer')),
+    CONSTRAINT chk_campaign_reviews_action CHECK (action IN ('comment', 'changes_requested', 'resubmitted', 'approved', 'rejected'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_campaign_reviews_campaign_id ON campaign_reviews(campaign_id, id);
+
+COMMENT ON TABLE campaign_reviews IS 'Review history of campaigns: admin decisions, revision requests, resubmissions and comments';
+COMMENT ON COLUMN campaign_reviews.action IS 'comment: free-form message, changes_requested: admin asked for revisions, resubmitted: customer finalized again, approved/rejected: admin decision';
+
+COMMIT;
@@ -0,0 +1,6 @@
+-- Migration: 0135_create_campaign_reviews_down.sql
+-- Description: Drop campaign_reviews table
+
+BEGIN;
+DROP TABLE IF EXISTS campaign_reviews;
+COMMIT;
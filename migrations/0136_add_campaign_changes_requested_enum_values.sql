@@ -0,0 +1,19 @@
+-- Migration: 0136_add_campaign_changes_requested_enum_values.sql
+-- Description: Add 'changes-requested' campaign status and audit actions for campaign revision requests and review comments
+
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1
+        FROM pg_type t
+        JOIN pg_enum e ON t.oid = e.enumtypid
+        WHERE t.typname = 'sms_campaign_status' AND e.enumlabel = 'changes-requested'
+    ) THEN
+        ALTER TYPE sms_campaign_status ADD VALUE 'changes-requested';
+    END IF;
+END$$;
+
+ALTER TYPE audit_action_enum ADD VALUE IF NOT EXISTS 'admin_campaign_changes_requested';
+ALTER TYPE audit_action_enum ADD VALUE IF NOT EXISTS 'admin_campaign_review_comment';
+ALTER TYPE audit_action_enum ADD VALUE IF NOT EXISTS 'campaign_resubmitted';
+ALTER TYPE audit_action_enum ADD VALUE IF NOT EXISTS 'campaign_review_comment';
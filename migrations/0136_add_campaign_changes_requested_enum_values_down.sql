@@ -0,0 +1,4 @@
+-- Migration: 0136_add_campaign_changes_requested_enum_values_down.sql
+-- Description: Down migration for campaign revision request enum values (no-op)
+
+-- PostgreSQL enum values cannot be removed safely in a reversible migration.
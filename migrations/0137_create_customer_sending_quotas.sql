@@ -0,0 +1,26 @@
+-- Migration: 0137_create_customer_sending_quotas.sql
+-- Description: Create customer_sending_quotas table of per-customer daily/monthly recipient limits and track per-campaign quota exclusions
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS customer_sending_quotas (
+    id BIGSERIAL PRIMARY KEY,
+    customer_id INTEGER NOT NULL UNIQUE REFERENCES customers(id) ON DELETE CASCADE,
+    -- NULL means unlimited
+    daily_limit BIGINT,
+    monthly_limit BIGINT,
+    admin_id INTEGER REFERENCES admins(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT chk_customer_sending_quotas_daily_limit CHECK (daily_limit IS NULL OR daily_limit >= 0),
+    CONSTRAINT chk_customer_sending_quotas_monthly_limit CHECK (monthly_limit IS NULL OR monthly_limit >= 0)
+);
+
+COMMENT ON TABLE customer_sending_quotas IS 'Maximum number of recipients a customer may message per Tehran calendar day and month, across all platforms';
+
+-- Number of selected recipients dropped because the customer's sending quota was exhausted
+ALTER TABLE processed_campaigns
+    ADD COLUMN IF NOT EXISTS quota_excluded BIGINT NOT NULL DEFAULT 0;
+
+COMMIT;
@@ -0,0 +1,7 @@
+-- Migration: 0137_create_customer_sending_quotas_down.sql
+-- Description: Drop customer_sending_quotas table and processed_campaigns.quota_excluded
+
+BEGIN;
+ALTER TABLE processed_campaigns DROP COLUMN IF EXISTS quota_excluded;
+DROP TABLE IF EXISTS customer_sending_quotas;
+COMMIT;
@@ -0,0 +1,4 @@
+-- Migration: 0138_add_sending_quota_audit_actions.sql
+-- Description: Add audit actions for admin updates of customer sending quotas
+
+ALTER TYPE audit_action_enum ADD VALUE IF NOT EXISTS 'admin_customer_sending_quota_update';
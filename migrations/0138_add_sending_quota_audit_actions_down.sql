@@ -0,0 +1,4 @@
+-- Migration: 0138_add_sending_quota_audit_actions_down.sql
+-- Description: Down migration for sending quota audit actions (no-op)
+
+-- PostgreSQL enum values cannot be removed safely in a reversible migration.
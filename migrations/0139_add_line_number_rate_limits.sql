@@ -0,0 +1,25 @@
+-- Migration: 0139_add_line_number_rate_limits.sql
+-- Description: Add provider throughput limits and overflow pools to line numbers and record the sending line of each SMS
+
+BEGIN;
+
+ALTER TABLE line_numbers
+    ADD COLUMN IF NOT EXISTS rate_per_second INTEGER,
+    ADD COLUMN IF NOT EXISTS burst INTEGER,
+    ADD COLUMN IF NOT EXISTS pool VARCHAR(50);
+
+ALTER TABLE line_numbers
+    ADD CONSTRAINT chk_line_numbers_rate_per_second CHECK (rate_per_second IS NULL OR rate_per_second >= 0),
+    ADD CONSTRAINT chk_line_numbers_burst CHECK (burst IS NULL OR burst >= 0);
+
+CREATE INDEX IF NOT EXISTS idx_line_numbers_pool ON line_numbers(pool) WHERE pool IS NOT NULL;
+
+COMMENT ON COLUMN line_numbers.rate_per_second IS 'Provider throughput limit in messages per second; NULL or 0 means unlimited';
+COMMENT ON COLUMN line_numbers.burst IS 'Messages that may be sent at once before throttling; NULL or 0 defaults to rate_per_second';
+COMMENT ON COLUMN line_numbers.pool IS 'Active lines sharing a pool are interchangeable senders; campaigns spill over to them when their line is saturated';
+
+-- Line that actually sent the message; differs from the campaign line when it spilled over
+ALTER TABLE sent_sms
+    ADD COLUMN IF NOT EXISTS sender VARCHAR(20);
+
+COMMIT;
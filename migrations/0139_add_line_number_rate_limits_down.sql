@@ -0,0 +1,13 @@
+-- Migration: 0139_add_line_number_rate_limits_down.sql
+-- Description: Drop line number rate limits, pools and sent_sms.sender
+
+BEGIN;
+ALTER TABLE sent_sms DROP COLUMN IF EXISTS sender;
+DROP INDEX IF EXISTS idx_line_numbers_pool;
+ALTER TABLE line_numbers
+    DROP CONSTRAINT IF EXISTS chk_line_numbers_burst,
+    DROP CONSTRAINT IF EXISTS chk_line_numbers_rate_per_second,
+    DROP COLUMN IF EXISTS pool,
+    DROP COLUMN IF EXISTS burst,
+    DROP COLUMN IF EXISTS rate_per_second;
+COMMIT;
@@ -0,0 +1,52 @@
+-- Migration: 0140_create_line_number_tiers_and_reservations.sql
+-- Description: Create line_number_tiers pricing table and line_number_reservations for exclusive customer use of a line
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS line_number_tiers (
+    id SERIAL PRIMARY KEY,
+    -- Matches line_numbers.tier
+    name VARCHAR(50) NOT NULL UNIQUE,
+    display_name VARCHAR(255),
+    description TEXT,
+    -- Price in Tomans of reserving one line of the tier for one day
+    reservation_price_per_day BIGINT NOT NULL DEFAULT 0,
+    max_reservation_days INTEGER NOT NULL DEFAULT 30,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT chk_line_number_tiers_price CHECK (reservation_price_per_day >= 0),
+    CONSTRAINT chk_line_number_tiers_max_days CHECK (max_reservation_days > 0)
+);
+
+COMMENT ON TABLE line_number_tiers IS 'Reservation pricing of line numbers grouped by line_numbers.tier';
+
+CREATE TABLE IF NOT EXISTS line_number_reservations (
+    id BIGSERIAL PRIMARY KEY,
+    uuid UUID NOT NULL UNIQUE DEFAULT gen_random_uuid(),
+    line_number_id INTEGER NOT NULL REFERENCES line_numbers(id) ON DELETE CASCADE,
+    customer_id INTEGER NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+    tier_id INTEGER REFERENCES line_number_tiers(id) ON DELETE SET NULL,
+    days INTEGER NOT NULL,
+    -- Total price in Tomans debited from the customer's wallet
+    price BIGINT NOT NULL DEFAULT 0,
+    starts_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    released_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT chk_line_number_reservations_days CHECK (days > 0),
+    CONSTRAINT chk_line_number_reservations_price CHECK (price >= 0),
+    CONSTRAINT chk_line_number_reservations_window CHECK (expires_at > starts_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_line_number_reservations_line_open
+    ON line_number_reservations (line_number_id, expires_at) WHERE released_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_line_number_reservations_customer_id
+    ON line_number_reservations (customer_id);
+
+COMMENT ON TABLE line_number_reservations IS 'Exclusive use of a line number by one customer until expires_at or released_at';
+
+COMMIT;
@@ -0,0 +1,7 @@
+-- Migration: 0140_create_line_number_tiers_and_reservations_down.sql
+-- Description: Drop line_number_reservations and line_number_tiers
+
+BEGIN;
+DROP TABLE IF EXISTS line_number_reservations;
+DROP TABLE IF EXISTS line_number_tiers;
+COMMIT;
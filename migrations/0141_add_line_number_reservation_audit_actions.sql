@@ -0,0 +1,8 @@
+-- Migration: 0141_add_line_number_reservation_audit_actions.sql
+-- Description: Add audit actions for line number reservations and admin tier pricing
+
+ALTER TYPE audit_action_enum ADD VALUE IF NOT EXISTS 'line_number_reserved';
+ALTER TYPE audit_action_enum ADD VALUE IF NOT EXISTS 'line_number_reservation_failed';
+ALTER TYPE audit_action_enum ADD VALUE IF NOT EXISTS 'line_number_reservation_released';
+ALTER TYPE audit_action_enum ADD VALUE IF NOT EXISTS 'admin_line_number_tier_create';
+ALTER TYPE audit_action_enum ADD VALUE IF NOT EXISTS 'admin_line_number_tier_update';
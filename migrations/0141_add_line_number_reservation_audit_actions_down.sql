@@ -0,0 +1,4 @@
+-- Migration: 0141_add_line_number_reservation_audit_actions_down.sql
+-- Description: Down migration for line number reservation audit actions (no-op)
+
+-- PostgreSQL enum values cannot be removed safely in a reversible migration.
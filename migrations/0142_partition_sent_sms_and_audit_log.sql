@@ -0,0 +1,154 @@
+-- Migration: 0142_partition_sent_sms_and_audit_log.sql
+-- Description: Convert sent_sms and audit_log into tables range partitioned by month on created_at
+--
+-- The existing rows are not copied: each table is renamed to <table>_legacy and
+-- attached as the partition covering everything before the first of next
+-- month (UTC). Monthly partitions named <table>_pYYYY_MM are created from then
+-- on by ensure_monthly_partition(), which the partition maintenance worker
+-- calls ahead of time. A DEFAULT partition catches rows outside any range.
+--
+-- Partitioned tables need the partition key in every unique constraint, so the
+-- primary keys become (id, created_at). Rewriting the legacy primary keys takes
+-- an ACCESS EXCLUSIVE lock for the duration of the index build.
+
+BEGIN;
+
+CREATE OR REPLACE FUNCTION ensure_monthly_partition(parent_table TEXT, month_start DATE)
+RETURNS TEXT
+LANGUAGE plpgsql
+AS $$
+DECLARE
+    first_day DATE := date_trunc('month', month_start)::DATE;
+    partition_name TEXT := format('%s_p%s', parent_table, to_char(first_day, 'YYYY_MM'));
+BEGIN
+    IF to_regclass(partition_name) IS NULL THEN
+        EXECUTE format(
+            'CREATE TABLE %I PARTITION OF %I FOR VALUES FROM (%L) TO (%L)',
+            partition_name,
+            parent_table,
+            (first_day::TIMESTAMP AT TIME ZONE 'UTC'),
+            ((first_day + INTERVAL '1 month')::TIMESTAMP AT TIME ZONE 'UTC')
+        );
+    END IF;
+    RETURN partition_name;
+END;
+$$;
+
+COMMENT ON FUNCTION ensure_monthly_partition(TEXT, DATE) IS 'Creates the <parent>_pYYYY_MM partition holding the UTC month of month_start if missing';
+
+-- Renames every non primary key index of a table with a _legacy suffix so the
+-- original names can be reused on the partitioned parent
+CREATE OR REPLACE FUNCTION pg_temp.rename_legacy_indexes(legacy_table TEXT)
+RETURNS VOID
+LANGUAGE plpgsql
+AS $$
+DECLARE
+    idx RECORD;
+BEGIN
+    FOR idx IN
+        SELECT i.indexname
+        FROM pg_indexes i
+        WHERE i.schemaname = current_schema()
+          AND i.tablename = legacy_table
+          AND i.indexname NOT LIKE '%\_pkey'
+    LOOP
+        EXECUTE format('ALTER INDEX %I RENAME TO %I', idx.indexname, idx.indexname || '_legacy');
+    END LOOP;
+END;
+$$;
+
+-- ---------------------------------------------------------------------------
+-- sent_sms
+-- ---------------------------------------------------------------------------
+
+ALTER TABLE sent_sms RENAME TO sent_sms_legacy;
+ALTER TABLE sent_sms_legacy DROP CONSTRAINT sent_sms_pkey;
+ALTER TABLE sent_sms_legacy ADD CONSTRAINT sent_sms_legacy_pkey PRIMARY KEY (id, created_at);
+SELECT pg_temp.rename_legacy_indexes('sent_sms_legacy');
+
+CREATE TABLE sent_sms (
+    LIKE sent_sms_legacy INCLUDING DEFAULTS INCLUDING CONSTRAINTS,
+    CONSTRAINT sent_sms_pkey PRIMARY KEY (id, created_at),
+    CONSTRAINT sent_sms_processed_campaign_id_fkey FOREIGN KEY (processed_campaign_id)
+        REFERENCES processed_campaigns(id) ON DELETE CASCADE
+) PARTITION BY RANGE (created_at);
+
+CREATE INDEX idx_sent_sms_processed_campaign_id ON sent_sms(processed_campaign_id);
+CREATE INDEX idx_sent_sms_phone_number ON sent_sms(phone_number);
+CREATE INDEX idx_sent_sms_tracking_id ON sent_sms(tracking_id);
+CREATE INDEX idx_sent_sms_status ON sent_sms(status);
+CREATE INDEX idx_sent_sms_created_at ON sent_sms(created_at);
+CREATE INDEX idx_sent_sms_processed_campaign_id_variant
+    ON sent_sms (processed_campaign_id, variant)
+    WHERE variant IS NOT NULL;
+
+DO $$
+BEGIN
+    EXECUTE format(
+        'ALTER TABLE sent_sms ATTACH PARTITION sent_sms_legacy FOR VALUES FROM (MINVALUE) TO (%L)',
+        (date_trunc('month', CURRENT_TIMESTAMP AT TIME ZONE 'UTC') + INTERVAL '1 month') AT TIME ZONE 'UTC'
+    );
+END$$;
+
+ALTER SEQUENCE sent_sms_id_seq OWNED BY sent_sms.id;
+
+CREATE TABLE sent_sms_default PARTITION OF sent_sms DEFAULT;
+
+-- ---------------------------------------------------------------------------
+-- audit_log
+-- ---------------------------------------------------------------------------
+
+UPDATE audit_log SET created_at = CURRENT_TIMESTAMP WHERE created_at IS NULL;
+
+ALTER TABLE audit_log RENAME TO audit_log_legacy;
+ALTER TABLE audit_log_legacy ALTER COLUMN created_at SET NOT NULL;
+ALTER TABLE audit_log_legacy DROP CONSTRAINT audit_log_pkey;
+ALTER TABLE audit_log_legacy ADD CONSTRAINT audit_log_legacy_pkey PRIMARY KEY (id, created_at);
+SELECT pg_temp.rename_legacy_indexes('audit_log_legacy');
+
+CREATE TABLE audit_log (
+    LIKE audit_log_legacy INCLUDING DEFAULTS INCLUDING CONSTRAINTS,
+    CONSTRAINT audit_log_pkey PRIMARY KEY (id, created_at)
+) PARTITION BY RANGE (created_at);
+
+CREATE INDEX idx_audit_customer_id ON audit_log(customer_id) WHERE customer_id IS NOT NULL;
+CREATE INDEX idx_audit_action ON audit_log(action);
+CREATE INDEX idx_audit_created_at ON audit_log(created_at);
+CREATE INDEX idx_audit_success ON audit_log(success) WHERE success IS NOT NULL;
+CREATE INDEX idx_audit_ip_address ON audit_log(ip_address)
+    WHERE ip_address IS NOT NULL AND ip_address <> '';
+CREATE INDEX idx_audit_request_id ON audit_log(request_id) WHERE request_id IS NOT NULL;
+CREATE INDEX idx_audit_customer_action ON audit_log(customer_id, action) WHERE customer_id IS NOT NULL;
+CREATE INDEX idx_audit_customer_date ON audit_log(customer_id, created_at) WHERE customer_id IS NOT NULL;
+CREATE INDEX idx_audit_action_date ON audit_log(action, created_at);
+CREATE INDEX idx_audit_failed_actions ON audit_log(success, action, created_at) WHERE success = FALSE;
+CREATE INDEX idx_audit_metadata ON audit_log USING GIN (metadata) WHERE metadata IS NOT NULL;
+
+DO $$
+BEGIN
+    EXECUTE format(
+        'ALTER TABLE audit_log ATTACH PARTITION audit_log_legacy FOR VALUES FROM (MINVALUE) TO (%L)',
+        (date_trunc('month', CURRENT_TIMESTAMP AT TIME ZONE 'UTC') + INTERVAL '1 month') AT TIME ZONE 'UTC'
+    );
+END$$;
+
+ALTER SEQUENCE audit_log_id_seq OWNED BY audit_log.id;
+
+CREATE TABLE audit_log_default PARTITION OF audit_log DEFAULT;
+
+-- ---------------------------------------------------------------------------
+-- Upcoming months
+-- ---------------------------------------------------------------------------
+
+DO $$
+DECLARE
+    next_month DATE := (date_trunc('month', CURRENT_TIMESTAMP AT TIME ZONE 'UTC') + INTERVAL '1 month')::DATE;
+    i INTEGER;
+BEGIN
+    FOR i IN 0..2 LOOP
+        PERFORM ensure_monthly_partition('sent_sms', (next_month + make_interval(months => i))::DATE);
+        PERFORM ensure_monthly_partition('audit_log', (next_month + make_interval(months => i))::DATE);
+    END LOOP;
+END$$;
+
+COMMIT;
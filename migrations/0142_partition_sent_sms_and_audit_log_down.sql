@@ -0,0 +1,52 @@
+-- Migration: 0142_partition_sent_sms_and_audit_log_down.sql
+-- Description: Fold the sent_sms and audit_log partitions back into plain tables
+--
+-- Rows of partitions that were already archived and dropped are not restored;
+-- load them back from the archive store first if they are needed.
+
+BEGIN;
+
+-- sent_sms
+CREATE TABLE sent_sms_unpartitioned (LIKE sent_sms INCLUDING DEFAULTS INCLUDING CONSTRAINTS);
+INSERT INTO sent_sms_unpartitioned SELECT * FROM sent_sms;
+ALTER SEQUENCE sent_sms_id_seq OWNED BY sent_sms_unpartitioned.id;
+DROP TABLE sent_sms CASCADE;
+ALTER TABLE sent_sms_unpartitioned RENAME TO sent_sms;
+ALTER TABLE sent_sms ADD CONSTRAINT sent_sms_pkey PRIMARY KEY (id);
+ALTER TABLE sent_sms ADD CONSTRAINT sent_sms_processed_campaign_id_fkey FOREIGN KEY (processed_campaign_id)
+    REFERENCES processed_campaigns(id) ON DELETE CASCADE;
+
+CREATE INDEX idx_sent_sms_processed_campaign_id ON sent_sms(processed_campaign_id);
+CREATE INDEX idx_sent_sms_phone_number ON sent_sms(phone_number);
+CREATE INDEX idx_sent_sms_tracking_id ON sent_sms(tracking_id);
+CREATE INDEX idx_sent_sms_status ON sent_sms(status);
+CREATE INDEX idx_sent_sms_created_at ON sent_sms(created_at);
+CREATE INDEX idx_sent_sms_processed_campaign_id_variant
+    ON sent_sms (processed_campaign_id, variant)
+    WHERE variant IS NOT NULL;
+
+-- audit_log
+CREATE TABLE audit_log_unpartitioned (LIKE audit_log INCLUDING DEFAULTS INCLUDING CONSTRAINTS);
+INSERT INTO audit_log_unpartitioned SELECT * FROM audit_log;
+ALTER SEQUENCE audit_log_id_seq OWNED BY audit_log_unpartitioned.id;
+DROP TABLE audit_log CASCADE;
+ALTER TABLE audit_log_unpartitioned RENAME TO audit_log;
+ALTER TABLE audit_log ALTER COLUMN created_at DROP NOT NULL;
+ALTER TABLE audit_log ADD CONSTRAINT audit_log_pkey PRIMARY KEY (id);
+
+CREATE INDEX idx_audit_customer_id ON audit_log(customer_id) WHERE customer_id IS NOT NULL;
+CREATE INDEX idx_audit_action ON audit_log(action);
+CREATE INDEX idx_audit_created_at ON audit_log(created_at);
+CREATE INDEX idx_audit_success ON audit_log(success) WHERE success IS NOT NULL;
+CREATE INDEX idx_audit_ip_address ON audit_log(ip_address)
+    WHERE ip_address IS NOT NULL AND ip_address <> '';
+CREATE INDEX idx_audit_request_id ON audit_log(request_id) WHERE request_id IS NOT NULL;
+CREATE INDEX idx_audit_customer_action ON audit_log(customer_id, action) WHERE customer_id IS NOT NULL;
+CREATE INDEX idx_audit_customer_date ON audit_log(customer_id, created_at) WHERE customer_id IS NOT NULL;
+CREATE INDEX idx_audit_action_date ON audit_log(action, created_at);
+CREATE INDEX idx_audit_failed_actions ON audit_log(success, action, created_at) WHERE success = FALSE;
+CREATE INDEX idx_audit_metadata ON audit_log USING GIN (metadata) WHERE metadata IS NOT NULL;
+
+DROP FUNCTION IF EXISTS ensure_monthly_partition(TEXT, DATE);
+
+COMMIT;
@@ -0,0 +1,31 @@
+-- Migration: 0143_create_partition_archives.sql
+-- Description: Create partition_archives recording the partitions moved to cold storage
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS partition_archives (
+    id BIGSERIAL PRIMARY KEY,
+    uuid UUID NOT NULL UNIQUE DEFAULT gen_random_uuid(),
+    -- Partitioned table the rows belonged to, e.g. sent_sms
+    parent_table VARCHAR(63) NOT NULL,
+    partition_name VARCHAR(63) NOT NULL UNIQUE,
+    -- Lower bound is NULL for the legacy partition that started at MINVALUE
+    range_start TIMESTAMP WITH TIME ZONE,
+    range_end TIMESTAMP WITH TIME ZONE NOT NULL,
+    row_count BIGINT NOT NULL DEFAULT 0,
+    -- Gzipped CSV with a header row, relative to the archive store root
+    storage_key TEXT NOT NULL,
+    size_bytes BIGINT NOT NULL DEFAULT 0,
+    checksum_sha256 VARCHAR(64) NOT NULL,
+    archived_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT chk_partition_archives_range CHECK (range_start IS NULL OR range_end > range_start)
+);
+
+CREATE INDEX IF NOT EXISTS idx_partition_archives_parent_range
+    ON partition_archives (parent_table, range_end);
+
+COMMENT ON TABLE partition_archives IS 'Partitions of sent_sms and audit_log exported to the archive store and dropped';
+
+COMMIT;
@@ -0,0 +1,6 @@
+-- Migration: 0143_create_partition_archives_down.sql
+-- Description: Drop partition_archives
+
+BEGIN;
+DROP TABLE IF EXISTS partition_archives;
+COMMIT;
@@ -0,0 +1,3 @@
+-- Description: Add audit_action_enum value for admin force-logout
+
+ALTER TYPE audit_action_enum ADD VALUE IF NOT EXISTS 'admin_force_logout_customer';
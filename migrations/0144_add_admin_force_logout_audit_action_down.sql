@@ -0,0 +1,3 @@
+-- Description: Down migration for admin force-logout audit action
+
+-- PostgreSQL enum values cannot be removed safely in a reversible migration.
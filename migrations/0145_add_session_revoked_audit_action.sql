@@ -0,0 +1,3 @@
+-- Description: Add audit_action_enum value for customers revoking one of their sessions
+
+ALTER TYPE audit_action_enum ADD VALUE IF NOT EXISTS 'session_revoked';
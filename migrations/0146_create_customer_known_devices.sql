@@ -0,0 +1,29 @@
+-- Migration: 0146_create_customer_known_devices.sql
+-- Description: Create customer_known_devices of the devices and networks customers logged in from, and customers.password_reset_required
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS customer_known_devices (
+    id BIGSERIAL PRIMARY KEY,
+    customer_id INTEGER NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+    -- SHA-256 of the browser, OS, and device type parsed from the user agent
+    fingerprint VARCHAR(64) NOT NULL,
+    -- /24 (IPv4) or /48 (IPv6) network of the client IP
+    ip_range VARCHAR(64) NOT NULL,
+    country VARCHAR(2),
+    user_agent TEXT,
+    first_seen_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_seen_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT uk_customer_known_devices UNIQUE (customer_id, fingerprint, ip_range)
+);
+
+CREATE INDEX IF NOT EXISTS idx_customer_known_devices_customer_id ON customer_known_devices(customer_id);
+
+COMMENT ON TABLE customer_known_devices IS 'Devices and networks each customer logged in from; logins from unseen ones trigger a suspicious login alert';
+
+-- Set when the customer reported a login as not theirs; login is refused until the password is reset
+ALTER TABLE customers
+    ADD COLUMN IF NOT EXISTS password_reset_required BOOLEAN NOT NULL DEFAULT FALSE;
+
+COMMIT;
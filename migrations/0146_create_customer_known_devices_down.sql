@@ -0,0 +1,7 @@
+-- Migration: 0146_create_customer_known_devices_down.sql
+-- Description: Drop customer_known_devices table and customers.password_reset_required
+
+BEGIN;
+ALTER TABLE customers DROP COLUMN IF EXISTS password_reset_required;
+DROP TABLE IF EXISTS customer_known_devices;
+COMMIT;
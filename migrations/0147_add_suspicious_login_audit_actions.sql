@@ -0,0 +1,5 @@
+-- Migration: 0147_add_suspicious_login_audit_actions.sql
+-- Description: Add audit actions for suspicious login alerts and logins reported as not the customer's
+
+ALTER TYPE audit_action_enum ADD VALUE IF NOT EXISTS 'suspicious_login_detected';
+ALTER TYPE audit_action_enum ADD VALUE IF NOT EXISTS 'login_reported_not_me';
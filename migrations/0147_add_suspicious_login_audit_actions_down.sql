@@ -0,0 +1,4 @@
+-- Migration: 0147_add_suspicious_login_audit_actions_down.sql
+-- Description: Down migration for suspicious login audit actions
+
+-- PostgreSQL enum values cannot be removed safely in a reversible migration.
@@ -0,0 +1,12 @@
+-- Migration: 0148_add_admin_totp.sql
+-- Description: Add the TOTP secret admins confirm at their first login and verify on every later one
+
+BEGIN;
+
+ALTER TABLE admins
+    ADD COLUMN IF NOT EXISTS totp_secret VARCHAR(64),
+    ADD COLUMN IF NOT EXISTS totp_enabled_at TIMESTAMP WITH TIME ZONE;
+
+COMMENT ON COLUMN admins.totp_secret IS 'Base32 TOTP secret (RFC 6238); NULL until the admin enrolls an authenticator app';
+
+COMMIT;
@@ -0,0 +1,8 @@
+-- Migration: 0148_add_admin_totp_down.sql
+-- Description: Drop admin TOTP columns
+
+BEGIN;
+ALTER TABLE admins
+    DROP COLUMN IF EXISTS totp_enabled_at,
+    DROP COLUMN IF EXISTS totp_secret;
+COMMIT;
@@ -0,0 +1,6 @@
+-- Migration: 0149_add_admin_security_audit_actions.sql
+-- Description: Add audit actions for admin TOTP logins and requests refused by the admin IP allowlist
+
+ALTER TYPE audit_action_enum ADD VALUE IF NOT EXISTS 'admin_totp_enrolled';
+ALTER TYPE audit_action_enum ADD VALUE IF NOT EXISTS 'admin_totp_failed';
+ALTER TYPE audit_action_enum ADD VALUE IF NOT EXISTS 'admin_ip_denied';
@@ -0,0 +1,4 @@
+-- Migration: 0149_add_admin_security_audit_actions_down.sql
+-- Description: Down migration for admin TOTP and IP allowlist audit actions
+
+-- PostgreSQL enum values cannot be removed safely in a reversible migration.
@@ -0,0 +1,3 @@
+-- Description: Add audit_action_enum value for admin impersonation of customers
+
+ALTER TYPE audit_action_enum ADD VALUE IF NOT EXISTS 'admin_impersonate_customer';
@@ -0,0 +1,49 @@
+-- Migration: 0151_create_customer_data_requests.sql
+-- Description: Create customer_data_requests of customer data exports and account deletions, and customers.deleted_at
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS customer_data_requests (
+    id BIGSERIAL PRIMARY KEY,
+    uuid UUID NOT NULL UNIQUE DEFAULT gen_random_uuid(),
+    customer_id INTEGER NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+    type VARCHAR(20) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+
+    -- Exports only: format of the bundled records and path of the zip file
+    format VARCHAR(10),
+    file_path TEXT,
+
+    -- Deletions only: end of the grace period
+    scheduled_for TIMESTAMP WITH TIME ZONE,
+    error_message TEXT,
+
+    started_at TIMESTAMP WITH TIME ZONE,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    cancelled_at TIMESTAMP WITH TIME ZONE,
+    expires_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT chk_customer_data_requests_type CHECK (type IN ('export', 'deletion')),
+    CONSTRAINT chk_customer_data_requests_status CHECK (status IN ('pending', 'scheduled', 'processing', 'completed', 'failed', 'cancelled')),
+    CONSTRAINT chk_customer_data_requests_format CHECK (format IS NULL OR format IN ('json', 'csv'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_customer_data_requests_customer_id ON customer_data_requests(customer_id);
+CREATE INDEX IF NOT EXISTS idx_customer_data_requests_status ON customer_data_requests(status);
+
+-- At most one open deletion request per customer
+CREATE UNIQUE INDEX IF NOT EXISTS uk_customer_data_requests_open_deletion
+    ON customer_data_requests(customer_id)
+    WHERE type = 'deletion' AND status IN ('scheduled', 'processing');
+
+COMMENT ON TABLE customer_data_requests IS 'Customer requests to export their data or delete their account, processed in the background';
+
+-- Set when the account was deleted; the row is kept with its personal data anonymized
+ALTER TABLE customers
+    ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_customers_deleted_at ON customers(deleted_at);
+
+COMMIT;
@@ -0,0 +1,8 @@
+-- Migration: 0151_create_customer_data_requests_down.sql
+-- Description: Drop customer_data_requests table and customers.deleted_at
+
+BEGIN;
+DROP INDEX IF EXISTS idx_customers_deleted_at;
+ALTER TABLE customers DROP COLUMN IF EXISTS deleted_at;
+DROP TABLE IF EXISTS customer_data_requests;
+COMMIT;
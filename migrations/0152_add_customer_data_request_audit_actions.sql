@@ -0,0 +1,7 @@
+-- Description: Add audit_action_enum values for customer data exports and account deletion
+
+ALTER TYPE audit_action_enum ADD VALUE IF NOT EXISTS 'data_export_requested';
+ALTER TYPE audit_action_enum ADD VALUE IF NOT EXISTS 'data_export_completed';
+ALTER TYPE audit_action_enum ADD VALUE IF NOT EXISTS 'account_deletion_requested';
+ALTER TYPE audit_action_enum ADD VALUE IF NOT EXISTS 'account_deletion_cancelled';
+ALTER TYPE audit_action_enum ADD VALUE IF NOT EXISTS 'account_deleted';
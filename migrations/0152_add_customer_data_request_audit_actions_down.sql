@@ -0,0 +1,3 @@
+-- Description: Down migration for customer data request audit actions
+
+-- PostgreSQL enum values cannot be removed safely in a reversible migration.
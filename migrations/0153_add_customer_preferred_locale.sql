@@ -0,0 +1,16 @@
+-- Migration: 0153_add_customer_preferred_locale.sql
+-- Description: Add the language customers receive SMS, emails and pages in
+
+BEGIN;
+
+ALTER TABLE customers
+    ADD COLUMN IF NOT EXISTS preferred_locale VARCHAR(8);
+
+ALTER TABLE customers
+    DROP CONSTRAINT IF EXISTS chk_customers_preferred_locale;
+ALTER TABLE customers
+    ADD CONSTRAINT chk_customers_preferred_locale CHECK (preferred_locale IS NULL OR preferred_locale IN ('en', 'fa'));
+
+COMMENT ON COLUMN customers.preferred_locale IS 'Locale of messages sent to the customer (en or fa); NULL uses I18N_DEFAULT_LOCALE';
+
+COMMIT;
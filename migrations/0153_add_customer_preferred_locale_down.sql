@@ -0,0 +1,8 @@
+-- Migration: 0153_add_customer_preferred_locale_down.sql
+-- Description: Drop customers.preferred_locale
+
+BEGIN;
+ALTER TABLE customers
+    DROP CONSTRAINT IF EXISTS chk_customers_preferred_locale,
+    DROP COLUMN IF EXISTS preferred_locale;
+COMMIT;
@@ -0,0 +1,3 @@
+-- Description: Add audit_action_enum value for runtime configuration reloads
+
+ALTER TYPE audit_action_enum ADD VALUE IF NOT EXISTS 'config_reloaded';
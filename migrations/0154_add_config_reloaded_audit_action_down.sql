@@ -0,0 +1,3 @@
+-- Description: Down migration for the configuration reload audit action
+
+-- PostgreSQL enum values cannot be removed safely in a reversible migration.
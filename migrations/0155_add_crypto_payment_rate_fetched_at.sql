@@ -0,0 +1,12 @@
+-- Migration: 0155_add_crypto_payment_rate_fetched_at.sql
+-- Description: Record when the exchange rate a crypto payment request was checked against was fetched
+
+BEGIN;
+
+ALTER TABLE crypto_payment_requests
+    ADD COLUMN IF NOT EXISTS rate_fetched_at TIMESTAMPTZ;
+
+COMMENT ON COLUMN crypto_payment_requests.rate_source IS 'Exchange rate sources the provider quote was checked against, comma separated';
+COMMENT ON COLUMN crypto_payment_requests.rate_fetched_at IS 'When the exchange rate in rate_source was fetched';
+
+COMMIT;
@@ -0,0 +1,8 @@
+-- Migration: 0155_add_crypto_payment_rate_fetched_at_down.sql
+-- Description: Drop crypto_payment_requests.rate_fetched_at
+
+BEGIN;
+COMMENT ON COLUMN crypto_payment_requests.rate_source IS NULL;
+ALTER TABLE crypto_payment_requests
+    DROP COLUMN IF EXISTS rate_fetched_at;
+COMMIT;
@@ -0,0 +1,25 @@
+-- Migration: 0156_create_crypto_webhook_events.sql
+-- Description: Record applied crypto provider callbacks so re-delivered ones are not applied twice
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS crypto_webhook_events (
+    id BIGSERIAL PRIMARY KEY,
+    platform VARCHAR(64) NOT NULL,
+    -- SHA-256 of the provider request ID, tx hashes and status
+    event_key CHAR(64) NOT NULL,
+    track_id VARCHAR(255) NOT NULL,
+    tx_hash TEXT NOT NULL DEFAULT '',
+    status VARCHAR(32) NOT NULL,
+    event_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT uk_crypto_webhook_events_platform_event_key UNIQUE (platform, event_key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_crypto_webhook_events_track_id ON crypto_webhook_events(track_id);
+CREATE INDEX IF NOT EXISTS idx_crypto_webhook_events_created_at ON crypto_webhook_events(created_at);
+
+COMMENT ON TABLE crypto_webhook_events IS 'Crypto provider callbacks already applied, used to ignore re-deliveries';
+
+COMMIT;
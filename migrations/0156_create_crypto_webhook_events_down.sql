@@ -0,0 +1,6 @@
+-- Migration: 0156_create_crypto_webhook_events_down.sql
+-- Description: Drop crypto_webhook_events table
+
+BEGIN;
+DROP TABLE IF EXISTS crypto_webhook_events;
+COMMIT;
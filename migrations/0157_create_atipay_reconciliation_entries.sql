@@ -0,0 +1,40 @@
+-- Migration: 0157_create_atipay_reconciliation_entries.sql
+-- Description: Create atipay_reconciliation_entries matching Atipay daily settlement reports to payment requests
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS atipay_reconciliation_entries (
+    id BIGSERIAL PRIMARY KEY,
+    uuid UUID NOT NULL UNIQUE DEFAULT gen_random_uuid(),
+    -- Tehran calendar day of the settlement report
+    report_date DATE NOT NULL,
+    status VARCHAR(32) NOT NULL,
+    source VARCHAR(16) NOT NULL,
+
+    reference_number VARCHAR(255),
+    rrn VARCHAR(255),
+    -- Our invoice number, sent to Atipay as the reservation number
+    reservation_number VARCHAR(255),
+
+    payment_request_id BIGINT REFERENCES payment_requests(id) ON DELETE SET NULL,
+    -- Amounts in toman; report_amount is null for payments missing from the report,
+    -- system_amount for settlements missing here
+    report_amount BIGINT,
+    system_amount BIGINT,
+    report_status VARCHAR(64),
+    detail TEXT,
+
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT chk_atipay_reconciliation_entries_status CHECK (status IN ('matched', 'amount_mismatch', 'status_mismatch', 'missing_in_system', 'missing_in_report', 'duplicate')),
+    CONSTRAINT chk_atipay_reconciliation_entries_source CHECK (source IN ('upload', 'pull'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_atipay_reconciliation_entries_report_date ON atipay_reconciliation_entries(report_date);
+CREATE INDEX IF NOT EXISTS idx_atipay_reconciliation_entries_status ON atipay_reconciliation_entries(status);
+CREATE INDEX IF NOT EXISTS idx_atipay_reconciliation_entries_reference_number ON atipay_reconciliation_entries(reference_number);
+CREATE INDEX IF NOT EXISTS idx_atipay_reconciliation_entries_payment_request_id ON atipay_reconciliation_entries(payment_request_id);
+
+COMMENT ON TABLE atipay_reconciliation_entries IS 'Atipay daily settlement report entries matched against completed payment requests; a day reconciled again replaces its entries';
+
+COMMIT;
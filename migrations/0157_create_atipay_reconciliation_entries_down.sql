@@ -0,0 +1,6 @@
+-- Migration: 0157_create_atipay_reconciliation_entries_down.sql
+-- Description: Drop atipay_reconciliation_entries table
+
+BEGIN;
+DROP TABLE IF EXISTS atipay_reconciliation_entries;
+COMMIT;
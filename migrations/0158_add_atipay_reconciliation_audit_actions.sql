@@ -0,0 +1,5 @@
+-- Migration: 0158_add_atipay_reconciliation_audit_actions.sql
+-- Description: Add audit_action_enum values for Atipay settlement reconciliation
+
+ALTER TYPE audit_action_enum ADD VALUE IF NOT EXISTS 'admin_atipay_reconciliation_upload';
+ALTER TYPE audit_action_enum ADD VALUE IF NOT EXISTS 'atipay_reconciliation_pulled';
@@ -0,0 +1,4 @@
+-- Migration: 0158_add_atipay_reconciliation_audit_actions_down.sql
+-- Description: Down migration for Atipay reconciliation audit actions
+
+-- PostgreSQL enum values cannot be removed safely in a reversible migration.
@@ -0,0 +1,45 @@
+-- Migration: 0159_create_wallet_adjustment_requests.sql
+-- Description: Create wallet_adjustment_requests for manual balance corrections approved by a second admin
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS wallet_adjustment_requests (
+    id BIGSERIAL PRIMARY KEY,
+    uuid UUID NOT NULL UNIQUE DEFAULT gen_random_uuid(),
+    -- Shared with the balance snapshot and transaction created on approval
+    correlation_id UUID NOT NULL,
+
+    customer_id INTEGER NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+    direction VARCHAR(10) NOT NULL,
+    -- Amount in toman
+    amount BIGINT NOT NULL,
+    reason_code VARCHAR(32) NOT NULL,
+    note TEXT NOT NULL,
+
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    requested_by_admin_id BIGINT NOT NULL REFERENCES admins(id),
+    reviewed_by_admin_id BIGINT REFERENCES admins(id),
+    review_note TEXT,
+    reviewed_at TIMESTAMP WITH TIME ZONE,
+    transaction_id BIGINT REFERENCES transactions(id),
+
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT chk_wallet_adjustment_requests_direction CHECK (direction IN ('credit', 'debit')),
+    CONSTRAINT chk_wallet_adjustment_requests_amount CHECK (amount > 0),
+    CONSTRAINT chk_wallet_adjustment_requests_reason_code CHECK (reason_code IN ('chargeback', 'goodwill_credit', 'refund', 'correction', 'other')),
+    CONSTRAINT chk_wallet_adjustment_requests_status CHECK (status IN ('pending', 'approved', 'rejected')),
+    -- Dual approval: the requester cannot review their own request
+    CONSTRAINT chk_wallet_adjustment_requests_reviewer CHECK (reviewed_by_admin_id IS NULL OR reviewed_by_admin_id <> requested_by_admin_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_wallet_adjustment_requests_customer_id ON wallet_adjustment_requests(customer_id);
+CREATE INDEX IF NOT EXISTS idx_wallet_adjustment_requests_status ON wallet_adjustment_requests(status);
+CREATE INDEX IF NOT EXISTS idx_wallet_adjustment_requests_requested_by_admin_id ON wallet_adjustment_requests(requested_by_admin_id);
+CREATE INDEX IF NOT EXISTS idx_wallet_adjustment_requests_reviewed_by_admin_id ON wallet_adjustment_requests(reviewed_by_admin_id);
+CREATE INDEX IF NOT EXISTS idx_wallet_adjustment_requests_correlation_id ON wallet_adjustment_requests(correlation_id);
+
+COMMENT ON TABLE wallet_adjustment_requests IS 'Manual wallet credits and debits; applied only after a second admin approves';
+
+COMMIT;
@@ -0,0 +1,6 @@
+-- Migration: 0159_create_wallet_adjustment_requests_down.sql
+-- Description: Drop wallet_adjustment_requests table
+
+BEGIN;
+DROP TABLE IF EXISTS wallet_adjustment_requests;
+COMMIT;
@@ -0,0 +1,6 @@
+-- Migration: 0160_add_wallet_adjustment_audit_actions.sql
+-- Description: Add audit_action_enum values for dual-approval wallet adjustments
+
+ALTER TYPE audit_action_enum ADD VALUE IF NOT EXISTS 'admin_wallet_adjustment_requested';
+ALTER TYPE audit_action_enum ADD VALUE IF NOT EXISTS 'admin_wallet_adjustment_approved';
+ALTER TYPE audit_action_enum ADD VALUE IF NOT EXISTS 'admin_wallet_adjustment_rejected';
@@ -0,0 +1,4 @@
+-- Migration: 0160_add_wallet_adjustment_audit_actions_down.sql
+-- Description: Down migration for wallet adjustment audit actions
+
+-- PostgreSQL enum values cannot be removed safely in a reversible migration.
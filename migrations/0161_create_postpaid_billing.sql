@@ -0,0 +1,65 @@
+-- Migration: 0161_create_postpaid_billing.sql
+-- Description: Create customer_credit_lines, postpaid_draws and postpaid_invoices for campaigns funded on credit and billed monthly
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS customer_credit_lines (
+    id BIGSERIAL PRIMARY KEY,
+    customer_id INTEGER NOT NULL UNIQUE REFERENCES customers(id) ON DELETE CASCADE,
+    -- Amounts in toman
+    credit_limit BIGINT NOT NULL DEFAULT 0,
+    outstanding BIGINT NOT NULL DEFAULT 0,
+    admin_id BIGINT REFERENCES admins(id),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT (CURRENT_TIMESTAMP AT TIME ZONE 'UTC'),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT (CURRENT_TIMESTAMP AT TIME ZONE 'UTC'),
+
+    CONSTRAINT chk_customer_credit_lines_credit_limit CHECK (credit_limit >= 0),
+    CONSTRAINT chk_customer_credit_lines_outstanding CHECK (outstanding >= 0)
+);
+
+CREATE TABLE IF NOT EXISTS postpaid_invoices (
+    id BIGSERIAL PRIMARY KEY,
+    uuid UUID NOT NULL UNIQUE DEFAULT gen_random_uuid(),
+    customer_id INTEGER NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+    -- Tehran calendar month billed, [period_start, period_end)
+    period_start TIMESTAMP WITH TIME ZONE NOT NULL,
+    period_end TIMESTAMP WITH TIME ZONE NOT NULL,
+    amount BIGINT NOT NULL,
+    num_draws BIGINT NOT NULL,
+    status VARCHAR(10) NOT NULL DEFAULT 'open',
+    due_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    paid_at TIMESTAMP WITH TIME ZONE,
+    paid_by_admin_id BIGINT REFERENCES admins(id),
+    payment_reference VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT (CURRENT_TIMESTAMP AT TIME ZONE 'UTC'),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT (CURRENT_TIMESTAMP AT TIME ZONE 'UTC'),
+
+    CONSTRAINT uq_postpaid_invoices_customer_period UNIQUE (customer_id, period_start),
+    CONSTRAINT chk_postpaid_invoices_amount CHECK (amount > 0),
+    CONSTRAINT chk_postpaid_invoices_status CHECK (status IN ('open', 'paid'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_postpaid_invoices_status_due_at ON postpaid_invoices(status, due_at);
+
+CREATE TABLE IF NOT EXISTS postpaid_draws (
+    id BIGSERIAL PRIMARY KEY,
+    customer_id INTEGER NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+    campaign_id INTEGER REFERENCES campaigns(id) ON DELETE SET NULL,
+    amount BIGINT NOT NULL,
+    transaction_id BIGINT NOT NULL REFERENCES transactions(id),
+    -- Set once the draw is billed
+    invoice_id BIGINT REFERENCES postpaid_invoices(id),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT (CURRENT_TIMESTAMP AT TIME ZONE 'UTC'),
+
+    CONSTRAINT chk_postpaid_draws_amount CHECK (amount > 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_postpaid_draws_customer_id ON postpaid_draws(customer_id);
+CREATE INDEX IF NOT EXISTS idx_postpaid_draws_campaign_id ON postpaid_draws(campaign_id);
+CREATE INDEX IF NOT EXISTS idx_postpaid_draws_uninvoiced ON postpaid_draws(created_at) WHERE invoice_id IS NULL;
+
+COMMENT ON TABLE customer_credit_lines IS 'Per-customer credit limit for campaigns funded postpaid, and the amount drawn but not yet paid';
+COMMENT ON TABLE postpaid_draws IS 'Credit drawn into a wallet to fund a campaign budget beyond its balance';
+COMMENT ON TABLE postpaid_invoices IS 'Monthly invoices of postpaid draws; open invoices past due_at block new campaigns';
+
+COMMIT;
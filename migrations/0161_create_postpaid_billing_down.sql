@@ -0,0 +1,8 @@
+-- Migration: 0161_create_postpaid_billing_down.sql
+-- Description: Drop postpaid billing tables
+
+BEGIN;
+DROP TABLE IF EXISTS postpaid_draws;
+DROP TABLE IF EXISTS postpaid_invoices;
+DROP TABLE IF EXISTS customer_credit_lines;
+COMMIT;
@@ -0,0 +1,6 @@
+-- Migration: 0162_add_postpaid_billing_audit_actions.sql
+-- Description: Add audit_action_enum values for credit limits and postpaid invoices
+
+ALTER TYPE audit_action_enum ADD VALUE IF NOT EXISTS 'admin_customer_credit_limit_update';
+ALTER TYPE audit_action_enum ADD VALUE IF NOT EXISTS 'admin_postpaid_invoice_paid';
+ALTER TYPE audit_action_enum ADD VALUE IF NOT EXISTS 'postpaid_invoice_issued';
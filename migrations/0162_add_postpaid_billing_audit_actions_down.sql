@@ -0,0 +1,4 @@
+-- Migration: 0162_add_postpaid_billing_audit_actions_down.sql
+-- Description: Down migration for postpaid billing audit actions
+
+-- PostgreSQL enum values cannot be removed safely in a reversible migration.
@@ -0,0 +1,43 @@
+-- Migration: 0163_create_tax_invoices.sql
+-- Description: Create tax_invoices, the official invoices issued for successful wallet charges
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS tax_invoices (
+    id BIGSERIAL PRIMARY KEY,
+    uuid UUID NOT NULL UNIQUE DEFAULT gen_random_uuid(),
+    -- Numbers are gap-free per legal entity; the counter lives in sequence_counters
+    -- under 'tax_invoice:<legal_entity>' and is advanced in the charge transaction
+    legal_entity VARCHAR(16) NOT NULL,
+    sequence_number BIGINT NOT NULL,
+    invoice_number VARCHAR(40) NOT NULL UNIQUE,
+    customer_id INTEGER NOT NULL REFERENCES customers(id) ON DELETE RESTRICT,
+    transaction_id BIGINT NOT NULL UNIQUE REFERENCES transactions(id),
+    payment_channel VARCHAR(40) NOT NULL,
+    payment_reference VARCHAR(255),
+    -- Amounts in toman; amount_with_tax = amount + tax
+    amount_with_tax BIGINT NOT NULL,
+    amount BIGINT NOT NULL,
+    tax BIGINT NOT NULL,
+    tax_basis_points BIGINT NOT NULL,
+    description VARCHAR(255) NOT NULL,
+    -- Seller and buyer as they were when the invoice was issued
+    seller JSONB NOT NULL,
+    buyer JSONB NOT NULL,
+    issued_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    pdf_path VARCHAR(512),
+    pdf_generated_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT (CURRENT_TIMESTAMP AT TIME ZONE 'UTC'),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT (CURRENT_TIMESTAMP AT TIME ZONE 'UTC'),
+
+    CONSTRAINT uq_tax_invoices_legal_entity_sequence UNIQUE (legal_entity, sequence_number),
+    CONSTRAINT chk_tax_invoices_sequence_number CHECK (sequence_number > 0),
+    CONSTRAINT chk_tax_invoices_amounts CHECK (amount_with_tax = amount + tax)
+);
+
+CREATE INDEX IF NOT EXISTS idx_tax_invoices_customer_issued_at ON tax_invoices(customer_id, issued_at DESC);
+CREATE INDEX IF NOT EXISTS idx_tax_invoices_issued_at ON tax_invoices(issued_at);
+
+COMMENT ON TABLE tax_invoices IS 'Official invoices of wallet charges, numbered without gaps per legal entity';
+
+COMMIT;
@@ -0,0 +1,7 @@
+-- Migration: 0163_create_tax_invoices_down.sql
+-- Description: Drop tax_invoices and their number sequences
+
+BEGIN;
+DROP TABLE IF EXISTS tax_invoices;
+DELETE FROM sequence_counters WHERE name LIKE 'tax_invoice:%';
+COMMIT;
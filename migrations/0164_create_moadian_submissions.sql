@@ -0,0 +1,45 @@
+-- Migration: 0164_create_moadian_submissions.sql
+-- Description: Queue issued tax invoices for submission to Moadian, the tax authority's e-invoicing system
+
+BEGIN;
+
+-- Charges through a payment request (gateway, deposit receipt, admin charge)
+-- link their invoice to it; crypto charges have none
+ALTER TABLE tax_invoices
+    ADD COLUMN IF NOT EXISTS payment_request_id BIGINT REFERENCES payment_requests(id);
+
+CREATE TABLE IF NOT EXISTS moadian_submissions (
+    id BIGSERIAL PRIMARY KEY,
+    uuid UUID NOT NULL UNIQUE DEFAULT gen_random_uuid(),
+    tax_invoice_id BIGINT NOT NULL UNIQUE REFERENCES tax_invoices(id),
+    payment_request_id BIGINT REFERENCES payment_requests(id),
+    -- 22-character tax ID derived from the memory ID, issue day and invoice sequence number
+    tax_id VARCHAR(22) UNIQUE,
+    -- pending: waiting for a (re)try; processing: claimed by a worker;
+    -- submitted: accepted for processing, waiting for the result;
+    -- accepted / rejected: final result; failed: gave up after retries
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    reference_number VARCHAR(64),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT (CURRENT_TIMESTAMP AT TIME ZONE 'UTC'),
+    last_error TEXT,
+    submitted_at TIMESTAMP WITH TIME ZONE,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT (CURRENT_TIMESTAMP AT TIME ZONE 'UTC'),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT (CURRENT_TIMESTAMP AT TIME ZONE 'UTC'),
+
+    CONSTRAINT chk_moadian_submissions_status CHECK (status IN ('pending', 'processing', 'submitted', 'accepted', 'rejected', 'failed'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_moadian_submissions_due ON moadian_submissions(next_attempt_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_moadian_submissions_status ON moadian_submissions(status);
+
+COMMENT ON TABLE moadian_submissions IS 'Submission queue and status of tax invoices sent to Moadian';
+
+-- Latest Moadian status of the invoice of a payment request
+ALTER TABLE payment_requests
+    ADD COLUMN IF NOT EXISTS moadian_status VARCHAR(20),
+    ADD COLUMN IF NOT EXISTS moadian_tax_id VARCHAR(22),
+    ADD COLUMN IF NOT EXISTS moadian_reference_number VARCHAR(64);
+
+COMMIT;
@@ -0,0 +1,11 @@
+-- Migration: 0164_create_moadian_submissions_down.sql
+-- Description: Drop the Moadian submission queue and status columns
+
+BEGIN;
+ALTER TABLE payment_requests
+    DROP COLUMN IF EXISTS moadian_reference_number,
+    DROP COLUMN IF EXISTS moadian_tax_id,
+    DROP COLUMN IF EXISTS moadian_status;
+DROP TABLE IF EXISTS moadian_submissions;
+ALTER TABLE tax_invoices DROP COLUMN IF EXISTS payment_request_id;
+COMMIT;
@@ -0,0 +1,30 @@
+-- Migration: 0165_create_agency_delegations.sql
+-- Description: Grants letting a customer's referrer agency create and manage campaigns for them
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS agency_delegations (
+    id BIGSERIAL PRIMARY KEY,
+    uuid UUID NOT NULL DEFAULT gen_random_uuid(),
+    agency_id BIGINT NOT NULL REFERENCES customers(id),
+    customer_id BIGINT NOT NULL REFERENCES customers(id),
+    -- create_campaigns: create, edit, submit and clone campaigns;
+    -- manage_campaigns: cancel, pause and resume them
+    permissions TEXT[] NOT NULL DEFAULT '{}',
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT (CURRENT_TIMESTAMP AT TIME ZONE 'UTC'),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT (CURRENT_TIMESTAMP AT TIME ZONE 'UTC'),
+
+    CONSTRAINT uk_agency_delegations_uuid UNIQUE (uuid),
+    CONSTRAINT chk_agency_delegations_not_self CHECK (agency_id <> customer_id),
+    CONSTRAINT chk_agency_delegations_permissions CHECK (permissions <@ ARRAY['create_campaigns', 'manage_campaigns']::TEXT[])
+);
+
+-- A customer has at most one active grant
+CREATE UNIQUE INDEX IF NOT EXISTS uk_agency_delegations_active_customer ON agency_delegations(customer_id) WHERE revoked_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_agency_delegations_agency_id ON agency_delegations(agency_id);
+CREATE INDEX IF NOT EXISTS idx_agency_delegations_customer_id ON agency_delegations(customer_id);
+
+COMMENT ON TABLE agency_delegations IS 'Customers'' grants letting their referrer agency act for them; revoked rows are kept';
+
+COMMIT;
@@ -0,0 +1,6 @@
+-- Migration: 0165_create_agency_delegations_down.sql
+-- Description: Drop agency delegation grants
+
+BEGIN;
+DROP TABLE IF EXISTS agency_delegations;
+COMMIT;
@@ -0,0 +1,6 @@
+-- Migration: 0166_add_agency_delegation_audit_actions.sql
+-- Description: Add audit_action_enum values for agency delegation grants
+
+ALTER TYPE audit_action_enum ADD VALUE IF NOT EXISTS 'agency_delegation_granted';
+ALTER TYPE audit_action_enum ADD VALUE IF NOT EXISTS 'agency_delegation_revoked';
+ALTER TYPE audit_action_enum ADD VALUE IF NOT EXISTS 'agency_delegation_denied';
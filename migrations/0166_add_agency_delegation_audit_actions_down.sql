@@ -0,0 +1,4 @@
+-- Migration: 0166_add_agency_delegation_audit_actions_down.sql
+-- Description: Down migration for agency delegation audit actions
+
+-- PostgreSQL enum values cannot be removed safely in a reversible migration.
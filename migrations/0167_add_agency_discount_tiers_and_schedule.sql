@@ -0,0 +1,27 @@
+-- Migration: 0167_add_agency_discount_tiers_and_schedule.sql
+-- Description: Volume-based tiers and effective dates for agency discounts
+
+BEGIN;
+
+-- tiers: [{"min_monthly_amount_with_tax": 10000000, "discount_rate": 0.2}, ...]
+-- sorted by threshold; a charge gets the rate of the highest threshold the
+-- customer's earlier charges of the Tehran month reach
+ALTER TABLE agency_discounts
+    ADD COLUMN IF NOT EXISTS tiers JSONB NOT NULL DEFAULT '[]',
+    ADD COLUMN IF NOT EXISTS effective_from TIMESTAMP WITH TIME ZONE;
+
+-- Existing discounts applied from their creation
+UPDATE agency_discounts SET effective_from = created_at WHERE effective_from IS NULL;
+
+ALTER TABLE agency_discounts
+    ALTER COLUMN effective_from SET DEFAULT (CURRENT_TIMESTAMP AT TIME ZONE 'UTC'),
+    ALTER COLUMN effective_from SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_agency_discounts_agency_customer_effective_from
+    ON agency_discounts(agency_id, customer_id, effective_from DESC);
+
+-- A scheduled discount ends the one before it at its effective_from, so
+-- uk_agency_discounts_agency_customer_active still leaves one open-ended row
+COMMENT ON COLUMN agency_discounts.effective_from IS 'When the discount starts to apply; later than created_at for scheduled rate changes';
+
+COMMIT;
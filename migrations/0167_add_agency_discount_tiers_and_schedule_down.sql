@@ -0,0 +1,9 @@
+-- Migration: 0167_add_agency_discount_tiers_and_schedule_down.sql
+-- Description: Drop agency discount tiers and effective dates
+
+BEGIN;
+DROP INDEX IF EXISTS idx_agency_discounts_agency_customer_effective_from;
+ALTER TABLE agency_discounts
+    DROP COLUMN IF EXISTS effective_from,
+    DROP COLUMN IF EXISTS tiers;
+COMMIT;
@@ -0,0 +1,15 @@
+-- Migration: 0168_add_financial_report_indexes.sql
+-- Description: Indexes behind the admin financial dashboard reports
+
+BEGIN;
+
+-- Financial and top customer reports scan completed transactions of a time range
+CREATE INDEX IF NOT EXISTS idx_transactions_completed_created_at
+    ON transactions(created_at)
+    WHERE status = 'completed' AND deleted_at IS NULL;
+
+-- Wallet liability picks each wallet's latest balance snapshot
+CREATE INDEX IF NOT EXISTS idx_balance_snapshots_wallet_id_created_at
+    ON balance_snapshots(wallet_id, created_at DESC, id DESC);
+
+COMMIT;
@@ -0,0 +1,7 @@
+-- Migration: 0168_add_financial_report_indexes_down.sql
+-- Description: Drop the admin financial dashboard report indexes
+
+BEGIN;
+DROP INDEX IF EXISTS idx_balance_snapshots_wallet_id_created_at;
+DROP INDEX IF EXISTS idx_transactions_completed_created_at;
+COMMIT;
@@ -0,0 +1,72 @@
+-- Migration: 0169_create_reporting_rollups.sql
+-- Description: Summary tables the admin and customer analytics endpoints read instead of scanning sent messages, clicks and transactions
+
+BEGIN;
+
+-- Per campaign and Tehran day: messages sent over every channel, unique
+-- clickers and the campaign budget spent and refunded
+CREATE TABLE IF NOT EXISTS campaign_daily_stats (
+    stat_date DATE NOT NULL,
+    campaign_id BIGINT NOT NULL REFERENCES campaigns(id) ON DELETE CASCADE,
+    customer_id BIGINT NOT NULL REFERENCES customers(id),
+    sent_messages BIGINT NOT NULL DEFAULT 0,
+    successful_messages BIGINT NOT NULL DEFAULT 0,
+    delivered_parts BIGINT NOT NULL DEFAULT 0,
+    clicks BIGINT NOT NULL DEFAULT 0,
+    spend BIGINT NOT NULL DEFAULT 0,
+    refunds BIGINT NOT NULL DEFAULT 0,
+    refreshed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT (CURRENT_TIMESTAMP AT TIME ZONE 'UTC'),
+
+    PRIMARY KEY (stat_date, campaign_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_campaign_daily_stats_campaign_id ON campaign_daily_stats(campaign_id, stat_date);
+CREATE INDEX IF NOT EXISTS idx_campaign_daily_stats_customer_id ON campaign_daily_stats(customer_id, stat_date);
+
+-- Per Tehran day: the money flows of the admin financial report
+CREATE TABLE IF NOT EXISTS revenue_daily (
+    stat_date DATE PRIMARY KEY,
+    charges BIGINT NOT NULL DEFAULT 0,
+    revenue_with_tax BIGINT NOT NULL DEFAULT 0,
+    tax_collected BIGINT NOT NULL DEFAULT 0,
+    system_share BIGINT NOT NULL DEFAULT 0,
+    agency_share_with_tax BIGINT NOT NULL DEFAULT 0,
+    campaign_spend BIGINT NOT NULL DEFAULT 0,
+    campaign_refunds BIGINT NOT NULL DEFAULT 0,
+    refreshed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT (CURRENT_TIMESTAMP AT TIME ZONE 'UTC')
+);
+
+-- Per customer and month (first day of the month, Tehran dates)
+CREATE TABLE IF NOT EXISTS customer_monthly_usage (
+    month DATE NOT NULL,
+    customer_id BIGINT NOT NULL REFERENCES customers(id),
+    campaigns BIGINT NOT NULL DEFAULT 0,
+    sent_messages BIGINT NOT NULL DEFAULT 0,
+    successful_messages BIGINT NOT NULL DEFAULT 0,
+    delivered_parts BIGINT NOT NULL DEFAULT 0,
+    clicks BIGINT NOT NULL DEFAULT 0,
+    campaign_spend BIGINT NOT NULL DEFAULT 0,
+    campaign_refunds BIGINT NOT NULL DEFAULT 0,
+    charges BIGINT NOT NULL DEFAULT 0,
+    paid_with_tax BIGINT NOT NULL DEFAULT 0,
+    refreshed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT (CURRENT_TIMESTAMP AT TIME ZONE 'UTC'),
+
+    PRIMARY KEY (month, customer_id),
+    CONSTRAINT chk_customer_monthly_usage_month CHECK (month = date_trunc('month', month)::date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_customer_monthly_usage_customer_id ON customer_monthly_usage(customer_id, month);
+
+-- The last Tehran day the incremental refresh has rolled up
+CREATE TABLE IF NOT EXISTS report_rollup_state (
+    name TEXT PRIMARY KEY,
+    refreshed_through DATE NOT NULL,
+    refreshed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT (CURRENT_TIMESTAMP AT TIME ZONE 'UTC')
+);
+
+COMMENT ON TABLE campaign_daily_stats IS 'Rollup of sent messages, unique clickers and budget per campaign and Tehran day; clicks are unique per day';
+COMMENT ON TABLE revenue_daily IS 'Rollup of the admin financial report per Tehran day';
+COMMENT ON TABLE customer_monthly_usage IS 'Rollup of campaign usage and wallet charges per customer and month';
+COMMENT ON TABLE report_rollup_state IS 'Watermarks of the reporting rollup refresh';
+
+COMMIT;
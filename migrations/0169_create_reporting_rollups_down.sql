@@ -0,0 +1,9 @@
+-- Migration: 0169_create_reporting_rollups_down.sql
+-- Description: Drop the reporting rollup tables
+
+BEGIN;
+DROP TABLE IF EXISTS report_rollup_state;
+DROP TABLE IF EXISTS customer_monthly_usage;
+DROP TABLE IF EXISTS revenue_daily;
+DROP TABLE IF EXISTS campaign_daily_stats;
+COMMIT;
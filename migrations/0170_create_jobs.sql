@@ -0,0 +1,36 @@
+-- Migration: 0170_create_jobs.sql
+-- Description: Persistent queue of background jobs (SMS and email notifications, audience UID pushes) with retries and dead-letter storage
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS jobs (
+    id BIGSERIAL PRIMARY KEY,
+    uuid UUID NOT NULL UNIQUE,
+    type VARCHAR(64) NOT NULL,
+    payload JSONB NOT NULL DEFAULT '{}'::jsonb,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL DEFAULT 5,
+    run_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT (CURRENT_TIMESTAMP AT TIME ZONE 'UTC'),
+    locked_at TIMESTAMP WITH TIME ZONE,
+    last_error TEXT,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    dead_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT (CURRENT_TIMESTAMP AT TIME ZONE 'UTC'),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT (CURRENT_TIMESTAMP AT TIME ZONE 'UTC'),
+
+    CONSTRAINT chk_jobs_status CHECK (status IN ('pending', 'running', 'succeeded', 'dead')),
+    CONSTRAINT chk_jobs_attempts CHECK (attempts >= 0 AND max_attempts > 0)
+);
+
+-- Workers claim due pending jobs and running jobs whose worker stopped
+CREATE INDEX IF NOT EXISTS idx_jobs_pending_run_at ON jobs(run_at, id) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_jobs_running_locked_at ON jobs(locked_at) WHERE status = 'running';
+-- Succeeded jobs are purged after the retention period
+CREATE INDEX IF NOT EXISTS idx_jobs_succeeded_completed_at ON jobs(completed_at) WHERE status = 'succeeded';
+-- Admin listing by status and type
+CREATE INDEX IF NOT EXISTS idx_jobs_status_type ON jobs(status, type, id DESC);
+
+COMMIT;
+
+ALTER TYPE audit_action_enum ADD VALUE IF NOT EXISTS 'admin_job_requeued';
@@ -0,0 +1,8 @@
+-- Migration: 0170_create_jobs_down.sql
+-- Description: Drop the background job queue
+
+BEGIN;
+DROP TABLE IF EXISTS jobs;
+COMMIT;
+
+-- PostgreSQL enum values cannot be removed safely in a reversible migration.
@@ -0,0 +1,13 @@
+-- Migration: 0171_add_job_cancelled_status.sql
+-- Description: Let admins cancel pending or dead background jobs, e.g. dead-lettered SMS batches that must not be resent
+
+BEGIN;
+
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS cancelled_at TIMESTAMP WITH TIME ZONE;
+
+ALTER TABLE jobs DROP CONSTRAINT IF EXISTS chk_jobs_status;
+ALTER TABLE jobs ADD CONSTRAINT chk_jobs_status CHECK (status IN ('pending', 'running', 'succeeded', 'dead', 'cancelled'));
+
+COMMIT;
+
+ALTER TYPE audit_action_enum ADD VALUE IF NOT EXISTS 'admin_job_cancelled';
@@ -0,0 +1,15 @@
+-- Migration: 0171_add_job_cancelled_status_down.sql
+-- Description: Remove the cancelled job status; cancelled jobs are kept as dead
+
+BEGIN;
+
+UPDATE jobs SET status = 'dead', dead_at = COALESCE(dead_at, cancelled_at) WHERE status = 'cancelled';
+
+ALTER TABLE jobs DROP CONSTRAINT IF EXISTS chk_jobs_status;
+ALTER TABLE jobs ADD CONSTRAINT chk_jobs_status CHECK (status IN ('pending', 'running', 'succeeded', 'dead'));
+
+ALTER TABLE jobs DROP COLUMN IF EXISTS cancelled_at;
+
+COMMIT;
+
+-- PostgreSQL enum values cannot be removed safely in a reversible migration.
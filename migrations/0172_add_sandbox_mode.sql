@@ -0,0 +1,22 @@
+-- Migration: 0172_add_sandbox_mode.sql
+-- Description: Flag sandbox customers, whose campaigns run against mock providers, and the campaigns and transactions they create
+
+BEGIN;
+
+ALTER TABLE customers ADD COLUMN IF NOT EXISTS is_sandbox BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE campaigns ADD COLUMN IF NOT EXISTS is_sandbox BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS is_sandbox BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX IF NOT EXISTS idx_customers_is_sandbox ON customers(is_sandbox) WHERE is_sandbox;
+CREATE INDEX IF NOT EXISTS idx_campaigns_sandbox_customer_id ON campaigns(customer_id) WHERE is_sandbox;
+CREATE INDEX IF NOT EXISTS idx_transactions_sandbox_customer_id ON transactions(customer_id) WHERE is_sandbox;
+
+COMMENT ON COLUMN customers.is_sandbox IS 'Campaigns run against mock providers and the wallet holds test money only';
+COMMENT ON COLUMN campaigns.is_sandbox IS 'Created by a sandbox customer; executed without sending real messages';
+COMMENT ON COLUMN transactions.is_sandbox IS 'Test money of a sandbox customer; excluded from financial reports';
+
+COMMIT;
+
+ALTER TYPE audit_action_enum ADD VALUE IF NOT EXISTS 'sandbox_wallet_topped_up';
+ALTER TYPE audit_action_enum ADD VALUE IF NOT EXISTS 'sandbox_purged';
+ALTER TYPE audit_action_enum ADD VALUE IF NOT EXISTS 'admin_customer_sandbox_update';
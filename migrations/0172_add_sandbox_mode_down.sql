@@ -0,0 +1,16 @@
+-- Migration: 0172_add_sandbox_mode_down.sql
+-- Description: Remove the sandbox flags; sandbox data should be purged first
+
+BEGIN;
+
+DROP INDEX IF EXISTS idx_transactions_sandbox_customer_id;
+DROP INDEX IF EXISTS idx_campaigns_sandbox_customer_id;
+DROP INDEX IF EXISTS idx_customers_is_sandbox;
+
+ALTER TABLE transactions DROP COLUMN IF EXISTS is_sandbox;
+ALTER TABLE campaigns DROP COLUMN IF EXISTS is_sandbox;
+ALTER TABLE customers DROP COLUMN IF EXISTS is_sandbox;
+
+COMMIT;
+
+-- PostgreSQL enum values cannot be removed safely in a reversible migration.
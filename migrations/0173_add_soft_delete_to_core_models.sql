@@ -0,0 +1,25 @@
+-- Migration: 0173_add_soft_delete_to_core_models.sql
+-- Description: Soft-delete campaigns, audience profiles, tags and line numbers, and the admin delete and restore audit actions
+
+BEGIN;
+
+ALTER TABLE campaigns ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE audience_profiles ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE tags ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE line_numbers ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+
+-- Deleted rows are rare; the partial indexes serve audits listing them
+CREATE INDEX IF NOT EXISTS idx_campaigns_deleted_at ON campaigns(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_audience_profiles_deleted_at ON audience_profiles(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_tags_deleted_at ON tags(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_line_numbers_deleted_at ON line_numbers(deleted_at) WHERE deleted_at IS NOT NULL;
+
+COMMENT ON COLUMN campaigns.deleted_at IS 'Soft-deleted by an admin; hidden from customers, admins and the schedulers until restored';
+COMMENT ON COLUMN audience_profiles.deleted_at IS 'Soft-deleted by an admin; excluded from targeting until restored';
+COMMENT ON COLUMN tags.deleted_at IS 'Soft-deleted by an admin; the name stays reserved until the tag is restored';
+COMMENT ON COLUMN line_numbers.deleted_at IS 'Soft-deleted by an admin; the number stays reserved until the line is restored';
+
+COMMIT;
+
+ALTER TYPE audit_action_enum ADD VALUE IF NOT EXISTS 'admin_record_deleted';
+ALTER TYPE audit_action_enum ADD VALUE IF NOT EXISTS 'admin_record_restored';
@@ -0,0 +1,18 @@
+-- Migration: 0173_add_soft_delete_to_core_models_down.sql
+-- Description: Remove soft deletion; soft-deleted rows become visible again
+
+BEGIN;
+
+DROP INDEX IF EXISTS idx_line_numbers_deleted_at;
+DROP INDEX IF EXISTS idx_tags_deleted_at;
+DROP INDEX IF EXISTS idx_audience_profiles_deleted_at;
+DROP INDEX IF EXISTS idx_campaigns_deleted_at;
+
+ALTER TABLE line_numbers DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE tags DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE audience_profiles DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE campaigns DROP COLUMN IF EXISTS deleted_at;
+
+COMMIT;
+
+-- PostgreSQL enum values cannot be removed safely in a reversible migration.
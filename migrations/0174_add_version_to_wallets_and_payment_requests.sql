@@ -0,0 +1,12 @@
+-- Migration: 0174_add_version_to_wallets_and_payment_requests.sql
+-- Description: Version wallets and payment requests for optimistic locking of balance and status updates
+
+BEGIN;
+
+ALTER TABLE wallets ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE payment_requests ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN wallets.version IS 'Advanced with every balance snapshot of the wallet; a writer that read an older version retries';
+COMMENT ON COLUMN payment_requests.version IS 'Advanced with every update of the request; a writer that read an older version retries';
+
+COMMIT;
@@ -0,0 +1,9 @@
+-- Migration: 0174_add_version_to_wallets_and_payment_requests_down.sql
+-- Description: Remove the optimistic locking versions of wallets and payment requests
+
+BEGIN;
+
+ALTER TABLE payment_requests DROP COLUMN IF EXISTS version;
+ALTER TABLE wallets DROP COLUMN IF EXISTS version;
+
+COMMIT;
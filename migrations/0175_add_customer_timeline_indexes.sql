@@ -0,0 +1,17 @@
+-- Migration: 0175_add_customer_timeline_indexes.sql
+-- Description: Indexes behind the admin customer activity timeline
+
+BEGIN;
+
+-- The timeline pages each source newest first per customer; audit_log is
+-- already covered by idx_audit_customer_date
+CREATE INDEX IF NOT EXISTS idx_sessions_customer_id_created_at
+    ON customer_sessions(customer_id, created_at DESC, id DESC);
+
+CREATE INDEX IF NOT EXISTS idx_payment_requests_customer_id_created_at
+    ON payment_requests(customer_id, created_at DESC, id DESC);
+
+CREATE INDEX IF NOT EXISTS idx_campaigns_customer_id_created_at
+    ON campaigns(customer_id, created_at DESC, id DESC);
+
+COMMIT;
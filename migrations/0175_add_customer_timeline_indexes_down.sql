@@ -0,0 +1,8 @@
+-- Migration: 0175_add_customer_timeline_indexes_down.sql
+-- Description: Drop the admin customer activity timeline indexes
+
+BEGIN;
+DROP INDEX IF EXISTS idx_campaigns_customer_id_created_at;
+DROP INDEX IF EXISTS idx_payment_requests_customer_id_created_at;
+DROP INDEX IF EXISTS idx_sessions_customer_id_created_at;
+COMMIT;
@@ -0,0 +1,24 @@
+-- Migration: 0176_add_customer_search_indexes.sql
+-- Description: Trigram and full-text indexes behind the admin customer search, and its audit action
+
+BEGIN;
+
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- Substring and similarity matches over name, company, email, mobile,
+-- national ID and UUID. The expressions must match CustomerRepository.Search.
+CREATE INDEX IF NOT EXISTS idx_customers_search_trgm
+    ON customers USING GIN ((lower(representative_first_name || ' ' || representative_last_name || ' ' ||
+        coalesce(company_name, '') || ' ' || email || ' ' || representative_mobile || ' ' ||
+        coalesce(national_id, '') || ' ' || uuid::text)) gin_trgm_ops)
+    WHERE deleted_at IS NULL;
+
+-- Word prefix matches over name and company
+CREATE INDEX IF NOT EXISTS idx_customers_search_tsv
+    ON customers USING GIN (to_tsvector('simple', representative_first_name || ' ' || representative_last_name || ' ' ||
+        coalesce(company_name, '')))
+    WHERE deleted_at IS NULL;
+
+COMMIT;
+
+ALTER TYPE audit_action_enum ADD VALUE IF NOT EXISTS 'admin_search_customers';
@@ -0,0 +1,9 @@
+-- Migration: 0176_add_customer_search_indexes_down.sql
+-- Description: Drop the admin customer search indexes; pg_trgm is kept for the short link indexes
+
+-- PostgreSQL enum values cannot be removed safely; admin_search_customers is kept.
+
+BEGIN;
+DROP INDEX IF EXISTS idx_customers_search_tsv;
+DROP INDEX IF EXISTS idx_customers_search_trgm;
+COMMIT;
@@ -0,0 +1,25 @@
+-- Migration: 0177_create_notifications.sql
+-- Description: Create notifications table holding the in-app inbox of customers
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS notifications (
+    id BIGSERIAL PRIMARY KEY,
+    customer_id INTEGER NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+    kind VARCHAR(30) NOT NULL,
+    args JSONB NOT NULL DEFAULT '{}',
+    read_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT chk_notifications_kind CHECK (kind IN ('campaign_approved', 'campaign_rejected', 'payment_credited', 'low_balance'))
+);
+
+-- The inbox pages newest first per customer; the unread count only scans
+-- unread rows
+CREATE INDEX IF NOT EXISTS idx_notifications_customer_id ON notifications(customer_id, id DESC);
+CREATE INDEX IF NOT EXISTS idx_notifications_customer_id_unread ON notifications(customer_id) WHERE read_at IS NULL;
+
+COMMENT ON TABLE notifications IS 'In-app inbox of customers, fed by campaign decisions, wallet credits and low balance warnings';
+COMMENT ON COLUMN notifications.args IS 'Arguments of the event; title and body are rendered from kind and args in the customer locale when read';
+
+COMMIT;
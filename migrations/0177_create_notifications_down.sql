@@ -0,0 +1,6 @@
+-- Migration: 0177_create_notifications_down.sql
+-- Description: Drop notifications table
+
+BEGIN;
+DROP TABLE IF EXISTS notifications;
+COMMIT;
@@ -0,0 +1,39 @@
+-- Migration: 0178_add_customer_telegram_link.sql
+-- Description: Add the Telegram chat customers receive campaign and payment notices in, the requests that link it, and the link audit actions
+
+BEGIN;
+
+ALTER TABLE customers
+    ADD COLUMN IF NOT EXISTS telegram_chat_id BIGINT,
+    ADD COLUMN IF NOT EXISTS telegram_linked_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_customers_telegram_chat_id ON customers(telegram_chat_id) WHERE telegram_chat_id IS NOT NULL;
+
+COMMENT ON COLUMN customers.telegram_chat_id IS 'Linked Telegram chat campaign and payment notices are sent to instead of SMS; NULL when not linked';
+
+CREATE TABLE IF NOT EXISTS telegram_link_requests (
+    id BIGSERIAL PRIMARY KEY,
+    customer_id INTEGER NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+    token_hash VARCHAR(64) NOT NULL,
+    chat_id BIGINT,
+    code_hash VARCHAR(64),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    linked_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT uk_telegram_link_requests_token_hash UNIQUE (token_hash),
+    CONSTRAINT chk_telegram_link_requests_attempts CHECK (attempts >= 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_telegram_link_requests_customer_id ON telegram_link_requests(customer_id, id DESC);
+
+COMMENT ON TABLE telegram_link_requests IS 'Deep-link requests linking a customer to a Telegram chat, completed with a code the bot sends to the chat';
+COMMENT ON COLUMN telegram_link_requests.token_hash IS 'SHA-256 of the deep-link start token';
+COMMENT ON COLUMN telegram_link_requests.code_hash IS 'SHA-256 of the verification code sent to chat_id';
+
+COMMIT;
+
+ALTER TYPE audit_action_enum ADD VALUE IF NOT EXISTS 'telegram_linked';
+ALTER TYPE audit_action_enum ADD VALUE IF NOT EXISTS 'telegram_unlinked';
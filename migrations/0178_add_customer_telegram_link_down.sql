@@ -0,0 +1,12 @@
+-- Migration: 0178_add_customer_telegram_link_down.sql
+-- Description: Drop telegram_link_requests and the customer Telegram chat columns
+
+-- PostgreSQL enum values cannot be removed safely; telegram_linked and telegram_unlinked are kept.
+
+BEGIN;
+DROP TABLE IF EXISTS telegram_link_requests;
+DROP INDEX IF EXISTS idx_customers_telegram_chat_id;
+ALTER TABLE customers
+    DROP COLUMN IF EXISTS telegram_linked_at,
+    DROP COLUMN IF EXISTS telegram_chat_id;
+COMMIT;
@@ -0,0 +1,27 @@
+-- Migration: 0179_create_push_devices.sql
+-- Description: Create push_devices holding the FCM tokens of customers' browsers and apps, and the push audit actions
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS push_devices (
+    id BIGSERIAL PRIMARY KEY,
+    customer_id INTEGER NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+    token TEXT NOT NULL,
+    platform VARCHAR(10) NOT NULL,
+    user_agent VARCHAR(255),
+    last_seen_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT uk_push_devices_token UNIQUE (token),
+    CONSTRAINT chk_push_devices_platform CHECK (platform IN ('web', 'android', 'ios'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_push_devices_customer_id ON push_devices(customer_id, last_seen_at DESC);
+
+COMMENT ON TABLE push_devices IS 'FCM registration tokens push notifications are sent to; tokens FCM rejects are deleted when a send fails';
+COMMENT ON COLUMN push_devices.last_seen_at IS 'Last registration of the token; the oldest devices are dropped beyond the per-customer limit';
+
+COMMIT;
+
+ALTER TYPE audit_action_enum ADD VALUE IF NOT EXISTS 'admin_push_broadcast';
@@ -0,0 +1,8 @@
+-- Migration: 0179_create_push_devices_down.sql
+-- Description: Drop push_devices
+
+-- PostgreSQL enum values cannot be removed safely; admin_push_broadcast is kept.
+
+BEGIN;
+DROP TABLE IF EXISTS push_devices;
+COMMIT;
@@ -0,0 +1,50 @@
+-- Migration: 0180_add_campaign_status_job_state.sql
+-- Description: Give campaign status jobs an explicit state, a backoff-driven next run time and a per-job attempt limit
+
+BEGIN;
+
+ALTER TABLE campaign_status_jobs
+    ADD COLUMN IF NOT EXISTS status VARCHAR(20),
+    ADD COLUMN IF NOT EXISTS next_run_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN IF NOT EXISTS max_attempts INTEGER NOT NULL DEFAULT 3;
+
+-- Jobs executed before this migration either succeeded or ran out of retries
+UPDATE campaign_status_jobs
+SET status = CASE
+        WHEN executed_at IS NULL THEN 'pending'
+        WHEN error IS NULL THEN 'succeeded'
+        ELSE 'failed'
+    END,
+    next_run_at = scheduled_at
+WHERE status IS NULL;
+
+ALTER TABLE campaign_status_jobs
+    ALTER COLUMN status SET NOT NULL,
+    ALTER COLUMN status SET DEFAULT 'pending',
+    ALTER COLUMN next_run_at SET NOT NULL;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1
+        FROM pg_constraint
+        WHERE conname = 'chk_campaign_status_jobs_status'
+          AND conrelid = 'campaign_status_jobs'::regclass
+    ) THEN
+        ALTER TABLE campaign_status_jobs
+            ADD CONSTRAINT chk_campaign_status_jobs_status
+            CHECK (status IN ('pending', 'succeeded', 'failed', 'skipped'));
+    END IF;
+END
+$$;
+
+DROP INDEX IF EXISTS idx_campaign_status_jobs_platform_scheduled_retry;
+CREATE INDEX IF NOT EXISTS idx_campaign_status_jobs_platform_next_run
+    ON campaign_status_jobs(platform, next_run_at)
+    WHERE status = 'pending';
+
+COMMENT ON COLUMN campaign_status_jobs.status IS 'pending until the job succeeds, runs out of attempts (failed) or is made redundant by final delivery statuses (skipped)';
+COMMENT ON COLUMN campaign_status_jobs.next_run_at IS 'When a worker picks the job up next: scheduled_at, pushed back exponentially after each failed attempt';
+COMMENT ON COLUMN campaign_status_jobs.retry_count IS 'Failed attempts so far; the job fails once it reaches max_attempts';
+
+COMMIT;
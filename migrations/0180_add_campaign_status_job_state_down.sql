@@ -0,0 +1,22 @@
+-- Migration: 0180_add_campaign_status_job_state_down.sql
+-- Description: Drop the campaign status job state columns
+
+BEGIN;
+
+-- Skipped jobs become executed ones so the old worker does not poll them again
+UPDATE campaign_status_jobs
+SET executed_at = COALESCE(executed_at, updated_at)
+WHERE status = 'skipped';
+
+DROP INDEX IF EXISTS idx_campaign_status_jobs_platform_next_run;
+CREATE INDEX IF NOT EXISTS idx_campaign_status_jobs_platform_scheduled_retry
+    ON campaign_status_jobs(platform, scheduled_at, retry_count)
+    WHERE executed_at IS NULL;
+
+ALTER TABLE campaign_status_jobs
+    DROP CONSTRAINT IF EXISTS chk_campaign_status_jobs_status,
+    DROP COLUMN IF EXISTS max_attempts,
+    DROP COLUMN IF EXISTS next_run_at,
+    DROP COLUMN IF EXISTS status;
+
+COMMIT;
@@ -0,0 +1,20 @@
+-- Migration: 0181_add_sms_frequency_cap.sql
+-- Description: Add an optional per-customer SMS frequency cap and track per-campaign frequency-cap exclusions
+
+BEGIN;
+
+-- NULL disables the cap
+ALTER TABLE customer_sending_quotas
+    ADD COLUMN IF NOT EXISTS frequency_cap_hours INTEGER;
+
+ALTER TABLE customer_sending_quotas
+    ADD CONSTRAINT chk_customer_sending_quotas_frequency_cap_hours
+    CHECK (frequency_cap_hours IS NULL OR (frequency_cap_hours > 0 AND frequency_cap_hours <= 720));
+
+COMMENT ON COLUMN customer_sending_quotas.frequency_cap_hours IS 'SMS audience candidates messaged by another SMS campaign of the customer within this many hours are skipped';
+
+-- Number of audience candidates skipped because of the customer's frequency cap
+ALTER TABLE processed_campaigns
+    ADD COLUMN IF NOT EXISTS frequency_capped BIGINT NOT NULL DEFAULT 0;
+
+COMMIT;
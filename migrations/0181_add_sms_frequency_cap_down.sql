@@ -0,0 +1,8 @@
+-- Migration: 0181_add_sms_frequency_cap_down.sql
+-- Description: Drop the SMS frequency cap and processed_campaigns.frequency_capped
+
+BEGIN;
+ALTER TABLE processed_campaigns DROP COLUMN IF EXISTS frequency_capped;
+ALTER TABLE customer_sending_quotas DROP CONSTRAINT IF EXISTS chk_customer_sending_quotas_frequency_cap_hours;
+ALTER TABLE customer_sending_quotas DROP COLUMN IF EXISTS frequency_cap_hours;
+COMMIT;
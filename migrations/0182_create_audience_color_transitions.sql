@@ -0,0 +1,24 @@
+-- Migration: 0182_create_audience_color_transitions.sql
+-- Description: Create audience_color_transitions recording the automatic white/pink moves of audience profiles
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS audience_color_transitions (
+    id BIGSERIAL PRIMARY KEY,
+    audience_profile_id BIGINT NOT NULL REFERENCES audience_profiles(id) ON DELETE CASCADE,
+    from_color VARCHAR(20) NOT NULL,
+    to_color VARCHAR(20) NOT NULL,
+    reason VARCHAR(32) NOT NULL,
+    evidence BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT chk_audience_color_transitions_reason CHECK (reason IN ('delivery_failures', 'opt_out', 'click_engagement'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_audience_color_transitions_profile ON audience_color_transitions(audience_profile_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audience_color_transitions_created_at ON audience_color_transitions(created_at);
+
+COMMENT ON TABLE audience_color_transitions IS 'History of audience profile color changes made by the color lifecycle worker';
+COMMENT ON COLUMN audience_color_transitions.evidence IS 'Undelivered messages or clicks that triggered the transition; 0 for opt-outs';
+
+COMMIT;
@@ -0,0 +1,6 @@
+-- Migration: 0182_create_audience_color_transitions_down.sql
+-- Description: Drop audience_color_transitions
+
+BEGIN;
+DROP TABLE IF EXISTS audience_color_transitions;
+COMMIT;
@@ -0,0 +1,20 @@
+-- Migration: 0183_add_operator_routing.sql
+-- Description: Certify line numbers per mobile operator and record the operator of every sent SMS
+
+BEGIN;
+
+-- NULL or empty means the line delivers to every operator
+ALTER TABLE line_numbers
+    ADD COLUMN IF NOT EXISTS certified_operators TEXT[];
+
+ALTER TABLE line_numbers
+    ADD CONSTRAINT chk_line_numbers_certified_operators
+    CHECK (certified_operators IS NULL OR certified_operators <@ ARRAY['mci', 'mtn', 'rightel']::TEXT[]);
+
+COMMENT ON COLUMN line_numbers.certified_operators IS 'Operators the line is certified to deliver to; SMS batches are routed per recipient operator to pool lines certified for it';
+
+-- NULL for recipients without a known operator and for messages sent before this migration
+ALTER TABLE sent_sms
+    ADD COLUMN IF NOT EXISTS operator VARCHAR(16);
+
+COMMIT;
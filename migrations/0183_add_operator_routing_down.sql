@@ -0,0 +1,8 @@
+-- Migration: 0183_add_operator_routing_down.sql
+-- Description: Drop line number operator certification and the operator of sent SMS
+
+BEGIN;
+ALTER TABLE sent_sms DROP COLUMN IF EXISTS operator;
+ALTER TABLE line_numbers DROP CONSTRAINT IF EXISTS chk_line_numbers_certified_operators;
+ALTER TABLE line_numbers DROP COLUMN IF EXISTS certified_operators;
+COMMIT;
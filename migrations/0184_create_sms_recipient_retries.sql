@@ -0,0 +1,42 @@
+-- Migration: 0184_create_sms_recipient_retries.sql
+-- Description: Create sms_recipient_retries for resending SMS the provider rejected with a transient error, and flag permanent provider errors on sent_sms
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS sms_recipient_retries (
+    id BIGSERIAL PRIMARY KEY,
+    campaign_id BIGINT NOT NULL REFERENCES campaigns(id) ON DELETE CASCADE,
+    processed_campaign_id BIGINT NOT NULL REFERENCES processed_campaigns(id) ON DELETE CASCADE,
+    tracking_id VARCHAR(64) NOT NULL,
+    recipient VARCHAR(20) NOT NULL,
+    sender VARCHAR(20) NOT NULL,
+    body TEXT NOT NULL,
+    parts BIGINT NOT NULL DEFAULT 0,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL,
+    next_attempt_at TIMESTAMPTZ NOT NULL,
+    last_error_code VARCHAR(64),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT (CURRENT_TIMESTAMP AT TIME ZONE 'UTC'),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT (CURRENT_TIMESTAMP AT TIME ZONE 'UTC'),
+
+    CONSTRAINT chk_sms_recipient_retries_status CHECK (status IN ('pending', 'recovered', 'exhausted', 'permanent')),
+    CONSTRAINT chk_sms_recipient_retries_attempts CHECK (attempts >= 0 AND attempts <= max_attempts)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_sms_recipient_retries_processed_campaign_tracking ON sms_recipient_retries(processed_campaign_id, tracking_id);
+CREATE INDEX IF NOT EXISTS idx_sms_recipient_retries_due ON sms_recipient_retries(next_attempt_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_sms_recipient_retries_campaign_id ON sms_recipient_retries(campaign_id);
+
+COMMENT ON TABLE sms_recipient_retries IS 'SMS recipients the provider rejected with a transient error code, resent by the retry worker';
+COMMENT ON COLUMN sms_recipient_retries.attempts IS 'Resends made so far; the original send is not counted';
+
+ALTER TABLE sent_sms ADD COLUMN IF NOT EXISTS permanent_error BOOLEAN NOT NULL DEFAULT FALSE;
+CREATE INDEX IF NOT EXISTS idx_sent_sms_permanent_error ON sent_sms(phone_number, created_at) WHERE permanent_error;
+COMMENT ON COLUMN sent_sms.permanent_error IS 'The provider rejected the message with a permanent error code; the audience profile is demoted by the color worker';
+
+ALTER TABLE audience_color_transitions DROP CONSTRAINT IF EXISTS chk_audience_color_transitions_reason;
+ALTER TABLE audience_color_transitions ADD CONSTRAINT chk_audience_color_transitions_reason
+    CHECK (reason IN ('delivery_failures', 'opt_out', 'click_engagement', 'permanent_error'));
+
+COMMIT;
@@ -0,0 +1,16 @@
+-- Migration: 0184_create_sms_recipient_retries_down.sql
+-- Description: Drop sms_recipient_retries and sent_sms.permanent_error
+
+BEGIN;
+
+DELETE FROM audience_color_transitions WHERE reason = 'permanent_error';
+ALTER TABLE audience_color_transitions DROP CONSTRAINT IF EXISTS chk_audience_color_transitions_reason;
+ALTER TABLE audience_color_transitions ADD CONSTRAINT chk_audience_color_transitions_reason
+    CHECK (reason IN ('delivery_failures', 'opt_out', 'click_engagement'));
+
+DROP INDEX IF EXISTS idx_sent_sms_permanent_error;
+ALTER TABLE sent_sms DROP COLUMN IF EXISTS permanent_error;
+
+DROP TABLE IF EXISTS sms_recipient_retries;
+
+COMMIT;
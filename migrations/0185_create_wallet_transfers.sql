@@ -0,0 +1,45 @@
+-- Migration: 0185_create_wallet_transfers.sql
+-- Description: Create wallet_transfers for OTP-confirmed balance transfers between accounts of the same company
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS wallet_transfers (
+    id BIGSERIAL PRIMARY KEY,
+    uuid UUID NOT NULL UNIQUE DEFAULT gen_random_uuid(),
+    -- Shared with both balance snapshots and both transactions of the transfer
+    correlation_id UUID NOT NULL,
+
+    sender_customer_id INTEGER NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+    receiver_customer_id INTEGER NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+    -- Amount in toman
+    amount BIGINT NOT NULL,
+    note TEXT,
+
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    -- SHA-256 of the OTP sent to the sender's mobile
+    otp_hash VARCHAR(64) NOT NULL,
+    otp_attempts INTEGER NOT NULL DEFAULT 0,
+    otp_expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+
+    sender_transaction_id BIGINT REFERENCES transactions(id),
+    receiver_transaction_id BIGINT REFERENCES transactions(id),
+    completed_at TIMESTAMP WITH TIME ZONE,
+
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT chk_wallet_transfers_amount CHECK (amount > 0),
+    CONSTRAINT chk_wallet_transfers_status CHECK (status IN ('pending', 'completed', 'expired')),
+    CONSTRAINT chk_wallet_transfers_parties CHECK (sender_customer_id <> receiver_customer_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_wallet_transfers_sender_customer_id ON wallet_transfers(sender_customer_id);
+CREATE INDEX IF NOT EXISTS idx_wallet_transfers_receiver_customer_id ON wallet_transfers(receiver_customer_id);
+CREATE INDEX IF NOT EXISTS idx_wallet_transfers_status ON wallet_transfers(status);
+CREATE INDEX IF NOT EXISTS idx_wallet_transfers_correlation_id ON wallet_transfers(correlation_id);
+-- Daily limit: completed transfers of a sender over the last 24 hours
+CREATE INDEX IF NOT EXISTS idx_wallet_transfers_sender_completed_at ON wallet_transfers(sender_customer_id, completed_at) WHERE status = 'completed';
+
+COMMENT ON TABLE wallet_transfers IS 'Balance transfers between wallets of accounts sharing a company national ID; applied once the sender confirms the OTP';
+
+COMMIT;
@@ -0,0 +1,8 @@
+-- Migration: 0185_create_wallet_transfers_down.sql
+-- Description: Down migration for wallet_transfers
+
+BEGIN;
+
+DROP TABLE IF EXISTS wallet_transfers;
+
+COMMIT;
@@ -0,0 +1,9 @@
+-- Migration: 0186_add_wallet_transfer_enum_values.sql
+-- Description: Add wallet transfer transaction types and audit actions
+
+ALTER TYPE transaction_type_enum ADD VALUE IF NOT EXISTS 'transfer_out' AFTER 'discharge_agency_share_with_tax';
+ALTER TYPE transaction_type_enum ADD VALUE IF NOT EXISTS 'transfer_in' AFTER 'transfer_out';
+
+ALTER TYPE audit_action_enum ADD VALUE IF NOT EXISTS 'wallet_transfer_initiated';
+ALTER TYPE audit_action_enum ADD VALUE IF NOT EXISTS 'wallet_transfer_completed';
+ALTER TYPE audit_action_enum ADD VALUE IF NOT EXISTS 'wallet_transfer_failed';
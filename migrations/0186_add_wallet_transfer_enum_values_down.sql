@@ -0,0 +1,4 @@
+-- Migration: 0186_add_wallet_transfer_enum_values_down.sql
+-- Description: Down migration for wallet transfer transaction types and audit actions
+
+-- PostgreSQL enum values cannot be removed safely in a reversible migration.
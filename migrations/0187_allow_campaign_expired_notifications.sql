@@ -0,0 +1,10 @@
+-- Migration: 0187_allow_campaign_expired_notifications.sql
+-- Description: Allow the inbox notification of campaigns expired unsent with their budget refunded
+
+BEGIN;
+
+ALTER TABLE notifications DROP CONSTRAINT IF EXISTS chk_notifications_kind;
+ALTER TABLE notifications ADD CONSTRAINT chk_notifications_kind
+    CHECK (kind IN ('campaign_approved', 'campaign_rejected', 'payment_credited', 'low_balance', 'campaign_expired'));
+
+COMMIT;
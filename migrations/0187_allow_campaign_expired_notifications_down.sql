@@ -0,0 +1,9 @@
+-- Migration: 0187_allow_campaign_expired_notifications_down.sql
+-- Description: Drop the inbox notifications of expired campaigns and restore the previous kinds
+
+BEGIN;
+DELETE FROM notifications WHERE kind = 'campaign_expired';
+ALTER TABLE notifications DROP CONSTRAINT IF EXISTS chk_notifications_kind;
+ALTER TABLE notifications ADD CONSTRAINT chk_notifications_kind
+    CHECK (kind IN ('campaign_approved', 'campaign_rejected', 'payment_credited', 'low_balance'));
+COMMIT;
@@ -0,0 +1,5 @@
+-- Migration: 0188_add_admin_bulk_audit_actions.sql
+-- Description: Add audit actions of admin agency reassignment and crypto payment resync
+
+ALTER TYPE audit_action_enum ADD VALUE IF NOT EXISTS 'admin_customer_agency_reassigned';
+ALTER TYPE audit_action_enum ADD VALUE IF NOT EXISTS 'admin_crypto_payment_resynced';
@@ -0,0 +1,4 @@
+-- Migration: 0188_add_admin_bulk_audit_actions_down.sql
+-- Description: Down migration for admin agency reassignment and crypto payment resync audit actions
+
+-- PostgreSQL enum values cannot be removed safely in a reversible migration.
@@ -0,0 +1,23 @@
+-- Migration: 0189_add_customer_suspension.sql
+-- Description: Suspend customers with graduated levels, separately from is_active; suspended customers can still log in and view their data
+
+BEGIN;
+
+ALTER TABLE customers ADD COLUMN IF NOT EXISTS suspension_level VARCHAR(20);
+ALTER TABLE customers ADD COLUMN IF NOT EXISTS suspension_reason VARCHAR(40);
+ALTER TABLE customers ADD COLUMN IF NOT EXISTS suspension_note VARCHAR(500);
+ALTER TABLE customers ADD COLUMN IF NOT EXISTS suspended_at TIMESTAMPTZ;
+
+ALTER TABLE customers DROP CONSTRAINT IF EXISTS chk_customers_suspension_level;
+ALTER TABLE customers ADD CONSTRAINT chk_customers_suspension_level
+    CHECK (suspension_level IS NULL OR suspension_level IN ('warning', 'restricted', 'suspended'));
+
+CREATE INDEX IF NOT EXISTS idx_customers_suspension_level ON customers(suspension_level) WHERE suspension_level IS NOT NULL;
+
+COMMENT ON COLUMN customers.suspension_level IS 'warning restricts nothing, restricted blocks campaigns, suspended also blocks wallet recharges; NULL when not suspended';
+COMMENT ON COLUMN customers.suspension_reason IS 'Reason code returned to the customer with every blocked request';
+COMMENT ON COLUMN customers.suspension_note IS 'Internal admin note, never shown to the customer';
+
+COMMIT;
+
+ALTER TYPE audit_action_enum ADD VALUE IF NOT EXISTS 'admin_customer_suspension_update';
@@ -0,0 +1,16 @@
+-- Migration: 0189_add_customer_suspension_down.sql
+-- Description: Remove customer suspension; suspended customers are no longer restricted
+
+BEGIN;
+
+DROP INDEX IF EXISTS idx_customers_suspension_level;
+ALTER TABLE customers DROP CONSTRAINT IF EXISTS chk_customers_suspension_level;
+
+ALTER TABLE customers DROP COLUMN IF EXISTS suspended_at;
+ALTER TABLE customers DROP COLUMN IF EXISTS suspension_note;
+ALTER TABLE customers DROP COLUMN IF EXISTS suspension_reason;
+ALTER TABLE customers DROP COLUMN IF EXISTS suspension_level;
+
+COMMIT;
+
+-- PostgreSQL enum values cannot be removed safely in a reversible migration.
@@ -0,0 +1,49 @@
+-- Migration: 0190_add_customer_kyc.sql
+-- Description: Track company document verification on customers and store the uploaded KYC documents, plus the KYC audit actions
+
+BEGIN;
+
+ALTER TABLE customers ADD COLUMN IF NOT EXISTS kyc_status VARCHAR(20) NOT NULL DEFAULT 'not_submitted';
+ALTER TABLE customers ADD COLUMN IF NOT EXISTS kyc_submitted_at TIMESTAMPTZ;
+ALTER TABLE customers ADD COLUMN IF NOT EXISTS kyc_reviewed_at TIMESTAMPTZ;
+ALTER TABLE customers ADD COLUMN IF NOT EXISTS kyc_reviewer_id INTEGER;
+ALTER TABLE customers ADD COLUMN IF NOT EXISTS kyc_rejection_reason VARCHAR(500);
+
+ALTER TABLE customers DROP CONSTRAINT IF EXISTS chk_customers_kyc_status;
+ALTER TABLE customers ADD CONSTRAINT chk_customers_kyc_status
+    CHECK (kyc_status IN ('not_submitted', 'pending_review', 'verified', 'rejected'));
+
+CREATE INDEX IF NOT EXISTS idx_customers_kyc_status ON customers(kyc_status) WHERE kyc_status = 'pending_review';
+
+COMMENT ON COLUMN customers.kyc_status IS 'Company document verification; unverified companies are held to the KYC_UNVERIFIED_* sending quota cap';
+COMMENT ON COLUMN customers.kyc_rejection_reason IS 'Reason shown to the customer when their documents were rejected';
+
+CREATE TABLE IF NOT EXISTS kyc_documents (
+    id BIGSERIAL PRIMARY KEY,
+    uuid UUID NOT NULL,
+    customer_id INTEGER NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+    kind VARCHAR(40) NOT NULL,
+    storage_key VARCHAR(255) NOT NULL,
+    file_name VARCHAR(255) NOT NULL,
+    content_type VARCHAR(120) NOT NULL,
+    file_size BIGINT NOT NULL,
+    sha256 VARCHAR(64) NOT NULL,
+    superseded_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT uk_kyc_documents_uuid UNIQUE (uuid),
+    CONSTRAINT chk_kyc_documents_kind CHECK (kind IN ('registration_certificate', 'official_gazette', 'representative_id')),
+    CONSTRAINT chk_kyc_documents_file_size CHECK (file_size > 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_kyc_documents_customer_id ON kyc_documents(customer_id, created_at DESC);
+CREATE UNIQUE INDEX IF NOT EXISTS uk_kyc_documents_current_kind ON kyc_documents(customer_id, kind) WHERE superseded_at IS NULL;
+
+COMMENT ON TABLE kyc_documents IS 'Verification documents of company customers; the files live in object storage under storage_key';
+COMMENT ON COLUMN kyc_documents.superseded_at IS 'Set when the customer uploaded another document of the same kind; superseded rows are kept for the audit trail';
+
+COMMIT;
+
+ALTER TYPE audit_action_enum ADD VALUE IF NOT EXISTS 'kyc_document_upload';
+ALTER TYPE audit_action_enum ADD VALUE IF NOT EXISTS 'kyc_submit';
+ALTER TYPE audit_action_enum ADD VALUE IF NOT EXISTS 'admin_kyc_review';
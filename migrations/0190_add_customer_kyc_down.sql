@@ -0,0 +1,16 @@
+-- Migration: 0190_add_customer_kyc_down.sql
+-- Description: Drop kyc_documents and the customer KYC columns
+
+-- PostgreSQL enum values cannot be removed safely; the KYC audit actions are kept.
+-- The files the documents point to are not deleted from object storage.
+
+BEGIN;
+DROP TABLE IF EXISTS kyc_documents;
+DROP INDEX IF EXISTS idx_customers_kyc_status;
+ALTER TABLE customers DROP CONSTRAINT IF EXISTS chk_customers_kyc_status;
+ALTER TABLE customers DROP COLUMN IF EXISTS kyc_rejection_reason;
+ALTER TABLE customers DROP COLUMN IF EXISTS kyc_reviewer_id;
+ALTER TABLE customers DROP COLUMN IF EXISTS kyc_reviewed_at;
+ALTER TABLE customers DROP COLUMN IF EXISTS kyc_submitted_at;
+ALTER TABLE customers DROP COLUMN IF EXISTS kyc_status;
+COMMIT;
@@ -0,0 +1,6 @@
+-- Migration: 0191_add_otp_login_audit_actions.sql
+-- Description: Add audit actions of passwordless login with a one-time SMS code
+
+ALTER TYPE audit_action_enum ADD VALUE IF NOT EXISTS 'otp_login_requested';
+ALTER TYPE audit_action_enum ADD VALUE IF NOT EXISTS 'otp_login_success';
+ALTER TYPE audit_action_enum ADD VALUE IF NOT EXISTS 'otp_login_failed';
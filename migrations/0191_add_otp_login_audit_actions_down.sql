@@ -0,0 +1,4 @@
+-- Migration: 0191_add_otp_login_audit_actions_down.sql
+-- Description: Down migration for passwordless login audit actions
+
+-- PostgreSQL enum values cannot be removed safely in a reversible migration.
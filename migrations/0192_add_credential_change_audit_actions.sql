@@ -0,0 +1,7 @@
+-- Migration: 0192_add_credential_change_audit_actions.sql
+-- Description: Add audit actions of self-service password and mobile changes
+
+ALTER TYPE audit_action_enum ADD VALUE IF NOT EXISTS 'password_change_failed';
+ALTER TYPE audit_action_enum ADD VALUE IF NOT EXISTS 'mobile_change_requested';
+ALTER TYPE audit_action_enum ADD VALUE IF NOT EXISTS 'mobile_changed';
+ALTER TYPE audit_action_enum ADD VALUE IF NOT EXISTS 'mobile_change_failed';
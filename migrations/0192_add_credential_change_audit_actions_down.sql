@@ -0,0 +1,4 @@
+-- Migration: 0192_add_credential_change_audit_actions_down.sql
+-- Description: Down migration for password and mobile change audit actions
+
+-- PostgreSQL enum values cannot be removed safely in a reversible migration.
@@ -0,0 +1,36 @@
+-- Migration: 0193_add_customer_profile_changes.sql
+-- Description: Record the field-level history of customer profile changes, plus the profile change audit actions
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS customer_profile_changes (
+    id BIGSERIAL PRIMARY KEY,
+    uuid UUID NOT NULL,
+    customer_id INTEGER NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+    field VARCHAR(40) NOT NULL,
+    old_value VARCHAR(255),
+    new_value VARCHAR(255),
+    status VARCHAR(20) NOT NULL,
+    changed_by_admin_id INTEGER,
+    reviewer_id INTEGER,
+    reviewed_at TIMESTAMP WITH TIME ZONE,
+    rejection_reason VARCHAR(500),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT uk_customer_profile_changes_uuid UNIQUE (uuid),
+    CONSTRAINT chk_customer_profile_changes_field CHECK (field IN ('company_name', 'national_id', 'company_phone', 'company_address', 'postal_code', 'sheba_number')),
+    CONSTRAINT chk_customer_profile_changes_status CHECK (status IN ('applied', 'pending_review', 'rejected', 'superseded'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_customer_profile_changes_customer_id ON customer_profile_changes(customer_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_customer_profile_changes_pending ON customer_profile_changes(id) WHERE status = 'pending_review';
+CREATE UNIQUE INDEX IF NOT EXISTS uk_customer_profile_changes_pending_field ON customer_profile_changes(customer_id, field) WHERE status = 'pending_review';
+
+COMMENT ON TABLE customer_profile_changes IS 'History of customer profile field changes; rows are never deleted';
+COMMENT ON COLUMN customer_profile_changes.status IS 'Changes of sensitive fields (national_id, sheba_number) wait in pending_review until an admin approves them';
+COMMENT ON COLUMN customer_profile_changes.changed_by_admin_id IS 'Admin who made the change while impersonating the customer; NULL when the customer made it';
+
+COMMIT;
+
+ALTER TYPE audit_action_enum ADD VALUE IF NOT EXISTS 'profile_change_requested';
+ALTER TYPE audit_action_enum ADD VALUE IF NOT EXISTS 'admin_profile_change_review';
@@ -0,0 +1,8 @@
+-- Migration: 0193_add_customer_profile_changes_down.sql
+-- Description: Drop customer_profile_changes
+
+-- PostgreSQL enum values cannot be removed safely; the profile change audit actions are kept.
+
+BEGIN;
+DROP TABLE IF EXISTS customer_profile_changes;
+COMMIT;
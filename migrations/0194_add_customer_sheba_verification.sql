@@ -0,0 +1,22 @@
+-- Migration: 0194_add_customer_sheba_verification.sql
+-- Description: Store the bank inquiry result of customer Sheba numbers, plus the Sheba verification audit action
+
+BEGIN;
+
+ALTER TABLE customers
+    ADD COLUMN IF NOT EXISTS sheba_verification VARCHAR(20),
+    ADD COLUMN IF NOT EXISTS sheba_verified_number VARCHAR(26),
+    ADD COLUMN IF NOT EXISTS sheba_owner_name VARCHAR(255),
+    ADD COLUMN IF NOT EXISTS sheba_verified_at TIMESTAMP WITH TIME ZONE;
+
+ALTER TABLE customers DROP CONSTRAINT IF EXISTS chk_customers_sheba_verification;
+ALTER TABLE customers ADD CONSTRAINT chk_customers_sheba_verification
+    CHECK (sheba_verification IS NULL OR sheba_verification IN ('verified', 'owner_mismatch', 'inactive'));
+
+COMMENT ON COLUMN customers.sheba_verification IS 'Result of the last bank inquiry of sheba_verified_number; NULL when never looked up';
+COMMENT ON COLUMN customers.sheba_verified_number IS 'Sheba number the last inquiry was about; the result no longer applies once sheba_number differs';
+COMMENT ON COLUMN customers.sheba_owner_name IS 'Account owners the bank reported, comma separated';
+
+COMMIT;
+
+ALTER TYPE audit_action_enum ADD VALUE IF NOT EXISTS 'sheba_verification';
@@ -0,0 +1,13 @@
+-- Migration: 0194_add_customer_sheba_verification_down.sql
+-- Description: Drop the customer Sheba verification columns
+
+-- PostgreSQL enum values cannot be removed safely; the Sheba verification audit action is kept.
+
+BEGIN;
+ALTER TABLE customers DROP CONSTRAINT IF EXISTS chk_customers_sheba_verification;
+ALTER TABLE customers
+    DROP COLUMN IF EXISTS sheba_verified_at,
+    DROP COLUMN IF EXISTS sheba_owner_name,
+    DROP COLUMN IF EXISTS sheba_verified_number,
+    DROP COLUMN IF EXISTS sheba_verification;
+COMMIT;
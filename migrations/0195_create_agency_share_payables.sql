@@ -0,0 +1,36 @@
+-- Migration: 0195_create_agency_share_payables.sql
+-- Description: Create agency_share_payables for agency shares of recharges settled to the system Sheba number, plus their audit actions
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS agency_share_payables (
+    id BIGSERIAL PRIMARY KEY,
+    uuid UUID NOT NULL UNIQUE DEFAULT gen_random_uuid(),
+    agency_id INTEGER NOT NULL REFERENCES customers(id),
+    customer_id INTEGER NOT NULL REFERENCES customers(id),
+    payment_request_id BIGINT NOT NULL REFERENCES payment_requests(id),
+    amount_with_tax BIGINT NOT NULL,
+    reason VARCHAR(30) NOT NULL,
+    status VARCHAR(10) NOT NULL DEFAULT 'pending',
+    paid_at TIMESTAMP WITH TIME ZONE,
+    paid_by_admin_id BIGINT REFERENCES admins(id),
+    payment_reference VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT (CURRENT_TIMESTAMP AT TIME ZONE 'UTC'),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT (CURRENT_TIMESTAMP AT TIME ZONE 'UTC'),
+
+    CONSTRAINT uq_agency_share_payables_payment_request UNIQUE (payment_request_id),
+    CONSTRAINT chk_agency_share_payables_amount CHECK (amount_with_tax > 0),
+    CONSTRAINT chk_agency_share_payables_reason CHECK (reason IN ('sheba_invalid', 'sheba_not_verified', 'sheba_inquiry_unavailable')),
+    CONSTRAINT chk_agency_share_payables_status CHECK (status IN ('pending', 'paid'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_agency_share_payables_agency_id ON agency_share_payables(agency_id);
+CREATE INDEX IF NOT EXISTS idx_agency_share_payables_pending ON agency_share_payables(id) WHERE status = 'pending';
+
+COMMENT ON TABLE agency_share_payables IS 'Agency shares of recharges Atipay settled to the system Sheba number because the agency''s could not be used; owed to the agency until finance pays them out';
+COMMENT ON COLUMN agency_share_payables.amount_with_tax IS 'Agency share including tax, in toman';
+
+COMMIT;
+
+ALTER TYPE audit_action_enum ADD VALUE IF NOT EXISTS 'agency_settlement_fallback';
+ALTER TYPE audit_action_enum ADD VALUE IF NOT EXISTS 'admin_agency_payable_paid';
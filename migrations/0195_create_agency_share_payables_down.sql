@@ -0,0 +1,8 @@
+-- Migration: 0195_create_agency_share_payables_down.sql
+-- Description: Drop agency_share_payables
+
+-- PostgreSQL enum values cannot be removed safely; the agency payable audit actions are kept.
+
+BEGIN;
+DROP TABLE IF EXISTS agency_share_payables;
+COMMIT;
@@ -0,0 +1,12 @@
+-- Migration: 0196_add_atipay_terminal_to_payment_requests.sql
+-- Description: Record the Atipay terminal each payment request was sent to, so its callback is verified with that terminal's API key
+
+BEGIN;
+
+ALTER TABLE payment_requests ADD COLUMN IF NOT EXISTS atipay_terminal VARCHAR(255) NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_payment_requests_atipay_terminal ON payment_requests(atipay_terminal);
+
+COMMENT ON COLUMN payment_requests.atipay_terminal IS 'Atipay terminal the token was requested from; empty on requests made before terminals were recorded, which used the primary terminal';
+
+COMMIT;
@@ -0,0 +1,9 @@
+-- Migration: 0196_add_atipay_terminal_to_payment_requests_down.sql
+-- Description: Remove the Atipay terminal of payment requests
+
+BEGIN;
+
+DROP INDEX IF EXISTS idx_payment_requests_atipay_terminal;
+ALTER TABLE payment_requests DROP COLUMN IF EXISTS atipay_terminal;
+
+COMMIT;
@@ -0,0 +1,29 @@
+-- Migration: 0197_add_bank_transfer_details_to_deposit_receipts.sql
+-- Description: Record the date and tracking number of the bank transfer behind a deposit receipt, and the bank statement entry finance matched it with
+
+BEGIN;
+
+ALTER TABLE deposit_receipts ADD COLUMN IF NOT EXISTS transfer_date DATE;
+ALTER TABLE deposit_receipts ADD COLUMN IF NOT EXISTS tracking_number VARCHAR(64);
+ALTER TABLE deposit_receipts ADD COLUMN IF NOT EXISTS bank_reference VARCHAR(64);
+ALTER TABLE deposit_receipts ADD COLUMN IF NOT EXISTS matched_amount BIGINT;
+ALTER TABLE deposit_receipts ADD COLUMN IF NOT EXISTS reviewed_at TIMESTAMPTZ;
+
+-- One transfer backs at most one receipt under review or approved; a
+-- rejected receipt frees its tracking number for a corrected one
+CREATE UNIQUE INDEX IF NOT EXISTS uniq_deposit_receipts_tracking_number
+    ON deposit_receipts(tracking_number)
+    WHERE tracking_number IS NOT NULL AND status <> 'rejected' AND deleted_at IS NULL;
+
+-- A bank statement entry credits one receipt only
+CREATE UNIQUE INDEX IF NOT EXISTS uniq_deposit_receipts_bank_reference
+    ON deposit_receipts(bank_reference)
+    WHERE bank_reference IS NOT NULL AND deleted_at IS NULL;
+
+COMMENT ON COLUMN deposit_receipts.transfer_date IS 'Date of the bank transfer as reported by the customer';
+COMMENT ON COLUMN deposit_receipts.tracking_number IS 'Bank tracking number of the transfer as reported by the customer';
+COMMENT ON COLUMN deposit_receipts.bank_reference IS 'Bank statement entry finance matched the receipt with on approval';
+COMMENT ON COLUMN deposit_receipts.matched_amount IS 'Amount of the matched statement entry in Tomans; the wallet is credited with it';
+COMMENT ON COLUMN deposit_receipts.reviewed_at IS 'When finance approved or rejected the receipt';
+
+COMMIT;
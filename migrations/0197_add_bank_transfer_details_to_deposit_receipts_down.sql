@@ -0,0 +1,14 @@
+-- Migration: 0197_add_bank_transfer_details_to_deposit_receipts_down.sql
+-- Description: Remove the bank transfer details of deposit receipts
+
+BEGIN;
+
+DROP INDEX IF EXISTS uniq_deposit_receipts_bank_reference;
+DROP INDEX IF EXISTS uniq_deposit_receipts_tracking_number;
+ALTER TABLE deposit_receipts DROP COLUMN IF EXISTS reviewed_at;
+ALTER TABLE deposit_receipts DROP COLUMN IF EXISTS matched_amount;
+ALTER TABLE deposit_receipts DROP COLUMN IF EXISTS bank_reference;
+ALTER TABLE deposit_receipts DROP COLUMN IF EXISTS tracking_number;
+ALTER TABLE deposit_receipts DROP COLUMN IF EXISTS transfer_date;
+
+COMMIT;
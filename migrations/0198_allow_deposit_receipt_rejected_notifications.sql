@@ -0,0 +1,10 @@
+-- Migration: 0198_allow_deposit_receipt_rejected_notifications.sql
+-- Description: Allow the inbox notification of rejected deposit receipts
+
+BEGIN;
+
+ALTER TABLE notifications DROP CONSTRAINT IF EXISTS chk_notifications_kind;
+ALTER TABLE notifications ADD CONSTRAINT chk_notifications_kind
+    CHECK (kind IN ('campaign_approved', 'campaign_rejected', 'payment_credited', 'low_balance', 'campaign_expired', 'deposit_receipt_rejected'));
+
+COMMIT;
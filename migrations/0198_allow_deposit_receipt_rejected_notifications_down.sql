@@ -0,0 +1,9 @@
+-- Migration: 0198_allow_deposit_receipt_rejected_notifications_down.sql
+-- Description: Drop the inbox notifications of rejected deposit receipts and restore the previous kinds
+
+BEGIN;
+DELETE FROM notifications WHERE kind = 'deposit_receipt_rejected';
+ALTER TABLE notifications DROP CONSTRAINT IF EXISTS chk_notifications_kind;
+ALTER TABLE notifications ADD CONSTRAINT chk_notifications_kind
+    CHECK (kind IN ('campaign_approved', 'campaign_rejected', 'payment_credited', 'low_balance', 'campaign_expired'));
+COMMIT;
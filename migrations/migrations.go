@@ -0,0 +1,10 @@
+// Package migrations embeds the ordered SQL schema history so the binaries
+// and the migrate command carry the migrations they were built with.
+package migrations
+
+import "embed"
+
+// FS holds every migration file of this directory
+//
+//go:embed *.sql
+var FS embed.FS
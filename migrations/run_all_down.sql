@@ -3,6 +3,243 @@
 
 \echo 'Starting database rollback...'
 
+\echo 'Running 0198_allow_deposit_receipt_rejected_notifications_down.sql...'
+\i migrations/0198_allow_deposit_receipt_rejected_notifications_down.sql
+
+\echo 'Running 0197_add_bank_transfer_details_to_deposit_receipts_down.sql...'
+\i migrations/0197_add_bank_transfer_details_to_deposit_receipts_down.sql
+
+\echo 'Running 0196_add_atipay_terminal_to_payment_requests_down.sql...'
+\i migrations/0196_add_atipay_terminal_to_payment_requests_down.sql
+
+\echo 'Running 0195_create_agency_share_payables_down.sql...'
+\i migrations/0195_create_agency_share_payables_down.sql
+
+\echo 'Running 0194_add_customer_sheba_verification_down.sql...'
+\i migrations/0194_add_customer_sheba_verification_down.sql
+
+\echo 'Running 0193_add_customer_profile_changes_down.sql...'
+\i migrations/0193_add_customer_profile_changes_down.sql
+
+\echo 'Running 0192_add_credential_change_audit_actions_down.sql...'
+\i migrations/0192_add_credential_change_audit_actions_down.sql
+
+\echo 'Running 0191_add_otp_login_audit_actions_down.sql...'
+\i migrations/0191_add_otp_login_audit_actions_down.sql
+
+\echo 'Running 0190_add_customer_kyc_down.sql...'
+\i migrations/0190_add_customer_kyc_down.sql
+
+\echo 'Running 0189_add_customer_suspension_down.sql...'
+\i migrations/0189_add_customer_suspension_down.sql
+
+\echo 'Running 0188_add_admin_bulk_audit_actions_down.sql...'
+\i migrations/0188_add_admin_bulk_audit_actions_down.sql
+
+\echo 'Running 0187_allow_campaign_expired_notifications_down.sql...'
+\i migrations/0187_allow_campaign_expired_notifications_down.sql
+
+\echo 'Running 0186_add_wallet_transfer_enum_values_down.sql...'
+\i migrations/0186_add_wallet_transfer_enum_values_down.sql
+
+\echo 'Running 0185_create_wallet_transfers_down.sql...'
+\i migrations/0185_create_wallet_transfers_down.sql
+
+\echo 'Running 0184_create_sms_recipient_retries_down.sql...'
+\i migrations/0184_create_sms_recipient_retries_down.sql
+
+\echo 'Running 0183_add_operator_routing_down.sql...'
+\i migrations/0183_add_operator_routing_down.sql
+
+\echo 'Running 0182_create_audience_color_transitions_down.sql...'
+\i migrations/0182_create_audience_color_transitions_down.sql
+
+\echo 'Running 0181_add_sms_frequency_cap_down.sql...'
+\i migrations/0181_add_sms_frequency_cap_down.sql
+
+\echo 'Running 0180_add_campaign_status_job_state_down.sql...'
+\i migrations/0180_add_campaign_status_job_state_down.sql
+
+\echo 'Running 0179_create_push_devices_down.sql...'
+\i migrations/0179_create_push_devices_down.sql
+
+\echo 'Running 0178_add_customer_telegram_link_down.sql...'
+\i migrations/0178_add_customer_telegram_link_down.sql
+
+\echo 'Running 0177_create_notifications_down.sql...'
+\i migrations/0177_create_notifications_down.sql
+
+\echo 'Running 0176_add_customer_search_indexes_down.sql...'
+\i migrations/0176_add_customer_search_indexes_down.sql
+
+\echo 'Running 0175_add_customer_timeline_indexes_down.sql...'
+\i migrations/0175_add_customer_timeline_indexes_down.sql
+
+\echo 'Running 0174_add_version_to_wallets_and_payment_requests_down.sql...'
+\i migrations/0174_add_version_to_wallets_and_payment_requests_down.sql
+
+\echo 'Running 0173_add_soft_delete_to_core_models_down.sql...'
+\i migrations/0173_add_soft_delete_to_core_models_down.sql
+
+\echo 'Running 0172_add_sandbox_mode_down.sql...'
+\i migrations/0172_add_sandbox_mode_down.sql
+
+\echo 'Running 0171_add_job_cancelled_status_down.sql...'
+\i migrations/0171_add_job_cancelled_status_down.sql
+
+\echo 'Running 0170_create_jobs_down.sql...'
+\i migrations/0170_create_jobs_down.sql
+
+\echo 'Running 0169_create_reporting_rollups_down.sql...'
+\i migrations/0169_create_reporting_rollups_down.sql
+
+\echo 'Running 0168_add_financial_report_indexes_down.sql...'
+\i migrations/0168_add_financial_report_indexes_down.sql
+
+\echo 'Running 0167_add_agency_discount_tiers_and_schedule_down.sql...'
+\i migrations/0167_add_agency_discount_tiers_and_schedule_down.sql
+
+\echo 'Running 0166_add_agency_delegation_audit_actions_down.sql...'
+\i migrations/0166_add_agency_delegation_audit_actions_down.sql
+
+\echo 'Running 0165_create_agency_delegations_down.sql...'
+\i migrations/0165_create_agency_delegations_down.sql
+
+\echo 'Running 0164_create_moadian_submissions_down.sql...'
+\i migrations/0164_create_moadian_submissions_down.sql
+
+\echo 'Running 0163_create_tax_invoices_down.sql...'
+\i migrations/0163_create_tax_invoices_down.sql
+
+\echo 'Running 0162_add_postpaid_billing_audit_actions_down.sql...'
+\i migrations/0162_add_postpaid_billing_audit_actions_down.sql
+
+\echo 'Running 0161_create_postpaid_billing_down.sql...'
+\i migrations/0161_create_postpaid_billing_down.sql
+
+\echo 'Running 0160_add_wallet_adjustment_audit_actions_down.sql...'
+\i migrations/0160_add_wallet_adjustment_audit_actions_down.sql
+
+\echo 'Running 0159_create_wallet_adjustment_requests_down.sql...'
+\i migrations/0159_create_wallet_adjustment_requests_down.sql
+
+\echo 'Running 0158_add_atipay_reconciliation_audit_actions_down.sql...'
+\i migrations/0158_add_atipay_reconciliation_audit_actions_down.sql
+
+\echo 'Running 0157_create_atipay_reconciliation_entries_down.sql...'
+\i migrations/0157_create_atipay_reconciliation_entries_down.sql
+
+\echo 'Running 0156_create_crypto_webhook_events_down.sql...'
+\i migrations/0156_create_crypto_webhook_events_down.sql
+
+\echo 'Running 0155_add_crypto_payment_rate_fetched_at_down.sql...'
+\i migrations/0155_add_crypto_payment_rate_fetched_at_down.sql
+
+\echo 'Running 0154_add_config_reloaded_audit_action_down.sql...'
+\i migrations/0154_add_config_reloaded_audit_action_down.sql
+
+\echo 'Running 0153_add_customer_preferred_locale_down.sql...'
+\i migrations/0153_add_customer_preferred_locale_down.sql
+
+\echo 'Running 0152_add_customer_data_request_audit_actions_down.sql...'
+\i migrations/0152_add_customer_data_request_audit_actions_down.sql
+
+\echo 'Running 0151_create_customer_data_requests_down.sql...'
+\i migrations/0151_create_customer_data_requests_down.sql
+
+\echo 'Running 0150_add_customer_impersonation_audit_action_down.sql...'
+\i migrations/0150_add_customer_impersonation_audit_action_down.sql
+
+\echo 'Running 0149_add_admin_security_audit_actions_down.sql...'
+\i migrations/0149_add_admin_security_audit_actions_down.sql
+
+\echo 'Running 0148_add_admin_totp_down.sql...'
+\i migrations/0148_add_admin_totp_down.sql
+
+\echo 'Running 0147_add_suspicious_login_audit_actions_down.sql...'
+\i migrations/0147_add_suspicious_login_audit_actions_down.sql
+
+\echo 'Running 0146_create_customer_known_devices_down.sql...'
+\i migrations/0146_create_customer_known_devices_down.sql
+
+\echo 'Running 0145_add_session_revoked_audit_action_down.sql...'
+\i migrations/0145_add_session_revoked_audit_action_down.sql
+
+\echo 'Running 0144_add_admin_force_logout_audit_action_down.sql...'
+\i migrations/0144_add_admin_force_logout_audit_action_down.sql
+
+\echo 'Running 0143_create_partition_archives_down.sql...'
+\i migrations/0143_create_partition_archives_down.sql
+
+\echo 'Running 0142_partition_sent_sms_and_audit_log_down.sql...'
+\i migrations/0142_partition_sent_sms_and_audit_log_down.sql
+
+\echo 'Running 0141_add_line_number_reservation_audit_actions_down.sql...'
+\i migrations/0141_add_line_number_reservation_audit_actions_down.sql
+
+\echo 'Running 0140_create_line_number_tiers_and_reservations_down.sql...'
+\i migrations/0140_create_line_number_tiers_and_reservations_down.sql
+
+\echo 'Running 0139_add_line_number_rate_limits_down.sql...'
+\i migrations/0139_add_line_number_rate_limits_down.sql
+
+\echo 'Running 0138_add_sending_quota_audit_actions_down.sql...'
+\i migrations/0138_add_sending_quota_audit_actions_down.sql
+
+\echo 'Running 0137_create_customer_sending_quotas_down.sql...'
+\i migrations/0137_create_customer_sending_quotas_down.sql
+
+\echo 'Running 0136_add_campaign_changes_requested_enum_values_down.sql...'
+\i migrations/0136_add_campaign_changes_requested_enum_values_down.sql
+
+\echo 'Running 0135_create_campaign_reviews_down.sql...'
+\i migrations/0135_create_campaign_reviews_down.sql
+
+\echo 'Running 0134_add_blacklist_audit_actions_down.sql...'
+\i migrations/0134_add_blacklist_audit_actions_down.sql
+
+\echo 'Running 0133_create_blacklisted_numbers_down.sql...'
+\i migrations/0133_create_blacklisted_numbers_down.sql
+
+\echo 'Running 0132_normalize_phone_numbers_down.sql...'
+\i migrations/0132_normalize_phone_numbers_down.sql
+
+\echo 'Running 0131_add_audience_import_audit_actions_down.sql...'
+\i migrations/0131_add_audience_import_audit_actions_down.sql
+
+\echo 'Running 0130_create_audience_import_jobs_down.sql...'
+\i migrations/0130_create_audience_import_jobs_down.sql
+
+\echo 'Running 0129_add_audience_profile_attributes_down.sql...'
+\i migrations/0129_add_audience_profile_attributes_down.sql
+
+\echo 'Running 0128_add_sent_sms_variant_down.sql...'
+\i migrations/0128_add_sent_sms_variant_down.sql
+
+\echo 'Running 0127_add_campaign_execution_stop_columns_down.sql...'
+\i migrations/0127_add_campaign_execution_stop_columns_down.sql
+
+\echo 'Running 0126_add_campaign_execution_control_enum_values_down.sql...'
+\i migrations/0126_add_campaign_execution_control_enum_values_down.sql
+
+\echo 'Running 0125_add_campaign_recurrence_audit_actions_down.sql...'
+\i migrations/0125_add_campaign_recurrence_audit_actions_down.sql
+
+\echo 'Running 0124_add_campaign_recurrence_down.sql...'
+\i migrations/0124_add_campaign_recurrence_down.sql
+
+\echo 'Running 0123_add_campaign_template_audit_actions_down.sql...'
+\i migrations/0123_add_campaign_template_audit_actions_down.sql
+
+\echo 'Running 0122_create_campaign_templates_down.sql...'
+\i migrations/0122_create_campaign_templates_down.sql
+
+\echo 'Running 0121_add_sms_tariff_audit_actions_down.sql...'
+\i migrations/0121_add_sms_tariff_audit_actions_down.sql
+
+\echo 'Running 0120_create_sms_tariffs_down.sql...'
+\i migrations/0120_create_sms_tariffs_down.sql
+
 \echo 'Running 0119_convert_bundle_tag_evaluation_ids_to_bigserial_down.sql...'
 \i migrations/0119_convert_bundle_tag_evaluation_ids_to_bigserial_down.sql
 
@@ -365,5 +365,242 @@
 \echo 'Running 0119_convert_bundle_tag_evaluation_ids_to_bigserial.sql...'
 \i migrations/0119_convert_bundle_tag_evaluation_ids_to_bigserial.sql
 
+\echo 'Running 0120_create_sms_tariffs.sql...'
+\i migrations/0120_create_sms_tariffs.sql
+
+\echo 'Running 0121_add_sms_tariff_audit_actions.sql...'
+\i migrations/0121_add_sms_tariff_audit_actions.sql
+
+\echo 'Running 0122_create_campaign_templates.sql...'
+\i migrations/0122_create_campaign_templates.sql
+
+\echo 'Running 0123_add_campaign_template_audit_actions.sql...'
+\i migrations/0123_add_campaign_template_audit_actions.sql
+
+\echo 'Running 0124_add_campaign_recurrence.sql...'
+\i migrations/0124_add_campaign_recurrence.sql
+
+\echo 'Running 0125_add_campaign_recurrence_audit_actions.sql...'
+\i migrations/0125_add_campaign_recurrence_audit_actions.sql
+
+\echo 'Running 0126_add_campaign_execution_control_enum_values.sql...'
+\i migrations/0126_add_campaign_execution_control_enum_values.sql
+
+\echo 'Running 0127_add_campaign_execution_stop_columns.sql...'
+\i migrations/0127_add_campaign_execution_stop_columns.sql
+
+\echo 'Running 0128_add_sent_sms_variant.sql...'
+\i migrations/0128_add_sent_sms_variant.sql
+
+\echo 'Running 0129_add_audience_profile_attributes.sql...'
+\i migrations/0129_add_audience_profile_attributes.sql
+
+\echo 'Running 0130_create_audience_import_jobs.sql...'
+\i migrations/0130_create_audience_import_jobs.sql
+
+\echo 'Running 0131_add_audience_import_audit_actions.sql...'
+\i migrations/0131_add_audience_import_audit_actions.sql
+
+\echo 'Running 0132_normalize_phone_numbers.sql...'
+\i migrations/0132_normalize_phone_numbers.sql
+
+\echo 'Running 0133_create_blacklisted_numbers.sql...'
+\i migrations/0133_create_blacklisted_numbers.sql
+
+\echo 'Running 0134_add_blacklist_audit_actions.sql...'
+\i migrations/0134_add_blacklist_audit_actions.sql
+
+\echo 'Running 0135_create_campaign_reviews.sql...'
+\i migrations/0135_create_campaign_reviews.sql
+
+\echo 'Running 0136_add_campaign_changes_requested_enum_values.sql...'
+\i migrations/0136_add_campaign_changes_requested_enum_values.sql
+
+\echo 'Running 0137_create_customer_sending_quotas.sql...'
+\i migrations/0137_create_customer_sending_quotas.sql
+
+\echo 'Running 0138_add_sending_quota_audit_actions.sql...'
+\i migrations/0138_add_sending_quota_audit_actions.sql
+
+\echo 'Running 0139_add_line_number_rate_limits.sql...'
+\i migrations/0139_add_line_number_rate_limits.sql
+
+\echo 'Running 0140_create_line_number_tiers_and_reservations.sql...'
+\i migrations/0140_create_line_number_tiers_and_reservations.sql
+
+\echo 'Running 0141_add_line_number_reservation_audit_actions.sql...'
+\i migrations/0141_add_line_number_reservation_audit_actions.sql
+
+\echo 'Running 0142_partition_sent_sms_and_audit_log.sql...'
+\i migrations/0142_partition_sent_sms_and_audit_log.sql
+
+\echo 'Running 0143_create_partition_archives.sql...'
+\i migrations/0143_create_partition_archives.sql
+
+\echo 'Running 0144_add_admin_force_logout_audit_action.sql...'
+\i migrations/0144_add_admin_force_logout_audit_action.sql
+
+\echo 'Running 0145_add_session_revoked_audit_action.sql...'
+\i migrations/0145_add_session_revoked_audit_action.sql
+
+\echo 'Running 0146_create_customer_known_devices.sql...'
+\i migrations/0146_create_customer_known_devices.sql
+
+\echo 'Running 0147_add_suspicious_login_audit_actions.sql...'
+\i migrations/0147_add_suspicious_login_audit_actions.sql
+
+\echo 'Running 0148_add_admin_totp.sql...'
+\i migrations/0148_add_admin_totp.sql
+
+\echo 'Running 0149_add_admin_security_audit_actions.sql...'
+\i migrations/0149_add_admin_security_audit_actions.sql
+
+\echo 'Running 0150_add_customer_impersonation_audit_action.sql...'
+\i migrations/0150_add_customer_impersonation_audit_action.sql
+
+\echo 'Running 0151_create_customer_data_requests.sql...'
+\i migrations/0151_create_customer_data_requests.sql
+
+\echo 'Running 0152_add_customer_data_request_audit_actions.sql...'
+\i migrations/0152_add_customer_data_request_audit_actions.sql
+
+\echo 'Running 0153_add_customer_preferred_locale.sql...'
+\i migrations/0153_add_customer_preferred_locale.sql
+
+\echo 'Running 0154_add_config_reloaded_audit_action.sql...'
+\i migrations/0154_add_config_reloaded_audit_action.sql
+
+\echo 'Running 0155_add_crypto_payment_rate_fetched_at.sql...'
+\i migrations/0155_add_crypto_payment_rate_fetched_at.sql
+
+\echo 'Running 0156_create_crypto_webhook_events.sql...'
+\i migrations/0156_create_crypto_webhook_events.sql
+
+\echo 'Running 0157_create_atipay_reconciliation_entries.sql...'
+\i migrations/0157_create_atipay_reconciliation_entries.sql
+
+\echo 'Running 0158_add_atipay_reconciliation_audit_actions.sql...'
+\i migrations/0158_add_atipay_reconciliation_audit_actions.sql
+
+\echo 'Running 0159_create_wallet_adjustment_requests.sql...'
+\i migrations/0159_create_wallet_adjustment_requests.sql
+
+\echo 'Running 0160_add_wallet_adjustment_audit_actions.sql...'
+\i migrations/0160_add_wallet_adjustment_audit_actions.sql
+
+\echo 'Running 0161_create_postpaid_billing.sql...'
+\i migrations/0161_create_postpaid_billing.sql
+
+\echo 'Running 0162_add_postpaid_billing_audit_actions.sql...'
+\i migrations/0162_add_postpaid_billing_audit_actions.sql
+
+\echo 'Running 0163_create_tax_invoices.sql...'
+\i migrations/0163_create_tax_invoices.sql
+
+\echo 'Running 0164_create_moadian_submissions.sql...'
+\i migrations/0164_create_moadian_submissions.sql
+
+\echo 'Running 0165_create_agency_delegations.sql...'
+\i migrations/0165_create_agency_delegations.sql
+
+\echo 'Running 0166_add_agency_delegation_audit_actions.sql...'
+\i migrations/0166_add_agency_delegation_audit_actions.sql
+
+\echo 'Running 0167_add_agency_discount_tiers_and_schedule.sql...'
+\i migrations/0167_add_agency_discount_tiers_and_schedule.sql
+
+\echo 'Running 0168_add_financial_report_indexes.sql...'
+\i migrations/0168_add_financial_report_indexes.sql
+
+\echo 'Running 0169_create_reporting_rollups.sql...'
+\i migrations/0169_create_reporting_rollups.sql
+
+\echo 'Running 0170_create_jobs.sql...'
+\i migrations/0170_create_jobs.sql
+
+\echo 'Running 0171_add_job_cancelled_status.sql...'
+\i migrations/0171_add_job_cancelled_status.sql
+
+\echo 'Running 0172_add_sandbox_mode.sql...'
+\i migrations/0172_add_sandbox_mode.sql
+
+\echo 'Running 0173_add_soft_delete_to_core_models.sql...'
+\i migrations/0173_add_soft_delete_to_core_models.sql
+
+\echo 'Running 0174_add_version_to_wallets_and_payment_requests.sql...'
+\i migrations/0174_add_version_to_wallets_and_payment_requests.sql
+
+\echo 'Running 0175_add_customer_timeline_indexes.sql...'
+\i migrations/0175_add_customer_timeline_indexes.sql
+
+\echo 'Running 0176_add_customer_search_indexes.sql...'
+\i migrations/0176_add_customer_search_indexes.sql
+
+\echo 'Running 0177_create_notifications.sql...'
+\i migrations/0177_create_notifications.sql
+
+\echo 'Running 0178_add_customer_telegram_link.sql...'
+\i migrations/0178_add_customer_telegram_link.sql
+
+\echo 'Running 0179_create_push_devices.sql...'
+\i migrations/0179_create_push_devices.sql
+
+\echo 'Running 0180_add_campaign_status_job_state.sql...'
+\i migrations/0180_add_campaign_status_job_state.sql
+
+\echo 'Running 0181_add_sms_frequency_cap.sql...'
+\i migrations/0181_add_sms_frequency_cap.sql
+
+\echo 'Running 0182_create_audience_color_transitions.sql...'
+\i migrations/0182_create_audience_color_transitions.sql
+
+\echo 'Running 0183_add_operator_routing.sql...'
+\i migrations/0183_add_operator_routing.sql
+
+\echo 'Running 0184_create_sms_recipient_retries.sql...'
+\i migrations/0184_create_sms_recipient_retries.sql
+
+\echo 'Running 0185_create_wallet_transfers.sql...'
+\i migrations/0185_create_wallet_transfers.sql
+
+\echo 'Running 0186_add_wallet_transfer_enum_values.sql...'
+\i migrations/0186_add_wallet_transfer_enum_values.sql
+
+\echo 'Running 0187_allow_campaign_expired_notifications.sql...'
+\i migrations/0187_allow_campaign_expired_notifications.sql
+
+\echo 'Running 0188_add_admin_bulk_audit_actions.sql...'
+\i migrations/0188_add_admin_bulk_audit_actions.sql
+
+\echo 'Running 0189_add_customer_suspension.sql...'
+\i migrations/0189_add_customer_suspension.sql
+
+\echo 'Running 0190_add_customer_kyc.sql...'
+\i migrations/0190_add_customer_kyc.sql
+
+\echo 'Running 0191_add_otp_login_audit_actions.sql...'
+\i migrations/0191_add_otp_login_audit_actions.sql
+
+\echo 'Running 0192_add_credential_change_audit_actions.sql...'
+\i migrations/0192_add_credential_change_audit_actions.sql
+
+\echo 'Running 0193_add_customer_profile_changes.sql...'
+\i migrations/0193_add_customer_profile_changes.sql
+
+\echo 'Running 0194_add_customer_sheba_verification.sql...'
+\i migrations/0194_add_customer_sheba_verification.sql
+
+\echo 'Running 0195_create_agency_share_payables.sql...'
+\i migrations/0195_create_agency_share_payables.sql
+
+\echo 'Running 0196_add_atipay_terminal_to_payment_requests.sql...'
+\i migrations/0196_add_atipay_terminal_to_payment_requests.sql
+
+\echo 'Running 0197_add_bank_transfer_details_to_deposit_receipts.sql...'
+\i migrations/0197_add_bank_transfer_details_to_deposit_receipts.sql
+
+\echo 'Running 0198_allow_deposit_receipt_rejected_notifications.sql...'
+\i migrations/0198_allow_deposit_receipt_rejected_notifications.sql
+
 \echo 'All migrations completed successfully!'
 \echo 'Database schema is now ready for the Yamata no Orochi wallet, and payment system with comprehensive audit logging and tax collection.' 
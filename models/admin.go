@@ -18,6 +18,11 @@ type Admin struct {
 	AllowedPermissions pq.StringArray `gorm:"type:text[];not null;default:'{}'" json:"allowed_permissions"`
 	DeniedPermissions  pq.StringArray `gorm:"type:text[];not null;default:'{}'" json:"denied_permissions"`
 
+	// TOTPSecret is the base32 secret of the admin's authenticator app; nil
+	// until it is confirmed at the first login
+	TOTPSecret    *string    `gorm:"column:totp_secret;size:64" json:"-"`
+	TOTPEnabledAt *time.Time `gorm:"column:totp_enabled_at" json:"totp_enabled_at,omitempty"`
+
 	IsActive    *bool      `gorm:"default:true;index:idx_admins_is_active" json:"is_active"`
 	CreatedAt   time.Time  `gorm:"default:(CURRENT_TIMESTAMP AT TIME ZONE 'UTC');index:idx_admins_created_at" json:"created_at"`
 	UpdatedAt   time.Time  `gorm:"default:(CURRENT_TIMESTAMP AT TIME ZONE 'UTC')" json:"updated_at"`
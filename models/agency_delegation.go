@@ -0,0 +1,67 @@
+package models
+
+import (
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+// Permissions a customer can delegate to their referrer agency
+const (
+	// AgencyDelegationPermissionCreateCampaigns lets the agency create,
+	// edit, submit and clone the customer's campaigns
+	AgencyDelegationPermissionCreateCampaigns = "create_campaigns"
+	// AgencyDelegationPermissionManageCampaigns lets the agency cancel,
+	// pause and resume the customer's campaigns
+	AgencyDelegationPermissionManageCampaigns = "manage_campaigns"
+)
+
+// AgencyDelegationPermissions lists the permissions a grant can hold
+var AgencyDelegationPermissions = []string{
+	AgencyDelegationPermissionCreateCampaigns,
+	AgencyDelegationPermissionManageCampaigns,
+}
+
+// AgencyDelegation is a customer's grant letting their referrer agency act
+// for them. A customer has at most one active grant; revoking it keeps the
+// row for the audit trail.
+type AgencyDelegation struct {
+	ID          uint           `gorm:"primaryKey" json:"id"`
+	UUID        uuid.UUID      `gorm:"type:uuid;not null;uniqueIndex:uk_agency_delegations_uuid" json:"uuid"`
+	AgencyID    uint           `gorm:"not null;index:idx_agency_delegations_agency_id" json:"agency_id"`
+	CustomerID  uint           `gorm:"not null;index:idx_agency_delegations_customer_id" json:"customer_id"`
+	Permissions pq.StringArray `gorm:"type:text[];not null;default:'{}'" json:"permissions"`
+	// RevokedAt is set when the customer withdrew the grant
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt time.Time  `gorm:"default:(CURRENT_TIMESTAMP AT TIME ZONE 'UTC')" json:"created_at"`
+	UpdatedAt time.Time  `gorm:"default:(CURRENT_TIMESTAMP AT TIME ZONE 'UTC')" json:"updated_at"`
+}
+
+func (AgencyDelegation) TableName() string {
+	return "agency_delegations"
+}
+
+// BeforeCreate ensures UUID is set for AgencyDelegation
+func (d *AgencyDelegation) BeforeCreate(tx *gorm.DB) error {
+	if d.UUID == uuid.Nil {
+		d.UUID = uuid.New()
+	}
+	return nil
+}
+
+// Allows reports whether the grant is active and holds permission
+func (d *AgencyDelegation) Allows(permission string) bool {
+	return d.RevokedAt == nil && slices.Contains(d.Permissions, permission)
+}
+
+// AgencyDelegationFilter represents filter criteria for delegation queries
+type AgencyDelegationFilter struct {
+	ID         *uint      `json:"id,omitempty"`
+	UUID       *uuid.UUID `json:"uuid,omitempty"`
+	AgencyID   *uint      `json:"agency_id,omitempty"`
+	CustomerID *uint      `json:"customer_id,omitempty"`
+	IsActive   *bool      `json:"is_active,omitempty"`
+}
@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"time"
 
+	"github.com/amirphl/Yamata-no-Orochi/pricing"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
@@ -16,6 +18,12 @@ type AgencyDiscount struct {
 	CustomerID uint      `gorm:"not null;index:idx_agency_discounts_customer_id" json:"customer_id"`
 	// DiscountRate must be between 0 and 0.5 inclusive
 	DiscountRate float64 `gorm:"type:numeric(5,4);not null" json:"discount_rate"`
+	// Tiers raise DiscountRate for the charges of months in which the
+	// customer has already charged past their thresholds
+	Tiers pricing.DiscountTiers `gorm:"type:jsonb;serializer:json;not null;default:'[]'" json:"tiers"`
+	// EffectiveFrom is when the discount starts to apply; later than
+	// CreatedAt for scheduled rate changes
+	EffectiveFrom time.Time `gorm:"not null;default:(CURRENT_TIMESTAMP AT TIME ZONE 'UTC')" json:"effective_from"`
 	// ExpiresAt can be null to indicate the discount does not expire
 	ExpiresAt *time.Time `json:"expires_at,omitempty"`
 
@@ -31,14 +39,27 @@ func (AgencyDiscount) TableName() string {
 	return "agency_discounts"
 }
 
-// BeforeCreate ensures UUID is set for AgencyDiscount
+// BeforeCreate ensures UUID is set for AgencyDiscount and that discounts
+// without a schedule or tiers apply right away at their base rate
 func (a *AgencyDiscount) BeforeCreate(tx *gorm.DB) error {
 	if a.UUID == uuid.Nil {
 		a.UUID = uuid.New()
 	}
+	if a.EffectiveFrom.IsZero() {
+		a.EffectiveFrom = utils.UTCNow()
+	}
+	if a.Tiers == nil {
+		a.Tiers = pricing.DiscountTiers{}
+	}
 	return nil
 }
 
+// RateFor returns the rate of a charge made when the customer had already
+// charged monthlyAmountWithTax in the same month
+func (a *AgencyDiscount) RateFor(monthlyAmountWithTax uint64) float64 {
+	return a.Tiers.RateFor(a.DiscountRate, monthlyAmountWithTax)
+}
+
 // AgencyDiscountFilter represents filter criteria for agency discount queries
 type AgencyDiscountFilter struct {
 	ID            *uint      `json:"id,omitempty"`
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AgencySettlementFallbackReason is why an agency's share of a recharge was
+// settled to the system Sheba number instead of the agency's
+type AgencySettlementFallbackReason string
+
+const (
+	AgencySettlementShebaInvalid            AgencySettlementFallbackReason = "sheba_invalid"
+	AgencySettlementShebaNotVerified        AgencySettlementFallbackReason = "sheba_not_verified"
+	AgencySettlementShebaInquiryUnavailable AgencySettlementFallbackReason = "sheba_inquiry_unavailable"
+)
+
+// AgencySharePayableStatus is whether finance has paid an agency share out
+type AgencySharePayableStatus string
+
+const (
+	AgencySharePayablePending AgencySharePayableStatus = "pending"
+	AgencySharePayablePaid    AgencySharePayableStatus = "paid"
+)
+
+// AgencySharePayable is an agency's share of a recharge that Atipay settled
+// to the system Sheba number; the platform owes it to the agency until
+// finance pays it out by hand
+type AgencySharePayable struct {
+	ID               uint                           `gorm:"primaryKey" json:"id"`
+	UUID             uuid.UUID                      `gorm:"type:uuid;uniqueIndex;not null;default:gen_random_uuid()" json:"uuid"`
+	AgencyID         uint                           `gorm:"not null;index" json:"agency_id"`
+	CustomerID       uint                           `gorm:"not null" json:"customer_id"`
+	PaymentRequestID uint                           `gorm:"not null;uniqueIndex" json:"payment_request_id"`
+	AmountWithTax    uint64                         `gorm:"type:bigint;not null" json:"amount_with_tax"` // Tomans
+	Reason           AgencySettlementFallbackReason `gorm:"type:varchar(30);not null" json:"reason"`
+	Status           AgencySharePayableStatus       `gorm:"type:varchar(10);not null;default:'pending'" json:"status"`
+	PaidAt           *time.Time                     `json:"paid_at,omitempty"`
+	PaidByAdminID    *uint                          `json:"paid_by_admin_id,omitempty"`
+	PaymentReference string                         `gorm:"type:varchar(255)" json:"payment_reference,omitempty"`
+	CreatedAt        time.Time                      `gorm:"default:(CURRENT_TIMESTAMP AT TIME ZONE 'UTC')" json:"created_at"`
+	UpdatedAt        time.Time                      `gorm:"default:(CURRENT_TIMESTAMP AT TIME ZONE 'UTC')" json:"updated_at"`
+}
+
+func (AgencySharePayable) TableName() string {
+	return "agency_share_payables"
+}
+
+// AgencySharePayableFilter represents filter criteria for payable queries
+type AgencySharePayableFilter struct {
+	ID               *uint
+	UUID             *uuid.UUID
+	AgencyID         *uint
+	PaymentRequestID *uint
+	Status           *AgencySharePayableStatus
+}
@@ -0,0 +1,73 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AtipayReconciliationStatus is the outcome of matching one settlement
+// report entry, or one completed payment request, against the other side
+type AtipayReconciliationStatus string
+
+const (
+	// AtipayReconciliationMatched: settled by Atipay and completed here for the same amount
+	AtipayReconciliationMatched AtipayReconciliationStatus = "matched"
+	// AtipayReconciliationAmountMismatch: settled for another amount than was charged
+	AtipayReconciliationAmountMismatch AtipayReconciliationStatus = "amount_mismatch"
+	// AtipayReconciliationStatusMismatch: settled, but the payment request is not completed
+	AtipayReconciliationStatusMismatch AtipayReconciliationStatus = "status_mismatch"
+	// AtipayReconciliationMissingInSystem: settled, but no payment request matches it
+	AtipayReconciliationMissingInSystem AtipayReconciliationStatus = "missing_in_system"
+	// AtipayReconciliationMissingInReport: completed here, but not in the report
+	AtipayReconciliationMissingInReport AtipayReconciliationStatus = "missing_in_report"
+	// AtipayReconciliationDuplicate: the same payment appears more than once in the report
+	AtipayReconciliationDuplicate AtipayReconciliationStatus = "duplicate"
+)
+
+// Valid reports whether s is a known reconciliation status
+func (s AtipayReconciliationStatus) Valid() bool {
+	switch s {
+	case AtipayReconciliationMatched, AtipayReconciliationAmountMismatch, AtipayReconciliationStatusMismatch,
+		AtipayReconciliationMissingInSystem, AtipayReconciliationMissingInReport, AtipayReconciliationDuplicate:
+		return true
+	}
+	return false
+}
+
+// AtipayReconciliationEntry is one line of the reconciliation of an Atipay
+// daily settlement report against the payment requests completed that day.
+// Reconciling a day again replaces its entries.
+type AtipayReconciliationEntry struct {
+	ID         uint                       `gorm:"primaryKey;autoIncrement" json:"id"`
+	UUID       uuid.UUID                  `gorm:"type:uuid;uniqueIndex;not null;default:gen_random_uuid()" json:"uuid"`
+	ReportDate time.Time                  `gorm:"type:date;not null;index" json:"report_date"` // Tehran calendar day
+	Status     AtipayReconciliationStatus `gorm:"type:varchar(32);not null;index" json:"status"`
+	Source     string                     `gorm:"type:varchar(16);not null" json:"source"` // upload|pull
+
+	ReferenceNumber   string `gorm:"type:varchar(255);index" json:"reference_number"`
+	RRN               string `gorm:"type:varchar(255)" json:"rrn"`
+	ReservationNumber string `gorm:"type:varchar(255)" json:"reservation_number"` // our invoice number
+
+	PaymentRequestID *uint   `gorm:"index" json:"payment_request_id"`
+	ReportAmount     *uint64 `json:"report_amount"` // toman
+	SystemAmount     *uint64 `json:"system_amount"` // toman
+	ReportStatus     string  `gorm:"type:varchar(64)" json:"report_status"`
+	Detail           string  `gorm:"type:text" json:"detail"`
+
+	CreatedAt time.Time `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+func (AtipayReconciliationEntry) TableName() string {
+	return "atipay_reconciliation_entries"
+}
+
+// AtipayReconciliationEntryFilter provides query criteria for reconciliation entries
+type AtipayReconciliationEntryFilter struct {
+	ID               *uint                       `json:"id,omitempty"`
+	ReportDate       *time.Time                  `json:"report_date,omitempty"`
+	Status           *AtipayReconciliationStatus `json:"status,omitempty"`
+	Discrepancies    *bool                       `json:"discrepancies,omitempty"` // every status but matched
+	PaymentRequestID *uint                       `json:"payment_request_id,omitempty"`
+	ReferenceNumber  *string                     `json:"reference_number,omitempty"`
+}
@@ -0,0 +1,50 @@
+package models
+
+import "time"
+
+// AudienceColorTransitionReason records which lifecycle rule moved a profile
+type AudienceColorTransitionReason string
+
+const (
+	// White profiles with repeated undelivered SMS and no delivery are demoted
+	AudienceColorReasonDeliveryFailures AudienceColorTransitionReason = "delivery_failures"
+	// White profiles whose number is blacklisted are demoted
+	AudienceColorReasonOptOut AudienceColorTransitionReason = "opt_out"
+	// Pink profiles that clicked campaign short links are promoted
+	AudienceColorReasonClickEngagement AudienceColorTransitionReason = "click_engagement"
+	// White profiles an SMS was rejected for with a permanent provider error
+	// are demoted
+	AudienceColorReasonPermanentError AudienceColorTransitionReason = "permanent_error"
+)
+
+// AudienceColorTransition is one automatic color change of an audience
+// profile. Evidence is the number of undelivered or rejected messages or
+// clicks the rule matched, and 0 for opt-outs.
+type AudienceColorTransition struct {
+	ID                int64                         `gorm:"primaryKey;autoIncrement;type:bigserial" json:"id"`
+	AudienceProfileID int64                         `gorm:"not null;index:idx_audience_color_transitions_profile" json:"audience_profile_id"`
+	FromColor         string                        `gorm:"size:20;not null" json:"from_color"`
+	ToColor           string                        `gorm:"size:20;not null" json:"to_color"`
+	Reason            AudienceColorTransitionReason `gorm:"size:32;not null" json:"reason"`
+	Evidence          int64                         `gorm:"not null;default:0" json:"evidence"`
+	CreatedAt         time.Time                     `gorm:"default:(CURRENT_TIMESTAMP AT TIME ZONE 'UTC');index:idx_audience_color_transitions_created_at" json:"created_at"`
+}
+
+func (AudienceColorTransition) TableName() string {
+	return "audience_color_transitions"
+}
+
+// AudienceColorTransitionFilter represents filter criteria for color transition queries
+type AudienceColorTransitionFilter struct {
+	ID                *int64
+	AudienceProfileID *int64
+	Reason            *AudienceColorTransitionReason
+	CreatedAfter      *time.Time
+}
+
+// AudienceColorCandidate is a profile a lifecycle rule matched, with the
+// number of messages or clicks it matched on
+type AudienceColorCandidate struct {
+	AudienceProfileID int64
+	Evidence          int64
+}
@@ -0,0 +1,71 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AudienceImportJobStatus represents the processing state of a bulk audience import
+type AudienceImportJobStatus string
+
+const (
+	AudienceImportJobStatusPending    AudienceImportJobStatus = "pending"
+	AudienceImportJobStatusProcessing AudienceImportJobStatus = "processing"
+	AudienceImportJobStatusCompleted  AudienceImportJobStatus = "completed"
+	AudienceImportJobStatusFailed     AudienceImportJobStatus = "failed"
+)
+
+// AudienceImportJob tracks an uploaded CSV of phone numbers and tags that is
+// imported into audience_profiles in the background.
+// Table: audience_import_jobs
+type AudienceImportJob struct {
+	ID       uint                    `gorm:"primaryKey" json:"id"`
+	UUID     uuid.UUID               `gorm:"type:uuid;not null;uniqueIndex" json:"uuid"`
+	AdminID  *uint                   `json:"admin_id,omitempty"`
+	FileName string                  `gorm:"size:255;not null" json:"file_name"`
+	FilePath string                  `gorm:"type:text;not null" json:"-"`
+	Status   AudienceImportJobStatus `gorm:"size:20;not null;default:'pending';index:idx_audience_import_jobs_status" json:"status"`
+
+	TotalRows     int64 `gorm:"not null;default:0" json:"total_rows"`
+	ProcessedRows int64 `gorm:"not null;default:0" json:"processed_rows"`
+	InsertedRows  int64 `gorm:"not null;default:0" json:"inserted_rows"`
+	ExistingRows  int64 `gorm:"not null;default:0" json:"existing_rows"`
+	DuplicateRows int64 `gorm:"not null;default:0" json:"duplicate_rows"`
+	FailedRows    int64 `gorm:"not null;default:0" json:"failed_rows"`
+
+	// RowErrors holds a capped list of AudienceImportRowError
+	RowErrors    json.RawMessage `gorm:"type:jsonb;not null;default:'[]'" json:"row_errors"`
+	ErrorMessage *string         `gorm:"type:text" json:"error_message,omitempty"`
+
+	StartedAt   *time.Time `json:"started_at,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	CreatedAt   time.Time  `gorm:"default:(CURRENT_TIMESTAMP AT TIME ZONE 'UTC');index:idx_audience_import_jobs_created_at" json:"created_at"`
+	UpdatedAt   time.Time  `gorm:"default:(CURRENT_TIMESTAMP AT TIME ZONE 'UTC')" json:"updated_at"`
+}
+
+func (AudienceImportJob) TableName() string {
+	return "audience_import_jobs"
+}
+
+// IsFinished reports whether the import reached a terminal status
+func (j *AudienceImportJob) IsFinished() bool {
+	return j.Status == AudienceImportJobStatusCompleted || j.Status == AudienceImportJobStatusFailed
+}
+
+// AudienceImportRowError describes why a CSV row was rejected. Row is the
+// 1-based data row number, not counting the header.
+type AudienceImportRowError struct {
+	Row         int64  `json:"row"`
+	PhoneNumber string `json:"phone_number,omitempty"`
+	Error       string `json:"error"`
+}
+
+// AudienceImportJobFilter represents filter criteria for audience import job queries
+type AudienceImportJobFilter struct {
+	ID      *uint
+	UUID    *uuid.UUID
+	AdminID *uint
+	Status  *AudienceImportJobStatus
+}
@@ -1,9 +1,11 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/lib/pq"
+	"gorm.io/gorm"
 )
 
 // AudienceProfile represents a profile of an audience used for campaign targeting
@@ -17,15 +19,25 @@ type AudienceProfile struct {
 	Tags            pq.Int32Array `gorm:"type:integer[];index:idx_audience_profiles_tag_gin,using:gin" json:"tags"`
 	Color           string        `gorm:"size:20;not null;index:idx_audience_profiles_color" json:"color"`
 	NormalizedScore *float64      `gorm:"column:normalized_score" json:"normalized_score,omitempty"`
+	// Attributes hold personalization values keyed by variable name, e.g. first_name
+	Attributes json.RawMessage `gorm:"type:jsonb;not null;default:'{}'" json:"attributes,omitempty"`
 
-	CreatedAt time.Time `gorm:"default:(CURRENT_TIMESTAMP AT TIME ZONE 'UTC');index:idx_audience_profiles_created_at" json:"created_at"`
-	UpdatedAt time.Time `gorm:"default:(CURRENT_TIMESTAMP AT TIME ZONE 'UTC')" json:"updated_at"`
+	CreatedAt time.Time      `gorm:"default:(CURRENT_TIMESTAMP AT TIME ZONE 'UTC');index:idx_audience_profiles_created_at" json:"created_at"`
+	UpdatedAt time.Time      `gorm:"default:(CURRENT_TIMESTAMP AT TIME ZONE 'UTC')" json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index:idx_audience_profiles_deleted_at" json:"deleted_at,omitempty"`
 }
 
 func (AudienceProfile) TableName() string {
 	return "audience_profiles"
 }
 
+// Audience colors. SMS campaigns send to white profiles first and fall back to
+// pink ones when the white pool is exhausted.
+const (
+	AudienceColorWhite = "white"
+	AudienceColorPink  = "pink"
+)
+
 // NormalizedScoreConstraint expresses a WHERE predicate on normalized_score.
 //
 // Exactly one of the following patterns should be set per query:
@@ -47,7 +59,10 @@ type AudienceProfileFilter struct {
 	PhoneNumber     *string
 	Tags            *pq.Int32Array
 	Color           *string
+	HasPhoneNumber  *bool
 	CreatedAfter    *time.Time
 	CreatedBefore   *time.Time
 	NormalizedScore *NormalizedScoreConstraint
+	// IncludeDeleted returns soft-deleted profiles too, for audits
+	IncludeDeleted bool
 }
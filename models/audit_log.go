@@ -36,6 +36,10 @@ const (
 	AuditActionLoginFailed            = "login_failed"
 	AuditActionLogout                 = "logout"
 	AuditActionPasswordChanged        = "password_changed"
+	AuditActionPasswordChangeFailed   = "password_change_failed"
+	AuditActionMobileChangeRequested  = "mobile_change_requested"
+	AuditActionMobileChanged          = "mobile_changed"
+	AuditActionMobileChangeFailed     = "mobile_change_failed"
 	AuditActionPasswordResetRequested = "password_reset_requested"
 	AuditActionPasswordResetCompleted = "password_reset_completed"
 	AuditActionPasswordResetFailed    = "password_reset_failed"
@@ -44,6 +48,17 @@ const (
 	AuditActionAccountDeactivated     = "account_deactivated"
 	AuditActionSessionCreated         = "session_created"
 	AuditActionSessionExpired         = "session_expired"
+	AuditActionSessionRevoked         = "session_revoked"
+	AuditActionSuspiciousLogin        = "suspicious_login_detected"
+	AuditActionLoginReportedNotMe     = "login_reported_not_me"
+	AuditActionOTPLoginRequested      = "otp_login_requested"
+	AuditActionOTPLoginSuccess        = "otp_login_success"
+	AuditActionOTPLoginFailed         = "otp_login_failed"
+	AuditActionDataExportRequested    = "data_export_requested"
+	AuditActionDataExportCompleted    = "data_export_completed"
+	AuditActionDeletionRequested      = "account_deletion_requested"
+	AuditActionDeletionCancelled      = "account_deletion_cancelled"
+	AuditActionAccountDeleted         = "account_deleted"
 	AuditActionOTPGenerated           = "otp_generated"
 	AuditActionOTPVerified            = "otp_verified"
 	AuditActionOTPVerificationFailed  = "otp_verification_failed"
@@ -61,38 +76,65 @@ const (
 	AuditActionCampaignRefundReconcileFailed = "campaign_refund_reconcile_failed"
 	AuditActionCampaignReportExported        = "campaign_report_exported"
 	AuditActionCampaignReportExportFailed    = "campaign_report_export_failed"
+	AuditActionCampaignTemplateCreated       = "campaign_template_created"
+	AuditActionCampaignTemplateDeleted       = "campaign_template_deleted"
+	AuditActionCampaignOccurrenceCreated     = "campaign_occurrence_created"
+	AuditActionCampaignOccurrenceFailed      = "campaign_occurrence_failed"
+	AuditActionCampaignRecurrenceEnded       = "campaign_recurrence_ended"
+	AuditActionCampaignPaused                = "campaign_paused"
+	AuditActionCampaignResumed               = "campaign_resumed"
+	AuditActionCampaignExecutionStopped      = "campaign_execution_stopped"
+	AuditActionCampaignResubmitted           = "campaign_resubmitted"
+	AuditActionCampaignReviewComment         = "campaign_review_comment"
 	AuditActionBundleCreated                 = "bundle_created"
 	AuditActionBundleCreationFailed          = "bundle_creation_failed"
 	AuditActionBundleUpdated                 = "bundle_updated"
 	AuditActionBundleUpdateFailed            = "bundle_update_failed"
 
+	// Line number reservation actions
+	AuditActionLineNumberReserved            = "line_number_reserved"
+	AuditActionLineNumberReservationFailed   = "line_number_reservation_failed"
+	AuditActionLineNumberReservationReleased = "line_number_reservation_released"
+
 	// Payment actions
 	AuditActionWalletChargeInitiated                   = "wallet_charge_initiated"
 	AuditActionWalletChargeCompleted                   = "wallet_charge_completed"
 	AuditActionWalletChargeFailed                      = "wallet_charge_failed"
 	AuditActionWalletCreated                           = "wallet_created"
+	AuditActionWalletTransferInitiated                 = "wallet_transfer_initiated"
+	AuditActionWalletTransferCompleted                 = "wallet_transfer_completed"
+	AuditActionWalletTransferFailed                    = "wallet_transfer_failed"
 	AuditActionPaymentCallbackProcessed                = "payment_callback_processed"
 	AuditActionPaymentCompleted                        = "payment_completed"
 	AuditActionPaymentFailed                           = "payment_failed"
 	AuditActionPaymentCancelled                        = "payment_cancelled"
 	AuditActionPaymentExpired                          = "payment_expired"
+	AuditActionAgencySettlementFallback                = "agency_settlement_fallback"
 	AuditActionTransactionHistoryRetrieved             = "transaction_history_retrieved"
 	AuditActionDepositReceiptSubmitted                 = "deposit_receipt_submitted"
 	AuditActionAdminDepositReceiptReviewed             = "admin_deposit_receipt_reviewed"
 	AuditActionInvoiceIssueRequested                   = "invoice_issue_requested"
 	AuditActionAdminPreviewWalletChargeImpactSucceeded = "admin_preview_wallet_charge_impact_succeeded"
 	AuditActionAdminPreviewWalletChargeImpactFailed    = "admin_preview_wallet_charge_impact_failed"
+	AuditActionSandboxWalletToppedUp                   = "sandbox_wallet_topped_up"
+	AuditActionSandboxPurged                           = "sandbox_purged"
 
 	// Agency discount actions
 	AuditActionCreateDiscountByAgencyFailed    = "create_discount_by_agency_failed"
 	AuditActionCreateDiscountByAgencyCompleted = "create_discount_by_agency_completed"
 
+	// Agency delegation actions
+	AuditActionAgencyDelegationGranted = "agency_delegation_granted"
+	AuditActionAgencyDelegationRevoked = "agency_delegation_revoked"
+	AuditActionAgencyDelegationDenied  = "agency_delegation_denied"
+
 	// Admin actions
 	AuditActionAdminListCustomers                    = "admin_list_customers"
 	AuditActionAdminViewCustomer                     = "admin_view_customer"
 	AuditActionAdminViewCustomerShares               = "admin_view_customer_shares"
 	AuditActionAdminViewCustomerDiscounts            = "admin_view_customer_discounts"
 	AuditActionAdminSetCustomerStatus                = "admin_set_customer_status"
+	AuditActionAdminForceLogoutCustomer              = "admin_force_logout_customer"
 	AuditActionAdminCreateShortLinks                 = "admin_create_short_links"
 	AuditActionAdminDownloadShortLinks               = "admin_download_short_links"
 	AuditActionAdminDownloadShortLinksWithClicks     = "admin_download_short_links_with_clicks"
@@ -100,6 +142,8 @@ const (
 	AuditActionAdminDownloadShortLinksByScenarioName = "admin_download_short_links_by_scenario_regex"
 	AuditActionAdminCampaignApproved                 = "admin_campaign_approved"
 	AuditActionAdminCampaignRejected                 = "admin_campaign_rejected"
+	AuditActionAdminCampaignChangesRequested         = "admin_campaign_changes_requested"
+	AuditActionAdminCampaignReviewComment            = "admin_campaign_review_comment"
 	AuditActionAdminCampaignCancelled                = "admin_campaign_cancelled"
 	AuditActionAdminCampaignRescheduled              = "admin_campaign_rescheduled"
 	AuditActionAdminCampaignList                     = "admin_campaign_list"
@@ -121,6 +165,62 @@ const (
 	AuditActionAdminDownloadDepositReceiptFile       = "admin_download_deposit_receipt_file"
 	AuditActionAdminUpdateDepositReceiptStatus       = "admin_update_deposit_receipt_status"
 	AuditActionAdminAttachInvoiceToTransaction       = "admin_attach_invoice_to_transaction"
+	AuditActionAdminSMSTariffCreate                  = "admin_sms_tariff_create"
+	AuditActionAdminSMSTariffList                    = "admin_sms_tariff_list"
+	AuditActionAdminSMSTariffUpdate                  = "admin_sms_tariff_update"
+	AuditActionAdminSMSTariffDelete                  = "admin_sms_tariff_delete"
+	AuditActionAdminCampaignTemplatePublish          = "admin_campaign_template_publish"
+	AuditActionAdminCampaignTemplateDelete           = "admin_campaign_template_delete"
+	AuditActionAdminAudienceImportCreate             = "admin_audience_import_create"
+	AuditActionAdminBlacklistUpload                  = "admin_blacklist_upload"
+	AuditActionAdminBlacklistDelete                  = "admin_blacklist_delete"
+	AuditActionAdminCustomerSendingQuotaUpdate       = "admin_customer_sending_quota_update"
+	AuditActionAdminLineNumberTierCreate             = "admin_line_number_tier_create"
+	AuditActionAdminLineNumberTierUpdate             = "admin_line_number_tier_update"
+	AuditActionAdminTOTPEnrolled                     = "admin_totp_enrolled"
+	AuditActionAdminTOTPFailed                       = "admin_totp_failed"
+	AuditActionAdminIPDenied                         = "admin_ip_denied"
+	AuditActionAdminImpersonateCustomer              = "admin_impersonate_customer"
+	AuditActionAdminAtipayReconciliationUpload       = "admin_atipay_reconciliation_upload"
+	AuditActionAdminWalletAdjustmentRequested        = "admin_wallet_adjustment_requested"
+	AuditActionAdminWalletAdjustmentApproved         = "admin_wallet_adjustment_approved"
+	AuditActionAdminWalletAdjustmentRejected         = "admin_wallet_adjustment_rejected"
+	AuditActionAdminCustomerCreditLimitUpdate        = "admin_customer_credit_limit_update"
+	AuditActionAdminPostpaidInvoicePaid              = "admin_postpaid_invoice_paid"
+	AuditActionAdminAgencyPayablePaid                = "admin_agency_payable_paid"
+	AuditActionAdminJobRequeued                      = "admin_job_requeued"
+	AuditActionAdminJobCancelled                     = "admin_job_cancelled"
+	AuditActionAdminCustomerSandboxUpdate            = "admin_customer_sandbox_update"
+	AuditActionAdminRecordDeleted                    = "admin_record_deleted"
+	AuditActionAdminRecordRestored                   = "admin_record_restored"
+	AuditActionAdminSearchCustomers                  = "admin_search_customers"
+	AuditActionAdminPushBroadcast                    = "admin_push_broadcast"
+	AuditActionAdminCustomerAgencyReassigned         = "admin_customer_agency_reassigned"
+	AuditActionAdminCryptoPaymentResynced            = "admin_crypto_payment_resynced"
+	AuditActionAdminCustomerSuspensionUpdate         = "admin_customer_suspension_update"
+	AuditActionAdminKYCReview                        = "admin_kyc_review"
+	AuditActionAdminProfileChangeReview              = "admin_profile_change_review"
+
+	// KYC actions
+	AuditActionKYCDocumentUpload = "kyc_document_upload"
+	AuditActionKYCSubmit         = "kyc_submit"
+
+	// Profile actions
+	AuditActionProfileChangeRequested = "profile_change_requested"
+	AuditActionShebaVerification      = "sheba_verification"
+
+	// Notification channel actions
+	AuditActionTelegramLinked   = "telegram_linked"
+	AuditActionTelegramUnlinked = "telegram_unlinked"
+
+	// Configuration actions
+	AuditActionConfigReloaded = "config_reloaded"
+
+	// Payment reconciliation actions
+	AuditActionAtipayReconciliationPulled = "atipay_reconciliation_pulled"
+
+	// Postpaid billing actions
+	AuditActionPostpaidInvoiceIssued = "postpaid_invoice_issued"
 )
 
 // AuditLogFilter represents filter criteria for audit log queries
@@ -142,10 +242,16 @@ func (a *AuditLog) IsFailed() bool {
 var SecurityActions = map[string]bool{
 	AuditActionLoginSuccess:          true,
 	AuditActionLoginFailed:           true,
+	AuditActionOTPLoginSuccess:       true,
+	AuditActionOTPLoginFailed:        true,
 	AuditActionPasswordChanged:       true,
+	AuditActionPasswordChangeFailed:  true,
+	AuditActionMobileChanged:         true,
+	AuditActionMobileChangeFailed:    true,
 	AuditActionAccountActivated:      true,
 	AuditActionAccountDeactivated:    true,
 	AuditActionOTPVerificationFailed: true,
+	AuditActionConfigReloaded:        true,
 }
 
 func (a *AuditLog) IsSecurityEvent() bool {
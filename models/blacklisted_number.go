@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// BlacklistSource records where a blacklisted number came from
+type BlacklistSource string
+
+const (
+	BlacklistSourceRegulator BlacklistSource = "regulator"
+	BlacklistSourceComplaint BlacklistSource = "complaint"
+	BlacklistSourceAdmin     BlacklistSource = "admin"
+)
+
+// Valid reports whether the source is one of the known sources
+func (s BlacklistSource) Valid() bool {
+	switch s {
+	case BlacklistSourceRegulator, BlacklistSourceComplaint, BlacklistSourceAdmin:
+		return true
+	}
+	return false
+}
+
+// BlacklistedNumber is a recipient that must never be messaged by any campaign.
+// PhoneNumber is stored in canonical E.164 form, like audience profiles.
+type BlacklistedNumber struct {
+	ID          uint            `gorm:"primaryKey" json:"id"`
+	PhoneNumber string          `gorm:"type:varchar(20);not null;uniqueIndex" json:"phone_number"`
+	Source      BlacklistSource `gorm:"type:varchar(20);not null;index:idx_blacklisted_numbers_source" json:"source"`
+	Reason      *string         `gorm:"type:text" json:"reason,omitempty"`
+	AdminID     *uint           `json:"admin_id,omitempty"`
+	CreatedAt   time.Time       `gorm:"default:(CURRENT_TIMESTAMP AT TIME ZONE 'UTC');index:idx_blacklisted_numbers_created_at" json:"created_at"`
+}
+
+func (BlacklistedNumber) TableName() string {
+	return "blacklisted_numbers"
+}
+
+// BlacklistedNumberFilter represents filter criteria for blacklisted number queries
+type BlacklistedNumberFilter struct {
+	ID          *uint
+	PhoneNumber *string
+	Source      *BlacklistSource
+}
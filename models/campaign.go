@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/amirphl/Yamata-no-Orochi/listquery"
+	"github.com/amirphl/Yamata-no-Orochi/recurrence"
 	"github.com/amirphl/Yamata-no-Orochi/utils"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -25,8 +27,10 @@ const (
 	CampaignStatusInitiated          CampaignStatus = "initiated"
 	CampaignStatusInProgress         CampaignStatus = "in-progress"
 	CampaignStatusWaitingForApproval CampaignStatus = "waiting-for-approval"
+	CampaignStatusChangesRequested   CampaignStatus = "changes-requested"
 	CampaignStatusApproved           CampaignStatus = "approved"
 	CampaignStatusRunning            CampaignStatus = "running"
+	CampaignStatusPaused             CampaignStatus = "paused"
 	CampaignStatusExecuted           CampaignStatus = "executed"
 	CampaignStatusExpired            CampaignStatus = "expired"
 	CampaignStatusRejected           CampaignStatus = "rejected"
@@ -52,10 +56,11 @@ func (s CampaignStatus) String() string {
 func (s CampaignStatus) Valid() bool {
 	switch s {
 	case CampaignStatusInitiated, CampaignStatusInProgress,
-		CampaignStatusWaitingForApproval, CampaignStatusApproved,
-		CampaignStatusRejected, CampaignStatusCancelled,
+		CampaignStatusWaitingForApproval, CampaignStatusChangesRequested,
+		CampaignStatusApproved, CampaignStatusRejected, CampaignStatusCancelled,
 		CampaignStatusCancelledByAdmin,
-		CampaignStatusRunning, CampaignStatusExecuted, CampaignStatusExpired:
+		CampaignStatusRunning, CampaignStatusPaused,
+		CampaignStatusExecuted, CampaignStatusExpired:
 		return true
 	default:
 		return false
@@ -161,6 +166,51 @@ type CampaignSpec struct {
 
 	// Budget
 	Budget *uint64 `json:"budget,omitempty"`
+
+	// Recurrence repeats the campaign after its first run; nil means one-off
+	Recurrence *CampaignRecurrence `json:"recurrence,omitempty"`
+
+	// Variants A/B test the content of an SMS campaign; Content mirrors the
+	// first variant so pricing and previews keep working.
+	Variants []CampaignContentVariant `json:"variants,omitempty"`
+}
+
+// CampaignContentVariant is one content alternative of an A/B tested campaign.
+// Weight is the percentage of recipients that receive it.
+type CampaignContentVariant struct {
+	Label   string `json:"label"`
+	Content string `json:"content"`
+	Weight  uint   `json:"weight"`
+}
+
+// CampaignRecurrence is the recurrence rule of a recurring campaign. The
+// campaign itself is the first occurrence; later occurrences are materialized
+// as child campaigns by the scheduler.
+type CampaignRecurrence struct {
+	Frequency string `json:"frequency"`
+	Interval  uint   `json:"interval,omitempty"`
+	// Weekdays are 0 (Sunday) to 6 (Saturday)
+	Weekdays []int   `json:"weekdays,omitempty"`
+	Cron     *string `json:"cron,omitempty"`
+	// Until and MaxOccurrences end the series; MaxOccurrences counts the
+	// parent campaign as the first occurrence.
+	Until          *time.Time `json:"until,omitempty"`
+	MaxOccurrences *uint      `json:"max_occurrences,omitempty"`
+}
+
+// Rule converts the stored recurrence into an expandable rule
+func (r CampaignRecurrence) Rule() recurrence.Rule {
+	rule := recurrence.Rule{
+		Frequency: r.Frequency,
+		Interval:  r.Interval,
+	}
+	for _, wd := range r.Weekdays {
+		rule.Weekdays = append(rule.Weekdays, time.Weekday(wd))
+	}
+	if r.Cron != nil {
+		rule.Cron = *r.Cron
+	}
+	return rule
 }
 
 // Value implements the driver.Valuer interface for CampaignSpec
@@ -194,6 +244,7 @@ type Campaign struct {
 	UUID       uuid.UUID       `gorm:"type:uuid;not null;uniqueIndex:uk_campaigns_uuid;index:idx_campaigns_uuid" json:"uuid"`
 	CustomerID uint            `gorm:"not null;index:idx_campaigns_customer_id" json:"customer_id"`
 	Hidden     bool            `gorm:"not null;default:false" json:"hidden"`
+	IsSandbox  bool            `gorm:"not null;default:false" json:"is_sandbox"` // created by a sandbox customer; never sent for real
 	Status     CampaignStatus  `gorm:"type:sms_campaign_status;not null;default:'initiated';index:idx_campaigns_status" json:"status"`
 	CreatedAt  time.Time       `gorm:"default:(CURRENT_TIMESTAMP AT TIME ZONE 'UTC');index:idx_campaigns_created_at" json:"created_at"`
 	UpdatedAt  *time.Time      `gorm:"index:idx_campaigns_updated_at" json:"updated_at,omitempty"`
@@ -207,6 +258,22 @@ type Campaign struct {
 	BundleID *uint         `gorm:"index:idx_campaigns_bundle_id" json:"bundle_id,omitempty"`
 	Phase    CampaignPhase `gorm:"type:campaign_phase;not null;default:'execution'" json:"phase"`
 
+	// Recurring series: children point at the parent and carry their
+	// occurrence number (the parent is occurrence 1). NextOccurrenceAt is set
+	// on the parent while the series is active.
+	ParentCampaignID *uint      `gorm:"index:idx_campaigns_parent_campaign_id" json:"parent_campaign_id,omitempty"`
+	OccurrenceNumber *uint      `json:"occurrence_number,omitempty"`
+	NextOccurrenceAt *time.Time `gorm:"index:idx_campaigns_next_occurrence_at" json:"next_occurrence_at,omitempty"`
+
+	// Set when a running or paused campaign is cancelled before all batches
+	// were sent; SentBeforeStop is the number of recipients already handed
+	// to the provider.
+	ExecutionStoppedAt *time.Time `json:"execution_stopped_at,omitempty"`
+	SentBeforeStop     *uint64    `gorm:"type:bigint" json:"sent_before_stop,omitempty"`
+
+	// Set when an admin deleted the campaign; see IsDeletable
+	DeletedAt gorm.DeletedAt `gorm:"index:idx_campaigns_deleted_at" json:"deleted_at,omitempty"`
+
 	// Relations
 	Customer *Customer `gorm:"foreignKey:CustomerID;references:ID" json:"customer,omitempty"`
 	Bundle   *Bundle   `gorm:"foreignKey:BundleID;references:ID" json:"bundle,omitempty"`
@@ -241,12 +308,26 @@ func (c *Campaign) BeforeUpdate(tx *gorm.DB) error {
 // IsEditable checks if the campaign can be edited
 func (c *Campaign) IsEditable() bool {
 	return c.Status == CampaignStatusInitiated ||
-		c.Status == CampaignStatusInProgress
+		c.Status == CampaignStatusInProgress ||
+		c.Status == CampaignStatusChangesRequested
 }
 
-// IsDeletable checks if the campaign can be deleted
+// IsDeletable checks if the campaign can be soft-deleted: drafts and
+// finished campaigns, which hold no reserved budget and have nothing left
+// for the schedulers to do
 func (c *Campaign) IsDeletable() bool {
-	return false
+	switch c.Status {
+	case CampaignStatusInitiated,
+		CampaignStatusInProgress,
+		CampaignStatusExecuted,
+		CampaignStatusExpired,
+		CampaignStatusRejected,
+		CampaignStatusCancelled,
+		CampaignStatusCancelledByAdmin:
+		return true
+	default:
+		return false
+	}
 }
 
 // CanTransitionTo checks if the campaign can transition to the given status
@@ -262,7 +343,20 @@ func (c *Campaign) CanTransitionTo(newStatus CampaignStatus) bool {
 	case CampaignStatusWaitingForApproval:
 		return newStatus == CampaignStatusApproved ||
 			newStatus == CampaignStatusRejected ||
+			newStatus == CampaignStatusChangesRequested ||
 			newStatus == CampaignStatusCancelled
+	case CampaignStatusChangesRequested:
+		return newStatus == CampaignStatusWaitingForApproval ||
+			newStatus == CampaignStatusCancelled
+	case CampaignStatusRunning:
+		return newStatus == CampaignStatusPaused ||
+			newStatus == CampaignStatusExecuted ||
+			newStatus == CampaignStatusCancelled ||
+			newStatus == CampaignStatusCancelledByAdmin
+	case CampaignStatusPaused:
+		return newStatus == CampaignStatusRunning ||
+			newStatus == CampaignStatusCancelled ||
+			newStatus == CampaignStatusCancelledByAdmin
 	default:
 		return false
 	}
@@ -295,6 +389,35 @@ type CampaignFilter struct {
 	MaxBudget          *uint64         `json:"max_budget,omitempty"`
 	BundleID           *uint           `json:"bundle_id,omitempty"`
 	Phase              *CampaignPhase  `json:"phase,omitempty"`
+	ParentCampaignID   *uint           `json:"parent_campaign_id,omitempty"`
+	// IncludeDeleted returns soft-deleted campaigns too, for audits
+	IncludeDeleted bool `json:"include_deleted,omitempty"`
+	// List is an admin filter and sort over CampaignListFields
+	List *listquery.Query `json:"-"`
+}
+
+// CampaignListFields are the fields admins can filter and sort campaigns by
+var CampaignListFields = listquery.Fields{
+	"id":          {Column: "campaigns.id", Kind: listquery.KindInt},
+	"customer_id": {Column: "campaigns.customer_id", Kind: listquery.KindInt},
+	"bundle_id":   {Column: "campaigns.bundle_id", Kind: listquery.KindInt, Nullable: true},
+	"status": {Column: "campaigns.status", Values: []string{
+		string(CampaignStatusInitiated), string(CampaignStatusInProgress), string(CampaignStatusWaitingForApproval),
+		string(CampaignStatusChangesRequested), string(CampaignStatusApproved), string(CampaignStatusRunning),
+		string(CampaignStatusPaused), string(CampaignStatusExecuted), string(CampaignStatusExpired),
+		string(CampaignStatusRejected), string(CampaignStatusCancelled), string(CampaignStatusCancelledByAdmin),
+	}},
+	"phase":        {Column: "campaigns.phase", Values: []string{string(CampaignPhaseTest), string(CampaignPhaseExecution)}},
+	"title":        {Column: "campaigns.spec->>'title'"},
+	"platform":     {Column: "campaigns.spec->>'platform'"},
+	"level1":       {Column: "campaigns.spec->>'level1'"},
+	"line_number":  {Column: "campaigns.spec->>'line_number'"},
+	"budget":       {Column: "CAST(campaigns.spec->>'budget' AS BIGINT)", Kind: listquery.KindInt, Nullable: true},
+	"num_audience": {Column: "campaigns.num_audience", Kind: listquery.KindInt, Nullable: true},
+	"hidden":       {Column: "campaigns.hidden", Kind: listquery.KindBool},
+	"is_sandbox":   {Column: "campaigns.is_sandbox", Kind: listquery.KindBool},
+	"created_at":   {Column: "campaigns.created_at", Kind: listquery.KindTime},
+	"updated_at":   {Column: "campaigns.updated_at", Kind: listquery.KindTime, Nullable: true},
 }
 
 // GetStatusDisplayName returns a human-readable status name
@@ -306,6 +429,8 @@ func (c *Campaign) GetStatusDisplayName() string {
 		return "In Progress"
 	case CampaignStatusWaitingForApproval:
 		return "Waiting for Approval"
+	case CampaignStatusChangesRequested:
+		return "Changes Requested"
 	case CampaignStatusApproved:
 		return "Approved"
 	case CampaignStatusExpired:
@@ -318,6 +443,8 @@ func (c *Campaign) GetStatusDisplayName() string {
 		return "Cancelled by Admin"
 	case CampaignStatusRunning:
 		return "Running"
+	case CampaignStatusPaused:
+		return "Paused"
 	case CampaignStatusExecuted:
 		return "Executed"
 	default:
@@ -334,6 +461,8 @@ func (c *Campaign) GetStatusColor() string {
 		return "#007bff" // blue
 	case CampaignStatusWaitingForApproval:
 		return "#ffc107" // yellow
+	case CampaignStatusChangesRequested:
+		return "#e83e8c" // pink
 	case CampaignStatusApproved:
 		return "#28a745" // green
 	case CampaignStatusExpired:
@@ -344,6 +473,8 @@ func (c *Campaign) GetStatusColor() string {
 		return "#6c757d" // gray
 	case CampaignStatusRunning:
 		return "#17a2b8" // cyan
+	case CampaignStatusPaused:
+		return "#ffc107" // yellow
 	case CampaignStatusExecuted:
 		return "#343a40" // dark gray
 	default:
@@ -0,0 +1,48 @@
+package models
+
+import "time"
+
+// CampaignReviewAuthorType tells whether an admin or the campaign owner wrote a review entry
+type CampaignReviewAuthorType string
+
+const (
+	CampaignReviewAuthorAdmin    CampaignReviewAuthorType = "admin"
+	CampaignReviewAuthorCustomer CampaignReviewAuthorType = "customer"
+)
+
+// CampaignReviewAction is the kind of a review entry
+type CampaignReviewAction string
+
+const (
+	CampaignReviewActionComment          CampaignReviewAction = "comment"
+	CampaignReviewActionChangesRequested CampaignReviewAction = "changes_requested"
+	CampaignReviewActionResubmitted      CampaignReviewAction = "resubmitted"
+	CampaignReviewActionApproved         CampaignReviewAction = "approved"
+	CampaignReviewActionRejected         CampaignReviewAction = "rejected"
+)
+
+// CampaignReview is an entry in the review history of a campaign: an admin
+// decision, a revision request, a customer resubmission or a plain comment.
+// Entries are append-only and ordered by ID.
+type CampaignReview struct {
+	ID         uint                     `gorm:"primaryKey" json:"id"`
+	CampaignID uint                     `gorm:"not null;index:idx_campaign_reviews_campaign_id" json:"campaign_id"`
+	AuthorType CampaignReviewAuthorType `gorm:"type:varchar(20);not null" json:"author_type"`
+	AdminID    *uint                    `json:"admin_id,omitempty"`
+	CustomerID *uint                    `json:"customer_id,omitempty"`
+	Action     CampaignReviewAction     `gorm:"type:varchar(30);not null" json:"action"`
+	Comment    *string                  `gorm:"type:text" json:"comment,omitempty"`
+	CreatedAt  time.Time                `gorm:"default:(CURRENT_TIMESTAMP AT TIME ZONE 'UTC')" json:"created_at"`
+}
+
+func (CampaignReview) TableName() string {
+	return "campaign_reviews"
+}
+
+// CampaignReviewFilter represents filter criteria for campaign review queries
+type CampaignReviewFilter struct {
+	ID         *uint
+	CampaignID *uint
+	AuthorType *CampaignReviewAuthorType
+	Action     *CampaignReviewAction
+}
@@ -6,15 +6,31 @@ import (
 	"github.com/lib/pq"
 )
 
+// Campaign status job states. Only pending jobs are picked up; the others
+// are terminal.
+const (
+	CampaignStatusJobPending   = "pending"
+	CampaignStatusJobSucceeded = "succeeded"
+	CampaignStatusJobFailed    = "failed"
+	// CampaignStatusJobSkipped marks a later check of a batch whose messages
+	// all reached a final delivery status, so polling again would change nothing
+	CampaignStatusJobSkipped = "skipped"
+)
+
 // CampaignStatusJob represents a scheduled job to fetch delivery status across platforms.
+// A failed attempt increments RetryCount and pushes NextRunAt back; the job
+// fails once RetryCount reaches MaxAttempts.
 type CampaignStatusJob struct {
 	ID                  uint           `gorm:"primaryKey" json:"id"`
 	CorrelationID       string         `gorm:"size:64;index:idx_campaign_status_jobs_corr_id;not null" json:"correlation_id"`
 	ProcessedCampaignID uint           `gorm:"index:idx_campaign_status_jobs_processed_campaign_id;not null" json:"processed_campaign_id"`
-	Platform            string         `gorm:"size:20;index:idx_campaign_status_jobs_platform_scheduled_retry,priority:1;not null" json:"platform"`
+	Platform            string         `gorm:"size:20;index:idx_campaign_status_jobs_platform_next_run,priority:1;not null" json:"platform"`
 	TrackingIDs         pq.StringArray `gorm:"type:text[];not null" json:"tracking_ids"`
-	RetryCount          int            `gorm:"index:idx_campaign_status_jobs_platform_scheduled_retry,priority:3;not null;default:0" json:"retry_count"`
-	ScheduledAt         time.Time      `gorm:"index:idx_campaign_status_jobs_scheduled_retry;index:idx_campaign_status_jobs_platform_scheduled_retry,priority:2;not null" json:"scheduled_at"`
+	Status              string         `gorm:"size:20;not null;default:pending" json:"status"`
+	RetryCount          int            `gorm:"not null;default:0" json:"retry_count"`
+	MaxAttempts         int            `gorm:"not null;default:3" json:"max_attempts"`
+	ScheduledAt         time.Time      `gorm:"index:idx_campaign_status_jobs_scheduled_retry;not null" json:"scheduled_at"`
+	NextRunAt           time.Time      `gorm:"index:idx_campaign_status_jobs_platform_next_run,priority:2;not null" json:"next_run_at"`
 	ExecutedAt          *time.Time     `json:"executed_at,omitempty"`
 	Error               *string        `gorm:"type:text" json:"error,omitempty"`
 	RawProviderResponse *string        `gorm:"type:text" json:"raw_provider_response,omitempty"`
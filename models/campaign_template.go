@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CampaignTemplate stores reusable message content and targeting. Templates
+// with a nil CustomerID are global templates published by admins and are
+// visible to every customer.
+// Table: campaign_templates
+type CampaignTemplate struct {
+	ID         uint         `gorm:"primaryKey" json:"id"`
+	UUID       uuid.UUID    `gorm:"type:uuid;not null;uniqueIndex:uk_campaign_templates_uuid" json:"uuid"`
+	CustomerID *uint        `gorm:"index:idx_campaign_templates_customer_id" json:"customer_id,omitempty"`
+	Name       string       `gorm:"size:255;not null" json:"name"`
+	Spec       CampaignSpec `gorm:"type:jsonb;not null" json:"spec"`
+
+	// Set for global templates; records which admin published it
+	PublishedByAdminID *uint `json:"published_by_admin_id,omitempty"`
+
+	CreatedAt time.Time `gorm:"default:(CURRENT_TIMESTAMP AT TIME ZONE 'UTC');index:idx_campaign_templates_created_at" json:"created_at"`
+	UpdatedAt time.Time `gorm:"default:(CURRENT_TIMESTAMP AT TIME ZONE 'UTC')" json:"updated_at"`
+
+	Customer *Customer `gorm:"foreignKey:CustomerID;references:ID" json:"customer,omitempty"`
+}
+
+func (CampaignTemplate) TableName() string {
+	return "campaign_templates"
+}
+
+// IsGlobal reports whether the template was published by an admin for all customers
+func (t *CampaignTemplate) IsGlobal() bool {
+	return t.CustomerID == nil
+}
+
+// CampaignTemplateFilter represents filter criteria for campaign template queries
+type CampaignTemplateFilter struct {
+	ID         *uint
+	UUID       *uuid.UUID
+	CustomerID *uint
+	Global     *bool
+	Name       *string
+}
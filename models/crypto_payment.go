@@ -12,7 +12,8 @@ import (
 type CryptoPlatform string
 
 const (
-	CryptoPlatformOxapay CryptoPlatform = "oxapay"
+	CryptoPlatformOxapay      CryptoPlatform = "oxapay"
+	CryptoPlatformNowPayments CryptoPlatform = "nowpayments"
 )
 
 // CryptoCurrency represents supported crypto assets for deposit
@@ -61,6 +62,7 @@ type CryptoPaymentRequest struct {
 	ExpectedCoinAmount string         `gorm:"type:numeric(38,18);not null" json:"expected_coin_amount"`
 	ExchangeRate       string         `gorm:"type:numeric(38,18);not null" json:"exchange_rate"` // coin per Toman or vice versa
 	RateSource         string         `gorm:"type:varchar(128)" json:"rate_source"`
+	RateFetchedAt      *time.Time     `json:"rate_fetched_at"`
 
 	// Deposit details provisioned by provider or generated by us
 	DepositAddress string `gorm:"type:varchar(255);index" json:"deposit_address"`
@@ -193,3 +195,29 @@ type CryptoDepositFilter struct {
 	CreatedBefore          *time.Time      `json:"created_before,omitempty"`
 	ConfirmedOnly          *bool           `json:"confirmed_only,omitempty"`
 }
+
+// CryptoWebhookEvent is a provider callback that was applied. A callback
+// delivered again is recognised by its event key and not applied twice.
+type CryptoWebhookEvent struct {
+	ID       uint           `gorm:"primaryKey;autoIncrement" json:"id"`
+	Platform CryptoPlatform `gorm:"type:varchar(64);not null;uniqueIndex:uk_crypto_webhook_events_platform_event_key" json:"platform"`
+	// EventKey is the SHA-256 of the provider request ID, tx hashes and status
+	EventKey  string     `gorm:"type:char(64);not null;uniqueIndex:uk_crypto_webhook_events_platform_event_key" json:"event_key"`
+	TrackID   string     `gorm:"type:varchar(255);not null;index" json:"track_id"`
+	TxHash    string     `gorm:"type:text;not null;default:''" json:"tx_hash"` // comma separated
+	Status    string     `gorm:"type:varchar(32);not null" json:"status"`
+	EventAt   *time.Time `json:"event_at"`
+	CreatedAt time.Time  `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+func (CryptoWebhookEvent) TableName() string {
+	return "crypto_webhook_events"
+}
+
+// CryptoWebhookEventFilter provides query criteria for webhook events
+type CryptoWebhookEventFilter struct {
+	ID       *uint           `json:"id,omitempty"`
+	Platform *CryptoPlatform `json:"platform,omitempty"`
+	EventKey *string         `json:"event_key,omitempty"`
+	TrackID  *string         `json:"track_id,omitempty"`
+}
@@ -4,6 +4,7 @@ package models
 import (
 	"time"
 
+	"github.com/amirphl/Yamata-no-Orochi/listquery"
 	"github.com/google/uuid"
 )
 
@@ -42,6 +43,52 @@ type Customer struct {
 	IsMobileVerified *bool `gorm:"default:false" json:"is_mobile_verified"`
 	IsActive         *bool `gorm:"default:true;index:idx_customers_is_active" json:"is_active"`
 
+	// PasswordResetRequired blocks login until the password is reset, after
+	// the customer reported a login as not theirs
+	PasswordResetRequired *bool `gorm:"default:false" json:"password_reset_required"`
+
+	// PreferredLocale is the language SMS, emails and pages are sent to the
+	// customer in ("en" or "fa"); nil uses the deployment default
+	PreferredLocale *string `gorm:"size:8" json:"preferred_locale,omitempty"`
+
+	// IsSandbox marks a test account: its campaigns run against mock
+	// providers and its wallet only ever holds test money
+	IsSandbox *bool `gorm:"default:false" json:"is_sandbox"`
+
+	// SuspensionLevel restricts what the customer may do while they can still
+	// log in and view their data; nil when the customer is not suspended
+	SuspensionLevel *CustomerSuspensionLevel `gorm:"size:20;index:idx_customers_suspension_level" json:"suspension_level,omitempty"`
+	// SuspensionReason is the reason code shown to the customer
+	SuspensionReason *CustomerSuspensionReason `gorm:"size:40" json:"suspension_reason,omitempty"`
+	// SuspensionNote is the admin's internal note; never shown to the customer
+	SuspensionNote *string    `gorm:"size:500" json:"-"`
+	SuspendedAt    *time.Time `json:"suspended_at,omitempty"`
+
+	// KYCStatus is the company's document verification state; unverified
+	// companies are held to the unverified sending quota cap
+	KYCStatus          KYCStatus  `gorm:"size:20;not null;default:not_submitted;index:idx_customers_kyc_status" json:"kyc_status"`
+	KYCSubmittedAt     *time.Time `json:"kyc_submitted_at,omitempty"`
+	KYCReviewedAt      *time.Time `json:"kyc_reviewed_at,omitempty"`
+	KYCReviewerID      *uint      `json:"-"`
+	KYCRejectionReason *string    `gorm:"size:500" json:"kyc_rejection_reason,omitempty"`
+
+	// ShebaVerification is the bank inquiry result of ShebaVerifiedNumber;
+	// read it through ShebaStatus, which ignores it once the number changed
+	ShebaVerification   *ShebaVerificationStatus `gorm:"size:20" json:"-"`
+	ShebaVerifiedNumber *string                  `gorm:"size:26" json:"-"`
+	// ShebaOwnerName is the account owner the bank reported
+	ShebaOwnerName  *string    `gorm:"size:255" json:"-"`
+	ShebaVerifiedAt *time.Time `json:"sheba_verified_at,omitempty"`
+
+	// TelegramChatID is the Telegram chat campaign and payment notices are
+	// sent to instead of SMS; it is set once the customer confirmed the link
+	TelegramChatID   *int64     `gorm:"index:idx_customers_telegram_chat_id" json:"-"`
+	TelegramLinkedAt *time.Time `json:"telegram_linked_at,omitempty"`
+
+	// DeletedAt is set when the customer deleted their account. The row is
+	// kept for financial records but its personal data is anonymized.
+	DeletedAt *time.Time `gorm:"index:idx_customers_deleted_at" json:"deleted_at,omitempty"`
+
 	// Timestamps
 	CreatedAt        time.Time  `gorm:"default:(CURRENT_TIMESTAMP AT TIME ZONE 'UTC');index:idx_customers_created_at" json:"created_at"`
 	UpdatedAt        time.Time  `gorm:"default:(CURRENT_TIMESTAMP AT TIME ZONE 'UTC')" json:"updated_at"`
@@ -75,10 +122,33 @@ type CustomerFilter struct {
 	IsEmailVerified      *bool
 	IsMobileVerified     *bool
 	IsActive             *bool
+	KYCStatus            *KYCStatus
 	CreatedAfter         *time.Time
 	CreatedBefore        *time.Time
 	LastLoginAfter       *time.Time
 	LastLoginBefore      *time.Time
+	// List is an admin filter and sort over CustomerListFields
+	List *listquery.Query
+}
+
+// CustomerListFields are the fields admins can filter and sort customers by
+var CustomerListFields = listquery.Fields{
+	"id":                 {Column: "customers.id", Kind: listquery.KindInt},
+	"email":              {Column: "customers.email"},
+	"mobile":             {Column: "customers.representative_mobile"},
+	"first_name":         {Column: "customers.representative_first_name"},
+	"last_name":          {Column: "customers.representative_last_name"},
+	"company_name":       {Column: "customers.company_name", Nullable: true},
+	"national_id":        {Column: "customers.national_id", Nullable: true},
+	"account_type_id":    {Column: "customers.account_type_id", Kind: listquery.KindInt},
+	"referrer_agency_id": {Column: "customers.referrer_agency_id", Kind: listquery.KindInt, Nullable: true},
+	"is_active":          {Column: "customers.is_active", Kind: listquery.KindBool},
+	"is_sandbox":         {Column: "customers.is_sandbox", Kind: listquery.KindBool},
+	"is_email_verified":  {Column: "customers.is_email_verified", Kind: listquery.KindBool},
+	"is_mobile_verified": {Column: "customers.is_mobile_verified", Kind: listquery.KindBool},
+	"kyc_status":         {Column: "customers.kyc_status"},
+	"created_at":         {Column: "customers.created_at", Kind: listquery.KindTime},
+	"last_login_at":      {Column: "customers.last_login_at", Kind: listquery.KindTime, Nullable: true},
 }
 
 func (c *Customer) IsIndividual() bool {
@@ -93,6 +163,39 @@ func (c *Customer) IsAgency() bool {
 	return c.AccountType.TypeName == AccountTypeMarketingAgency
 }
 
+// InSandbox reports whether the customer is a sandbox account
+func (c *Customer) InSandbox() bool {
+	return c.IsSandbox != nil && *c.IsSandbox
+}
+
+// Suspension returns the customer's suspension level and reason; the level is
+// empty when the customer is not suspended
+func (c *Customer) Suspension() (CustomerSuspensionLevel, CustomerSuspensionReason) {
+	if c.SuspensionLevel == nil {
+		return "", ""
+	}
+	var reason CustomerSuspensionReason
+	if c.SuspensionReason != nil {
+		reason = *c.SuspensionReason
+	}
+	return *c.SuspensionLevel, reason
+}
+
 func (c *Customer) RequiresCompanyFields() bool {
 	return c.IsCompany() || c.IsAgency()
 }
+
+// KYC returns the customer's verification state; rows loaded without the
+// column count as not submitted
+func (c *Customer) KYC() KYCStatus {
+	if c.KYCStatus == "" {
+		return KYCStatusNotSubmitted
+	}
+	return c.KYCStatus
+}
+
+// IsUnverifiedCompany reports whether the customer is a company or agency
+// whose documents are not verified yet; only company accounts go through KYC
+func (c *Customer) IsUnverifiedCompany() bool {
+	return c.RequiresCompanyFields() && c.KYC() != KYCStatusVerified
+}
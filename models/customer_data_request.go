@@ -0,0 +1,84 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CustomerDataRequestType is what a customer asked to be done with their data
+type CustomerDataRequestType string
+
+const (
+	CustomerDataRequestTypeExport   CustomerDataRequestType = "export"
+	CustomerDataRequestTypeDeletion CustomerDataRequestType = "deletion"
+)
+
+// CustomerDataRequestStatus represents the processing state of a data request
+type CustomerDataRequestStatus string
+
+const (
+	// Exports wait in pending until the scheduler picks them up; deletions wait
+	// in scheduled until their grace period ends
+	CustomerDataRequestStatusPending    CustomerDataRequestStatus = "pending"
+	CustomerDataRequestStatusScheduled  CustomerDataRequestStatus = "scheduled"
+	CustomerDataRequestStatusProcessing CustomerDataRequestStatus = "processing"
+	CustomerDataRequestStatusCompleted  CustomerDataRequestStatus = "completed"
+	CustomerDataRequestStatusFailed     CustomerDataRequestStatus = "failed"
+	CustomerDataRequestStatusCancelled  CustomerDataRequestStatus = "cancelled"
+)
+
+// CustomerDataExportFormat is the file format of the records in an export bundle
+type CustomerDataExportFormat string
+
+const (
+	CustomerDataExportFormatJSON CustomerDataExportFormat = "json"
+	CustomerDataExportFormatCSV  CustomerDataExportFormat = "csv"
+)
+
+// CustomerDataRequest tracks a customer's request to export their data or to
+// delete their account; both are carried out in the background.
+// Table: customer_data_requests
+type CustomerDataRequest struct {
+	ID         uint                      `gorm:"primaryKey" json:"id"`
+	UUID       uuid.UUID                 `gorm:"type:uuid;not null;uniqueIndex" json:"uuid"`
+	CustomerID uint                      `gorm:"not null;index:idx_customer_data_requests_customer_id" json:"customer_id"`
+	Type       CustomerDataRequestType   `gorm:"size:20;not null" json:"type"`
+	Status     CustomerDataRequestStatus `gorm:"size:20;not null;default:'pending';index:idx_customer_data_requests_status" json:"status"`
+
+	// Format and FilePath are only set for exports; the file is removed once
+	// ExpiresAt passes or the account is deleted
+	Format   *CustomerDataExportFormat `gorm:"size:10" json:"format,omitempty"`
+	FilePath *string                   `gorm:"type:text" json:"-"`
+
+	// ScheduledFor is when a deletion is carried out unless cancelled before
+	ScheduledFor *time.Time `json:"scheduled_for,omitempty"`
+	ErrorMessage *string    `gorm:"type:text" json:"error_message,omitempty"`
+
+	StartedAt   *time.Time `json:"started_at,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	CancelledAt *time.Time `json:"cancelled_at,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	CreatedAt   time.Time  `gorm:"default:(CURRENT_TIMESTAMP AT TIME ZONE 'UTC')" json:"created_at"`
+	UpdatedAt   time.Time  `gorm:"default:(CURRENT_TIMESTAMP AT TIME ZONE 'UTC')" json:"updated_at"`
+}
+
+func (CustomerDataRequest) TableName() string {
+	return "customer_data_requests"
+}
+
+// IsFinished reports whether the request reached a terminal status
+func (r *CustomerDataRequest) IsFinished() bool {
+	return r.Status == CustomerDataRequestStatusCompleted ||
+		r.Status == CustomerDataRequestStatusFailed ||
+		r.Status == CustomerDataRequestStatusCancelled
+}
+
+// CustomerDataRequestFilter represents filter criteria for customer data request queries
+type CustomerDataRequestFilter struct {
+	ID         *uint
+	UUID       *uuid.UUID
+	CustomerID *uint
+	Type       *CustomerDataRequestType
+	Statuses   []CustomerDataRequestStatus
+}
@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// CustomerKnownDevice is a device and network a customer logged in from. A
+// login from a device or IP range not seen before is reported to the
+// customer as a possibly suspicious login.
+type CustomerKnownDevice struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	CustomerID  uint      `gorm:"not null;index:idx_customer_known_devices_customer_id" json:"customer_id"`
+	Fingerprint string    `gorm:"size:64;not null" json:"fingerprint"`
+	IPRange     string    `gorm:"size:64;not null" json:"ip_range"`
+	Country     *string   `gorm:"size:2" json:"country,omitempty"`
+	UserAgent   *string   `gorm:"type:text" json:"user_agent,omitempty"`
+	FirstSeenAt time.Time `gorm:"default:(CURRENT_TIMESTAMP AT TIME ZONE 'UTC')" json:"first_seen_at"`
+	LastSeenAt  time.Time `gorm:"default:(CURRENT_TIMESTAMP AT TIME ZONE 'UTC')" json:"last_seen_at"`
+}
+
+func (CustomerKnownDevice) TableName() string {
+	return "customer_known_devices"
+}
+
+// CustomerKnownDeviceFilter represents filter criteria for known device queries
+type CustomerKnownDeviceFilter struct {
+	ID          *uint
+	CustomerID  *uint
+	Fingerprint *string
+	IPRange     *string
+}
@@ -0,0 +1,83 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ProfileField is a customer profile field customers can change themselves.
+// Its value is the customers column it is stored in.
+type ProfileField string
+
+const (
+	ProfileFieldCompanyName    ProfileField = "company_name"
+	ProfileFieldNationalID     ProfileField = "national_id"
+	ProfileFieldCompanyPhone   ProfileField = "company_phone"
+	ProfileFieldCompanyAddress ProfileField = "company_address"
+	ProfileFieldPostalCode     ProfileField = "postal_code"
+	ProfileFieldShebaNumber    ProfileField = "sheba_number"
+)
+
+// Sensitive reports whether a change of f waits for an admin to verify it
+// before it is applied; payouts go to the Sheba number and invoices and KYC
+// rely on the national ID
+func (f ProfileField) Sensitive() bool {
+	return f == ProfileFieldNationalID || f == ProfileFieldShebaNumber
+}
+
+// ProfileChangeStatus is where a profile field change is
+type ProfileChangeStatus string
+
+const (
+	// ProfileChangeApplied changes are on the customer; sensitive changes
+	// reach it once an admin approves them
+	ProfileChangeApplied ProfileChangeStatus = "applied"
+	// ProfileChangePendingReview changes of sensitive fields wait for an admin
+	ProfileChangePendingReview ProfileChangeStatus = "pending_review"
+	// ProfileChangeRejected changes were refused by an admin and never applied
+	ProfileChangeRejected ProfileChangeStatus = "rejected"
+	// ProfileChangeSuperseded changes were replaced by a newer change of the
+	// same field while waiting for review
+	ProfileChangeSuperseded ProfileChangeStatus = "superseded"
+)
+
+// CustomerProfileChange is the history of one profile field: its value
+// before and after, who changed it and when. Rows are never deleted.
+type CustomerProfileChange struct {
+	ID         uint                `gorm:"primaryKey" json:"id"`
+	UUID       uuid.UUID           `gorm:"type:uuid;not null;uniqueIndex:uk_customer_profile_changes_uuid" json:"uuid"`
+	CustomerID uint                `gorm:"not null;index:idx_customer_profile_changes_customer_id" json:"customer_id"`
+	Field      ProfileField        `gorm:"size:40;not null" json:"field"`
+	OldValue   *string             `gorm:"size:255" json:"old_value,omitempty"`
+	NewValue   *string             `gorm:"size:255" json:"new_value,omitempty"`
+	Status     ProfileChangeStatus `gorm:"size:20;not null" json:"status"`
+	// ChangedByAdminID is the admin who made the change while impersonating
+	// the customer; nil when the customer made it
+	ChangedByAdminID *uint      `json:"changed_by_admin_id,omitempty"`
+	ReviewerID       *uint      `json:"reviewer_id,omitempty"`
+	ReviewedAt       *time.Time `json:"reviewed_at,omitempty"`
+	RejectionReason  *string    `gorm:"size:500" json:"rejection_reason,omitempty"`
+	CreatedAt        time.Time  `gorm:"default:(CURRENT_TIMESTAMP AT TIME ZONE 'UTC')" json:"created_at"`
+}
+
+func (CustomerProfileChange) TableName() string {
+	return "customer_profile_changes"
+}
+
+func (c *CustomerProfileChange) BeforeCreate(tx *gorm.DB) error {
+	if c.UUID == uuid.Nil {
+		c.UUID = uuid.New()
+	}
+	return nil
+}
+
+// CustomerProfileChangeFilter represents filter criteria for profile change queries
+type CustomerProfileChangeFilter struct {
+	ID         *uint
+	UUID       *uuid.UUID
+	CustomerID *uint
+	Field      *ProfileField
+	Status     *ProfileChangeStatus
+}
@@ -0,0 +1,85 @@
+package models
+
+import "time"
+
+// MaxFrequencyCapHours bounds CustomerSendingQuota.FrequencyCapHours, matching
+// the column's check constraint
+const MaxFrequencyCapHours = 720
+
+// CustomerSendingQuota caps how many recipients a customer may message per
+// Tehran calendar day and month, across all platforms. A nil limit means
+// unlimited; customers without a quota row are unlimited as well.
+// FrequencyCapHours, when set, keeps the customer's SMS campaigns from
+// messaging a number another of its SMS campaigns messaged within that many
+// hours.
+type CustomerSendingQuota struct {
+	ID                uint      `gorm:"primaryKey" json:"id"`
+	CustomerID        uint      `gorm:"not null;uniqueIndex" json:"customer_id"`
+	DailyLimit        *uint64   `gorm:"type:bigint" json:"daily_limit,omitempty"`
+	MonthlyLimit      *uint64   `gorm:"type:bigint" json:"monthly_limit,omitempty"`
+	FrequencyCapHours *uint     `gorm:"type:integer" json:"frequency_cap_hours,omitempty"`
+	AdminID           *uint     `json:"admin_id,omitempty"`
+	CreatedAt         time.Time `gorm:"default:(CURRENT_TIMESTAMP AT TIME ZONE 'UTC')" json:"created_at"`
+	UpdatedAt         time.Time `gorm:"default:(CURRENT_TIMESTAMP AT TIME ZONE 'UTC')" json:"updated_at"`
+}
+
+func (CustomerSendingQuota) TableName() string {
+	return "customer_sending_quotas"
+}
+
+// IsUnlimited reports whether neither a daily nor a monthly limit is set
+func (q *CustomerSendingQuota) IsUnlimited() bool {
+	return q == nil || (q.DailyLimit == nil && q.MonthlyLimit == nil)
+}
+
+// Remaining returns how many more recipients fit in both the daily and the
+// monthly limit given the recipients already counted against them. The
+// second result is false when the quota is unlimited.
+func (q *CustomerSendingQuota) Remaining(dailyUsed, monthlyUsed uint64) (uint64, bool) {
+	if q.IsUnlimited() {
+		return 0, false
+	}
+	remaining := ^uint64(0)
+	if q.DailyLimit != nil {
+		remaining = min(remaining, saturatingSub(*q.DailyLimit, dailyUsed))
+	}
+	if q.MonthlyLimit != nil {
+		remaining = min(remaining, saturatingSub(*q.MonthlyLimit, monthlyUsed))
+	}
+	return remaining, true
+}
+
+// FrequencyCap returns the SMS frequency cap window; the second result is
+// false when no cap is set
+func (q *CustomerSendingQuota) FrequencyCap() (time.Duration, bool) {
+	if q == nil || q.FrequencyCapHours == nil || *q.FrequencyCapHours == 0 {
+		return 0, false
+	}
+	return time.Duration(*q.FrequencyCapHours) * time.Hour, true
+}
+
+func saturatingSub(a, b uint64) uint64 {
+	if b >= a {
+		return 0
+	}
+	return a - b
+}
+
+// CustomerSendingQuotaFilter represents filter criteria for sending quota queries
+type CustomerSendingQuotaFilter struct {
+	ID         *uint
+	CustomerID *uint
+}
+
+// SendingQuotaUsage counts the recipients of a customer in the Tehran day and
+// month containing a point in time. Sent recipients come from processed
+// campaigns; reserved recipients belong to campaigns awaiting approval or
+// approved but not yet sent, scheduled in the same period.
+type SendingQuotaUsage struct {
+	DayStart        time.Time
+	MonthStart      time.Time
+	DailySent       uint64
+	MonthlySent     uint64
+	DailyReserved   uint64
+	MonthlyReserved uint64
+}
@@ -62,4 +62,10 @@ type DeviceInfo struct {
 	OS         string `json:"os,omitempty"`
 	DeviceType string `json:"device_type,omitempty"`
 	IsMobile   bool   `json:"is_mobile,omitempty"`
+
+	// Country is estimated from the client IP when the session is created
+	Country string `json:"country,omitempty"`
+
+	// ImpersonatedBy is the admin a support session was issued to
+	ImpersonatedBy uint `json:"impersonated_by,omitempty"`
 }
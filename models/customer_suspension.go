@@ -0,0 +1,50 @@
+package models
+
+// CustomerSuspensionLevel is how far a suspended customer is restricted.
+// Suspended customers can always log in and view their data; unlike an
+// inactive account, a suspension only blocks spending and topping up.
+type CustomerSuspensionLevel string
+
+const (
+	// CustomerSuspensionWarning restricts nothing; the customer is only shown
+	// the reason so they can fix it before stricter enforcement
+	CustomerSuspensionWarning CustomerSuspensionLevel = "warning"
+	// CustomerSuspensionRestricted blocks creating and submitting campaigns
+	CustomerSuspensionRestricted CustomerSuspensionLevel = "restricted"
+	// CustomerSuspensionSuspended also blocks recharging the wallet
+	CustomerSuspensionSuspended CustomerSuspensionLevel = "suspended"
+)
+
+// Valid reports whether l is a known suspension level
+func (l CustomerSuspensionLevel) Valid() bool {
+	switch l {
+	case CustomerSuspensionWarning, CustomerSuspensionRestricted, CustomerSuspensionSuspended:
+		return true
+	}
+	return false
+}
+
+// CustomerSuspensionReason is the reason code a customer is suspended for;
+// it is returned to the customer with every blocked request
+type CustomerSuspensionReason string
+
+const (
+	CustomerSuspensionReasonPolicyViolation      CustomerSuspensionReason = "policy_violation"
+	CustomerSuspensionReasonSpamComplaints       CustomerSuspensionReason = "spam_complaints"
+	CustomerSuspensionReasonPaymentDispute       CustomerSuspensionReason = "payment_dispute"
+	CustomerSuspensionReasonFraudReview          CustomerSuspensionReason = "fraud_review"
+	CustomerSuspensionReasonVerificationRequired CustomerSuspensionReason = "verification_required"
+)
+
+// Valid reports whether r is a known suspension reason
+func (r CustomerSuspensionReason) Valid() bool {
+	switch r {
+	case CustomerSuspensionReasonPolicyViolation,
+		CustomerSuspensionReasonSpamComplaints,
+		CustomerSuspensionReasonPaymentDispute,
+		CustomerSuspensionReasonFraudReview,
+		CustomerSuspensionReasonVerificationRequired:
+		return true
+	}
+	return false
+}
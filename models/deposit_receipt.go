@@ -29,6 +29,15 @@ type DepositReceipt struct {
 	StatusReason  string               `gorm:"type:text" json:"status_reason"`
 	ReviewerID    *uint                `gorm:"index" json:"reviewer_id,omitempty"`
 	RejectionNote *string              `gorm:"type:text" json:"rejection_note,omitempty"`
+	// The transfer as the customer reported it; receipts submitted before
+	// these were asked for have neither
+	TransferDate   *time.Time `gorm:"type:date" json:"transfer_date,omitempty"`
+	TrackingNumber *string    `gorm:"type:varchar(64)" json:"tracking_number,omitempty"`
+	// The bank statement entry finance matched the receipt with, and the
+	// amount it shows, which is what the wallet is credited with
+	BankReference *string    `gorm:"type:varchar(64)" json:"bank_reference,omitempty"`
+	MatchedAmount *uint64    `json:"matched_amount,omitempty"`
+	ReviewedAt    *time.Time `json:"reviewed_at,omitempty"`
 
 	FileName    string `gorm:"type:varchar(255);not null" json:"file_name"`
 	ContentType string `gorm:"type:varchar(120);not null" json:"content_type"`
@@ -58,4 +67,10 @@ type DepositReceiptFilter struct {
 	Lang          *string
 	CreatedAfter  *time.Time
 	CreatedBefore *time.Time
+	// TrackingNumber and BankReference find the receipts of one transfer
+	TrackingNumber *string
+	BankReference  *string
+	// OmitFileData leaves the receipt files out of listings that do not
+	// show them
+	OmitFileData bool
 }
@@ -0,0 +1,107 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// JobStatus represents the state of a queued background job
+type JobStatus string
+
+const (
+	JobStatusPending   JobStatus = "pending"
+	JobStatusRunning   JobStatus = "running"
+	JobStatusSucceeded JobStatus = "succeeded"
+	// JobStatusDead marks a job that failed MaxAttempts times. It is kept
+	// with its last error until an admin requeues it.
+	JobStatusDead JobStatus = "dead"
+	// JobStatusCancelled marks a pending or dead job an admin chose not to
+	// run, e.g. an SMS batch that must not be resent
+	JobStatusCancelled JobStatus = "cancelled"
+)
+
+// Job types; the queue looks up the handler of a job by its type
+const (
+	JobTypeSendSMS          = "send_sms"
+	JobTypeSendEmail        = "send_email"
+	JobTypePushAudienceUIDs = "push_audience_uids"
+	JobTypeSendSMSBatch     = "send_sms_batch"
+)
+
+// Job is a unit of background work, e.g. an SMS to send, that is retried
+// with backoff until it succeeds or runs out of attempts.
+// Table: jobs
+type Job struct {
+	ID          uint            `gorm:"primaryKey" json:"id"`
+	UUID        uuid.UUID       `gorm:"type:uuid;not null;uniqueIndex" json:"uuid"`
+	Type        string          `gorm:"size:64;not null" json:"type"`
+	Payload     json.RawMessage `gorm:"type:jsonb;not null;default:'{}'" json:"payload"`
+	Status      JobStatus       `gorm:"size:20;not null;default:'pending'" json:"status"`
+	Attempts    int             `gorm:"not null;default:0" json:"attempts"`
+	MaxAttempts int             `gorm:"not null;default:5" json:"max_attempts"`
+	// RunAt is when the job is due: when it was enqueued, the time it was
+	// scheduled for, or the end of the backoff after a failed attempt
+	RunAt       time.Time  `gorm:"not null" json:"run_at"`
+	LockedAt    *time.Time `json:"locked_at,omitempty"`
+	LastError   *string    `gorm:"type:text" json:"last_error,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	DeadAt      *time.Time `json:"dead_at,omitempty"`
+	CancelledAt *time.Time `json:"cancelled_at,omitempty"`
+	CreatedAt   time.Time  `gorm:"default:(CURRENT_TIMESTAMP AT TIME ZONE 'UTC')" json:"created_at"`
+	UpdatedAt   time.Time  `gorm:"default:(CURRENT_TIMESTAMP AT TIME ZONE 'UTC')" json:"updated_at"`
+}
+
+func (Job) TableName() string {
+	return "jobs"
+}
+
+// JobFilter represents filter criteria for job queries
+type JobFilter struct {
+	ID     *uint
+	UUID   *uuid.UUID
+	Type   *string
+	Status *JobStatus
+}
+
+// SendSMSJob is the payload of a send_sms job
+type SendSMSJob struct {
+	Mobile     string `json:"mobile"`
+	Message    string `json:"message"`
+	CustomerID *int64 `json:"customer_id,omitempty"`
+}
+
+// SendEmailJob is the payload of a send_email job
+type SendEmailJob struct {
+	To      string `json:"to"`
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+}
+
+// PushAudienceUIDsJob is the payload of a push_audience_uids job, which
+// hands the audience of a sent campaign to the bot
+type PushAudienceUIDsJob struct {
+	CampaignID uint     `json:"campaign_id"`
+	UIDs       []string `json:"uids"`
+	Codes      []string `json:"codes"`
+}
+
+// SendSMSBatchJob is the payload of a send_sms_batch job: a campaign batch
+// the SMS provider rejected, resent from the same sender line
+type SendSMSBatchJob struct {
+	CampaignID          uint              `json:"campaign_id"`
+	ProcessedCampaignID uint              `json:"processed_campaign_id"`
+	Sender              string            `json:"sender"`
+	Items               []SMSBatchJobItem `json:"items"`
+	// Error is the provider error of the original send
+	Error string `json:"error"`
+}
+
+// SMSBatchJobItem is one message of a send_sms_batch job
+type SMSBatchJobItem struct {
+	Recipient  string `json:"recipient"`
+	Body       string `json:"body"`
+	TrackingID string `json:"tracking_id"`
+	Parts      uint64 `json:"parts,omitempty"`
+}
@@ -0,0 +1,106 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// KYCStatus is where a company customer is in document verification
+type KYCStatus string
+
+const (
+	KYCStatusNotSubmitted  KYCStatus = "not_submitted"
+	KYCStatusPendingReview KYCStatus = "pending_review"
+	KYCStatusVerified      KYCStatus = "verified"
+	KYCStatusRejected      KYCStatus = "rejected"
+)
+
+// CanTransitionTo reports whether a customer may move from s to next.
+// Customers submit documents for review, and resubmit after a rejection; an
+// admin verifies or rejects a pending review and may later reject a verified
+// customer whose documents turn out to be invalid.
+func (s KYCStatus) CanTransitionTo(next KYCStatus) bool {
+	switch s {
+	case KYCStatusNotSubmitted, KYCStatusRejected:
+		return next == KYCStatusPendingReview
+	case KYCStatusPendingReview:
+		return next == KYCStatusVerified || next == KYCStatusRejected
+	case KYCStatusVerified:
+		return next == KYCStatusRejected
+	}
+	return false
+}
+
+// AcceptsUploads reports whether documents may be uploaded in s; documents
+// are frozen while they are reviewed and once they are verified
+func (s KYCStatus) AcceptsUploads() bool {
+	return s == KYCStatusNotSubmitted || s == KYCStatusRejected
+}
+
+// KYCDocumentKind is the kind of a document a company customer uploads
+type KYCDocumentKind string
+
+const (
+	// KYCDocumentRegistrationCertificate is the company registration certificate
+	KYCDocumentRegistrationCertificate KYCDocumentKind = "registration_certificate"
+	// KYCDocumentOfficialGazette is the official gazette notice naming the
+	// company's signatories; optional
+	KYCDocumentOfficialGazette KYCDocumentKind = "official_gazette"
+	// KYCDocumentRepresentativeID is the representative's national ID card
+	KYCDocumentRepresentativeID KYCDocumentKind = "representative_id"
+)
+
+// RequiredKYCDocumentKinds must all be uploaded before submitting for review
+var RequiredKYCDocumentKinds = []KYCDocumentKind{
+	KYCDocumentRegistrationCertificate,
+	KYCDocumentRepresentativeID,
+}
+
+// Valid reports whether k is a known document kind
+func (k KYCDocumentKind) Valid() bool {
+	switch k {
+	case KYCDocumentRegistrationCertificate, KYCDocumentOfficialGazette, KYCDocumentRepresentativeID:
+		return true
+	}
+	return false
+}
+
+// KYCDocument is a verification document a customer uploaded. The file
+// itself lives in object storage under StorageKey. Uploading the same kind
+// again supersedes the previous document, which is kept for the audit trail.
+type KYCDocument struct {
+	ID           uint            `gorm:"primaryKey" json:"id"`
+	UUID         uuid.UUID       `gorm:"type:uuid;not null;uniqueIndex:uk_kyc_documents_uuid" json:"uuid"`
+	CustomerID   uint            `gorm:"not null;index:idx_kyc_documents_customer_id" json:"customer_id"`
+	Kind         KYCDocumentKind `gorm:"size:40;not null" json:"kind"`
+	StorageKey   string          `gorm:"size:255;not null" json:"-"`
+	FileName     string          `gorm:"size:255;not null" json:"file_name"`
+	ContentType  string          `gorm:"size:120;not null" json:"content_type"`
+	FileSize     int64           `gorm:"not null" json:"file_size"`
+	SHA256       string          `gorm:"size:64;not null" json:"sha256"`
+	SupersededAt *time.Time      `json:"superseded_at,omitempty"`
+	CreatedAt    time.Time       `gorm:"default:(CURRENT_TIMESTAMP AT TIME ZONE 'UTC')" json:"created_at"`
+}
+
+func (KYCDocument) TableName() string {
+	return "kyc_documents"
+}
+
+func (d *KYCDocument) BeforeCreate(tx *gorm.DB) error {
+	if d.UUID == uuid.Nil {
+		d.UUID = uuid.New()
+	}
+	return nil
+}
+
+// KYCDocumentFilter represents filter criteria for KYC document queries
+type KYCDocumentFilter struct {
+	ID         *uint
+	UUID       *uuid.UUID
+	CustomerID *uint
+	Kind       *KYCDocumentKind
+	// Current selects documents that are not superseded
+	Current *bool
+}
@@ -5,6 +5,8 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"gorm.io/gorm"
 )
 
 // LineNumber represents a sender line with pricing factor and optional priority
@@ -14,6 +16,12 @@ import (
 // Indices on uuid, is_active, created_at, priority
 // Timestamps default to UTC at DB level
 // PriceFactor is a multiplier applied to base price (e.g., 1.1000)
+// Operator and Tier select the applicable SMS tariff (see SMSTariff)
+// RatePerSecond and Burst are the provider throughput limit of the line
+// Pool groups interchangeable lines a campaign may spill over to when saturated
+// CertifiedOperators lists the recipient operators the line may deliver to;
+// empty means all of them
+// Soft-deleted lines keep their number reserved until restored
 type LineNumber struct {
 	ID   uint      `gorm:"primaryKey" json:"id"`
 	UUID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:uk_line_numbers_uuid;index:idx_line_numbers_uuid" json:"uuid"`
@@ -22,16 +30,53 @@ type LineNumber struct {
 	LineNumber  string  `gorm:"size:20;not null;uniqueIndex:uk_line_numbers_value;index:idx_line_numbers_value" json:"line_number"`
 	PriceFactor float64 `gorm:"type:numeric(10,4);not null" json:"price_factor"`
 	Priority    *int    `gorm:"index:idx_line_numbers_priority" json:"priority,omitempty"`
+	Operator    *string `gorm:"size:20" json:"operator,omitempty"`
+	Tier        *string `gorm:"size:50" json:"tier,omitempty"`
 
-	IsActive  *bool     `gorm:"default:true;index:idx_line_numbers_is_active" json:"is_active"`
-	CreatedAt time.Time `gorm:"default:(CURRENT_TIMESTAMP AT TIME ZONE 'UTC');index:idx_line_numbers_created_at" json:"created_at"`
-	UpdatedAt time.Time `gorm:"default:(CURRENT_TIMESTAMP AT TIME ZONE 'UTC')" json:"updated_at"`
+	RatePerSecond *int    `json:"rate_per_second,omitempty"`
+	Burst         *int    `json:"burst,omitempty"`
+	Pool          *string `gorm:"size:50;index:idx_line_numbers_pool" json:"pool,omitempty"`
+
+	CertifiedOperators pq.StringArray `gorm:"type:text[]" json:"certified_operators,omitempty"`
+
+	IsActive  *bool          `gorm:"default:true;index:idx_line_numbers_is_active" json:"is_active"`
+	CreatedAt time.Time      `gorm:"default:(CURRENT_TIMESTAMP AT TIME ZONE 'UTC');index:idx_line_numbers_created_at" json:"created_at"`
+	UpdatedAt time.Time      `gorm:"default:(CURRENT_TIMESTAMP AT TIME ZONE 'UTC')" json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index:idx_line_numbers_deleted_at" json:"deleted_at,omitempty"`
 }
 
 func (LineNumber) TableName() string {
 	return "line_numbers"
 }
 
+// SendRate returns the throughput limit of the line in messages per second and
+// its burst size. A zero rate means unlimited; the burst defaults to the rate.
+func (l LineNumber) SendRate() (int, int) {
+	if l.RatePerSecond == nil || *l.RatePerSecond <= 0 {
+		return 0, 0
+	}
+	rate := *l.RatePerSecond
+	if l.Burst == nil || *l.Burst <= 0 {
+		return rate, rate
+	}
+	return rate, *l.Burst
+}
+
+// CertifiedFor reports whether the line may deliver to recipients of
+// operator. Lines without certifications deliver to every operator, and
+// recipients of an unknown operator may be sent from any line.
+func (l LineNumber) CertifiedFor(operator string) bool {
+	if len(l.CertifiedOperators) == 0 || operator == "" {
+		return true
+	}
+	for _, op := range l.CertifiedOperators {
+		if op == operator {
+			return true
+		}
+	}
+	return false
+}
+
 // LineNumberFilter represents filter criteria for line number queries
 type LineNumberFilter struct {
 	ID            *uint
@@ -40,6 +85,9 @@ type LineNumberFilter struct {
 	LineNumber    *string
 	IsActive      *bool
 	Priority      *int
+	Pool          *string
 	CreatedAfter  *time.Time
 	CreatedBefore *time.Time
+	// IncludeDeleted returns soft-deleted lines too, for audits
+	IncludeDeleted bool
 }
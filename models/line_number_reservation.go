@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LineNumberReservation gives one customer exclusive use of a line number from
+// StartsAt until ExpiresAt, or until the customer releases it earlier.
+type LineNumberReservation struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	UUID         uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex" json:"uuid"`
+	LineNumberID uint       `gorm:"not null;index" json:"line_number_id"`
+	CustomerID   uint       `gorm:"not null;index" json:"customer_id"`
+	TierID       *uint      `json:"tier_id,omitempty"`
+	Days         int        `gorm:"not null" json:"days"`
+	Price        uint64     `gorm:"type:bigint;not null;default:0" json:"price"` // Tomans
+	StartsAt     time.Time  `gorm:"not null" json:"starts_at"`
+	ExpiresAt    time.Time  `gorm:"not null" json:"expires_at"`
+	ReleasedAt   *time.Time `json:"released_at,omitempty"`
+	CreatedAt    time.Time  `gorm:"default:(CURRENT_TIMESTAMP AT TIME ZONE 'UTC')" json:"created_at"`
+	UpdatedAt    time.Time  `gorm:"default:(CURRENT_TIMESTAMP AT TIME ZONE 'UTC')" json:"updated_at"`
+
+	LineNumber *LineNumber `gorm:"foreignKey:LineNumberID" json:"line_number,omitempty"`
+}
+
+func (LineNumberReservation) TableName() string {
+	return "line_number_reservations"
+}
+
+// IsActiveAt reports whether the reservation holds the line at the given time
+func (r LineNumberReservation) IsActiveAt(at time.Time) bool {
+	return r.ReleasedAt == nil && !at.Before(r.StartsAt) && at.Before(r.ExpiresAt)
+}
+
+// LineNumberReservationFilter represents filter criteria for reservation queries
+type LineNumberReservationFilter struct {
+	ID           *uint
+	UUID         *uuid.UUID
+	LineNumberID *uint
+	CustomerID   *uint
+	// ActiveAt keeps reservations holding their line at the given time
+	ActiveAt *time.Time
+}
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// LineNumberTier prices the reservation of line numbers whose Tier matches Name.
+// Lines without a tier, or whose tier has no active row, cannot be reserved.
+type LineNumberTier struct {
+	ID                     uint      `gorm:"primaryKey" json:"id"`
+	Name                   string    `gorm:"size:50;not null;uniqueIndex" json:"name"`
+	DisplayName            *string   `gorm:"size:255" json:"display_name,omitempty"`
+	Description            *string   `gorm:"type:text" json:"description,omitempty"`
+	ReservationPricePerDay uint64    `gorm:"type:bigint;not null;default:0" json:"reservation_price_per_day"` // Tomans
+	MaxReservationDays     int       `gorm:"not null;default:30" json:"max_reservation_days"`
+	IsActive               *bool     `gorm:"not null;default:true" json:"is_active"`
+	CreatedAt              time.Time `gorm:"default:(CURRENT_TIMESTAMP AT TIME ZONE 'UTC')" json:"created_at"`
+	UpdatedAt              time.Time `gorm:"default:(CURRENT_TIMESTAMP AT TIME ZONE 'UTC')" json:"updated_at"`
+}
+
+func (LineNumberTier) TableName() string {
+	return "line_number_tiers"
+}
+
+// LineNumberTierFilter represents filter criteria for line number tier queries
+type LineNumberTierFilter struct {
+	ID       *uint
+	Name     *string
+	IsActive *bool
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MoadianSubmissionStatus is where a tax invoice is in its submission to
+// Moadian, the tax authority's e-invoicing system
+type MoadianSubmissionStatus string
+
+const (
+	MoadianSubmissionStatusPending    MoadianSubmissionStatus = "pending"    // Waiting for the first try or a retry
+	MoadianSubmissionStatusProcessing MoadianSubmissionStatus = "processing" // Claimed by a worker
+	MoadianSubmissionStatusSubmitted  MoadianSubmissionStatus = "submitted"  // Received by Moadian, result pending
+	MoadianSubmissionStatusAccepted   MoadianSubmissionStatus = "accepted"   // Registered by the tax authority
+	MoadianSubmissionStatusRejected   MoadianSubmissionStatus = "rejected"   // Refused; needs a corrected invoice
+	MoadianSubmissionStatusFailed     MoadianSubmissionStatus = "failed"     // Gave up after repeated transient failures
+)
+
+// MoadianSubmission queues a tax invoice for submission to Moadian and tracks
+// the result. Transient failures put it back to pending with a later
+// NextAttemptAt.
+type MoadianSubmission struct {
+	ID               uint                    `gorm:"primaryKey" json:"id"`
+	UUID             uuid.UUID               `gorm:"type:uuid;uniqueIndex;not null;default:gen_random_uuid()" json:"uuid"`
+	TaxInvoiceID     uint                    `gorm:"not null;uniqueIndex" json:"tax_invoice_id"`
+	PaymentRequestID *uint                   `json:"payment_request_id,omitempty"`
+	TaxID            *string                 `gorm:"type:varchar(22);uniqueIndex" json:"tax_id,omitempty"`
+	Status           MoadianSubmissionStatus `gorm:"type:varchar(20);not null;default:'pending'" json:"status"`
+	ReferenceNumber  *string                 `gorm:"type:varchar(64)" json:"reference_number,omitempty"`
+	Attempts         int                     `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt    time.Time               `gorm:"not null" json:"next_attempt_at"`
+	LastError        *string                 `gorm:"type:text" json:"last_error,omitempty"`
+	SubmittedAt      *time.Time              `json:"submitted_at,omitempty"`
+	CompletedAt      *time.Time              `json:"completed_at,omitempty"`
+	CreatedAt        time.Time               `gorm:"default:(CURRENT_TIMESTAMP AT TIME ZONE 'UTC')" json:"created_at"`
+	UpdatedAt        time.Time               `gorm:"default:(CURRENT_TIMESTAMP AT TIME ZONE 'UTC')" json:"updated_at"`
+}
+
+func (MoadianSubmission) TableName() string {
+	return "moadian_submissions"
+}
+
+// MoadianSubmissionFilter represents filter criteria for submission queries
+type MoadianSubmissionFilter struct {
+	ID           *uint
+	UUID         *uuid.UUID
+	TaxInvoiceID *uint
+	Status       *MoadianSubmissionStatus
+}
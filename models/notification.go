@@ -0,0 +1,49 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// NotificationKind is the domain event a notification reports
+type NotificationKind string
+
+const (
+	NotificationKindCampaignApproved NotificationKind = "campaign_approved"
+	NotificationKindCampaignRejected NotificationKind = "campaign_rejected"
+	NotificationKindPaymentCredited  NotificationKind = "payment_credited"
+	NotificationKindLowBalance       NotificationKind = "low_balance"
+	// A campaign still waiting for approval past its schedule time expired
+	// and its reserved budget was refunded
+	NotificationKindCampaignExpired NotificationKind = "campaign_expired"
+	// Finance rejected a bank transfer receipt; an approved one is reported
+	// as a credited payment
+	NotificationKindDepositReceiptRejected NotificationKind = "deposit_receipt_rejected"
+)
+
+// Notification is an entry in a customer's in-app inbox. It stores the
+// event and its arguments, not text: the title and body are rendered in the
+// customer's locale when the inbox is read.
+type Notification struct {
+	ID         uint             `gorm:"primaryKey" json:"id"`
+	CustomerID uint             `gorm:"not null;index:idx_notifications_customer_id" json:"customer_id"`
+	Kind       NotificationKind `gorm:"type:varchar(30);not null" json:"kind"`
+	Args       json.RawMessage  `gorm:"type:jsonb;not null;default:'{}'" json:"args"`
+	ReadAt     *time.Time       `json:"read_at,omitempty"`
+	CreatedAt  time.Time        `gorm:"default:(CURRENT_TIMESTAMP AT TIME ZONE 'UTC')" json:"created_at"`
+}
+
+func (Notification) TableName() string {
+	return "notifications"
+}
+
+// NotificationFilter represents filter criteria for notification queries
+type NotificationFilter struct {
+	ID         *uint
+	CustomerID *uint
+	Kind       *NotificationKind
+	// Unread keeps notifications that have not been read
+	Unread bool
+	// BeforeID keeps notifications older than a page cursor
+	BeforeID *uint
+}
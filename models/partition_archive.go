@@ -0,0 +1,54 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PartitionArchive records one monthly partition of a partitioned table that
+// was exported to the archive store and dropped from the database.
+type PartitionArchive struct {
+	ID            uint       `gorm:"primaryKey" json:"id"`
+	UUID          uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex" json:"uuid"`
+	ParentTable   string     `gorm:"size:63;not null" json:"parent_table"`
+	PartitionName string     `gorm:"size:63;not null;uniqueIndex" json:"partition_name"`
+	RangeStart    *time.Time `json:"range_start,omitempty"` // nil for the legacy partition starting at MINVALUE
+	RangeEnd      time.Time  `gorm:"not null" json:"range_end"`
+	RowCount      int64      `gorm:"not null;default:0" json:"row_count"`
+	StorageKey    string     `gorm:"type:text;not null" json:"storage_key"`
+	SizeBytes     int64      `gorm:"not null;default:0" json:"size_bytes"`
+	Checksum      string     `gorm:"column:checksum_sha256;size:64;not null" json:"checksum_sha256"`
+	ArchivedAt    time.Time  `gorm:"not null" json:"archived_at"`
+	CreatedAt     time.Time  `gorm:"default:(CURRENT_TIMESTAMP AT TIME ZONE 'UTC')" json:"created_at"`
+}
+
+func (PartitionArchive) TableName() string {
+	return "partition_archives"
+}
+
+// Covers reports whether the archived range contains the given time
+func (a PartitionArchive) Covers(at time.Time) bool {
+	return (a.RangeStart == nil || !at.Before(*a.RangeStart)) && at.Before(a.RangeEnd)
+}
+
+// PartitionArchiveFilter represents filter criteria for partition archive queries
+type PartitionArchiveFilter struct {
+	ID            *uint
+	UUID          *uuid.UUID
+	ParentTable   *string
+	PartitionName *string
+	// OverlapsFrom/OverlapsTo keep archives whose range intersects [from, to)
+	OverlapsFrom *time.Time
+	OverlapsTo   *time.Time
+}
+
+// TablePartition describes one attached partition of a range partitioned
+// table. RangeStart is nil for a MINVALUE lower bound; both bounds are nil for
+// the DEFAULT partition.
+type TablePartition struct {
+	Name       string
+	RangeStart *time.Time
+	RangeEnd   *time.Time
+	IsDefault  bool
+}
@@ -47,6 +47,9 @@ type PaymentRequest struct {
 	// Atipay response data
 	AtipayToken  string `gorm:"type:varchar(255);index" json:"atipay_token"` // Token from Atipay get-token
 	AtipayStatus string `gorm:"type:varchar(50)" json:"atipay_status"`       // Status from Atipay
+	// AtipayTerminal is the terminal the token was requested from; empty on
+	// requests made before terminals were recorded, which used the primary one
+	AtipayTerminal string `gorm:"type:varchar(255);not null;default:'';index" json:"atipay_terminal"`
 
 	// Payment result data (from redirect-to-gateway callback)
 	PaymentState       string `gorm:"type:varchar(50)" json:"payment_state"`            // Atipay state parameter
@@ -58,10 +61,18 @@ type PaymentRequest struct {
 	PaymentMaskedPAN   string `gorm:"type:varchar(255)" json:"payment_masked_pan"`      // Atipay maskedPan
 	PaymentRRN         string `gorm:"type:varchar(255)" json:"payment_rrn"`             // Atipay RRN
 
+	// Moadian e-invoice submission of the charge's tax invoice
+	MoadianStatus          *string `gorm:"type:varchar(20)" json:"moadian_status,omitempty"`
+	MoadianTaxID           *string `gorm:"type:varchar(22)" json:"moadian_tax_id,omitempty"`
+	MoadianReferenceNumber *string `gorm:"type:varchar(64)" json:"moadian_reference_number,omitempty"`
+
 	// Status tracking
 	Status       PaymentRequestStatus `gorm:"type:varchar(20);not null;default:'created';index" json:"status"`
 	StatusReason string               `gorm:"type:text" json:"status_reason"` // Reason for status change
 
+	// Version advances with every update; Update fails on a stale copy
+	Version int64 `gorm:"not null;default:0" json:"version"`
+
 	// Metadata and audit
 	Metadata  json.RawMessage `gorm:"type:jsonb;default:'{}'" json:"metadata"`
 	CreatedAt time.Time       `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
@@ -129,6 +140,7 @@ type PaymentRequestFilter struct {
 	AtipayToken      *string               `json:"atipay_token,omitempty"`
 	PaymentReference *string               `json:"payment_reference,omitempty"`
 	Status           *PaymentRequestStatus `json:"status,omitempty"`
+	PaymentChannel   *string               `json:"payment_channel,omitempty"` // metadata payment_channel, e.g. atipay
 	CreatedAfter     *time.Time            `json:"created_after,omitempty"`
 	CreatedBefore    *time.Time            `json:"created_before,omitempty"`
 	ExpiresAfter     *time.Time            `json:"expires_after,omitempty"`
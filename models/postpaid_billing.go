@@ -0,0 +1,117 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CustomerCreditLine lets an enterprise customer finalize campaigns on
+// credit. When a campaign budget exceeds the wallet balance, the shortfall is
+// drawn from the credit line into the wallet and added to Outstanding, so the
+// customer's net balance goes negative by at most CreditLimit. Draws are
+// billed on monthly postpaid invoices; Outstanding drops once they are paid.
+type CustomerCreditLine struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	CustomerID  uint      `gorm:"not null;uniqueIndex" json:"customer_id"`
+	CreditLimit uint64    `gorm:"type:bigint;not null;default:0" json:"credit_limit"` // Tomans
+	Outstanding uint64    `gorm:"type:bigint;not null;default:0" json:"outstanding"`  // Tomans drawn and not yet paid
+	AdminID     *uint     `json:"admin_id,omitempty"`
+	CreatedAt   time.Time `gorm:"default:(CURRENT_TIMESTAMP AT TIME ZONE 'UTC')" json:"created_at"`
+	UpdatedAt   time.Time `gorm:"default:(CURRENT_TIMESTAMP AT TIME ZONE 'UTC')" json:"updated_at"`
+}
+
+func (CustomerCreditLine) TableName() string {
+	return "customer_credit_lines"
+}
+
+// Available returns how much more may be drawn, floored at zero since the
+// limit may have been lowered below what is outstanding
+func (l *CustomerCreditLine) Available() uint64 {
+	if l == nil {
+		return 0
+	}
+	return saturatingSub(l.CreditLimit, l.Outstanding)
+}
+
+// CustomerCreditLineFilter represents filter criteria for credit line queries
+type CustomerCreditLineFilter struct {
+	ID         *uint
+	CustomerID *uint
+}
+
+// PostpaidDraw is one amount drawn from a credit line to fund a campaign. It
+// is billed on the invoice of the Tehran month it was drawn in.
+type PostpaidDraw struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	CustomerID    uint      `gorm:"not null;index" json:"customer_id"`
+	CampaignID    *uint     `gorm:"index" json:"campaign_id,omitempty"`
+	Amount        uint64    `gorm:"type:bigint;not null" json:"amount"` // Tomans
+	TransactionID uint      `gorm:"not null" json:"transaction_id"`
+	InvoiceID     *uint     `gorm:"index" json:"invoice_id,omitempty"`
+	CreatedAt     time.Time `gorm:"default:(CURRENT_TIMESTAMP AT TIME ZONE 'UTC')" json:"created_at"`
+}
+
+func (PostpaidDraw) TableName() string {
+	return "postpaid_draws"
+}
+
+// PostpaidDrawFilter represents filter criteria for draw queries
+type PostpaidDrawFilter struct {
+	ID         *uint
+	CustomerID *uint
+	CampaignID *uint
+	InvoiceID  *uint
+}
+
+// PostpaidDrawTotal sums a customer's uninvoiced draws
+type PostpaidDrawTotal struct {
+	CustomerID uint
+	Amount     uint64
+	Draws      int64
+}
+
+type PostpaidInvoiceStatus string
+
+const (
+	PostpaidInvoiceStatusOpen PostpaidInvoiceStatus = "open"
+	PostpaidInvoiceStatusPaid PostpaidInvoiceStatus = "paid"
+)
+
+// PostpaidInvoice bills the credit drawn by a customer in a Tehran calendar
+// month. An open invoice past DueAt is overdue and blocks new campaigns.
+type PostpaidInvoice struct {
+	ID               uint                  `gorm:"primaryKey" json:"id"`
+	UUID             uuid.UUID             `gorm:"type:uuid;uniqueIndex;not null;default:gen_random_uuid()" json:"uuid"`
+	CustomerID       uint                  `gorm:"not null;index" json:"customer_id"`
+	PeriodStart      time.Time             `gorm:"not null" json:"period_start"`
+	PeriodEnd        time.Time             `gorm:"not null" json:"period_end"`
+	Amount           uint64                `gorm:"type:bigint;not null" json:"amount"` // Tomans
+	NumDraws         int64                 `gorm:"not null" json:"num_draws"`
+	Status           PostpaidInvoiceStatus `gorm:"type:varchar(10);not null;default:'open';index" json:"status"`
+	DueAt            time.Time             `gorm:"not null" json:"due_at"`
+	PaidAt           *time.Time            `json:"paid_at,omitempty"`
+	PaidByAdminID    *uint                 `json:"paid_by_admin_id,omitempty"`
+	PaymentReference string                `gorm:"type:varchar(255)" json:"payment_reference,omitempty"`
+	CreatedAt        time.Time             `gorm:"default:(CURRENT_TIMESTAMP AT TIME ZONE 'UTC')" json:"created_at"`
+	UpdatedAt        time.Time             `gorm:"default:(CURRENT_TIMESTAMP AT TIME ZONE 'UTC')" json:"updated_at"`
+}
+
+func (PostpaidInvoice) TableName() string {
+	return "postpaid_invoices"
+}
+
+// IsOverdue reports whether the invoice is still open after its due date
+func (i *PostpaidInvoice) IsOverdue(now time.Time) bool {
+	return i.Status == PostpaidInvoiceStatusOpen && now.After(i.DueAt)
+}
+
+// PostpaidInvoiceFilter represents filter criteria for invoice queries
+type PostpaidInvoiceFilter struct {
+	ID          *uint
+	UUID        *uuid.UUID
+	CustomerID  *uint
+	Status      *PostpaidInvoiceStatus
+	PeriodStart *time.Time
+	DueBefore   *time.Time
+}
@@ -23,6 +23,13 @@ type ProcessedCampaign struct {
 	Statistics     json.RawMessage `gorm:"type:jsonb;not null;default:'{}'" json:"statistics"`
 	// Reference to the audience selection snapshot used when preparing this campaign
 	AudienceSelectionID *uint `gorm:"index:idx_processed_campaigns_audience_selection_id" json:"audience_selection_id,omitempty"`
+	// Number of audience candidates skipped because they were blacklisted
+	BlacklistedExcluded int64 `gorm:"not null;default:0" json:"blacklisted_excluded"`
+	// Number of selected recipients dropped because the customer's sending quota was exhausted
+	QuotaExcluded int64 `gorm:"not null;default:0" json:"quota_excluded"`
+	// Number of audience candidates skipped because another SMS campaign of the
+	// customer messaged them within its frequency cap
+	FrequencyCapped int64 `gorm:"not null;default:0" json:"frequency_capped"`
 
 	CreatedAt time.Time `gorm:"default:(CURRENT_TIMESTAMP AT TIME ZONE 'UTC')" json:"created_at"`
 	UpdatedAt time.Time `gorm:"default:(CURRENT_TIMESTAMP AT TIME ZONE 'UTC')" json:"updated_at"`
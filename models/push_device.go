@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// Platforms a push device registers from
+const (
+	PushPlatformWeb     = "web"
+	PushPlatformAndroid = "android"
+	PushPlatformIOS     = "ios"
+)
+
+// PushDevice is an FCM registration token of a customer's browser or app.
+// A token belongs to one customer at a time: registering it again moves it.
+type PushDevice struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	CustomerID uint      `gorm:"not null;index:idx_push_devices_customer_id" json:"customer_id"`
+	Token      string    `gorm:"type:text;not null;uniqueIndex:uk_push_devices_token" json:"-"`
+	Platform   string    `gorm:"size:10;not null" json:"platform"`
+	UserAgent  *string   `gorm:"size:255" json:"user_agent,omitempty"`
+	LastSeenAt time.Time `gorm:"not null" json:"last_seen_at"`
+	CreatedAt  time.Time `gorm:"default:(CURRENT_TIMESTAMP AT TIME ZONE 'UTC')" json:"created_at"`
+	UpdatedAt  time.Time `gorm:"default:(CURRENT_TIMESTAMP AT TIME ZONE 'UTC')" json:"updated_at"`
+}
+
+func (PushDevice) TableName() string {
+	return "push_devices"
+}
+
+// PushDeviceFilter represents filter criteria for push device queries
+type PushDeviceFilter struct {
+	ID         *uint
+	CustomerID *uint
+	Token      *string
+}
@@ -0,0 +1,67 @@
+package models
+
+import "time"
+
+// ReportRollupDaily names the watermark of the daily reporting rollups
+const ReportRollupDaily = "daily"
+
+// CampaignDailyStat is one campaign's messages, unique clickers and budget on
+// one Tehran day. Clicks are unique per day, so summing days over-counts
+// clickers who came back.
+type CampaignDailyStat struct {
+	StatDate           time.Time `gorm:"type:date;primaryKey" json:"stat_date"`
+	CampaignID         uint      `gorm:"primaryKey" json:"campaign_id"`
+	CustomerID         uint      `gorm:"not null" json:"customer_id"`
+	SentMessages       int64     `gorm:"not null;default:0" json:"sent_messages"`
+	SuccessfulMessages int64     `gorm:"not null;default:0" json:"successful_messages"`
+	DeliveredParts     int64     `gorm:"not null;default:0" json:"delivered_parts"`
+	Clicks             int64     `gorm:"not null;default:0" json:"clicks"`
+	Spend              uint64    `gorm:"not null;default:0" json:"spend"`
+	Refunds            uint64    `gorm:"not null;default:0" json:"refunds"`
+	RefreshedAt        time.Time `gorm:"not null" json:"refreshed_at"`
+}
+
+func (CampaignDailyStat) TableName() string { return "campaign_daily_stats" }
+
+// RevenueDaily is the admin financial report of one Tehran day
+type RevenueDaily struct {
+	StatDate           time.Time `gorm:"type:date;primaryKey" json:"stat_date"`
+	Charges            int64     `gorm:"not null;default:0" json:"charges"`
+	RevenueWithTax     uint64    `gorm:"not null;default:0" json:"revenue_with_tax"`
+	TaxCollected       uint64    `gorm:"not null;default:0" json:"tax_collected"`
+	SystemShare        uint64    `gorm:"not null;default:0" json:"system_share"`
+	AgencyShareWithTax uint64    `gorm:"not null;default:0" json:"agency_share_with_tax"`
+	CampaignSpend      uint64    `gorm:"not null;default:0" json:"campaign_spend"`
+	CampaignRefunds    uint64    `gorm:"not null;default:0" json:"campaign_refunds"`
+	RefreshedAt        time.Time `gorm:"not null" json:"refreshed_at"`
+}
+
+func (RevenueDaily) TableName() string { return "revenue_daily" }
+
+// CustomerMonthlyUsage is a customer's campaign usage and wallet charges in
+// one month, keyed by the first Tehran day of the month
+type CustomerMonthlyUsage struct {
+	Month              time.Time `gorm:"type:date;primaryKey" json:"month"`
+	CustomerID         uint      `gorm:"primaryKey" json:"customer_id"`
+	Campaigns          int64     `gorm:"not null;default:0" json:"campaigns"`
+	SentMessages       int64     `gorm:"not null;default:0" json:"sent_messages"`
+	SuccessfulMessages int64     `gorm:"not null;default:0" json:"successful_messages"`
+	DeliveredParts     int64     `gorm:"not null;default:0" json:"delivered_parts"`
+	Clicks             int64     `gorm:"not null;default:0" json:"clicks"`
+	CampaignSpend      uint64    `gorm:"not null;default:0" json:"campaign_spend"`
+	CampaignRefunds    uint64    `gorm:"not null;default:0" json:"campaign_refunds"`
+	Charges            int64     `gorm:"not null;default:0" json:"charges"`
+	PaidWithTax        uint64    `gorm:"not null;default:0" json:"paid_with_tax"`
+	RefreshedAt        time.Time `gorm:"not null" json:"refreshed_at"`
+}
+
+func (CustomerMonthlyUsage) TableName() string { return "customer_monthly_usage" }
+
+// ReportRollupState is the last Tehran day a rollup has been refreshed through
+type ReportRollupState struct {
+	Name             string    `gorm:"primaryKey" json:"name"`
+	RefreshedThrough time.Time `gorm:"type:date;not null" json:"refreshed_through"`
+	RefreshedAt      time.Time `gorm:"not null" json:"refreshed_at"`
+}
+
+func (ReportRollupState) TableName() string { return "report_rollup_state" }
@@ -19,11 +19,22 @@ type SentSMS struct {
 	TrackingID          string        `gorm:"size:64;not null;index:idx_sent_sms_tracking_id" json:"tracking_id"`
 	PartsDelivered      int           `gorm:"default:0" json:"parts_delivered"`
 	Status              SMSSendStatus `gorm:"type:sent_sms_status;not null;default:'pending';index:idx_sent_sms_status" json:"status"`
+	// Variant is the label of the A/B content variant sent, if any
+	Variant *string `gorm:"size:16" json:"variant,omitempty"`
+	// Sender is the line that sent the message; it differs from the campaign
+	// line when the campaign spilled over to another line of the same pool
+	Sender *string `gorm:"size:20" json:"sender,omitempty"`
+	// Operator is the recipient's mobile operator (mci, mtn or rightel), nil
+	// when the number's prefix is unknown
+	Operator *string `gorm:"size:16" json:"operator,omitempty"`
 
 	// Provider response fields (optional, populated after provider acknowledgement)
 	ServerID    *string `gorm:"size:64" json:"server_id,omitempty"`
 	ErrorCode   *string `gorm:"size:64" json:"error_code,omitempty"`
 	Description *string `gorm:"type:text" json:"description,omitempty"`
+	// PermanentError is set when the provider rejected the message with a
+	// permanent error code; the color worker demotes the recipient's profile
+	PermanentError bool `gorm:"not null;default:false" json:"permanent_error"`
 
 	CreatedAt time.Time `gorm:"default:(CURRENT_TIMESTAMP AT TIME ZONE 'UTC');index:idx_sent_sms_created_at" json:"created_at"`
 	UpdatedAt time.Time `gorm:"default:(CURRENT_TIMESTAMP AT TIME ZONE 'UTC')" json:"updated_at"`
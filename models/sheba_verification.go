@@ -0,0 +1,27 @@
+package models
+
+// ShebaVerificationStatus is the result of the bank inquiry of a customer's
+// Sheba number
+type ShebaVerificationStatus string
+
+const (
+	// ShebaUnverified numbers were never looked up, or changed since
+	ShebaUnverified ShebaVerificationStatus = "unverified"
+	// ShebaVerified numbers belong to an active account of the customer
+	ShebaVerified ShebaVerificationStatus = "verified"
+	// ShebaOwnerMismatch numbers belong to someone else
+	ShebaOwnerMismatch ShebaVerificationStatus = "owner_mismatch"
+	// ShebaInactive numbers belong to a blocked or closed account, or to no
+	// account the bank knows
+	ShebaInactive ShebaVerificationStatus = "inactive"
+)
+
+// ShebaStatus returns the verification state of the customer's current Sheba
+// number. The stored result only holds for the number it was looked up for,
+// so a changed number counts as unverified.
+func (c *Customer) ShebaStatus() ShebaVerificationStatus {
+	if c.ShebaVerification == nil || c.ShebaNumber == nil || c.ShebaVerifiedNumber == nil || *c.ShebaVerifiedNumber != *c.ShebaNumber {
+		return ShebaUnverified
+	}
+	return *c.ShebaVerification
+}
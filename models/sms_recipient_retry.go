@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+// SMSRetryStatus enumerates the states of an SMS recipient retry
+type SMSRetryStatus string
+
+const (
+	// SMSRetryStatusPending waits for its next attempt
+	SMSRetryStatusPending SMSRetryStatus = "pending"
+	// SMSRetryStatusRecovered was accepted by the provider on a resend
+	SMSRetryStatusRecovered SMSRetryStatus = "recovered"
+	// SMSRetryStatusExhausted still failed after its last attempt
+	SMSRetryStatusExhausted SMSRetryStatus = "exhausted"
+	// SMSRetryStatusPermanent got a permanent error code on a resend
+	SMSRetryStatusPermanent SMSRetryStatus = "permanent"
+)
+
+// SMSRecipientRetry is an SMS the provider rejected with a transient error
+// code, resent from the same line under the same tracking ID until it is
+// accepted or runs out of attempts. Attempts counts resends only.
+// Table: sms_recipient_retries
+type SMSRecipientRetry struct {
+	ID                  uint           `gorm:"primaryKey" json:"id"`
+	CampaignID          uint           `gorm:"not null;index:idx_sms_recipient_retries_campaign_id" json:"campaign_id"`
+	ProcessedCampaignID uint           `gorm:"not null;uniqueIndex:idx_sms_recipient_retries_processed_campaign_tracking" json:"processed_campaign_id"`
+	TrackingID          string         `gorm:"size:64;not null;uniqueIndex:idx_sms_recipient_retries_processed_campaign_tracking" json:"tracking_id"`
+	Recipient           string         `gorm:"size:20;not null" json:"recipient"`
+	Sender              string         `gorm:"size:20;not null" json:"sender"`
+	Body                string         `gorm:"type:text;not null" json:"body"`
+	Parts               uint64         `gorm:"not null;default:0" json:"parts"`
+	Status              SMSRetryStatus `gorm:"size:16;not null;default:'pending'" json:"status"`
+	Attempts            int            `gorm:"not null;default:0" json:"attempts"`
+	MaxAttempts         int            `gorm:"not null" json:"max_attempts"`
+	NextAttemptAt       time.Time      `gorm:"not null" json:"next_attempt_at"`
+	LastErrorCode       *string        `gorm:"size:64" json:"last_error_code,omitempty"`
+	CreatedAt           time.Time      `gorm:"default:(CURRENT_TIMESTAMP AT TIME ZONE 'UTC')" json:"created_at"`
+	UpdatedAt           time.Time      `gorm:"default:(CURRENT_TIMESTAMP AT TIME ZONE 'UTC')" json:"updated_at"`
+}
+
+func (SMSRecipientRetry) TableName() string {
+	return "sms_recipient_retries"
+}
+
+// SMSRecipientRetryFilter represents filter criteria for SMS retry queries
+type SMSRecipientRetryFilter struct {
+	ID                  *uint
+	CampaignID          *uint
+	ProcessedCampaignID *uint
+	TrackingID          *string
+	Status              *SMSRetryStatus
+}
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SMS operators a line number can belong to
+const (
+	SMSOperatorMCI     = "mci"
+	SMSOperatorMTN     = "mtn"
+	SMSOperatorRightel = "rightel"
+)
+
+// IsValidSMSOperator reports whether op is a known SMS operator
+func IsValidSMSOperator(op string) bool {
+	switch op {
+	case SMSOperatorMCI, SMSOperatorMTN, SMSOperatorRightel:
+		return true
+	}
+	return false
+}
+
+// SMSTariff is a per-part SMS price for a combination of operator, line number
+// tier, account type and part count. Nil dimensions act as wildcards and the
+// most specific active tariff wins.
+// Table: sms_tariffs
+type SMSTariff struct {
+	ID   uint      `gorm:"primaryKey" json:"id"`
+	UUID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:uk_sms_tariffs_uuid" json:"uuid"`
+
+	Operator       *string `gorm:"size:20" json:"operator,omitempty"`
+	LineNumberTier *string `gorm:"size:50" json:"line_number_tier,omitempty"`
+	AccountType    *string `gorm:"size:50" json:"account_type,omitempty"`
+	PartCount      *uint64 `json:"part_count,omitempty"`
+	PricePerPart   uint64  `gorm:"not null" json:"price_per_part"`
+	Description    *string `gorm:"type:text" json:"description,omitempty"`
+
+	IsActive  *bool     `gorm:"default:true;index:idx_sms_tariffs_is_active" json:"is_active"`
+	CreatedAt time.Time `gorm:"default:(CURRENT_TIMESTAMP AT TIME ZONE 'UTC')" json:"created_at"`
+	UpdatedAt time.Time `gorm:"default:(CURRENT_TIMESTAMP AT TIME ZONE 'UTC')" json:"updated_at"`
+}
+
+func (SMSTariff) TableName() string {
+	return "sms_tariffs"
+}
+
+// SMSTariffFilter represents filter criteria for SMS tariff queries
+type SMSTariffFilter struct {
+	ID          *uint
+	UUID        *uuid.UUID
+	Operator    *string
+	AccountType *string
+	IsActive    *bool
+}
@@ -1,21 +1,27 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 // Tag represents a label used to categorize or target entities like audiences
 // Table: tags
 // Unique by name; indexed by is_active and created_at
 // Timestamps default to UTC at DB level
 // Name length limited to 255 characters
+// Soft-deleted tags keep their name reserved until restored
 type Tag struct {
-	ID              uint      `gorm:"primaryKey" json:"id"`
-	Name            string    `gorm:"size:255;not null;uniqueIndex:uk_tags_name;index:idx_tags_name" json:"name"`
-	DisplayTitle    *string   `gorm:"type:text" json:"display_title,omitempty"`
-	AudiencePersona *string   `gorm:"type:text" json:"audience_persona,omitempty"`
-	AudienceCount   *int64    `gorm:"type:bigint" json:"audience_count,omitempty"`
-	IsActive        *bool     `gorm:"default:true;index:idx_tags_is_active" json:"is_active"`
-	CreatedAt       time.Time `gorm:"default:(CURRENT_TIMESTAMP AT TIME ZONE 'UTC');index:idx_tags_created_at" json:"created_at"`
-	UpdatedAt       time.Time `gorm:"default:(CURRENT_TIMESTAMP AT TIME ZONE 'UTC')" json:"updated_at"`
+	ID              uint           `gorm:"primaryKey" json:"id"`
+	Name            string         `gorm:"size:255;not null;uniqueIndex:uk_tags_name;index:idx_tags_name" json:"name"`
+	DisplayTitle    *string        `gorm:"type:text" json:"display_title,omitempty"`
+	AudiencePersona *string        `gorm:"type:text" json:"audience_persona,omitempty"`
+	AudienceCount   *int64         `gorm:"type:bigint" json:"audience_count,omitempty"`
+	IsActive        *bool          `gorm:"default:true;index:idx_tags_is_active" json:"is_active"`
+	CreatedAt       time.Time      `gorm:"default:(CURRENT_TIMESTAMP AT TIME ZONE 'UTC');index:idx_tags_created_at" json:"created_at"`
+	UpdatedAt       time.Time      `gorm:"default:(CURRENT_TIMESTAMP AT TIME ZONE 'UTC')" json:"updated_at"`
+	DeletedAt       gorm.DeletedAt `gorm:"index:idx_tags_deleted_at" json:"deleted_at,omitempty"`
 }
 
 func (Tag) TableName() string { return "tags" }
@@ -27,4 +33,6 @@ type TagFilter struct {
 	IsActive      *bool
 	CreatedAfter  *time.Time
 	CreatedBefore *time.Time
+	// IncludeDeleted returns soft-deleted tags too, for audits
+	IncludeDeleted bool
 }
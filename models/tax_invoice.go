@@ -0,0 +1,72 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TaxInvoice is the official invoice of a successful wallet charge. Its
+// number is allocated in the charge's database transaction from a counter per
+// legal entity, so numbers of one entity have no gaps. Seller and buyer are
+// stored as issued, and the PDF is rendered from the row when first
+// downloaded.
+type TaxInvoice struct {
+	ID               uint            `gorm:"primaryKey" json:"id"`
+	UUID             uuid.UUID       `gorm:"type:uuid;uniqueIndex;not null;default:gen_random_uuid()" json:"uuid"`
+	LegalEntity      string          `gorm:"type:varchar(16);not null" json:"legal_entity"`
+	SequenceNumber   uint64          `gorm:"type:bigint;not null" json:"sequence_number"`
+	InvoiceNumber    string          `gorm:"type:varchar(40);uniqueIndex;not null" json:"invoice_number"`
+	CustomerID       uint            `gorm:"not null;index" json:"customer_id"`
+	TransactionID    uint            `gorm:"not null;uniqueIndex" json:"transaction_id"`
+	PaymentRequestID *uint           `json:"payment_request_id,omitempty"` // nil for crypto charges
+	PaymentChannel   string          `gorm:"type:varchar(40);not null" json:"payment_channel"`
+	Reference        string          `gorm:"column:payment_reference;type:varchar(255)" json:"payment_reference,omitempty"`
+	AmountWithTax    uint64          `gorm:"type:bigint;not null" json:"amount_with_tax"` // Tomans
+	Amount           uint64          `gorm:"type:bigint;not null" json:"amount"`
+	Tax              uint64          `gorm:"type:bigint;not null" json:"tax"`
+	TaxBasisPoints   uint64          `gorm:"type:bigint;not null" json:"tax_basis_points"`
+	Description      string          `gorm:"type:varchar(255);not null" json:"description"`
+	Seller           json.RawMessage `gorm:"type:jsonb;not null" json:"seller"`
+	Buyer            json.RawMessage `gorm:"type:jsonb;not null" json:"buyer"`
+	IssuedAt         time.Time       `gorm:"not null" json:"issued_at"`
+	PDFPath          string          `gorm:"column:pdf_path;type:varchar(512)" json:"-"`
+	PDFGeneratedAt   *time.Time      `gorm:"column:pdf_generated_at" json:"pdf_generated_at,omitempty"`
+	CreatedAt        time.Time       `gorm:"default:(CURRENT_TIMESTAMP AT TIME ZONE 'UTC')" json:"created_at"`
+	UpdatedAt        time.Time       `gorm:"default:(CURRENT_TIMESTAMP AT TIME ZONE 'UTC')" json:"updated_at"`
+}
+
+func (TaxInvoice) TableName() string {
+	return "tax_invoices"
+}
+
+// TaxInvoiceNumber formats the official number of an invoice, e.g. JZB-00000042
+func TaxInvoiceNumber(legalEntity string, sequenceNumber uint64) string {
+	return fmt.Sprintf("%s-%08d", legalEntity, sequenceNumber)
+}
+
+// TaxInvoiceParty is the seller or buyer printed on an invoice
+type TaxInvoiceParty struct {
+	Name               string `json:"name"`
+	EconomicCode       string `json:"economic_code,omitempty"`
+	NationalID         string `json:"national_id,omitempty"`
+	RegistrationNumber string `json:"registration_number,omitempty"`
+	Address            string `json:"address,omitempty"`
+	PostalCode         string `json:"postal_code,omitempty"`
+	Phone              string `json:"phone,omitempty"`
+	Mobile             string `json:"mobile,omitempty"`
+	Email              string `json:"email,omitempty"`
+}
+
+// TaxInvoiceFilter represents filter criteria for invoice queries
+type TaxInvoiceFilter struct {
+	ID            *uint
+	UUID          *uuid.UUID
+	CustomerID    *uint
+	TransactionID *uint
+	LegalEntity   *string
+	IssuedAfter   *time.Time
+	IssuedBefore  *time.Time
+}
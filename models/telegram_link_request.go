@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// TelegramLinkRequest links a customer to a Telegram chat. The customer opens
+// the bot with the deep-link token, the bot records the chat and sends a
+// verification code there, and entering the code in the dashboard completes
+// the link. Only hashes of the token and the code are stored.
+type TelegramLinkRequest struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	CustomerID uint       `gorm:"not null;index:idx_telegram_link_requests_customer_id" json:"customer_id"`
+	TokenHash  string     `gorm:"size:64;not null;uniqueIndex:uk_telegram_link_requests_token_hash" json:"-"`
+	ChatID     *int64     `json:"-"`
+	CodeHash   *string    `gorm:"size:64" json:"-"`
+	Attempts   int        `gorm:"not null;default:0" json:"attempts"`
+	ExpiresAt  time.Time  `gorm:"not null" json:"expires_at"`
+	LinkedAt   *time.Time `json:"linked_at,omitempty"`
+	CreatedAt  time.Time  `gorm:"default:(CURRENT_TIMESTAMP AT TIME ZONE 'UTC')" json:"created_at"`
+	UpdatedAt  time.Time  `gorm:"default:(CURRENT_TIMESTAMP AT TIME ZONE 'UTC')" json:"updated_at"`
+}
+
+func (TelegramLinkRequest) TableName() string {
+	return "telegram_link_requests"
+}
+
+// TelegramLinkRequestFilter represents filter criteria for link request queries
+type TelegramLinkRequestFilter struct {
+	ID         *uint
+	CustomerID *uint
+	TokenHash  *string
+	// Pending keeps requests that have not completed a link
+	Pending bool
+	// ActiveAt keeps requests that have not expired at the given time
+	ActiveAt *time.Time
+}
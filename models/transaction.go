@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"time"
 
+	"github.com/amirphl/Yamata-no-Orochi/listquery"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
@@ -25,6 +26,8 @@ const (
 	TransactionTypeDebit                       TransactionType = "debit"                           // Debit from wallet
 	TransactionTypeChargeAgencyShareWithTax    TransactionType = "charge_agency_share_with_tax"    // Charge Agency share including tax
 	TransactionTypeDischargeAgencyShareWithTax TransactionType = "discharge_agency_share_with_tax" // Discharge Agency share including tax
+	TransactionTypeTransferOut                 TransactionType = "transfer_out"                    // Balance sent to another account of the same company
+	TransactionTypeTransferIn                  TransactionType = "transfer_in"                     // Balance received from another account of the same company
 )
 
 // TransactionStatus represents the current status of a transaction
@@ -43,6 +46,14 @@ const (
 	TransactionSourceIncreaseRealSystemShare        = "payment_callback_increase_system_locked_(real_system_share)"
 	TransactionSourceIncreaseTaxSystemShare         = "payment_callback_increase_tax_locked_(tax_system_share)"
 	TransactionSourceIncreaseCustomerFreePlusCredit = "payment_callback_increase_customer_free_plus_credit"
+
+	TransactionSourceCryptoIncreaseAgencyShareWithTax     = "crypto_increase_agency_share_with_tax"
+	TransactionSourceCryptoIncreaseRealSystemShare        = "crypto_increase_system_locked_(real_system_share)"
+	TransactionSourceCryptoIncreaseTaxSystemShare         = "crypto_increase_tax_locked_(tax_system_share)"
+	TransactionSourceCryptoIncreaseCustomerFreePlusCredit = "crypto_increase_customer_free_plus_credit"
+
+	// Test money a sandbox customer added to their wallet
+	TransactionSourceSandboxTopUp = "sandbox_wallet"
 )
 
 // Transaction represents an immutable financial transaction in the system
@@ -75,6 +86,9 @@ type Transaction struct {
 	Description string          `gorm:"type:text" json:"description"`
 	Metadata    json.RawMessage `gorm:"type:jsonb;default:'{}'" json:"metadata"`
 
+	// IsSandbox marks test money of a sandbox customer; set on save
+	IsSandbox bool `gorm:"not null;default:false" json:"is_sandbox"`
+
 	// Audit fields
 	CreatedAt time.Time      `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
 	UpdatedAt time.Time      `gorm:"not null;default:CURRENT_TIMESTAMP" json:"updated_at"`
@@ -136,4 +150,32 @@ type TransactionFilter struct {
 
 	// campaign filter
 	CampaignID *uint `json:"campaign_id,omitempty"`
+
+	// List is an admin filter and sort over TransactionListFields
+	List *listquery.Query `json:"-"`
+}
+
+// TransactionListFields are the fields admins can filter and sort transactions by
+var TransactionListFields = listquery.Fields{
+	"id": {Column: "transactions.id", Kind: listquery.KindInt},
+	"type": {Column: "transactions.type", Values: []string{
+		string(TransactionTypeDeposit), string(TransactionTypeWithdrawal), string(TransactionTypeFreeze),
+		string(TransactionTypeUnfreeze), string(TransactionTypeLock), string(TransactionTypeUnlock),
+		string(TransactionTypeRefund), string(TransactionTypeFee), string(TransactionTypeAdjustment),
+		string(TransactionTypeCredit), string(TransactionTypeDebit),
+		string(TransactionTypeChargeAgencyShareWithTax), string(TransactionTypeDischargeAgencyShareWithTax),
+		string(TransactionTypeTransferOut), string(TransactionTypeTransferIn),
+	}},
+	"status": {Column: "transactions.status", Values: []string{
+		string(TransactionStatusPending), string(TransactionStatusCompleted), string(TransactionStatusFailed),
+		string(TransactionStatusCancelled), string(TransactionStatusReversed),
+	}},
+	"amount":             {Column: "transactions.amount", Kind: listquery.KindInt},
+	"currency":           {Column: "transactions.currency"},
+	"wallet_id":          {Column: "transactions.wallet_id", Kind: listquery.KindInt},
+	"customer_id":        {Column: "transactions.customer_id", Kind: listquery.KindInt},
+	"external_reference": {Column: "transactions.external_reference"},
+	"is_sandbox":         {Column: "transactions.is_sandbox", Kind: listquery.KindBool},
+	"created_at":         {Column: "transactions.created_at", Kind: listquery.KindTime},
+	"updated_at":         {Column: "transactions.updated_at", Kind: listquery.KindTime},
 }
@@ -18,6 +18,9 @@ type Wallet struct {
 	// Metadata for additional wallet information
 	Metadata json.RawMessage `gorm:"type:jsonb;default:'{}'" json:"metadata"`
 
+	// Version advances with every balance snapshot; see WalletRepository.AdvanceVersion
+	Version int64 `gorm:"not null;default:0" json:"version"`
+
 	// Audit fields
 	CreatedAt time.Time      `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
 	UpdatedAt time.Time      `gorm:"not null;default:CURRENT_TIMESTAMP" json:"updated_at"`
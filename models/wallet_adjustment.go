@@ -0,0 +1,85 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WalletAdjustmentDirection is whether an adjustment adds to or takes from a wallet
+type WalletAdjustmentDirection string
+
+const (
+	WalletAdjustmentCredit WalletAdjustmentDirection = "credit"
+	WalletAdjustmentDebit  WalletAdjustmentDirection = "debit"
+)
+
+// WalletAdjustmentReason explains why finance adjusted a balance
+type WalletAdjustmentReason string
+
+const (
+	WalletAdjustmentReasonChargeback     WalletAdjustmentReason = "chargeback"      // debit only
+	WalletAdjustmentReasonGoodwillCredit WalletAdjustmentReason = "goodwill_credit" // credit only
+	WalletAdjustmentReasonRefund         WalletAdjustmentReason = "refund"          // credit only
+	WalletAdjustmentReasonCorrection     WalletAdjustmentReason = "correction"
+	WalletAdjustmentReasonOther          WalletAdjustmentReason = "other"
+)
+
+// Allows reports whether the reason may be used for an adjustment in direction d
+func (r WalletAdjustmentReason) Allows(d WalletAdjustmentDirection) bool {
+	switch r {
+	case WalletAdjustmentReasonChargeback:
+		return d == WalletAdjustmentDebit
+	case WalletAdjustmentReasonGoodwillCredit, WalletAdjustmentReasonRefund:
+		return d == WalletAdjustmentCredit
+	case WalletAdjustmentReasonCorrection, WalletAdjustmentReasonOther:
+		return d == WalletAdjustmentCredit || d == WalletAdjustmentDebit
+	}
+	return false
+}
+
+type WalletAdjustmentStatus string
+
+const (
+	WalletAdjustmentStatusPending  WalletAdjustmentStatus = "pending"
+	WalletAdjustmentStatusApproved WalletAdjustmentStatus = "approved"
+	WalletAdjustmentStatusRejected WalletAdjustmentStatus = "rejected"
+)
+
+// WalletAdjustmentRequest is a manual balance correction requested by one
+// finance admin. It only changes the wallet once a second admin approves it;
+// the balance snapshot and transaction then share its correlation ID.
+type WalletAdjustmentRequest struct {
+	ID            uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	UUID          uuid.UUID `gorm:"type:uuid;uniqueIndex;not null;default:gen_random_uuid()" json:"uuid"`
+	CorrelationID uuid.UUID `gorm:"type:uuid;index;not null" json:"correlation_id"`
+
+	CustomerID uint                      `gorm:"not null;index" json:"customer_id"`
+	Direction  WalletAdjustmentDirection `gorm:"type:varchar(10);not null" json:"direction"`
+	Amount     uint64                    `gorm:"not null" json:"amount"` // Amount in Tomans
+	ReasonCode WalletAdjustmentReason    `gorm:"type:varchar(32);not null" json:"reason_code"`
+	Note       string                    `gorm:"type:text;not null" json:"note"`
+
+	Status             WalletAdjustmentStatus `gorm:"type:varchar(20);not null;default:'pending';index" json:"status"`
+	RequestedByAdminID uint                   `gorm:"not null;index" json:"requested_by_admin_id"`
+	ReviewedByAdminID  *uint                  `gorm:"index" json:"reviewed_by_admin_id,omitempty"`
+	ReviewNote         string                 `gorm:"type:text" json:"review_note,omitempty"`
+	ReviewedAt         *time.Time             `json:"reviewed_at,omitempty"`
+	TransactionID      *uint                  `json:"transaction_id,omitempty"` // set once approved
+
+	CreatedAt time.Time `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt time.Time `gorm:"not null;default:CURRENT_TIMESTAMP" json:"updated_at"`
+}
+
+func (WalletAdjustmentRequest) TableName() string {
+	return "wallet_adjustment_requests"
+}
+
+// WalletAdjustmentRequestFilter provides query criteria for adjustment requests
+type WalletAdjustmentRequestFilter struct {
+	ID                 *uint
+	UUID               *uuid.UUID
+	CustomerID         *uint
+	RequestedByAdminID *uint
+	Status             *WalletAdjustmentStatus
+}
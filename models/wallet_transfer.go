@@ -0,0 +1,57 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type WalletTransferStatus string
+
+const (
+	WalletTransferStatusPending   WalletTransferStatus = "pending"   // waiting for the sender's OTP
+	WalletTransferStatusCompleted WalletTransferStatus = "completed" // both wallets were changed
+	WalletTransferStatusExpired   WalletTransferStatus = "expired"   // OTP expired or too many wrong codes
+)
+
+// WalletTransfer moves free balance from one customer's wallet to another
+// account of the same company. It changes neither wallet until the sender
+// confirms the OTP sent to their mobile; the balance snapshots and
+// transactions of both wallets then share its correlation ID.
+type WalletTransfer struct {
+	ID            uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	UUID          uuid.UUID `gorm:"type:uuid;uniqueIndex;not null;default:gen_random_uuid()" json:"uuid"`
+	CorrelationID uuid.UUID `gorm:"type:uuid;index;not null" json:"correlation_id"`
+
+	SenderCustomerID   uint    `gorm:"not null;index" json:"sender_customer_id"`
+	ReceiverCustomerID uint    `gorm:"not null;index" json:"receiver_customer_id"`
+	Amount             uint64  `gorm:"not null" json:"amount"` // Amount in Tomans
+	Note               *string `gorm:"type:text" json:"note,omitempty"`
+
+	Status       WalletTransferStatus `gorm:"type:varchar(20);not null;default:'pending';index" json:"status"`
+	OTPHash      string               `gorm:"column:otp_hash;type:varchar(64);not null" json:"-"`
+	OTPAttempts  int                  `gorm:"column:otp_attempts;not null;default:0" json:"otp_attempts"`
+	OTPExpiresAt time.Time            `gorm:"column:otp_expires_at;not null" json:"otp_expires_at"`
+
+	SenderTransactionID   *uint      `json:"sender_transaction_id,omitempty"`   // set once completed
+	ReceiverTransactionID *uint      `json:"receiver_transaction_id,omitempty"` // set once completed
+	CompletedAt           *time.Time `json:"completed_at,omitempty"`
+
+	CreatedAt time.Time `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt time.Time `gorm:"not null;default:CURRENT_TIMESTAMP" json:"updated_at"`
+}
+
+func (WalletTransfer) TableName() string {
+	return "wallet_transfers"
+}
+
+// WalletTransferFilter provides query criteria for wallet transfers
+type WalletTransferFilter struct {
+	ID                 *uint
+	UUID               *uuid.UUID
+	SenderCustomerID   *uint
+	ReceiverCustomerID *uint
+	// CustomerID matches transfers the customer sent or received
+	CustomerID *uint
+	Status     *WalletTransferStatus
+}
@@ -0,0 +1,79 @@
+// Package personalization resolves per-recipient variables such as
+// {first_name} in campaign content from audience profile attributes.
+package personalization
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Supported variables; each is read from the audience profile attribute with
+// the same name.
+const (
+	VariableFirstName = "first_name"
+	VariableLastName  = "last_name"
+	VariableCity      = "city"
+)
+
+// LinkPlaceholder is substituted with the campaign link by the scheduler and
+// is not a personalization variable.
+const LinkPlaceholder = "YOUR_LINK"
+
+// Variables lists the supported variables in display order
+var Variables = []string{VariableFirstName, VariableLastName, VariableCity}
+
+// placeholderPattern matches {name} and {name|fallback}
+var placeholderPattern = regexp.MustCompile(`\{([A-Za-z_][A-Za-z0-9_]*)(?:\|([^{}]*))?\}`)
+
+func isSupported(name string) bool {
+	for _, v := range Variables {
+		if v == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Parse returns the distinct supported and unknown variables used in text, in
+// order of first appearance. The link placeholder is ignored.
+func Parse(text string) (used []string, unknown []string) {
+	seen := make(map[string]struct{})
+	for _, m := range placeholderPattern.FindAllStringSubmatch(text, -1) {
+		name := m[1]
+		if name == LinkPlaceholder {
+			continue
+		}
+		if _, ok := seen[name]; ok {
+			continue
+		}
+		seen[name] = struct{}{}
+		if isSupported(name) {
+			used = append(used, name)
+		} else {
+			unknown = append(unknown, name)
+		}
+	}
+	return used, unknown
+}
+
+// HasVariables reports whether text uses any supported variable
+func HasVariables(text string) bool {
+	used, _ := Parse(text)
+	return len(used) > 0
+}
+
+// Render replaces supported variables with the recipient's attributes. A
+// missing or blank attribute renders the fallback after '|', or nothing.
+// The link placeholder and unknown variables are left untouched.
+func Render(text string, attrs map[string]string) string {
+	return placeholderPattern.ReplaceAllStringFunc(text, func(match string) string {
+		m := placeholderPattern.FindStringSubmatch(match)
+		if !isSupported(m[1]) {
+			return match
+		}
+		if value := strings.TrimSpace(attrs[m[1]]); value != "" {
+			return value
+		}
+		return strings.TrimSpace(m[2])
+	})
+}
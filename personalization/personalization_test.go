@@ -0,0 +1,62 @@
+package personalization
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		text        string
+		wantUsed    []string
+		wantUnknown []string
+	}{
+		{name: "plain text", text: "سلام"},
+		{name: "link only", text: "خرید {YOUR_LINK}"},
+		{name: "supported", text: "{first_name} عزیز از {city}", wantUsed: []string{"first_name", "city"}},
+		{name: "fallback", text: "{first_name|دوست} عزیز", wantUsed: []string{"first_name"}},
+		{name: "duplicates", text: "{city} {city} {nickname} {nickname}", wantUsed: []string{"city"}, wantUnknown: []string{"nickname"}},
+		{name: "unbalanced braces ignored", text: "{first_name {city", wantUsed: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			used, unknown := Parse(tt.text)
+			if !reflect.DeepEqual(used, tt.wantUsed) {
+				t.Fatalf("used: expected %v, got %v", tt.wantUsed, used)
+			}
+			if !reflect.DeepEqual(unknown, tt.wantUnknown) {
+				t.Fatalf("unknown: expected %v, got %v", tt.wantUnknown, unknown)
+			}
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	t.Parallel()
+
+	attrs := map[string]string{"first_name": "Sara", "city": " Tehran ", "last_name": ""}
+
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{name: "values", text: "Hi {first_name} from {city}", want: "Hi Sara from Tehran"},
+		{name: "missing without fallback", text: "Hi {last_name}!", want: "Hi !"},
+		{name: "missing with fallback", text: "Hi {last_name|friend}!", want: "Hi friend!"},
+		{name: "present ignores fallback", text: "Hi {first_name|friend}", want: "Hi Sara"},
+		{name: "link and unknown kept", text: "{nickname} {YOUR_LINK}", want: "{nickname} {YOUR_LINK}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Render(tt.text, attrs); got != tt.want {
+				t.Fatalf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
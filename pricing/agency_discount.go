@@ -0,0 +1,43 @@
+package pricing
+
+import "fmt"
+
+// DiscountTier raises an agency discount to DiscountRate once the customer's
+// wallet charges in the current month reach MinMonthlyAmountWithTax
+type DiscountTier struct {
+	MinMonthlyAmountWithTax uint64  `json:"min_monthly_amount_with_tax"`
+	DiscountRate            float64 `json:"discount_rate"`
+}
+
+// DiscountTiers is a list of tiers sorted by MinMonthlyAmountWithTax ascending
+type DiscountTiers []DiscountTier
+
+// RateFor returns the rate of the highest tier monthlyAmountWithTax reaches,
+// or base when it reaches none.
+func (t DiscountTiers) RateFor(base float64, monthlyAmountWithTax uint64) float64 {
+	rate := base
+	for _, tier := range t {
+		if tier.MinMonthlyAmountWithTax > monthlyAmountWithTax {
+			break
+		}
+		rate = tier.DiscountRate
+	}
+	return rate
+}
+
+// Validate checks that thresholds are positive and strictly increasing and
+// that rates never drop below base or the previous tier nor exceed maxRate.
+func (t DiscountTiers) Validate(base, maxRate float64) error {
+	var prevAmount uint64
+	prevRate := base
+	for i, tier := range t {
+		if tier.MinMonthlyAmountWithTax <= prevAmount {
+			return fmt.Errorf("tier %d: threshold must be greater than %d", i, prevAmount)
+		}
+		if tier.DiscountRate < prevRate || tier.DiscountRate > maxRate {
+			return fmt.Errorf("tier %d: rate must be between %g and %g", i, prevRate, maxRate)
+		}
+		prevAmount, prevRate = tier.MinMonthlyAmountWithTax, tier.DiscountRate
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package pricing
+
+import "testing"
+
+func TestDiscountTiersRateFor(t *testing.T) {
+	t.Parallel()
+
+	tiers := DiscountTiers{
+		{MinMonthlyAmountWithTax: 10_000_000, DiscountRate: 0.2},
+		{MinMonthlyAmountWithTax: 50_000_000, DiscountRate: 0.3},
+	}
+
+	tests := []struct {
+		monthly uint64
+		want    float64
+	}{
+		{monthly: 0, want: 0.1},
+		{monthly: 9_999_999, want: 0.1},
+		{monthly: 10_000_000, want: 0.2},
+		{monthly: 49_999_999, want: 0.2},
+		{monthly: 80_000_000, want: 0.3},
+	}
+	for _, tt := range tests {
+		if got := tiers.RateFor(0.1, tt.monthly); got != tt.want {
+			t.Errorf("monthly %d: expected rate %g, got %g", tt.monthly, tt.want, got)
+		}
+	}
+
+	if got := DiscountTiers(nil).RateFor(0.1, 80_000_000); got != 0.1 {
+		t.Fatalf("expected the base rate without tiers, got %g", got)
+	}
+}
+
+func TestDiscountTiersValidate(t *testing.T) {
+	t.Parallel()
+
+	valid := DiscountTiers{
+		{MinMonthlyAmountWithTax: 10_000_000, DiscountRate: 0.2},
+		{MinMonthlyAmountWithTax: 50_000_000, DiscountRate: 0.2},
+	}
+	if err := valid.Validate(0.1, 0.5); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	invalid := map[string]DiscountTiers{
+		"zero threshold":         {{MinMonthlyAmountWithTax: 0, DiscountRate: 0.2}},
+		"unsorted thresholds":    {{MinMonthlyAmountWithTax: 20, DiscountRate: 0.2}, {MinMonthlyAmountWithTax: 10, DiscountRate: 0.3}},
+		"rate below base":        {{MinMonthlyAmountWithTax: 10, DiscountRate: 0.05}},
+		"rate below previous":    {{MinMonthlyAmountWithTax: 10, DiscountRate: 0.3}, {MinMonthlyAmountWithTax: 20, DiscountRate: 0.2}},
+		"rate above the maximum": {{MinMonthlyAmountWithTax: 10, DiscountRate: 0.6}},
+	}
+	for name, tiers := range invalid {
+		if err := tiers.Validate(0.1, 0.5); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
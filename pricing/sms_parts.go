@@ -0,0 +1,88 @@
+package pricing
+
+import (
+	"strings"
+	"unicode/utf16"
+)
+
+// SMS encodings reported by CountSMSSegments
+const (
+	SMSEncodingGSM7 = "gsm7"
+	SMSEncodingUCS2 = "ucs2"
+)
+
+// SMSOptOutSuffix is appended to every campaign SMS body. Being Persian, it
+// makes every campaign message UCS-2.
+const SMSOptOutSuffix = "\n" + "لغو۱۱"
+
+const (
+	gsm7SinglePartLimit = 160
+	gsm7MultiPartLimit  = 153
+	ucs2SinglePartLimit = 70
+	ucs2MultiPartLimit  = 67
+)
+
+// gsm7Basic is the GSM 03.38 default alphabet; each character is one septet.
+const gsm7Basic = "@£$¥èéùìòÇ\nØø\rÅåΔ_ΦΓΛΩΠΨΣΘΞÆæßÉ !\"#¤%&'()*+,-./0123456789:;<=>?" +
+	"¡ABCDEFGHIJKLMNOPQRSTUVWXYZÄÖÑÜ§¿abcdefghijklmnopqrstuvwxyzäöñüà"
+
+// gsm7Extension characters are sent as an escape plus a septet.
+const gsm7Extension = "^{}\\[~]|€\f"
+
+// SMSSegments describes how a message body is split into SMS parts
+type SMSSegments struct {
+	Encoding string
+	// Units is the length in the chosen encoding: septets for GSM-7 and
+	// UTF-16 code units for UCS-2.
+	Units uint64
+	Parts uint64
+}
+
+// CountSMSSegments reports the encoding, encoded length and number of parts
+// needed to send text. A message that contains any character outside the
+// GSM-7 alphabet (e.g. Persian) is sent entirely as UCS-2.
+func CountSMSSegments(text string) SMSSegments {
+	septets := uint64(0)
+	gsm7 := true
+	for _, r := range text {
+		switch {
+		case strings.ContainsRune(gsm7Basic, r):
+			septets++
+		case strings.ContainsRune(gsm7Extension, r):
+			septets += 2
+		default:
+			gsm7 = false
+		}
+		if !gsm7 {
+			break
+		}
+	}
+
+	if gsm7 {
+		return SMSSegments{
+			Encoding: SMSEncodingGSM7,
+			Units:    septets,
+			Parts:    SMSPartsForLength(SMSEncodingGSM7, septets),
+		}
+	}
+
+	units := uint64(len(utf16.Encode([]rune(text))))
+	return SMSSegments{
+		Encoding: SMSEncodingUCS2,
+		Units:    units,
+		Parts:    SMSPartsForLength(SMSEncodingUCS2, units),
+	}
+}
+
+// SMSPartsForLength returns the number of parts needed for units characters
+// in the given encoding. An empty message still occupies one part.
+func SMSPartsForLength(encoding string, units uint64) uint64 {
+	single, multi := uint64(ucs2SinglePartLimit), uint64(ucs2MultiPartLimit)
+	if encoding == SMSEncodingGSM7 {
+		single, multi = gsm7SinglePartLimit, gsm7MultiPartLimit
+	}
+	if units <= single {
+		return 1
+	}
+	return (units + multi - 1) / multi
+}
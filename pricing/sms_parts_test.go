@@ -0,0 +1,50 @@
+package pricing
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCountSMSSegments(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		text         string
+		wantEncoding string
+		wantUnits    uint64
+		wantParts    uint64
+	}{
+		{name: "empty", text: "", wantEncoding: SMSEncodingGSM7, wantUnits: 0, wantParts: 1},
+		{name: "gsm7 single", text: strings.Repeat("a", 160), wantEncoding: SMSEncodingGSM7, wantUnits: 160, wantParts: 1},
+		{name: "gsm7 multi", text: strings.Repeat("a", 161), wantEncoding: SMSEncodingGSM7, wantUnits: 161, wantParts: 2},
+		{name: "gsm7 extension counts twice", text: strings.Repeat("€", 80) + "a", wantEncoding: SMSEncodingGSM7, wantUnits: 161, wantParts: 2},
+		{name: "persian single", text: strings.Repeat("س", 70), wantEncoding: SMSEncodingUCS2, wantUnits: 70, wantParts: 1},
+		{name: "persian multi", text: strings.Repeat("س", 135), wantEncoding: SMSEncodingUCS2, wantUnits: 135, wantParts: 3},
+		{name: "mixed switches to ucs2", text: "hello\nلغو۱۱", wantEncoding: SMSEncodingUCS2, wantUnits: 11, wantParts: 1},
+		{name: "surrogate pair counts twice", text: "😀", wantEncoding: SMSEncodingUCS2, wantUnits: 2, wantParts: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CountSMSSegments(tt.text)
+			if got.Encoding != tt.wantEncoding || got.Units != tt.wantUnits || got.Parts != tt.wantParts {
+				t.Fatalf("expected %s/%d/%d, got %s/%d/%d", tt.wantEncoding, tt.wantUnits, tt.wantParts, got.Encoding, got.Units, got.Parts)
+			}
+		})
+	}
+}
+
+func TestSMSPartsForLength(t *testing.T) {
+	t.Parallel()
+
+	if got := SMSPartsForLength(SMSEncodingUCS2, 134); got != 2 {
+		t.Fatalf("expected 2 parts, got %d", got)
+	}
+	if got := SMSPartsForLength(SMSEncodingGSM7, 306); got != 2 {
+		t.Fatalf("expected 2 parts, got %d", got)
+	}
+	if got := SMSPartsForLength(SMSEncodingGSM7, 307); got != 3 {
+		t.Fatalf("expected 3 parts, got %d", got)
+	}
+}
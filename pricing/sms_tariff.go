@@ -0,0 +1,73 @@
+package pricing
+
+// TariffRule is one row of the SMS tariff table. Empty strings and a zero
+// PartCount act as wildcards that match any value.
+type TariffRule struct {
+	ID             uint
+	Operator       string
+	LineNumberTier string
+	AccountType    string
+	PartCount      uint64
+	PricePerPart   uint64
+}
+
+// TariffCriteria describes the message being priced
+type TariffCriteria struct {
+	Operator       string
+	LineNumberTier string
+	AccountType    string
+	Parts          uint64
+}
+
+// specificity counts the non-wildcard dimensions of a rule that match c.
+// It returns -1 when the rule does not apply.
+func (r TariffRule) specificity(c TariffCriteria) int {
+	score := 0
+	for _, dim := range []struct{ rule, want string }{
+		{r.Operator, c.Operator},
+		{r.LineNumberTier, c.LineNumberTier},
+		{r.AccountType, c.AccountType},
+	} {
+		if dim.rule == "" {
+			continue
+		}
+		if dim.rule != dim.want {
+			return -1
+		}
+		score++
+	}
+	if r.PartCount != 0 {
+		if r.PartCount != c.Parts {
+			return -1
+		}
+		score++
+	}
+	return score
+}
+
+// SelectTariff returns the most specific rule matching c. Ties are broken in
+// favour of the rule that appears first, so callers should pass rules in a
+// stable order (e.g. by id).
+func SelectTariff(rules []TariffRule, c TariffCriteria) (TariffRule, bool) {
+	best := -1
+	var selected TariffRule
+	for _, r := range rules {
+		if s := r.specificity(c); s > best {
+			best = s
+			selected = r
+		}
+	}
+	return selected, best >= 0
+}
+
+// LegacySMSPartsCost is the pre-tariff SMS cost of all parts of one message:
+// the platform base price scaled by the line number factor and part count.
+func LegacySMSPartsCost(basePrice uint64, lineNumberFactor float64, parts uint64) uint64 {
+	return basePrice * uint64(lineNumberFactor*float64(parts))
+}
+
+// MessagePrice adds the audience segment surcharge to the cost of sending a
+// single message.
+func MessagePrice(sendCost uint64, segmentFactor float64, pagePrice uint64) uint64 {
+	return sendCost + uint64(segmentFactor*float64(pagePrice))
+}
@@ -0,0 +1,57 @@
+package pricing
+
+import "testing"
+
+func TestSelectTariff(t *testing.T) {
+	t.Parallel()
+
+	rules := []TariffRule{
+		{ID: 1, PricePerPart: 100},
+		{ID: 2, Operator: "mci", PricePerPart: 120},
+		{ID: 3, Operator: "mci", LineNumberTier: "premium", PricePerPart: 150},
+		{ID: 4, AccountType: "marketing_agency", PricePerPart: 90},
+		{ID: 5, Operator: "mci", LineNumberTier: "premium", PartCount: 1, PricePerPart: 170},
+	}
+
+	tests := []struct {
+		name   string
+		c      TariffCriteria
+		wantID uint
+	}{
+		{name: "falls back to catch-all", c: TariffCriteria{Operator: "mtn", Parts: 2}, wantID: 1},
+		{name: "operator match", c: TariffCriteria{Operator: "mci", Parts: 2}, wantID: 2},
+		{name: "operator and tier", c: TariffCriteria{Operator: "mci", LineNumberTier: "premium", Parts: 3}, wantID: 3},
+		{name: "most specific with part count", c: TariffCriteria{Operator: "mci", LineNumberTier: "premium", Parts: 1}, wantID: 5},
+		{name: "tie keeps first rule", c: TariffCriteria{Operator: "mci", AccountType: "marketing_agency", Parts: 2}, wantID: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := SelectTariff(rules, tt.c)
+			if !ok {
+				t.Fatal("expected a matching tariff")
+			}
+			if got.ID != tt.wantID {
+				t.Fatalf("expected tariff %d, got %d", tt.wantID, got.ID)
+			}
+		})
+	}
+
+	if _, ok := SelectTariff(rules[1:2], TariffCriteria{Operator: "mtn"}); ok {
+		t.Fatal("expected no match for a different operator")
+	}
+	if _, ok := SelectTariff(nil, TariffCriteria{}); ok {
+		t.Fatal("expected no match for an empty table")
+	}
+}
+
+func TestLegacySMSPartsCostAndMessagePrice(t *testing.T) {
+	t.Parallel()
+
+	if got := LegacySMSPartsCost(200, 1.5, 3); got != 800 {
+		t.Fatalf("expected 800, got %d", got)
+	}
+	if got := MessagePrice(800, 1.25, 40); got != 850 {
+		t.Fatalf("expected 850, got %d", got)
+	}
+}
@@ -0,0 +1,154 @@
+// Package pricing centralizes the monetary arithmetic shared by payment and campaign flows
+package pricing
+
+import (
+	"fmt"
+	"math/bits"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// basisPointsPerUnit is the number of basis points in a rate of 1.0 (100%)
+const basisPointsPerUnit = 10000
+
+// TaxRate is a VAT rate expressed in basis points (1/100 of a percent) that
+// applies to payments made at or after EffectiveFrom.
+type TaxRate struct {
+	EffectiveFrom time.Time `json:"effective_from"`
+	BasisPoints   uint64    `json:"basis_points"`
+}
+
+// DefaultTaxRate is the 10% VAT rate applied when no schedule is configured
+var DefaultTaxRate = TaxRate{BasisPoints: 1000}
+
+// Percent returns the rate as a percentage (e.g. 10 for 10%)
+func (r TaxRate) Percent() float64 {
+	return float64(r.BasisPoints) / 100
+}
+
+// TaxSchedule is a list of tax rates sorted by EffectiveFrom ascending
+type TaxSchedule []TaxRate
+
+// RateAt returns the rate in effect at t. Times before the first entry use the
+// first entry, and an empty schedule falls back to DefaultTaxRate.
+func (s TaxSchedule) RateAt(t time.Time) TaxRate {
+	if len(s) == 0 {
+		return DefaultTaxRate
+	}
+	rate := s[0]
+	for _, r := range s[1:] {
+		if r.EffectiveFrom.After(t) {
+			break
+		}
+		rate = r
+	}
+	return rate
+}
+
+// ParseTaxSchedule parses a comma-separated list of "YYYY-MM-DD:percent"
+// entries, e.g. "2023-03-21:9,2024-03-20:10". Dates are interpreted as UTC
+// midnight and percentages may have up to two decimal places.
+func ParseTaxSchedule(spec string) (TaxSchedule, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return TaxSchedule{DefaultTaxRate}, nil
+	}
+
+	schedule := make(TaxSchedule, 0)
+	seen := make(map[time.Time]struct{})
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		parts := strings.SplitN(item, ":", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid tax rate entry %q: expected YYYY-MM-DD:percent", item)
+		}
+
+		effectiveFrom, err := time.Parse(time.DateOnly, strings.TrimSpace(parts[0]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid tax rate date %q: %w", parts[0], err)
+		}
+		if _, dup := seen[effectiveFrom]; dup {
+			return nil, fmt.Errorf("duplicate tax rate date %q", parts[0])
+		}
+		seen[effectiveFrom] = struct{}{}
+
+		bps, err := parsePercentBasisPoints(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid tax rate percent %q: %w", parts[1], err)
+		}
+
+		schedule = append(schedule, TaxRate{EffectiveFrom: effectiveFrom, BasisPoints: bps})
+	}
+
+	if len(schedule) == 0 {
+		return nil, fmt.Errorf("tax rate schedule has no entries")
+	}
+
+	sort.Slice(schedule, func(i, j int) bool {
+		return schedule[i].EffectiveFrom.Before(schedule[j].EffectiveFrom)
+	})
+	return schedule, nil
+}
+
+// parsePercentBasisPoints converts a decimal percentage such as "9" or "9.25"
+// into basis points without going through floating point.
+func parsePercentBasisPoints(s string) (uint64, error) {
+	whole, frac, _ := strings.Cut(s, ".")
+	if whole == "" || len(frac) > 2 {
+		return 0, fmt.Errorf("expected a percentage with at most two decimal places")
+	}
+	for len(frac) < 2 {
+		frac += "0"
+	}
+	w, err := strconv.ParseUint(whole, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	f, err := strconv.ParseUint(frac, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	bps := w*100 + f
+	if bps > basisPointsPerUnit {
+		return 0, fmt.Errorf("percentage must be between 0 and 100")
+	}
+	return bps, nil
+}
+
+// TaxSplit is a tax-inclusive amount broken into its net and tax parts
+type TaxSplit struct {
+	Gross uint64 `json:"gross"`
+	Net   uint64 `json:"net"`
+	Tax   uint64 `json:"tax"`
+}
+
+// SplitGross splits a tax-inclusive amount into net and tax using exact integer
+// arithmetic: net = floor(gross / (1 + rate)) and tax = gross - net, so the
+// tax portion absorbs rounding and the parts always sum to gross.
+func SplitGross(gross uint64, rate TaxRate) TaxSplit {
+	hi, lo := bits.Mul64(gross, basisPointsPerUnit)
+	net, _ := bits.Div64(hi, lo, basisPointsPerUnit+rate.BasisPoints)
+	return TaxSplit{Gross: gross, Net: net, Tax: gross - net}
+}
+
+// SplitShares divides a tax-inclusive payment between the system and the
+// referring agency. The system receives half of the pre-discount value and the
+// agency keeps the rest.
+func SplitShares(amountWithTax uint64, discountRate float64) (systemShareWithTax, agencyShareWithTax uint64) {
+	x := float64(amountWithTax) / (1 - discountRate)
+	systemShareWithTax = uint64(x / 2)
+	agencyShareWithTax = amountWithTax - systemShareWithTax
+	return systemShareWithTax, agencyShareWithTax
+}
+
+// CustomerCredit returns the extra credit a customer receives on top of a net
+// payment when their agency grants discountRate.
+func CustomerCredit(net uint64, discountRate float64) uint64 {
+	return uint64(float64(net)/(1-discountRate)) - net
+}
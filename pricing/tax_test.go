@@ -0,0 +1,140 @@
+package pricing
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSplitGrossMatchesLegacyTenElevenths(t *testing.T) {
+	t.Parallel()
+
+	for _, gross := range []uint64{0, 1, 10, 11, 22, 999, 1000, 110000, 1234567, 99999999999} {
+		got := SplitGross(gross, DefaultTaxRate)
+		wantNet := gross * 10 / 11
+		if got.Net != wantNet {
+			t.Fatalf("gross %d: expected net %d, got %d", gross, wantNet, got.Net)
+		}
+		if got.Net+got.Tax != gross {
+			t.Fatalf("gross %d: net %d + tax %d does not sum to gross", gross, got.Net, got.Tax)
+		}
+	}
+}
+
+func TestSplitGross(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		gross   uint64
+		bps     uint64
+		wantNet uint64
+		wantTax uint64
+	}{
+		{name: "zero rate", gross: 5000, bps: 0, wantNet: 5000, wantTax: 0},
+		{name: "nine percent", gross: 109000, bps: 900, wantNet: 100000, wantTax: 9000},
+		{name: "fractional rate", gross: 100000, bps: 925, wantNet: 91533, wantTax: 8467},
+		{name: "large amount does not overflow", gross: 1 << 62, bps: 1000, wantNet: 4192441834933989003, wantTax: 419244183493398901},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got := SplitGross(tt.gross, TaxRate{BasisPoints: tt.bps})
+			if got.Net != tt.wantNet || got.Tax != tt.wantTax {
+				t.Fatalf("expected net=%d tax=%d, got net=%d tax=%d", tt.wantNet, tt.wantTax, got.Net, got.Tax)
+			}
+		})
+	}
+}
+
+func TestParseTaxSchedule(t *testing.T) {
+	t.Parallel()
+
+	t.Run("empty uses default", func(t *testing.T) {
+		s, err := ParseTaxSchedule("  ")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(s) != 1 || s[0].BasisPoints != DefaultTaxRate.BasisPoints {
+			t.Fatalf("expected default schedule, got %+v", s)
+		}
+	})
+
+	t.Run("sorted and decimal", func(t *testing.T) {
+		s, err := ParseTaxSchedule("2024-03-20:10, 2023-03-21:9.5")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(s) != 2 {
+			t.Fatalf("expected 2 entries, got %d", len(s))
+		}
+		if s[0].BasisPoints != 950 || s[1].BasisPoints != 1000 {
+			t.Fatalf("unexpected rates: %+v", s)
+		}
+	})
+
+	invalid := []string{
+		"2024-03-20",
+		"20-03-2024:10",
+		"2024-03-20:abc",
+		"2024-03-20:10.125",
+		"2024-03-20:101",
+		"2024-03-20:10,2024-03-20:9",
+		",",
+	}
+	for _, spec := range invalid {
+		if _, err := ParseTaxSchedule(spec); err == nil {
+			t.Fatalf("expected error for %q", spec)
+		}
+	}
+}
+
+func TestTaxScheduleRateAt(t *testing.T) {
+	t.Parallel()
+
+	s, err := ParseTaxSchedule("2023-03-21:9,2024-03-20:10")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		at   time.Time
+		want uint64
+	}{
+		{at: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC), want: 900},
+		{at: time.Date(2023, 3, 21, 0, 0, 0, 0, time.UTC), want: 900},
+		{at: time.Date(2024, 3, 19, 23, 59, 59, 0, time.UTC), want: 900},
+		{at: time.Date(2024, 3, 20, 0, 0, 0, 0, time.UTC), want: 1000},
+		{at: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC), want: 1000},
+	}
+	for _, tt := range tests {
+		if got := s.RateAt(tt.at).BasisPoints; got != tt.want {
+			t.Fatalf("at %s: expected %d bps, got %d", tt.at, tt.want, got)
+		}
+	}
+
+	if got := TaxSchedule(nil).RateAt(time.Now()); got != DefaultTaxRate {
+		t.Fatalf("expected default rate for empty schedule, got %+v", got)
+	}
+}
+
+func TestSplitSharesAndCustomerCredit(t *testing.T) {
+	t.Parallel()
+
+	system, agency := SplitShares(1000000, 0.5)
+	if system != 1000000 || agency != 0 {
+		t.Fatalf("expected 1000000/0, got %d/%d", system, agency)
+	}
+
+	system, agency = SplitShares(900000, 0.1)
+	if system != 500000 || agency != 400000 {
+		t.Fatalf("expected 500000/400000, got %d/%d", system, agency)
+	}
+
+	if got := CustomerCredit(900000, 0.1); got != 100000 {
+		t.Fatalf("expected credit 100000, got %d", got)
+	}
+	if got := CustomerCredit(900000, 0); got != 0 {
+		t.Fatalf("expected zero credit without discount, got %d", got)
+	}
+}
@@ -0,0 +1,122 @@
+// Package recurrence expands campaign recurrence rules into occurrence times.
+// All calculations are done in UTC.
+package recurrence
+
+import (
+	"errors"
+	"time"
+)
+
+// Supported recurrence frequencies
+const (
+	FrequencyDaily  = "daily"
+	FrequencyWeekly = "weekly"
+	FrequencyCron   = "cron"
+)
+
+var (
+	ErrFrequencyInvalid = errors.New("recurrence frequency must be daily, weekly or cron")
+	ErrWeekdayInvalid   = errors.New("recurrence weekday must be between 0 (Sunday) and 6 (Saturday)")
+	ErrCronRequired     = errors.New("cron expression is required for cron recurrence")
+)
+
+const day = 24 * time.Hour
+
+// Rule describes how a series repeats after its first occurrence.
+type Rule struct {
+	Frequency string
+	// Interval repeats every N days (daily) or weeks (weekly); zero means 1
+	Interval uint
+	// Weekdays restricts weekly rules to these days; empty means the weekday
+	// of the first occurrence
+	Weekdays []time.Weekday
+	Cron     string
+}
+
+// Validate checks that the rule can produce occurrences
+func (r Rule) Validate() error {
+	switch r.Frequency {
+	case FrequencyDaily:
+		return nil
+	case FrequencyWeekly:
+		for _, wd := range r.Weekdays {
+			if wd < time.Sunday || wd > time.Saturday {
+				return ErrWeekdayInvalid
+			}
+		}
+		return nil
+	case FrequencyCron:
+		if r.Cron == "" {
+			return ErrCronRequired
+		}
+		_, err := ParseCron(r.Cron)
+		return err
+	default:
+		return ErrFrequencyInvalid
+	}
+}
+
+// Next returns the first occurrence strictly after `after` of a series whose
+// first occurrence is anchor. Daily and weekly occurrences keep the anchor's
+// time of day; cron occurrences follow the expression.
+func (r Rule) Next(anchor, after time.Time) (time.Time, error) {
+	anchor = anchor.UTC()
+	after = after.UTC()
+	if after.Before(anchor) {
+		return anchor, nil
+	}
+
+	switch r.Frequency {
+	case FrequencyDaily:
+		step := time.Duration(r.interval()) * day
+		k := after.Sub(anchor)/step + 1
+		return anchor.Add(k * step), nil
+	case FrequencyWeekly:
+		return r.nextWeekly(anchor, after), nil
+	case FrequencyCron:
+		s, err := ParseCron(r.Cron)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return s.Next(after)
+	default:
+		return time.Time{}, ErrFrequencyInvalid
+	}
+}
+
+func (r Rule) interval() uint {
+	if r.Interval == 0 {
+		return 1
+	}
+	return r.Interval
+}
+
+func (r Rule) nextWeekly(anchor, after time.Time) time.Time {
+	weekdays := make(map[time.Weekday]bool, len(r.Weekdays))
+	for _, wd := range r.Weekdays {
+		weekdays[wd] = true
+	}
+	if len(weekdays) == 0 {
+		weekdays[anchor.Weekday()] = true
+	}
+
+	anchorDay := anchor.Truncate(day)
+	timeOfDay := anchor.Sub(anchorDay)
+	weekStart := anchorDay.Add(-time.Duration(anchor.Weekday()) * day)
+	interval := int64(r.interval())
+
+	// Scanning one full cycle of weeks past `after` always finds a match.
+	start := after.Truncate(day)
+	for i := range 7*interval + 7 {
+		candidateDay := start.Add(time.Duration(i) * day)
+		candidate := candidateDay.Add(timeOfDay)
+		if !candidate.After(after) || !weekdays[candidate.Weekday()] {
+			continue
+		}
+		week := int64(candidateDay.Sub(weekStart) / (7 * day))
+		if week%interval == 0 {
+			return candidate
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,142 @@
+package recurrence
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func mustTime(t *testing.T, value string) time.Time {
+	t.Helper()
+	ts, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		t.Fatalf("parse %q: %v", value, err)
+	}
+	return ts
+}
+
+func TestRuleNext(t *testing.T) {
+	t.Parallel()
+
+	// 2026-03-04 is a Wednesday
+	anchor := "2026-03-04T09:30:00Z"
+
+	tests := []struct {
+		name  string
+		rule  Rule
+		after string
+		want  string
+	}{
+		{name: "before anchor returns anchor", rule: Rule{Frequency: FrequencyDaily}, after: "2026-03-01T00:00:00Z", want: anchor},
+		{name: "daily at anchor", rule: Rule{Frequency: FrequencyDaily}, after: anchor, want: "2026-03-05T09:30:00Z"},
+		{name: "daily mid day", rule: Rule{Frequency: FrequencyDaily}, after: "2026-03-06T12:00:00Z", want: "2026-03-07T09:30:00Z"},
+		{name: "every third day", rule: Rule{Frequency: FrequencyDaily, Interval: 3}, after: "2026-03-05T00:00:00Z", want: "2026-03-07T09:30:00Z"},
+		{name: "weekly defaults to anchor weekday", rule: Rule{Frequency: FrequencyWeekly}, after: anchor, want: "2026-03-11T09:30:00Z"},
+		{
+			name:  "weekly on several days",
+			rule:  Rule{Frequency: FrequencyWeekly, Weekdays: []time.Weekday{time.Monday, time.Friday}},
+			after: anchor,
+			want:  "2026-03-06T09:30:00Z",
+		},
+		{
+			name:  "biweekly skips odd weeks",
+			rule:  Rule{Frequency: FrequencyWeekly, Interval: 2, Weekdays: []time.Weekday{time.Monday}},
+			after: anchor,
+			want:  "2026-03-16T09:30:00Z",
+		},
+		{name: "cron", rule: Rule{Frequency: FrequencyCron, Cron: "0 8 * * 1"}, after: anchor, want: "2026-03-09T08:00:00Z"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.rule.Next(mustTime(t, anchor), mustTime(t, tt.after))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if want := mustTime(t, tt.want); !got.Equal(want) {
+				t.Fatalf("expected %s, got %s", want, got)
+			}
+		})
+	}
+}
+
+func TestRuleValidate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		rule Rule
+		want error
+	}{
+		{name: "daily", rule: Rule{Frequency: FrequencyDaily}},
+		{name: "unknown frequency", rule: Rule{Frequency: "hourly"}, want: ErrFrequencyInvalid},
+		{name: "bad weekday", rule: Rule{Frequency: FrequencyWeekly, Weekdays: []time.Weekday{7}}, want: ErrWeekdayInvalid},
+		{name: "cron missing", rule: Rule{Frequency: FrequencyCron}, want: ErrCronRequired},
+		{name: "cron invalid", rule: Rule{Frequency: FrequencyCron, Cron: "61 * * * *"}, want: ErrCronInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.rule.Validate()
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestCronScheduleNext(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		expr  string
+		after string
+		want  string
+	}{
+		{name: "every 15 minutes", expr: "*/15 * * * *", after: "2026-03-04T09:31:00Z", want: "2026-03-04T09:45:00Z"},
+		{name: "strictly after", expr: "30 9 * * *", after: "2026-03-04T09:30:00Z", want: "2026-03-05T09:30:00Z"},
+		{name: "month rollover", expr: "0 0 1 * *", after: "2026-03-04T09:30:00Z", want: "2026-04-01T00:00:00Z"},
+		{name: "sunday as seven", expr: "0 10 * * 7", after: "2026-03-04T09:30:00Z", want: "2026-03-08T10:00:00Z"},
+		{name: "weekday range", expr: "0 9 * * 1-5", after: "2026-03-06T10:00:00Z", want: "2026-03-09T09:00:00Z"},
+		{name: "day of month or weekday", expr: "0 12 15 * 0", after: "2026-03-04T09:30:00Z", want: "2026-03-08T12:00:00Z"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := ParseCron(tt.expr)
+			if err != nil {
+				t.Fatalf("parse %q: %v", tt.expr, err)
+			}
+			got, err := s.Next(mustTime(t, tt.after))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if want := mustTime(t, tt.want); !got.Equal(want) {
+				t.Fatalf("expected %s, got %s", want, got)
+			}
+		})
+	}
+}
+
+func TestCronScheduleNoMatch(t *testing.T) {
+	t.Parallel()
+
+	s, err := ParseCron("0 0 30 2 *")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if _, err := s.Next(mustTime(t, "2026-03-04T09:30:00Z")); !errors.Is(err, ErrCronNoMatch) {
+		t.Fatalf("expected ErrCronNoMatch, got %v", err)
+	}
+}
+
+func TestParseCronRejectsMalformed(t *testing.T) {
+	t.Parallel()
+
+	for _, expr := range []string{"", "* * * *", "a * * * *", "*/0 * * * *", "5-1 * * * *", "* 24 * * *"} {
+		if _, err := ParseCron(expr); !errors.Is(err, ErrCronInvalid) {
+			t.Fatalf("expected ErrCronInvalid for %q, got %v", expr, err)
+		}
+	}
+}
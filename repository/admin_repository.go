@@ -4,6 +4,7 @@ package repository
 import (
 	"context"
 	"errors"
+	"fmt"
 
 	"github.com/amirphl/Yamata-no-Orochi/models"
 	"github.com/amirphl/Yamata-no-Orochi/utils"
@@ -73,6 +74,25 @@ func (r *AdminRepositoryImpl) ByUsername(ctx context.Context, username string) (
 	return admins[0], nil
 }
 
+// EnableTOTP stores the confirmed TOTP secret of an admin
+func (r *AdminRepositoryImpl) EnableTOTP(ctx context.Context, adminID uint, secret string) error {
+	now := utils.UTCNow()
+	res := r.getDB(ctx).Model(&models.Admin{}).
+		Where("id = ?", adminID).
+		Updates(map[string]any{
+			"totp_secret":     secret,
+			"totp_enabled_at": now,
+			"updated_at":      now,
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("admin not found with ID: %d", adminID)
+	}
+	return nil
+}
+
 // applyFilter applies filter criteria to a GORM query
 func (r *AdminRepositoryImpl) applyFilter(query *gorm.DB, filter models.AdminFilter) *gorm.DB {
 	if filter.ID != nil {
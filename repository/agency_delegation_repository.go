@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+// AgencyDelegationRepositoryImpl implements AgencyDelegationRepository
+type AgencyDelegationRepositoryImpl struct {
+	*BaseRepository[models.AgencyDelegation, models.AgencyDelegationFilter]
+}
+
+// NewAgencyDelegationRepository creates a new agency delegation repository
+func NewAgencyDelegationRepository(db *gorm.DB) AgencyDelegationRepository {
+	return &AgencyDelegationRepositoryImpl{
+		BaseRepository: NewBaseRepository[models.AgencyDelegation, models.AgencyDelegationFilter](db),
+	}
+}
+
+// AgencyDelegationWithCustomer is a projection row joining a grant and the
+// customer who gave it
+type AgencyDelegationWithCustomer struct {
+	DelegationUUID          string         `gorm:"column:delegation_uuid"`
+	CustomerID              uint           `gorm:"column:customer_id"`
+	CustomerUUID            string         `gorm:"column:customer_uuid"`
+	RepresentativeFirstName string         `gorm:"column:representative_first_name"`
+	RepresentativeLastName  string         `gorm:"column:representative_last_name"`
+	CompanyName             *string        `gorm:"column:company_name"`
+	Permissions             pq.StringArray `gorm:"column:permissions"`
+	CreatedAt               time.Time      `gorm:"column:created_at"`
+}
+
+// ActiveByCustomer returns the customer's active grant, or nil
+func (r *AgencyDelegationRepositoryImpl) ActiveByCustomer(ctx context.Context, customerID uint) (*models.AgencyDelegation, error) {
+	var d models.AgencyDelegation
+	err := r.getDB(ctx).
+		Where("customer_id = ? AND revoked_at IS NULL", customerID).
+		Last(&d).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &d, nil
+}
+
+// ListActiveWithCustomer returns the active grants an agency holds, newest
+// first, with the customers who gave them
+func (r *AgencyDelegationRepositoryImpl) ListActiveWithCustomer(ctx context.Context, agencyID uint) ([]*AgencyDelegationWithCustomer, error) {
+	var rows []*AgencyDelegationWithCustomer
+	err := r.getDB(ctx).Table("agency_delegations AS d").
+		Select(`d.uuid::text AS delegation_uuid,
+			d.customer_id AS customer_id,
+			c.uuid::text AS customer_uuid,
+			c.representative_first_name AS representative_first_name,
+			c.representative_last_name AS representative_last_name,
+			c.company_name AS company_name,
+			d.permissions AS permissions,
+			d.created_at AS created_at`).
+		Joins("JOIN customers c ON c.id = d.customer_id").
+		Where("d.agency_id = ? AND d.revoked_at IS NULL", agencyID).
+		Order("d.created_at DESC").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// Update persists the grant's permissions and revocation
+func (r *AgencyDelegationRepositoryImpl) Update(ctx context.Context, d *models.AgencyDelegation) error {
+	d.UpdatedAt = utils.UTCNow()
+	return r.getDB(ctx).Save(d).Error
+}
+
+// ByFilter returns grants matching the filter
+func (r *AgencyDelegationRepositoryImpl) ByFilter(ctx context.Context, filter models.AgencyDelegationFilter, orderBy string, limit, offset int) ([]*models.AgencyDelegation, error) {
+	db := r.applyFilter(r.getDB(ctx).Model(&models.AgencyDelegation{}), filter)
+	if orderBy == "" {
+		orderBy = "id DESC"
+	}
+	db = db.Order(orderBy)
+	if limit > 0 {
+		db = db.Limit(limit)
+	}
+	if offset > 0 {
+		db = db.Offset(offset)
+	}
+
+	var rows []*models.AgencyDelegation
+	if err := db.Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// Count returns the number of grants matching the filter
+func (r *AgencyDelegationRepositoryImpl) Count(ctx context.Context, filter models.AgencyDelegationFilter) (int64, error) {
+	var count int64
+	if err := r.applyFilter(r.getDB(ctx).Model(&models.AgencyDelegation{}), filter).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// Exists checks whether any grant matches the filter
+func (r *AgencyDelegationRepositoryImpl) Exists(ctx context.Context, filter models.AgencyDelegationFilter) (bool, error) {
+	count, err := r.Count(ctx, filter)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *AgencyDelegationRepositoryImpl) applyFilter(query *gorm.DB, filter models.AgencyDelegationFilter) *gorm.DB {
+	if filter.ID != nil {
+		query = query.Where("id = ?", *filter.ID)
+	}
+	if filter.UUID != nil {
+		query = query.Where("uuid = ?", *filter.UUID)
+	}
+	if filter.AgencyID != nil {
+		query = query.Where("agency_id = ?", *filter.AgencyID)
+	}
+	if filter.CustomerID != nil {
+		query = query.Where("customer_id = ?", *filter.CustomerID)
+	}
+	if filter.IsActive != nil {
+		if *filter.IsActive {
+			query = query.Where("revoked_at IS NULL")
+		} else {
+			query = query.Where("revoked_at IS NOT NULL")
+		}
+	}
+	return query
+}
@@ -70,11 +70,14 @@ func (r *AgencyDiscountRepositoryImpl) ByAgencyAndCustomer(ctx context.Context,
 	return r.ByFilter(ctx, filter, "id DESC", 0, 0)
 }
 
+// GetActiveDiscount returns the discount in effect now, or nil. Discounts
+// scheduled to start later are not active yet.
 func (r *AgencyDiscountRepositoryImpl) GetActiveDiscount(ctx context.Context, agencyID, customerID uint) (*models.AgencyDiscount, error) {
 	db := r.getDB(ctx)
+	now := utils.UTCNow()
 	var ad models.AgencyDiscount
-	if err := db.Where("agency_id = ? AND customer_id = ? AND (expires_at IS NULL OR expires_at > ?)", agencyID, customerID, utils.UTCNow()).
-		Order("id DESC").
+	if err := db.Where("agency_id = ? AND customer_id = ? AND effective_from <= ? AND (expires_at IS NULL OR expires_at > ?)", agencyID, customerID, now, now).
+		Order("effective_from DESC").
 		Last(&ad).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil
@@ -84,7 +87,10 @@ func (r *AgencyDiscountRepositoryImpl) GetActiveDiscount(ctx context.Context, ag
 	return &ad, nil
 }
 
-// ExpireActiveByAgencyAndCustomer sets expires_at for all currently non-expired discounts of an agency for a customer
+// ExpireActiveByAgencyAndCustomer ends the discounts of an agency for a
+// customer that would apply at or after expiredAt: the one in effect then
+// expires at expiredAt and those scheduled later expire at their
+// effective_from, so they never apply but stay in the history
 func (r *AgencyDiscountRepositoryImpl) ExpireActiveByAgencyAndCustomer(ctx context.Context, agencyID, customerID uint, expiredAt time.Time) error {
 	db, shouldCommit, err := r.getDBForWrite(ctx)
 	if err != nil {
@@ -100,19 +106,23 @@ func (r *AgencyDiscountRepositoryImpl) ExpireActiveByAgencyAndCustomer(ctx conte
 		}()
 	}
 
-	res := db.Model(&models.AgencyDiscount{}).
-		Where("agency_id = ? AND customer_id = ? AND (expires_at IS NULL OR expires_at > ?)", agencyID, customerID, utils.UTCNow()).
-		Update("expires_at", expiredAt)
-	if res.Error != nil {
-		return res.Error
+	err = db.Model(&models.AgencyDiscount{}).
+		Where("agency_id = ? AND customer_id = ? AND effective_from < ? AND (expires_at IS NULL OR expires_at > ?)", agencyID, customerID, expiredAt, expiredAt).
+		Update("expires_at", expiredAt).Error
+	if err != nil {
+		return err
 	}
-	return nil
+	err = db.Model(&models.AgencyDiscount{}).
+		Where("agency_id = ? AND customer_id = ? AND effective_from >= ? AND (expires_at IS NULL OR expires_at > effective_from)", agencyID, customerID, expiredAt).
+		Update("expires_at", gorm.Expr("effective_from")).Error
+	return err
 }
 
-// ListActiveDiscountsWithCustomer returns active (non-expired) discounts of an agency joined with customer info
+// ListActiveDiscountsWithCustomer returns discounts of an agency in effect now of an agency joined with customer info
 // Optional nameLike filters by representative first+last name using ILIKE
 func (r *AgencyDiscountRepositoryImpl) ListActiveDiscountsWithCustomer(ctx context.Context, agencyID uint, nameLike, orderBy string) ([]*AgencyDiscountWithCustomer, error) {
 	db := r.getDB(ctx)
+	now := utils.UTCNow()
 
 	// Sanitize orderBy to a small whitelist to avoid SQL injection
 	allowed := map[string]string{
@@ -141,7 +151,7 @@ func (r *AgencyDiscountRepositoryImpl) ListActiveDiscountsWithCustomer(ctx conte
 			ad.created_at AS created_at,
 			ad.expires_at AS expires_at`).
 		Joins("JOIN customers c ON c.id = ad.customer_id").
-		Where("ad.agency_id = ? AND (ad.expires_at IS NULL OR ad.expires_at > ?)", agencyID, utils.UTCNow())
+		Where("ad.agency_id = ? AND ad.effective_from <= ? AND (ad.expires_at IS NULL OR ad.expires_at > ?)", agencyID, now, now)
 
 	if trimmed := strings.TrimSpace(nameLike); trimmed != "" {
 		pattern := "%" + strings.ToLower(trimmed) + "%"
@@ -182,7 +192,8 @@ func (r *AgencyDiscountRepositoryImpl) ByFilter(ctx context.Context, filter mode
 		query = query.Where("discount_rate = ?", *filter.DiscountRate)
 	}
 	if filter.IsActive != nil && *filter.IsActive {
-		query = query.Where("expires_at IS NULL OR expires_at > ?", utils.UTCNow())
+		now := utils.UTCNow()
+		query = query.Where("effective_from <= ? AND (expires_at IS NULL OR expires_at > ?)", now, now)
 	}
 	if filter.ExpiresAfter != nil {
 		query = query.Where("expires_at >= ?", *filter.ExpiresAfter)
@@ -236,7 +247,8 @@ func (r *AgencyDiscountRepositoryImpl) Count(ctx context.Context, filter models.
 		query = query.Where("discount_rate = ?", *filter.DiscountRate)
 	}
 	if filter.IsActive != nil && *filter.IsActive {
-		query = query.Where("expires_at IS NULL OR expires_at > ?", utils.UTCNow())
+		now := utils.UTCNow()
+		query = query.Where("effective_from <= ? AND (expires_at IS NULL OR expires_at > ?)", now, now)
 	}
 	if filter.ExpiresAfter != nil {
 		query = query.Where("expires_at >= ?", *filter.ExpiresAfter)
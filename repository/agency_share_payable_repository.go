@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AgencySharePayableRepositoryImpl implements AgencySharePayableRepository
+type AgencySharePayableRepositoryImpl struct {
+	*BaseRepository[models.AgencySharePayable, models.AgencySharePayableFilter]
+}
+
+// NewAgencySharePayableRepository creates a new agency share payable repository
+func NewAgencySharePayableRepository(db *gorm.DB) AgencySharePayableRepository {
+	return &AgencySharePayableRepositoryImpl{
+		BaseRepository: NewBaseRepository[models.AgencySharePayable, models.AgencySharePayableFilter](db),
+	}
+}
+
+// ByUUID retrieves a payable by UUID
+func (r *AgencySharePayableRepositoryImpl) ByUUID(ctx context.Context, id uuid.UUID) (*models.AgencySharePayable, error) {
+	var p models.AgencySharePayable
+	if err := r.getDB(ctx).Where("uuid = ?", id).Last(&p).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &p, nil
+}
+
+// MarkPaid records the payout of a payable that is still pending, and
+// reports false when it was already paid
+func (r *AgencySharePayableRepositoryImpl) MarkPaid(ctx context.Context, p *models.AgencySharePayable) (bool, error) {
+	res := r.getDB(ctx).Model(&models.AgencySharePayable{}).
+		Where("id = ? AND status = ?", p.ID, models.AgencySharePayablePending).
+		Updates(map[string]any{
+			"status":            models.AgencySharePayablePaid,
+			"paid_at":           p.PaidAt,
+			"paid_by_admin_id":  p.PaidByAdminID,
+			"payment_reference": p.PaymentReference,
+			"updated_at":        p.UpdatedAt,
+		})
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
+
+// SumAmount totals the amount of the payables matching the filter
+func (r *AgencySharePayableRepositoryImpl) SumAmount(ctx context.Context, filter models.AgencySharePayableFilter) (uint64, error) {
+	var sum uint64
+	err := r.applyFilter(r.getDB(ctx).Model(&models.AgencySharePayable{}), filter).
+		Select("COALESCE(SUM(amount_with_tax), 0)").
+		Scan(&sum).Error
+	return sum, err
+}
+
+// ByFilter returns payables matching the filter
+func (r *AgencySharePayableRepositoryImpl) ByFilter(ctx context.Context, filter models.AgencySharePayableFilter, orderBy string, limit, offset int) ([]*models.AgencySharePayable, error) {
+	db := r.applyFilter(r.getDB(ctx).Model(&models.AgencySharePayable{}), filter)
+	if orderBy == "" {
+		orderBy = "id DESC"
+	}
+	db = db.Order(orderBy)
+	if limit > 0 {
+		db = db.Limit(limit)
+	}
+	if offset > 0 {
+		db = db.Offset(offset)
+	}
+
+	var items []*models.AgencySharePayable
+	if err := db.Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// Count returns the number of payables matching the filter
+func (r *AgencySharePayableRepositoryImpl) Count(ctx context.Context, filter models.AgencySharePayableFilter) (int64, error) {
+	var count int64
+	if err := r.applyFilter(r.getDB(ctx).Model(&models.AgencySharePayable{}), filter).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// Exists checks whether any payable matches the filter
+func (r *AgencySharePayableRepositoryImpl) Exists(ctx context.Context, filter models.AgencySharePayableFilter) (bool, error) {
+	count, err := r.Count(ctx, filter)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *AgencySharePayableRepositoryImpl) applyFilter(query *gorm.DB, filter models.AgencySharePayableFilter) *gorm.DB {
+	if filter.ID != nil {
+		query = query.Where("id = ?", *filter.ID)
+	}
+	if filter.UUID != nil {
+		query = query.Where("uuid = ?", *filter.UUID)
+	}
+	if filter.AgencyID != nil {
+		query = query.Where("agency_id = ?", *filter.AgencyID)
+	}
+	if filter.PaymentRequestID != nil {
+		query = query.Where("payment_request_id = ?", *filter.PaymentRequestID)
+	}
+	if filter.Status != nil {
+		query = query.Where("status = ?", *filter.Status)
+	}
+	return query
+}
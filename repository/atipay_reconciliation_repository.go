@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"gorm.io/gorm"
+)
+
+// AtipayReconciliationRepositoryImpl implements AtipayReconciliationRepository
+type AtipayReconciliationRepositoryImpl struct {
+	*BaseRepository[models.AtipayReconciliationEntry, models.AtipayReconciliationEntryFilter]
+}
+
+// NewAtipayReconciliationRepository creates a new Atipay reconciliation repository
+func NewAtipayReconciliationRepository(db *gorm.DB) AtipayReconciliationRepository {
+	return &AtipayReconciliationRepositoryImpl{
+		BaseRepository: NewBaseRepository[models.AtipayReconciliationEntry, models.AtipayReconciliationEntryFilter](db),
+	}
+}
+
+// ReplaceForDate deletes the entries of a report date and inserts the given
+// ones. Run it in a transaction so a failed insert keeps the old entries.
+func (r *AtipayReconciliationRepositoryImpl) ReplaceForDate(ctx context.Context, reportDate string, entries []*models.AtipayReconciliationEntry) error {
+	db := r.getDB(ctx)
+	if err := db.Where("report_date = ?", reportDate).Delete(&models.AtipayReconciliationEntry{}).Error; err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+	return db.CreateInBatches(entries, 1000).Error
+}
+
+// CountByStatus returns the number of entries of a report date by status
+func (r *AtipayReconciliationRepositoryImpl) CountByStatus(ctx context.Context, reportDate string) (map[models.AtipayReconciliationStatus]int64, error) {
+	var rows []struct {
+		Status models.AtipayReconciliationStatus
+		Count  int64
+	}
+	err := r.getDB(ctx).Model(&models.AtipayReconciliationEntry{}).
+		Select("status, COUNT(*) AS count").
+		Where("report_date = ?", reportDate).
+		Group("status").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[models.AtipayReconciliationStatus]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+	return counts, nil
+}
+
+// ByFilter returns reconciliation entries matching the filter
+func (r *AtipayReconciliationRepositoryImpl) ByFilter(ctx context.Context, filter models.AtipayReconciliationEntryFilter, orderBy string, limit, offset int) ([]*models.AtipayReconciliationEntry, error) {
+	db := r.applyFilter(r.getDB(ctx).Model(&models.AtipayReconciliationEntry{}), filter)
+	if orderBy == "" {
+		orderBy = "id ASC"
+	}
+	db = db.Order(orderBy)
+	if limit > 0 {
+		db = db.Limit(limit)
+	}
+	if offset > 0 {
+		db = db.Offset(offset)
+	}
+
+	var entries []*models.AtipayReconciliationEntry
+	if err := db.Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// Count returns the number of reconciliation entries matching the filter
+func (r *AtipayReconciliationRepositoryImpl) Count(ctx context.Context, filter models.AtipayReconciliationEntryFilter) (int64, error) {
+	var count int64
+	if err := r.applyFilter(r.getDB(ctx).Model(&models.AtipayReconciliationEntry{}), filter).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// Exists checks whether any reconciliation entry matches the filter
+func (r *AtipayReconciliationRepositoryImpl) Exists(ctx context.Context, filter models.AtipayReconciliationEntryFilter) (bool, error) {
+	count, err := r.Count(ctx, filter)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *AtipayReconciliationRepositoryImpl) applyFilter(query *gorm.DB, filter models.AtipayReconciliationEntryFilter) *gorm.DB {
+	if filter.ID != nil {
+		query = query.Where("id = ?", *filter.ID)
+	}
+	if filter.ReportDate != nil {
+		query = query.Where("report_date = ?", filter.ReportDate.Format("2006-01-02"))
+	}
+	if filter.Status != nil {
+		query = query.Where("status = ?", *filter.Status)
+	}
+	if filter.Discrepancies != nil {
+		if *filter.Discrepancies {
+			query = query.Where("status <> ?", models.AtipayReconciliationMatched)
+		} else {
+			query = query.Where("status = ?", models.AtipayReconciliationMatched)
+		}
+	}
+	if filter.PaymentRequestID != nil {
+		query = query.Where("payment_request_id = ?", *filter.PaymentRequestID)
+	}
+	if filter.ReferenceNumber != nil {
+		query = query.Where("reference_number = ?", *filter.ReferenceNumber)
+	}
+	return query
+}
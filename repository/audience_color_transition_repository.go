@@ -0,0 +1,193 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"gorm.io/gorm"
+)
+
+// lastColorTransitionSQL is the time of a profile's last color transition,
+// or -infinity when it never had one
+const lastColorTransitionSQL = `COALESCE((SELECT MAX(t.created_at) FROM audience_color_transitions t
+	WHERE t.audience_profile_id = ap.id), '-infinity')`
+
+// AudienceColorTransitionRepositoryImpl implements AudienceColorTransitionRepository
+type AudienceColorTransitionRepositoryImpl struct {
+	*BaseRepository[models.AudienceColorTransition, models.AudienceColorTransitionFilter]
+}
+
+// NewAudienceColorTransitionRepository creates a new audience color transition repository
+func NewAudienceColorTransitionRepository(db *gorm.DB) AudienceColorTransitionRepository {
+	return &AudienceColorTransitionRepositoryImpl{
+		BaseRepository: NewBaseRepository[models.AudienceColorTransition, models.AudienceColorTransitionFilter](db),
+	}
+}
+
+// FailureDemotionCandidates returns white profiles with at least minFailures
+// undelivered SMS and no delivered one since since and their last transition
+func (r *AudienceColorTransitionRepositoryImpl) FailureDemotionCandidates(ctx context.Context, since time.Time, minFailures, limit int) ([]models.AudienceColorCandidate, error) {
+	var out []models.AudienceColorCandidate
+	err := r.getDB(ctx).Table("sent_sms ss").
+		Select("ap.id AS audience_profile_id, COUNT(*) FILTER (WHERE ss.status = ?) AS evidence", models.SMSSendStatusUnsuccessful).
+		Joins("JOIN audience_profiles ap ON ap.phone_number = ss.phone_number AND ap.color = ? AND ap.deleted_at IS NULL", models.AudienceColorWhite).
+		Where("ss.created_at >= ?", since).
+		Where("ss.status <> ?", models.SMSSendStatusPending).
+		Where("ss.created_at > "+lastColorTransitionSQL).
+		Group("ap.id").
+		Having("COUNT(*) FILTER (WHERE ss.status = ?) >= ?", models.SMSSendStatusUnsuccessful, minFailures).
+		Having("COUNT(*) FILTER (WHERE ss.status = ?) = 0", models.SMSSendStatusSuccessful).
+		Order("ap.id").
+		Limit(limit).
+		Scan(&out).Error
+	return out, err
+}
+
+// OptOutDemotionCandidates returns white profiles whose number is blacklisted
+func (r *AudienceColorTransitionRepositoryImpl) OptOutDemotionCandidates(ctx context.Context, limit int) ([]models.AudienceColorCandidate, error) {
+	var out []models.AudienceColorCandidate
+	err := r.getDB(ctx).Table("audience_profiles ap").
+		Select("ap.id AS audience_profile_id, 0 AS evidence").
+		Joins("JOIN blacklisted_numbers bn ON bn.phone_number = ap.phone_number").
+		Where("ap.color = ? AND ap.deleted_at IS NULL", models.AudienceColorWhite).
+		Order("ap.id").
+		Limit(limit).
+		Scan(&out).Error
+	return out, err
+}
+
+// ClickPromotionCandidates returns pink, non-blacklisted profiles with at
+// least minClicks short link clicks since since and their last transition.
+// Automated click traffic is not counted.
+func (r *AudienceColorTransitionRepositoryImpl) ClickPromotionCandidates(ctx context.Context, since time.Time, minClicks, limit int) ([]models.AudienceColorCandidate, error) {
+	var out []models.AudienceColorCandidate
+	err := excludeAutomatedClickTraffic(r.getDB(ctx).Table("short_link_clicks c")).
+		Select("ap.id AS audience_profile_id, COUNT(*) AS evidence").
+		Joins("JOIN audience_profiles ap ON ap.phone_number = c.phone_number AND ap.color = ? AND ap.deleted_at IS NULL", models.AudienceColorPink).
+		Where("c.created_at >= ?", since).
+		Where("c.created_at > "+lastColorTransitionSQL).
+		Where("NOT EXISTS (SELECT 1 FROM blacklisted_numbers bn WHERE bn.phone_number = ap.phone_number)").
+		Group("ap.id").
+		Having("COUNT(*) >= ?", minClicks).
+		Order("ap.id").
+		Limit(limit).
+		Scan(&out).Error
+	return out, err
+}
+
+// PermanentErrorDemotionCandidates returns white profiles an SMS was rejected
+// for with a permanent provider error since since and their last transition
+func (r *AudienceColorTransitionRepositoryImpl) PermanentErrorDemotionCandidates(ctx context.Context, since time.Time, limit int) ([]models.AudienceColorCandidate, error) {
+	var out []models.AudienceColorCandidate
+	err := r.getDB(ctx).Table("sent_sms ss").
+		Select("ap.id AS audience_profile_id, COUNT(*) AS evidence").
+		Joins("JOIN audience_profiles ap ON ap.phone_number = ss.phone_number AND ap.color = ? AND ap.deleted_at IS NULL", models.AudienceColorWhite).
+		Where("ss.permanent_error").
+		Where("ss.created_at >= ?", since).
+		Where("ss.created_at > " + lastColorTransitionSQL).
+		Group("ap.id").
+		Order("ap.id").
+		Limit(limit).
+		Scan(&out).Error
+	return out, err
+}
+
+// Apply moves the candidates still in from to to and records a transition for
+// each moved profile, in one transaction
+func (r *AudienceColorTransitionRepositoryImpl) Apply(ctx context.Context, from, to string, reason models.AudienceColorTransitionReason, candidates []models.AudienceColorCandidate, at time.Time) (int, error) {
+	if len(candidates) == 0 {
+		return 0, nil
+	}
+	ids := make([]int64, len(candidates))
+	evidence := make(map[int64]int64, len(candidates))
+	for i, c := range candidates {
+		ids[i] = c.AudienceProfileID
+		evidence[c.AudienceProfileID] = c.Evidence
+	}
+
+	moved := 0
+	err := r.getDB(ctx).Transaction(func(tx *gorm.DB) error {
+		var updated []int64
+		if err := tx.Raw(`UPDATE audience_profiles SET color = ?, updated_at = ?
+			WHERE id IN ? AND color = ? AND deleted_at IS NULL
+			RETURNING id`, to, at, ids, from).Scan(&updated).Error; err != nil {
+			return err
+		}
+		if len(updated) == 0 {
+			return nil
+		}
+		transitions := make([]*models.AudienceColorTransition, len(updated))
+		for i, id := range updated {
+			transitions[i] = &models.AudienceColorTransition{
+				AudienceProfileID: id,
+				FromColor:         from,
+				ToColor:           to,
+				Reason:            reason,
+				Evidence:          evidence[id],
+				CreatedAt:         at,
+			}
+		}
+		if err := tx.CreateInBatches(transitions, 500).Error; err != nil {
+			return err
+		}
+		moved = len(updated)
+		return nil
+	})
+	return moved, err
+}
+
+// ByFilter returns color transitions matching the filter
+func (r *AudienceColorTransitionRepositoryImpl) ByFilter(ctx context.Context, filter models.AudienceColorTransitionFilter, orderBy string, limit, offset int) ([]*models.AudienceColorTransition, error) {
+	db := r.applyFilter(r.getDB(ctx).Model(&models.AudienceColorTransition{}), filter)
+	if orderBy == "" {
+		orderBy = "created_at DESC, id DESC"
+	}
+	db = db.Order(orderBy)
+	if limit > 0 {
+		db = db.Limit(limit)
+	}
+	if offset > 0 {
+		db = db.Offset(offset)
+	}
+
+	var transitions []*models.AudienceColorTransition
+	if err := db.Find(&transitions).Error; err != nil {
+		return nil, err
+	}
+	return transitions, nil
+}
+
+// Count returns the number of color transitions matching the filter
+func (r *AudienceColorTransitionRepositoryImpl) Count(ctx context.Context, filter models.AudienceColorTransitionFilter) (int64, error) {
+	var count int64
+	if err := r.applyFilter(r.getDB(ctx).Model(&models.AudienceColorTransition{}), filter).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// Exists checks whether any color transition matches the filter
+func (r *AudienceColorTransitionRepositoryImpl) Exists(ctx context.Context, filter models.AudienceColorTransitionFilter) (bool, error) {
+	count, err := r.Count(ctx, filter)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *AudienceColorTransitionRepositoryImpl) applyFilter(query *gorm.DB, filter models.AudienceColorTransitionFilter) *gorm.DB {
+	if filter.ID != nil {
+		query = query.Where("id = ?", *filter.ID)
+	}
+	if filter.AudienceProfileID != nil {
+		query = query.Where("audience_profile_id = ?", *filter.AudienceProfileID)
+	}
+	if filter.Reason != nil {
+		query = query.Where("reason = ?", *filter.Reason)
+	}
+	if filter.CreatedAfter != nil {
+		query = query.Where("created_at > ?", *filter.CreatedAfter)
+	}
+	return query
+}
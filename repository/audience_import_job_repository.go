@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AudienceImportJobRepositoryImpl implements AudienceImportJobRepository
+type AudienceImportJobRepositoryImpl struct {
+	*BaseRepository[models.AudienceImportJob, models.AudienceImportJobFilter]
+}
+
+// NewAudienceImportJobRepository creates a new audience import job repository
+func NewAudienceImportJobRepository(db *gorm.DB) AudienceImportJobRepository {
+	return &AudienceImportJobRepositoryImpl{
+		BaseRepository: NewBaseRepository[models.AudienceImportJob, models.AudienceImportJobFilter](db),
+	}
+}
+
+// ByUUID retrieves an import job by its UUID
+func (r *AudienceImportJobRepositoryImpl) ByUUID(ctx context.Context, id uuid.UUID) (*models.AudienceImportJob, error) {
+	db := r.getDB(ctx)
+	var job models.AudienceImportJob
+	if err := db.Where("uuid = ?", id).Last(&job).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &job, nil
+}
+
+// ClaimNext marks the oldest pending job, or a processing job whose worker
+// stopped updating it before staleBefore, as processing and returns it.
+// Concurrent workers never claim the same job. Returns nil when there is
+// nothing to do.
+func (r *AudienceImportJobRepositoryImpl) ClaimNext(ctx context.Context, staleBefore time.Time) (*models.AudienceImportJob, error) {
+	db := r.getDB(ctx)
+	now := utils.UTCNow()
+	var jobs []*models.AudienceImportJob
+	err := db.Raw(`
+		UPDATE audience_import_jobs
+		SET status = ?, started_at = COALESCE(started_at, ?), updated_at = ?
+		WHERE id = (
+			SELECT id FROM audience_import_jobs
+			WHERE status = ? OR (status = ? AND updated_at < ?)
+			ORDER BY id
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`,
+		models.AudienceImportJobStatusProcessing, now, now,
+		models.AudienceImportJobStatusPending, models.AudienceImportJobStatusProcessing, staleBefore,
+	).Scan(&jobs).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(jobs) == 0 {
+		return nil, nil
+	}
+	return jobs[0], nil
+}
+
+// Update persists the job's status, counters and errors
+func (r *AudienceImportJobRepositoryImpl) Update(ctx context.Context, job *models.AudienceImportJob) error {
+	db := r.getDB(ctx)
+	job.UpdatedAt = utils.UTCNow()
+	return db.Save(job).Error
+}
+
+// ByFilter returns import jobs matching the filter
+func (r *AudienceImportJobRepositoryImpl) ByFilter(ctx context.Context, filter models.AudienceImportJobFilter, orderBy string, limit, offset int) ([]*models.AudienceImportJob, error) {
+	db := r.applyFilter(r.getDB(ctx).Model(&models.AudienceImportJob{}), filter)
+	if orderBy == "" {
+		orderBy = "id DESC"
+	}
+	db = db.Order(orderBy)
+	if limit > 0 {
+		db = db.Limit(limit)
+	}
+	if offset > 0 {
+		db = db.Offset(offset)
+	}
+
+	var jobs []*models.AudienceImportJob
+	if err := db.Find(&jobs).Error; err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// Count returns the number of import jobs matching the filter
+func (r *AudienceImportJobRepositoryImpl) Count(ctx context.Context, filter models.AudienceImportJobFilter) (int64, error) {
+	var count int64
+	if err := r.applyFilter(r.getDB(ctx).Model(&models.AudienceImportJob{}), filter).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// Exists checks whether any import job matches the filter
+func (r *AudienceImportJobRepositoryImpl) Exists(ctx context.Context, filter models.AudienceImportJobFilter) (bool, error) {
+	count, err := r.Count(ctx, filter)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *AudienceImportJobRepositoryImpl) applyFilter(query *gorm.DB, filter models.AudienceImportJobFilter) *gorm.DB {
+	if filter.ID != nil {
+		query = query.Where("id = ?", *filter.ID)
+	}
+	if filter.UUID != nil {
+		query = query.Where("uuid = ?", *filter.UUID)
+	}
+	if filter.AdminID != nil {
+		query = query.Where("admin_id = ?", *filter.AdminID)
+	}
+	if filter.Status != nil {
+		query = query.Where("status = ?", *filter.Status)
+	}
+	return query
+}
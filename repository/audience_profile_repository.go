@@ -2,11 +2,17 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 
 	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/amirphl/Yamata-no-Orochi/utils/phonenumber"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // AudienceProfileRepositoryImpl implements AudienceProfileRepository
@@ -57,7 +63,185 @@ func (r *AudienceProfileRepositoryImpl) ByUIDs(ctx context.Context, uids []strin
 	return rows, nil
 }
 
+// Save inserts a profile, storing its phone number in canonical E.164 form
+func (r *AudienceProfileRepositoryImpl) Save(ctx context.Context, profile *models.AudienceProfile) error {
+	canonicalizeAudiencePhone(profile)
+	return r.BaseRepository.Save(ctx, profile)
+}
+
+// SaveBatch inserts profiles, storing their phone numbers in canonical E.164 form
+func (r *AudienceProfileRepositoryImpl) SaveBatch(ctx context.Context, profiles []*models.AudienceProfile) error {
+	for _, profile := range profiles {
+		canonicalizeAudiencePhone(profile)
+	}
+	return r.BaseRepository.SaveBatch(ctx, profiles)
+}
+
+// canonicalizeAudiencePhone rewrites Iranian mobile numbers to E.164. Other
+// values are kept so foreign or legacy numbers are not lost.
+func canonicalizeAudiencePhone(profile *models.AudienceProfile) {
+	if profile != nil && profile.PhoneNumber != nil && *profile.PhoneNumber != "" {
+		profile.PhoneNumber = utils.ToPtr(phonenumber.CanonicalOrRaw(*profile.PhoneNumber))
+	}
+}
+
+// AttributesByIDs returns the personalization attributes of the given profiles.
+// Profiles without attributes are omitted; non-string values are skipped.
+func (r *AudienceProfileRepositoryImpl) AttributesByIDs(ctx context.Context, ids []int64) (map[int64]map[string]string, error) {
+	out := make(map[int64]map[string]string)
+	if len(ids) == 0 {
+		return out, nil
+	}
+
+	type row struct {
+		ID         int64
+		Attributes json.RawMessage
+	}
+	var rows []row
+	db := r.getDB(ctx)
+	if err := db.Model(&models.AudienceProfile{}).
+		Select("id, attributes").
+		Where("id IN ? AND attributes <> '{}'::jsonb", ids).
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	for _, rw := range rows {
+		var raw map[string]any
+		if err := json.Unmarshal(rw.Attributes, &raw); err != nil {
+			return nil, fmt.Errorf("decode attributes of audience profile %d: %w", rw.ID, err)
+		}
+		attrs := make(map[string]string, len(raw))
+		for k, v := range raw {
+			if s, ok := v.(string); ok {
+				attrs[k] = s
+			}
+		}
+		out[rw.ID] = attrs
+	}
+	return out, nil
+}
+
+// AudienceProfileImportEntry is one phone number to import into audience_profiles
+type AudienceProfileImportEntry struct {
+	// PhoneNumber is stored for new profiles
+	PhoneNumber string
+	// Aliases are other stored forms of the same number matched against existing profiles
+	Aliases []string
+	Tags    []int32
+}
+
+// ImportPhoneNumbers creates white profiles for numbers that have no profile
+// yet and merges the entry tags into the profiles that already exist. Entries
+// must not repeat a number. It returns how many entries created a profile and
+// how many matched an existing one.
+func (r *AudienceProfileRepositoryImpl) ImportPhoneNumbers(ctx context.Context, entries []AudienceProfileImportEntry) (inserted, existing int64, err error) {
+	if len(entries) == 0 {
+		return 0, 0, nil
+	}
+
+	db, shouldCommit, err := r.getDBForWrite(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	if shouldCommit {
+		defer func() {
+			if err != nil {
+				db.Rollback()
+			} else {
+				db.Commit()
+			}
+		}()
+	}
+
+	lookup := make([]string, 0, len(entries))
+	for _, e := range entries {
+		lookup = append(lookup, e.PhoneNumber)
+		lookup = append(lookup, e.Aliases...)
+	}
+	var found []struct {
+		ID          int64
+		PhoneNumber string
+	}
+	// Soft-deleted profiles still own their number and stay deleted
+	if err = db.Unscoped().Model(&models.AudienceProfile{}).
+		Select("id, phone_number").
+		Where("phone_number IN ?", lookup).
+		Scan(&found).Error; err != nil {
+		return 0, 0, err
+	}
+	byPhone := make(map[string]int64, len(found))
+	for _, f := range found {
+		byPhone[f.PhoneNumber] = f.ID
+	}
+
+	// Existing profiles are grouped by tag set so each set is merged in one statement
+	idsByTags := make(map[string][]int64)
+	tagsByKey := make(map[string]pq.Int32Array)
+	newProfiles := make([]*models.AudienceProfile, 0, len(entries))
+	for _, e := range entries {
+		id, ok := byPhone[e.PhoneNumber]
+		for _, alias := range e.Aliases {
+			if ok {
+				break
+			}
+			id, ok = byPhone[alias]
+		}
+		if ok {
+			existing++
+			if len(e.Tags) > 0 {
+				key := fmt.Sprint(e.Tags)
+				idsByTags[key] = append(idsByTags[key], id)
+				tagsByKey[key] = pq.Int32Array(e.Tags)
+			}
+			continue
+		}
+
+		phone := e.PhoneNumber
+		tags := pq.Int32Array(e.Tags)
+		if tags == nil {
+			tags = pq.Int32Array{}
+		}
+		newProfiles = append(newProfiles, &models.AudienceProfile{
+			UID:         uuid.NewString(),
+			PhoneNumber: &phone,
+			Tags:        tags,
+			Color:       models.AudienceColorWhite,
+			Attributes:  json.RawMessage(`{}`),
+		})
+	}
+
+	now := utils.UTCNow()
+	for key, ids := range idsByTags {
+		if err = db.Exec(`
+			UPDATE audience_profiles
+			SET tags = ARRAY(SELECT DISTINCT unnest(COALESCE(tags, '{}'::integer[]) || ?::integer[])),
+				updated_at = ?
+			WHERE id IN ? AND NOT (COALESCE(tags, '{}'::integer[]) @> ?::integer[])`,
+			tagsByKey[key], now, ids, tagsByKey[key],
+		).Error; err != nil {
+			return 0, 0, err
+		}
+	}
+
+	if len(newProfiles) > 0 {
+		// A profile created concurrently for the same number is left untouched
+		res := db.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(newProfiles, 1000)
+		if err = res.Error; err != nil {
+			return 0, 0, err
+		}
+		inserted = res.RowsAffected
+		// Numbers inserted concurrently by another writer count as existing
+		existing += int64(len(newProfiles)) - inserted
+	}
+
+	return inserted, existing, nil
+}
+
 func (r *AudienceProfileRepositoryImpl) applyFilter(db *gorm.DB, f models.AudienceProfileFilter) *gorm.DB {
+	if f.IncludeDeleted {
+		db = db.Unscoped()
+	}
 	if f.ID != nil {
 		db = db.Where("id = ?", *f.ID)
 	}
@@ -65,7 +249,7 @@ func (r *AudienceProfileRepositoryImpl) applyFilter(db *gorm.DB, f models.Audien
 		db = db.Where("uid = ?", *f.UID)
 	}
 	if f.PhoneNumber != nil {
-		db = db.Where("phone_number = ?", *f.PhoneNumber)
+		db = db.Where("phone_number = ?", phonenumber.CanonicalOrRaw(*f.PhoneNumber))
 	}
 	if f.Tags != nil && len(*f.Tags) > 0 {
 		db = db.Where("tags && ?", *f.Tags) // overlap operator for arrays
@@ -73,6 +257,13 @@ func (r *AudienceProfileRepositoryImpl) applyFilter(db *gorm.DB, f models.Audien
 	if f.Color != nil {
 		db = db.Where("color = ?", *f.Color)
 	}
+	if f.HasPhoneNumber != nil {
+		if *f.HasPhoneNumber {
+			db = db.Where("phone_number IS NOT NULL AND phone_number <> ''")
+		} else {
+			db = db.Where("phone_number IS NULL OR phone_number = ''")
+		}
+	}
 	if f.CreatedAfter != nil {
 		db = db.Where("created_at >= ?", *f.CreatedAfter)
 	}
@@ -120,7 +311,7 @@ func (r *AudienceProfileRepositoryImpl) ByFilter(ctx context.Context, filter mod
 }
 
 func (r *AudienceProfileRepositoryImpl) Count(ctx context.Context, filter models.AudienceProfileFilter) (int64, error) {
-	db := r.getDB(ctx)
+	db := r.getReadDB(ctx)
 	query := r.applyFilter(db.Model(&models.AudienceProfile{}), filter)
 	var count int64
 	if err := query.Count(&count).Error; err != nil {
@@ -136,3 +327,13 @@ func (r *AudienceProfileRepositoryImpl) Exists(ctx context.Context, filter model
 	}
 	return count > 0, nil
 }
+
+// SoftDelete marks a audience profile deleted, leaving it out of later queries
+func (r *AudienceProfileRepositoryImpl) SoftDelete(ctx context.Context, id uint) (bool, error) {
+	return r.softDelete(ctx, id)
+}
+
+// Restore brings back a soft-deleted audience profile
+func (r *AudienceProfileRepositoryImpl) Restore(ctx context.Context, id uint) (bool, error) {
+	return r.restore(ctx, id)
+}
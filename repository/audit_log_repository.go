@@ -3,9 +3,12 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 
 	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
 	"gorm.io/gorm"
 )
 
@@ -21,6 +24,83 @@ func NewAuditLogRepository(db *gorm.DB) AuditLogRepository {
 	}
 }
 
+// Save stores an audit log entry, tagging it with the impersonation and
+// delegation of ctx
+func (r *AuditLogRepositoryImpl) Save(ctx context.Context, entry *models.AuditLog) error {
+	tagRequestID(ctx, entry)
+	tagImpersonation(ctx, entry)
+	tagDelegation(ctx, entry)
+	return r.BaseRepository.Save(ctx, entry)
+}
+
+// tagRequestID fills in the request ID of entries saved while serving a
+// request, for flows that do not set it themselves
+func tagRequestID(ctx context.Context, entry *models.AuditLog) {
+	if entry.RequestID != nil {
+		return
+	}
+	if requestID, ok := ctx.Value(utils.RequestIDKey).(string); ok && requestID != "" {
+		entry.RequestID = &requestID
+	}
+}
+
+// tagImpersonation records the admin and customer of an impersonated request
+// in the entry's metadata under "impersonation". Entries without a customer
+// get the impersonated one.
+func tagImpersonation(ctx context.Context, entry *models.AuditLog) {
+	impersonation, ok := ctx.Value(utils.ImpersonationKey).(utils.Impersonation)
+	if !ok || impersonation.AdminID == 0 {
+		return
+	}
+
+	var metadata map[string]any
+	if len(entry.Metadata) > 0 {
+		if err := json.Unmarshal(entry.Metadata, &metadata); err != nil {
+			metadata = map[string]any{"original": entry.Metadata}
+		}
+	}
+	if metadata == nil {
+		metadata = map[string]any{}
+	}
+	metadata["impersonation"] = impersonation
+	if raw, err := json.Marshal(metadata); err == nil {
+		entry.Metadata = raw
+	}
+	if entry.CustomerID == nil && impersonation.CustomerID != 0 {
+		customerID := impersonation.CustomerID
+		entry.CustomerID = &customerID
+	}
+}
+
+// tagDelegation records the agency and customer of a request an agency made
+// for a referred customer in the entry's metadata under "delegation", so the
+// entry names both the principal and the delegate. Entries without a
+// customer get the principal.
+func tagDelegation(ctx context.Context, entry *models.AuditLog) {
+	delegation, ok := ctx.Value(utils.DelegationKey).(utils.Delegation)
+	if !ok || delegation.AgencyID == 0 {
+		return
+	}
+
+	var metadata map[string]any
+	if len(entry.Metadata) > 0 {
+		if err := json.Unmarshal(entry.Metadata, &metadata); err != nil {
+			metadata = map[string]any{"original": entry.Metadata}
+		}
+	}
+	if metadata == nil {
+		metadata = map[string]any{}
+	}
+	metadata["delegation"] = delegation
+	if raw, err := json.Marshal(metadata); err == nil {
+		entry.Metadata = raw
+	}
+	if entry.CustomerID == nil && delegation.CustomerID != 0 {
+		customerID := delegation.CustomerID
+		entry.CustomerID = &customerID
+	}
+}
+
 // ByID retrieves an audit log by its ID with preloaded relationships
 func (r *AuditLogRepositoryImpl) ByID(ctx context.Context, id uint) (*models.AuditLog, error) {
 	db := r.getDB(ctx)
@@ -77,6 +157,23 @@ func (r *AuditLogRepositoryImpl) ListByAction(ctx context.Context, action string
 }
 
 // ListFailedActions retrieves all failed audit log entries with pagination
+// ListByCampaign returns up to limit audit log entries whose metadata names
+// the campaign, oldest first
+func (r *AuditLogRepositoryImpl) ListByCampaign(ctx context.Context, campaignID uint, limit int) ([]*models.AuditLog, error) {
+	db := r.getDB(ctx)
+
+	var logs []*models.AuditLog
+	err := db.Where("metadata @> ?::jsonb", fmt.Sprintf(`{"campaign_id":%d}`, campaignID)).
+		Order("created_at ASC, id ASC").
+		Limit(limit).
+		Find(&logs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return logs, nil
+}
+
 func (r *AuditLogRepositoryImpl) ListFailedActions(ctx context.Context, limit, offset int) ([]*models.AuditLog, error) {
 	db := r.getDB(ctx)
 
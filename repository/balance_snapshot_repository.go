@@ -10,6 +10,17 @@ import (
 	"gorm.io/gorm"
 )
 
+// WalletBalanceTotals sums the latest balance snapshots of a set of wallets
+type WalletBalanceTotals struct {
+	Wallets            int64  `json:"wallets"`
+	FreeBalance        uint64 `json:"free_balance"`
+	FrozenBalance      uint64 `json:"frozen_balance"`
+	LockedBalance      uint64 `json:"locked_balance"`
+	CreditBalance      uint64 `json:"credit_balance"`
+	SpentOnCampaign    uint64 `json:"spent_on_campaign"`
+	AgencyShareWithTax uint64 `json:"agency_share_with_tax"`
+}
+
 // BalanceSnapshotRepositoryImpl implements BalanceSnapshotRepository interface
 type BalanceSnapshotRepositoryImpl struct {
 	*BaseRepository[models.BalanceSnapshot, models.BalanceSnapshotFilter]
@@ -240,3 +251,36 @@ func (r *BalanceSnapshotRepositoryImpl) applyFilter(query *gorm.DB, filter model
 	}
 	return query
 }
+
+// SumLatestBalances sums the latest balance snapshot of every wallet except
+// the ones with the given UUIDs and those of sandbox customers
+func (r *BalanceSnapshotRepositoryImpl) SumLatestBalances(ctx context.Context, excludeWalletUUIDs []string) (*WalletBalanceTotals, error) {
+	db := r.getReadDB(ctx)
+	latest := db.
+		Table("balance_snapshots bs").
+		Select("DISTINCT ON (bs.wallet_id) bs.*").
+		Joins("JOIN wallets w ON w.id = bs.wallet_id").
+		Joins("JOIN customers c ON c.id = w.customer_id").
+		Where("bs.deleted_at IS NULL").
+		Where("NOT c.is_sandbox").
+		Order("bs.wallet_id, bs.created_at DESC, bs.id DESC")
+	if len(excludeWalletUUIDs) > 0 {
+		latest = latest.Where("w.uuid::text NOT IN ?", excludeWalletUUIDs)
+	}
+
+	var totals WalletBalanceTotals
+	err := db.
+		Table("(?) s", latest).
+		Select(`COUNT(*) AS wallets,
+			COALESCE(SUM(s.free_balance), 0) AS free_balance,
+			COALESCE(SUM(s.frozen_balance), 0) AS frozen_balance,
+			COALESCE(SUM(s.locked_balance), 0) AS locked_balance,
+			COALESCE(SUM(s.credit_balance), 0) AS credit_balance,
+			COALESCE(SUM(s.spent_on_campaign), 0) AS spent_on_campaign,
+			COALESCE(SUM(s.agency_share_with_tax), 0) AS agency_share_with_tax`).
+		Scan(&totals).Error
+	if err != nil {
+		return nil, err
+	}
+	return &totals, nil
+}
@@ -3,6 +3,7 @@ package repository
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 
 	"gorm.io/gorm"
@@ -11,6 +12,8 @@ import (
 // BaseRepository provides common repository functionality with transaction support
 type BaseRepository[T any, F any] struct {
 	DB *gorm.DB
+	// ReadDB is an optional read-only replica used by getReadDB
+	ReadDB *gorm.DB
 }
 
 // NewBaseRepository creates a new base repository instance
@@ -28,6 +31,25 @@ func (r *BaseRepository[T, F]) getDB(ctx context.Context) *gorm.DB {
 	return r.DB.WithContext(ctx)
 }
 
+// getReadDB returns the connection for heavy reads that tolerate replication
+// lag, such as history listings, reports, and audience counts. Inside a
+// transaction the transaction is returned so reads observe its own writes.
+func (r *BaseRepository[T, F]) getReadDB(ctx context.Context) *gorm.DB {
+	if tx, ok := ctx.Value(TxContextKey).(*gorm.DB); ok && tx != nil {
+		return tx
+	}
+	if r.ReadDB != nil {
+		return r.ReadDB.WithContext(ctx)
+	}
+	return r.DB.WithContext(ctx)
+}
+
+// SetReadReplica routes the repository's lag-tolerant reads to replica; nil
+// routes them back to the primary
+func (r *BaseRepository[T, F]) SetReadReplica(replica *gorm.DB) {
+	r.ReadDB = replica
+}
+
 // getDBForWrite returns database connection with transaction for write operations
 func (r *BaseRepository[T, F]) getDBForWrite(ctx context.Context) (*gorm.DB, bool, error) {
 	if tx, ok := ctx.Value(TxContextKey).(*gorm.DB); ok && tx != nil {
@@ -308,8 +330,22 @@ func (r *BaseRepository[T, F]) SaveBatch(ctx context.Context, entities []*T) err
 // }
 
 // WithTransaction executes a function within a database transaction
+//
+// The transaction is pinned to a dedicated connection, exposed in the context
+// under TxConnContextKey, so repositories can run driver-level commands inside
+// it. When db is itself a transaction the pinning is skipped.
 func WithTransaction(ctx context.Context, db *gorm.DB, fn func(context.Context) error) (err error) {
-	tx := db.WithContext(ctx).Begin()
+	txDB := db.WithContext(ctx)
+	var conn *sql.Conn
+	if sqlDB, dbErr := db.DB(); dbErr == nil {
+		if conn, err = sqlDB.Conn(ctx); err != nil {
+			return fmt.Errorf("failed to acquire connection: %w", err)
+		}
+		defer conn.Close()
+		txDB.Statement.ConnPool = conn
+	}
+
+	tx := txDB.Begin()
 	if tx.Error != nil {
 		return fmt.Errorf("failed to begin transaction: %w", tx.Error)
 	}
@@ -322,6 +358,9 @@ func WithTransaction(ctx context.Context, db *gorm.DB, fn func(context.Context)
 	}()
 
 	ctx = context.WithValue(ctx, TxContextKey, tx)
+	if conn != nil {
+		ctx = context.WithValue(ctx, TxConnContextKey, conn)
+	}
 
 	if err := fn(ctx); err != nil {
 		tx.Rollback()
@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/utils/phonenumber"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// BlacklistedNumberRepositoryImpl implements BlacklistedNumberRepository
+type BlacklistedNumberRepositoryImpl struct {
+	*BaseRepository[models.BlacklistedNumber, models.BlacklistedNumberFilter]
+}
+
+// NewBlacklistedNumberRepository creates a new blacklisted number repository
+func NewBlacklistedNumberRepository(db *gorm.DB) BlacklistedNumberRepository {
+	return &BlacklistedNumberRepositoryImpl{
+		BaseRepository: NewBaseRepository[models.BlacklistedNumber, models.BlacklistedNumberFilter](db),
+	}
+}
+
+// Save inserts a blacklisted number, storing it in canonical E.164 form
+func (r *BlacklistedNumberRepositoryImpl) Save(ctx context.Context, entry *models.BlacklistedNumber) error {
+	entry.PhoneNumber = phonenumber.CanonicalOrRaw(entry.PhoneNumber)
+	return r.BaseRepository.Save(ctx, entry)
+}
+
+// SaveBatch inserts blacklisted numbers, storing them in canonical E.164 form
+func (r *BlacklistedNumberRepositoryImpl) SaveBatch(ctx context.Context, entries []*models.BlacklistedNumber) error {
+	for _, entry := range entries {
+		entry.PhoneNumber = phonenumber.CanonicalOrRaw(entry.PhoneNumber)
+	}
+	return r.BaseRepository.SaveBatch(ctx, entries)
+}
+
+// InsertMissing inserts the entries whose phone number is not blacklisted yet
+// and returns how many were inserted. Existing entries keep their source and
+// reason.
+func (r *BlacklistedNumberRepositoryImpl) InsertMissing(ctx context.Context, entries []*models.BlacklistedNumber) (int64, error) {
+	if len(entries) == 0 {
+		return 0, nil
+	}
+	for _, entry := range entries {
+		entry.PhoneNumber = phonenumber.CanonicalOrRaw(entry.PhoneNumber)
+	}
+	res := r.getDB(ctx).Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(entries, 1000)
+	if res.Error != nil {
+		return 0, res.Error
+	}
+	return res.RowsAffected, nil
+}
+
+// DeleteByPhoneNumber removes a number from the blacklist. It reports whether
+// the number was blacklisted.
+func (r *BlacklistedNumberRepositoryImpl) DeleteByPhoneNumber(ctx context.Context, phoneNumber string) (bool, error) {
+	res := r.getDB(ctx).
+		Where("phone_number = ?", phonenumber.CanonicalOrRaw(phoneNumber)).
+		Delete(&models.BlacklistedNumber{})
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
+
+// PhoneNumberSet returns every blacklisted phone number
+func (r *BlacklistedNumberRepositoryImpl) PhoneNumberSet(ctx context.Context) (map[string]struct{}, error) {
+	var phones []string
+	if err := r.getDB(ctx).Model(&models.BlacklistedNumber{}).Pluck("phone_number", &phones).Error; err != nil {
+		return nil, err
+	}
+	set := make(map[string]struct{}, len(phones))
+	for _, p := range phones {
+		set[p] = struct{}{}
+	}
+	return set, nil
+}
+
+// ByFilter returns blacklisted numbers matching the filter
+func (r *BlacklistedNumberRepositoryImpl) ByFilter(ctx context.Context, filter models.BlacklistedNumberFilter, orderBy string, limit, offset int) ([]*models.BlacklistedNumber, error) {
+	db := r.applyFilter(r.getDB(ctx).Model(&models.BlacklistedNumber{}), filter)
+	if orderBy == "" {
+		orderBy = "id DESC"
+	}
+	db = db.Order(orderBy)
+	if limit > 0 {
+		db = db.Limit(limit)
+	}
+	if offset > 0 {
+		db = db.Offset(offset)
+	}
+
+	var entries []*models.BlacklistedNumber
+	if err := db.Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// Count returns the number of blacklisted numbers matching the filter
+func (r *BlacklistedNumberRepositoryImpl) Count(ctx context.Context, filter models.BlacklistedNumberFilter) (int64, error) {
+	var count int64
+	if err := r.applyFilter(r.getDB(ctx).Model(&models.BlacklistedNumber{}), filter).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// Exists checks whether any blacklisted number matches the filter
+func (r *BlacklistedNumberRepositoryImpl) Exists(ctx context.Context, filter models.BlacklistedNumberFilter) (bool, error) {
+	count, err := r.Count(ctx, filter)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *BlacklistedNumberRepositoryImpl) applyFilter(query *gorm.DB, filter models.BlacklistedNumberFilter) *gorm.DB {
+	if filter.ID != nil {
+		query = query.Where("id = ?", *filter.ID)
+	}
+	if filter.PhoneNumber != nil {
+		query = query.Where("phone_number = ?", phonenumber.CanonicalOrRaw(*filter.PhoneNumber))
+	}
+	if filter.Source != nil {
+		query = query.Where("source = ?", *filter.Source)
+	}
+	return query
+}
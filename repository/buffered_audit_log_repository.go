@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"gorm.io/gorm"
+)
+
+// BufferedAuditLogRepository takes audit log writes off the request path. Save
+// queues the entry and returns; a single flusher writes queued entries with
+// SaveBatch every flushInterval or once batchSize entries are waiting. Entries
+// are written in the order they were saved, so the history of a customer keeps
+// its order.
+//
+// Saves inside a transaction are written synchronously through it so they
+// still roll back with it. When the queue is full Save blocks until the
+// flusher catches up; after Close it writes synchronously.
+type BufferedAuditLogRepository struct {
+	AuditLogRepository
+
+	entries       chan *models.AuditLog
+	batchSize     int
+	flushInterval time.Duration
+	logger        *log.Logger
+
+	mu     sync.RWMutex
+	closed bool
+	done   chan struct{}
+}
+
+// NewBufferedAuditLogRepository wraps repo and starts its flusher
+func NewBufferedAuditLogRepository(
+	repo AuditLogRepository,
+	logger *log.Logger,
+	bufferSize int,
+	batchSize int,
+	flushInterval time.Duration,
+) *BufferedAuditLogRepository {
+	if bufferSize <= 0 {
+		bufferSize = 4096
+	}
+	if batchSize <= 0 {
+		batchSize = 200
+	}
+	if flushInterval <= 0 {
+		flushInterval = time.Second
+	}
+	if logger == nil {
+		logger = log.Default()
+	}
+	r := &BufferedAuditLogRepository{
+		AuditLogRepository: repo,
+		entries:            make(chan *models.AuditLog, bufferSize),
+		batchSize:          batchSize,
+		flushInterval:      flushInterval,
+		logger:             logger,
+		done:               make(chan struct{}),
+	}
+	go r.run()
+	return r
+}
+
+// Save queues an audit log entry, stamping its creation time now
+func (r *BufferedAuditLogRepository) Save(ctx context.Context, entry *models.AuditLog) error {
+	if tx, ok := ctx.Value(TxContextKey).(*gorm.DB); ok && tx != nil {
+		return r.AuditLogRepository.Save(ctx, entry)
+	}
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = utils.UTCNow()
+	}
+	// The flusher writes without the request context, so the entry is tagged
+	// while it is still known
+	tagRequestID(ctx, entry)
+	tagImpersonation(ctx, entry)
+	tagDelegation(ctx, entry)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.closed {
+		return r.AuditLogRepository.Save(context.WithoutCancel(ctx), entry)
+	}
+	r.entries <- entry
+	return nil
+}
+
+// Close stops accepting entries and waits until the queued ones are written
+func (r *BufferedAuditLogRepository) Close(ctx context.Context) error {
+	r.mu.Lock()
+	if !r.closed {
+		r.closed = true
+		close(r.entries)
+	}
+	r.mu.Unlock()
+
+	select {
+	case <-r.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (r *BufferedAuditLogRepository) run() {
+	defer close(r.done)
+	ticker := time.NewTicker(r.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*models.AuditLog, 0, r.batchSize)
+	for {
+		select {
+		case entry, ok := <-r.entries:
+			if !ok {
+				r.flush(batch)
+				return
+			}
+			batch = append(batch, entry)
+			if len(batch) >= r.batchSize {
+				r.flush(batch)
+				batch = make([]*models.AuditLog, 0, r.batchSize)
+			}
+		case <-ticker.C:
+			if len(batch) > 0 {
+				r.flush(batch)
+				batch = make([]*models.AuditLog, 0, r.batchSize)
+			}
+		}
+	}
+}
+
+// flush writes a batch; when the batch insert fails its entries are retried
+// one by one so a single invalid entry does not drop the others
+func (r *BufferedAuditLogRepository) flush(batch []*models.AuditLog) {
+	if len(batch) == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	err := r.AuditLogRepository.SaveBatch(ctx, batch)
+	if err == nil {
+		return
+	}
+	r.logger.Printf("audit log writer: batch of %d failed, retrying individually: %v", len(batch), err)
+	for _, entry := range batch {
+		if err := r.AuditLogRepository.Save(ctx, entry); err != nil {
+			r.logger.Printf("audit log writer: dropped %s entry: %v", entry.Action, err)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"gorm.io/gorm"
+)
+
+type recordingAuditLogRepository struct {
+	AuditLogRepository
+
+	mu      sync.Mutex
+	saved   []string
+	batches int
+}
+
+func (r *recordingAuditLogRepository) Save(_ context.Context, entry *models.AuditLog) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if entry.Action == "invalid" {
+		return errors.New("check constraint violated")
+	}
+	r.saved = append(r.saved, entry.Action)
+	return nil
+}
+
+func (r *recordingAuditLogRepository) SaveBatch(_ context.Context, entries []*models.AuditLog) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.batches++
+	for _, entry := range entries {
+		if entry.Action == "invalid" {
+			return errors.New("check constraint violated")
+		}
+	}
+	for _, entry := range entries {
+		r.saved = append(r.saved, entry.Action)
+	}
+	return nil
+}
+
+func (r *recordingAuditLogRepository) snapshot() ([]string, int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.saved...), r.batches
+}
+
+func TestBufferedAuditLogRepositoryFlushesInOrderOnClose(t *testing.T) {
+	t.Parallel()
+
+	inner := &recordingAuditLogRepository{}
+	repo := NewBufferedAuditLogRepository(inner, nil, 16, 2, time.Hour)
+
+	for _, action := range []string{"a", "b", "c", "invalid", "d"} {
+		if err := repo.Save(context.Background(), &models.AuditLog{Action: action}); err != nil {
+			t.Fatalf("unexpected save error: %v", err)
+		}
+	}
+	if err := repo.Close(context.Background()); err != nil {
+		t.Fatalf("unexpected close error: %v", err)
+	}
+
+	saved, batches := inner.snapshot()
+	if got := len(saved); got != 4 || saved[0] != "a" || saved[1] != "b" || saved[2] != "c" || saved[3] != "d" {
+		t.Fatalf("expected a,b,c,d written in order without the invalid entry, got %v", saved)
+	}
+	if batches != 3 {
+		t.Fatalf("expected 3 batches of at most 2 entries, got %d", batches)
+	}
+
+	// Saves after Close are written synchronously
+	if err := repo.Save(context.Background(), &models.AuditLog{Action: "e"}); err != nil {
+		t.Fatalf("unexpected save error after close: %v", err)
+	}
+	if saved, _ := inner.snapshot(); saved[len(saved)-1] != "e" {
+		t.Fatalf("expected entry saved after close to be written, got %v", saved)
+	}
+}
+
+func TestBufferedAuditLogRepositoryFlushesOnInterval(t *testing.T) {
+	t.Parallel()
+
+	inner := &recordingAuditLogRepository{}
+	repo := NewBufferedAuditLogRepository(inner, nil, 16, 100, 10*time.Millisecond)
+	defer repo.Close(context.Background())
+
+	entry := &models.AuditLog{Action: "login_success"}
+	if err := repo.Save(context.Background(), entry); err != nil {
+		t.Fatalf("unexpected save error: %v", err)
+	}
+	if entry.CreatedAt.IsZero() {
+		t.Fatalf("expected queued entry to be stamped with its creation time")
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if saved, _ := inner.snapshot(); len(saved) == 1 {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected entry to be flushed by the interval")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestBufferedAuditLogRepositoryWritesThroughTransactions(t *testing.T) {
+	t.Parallel()
+
+	inner := &recordingAuditLogRepository{}
+	repo := NewBufferedAuditLogRepository(inner, nil, 16, 100, time.Hour)
+	defer repo.Close(context.Background())
+
+	txCtx := context.WithValue(context.Background(), TxContextKey, &gorm.DB{})
+	if err := repo.Save(txCtx, &models.AuditLog{Action: "in_tx"}); err != nil {
+		t.Fatalf("unexpected save error: %v", err)
+	}
+	if saved, batches := inner.snapshot(); len(saved) != 1 || batches != 0 {
+		t.Fatalf("expected transactional save to bypass the queue, got saved=%v batches=%d", saved, batches)
+	}
+}
+
+func TestBufferedAuditLogRepositoryTagsImpersonation(t *testing.T) {
+	t.Parallel()
+
+	inner := &recordingAuditLogRepository{}
+	repo := NewBufferedAuditLogRepository(inner, nil, 16, 100, time.Hour)
+	defer repo.Close(context.Background())
+
+	ctx := context.WithValue(context.Background(), utils.ImpersonationKey, utils.Impersonation{AdminID: 3, CustomerID: 42})
+	entry := &models.AuditLog{Action: "campaign_created", Metadata: json.RawMessage(`{"campaign_id":9}`)}
+	if err := repo.Save(ctx, entry); err != nil {
+		t.Fatalf("unexpected save error: %v", err)
+	}
+
+	var metadata struct {
+		CampaignID    uint                `json:"campaign_id"`
+		Impersonation utils.Impersonation `json:"impersonation"`
+	}
+	if err := json.Unmarshal(entry.Metadata, &metadata); err != nil {
+		t.Fatalf("unexpected metadata %s: %v", entry.Metadata, err)
+	}
+	if metadata.CampaignID != 9 || metadata.Impersonation.AdminID != 3 || metadata.Impersonation.CustomerID != 42 {
+		t.Fatalf("expected impersonation added to metadata, got %s", entry.Metadata)
+	}
+	if entry.CustomerID == nil || *entry.CustomerID != 42 {
+		t.Fatalf("expected the impersonated customer on the entry, got %v", entry.CustomerID)
+	}
+
+	plain := &models.AuditLog{Action: "login_success"}
+	if err := repo.Save(context.Background(), plain); err != nil {
+		t.Fatalf("unexpected save error: %v", err)
+	}
+	if plain.Metadata != nil || plain.CustomerID != nil {
+		t.Fatalf("expected entries outside impersonation untouched, got %+v", plain)
+	}
+}
+
+func TestBufferedAuditLogRepositoryTagsDelegation(t *testing.T) {
+	t.Parallel()
+
+	inner := &recordingAuditLogRepository{}
+	repo := NewBufferedAuditLogRepository(inner, nil, 16, 100, time.Hour)
+	defer repo.Close(context.Background())
+
+	ctx := context.WithValue(context.Background(), utils.DelegationKey, utils.Delegation{AgencyID: 7, CustomerID: 42})
+	entry := &models.AuditLog{Action: "campaign_created"}
+	if err := repo.Save(ctx, entry); err != nil {
+		t.Fatalf("unexpected save error: %v", err)
+	}
+
+	var metadata struct {
+		Delegation utils.Delegation `json:"delegation"`
+	}
+	if err := json.Unmarshal(entry.Metadata, &metadata); err != nil {
+		t.Fatalf("unexpected metadata %s: %v", entry.Metadata, err)
+	}
+	if metadata.Delegation.AgencyID != 7 || metadata.Delegation.CustomerID != 42 {
+		t.Fatalf("expected delegation added to metadata, got %s", entry.Metadata)
+	}
+	if entry.CustomerID == nil || *entry.CustomerID != 42 {
+		t.Fatalf("expected the delegating customer on the entry, got %v", entry.CustomerID)
+	}
+}
+
+func TestBufferedAuditLogRepositoryTagsRequestID(t *testing.T) {
+	t.Parallel()
+
+	inner := &recordingAuditLogRepository{}
+	repo := NewBufferedAuditLogRepository(inner, nil, 16, 100, time.Hour)
+	defer repo.Close(context.Background())
+
+	ctx := context.WithValue(context.Background(), utils.RequestIDKey, "req-1")
+	entry := &models.AuditLog{Action: "campaign_created"}
+	own := &models.AuditLog{Action: "login_success", RequestID: utils.ToPtr("req-0")}
+	for _, e := range []*models.AuditLog{entry, own} {
+		if err := repo.Save(ctx, e); err != nil {
+			t.Fatalf("unexpected save error: %v", err)
+		}
+	}
+
+	if entry.RequestID == nil || *entry.RequestID != "req-1" {
+		t.Fatalf("expected the request ID from the context, got %v", entry.RequestID)
+	}
+	if *own.RequestID != "req-0" {
+		t.Fatalf("expected an entry's own request ID kept, got %s", *own.RequestID)
+	}
+}
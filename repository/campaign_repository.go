@@ -26,6 +26,21 @@ func NewCampaignRepository(db *gorm.DB) CampaignRepository {
 	}
 }
 
+// AgencyCampaignAggregate is a report row of a campaign run by a customer
+// referred by an agency
+type AgencyCampaignAggregate struct {
+	CampaignID              uint      `json:"campaign_id"`
+	UUID                    string    `json:"uuid"`
+	Title                   string    `json:"title"`
+	Status                  string    `json:"status"`
+	CustomerID              uint      `json:"customer_id"`
+	RepresentativeFirstName string    `json:"representative_first_name"`
+	RepresentativeLastName  string    `json:"representative_last_name"`
+	CompanyName             string    `json:"company_name"`
+	TotalSent               uint64    `json:"total_sent"`
+	CreatedAt               time.Time `json:"created_at"`
+}
+
 // statisticsWithoutTrackingResults is the SELECT expression that returns all statistics
 // keys except "trackingResults", which can be very large and is excluded from read queries.
 const statisticsWithoutTrackingResults = "campaigns.id, campaigns.uuid, campaigns.customer_id, campaigns.hidden, campaigns.status, " +
@@ -250,6 +265,32 @@ func (r *CampaignRepositoryImpl) MarkHidden(ctx context.Context, customerID uint
 	return result.RowsAffected, nil
 }
 
+// DeleteSandboxByCustomerID deletes the sandbox campaigns of a customer with
+// their reviews and rollups, and returns how many were deleted
+func (r *CampaignRepositoryImpl) DeleteSandboxByCustomerID(ctx context.Context, customerID uint) (int64, error) {
+	db, shouldCommit, err := r.getDBForWrite(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	if shouldCommit {
+		defer func() {
+			if err != nil {
+				db.Rollback()
+			} else {
+				db.Commit()
+			}
+		}()
+	}
+
+	// Sandbox data is purged for good, not soft-deleted
+	result := db.Unscoped().Where("customer_id = ? AND is_sandbox", customerID).Delete(&models.Campaign{})
+	if err = result.Error; err != nil {
+		return 0, err
+	}
+	return result.RowsAffected, nil
+}
+
 func (r *CampaignRepositoryImpl) MarkVisible(ctx context.Context, customerID uint, campaignIDs []uint) (int64, error) {
 	if len(campaignIDs) == 0 {
 		return 0, nil
@@ -321,6 +362,31 @@ func (r *CampaignRepositoryImpl) GetScheduledCampaigns(ctx context.Context, from
 	return r.ByFilter(ctx, filter, "schedule_at ASC", 0, 0)
 }
 
+// ListDueRecurringParents retrieves recurring parent campaigns whose next
+// occurrence is due at or before the given time. Only series whose parent was
+// approved are returned; cancelled or rejected parents stop their series.
+func (r *CampaignRepositoryImpl) ListDueRecurringParents(ctx context.Context, dueBefore time.Time, limit int) ([]*models.Campaign, error) {
+	db := r.getDB(ctx)
+
+	var campaigns []*models.Campaign
+	query := db.Model(&models.Campaign{}).
+		Where("campaigns.parent_campaign_id IS NULL").
+		Where("campaigns.next_occurrence_at IS NOT NULL AND campaigns.next_occurrence_at <= ?", dueBefore).
+		Where("campaigns.status IN ?", []models.CampaignStatus{
+			models.CampaignStatusApproved,
+			models.CampaignStatusRunning,
+			models.CampaignStatusExecuted,
+		}).
+		Order("campaigns.next_occurrence_at ASC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if err := query.Find(&campaigns).Error; err != nil {
+		return nil, err
+	}
+	return campaigns, nil
+}
+
 func excludeAutomatedClickTraffic(db *gorm.DB) *gorm.DB {
 	return db.
 		Where("COALESCE(ip, '') !~ ?", "^(66\\.249\\.|74\\.125\\.)").
@@ -379,7 +445,7 @@ func (r *CampaignRepositoryImpl) AggregateClickCountsByCampaignIDs(ctx context.C
 		Clicks     int64
 	}
 	var rows []row
-	db := excludeAutomatedClickTraffic(r.getDB(ctx))
+	db := excludeAutomatedClickTraffic(r.getReadDB(ctx))
 	if err := db.Table("short_link_clicks").
 		Select("campaign_id, COUNT(DISTINCT uid) AS clicks").
 		Where("campaign_id IN ?", campaignIDs).
@@ -441,7 +507,7 @@ func (r *CampaignRepositoryImpl) AggregateClickCountsByCustomerIDs(ctx context.C
 		Clicks     int64
 	}
 	var rows []row
-	db := r.getDB(ctx)
+	db := r.getReadDB(ctx)
 	if err := db.Table("campaigns c").
 		Select("c.customer_id, COALESCE(SUM(campaign_clicks.clicks), 0) AS clicks").
 		Joins(`JOIN (
@@ -483,7 +549,7 @@ func (r *CampaignRepositoryImpl) AggregateTotalSentByCustomerIDs(ctx context.Con
 		TotalSent  uint64 `json:"total_sent"`
 	}
 
-	db := r.getDB(ctx)
+	db := r.getReadDB(ctx)
 	var rows []row
 	err := db.Table("campaigns").
 		Select(`customer_id, COALESCE(SUM(
@@ -507,6 +573,40 @@ func (r *CampaignRepositoryImpl) AggregateTotalSentByCustomerIDs(ctx context.Con
 	return results, nil
 }
 
+// TopByReferrerAgency returns up to limit campaigns of customers referred by
+// the agency, created in the date range, with the most messages sent
+func (r *CampaignRepositoryImpl) TopByReferrerAgency(ctx context.Context, agencyID uint, startDate, endDate *time.Time, limit int) ([]*AgencyCampaignAggregate, error) {
+	const totalSent = `COALESCE(
+		NULLIF(c.statistics->>'aggregatedTotalSent', '')::bigint,
+		NULLIF(c.statistics->>'totalSent', '')::bigint,
+		0
+	)`
+	rows := make([]*AgencyCampaignAggregate, 0)
+	query := r.getReadDB(ctx).
+		Table("campaigns c").
+		Select(fmt.Sprintf(`c.id AS campaign_id, c.uuid::text AS uuid, COALESCE(c.spec->>'title', '') AS title, c.status::text AS status,
+			u.id AS customer_id, u.representative_first_name AS representative_first_name,
+			u.representative_last_name AS representative_last_name, COALESCE(u.company_name, '') AS company_name,
+			%s AS total_sent, c.created_at AS created_at`, totalSent)).
+		Joins("JOIN customers u ON u.id = c.customer_id").
+		Where("u.referrer_agency_id = ?", agencyID).
+		Where(totalSent + " > 0").
+		Order("total_sent DESC, c.id DESC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if startDate != nil {
+		query = query.Where("c.created_at >= ?", *startDate)
+	}
+	if endDate != nil {
+		query = query.Where("c.created_at <= ?", *endDate)
+	}
+	if err := query.Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
 // ByFilter retrieves campaigns based on filter criteria
 func (r *CampaignRepositoryImpl) ByFilter(ctx context.Context, filter models.CampaignFilter, orderBy string, limit, offset int) ([]*models.Campaign, error) {
 	db := r.getDB(ctx)
@@ -514,7 +614,8 @@ func (r *CampaignRepositoryImpl) ByFilter(ctx context.Context, filter models.Cam
 	var campaigns []*models.Campaign
 	query := r.applyFilter(db, filter)
 
-	// Apply ordering
+	// Apply ordering; an admin list sort comes first
+	query = applyListSorts(query, filter.List)
 	if orderBy != "" {
 		query = r.applyOrder(query, orderBy)
 	}
@@ -648,6 +749,9 @@ func (r *CampaignRepositoryImpl) Exists(ctx context.Context, filter models.Campa
 
 // applyFilter applies filter conditions to the GORM query
 func (r *CampaignRepositoryImpl) applyFilter(db *gorm.DB, filter models.CampaignFilter) *gorm.DB {
+	if filter.IncludeDeleted {
+		db = db.Unscoped()
+	}
 	if filter.ID != nil {
 		db = db.Where("campaigns.id = ?", *filter.ID)
 	}
@@ -730,6 +834,19 @@ func (r *CampaignRepositoryImpl) applyFilter(db *gorm.DB, filter models.Campaign
 	if filter.Phase != nil {
 		db = db.Where("campaigns.phase = ?", *filter.Phase)
 	}
+	if filter.ParentCampaignID != nil {
+		db = db.Where("campaigns.parent_campaign_id = ?", *filter.ParentCampaignID)
+	}
+
+	return applyListConditions(db, filter.List)
+}
+
+// SoftDelete marks a campaign deleted, leaving it out of later queries
+func (r *CampaignRepositoryImpl) SoftDelete(ctx context.Context, id uint) (bool, error) {
+	return r.softDelete(ctx, id)
+}
 
-	return db
+// Restore brings back a soft-deleted campaign
+func (r *CampaignRepositoryImpl) Restore(ctx context.Context, id uint) (bool, error) {
+	return r.restore(ctx, id)
 }
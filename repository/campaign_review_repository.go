@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"gorm.io/gorm"
+)
+
+// CampaignReviewRepositoryImpl implements CampaignReviewRepository
+type CampaignReviewRepositoryImpl struct {
+	*BaseRepository[models.CampaignReview, models.CampaignReviewFilter]
+}
+
+// NewCampaignReviewRepository creates a new campaign review repository
+func NewCampaignReviewRepository(db *gorm.DB) CampaignReviewRepository {
+	return &CampaignReviewRepositoryImpl{
+		BaseRepository: NewBaseRepository[models.CampaignReview, models.CampaignReviewFilter](db),
+	}
+}
+
+// ByCampaignID returns the review history of a campaign, oldest first
+func (r *CampaignReviewRepositoryImpl) ByCampaignID(ctx context.Context, campaignID uint) ([]*models.CampaignReview, error) {
+	return r.ByFilter(ctx, models.CampaignReviewFilter{CampaignID: &campaignID}, "id ASC", 0, 0)
+}
+
+// ByFilter returns campaign reviews matching the filter
+func (r *CampaignReviewRepositoryImpl) ByFilter(ctx context.Context, filter models.CampaignReviewFilter, orderBy string, limit, offset int) ([]*models.CampaignReview, error) {
+	db := r.applyFilter(r.getDB(ctx).Model(&models.CampaignReview{}), filter)
+	if orderBy == "" {
+		orderBy = "id ASC"
+	}
+	db = db.Order(orderBy)
+	if limit > 0 {
+		db = db.Limit(limit)
+	}
+	if offset > 0 {
+		db = db.Offset(offset)
+	}
+
+	var reviews []*models.CampaignReview
+	if err := db.Find(&reviews).Error; err != nil {
+		return nil, err
+	}
+	return reviews, nil
+}
+
+// Count returns the number of campaign reviews matching the filter
+func (r *CampaignReviewRepositoryImpl) Count(ctx context.Context, filter models.CampaignReviewFilter) (int64, error) {
+	var count int64
+	if err := r.applyFilter(r.getDB(ctx).Model(&models.CampaignReview{}), filter).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// Exists checks whether any campaign review matches the filter
+func (r *CampaignReviewRepositoryImpl) Exists(ctx context.Context, filter models.CampaignReviewFilter) (bool, error) {
+	count, err := r.Count(ctx, filter)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *CampaignReviewRepositoryImpl) applyFilter(query *gorm.DB, filter models.CampaignReviewFilter) *gorm.DB {
+	if filter.ID != nil {
+		query = query.Where("id = ?", *filter.ID)
+	}
+	if filter.CampaignID != nil {
+		query = query.Where("campaign_id = ?", *filter.CampaignID)
+	}
+	if filter.AuthorType != nil {
+		query = query.Where("author_type = ?", *filter.AuthorType)
+	}
+	if filter.Action != nil {
+		query = query.Where("action = ?", *filter.Action)
+	}
+	return query
+}
@@ -30,15 +30,43 @@ func (r *CampaignStatusJobRepositoryImpl) ByID(ctx context.Context, id uint) (*m
 	return &row, nil
 }
 
-// ListDue returns jobs for one platform scheduled before or at 'now' with retry_count < 3.
+// ListDue returns pending jobs of one platform whose next run is at or
+// before 'now', longest overdue first
 func (r *CampaignStatusJobRepositoryImpl) ListDue(ctx context.Context, platform string, now time.Time, limit int) ([]*models.CampaignStatusJob, error) {
 	if limit <= 0 {
 		limit = 100
 	}
 	db := r.getDB(ctx)
 	var rows []*models.CampaignStatusJob
-	if err := db.Where("platform = ? AND scheduled_at <= ? AND retry_count < ? AND executed_at IS NULL", platform, now, 3).
-		Order("scheduled_at ASC, id ASC").
+	if err := db.Where("platform = ? AND status = ? AND next_run_at <= ?", platform, models.CampaignStatusJobPending, now).
+		Order("next_run_at ASC, id ASC").
+		Limit(limit).
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// SkipPending marks the pending jobs of a correlation ID skipped and returns
+// how many it marked
+func (r *CampaignStatusJobRepositoryImpl) SkipPending(ctx context.Context, correlationID string, now time.Time) (int64, error) {
+	res := r.getDB(ctx).Model(&models.CampaignStatusJob{}).
+		Where("correlation_id = ? AND status = ?", correlationID, models.CampaignStatusJobPending).
+		Updates(map[string]any{
+			"status":      models.CampaignStatusJobSkipped,
+			"executed_at": now,
+			"updated_at":  now,
+		})
+	return res.RowsAffected, res.Error
+}
+
+// ListByProcessedCampaign returns up to limit status jobs of a processed
+// campaign in the order their batches were sent
+func (r *CampaignStatusJobRepositoryImpl) ListByProcessedCampaign(ctx context.Context, processedCampaignID uint, limit int) ([]*models.CampaignStatusJob, error) {
+	db := r.getDB(ctx)
+	var rows []*models.CampaignStatusJob
+	if err := db.Where("processed_campaign_id = ?", processedCampaignID).
+		Order("created_at ASC, id ASC").
 		Limit(limit).
 		Find(&rows).Error; err != nil {
 		return nil, err
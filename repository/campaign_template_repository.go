@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CampaignTemplateRepositoryImpl implements CampaignTemplateRepository
+type CampaignTemplateRepositoryImpl struct {
+	*BaseRepository[models.CampaignTemplate, models.CampaignTemplateFilter]
+}
+
+// NewCampaignTemplateRepository creates a new campaign template repository
+func NewCampaignTemplateRepository(db *gorm.DB) CampaignTemplateRepository {
+	return &CampaignTemplateRepositoryImpl{
+		BaseRepository: NewBaseRepository[models.CampaignTemplate, models.CampaignTemplateFilter](db),
+	}
+}
+
+// ByUUID retrieves a template by its UUID
+func (r *CampaignTemplateRepositoryImpl) ByUUID(ctx context.Context, id uuid.UUID) (*models.CampaignTemplate, error) {
+	db := r.getDB(ctx)
+	var template models.CampaignTemplate
+	if err := db.Where("uuid = ?", id).Last(&template).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &template, nil
+}
+
+// ListVisibleToCustomer returns the customer's own templates followed by global ones
+func (r *CampaignTemplateRepositoryImpl) ListVisibleToCustomer(ctx context.Context, customerID uint) ([]*models.CampaignTemplate, error) {
+	db := r.getDB(ctx)
+	var templates []*models.CampaignTemplate
+	if err := db.Model(&models.CampaignTemplate{}).
+		Where("customer_id = ? OR customer_id IS NULL", customerID).
+		Order("customer_id IS NULL ASC, id DESC").
+		Find(&templates).Error; err != nil {
+		return nil, err
+	}
+	return templates, nil
+}
+
+// Delete removes a template by ID
+func (r *CampaignTemplateRepositoryImpl) Delete(ctx context.Context, id uint) error {
+	db, shouldCommit, err := r.getDBForWrite(ctx)
+	if err != nil {
+		return err
+	}
+	if shouldCommit {
+		defer func() {
+			if err != nil {
+				db.Rollback()
+			} else {
+				db.Commit()
+			}
+		}()
+	}
+
+	result := db.Delete(&models.CampaignTemplate{}, id)
+	if err = result.Error; err != nil {
+		return err
+	}
+	if result.RowsAffected == 0 {
+		err = gorm.ErrRecordNotFound
+		return err
+	}
+	return nil
+}
+
+// ByFilter returns templates matching the filter
+func (r *CampaignTemplateRepositoryImpl) ByFilter(ctx context.Context, filter models.CampaignTemplateFilter, orderBy string, limit, offset int) ([]*models.CampaignTemplate, error) {
+	db := r.applyFilter(r.getDB(ctx).Model(&models.CampaignTemplate{}), filter)
+	if orderBy == "" {
+		orderBy = "id DESC"
+	}
+	db = db.Order(orderBy)
+	if limit > 0 {
+		db = db.Limit(limit)
+	}
+	if offset > 0 {
+		db = db.Offset(offset)
+	}
+
+	var templates []*models.CampaignTemplate
+	if err := db.Find(&templates).Error; err != nil {
+		return nil, err
+	}
+	return templates, nil
+}
+
+// Count returns the number of templates matching the filter
+func (r *CampaignTemplateRepositoryImpl) Count(ctx context.Context, filter models.CampaignTemplateFilter) (int64, error) {
+	var count int64
+	if err := r.applyFilter(r.getDB(ctx).Model(&models.CampaignTemplate{}), filter).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// Exists checks whether any template matches the filter
+func (r *CampaignTemplateRepositoryImpl) Exists(ctx context.Context, filter models.CampaignTemplateFilter) (bool, error) {
+	count, err := r.Count(ctx, filter)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *CampaignTemplateRepositoryImpl) applyFilter(query *gorm.DB, filter models.CampaignTemplateFilter) *gorm.DB {
+	if filter.ID != nil {
+		query = query.Where("id = ?", *filter.ID)
+	}
+	if filter.UUID != nil {
+		query = query.Where("uuid = ?", *filter.UUID)
+	}
+	if filter.CustomerID != nil {
+		query = query.Where("customer_id = ?", *filter.CustomerID)
+	}
+	if filter.Global != nil {
+		if *filter.Global {
+			query = query.Where("customer_id IS NULL")
+		} else {
+			query = query.Where("customer_id IS NOT NULL")
+		}
+	}
+	if filter.Name != nil {
+		query = query.Where("lower(name) = lower(?)", *filter.Name)
+	}
+	return query
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CryptoWebhookEventRepositoryImpl implements CryptoWebhookEventRepository
+type CryptoWebhookEventRepositoryImpl struct {
+	*BaseRepository[models.CryptoWebhookEvent, models.CryptoWebhookEventFilter]
+}
+
+// NewCryptoWebhookEventRepository creates a new crypto webhook event repository
+func NewCryptoWebhookEventRepository(db *gorm.DB) CryptoWebhookEventRepository {
+	return &CryptoWebhookEventRepositoryImpl{
+		BaseRepository: NewBaseRepository[models.CryptoWebhookEvent, models.CryptoWebhookEventFilter](db),
+	}
+}
+
+// Record inserts an event unless one with the same platform and event key
+// exists, and reports whether it was inserted. A concurrent insert of the
+// same event waits for the other transaction and is reported as existing
+// once it commits.
+func (r *CryptoWebhookEventRepositoryImpl) Record(ctx context.Context, event *models.CryptoWebhookEvent) (bool, error) {
+	res := r.getDB(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "platform"}, {Name: "event_key"}},
+		DoNothing: true,
+	}).Create(event)
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
+
+// ByFilter returns webhook events matching the filter
+func (r *CryptoWebhookEventRepositoryImpl) ByFilter(ctx context.Context, filter models.CryptoWebhookEventFilter, orderBy string, limit, offset int) ([]*models.CryptoWebhookEvent, error) {
+	db := r.applyFilter(r.getDB(ctx).Model(&models.CryptoWebhookEvent{}), filter)
+	if orderBy == "" {
+		orderBy = "id DESC"
+	}
+	db = db.Order(orderBy)
+	if limit > 0 {
+		db = db.Limit(limit)
+	}
+	if offset > 0 {
+		db = db.Offset(offset)
+	}
+
+	var events []*models.CryptoWebhookEvent
+	if err := db.Find(&events).Error; err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// Count returns the number of webhook events matching the filter
+func (r *CryptoWebhookEventRepositoryImpl) Count(ctx context.Context, filter models.CryptoWebhookEventFilter) (int64, error) {
+	var count int64
+	if err := r.applyFilter(r.getDB(ctx).Model(&models.CryptoWebhookEvent{}), filter).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// Exists checks whether any webhook event matches the filter
+func (r *CryptoWebhookEventRepositoryImpl) Exists(ctx context.Context, filter models.CryptoWebhookEventFilter) (bool, error) {
+	count, err := r.Count(ctx, filter)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *CryptoWebhookEventRepositoryImpl) applyFilter(query *gorm.DB, filter models.CryptoWebhookEventFilter) *gorm.DB {
+	if filter.ID != nil {
+		query = query.Where("id = ?", *filter.ID)
+	}
+	if filter.Platform != nil {
+		query = query.Where("platform = ?", *filter.Platform)
+	}
+	if filter.EventKey != nil {
+		query = query.Where("event_key = ?", *filter.EventKey)
+	}
+	if filter.TrackID != nil {
+		query = query.Where("track_id = ?", *filter.TrackID)
+	}
+	return query
+}
@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Categories of customer activity
+const (
+	CustomerActivityAudit    = "audit"
+	CustomerActivitySession  = "session"
+	CustomerActivityPayment  = "payment"
+	CustomerActivityCampaign = "campaign"
+)
+
+// CustomerActivity is one entry of a customer's activity timeline. Category
+// and SourceID name the row it was read from.
+type CustomerActivity struct {
+	Category   string          `gorm:"column:category"`
+	SourceID   uint            `gorm:"column:source_id"`
+	OccurredAt time.Time       `gorm:"column:occurred_at"`
+	Type       string          `gorm:"column:type"`
+	Summary    string          `gorm:"column:summary"`
+	Success    *bool           `gorm:"column:success"`
+	Details    json.RawMessage `gorm:"column:details"`
+}
+
+// CustomerActivityCursor is the position of the last activity of a page.
+// Activities are ordered by OccurredAt, Category and SourceID, all descending.
+type CustomerActivityCursor struct {
+	OccurredAt time.Time
+	Category   string
+	SourceID   uint
+}
+
+// CustomerActivityQuery selects a page of a customer's activities
+type CustomerActivityQuery struct {
+	CustomerID uint
+	// Categories limits the timeline to some categories; empty means all
+	Categories []string
+	// After continues the timeline after a previous page
+	After *CustomerActivityCursor
+	Limit int
+}
+
+// customerActivitySources are the per category SELECTs of the timeline. Each
+// selects the shared columns of one table for the customer in its first
+// argument.
+var customerActivitySources = []struct {
+	category string
+	sql      string
+}{
+	{CustomerActivitySession, `SELECT 'session'::text AS category, id AS source_id, created_at AS occurred_at,
+		CASE WHEN is_active THEN 'session_started' ELSE 'session_ended' END AS type,
+		COALESCE(user_agent, '') AS summary, NULL::boolean AS success,
+		jsonb_build_object('correlation_id', correlation_id, 'ip_address', host(ip_address),
+			'last_accessed_at', last_accessed_at, 'expires_at', expires_at) AS details
+		FROM customer_sessions WHERE customer_id = ?`},
+	{CustomerActivityPayment, `SELECT 'payment'::text AS category, id AS source_id, created_at AS occurred_at,
+		'payment_' || status AS type, description AS summary,
+		CASE WHEN status = 'completed' THEN TRUE WHEN status IN ('failed', 'cancelled', 'expired') THEN FALSE END AS success,
+		jsonb_build_object('uuid', uuid, 'amount', amount, 'currency', currency, 'status', status,
+			'status_reason', status_reason, 'updated_at', updated_at) AS details
+		FROM payment_requests WHERE customer_id = ?`},
+	{CustomerActivityCampaign, `SELECT 'campaign'::text AS category, id AS source_id, created_at AS occurred_at,
+		'campaign_' || status::text AS type, COALESCE(spec->>'title', '') AS summary, NULL::boolean AS success,
+		jsonb_build_object('uuid', uuid, 'status', status, 'platform', spec->>'platform',
+			'updated_at', updated_at, 'deleted_at', deleted_at) AS details
+		FROM campaigns WHERE customer_id = ?`},
+	{CustomerActivityAudit, `SELECT 'audit'::text AS category, id AS source_id, created_at AS occurred_at,
+		action::text AS type, COALESCE(description, '') AS summary, success,
+		jsonb_build_object('ip_address', host(ip_address), 'request_id', request_id,
+			'error', error_message, 'metadata', metadata) AS details
+		FROM audit_log WHERE customer_id = ?`},
+}
+
+// NewCustomerActivityRepository creates a repository reading the activity
+// timeline of customers
+func NewCustomerActivityRepository(db *gorm.DB) CustomerActivityRepository {
+	return &customerActivityRepository{db: db}
+}
+
+type customerActivityRepository struct {
+	db *gorm.DB
+}
+
+func (r *customerActivityRepository) getDB(ctx context.Context) *gorm.DB {
+	if tx, ok := ctx.Value(TxContextKey).(*gorm.DB); ok && tx != nil {
+		return tx
+	}
+	return r.db.WithContext(ctx)
+}
+
+// ListActivities returns up to q.Limit activities of a customer, newest
+// first. It is one UNION ALL over the selected tables. Every branch applies
+// the cursor and the limit on its own customer_id and created_at indexes, so
+// a page reads at most Limit rows per category however long the history is.
+func (r *customerActivityRepository) ListActivities(ctx context.Context, q CustomerActivityQuery) ([]*CustomerActivity, error) {
+	wanted := make(map[string]bool, len(q.Categories))
+	for _, c := range q.Categories {
+		wanted[c] = true
+	}
+
+	var branches []string
+	var args []any
+	for _, src := range customerActivitySources {
+		if len(wanted) > 0 && !wanted[src.category] {
+			continue
+		}
+		branch := src.sql
+		branchArgs := []any{q.CustomerID}
+		if q.After != nil {
+			cond, condArgs := customerActivityCursorCondition(src.category, *q.After)
+			branch += " AND " + cond
+			branchArgs = append(branchArgs, condArgs...)
+		}
+		branches = append(branches, "("+branch+" ORDER BY created_at DESC, id DESC LIMIT ?)")
+		args = append(args, append(branchArgs, q.Limit)...)
+	}
+	if len(branches) == 0 {
+		return []*CustomerActivity{}, nil
+	}
+
+	query := strings.Join(branches, "\nUNION ALL\n") +
+		"\nORDER BY occurred_at DESC, category DESC, source_id DESC LIMIT ?"
+	args = append(args, q.Limit)
+
+	var rows []*CustomerActivity
+	if err := r.getDB(ctx).Raw(query, args...).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// customerActivityCursorCondition keeps the rows of one category that sort
+// after the cursor. Within the cursor's instant, categories sorting above the
+// cursor's were on earlier pages, and its own category continues by ID.
+func customerActivityCursorCondition(category string, after CustomerActivityCursor) (string, []any) {
+	switch {
+	case category > after.Category:
+		return "created_at < ?", []any{after.OccurredAt}
+	case category < after.Category:
+		return "created_at <= ?", []any{after.OccurredAt}
+	default:
+		return "(created_at, id) < (?, ?)", []any{after.OccurredAt, after.SourceID}
+	}
+}
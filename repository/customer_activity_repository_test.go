@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCustomerActivityCursorCondition(t *testing.T) {
+	t.Parallel()
+
+	at := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	after := CustomerActivityCursor{OccurredAt: at, Category: CustomerActivityPayment, SourceID: 42}
+	cases := []struct {
+		category string
+		want     string
+		args     int
+	}{
+		// session sorts above payment, so its rows at the cursor's instant were on the page
+		{category: CustomerActivitySession, want: "created_at < ?", args: 1},
+		{category: CustomerActivityPayment, want: "(created_at, id) < (?, ?)", args: 2},
+		{category: CustomerActivityCampaign, want: "created_at <= ?", args: 1},
+		{category: CustomerActivityAudit, want: "created_at <= ?", args: 1},
+	}
+	for _, tc := range cases {
+		got, args := customerActivityCursorCondition(tc.category, after)
+		if got != tc.want || len(args) != tc.args {
+			t.Fatalf("%s: got %q with %d args, want %q with %d", tc.category, got, len(args), tc.want, tc.args)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CustomerCreditLineRepositoryImpl implements CustomerCreditLineRepository
+type CustomerCreditLineRepositoryImpl struct {
+	*BaseRepository[models.CustomerCreditLine, models.CustomerCreditLineFilter]
+}
+
+// NewCustomerCreditLineRepository creates a new customer credit line repository
+func NewCustomerCreditLineRepository(db *gorm.DB) CustomerCreditLineRepository {
+	return &CustomerCreditLineRepositoryImpl{
+		BaseRepository: NewBaseRepository[models.CustomerCreditLine, models.CustomerCreditLineFilter](db),
+	}
+}
+
+// ByCustomerID returns the credit line of a customer, or nil when none is configured
+func (r *CustomerCreditLineRepositoryImpl) ByCustomerID(ctx context.Context, customerID uint) (*models.CustomerCreditLine, error) {
+	var line models.CustomerCreditLine
+	err := r.getDB(ctx).Where("customer_id = ?", customerID).First(&line).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &line, nil
+}
+
+// UpsertLimit creates the credit line of a customer or replaces its limit,
+// keeping what is outstanding
+func (r *CustomerCreditLineRepositoryImpl) UpsertLimit(ctx context.Context, line *models.CustomerCreditLine) error {
+	return r.getDB(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "customer_id"}},
+		DoUpdates: clause.Assignments(map[string]any{
+			"credit_limit": clause.Expr{SQL: "EXCLUDED.credit_limit"},
+			"admin_id":     clause.Expr{SQL: "EXCLUDED.admin_id"},
+			"updated_at":   clause.Expr{SQL: "EXCLUDED.updated_at"},
+		}),
+	}).Create(line).Error
+}
+
+// Draw adds amount to what the customer owes if it stays within the limit,
+// and reports false otherwise. The check and the increment are a single
+// statement so concurrent draws cannot exceed the limit.
+func (r *CustomerCreditLineRepositoryImpl) Draw(ctx context.Context, customerID uint, amount uint64) (bool, error) {
+	res := r.getDB(ctx).Model(&models.CustomerCreditLine{}).
+		Where("customer_id = ? AND outstanding + ? <= credit_limit", customerID, amount).
+		Updates(map[string]any{
+			"outstanding": gorm.Expr("outstanding + ?", amount),
+			"updated_at":  time.Now().UTC(),
+		})
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
+
+// Settle deducts a paid amount from what the customer owes
+func (r *CustomerCreditLineRepositoryImpl) Settle(ctx context.Context, customerID uint, amount uint64) error {
+	return r.getDB(ctx).Model(&models.CustomerCreditLine{}).
+		Where("customer_id = ?", customerID).
+		Updates(map[string]any{
+			"outstanding": gorm.Expr("GREATEST(outstanding - ?, 0)", amount),
+			"updated_at":  time.Now().UTC(),
+		}).Error
+}
+
+// ByFilter returns credit lines matching the filter
+func (r *CustomerCreditLineRepositoryImpl) ByFilter(ctx context.Context, filter models.CustomerCreditLineFilter, orderBy string, limit, offset int) ([]*models.CustomerCreditLine, error) {
+	db := r.applyFilter(r.getDB(ctx).Model(&models.CustomerCreditLine{}), filter)
+	if orderBy == "" {
+		orderBy = "id DESC"
+	}
+	db = db.Order(orderBy)
+	if limit > 0 {
+		db = db.Limit(limit)
+	}
+	if offset > 0 {
+		db = db.Offset(offset)
+	}
+
+	var items []*models.CustomerCreditLine
+	if err := db.Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// Count returns the number of credit lines matching the filter
+func (r *CustomerCreditLineRepositoryImpl) Count(ctx context.Context, filter models.CustomerCreditLineFilter) (int64, error) {
+	var count int64
+	if err := r.applyFilter(r.getDB(ctx).Model(&models.CustomerCreditLine{}), filter).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// Exists checks whether any credit line matches the filter
+func (r *CustomerCreditLineRepositoryImpl) Exists(ctx context.Context, filter models.CustomerCreditLineFilter) (bool, error) {
+	count, err := r.Count(ctx, filter)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *CustomerCreditLineRepositoryImpl) applyFilter(query *gorm.DB, filter models.CustomerCreditLineFilter) *gorm.DB {
+	if filter.ID != nil {
+		query = query.Where("id = ?", *filter.ID)
+	}
+	if filter.CustomerID != nil {
+		query = query.Where("customer_id = ?", *filter.CustomerID)
+	}
+	return query
+}
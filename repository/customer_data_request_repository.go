@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CustomerDataRequestRepositoryImpl implements CustomerDataRequestRepository
+type CustomerDataRequestRepositoryImpl struct {
+	*BaseRepository[models.CustomerDataRequest, models.CustomerDataRequestFilter]
+}
+
+// NewCustomerDataRequestRepository creates a new customer data request repository
+func NewCustomerDataRequestRepository(db *gorm.DB) CustomerDataRequestRepository {
+	return &CustomerDataRequestRepositoryImpl{
+		BaseRepository: NewBaseRepository[models.CustomerDataRequest, models.CustomerDataRequestFilter](db),
+	}
+}
+
+// ByUUID retrieves a data request by its UUID
+func (r *CustomerDataRequestRepositoryImpl) ByUUID(ctx context.Context, id uuid.UUID) (*models.CustomerDataRequest, error) {
+	db := r.getDB(ctx)
+	var request models.CustomerDataRequest
+	if err := db.Where("uuid = ?", id).Last(&request).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &request, nil
+}
+
+// ClaimNext marks the oldest pending export, scheduled deletion whose grace
+// period ended before now, or processing request whose worker stopped
+// updating it before staleBefore, as processing and returns it. Concurrent
+// workers never claim the same request. Returns nil when there is nothing to do.
+func (r *CustomerDataRequestRepositoryImpl) ClaimNext(ctx context.Context, now, staleBefore time.Time) (*models.CustomerDataRequest, error) {
+	db := r.getDB(ctx)
+	var requests []*models.CustomerDataRequest
+	err := db.Raw(`
+		UPDATE customer_data_requests
+		SET status = ?, started_at = COALESCE(started_at, ?), updated_at = ?
+		WHERE id = (
+			SELECT id FROM customer_data_requests
+			WHERE status = ?
+				OR (status = ? AND scheduled_for <= ?)
+				OR (status = ? AND updated_at < ?)
+			ORDER BY id
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`,
+		models.CustomerDataRequestStatusProcessing, now, now,
+		models.CustomerDataRequestStatusPending,
+		models.CustomerDataRequestStatusScheduled, now,
+		models.CustomerDataRequestStatusProcessing, staleBefore,
+	).Scan(&requests).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(requests) == 0 {
+		return nil, nil
+	}
+	return requests[0], nil
+}
+
+// ExpiredExports returns completed exports whose file is still on disk after
+// their download window closed
+func (r *CustomerDataRequestRepositoryImpl) ExpiredExports(ctx context.Context, now time.Time, limit int) ([]*models.CustomerDataRequest, error) {
+	db := r.getDB(ctx).
+		Where("type = ? AND status = ?", models.CustomerDataRequestTypeExport, models.CustomerDataRequestStatusCompleted).
+		Where("file_path IS NOT NULL AND expires_at <= ?", now).
+		Order("id")
+	if limit > 0 {
+		db = db.Limit(limit)
+	}
+
+	var requests []*models.CustomerDataRequest
+	if err := db.Find(&requests).Error; err != nil {
+		return nil, err
+	}
+	return requests, nil
+}
+
+// Update persists the request's status, file and timestamps
+func (r *CustomerDataRequestRepositoryImpl) Update(ctx context.Context, request *models.CustomerDataRequest) error {
+	db := r.getDB(ctx)
+	request.UpdatedAt = utils.UTCNow()
+	return db.Save(request).Error
+}
+
+// ByFilter returns data requests matching the filter
+func (r *CustomerDataRequestRepositoryImpl) ByFilter(ctx context.Context, filter models.CustomerDataRequestFilter, orderBy string, limit, offset int) ([]*models.CustomerDataRequest, error) {
+	db := r.applyFilter(r.getDB(ctx).Model(&models.CustomerDataRequest{}), filter)
+	if orderBy == "" {
+		orderBy = "id DESC"
+	}
+	db = db.Order(orderBy)
+	if limit > 0 {
+		db = db.Limit(limit)
+	}
+	if offset > 0 {
+		db = db.Offset(offset)
+	}
+
+	var requests []*models.CustomerDataRequest
+	if err := db.Find(&requests).Error; err != nil {
+		return nil, err
+	}
+	return requests, nil
+}
+
+// Count returns the number of data requests matching the filter
+func (r *CustomerDataRequestRepositoryImpl) Count(ctx context.Context, filter models.CustomerDataRequestFilter) (int64, error) {
+	var count int64
+	if err := r.applyFilter(r.getDB(ctx).Model(&models.CustomerDataRequest{}), filter).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// Exists checks whether any data request matches the filter
+func (r *CustomerDataRequestRepositoryImpl) Exists(ctx context.Context, filter models.CustomerDataRequestFilter) (bool, error) {
+	count, err := r.Count(ctx, filter)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *CustomerDataRequestRepositoryImpl) applyFilter(query *gorm.DB, filter models.CustomerDataRequestFilter) *gorm.DB {
+	if filter.ID != nil {
+		query = query.Where("id = ?", *filter.ID)
+	}
+	if filter.UUID != nil {
+		query = query.Where("uuid = ?", *filter.UUID)
+	}
+	if filter.CustomerID != nil {
+		query = query.Where("customer_id = ?", *filter.CustomerID)
+	}
+	if filter.Type != nil {
+		query = query.Where("type = ?", *filter.Type)
+	}
+	if len(filter.Statuses) > 0 {
+		query = query.Where("status IN ?", filter.Statuses)
+	}
+	return query
+}
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CustomerKnownDeviceRepositoryImpl implements CustomerKnownDeviceRepository
+type CustomerKnownDeviceRepositoryImpl struct {
+	*BaseRepository[models.CustomerKnownDevice, models.CustomerKnownDeviceFilter]
+}
+
+// NewCustomerKnownDeviceRepository creates a new customer known device repository
+func NewCustomerKnownDeviceRepository(db *gorm.DB) CustomerKnownDeviceRepository {
+	return &CustomerKnownDeviceRepositoryImpl{
+		BaseRepository: NewBaseRepository[models.CustomerKnownDevice, models.CustomerKnownDeviceFilter](db),
+	}
+}
+
+// ByCustomerID returns the known devices of a customer
+func (r *CustomerKnownDeviceRepositoryImpl) ByCustomerID(ctx context.Context, customerID uint) ([]*models.CustomerKnownDevice, error) {
+	return r.ByFilter(ctx, models.CustomerKnownDeviceFilter{CustomerID: &customerID}, "", 0, 0)
+}
+
+// Upsert records a device, or refreshes when and from which country a known
+// one was last seen
+func (r *CustomerKnownDeviceRepositoryImpl) Upsert(ctx context.Context, device *models.CustomerKnownDevice) error {
+	return r.getDB(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "customer_id"}, {Name: "fingerprint"}, {Name: "ip_range"}},
+		DoUpdates: clause.Assignments(map[string]any{
+			"country":      clause.Expr{SQL: "COALESCE(EXCLUDED.country, customer_known_devices.country)"},
+			"user_agent":   clause.Expr{SQL: "EXCLUDED.user_agent"},
+			"last_seen_at": clause.Expr{SQL: "EXCLUDED.last_seen_at"},
+		}),
+	}).Create(device).Error
+}
+
+// Forget removes a device so logging in from it is reported again
+func (r *CustomerKnownDeviceRepositoryImpl) Forget(ctx context.Context, customerID uint, fingerprint, ipRange string) error {
+	return r.getDB(ctx).
+		Where("customer_id = ? AND fingerprint = ? AND ip_range = ?", customerID, fingerprint, ipRange).
+		Delete(&models.CustomerKnownDevice{}).Error
+}
+
+// ForgetAll deletes every known device of a customer
+func (r *CustomerKnownDeviceRepositoryImpl) ForgetAll(ctx context.Context, customerID uint) error {
+	return r.getDB(ctx).
+		Where("customer_id = ?", customerID).
+		Delete(&models.CustomerKnownDevice{}).Error
+}
+
+// ByFilter returns known devices matching the filter
+func (r *CustomerKnownDeviceRepositoryImpl) ByFilter(ctx context.Context, filter models.CustomerKnownDeviceFilter, orderBy string, limit, offset int) ([]*models.CustomerKnownDevice, error) {
+	db := r.applyFilter(r.getDB(ctx).Model(&models.CustomerKnownDevice{}), filter)
+	if orderBy == "" {
+		orderBy = "id DESC"
+	}
+	db = db.Order(orderBy)
+	if limit > 0 {
+		db = db.Limit(limit)
+	}
+	if offset > 0 {
+		db = db.Offset(offset)
+	}
+
+	var devices []*models.CustomerKnownDevice
+	if err := db.Find(&devices).Error; err != nil {
+		return nil, err
+	}
+	return devices, nil
+}
+
+// Count returns the number of known devices matching the filter
+func (r *CustomerKnownDeviceRepositoryImpl) Count(ctx context.Context, filter models.CustomerKnownDeviceFilter) (int64, error) {
+	var count int64
+	if err := r.applyFilter(r.getDB(ctx).Model(&models.CustomerKnownDevice{}), filter).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// Exists checks whether any known device matches the filter
+func (r *CustomerKnownDeviceRepositoryImpl) Exists(ctx context.Context, filter models.CustomerKnownDeviceFilter) (bool, error) {
+	count, err := r.Count(ctx, filter)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *CustomerKnownDeviceRepositoryImpl) applyFilter(query *gorm.DB, filter models.CustomerKnownDeviceFilter) *gorm.DB {
+	if filter.ID != nil {
+		query = query.Where("id = ?", *filter.ID)
+	}
+	if filter.CustomerID != nil {
+		query = query.Where("customer_id = ?", *filter.CustomerID)
+	}
+	if filter.Fingerprint != nil {
+		query = query.Where("fingerprint = ?", *filter.Fingerprint)
+	}
+	if filter.IPRange != nil {
+		query = query.Where("ip_range = ?", *filter.IPRange)
+	}
+	return query
+}
@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CustomerProfileChangeRepositoryImpl implements CustomerProfileChangeRepository
+type CustomerProfileChangeRepositoryImpl struct {
+	*BaseRepository[models.CustomerProfileChange, models.CustomerProfileChangeFilter]
+}
+
+// NewCustomerProfileChangeRepository creates a new profile change repository
+func NewCustomerProfileChangeRepository(db *gorm.DB) CustomerProfileChangeRepository {
+	return &CustomerProfileChangeRepositoryImpl{
+		BaseRepository: NewBaseRepository[models.CustomerProfileChange, models.CustomerProfileChangeFilter](db),
+	}
+}
+
+// ByUUID returns a profile change, or nil when there is none
+func (r *CustomerProfileChangeRepositoryImpl) ByUUID(ctx context.Context, id uuid.UUID) (*models.CustomerProfileChange, error) {
+	var change models.CustomerProfileChange
+	err := r.getDB(ctx).Where("uuid = ?", id).First(&change).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &change, nil
+}
+
+// SupersedePending marks the customer's change of field waiting for review as
+// superseded and returns how many rows changed
+func (r *CustomerProfileChangeRepositoryImpl) SupersedePending(ctx context.Context, customerID uint, field models.ProfileField) (int64, error) {
+	res := r.getDB(ctx).Model(&models.CustomerProfileChange{}).
+		Where("customer_id = ? AND field = ? AND status = ?", customerID, field, models.ProfileChangePendingReview).
+		Update("status", models.ProfileChangeSuperseded)
+	return res.RowsAffected, res.Error
+}
+
+// Review moves a change waiting for review to applied or rejected. It
+// reports false when the change was no longer waiting, so concurrent reviews
+// cannot both succeed.
+func (r *CustomerProfileChangeRepositoryImpl) Review(ctx context.Context, id uint, to models.ProfileChangeStatus, reviewerID *uint, reason *string, at time.Time) (bool, error) {
+	res := r.getDB(ctx).Model(&models.CustomerProfileChange{}).
+		Where("id = ? AND status = ?", id, models.ProfileChangePendingReview).
+		Updates(map[string]any{
+			"status":           to,
+			"reviewer_id":      reviewerID,
+			"reviewed_at":      at,
+			"rejection_reason": reason,
+		})
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected == 1, nil
+}
+
+// AnonymizeByCustomer erases the old and new values of the customer's
+// changes; the rows stay as the record of what changed and when
+func (r *CustomerProfileChangeRepositoryImpl) AnonymizeByCustomer(ctx context.Context, customerID uint) error {
+	return r.getDB(ctx).Model(&models.CustomerProfileChange{}).
+		Where("customer_id = ?", customerID).
+		Updates(map[string]any{"old_value": nil, "new_value": nil}).Error
+}
+
+// ByFilter returns profile changes matching the filter
+func (r *CustomerProfileChangeRepositoryImpl) ByFilter(ctx context.Context, filter models.CustomerProfileChangeFilter, orderBy string, limit, offset int) ([]*models.CustomerProfileChange, error) {
+	db := r.applyFilter(r.getDB(ctx).Model(&models.CustomerProfileChange{}), filter)
+	if orderBy == "" {
+		orderBy = "id DESC"
+	}
+	db = db.Order(orderBy)
+	if limit > 0 {
+		db = db.Limit(limit)
+	}
+	if offset > 0 {
+		db = db.Offset(offset)
+	}
+
+	var changes []*models.CustomerProfileChange
+	if err := db.Find(&changes).Error; err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// Count returns the number of profile changes matching the filter
+func (r *CustomerProfileChangeRepositoryImpl) Count(ctx context.Context, filter models.CustomerProfileChangeFilter) (int64, error) {
+	var count int64
+	if err := r.applyFilter(r.getDB(ctx).Model(&models.CustomerProfileChange{}), filter).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// Exists checks whether any profile change matches the filter
+func (r *CustomerProfileChangeRepositoryImpl) Exists(ctx context.Context, filter models.CustomerProfileChangeFilter) (bool, error) {
+	count, err := r.Count(ctx, filter)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *CustomerProfileChangeRepositoryImpl) applyFilter(query *gorm.DB, filter models.CustomerProfileChangeFilter) *gorm.DB {
+	if filter.ID != nil {
+		query = query.Where("id = ?", *filter.ID)
+	}
+	if filter.UUID != nil {
+		query = query.Where("uuid = ?", *filter.UUID)
+	}
+	if filter.CustomerID != nil {
+		query = query.Where("customer_id = ?", *filter.CustomerID)
+	}
+	if filter.Field != nil {
+		query = query.Where("field = ?", *filter.Field)
+	}
+	if filter.Status != nil {
+		query = query.Where("status = ?", *filter.Status)
+	}
+	return query
+}
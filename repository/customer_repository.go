@@ -4,11 +4,15 @@ package repository
 import (
 	"context"
 	"errors"
+	"fmt"
 	"strconv"
+	"strings"
 	"time"
+	"unicode"
 
 	"github.com/amirphl/Yamata-no-Orochi/models"
 	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/amirphl/Yamata-no-Orochi/utils/phonenumber"
 	"gorm.io/gorm"
 )
 
@@ -58,8 +62,19 @@ func (r *CustomerRepositoryImpl) ByEmail(ctx context.Context, email string) (*mo
 	return customers[0], nil
 }
 
-// ByMobile retrieves a customer by mobile number
+// Save inserts a customer, storing the representative mobile in canonical E.164 form
+func (r *CustomerRepositoryImpl) Save(ctx context.Context, customer *models.Customer) error {
+	mobile, err := phonenumber.Canonical(customer.RepresentativeMobile)
+	if err != nil {
+		return fmt.Errorf("representative mobile %q: %w", customer.RepresentativeMobile, err)
+	}
+	customer.RepresentativeMobile = mobile
+	return r.BaseRepository.Save(ctx, customer)
+}
+
+// ByMobile retrieves a customer by mobile number given in any form
 func (r *CustomerRepositoryImpl) ByMobile(ctx context.Context, mobile string) (*models.Customer, error) {
+	mobile = phonenumber.CanonicalOrRaw(mobile)
 	filter := models.CustomerFilter{RepresentativeMobile: &mobile}
 	customers, err := r.ByFilter(ctx, filter, "", 0, 0)
 	if err != nil {
@@ -208,6 +223,10 @@ func (r *CustomerRepositoryImpl) applyFilter(query *gorm.DB, filter models.Custo
 		query = query.Where("is_active = ?", *filter.IsActive)
 	}
 
+	if filter.KYCStatus != nil {
+		query = query.Where("kyc_status = ?", *filter.KYCStatus)
+	}
+
 	if filter.CreatedAfter != nil {
 		query = query.Where("created_at > ?", *filter.CreatedAfter)
 	}
@@ -230,7 +249,7 @@ func (r *CustomerRepositoryImpl) applyFilter(query *gorm.DB, filter models.Custo
 			Where("account_types.type_name = ?", *filter.AccountTypeName)
 	}
 
-	return query
+	return applyListConditions(query, filter.List)
 }
 
 // ByFilter retrieves customers based on filter criteria
@@ -241,11 +260,11 @@ func (r *CustomerRepositoryImpl) ByFilter(ctx context.Context, filter models.Cus
 	// Apply filters
 	query = r.applyFilter(query, filter)
 
-	// Apply ordering (default to id DESC)
+	// Apply ordering (default to id DESC); an admin list sort comes first
 	if orderBy == "" {
 		orderBy = "id DESC"
 	}
-	query = query.Order(orderBy)
+	query = applyListSorts(query, filter.List).Order(orderBy)
 
 	// Apply pagination
 	if limit > 0 {
@@ -326,6 +345,68 @@ func (r *CustomerRepositoryImpl) UpdatePassword(ctx context.Context, customerID
 	return nil
 }
 
+// UpdateRepresentativeMobile replaces the representative mobile with one the
+// customer verified at verifiedAt
+func (r *CustomerRepositoryImpl) UpdateRepresentativeMobile(ctx context.Context, customerID uint, mobile string, verifiedAt time.Time) error {
+	canonical, err := phonenumber.Canonical(mobile)
+	if err != nil {
+		return fmt.Errorf("representative mobile %q: %w", mobile, err)
+	}
+	res := r.getDB(ctx).Model(&models.Customer{}).
+		Where("id = ?", customerID).
+		Updates(map[string]any{
+			"representative_mobile": canonical,
+			"is_mobile_verified":    true,
+			"mobile_verified_at":    verifiedAt,
+			"updated_at":            utils.UTCNow(),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return errors.New("customer not found with ID: " + strconv.Itoa(int(customerID)))
+	}
+	return nil
+}
+
+// UpdateProfileFields sets profile fields of a customer; a nil value clears
+// the field
+func (r *CustomerRepositoryImpl) UpdateProfileFields(ctx context.Context, customerID uint, values map[models.ProfileField]*string) error {
+	if len(values) == 0 {
+		return nil
+	}
+	updates := make(map[string]any, len(values)+1)
+	for field, value := range values {
+		updates[string(field)] = value
+	}
+	updates["updated_at"] = utils.UTCNow()
+	res := r.getDB(ctx).Model(&models.Customer{}).
+		Where("id = ?", customerID).
+		Updates(updates)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return errors.New("customer not found with ID: " + strconv.Itoa(int(customerID)))
+	}
+	return nil
+}
+
+// SaveShebaVerification stores the bank inquiry result of sheba. It does
+// nothing when the customer's Sheba number changed meanwhile, so a result is
+// never recorded against a number it was not looked up for.
+func (r *CustomerRepositoryImpl) SaveShebaVerification(ctx context.Context, customerID uint, sheba string, status models.ShebaVerificationStatus, ownerName *string, at time.Time) error {
+	return r.getDB(ctx).Model(&models.Customer{}).
+		Where("id = ? AND sheba_number = ?", customerID, sheba).
+		Updates(map[string]any{
+			"sheba_verification":    status,
+			"sheba_verified_number": sheba,
+			"sheba_owner_name":      ownerName,
+			"sheba_verified_at":     at,
+			"updated_at":            utils.UTCNow(),
+		}).Error
+}
+
 // UpdateVerificationStatus updates verification fields for an existing customer
 // This is a special case that allows updating verification status while maintaining referential integrity
 func (r *CustomerRepositoryImpl) UpdateVerificationStatus(ctx context.Context, customerID uint, isMobileVerified, isEmailVerified *bool, mobileVerifiedAt, emailVerifiedAt *time.Time) error {
@@ -381,6 +462,100 @@ func (r *CustomerRepositoryImpl) UpdateVerificationStatus(ctx context.Context, c
 	return nil
 }
 
+// SetPasswordResetRequired sets whether a customer must reset their password
+// before logging in again
+func (r *CustomerRepositoryImpl) SetPasswordResetRequired(ctx context.Context, customerID uint, required bool) error {
+	res := r.getDB(ctx).Model(&models.Customer{}).
+		Where("id = ?", customerID).
+		Updates(map[string]any{
+			"password_reset_required": required,
+			"updated_at":              utils.UTCNow(),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return errors.New("customer not found with ID: " + strconv.Itoa(int(customerID)))
+	}
+	return nil
+}
+
+// UpdatePreferredLocale sets the locale messages are sent to the customer in; nil clears it
+func (r *CustomerRepositoryImpl) UpdatePreferredLocale(ctx context.Context, customerID uint, locale *string) error {
+	res := r.getDB(ctx).Model(&models.Customer{}).
+		Where("id = ?", customerID).
+		Updates(map[string]any{
+			"preferred_locale": locale,
+			"updated_at":       utils.UTCNow(),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return errors.New("customer not found with ID: " + strconv.Itoa(int(customerID)))
+	}
+	return nil
+}
+
+// UpdateTelegramChat links the customer to a Telegram chat; nil unlinks it
+func (r *CustomerRepositoryImpl) UpdateTelegramChat(ctx context.Context, customerID uint, chatID *int64, linkedAt *time.Time) error {
+	res := r.getDB(ctx).Model(&models.Customer{}).
+		Where("id = ?", customerID).
+		Updates(map[string]any{
+			"telegram_chat_id":   chatID,
+			"telegram_linked_at": linkedAt,
+			"updated_at":         utils.UTCNow(),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return errors.New("customer not found with ID: " + strconv.Itoa(int(customerID)))
+	}
+	return nil
+}
+
+// Anonymize replaces the personal data of a deleted customer with
+// placeholders, makes the password unusable and deactivates the account. The
+// row itself is kept so wallets, transactions and campaigns still reference it.
+func (r *CustomerRepositoryImpl) Anonymize(ctx context.Context, customerID uint, deletedAt time.Time) error {
+	res := r.getDB(ctx).Model(&models.Customer{}).
+		Where("id = ?", customerID).
+		Updates(map[string]any{
+			"company_name":              nil,
+			"national_id":               nil,
+			"company_phone":             nil,
+			"company_address":           nil,
+			"postal_code":               nil,
+			"representative_first_name": "Deleted",
+			"representative_last_name":  "Customer",
+			// Unique columns get a per-customer placeholder that can never be
+			// a valid mobile number or a deliverable email address
+			"representative_mobile": fmt.Sprintf("del-%d", customerID),
+			"email":                 fmt.Sprintf("deleted-%d@deleted.invalid", customerID),
+			"password_hash":         "!",
+			"sheba_number":          nil,
+			"sheba_verification":    nil,
+			"sheba_verified_number": nil,
+			"sheba_owner_name":      nil,
+			"sheba_verified_at":     nil,
+			"job":                   nil,
+			"category":              nil,
+			"telegram_chat_id":      nil,
+			"telegram_linked_at":    nil,
+			"is_active":             false,
+			"deleted_at":            deletedAt,
+			"updated_at":            utils.UTCNow(),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return errors.New("customer not found with ID: " + strconv.Itoa(int(customerID)))
+	}
+	return nil
+}
+
 // UpdateActiveStatus toggles is_active for a given customer ID
 func (r *CustomerRepositoryImpl) UpdateActiveStatus(ctx context.Context, customerID uint, isActive bool) error {
 	db, shouldCommit, err := r.getDBForWrite(ctx)
@@ -411,6 +586,110 @@ func (r *CustomerRepositoryImpl) UpdateActiveStatus(ctx context.Context, custome
 	return nil
 }
 
+// UpdateReferrerAgency moves a customer under another referrer agency
+func (r *CustomerRepositoryImpl) UpdateReferrerAgency(ctx context.Context, customerID, agencyID uint) error {
+	res := r.getDB(ctx).Model(&models.Customer{}).
+		Where("id = ?", customerID).
+		Updates(map[string]any{
+			"referrer_agency_id": agencyID,
+			"updated_at":         utils.UTCNow(),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return errors.New("customer not found with ID: " + strconv.Itoa(int(customerID)))
+	}
+	return nil
+}
+
+// UpdateSuspension sets the customer's suspension; a nil level lifts it and
+// clears the reason and note
+func (r *CustomerRepositoryImpl) UpdateSuspension(ctx context.Context, customerID uint, level *models.CustomerSuspensionLevel, reason *models.CustomerSuspensionReason, note *string) error {
+	now := utils.UTCNow()
+	updates := map[string]any{
+		"suspension_level":  nil,
+		"suspension_reason": nil,
+		"suspension_note":   nil,
+		"suspended_at":      nil,
+		"updated_at":        now,
+	}
+	if level != nil {
+		updates["suspension_level"] = *level
+		updates["suspension_reason"] = reason
+		updates["suspension_note"] = note
+		updates["suspended_at"] = now
+	}
+	res := r.getDB(ctx).Model(&models.Customer{}).
+		Where("id = ?", customerID).
+		Updates(updates)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return errors.New("customer not found with ID: " + strconv.Itoa(int(customerID)))
+	}
+	return nil
+}
+
+// TransitionKYCStatus moves the customer from one KYC status to another and
+// reports false when the customer is no longer in from, so concurrent
+// submissions and reviews cannot both apply. reviewerID and reason are only
+// set by reviews; submitting clears a previous rejection reason.
+func (r *CustomerRepositoryImpl) TransitionKYCStatus(ctx context.Context, customerID uint, from, to models.KYCStatus, reviewerID *uint, reason *string) (bool, error) {
+	now := utils.UTCNow()
+	updates := map[string]any{
+		"kyc_status": to,
+		"updated_at": now,
+	}
+	if to == models.KYCStatusPendingReview {
+		updates["kyc_submitted_at"] = now
+		updates["kyc_rejection_reason"] = nil
+	} else {
+		updates["kyc_reviewed_at"] = now
+		updates["kyc_reviewer_id"] = reviewerID
+		updates["kyc_rejection_reason"] = reason
+	}
+	res := r.getDB(ctx).Model(&models.Customer{}).
+		Where("id = ? AND kyc_status = ?", customerID, from).
+		Updates(updates)
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
+
+// UpdateSandbox toggles is_sandbox for a given customer ID
+func (r *CustomerRepositoryImpl) UpdateSandbox(ctx context.Context, customerID uint, isSandbox bool) error {
+	db, shouldCommit, err := r.getDBForWrite(ctx)
+	if err != nil {
+		return err
+	}
+	if shouldCommit {
+		defer func() {
+			if err != nil {
+				db.Rollback()
+			} else {
+				db.Commit()
+			}
+		}()
+	}
+	res := db.Model(&models.Customer{}).
+		Where("id = ?", customerID).
+		Updates(map[string]any{
+			"is_sandbox": isSandbox,
+			"updated_at": utils.UTCNow(),
+		})
+	if err = res.Error; err != nil {
+		return err
+	}
+	if res.RowsAffected == 0 {
+		err = errors.New("customer not found with ID: " + strconv.Itoa(int(customerID)))
+		return err
+	}
+	return nil
+}
+
 // FindByIDs retrieves customers by a list of IDs with necessary preloads
 func (r *CustomerRepositoryImpl) FindByIDs(ctx context.Context, ids []uint) ([]*models.Customer, error) {
 	db := r.getDB(ctx)
@@ -427,3 +706,79 @@ func (r *CustomerRepositoryImpl) FindByIDs(ctx context.Context, ids []uint) ([]*
 	}
 	return customers, nil
 }
+
+// customerSearchDocument and customerSearchVector are the expressions behind
+// idx_customers_search_trgm and idx_customers_search_tsv. They must stay
+// identical to the indexed expressions for the planner to use the indexes.
+const (
+	customerSearchDocument = `lower(representative_first_name || ' ' || representative_last_name || ' ' || ` +
+		`coalesce(company_name, '') || ' ' || email || ' ' || representative_mobile || ' ' || ` +
+		`coalesce(national_id, '') || ' ' || uuid::text)`
+	customerSearchVector = `to_tsvector('simple', representative_first_name || ' ' || representative_last_name || ' ' || ` +
+		`coalesce(company_name, ''))`
+)
+
+// Search ranks the customers matching an admin search term. A customer
+// matches when the term is a substring of its name, company, email, mobile,
+// national ID or UUID, or when every word of the term prefixes a word of its
+// name or company. Exact email, mobile, national ID and UUID matches rank
+// first, then by word similarity and text rank. Anonymized customers are left
+// out. It returns a page of hits and the number of matches.
+func (r *CustomerRepositoryImpl) Search(ctx context.Context, q CustomerSearchQuery) ([]CustomerSearchHit, int64, error) {
+	term := strings.ToLower(strings.TrimSpace(q.Term))
+	args := map[string]any{
+		"term":    term,
+		"pattern": "%" + escapeLikePattern(term) + "%",
+		"mobile":  phonenumber.CanonicalOrRaw(term),
+	}
+
+	match := []string{customerSearchDocument + " LIKE @pattern", "representative_mobile = @mobile"}
+	rank := "CASE WHEN lower(email) = @term OR representative_mobile = @mobile OR national_id = @term OR uuid::text = @term THEN 2 ELSE 0 END" +
+		" + word_similarity(@term, " + customerSearchDocument + ")"
+	if tsq := customerSearchTSQuery(term); tsq != "" {
+		args["tsq"] = tsq
+		match = append(match, customerSearchVector+" @@ to_tsquery('simple', @tsq)")
+		rank += " + ts_rank(" + customerSearchVector + ", to_tsquery('simple', @tsq))"
+	}
+	where := "deleted_at IS NULL AND (" + strings.Join(match, " OR ") + ")"
+	if len(q.ExcludeEmails) > 0 {
+		args["excluded"] = q.ExcludeEmails
+		where += " AND lower(email) NOT IN @excluded"
+	}
+
+	db := r.getDB(ctx)
+	var total int64
+	if err := db.Raw("SELECT COUNT(*) FROM customers WHERE "+where, args).Scan(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if total == 0 {
+		return []CustomerSearchHit{}, 0, nil
+	}
+
+	args["limit"], args["offset"] = q.Limit, q.Offset
+	var hits []CustomerSearchHit
+	err := db.Raw("SELECT id, "+rank+" AS rank FROM customers WHERE "+where+
+		" ORDER BY rank DESC, id DESC LIMIT @limit OFFSET @offset", args).Scan(&hits).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return hits, total, nil
+}
+
+// customerSearchTSQuery turns a search term into a prefix tsquery of its
+// words, or "" when it has none. Only letters and digits are kept, so the
+// result is always valid tsquery syntax.
+func customerSearchTSQuery(term string) string {
+	words := strings.FieldsFunc(term, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, w := range words {
+		words[i] = w + ":*"
+	}
+	return strings.Join(words, " & ")
+}
+
+// escapeLikePattern escapes the LIKE wildcards of s so it matches literally
+func escapeLikePattern(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
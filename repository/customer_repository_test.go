@@ -0,0 +1,28 @@
+package repository
+
+import "testing"
+
+func TestCustomerSearchTSQuery(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]string{
+		"ali rezaei":        "ali:* & rezaei:*",
+		"ali@example.com":   "ali:* & example:* & com:*",
+		"شرکت  آریا":        "شرکت:* & آریا:*",
+		"it's & (x) | !y:*": "it:* & s:* & x:* & y:*",
+		"@@ -- ::":          "",
+	}
+	for term, want := range cases {
+		if got := customerSearchTSQuery(term); got != want {
+			t.Errorf("customerSearchTSQuery(%q) = %q, want %q", term, got, want)
+		}
+	}
+}
+
+func TestEscapeLikePattern(t *testing.T) {
+	t.Parallel()
+
+	if got, want := escapeLikePattern(`50%_off\now`), `50\%\_off\\now`; got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
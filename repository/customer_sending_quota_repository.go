@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CustomerSendingQuotaRepositoryImpl implements CustomerSendingQuotaRepository
+type CustomerSendingQuotaRepositoryImpl struct {
+	*BaseRepository[models.CustomerSendingQuota, models.CustomerSendingQuotaFilter]
+}
+
+// NewCustomerSendingQuotaRepository creates a new customer sending quota repository
+func NewCustomerSendingQuotaRepository(db *gorm.DB) CustomerSendingQuotaRepository {
+	return &CustomerSendingQuotaRepositoryImpl{
+		BaseRepository: NewBaseRepository[models.CustomerSendingQuota, models.CustomerSendingQuotaFilter](db),
+	}
+}
+
+// ByCustomerID returns the quota of a customer, or nil when none is configured
+func (r *CustomerSendingQuotaRepositoryImpl) ByCustomerID(ctx context.Context, customerID uint) (*models.CustomerSendingQuota, error) {
+	var quota models.CustomerSendingQuota
+	err := r.getDB(ctx).Where("customer_id = ?", customerID).First(&quota).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &quota, nil
+}
+
+// Upsert creates the quota of a customer or replaces its limits
+func (r *CustomerSendingQuotaRepositoryImpl) Upsert(ctx context.Context, quota *models.CustomerSendingQuota) error {
+	return r.getDB(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "customer_id"}},
+		DoUpdates: clause.Assignments(map[string]any{
+			"daily_limit":         clause.Expr{SQL: "EXCLUDED.daily_limit"},
+			"monthly_limit":       clause.Expr{SQL: "EXCLUDED.monthly_limit"},
+			"frequency_cap_hours": clause.Expr{SQL: "EXCLUDED.frequency_cap_hours"},
+			"admin_id":            clause.Expr{SQL: "EXCLUDED.admin_id"},
+			"updated_at":          clause.Expr{SQL: "EXCLUDED.updated_at"},
+		}),
+	}).Create(quota).Error
+}
+
+// Usage counts the recipients of a customer in the Tehran day and month
+// containing at. Campaign excludeCampaignID is left out of the reserved
+// counts so a campaign being finalized is not counted against itself.
+func (r *CustomerSendingQuotaRepositoryImpl) Usage(ctx context.Context, customerID uint, at time.Time, excludeCampaignID uint) (*models.SendingQuotaUsage, error) {
+	dayStart, dayEnd := utils.TehranDayBounds(at)
+	monthStart, monthEnd := utils.TehranMonthBounds(at)
+	usage := &models.SendingQuotaUsage{DayStart: dayStart, MonthStart: monthStart}
+
+	var err error
+	if usage.DailySent, err = r.sentBetween(ctx, customerID, dayStart, dayEnd); err != nil {
+		return nil, err
+	}
+	if usage.MonthlySent, err = r.sentBetween(ctx, customerID, monthStart, monthEnd); err != nil {
+		return nil, err
+	}
+	if usage.DailyReserved, err = r.reservedBetween(ctx, customerID, dayStart, dayEnd, excludeCampaignID); err != nil {
+		return nil, err
+	}
+	if usage.MonthlyReserved, err = r.reservedBetween(ctx, customerID, monthStart, monthEnd, excludeCampaignID); err != nil {
+		return nil, err
+	}
+	return usage, nil
+}
+
+// sentBetween sums the recipients selected for the customer's campaigns
+// processed in [from, to)
+func (r *CustomerSendingQuotaRepositoryImpl) sentBetween(ctx context.Context, customerID uint, from, to time.Time) (uint64, error) {
+	var total int64
+	err := r.getDB(ctx).
+		Table("processed_campaigns AS pc").
+		Joins("JOIN campaigns c ON c.id = pc.campaign_id").
+		Where("c.customer_id = ? AND pc.created_at >= ? AND pc.created_at < ?", customerID, from, to).
+		Select("COALESCE(SUM(cardinality(pc.audience_ids)), 0)").
+		Scan(&total).Error
+	if err != nil {
+		return 0, err
+	}
+	return uint64(max(total, 0)), nil
+}
+
+// reservedBetween sums the audience of the customer's campaigns that await
+// approval or sending and are scheduled in [from, to)
+func (r *CustomerSendingQuotaRepositoryImpl) reservedBetween(ctx context.Context, customerID uint, from, to time.Time, excludeCampaignID uint) (uint64, error) {
+	var total int64
+	err := r.getDB(ctx).
+		Model(&models.Campaign{}).
+		Where("customer_id = ? AND id <> ?", customerID, excludeCampaignID).
+		Where("status IN ?", []models.CampaignStatus{models.CampaignStatusWaitingForApproval, models.CampaignStatusApproved}).
+		Where("(spec->>'schedule_at')::timestamptz >= ? AND (spec->>'schedule_at')::timestamptz < ?", from, to).
+		Select("COALESCE(SUM(num_audience), 0)").
+		Scan(&total).Error
+	if err != nil {
+		return 0, err
+	}
+	return uint64(max(total, 0)), nil
+}
+
+// ByFilter returns sending quotas matching the filter
+func (r *CustomerSendingQuotaRepositoryImpl) ByFilter(ctx context.Context, filter models.CustomerSendingQuotaFilter, orderBy string, limit, offset int) ([]*models.CustomerSendingQuota, error) {
+	db := r.applyFilter(r.getDB(ctx).Model(&models.CustomerSendingQuota{}), filter)
+	if orderBy == "" {
+		orderBy = "id DESC"
+	}
+	db = db.Order(orderBy)
+	if limit > 0 {
+		db = db.Limit(limit)
+	}
+	if offset > 0 {
+		db = db.Offset(offset)
+	}
+
+	var quotas []*models.CustomerSendingQuota
+	if err := db.Find(&quotas).Error; err != nil {
+		return nil, err
+	}
+	return quotas, nil
+}
+
+// Count returns the number of sending quotas matching the filter
+func (r *CustomerSendingQuotaRepositoryImpl) Count(ctx context.Context, filter models.CustomerSendingQuotaFilter) (int64, error) {
+	var count int64
+	if err := r.applyFilter(r.getDB(ctx).Model(&models.CustomerSendingQuota{}), filter).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// Exists checks whether any sending quota matches the filter
+func (r *CustomerSendingQuotaRepositoryImpl) Exists(ctx context.Context, filter models.CustomerSendingQuotaFilter) (bool, error) {
+	count, err := r.Count(ctx, filter)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *CustomerSendingQuotaRepositoryImpl) applyFilter(query *gorm.DB, filter models.CustomerSendingQuotaFilter) *gorm.DB {
+	if filter.ID != nil {
+		query = query.Where("id = ?", *filter.ID)
+	}
+	if filter.CustomerID != nil {
+		query = query.Where("customer_id = ?", *filter.CustomerID)
+	}
+	return query
+}
@@ -4,6 +4,7 @@ package repository
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/amirphl/Yamata-no-Orochi/models"
 	"github.com/amirphl/Yamata-no-Orochi/utils"
@@ -104,6 +105,18 @@ func (r *CustomerSessionRepositoryImpl) ListActiveSessionsByCustomer(ctx context
 	return activeSessions, nil
 }
 
+// TouchLastAccessed records that the session of token was used now. It is a
+// no-op when the session was already marked as used within interval, so it
+// can be called on every request.
+func (r *CustomerSessionRepositoryImpl) TouchLastAccessed(ctx context.Context, token string, interval time.Duration) error {
+	db := r.getDB(ctx)
+
+	now := utils.UTCNow()
+	return db.Model(&models.CustomerSession{}).
+		Where("session_token = ? AND is_active = ? AND last_accessed_at < ?", token, true, now.Add(-interval)).
+		UpdateColumn("last_accessed_at", now).Error
+}
+
 // Update updates a customer session
 func (r *CustomerSessionRepositoryImpl) Update(ctx context.Context, session *models.CustomerSession) error {
 	db, shouldCommit, err := r.getDBForWrite(ctx)
@@ -133,6 +146,22 @@ func (r *CustomerSessionRepositoryImpl) Update(ctx context.Context, session *mod
 	return nil
 }
 
+// AnonymizeByCustomer ends every session of a customer and erases the client
+// IP address, user agent and device details recorded with them
+func (r *CustomerSessionRepositoryImpl) AnonymizeByCustomer(ctx context.Context, customerID uint) error {
+	now := utils.UTCNow()
+	return r.getDB(ctx).Model(&models.CustomerSession{}).
+		Where("customer_id = ?", customerID).
+		Updates(map[string]any{
+			"is_active":   false,
+			"ip_address":  nil,
+			"user_agent":  nil,
+			"device_info": nil,
+			"expires_at":  gorm.Expr("LEAST(expires_at, ?)", now),
+			"updated_at":  now,
+		}).Error
+}
+
 // applyFilter applies filter criteria to a GORM query
 func (r *CustomerSessionRepositoryImpl) applyFilter(query *gorm.DB, filter models.CustomerSessionFilter) *gorm.DB {
 	// Apply filters based on provided values
@@ -96,12 +96,21 @@ func (r *depositReceiptRepository) List(ctx context.Context, f models.DepositRec
 	if f.Lang != nil {
 		q = q.Where("lang = ?", *f.Lang)
 	}
+	if f.TrackingNumber != nil {
+		q = q.Where("tracking_number = ?", *f.TrackingNumber)
+	}
+	if f.BankReference != nil {
+		q = q.Where("bank_reference = ?", *f.BankReference)
+	}
 	if f.CreatedAfter != nil {
 		q = q.Where("created_at >= ?", *f.CreatedAfter)
 	}
 	if f.CreatedBefore != nil {
 		q = q.Where("created_at <= ?", *f.CreatedBefore)
 	}
+	if f.OmitFileData {
+		q = q.Omit("file_data")
+	}
 	if order != "" {
 		q = q.Order(order)
 	} else {
@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Hot queries of login, wallet charging, campaign listing, the scheduler
+// status checks and the admin customer timeline and search, each with the
+// latency it must stay under. The budgets are for a warm, indexed database on
+// the CI runner; scale them with BENCH_SLO_SCALE on slower machines. Run
+// against a migrated database holding at least one customer with a wallet:
+//
+//	REPOSITORY_BENCH_DSN="host=localhost user=postgres dbname=yamata sslmode=disable" \
+//	  go test ./repository -run '^$' -bench HotQueries
+//
+// A benchmark whose average exceeds its budget fails, so the run doubles as
+// a performance regression check.
+type hotQuery struct {
+	name string
+	slo  time.Duration
+	run  func(ctx context.Context) error
+}
+
+// benchHotQueriesFixture is a customer the hot queries look up
+type benchHotQueriesFixture struct {
+	CustomerID uint
+	Mobile     string
+	WalletID   uint
+}
+
+func benchHotQueriesDB(b *testing.B) (*gorm.DB, benchHotQueriesFixture) {
+	dsn := os.Getenv("REPOSITORY_BENCH_DSN")
+	if dsn == "" {
+		b.Skip("REPOSITORY_BENCH_DSN not set")
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		b.Fatalf("open database: %v", err)
+	}
+	var fixture benchHotQueriesFixture
+	err = db.Raw(`SELECT c.id AS customer_id, c.representative_mobile AS mobile, w.id AS wallet_id
+		FROM customers c JOIN wallets w ON w.customer_id = c.id
+		ORDER BY c.id LIMIT 1`).Scan(&fixture).Error
+	if err != nil {
+		b.Fatalf("load customer: %v", err)
+	}
+	if fixture.CustomerID == 0 {
+		b.Skip("no customer with a wallet to query")
+	}
+	return db, fixture
+}
+
+// sloScale returns the BENCH_SLO_SCALE multiplier of the latency budgets
+func sloScale(b *testing.B) float64 {
+	raw := os.Getenv("BENCH_SLO_SCALE")
+	if raw == "" {
+		return 1
+	}
+	scale, err := strconv.ParseFloat(raw, 64)
+	if err != nil || scale <= 0 {
+		b.Fatalf("invalid BENCH_SLO_SCALE %q", raw)
+	}
+	return scale
+}
+
+// assertSLO fails b when the average latency of its iterations exceeds slo.
+// The first round of a benchmark runs once on cold caches, so it only fails
+// when it is an order of magnitude over.
+func assertSLO(b *testing.B, slo time.Duration) {
+	b.Helper()
+	perOp := b.Elapsed() / time.Duration(b.N)
+	limit := time.Duration(float64(slo) * sloScale(b))
+	if b.N == 1 {
+		limit *= 10
+	}
+	if perOp > limit {
+		b.Errorf("%s/op exceeds the %s budget (N=%d)", perOp, limit, b.N)
+	}
+}
+
+func BenchmarkHotQueries(b *testing.B) {
+	db, fixture := benchHotQueriesDB(b)
+	customers := NewCustomerRepository(db)
+	wallets := NewWalletRepository(db)
+	snapshots := NewBalanceSnapshotRepository(db)
+	transactions := NewTransactionRepository(db)
+	campaigns := NewCampaignRepository(db)
+	statusJobs := NewCampaignStatusJobRepository(db)
+	activities := NewCustomerActivityRepository(db)
+
+	queries := []hotQuery{
+		{"customer_by_mobile", 2 * time.Millisecond, func(ctx context.Context) error {
+			_, err := customers.ByMobile(ctx, fixture.Mobile)
+			return err
+		}},
+		{"wallet_by_customer", 2 * time.Millisecond, func(ctx context.Context) error {
+			_, err := wallets.ByCustomerID(ctx, fixture.CustomerID)
+			return err
+		}},
+		{"latest_balance_snapshot", 3 * time.Millisecond, func(ctx context.Context) error {
+			_, err := snapshots.GetLatestByWalletID(ctx, fixture.WalletID)
+			return err
+		}},
+		{"transactions_by_wallet", 5 * time.Millisecond, func(ctx context.Context) error {
+			_, err := transactions.ByWalletID(ctx, fixture.WalletID, 20, 0)
+			return err
+		}},
+		{"campaigns_by_customer", 10 * time.Millisecond, func(ctx context.Context) error {
+			_, err := campaigns.ByCustomerID(ctx, fixture.CustomerID, 20, 0)
+			return err
+		}},
+		{"due_status_jobs", 5 * time.Millisecond, func(ctx context.Context) error {
+			_, err := statusJobs.ListDue(ctx, models.CampaignPlatformSMS, time.Now(), 100)
+			return err
+		}},
+		{"customer_activity_timeline", 15 * time.Millisecond, func(ctx context.Context) error {
+			_, err := activities.ListActivities(ctx, CustomerActivityQuery{CustomerID: fixture.CustomerID, Limit: 50})
+			return err
+		}},
+		{"customer_search", 20 * time.Millisecond, func(ctx context.Context) error {
+			_, _, err := customers.Search(ctx, CustomerSearchQuery{Term: fixture.Mobile[len(fixture.Mobile)-7:], Limit: 20})
+			return err
+		}},
+	}
+	for _, q := range queries {
+		b.Run(q.name, func(b *testing.B) {
+			ctx := context.Background()
+			for i := 0; i < b.N; i++ {
+				if err := q.run(ctx); err != nil {
+					b.Fatalf("%s: %v", q.name, err)
+				}
+			}
+			assertSLO(b, q.slo)
+		})
+	}
+}
@@ -4,6 +4,7 @@ package repository
 import (
 	"context"
 	"encoding/json"
+	"io"
 	"time"
 
 	"github.com/amirphl/Yamata-no-Orochi/models"
@@ -15,6 +16,10 @@ type contextKey string
 
 const TxContextKey contextKey = "tx"
 
+// TxConnContextKey holds the *sql.Conn a WithTransaction transaction runs on,
+// for driver-level operations such as COPY that must join the transaction
+const TxConnContextKey contextKey = "tx_conn"
+
 type Repository[T any, F any] interface {
 	ByFilter(ctx context.Context, filter F, orderBy string, limit, offset int) ([]*T, error)
 	Save(ctx context.Context, entity *T) error
@@ -36,6 +41,7 @@ type AdminRepository interface {
 	ByID(ctx context.Context, id uint) (*models.Admin, error)
 	ByUUID(ctx context.Context, uuid string) (*models.Admin, error)
 	ByUsername(ctx context.Context, username string) (*models.Admin, error)
+	EnableTOTP(ctx context.Context, adminID uint, secret string) error
 }
 
 // BotRepository defines operations for bots
@@ -49,14 +55,172 @@ type BotRepository interface {
 // AudienceProfileRepository defines operations for audience profiles
 type AudienceProfileRepository interface {
 	Repository[models.AudienceProfile, models.AudienceProfileFilter]
+	SoftDeleteRepository
 	ByID(ctx context.Context, id uint) (*models.AudienceProfile, error)
 	ByUID(ctx context.Context, uid string) (*models.AudienceProfile, error)
 	ByUIDs(ctx context.Context, uids []string) ([]*models.AudienceProfile, error)
+	AttributesByIDs(ctx context.Context, ids []int64) (map[int64]map[string]string, error)
+	ImportPhoneNumbers(ctx context.Context, entries []AudienceProfileImportEntry) (inserted, existing int64, err error)
+}
+
+// AudienceImportJobRepository defines operations for bulk audience import jobs
+type AudienceImportJobRepository interface {
+	Repository[models.AudienceImportJob, models.AudienceImportJobFilter]
+	ByUUID(ctx context.Context, id uuid.UUID) (*models.AudienceImportJob, error)
+	ClaimNext(ctx context.Context, staleBefore time.Time) (*models.AudienceImportJob, error)
+	Update(ctx context.Context, job *models.AudienceImportJob) error
+}
+
+// JobRepository defines operations for the persistent background job queue
+type JobRepository interface {
+	Repository[models.Job, models.JobFilter]
+	ByUUID(ctx context.Context, id uuid.UUID) (*models.Job, error)
+	ClaimNext(ctx context.Context, staleBefore time.Time) (*models.Job, error)
+	Update(ctx context.Context, job *models.Job) error
+	Requeue(ctx context.Context, id uint) (bool, error)
+	Cancel(ctx context.Context, id uint) (bool, error)
+	DeleteSucceededBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// AudienceColorTransitionRepository finds audience profiles due a color change
+// and records the changes. Only activity after a profile's last transition
+// counts, so a profile is not moved back and forth on the same evidence.
+type AudienceColorTransitionRepository interface {
+	Repository[models.AudienceColorTransition, models.AudienceColorTransitionFilter]
+	// FailureDemotionCandidates returns white profiles with at least
+	// minFailures undelivered SMS and no delivered one since since
+	FailureDemotionCandidates(ctx context.Context, since time.Time, minFailures, limit int) ([]models.AudienceColorCandidate, error)
+	// OptOutDemotionCandidates returns white profiles whose number is blacklisted
+	OptOutDemotionCandidates(ctx context.Context, limit int) ([]models.AudienceColorCandidate, error)
+	// ClickPromotionCandidates returns pink, non-blacklisted profiles with at
+	// least minClicks short link clicks since since
+	ClickPromotionCandidates(ctx context.Context, since time.Time, minClicks, limit int) ([]models.AudienceColorCandidate, error)
+	// PermanentErrorDemotionCandidates returns white profiles an SMS was
+	// rejected for with a permanent provider error since since
+	PermanentErrorDemotionCandidates(ctx context.Context, since time.Time, limit int) ([]models.AudienceColorCandidate, error)
+	// Apply moves the candidates still in from to to and records a transition
+	// for each; it returns how many were moved
+	Apply(ctx context.Context, from, to string, reason models.AudienceColorTransitionReason, candidates []models.AudienceColorCandidate, at time.Time) (int, error)
+}
+
+// BlacklistedNumberRepository defines operations for prohibited recipients
+type BlacklistedNumberRepository interface {
+	Repository[models.BlacklistedNumber, models.BlacklistedNumberFilter]
+	InsertMissing(ctx context.Context, entries []*models.BlacklistedNumber) (int64, error)
+	DeleteByPhoneNumber(ctx context.Context, phoneNumber string) (bool, error)
+	PhoneNumberSet(ctx context.Context) (map[string]struct{}, error)
+}
+
+// CampaignReviewRepository defines operations for the review history of campaigns
+type CampaignReviewRepository interface {
+	Repository[models.CampaignReview, models.CampaignReviewFilter]
+	ByCampaignID(ctx context.Context, campaignID uint) ([]*models.CampaignReview, error)
+}
+
+// NotificationRepository defines operations for the in-app inbox of customers
+type NotificationRepository interface {
+	Repository[models.Notification, models.NotificationFilter]
+	Record(ctx context.Context, notification *models.Notification) error
+	MarkRead(ctx context.Context, customerID uint, ids []uint) (int64, error)
+}
+
+// TelegramLinkRequestRepository defines operations for Telegram chat link requests
+type TelegramLinkRequestRepository interface {
+	Repository[models.TelegramLinkRequest, models.TelegramLinkRequestFilter]
+	AttachChat(ctx context.Context, id uint, chatID int64, codeHash string) error
+	IncrementAttempts(ctx context.Context, id uint) error
+	MarkLinked(ctx context.Context, id uint, linkedAt time.Time) error
+}
+
+// PushDeviceRepository defines operations for customers' push devices
+type PushDeviceRepository interface {
+	Repository[models.PushDevice, models.PushDeviceFilter]
+	Register(ctx context.Context, device *models.PushDevice) error
+	Remove(ctx context.Context, customerID uint, token string) (int64, error)
+	RemoveTokens(ctx context.Context, tokens []string) (int64, error)
+	PruneOldest(ctx context.Context, customerID uint, keep int) (int64, error)
+}
+
+// KYCDocumentRepository defines operations for customers' verification documents
+type KYCDocumentRepository interface {
+	Repository[models.KYCDocument, models.KYCDocumentFilter]
+	ByUUID(ctx context.Context, customerID uint, id uuid.UUID) (*models.KYCDocument, error)
+	Current(ctx context.Context, customerID uint) ([]*models.KYCDocument, error)
+	Supersede(ctx context.Context, customerID uint, kind models.KYCDocumentKind, at time.Time) (int64, error)
+}
+
+// CustomerProfileChangeRepository defines operations for the history of
+// customers' profile field changes
+type CustomerProfileChangeRepository interface {
+	Repository[models.CustomerProfileChange, models.CustomerProfileChangeFilter]
+	ByUUID(ctx context.Context, id uuid.UUID) (*models.CustomerProfileChange, error)
+	SupersedePending(ctx context.Context, customerID uint, field models.ProfileField) (int64, error)
+	Review(ctx context.Context, id uint, to models.ProfileChangeStatus, reviewerID *uint, reason *string, at time.Time) (bool, error)
+	AnonymizeByCustomer(ctx context.Context, customerID uint) error
+}
+
+// CustomerSendingQuotaRepository defines operations for per-customer sending quotas
+type CustomerSendingQuotaRepository interface {
+	Repository[models.CustomerSendingQuota, models.CustomerSendingQuotaFilter]
+	ByCustomerID(ctx context.Context, customerID uint) (*models.CustomerSendingQuota, error)
+	Upsert(ctx context.Context, quota *models.CustomerSendingQuota) error
+	Usage(ctx context.Context, customerID uint, at time.Time, excludeCampaignID uint) (*models.SendingQuotaUsage, error)
+}
+
+// CustomerCreditLineRepository defines operations for postpaid credit lines
+type CustomerCreditLineRepository interface {
+	Repository[models.CustomerCreditLine, models.CustomerCreditLineFilter]
+	ByCustomerID(ctx context.Context, customerID uint) (*models.CustomerCreditLine, error)
+	UpsertLimit(ctx context.Context, line *models.CustomerCreditLine) error
+	Draw(ctx context.Context, customerID uint, amount uint64) (bool, error)
+	Settle(ctx context.Context, customerID uint, amount uint64) error
+}
+
+// PostpaidDrawRepository defines operations for amounts drawn from credit lines
+type PostpaidDrawRepository interface {
+	Repository[models.PostpaidDraw, models.PostpaidDrawFilter]
+	UninvoicedTotals(ctx context.Context, before time.Time) ([]models.PostpaidDrawTotal, error)
+	AssignInvoice(ctx context.Context, customerID uint, before time.Time, invoiceID uint) (int64, error)
+}
+
+// PostpaidInvoiceRepository defines operations for monthly postpaid invoices
+type PostpaidInvoiceRepository interface {
+	Repository[models.PostpaidInvoice, models.PostpaidInvoiceFilter]
+	ByUUID(ctx context.Context, id uuid.UUID) (*models.PostpaidInvoice, error)
+	MarkPaid(ctx context.Context, inv *models.PostpaidInvoice) (bool, error)
+}
+
+// AgencySharePayableRepository defines operations for agency shares the
+// platform owes after settling them to the system Sheba number
+type AgencySharePayableRepository interface {
+	Repository[models.AgencySharePayable, models.AgencySharePayableFilter]
+	ByUUID(ctx context.Context, id uuid.UUID) (*models.AgencySharePayable, error)
+	MarkPaid(ctx context.Context, p *models.AgencySharePayable) (bool, error)
+	SumAmount(ctx context.Context, filter models.AgencySharePayableFilter) (uint64, error)
+}
+
+// TaxInvoiceRepository defines operations for official invoices of wallet charges
+type TaxInvoiceRepository interface {
+	Repository[models.TaxInvoice, models.TaxInvoiceFilter]
+	NextSequenceNumber(ctx context.Context, legalEntity string) (uint64, error)
+	ByUUID(ctx context.Context, id uuid.UUID) (*models.TaxInvoice, error)
+	SetPDF(ctx context.Context, id uint, path string, generatedAt time.Time) error
+}
+
+// MoadianSubmissionRepository defines operations for the queue of tax invoices
+// submitted to Moadian
+type MoadianSubmissionRepository interface {
+	Repository[models.MoadianSubmission, models.MoadianSubmissionFilter]
+	EnqueueIssued(ctx context.Context, limit int) (int64, error)
+	ClaimNextDue(ctx context.Context, now, staleBefore time.Time) (*models.MoadianSubmission, error)
+	ListAwaitingResult(ctx context.Context, now time.Time, limit int) ([]*models.MoadianSubmission, error)
+	Update(ctx context.Context, sub *models.MoadianSubmission) error
 }
 
 // LineNumberRepository defines operations for line numbers
 type LineNumberRepository interface {
 	Repository[models.LineNumber, models.LineNumberFilter]
+	SoftDeleteRepository
 	ByID(ctx context.Context, id uint) (*models.LineNumber, error)
 	ByUUID(ctx context.Context, uuid string) (*models.LineNumber, error)
 	ByValue(ctx context.Context, value string) (*models.LineNumber, error)
@@ -64,6 +228,56 @@ type LineNumberRepository interface {
 	UpdateBatch(ctx context.Context, lines []*models.LineNumber) error
 }
 
+// LineNumberTierRepository defines operations for line number reservation tiers
+type LineNumberTierRepository interface {
+	Repository[models.LineNumberTier, models.LineNumberTierFilter]
+	ByID(ctx context.Context, id uint) (*models.LineNumberTier, error)
+	ByName(ctx context.Context, name string) (*models.LineNumberTier, error)
+	Update(ctx context.Context, tier *models.LineNumberTier) error
+}
+
+// LineNumberReservationRepository defines operations for line number reservations
+type LineNumberReservationRepository interface {
+	Repository[models.LineNumberReservation, models.LineNumberReservationFilter]
+	LockLineNumber(ctx context.Context, lineNumberID uint) error
+	ActiveByLineNumberID(ctx context.Context, lineNumberID uint, at time.Time) (*models.LineNumberReservation, error)
+	Release(ctx context.Context, id uint, at time.Time) error
+}
+
+// PartitionRepository manages the monthly partitions of range partitioned tables
+type PartitionRepository interface {
+	ListPartitions(ctx context.Context, parentTable string) ([]models.TablePartition, error)
+	EnsureMonthlyPartition(ctx context.Context, parentTable string, month time.Time) (string, error)
+	CopyPartitionCSV(ctx context.Context, partitionName string, w io.Writer) (int64, error)
+	DetachAndDropPartition(ctx context.Context, parentTable, partitionName string) error
+	TryLockMaintenance(ctx context.Context) (release func(), ok bool, err error)
+}
+
+// ReportRollupRepository refreshes and reads the reporting rollups:
+// campaign_daily_stats, revenue_daily and customer_monthly_usage
+type ReportRollupRepository interface {
+	TryLockRefresh(ctx context.Context) (release func(), ok bool, err error)
+	RefreshDay(ctx context.Context, day time.Time, refreshedAt time.Time) error
+	RefreshMonth(ctx context.Context, month time.Time, refreshedAt time.Time) error
+	State(ctx context.Context, name string) (*models.ReportRollupState, error)
+	AdvanceState(ctx context.Context, name string, through time.Time, refreshedAt time.Time) error
+	RevenuePeriods(ctx context.Context, granularity string, fromDate, toDate time.Time) ([]*FinancialPeriodAggregate, error)
+	CampaignDailyStats(ctx context.Context, campaignID uint, fromDate, toDate time.Time) ([]*models.CampaignDailyStat, error)
+	CustomerMonthlyUsage(ctx context.Context, customerID uint, fromMonth, toMonth time.Time) ([]*models.CustomerMonthlyUsage, error)
+}
+
+// CustomerActivityRepository reads the activity timeline of a customer:
+// audit logs, sessions, payment requests and campaigns in one stream
+type CustomerActivityRepository interface {
+	ListActivities(ctx context.Context, q CustomerActivityQuery) ([]*CustomerActivity, error)
+}
+
+// PartitionArchiveRepository defines operations for archived partitions
+type PartitionArchiveRepository interface {
+	Repository[models.PartitionArchive, models.PartitionArchiveFilter]
+	Overlapping(ctx context.Context, parentTable string, from, to time.Time) ([]*models.PartitionArchive, error)
+}
+
 // PlatformBasePriceRepository defines operations for platform base prices.
 type PlatformBasePriceRepository interface {
 	Insert(ctx context.Context, p *models.PlatformBasePrice) error
@@ -79,6 +293,24 @@ type PagePriceRepository interface {
 	ListLatest(ctx context.Context) ([]*models.PagePrice, error)
 }
 
+// SMSTariffRepository defines operations for SMS tariffs
+type SMSTariffRepository interface {
+	Repository[models.SMSTariff, models.SMSTariffFilter]
+	ByID(ctx context.Context, id uint) (*models.SMSTariff, error)
+	ByUUID(ctx context.Context, id uuid.UUID) (*models.SMSTariff, error)
+	ListActive(ctx context.Context) ([]*models.SMSTariff, error)
+	Update(ctx context.Context, tariff *models.SMSTariff) error
+	Delete(ctx context.Context, id uint) error
+}
+
+// CampaignTemplateRepository defines operations for campaign templates
+type CampaignTemplateRepository interface {
+	Repository[models.CampaignTemplate, models.CampaignTemplateFilter]
+	ByUUID(ctx context.Context, id uuid.UUID) (*models.CampaignTemplate, error)
+	ListVisibleToCustomer(ctx context.Context, customerID uint) ([]*models.CampaignTemplate, error)
+	Delete(ctx context.Context, id uint) error
+}
+
 // CustomerRepository defines operations for customers
 type CustomerRepository interface {
 	Repository[models.Customer, models.CustomerFilter]
@@ -91,9 +323,36 @@ type CustomerRepository interface {
 	ListByAgency(ctx context.Context, agencyID uint) ([]*models.Customer, error)
 	ListActiveCustomers(ctx context.Context, limit, offset int) ([]*models.Customer, error)
 	UpdatePassword(ctx context.Context, customerID uint, passwordHash string) error
+	UpdateRepresentativeMobile(ctx context.Context, customerID uint, mobile string, verifiedAt time.Time) error
+	UpdateProfileFields(ctx context.Context, customerID uint, values map[models.ProfileField]*string) error
+	SaveShebaVerification(ctx context.Context, customerID uint, sheba string, status models.ShebaVerificationStatus, ownerName *string, at time.Time) error
 	UpdateVerificationStatus(ctx context.Context, customerID uint, isMobileVerified, isEmailVerified *bool, mobileVerifiedAt, emailVerifiedAt *time.Time) error
 	FindByIDs(ctx context.Context, ids []uint) ([]*models.Customer, error)
 	UpdateActiveStatus(ctx context.Context, customerID uint, isActive bool) error
+	UpdateReferrerAgency(ctx context.Context, customerID, agencyID uint) error
+	UpdateSandbox(ctx context.Context, customerID uint, isSandbox bool) error
+	UpdateSuspension(ctx context.Context, customerID uint, level *models.CustomerSuspensionLevel, reason *models.CustomerSuspensionReason, note *string) error
+	TransitionKYCStatus(ctx context.Context, customerID uint, from, to models.KYCStatus, reviewerID *uint, reason *string) (bool, error)
+	SetPasswordResetRequired(ctx context.Context, customerID uint, required bool) error
+	UpdatePreferredLocale(ctx context.Context, customerID uint, locale *string) error
+	UpdateTelegramChat(ctx context.Context, customerID uint, chatID *int64, linkedAt *time.Time) error
+	Anonymize(ctx context.Context, customerID uint, deletedAt time.Time) error
+	Search(ctx context.Context, q CustomerSearchQuery) ([]CustomerSearchHit, int64, error)
+}
+
+// CustomerSearchQuery selects a page of customers matching an admin search
+type CustomerSearchQuery struct {
+	Term string
+	// ExcludeEmails leaves out internal accounts by their lowercased email
+	ExcludeEmails []string
+	Limit         int
+	Offset        int
+}
+
+// CustomerSearchHit is a customer matching a search and its relevance
+type CustomerSearchHit struct {
+	CustomerID uint    `gorm:"column:id"`
+	Rank       float64 `gorm:"column:rank"`
 }
 
 // CustomerSessionRepository defines operations for customer sessions
@@ -103,9 +362,29 @@ type CustomerSessionRepository interface {
 	BySessionToken(ctx context.Context, token string) (*models.CustomerSession, error)
 	ByRefreshToken(ctx context.Context, token string) (*models.CustomerSession, error)
 	ListActiveSessionsByCustomer(ctx context.Context, customerID uint) ([]*models.CustomerSession, error)
+	TouchLastAccessed(ctx context.Context, token string, interval time.Duration) error
 	GetLatestByCorrelationID(ctx context.Context, correlationID uuid.UUID) (*models.CustomerSession, error)
 	GetHistoryByCorrelationID(ctx context.Context, correlationID uuid.UUID) ([]*models.CustomerSession, error)
 	Update(ctx context.Context, session *models.CustomerSession) error
+	AnonymizeByCustomer(ctx context.Context, customerID uint) error
+}
+
+// CustomerKnownDeviceRepository defines operations for the devices customers logged in from
+type CustomerKnownDeviceRepository interface {
+	Repository[models.CustomerKnownDevice, models.CustomerKnownDeviceFilter]
+	ByCustomerID(ctx context.Context, customerID uint) ([]*models.CustomerKnownDevice, error)
+	Upsert(ctx context.Context, device *models.CustomerKnownDevice) error
+	Forget(ctx context.Context, customerID uint, fingerprint, ipRange string) error
+	ForgetAll(ctx context.Context, customerID uint) error
+}
+
+// CustomerDataRequestRepository defines operations for customer data exports and account deletions
+type CustomerDataRequestRepository interface {
+	Repository[models.CustomerDataRequest, models.CustomerDataRequestFilter]
+	ByUUID(ctx context.Context, id uuid.UUID) (*models.CustomerDataRequest, error)
+	ClaimNext(ctx context.Context, now, staleBefore time.Time) (*models.CustomerDataRequest, error)
+	ExpiredExports(ctx context.Context, now time.Time, limit int) ([]*models.CustomerDataRequest, error)
+	Update(ctx context.Context, request *models.CustomerDataRequest) error
 }
 
 // AuditLogRepository defines operations for audit logs
@@ -114,6 +393,7 @@ type AuditLogRepository interface {
 	ByID(ctx context.Context, id uint) (*models.AuditLog, error)
 	ListByCustomer(ctx context.Context, customerID uint, limit, offset int) ([]*models.AuditLog, error)
 	ListByAction(ctx context.Context, action string, limit, offset int) ([]*models.AuditLog, error)
+	ListByCampaign(ctx context.Context, campaignID uint, limit int) ([]*models.AuditLog, error)
 	ListFailedActions(ctx context.Context, limit, offset int) ([]*models.AuditLog, error)
 	ListSecurityEvents(ctx context.Context, limit, offset int) ([]*models.AuditLog, error)
 }
@@ -129,6 +409,7 @@ type BundleRepository interface {
 // CampaignRepository defines the interface for campaign data access
 type CampaignRepository interface {
 	Repository[models.Campaign, models.CampaignFilter]
+	SoftDeleteRepository
 	ByID(ctx context.Context, id uint) (*models.Campaign, error)
 	ByUUID(ctx context.Context, uuid string) (*models.Campaign, error)
 	ByCustomerID(ctx context.Context, customerID uint, limit, offset int) ([]*models.Campaign, error)
@@ -141,13 +422,16 @@ type CampaignRepository interface {
 	UpdateStatus(ctx context.Context, id uint, status models.CampaignStatus) error
 	MarkHidden(ctx context.Context, customerID uint, campaignIDs []uint) (int64, error)
 	MarkVisible(ctx context.Context, customerID uint, campaignIDs []uint) (int64, error)
+	DeleteSandboxByCustomerID(ctx context.Context, customerID uint) (int64, error)
 	CountByCustomerID(ctx context.Context, customerID uint) (int, error)
 	CountByStatus(ctx context.Context, status models.CampaignStatus) (int, error)
 	GetPendingApproval(ctx context.Context, limit, offset int) ([]*models.Campaign, error)
 	GetScheduledCampaigns(ctx context.Context, from, to time.Time) ([]*models.Campaign, error)
+	ListDueRecurringParents(ctx context.Context, dueBefore time.Time, limit int) ([]*models.Campaign, error)
 	AggregateClickCountsByCampaignIDs(ctx context.Context, campaignIDs []uint) (map[uint]int64, error)
 	AggregateClickCountsByCustomerIDs(ctx context.Context, customerIDs []uint) (map[uint]int64, error)
 	AggregateTotalSentByCustomerIDs(ctx context.Context, customerIDs []uint) (map[uint]uint64, error)
+	TopByReferrerAgency(ctx context.Context, agencyID uint, startDate, endDate *time.Time, limit int) ([]*AgencyCampaignAggregate, error)
 }
 
 // WalletRepository defines the interface for wallet data access
@@ -157,6 +441,8 @@ type WalletRepository interface {
 	ByUUID(ctx context.Context, uuid string) (*models.Wallet, error)
 	ByCustomerID(ctx context.Context, customerID uint) (*models.Wallet, error)
 	SaveWithInitialSnapshot(ctx context.Context, wallet *models.Wallet) error
+	AdvanceVersion(ctx context.Context, wallet *models.Wallet) error
+	LockBalance(ctx context.Context, walletID uint) error
 	GetCurrentBalance(ctx context.Context, walletID uint) (*models.BalanceSnapshot, error)
 	GetBalanceAtTime(ctx context.Context, walletID uint, timestamp time.Time) (*models.BalanceSnapshot, error)
 	GetBalanceHistory(ctx context.Context, walletID uint, limit, offset int) ([]*models.BalanceSnapshot, error)
@@ -168,6 +454,7 @@ type TransactionRepository interface {
 	ByID(ctx context.Context, id uint) (*models.Transaction, error)
 	ByUUID(ctx context.Context, uuid string) (*models.Transaction, error)
 	UpdateMetadata(ctx context.Context, id uint, metadata []byte, updatedAt time.Time) error
+	UpdateStatus(ctx context.Context, id uint, status models.TransactionStatus, updatedAt time.Time) error
 	ByCorrelationID(ctx context.Context, correlationID uuid.UUID) ([]*models.Transaction, error)
 	ByWalletID(ctx context.Context, walletID uint, limit, offset int) ([]*models.Transaction, error)
 	ByCustomerID(ctx context.Context, customerID uint, limit, offset int) ([]*models.Transaction, error)
@@ -184,6 +471,15 @@ type TransactionRepository interface {
 	AggregateAgencyTransactionsByDiscounts(ctx context.Context, agencyID uint, customerID uint, orderBy string) ([]*AgencyCustomerDiscountAggregate, error)
 	AggregateCustomersShares(ctx context.Context, startDate, endDate *time.Time) ([]*CustomerShareAggregate, error)
 	AggregateCustomerTransactionsByDiscounts(ctx context.Context, customerID uint, orderBy string) ([]*AgencyCustomerDiscountAggregate, error)
+	AggregateAgencyCommissionByCustomers(ctx context.Context, agencyID uint, startDate, endDate *time.Time) ([]*AgencyCommissionCustomerAggregate, error)
+	AggregateAgencyCommissionByMonth(ctx context.Context, agencyID uint, startDate, endDate *time.Time) ([]*AgencyCommissionMonthAggregate, error)
+	AggregateFinancialPeriods(ctx context.Context, granularity string, startDate, endDate time.Time) ([]*FinancialPeriodAggregate, error)
+	AggregateTopCustomers(ctx context.Context, startDate, endDate time.Time, limit int) ([]*TopCustomerAggregate, error)
+	SumCustomerDepositsWithTax(ctx context.Context, customerID uint, startDate, endDate time.Time) (uint64, error)
+	DeleteSandboxByCustomerID(ctx context.Context, customerID uint) (int64, error)
+	// Frozen budget audit
+	CampaignFrozenLedgers(ctx context.Context) ([]*CampaignFrozenLedger, error)
+	WalletFrozenLedgers(ctx context.Context) ([]*WalletFrozenLedger, error)
 }
 
 // ACLChangeRequestRepository defines operations for maker-checker requests.
@@ -202,12 +498,16 @@ type BalanceSnapshotRepository interface {
 	ByCustomerID(ctx context.Context, customerID uint, limit, offset int) ([]*models.BalanceSnapshot, error)
 	GetLatestByWalletID(ctx context.Context, walletID uint) (*models.BalanceSnapshot, error)
 	GetLatestByWalletIDBeforeTime(ctx context.Context, walletID uint, timestamp time.Time) (*models.BalanceSnapshot, error)
+	SumLatestBalances(ctx context.Context, excludeWalletUUIDs []string) (*WalletBalanceTotals, error)
 }
 
 // PaymentRequestRepository defines the interface for payment request data access
 type PaymentRequestRepository interface {
 	Repository[models.PaymentRequest, models.PaymentRequestFilter]
 	Update(ctx context.Context, request *models.PaymentRequest) error
+	MarkExpired(ctx context.Context, id uint, from models.PaymentRequestStatus, reason string) (bool, error)
+	SetMoadianStatus(ctx context.Context, id uint, status models.MoadianSubmissionStatus, taxID, referenceNumber *string) error
+	ListCompletedAtipay(ctx context.Context, from, to time.Time) ([]*models.PaymentRequest, error)
 	LockCustomerInvoiceUUID(ctx context.Context, invoiceUUID string) error
 	IsCustomerDepositInvoiceUUIDAlreadyLinked(ctx context.Context, invoiceUUID string) (bool, error)
 	FindAdminChargeByIdempotencyKey(ctx context.Context, idempotencyKey string) (*models.PaymentRequest, error)
@@ -225,6 +525,30 @@ type PaymentRequestRepository interface {
 	GetCompletedRequests(ctx context.Context, limit, offset int) ([]*models.PaymentRequest, error)
 }
 
+// AtipayReconciliationRepository defines data access for Atipay settlement reconciliation entries
+type AtipayReconciliationRepository interface {
+	Repository[models.AtipayReconciliationEntry, models.AtipayReconciliationEntryFilter]
+	ReplaceForDate(ctx context.Context, reportDate string, entries []*models.AtipayReconciliationEntry) error
+	CountByStatus(ctx context.Context, reportDate string) (map[models.AtipayReconciliationStatus]int64, error)
+}
+
+// WalletAdjustmentRequestRepository defines data access for dual-approval wallet adjustments
+type WalletAdjustmentRequestRepository interface {
+	Repository[models.WalletAdjustmentRequest, models.WalletAdjustmentRequestFilter]
+	ByUUID(ctx context.Context, id uuid.UUID) (*models.WalletAdjustmentRequest, error)
+	MarkReviewed(ctx context.Context, req *models.WalletAdjustmentRequest) (bool, error)
+}
+
+// WalletTransferRepository defines data access for transfers between customer wallets
+type WalletTransferRepository interface {
+	Repository[models.WalletTransfer, models.WalletTransferFilter]
+	ByUUID(ctx context.Context, id uuid.UUID) (*models.WalletTransfer, error)
+	IncrementOTPAttempts(ctx context.Context, id uint) error
+	Expire(ctx context.Context, id uint) error
+	MarkCompleted(ctx context.Context, t *models.WalletTransfer) (bool, error)
+	SumSentSince(ctx context.Context, senderCustomerID uint, since time.Time) (uint64, error)
+}
+
 // CryptoPaymentRequestRepository defines data access for crypto payment requests
 type CryptoPaymentRequestRepository interface {
 	Repository[models.CryptoPaymentRequest, models.CryptoPaymentRequestFilter]
@@ -250,6 +574,12 @@ type CryptoDepositRepository interface {
 	Update(ctx context.Context, deposit *models.CryptoDeposit) error
 }
 
+// CryptoWebhookEventRepository defines data access for applied crypto provider callbacks
+type CryptoWebhookEventRepository interface {
+	Repository[models.CryptoWebhookEvent, models.CryptoWebhookEventFilter]
+	Record(ctx context.Context, event *models.CryptoWebhookEvent) (bool, error)
+}
+
 // DepositReceiptRepository defines data access for offline deposit receipts.
 type DepositReceiptRepository interface {
 	Save(ctx context.Context, receipt *models.DepositReceipt) error
@@ -270,6 +600,15 @@ type AgencyDiscountRepository interface {
 	ExpireActiveByAgencyAndCustomer(ctx context.Context, agencyID, customerID uint, expiredAt time.Time) error
 }
 
+// AgencyDelegationRepository defines operations for customers' grants
+// letting their referrer agency act for them
+type AgencyDelegationRepository interface {
+	Repository[models.AgencyDelegation, models.AgencyDelegationFilter]
+	ActiveByCustomer(ctx context.Context, customerID uint) (*models.AgencyDelegation, error)
+	ListActiveWithCustomer(ctx context.Context, agencyID uint) ([]*AgencyDelegationWithCustomer, error)
+	Update(ctx context.Context, d *models.AgencyDelegation) error
+}
+
 // SegmentPriceFactorRepository defines operations for segment price factors
 type SegmentPriceFactorRepository interface {
 	Repository[models.SegmentPriceFactor, models.SegmentPriceFactorFilter]
@@ -282,6 +621,7 @@ type SegmentPriceFactorRepository interface {
 // TagRepository defines operations for tags
 type TagRepository interface {
 	Repository[models.Tag, models.TagFilter]
+	SoftDeleteRepository
 	ByID(ctx context.Context, id uint) (*models.Tag, error)
 	ByName(ctx context.Context, name string) (*models.Tag, error)
 	ListByIDs(ctx context.Context, ids []uint) ([]*models.Tag, error)
@@ -345,6 +685,7 @@ type ProcessedCampaignRepository interface {
 	Update(ctx context.Context, pc *models.ProcessedCampaign) error
 	AppendAudienceData(ctx context.Context, id uint, ids []int64, codes []string) error
 	UpdateMeta(ctx context.Context, pc *models.ProcessedCampaign) error
+	SentAudienceCount(ctx context.Context, campaignID uint) (uint64, error)
 }
 
 // SentSMSProviderUpdate describes provider fields update identified by tracking id
@@ -353,6 +694,11 @@ type SentSMSProviderUpdate struct {
 	ServerID    *string
 	ErrorCode   *string
 	Description *string
+	// Sender is the line the message was sent from; nil leaves it unchanged
+	Sender *string
+	// PermanentError flags a permanent provider error code; false leaves
+	// the flag unchanged
+	PermanentError bool
 }
 
 // SentBaleSendResultUpdate describes send result fields update identified by tracking id.
@@ -386,11 +732,45 @@ type SentRubikaSendResultUpdate struct {
 }
 
 // SentSMSRepository defines operations for sent SMS rows
+// SMSRecipientRetryRepository stores SMS recipients waiting to be resent
+// after a transient provider error
+type SMSRecipientRetryRepository interface {
+	Repository[models.SMSRecipientRetry, models.SMSRecipientRetryFilter]
+	// Schedule inserts the retries; a tracking ID already being retried
+	// under the same processed campaign is left as it is
+	Schedule(ctx context.Context, retries []*models.SMSRecipientRetry) error
+	// ListDue returns pending retries due at now of running or executed
+	// campaigns, earliest first; retries of paused campaigns wait
+	ListDue(ctx context.Context, now time.Time, limit int) ([]*models.SMSRecipientRetry, error)
+	// ExhaustCancelled ends the pending retries of cancelled campaigns as
+	// exhausted and returns how many it ended
+	ExhaustCancelled(ctx context.Context, now time.Time) (int64, error)
+	Update(ctx context.Context, retry *models.SMSRecipientRetry) error
+	// OutcomesByCampaign counts the retries of a campaign by outcome
+	OutcomesByCampaign(ctx context.Context, campaignID uint) (*SMSRetryOutcomes, error)
+}
+
+// SMSRetryOutcomes summarizes the recipient retries of a campaign.
+// PermanentErrors counts its sent SMS rejected with a permanent error code,
+// whether on the first send or on a resend.
+type SMSRetryOutcomes struct {
+	Retried         int64
+	Pending         int64
+	Recovered       int64
+	Exhausted       int64
+	Permanent       int64
+	Attempts        int64
+	PermanentErrors int64
+}
+
 type SentSMSRepository interface {
 	Repository[models.SentSMS, models.SentSMSFilter]
 	ByID(ctx context.Context, id uint) (*models.SentSMS, error)
 	ListByProcessedCampaign(ctx context.Context, processedCampaignID uint, limit, offset int) ([]*models.SentSMS, error)
 	UpdateProviderFieldsByTrackingIDs(ctx context.Context, updates []SentSMSProviderUpdate) error
+	// RecentPhonesByCustomer returns the canonical numbers the customer's SMS
+	// campaigns other than excludeCampaignID messaged since the given time
+	RecentPhonesByCustomer(ctx context.Context, customerID, excludeCampaignID uint, since time.Time) (map[string]struct{}, error)
 }
 
 // SentBaleMessageRepository defines operations for sent Bale message rows.
@@ -429,6 +809,8 @@ type CampaignStatusJobRepository interface {
 	ByID(ctx context.Context, id uint) (*models.CampaignStatusJob, error)
 	SaveBatch(ctx context.Context, jobs []*models.CampaignStatusJob) error
 	ListDue(ctx context.Context, platform string, now time.Time, limit int) ([]*models.CampaignStatusJob, error)
+	SkipPending(ctx context.Context, correlationID string, now time.Time) (int64, error)
+	ListByProcessedCampaign(ctx context.Context, processedCampaignID uint, limit int) ([]*models.CampaignStatusJob, error)
 	Update(ctx context.Context, job *models.CampaignStatusJob) error
 }
 
@@ -437,6 +819,8 @@ type SMSStatusResultRepository interface {
 	Repository[models.SMSStatusResult, any]
 	SaveBatch(ctx context.Context, rows []*models.SMSStatusResult) error
 	AggregateByCampaign(ctx context.Context, processedCampaignID uint) (*SMSStatusAggregates, error)
+	AggregateByVariant(ctx context.Context, campaignID uint) ([]SMSVariantAggregates, error)
+	AggregateByOperator(ctx context.Context, campaignID uint) ([]SMSOperatorAggregates, error)
 	TrackingResultsByCampaign(ctx context.Context, processedCampaignID uint) ([]SMSTrackingResult, error)
 }
 
@@ -0,0 +1,178 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// JobRepositoryImpl implements JobRepository
+type JobRepositoryImpl struct {
+	*BaseRepository[models.Job, models.JobFilter]
+}
+
+// NewJobRepository creates a new background job repository
+func NewJobRepository(db *gorm.DB) JobRepository {
+	return &JobRepositoryImpl{
+		BaseRepository: NewBaseRepository[models.Job, models.JobFilter](db),
+	}
+}
+
+// ByUUID retrieves a job by its UUID
+func (r *JobRepositoryImpl) ByUUID(ctx context.Context, id uuid.UUID) (*models.Job, error) {
+	db := r.getDB(ctx)
+	var job models.Job
+	if err := db.Where("uuid = ?", id).Last(&job).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &job, nil
+}
+
+// ClaimNext marks the pending job that has been due the longest, or a
+// running job whose worker locked it before staleBefore, as running, counts
+// the attempt and returns it. Concurrent workers never claim the same job.
+// Returns nil when there is nothing to do.
+func (r *JobRepositoryImpl) ClaimNext(ctx context.Context, staleBefore time.Time) (*models.Job, error) {
+	db := r.getDB(ctx)
+	now := utils.UTCNow()
+	var jobs []*models.Job
+	err := db.Raw(`
+		UPDATE jobs
+		SET status = ?, attempts = attempts + 1, locked_at = ?, updated_at = ?
+		WHERE id = (
+			SELECT id FROM jobs
+			WHERE (status = ? AND run_at <= ?) OR (status = ? AND locked_at < ?)
+			ORDER BY run_at, id
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`,
+		models.JobStatusRunning, now, now,
+		models.JobStatusPending, now, models.JobStatusRunning, staleBefore,
+	).Scan(&jobs).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(jobs) == 0 {
+		return nil, nil
+	}
+	return jobs[0], nil
+}
+
+// Update persists the job's status, attempts and errors
+func (r *JobRepositoryImpl) Update(ctx context.Context, job *models.Job) error {
+	db := r.getDB(ctx)
+	job.UpdatedAt = utils.UTCNow()
+	return db.Save(job).Error
+}
+
+// Requeue makes a dead job pending again with a fresh set of attempts.
+// Returns false when the job is not dead, e.g. another admin requeued it.
+func (r *JobRepositoryImpl) Requeue(ctx context.Context, id uint) (bool, error) {
+	db := r.getDB(ctx)
+	now := utils.UTCNow()
+	res := db.Model(&models.Job{}).
+		Where("id = ? AND status = ?", id, models.JobStatusDead).
+		Updates(map[string]any{
+			"status":     models.JobStatusPending,
+			"attempts":   0,
+			"run_at":     now,
+			"locked_at":  nil,
+			"dead_at":    nil,
+			"updated_at": now,
+		})
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
+
+// Cancel stops a pending or dead job from running again. Returns false when
+// the job is in any other state, e.g. a worker is running it.
+func (r *JobRepositoryImpl) Cancel(ctx context.Context, id uint) (bool, error) {
+	db := r.getDB(ctx)
+	now := utils.UTCNow()
+	res := db.Model(&models.Job{}).
+		Where("id = ? AND status IN ?", id, []models.JobStatus{models.JobStatusPending, models.JobStatusDead}).
+		Updates(map[string]any{
+			"status":       models.JobStatusCancelled,
+			"cancelled_at": now,
+			"locked_at":    nil,
+			"updated_at":   now,
+		})
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
+
+// DeleteSucceededBefore removes jobs that succeeded before the given time
+// and returns how many were removed
+func (r *JobRepositoryImpl) DeleteSucceededBefore(ctx context.Context, before time.Time) (int64, error) {
+	db := r.getDB(ctx)
+	res := db.Where("status = ? AND completed_at < ?", models.JobStatusSucceeded, before).Delete(&models.Job{})
+	return res.RowsAffected, res.Error
+}
+
+// ByFilter returns jobs matching the filter
+func (r *JobRepositoryImpl) ByFilter(ctx context.Context, filter models.JobFilter, orderBy string, limit, offset int) ([]*models.Job, error) {
+	db := r.applyFilter(r.getDB(ctx).Model(&models.Job{}), filter)
+	if orderBy == "" {
+		orderBy = "id DESC"
+	}
+	db = db.Order(orderBy)
+	if limit > 0 {
+		db = db.Limit(limit)
+	}
+	if offset > 0 {
+		db = db.Offset(offset)
+	}
+
+	var jobs []*models.Job
+	if err := db.Find(&jobs).Error; err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// Count returns the number of jobs matching the filter
+func (r *JobRepositoryImpl) Count(ctx context.Context, filter models.JobFilter) (int64, error) {
+	var count int64
+	if err := r.applyFilter(r.getDB(ctx).Model(&models.Job{}), filter).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// Exists checks whether any job matches the filter
+func (r *JobRepositoryImpl) Exists(ctx context.Context, filter models.JobFilter) (bool, error) {
+	count, err := r.Count(ctx, filter)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *JobRepositoryImpl) applyFilter(query *gorm.DB, filter models.JobFilter) *gorm.DB {
+	if filter.ID != nil {
+		query = query.Where("id = ?", *filter.ID)
+	}
+	if filter.UUID != nil {
+		query = query.Where("uuid = ?", *filter.UUID)
+	}
+	if filter.Type != nil {
+		query = query.Where("type = ?", *filter.Type)
+	}
+	if filter.Status != nil {
+		query = query.Where("status = ?", *filter.Status)
+	}
+	return query
+}
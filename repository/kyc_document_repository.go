@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// KYCDocumentRepositoryImpl implements KYCDocumentRepository
+type KYCDocumentRepositoryImpl struct {
+	*BaseRepository[models.KYCDocument, models.KYCDocumentFilter]
+}
+
+// NewKYCDocumentRepository creates a new KYC document repository
+func NewKYCDocumentRepository(db *gorm.DB) KYCDocumentRepository {
+	return &KYCDocumentRepositoryImpl{
+		BaseRepository: NewBaseRepository[models.KYCDocument, models.KYCDocumentFilter](db),
+	}
+}
+
+// ByUUID returns a document of the customer, or nil when there is none
+func (r *KYCDocumentRepositoryImpl) ByUUID(ctx context.Context, customerID uint, id uuid.UUID) (*models.KYCDocument, error) {
+	var doc models.KYCDocument
+	err := r.getDB(ctx).Where("customer_id = ? AND uuid = ?", customerID, id).First(&doc).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// Current returns the customer's documents that are not superseded, one per kind
+func (r *KYCDocumentRepositoryImpl) Current(ctx context.Context, customerID uint) ([]*models.KYCDocument, error) {
+	return r.ByFilter(ctx, models.KYCDocumentFilter{CustomerID: &customerID, Current: utils.ToPtr(true)}, "kind ASC", 0, 0)
+}
+
+// Supersede marks the customer's current document of kind as superseded and
+// returns how many rows changed
+func (r *KYCDocumentRepositoryImpl) Supersede(ctx context.Context, customerID uint, kind models.KYCDocumentKind, at time.Time) (int64, error) {
+	res := r.getDB(ctx).Model(&models.KYCDocument{}).
+		Where("customer_id = ? AND kind = ? AND superseded_at IS NULL", customerID, kind).
+		Update("superseded_at", at)
+	return res.RowsAffected, res.Error
+}
+
+// ByFilter returns KYC documents matching the filter
+func (r *KYCDocumentRepositoryImpl) ByFilter(ctx context.Context, filter models.KYCDocumentFilter, orderBy string, limit, offset int) ([]*models.KYCDocument, error) {
+	db := r.applyFilter(r.getDB(ctx).Model(&models.KYCDocument{}), filter)
+	if orderBy == "" {
+		orderBy = "id DESC"
+	}
+	db = db.Order(orderBy)
+	if limit > 0 {
+		db = db.Limit(limit)
+	}
+	if offset > 0 {
+		db = db.Offset(offset)
+	}
+
+	var docs []*models.KYCDocument
+	if err := db.Find(&docs).Error; err != nil {
+		return nil, err
+	}
+	return docs, nil
+}
+
+// Count returns the number of KYC documents matching the filter
+func (r *KYCDocumentRepositoryImpl) Count(ctx context.Context, filter models.KYCDocumentFilter) (int64, error) {
+	var count int64
+	if err := r.applyFilter(r.getDB(ctx).Model(&models.KYCDocument{}), filter).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// Exists checks whether any KYC document matches the filter
+func (r *KYCDocumentRepositoryImpl) Exists(ctx context.Context, filter models.KYCDocumentFilter) (bool, error) {
+	count, err := r.Count(ctx, filter)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *KYCDocumentRepositoryImpl) applyFilter(query *gorm.DB, filter models.KYCDocumentFilter) *gorm.DB {
+	if filter.ID != nil {
+		query = query.Where("id = ?", *filter.ID)
+	}
+	if filter.UUID != nil {
+		query = query.Where("uuid = ?", *filter.UUID)
+	}
+	if filter.CustomerID != nil {
+		query = query.Where("customer_id = ?", *filter.CustomerID)
+	}
+	if filter.Kind != nil {
+		query = query.Where("kind = ?", *filter.Kind)
+	}
+	if filter.Current != nil {
+		if *filter.Current {
+			query = query.Where("superseded_at IS NULL")
+		} else {
+			query = query.Where("superseded_at IS NOT NULL")
+		}
+	}
+	return query
+}
@@ -72,6 +72,9 @@ func (r *LineNumberRepositoryImpl) ByValue(ctx context.Context, value string) (*
 
 // applyFilter applies filter criteria to a GORM query
 func (r *LineNumberRepositoryImpl) applyFilter(query *gorm.DB, filter models.LineNumberFilter) *gorm.DB {
+	if filter.IncludeDeleted {
+		query = query.Unscoped()
+	}
 	if filter.ID != nil {
 		query = query.Where("id = ?", *filter.ID)
 	}
@@ -90,6 +93,9 @@ func (r *LineNumberRepositoryImpl) applyFilter(query *gorm.DB, filter models.Lin
 	if filter.Priority != nil {
 		query = query.Where("priority = ?", *filter.Priority)
 	}
+	if filter.Pool != nil {
+		query = query.Where("pool = ?", *filter.Pool)
+	}
 	if filter.CreatedAfter != nil {
 		query = query.Where("created_at > ?", *filter.CreatedAfter)
 	}
@@ -188,6 +194,28 @@ func (r *LineNumberRepositoryImpl) Update(ctx context.Context, line *models.Line
 	if line.IsActive != nil {
 		updates["is_active"] = *line.IsActive
 	}
+	if line.Operator != nil {
+		updates["operator"] = *line.Operator
+	}
+	if line.Tier != nil {
+		updates["tier"] = *line.Tier
+	}
+	if line.RatePerSecond != nil {
+		updates["rate_per_second"] = *line.RatePerSecond
+	}
+	if line.Burst != nil {
+		updates["burst"] = *line.Burst
+	}
+	// An empty pool removes the line from its pool
+	if line.Pool != nil && *line.Pool == "" {
+		updates["pool"] = nil
+	} else if line.Pool != nil {
+		updates["pool"] = *line.Pool
+	}
+	// A non-nil, empty list certifies the line for every operator
+	if line.CertifiedOperators != nil {
+		updates["certified_operators"] = line.CertifiedOperators
+	}
 
 	result := db.Model(&models.LineNumber{}).
 		Where("id = ?", line.ID).
@@ -242,6 +270,28 @@ func (r *LineNumberRepositoryImpl) UpdateBatch(ctx context.Context, lines []*mod
 		if line.IsActive != nil {
 			updates["is_active"] = *line.IsActive
 		}
+		if line.Operator != nil {
+			updates["operator"] = *line.Operator
+		}
+		if line.Tier != nil {
+			updates["tier"] = *line.Tier
+		}
+		if line.RatePerSecond != nil {
+			updates["rate_per_second"] = *line.RatePerSecond
+		}
+		if line.Burst != nil {
+			updates["burst"] = *line.Burst
+		}
+		// An empty pool removes the line from its pool
+		if line.Pool != nil && *line.Pool == "" {
+			updates["pool"] = nil
+		} else if line.Pool != nil {
+			updates["pool"] = *line.Pool
+		}
+		// A non-nil, empty list certifies the line for every operator
+		if line.CertifiedOperators != nil {
+			updates["certified_operators"] = line.CertifiedOperators
+		}
 		if err := db.Model(&models.LineNumber{}).
 			Where("id = ?", line.ID).
 			Updates(updates).Error; err != nil {
@@ -250,3 +300,13 @@ func (r *LineNumberRepositoryImpl) UpdateBatch(ctx context.Context, lines []*mod
 	}
 	return nil
 }
+
+// SoftDelete marks a line number deleted, leaving it out of later queries
+func (r *LineNumberRepositoryImpl) SoftDelete(ctx context.Context, id uint) (bool, error) {
+	return r.softDelete(ctx, id)
+}
+
+// Restore brings back a soft-deleted line number
+func (r *LineNumberRepositoryImpl) Restore(ctx context.Context, id uint) (bool, error) {
+	return r.restore(ctx, id)
+}
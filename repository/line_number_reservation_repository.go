@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"gorm.io/gorm"
+)
+
+// LineNumberReservationRepositoryImpl implements LineNumberReservationRepository
+type LineNumberReservationRepositoryImpl struct {
+	*BaseRepository[models.LineNumberReservation, models.LineNumberReservationFilter]
+}
+
+// NewLineNumberReservationRepository creates a new line number reservation repository
+func NewLineNumberReservationRepository(db *gorm.DB) LineNumberReservationRepository {
+	return &LineNumberReservationRepositoryImpl{
+		BaseRepository: NewBaseRepository[models.LineNumberReservation, models.LineNumberReservationFilter](db),
+	}
+}
+
+// LockLineNumber acquires a transaction-scoped advisory lock serializing
+// reservations of one line number
+func (r *LineNumberReservationRepositoryImpl) LockLineNumber(ctx context.Context, lineNumberID uint) error {
+	return r.getDB(ctx).Exec("SELECT pg_advisory_xact_lock(hashtext('line_number_reservation'), ?)", int64(lineNumberID)).Error
+}
+
+// ActiveByLineNumberID returns the reservation holding a line at the given
+// time, or nil when the line is free
+func (r *LineNumberReservationRepositoryImpl) ActiveByLineNumberID(ctx context.Context, lineNumberID uint, at time.Time) (*models.LineNumberReservation, error) {
+	var reservation models.LineNumberReservation
+	err := r.applyFilter(r.getDB(ctx), models.LineNumberReservationFilter{LineNumberID: &lineNumberID, ActiveAt: &at}).
+		Order("id DESC").
+		First(&reservation).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &reservation, nil
+}
+
+// Release ends a reservation early; it is a no-op for already released ones
+func (r *LineNumberReservationRepositoryImpl) Release(ctx context.Context, id uint, at time.Time) error {
+	return r.getDB(ctx).Model(&models.LineNumberReservation{}).
+		Where("id = ? AND released_at IS NULL", id).
+		Updates(map[string]any{"released_at": at, "updated_at": at}).Error
+}
+
+// ByFilter returns reservations matching the filter with their line numbers
+func (r *LineNumberReservationRepositoryImpl) ByFilter(ctx context.Context, filter models.LineNumberReservationFilter, orderBy string, limit, offset int) ([]*models.LineNumberReservation, error) {
+	db := r.applyFilter(r.getDB(ctx).Model(&models.LineNumberReservation{}), filter).Preload("LineNumber")
+	if orderBy == "" {
+		orderBy = "id DESC"
+	}
+	db = db.Order(orderBy)
+	if limit > 0 {
+		db = db.Limit(limit)
+	}
+	if offset > 0 {
+		db = db.Offset(offset)
+	}
+
+	var reservations []*models.LineNumberReservation
+	if err := db.Find(&reservations).Error; err != nil {
+		return nil, err
+	}
+	return reservations, nil
+}
+
+// Count returns the number of reservations matching the filter
+func (r *LineNumberReservationRepositoryImpl) Count(ctx context.Context, filter models.LineNumberReservationFilter) (int64, error) {
+	var count int64
+	if err := r.applyFilter(r.getDB(ctx).Model(&models.LineNumberReservation{}), filter).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// Exists checks whether any reservation matches the filter
+func (r *LineNumberReservationRepositoryImpl) Exists(ctx context.Context, filter models.LineNumberReservationFilter) (bool, error) {
+	count, err := r.Count(ctx, filter)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *LineNumberReservationRepositoryImpl) applyFilter(query *gorm.DB, filter models.LineNumberReservationFilter) *gorm.DB {
+	if filter.ID != nil {
+		query = query.Where("id = ?", *filter.ID)
+	}
+	if filter.UUID != nil {
+		query = query.Where("uuid = ?", *filter.UUID)
+	}
+	if filter.LineNumberID != nil {
+		query = query.Where("line_number_id = ?", *filter.LineNumberID)
+	}
+	if filter.CustomerID != nil {
+		query = query.Where("customer_id = ?", *filter.CustomerID)
+	}
+	if filter.ActiveAt != nil {
+		query = query.Where("released_at IS NULL AND starts_at <= ? AND expires_at > ?", *filter.ActiveAt, *filter.ActiveAt)
+	}
+	return query
+}
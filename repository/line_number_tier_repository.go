@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"gorm.io/gorm"
+)
+
+// LineNumberTierRepositoryImpl implements LineNumberTierRepository
+type LineNumberTierRepositoryImpl struct {
+	*BaseRepository[models.LineNumberTier, models.LineNumberTierFilter]
+}
+
+// NewLineNumberTierRepository creates a new line number tier repository
+func NewLineNumberTierRepository(db *gorm.DB) LineNumberTierRepository {
+	return &LineNumberTierRepositoryImpl{
+		BaseRepository: NewBaseRepository[models.LineNumberTier, models.LineNumberTierFilter](db),
+	}
+}
+
+// ByID returns a tier by id, or nil when not found
+func (r *LineNumberTierRepositoryImpl) ByID(ctx context.Context, id uint) (*models.LineNumberTier, error) {
+	var tier models.LineNumberTier
+	err := r.getDB(ctx).Where("id = ?", id).First(&tier).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &tier, nil
+}
+
+// ByName returns a tier by name, or nil when not found
+func (r *LineNumberTierRepositoryImpl) ByName(ctx context.Context, name string) (*models.LineNumberTier, error) {
+	var tier models.LineNumberTier
+	err := r.getDB(ctx).Where("name = ?", name).First(&tier).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &tier, nil
+}
+
+// Update persists the mutable fields of a tier
+func (r *LineNumberTierRepositoryImpl) Update(ctx context.Context, tier *models.LineNumberTier) error {
+	return r.getDB(ctx).Model(&models.LineNumberTier{}).
+		Where("id = ?", tier.ID).
+		Updates(map[string]any{
+			"display_name":              tier.DisplayName,
+			"description":               tier.Description,
+			"reservation_price_per_day": tier.ReservationPricePerDay,
+			"max_reservation_days":      tier.MaxReservationDays,
+			"is_active":                 tier.IsActive,
+			"updated_at":                tier.UpdatedAt,
+		}).Error
+}
+
+// ByFilter returns tiers matching the filter
+func (r *LineNumberTierRepositoryImpl) ByFilter(ctx context.Context, filter models.LineNumberTierFilter, orderBy string, limit, offset int) ([]*models.LineNumberTier, error) {
+	db := r.applyFilter(r.getDB(ctx).Model(&models.LineNumberTier{}), filter)
+	if orderBy == "" {
+		orderBy = "id ASC"
+	}
+	db = db.Order(orderBy)
+	if limit > 0 {
+		db = db.Limit(limit)
+	}
+	if offset > 0 {
+		db = db.Offset(offset)
+	}
+
+	var tiers []*models.LineNumberTier
+	if err := db.Find(&tiers).Error; err != nil {
+		return nil, err
+	}
+	return tiers, nil
+}
+
+// Count returns the number of tiers matching the filter
+func (r *LineNumberTierRepositoryImpl) Count(ctx context.Context, filter models.LineNumberTierFilter) (int64, error) {
+	var count int64
+	if err := r.applyFilter(r.getDB(ctx).Model(&models.LineNumberTier{}), filter).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// Exists checks whether any tier matches the filter
+func (r *LineNumberTierRepositoryImpl) Exists(ctx context.Context, filter models.LineNumberTierFilter) (bool, error) {
+	count, err := r.Count(ctx, filter)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *LineNumberTierRepositoryImpl) applyFilter(query *gorm.DB, filter models.LineNumberTierFilter) *gorm.DB {
+	if filter.ID != nil {
+		query = query.Where("id = ?", *filter.ID)
+	}
+	if filter.Name != nil {
+		query = query.Where("name = ?", *filter.Name)
+	}
+	if filter.IsActive != nil {
+		query = query.Where("is_active = ?", *filter.IsActive)
+	}
+	return query
+}
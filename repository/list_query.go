@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"github.com/amirphl/Yamata-no-Orochi/listquery"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// applyListConditions adds the filter conditions of an admin list query.
+// Columns come from the listing's listquery.Fields and values are typed by
+// listquery, so only the values are bound.
+func applyListConditions(db *gorm.DB, q *listquery.Query) *gorm.DB {
+	if q == nil {
+		return db
+	}
+	for _, c := range q.Conditions {
+		switch c.Operator {
+		case listquery.OpEq:
+			db = db.Where(c.Column+" = ?", c.Values[0])
+		case listquery.OpNe:
+			db = db.Where(c.Column+" <> ?", c.Values[0])
+		case listquery.OpGt:
+			db = db.Where(c.Column+" > ?", c.Values[0])
+		case listquery.OpGte:
+			db = db.Where(c.Column+" >= ?", c.Values[0])
+		case listquery.OpLt:
+			db = db.Where(c.Column+" < ?", c.Values[0])
+		case listquery.OpLte:
+			db = db.Where(c.Column+" <= ?", c.Values[0])
+		case listquery.OpIn:
+			db = db.Where(c.Column+" IN ?", c.Values)
+		case listquery.OpNin:
+			db = db.Where(c.Column+" NOT IN ?", c.Values)
+		case listquery.OpContains:
+			db = db.Where(c.Column+" ILIKE ?", "%"+escapeLikePattern(c.Values[0].(string))+"%")
+		case listquery.OpNull:
+			if c.Values[0].(bool) {
+				db = db.Where(c.Column + " IS NULL")
+			} else {
+				db = db.Where(c.Column + " IS NOT NULL")
+			}
+		}
+	}
+	return db
+}
+
+// applyListSorts orders by the sorts of an admin list query. They come before
+// any order the repository adds, which then only breaks ties.
+func applyListSorts(db *gorm.DB, q *listquery.Query) *gorm.DB {
+	if q == nil {
+		return db
+	}
+	for _, s := range q.Sorts {
+		db = db.Order(clause.OrderByColumn{Column: clause.Column{Name: s.Column, Raw: true}, Desc: s.Desc})
+	}
+	return db
+}
@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"gorm.io/gorm"
+)
+
+// MoadianSubmissionRepositoryImpl implements MoadianSubmissionRepository
+type MoadianSubmissionRepositoryImpl struct {
+	*BaseRepository[models.MoadianSubmission, models.MoadianSubmissionFilter]
+}
+
+// NewMoadianSubmissionRepository creates a new Moadian submission repository
+func NewMoadianSubmissionRepository(db *gorm.DB) MoadianSubmissionRepository {
+	return &MoadianSubmissionRepositoryImpl{
+		BaseRepository: NewBaseRepository[models.MoadianSubmission, models.MoadianSubmissionFilter](db),
+	}
+}
+
+// EnqueueIssued queues up to limit tax invoices that have no submission yet,
+// oldest first, and returns how many it queued
+func (r *MoadianSubmissionRepositoryImpl) EnqueueIssued(ctx context.Context, limit int) (int64, error) {
+	now := utils.UTCNow()
+	res := r.getDB(ctx).Exec(`
+		INSERT INTO moadian_submissions (tax_invoice_id, payment_request_id, status, next_attempt_at, created_at, updated_at)
+		SELECT ti.id, ti.payment_request_id, ?, ?, ?, ?
+		FROM tax_invoices ti
+		WHERE NOT EXISTS (SELECT 1 FROM moadian_submissions ms WHERE ms.tax_invoice_id = ti.id)
+		ORDER BY ti.id
+		LIMIT ?
+		ON CONFLICT (tax_invoice_id) DO NOTHING`,
+		models.MoadianSubmissionStatusPending, now, now, now, limit,
+	)
+	return res.RowsAffected, res.Error
+}
+
+// ClaimNextDue marks the oldest pending submission due by now, or a
+// processing one whose worker stopped updating it before staleBefore, as
+// processing and returns it. Concurrent workers never claim the same
+// submission. Returns nil when there is nothing to do.
+func (r *MoadianSubmissionRepositoryImpl) ClaimNextDue(ctx context.Context, now, staleBefore time.Time) (*models.MoadianSubmission, error) {
+	var subs []*models.MoadianSubmission
+	err := r.getDB(ctx).Raw(`
+		UPDATE moadian_submissions
+		SET status = ?, updated_at = ?
+		WHERE id = (
+			SELECT id FROM moadian_submissions
+			WHERE (status = ? AND next_attempt_at <= ?) OR (status = ? AND updated_at < ?)
+			ORDER BY next_attempt_at, id
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`,
+		models.MoadianSubmissionStatusProcessing, utils.UTCNow(),
+		models.MoadianSubmissionStatusPending, now, models.MoadianSubmissionStatusProcessing, staleBefore,
+	).Scan(&subs).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(subs) == 0 {
+		return nil, nil
+	}
+	return subs[0], nil
+}
+
+// ListAwaitingResult returns up to limit submitted invoices whose result is
+// due to be looked up by now
+func (r *MoadianSubmissionRepositoryImpl) ListAwaitingResult(ctx context.Context, now time.Time, limit int) ([]*models.MoadianSubmission, error) {
+	var subs []*models.MoadianSubmission
+	err := r.getDB(ctx).
+		Where("status = ? AND next_attempt_at <= ?", models.MoadianSubmissionStatusSubmitted, now).
+		Order("next_attempt_at ASC, id ASC").
+		Limit(limit).
+		Find(&subs).Error
+	if err != nil {
+		return nil, err
+	}
+	return subs, nil
+}
+
+// Update persists the submission's status, attempts and result
+func (r *MoadianSubmissionRepositoryImpl) Update(ctx context.Context, sub *models.MoadianSubmission) error {
+	sub.UpdatedAt = utils.UTCNow()
+	return r.getDB(ctx).Save(sub).Error
+}
+
+// ByFilter returns submissions matching the filter
+func (r *MoadianSubmissionRepositoryImpl) ByFilter(ctx context.Context, filter models.MoadianSubmissionFilter, orderBy string, limit, offset int) ([]*models.MoadianSubmission, error) {
+	db := r.applyFilter(r.getDB(ctx).Model(&models.MoadianSubmission{}), filter)
+	if orderBy == "" {
+		orderBy = "id DESC"
+	}
+	db = db.Order(orderBy)
+	if limit > 0 {
+		db = db.Limit(limit)
+	}
+	if offset > 0 {
+		db = db.Offset(offset)
+	}
+
+	var subs []*models.MoadianSubmission
+	if err := db.Find(&subs).Error; err != nil {
+		return nil, err
+	}
+	return subs, nil
+}
+
+// Count returns the number of submissions matching the filter
+func (r *MoadianSubmissionRepositoryImpl) Count(ctx context.Context, filter models.MoadianSubmissionFilter) (int64, error) {
+	var count int64
+	if err := r.applyFilter(r.getDB(ctx).Model(&models.MoadianSubmission{}), filter).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// Exists checks whether any submission matches the filter
+func (r *MoadianSubmissionRepositoryImpl) Exists(ctx context.Context, filter models.MoadianSubmissionFilter) (bool, error) {
+	count, err := r.Count(ctx, filter)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *MoadianSubmissionRepositoryImpl) applyFilter(query *gorm.DB, filter models.MoadianSubmissionFilter) *gorm.DB {
+	if filter.ID != nil {
+		query = query.Where("id = ?", *filter.ID)
+	}
+	if filter.UUID != nil {
+		query = query.Where("uuid = ?", *filter.UUID)
+	}
+	if filter.TaxInvoiceID != nil {
+		query = query.Where("tax_invoice_id = ?", *filter.TaxInvoiceID)
+	}
+	if filter.Status != nil {
+		query = query.Where("status = ?", *filter.Status)
+	}
+	return query
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"gorm.io/gorm"
+)
+
+// NotificationRepositoryImpl implements NotificationRepository
+type NotificationRepositoryImpl struct {
+	*BaseRepository[models.Notification, models.NotificationFilter]
+}
+
+// NewNotificationRepository creates a new notification repository
+func NewNotificationRepository(db *gorm.DB) NotificationRepository {
+	return &NotificationRepositoryImpl{
+		BaseRepository: NewBaseRepository[models.Notification, models.NotificationFilter](db),
+	}
+}
+
+// Record inserts a notification. Inside a transaction the insert runs in a
+// savepoint, so a failed insert leaves the transaction of the change it
+// reports usable.
+func (r *NotificationRepositoryImpl) Record(ctx context.Context, notification *models.Notification) error {
+	return r.getDB(ctx).Transaction(func(tx *gorm.DB) error {
+		return tx.Create(notification).Error
+	})
+}
+
+// MarkRead marks the given unread notifications of a customer as read and
+// returns how many changed. Nil ids marks every unread notification.
+func (r *NotificationRepositoryImpl) MarkRead(ctx context.Context, customerID uint, ids []uint) (int64, error) {
+	query := r.getDB(ctx).Model(&models.Notification{}).
+		Where("customer_id = ? AND read_at IS NULL", customerID)
+	if ids != nil {
+		query = query.Where("id IN ?", ids)
+	}
+	res := query.Update("read_at", time.Now().UTC())
+	return res.RowsAffected, res.Error
+}
+
+// ByFilter returns notifications matching the filter
+func (r *NotificationRepositoryImpl) ByFilter(ctx context.Context, filter models.NotificationFilter, orderBy string, limit, offset int) ([]*models.Notification, error) {
+	db := r.applyFilter(r.getDB(ctx).Model(&models.Notification{}), filter)
+	if orderBy == "" {
+		orderBy = "id DESC"
+	}
+	db = db.Order(orderBy)
+	if limit > 0 {
+		db = db.Limit(limit)
+	}
+	if offset > 0 {
+		db = db.Offset(offset)
+	}
+
+	var notifications []*models.Notification
+	if err := db.Find(&notifications).Error; err != nil {
+		return nil, err
+	}
+	return notifications, nil
+}
+
+// Count returns the number of notifications matching the filter
+func (r *NotificationRepositoryImpl) Count(ctx context.Context, filter models.NotificationFilter) (int64, error) {
+	var count int64
+	if err := r.applyFilter(r.getDB(ctx).Model(&models.Notification{}), filter).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// Exists checks whether any notification matches the filter
+func (r *NotificationRepositoryImpl) Exists(ctx context.Context, filter models.NotificationFilter) (bool, error) {
+	count, err := r.Count(ctx, filter)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *NotificationRepositoryImpl) applyFilter(query *gorm.DB, filter models.NotificationFilter) *gorm.DB {
+	if filter.ID != nil {
+		query = query.Where("id = ?", *filter.ID)
+	}
+	if filter.CustomerID != nil {
+		query = query.Where("customer_id = ?", *filter.CustomerID)
+	}
+	if filter.Kind != nil {
+		query = query.Where("kind = ?", *filter.Kind)
+	}
+	if filter.Unread {
+		query = query.Where("read_at IS NULL")
+	}
+	if filter.BeforeID != nil {
+		query = query.Where("id < ?", *filter.BeforeID)
+	}
+	return query
+}
@@ -0,0 +1,8 @@
+package repository
+
+import "errors"
+
+// ErrVersionConflict is returned by compare-and-swap updates when the row
+// changed since it was read. The caller's transaction is stale and should be
+// rolled back and run again from fresh reads.
+var ErrVersionConflict = errors.New("row was changed by a concurrent update")
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"gorm.io/gorm"
+)
+
+// PartitionArchiveRepositoryImpl implements PartitionArchiveRepository
+type PartitionArchiveRepositoryImpl struct {
+	*BaseRepository[models.PartitionArchive, models.PartitionArchiveFilter]
+}
+
+// NewPartitionArchiveRepository creates a new partition archive repository
+func NewPartitionArchiveRepository(db *gorm.DB) PartitionArchiveRepository {
+	return &PartitionArchiveRepositoryImpl{
+		BaseRepository: NewBaseRepository[models.PartitionArchive, models.PartitionArchiveFilter](db),
+	}
+}
+
+// Overlapping returns the archives of a table whose range intersects
+// [from, to), oldest first
+func (r *PartitionArchiveRepositoryImpl) Overlapping(ctx context.Context, parentTable string, from, to time.Time) ([]*models.PartitionArchive, error) {
+	return r.ByFilter(ctx, models.PartitionArchiveFilter{
+		ParentTable:  &parentTable,
+		OverlapsFrom: &from,
+		OverlapsTo:   &to,
+	}, "range_end ASC", 0, 0)
+}
+
+// ByFilter returns partition archives matching the filter
+func (r *PartitionArchiveRepositoryImpl) ByFilter(ctx context.Context, filter models.PartitionArchiveFilter, orderBy string, limit, offset int) ([]*models.PartitionArchive, error) {
+	db := r.applyFilter(r.getDB(ctx).Model(&models.PartitionArchive{}), filter)
+	if orderBy == "" {
+		orderBy = "id DESC"
+	}
+	db = db.Order(orderBy)
+	if limit > 0 {
+		db = db.Limit(limit)
+	}
+	if offset > 0 {
+		db = db.Offset(offset)
+	}
+
+	var archives []*models.PartitionArchive
+	if err := db.Find(&archives).Error; err != nil {
+		return nil, err
+	}
+	return archives, nil
+}
+
+// Count returns the number of partition archives matching the filter
+func (r *PartitionArchiveRepositoryImpl) Count(ctx context.Context, filter models.PartitionArchiveFilter) (int64, error) {
+	var count int64
+	if err := r.applyFilter(r.getDB(ctx).Model(&models.PartitionArchive{}), filter).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// Exists checks whether any partition archive matches the filter
+func (r *PartitionArchiveRepositoryImpl) Exists(ctx context.Context, filter models.PartitionArchiveFilter) (bool, error) {
+	count, err := r.Count(ctx, filter)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *PartitionArchiveRepositoryImpl) applyFilter(query *gorm.DB, filter models.PartitionArchiveFilter) *gorm.DB {
+	if filter.ID != nil {
+		query = query.Where("id = ?", *filter.ID)
+	}
+	if filter.UUID != nil {
+		query = query.Where("uuid = ?", *filter.UUID)
+	}
+	if filter.ParentTable != nil {
+		query = query.Where("parent_table = ?", *filter.ParentTable)
+	}
+	if filter.PartitionName != nil {
+		query = query.Where("partition_name = ?", *filter.PartitionName)
+	}
+	if filter.OverlapsFrom != nil {
+		query = query.Where("range_end > ?", *filter.OverlapsFrom)
+	}
+	if filter.OverlapsTo != nil {
+		query = query.Where("(range_start IS NULL OR range_start < ?)", *filter.OverlapsTo)
+	}
+	return query
+}
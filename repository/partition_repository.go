@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/jackc/pgx/v5"
+	"gorm.io/gorm"
+)
+
+// partitionBoundPattern matches pg_get_expr output of a range partition bound,
+// e.g. FOR VALUES FROM ('2026-10-01 00:00:00+00') TO ('2026-11-01 00:00:00+00')
+var partitionBoundPattern = regexp.MustCompile(`^FOR VALUES FROM \((MINVALUE|'[^']*')\) TO \((MAXVALUE|'[^']*')\)$`)
+
+var timestamptzLayouts = []string{
+	"2006-01-02 15:04:05.999999999-07",
+	"2006-01-02 15:04:05.999999999-07:00",
+}
+
+// NewPartitionRepository creates a repository managing the monthly partitions
+// of the range partitioned tables
+func NewPartitionRepository(db *gorm.DB) PartitionRepository {
+	return &partitionRepository{db: db}
+}
+
+type partitionRepository struct {
+	db *gorm.DB
+}
+
+type partitionRow struct {
+	Name  string
+	Bound string
+}
+
+// ListPartitions returns the partitions attached to a parent table ordered by name
+func (r *partitionRepository) ListPartitions(ctx context.Context, parentTable string) ([]models.TablePartition, error) {
+	var rows []partitionRow
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT c.relname AS name, pg_get_expr(c.relpartbound, c.oid) AS bound
+		FROM pg_inherits i
+		JOIN pg_class c ON c.oid = i.inhrelid
+		WHERE i.inhparent = to_regclass(?)
+		ORDER BY c.relname`, parentTable).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	partitions := make([]models.TablePartition, 0, len(rows))
+	for _, row := range rows {
+		p, err := ParsePartitionBound(row.Name, row.Bound)
+		if err != nil {
+			return nil, err
+		}
+		partitions = append(partitions, p)
+	}
+	return partitions, nil
+}
+
+// EnsureMonthlyPartition creates the partition holding the UTC month of the
+// given time if it does not exist yet and returns its name
+func (r *partitionRepository) EnsureMonthlyPartition(ctx context.Context, parentTable string, month time.Time) (string, error) {
+	var name string
+	err := r.db.WithContext(ctx).
+		Raw("SELECT ensure_monthly_partition(?, ?::date)", parentTable, month.UTC().Format("2006-01-02")).
+		Scan(&name).Error
+	return name, err
+}
+
+// CopyPartitionCSV streams every row of a partition to w as CSV with a header
+// row and returns the number of rows written
+func (r *partitionRepository) CopyPartitionCSV(ctx context.Context, partitionName string, w io.Writer) (int64, error) {
+	query := fmt.Sprintf(
+		"COPY (SELECT * FROM %s ORDER BY id) TO STDOUT WITH (FORMAT csv, HEADER true)",
+		pgx.Identifier{partitionName}.Sanitize(),
+	)
+	var rows int64
+	err := withPgxConn(ctx, r.db, func(conn *pgx.Conn) error {
+		tag, err := conn.PgConn().CopyTo(ctx, w, query)
+		if err != nil {
+			return err
+		}
+		rows = tag.RowsAffected()
+		return nil
+	})
+	return rows, err
+}
+
+// DetachAndDropPartition removes a partition and its rows from the database
+func (r *partitionRepository) DetachAndDropPartition(ctx context.Context, parentTable, partitionName string) error {
+	parent := pgx.Identifier{parentTable}.Sanitize()
+	partition := pgx.Identifier{partitionName}.Sanitize()
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(fmt.Sprintf("ALTER TABLE %s DETACH PARTITION %s", parent, partition)).Error; err != nil {
+			return err
+		}
+		return tx.Exec(fmt.Sprintf("DROP TABLE %s", partition)).Error
+	})
+}
+
+// TryLockMaintenance takes a session-level advisory lock so only one instance
+// maintains partitions at a time; the returned release func must be called
+// when ok is true
+func (r *partitionRepository) TryLockMaintenance(ctx context.Context) (release func(), ok bool, err error) {
+	sqlDB, err := r.db.DB()
+	if err != nil {
+		return nil, false, err
+	}
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock(hashtext('partition_maintenance'))").Scan(&ok); err != nil {
+		conn.Close()
+		return nil, false, err
+	}
+	if !ok {
+		conn.Close()
+		return nil, false, nil
+	}
+	return func() {
+		_, _ = conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock(hashtext('partition_maintenance'))")
+		conn.Close()
+	}, true, nil
+}
+
+// ParsePartitionBound builds a TablePartition from the pg_get_expr text of its
+// partition bound
+func ParsePartitionBound(name, bound string) (models.TablePartition, error) {
+	p := models.TablePartition{Name: name}
+	if bound == "DEFAULT" {
+		p.IsDefault = true
+		return p, nil
+	}
+	m := partitionBoundPattern.FindStringSubmatch(bound)
+	if m == nil {
+		return p, fmt.Errorf("partition %s: unsupported bound %q", name, bound)
+	}
+	var err error
+	if p.RangeStart, err = parsePartitionBoundValue(m[1]); err != nil {
+		return p, fmt.Errorf("partition %s: %w", name, err)
+	}
+	if p.RangeEnd, err = parsePartitionBoundValue(m[2]); err != nil {
+		return p, fmt.Errorf("partition %s: %w", name, err)
+	}
+	return p, nil
+}
+
+func parsePartitionBoundValue(v string) (*time.Time, error) {
+	if v == "MINVALUE" || v == "MAXVALUE" {
+		return nil, nil
+	}
+	t, err := ParseTimestamptz(v[1 : len(v)-1])
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// ParseTimestamptz parses the text form PostgreSQL prints timestamptz values
+// in, as found in partition bounds and COPY output, and returns it in UTC
+func ParseTimestamptz(s string) (time.Time, error) {
+	for _, layout := range timestamptzLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unsupported timestamptz value %q", s)
+}
@@ -4,11 +4,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/amirphl/Yamata-no-Orochi/models"
 	"github.com/amirphl/Yamata-no-Orochi/utils"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // PaymentRequestRepositoryImpl implements PaymentRequestRepository interface
@@ -250,7 +252,9 @@ func (r *PaymentRequestRepositoryImpl) SaveBatch(ctx context.Context, requests [
 	return nil
 }
 
-// Update updates a payment request
+// Update writes every field of a payment request if it is still at the
+// version it was read with, and advances the version. A request changed in
+// the meantime, e.g. by a concurrent callback, fails with ErrVersionConflict.
 func (r *PaymentRequestRepositoryImpl) Update(ctx context.Context, request *models.PaymentRequest) error {
 	db, shouldCommit, err := r.getDBForWrite(ctx)
 	if err != nil {
@@ -267,13 +271,74 @@ func (r *PaymentRequestRepositoryImpl) Update(ctx context.Context, request *mode
 		}()
 	}
 
-	err = db.Save(request).Error
-	if err != nil {
+	// Save would insert the row when the version check matches nothing
+	expected := request.Version
+	request.Version = expected + 1
+	res := db.Model(request).
+		Where("version = ?", expected).
+		Select("*").
+		Omit("id", "created_at", clause.Associations).
+		Updates(request)
+	if err = res.Error; err != nil {
+		request.Version = expected
+		return err
+	}
+	if res.RowsAffected == 0 {
+		request.Version = expected
+		err = ErrVersionConflict
 		return err
 	}
 	return nil
 }
 
+// MarkExpired expires a payment request still in status from, and reports
+// whether it did. A request a callback moved on in the meantime is left as
+// it is.
+func (r *PaymentRequestRepositoryImpl) MarkExpired(ctx context.Context, id uint, from models.PaymentRequestStatus, reason string) (bool, error) {
+	res := r.getDB(ctx).Model(&models.PaymentRequest{}).
+		Where("id = ? AND status = ?", id, from).
+		Updates(map[string]any{
+			"status":        models.PaymentRequestStatusExpired,
+			"status_reason": reason,
+			"updated_at":    utils.UTCNow(),
+			"version":       gorm.Expr("version + 1"),
+		})
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
+
+// SetMoadianStatus mirrors the Moadian submission status of the request's
+// invoice onto the request. updated_at is left alone, as Atipay
+// reconciliation selects completed requests by it.
+func (r *PaymentRequestRepositoryImpl) SetMoadianStatus(ctx context.Context, id uint, status models.MoadianSubmissionStatus, taxID, referenceNumber *string) error {
+	return r.getDB(ctx).Model(&models.PaymentRequest{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"moadian_status":           string(status),
+			"moadian_tax_id":           taxID,
+			"moadian_reference_number": referenceNumber,
+			"version":                  gorm.Expr("version + 1"),
+		}).Error
+}
+
+// ListCompletedAtipay returns the payment requests completed through Atipay
+// between from and to, by when they were last updated
+func (r *PaymentRequestRepositoryImpl) ListCompletedAtipay(ctx context.Context, from, to time.Time) ([]*models.PaymentRequest, error) {
+	var requests []*models.PaymentRequest
+	err := r.getDB(ctx).
+		Where("status = ?", models.PaymentRequestStatusCompleted).
+		Where("payment_reference <> ''").
+		Where("updated_at >= ? AND updated_at < ?", from, to).
+		Order("updated_at ASC").
+		Find(&requests).Error
+	if err != nil {
+		return nil, err
+	}
+	return requests, nil
+}
+
 // LockCustomerInvoiceUUID acquires a transaction-scoped advisory lock for a deposit-receipt invoice UUID.
 func (r *PaymentRequestRepositoryImpl) LockCustomerInvoiceUUID(ctx context.Context, invoiceUUID string) error {
 	db := r.getDB(ctx)
@@ -372,6 +437,9 @@ func (r *PaymentRequestRepositoryImpl) applyFilter(query *gorm.DB, filter models
 	if filter.Status != nil {
 		query = query.Where("status = ?", *filter.Status)
 	}
+	if filter.PaymentChannel != nil {
+		query = query.Where("metadata->>'payment_channel' = ?", *filter.PaymentChannel)
+	}
 	if filter.CreatedAfter != nil {
 		query = query.Where("created_at > ?", *filter.CreatedAfter)
 	}
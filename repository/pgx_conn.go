@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+	"gorm.io/gorm"
+)
+
+// errPgxConnUnavailable is returned by withPgxConn when no pgx connection can
+// be used for the call; callers fall back to plain GORM statements
+var errPgxConnUnavailable = errors.New("pgx connection unavailable")
+
+// withPgxConn runs fn on the underlying pgx connection. Inside a transaction
+// started by WithTransaction fn runs on the transaction's connection, so its
+// commands join the transaction; inside any other transaction the connection
+// is not reachable and errPgxConnUnavailable is returned.
+func withPgxConn(ctx context.Context, db *gorm.DB, fn func(conn *pgx.Conn) error) error {
+	conn, _ := ctx.Value(TxConnContextKey).(*sql.Conn)
+	if conn == nil {
+		if tx, ok := ctx.Value(TxContextKey).(*gorm.DB); ok && tx != nil {
+			return errPgxConnUnavailable
+		}
+		sqlDB, err := db.DB()
+		if err != nil {
+			return errPgxConnUnavailable
+		}
+		if conn, err = sqlDB.Conn(ctx); err != nil {
+			return err
+		}
+		defer conn.Close()
+	}
+
+	return conn.Raw(func(driverConn any) error {
+		stdConn, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			return errPgxConnUnavailable
+		}
+		return fn(stdConn.Conn())
+	})
+}
@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"gorm.io/gorm"
+)
+
+// PostpaidDrawRepositoryImpl implements PostpaidDrawRepository
+type PostpaidDrawRepositoryImpl struct {
+	*BaseRepository[models.PostpaidDraw, models.PostpaidDrawFilter]
+}
+
+// NewPostpaidDrawRepository creates a new postpaid draw repository
+func NewPostpaidDrawRepository(db *gorm.DB) PostpaidDrawRepository {
+	return &PostpaidDrawRepositoryImpl{
+		BaseRepository: NewBaseRepository[models.PostpaidDraw, models.PostpaidDrawFilter](db),
+	}
+}
+
+// UninvoicedTotals sums, per customer, the draws made before the given time
+// that are not on an invoice yet
+func (r *PostpaidDrawRepositoryImpl) UninvoicedTotals(ctx context.Context, before time.Time) ([]models.PostpaidDrawTotal, error) {
+	var totals []models.PostpaidDrawTotal
+	err := r.getDB(ctx).Model(&models.PostpaidDraw{}).
+		Select("customer_id, COALESCE(SUM(amount), 0) AS amount, COUNT(*) AS draws").
+		Where("invoice_id IS NULL AND created_at < ?", before).
+		Group("customer_id").
+		Order("customer_id").
+		Scan(&totals).Error
+	return totals, err
+}
+
+// AssignInvoice puts a customer's uninvoiced draws made before the given time
+// on an invoice and returns how many were assigned
+func (r *PostpaidDrawRepositoryImpl) AssignInvoice(ctx context.Context, customerID uint, before time.Time, invoiceID uint) (int64, error) {
+	res := r.getDB(ctx).Model(&models.PostpaidDraw{}).
+		Where("customer_id = ? AND invoice_id IS NULL AND created_at < ?", customerID, before).
+		Update("invoice_id", invoiceID)
+	return res.RowsAffected, res.Error
+}
+
+// ByFilter returns draws matching the filter
+func (r *PostpaidDrawRepositoryImpl) ByFilter(ctx context.Context, filter models.PostpaidDrawFilter, orderBy string, limit, offset int) ([]*models.PostpaidDraw, error) {
+	db := r.applyFilter(r.getDB(ctx).Model(&models.PostpaidDraw{}), filter)
+	if orderBy == "" {
+		orderBy = "id DESC"
+	}
+	db = db.Order(orderBy)
+	if limit > 0 {
+		db = db.Limit(limit)
+	}
+	if offset > 0 {
+		db = db.Offset(offset)
+	}
+
+	var items []*models.PostpaidDraw
+	if err := db.Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// Count returns the number of draws matching the filter
+func (r *PostpaidDrawRepositoryImpl) Count(ctx context.Context, filter models.PostpaidDrawFilter) (int64, error) {
+	var count int64
+	if err := r.applyFilter(r.getDB(ctx).Model(&models.PostpaidDraw{}), filter).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// Exists checks whether any draw matches the filter
+func (r *PostpaidDrawRepositoryImpl) Exists(ctx context.Context, filter models.PostpaidDrawFilter) (bool, error) {
+	count, err := r.Count(ctx, filter)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *PostpaidDrawRepositoryImpl) applyFilter(query *gorm.DB, filter models.PostpaidDrawFilter) *gorm.DB {
+	if filter.ID != nil {
+		query = query.Where("id = ?", *filter.ID)
+	}
+	if filter.CustomerID != nil {
+		query = query.Where("customer_id = ?", *filter.CustomerID)
+	}
+	if filter.CampaignID != nil {
+		query = query.Where("campaign_id = ?", *filter.CampaignID)
+	}
+	if filter.InvoiceID != nil {
+		query = query.Where("invoice_id = ?", *filter.InvoiceID)
+	}
+	return query
+}
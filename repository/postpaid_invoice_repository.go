@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PostpaidInvoiceRepositoryImpl implements PostpaidInvoiceRepository
+type PostpaidInvoiceRepositoryImpl struct {
+	*BaseRepository[models.PostpaidInvoice, models.PostpaidInvoiceFilter]
+}
+
+// NewPostpaidInvoiceRepository creates a new postpaid invoice repository
+func NewPostpaidInvoiceRepository(db *gorm.DB) PostpaidInvoiceRepository {
+	return &PostpaidInvoiceRepositoryImpl{
+		BaseRepository: NewBaseRepository[models.PostpaidInvoice, models.PostpaidInvoiceFilter](db),
+	}
+}
+
+// ByUUID retrieves an invoice by UUID
+func (r *PostpaidInvoiceRepositoryImpl) ByUUID(ctx context.Context, id uuid.UUID) (*models.PostpaidInvoice, error) {
+	var inv models.PostpaidInvoice
+	if err := r.getDB(ctx).Where("uuid = ?", id).Last(&inv).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &inv, nil
+}
+
+// MarkPaid records the payment of an invoice that is still open, and reports
+// false when it was already paid
+func (r *PostpaidInvoiceRepositoryImpl) MarkPaid(ctx context.Context, inv *models.PostpaidInvoice) (bool, error) {
+	res := r.getDB(ctx).Model(&models.PostpaidInvoice{}).
+		Where("id = ? AND status = ?", inv.ID, models.PostpaidInvoiceStatusOpen).
+		Updates(map[string]any{
+			"status":            models.PostpaidInvoiceStatusPaid,
+			"paid_at":           inv.PaidAt,
+			"paid_by_admin_id":  inv.PaidByAdminID,
+			"payment_reference": inv.PaymentReference,
+			"updated_at":        inv.UpdatedAt,
+		})
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
+
+// ByFilter returns invoices matching the filter
+func (r *PostpaidInvoiceRepositoryImpl) ByFilter(ctx context.Context, filter models.PostpaidInvoiceFilter, orderBy string, limit, offset int) ([]*models.PostpaidInvoice, error) {
+	db := r.applyFilter(r.getDB(ctx).Model(&models.PostpaidInvoice{}), filter)
+	if orderBy == "" {
+		orderBy = "id DESC"
+	}
+	db = db.Order(orderBy)
+	if limit > 0 {
+		db = db.Limit(limit)
+	}
+	if offset > 0 {
+		db = db.Offset(offset)
+	}
+
+	var items []*models.PostpaidInvoice
+	if err := db.Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// Count returns the number of invoices matching the filter
+func (r *PostpaidInvoiceRepositoryImpl) Count(ctx context.Context, filter models.PostpaidInvoiceFilter) (int64, error) {
+	var count int64
+	if err := r.applyFilter(r.getDB(ctx).Model(&models.PostpaidInvoice{}), filter).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// Exists checks whether any invoice matches the filter
+func (r *PostpaidInvoiceRepositoryImpl) Exists(ctx context.Context, filter models.PostpaidInvoiceFilter) (bool, error) {
+	count, err := r.Count(ctx, filter)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *PostpaidInvoiceRepositoryImpl) applyFilter(query *gorm.DB, filter models.PostpaidInvoiceFilter) *gorm.DB {
+	if filter.ID != nil {
+		query = query.Where("id = ?", *filter.ID)
+	}
+	if filter.UUID != nil {
+		query = query.Where("uuid = ?", *filter.UUID)
+	}
+	if filter.CustomerID != nil {
+		query = query.Where("customer_id = ?", *filter.CustomerID)
+	}
+	if filter.Status != nil {
+		query = query.Where("status = ?", *filter.Status)
+	}
+	if filter.PeriodStart != nil {
+		query = query.Where("period_start = ?", *filter.PeriodStart)
+	}
+	if filter.DueBefore != nil {
+		query = query.Where("due_at < ?", *filter.DueBefore)
+	}
+	return query
+}
@@ -123,6 +123,27 @@ func (r *ProcessedCampaignRepositoryImpl) AppendAudienceData(ctx context.Context
 	).Error
 }
 
+// SentAudienceCount returns how many audiences of the latest processed run of
+// a campaign were already handed to the provider, i.e. the position of
+// last_audience_id in audience_ids. It is 0 when the campaign has not been
+// processed or no batch was saved yet.
+func (r *ProcessedCampaignRepositoryImpl) SentAudienceCount(ctx context.Context, campaignID uint) (uint64, error) {
+	db := r.getDB(ctx)
+	var count int64
+	err := db.Raw(
+		`SELECT COALESCE(array_position(audience_ids, last_audience_id), 0)
+		FROM processed_campaigns
+		WHERE campaign_id = ?
+		ORDER BY id DESC
+		LIMIT 1`,
+		campaignID,
+	).Scan(&count).Error
+	if err != nil {
+		return 0, err
+	}
+	return uint64(count), nil
+}
+
 func (r *ProcessedCampaignRepositoryImpl) UpdateMeta(ctx context.Context, pc *models.ProcessedCampaign) (err error) {
 	db, shouldCommit, err := r.getDBForWrite(ctx)
 	if err != nil {
@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PushDeviceRepositoryImpl implements PushDeviceRepository
+type PushDeviceRepositoryImpl struct {
+	*BaseRepository[models.PushDevice, models.PushDeviceFilter]
+}
+
+// NewPushDeviceRepository creates a new push device repository
+func NewPushDeviceRepository(db *gorm.DB) PushDeviceRepository {
+	return &PushDeviceRepositoryImpl{
+		BaseRepository: NewBaseRepository[models.PushDevice, models.PushDeviceFilter](db),
+	}
+}
+
+// Register inserts a device, or moves an already registered token to the
+// customer and refreshes its last_seen_at
+func (r *PushDeviceRepositoryImpl) Register(ctx context.Context, device *models.PushDevice) error {
+	return r.getDB(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "token"}},
+		DoUpdates: clause.AssignmentColumns([]string{"customer_id", "platform", "user_agent", "last_seen_at", "updated_at"}),
+	}).Create(device).Error
+}
+
+// Remove deletes a token of the customer and returns how many rows went
+func (r *PushDeviceRepositoryImpl) Remove(ctx context.Context, customerID uint, token string) (int64, error) {
+	res := r.getDB(ctx).Where("customer_id = ? AND token = ?", customerID, token).Delete(&models.PushDevice{})
+	return res.RowsAffected, res.Error
+}
+
+// RemoveTokens deletes tokens FCM rejected, whoever they belong to
+func (r *PushDeviceRepositoryImpl) RemoveTokens(ctx context.Context, tokens []string) (int64, error) {
+	if len(tokens) == 0 {
+		return 0, nil
+	}
+	res := r.getDB(ctx).Where("token IN ?", tokens).Delete(&models.PushDevice{})
+	return res.RowsAffected, res.Error
+}
+
+// PruneOldest keeps the customer's keep most recently seen devices and
+// deletes the others
+func (r *PushDeviceRepositoryImpl) PruneOldest(ctx context.Context, customerID uint, keep int) (int64, error) {
+	db := r.getDB(ctx)
+	newest := db.Model(&models.PushDevice{}).
+		Select("id").
+		Where("customer_id = ?", customerID).
+		Order("last_seen_at DESC, id DESC").
+		Limit(keep)
+	res := db.Where("customer_id = ? AND id NOT IN (?)", customerID, newest).Delete(&models.PushDevice{})
+	return res.RowsAffected, res.Error
+}
+
+// ByFilter returns push devices matching the filter
+func (r *PushDeviceRepositoryImpl) ByFilter(ctx context.Context, filter models.PushDeviceFilter, orderBy string, limit, offset int) ([]*models.PushDevice, error) {
+	db := r.applyFilter(r.getDB(ctx).Model(&models.PushDevice{}), filter)
+	if orderBy == "" {
+		orderBy = "last_seen_at DESC, id DESC"
+	}
+	db = db.Order(orderBy)
+	if limit > 0 {
+		db = db.Limit(limit)
+	}
+	if offset > 0 {
+		db = db.Offset(offset)
+	}
+
+	var devices []*models.PushDevice
+	if err := db.Find(&devices).Error; err != nil {
+		return nil, err
+	}
+	return devices, nil
+}
+
+// Count returns the number of push devices matching the filter
+func (r *PushDeviceRepositoryImpl) Count(ctx context.Context, filter models.PushDeviceFilter) (int64, error) {
+	var count int64
+	if err := r.applyFilter(r.getDB(ctx).Model(&models.PushDevice{}), filter).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// Exists checks whether any push device matches the filter
+func (r *PushDeviceRepositoryImpl) Exists(ctx context.Context, filter models.PushDeviceFilter) (bool, error) {
+	count, err := r.Count(ctx, filter)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *PushDeviceRepositoryImpl) applyFilter(query *gorm.DB, filter models.PushDeviceFilter) *gorm.DB {
+	if filter.ID != nil {
+		query = query.Where("id = ?", *filter.ID)
+	}
+	if filter.CustomerID != nil {
+		query = query.Where("customer_id = ?", *filter.CustomerID)
+	}
+	if filter.Token != nil {
+		query = query.Where("token = ?", *filter.Token)
+	}
+	return query
+}
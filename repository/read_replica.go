@@ -0,0 +1,24 @@
+package repository
+
+import "gorm.io/gorm"
+
+// ReadReplicaAware is implemented by every repository embedding BaseRepository
+type ReadReplicaAware interface {
+	SetReadReplica(replica *gorm.DB)
+}
+
+// UseReadReplica points the lag-tolerant reads of repos at replica. Repos that
+// do not embed BaseRepository are left on the primary. A nil replica is a no-op.
+func UseReadReplica(replica *gorm.DB, repos ...any) int {
+	if replica == nil {
+		return 0
+	}
+	n := 0
+	for _, repo := range repos {
+		if aware, ok := repo.(ReadReplicaAware); ok {
+			aware.SetReadReplica(replica)
+			n++
+		}
+	}
+	return n
+}
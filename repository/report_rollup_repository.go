@@ -0,0 +1,249 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"gorm.io/gorm"
+)
+
+// sentMessageTables are the per channel tables of sent messages; each has
+// processed_campaign_id, status, parts_delivered and created_at
+var sentMessageTables = []string{"sent_sms", "sent_bale_messages", "sent_rubika_messages", "sent_splus_messages"}
+
+// NewReportRollupRepository creates a repository refreshing and reading the
+// reporting rollups
+func NewReportRollupRepository(db *gorm.DB) ReportRollupRepository {
+	return &reportRollupRepository{db: db}
+}
+
+type reportRollupRepository struct {
+	db *gorm.DB
+}
+
+func (r *reportRollupRepository) getDB(ctx context.Context) *gorm.DB {
+	if tx, ok := ctx.Value(TxContextKey).(*gorm.DB); ok && tx != nil {
+		return tx
+	}
+	return r.db.WithContext(ctx)
+}
+
+// TryLockRefresh takes a session-level advisory lock so only one instance
+// refreshes the rollups at a time; the returned release func must be called
+// when ok is true
+func (r *reportRollupRepository) TryLockRefresh(ctx context.Context) (release func(), ok bool, err error) {
+	sqlDB, err := r.db.DB()
+	if err != nil {
+		return nil, false, err
+	}
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock(hashtext('report_rollups'))").Scan(&ok); err != nil {
+		conn.Close()
+		return nil, false, err
+	}
+	if !ok {
+		conn.Close()
+		return nil, false, nil
+	}
+	return func() {
+		_, _ = conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock(hashtext('report_rollups'))")
+		conn.Close()
+	}, true, nil
+}
+
+// RefreshDay replaces the campaign_daily_stats and revenue_daily rows of the
+// Tehran day starting at day with fresh aggregates of its sent messages,
+// clicks and transactions
+func (r *reportRollupRepository) RefreshDay(ctx context.Context, day time.Time, refreshedAt time.Time) error {
+	start, end := day, day.AddDate(0, 0, 1)
+	statDate := day.Format("2006-01-02")
+
+	return r.getDB(ctx).Transaction(func(tx *gorm.DB) error {
+		parts := make([]any, 0, len(sentMessageTables)+2)
+		for _, table := range sentMessageTables {
+			parts = append(parts, tx.Table(table+" s").
+				Select(`pc.campaign_id AS campaign_id,
+					COUNT(*) AS sent_messages,
+					COUNT(*) FILTER (WHERE s.status = 'successful') AS successful_messages,
+					COALESCE(SUM(s.parts_delivered), 0) AS delivered_parts,
+					0 AS clicks, 0 AS spend, 0 AS refunds`).
+				Joins("JOIN processed_campaigns pc ON pc.id = s.processed_campaign_id").
+				Where("s.created_at >= ? AND s.created_at < ?", start, end).
+				Group("pc.campaign_id"))
+		}
+		parts = append(parts, excludeAutomatedClickTraffic(tx.Table("short_link_clicks")).
+			Select(`campaign_id, 0 AS sent_messages, 0 AS successful_messages, 0 AS delivered_parts,
+				COUNT(DISTINCT uid) AS clicks, 0 AS spend, 0 AS refunds`).
+			Where("campaign_id IS NOT NULL").
+			Where("created_at >= ? AND created_at < ?", start, end).
+			Group("campaign_id"))
+		parts = append(parts, tx.Table("transactions t").
+			Select(`(t.metadata->>'campaign_id')::bigint AS campaign_id,
+				0 AS sent_messages, 0 AS successful_messages, 0 AS delivered_parts, 0 AS clicks,
+				COALESCE(SUM(t.amount) FILTER (WHERE t.type = ?), 0) AS spend,
+				COALESCE(SUM(t.amount) FILTER (WHERE t.type = ?), 0) AS refunds`,
+				models.TransactionTypeFee, models.TransactionTypeRefund).
+			Where("t.status = ?", models.TransactionStatusCompleted).
+			Where("NOT t.is_sandbox").
+			Where(`((t.type = ? AND t.metadata->>'operation' = ?)
+				OR (t.type = ? AND t.metadata->>'operation' IN ?))`,
+				models.TransactionTypeFee, reportCampaignSpendOperation,
+				models.TransactionTypeRefund, reportCampaignRefundOperations).
+			Where("t.metadata->>'campaign_id' IS NOT NULL").
+			Where("t.created_at >= ? AND t.created_at < ?", start, end).
+			Group("(t.metadata->>'campaign_id')::bigint"))
+
+		union := "(?)"
+		for range parts[1:] {
+			union += " UNION ALL (?)"
+		}
+
+		if err := tx.Exec("DELETE FROM campaign_daily_stats WHERE stat_date = ?", statDate).Error; err != nil {
+			return err
+		}
+		args := append([]any{statDate, refreshedAt}, parts...)
+		if err := tx.Exec(`INSERT INTO campaign_daily_stats (stat_date, campaign_id, customer_id, sent_messages,
+				successful_messages, delivered_parts, clicks, spend, refunds, refreshed_at)
+			SELECT ?::date, d.campaign_id, c.customer_id, SUM(d.sent_messages), SUM(d.successful_messages),
+				SUM(d.delivered_parts), SUM(d.clicks), SUM(d.spend), SUM(d.refunds), ?
+			FROM (`+union+`) d
+			JOIN campaigns c ON c.id = d.campaign_id
+			GROUP BY d.campaign_id, c.customer_id`, args...).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Exec("DELETE FROM revenue_daily WHERE stat_date = ?", statDate).Error; err != nil {
+			return err
+		}
+		return tx.Exec(`INSERT INTO revenue_daily (stat_date, charges, revenue_with_tax, tax_collected, system_share,
+				agency_share_with_tax, campaign_spend, campaign_refunds, refreshed_at)
+			SELECT agg.period::date, agg.charges, agg.revenue_with_tax, agg.tax_collected, agg.system_share,
+				agg.agency_share_with_tax, agg.campaign_spend, agg.campaign_refunds, ?
+			FROM (?) agg`, refreshedAt, financialPeriodsQuery(tx, ReportGranularityDaily, start, end)).Error
+	})
+}
+
+// RefreshMonth replaces the customer_monthly_usage rows of the month starting
+// at the Tehran day month from campaign_daily_stats and the month's wallet
+// charges. The month's days must have been refreshed first.
+func (r *reportRollupRepository) RefreshMonth(ctx context.Context, month time.Time, refreshedAt time.Time) error {
+	start, end := month, month.AddDate(0, 1, 0)
+	monthDate := month.Format("2006-01-02")
+
+	return r.getDB(ctx).Transaction(func(tx *gorm.DB) error {
+		usage := tx.Table("campaign_daily_stats").
+			Select(`customer_id, COUNT(DISTINCT campaign_id) AS campaigns,
+				SUM(sent_messages) AS sent_messages, SUM(successful_messages) AS successful_messages,
+				SUM(delivered_parts) AS delivered_parts, SUM(clicks) AS clicks,
+				SUM(spend) AS campaign_spend, SUM(refunds) AS campaign_refunds,
+				0 AS charges, 0 AS paid_with_tax`).
+			Where("stat_date >= ? AND stat_date < ?", start.Format("2006-01-02"), end.Format("2006-01-02")).
+			Group("customer_id")
+		charges := tx.Table("transactions t").
+			Select(`t.customer_id AS customer_id, 0 AS campaigns, 0 AS sent_messages, 0 AS successful_messages,
+				0 AS delivered_parts, 0 AS clicks, 0 AS campaign_spend, 0 AS campaign_refunds,
+				COUNT(*) AS charges,
+				COALESCE(SUM(COALESCE((t.metadata->>'amount_with_tax')::bigint, t.amount)), 0) AS paid_with_tax`).
+			Where("t.status = ?", models.TransactionStatusCompleted).
+			Where("t.type = ? AND t.metadata->>'source' IN ?", models.TransactionTypeDeposit, reportRevenueSources).
+			Where("t.customer_id IS NOT NULL").
+			Where("t.created_at >= ? AND t.created_at < ?", start, end).
+			Group("t.customer_id")
+
+		if err := tx.Exec("DELETE FROM customer_monthly_usage WHERE month = ?", monthDate).Error; err != nil {
+			return err
+		}
+		return tx.Exec(`INSERT INTO customer_monthly_usage (month, customer_id, campaigns, sent_messages,
+				successful_messages, delivered_parts, clicks, campaign_spend, campaign_refunds, charges,
+				paid_with_tax, refreshed_at)
+			SELECT ?::date, u.customer_id, SUM(u.campaigns), SUM(u.sent_messages), SUM(u.successful_messages),
+				SUM(u.delivered_parts), SUM(u.clicks), SUM(u.campaign_spend), SUM(u.campaign_refunds),
+				SUM(u.charges), SUM(u.paid_with_tax), ?
+			FROM ((?) UNION ALL (?)) u
+			GROUP BY u.customer_id`, monthDate, refreshedAt, usage, charges).Error
+	})
+}
+
+// State returns the watermark of a rollup, or nil before its first refresh
+func (r *reportRollupRepository) State(ctx context.Context, name string) (*models.ReportRollupState, error) {
+	var state models.ReportRollupState
+	err := r.getDB(ctx).Where("name = ?", name).First(&state).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &state, nil
+}
+
+// AdvanceState moves the watermark of a rollup forward to through; it never
+// moves it back, so backfilling old days keeps the incremental position
+func (r *reportRollupRepository) AdvanceState(ctx context.Context, name string, through time.Time, refreshedAt time.Time) error {
+	return r.getDB(ctx).Exec(`INSERT INTO report_rollup_state (name, refreshed_through, refreshed_at)
+		VALUES (?, ?::date, ?)
+		ON CONFLICT (name) DO UPDATE SET
+			refreshed_through = GREATEST(report_rollup_state.refreshed_through, EXCLUDED.refreshed_through),
+			refreshed_at = EXCLUDED.refreshed_at`,
+		name, through.Format("2006-01-02"), refreshedAt).Error
+}
+
+// RevenuePeriods sums revenue_daily per day or Saturday-started week over
+// the Tehran days [fromDate, toDate), in the shape of AggregateFinancialPeriods
+func (r *reportRollupRepository) RevenuePeriods(ctx context.Context, granularity string, fromDate, toDate time.Time) ([]*FinancialPeriodAggregate, error) {
+	period := "to_char(stat_date, 'YYYY-MM-DD')"
+	if granularity == ReportGranularityWeekly {
+		period = "to_char(date_trunc('week', stat_date + INTERVAL '2 days') - INTERVAL '2 days', 'YYYY-MM-DD')"
+	}
+	rows := make([]*FinancialPeriodAggregate, 0)
+	err := r.getDB(ctx).
+		Table("revenue_daily").
+		Select(period+` AS period,
+			SUM(charges) AS charges, SUM(revenue_with_tax) AS revenue_with_tax,
+			SUM(tax_collected) AS tax_collected, SUM(system_share) AS system_share,
+			SUM(agency_share_with_tax) AS agency_share_with_tax,
+			SUM(campaign_spend) AS campaign_spend, SUM(campaign_refunds) AS campaign_refunds`).
+		Where("stat_date >= ? AND stat_date < ?", fromDate.Format("2006-01-02"), toDate.Format("2006-01-02")).
+		Group("period").
+		Order("period ASC").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// CampaignDailyStats lists a campaign's rollup over the Tehran days
+// [fromDate, toDate), oldest first
+func (r *reportRollupRepository) CampaignDailyStats(ctx context.Context, campaignID uint, fromDate, toDate time.Time) ([]*models.CampaignDailyStat, error) {
+	rows := make([]*models.CampaignDailyStat, 0)
+	err := r.getDB(ctx).
+		Where("campaign_id = ?", campaignID).
+		Where("stat_date >= ? AND stat_date < ?", fromDate.Format("2006-01-02"), toDate.Format("2006-01-02")).
+		Order("stat_date ASC").
+		Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// CustomerMonthlyUsage lists a customer's monthly usage for the months
+// starting in [fromMonth, toMonth), oldest first
+func (r *reportRollupRepository) CustomerMonthlyUsage(ctx context.Context, customerID uint, fromMonth, toMonth time.Time) ([]*models.CustomerMonthlyUsage, error) {
+	rows := make([]*models.CustomerMonthlyUsage, 0)
+	err := r.getDB(ctx).
+		Where("customer_id = ?", customerID).
+		Where("month >= ? AND month < ?", fromMonth.Format("2006-01-02"), toMonth.Format("2006-01-02")).
+		Order("month ASC").
+		Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
@@ -3,8 +3,12 @@ package repository
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/amirphl/Yamata-no-Orochi/utils/phonenumber"
+	"github.com/jackc/pgx/v5"
 	"gorm.io/gorm"
 )
 
@@ -17,6 +21,79 @@ func NewSentSMSRepository(db *gorm.DB) SentSMSRepository {
 	return &SentSMSRepositoryImpl{BaseRepository: NewBaseRepository[models.SentSMS, models.SentSMSFilter](db)}
 }
 
+// Save inserts a sent SMS row, storing the recipient in canonical E.164 form
+func (r *SentSMSRepositoryImpl) Save(ctx context.Context, row *models.SentSMS) error {
+	row.PhoneNumber = phonenumber.CanonicalOrRaw(row.PhoneNumber)
+	return r.BaseRepository.Save(ctx, row)
+}
+
+// sentSMSCopyMinRows is the batch size from which SaveBatch loads rows with
+// COPY instead of a multi-row INSERT
+const sentSMSCopyMinRows = 32
+
+// sentSMSCopyColumns lists every persisted column but the generated id, in
+// the order of the values built by sentSMSCopyRow
+var sentSMSCopyColumns = []string{
+	"processed_campaign_id", "phone_number", "tracking_id", "parts_delivered", "status",
+	"variant", "sender", "operator", "server_id", "error_code", "description", "permanent_error",
+	"created_at", "updated_at",
+}
+
+func sentSMSCopyRow(row *models.SentSMS) []any {
+	return []any{
+		int64(row.ProcessedCampaignID), row.PhoneNumber, row.TrackingID, int32(row.PartsDelivered), string(row.Status),
+		row.Variant, row.Sender, row.Operator, row.ServerID, row.ErrorCode, row.Description, row.PermanentError,
+		row.CreatedAt, row.UpdatedAt,
+	}
+}
+
+// SaveBatch inserts sent SMS rows, storing recipients in canonical E.164 form.
+// Large batches are loaded with COPY; when no pgx connection is reachable,
+// e.g. inside a transaction not started by WithTransaction, the rows are
+// inserted through GORM instead. Row IDs are not populated on the COPY path.
+func (r *SentSMSRepositoryImpl) SaveBatch(ctx context.Context, rows []*models.SentSMS) error {
+	for _, row := range rows {
+		row.PhoneNumber = phonenumber.CanonicalOrRaw(row.PhoneNumber)
+	}
+	if len(rows) >= sentSMSCopyMinRows {
+		err := r.copyBatch(ctx, rows)
+		if !errors.Is(err, errPgxConnUnavailable) {
+			return err
+		}
+	}
+	return r.BaseRepository.SaveBatch(ctx, rows)
+}
+
+func (r *SentSMSRepositoryImpl) copyBatch(ctx context.Context, rows []*models.SentSMS) error {
+	now := utils.UTCNow()
+	values := make([][]any, len(rows))
+	for i, row := range rows {
+		if row.Status == "" {
+			row.Status = models.SMSSendStatusPending
+		}
+		if row.CreatedAt.IsZero() {
+			row.CreatedAt = now
+		}
+		if row.UpdatedAt.IsZero() {
+			row.UpdatedAt = row.CreatedAt
+		}
+		values[i] = sentSMSCopyRow(row)
+	}
+
+	return withPgxConn(ctx, r.DB, func(conn *pgx.Conn) error {
+		// COPY encodes in binary, so the status enum must be known to the connection
+		if _, ok := conn.TypeMap().TypeForName("sent_sms_status"); !ok {
+			t, err := conn.LoadType(ctx, "sent_sms_status")
+			if err != nil {
+				return err
+			}
+			conn.TypeMap().RegisterType(t)
+		}
+		_, err := conn.CopyFrom(ctx, pgx.Identifier{models.SentSMS{}.TableName()}, sentSMSCopyColumns, pgx.CopyFromRows(values))
+		return err
+	})
+}
+
 func (r *SentSMSRepositoryImpl) ByID(ctx context.Context, id uint) (*models.SentSMS, error) {
 	db := r.getDB(ctx)
 	var row models.SentSMS
@@ -42,7 +119,7 @@ func (r *SentSMSRepositoryImpl) applyFilter(db *gorm.DB, f models.SentSMSFilter)
 		db = db.Where("processed_campaign_id = ?", *f.ProcessedCampaignID)
 	}
 	if f.PhoneNumber != nil {
-		db = db.Where("phone_number = ?", *f.PhoneNumber)
+		db = db.Where("phone_number = ?", phonenumber.CanonicalOrRaw(*f.PhoneNumber))
 	}
 	if f.Status != nil {
 		db = db.Where("status = ?", *f.Status)
@@ -117,9 +194,38 @@ func (r *SentSMSRepositoryImpl) UpdateProviderFieldsByTrackingIDs(ctx context.Co
 			"error_code":  u.ErrorCode,
 			"description": u.Description,
 		}
+		if u.Sender != nil {
+			m["sender"] = *u.Sender
+		}
+		if u.PermanentError {
+			m["permanent_error"] = true
+		}
 		if e := db.Model(&models.SentSMS{}).Where("tracking_id = ?", u.TrackingID).Updates(m).Error; e != nil {
 			return e
 		}
 	}
 	return nil
 }
+
+// RecentPhonesByCustomer returns the canonical numbers the customer's SMS
+// campaigns other than excludeCampaignID messaged since the given time.
+// Messages the provider rejected do not count.
+func (r *SentSMSRepositoryImpl) RecentPhonesByCustomer(ctx context.Context, customerID, excludeCampaignID uint, since time.Time) (map[string]struct{}, error) {
+	var phones []string
+	err := r.getDB(ctx).
+		Table("sent_sms AS ss").
+		Joins("JOIN processed_campaigns pc ON pc.id = ss.processed_campaign_id").
+		Joins("JOIN campaigns c ON c.id = pc.campaign_id").
+		Where("c.customer_id = ? AND c.id <> ? AND ss.created_at >= ?", customerID, excludeCampaignID, since).
+		Where("ss.phone_number <> '' AND ss.status <> ?", models.SMSSendStatusUnsuccessful).
+		Distinct("ss.phone_number").
+		Pluck("ss.phone_number", &phones).Error
+	if err != nil {
+		return nil, err
+	}
+	set := make(map[string]struct{}, len(phones))
+	for _, phone := range phones {
+		set[phonenumber.CanonicalOrRaw(phone)] = struct{}{}
+	}
+	return set, nil
+}
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
+)
+
+// errBenchRollback rolls back the transaction of each benchmark iteration
+var errBenchRollback = errors.New("rollback")
+
+// TestSentSMSCopyColumns keeps the COPY path of SaveBatch in step with the
+// model, so a new column is not silently stored as its default
+func TestSentSMSCopyColumns(t *testing.T) {
+	s, err := schema.Parse(&models.SentSMS{}, &sync.Map{}, schema.NamingStrategy{})
+	if err != nil {
+		t.Fatalf("parse SentSMS: %v", err)
+	}
+	copied := make(map[string]bool, len(sentSMSCopyColumns))
+	for _, column := range sentSMSCopyColumns {
+		if copied[column] {
+			t.Errorf("column %s is copied twice", column)
+		}
+		copied[column] = true
+	}
+	for _, field := range s.Fields {
+		if field.DBName == "" || field.PrimaryKey {
+			continue
+		}
+		if !copied[field.DBName] {
+			t.Errorf("column %s is missing from sentSMSCopyColumns", field.DBName)
+		}
+		delete(copied, field.DBName)
+	}
+	for column := range copied {
+		t.Errorf("sentSMSCopyColumns lists %s, which SentSMS does not persist", column)
+	}
+	if got := len(sentSMSCopyRow(&models.SentSMS{})); got != len(sentSMSCopyColumns) {
+		t.Errorf("sentSMSCopyRow returns %d values for %d columns", got, len(sentSMSCopyColumns))
+	}
+}
+
+// Run against a migrated database holding at least one processed campaign:
+//
+//	SENT_SMS_BENCH_DSN="host=localhost user=postgres dbname=yamata sslmode=disable" \
+//	  go test ./repository -run '^$' -bench SentSMSSaveBatch
+func benchSentSMSRepository(b *testing.B) (*SentSMSRepositoryImpl, *gorm.DB, uint) {
+	dsn := os.Getenv("SENT_SMS_BENCH_DSN")
+	if dsn == "" {
+		b.Skip("SENT_SMS_BENCH_DSN not set")
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		b.Fatalf("open database: %v", err)
+	}
+	var processedCampaignID uint
+	if err := db.Raw("SELECT id FROM processed_campaigns ORDER BY id LIMIT 1").Scan(&processedCampaignID).Error; err != nil {
+		b.Fatalf("load processed campaign: %v", err)
+	}
+	if processedCampaignID == 0 {
+		b.Skip("no processed campaign to attach rows to")
+	}
+	return NewSentSMSRepository(db).(*SentSMSRepositoryImpl), db, processedCampaignID
+}
+
+func benchSentSMSRows(processedCampaignID uint, n, iteration int) []*models.SentSMS {
+	rows := make([]*models.SentSMS, n)
+	for i := range rows {
+		rows[i] = &models.SentSMS{
+			ProcessedCampaignID: processedCampaignID,
+			PhoneNumber:         fmt.Sprintf("+98912%07d", i),
+			TrackingID:          fmt.Sprintf("bench-%d-%d", iteration, i),
+			Status:              models.SMSSendStatusPending,
+		}
+	}
+	return rows
+}
+
+func BenchmarkSentSMSSaveBatch(b *testing.B) {
+	repo, db, processedCampaignID := benchSentSMSRepository(b)
+
+	paths := []struct {
+		name string
+		save func(ctx context.Context, rows []*models.SentSMS) error
+	}{
+		{"gorm", repo.BaseRepository.SaveBatch},
+		{"copy", repo.copyBatch},
+	}
+	for _, size := range []int{200, 1000} {
+		for _, path := range paths {
+			b.Run(fmt.Sprintf("%s/%d", path.name, size), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					rows := benchSentSMSRows(processedCampaignID, size, i)
+					err := WithTransaction(context.Background(), db, func(txCtx context.Context) error {
+						if err := path.save(txCtx, rows); err != nil {
+							return err
+						}
+						return errBenchRollback
+					})
+					if !errors.Is(err, errBenchRollback) {
+						b.Fatalf("save batch: %v", err)
+					}
+				}
+			})
+		}
+	}
+}
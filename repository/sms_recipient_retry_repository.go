@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SMSRecipientRetryRepositoryImpl implements SMSRecipientRetryRepository
+type SMSRecipientRetryRepositoryImpl struct {
+	*BaseRepository[models.SMSRecipientRetry, models.SMSRecipientRetryFilter]
+}
+
+// NewSMSRecipientRetryRepository creates a new SMS recipient retry repository
+func NewSMSRecipientRetryRepository(db *gorm.DB) SMSRecipientRetryRepository {
+	return &SMSRecipientRetryRepositoryImpl{
+		BaseRepository: NewBaseRepository[models.SMSRecipientRetry, models.SMSRecipientRetryFilter](db),
+	}
+}
+
+// Schedule inserts the retries, skipping tracking IDs already being retried
+func (r *SMSRecipientRetryRepositoryImpl) Schedule(ctx context.Context, retries []*models.SMSRecipientRetry) error {
+	if len(retries) == 0 {
+		return nil
+	}
+	return r.getDB(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "processed_campaign_id"}, {Name: "tracking_id"}},
+			DoNothing: true,
+		}).
+		CreateInBatches(retries, 500).Error
+}
+
+// ListDue returns pending retries due at now of running or executed
+// campaigns, earliest first
+func (r *SMSRecipientRetryRepositoryImpl) ListDue(ctx context.Context, now time.Time, limit int) ([]*models.SMSRecipientRetry, error) {
+	var retries []*models.SMSRecipientRetry
+	err := r.getDB(ctx).
+		Where("status = ? AND next_attempt_at <= ?", models.SMSRetryStatusPending, now).
+		Where("EXISTS (SELECT 1 FROM campaigns c WHERE c.id = sms_recipient_retries.campaign_id AND c.status IN ?)",
+			[]models.CampaignStatus{models.CampaignStatusRunning, models.CampaignStatusExecuted}).
+		Order("next_attempt_at ASC, id ASC").
+		Limit(limit).
+		Find(&retries).Error
+	return retries, err
+}
+
+// ExhaustCancelled ends the pending retries of cancelled campaigns
+func (r *SMSRecipientRetryRepositoryImpl) ExhaustCancelled(ctx context.Context, now time.Time) (int64, error) {
+	res := r.getDB(ctx).Model(&models.SMSRecipientRetry{}).
+		Where("status = ?", models.SMSRetryStatusPending).
+		Where("EXISTS (SELECT 1 FROM campaigns c WHERE c.id = sms_recipient_retries.campaign_id AND c.status IN ?)",
+			[]models.CampaignStatus{models.CampaignStatusCancelled, models.CampaignStatusCancelledByAdmin}).
+		Updates(map[string]any{"status": models.SMSRetryStatusExhausted, "updated_at": now})
+	return res.RowsAffected, res.Error
+}
+
+// Update saves the state of a retry
+func (r *SMSRecipientRetryRepositoryImpl) Update(ctx context.Context, retry *models.SMSRecipientRetry) error {
+	return r.getDB(ctx).Model(retry).Updates(map[string]any{
+		"status":          retry.Status,
+		"attempts":        retry.Attempts,
+		"next_attempt_at": retry.NextAttemptAt,
+		"last_error_code": retry.LastErrorCode,
+		"updated_at":      retry.UpdatedAt,
+	}).Error
+}
+
+// OutcomesByCampaign counts the retries of a campaign by outcome, and its
+// sent SMS flagged with a permanent error across all processed runs
+func (r *SMSRecipientRetryRepositoryImpl) OutcomesByCampaign(ctx context.Context, campaignID uint) (*SMSRetryOutcomes, error) {
+	db := r.getDB(ctx)
+	var out SMSRetryOutcomes
+	if err := db.Model(&models.SMSRecipientRetry{}).
+		Select(`
+			COUNT(*) AS retried,
+			COUNT(*) FILTER (WHERE status = ?) AS pending,
+			COUNT(*) FILTER (WHERE status = ?) AS recovered,
+			COUNT(*) FILTER (WHERE status = ?) AS exhausted,
+			COUNT(*) FILTER (WHERE status = ?) AS permanent,
+			COALESCE(SUM(attempts), 0) AS attempts`,
+			models.SMSRetryStatusPending, models.SMSRetryStatusRecovered,
+			models.SMSRetryStatusExhausted, models.SMSRetryStatusPermanent).
+		Where("campaign_id = ?", campaignID).
+		Scan(&out).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Table("sent_sms AS ss").
+		Joins("JOIN processed_campaigns AS pc ON pc.id = ss.processed_campaign_id").
+		Where("pc.campaign_id = ? AND ss.permanent_error", campaignID).
+		Count(&out.PermanentErrors).Error; err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ByFilter returns SMS retries matching the filter
+func (r *SMSRecipientRetryRepositoryImpl) ByFilter(ctx context.Context, filter models.SMSRecipientRetryFilter, orderBy string, limit, offset int) ([]*models.SMSRecipientRetry, error) {
+	db := r.applyFilter(r.getDB(ctx).Model(&models.SMSRecipientRetry{}), filter)
+	if orderBy == "" {
+		orderBy = "id DESC"
+	}
+	db = db.Order(orderBy)
+	if limit > 0 {
+		db = db.Limit(limit)
+	}
+	if offset > 0 {
+		db = db.Offset(offset)
+	}
+
+	var retries []*models.SMSRecipientRetry
+	if err := db.Find(&retries).Error; err != nil {
+		return nil, err
+	}
+	return retries, nil
+}
+
+// Count returns the number of SMS retries matching the filter
+func (r *SMSRecipientRetryRepositoryImpl) Count(ctx context.Context, filter models.SMSRecipientRetryFilter) (int64, error) {
+	var count int64
+	if err := r.applyFilter(r.getDB(ctx).Model(&models.SMSRecipientRetry{}), filter).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// Exists checks whether any SMS retry matches the filter
+func (r *SMSRecipientRetryRepositoryImpl) Exists(ctx context.Context, filter models.SMSRecipientRetryFilter) (bool, error) {
+	count, err := r.Count(ctx, filter)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *SMSRecipientRetryRepositoryImpl) applyFilter(query *gorm.DB, filter models.SMSRecipientRetryFilter) *gorm.DB {
+	if filter.ID != nil {
+		query = query.Where("id = ?", *filter.ID)
+	}
+	if filter.CampaignID != nil {
+		query = query.Where("campaign_id = ?", *filter.CampaignID)
+	}
+	if filter.ProcessedCampaignID != nil {
+		query = query.Where("processed_campaign_id = ?", *filter.ProcessedCampaignID)
+	}
+	if filter.TrackingID != nil {
+		query = query.Where("tracking_id = ?", *filter.TrackingID)
+	}
+	if filter.Status != nil {
+		query = query.Where("status = ?", *filter.Status)
+	}
+	return query
+}
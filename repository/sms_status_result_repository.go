@@ -23,6 +23,24 @@ type SMSStatusAggregates struct {
 	AggregatedUnknown        int64
 }
 
+// SMSVariantAggregates summarizes the sent SMS rows of one A/B content variant
+type SMSVariantAggregates struct {
+	Variant   string
+	Sent      int64
+	Delivered int64
+	Clicks    int64
+}
+
+// SMSOperatorAggregates summarizes the sent SMS rows of one recipient
+// operator. Operator is empty for unknown prefixes and rows sent before
+// operators were recorded.
+type SMSOperatorAggregates struct {
+	Operator  string
+	Sent      int64
+	Delivered int64
+	Clicks    int64
+}
+
 type SMSTrackingResult struct {
 	AudienceProfileUID    *string `json:"audienceProfileUID" gorm:"column:audience_profile_uid"`
 	PhoneNumber           string  `json:"phoneNumber" gorm:"column:phone_number"`
@@ -137,6 +155,68 @@ func (r *SMSStatusResultRepositoryImpl) AggregateByCampaign(ctx context.Context,
 	return &agg, nil
 }
 
+// AggregateByVariant counts sent, fully delivered and clicked recipients per
+// content variant across all processed runs of a campaign. Clicks are distinct
+// recipients with at least one non-automated click on their short link.
+func (r *SMSStatusResultRepositoryImpl) AggregateByVariant(ctx context.Context, campaignID uint) ([]SMSVariantAggregates, error) {
+	db := r.getReadDB(ctx)
+	clicks := excludeAutomatedClickTraffic(db.Table("short_link_clicks")).
+		Select("DISTINCT phone_number").
+		Where("campaign_id = ? AND phone_number IS NOT NULL", campaignID)
+
+	out := make([]SMSVariantAggregates, 0)
+	if err := db.Table("sent_sms AS ss").
+		Select(`
+			ss.variant,
+			COUNT(DISTINCT ss.id) AS sent,
+			COUNT(DISTINCT ss.id) FILTER (WHERE ssr.total_parts > 0 AND ssr.total_parts = ssr.total_delivered_parts) AS delivered,
+			COUNT(DISTINCT clk.phone_number) AS clicks`).
+		Joins("JOIN processed_campaigns AS pc ON pc.id = ss.processed_campaign_id").
+		Joins(`
+			LEFT JOIN sms_status_results AS ssr
+				ON ssr.processed_campaign_id = ss.processed_campaign_id
+				AND ssr.tracking_id = ss.tracking_id`).
+		Joins("LEFT JOIN (?) AS clk ON clk.phone_number = ss.phone_number", clicks).
+		Where("pc.campaign_id = ? AND ss.variant IS NOT NULL AND ss.phone_number <> ''", campaignID).
+		Group("ss.variant").
+		Order("ss.variant ASC").
+		Scan(&out).Error; err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AggregateByOperator counts sent, fully delivered and clicked recipients per
+// recipient operator across all processed runs of a campaign, counting clicks
+// like AggregateByVariant.
+func (r *SMSStatusResultRepositoryImpl) AggregateByOperator(ctx context.Context, campaignID uint) ([]SMSOperatorAggregates, error) {
+	db := r.getReadDB(ctx)
+	clicks := excludeAutomatedClickTraffic(db.Table("short_link_clicks")).
+		Select("DISTINCT phone_number").
+		Where("campaign_id = ? AND phone_number IS NOT NULL", campaignID)
+
+	out := make([]SMSOperatorAggregates, 0)
+	if err := db.Table("sent_sms AS ss").
+		Select(`
+			COALESCE(ss.operator, '') AS operator,
+			COUNT(DISTINCT ss.id) AS sent,
+			COUNT(DISTINCT ss.id) FILTER (WHERE ssr.total_parts > 0 AND ssr.total_parts = ssr.total_delivered_parts) AS delivered,
+			COUNT(DISTINCT clk.phone_number) AS clicks`).
+		Joins("JOIN processed_campaigns AS pc ON pc.id = ss.processed_campaign_id").
+		Joins(`
+			LEFT JOIN sms_status_results AS ssr
+				ON ssr.processed_campaign_id = ss.processed_campaign_id
+				AND ssr.tracking_id = ss.tracking_id`).
+		Joins("LEFT JOIN (?) AS clk ON clk.phone_number = ss.phone_number", clicks).
+		Where("pc.campaign_id = ? AND ss.phone_number <> ''", campaignID).
+		Group("COALESCE(ss.operator, '')").
+		Order("operator ASC").
+		Scan(&out).Error; err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (r *SMSStatusResultRepositoryImpl) TrackingResultsByCampaign(ctx context.Context, processedCampaignID uint) ([]SMSTrackingResult, error) {
 	db := r.getDB(ctx)
 	trackingResults := make([]SMSTrackingResult, 0)
@@ -0,0 +1,173 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// SMSTariffRepositoryImpl implements SMSTariffRepository
+type SMSTariffRepositoryImpl struct {
+	*BaseRepository[models.SMSTariff, models.SMSTariffFilter]
+}
+
+// NewSMSTariffRepository creates a new SMS tariff repository
+func NewSMSTariffRepository(db *gorm.DB) SMSTariffRepository {
+	return &SMSTariffRepositoryImpl{
+		BaseRepository: NewBaseRepository[models.SMSTariff, models.SMSTariffFilter](db),
+	}
+}
+
+// ByID retrieves a tariff by its ID
+func (r *SMSTariffRepositoryImpl) ByID(ctx context.Context, id uint) (*models.SMSTariff, error) {
+	db := r.getDB(ctx)
+	var tariff models.SMSTariff
+	if err := db.Last(&tariff, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &tariff, nil
+}
+
+// ByUUID retrieves a tariff by its UUID
+func (r *SMSTariffRepositoryImpl) ByUUID(ctx context.Context, id uuid.UUID) (*models.SMSTariff, error) {
+	db := r.getDB(ctx)
+	var tariff models.SMSTariff
+	if err := db.Where("uuid = ?", id).Last(&tariff).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &tariff, nil
+}
+
+// ListActive returns all active tariffs ordered by id
+func (r *SMSTariffRepositoryImpl) ListActive(ctx context.Context) ([]*models.SMSTariff, error) {
+	active := true
+	return r.ByFilter(ctx, models.SMSTariffFilter{IsActive: &active}, "id ASC", 0, 0)
+}
+
+// Update overwrites all mutable fields, including clearing nullable dimensions
+func (r *SMSTariffRepositoryImpl) Update(ctx context.Context, tariff *models.SMSTariff) error {
+	if tariff == nil || tariff.ID == 0 {
+		return errors.New("sms tariff ID is required for update")
+	}
+
+	db, shouldCommit, err := r.getDBForWrite(ctx)
+	if err != nil {
+		return err
+	}
+	if shouldCommit {
+		defer func() {
+			if err != nil {
+				db.Rollback()
+			} else {
+				db.Commit()
+			}
+		}()
+	}
+
+	result := db.Model(&models.SMSTariff{}).
+		Where("id = ?", tariff.ID).
+		Select("operator", "line_number_tier", "account_type", "part_count", "price_per_part", "description", "is_active", "updated_at").
+		Updates(tariff)
+	if err = result.Error; err != nil {
+		return err
+	}
+	if result.RowsAffected == 0 {
+		err = gorm.ErrRecordNotFound
+		return err
+	}
+	return nil
+}
+
+// Delete removes a tariff by ID
+func (r *SMSTariffRepositoryImpl) Delete(ctx context.Context, id uint) error {
+	db, shouldCommit, err := r.getDBForWrite(ctx)
+	if err != nil {
+		return err
+	}
+	if shouldCommit {
+		defer func() {
+			if err != nil {
+				db.Rollback()
+			} else {
+				db.Commit()
+			}
+		}()
+	}
+
+	result := db.Delete(&models.SMSTariff{}, id)
+	if err = result.Error; err != nil {
+		return err
+	}
+	if result.RowsAffected == 0 {
+		err = gorm.ErrRecordNotFound
+		return err
+	}
+	return nil
+}
+
+// ByFilter returns tariffs matching the filter
+func (r *SMSTariffRepositoryImpl) ByFilter(ctx context.Context, filter models.SMSTariffFilter, orderBy string, limit, offset int) ([]*models.SMSTariff, error) {
+	db := r.applyFilter(r.getDB(ctx).Model(&models.SMSTariff{}), filter)
+	if orderBy == "" {
+		orderBy = "id ASC"
+	}
+	db = db.Order(orderBy)
+	if limit > 0 {
+		db = db.Limit(limit)
+	}
+	if offset > 0 {
+		db = db.Offset(offset)
+	}
+
+	var tariffs []*models.SMSTariff
+	if err := db.Find(&tariffs).Error; err != nil {
+		return nil, err
+	}
+	return tariffs, nil
+}
+
+// Count returns the number of tariffs matching the filter
+func (r *SMSTariffRepositoryImpl) Count(ctx context.Context, filter models.SMSTariffFilter) (int64, error) {
+	var count int64
+	if err := r.applyFilter(r.getDB(ctx).Model(&models.SMSTariff{}), filter).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// Exists checks whether any tariff matches the filter
+func (r *SMSTariffRepositoryImpl) Exists(ctx context.Context, filter models.SMSTariffFilter) (bool, error) {
+	count, err := r.Count(ctx, filter)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *SMSTariffRepositoryImpl) applyFilter(query *gorm.DB, filter models.SMSTariffFilter) *gorm.DB {
+	if filter.ID != nil {
+		query = query.Where("id = ?", *filter.ID)
+	}
+	if filter.UUID != nil {
+		query = query.Where("uuid = ?", *filter.UUID)
+	}
+	if filter.Operator != nil {
+		query = query.Where("operator = ?", *filter.Operator)
+	}
+	if filter.AccountType != nil {
+		query = query.Where("account_type = ?", *filter.AccountType)
+	}
+	if filter.IsActive != nil {
+		query = query.Where("is_active = ?", *filter.IsActive)
+	}
+	return query
+}
@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"context"
+)
+
+// SoftDeleteRepository is implemented by the repositories of models with a
+// gorm.DeletedAt column. Soft-deleted rows are left out of every ORM query
+// of the repository; ByFilter, Count and Exists return them when the filter
+// sets IncludeDeleted. Raw SQL, such as the reports, is not scoped and keeps
+// counting them.
+type SoftDeleteRepository interface {
+	// SoftDelete marks the row deleted and reports whether a live row was found
+	SoftDelete(ctx context.Context, id uint) (bool, error)
+	// Restore clears the deletion and reports whether a deleted row was found
+	Restore(ctx context.Context, id uint) (bool, error)
+}
+
+// softDelete sets deleted_at on a live row of T. Only call it for models
+// with a gorm.DeletedAt field; on other models gorm deletes the row.
+func (r *BaseRepository[T, F]) softDelete(ctx context.Context, id uint) (bool, error) {
+	db := r.getDB(ctx)
+	res := db.Delete(new(T), id)
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
+
+// restore clears deleted_at on a soft-deleted row of T
+func (r *BaseRepository[T, F]) restore(ctx context.Context, id uint) (bool, error) {
+	db := r.getDB(ctx)
+	res := db.Unscoped().Model(new(T)).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Update("deleted_at", nil)
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
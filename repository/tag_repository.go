@@ -92,6 +92,9 @@ func (r *TagRepositoryImpl) ListActiveAfterID(ctx context.Context, afterID *uint
 
 // applyFilter applies filter criteria to a GORM query
 func (r *TagRepositoryImpl) applyFilter(query *gorm.DB, filter models.TagFilter) *gorm.DB {
+	if filter.IncludeDeleted {
+		query = query.Unscoped()
+	}
 	if filter.ID != nil {
 		query = query.Where("id = ?", *filter.ID)
 	}
@@ -157,3 +160,13 @@ func (r *TagRepositoryImpl) Exists(ctx context.Context, filter models.TagFilter)
 	}
 	return c > 0, nil
 }
+
+// SoftDelete marks a tag deleted, leaving it out of later queries
+func (r *TagRepositoryImpl) SoftDelete(ctx context.Context, id uint) (bool, error) {
+	return r.softDelete(ctx, id)
+}
+
+// Restore brings back a soft-deleted tag
+func (r *TagRepositoryImpl) Restore(ctx context.Context, id uint) (bool, error) {
+	return r.restore(ctx, id)
+}
@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// TaxInvoiceRepositoryImpl implements TaxInvoiceRepository
+type TaxInvoiceRepositoryImpl struct {
+	*BaseRepository[models.TaxInvoice, models.TaxInvoiceFilter]
+}
+
+// NewTaxInvoiceRepository creates a new tax invoice repository
+func NewTaxInvoiceRepository(db *gorm.DB) TaxInvoiceRepository {
+	return &TaxInvoiceRepositoryImpl{
+		BaseRepository: NewBaseRepository[models.TaxInvoice, models.TaxInvoiceFilter](db),
+	}
+}
+
+// NextSequenceNumber advances the invoice counter of a legal entity and
+// returns the new value. The counter row stays locked until the caller's
+// transaction ends, so a rolled back charge gives its number back and
+// concurrent charges are numbered one after the other.
+func (r *TaxInvoiceRepositoryImpl) NextSequenceNumber(ctx context.Context, legalEntity string) (uint64, error) {
+	name := "tax_invoice:" + legalEntity
+	db := r.getDB(ctx)
+	if err := db.Exec(
+		"INSERT INTO sequence_counters (name, last_value) VALUES (?, '0') ON CONFLICT (name) DO NOTHING",
+		name,
+	).Error; err != nil {
+		return 0, err
+	}
+
+	var last string
+	if err := db.Raw(
+		"UPDATE sequence_counters SET last_value = (CAST(last_value AS BIGINT) + 1)::text, updated_at = ? WHERE name = ? RETURNING last_value",
+		utils.UTCNow(), name,
+	).Scan(&last).Error; err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(last, 10, 64)
+}
+
+// ByUUID retrieves an invoice by UUID
+func (r *TaxInvoiceRepositoryImpl) ByUUID(ctx context.Context, id uuid.UUID) (*models.TaxInvoice, error) {
+	var inv models.TaxInvoice
+	if err := r.getDB(ctx).Where("uuid = ?", id).Last(&inv).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &inv, nil
+}
+
+// SetPDF records where the rendered PDF of an invoice is stored
+func (r *TaxInvoiceRepositoryImpl) SetPDF(ctx context.Context, id uint, path string, generatedAt time.Time) error {
+	return r.getDB(ctx).Model(&models.TaxInvoice{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"pdf_path":         path,
+			"pdf_generated_at": generatedAt,
+			"updated_at":       generatedAt,
+		}).Error
+}
+
+// ByFilter returns invoices matching the filter
+func (r *TaxInvoiceRepositoryImpl) ByFilter(ctx context.Context, filter models.TaxInvoiceFilter, orderBy string, limit, offset int) ([]*models.TaxInvoice, error) {
+	db := r.applyFilter(r.getDB(ctx).Model(&models.TaxInvoice{}), filter)
+	if orderBy == "" {
+		orderBy = "id DESC"
+	}
+	db = db.Order(orderBy)
+	if limit > 0 {
+		db = db.Limit(limit)
+	}
+	if offset > 0 {
+		db = db.Offset(offset)
+	}
+
+	var items []*models.TaxInvoice
+	if err := db.Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// Count returns the number of invoices matching the filter
+func (r *TaxInvoiceRepositoryImpl) Count(ctx context.Context, filter models.TaxInvoiceFilter) (int64, error) {
+	var count int64
+	if err := r.applyFilter(r.getDB(ctx).Model(&models.TaxInvoice{}), filter).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// Exists checks whether any invoice matches the filter
+func (r *TaxInvoiceRepositoryImpl) Exists(ctx context.Context, filter models.TaxInvoiceFilter) (bool, error) {
+	count, err := r.Count(ctx, filter)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *TaxInvoiceRepositoryImpl) applyFilter(query *gorm.DB, filter models.TaxInvoiceFilter) *gorm.DB {
+	if filter.ID != nil {
+		query = query.Where("id = ?", *filter.ID)
+	}
+	if filter.UUID != nil {
+		query = query.Where("uuid = ?", *filter.UUID)
+	}
+	if filter.CustomerID != nil {
+		query = query.Where("customer_id = ?", *filter.CustomerID)
+	}
+	if filter.TransactionID != nil {
+		query = query.Where("transaction_id = ?", *filter.TransactionID)
+	}
+	if filter.LegalEntity != nil {
+		query = query.Where("legal_entity = ?", *filter.LegalEntity)
+	}
+	if filter.IssuedAfter != nil {
+		query = query.Where("issued_at >= ?", *filter.IssuedAfter)
+	}
+	if filter.IssuedBefore != nil {
+		query = query.Where("issued_at < ?", *filter.IssuedBefore)
+	}
+	return query
+}
@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"gorm.io/gorm"
+)
+
+// TelegramLinkRequestRepositoryImpl implements TelegramLinkRequestRepository
+type TelegramLinkRequestRepositoryImpl struct {
+	*BaseRepository[models.TelegramLinkRequest, models.TelegramLinkRequestFilter]
+}
+
+// NewTelegramLinkRequestRepository creates a new Telegram link request repository
+func NewTelegramLinkRequestRepository(db *gorm.DB) TelegramLinkRequestRepository {
+	return &TelegramLinkRequestRepositoryImpl{
+		BaseRepository: NewBaseRepository[models.TelegramLinkRequest, models.TelegramLinkRequestFilter](db),
+	}
+}
+
+// AttachChat records the chat that opened the deep link and the hash of the
+// verification code sent to it. A new chat restarts the attempt count.
+func (r *TelegramLinkRequestRepositoryImpl) AttachChat(ctx context.Context, id uint, chatID int64, codeHash string) error {
+	return r.getDB(ctx).Model(&models.TelegramLinkRequest{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"chat_id":    chatID,
+			"code_hash":  codeHash,
+			"attempts":   0,
+			"updated_at": utils.UTCNow(),
+		}).Error
+}
+
+// IncrementAttempts counts a wrong verification code
+func (r *TelegramLinkRequestRepositoryImpl) IncrementAttempts(ctx context.Context, id uint) error {
+	return r.getDB(ctx).Model(&models.TelegramLinkRequest{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"attempts":   gorm.Expr("attempts + 1"),
+			"updated_at": utils.UTCNow(),
+		}).Error
+}
+
+// MarkLinked completes a request
+func (r *TelegramLinkRequestRepositoryImpl) MarkLinked(ctx context.Context, id uint, linkedAt time.Time) error {
+	return r.getDB(ctx).Model(&models.TelegramLinkRequest{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"linked_at":  linkedAt,
+			"updated_at": utils.UTCNow(),
+		}).Error
+}
+
+// ByFilter returns link requests matching the filter
+func (r *TelegramLinkRequestRepositoryImpl) ByFilter(ctx context.Context, filter models.TelegramLinkRequestFilter, orderBy string, limit, offset int) ([]*models.TelegramLinkRequest, error) {
+	db := r.applyFilter(r.getDB(ctx).Model(&models.TelegramLinkRequest{}), filter)
+	if orderBy == "" {
+		orderBy = "id DESC"
+	}
+	db = db.Order(orderBy)
+	if limit > 0 {
+		db = db.Limit(limit)
+	}
+	if offset > 0 {
+		db = db.Offset(offset)
+	}
+
+	var requests []*models.TelegramLinkRequest
+	if err := db.Find(&requests).Error; err != nil {
+		return nil, err
+	}
+	return requests, nil
+}
+
+// Count returns the number of link requests matching the filter
+func (r *TelegramLinkRequestRepositoryImpl) Count(ctx context.Context, filter models.TelegramLinkRequestFilter) (int64, error) {
+	var count int64
+	if err := r.applyFilter(r.getDB(ctx).Model(&models.TelegramLinkRequest{}), filter).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// Exists checks whether any link request matches the filter
+func (r *TelegramLinkRequestRepositoryImpl) Exists(ctx context.Context, filter models.TelegramLinkRequestFilter) (bool, error) {
+	count, err := r.Count(ctx, filter)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *TelegramLinkRequestRepositoryImpl) applyFilter(query *gorm.DB, filter models.TelegramLinkRequestFilter) *gorm.DB {
+	if filter.ID != nil {
+		query = query.Where("id = ?", *filter.ID)
+	}
+	if filter.CustomerID != nil {
+		query = query.Where("customer_id = ?", *filter.CustomerID)
+	}
+	if filter.TokenHash != nil {
+		query = query.Where("token_hash = ?", *filter.TokenHash)
+	}
+	if filter.Pending {
+		query = query.Where("linked_at IS NULL")
+	}
+	if filter.ActiveAt != nil {
+		query = query.Where("expires_at > ?", *filter.ActiveAt)
+	}
+	return query
+}
@@ -44,6 +44,107 @@ type CustomerShareAggregate struct {
 	TaxShare           uint64 `json:"tax_share"`
 }
 
+// AgencyCommissionCustomerAggregate is a report row of what a referred
+// customer paid and the commission its agency accrued from it
+type AgencyCommissionCustomerAggregate struct {
+	CustomerID              uint   `json:"customer_id"`
+	RepresentativeFirstName string `json:"representative_first_name"`
+	RepresentativeLastName  string `json:"representative_last_name"`
+	CompanyName             string `json:"company_name"`
+	Charges                 int64  `json:"charges"`
+	RevenueWithTax          uint64 `json:"revenue_with_tax"`
+	CommissionWithTax       uint64 `json:"commission_with_tax"`
+}
+
+// AgencyCommissionMonthAggregate is a report row of the commission an agency
+// accrued and was paid out in a Tehran calendar month
+type AgencyCommissionMonthAggregate struct {
+	Month   string `json:"month"` // YYYY-MM
+	Accrued uint64 `json:"accrued"`
+	Settled uint64 `json:"settled"`
+}
+
+// FinancialPeriodAggregate is a report row of the platform's money flows in
+// a Tehran calendar day or week
+type FinancialPeriodAggregate struct {
+	Period             string `json:"period"` // YYYY-MM-DD the period starts on
+	Charges            int64  `json:"charges"`
+	RevenueWithTax     uint64 `json:"revenue_with_tax"`
+	TaxCollected       uint64 `json:"tax_collected"`
+	SystemShare        uint64 `json:"system_share"`
+	AgencyShareWithTax uint64 `json:"agency_share_with_tax"`
+	CampaignSpend      uint64 `json:"campaign_spend"`
+	CampaignRefunds    uint64 `json:"campaign_refunds"`
+}
+
+// TopCustomerAggregate is a report row of what a customer paid and spent on
+// campaigns in a range
+type TopCustomerAggregate struct {
+	CustomerID              uint   `json:"customer_id"`
+	RepresentativeFirstName string `json:"representative_first_name"`
+	RepresentativeLastName  string `json:"representative_last_name"`
+	CompanyName             string `json:"company_name"`
+	Charges                 int64  `json:"charges"`
+	RevenueWithTax          uint64 `json:"revenue_with_tax"`
+	CampaignSpend           uint64 `json:"campaign_spend"`
+	CampaignRefunds         uint64 `json:"campaign_refunds"`
+}
+
+// CampaignFrozenLedger is the budget a campaign holds frozen according to
+// its transactions, with its latest budget reservation
+type CampaignFrozenLedger struct {
+	CampaignID               uint       `json:"campaign_id"`
+	CampaignUUID             string     `json:"campaign_uuid"`
+	CustomerID               uint       `json:"customer_id"`
+	WalletID                 *uint      `json:"wallet_id"`
+	Status                   string     `json:"status"`
+	NetFrozen                int64      `json:"net_frozen"` // frozen moved in minus frozen moved out
+	Transactions             int64      `json:"transactions"`
+	LastTransactionAt        *time.Time `json:"last_transaction_at"`
+	ReservedAmount           *uint64    `json:"reserved_amount"`
+	ReservationUUID          *string    `json:"reservation_uuid"`
+	ReservationCorrelationID *string    `json:"reservation_correlation_id"`
+}
+
+// WalletFrozenLedger compares a wallet's current frozen balance with the
+// frozen budget its campaign transactions add up to
+type WalletFrozenLedger struct {
+	WalletID       uint      `json:"wallet_id"`
+	WalletUUID     string    `json:"wallet_uuid"`
+	CustomerID     uint      `json:"customer_id"`
+	SnapshotID     uint      `json:"snapshot_id"`
+	SnapshotAt     time.Time `json:"snapshot_at"`
+	FrozenBalance  uint64    `json:"frozen_balance"`
+	CampaignFrozen int64     `json:"campaign_frozen"`
+}
+
+// Report granularities of AggregateFinancialPeriods
+const (
+	ReportGranularityDaily  = "daily"
+	ReportGranularityWeekly = "weekly"
+)
+
+// Sources of the wallet charges financial reports count, by gateway and crypto
+var (
+	reportRevenueSources     = []string{models.TransactionSourceIncreaseCustomerFreePlusCredit, models.TransactionSourceCryptoIncreaseCustomerFreePlusCredit}
+	reportTaxSources         = []string{models.TransactionSourceIncreaseTaxSystemShare, models.TransactionSourceCryptoIncreaseTaxSystemShare}
+	reportSystemShareSources = []string{models.TransactionSourceIncreaseRealSystemShare, models.TransactionSourceCryptoIncreaseRealSystemShare}
+)
+
+// Operations that move campaign budget into and back out of spent_on_campaign
+const reportCampaignSpendOperation = "approve_campaign_budget_consume"
+
+// campaignFrozenDelta is what a transaction moved into (positive) or out of
+// (negative) frozen balance
+const campaignFrozenDelta = "COALESCE((t.balance_after->>'frozen')::bigint, 0) - COALESCE((t.balance_before->>'frozen')::bigint, 0)"
+
+var reportCampaignRefundOperations = []string{
+	"cancel_campaign_refund_spent",
+	"cancel_campaign_refund_spent_after_approval",
+	"partial_undelivered_messages_refund",
+	"stop_campaign_refund_unsent",
+}
+
 // TransactionRepositoryImpl implements TransactionRepository interface
 type TransactionRepositoryImpl struct {
 	*BaseRepository[models.Transaction, models.TransactionFilter]
@@ -95,6 +196,17 @@ func (r *TransactionRepositoryImpl) UpdateMetadata(ctx context.Context, id uint,
 		}).Error
 }
 
+// UpdateStatus updates only the status of a transaction
+func (r *TransactionRepositoryImpl) UpdateStatus(ctx context.Context, id uint, status models.TransactionStatus, updatedAt time.Time) error {
+	db := r.getDB(ctx)
+	return db.Model(&models.Transaction{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"status":     status,
+			"updated_at": updatedAt,
+		}).Error
+}
+
 // ByCorrelationID finds transactions by correlation ID
 func (r *TransactionRepositoryImpl) ByCorrelationID(ctx context.Context, correlationID uuid.UUID) ([]*models.Transaction, error) {
 	db := r.getDB(ctx)
@@ -212,7 +324,7 @@ func (r *TransactionRepositoryImpl) GetCompletedTransactions(ctx context.Context
 
 // GetAdminListWithCustomer retrieves filtered transactions and preloads full customer records.
 func (r *TransactionRepositoryImpl) GetAdminListWithCustomer(ctx context.Context, filter models.TransactionFilter, orderBy string, limit, offset int) ([]*models.Transaction, error) {
-	db := r.getDB(ctx)
+	db := r.getReadDB(ctx)
 	var transactions []*models.Transaction
 
 	query := db.Model(&models.Transaction{}).
@@ -220,6 +332,8 @@ func (r *TransactionRepositoryImpl) GetAdminListWithCustomer(ctx context.Context
 		Preload("Customer.AccountType")
 	query = r.applyFilter(query, filter)
 
+	// An admin list sort comes first
+	query = applyListSorts(query, filter.List)
 	if orderBy != "" {
 		query = query.Order(orderBy)
 	} else {
@@ -265,7 +379,14 @@ func (r *TransactionRepositoryImpl) ByFilter(ctx context.Context, filter models.
 	return transactions, nil
 }
 
-// SaveBatch inserts multiple transactions in a single transaction
+// Save inserts a transaction, flagged as sandbox when its customer is a
+// sandbox account
+func (r *TransactionRepositoryImpl) Save(ctx context.Context, transaction *models.Transaction) error {
+	return r.SaveBatch(ctx, []*models.Transaction{transaction})
+}
+
+// SaveBatch inserts multiple transactions in a single transaction. The
+// transactions of sandbox customers are flagged as sandbox.
 func (r *TransactionRepositoryImpl) SaveBatch(ctx context.Context, transactions []*models.Transaction) error {
 	if len(transactions) == 0 {
 		return nil
@@ -286,6 +407,9 @@ func (r *TransactionRepositoryImpl) SaveBatch(ctx context.Context, transactions
 		}()
 	}
 
+	if err = tagSandbox(db, transactions); err != nil {
+		return err
+	}
 	err = db.CreateInBatches(transactions, 100).Error
 	if err != nil {
 		return err
@@ -294,6 +418,65 @@ func (r *TransactionRepositoryImpl) SaveBatch(ctx context.Context, transactions
 	return nil
 }
 
+// tagSandbox flags the transactions of sandbox customers, so test money can
+// be told apart from real money and left out of financial reports
+func tagSandbox(db *gorm.DB, transactions []*models.Transaction) error {
+	customerIDs := make([]uint, 0, len(transactions))
+	for _, t := range transactions {
+		customerIDs = append(customerIDs, t.CustomerID)
+	}
+	var sandboxIDs []uint
+	if err := db.Model(&models.Customer{}).
+		Where("id IN ? AND is_sandbox", customerIDs).
+		Pluck("id", &sandboxIDs).Error; err != nil {
+		return err
+	}
+	if len(sandboxIDs) == 0 {
+		return nil
+	}
+	sandbox := make(map[uint]bool, len(sandboxIDs))
+	for _, id := range sandboxIDs {
+		sandbox[id] = true
+	}
+	for _, t := range transactions {
+		if sandbox[t.CustomerID] {
+			t.IsSandbox = true
+		}
+	}
+	return nil
+}
+
+// DeleteSandboxByCustomerID deletes the sandbox transactions of a customer
+// that no invoice, postpaid draw or wallet adjustment refers to, and returns
+// how many were deleted
+func (r *TransactionRepositoryImpl) DeleteSandboxByCustomerID(ctx context.Context, customerID uint) (int64, error) {
+	db, shouldCommit, err := r.getDBForWrite(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	if shouldCommit {
+		defer func() {
+			if err != nil {
+				db.Rollback()
+			} else {
+				db.Commit()
+			}
+		}()
+	}
+
+	res := db.Unscoped().
+		Where("customer_id = ? AND is_sandbox", customerID).
+		Where("id NOT IN (SELECT transaction_id FROM tax_invoices)").
+		Where("id NOT IN (SELECT transaction_id FROM postpaid_draws)").
+		Where("id NOT IN (SELECT transaction_id FROM wallet_adjustment_requests WHERE transaction_id IS NOT NULL)").
+		Delete(&models.Transaction{})
+	if err = res.Error; err != nil {
+		return 0, err
+	}
+	return res.RowsAffected, nil
+}
+
 // Count returns the number of transactions matching the filter
 func (r *TransactionRepositoryImpl) Count(ctx context.Context, filter models.TransactionFilter) (int64, error) {
 	db := r.getDB(ctx)
@@ -320,17 +503,18 @@ func (r *TransactionRepositoryImpl) GetHistoryWithMetadata(
 	status *models.TransactionStatus,
 	limit, offset int,
 ) ([]*models.Transaction, int64, error) {
-	db := r.getDB(ctx)
+	db := r.getReadDB(ctx)
 
 	query := db.Model(&models.Transaction{}).
 		Where("wallet_id = ?", walletID).
 		Where("customer_id = ?", customerID).
-		Where(`((metadata->>'source' = ? AND metadata->>'operation' = ?) OR (metadata->>'source' = ? AND metadata->>'operation' = ?) OR (metadata->>'source' = ? AND metadata->>'operation' = ?) OR (metadata->>'source' = ? AND metadata->>'operation' = ?) OR (metadata->>'source' = ? AND metadata->>'operation' = ?))`,
+		Where(`((metadata->>'source' = ? AND metadata->>'operation' = ?) OR (metadata->>'source' = ? AND metadata->>'operation' = ?) OR (metadata->>'source' = ? AND metadata->>'operation' = ?) OR (metadata->>'source' = ? AND metadata->>'operation' = ?) OR (metadata->>'source' = ? AND metadata->>'operation' = ?) OR (metadata->>'source' = ? AND metadata->>'operation' = ?))`,
 			models.TransactionSourceIncreaseCustomerFreePlusCredit, "increase_customer_free_plus_credit",
 			models.TransactionSourceIncreaseAgencyShareWithTax, "increase_agency_share_with_tax",
 			"campaign_partial_refund", "partial_undelivered_messages_refund",
 			"admin_campaign_cancel", "cancel_campaign_refund_frozen_missed_approval_deadline",
-			"admin_campaign_cancel", "cancel_campaign_refund_spent")
+			"admin_campaign_cancel", "cancel_campaign_refund_spent",
+			models.TransactionSourceSandboxTopUp, "sandbox_top_up")
 
 	if startDate != nil {
 		query = query.Where("created_at >= ?", *startDate)
@@ -435,12 +619,12 @@ func (r *TransactionRepositoryImpl) applyFilter(query *gorm.DB, filter models.Tr
 		query = query.Where("(metadata->>'campaign_id')::bigint = ?", *filter.CampaignID)
 	}
 
-	return query
+	return applyListConditions(query, filter.List)
 }
 
 // AggregateAgencyTransactionsByCustomers aggregates transaction amounts per customer under an agency based on metadata
 func (r *TransactionRepositoryImpl) AggregateAgencyTransactionsByCustomers(ctx context.Context, agencyID uint, nameLike string, startDate, endDate *time.Time, orderBy string) ([]*AgencyCustomerTransactionAggregate, error) {
-	db := r.getDB(ctx)
+	db := r.getReadDB(ctx)
 	rows := make([]*AgencyCustomerTransactionAggregate, 0)
 
 	allowed := map[string]string{
@@ -484,7 +668,7 @@ func (r *TransactionRepositoryImpl) AggregateAgencyTransactionsByCustomers(ctx c
 
 // AggregateAgencyTransactionsByDiscounts aggregates transactions by agency_discount_id for a given agency and customer
 func (r *TransactionRepositoryImpl) AggregateAgencyTransactionsByDiscounts(ctx context.Context, agencyID uint, customerID uint, orderBy string) ([]*AgencyCustomerDiscountAggregate, error) {
-	db := r.getDB(ctx)
+	db := r.getReadDB(ctx)
 	rows := make([]*AgencyCustomerDiscountAggregate, 0)
 
 	allowed := map[string]string{
@@ -516,7 +700,7 @@ func (r *TransactionRepositoryImpl) AggregateAgencyTransactionsByDiscounts(ctx c
 
 // AggregateCustomerTransactionsByDiscounts aggregates discounts used by a customer across all agencies
 func (r *TransactionRepositoryImpl) AggregateCustomerTransactionsByDiscounts(ctx context.Context, customerID uint, orderBy string) ([]*AgencyCustomerDiscountAggregate, error) {
-	db := r.getDB(ctx)
+	db := r.getReadDB(ctx)
 	rows := make([]*AgencyCustomerDiscountAggregate, 0)
 
 	allowed := map[string]string{
@@ -547,7 +731,7 @@ func (r *TransactionRepositoryImpl) AggregateCustomerTransactionsByDiscounts(ctx
 
 // AggregateCustomersShares aggregates agency/system/tax shares per customer across the platform
 func (r *TransactionRepositoryImpl) AggregateCustomersShares(ctx context.Context, startDate, endDate *time.Time) ([]*CustomerShareAggregate, error) {
-	db := r.getDB(ctx)
+	db := r.getReadDB(ctx)
 	rows := make([]*CustomerShareAggregate, 0)
 
 	agSub := db.
@@ -607,3 +791,245 @@ func (r *TransactionRepositoryImpl) AggregateCustomersShares(ctx context.Context
 	}
 	return rows, nil
 }
+
+// AggregateAgencyCommissionByCustomers sums, per referred customer, the
+// charges that accrued commission for the agency, what the customer paid in
+// them and the commission
+func (r *TransactionRepositoryImpl) AggregateAgencyCommissionByCustomers(ctx context.Context, agencyID uint, startDate, endDate *time.Time) ([]*AgencyCommissionCustomerAggregate, error) {
+	rows := make([]*AgencyCommissionCustomerAggregate, 0)
+	query := r.getReadDB(ctx).
+		Table("transactions t").
+		Select(`u.id AS customer_id, u.representative_first_name AS representative_first_name,
+			u.representative_last_name AS representative_last_name, COALESCE(u.company_name, '') AS company_name,
+			COUNT(*) AS charges,
+			COALESCE(SUM(COALESCE((t.metadata->>'amount_with_tax')::bigint, 0)), 0) AS revenue_with_tax,
+			COALESCE(SUM(t.amount), 0) AS commission_with_tax`).
+		Joins("JOIN customers u ON u.id = (t.metadata->>'customer_id')::bigint").
+		Where("t.customer_id = ?", agencyID).
+		Where("t.type = ?", models.TransactionTypeChargeAgencyShareWithTax).
+		Where("t.status = ?", models.TransactionStatusCompleted).
+		Where("t.metadata->>'source' = ?", models.TransactionSourceIncreaseAgencyShareWithTax).
+		Group("u.id, u.representative_first_name, u.representative_last_name, u.company_name").
+		Order("commission_with_tax DESC, u.id ASC")
+	if startDate != nil {
+		query = query.Where("t.created_at >= ?", *startDate)
+	}
+	if endDate != nil {
+		query = query.Where("t.created_at <= ?", *endDate)
+	}
+	if err := query.Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// AggregateAgencyCommissionByMonth sums the commission an agency accrued
+// (agency share charges) and was paid out (agency share discharges) per
+// Tehran calendar month, oldest first
+func (r *TransactionRepositoryImpl) AggregateAgencyCommissionByMonth(ctx context.Context, agencyID uint, startDate, endDate *time.Time) ([]*AgencyCommissionMonthAggregate, error) {
+	rows := make([]*AgencyCommissionMonthAggregate, 0)
+	query := r.getReadDB(ctx).
+		Table("transactions t").
+		Select(`to_char(t.created_at AT TIME ZONE 'Asia/Tehran', 'YYYY-MM') AS month,
+			COALESCE(SUM(CASE WHEN t.type = ? THEN t.amount ELSE 0 END), 0) AS accrued,
+			COALESCE(SUM(CASE WHEN t.type = ? THEN t.amount ELSE 0 END), 0) AS settled`,
+			models.TransactionTypeChargeAgencyShareWithTax, models.TransactionTypeDischargeAgencyShareWithTax).
+		Where("t.customer_id = ?", agencyID).
+		Where("t.type IN ?", []models.TransactionType{models.TransactionTypeChargeAgencyShareWithTax, models.TransactionTypeDischargeAgencyShareWithTax}).
+		Where("t.status = ?", models.TransactionStatusCompleted).
+		Group("month").
+		Order("month ASC")
+	if startDate != nil {
+		query = query.Where("t.created_at >= ?", *startDate)
+	}
+	if endDate != nil {
+		query = query.Where("t.created_at <= ?", *endDate)
+	}
+	if err := query.Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// SumCustomerDepositsWithTax sums what a customer paid, tax included, in the
+// wallet charges completed in [startDate, endDate)
+func (r *TransactionRepositoryImpl) SumCustomerDepositsWithTax(ctx context.Context, customerID uint, startDate, endDate time.Time) (uint64, error) {
+	var sum uint64
+	err := r.getDB(ctx).
+		Model(&models.Transaction{}).
+		Select("COALESCE(SUM(COALESCE((metadata->>'amount_with_tax')::bigint, 0)), 0)").
+		Where("customer_id = ?", customerID).
+		Where("type = ?", models.TransactionTypeDeposit).
+		Where("status = ?", models.TransactionStatusCompleted).
+		Where("created_at >= ? AND created_at < ?", startDate, endDate).
+		Scan(&sum).Error
+	if err != nil {
+		return 0, err
+	}
+	return sum, nil
+}
+
+// reportPeriodExpr truncates created_at to the Tehran day or to the Tehran
+// week, which starts on Saturday
+func reportPeriodExpr(granularity string) string {
+	if granularity == ReportGranularityWeekly {
+		return "to_char(date_trunc('week', (t.created_at AT TIME ZONE 'Asia/Tehran') + INTERVAL '2 days') - INTERVAL '2 days', 'YYYY-MM-DD')"
+	}
+	return "to_char(date_trunc('day', t.created_at AT TIME ZONE 'Asia/Tehran'), 'YYYY-MM-DD')"
+}
+
+// AggregateFinancialPeriods sums, per Tehran day or week in [startDate,
+// endDate), the wallet charges and what they paid, the tax, system and agency
+// shares split from them, and the campaign budget spent and refunded. Periods
+// without any of these are omitted; oldest first.
+func (r *TransactionRepositoryImpl) AggregateFinancialPeriods(ctx context.Context, granularity string, startDate, endDate time.Time) ([]*FinancialPeriodAggregate, error) {
+	rows := make([]*FinancialPeriodAggregate, 0)
+	err := financialPeriodsQuery(r.getReadDB(ctx), granularity, startDate, endDate).
+		Order("period ASC").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// financialPeriodsQuery selects the columns of FinancialPeriodAggregate per
+// period; the revenue_daily rollup is refreshed from it
+func financialPeriodsQuery(db *gorm.DB, granularity string, startDate, endDate time.Time) *gorm.DB {
+	return db.
+		Table("transactions t").
+		Select(reportPeriodExpr(granularity)+` AS period,
+			COUNT(*) FILTER (WHERE t.type = ? AND t.metadata->>'source' IN ?) AS charges,
+			COALESCE(SUM(COALESCE((t.metadata->>'amount_with_tax')::bigint, t.amount)) FILTER (WHERE t.type = ? AND t.metadata->>'source' IN ?), 0) AS revenue_with_tax,
+			COALESCE(SUM(t.amount) FILTER (WHERE t.type = ? AND t.metadata->>'source' IN ?), 0) AS tax_collected,
+			COALESCE(SUM(t.amount) FILTER (WHERE t.type = ? AND t.metadata->>'source' IN ?), 0) AS system_share,
+			COALESCE(SUM(t.amount) FILTER (WHERE t.type = ?), 0) AS agency_share_with_tax,
+			COALESCE(SUM(t.amount) FILTER (WHERE t.type = ? AND t.metadata->>'operation' = ?), 0) AS campaign_spend,
+			COALESCE(SUM(t.amount) FILTER (WHERE t.type = ? AND t.metadata->>'operation' IN ?), 0) AS campaign_refunds`,
+			models.TransactionTypeDeposit, reportRevenueSources,
+			models.TransactionTypeDeposit, reportRevenueSources,
+			models.TransactionTypeLock, reportTaxSources,
+			models.TransactionTypeLock, reportSystemShareSources,
+			models.TransactionTypeChargeAgencyShareWithTax,
+			models.TransactionTypeFee, reportCampaignSpendOperation,
+			models.TransactionTypeRefund, reportCampaignRefundOperations).
+		Where("t.status = ?", models.TransactionStatusCompleted).
+		Where("NOT t.is_sandbox").
+		Where("t.type IN ?", []models.TransactionType{
+			models.TransactionTypeDeposit,
+			models.TransactionTypeLock,
+			models.TransactionTypeChargeAgencyShareWithTax,
+			models.TransactionTypeFee,
+			models.TransactionTypeRefund,
+		}).
+		Where("t.created_at >= ? AND t.created_at < ?", startDate, endDate).
+		Group("period")
+}
+
+// AggregateTopCustomers ranks customers by what they paid for wallet charges
+// in [startDate, endDate), reporting their campaign spend alongside
+func (r *TransactionRepositoryImpl) AggregateTopCustomers(ctx context.Context, startDate, endDate time.Time, limit int) ([]*TopCustomerAggregate, error) {
+	rows := make([]*TopCustomerAggregate, 0)
+	err := r.getReadDB(ctx).
+		Table("transactions t").
+		Select(`u.id AS customer_id, u.representative_first_name AS representative_first_name,
+			u.representative_last_name AS representative_last_name, COALESCE(u.company_name, '') AS company_name,
+			COUNT(*) FILTER (WHERE t.type = ?) AS charges,
+			COALESCE(SUM(COALESCE((t.metadata->>'amount_with_tax')::bigint, t.amount)) FILTER (WHERE t.type = ?), 0) AS revenue_with_tax,
+			COALESCE(SUM(t.amount) FILTER (WHERE t.type = ?), 0) AS campaign_spend,
+			COALESCE(SUM(t.amount) FILTER (WHERE t.type = ?), 0) AS campaign_refunds`,
+			models.TransactionTypeDeposit, models.TransactionTypeDeposit,
+			models.TransactionTypeFee, models.TransactionTypeRefund).
+		Joins("JOIN customers u ON u.id = t.customer_id").
+		Where("t.status = ?", models.TransactionStatusCompleted).
+		Where("NOT t.is_sandbox").
+		Where(`((t.type = ? AND t.metadata->>'source' IN ?)
+			OR (t.type = ? AND t.metadata->>'operation' = ?)
+			OR (t.type = ? AND t.metadata->>'operation' IN ?))`,
+			models.TransactionTypeDeposit, reportRevenueSources,
+			models.TransactionTypeFee, reportCampaignSpendOperation,
+			models.TransactionTypeRefund, reportCampaignRefundOperations).
+		Where("t.created_at >= ? AND t.created_at < ?", startDate, endDate).
+		Group("u.id, u.representative_first_name, u.representative_last_name, u.company_name").
+		Order("revenue_with_tax DESC, campaign_spend DESC, u.id ASC").
+		Limit(limit).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// CampaignFrozenLedgers returns the frozen budget ledger of every non-sandbox
+// campaign waiting for approval or whose transactions do not net to zero
+// frozen. Only transactions on the campaign owner's wallet are counted.
+func (r *TransactionRepositoryImpl) CampaignFrozenLedgers(ctx context.Context) ([]*CampaignFrozenLedger, error) {
+	rows := make([]*CampaignFrozenLedger, 0)
+	err := r.getReadDB(ctx).Raw(`
+		WITH moves AS (
+			SELECT c.id AS campaign_id, SUM(`+campaignFrozenDelta+`) AS net_frozen,
+				COUNT(*) AS transactions, MAX(t.created_at) AS last_transaction_at
+			FROM transactions t
+			JOIN campaigns c ON c.id = (t.metadata->>'campaign_id')::bigint AND c.customer_id = t.customer_id
+			WHERE t.metadata->>'campaign_id' IS NOT NULL AND t.status = ?
+			GROUP BY c.id
+		), reservations AS (
+			SELECT DISTINCT ON ((t.metadata->>'campaign_id')::bigint)
+				(t.metadata->>'campaign_id')::bigint AS campaign_id, t.amount, t.uuid::text AS uuid,
+				t.correlation_id::text AS correlation_id
+			FROM transactions t
+			WHERE t.type = ? AND t.status = ?
+				AND t.metadata->>'source' = 'campaign_update' AND t.metadata->>'operation' = 'reserve_budget'
+			ORDER BY (t.metadata->>'campaign_id')::bigint, t.id DESC
+		)
+		SELECT c.id AS campaign_id, c.uuid::text AS campaign_uuid, c.customer_id, w.id AS wallet_id, c.status,
+			COALESCE(m.net_frozen, 0) AS net_frozen, COALESCE(m.transactions, 0) AS transactions,
+			m.last_transaction_at, res.amount AS reserved_amount, res.uuid AS reservation_uuid,
+			res.correlation_id AS reservation_correlation_id
+		FROM campaigns c
+		LEFT JOIN moves m ON m.campaign_id = c.id
+		LEFT JOIN reservations res ON res.campaign_id = c.id
+		LEFT JOIN wallets w ON w.customer_id = c.customer_id
+		WHERE NOT c.is_sandbox AND (c.status = ? OR COALESCE(m.net_frozen, 0) <> 0)
+		ORDER BY c.id`,
+		models.TransactionStatusCompleted,
+		models.TransactionTypeFreeze, models.TransactionStatusCompleted,
+		models.CampaignStatusWaitingForApproval,
+	).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// WalletFrozenLedgers returns every non-sandbox wallet whose latest balance
+// snapshot or campaign transactions hold frozen budget
+func (r *TransactionRepositoryImpl) WalletFrozenLedgers(ctx context.Context) ([]*WalletFrozenLedger, error) {
+	rows := make([]*WalletFrozenLedger, 0)
+	err := r.getReadDB(ctx).Raw(`
+		WITH latest AS (
+			SELECT DISTINCT ON (bs.wallet_id) bs.wallet_id, bs.id AS snapshot_id, bs.created_at AS snapshot_at, bs.frozen_balance
+			FROM balance_snapshots bs
+			WHERE bs.deleted_at IS NULL
+			ORDER BY bs.wallet_id, bs.created_at DESC, bs.id DESC
+		), moves AS (
+			SELECT t.wallet_id, SUM(`+campaignFrozenDelta+`) AS campaign_frozen
+			FROM transactions t
+			WHERE t.metadata->>'campaign_id' IS NOT NULL AND t.status = ?
+			GROUP BY t.wallet_id
+		)
+		SELECT w.id AS wallet_id, w.uuid::text AS wallet_uuid, w.customer_id, l.snapshot_id, l.snapshot_at,
+			l.frozen_balance, COALESCE(m.campaign_frozen, 0) AS campaign_frozen
+		FROM wallets w
+		JOIN customers c ON c.id = w.customer_id
+		JOIN latest l ON l.wallet_id = w.id
+		LEFT JOIN moves m ON m.wallet_id = w.id
+		WHERE NOT c.is_sandbox AND (l.frozen_balance <> 0 OR COALESCE(m.campaign_frozen, 0) <> 0)
+		ORDER BY w.id`,
+		models.TransactionStatusCompleted,
+	).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
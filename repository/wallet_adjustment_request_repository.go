@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// WalletAdjustmentRequestRepositoryImpl implements WalletAdjustmentRequestRepository
+type WalletAdjustmentRequestRepositoryImpl struct {
+	*BaseRepository[models.WalletAdjustmentRequest, models.WalletAdjustmentRequestFilter]
+}
+
+// NewWalletAdjustmentRequestRepository creates a new wallet adjustment request repository
+func NewWalletAdjustmentRequestRepository(db *gorm.DB) WalletAdjustmentRequestRepository {
+	return &WalletAdjustmentRequestRepositoryImpl{
+		BaseRepository: NewBaseRepository[models.WalletAdjustmentRequest, models.WalletAdjustmentRequestFilter](db),
+	}
+}
+
+// ByUUID retrieves an adjustment request by UUID
+func (r *WalletAdjustmentRequestRepositoryImpl) ByUUID(ctx context.Context, id uuid.UUID) (*models.WalletAdjustmentRequest, error) {
+	var req models.WalletAdjustmentRequest
+	if err := r.getDB(ctx).Where("uuid = ?", id).Last(&req).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &req, nil
+}
+
+// MarkReviewed records the review of a request that is still pending, and
+// reports false when another admin reviewed it first
+func (r *WalletAdjustmentRequestRepositoryImpl) MarkReviewed(ctx context.Context, req *models.WalletAdjustmentRequest) (bool, error) {
+	res := r.getDB(ctx).Model(&models.WalletAdjustmentRequest{}).
+		Where("id = ? AND status = ?", req.ID, models.WalletAdjustmentStatusPending).
+		Updates(map[string]any{
+			"status":               req.Status,
+			"reviewed_by_admin_id": req.ReviewedByAdminID,
+			"review_note":          req.ReviewNote,
+			"reviewed_at":          req.ReviewedAt,
+			"transaction_id":       req.TransactionID,
+			"updated_at":           req.UpdatedAt,
+		})
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
+
+// ByFilter returns adjustment requests matching the filter
+func (r *WalletAdjustmentRequestRepositoryImpl) ByFilter(ctx context.Context, filter models.WalletAdjustmentRequestFilter, orderBy string, limit, offset int) ([]*models.WalletAdjustmentRequest, error) {
+	db := r.applyFilter(r.getDB(ctx).Model(&models.WalletAdjustmentRequest{}), filter)
+	if orderBy == "" {
+		orderBy = "id DESC"
+	}
+	db = db.Order(orderBy)
+	if limit > 0 {
+		db = db.Limit(limit)
+	}
+	if offset > 0 {
+		db = db.Offset(offset)
+	}
+
+	var items []*models.WalletAdjustmentRequest
+	if err := db.Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// Count returns the number of adjustment requests matching the filter
+func (r *WalletAdjustmentRequestRepositoryImpl) Count(ctx context.Context, filter models.WalletAdjustmentRequestFilter) (int64, error) {
+	var count int64
+	if err := r.applyFilter(r.getDB(ctx).Model(&models.WalletAdjustmentRequest{}), filter).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// Exists checks whether any adjustment request matches the filter
+func (r *WalletAdjustmentRequestRepositoryImpl) Exists(ctx context.Context, filter models.WalletAdjustmentRequestFilter) (bool, error) {
+	count, err := r.Count(ctx, filter)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *WalletAdjustmentRequestRepositoryImpl) applyFilter(query *gorm.DB, filter models.WalletAdjustmentRequestFilter) *gorm.DB {
+	if filter.ID != nil {
+		query = query.Where("id = ?", *filter.ID)
+	}
+	if filter.UUID != nil {
+		query = query.Where("uuid = ?", *filter.UUID)
+	}
+	if filter.CustomerID != nil {
+		query = query.Where("customer_id = ?", *filter.CustomerID)
+	}
+	if filter.RequestedByAdminID != nil {
+		query = query.Where("requested_by_admin_id = ?", *filter.RequestedByAdminID)
+	}
+	if filter.Status != nil {
+		query = query.Where("status = ?", *filter.Status)
+	}
+	return query
+}
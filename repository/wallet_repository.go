@@ -116,6 +116,37 @@ func (r *WalletRepositoryImpl) SaveWithInitialSnapshot(ctx context.Context, wall
 
 }
 
+// LockBalance acquires a transaction-scoped advisory lock serializing
+// balance snapshot writes to one wallet. Take it before reading the latest
+// snapshot; it is released when the transaction ends.
+func (r *WalletRepositoryImpl) LockBalance(ctx context.Context, walletID uint) error {
+	return r.getDB(ctx).Exec("SELECT pg_advisory_xact_lock(hashtext('wallet_balance'), ?)", int64(walletID)).Error
+}
+
+// AdvanceVersion moves the wallet to its next version if it is still at the
+// version it was read with. Every balance snapshot writer calls it before
+// saving, after reading the wallet and then its latest snapshot, so two
+// transactions computing from the same snapshot cannot both save one. The
+// loser gets ErrVersionConflict; the winner's row lock is held until commit.
+func (r *WalletRepositoryImpl) AdvanceVersion(ctx context.Context, wallet *models.Wallet) error {
+	now := utils.UTCNow()
+	res := r.getDB(ctx).Model(&models.Wallet{}).
+		Where("id = ? AND version = ?", wallet.ID, wallet.Version).
+		Updates(map[string]any{
+			"version":    gorm.Expr("version + 1"),
+			"updated_at": now,
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrVersionConflict
+	}
+	wallet.Version++
+	wallet.UpdatedAt = now
+	return nil
+}
+
 // GetCurrentBalance gets the current balance snapshot for a wallet
 func (r *WalletRepositoryImpl) GetCurrentBalance(ctx context.Context, walletID uint) (*models.BalanceSnapshot, error) {
 	db := r.getDB(ctx)
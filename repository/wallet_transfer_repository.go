@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/amirphl/Yamata-no-Orochi/models"
+	"github.com/amirphl/Yamata-no-Orochi/utils"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// WalletTransferRepositoryImpl implements WalletTransferRepository
+type WalletTransferRepositoryImpl struct {
+	*BaseRepository[models.WalletTransfer, models.WalletTransferFilter]
+}
+
+// NewWalletTransferRepository creates a new wallet transfer repository
+func NewWalletTransferRepository(db *gorm.DB) WalletTransferRepository {
+	return &WalletTransferRepositoryImpl{
+		BaseRepository: NewBaseRepository[models.WalletTransfer, models.WalletTransferFilter](db),
+	}
+}
+
+// ByUUID retrieves a transfer by UUID
+func (r *WalletTransferRepositoryImpl) ByUUID(ctx context.Context, id uuid.UUID) (*models.WalletTransfer, error) {
+	var t models.WalletTransfer
+	if err := r.getDB(ctx).Where("uuid = ?", id).Last(&t).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &t, nil
+}
+
+// IncrementOTPAttempts counts a wrong OTP for a pending transfer
+func (r *WalletTransferRepositoryImpl) IncrementOTPAttempts(ctx context.Context, id uint) error {
+	return r.getDB(ctx).Model(&models.WalletTransfer{}).
+		Where("id = ? AND status = ?", id, models.WalletTransferStatusPending).
+		Updates(map[string]any{
+			"otp_attempts": gorm.Expr("otp_attempts + 1"),
+			"updated_at":   utils.UTCNow(),
+		}).Error
+}
+
+// Expire ends a pending transfer without changing either wallet
+func (r *WalletTransferRepositoryImpl) Expire(ctx context.Context, id uint) error {
+	return r.getDB(ctx).Model(&models.WalletTransfer{}).
+		Where("id = ? AND status = ?", id, models.WalletTransferStatusPending).
+		Updates(map[string]any{
+			"status":     models.WalletTransferStatusExpired,
+			"updated_at": utils.UTCNow(),
+		}).Error
+}
+
+// MarkCompleted records the transactions of a transfer that is still
+// pending, and reports false when it was completed or expired first
+func (r *WalletTransferRepositoryImpl) MarkCompleted(ctx context.Context, t *models.WalletTransfer) (bool, error) {
+	res := r.getDB(ctx).Model(&models.WalletTransfer{}).
+		Where("id = ? AND status = ?", t.ID, models.WalletTransferStatusPending).
+		Updates(map[string]any{
+			"status":                  models.WalletTransferStatusCompleted,
+			"sender_transaction_id":   t.SenderTransactionID,
+			"receiver_transaction_id": t.ReceiverTransactionID,
+			"completed_at":            t.CompletedAt,
+			"updated_at":              t.UpdatedAt,
+		})
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
+
+// SumSentSince returns the amount of the transfers a customer completed
+// since the given time
+func (r *WalletTransferRepositoryImpl) SumSentSince(ctx context.Context, senderCustomerID uint, since time.Time) (uint64, error) {
+	var total uint64
+	err := r.getDB(ctx).Model(&models.WalletTransfer{}).
+		Select("COALESCE(SUM(amount), 0)").
+		Where("sender_customer_id = ? AND status = ? AND completed_at >= ?", senderCustomerID, models.WalletTransferStatusCompleted, since).
+		Scan(&total).Error
+	if err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// ByFilter returns transfers matching the filter
+func (r *WalletTransferRepositoryImpl) ByFilter(ctx context.Context, filter models.WalletTransferFilter, orderBy string, limit, offset int) ([]*models.WalletTransfer, error) {
+	db := r.applyFilter(r.getDB(ctx).Model(&models.WalletTransfer{}), filter)
+	if orderBy == "" {
+		orderBy = "id DESC"
+	}
+	db = db.Order(orderBy)
+	if limit > 0 {
+		db = db.Limit(limit)
+	}
+	if offset > 0 {
+		db = db.Offset(offset)
+	}
+
+	var items []*models.WalletTransfer
+	if err := db.Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// Count returns the number of transfers matching the filter
+func (r *WalletTransferRepositoryImpl) Count(ctx context.Context, filter models.WalletTransferFilter) (int64, error) {
+	var count int64
+	if err := r.applyFilter(r.getDB(ctx).Model(&models.WalletTransfer{}), filter).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// Exists checks whether any transfer matches the filter
+func (r *WalletTransferRepositoryImpl) Exists(ctx context.Context, filter models.WalletTransferFilter) (bool, error) {
+	count, err := r.Count(ctx, filter)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *WalletTransferRepositoryImpl) applyFilter(query *gorm.DB, filter models.WalletTransferFilter) *gorm.DB {
+	if filter.ID != nil {
+		query = query.Where("id = ?", *filter.ID)
+	}
+	if filter.UUID != nil {
+		query = query.Where("uuid = ?", *filter.UUID)
+	}
+	if filter.SenderCustomerID != nil {
+		query = query.Where("sender_customer_id = ?", *filter.SenderCustomerID)
+	}
+	if filter.ReceiverCustomerID != nil {
+		query = query.Where("receiver_customer_id = ?", *filter.ReceiverCustomerID)
+	}
+	if filter.CustomerID != nil {
+		query = query.Where("sender_customer_id = ? OR receiver_customer_id = ?", *filter.CustomerID, *filter.CustomerID)
+	}
+	if filter.Status != nil {
+		query = query.Where("status = ?", *filter.Status)
+	}
+	return query
+}
@@ -0,0 +1,31 @@
+// Package templates embeds the HTML pages the server renders, so the binary
+// does not depend on files next to it at runtime
+package templates
+
+import (
+	"embed"
+	"fmt"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/i18n"
+)
+
+//go:embed *.html
+var files embed.FS
+
+// PaymentResult is the page a customer lands on when the payment gateway
+// redirects back. It expects Success, Status, Message, TotalAmount,
+// TaxAmount, TaxPercent, NetAmount, ReferenceNumber, TraceNumber, RRN,
+// MaskedPAN and ProcessedAt.
+var PaymentResult = mustParse("payment_result.html")
+
+func mustParse(name string) *i18n.Page {
+	text, err := files.ReadFile(name)
+	if err != nil {
+		panic(fmt.Sprintf("templates: read %s: %v", name, err))
+	}
+	page, err := i18n.ParsePage(name, string(text))
+	if err != nil {
+		panic(fmt.Sprintf("templates: parse %s: %v", name, err))
+	}
+	return page
+}
@@ -0,0 +1,87 @@
+package templates_test
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/amirphl/Yamata-no-Orochi/app/i18n"
+	"github.com/amirphl/Yamata-no-Orochi/templates"
+)
+
+func paymentResultData(success bool) map[string]any {
+	return map[string]any{
+		"Success": success, "Status": "<failed>", "Message": "Payment failed", "TotalAmount": 110000, "TaxAmount": 10000,
+		"TaxPercent": 10.0, "NetAmount": 100000, "ReferenceNumber": "r", "TraceNumber": "t", "RRN": "n", "MaskedPAN": "p", "ProcessedAt": "now",
+	}
+}
+
+func TestPaymentResultLocalized(t *testing.T) {
+	t.Parallel()
+
+	html, err := templates.PaymentResult.Render(i18n.LocalePersian, paymentResultData(false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`lang="fa" dir="rtl"`, "پرداخت ناموفق بود", "110000 تومان", "(10%)", "&lt;failed&gt;"} {
+		if !strings.Contains(html, want) {
+			t.Errorf("Persian page lacks %q", want)
+		}
+	}
+
+	html, err = templates.PaymentResult.Render(i18n.LocaleEnglish, paymentResultData(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`lang="en" dir="ltr"`, "Payment Successful!", "Return to Wallet"} {
+		if !strings.Contains(html, want) {
+			t.Errorf("English page lacks %q", want)
+		}
+	}
+}
+
+func TestPaymentResultEscapesGatewayValues(t *testing.T) {
+	t.Parallel()
+
+	// Reference, trace and card values come from the gateway callback
+	data := paymentResultData(true)
+	data["ReferenceNumber"] = `<script>alert("ref")</script>`
+	data["MaskedPAN"] = `"><img src=x onerror=alert(1)>`
+
+	html, err := templates.PaymentResult.Render(i18n.LocaleEnglish, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, raw := range []string{`<script>alert("ref")`, `<img src=x`} {
+		if strings.Contains(html, raw) {
+			t.Errorf("page contains unescaped %q", raw)
+		}
+	}
+	for _, want := range []string{"&lt;script&gt;alert(&#34;ref&#34;)&lt;/script&gt;", "&#34;&gt;&lt;img src=x onerror=alert(1)&gt;"} {
+		if !strings.Contains(html, want) {
+			t.Errorf("page lacks escaped %q", want)
+		}
+	}
+}
+
+func TestPaymentResultConcurrentLocales(t *testing.T) {
+	t.Parallel()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		locale := i18n.Locales()[i%len(i18n.Locales())]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			html, err := templates.PaymentResult.Render(locale, paymentResultData(true))
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if want := `lang="` + string(locale) + `"`; !strings.Contains(html, want) {
+				t.Errorf("%s page lacks %q", locale, want)
+			}
+		}()
+	}
+	wg.Wait()
+}
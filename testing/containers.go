@@ -0,0 +1,184 @@
+package testing
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	stdtesting "testing"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+// Test database modes, chosen with TEST_DB_MODE. In external mode the tests
+// use the Postgres and Redis named by TEST_DB_* and TEST_REDIS_URL; in
+// testcontainers mode every test package starts its own Postgres and Redis
+// containers, so packages run in parallel without sharing state.
+const (
+	TestDBModeExternal       = "external"
+	TestDBModeTestcontainers = "testcontainers"
+)
+
+// Images of the test containers; they match docker-compose.beta.yml
+const (
+	testPostgresImage = "postgres:15-alpine"
+	testRedisImage    = "redis:8.0.3-alpine"
+)
+
+const containerStartupTimeout = 2 * time.Minute
+
+// TestDBMode returns the configured test database mode
+func TestDBMode() string {
+	if strings.EqualFold(getEnv("TEST_DB_MODE", TestDBModeExternal), TestDBModeTestcontainers) {
+		return TestDBModeTestcontainers
+	}
+	return TestDBModeExternal
+}
+
+// testContainers are the containers of the running test package
+type testContainers struct {
+	postgres *testcontainers.DockerContainer
+	redis    *testcontainers.DockerContainer
+	dbConfig *TestDBConfig
+	redisURL string
+}
+
+var (
+	containersMu  sync.Mutex
+	containers    *testContainers
+	containersErr error
+)
+
+// startTestContainers starts the package's Postgres and Redis containers on
+// first use and returns them afterwards
+func startTestContainers() (*testContainers, error) {
+	containersMu.Lock()
+	defer containersMu.Unlock()
+	if containers != nil || containersErr != nil {
+		return containers, containersErr
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), containerStartupTimeout)
+	defer cancel()
+
+	tc := &testContainers{}
+	containersErr = func() error {
+		const user, password = "postgres", "postgres"
+		pg, err := testcontainers.Run(ctx, testPostgresImage,
+			testcontainers.WithExposedPorts("5432/tcp"),
+			testcontainers.WithEnv(map[string]string{
+				"POSTGRES_USER":     user,
+				"POSTGRES_PASSWORD": password,
+			}),
+			// Postgres restarts once after initdb, so it is ready on the second log line
+			testcontainers.WithWaitStrategy(
+				wait.ForLog("database system is ready to accept connections").WithOccurrence(2),
+				wait.ForListeningPort("5432/tcp"),
+			),
+		)
+		tc.postgres = pg
+		if err != nil {
+			return fmt.Errorf("start postgres container: %w", err)
+		}
+		host, err := pg.Host(ctx)
+		if err != nil {
+			return fmt.Errorf("postgres container host: %w", err)
+		}
+		port, err := pg.MappedPort(ctx, "5432/tcp")
+		if err != nil {
+			return fmt.Errorf("postgres container port: %w", err)
+		}
+		tc.dbConfig = &TestDBConfig{Host: host, Port: int(port.Num()), User: user, Password: password, SSLMode: "disable"}
+
+		rc, err := testcontainers.Run(ctx, testRedisImage,
+			testcontainers.WithExposedPorts("6379/tcp"),
+			testcontainers.WithWaitStrategy(wait.ForListeningPort("6379/tcp")),
+		)
+		tc.redis = rc
+		if err != nil {
+			return fmt.Errorf("start redis container: %w", err)
+		}
+		endpoint, err := rc.PortEndpoint(ctx, "6379/tcp", "")
+		if err != nil {
+			return fmt.Errorf("redis container endpoint: %w", err)
+		}
+		tc.redisURL = "redis://" + endpoint
+		return nil
+	}()
+	if containersErr != nil {
+		tc.terminate()
+		return nil, containersErr
+	}
+	containers = tc
+	return containers, nil
+}
+
+func (tc *testContainers) terminate() {
+	for _, c := range []*testcontainers.DockerContainer{tc.redis, tc.postgres} {
+		if c == nil {
+			continue
+		}
+		if err := testcontainers.TerminateContainer(c); err != nil {
+			log.Printf("Warning: failed to terminate test container: %v", err)
+		}
+	}
+}
+
+// TerminateContainers stops the containers started for the running test
+// package, if any. Containers left behind by a crashed test binary are
+// removed by the testcontainers reaper.
+func TerminateContainers() {
+	containersMu.Lock()
+	defer containersMu.Unlock()
+	if containers != nil {
+		containers.terminate()
+	}
+	containers, containersErr = nil, nil
+}
+
+// Main runs the tests of a package and then stops its containers. Call it
+// from TestMain:
+//
+//	func TestMain(m *testing.M) { os.Exit(testingutil.Main(m)) }
+func Main(m *stdtesting.M) int {
+	defer TerminateContainers()
+	return m.Run()
+}
+
+// SkipWithoutDocker skips t in testcontainers mode when Docker is not running
+func SkipWithoutDocker(t *stdtesting.T) {
+	t.Helper()
+	if TestDBMode() == TestDBModeTestcontainers {
+		testcontainers.SkipIfProviderIsNotHealthy(t)
+	}
+}
+
+// resolveTestDBConfig returns the Postgres server test databases are
+// created on for the configured mode
+func resolveTestDBConfig() (*TestDBConfig, error) {
+	if TestDBMode() != TestDBModeTestcontainers {
+		return GetTestDBConfig(), nil
+	}
+	tc, err := startTestContainers()
+	if err != nil {
+		return nil, err
+	}
+	cfg := *tc.dbConfig
+	return &cfg, nil
+}
+
+// TestRedisURL returns the Redis the tests use: the package's container in
+// testcontainers mode and TEST_REDIS_URL otherwise
+func TestRedisURL() (string, error) {
+	if TestDBMode() != TestDBModeTestcontainers {
+		return getEnv("TEST_REDIS_URL", "redis://localhost:6379"), nil
+	}
+	tc, err := startTestContainers()
+	if err != nil {
+		return "", err
+	}
+	return tc.redisURL, nil
+}
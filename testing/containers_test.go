@@ -0,0 +1,46 @@
+package testing
+
+import (
+	"context"
+	"os"
+	stdtesting "testing"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func TestMain(m *stdtesting.M) { os.Exit(Main(m)) }
+
+func TestContainersProvisionMigratedDatabaseAndRedis(t *stdtesting.T) {
+	if TestDBMode() != TestDBModeTestcontainers {
+		t.Skip("set TEST_DB_MODE=testcontainers to run against containers")
+	}
+	SkipWithoutDocker(t)
+
+	err := TestWithDB(func(tdb *TestDB) error {
+		var accountTypes int64
+		if err := tdb.DB.Raw("SELECT count(*) FROM account_types").Scan(&accountTypes).Error; err != nil {
+			return err
+		}
+		if accountTypes == 0 {
+			t.Error("migrations did not seed account types")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	redisURL, err := TestRedisURL()
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts, err := redis.ParseURL(redisURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rc := redis.NewClient(opts)
+	defer rc.Close()
+	if err := rc.Ping(context.Background()).Err(); err != nil {
+		t.Fatalf("ping redis container: %v", err)
+	}
+}
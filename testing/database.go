@@ -2,17 +2,16 @@
 package testing
 
 import (
-	"database/sql"
 	"fmt"
 	"log"
 	"math/rand"
 	"os"
-	"path/filepath"
 	"strconv"
 
+	"github.com/amirphl/Yamata-no-Orochi/app/dbmigrate"
+	"github.com/amirphl/Yamata-no-Orochi/migrations"
 	"github.com/amirphl/Yamata-no-Orochi/models"
 	"github.com/amirphl/Yamata-no-Orochi/utils"
-	_ "github.com/lib/pq" // PostgreSQL driver for database/sql
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
@@ -45,9 +44,13 @@ type TestDB struct {
 	config *TestDBConfig
 }
 
-// SetupTestDB creates a new test database with a unique name
+// SetupTestDB creates a new test database with a unique name and applies
+// every migration to it
 func SetupTestDB() (*TestDB, error) {
-	config := GetTestDBConfig()
+	config, err := resolveTestDBConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to provision test database server: %w", err)
+	}
 
 	// Generate unique database name
 	timestamp := utils.UTCNow().UnixNano()
@@ -198,70 +201,19 @@ func (tdb *TestDB) InsertTestAccountTypes() error {
 	return nil
 }
 
-// runTestMigrations runs all database migrations by executing SQL files directly
+// runTestMigrations applies every migration through the same migrator the
+// migrate command uses, so test databases get a schema_migrations version
 func runTestMigrations(databaseURL, dbName string) error {
-	// Get the absolute path to migrations directory
-	wd, err := os.Getwd()
+	m, err := dbmigrate.Open(databaseURL, migrations.FS)
 	if err != nil {
-		return fmt.Errorf("failed to get working directory: %w", err)
-	}
-
-	// If we're in the tests directory, go up one level to find migrations
-	if filepath.Base(wd) == "tests" {
-		wd = filepath.Dir(wd)
-	}
-
-	migrationsPath := filepath.Join(wd, "migrations")
-
-	// Check if migrations directory exists
-	if _, err := os.Stat(migrationsPath); os.IsNotExist(err) {
-		return fmt.Errorf("migrations directory not found at %s", migrationsPath)
-	}
-
-	// Create a database connection
-	db, err := sql.Open("postgres", databaseURL)
-	if err != nil {
-		return fmt.Errorf("failed to open database connection: %w", err)
-	}
-	defer db.Close()
-
-	// Test the connection
-	if err := db.Ping(); err != nil {
-		return fmt.Errorf("failed to ping database: %w", err)
-	}
-
-	// Define migration files in order (excluding down migrations and utility files)
-	var migrationFiles = []string{
-		"0001_create_account_types.sql",
-		"0002_create_customers.sql",
-		// TODO: Add more migration files here as needed
+		return fmt.Errorf("failed to open migrator: %w", err)
 	}
-
-	// Execute each migration file
-	for _, filename := range migrationFiles {
-		migrationPath := filepath.Join(migrationsPath, filename)
-
-		// Check if file exists
-		if _, err := os.Stat(migrationPath); os.IsNotExist(err) {
-			log.Printf("Warning: migration file %s not found, skipping", filename)
-			continue
-		}
-
-		// Read migration file
-		content, err := os.ReadFile(migrationPath)
-		if err != nil {
-			return fmt.Errorf("failed to read migration file %s: %w", filename, err)
-		}
-
-		// Execute migration
-		if _, err := db.Exec(string(content)); err != nil {
-			return fmt.Errorf("failed to execute migration %s: %w", filename, err)
-		}
-
-		log.Printf("Applied migration: %s", filename)
+	defer m.Close()
+	if err := m.Up(); err != nil {
+		return fmt.Errorf("failed to apply migrations: %w", err)
 	}
 
-	log.Printf("Successfully applied %d migrations to test database %s", len(migrationFiles), dbName)
+	log.Printf("Successfully applied %d migrations to test database %s", len(m.Migrations()), dbName)
 	return nil
 }
 
@@ -15,8 +15,29 @@ const (
 	CancelFuncKey ContextKey = "Cancel-Func"
 	CustomerIDKey ContextKey = "Customer-ID"
 	AdminIDKey    ContextKey = "Admin-ID"
+
+	// ImpersonationKey holds the Impersonation of requests an admin makes
+	// with a customer impersonation token
+	ImpersonationKey ContextKey = "Impersonation"
+
+	// DelegationKey holds the Delegation of requests an agency makes on
+	// behalf of a referred customer
+	DelegationKey ContextKey = "Delegation"
 )
 
+// Impersonation names the admin acting as a customer
+type Impersonation struct {
+	AdminID    uint `json:"admin_id"`
+	CustomerID uint `json:"customer_id"`
+}
+
+// Delegation names the agency acting for a referred customer under the
+// customer's delegation grant
+type Delegation struct {
+	AgencyID   uint `json:"agency_id"`
+	CustomerID uint `json:"customer_id"`
+}
+
 // Token and session time constants
 const (
 	// AccessTokenTTL is the time-to-live for access tokens (24 hours)
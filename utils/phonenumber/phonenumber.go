@@ -0,0 +1,137 @@
+// Package phonenumber parses, validates and formats Iranian mobile numbers.
+//
+// Numbers arrive as 09121234567, 9121234567, 989121234567, 00989121234567 or
+// +989121234567, sometimes with Persian or Arabic digits and separators. All of
+// them parse to the same Number. The canonical stored form is E.164
+// (+989121234567); the SMS gateway expects the international form without the
+// plus sign (989121234567).
+package phonenumber
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrInvalid is returned for input that is not an Iranian mobile number
+var ErrInvalid = errors.New("phonenumber: not a valid Iranian mobile number")
+
+// Operator identifies the mobile network a number was allocated to. Values
+// match the SMS tariff operators.
+type Operator string
+
+const (
+	OperatorUnknown Operator = ""
+	OperatorMCI     Operator = "mci"
+	OperatorMTN     Operator = "mtn"
+	OperatorRightel Operator = "rightel"
+)
+
+// operatorPrefixes maps the three digits after the leading 9 to the operator
+// the range was allocated to. Ported numbers keep their original prefix.
+var operatorPrefixes = map[string]Operator{
+	"910": OperatorMCI, "911": OperatorMCI, "912": OperatorMCI, "913": OperatorMCI, "914": OperatorMCI,
+	"915": OperatorMCI, "916": OperatorMCI, "917": OperatorMCI, "918": OperatorMCI, "919": OperatorMCI,
+	"990": OperatorMCI, "991": OperatorMCI, "992": OperatorMCI, "993": OperatorMCI, "994": OperatorMCI,
+
+	"900": OperatorMTN, "901": OperatorMTN, "902": OperatorMTN, "903": OperatorMTN, "904": OperatorMTN,
+	"905": OperatorMTN, "930": OperatorMTN, "933": OperatorMTN, "935": OperatorMTN, "936": OperatorMTN,
+	"937": OperatorMTN, "938": OperatorMTN, "939": OperatorMTN, "941": OperatorMTN,
+
+	"920": OperatorRightel, "921": OperatorRightel, "922": OperatorRightel,
+}
+
+// Number is a parsed Iranian mobile number
+type Number struct {
+	// subscriber holds the ten digits after the country code, e.g. 9121234567
+	subscriber string
+}
+
+// Parse accepts an Iranian mobile number in any common form
+func Parse(raw string) (Number, error) {
+	var b strings.Builder
+	for i, r := range strings.TrimSpace(raw) {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r >= '۰' && r <= '۹':
+			b.WriteRune('0' + (r - '۰'))
+		case r >= '٠' && r <= '٩':
+			b.WriteRune('0' + (r - '٠'))
+		case r == '+' && i == 0:
+		case r == ' ' || r == '-' || r == '(' || r == ')' || r == '.':
+		default:
+			return Number{}, ErrInvalid
+		}
+	}
+	digits := b.String()
+
+	switch {
+	case strings.HasPrefix(digits, "0098"):
+		digits = digits[4:]
+	case strings.HasPrefix(digits, "98") && len(digits) == 12:
+		digits = digits[2:]
+	case strings.HasPrefix(digits, "0") && len(digits) == 11:
+		digits = digits[1:]
+	}
+	if len(digits) != 10 || digits[0] != '9' {
+		return Number{}, ErrInvalid
+	}
+	return Number{subscriber: digits}, nil
+}
+
+// IsValid reports whether raw parses as an Iranian mobile number
+func IsValid(raw string) bool {
+	_, err := Parse(raw)
+	return err == nil
+}
+
+// Canonical returns the E.164 form used for storage
+func Canonical(raw string) (string, error) {
+	n, err := Parse(raw)
+	if err != nil {
+		return "", err
+	}
+	return n.E164(), nil
+}
+
+// CanonicalOrRaw returns the E.164 form of raw, or raw unchanged when it is
+// not an Iranian mobile number. Lookups use it so non-mobile identifiers such
+// as emails still match.
+func CanonicalOrRaw(raw string) string {
+	if n, err := Parse(raw); err == nil {
+		return n.E164()
+	}
+	return raw
+}
+
+// E164 returns the number as +989121234567
+func (n Number) E164() string {
+	return "+98" + n.subscriber
+}
+
+// International returns the number as 989121234567, the SMS gateway format
+func (n Number) International() string {
+	return "98" + n.subscriber
+}
+
+// National returns the number as 09121234567
+func (n Number) National() string {
+	return "0" + n.subscriber
+}
+
+// Forms returns every form the number may be stored in, canonical first
+func (n Number) Forms() []string {
+	return []string{n.E164(), n.International(), n.National(), n.subscriber}
+}
+
+// Operator returns the network the number's prefix was allocated to
+func (n Number) Operator() Operator {
+	if n.subscriber == "" {
+		return OperatorUnknown
+	}
+	return operatorPrefixes[n.subscriber[:3]]
+}
+
+func (n Number) String() string {
+	return n.E164()
+}
@@ -0,0 +1,102 @@
+package phonenumber
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		raw  string
+		want string
+	}{
+		{name: "e164", raw: "+989121234567", want: "+989121234567"},
+		{name: "international", raw: "989121234567", want: "+989121234567"},
+		{name: "international prefix", raw: "00989121234567", want: "+989121234567"},
+		{name: "national", raw: "09121234567", want: "+989121234567"},
+		{name: "subscriber only", raw: "9121234567", want: "+989121234567"},
+		{name: "separators", raw: " 0912 123-4567 ", want: "+989121234567"},
+		{name: "persian digits", raw: "۰۹۱۲۱۲۳۴۵۶۷", want: "+989121234567"},
+		{name: "arabic digits", raw: "٠٩١٢١٢٣٤٥٦٧", want: "+989121234567"},
+		{name: "landline", raw: "02112345678"},
+		{name: "too short", raw: "0912123456"},
+		{name: "too long", raw: "091212345678"},
+		{name: "letters", raw: "0912abc4567"},
+		{name: "plus in middle", raw: "98+9121234567"},
+		{name: "empty", raw: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, err := Parse(tt.raw)
+			if tt.want == "" {
+				if !errors.Is(err, ErrInvalid) {
+					t.Fatalf("expected ErrInvalid for %q, got %v (%s)", tt.raw, err, n)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := n.E164(); got != tt.want {
+				t.Fatalf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestNumberFormats(t *testing.T) {
+	t.Parallel()
+
+	n, err := Parse("09351234567")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n.International() != "989351234567" || n.National() != "09351234567" {
+		t.Fatalf("unexpected formats %q %q", n.International(), n.National())
+	}
+	forms := n.Forms()
+	if len(forms) != 4 || forms[0] != "+989351234567" || forms[3] != "9351234567" {
+		t.Fatalf("unexpected forms %v", forms)
+	}
+}
+
+func TestNumberOperator(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		raw  string
+		want Operator
+	}{
+		{raw: "09121234567", want: OperatorMCI},
+		{raw: "09901234567", want: OperatorMCI},
+		{raw: "09351234567", want: OperatorMTN},
+		{raw: "09011234567", want: OperatorMTN},
+		{raw: "09211234567", want: OperatorRightel},
+		{raw: "09981234567", want: OperatorUnknown},
+	}
+
+	for _, tt := range tests {
+		n, err := Parse(tt.raw)
+		if err != nil {
+			t.Fatalf("parse %q: %v", tt.raw, err)
+		}
+		if got := n.Operator(); got != tt.want {
+			t.Fatalf("%s: expected operator %q, got %q", tt.raw, tt.want, got)
+		}
+	}
+}
+
+func TestCanonicalOrRaw(t *testing.T) {
+	t.Parallel()
+
+	if got := CanonicalOrRaw("09121234567"); got != "+989121234567" {
+		t.Fatalf("expected canonical form, got %q", got)
+	}
+	if got := CanonicalOrRaw("user@example.com"); got != "user@example.com" {
+		t.Fatalf("expected raw value, got %q", got)
+	}
+}
@@ -99,3 +99,28 @@ func TehranNow() (time.Time, error) {
 	}
 	return time.Now().In(loc), nil
 }
+
+// TehranLocation returns the Asia/Tehran time zone, falling back to a fixed
+// +03:30 offset when the tz database is unavailable
+func TehranLocation() *time.Location {
+	if loc, err := time.LoadLocation("Asia/Tehran"); err == nil {
+		return loc
+	}
+	return time.FixedZone("Asia/Tehran", 3*3600+1800)
+}
+
+// TehranDayBounds returns the [start, end) range of the Tehran calendar day containing t
+func TehranDayBounds(t time.Time) (time.Time, time.Time) {
+	loc := TehranLocation()
+	y, m, d := t.In(loc).Date()
+	start := time.Date(y, m, d, 0, 0, 0, 0, loc)
+	return start, start.AddDate(0, 0, 1)
+}
+
+// TehranMonthBounds returns the [start, end) range of the Tehran calendar month containing t
+func TehranMonthBounds(t time.Time) (time.Time, time.Time) {
+	loc := TehranLocation()
+	y, m, _ := t.In(loc).Date()
+	start := time.Date(y, m, 1, 0, 0, 0, 0, loc)
+	return start, start.AddDate(0, 1, 0)
+}